@@ -0,0 +1,32 @@
+"""
+@knowgraph
+type: module
+description: Public entry point of the knowgraph Python client
+owner: knowgraph-mcp
+status: experimental
+tags: [python, client, notebook]
+context:
+  business_goal: Let notebook users get started with one import
+  domain: clients
+"""
+
+from .client import KnowGraphClient, KnowGraphError
+from .frames import (
+    coverage_trends_frame,
+    edges_frame,
+    leaderboard_frame,
+    nodes_frame,
+    team_trends_frame,
+    traverse_frame,
+)
+
+__all__ = [
+    "KnowGraphClient",
+    "KnowGraphError",
+    "coverage_trends_frame",
+    "edges_frame",
+    "leaderboard_frame",
+    "nodes_frame",
+    "team_trends_frame",
+    "traverse_frame",
+]
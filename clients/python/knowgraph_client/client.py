@@ -0,0 +1,158 @@
+"""
+@knowgraph
+type: service
+description: Thin client for the knowgraph serve HTTP graph and trends APIs using only the standard library
+owner: knowgraph-mcp
+status: experimental
+tags: [python, client, http, graph, trends]
+context:
+  business_goal: Let data analysts explore the graph from notebooks
+  domain: clients
+"""
+
+import json
+from typing import Any, Dict, Iterable, Iterator, List, Optional
+from urllib.error import HTTPError
+from urllib.parse import quote, urlencode
+from urllib.request import Request, urlopen
+
+PAGE_SIZE = 100
+
+
+class KnowGraphError(Exception):
+    """A request the server answered with an error status."""
+
+    def __init__(self, status: int, message: str) -> None:
+        super().__init__(f"{status}: {message}")
+        self.status = status
+        self.message = message
+
+
+def _join(values: Optional[Iterable[str]]) -> Optional[str]:
+    if values is None:
+        return None
+    joined = ",".join(values)
+    return joined or None
+
+
+class KnowGraphClient:
+    """Read the graph served by ``knowgraph serve --http``.
+
+    ``token`` is sent as a bearer token when the server runs with
+    ``serve.auth``. ``namespaces`` keeps every call to those namespaces.
+    """
+
+    def __init__(
+        self,
+        base_url: str = "http://127.0.0.1:8080",
+        token: Optional[str] = None,
+        namespaces: Optional[Iterable[str]] = None,
+        timeout: float = 30.0,
+    ) -> None:
+        self.base_url = base_url.rstrip("/")
+        self.token = token
+        self.namespaces = list(namespaces) if namespaces else None
+        self.timeout = timeout
+
+    def _get(self, path: str, **params: Any) -> Any:
+        query = {"namespaces": _join(self.namespaces), **params}
+        encoded = urlencode(
+            {key: value for key, value in query.items() if value is not None}
+        )
+        url = f"{self.base_url}{path}" + (f"?{encoded}" if encoded else "")
+        headers = {"Accept": "application/json"}
+        if self.token:
+            headers["Authorization"] = f"Bearer {self.token}"
+        try:
+            with urlopen(Request(url, headers=headers), timeout=self.timeout) as res:
+                return json.load(res)
+        except HTTPError as err:
+            try:
+                message = json.load(err).get("error", err.reason)
+            except ValueError:
+                message = err.reason
+            raise KnowGraphError(err.code, message) from None
+
+    def health(self) -> Dict[str, Any]:
+        """The server's liveness report."""
+        return self._get("/healthz")
+
+    def query(
+        self,
+        query: Optional[str] = None,
+        type: Optional[str] = None,
+        owner: Optional[str] = None,
+        tags: Optional[Iterable[str]] = None,
+        limit: Optional[int] = None,
+        offset: Optional[int] = None,
+    ) -> Dict[str, Any]:
+        """One page of matching nodes, as ``{"nodes": [...], "total": n}``."""
+        return self._get(
+            "/graph/v1/nodes",
+            query=query,
+            type=type,
+            owner=owner,
+            tags=_join(tags),
+            limit=limit,
+            offset=offset,
+        )
+
+    def iter_nodes(
+        self,
+        query: Optional[str] = None,
+        type: Optional[str] = None,
+        owner: Optional[str] = None,
+        tags: Optional[Iterable[str]] = None,
+        page_size: int = PAGE_SIZE,
+    ) -> Iterator[Dict[str, Any]]:
+        """Every matching node, fetched a page at a time."""
+        tags = list(tags) if tags else None
+        offset = 0
+        while True:
+            page = self.query(query, type, owner, tags, page_size, offset)
+            yield from page["nodes"]
+            offset += len(page["nodes"])
+            if not page["nodes"] or offset >= page["total"]:
+                return
+
+    def node(self, node_id: str) -> Dict[str, Any]:
+        """One node with its annotation fields, dependencies, and dependents."""
+        return self._get(f"/graph/v1/nodes/{quote(node_id, safe='')}")
+
+    def traverse(
+        self,
+        start_id: str,
+        direction: str = "outgoing",
+        max_depth: Optional[int] = None,
+        kinds: Optional[Iterable[str]] = None,
+        provenance: Optional[Iterable[str]] = None,
+        min_confidence: Optional[float] = None,
+    ) -> List[Dict[str, Any]]:
+        """The steps reached from ``start_id``, each a node, depth, and edge."""
+        return self._get(
+            "/graph/v1/traverse",
+            startId=start_id,
+            direction=direction,
+            maxDepth=max_depth,
+            kinds=_join(kinds),
+            provenance=_join(provenance),
+            minConfidence=min_confidence,
+        )["steps"]
+
+    def snapshot(self) -> Dict[str, Any]:
+        """Every node and edge, as ``{"nodes": [...], "edges": [...]}``."""
+        return self._get("/graph/v1/snapshot")
+
+    def leaderboard(self) -> Dict[str, Any]:
+        """Every team's latest grade, ranked across repositories."""
+        return self._get("/trends/v1/leaderboard")
+
+    def team_trends(
+        self, owner: Optional[str] = None, since: Optional[str] = None
+    ) -> List[Dict[str, Any]]:
+        """Each team's score at every recorded scorecard."""
+        return self._get("/trends/v1/teams", owner=owner, since=since)["items"]
+
+    def coverage_trends(self, since: Optional[str] = None) -> List[Dict[str, Any]]:
+        """Each repository's coverage at every recorded scan."""
+        return self._get("/trends/v1/coverage", since=since)["items"]
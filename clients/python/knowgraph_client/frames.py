@@ -0,0 +1,84 @@
+"""
+@knowgraph
+type: module
+description: pandas DataFrame helpers that flatten graph and trends API responses for notebooks
+owner: knowgraph-mcp
+status: experimental
+tags: [python, client, pandas, notebook]
+context:
+  business_goal: Hand analysts graph data in the DataFrame shape their tools already expect
+  domain: clients
+"""
+
+from typing import Any, Dict, Iterable, List
+
+from .client import KnowGraphClient
+
+
+def _pandas() -> Any:
+    try:
+        import pandas
+    except ImportError:
+        raise ImportError(
+            "DataFrame helpers need pandas: pip install 'knowgraph-client[pandas]'"
+        ) from None
+    return pandas
+
+
+def _frame(rows: Iterable[Dict[str, Any]]) -> Any:
+    return _pandas().DataFrame(list(rows))
+
+
+def nodes_frame(client: KnowGraphClient, **filters: Any) -> Any:
+    """Every node matching ``filters`` (as for ``iter_nodes``), one per row."""
+    return _frame(client.iter_nodes(**filters))
+
+
+def edges_frame(client: KnowGraphClient) -> Any:
+    """Every edge of the graph, one per row, with node names alongside ids."""
+    snapshot = client.snapshot()
+    names = {node["id"]: node["name"] for node in snapshot["nodes"]}
+    return _frame(
+        {**edge, "fromName": names.get(edge["from"]), "toName": names.get(edge["to"])}
+        for edge in snapshot["edges"]
+    )
+
+
+def traverse_frame(client: KnowGraphClient, start_id: str, **options: Any) -> Any:
+    """The nodes reached from ``start_id``, one per row with its depth and edge."""
+    rows: List[Dict[str, Any]] = []
+    for step in client.traverse(start_id, **options):
+        edge = step.get("edge") or {}
+        rows.append(
+            {
+                **step["node"],
+                "depth": step["depth"],
+                "edgeKind": edge.get("kind"),
+                "edgeFrom": edge.get("from"),
+                "edgeTo": edge.get("to"),
+            }
+        )
+    return _frame(rows)
+
+
+def leaderboard_frame(client: KnowGraphClient) -> Any:
+    """The team leaderboard, one entry per row."""
+    return _frame(client.leaderboard()["entries"])
+
+
+def team_trends_frame(client: KnowGraphClient, **options: Any) -> Any:
+    """Team scores over time, one point per row, ready to pivot and plot."""
+    return _frame(
+        {"namespace": item["namespace"], "owner": item["owner"], **point}
+        for item in client.team_trends(**options)
+        for point in item["points"]
+    )
+
+
+def coverage_trends_frame(client: KnowGraphClient, **options: Any) -> Any:
+    """Repository coverage over time, one point per row."""
+    return _frame(
+        {"namespace": item["namespace"], **point}
+        for item in client.coverage_trends(**options)
+        for point in item["points"]
+    )
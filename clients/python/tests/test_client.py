@@ -0,0 +1,130 @@
+import importlib.util
+import json
+import threading
+import unittest
+from http.server import BaseHTTPRequestHandler, HTTPServer
+from urllib.parse import parse_qs, urlparse
+
+from knowgraph_client import (
+    KnowGraphClient,
+    KnowGraphError,
+    edges_frame,
+    nodes_frame,
+    team_trends_frame,
+)
+
+HAS_PANDAS = importlib.util.find_spec("pandas") is not None
+
+NODES = [
+    {"id": f"id-{name}", "name": name, "entityType": "service", "owner": "shop"}
+    for name in ["Cart", "Checkout", "Payments"]
+]
+
+EDGE = {"from": "id-Checkout", "to": "id-Payments", "kind": "service"}
+
+
+class Stub(BaseHTTPRequestHandler):
+    requests = []
+
+    def log_message(self, *args):
+        pass
+
+    def reply(self, status, body):
+        self.send_response(status)
+        self.send_header("Content-Type", "application/json")
+        self.end_headers()
+        self.wfile.write(json.dumps(body).encode())
+
+    def do_GET(self):
+        url = urlparse(self.path)
+        params = {key: values[0] for key, values in parse_qs(url.query).items()}
+        Stub.requests.append((url.path, params, self.headers.get("Authorization")))
+        if url.path == "/graph/v1/nodes":
+            offset = int(params.get("offset", 0))
+            limit = int(params.get("limit", len(NODES)))
+            page = NODES[offset : offset + limit]
+            self.reply(200, {"nodes": page, "total": len(NODES)})
+        elif url.path == "/graph/v1/nodes/acme%2Fshop%3Aid-Cart":
+            self.reply(200, {"node": NODES[0], "dependencies": [], "dependents": []})
+        elif url.path == "/graph/v1/traverse":
+            step = {"node": NODES[2], "depth": 1, "edge": EDGE}
+            self.reply(200, {"steps": [step]})
+        elif url.path == "/graph/v1/snapshot":
+            self.reply(200, {"nodes": NODES, "edges": [EDGE]})
+        elif url.path == "/trends/v1/teams":
+            points = [{"timestamp": "2024-06-01", "score": 80, "grade": "B"}]
+            item = {"namespace": "acme/shop", "owner": "shop", "points": points}
+            self.reply(200, {"items": [item]})
+        else:
+            self.reply(404, {"error": "Not found"})
+
+
+class ClientTest(unittest.TestCase):
+    @classmethod
+    def setUpClass(cls):
+        cls.server = HTTPServer(("127.0.0.1", 0), Stub)
+        threading.Thread(target=cls.server.serve_forever, daemon=True).start()
+        cls.base_url = f"http://127.0.0.1:{cls.server.server_port}"
+
+    @classmethod
+    def tearDownClass(cls):
+        cls.server.shutdown()
+        cls.server.server_close()
+
+    def setUp(self):
+        Stub.requests = []
+        self.client = KnowGraphClient(
+            self.base_url, token="secret", namespaces=["acme/shop"]
+        )
+
+    def test_sends_the_token_and_namespaces(self):
+        self.client.query(owner="shop", tags=["pci", "core"])
+        path, params, auth = Stub.requests[0]
+        self.assertEqual(path, "/graph/v1/nodes")
+        self.assertEqual(
+            params, {"namespaces": "acme/shop", "owner": "shop", "tags": "pci,core"}
+        )
+        self.assertEqual(auth, "Bearer secret")
+
+    def test_pages_through_every_node(self):
+        names = [node["name"] for node in self.client.iter_nodes(page_size=2)]
+        self.assertEqual(names, ["Cart", "Checkout", "Payments"])
+        self.assertEqual(len(Stub.requests), 2)
+
+    def test_encodes_node_ids(self):
+        node = self.client.node("acme/shop:id-Cart")
+        self.assertEqual(node["node"]["name"], "Cart")
+
+    def test_traverses_with_server_parameter_names(self):
+        steps = self.client.traverse("id-Checkout", max_depth=2, kinds=["service"])
+        self.assertEqual(steps[0]["node"]["name"], "Payments")
+        _, params, _ = Stub.requests[0]
+        self.assertEqual(params["startId"], "id-Checkout")
+        self.assertEqual(params["maxDepth"], "2")
+        self.assertEqual(params["kinds"], "service")
+
+    def test_raises_the_server_error(self):
+        with self.assertRaises(KnowGraphError) as caught:
+            self.client._get("/graph/v1/edges")
+        self.assertEqual(caught.exception.status, 404)
+        self.assertEqual(caught.exception.message, "Not found")
+
+    @unittest.skipIf(HAS_PANDAS, "pandas is installed")
+    def test_frames_explain_the_missing_extra(self):
+        with self.assertRaisesRegex(ImportError, r"knowgraph-client\[pandas\]"):
+            nodes_frame(self.client)
+
+    @unittest.skipUnless(HAS_PANDAS, "pandas is not installed")
+    def test_builds_data_frames(self):
+        names = list(nodes_frame(self.client)["name"])
+        self.assertEqual(names, ["Cart", "Checkout", "Payments"])
+        edges = edges_frame(self.client)
+        self.assertEqual(list(edges["toName"]), ["Payments"])
+        trends = team_trends_frame(self.client)
+        self.assertEqual(
+            list(trends.columns), ["namespace", "owner", "timestamp", "score", "grade"]
+        )
+
+
+if __name__ == "__main__":
+    unittest.main()
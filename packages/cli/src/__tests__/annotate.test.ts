@@ -0,0 +1,147 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Readable } from 'node:stream';
+import { Command } from 'commander';
+import { readAuditLog } from '@know-graph/core';
+import type { UnannotatedSymbol, WizardResult } from '@know-graph/core';
+import {
+  formatUnannotated,
+  formatWizardResult,
+  registerAnnotateCommand,
+} from '../commands/annotate.js';
+
+const symbol: UnannotatedSymbol = {
+  name: 'charge',
+  filePath: 'src/charge.ts',
+  line: 3,
+  language: 'typescript',
+  entityType: 'function',
+  source: 'export function charge(): void {}',
+};
+
+describe('annotate command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-annotate-'));
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerAnnotateCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'annotate', ...args]);
+  }
+
+  it('lists unannotated symbols and how to annotate them', () => {
+    const output = formatUnannotated([symbol]);
+    expect(output).toContain('1 unannotated symbol(s)');
+    expect(output).toContain('src/charge.ts:3');
+    expect(output).toContain('knowgraph annotate --interactive');
+    expect(formatUnannotated([])).toContain('No unannotated symbols');
+  });
+
+  it('summarizes a wizard run', () => {
+    const result: WizardResult = {
+      annotated: [
+        {
+          symbol,
+          metadata: { type: 'function', description: 'Charges a card' },
+          block: 'type: function\ndescription: Charges a card',
+        },
+      ],
+      files: [],
+      skipped: 1,
+      found: 4,
+      quit: true,
+    };
+    expect(formatWizardResult(result)).toContain(
+      'Annotated 1 of 4 unannotated symbol(s); 3 left, 1 skipped',
+    );
+  });
+
+  it('prints the symbols as JSON without --interactive', async () => {
+    writeFileSync(join(dir, 'charge.ts'), 'export function charge() {}\n');
+    await run('--path', dir, '--format', 'json');
+    const symbols = JSON.parse(String(consoleLogSpy.mock.calls[0][0]));
+    expect(symbols.map((s: UnannotatedSymbol) => s.name)).toEqual(['charge']);
+  });
+
+  describe('--interactive', () => {
+    const source = 'export function charge() {}\n';
+    const stdin = Object.getOwnPropertyDescriptor(process, 'stdin');
+    let stderrSpy: ReturnType<typeof vi.spyOn>;
+
+    // Annotate it, describe it, keep the type, no owner, no tags
+    function runAnswering(...args: string[]): Promise<Command> {
+      Object.defineProperty(process, 'stdin', {
+        value: Readable.from(['y\nCharges a card\n\n-\n-\n']),
+        configurable: true,
+      });
+      return run(
+        '--interactive',
+        ...args,
+        '--path',
+        dir,
+        '--config',
+        join(dir, '.knowgraph.yml'),
+        '--format',
+        'json',
+      );
+    }
+
+    beforeEach(() => {
+      vi.stubEnv('KNOWGRAPH_ACTOR', 'ana@example.com');
+      // Questions go to stderr when stdout carries JSON
+      stderrSpy = vi
+        .spyOn(process.stderr, 'write')
+        .mockImplementation(() => true);
+      writeFileSync(join(dir, 'charge.ts'), source);
+    });
+
+    afterEach(() => {
+      if (stdin) Object.defineProperty(process, 'stdin', stdin);
+      stderrSpy.mockRestore();
+      vi.unstubAllEnvs();
+    });
+
+    it('writes the answered blocks and audits them', async () => {
+      await runAnswering();
+      expect(readFileSync(join(dir, 'charge.ts'), 'utf-8')).toContain(
+        'description: Charges a card',
+      );
+      const [entry] = readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'));
+      expect(entry?.command).toBe('annotate');
+      expect(entry?.changes).toHaveLength(1);
+    });
+
+    it('shows the plan without writing under --dry-run', async () => {
+      await runAnswering('--dry-run');
+      expect(readFileSync(join(dir, 'charge.ts'), 'utf-8')).toBe(source);
+      const plan = JSON.parse(String(consoleLogSpy.mock.calls.at(-1)?.[0]));
+      expect(plan.command).toBe('annotate --interactive');
+      expect(plan.changes[0].diff).toContain('+ * description: Charges a card');
+      expect(readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'))).toEqual([]);
+    });
+  });
+
+  it('rejects a bad limit as a usage error', async () => {
+    await run('--path', dir, '--limit', '0');
+    expect(process.exitCode).toBe(2);
+  });
+});
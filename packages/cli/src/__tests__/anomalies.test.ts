@@ -0,0 +1,245 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import {
+  appendScanMetrics,
+  createDatabaseManager,
+  readOutbox,
+  readScanHistory,
+} from '@know-graph/core';
+import type { AnomalyReport, ScanMetrics } from '@know-graph/core';
+import {
+  formatAnomalies,
+  registerAnomaliesCommand,
+} from '../commands/anomalies.js';
+import {
+  createAlertSinks,
+  historyPath,
+  recordScan,
+  toAnomalyThresholds,
+} from '../utils/history.js';
+import { createManifestDeliveryClient } from '../utils/delivery.js';
+import { readHistoryConfig } from '../utils/manifest.js';
+
+function scan(coverage: number, timestamp: string): ScanMetrics {
+  return {
+    timestamp,
+    files: 10,
+    entities: 4,
+    edges: 6,
+    coverage,
+    byEntity: {},
+  };
+}
+
+const first = scan(80, '2026-03-01T00:00:00.000Z');
+const second = scan(70, '2026-03-02T00:00:00.000Z');
+const report: AnomalyReport = {
+  previous: first,
+  current: second,
+  anomalies: [
+    {
+      kind: 'coverage_drop',
+      message: 'Coverage dropped 10 points (80% → 70%)',
+      value: 10,
+      threshold: 5,
+      entities: [],
+    },
+  ],
+};
+
+describe('anomalies command', () => {
+  let dir: string;
+  let configPath: string;
+  let logSpy: ReturnType<typeof vi.spyOn>;
+  let errorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-anomalies-'));
+    configPath = join(dir, '.knowgraph.yml');
+    logSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    logSpy.mockRestore();
+    errorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  it('summarizes the latest scan and lists anomalies by scan', () => {
+    const output = formatAnomalies([first, second], [report]);
+    expect(output).toContain('2 scans, latest 2026-03-02T00:00:00.000Z');
+    expect(output).toContain('Coverage: 70% (-10 pts)');
+    expect(output).toContain('Coverage dropped 10 points (80% → 70%)');
+    expect(formatAnomalies([], [])).toContain('No scans recorded yet');
+  });
+
+  it('maps manifest thresholds and resolves sinks', () => {
+    writeFileSync(
+      configPath,
+      [
+        'version: "1.0"',
+        'history:',
+        '  thresholds:',
+        '    coverage_drop_points: 2',
+        '  sinks:',
+        '    - type: slack',
+        '      url_env: SLACK_WEBHOOK_URL',
+        '    - type: webhook',
+        '      url_env: UNSET_WEBHOOK_URL',
+        '    - type: file',
+        '      path: alerts.jsonl',
+        '    - type: teams',
+        '      url: https://example.webhook.office.com/x',
+        '    - type: email',
+        '      host: smtp.example.com',
+        '      from: knowgraph@example.com',
+        '      to: [platform@example.com]',
+        '    - type: email',
+        '      host: smtp.example.com',
+        '      from: knowgraph@example.com',
+        '      to: [platform@example.com]',
+        '      password_env: UNSET_SMTP_PASSWORD',
+      ].join('\n'),
+    );
+    const config = readHistoryConfig(configPath);
+    expect(toAnomalyThresholds(config).coverageDropPoints).toBe(2);
+    const sinks = createAlertSinks(configPath, config, {
+      SLACK_WEBHOOK_URL: 'https://hooks.slack.com/x',
+    });
+    expect(sinks.map((sink) => sink.name)).toEqual([
+      'slack',
+      'file',
+      'teams',
+      'email',
+    ]);
+    expect(errorSpy).toHaveBeenCalledWith(
+      expect.stringContaining('UNSET_WEBHOOK_URL is not set'),
+    );
+    expect(errorSpy).toHaveBeenCalledWith(
+      expect.stringContaining('UNSET_SMTP_PASSWORD is not set'),
+    );
+  });
+
+  it('queues alerts next to the manifest when a sink is unreachable', async () => {
+    writeFileSync(
+      configPath,
+      [
+        'version: "1.0"',
+        'history:',
+        '  sinks:',
+        '    - type: slack',
+        '      url: https://hooks.slack.com/x',
+        'delivery:',
+        '  attempts: 1',
+        '  queue:',
+        '    path: out/outbox.jsonl',
+      ].join('\n'),
+    );
+    const fetch = vi.fn(async () => {
+      throw new TypeError('fetch failed');
+    });
+    vi.stubGlobal('fetch', fetch);
+    try {
+      const client = createManifestDeliveryClient(configPath);
+      const [slack] = createAlertSinks(
+        configPath,
+        readHistoryConfig(configPath),
+        {},
+        client,
+      );
+      expect(await slack.send(report)).toBe('queued');
+      const [queued] = readOutbox(join(dir, 'out', 'outbox.jsonl'));
+      expect(queued.request.sink).toBe('slack');
+      expect(queued.error).toBe('fetch failed');
+    } finally {
+      vi.unstubAllGlobals();
+    }
+  });
+
+  it('templates and signs webhooks from files next to the manifest', async () => {
+    writeFileSync(join(dir, 'hook.tmpl'), '{"summary": {{ json .text }}}');
+    writeFileSync(join(dir, 'broken.tmpl'), '{{ if }}');
+    writeFileSync(
+      configPath,
+      [
+        'version: "1.0"',
+        'history:',
+        '  sinks:',
+        '    - type: webhook',
+        '      url: https://hooks.example.com/kg',
+        '      template_file: hook.tmpl',
+        '      secret_env: WEBHOOK_SECRET',
+        '    - type: webhook',
+        '      url: https://hooks.example.com/kg',
+        '      template_file: broken.tmpl',
+      ].join('\n'),
+    );
+    const fetch = vi.fn(async () => ({ ok: true, status: 200 }));
+    vi.stubGlobal('fetch', fetch);
+    try {
+      const sinks = createAlertSinks(
+        configPath,
+        readHistoryConfig(configPath),
+        { WEBHOOK_SECRET: 'shh' },
+      );
+      expect(sinks).toHaveLength(1);
+      expect(errorSpy).toHaveBeenCalledWith(
+        expect.stringContaining('broken.tmpl:1: missing value for if'),
+      );
+      await sinks[0].send(report);
+      const [, init] = fetch.mock.calls[0] as unknown as [string, RequestInit];
+      expect(JSON.parse(init.body as string).summary).toContain(
+        'Coverage dropped 10 points',
+      );
+      expect(init.headers).toHaveProperty('X-Knowgraph-Signature-256');
+    } finally {
+      vi.unstubAllGlobals();
+    }
+  });
+
+  it('records a scan per index run next to the manifest', async () => {
+    mkdirSync(join(dir, '.knowgraph'));
+    const dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    const dbManager = createDatabaseManager(dbPath);
+    dbManager.initialize();
+    dbManager.close();
+
+    await recordScan(configPath, dbPath, 4);
+    await recordScan(configPath, dbPath, 4);
+    const history = readScanHistory(historyPath(configPath));
+    expect(history.map((s) => [s.files, s.entities])).toEqual([
+      [4, 0],
+      [4, 0],
+    ]);
+
+    writeFileSync(configPath, 'version: "1.0"\nhistory:\n  enabled: false\n');
+    await recordScan(configPath, dbPath, 4);
+    expect(readScanHistory(historyPath(configPath))).toHaveLength(2);
+  });
+
+  it('fails --check when the latest scan has anomalies', async () => {
+    const path = historyPath(configPath);
+    appendScanMetrics(path, first);
+    appendScanMetrics(path, second);
+
+    const program = new Command();
+    registerAnomaliesCommand(program);
+    await program.parseAsync([
+      'node',
+      'knowgraph',
+      'anomalies',
+      '--config',
+      configPath,
+      '--check',
+    ]);
+    expect(process.exitCode).toBe(1);
+  });
+});
@@ -0,0 +1,95 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { Command } from 'commander';
+import type { AskResult, StoredEntity } from '@know-graph/core';
+import { formatAskResult, registerAskCommand } from '../commands/ask.js';
+
+const entity: StoredEntity = {
+  id: 'session-store',
+  filePath: 'src/session-store.ts',
+  name: 'SessionStore',
+  entityType: 'service',
+  description: 'Stores user sessions in Redis',
+  rawDocstring: null,
+  signature: null,
+  parent: null,
+  language: 'typescript',
+  line: 4,
+  column: 0,
+  owner: 'identity-team',
+  status: 'stable',
+  metadata: { type: 'service', description: 'Stores user sessions in Redis' },
+  tags: [],
+  links: [],
+  fileHash: null,
+  createdAt: '2024-01-01T00:00:00Z',
+  updatedAt: '2024-01-01T00:00:00Z',
+};
+
+const result: AskResult = {
+  question: 'Which services can write to redis-sessions?',
+  mode: 'offline',
+  terms: ['write', 'redi', 'session'],
+  types: ['service'],
+  matches: [
+    {
+      node: {
+        id: 'session-store',
+        name: 'SessionStore',
+        entityType: 'service',
+        external: false,
+        filePath: 'src/session-store.ts',
+        owner: 'identity-team',
+        domain: null,
+        workspace: null,
+      },
+      entity,
+      kind: 'service',
+      score: 0.421,
+      terms: ['redi', 'session'],
+      dependents: [],
+      dependencies: [],
+    },
+  ],
+  answer: 'redis-sessions (database, external)',
+};
+
+describe('ask command', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerAskCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'ask', ...args]);
+  }
+
+  it('prints the answer with its sources', () => {
+    const output = formatAskResult(result);
+    expect(output).toContain('redis-sessions (database, external)');
+    expect(output).toContain(
+      '1. SessionStore (service) src/session-store.ts:4, score 0.42',
+    );
+    expect(output).toContain('Answered offline');
+  });
+
+  it('requires --llm for embeddings', async () => {
+    await run('what uses redis?', '--embedding-model', 'embed');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    await run('what uses redis?', '--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+});
@@ -0,0 +1,131 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { readAuditLog } from '@know-graph/core';
+import type { AuditEntry } from '@know-graph/core';
+import {
+  formatAuditEntries,
+  formatVerification,
+  registerAuditCommand,
+} from '../commands/audit.js';
+import { auditLogPath, recordAudit, resolveActor } from '../utils/audit.js';
+
+const entry: AuditEntry = {
+  seq: 1,
+  timestamp: '2026-03-01T12:00:00.000Z',
+  actor: 'alice@example.com',
+  command: 'lint',
+  args: ['lint', '--fix'],
+  cwd: '/repo',
+  outcome: 'success',
+  changes: [
+    {
+      target: 'src/pay.ts',
+      summary: 'modified',
+      diff: '--- a/src/pay.ts\n+++ b/src/pay.ts',
+    },
+  ],
+  prevHash: '0'.repeat(64),
+  hash: 'abc',
+};
+
+describe('audit command', () => {
+  it('registers log, export, and verify', () => {
+    const program = new Command();
+    registerAuditCommand(program);
+    const audit = program.commands.find((c) => c.name() === 'audit');
+    expect(audit?.commands.map((c) => c.name())).toEqual([
+      'log',
+      'export',
+      'verify',
+    ]);
+  });
+
+  it('lists entries with change summaries and optional diffs', () => {
+    const output = formatAuditEntries([entry], false);
+    expect(output).toContain('#1');
+    expect(output).toContain('alice@example.com');
+    expect(output).toContain('src/pay.ts: modified');
+    expect(output).not.toContain('+++ b/src/pay.ts');
+    expect(formatAuditEntries([entry], true)).toContain('+++ b/src/pay.ts');
+    expect(formatAuditEntries([], false)).toBe('No audit entries found.');
+  });
+
+  it('reports where the chain breaks', () => {
+    expect(formatVerification({ valid: true, entries: 3 })).toContain(
+      'intact: 3 entries',
+    );
+    expect(
+      formatVerification({
+        valid: false,
+        entries: 3,
+        brokenAt: 2,
+        reason: 'entry contents do not match its hash',
+      }),
+    ).toContain('broken at entry #2');
+  });
+});
+
+describe('resolveActor', () => {
+  it('prefers KNOWGRAPH_ACTOR, then the git author', () => {
+    expect(
+      resolveActor({ KNOWGRAPH_ACTOR: 'ci-bot', GIT_AUTHOR_EMAIL: 'a@b.c' }),
+    ).toBe('ci-bot');
+    expect(resolveActor({ GIT_AUTHOR_EMAIL: 'a@b.c' })).toBe('a@b.c');
+    expect(resolveActor({})).toContain('@');
+  });
+});
+
+describe('recordAudit', () => {
+  let dir: string;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-audit-'));
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+    vi.stubEnv('KNOWGRAPH_ACTOR', 'tester');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    process.exitCode = originalExitCode;
+    vi.unstubAllEnvs();
+  });
+
+  it('appends to the log next to the manifest', () => {
+    const configPath = join(dir, '.knowgraph.yml');
+    recordAudit(configPath, 'init', [
+      { target: '.knowgraph.yml', summary: 'created' },
+      undefined,
+    ]);
+    process.exitCode = 1;
+    recordAudit(configPath, 'lint', []);
+
+    const entries = readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'));
+    expect(entries.map((e) => [e.command, e.actor, e.outcome])).toEqual([
+      ['init', 'tester', 'success'],
+      ['lint', 'tester', 'failure'],
+    ]);
+    expect(entries[0].changes).toEqual([
+      { target: '.knowgraph.yml', summary: 'created' },
+    ]);
+  });
+
+  it('honours the manifest audit settings', () => {
+    const configPath = join(dir, '.knowgraph.yml');
+    writeFileSync(
+      configPath,
+      'version: "1.0"\naudit:\n  path: evidence/audit.jsonl\n',
+    );
+    expect(auditLogPath(configPath)).toBe(
+      join(dir, 'evidence', 'audit.jsonl'),
+    );
+
+    writeFileSync(configPath, 'version: "1.0"\naudit:\n  enabled: false\n');
+    recordAudit(configPath, 'init', []);
+    expect(readAuditLog(auditLogPath(configPath))).toEqual([]);
+  });
+});
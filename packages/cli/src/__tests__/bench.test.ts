@@ -0,0 +1,121 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import type { BenchReport } from '@know-graph/core';
+import { formatBenchReport, registerBenchCommand } from '../commands/bench.js';
+
+const report: BenchReport = {
+  schema: 1,
+  version: '1.2.3',
+  createdAt: '2026-01-01T00:00:00.000Z',
+  node: '20.11.0',
+  os: 'linux',
+  arch: 'x64',
+  cpus: 4,
+  results: [
+    {
+      name: 'scan/1k',
+      case: 'scan',
+      files: 1000,
+      entities: 3000,
+      edges: 1500,
+      runs: 3,
+      medianMs: 120,
+      minMs: 110,
+      maxMs: 140,
+    },
+  ],
+};
+
+describe('bench command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-bench-cli-'));
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    program.version('1.2.3');
+    registerBenchCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'bench',
+      '--work-dir',
+      join(dir, 'work'),
+      '--runs',
+      '1',
+      ...args,
+    ]);
+  }
+
+  it('shows each case with its baseline change', () => {
+    const output = formatBenchReport(report, [
+      {
+        name: 'scan/1k',
+        baselineMs: 100,
+        currentMs: 120,
+        changePercent: 20,
+        regressed: true,
+      },
+    ]);
+    expect(output).toContain('scan/1k');
+    expect(output).toContain('120 ms');
+    expect(output).toContain('was 100 ms');
+    expect(output).toContain('+20% regressed');
+  });
+
+  it('writes a machine-readable report', async () => {
+    const output = join(dir, 'bench.json');
+    await run('--sizes', '10', '--output', output);
+    const written = JSON.parse(readFileSync(output, 'utf-8')) as BenchReport;
+    expect(written.version).toBe('1.2.3');
+    expect(written.results.map((r) => r.name)).toEqual([
+      'scan/10',
+      'graph/10',
+    ]);
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('fails with a policy exit code on a regression', async () => {
+    const baseline = join(dir, 'baseline.json');
+    const fast: BenchReport = {
+      ...report,
+      results: [
+        { ...report.results[0], name: 'scan/200', medianMs: 0.01 },
+        { ...report.results[0], name: 'graph/200', medianMs: 0.01 },
+      ],
+    };
+    writeFileSync(baseline, JSON.stringify(fast));
+    await run('--sizes', '200', '--baseline', baseline);
+    // Scanning two hundred files takes well over the 5 ms noise floor
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('rejects invalid sizes as a usage error', async () => {
+    await run('--sizes', 'lots');
+    expect(process.exitCode).toBe(2);
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain('lots');
+  });
+
+  it('rejects a baseline that is not a bench report', async () => {
+    const baseline = join(dir, 'baseline.json');
+    writeFileSync(baseline, '{"hello": "world"}');
+    await run('--sizes', '10', '--baseline', baseline);
+    expect(process.exitCode).toBe(4);
+  });
+});
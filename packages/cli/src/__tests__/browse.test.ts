@@ -0,0 +1,247 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import { BUILTIN_VIEWS } from '@know-graph/core';
+import type { DependencyGraph, StoredEntity } from '@know-graph/core';
+import {
+  editorCommand,
+  registerBrowseCommand,
+  toBrowserKey,
+} from '../commands/browse.js';
+import {
+  createBrowserState,
+  renderBrowser,
+  updateBrowser,
+} from '../utils/browser.js';
+import type { BrowserKey, BrowserState } from '../utils/browser.js';
+import { fuzzyFilter, fuzzyScore } from '../utils/fuzzy.js';
+
+function createEntity(overrides: Partial<StoredEntity> = {}): StoredEntity {
+  return {
+    id: 'checkout',
+    filePath: 'src/checkout.ts',
+    name: 'CheckoutService',
+    entityType: 'service',
+    description: 'Runs checkout for a cart',
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 12,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: { type: 'service', description: 'Runs checkout for a cart' },
+    tags: ['payments'],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const checkout = createEntity();
+const ledger = createEntity({
+  id: 'ledger',
+  filePath: 'src/ledger.ts',
+  name: 'LedgerRepository',
+  entityType: 'class',
+  description: 'Stores ledger entries',
+  line: 3,
+  tags: ['billing'],
+});
+
+const graph: DependencyGraph = {
+  nodes: [
+    ...[checkout, ledger].map((entity) => ({
+      id: entity.id,
+      name: entity.name,
+      entityType: entity.entityType,
+      external: false,
+      filePath: entity.filePath,
+      owner: entity.owner,
+      domain: null,
+      workspace: null,
+    })),
+    {
+      id: 'external:external_api:stripe',
+      name: 'stripe',
+      entityType: null,
+      external: true,
+      filePath: null,
+      owner: null,
+      domain: null,
+      workspace: null,
+    },
+  ],
+  edges: [
+    { from: 'checkout', to: 'ledger', kind: 'service' },
+    {
+      from: 'checkout',
+      to: 'external:external_api:stripe',
+      kind: 'external_api',
+    },
+  ],
+};
+
+function press(state: BrowserState, ...keys: BrowserKey[]): BrowserState {
+  return keys.reduce(
+    (current, key) => updateBrowser(current, key).state,
+    state,
+  );
+}
+
+const viewport = { rows: 20, columns: 100 };
+
+describe('fuzzy matching', () => {
+  it('matches subsequences and ranks word starts first', () => {
+    expect(fuzzyScore('lr', 'LedgerRepository')).not.toBeNull();
+    expect(fuzzyScore('xz', 'LedgerRepository')).toBeNull();
+    expect(fuzzyScore('cs', 'CheckoutService')!).toBeGreaterThan(
+      fuzzyScore('cs', 'checks')!,
+    );
+  });
+
+  it('filters by any field and keeps everything for an empty query', () => {
+    const fields = (entity: StoredEntity) => [entity.name, ...entity.tags];
+    expect(fuzzyFilter([checkout, ledger], 'billing', fields)).toEqual([
+      ledger,
+    ]);
+    expect(fuzzyFilter([checkout, ledger], ' ', fields)).toEqual([
+      checkout,
+      ledger,
+    ]);
+  });
+});
+
+describe('browser state', () => {
+  const initial = createBrowserState([checkout, ledger], graph);
+
+  it('filters the list as the query is typed', () => {
+    const state = press(initial, { text: 'led' });
+    expect(state.matches.map((e) => e.name)).toEqual(['LedgerRepository']);
+    const cleared = press(state, 'backspace', 'backspace', 'backspace');
+    expect(cleared.matches).toHaveLength(2);
+    expect(press(state, 'back').query).toBe('');
+  });
+
+  it('shows dependencies and follows edges to other entities', () => {
+    const detail = press(initial, 'enter');
+    expect(detail.detail?.entity.name).toBe('CheckoutService');
+    expect(detail.detail?.edges.map((e) => e.node.name)).toEqual([
+      'LedgerRepository',
+      'stripe',
+    ]);
+
+    const followed = press(detail, 'enter');
+    expect(followed.detail?.entity.name).toBe('LedgerRepository');
+    expect(followed.detail?.edges).toEqual([
+      expect.objectContaining({ direction: 'in', kind: 'service' }),
+    ]);
+    expect(press(followed, 'back').detail?.entity.name).toBe('CheckoutService');
+    expect(press(followed, 'back', 'back').detail).toBeUndefined();
+  });
+
+  it('does not follow edges to external dependencies', () => {
+    const state = press(initial, 'enter', 'down', 'enter');
+    expect(state.detail?.entity.name).toBe('CheckoutService');
+  });
+
+  it('asks to open the selected entity in the editor', () => {
+    expect(updateBrowser(press(initial, 'down'), 'open').effect).toEqual({
+      type: 'open',
+      filePath: 'src/ledger.ts',
+      line: 3,
+    });
+  });
+
+  it('quits on back with an empty query', () => {
+    expect(updateBrowser(initial, 'back').effect).toEqual({ type: 'quit' });
+  });
+
+  it('renders the list and the detail view', () => {
+    const list = renderBrowser(press(initial, { text: 'check' }), viewport);
+    expect(list).toContain('1 of 2 entities');
+    expect(list).toContain('CheckoutService  service  src/checkout.ts:12');
+    expect(list.split('\n')).toHaveLength(viewport.rows);
+
+    const detail = renderBrowser(press(initial, 'enter'), viewport);
+    expect(detail).toContain('Depends on 2, used by 0');
+    expect(detail).toContain('-> stripe  external_api  external');
+  });
+
+  it('shows description references as the names they link to', () => {
+    const posting = createEntity({
+      description: 'Posts each order to [[LedgerRepository]] when paid',
+    });
+    const state = createBrowserState([posting, ledger], {
+      ...graph,
+      edges: [{ from: 'checkout', to: 'ledger', kind: 'reference' }],
+    });
+    const detail = renderBrowser(press(state, 'enter'), viewport);
+    expect(detail).toContain('Posts each order to LedgerRepository when paid');
+    expect(detail).toContain('-> LedgerRepository  reference  src/ledger.ts');
+    expect(press(state, 'enter', 'enter').detail?.entity.name).toBe(
+      'LedgerRepository',
+    );
+  });
+});
+
+describe('browser views', () => {
+  it('lists the entities and columns of the view', () => {
+    const oncall = createEntity({
+      metadata: {
+        type: 'service',
+        description: 'Runs checkout for a cart',
+        operational: { on_call_team: 'checkout-oncall', sla: '99.9%' },
+      },
+    });
+    const state = createBrowserState(
+      [oncall, ledger],
+      graph,
+      BUILTIN_VIEWS.sre,
+    );
+    expect(state.entities.map((e) => e.name)).toEqual(['CheckoutService']);
+    const list = renderBrowser(state, viewport);
+    expect(list).toContain('CheckoutService  service  checkout-oncall  99.9%');
+
+    const detail = renderBrowser(press(state, 'enter'), viewport);
+    expect(detail).toContain('operational: {"on_call_team":"checkout-oncall"');
+    expect(detail).toContain('slo: -');
+  });
+});
+
+describe('browse command', () => {
+  it('registers the browse command', () => {
+    const program = new Command();
+    registerBrowseCommand(program);
+    expect(program.commands.find((c) => c.name() === 'browse')).toBeDefined();
+  });
+
+  it('builds the editor command line for the editor in use', () => {
+    expect(editorCommand('vim', 'src/a.ts', 7)).toEqual([
+      'vim',
+      '+7',
+      'src/a.ts',
+    ]);
+    expect(editorCommand('code --wait', 'src/a.ts', 7)).toEqual([
+      'code',
+      '--wait',
+      '--goto',
+      'src/a.ts:7',
+    ]);
+    expect(editorCommand('/usr/bin/subl', 'src/a.ts', 7)).toEqual([
+      '/usr/bin/subl',
+      'src/a.ts:7',
+    ]);
+  });
+
+  it('maps keypresses to browser keys', () => {
+    expect(toBrowserKey(undefined, { name: 'up' })).toBe('up');
+    expect(toBrowserKey('\r', { name: 'return' })).toBe('enter');
+    expect(toBrowserKey('\x0f', { name: 'o', ctrl: true })).toBe('open');
+    expect(toBrowserKey('\x03', { name: 'c', ctrl: true })).toBe('quit');
+    expect(toBrowserKey('a', { name: 'a' })).toEqual({ text: 'a' });
+    expect(toBrowserKey('\x1b[Z', { name: 'undefined' })).toBeUndefined();
+  });
+});
@@ -0,0 +1,198 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { deflateRawSync, inflateRawSync } from 'node:zlib';
+import { Command } from 'commander';
+import {
+  BUNDLE_PATHS,
+  createDatabaseManager,
+  createQueryEngine,
+  encodeBundle,
+  readAuditLog,
+} from '@know-graph/core';
+import { registerBundleCommand } from '../commands/bundle.js';
+
+describe('bundle command', () => {
+  let dir: string;
+  let dbPath: string;
+  let configPath: string;
+  let bundlePath: string;
+  let logSpy: ReturnType<typeof vi.spyOn>;
+  let errorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-bundle-'));
+    mkdirSync(join(dir, 'source'));
+    dbPath = join(dir, 'source', 'knowgraph.db');
+    configPath = join(dir, 'source', '.knowgraph.yml');
+    bundlePath = join(dir, 'shop.kgb');
+    writeFileSync(configPath, "version: '1.0'\nnamespace: acme/shop\n");
+    const dbManager = createDatabaseManager(dbPath);
+    dbManager.initialize();
+    dbManager.insertEntity({
+      filePath: 'src/payments.ts',
+      name: 'PaymentService',
+      entityType: 'service',
+      description: 'Charges cards',
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      metadata: { type: 'service', description: 'Charges cards' },
+    });
+    dbManager.close();
+    logSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    logSpy.mockRestore();
+    errorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  async function run(...args: string[]): Promise<void> {
+    const program = new Command();
+    registerBundleCommand(program);
+    await program.parseAsync(['node', 'knowgraph', 'bundle', ...args]);
+  }
+
+  async function exportBundle(): Promise<void> {
+    await run(
+      'export',
+      '--db',
+      dbPath,
+      '--config',
+      configPath,
+      '--output',
+      bundlePath,
+    );
+  }
+
+  it('carries an index across to another instance', async () => {
+    await exportBundle();
+    expect(process.exitCode).toBeUndefined();
+    expect(String(logSpy.mock.calls[0][0])).toContain('Bundled 4 file(s)');
+
+    const target = join(dir, 'target', 'knowgraph.db');
+    const outputDir = join(dir, 'target', 'bundle');
+    await run(
+      'import',
+      bundlePath,
+      '--db',
+      target,
+      '--config',
+      join(dir, 'target', '.knowgraph.yml'),
+      '--output-dir',
+      outputDir,
+      '--format',
+      'json',
+    );
+    expect(process.exitCode).toBeUndefined();
+    const result = JSON.parse(String(logSpy.mock.calls[1][0]));
+    expect(result.header.namespace).toBe('acme/shop');
+    expect(result.problems).toEqual([]);
+
+    const dbManager = createDatabaseManager(target);
+    try {
+      const names = createQueryEngine(dbManager)
+        .getAll()
+        .map((entity) => entity.name);
+      expect(names).toEqual(['PaymentService']);
+    } finally {
+      dbManager.close();
+    }
+    expect(readFileSync(join(outputDir, 'CODEBASE.md'), 'utf-8')).toContain(
+      'PaymentService',
+    );
+    expect(existsSync(join(outputDir, 'graph.kgs'))).toBe(true);
+    expect(readFileSync(join(outputDir, '.knowgraph.yml'), 'utf-8')).toContain(
+      'acme/shop',
+    );
+    const [entry] = readAuditLog(
+      join(dir, 'target', '.knowgraph', 'audit.jsonl'),
+    );
+    expect(entry?.command).toBe('bundle import');
+    expect(entry?.changes.map((change) => change.summary)).toEqual([
+      'created from the bundle',
+      '1 added, 0 updated, 0 removed',
+      'created',
+      'created',
+      'created',
+    ]);
+  });
+
+  it('plans a forced import without writing under --dry-run', async () => {
+    await exportBundle();
+    const before = readFileSync(dbPath);
+    await run(
+      'import',
+      bundlePath,
+      '--db',
+      dbPath,
+      '--config',
+      configPath,
+      '--output-dir',
+      join(dir, 'bundle'),
+      '--force',
+      '--dry-run',
+      '--format',
+      'json',
+    );
+    expect(process.exitCode).toBeUndefined();
+    const plan = JSON.parse(String(logSpy.mock.calls[1][0]));
+    expect(plan.command).toBe('bundle import');
+    expect(plan.changes[0]).toEqual({
+      target: dbPath,
+      summary: 'replaced from the bundle',
+    });
+    expect(plan.changes[1].summary).toBe('0 added, 0 updated, 0 removed');
+    expect(readFileSync(dbPath)).toEqual(before);
+    expect(existsSync(join(dir, 'bundle'))).toBe(false);
+  });
+
+  it('keeps an existing database unless --force is given', async () => {
+    await exportBundle();
+    await run('import', bundlePath, '--db', dbPath);
+    expect(process.exitCode).toBe(2);
+    expect(String(errorSpy.mock.calls[0][0])).toContain('already exists');
+  });
+
+  it('refuses a bundle from a newer release with exit 4', async () => {
+    const bytes = encodeBundle([
+      { path: BUNDLE_PATHS.index, bytes: Buffer.from('index') },
+    ]);
+    // Rewrite the header as a later release would write it
+    const body = inflateRawSync(bytes.subarray(4));
+    const headerEnd = 4 + body.readUInt32BE(0);
+    const header = JSON.parse(body.subarray(4, headerEnd).toString('utf-8'));
+    header.schemas.snapshot += 1;
+    const json = Buffer.from(JSON.stringify(header), 'utf-8');
+    const length = Buffer.alloc(4);
+    length.writeUInt32BE(json.length);
+    const newer = Buffer.concat([length, json, body.subarray(headerEnd)]);
+    writeFileSync(
+      bundlePath,
+      Buffer.concat([bytes.subarray(0, 4), deflateRawSync(newer)]),
+    );
+
+    const target = join(dir, 'target.db');
+    await run('import', bundlePath, '--db', target);
+    expect(process.exitCode).toBe(4);
+    expect(String(errorSpy.mock.calls[0][0])).toContain(
+      'The graph snapshot has format version',
+    );
+    expect(existsSync(target)).toBe(false);
+  });
+});
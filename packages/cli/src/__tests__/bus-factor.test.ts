@@ -0,0 +1,106 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { Command } from 'commander';
+import type { BusFactorEntry, BusFactorReport } from '@know-graph/core';
+import {
+  formatBusFactorReport,
+  registerBusFactorCommand,
+} from '../commands/bus-factor.js';
+
+function makeEntry(overrides: Partial<BusFactorEntry> = {}): BusFactorEntry {
+  return {
+    entityId: 'checkout',
+    name: 'CheckoutService',
+    entityType: 'service',
+    filePath: 'src/checkout.ts',
+    owner: 'payments-team',
+    revenueImpact: 'critical',
+    dependents: 3,
+    busFactor: 1,
+    topContributor: 'ada@example.com',
+    topShare: 0.9,
+    recentCommits: 20,
+    contributors: [],
+    critical: true,
+    atRisk: true,
+    ...overrides,
+  };
+}
+
+const report: BusFactorReport = {
+  entries: [
+    makeEntry(),
+    makeEntry({
+      name: 'Ledger',
+      filePath: 'src/ledger.ts',
+      revenueImpact: null,
+      dependents: 8,
+      busFactor: 2,
+      topShare: 0.5,
+      atRisk: false,
+    }),
+    makeEntry({
+      name: 'Docs',
+      revenueImpact: 'none',
+      critical: false,
+      atRisk: false,
+    }),
+  ],
+  atRisk: 1,
+  unknown: 2,
+};
+
+describe('bus-factor command', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerBusFactorCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'bus-factor', ...args]);
+  }
+
+  it('lists at-risk entities, then the other critical ones', () => {
+    const output = formatBusFactorReport(report);
+    expect(output).toContain('1 critical entities are maintained by one');
+    expect(output).toContain(
+      'src/checkout.ts (revenue critical, 3 dependents)',
+    );
+    expect(output).toContain(
+      'bus factor 1, ada@example.com made 90% of 20 recent commits',
+    );
+    expect(output).toContain('Other critical entities');
+    expect(output).toContain('Ledger');
+    expect(output).not.toContain('Docs');
+    expect(output).toContain('2 entities have no git data');
+  });
+
+  it('lists every entity with --all', () => {
+    expect(formatBusFactorReport(report, true)).toContain('Docs');
+  });
+
+  it('explains how to get contributor data when there is none', () => {
+    const empty = { entries: [], atRisk: 0, unknown: 3 };
+    expect(formatBusFactorReport(empty)).toContain('git enricher');
+  });
+
+  it('rejects an out-of-range coverage as a usage error', async () => {
+    await run('--coverage', '1.5');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    await run('--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+});
@@ -0,0 +1,116 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerChangeCostCommand } from '../commands/change-cost.js';
+import { indexInto } from '../utils/indexing.js';
+
+function service(
+  name: string,
+  owner: string,
+  domain: string,
+  extra: readonly string[] = [],
+): string {
+  return [
+    '"""',
+    '@knowgraph',
+    'type: service',
+    `description: The ${name} service`,
+    `owner: ${owner}`,
+    'context:',
+    `  domain: ${domain}`,
+    ...extra,
+    '"""',
+    `class ${name}:`,
+    '    pass',
+    '',
+  ].join('\n');
+}
+
+describe('change-cost command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-change-cost-'));
+    mkdirSync(join(dir, 'src'));
+    writeFileSync(
+      join(dir, 'src', 'checkout.py'),
+      service('Checkout', 'team-payments', 'payments', [
+        'compliance:',
+        '  regulations: [PCI-DSS]',
+      ]),
+    );
+    writeFileSync(
+      join(dir, 'src', 'cart.py'),
+      service('Cart', 'team-web', 'storefront', [
+        'dependencies:',
+        '  services: [Checkout]',
+      ]),
+    );
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerChangeCostCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'change-cost',
+      '--db',
+      dbPath,
+      ...args,
+    ]);
+  }
+
+  function output(): string {
+    return consoleLogSpy.mock.calls.map((call) => String(call[0])).join('\n');
+  }
+
+  it('scores a file by teams, cross-domain edges, and reviews', async () => {
+    await run('src/checkout.py', '--format', 'json');
+    const report = JSON.parse(output());
+    expect(report.teams.map((team: { team: string }) => team.team)).toEqual([
+      'team-payments',
+      'team-web',
+    ]);
+    expect(report.breakdown).toEqual({
+      teams: 6,
+      crossDomainEdges: 1,
+      complianceReviews: 5,
+    });
+    expect(report.score).toBe(12);
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('prints the breakdown and fails above --max-score', async () => {
+    await run('Checkout', 'src/missing.py', '--max-score', '10');
+    expect(output()).toContain('Change cost: 12');
+    expect(output()).toContain('PCI-DSS');
+    expect(output()).toContain('Matched no entity: src/missing.py');
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('needs a change set that matches an entity', async () => {
+    await run('src/missing.py');
+    expect(process.exitCode).toBe(2);
+  });
+});
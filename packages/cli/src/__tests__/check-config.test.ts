@@ -0,0 +1,112 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerCheckConfigCommand } from '../commands/check-config.js';
+import { indexInto } from '../utils/indexing.js';
+
+const SERVICE = [
+  'class Payments:',
+  '    """',
+  '    @knowgraph',
+  '    type: service',
+  '    description: Takes payments',
+  '    owner: team-payments',
+  '    dependencies:',
+  '      services: [ledger]',
+  '      databases: [payments-db]',
+  '    """',
+  '',
+].join('\n');
+
+const VALUES = [
+  'env:',
+  '  LEDGER_URL: http://ledger:8080',
+  '  FRAUD_URL: http://fraud-check:8080',
+  '',
+].join('\n');
+
+describe('check-config command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-check-config-'));
+    mkdirSync(join(dir, 'src'));
+    writeFileSync(join(dir, 'src', 'payments.py'), SERVICE);
+    mkdirSync(join(dir, 'chart'));
+    writeFileSync(join(dir, 'chart', 'values.yaml'), VALUES);
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerCheckConfigCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'check-config',
+      '--db',
+      dbPath,
+      '--config',
+      join(dir, '.knowgraph.yml'),
+      ...args,
+    ]);
+  }
+
+  function output(): string {
+    return consoleLogSpy.mock.calls.map((call) => String(call[0])).join('\n');
+  }
+
+  it('reports mismatches in both directions and fails', async () => {
+    await run(join(dir, 'chart', 'values.yaml'), '--service', 'Payments');
+    expect(output()).toContain('Declared, but not configured:');
+    expect(output()).toContain('payments-db');
+    expect(output()).toContain('Configured, but not declared:');
+    expect(output()).toContain('fraud-check');
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('reads services from runtime_config in the manifest', async () => {
+    writeFileSync(
+      join(dir, '.knowgraph.yml'),
+      [
+        'version: "1.0"',
+        'runtime_config:',
+        '  services:',
+        '    - service: Payments',
+        '      files: [chart/values.yaml]',
+        '  ignore: [FRAUD_URL]',
+        '',
+      ].join('\n'),
+    );
+    await run('--format', 'json');
+    const [report] = JSON.parse(output());
+    expect(report.undeclared).toEqual([]);
+    expect(report.unconfigured).toEqual([
+      { kind: 'database', name: 'payments-db' },
+    ]);
+  });
+
+  it('needs --service with files', async () => {
+    await run(join(dir, 'chart', 'values.yaml'));
+    expect(process.exitCode).toBe(2);
+  });
+});
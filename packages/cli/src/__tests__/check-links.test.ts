@@ -0,0 +1,82 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import { Command } from 'commander';
+import type { DeadLinkReport } from '@know-graph/core';
+import {
+  formatDeadLinkReport,
+  registerCheckLinksCommand,
+} from '../commands/check-links.js';
+
+describe('check-links command', () => {
+  afterEach(() => {
+    vi.restoreAllMocks();
+    process.exitCode = undefined;
+  });
+
+  it('registers the check-links command', () => {
+    const program = new Command();
+    registerCheckLinksCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'check-links');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toContain('--header');
+  });
+
+  it('rejects a bad timeout or concurrency as a usage error', async () => {
+    const errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    for (const [flag, value] of [
+      ['--timeout', 'soon'],
+      ['--concurrency', '0'],
+    ]) {
+      const program = new Command();
+      registerCheckLinksCommand(program);
+      const args = ['check-links', flag, value];
+      await program.parseAsync(['node', 'knowgraph', ...args]);
+      expect(process.exitCode).toBe(2);
+      expect(String(errorSpy.mock.calls.at(-1)?.[0])).toContain(
+        `${flag} must be a positive integer`,
+      );
+    }
+  });
+
+  it('groups dead links by team', () => {
+    const report: DeadLinkReport = {
+      checked: 3,
+      dead: 1,
+      groups: [
+        {
+          owner: 'payments-team',
+          deadLinks: [
+            {
+              ok: false,
+              status: 404,
+              error: 'HTTP 404 Not Found',
+              link: {
+                entityId: 'a',
+                name: 'checkout',
+                filePath: 'src/checkout.ts',
+                owner: 'payments-team',
+                kind: 'runbook',
+                url: 'https://wiki/runbooks/checkout',
+              },
+            },
+          ],
+        },
+      ],
+    };
+    const output = formatDeadLinkReport(report);
+    expect(output).toContain('Dead operational links: 1 of 3');
+    expect(output).toContain('payments-team');
+    expect(output).toContain('https://wiki/runbooks/checkout');
+    expect(output).toContain('HTTP 404 Not Found');
+  });
+
+  it('reports when all links resolve', () => {
+    const output = formatDeadLinkReport({ checked: 2, dead: 0, groups: [] });
+    expect(output).toContain('All 2 operational links resolve.');
+  });
+
+  it('reports when there are no operational links', () => {
+    expect(formatDeadLinkReport({ checked: 0, dead: 0, groups: [] })).toBe(
+      'No runbook or dashboard links found.',
+    );
+  });
+});
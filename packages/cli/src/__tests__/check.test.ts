@@ -0,0 +1,446 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join, resolve } from 'node:path';
+import { Command } from 'commander';
+import { checkCycleBudgets, findDependencyCycles } from '@know-graph/core';
+import type {
+  DependencyGraph,
+  FreshnessEntry,
+  LintResult,
+  ValidationResult,
+} from '@know-graph/core';
+import {
+  apiFindings,
+  combineCheckResults,
+  cycleFindings,
+  formatCheckAnnotations,
+  formatCheckSummary,
+  freshnessFindings,
+  registerCheckCommand,
+} from '../commands/check.js';
+import { formatWorkflowAnnotation, writeStepSummary } from '../utils/github.js';
+import {
+  formatJUnitReport,
+  toCodeQualityIssues,
+} from '../utils/ci-reports.js';
+import { compareWithBaseline, createBaseline } from '../utils/baseline.js';
+
+const FIXTURES_DIR = resolve(__dirname, 'fixtures');
+
+const validation: ValidationResult = {
+  issues: [
+    {
+      filePath: '/repo/src/pay.ts',
+      line: 12,
+      rule: 'required-fields',
+      message: 'Missing "owner"',
+      severity: 'error',
+    },
+  ],
+  fileCount: 2,
+  errorCount: 1,
+  warningCount: 0,
+  infoCount: 0,
+  isValid: false,
+  suppressed: [
+    {
+      issue: {
+        filePath: '/repo/src/legacy.ts',
+        line: 8,
+        rule: 'owner-present',
+        message: 'Missing "owner"',
+        severity: 'warning',
+      },
+      reason: 'Owner decided in Q3',
+    },
+  ],
+};
+
+const lint: LintResult = {
+  issues: [
+    {
+      filePath: '/repo/src/auth.ts',
+      line: 3,
+      rule: 'short-description',
+      message: 'Description is shorter than 10 characters',
+    },
+  ],
+  fileCount: 2,
+  fixableCount: 0,
+  fixedCount: 0,
+  fixedFiles: [],
+  suppressed: [],
+};
+
+const result = combineCheckResults(validation, lint, '/repo');
+
+describe('check results', () => {
+  it('combines validation and lint findings in file order', () => {
+    expect(result.findings.map((f) => [f.filePath, f.severity])).toEqual([
+      ['src/auth.ts', 'warning'],
+      ['src/pay.ts', 'error'],
+    ]);
+    expect(result).toMatchObject({
+      fileCount: 2,
+      errorCount: 1,
+      warningCount: 1,
+    });
+  });
+
+  it('applies configured severities to lint findings', () => {
+    const configured = combineCheckResults(validation, lint, '/repo', {
+      'short-description': 'info',
+    });
+    expect(configured).toMatchObject({ warningCount: 0, infoCount: 1 });
+    expect(formatCheckAnnotations(configured).split('\n')[0]).toMatch(
+      /^::notice file=src\/auth\.ts/,
+    );
+    const off = combineCheckResults(validation, lint, '/repo', {
+      'short-description': 'off',
+    });
+    expect(off.findings.map((f) => f.rule)).toEqual(['required-fields']);
+  });
+
+  it('emits a workflow command per finding', () => {
+    expect(formatCheckAnnotations(result).split('\n')).toEqual([
+      '::warning file=src/auth.ts,line=3,title=knowgraph lint%3A short-description::Description is shorter than 10 characters',
+      '::error file=src/pay.ts,line=12,title=knowgraph validate%3A required-fields::Missing "owner"',
+    ]);
+  });
+
+  it('escapes workflow command data and properties', () => {
+    expect(
+      formatWorkflowAnnotation({
+        level: 'notice',
+        file: 'src/a,b.ts',
+        line: 1,
+        title: 'x',
+        message: '100% done\nnext line',
+      }),
+    ).toBe(
+      '::notice file=src/a%2Cb.ts,line=1,title=x::100%25 done%0Anext line',
+    );
+  });
+
+  it('summarizes the findings as a Markdown table', () => {
+    const summary = formatCheckSummary(result);
+    expect(summary).toContain(
+      '**1 error(s), 1 warning(s) in 2 file(s) (1 suppressed)**',
+    );
+    expect(summary).toContain(
+      '| error | `src/pay.ts:12` | required-fields | Missing "owner" |',
+    );
+    expect(summary).toContain(
+      '| `src/legacy.ts:8` | owner-present | Owner decided in Q3 |',
+    );
+  });
+});
+
+describe('CI reports', () => {
+  it('maps findings to GitLab Code Quality issues', () => {
+    const issues = toCodeQualityIssues(result.findings);
+    expect(issues[1]).toMatchObject({
+      description: 'Missing "owner"',
+      check_name: 'validate/required-fields',
+      severity: 'major',
+      location: { path: 'src/pay.ts', lines: { begin: 12 } },
+    });
+    expect(issues[0].severity).toBe('minor');
+    expect(issues[0].fingerprint).toMatch(/^[0-9a-f]{32}$/);
+    expect(toCodeQualityIssues(result.findings)[0].fingerprint).toBe(
+      issues[0].fingerprint,
+    );
+    expect(issues[0].fingerprint).not.toBe(issues[1].fingerprint);
+  });
+
+  it('reports errors as JUnit failures and warnings only under strict', () => {
+    const report = formatJUnitReport(result.findings, { name: 'knowgraph' });
+    expect(report).toContain(
+      '<testsuite name="knowgraph" tests="2" failures="1" errors="0">',
+    );
+    expect(report).toContain(
+      '<failure type="error" message="Missing &quot;owner&quot;">',
+    );
+    expect(report).toContain(
+      '<system-out>src/auth.ts:3 Description is shorter',
+    );
+    const strict = formatJUnitReport(result.findings, {
+      name: 'knowgraph',
+      strict: true,
+    });
+    expect(strict).toContain('failures="2"');
+  });
+
+  it('reports a clean run as one passing JUnit test case', () => {
+    const report = formatJUnitReport([], { name: 'knowgraph' });
+    expect(report).toContain('tests="1" failures="0"');
+    expect(report).toContain('<testcase classname="knowgraph"');
+  });
+});
+
+describe('baseline', () => {
+  it('ignores recorded findings even after they move', () => {
+    const baseline = createBaseline(result.findings);
+    expect(baseline.entries.map((e) => e.filePath)).toEqual([
+      'src/auth.ts',
+      'src/pay.ts',
+    ]);
+    const moved = result.findings.map((f) => ({ ...f, line: f.line + 5 }));
+    expect(compareWithBaseline(moved, baseline)).toEqual({
+      fresh: [],
+      baselinedCount: 2,
+      fixedCount: 0,
+    });
+  });
+
+  it('reports new findings and counts fixed ones', () => {
+    const baseline = createBaseline(result.findings.slice(0, 1));
+    const duplicate = { ...result.findings[0], line: 40 };
+    const comparison = compareWithBaseline(
+      [result.findings[0], duplicate, result.findings[1]],
+      baseline,
+    );
+    expect(comparison.fresh).toEqual([duplicate, result.findings[1]]);
+    expect(comparison.baselinedCount).toBe(1);
+    expect(compareWithBaseline([], baseline).fixedCount).toBe(1);
+  });
+
+  it('follows baselined files into the files they were split into', () => {
+    const baseline = createBaseline(result.findings);
+    const split = result.findings.map((f) =>
+      f.filePath === 'src/pay.ts' ? { ...f, filePath: 'src/charge.ts' } : f,
+    );
+    expect(compareWithBaseline(split, baseline).fresh).toHaveLength(1);
+    const comparison = compareWithBaseline(split, baseline, {
+      'src/pay.ts': ['src/charge.ts', 'src/refund.ts'],
+    });
+    expect(comparison.fresh).toEqual([]);
+    expect(comparison.fixedCount).toBe(0);
+  });
+});
+
+describe('cycle budgets', () => {
+  const node = (name: string, domain: string) => ({
+    id: `id-${name}`,
+    name,
+    entityType: 'service' as const,
+    external: false,
+    filePath: `src/${name}.ts`,
+    owner: null,
+    domain,
+    workspace: null,
+  });
+  const edge = (from: string, to: string) => ({
+    from: `id-${from}`,
+    to: `id-${to}`,
+    kind: 'service' as const,
+    provenance: 'declared' as const,
+    confidence: 1,
+  });
+  const graph: DependencyGraph = {
+    nodes: [node('cart', 'ordering'), node('checkout', 'ordering')],
+    edges: [edge('cart', 'checkout'), edge('checkout', 'cart')],
+  };
+  const cycles = findDependencyCycles(graph);
+
+  it('fails a new cycle over budget and names the edge to remove', () => {
+    const [finding] = cycleFindings(
+      checkCycleBudgets(cycles),
+      new Map([['id-cart', 12]]),
+    );
+    expect(finding).toEqual({
+      source: 'cycles',
+      severity: 'error',
+      filePath: 'src/cart.ts',
+      line: 12,
+      rule: 'dependency-cycle',
+      message:
+        'Dependency cycle among cart, checkout (ordering: 1 new, budget 0). Remove checkout -> cart to break it',
+    });
+  });
+
+  it('reports cycles within budget as info and omits baselined ones', () => {
+    const within = checkCycleBudgets(cycles, { defaultBudget: 1 });
+    expect(cycleFindings(within).map((f) => f.severity)).toEqual(['info']);
+
+    const baseline = createBaseline(
+      [],
+      [['src/cart.ts:cart', 'src/checkout.ts:checkout']],
+    );
+    const known = checkCycleBudgets(cycles, { baseline: baseline.cycles });
+    expect(cycleFindings(known)).toEqual([]);
+    expect(createBaseline([]).cycles).toBeUndefined();
+  });
+});
+
+describe('API stability', () => {
+  it('reports breaking changes to stable packages at their annotation', () => {
+    const change = {
+      importPath: 'example.com/app/pkg/client',
+      dir: 'pkg/client',
+      symbol: 'New',
+      kind: 'func' as const,
+      change: 'removed' as const,
+      before: 'func New(Options) *Client',
+      after: null,
+      breaking: true,
+    };
+    const findings = apiFindings([
+      change,
+      { ...change, symbol: 'Dial', change: 'added', breaking: false },
+    ]);
+    expect(findings).toEqual([
+      {
+        source: 'api',
+        severity: 'error',
+        filePath: 'pkg/client',
+        line: 1,
+        rule: 'api-breaking-change',
+        message:
+          'New in stable package example.com/app/pkg/client removed (was func New(Options) *Client)',
+      },
+    ]);
+  });
+});
+
+describe('review deadlines', () => {
+  it('fails overdue reviews and notes those due soon', () => {
+    const overdue: FreshnessEntry = {
+      entityId: 'checkout',
+      name: 'CheckoutService',
+      entityType: 'service',
+      filePath: 'src/checkout.ts',
+      line: 4,
+      owner: 'payments-team',
+      revenueImpact: 'critical',
+      months: 3,
+      reviewedAt: null,
+      reviewedFrom: null,
+      dueAt: null,
+      daysLeft: null,
+      state: 'overdue',
+    };
+    const findings = freshnessFindings({
+      generatedAt: '2024-06-15T00:00:00.000Z',
+      entries: [
+        overdue,
+        { ...overdue, dueAt: '2024-07-01', daysLeft: 16, state: 'due-soon' },
+        { ...overdue, dueAt: '2024-11-01', daysLeft: 139, state: 'current' },
+      ],
+      overdue: 1,
+      dueSoon: 1,
+    });
+    expect(findings.map((f) => [f.severity, f.rule])).toEqual([
+      ['error', 'review-overdue'],
+      ['info', 'review-due-soon'],
+    ]);
+    expect(findings[0].message).toBe(
+      'CheckoutService (revenue impact critical) must be reviewed every 3 months: never reviewed. Review the annotation and set last_reviewed',
+    );
+  });
+});
+
+describe('writeStepSummary', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-summary-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('appends to GITHUB_STEP_SUMMARY when it is set', () => {
+    const path = join(dir, 'summary.md');
+    expect(writeStepSummary('# One', { GITHUB_STEP_SUMMARY: path })).toBe(
+      true,
+    );
+    writeStepSummary('# Two\n', { GITHUB_STEP_SUMMARY: path });
+    expect(readFileSync(path, 'utf-8')).toBe('# One\n# Two\n');
+    expect(writeStepSummary('# Three', {})).toBe(false);
+  });
+});
+
+describe('check command', () => {
+  let logSpy: ReturnType<typeof vi.spyOn>;
+  let errorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    logSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    logSpy.mockRestore();
+    errorSpy.mockRestore();
+    vi.unstubAllEnvs();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerCheckCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'check', ...args]);
+  }
+
+  it('passes a valid fixture and prints the summary line', async () => {
+    vi.stubEnv('GITHUB_STEP_SUMMARY', '');
+    const sample = resolve(FIXTURES_DIR, 'sample.ts');
+    await run(sample, '--format', 'github-annotations');
+    expect(process.exitCode).toBeUndefined();
+    expect(logSpy).toHaveBeenCalledWith(
+      expect.stringMatching(/0 error\(s\), \d+ warning\(s\) in \d+ file\(s\)/),
+    );
+  });
+
+  it('prints a GitLab Code Quality report', async () => {
+    const sample = resolve(FIXTURES_DIR, 'sample.ts');
+    await run(sample, '--format', 'gitlab-codequality');
+    expect(Array.isArray(JSON.parse(logSpy.mock.calls[0][0]))).toBe(true);
+  });
+
+  it('records a baseline and then passes against it', async () => {
+    const dir = mkdtempSync(join(tmpdir(), 'kg-baseline-'));
+    try {
+      const path = join(dir, 'baseline.json');
+      await run(FIXTURES_DIR, '--baseline', path, '--update-baseline');
+      expect(process.exitCode).toBeUndefined();
+      expect(JSON.parse(readFileSync(path, 'utf-8')).version).toBe(1);
+
+      await run(FIXTURES_DIR, '--baseline', path, '--strict');
+      expect(process.exitCode).toBeUndefined();
+      expect(errorSpy).toHaveBeenCalledWith(
+        expect.stringContaining('ignored by the baseline'),
+      );
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  it('fails with an I/O error for a missing baseline', async () => {
+    const path = join(tmpdir(), 'kg-missing-baseline.json');
+    await run(FIXTURES_DIR, '--baseline', path);
+    expect(process.exitCode).toBe(5);
+    expect(existsSync(path)).toBe(false);
+  });
+
+  it('needs --baseline for --update-baseline', async () => {
+    await run(FIXTURES_DIR, '--update-baseline');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('rejects an unknown format as a usage error', async () => {
+    await run(FIXTURES_DIR, '--format', 'sarif');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error for a missing path', async () => {
+    await run('/nonexistent/path');
+    expect(process.exitCode).toBe(5);
+  });
+});
@@ -0,0 +1,111 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import type { DependencyGraph, GraphNode } from '@know-graph/core';
+import { registerClustersCommand } from '../commands/clusters.js';
+
+function node(
+  name: string,
+  filePath: string,
+  overrides: Partial<GraphNode> = {},
+): GraphNode {
+  return {
+    id: name,
+    name,
+    entityType: 'class',
+    external: false,
+    filePath,
+    owner: null,
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+const graph: DependencyGraph = {
+  nodes: [
+    node('billing', 'src/billing/index.ts', { entityType: 'module' }),
+    node('Invoice', 'src/billing/invoice.ts'),
+    node('InvoiceRepository', 'src/billing/repository.ts'),
+    node('auth', 'src/auth/index.ts', { entityType: 'module' }),
+    node('InvoiceToken', 'src/auth/tokens.ts'),
+  ],
+  edges: [
+    {
+      from: 'Invoice',
+      to: 'InvoiceRepository',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    },
+    {
+      from: 'Invoice',
+      to: 'InvoiceToken',
+      kind: 'service',
+      provenance: 'call',
+      confidence: 0.9,
+    },
+    {
+      from: 'InvoiceToken',
+      to: 'InvoiceRepository',
+      kind: 'service',
+      provenance: 'call',
+      confidence: 0.9,
+    },
+  ],
+};
+
+describe('clusters command', () => {
+  let dir: string;
+  let graphPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-clusters-'));
+    graphPath = join(dir, 'graph.json');
+    writeFileSync(graphPath, JSON.stringify(graph));
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerClustersCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'clusters', ...args]);
+  }
+
+  function output(): string {
+    return consoleLogSpy.mock.calls.map((call) => String(call[0])).join('\n');
+  }
+
+  it('suggests moving members into the module most of them live in', async () => {
+    await run(graphPath);
+    expect(output()).toContain('3 entities across billing, auth');
+    expect(output()).toContain('Move InvoiceToken into billing');
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('clusters only the edges the filter keeps', async () => {
+    await run(graphPath, '--provenance', 'declared', '--format', 'json');
+    const report = JSON.parse(output());
+    expect(report.suggestions).toEqual([]);
+  });
+
+  it('rejects a --min-size below 2', async () => {
+    await run(graphPath, '--min-size', '1');
+    expect(process.exitCode).toBe(2);
+  });
+});
@@ -0,0 +1,139 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerCodegenCommand } from '../commands/codegen.js';
+import { indexInto } from '../utils/indexing.js';
+
+function service(name: string, fields: readonly string[]): string {
+  return [
+    `class ${name}:`,
+    '    """',
+    '    @knowgraph',
+    '    type: service',
+    `    description: The ${name} service`,
+    '    owner: platform-team',
+    '    status: stable',
+    ...fields.map((field) => `    ${field}`),
+    '    """',
+    '',
+  ].join('\n');
+}
+
+const TOKENS = `
+openapi: 3.0.3
+paths:
+  /tokens:
+    post:
+      operationId: issueToken
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                subject: { type: string }
+      responses:
+        '201':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  token: { type: string }
+`;
+
+describe('codegen command', () => {
+  let dir: string;
+  let dbPath: string;
+  let output: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-codegen-'));
+    mkdirSync(join(dir, 'api'));
+    writeFileSync(join(dir, 'api', 'tokens.yaml'), TOKENS);
+    writeFileSync(
+      join(dir, 'login.py'),
+      service('LoginService', [
+        'dependencies:',
+        '  services: [TokenService, AuditService]',
+      ]),
+    );
+    writeFileSync(
+      join(dir, 'tokens.py'),
+      service('TokenService', ['api_specs: [api/tokens.yaml]']),
+    );
+    writeFileSync(join(dir, 'audit.py'), service('AuditService', []));
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    output = join(dir, 'deps');
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerCodegenCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'codegen',
+      ...args,
+      '--root',
+      dir,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('writes an interface and a stub per dependency with specs', async () => {
+    await run('LoginService', '--output', output, '--format', 'json');
+    const result = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(result).toMatchObject({
+      package: 'deps',
+      stubs: [
+        {
+          interface: 'TokenServiceClient',
+          specs: ['api/tokens.yaml'],
+          operations: 1,
+        },
+      ],
+      skipped: [{ reason: 'links no api_specs' }],
+    });
+    const client = readFileSync(join(output, 'token_service.go'), 'utf-8');
+    expect(client).toContain('package deps');
+    expect(client).toContain('type TokenServiceClient interface {');
+    expect(existsSync(join(output, 'token_service_stub.go'))).toBe(true);
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('lists the files without writing them on --dry-run', async () => {
+    await run('LoginService', '--output', output, '--dry-run');
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain('Would write');
+    expect(existsSync(output)).toBe(false);
+  });
+
+  it('rejects a package that is not a Go package name', async () => {
+    await run('LoginService', '--output', output, '--package', 'Deps');
+    expect(process.exitCode).toBe(2);
+    expect(existsSync(output)).toBe(false);
+  });
+});
@@ -0,0 +1,90 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { appendScanMetrics, readScanHistory } from '@know-graph/core';
+import type { ScanMetrics } from '@know-graph/core';
+import { registerCompactHistoryCommand } from '../commands/compact-history.js';
+
+function scan(timestamp: string): ScanMetrics {
+  return {
+    timestamp,
+    files: 10,
+    entities: 4,
+    edges: 6,
+    coverage: 80,
+    byEntity: {},
+  };
+}
+
+describe('compact-history command', () => {
+  let dir: string;
+  let historyFile: string;
+  let consoleSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-compact-'));
+    historyFile = join(dir, '.knowgraph', 'history.jsonl');
+    for (const day of ['01', '02', '03']) {
+      appendScanMetrics(historyFile, scan(`2020-01-${day}T00:00:00.000Z`));
+    }
+    appendScanMetrics(historyFile, scan(new Date().toISOString()));
+    consoleSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    consoleSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerCompactHistoryCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'compact-history',
+      '--config',
+      join(dir, '.knowgraph.yml'),
+      ...args,
+    ]);
+  }
+
+  it('folds old scans into monthly aggregates', async () => {
+    await run();
+    const history = readScanHistory(historyFile);
+    expect(history.map((entry) => entry.scans)).toEqual([3, undefined]);
+    expect(consoleSpy.mock.calls[0][0]).toContain('Compacted 4 scans to 2');
+  });
+
+  it('leaves the history alone with --dry-run', async () => {
+    await run('--dry-run', '--format', 'json');
+    expect(readScanHistory(historyFile)).toHaveLength(4);
+    expect(JSON.parse(consoleSpy.mock.calls[0][0])).toMatchObject({
+      dryRun: true,
+      before: 4,
+      after: 2,
+      pruned: 2,
+    });
+  });
+
+  it('fails with a parse error on a malformed history', async () => {
+    mkdirSync(join(dir, '.knowgraph'), { recursive: true });
+    writeFileSync(historyFile, 'not json\n');
+    await run();
+    expect(process.exitCode).toBe(3);
+  });
+
+  it('rejects an unknown format as a usage error', async () => {
+    await run('--format', 'csv');
+    expect(process.exitCode).toBe(2);
+  });
+});
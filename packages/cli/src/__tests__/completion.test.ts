@@ -0,0 +1,64 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import {
+  generateCompletion,
+  registerCompletionCommand,
+} from '../commands/completion.js';
+
+function createProgram(): Command {
+  const program = new Command()
+    .name('knowgraph')
+    .version('0.0.0')
+    .option('--error-format <format>', 'Error format (text|json)');
+  program
+    .command('parse')
+    .description('Parse files and extract annotations')
+    .option('--format <format>', 'Output format')
+    .option('--pretty', 'Pretty-print JSON output');
+  const hook = program.command('hook').description("Manage the hook's state");
+  hook.command('install').option('--force', 'Overwrite [existing] hook');
+  registerCompletionCommand(program);
+  return program;
+}
+
+describe('completion command', () => {
+  it('registers the completion command', () => {
+    const program = createProgram();
+    expect(
+      program.commands.find((c) => c.name() === 'completion'),
+    ).toBeDefined();
+  });
+
+  it('completes commands, subcommands, and options in bash', () => {
+    const script = generateCompletion(createProgram(), 'bash');
+    expect(script).toContain('complete -o default -F _knowgraph knowgraph');
+    expect(script).toContain('" parse"|" hook"|" hook install"|" completion"');
+    expect(script).toContain(
+      '"") COMPREPLY=($(compgen -W "parse hook completion --version --error-format --help"',
+    );
+    expect(script).toContain(
+      '" parse") COMPREPLY=($(compgen -W "--error-format --format --pretty --help"',
+    );
+    expect(script).toContain(
+      '" hook install") COMPREPLY=($(compgen -W "--error-format --force --help"',
+    );
+  });
+
+  it('escapes descriptions for zsh', () => {
+    const script = generateCompletion(createProgram(), 'zsh');
+    expect(script).toContain('#compdef knowgraph');
+    expect(script).toContain(`'hook:Manage the hook'\\''s state'`);
+    expect(script).toContain(`'--force[Overwrite \\[existing\\] hook]'`);
+    expect(script).toContain(`'--format[Output format]:value:'`);
+  });
+
+  it('scopes fish completions to the command path', () => {
+    const script = generateCompletion(createProgram(), 'fish');
+    expect(script).toContain(
+      `complete -c knowgraph -n '__knowgraph_at \\' hook\\'' -f -a install`,
+    );
+    expect(script).toContain(
+      `complete -c knowgraph -n '__knowgraph_at \\' parse\\'' -l format -r -d 'Output format'`,
+    );
+  });
+});
@@ -0,0 +1,72 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerConfigCommand } from '../commands/config.js';
+
+describe('config lint command', () => {
+  let dir: string;
+  let configPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-config-'));
+    configPath = join(dir, '.knowgraph.yml');
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerConfigCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'config',
+      'lint',
+      ...args,
+      '--config',
+      configPath,
+    ]);
+  }
+
+  it('suggests the key a typo meant and exits with 4', async () => {
+    writeFileSync(configPath, 'version: "1.0"\nexlude:\n  - dist\n');
+    await run();
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('Unknown key exlude; did you mean exclude?');
+    expect(output).toContain('1 error(s), 0 warning(s)');
+    expect(process.exitCode).toBe(4);
+  });
+
+  it('passes with only deprecation warnings', async () => {
+    writeFileSync(configPath, 'version: "1.0"\ninclude:\n  - "**/*"\n');
+    await run('--format', 'json');
+    const issues = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(issues).toEqual([
+      expect.objectContaining({ rule: 'deprecated', path: 'include' }),
+    ]);
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('reports a valid manifest', async () => {
+    writeFileSync(configPath, 'version: "1.0"\n');
+    await run();
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain('is valid');
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('fails when there is no manifest', async () => {
+    await run();
+    expect(process.exitCode).toBe(5);
+  });
+});
@@ -0,0 +1,138 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { createDatabaseManager } from '@know-graph/core';
+import type { ConformanceReport } from '@know-graph/core';
+import {
+  formatConformanceText,
+  registerConformCommand,
+} from '../commands/conform.js';
+
+const report: ConformanceReport = {
+  convergence: 60,
+  modules: {
+    matched: 2,
+    missing: ['ledger'],
+    unexpected: [
+      {
+        id: 'legacy',
+        name: 'legacy-billing',
+        entityType: 'module',
+        filePath: 'src/legacy.ts',
+        owner: 'payments-team',
+      },
+    ],
+  },
+  edges: { matched: 1, missing: [], unexpected: [] },
+};
+
+describe('conform command', () => {
+  let dir: string;
+  let dbPath: string;
+  let logSpy: ReturnType<typeof vi.spyOn>;
+  let errorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-conform-'));
+    dbPath = join(dir, 'knowgraph.db');
+    logSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+
+    const dbManager = createDatabaseManager(dbPath);
+    dbManager.initialize();
+    for (const name of ['checkout', 'payments']) {
+      dbManager.insertEntity({
+        filePath: `src/${name}.ts`,
+        name,
+        entityType: 'module',
+        description: `The ${name} module`,
+        language: 'typescript',
+        line: 1,
+        column: 0,
+        metadata: {
+          type: 'module',
+          description: `The ${name} module`,
+          ...(name === 'checkout'
+            ? { dependencies: { services: ['payments'] } }
+            : {}),
+        },
+      });
+    }
+    dbManager.close();
+    writeFileSync(
+      join(dir, 'architecture.yml'),
+      'modules: [checkout, payments, ledger]\n',
+    );
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    logSpy.mockRestore();
+    errorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  async function run(...args: string[]): Promise<void> {
+    const program = new Command();
+    registerConformCommand(program);
+    await program.parseAsync(['node', 'knowgraph', 'conform', ...args]);
+  }
+
+  it('lists the delta with the change since the last recorded run', () => {
+    const output = formatConformanceText(report, {
+      timestamp: '2026-01-05T00:00:00.000Z',
+      convergence: 55.6,
+      missingModules: 1,
+      unexpectedModules: 1,
+      missingEdges: 1,
+      unexpectedEdges: 0,
+    });
+    expect(output).toContain('Convergence: 60% (+4.4 since 2026-01-05)');
+    expect(output).toContain('Missing modules (1)');
+    expect(output).toContain('legacy-billing (module, payments-team)');
+    expect(output).not.toContain('Missing edges');
+  });
+
+  it('reads the target next to the manifest and records each run', async () => {
+    const config = join(dir, '.knowgraph.yml');
+    writeFileSync(config, '');
+    await run('--db', dbPath, '--config', config, '--record');
+    await run('--db', dbPath, '--config', config, '--format', 'json');
+    expect(process.exitCode).toBeUndefined();
+
+    const json = JSON.parse(String(logSpy.mock.calls[1][0]));
+    expect(json.modules.missing).toEqual(['ledger']);
+    expect(json.edges.unexpected).toEqual([
+      { from: 'checkout', to: 'payments', kinds: ['service'] },
+    ]);
+    expect(json.previous.convergence).toBe(json.convergence);
+    const history = readFileSync(
+      join(dir, '.knowgraph', 'conformance.jsonl'),
+      'utf-8',
+    );
+    expect(history.trim().split('\n')).toHaveLength(1);
+  });
+
+  it('exits 1 below --min-convergence', async () => {
+    await run(
+      join(dir, 'architecture.yml'),
+      '--db',
+      dbPath,
+      '--config',
+      join(dir, '.knowgraph.yml'),
+      '--min-convergence',
+      '90',
+    );
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('reports a missing target file as an IO error', async () => {
+    await run(join(dir, 'missing.yml'), '--db', dbPath);
+    expect(process.exitCode).toBe(5);
+  });
+});
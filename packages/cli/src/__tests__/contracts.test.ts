@@ -0,0 +1,135 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerContractsCommand } from '../commands/contracts.js';
+import { indexInto } from '../utils/indexing.js';
+
+function service(name: string, services: readonly string[]): string {
+  return [
+    `class ${name}:`,
+    '    """',
+    '    @knowgraph',
+    '    type: service',
+    `    description: The ${name} service`,
+    '    owner: platform-team',
+    '    status: stable',
+    '    dependencies:',
+    `      services: [${services.join(', ')}]`,
+    '    """',
+    '',
+  ].join('\n');
+}
+
+describe('contracts command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-contracts-'));
+    writeFileSync(
+      join(dir, 'web.py'),
+      service('WebService', ['LoginService', 'token-service']),
+    );
+    writeFileSync(join(dir, 'login.py'), service('LoginService', []));
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerContractsCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'contracts',
+      ...args,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('writes a skeleton per service dependency without overwriting', async () => {
+    const output = join(dir, 'contracts');
+    mkdirSync(output);
+    const existing = join(output, 'web-service.login-service.pact.test.ts');
+    writeFileSync(existing, '// started by hand\n');
+
+    await run('generate', '--output', output, '--format', 'json');
+    const generated = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(generated).toHaveLength(2);
+    expect(generated.map((s: { written: boolean }) => s.written)).toEqual([
+      false,
+      true,
+    ]);
+    expect(readFileSync(existing, 'utf-8')).toBe('// started by hand\n');
+    const written = readFileSync(
+      join(output, 'web-service.token-service.pact.test.ts'),
+      'utf-8',
+    );
+    expect(written).toContain(
+      '// knowgraph:contract WebService -> token-service',
+    );
+  });
+
+  it('writes Go skeletons for one consumer on a dry run', async () => {
+    const output = join(dir, 'contracts');
+    await run(
+      'generate',
+      '--framework',
+      'go',
+      '--consumer',
+      'WebService',
+      '--output',
+      output,
+      '--dry-run',
+    );
+    const text = consoleLogSpy.mock.calls.map((c) => String(c[0])).join('\n');
+    expect(text).toContain('Would write 2 go contract skeleton(s)');
+    expect(text).toContain('web_service_token_service_contract_test.go');
+    expect(existsSync(output)).toBe(false);
+  });
+
+  it('rejects an unknown framework', async () => {
+    await run('generate', '--framework', 'junit');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('reports dependencies without a contract test', async () => {
+    mkdirSync(join(dir, 'pacts'));
+    writeFileSync(
+      join(dir, 'pacts', 'web-login.json'),
+      JSON.stringify({
+        consumer: { name: 'WebService' },
+        provider: { name: 'LoginService' },
+        interactions: [],
+      }),
+    );
+    await run('coverage', join(dir, 'pacts'), '--check');
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('1 of 2 service dependencies have no contract');
+    expect(output).toMatch(/uncovered.*WebService.*token-service/);
+    expect(output).toMatch(/covered.*LoginService.*web-login\.json/);
+    expect(process.exitCode).toBe(1);
+  });
+});
@@ -0,0 +1,69 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { CostReport } from '@know-graph/core';
+import { formatCostReport, registerCostCommand } from '../commands/cost.js';
+
+function makeReport(): CostReport {
+  return {
+    currency: 'USD',
+    totalCost: 15,
+    unattributedCost: 1,
+    modules: [
+      {
+        entityId: 'a',
+        name: 'checkout',
+        entityType: 'module',
+        filePath: 'src/checkout.ts',
+        owner: 'payments-team',
+        costCenter: 'CC-1',
+        cost: 10,
+        lineItems: 1,
+      },
+      {
+        entityId: 'b',
+        name: 'search',
+        entityType: 'service',
+        filePath: 'src/search.ts',
+        owner: 'search-team',
+        costCenter: null,
+        cost: 4,
+        lineItems: 1,
+      },
+    ],
+    costCenters: [
+      { costCenter: 'CC-1', cost: 10 },
+      { costCenter: '(no cost center)', cost: 4 },
+    ],
+  };
+}
+
+describe('cost command', () => {
+  it('registers the cost command', () => {
+    const program = new Command();
+    registerCostCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'cost');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toContain('--provider');
+  });
+
+  it('formats spend per module and cost center', () => {
+    const output = formatCostReport(makeReport());
+    expect(output).toContain('checkout (module)');
+    expect(output).toContain('[CC-1]');
+    expect(output).toContain('10.00 USD');
+    expect(output).toContain('(no cost center)');
+    expect(output).toContain('Total: 15.00 USD');
+    expect(output).toContain('Unattributed: 1.00 USD');
+  });
+
+  it('reports when no entities carry cost fields', () => {
+    const report: CostReport = {
+      ...makeReport(),
+      modules: [],
+      costCenters: [],
+    };
+    expect(formatCostReport(report)).toBe(
+      'No entities declare cost_center or cloud_resources.',
+    );
+  });
+});
@@ -0,0 +1,68 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { randomBytes } from 'node:crypto';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager, isEncryptedFile } from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+
+describe('index helpers', () => {
+  let dir: string;
+  let dbPath: string;
+  let configPath: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-db-'));
+    mkdirSync(join(dir, 'project'));
+    dbPath = join(dir, 'project', 'knowgraph.db');
+    configPath = join(dir, 'project', '.knowgraph.yml');
+  });
+
+  afterEach(() => {
+    delete process.env.KG_TEST_STORE_KEY;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function storeEntity(options: { encryptionKey?: Buffer } = {}): void {
+    const dbManager = createDatabaseManager(dbPath, options);
+    dbManager.initialize();
+    dbManager.insertEntity({
+      filePath: 'src/payments.ts',
+      name: 'payments',
+      entityType: 'module',
+      description: 'Takes payments',
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      metadata: { type: 'module', description: 'Takes payments' },
+    });
+    dbManager.close();
+  }
+
+  it('reads an encrypted index with the key the given manifest names', () => {
+    const key = randomBytes(32);
+    storeEntity({ encryptionKey: key });
+    expect(isEncryptedFile(dbPath)).toBe(true);
+    writeFileSync(
+      configPath,
+      'version: "1.0"\nencryption:\n  key_env: KG_TEST_STORE_KEY\n',
+    );
+    process.env.KG_TEST_STORE_KEY = key.toString('hex');
+
+    expect(loadEntities(dbPath, configPath)?.map((e) => e.name)).toEqual([
+      'payments',
+    ]);
+  });
+
+  it('builds the graph with the names in the given manifest', () => {
+    storeEntity();
+    writeFileSync(
+      configPath,
+      'version: "1.0"\naliases:\n  payments: [billing]\n',
+    );
+    const entities = loadEntities(dbPath, configPath) ?? [];
+
+    const graph = buildGraph(dbPath, entities, { configPath });
+    expect(graph.nodes.map((node) => node.aliases)).toEqual([['billing']]);
+  });
+});
@@ -0,0 +1,74 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { DecisionReport, GovernedEntity } from '@know-graph/core';
+import {
+  formatDecisionReport,
+  registerDecisionsCommand,
+} from '../commands/decisions.js';
+
+const ledger: GovernedEntity = {
+  entityId: 'a',
+  name: 'ledger',
+  entityType: 'module',
+  filePath: 'src/ledger.ts',
+  line: 3,
+  owner: 'billing-team',
+};
+
+function makeReport(): DecisionReport {
+  const oldDecision = {
+    id: 'ADR-007',
+    title: 'Use PostgreSQL for billing',
+    status: 'superseded' as const,
+    supersededBy: 'ADR-019',
+    filePath: 'docs/adr/0007.md',
+  };
+  const newDecision = {
+    id: 'ADR-019',
+    title: 'Move billing to Spanner',
+    status: 'accepted' as const,
+    filePath: 'docs/adr/0019.md',
+  };
+  return {
+    decisions: [
+      { decision: oldDecision, governs: [ledger] },
+      { decision: newDecision, governs: [] },
+    ],
+    superseded: [
+      { decision: oldDecision, replacement: newDecision, governs: [ledger] },
+    ],
+    unknownReferences: [{ decisionId: 'ADR-999', entity: ledger }],
+  };
+}
+
+describe('decisions command', () => {
+  it('registers the decisions command', () => {
+    const program = new Command();
+    registerDecisionsCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'decisions');
+    expect(cmd).toBeDefined();
+  });
+
+  it('lists decisions and superseded governance', () => {
+    const output = formatDecisionReport(makeReport());
+    expect(output).toContain('ADR-007 Use PostgreSQL for billing');
+    expect(output).toContain('Code governed by superseded decisions');
+    expect(output).toContain('ADR-007 -> ADR-019 Move billing to Spanner');
+    expect(output).toContain('src/ledger.ts:3');
+  });
+
+  it('lists unknown references', () => {
+    const output = formatDecisionReport(makeReport());
+    expect(output).toContain('ADR-999 in ledger');
+  });
+
+  it('reports when no ADRs exist', () => {
+    expect(
+      formatDecisionReport({
+        decisions: [],
+        superseded: [],
+        unknownReferences: [],
+      }),
+    ).toBe('No ADRs found.');
+  });
+});
@@ -0,0 +1,77 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  createManifestCredentials,
+  createManifestDeliveryClient,
+} from '../utils/delivery.js';
+
+const MANIFEST = [
+  'version: "1.0"',
+  'delivery:',
+  '  attempts: 1',
+  '  auth:',
+  '    - type: token',
+  '      url: https://registry.example.com/',
+  '      token_env: REGISTRY_TOKEN',
+  '    - type: token',
+  '      url: https://audit.example.com/',
+  '      token_env: UNSET_AUDIT_TOKEN',
+].join('\n');
+
+describe('manifest delivery credentials', () => {
+  let dir: string;
+  let configPath: string;
+  let fetchMock: ReturnType<typeof vi.fn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-delivery-'));
+    configPath = join(dir, '.knowgraph.yml');
+    writeFileSync(configPath, MANIFEST);
+    fetchMock = vi.fn(async () => ({ ok: true, status: 200 }));
+    vi.stubGlobal('fetch', fetchMock);
+    vi.stubEnv('REGISTRY_TOKEN', 'secret');
+  });
+
+  afterEach(() => {
+    vi.unstubAllGlobals();
+    vi.unstubAllEnvs();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('authenticates deliveries to the configured URLs only', async () => {
+    const client = createManifestDeliveryClient(configPath, { queue: false });
+    const send = (url: string): Promise<unknown> =>
+      client.send({ sink: 'test', url, body: '{}' });
+    await send('https://registry.example.com/registry/v1/queries/x');
+    await send('https://hooks.slack.com/services/T0');
+    expect(
+      fetchMock.mock.calls.map(
+        (call) => (call as [string, RequestInit])[1].headers,
+      ),
+    ).toEqual([
+      { 'Content-Type': 'application/json', Authorization: 'Bearer secret' },
+      { 'Content-Type': 'application/json' },
+    ]);
+
+    await expect(send('https://audit.example.com/events')).rejects.toThrow(
+      'UNSET_AUDIT_TOKEN is not set',
+    );
+  });
+
+  it('resolves certificate paths against the manifest', async () => {
+    writeFileSync(join(dir, 'client.pem'), 'CERT');
+    writeFileSync(join(dir, 'client-key.pem'), 'KEY');
+    const provider = createManifestCredentials(configPath, {
+      type: 'mtls',
+      url: 'https://registry.example.com/',
+      cert: 'client.pem',
+      key: 'client-key.pem',
+    });
+    expect((await provider.credentials()).tls).toMatchObject({
+      cert: 'CERT',
+      key: 'KEY',
+    });
+  });
+});
@@ -0,0 +1,99 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerDependentsCommand } from '../commands/dependents.js';
+import { indexInto } from '../utils/indexing.js';
+
+function service(name: string, dependencies: readonly string[]): string {
+  return [
+    '"""',
+    '@knowgraph',
+    'type: service',
+    `description: The ${name} service`,
+    `owner: ${name}-team`,
+    'dependencies:',
+    ...dependencies.map((line) => `  ${line}`),
+    '"""',
+    `class ${name}:`,
+    '    pass',
+    '',
+  ].join('\n');
+}
+
+describe('dependents command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-dependents-'));
+    writeFileSync(
+      join(dir, 'orders.py'),
+      service('orders', ['databases: [postgres-main]']),
+    );
+    writeFileSync(
+      join(dir, 'checkout.py'),
+      service('checkout', ['services: [orders]']),
+    );
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerDependentsCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'dependents',
+      ...args,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('lists direct and transitive dependents built at index time', async () => {
+    await run('postgres-main');
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('postgres-main');
+    expect(output).toContain('2 dependent(s)');
+    expect(output.indexOf('orders')).toBeLessThan(output.indexOf('checkout'));
+  });
+
+  it('limits the depth and writes JSON', async () => {
+    await run('postgres-main', '--depth', '1', '--format', 'json');
+    const records = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(records).toHaveLength(1);
+    expect(records[0]).toMatchObject({
+      sourceName: 'orders',
+      kind: 'database',
+      depth: 1,
+    });
+  });
+
+  it('says when nothing depends on a name', async () => {
+    await run('checkout');
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      'Nothing indexed depends on checkout',
+    );
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('rejects a depth that is not a positive integer', async () => {
+    await run('orders', '--depth', '0');
+    expect(process.exitCode).toBe(2);
+  });
+});
@@ -0,0 +1,116 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import {
+  deploymentLogPath,
+  formatDeployedNodes,
+  registerDeploymentsCommand,
+} from '../commands/deployments.js';
+
+describe('deployments command', () => {
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+  let dir: string;
+
+  beforeEach(() => {
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+    dir = mkdtempSync(join(tmpdir(), 'kg-deployments-'));
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerDeploymentsCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'deployments', ...args]);
+  }
+
+  it('registers the import, current, and list subcommands', () => {
+    const program = new Command();
+    registerDeploymentsCommand(program);
+    const command = program.commands.find((c) => c.name() === 'deployments');
+    expect(command!.commands.map((c) => c.name())).toEqual([
+      'import',
+      'current',
+      'list',
+    ]);
+  });
+
+  it('resolves the log next to the manifest', () => {
+    const config = join(dir, '.knowgraph.yml');
+    writeFileSync(config, 'version: "1.0"\ndeployments:\n  path: cd.jsonl\n');
+    expect(deploymentLogPath(config)).toBe(join(dir, 'cd.jsonl'));
+  });
+
+  it('imports a CSV into the log', async () => {
+    const config = join(dir, '.knowgraph.yml');
+    const csv = join(dir, 'deployments.csv');
+    writeFileSync(
+      csv,
+      'service,environment,version,deployed_at\nauth,prod,2.3.1,2024-05-30T08:00:00Z\n',
+    );
+    await run('import', csv, '--config', config);
+    expect(process.exitCode).toBeUndefined();
+    const log = join(dir, '.knowgraph', 'deployments.jsonl');
+    expect(JSON.parse(readFileSync(log, 'utf-8'))).toEqual({
+      service: 'auth',
+      environment: 'prod',
+      version: '2.3.1',
+      deployedAt: '2024-05-30T08:00:00.000Z',
+      source: 'csv',
+    });
+  });
+
+  it('fails with a usage error on an unknown source', async () => {
+    await run('import', join(dir, 'x.json'), '--source', 'jenkins');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with a parse error on an unreadable payload', async () => {
+    const payload = join(dir, 'payload.json');
+    writeFileSync(payload, '{"hello": 1}');
+    await run('import', payload, '--config', join(dir, '.knowgraph.yml'));
+    expect(process.exitCode).toBe(3);
+  });
+
+  it('fails with a usage error on an unknown status', async () => {
+    await run('list', '--status', 'retired');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    await run('list', '--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+
+  it('groups deployed nodes by environment', () => {
+    const text = formatDeployedNodes([
+      {
+        entityId: 'id-login',
+        name: 'HandleLogin',
+        entityType: 'function',
+        status: 'deprecated',
+        owner: 'identity',
+        service: 'auth',
+        environment: 'prod',
+        version: '2.3.1',
+        deployedAt: '2024-05-30T08:00:00.000Z',
+      },
+    ]);
+    expect(text).toContain('prod');
+    expect(text).toContain('HandleLogin');
+    expect(text).toContain('via auth');
+    expect(formatDeployedNodes([])).toBe('No deployed nodes found.');
+  });
+});
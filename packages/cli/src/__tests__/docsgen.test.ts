@@ -0,0 +1,119 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerDocsgenCommand } from '../commands/docsgen.js';
+import { indexInto } from '../utils/indexing.js';
+
+function moduleFile(description: string, services: readonly string[]): string {
+  return [
+    '"""',
+    '@knowgraph',
+    'type: module',
+    `description: ${description}`,
+    'owner: payments-team',
+    'status: stable',
+    'dependencies:',
+    `  services: [${services.join(', ')}]`,
+    '"""',
+    '',
+  ].join('\n');
+}
+
+describe('docsgen command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-docsgen-'));
+    mkdirSync(join(dir, 'billing'));
+    mkdirSync(join(dir, 'ledger'));
+    writeFileSync(
+      join(dir, 'billing', 'billing.py'),
+      moduleFile('Bills customers', ['ledger']),
+    );
+    writeFileSync(
+      join(dir, 'ledger', 'ledger.py'),
+      moduleFile('Keeps books', []),
+    );
+    writeFileSync(
+      join(dir, 'billing', 'README.md'),
+      '# Billing\n\nHow to run it.\n',
+    );
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerDocsgenCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'docsgen',
+      dir,
+      ...args,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('writes the Architecture section into existing READMEs', async () => {
+    await run('--format', 'json');
+    const result = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(result.updates).toEqual([
+      {
+        path: 'billing/README.md',
+        entities: [expect.any(String)],
+        status: 'updated',
+      },
+    ]);
+    expect(result.skipped).toEqual([
+      { path: 'ledger/README.md', reason: 'no README to update' },
+    ]);
+    const readme = readFileSync(join(dir, 'billing', 'README.md'), 'utf-8');
+    expect(readme).toMatch(/^# Billing\n\nHow to run it\.\n\n<!-- knowgraph/);
+    expect(readme).toContain('Bills customers');
+    expect(readme).toContain('- Services: ledger');
+    expect(existsSync(join(dir, 'ledger', 'README.md'))).toBe(false);
+  });
+
+  it('creates missing READMEs with --create', async () => {
+    await run('--create');
+    const readme = readFileSync(join(dir, 'ledger', 'README.md'), 'utf-8');
+    expect(readme).toContain('**Used by:** billing');
+  });
+
+  it('fails --check until the READMEs are generated', async () => {
+    await run('--check');
+    expect(process.exitCode).toBe(1);
+    expect(readFileSync(join(dir, 'billing', 'README.md'), 'utf-8')).toBe(
+      '# Billing\n\nHow to run it.\n',
+    );
+
+    process.exitCode = undefined;
+    await run();
+    await run('--check');
+    expect(process.exitCode).toBeUndefined();
+  });
+});
@@ -0,0 +1,129 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  copyFileSync,
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join, resolve } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerDoctorCommand } from '../commands/doctor.js';
+import { indexInto } from '../utils/indexing.js';
+
+const SAMPLE = resolve(__dirname, 'fixtures', 'sample.ts');
+
+describe('doctor command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-doctor-'));
+    mkdirSync(join(dir, 'src'));
+    copyFileSync(SAMPLE, join(dir, 'src', 'sample.ts'));
+    writeFileSync(join(dir, '.knowgraph.yml'), 'version: "1.0"\n');
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    program.version('1.2.3');
+    registerDoctorCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'doctor',
+      dir,
+      '--db',
+      dbPath,
+      ...args,
+    ]);
+  }
+
+  function output(): string {
+    return consoleLogSpy.mock.calls.map((call) => String(call[0])).join('\n');
+  }
+
+  it('reports each check and the parser coverage', async () => {
+    await run();
+
+    const text = output();
+    expect(text).toContain('knowgraph 1.2.3');
+    expect(text).toMatch(/ok\s+config/);
+    expect(text).toMatch(/ok\s+store/);
+    expect(text).toContain('typescript');
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('fails on an invalid manifest and suggests a fix', async () => {
+    writeFileSync(join(dir, '.knowgraph.yml'), 'version: 2\n');
+
+    await run('--format', 'json');
+
+    const report = JSON.parse(output());
+    const config = report.checks.find(
+      (check: { name: string }) => check.name === 'config',
+    );
+    expect(config.status).toBe('fail');
+    expect(config.fix).toBeTruthy();
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('writes the report to a bundle file', async () => {
+    const bundle = join(dir, 'doctor.json');
+
+    await run('--bundle', bundle);
+
+    const report = JSON.parse(readFileSync(bundle, 'utf-8'));
+    expect(report.environment.knowgraphVersion).toBe('1.2.3');
+    expect(report.configSections).toEqual(['version']);
+    const names = report.checks.map((check: { name: string }) => check.name);
+    expect(names).toEqual([
+      'config',
+      'store',
+      'git',
+      'annotations',
+      'limits',
+      'cache',
+    ]);
+  });
+
+  it('shows what each enricher has cached', async () => {
+    mkdirSync(join(dir, '.knowgraph', 'cache'));
+    writeFileSync(
+      join(dir, '.knowgraph', 'cache', 'enrich-git.json'),
+      JSON.stringify({
+        entries: {
+          a: { expiresAt: Date.now() + 60_000, input: 'h:x', reply: null },
+        },
+        lastRun: { at: '2026-10-01T00:00:00.000Z', calls: 1, cached: 4 },
+      }),
+    );
+
+    await run();
+
+    const text = output();
+    expect(text).toMatch(/ok\s+cache\s+1 cached enrichment replies/);
+    expect(text).toContain('Enrichment cache');
+    expect(text).toMatch(/git\s+1 entries, 0\.\d KiB/);
+    expect(text).toContain('4 cached, 1 calls');
+  });
+});
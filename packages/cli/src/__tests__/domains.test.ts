@@ -0,0 +1,67 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { DomainReport } from '@know-graph/core';
+import {
+  formatDomainReport,
+  registerDomainsCommand,
+} from '../commands/domains.js';
+
+const report: DomainReport = {
+  domains: [
+    {
+      domain: 'billing',
+      entityCount: 2,
+      owners: ['billing-team'],
+      internalEdges: 0,
+      afferent: 1,
+      efferent: 2,
+      instability: 0.67,
+    },
+    {
+      domain: 'ordering',
+      entityCount: 2,
+      owners: [],
+      internalEdges: 1,
+      afferent: 1,
+      efferent: 1,
+      instability: 0.5,
+    },
+  ],
+  dependencies: [
+    {
+      from: 'ordering',
+      to: 'billing',
+      count: 1,
+      examples: ['checkout -> ledger'],
+    },
+  ],
+};
+
+describe('domains command', () => {
+  it('registers the domains command', () => {
+    const program = new Command();
+    registerDomainsCommand(program);
+    expect(program.commands.find((c) => c.name() === 'domains')).toBeDefined();
+  });
+
+  it('summarizes domains and coupling', () => {
+    const output = formatDomainReport(report);
+    expect(output).toContain('2 entities, 0 internal deps');
+    expect(output).toContain('Ca 1 / Ce 2, instability 0.67');
+    expect(output).toContain('owners: billing-team');
+  });
+
+  it('lists cross-domain dependencies with examples', () => {
+    const output = formatDomainReport(report);
+    expect(output).toContain('ordering -> billing');
+    expect(output).toContain('checkout -> ledger');
+  });
+
+  it('notes when no cross-domain dependencies exist', () => {
+    const output = formatDomainReport({ ...report, dependencies: [] });
+    expect(output).toContain('No cross-domain dependencies.');
+    expect(formatDomainReport({ domains: [], dependencies: [] })).toBe(
+      'No entities indexed.',
+    );
+  });
+});
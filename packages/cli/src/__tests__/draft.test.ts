@@ -0,0 +1,121 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join, relative } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { readAuditLog } from '@know-graph/core';
+import type { DraftResult } from '@know-graph/core';
+import { registerDraftCommand } from '../commands/draft.js';
+
+const SOURCE = [
+  'export function refund(orderId: string): void {',
+  '  ledger.reverse(orderId);',
+  '}',
+  '',
+].join('\n');
+
+function completion(content: string): Response {
+  return new Response(
+    JSON.stringify({ choices: [{ message: { content } }] }),
+    { status: 200, headers: { 'Content-Type': 'application/json' } },
+  );
+}
+
+describe('draft command', () => {
+  let dir: string;
+  let fetchMock: ReturnType<typeof vi.fn>;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-draft-cli-'));
+    writeFileSync(join(dir, 'billing.ts'), SOURCE);
+    fetchMock = vi.fn(async () =>
+      completion(
+        '{"type": "function", "description": "Reverses the ledger entries of an order", "tags": ["billing"]}',
+      ),
+    );
+    vi.stubGlobal('fetch', fetchMock);
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    vi.unstubAllGlobals();
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerDraftCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'draft',
+      '--path',
+      dir,
+      '--config',
+      join(dir, '.knowgraph.yml'),
+      ...args,
+    ]);
+  }
+
+  it('writes drafts marked generated', async () => {
+    await run('--llm', 'http://llm.test/v1', '--owner', 'billing-team');
+    expect(fetchMock).toHaveBeenCalledTimes(1);
+    expect(String(fetchMock.mock.calls[0]?.[0])).toBe(
+      'http://llm.test/v1/chat/completions',
+    );
+    const content = readFileSync(join(dir, 'billing.ts'), 'utf-8');
+    expect(content).toContain(' * owner: billing-team');
+    expect(content).toContain(' * generated: true\n */\nexport function refund');
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      'Drafted 1 of 1 unannotated symbol(s) in 1 file(s)',
+    );
+    expect(process.exitCode).toBeUndefined();
+    const [entry] = readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'));
+    expect(entry?.command).toBe('draft');
+    expect(entry?.changes.map((change) => change.target)).toEqual([
+      relative('.', join(dir, 'billing.ts')),
+    ]);
+  });
+
+  it('reads the model from the manifest and leaves files alone in a dry run', async () => {
+    writeFileSync(
+      join(dir, '.knowgraph.yml'),
+      'version: "1.0"\nllm:\n  base_url: http://manifest.test/v1\n  model: local-model\n',
+    );
+    await run('--dry-run', '--format', 'json');
+    expect(String(fetchMock.mock.calls[0]?.[0])).toBe(
+      'http://manifest.test/v1/chat/completions',
+    );
+    const body = JSON.parse(String(fetchMock.mock.calls[0]?.[1]?.body)) as {
+      model: string;
+    };
+    expect(body.model).toBe('local-model');
+    const result = JSON.parse(
+      String(consoleLogSpy.mock.calls[0]?.[0]),
+    ) as DraftResult;
+    expect(result.files[0]?.drafts[0]?.metadata.generated).toBe(true);
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).toBe(SOURCE);
+    expect(readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'))).toEqual([]);
+  });
+
+  it('needs a model', async () => {
+    await run();
+    expect(process.exitCode).toBe(2);
+    expect(fetchMock).not.toHaveBeenCalled();
+  });
+
+  it('reports API failures as I/O errors', async () => {
+    fetchMock.mockImplementation(
+      async () => new Response('denied', { status: 401 }),
+    );
+    await run('--llm', 'http://llm.test/v1');
+    expect(process.exitCode).toBe(5);
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).toBe(SOURCE);
+  });
+});
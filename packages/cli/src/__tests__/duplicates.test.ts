@@ -0,0 +1,62 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { DuplicateReport } from '@know-graph/core';
+import {
+  formatDuplicateReport,
+  registerDuplicatesCommand,
+} from '../commands/duplicates.js';
+
+const report: DuplicateReport = {
+  compared: 10,
+  clusters: [
+    {
+      score: 0.89,
+      owners: ['payments-team', 'search-team'],
+      sharedTerms: ['backoff', 'retry'],
+      members: [
+        {
+          entityId: 'a',
+          name: 'retryWithBackoff',
+          entityType: 'function',
+          filePath: 'src/payments/retry.ts',
+          owner: 'payments-team',
+          description: 'Retries failed HTTP requests with exponential backoff',
+        },
+        {
+          entityId: 'b',
+          name: 'httpRetry',
+          entityType: 'function',
+          filePath: 'src/search/http.ts',
+          owner: 'search-team',
+          description: 'Retry failed HTTP requests',
+        },
+      ],
+    },
+  ],
+};
+
+describe('duplicates command', () => {
+  it('registers the duplicates command', () => {
+    const program = new Command();
+    registerDuplicatesCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'duplicates');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toContain('--cross-team');
+  });
+
+  it('lists clusters with owners and shared terms', () => {
+    const output = formatDuplicateReport(report);
+    expect(output).toContain('Consolidation candidates: 1 clusters');
+    expect(output).toContain('score 0.89');
+    expect(output).toContain('owners: payments-team, search-team');
+    expect(output).toContain('shared: backoff, retry');
+    expect(output).toContain('retryWithBackoff (function)');
+    expect(output).toContain('src/search/http.ts: Retry failed HTTP requests');
+  });
+
+  it('reports when nothing overlaps', () => {
+    expect(formatDuplicateReport({ compared: 3, clusters: [] })).toBe(
+      'No likely duplicates among 3 entities.',
+    );
+  });
+});
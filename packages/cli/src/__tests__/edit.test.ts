@@ -0,0 +1,175 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { readAuditLog } from '@know-graph/core';
+import { registerEditCommand } from '../commands/edit.js';
+
+const SOURCE = [
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Charges a card',
+  ' * status: experimental',
+  ' * tags: [payments]',
+  ' */',
+  'export function charge(): void {}',
+  '',
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Sends a receipt',
+  ' * status: experimental',
+  ' * tags: [email]',
+  ' */',
+  'export function receipt(): void {}',
+  '',
+].join('\n');
+
+describe('edit command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-edit-cli-'));
+    writeFileSync(join(dir, 'billing.ts'), SOURCE);
+    vi.stubEnv('KNOWGRAPH_ACTOR', 'ana@example.com');
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    vi.unstubAllEnvs();
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerEditCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'edit',
+      ...args,
+      '--path',
+      dir,
+      '--config',
+      join(dir, '.knowgraph.yml'),
+    ]);
+  }
+
+  it('updates the matching annotations and audits the change', async () => {
+    await run(
+      '--query',
+      'tag=payments AND status=experimental',
+      '--set',
+      'status=stable',
+    );
+    expect(process.exitCode).toBeUndefined();
+    const content = readFileSync(join(dir, 'billing.ts'), 'utf-8');
+    expect(content.match(/status: stable/g)).toHaveLength(1);
+    expect(content).toContain('Sends a receipt\n * status: experimental');
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      '1 of 1 matching annotation(s) updated in 1 file(s)',
+    );
+    const [entry] = readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'));
+    expect(entry?.command).toBe('edit');
+    expect(entry?.changes).toHaveLength(1);
+  });
+
+  it('shows a diff and writes nothing in a dry run', async () => {
+    await run(
+      '--query',
+      'status=experimental',
+      '--set',
+      'status=deprecated',
+      '--dry-run',
+    );
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('- * status: experimental');
+    expect(output).toContain('+ * status: deprecated');
+    expect(output).toContain('2 annotation(s) updated');
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).toBe(SOURCE);
+  });
+
+  it('fails without anything to set', async () => {
+    await run('--query', 'tag=payments');
+    expect(process.exitCode).toBe(2);
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).toBe(SOURCE);
+  });
+
+  it('sets fields per node from a CSV and reports unresolved rows', async () => {
+    const csv = join(dir, 'owners.csv');
+    writeFileSync(
+      csv,
+      [
+        'node,field,value',
+        'charge,owner,payments-team',
+        'receipt,owner,email-team',
+        'refund,owner,payments-team',
+      ].join('\n'),
+    );
+    await run('--csv', csv);
+    const content = readFileSync(join(dir, 'billing.ts'), 'utf-8');
+    expect(content).toContain('tags: [payments]\n * owner: payments-team');
+    expect(content).toContain('tags: [email]\n * owner: email-team');
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('✗ Row 3: refund matches no annotated symbol');
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('rejects --csv together with a query', async () => {
+    await run('--csv', join(dir, 'owners.csv'), '--query', 'tag=payments');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('expands compact annotations and fails on ones that do not parse', async () => {
+    writeFileSync(
+      join(dir, 'util.ts'),
+      [
+        '// knowgraph: type=function owner=payments-team',
+        'export function cents(): void {}',
+        '// knowgraph: type=function stray',
+        'export function dollars(): void {}',
+      ].join('\n'),
+    );
+    await run('--expand');
+    const content = readFileSync(join(dir, 'util.ts'), 'utf-8');
+    expect(content).toContain(' * @knowgraph\n * type: function\n');
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('✓ Expanded util.ts:1');
+    expect(output).toContain('✗ util.ts:3 Expected key=value, got "stray"');
+    expect(process.exitCode).toBe(3);
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).toBe(SOURCE);
+  });
+
+  it('converts Go annotation blocks to directives with --godoc', async () => {
+    writeFileSync(
+      join(dir, 'charge.go'),
+      [
+        '// @knowgraph',
+        '// type: function',
+        '// description: Charges a card',
+        'func Charge() {}',
+      ].join('\n'),
+    );
+    await run('--godoc');
+    expect(readFileSync(join(dir, 'charge.go'), 'utf-8')).toBe(
+      '//knowgraph:type=function description="Charges a card"\nfunc Charge() {}',
+    );
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      '1 Go annotation block(s) converted in 1 file(s)',
+    );
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('rejects --expand together with a query', async () => {
+    await run('--expand', '--query', 'tag=payments');
+    expect(process.exitCode).toBe(2);
+  });
+});
@@ -0,0 +1,114 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  reportCheckFailure,
+  reportError,
+  resolveErrorFormat,
+  setErrorFormat,
+} from '../utils/errors.js';
+
+describe('resolveErrorFormat', () => {
+  it('reads the flag in either form, then the environment', () => {
+    expect(resolveErrorFormat(['lint', '--error-format', 'json'], {})).toBe(
+      'json',
+    );
+    expect(resolveErrorFormat(['lint', '--error-format=json'], {})).toBe(
+      'json',
+    );
+    expect(
+      resolveErrorFormat(['lint'], { KNOWGRAPH_ERROR_FORMAT: 'json' }),
+    ).toBe('json');
+    expect(
+      resolveErrorFormat(['--error-format', 'text'], {
+        KNOWGRAPH_ERROR_FORMAT: 'json',
+      }),
+    ).toBe('text');
+  });
+
+  it('falls back to text', () => {
+    expect(resolveErrorFormat(['lint'], {})).toBe('text');
+    expect(resolveErrorFormat(['--error-format', 'xml'], {})).toBe('text');
+  });
+});
+
+describe('reportError', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    setErrorFormat('text');
+  });
+
+  it('prints the message and hint as text', () => {
+    reportError('Config not found', 'io', "Run 'knowgraph init'.");
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain(
+      'Error: Config not found',
+    );
+    expect(String(consoleErrorSpy.mock.calls[1]?.[0])).toContain(
+      "Run 'knowgraph init'.",
+    );
+    expect(process.exitCode).toBe(5);
+  });
+
+  it('prints one JSON envelope in JSON mode', () => {
+    setErrorFormat('json');
+    reportError('--limit must be a positive integer', 'usage', 'Try 10.');
+    expect(consoleErrorSpy).toHaveBeenCalledTimes(1);
+    expect(JSON.parse(String(consoleErrorSpy.mock.calls[0]?.[0]))).toEqual({
+      error: {
+        kind: 'usage',
+        exitCode: 2,
+        message: '--limit must be a positive integer',
+        details: { hint: 'Try 10.' },
+      },
+    });
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('classifies errors without an explicit kind', () => {
+    reportError(Object.assign(new Error('EACCES'), { code: 'EACCES' }));
+    expect(process.exitCode).toBe(5);
+  });
+});
+
+describe('reportCheckFailure', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    setErrorFormat('text');
+  });
+
+  it('only sets the exit code in text mode', () => {
+    reportCheckFailure('3 lint issue(s)');
+    expect(consoleErrorSpy).not.toHaveBeenCalled();
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('adds the envelope with its details in JSON mode', () => {
+    setErrorFormat('json');
+    reportCheckFailure('2 error(s) and 0 warning(s)', 'schema', { errors: 2 });
+    expect(JSON.parse(String(consoleErrorSpy.mock.calls[0]?.[0]))).toEqual({
+      error: {
+        kind: 'schema',
+        exitCode: 4,
+        message: '2 error(s) and 0 warning(s)',
+        details: { errors: 2 },
+      },
+    });
+    expect(process.exitCode).toBe(4);
+  });
+});
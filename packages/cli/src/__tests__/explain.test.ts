@@ -0,0 +1,96 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { Explanation, StoredEntity } from '@know-graph/core';
+import {
+  formatExplanation,
+  registerExplainCommand,
+} from '../commands/explain.js';
+
+const entity: StoredEntity = {
+  id: 'checkout',
+  filePath: 'src/checkout.ts',
+  name: 'CheckoutService',
+  entityType: 'service',
+  description: 'Runs checkout for a cart',
+  rawDocstring: null,
+  signature: null,
+  parent: null,
+  language: 'typescript',
+  line: 12,
+  column: 0,
+  owner: 'payments-team',
+  status: 'stable',
+  metadata: { type: 'service', description: 'Runs checkout for a cart' },
+  tags: ['payments'],
+  links: [],
+  fileHash: null,
+  createdAt: '2024-01-01T00:00:00Z',
+  updatedAt: '2024-01-01T00:00:00Z',
+};
+
+const explanation: Explanation = {
+  entity,
+  businessGoal: 'Take payments',
+  dependencies: [
+    {
+      id: 'external:external_api:stripe',
+      name: 'stripe',
+      entityType: null,
+      kind: 'external_api',
+      external: true,
+      filePath: null,
+      owner: null,
+    },
+  ],
+  dependents: [],
+  compliance: { regulations: ['PCI-DSS'] },
+  links: [{ type: 'runbook', url: 'https://runbooks.example.com/checkout' }],
+  decisions: [{ id: 'ADR-9' }],
+  lastChange: {
+    commit: '0123456789abcdef',
+    author: 'Ada',
+    date: '2024-05-01T10:00:00Z',
+  },
+  linkedChanges: [
+    {
+      commit: 'fedcba9876543210',
+      author: 'Lin',
+      date: '2024-05-03T09:00:00Z',
+      subject: 'Retry declined charges',
+    },
+  ],
+  contributors: [
+    { author: 'Ada', commits: 3, last_modified: '2024-05-01T10:00:00Z' },
+  ],
+};
+
+describe('explain command', () => {
+  it('registers the explain command', () => {
+    const program = new Command();
+    registerExplainCommand(program);
+    expect(program.commands.find((c) => c.name() === 'explain')).toBeDefined();
+  });
+
+  it('summarizes the entity and its sections', () => {
+    const output = formatExplanation(explanation);
+    expect(output).toContain('Runs checkout for a cart');
+    expect(output).toContain('src/checkout.ts:12');
+    expect(output).toContain('Owner:    payments-team');
+    expect(output).toContain('Goal:     Take payments');
+    expect(output).toContain('Regulations: PCI-DSS');
+    expect(output).toContain('https://runbooks.example.com/checkout');
+    expect(output).toContain('(no ADR found)');
+    expect(output).toContain('0123456 by Ada on 2024-05-01T10:00:00Z');
+    expect(output).toContain(
+      'fedcba9 Retry declined charges by Lin on 2024-05-03T09:00:00Z',
+    );
+    expect(output).toContain('Contributors');
+    expect(output).toContain('3 commits, last on 2024-05-01T10:00:00Z');
+  });
+
+  it('leaves out empty sections', () => {
+    const output = formatExplanation(explanation);
+    expect(output).not.toContain('Used by');
+    expect(output).not.toContain('Operations');
+  });
+});
@@ -1,6 +1,33 @@
 import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  checkGoldenExports,
+  createRedactor,
+  fileChange,
+  previewRedaction,
+  resolveRedactionProfile,
+} from '@know-graph/core';
 import type { StoredEntity } from '@know-graph/core';
-import { formatExport } from '../commands/export.js';
+import {
+  createExportRegistry,
+  formatExport,
+  formatExporterList,
+  writeExport,
+} from '../commands/export.js';
+import {
+  formatPruneReport,
+  formatRedactionPreview,
+} from '../utils/export-plan.js';
+import { parseEdgeFilter } from '../utils/edge-filter.js';
+import { resolvePruneOptions } from '../utils/prune.js';
+import {
+  readEdgeRules,
+  readGraphNames,
+  readRedactionProfiles,
+  saltRedactionProfile,
+} from '../utils/manifest.js';
 
 function createEntity(overrides: Partial<StoredEntity> = {}): StoredEntity {
   return {
@@ -283,6 +310,23 @@ describe('formatExport', () => {
 
       expect(result).not.toContain('**Business Goal:**');
     });
+
+    it('shows image diagrams inline and links Excalidraw scenes', () => {
+      const entity = createEntity({
+        metadata: {
+          type: 'function',
+          description: 'A function',
+          diagrams: ['flow.svg', '../../docs/dates.excalidraw'],
+        },
+      });
+
+      const result = formatExport([entity], 'markdown');
+
+      expect(result).toContain(
+        '**Diagrams:** ![src/utils/flow.svg](src/utils/flow.svg) ' +
+          '[docs/dates.excalidraw](docs/dates.excalidraw)',
+      );
+    });
   });
 
   describe('multiple entity types within owner', () => {
@@ -341,6 +385,151 @@ describe('formatExport', () => {
       expect(result).not.toContain('null');
     });
   });
+
+  describe('deterministic output', () => {
+    it('produces identical output regardless of input order', () => {
+      const entities = [
+        createEntity({ id: 'a', name: 'parse', line: 30 }),
+        createEntity({ id: 'b', name: 'render', filePath: 'src/ui.ts' }),
+        createEntity({ id: 'c', name: 'Zed', owner: 'Beta' }),
+        createEntity({ id: 'd', name: 'load', owner: 'alpha' }),
+      ];
+
+      const forward = formatExport(entities, 'markdown');
+      const reversed = formatExport([...entities].reverse(), 'markdown');
+
+      expect(reversed).toBe(forward);
+      // Code-point order: upper case sorts before lower case on every machine
+      expect(forward.indexOf('### Beta')).toBeLessThan(
+        forward.indexOf('### alpha'),
+      );
+    });
+  });
+
+  describe('cancellation', () => {
+    it('stops with an AbortError when the signal is aborted', () => {
+      const controller = new AbortController();
+      controller.abort();
+
+      expect(() =>
+        formatExport([createEntity()], 'cursorrules', {
+          signal: controller.signal,
+        }),
+      ).toThrow(expect.objectContaining({ name: 'AbortError' }));
+    });
+
+    it('ignores cancellation for an empty index', () => {
+      const controller = new AbortController();
+      controller.abort();
+
+      expect(() =>
+        formatExport([], 'markdown', { signal: controller.signal }),
+      ).not.toThrow();
+    });
+  });
+});
+
+describe('writeExport', () => {
+  it('writes one section per chunk and matches formatExport', () => {
+    const entities = [
+      createEntity({ id: 'a', name: 'alpha' }),
+      createEntity({ id: 'b', name: 'beta', owner: null, line: 20 }),
+    ];
+    const chunks: string[] = [];
+    writeExport(entities, 'markdown', { write: (c) => chunks.push(c) });
+
+    expect(chunks.length).toBeGreaterThan(5);
+    expect(chunks.join('')).toBe(formatExport(entities, 'markdown'));
+  });
+});
+
+describe('createExportRegistry', () => {
+  const plugin = {
+    name: 'catalog',
+    command: ['kg-catalog'],
+    formats: ['backstage', 'json'],
+  };
+
+  it('lists built-in formats before plugin formats', () => {
+    const registry = createExportRegistry([plugin]);
+    expect(registry.list().map((e) => e.name)).toEqual([
+      'cursorrules',
+      'markdown',
+      'json',
+      'snapshot',
+      'patch',
+      'parquet-nodes',
+      'parquet-edges',
+      'chunks',
+      'backstage',
+    ]);
+    expect(registry.get('json')?.description).not.toContain('catalog');
+    expect(registry.get('backstage')?.defaultOutput).toBe(
+      'knowgraph-export.backstage',
+    );
+  });
+
+  it('renders context files from localized entities', () => {
+    const exporter = createExportRegistry([]).get('markdown')!;
+    const chunks: string[] = [];
+    const stats = exporter.export(
+      () => [createEntity()],
+      { write: (c) => chunks.push(String(c)) },
+      {},
+    );
+    expect(exporter.localized).toBe(true);
+    expect(stats).toEqual({ nodes: 1 });
+    expect(chunks.join('')).toBe(formatExport([createEntity()], 'markdown'));
+  });
+
+  it('leaves pruned entities out of context files', () => {
+    const exporter = createExportRegistry([]).get('markdown')!;
+    const chunks: string[] = [];
+    const utils = createEntity({
+      id: 'utils',
+      name: 'utils',
+      entityType: 'module',
+    });
+    const stats = exporter.export(
+      () => [utils, createEntity()],
+      { write: (c) => chunks.push(String(c)) },
+      { prune: { collapseFunctions: true } },
+    );
+    expect(stats.nodes).toBe(1);
+    expect(stats.pruned?.map((d) => [d.name, d.reason])).toEqual([
+      ['formatDate', 'leaf-function'],
+    ]);
+    expect(chunks.join('')).toBe(formatExport([utils], 'markdown'));
+    expect(formatPruneReport(stats.pruned ?? [])).toContain(
+      'leaf-function into its module, significance 0',
+    );
+  });
+
+  it('formats the list with default outputs', () => {
+    const output = formatExporterList(createExportRegistry([plugin]).list());
+    expect(output).toContain('cursorrules');
+    expect(output).toContain('(.cursorrules)');
+    expect(output).toContain("Rendered by plugin 'catalog'");
+  });
+});
+
+describe('golden context files', () => {
+  // The graph formats have theirs in core. Rewrite after an intended change
+  // with KNOWGRAPH_UPDATE_GOLDEN=1 and review the diff.
+  const goldenDir = resolve(__dirname, 'fixtures', 'golden');
+  const registry = createExportRegistry([]);
+
+  for (const format of ['cursorrules', 'markdown']) {
+    it(`renders ${format} as its golden file`, () => {
+      const [result] = checkGoldenExports([registry.get(format)!], goldenDir, {
+        update: process.env.KNOWGRAPH_UPDATE_GOLDEN === '1',
+      });
+      expect(result.diff ?? '').toBe('');
+      expect(result.status, `${result.path} has not been written`).not.toBe(
+        'missing',
+      );
+    });
+  }
 });
 
 describe('registerExportCommand', () => {
@@ -358,6 +547,11 @@ describe('registerExportCommand', () => {
     );
     expect(exportCmd).toBeDefined();
     expect(exportCmd!.description()).toContain('Export');
+    expect(exportCmd!.options.map((o) => o.long)).toContain('--list-formats');
+    const format = exportCmd!.options.find((o) => o.long === '--format');
+    expect(format?.description).toContain(
+      'parquet-nodes|parquet-edges|chunks, or a plugin format',
+    );
   });
 });
 
@@ -405,9 +599,229 @@ describe('runExport integration', () => {
     // Allow microtask to complete
     await new Promise((resolve) => setTimeout(resolve, 10));
 
-    expect(process.exitCode).toBe(1);
+    expect(process.exitCode).toBe(5);
     expect(consoleErrorSpy).toHaveBeenCalled();
     const errorArg = consoleErrorSpy.mock.calls[0]?.[0] ?? '';
     expect(errorArg).toContain('Database not found');
   });
 });
+
+describe('readRedactionProfiles', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-redact-'));
+    writeFileSync(
+      join(dir, '.knowgraph.yml'),
+      [
+        'version: "1.0"',
+        'redaction:',
+        '  profiles:',
+        '    partner:',
+        '      salt_env: KG_SALT',
+        '      rules:',
+        '        - field: owner',
+        '          action: hash',
+        '',
+      ].join('\n'),
+    );
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('reads profiles with salts from the environment', () => {
+    const profiles = readRedactionProfiles(join(dir, '.knowgraph.yml'), {
+      KG_SALT: 'pepper',
+    });
+    expect(profiles.partner).toEqual({
+      name: 'partner',
+      salt: 'pepper',
+      rules: [{ field: 'owner', action: 'hash' }],
+    });
+
+    const entity = createEntity({ owner: 'jane@example.com' });
+    const redacted = createRedactor(profiles.partner!)(entity);
+    expect(redacted.owner).not.toBe('jane@example.com');
+    expect(formatExport([redacted], 'markdown')).not.toContain('jane@');
+  });
+
+  it('fails when the salt variable is unset', () => {
+    expect(() =>
+      readRedactionProfiles(join(dir, '.knowgraph.yml'), {}),
+    ).toThrow(/KG_SALT/);
+  });
+});
+
+describe('saltRedactionProfile', () => {
+  it('salts built-in profiles from their variable', () => {
+    const vendor = resolveRedactionProfile('vendor');
+    expect(
+      saltRedactionProfile(vendor, { KNOWGRAPH_REDACTION_SALT: 'pepper' }),
+    ).toMatchObject({ name: 'vendor', salt: 'pepper' });
+    expect(() => saltRedactionProfile(vendor, {})).toThrow(
+      /KNOWGRAPH_REDACTION_SALT/,
+    );
+    const partner = { name: 'partner', rules: [] };
+    expect(saltRedactionProfile(partner, {})).toBe(partner);
+  });
+});
+
+describe('formatRedactionPreview', () => {
+  const profile = {
+    name: 'partner',
+    rules: [{ field: 'owner', action: 'hash' as const }],
+  };
+  const entity = createEntity({ owner: 'jane@example.com' });
+  const preview = previewRedaction(
+    [entity, createEntity({ id: 'test-id-2', owner: null })],
+    profile,
+  );
+
+  it('counts each rule and diffs the export against the unredacted one', () => {
+    const before = formatExport([entity], 'markdown');
+    const after = formatExport([createRedactor(profile)(entity)], 'markdown');
+    const output = formatRedactionPreview(
+      preview,
+      fileChange('CODEBASE.md', before, after),
+    );
+
+    expect(output).toContain('Redaction preview: partner');
+    expect(output).toContain('(1 of 2 entities redacted)');
+    expect(output).toContain('1. hash owner 1 entities, 1 values');
+    expect(output).toContain('owner');
+    expect(output).toMatch(/^\s+-.*jane@example\.com/m);
+    expect(output).toContain('Preview only: nothing was written.');
+  });
+
+  it('says when there is nothing to diff', () => {
+    expect(formatRedactionPreview(preview, undefined, true)).toContain(
+      'Binary format',
+    );
+    expect(formatRedactionPreview(preview, undefined)).toContain('identical');
+  });
+});
+
+describe('readGraphNames', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-names-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('reads aliases and renames from the manifest', () => {
+    const configPath = join(dir, '.knowgraph.yml');
+    writeFileSync(
+      configPath,
+      [
+        'version: "1.0"',
+        'aliases:',
+        '  user-service: [users, user-svc]',
+        'renames:',
+        '  - from: accounts',
+        '    to: user-service',
+        '',
+      ].join('\n'),
+    );
+    expect(readGraphNames(configPath)).toEqual({
+      aliases: { 'user-service': ['users', 'user-svc'] },
+      renames: [{ from: 'accounts', to: 'user-service' }],
+    });
+  });
+
+  it('is empty without a manifest', () => {
+    expect(readGraphNames(join(dir, '.knowgraph.yml'))).toEqual({});
+  });
+
+  it('reads edge rules with the kinds they add', () => {
+    const configPath = join(dir, '.knowgraph.yml');
+    writeFileSync(
+      configPath,
+      [
+        'version: "1.0"',
+        'edge_rules:',
+        '  shares_datastore:',
+        '    shared: dependencies.databases',
+        '    from: { type: service }',
+        '',
+      ].join('\n'),
+    );
+    expect(readEdgeRules(configPath)).toEqual([
+      {
+        kind: 'shares_datastore',
+        shared: 'dependencies.databases',
+        from: { type: 'service' },
+      },
+    ]);
+    expect(readEdgeRules(join(dir, 'missing.yml'))).toEqual([]);
+  });
+});
+
+describe('parseEdgeFilter', () => {
+  it('parses --provenance and --min-confidence', () => {
+    expect(parseEdgeFilter('declared,import', '0.8')).toEqual({
+      provenance: ['declared', 'import'],
+      minConfidence: 0.8,
+    });
+    expect(parseEdgeFilter(undefined, '1')).toEqual({ minConfidence: 1 });
+    expect(parseEdgeFilter(undefined, undefined)).toBeUndefined();
+  });
+
+  it('throws usage errors on bad values', () => {
+    expect(() => parseEdgeFilter('guess', undefined)).toThrow(
+      'Invalid provenance "guess"',
+    );
+    for (const value of ['', 'high', '1.5', '-0.1']) {
+      expect(() => parseEdgeFilter(undefined, value)).toThrow(
+        `Invalid --min-confidence '${value}'`,
+      );
+    }
+  });
+
+  it('parses --protocol and --criticality of annotated dependencies', () => {
+    expect(
+      parseEdgeFilter(undefined, undefined, {
+        protocol: 'grpc, http',
+        criticality: 'critical,high',
+      }),
+    ).toEqual({
+      protocols: ['grpc', 'http'],
+      criticalities: ['critical', 'high'],
+    });
+    expect(() =>
+      parseEdgeFilter(undefined, undefined, { criticality: 'urgent' }),
+    ).toThrow("Invalid --criticality 'urgent'");
+    expect(() =>
+      parseEdgeFilter(undefined, undefined, { protocol: ',' }),
+    ).toThrow("Invalid --protocol ','");
+  });
+});
+
+describe('resolvePruneOptions', () => {
+  it('prefers flags over the manifest', () => {
+    expect(
+      resolvePruneOptions(
+        { minSignificance: '2' },
+        { minSignificance: 1, maxNodes: 500 },
+      ),
+    ).toEqual({ minSignificance: 2, maxNodes: 500 });
+    expect(resolvePruneOptions({ collapseFunctions: true }, {})).toEqual({
+      collapseFunctions: true,
+    });
+    expect(resolvePruneOptions({}, {})).toBeUndefined();
+  });
+
+  it('throws usage errors on bad counts', () => {
+    expect(() => resolvePruneOptions({ maxNodes: '0' }, {})).toThrow(
+      "Invalid --max-nodes '0'",
+    );
+    expect(() => resolvePruneOptions({ minSignificance: 'x' }, {})).toThrow(
+      "Invalid --min-significance 'x'",
+    );
+  });
+});
@@ -0,0 +1,96 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { Command } from 'commander';
+import type { FreshnessEntry, FreshnessReport } from '@know-graph/core';
+import {
+  formatFreshnessReport,
+  registerFreshnessCommand,
+} from '../commands/freshness.js';
+
+function entry(
+  name: string,
+  overrides: Partial<FreshnessEntry> = {},
+): FreshnessEntry {
+  return {
+    entityId: name,
+    name,
+    entityType: 'service',
+    filePath: `src/${name}.ts`,
+    line: 1,
+    owner: 'payments-team',
+    revenueImpact: 'critical',
+    months: 3,
+    reviewedAt: '2024-01-10',
+    reviewedFrom: 'last_reviewed',
+    dueAt: '2024-04-10',
+    daysLeft: -66,
+    state: 'overdue',
+    ...overrides,
+  };
+}
+
+const report: FreshnessReport = {
+  generatedAt: '2024-06-15T12:00:00.000Z',
+  entries: [
+    entry('CheckoutService'),
+    entry('Ledger', { dueAt: '2024-07-01', daysLeft: 16, state: 'due-soon' }),
+    entry('Search', {
+      revenueImpact: 'high',
+      dueAt: '2024-11-01',
+      daysLeft: 139,
+      state: 'current',
+    }),
+  ],
+  overdue: 1,
+  dueSoon: 1,
+};
+
+describe('freshness command', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerFreshnessCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'freshness', ...args]);
+  }
+
+  it('lists overdue and due-soon nodes', () => {
+    const output = formatFreshnessReport(report);
+    expect(output).toContain('1 annotations are overdue for review');
+    expect(output).toContain('overdue since 2024-04-10 (66 days)');
+    expect(output).toContain('due 2024-07-01 (in 16 days)');
+    expect(output).not.toContain('Search');
+    expect(formatFreshnessReport(report, true)).toContain('Search');
+  });
+
+  it('says so when no entity has a deadline', () => {
+    const empty = { ...report, entries: [], overdue: 0, dueSoon: 0 };
+    expect(formatFreshnessReport(empty)).toContain('set freshness.deadlines');
+  });
+
+  it('rejects a reminder window that is not whole days', async () => {
+    await run('--remind-days', 'soon');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('rejects an unknown sink type', async () => {
+    await run('--notify', 'pager');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    await run('--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+});
@@ -0,0 +1,93 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  appendFileSync,
+  copyFileSync,
+  mkdirSync,
+  mkdtempSync,
+  rmSync,
+} from 'node:fs';
+import { join, resolve } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerFsckCommand } from '../commands/fsck.js';
+import { indexInto } from '../utils/indexing.js';
+
+const SAMPLE = resolve(__dirname, 'fixtures', 'sample.ts');
+
+describe('fsck command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-fsck-'));
+    mkdirSync(join(dir, 'src'));
+    copyFileSync(SAMPLE, join(dir, 'src', 'sample.ts'));
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerFsckCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'fsck',
+      dir,
+      '--db',
+      dbPath,
+      ...args,
+    ]);
+  }
+
+  function output(): string {
+    return consoleLogSpy.mock.calls.map((call) => String(call[0])).join('\n');
+  }
+
+  it('passes when the index matches source', async () => {
+    await run();
+    expect(process.exitCode).toBeUndefined();
+    expect(output()).toContain('The index matches source');
+  });
+
+  it('fails on a file changed since it was indexed', async () => {
+    appendFileSync(join(dir, 'src', 'sample.ts'), '\n// edited\n');
+    await run('--format', 'json');
+    expect(process.exitCode).toBe(1);
+    expect(JSON.parse(output()).issues).toEqual([
+      {
+        kind: 'stale',
+        subject: 'src/sample.ts',
+        message: 'Changed since it was indexed',
+        repairable: true,
+      },
+    ]);
+  });
+
+  it('re-indexes what diverged with --repair', async () => {
+    appendFileSync(join(dir, 'src', 'sample.ts'), '\n// edited\n');
+    await run('--repair', '--format', 'json');
+    expect(process.exitCode).toBeUndefined();
+    const report = JSON.parse(output());
+    expect(report.issues).toEqual([]);
+    expect(report.repaired).toEqual({
+      filesCleared: ['src/sample.ts'],
+      rowsDeleted: 0,
+    });
+  });
+});
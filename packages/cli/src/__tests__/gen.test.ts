@@ -0,0 +1,72 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import type { TestData } from '@know-graph/core';
+import { registerGenCommand } from '../commands/gen.js';
+
+describe('gen testdata command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-gen-'));
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerGenCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'gen',
+      'testdata',
+      '--output',
+      join(dir, 'out'),
+      ...args,
+    ]);
+  }
+
+  it('writes a repository and snapshot of the requested size', async () => {
+    await run('--nodes', '30', '--edges', '40', '--format', 'json');
+    const data = JSON.parse(
+      String(consoleLogSpy.mock.calls[0]?.[0]),
+    ) as TestData;
+    expect(data).toMatchObject({ files: 10, nodes: 30, edges: 40 });
+    expect(existsSync(join(dir, 'out', 'repo', 'src', 'd0'))).toBe(true);
+    expect(existsSync(join(dir, 'out', 'graph.kgs'))).toBe(true);
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('names the repository and snapshot in text output', async () => {
+    await run('--nodes', '6', '--edges', '1');
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('Wrote 6 nodes and 1 edges in 2 files');
+    expect(output).toContain(join(dir, 'out', 'graph.kgs'));
+  });
+
+  it('rejects counts that are not integers as a usage error', async () => {
+    await run('--nodes', 'many');
+    expect(process.exitCode).toBe(2);
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain('--nodes');
+  });
+
+  it('rejects more edges than the services can hold', async () => {
+    await run('--nodes', '9', '--edges', '4');
+    expect(process.exitCode).toBe(2);
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain(
+      'at most 3 fit',
+    );
+  });
+});
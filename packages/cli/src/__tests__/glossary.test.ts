@@ -0,0 +1,58 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { TermLinks } from '@know-graph/core';
+import {
+  formatGlossarySummary,
+  formatTermLinks,
+  registerGlossaryCommand,
+} from '../commands/glossary.js';
+
+const chargeback: TermLinks = {
+  term: {
+    term: 'chargeback',
+    definition: 'A payment reversal initiated by the card issuer',
+    aliases: ['dispute'],
+  },
+  mentions: [
+    {
+      entityId: 'a',
+      name: 'handleChargeback',
+      entityType: 'function',
+      filePath: 'src/chargebacks.ts',
+      line: 12,
+      owner: 'payments-team',
+      matchedAs: 'chargeback',
+    },
+  ],
+};
+
+describe('glossary command', () => {
+  it('registers the glossary command', () => {
+    const program = new Command();
+    registerGlossaryCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'glossary');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toContain('--glossary');
+  });
+
+  it('shows a term with its definition and related entities', () => {
+    const output = formatTermLinks(chargeback);
+    expect(output).toContain('chargeback');
+    expect(output).toContain('aka dispute');
+    expect(output).toContain('A payment reversal initiated by the card issuer');
+    expect(output).toContain('handleChargeback (function)');
+    expect(output).toContain('src/chargebacks.ts:12');
+  });
+
+  it('notes terms with no mentions', () => {
+    const output = formatTermLinks({ ...chargeback, mentions: [] });
+    expect(output).toContain('No entities mention this term.');
+  });
+
+  it('summarizes mention counts per term', () => {
+    const output = formatGlossarySummary([chargeback]);
+    expect(output).toContain('chargeback');
+    expect(output).toContain('entities');
+    expect(formatGlossarySummary([])).toBe('Glossary defines no terms.');
+  });
+});
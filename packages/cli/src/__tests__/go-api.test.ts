@@ -0,0 +1,86 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { GoApiChange, GoApiSurface } from '@know-graph/core';
+import { formatGoApiChanges, formatGoApiSurface } from '../commands/go-api.js';
+import { readGoApiSurface, writeGoApiSurface } from '../utils/go-api.js';
+
+const surface: GoApiSurface = {
+  version: 1,
+  packages: [
+    {
+      importPath: 'example.com/app/pkg/client',
+      dir: 'pkg/client',
+      stable: true,
+      symbols: [
+        { name: 'New', kind: 'func', signature: 'func New(Options) *Client' },
+      ],
+    },
+  ],
+};
+
+const changes: readonly GoApiChange[] = [
+  {
+    importPath: 'example.com/app/pkg/client',
+    dir: 'pkg/client',
+    symbol: 'Dial',
+    kind: 'func',
+    change: 'added',
+    before: null,
+    after: 'func Dial(string) (*Client, error)',
+    breaking: false,
+  },
+  {
+    importPath: 'example.com/app/pkg/client',
+    dir: 'pkg/client',
+    symbol: 'New',
+    kind: 'func',
+    change: 'changed',
+    before: 'func New(Options) *Client',
+    after: 'func New(context.Context, Options) *Client',
+    breaking: true,
+  },
+];
+
+describe('go-api formatting', () => {
+  it('lists packages with their stability and export counts', () => {
+    const output = formatGoApiSurface(surface);
+    expect(output).toContain('Go packages: 1');
+    expect(output).toContain('example.com/app/pkg/client');
+    expect(output).toContain('1 exported');
+  });
+
+  it('lists breaking changes first with both signatures', () => {
+    const lines = formatGoApiChanges(changes).split('\n');
+    expect(lines[0]).toContain('1 breaking change(s) in stable packages');
+    expect(lines[1]).toContain('BREAKING example.com/app/pkg/client');
+    expect(lines[2]).toContain('before: func New(Options) *Client');
+    expect(lines[4]).toContain('Dial added');
+    expect(formatGoApiChanges([])).toContain('No exported API changes');
+  });
+});
+
+describe('recorded Go API files', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-go-api-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('round-trips a surface', () => {
+    const path = join(dir, 'api', 'go-api.json');
+    writeGoApiSurface(path, surface);
+    expect(readGoApiSurface(path)).toEqual(surface);
+  });
+
+  it('rejects files of another shape', () => {
+    const path = join(dir, 'go-api.json');
+    writeFileSync(path, JSON.stringify({ version: 1, packages: [{}] }));
+    expect(() => readGoApiSurface(path)).toThrow(/not a version 1/);
+  });
+});
@@ -0,0 +1,61 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { DependencyGraph, GraphNode } from '@know-graph/core';
+import {
+  formatGoPackages,
+  registerGoPackagesCommand,
+} from '../commands/go-packages.js';
+
+function makeNode(name: string, annotated?: boolean): GraphNode {
+  return {
+    id: `go:${name}`,
+    name,
+    entityType: null,
+    external: false,
+    filePath: name,
+    owner: annotated ? 'api-team' : null,
+    domain: null,
+    workspace: null,
+    annotated,
+  };
+}
+
+describe('go-packages command', () => {
+  it('registers the go-packages command', () => {
+    const program = new Command();
+    registerGoPackagesCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'go-packages');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toContain('--db');
+  });
+
+  it('lists packages, imports, and unannotated packages', () => {
+    const graph: DependencyGraph = {
+      nodes: [
+        makeNode('example.com/api', true),
+        makeNode('example.com/api/store', false),
+        { ...makeNode('checkout'), id: 'id-checkout' },
+      ],
+      edges: [
+        {
+          from: 'go:example.com/api',
+          to: 'go:example.com/api/store',
+          kind: 'import',
+        },
+      ],
+    };
+    const output = formatGoPackages(graph);
+    expect(output).toContain('Go packages: 2');
+    expect(output).toContain('1 unannotated');
+    expect(output).toContain('owner: api-team');
+    expect(output).toContain('example.com/api/store (unannotated)');
+    expect(output).toContain('imports: example.com/api/store');
+    expect(output).not.toContain('checkout');
+  });
+
+  it('explains when no packages are found', () => {
+    expect(formatGoPackages({ nodes: [], edges: [] })).toContain(
+      'No Go packages found',
+    );
+  });
+});
@@ -0,0 +1,102 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import type { GoTestSelection } from '@know-graph/core';
+import {
+  formatGoTestArgs,
+  formatGoTestSelection,
+  goTestPatterns,
+  registerGoTestsCommand,
+} from '../commands/go-tests.js';
+
+const SELECTION: GoTestSelection = {
+  full: false,
+  reason: null,
+  packages: [
+    { importPath: 'example.com/shop', dir: '.', changed: false },
+    { importPath: 'example.com/shop/billing', dir: 'billing', changed: true },
+  ],
+  unmapped: [],
+  coverage: 1,
+};
+
+describe('go-tests command', () => {
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+  let dir: string;
+
+  beforeEach(() => {
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+    dir = mkdtempSync(join(tmpdir(), 'kg-go-tests-'));
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerGoTestsCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'go-tests', ...args]);
+  }
+
+  it('fails with a usage error without changed files', async () => {
+    await run('--root', dir);
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with a usage error on an out-of-range --min-coverage', async () => {
+    await run('a.go', '--min-coverage', '80');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('prints go test arguments without an index', async () => {
+    writeFileSync(join(dir, 'go.mod'), 'module example.com/shop\n');
+    mkdirSync(join(dir, 'billing'));
+    writeFileSync(join(dir, 'billing', 'billing.go'), 'package billing\n');
+    writeFileSync(
+      join(dir, 'billing', 'billing_test.go'),
+      '//go:build integration\n\npackage billing\n',
+    );
+    writeFileSync(
+      join(dir, 'main.go'),
+      'package main\n\nimport "example.com/shop/billing"\n',
+    );
+    await run(
+      'billing/billing.go',
+      '--root',
+      dir,
+      '--db',
+      join(dir, 'missing.db'),
+      '--format',
+      'args',
+    );
+    expect(process.exitCode).toBeUndefined();
+    expect(consoleLogSpy).toHaveBeenCalledWith('-tags=integration . ./billing');
+  });
+
+  it('formats patterns, arguments, and a summary', () => {
+    expect(goTestPatterns(SELECTION)).toEqual(['.', './billing']);
+    expect(goTestPatterns({ ...SELECTION, full: true })).toEqual(['./...']);
+    expect(formatGoTestArgs({ ...SELECTION, packages: [] }, [])).toBe('');
+    const text = formatGoTestSelection(SELECTION, ['integration']);
+    expect(text).toContain('Go test packages: 2');
+    expect(text).toContain('example.com/shop/billing');
+    expect(text).toContain('Tags: integration');
+    expect(
+      formatGoTestSelection(
+        { ...SELECTION, full: true, reason: 'go.mod changed' },
+        [],
+      ),
+    ).toContain('Running all Go tests: go.mod changed');
+  });
+});
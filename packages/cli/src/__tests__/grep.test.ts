@@ -0,0 +1,140 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { createDatabaseManager } from '@know-graph/core';
+import type { CodeSearchResult } from '@know-graph/core';
+import { formatGrepResult, registerGrepCommand } from '../commands/grep.js';
+
+describe('grep command', () => {
+  let dir: string;
+  let dbPath: string;
+  let logSpy: ReturnType<typeof vi.spyOn>;
+  let errorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-grep-'));
+    dbPath = join(dir, 'knowgraph.db');
+    mkdirSync(join(dir, 'src'));
+    writeFileSync(
+      join(dir, 'src', 'passwords.ts'),
+      '/** @knowgraph */\nexport const hash = (p: string) => bcrypt.hash(p);\n',
+    );
+    writeFileSync(
+      join(dir, 'src', 'cards.ts'),
+      '/** @knowgraph */\nexport const salt = bcrypt.genSaltSync();\n',
+    );
+    const dbManager = createDatabaseManager(dbPath);
+    dbManager.initialize();
+    dbManager.insertEntity({
+      filePath: 'src/passwords.ts',
+      name: 'passwords',
+      entityType: 'module',
+      description: 'Password hashing',
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      owner: 'auth-team',
+      metadata: {
+        type: 'module',
+        description: 'Password hashing',
+        compliance: { data_sensitivity: 'confidential' },
+      },
+    });
+    dbManager.insertEntity({
+      filePath: 'src/cards.ts',
+      name: 'cards',
+      entityType: 'module',
+      description: 'Card salts',
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      owner: 'billing-team',
+      metadata: { type: 'module', description: 'Card salts' },
+    });
+    dbManager.close();
+    logSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    logSpy.mockRestore();
+    errorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  async function run(...args: string[]): Promise<void> {
+    const program = new Command();
+    registerGrepCommand(program);
+    await program.parseAsync(['node', 'knowgraph', 'grep', ...args]);
+  }
+
+  it('groups hits under their node', () => {
+    const result: CodeSearchResult = {
+      hits: [
+        {
+          filePath: 'src/passwords.ts',
+          line: 2,
+          column: 37,
+          text: 'export const hash = (p: string) => bcrypt.hash(p, 12);',
+          node: {
+            id: 'passwords',
+            name: 'passwords',
+            entityType: 'module',
+            owner: 'auth-team',
+            line: 1,
+          },
+        },
+      ],
+      files: 1,
+      unreadable: ['src/gone.ts'],
+      truncated: true,
+    };
+    const output = formatGrepResult(result);
+    expect(output).toContain(
+      'passwords (module, auth-team) src/passwords.ts:1',
+    );
+    expect(output).toContain('src/passwords.ts:2: export const hash');
+    expect(output).toContain(
+      '1 hit(s) in 1 node(s) across 1 file(s) (limit reached)',
+    );
+    expect(output).toContain('1 indexed file(s) could not be read');
+    expect(formatGrepResult({ ...result, hits: [] })).toContain('No matches.');
+  });
+
+  it('searches only nodes matching --where', async () => {
+    await run(
+      'bcrypt',
+      dir,
+      '--db',
+      dbPath,
+      '--where',
+      'owner=auth-team AND compliance.data_sensitivity=confidential',
+      '--format',
+      'json',
+    );
+    expect(process.exitCode).toBeUndefined();
+    const result = JSON.parse(String(logSpy.mock.calls[0][0]));
+    expect(
+      result.hits.map((hit: { filePath: string; line: number }) => [
+        hit.filePath,
+        hit.line,
+      ]),
+    ).toEqual([['src/passwords.ts', 2]]);
+    expect(result.hits[0].node.owner).toBe('auth-team');
+  });
+
+  it('rejects an invalid pattern or query with exit 2', async () => {
+    await run('bcrypt(', dir, '--db', dbPath);
+    expect(process.exitCode).toBe(2);
+    process.exitCode = undefined;
+    await run('bcrypt', dir, '--db', dbPath, '--where', 'owner');
+    expect(process.exitCode).toBe(2);
+    expect(String(errorSpy.mock.calls[1][0])).toContain('Invalid query term');
+  });
+});
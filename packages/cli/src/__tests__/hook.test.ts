@@ -21,6 +21,9 @@ import {
   installHook,
   uninstallHook,
   getHookStatus,
+  getChangedSince,
+  getStagedFiles,
+  appendTrailerSuggestions,
 } from '../commands/hook.js';
 
 vi.mock('node:fs');
@@ -180,6 +183,18 @@ describe('hook command', () => {
       expect(result.alreadyInstalled).toBe(true);
     });
 
+    it('returns the hook without writing it in a dry run', () => {
+      mockedChildProcess.execSync.mockReturnValue('/my/repo\n');
+      mockedFs.existsSync.mockReturnValue(false);
+
+      const result = installHook({ dryRun: true });
+
+      expect(result.success).toBe(true);
+      expect(result.content).toContain(HOOK_MARKER_START);
+      expect(mockedFs.mkdirSync).not.toHaveBeenCalled();
+      expect(mockedFs.writeFileSync).not.toHaveBeenCalled();
+    });
+
     it('returns error when not in a git repository', () => {
       mockedChildProcess.execSync.mockImplementation(() => {
         throw new Error('not a git repo');
@@ -306,4 +321,63 @@ describe('hook command', () => {
       expect(result.error).toContain('git repository');
     });
   });
+
+  describe('getStagedFiles', () => {
+    it('lists the staged files', () => {
+      mockedChildProcess.execSync.mockReturnValue('src/a.ts\nsrc/b.ts\n');
+      expect(getStagedFiles()).toEqual(['src/a.ts', 'src/b.ts']);
+    });
+
+    it('returns nothing outside a git repository', () => {
+      mockedChildProcess.execSync.mockImplementation(() => {
+        throw new Error('not a git repo');
+      });
+      expect(getStagedFiles()).toEqual([]);
+    });
+  });
+
+  describe('getChangedSince', () => {
+    it('lists the files changed since the merge base', () => {
+      mockedChildProcess.execFileSync.mockReturnValue('go.mod\napi/x.go\n');
+      expect(getChangedSince('origin/main')).toEqual(['go.mod', 'api/x.go']);
+      expect(mockedChildProcess.execFileSync.mock.calls[0][1]).toEqual([
+        'diff',
+        '--name-only',
+        'origin/main...HEAD',
+      ]);
+    });
+
+    it('returns null for a ref git cannot diff against', () => {
+      mockedChildProcess.execFileSync.mockImplementation(() => {
+        throw new Error('unknown revision');
+      });
+      expect(getChangedSince('nope')).toBeNull();
+    });
+  });
+
+  describe('appendTrailerSuggestions', () => {
+    const trailers = ['Knowgraph-Node: src/a.ts:A'];
+
+    it('adds commented trailers above the git comment block', () => {
+      const message = 'Fix rounding\n\n# Please enter the commit message\n';
+      expect(appendTrailerSuggestions(message, trailers)).toBe(
+        [
+          'Fix rounding',
+          '',
+          '# Uncomment to link this commit to the nodes it changes:',
+          '# Knowgraph-Node: src/a.ts:A',
+          '# Please enter the commit message',
+          '',
+        ].join('\n'),
+      );
+    });
+
+    it('leaves messages that already link nodes alone', () => {
+      const message = 'Fix rounding\n\nKnowgraph-Node: Ledger\n';
+      expect(appendTrailerSuggestions(message, trailers)).toBe(message);
+      expect(appendTrailerSuggestions('Fix rounding\n', [])).toBe(
+        'Fix rounding\n',
+      );
+    });
+  });
 });
@@ -0,0 +1,139 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { createDatabaseManager } from '@know-graph/core';
+import type { HotspotReport } from '@know-graph/core';
+import {
+  formatHotspotHtml,
+  formatHotspotText,
+  registerHotspotsCommand,
+} from '../commands/hotspots.js';
+
+function git(cwd: string, ...args: string[]): void {
+  execFileSync(
+    'git',
+    ['-c', 'user.name=test', '-c', 'user.email=test@example.com', ...args],
+    { cwd },
+  );
+}
+
+const report: HotspotReport = {
+  churnThreshold: 3,
+  criticalImpacts: ['critical', 'high'],
+  entries: [
+    {
+      entityId: 'charges',
+      name: 'charges',
+      entityType: 'module',
+      filePath: 'src/charges.ts',
+      owner: 'payments-team',
+      revenueImpact: 'critical',
+      commits: 12,
+      authors: 3,
+      lastModified: '2024-06-30T12:00:00Z',
+      quadrant: 'hotspot',
+    },
+    {
+      entityId: 'avatars',
+      name: 'avatars <img>',
+      entityType: 'module',
+      filePath: 'src/avatars.ts',
+      owner: null,
+      revenueImpact: null,
+      commits: 0,
+      authors: 0,
+      lastModified: null,
+      quadrant: 'quiet',
+    },
+  ],
+  counts: { hotspot: 1, churning: 0, critical: 0, quiet: 1 },
+};
+
+describe('hotspots command', () => {
+  let dir: string;
+  let dbPath: string;
+  let errorSpy: ReturnType<typeof vi.spyOn>;
+  let writeSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-hotspots-'));
+    dbPath = join(dir, 'knowgraph.db');
+    errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    writeSpy = vi.spyOn(process.stdout, 'write').mockImplementation(() => true);
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    errorSpy.mockRestore();
+    writeSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  async function run(...args: string[]): Promise<void> {
+    const program = new Command();
+    registerHotspotsCommand(program);
+    await program.parseAsync(['node', 'knowgraph', 'hotspots', ...args]);
+  }
+
+  it('lists each quadrant with its modules, hotspots first', () => {
+    const output = formatHotspotText(report);
+    expect(output).toContain(
+      'High churn: 3+ commits. Critical: critical, high.',
+    );
+    expect(output).toContain('Hotspots: high churn, high criticality (1)');
+    expect(output).toContain('12  charges (critical, payments-team)');
+    expect(output).not.toContain('Churning');
+    expect(formatHotspotHtml(report)).toContain(
+      '<td>avatars &lt;img&gt;</td><td>-</td>',
+    );
+  });
+
+  it('measures churn from the git history as CSV', async () => {
+    execFileSync('git', ['init', '-q', dir]);
+    mkdirSync(join(dir, 'src'));
+    for (let i = 0; i < 3; i++) {
+      writeFileSync(join(dir, 'src', 'charges.ts'), `export const x = ${i};`);
+      git(dir, 'add', 'src');
+      git(dir, 'commit', '-qm', `change ${i}`);
+    }
+    const dbManager = createDatabaseManager(dbPath);
+    dbManager.initialize();
+    dbManager.insertEntity({
+      filePath: 'src/charges.ts',
+      name: 'charges',
+      entityType: 'module',
+      description: 'Card charges',
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      owner: 'payments-team',
+      metadata: {
+        type: 'module',
+        description: 'Card charges',
+        context: { revenue_impact: 'critical' },
+      },
+    });
+    dbManager.close();
+
+    await run(dir, '--db', dbPath, '--format', 'csv');
+    expect(process.exitCode).toBeUndefined();
+    const csv = String(writeSpy.mock.calls[0][0]).split('\n');
+    expect(csv[1]).toMatch(
+      /^hotspot,charges,module,src\/charges\.ts,payments-team,critical,3,1,/,
+    );
+  });
+
+  it('rejects an unknown revenue impact with exit 2', async () => {
+    await run(dir, '--db', dbPath, '--critical', 'critical,urgent');
+    expect(process.exitCode).toBe(2);
+    expect(String(errorSpy.mock.calls[0][0])).toContain(
+      'Invalid --critical "critical,urgent"',
+    );
+  });
+});
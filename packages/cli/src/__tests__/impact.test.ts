@@ -0,0 +1,80 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import type { BazelTargetSelection } from '@know-graph/core';
+import {
+  formatBazelImpact,
+  registerImpactCommand,
+} from '../commands/impact.js';
+
+describe('impact command', () => {
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+  let dir: string;
+
+  beforeEach(() => {
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+    dir = mkdtempSync(join(tmpdir(), 'kg-impact-'));
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerImpactCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'impact', ...args]);
+  }
+
+  it('fails with a usage error outside a Bazel workspace', async () => {
+    await run('src/a.ts', '--root', dir);
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('prints target patterns without bazel query', async () => {
+    writeFileSync(join(dir, 'MODULE.bazel'), '');
+    writeFileSync(join(dir, 'BUILD.bazel'), '');
+    mkdirSync(join(dir, 'libs', 'db'), { recursive: true });
+    writeFileSync(join(dir, 'libs', 'db', 'BUILD'), '');
+    await run(
+      'libs/db/db.go',
+      '--root',
+      dir,
+      '--db',
+      join(dir, 'missing.db'),
+      '--no-bazel-query',
+      '--format',
+      'bazel',
+    );
+    expect(process.exitCode).toBeUndefined();
+    expect(consoleLogSpy).toHaveBeenCalledWith('//libs/db:all');
+  });
+
+  it('summarizes affected packages or the reason for a full run', () => {
+    const selection: BazelTargetSelection = {
+      full: false,
+      reason: null,
+      packages: [{ label: '//libs/db', path: 'libs/db', changed: true }],
+      unmapped: ['docs/diagram.svg'],
+      coverage: 0.5,
+    };
+    const text = formatBazelImpact(selection);
+    expect(text).toContain('Affected Bazel packages: 1');
+    expect(text).toContain('//libs/db');
+    expect(text).toContain('Not in the graph: docs/diagram.svg');
+    const full = { ...selection, full: true, reason: 'WORKSPACE changed' };
+    expect(formatBazelImpact(full)).toContain(
+      'Testing every target: WORKSPACE changed',
+    );
+  });
+});
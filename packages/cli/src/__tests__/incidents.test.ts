@@ -0,0 +1,135 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import {
+  formatIncidentReport,
+  incidentLogPath,
+  registerIncidentsCommand,
+} from '../commands/incidents.js';
+
+const FIREHYDRANT_LIST = {
+  data: [
+    {
+      id: 'fh-1',
+      name: 'Checkout errors after deploy',
+      severity: 'SEV1',
+      started_at: '2024-05-02T10:00:00Z',
+      services: [{ name: 'checkout' }],
+    },
+  ],
+};
+
+describe('incidents command', () => {
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+  let dir: string;
+
+  beforeEach(() => {
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+    dir = mkdtempSync(join(tmpdir(), 'kg-incidents-'));
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    vi.unstubAllEnvs();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerIncidentsCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'incidents', ...args]);
+  }
+
+  it('registers the import and report subcommands', () => {
+    const program = new Command();
+    registerIncidentsCommand(program);
+    const command = program.commands.find((c) => c.name() === 'incidents');
+    expect(command!.commands.map((c) => c.name())).toEqual([
+      'import',
+      'report',
+    ]);
+  });
+
+  it('resolves the log next to the manifest', () => {
+    const config = join(dir, '.knowgraph.yml');
+    writeFileSync(config, 'version: "1.0"\nincidents:\n  path: inc.jsonl\n');
+    expect(incidentLogPath(config)).toBe(join(dir, 'inc.jsonl'));
+  });
+
+  it('imports a saved FireHydrant response into the log', async () => {
+    const config = join(dir, '.knowgraph.yml');
+    const saved = join(dir, 'incidents.json');
+    writeFileSync(saved, JSON.stringify(FIREHYDRANT_LIST));
+    await run('import', saved, '--config', config);
+    expect(process.exitCode).toBeUndefined();
+    const log = join(dir, '.knowgraph', 'incidents.jsonl');
+    expect(JSON.parse(readFileSync(log, 'utf-8'))).toMatchObject({
+      id: 'fh-1',
+      source: 'firehydrant',
+      services: ['checkout'],
+    });
+  });
+
+  it('fails with a usage error when fetching without a source', async () => {
+    await run('import');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with a usage error when the API key is unset', async () => {
+    vi.stubEnv('INCIDENT_IO_API_KEY', '');
+    await run('import', '--source', 'incidentio');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with a usage error on a malformed --since', async () => {
+    await run('report', '--since', 'last week');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    await run('report', '--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+
+  it('lists ranked nodes with their incidents', () => {
+    const text = formatIncidentReport(
+      {
+        rankings: [
+          {
+            entityId: 'id-checkout',
+            name: 'checkout',
+            entityType: 'service',
+            filePath: 'src/checkout.ts',
+            owner: 'payments',
+            revenueImpact: 'critical',
+            incidents: [
+              {
+                id: 'fh-1',
+                title: 'Checkout errors after deploy',
+                severity: 'SEV1',
+                startedAt: '2024-05-02T10:00:00.000Z',
+                url: null,
+              },
+            ],
+            weight: 5,
+            score: 5,
+          },
+        ],
+        unmatched: [],
+      },
+      20,
+    );
+    expect(text).toContain('checkout');
+    expect(text).toContain('Checkout errors after deploy [SEV1]');
+    expect(text).toContain('1 × critical 5');
+  });
+});
@@ -1,11 +1,24 @@
 import { describe, it, expect, afterEach } from 'vitest';
-import { resolve, join } from 'node:path';
-import { mkdirSync, rmSync, existsSync } from 'node:fs';
+import { dirname, resolve, join } from 'node:path';
+import { mkdirSync, rmSync, existsSync, writeFileSync } from 'node:fs';
 import {
   createDefaultRegistry,
   createDatabaseManager,
   createIndexer,
 } from '@know-graph/core';
+import {
+  formatAnnotationConflicts,
+  formatEnricherRuns,
+} from '../commands/index-cmd.js';
+import {
+  readAnnotationLayers,
+  readConfigHash,
+  readEncryptionOptions,
+  readEnrichers,
+  readEnrichmentRateLimits,
+} from '../utils/manifest.js';
+import { indexInto } from '../utils/indexing.js';
+import { defaultCacheDir, resolveScanTarget } from '../utils/remote.js';
 
 const FIXTURES_DIR = resolve(__dirname, 'fixtures');
 const TEMP_DIR = resolve(__dirname, '.tmp-test-output');
@@ -103,3 +116,286 @@ describe('index command logic', () => {
     dbManager.close();
   });
 });
+
+describe('readConfigHash', () => {
+  it('changes when the manifest or index settings change', () => {
+    mkdirSync(TEMP_DIR, { recursive: true });
+    const configPath = join(TEMP_DIR, '.knowgraph.yml');
+    const missing = readConfigHash(configPath);
+
+    writeFileSync(configPath, 'version: "1.0"\ni18n:\n  default_locale: en\n');
+    const configured = readConfigHash(configPath);
+    expect(configured).not.toBe(missing);
+    expect(readConfigHash(configPath)).toBe(configured);
+    expect(readConfigHash(configPath, { exclude: ['vendor'] })).not.toBe(
+      configured,
+    );
+  });
+});
+
+describe('readEncryptionOptions', () => {
+  const key = 'ab'.repeat(32);
+
+  it('reads the key from key_env or key_command', () => {
+    mkdirSync(TEMP_DIR, { recursive: true });
+    const configPath = join(TEMP_DIR, '.knowgraph.yml');
+    expect(readEncryptionOptions(configPath)).toEqual({});
+
+    writeFileSync(
+      configPath,
+      'version: "1.0"\nencryption:\n  key_env: KG_STORE_KEY\n',
+    );
+    expect(
+      readEncryptionOptions(configPath, { KG_STORE_KEY: key }).encryptionKey,
+    ).toEqual(Buffer.from(key, 'hex'));
+    expect(() => readEncryptionOptions(configPath, {})).toThrow(
+      /KG_STORE_KEY/,
+    );
+
+    writeFileSync(
+      configPath,
+      `version: "1.0"\nencryption:\n  key_command: [node, -e, "console.log('${key}')"]\n`,
+    );
+    expect(readEncryptionOptions(configPath).encryptionKey).toEqual(
+      Buffer.from(key, 'hex'),
+    );
+  });
+
+  it('throws on an invalid manifest instead of writing plain files', () => {
+    mkdirSync(TEMP_DIR, { recursive: true });
+    const configPath = join(TEMP_DIR, '.knowgraph.yml');
+    writeFileSync(
+      configPath,
+      'version: "1.0"\nencryption:\n  key_env: A\n  key_command: [b]\n',
+    );
+    expect(() => readEncryptionOptions(configPath)).toThrow(/Invalid/);
+  });
+});
+
+describe('enrichers', () => {
+  it('reads the configured order with enabled defaulting to true', () => {
+    mkdirSync(TEMP_DIR, { recursive: true });
+    const configPath = join(TEMP_DIR, '.knowgraph.yml');
+    expect(readEnrichers(configPath)).toBeUndefined();
+
+    writeFileSync(
+      configPath,
+      [
+        'version: "1.0"',
+        'enrichers:',
+        '  - name: git',
+        '  - name: routes',
+        '    enabled: false',
+        '    paths: [src/api/]',
+        '',
+      ].join('\n'),
+    );
+    expect(readEnrichers(configPath)).toEqual([
+      { name: 'git', enabled: true },
+      { name: 'routes', enabled: false, paths: ['src/api/'] },
+    ]);
+  });
+
+  it('reads batching, rate limits, and cache lifetimes', () => {
+    mkdirSync(TEMP_DIR, { recursive: true });
+    const configPath = join(TEMP_DIR, '.knowgraph.yml');
+    expect(readEnrichmentRateLimits(configPath)).toEqual({});
+
+    writeFileSync(
+      configPath,
+      [
+        'version: "1.0"',
+        'enrichment:',
+        '  rate_limits:',
+        '    github: { requests_per_minute: 80 }',
+        'enrichers:',
+        '  - name: owners',
+        '    batch_size: 50',
+        '    rate_limit: github',
+        '    cache_ttl_ms: 86400000',
+        '',
+      ].join('\n'),
+    );
+    expect(readEnrichers(configPath)).toEqual([
+      {
+        name: 'owners',
+        enabled: true,
+        batchSize: 50,
+        rateLimit: 'github',
+        cacheTtlMs: 86_400_000,
+      },
+    ]);
+    expect(readEnrichmentRateLimits(configPath)).toEqual({
+      github: { requestsPerMinute: 80 },
+    });
+  });
+
+  it('runs only the enrichers asked for, with their manifest settings', () => {
+    mkdirSync(TEMP_DIR, { recursive: true });
+    writeFileSync(
+      join(TEMP_DIR, '.knowgraph.yml'),
+      'version: "1.0"\nenrichers:\n  - name: git\n    enabled: false\n',
+    );
+    const dbPath = join(TEMP_DIR, '.knowgraph', 'knowgraph.db');
+    mkdirSync(dirname(dbPath), { recursive: true });
+    const settings = { incremental: false };
+    expect(
+      indexInto(TEMP_DIR, dbPath, { ...settings, enrichers: ['git'] }).runs,
+    ).toEqual([{ name: 'git', status: 'skipped', updated: 0, ms: 0 }]);
+    expect(
+      indexInto(TEMP_DIR, dbPath, { ...settings, enrichers: [] }).runs,
+    ).toEqual([]);
+    expect(() =>
+      indexInto(TEMP_DIR, dbPath, { ...settings, enrichers: ['callgraph'] }),
+    ).toThrow("Unknown enricher 'callgraph'");
+  });
+
+  it('runs the built-in call and route enrichers on the indexed code', () => {
+    mkdirSync(join(TEMP_DIR, 'src'), { recursive: true });
+    writeFileSync(
+      join(TEMP_DIR, '.knowgraph.yml'),
+      'version: "1.0"\nenrichers:\n  - name: calls\n  - name: routes\n',
+    );
+    const annotated = (name: string, code: string) =>
+      [
+        '/**',
+        ' * @knowgraph',
+        ' * type: function',
+        ` * description: ${name}`,
+        ' */',
+        code,
+        '',
+      ].join('\n');
+    writeFileSync(
+      join(TEMP_DIR, 'src', 'api.ts'),
+      annotated(
+        'register',
+        "export function register() {\n  app.get('/users', listUsers);\n}",
+      ) +
+        annotated('listUsers', 'export function listUsers() {\n  load();\n}'),
+    );
+    writeFileSync(
+      join(TEMP_DIR, 'src', 'store.ts'),
+      annotated('load', 'export function load() {}'),
+    );
+    const dbPath = join(TEMP_DIR, '.knowgraph', 'knowgraph.db');
+    mkdirSync(dirname(dbPath), { recursive: true });
+
+    const { runs } = indexInto(TEMP_DIR, dbPath, { incremental: false });
+    expect(runs.map((run) => [run.name, run.status, run.updated])).toEqual([
+      ['calls', 'ok', 1],
+      ['routes', 'ok', 1],
+    ]);
+    const dbManager = createDatabaseManager(dbPath);
+    try {
+      const metadata = (name: string) =>
+        dbManager
+          .getEntitiesByFilePath('src/api.ts')
+          .find((entity) => entity.name === name)?.metadata;
+      expect(metadata('register')).toMatchObject({ routes: ['GET /users'] });
+      expect(metadata('listUsers')).toMatchObject({
+        calls: ['src/store.ts:load'],
+      });
+    } finally {
+      dbManager.close();
+    }
+  });
+
+  it('formats a line per enricher with its outcome', () => {
+    const output = formatEnricherRuns([
+      { name: 'git', status: 'ok', updated: 12, ms: 40.4 },
+      { name: 'routes', status: 'skipped', updated: 0, ms: 0 },
+      {
+        name: 'owners',
+        status: 'failed',
+        updated: 0,
+        ms: 3,
+        error: 'timed out',
+      },
+    ]);
+    expect(output).toContain('git     12 updated in 40ms');
+    expect(output).toContain('routes  skipped');
+    expect(output).toContain('owners  failed: timed out');
+  });
+
+  it('counts calls and cached replies for steps that made any', () => {
+    const output = formatEnricherRuns([
+      {
+        name: 'owners',
+        status: 'ok',
+        updated: 40,
+        ms: 900,
+        calls: { calls: 2, cached: 38 },
+      },
+    ]);
+    expect(output).toContain(
+      'owners  40 updated in 900ms (2 calls, 38 cached)',
+    );
+  });
+});
+
+describe('annotation conflicts', () => {
+  it('reads the resolution order and defaults from the manifest', () => {
+    mkdirSync(TEMP_DIR, { recursive: true });
+    const configPath = join(TEMP_DIR, '.knowgraph.yml');
+    expect(readAnnotationLayers(configPath)).toEqual({});
+
+    writeFileSync(
+      configPath,
+      [
+        'version: "1.0"',
+        'annotations:',
+        '  resolution: [sidecar, inline, defaults]',
+        '  defaults:',
+        '    - paths: [services/payments/]',
+        '      metadata:',
+        '        owner: payments-team',
+        '',
+      ].join('\n'),
+    );
+    expect(readAnnotationLayers(configPath)).toEqual({
+      resolution: ['sidecar', 'inline', 'defaults'],
+      defaults: [
+        {
+          paths: ['services/payments/'],
+          metadata: { owner: 'payments-team' },
+        },
+      ],
+    });
+  });
+
+  it('formats each conflict with the kept and overridden locations', () => {
+    const output = formatAnnotationConflicts([
+      {
+        filePath: 'src/api.ts',
+        entity: 'Api',
+        field: 'owner',
+        kept: { source: 'inline', location: 'src/api.ts:3', value: 'team-a' },
+        overridden: [
+          {
+            source: 'defaults',
+            location: 'annotations.defaults[0]',
+            value: 'platform',
+          },
+        ],
+      },
+    ]);
+    expect(output).toBe(
+      '  src/api.ts Api.owner: kept "team-a" (inline, src/api.ts:3) over "platform" (defaults, annotations.defaults[0])',
+    );
+  });
+});
+
+describe('scan targets', () => {
+  it('resolves local paths without touching git', () => {
+    expect(resolveScanTarget('fixtures')).toEqual({
+      rootDir: resolve('fixtures'),
+    });
+  });
+
+  it('caches checkouts under XDG_CACHE_HOME when set', () => {
+    expect(defaultCacheDir({ XDG_CACHE_HOME: '/var/cache' })).toBe(
+      join('/var/cache', 'knowgraph', 'repos'),
+    );
+  });
+});
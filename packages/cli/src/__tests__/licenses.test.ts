@@ -0,0 +1,79 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { LicenseReport } from '@know-graph/core';
+import {
+  formatLicenseReport,
+  registerLicensesCommand,
+} from '../commands/licenses.js';
+
+const report: LicenseReport = {
+  checked: 2,
+  conflicts: 1,
+  modules: [
+    {
+      entityId: 'a',
+      name: 'checkout',
+      entityType: 'module',
+      filePath: 'src/checkout/index.ts',
+      owner: 'payments-team',
+      license: 'MIT',
+      origin: null,
+      conflicts: [
+        {
+          name: 'readline@8.2',
+          license: 'GPL-3.0-or-later',
+          category: 'strong-copyleft',
+          source: 'sbom',
+          reason: 'strong copyleft dependency of permissive code',
+        },
+      ],
+      unknown: [
+        {
+          name: 'mystery',
+          license: null,
+          category: 'unknown',
+          source: 'sbom',
+        },
+      ],
+      missingLicense: false,
+    },
+    {
+      entityId: 'b',
+      name: 'date-parser',
+      entityType: 'module',
+      filePath: 'vendor/date-parser/index.ts',
+      owner: null,
+      license: null,
+      origin: 'vendored',
+      conflicts: [],
+      unknown: [],
+      missingLicense: true,
+    },
+  ],
+};
+
+describe('licenses command', () => {
+  it('registers the licenses command', () => {
+    const program = new Command();
+    registerLicensesCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'licenses');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toContain('--license');
+  });
+
+  it('lists conflicts, unknown licenses, and missing licenses', () => {
+    const output = formatLicenseReport(report);
+    expect(output).toContain('License issues in 2 module(s)');
+    expect(output).toContain(
+      'readline@8.2 GPL-3.0-or-later: strong copyleft dependency of permissive code',
+    );
+    expect(output).toContain('mystery no license');
+    expect(output).toContain('vendored code without a license annotation');
+  });
+
+  it('reports a clean check', () => {
+    expect(
+      formatLicenseReport({ checked: 3, conflicts: 0, modules: [] }),
+    ).toContain('No license issues in 3 module(s)');
+  });
+});
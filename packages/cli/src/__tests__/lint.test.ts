@@ -0,0 +1,80 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { LintResult } from '@know-graph/core';
+import { formatLintResult, registerLintCommand } from '../commands/lint.js';
+
+const result: LintResult = {
+  issues: [
+    {
+      filePath: 'src/pay.ts',
+      line: 2,
+      rule: 'short-description',
+      message: 'Description is 8 characters; write at least 10',
+    },
+    {
+      filePath: 'src/pay.ts',
+      line: 2,
+      rule: 'duplicate-tags',
+      message: 'Duplicate tags: payments',
+      fix: 'Remove repeated tags, keeping the first occurrence',
+    },
+  ],
+  fileCount: 3,
+  fixableCount: 1,
+  fixedCount: 0,
+  fixedFiles: [],
+  suppressed: [
+    {
+      issue: {
+        filePath: 'src/legacy.ts',
+        line: 4,
+        rule: 'personal-owner',
+        message: 'Owner is a person',
+      },
+      reason: 'Sole maintainer until the rewrite',
+    },
+  ],
+};
+
+describe('lint command', () => {
+  it('registers the lint command with --fix', () => {
+    const program = new Command();
+    registerLintCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'lint');
+    expect(cmd).toBeDefined();
+    const flags = cmd?.options.map((o) => o.long);
+    expect(flags).toContain('--fix');
+    expect(flags).toContain('--owner-map');
+  });
+
+  it('lists issues with locations and fix suggestions', () => {
+    const output = formatLintResult(result);
+    expect(output).toContain('src/pay.ts:2');
+    expect(output).toContain('Description is 8 characters');
+    expect(output).toContain(
+      '(fixable: Remove repeated tags, keeping the first occurrence)',
+    );
+    expect(output).toContain(
+      '2 issue(s) in 3 file(s), 1 suppressed, 1 fixable with --fix',
+    );
+  });
+
+  it('lists suppressed issues with their reasons', () => {
+    expect(formatLintResult(result)).toContain(
+      'src/legacy.ts:4 [SUPPRESSED] personal-owner: Sole maintainer until the rewrite',
+    );
+  });
+
+  it('summarises applied fixes', () => {
+    const output = formatLintResult({
+      issues: [],
+      fileCount: 3,
+      fixableCount: 0,
+      fixedCount: 2,
+      fixedFiles: ['src/pay.ts'],
+      suppressed: [],
+    });
+    expect(output).toContain('Fixed 2 issue(s) in 1 file(s)');
+    expect(output).toContain('0 issue(s) in 3 file(s)');
+  });
+});
@@ -0,0 +1,54 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import {
+  configureLogging,
+  getLogger,
+  isStructuredLogging,
+  resolveLogSettings,
+} from '../utils/logging.js';
+
+describe('resolveLogSettings', () => {
+  it('reads the flags in either form, then the environment', () => {
+    expect(
+      resolveLogSettings(['index', '--log-level', 'debug'], {
+        KNOWGRAPH_LOG_FORMAT: 'json',
+      }),
+    ).toEqual({ level: 'debug', format: 'json' });
+    expect(
+      resolveLogSettings(['index', '--log-format=json'], {
+        KNOWGRAPH_LOG_LEVEL: 'warn',
+      }),
+    ).toEqual({ level: 'warn', format: 'json' });
+    expect(resolveLogSettings(['index'], {})).toEqual({
+      level: 'info',
+      format: 'text',
+    });
+  });
+
+  it('rejects unknown values', () => {
+    expect(() => resolveLogSettings(['--log-level', 'loud'], {})).toThrow(
+      /Unknown log level/,
+    );
+  });
+});
+
+describe('getLogger', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    configureLogging({ level: 'info', format: 'text' });
+  });
+
+  it('writes JSON records to stderr at the configured level', () => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    configureLogging({ level: 'warn', format: 'json' });
+
+    getLogger().info('Published');
+    getLogger().warn('Skipping sink', { sink: 'slack' });
+
+    expect(isStructuredLogging()).toBe(true);
+    expect(consoleErrorSpy).toHaveBeenCalledTimes(1);
+    const record = JSON.parse(String(consoleErrorSpy.mock.calls[0][0]));
+    expect(record).toMatchObject({ level: 'warn', sink: 'slack' });
+  });
+});
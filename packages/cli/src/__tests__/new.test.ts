@@ -0,0 +1,129 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  existsSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerNewCommand } from '../commands/new.js';
+
+const MANIFEST = [
+  'version: "1.0"',
+  'templates:',
+  '  default:',
+  '    directory: services',
+  '    metadata:',
+  '      owner: platform-team',
+  '      context:',
+  '        domain: platform',
+  '    handlers: [Health]',
+  '  payments:',
+  '    directory: services/payments',
+  '    metadata:',
+  '      owner: payments-team',
+  '      compliance:',
+  '        regulations: [PCI-DSS]',
+  '',
+].join('\n');
+
+describe('new module command', () => {
+  let dir: string;
+  let configPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-new-'));
+    configPath = join(dir, '.knowgraph.yml');
+    writeFileSync(configPath, MANIFEST);
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerNewCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'new',
+      'module',
+      ...args,
+      '--config',
+      configPath,
+    ]);
+  }
+
+  it('scaffolds a module from the default template', async () => {
+    await run('billing', '--description', 'Bills customers');
+    const doc = readFileSync(join(dir, 'services/billing/doc.go'), 'utf-8');
+    expect(doc).toContain('// owner: platform-team\n');
+    expect(doc).toContain('//   domain: platform\n');
+    expect(doc).toContain('package billing\n');
+    expect(existsSync(join(dir, 'services/billing/health.go'))).toBe(true);
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('uses a named template and the given handlers', async () => {
+    await run(
+      'refunds',
+      '--description',
+      'Refunds card payments',
+      '--template',
+      'payments',
+      '--handler',
+      'CreateRefund',
+      '--depends-on',
+      'ledger',
+      '--format',
+      'json',
+    );
+    const result = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(result).toMatchObject({
+      directory: 'services/payments/refunds',
+      package: 'refunds',
+      metadata: {
+        owner: 'payments-team',
+        compliance: { regulations: ['PCI-DSS'] },
+        dependencies: { services: ['ledger'] },
+      },
+      files: [
+        'services/payments/refunds/doc.go',
+        'services/payments/refunds/create_refund.go',
+      ],
+    });
+  });
+
+  it('writes nothing on --dry-run', async () => {
+    await run('billing', '--description', 'Bills customers', '--dry-run');
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      'services/billing/doc.go',
+    );
+    expect(existsSync(join(dir, 'services'))).toBe(false);
+  });
+
+  it('refuses to overwrite a module or use an unknown template', async () => {
+    await run('billing', '--description', 'Bills customers');
+    await run('billing', '--description', 'Bills customers again');
+    expect(process.exitCode).toBe(2);
+    expect(
+      readFileSync(join(dir, 'services/billing/doc.go'), 'utf-8'),
+    ).toContain('Bills customers\n');
+
+    process.exitCode = undefined;
+    await run('ledger', '--description', 'Ledger', '--template', 'mobile');
+    expect(process.exitCode).toBe(2);
+    expect(existsSync(join(dir, 'services/ledger'))).toBe(false);
+  });
+});
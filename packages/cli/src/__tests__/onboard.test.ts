@@ -0,0 +1,47 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { Command } from 'commander';
+import { registerOnboardCommand } from '../commands/onboard.js';
+
+describe('onboard command', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerOnboardCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'onboard', ...args]);
+  }
+
+  it('registers the onboard command with its options', () => {
+    const program = new Command();
+    registerOnboardCommand(program);
+    const command = program.commands.find((c) => c.name() === 'onboard');
+    expect(command!.options.map((o) => o.long)).toEqual([
+      '--db',
+      '--format',
+      '--limit',
+      '--output',
+    ]);
+  });
+
+  it('rejects an invalid limit as a usage error', async () => {
+    await run('billing', '--limit', '0');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    await run('billing', '--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+});
@@ -0,0 +1,110 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import { copyFileSync, existsSync, mkdtempSync, rmSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { parseKnowGraphScan } from '@know-graph/core';
+import {
+  formatReconcileResult,
+  registerOperatorCommand,
+  scanRepository,
+} from '../commands/operator.js';
+
+const SAMPLE = resolve(__dirname, 'fixtures', 'sample.ts');
+
+function git(cwd: string, ...args: string[]): string {
+  return execFileSync('git', args, { cwd, encoding: 'utf-8' }).trim();
+}
+
+describe('operator command', () => {
+  let dir: string;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-operator-'));
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerOperatorCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'operator', ...args]);
+  }
+
+  it('indexes the scanned repository into its own database', () => {
+    const repo = join(dir, 'repo');
+    execFileSync('git', ['init', '-q', repo]);
+    copyFileSync(SAMPLE, join(repo, 'sample.ts'));
+    git(repo, 'add', '.');
+    git(
+      repo,
+      '-c',
+      'user.name=test',
+      '-c',
+      'user.email=test@example.com',
+      'commit',
+      '-qm',
+      'init',
+    );
+    const scan = parseKnowGraphScan({
+      metadata: { name: 'sample', namespace: 'platform', uid: 'uid-1' },
+      spec: { repo: `file://${repo}`, selector: {} },
+    });
+
+    const dataDir = join(dir, 'data');
+    const scanned = scanRepository(scan, dataDir, join(dir, 'cache'));
+    expect(scanned.commit).toBe(git(repo, 'rev-parse', 'HEAD'));
+    expect(scanned.entities.length).toBeGreaterThan(0);
+    expect(existsSync(join(dataDir, 'platform', 'sample.db'))).toBe(true);
+  });
+
+  it('formats reconcile results', () => {
+    const scan = parseKnowGraphScan({
+      metadata: { name: 'sample', namespace: 'platform', uid: 'uid-1' },
+      spec: { repo: 'https://example.com/sample.git', selector: {} },
+    });
+    expect(
+      formatReconcileResult({
+        scan,
+        status: {
+          phase: 'Ready',
+          lastScanTime: 'now',
+          commit: '5c5aa05ffee8f8a6364f',
+          entities: 4,
+          workloads: 2,
+        },
+      }),
+    ).toBe(
+      'platform/sample: 4 entities at 5c5aa05ffee8, 2 workload(s) annotated',
+    );
+  });
+
+  it('rejects a bad interval', async () => {
+    await run('--interval', '0', '--api-url', 'https://k8s.test');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('needs an API server outside a cluster', async () => {
+    const host = process.env.KUBERNETES_SERVICE_HOST;
+    delete process.env.KUBERNETES_SERVICE_HOST;
+    try {
+      await run('--once');
+    } finally {
+      if (host !== undefined) process.env.KUBERNETES_SERVICE_HOST = host;
+    }
+    expect(process.exitCode).toBe(2);
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain(
+      'Not running in a Kubernetes pod',
+    );
+  });
+});
@@ -0,0 +1,197 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { createGraphPatch, graphDigest } from '@know-graph/core';
+import type { DependencyGraph, GraphNode } from '@know-graph/core';
+import { registerPatchCommand } from '../commands/patch.js';
+import { readGraphFile } from '../commands/stitch.js';
+
+function node(id: string): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+const base: DependencyGraph = {
+  nodes: [node('checkout'), node('payments')],
+  edges: [
+    {
+      from: 'checkout',
+      to: 'payments',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    },
+  ],
+};
+
+const target: DependencyGraph = {
+  nodes: [node('checkout'), node('payments'), node('refunds')],
+  edges: [
+    ...base.edges,
+    {
+      from: 'refunds',
+      to: 'payments',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    },
+  ],
+};
+
+describe('patch command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-patch-'));
+    writeFileSync(join(dir, 'graph.json'), JSON.stringify(base));
+    writeFileSync(
+      join(dir, 'change.json'),
+      JSON.stringify(createGraphPatch(base, target)),
+    );
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerPatchCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'patch', ...args]);
+  }
+
+  it('applies the patch to the graph in place', async () => {
+    await run(join(dir, 'graph.json'), join(dir, 'change.json'));
+    expect(process.exitCode).toBeUndefined();
+    expect(graphDigest(readGraphFile(join(dir, 'graph.json')))).toBe(
+      graphDigest(target),
+    );
+    expect(consoleLogSpy.mock.calls[0]?.[0]).toBe(
+      [
+        '1 nodes added or changed, 0 removed',
+        '1 edges added or changed, 0 removed',
+      ].join('; '),
+    );
+  });
+
+  it('writes elsewhere with --output', async () => {
+    const output = join(dir, 'patched.kgs');
+    await run(
+      join(dir, 'graph.json'),
+      join(dir, 'change.json'),
+      '--output',
+      output,
+    );
+    expect(readGraphFile(output).nodes).toHaveLength(3);
+    expect(readGraphFile(join(dir, 'graph.json'))).toEqual(base);
+  });
+
+  it('refuses a graph the patch was not made from', async () => {
+    writeFileSync(join(dir, 'other.json'), JSON.stringify(target));
+    await run(join(dir, 'other.json'), join(dir, 'change.json'));
+    expect(process.exitCode).toBe(2);
+    expect(readFileSync(join(dir, 'other.json'), 'utf-8')).toBe(
+      JSON.stringify(target),
+    );
+  });
+});
+
+describe('push command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-push-'));
+    writeFileSync(join(dir, 'last.json'), JSON.stringify(base));
+    writeFileSync(join(dir, 'graph.json'), JSON.stringify(target));
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+    vi.stubEnv('KNOWGRAPH_TOKEN', 'kg_test');
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    vi.unstubAllEnvs();
+    vi.unstubAllGlobals();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerPatchCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'push', ...args]);
+  }
+
+  it('pushes the patch from the last pushed graph', async () => {
+    const fetch = vi.fn(
+      async () =>
+        new Response(JSON.stringify({ digest: graphDigest(target) }), {
+          status: 200,
+        }),
+    );
+    vi.stubGlobal('fetch', fetch);
+    await run(
+      join(dir, 'graph.json'),
+      'http://localhost:8080',
+      '--name',
+      'payments',
+      '--base',
+      join(dir, 'last.json'),
+    );
+
+    expect(process.exitCode).toBeUndefined();
+    const [url, init] = fetch.mock.calls[0] as unknown as [
+      string,
+      RequestInit,
+    ];
+    expect(url).toBe('http://localhost:8080/registry/v1/graphs/payments');
+    expect(init.method).toBe('PATCH');
+    expect(consoleLogSpy.mock.calls[0]?.[0]).toMatch(
+      /^Pushed a patch \(\d+ bytes\): 1 nodes added or changed/,
+    );
+  });
+
+  it('needs a name and a token', async () => {
+    await run(join(dir, 'graph.json'), 'http://localhost:8080');
+    expect(process.exitCode).toBe(2);
+
+    process.exitCode = undefined;
+    vi.stubEnv('KNOWGRAPH_TOKEN', '');
+    await run(
+      join(dir, 'graph.json'),
+      'http://localhost:8080',
+      '--name',
+      'payments',
+    );
+    expect(process.exitCode).toBe(2);
+    expect(consoleErrorSpy.mock.calls.flat().join('\n')).toContain(
+      'KNOWGRAPH_TOKEN is not set',
+    );
+  });
+});
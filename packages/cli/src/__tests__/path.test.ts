@@ -0,0 +1,140 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerPathCommand } from '../commands/path.js';
+import { indexInto } from '../utils/indexing.js';
+
+function service(name: string, dependencies: readonly string[]): string {
+  return [
+    `class ${name}:`,
+    '    """',
+    '    @knowgraph',
+    '    type: service',
+    `    description: The ${name} service`,
+    '    dependencies:',
+    ...dependencies.map((line) => `      ${line}`),
+    '    """',
+    '',
+  ].join('\n');
+}
+
+describe('path command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-path-'));
+    writeFileSync(
+      join(dir, 'orders.py'),
+      service('OrderService', ['databases: [postgres-main]']),
+    );
+    writeFileSync(
+      join(dir, 'cart.py'),
+      service('CartService', ['services: [OrderService]']),
+    );
+    writeFileSync(
+      join(dir, 'checkout.py'),
+      service('CheckoutService', [
+        'services: [OrderService, CartService]',
+      ]),
+    );
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerPathCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'path',
+      ...args,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('prints the shortest paths with edge provenance', async () => {
+    await run('--from', 'checkout-service', '--to', 'postgres-main');
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain(
+      '2 path(s) from CheckoutService to postgres-main',
+    );
+    expect(output).toContain('-> OrderService service, declared');
+    expect(output.indexOf('2 hops')).toBeLessThan(output.indexOf('3 hops'));
+  });
+
+  it('limits the paths with --k and writes JSON', async () => {
+    await run(
+      '--from',
+      'CheckoutService',
+      '--to',
+      'postgres-main',
+      '--k',
+      '1',
+      '--format',
+      'json',
+    );
+    const result = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(result.paths).toHaveLength(1);
+    expect(result.paths[0].edges).toHaveLength(2);
+  });
+
+  it('says when there is no path', async () => {
+    await run('--from', 'OrderService', '--to', 'CartService');
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      'OrderService does not depend on CartService',
+    );
+  });
+
+  it('follows one environment with --env', async () => {
+    writeFileSync(
+      join(dir, 'reports.py'),
+      service('ReportService', [
+        'environments:',
+        '  prod:',
+        '    databases: [postgres-main]',
+        '  dev:',
+        '    databases: [sqlite]',
+      ]),
+    );
+    indexInto(dir, dbPath, { incremental: false });
+    await run('--from', 'ReportService', '--to', 'postgres-main');
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      '1 path(s) from ReportService to postgres-main',
+    );
+    await run(
+      '--from',
+      'ReportService',
+      '--to',
+      'postgres-main',
+      '--env',
+      'dev',
+    );
+    expect(String(consoleLogSpy.mock.calls[1]?.[0])).toContain(
+      'ReportService does not depend on postgres-main',
+    );
+    await run('--from', 'ReportService', '--to', 'sqlite', '--env', 'qa');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails on a name that matches no node', async () => {
+    await run('--from', 'billing', '--to', 'postgres-main');
+    expect(process.exitCode).toBe(2);
+  });
+});
@@ -0,0 +1,37 @@
+import { describe, it, expect } from 'vitest';
+import { fileChange } from '@know-graph/core';
+import { formatPlan } from '../utils/plan.js';
+
+describe('formatPlan', () => {
+  it('lists each change with its diff and the totals', () => {
+    const output = formatPlan({
+      command: 'lint --fix',
+      changes: [
+        fileChange('src/pay.ts', 'owner: alice\n', 'owner: payments\n')!,
+        { target: 'graph', summary: '1 added, 0 updated, 0 removed' },
+      ],
+      totals: ['1 issue(s) fixed', '1 file(s) rewritten'],
+    });
+    expect(output).toContain('knowgraph lint --fix would make these changes:');
+    expect(output).toContain('src/pay.ts: modified');
+    expect(output).toContain('+owner: payments');
+    expect(output).toContain('graph: 1 added, 0 updated, 0 removed');
+    expect(output).toContain('1 issue(s) fixed, 1 file(s) rewritten.');
+    expect(output).toContain('Dry run: nothing was written.');
+  });
+
+  it('reports created files', () => {
+    const output = formatPlan({
+      command: 'init',
+      changes: [fileChange('.knowgraph.yml', undefined, 'version: "1.0"\n')!],
+      totals: [],
+    });
+    expect(output).toContain('.knowgraph.yml: created');
+  });
+
+  it('says when there is nothing to do', () => {
+    expect(formatPlan({ command: 'index', changes: [], totals: [] })).toContain(
+      'No changes. knowgraph index would leave everything as it is.',
+    );
+  });
+});
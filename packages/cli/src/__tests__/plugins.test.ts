@@ -0,0 +1,213 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import type { PluginConfig } from '@know-graph/core';
+import {
+  formatGoldenResults,
+  formatPlugins,
+  registerPluginsCommand,
+} from '../commands/plugins.js';
+import { readPlugins } from '../utils/manifest.js';
+
+const plugin: PluginConfig = {
+  name: 'terraform',
+  command: ['node', './tools/kg-terraform.js'],
+  extensions: ['.tf'],
+  formats: ['tfdoc'],
+  rules: true,
+};
+
+describe('plugins command', () => {
+  it('registers with --check', () => {
+    const program = new Command();
+    registerPluginsCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'plugins');
+    expect(cmd?.options.map((o) => o.long)).toContain('--check');
+  });
+
+  it('lists capabilities and probe results', () => {
+    const output = formatPlugins(
+      [plugin, { ...plugin, name: 'broken', formats: undefined }],
+      new Map([
+        ['terraform', { name: 'terraform', version: '0.1.0' }],
+        ['broken', new Error("Plugin 'broken' describe exited with 1")],
+      ]),
+    );
+    expect(output).toContain('node ./tools/kg-terraform.js');
+    expect(output).toContain('extracts .tf; exports tfdoc; lint rules');
+    expect(output).toContain('terraform 0.1.0');
+    expect(output).toContain("Plugin 'broken' describe exited with 1");
+    expect(formatPlugins([])).toContain('No plugins configured');
+    expect(
+      formatPlugins([
+        { name: 'policy', wasm: '/repo/rules.wasm', rules: true, enrich: true },
+      ]),
+    ).toContain('lint rules; enriches entities');
+  });
+});
+
+describe('plugins --golden', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  function writePlugin(prefix: string): void {
+    writeFileSync(
+      join(dir, 'plugin.cjs'),
+      [
+        "let input = '';",
+        'process.stdin.on("data", (chunk) => (input += chunk));',
+        'process.stdin.on("end", () => {',
+        '  const { params } = JSON.parse(input);',
+        '  const names = params.entities.map((e) => e.name).join("\\n");',
+        `  const content = ${JSON.stringify(prefix)} + names + "\\n";`,
+        '  process.stdout.write(JSON.stringify({ result: { content } }));',
+        '});',
+      ].join('\n'),
+    );
+  }
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerPluginsCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'plugins',
+      '--config',
+      join(dir, '.knowgraph.yml'),
+      '--golden',
+      join(dir, 'golden'),
+      ...args,
+    ]);
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-plugins-'));
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    writePlugin('# Entities\n');
+    writeFileSync(
+      join(dir, '.knowgraph.yml'),
+      [
+        'version: "1.0"',
+        'plugins:',
+        '  - name: names',
+        `    command: [${JSON.stringify(process.execPath)}, ./plugin.cjs]`,
+        '    formats: [names]',
+        '',
+      ].join('\n'),
+    );
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('fails on a missing golden file until --update writes it', async () => {
+    await run();
+    expect(process.exitCode).toBe(1);
+    process.exitCode = undefined;
+
+    await run('--update');
+    expect(process.exitCode).toBeUndefined();
+    expect(readFileSync(join(dir, 'golden', 'names.golden'), 'utf-8')).toBe(
+      '# Entities\nCheckoutService\nPaymentGateway\ncharge\nShippingService\n',
+    );
+
+    await run();
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('shows how the output differs from the golden file', async () => {
+    await run('--update');
+    writePlugin('# Services\n');
+    await run();
+    expect(process.exitCode).toBe(1);
+    const output = consoleLogSpy.mock.calls.map((c) => String(c[0])).join('\n');
+    expect(output).toContain('-# Entities');
+    expect(output).toContain('+# Services');
+  });
+
+  it('rejects --update without --golden', async () => {
+    const program = new Command();
+    registerPluginsCommand(program);
+    await program.parseAsync(['node', 'knowgraph', 'plugins', '--update']);
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('labels each result with its format and status', () => {
+    const output = formatGoldenResults([
+      { format: 'names', path: 'golden/names.golden', status: 'match' },
+      { format: 'tfdoc', path: 'golden/tfdoc.golden', status: 'missing' },
+      { format: 'yaml', path: 'golden/yaml.golden', status: 'created' },
+    ]);
+    expect(output).toContain('names');
+    expect(output).toContain('tfdoc golden/tfdoc.golden missing');
+    expect(output).toContain('yaml golden/yaml.golden created');
+  });
+});
+
+describe('readPlugins', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-plugins-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('runs plugins from the manifest directory', () => {
+    writeFileSync(
+      join(dir, '.knowgraph.yml'),
+      [
+        'version: "1.0"',
+        'plugins:',
+        '  - name: terraform',
+        '    command: [node, ./tools/kg-terraform.js]',
+        '    extensions: [.tf]',
+        '    timeout_ms: 5000',
+        '',
+      ].join('\n'),
+    );
+    expect(readPlugins(join(dir, '.knowgraph.yml'))).toEqual([
+      {
+        name: 'terraform',
+        command: ['node', './tools/kg-terraform.js'],
+        extensions: ['.tf'],
+        formats: undefined,
+        rules: undefined,
+        timeoutMs: 5000,
+        cwd: dir,
+      },
+    ]);
+    expect(readPlugins(join(dir, 'missing.yml'))).toEqual([]);
+  });
+
+  it('resolves wasm modules against the manifest directory', () => {
+    writeFileSync(
+      join(dir, '.knowgraph.yml'),
+      [
+        'version: "1.0"',
+        'plugins:',
+        '  - name: policy',
+        '    wasm: ./rules/policy.wasm',
+        '    memory_mb: 16',
+        '    enrich: true',
+        '',
+      ].join('\n'),
+    );
+    expect(readPlugins(join(dir, '.knowgraph.yml'))[0]).toMatchObject({
+      wasm: join(dir, 'rules', 'policy.wasm'),
+      memoryMb: 16,
+      enrich: true,
+    });
+  });
+});
@@ -0,0 +1,46 @@
+import { describe, it, expect, afterEach } from 'vitest';
+import { existsSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { formatPhaseTimings, startProfile } from '../utils/profile.js';
+
+describe('formatPhaseTimings', () => {
+  it('lists each phase with its share and a total', () => {
+    const output = formatPhaseTimings([
+      { phase: 'walk', ms: 25, calls: 1 },
+      { phase: 'parse', ms: 75, calls: 3 },
+    ]);
+    expect(output).toContain('Phase timings:');
+    expect(output).toMatch(/walk\s+25\.0ms\s+25%, 1 call\b/);
+    expect(output).toMatch(/parse\s+75\.0ms\s+75%, 3 calls/);
+    expect(output).toMatch(/total\s+100\.0ms/);
+  });
+
+  it('handles no recorded phases', () => {
+    expect(formatPhaseTimings([])).toBe('No phases recorded.');
+  });
+});
+
+describe('startProfile', () => {
+  const dir = join(tmpdir(), `knowgraph-profile-${Date.now()}`);
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('writes CPU and heap profiles when stopped', async () => {
+    const capture = await startProfile(dir);
+    capture.timer.time('parse', () => JSON.parse('{"a":[1,2,3]}'));
+    const files = await capture.stop();
+
+    expect(files).toEqual([
+      join(dir, 'cpu.cpuprofile'),
+      join(dir, 'heap.heapprofile'),
+    ]);
+    expect(files.every((file) => existsSync(file))).toBe(true);
+    expect(JSON.parse(readFileSync(files[0], 'utf-8'))).toHaveProperty('nodes');
+    expect(capture.timer.timings()).toEqual([
+      expect.objectContaining({ phase: 'parse', calls: 1 }),
+    ]);
+  });
+});
@@ -0,0 +1,238 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { readAuditLog } from '@know-graph/core';
+import { registerPublishCommand } from '../commands/publish.js';
+import { indexInto } from '../utils/indexing.js';
+
+function moduleFile(description: string): string {
+  return [
+    '"""',
+    '@knowgraph',
+    'type: module',
+    `description: ${description}`,
+    'owner: payments-team',
+    '"""',
+    '',
+  ].join('\n');
+}
+
+const CONFIG = [
+  'version: "1.0"',
+  'confluence:',
+  '  base_url: https://acme.atlassian.net/wiki',
+  '  token_env: KG_TEST_CONFLUENCE_TOKEN',
+  '  pages:',
+  '    billing:',
+  '      space: PAY',
+  '      title: Billing',
+  'notion_database:',
+  '  database_id: db-1',
+  '  token_env: KG_TEST_NOTION_TOKEN',
+  '',
+].join('\n');
+
+/**
+ * A fake Notion API over one database, storing the rows written to it
+ * and answering queries with them as Notion would.
+ */
+function stubNotion(): Map<string, Record<string, object>> {
+  const rows = new Map<string, Record<string, object>>();
+  const plain = (parts: Array<{ text: { content: string } }>) =>
+    parts.map((part) => ({ plain_text: part.text.content }));
+  const read = (properties: Record<string, Record<string, unknown>>) =>
+    Object.fromEntries(
+      Object.entries(properties).map(([name, value]) => {
+        const [type, content] = Object.entries(value)[0];
+        return [
+          name,
+          type === 'title' || type === 'rich_text'
+            ? { type, [type]: plain(content as []) }
+            : { type, [type]: content },
+        ];
+      }),
+    );
+  vi.stubGlobal(
+    'fetch',
+    vi.fn(async (url: string, init: RequestInit = {}) => {
+      const body = init.body ? JSON.parse(String(init.body)) : {};
+      let response: object = {};
+      if (url.endsWith('/query')) {
+        response = {
+          results: [...rows].map(([id, properties]) => ({ id, properties })),
+        };
+      } else if (url.includes('/databases/')) {
+        response = { properties: { Name: { type: 'title' } } };
+      } else if (init.method === 'POST') {
+        const id = `page-${rows.size + 1}`;
+        rows.set(id, read(body.properties));
+        response = { id };
+      }
+      return {
+        ok: true,
+        status: 200,
+        statusText: 'OK',
+        text: async () => JSON.stringify(response),
+      };
+    }),
+  );
+  return rows;
+}
+
+describe('publish commands', () => {
+  let dir: string;
+  let dbPath: string;
+  let configPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-publish-'));
+    mkdirSync(join(dir, 'billing'));
+    mkdirSync(join(dir, 'ledger'));
+    writeFileSync(
+      join(dir, 'billing', 'billing.py'),
+      moduleFile('Bills customers'),
+    );
+    writeFileSync(join(dir, 'ledger', 'ledger.py'), moduleFile('Keeps books'));
+    configPath = join(dir, '.knowgraph.yml');
+    writeFileSync(configPath, CONFIG);
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    vi.unstubAllGlobals();
+    vi.unstubAllEnvs();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(target: string, ...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerPublishCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'publish',
+      target,
+      ...args,
+      '--config',
+      configPath,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('lists the mapped pages on a dry run', async () => {
+    const fetch = vi.fn();
+    vi.stubGlobal('fetch', fetch);
+    await run('confluence', '--dry-run', '--format', 'json');
+    const result = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(result.pages).toEqual([
+      {
+        title: 'Billing',
+        space: 'PAY',
+        directory: 'billing',
+        entities: [expect.any(String)],
+      },
+    ]);
+    expect(result.skipped).toEqual([
+      { directory: 'ledger', reason: 'not mapped to a space' },
+    ]);
+    expect(fetch).not.toHaveBeenCalled();
+    expect(readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'))).toEqual([]);
+  });
+
+  it('refuses to publish without a token', async () => {
+    vi.stubEnv('KG_TEST_CONFLUENCE_TOKEN', '');
+    await run('confluence');
+    expect(process.exitCode).toBe(2);
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain(
+      'KG_TEST_CONFLUENCE_TOKEN is not set',
+    );
+  });
+
+  it('creates the page once and then leaves it alone', async () => {
+    vi.stubEnv('KG_TEST_CONFLUENCE_TOKEN', 'secret');
+    let stored: { id: string; version: object } | undefined;
+    const methods: string[] = [];
+    vi.stubGlobal(
+      'fetch',
+      vi.fn(async (_url: string, init: RequestInit = {}) => {
+        const method = init.method ?? 'GET';
+        methods.push(method);
+        if (method === 'POST') {
+          stored = { id: '100', ...JSON.parse(String(init.body)) };
+        }
+        const body =
+          method === 'POST' ? stored : { results: stored ? [stored] : [] };
+        return {
+          ok: true,
+          status: 200,
+          statusText: 'OK',
+          text: async () => JSON.stringify(body),
+        };
+      }),
+    );
+
+    await run('confluence', '--format', 'json');
+    await run('confluence', '--format', 'json');
+    const [first, second] = consoleLogSpy.mock.calls.map((call) =>
+      JSON.parse(String(call[0])),
+    );
+    expect(first.pages).toEqual([
+      {
+        title: 'Billing',
+        space: 'PAY',
+        id: '100',
+        version: 1,
+        status: 'created',
+      },
+    ]);
+    expect(second.pages[0].status).toBe('unchanged');
+    expect(methods).toEqual(['GET', 'POST', 'GET']);
+    const entries = readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'));
+    expect(entries.map((entry) => entry.changes)).toEqual([
+      [{ target: 'confluence:PAY/Billing', summary: 'created, version 1' }],
+      [],
+    ]);
+  });
+
+  it('syncs Notion rows and fails on rows edited there', async () => {
+    vi.stubEnv('KG_TEST_NOTION_TOKEN', 'secret');
+    const rows = stubNotion();
+    await run('notion', '--format', 'json');
+    const created = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(created.rows.map((row: { name: string }) => row.name)).toEqual([
+      'billing',
+      'ledger',
+    ]);
+    expect(process.exitCode).toBeUndefined();
+
+    rows.get('page-2')!.Owner = {
+      type: 'rich_text',
+      rich_text: [{ plain_text: 'books-team' }],
+    };
+    await run('notion');
+    expect(process.exitCode).toBe(1);
+    const output = String(consoleLogSpy.mock.calls[1]?.[0]);
+    expect(output).toContain('Edited in Notion, differing from the code');
+    expect(output).toContain(
+      'owner: "books-team" in Notion, "payments-team" in code',
+    );
+    const [synced] = readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'));
+    expect(synced?.command).toBe('publish notion');
+    expect(synced?.changes.map((change) => change.summary)).toEqual([
+      'created',
+      'created',
+    ]);
+  });
+});
@@ -1,6 +1,7 @@
-import { describe, it, expect, beforeAll, afterAll } from 'vitest';
+import { describe, it, expect, vi, beforeAll, afterAll } from 'vitest';
 import { resolve, join } from 'node:path';
-import { mkdirSync, rmSync, existsSync } from 'node:fs';
+import { mkdirSync, rmSync, existsSync, writeFileSync } from 'node:fs';
+import { Command } from 'commander';
 import {
   createDefaultRegistry,
   createDatabaseManager,
@@ -8,7 +9,9 @@ import {
   createQueryEngine,
 } from '@know-graph/core';
 import type { DatabaseManager } from '@know-graph/core';
+import { registerQueryCommand } from '../commands/query.js';
 import { formatTable, formatJson } from '../utils/format.js';
+import { collectScope, parseScopes } from '../utils/scope.js';
 
 const FIXTURES_DIR = resolve(__dirname, 'fixtures');
 const TEMP_DIR = resolve(__dirname, '.tmp-query-test');
@@ -85,4 +88,186 @@ describe('query command logic', () => {
     expect(Array.isArray(parsed)).toBe(true);
     expect(parsed.length).toBeGreaterThan(0);
   });
+
+  it('should emit JSON with sorted keys', () => {
+    expect(formatJson({ b: 1, a: { d: 0.1 + 0.2, c: 2 } }, false)).toBe(
+      '{"a":{"c":2,"d":0.3},"b":1}',
+    );
+  });
+});
+
+describe('--scope', () => {
+  it('collects repeated flags and parses each one', () => {
+    const values = collectScope('tag=payments', collectScope('path=./svc/'));
+    expect(parseScopes(values)).toEqual([
+      { kind: 'path', path: 'svc' },
+      { kind: 'tag', tag: 'payments' },
+    ]);
+    expect(parseScopes()).toEqual([]);
+  });
+
+  it('exits with the usage code on an invalid scope', async () => {
+    const error = vi.spyOn(console, 'error').mockImplementation(() => {});
+    const exitCode = process.exitCode;
+    process.exitCode = undefined;
+    try {
+      const program = new Command();
+      registerQueryCommand(program);
+      await program.parseAsync([
+        'node',
+        'knowgraph',
+        'query',
+        'sample',
+        '--db',
+        join(TEMP_DIR, 'knowgraph.db'),
+        '--scope',
+        'owner=payments',
+      ]);
+      expect(process.exitCode).toBe(2);
+      expect(String(error.mock.calls[0]?.[0])).toContain(
+        'Invalid scope "owner=payments"',
+      );
+    } finally {
+      error.mockRestore();
+      process.exitCode = exitCode;
+    }
+  });
+});
+
+async function query(...args: string[]): Promise<void> {
+  const program = new Command();
+  registerQueryCommand(program);
+  await program.parseAsync([
+    'node',
+    'knowgraph',
+    'query',
+    ...args,
+    '--db',
+    join(TEMP_DIR, 'knowgraph.db'),
+    '--config',
+    join(TEMP_DIR, '.knowgraph.yml'),
+  ]);
+}
+
+describe('--saved', () => {
+  it('runs a query saved in the config, with flags overriding it', async () => {
+    writeFileSync(
+      join(TEMP_DIR, '.knowgraph.yml'),
+      [
+        'version: "1.0"',
+        'queries:',
+        '  team-functions:',
+        '    type: function',
+        '    owner: test-team',
+      ].join('\n'),
+    );
+    const log = vi.spyOn(console, 'log').mockImplementation(() => {});
+    const error = vi.spyOn(console, 'error').mockImplementation(() => {});
+    try {
+      await query('--saved', 'team-functions', '--format', 'json');
+      const saved = JSON.parse(String(log.mock.calls[0]?.[0]));
+      expect(saved.length).toBeGreaterThan(0);
+      for (const entity of saved) {
+        expect(entity.entityType).toBe('function');
+      }
+
+      await query('--saved', 'team-functions', '--type', 'class');
+      expect(String(log.mock.calls[1]?.[0])).not.toContain('function');
+    } finally {
+      log.mockRestore();
+      error.mockRestore();
+    }
+  });
+
+  it('exits with the usage code for an unknown saved query', async () => {
+    writeFileSync(
+      join(TEMP_DIR, '.knowgraph.yml'),
+      'version: "1.0"\nqueries: {}\n',
+    );
+    const error = vi.spyOn(console, 'error').mockImplementation(() => {});
+    const exitCode = process.exitCode;
+    process.exitCode = undefined;
+    try {
+      await query('--saved', 'missing');
+      expect(process.exitCode).toBe(2);
+      expect(String(error.mock.calls[0]?.[0])).toContain(
+        "Unknown saved query 'missing'",
+      );
+    } finally {
+      error.mockRestore();
+      process.exitCode = exitCode;
+    }
+  });
+});
+
+describe('--view', () => {
+  it('shows the types and columns of a view from the config', async () => {
+    writeFileSync(
+      join(TEMP_DIR, '.knowgraph.yml'),
+      [
+        'version: "1.0"',
+        'views:',
+        '  classes:',
+        '    types: [class]',
+        '    fields: [owner]',
+        '    layout:',
+        '      columns: [name, Kind=entityType]',
+        '      group_by: owner',
+      ].join('\n'),
+    );
+    const log = vi.spyOn(console, 'log').mockImplementation(() => {});
+    const error = vi.spyOn(console, 'error').mockImplementation(() => {});
+    try {
+      await query('sample', '--view', 'classes');
+      const output = String(log.mock.calls[0]?.[0]);
+      expect(output).toContain('test-team');
+      expect(output).toContain('Kind');
+      expect(output).not.toContain('function');
+
+      await query('sample', '--view', 'classes', '--format', 'json');
+      const [entity] = JSON.parse(String(log.mock.calls[1]?.[0]));
+      expect(entity.entityType).toBe('class');
+      expect(entity.tags).toEqual([]);
+    } finally {
+      log.mockRestore();
+      error.mockRestore();
+    }
+  });
+});
+
+describe('--as-of', () => {
+  it('exits with the usage code on an invalid date', async () => {
+    writeFileSync(join(TEMP_DIR, '.knowgraph.yml'), 'version: "1.0"\n');
+    const error = vi.spyOn(console, 'error').mockImplementation(() => {});
+    const exitCode = process.exitCode;
+    process.exitCode = undefined;
+    try {
+      await query('sample', '--as-of', '2026-13-01');
+      expect(process.exitCode).toBe(2);
+      expect(String(error.mock.calls[0]?.[0])).toContain(
+        "Invalid date '2026-13-01': expected YYYY-MM-DD",
+      );
+    } finally {
+      error.mockRestore();
+      process.exitCode = exitCode;
+    }
+  });
+});
+
+describe('--interactive', () => {
+  it('needs a terminal', async () => {
+    const error = vi.spyOn(console, 'error').mockImplementation(() => {});
+    const exitCode = process.exitCode;
+    process.exitCode = undefined;
+    try {
+      await query('sample', '--interactive');
+      expect(process.exitCode).toBe(2);
+      expect(String(error.mock.calls[0]?.[0])).toContain(
+        '--interactive needs an interactive terminal',
+      );
+    } finally {
+      error.mockRestore();
+      process.exitCode = exitCode;
+    }
+  });
 });
@@ -0,0 +1,121 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { createDatabaseManager } from '@know-graph/core';
+import { registerReportCommand } from '../commands/report.js';
+
+describe('report command', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+  let dir: string;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+    dir = mkdtempSync(join(tmpdir(), 'kg-report-'));
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerReportCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'report', ...args]);
+  }
+
+  it('registers the report command with its options', () => {
+    const program = new Command();
+    registerReportCommand(program);
+    const command = program.commands.find((c) => c.name() === 'report');
+    expect(command!.options.map((o) => o.long)).toEqual([
+      '--db',
+      '--output',
+      '--view',
+      '--config',
+    ]);
+  });
+
+  it('fails with an I/O error when the template is missing', async () => {
+    await run(join(dir, 'missing.tmpl'));
+    expect(process.exitCode).toBe(5);
+  });
+
+  it('fails with a parse error before reading the index', async () => {
+    const template = join(dir, 'broken.tmpl');
+    writeFileSync(template, '{{ range .entities }}');
+    await run(template, '--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(3);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    const template = join(dir, 'count.tmpl');
+    writeFileSync(template, '{{ len .entities }}');
+    await run(template, '--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+
+  it('gives templates the maturity of each module', async () => {
+    const dbPath = join(dir, 'knowgraph.db');
+    const dbManager = createDatabaseManager(dbPath);
+    dbManager.initialize();
+    dbManager.insertEntity({
+      filePath: 'src/charges.ts',
+      name: 'charges',
+      entityType: 'module',
+      description: 'Card charges',
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      owner: 'payments-team',
+      metadata: { type: 'module', description: 'Card charges' },
+    });
+    dbManager.close();
+    mkdirSync(join(dir, 'src'));
+    writeFileSync(join(dir, 'src', 'charges.test.ts'), '');
+    writeFileSync(
+      join(dir, '.knowgraph.yml'),
+      [
+        'version: "1.0"',
+        'maturity:',
+        '  levels:',
+        '    - name: owned',
+        '      require: [owner]',
+        '    - name: tested',
+        '      require: [tests]',
+        '    - name: operable',
+        '      require: [runbook]',
+      ].join('\n'),
+    );
+    const template = join(dir, 'maturity.tmpl');
+    writeFileSync(
+      template,
+      '{{ range (maturity).modules }}{{ .name }}={{ .levelName }}' +
+        '{{ range .roadmap }} next:{{ .name }}{{ end }}{{ end }}',
+    );
+    const output = join(dir, 'out.txt');
+    await run(
+      template,
+      '--db',
+      dbPath,
+      '--config',
+      join(dir, '.knowgraph.yml'),
+      '--output',
+      output,
+    );
+    expect(process.exitCode).toBeUndefined();
+    expect(readFileSync(output, 'utf-8')).toBe('charges=tested next:operable');
+  });
+});
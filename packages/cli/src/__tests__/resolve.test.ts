@@ -0,0 +1,71 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { Command } from 'commander';
+import type { Resolution } from '@know-graph/core';
+import {
+  formatResolutions,
+  registerResolveCommand,
+} from '../commands/resolve.js';
+
+const resolutions: readonly Resolution[] = [
+  {
+    query: 'issueToken',
+    kind: 'operation',
+    matches: [
+      {
+        id: 'issue',
+        name: 'HandleIssueToken',
+        entityType: 'function',
+        filePath: 'services/tokens/http/issue.go',
+        line: 12,
+        owner: 'identity-team',
+        repository: 'acme/app',
+        via: 'POST /tokens in services/tokens/api/tokens.yaml',
+      },
+    ],
+  },
+  { query: 'nothing-here', kind: null, matches: [] },
+];
+
+describe('resolve command', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerResolveCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'resolve', ...args]);
+  }
+
+  it('lists each match with its repository and how it was found', () => {
+    const output = formatResolutions(resolutions);
+    expect(output).toContain('issueToken (operation)');
+    expect(output).toContain(
+      '→ HandleIssueToken function services/tokens/http/issue.go:12',
+    );
+    expect(output).toContain(
+      '[acme/app, via POST /tokens in services/tokens/api/tokens.yaml]',
+    );
+    expect(output).toContain('nothing-here not found');
+  });
+
+  it('rejects an unknown identifier kind as a usage error', async () => {
+    await run('issueToken', '--from', 'url');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    await run('issueToken', '--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+});
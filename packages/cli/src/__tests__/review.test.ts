@@ -0,0 +1,108 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { readAuditLog } from '@know-graph/core';
+import { registerReviewCommand } from '../commands/review.js';
+
+const SOURCE = [
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Reverses the ledger entries of an order',
+  ' * generated: true',
+  ' */',
+  'export function refund(orderId: string): void {}',
+  '',
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Books a refund for later settlement',
+  ' * generated: true',
+  ' */',
+  'export function queueRefund(orderId: string): void {}',
+  '',
+].join('\n');
+
+describe('review command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-review-cli-'));
+    writeFileSync(join(dir, 'billing.ts'), SOURCE);
+    vi.stubEnv('KNOWGRAPH_ACTOR', 'ana@example.com');
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    vi.unstubAllEnvs();
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerReviewCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'review', ...args]);
+  }
+
+  function decide(...args: string[]): Promise<Command> {
+    return run(
+      ...args,
+      '--path',
+      dir,
+      '--config',
+      join(dir, '.knowgraph.yml'),
+    );
+  }
+
+  it('lists the annotations awaiting review', async () => {
+    await run('list', '--path', dir);
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('billing.ts');
+    expect(output).toContain('refund');
+    expect(output).toContain('2 annotation(s) awaiting review');
+  });
+
+  it('approves with the reviewer recorded and audited', async () => {
+    await decide('approve', 'refund');
+    expect(process.exitCode).toBeUndefined();
+    const content = readFileSync(join(dir, 'billing.ts'), 'utf-8');
+    expect(content).toContain('reviewed_by: ana@example.com');
+    expect(content.match(/generated: true/g)).toHaveLength(1);
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      'Approved refund',
+    );
+    const [entry] = readAuditLog(join(dir, '.knowgraph', 'audit.jsonl'));
+    expect(entry?.actor).toBe('ana@example.com');
+    expect(entry?.command).toBe('review approve');
+    expect(entry?.changes).toHaveLength(1);
+  });
+
+  it('rejects by path:line, leaving the symbol unannotated', async () => {
+    await decide('reject', 'billing.ts:10');
+    const content = readFileSync(join(dir, 'billing.ts'), 'utf-8');
+    expect(content).not.toContain('Books a refund');
+    expect(content).toContain('\n\nexport function queueRefund');
+  });
+
+  it('writes nothing in a dry run', async () => {
+    await decide('approve', 'refund', 'queueRefund', '--dry-run');
+    expect(String(consoleLogSpy.mock.calls[0]?.[0])).toContain(
+      '2 annotation(s) approved',
+    );
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).toBe(SOURCE);
+  });
+
+  it('fails on a target that is not awaiting review', async () => {
+    await decide('approve', 'settle');
+    expect(process.exitCode).toBe(2);
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).toBe(SOURCE);
+  });
+});
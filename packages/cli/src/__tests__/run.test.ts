@@ -0,0 +1,169 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { ManifestSchema } from '@know-graph/core';
+import type { AlertMessage, PipelineStepConfig } from '@know-graph/core';
+import {
+  planPipeline,
+  registerRunCommand,
+  runPipeline,
+} from '../commands/run.js';
+
+const CONFIG = [
+  'version: "1.0"',
+  'pipelines:',
+  '  nightly:',
+  '    - scan: { incremental: false }',
+  '    - enrich: [git, callgraph]',
+  '    - check: [annotations, licenses]',
+  '    - export: [json, backstage]',
+  '    - notify: [slack]',
+  '',
+].join('\n');
+
+function steps(pipeline: unknown[]): readonly PipelineStepConfig[] {
+  return ManifestSchema.parse({ version: '1.0', pipelines: { p: pipeline } })
+    .pipelines!.p;
+}
+
+describe('planPipeline', () => {
+  it('runs enrichers with the scan before them, and a command per check', () => {
+    const plan = planPipeline(
+      steps([
+        { scan: { incremental: false } },
+        { enrich: ['git', 'callgraph'] },
+        { check: ['annotations', 'licenses'] },
+        { export: ['json', 'backstage'] },
+        { notify: ['slack'] },
+      ]),
+    );
+    expect(plan).toEqual([
+      {
+        name: 'scan',
+        args: [
+          'index',
+          '.',
+          '--no-incremental',
+          '--enrichers',
+          'git,callgraph',
+        ],
+      },
+      { name: 'check annotations', args: ['check'] },
+      { name: 'check licenses', args: ['licenses'] },
+      { name: 'export json', args: ['export', '--format', 'json'] },
+      { name: 'export backstage', args: ['export', '--format', 'backstage'] },
+      { name: 'notify', sinks: ['slack'] },
+    ]);
+  });
+
+  it('refuses an enrich step without a scan before it', () => {
+    expect(() =>
+      planPipeline(steps([{ check: ['annotations'] }, { enrich: ['git'] }])),
+    ).toThrow('An enrich step must follow a scan step');
+    expect(() => steps([{ scan: {}, check: ['annotations'] }])).toThrow();
+  });
+});
+
+describe('runPipeline', () => {
+  it('skips commands after a failure, but still notifies', async () => {
+    const ran: string[] = [];
+    const messages: AlertMessage[] = [];
+    let clock = 0;
+    const run = await runPipeline(
+      'nightly',
+      planPipeline(
+        steps([
+          { scan: {} },
+          { check: ['annotations'] },
+          { export: ['json'] },
+          { notify: ['slack'] },
+        ]),
+      ),
+      {
+        exec: async (args) => {
+          ran.push(args[0]);
+          return args[0] === 'check' ? 1 : 0;
+        },
+        notify: async (_sinks, message) => {
+          messages.push(message);
+        },
+        log: () => {},
+        now: () => (clock += 1500),
+      },
+    );
+    expect(ran).toEqual(['index', 'check']);
+    expect(run.ok).toBe(false);
+    expect(run.steps.map((step) => step.status)).toEqual([
+      'ok',
+      'failed',
+      'skipped',
+    ]);
+    expect(messages[0].subject).toBe(
+      'knowgraph: pipeline nightly failed at check annotations',
+    );
+    expect(messages[0].lines).toEqual([
+      '- scan: ok in 1.5s',
+      '- check annotations: failed with exit code 1',
+      '- export json: skipped',
+    ]);
+  });
+});
+
+describe('run command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-run-'));
+    writeFileSync(join(dir, '.knowgraph.yml'), CONFIG);
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    program
+      .option('--error-format <format>', '', 'text')
+      .option('--log-level <level>', '', 'info')
+      .option('--log-format <format>', '', 'text');
+    registerRunCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'run',
+      ...args,
+      '--config',
+      join(dir, '.knowgraph.yml'),
+    ]);
+  }
+
+  it('prints the commands on a dry run', async () => {
+    await run('nightly', '--dry-run');
+    expect(consoleLogSpy.mock.calls.map((call) => call[0])).toEqual([
+      'knowgraph index . --no-incremental --enrichers git,callgraph',
+      'knowgraph check',
+      'knowgraph licenses',
+      'knowgraph export --format json',
+      'knowgraph export --format backstage',
+      'notify slack',
+    ]);
+  });
+
+  it('fails on an unknown pipeline', async () => {
+    await run('weekly', '--dry-run');
+    expect(process.exitCode).toBe(2);
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain(
+      "Unknown pipeline 'weekly'. Available: nightly",
+    );
+  });
+});
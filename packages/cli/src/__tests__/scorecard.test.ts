@@ -0,0 +1,149 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type {
+  MaturityReport,
+  OwnerScorecard,
+  ScorecardReport,
+} from '@know-graph/core';
+import {
+  formatMaturityMarkdown,
+  formatMaturityText,
+  formatScorecardHtml,
+  formatScorecardMarkdown,
+  formatScorecardSlack,
+  formatScorecardText,
+  registerScorecardCommand,
+} from '../commands/scorecard.js';
+
+const card: OwnerScorecard = {
+  owner: 'checkout',
+  metrics: {
+    entities: 4,
+    coverage: 75,
+    dated: 4,
+    stale: 1,
+    violations: 2,
+    deprecatedDependencies: 1,
+    needRunbook: 2,
+    missingRunbooks: 1,
+  },
+  scores: {
+    coverage: 75,
+    freshness: 75,
+    policy: 50,
+    dependencies: 75,
+    runbooks: 50,
+  },
+  score: 66,
+  grade: 'D',
+  previousScore: 60,
+  trend: 'up',
+};
+
+const report: ScorecardReport = {
+  generatedAt: '2024-07-01T00:00:00.000Z',
+  staleAfterDays: 180,
+  scorecards: [
+    {
+      ...card,
+      owner: 'search <team>',
+      score: 92,
+      grade: 'A',
+      previousScore: null,
+      trend: null,
+    },
+    card,
+  ],
+};
+
+const maturity: MaturityReport = {
+  generatedAt: '2024-07-01T00:00:00.000Z',
+  levels: [
+    { name: 'owned', require: ['owner'] },
+    { name: 'tested', require: ['tests'] },
+    { name: 'operable', require: ['runbook', 'slo'] },
+  ],
+  modules: [
+    {
+      entityId: 'refunds',
+      name: 'refunds',
+      entityType: 'module',
+      filePath: 'src/refunds.ts',
+      owner: 'checkout',
+      level: 1,
+      levelName: 'owned',
+      met: ['owner', 'runbook'],
+      roadmap: [
+        { level: 2, name: 'tested', missing: ['tests'] },
+        { level: 3, name: 'operable', missing: ['slo'] },
+      ],
+    },
+    {
+      entityId: 'ledger',
+      name: 'ledger',
+      entityType: 'module',
+      filePath: 'src/ledger.ts',
+      owner: null,
+      level: 3,
+      levelName: 'operable',
+      met: ['owner', 'tests', 'runbook', 'slo'],
+      roadmap: [],
+    },
+  ],
+  counts: [0, 1, 0, 1],
+};
+
+describe('scorecard command', () => {
+  it('registers the scorecard command', () => {
+    const program = new Command();
+    registerScorecardCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'scorecard');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toEqual(
+      expect.arrayContaining(['--previous', '--record', '--slack']),
+    );
+  });
+
+  it('shows each team with its grade and trend', () => {
+    const output = formatScorecardText(report);
+    expect(output).toContain('Team scorecards (2 teams)');
+    expect(output).toMatch(/checkout\s+.*D.*\s+66 ↑ \+6\s+75%\s+1\/4/);
+  });
+
+  it('renders a Markdown table', () => {
+    const output = formatScorecardMarkdown(report);
+    expect(output).toContain(
+      '| checkout | **D** | 66 ↑ +6 | 75% | 1/4 | 2 | 1 | 1/2 |',
+    );
+  });
+
+  it('renders an escaped HTML page', () => {
+    const output = formatScorecardHtml(report);
+    expect(output).toContain('<td>search &lt;team&gt;</td>');
+    expect(output).toContain('<td class="grade D">D</td>');
+  });
+
+  it('posts one line per team to Slack', () => {
+    expect(formatScorecardSlack(report).text.split('\n')).toEqual([
+      '*Team scorecards* (2024-07-01T00:00:00.000Z)',
+      '*A* search <team>: 92',
+      '*D* checkout: 66 ↑ +6',
+    ]);
+  });
+
+  it('lists each module with what it needs for the next level', () => {
+    const text = formatMaturityText(maturity);
+    expect(text).toContain('none 0, owned 1, tested 0, operable 1');
+    expect(text).toContain('refunds src/refunds.ts -> tested: needs tests');
+    const markdown = formatMaturityMarkdown(maturity);
+    expect(markdown).toContain(
+      '| refunds (src/refunds.ts) | checkout | 1 owned | tested: needs tests for its file |',
+    );
+    expect(markdown).toContain(
+      '| ledger (src/ledger.ts) | - | 3 operable | - |',
+    );
+    expect(formatScorecardHtml(report, maturity)).toContain(
+      '<h2>Module maturity</h2>',
+    );
+  });
+});
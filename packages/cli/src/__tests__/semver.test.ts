@@ -0,0 +1,85 @@
+import { describe, it, expect } from 'vitest';
+import { existsSync, mkdirSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import type { GitCommandRunner, VersionSuggestion } from '@know-graph/core';
+import { formatVersionSuggestion } from '../commands/semver.js';
+import { snapshotRef } from '../utils/release.js';
+
+const SERVICE = `/**
+ * @knowgraph
+ * type: service
+ * description: Charges customers for orders
+ * owner: billing-team
+ * status: stable
+ */
+export class BillingService {}
+`;
+
+describe('formatVersionSuggestion', () => {
+  const suggestion: VersionSuggestion = {
+    bump: 'minor',
+    current: '1.4.2',
+    next: '1.5.0',
+    reasons: [
+      {
+        bump: 'minor',
+        kind: 'node-added',
+        subject: 'src/ledger.ts:LedgerService',
+        detail: 'new service',
+      },
+      {
+        bump: 'patch',
+        kind: 'node-updated',
+        subject: 'src/billing.ts:BillingService',
+        detail: 'service changed',
+      },
+    ],
+  };
+
+  it('lists the deciding reasons and counts the rest', () => {
+    const output = formatVersionSuggestion(suggestion);
+    expect(output).toContain('minor');
+    expect(output).toContain('1.4.2 -> 1.5.0');
+    expect(output).toContain('src/ledger.ts:LedgerService');
+    expect(output).not.toContain('src/billing.ts:BillingService');
+    expect(output).toContain('1 patch-level change(s)');
+  });
+
+  it('explains a patch suggestion without changes', () => {
+    const output = formatVersionSuggestion({
+      bump: 'patch',
+      current: null,
+      next: null,
+      reasons: [],
+    });
+    expect(output).toContain('No entity or API changes');
+  });
+});
+
+describe('snapshotRef', () => {
+  it('scans the ref in a worktree and removes it afterwards', () => {
+    const calls: string[][] = [];
+    const git: GitCommandRunner = {
+      run(args) {
+        calls.push([...args]);
+        if (args[1] === 'add') {
+          const dir = args[4];
+          mkdirSync(join(dir, 'src'), { recursive: true });
+          writeFileSync(join(dir, 'src', 'billing.ts'), SERVICE);
+        }
+        return '';
+      },
+    };
+
+    const snapshot = snapshotRef('/repo', 'v1.4.2', git);
+
+    expect(snapshot.entities.map((e) => e.name)).toEqual(['BillingService']);
+    expect(snapshot.api).toBeUndefined();
+    expect(calls.map((args) => args.slice(0, 2))).toEqual([
+      ['worktree', 'add'],
+      ['worktree', 'remove'],
+    ]);
+    expect(calls[0][5]).toBe('v1.4.2');
+    expect(existsSync(calls[0][4])).toBe(false);
+  });
+});
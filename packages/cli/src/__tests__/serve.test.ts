@@ -0,0 +1,337 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  copyFileSync,
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join, resolve } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import {
+  isLoopbackHost,
+  parseListenAddress,
+  registerServeCommand,
+  resolveAuthOptions,
+  resolveNamespacedIndexes,
+  resolveRegistry,
+  resolveScanSchedule,
+  resolveSimulation,
+  resolveTrendSources,
+  resolveWebhooks,
+} from '../commands/serve.js';
+import { readServeConfig } from '../utils/manifest.js';
+import { runScheduledScan } from '../utils/scan-schedule.js';
+
+const FIXTURES_DIR = resolve(__dirname, 'fixtures');
+
+describe('parseListenAddress', () => {
+  it('splits host and port', () => {
+    expect(parseListenAddress('0.0.0.0:50051')).toEqual({
+      host: '0.0.0.0',
+      port: 50051,
+    });
+    expect(parseListenAddress('[::]:9000')).toEqual({
+      host: '[::]',
+      port: 9000,
+    });
+  });
+
+  it('binds loopback for a bare port', () => {
+    expect(parseListenAddress('50051')).toEqual({
+      host: '127.0.0.1',
+      port: 50051,
+    });
+  });
+
+  it('rejects malformed addresses', () => {
+    expect(parseListenAddress('localhost')).toBeUndefined();
+    expect(parseListenAddress('localhost:99999')).toBeUndefined();
+  });
+});
+
+describe('isLoopbackHost', () => {
+  it('recognises loopback addresses only', () => {
+    expect(isLoopbackHost('127.0.0.1')).toBe(true);
+    expect(isLoopbackHost('localhost')).toBe(true);
+    expect(isLoopbackHost('[::1]')).toBe(true);
+    expect(isLoopbackHost('0.0.0.0')).toBe(false);
+    expect(isLoopbackHost('10.0.0.5')).toBe(false);
+  });
+});
+
+describe('resolveAuthOptions', () => {
+  it('reads tokens and secrets from the environment', () => {
+    const auth = resolveAuthOptions(
+      {
+        auth: {
+          tokens: [{ name: 'ci', token_env: 'KG_CI', roles: ['reader'] }],
+          jwt: { secret_env: 'KG_JWT', roles_claim: 'groups' },
+        },
+        restricted_fields: { compliance: ['compliance'] },
+      },
+      { KG_CI: 'ci-token', KG_JWT: 'shh' },
+    );
+    expect(auth.tokens).toEqual([
+      { name: 'ci', token: 'ci-token', roles: ['reader'] },
+    ]);
+    expect(auth.jwt).toMatchObject({ secret: 'shh', rolesClaim: 'groups' });
+    expect(auth.restrictedFields).toEqual({ compliance: ['compliance'] });
+  });
+
+  it('fails when a referenced variable is unset', () => {
+    expect(() =>
+      resolveAuthOptions(
+        { auth: { tokens: [{ name: 'ci', token_env: 'KG_CI', roles: [] }] } },
+        {},
+      ),
+    ).toThrow(/KG_CI/);
+  });
+});
+
+describe('serve namespaces', () => {
+  const config = {
+    namespaces: {
+      'acme/payments': { roles: ['payments'] },
+      'acme/search': { db: 'indexes/search.db', roles: ['search'] },
+      'acme/docs': { db: '/srv/docs.db' },
+    },
+  };
+
+  it('maps namespace roles into access rules', () => {
+    expect(resolveAuthOptions(config, {}).namespaceRoles).toEqual({
+      'acme/payments': ['payments'],
+      'acme/search': ['search'],
+    });
+  });
+
+  it('resolves hosted indexes against the manifest directory', () => {
+    expect(resolveNamespacedIndexes(config, '/repo/.knowgraph.yml')).toEqual([
+      { namespace: 'acme/search', dbPath: '/repo/indexes/search.db' },
+      { namespace: 'acme/docs', dbPath: '/srv/docs.db' },
+    ]);
+  });
+
+  it('finds each hosted index history next to its database', () => {
+    const withHistory = {
+      ...config,
+      namespaces: {
+        ...config.namespaces,
+        'acme/docs': { db: '/srv/docs.db', history: 'docs-history.jsonl' },
+      },
+    };
+    expect(
+      resolveTrendSources(withHistory, '/repo/.knowgraph.yml', 'acme/payments'),
+    ).toEqual([
+      {
+        namespace: 'acme/payments',
+        historyPath: '/repo/.knowgraph/history.jsonl',
+        scorecardsPath: '/repo/.knowgraph/scorecards.jsonl',
+      },
+      {
+        namespace: 'acme/search',
+        historyPath: '/repo/indexes/history.jsonl',
+        scorecardsPath: '/repo/indexes/scorecards.jsonl',
+      },
+      {
+        namespace: 'acme/docs',
+        historyPath: '/repo/docs-history.jsonl',
+        scorecardsPath: '/srv/scorecards.jsonl',
+      },
+    ]);
+  });
+});
+
+describe('resolveRegistry', () => {
+  let dir: string | undefined;
+
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('opens the stores relative to the manifest directory', () => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-serve-'));
+    const configPath = join(dir, '.knowgraph.yml');
+    expect(resolveRegistry({}, configPath)).toBeUndefined();
+
+    const registry = resolveRegistry(
+      {
+        registry: {
+          path: 'registry.json',
+          admin_roles: ['platform'],
+          graphs: 'graphs',
+        },
+      },
+      configPath,
+    );
+    expect(registry?.adminRoles).toEqual(['platform']);
+    registry?.store.put('tokens', 'ci', { roles: [] });
+    expect(readFileSync(join(dir, 'registry.json'), 'utf-8')).toContain(
+      '"ci"',
+    );
+    registry?.graphs?.put('payments', { nodes: [], edges: [] });
+    expect(existsSync(join(dir, 'graphs', 'payments.kgs'))).toBe(true);
+  });
+});
+
+describe('resolveWebhooks', () => {
+  it('reads secrets and resolves the fact log next to the manifest', () => {
+    const config = {
+      webhooks: {
+        path: 'logs/facts.jsonl',
+        sources: {
+          argo: {
+            secret_env: 'ARGO_WEBHOOK_SECRET',
+            signature_header: 'X-Hub-Signature-256',
+            node: 'app.name',
+            facts: { version: 'app.version' },
+          },
+        },
+      },
+    };
+    expect(resolveWebhooks({}, '/repo/.knowgraph.yml')).toBeUndefined();
+    expect(
+      resolveWebhooks(config, '/repo/.knowgraph.yml', {
+        ARGO_WEBHOOK_SECRET: 'shh',
+      }),
+    ).toEqual({
+      logPath: resolve('/repo/logs/facts.jsonl'),
+      sources: {
+        argo: {
+          secret: 'shh',
+          signatureHeader: 'X-Hub-Signature-256',
+          mapping: { node: 'app.name', facts: { version: 'app.version' } },
+        },
+      },
+    });
+    expect(() => resolveWebhooks(config, '/repo/.knowgraph.yml', {})).toThrow(
+      'ARGO_WEBHOOK_SECRET',
+    );
+  });
+});
+
+describe('readServeConfig', () => {
+  let dir: string | undefined;
+
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('returns the serve section and rejects invalid manifests', () => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-serve-'));
+    const configPath = join(dir, '.knowgraph.yml');
+    expect(readServeConfig(configPath)).toEqual({});
+
+    writeFileSync(
+      configPath,
+      'version: "1.0"\nserve:\n  restricted_fields:\n    compliance: [auditor]\n',
+    );
+    expect(readServeConfig(configPath)).toEqual({
+      restricted_fields: { compliance: ['auditor'] },
+    });
+
+    writeFileSync(configPath, 'version: "1.0"\nserve:\n  auth:\n    jwt: {}\n');
+    expect(() => readServeConfig(configPath)).toThrow(/Invalid/);
+  });
+});
+
+describe('resolveScanSchedule', () => {
+  it('prefers the flag over serve.scan_schedule', () => {
+    const config = { scan_schedule: '@daily' };
+    expect(resolveScanSchedule('0 */6 * * *', config)?.expression).toBe(
+      '0 */6 * * *',
+    );
+    expect(resolveScanSchedule(undefined, config)?.expression).toBe('@daily');
+    expect(resolveScanSchedule(undefined, {})).toBeUndefined();
+  });
+
+  it('rejects invalid cron expressions', () => {
+    expect(() => resolveScanSchedule('every hour', {})).toThrow(
+      /expected 5 fields/,
+    );
+  });
+});
+
+describe('resolveSimulation', () => {
+  let dir: string | undefined;
+
+  afterEach(() => {
+    if (dir) rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('reads changed files from a local scan path only', () => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-serve-'));
+    const configPath = join(dir, '.knowgraph.yml');
+    writeFileSync(
+      configPath,
+      'version: "1.0"\ncycles:\n  default_budget: 0\n',
+    );
+    expect(resolveSimulation(dir, configPath)).toEqual({
+      rootDir: dir,
+      cycles: { budgets: {}, defaultBudget: 0 },
+    });
+    expect(
+      resolveSimulation('https://github.com/acme/shop.git', configPath),
+    ).toEqual({ cycles: { budgets: {}, defaultBudget: 0 } });
+  });
+});
+
+describe('scheduled scans', () => {
+  let dir: string;
+  let errorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-scan-'));
+    errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    errorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('indexes the repository and records each scan', async () => {
+    copyFileSync(join(FIXTURES_DIR, 'sample.ts'), join(dir, 'sample.ts'));
+    // Under a dot directory, which scans skip, so only sample.ts is counted
+    mkdirSync(join(dir, '.knowgraph'));
+    const dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    await runScheduledScan(dir, dbPath);
+    await runScheduledScan(dir, dbPath);
+
+    const history = readFileSync(
+      join(dir, '.knowgraph', 'history.jsonl'),
+      'utf-8',
+    );
+    expect(history.trim().split('\n')).toHaveLength(2);
+    expect(String(errorSpy.mock.calls[0][0])).toContain('Rescanned 1 file(s)');
+  });
+
+  it('refuses to serve with an invalid schedule', async () => {
+    const dbPath = join(dir, 'knowgraph.db');
+    writeFileSync(dbPath, '');
+    const program = new Command();
+    registerServeCommand(program);
+    await program.parseAsync([
+      'node',
+      'knowgraph',
+      'serve',
+      '--db',
+      dbPath,
+      '--config',
+      join(dir, '.knowgraph.yml'),
+      '--scan-schedule',
+      '61 * * * *',
+    ]);
+    expect(process.exitCode).toBe(2);
+    expect(errorSpy).toHaveBeenCalledWith(
+      expect.stringContaining('Invalid minute "61"'),
+    );
+  });
+});
@@ -0,0 +1,134 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerSimulateCommand } from '../commands/simulate.js';
+import { indexInto } from '../utils/indexing.js';
+
+function service(
+  name: string,
+  owner: string,
+  dependencies: readonly string[],
+  status = 'stable',
+): string {
+  return [
+    `class ${name}:`,
+    '    """',
+    '    @knowgraph',
+    '    type: service',
+    `    description: The ${name} service`,
+    `    owner: ${owner}`,
+    `    status: ${status}`,
+    '    dependencies:',
+    ...dependencies.map((line) => `      ${line}`),
+    '    """',
+    '',
+  ].join('\n');
+}
+
+describe('simulate command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-simulate-'));
+    writeFileSync(
+      join(dir, 'login.py'),
+      service('LoginService', 'auth-team', [
+        'databases: [redis-sessions]',
+      ]),
+    );
+    writeFileSync(
+      join(dir, 'profile.py'),
+      service('ProfileService', 'accounts-team', [
+        'databases: [redis-sessions]',
+      ]),
+    );
+    writeFileSync(
+      join(dir, 'web.py'),
+      service('WebService', 'web-team', ['services: [LoginService]']),
+    );
+    writeFileSync(
+      join(dir, 'store.py'),
+      service(
+        'LegacyStore',
+        'platform-team',
+        ['databases: [mysql]'],
+        'deprecated',
+      ),
+    );
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerSimulateCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'simulate',
+      ...args,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('prints a migration checklist grouped by team', async () => {
+    await run('redis-sessions', '--replace-with', 'valkey');
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('Replacing redis-sessions with valkey');
+    expect(output).toContain('affects 3 node(s)');
+    expect(output.indexOf('accounts-team')).toBeLessThan(
+      output.indexOf('auth-team'),
+    );
+    expect(output).toContain(
+      '[ ] LoginService: replace its database dependency on redis-sessions with valkey',
+    );
+    expect(output).toContain('Affected through other dependencies: WebService');
+    expect(output).toContain('No policy would newly fail.');
+  });
+
+  it('writes the checklist as markdown', async () => {
+    await run('redis-sessions', '--format', 'markdown');
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('# Removing redis-sessions');
+    expect(output).toContain('## auth-team');
+    expect(output).toContain(
+      '- [ ] **LoginService**: remove its database dependency on redis-sessions',
+    );
+  });
+
+  it('fails --check when dependents would move to a deprecated node', async () => {
+    await run(
+      'redis-sessions',
+      '--replace-with',
+      'LegacyStore',
+      '--format',
+      'json',
+      '--check',
+    );
+    const result = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(result.violations).toHaveLength(1);
+    expect(result.violations[0].policy).toBe('deprecated-dependency');
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('fails on a name that matches no node', async () => {
+    await run('memcached');
+    expect(process.exitCode).toBe(2);
+  });
+});
@@ -0,0 +1,119 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  existsSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerSinkCommand, testSinks } from '../commands/sink.js';
+
+const MANIFEST = [
+  'version: "1.0"',
+  'warehouse:',
+  '  sinks:',
+  '    - type: bigquery',
+  '      project: acme',
+  '      dataset: eng',
+  'history:',
+  '  sinks:',
+  '    - type: slack',
+  '      url: https://hooks.slack.com/services/T0/B0/secret',
+  '    - type: webhook',
+  '      url_env: UNSET_WEBHOOK_URL',
+  '    - type: file',
+  '      path: alerts.jsonl',
+  'delivery:',
+  '  attempts: 1',
+].join('\n');
+
+describe('sink test command', () => {
+  let dir: string;
+  let configPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-sink-'));
+    configPath = join(dir, '.knowgraph.yml');
+    writeFileSync(configPath, MANIFEST);
+    vi.stubGlobal(
+      'fetch',
+      vi.fn(async (url: string) =>
+        url.includes('bigquery')
+          ? { ok: true, status: 200, text: async () => '{}' }
+          : { ok: false, status: 404, statusText: 'Not Found' },
+      ),
+    );
+    vi.stubEnv('GOOGLE_OAUTH_ACCESS_TOKEN', 'token');
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    vi.unstubAllGlobals();
+    vi.unstubAllEnvs();
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerSinkCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'sink',
+      'test',
+      ...args,
+      '--config',
+      configPath,
+    ]);
+  }
+
+  it('tests every sink and says how to fix the failing ones', async () => {
+    const results = await testSinks(configPath);
+    expect(results.map(({ sink, target, ok }) => [sink, target, ok])).toEqual([
+      ['bigquery', 'acme.eng', true],
+      ['slack', 'hooks.slack.com', false],
+      ['webhook', '$UNSET_WEBHOOK_URL', false],
+      ['file', 'alerts.jsonl', true],
+    ]);
+    expect(results[1]).toMatchObject({
+      error: 'Delivery to slack failed: 404 Not Found',
+      fix: 'Check the webhook in url still exists and accepts POSTs.',
+    });
+    expect(results[2].error).toBe('UNSET_WEBHOOK_URL is not set');
+    const [line] = readFileSync(join(dir, 'alerts.jsonl'), 'utf-8').split('\n');
+    expect(JSON.parse(line).event).toBe('knowgraph.test');
+    expect(existsSync(join(dir, '.knowgraph', 'outbox.jsonl'))).toBe(false);
+  });
+
+  it('prints results and exits with the I/O code on a failure', async () => {
+    await run();
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('✓ bigquery  acme.eng');
+    expect(output).toContain('Fix: Check the webhook in url');
+    expect(output).toContain('2 of 4 sink(s) failed');
+    expect(process.exitCode).toBe(5);
+  });
+
+  it('tests only the sinks of one type', async () => {
+    await run('bigquery', '--format', 'json');
+    const results = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(results).toEqual([
+      { sink: 'bigquery', kind: 'warehouse', target: 'acme.eng', ok: true },
+    ]);
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('fails when no sink of the type is configured', async () => {
+    await run('snowflake');
+    expect(process.exitCode).toBe(2);
+  });
+});
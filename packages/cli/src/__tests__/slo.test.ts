@@ -0,0 +1,68 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { SloReport } from '@know-graph/core';
+import { formatSloReport, registerSloCommand } from '../commands/slo.js';
+
+function makeReport(): SloReport {
+  return {
+    totalEntries: 2,
+    groups: [
+      {
+        owner: 'payments-team',
+        entries: [
+          {
+            entityId: 'a',
+            name: 'checkout',
+            entityType: 'service',
+            filePath: 'src/checkout.ts',
+            line: 1,
+            owner: 'payments-team',
+            slo: { availability: 99.9, latency_ms: 300 },
+            sli: { availability: 99.95, errorBudgetRemaining: 50 },
+          },
+          {
+            entityId: 'b',
+            name: 'refunds',
+            entityType: 'module',
+            filePath: 'src/refunds.ts',
+            line: 1,
+            owner: 'payments-team',
+            slo: { latency_ms: 800, latency_percentile: 95 },
+            sli: { error: 'Query returned no data' },
+          },
+        ],
+      },
+    ],
+  };
+}
+
+describe('slo command', () => {
+  it('registers the slo command', () => {
+    const program = new Command();
+    registerSloCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'slo');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toContain('--prometheus');
+  });
+
+  it('formats targets and error budgets per owner', () => {
+    const output = formatSloReport(makeReport());
+    expect(output).toContain('payments-team');
+    expect(output).toContain('checkout (service)');
+    expect(output).toContain('99.9%, p99 < 300ms');
+    expect(output).toContain('actual 99.95%');
+    expect(output).toContain('50% budget left');
+  });
+
+  it('shows custom percentiles and SLI errors', () => {
+    const output = formatSloReport(makeReport());
+    expect(output).toContain('p95 < 800ms');
+    expect(output).toContain('SLI: Query returned no data');
+  });
+
+  it('reports when no slos exist', () => {
+    expect(formatSloReport({ groups: [], totalEntries: 0 })).toBe(
+      'No entities declare an slo.',
+    );
+  });
+});
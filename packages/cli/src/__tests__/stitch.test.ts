@@ -0,0 +1,233 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import {
+  externalNode,
+  namespaceGraph,
+  readGraphSnapshot,
+} from '@know-graph/core';
+import type { DependencyGraph, GraphNode } from '@know-graph/core';
+import { readGraphFile, registerStitchCommand } from '../commands/stitch.js';
+
+function node(id: string, overrides: Partial<GraphNode> = {}): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+const shop: DependencyGraph = {
+  nodes: [
+    node('checkout'),
+    externalNode('service', 'token-service'),
+    externalNode('external_api', 'stripe'),
+  ],
+  edges: [
+    { from: 'checkout', to: 'external:service:token-service', kind: 'service' },
+    {
+      from: 'checkout',
+      to: 'external:external_api:stripe',
+      kind: 'external_api',
+    },
+  ],
+};
+
+const identity: DependencyGraph = {
+  nodes: [node('auth', { aliases: ['token-service'] })],
+  edges: [],
+};
+
+describe('stitch command', () => {
+  let dir: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-stitch-'));
+    writeFileSync(join(dir, 'shop.json'), JSON.stringify(shop));
+    writeFileSync(join(dir, 'identity.json'), JSON.stringify(identity));
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerStitchCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'stitch', ...args]);
+  }
+
+  it('writes the merged graph and lists unresolved stubs', async () => {
+    const output = join(dir, 'merged.json');
+    await run(
+      join(dir, 'shop.json'),
+      join(dir, 'identity.json'),
+      '--output',
+      output,
+    );
+    const merged = readGraphFile(output);
+    expect(merged.edges).toContainEqual({
+      from: 'checkout',
+      to: 'auth',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    });
+    expect(merged.nodes.map((n) => n.id)).not.toContain(
+      'external:service:token-service',
+    );
+    const report = consoleLogSpy.mock.calls.map((c) => c[0]).join('\n');
+    expect(report).toContain('resolved 1 stubs');
+    expect(report).toContain('stripe');
+    expect(report).toContain('<- checkout');
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('writes .kgs snapshots and fails --check on leftovers', async () => {
+    const output = join(dir, 'merged.kgs');
+    await run(join(dir, 'shop.json'), '--output', output, '--check');
+    expect(readGraphSnapshot(output).nodes).toHaveLength(3);
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('writes only the namespaces given with --namespace', async () => {
+    writeFileSync(
+      join(dir, 'shop-ns.json'),
+      JSON.stringify(namespaceGraph(shop, 'acme/shop')),
+    );
+    writeFileSync(
+      join(dir, 'identity-ns.json'),
+      JSON.stringify(namespaceGraph(identity, 'acme/identity')),
+    );
+    const graphs = [join(dir, 'shop-ns.json'), join(dir, 'identity-ns.json')];
+    const output = join(dir, 'merged.json');
+
+    await run(...graphs, '--output', output, '--namespace', 'acme/shop');
+    expect(readGraphFile(output).nodes.map((n) => n.id)).toEqual([
+      'acme/shop:checkout',
+      'external:external_api:stripe',
+    ]);
+
+    await run(...graphs, '--output', output, '--namespace', 'acme');
+    expect(readGraphFile(output).edges).toContainEqual({
+      from: 'acme/shop:checkout',
+      to: 'acme/identity:auth',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    });
+
+    await run(...graphs, '--output', output, '--namespace', 'acme:shop');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('locates services two graphs both define and fails --check', async () => {
+    writeFileSync(
+      join(dir, 'legacy.json'),
+      JSON.stringify({
+        nodes: [node('legacy-auth', { name: 'Auth' })],
+        edges: [],
+      }),
+    );
+    await run(
+      join(dir, 'identity.json'),
+      join(dir, 'legacy.json'),
+      '--output',
+      join(dir, 'merged.json'),
+      '--check',
+    );
+    const report = consoleLogSpy.mock.calls.map((c) => c[0]).join('\n');
+    expect(report).toContain('Every external stub resolved.');
+    expect(report).toContain('2 service nodes are named "auth"');
+    expect(report).toContain(`${join(dir, 'identity.json')}: src/auth.ts`);
+    expect(report).toContain(
+      `${join(dir, 'legacy.json')}: src/legacy-auth.ts`,
+    );
+    expect(process.exitCode).toBe(1);
+  });
+
+  it('resolves stubs through --registry and flags near misses', async () => {
+    const users: DependencyGraph = {
+      nodes: [
+        node('acme/users:users_api.py:UserService', {
+          name: 'UserService',
+          namespace: 'acme/users',
+        }),
+      ],
+      edges: [],
+    };
+    const orders: DependencyGraph = {
+      nodes: [
+        node('orders'),
+        externalNode('service', 'user-service'),
+        externalNode('service', 'UserServce'),
+      ],
+      edges: [
+        {
+          from: 'orders',
+          to: 'external:service:user-service',
+          kind: 'service',
+        },
+        { from: 'orders', to: 'external:service:UserServce', kind: 'service' },
+      ],
+    };
+    writeFileSync(join(dir, 'users.json'), JSON.stringify(users));
+    writeFileSync(join(dir, 'orders.json'), JSON.stringify(orders));
+    writeFileSync(
+      join(dir, 'services.yml'),
+      'services:\n  user-service:\n    repo: acme/users\n    node: UserService\n',
+    );
+    const output = join(dir, 'merged.json');
+    await run(
+      join(dir, 'orders.json'),
+      join(dir, 'users.json'),
+      '--output',
+      output,
+      '--registry',
+      join(dir, 'services.yml'),
+    );
+    expect(readGraphFile(output).edges[0].to).toBe(
+      'acme/users:users_api.py:UserService',
+    );
+    const report = consoleLogSpy.mock.calls.map((c) => c[0]).join('\n');
+    expect(report).toContain('resolved 1 stubs');
+    expect(report).toContain('did you mean "UserService"?');
+  });
+
+  it('rejects an invalid registry as a schema error', async () => {
+    writeFileSync(join(dir, 'services.yml'), 'services:\n  users: [acme]\n');
+    await run(
+      join(dir, 'shop.json'),
+      '--output',
+      join(dir, 'out.json'),
+      '--registry',
+      join(dir, 'services.yml'),
+    );
+    expect(process.exitCode).toBe(4);
+  });
+
+  it('rejects files that are not graph exports', async () => {
+    writeFileSync(join(dir, 'other.json'), '{"entities":[]}');
+    await run(join(dir, 'other.json'), '--output', join(dir, 'out.json'));
+    expect(process.exitCode).toBe(4);
+    expect(() => readFileSync(join(dir, 'out.json'))).toThrow();
+  });
+});
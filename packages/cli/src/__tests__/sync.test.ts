@@ -120,7 +120,7 @@ describe('runSync integration', () => {
       '/nonexistent/path/.knowgraph.yml',
     ]);
 
-    expect(process.exitCode).toBe(1);
+    expect(process.exitCode).toBe(5);
     expect(consoleErrorSpy).toHaveBeenCalled();
     const errorArg = consoleErrorSpy.mock.calls[0]?.[0] ?? '';
     expect(errorArg).toContain('Config not found');
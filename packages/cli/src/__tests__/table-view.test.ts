@@ -0,0 +1,89 @@
+import { describe, it, expect } from 'vitest';
+import { toTableKey } from '../utils/table-terminal.js';
+import {
+  compareCells,
+  createTableState,
+  exportTable,
+  renderTable,
+  updateTable,
+} from '../utils/table-view.js';
+import type { TableKey, TableState } from '../utils/table-view.js';
+
+const data = {
+  headers: ['Name', 'Owner', 'Commits'],
+  rows: [
+    ['checkout', 'payments-team', '12'],
+    ['ledger', 'billing|ops', '3'],
+    ['avatars', '-', '120'],
+  ],
+};
+
+function press(state: TableState, ...keys: TableKey[]): TableState {
+  return keys.reduce((current, key) => updateTable(current, key).state, state);
+}
+
+describe('table view', () => {
+  it('sorts numbers by value, toggling the direction', () => {
+    expect(compareCells('12', '120')).toBeLessThan(0);
+    expect(compareCells('9.5%', '10%')).toBeLessThan(0);
+    expect(compareCells('Ledger', 'avatars')).toBeGreaterThan(0);
+
+    const state = press(createTableState(data), 'right', 'right', 'sort');
+    expect(state.sort).toEqual({ column: 2, descending: false });
+    expect(state.order).toEqual([1, 0, 2]);
+    expect(press(state, 'sort').order).toEqual([2, 0, 1]);
+  });
+
+  it('hides the selected column and exports what is shown', () => {
+    let state = press(createTableState(data), 'sort', 'right', 'hide');
+    expect(state.hidden).toEqual([1]);
+    expect(state.column).toBe(2);
+
+    expect(exportTable(state, 'csv')).toBe(
+      'Name,Commits\navatars,120\ncheckout,12\nledger,3\n',
+    );
+    expect(JSON.parse(exportTable(state, 'json'))[0]).toEqual({
+      Name: 'avatars',
+      Commits: '120',
+    });
+
+    state = press(state, 'showAll');
+    expect(exportTable(state, 'markdown').split('\n').slice(0, 3)).toEqual([
+      '| Name | Owner | Commits |',
+      '|---|---|---|',
+      '| avatars | - | 120 |',
+    ]);
+    expect(exportTable(state, 'markdown')).toContain('billing\\|ops');
+
+    const last = press(
+      createTableState({ headers: ['Name'], rows: [] }),
+      'hide',
+    );
+    expect(last.hidden).toEqual([]);
+    expect(last.status).toBe('The last column cannot be hidden');
+    expect(updateTable(state, { export: 'csv' }).effect).toEqual({
+      type: 'export',
+      format: 'csv',
+    });
+  });
+
+  it('renders the page holding the cursor with the key help', () => {
+    const state = press(createTableState(data), 'sort', 'down');
+    const screen = renderTable(state, { rows: 5, columns: 60 }, 'query');
+    const lines = screen.split('\n');
+    expect(lines).toHaveLength(5);
+    expect(lines[0]).toContain('3 rows, by Name ascending');
+    expect(lines[1]).toContain('Name ^');
+    expect(screen).toContain('checkout');
+    expect(screen).not.toContain('ledger');
+    expect(lines[4]).toContain('s sort');
+  });
+
+  it('maps keys to table actions', () => {
+    expect(toTableKey(undefined, { name: 'left' })).toBe('left');
+    expect(toTableKey('s', { name: 's' })).toBe('sort');
+    expect(toTableKey('m', { name: 'm' })).toEqual({ export: 'markdown' });
+    expect(toTableKey(undefined, { name: 'c', ctrl: true })).toBe('quit');
+    expect(toTableKey('x', { name: 'x' })).toBeUndefined();
+  });
+});
@@ -0,0 +1,106 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import {
+  copyFileSync,
+  mkdirSync,
+  mkdtempSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join, resolve } from 'node:path';
+import { tmpdir } from 'node:os';
+import { TelemetryConfigSchema } from '@know-graph/core';
+import { indexInto } from '../utils/indexing.js';
+import {
+  flushTelemetry,
+  getTelemetry,
+  resolveTelemetrySettings,
+  startTelemetry,
+} from '../utils/telemetry.js';
+
+const SAMPLE = resolve(__dirname, 'fixtures', 'sample.ts');
+
+describe('resolveTelemetrySettings', () => {
+  const config = TelemetryConfigSchema.parse({
+    endpoint: 'http://collector:4318',
+    headers_env: { 'x-api-key': 'COLLECTOR_KEY' },
+  });
+
+  it('sends nothing without an endpoint or when disabled', () => {
+    const defaults = TelemetryConfigSchema.parse({});
+    expect(resolveTelemetrySettings(defaults, '1.2.3', {})).toBeUndefined();
+    expect(
+      resolveTelemetrySettings(config, '1.2.3', { OTEL_SDK_DISABLED: 'true' }),
+    ).toBeUndefined();
+  });
+
+  it('lets the OTEL variables override the manifest', () => {
+    const settings = resolveTelemetrySettings(config, '1.2.3', {
+      COLLECTOR_KEY: 's3cret',
+      OTEL_EXPORTER_OTLP_ENDPOINT: 'https://otlp.example.com',
+      OTEL_EXPORTER_OTLP_HEADERS: 'x-tenant=acme%20corp',
+      OTEL_SERVICE_NAME: 'knowgraph-nightly',
+    });
+
+    expect(settings?.exporter).toMatchObject({
+      endpoint: 'https://otlp.example.com',
+      headers: { 'x-api-key': 's3cret', 'x-tenant': 'acme corp' },
+      resource: {
+        'service.name': 'knowgraph-nightly',
+        'service.version': '1.2.3',
+      },
+      timeoutMs: 10_000,
+    });
+    expect(settings?.intervalMs).toBe(60_000);
+  });
+});
+
+describe('startTelemetry', () => {
+  let dir: string;
+  let fetchSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-telemetry-'));
+    mkdirSync(join(dir, 'src'));
+    mkdirSync(join(dir, '.knowgraph'));
+    copyFileSync(SAMPLE, join(dir, 'src', 'sample.ts'));
+    writeFileSync(
+      join(dir, '.knowgraph.yml'),
+      'version: "1.0"\ntelemetry:\n  endpoint: http://collector:4318\n',
+    );
+    fetchSpy = vi
+      .spyOn(globalThis, 'fetch')
+      .mockImplementation(async () => new Response('', { status: 200 }));
+  });
+
+  afterEach(() => {
+    fetchSpy.mockRestore();
+    startTelemetry(join(dir, 'missing.yml'), '1.2.3', {});
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('traces an index run with its phases and sends it on flush', async () => {
+    startTelemetry(join(dir, '.knowgraph.yml'), '1.2.3', {});
+
+    indexInto(dir, join(dir, '.knowgraph', 'knowgraph.db'), {
+      incremental: false,
+    });
+    await flushTelemetry();
+
+    const urls = fetchSpy.mock.calls.map((call) => String(call[0]));
+    expect(urls).toEqual([
+      'http://collector:4318/v1/traces',
+      'http://collector:4318/v1/metrics',
+    ]);
+    const traces = JSON.parse(String(fetchSpy.mock.calls[0][1]?.body));
+    const [span] = traces.resourceSpans[0].scopeSpans[0].spans;
+    expect(span.name).toBe('knowgraph.index');
+    const keys = span.attributes.map((kv: { key: string }) => kv.key);
+    expect(keys).toContain('knowgraph.files');
+    expect(keys).toContain('knowgraph.phase.parse.ms');
+  });
+
+  it('records nothing once the endpoint is gone', () => {
+    startTelemetry(join(dir, 'missing.yml'), '1.2.3', {});
+    expect(getTelemetry()).toBeUndefined();
+  });
+});
@@ -0,0 +1,88 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { registerTombstonesCommand } from '../commands/tombstones.js';
+import { indexInto } from '../utils/indexing.js';
+
+function service(name: string): string {
+  return [
+    '"""',
+    '@knowgraph',
+    'type: service',
+    `description: The ${name} service`,
+    `owner: ${name}-team`,
+    '"""',
+    `class ${name}:`,
+    '    pass',
+    '',
+  ].join('\n');
+}
+
+describe('tombstones command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-tombstones-'));
+    writeFileSync(join(dir, 'orders.py'), service('orders'));
+    writeFileSync(join(dir, 'legacy.py'), service('legacy'));
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    rmSync(join(dir, 'legacy.py'));
+    indexInto(dir, dbPath, { incremental: true });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerTombstonesCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'tombstones',
+      ...args,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('lists the nodes removed by a scan', async () => {
+    await run();
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('legacy');
+    expect(output).toContain('file_deleted');
+    expect(output).not.toContain('orders');
+  });
+
+  it('writes JSON and filters by removal time', async () => {
+    await run('--format', 'json');
+    expect(JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]))).toEqual([
+      expect.objectContaining({
+        name: 'legacy',
+        filePath: 'legacy.py',
+        owner: 'legacy-team',
+        reason: 'file_deleted',
+      }),
+    ]);
+    await run('--since', '2999-01-01', '--format', 'json');
+    expect(JSON.parse(String(consoleLogSpy.mock.calls[1]?.[0]))).toEqual([]);
+  });
+
+  it('rejects a --since that is not a date', async () => {
+    await run('--since', 'yesterday');
+    expect(process.exitCode).toBe(2);
+  });
+});
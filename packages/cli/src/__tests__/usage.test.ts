@@ -0,0 +1,159 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { readUsageState, writeUsageState } from '@know-graph/core';
+import { registerTelemetryCommand } from '../commands/telemetry.js';
+import {
+  recordUsage,
+  usageCommandName,
+  usageStatePath,
+} from '../utils/usage.js';
+
+describe('usage reporting', () => {
+  let dir: string;
+  let env: Record<string, string>;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  const originalConfigHome = process.env.XDG_CONFIG_HOME;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-usage-'));
+    env = { XDG_CONFIG_HOME: dir };
+    process.env.XDG_CONFIG_HOME = dir;
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    if (originalConfigHome === undefined) delete process.env.XDG_CONFIG_HOME;
+    else process.env.XDG_CONFIG_HOME = originalConfigHome;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    program.version('1.2.3');
+    registerTelemetryCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'telemetry', ...args]);
+  }
+
+  it('names commands by their path, without arguments', async () => {
+    const program = new Command();
+    let name = '';
+    program.hook('preAction', (_program, actionCommand) => {
+      name = usageCommandName(actionCommand);
+    });
+    program
+      .command('deployments')
+      .command('import')
+      .argument('<files...>')
+      .action(() => {});
+    await program.parseAsync([
+      'node',
+      'knowgraph',
+      'deployments',
+      'import',
+      'secret.csv',
+    ]);
+    expect(name).toBe('deployments import');
+  });
+
+  it('records nothing before opting in', async () => {
+    const fetchFn = vi.fn();
+    await recordUsage('index', 0, '1.2.3', { env, fetch: fetchFn });
+    expect(readUsageState(usageStatePath(env)).commands).toEqual({});
+    expect(fetchFn).not.toHaveBeenCalled();
+  });
+
+  it('refuses to enable without an endpoint to send to', async () => {
+    await run('enable');
+    expect(process.exitCode).toBe(2);
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain(
+      'Usage reports need an endpoint',
+    );
+    expect(readUsageState(usageStatePath(env)).enabled).toBe(false);
+    process.exitCode = undefined;
+  });
+
+  it('counts runs after enable and forgets them on disable', async () => {
+    await run('enable', '--endpoint', 'https://usage.example.com');
+    await recordUsage('index', 5, '1.2.3', {
+      env,
+      fetch: vi.fn(),
+      now: new Date(Date.now() - 1000),
+    });
+    expect(readUsageState(usageStatePath(env)).commands).toEqual({
+      index: { runs: 1, failures: { io: 1 } },
+    });
+
+    await run('disable');
+    const state = readUsageState(usageStatePath(env));
+    expect(state.enabled).toBe(false);
+    expect(state.commands).toEqual({});
+  });
+
+  it('honours DO_NOT_TRACK over the stored choice', async () => {
+    await run('enable', '--endpoint', 'https://usage.example.com');
+    await recordUsage('index', 0, '1.2.3', {
+      env: { ...env, DO_NOT_TRACK: '1' },
+    });
+    expect(readUsageState(usageStatePath(env)).commands).toEqual({});
+  });
+
+  it('sends a due report and clears the counts', async () => {
+    const path = usageStatePath(env);
+    await run('enable', '--endpoint', 'https://usage.example.com');
+    writeUsageState(path, {
+      ...readUsageState(path),
+      since: '2026-01-01T00:00:00.000Z',
+    });
+    const fetchFn = vi.fn(async () => new Response(null, { status: 204 }));
+    await recordUsage('export', 0, '1.2.3', {
+      env: { ...env, KNOWGRAPH_USAGE_ENDPOINT: 'https://other.example.com' },
+      fetch: fetchFn,
+      now: new Date('2026-01-03T00:00:00Z'),
+    });
+
+    const [url, init] = fetchFn.mock.calls[0] as unknown as [
+      string,
+      RequestInit,
+    ];
+    expect(url).toBe('https://other.example.com');
+    const sent = JSON.parse(String(init.body));
+    expect(sent.commands).toEqual([
+      { command: 'export', runs: 1, failures: {} },
+    ]);
+    expect(sent.version).toBe('1.2.3');
+    expect(readUsageState(path).commands).toEqual({});
+  });
+
+  it('keeps counts when the endpoint cannot be reached', async () => {
+    const path = usageStatePath(env);
+    await run('enable', '--endpoint', 'https://usage.example.com');
+    writeUsageState(path, {
+      ...readUsageState(path),
+      since: '2026-01-01T00:00:00.000Z',
+    });
+    await recordUsage('export', 0, '1.2.3', {
+      env,
+      fetch: vi.fn(async () => {
+        throw new Error('offline');
+      }),
+      now: new Date('2026-01-03T00:00:00Z'),
+    });
+    expect(readUsageState(path).commands.export?.runs).toBe(1);
+  });
+
+  it('previews the report that would be sent', async () => {
+    await run('preview');
+    const report = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(report).toMatchObject({ schema: 1, version: '1.2.3', commands: [] });
+    expect(String(consoleErrorSpy.mock.calls[0]?.[0])).toContain(
+      'nothing is sent',
+    );
+  });
+});
@@ -0,0 +1,91 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { Command } from 'commander';
+import type { VacancyReport } from '@know-graph/core';
+import {
+  formatVacancyReport,
+  registerVacanciesCommand,
+} from '../commands/vacancies.js';
+
+const report: VacancyReport = {
+  vacancies: [
+    {
+      owner: 'ada@example.com',
+      reason: 'departed',
+      nodes: [
+        {
+          entityId: 'checkout',
+          name: 'CheckoutService',
+          entityType: 'service',
+          filePath: 'src/checkout.ts',
+          suggested: 'lin',
+          suggestedFrom: 'contributor',
+        },
+        {
+          entityId: 'ledger',
+          name: 'Ledger',
+          entityType: 'service',
+          filePath: 'src/ledger.ts',
+          suggested: null,
+          suggestedFrom: null,
+        },
+      ],
+      inheritors: [
+        {
+          author: 'lin@example.com',
+          login: 'lin',
+          commits: 9,
+          lastModified: '2024-06-01T00:00:00Z',
+        },
+      ],
+    },
+  ],
+  vacantNodes: 2,
+  unverified: ['search-team'],
+};
+
+describe('vacancies command', () => {
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+  let originalExitCode: typeof process.exitCode;
+
+  beforeEach(() => {
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    originalExitCode = process.exitCode;
+    process.exitCode = undefined;
+  });
+
+  afterEach(() => {
+    consoleErrorSpy.mockRestore();
+    process.exitCode = originalExitCode;
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerVacanciesCommand(program);
+    return program.parseAsync(['node', 'knowgraph', 'vacancies', ...args]);
+  }
+
+  it('lists vacant owners with inheritors and suggestions', () => {
+    const output = formatVacancyReport(report);
+    expect(output).toContain('1 owners no longer exist, leaving 2 nodes');
+    expect(output).toContain('(departed, 2 nodes)');
+    expect(output).toContain('inheritors: lin 9 commits');
+    expect(output).toContain('→ lin (contributor)');
+    expect(output).toContain('→ no one to suggest');
+    expect(output).toContain('1 owners could not be checked');
+  });
+
+  it('says so when every owner exists', () => {
+    const empty = { vacancies: [], vacantNodes: 0, unverified: [] };
+    expect(formatVacancyReport(empty)).toContain('Every owner is in');
+  });
+
+  it('rejects an unknown format as a usage error', async () => {
+    await run('--format', 'xml');
+    expect(process.exitCode).toBe(2);
+  });
+
+  it('fails with an I/O error when the database is missing', async () => {
+    await run('--db', '/nonexistent/knowgraph.db');
+    expect(process.exitCode).toBe(5);
+  });
+});
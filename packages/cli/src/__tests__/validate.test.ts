@@ -74,7 +74,22 @@ describe('validate command', () => {
     expect(parsed).toHaveProperty('isValid');
   });
 
-  it('sets exit code 1 for invalid path', () => {
+  it('outputs a JUnit report when --format junit is used', () => {
+    const program = createProgram();
+    program.parse([
+      'node',
+      'knowgraph',
+      'validate',
+      FIXTURES_DIR,
+      '--format',
+      'junit',
+    ]);
+    const report = logSpy.mock.calls.map((c) => c[0]).join('\n');
+    expect(report).toContain('<?xml version="1.0" encoding="UTF-8"?>');
+    expect(report).toContain('<testsuite name="knowgraph validate"');
+  });
+
+  it('sets the io exit code for invalid path', () => {
     const program = createProgram();
     program.parse([
       'node',
@@ -82,7 +97,7 @@ describe('validate command', () => {
       'validate',
       '/nonexistent/path/foo/bar',
     ]);
-    expect(process.exitCode).toBe(1);
+    expect(process.exitCode).toBe(5);
   });
 
   it('accepts --rule option to filter rules', () => {
@@ -0,0 +1,145 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Command } from 'commander';
+import { externalNode } from '@know-graph/core';
+import type { DependencyGraph } from '@know-graph/core';
+import { registerVersionsCommand } from '../commands/versions.js';
+import { indexInto } from '../utils/indexing.js';
+
+function service(name: string, lines: readonly string[]): string {
+  return [
+    `class ${name}:`,
+    '    """',
+    '    @knowgraph',
+    '    type: service',
+    `    description: The ${name} service`,
+    '    owner: platform-team',
+    '    status: stable',
+    ...lines.map((line) => `    ${line}`),
+    '    """',
+    '',
+  ].join('\n');
+}
+
+describe('versions command', () => {
+  let dir: string;
+  let dbPath: string;
+  let consoleLogSpy: ReturnType<typeof vi.spyOn>;
+  let consoleErrorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-versions-'));
+    writeFileSync(
+      join(dir, 'tokens.py'),
+      service('TokenService', ['version: "2.4.0"']),
+    );
+    writeFileSync(
+      join(dir, 'login.py'),
+      service('LoginService', [
+        'dependencies:',
+        '  services: [TokenService]',
+        '  versions:',
+        '    TokenService: ">=2.1 <3"',
+      ]),
+    );
+    dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    mkdirSync(join(dir, '.knowgraph'));
+    indexInto(dir, dbPath, { incremental: false });
+    consoleLogSpy = vi.spyOn(console, 'log').mockImplementation(() => {});
+    consoleErrorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    consoleLogSpy.mockRestore();
+    consoleErrorSpy.mockRestore();
+    process.exitCode = undefined;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function run(...args: string[]): Promise<Command> {
+    const program = new Command();
+    registerVersionsCommand(program);
+    return program.parseAsync([
+      'node',
+      'knowgraph',
+      'versions',
+      ...args,
+      '--db',
+      dbPath,
+    ]);
+  }
+
+  it('checks requirements against versions declared in the repository', async () => {
+    await run();
+    const output = String(consoleLogSpy.mock.calls[0]?.[0]);
+    expect(output).toContain('requirement(s) that can be checked are met');
+    expect(output).toContain('LoginService requires TokenService >=2.1 <3');
+    expect(output).toContain('has 2.4.0');
+    expect(process.exitCode).toBeUndefined();
+  });
+
+  it('fails when a stitched provider does not ship the required version', async () => {
+    const shop: DependencyGraph = {
+      nodes: [
+        {
+          id: 'checkout',
+          name: 'checkout',
+          entityType: 'service',
+          external: false,
+          filePath: 'src/checkout.ts',
+          owner: null,
+          domain: null,
+          workspace: null,
+        },
+        externalNode('service', 'token-service'),
+      ],
+      edges: [
+        {
+          from: 'checkout',
+          to: 'external:service:token-service',
+          kind: 'service',
+          provenance: 'declared',
+          confidence: 1,
+          requires: '^3',
+        },
+      ],
+    };
+    const identity: DependencyGraph = {
+      nodes: [
+        {
+          id: 'auth',
+          name: 'auth',
+          entityType: 'service',
+          external: false,
+          filePath: 'src/auth.ts',
+          owner: null,
+          domain: null,
+          workspace: null,
+          aliases: ['token-service'],
+          version: '2.9.1',
+        },
+      ],
+      edges: [],
+    };
+    writeFileSync(join(dir, 'shop.json'), JSON.stringify(shop));
+    writeFileSync(join(dir, 'identity.json'), JSON.stringify(identity));
+
+    await run(
+      join(dir, 'shop.json'),
+      join(dir, 'identity.json'),
+      '--format',
+      'json',
+    );
+    const report = JSON.parse(String(consoleLogSpy.mock.calls[0]?.[0]));
+    expect(report.incompatible).toBe(1);
+    expect(report.requirements[0]).toMatchObject({
+      requires: '^3',
+      version: '2.9.1',
+      status: 'incompatible',
+    });
+    expect(report.requirements[0].to.id).toBe('auth');
+    expect(process.exitCode).toBe(1);
+  });
+});
@@ -0,0 +1,129 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager } from '@know-graph/core';
+import { readWarehouseConfig } from '../utils/manifest.js';
+import {
+  createWarehouseSinks,
+  publishToWarehouses,
+  warehouseRepository,
+} from '../utils/warehouse.js';
+
+const MANIFEST = [
+  'version: "1.0"',
+  'warehouse:',
+  '  sinks:',
+  '    - type: bigquery',
+  '      project: acme',
+  '      dataset: eng',
+  '    - type: snowflake',
+  '      account: acme-eng',
+  '      database: ENG',
+  '      schema: KNOWGRAPH',
+  '      token_env: UNSET_SNOWFLAKE_TOKEN',
+].join('\n');
+
+describe('warehouse sinks', () => {
+  let dir: string;
+  let configPath: string;
+  let errorSpy: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-warehouse-'));
+    configPath = join(dir, '.knowgraph.yml');
+    errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    errorSpy.mockRestore();
+    vi.unstubAllGlobals();
+    vi.unstubAllEnvs();
+  });
+
+  it('skips sinks whose token variable is unset', () => {
+    writeFileSync(configPath, MANIFEST);
+    const config = readWarehouseConfig(configPath);
+    const sinks = createWarehouseSinks(configPath, config, {
+      GOOGLE_OAUTH_ACCESS_TOKEN: 'token',
+    });
+    expect(sinks.map((sink) => sink.name)).toEqual(['bigquery']);
+    expect(errorSpy).toHaveBeenCalledWith(
+      expect.stringContaining('UNSET_SNOWFLAKE_TOKEN is not set'),
+    );
+  });
+
+  it('authenticates with the delivery credentials for its API', async () => {
+    writeFileSync(
+      configPath,
+      [
+        MANIFEST,
+        'delivery:',
+        '  attempts: 1',
+        '  auth:',
+        '    - type: token',
+        '      url: https://acme-eng.snowflakecomputing.com/',
+        '      token_env: SNOWFLAKE_CI_TOKEN',
+      ].join('\n'),
+    );
+    const fetchMock = vi.fn(async () => ({
+      ok: true,
+      status: 200,
+      statusText: 'OK',
+      text: async () => '{}',
+    }));
+    vi.stubGlobal('fetch', fetchMock);
+    const sinks = createWarehouseSinks(
+      configPath,
+      readWarehouseConfig(configPath),
+      { SNOWFLAKE_CI_TOKEN: 'ci-token' },
+    );
+    expect(sinks.map((sink) => sink.name)).toEqual(['snowflake']);
+
+    await sinks[0].test();
+    const [, init] = fetchMock.mock.calls[0] as unknown as [
+      string,
+      { headers: Record<string, string> },
+    ];
+    expect(init.headers.Authorization).toBe('Bearer ci-token');
+  });
+
+  it('stamps rows with the repository, namespace, or directory', () => {
+    writeFileSync(configPath, MANIFEST);
+    const config = readWarehouseConfig(configPath);
+    expect(warehouseRepository(configPath, config)).toMatch(
+      /^knowgraph-warehouse-/,
+    );
+    writeFileSync(configPath, `${MANIFEST}\nnamespace: acme/shop\n`);
+    expect(warehouseRepository(configPath, config)).toBe('acme/shop');
+    expect(
+      warehouseRepository(configPath, { ...config, repository: 'shop' }),
+    ).toBe('shop');
+  });
+
+  it('warns instead of failing when a sink cannot be reached', async () => {
+    writeFileSync(configPath, MANIFEST);
+    mkdirSync(join(dir, '.knowgraph'));
+    const dbPath = join(dir, '.knowgraph', 'knowgraph.db');
+    const dbManager = createDatabaseManager(dbPath);
+    dbManager.initialize();
+    dbManager.close();
+    vi.stubEnv('GOOGLE_OAUTH_ACCESS_TOKEN', 'token');
+    vi.stubGlobal(
+      'fetch',
+      vi.fn(async () => ({
+        ok: false,
+        status: 403,
+        statusText: 'Forbidden',
+        text: async () => '{"error":{"message":"Access Denied"}}',
+      })),
+    );
+
+    await publishToWarehouses(configPath, dbPath);
+    expect(errorSpy).toHaveBeenCalledWith(
+      expect.stringContaining('Could not publish to bigquery'),
+    );
+    expect(process.exitCode).toBeUndefined();
+  });
+});
@@ -0,0 +1,69 @@
+import { describe, it, expect } from 'vitest';
+import { Command } from 'commander';
+import type { WorkspaceReport } from '@know-graph/core';
+import {
+  formatBazelReconciliation,
+  formatWorkspaceReport,
+  registerWorkspacesCommand,
+} from '../commands/workspaces.js';
+
+const report: WorkspaceReport = {
+  members: [
+    {
+      name: '@acme/web',
+      kind: 'pnpm',
+      path: 'packages/web',
+      entityCount: 3,
+      owners: ['web-team'],
+      dependsOn: ['example.com/api'],
+    },
+    {
+      name: 'example.com/api',
+      kind: 'go',
+      path: 'services/api',
+      entityCount: 0,
+      owners: [],
+      dependsOn: [],
+    },
+  ],
+  unassignedEntities: 2,
+};
+
+describe('workspaces command', () => {
+  it('registers the workspaces command', () => {
+    const program = new Command();
+    registerWorkspacesCommand(program);
+    const cmd = program.commands.find((c) => c.name() === 'workspaces');
+    expect(cmd).toBeDefined();
+    expect(cmd?.options.map((o) => o.long)).toContain('--db');
+    expect(cmd?.options.map((o) => o.long)).toContain('--bazel-query');
+  });
+
+  it('lists members with kind, path, owners, and dependencies', () => {
+    const output = formatWorkspaceReport(report);
+    expect(output).toContain('Workspace members: 2');
+    expect(output).toContain('[pnpm] packages/web');
+    expect(output).toContain('3 entities');
+    expect(output).toContain('owners: web-team');
+    expect(output).toContain('depends on: example.com/api');
+    expect(output).toContain('2 entities are outside every workspace member');
+  });
+
+  it('explains when no members are found', () => {
+    expect(
+      formatWorkspaceReport({ members: [], unassignedEntities: 0 }),
+    ).toContain('No workspace members found');
+  });
+
+  it('lists undeclared and declared-only Bazel edges', () => {
+    const output = formatBazelReconciliation({
+      confirmed: [{ from: '//services/api', to: '//libs/db' }],
+      undeclared: [{ from: '//services/billing', to: '//libs/db' }],
+      declaredOnly: [],
+    });
+    expect(output).toContain('1 confirmed');
+    expect(output).toContain('1 undeclared');
+    expect(output).toContain('//services/billing -> //libs/db');
+    expect(output).not.toContain('declared only');
+  });
+});
@@ -0,0 +1,236 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists unannotated exported symbols and, with --interactive, asks about each one and writes its @knowgraph block
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, annotate, wizard, interactive, annotations]
+ * context:
+ *   business_goal: Let a team annotate an existing codebase by answering questions instead of writing YAML
+ *   domain: cli
+ */
+import { existsSync, readFileSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import { createInterface } from 'node:readline';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  annotateInteractively,
+  findUnannotatedSymbols,
+  listDraftFiles,
+} from '@know-graph/core';
+import type {
+  UnannotatedSymbol,
+  WizardPrompter,
+  WizardResult,
+} from '@know-graph/core';
+import { draftedChanges, recordAudit } from '../utils/audit.js';
+import { loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+import { formatPlan } from '../utils/plan.js';
+
+interface AnnotateCommandOptions {
+  readonly path: string;
+  readonly interactive?: boolean;
+  readonly owner?: string;
+  readonly limit?: string;
+  readonly dryRun?: boolean;
+  readonly db: string;
+  readonly config: string;
+  readonly format: string;
+}
+
+export function formatUnannotated(
+  symbols: readonly UnannotatedSymbol[],
+): string {
+  if (symbols.length === 0) {
+    return chalk.green('No unannotated symbols found.');
+  }
+  const lines = symbols.map(
+    (symbol) =>
+      `  ${symbol.filePath}:${symbol.line} ${chalk.cyan(symbol.name)} ${chalk.dim(symbol.entityType)}`,
+  );
+  return [
+    `${symbols.length} unannotated symbol(s)`,
+    ...lines,
+    '',
+    chalk.dim(
+      "Run 'knowgraph annotate --interactive' to annotate them one by one.",
+    ),
+  ].join('\n');
+}
+
+export function formatWizardResult(result: WizardResult): string {
+  const lines = result.annotated.map(
+    (annotation) =>
+      `  ${chalk.green('✓')} ${annotation.symbol.filePath}:${annotation.symbol.line} ${annotation.symbol.name}`,
+  );
+  const left = result.found - result.annotated.length;
+  lines.push(
+    '',
+    `Annotated ${result.annotated.length} of ${result.found} unannotated symbol(s); ${left} left, ${result.skipped} skipped`,
+  );
+  return lines.join('\n');
+}
+
+/**
+ * A prompter reading answers line by line from stdin, so the wizard also
+ * takes answers piped in. Questions go to stderr when stdout carries JSON.
+ */
+function createTerminalPrompter(json: boolean): {
+  readonly prompter: WizardPrompter;
+  close(): void;
+} {
+  const output = json ? process.stderr : process.stdout;
+  const rl = createInterface({ input: process.stdin, output });
+  // Piped input arrives in chunks of several lines; keep the ones that
+  // come before their question
+  const lines: string[] = [];
+  let closed = false;
+  let waiting: ((answer: string | null) => void) | undefined;
+  rl.on('line', (line) => {
+    const done = waiting;
+    waiting = undefined;
+    if (done) done(line);
+    else lines.push(line);
+  });
+  rl.on('close', () => {
+    closed = true;
+    waiting?.(null);
+  });
+  return {
+    prompter: {
+      say: (text) => output.write(`${text}\n`),
+      ask: (question, suggested) =>
+        new Promise((done) => {
+          const hint = suggested ? chalk.dim(` [${suggested}]`) : '';
+          const prompt = `${chalk.bold(question)}${hint} `;
+          if (closed) {
+            output.write(prompt);
+          } else {
+            rl.setPrompt(prompt);
+            rl.prompt();
+          }
+          const line = lines.shift();
+          if (line !== undefined) done(line);
+          else if (closed) done(null);
+          else waiting = done;
+        }),
+    },
+    close: () => rl.close(),
+  };
+}
+
+/** Names of the indexed services, when there is an index to read. */
+function indexedServices(
+  dbPath: string,
+  configPath: string,
+): readonly string[] {
+  if (!existsSync(dbPath)) return [];
+  const entities = loadEntities(dbPath, configPath) ?? [];
+  return entities
+    .filter((entity) => entity.entityType === 'service')
+    .map((entity) => entity.name);
+}
+
+function listUnannotated(path: string): readonly UnannotatedSymbol[] {
+  const { rootDir, files } = listDraftFiles(path);
+  return files.flatMap((filePath) =>
+    findUnannotatedSymbols(
+      readFileSync(join(rootDir, filePath), 'utf-8'),
+      filePath,
+    ),
+  );
+}
+
+async function runAnnotate(options: AnnotateCommandOptions): Promise<void> {
+  if (options.format !== 'text' && options.format !== 'json') {
+    reportError(
+      `Unknown format "${options.format}". Use text or json.`,
+      'usage',
+    );
+    return;
+  }
+  const limit = options.limit === undefined ? undefined : Number(options.limit);
+  if (limit !== undefined && (!Number.isInteger(limit) || limit < 1)) {
+    reportError('--limit must be a positive integer', 'usage');
+    return;
+  }
+  const json = options.format === 'json';
+
+  if (!options.interactive) {
+    try {
+      const symbols = listUnannotated(resolve(options.path));
+      console.log(
+        json ? formatJson(symbols, true) : formatUnannotated(symbols),
+      );
+    } catch (err) {
+      reportError(err);
+    }
+    return;
+  }
+
+  const absPath = resolve(options.path);
+  const terminal = createTerminalPrompter(json);
+  let result: WizardResult;
+  try {
+    result = await annotateInteractively(absPath, {
+      prompter: terminal.prompter,
+      owner: options.owner,
+      services: indexedServices(
+        resolve(options.db),
+        resolve(options.config),
+      ),
+      limit,
+      write: !options.dryRun,
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  } finally {
+    terminal.close();
+  }
+
+  const changes = draftedChanges(absPath, result.files);
+  if (options.dryRun) {
+    const plan = {
+      command: 'annotate --interactive',
+      changes,
+      totals: [`${result.annotated.length} annotation(s) written`],
+    };
+    console.log(json ? formatJson(plan, true) : formatPlan(plan));
+    return;
+  }
+  console.log(json ? formatJson(result, true) : formatWizardResult(result));
+  recordAudit(resolve(options.config), 'annotate', changes);
+}
+
+export function registerAnnotateCommand(program: Command): void {
+  program
+    .command('annotate')
+    .description(
+      'List unannotated exported symbols, or annotate them one by one with --interactive',
+    )
+    .option('--path <path>', 'Directory or file to annotate', '.')
+    .option(
+      '--interactive',
+      'Ask about each symbol and write its block as you answer',
+    )
+    .option('--owner <team>', 'Owner to suggest for the first symbol')
+    .option('--limit <n>', 'Ask about at most this many symbols')
+    .option(
+      '--dry-run',
+      'Ask as usual, then show how the files would change without writing',
+    )
+    .option(
+      '--db <path>',
+      'Database whose services are suggested as dependencies',
+      '.knowgraph/knowgraph.db',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action(async (options: AnnotateCommandOptions) => {
+      await runAnnotate(options);
+    });
+}
@@ -0,0 +1,117 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that reviews the scan history for dependency, ownership, status, and coverage anomalies
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, history, anomalies]
+ * context:
+ *   business_goal: Catch sudden, unreviewed changes to the graph before they become the norm
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { detectHistoryAnomalies, readScanHistory } from '@know-graph/core';
+import type { AnomalyReport, ScanMetrics } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import {
+  formatAnomalyReport,
+  historyPath,
+  toAnomalyThresholds,
+} from '../utils/history.js';
+import { readHistoryConfig } from '../utils/manifest.js';
+
+interface AnomaliesCommandOptions {
+  readonly config: string;
+  readonly format: string;
+  readonly check?: boolean;
+}
+
+function change(current: number, previous: number, unit = ''): string {
+  const delta = Math.round((current - previous) * 10) / 10;
+  if (delta === 0) return '';
+  return chalk.dim(` (${delta > 0 ? '+' : ''}${delta}${unit})`);
+}
+
+export function formatAnomalies(
+  history: readonly ScanMetrics[],
+  reports: readonly AnomalyReport[],
+): string {
+  const latest = history.at(-1);
+  if (!latest) {
+    return "No scans recorded yet; run 'knowgraph index' to start the history.";
+  }
+  const previous = history.at(-2) ?? latest;
+  const lines = [
+    `${chalk.bold('Scan history:')} ${history.length} scans, latest ${latest.timestamp}`,
+    `  Entities: ${latest.entities}${change(latest.entities, previous.entities)}`,
+    `  Edges:    ${latest.edges}${change(latest.edges, previous.edges)}`,
+    `  Coverage: ${latest.coverage}%${change(latest.coverage, previous.coverage, ' pts')}`,
+  ];
+  const flagged = reports.filter((report) => report.anomalies.length > 0);
+  if (flagged.length === 0) {
+    lines.push('', chalk.green('No anomalies between scans.'));
+  }
+  for (const report of flagged) {
+    lines.push(
+      '',
+      chalk.bold(report.current.timestamp),
+      formatAnomalyReport(report),
+    );
+  }
+  return lines.join('\n');
+}
+
+function runAnomalies(options: AnomaliesCommandOptions): void {
+  const configPath = resolve(options.config);
+  let history: readonly ScanMetrics[];
+  let reports: readonly AnomalyReport[];
+  try {
+    const config = readHistoryConfig(configPath);
+    history = readScanHistory(historyPath(configPath));
+    reports = detectHistoryAnomalies(history, toAnomalyThresholds(config));
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(
+      formatJson(
+        {
+          scans: history.length,
+          reports: reports.map((report) => ({
+            timestamp: report.current.timestamp,
+            anomalies: report.anomalies,
+          })),
+        },
+        true,
+      ),
+    );
+  } else {
+    console.log(formatAnomalies(history, reports));
+  }
+
+  const latest = reports.at(-1);
+  if (options.check && latest && latest.anomalies.length > 0) {
+    reportCheckFailure(
+      `${latest.anomalies.length} anomalies in the latest scan`,
+      'policy',
+      { kinds: latest.anomalies.map((anomaly) => anomaly.kind) },
+    );
+  }
+}
+
+export function registerAnomaliesCommand(program: Command): void {
+  program
+    .command('anomalies')
+    .description('Review the scan history for sudden changes to the graph')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--check', 'Exit with code 1 if the latest scan has anomalies')
+    .action((options: AnomaliesCommandOptions) => {
+      runAnomalies(options);
+    });
+}
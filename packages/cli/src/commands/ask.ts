@@ -0,0 +1,137 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that answers a question about the codebase from the graph, offline or with an LLM
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, ask, rag, llm]
+ * context:
+ *   business_goal: Let engineers ask questions about the codebase in plain language
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  askGraph,
+  createOpenAiCompatibleEmbedder,
+  createOpenAiCompatibleModel,
+} from '@know-graph/core';
+import type { AskMatch, AskOptions, AskResult } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface AskCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly limit: string;
+  readonly llm?: string;
+  readonly model: string;
+  readonly embeddingModel?: string;
+  readonly apiKeyEnv: string;
+}
+
+function source(match: AskMatch, index: number): string {
+  const where = match.entity
+    ? `${match.entity.filePath}:${match.entity.line}`
+    : 'external';
+  const details = `(${match.kind}) ${where}, score ${match.score.toFixed(2)}`;
+  return `  ${index + 1}. ${match.node.name} ${chalk.dim(details)}`;
+}
+
+export function formatAskResult(result: AskResult): string {
+  const lines = [result.answer];
+  if (result.matches.length > 0) {
+    lines.push('', chalk.bold('Sources'), ...result.matches.map(source));
+  }
+  if (result.mode === 'offline') {
+    lines.push(
+      '',
+      chalk.dim(
+        'Answered offline; pass --llm <url> to compose it with a model.',
+      ),
+    );
+  }
+  return lines.join('\n');
+}
+
+function askOptions(options: AskCommandOptions): AskOptions | undefined {
+  const limit = Number(options.limit);
+  if (!Number.isInteger(limit) || limit < 1) {
+    reportError('--limit must be a positive integer', 'usage');
+    return undefined;
+  }
+  if (!options.llm) {
+    if (options.embeddingModel) {
+      reportError('--embedding-model needs --llm <url>', 'usage');
+      return undefined;
+    }
+    return { limit };
+  }
+
+  const api = { baseUrl: options.llm, apiKey: process.env[options.apiKeyEnv] };
+  return {
+    limit,
+    model: createOpenAiCompatibleModel({ ...api, model: options.model }),
+    embedder: options.embeddingModel
+      ? createOpenAiCompatibleEmbedder({
+          ...api,
+          model: options.embeddingModel,
+        })
+      : undefined,
+  };
+}
+
+async function runAsk(
+  question: string,
+  options: AskCommandOptions,
+): Promise<void> {
+  const resolved = askOptions(options);
+  if (!resolved) return;
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath);
+  if (!entities) return;
+
+  try {
+    const result = await askGraph(
+      entities,
+      buildGraph(dbPath, entities),
+      question,
+      resolved,
+    );
+    if (options.format === 'json') {
+      console.log(formatJson(result, true));
+    } else {
+      console.log(formatAskResult(result));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerAskCommand(program: Command): void {
+  program
+    .command('ask <question>')
+    .description('Answer a question about the codebase from the graph')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--limit <n>', 'Number of entities to retrieve', '5')
+    .option(
+      '--llm <url>',
+      'OpenAI-compatible API base URL used to compose the answer',
+    )
+    .option('--model <name>', 'Chat model for --llm', 'gpt-4o-mini')
+    .option(
+      '--embedding-model <name>',
+      'Embedding model for --llm, to rank by meaning as well as keywords',
+    )
+    .option(
+      '--api-key-env <name>',
+      'Environment variable holding the API key',
+      'OPENAI_API_KEY',
+    )
+    .action(async (question: string, options: AskCommandOptions) => {
+      await runAsk(question, options);
+    });
+}
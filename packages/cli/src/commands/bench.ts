@@ -0,0 +1,183 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that benchmarks scans and graph builds on synthetic repositories and fails on regressions against a baseline
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, bench, performance, regression]
+ * context:
+ *   business_goal: Catch scanner and graph builder slowdowns before they reach a release
+ *   domain: cli
+ */
+import { readFileSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  compareBenchReports,
+  createKnowgraphError,
+  formatBenchSize,
+  parseBenchSize,
+  runBenchmarks,
+} from '@know-graph/core';
+import type { BenchComparison, BenchReport } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+
+interface BenchCommandOptions {
+  readonly sizes: string;
+  readonly runs: string;
+  readonly seed: string;
+  readonly workDir: string;
+  readonly output?: string;
+  readonly baseline?: string;
+  readonly maxRegression: string;
+  readonly format: string;
+}
+
+function pad(text: string, width: number): string {
+  return text.padEnd(width);
+}
+
+function formatChange(comparison: BenchComparison): string {
+  const sign = comparison.changePercent > 0 ? '+' : '';
+  const text = `${sign}${comparison.changePercent}%`;
+  if (comparison.regressed) return chalk.red(`${text} regressed`);
+  return comparison.changePercent < 0 ? chalk.green(text) : chalk.dim(text);
+}
+
+export function formatBenchReport(
+  report: BenchReport,
+  comparisons: readonly BenchComparison[] = [],
+): string {
+  const byName = new Map(comparisons.map((c) => [c.name, c]));
+  const lines = [
+    chalk.bold(`knowgraph ${report.version} benchmarks`) +
+      chalk.dim(
+        ` (node ${report.node}, ${report.os}/${report.arch}, ${report.cpus} CPUs)`,
+      ),
+    '',
+  ];
+  for (const result of report.results) {
+    const comparison = byName.get(result.name);
+    const baseline = comparison
+      ? `  was ${comparison.baselineMs} ms ${formatChange(comparison)}`
+      : '';
+    lines.push(
+      `  ${pad(result.name, 12)} ${pad(`${result.medianMs} ms`, 12)}` +
+        chalk.dim(`${result.minMs}-${result.maxMs} ms, ${result.runs} run(s)`) +
+        baseline,
+    );
+  }
+  return lines.join('\n');
+}
+
+function readBaseline(path: string): BenchReport {
+  const report = JSON.parse(readFileSync(path, 'utf-8')) as BenchReport;
+  if (report.schema !== 1 || !Array.isArray(report.results)) {
+    throw createKnowgraphError(
+      'schema',
+      `${path} is not a knowgraph bench report`,
+    );
+  }
+  return report;
+}
+
+function runBench(options: BenchCommandOptions, version: string): void {
+  const runs = Number(options.runs);
+  if (!Number.isInteger(runs) || runs < 1) {
+    reportError('--runs must be a positive integer', 'usage');
+    return;
+  }
+  const seed = Number(options.seed);
+  if (!Number.isInteger(seed)) {
+    reportError('--seed must be an integer', 'usage');
+    return;
+  }
+  const maxRegressionPercent = Number(options.maxRegression);
+  if (!Number.isFinite(maxRegressionPercent) || maxRegressionPercent < 0) {
+    reportError('--max-regression must be a non-negative number', 'usage');
+    return;
+  }
+
+  let sizes: number[];
+  let baseline: BenchReport | undefined;
+  try {
+    sizes = options.sizes.split(',').map(parseBenchSize);
+    baseline = options.baseline
+      ? readBaseline(resolve(options.baseline))
+      : undefined;
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  let report: BenchReport;
+  try {
+    report = runBenchmarks({
+      sizes,
+      runs,
+      seed,
+      version,
+      workDir: resolve(options.workDir),
+      onSize: (files) =>
+        console.error(chalk.dim(`Benchmarking ${formatBenchSize(files)}...`)),
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  if (options.output) {
+    writeFileSync(resolve(options.output), formatJson(report, true) + '\n');
+  }
+
+  const comparisons = baseline
+    ? compareBenchReports(baseline, report, { maxRegressionPercent })
+    : [];
+  if (options.format === 'json') {
+    console.log(formatJson({ report, comparisons }, true));
+  } else {
+    console.log(formatBenchReport(report, comparisons));
+  }
+
+  const regressed = comparisons.filter((c) => c.regressed);
+  if (regressed.length > 0) {
+    reportCheckFailure(
+      `${regressed.length} benchmark(s) more than ${maxRegressionPercent}% slower than the baseline`,
+      'policy',
+      { regressed: regressed.map((c) => c.name) },
+    );
+  }
+}
+
+export function registerBenchCommand(program: Command): void {
+  program
+    .command('bench')
+    .description(
+      'Benchmark scans and graph builds on synthetic repositories, optionally against a baseline',
+    )
+    .option(
+      '--sizes <sizes>',
+      'Repository sizes in files, comma-separated',
+      '1k,10k',
+    )
+    .option('--runs <n>', 'Timed runs per case; the median is reported', '3')
+    .option('--seed <n>', 'Seed for the synthetic repositories', '1')
+    .option(
+      '--work-dir <dir>',
+      'Where synthetic repositories are written and reused',
+      join(tmpdir(), 'knowgraph-bench'),
+    )
+    .option('--output <file>', 'Also write the JSON report to this file')
+    .option('--baseline <file>', 'JSON report to compare against')
+    .option(
+      '--max-regression <percent>',
+      'Fail when a median is this much slower than the baseline',
+      '10',
+    )
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: BenchCommandOptions) => {
+      runBench(options, program.version() ?? 'unknown');
+    });
+}
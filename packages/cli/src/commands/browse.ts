@@ -0,0 +1,224 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that opens an interactive terminal browser over the indexed graph
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, browse, tui, editor]
+ * context:
+ *   business_goal: Let developers explore the code graph from the terminal without leaving their shell
+ *   domain: cli
+ */
+import { spawnSync } from 'node:child_process';
+import { basename, resolve } from 'node:path';
+import { emitKeypressEvents } from 'node:readline';
+import type { Key } from 'node:readline';
+import type { Command } from 'commander';
+import { resolveView } from '@know-graph/core';
+import type { ViewProfile } from '@know-graph/core';
+import {
+  createBrowserState,
+  renderBrowser,
+  updateBrowser,
+} from '../utils/browser.js';
+import type { BrowserKey, BrowserState, Viewport } from '../utils/browser.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+import { readViews } from '../utils/manifest.js';
+
+interface BrowseCommandOptions {
+  readonly db: string;
+  readonly root: string;
+  readonly editor?: string;
+  readonly view?: string;
+  readonly config: string;
+}
+
+const ENTER_SCREEN = '\x1b[?1049h\x1b[?25l';
+const LEAVE_SCREEN = '\x1b[?25h\x1b[?1049l';
+const CLEAR = '\x1b[H\x1b[2J';
+
+// Editors that take `file:line` rather than `+line file`
+const GOTO_EDITORS = new Set(['code', 'code-insiders', 'codium', 'cursor']);
+const COLON_EDITORS = new Set(['subl', 'zed', 'hx']);
+
+/**
+ * The command line that opens `filePath` at `line` in `editor`, which may
+ * carry its own arguments (`code --wait`). Editors that are not known to
+ * take `file:line` get the `+line file` form vi, Emacs, and nano share.
+ */
+export function editorCommand(
+  editor: string,
+  filePath: string,
+  line: number,
+): readonly string[] {
+  const [command = 'vi', ...args] = editor.split(/\s+/).filter(Boolean);
+  const name = basename(command).replace(/\.(exe|cmd)$/, '');
+  if (GOTO_EDITORS.has(name)) {
+    return [command, ...args, '--goto', `${filePath}:${line}`];
+  }
+  if (COLON_EDITORS.has(name)) return [command, ...args, `${filePath}:${line}`];
+  return [command, ...args, `+${line}`, filePath];
+}
+
+/** The browser key for a keypress, or undefined for keys it ignores. */
+export function toBrowserKey(
+  text: string | undefined,
+  key: Key | undefined,
+): BrowserKey | undefined {
+  if (key?.ctrl) {
+    if (key.name === 'c' || key.name === 'd') return 'quit';
+    if (key.name === 'o') return 'open';
+    if (key.name === 'p') return 'up';
+    if (key.name === 'n') return 'down';
+    return undefined;
+  }
+  switch (key?.name) {
+    case 'up':
+      return 'up';
+    case 'down':
+      return 'down';
+    case 'pageup':
+      return 'pageUp';
+    case 'pagedown':
+      return 'pageDown';
+    case 'return':
+    case 'enter':
+      return 'enter';
+    case 'escape':
+      return 'back';
+    case 'backspace':
+      return 'backspace';
+  }
+  if (key?.meta || !text || !/^[^\x00-\x1f\x7f]+$/.test(text)) {
+    return undefined;
+  }
+  return { text };
+}
+
+function viewport(): Viewport {
+  return {
+    rows: process.stdout.rows ?? 24,
+    columns: process.stdout.columns ?? 80,
+  };
+}
+
+/** Run the editor on the main screen; returns an error to show, if any. */
+function openInEditor(editor: string, filePath: string, line: number): string {
+  const [command, ...args] = editorCommand(editor, filePath, line);
+  process.stdout.write(LEAVE_SCREEN);
+  process.stdin.setRawMode(false);
+  const run = spawnSync(command, args, { stdio: 'inherit' });
+  process.stdin.setRawMode(true);
+  process.stdout.write(ENTER_SCREEN);
+  return run.error ? `Could not start ${command}: ${run.error.message}` : '';
+}
+
+function browse(
+  initial: BrowserState,
+  rootDir: string,
+  editor: string,
+): Promise<void> {
+  let state = initial;
+  const draw = (): void => {
+    process.stdout.write(`${CLEAR}${renderBrowser(state, viewport())}`);
+  };
+
+  return new Promise<void>((done) => {
+    const onKey = (text: string | undefined, key: Key | undefined): void => {
+      const browserKey = toBrowserKey(text, key);
+      if (!browserKey) return;
+      // Two header rows and the key help leave the rest for the list
+      const page = Math.max(viewport().rows - 3, 1);
+      const update = updateBrowser(
+        { ...state, status: undefined },
+        browserKey,
+        page,
+      );
+      state = update.state;
+      if (update.effect?.type === 'quit') {
+        process.stdin.off('keypress', onKey);
+        process.stdout.off('resize', draw);
+        process.stdin.setRawMode(false);
+        process.stdin.pause();
+        process.stdout.write(LEAVE_SCREEN);
+        done();
+        return;
+      }
+      if (update.effect?.type === 'open') {
+        const { filePath, line } = update.effect;
+        const status = openInEditor(editor, resolve(rootDir, filePath), line);
+        if (status) state = { ...state, status };
+      }
+      draw();
+    };
+
+    emitKeypressEvents(process.stdin);
+    process.stdin.setRawMode(true);
+    process.stdin.on('keypress', onKey);
+    process.stdout.on('resize', draw);
+    process.stdin.resume();
+    process.stdout.write(ENTER_SCREEN);
+    draw();
+  });
+}
+
+async function runBrowse(options: BrowseCommandOptions): Promise<void> {
+  if (!process.stdin.isTTY || !process.stdout.isTTY) {
+    reportError(
+      'knowgraph browse needs an interactive terminal',
+      'usage',
+      "Use 'knowgraph query' in scripts and pipes.",
+    );
+    return;
+  }
+  const configPath = resolve(options.config);
+  let view: ViewProfile | undefined;
+  try {
+    view = options.view
+      ? resolveView(options.view, readViews(configPath))
+      : undefined;
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath, configPath);
+  if (!entities) return;
+
+  const editor =
+    options.editor ?? process.env.VISUAL ?? process.env.EDITOR ?? 'vi';
+  await browse(
+    createBrowserState(
+      entities,
+      buildGraph(dbPath, entities, { references: true, configPath }),
+      view,
+    ),
+    resolve(options.root),
+    editor,
+  );
+}
+
+export function registerBrowseCommand(program: Command): void {
+  program
+    .command('browse')
+    .description('Browse entities and their dependencies in the terminal')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option(
+      '--root <dir>',
+      'Directory the indexed file paths are relative to',
+      '.',
+    )
+    .option(
+      '--editor <command>',
+      'Editor to open entities in (default: $VISUAL, then $EDITOR, then vi)',
+    )
+    .option(
+      '--view <name>',
+      'Browse only the types and fields of a view (e.g. compliance, sre)',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .action(async (options: BrowseCommandOptions) => {
+      await runBrowse(options);
+    });
+}
@@ -0,0 +1,396 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI commands that pack an index, its graph, an overview, and the config into one offline bundle, and load such a bundle into another instance
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, bundle, offline, air-gap]
+ * context:
+ *   business_goal: Let customers on isolated networks run knowgraph on graphs built where the code is
+ *   domain: cli
+ */
+import {
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  BUNDLE_PATHS,
+  bundleFile,
+  checkBundle,
+  createQueryEngine,
+  decodeBundle,
+  diffGraphs,
+  encodeBundle,
+  encodeGraphSnapshot,
+  fileChange,
+  graphChange,
+  isPendingReview,
+  lintManifestText,
+  writeStoreFile,
+} from '@know-graph/core';
+import type {
+  AuditChange,
+  BundleFile,
+  BundleHeader,
+  BundleProblem,
+  DependencyGraph,
+  OfflineBundle,
+  StoredEntity,
+} from '@know-graph/core';
+import { readGraphSnapshot, recordAudit } from '../utils/audit.js';
+import { buildGraph, openDatabase } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import { readEncryptionOptions, readNamespace } from '../utils/manifest.js';
+import { formatPlan } from '../utils/plan.js';
+import { formatExport } from './export.js';
+
+interface BundleExportOptions {
+  readonly db: string;
+  readonly config: string;
+  readonly output: string;
+  readonly format: string;
+}
+
+interface BundleImportOptions {
+  readonly db: string;
+  readonly config: string;
+  readonly outputDir: string;
+  readonly force?: boolean;
+  readonly dryRun?: boolean;
+  readonly format: string;
+}
+
+/** What `bundle import` did, for its report. */
+export interface BundleImportResult {
+  readonly header: BundleHeader;
+  readonly problems: readonly BundleProblem[];
+  /** Paths written, the index first. */
+  readonly written: readonly string[];
+}
+
+function formatSize(bytes: number): string {
+  return bytes < 1024 ? `${bytes} B` : `${(bytes / 1024).toFixed(1)} KB`;
+}
+
+export function formatBundleContents(
+  files: readonly BundleFile[],
+  output: string,
+): string {
+  const size = files.reduce((sum, file) => sum + file.bytes.length, 0);
+  return [
+    ...files.map(
+      (file) =>
+        `  ${file.path} ${chalk.dim(formatSize(file.bytes.length))}`,
+    ),
+    '',
+    `Bundled ${files.length} file(s), ${formatSize(size)}, into ${output}`,
+  ].join('\n');
+}
+
+export function formatBundleImport(result: BundleImportResult): string {
+  const { header } = result;
+  const from = header.namespace ? ` of ${header.namespace}` : '';
+  const lines = [
+    `Imported the bundle${from} made ${header.createdAt}`,
+    ...result.written.map((path) => `  ${chalk.green('✓')} ${path}`),
+  ];
+  for (const problem of result.problems) {
+    lines.push(`  ${chalk.yellow('⚠')} ${problem.message}`);
+  }
+  return lines.join('\n');
+}
+
+function isFormat(format: string): boolean {
+  if (format === 'text' || format === 'json') return true;
+  reportError(`Unknown format "${format}". Use text or json.`, 'usage');
+  return false;
+}
+
+/**
+ * The files of a bundle of the index at `dbPath`: the database itself,
+ * decrypted, its graph as a snapshot, a markdown overview of the approved
+ * entities to read without knowgraph, and the manifest when there is one.
+ */
+function collectBundleFiles(
+  dbPath: string,
+  configPath: string,
+): readonly BundleFile[] {
+  const dbManager = openDatabase(dbPath, configPath, { readOnly: true });
+  let index: Buffer;
+  let entities: readonly StoredEntity[];
+  try {
+    index = dbManager.db.serialize();
+    entities = createQueryEngine(dbManager).getAll();
+  } finally {
+    dbManager.close();
+  }
+  const approved = entities.filter(
+    (entity) => !isPendingReview(entity.metadata),
+  );
+  const files: BundleFile[] = [
+    { path: BUNDLE_PATHS.index, bytes: index },
+    {
+      path: BUNDLE_PATHS.graph,
+      bytes: encodeGraphSnapshot(
+        buildGraph(dbPath, entities, { configPath }),
+      ),
+    },
+    {
+      path: BUNDLE_PATHS.site,
+      bytes: Buffer.from(formatExport(approved, 'markdown'), 'utf-8'),
+    },
+  ];
+  if (existsSync(configPath)) {
+    files.push({ path: BUNDLE_PATHS.config, bytes: readFileSync(configPath) });
+  }
+  return files;
+}
+
+function runBundleExport(options: BundleExportOptions): void {
+  if (!isFormat(options.format)) return;
+  const dbPath = resolve(options.db);
+  const configPath = resolve(options.config);
+  if (!existsSync(dbPath)) {
+    reportError(
+      `Database not found at ${dbPath}`,
+      'io',
+      "Run 'knowgraph index' first to create the database.",
+    );
+    return;
+  }
+  try {
+    const files = collectBundleFiles(dbPath, configPath);
+    const output = resolve(options.output);
+    writeFileSync(
+      output,
+      encodeBundle(files, { namespace: readNamespace(configPath) }),
+    );
+    console.log(
+      options.format === 'json'
+        ? formatJson(
+            {
+              output,
+              files: files.map((f) => ({ path: f.path, size: f.bytes.length })),
+            },
+            true,
+          )
+        : formatBundleContents(files, options.output),
+    );
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+/** Warnings for a bundled manifest this release's schema would reject. */
+function configProblems(bytes: Uint8Array): readonly BundleProblem[] {
+  return lintManifestText(Buffer.from(bytes).toString('utf-8'))
+    .filter((issue) => issue.severity === 'error')
+    .map((issue) => ({
+      severity: 'warning',
+      message: `Bundled config: ${issue.path ? `${issue.path}: ` : ''}${issue.message}`,
+    }));
+}
+
+/** A bundled file `bundle import` writes beside the index. */
+interface BundleOutput {
+  /** As given under `--output-dir`, for reports. */
+  readonly path: string;
+  readonly bytes: Uint8Array;
+  /** Written as a store, encrypted when the manifest configures a key. */
+  readonly store?: boolean;
+}
+
+function bundleOutputs(
+  bundle: OfflineBundle,
+  outputDir: string,
+): BundleOutput[] {
+  const outputs: BundleOutput[] = [];
+  const graph = bundleFile(bundle, BUNDLE_PATHS.graph);
+  if (graph) {
+    outputs.push({
+      path: join(outputDir, 'graph.kgs'),
+      bytes: graph,
+      store: true,
+    });
+  }
+  const site = bundleFile(bundle, BUNDLE_PATHS.site);
+  if (site) outputs.push({ path: join(outputDir, 'CODEBASE.md'), bytes: site });
+  // Kept beside the local manifest rather than over it, to compare
+  const config = bundleFile(bundle, BUNDLE_PATHS.config);
+  if (config) {
+    outputs.push({ path: join(outputDir, '.knowgraph.yml'), bytes: config });
+  }
+  return outputs;
+}
+
+/**
+ * What importing `index` changes: the index at `db` and the graph in it,
+ * node by node, and each output, text files with their diff.
+ */
+function importChanges(
+  db: string,
+  configPath: string,
+  index: Uint8Array,
+  outputs: readonly BundleOutput[],
+): AuditChange[] {
+  const dbPath = resolve(db);
+  const before = readGraphSnapshot(dbPath, {}, configPath);
+  const dir = mkdtempSync(join(tmpdir(), 'knowgraph-bundle-'));
+  let after: DependencyGraph;
+  try {
+    const indexPath = join(dir, 'knowgraph.db');
+    writeFileSync(indexPath, index);
+    after = readGraphSnapshot(indexPath, {}, configPath);
+  } finally {
+    rmSync(dir, { recursive: true, force: true });
+  }
+  const replaced = (path: string): string =>
+    existsSync(resolve(path)) ? 'replaced' : 'created';
+  const changes: AuditChange[] = [
+    { target: db, summary: `${replaced(db)} from the bundle` },
+    graphChange(diffGraphs(before, after)),
+  ];
+  for (const output of outputs) {
+    const path = resolve(output.path);
+    const change = output.store
+      ? { target: output.path, summary: replaced(output.path) }
+      : fileChange(
+          output.path,
+          existsSync(path) ? readFileSync(path, 'utf-8') : undefined,
+          Buffer.from(output.bytes).toString('utf-8'),
+        );
+    if (change) changes.push(change);
+  }
+  return changes;
+}
+
+function runBundleImport(file: string, options: BundleImportOptions): void {
+  if (!isFormat(options.format)) return;
+  const dbPath = resolve(options.db);
+  if (existsSync(dbPath) && !options.force) {
+    reportError(
+      `${options.db} already exists`,
+      'usage',
+      'Pass --force to replace it with the bundled index.',
+    );
+    return;
+  }
+  try {
+    const bundle = decodeBundle(readFileSync(resolve(file)));
+    const problems = [...checkBundle(bundle.header)];
+    const errors = problems.filter((p) => p.severity === 'error');
+    if (errors.length > 0) {
+      reportError(
+        errors.map((p) => p.message).join('; '),
+        'schema',
+        'Upgrade knowgraph on this side of the air gap to import the bundle.',
+      );
+      return;
+    }
+    const index = bundleFile(bundle, BUNDLE_PATHS.index);
+    if (!index) {
+      reportError(`${file} carries no index`, 'schema');
+      return;
+    }
+    const config = bundleFile(bundle, BUNDLE_PATHS.config);
+    if (config) problems.push(...configProblems(config));
+
+    const configPath = resolve(options.config);
+    const outputs = bundleOutputs(bundle, options.outputDir);
+    const changes = importChanges(options.db, configPath, index, outputs);
+    if (options.dryRun) {
+      const plan = {
+        command: 'bundle import',
+        changes,
+        totals: [`${outputs.length + 1} file(s) written`],
+      };
+      console.log(
+        options.format === 'json' ? formatJson(plan, true) : formatPlan(plan),
+      );
+      return;
+    }
+
+    const encryption = readEncryptionOptions(configPath);
+    mkdirSync(dirname(dbPath), { recursive: true });
+    writeStoreFile(dbPath, index, encryption);
+    // The journal of the replaced index would be replayed over the new one
+    rmSync(`${dbPath}-wal`, { force: true });
+    rmSync(`${dbPath}-shm`, { force: true });
+    mkdirSync(resolve(options.outputDir), { recursive: true });
+    for (const output of outputs) {
+      if (output.store) {
+        writeStoreFile(resolve(output.path), output.bytes, encryption);
+      } else {
+        writeFileSync(resolve(output.path), output.bytes);
+      }
+    }
+    const written = [options.db, ...outputs.map((output) => output.path)];
+
+    const result: BundleImportResult = {
+      header: bundle.header,
+      problems,
+      written,
+    };
+    console.log(
+      options.format === 'json'
+        ? formatJson(result, true)
+        : formatBundleImport(result),
+    );
+    recordAudit(configPath, 'bundle import', changes);
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerBundleCommand(program: Command): void {
+  const bundle = program
+    .command('bundle')
+    .description(
+      'Move an index and its graph across an air gap as one offline bundle',
+    );
+
+  bundle
+    .command('export')
+    .description(
+      'Pack the index, its graph snapshot, a markdown overview, and the config into one file',
+    )
+    .option('--db <path>', 'Database to bundle', '.knowgraph/knowgraph.db')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--output <file>', 'Bundle to write', 'knowgraph-bundle.kgb')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: BundleExportOptions) => {
+      runBundleExport(options);
+    });
+
+  bundle
+    .command('import <file>')
+    .description(
+      'Check a bundle against this release and load its index into this instance',
+    )
+    .option('--db <path>', 'Database to write', '.knowgraph/knowgraph.db')
+    .option(
+      '--config <path>',
+      'Path to .knowgraph.yml, for encrypting what is written',
+      '.knowgraph.yml',
+    )
+    .option(
+      '--output-dir <dir>',
+      'Where to write the bundled graph, overview, and config',
+      '.knowgraph/bundle',
+    )
+    .option('--force', 'Replace an existing database')
+    .option('--dry-run', 'Show what the import would change without writing')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((file: string, options: BundleImportOptions) => {
+      runBundleImport(file, options);
+    });
+}
@@ -0,0 +1,141 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that reports critical modules whose recent commits come from one person
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, bus-factor, ownership, git]
+ * context:
+ *   business_goal: Surface revenue-critical code that only one person effectively maintains
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { buildBusFactorReport } from '@know-graph/core';
+import type { BusFactorEntry, BusFactorReport } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+
+interface BusFactorCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly coverage: string;
+  readonly minDependents: string;
+  readonly all?: boolean;
+  readonly check?: boolean;
+}
+
+function entryLines(entry: BusFactorEntry): readonly string[] {
+  const share = `${Math.round(entry.topShare * 100)}%`;
+  const why = [
+    ...(entry.revenueImpact ? [`revenue ${entry.revenueImpact}`] : []),
+    `${entry.dependents} dependents`,
+  ].join(', ');
+  return [
+    `  ${entry.name} ${chalk.dim(`${entry.filePath} (${why})`)}`,
+    `    bus factor ${entry.busFactor}, ${entry.topContributor} made ${share} of ${entry.recentCommits} recent commits`,
+  ];
+}
+
+export function formatBusFactorReport(
+  report: BusFactorReport,
+  all = false,
+): string {
+  if (report.entries.length === 0) {
+    return "No contributor data; enable the git enricher and run 'knowgraph index'.";
+  }
+  const atRisk = report.entries.filter((entry) => entry.atRisk);
+  const rest = report.entries.filter(
+    (entry) => !entry.atRisk && (all || entry.critical),
+  );
+  const lines = [
+    atRisk.length > 0
+      ? chalk.red(
+          `${atRisk.length} critical entities are maintained by one person`,
+        )
+      : chalk.green('No critical entity is maintained by one person.'),
+    ...atRisk.flatMap(entryLines),
+  ];
+  if (rest.length > 0) {
+    lines.push(
+      '',
+      chalk.bold(all ? 'Other entities' : 'Other critical entities'),
+      ...rest.flatMap(entryLines),
+    );
+  }
+  if (report.unknown > 0) {
+    lines.push('', chalk.dim(`${report.unknown} entities have no git data`));
+  }
+  return lines.join('\n');
+}
+
+function runBusFactor(options: BusFactorCommandOptions): void {
+  const coverage = Number(options.coverage);
+  if (!(coverage > 0 && coverage <= 1)) {
+    reportError('--coverage must be a number above 0 and at most 1', 'usage');
+    return;
+  }
+  const minDependents = Number(options.minDependents);
+  if (!Number.isInteger(minDependents) || minDependents < 1) {
+    reportError('--min-dependents must be a positive integer', 'usage');
+    return;
+  }
+
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath);
+  if (!entities) return;
+
+  let report: BusFactorReport;
+  try {
+    report = buildBusFactorReport(entities, buildGraph(dbPath, entities), {
+      coverage,
+      minDependents,
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else {
+    console.log(formatBusFactorReport(report, options.all));
+  }
+
+  if (options.check && report.atRisk > 0) {
+    reportCheckFailure(
+      `${report.atRisk} critical entities are maintained by one person`,
+      'policy',
+      {
+        entities: report.entries
+          .filter((entry) => entry.atRisk)
+          .map((entry) => entry.name),
+      },
+    );
+  }
+}
+
+export function registerBusFactorCommand(program: Command): void {
+  program
+    .command('bus-factor')
+    .description('Report critical modules effectively maintained by one person')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option(
+      '--coverage <share>',
+      'Share of recent commits the bus factor must cover',
+      '0.75',
+    )
+    .option(
+      '--min-dependents <n>',
+      'Dependents that make an entity critical regardless of revenue impact',
+      '5',
+    )
+    .option('--all', 'List every entity with git data, not only critical ones')
+    .option('--check', 'Exit with code 1 if a critical entity is at risk')
+    .action((options: BusFactorCommandOptions) => {
+      runBusFactor(options);
+    });
+}
@@ -0,0 +1,174 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that estimates the coordination cost of a change set from ownership, domain boundaries, and compliance reviews
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, change-cost, ownership, compliance, planning]
+ * context:
+ *   business_goal: Let teams planning large refactors see how many owners, boundaries, and reviews a change will involve
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { estimateChangeCost, resolveChangeSet } from '@know-graph/core';
+import type { ChangeCostReport } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { getStagedFiles } from './hook.js';
+
+interface ChangeCostCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly staged?: boolean;
+  readonly maxScore?: string;
+}
+
+function factorLine(label: string, count: number, weight: number): string {
+  return `  ${label.padEnd(20)} ${count} x ${weight} = ${count * weight}`;
+}
+
+export function formatChangeCostReport(
+  report: ChangeCostReport,
+  unmatched: readonly string[] = [],
+): string {
+  const { weights } = report;
+  const lines = [
+    chalk.bold(`Change cost: ${report.score}`),
+    factorLine('owning teams', report.teams.length, weights.teams),
+    factorLine(
+      'cross-domain edges',
+      report.crossDomainEdges.length,
+      weights.crossDomainEdges,
+    ),
+    factorLine(
+      'compliance reviews',
+      report.complianceReviews.length,
+      weights.complianceReviews,
+    ),
+    '',
+    chalk.bold(`Changed entities (${report.changed.length})`),
+  ];
+  for (const entity of report.changed) {
+    const where = entity.domain ? ` [${entity.domain}]` : '';
+    lines.push(`  ${entity.name} ${chalk.dim(`${entity.filePath}${where}`)}`);
+  }
+
+  if (report.teams.length > 0) {
+    lines.push('', chalk.bold('Owning teams'));
+    for (const team of report.teams) {
+      const parts = [
+        ...(team.changed.length > 0
+          ? [`changes ${team.changed.join(', ')}`]
+          : []),
+        ...(team.dependents.length > 0
+          ? [`dependents ${team.dependents.join(', ')}`]
+          : []),
+      ];
+      lines.push(`  ${chalk.cyan(team.team)} ${chalk.dim(parts.join('; '))}`);
+    }
+  }
+  if (report.unowned.length > 0) {
+    lines.push(chalk.yellow(`  No owner: ${report.unowned.join(', ')}`));
+  }
+
+  if (report.crossDomainEdges.length > 0) {
+    lines.push('', chalk.bold('Cross-domain edges'));
+    for (const edge of report.crossDomainEdges) {
+      lines.push(
+        `  ${edge.from} -> ${edge.to} ${chalk.dim(`${edge.fromDomain} -> ${edge.toDomain}`)}`,
+      );
+    }
+  }
+
+  if (report.complianceReviews.length > 0) {
+    lines.push('', chalk.bold('Compliance reviews'));
+    for (const review of report.complianceReviews) {
+      lines.push(
+        `  ${chalk.red(review.name)} ${chalk.dim(`${review.kind.replace('_', ' ')}: ${review.entities.join(', ')}`)}`,
+      );
+    }
+  }
+
+  if (unmatched.length > 0) {
+    lines.push('', chalk.dim(`Matched no entity: ${unmatched.join(', ')}`));
+  }
+  return lines.join('\n');
+}
+
+function runChangeCost(
+  targets: readonly string[],
+  options: ChangeCostCommandOptions,
+): void {
+  const maxScore =
+    options.maxScore === undefined ? undefined : Number(options.maxScore);
+  if (maxScore !== undefined && !(maxScore >= 0)) {
+    reportError('--max-score must be a non-negative number', 'usage');
+    return;
+  }
+  const changeSet = [...targets, ...(options.staged ? getStagedFiles() : [])];
+  if (changeSet.length === 0) {
+    reportError(
+      'Nothing to estimate',
+      'usage',
+      'Pass the files, directories, or entities the change touches, or --staged.',
+    );
+    return;
+  }
+
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath);
+  if (!entities) return;
+
+  const { entities: changed, unmatched } = resolveChangeSet(
+    entities,
+    changeSet,
+  );
+  if (changed.length === 0) {
+    reportError(
+      `No indexed entity in the change set: ${unmatched.join(', ')}`,
+      'usage',
+      'Paths are relative to the indexed root; entities can be given by name or id.',
+    );
+    return;
+  }
+
+  let report: ChangeCostReport;
+  try {
+    report = estimateChangeCost(changed, buildGraph(dbPath, entities));
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson({ ...report, unmatched }, true));
+  } else {
+    console.log(formatChangeCostReport(report, unmatched));
+  }
+
+  if (maxScore !== undefined && report.score > maxScore) {
+    reportCheckFailure(
+      `Change cost ${report.score} is above the maximum of ${maxScore}`,
+      'policy',
+      { score: report.score, maxScore, breakdown: report.breakdown },
+    );
+  }
+}
+
+export function registerChangeCostCommand(program: Command): void {
+  program
+    .command('change-cost [targets...]')
+    .description(
+      'Estimate the coordination cost of a change set: owning teams, cross-domain edges, and compliance reviews',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--staged', 'Add the files staged for the next commit')
+    .option('--max-score <n>', 'Exit with code 1 if the score is above n')
+    .action((targets: string[], options: ChangeCostCommandOptions) => {
+      runChangeCost(targets, options);
+    });
+}
@@ -0,0 +1,185 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that checks services' declared dependencies against their Helm values and Kubernetes environment configuration
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, runtimeconfig, helm, kubernetes, dependencies]
+ * context:
+ *   business_goal: Catch dependency annotations that disagree with what a service is actually wired to at runtime
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  checkRuntimeConfig,
+  declaredDependencies,
+  readRuntimeSettings,
+} from '@know-graph/core';
+import type {
+  RuntimeConfigReport,
+  RuntimeConfigService,
+  StoredEntity,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { readRuntimeConfig } from '../utils/manifest.js';
+
+interface CheckConfigCommandOptions {
+  readonly db: string;
+  readonly config: string;
+  readonly service?: string;
+  readonly format: string;
+}
+
+function findingCount(report: RuntimeConfigReport): number {
+  return report.unconfigured.length + report.undeclared.length;
+}
+
+export function formatRuntimeConfigReports(
+  reports: readonly RuntimeConfigReport[],
+): string {
+  const lines: string[] = [];
+  for (const report of reports) {
+    if (lines.length > 0) lines.push('');
+    if (findingCount(report) === 0) {
+      lines.push(
+        chalk.green(
+          `${report.service}: all ${report.configured.length} declared dependencies are configured`,
+        ),
+      );
+      continue;
+    }
+    lines.push(chalk.bold(report.service));
+    if (report.unconfigured.length > 0) {
+      lines.push('  Declared, but not configured:');
+      for (const { kind, name } of report.unconfigured) {
+        lines.push(`    ${chalk.red(name)} ${chalk.dim(`[${kind}]`)}`);
+      }
+    }
+    if (report.undeclared.length > 0) {
+      lines.push('  Configured, but not declared:');
+      for (const { setting, target } of report.undeclared) {
+        lines.push(
+          `    ${chalk.yellow(target)} ${chalk.dim(`${setting.key} (${setting.file})`)}`,
+        );
+      }
+    }
+  }
+  return lines.join('\n');
+}
+
+/** The service entity named `name`, or any entity of that name. */
+function findService(
+  entities: readonly StoredEntity[],
+  name: string,
+): StoredEntity | undefined {
+  const named = entities.filter((entity) => entity.name === name);
+  return named.find((entity) => entity.entityType === 'service') ?? named[0];
+}
+
+function runCheckConfig(
+  files: readonly string[],
+  options: CheckConfigCommandOptions,
+): void {
+  const config = readRuntimeConfig(resolve(options.config));
+  let services: readonly RuntimeConfigService[];
+  if (files.length > 0) {
+    if (!options.service) {
+      reportError('Files need --service to say whose they are', 'usage');
+      return;
+    }
+    services = [
+      { service: options.service, files: files.map((f) => resolve(f)) },
+    ];
+  } else {
+    services = config.services.filter(
+      ({ service }) => !options.service || service === options.service,
+    );
+    if (services.length === 0) {
+      reportError(
+        options.service
+          ? `No runtime_config entry for '${options.service}'`
+          : 'No runtime configuration to check',
+        'usage',
+        'List services and their Helm values or manifests under runtime_config in .knowgraph.yml, or pass files and --service.',
+      );
+      return;
+    }
+  }
+
+  const missing = services
+    .flatMap(({ files: paths }) => paths)
+    .find((path) => !existsSync(path));
+  if (missing) {
+    reportError(`File not found: ${missing}`, 'io');
+    return;
+  }
+
+  const entities = loadEntities(
+    resolve(options.db),
+    resolve(options.config),
+  );
+  if (!entities) return;
+
+  const reports: RuntimeConfigReport[] = [];
+  try {
+    for (const { service, files: paths } of services) {
+      const entity = findService(entities, service);
+      if (!entity) {
+        reportError(
+          `No entity named '${service}' in the index`,
+          'usage',
+          'Use the name of the service entity whose dependencies are declared.',
+        );
+        return;
+      }
+      reports.push(
+        checkRuntimeConfig(
+          {
+            name: service,
+            dependencies: declaredDependencies(entity),
+          },
+          paths.flatMap((path) => readRuntimeSettings(path)),
+          { ignore: config.ignore },
+        ),
+      );
+    }
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(reports, true));
+  } else {
+    console.log(formatRuntimeConfigReports(reports));
+  }
+
+  const findings = reports.reduce((sum, r) => sum + findingCount(r), 0);
+  if (findings > 0) {
+    reportCheckFailure(
+      `${findings} dependency mismatch(es) between annotations and runtime configuration`,
+      'policy',
+      { findings },
+    );
+  }
+}
+
+export function registerCheckConfigCommand(program: Command): void {
+  program
+    .command('check-config [files...]')
+    .description(
+      "Check services' declared dependencies against their Helm values and Kubernetes env config",
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--service <name>', 'The service the files configure, or to check')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((files: string[], options: CheckConfigCommandOptions) => {
+      runCheckConfig(files, options);
+    });
+}
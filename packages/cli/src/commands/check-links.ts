@@ -0,0 +1,115 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that verifies runbook and dashboard links resolve and reports dead links per team
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, linkcheck, runbook, dashboard]
+ * context:
+ *   business_goal: Catch dead operational links before on-call engineers need them
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  buildDeadLinkReport,
+  checkOperationalLinks,
+  collectOperationalLinks,
+  createLinkChecker,
+  parseHeaders,
+} from '@know-graph/core';
+import type { DeadLinkReport } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { loadEntities } from '../utils/db.js';
+import { reportError, reportCheckFailure } from '../utils/errors.js';
+
+interface CheckLinksCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly header?: readonly string[];
+  readonly timeout: string;
+  readonly concurrency: string;
+}
+
+export function formatDeadLinkReport(report: DeadLinkReport): string {
+  if (report.checked === 0) {
+    return 'No runbook or dashboard links found.';
+  }
+  if (report.dead === 0) {
+    return chalk.green(`All ${report.checked} operational links resolve.`);
+  }
+
+  const lines: string[] = [
+    chalk.bold(`Dead operational links: ${report.dead} of ${report.checked}`),
+  ];
+  for (const group of report.groups) {
+    lines.push('');
+    lines.push(chalk.bold(group.owner));
+    for (const result of group.deadLinks) {
+      const reason = result.error ?? `HTTP ${result.status}`;
+      lines.push(
+        `  ${result.link.name} ${chalk.dim(`[${result.link.kind}]`)} ${result.link.url}`,
+      );
+      lines.push(`    ${chalk.red(reason)}`);
+    }
+  }
+  return lines.join('\n');
+}
+
+async function runCheckLinks(options: CheckLinksCommandOptions): Promise<void> {
+  const timeoutMs = Number(options.timeout);
+  if (!Number.isInteger(timeoutMs) || timeoutMs < 1) {
+    reportError('--timeout must be a positive integer', 'usage');
+    return;
+  }
+  const concurrency = Number(options.concurrency);
+  if (!Number.isInteger(concurrency) || concurrency < 1) {
+    reportError('--concurrency must be a positive integer', 'usage');
+    return;
+  }
+
+  const entities = loadEntities(resolve(options.db));
+  if (!entities) return;
+
+  try {
+    const checker = createLinkChecker({
+      headers: parseHeaders(options.header ?? []),
+      timeoutMs,
+    });
+    const links = collectOperationalLinks(entities);
+    const results = await checkOperationalLinks(links, checker, concurrency);
+    const report = buildDeadLinkReport(results);
+
+    if (options.format === 'json') {
+      console.log(formatJson(report, true));
+    } else {
+      console.log(formatDeadLinkReport(report));
+    }
+
+    if (report.dead > 0) {
+      reportCheckFailure(`${report.dead} dead link(s)`, 'policy', {
+        dead: report.dead,
+      });
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerCheckLinksCommand(program: Command): void {
+  program
+    .command('check-links')
+    .description('Verify runbook and dashboard links resolve')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (table|json)', 'table')
+    .option(
+      '--header <header...>',
+      'Extra request headers, e.g. "Authorization: Bearer $TOKEN"',
+    )
+    .option('--timeout <ms>', 'Per-request timeout in milliseconds', '10000')
+    .option('--concurrency <n>', 'Maximum concurrent requests', '4')
+    .action(async (options: CheckLinksCommandOptions) => {
+      await runCheckLinks(options);
+    });
+}
@@ -0,0 +1,617 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that runs validation and lint together for CI, with GitHub, GitLab, and JUnit report output
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, check, ci, github, gitlab, junit]
+ * context:
+ *   business_goal: Gate pull requests on annotation quality and show problems inline on the diff
+ *   domain: cli
+ */
+import { posix, relative, resolve, sep } from 'node:path';
+import { existsSync, statSync } from 'node:fs';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  applySeverities,
+  buildFreshnessReport,
+  checkCycleBudgets,
+  createDefaultLintRules,
+  createLinter,
+  createPluginLintRule,
+  createValidator,
+  cycleMemberKey,
+  describeDue,
+  diffGoApi,
+  discoverGoPackages,
+  extractGoApi,
+  findDependencyCycles,
+} from '@know-graph/core';
+import type {
+  CycleBudgetReport,
+  DependencyCycle,
+  FreshnessReport,
+  GoApiChange,
+  LintIssue,
+  LintResult,
+  StoredEntity,
+  RuleSeverities,
+  ValidationIssue,
+  ValidationResult,
+  ValidationSeverity,
+} from '@know-graph/core';
+import { formatJson, formatSeverity } from '../utils/format.js';
+import {
+  readCycleBudgets,
+  readDescriptionThresholds,
+  readFreshnessConfig,
+  readPlugins,
+  readRuleSeverities,
+} from '../utils/manifest.js';
+import { buildGraph, loadEntities, readFileLineage } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { formatWorkflowAnnotation, writeStepSummary } from '../utils/github.js';
+import type { AnnotationLevel } from '../utils/github.js';
+import {
+  formatCodeQualityReport,
+  formatJUnitReport,
+} from '../utils/ci-reports.js';
+import {
+  compareWithBaseline,
+  createBaseline,
+  readBaseline,
+  writeBaseline,
+} from '../utils/baseline.js';
+import type { Baseline, BaselineComparison } from '../utils/baseline.js';
+import { readGoApiSurface } from '../utils/go-api.js';
+
+interface CheckCommandOptions {
+  readonly strict?: boolean;
+  readonly format: string;
+  readonly minDescription: string;
+  readonly baseline?: string;
+  readonly updateBaseline?: boolean;
+  readonly db: string;
+  readonly apiBaseline?: string;
+}
+
+const FORMATS = [
+  'text',
+  'json',
+  'github-annotations',
+  'gitlab-codequality',
+  'junit',
+];
+
+export interface CheckFinding {
+  readonly source: 'validate' | 'lint' | 'cycles' | 'api' | 'freshness';
+  readonly severity: ValidationSeverity;
+  /** Relative to the working directory. */
+  readonly filePath: string;
+  readonly line: number;
+  readonly rule: string;
+  readonly message: string;
+}
+
+/** A finding a `knowgraph:ignore` comment suppressed. */
+export interface SuppressedFinding extends CheckFinding {
+  readonly reason: string | null;
+}
+
+export interface CheckResult {
+  readonly findings: readonly CheckFinding[];
+  readonly fileCount: number;
+  readonly errorCount: number;
+  readonly warningCount: number;
+  readonly infoCount: number;
+  readonly suppressed: readonly SuppressedFinding[];
+}
+
+function toFinding(
+  source: CheckFinding['source'],
+  issue: ValidationIssue | LintIssue,
+  severity: ValidationSeverity,
+  cwd: string,
+): CheckFinding {
+  return {
+    source,
+    severity,
+    filePath: relative(cwd, issue.filePath),
+    line: issue.line,
+    rule: issue.rule,
+    message: issue.message,
+  };
+}
+
+function byLocation(a: CheckFinding, b: CheckFinding): number {
+  return a.filePath.localeCompare(b.filePath) || a.line - b.line;
+}
+
+/**
+ * Validation issues keep their severity; lint issues are warnings unless
+ * `severities` configures their rule. Findings are sorted by file and line
+ * so both tools' output reads as one report.
+ */
+export function combineCheckResults(
+  validation: ValidationResult,
+  lint: LintResult,
+  cwd = process.cwd(),
+  severities: RuleSeverities = {},
+): CheckResult {
+  const findings = [
+    ...validation.issues.map((issue) =>
+      toFinding('validate', issue, issue.severity, cwd),
+    ),
+    ...applySeverities(
+      lint.issues.map((issue) => toFinding('lint', issue, 'warning', cwd)),
+      severities,
+    ),
+  ].sort(byLocation);
+  const suppressed: SuppressedFinding[] = [
+    ...validation.suppressed.map(({ issue, reason }) => ({
+      ...toFinding('validate', issue, issue.severity, cwd),
+      reason,
+    })),
+    ...applySeverities(
+      lint.suppressed.map(({ issue, reason }) => ({
+        ...toFinding('lint', issue, 'warning', cwd),
+        reason,
+      })),
+      severities,
+    ),
+  ].sort(byLocation);
+  return withFindings(
+    findings,
+    Math.max(validation.fileCount, lint.fileCount),
+    suppressed,
+  );
+}
+
+function withFindings(
+  findings: readonly CheckFinding[],
+  fileCount: number,
+  suppressed: readonly SuppressedFinding[],
+): CheckResult {
+  const count = (severity: ValidationSeverity): number =>
+    findings.filter((f) => f.severity === severity).length;
+  return {
+    findings,
+    fileCount,
+    errorCount: count('error'),
+    warningCount: count('warning'),
+    infoCount: count('info'),
+    suppressed,
+  };
+}
+
+/**
+ * One finding per dependency cycle the baseline does not record: an error
+ * when a domain it touches is over budget, otherwise info. Each sits at
+ * its first member's declaration, found in `lines` by node id.
+ */
+export function cycleFindings(
+  report: CycleBudgetReport,
+  lines: ReadonlyMap<string, number> = new Map(),
+): readonly CheckFinding[] {
+  const budgets = new Map(report.domains.map((entry) => [entry.domain, entry]));
+  return report.cycles
+    .filter((status) => !status.baselined)
+    .map(({ cycle, domains, overBudget }) => {
+      const names = new Map(cycle.members.map((m) => [m.id, m.name]));
+      const located = cycle.members.find((member) => member.filePath);
+      const budget = domains
+        .map((domain) => {
+          const entry = budgets.get(domain);
+          return `${domain}: ${entry?.newCycles} new, budget ${entry?.budget}`;
+        })
+        .join('; ');
+      const breaking = cycle.breakingEdges
+        .map((edge) => `${names.get(edge.from)} -> ${names.get(edge.to)}`)
+        .join(', ');
+      return {
+        source: 'cycles' as const,
+        severity: overBudget ? ('error' as const) : ('info' as const),
+        filePath: located?.filePath ?? '.knowgraph.yml',
+        line: (located && lines.get(located.id)) ?? 1,
+        rule: 'dependency-cycle',
+        message: `Dependency cycle among ${cycle.members.map((m) => m.name).join(', ')} (${budget}). Remove ${breaking} to break it`,
+      };
+    });
+}
+
+/**
+ * One error per breaking change to a stable Go package, at the package's
+ * stable `module` annotation among `entities`, otherwise at its directory.
+ */
+export function apiFindings(
+  changes: readonly GoApiChange[],
+  entities: readonly StoredEntity[] = [],
+): readonly CheckFinding[] {
+  const modules = new Map<string, StoredEntity>();
+  for (const entity of entities) {
+    if (entity.entityType !== 'module' || entity.status !== 'stable') continue;
+    modules.set(posix.dirname(entity.filePath.split(sep).join('/')), entity);
+  }
+  return changes
+    .filter((change) => change.breaking)
+    .map((change) => {
+      const location = modules.get(change.dir);
+      const detail =
+        change.change === 'removed'
+          ? `removed (was ${change.before})`
+          : `changed from ${change.before} to ${change.after}`;
+      return {
+        source: 'api' as const,
+        severity: 'error' as const,
+        filePath: location?.filePath ?? change.dir,
+        line: location?.line ?? 1,
+        rule: 'api-breaking-change',
+        message: `${change.symbol} in stable package ${change.importPath} ${detail}`,
+      };
+    });
+}
+
+/**
+ * One finding per entity past its review deadline, an error, and one per
+ * entity within the reminder window, info, at its declaration.
+ */
+export function freshnessFindings(
+  report: FreshnessReport,
+): readonly CheckFinding[] {
+  return report.entries
+    .filter((entry) => entry.state !== 'current')
+    .map((entry) => {
+      const impact = entry.revenueImpact ?? 'unset';
+      const overdue = entry.state === 'overdue';
+      return {
+        source: 'freshness' as const,
+        severity: overdue ? ('error' as const) : ('info' as const),
+        filePath: entry.filePath,
+        line: entry.line,
+        rule: overdue ? 'review-overdue' : 'review-due-soon',
+        message: `${entry.name} (revenue impact ${impact}) must be reviewed every ${entry.months} months: ${describeDue(entry)}. Review the annotation and set last_reviewed`,
+      };
+    });
+}
+
+function summaryLine(result: CheckResult): string {
+  const extra = [
+    ...(result.infoCount > 0 ? [`${result.infoCount} info`] : []),
+    ...(result.suppressed.length > 0
+      ? [`${result.suppressed.length} suppressed`]
+      : []),
+  ];
+  const suffix = extra.length > 0 ? ` (${extra.join(', ')})` : '';
+  return `${result.errorCount} error(s), ${result.warningCount} warning(s) in ${result.fileCount} file(s)${suffix}`;
+}
+
+function annotationLevel(severity: ValidationSeverity): AnnotationLevel {
+  return severity === 'info' ? 'notice' : severity;
+}
+
+export function formatCheckText(result: CheckResult): string {
+  const lines = result.findings.map((finding) => {
+    const severity = formatSeverity(finding.severity);
+    const location = chalk.cyan(`${finding.filePath}:${finding.line}`);
+    return `${location} ${severity} ${chalk.dim(finding.rule)}: ${finding.message}`;
+  });
+  for (const finding of result.suppressed) {
+    const location = `${finding.filePath}:${finding.line}`;
+    lines.push(
+      chalk.dim(
+        `${location} [SUPPRESSED] ${finding.rule}: ${finding.reason ?? 'no reason given'}`,
+      ),
+    );
+  }
+  const color = result.errorCount > 0 ? chalk.red : chalk.green;
+  lines.push('', color(summaryLine(result)));
+  return lines.join('\n');
+}
+
+/**
+ * One `::error`, `::warning`, or, for info findings, `::notice` workflow
+ * command per finding.
+ */
+export function formatCheckAnnotations(result: CheckResult): string {
+  return result.findings
+    .map((finding) =>
+      formatWorkflowAnnotation({
+        level: annotationLevel(finding.severity),
+        file: finding.filePath,
+        line: finding.line,
+        title: `knowgraph ${finding.source}: ${finding.rule}`,
+        message: finding.message,
+      }),
+    )
+    .join('\n');
+}
+
+function tableCell(value: string): string {
+  return value.replace(/\|/g, '\\|').replace(/\n/g, ' ');
+}
+
+/**
+ * The Markdown job summary: totals, a table of findings, then a table of
+ * suppressed findings with their reasons.
+ */
+export function formatCheckSummary(result: CheckResult): string {
+  const lines = ['## KnowGraph check', '', `**${summaryLine(result)}**`];
+  if (result.findings.length > 0) {
+    lines.push(
+      '',
+      '| Severity | Location | Rule | Message |',
+      '|----------|----------|------|---------|',
+      ...result.findings.map(
+        (f) =>
+          `| ${f.severity} | \`${f.filePath}:${f.line}\` | ${f.rule} | ${tableCell(f.message)} |`,
+      ),
+    );
+  }
+  if (result.suppressed.length > 0) {
+    lines.push(
+      '',
+      '### Suppressed',
+      '',
+      '| Location | Rule | Reason |',
+      '|----------|------|--------|',
+      ...result.suppressed.map(
+        (f) =>
+          `| \`${f.filePath}:${f.line}\` | ${f.rule} | ${tableCell(f.reason ?? '-')} |`,
+      ),
+    );
+  }
+  return `${lines.join('\n')}\n`;
+}
+
+function baselineNote(comparison: BaselineComparison<CheckFinding>): string {
+  const fixed =
+    comparison.fixedCount > 0
+      ? `; ${comparison.fixedCount} fixed since it was recorded, run with --update-baseline to drop them`
+      : '';
+  return `${comparison.baselinedCount} existing finding(s) ignored by the baseline${fixed}`;
+}
+
+function runCheck(targetPath: string, options: CheckCommandOptions): void {
+  if (!FORMATS.includes(options.format)) {
+    reportError(
+      `Unknown format "${options.format}" (use ${FORMATS.join('|')})`,
+      'usage',
+    );
+    return;
+  }
+  const minDescriptionLength = Number(options.minDescription);
+  if (!Number.isInteger(minDescriptionLength) || minDescriptionLength < 1) {
+    reportError('--min-description must be a positive integer', 'usage');
+    return;
+  }
+  if (options.updateBaseline && !options.baseline) {
+    reportError('--update-baseline needs --baseline <path>', 'usage');
+    return;
+  }
+  const baselinePath = options.baseline && resolve(options.baseline);
+  const apiBaselinePath = options.apiBaseline && resolve(options.apiBaseline);
+  if (apiBaselinePath && !existsSync(apiBaselinePath)) {
+    reportError(
+      `API baseline not found: ${apiBaselinePath}`,
+      'io',
+      `Record the current Go API with 'knowgraph go-api --output ${options.apiBaseline}'.`,
+    );
+    return;
+  }
+  if (baselinePath && !options.updateBaseline && !existsSync(baselinePath)) {
+    reportError(
+      `Baseline not found: ${baselinePath}`,
+      'io',
+      `Record the current findings with 'knowgraph check --baseline ${options.baseline} --update-baseline'.`,
+    );
+    return;
+  }
+  const absPath = resolve(targetPath);
+  try {
+    statSync(absPath);
+  } catch {
+    reportError(`Path not found: ${absPath}`, 'io');
+    return;
+  }
+
+  let result: CheckResult;
+  let cycles: readonly DependencyCycle[] | undefined;
+  let apiChanges: readonly GoApiChange[] = [];
+  let entities: readonly StoredEntity[] | undefined = [];
+  const lines = new Map<string, number>();
+  let reviewFindings: readonly CheckFinding[] = [];
+  const budgets = readCycleBudgets(resolve('.knowgraph.yml'));
+  const freshness = readFreshnessConfig(resolve('.knowgraph.yml'));
+  try {
+    const linter = createLinter(
+      createDefaultLintRules({
+        minDescriptionLength,
+        descriptionThresholds: readDescriptionThresholds(
+          resolve('.knowgraph.yml'),
+        ),
+      }),
+      readPlugins(resolve('.knowgraph.yml'))
+        .filter((plugin) => plugin.rules)
+        .map(createPluginLintRule),
+    );
+    const severities = readRuleSeverities(resolve('.knowgraph.yml'));
+    result = combineCheckResults(
+      createValidator().validate(absPath, { severities }),
+      linter.lint(absPath),
+      process.cwd(),
+      severities,
+    );
+    const dbPath = resolve(options.db);
+    if (budgets || apiBaselinePath || freshness) {
+      entities = loadEntities(dbPath);
+    }
+    if (!entities) return;
+    if (freshness) {
+      reviewFindings = applySeverities(
+        freshnessFindings(buildFreshnessReport(entities, freshness)),
+        severities,
+      );
+    }
+    if (budgets) {
+      for (const entity of entities) lines.set(entity.id, entity.line);
+      cycles = findDependencyCycles(buildGraph(dbPath, entities));
+    }
+    if (apiBaselinePath) {
+      const root = process.cwd();
+      apiChanges = diffGoApi(
+        readGoApiSurface(apiBaselinePath),
+        extractGoApi(root, discoverGoPackages(root), entities),
+      );
+    }
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (baselinePath && options.updateBaseline) {
+    try {
+      writeBaseline(
+        baselinePath,
+        createBaseline(
+          result.findings,
+          (cycles ?? []).map((cycle) => cycle.members.map(cycleMemberKey)),
+        ),
+      );
+    } catch (err) {
+      reportError(err, 'io');
+      return;
+    }
+    const recordedCycles = cycles
+      ? ` and ${cycles.length} dependency cycle(s)`
+      : '';
+    console.log(
+      `Recorded ${result.findings.length} finding(s)${recordedCycles} in ${options.baseline}`,
+    );
+    return;
+  }
+  let baseline: Baseline | undefined;
+  if (baselinePath) {
+    let comparison: BaselineComparison<CheckFinding>;
+    try {
+      baseline = readBaseline(baselinePath);
+      comparison = compareWithBaseline(
+        result.findings,
+        baseline,
+        readFileLineage(resolve(options.db)),
+      );
+    } catch (err) {
+      reportError(err, 'parse');
+      return;
+    }
+    result = withFindings(
+      comparison.fresh,
+      result.fileCount,
+      result.suppressed,
+    );
+    console.error(chalk.dim(baselineNote(comparison)));
+  }
+  if (apiChanges.length > 0) {
+    result = withFindings(
+      [...result.findings, ...apiFindings(apiChanges, entities)].sort(
+        byLocation,
+      ),
+      result.fileCount,
+      result.suppressed,
+    );
+  }
+  if (reviewFindings.length > 0) {
+    result = withFindings(
+      [...result.findings, ...reviewFindings].sort(byLocation),
+      result.fileCount,
+      result.suppressed,
+    );
+  }
+  if (budgets && cycles) {
+    const report = checkCycleBudgets(cycles, {
+      ...budgets,
+      baseline: baseline?.cycles,
+    });
+    result = withFindings(
+      [...result.findings, ...cycleFindings(report, lines)].sort(byLocation),
+      result.fileCount,
+      result.suppressed,
+    );
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(result, true));
+  } else if (options.format === 'gitlab-codequality') {
+    console.log(formatCodeQualityReport(result.findings));
+  } else if (options.format === 'junit') {
+    console.log(
+      formatJUnitReport(result.findings, {
+        name: 'knowgraph check',
+        strict: options.strict,
+      }),
+    );
+  } else if (options.format === 'github-annotations') {
+    if (result.findings.length > 0) {
+      console.log(formatCheckAnnotations(result));
+    }
+    console.log(summaryLine(result));
+    try {
+      writeStepSummary(formatCheckSummary(result));
+    } catch (err) {
+      console.error(
+        chalk.yellow(`Could not write the step summary: ${String(err)}`),
+      );
+    }
+  } else {
+    console.log(formatCheckText(result));
+  }
+
+  if (result.errorCount > 0) {
+    reportCheckFailure(summaryLine(result), 'schema', {
+      errors: result.errorCount,
+      warnings: result.warningCount,
+    });
+  } else if (options.strict && result.warningCount > 0) {
+    reportCheckFailure(summaryLine(result), 'policy', {
+      errors: result.errorCount,
+      warnings: result.warningCount,
+    });
+  }
+}
+
+export function registerCheckCommand(program: Command): void {
+  program
+    .command('check [path]')
+    .description('Validate and lint annotations in one pass, for CI')
+    .option('--strict', 'Fail on warnings and lint issues as well as errors')
+    .option(
+      '--format <format>',
+      'Output format (text|json|github-annotations|gitlab-codequality|junit)',
+      'text',
+    )
+    .option(
+      '--min-description <length>',
+      'Minimum description length in characters',
+      '10',
+    )
+    .option(
+      '--baseline <path>',
+      'Only fail on findings not recorded in this baseline file',
+    )
+    .option(
+      '--update-baseline',
+      'Record the current findings in the --baseline file and exit',
+    )
+    .option(
+      '--db <path>',
+      'Database to read dependency cycles and moved baseline files from',
+      '.knowgraph/knowgraph.db',
+    )
+    .option(
+      '--api-baseline <path>',
+      'Fail on breaking changes to stable Go packages since this recorded API',
+    )
+    .action((path: string | undefined, options: CheckCommandOptions) => {
+      runCheck(path ?? '.', options);
+    });
+}
@@ -0,0 +1,153 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that finds densely connected clusters spanning several modules and suggests extraction boundaries
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, refactor, modules, clustering]
+ * context:
+ *   business_goal: Help teams planning a refactor see which functions and types belong together across module lines
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { filterGraphEdges, suggestExtractions } from '@know-graph/core';
+import type {
+  DependencyGraph,
+  ExtractionReport,
+  ExtractionSuggestion,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { parseEdgeFilter } from '../utils/edge-filter.js';
+import { reportError } from '../utils/errors.js';
+import { readGraphFile } from './stitch.js';
+
+interface ClustersCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly minSize: string;
+  readonly minCohesion: string;
+  readonly provenance?: string;
+  readonly minConfidence?: string;
+}
+
+function suggestionLines(
+  suggestion: ExtractionSuggestion,
+  index: number,
+): readonly string[] {
+  const modules = suggestion.modules.map((entry) => entry.module).join(', ');
+  const lines = [
+    chalk.bold(
+      `${index + 1}. ${suggestion.members.length} entities across ${modules}`,
+    ) +
+      chalk.dim(
+        ` (cohesion ${suggestion.cohesion}, ${suggestion.internalEdges} internal / ${suggestion.boundaryEdges} boundary edges)`,
+      ),
+  ];
+  for (const entry of suggestion.modules) {
+    const share = `${Math.round(entry.share * 100)}% of module`;
+    lines.push(
+      `   ${chalk.cyan(entry.module)} ${entry.members.join(', ')} ${chalk.dim(share)}`,
+    );
+  }
+  lines.push(
+    suggestion.home
+      ? `   Move ${suggestion.moves.join(', ')} into ${chalk.green(suggestion.home)}, or extract all ${suggestion.members.length} into a new module`
+      : `   Extract all ${suggestion.members.length} into a new module`,
+  );
+  return lines;
+}
+
+export function formatExtractionReport(report: ExtractionReport): string {
+  if (report.suggestions.length === 0) {
+    return chalk.green(
+      `No cluster spans more than one module (${report.clusters} clusters found).`,
+    );
+  }
+  const lines = [
+    chalk.bold(
+      `${report.suggestions.length} of ${report.clusters} clusters span more than one module`,
+    ),
+  ];
+  report.suggestions.forEach((suggestion, index) => {
+    lines.push('', ...suggestionLines(suggestion, index));
+  });
+  return lines.join('\n');
+}
+
+function runClusters(
+  graphPath: string | undefined,
+  options: ClustersCommandOptions,
+): void {
+  const minSize = Number(options.minSize);
+  if (!Number.isInteger(minSize) || minSize < 2) {
+    reportError('--min-size must be an integer of at least 2', 'usage');
+    return;
+  }
+  const minCohesion = Number(options.minCohesion);
+  if (!(minCohesion >= 0 && minCohesion <= 1)) {
+    reportError('--min-cohesion must be a number from 0 to 1', 'usage');
+    return;
+  }
+
+  let report: ExtractionReport;
+  try {
+    const edgeFilter = parseEdgeFilter(
+      options.provenance,
+      options.minConfidence,
+    );
+    let graph: DependencyGraph;
+    if (graphPath) {
+      graph = readGraphFile(resolve(graphPath));
+    } else {
+      const dbPath = resolve(options.db);
+      const entities = loadEntities(dbPath);
+      if (!entities) return;
+      graph = buildGraph(dbPath, entities);
+    }
+    report = suggestExtractions(
+      edgeFilter ? filterGraphEdges(graph, edgeFilter) : graph,
+      { minSize, minCohesion },
+    );
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else {
+    console.log(formatExtractionReport(report));
+  }
+}
+
+export function registerClustersCommand(program: Command): void {
+  program
+    .command('clusters [graph]')
+    .description(
+      'Find densely connected clusters spanning several modules and suggest what to move together',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--min-size <n>', 'Smallest cluster worth suggesting', '3')
+    .option(
+      '--min-cohesion <share>',
+      'Lowest share of edge weight touching a cluster that stays inside it',
+      '0.5',
+    )
+    .option(
+      '--provenance <list>',
+      'Cluster only graph edges with these provenances (e.g. declared,call)',
+    )
+    .option(
+      '--min-confidence <n>',
+      'Cluster only graph edges with at least this confidence, from 0 to 1',
+    )
+    .action(
+      (graphPath: string | undefined, options: ClustersCommandOptions) => {
+        runClusters(graphPath, options);
+      },
+    );
+}
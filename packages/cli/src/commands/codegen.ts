@@ -0,0 +1,169 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that writes Go interfaces and test stubs for a module's declared service dependencies from their OpenAPI and protobuf specs
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, codegen, go, stubs, testing]
+ * context:
+ *   business_goal: Make declaring a dependency yield a testable seam without hand-writing the client interface
+ *   domain: cli
+ */
+import { mkdirSync, writeFileSync } from 'node:fs';
+import { basename, join, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { generateDependencyStubs, goFileName } from '@know-graph/core';
+import type {
+  DependencyGraph,
+  StoredEntity,
+  StubGeneration,
+} from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import { resolveGraphNode } from '../utils/nodes.js';
+
+interface CodegenCommandOptions {
+  readonly output: string;
+  readonly package?: string;
+  readonly root: string;
+  readonly dryRun?: boolean;
+  readonly db: string;
+  readonly format: string;
+}
+
+export function formatStubGeneration(
+  result: StubGeneration,
+  output: string,
+  dryRun = false,
+): string {
+  const { consumer, stubs, skipped } = result;
+  const lines: string[] = [];
+  if (stubs.length === 0 && skipped.length === 0) {
+    return chalk.dim(`${consumer.name} declares no service dependencies.`);
+  }
+  if (stubs.length > 0) {
+    const verb = dryRun ? 'Would write' : 'Wrote';
+    lines.push(
+      chalk.green(
+        `${verb} stubs for ${stubs.length} dependency(ies) of ${consumer.name}`,
+      ),
+    );
+  }
+  for (const stub of stubs) {
+    lines.push(
+      `  ${chalk.cyan(stub.interfaceName)} ${chalk.dim(`${stub.operations} operation(s) from ${stub.specs.join(', ')}`)}`,
+    );
+    for (const file of stub.files) {
+      lines.push(`    ${join(output, file.fileName)}`);
+    }
+  }
+  if (skipped.length > 0) {
+    if (lines.length > 0) lines.push('');
+    lines.push(chalk.bold('Skipped'));
+    for (const skip of skipped) {
+      lines.push(`  ${chalk.yellow(skip.provider.name)}: ${skip.reason}`);
+    }
+  }
+  return lines.join('\n');
+}
+
+function runCodegen(name: string, options: CodegenCommandOptions): void {
+  let entities: readonly StoredEntity[];
+  let graph: DependencyGraph;
+  try {
+    const dbPath = resolve(options.db);
+    const loaded = loadEntities(dbPath);
+    if (!loaded) return;
+    entities = loaded;
+    graph = buildGraph(dbPath, entities);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const consumer = resolveGraphNode(graph, name);
+  if (!consumer) return;
+  const outputDir = resolve(options.output);
+  const packageName = options.package ?? goFileName(basename(outputDir));
+  if (!/^[a-z_][a-z0-9_]*$/.test(packageName)) {
+    reportError(
+      `'${packageName}' is not a Go package name`,
+      'usage',
+      'Pass --package with a lower-case identifier',
+    );
+    return;
+  }
+
+  let result: StubGeneration;
+  try {
+    result = generateDependencyStubs(entities, graph, consumer.id, {
+      rootDir: resolve(options.root),
+      packageName,
+    });
+    if (!options.dryRun) {
+      for (const stub of result.stubs) {
+        mkdirSync(outputDir, { recursive: true });
+        for (const file of stub.files) {
+          const path = join(outputDir, file.fileName);
+          writeFileSync(path, file.content, 'utf-8');
+        }
+      }
+    }
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(
+      formatJson(
+        {
+          consumer: consumer.id,
+          package: packageName,
+          stubs: result.stubs.map((stub) => ({
+            provider: stub.provider.id,
+            interface: stub.interfaceName,
+            specs: stub.specs,
+            operations: stub.operations,
+            files: stub.files.map((file) =>
+              join(options.output, file.fileName),
+            ),
+          })),
+          skipped: result.skipped.map((skip) => ({
+            provider: skip.provider.id,
+            reason: skip.reason,
+          })),
+        },
+        true,
+      ),
+    );
+  } else {
+    console.log(formatStubGeneration(result, options.output, options.dryRun));
+  }
+}
+
+export function registerCodegenCommand(program: Command): void {
+  program
+    .command('codegen <module>')
+    .description(
+      "Generate Go interfaces and test stubs for a module's service dependencies from the OpenAPI and protobuf specs their providers link in api_specs",
+    )
+    .option('--output <dir>', 'Directory to write the Go files to', 'deps')
+    .option(
+      '--package <name>',
+      'Go package of the files (default: the output directory name)',
+    )
+    .option(
+      '--root <dir>',
+      'Directory the indexed file paths are relative to',
+      '.',
+    )
+    .option('--dry-run', 'List the files without writing them')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((name: string, options: CodegenCommandOptions) => {
+      runCodegen(name, options);
+    });
+}
@@ -0,0 +1,103 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that prunes old scans from the scan history under its retention policy while keeping monthly trend aggregates
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, history, retention, compaction]
+ * context:
+ *   business_goal: Keep years of graph trends without the scan history growing without bound
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  compactScanHistory,
+  readScanHistory,
+  writeScanHistory,
+} from '@know-graph/core';
+import type { CompactionResult } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+import { historyPath, toRetentionPolicy } from '../utils/history.js';
+import { readHistoryConfig } from '../utils/manifest.js';
+
+interface CompactHistoryCommandOptions {
+  readonly config: string;
+  readonly format: string;
+  readonly dryRun?: boolean;
+}
+
+export function formatCompaction(
+  before: number,
+  result: CompactionResult,
+  dryRun = false,
+): string {
+  if (result.pruned === 0) {
+    return chalk.green(`Nothing to compact in ${before} scans.`);
+  }
+  const verb = dryRun ? 'Would compact' : 'Compacted';
+  return [
+    `${verb} ${before} scans to ${result.history.length}:`,
+    `  Kept whole:       ${result.kept}`,
+    `  Trend aggregates: ${result.aggregates}`,
+    `  Pruned:           ${result.pruned}`,
+  ].join('\n');
+}
+
+function runCompactHistory(options: CompactHistoryCommandOptions): void {
+  if (options.format !== 'text' && options.format !== 'json') {
+    reportError(
+      `Unknown format "${options.format}". Use text or json.`,
+      'usage',
+    );
+    return;
+  }
+  const configPath = resolve(options.config);
+  const path = historyPath(configPath);
+  try {
+    const history = readScanHistory(path);
+    const result = compactScanHistory(
+      history,
+      toRetentionPolicy(readHistoryConfig(configPath)),
+    );
+    if (!options.dryRun && result.pruned > 0) {
+      writeScanHistory(path, result.history);
+    }
+    if (options.format === 'json') {
+      console.log(
+        formatJson(
+          {
+            path,
+            dryRun: options.dryRun ?? false,
+            before: history.length,
+            after: result.history.length,
+            kept: result.kept,
+            aggregates: result.aggregates,
+            pruned: result.pruned,
+          },
+          true,
+        ),
+      );
+    } else {
+      console.log(formatCompaction(history.length, result, options.dryRun));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerCompactHistoryCommand(program: Command): void {
+  program
+    .command('compact-history')
+    .description(
+      'Prune old scans from the scan history, keeping monthly trend aggregates',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--dry-run', 'Report what would be pruned without rewriting it')
+    .action((options: CompactHistoryCommandOptions) => {
+      runCompactHistory(options);
+    });
+}
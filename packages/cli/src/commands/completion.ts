@@ -0,0 +1,232 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that prints bash, zsh, and fish completion scripts generated from the command tree
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, completion, shell]
+ * context:
+ *   business_goal: Make every knowgraph command and option discoverable with tab completion
+ *   domain: cli
+ */
+import type { Command } from 'commander';
+import { reportError } from '../utils/errors.js';
+
+export const COMPLETION_SHELLS = ['bash', 'zsh', 'fish'] as const;
+
+export type CompletionShell = (typeof COMPLETION_SHELLS)[number];
+
+interface CompletionOption {
+  readonly flag: string;
+  readonly description: string;
+  readonly takesValue: boolean;
+}
+
+/** One command in the tree, by the words that lead to it. */
+interface CompletionEntry {
+  /** Words after `knowgraph`, each after a space: ` hook install`. */
+  readonly path: string;
+  readonly subcommands: readonly {
+    readonly name: string;
+    readonly description: string;
+  }[];
+  readonly options: readonly CompletionOption[];
+}
+
+function collect(
+  command: Command,
+  path: string,
+  inherited: readonly CompletionOption[],
+): CompletionEntry[] {
+  const own = command.options.flatMap((option) =>
+    option.long && !option.hidden
+      ? [
+          {
+            flag: option.long,
+            description: option.description,
+            takesValue: option.required || option.optional,
+          },
+        ]
+      : [],
+  );
+  const options = [
+    ...inherited,
+    ...own,
+    { flag: '--help', description: 'Display help', takesValue: false },
+  ];
+  const subcommands = command.commands.map((sub) => ({
+    name: sub.name(),
+    description: sub.description(),
+  }));
+  // Root options, such as --error-format, are accepted after any command
+  const shared =
+    path === ''
+      ? own.filter((option) => option.flag !== '--version')
+      : inherited;
+  return [
+    { path, subcommands, options },
+    ...command.commands.flatMap((sub) =>
+      collect(sub, `${path} ${sub.name()}`, shared),
+    ),
+  ];
+}
+
+function words(entry: CompletionEntry): string {
+  return [
+    ...entry.subcommands.map((sub) => sub.name),
+    ...entry.options.map((option) => option.flag),
+  ].join(' ');
+}
+
+function bash(entries: readonly CompletionEntry[]): string {
+  const paths = entries.slice(1).map((entry) => `"${entry.path}"`);
+  return [
+    '# knowgraph bash completion. Add to ~/.bashrc:',
+    '#   source <(knowgraph completion bash)',
+    '_knowgraph() {',
+    '  local cur="${COMP_WORDS[COMP_CWORD]}" cmdpath="" i',
+    '  for ((i = 1; i < COMP_CWORD; i++)); do',
+    '    case "$cmdpath ${COMP_WORDS[i]}" in',
+    `      ${paths.join('|')}) cmdpath="$cmdpath \${COMP_WORDS[i]}" ;;`,
+    '    esac',
+    '  done',
+    '  case "$cmdpath" in',
+    ...entries.map(
+      (entry) =>
+        `    "${entry.path}") COMPREPLY=($(compgen -W "${words(entry)}" -- "$cur")) ;;`,
+    ),
+    '  esac',
+    '}',
+    // Arguments that match no word fall back to file names
+    'complete -o default -F _knowgraph knowgraph',
+    '',
+  ].join('\n');
+}
+
+function zshQuote(text: string): string {
+  return `'${text.replace(/'/g, `'\\''`)}'`;
+}
+
+function zshDescription(text: string): string {
+  return text.replace(/[[\]:\\]/g, (char) => `\\${char}`);
+}
+
+function zsh(entries: readonly CompletionEntry[]): string {
+  const paths = entries.slice(1).map((entry) => `"${entry.path}"`);
+  const cases = entries.flatMap((entry) => {
+    const body =
+      entry.subcommands.length > 0
+        ? [
+            '      local -a commands=(',
+            ...entry.subcommands.map(
+              (sub) =>
+                `        ${zshQuote(`${sub.name}:${zshDescription(sub.description)}`)}`,
+            ),
+            '      )',
+            "      _describe 'command' commands",
+          ]
+        : [
+            '      _arguments \\',
+            ...entry.options.map((option) => {
+              const value = option.takesValue ? ':value:' : '';
+              const spec = `${option.flag}[${zshDescription(option.description)}]${value}`;
+              return `        ${zshQuote(spec)} \\`;
+            }),
+            "        '*:file:_files'",
+          ];
+    return [`    "${entry.path}")`, ...body, '      ;;'];
+  });
+  return [
+    '#compdef knowgraph',
+    '# knowgraph zsh completion. Add to ~/.zshrc:',
+    '#   source <(knowgraph completion zsh)',
+    '_knowgraph() {',
+    '  local cmdpath="" i',
+    '  for ((i = 2; i < CURRENT; i++)); do',
+    '    case "$cmdpath ${words[i]}" in',
+    `      ${paths.join('|')}) cmdpath="$cmdpath \${words[i]}" ;;`,
+    '    esac',
+    '  done',
+    '  case "$cmdpath" in',
+    ...cases,
+    '  esac',
+    '}',
+    'compdef _knowgraph knowgraph',
+    '',
+  ].join('\n');
+}
+
+function fishQuote(text: string): string {
+  return `'${text.replace(/[\\']/g, (char) => `\\${char}`)}'`;
+}
+
+function fish(entries: readonly CompletionEntry[]): string {
+  const paths = entries.slice(1).map((entry) => fishQuote(entry.path));
+  const lines = [
+    '# knowgraph fish completion. Save it with:',
+    '#   knowgraph completion fish > ~/.config/fish/completions/knowgraph.fish',
+    `set -g __knowgraph_commands ${paths.join(' ')}`,
+    '',
+    '# Whether the words typed so far lead to the command at $argv[1]',
+    'function __knowgraph_at',
+    "    set -l cmdpath ''",
+    '    for token in (commandline -opc)[2..-1]',
+    '        if contains -- "$cmdpath $token" $__knowgraph_commands',
+    '            set cmdpath "$cmdpath $token"',
+    '        end',
+    '    end',
+    '    test "$cmdpath" = "$argv[1]"',
+    'end',
+    '',
+  ];
+  for (const entry of entries) {
+    const condition = fishQuote(`__knowgraph_at ${fishQuote(entry.path)}`);
+    for (const sub of entry.subcommands) {
+      lines.push(
+        `complete -c knowgraph -n ${condition} -f -a ${sub.name} -d ${fishQuote(sub.description)}`,
+      );
+    }
+    for (const option of entry.options) {
+      const value = option.takesValue ? ' -r' : '';
+      lines.push(
+        `complete -c knowgraph -n ${condition} -l ${option.flag.slice(2)}${value} -d ${fishQuote(option.description)}`,
+      );
+    }
+  }
+  lines.push('');
+  return lines.join('\n');
+}
+
+/**
+ * The completion script for `shell`, covering every command, subcommand,
+ * and long option registered on `program`.
+ */
+export function generateCompletion(
+  program: Command,
+  shell: CompletionShell,
+): string {
+  const entries = collect(program, '', []);
+  if (shell === 'bash') return bash(entries);
+  if (shell === 'zsh') return zsh(entries);
+  return fish(entries);
+}
+
+function isCompletionShell(shell: string): shell is CompletionShell {
+  return (COMPLETION_SHELLS as readonly string[]).includes(shell);
+}
+
+export function registerCompletionCommand(program: Command): void {
+  program
+    .command('completion <shell>')
+    .description('Print a shell completion script (bash|zsh|fish)')
+    .action((shell: string) => {
+      if (!isCompletionShell(shell)) {
+        reportError(
+          `Unknown shell "${shell}". Use ${COMPLETION_SHELLS.join(', ')}.`,
+          'usage',
+        );
+        return;
+      }
+      process.stdout.write(generateCompletion(program, shell));
+    });
+}
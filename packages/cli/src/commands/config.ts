@@ -0,0 +1,99 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lints .knowgraph.yml, reporting schema errors, misspelled keys with suggestions, and deprecated settings, and prints the manifest JSON Schema
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, config, manifest, lint, validation, json-schema]
+ * context:
+ *   business_goal: Stop a typo in .knowgraph.yml from silently producing a wrong graph
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { lintManifest, manifestJsonSchema } from '@know-graph/core';
+import type { ConfigIssue } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+
+interface ConfigLintCommandOptions {
+  readonly config: string;
+  readonly format: string;
+}
+
+export function formatConfigIssues(
+  configPath: string,
+  issues: readonly ConfigIssue[],
+): string {
+  if (issues.length === 0) return chalk.green(`${configPath} is valid`);
+  const lines = issues.map((issue) => {
+    const where = issue.line ? `${configPath}:${issue.line}` : configPath;
+    const severity =
+      issue.severity === 'error'
+        ? chalk.red('error  ')
+        : chalk.yellow('warning');
+    return `${chalk.dim(where)}  ${severity}  ${issue.message}`;
+  });
+  const errors = issues.filter((issue) => issue.severity === 'error').length;
+  lines.push('', `${errors} error(s), ${issues.length - errors} warning(s)`);
+  return lines.join('\n');
+}
+
+function runConfigLint(options: ConfigLintCommandOptions): void {
+  const configPath = resolve(options.config);
+  if (!existsSync(configPath)) {
+    reportError(
+      `No manifest at ${options.config}`,
+      'io',
+      'Run `knowgraph init` to create one, or pass --config.',
+    );
+    return;
+  }
+  let issues: readonly ConfigIssue[];
+  try {
+    issues = lintManifest(configPath);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(issues, true));
+  } else {
+    console.log(formatConfigIssues(options.config, issues));
+  }
+  const errors = issues.filter((issue) => issue.severity === 'error');
+  if (errors.length > 0) {
+    reportCheckFailure(`${errors.length} error(s) in the manifest`, 'schema', {
+      paths: errors.map((issue) => issue.path),
+    });
+  }
+}
+
+export function registerConfigCommand(program: Command): void {
+  const config = program
+    .command('config')
+    .description('Work with the .knowgraph.yml manifest');
+
+  config
+    .command('lint')
+    .description(
+      'Check the manifest against its schema, suggesting the key each unknown one probably meant and flagging deprecated settings',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: ConfigLintCommandOptions) => {
+      runConfigLint(options);
+    });
+
+  config
+    .command('schema')
+    .description(
+      'Print the JSON Schema of the manifest, for editors and schema/v1.0/manifest.schema.json',
+    )
+    .action(() => {
+      console.log(formatJson(manifestJsonSchema(), true));
+    });
+}
@@ -0,0 +1,204 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that reports the modules and edges the graph adds to or lacks from a target architecture file, with convergence tracked across recorded runs
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, conformance, architecture, target, convergence]
+ * context:
+ *   business_goal: Let architects measure how far the codebase is from the architecture they intend, and whether it is getting closer
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { dirname, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  appendConformancePoint,
+  checkConformance,
+  conformancePoint,
+  parseTargetArchitecture,
+  readConformanceHistory,
+} from '@know-graph/core';
+import type {
+  ConformancePoint,
+  ConformanceReport,
+  TargetArchitecture,
+} from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { readConformanceConfig } from '../utils/manifest.js';
+
+interface ConformCommandOptions {
+  readonly db: string;
+  readonly config: string;
+  readonly format: string;
+  readonly record?: boolean;
+  readonly minConvergence?: string;
+}
+
+const FORMATS = ['text', 'json'];
+
+function trend(
+  report: ConformanceReport,
+  previous: ConformancePoint | undefined,
+): string {
+  if (!previous) return '';
+  const delta =
+    Math.round((report.convergence - previous.convergence) * 10) / 10;
+  const since = `since ${previous.timestamp.slice(0, 10)}`;
+  if (delta > 0) return chalk.green(` (+${delta} ${since})`);
+  if (delta < 0) return chalk.red(` (${delta} ${since})`);
+  return chalk.dim(` (unchanged ${since})`);
+}
+
+/**
+ * The convergence, with its change since `previous` when a run was
+ * recorded, then what the graph lacks from and adds to the target.
+ */
+export function formatConformanceText(
+  report: ConformanceReport,
+  previous?: ConformancePoint,
+): string {
+  const { modules, edges } = report;
+  const lines = [
+    chalk.bold(`Convergence: ${report.convergence}%`) +
+      trend(report, previous),
+    chalk.dim(
+      `Modules: ${modules.matched} matched, ${modules.missing.length} missing, ${modules.unexpected.length} unexpected. ` +
+        `Edges: ${edges.matched} matched, ${edges.missing.length} missing, ${edges.unexpected.length} unexpected.`,
+    ),
+  ];
+  if (modules.missing.length > 0) {
+    lines.push('', chalk.bold(`Missing modules (${modules.missing.length})`));
+    lines.push(...modules.missing.map((name) => `  ${chalk.yellow(name)}`));
+  }
+  if (modules.unexpected.length > 0) {
+    lines.push(
+      '',
+      chalk.bold(`Unexpected modules (${modules.unexpected.length})`),
+    );
+    for (const module of modules.unexpected) {
+      const owner = module.owner ? `, ${module.owner}` : '';
+      lines.push(
+        `  ${chalk.red(module.name)} ${chalk.dim(`(${module.entityType ?? 'unknown'}${owner}) ${module.filePath ?? ''}`)}`,
+      );
+    }
+  }
+  if (edges.missing.length > 0) {
+    lines.push('', chalk.bold(`Missing edges (${edges.missing.length})`));
+    lines.push(...edges.missing.map((edge) => `  ${edge.from} -> ${edge.to}`));
+  }
+  if (edges.unexpected.length > 0) {
+    lines.push('', chalk.bold(`Unexpected edges (${edges.unexpected.length})`));
+    lines.push(
+      ...edges.unexpected.map(
+        (edge) =>
+          `  ${chalk.red(`${edge.from} -> ${edge.to}`)} ${chalk.dim(edge.kinds.join(', '))}`,
+      ),
+    );
+  }
+  return lines.join('\n');
+}
+
+function runConform(
+  targetFile: string | undefined,
+  options: ConformCommandOptions,
+): void {
+  if (!FORMATS.includes(options.format)) {
+    reportError(
+      `Unknown format "${options.format}" (use ${FORMATS.join('|')})`,
+      'usage',
+    );
+    return;
+  }
+  const minConvergence =
+    options.minConvergence === undefined
+      ? undefined
+      : Number(options.minConvergence);
+  if (
+    minConvergence !== undefined &&
+    !(minConvergence >= 0 && minConvergence <= 100)
+  ) {
+    reportError(
+      '--min-convergence must be a percentage from 0 to 100',
+      'usage',
+    );
+    return;
+  }
+
+  const configPath = resolve(options.config);
+  const config = readConformanceConfig(configPath);
+  const targetPath = targetFile
+    ? resolve(targetFile)
+    : resolve(dirname(configPath), config.target);
+  let target: TargetArchitecture;
+  try {
+    target = parseTargetArchitecture(
+      readFileSync(targetPath, 'utf-8'),
+      targetPath,
+    );
+  } catch (err) {
+    reportError(
+      err,
+      undefined,
+      'Name the intended modules and edges in the target architecture file, or pass its path.',
+    );
+    return;
+  }
+
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath, configPath);
+  if (!entities) return;
+
+  const historyPath = resolve(dirname(configPath), config.path);
+  let report: ConformanceReport;
+  let previous: ConformancePoint | undefined;
+  try {
+    report = checkConformance(
+      buildGraph(dbPath, entities, { configPath }),
+      target,
+    );
+    previous = readConformanceHistory(historyPath).at(-1);
+    if (options.record) {
+      appendConformancePoint(historyPath, conformancePoint(report));
+    }
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson({ ...report, previous: previous ?? null }, true));
+  } else {
+    console.log(formatConformanceText(report, previous));
+  }
+
+  if (minConvergence !== undefined && report.convergence < minConvergence) {
+    reportCheckFailure(
+      `Convergence ${report.convergence}% is below the minimum of ${minConvergence}%`,
+      'policy',
+      { convergence: report.convergence, minConvergence },
+    );
+  }
+}
+
+export function registerConformCommand(program: Command): void {
+  program
+    .command('conform [target]')
+    .description(
+      'Compare the graph with a target architecture file and report the modules and edges it adds or lacks',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--record', 'Append the run to the conformance history')
+    .option(
+      '--min-convergence <percent>',
+      'Exit with code 1 if convergence is below percent',
+    )
+    .action((target: string | undefined, options: ConformCommandOptions) => {
+      runConform(target, options);
+    });
+}
@@ -0,0 +1,239 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that writes consumer/provider contract test skeletons for service dependencies and reports the dependencies no contract test covers
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, contracts, pact, testing, coverage]
+ * context:
+ *   business_goal: Turn the service dependencies the graph declares into ones that tests verify
+ *   domain: cli
+ */
+import { existsSync, mkdirSync, writeFileSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  checkContractCoverage,
+  contractEdges,
+  renderContractSkeleton,
+  scanContractTests,
+} from '@know-graph/core';
+import type {
+  ContractEdge,
+  ContractFramework,
+  ContractReport,
+  DependencyGraph,
+} from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import { resolveGraphNode } from '../utils/nodes.js';
+
+const FRAMEWORKS: readonly ContractFramework[] = ['pact', 'go'];
+
+interface ContractsGenerateOptions {
+  readonly framework: string;
+  readonly output: string;
+  readonly consumer?: string;
+  readonly dryRun?: boolean;
+  readonly db: string;
+  readonly format: string;
+}
+
+interface ContractsCoverageOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly check?: boolean;
+}
+
+interface GeneratedSkeleton {
+  readonly consumer: string;
+  readonly provider: string;
+  readonly path: string;
+  /** False when a file already had the name and was left alone. */
+  readonly written: boolean;
+}
+
+function edgeLabel(edge: ContractEdge): string {
+  return `${chalk.cyan(edge.consumer.name)} -> ${chalk.cyan(edge.provider.name)}`;
+}
+
+export function formatContractReport(report: ContractReport): string {
+  const total = report.covered + report.uncovered;
+  if (total === 0) {
+    return chalk.dim('No service dependencies to cover.');
+  }
+  const lines = [
+    report.uncovered === 0
+      ? chalk.green(`All ${total} service dependencies have a contract test.`)
+      : chalk.bold(
+          `${report.uncovered} of ${total} service dependencies have no contract test`,
+        ),
+  ];
+  for (const { edge, tests } of report.edges) {
+    if (tests.length === 0) {
+      lines.push(
+        `  ${chalk.red('uncovered')} ${edgeLabel(edge)} ${chalk.dim(`(${edge.provenance})`)}`,
+      );
+    } else {
+      const files = tests.map((test) => test.filePath).join(', ');
+      lines.push(
+        `  ${chalk.green('covered  ')} ${edgeLabel(edge)} ${chalk.dim(files)}`,
+      );
+    }
+  }
+  if (report.orphaned.length > 0) {
+    lines.push('', chalk.bold('Contract tests of no dependency in the graph'));
+    for (const test of report.orphaned) {
+      lines.push(
+        `  ${test.consumer} -> ${test.provider} ${chalk.dim(test.filePath)}`,
+      );
+    }
+  }
+  return lines.join('\n');
+}
+
+function loadGraph(db: string): DependencyGraph | undefined {
+  const dbPath = resolve(db);
+  const entities = loadEntities(dbPath);
+  return entities ? buildGraph(dbPath, entities) : undefined;
+}
+
+function runGenerate(options: ContractsGenerateOptions): void {
+  const framework = options.framework as ContractFramework;
+  if (!FRAMEWORKS.includes(framework)) {
+    reportError(
+      `Unknown framework '${options.framework}'`,
+      'usage',
+      `Use one of: ${FRAMEWORKS.join(', ')}`,
+    );
+    return;
+  }
+
+  let graph: DependencyGraph | undefined;
+  try {
+    graph = loadGraph(options.db);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  if (!graph) return;
+
+  let edges = contractEdges(graph);
+  if (options.consumer !== undefined) {
+    const consumer = resolveGraphNode(
+      graph,
+      options.consumer,
+      `--consumer ${options.consumer}`,
+    );
+    if (!consumer) return;
+    edges = edges.filter((edge) => edge.consumer.id === consumer.id);
+  }
+
+  const outputDir = resolve(options.output);
+  const generated: GeneratedSkeleton[] = [];
+  try {
+    for (const edge of edges) {
+      const skeleton = renderContractSkeleton(edge, framework);
+      const path = join(outputDir, skeleton.fileName);
+      // Never overwrite a contract someone has started filling in
+      const written = !existsSync(path);
+      if (written && !options.dryRun) {
+        mkdirSync(outputDir, { recursive: true });
+        writeFileSync(path, skeleton.content, 'utf-8');
+      }
+      generated.push({
+        consumer: edge.consumer.id,
+        provider: edge.provider.id,
+        path: join(options.output, skeleton.fileName),
+        written,
+      });
+    }
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(generated, true));
+    return;
+  }
+  if (generated.length === 0) {
+    console.log(chalk.dim('No service dependencies to write contracts for.'));
+    return;
+  }
+  const verb = options.dryRun ? 'Would write' : 'Wrote';
+  const count = generated.filter((skeleton) => skeleton.written).length;
+  console.log(
+    chalk.green(`${verb} ${count} ${framework} contract skeleton(s)`),
+  );
+  for (const skeleton of generated) {
+    const note = skeleton.written ? '' : chalk.dim(' (exists, left alone)');
+    console.log(`  ${skeleton.path}${note}`);
+  }
+}
+
+function runCoverage(root: string, options: ContractsCoverageOptions): void {
+  let report: ContractReport;
+  try {
+    const graph = loadGraph(options.db);
+    if (!graph) return;
+    report = checkContractCoverage(graph, scanContractTests(resolve(root)));
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else {
+    console.log(formatContractReport(report));
+  }
+  if (options.check && report.uncovered > 0) {
+    reportCheckFailure(
+      `${report.uncovered} service dependency(ies) without a contract test`,
+      'policy',
+      { uncovered: report.uncovered, covered: report.covered },
+    );
+  }
+}
+
+export function registerContractsCommand(program: Command): void {
+  const contractsCmd = program
+    .command('contracts')
+    .description(
+      'Generate contract tests for service dependencies and report the ones without',
+    );
+
+  contractsCmd
+    .command('generate')
+    .description(
+      'Write a consumer/provider contract test skeleton for each service dependency',
+    )
+    .option('--framework <framework>', 'Test framework (pact|go)', 'pact')
+    .option('--output <dir>', 'Directory to write skeletons to', 'contracts')
+    .option(
+      '--consumer <name>',
+      'Only the dependencies of this node, by name, alias, or id',
+    )
+    .option('--dry-run', 'List the skeletons without writing them')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: ContractsGenerateOptions) => {
+      runGenerate(options);
+    });
+
+  contractsCmd
+    .command('coverage')
+    .description(
+      'Report service dependencies that no pact file, Pact test, or knowgraph:contract marker covers',
+    )
+    .argument('[root]', 'Directory to look for contract tests in', '.')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--check', 'Exit 1 when a service dependency has no contract')
+    .action((root: string, options: ContractsCoverageOptions) => {
+      runCoverage(root, options);
+    });
+}
@@ -0,0 +1,126 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that joins cloud cost exports to annotated modules by resource tag
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, finops, cost]
+ * context:
+ *   business_goal: Give FinOps reviews a per-module and per-cost-center view of cloud spend
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  buildCostReport,
+  CloudProviderSchema,
+  parseCostExport,
+} from '@know-graph/core';
+import type { CloudProvider, CostLineItem, CostReport } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface CostCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly provider?: string;
+}
+
+function formatAmount(amount: number, currency: string | null): string {
+  const value = amount.toFixed(2);
+  return currency ? `${value} ${currency}` : value;
+}
+
+export function formatCostReport(report: CostReport): string {
+  if (report.modules.length === 0) {
+    return 'No entities declare cost_center or cloud_resources.';
+  }
+
+  const { currency } = report;
+  const lines: string[] = [chalk.bold('Cost Report')];
+
+  lines.push('');
+  lines.push(chalk.bold('By module'));
+  for (const mod of report.modules) {
+    const center = mod.costCenter ? chalk.dim(` [${mod.costCenter}]`) : '';
+    lines.push(
+      `  ${mod.name} (${mod.entityType})${center} ${chalk.cyan(formatAmount(mod.cost, currency))}`,
+    );
+  }
+
+  lines.push('');
+  lines.push(chalk.bold('By cost center'));
+  for (const center of report.costCenters) {
+    lines.push(
+      `  ${center.costCenter} ${chalk.cyan(formatAmount(center.cost, currency))}`,
+    );
+  }
+
+  lines.push('');
+  lines.push(`Total: ${formatAmount(report.totalCost, currency)}`);
+  if (report.unattributedCost > 0) {
+    lines.push(
+      chalk.yellow(
+        `Unattributed: ${formatAmount(report.unattributedCost, currency)}`,
+      ),
+    );
+  }
+  return lines.join('\n');
+}
+
+function runCost(
+  exports: readonly string[],
+  options: CostCommandOptions,
+): void {
+  let provider: CloudProvider | undefined;
+  if (options.provider) {
+    const parsed = CloudProviderSchema.safeParse(options.provider);
+    if (!parsed.success) {
+      reportError(
+        `Unknown provider "${options.provider}". Use aws or gcp.`,
+        'usage',
+      );
+      return;
+    }
+    provider = parsed.data;
+  }
+
+  const entities = loadEntities(resolve(options.db));
+  if (!entities) return;
+
+  try {
+    const items: CostLineItem[] = [];
+    for (const file of exports) {
+      const text = readFileSync(resolve(file), 'utf-8');
+      items.push(...parseCostExport(text, provider));
+    }
+
+    const report = buildCostReport(entities, items);
+    if (options.format === 'json') {
+      console.log(formatJson(report, true));
+    } else {
+      console.log(formatCostReport(report));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerCostCommand(program: Command): void {
+  program
+    .command('cost')
+    .description('Join AWS/GCP cost export CSVs to modules by resource tag')
+    .argument('<exports...>', 'Cost export CSV files')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (table|json)', 'table')
+    .option(
+      '--provider <provider>',
+      'Export format (aws|gcp); detected from the header when omitted',
+    )
+    .action((exports: string[], options: CostCommandOptions) => {
+      runCost(exports, options);
+    });
+}
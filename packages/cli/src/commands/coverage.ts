@@ -16,6 +16,7 @@ import chalk from 'chalk';
 import { calculateCoverage } from '@know-graph/core';
 import type { CoverageBreakdown, CoverageResult } from '@know-graph/core';
 import { formatJson } from '../utils/format.js';
+import { reportError, reportCheckFailure } from '../utils/errors.js';
 
 interface CoverageCommandOptions {
   readonly format: string;
@@ -62,6 +63,23 @@ function printTableOutput(
   );
   console.log(`  Coverage:        ${formatPercentage(result.percentage)}`);
 
+  if (result.generatedFiles > 0) {
+    const unlinked = result.unlinkedGeneratedFiles;
+    console.log(
+      `  Generated files: ${chalk.cyan(String(result.generatedFiles))} (${unlinked.length} without a generator)`,
+    );
+    for (const file of unlinked.slice(0, 10)) {
+      console.log(chalk.dim(`    ${file}`));
+    }
+    if (unlinked.length > 0) {
+      console.log(
+        chalk.dim(
+          '    Add `generates` patterns to the generator annotation to cover these',
+        ),
+      );
+    }
+  }
+
   if (!byDimension || byDimension === 'language') {
     printBreakdownTable('By Language:', result.byLanguage);
   }
@@ -84,8 +102,7 @@ function runCoverage(
   try {
     statSync(absPath);
   } catch {
-    console.error(chalk.red(`Error: Path not found: ${absPath}`));
-    process.exitCode = 1;
+    reportError(`Path not found: ${absPath}`, 'io');
     return;
   }
 
@@ -101,30 +118,22 @@ function runCoverage(
     if (options.threshold !== undefined) {
       const threshold = Number(options.threshold);
       if (Number.isNaN(threshold)) {
-        console.error(
-          chalk.red(`Error: Invalid threshold value: ${options.threshold}`),
-        );
-        process.exitCode = 1;
+        reportError(`Invalid threshold value: ${options.threshold}`, 'usage');
         return;
       }
 
       if (result.percentage < threshold) {
+        const message = `Coverage ${result.percentage}% is below threshold ${threshold}%`;
         console.error('');
-        console.error(
-          chalk.red(
-            `Coverage ${result.percentage}% is below threshold ${threshold}%`,
-          ),
-        );
-        process.exitCode = 1;
+        console.error(chalk.red(message));
+        reportCheckFailure(message, 'policy', {
+          percentage: result.percentage,
+          threshold,
+        });
       }
     }
   } catch (err) {
-    console.error(
-      chalk.red(
-        `Error: ${err instanceof Error ? err.message : String(err)}`,
-      ),
-    );
-    process.exitCode = 1;
+    reportError(err);
   }
 }
 
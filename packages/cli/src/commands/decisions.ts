@@ -0,0 +1,120 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that scans ADRs, links them to annotated code, and reports superseded governance
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, decisions, adr]
+ * context:
+ *   business_goal: Show which code still follows architecture decisions that have been replaced
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { buildDecisionReport, scanAdrDirectory } from '@know-graph/core';
+import type { DecisionReport, DecisionStatus } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface DecisionsCommandOptions {
+  readonly db: string;
+  readonly format: string;
+}
+
+function colorStatus(status: DecisionStatus): string {
+  switch (status) {
+    case 'accepted':
+      return chalk.green(status);
+    case 'superseded':
+    case 'deprecated':
+    case 'rejected':
+      return chalk.red(status);
+    default:
+      return chalk.yellow(status);
+  }
+}
+
+export function formatDecisionReport(report: DecisionReport): string {
+  if (report.decisions.length === 0) {
+    return 'No ADRs found.';
+  }
+
+  const lines: string[] = [chalk.bold('Decisions')];
+  for (const node of report.decisions) {
+    const { id, title, status } = node.decision;
+    lines.push(
+      `  ${id} ${title} [${colorStatus(status)}] ` +
+        chalk.dim(`governs ${node.governs.length}`),
+    );
+  }
+
+  if (report.superseded.length > 0) {
+    lines.push('');
+    lines.push(chalk.bold('Code governed by superseded decisions'));
+    for (const item of report.superseded) {
+      const replacement = item.replacement
+        ? ` -> ${item.replacement.id} ${item.replacement.title}`
+        : '';
+      lines.push(`  ${item.decision.id}${replacement}`);
+      for (const entity of item.governs) {
+        lines.push(
+          `    ${entity.name} ${chalk.dim(`${entity.filePath}:${entity.line}`)}`,
+        );
+      }
+    }
+  }
+
+  if (report.unknownReferences.length > 0) {
+    lines.push('');
+    lines.push(chalk.yellow('Unknown decision references'));
+    for (const ref of report.unknownReferences) {
+      lines.push(`  ${ref.decisionId} in ${ref.entity.name}`);
+    }
+  }
+
+  return lines.join('\n');
+}
+
+function runDecisions(adrDir: string, options: DecisionsCommandOptions): void {
+  const dir = resolve(adrDir);
+  if (!existsSync(dir)) {
+    reportError(`ADR directory not found at ${dir}`, 'io');
+    return;
+  }
+
+  const entities = loadEntities(resolve(options.db));
+  if (!entities) return;
+
+  try {
+    const report = buildDecisionReport(
+      entities,
+      scanAdrDirectory(dir, process.cwd()),
+    );
+    if (options.format === 'json') {
+      console.log(formatJson(report, true));
+    } else {
+      console.log(formatDecisionReport(report));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerDecisionsCommand(program: Command): void {
+  program
+    .command('decisions')
+    .description('Link ADRs to code and report superseded decisions')
+    .argument(
+      '[adr-dir]',
+      'Directory containing ADR markdown files',
+      'docs/adr',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (table|json)', 'table')
+    .action((adrDir: string, options: DecisionsCommandOptions) => {
+      runDecisions(adrDir, options);
+    });
+}
@@ -0,0 +1,112 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists what depends on a service, database, or external API from the persisted reverse index
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, dependents, graph, impact]
+ * context:
+ *   business_goal: Tell a team who they would break in the time it takes to type the question
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { hasDependents, readDependents } from '@know-graph/core';
+import type { DependentRecord } from '@know-graph/core';
+import { openDatabase } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+
+interface DependentsCommandOptions {
+  readonly db: string;
+  readonly depth?: string;
+  readonly format: string;
+}
+
+export function formatDependents(
+  name: string,
+  records: readonly DependentRecord[],
+): string {
+  if (records.length === 0) {
+    return chalk.dim(`Nothing indexed depends on ${name}.`);
+  }
+  const lines: string[] = [];
+  let target: string | undefined;
+  for (const record of records) {
+    if (record.target !== target) {
+      target = record.target;
+      const count = records.filter((r) => r.target === target).length;
+      if (lines.length > 0) lines.push('');
+      lines.push(
+        `${chalk.bold(record.targetName)} ${chalk.dim(`${count} dependent(s)`)}`,
+      );
+    }
+    const type = record.entityType ?? 'external';
+    const where = [record.filePath, record.owner].filter(Boolean).join(', ');
+    lines.push(
+      `  ${String(record.depth).padStart(2)}  ${chalk.cyan(record.sourceName)} ` +
+        chalk.dim(`(${type}) via ${record.kind}${where ? `  ${where}` : ''}`),
+    );
+  }
+  return lines.join('\n');
+}
+
+function runDependents(name: string, options: DependentsCommandOptions): void {
+  const maxDepth =
+    options.depth === undefined ? undefined : Number(options.depth);
+  if (
+    maxDepth !== undefined &&
+    (!Number.isInteger(maxDepth) || maxDepth < 1)
+  ) {
+    reportError('--depth must be a positive integer', 'usage');
+    return;
+  }
+  const dbPath = resolve(options.db);
+  if (!existsSync(dbPath)) {
+    reportError(
+      `Database not found at ${dbPath}`,
+      'io',
+      "Run 'knowgraph index' first to create the database.",
+    );
+    return;
+  }
+
+  const dbManager = openDatabase(dbPath, undefined, { readOnly: true });
+  let records: readonly DependentRecord[];
+  try {
+    if (!hasDependents(dbManager)) {
+      reportError(
+        `The index at ${dbPath} has no dependents yet`,
+        'io',
+        "Run 'knowgraph index' to build them.",
+      );
+      return;
+    }
+    records = readDependents(dbManager, name, { maxDepth });
+  } catch (err) {
+    reportError(err);
+    return;
+  } finally {
+    dbManager.close();
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(records, true));
+  } else {
+    console.log(formatDependents(name, records));
+  }
+}
+
+export function registerDependentsCommand(program: Command): void {
+  program
+    .command('dependents <name>')
+    .description('List what depends on a service, database, or external API')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--depth <n>', 'Only dependents at most this many hops away')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((name: string, options: DependentsCommandOptions) => {
+      runDependents(name, options);
+    });
+}
@@ -0,0 +1,213 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that imports ArgoCD, Spinnaker, and CSV deployment events and lists which nodes are live where
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, deployments, argocd, spinnaker]
+ * context:
+ *   business_goal: Answer whether deprecated code is still deployed to an environment
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { dirname, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  StatusSchema,
+  appendDeploymentEvents,
+  currentDeployments,
+  joinDeployments,
+  parseDeploymentEvents,
+  readDeploymentLog,
+} from '@know-graph/core';
+import type {
+  DeployedNode,
+  DeploymentEvent,
+  DeploymentSource,
+  Status,
+} from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+import { readDeploymentsConfig } from '../utils/manifest.js';
+
+const SOURCES: readonly DeploymentSource[] = ['argocd', 'spinnaker', 'csv'];
+
+interface DeploymentsImportOptions {
+  readonly config: string;
+  readonly source?: string;
+}
+
+interface DeploymentsListOptions {
+  readonly config: string;
+  readonly db: string;
+  readonly env?: string;
+  readonly status?: string;
+  readonly name?: string;
+  readonly format: string;
+}
+
+/** The deployment log for the manifest at `configPath`, resolved next to it. */
+export function deploymentLogPath(configPath: string): string {
+  return resolve(dirname(configPath), readDeploymentsConfig(configPath).path);
+}
+
+export function formatDeployedNodes(nodes: readonly DeployedNode[]): string {
+  if (nodes.length === 0) return 'No deployed nodes found.';
+  const lines: string[] = [];
+  let environment: string | undefined;
+  for (const node of nodes) {
+    if (node.environment !== environment) {
+      if (environment !== undefined) lines.push('');
+      environment = node.environment;
+      lines.push(chalk.bold(environment));
+    }
+    const status =
+      node.status === 'deprecated' ? chalk.red(` [${node.status}]`) : '';
+    const via =
+      node.service === node.name ? '' : chalk.dim(` via ${node.service}`);
+    lines.push(
+      `  ${node.name}${status} ${chalk.cyan(node.version)}${via} ` +
+        chalk.dim(node.deployedAt),
+    );
+  }
+  return lines.join('\n');
+}
+
+function runDeploymentsImport(
+  files: readonly string[],
+  options: DeploymentsImportOptions,
+): void {
+  const source = options.source as DeploymentSource | undefined;
+  if (source && !SOURCES.includes(source)) {
+    reportError(
+      `Unknown source "${options.source}". Use ${SOURCES.join(', ')}.`,
+      'usage',
+    );
+    return;
+  }
+
+  try {
+    const events: DeploymentEvent[] = [];
+    for (const file of files) {
+      const text =
+        file === '-'
+          ? readFileSync(0, 'utf-8')
+          : readFileSync(resolve(file), 'utf-8');
+      events.push(...parseDeploymentEvents(text, source));
+    }
+    const logPath = deploymentLogPath(resolve(options.config));
+    appendDeploymentEvents(logPath, events);
+    console.log(
+      chalk.green(`Imported ${events.length} deployment events to ${logPath}`),
+    );
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+function runDeploymentsList(options: DeploymentsListOptions): void {
+  let status: Status | undefined;
+  if (options.status) {
+    const parsed = StatusSchema.safeParse(options.status);
+    if (!parsed.success) {
+      reportError(
+        `Unknown status "${options.status}". Use experimental, stable, or deprecated.`,
+        'usage',
+      );
+      return;
+    }
+    status = parsed.data;
+  }
+
+  const dbPath = resolve(options.db);
+  const configPath = resolve(options.config);
+  const entities = loadEntities(dbPath, configPath);
+  if (!entities) return;
+
+  try {
+    const events = readDeploymentLog(deploymentLogPath(configPath));
+    const deployed = joinDeployments(
+      entities,
+      buildGraph(dbPath, entities, { configPath }),
+      events,
+      { environment: options.env, status, name: options.name },
+    );
+    if (options.format === 'json') {
+      console.log(formatJson(deployed, true));
+    } else {
+      console.log(formatDeployedNodes(deployed));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+function runDeploymentsCurrent(options: {
+  readonly config: string;
+  readonly format: string;
+}): void {
+  try {
+    const current = currentDeployments(
+      readDeploymentLog(deploymentLogPath(resolve(options.config))),
+    );
+    if (options.format === 'json') {
+      console.log(formatJson(current, true));
+    } else if (current.length === 0) {
+      console.log('No deployments recorded.');
+    } else {
+      for (const event of current) {
+        console.log(
+          `${chalk.bold(event.environment)} ${event.service} ` +
+            `${chalk.cyan(event.version)} ${chalk.dim(event.deployedAt)}`,
+        );
+      }
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerDeploymentsCommand(program: Command): void {
+  const deploymentsCmd = program
+    .command('deployments')
+    .description('Track which versions of which nodes are deployed where');
+
+  deploymentsCmd
+    .command('import')
+    .description(
+      'Record ArgoCD or Spinnaker webhook payloads or deployment CSVs',
+    )
+    .argument('<files...>', 'Payload or CSV files; - reads stdin')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option(
+      '--source <source>',
+      'Event format (argocd|spinnaker|csv); detected when omitted',
+    )
+    .action((files: string[], options: DeploymentsImportOptions) => {
+      runDeploymentsImport(files, options);
+    });
+
+  deploymentsCmd
+    .command('current')
+    .description('Show the version live per service and environment')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: { readonly config: string; readonly format: string }) => {
+      runDeploymentsCurrent(options);
+    });
+
+  deploymentsCmd
+    .command('list')
+    .description('List indexed nodes with the version deployed per environment')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--env <environment>', 'Only this environment')
+    .option('--status <status>', 'Only nodes with this status')
+    .option('--name <name>', 'Only nodes with this name')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: DeploymentsListOptions) => {
+      runDeploymentsList(options);
+    });
+}
@@ -0,0 +1,160 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that writes each module README's Architecture section from the graph, between marker comments
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, docsgen, readme, markdown]
+ * context:
+ *   business_goal: Keep module docs consistent with the graph without copying its facts by hand
+ *   domain: cli
+ */
+import { writeFileSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { fileChange, planReadmeUpdates } from '@know-graph/core';
+import type { DocsgenResult } from '@know-graph/core';
+import { recordAudit } from '../utils/audit.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import { formatPlan } from '../utils/plan.js';
+
+interface DocsgenCommandOptions {
+  readonly db: string;
+  readonly create?: boolean;
+  readonly check?: boolean;
+  readonly dryRun?: boolean;
+  readonly format: string;
+}
+
+export function formatDocsgenResult(
+  result: DocsgenResult,
+  check = false,
+): string {
+  const changed = result.updates.filter(
+    (update) => update.status !== 'unchanged',
+  );
+  const lines: string[] = [];
+  if (result.updates.length > 0) {
+    lines.push(
+      changed.length === 0
+        ? chalk.green(`All ${result.updates.length} README(s) are up to date`)
+        : check
+          ? chalk.yellow(`${changed.length} README(s) are out of date`)
+          : chalk.green(
+              `Updated ${changed.length} of ${result.updates.length} README(s)`,
+            ),
+    );
+  }
+  for (const update of changed) {
+    const status = check ? 'out of date' : update.status;
+    lines.push(`  ${chalk.cyan(update.path)} ${chalk.dim(status)}`);
+  }
+  if (result.skipped.length > 0) {
+    if (lines.length > 0) lines.push('');
+    lines.push(chalk.bold('Skipped'));
+    for (const skip of result.skipped) {
+      lines.push(`  ${chalk.yellow(skip.path)}: ${skip.reason}`);
+    }
+    if (result.skipped.some((skip) => skip.reason === 'no README to update')) {
+      lines.push('', chalk.dim('Pass --create to write the missing READMEs.'));
+    }
+  }
+  if (lines.length === 0) {
+    return chalk.dim('No modules are indexed, so no READMEs to generate.');
+  }
+  return lines.join('\n');
+}
+
+function runDocsgen(root: string, options: DocsgenCommandOptions): void {
+  const rootDir = resolve(root);
+  let result: DocsgenResult;
+  try {
+    const dbPath = resolve(options.db);
+    const entities = loadEntities(dbPath);
+    if (!entities) return;
+    result = planReadmeUpdates(entities, buildGraph(dbPath, entities), {
+      rootDir,
+      create: options.create,
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const changed = result.updates.filter(
+    (update) => update.status !== 'unchanged',
+  );
+  const changes = changed.flatMap(
+    (update) => fileChange(update.path, update.before, update.after) ?? [],
+  );
+  if (options.dryRun) {
+    console.log(
+      formatPlan({
+        command: 'docsgen',
+        changes,
+        totals: [`${changes.length} README(s) written`],
+      }),
+    );
+    return;
+  }
+
+  if (!options.check) {
+    try {
+      for (const update of changed) {
+        writeFileSync(join(rootDir, update.path), update.after, 'utf-8');
+      }
+    } catch (err) {
+      reportError(err);
+      return;
+    }
+  }
+
+  if (options.format === 'json') {
+    console.log(
+      formatJson(
+        {
+          updates: result.updates.map(({ path, entities, status }) => ({
+            path,
+            entities,
+            status,
+          })),
+          skipped: result.skipped,
+        },
+        true,
+      ),
+    );
+  } else {
+    console.log(formatDocsgenResult(result, options.check));
+  }
+
+  if (options.check) {
+    if (changed.length > 0) {
+      reportCheckFailure(
+        `${changed.length} README(s) with an out-of-date Architecture section`,
+        'policy',
+        { outdated: changed.map((update) => update.path) },
+      );
+    }
+  } else if (changes.length > 0) {
+    recordAudit(resolve('.knowgraph.yml'), 'docsgen', changes);
+  }
+}
+
+export function registerDocsgenCommand(program: Command): void {
+  program
+    .command('docsgen [root]')
+    .description(
+      "Generate or update the Architecture section of each module's README from the graph, between knowgraph:architecture markers",
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--create', 'Write a README for module directories without one')
+    .option('--check', 'Write nothing and exit 1 when a README is out of date')
+    .option('--dry-run', 'Show the README changes without writing them')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((root: string | undefined, options: DocsgenCommandOptions) => {
+      runDocsgen(root ?? '.', options);
+    });
+}
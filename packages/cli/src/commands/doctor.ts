@@ -0,0 +1,156 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that checks the environment and repository setup and writes a diagnostics bundle for support
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, doctor, diagnostics, support]
+ * context:
+ *   business_goal: Let users find setup problems themselves and hand support everything needed in one file
+ *   domain: cli
+ */
+import { writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { runDoctor } from '@know-graph/core';
+import type { DoctorReport, DoctorStatus } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { readIndexSetup, readParsers } from '../utils/indexing.js';
+
+interface DoctorCommandOptions {
+  readonly config?: string;
+  readonly db: string;
+  readonly exclude?: string;
+  readonly format: string;
+  readonly bundle?: string;
+}
+
+const STATUS_COLORS: Record<DoctorStatus, (text: string) => string> = {
+  ok: chalk.green,
+  warn: chalk.yellow,
+  fail: chalk.red,
+};
+
+export function formatDoctorReport(report: DoctorReport): string {
+  const env = report.environment;
+  const lines = [
+    chalk.bold('Environment'),
+    `  knowgraph ${env.knowgraphVersion}, node ${env.nodeVersion}, ` +
+      `${env.platform} ${env.arch}, ${env.gitVersion ?? 'no git'}`,
+    '',
+    chalk.bold('Checks'),
+  ];
+  const width = Math.max(...report.checks.map((check) => check.name.length));
+  for (const check of report.checks) {
+    const status = STATUS_COLORS[check.status](check.status.padEnd(4));
+    lines.push(`  ${status}  ${check.name.padEnd(width)}  ${check.message}`);
+    if (check.fix) {
+      lines.push(chalk.dim(`        ${' '.repeat(width)}  Fix: ${check.fix}`));
+    }
+  }
+  if (report.coverage.languages.length > 0) {
+    lines.push('', chalk.bold('Parser coverage'));
+    for (const language of report.coverage.languages) {
+      const counts =
+        `${language.files} files, ${language.annotatedFiles} annotated, ` +
+        `${language.entities} entities`;
+      const unparseable =
+        language.unparseable > 0
+          ? chalk.yellow(`, ${language.unparseable} unparseable`)
+          : '';
+      lines.push(
+        `  ${(language.extension || '(none)').padEnd(8)} ` +
+          `${language.parser.padEnd(12)} ${counts}${unparseable}`,
+      );
+    }
+  }
+  if (report.enrichmentCache.length > 0) {
+    lines.push('', chalk.bold('Enrichment cache'));
+    for (const cache of report.enrichmentCache) {
+      if (cache.damaged) {
+        lines.push(`  ${cache.name.padEnd(12)} ${chalk.yellow('unreadable')}`);
+        continue;
+      }
+      const expired = cache.expired > 0 ? `, ${cache.expired} expired` : '';
+      const lastRun = cache.lastRun
+        ? chalk.dim(
+            `  last run ${cache.lastRun.at}: ${cache.lastRun.cached} cached, ` +
+              `${cache.lastRun.calls} calls`,
+          )
+        : '';
+      lines.push(
+        `  ${cache.name.padEnd(12)} ${cache.entries} entries${expired}, ` +
+          `${(cache.bytes / 1024).toFixed(1)} KiB${lastRun}`,
+      );
+    }
+  }
+  return lines.join('\n');
+}
+
+function runDoctorCommand(
+  targetPath: string,
+  version: string,
+  options: DoctorCommandOptions,
+): void {
+  // Paths stay as given so the report does not leak the home directory
+  const configPath = options.config ?? join(targetPath, '.knowgraph.yml');
+  try {
+    const setup = readIndexSetup(targetPath, { exclude: options.exclude });
+    const report = runDoctor(readParsers(configPath), {
+      rootDir: targetPath,
+      configPath,
+      dbPath: options.db,
+      knowgraphVersion: version,
+      exclude: setup.exclude,
+      walk: setup.walk,
+      limits: setup.limits,
+    });
+
+    if (options.bundle) {
+      writeFileSync(options.bundle, formatJson(report, true) + '\n', 'utf-8');
+    }
+    if (options.format === 'json') {
+      console.log(formatJson(report, true));
+    } else {
+      console.log(formatDoctorReport(report));
+      if (options.bundle) {
+        console.log(
+          chalk.dim(`\nWrote diagnostics bundle to ${options.bundle}`),
+        );
+      }
+    }
+
+    const failed = report.checks.filter((check) => check.status === 'fail');
+    if (failed.length > 0) {
+      reportCheckFailure(`${failed.length} doctor check(s) failed`, 'policy', {
+        failed: failed.map((check) => check.name),
+      });
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerDoctorCommand(program: Command): void {
+  program
+    .command('doctor [path]')
+    .description(
+      'Check the manifest, index, git, annotation parsing, and enrichment cache, and suggest fixes',
+    )
+    .option('--config <path>', 'Manifest path (default: <path>/.knowgraph.yml)')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option(
+      '--exclude <patterns>',
+      'Comma-separated glob patterns to exclude from the parser scan',
+    )
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option(
+      '--bundle <file>',
+      'Also write the report as JSON to this file to share with support',
+    )
+    .action((path: string | undefined, options: DoctorCommandOptions) => {
+      runDoctorCommand(path ?? '.', program.version() ?? 'unknown', options);
+    });
+}
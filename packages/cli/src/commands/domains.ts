@@ -0,0 +1,83 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that rolls entities up by bounded context and reports cross-domain dependencies
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, domain, ddd, coupling]
+ * context:
+ *   business_goal: Show DDD teams their context boundaries and the coupling between them
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { buildDomainReport } from '@know-graph/core';
+import type { DomainReport } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+
+interface DomainsCommandOptions {
+  readonly db: string;
+  readonly format: string;
+}
+
+export function formatDomainReport(report: DomainReport): string {
+  if (report.domains.length === 0) {
+    return 'No entities indexed.';
+  }
+
+  const lines: string[] = [chalk.bold('Domains')];
+  for (const summary of report.domains) {
+    const instability =
+      summary.instability === null ? '-' : String(summary.instability);
+    lines.push(
+      `  ${chalk.bold(summary.domain)} ${summary.entityCount} entities, ` +
+        `${summary.internalEdges} internal deps, ` +
+        `Ca ${summary.afferent} / Ce ${summary.efferent}, ` +
+        `instability ${instability}`,
+    );
+    if (summary.owners.length > 0) {
+      lines.push(chalk.dim(`    owners: ${summary.owners.join(', ')}`));
+    }
+  }
+
+  lines.push('');
+  if (report.dependencies.length === 0) {
+    lines.push(chalk.green('No cross-domain dependencies.'));
+    return lines.join('\n');
+  }
+
+  lines.push(chalk.bold('Cross-domain dependencies'));
+  for (const dep of report.dependencies) {
+    lines.push(`  ${dep.from} -> ${dep.to} ${chalk.cyan(String(dep.count))}`);
+    for (const example of dep.examples) {
+      lines.push(chalk.dim(`    ${example}`));
+    }
+  }
+  return lines.join('\n');
+}
+
+function runDomains(options: DomainsCommandOptions): void {
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath);
+  if (!entities) return;
+
+  const report = buildDomainReport(buildGraph(dbPath, entities));
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else {
+    console.log(formatDomainReport(report));
+  }
+}
+
+export function registerDomainsCommand(program: Command): void {
+  program
+    .command('domains')
+    .description('Roll up entities by domain and report cross-domain coupling')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (table|json)', 'table')
+    .action((options: DomainsCommandOptions) => {
+      runDomains(options);
+    });
+}
@@ -0,0 +1,166 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that drafts annotations for unannotated symbols with a configurable language model, marked generated for review
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, draft, llm, annotations]
+ * context:
+ *   business_goal: Cut the cost of annotating an existing codebase to reviewing drafts
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  createOpenAiCompatibleModel,
+  draftAnnotations,
+} from '@know-graph/core';
+import type {
+  AnswerModel,
+  DraftedFile,
+  DraftResult,
+} from '@know-graph/core';
+import { draftedChanges, recordAudit } from '../utils/audit.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+import { readLlmConfig } from '../utils/manifest.js';
+
+interface DraftCommandOptions {
+  readonly path: string;
+  readonly config: string;
+  readonly llm?: string;
+  readonly model?: string;
+  readonly apiKeyEnv?: string;
+  readonly owner?: string;
+  readonly limit?: string;
+  readonly dryRun?: boolean;
+  readonly format: string;
+}
+
+export function formatDraftResult(result: DraftResult, dryRun = false): string {
+  const drafted = result.files.reduce((n, file) => n + file.drafts.length, 0);
+  if (result.found === 0) {
+    return chalk.green('No unannotated symbols found.');
+  }
+  const lines: string[] = [];
+  for (const file of result.files) {
+    lines.push(chalk.bold(file.filePath));
+    for (const draft of file.drafts) {
+      lines.push(`  ${chalk.cyan(`${draft.symbol.name}`)}:${draft.symbol.line}`);
+      if (dryRun) {
+        lines.push(
+          ...draft.block.split('\n').map((line) => chalk.dim(`    ${line}`)),
+        );
+      }
+    }
+  }
+  for (const skip of result.skipped) {
+    lines.push(
+      chalk.yellow(
+        `  ⚠ ${skip.symbol.filePath}:${skip.symbol.line} ${skip.symbol.name}: ${skip.reason}`,
+      ),
+    );
+  }
+  if (lines.length > 0) lines.push('');
+  const verb = dryRun ? 'Would draft' : 'Drafted';
+  lines.push(
+    `${verb} ${drafted} of ${result.found} unannotated symbol(s) in ${result.files.length} file(s)`,
+  );
+  if (drafted > 0 && !dryRun) {
+    lines.push(
+      chalk.dim(
+        'Approve or reject each draft with `knowgraph review`; exports leave drafts out until then.',
+      ),
+    );
+  }
+  return lines.join('\n');
+}
+
+function resolveModel(options: DraftCommandOptions): AnswerModel | undefined {
+  const config = readLlmConfig(resolve(options.config));
+  const baseUrl = options.llm ?? config.base_url;
+  if (!baseUrl) {
+    reportError(
+      'No language model configured',
+      'usage',
+      'Pass --llm <url> or set llm.base_url in .knowgraph.yml',
+    );
+    return undefined;
+  }
+  return createOpenAiCompatibleModel({
+    baseUrl,
+    model: options.model ?? config.model,
+    apiKey: process.env[options.apiKeyEnv ?? config.api_key_env],
+  });
+}
+
+async function runDraft(options: DraftCommandOptions): Promise<void> {
+  const limit = options.limit === undefined ? undefined : Number(options.limit);
+  if (limit !== undefined && (!Number.isInteger(limit) || limit < 1)) {
+    reportError('--limit must be a positive integer', 'usage');
+    return;
+  }
+  const model = resolveModel(options);
+  if (!model) return;
+
+  const absPath = resolve(options.path);
+  const written: DraftedFile[] = [];
+  try {
+    const result = await draftAnnotations(absPath, {
+      model,
+      limit,
+      owner: options.owner,
+      write: !options.dryRun,
+      onFile: (file) => written.push(file),
+      onSymbol:
+        options.format === 'json'
+          ? undefined
+          : (symbol) =>
+              console.error(
+                chalk.dim(`Drafting ${symbol.filePath}:${symbol.line} ${symbol.name}...`),
+              ),
+    });
+    if (options.format === 'json') {
+      console.log(formatJson(result, true));
+    } else {
+      console.log(formatDraftResult(result, options.dryRun));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+  // Files drafted before a failing model call are written too
+  if (!options.dryRun && written.length > 0) {
+    recordAudit(
+      resolve(options.config),
+      'draft',
+      draftedChanges(absPath, written),
+    );
+  }
+}
+
+export function registerDraftCommand(program: Command): void {
+  program
+    .command('draft')
+    .description(
+      'Draft annotations for unannotated symbols with a language model, marked generated for review',
+    )
+    .option('--path <path>', 'Directory or file to draft annotations in', '.')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option(
+      '--llm <url>',
+      'OpenAI-compatible API base URL (default: llm.base_url)',
+    )
+    .option('--model <name>', 'Chat model (default: llm.model, gpt-4o-mini)')
+    .option(
+      '--api-key-env <name>',
+      'Environment variable holding the API key (default: llm.api_key_env, OPENAI_API_KEY)',
+    )
+    .option('--owner <team>', 'Owner to write into every draft')
+    .option('--limit <n>', 'Draft at most this many symbols')
+    .option('--dry-run', 'Print the drafts without writing them')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action(async (options: DraftCommandOptions) => {
+      await runDraft(options);
+    });
+}
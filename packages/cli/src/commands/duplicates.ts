@@ -0,0 +1,89 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that reports clusters of entities likely implementing the same capability
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, duplicates, consolidation]
+ * context:
+ *   business_goal: Produce a consolidation-candidates report for overlapping libraries across teams
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { detectDuplicates } from '@know-graph/core';
+import type { DuplicateReport } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface DuplicatesCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly threshold: string;
+  readonly crossTeam?: boolean;
+}
+
+export function formatDuplicateReport(report: DuplicateReport): string {
+  if (report.clusters.length === 0) {
+    return `No likely duplicates among ${report.compared} entities.`;
+  }
+
+  const lines: string[] = [
+    chalk.bold(`Consolidation candidates: ${report.clusters.length} clusters`),
+  ];
+  report.clusters.forEach((cluster, index) => {
+    lines.push('');
+    const owners = cluster.owners.length > 0 ? cluster.owners.join(', ') : '-';
+    lines.push(
+      `${chalk.bold(`#${index + 1}`)} score ${cluster.score} ` +
+        chalk.dim(`owners: ${owners}`),
+    );
+    if (cluster.sharedTerms.length > 0) {
+      lines.push(chalk.dim(`  shared: ${cluster.sharedTerms.join(', ')}`));
+    }
+    for (const member of cluster.members) {
+      const owner = member.owner ?? '(no owner)';
+      lines.push(
+        `  ${member.name} (${member.entityType}) ${chalk.cyan(owner)}`,
+      );
+      lines.push(chalk.dim(`    ${member.filePath}: ${member.description}`));
+    }
+  });
+  return lines.join('\n');
+}
+
+function runDuplicates(options: DuplicatesCommandOptions): void {
+  const threshold = Number(options.threshold);
+  if (!Number.isFinite(threshold) || threshold <= 0 || threshold > 1) {
+    reportError('--threshold must be a number between 0 and 1', 'usage');
+    return;
+  }
+
+  const entities = loadEntities(resolve(options.db));
+  if (!entities) return;
+
+  const report = detectDuplicates(entities, {
+    threshold,
+    crossTeamOnly: options.crossTeam ?? false,
+  });
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else {
+    console.log(formatDuplicateReport(report));
+  }
+}
+
+export function registerDuplicatesCommand(program: Command): void {
+  program
+    .command('duplicates')
+    .description('Find entities that likely implement the same capability')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (table|json)', 'table')
+    .option('--threshold <score>', 'Minimum similarity (0-1)', '0.5')
+    .option('--cross-team', 'Only report clusters spanning several owners')
+    .action((options: DuplicatesCommandOptions) => {
+      runDuplicates(options);
+    });
+}
@@ -0,0 +1,304 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that sets annotation fields in source on every annotation a query matches, or per node from a CSV, or converts between annotation forms, with a dry-run diff
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, bulkedit, annotations, audit, csv, godoc]
+ * context:
+ *   business_goal: Replace manual find-and-replace across the codebase when many annotations need the same metadata change
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { relative, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  convertToGoDirectives,
+  createKnowgraphError,
+  editAnnotations,
+  expandAnnotations,
+  fileChange,
+  importEditCsv,
+  parseEditAssignment,
+  parseEditCsv,
+  parseEditQuery,
+} from '@know-graph/core';
+import type {
+  AuditChange,
+  EditCsvResult,
+  EditDirectiveResult,
+  EditExpandResult,
+  EditResult,
+} from '@know-graph/core';
+import { recordAudit } from '../utils/audit.js';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { formatPlan } from '../utils/plan.js';
+
+interface EditCommandOptions {
+  readonly query?: string;
+  readonly set?: readonly string[];
+  readonly csv?: string;
+  readonly expand?: boolean;
+  readonly godoc?: boolean;
+  readonly path: string;
+  readonly config: string;
+  readonly dryRun?: boolean;
+  readonly format: string;
+}
+
+/** Commander option parser that lets `--set` be given more than once. */
+function collectSet(
+  value: string,
+  previous: readonly string[] = [],
+): readonly string[] {
+  return [...previous, value];
+}
+
+type AnyEditResult =
+  | EditResult
+  | EditCsvResult
+  | EditExpandResult
+  | EditDirectiveResult;
+
+/** A rewrite of annotations from one form to another. */
+type Conversion = 'expand' | 'godoc';
+
+interface ConversionText {
+  readonly done: string;
+  readonly noun: string;
+  readonly none: string;
+  /** Why an annotation was left as it was, and the failure it reports. */
+  readonly failure: string;
+  readonly kind: 'parse' | 'policy';
+}
+
+const CONVERSION_TEXT: Readonly<Record<Conversion, ConversionText>> = {
+  expand: {
+    done: 'Expanded',
+    noun: 'compact annotation(s)',
+    none: 'No compact annotations to expand.',
+    failure: 'do not parse',
+    kind: 'parse',
+  },
+  godoc: {
+    done: 'Converted',
+    noun: 'Go annotation block(s)',
+    none: 'No Go annotation blocks to convert.',
+    failure: 'have no directive form',
+    kind: 'policy',
+  },
+};
+
+function conversionOf(options: EditCommandOptions): Conversion | undefined {
+  if (options.expand) return 'expand';
+  if (options.godoc) return 'godoc';
+  return undefined;
+}
+
+export function formatEditResult(
+  result: AnyEditResult,
+  conversion?: Conversion,
+): string {
+  if ('invalid' in result) {
+    const text = CONVERSION_TEXT[conversion ?? 'expand'];
+    return formatConversionResult(result, text);
+  }
+  const lines = result.matches.map(({ filePath, line, changed }) =>
+    changed
+      ? chalk.green(`✓ Updated ${filePath}:${line}`)
+      : chalk.dim(`- Unchanged ${filePath}:${line}`),
+  );
+  const unresolved = 'unresolved' in result ? result.unresolved : [];
+  for (const { row, node, reason } of unresolved) {
+    lines.push(chalk.red(`✗ Row ${row}: ${node} ${reason}`));
+  }
+  if (lines.length === 0) {
+    return chalk.yellow('No annotations match the query.');
+  }
+  const changed = result.matches.filter((match) => match.changed).length;
+  lines.push('');
+  lines.push(
+    `${changed} of ${result.matches.length} matching annotation(s) updated in ${result.files.length} file(s)`,
+  );
+  return lines.join('\n');
+}
+
+function formatConversionResult(
+  result: EditExpandResult | EditDirectiveResult,
+  text: ConversionText,
+): string {
+  const lines = result.matches.map(({ filePath, line }) =>
+    chalk.green(`✓ ${text.done} ${filePath}:${line}`),
+  );
+  for (const { filePath, line, message } of result.invalid) {
+    lines.push(chalk.red(`✗ ${filePath}:${line} ${message}`));
+  }
+  if (lines.length === 0) return chalk.yellow(text.none);
+  lines.push('');
+  lines.push(
+    `${result.matches.length} ${text.noun} ${text.done.toLowerCase()} in ${result.files.length} file(s)`,
+  );
+  return lines.join('\n');
+}
+
+function edit(absPath: string, options: EditCommandOptions): AnyEditResult {
+  const write = !options.dryRun;
+  const conversion = conversionOf(options);
+  if (conversion) {
+    if (options.expand && options.godoc) {
+      throw createKnowgraphError(
+        'usage',
+        'Pass either --expand or --godoc, not both',
+      );
+    }
+    if (
+      options.query !== undefined ||
+      options.set?.length ||
+      options.csv !== undefined
+    ) {
+      throw createKnowgraphError(
+        'usage',
+        `Pass --${conversion} on its own, without --query, --set, or --csv`,
+      );
+    }
+    return conversion === 'expand'
+      ? expandAnnotations(absPath, { write })
+      : convertToGoDirectives(absPath, { write });
+  }
+  if (options.csv !== undefined) {
+    if (options.query !== undefined || options.set?.length) {
+      throw createKnowgraphError(
+        'usage',
+        'Pass either --csv or --query with --set, not both',
+      );
+    }
+    const text =
+      options.csv === '-'
+        ? readFileSync(0, 'utf-8')
+        : readFileSync(resolve(options.csv), 'utf-8');
+    return importEditCsv(absPath, parseEditCsv(text), { write });
+  }
+  if (options.query === undefined) {
+    throw createKnowgraphError(
+      'usage',
+      'Nothing to edit: pass --query with --set, --csv, --expand, or --godoc',
+    );
+  }
+  const query = parseEditQuery(options.query);
+  if (!options.set?.length) {
+    throw createKnowgraphError(
+      'usage',
+      'Nothing to set: pass --set field=value',
+    );
+  }
+  return editAnnotations(absPath, query, options.set.map(parseEditAssignment), {
+    write,
+  });
+}
+
+function runEdit(options: EditCommandOptions): void {
+  const absPath = resolve(options.path);
+  let result: AnyEditResult;
+  try {
+    result = edit(absPath, options);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const conversion = conversionOf(options);
+  const text = CONVERSION_TEXT[conversion ?? 'expand'];
+  const changes = result.files.map((file) =>
+    fileChange(
+      relative('.', resolve(absPath, file.filePath)),
+      file.before,
+      file.after,
+    ),
+  );
+  if (options.dryRun) {
+    const updated = result.matches.filter((match) => match.changed).length;
+    const plan = {
+      command: 'edit',
+      changes: changes.filter(
+        (change): change is AuditChange => change !== undefined,
+      ),
+      totals: [
+        'invalid' in result
+          ? `${updated} ${text.noun} ${text.done.toLowerCase()}`
+          : `${updated} annotation(s) updated`,
+      ],
+    };
+    console.log(
+      options.format === 'json' ? formatJson(plan, true) : formatPlan(plan),
+    );
+  } else {
+    if (options.format === 'json') {
+      console.log(
+        formatJson(
+          'unresolved' in result
+            ? { matches: result.matches, unresolved: result.unresolved }
+            : 'invalid' in result
+              ? { matches: result.matches, invalid: result.invalid }
+              : result.matches,
+          true,
+        ),
+      );
+    } else {
+      console.log(formatEditResult(result, conversion));
+    }
+    recordAudit(resolve(options.config), 'edit', changes);
+  }
+
+  if ('unresolved' in result && result.unresolved.length > 0) {
+    reportCheckFailure(
+      `${result.unresolved.length} CSV row(s) name no single annotated symbol`,
+      'policy',
+      { unresolved: result.unresolved },
+    );
+  }
+  if ('invalid' in result && result.invalid.length > 0) {
+    reportCheckFailure(
+      `${result.invalid.length} ${text.noun} ${text.failure}`,
+      text.kind,
+      { invalid: result.invalid },
+    );
+  }
+}
+
+export function registerEditCommand(program: Command): void {
+  program
+    .command('edit')
+    .description(
+      'Set annotation fields in source on every annotation a query matches, or per node from a CSV, or convert annotations between forms',
+    )
+    .option(
+      '--query <query>',
+      'Annotations to edit, e.g. "tag=payments AND status=experimental"',
+    )
+    .option(
+      '--set <field=value>',
+      'Field to set, value read as YAML (repeatable)',
+      collectSet,
+    )
+    .option(
+      '--csv <file>',
+      'CSV of node, field, value rows to set instead of a query (- for stdin)',
+    )
+    .option(
+      '--expand',
+      'Rewrite compact `knowgraph:` one-line annotations as full YAML blocks',
+    )
+    .option(
+      '--godoc',
+      'Rewrite Go @knowgraph blocks as //knowgraph: directives godoc hides',
+    )
+    .option('--path <path>', 'Directory or file the annotations are in', '.')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--dry-run', 'Show how the files would change without writing')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: EditCommandOptions) => {
+      runEdit(options);
+    });
+}
@@ -0,0 +1,182 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that summarizes everything known about a symbol for the terminal or as Markdown
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, explain, onboarding]
+ * context:
+ *   business_goal: Give engineers new to a codebase everything known about a symbol in one place
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  explainEntity,
+  findSymbol,
+  formatExplanationMarkdown,
+  recentChanges,
+  scanAdrDirectory,
+} from '@know-graph/core';
+import type {
+  ExplainedNode,
+  Explanation,
+  StoredEntity,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface ExplainCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly adrDir: string;
+}
+
+function location(entity: StoredEntity): string {
+  return `${entity.filePath}:${entity.line}`;
+}
+
+function nodeLine(node: ExplainedNode): string {
+  const where = node.external ? 'external' : (node.filePath ?? '');
+  const owner = node.owner ? `, ${node.owner}` : '';
+  return `  ${node.name} ${chalk.dim(`(${node.kind}) ${where}${owner}`)}`;
+}
+
+export function formatExplanation(explanation: Explanation): string {
+  const { entity, compliance, operational } = explanation;
+  const lines = [
+    `${chalk.bold(entity.name)} ${chalk.dim(`(${entity.entityType})`)}`,
+    entity.description,
+    '',
+    `  Location: ${chalk.cyan(location(entity))}`,
+    `  Owner:    ${entity.owner ?? '-'}`,
+    `  Status:   ${entity.status ?? '-'}`,
+  ];
+  if (explanation.domain) lines.push(`  Domain:   ${explanation.domain}`);
+  if (entity.tags.length > 0) {
+    lines.push(`  Tags:     ${entity.tags.join(', ')}`);
+  }
+  if (explanation.businessGoal) {
+    lines.push(`  Goal:     ${explanation.businessGoal}`);
+  }
+
+  const section = (title: string, items: readonly string[]): void => {
+    if (items.length > 0) lines.push('', chalk.bold(title), ...items);
+  };
+  section('Depends on', explanation.dependencies.map(nodeLine));
+  section('Used by', explanation.dependents.map(nodeLine));
+  section('Compliance', [
+    ...(compliance?.regulations?.length
+      ? [`  Regulations: ${compliance.regulations.join(', ')}`]
+      : []),
+    ...(compliance?.data_sensitivity
+      ? [`  Data sensitivity: ${compliance.data_sensitivity}`]
+      : []),
+    ...(compliance?.audit_requirements?.length
+      ? [`  Audit requirements: ${compliance.audit_requirements.join(', ')}`]
+      : []),
+  ]);
+  section('Operations', [
+    ...(operational?.sla ? [`  SLA: ${operational.sla}`] : []),
+    ...(operational?.on_call_team
+      ? [`  On call: ${operational.on_call_team}`]
+      : []),
+  ]);
+  section(
+    'Links',
+    explanation.links.map(
+      (link) =>
+        `  ${link.title ?? link.type ?? 'link'} ${chalk.cyan(link.url)}`,
+    ),
+  );
+  section(
+    'Decisions',
+    explanation.decisions.map((decision) =>
+      decision.record
+        ? `  ${decision.id} ${decision.record.title} ${chalk.dim(`[${decision.record.status}]`)}`
+        : `  ${decision.id} ${chalk.yellow('(no ADR found)')}`,
+    ),
+  );
+  section(
+    'Recent changes',
+    recentChanges(explanation).map(
+      (change) =>
+        `  ${change.commit.slice(0, 7)}${change.subject ? ` ${change.subject}` : ''} by ${change.author} on ${change.date}`,
+    ),
+  );
+  section(
+    'Contributors',
+    explanation.contributors.map(
+      (c) =>
+        `  ${c.author} ${chalk.dim(`${c.commits} commit${c.commits === 1 ? '' : 's'}, last on ${c.last_modified}`)}`,
+    ),
+  );
+  return lines.join('\n');
+}
+
+function runExplain(symbol: string, options: ExplainCommandOptions): void {
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath);
+  if (!entities) return;
+
+  const matches = findSymbol(entities, symbol);
+  if (matches.length === 0) {
+    reportError(
+      `No entity matches "${symbol}"`,
+      'usage',
+      `Search with 'knowgraph query ${symbol}'.`,
+    );
+    return;
+  }
+  if (matches.length > 1) {
+    for (const entity of matches) {
+      console.error(`  ${location(entity)} ${entity.name}`);
+    }
+    reportError(
+      `"${symbol}" matches ${matches.length} entities`,
+      'usage',
+      'Pass one of the locations above, such as src/file.ts:12.',
+    );
+    return;
+  }
+
+  try {
+    const adrDir = resolve(options.adrDir);
+    const decisions = existsSync(adrDir)
+      ? scanAdrDirectory(adrDir, process.cwd())
+      : undefined;
+    const explanation = explainEntity(
+      matches[0],
+      buildGraph(dbPath, entities),
+      { decisions },
+    );
+    if (options.format === 'json') {
+      console.log(formatJson(explanation, true));
+    } else if (options.format === 'markdown') {
+      process.stdout.write(formatExplanationMarkdown(explanation));
+    } else {
+      console.log(formatExplanation(explanation));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerExplainCommand(program: Command): void {
+  program
+    .command('explain <symbol>')
+    .description('Summarize everything known about a symbol')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|markdown|json)', 'text')
+    .option(
+      '--adr-dir <dir>',
+      'Directory of ADRs to resolve decisions against',
+      'docs/adr',
+    )
+    .action((symbol: string, options: ExplainCommandOptions) => {
+      runExplain(symbol, options);
+    });
+}
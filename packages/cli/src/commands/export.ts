@@ -1,29 +1,102 @@
 /**
  * @knowgraph
  * type: module
- * description: CLI command to export knowledge graph as .cursorrules or markdown
+ * description: CLI command to export the knowledge graph in any format from the exporter registry
  * owner: knowgraph-cli
  * status: experimental
- * tags: [cli, command, export, cursorrules]
+ * tags: [cli, command, export, cursorrules, streaming, redaction]
  * context:
  *   business_goal: Enable AI coding tools beyond MCP to leverage KnowGraph data
  *   domain: cli
  */
-import { resolve } from 'node:path';
-import { existsSync, writeFileSync } from 'node:fs';
+import { relative, resolve } from 'node:path';
+import { existsSync } from 'node:fs';
 import type { Command } from 'commander';
 import chalk from 'chalk';
 import {
-  createDatabaseManager,
+  buildExportGraph,
+  compareEntities,
+  compareStrings,
+  createCancellationCheck,
+  createDefaultExporterRegistry,
+  createExporterRegistry,
+  createPhaseTimer,
+  createPluginExporter,
   createQueryEngine,
+  entityDiagrams,
+  entityInScope,
+  fileChange,
+  inView,
+  isDiagramImage,
+  isPendingReview,
+  previewRedaction,
+  recordPhaseTimings,
+  resolveView,
+  timePhase,
 } from '@know-graph/core';
-import type { StoredEntity } from '@know-graph/core';
-
-type ExportFormat = 'cursorrules' | 'markdown';
+import type {
+  ByteSink,
+  CancellationOptions,
+  Exporter,
+  ExporterRegistry,
+  ExportOptions,
+  ExportStats,
+  PhaseTimer,
+  PluginConfig,
+  Redactor,
+  ScanScope,
+  StoredEntity,
+  TextSink,
+  ViewProfile,
+} from '@know-graph/core';
+import { readPlugins, readViews } from '../utils/manifest.js';
+import {
+  createEntityPipeline,
+  resolveExportOptions,
+} from '../utils/export-options.js';
+import {
+  formatPruneReport,
+  formatRedactionPreview,
+  planFile,
+  renderExport,
+  writeToFile,
+} from '../utils/export-plan.js';
+import { formatPlan } from '../utils/plan.js';
+import { reportProfile, startProfile } from '../utils/profile.js';
+import { flushTelemetry, startTelemetry } from '../utils/telemetry.js';
+import { reportError } from '../utils/errors.js';
+import { openDatabase } from '../utils/db.js';
+import { checkEnvironment } from '../utils/environments.js';
+import { collectScope, parseScopes } from '../utils/scope.js';
+import { readGraphFile } from './stitch.js';
+
+type ContextFormat = 'cursorrules' | 'markdown';
 
 interface ExportCommandOptions {
-  readonly format: ExportFormat;
+  readonly format: string;
+  readonly listFormats?: boolean;
   readonly output?: string;
+  readonly locale?: string;
+  readonly timeout?: string;
+  readonly profile?: string;
+  readonly redact?: string;
+  readonly view?: string;
+  readonly preview?: boolean;
+  readonly dryRun?: boolean;
+  readonly scope?: readonly string[];
+  readonly provenance?: string;
+  readonly minConfidence?: string;
+  readonly protocol?: string;
+  readonly criticality?: string;
+  readonly namespace?: string;
+  readonly collapseFunctions?: boolean;
+  readonly minSignificance?: string;
+  readonly maxNodes?: string;
+  readonly base?: string;
+  readonly includeDrafts?: boolean;
+  readonly env?: string;
+  readonly asOf?: string;
+  readonly chunkTokens?: string;
 }
 
 interface OwnerGroup {
@@ -65,7 +138,7 @@ function groupByOwner(
   }
 
   // Sort owner groups alphabetically
-  groups.sort((a, b) => a.owner.localeCompare(b.owner));
+  groups.sort((a, b) => compareStrings(a.owner, b.owner));
 
   // Add unowned group at the end if it exists
   const unowned = ownerMap.get('');
@@ -91,7 +164,7 @@ function groupByEntityType(
     groups.push({ entityType, entities: groupEntities });
   }
 
-  return groups.sort((a, b) => a.entityType.localeCompare(b.entityType));
+  return groups.sort((a, b) => compareStrings(a.entityType, b.entityType));
 }
 
 function formatEntityLine(entity: StoredEntity): string {
@@ -117,10 +190,18 @@ function formatEntityDetails(entity: StoredEntity): string {
     lines.push(`**Business Goal:** ${businessGoal}`);
   }
 
+  // Images show inline; Excalidraw scenes can only be linked
+  const diagrams = entityDiagrams(entity).map((path) =>
+    isDiagramImage(path) ? `![${path}](${path})` : `[${path}](${path})`,
+  );
+  if (diagrams.length > 0) {
+    lines.push(`**Diagrams:** ${diagrams.join(' ')}`);
+  }
+
   return lines.join('\n');
 }
 
-function getFormatHeader(format: ExportFormat): string {
+function getFormatHeader(format: ContextFormat): string {
   if (format === 'markdown') {
     return [
       '# Project Knowledge Graph',
@@ -138,21 +219,34 @@ function getFormatHeader(format: ExportFormat): string {
   ].join('\n');
 }
 
-export function formatExport(
-  entities: readonly StoredEntity[],
-  format: ExportFormat,
-): string {
-  const sections: string[] = [];
+/**
+ * Write entities as a context file to `sink`, one section at a time.
+ * Entities are ordered by location first, so the output is byte-identical
+ * for the same index regardless of the order they are passed in.
+ */
+export function writeExport(
+  input: readonly StoredEntity[],
+  format: ContextFormat,
+  sink: TextSink,
+  cancellation: CancellationOptions = {},
+): void {
+  const checkCancelled = createCancellationCheck('Export', cancellation);
+  const entities = [...input].sort(compareEntities);
+  let first = true;
+  const emit = (section: string): void => {
+    sink.write(first ? section : `\n\n${section}`);
+    first = false;
+  };
 
   // Header
-  sections.push(getFormatHeader(format));
+  emit(getFormatHeader(format));
 
   if (entities.length === 0) {
-    sections.push('## Architecture Overview');
-    sections.push(
+    emit('## Architecture Overview');
+    emit(
       'This project contains 0 annotated code entities. Run `knowgraph index .` to populate the knowledge graph.',
     );
-    return sections.join('\n\n');
+    return;
   }
 
   // Architecture Overview
@@ -160,16 +254,17 @@ export function formatExport(
   const namedOwners = ownerGroups.filter((g) => g.owner !== '');
   const ownerCount = namedOwners.length;
 
-  sections.push('## Architecture Overview');
-  sections.push(
+  emit('## Architecture Overview');
+  emit(
     `This project contains ${entities.length} annotated code entities across ${ownerCount} owners.`,
   );
 
   // Code Ownership section
-  sections.push('## Code Ownership');
+  emit('## Code Ownership');
 
   for (const group of ownerGroups) {
     if (group.owner === '') continue;
+    checkCancelled();
 
     const ownerLines: string[] = [];
     ownerLines.push(`### ${group.owner}`);
@@ -181,7 +276,7 @@ export function formatExport(
       }
     }
 
-    sections.push(ownerLines.join('\n'));
+    emit(ownerLines.join('\n'));
   }
 
   // Unowned entities
@@ -192,67 +287,302 @@ export function formatExport(
     for (const entity of unownedGroup.entities) {
       unownedLines.push(formatEntityLine(entity));
     }
-    sections.push(unownedLines.join('\n'));
+    emit(unownedLines.join('\n'));
   }
 
   // Entity Details section
-  sections.push('## Entity Details');
+  emit('## Entity Details');
+
+  for (const entity of entities) {
+    checkCancelled();
+    emit(formatEntityDetails(entity));
+  }
+}
+
+/**
+ * Render entities as a context file in memory. See `writeExport`.
+ */
+export function formatExport(
+  input: readonly StoredEntity[],
+  format: ContextFormat,
+  cancellation: CancellationOptions = {},
+): string {
+  const chunks: string[] = [];
+  writeExport(input, format, { write: (c) => chunks.push(c) }, cancellation);
+  return chunks.join('');
+}
+
+function* mapEntities(
+  entities: Iterable<StoredEntity>,
+  fn: Redactor,
+): Generator<StoredEntity> {
+  for (const entity of entities) yield fn(entity);
+}
+
+/** Ids of the entities in scope, read before redaction can hide paths. */
+function scopedIds(
+  entities: Iterable<StoredEntity>,
+  scopes: readonly ScanScope[],
+): ReadonlySet<string> {
+  const ids = new Set<string>();
+  for (const entity of entities) {
+    if (entityInScope(entity, scopes)) ids.add(entity.id);
+  }
+  return ids;
+}
+
+/** `entities` without generated annotations nobody has approved yet. */
+function* approvedEntities(
+  entities: Iterable<StoredEntity>,
+): Generator<StoredEntity> {
+  for (const entity of entities) {
+    if (!isPendingReview(entity.metadata)) yield entity;
+  }
+}
+
+/** `entities` of the types `view` shows. */
+function* viewEntities(
+  entities: Iterable<StoredEntity>,
+  view: ViewProfile,
+): Generator<StoredEntity> {
+  for (const entity of entities) {
+    if (inView(view, entity)) yield entity;
+  }
+}
 
+function* filterEntities(
+  entities: Iterable<StoredEntity>,
+  inScope: ReadonlySet<string>,
+): Generator<StoredEntity> {
   for (const entity of entities) {
-    sections.push(formatEntityDetails(entity));
+    if (inScope.has(entity.id)) yield entity;
   }
+}
+
+/**
+ * `entities` without those whose graph nodes `options.prune` leaves out, so
+ * context files keep to the same budget as graph exports.
+ */
+function pruneEntities(
+  entities: readonly StoredEntity[],
+  options: ExportOptions,
+): Pick<ExportStats, 'pruned'> & {
+  readonly entities: readonly StoredEntity[];
+} {
+  if (!options.prune) return { entities };
+  const { graph, pruned } = buildExportGraph(() => entities, {
+    ...options,
+    namespace: undefined,
+    submodules: undefined,
+  });
+  const kept = new Set(graph.nodes.map((node) => node.id));
+  return {
+    entities: entities.filter((entity) => kept.has(entity.id)),
+    pruned,
+  };
+}
+
+export function createContextExporter(format: ContextFormat): Exporter {
+  return {
+    name: format,
+    description:
+      format === 'cursorrules'
+        ? 'Context file for Cursor and other AI coding tools'
+        : 'Markdown overview of owners and entities',
+    defaultOutput: format === 'cursorrules' ? '.cursorrules' : 'CODEBASE.md',
+    localized: true,
+    export(entities, sink, options) {
+      const { entities: all, pruned } = timePhase(
+        options.profiler,
+        'build',
+        () => pruneEntities([...entities()], options),
+      );
+      timePhase(options.profiler, 'export', () =>
+        writeExport(all, format, sink, options),
+      );
+      return { nodes: all.length, pruned };
+    },
+  };
+}
 
-  return sections.join('\n\n');
+/**
+ * Every format `knowgraph export` accepts: the context files, the core
+ * graph formats, and the `formats` of configured plugins, in that order.
+ * Plugins cannot replace a built-in format.
+ */
+export function createExportRegistry(
+  plugins: readonly PluginConfig[],
+): ExporterRegistry {
+  const registry = createExporterRegistry();
+  registry.register(createContextExporter('cursorrules'));
+  registry.register(createContextExporter('markdown'));
+  for (const exporter of createDefaultExporterRegistry().list()) {
+    registry.register(exporter);
+  }
+  for (const plugin of plugins) {
+    for (const format of plugin.formats ?? []) {
+      registry.register(createPluginExporter(plugin, format));
+    }
+  }
+  return registry;
 }
 
-function getDefaultOutputFile(format: ExportFormat): string {
-  return format === 'cursorrules' ? '.cursorrules' : 'CODEBASE.md';
+export function formatExporterList(exporters: readonly Exporter[]): string {
+  const width = Math.max(...exporters.map((e) => e.name.length));
+  return exporters
+    .map(
+      (e) =>
+        `${chalk.bold(e.name.padEnd(width))}  ${e.description} ${chalk.dim(`(${e.defaultOutput})`)}`,
+    )
+    .join('\n');
 }
 
-function runExport(
+function exportIndex(
   targetPath: string,
   options: ExportCommandOptions,
+  profiler?: PhaseTimer,
 ): void {
   const absPath = resolve(targetPath);
   const dbPath = resolve(absPath, '.knowgraph', 'knowgraph.db');
+  const configPath = resolve(absPath, '.knowgraph.yml');
+  const registry = createExportRegistry(readPlugins(configPath));
+
+  if (options.listFormats) {
+    console.log(formatExporterList(registry.list()));
+    return;
+  }
 
   if (!existsSync(dbPath)) {
-    console.error(
-      chalk.red(
-        `Error: Database not found at ${dbPath}. Run 'knowgraph index ${targetPath}' first.`,
-      ),
+    reportError(
+      `Database not found at ${dbPath}. Run 'knowgraph index ${targetPath}' first.`,
+      'io',
     );
-    process.exitCode = 1;
     return;
   }
 
-  const format = options.format;
-  if (format !== 'cursorrules' && format !== 'markdown') {
-    console.error(
-      chalk.red(
-        `Error: Invalid format '${format}'. Use 'cursorrules' or 'markdown'.`,
-      ),
+  const exporter = registry.get(options.format);
+  if (!exporter) {
+    const formats = registry.list().map((e) => `'${e.name}'`);
+    reportError(
+      `Invalid format '${options.format}'. Use ${formats.join(', ')}.`,
+      'usage',
     );
-    process.exitCode = 1;
+    return;
+  }
+
+  if (options.preview && !options.redact) {
+    reportError('--preview needs a profile to preview: add --redact', 'usage');
     return;
   }
 
   try {
-    const dbManager = createDatabaseManager(dbPath);
+    const scopes = parseScopes(options.scope);
+    const exportOptions: ExportOptions = {
+      ...resolveExportOptions(absPath, configPath, options),
+      base: options.base ? readGraphFile(resolve(options.base)) : undefined,
+      profiler,
+    };
+    const dbManager = openDatabase(dbPath, configPath, { readOnly: true });
     try {
       const queryEngine = createQueryEngine(dbManager);
-      const result = queryEngine.search({ query: '', limit: 10000 });
-      const entities = result.entities;
-
-      const content = formatExport(entities, format);
-
+      checkEnvironment(options.env, queryEngine.iterateAll());
+      const view = options.view
+        ? resolveView(options.view, readViews(configPath))
+        : undefined;
+      // Drafts are not authoritative until `knowgraph review approve`
+      const stored = (): Iterable<StoredEntity> =>
+        options.includeDrafts
+          ? queryEngine.iterateAll()
+          : approvedEntities(queryEngine.iterateAll());
+      const entities = (): Iterable<StoredEntity> =>
+        view ? viewEntities(stored(), view) : stored();
+      // Graph formats stub out-of-scope neighbors; others just drop them
+      const inScope =
+        scopes.length > 0 ? scopedIds(entities(), scopes) : undefined;
+      const source = (): Iterable<StoredEntity> =>
+        inScope && !exporter.scoped
+          ? filterEntities(entities(), inScope)
+          : entities();
       const outputFile = resolve(
         absPath,
-        options.output ?? getDefaultOutputFile(format),
+        options.output ?? exporter.defaultOutput,
+      );
+      const { profile, shape, prepare } = createEntityPipeline(
+        configPath,
+        exporter,
+        options,
+        view,
       );
-      writeFileSync(outputFile, content, 'utf-8');
+      const applied = [
+        ...(options.view ? [`view: ${options.view}`] : []),
+        ...(options.redact ? [`redacted: ${options.redact}`] : []),
+      ];
+      const notes =
+        applied.length > 0 ? chalk.dim(` (${applied.join(', ')})`) : '';
+
+      const writeWith =
+        (fn: Redactor) =>
+        (sink: ByteSink): ExportStats =>
+          exporter.export(() => mapEntities(source(), fn), sink, {
+            ...exportOptions,
+            inScope: exporter.scoped ? inScope : undefined,
+          });
+      const write = writeWith(prepare);
+
+      if (profile && options.preview) {
+        const preview = previewRedaction(
+          mapEntities(
+            inScope
+              ? filterEntities(entities(), inScope)
+              : source(),
+            shape,
+          ),
+          profile,
+        );
+        const before = renderExport(writeWith(shape));
+        const after = renderExport(write);
+        const change =
+          before === undefined
+            ? undefined
+            : fileChange(relative(absPath, outputFile), before, after);
+        console.log(
+          formatRedactionPreview(preview, change, before === undefined),
+        );
+        return;
+      }
+
+      if (options.dryRun) {
+        const { result, change } = planFile(
+          outputFile,
+          relative(absPath, outputFile),
+          write,
+        );
+        const edges =
+          result.edges !== undefined ? [`${result.edges} edge(s)`] : [];
+        const pruned = result.pruned ? [`${result.pruned.length} pruned`] : [];
+        console.log(
+          formatPlan({
+            command: `export --format ${exporter.name}`,
+            changes: change ? [change] : [],
+            totals: [`${result.nodes} node(s)`, ...edges, ...pruned],
+          }),
+        );
+        if (result.pruned?.length) {
+          console.log(formatPruneReport(result.pruned));
+        }
+        return;
+      }
 
-      if (entities.length === 0) {
+      const stats = writeToFile(outputFile, write);
+      if (stats.pruned?.length) console.log(formatPruneReport(stats.pruned));
+
+      if (stats.edges !== undefined) {
+        console.log(
+          chalk.green(
+            `Exported ${stats.nodes} nodes and ${stats.edges} edges to ${outputFile}`,
+          ) + notes,
+        );
+      } else if (stats.nodes === 0) {
         console.log(
           chalk.yellow(
             `Warning: No entities found in the index. Exported empty template to ${outputFile}`,
@@ -260,37 +590,158 @@ function runExport(
         );
       } else {
         console.log(
-          chalk.green(
-            `Exported ${entities.length} entities to ${outputFile}`,
-          ),
+          chalk.green(`Exported ${stats.nodes} entities to ${outputFile}`) +
+            notes,
         );
       }
     } finally {
       dbManager.close();
     }
   } catch (err) {
-    console.error(
-      chalk.red(
-        `Error: ${err instanceof Error ? err.message : String(err)}`,
-      ),
+    reportError(err);
+  }
+}
+
+async function runExport(
+  targetPath: string,
+  options: ExportCommandOptions,
+  version: string,
+): Promise<void> {
+  const telemetry = startTelemetry(
+    resolve(targetPath, '.knowgraph.yml'),
+    version,
+  );
+  const capture = options.profile
+    ? await startProfile(resolve(options.profile))
+    : undefined;
+  try {
+    if (!telemetry) {
+      exportIndex(targetPath, options, capture?.timer);
+      return;
+    }
+    const profiler = capture?.timer ?? createPhaseTimer();
+    telemetry.trace(
+      'knowgraph.export',
+      { attributes: { 'knowgraph.export.format': options.format } },
+      (span) => {
+        try {
+          exportIndex(targetPath, options, profiler);
+        } finally {
+          // Failures are reported rather than thrown, leaving the exit code
+          span.setAttributes({
+            'knowgraph.failed': Boolean(process.exitCode),
+          });
+          recordPhaseTimings(telemetry, profiler.timings(), span);
+        }
+      },
     );
-    process.exitCode = 1;
+  } finally {
+    if (capture) await reportProfile(capture);
+    await flushTelemetry();
   }
 }
 
 export function registerExportCommand(program: Command): void {
+  // From the registry, so a new built-in format cannot be left out
+  const formats = createExportRegistry([])
+    .list()
+    .map((exporter) => exporter.name)
+    .join('|');
   program
     .command('export [path]')
     .description(
-      'Export knowledge graph as .cursorrules or markdown file',
+      'Export knowledge graph as .cursorrules, markdown, or a dependency graph',
     )
     .option(
       '--format <format>',
-      'Output format (cursorrules|markdown)',
+      `Output format (${formats}, or a plugin format)`,
       'cursorrules',
     )
+    .option('--list-formats', 'List available formats and exit')
     .option('--output <file>', 'Output file path')
-    .action((path: string | undefined, opts: ExportCommandOptions) => {
-      runExport(path ?? '.', opts);
+    .option(
+      '--locale <code>',
+      'Locale for descriptions and business goals (default: i18n.default_locale)',
+    )
+    .option(
+      '--timeout <ms>',
+      'Abort the export after this many milliseconds (default: timeouts.export_ms)',
+    )
+    .option(
+      '--profile <dir>',
+      'Write CPU and heap profiles to <dir> and print phase timings',
+    )
+    .option(
+      '--redact <profile>',
+      'Strip or hash sensitive fields with a redaction profile (e.g. vendor)',
+    )
+    .option(
+      '--view <name>',
+      'Export only the types and fields of a view (e.g. compliance, sre)',
+    )
+    .option(
+      '--preview',
+      'With --redact, show what each rule redacts and the diff from the unredacted export',
+    )
+    .option('--dry-run', 'Show how the output file would change')
+    .option(
+      '--scope <scope>',
+      'Export only path=<dir> or tag=<tag>; repeat to widen',
+      collectScope,
+    )
+    .option(
+      '--provenance <list>',
+      'Keep only graph edges with these provenances (e.g. declared,import)',
+    )
+    .option(
+      '--min-confidence <n>',
+      'Keep only graph edges with at least this confidence, from 0 to 1',
+    )
+    .option(
+      '--protocol <list>',
+      'Keep only dependencies annotated with these protocols (e.g. grpc,http)',
+    )
+    .option(
+      '--criticality <list>',
+      'Keep only dependencies annotated with these criticalities (e.g. critical)',
+    )
+    .option(
+      '--namespace <org/repo>',
+      'Prefix graph node ids with this namespace (default: namespace)',
+    )
+    .option(
+      '--collapse-functions',
+      'Fold functions nothing depends on into the module in their file',
+    )
+    .option(
+      '--min-significance <n>',
+      'Leave out nodes with fewer edges than this (default: prune.min_significance)',
+    )
+    .option(
+      '--max-nodes <n>',
+      'Then leave out the least connected nodes beyond this many (default: prune.max_nodes)',
+    )
+    .option(
+      '--base <graph>',
+      'Graph export the patch format writes the changes from (.json or .kgs)',
+    )
+    .option(
+      '--chunk-tokens <n>',
+      'Tokens a chunk may hold before its module is split, for the chunks format (default: 1000)',
+    )
+    .option(
+      '--include-drafts',
+      'Also export generated annotations that are awaiting review',
+    )
+    .option(
+      '--env <environment>',
+      'Only the dependencies declared for this environment (e.g. prod)',
+    )
+    .option(
+      '--as-of <date>',
+      'Export the owners and dependencies as they stood on this date',
+    )
+    .action(async (path: string | undefined, opts: ExportCommandOptions) => {
+      await runExport(path ?? '.', opts, program.version() ?? 'unknown');
     });
 }
@@ -0,0 +1,173 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists annotations past or near their review deadline and sends the reminder digest to alert sinks
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, freshness, review, sla, digest]
+ * context:
+ *   business_goal: Keep annotations on revenue-critical code trustworthy by making someone look at them on a schedule
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  DEFAULT_REMIND_DAYS,
+  buildFreshnessReport,
+  describeDue,
+  freshnessDigest,
+} from '@know-graph/core';
+import type {
+  AlertSinkConfig,
+  FreshnessEntry,
+  FreshnessReport,
+} from '@know-graph/core';
+import { loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { notifyAlertSinks } from '../utils/history.js';
+import { readFreshnessConfig } from '../utils/manifest.js';
+
+const SINK_TYPES: readonly AlertSinkConfig['type'][] = [
+  'webhook',
+  'slack',
+  'teams',
+  'email',
+  'file',
+];
+
+interface FreshnessCommandOptions {
+  readonly config: string;
+  readonly db: string;
+  readonly remindDays?: string;
+  readonly format: string;
+  readonly all?: boolean;
+  readonly notify?: string;
+  readonly check?: boolean;
+}
+
+function entryLine(entry: FreshnessEntry): string {
+  const impact = entry.revenueImpact ?? 'unset';
+  const owner = entry.owner ? `, ${entry.owner}` : '';
+  const due = describeDue(entry);
+  const when = entry.state === 'overdue' ? chalk.red(due) : due;
+  return `  ${entry.name} ${chalk.dim(`${entry.filePath} (${impact}${owner})`)} ${when}`;
+}
+
+export function formatFreshnessReport(
+  report: FreshnessReport,
+  all = false,
+): string {
+  if (report.entries.length === 0) {
+    return 'No entity has a review deadline; set freshness.deadlines in .knowgraph.yml.';
+  }
+  const section = (
+    title: string,
+    entries: readonly FreshnessEntry[],
+  ): readonly string[] =>
+    entries.length > 0
+      ? ['', chalk.bold(title), ...entries.map(entryLine)]
+      : [];
+  const of = (state: FreshnessEntry['state']) =>
+    report.entries.filter((entry) => entry.state === state);
+  const lines = [
+    report.overdue > 0
+      ? chalk.red(`${report.overdue} annotations are overdue for review`)
+      : chalk.green('No annotation is overdue for review.'),
+    ...section('Overdue', of('overdue')),
+    ...section('Due soon', of('due-soon')),
+    ...(all ? section('Current', of('current')) : []),
+  ];
+  return lines.join('\n');
+}
+
+/** `--notify` as sink types; undefined after reporting an unknown one. */
+function parseSinkTypes(
+  value: string,
+): readonly AlertSinkConfig['type'][] | undefined {
+  const types = value.split(',').map((type) => type.trim());
+  const unknown = types.filter(
+    (type) => !SINK_TYPES.includes(type as AlertSinkConfig['type']),
+  );
+  if (unknown.length > 0) {
+    reportError(
+      `Unknown sink type "${unknown[0]}". Use ${SINK_TYPES.join(', ')}.`,
+      'usage',
+    );
+    return undefined;
+  }
+  return types as AlertSinkConfig['type'][];
+}
+
+async function runFreshness(options: FreshnessCommandOptions): Promise<void> {
+  const configPath = resolve(options.config);
+  const config = readFreshnessConfig(configPath);
+  const remindDays = Number(
+    options.remindDays ?? config?.remindDays ?? DEFAULT_REMIND_DAYS,
+  );
+  if (!Number.isInteger(remindDays) || remindDays < 0) {
+    reportError('--remind-days must be a whole number of days', 'usage');
+    return;
+  }
+  const sinks = options.notify ? parseSinkTypes(options.notify) : [];
+  if (!sinks) return;
+
+  const entities = loadEntities(resolve(options.db), configPath);
+  if (!entities) return;
+
+  const report = buildFreshnessReport(entities, {
+    deadlines: config?.deadlines,
+    remindDays,
+  });
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else {
+    console.log(formatFreshnessReport(report, options.all));
+  }
+
+  if (sinks.length > 0 && report.overdue + report.dueSoon > 0) {
+    try {
+      await notifyAlertSinks(configPath, sinks, freshnessDigest(report));
+    } catch (err) {
+      reportError(err);
+      return;
+    }
+  }
+
+  if (options.check && report.overdue > 0) {
+    reportCheckFailure(
+      `${report.overdue} annotations are overdue for review`,
+      'policy',
+      {
+        entities: report.entries
+          .filter((entry) => entry.state === 'overdue')
+          .map((entry) => entry.name),
+      },
+    );
+  }
+}
+
+export function registerFreshnessCommand(program: Command): void {
+  program
+    .command('freshness')
+    .description(
+      'List annotations past or near their review deadline and send reminders',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option(
+      '--remind-days <n>',
+      'Days before a deadline a node counts as due soon (default: freshness.remind_days, or 30)',
+    )
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--all', 'Also list nodes whose review is current')
+    .option(
+      '--notify <sinks>',
+      'Send the digest to these history.sinks types, comma-separated (webhook|slack|teams|email|file)',
+    )
+    .option('--check', 'Exit with code 1 if a review is overdue')
+    .action(async (options: FreshnessCommandOptions) => {
+      await runFreshness(options);
+    });
+}
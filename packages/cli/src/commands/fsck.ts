@@ -0,0 +1,188 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that checks the local index against a fresh scan of source and repairs divergence
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, fsck, index, consistency]
+ * context:
+ *   business_goal: Catch a local index that silently disagrees with the code before anyone trusts its answers
+ *   domain: cli
+ */
+import { existsSync, statSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  checkIndex,
+  readIndexedScopes,
+  repairIndex,
+} from '@know-graph/core';
+import type {
+  FsckIssue,
+  FsckRepair,
+  FsckReport,
+  ScanScope,
+} from '@know-graph/core';
+import { openDatabase } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { indexInto, readIndexSetup } from '../utils/indexing.js';
+
+interface FsckCommandOptions {
+  readonly db: string;
+  readonly exclude?: string;
+  readonly repair?: boolean;
+  readonly format: string;
+}
+
+/** One line per issue: its kind, what it is about, and what is wrong. */
+export function formatFsckIssues(issues: readonly FsckIssue[]): string {
+  const width = Math.max(...issues.map((found) => found.kind.length));
+  return issues
+    .map((found) => {
+      const line = `  ${found.kind.padEnd(width)}  ${found.subject}: ${found.message}`;
+      return found.repairable ? chalk.yellow(line) : chalk.red(line);
+    })
+    .join('\n');
+}
+
+function check(
+  rootDir: string,
+  dbPath: string,
+  options: FsckCommandOptions,
+): FsckReport {
+  const setup = readIndexSetup(rootDir, { exclude: options.exclude });
+  const dbManager = openDatabase(dbPath, resolve(rootDir, '.knowgraph.yml'), {
+    readOnly: true,
+  });
+  try {
+    return checkIndex(dbManager, setup.parserRegistry, {
+      rootDir,
+      exclude: setup.exclude,
+      configHash: setup.configHash,
+      ...setup.walk,
+      ...setup.limits,
+    });
+  } finally {
+    dbManager.close();
+  }
+}
+
+/**
+ * Clear what `report` found diverged, then re-index incrementally with the
+ * scopes the index was built with, so only cleared and unindexed files
+ * are parsed again.
+ */
+function repair(
+  rootDir: string,
+  dbPath: string,
+  options: FsckCommandOptions,
+  report: FsckReport,
+): FsckRepair {
+  const dbManager = openDatabase(dbPath, resolve(rootDir, '.knowgraph.yml'));
+  let repaired: FsckRepair;
+  let scopes: readonly ScanScope[];
+  try {
+    scopes = readIndexedScopes(dbManager);
+    repaired = repairIndex(dbManager, report);
+  } finally {
+    dbManager.close();
+  }
+  indexInto(rootDir, dbPath, {
+    exclude: options.exclude,
+    incremental: true,
+    scopes,
+  });
+  return repaired;
+}
+
+function runFsck(targetPath: string, options: FsckCommandOptions): void {
+  const rootDir = resolve(targetPath);
+  const dbPath = resolve(options.db);
+  try {
+    statSync(rootDir);
+  } catch {
+    reportError(`Path not found: ${rootDir}`, 'io');
+    return;
+  }
+  if (!existsSync(dbPath)) {
+    reportError(
+      `Database not found at ${dbPath}`,
+      'io',
+      "Run 'knowgraph index' first to create the database.",
+    );
+    return;
+  }
+
+  try {
+    const found = check(rootDir, dbPath, options);
+    const repairable = found.issues.some((issue) => issue.repairable);
+    const repaired =
+      options.repair && repairable
+        ? repair(rootDir, dbPath, options, found)
+        : undefined;
+    const report = repaired ? check(rootDir, dbPath, options) : found;
+
+    if (options.format === 'json') {
+      console.log(
+        formatJson(
+          repaired ? { ...report, found: found.issues, repaired } : report,
+          true,
+        ),
+      );
+    } else {
+      if (repaired) {
+        console.log(formatFsckIssues(found.issues));
+        console.log(
+          chalk.green(
+            `Cleared ${repaired.filesCleared.length} file(s) and ${repaired.rowsDeleted} dangling row(s), then re-indexed`,
+          ),
+        );
+      }
+      console.log(
+        `Checked ${chalk.cyan(String(report.filesChecked))} file(s) against ${dbPath}`,
+      );
+      if (report.issues.length === 0) {
+        console.log(chalk.green('The index matches source'));
+      } else {
+        console.log(formatFsckIssues(report.issues));
+        if (!repaired && repairable) {
+          console.log(
+            chalk.dim(
+              "Run 'knowgraph fsck --repair' to fix the repairable ones",
+            ),
+          );
+        }
+      }
+    }
+
+    if (report.issues.length > 0) {
+      reportCheckFailure(
+        `${report.issues.length} index issue(s) found`,
+        'policy',
+        { issues: report.issues.length },
+      );
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerFsckCommand(program: Command): void {
+  program
+    .command('fsck [path]')
+    .description(
+      'Check the index against a fresh scan of source and its own references',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option(
+      '--exclude <patterns>',
+      'Comma-separated glob patterns the index excluded',
+    )
+    .option('--repair', 'Clear divergent entries and re-index them')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((path: string | undefined, options: FsckCommandOptions) => {
+      runFsck(path ?? '.', options);
+    });
+}
@@ -0,0 +1,118 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that links glossary terms to entities and shows everything related to a term
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, glossary, domain]
+ * context:
+ *   business_goal: Let anyone find the code behind a business term from the terminal
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  findEntitiesForTerm,
+  linkGlossaryTerms,
+  loadGlossary,
+} from '@know-graph/core';
+import type { TermLinks } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface GlossaryCommandOptions {
+  readonly db: string;
+  readonly glossary: string;
+  readonly format: string;
+}
+
+export function formatTermLinks(links: TermLinks): string {
+  const { term } = links;
+  const lines: string[] = [chalk.bold(term.term)];
+  if (term.aliases.length > 0) {
+    lines.push(chalk.dim(`  aka ${term.aliases.join(', ')}`));
+  }
+  lines.push(`  ${term.definition}`);
+  lines.push('');
+
+  if (links.mentions.length === 0) {
+    lines.push('  No entities mention this term.');
+    return lines.join('\n');
+  }
+  for (const mention of links.mentions) {
+    lines.push(
+      `  ${mention.name} (${mention.entityType}) ${chalk.dim(`${mention.filePath}:${mention.line}`)}`,
+    );
+  }
+  return lines.join('\n');
+}
+
+export function formatGlossarySummary(links: readonly TermLinks[]): string {
+  if (links.length === 0) {
+    return 'Glossary defines no terms.';
+  }
+  const lines: string[] = [chalk.bold('Glossary')];
+  for (const entry of links) {
+    lines.push(
+      `  ${entry.term.term} ${chalk.cyan(String(entry.mentions.length))} entities`,
+    );
+  }
+  return lines.join('\n');
+}
+
+function runGlossary(
+  term: string | undefined,
+  options: GlossaryCommandOptions,
+): void {
+  const glossaryPath = resolve(options.glossary);
+  if (!existsSync(glossaryPath)) {
+    reportError(`Glossary not found at ${glossaryPath}`, 'io');
+    return;
+  }
+
+  const entities = loadEntities(resolve(options.db));
+  if (!entities) return;
+
+  try {
+    const glossary = loadGlossary(glossaryPath);
+
+    if (!term) {
+      const links = linkGlossaryTerms(entities, glossary);
+      console.log(
+        options.format === 'json'
+          ? formatJson(links, true)
+          : formatGlossarySummary(links),
+      );
+      return;
+    }
+
+    const links = findEntitiesForTerm(entities, glossary, term);
+    if (!links) {
+      reportError(`"${term}" is not in the glossary`, 'usage');
+      return;
+    }
+    console.log(
+      options.format === 'json'
+        ? formatJson(links, true)
+        : formatTermLinks(links),
+    );
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerGlossaryCommand(program: Command): void {
+  program
+    .command('glossary')
+    .description('Show entities related to glossary terms')
+    .argument('[term]', 'Term or alias to look up')
+    .option('--glossary <path>', 'Glossary file', 'glossary.yml')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((term: string | undefined, options: GlossaryCommandOptions) => {
+      runGlossary(term, options);
+    });
+}
@@ -0,0 +1,143 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that records the exported Go API of each package and diffs it against a recorded one
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, go, api, stability, diff]
+ * context:
+ *   business_goal: Hold packages annotated as stable to their API contract so breaking changes are caught before release
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { diffGoApi, discoverGoPackages, extractGoApi } from '@know-graph/core';
+import type { GoApiChange, GoApiSurface } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { readGoApiSurface, writeGoApiSurface } from '../utils/go-api.js';
+
+interface GoApiCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly output?: string;
+  readonly against?: string;
+}
+
+export function formatGoApiSurface(surface: GoApiSurface): string {
+  if (surface.packages.length === 0) {
+    return 'No Go packages found (looked for directories with .go files under a go.mod).';
+  }
+  const stable = surface.packages.filter((pkg) => pkg.stable).length;
+  const lines = [
+    chalk.bold(`Go packages: ${surface.packages.length}`) +
+      chalk.dim(` (${stable} stable)`),
+  ];
+  for (const pkg of surface.packages) {
+    const label = pkg.stable ? chalk.bold(pkg.importPath) : pkg.importPath;
+    const status = pkg.stable ? chalk.green(' stable') : '';
+    lines.push(
+      `  ${label}${status} ${chalk.dim(`${pkg.symbols.length} exported`)}`,
+    );
+  }
+  return lines.join('\n');
+}
+
+function changeLines(change: GoApiChange): readonly string[] {
+  const label = change.breaking ? chalk.red('BREAKING ') : '';
+  const lines = [
+    `  ${label}${change.importPath} ${chalk.bold(change.symbol)} ${change.change}`,
+  ];
+  if (change.before) lines.push(chalk.dim(`    before: ${change.before}`));
+  if (change.after) lines.push(chalk.dim(`    after:  ${change.after}`));
+  return lines;
+}
+
+/** Breaking changes first, then the rest, each in package order. */
+export function formatGoApiChanges(changes: readonly GoApiChange[]): string {
+  if (changes.length === 0) {
+    return chalk.green('No exported API changes.');
+  }
+  const breaking = changes.filter((change) => change.breaking);
+  const lines = [
+    chalk.bold(
+      `${breaking.length} breaking change(s) in stable packages, ${changes.length - breaking.length} other change(s)`,
+    ),
+  ];
+  for (const change of [
+    ...breaking,
+    ...changes.filter((change) => !change.breaking),
+  ]) {
+    lines.push(...changeLines(change));
+  }
+  return lines.join('\n');
+}
+
+function runGoApi(rootPath: string, options: GoApiCommandOptions): void {
+  let recorded: GoApiSurface | undefined;
+  if (options.against) {
+    try {
+      recorded = readGoApiSurface(resolve(options.against));
+    } catch (err) {
+      reportError(err);
+      return;
+    }
+  }
+
+  const entities = loadEntities(resolve(options.db));
+  if (!entities) return;
+
+  let surface: GoApiSurface;
+  try {
+    const root = resolve(rootPath);
+    surface = extractGoApi(root, discoverGoPackages(root), entities);
+    if (options.output) writeGoApiSurface(resolve(options.output), surface);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (!recorded) {
+    if (options.format === 'json') {
+      console.log(formatJson(surface, true));
+    } else {
+      console.log(formatGoApiSurface(surface));
+    }
+    return;
+  }
+
+  const changes = diffGoApi(recorded, surface);
+  if (options.format === 'json') {
+    console.log(formatJson(changes, true));
+  } else {
+    console.log(formatGoApiChanges(changes));
+  }
+  const breaking = changes.filter((change) => change.breaking).length;
+  if (breaking > 0) {
+    reportCheckFailure(
+      `${breaking} breaking change(s) to stable Go packages`,
+      'policy',
+      { breaking, changes: changes.length },
+    );
+  }
+}
+
+export function registerGoApiCommand(program: Command): void {
+  program
+    .command('go-api [path]')
+    .description(
+      'Record the exported API of Go packages, or diff it against a recorded one',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option('--output <file>', 'Write the current API to this file')
+    .option(
+      '--against <file>',
+      'Report changes since this recorded API, failing on breaking ones',
+    )
+    .action((path: string | undefined, options: GoApiCommandOptions) => {
+      runGoApi(path ?? '.', options);
+    });
+}
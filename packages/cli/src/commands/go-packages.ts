@@ -0,0 +1,97 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists Go packages and their intra-repo imports, flagging unannotated ones
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, go, packages, imports]
+ * context:
+ *   business_goal: Show the full Go package structure with annotations layered on top
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  discoverGoPackages,
+  discoverWorkspaceMembers,
+  sortGraph,
+} from '@know-graph/core';
+import type { DependencyGraph } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+
+interface GoPackagesCommandOptions {
+  readonly db: string;
+  readonly format: string;
+}
+
+/**
+ * Format the Go package nodes of a graph built with `goPackages`. Other
+ * nodes and non-import edges are ignored.
+ */
+export function formatGoPackages(graph: DependencyGraph): string {
+  const packages = graph.nodes.filter((node) => node.annotated !== undefined);
+  if (packages.length === 0) {
+    return 'No Go packages found (looked for directories with .go files under a go.mod).';
+  }
+
+  const names = new Map(packages.map((node) => [node.id, node.name]));
+  const imports = new Map<string, string[]>();
+  for (const edge of graph.edges) {
+    const target = names.get(edge.to);
+    if (edge.kind !== 'import' || !target) continue;
+    imports.set(edge.from, [...(imports.get(edge.from) ?? []), target]);
+  }
+
+  const unannotated = packages.filter((node) => !node.annotated).length;
+  const lines: string[] = [
+    chalk.bold(`Go packages: ${packages.length}`) +
+      chalk.dim(` (${unannotated} unannotated)`),
+  ];
+  for (const node of packages) {
+    const label = node.annotated
+      ? chalk.bold(node.name)
+      : chalk.dim(`${node.name} (unannotated)`);
+    const owner = node.owner ? chalk.dim(` owner: ${node.owner}`) : '';
+    lines.push(`  ${label}${owner}`);
+    const deps = imports.get(node.id) ?? [];
+    if (deps.length > 0) {
+      lines.push(`    imports: ${deps.join(', ')}`);
+    }
+  }
+  return lines.join('\n');
+}
+
+function runGoPackages(
+  rootPath: string,
+  options: GoPackagesCommandOptions,
+): void {
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath);
+  if (!entities) return;
+
+  const root = resolve(rootPath);
+  const graph = buildGraph(dbPath, entities, {
+    workspaces: discoverWorkspaceMembers(root),
+    goPackages: discoverGoPackages(root),
+  });
+  if (options.format === 'json') {
+    console.log(formatJson(sortGraph(graph), true));
+  } else {
+    console.log(formatGoPackages(graph));
+  }
+}
+
+export function registerGoPackagesCommand(program: Command): void {
+  program
+    .command('go-packages [path]')
+    .description(
+      'List Go packages and intra-repo imports, including unannotated packages',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (table|json)', 'table')
+    .action((path: string | undefined, options: GoPackagesCommandOptions) => {
+      runGoPackages(path ?? '.', options);
+    });
+}
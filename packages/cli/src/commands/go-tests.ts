@@ -0,0 +1,154 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists the Go test packages and build tags a change set needs, for CI to run
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, go, testing, ci]
+ * context:
+ *   business_goal: Cut CI time by running only the Go tests a change can break
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  buildDependencyGraph,
+  discoverGoPackages,
+  discoverGoTestTags,
+  discoverWorkspaceMembers,
+  selectGoTests,
+} from '@know-graph/core';
+import type { DependencyGraph, GoTestSelection } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+import { collectChangedFiles } from './hook.js';
+
+interface GoTestsCommandOptions {
+  readonly root: string;
+  readonly db: string;
+  readonly format: string;
+  readonly staged?: boolean;
+  readonly base?: string;
+  readonly minCoverage: string;
+}
+
+/** `go test` package patterns for a selection: `./...` when it is full. */
+export function goTestPatterns(selection: GoTestSelection): string[] {
+  if (selection.full) return ['./...'];
+  return selection.packages.map((pkg) =>
+    pkg.dir === '.' ? '.' : `./${pkg.dir}`,
+  );
+}
+
+/** Arguments for `go test`, or nothing when no package needs testing. */
+export function formatGoTestArgs(
+  selection: GoTestSelection,
+  tags: readonly string[],
+): string {
+  if (!selection.full && selection.packages.length === 0) return '';
+  const flags = tags.length > 0 ? [`-tags=${tags.join(',')}`] : [];
+  return [...flags, ...goTestPatterns(selection)].join(' ');
+}
+
+export function formatGoTestSelection(
+  selection: GoTestSelection,
+  tags: readonly string[],
+): string {
+  const coverage = chalk.dim(
+    ` (${Math.round(selection.coverage * 100)}% of changed files mapped)`,
+  );
+  const lines: string[] = [];
+  if (selection.full) {
+    lines.push(chalk.yellow(`Running all Go tests: ${selection.reason}`));
+  } else if (selection.packages.length === 0) {
+    lines.push(`No Go packages are affected.${coverage}`);
+  } else {
+    lines.push(
+      chalk.bold(`Go test packages: ${selection.packages.length}`) + coverage,
+    );
+    for (const pkg of selection.packages) {
+      const why = pkg.changed ? 'changed' : 'depends on a change';
+      lines.push(`  ${pkg.importPath} ${chalk.dim(why)}`);
+    }
+  }
+  if (tags.length > 0) lines.push(`Tags: ${tags.join(', ')}`);
+  if (selection.unmapped.length > 0) {
+    lines.push(chalk.dim(`Not in the graph: ${selection.unmapped.join(', ')}`));
+  }
+  return lines.join('\n');
+}
+
+function runGoTests(
+  files: readonly string[],
+  options: GoTestsCommandOptions,
+): void {
+  const minCoverage = Number(options.minCoverage);
+  if (!(minCoverage >= 0 && minCoverage <= 1)) {
+    reportError('--min-coverage must be a number from 0 to 1', 'usage');
+    return;
+  }
+  const changed = collectChangedFiles(files, options);
+  if (!changed) return;
+
+  const root = resolve(options.root);
+  const packages = discoverGoPackages(root);
+  const dbPath = resolve(options.db);
+  let graph: DependencyGraph;
+  try {
+    if (existsSync(dbPath)) {
+      const entities = loadEntities(dbPath);
+      if (!entities) return;
+      graph = buildGraph(dbPath, entities, {
+        workspaces: discoverWorkspaceMembers(root),
+        goPackages: packages,
+      });
+    } else {
+      graph = buildDependencyGraph([], { goPackages: packages });
+    }
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const selection = selectGoTests(packages, graph, changed, { minCoverage });
+  const tags = discoverGoTestTags(
+    root,
+    selection.packages.map((pkg) => pkg.dir),
+  );
+  if (options.format === 'json') {
+    console.log(
+      formatJson(
+        { ...selection, patterns: goTestPatterns(selection), tags },
+        true,
+      ),
+    );
+  } else if (options.format === 'args') {
+    console.log(formatGoTestArgs(selection, tags));
+  } else {
+    console.log(formatGoTestSelection(selection, tags));
+  }
+}
+
+export function registerGoTestsCommand(program: Command): void {
+  program
+    .command('go-tests [files...]')
+    .description(
+      'List the Go test packages and build tags a change needs, falling back to all when the graph covers too little of it',
+    )
+    .option('--root <path>', 'Repository root to discover Go packages in', '.')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json|args)', 'text')
+    .option('--staged', 'Add the files staged for the next commit')
+    .option('--base <ref>', 'Add the files changed since HEAD forked from ref')
+    .option(
+      '--min-coverage <ratio>',
+      'Run all tests when fewer changed files than this map to the graph',
+      '0.8',
+    )
+    .action((files: string[], options: GoTestsCommandOptions) => {
+      runGoTests(files, options);
+    });
+}
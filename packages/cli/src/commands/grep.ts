@@ -0,0 +1,144 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that searches indexed source for text, keeping hits inside nodes that match structural filters and labelling each with its node
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, grep, search, codesearch]
+ * context:
+ *   business_goal: Answer questions like "where do auth-team's confidential modules call bcrypt" in one search
+ *   domain: cli
+ */
+import { existsSync, readFileSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  compileSearchPattern,
+  parseEditQuery,
+  searchCode,
+} from '@know-graph/core';
+import type { CodeSearchHit, CodeSearchResult } from '@know-graph/core';
+import { loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+
+interface GrepCommandOptions {
+  readonly where?: string;
+  readonly ignoreCase?: boolean;
+  readonly fixedStrings?: boolean;
+  readonly limit?: string;
+  readonly db: string;
+  readonly format: string;
+}
+
+function formatNode(hit: CodeSearchHit): string {
+  if (!hit.node) return chalk.dim(`(no node) ${hit.filePath}`);
+  const { node } = hit;
+  const owner = node.owner ? `, ${node.owner}` : '';
+  return `${chalk.cyan(node.name)} ${chalk.dim(`(${node.entityType}${owner}) ${hit.filePath}:${node.line}`)}`;
+}
+
+/** Hits grouped under the node each falls in, as `file:line: text`. */
+export function formatGrepResult(result: CodeSearchResult): string {
+  if (result.hits.length === 0) {
+    return chalk.yellow('No matches.');
+  }
+  const lines: string[] = [];
+  let previous: string | undefined;
+  for (const hit of result.hits) {
+    const group = `${hit.filePath}:${hit.node?.id ?? ''}`;
+    if (group !== previous) {
+      if (previous !== undefined) lines.push('');
+      lines.push(formatNode(hit));
+      previous = group;
+    }
+    lines.push(`  ${chalk.dim(`${hit.filePath}:${hit.line}:`)} ${hit.text}`);
+  }
+  const nodes = new Set(result.hits.map((hit) => hit.node?.id ?? null));
+  nodes.delete(null);
+  lines.push(
+    '',
+    `${result.hits.length} hit(s) in ${nodes.size} node(s) across ${result.files} file(s)${result.truncated ? ' (limit reached)' : ''}`,
+  );
+  if (result.unreadable.length > 0) {
+    lines.push(
+      chalk.yellow(
+        `${result.unreadable.length} indexed file(s) could not be read; re-index if they were moved or deleted`,
+      ),
+    );
+  }
+  return lines.join('\n');
+}
+
+function runGrep(
+  pattern: string,
+  path: string,
+  options: GrepCommandOptions,
+): void {
+  if (options.format !== 'text' && options.format !== 'json') {
+    reportError(
+      `Unknown format "${options.format}". Use text or json.`,
+      'usage',
+    );
+    return;
+  }
+  const limit = options.limit === undefined ? undefined : Number(options.limit);
+  if (limit !== undefined && (!Number.isInteger(limit) || limit < 1)) {
+    reportError('--limit must be a positive integer', 'usage');
+    return;
+  }
+  try {
+    const compiled = compileSearchPattern(pattern, {
+      fixed: options.fixedStrings,
+      ignoreCase: options.ignoreCase,
+    });
+    const where =
+      options.where === undefined ? undefined : parseEditQuery(options.where);
+    const entities = loadEntities(resolve(options.db));
+    if (!entities) return;
+    const rootDir = resolve(path);
+    const result = searchCode(entities, {
+      pattern: compiled,
+      where,
+      limit,
+      readFile: (filePath) => {
+        const file = join(rootDir, filePath);
+        return existsSync(file) ? readFileSync(file, 'utf-8') : undefined;
+      },
+    });
+    console.log(
+      options.format === 'json'
+        ? formatJson(result, true)
+        : formatGrepResult(result),
+    );
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerGrepCommand(program: Command): void {
+  program
+    .command('grep <pattern> [path]')
+    .description(
+      'Search indexed source for a pattern, only inside nodes matching --where, showing the node of each hit',
+    )
+    .option(
+      '--where <query>',
+      'Nodes to search, e.g. "owner=auth-team AND compliance.data_sensitivity=confidential"',
+    )
+    .option('-i, --ignore-case', 'Match without regard to case')
+    .option('-F, --fixed-strings', 'Match the pattern as literal text')
+    .option('--limit <n>', 'Stop after this many hits')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action(
+      (
+        pattern: string,
+        path: string | undefined,
+        options: GrepCommandOptions,
+      ) => {
+        runGrep(pattern, path ?? '.', options);
+      },
+    );
+}
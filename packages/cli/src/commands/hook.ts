@@ -17,31 +17,56 @@ import {
   mkdirSync,
   unlinkSync,
 } from 'node:fs';
-import { join } from 'node:path';
-import { execSync } from 'node:child_process';
+import { dirname, join, resolve } from 'node:path';
+import { execFileSync, execSync } from 'node:child_process';
 import type { Command } from 'commander';
 import chalk from 'chalk';
+import {
+  fileChange,
+  parseNodeTrailers,
+  suggestNodeTrailers,
+} from '@know-graph/core';
+import { recordAudit } from '../utils/audit.js';
+import { loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+import { formatPlan } from '../utils/plan.js';
 
 export const HOOK_MARKER_START = '# >>> KnowGraph pre-commit hook >>>';
 export const HOOK_MARKER_END = '# <<< KnowGraph pre-commit hook <<<';
 
 interface InstallOptions {
   readonly force?: boolean;
+  /** Work out the new hook without writing it. */
+  readonly dryRun?: boolean;
 }
 
 interface InstallResult {
   readonly success: boolean;
   readonly appended?: boolean;
   readonly alreadyInstalled?: boolean;
+  /** The hook as installed, or as it would be in a dry run. */
+  readonly content?: string;
   readonly error?: string;
 }
 
+interface UninstallOptions {
+  readonly dryRun?: boolean;
+}
+
 interface UninstallResult {
   readonly success: boolean;
   readonly removed?: boolean;
+  readonly notInstalled?: boolean;
+  /** What is left of the hook; absent when the file is removed. */
+  readonly content?: string;
   readonly error?: string;
 }
 
+interface SuggestTrailersOptions {
+  readonly db: string;
+  readonly messageFile?: string;
+}
+
 interface HookStatus {
   readonly installed: boolean;
   readonly hookPath?: string;
@@ -133,18 +158,22 @@ function isOnlyKnowgraphContent(content: string): boolean {
   return stripped.length === 0;
 }
 
+function writeHook(hookPath: string, content: string, dryRun: boolean): void {
+  if (dryRun) return;
+  mkdirSync(dirname(hookPath), { recursive: true });
+  writeFileSync(hookPath, content);
+  chmodSync(hookPath, 0o755);
+}
+
 export function installHook(options: InstallOptions = {}): InstallResult {
-  const { force = false } = options;
+  const { force = false, dryRun = false } = options;
 
   const gitRoot = getGitRoot();
   if (gitRoot === null) {
     return { success: false, error: 'Not in a git repository' };
   }
 
-  const hooksDir = join(gitRoot, '.git', 'hooks');
-  const hookPath = join(hooksDir, 'pre-commit');
-
-  mkdirSync(hooksDir, { recursive: true });
+  const hookPath = join(gitRoot, '.git', 'hooks', 'pre-commit');
 
   if (existsSync(hookPath)) {
     const existingContent = readFileSync(hookPath, 'utf-8');
@@ -156,24 +185,25 @@ export function installHook(options: InstallOptions = {}): InstallResult {
       const updated = removeKnowgraphSection(existingContent);
       const newContent =
         updated.trimEnd() + '\n\n' + extractKnowgraphSection() + '\n';
-      writeFileSync(hookPath, newContent);
-      chmodSync(hookPath, 0o755);
-      return { success: true, appended: false };
+      writeHook(hookPath, newContent, dryRun);
+      return { success: true, appended: false, content: newContent };
     }
 
     const newContent =
       existingContent.trimEnd() + '\n\n' + extractKnowgraphSection() + '\n';
-    writeFileSync(hookPath, newContent);
-    chmodSync(hookPath, 0o755);
-    return { success: true, appended: true };
+    writeHook(hookPath, newContent, dryRun);
+    return { success: true, appended: true, content: newContent };
   }
 
-  writeFileSync(hookPath, buildHookScript());
-  chmodSync(hookPath, 0o755);
-  return { success: true };
+  const script = buildHookScript();
+  writeHook(hookPath, script, dryRun);
+  return { success: true, content: script };
 }
 
-export function uninstallHook(): UninstallResult {
+export function uninstallHook(
+  options: UninstallOptions = {},
+): UninstallResult {
+  const { dryRun = false } = options;
   const gitRoot = getGitRoot();
   if (gitRoot === null) {
     return { success: false, error: 'Not in a git repository' };
@@ -182,23 +212,31 @@ export function uninstallHook(): UninstallResult {
   const hookPath = join(gitRoot, '.git', 'hooks', 'pre-commit');
 
   if (!existsSync(hookPath)) {
-    return { success: false, error: 'KnowGraph hook is not installed' };
+    return {
+      success: false,
+      notInstalled: true,
+      error: 'KnowGraph hook is not installed',
+    };
   }
 
   const content = readFileSync(hookPath, 'utf-8');
 
   if (!hasKnowgraphSection(content)) {
-    return { success: false, error: 'KnowGraph hook is not installed' };
+    return {
+      success: false,
+      notInstalled: true,
+      error: 'KnowGraph hook is not installed',
+    };
   }
 
   if (isOnlyKnowgraphContent(content)) {
-    unlinkSync(hookPath);
+    if (!dryRun) unlinkSync(hookPath);
     return { success: true, removed: true };
   }
 
   const updated = removeKnowgraphSection(content);
-  writeFileSync(hookPath, updated);
-  return { success: true, removed: false };
+  if (!dryRun) writeFileSync(hookPath, updated);
+  return { success: true, removed: false, content: updated };
 }
 
 export function getHookStatus(): HookStatus {
@@ -222,6 +260,118 @@ export function getHookStatus(): HookStatus {
   return { installed: false, hookPath };
 }
 
+/** Files staged for the next commit, or none outside a repository. */
+export function getStagedFiles(): readonly string[] {
+  try {
+    return execSync('git diff --cached --name-only', { encoding: 'utf-8' })
+      .split('\n')
+      .filter(Boolean);
+  } catch {
+    return [];
+  }
+}
+
+/** Files changed on this branch since it forked from `ref`; null on failure. */
+export function getChangedSince(ref: string): readonly string[] | null {
+  try {
+    return execFileSync('git', ['diff', '--name-only', `${ref}...HEAD`], {
+      encoding: 'utf-8',
+      stdio: ['ignore', 'pipe', 'ignore'],
+    })
+      .split('\n')
+      .filter(Boolean);
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * `files` plus the staged files with `staged` and the branch's changes
+ * since `base`. Reports a usage error and returns undefined when git cannot
+ * diff against `base` or there are no files at all.
+ */
+export function collectChangedFiles(
+  files: readonly string[],
+  options: { readonly staged?: boolean; readonly base?: string },
+): readonly string[] | undefined {
+  const changed = [...files, ...(options.staged ? getStagedFiles() : [])];
+  if (options.base) {
+    const since = getChangedSince(options.base);
+    if (!since) {
+      reportError(
+        `Could not diff against ${options.base}`,
+        'usage',
+        'Pass a branch, tag, or commit that shares history with HEAD.',
+      );
+      return undefined;
+    }
+    changed.push(...since);
+  }
+  if (changed.length === 0) {
+    reportError(
+      'No changed files',
+      'usage',
+      'Pass the changed files, --staged, or --base <ref>.',
+    );
+    return undefined;
+  }
+  return changed;
+}
+
+/**
+ * `message` with `trailers` added as comments for the author to uncomment,
+ * just above git's own comment block so they end up as the last paragraph.
+ * A message that already has `Knowgraph-Node` trailers is left alone.
+ */
+export function appendTrailerSuggestions(
+  message: string,
+  trailers: readonly string[],
+): string {
+  if (trailers.length === 0 || parseNodeTrailers(message).length > 0) {
+    return message;
+  }
+  const lines = message.split('\n');
+  const comments = lines.findIndex((line) => line.startsWith('#'));
+  const at = comments === -1 ? lines.length : comments;
+  const body = lines.slice(0, at).join('\n').trimEnd();
+  const block = [
+    '# Uncomment to link this commit to the nodes it changes:',
+    ...trailers.map((trailer) => `# ${trailer}`),
+  ];
+  return [body, '', ...block, ...lines.slice(at)].join('\n');
+}
+
+function readHookFile(hookPath: string | null): string | undefined {
+  return hookPath && existsSync(hookPath)
+    ? readFileSync(hookPath, 'utf-8')
+    : undefined;
+}
+
+function printHookPlan(
+  command: string,
+  before: string | undefined,
+  after: string | undefined,
+): void {
+  const change = fileChange('.git/hooks/pre-commit', before, after);
+  console.log(
+    formatPlan({
+      command,
+      changes: change ? [change] : [],
+      totals: [`${change ? 1 : 0} hook(s) changed`],
+    }),
+  );
+}
+
+function auditHookChange(
+  command: string,
+  hookPath: string | null,
+  before: string | undefined,
+): void {
+  recordAudit(resolve('.knowgraph.yml'), command, [
+    fileChange('.git/hooks/pre-commit', before, readHookFile(hookPath)),
+  ]);
+}
+
 export function registerHookCommand(program: Command): void {
   const hookCmd = program
     .command('hook')
@@ -231,8 +381,11 @@ export function registerHookCommand(program: Command): void {
     .command('install')
     .description('Install the KnowGraph pre-commit hook')
     .option('--force', 'Overwrite existing KnowGraph hook section', false)
-    .action((options: { readonly force: boolean }) => {
-      const result = installHook({ force: options.force });
+    .option('--dry-run', 'Show the hook change without writing it')
+    .action((options: InstallOptions) => {
+      const hookPath = getHookPath();
+      const before = readHookFile(hookPath);
+      const result = installHook(options);
 
       if (result.alreadyInstalled) {
         console.log(
@@ -244,10 +397,14 @@ export function registerHookCommand(program: Command): void {
       }
 
       if (!result.success) {
-        console.error(chalk.red(`Error: ${result.error}`));
-        process.exitCode = 1;
+        reportError(result.error, 'io');
+        return;
+      }
+      if (options.dryRun) {
+        printHookPlan('hook install', before, result.content);
         return;
       }
+      auditHookChange('hook install', hookPath, before);
 
       if (result.appended) {
         console.log(
@@ -261,14 +418,21 @@ export function registerHookCommand(program: Command): void {
   hookCmd
     .command('uninstall')
     .description('Remove the KnowGraph pre-commit hook')
-    .action(() => {
-      const result = uninstallHook();
+    .option('--dry-run', 'Show the hook change without writing it')
+    .action((options: UninstallOptions) => {
+      const hookPath = getHookPath();
+      const before = readHookFile(hookPath);
+      const result = uninstallHook(options);
 
       if (!result.success) {
-        console.error(chalk.red(`Error: ${result.error}`));
-        process.exitCode = 1;
+        reportError(result.error, result.notInstalled ? 'usage' : 'io');
+        return;
+      }
+      if (options.dryRun) {
+        printHookPlan('hook uninstall', before, result.content);
         return;
       }
+      auditHookChange('hook uninstall', hookPath, before);
 
       if (result.removed) {
         console.log(chalk.green('KnowGraph pre-commit hook removed.'));
@@ -281,6 +445,36 @@ export function registerHookCommand(program: Command): void {
       }
     });
 
+  hookCmd
+    .command('suggest-trailers [files...]')
+    .description(
+      'Suggest Knowgraph-Node trailers for the staged or given files',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option(
+      '--message-file <path>',
+      'Add the suggestions to a commit message file (for prepare-commit-msg)',
+    )
+    .action((files: string[], options: SuggestTrailersOptions) => {
+      const entities = loadEntities(resolve(options.db));
+      if (!entities) return;
+      const trailers = suggestNodeTrailers(
+        entities,
+        files.length > 0 ? files : getStagedFiles(),
+      );
+      if (!options.messageFile) {
+        if (trailers.length > 0) console.log(trailers.join('\n'));
+        return;
+      }
+      try {
+        const path = resolve(options.messageFile);
+        const message = readFileSync(path, 'utf-8');
+        writeFileSync(path, appendTrailerSuggestions(message, trailers));
+      } catch (err) {
+        reportError(err);
+      }
+    });
+
   hookCmd
     .command('status')
     .description('Check if the KnowGraph pre-commit hook is installed')
@@ -288,8 +482,7 @@ export function registerHookCommand(program: Command): void {
       const status = getHookStatus();
 
       if (status.error) {
-        console.error(chalk.red(`Error: ${status.error}`));
-        process.exitCode = 1;
+        reportError(status.error, 'io');
         return;
       }
 
@@ -0,0 +1,261 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that crosses git churn with revenue impact into a quadrant report of modules, as text, JSON, CSV, or an HTML page
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, hotspots, churn, git, criticality, csv, html]
+ * context:
+ *   business_goal: Give engineering leadership a ranked list of critical code that keeps changing, to target stabilization work
+ *   domain: cli
+ */
+import { writeFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  HOTSPOT_QUADRANTS,
+  RevenueImpactSchema,
+  buildHotspotReport,
+  createGitLogRunner,
+  formatHotspotCsv,
+  measureChurn,
+  parseGitHistory,
+} from '@know-graph/core';
+import type {
+  HotspotEntry,
+  HotspotQuadrant,
+  HotspotReport,
+  RevenueImpact,
+} from '@know-graph/core';
+import { loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+
+interface HotspotsCommandOptions {
+  readonly db: string;
+  readonly since: string;
+  readonly churnThreshold?: string;
+  readonly critical: string;
+  readonly format: string;
+  readonly output?: string;
+}
+
+const FORMATS = ['text', 'json', 'csv', 'html'];
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+const TITLES: Readonly<Record<HotspotQuadrant, string>> = {
+  hotspot: 'Hotspots: high churn, high criticality',
+  churning: 'Churning: high churn, low criticality',
+  critical: 'Critical: low churn, high criticality',
+  quiet: 'Quiet: low churn, low criticality',
+};
+
+function entryLine(entry: HotspotEntry): string {
+  const impact = entry.revenueImpact ?? 'no impact';
+  const owner = entry.owner ? `, ${entry.owner}` : '';
+  return `  ${String(entry.commits).padStart(4)}  ${entry.name} ${chalk.dim(`(${impact}${owner}) ${entry.filePath}`)}`;
+}
+
+export function formatHotspotText(report: HotspotReport): string {
+  if (report.entries.length === 0) {
+    return chalk.yellow('No modules to place.');
+  }
+  const lines = [
+    chalk.dim(
+      `High churn: ${report.churnThreshold}+ commits. Critical: ${report.criticalImpacts.join(', ')}.`,
+    ),
+  ];
+  for (const quadrant of HOTSPOT_QUADRANTS) {
+    const entries = report.entries.filter(
+      (entry) => entry.quadrant === quadrant,
+    );
+    if (entries.length === 0) continue;
+    const title = `${TITLES[quadrant]} (${entries.length})`;
+    lines.push(
+      '',
+      quadrant === 'hotspot' ? chalk.red.bold(title) : chalk.bold(title),
+      ...entries.map(entryLine),
+    );
+  }
+  return lines.join('\n');
+}
+
+function escapeHtml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;');
+}
+
+const HTML_STYLE = [
+  'body{font-family:system-ui,sans-serif;margin:2rem;color:#1f2328}',
+  '.grid{display:grid;grid-template-columns:1fr 1fr;gap:1rem}',
+  'section{border:1px solid #d0d7de;border-radius:6px;padding:0 1rem 1rem}',
+  '.hotspot{background:#ffebe9}.churning,.critical{background:#fff8c5}',
+  '.quiet{background:#dafbe1}',
+  'table{border-collapse:collapse;width:100%}',
+  'th,td{padding:.2rem .5rem;text-align:left}',
+].join('');
+
+function quadrantHtml(
+  report: HotspotReport,
+  quadrant: HotspotQuadrant,
+): string[] {
+  const entries = report.entries.filter(
+    (entry) => entry.quadrant === quadrant,
+  );
+  const rows = entries.map((entry) =>
+    [
+      entry.name,
+      entry.owner ?? '-',
+      entry.revenueImpact ?? '-',
+      String(entry.commits),
+      entry.filePath,
+    ]
+      .map((cell) => `<td>${escapeHtml(cell)}</td>`)
+      .join(''),
+  );
+  return [
+    `<section class="${quadrant}">`,
+    `<h2>${TITLES[quadrant]} (${entries.length})</h2>`,
+    '<table>',
+    '<tr><th>Module</th><th>Owner</th><th>Impact</th><th>Commits</th><th>File</th></tr>',
+    ...rows.map((row) => `<tr>${row}</tr>`),
+    '</table>',
+    '</section>',
+  ];
+}
+
+/**
+ * A standalone page with the four quadrants, churn above criticality, for
+ * publishing beside other generated pages.
+ */
+export function formatHotspotHtml(report: HotspotReport): string {
+  return [
+    '<!DOCTYPE html>',
+    '<html lang="en">',
+    '<head>',
+    '<meta charset="utf-8">',
+    '<title>Churn and criticality</title>',
+    `<style>${HTML_STYLE}</style>`,
+    '</head>',
+    '<body>',
+    '<h1>Churn and criticality</h1>',
+    `<p>High churn is ${report.churnThreshold} or more commits. Critical means a revenue impact of ${escapeHtml(report.criticalImpacts.join(', '))}.</p>`,
+    '<div class="grid">',
+    ...HOTSPOT_QUADRANTS.flatMap((quadrant) => quadrantHtml(report, quadrant)),
+    '</div>',
+    '</body>',
+    '</html>',
+    '',
+  ].join('\n');
+}
+
+function render(report: HotspotReport, format: string): string {
+  switch (format) {
+    case 'json':
+      return `${formatJson(report, true)}\n`;
+    case 'csv':
+      return formatHotspotCsv(report);
+    case 'html':
+      return formatHotspotHtml(report);
+    default:
+      return `${formatHotspotText(report)}\n`;
+  }
+}
+
+function parseImpacts(value: string): readonly RevenueImpact[] | undefined {
+  const impacts = value.split(',').map((impact) => impact.trim());
+  const valid = impacts.every(
+    (impact) => RevenueImpactSchema.safeParse(impact).success,
+  );
+  return valid ? (impacts as RevenueImpact[]) : undefined;
+}
+
+function runHotspots(path: string, options: HotspotsCommandOptions): void {
+  if (!FORMATS.includes(options.format)) {
+    reportError(
+      `Unknown format "${options.format}" (use ${FORMATS.join('|')})`,
+      'usage',
+    );
+    return;
+  }
+  const days = Number(options.since);
+  if (!Number.isInteger(days) || days < 1) {
+    reportError('--since must be a positive number of days', 'usage');
+    return;
+  }
+  const threshold =
+    options.churnThreshold === undefined
+      ? undefined
+      : Number(options.churnThreshold);
+  if (
+    threshold !== undefined &&
+    (!Number.isInteger(threshold) || threshold < 1)
+  ) {
+    reportError('--churn-threshold must be a positive integer', 'usage');
+    return;
+  }
+  const criticalImpacts = parseImpacts(options.critical);
+  if (!criticalImpacts) {
+    reportError(
+      `Invalid --critical "${options.critical}"`,
+      'usage',
+      `Use revenue impacts from ${RevenueImpactSchema.options.join(', ')}, comma-separated.`,
+    );
+    return;
+  }
+
+  const entities = loadEntities(resolve(options.db));
+  if (!entities) return;
+  let log: string;
+  try {
+    log = createGitLogRunner().log(resolve(path));
+  } catch (err) {
+    reportError(err, 'io', 'Run from a git checkout to measure churn.');
+    return;
+  }
+  const report = buildHotspotReport(
+    entities,
+    measureChurn(parseGitHistory(log), new Date(Date.now() - days * DAY_MS)),
+    { churnThreshold: threshold, criticalImpacts },
+  );
+
+  const content = render(report, options.format);
+  if (options.output) {
+    writeFileSync(resolve(options.output), content, 'utf-8');
+    console.error(
+      chalk.green(`Wrote the quadrant report to ${options.output}`),
+    );
+  } else {
+    process.stdout.write(content);
+  }
+}
+
+export function registerHotspotsCommand(program: Command): void {
+  program
+    .command('hotspots')
+    .description(
+      'Place modules on a quadrant of git churn against revenue impact, high-churn critical ones first',
+    )
+    .argument('[path]', 'Git checkout the index was built from', '.')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--since <days>', 'Days of git history to count commits in', '90')
+    .option(
+      '--churn-threshold <commits>',
+      'Commits at which churn counts as high (default: the median of changed modules)',
+    )
+    .option(
+      '--critical <impacts>',
+      'Revenue impacts that count as critical, comma-separated',
+      'critical,high',
+    )
+    .option('--format <format>', 'Output format (text|json|csv|html)', 'text')
+    .option('--output <path>', 'Write the report to a file')
+    .action((path: string, options: HotspotsCommandOptions) => {
+      runHotspots(path, options);
+    });
+}
@@ -0,0 +1,165 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists the Bazel packages a change set can break as target patterns for bazel test
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, bazel, impact, ci]
+ * context:
+ *   business_goal: Let Bazel users test only what a change can break
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  bazelTargetPatterns,
+  buildDependencyGraph,
+  createBazelQueryRunner,
+  discoverWorkspaceMembers,
+  mergeBazelEdges,
+  queryBazelDependencies,
+  selectBazelTargets,
+} from '@know-graph/core';
+import type {
+  BazelEdge,
+  BazelTargetSelection,
+  DependencyGraph,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+import { collectChangedFiles } from './hook.js';
+
+interface ImpactCommandOptions {
+  readonly root: string;
+  readonly db: string;
+  readonly format: string;
+  readonly staged?: boolean;
+  readonly base?: string;
+  readonly minCoverage: string;
+  readonly bazelQuery: boolean;
+  readonly bazel: string;
+  readonly bazelScope: string;
+}
+
+export function formatBazelImpact(selection: BazelTargetSelection): string {
+  const coverage = chalk.dim(
+    ` (${Math.round(selection.coverage * 100)}% of changed files mapped)`,
+  );
+  const lines: string[] = [];
+  if (selection.full) {
+    lines.push(chalk.yellow(`Testing every target: ${selection.reason}`));
+  } else if (selection.packages.length === 0) {
+    lines.push(`No Bazel packages are affected.${coverage}`);
+  } else {
+    lines.push(
+      chalk.bold(`Affected Bazel packages: ${selection.packages.length}`) +
+        coverage,
+    );
+    for (const pkg of selection.packages) {
+      const why = pkg.changed ? 'changed' : 'depends on a change';
+      lines.push(`  ${pkg.label} ${chalk.dim(why)}`);
+    }
+  }
+  if (selection.unmapped.length > 0) {
+    lines.push(chalk.dim(`Not in the graph: ${selection.unmapped.join(', ')}`));
+  }
+  return lines.join('\n');
+}
+
+async function runImpact(
+  files: readonly string[],
+  options: ImpactCommandOptions,
+): Promise<void> {
+  const minCoverage = Number(options.minCoverage);
+  if (!(minCoverage >= 0 && minCoverage <= 1)) {
+    reportError('--min-coverage must be a number from 0 to 1', 'usage');
+    return;
+  }
+  const changed = collectChangedFiles(files, options);
+  if (!changed) return;
+
+  const root = resolve(options.root);
+  const members = discoverWorkspaceMembers(root);
+  if (!members.some((member) => member.kind === 'bazel')) {
+    reportError(
+      `No Bazel packages found under ${root}`,
+      'usage',
+      'Run from a Bazel workspace (MODULE.bazel or WORKSPACE), or pass --root.',
+    );
+    return;
+  }
+
+  const dbPath = resolve(options.db);
+  try {
+    let graph: DependencyGraph;
+    if (existsSync(dbPath)) {
+      const entities = loadEntities(dbPath);
+      if (!entities) return;
+      graph = buildGraph(dbPath, entities, { workspaces: members });
+    } else {
+      graph = buildDependencyGraph([], { workspaces: members });
+    }
+    if (options.bazelQuery) {
+      const runner = createBazelQueryRunner({
+        cwd: root,
+        bazelPath: options.bazel,
+      });
+      let buildEdges: readonly BazelEdge[];
+      try {
+        buildEdges = await queryBazelDependencies(runner, options.bazelScope);
+      } catch (err) {
+        reportError(
+          err,
+          'io',
+          'Pass --no-bazel-query to follow declared dependencies only.',
+        );
+        return;
+      }
+      graph = mergeBazelEdges(graph, buildEdges);
+    }
+
+    const selection = selectBazelTargets(members, graph, changed, {
+      minCoverage,
+    });
+    const patterns = bazelTargetPatterns(selection);
+    if (options.format === 'json') {
+      console.log(formatJson({ ...selection, patterns }, true));
+    } else if (options.format === 'bazel') {
+      console.log(selection.packages.length > 0 ? patterns.join(' ') : '');
+    } else {
+      console.log(formatBazelImpact(selection));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerImpactCommand(program: Command): void {
+  program
+    .command('impact [files...]')
+    .description(
+      'List the Bazel packages a change can break, as target patterns for bazel test',
+    )
+    .option('--root <path>', 'Bazel workspace root', '.')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json|bazel)', 'text')
+    .option('--staged', 'Add the files staged for the next commit')
+    .option('--base <ref>', 'Add the files changed since HEAD forked from ref')
+    .option(
+      '--min-coverage <ratio>',
+      'Test every target when fewer changed files than this map to the graph',
+      '0.8',
+    )
+    .option(
+      '--no-bazel-query',
+      'Follow declared dependencies only, without running bazel query',
+    )
+    .option('--bazel <path>', 'Bazel executable', 'bazel')
+    .option('--bazel-scope <pattern>', 'Target pattern to query', '//...')
+    .action(async (files: string[], options: ImpactCommandOptions) => {
+      await runImpact(files, options);
+    });
+}
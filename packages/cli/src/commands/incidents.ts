@@ -0,0 +1,238 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that imports incidents from FireHydrant or incident.io and ranks nodes by incident cost
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, incidents, firehydrant, incident-io]
+ * context:
+ *   business_goal: Show which code keeps causing incidents that hurt revenue
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { dirname, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  appendIncidents,
+  buildIncidentReport,
+  fetchIncidents,
+  parseIncidents,
+  readIncidentLog,
+} from '@know-graph/core';
+import type {
+  Incident,
+  IncidentReport,
+  IncidentSource,
+} from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+import { readIncidentsConfig } from '../utils/manifest.js';
+
+const SOURCES: readonly IncidentSource[] = ['firehydrant', 'incidentio'];
+
+const TOKEN_ENV: Readonly<Record<IncidentSource, string>> = {
+  firehydrant: 'FIREHYDRANT_API_KEY',
+  incidentio: 'INCIDENT_IO_API_KEY',
+};
+
+interface IncidentsImportOptions {
+  readonly config: string;
+  readonly source?: string;
+  readonly tokenEnv?: string;
+  readonly baseUrl?: string;
+  readonly since?: string;
+}
+
+interface IncidentsReportOptions {
+  readonly config: string;
+  readonly db: string;
+  readonly since?: string;
+  readonly limit: string;
+  readonly format: string;
+}
+
+/** The incident log for the manifest at `configPath`, resolved next to it. */
+export function incidentLogPath(configPath: string): string {
+  return resolve(dirname(configPath), readIncidentsConfig(configPath).path);
+}
+
+/** `--since` as an ISO 8601 time; null after reporting a malformed one. */
+function parseSince(since: string | undefined): string | undefined | null {
+  if (since === undefined) return undefined;
+  const time = Date.parse(since);
+  if (Number.isNaN(time)) {
+    reportError('--since must be an ISO 8601 date', 'usage');
+    return null;
+  }
+  return new Date(time).toISOString();
+}
+
+export function formatIncidentReport(
+  report: IncidentReport,
+  limit: number,
+): string {
+  if (report.rankings.length === 0) return 'No incidents matched any node.';
+  const lines: string[] = [];
+  for (const ranking of report.rankings.slice(0, limit)) {
+    const impact = ranking.revenueImpact ?? 'unset';
+    lines.push(
+      `${chalk.bold(ranking.name)} (${ranking.entityType}) ` +
+        `${chalk.cyan(String(ranking.score))} ` +
+        chalk.dim(`${ranking.incidents.length} × ${impact} ${ranking.weight}`),
+    );
+    for (const incident of ranking.incidents) {
+      const severity = incident.severity ? ` [${incident.severity}]` : '';
+      const started = incident.startedAt
+        ? chalk.dim(` ${incident.startedAt.slice(0, 10)}`)
+        : '';
+      lines.push(`  ${incident.title}${severity}${started}`);
+    }
+  }
+  if (report.unmatched.length > 0) {
+    lines.push('');
+    lines.push(
+      chalk.yellow(
+        `${report.unmatched.length} incidents matched no node; ` +
+          'add the services they name as aliases',
+      ),
+    );
+  }
+  return lines.join('\n');
+}
+
+async function runIncidentsImport(
+  files: readonly string[],
+  options: IncidentsImportOptions,
+): Promise<void> {
+  const source = options.source as IncidentSource | undefined;
+  if (source && !SOURCES.includes(source)) {
+    reportError(
+      `Unknown source "${options.source}". Use ${SOURCES.join(', ')}.`,
+      'usage',
+    );
+    return;
+  }
+  if (files.length === 0 && !source) {
+    reportError(
+      '--source is required when fetching incidents from an API',
+      'usage',
+    );
+    return;
+  }
+  const since = parseSince(options.since);
+  if (since === null) return;
+
+  const configPath = resolve(options.config);
+  const config = readIncidentsConfig(configPath);
+  try {
+    let incidents: readonly Incident[];
+    if (files.length > 0) {
+      const parsed: Incident[] = [];
+      for (const file of files) {
+        const text = readFileSync(resolve(file), 'utf-8');
+        parsed.push(...parseIncidents(text, source, config.service_fields));
+      }
+      incidents = since
+        ? parsed.filter((incident) => incident.startedAt >= since)
+        : parsed;
+    } else {
+      const tokenEnv = options.tokenEnv ?? TOKEN_ENV[source!];
+      const token = process.env[tokenEnv];
+      if (!token) {
+        reportError(
+          `Environment variable ${tokenEnv} is not set`,
+          'usage',
+          `Set ${tokenEnv} to an API key, or pass saved exports as files.`,
+        );
+        return;
+      }
+      incidents = await fetchIncidents(source!, {
+        token,
+        since,
+        baseUrl: options.baseUrl,
+        serviceFields: config.service_fields,
+      });
+    }
+    const logPath = incidentLogPath(configPath);
+    appendIncidents(logPath, incidents);
+    console.log(
+      chalk.green(`Imported ${incidents.length} incidents to ${logPath}`),
+    );
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+function runIncidentsReport(options: IncidentsReportOptions): void {
+  const limit = Number(options.limit);
+  if (!Number.isInteger(limit) || limit < 1) {
+    reportError('--limit must be a positive integer', 'usage');
+    return;
+  }
+  const since = parseSince(options.since);
+  if (since === null) return;
+
+  const dbPath = resolve(options.db);
+  const configPath = resolve(options.config);
+  const entities = loadEntities(dbPath, configPath);
+  if (!entities) return;
+
+  try {
+    const report = buildIncidentReport(
+      entities,
+      buildGraph(dbPath, entities, { configPath }),
+      readIncidentLog(incidentLogPath(configPath)),
+      { since, weights: readIncidentsConfig(configPath).weights },
+    );
+    if (options.format === 'json') {
+      console.log(
+        formatJson(
+          { ...report, rankings: report.rankings.slice(0, limit) },
+          true,
+        ),
+      );
+    } else {
+      console.log(formatIncidentReport(report, limit));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerIncidentsCommand(program: Command): void {
+  const incidentsCmd = program
+    .command('incidents')
+    .description('Track incident history per node and rank nodes by its cost');
+
+  incidentsCmd
+    .command('import')
+    .description(
+      'Record incidents from FireHydrant or incident.io, or saved API responses',
+    )
+    .argument('[files...]', 'Saved incident list responses; fetches when none')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--source <source>', 'Incident tool (firehydrant|incidentio)')
+    .option(
+      '--token-env <name>',
+      'Environment variable holding the API key (default: FIREHYDRANT_API_KEY or INCIDENT_IO_API_KEY)',
+    )
+    .option('--base-url <url>', 'API base URL, for proxies and tests')
+    .option('--since <date>', 'Only incidents started at or after this date')
+    .action(async (files: string[], options: IncidentsImportOptions) => {
+      await runIncidentsImport(files, options);
+    });
+
+  incidentsCmd
+    .command('report')
+    .description('Rank nodes by incident count times revenue impact')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--since <date>', 'Only incidents started at or after this date')
+    .option('--limit <n>', 'Number of nodes to show', '20')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: IncidentsReportOptions) => {
+      runIncidentsReport(options);
+    });
+}
@@ -9,60 +9,143 @@
  *   business_goal: Enable developers to build a searchable code knowledge graph
  *   domain: cli
  */
-import { mkdirSync } from 'node:fs';
-import { resolve, join } from 'node:path';
+import {
+  copyFileSync,
+  existsSync,
+  mkdirSync,
+  mkdtempSync,
+  rmSync,
+} from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, resolve, join } from 'node:path';
 import type { Command } from 'commander';
 import chalk from 'chalk';
 import ora from 'ora';
 import {
-  createDefaultRegistry,
-  createDatabaseManager,
-  createIndexer,
+  diffGraphs,
+  formatScope,
+  graphChange,
+  isCancellationError,
+  parseRemoteSource,
+  redactUrl,
+} from '@know-graph/core';
+import type {
+  AnnotationConflict,
+  AnnotationValue,
+  EnricherRun,
+  IndexProgress,
+  IndexResult,
+  PhaseTimer,
+  ScanScope,
+  SkippedFile,
 } from '@know-graph/core';
-import type { IndexProgress } from '@know-graph/core';
+import { indexInto } from '../utils/indexing.js';
+import { resolveScanTarget } from '../utils/remote.js';
+import type { ScanTarget } from '../utils/remote.js';
+import { formatPlan } from '../utils/plan.js';
+import { reportProfile, startProfile } from '../utils/profile.js';
+import {
+  isAuditEnabled,
+  readGraphSnapshot,
+  recordAudit,
+} from '../utils/audit.js';
+import { reportError } from '../utils/errors.js';
+import { recordScan } from '../utils/history.js';
+import { getLogger, isStructuredLogging } from '../utils/logging.js';
+import { readGraphNames } from '../utils/manifest.js';
+import { publishToWarehouses } from '../utils/warehouse.js';
+import { collectScope, parseScopes } from '../utils/scope.js';
+import { flushTelemetry, startTelemetry } from '../utils/telemetry.js';
 
 interface IndexOptions {
   readonly output: string;
   readonly exclude?: string;
   readonly incremental: boolean;
   readonly verbose?: boolean;
+  readonly locale?: string;
+  readonly timeout?: string;
+  readonly profile?: string;
+  readonly dryRun?: boolean;
+  readonly cacheDir?: string;
+  readonly scope?: readonly string[];
+  readonly followSymlinks?: boolean;
+  readonly submodules?: boolean;
+  readonly vendor?: boolean;
+  readonly maxFileBytes?: string;
+  readonly enrichers?: string;
 }
 
-function createParserRegistryAdapter(
-  coreRegistry: ReturnType<typeof createDefaultRegistry>,
-) {
-  return {
-    parse(filePath: string, content: string) {
-      return coreRegistry.parseFile(content, filePath).results;
-    },
-    canParse(filePath: string) {
-      return coreRegistry.getParser(filePath) !== undefined;
-    },
-  };
+export function formatEnricherRuns(runs: readonly EnricherRun[]): string {
+  const width = Math.max(...runs.map((run) => run.name.length));
+  return runs
+    .map((run) => {
+      const name = `  ${run.name.padEnd(width)}  `;
+      if (run.status === 'skipped') return chalk.dim(`${name}skipped`);
+      if (run.status === 'failed') {
+        return chalk.yellow(`${name}failed: ${run.error}`);
+      }
+      const calls = run.calls
+        ? chalk.dim(` (${run.calls.calls} calls, ${run.calls.cached} cached)`)
+        : '';
+      return `${name}${chalk.cyan(String(run.updated))} updated in ${Math.round(run.ms)}ms${calls}`;
+    })
+    .join('\n');
 }
 
-function runIndex(targetPath: string, options: IndexOptions): void {
-  const rootDir = resolve(targetPath);
-  const outputDir = resolve(options.output);
-  const dbPath = join(outputDir, 'knowgraph.db');
-
-  mkdirSync(outputDir, { recursive: true });
+function formatAnnotationValue(value: AnnotationValue): string {
+  return `${JSON.stringify(value.value)} (${value.source}, ${value.location})`;
+}
 
-  const spinner = ora('Initializing indexer...').start();
+/**
+ * One line per conflict: the entity and field, the value kept, and the
+ * values it overrode, each with where it is written.
+ */
+export function formatAnnotationConflicts(
+  conflicts: readonly AnnotationConflict[],
+): string {
+  return conflicts
+    .map((conflict) => {
+      const overridden = conflict.overridden.map(formatAnnotationValue);
+      return `  ${conflict.filePath} ${conflict.entity}.${conflict.field}: kept ${formatAnnotationValue(conflict.kept)} over ${overridden.join(', ')}`;
+    })
+    .join('\n');
+}
 
-  try {
-    const coreRegistry = createDefaultRegistry();
-    const registryAdapter = createParserRegistryAdapter(coreRegistry);
-    const dbManager = createDatabaseManager(dbPath);
-    dbManager.initialize();
+/** One line per skipped file: its path, why, and the limit it broke. */
+export function formatSkippedFiles(files: readonly SkippedFile[]): string {
+  return files
+    .map((file) =>
+      chalk.yellow(`  ${file.filePath} (${file.reason}): ${file.message}`),
+    )
+    .join('\n');
+}
 
-    const indexer = createIndexer(registryAdapter, dbManager);
+function indexRepository(
+  target: ScanTarget,
+  options: IndexOptions,
+  scopes: readonly ScanScope[],
+  dbPath: string,
+  profiler?: PhaseTimer,
+): IndexResult | undefined {
+  const { rootDir, source } = target;
 
-    const excludePatterns = options.exclude
-      ? options.exclude.split(',').map((p) => p.trim())
-      : ['node_modules', '.git', 'dist', 'build'];
+  // JSON log records stand in for the spinner, which would garble them
+  const structured = isStructuredLogging();
+  const logger = getLogger();
+  const spinner = ora({
+    text: 'Initializing indexer...',
+    isSilent: structured,
+  }).start();
 
+  try {
     const onProgress = (progress: IndexProgress): void => {
+      if (progress.currentFile) {
+        logger.debug(`Indexed ${progress.currentFile}`, {
+          file: progress.currentFile,
+          processed: progress.processedFiles,
+          total: progress.totalFiles,
+        });
+      }
       const pct =
         progress.totalFiles > 0
           ? Math.round((progress.processedFiles / progress.totalFiles) * 100)
@@ -74,16 +157,60 @@ function runIndex(targetPath: string, options: IndexOptions): void {
       }
     };
 
-    const result = indexer.index({
-      rootDir,
-      exclude: excludePatterns,
+    const { result, runs } = indexInto(rootDir, dbPath, {
+      exclude: options.exclude,
       incremental: options.incremental,
+      locale: options.locale,
+      timeout: options.timeout,
+      source,
+      scopes,
+      followSymlinks: options.followSymlinks,
+      submodules: options.submodules,
+      vendor: options.vendor,
+      maxFileBytes: options.maxFileBytes,
+      enrichers: options.enrichers
+        ?.split(',')
+        .map((name) => name.trim())
+        .filter((name) => name !== ''),
       onProgress,
+      onEnrich: () => {
+        spinner.text = 'Enriching...';
+      },
+      profiler,
     });
 
-    dbManager.close();
-
     spinner.succeed(chalk.green('Indexing complete!'));
+    if (structured) {
+      logger.info('Indexing complete', {
+        files: result.totalFiles,
+        entities: result.totalEntities,
+        relationships: result.totalRelationships,
+        durationMs: result.duration,
+        errors: result.errors.length,
+        skipped: result.skipped.length,
+        conflicts: result.conflicts.length,
+        renames: result.renames.length,
+        removed: result.tombstones.length,
+      });
+      for (const err of result.errors) {
+        logger.warn(`${err.filePath}: ${err.message}`, {
+          file: err.filePath,
+        });
+      }
+      for (const file of result.skipped) {
+        logger.warn(`Skipped ${file.filePath}: ${file.message}`, {
+          file: file.filePath,
+          reason: file.reason,
+        });
+      }
+    }
+    if (result.invalidated) {
+      console.log(
+        chalk.yellow(
+          'Configuration or schema changed since the last run; re-indexed all files.',
+        ),
+      );
+    }
 
     console.log('');
     console.log(chalk.bold('Summary:'));
@@ -94,8 +221,31 @@ function runIndex(targetPath: string, options: IndexOptions): void {
     console.log(
       `  Relationships:    ${chalk.cyan(String(result.totalRelationships))}`,
     );
+    if (result.renames.length > 0) {
+      console.log(
+        `  Renamed:          ${chalk.cyan(String(result.renames.length))}`,
+      );
+    }
+    if (result.tombstones.length > 0) {
+      console.log(
+        `  Removed:          ${chalk.cyan(String(result.tombstones.length))}`,
+      );
+    }
     console.log(`  Duration:         ${chalk.cyan(`${result.duration}ms`)}`);
-    console.log(`  Database:         ${chalk.cyan(dbPath)}`);
+    if (!options.dryRun) {
+      console.log(`  Database:         ${chalk.cyan(dbPath)}`);
+    }
+    if (source) {
+      const ref = source.ref ? `#${source.ref}` : '';
+      console.log(
+        `  Source:           ${chalk.cyan(`${source.url}${ref}`)} at ${source.commit.slice(0, 12)}`,
+      );
+    }
+    if (scopes.length > 0) {
+      console.log(
+        `  Scope:            ${chalk.cyan(scopes.map(formatScope).join(', '))}`,
+      );
+    }
 
     if (result.errors.length > 0) {
       console.log('');
@@ -111,17 +261,188 @@ function runIndex(targetPath: string, options: IndexOptions): void {
         );
       }
     }
+    if (result.skipped.length > 0) {
+      console.log('');
+      console.log(
+        chalk.yellow(
+          `${result.skipped.length} file(s) skipped by the scan limits:`,
+        ),
+      );
+      console.log(formatSkippedFiles(result.skipped.slice(0, 10)));
+      if (result.skipped.length > 10) {
+        console.log(
+          chalk.yellow(`  ... and ${result.skipped.length - 10} more`),
+        );
+      }
+    }
+    if (result.conflicts.length > 0) {
+      console.log('');
+      console.log(
+        chalk.yellow(
+          `${result.conflicts.length} annotation conflict(s), resolved in priority order:`,
+        ),
+      );
+      console.log(formatAnnotationConflicts(result.conflicts));
+    }
+    if (runs.length > 0) {
+      console.log('');
+      console.log(chalk.bold('Enrichers:'));
+      console.log(formatEnricherRuns(runs));
+    }
+    return result;
   } catch (err) {
+    if (isCancellationError(err)) {
+      spinner.fail(chalk.red('Indexing cancelled'));
+      reportError(
+        err,
+        'timeout',
+        'Files indexed so far are kept; re-run to resume.',
+      );
+      return undefined;
+    }
     spinner.fail(chalk.red('Indexing failed'));
-    console.error(chalk.red(err instanceof Error ? err.message : String(err)));
-    process.exitCode = 1;
+    reportError(err);
+    return undefined;
+  }
+}
+
+/**
+ * The directory to index for `targetPath`, fetching a git URL into the
+ * checkout cache first. Returns undefined after reporting an invalid ref
+ * or a failed fetch.
+ */
+function checkoutTarget(
+  targetPath: string,
+  cacheDir: string | undefined,
+): ScanTarget | undefined {
+  try {
+    if (!parseRemoteSource(targetPath)) return resolveScanTarget(targetPath);
+  } catch (err) {
+    reportError(err);
+    return undefined;
+  }
+  const spinner = ora({
+    text: `Fetching ${redactUrl(targetPath)}...`,
+    isSilent: isStructuredLogging(),
+  }).start();
+  try {
+    const target = resolveScanTarget(targetPath, cacheDir);
+    spinner.succeed(`Checked out ${target.source?.commit.slice(0, 12)}`);
+    return target;
+  } catch (err) {
+    spinner.fail(chalk.red('Fetch failed'));
+    reportError(
+      err,
+      'io',
+      'Check the URL and ref, and that git can reach the repository.',
+    );
+    return undefined;
+  }
+}
+
+/**
+ * Copy the index at `dbPath`, if any, to a temporary directory, so a dry
+ * run can update the copy incrementally and compare it with the original.
+ */
+function copyIndex(dbPath: string): string {
+  const planPath = join(
+    mkdtempSync(join(tmpdir(), 'knowgraph-plan-')),
+    'knowgraph.db',
+  );
+  for (const suffix of ['', '-wal']) {
+    if (existsSync(`${dbPath}${suffix}`)) {
+      copyFileSync(`${dbPath}${suffix}`, `${planPath}${suffix}`);
+    }
+  }
+  return planPath;
+}
+
+async function runIndex(
+  targetPath: string,
+  options: IndexOptions,
+  version: string,
+): Promise<void> {
+  let scopes: readonly ScanScope[];
+  try {
+    scopes = parseScopes(options.scope);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  const target = checkoutTarget(targetPath, options.cacheDir);
+  if (!target) return;
+  const configPath = join(target.rootDir, '.knowgraph.yml');
+  startTelemetry(configPath, version);
+  const outputDir = resolve(options.output);
+  const dbPath = join(outputDir, 'knowgraph.db');
+  // With the manifest's names, renamed entities diff as renames
+  const names = readGraphNames(configPath);
+  const before =
+    options.dryRun || isAuditEnabled(configPath)
+      ? readGraphSnapshot(dbPath, names, configPath)
+      : undefined;
+  const indexPath = options.dryRun ? copyIndex(dbPath) : dbPath;
+  if (!options.dryRun) mkdirSync(outputDir, { recursive: true });
+
+  const capture = options.profile
+    ? await startProfile(resolve(options.profile))
+    : undefined;
+  let result: IndexResult | undefined;
+  try {
+    result = indexRepository(
+      target,
+      options,
+      scopes,
+      indexPath,
+      capture?.timer,
+    );
+  } finally {
+    if (capture) await reportProfile(capture);
+  }
+
+  if (options.dryRun) {
+    try {
+      // A failed run has already been reported; its partial copy says nothing
+      if (before && !process.exitCode) {
+        const events = diffGraphs(
+          before,
+          readGraphSnapshot(indexPath, names, configPath),
+          result?.renames,
+        );
+        console.log('');
+        console.log(
+          formatPlan({
+            command: 'index',
+            changes: events.length > 0 ? [graphChange(events)] : [],
+            totals: [`${events.length} node(s) changed`],
+          }),
+        );
+      }
+    } finally {
+      rmSync(dirname(indexPath), { recursive: true, force: true });
+    }
+    return;
+  }
+
+  if (before) {
+    const events = diffGraphs(
+      before,
+      readGraphSnapshot(dbPath, names, configPath),
+      result?.renames,
+    );
+    recordAudit(configPath, 'index', [graphChange(events)]);
+  }
+  // A scoped scan's totals cover part of the repository, so skip history
+  if (result && !process.exitCode && scopes.length === 0) {
+    await recordScan(configPath, dbPath, result.totalFiles);
+    await publishToWarehouses(configPath, dbPath);
   }
 }
 
 export function registerIndexCommand(program: Command): void {
   program
     .command('index [path]')
-    .description('Scan repository and build the SQLite index')
+    .description('Scan a repository or git URL and build the SQLite index')
     .option('--output <dir>', 'Output directory', '.knowgraph')
     .option('--exclude <patterns>', 'Comma-separated glob patterns to exclude')
     .option(
@@ -131,7 +452,56 @@ export function registerIndexCommand(program: Command): void {
     )
     .option('--no-incremental', 'Force full re-index')
     .option('--verbose', 'Show detailed progress')
-    .action((path: string | undefined, options: IndexOptions) => {
-      runIndex(path ?? '.', options);
+    .option(
+      '--locale <code>',
+      'Locale for localized descriptions (default: i18n.default_locale)',
+    )
+    .option(
+      '--timeout <ms>',
+      'Abort the scan after this many milliseconds (default: timeouts.scan_ms)',
+    )
+    .option(
+      '--profile <dir>',
+      'Write CPU and heap profiles to <dir> and print phase timings',
+    )
+    .option(
+      '--dry-run',
+      'Index into a temporary copy and show how the graph would change',
+    )
+    .option(
+      '--cache-dir <dir>',
+      'Where git URL targets are checked out (default: ~/.cache/knowgraph/repos)',
+    )
+    .option(
+      '--scope <scope>',
+      'Index only path=<dir> or tag=<tag>; repeat to widen (default: everything)',
+      collectScope,
+    )
+    .option(
+      '--max-file-bytes <bytes>',
+      'Skip files larger than this (default: index.max_file_bytes or 1048576)',
+    )
+    .option(
+      '--follow-symlinks',
+      'Descend into symlinked directories outside the repository (default: index.follow_symlinks)',
+    )
+    .option(
+      '--submodules',
+      'Scan git submodules, each in its own namespace (default: index.submodules)',
+    )
+    .option(
+      '--vendor',
+      'Scan vendor/ directories as external nodes (default: index.vendor)',
+    )
+    .option(
+      '--enrichers <names>',
+      'Comma-separated enrichers to run, in order (default: the enrichers list)',
+    )
+    .action(async (path: string | undefined, options: IndexOptions) => {
+      try {
+        await runIndex(path ?? '.', options, program.version() ?? 'unknown');
+      } finally {
+        await flushTelemetry();
+      }
     });
 }
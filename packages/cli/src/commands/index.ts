@@ -9,3 +9,68 @@ export { registerSuggestCommand } from './suggest.js';
 export { registerHookCommand } from './hook.js';
 export { registerExportCommand } from './export.js';
 export { registerSyncCommand } from './sync.js';
+export { registerSloCommand } from './slo.js';
+export { registerCostCommand } from './cost.js';
+export { registerCheckLinksCommand } from './check-links.js';
+export { registerDecisionsCommand } from './decisions.js';
+export { registerGlossaryCommand } from './glossary.js';
+export { registerDomainsCommand } from './domains.js';
+export { registerDuplicatesCommand } from './duplicates.js';
+export { registerLintCommand } from './lint.js';
+export { registerWorkspacesCommand } from './workspaces.js';
+export { registerGoPackagesCommand } from './go-packages.js';
+export { registerAuditCommand } from './audit.js';
+export { registerPluginsCommand } from './plugins.js';
+export { registerBrowseCommand } from './browse.js';
+export { registerCompletionCommand } from './completion.js';
+export { registerExplainCommand } from './explain.js';
+export { registerOnboardCommand } from './onboard.js';
+export { registerAskCommand } from './ask.js';
+export { registerAnomaliesCommand } from './anomalies.js';
+export { registerBusFactorCommand } from './bus-factor.js';
+export { registerCheckCommand } from './check.js';
+export { registerStitchCommand } from './stitch.js';
+export { registerPatchCommand } from './patch.js';
+export { registerFsckCommand } from './fsck.js';
+export { registerOperatorCommand } from './operator.js';
+export { registerCheckConfigCommand } from './check-config.js';
+export { registerChangeCostCommand } from './change-cost.js';
+export { registerClustersCommand } from './clusters.js';
+export { registerGoApiCommand } from './go-api.js';
+export { registerSemverCommand } from './semver.js';
+export { registerLicensesCommand } from './licenses.js';
+export { registerScorecardCommand } from './scorecard.js';
+export { registerReportCommand } from './report.js';
+export { registerDeploymentsCommand } from './deployments.js';
+export { registerIncidentsCommand } from './incidents.js';
+export { registerGoTestsCommand } from './go-tests.js';
+export { registerImpactCommand } from './impact.js';
+export { registerDoctorCommand } from './doctor.js';
+export { registerTelemetryCommand } from './telemetry.js';
+export { registerBenchCommand } from './bench.js';
+export { registerGenCommand } from './gen.js';
+export { registerDraftCommand } from './draft.js';
+export { registerReviewCommand } from './review.js';
+export { registerDependentsCommand } from './dependents.js';
+export { registerPathCommand } from './path.js';
+export { registerSimulateCommand } from './simulate.js';
+export { registerVersionsCommand } from './versions.js';
+export { registerContractsCommand } from './contracts.js';
+export { registerCodegenCommand } from './codegen.js';
+export { registerNewCommand } from './new.js';
+export { registerDocsgenCommand } from './docsgen.js';
+export { registerPublishCommand } from './publish.js';
+export { registerRunCommand } from './run.js';
+export { registerEditCommand } from './edit.js';
+export { registerTombstonesCommand } from './tombstones.js';
+export { registerSinkCommand } from './sink.js';
+export { registerConfigCommand } from './config.js';
+export { registerVacanciesCommand } from './vacancies.js';
+export { registerFreshnessCommand } from './freshness.js';
+export { registerCompactHistoryCommand } from './compact-history.js';
+export { registerResolveCommand } from './resolve.js';
+export { registerAnnotateCommand } from './annotate.js';
+export { registerBundleCommand } from './bundle.js';
+export { registerGrepCommand } from './grep.js';
+export { registerHotspotsCommand } from './hotspots.js';
+export { registerConformCommand } from './conform.js';
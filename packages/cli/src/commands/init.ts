@@ -9,16 +9,20 @@
  *   business_goal: Provide guided onboarding for new KnowGraph users
  *   domain: cli
  */
-import { writeFileSync, existsSync } from 'node:fs';
+import { readFileSync, writeFileSync, existsSync } from 'node:fs';
 import { resolve, basename } from 'node:path';
 import type { Command } from 'commander';
 import chalk from 'chalk';
 import { stringify } from 'yaml';
+import { fileChange } from '@know-graph/core';
+import { recordAudit } from '../utils/audit.js';
 import { detectLanguages, suggestFiles } from '../utils/detect.js';
+import { formatPlan } from '../utils/plan.js';
 
 interface InitOptions {
   readonly name?: string;
   readonly yes?: boolean;
+  readonly dryRun?: boolean;
 }
 
 function generateManifest(
@@ -29,7 +33,6 @@ function generateManifest(
     version: '1.0',
     name: projectName,
     languages: [...languages],
-    include: ['**/*'],
     exclude: ['node_modules', '.git', 'dist', 'build', '__pycache__'],
     index: {
       output_dir: '.knowgraph',
@@ -42,7 +45,7 @@ async function runInit(options: InitOptions): Promise<void> {
   const dir = resolve('.');
   const configPath = resolve('.knowgraph.yml');
 
-  if (existsSync(configPath) && !options.yes) {
+  if (existsSync(configPath) && !options.yes && !options.dryRun) {
     console.log(
       chalk.yellow('.knowgraph.yml already exists. Use -y to overwrite.'),
     );
@@ -87,8 +90,24 @@ async function runInit(options: InitOptions): Promise<void> {
   // Step 3: Generate .knowgraph.yml
   const manifest = generateManifest(projectName, languages);
   const yamlContent = stringify(manifest);
+  const previous = existsSync(configPath)
+    ? readFileSync(configPath, 'utf-8')
+    : undefined;
+  const change = fileChange('.knowgraph.yml', previous, yamlContent);
+  if (options.dryRun) {
+    console.log('');
+    console.log(
+      formatPlan({
+        command: 'init',
+        changes: change ? [change] : [],
+        totals: [`${change ? 1 : 0} file(s) written`],
+      }),
+    );
+    return;
+  }
   writeFileSync(configPath, yamlContent, 'utf-8');
   console.log(`\nCreated ${chalk.green('.knowgraph.yml')}`);
+  recordAudit(configPath, 'init', [change]);
 
   // Step 4: Suggest high-impact files
   const suggested = suggestFiles(dir);
@@ -117,6 +136,7 @@ export function registerInitCommand(program: Command): void {
     .description('Initialize KnowGraph in the current directory')
     .option('--name <name>', 'Project name')
     .option('-y, --yes', 'Non-interactive mode, use defaults')
+    .option('--dry-run', 'Show the .knowgraph.yml that would be written')
     .action((options: InitOptions) => {
       runInit(options);
     });
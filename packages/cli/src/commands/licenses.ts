@@ -0,0 +1,124 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that reports modules whose dependencies are under licenses incompatible with their own
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, licensing, sbom, compliance]
+ * context:
+ *   business_goal: Give open source compliance reviews one list of modules whose dependencies need a license decision
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { checkLicenses, parseSbom } from '@know-graph/core';
+import type {
+  LicensedDependency,
+  LicenseReport,
+  SbomComponent,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+
+interface LicensesCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly license?: string;
+}
+
+function dependencyLabel(dep: LicensedDependency): string {
+  const source = dep.source === 'annotation' ? ' (in repo)' : '';
+  return `${dep.name}${source} ${chalk.dim(dep.license ?? 'no license')}`;
+}
+
+export function formatLicenseReport(report: LicenseReport): string {
+  if (report.modules.length === 0) {
+    return chalk.green(
+      `No license issues in ${report.checked} module(s) with licensed dependencies.`,
+    );
+  }
+  const lines = [
+    chalk.bold(`License issues in ${report.modules.length} module(s)`) +
+      chalk.dim(` (${report.checked} with licensed dependencies checked)`),
+  ];
+  for (const mod of report.modules) {
+    const origin = mod.origin ? `, ${mod.origin}` : '';
+    lines.push('');
+    lines.push(
+      `${chalk.bold(mod.name)} ${chalk.dim(`${mod.filePath} (${mod.license ?? 'no license'}${origin})`)}`,
+    );
+    if (mod.missingLicense) {
+      lines.push(
+        chalk.yellow(`  ${mod.origin} code without a license annotation`),
+      );
+    }
+    for (const conflict of mod.conflicts) {
+      lines.push(
+        `  ${chalk.red('conflict')} ${dependencyLabel(conflict)}: ${conflict.reason}`,
+      );
+    }
+    for (const dep of mod.unknown) {
+      lines.push(`  ${chalk.yellow('unknown')}  ${dependencyLabel(dep)}`);
+    }
+  }
+  return lines.join('\n');
+}
+
+function runLicenses(
+  sboms: readonly string[],
+  options: LicensesCommandOptions,
+): void {
+  let components: SbomComponent[];
+  try {
+    components = sboms.flatMap((file) => [
+      ...parseSbom(readFileSync(resolve(file), 'utf-8')),
+    ]);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath);
+  if (!entities) return;
+
+  const report = checkLicenses(
+    entities,
+    buildGraph(dbPath, entities),
+    components,
+    options.license ? { defaultLicense: options.license } : {},
+  );
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else {
+    console.log(formatLicenseReport(report));
+  }
+  if (report.conflicts > 0) {
+    reportCheckFailure(
+      `${report.conflicts} dependency license conflict(s)`,
+      'policy',
+      { conflicts: report.conflicts, modules: report.modules.length },
+    );
+  }
+}
+
+export function registerLicensesCommand(program: Command): void {
+  program
+    .command('licenses')
+    .description(
+      'Report modules whose dependencies, from SBOMs and annotations, have incompatible licenses',
+    )
+    .argument('[sboms...]', 'CycloneDX or SPDX JSON SBOM files')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option(
+      '--license <spdx>',
+      'License of modules that declare none, such as the repository license',
+    )
+    .action((sboms: string[], options: LicensesCommandOptions) => {
+      runLicenses(sboms, options);
+    });
+}
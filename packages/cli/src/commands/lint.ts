@@ -0,0 +1,217 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lints annotation quality and optionally rewrites fixable issues
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, lint, autofix, quality]
+ * context:
+ *   business_goal: Keep annotations useful by flagging vague descriptions, personal owners, and tag noise
+ *   domain: cli
+ */
+import { relative, resolve } from 'node:path';
+import { readFileSync, statSync } from 'node:fs';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { parse as parseYaml } from 'yaml';
+import {
+  createDefaultLintRules,
+  createLinter,
+  createPluginLintRule,
+  fileChange,
+} from '@know-graph/core';
+import type {
+  AuditChange,
+  LintIssue,
+  LintResult,
+  RuleSeverities,
+} from '@know-graph/core';
+import { recordAudit } from '../utils/audit.js';
+import { formatJson } from '../utils/format.js';
+import {
+  readDescriptionThresholds,
+  readPlugins,
+  readRuleSeverities,
+} from '../utils/manifest.js';
+import { formatPlan } from '../utils/plan.js';
+import { reportError, reportCheckFailure } from '../utils/errors.js';
+
+interface LintCommandOptions {
+  readonly fix?: boolean;
+  readonly dryRun?: boolean;
+  readonly format: string;
+  readonly ownerMap?: string;
+  readonly minDescription: string;
+}
+
+export function formatLintResult(result: LintResult): string {
+  const lines: string[] = result.issues.map((issue) => {
+    const location = chalk.cyan(`${issue.filePath}:${issue.line}`);
+    const fix = issue.fix ? chalk.green(` (fixable: ${issue.fix})`) : '';
+    return `${location} ${chalk.dim(issue.rule)}: ${issue.message}${fix}`;
+  });
+
+  if (result.fixedCount > 0) {
+    lines.push(
+      chalk.green(
+        `Fixed ${result.fixedCount} issue(s) in ${result.fixedFiles.length} file(s)`,
+      ),
+    );
+  }
+
+  for (const { issue, reason } of result.suppressed) {
+    const location = `${issue.filePath}:${issue.line}`;
+    lines.push(
+      chalk.dim(
+        `${location} [SUPPRESSED] ${issue.rule}: ${reason ?? 'no reason given'}`,
+      ),
+    );
+  }
+
+  const suppressed =
+    result.suppressed.length > 0
+      ? `, ${result.suppressed.length} suppressed`
+      : '';
+  const summary = `${result.issues.length} issue(s) in ${result.fileCount} file(s)${suppressed}`;
+  if (result.issues.length === 0) {
+    lines.push(chalk.green(summary));
+  } else {
+    const hint =
+      result.fixableCount > 0
+        ? `, ${result.fixableCount} fixable with --fix`
+        : '';
+    lines.push(chalk.yellow(`${summary}${hint}`));
+  }
+  return lines.join('\n');
+}
+
+function loadOwnerMap(path: string): Record<string, string> {
+  const parsed: unknown = parseYaml(readFileSync(path, 'utf-8'));
+  if (typeof parsed !== 'object' || parsed === null || Array.isArray(parsed)) {
+    throw new Error(`Owner map must be a mapping of person to team: ${path}`);
+  }
+  return Object.fromEntries(
+    Object.entries(parsed).map(([person, team]) => [person, String(team)]),
+  );
+}
+
+/**
+ * Drop issues from rules the manifest turns `off`. Built-in rules are left
+ * out of the linter so their fixes do not run; plugin issues are filtered
+ * here because a plugin reports under rule names of its own.
+ */
+function withoutDisabledRules(
+  result: LintResult,
+  severities: RuleSeverities,
+): LintResult {
+  const enabled = (issue: LintIssue): boolean =>
+    severities[issue.rule] !== 'off';
+  const issues = result.issues.filter(enabled);
+  return {
+    ...result,
+    issues,
+    fixableCount: issues.filter((issue) => issue.fix).length,
+    suppressed: result.suppressed.filter(({ issue }) => enabled(issue)),
+  };
+}
+
+function runLint(targetPath: string, options: LintCommandOptions): void {
+  const absPath = resolve(targetPath);
+
+  try {
+    statSync(absPath);
+  } catch {
+    reportError(`Path not found: ${absPath}`, 'io');
+    return;
+  }
+
+  const minDescriptionLength = Number(options.minDescription);
+  if (!Number.isInteger(minDescriptionLength) || minDescriptionLength < 1) {
+    reportError('--min-description must be a positive integer', 'usage');
+    return;
+  }
+
+  const changes: AuditChange[] = [];
+  try {
+    const ownerMap = options.ownerMap
+      ? loadOwnerMap(resolve(options.ownerMap))
+      : {};
+    const configPath = resolve('.knowgraph.yml');
+    const severities = readRuleSeverities(configPath);
+    const linter = createLinter(
+      createDefaultLintRules({
+        ownerMap,
+        minDescriptionLength,
+        descriptionThresholds: readDescriptionThresholds(configPath),
+      }).filter((rule) => severities[rule.name] !== 'off'),
+      readPlugins(configPath)
+        .filter((plugin) => plugin.rules)
+        .map(createPluginLintRule),
+    );
+    const linted = linter.lint(absPath, {
+      fix: options.fix || options.dryRun,
+      dryRun: options.dryRun,
+      onFix: (filePath, before, after) => {
+        const change = fileChange(relative('.', filePath), before, after);
+        if (change) changes.push(change);
+      },
+    });
+
+    const result = withoutDisabledRules(linted, severities);
+
+    if (options.dryRun) {
+      const plan = {
+        command: 'lint --fix',
+        changes,
+        totals: [
+          `${result.fixedCount} issue(s) fixed`,
+          `${result.fixedFiles.length} file(s) rewritten`,
+        ],
+      };
+      console.log(
+        options.format === 'json' ? formatJson(plan, true) : formatPlan(plan),
+      );
+      return;
+    }
+
+    if (options.format === 'json') {
+      console.log(formatJson(result, true));
+    } else {
+      console.log(formatLintResult(result));
+    }
+
+    if (result.issues.length > 0) {
+      reportCheckFailure(`${result.issues.length} lint issue(s)`, 'policy', {
+        issues: result.issues.length,
+        fixable: result.fixableCount,
+      });
+    }
+  } catch (err) {
+    reportError(err);
+  }
+
+  if (options.fix && !options.dryRun) {
+    recordAudit(resolve('.knowgraph.yml'), 'lint', changes);
+  }
+}
+
+export function registerLintCommand(program: Command): void {
+  program
+    .command('lint [path]')
+    .description('Lint annotation quality and suggest or apply fixes')
+    .option('--fix', 'Rewrite annotations to apply available fixes')
+    .option('--dry-run', 'Show the changes --fix would make without writing')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option(
+      '--owner-map <file>',
+      'YAML mapping of personal owners to team names, used by --fix',
+    )
+    .option(
+      '--min-description <length>',
+      'Minimum description length in characters',
+      '10',
+    )
+    .action((path: string | undefined, options: LintCommandOptions) => {
+      runLint(path ?? '.', options);
+    });
+}
@@ -0,0 +1,185 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that scaffolds a new Go module with an annotated doc.go and handler skeletons from an org template
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, scaffold, templates, go]
+ * context:
+ *   business_goal: Have new code born annotated rather than retrofitted
+ *   domain: cli
+ */
+import { existsSync, mkdirSync, writeFileSync } from 'node:fs';
+import { dirname, join, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { fileChange, scaffoldModule } from '@know-graph/core';
+import type { ModuleScaffold, ModuleTemplateConfig } from '@know-graph/core';
+import { recordAudit } from '../utils/audit.js';
+import { reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import { readModuleTemplates } from '../utils/manifest.js';
+import { formatPlan } from '../utils/plan.js';
+
+interface NewModuleOptions {
+  readonly description?: string;
+  readonly template?: string;
+  readonly owner?: string;
+  readonly handler?: readonly string[];
+  readonly dependsOn?: readonly string[];
+  readonly config: string;
+  readonly dryRun?: boolean;
+  readonly format: string;
+}
+
+export function formatModuleScaffold(scaffold: ModuleScaffold): string {
+  const lines = [
+    chalk.green(
+      `Created module ${scaffold.directory} (package ${scaffold.packageName})`,
+    ),
+  ];
+  for (const file of scaffold.files) {
+    lines.push(`  ${chalk.cyan(file.path)}`);
+  }
+  lines.push('', chalk.dim('Add it to the graph with: knowgraph index'));
+  return lines.join('\n');
+}
+
+/**
+ * The template named `name`, or the manifest's `default` template when no
+ * name is given. Undefined, after reporting, for an unknown name.
+ */
+function selectTemplate(
+  templates: Readonly<Record<string, ModuleTemplateConfig>>,
+  name: string | undefined,
+): ModuleTemplateConfig | null | undefined {
+  if (name === undefined) return templates.default ?? null;
+  const template = templates[name];
+  if (!template) {
+    const known = Object.keys(templates);
+    reportError(
+      `No template named ${name}`,
+      'usage',
+      known.length > 0
+        ? `Templates in .knowgraph.yml: ${known.join(', ')}`
+        : 'Add one under templates in .knowgraph.yml',
+    );
+    return undefined;
+  }
+  return template;
+}
+
+function runNewModule(name: string, options: NewModuleOptions): void {
+  if (!options.description) {
+    reportError(
+      '--description is required',
+      'usage',
+      'Say what the module does; it becomes the annotation of doc.go',
+    );
+    return;
+  }
+  const { description } = options;
+  const configPath = resolve(options.config);
+  const rootDir = dirname(configPath);
+  let scaffold: ModuleScaffold;
+  try {
+    const template = selectTemplate(
+      readModuleTemplates(configPath),
+      options.template,
+    );
+    if (template === undefined) return;
+    scaffold = scaffoldModule({
+      name,
+      description,
+      ...(options.owner ? { owner: options.owner } : {}),
+      ...(options.handler ? { handlers: options.handler } : {}),
+      ...(options.dependsOn ? { services: options.dependsOn } : {}),
+      ...(template ? { template } : {}),
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const existing = scaffold.files.find((file) =>
+    existsSync(join(rootDir, file.path)),
+  );
+  if (existing) {
+    reportError(
+      `${existing.path} already exists`,
+      'usage',
+      'Pick another module name, or move the existing files first',
+    );
+    return;
+  }
+  const changes = scaffold.files.flatMap(
+    (file) => fileChange(file.path, undefined, file.content) ?? [],
+  );
+  if (options.dryRun) {
+    console.log(
+      formatPlan({
+        command: 'new module',
+        changes,
+        totals: [`${scaffold.files.length} file(s) created`],
+      }),
+    );
+    return;
+  }
+
+  try {
+    for (const file of scaffold.files) {
+      const path = join(rootDir, file.path);
+      mkdirSync(dirname(path), { recursive: true });
+      writeFileSync(path, file.content, 'utf-8');
+    }
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(
+      formatJson(
+        {
+          directory: scaffold.directory,
+          package: scaffold.packageName,
+          metadata: scaffold.metadata,
+          files: scaffold.files.map((file) => file.path),
+        },
+        true,
+      ),
+    );
+  } else {
+    console.log(formatModuleScaffold(scaffold));
+  }
+  recordAudit(configPath, 'new module', changes);
+}
+
+export function registerNewCommand(program: Command): void {
+  const newCmd = program
+    .command('new')
+    .description('Create new code that is annotated from the start');
+
+  newCmd
+    .command('module <name>')
+    .description(
+      'Scaffold a Go module with an annotated doc.go and handler skeletons from a template in .knowgraph.yml',
+    )
+    .option('--description <text>', 'What the module does (required)')
+    .option(
+      '--template <name>',
+      'Template in .knowgraph.yml (default: the one named default)',
+    )
+    .option('--owner <owner>', "Owner, instead of the template's")
+    .option(
+      '--handler <names...>',
+      "Handlers to write skeletons for, instead of the template's",
+    )
+    .option('--depends-on <services...>', 'Services the module depends on')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--dry-run', 'Show the files without writing them')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((name: string, options: NewModuleOptions) => {
+      runNewModule(name, options);
+    });
+}
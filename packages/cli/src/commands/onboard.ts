@@ -0,0 +1,98 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that writes a Markdown onboarding guide with a suggested reading order for an area
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, onboarding, markdown]
+ * context:
+ *   business_goal: Give new team members a sensible order to read an unfamiliar area in
+ *   domain: cli
+ */
+import { writeFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  buildOnboardingPath,
+  formatOnboardingMarkdown,
+  resolveOnboardingArea,
+  selectAreaEntities,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface OnboardCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly limit?: string;
+  readonly output?: string;
+}
+
+function runOnboard(target: string, options: OnboardCommandOptions): void {
+  if (options.format !== 'markdown' && options.format !== 'json') {
+    reportError(
+      `Unknown format "${options.format}" (use markdown|json)`,
+      'usage',
+    );
+    return;
+  }
+  const limit = options.limit === undefined ? undefined : Number(options.limit);
+  if (limit !== undefined && (!Number.isInteger(limit) || limit < 1)) {
+    reportError('--limit must be a positive integer', 'usage');
+    return;
+  }
+
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath);
+  if (!entities) return;
+
+  const area = resolveOnboardingArea(entities, target);
+  if (selectAreaEntities(entities, area).length === 0) {
+    reportError(
+      `No entities found for ${area.kind} "${area.value}"`,
+      'usage',
+      "Name a domain, a tag, or a path, or prefix it with 'domain:', 'tag:', or 'module:'.",
+    );
+    return;
+  }
+
+  try {
+    const path = buildOnboardingPath(
+      entities,
+      buildGraph(dbPath, entities),
+      area,
+      { limit },
+    );
+    const content =
+      options.format === 'json'
+        ? `${formatJson(path, true)}\n`
+        : formatOnboardingMarkdown(path);
+    if (options.output) {
+      writeFileSync(resolve(options.output), content, 'utf-8');
+      console.log(
+        chalk.green(
+          `Wrote a ${path.steps.length}-step guide to ${options.output}`,
+        ),
+      );
+    } else {
+      process.stdout.write(content);
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerOnboardCommand(program: Command): void {
+  program
+    .command('onboard <area>')
+    .description('Write an onboarding guide with a reading order for an area')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (markdown|json)', 'markdown')
+    .option('--limit <n>', 'Number of entities to include')
+    .option('--output <file>', 'Write to a file instead of stdout')
+    .action((area: string, options: OnboardCommandOptions) => {
+      runOnboard(area, options);
+    });
+}
@@ -0,0 +1,199 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that runs the Kubernetes operator, rescanning the repositories KnowGraphScan resources name and publishing ownership to their workloads
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, kubernetes, operator, ownership]
+ * context:
+ *   business_goal: Let cluster tooling see who owns the code behind each workload
+ *   domain: cli
+ */
+import { mkdirSync } from 'node:fs';
+import { dirname, join, resolve } from 'node:path';
+import type { Command } from 'commander';
+import {
+  createKubernetesClient,
+  createQueryEngine,
+  inClusterClientOptions,
+  reconcileScans,
+  redactUrl,
+} from '@know-graph/core';
+import type {
+  KnowGraphScan,
+  KubernetesClientOptions,
+  ReconcileResult,
+  RepoScan,
+} from '@know-graph/core';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { openDatabase } from '../utils/db.js';
+import { indexInto } from '../utils/indexing.js';
+import { configureLogging, getLogger } from '../utils/logging.js';
+import { defaultCacheDir, resolveScanTarget } from '../utils/remote.js';
+
+interface OperatorCommandOptions {
+  readonly apiUrl?: string;
+  readonly namespace?: string;
+  readonly interval: string;
+  readonly dataDir: string;
+  readonly cacheDir?: string;
+  readonly once?: boolean;
+}
+
+/**
+ * Incrementally index the repository `scan` names into its own database
+ * under `dataDir`, fetching a git URL into `cacheDir` first, and read back
+ * its entities.
+ */
+export function scanRepository(
+  scan: KnowGraphScan,
+  dataDir: string,
+  cacheDir: string,
+): RepoScan {
+  const { repo, ref } = scan.spec;
+  const { rootDir, source } = resolveScanTarget(
+    ref ? `${repo}#${ref}` : repo,
+    cacheDir,
+  );
+  const dbPath = join(dataDir, scan.namespace, `${scan.name}.db`);
+  mkdirSync(dirname(dbPath), { recursive: true });
+  indexInto(rootDir, dbPath, { incremental: true, source });
+  const dbManager = openDatabase(dbPath, join(rootDir, '.knowgraph.yml'), {
+    readOnly: true,
+  });
+  try {
+    const entities = createQueryEngine(dbManager).getAll();
+    return source ? { entities, commit: source.commit } : { entities };
+  } finally {
+    dbManager.close();
+  }
+}
+
+export function formatReconcileResult(result: ReconcileResult): string {
+  const { scan, status } = result;
+  const name = `${scan.namespace}/${scan.name}`;
+  if (status.phase === 'Failed') return `${name}: failed: ${status.message}`;
+  const at = status.commit ? ` at ${status.commit.slice(0, 12)}` : '';
+  return `${name}: ${status.entities} entities${at}, ${status.workloads} workload(s) annotated`;
+}
+
+function clientOptions(
+  options: OperatorCommandOptions,
+): KubernetesClientOptions | undefined {
+  const base = options.apiUrl
+    ? { apiUrl: options.apiUrl, token: process.env.KNOWGRAPH_KUBE_TOKEN }
+    : inClusterClientOptions();
+  if (!base) return undefined;
+  return options.namespace ? { ...base, namespace: options.namespace } : base;
+}
+
+function wait(ms: number, signal: AbortSignal): Promise<void> {
+  return new Promise((done) => {
+    const timer = setTimeout(done, ms);
+    signal.addEventListener(
+      'abort',
+      () => {
+        clearTimeout(timer);
+        done();
+      },
+      { once: true },
+    );
+  });
+}
+
+async function runOperator(options: OperatorCommandOptions): Promise<void> {
+  const interval = Number(options.interval);
+  if (!Number.isInteger(interval) || interval < 1) {
+    reportError('--interval must be a positive number of seconds', 'usage');
+    return;
+  }
+  const kube = clientOptions(options);
+  if (!kube) {
+    reportError(
+      'Not running in a Kubernetes pod',
+      'usage',
+      'Pass --api-url, with a token in KNOWGRAPH_KUBE_TOKEN, to run outside a cluster.',
+    );
+    return;
+  }
+  const client = createKubernetesClient(kube);
+  const dataDir = resolve(options.dataDir);
+  const cacheDir = options.cacheDir ?? defaultCacheDir();
+  const scanRepo = async (scan: KnowGraphScan) =>
+    scanRepository(scan, dataDir, cacheDir);
+
+  // Stop between passes on Ctrl-C or the kubelet's SIGTERM
+  const controller = new AbortController();
+  for (const signal of ['SIGINT', 'SIGTERM'] as const) {
+    process.once(signal, () => controller.abort());
+  }
+  // A long-running process: stamp text records with their time too
+  configureLogging({ timestamps: true });
+  const logger = getLogger();
+  logger.info(
+    `Watching KnowGraphScan resources at ${redactUrl(kube.apiUrl)}` +
+      (kube.namespace ? ` in ${kube.namespace}` : '') +
+      (options.once ? '' : `, every ${interval}s`),
+  );
+
+  while (!controller.signal.aborted) {
+    let results: readonly ReconcileResult[];
+    try {
+      results = await reconcileScans(client, scanRepo);
+    } catch (err) {
+      if (options.once) {
+        reportError(err);
+        return;
+      }
+      // The API server may be restarting; try again next pass.
+      const message = err instanceof Error ? err.message : String(err);
+      logger.warn(`Reconcile failed: ${message}`);
+      results = [];
+    }
+    for (const result of results) {
+      const fields = {
+        scan: `${result.scan.namespace}/${result.scan.name}`,
+        phase: result.status.phase,
+      };
+      if (result.status.phase === 'Failed') {
+        logger.warn(formatReconcileResult(result), fields);
+      } else {
+        logger.info(formatReconcileResult(result), fields);
+      }
+    }
+    if (options.once) {
+      const failed = results.filter((r) => r.status.phase === 'Failed');
+      if (failed.length > 0) {
+        reportCheckFailure(`${failed.length} scan(s) failed`, 'io', {
+          failed: failed.map((r) => `${r.scan.namespace}/${r.scan.name}`),
+        });
+      }
+      return;
+    }
+    await wait(interval * 1000, controller.signal);
+  }
+}
+
+export function registerOperatorCommand(program: Command): void {
+  program
+    .command('operator')
+    .description(
+      'Rescan KnowGraphScan repositories and publish ownership to matching Deployments',
+    )
+    .option(
+      '--api-url <url>',
+      'Kubernetes API server (default: the in-cluster service)',
+    )
+    .option('--namespace <name>', 'Only watch scans in this namespace')
+    .option('--interval <seconds>', 'Seconds between reconcile passes', '300')
+    .option(
+      '--data-dir <path>',
+      'Directory for one index per scan',
+      '.knowgraph/operator',
+    )
+    .option('--cache-dir <path>', 'Directory for git checkouts')
+    .option('--once', 'Reconcile every scan once and exit')
+    .action(async (options: OperatorCommandOptions) => {
+      await runOperator(options);
+    });
+}
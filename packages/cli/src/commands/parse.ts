@@ -16,6 +16,7 @@ import chalk from 'chalk';
 import { createDefaultRegistry } from '@know-graph/core';
 import type { ParseResult, ParseDiagnostic } from '@know-graph/core';
 import { formatJson } from '../utils/format.js';
+import { reportError, reportCheckFailure } from '../utils/errors.js';
 
 function collectFilePaths(targetPath: string): readonly string[] {
   const stat = statSync(targetPath);
@@ -71,8 +72,7 @@ function runParse(targetPath: string, options: ParseOptions): void {
   try {
     statSync(absPath);
   } catch {
-    console.error(chalk.red(`Error: Path not found: ${absPath}`));
-    process.exitCode = 1;
+    reportError(`Path not found: ${absPath}`, 'io');
     return;
   }
 
@@ -116,7 +116,10 @@ function runParse(targetPath: string, options: ParseOptions): void {
       );
     }
     console.error('');
-    process.exitCode = 1;
+    reportCheckFailure(
+      `${allDiagnostics.length} annotation(s) failed schema validation`,
+      'schema',
+    );
   }
 
   if (options.validate) {
@@ -132,7 +135,7 @@ function runParse(targetPath: string, options: ParseOptions): void {
       }
     }
     if (hasErrors) {
-      process.exitCode = 1;
+      reportCheckFailure('Annotations are missing descriptions', 'schema');
     }
   }
 
@@ -144,12 +147,10 @@ function runParse(targetPath: string, options: ParseOptions): void {
         printSummary(allResults.length, fileCount);
       })
       .catch(() => {
-        console.error(
-          chalk.red(
-            'Error: yaml package not installed. Use --format json or install yaml.',
-          ),
+        reportError(
+          'yaml package not installed. Use --format json or install yaml.',
+          'internal',
         );
-        process.exitCode = 1;
       });
   } else {
     const pretty = options.pretty ?? false;
@@ -0,0 +1,138 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI commands that apply a graph patch to the graph it was made from and push graphs to a registry server as patches
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, patch, graph, registry]
+ * context:
+ *   business_goal: Push graph changes to a registry in kilobytes instead of re-sending full snapshots
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  applyGraphPatch,
+  parseGraphPatch,
+  pushGraph,
+} from '@know-graph/core';
+import type { GraphPatch, GraphPushReport } from '@know-graph/core';
+import { reportError } from '../utils/errors.js';
+import { readGraphFile, writeGraphFile } from './stitch.js';
+
+interface PatchCommandOptions {
+  readonly output?: string;
+}
+
+interface PushCommandOptions {
+  readonly name?: string;
+  readonly base?: string;
+  readonly tokenEnv: string;
+}
+
+export function formatPatchSummary(patch: GraphPatch): string {
+  const { nodes, edges } = patch;
+  return [
+    `${nodes.upsert.length} nodes added or changed, ${nodes.remove.length} removed`,
+    `${edges.upsert.length} edges added or changed, ${edges.remove.length} removed`,
+  ].join('; ');
+}
+
+function runPatch(
+  graphPath: string,
+  patchPath: string,
+  options: PatchCommandOptions,
+): void {
+  const output = options.output ?? graphPath;
+  let patch: GraphPatch;
+  try {
+    patch = parseGraphPatch(readFileSync(resolve(patchPath), 'utf-8'));
+    const graph = applyGraphPatch(readGraphFile(resolve(graphPath)), patch);
+    writeGraphFile(resolve(output), graph);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  console.log(formatPatchSummary(patch));
+  console.log(chalk.dim(`Wrote ${output}`));
+}
+
+/** What a push sent, as `push` reports it. */
+export function formatPushSummary(report: GraphPushReport): string {
+  const sent = report.patch
+    ? `a patch (${report.bytes} bytes): ${formatPatchSummary(report.patch)}`
+    : `the full graph (${report.bytes} bytes)`;
+  return `Pushed ${sent}`;
+}
+
+async function runPush(
+  graphPath: string,
+  server: string,
+  options: PushCommandOptions,
+): Promise<void> {
+  if (!options.name) {
+    reportError('--name is required', 'usage');
+    return;
+  }
+  const token = process.env[options.tokenEnv];
+  if (!token) {
+    reportError(
+      `Environment variable ${options.tokenEnv} is not set`,
+      'usage',
+      `Set ${options.tokenEnv} to a token with a registry admin role.`,
+    );
+    return;
+  }
+  let report: GraphPushReport;
+  try {
+    report = await pushGraph(readGraphFile(resolve(graphPath)), {
+      server,
+      name: options.name,
+      token,
+      base: options.base ? readGraphFile(resolve(options.base)) : undefined,
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  console.log(formatPushSummary(report));
+  console.log(chalk.dim(`Stored ${options.name} as ${report.digest}`));
+}
+
+export function registerPatchCommand(program: Command): void {
+  program
+    .command('patch <graph> <patch>')
+    .description(
+      'Apply a patch from export --format patch to the graph it was made from',
+    )
+    .option(
+      '--output <file>',
+      'Where to write the patched graph (.kgs for a snapshot; default: <graph>)',
+    )
+    .action((graph: string, patch: string, options: PatchCommandOptions) => {
+      runPatch(graph, patch, options);
+    });
+
+  program
+    .command('push <graph> <server>')
+    .description(
+      'Push a graph to a registry server, as a patch from the last push when given one',
+    )
+    .option('--name <name>', 'Name the server keeps the graph under')
+    .option(
+      '--base <file>',
+      'The graph last pushed (.json or .kgs); sends only the changes since',
+    )
+    .option(
+      '--token-env <name>',
+      'Environment variable holding the bearer token',
+      'KNOWGRAPH_TOKEN',
+    )
+    .action(
+      async (graph: string, server: string, options: PushCommandOptions) => {
+        await runPush(graph, server, options);
+      },
+    );
+}
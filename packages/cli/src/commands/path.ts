@@ -0,0 +1,153 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that prints the k shortest dependency paths between two nodes with the provenance of each edge
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, graph, paths, coupling, review]
+ * context:
+ *   business_goal: Explain surprising coupling found in reviews by showing how one component reaches another
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { findShortestPaths } from '@know-graph/core';
+import type {
+  DependencyGraph,
+  GraphEdge,
+  GraphNode,
+  GraphPath,
+} from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { parseEdgeFilter } from '../utils/edge-filter.js';
+import { checkEnvironment } from '../utils/environments.js';
+import { reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import { resolveGraphNode } from '../utils/nodes.js';
+
+interface PathCommandOptions {
+  readonly from?: string;
+  readonly to?: string;
+  readonly k: string;
+  readonly db: string;
+  readonly format: string;
+  readonly provenance?: string;
+  readonly minConfidence?: string;
+  readonly protocol?: string;
+  readonly criticality?: string;
+  readonly env?: string;
+}
+
+function edgeNote(edge: GraphEdge): string {
+  const confidence = edge.confidence < 1 ? ` ${edge.confidence}` : '';
+  const annotations = [edge.protocol, edge.criticality, edge.purpose]
+    .filter((value) => value !== undefined)
+    .map((value) => `, ${value}`)
+    .join('');
+  return chalk.dim(
+    `${edge.kind}, ${edge.provenance}${confidence}${annotations}`,
+  );
+}
+
+export function formatPaths(
+  from: GraphNode,
+  to: GraphNode,
+  paths: readonly GraphPath[],
+): string {
+  if (paths.length === 0) {
+    return chalk.dim(`${from.name} does not depend on ${to.name}.`);
+  }
+  const lines = [
+    chalk.bold(`${paths.length} path(s) from ${from.name} to ${to.name}`),
+  ];
+  paths.forEach((path, i) => {
+    const hops = path.edges.length;
+    const length = `${hops} hop${hops === 1 ? '' : 's'}`;
+    lines.push('', `${i + 1}. ${chalk.dim(length)}`);
+    lines.push(`   ${chalk.cyan(path.nodes[0].name)}`);
+    path.edges.forEach((edge, j) => {
+      lines.push(
+        `     -> ${chalk.cyan(path.nodes[j + 1].name)} ${edgeNote(edge)}`,
+      );
+    });
+  });
+  return lines.join('\n');
+}
+
+function runPath(options: PathCommandOptions): void {
+  if (!options.from || !options.to) {
+    reportError('Both --from and --to are required', 'usage');
+    return;
+  }
+  const k = Number(options.k);
+  if (!Number.isInteger(k) || k < 1) {
+    reportError('--k must be a positive integer', 'usage');
+    return;
+  }
+
+  let graph: DependencyGraph;
+  let filter: ReturnType<typeof parseEdgeFilter>;
+  try {
+    filter = parseEdgeFilter(options.provenance, options.minConfidence, {
+      protocol: options.protocol,
+      criticality: options.criticality,
+    });
+    const dbPath = resolve(options.db);
+    const entities = loadEntities(dbPath);
+    if (!entities) return;
+    checkEnvironment(options.env, entities);
+    graph = buildGraph(dbPath, entities, { environment: options.env });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const from = resolveGraphNode(graph, options.from, `--from ${options.from}`);
+  if (!from) return;
+  const to = resolveGraphNode(graph, options.to, `--to ${options.to}`);
+  if (!to) return;
+  const paths = findShortestPaths(graph, from.id, to.id, { ...filter, k });
+
+  if (options.format === 'json') {
+    console.log(formatJson({ from: from.id, to: to.id, paths }, true));
+  } else {
+    console.log(formatPaths(from, to, paths));
+  }
+}
+
+export function registerPathCommand(program: Command): void {
+  program
+    .command('path')
+    .description(
+      'Show the shortest dependency paths from one node to another, with how each edge was derived',
+    )
+    .option('--from <name>', 'Node the paths start at, by name, alias, or id')
+    .option('--to <name>', 'Node the paths end at, by name, alias, or id')
+    .option('--k <n>', 'How many paths to show, shortest first', '3')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option(
+      '--provenance <list>',
+      'Follow only edges with these provenances (e.g. declared,import)',
+    )
+    .option(
+      '--min-confidence <n>',
+      'Follow only edges with at least this confidence, from 0 to 1',
+    )
+    .option(
+      '--protocol <list>',
+      'Follow only dependencies annotated with these protocols (e.g. grpc)',
+    )
+    .option(
+      '--criticality <list>',
+      'Follow only dependencies annotated with these criticalities (e.g. critical,high)',
+    )
+    .option(
+      '--env <environment>',
+      'Follow only the dependencies declared for this environment (e.g. prod)',
+    )
+    .action((options: PathCommandOptions) => {
+      runPath(options);
+    });
+}
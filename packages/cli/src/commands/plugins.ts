@@ -0,0 +1,191 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists configured plugins, checks that they respond, and checks plugin export formats against golden files
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, plugins, extensibility, golden]
+ * context:
+ *   business_goal: Make it easy to see which plugins extend a repository and whether they work
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  checkGoldenExports,
+  createPluginExporter,
+  describePlugin,
+} from '@know-graph/core';
+import type {
+  GoldenExportResult,
+  PluginConfig,
+  PluginDescription,
+} from '@know-graph/core';
+import { readPlugins } from '../utils/manifest.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+
+interface PluginsCommandOptions {
+  readonly config: string;
+  readonly check?: boolean;
+  readonly golden?: string;
+  readonly update?: boolean;
+}
+
+/** A plugin's `describe` answer, or the error that calling it produced. */
+export type PluginProbe = PluginDescription | Error;
+
+/** The module path of a wasm plugin, or the command line of an exec one. */
+function source(plugin: PluginConfig): string {
+  return plugin.wasm ?? plugin.command?.join(' ') ?? '';
+}
+
+function capabilities(plugin: PluginConfig): string {
+  const parts: string[] = [];
+  if (plugin.extensions?.length) {
+    parts.push(`extracts ${plugin.extensions.join(', ')}`);
+  }
+  if (plugin.formats?.length) {
+    parts.push(`exports ${plugin.formats.join(', ')}`);
+  }
+  if (plugin.rules) parts.push('lint rules');
+  if (plugin.enrich) parts.push('enriches entities');
+  return parts.join('; ');
+}
+
+export function formatPlugins(
+  plugins: readonly PluginConfig[],
+  probes?: ReadonlyMap<string, PluginProbe>,
+): string {
+  if (plugins.length === 0) {
+    return 'No plugins configured. Add a plugins list to .knowgraph.yml.';
+  }
+  const lines: string[] = [];
+  for (const plugin of plugins) {
+    lines.push(`${chalk.bold(plugin.name)} ${chalk.dim(source(plugin))}`);
+    lines.push(`  ${capabilities(plugin)}`);
+    const probe = probes?.get(plugin.name);
+    if (probe instanceof Error) {
+      lines.push(chalk.red(`  ✘ ${probe.message}`));
+    } else if (probe) {
+      const version = probe.version ? ` ${probe.version}` : '';
+      lines.push(chalk.green(`  ✔ ${probe.name}${version}`));
+    }
+  }
+  return lines.join('\n');
+}
+
+export function formatGoldenResults(
+  results: readonly GoldenExportResult[],
+): string {
+  const lines: string[] = [];
+  for (const result of results) {
+    const label = `${chalk.bold(result.format)} ${chalk.dim(result.path)}`;
+    switch (result.status) {
+      case 'match':
+        lines.push(chalk.green(`✔ ${label}`));
+        break;
+      case 'created':
+      case 'updated':
+        lines.push(chalk.yellow(`✎ ${label} ${result.status}`));
+        break;
+      case 'missing':
+        lines.push(chalk.red(`✘ ${label} missing; rerun with --update`));
+        break;
+      default:
+        lines.push(chalk.red(`✘ ${label} differs`));
+        if (result.diff) lines.push(result.diff);
+    }
+  }
+  return lines.join('\n');
+}
+
+/**
+ * Render every format configured plugins export over the golden entities
+ * and compare each with `<dir>/<format>.golden`.
+ */
+function runGolden(
+  plugins: readonly PluginConfig[],
+  dir: string,
+  update: boolean,
+): void {
+  const exporters = plugins.flatMap((plugin) =>
+    (plugin.formats ?? []).map((format) =>
+      createPluginExporter(plugin, format),
+    ),
+  );
+  if (exporters.length === 0) {
+    reportError('No configured plugin exports a format', 'usage');
+    return;
+  }
+
+  let results: readonly GoldenExportResult[];
+  try {
+    results = checkGoldenExports(exporters, dir, { update });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  console.log(formatGoldenResults(results));
+  const failed = results.filter(
+    (result) => result.status === 'mismatch' || result.status === 'missing',
+  );
+  if (failed.length > 0) {
+    reportCheckFailure(
+      `${failed.length} export format(s) differ from their golden files`,
+      'policy',
+      { formats: failed.map((result) => result.format) },
+    );
+  }
+}
+
+function runPlugins(options: PluginsCommandOptions): void {
+  const plugins = readPlugins(resolve(options.config));
+  if (options.update && !options.golden) {
+    reportError('--update needs --golden <dir>', 'usage');
+    return;
+  }
+  if (options.golden) {
+    runGolden(plugins, resolve(options.golden), options.update === true);
+    return;
+  }
+  if (!options.check) {
+    console.log(formatPlugins(plugins));
+    return;
+  }
+
+  const probes = new Map<string, PluginProbe>();
+  for (const plugin of plugins) {
+    try {
+      probes.set(plugin.name, describePlugin(plugin));
+    } catch (err) {
+      probes.set(
+        plugin.name,
+        err instanceof Error ? err : new Error(String(err)),
+      );
+    }
+  }
+  console.log(formatPlugins(plugins, probes));
+  const failed = [...probes].filter(([, probe]) => probe instanceof Error);
+  if (failed.length > 0) {
+    reportCheckFailure(`${failed.length} plugin(s) did not respond`, 'io', {
+      plugins: failed.map(([name]) => name),
+    });
+  }
+}
+
+export function registerPluginsCommand(program: Command): void {
+  program
+    .command('plugins')
+    .description('List plugins configured in .knowgraph.yml')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--check', 'Call each plugin and report whether it responds')
+    .option(
+      '--golden <dir>',
+      'Compare each plugin export format with <dir>/<format>.golden',
+    )
+    .option('--update', 'With --golden, rewrite golden files that differ')
+    .action((options: PluginsCommandOptions) => {
+      runPlugins(options);
+    });
+}
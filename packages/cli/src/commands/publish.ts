@@ -0,0 +1,364 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI commands that publish module pages to Confluence and sync modules and services into a Notion database, writing only what changed
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, publish, confluence, notion, docs]
+ * context:
+ *   business_goal: Put module docs where non-engineers already read, without copying them by hand
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  createConfluenceSink,
+  createNotionDatabaseSync,
+  notionRows,
+  planConfluencePages,
+} from '@know-graph/core';
+import type {
+  AuditChange,
+  ConfluenceConfig,
+  ConfluencePagePlan,
+  ConfluenceSink,
+  NotionSyncResult,
+  PublishedConfluencePage,
+} from '@know-graph/core';
+import { recordAudit } from '../utils/audit.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import {
+  readConfluenceConfig,
+  readNotionDatabaseConfig,
+} from '../utils/manifest.js';
+
+interface PublishConfluenceOptions {
+  readonly db: string;
+  readonly config: string;
+  readonly dryRun?: boolean;
+  readonly format: string;
+}
+
+interface PublishNotionOptions extends PublishConfluenceOptions {
+  readonly force?: boolean;
+}
+
+export function formatConfluencePublish(
+  plan: ConfluencePagePlan,
+  published: readonly PublishedConfluencePage[] | undefined,
+): string {
+  const lines: string[] = [];
+  if (published === undefined) {
+    if (plan.pages.length > 0) {
+      lines.push(chalk.green(`Would publish ${plan.pages.length} page(s)`));
+    }
+    for (const page of plan.pages) {
+      lines.push(
+        `  ${chalk.cyan(`${page.space}/${page.title}`)} ${chalk.dim(`from ${page.directory}`)}`,
+      );
+    }
+  } else if (published.length > 0) {
+    const changed = published.filter((page) => page.status !== 'unchanged');
+    lines.push(
+      changed.length === 0
+        ? chalk.green(`All ${published.length} page(s) are up to date`)
+        : chalk.green(
+            `Published ${changed.length} of ${published.length} page(s)`,
+          ),
+    );
+    for (const page of changed) {
+      lines.push(
+        `  ${chalk.cyan(`${page.space}/${page.title}`)} ${chalk.dim(`${page.status}, version ${page.version}`)}`,
+      );
+    }
+  }
+  if (plan.skipped.length > 0) {
+    if (lines.length > 0) lines.push('');
+    lines.push(chalk.bold('Skipped'));
+    for (const skip of plan.skipped) {
+      lines.push(`  ${chalk.yellow(skip.directory)}: ${skip.reason}`);
+    }
+  }
+  if (lines.length === 0) {
+    return chalk.dim('No modules are indexed, so no pages to publish.');
+  }
+  return lines.join('\n');
+}
+
+/** The pages created or updated, for the audit log. */
+function confluenceChanges(
+  published: readonly PublishedConfluencePage[],
+): AuditChange[] {
+  return published
+    .filter((page) => page.status !== 'unchanged')
+    .map((page) => ({
+      target: `confluence:${page.space}/${page.title}`,
+      summary: `${page.status}, version ${page.version}`,
+    }));
+}
+
+/** The rows created or updated, and the Notion edits they overwrote. */
+function notionChanges(result: NotionSyncResult): AuditChange[] {
+  return result.rows
+    .filter((row) => row.status === 'created' || row.status === 'updated')
+    .map((row) => {
+      const fields = row.conflicts.map((conflict) => conflict.field);
+      return {
+        target: `notion:${row.id}`,
+        summary:
+          fields.length > 0
+            ? `${row.status}, overwriting edits to ${fields.join(', ')}`
+            : row.status,
+      };
+    });
+}
+
+/** The sink, or undefined after reporting a credential that is not set. */
+function confluenceSink(
+  config: ConfluenceConfig,
+): ConfluenceSink | undefined {
+  const missing = [config.token_env, config.email_env].find(
+    (name) => name !== undefined && !process.env[name],
+  );
+  if (missing) {
+    reportError(
+      `${missing} is not set`,
+      'usage',
+      'Export the Confluence credentials named by confluence.token_env and confluence.email_env',
+    );
+    return undefined;
+  }
+  const email = config.email_env ? process.env[config.email_env] : undefined;
+  return createConfluenceSink({
+    baseUrl: config.base_url,
+    token: process.env[config.token_env] ?? '',
+    ...(email ? { email } : {}),
+  });
+}
+
+async function runPublishConfluence(
+  options: PublishConfluenceOptions,
+): Promise<void> {
+  let plan: ConfluencePagePlan;
+  let config: ConfluenceConfig | undefined;
+  try {
+    config = readConfluenceConfig(resolve(options.config));
+    if (!config) {
+      reportError(
+        `No confluence section in ${options.config}`,
+        'usage',
+        'Add confluence.base_url, and a space or pages to publish to',
+      );
+      return;
+    }
+    const dbPath = resolve(options.db);
+    const configPath = resolve(options.config);
+    const entities = loadEntities(dbPath, configPath);
+    if (!entities) return;
+    plan = planConfluencePages(
+      entities,
+      buildGraph(dbPath, entities, { configPath }),
+      config,
+    );
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  let published: PublishedConfluencePage[] | undefined;
+  if (!options.dryRun) {
+    const sink = confluenceSink(config);
+    if (!sink) return;
+    published = [];
+    try {
+      for (const page of plan.pages) {
+        published.push(await sink.publish(page));
+      }
+    } catch (err) {
+      if (published.length > 0 && options.format !== 'json') {
+        console.log(formatConfluencePublish(plan, published));
+      }
+      reportError(err);
+      // The pages published before the failure stay published
+      recordAudit(
+        resolve(options.config),
+        'publish confluence',
+        confluenceChanges(published),
+      );
+      return;
+    }
+  }
+
+  if (options.format === 'json') {
+    console.log(
+      formatJson(
+        {
+          pages:
+            published ??
+            plan.pages.map(({ title, space, directory, entities }) => ({
+              title,
+              space,
+              directory,
+              entities,
+            })),
+          skipped: plan.skipped,
+        },
+        true,
+      ),
+    );
+  } else {
+    console.log(formatConfluencePublish(plan, published));
+  }
+  if (published) {
+    recordAudit(
+      resolve(options.config),
+      'publish confluence',
+      confluenceChanges(published),
+    );
+  }
+}
+
+export function formatNotionSync(
+  result: NotionSyncResult,
+  options: { readonly dryRun?: boolean; readonly force?: boolean } = {},
+): string {
+  const { rows, orphaned } = result;
+  if (rows.length === 0 && orphaned.length === 0) {
+    return chalk.dim('No modules or services are indexed, so no rows to sync.');
+  }
+  const changed = rows.filter(
+    (row) => row.status === 'created' || row.status === 'updated',
+  );
+  const conflicts = rows.filter((row) => row.status === 'conflict');
+  const verb = options.dryRun ? 'Would sync' : 'Synced';
+  const lines = [
+    changed.length === 0 && conflicts.length === 0
+      ? chalk.green(`All ${rows.length} row(s) are up to date`)
+      : chalk.green(`${verb} ${changed.length} of ${rows.length} row(s)`),
+  ];
+  for (const row of changed) {
+    const overwritten = row.conflicts.length > 0 ? ', overwriting edits' : '';
+    lines.push(
+      `  ${chalk.cyan(row.name)} ${chalk.dim(`${row.status}${overwritten}`)}`,
+    );
+  }
+  if (conflicts.length > 0) {
+    lines.push('', chalk.bold('Edited in Notion, differing from the code'));
+    for (const row of conflicts) {
+      lines.push(`  ${chalk.yellow(row.name)}`);
+      for (const conflict of row.conflicts) {
+        lines.push(
+          `    ${conflict.field}: ${JSON.stringify(conflict.notion)} in Notion, ${JSON.stringify(conflict.code)} in code`,
+        );
+      }
+    }
+    lines.push(
+      '',
+      chalk.dim(
+        'Fix the annotations to keep the edits, or pass --force to overwrite them.',
+      ),
+    );
+  }
+  if (orphaned.length > 0) {
+    lines.push('', chalk.bold('Rows for entities no longer in the graph'));
+    for (const id of orphaned) lines.push(`  ${chalk.yellow(id)}`);
+  }
+  return lines.join('\n');
+}
+
+async function runPublishNotion(options: PublishNotionOptions): Promise<void> {
+  let result: NotionSyncResult;
+  try {
+    const config = readNotionDatabaseConfig(resolve(options.config));
+    if (!config) {
+      reportError(
+        `No notion_database section in ${options.config}`,
+        'usage',
+        'Add notion_database.database_id, and share the database with the integration',
+      );
+      return;
+    }
+    const token = process.env[config.token_env];
+    if (!token) {
+      reportError(
+        `${config.token_env} is not set`,
+        'usage',
+        'Export the token of a Notion integration the database is shared with',
+      );
+      return;
+    }
+    const entities = loadEntities(
+      resolve(options.db),
+      resolve(options.config),
+    );
+    if (!entities) return;
+    const database = createNotionDatabaseSync({
+      databaseId: config.database_id,
+      token,
+    });
+    result = await database.sync(notionRows(entities, config.types), {
+      force: options.force,
+      dryRun: options.dryRun,
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(result, true));
+  } else {
+    console.log(formatNotionSync(result, options));
+  }
+  const conflicts = result.rows.filter((row) => row.status === 'conflict');
+  if (conflicts.length > 0) {
+    reportCheckFailure(
+      `${conflicts.length} Notion row(s) edited to differ from the code`,
+      'policy',
+      { conflicts: conflicts.map((row) => row.id) },
+    );
+  }
+  if (!options.dryRun) {
+    recordAudit(
+      resolve(options.config),
+      'publish notion',
+      notionChanges(result),
+    );
+  }
+}
+
+export function registerPublishCommand(program: Command): void {
+  const publish = program
+    .command('publish')
+    .description('Publish generated docs to other tools');
+
+  publish
+    .command('confluence')
+    .description(
+      'Create or update a Confluence page for each module directory, in the spaces mapped under confluence in .knowgraph.yml',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--dry-run', 'List the pages without contacting Confluence')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action(async (options: PublishConfluenceOptions) => {
+      await runPublishConfluence(options);
+    });
+
+  publish
+    .command('notion')
+    .description(
+      'Sync a row per module and service into the Notion database in .knowgraph.yml, flagging rows edited in Notion to differ from the code',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--force', 'Overwrite rows edited in Notion with the code')
+    .option('--dry-run', 'Compare with the database without writing to it')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action(async (options: PublishNotionOptions) => {
+      await runPublishNotion(options);
+    });
+}
@@ -12,58 +12,150 @@
 import { resolve } from 'node:path';
 import type { Command } from 'commander';
 import chalk from 'chalk';
-import { createDatabaseManager, createQueryEngine } from '@know-graph/core';
-import type { EntityType } from '@know-graph/core';
-import { formatTable, formatJson } from '../utils/format.js';
+import {
+  createQueryEngine,
+  parseAsOfDate,
+  projectEntity,
+  resolveView,
+  runSavedQuery,
+  selectEnvironment,
+  viewTable,
+} from '@know-graph/core';
+import type { EntityType, QueryOptions } from '@know-graph/core';
+import { openDatabase } from '../utils/db.js';
+import {
+  entityTable,
+  formatTable,
+  formatJson,
+  formatViewTable,
+} from '../utils/format.js';
+import { checkEnvironment } from '../utils/environments.js';
+import { reportError } from '../utils/errors.js';
+import { readSavedQueries, readViews } from '../utils/manifest.js';
+import { collectScope, parseScopes } from '../utils/scope.js';
+import { showTable } from '../utils/table-terminal.js';
+import type { TableData } from '../utils/table-view.js';
 
 interface QueryCommandOptions {
   readonly type?: string;
   readonly owner?: string;
   readonly tags?: string;
+  readonly contributor?: string;
+  readonly scope?: readonly string[];
+  readonly env?: string;
+  readonly asOf?: string;
+  readonly saved?: string;
+  readonly view?: string;
   readonly format: string;
-  readonly limit: string;
+  readonly interactive?: boolean;
+  readonly limit?: string;
   readonly db: string;
+  readonly config: string;
 }
 
-function runQuery(searchTerm: string, options: QueryCommandOptions): void {
+async function runQuery(
+  searchTerm: string | undefined,
+  options: QueryCommandOptions,
+): Promise<void> {
+  if (searchTerm === undefined && options.saved === undefined) {
+    reportError(
+      'Give a search term or --saved <name>',
+      'usage',
+      "Run 'knowgraph query --help' for usage.",
+    );
+    return;
+  }
+  if (options.interactive && (!process.stdin.isTTY || !process.stdout.isTTY)) {
+    reportError(
+      '--interactive needs an interactive terminal',
+      'usage',
+      'Use --format json or the default table in scripts and pipes.',
+    );
+    return;
+  }
   const dbPath = resolve(options.db);
+  const configPath = resolve(options.config);
 
   let dbManager;
   try {
-    dbManager = createDatabaseManager(dbPath);
+    dbManager = openDatabase(dbPath, configPath, { readOnly: true });
   } catch {
-    console.error(chalk.red(`Error: Could not open database at ${dbPath}`));
-    console.error(
-      chalk.yellow("Run 'knowgraph index' first to create the database."),
+    reportError(
+      `Could not open database at ${dbPath}`,
+      'io',
+      "Run 'knowgraph index' first to create the database.",
     );
-    process.exitCode = 1;
     return;
   }
 
+  let table: TableData | undefined;
   try {
     const engine = createQueryEngine(dbManager);
+    checkEnvironment(options.env, engine.iterateAll());
+    const view =
+      options.view !== undefined
+        ? resolveView(options.view, readViews(configPath))
+        : undefined;
 
     const tags = options.tags
       ? options.tags.split(',').map((t) => t.trim())
       : undefined;
+    const scopes = parseScopes(options.scope);
+    // Flags given alongside --saved replace what the query saved
+    const filters: QueryOptions = {
+      ...(searchTerm !== undefined ? { query: searchTerm } : {}),
+      ...(options.type !== undefined
+        ? { type: options.type as EntityType }
+        : {}),
+      ...(options.owner !== undefined ? { owner: options.owner } : {}),
+      ...(options.contributor !== undefined
+        ? { contributor: options.contributor }
+        : {}),
+      ...(tags ? { tags } : {}),
+      ...(scopes.length > 0 ? { scopes } : {}),
+      ...(options.asOf !== undefined
+        ? { asOf: parseAsOfDate(options.asOf) }
+        : {}),
+      ...(view && view.types.length > 0 ? { types: view.types } : {}),
+    };
 
-    const result = engine.search({
-      query: searchTerm,
-      type: options.type as EntityType | undefined,
-      owner: options.owner,
-      tags,
-      limit: parseInt(options.limit, 10),
-    });
+    const result =
+      options.saved !== undefined
+        ? runSavedQuery(
+            engine,
+            readSavedQueries(configPath),
+            options.saved,
+            options.limit !== undefined
+              ? { ...filters, limit: parseInt(options.limit, 10) }
+              : filters,
+          )
+        : engine.search({
+            ...filters,
+            limit: parseInt(options.limit ?? '20', 10),
+          });
 
     if (result.entities.length === 0) {
       console.log(chalk.yellow('No results found.'));
       return;
     }
 
+    const { env } = options;
+    const inEnvironment = env
+      ? result.entities.map((entity) => selectEnvironment(entity, env))
+      : result.entities;
+    const entities = view
+      ? inEnvironment.map((entity) => projectEntity(view, entity))
+      : inEnvironment;
+    if (options.interactive) {
+      table = view ? viewTable(view, entities) : entityTable(entities);
+      return;
+    }
     if (options.format === 'json') {
-      console.log(formatJson(result.entities, true));
+      console.log(formatJson(entities, true));
+    } else if (view) {
+      console.log(formatViewTable(view, entities));
     } else {
-      console.log(formatTable(result.entities));
+      console.log(formatTable(entities));
     }
 
     console.error(
@@ -72,28 +164,62 @@ function runQuery(searchTerm: string, options: QueryCommandOptions): void {
       ),
     );
   } catch (err) {
-    console.error(
-      chalk.red(
-        `Query failed: ${err instanceof Error ? err.message : String(err)}`,
-      ),
-    );
-    process.exitCode = 1;
+    reportError(err);
   } finally {
     dbManager.close();
   }
+
+  if (table) {
+    await showTable(table, {
+      title: `knowgraph query ${searchTerm ?? `--saved ${options.saved}`}`,
+      exportPath: 'knowgraph-query',
+    });
+  }
 }
 
 export function registerQueryCommand(program: Command): void {
   program
-    .command('query <search-term>')
+    .command('query [search-term]')
     .description('Search the code graph')
     .option('--type <type>', 'Filter by entity type')
     .option('--owner <owner>', 'Filter by owner')
     .option('--tags <tags>', 'Comma-separated tag filter')
+    .option(
+      '--contributor <author>',
+      'Filter by a top git contributor (author email)',
+    )
+    .option(
+      '--scope <scope>',
+      'Only path=<dir> or tag=<tag>; repeat to widen',
+      collectScope,
+    )
+    .option(
+      '--env <environment>',
+      'Show the dependencies declared for this environment (e.g. prod)',
+    )
+    .option(
+      '--as-of <date>',
+      'Search the graph as it stood on this date (YYYY-MM-DD)',
+    )
+    .option(
+      '--saved <name>',
+      'Run a query saved under queries in the config; flags override it',
+    )
+    .option(
+      '--view <name>',
+      'Show the types, fields, and columns of a view (e.g. compliance, sre)',
+    )
     .option('--format <format>', 'Output format (json|table)', 'table')
-    .option('--limit <n>', 'Max results', '20')
+    .option(
+      '-i, --interactive',
+      'Browse results in a sortable table with column hiding and CSV, JSON, and Markdown export',
+    )
+    .option('--limit <n>', 'Max results (default: 20, or all when --saved)')
     .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
-    .action((searchTerm: string, options: QueryCommandOptions) => {
-      runQuery(searchTerm, options);
-    });
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .action(
+      async (searchTerm: string | undefined, options: QueryCommandOptions) => {
+        await runQuery(searchTerm, options);
+      },
+    );
 }
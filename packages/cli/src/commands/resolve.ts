@@ -0,0 +1,161 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that translates file locations, import paths, service names, and OpenAPI operation ids into graph nodes
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, resolve, identifiers]
+ * context:
+ *   business_goal: Let scripts and people move between the graph's vocabulary and the artifacts they hold
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  IDENTIFIER_KINDS,
+  discoverGoPackages,
+  discoverWorkspaceMembers,
+  entityApiSpecs,
+  readApiSpec,
+  readGitSubmodules,
+  resolveIdentifier,
+} from '@know-graph/core';
+import type {
+  ApiSpec,
+  IdentifierKind,
+  Resolution,
+  StoredEntity,
+} from '@know-graph/core';
+import { loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { getLogger } from '../utils/logging.js';
+import { readGraphNames, readNamespace } from '../utils/manifest.js';
+
+interface ResolveCommandOptions {
+  readonly from?: string;
+  readonly root: string;
+  readonly config: string;
+  readonly db: string;
+  readonly format: string;
+}
+
+export function formatResolutions(
+  resolutions: readonly Resolution[],
+): string {
+  return resolutions
+    .flatMap((resolution) => {
+      if (!resolution.kind) {
+        return [`${resolution.query} ${chalk.red('not found')}`];
+      }
+      return [
+        `${resolution.query} ${chalk.dim(`(${resolution.kind})`)}`,
+        ...resolution.matches.map((match) => {
+          const extra = [
+            match.repository,
+            match.via ? `via ${match.via}` : null,
+          ].filter((part): part is string => part !== null);
+          const suffix =
+            extra.length > 0 ? chalk.dim(` [${extra.join(', ')}]`) : '';
+          return `  → ${match.name} ${match.entityType} ${match.filePath}:${match.line}${suffix}`;
+        }),
+      ];
+    })
+    .join('\n');
+}
+
+/**
+ * The specs the entities link, read from `rootDir`. A spec that cannot be
+ * read is left out with a warning, since other identifiers still resolve.
+ */
+function readLinkedSpecs(
+  rootDir: string,
+  entities: readonly StoredEntity[],
+): ReadonlyMap<string, ApiSpec> {
+  const specs = new Map<string, ApiSpec>();
+  for (const path of new Set(entities.flatMap(entityApiSpecs))) {
+    try {
+      specs.set(path, readApiSpec(rootDir, path));
+    } catch (err) {
+      const reason = err instanceof Error ? err.message : String(err);
+      getLogger().warn(`Skipping API spec ${path}: ${reason}`);
+    }
+  }
+  return specs;
+}
+
+function runResolve(
+  identifiers: readonly string[],
+  options: ResolveCommandOptions,
+): void {
+  const kind = options.from as IdentifierKind | undefined;
+  if (kind !== undefined && !IDENTIFIER_KINDS.includes(kind)) {
+    reportError(
+      `Unknown identifier kind "${options.from}". Use ${IDENTIFIER_KINDS.join(', ')}.`,
+      'usage',
+    );
+    return;
+  }
+  const configPath = resolve(options.config);
+  const entities = loadEntities(resolve(options.db), configPath);
+  if (!entities) return;
+
+  const rootDir = resolve(options.root);
+  let resolutions: readonly Resolution[];
+  try {
+    const context = {
+      entities,
+      goPackages: discoverGoPackages(rootDir),
+      workspaces: discoverWorkspaceMembers(rootDir),
+      specs: readLinkedSpecs(rootDir, entities),
+      submodules: readGitSubmodules(rootDir),
+      repository: readNamespace(configPath),
+      names: readGraphNames(configPath),
+    };
+    resolutions = identifiers.map((identifier) =>
+      resolveIdentifier(identifier, context, kind),
+    );
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(resolutions, true));
+  } else {
+    console.log(formatResolutions(resolutions));
+  }
+  const unresolved = resolutions.filter((resolution) => !resolution.kind);
+  if (unresolved.length > 0) {
+    reportCheckFailure(
+      `${unresolved.length} of ${resolutions.length} identifiers did not resolve`,
+      'policy',
+      { unresolved: unresolved.map((resolution) => resolution.query) },
+    );
+  }
+}
+
+export function registerResolveCommand(program: Command): void {
+  program
+    .command('resolve')
+    .description(
+      'Translate file:line locations, import paths, service names, and API operation ids into graph nodes',
+    )
+    .argument('<identifiers...>', 'Identifiers to resolve')
+    .option(
+      '--from <kind>',
+      'Resolve only as this kind (location|import|operation|service|node)',
+    )
+    .option(
+      '--root <dir>',
+      'Directory the indexed file paths are relative to',
+      '.',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((identifiers: string[], options: ResolveCommandOptions) => {
+      runResolve(identifiers, options);
+    });
+}
@@ -0,0 +1,188 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists generated annotations awaiting review and approves or rejects them, recording the reviewer
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, review, draft, annotations, audit]
+ * context:
+ *   business_goal: Keep generated annotations out of what teams rely on until a person has checked them
+ *   domain: cli
+ */
+import { relative, resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  fileChange,
+  listReviewQueue,
+  reviewAnnotations,
+} from '@know-graph/core';
+import type {
+  AuditChange,
+  ReviewDecision,
+  ReviewItem,
+  ReviewResult,
+} from '@know-graph/core';
+import { recordAudit, resolveActor } from '../utils/audit.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+import { formatPlan } from '../utils/plan.js';
+
+interface ReviewListOptions {
+  readonly path: string;
+  readonly format: string;
+}
+
+interface ReviewDecisionOptions {
+  readonly path: string;
+  readonly config: string;
+  readonly reviewer?: string;
+  readonly dryRun?: boolean;
+  readonly format: string;
+}
+
+export function formatReviewQueue(items: readonly ReviewItem[]): string {
+  if (items.length === 0) {
+    return chalk.green('No annotations awaiting review.');
+  }
+  const lines: string[] = [];
+  let filePath: string | undefined;
+  for (const item of items) {
+    if (item.filePath !== filePath) {
+      filePath = item.filePath;
+      lines.push(chalk.bold(filePath));
+    }
+    const description =
+      typeof item.metadata.description === 'string'
+        ? ` ${chalk.dim(item.metadata.description)}`
+        : '';
+    lines.push(
+      `  ${String(item.line).padStart(5)}  ${chalk.cyan(item.name)} (${item.entityType})${description}`,
+    );
+  }
+  lines.push('');
+  lines.push(`${items.length} annotation(s) awaiting review`);
+  return lines.join('\n');
+}
+
+export function formatReviewResult(result: ReviewResult): string {
+  return result.outcomes
+    .map(({ item, decision, reviewer }) => {
+      const where = chalk.dim(`(${item.filePath}:${item.line})`);
+      return decision === 'approve'
+        ? chalk.green(`✓ Approved ${item.name} ${where} as ${reviewer}`)
+        : chalk.yellow(`✗ Rejected ${item.name} ${where}`);
+    })
+    .join('\n');
+}
+
+function runReviewList(options: ReviewListOptions): void {
+  try {
+    const items = listReviewQueue(resolve(options.path));
+    if (options.format === 'json') {
+      console.log(formatJson(items, true));
+    } else {
+      console.log(formatReviewQueue(items));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+function runReviewDecision(
+  targets: readonly string[],
+  decision: ReviewDecision,
+  options: ReviewDecisionOptions,
+): void {
+  const reviewer = options.reviewer ?? resolveActor();
+  const absPath = resolve(options.path);
+  let result: ReviewResult;
+  try {
+    result = reviewAnnotations(absPath, targets, decision, {
+      reviewer,
+      write: !options.dryRun,
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const changes = result.files.map((file) =>
+    fileChange(
+      relative('.', resolve(absPath, file.filePath)),
+      file.before,
+      file.after,
+    ),
+  );
+  if (options.dryRun) {
+    const plan = {
+      command: `review ${decision}`,
+      changes: changes.filter(
+        (change): change is AuditChange => change !== undefined,
+      ),
+      totals: [
+        `${result.outcomes.length} annotation(s) ${decision === 'approve' ? 'approved' : 'rejected'}`,
+      ],
+    };
+    console.log(
+      options.format === 'json' ? formatJson(plan, true) : formatPlan(plan),
+    );
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(result.outcomes, true));
+  } else {
+    console.log(formatReviewResult(result));
+  }
+  recordAudit(resolve(options.config), `review ${decision}`, changes);
+}
+
+function registerDecision(
+  review: Command,
+  decision: ReviewDecision,
+  description: string,
+): void {
+  review
+    .command(`${decision} <targets...>`)
+    .description(description)
+    .option('--path <path>', 'Directory or file the annotations are in', '.')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option(
+      '--reviewer <name>',
+      'Who is reviewing (default: KNOWGRAPH_ACTOR, the git author, or the OS user)',
+    )
+    .option('--dry-run', 'Show how the files would change without writing')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((targets: string[], options: ReviewDecisionOptions) => {
+      runReviewDecision(targets, decision, options);
+    });
+}
+
+export function registerReviewCommand(program: Command): void {
+  const review = program
+    .command('review')
+    .description(
+      'Review generated annotations before exports treat them as authoritative',
+    );
+
+  review
+    .command('list')
+    .description('List generated annotations awaiting review')
+    .option('--path <path>', 'Directory or file to list annotations in', '.')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: ReviewListOptions) => {
+      runReviewList(options);
+    });
+
+  registerDecision(
+    review,
+    'approve',
+    'Approve annotations by path:line, path:name, or name, recording the reviewer',
+  );
+  registerDecision(
+    review,
+    'reject',
+    'Reject annotations by path:line, path:name, or name, removing them',
+  );
+}
@@ -0,0 +1,306 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that runs a pipeline of scan, enrich, check, export, and notify steps declared in .knowgraph.yml
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, pipeline, ci, automation]
+ * context:
+ *   business_goal: Replace brittle shell scripts that chain knowgraph commands with one declared, reviewable pipeline
+ *   domain: cli
+ */
+import { spawn } from 'node:child_process';
+import { dirname, resolve } from 'node:path';
+import { performance } from 'node:perf_hooks';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { createKnowgraphError } from '@know-graph/core';
+import type {
+  AlertMessage,
+  AlertSinkConfig,
+  PipelineCheck,
+  PipelineStepConfig,
+} from '@know-graph/core';
+import { reportError } from '../utils/errors.js';
+import { notifyAlertSinks } from '../utils/history.js';
+import { readPipelines } from '../utils/manifest.js';
+
+interface RunCommandOptions {
+  readonly config: string;
+  readonly dryRun?: boolean;
+}
+
+/** The command each `check` step name runs; each exits 1 on findings. */
+export const PIPELINE_CHECKS: Readonly<
+  Record<PipelineCheck, readonly string[]>
+> = {
+  annotations: ['check'],
+  anomalies: ['anomalies', '--check'],
+  licenses: ['licenses'],
+  versions: ['versions'],
+  links: ['check-links'],
+  contracts: ['contracts', 'coverage', '--check'],
+};
+
+/** A step as run: a knowgraph command, or the sinks to notify. */
+export type PlannedStep =
+  | { readonly name: string; readonly args: readonly string[] }
+  | {
+      readonly name: 'notify';
+      readonly sinks: readonly AlertSinkConfig['type'][];
+    };
+
+export interface StepOutcome {
+  readonly name: string;
+  readonly status: 'ok' | 'failed' | 'skipped';
+  /** Set for commands that ran. */
+  readonly exitCode?: number;
+  readonly ms: number;
+}
+
+export interface PipelineRun {
+  readonly pipeline: string;
+  readonly ok: boolean;
+  readonly steps: readonly StepOutcome[];
+}
+
+export interface PipelineRunner {
+  /** Run `knowgraph <args>`, resolving to its exit code. */
+  exec(args: readonly string[]): Promise<number>;
+  notify(
+    sinks: readonly AlertSinkConfig['type'][],
+    message: AlertMessage,
+  ): Promise<void>;
+  log(line: string): void;
+  now?(): number;
+}
+
+/**
+ * The commands `steps` run, in order. Enrich steps add their enrichers to
+ * the scan right before them, which runs them, so they must follow a scan
+ * or another enrich step. Check and export steps run one command per
+ * check or format. Throws a usage error on an enrich step out of place.
+ */
+export function planPipeline(
+  steps: readonly PipelineStepConfig[],
+): readonly PlannedStep[] {
+  const planned: PlannedStep[] = [];
+  let scan: { args: string[]; enrichers: string[] } | undefined;
+  for (const step of steps) {
+    if ('enrich' in step) {
+      if (!scan) {
+        throw createKnowgraphError(
+          'usage',
+          'An enrich step must follow a scan step, whose scan runs its enrichers',
+        );
+      }
+      scan.enrichers.push(...step.enrich);
+      continue;
+    }
+    if (scan && scan.enrichers.length > 0) {
+      scan.args.push('--enrichers', scan.enrichers.join(','));
+    }
+    scan = undefined;
+    if ('scan' in step) {
+      const args = ['index', step.scan.path];
+      if (!step.scan.incremental) args.push('--no-incremental');
+      if (step.scan.exclude) {
+        args.push('--exclude', step.scan.exclude.join(','));
+      }
+      scan = { args, enrichers: [] };
+      planned.push({ name: 'scan', args });
+    } else if ('check' in step) {
+      for (const check of step.check) {
+        planned.push({
+          name: `check ${check}`,
+          args: PIPELINE_CHECKS[check],
+        });
+      }
+    } else if ('export' in step) {
+      for (const format of step.export) {
+        planned.push({
+          name: `export ${format}`,
+          args: ['export', '--format', format],
+        });
+      }
+    } else {
+      planned.push({ name: 'notify', sinks: step.notify });
+    }
+  }
+  if (scan && scan.enrichers.length > 0) {
+    scan.args.push('--enrichers', scan.enrichers.join(','));
+  }
+  return planned;
+}
+
+export function formatStepOutcome(outcome: StepOutcome): string {
+  if (outcome.status === 'skipped') return `${outcome.name}: skipped`;
+  const seconds = (outcome.ms / 1000).toFixed(1);
+  return outcome.status === 'ok'
+    ? `${outcome.name}: ok in ${seconds}s`
+    : `${outcome.name}: failed with exit code ${outcome.exitCode}`;
+}
+
+/** How the run has gone so far, for notify steps to send. */
+export function pipelineMessage(run: PipelineRun): AlertMessage {
+  const failed = run.steps.find((step) => step.status === 'failed');
+  return {
+    event: 'knowgraph.pipeline',
+    subject: failed
+      ? `knowgraph: pipeline ${run.pipeline} failed at ${failed.name}`
+      : `knowgraph: pipeline ${run.pipeline} passed`,
+    lines: run.steps.map((step) => `- ${formatStepOutcome(step)}`),
+    data: { pipeline: run.pipeline, ok: run.ok, steps: [...run.steps] },
+  };
+}
+
+/**
+ * Run the planned steps in order. After a command fails, the remaining
+ * commands are skipped but notify steps still run, so a pipeline can
+ * report its own failure.
+ */
+export async function runPipeline(
+  pipeline: string,
+  plan: readonly PlannedStep[],
+  runner: PipelineRunner,
+): Promise<PipelineRun> {
+  const now = runner.now ?? (() => performance.now());
+  const steps: StepOutcome[] = [];
+  const run = (): PipelineRun => ({
+    pipeline,
+    ok: steps.every((step) => step.status === 'ok'),
+    steps,
+  });
+  for (const step of plan) {
+    if ('sinks' in step) {
+      runner.log(chalk.bold(`==> notify ${step.sinks.join(', ')}`));
+      await runner.notify(step.sinks, pipelineMessage(run()));
+      continue;
+    }
+    if (!run().ok) {
+      steps.push({ name: step.name, status: 'skipped', ms: 0 });
+      continue;
+    }
+    runner.log(
+      `${chalk.bold(`==> ${step.name}`)} ${chalk.dim(`knowgraph ${step.args.join(' ')}`)}`,
+    );
+    const started = now();
+    const exitCode = await runner.exec(step.args);
+    steps.push({
+      name: step.name,
+      status: exitCode === 0 ? 'ok' : 'failed',
+      exitCode,
+      ms: now() - started,
+    });
+  }
+  return run();
+}
+
+/**
+ * Run this CLI again with `args` in `cwd`, passing on the global options
+ * so errors and logs keep the format asked for.
+ */
+function execKnowgraph(
+  args: readonly string[],
+  cwd: string,
+  globals: readonly string[],
+): Promise<number> {
+  return new Promise((done, fail) => {
+    const child = spawn(
+      process.execPath,
+      [...process.execArgv, process.argv[1], ...globals, ...args],
+      { cwd, stdio: 'inherit' },
+    );
+    child.on('error', fail);
+    child.on('close', (code) => done(code ?? 1));
+  });
+}
+
+async function runPipelineCommand(
+  name: string,
+  options: RunCommandOptions,
+  globals: readonly string[],
+): Promise<void> {
+  const configPath = resolve(options.config);
+  let plan: readonly PlannedStep[];
+  try {
+    const pipelines = readPipelines(configPath);
+    const steps = Object.hasOwn(pipelines, name) ? pipelines[name] : undefined;
+    if (!steps) {
+      const names = Object.keys(pipelines).join(', ') || 'none';
+      throw createKnowgraphError(
+        'usage',
+        `Unknown pipeline '${name}'. Available: ${names}`,
+      );
+    }
+    plan = planPipeline(steps);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.dryRun) {
+    for (const step of plan) {
+      console.log(
+        'sinks' in step
+          ? `notify ${step.sinks.join(', ')}`
+          : `knowgraph ${step.args.join(' ')}`,
+      );
+    }
+    return;
+  }
+
+  const cwd = dirname(configPath);
+  let run: PipelineRun;
+  try {
+    run = await runPipeline(name, plan, {
+      exec: (args) => execKnowgraph(args, cwd, globals),
+      notify: (sinks, message) => notifyAlertSinks(configPath, sinks, message),
+      log: (line) => console.log(line),
+    });
+  } catch (err) {
+    reportError(err, 'io');
+    return;
+  }
+
+  console.log('');
+  console.log(chalk.bold(`Pipeline ${name}:`));
+  for (const step of run.steps) {
+    const line = `  ${formatStepOutcome(step)}`;
+    console.log(
+      step.status === 'ok'
+        ? line
+        : step.status === 'failed'
+          ? chalk.red(line)
+          : chalk.dim(line),
+    );
+  }
+  // The failing command has reported why; exit as it did
+  const failed = run.steps.find((step) => step.status === 'failed');
+  if (failed) process.exitCode = failed.exitCode;
+}
+
+export function registerRunCommand(program: Command): void {
+  program
+    .command('run <pipeline>')
+    .description(
+      'Run a pipeline of scan, enrich, check, export, and notify steps from .knowgraph.yml',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--dry-run', 'Print the commands the pipeline would run')
+    .action(async (pipeline: string, options: RunCommandOptions) => {
+      const { errorFormat, logLevel, logFormat } = program.opts<{
+        errorFormat: string;
+        logLevel: string;
+        logFormat: string;
+      }>();
+      await runPipelineCommand(pipeline, options, [
+        '--error-format',
+        errorFormat,
+        '--log-level',
+        logLevel,
+        '--log-format',
+        logFormat,
+      ]);
+    });
+}
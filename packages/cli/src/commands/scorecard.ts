@@ -0,0 +1,471 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that grades each owning team and the maturity of each module, and renders scorecards as text, Markdown, HTML, or a Slack post
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, scorecard, ownership, slack, html]
+ * context:
+ *   business_goal: Give platform teams a per-team grade that makes annotation adoption visible and competitive
+ *   domain: cli
+ */
+import { readFileSync, statSync, writeFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  DEFAULT_STALE_AFTER_DAYS,
+  appendScorecardReport,
+  buildMaturityReport,
+  buildScorecards,
+  calculateCoverage,
+  createDefaultLintRules,
+  createLinter,
+  createPluginLintRule,
+  createValidator,
+  describeCriterion,
+  listTestFiles,
+  readScorecardHistory,
+} from '@know-graph/core';
+import type {
+  MaturityReport,
+  ModuleMaturity,
+  OwnerScorecard,
+  ScorecardGrade,
+  ScorecardReport,
+  ScorecardTrend,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+import { createManifestDeliveryClient } from '../utils/delivery.js';
+import { scorecardHistoryPath } from '../utils/history.js';
+import {
+  readDescriptionThresholds,
+  readFreshnessConfig,
+  readMaturityRubric,
+  readPlugins,
+  readRuleSeverities,
+} from '../utils/manifest.js';
+import { combineCheckResults } from './check.js';
+
+interface ScorecardCommandOptions {
+  readonly db: string;
+  readonly config: string;
+  readonly format: string;
+  readonly staleDays: string;
+  readonly previous?: string;
+  readonly output?: string;
+  readonly slack?: string;
+  readonly record?: boolean;
+}
+
+const FORMATS = ['text', 'json', 'markdown', 'html'];
+
+const ARROWS: Readonly<Record<ScorecardTrend, string>> = {
+  up: '↑',
+  down: '↓',
+  steady: '→',
+};
+
+const HEADERS = [
+  'Team',
+  'Grade',
+  'Score',
+  'Coverage',
+  'Stale',
+  'Findings',
+  'Deprecated deps',
+  'Missing runbooks',
+  'Weak descriptions',
+];
+
+function trendLabel(card: OwnerScorecard): string {
+  if (card.trend === null || card.previousScore === null) return '';
+  const delta = card.score - card.previousScore;
+  const change = delta === 0 ? '' : ` ${delta > 0 ? '+' : ''}${delta}`;
+  return ` ${ARROWS[card.trend]}${change}`;
+}
+
+/** The metric columns of one row, after team, grade, and score. */
+function metricCells(card: OwnerScorecard): readonly string[] {
+  const { metrics } = card;
+  return [
+    metrics.coverage === null ? '-' : `${metrics.coverage}%`,
+    metrics.dated === 0 ? '-' : `${metrics.stale}/${metrics.dated}`,
+    String(metrics.violations),
+    String(metrics.deprecatedDependencies),
+    metrics.needRunbook === 0
+      ? '-'
+      : `${metrics.missingRunbooks}/${metrics.needRunbook}`,
+    `${metrics.weakDescriptions}/${metrics.entities}`,
+  ];
+}
+
+function gradeColor(grade: ScorecardGrade): (text: string) => string {
+  if (grade === 'A' || grade === 'B') return chalk.green;
+  if (grade === 'C') return chalk.yellow;
+  return chalk.red;
+}
+
+export function formatScorecardText(report: ScorecardReport): string {
+  if (report.scorecards.length === 0) {
+    return 'No entities indexed; nothing to grade.';
+  }
+  const rows = report.scorecards.map((card) => [
+    card.owner,
+    card.grade,
+    `${card.score}${trendLabel(card)}`,
+    ...metricCells(card),
+  ]);
+  const widths = HEADERS.map((header, i) =>
+    Math.max(header.length, ...rows.map((row) => row[i].length)),
+  );
+  const pad = (cells: readonly string[]): string[] =>
+    cells.map((cell, i) => cell.padEnd(widths[i]));
+  return [
+    chalk.bold(`Team scorecards (${report.scorecards.length} teams)`),
+    chalk.dim(
+      `Stale: not changed in ${report.staleAfterDays} days. Generated ${report.generatedAt}`,
+    ),
+    '',
+    chalk.dim(pad(HEADERS).join('  ').trimEnd()),
+    ...rows.map((row) => {
+      const cells = pad(row);
+      cells[1] = gradeColor(row[1] as ScorecardGrade)(cells[1]);
+      return cells.join('  ').trimEnd();
+    }),
+  ].join('\n');
+}
+
+/** What `module` needs for the level above its own, or null at the top. */
+function nextStep(module: ModuleMaturity): string | null {
+  const [step] = module.roadmap;
+  if (!step) return null;
+  return `${step.name}: needs ${step.missing.map(describeCriterion).join(', ')}`;
+}
+
+function levelLabel(module: ModuleMaturity): string {
+  return module.levelName
+    ? `${module.level} ${module.levelName}`
+    : `${module.level}`;
+}
+
+/** Modules by maturity level, each with what it needs to reach the next. */
+export function formatMaturityText(maturity: MaturityReport): string {
+  const summary = maturity.counts
+    .map((count, level) => {
+      const name = level === 0 ? 'none' : maturity.levels[level - 1].name;
+      return `${name} ${count}`;
+    })
+    .join(', ');
+  return [
+    chalk.bold(`Module maturity (${maturity.modules.length} modules)`),
+    chalk.dim(summary),
+    '',
+    ...maturity.modules.map((module) => {
+      const next = nextStep(module);
+      return `${levelLabel(module).padEnd(14)} ${module.name} ${chalk.dim(module.filePath)}${next ? chalk.dim(` -> ${next}`) : ''}`;
+    }),
+  ].join('\n');
+}
+
+function markdownCell(value: string): string {
+  return value.replace(/\|/g, '\\|');
+}
+
+const MATURITY_HEADERS = ['Module', 'Owner', 'Level', 'Next'];
+
+function maturityCells(module: ModuleMaturity): readonly string[] {
+  return [
+    `${module.name} (${module.filePath})`,
+    module.owner ?? '-',
+    levelLabel(module),
+    nextStep(module) ?? '-',
+  ];
+}
+
+export function formatMaturityMarkdown(maturity: MaturityReport): string {
+  const lines = ['## Module maturity', ''];
+  if (maturity.modules.length === 0) {
+    lines.push('No modules to grade.');
+    return `${lines.join('\n')}\n`;
+  }
+  lines.push(
+    `| ${MATURITY_HEADERS.join(' | ')} |`,
+    `|${MATURITY_HEADERS.map(() => '---').join('|')}|`,
+    ...maturity.modules.map(
+      (module) =>
+        `| ${maturityCells(module).map(markdownCell).join(' | ')} |`,
+    ),
+  );
+  return `${lines.join('\n')}\n`;
+}
+
+export function formatScorecardMarkdown(report: ScorecardReport): string {
+  const lines = [
+    '# Team scorecards',
+    '',
+    `Generated ${report.generatedAt}. Stale means not changed in ${report.staleAfterDays} days.`,
+  ];
+  if (report.scorecards.length === 0) {
+    lines.push('', 'No entities indexed; nothing to grade.');
+    return `${lines.join('\n')}\n`;
+  }
+  lines.push(
+    '',
+    `| ${HEADERS.join(' | ')} |`,
+    `|${HEADERS.map(() => '---').join('|')}|`,
+    ...report.scorecards.map((card) => {
+      const cells = [
+        markdownCell(card.owner),
+        `**${card.grade}**`,
+        `${card.score}${trendLabel(card)}`,
+        ...metricCells(card),
+      ];
+      return `| ${cells.join(' | ')} |`;
+    }),
+  );
+  return `${lines.join('\n')}\n`;
+}
+
+function escapeHtml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;');
+}
+
+const HTML_STYLE = [
+  'body{font-family:system-ui,sans-serif;margin:2rem;color:#1f2328}',
+  'table{border-collapse:collapse}',
+  'th,td{border:1px solid #d0d7de;padding:.4rem .8rem;text-align:left}',
+  '.grade{font-weight:bold;text-align:center}',
+  '.A,.B{background:#dafbe1}.C{background:#fff8c5}.D,.F{background:#ffebe9}',
+].join('');
+
+function maturityHtml(maturity: MaturityReport | undefined): string[] {
+  if (!maturity || maturity.modules.length === 0) return [];
+  const rows = maturity.modules.map((module) => {
+    const cells = maturityCells(module).map(
+      (cell) => `      <td>${escapeHtml(cell)}</td>`,
+    );
+    return ['    <tr>', ...cells, '    </tr>'].join('\n');
+  });
+  return [
+    '<h2>Module maturity</h2>',
+    '<table>',
+    '  <thead>',
+    `    <tr>${MATURITY_HEADERS.map((header) => `<th>${header}</th>`).join('')}</tr>`,
+    '  </thead>',
+    '  <tbody>',
+    ...rows,
+    '  </tbody>',
+    '</table>',
+  ];
+}
+
+/** A standalone page, for publishing from CI or attaching to a report. */
+export function formatScorecardHtml(
+  report: ScorecardReport,
+  maturity?: MaturityReport,
+): string {
+  const rows = report.scorecards.map((card) => {
+    const cells = [
+      `      <td>${escapeHtml(card.owner)}</td>`,
+      `      <td class="grade ${card.grade}">${card.grade}</td>`,
+      `      <td>${card.score}${trendLabel(card)}</td>`,
+      ...metricCells(card).map((cell) => `      <td>${cell}</td>`),
+    ];
+    return ['    <tr>', ...cells, '    </tr>'].join('\n');
+  });
+  return [
+    '<!DOCTYPE html>',
+    '<html lang="en">',
+    '<head>',
+    '<meta charset="utf-8">',
+    '<title>Team scorecards</title>',
+    `<style>${HTML_STYLE}</style>`,
+    '</head>',
+    '<body>',
+    '<h1>Team scorecards</h1>',
+    `<p>Generated ${escapeHtml(report.generatedAt)}. Stale means not changed in ${report.staleAfterDays} days.</p>`,
+    '<table>',
+    '  <thead>',
+    `    <tr>${HEADERS.map((header) => `<th>${header}</th>`).join('')}</tr>`,
+    '  </thead>',
+    '  <tbody>',
+    ...rows,
+    '  </tbody>',
+    '</table>',
+    ...maturityHtml(maturity),
+    '</body>',
+    '</html>',
+    '',
+  ].join('\n');
+}
+
+/** A Slack incoming webhook message: one line per team, best first. */
+export function formatScorecardSlack(report: ScorecardReport): {
+  readonly text: string;
+} {
+  const lines = report.scorecards.map(
+    (card) => `*${card.grade}* ${card.owner}: ${card.score}${trendLabel(card)}`,
+  );
+  return {
+    text: [`*Team scorecards* (${report.generatedAt})`, ...lines].join('\n'),
+  };
+}
+
+function render(
+  report: ScorecardReport,
+  maturity: MaturityReport,
+  format: string,
+): string {
+  switch (format) {
+    case 'json':
+      return `${formatJson({ ...report, maturity }, true)}\n`;
+    case 'markdown':
+      return `${formatScorecardMarkdown(report)}\n${formatMaturityMarkdown(maturity)}`;
+    case 'html':
+      return formatScorecardHtml(report, maturity);
+    default:
+      return `${formatScorecardText(report)}\n\n${formatMaturityText(maturity)}\n`;
+  }
+}
+
+async function runScorecard(
+  targetPath: string,
+  options: ScorecardCommandOptions,
+): Promise<void> {
+  if (!FORMATS.includes(options.format)) {
+    reportError(
+      `Unknown format "${options.format}" (use ${FORMATS.join('|')})`,
+      'usage',
+    );
+    return;
+  }
+  const staleAfterDays = Number(options.staleDays);
+  if (!Number.isInteger(staleAfterDays) || staleAfterDays < 1) {
+    reportError('--stale-days must be a positive integer', 'usage');
+    return;
+  }
+  const rootDir = resolve(targetPath);
+  const configPath = resolve(options.config);
+  try {
+    statSync(rootDir);
+  } catch {
+    reportError(`Path not found: ${rootDir}`, 'io');
+    return;
+  }
+
+  const dbPath = resolve(options.db);
+  const entities = loadEntities(dbPath, configPath);
+  if (!entities) return;
+
+  let report: ScorecardReport;
+  let maturity: MaturityReport;
+  try {
+    const severities = readRuleSeverities(configPath);
+    const descriptionThresholds = readDescriptionThresholds(configPath);
+    const linter = createLinter(
+      createDefaultLintRules({ descriptionThresholds }),
+      readPlugins(configPath)
+        .filter((plugin) => plugin.rules)
+        .map(createPluginLintRule),
+    );
+    const check = combineCheckResults(
+      createValidator().validate(rootDir, { severities }),
+      linter.lint(rootDir),
+      rootDir,
+      severities,
+    );
+    const historyPath = scorecardHistoryPath(configPath);
+    const previous = options.previous
+      ? (JSON.parse(
+          readFileSync(resolve(options.previous), 'utf-8'),
+        ) as ScorecardReport)
+      : readScorecardHistory(historyPath).at(-1);
+    const graph = buildGraph(dbPath, entities, { configPath });
+    report = buildScorecards(entities, graph, {
+      coverage: calculateCoverage({ rootDir }).byOwner,
+      // Weak descriptions are graded on their own, not as findings too
+      findings: check.findings.filter(
+        (f) => f.severity !== 'info' && f.rule !== 'description-quality',
+      ),
+      staleAfterDays,
+      descriptionThresholds,
+      previous,
+    });
+    if (options.record) appendScorecardReport(historyPath, report);
+    maturity = buildMaturityReport(entities, {
+      rubric: readMaturityRubric(configPath),
+      testFiles: listTestFiles(rootDir),
+      deadlines: readFreshnessConfig(configPath)?.deadlines,
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const content = render(report, maturity, options.format);
+  if (options.output) {
+    writeFileSync(resolve(options.output), content, 'utf-8');
+    console.error(chalk.green(`Wrote scorecards to ${options.output}`));
+  } else {
+    process.stdout.write(content);
+  }
+
+  if (options.slack) {
+    const client = createManifestDeliveryClient(configPath);
+    try {
+      const outcome = await client.send({
+        sink: 'slack',
+        url: options.slack,
+        body: JSON.stringify(formatScorecardSlack(report)),
+      });
+      console.error(
+        outcome === 'queued'
+          ? chalk.yellow('Could not reach Slack; queued the scorecards')
+          : chalk.green('Posted scorecards to Slack'),
+      );
+    } catch (err) {
+      reportError(err, 'io');
+    }
+  }
+}
+
+export function registerScorecardCommand(program: Command): void {
+  program
+    .command('scorecard')
+    .description(
+      'Grade each owning team on coverage, freshness, findings, deprecated dependencies, and runbooks, and each module on the maturity rubric',
+    )
+    .argument('[path]', 'Indexed directory to check', '.')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option(
+      '--format <format>',
+      'Output format (text|json|markdown|html)',
+      'text',
+    )
+    .option(
+      '--stale-days <days>',
+      'Days without a change before an entity is stale',
+      String(DEFAULT_STALE_AFTER_DAYS),
+    )
+    .option(
+      '--previous <path>',
+      'Earlier JSON scorecards to show trends against (default: the last recorded)',
+    )
+    .option('--output <path>', 'Write the scorecards to a file')
+    .option(
+      '--record',
+      'Append the scorecards to the history served for trends',
+    )
+    .option('--slack <url>', 'Also post the grades to a Slack incoming webhook')
+    .action(async (targetPath: string, options: ScorecardCommandOptions) => {
+      await runScorecard(targetPath, options);
+    });
+}
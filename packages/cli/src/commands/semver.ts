@@ -0,0 +1,107 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that suggests a semantic version bump from the graph and Go API changes between two git refs
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, release, semver, diff]
+ * context:
+ *   business_goal: Let release automation pick the version number from what actually changed rather than from commit messages
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { suggestVersionBump } from '@know-graph/core';
+import type {
+  BumpReason,
+  VersionBump,
+  VersionSuggestion,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+import { snapshotRef, snapshotTree } from '../utils/release.js';
+
+interface SemverCommandOptions {
+  readonly current?: string;
+  readonly format: string;
+}
+
+const BUMP_COLORS: Readonly<Record<VersionBump, (text: string) => string>> = {
+  major: chalk.red,
+  minor: chalk.yellow,
+  patch: chalk.green,
+};
+
+function reasonLine(reason: BumpReason): string {
+  return `  ${BUMP_COLORS[reason.bump](reason.bump.padEnd(5))} ${reason.subject} ${chalk.dim(reason.detail)}`;
+}
+
+export function formatVersionSuggestion(
+  suggestion: VersionSuggestion,
+): string {
+  const { bump, reasons, current, next } = suggestion;
+  const version = next ? ` ${chalk.dim(`${current} ->`)} ${next}` : '';
+  const lines = [
+    `${chalk.bold('Suggested bump:')} ${BUMP_COLORS[bump](bump)}${version}`,
+  ];
+  if (reasons.length === 0) {
+    lines.push(chalk.dim('  No entity or API changes; defaulting to patch.'));
+    return lines.join('\n');
+  }
+  // Patch reasons are every touched entity; show the ones that decided it
+  const deciding = reasons.filter(
+    (reason) => reason.bump === bump || reason.bump !== 'patch',
+  );
+  lines.push(...deciding.map(reasonLine));
+  const rest = reasons.length - deciding.length;
+  if (rest > 0) {
+    lines.push(chalk.dim(`  ... and ${rest} patch-level change(s)`));
+  }
+  return lines.join('\n');
+}
+
+function runSemver(
+  base: string,
+  head: string | undefined,
+  options: SemverCommandOptions,
+): void {
+  let suggestion: VersionSuggestion;
+  try {
+    const root = resolve('.');
+    const before = snapshotRef(root, base);
+    const after = head ? snapshotRef(root, head) : snapshotTree(root);
+    suggestion = suggestVersionBump(before, after, options.current);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(suggestion, true));
+  } else {
+    console.log(formatVersionSuggestion(suggestion));
+  }
+}
+
+export function registerSemverCommand(program: Command): void {
+  program
+    .command('semver <base> [head]')
+    .description(
+      'Suggest a semver bump from graph and API changes between two refs (head defaults to the working tree)',
+    )
+    .option(
+      '--current <version>',
+      'Current version to apply the suggested bump to',
+    )
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action(
+      (
+        base: string,
+        head: string | undefined,
+        options: SemverCommandOptions,
+      ) => {
+        runSemver(base, head, options);
+      },
+    );
+}
@@ -1,33 +1,477 @@
 /**
  * @knowgraph
  * type: module
- * description: CLI command that starts the MCP server for AI agent integration
+ * description: CLI command that starts the MCP server, or the gRPC API, event stream, and inbound webhooks
  * owner: knowgraph-cli
  * status: stable
- * tags: [cli, command, serve, mcp]
+ * tags: [cli, command, serve, mcp, grpc, auth, webhooks]
  * context:
  *   business_goal: Enable AI assistants to query the code graph via MCP protocol
  *   domain: cli
  */
-import { resolve } from 'node:path';
+import { dirname, join, resolve } from 'node:path';
 import { existsSync } from 'node:fs';
 import type { Command } from 'commander';
 import chalk from 'chalk';
+import {
+  classifyError,
+  createGraphStore,
+  createRegistryStore,
+  parseCronExpression,
+  parseRemoteSource,
+  redactUrl,
+} from '@know-graph/core';
+import type {
+  CronSchedule,
+  SavedQueryConfig,
+  ServeConfig,
+} from '@know-graph/core';
+import type {
+  AuthOptions,
+  InboundWebhookOptions,
+  NamespacedIndex,
+  RegistryApiOptions,
+  SimulationApiOptions,
+  TrendSource,
+} from '@know-graph/mcp-server';
+import {
+  readCycleBudgets,
+  readEncryptionOptions,
+  readNamespace,
+  readSavedQueries,
+  readServeConfig,
+} from '../utils/manifest.js';
+import { reportError } from '../utils/errors.js';
+import { historyPath, scorecardHistoryPath } from '../utils/history.js';
+import { configureLogging, getLogger } from '../utils/logging.js';
+import { startScanSchedule } from '../utils/scan-schedule.js';
+import {
+  flushTelemetryUntil,
+  getTelemetry,
+  startTelemetry,
+} from '../utils/telemetry.js';
 
 interface ServeOptions {
   readonly db: string;
   readonly verbose?: boolean;
+  readonly grpc?: string;
+  readonly http?: string;
+  readonly config: string;
+  readonly allowUnauthenticated?: boolean;
+  readonly scanSchedule?: string;
+  readonly scanPath: string;
 }
 
-async function runServe(options: ServeOptions): Promise<void> {
+function requireEnv(
+  name: string,
+  env: Readonly<Record<string, string | undefined>>,
+): string {
+  const value = env[name];
+  if (!value) throw new Error(`Environment variable "${name}" is not set`);
+  return value;
+}
+
+/**
+ * Turn the manifest's `serve` settings into server auth options, reading
+ * token and secret values from the environment. Throws when a referenced
+ * variable is unset.
+ */
+export function resolveAuthOptions(
+  config: ServeConfig,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): AuthOptions {
+  const { auth, restricted_fields: restrictedFields, namespaces } = config;
+  const jwt = auth?.jwt;
+  return {
+    tokens: auth?.tokens?.map((token) => ({
+      name: token.name,
+      token: requireEnv(token.token_env, env),
+      roles: token.roles,
+    })),
+    jwt: jwt && {
+      issuer: jwt.issuer,
+      audience: jwt.audience,
+      jwksUri: jwt.jwks_uri,
+      secret: jwt.secret_env ? requireEnv(jwt.secret_env, env) : undefined,
+      rolesClaim: jwt.roles_claim,
+    },
+    restrictedFields,
+    namespaceRoles: Object.fromEntries(
+      Object.entries(namespaces ?? {}).flatMap(([namespace, { roles }]) =>
+        roles ? [[namespace, roles] as const] : [],
+      ),
+    ),
+  };
+}
+
+/**
+ * The further indexes `serve.namespaces` hosts, with `db` paths resolved
+ * against the manifest's directory.
+ */
+export function resolveNamespacedIndexes(
+  config: ServeConfig,
+  configPath: string,
+): readonly NamespacedIndex[] {
+  return Object.entries(config.namespaces ?? {}).flatMap(
+    ([namespace, { db }]) =>
+      db ? [{ namespace, dbPath: resolve(dirname(configPath), db) }] : [],
+  );
+}
+
+/**
+ * Where each hosted index keeps its scan history and recorded scorecards:
+ * the manifest's own for the `--db` index, and for each `serve.namespaces`
+ * index its `history` and `scorecards` paths, or files next to its `db`.
+ */
+export function resolveTrendSources(
+  config: ServeConfig,
+  configPath: string,
+  namespace?: string,
+): readonly TrendSource[] {
+  const base = dirname(configPath);
+  const hosted = Object.entries(config.namespaces ?? {}).flatMap(
+    ([name, entry]): TrendSource[] => {
+      if (!entry.db) return [];
+      const dir = dirname(resolve(base, entry.db));
+      return [
+        {
+          namespace: name,
+          historyPath: entry.history
+            ? resolve(base, entry.history)
+            : join(dir, 'history.jsonl'),
+          scorecardsPath: entry.scorecards
+            ? resolve(base, entry.scorecards)
+            : join(dir, 'scorecards.jsonl'),
+        },
+      ];
+    },
+  );
+  return [
+    {
+      namespace,
+      historyPath: historyPath(configPath),
+      scorecardsPath: scorecardHistoryPath(configPath),
+    },
+    ...hosted,
+  ];
+}
+
+/**
+ * The registry API settings from `serve.registry`, with the store and the
+ * pushed graphs opened from their paths relative to the manifest's
+ * directory. Undefined without the section. Throws when the file is not a
+ * registry.
+ */
+export function resolveRegistry(
+  config: ServeConfig,
+  configPath: string,
+): RegistryApiOptions | undefined {
+  const { registry } = config;
+  if (!registry) return undefined;
+  return {
+    store: createRegistryStore(resolve(dirname(configPath), registry.path)),
+    adminRoles: registry.admin_roles,
+    graphs: createGraphStore(resolve(dirname(configPath), registry.graphs)),
+  };
+}
+
+/**
+ * The inbound webhook settings from `serve.webhooks`, with secrets read
+ * from the environment and the fact log resolved against the manifest's
+ * directory. Undefined without the section. Throws when a secret's
+ * variable is unset.
+ */
+export function resolveWebhooks(
+  config: ServeConfig,
+  configPath: string,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): InboundWebhookOptions | undefined {
+  const { webhooks } = config;
+  if (!webhooks) return undefined;
+  return {
+    logPath: resolve(dirname(configPath), webhooks.path),
+    sources: Object.fromEntries(
+      Object.entries(webhooks.sources).map(([name, source]) => [
+        name,
+        {
+          secret: requireEnv(source.secret_env, env),
+          signatureHeader: source.signature_header,
+          mapping: { node: source.node, facts: source.facts },
+        },
+      ]),
+    ),
+  };
+}
+
+/** Whether `host` only accepts connections from this machine. */
+export function isLoopbackHost(host: string): boolean {
+  return (
+    host === 'localhost' ||
+    host === '::1' ||
+    host === '[::1]' ||
+    /^127\.\d+\.\d+\.\d+$/.test(host)
+  );
+}
+
+/**
+ * Split a `--grpc` or `--http` address into host and port. A bare port
+ * binds 127.0.0.1. Returns undefined for anything that is not host:port.
+ */
+export function parseListenAddress(
+  address: string,
+): { readonly host: string; readonly port: number } | undefined {
+  const match = /^(?:(.+):)?(\d+)$/.exec(address);
+  if (!match) return undefined;
+  const port = Number(match[2]);
+  if (port > 65535) return undefined;
+  return { host: match[1] ?? '127.0.0.1', port };
+}
+
+function parseFlag(
+  flag: string,
+  address: string | undefined,
+): { readonly host: string; readonly port: number } | null | undefined {
+  if (address === undefined) return undefined;
+  const listen = parseListenAddress(address);
+  if (!listen) {
+    reportError(
+      `Invalid ${flag} address "${address}" (use host:port)`,
+      'usage',
+    );
+    return null;
+  }
+  return listen;
+}
+
+/**
+ * What the merge request simulation API needs: the `--scan-path` checkout,
+ * when it is a local directory, to read changed files from, and the
+ * manifest's cycle budgets.
+ */
+export function resolveSimulation(
+  scanPath: string,
+  configPath: string,
+): SimulationApiOptions {
+  const rootDir = resolve(scanPath);
+  const local = !parseRemoteSource(scanPath) && existsSync(rootDir);
+  return {
+    ...(local ? { rootDir } : {}),
+    cycles: readCycleBudgets(configPath),
+  };
+}
+
+/**
+ * The rescan schedule from `--scan-schedule`, falling back to the
+ * manifest's `serve.scan_schedule`; undefined when neither is set. Throws
+ * when the expression is not valid cron.
+ */
+export function resolveScanSchedule(
+  flag: string | undefined,
+  config: ServeConfig,
+): CronSchedule | undefined {
+  const expression = flag ?? config.scan_schedule;
+  return expression === undefined ? undefined : parseCronExpression(expression);
+}
+
+/**
+ * Start rescanning on the configured schedule, if any. Returns false when
+ * the schedule could not be read, after reporting why.
+ */
+function startScans(
+  dbPath: string,
+  options: ServeOptions,
+  signal: AbortSignal,
+): boolean {
+  let config: ServeConfig;
+  try {
+    config = readServeConfig(resolve(options.config));
+  } catch (err) {
+    reportError(err);
+    return false;
+  }
+  let schedule: CronSchedule | undefined;
+  try {
+    schedule = resolveScanSchedule(options.scanSchedule, config);
+  } catch (err) {
+    reportError(
+      err,
+      'usage',
+      'Use five cron fields, such as "0 */6 * * *" for every six hours.',
+    );
+    return false;
+  }
+  if (!schedule) return true;
+
+  let target: string;
+  try {
+    target = parseRemoteSource(options.scanPath)
+      ? options.scanPath
+      : resolve(options.scanPath);
+  } catch (err) {
+    reportError(err);
+    return false;
+  }
+  // A long-running process: stamp text records with their time too
+  configureLogging({ timestamps: true });
+  const task = startScanSchedule(schedule, target, dbPath, signal);
+  const next = task.next();
+  getLogger().info(
+    next
+      ? `Rescanning ${redactUrl(target)} on "${schedule.expression}"; next scan at ${next.toLocaleString()}`
+      : `"${schedule.expression}" never matches; no rescans will run`,
+    { schedule: schedule.expression, next: next?.toISOString() ?? null },
+  );
+  return true;
+}
+
+async function runNetworkServe(
+  dbPath: string,
+  options: ServeOptions,
+  controller: AbortController,
+): Promise<void> {
+  const grpc = parseFlag('--grpc', options.grpc);
+  const http = parseFlag('--http', options.http);
+  if (grpc === null || http === null) {
+    return;
+  }
+
+  const configPath = resolve(options.config);
+  let auth: AuthOptions;
+  let indexes: readonly NamespacedIndex[];
+  let registry: RegistryApiOptions | undefined;
+  let trends: readonly TrendSource[];
+  let webhooks: InboundWebhookOptions | undefined;
+  let savedQueries: Readonly<Record<string, SavedQueryConfig>>;
+  let simulation: SimulationApiOptions;
+  try {
+    const config = readServeConfig(configPath);
+    savedQueries = readSavedQueries(configPath);
+    registry = resolveRegistry(config, configPath);
+    webhooks = resolveWebhooks(config, configPath);
+    // Registry tokens authenticate gRPC calls too
+    auth = { ...resolveAuthOptions(config), registry: registry?.store };
+    indexes = resolveNamespacedIndexes(config, configPath);
+    trends = resolveTrendSources(config, configPath, readNamespace(configPath));
+    simulation = resolveSimulation(options.scanPath, configPath);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  const missing = indexes.find(({ dbPath }) => !existsSync(dbPath));
+  if (missing) {
+    reportError(
+      `Database for namespace ${missing.namespace} not found at ${missing.dbPath}`,
+      'io',
+    );
+    return;
+  }
+  const sources = { dbPath, namespace: readNamespace(configPath), indexes };
+
+  const authenticated = Boolean(
+    auth.tokens?.length || auth.jwt || auth.registry,
+  );
+  const exposed = [grpc, http].some(
+    (listen) => listen && !isLoopbackHost(listen.host),
+  );
+  if (exposed && !authenticated && !options.allowUnauthenticated) {
+    reportError(
+      'Refusing to serve the graph beyond localhost without authentication',
+      'usage',
+      `Configure serve.auth in ${options.config}, or pass --allow-unauthenticated.`,
+    );
+    return;
+  }
+
+  const { signal } = controller;
+  try {
+    const { startGrpcServer, startHttpServer, PROTO_PATH } = await import(
+      '@know-graph/mcp-server'
+    );
+    console.log(chalk.bold('KnowGraph server listening'));
+    console.log(`  Database: ${chalk.cyan(dbPath)}`);
+    if (sources.namespace || indexes.length > 0) {
+      const hosted = [sources.namespace, ...indexes.map((i) => i.namespace)];
+      console.log(`  Hosting:  ${hosted.filter(Boolean).join(', ')}`);
+    }
+    console.log(
+      `  Auth:     ${authenticated ? 'bearer token' : chalk.yellow('none')}`,
+    );
+    if (grpc) {
+      const server = await startGrpcServer({
+        ...sources,
+        ...grpc,
+        auth,
+        telemetry: getTelemetry(),
+        signal,
+      });
+      console.log(`  gRPC:     ${chalk.cyan(`${grpc.host}:${server.port}`)}`);
+      console.log(`  Proto:    ${chalk.dim(PROTO_PATH)}`);
+    }
+    if (http) {
+      const server = await startHttpServer({
+        ...sources,
+        ...http,
+        auth,
+        registry,
+        trends,
+        webhooks,
+        savedQueries,
+        simulation,
+        telemetry: getTelemetry(),
+        signal,
+      });
+      const base = `http://${http.host}:${server.port}`;
+      console.log(`  Events:   ${chalk.cyan(`${base}/events`)}`);
+      console.log(`  Trends:   ${chalk.cyan(`${base}/trends/v1/`)}`);
+      console.log(
+        `  Simulate: ${chalk.cyan(`${base}/simulate/v1/merge-requests`)}`,
+      );
+      if (registry) {
+        console.log(`  Registry: ${chalk.cyan(`${base}/registry/v1/`)}`);
+      }
+      if (webhooks) {
+        console.log(`  Webhooks: ${chalk.cyan(`${base}/webhooks/v1/`)}`);
+      }
+    }
+  } catch (err) {
+    // Shut down whichever server did start
+    controller.abort();
+    reportError(
+      `Failed to start server: ${err instanceof Error ? err.message : String(err)}`,
+      classifyError(err),
+    );
+  }
+}
+
+async function runServe(
+  options: ServeOptions,
+  version: string,
+): Promise<void> {
   const dbPath = resolve(options.db);
 
   if (!existsSync(dbPath)) {
-    console.error(chalk.red(`Error: Database not found at ${dbPath}`));
-    console.error(
-      chalk.yellow("Run 'knowgraph index' first to create the database."),
+    reportError(
+      `Database not found at ${dbPath}`,
+      'io',
+      "Run 'knowgraph index' first to create the database.",
     );
-    process.exitCode = 1;
+    return;
+  }
+
+  // Close the server cleanly on Ctrl-C or a supervisor's SIGTERM
+  const controller = new AbortController();
+  for (const signal of ['SIGINT', 'SIGTERM'] as const) {
+    process.once(signal, () => controller.abort());
+  }
+  startTelemetry(resolve(options.config), version);
+  flushTelemetryUntil(controller.signal);
+  if (!startScans(dbPath, options, controller.signal)) {
+    controller.abort();
+    return;
+  }
+
+  if (options.grpc || options.http) {
+    await runNetworkServe(dbPath, options, controller);
     return;
   }
 
@@ -56,24 +500,55 @@ async function runServe(options: ServeOptions): Promise<void> {
 
   try {
     const { startServer } = await import('@know-graph/mcp-server');
-    await startServer({ dbPath, verbose: options.verbose });
+    await startServer({
+      dbPath,
+      ...readEncryptionOptions(resolve(options.config)),
+      verbose: options.verbose,
+      signal: controller.signal,
+    });
   } catch (err) {
-    console.error(
-      chalk.red(
-        `Failed to start MCP server: ${err instanceof Error ? err.message : String(err)}`,
-      ),
+    reportError(
+      `Failed to start MCP server: ${err instanceof Error ? err.message : String(err)}`,
+      classifyError(err),
     );
-    process.exitCode = 1;
   }
 }
 
 export function registerServeCommand(program: Command): void {
   program
     .command('serve')
-    .description('Start the MCP server')
+    .description(
+      'Start the MCP server, or the gRPC API and event stream over the network',
+    )
     .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
     .option('--verbose', 'Enable verbose logging')
-    .action((options: ServeOptions) => {
-      runServe(options);
+    .option(
+      '--grpc <address>',
+      'Serve the gRPC API on host:port instead of MCP over stdio',
+    )
+    .option(
+      '--http <address>',
+      'Stream graph change events (SSE, WebSocket) on host:port',
+    )
+    .option(
+      '--config <path>',
+      'Path to .knowgraph.yml with serve.auth settings',
+      '.knowgraph.yml',
+    )
+    .option(
+      '--allow-unauthenticated',
+      'Serve --grpc/--http beyond localhost without serve.auth',
+    )
+    .option(
+      '--scan-schedule <cron>',
+      'Rescan --scan-path on this cron schedule (default: serve.scan_schedule)',
+    )
+    .option(
+      '--scan-path <path>',
+      'Directory or git URL to rescan; a directory also serves merge request simulations',
+      '.',
+    )
+    .action(async (options: ServeOptions) => {
+      await runServe(options, program.version() ?? 'unknown');
     });
 }
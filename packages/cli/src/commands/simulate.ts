@@ -0,0 +1,200 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that simulates removing or replacing a dependency and prints a migration checklist grouped by team
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, simulation, migration, what-if, policy]
+ * context:
+ *   business_goal: Size a dependency migration and its policy fallout before anyone starts it
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { findGraphNodes, simulateDependencyChange } from '@know-graph/core';
+import type {
+  DependencyGraph,
+  MigrationTask,
+  SimulationResult,
+} from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import { readCycleBudgets } from '../utils/manifest.js';
+import { resolveGraphNode } from '../utils/nodes.js';
+
+interface SimulateCommandOptions {
+  readonly replaceWith?: string;
+  readonly db: string;
+  readonly format: string;
+  readonly check?: boolean;
+}
+
+function taskText(task: MigrationTask, result: SimulationResult): string {
+  const { removed, replacement } = result;
+  return replacement
+    ? `replace its ${task.kind} dependency on ${removed.name} with ${replacement.name}`
+    : `remove its ${task.kind} dependency on ${removed.name}`;
+}
+
+function headline(result: SimulationResult): string {
+  const { removed, replacement } = result;
+  return replacement
+    ? `Replacing ${removed.name} with ${replacement.name}`
+    : `Removing ${removed.name}`;
+}
+
+export function formatSimulation(result: SimulationResult): string {
+  const lines = [
+    `${chalk.bold(headline(result))} ${chalk.dim(`affects ${result.affected} node(s)`)}`,
+  ];
+  if (result.teams.length === 0) {
+    const { name } = result.removed;
+    lines.push(chalk.dim(`Nothing indexed depends on ${name}.`));
+  }
+  for (const team of result.teams) {
+    lines.push('', chalk.bold(team.owner ?? '(no owner)'));
+    for (const task of team.tasks) {
+      lines.push(
+        `  [ ] ${chalk.cyan(task.node.name)}: ${taskText(task, result)} ` +
+          chalk.dim(`(${task.provenance})`),
+      );
+    }
+    if (team.indirect.length > 0) {
+      const names = team.indirect.map((node) => node.name).join(', ');
+      lines.push(
+        chalk.dim(`  Affected through other dependencies: ${names}`),
+      );
+    }
+  }
+  lines.push('');
+  if (result.violations.length === 0) {
+    lines.push(chalk.green('No policy would newly fail.'));
+  } else {
+    lines.push(chalk.bold('Policies that would newly fail'));
+    for (const violation of result.violations) {
+      lines.push(`  ${chalk.red(violation.policy)} ${violation.message}`);
+    }
+  }
+  return lines.join('\n');
+}
+
+export function formatSimulationMarkdown(result: SimulationResult): string {
+  const lines = [
+    `# ${headline(result)}`,
+    '',
+    `Affects ${result.affected} node(s).`,
+  ];
+  if (result.teams.length === 0) {
+    lines.push('', `Nothing indexed depends on ${result.removed.name}.`);
+  }
+  for (const team of result.teams) {
+    lines.push('', `## ${team.owner ?? '(no owner)'}`, '');
+    for (const task of team.tasks) {
+      lines.push(
+        `- [ ] **${task.node.name}**: ${taskText(task, result)} (${task.provenance})`,
+      );
+    }
+    if (team.indirect.length > 0) {
+      const names = team.indirect.map((node) => node.name).join(', ');
+      if (team.tasks.length > 0) lines.push('');
+      lines.push(`Affected through other dependencies: ${names}`);
+    }
+  }
+  lines.push('', '## Policies that would newly fail', '');
+  if (result.violations.length === 0) lines.push('None.');
+  for (const violation of result.violations) {
+    lines.push(`- \`${violation.policy}\`: ${violation.message}`);
+  }
+  return lines.join('\n');
+}
+
+/**
+ * The id of the node `name` refers to, or `name` itself when it matches
+ * none, which the simulation treats as a new external dependency.
+ */
+function resolveReplacement(
+  graph: DependencyGraph,
+  name: string,
+): string | undefined {
+  if (findGraphNodes(graph, name).length === 0) return name;
+  return resolveGraphNode(graph, name, `--replace-with ${name}`)?.id;
+}
+
+function runSimulate(name: string, options: SimulateCommandOptions): void {
+  let graph: DependencyGraph;
+  let deprecated: string[];
+  try {
+    const dbPath = resolve(options.db);
+    const entities = loadEntities(dbPath);
+    if (!entities) return;
+    graph = buildGraph(dbPath, entities);
+    deprecated = entities
+      .filter((entity) => entity.status === 'deprecated')
+      .map((entity) => entity.id);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const removed = resolveGraphNode(graph, name);
+  if (!removed) return;
+  let replaceWith: string | undefined;
+  if (options.replaceWith !== undefined) {
+    replaceWith = resolveReplacement(graph, options.replaceWith);
+    if (replaceWith === undefined) return;
+  }
+
+  let result: SimulationResult;
+  try {
+    result = simulateDependencyChange(
+      graph,
+      { remove: removed.id, replaceWith },
+      { cycles: readCycleBudgets(resolve('.knowgraph.yml')), deprecated },
+    );
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    // The changed graph is for callers of the API, not the report
+    const { replacement, teams, affected, violations } = result;
+    console.log(
+      formatJson(
+        { removed: removed.id, replacement, teams, affected, violations },
+        true,
+      ),
+    );
+  } else if (options.format === 'markdown') {
+    console.log(formatSimulationMarkdown(result));
+  } else {
+    console.log(formatSimulation(result));
+  }
+  if (options.check && result.violations.length > 0) {
+    reportCheckFailure(
+      `${result.violations.length} policy violation(s) after the change`,
+      'policy',
+      { violations: result.violations.length },
+    );
+  }
+}
+
+export function registerSimulateCommand(program: Command): void {
+  program
+    .command('simulate <name>')
+    .description(
+      'Simulate removing or replacing a dependency: who has to migrate, by team, and which policies would newly fail',
+    )
+    .option(
+      '--replace-with <name>',
+      'Node that replaces it, or the name of a new external dependency',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|markdown|json)', 'text')
+    .option('--check', 'Exit 1 when a policy would newly fail')
+    .action((name: string, options: SimulateCommandOptions) => {
+      runSimulate(name, options);
+    });
+}
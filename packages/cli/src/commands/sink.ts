@@ -0,0 +1,199 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that checks each configured warehouse and alert sink's credentials and connectivity without running a scan
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, sinks, warehouse, alerts, diagnostics]
+ * context:
+ *   business_goal: Find a revoked token or a dead webhook when it is set up, not after a nightly scan silently skips it
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import type {
+  AlertMessage,
+  AlertSinkConfig,
+  WarehouseSinkConfig,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { createManifestDeliveryClient } from '../utils/delivery.js';
+import { createAlertSink } from '../utils/history.js';
+import { readHistoryConfig, readWarehouseConfig } from '../utils/manifest.js';
+import { createWarehouseSink } from '../utils/warehouse.js';
+
+interface SinkTestCommandOptions {
+  readonly config: string;
+  readonly format: string;
+}
+
+export interface SinkTestResult {
+  /** The sink's type, such as `bigquery` or `slack`. */
+  readonly sink: string;
+  readonly kind: 'warehouse' | 'alert';
+  /** Where the sink points, without secrets. */
+  readonly target: string;
+  readonly ok: boolean;
+  readonly error?: string;
+  readonly fix?: string;
+}
+
+const TEST_MESSAGE: AlertMessage = {
+  event: 'knowgraph.test',
+  subject: 'knowgraph: sink test',
+  lines: ['This sink is configured and reachable; nothing needs doing.'],
+  data: {},
+};
+
+function describeError(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+function warehouseTarget(sink: WarehouseSinkConfig): string {
+  return sink.type === 'bigquery'
+    ? `${sink.project}.${sink.dataset}`
+    : `${sink.account}/${sink.database}.${sink.schema}`;
+}
+
+function alertTarget(sink: AlertSinkConfig): string {
+  if (sink.type === 'file') return sink.path;
+  if (sink.type === 'email') return `${sink.host} to ${sink.to.join(', ')}`;
+  if (sink.url_env && !sink.url) return `$${sink.url_env}`;
+  // Webhook URLs carry their credentials, so only the host is shown
+  return sink.url ? new URL(sink.url).host : '';
+}
+
+function alertFix(sink: AlertSinkConfig): string {
+  if (sink.type === 'file') return 'Check the directory can be written to.';
+  if (sink.type === 'email') {
+    return 'Check host, port, and secure, and the SMTP login variables.';
+  }
+  const where = sink.url ? 'url' : sink.url_env;
+  return `Check the webhook in ${where} still exists and accepts POSTs.`;
+}
+
+/**
+ * Check every warehouse sink's access without writing, and send every
+ * alert sink a test notification that is never queued, one sink at a
+ * time. Results follow the manifest's order.
+ */
+export async function testSinks(
+  configPath: string,
+  type?: string,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): Promise<readonly SinkTestResult[]> {
+  const warehouse = readWarehouseConfig(configPath);
+  const history = readHistoryConfig(configPath);
+  const client = createManifestDeliveryClient(configPath, { queue: false });
+  const results: SinkTestResult[] = [];
+
+  for (const sink of warehouse.sinks) {
+    if (type !== undefined && sink.type !== type) continue;
+    const result = { sink: sink.type, kind: 'warehouse' as const };
+    const target = warehouseTarget(sink);
+    try {
+      await createWarehouseSink(configPath, warehouse, sink, env).test();
+      results.push({ ...result, target, ok: true });
+    } catch (err) {
+      results.push({ ...result, target, ok: false, error: describeError(err) });
+    }
+  }
+  for (const sink of history.sinks) {
+    if (type !== undefined && sink.type !== type) continue;
+    const result = { sink: sink.type, kind: 'alert' as const };
+    const target = alertTarget(sink);
+    let created;
+    try {
+      created = createAlertSink(configPath, sink, env, client);
+    } catch (err) {
+      results.push({ ...result, target, ok: false, error: describeError(err) });
+      continue;
+    }
+    try {
+      await created.notify(TEST_MESSAGE);
+      results.push({ ...result, target, ok: true });
+    } catch (err) {
+      results.push({
+        ...result,
+        target,
+        ok: false,
+        error: describeError(err),
+        fix: alertFix(sink),
+      });
+    }
+  }
+  return results;
+}
+
+export function formatSinkTests(results: readonly SinkTestResult[]): string {
+  const width = Math.max(...results.map((result) => result.sink.length));
+  const lines: string[] = [];
+  for (const result of results) {
+    const mark = result.ok ? chalk.green('✓') : chalk.red('✗');
+    lines.push(`${mark} ${result.sink.padEnd(width)}  ${result.target}`);
+    if (result.error) lines.push(`    ${chalk.red(result.error)}`);
+    if (result.fix) lines.push(chalk.dim(`    Fix: ${result.fix}`));
+  }
+  const failed = results.filter((result) => !result.ok).length;
+  lines.push(
+    '',
+    failed > 0
+      ? chalk.red(`${failed} of ${results.length} sink(s) failed`)
+      : chalk.green(`All ${results.length} sink(s) reachable`),
+  );
+  return lines.join('\n');
+}
+
+async function runSinkTest(
+  type: string | undefined,
+  options: SinkTestCommandOptions,
+): Promise<void> {
+  let results: readonly SinkTestResult[];
+  try {
+    results = await testSinks(resolve(options.config), type);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+  if (results.length === 0) {
+    reportError(
+      type ? `No ${type} sink is configured` : 'No sinks are configured',
+      'usage',
+      'Add sinks under warehouse.sinks or history.sinks in .knowgraph.yml.',
+    );
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(results, true));
+  } else {
+    console.log(formatSinkTests(results));
+  }
+  const failed = results.filter((result) => !result.ok);
+  if (failed.length > 0) {
+    reportCheckFailure(`${failed.length} sink(s) failed`, 'io', {
+      failed: failed.map((result) => result.sink),
+    });
+  }
+}
+
+export function registerSinkCommand(program: Command): void {
+  const sink = program
+    .command('sink')
+    .description('Work with the warehouse and alert sinks in .knowgraph.yml');
+
+  sink
+    .command('test [type]')
+    .description(
+      'Check the credentials and connectivity of every configured sink, or those of one type, without scanning',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action(
+      async (type: string | undefined, options: SinkTestCommandOptions) => {
+        await runSinkTest(type, options);
+      },
+    );
+}
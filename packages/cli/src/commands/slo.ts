@@ -0,0 +1,119 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that reports SLO-bearing entities by owner with optional live SLI joins
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, slo, reliability]
+ * context:
+ *   business_goal: Let teams review their SLO targets and remaining error budget from the terminal
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  buildSloReport,
+  createPrometheusClient,
+  joinSliData,
+} from '@know-graph/core';
+import type { SloEntry, SloReport } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface SloCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly prometheus?: string;
+  readonly prometheusTokenEnv?: string;
+}
+
+function formatTarget(entry: SloEntry): string {
+  const parts: string[] = [];
+  if (entry.slo.availability !== undefined) {
+    parts.push(`${entry.slo.availability}%`);
+  }
+  if (entry.slo.latency_ms !== undefined) {
+    const percentile = entry.slo.latency_percentile ?? 99;
+    parts.push(`p${percentile} < ${entry.slo.latency_ms}ms`);
+  }
+  return parts.length > 0 ? parts.join(', ') : '-';
+}
+
+function formatBudget(entry: SloEntry): string {
+  if (!entry.sli) return '';
+  if (entry.sli.error) return chalk.yellow(` (SLI: ${entry.sli.error})`);
+
+  const actual = `actual ${entry.sli.availability}%`;
+  const budget = entry.sli.errorBudgetRemaining;
+  if (budget === undefined) return chalk.dim(` (${actual})`);
+
+  const color = budget < 0 ? chalk.red : budget < 25 ? chalk.yellow : chalk.green;
+  return ` (${actual}, ${color(`${budget}% budget left`)})`;
+}
+
+export function formatSloReport(report: SloReport): string {
+  if (report.totalEntries === 0) {
+    return 'No entities declare an slo.';
+  }
+
+  const lines: string[] = [chalk.bold('SLO Report')];
+  for (const group of report.groups) {
+    lines.push('');
+    lines.push(chalk.bold(group.owner));
+    for (const entry of group.entries) {
+      lines.push(
+        `  ${entry.name} (${entry.entityType}) ${chalk.cyan(formatTarget(entry))}${formatBudget(entry)}`,
+      );
+    }
+  }
+  return lines.join('\n');
+}
+
+async function runSlo(options: SloCommandOptions): Promise<void> {
+  const entities = loadEntities(resolve(options.db));
+  if (!entities) return;
+
+  try {
+    let report = buildSloReport(entities);
+
+    if (options.prometheus) {
+      const bearerToken = options.prometheusTokenEnv
+        ? process.env[options.prometheusTokenEnv]
+        : undefined;
+      const client = createPrometheusClient({
+        baseUrl: options.prometheus,
+        bearerToken,
+      });
+      report = await joinSliData(report, client);
+    }
+
+    if (options.format === 'json') {
+      console.log(formatJson(report, true));
+    } else {
+      console.log(formatSloReport(report));
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerSloCommand(program: Command): void {
+  program
+    .command('slo')
+    .description('Report SLO-bearing entities grouped by owner')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (table|json)', 'table')
+    .option(
+      '--prometheus <url>',
+      'Prometheus base URL used to evaluate each sli_query',
+    )
+    .option(
+      '--prometheus-token-env <name>',
+      'Environment variable holding a Prometheus bearer token',
+    )
+    .action(async (options: SloCommandOptions) => {
+      await runSlo(options);
+    });
+}
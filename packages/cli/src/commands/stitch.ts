@@ -0,0 +1,251 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that merges exported graphs, resolves external stubs across them, and checks the merge for name and owner conflicts
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, stitch, graph, federation]
+ * context:
+ *   business_goal: Connect graphs from separate repositories so cross-repo dependencies are traversable instead of dangling
+ *   domain: cli
+ */
+import { readFileSync, writeFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  createKnowgraphError,
+  decodeGraphSnapshot,
+  filterNamespaces,
+  isGraphSnapshot,
+  parseServiceRegistry,
+  stitchGraphs,
+  withProvenance,
+  writeGraphSnapshot,
+} from '@know-graph/core';
+import type {
+  ConflictLocation,
+  DependencyGraph,
+  ServiceRegistry,
+  StitchResult,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import {
+  readGraphConstraints,
+  readServiceRegistryPath,
+} from '../utils/manifest.js';
+import { parseNamespacePatterns } from '../utils/namespace.js';
+
+interface StitchCommandOptions {
+  readonly output: string;
+  readonly format: string;
+  readonly check?: boolean;
+  readonly namespace?: string;
+  readonly registry?: string;
+}
+
+function isGraph(value: unknown): value is DependencyGraph {
+  if (typeof value !== 'object' || value === null) return false;
+  const graph = value as Record<string, unknown>;
+  return Array.isArray(graph.nodes) && Array.isArray(graph.edges);
+}
+
+/** Read a graph written by `export --format json` or `--format snapshot`. */
+export function readGraphFile(path: string): DependencyGraph {
+  const bytes = readFileSync(path);
+  if (isGraphSnapshot(bytes)) return decodeGraphSnapshot(bytes);
+  const parsed: unknown = JSON.parse(bytes.toString('utf-8'));
+  if (!isGraph(parsed)) {
+    throw createKnowgraphError(
+      'schema',
+      `${path} is not a graph export: expected "nodes" and "edges" arrays`,
+    );
+  }
+  // Exports from before edges recorded provenance get the default for kind
+  return { nodes: parsed.nodes, edges: parsed.edges.map(withProvenance) };
+}
+
+/** Write `graph` as JSON, or as a snapshot when `path` ends in `.kgs`. */
+export function writeGraphFile(path: string, graph: DependencyGraph): void {
+  if (path.endsWith('.kgs')) {
+    writeGraphSnapshot(path, graph);
+  } else {
+    writeFileSync(path, formatJson(graph, true), 'utf-8');
+  }
+}
+
+/**
+ * The service registry at `path`, or at the manifest's `service_registry`
+ * when no path is given; undefined when neither names one.
+ */
+function readServiceRegistry(
+  path: string | undefined,
+  configPath: string,
+): ServiceRegistry | undefined {
+  const registryPath = path
+    ? resolve(path)
+    : readServiceRegistryPath(configPath);
+  if (!registryPath) return undefined;
+  return parseServiceRegistry(
+    readFileSync(registryPath, 'utf-8'),
+    registryPath,
+  );
+}
+
+/**
+ * `result` cut down to the namespaces matching `patterns`, with only the
+ * resolutions and unresolved stubs whose nodes are still in the graph.
+ * Stubs are resolved across every graph first, so dependencies between
+ * namespaces stay joined.
+ */
+export function scopeStitchResult(
+  result: StitchResult,
+  patterns: readonly string[],
+): StitchResult {
+  const graph = filterNamespaces(result.graph, patterns);
+  const ids = new Set(graph.nodes.map((node) => node.id));
+  return {
+    graph,
+    resolved: result.resolved.filter((stub) => ids.has(stub.target)),
+    unresolved: result.unresolved.filter((stub) => ids.has(stub.node.id)),
+    conflicts: result.conflicts.filter((conflict) =>
+      conflict.locations.some((location) => ids.has(location.node)),
+    ),
+  };
+}
+
+function formatLocation(
+  location: ConflictLocation,
+  sources: readonly string[],
+): string {
+  const source = sources[location.graph] ?? `graph ${location.graph + 1}`;
+  const namespace = location.namespace ? `${location.namespace} ` : '';
+  const owner = location.owner ? chalk.dim(` (${location.owner})`) : '';
+  return `    ${source}: ${namespace}${location.filePath ?? location.node}${owner}`;
+}
+
+/**
+ * The stitch summary, unresolved stubs, and constraint conflicts, with
+ * each conflicting copy located by the file in `sources` its graph came
+ * from.
+ */
+export function formatStitchReport(
+  result: StitchResult,
+  sources: readonly string[] = [],
+): string {
+  const { graph, resolved, unresolved, conflicts } = result;
+  const names = new Map(graph.nodes.map((node) => [node.id, node.name]));
+  const lines = [
+    `${graph.nodes.length} nodes and ${graph.edges.length} edges; resolved ${resolved.length} stubs`,
+  ];
+  if (unresolved.length === 0) {
+    lines.push(chalk.green('Every external stub resolved.'));
+  } else {
+    lines.push(chalk.yellow(`${unresolved.length} unresolved stubs:`));
+    for (const stub of unresolved) {
+      const dependents = stub.dependents.map((id) => names.get(id) ?? id);
+      const suggestion = stub.suggestion
+        ? chalk.yellow(` (did you mean "${stub.suggestion}"?)`)
+        : '';
+      lines.push(
+        `  ${stub.node.name} ${chalk.dim(`(${stub.kinds.join(', ')})`)} <- ${dependents.join(', ')}${suggestion}`,
+      );
+    }
+  }
+  if (conflicts.length > 0) {
+    lines.push(chalk.red(`${conflicts.length} constraint conflicts:`));
+    for (const conflict of conflicts) {
+      lines.push(`  ${conflict.message} ${chalk.dim(`[${conflict.rule}]`)}`);
+      for (const location of conflict.locations) {
+        lines.push(formatLocation(location, sources));
+      }
+    }
+  }
+  return lines.join('\n');
+}
+
+function runStitch(
+  paths: readonly string[],
+  options: StitchCommandOptions,
+): void {
+  let result: StitchResult;
+  try {
+    const patterns =
+      options.namespace !== undefined
+        ? parseNamespacePatterns(options.namespace)
+        : undefined;
+    const configPath = resolve('.knowgraph.yml');
+    const stitched = stitchGraphs(
+      paths.map((path) => readGraphFile(resolve(path))),
+      {
+        constraints: readGraphConstraints(configPath),
+        registry: readServiceRegistry(options.registry, configPath),
+      },
+    );
+    result = patterns ? scopeStitchResult(stitched, patterns) : stitched;
+    writeGraphFile(resolve(options.output), result.graph);
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(
+      formatJson(
+        {
+          resolved: result.resolved,
+          unresolved: result.unresolved,
+          conflicts: result.conflicts,
+        },
+        true,
+      ),
+    );
+  } else {
+    console.log(formatStitchReport(result, paths));
+    console.log(chalk.dim(`Wrote ${options.output}`));
+  }
+
+  if (options.check && result.unresolved.length > 0) {
+    reportCheckFailure(
+      `${result.unresolved.length} external stubs are unresolved`,
+      'policy',
+      { stubs: result.unresolved.map((stub) => stub.node.id) },
+    );
+  } else if (options.check && result.conflicts.length > 0) {
+    reportCheckFailure(
+      `${result.conflicts.length} graph constraints are broken`,
+      'policy',
+      { conflicts: result.conflicts },
+    );
+  }
+}
+
+export function registerStitchCommand(program: Command): void {
+  program
+    .command('stitch <graphs...>')
+    .description(
+      'Merge exported graphs and resolve external stubs by registry, name, or alias',
+    )
+    .option(
+      '--output <file>',
+      'Merged graph file (.kgs for a snapshot)',
+      'knowgraph-stitched.json',
+    )
+    .option('--format <format>', 'Report format (text|json)', 'text')
+    .option(
+      '--check',
+      'Exit with code 1 if any external stub is unresolved or constraint broken',
+    )
+    .option(
+      '--namespace <list>',
+      'Write only these namespaces or prefixes (e.g. acme/payments,acme/search)',
+    )
+    .option(
+      '--registry <file>',
+      'Service registry mapping service names to repositories (default: service_registry in .knowgraph.yml)',
+    )
+    .action((paths: string[], options: StitchCommandOptions) => {
+      runStitch(paths, options);
+    });
+}
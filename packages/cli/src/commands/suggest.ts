@@ -15,6 +15,8 @@ import type { Command } from 'commander';
 import chalk from 'chalk';
 import { createDefaultRegistry, createSuggestionEngine } from '@know-graph/core';
 import type { SuggestionResult } from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
 
 interface SuggestOptions {
   readonly limit: string;
@@ -50,7 +52,7 @@ function formatTextOutput(result: SuggestionResult): string {
 }
 
 function formatJsonOutput(result: SuggestionResult): string {
-  return JSON.stringify(result, null, 2);
+  return formatJson(result, true);
 }
 
 function runSuggest(targetPath: string, options: SuggestOptions): void {
@@ -59,8 +61,7 @@ function runSuggest(targetPath: string, options: SuggestOptions): void {
   try {
     statSync(absPath);
   } catch {
-    console.error(chalk.red(`Error: Path not found: ${absPath}`));
-    process.exitCode = 1;
+    reportError(`Path not found: ${absPath}`, 'io');
     return;
   }
 
@@ -70,8 +71,7 @@ function runSuggest(targetPath: string, options: SuggestOptions): void {
     const limit = parseInt(options.limit, 10);
 
     if (Number.isNaN(limit) || limit < 1) {
-      console.error(chalk.red('Error: --limit must be a positive integer'));
-      process.exitCode = 1;
+      reportError('--limit must be a positive integer', 'usage');
       return;
     }
 
@@ -83,12 +83,7 @@ function runSuggest(targetPath: string, options: SuggestOptions): void {
       console.log(formatTextOutput(result));
     }
   } catch (err) {
-    console.error(
-      chalk.red(
-        `Error: ${err instanceof Error ? err.message : String(err)}`,
-      ),
-    );
-    process.exitCode = 1;
+    reportError(err);
   }
 }
 
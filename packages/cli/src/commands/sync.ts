@@ -17,13 +17,17 @@ import ora from 'ora';
 import { parse as parseYaml } from 'yaml';
 import {
   createDefaultConnectorRegistry,
-  createDatabaseManager,
   ManifestSchema,
 } from '@know-graph/core';
 import type {
+  AuditChange,
   ConnectorConfig,
   ConnectorSyncResult,
 } from '@know-graph/core';
+import { recordAudit } from '../utils/audit.js';
+import { reportError } from '../utils/errors.js';
+import { openDatabase } from '../utils/db.js';
+import { formatPlan } from '../utils/plan.js';
 
 interface SyncCommandOptions {
   readonly dryRun?: boolean;
@@ -68,6 +72,17 @@ export function formatResult(
   return lines.join('\n');
 }
 
+function syncChanges(
+  results: readonly ConnectorSyncResult[],
+): readonly AuditChange[] {
+  return results.map((result) => ({
+    target: `connector:${result.connector}`,
+    summary:
+      `${result.linksAdded} links added, ${result.linksUpdated} updated, ` +
+      `${result.errors.length} error(s)`,
+  }));
+}
+
 async function runSync(
   connectorNames: readonly string[],
   options: SyncCommandOptions,
@@ -75,21 +90,17 @@ async function runSync(
   const configPath = resolve(options.config ?? '.knowgraph.yml');
 
   if (!existsSync(configPath)) {
-    console.error(
-      chalk.red(
-        `Error: Config not found: ${configPath}\nRun ${chalk.cyan('knowgraph init')} to create one.`,
-      ),
+    reportError(
+      `Config not found: ${configPath}`,
+      'io',
+      "Run 'knowgraph init' to create one.",
     );
-    process.exitCode = 1;
     return;
   }
 
   const manifestResult = loadManifest(configPath);
   if (!manifestResult.success) {
-    console.error(
-      chalk.red(`Error: Invalid config: ${manifestResult.error.message}`),
-    );
-    process.exitCode = 1;
+    reportError(`Invalid config: ${manifestResult.error.message}`, 'schema');
     return;
   }
 
@@ -129,22 +140,23 @@ async function runSync(
   const dbPath = join(outputDir, 'knowgraph.db');
 
   if (!existsSync(dbPath)) {
-    console.error(
-      chalk.red(
-        `Error: Database not found at ${dbPath}\nRun ${chalk.cyan('knowgraph index')} first.`,
-      ),
+    reportError(
+      `Database not found at ${dbPath}`,
+      'io',
+      "Run 'knowgraph index' first.",
     );
-    process.exitCode = 1;
     return;
   }
 
-  const dbManager = createDatabaseManager(dbPath);
+  const dbManager = openDatabase(dbPath, configPath);
   dbManager.initialize();
 
   const spinner = ora(
     options.dryRun ? 'Previewing sync (dry run)...' : 'Syncing connectors...',
   ).start();
 
+  let results: readonly ConnectorSyncResult[] = [];
+
   try {
     const registry = createDefaultConnectorRegistry();
 
@@ -169,7 +181,7 @@ async function runSync(
           }
         : undefined;
 
-    const results = await registry.syncAll({
+    results = await registry.syncAll({
       dbManager,
       configs,
       entityFilter,
@@ -182,7 +194,6 @@ async function runSync(
     spinner.stop();
 
     // Print results
-    const dryRunLabel = options.dryRun ? chalk.dim(' [dry run]') : '';
     console.log('');
     for (const result of results) {
       console.log(formatResult(result, options.verbose ?? false));
@@ -200,28 +211,43 @@ async function runSync(
     );
 
     console.log('');
-    if (totalErrors > 0) {
+    if (options.dryRun) {
+      console.log(
+        formatPlan({
+          command: 'sync',
+          changes: syncChanges(
+            results.filter((r) => r.linksAdded + r.linksUpdated > 0),
+          ),
+          totals: [
+            `${totalAdded} link(s) added`,
+            `${totalUpdated} updated`,
+            `${totalErrors} error(s)`,
+          ],
+        }),
+      );
+    } else if (totalErrors > 0) {
       console.log(
         chalk.yellow(
-          `Sync complete${dryRunLabel}: ${totalAdded} new links, ${totalUpdated} updated links, ${totalErrors} error(s)`,
+          `Sync complete: ${totalAdded} new links, ${totalUpdated} updated links, ${totalErrors} error(s)`,
         ),
       );
     } else {
       console.log(
         chalk.green(
-          `\u2714 Sync complete!${dryRunLabel} ${totalAdded} new links, ${totalUpdated} updated links`,
+          `\u2714 Sync complete! ${totalAdded} new links, ${totalUpdated} updated links`,
         ),
       );
     }
   } catch (err) {
     spinner.fail(chalk.red('Sync failed'));
-    console.error(
-      chalk.red(err instanceof Error ? err.message : String(err)),
-    );
-    process.exitCode = 1;
+    reportError(err);
   } finally {
     dbManager.close();
   }
+
+  if (!options.dryRun) {
+    recordAudit(configPath, 'sync', syncChanges(results));
+  }
 }
 
 export function registerSyncCommand(program: Command): void {
@@ -230,7 +256,7 @@ export function registerSyncCommand(program: Command): void {
     .description(
       'Sync external knowledge connectors (e.g. Notion, Jira) with the code graph',
     )
-    .option('--dry-run', 'Preview changes without writing to database')
+    .option('--dry-run', 'Show the links sync would write without writing them')
     .option(
       '--owner <owner>',
       'Only sync entities owned by this team/person',
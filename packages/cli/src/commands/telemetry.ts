@@ -0,0 +1,143 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that opts in to or out of anonymous usage counts and previews exactly what would be sent
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, usage, telemetry, privacy]
+ * context:
+ *   business_goal: Let maintainers prioritize features with data while never collecting code or project content
+ *   domain: cli
+ */
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  buildUsageReport,
+  isUsageDisabledByEnv,
+  readUsageState,
+  writeUsageState,
+} from '@know-graph/core';
+import type { UsageState } from '@know-graph/core';
+import { reportError } from '../utils/errors.js';
+import {
+  usageEndpoint,
+  usageEnvironment,
+  usageStatePath,
+} from '../utils/usage.js';
+
+type Env = Readonly<Record<string, string | undefined>>;
+
+/** One line per fact about the stored choice and where reports go. */
+export function formatUsageStatus(
+  state: UsageState,
+  path: string,
+  env: Env = process.env,
+): string {
+  const overridden = isUsageDisabledByEnv(env);
+  const status = overridden
+    ? chalk.yellow('disabled by DO_NOT_TRACK or KNOWGRAPH_TELEMETRY')
+    : state.enabled
+      ? chalk.green('enabled')
+      : 'disabled';
+  const runs = Object.values(state.commands).reduce(
+    (total, usage) => total + usage.runs,
+    0,
+  );
+  return [
+    `Usage reporting: ${status}`,
+    `  Settings:  ${path}`,
+    `  Endpoint:  ${usageEndpoint(state, env) ?? 'none (nothing is sent)'}`,
+    `  Pending:   ${runs} run(s) since ${state.since}`,
+    `  Last sent: ${state.lastSentAt ?? 'never'}`,
+  ].join('\n');
+}
+
+function isHttpUrl(value: string): boolean {
+  try {
+    return ['http:', 'https:'].includes(new URL(value).protocol);
+  } catch {
+    return false;
+  }
+}
+
+function setEnabled(enabled: boolean, endpoint?: string): void {
+  const path = usageStatePath();
+  const state = readUsageState(path);
+  if (enabled) {
+    const chosen = endpoint ?? usageEndpoint(state);
+    if (chosen === undefined) {
+      reportError(
+        'Usage reports need an endpoint to be posted to',
+        'usage',
+        'Pass --endpoint <url>, or set KNOWGRAPH_USAGE_ENDPOINT',
+      );
+      return;
+    }
+    if (!isHttpUrl(chosen)) {
+      reportError(`Invalid endpoint '${chosen}': use an http(s) URL`, 'usage');
+      return;
+    }
+    writeUsageState(path, { ...state, enabled, endpoint: chosen });
+  } else {
+    // Opting out also forgets what was counted but not yet sent
+    writeUsageState(path, { ...state, enabled, commands: {} });
+  }
+  console.log(formatUsageStatus(readUsageState(path), path));
+  if (enabled) {
+    console.log('');
+    console.log(
+      chalk.dim(
+        "Only command names, failure kinds, and the runtime are sent. Run 'knowgraph telemetry preview' to see the next report.",
+      ),
+    );
+  }
+}
+
+function runPreview(version: string): void {
+  const state = readUsageState(usageStatePath());
+  // The JSON `sendUsageReport` posts, only indented
+  const report = buildUsageReport(state, usageEnvironment(version));
+  console.log(JSON.stringify(report, null, 2));
+  if (!state.enabled || isUsageDisabledByEnv()) {
+    console.error(
+      chalk.dim(
+        "Usage reporting is off, so nothing is sent. Run 'knowgraph telemetry enable' to opt in.",
+      ),
+    );
+  }
+}
+
+export function registerTelemetryCommand(program: Command): void {
+  const telemetryCmd = program
+    .command('telemetry')
+    .description(
+      'Opt in to or out of anonymous usage counts sent to an endpoint you choose',
+    );
+
+  telemetryCmd
+    .command('status')
+    .description('Show whether usage reporting is on and what is pending')
+    .action(() => {
+      const path = usageStatePath();
+      console.log(formatUsageStatus(readUsageState(path), path));
+    });
+
+  telemetryCmd
+    .command('enable')
+    .description('Count command runs and send them at most once a day')
+    .option(
+      '--endpoint <url>',
+      'URL to post reports to (default: KNOWGRAPH_USAGE_ENDPOINT, or the one chosen before)',
+    )
+    .action((opts: { endpoint?: string }) => setEnabled(true, opts.endpoint));
+
+  telemetryCmd
+    .command('disable')
+    .description('Stop counting and drop counts not yet sent')
+    .action(() => setEnabled(false));
+
+  telemetryCmd
+    .command('preview')
+    .description('Print exactly the report that would be sent next')
+    .action(() => runPreview(program.version() ?? 'unknown'));
+}
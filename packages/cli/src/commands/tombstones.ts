@@ -0,0 +1,91 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists the nodes recent scans removed, with when, why, and who owned them, from the index's tombstones
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, tombstones, deletions, sync]
+ * context:
+ *   business_goal: Let registries and catalogs that mirror the graph retire deleted nodes on purpose instead of losing them silently
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import type { Tombstone } from '@know-graph/core';
+import { openDatabase } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportError } from '../utils/errors.js';
+
+interface TombstonesCommandOptions {
+  readonly db: string;
+  readonly since?: string;
+  readonly format: string;
+}
+
+export function formatTombstones(tombstones: readonly Tombstone[]): string {
+  if (tombstones.length === 0) {
+    return chalk.dim('No removed nodes within the retention window.');
+  }
+  const lines = tombstones.map((tombstone) => {
+    const where = [tombstone.filePath, tombstone.owner]
+      .filter(Boolean)
+      .join(', ');
+    return (
+      `  ${chalk.dim(tombstone.removedAt)}  ${chalk.red(tombstone.name)} ` +
+      chalk.dim(`(${tombstone.entityType}) ${tombstone.reason}  ${where}`)
+    );
+  });
+  return [
+    `${chalk.bold('Removed nodes')} ${chalk.dim(`${tombstones.length}`)}`,
+    ...lines,
+  ].join('\n');
+}
+
+function runTombstones(options: TombstonesCommandOptions): void {
+  const since =
+    options.since === undefined ? undefined : new Date(options.since);
+  if (since !== undefined && Number.isNaN(since.getTime())) {
+    reportError('--since must be a date, such as 2025-01-31', 'usage');
+    return;
+  }
+  const dbPath = resolve(options.db);
+  if (!existsSync(dbPath)) {
+    reportError(
+      `Database not found at ${dbPath}`,
+      'io',
+      "Run 'knowgraph index' first to create the database.",
+    );
+    return;
+  }
+
+  const dbManager = openDatabase(dbPath, undefined, { readOnly: true });
+  let tombstones: readonly Tombstone[];
+  try {
+    tombstones = dbManager.getTombstones(since?.toISOString());
+  } catch (err) {
+    reportError(err);
+    return;
+  } finally {
+    dbManager.close();
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(tombstones, true));
+  } else {
+    console.log(formatTombstones(tombstones));
+  }
+}
+
+export function registerTombstonesCommand(program: Command): void {
+  program
+    .command('tombstones')
+    .description('List nodes recent scans removed, oldest first')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--since <date>', 'Only nodes removed at or after this date')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((options: TombstonesCommandOptions) => {
+      runTombstones(options);
+    });
+}
@@ -0,0 +1,223 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that reports owners gone from the GitHub org or Okta, with the nodes they leave and suggested inheritors, as text, JSON, or an edit CSV
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, vacancy, ownership, github, okta]
+ * context:
+ *   business_goal: Hand code whose owner left to the people still working on it before an incident finds no one to page
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  buildVacancyReport,
+  fetchDirectory,
+  formatVacancyCsv,
+  mergeDirectories,
+  parseDirectory,
+} from '@know-graph/core';
+import type {
+  OrgDirectory,
+  OwnerVacancy,
+  VacancyReport,
+} from '@know-graph/core';
+import { loadEntities } from '../utils/db.js';
+import { formatJson } from '../utils/format.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { readOwnershipConfig } from '../utils/manifest.js';
+
+const GITHUB_TOKEN_ENV = 'GITHUB_TOKEN';
+const OKTA_TOKEN_ENV = 'OKTA_API_TOKEN';
+
+interface VacanciesCommandOptions {
+  readonly config: string;
+  readonly db: string;
+  readonly githubOrg?: string;
+  readonly githubUrl?: string;
+  readonly oktaUrl?: string;
+  readonly escalateTo?: string;
+  readonly format: string;
+  readonly check?: boolean;
+}
+
+function vacancyLines(vacancy: OwnerVacancy): readonly string[] {
+  const lines = [
+    `  ${chalk.bold(vacancy.owner)} ${chalk.dim(`(${vacancy.reason}, ${vacancy.nodes.length} nodes)`)}`,
+  ];
+  if (vacancy.inheritors.length > 0) {
+    const inheritors = vacancy.inheritors.map(
+      (inheritor) =>
+        `${inheritor.login ?? inheritor.author} ${inheritor.commits} commits`,
+    );
+    lines.push(`    inheritors: ${inheritors.join(', ')}`);
+  }
+  for (const node of vacancy.nodes) {
+    const next = node.suggested
+      ? `→ ${node.suggested} (${node.suggestedFrom})`
+      : chalk.yellow('→ no one to suggest');
+    lines.push(`    ${node.name} ${chalk.dim(node.filePath)} ${next}`);
+  }
+  return lines;
+}
+
+export function formatVacancyReport(report: VacancyReport): string {
+  const lines =
+    report.vacancies.length === 0
+      ? [chalk.green('Every owner is in the directory.')]
+      : [
+          chalk.red(
+            `${report.vacancies.length} owners no longer exist, leaving ${report.vacantNodes} nodes unowned`,
+          ),
+          ...report.vacancies.flatMap(vacancyLines),
+        ];
+  if (report.unverified.length > 0) {
+    lines.push(
+      '',
+      chalk.dim(
+        `${report.unverified.length} owners could not be checked, as the directory lacks teams or people: ${report.unverified.join(', ')}`,
+      ),
+    );
+  }
+  return lines.join('\n');
+}
+
+/** The token in `name`; undefined after reporting it unset. */
+function readToken(name: string, source: string): string | undefined {
+  const token = process.env[name];
+  if (!token) {
+    reportError(
+      `Environment variable ${name} is not set`,
+      'usage',
+      `Set ${name} to a ${source} API token, or pass saved responses as files.`,
+    );
+  }
+  return token;
+}
+
+/** The directory from saved responses or the APIs; undefined on error. */
+async function readDirectory(
+  files: readonly string[],
+  githubOrg: string | undefined,
+  oktaUrl: string | undefined,
+  githubUrl: string | undefined,
+): Promise<OrgDirectory | undefined> {
+  if (files.length > 0) {
+    return mergeDirectories(
+      files.map((file) => parseDirectory(readFileSync(resolve(file), 'utf-8'))),
+    );
+  }
+  if (!githubOrg && !oktaUrl) {
+    reportError(
+      'No directory to check owners against',
+      'usage',
+      'Pass saved GitHub or Okta responses, or set --github-org or --okta-url.',
+    );
+    return undefined;
+  }
+  const directories: OrgDirectory[] = [];
+  if (githubOrg) {
+    const token = readToken(GITHUB_TOKEN_ENV, 'GitHub');
+    if (!token) return undefined;
+    directories.push(
+      await fetchDirectory('github', {
+        token,
+        org: githubOrg,
+        baseUrl: githubUrl,
+      }),
+    );
+  }
+  if (oktaUrl) {
+    const token = readToken(OKTA_TOKEN_ENV, 'Okta');
+    if (!token) return undefined;
+    directories.push(await fetchDirectory('okta', { token, baseUrl: oktaUrl }));
+  }
+  return mergeDirectories(directories);
+}
+
+async function runVacancies(
+  files: readonly string[],
+  options: VacanciesCommandOptions,
+): Promise<void> {
+  if (!['text', 'json', 'csv'].includes(options.format)) {
+    reportError(
+      `Unknown format "${options.format}". Use text, json, or csv.`,
+      'usage',
+    );
+    return;
+  }
+  const dbPath = resolve(options.db);
+  const configPath = resolve(options.config);
+  const entities = loadEntities(dbPath, configPath);
+  if (!entities) return;
+
+  const config = readOwnershipConfig(configPath);
+  let report: VacancyReport;
+  try {
+    const directory = await readDirectory(
+      files,
+      options.githubOrg ?? config.github_org,
+      options.oktaUrl ?? config.okta_url,
+      options.githubUrl,
+    );
+    if (!directory) return;
+    report = buildVacancyReport(entities, directory, {
+      maxInheritors: config.inheritors,
+      escalateTo: options.escalateTo ?? config.escalate_to,
+    });
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else if (options.format === 'csv') {
+    process.stdout.write(formatVacancyCsv(report));
+  } else {
+    console.log(formatVacancyReport(report));
+  }
+
+  if (options.check && report.vacancies.length > 0) {
+    reportCheckFailure(
+      `${report.vacancies.length} owners no longer exist`,
+      'policy',
+      { owners: report.vacancies.map((vacancy) => vacancy.owner) },
+    );
+  }
+}
+
+export function registerVacanciesCommand(program: Command): void {
+  program
+    .command('vacancies')
+    .description(
+      'Report owners gone from the org directory and who should inherit their nodes',
+    )
+    .argument(
+      '[files...]',
+      'Saved GitHub team or member lists or Okta user lists; fetches when none',
+    )
+    .option('--config <path>', 'Path to .knowgraph.yml', '.knowgraph.yml')
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option(
+      '--github-org <org>',
+      `GitHub organization to list teams and members of, with ${GITHUB_TOKEN_ENV}`,
+    )
+    .option('--github-url <url>', 'GitHub API base URL, for GitHub Enterprise')
+    .option(
+      '--okta-url <url>',
+      `Okta org URL to list users of, with ${OKTA_TOKEN_ENV}`,
+    )
+    .option(
+      '--escalate-to <owner>',
+      'Owner suggested for nodes no active contributor or module owns',
+    )
+    .option('--format <format>', 'Output format (text|json|csv)', 'text')
+    .option('--check', 'Exit with code 1 if an owner no longer exists')
+    .action(async (files: string[], options: VacanciesCommandOptions) => {
+      await runVacancies(files, options);
+    });
+}
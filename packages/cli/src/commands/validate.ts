@@ -9,12 +9,20 @@
  *   business_goal: Help developers find and fix annotation issues before committing
  *   domain: cli
  */
-import { resolve } from 'node:path';
+import { relative, resolve } from 'node:path';
 import { statSync } from 'node:fs';
 import type { Command } from 'commander';
 import chalk from 'chalk';
 import { createValidator } from '@know-graph/core';
 import type { ValidationIssue, ValidationResult } from '@know-graph/core';
+import { formatJson, formatSeverity } from '../utils/format.js';
+import { readRuleSeverities } from '../utils/manifest.js';
+import { reportError, reportCheckFailure } from '../utils/errors.js';
+import {
+  formatCodeQualityReport,
+  formatJUnitReport,
+} from '../utils/ci-reports.js';
+import type { ReportFinding } from '../utils/ci-reports.js';
 
 interface ValidateCommandOptions {
   readonly strict?: boolean;
@@ -23,10 +31,7 @@ interface ValidateCommandOptions {
 }
 
 function formatIssueText(issue: ValidationIssue): string {
-  const severity =
-    issue.severity === 'error'
-      ? chalk.red('[ERROR]')
-      : chalk.yellow('[WARN]');
+  const severity = formatSeverity(issue.severity);
   const location = chalk.cyan(`${issue.filePath}:${issue.line}`);
   const ruleName = chalk.dim(issue.rule);
   return `${location} ${severity} ${ruleName}: ${issue.message}`;
@@ -36,6 +41,14 @@ function printTextOutput(result: ValidationResult, strict: boolean): void {
   for (const issue of result.issues) {
     console.log(formatIssueText(issue));
   }
+  for (const { issue, reason } of result.suppressed) {
+    const location = `${issue.filePath}:${issue.line}`;
+    console.log(
+      chalk.dim(
+        `${location} [SUPPRESSED] ${issue.rule}: ${reason ?? 'no reason given'}`,
+      ),
+    );
+  }
 
   console.log('');
 
@@ -43,16 +56,36 @@ function printTextOutput(result: ValidationResult, strict: boolean): void {
     ? result.errorCount > 0 || result.warningCount > 0
     : result.errorCount > 0;
 
+  const extra = [
+    ...(result.infoCount > 0 ? [`${result.infoCount} info`] : []),
+    ...(result.suppressed.length > 0
+      ? [`${result.suppressed.length} suppressed`]
+      : []),
+  ];
+  const suffix = extra.length > 0 ? ` (${extra.join(', ')})` : '';
   const summaryColor = isFailure ? chalk.red : chalk.green;
   console.log(
     summaryColor(
-      `${result.errorCount} error(s), ${result.warningCount} warning(s) in ${result.fileCount} file(s)`,
+      `${result.errorCount} error(s), ${result.warningCount} warning(s) in ${result.fileCount} file(s)${suffix}`,
     ),
   );
 }
 
 function printJsonOutput(result: ValidationResult): void {
-  console.log(JSON.stringify(result, null, 2));
+  console.log(formatJson(result, true));
+}
+
+function toReportFindings(
+  result: ValidationResult,
+  cwd = process.cwd(),
+): readonly ReportFinding[] {
+  return result.issues.map((issue) => ({
+    severity: issue.severity,
+    filePath: relative(cwd, issue.filePath),
+    line: issue.line,
+    rule: issue.rule,
+    message: issue.message,
+  }));
 }
 
 function runValidate(
@@ -64,8 +97,7 @@ function runValidate(
   try {
     statSync(absPath);
   } catch {
-    console.error(chalk.red(`Error: Path not found: ${absPath}`));
-    process.exitCode = 1;
+    reportError(`Path not found: ${absPath}`, 'io');
     return;
   }
 
@@ -73,10 +105,20 @@ function runValidate(
     const validator = createValidator();
     const result = validator.validate(absPath, {
       ruleName: options.rule,
+      severities: readRuleSeverities(resolve('.knowgraph.yml')),
     });
 
     if (options.format === 'json') {
       printJsonOutput(result);
+    } else if (options.format === 'gitlab-codequality') {
+      console.log(formatCodeQualityReport(toReportFindings(result)));
+    } else if (options.format === 'junit') {
+      console.log(
+        formatJUnitReport(toReportFindings(result), {
+          name: 'knowgraph validate',
+          strict: options.strict,
+        }),
+      );
     } else {
       printTextOutput(result, options.strict ?? false);
     }
@@ -85,15 +127,14 @@ function runValidate(
     const hasWarnings = result.warningCount > 0;
 
     if (hasErrors || (options.strict && hasWarnings)) {
-      process.exitCode = 1;
+      reportCheckFailure(
+        `${result.errorCount} error(s) and ${result.warningCount} warning(s)`,
+        'schema',
+        { errors: result.errorCount, warnings: result.warningCount },
+      );
     }
   } catch (err) {
-    console.error(
-      chalk.red(
-        `Validation failed: ${err instanceof Error ? err.message : String(err)}`,
-      ),
-    );
-    process.exitCode = 1;
+    reportError(err);
   }
 }
 
@@ -102,7 +143,11 @@ export function registerValidateCommand(program: Command): void {
     .command('validate [path]')
     .description('Validate @knowgraph annotations for correctness')
     .option('--strict', 'Treat warnings as errors')
-    .option('--format <format>', 'Output format (text|json)', 'text')
+    .option(
+      '--format <format>',
+      'Output format (text|json|gitlab-codequality|junit)',
+      'text',
+    )
     .option('--rule <name>', 'Run only a specific validation rule')
     .action((path: string | undefined, options: ValidateCommandOptions) => {
       runValidate(path ?? '.', options);
@@ -0,0 +1,125 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that checks the API versions dependencies require against the versions their providers declare
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, versions, compatibility, stitch, policy]
+ * context:
+ *   business_goal: Catch a consumer that needs an API version its provider does not ship before it breaks in production
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import { checkDependencyVersions, stitchGraphs } from '@know-graph/core';
+import type {
+  DependencyGraph,
+  VersionReport,
+  VersionRequirement,
+  VersionStatus,
+} from '@know-graph/core';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportCheckFailure, reportError } from '../utils/errors.js';
+import { formatJson } from '../utils/format.js';
+import { readGraphFile } from './stitch.js';
+
+interface VersionsCommandOptions {
+  readonly db: string;
+  readonly format: string;
+}
+
+const STATUS_COLORS: Readonly<
+  Record<VersionStatus, (text: string) => string>
+> = {
+  incompatible: chalk.red,
+  invalid: chalk.red,
+  unknown: chalk.yellow,
+  compatible: chalk.green,
+};
+
+function requirementLine(requirement: VersionRequirement): string {
+  const { from, to, requires, version, status } = requirement;
+  const has = version === null ? 'declares no version' : `has ${version}`;
+  return (
+    `  ${STATUS_COLORS[status](status.padEnd(12))} ` +
+    `${chalk.cyan(from.name)} requires ${chalk.cyan(to.name)} ${requires}` +
+    chalk.dim(`, ${has}`)
+  );
+}
+
+export function formatVersionReport(report: VersionReport): string {
+  const { requirements } = report;
+  if (requirements.length === 0) {
+    return chalk.dim('No dependency declares a required version.');
+  }
+  const problems = report.incompatible + report.invalid;
+  const lines = [
+    problems === 0
+      ? chalk.green(
+          `All ${requirements.length} version requirement(s) that can be checked are met.`,
+        )
+      : chalk.bold(
+          `${problems} of ${requirements.length} version requirement(s) not met`,
+        ),
+    '',
+    ...requirements.map(requirementLine),
+  ];
+  return lines.join('\n');
+}
+
+function runVersions(
+  graphs: readonly string[],
+  options: VersionsCommandOptions,
+): void {
+  let graph: DependencyGraph;
+  try {
+    if (graphs.length > 0) {
+      // Stitched, so a requirement on another repository's service is
+      // checked against the version that repository declares
+      graph = stitchGraphs(
+        graphs.map((file) => readGraphFile(resolve(file))),
+      ).graph;
+    } else {
+      const dbPath = resolve(options.db);
+      const entities = loadEntities(dbPath);
+      if (!entities) return;
+      graph = buildGraph(dbPath, entities);
+    }
+  } catch (err) {
+    reportError(err);
+    return;
+  }
+
+  const report = checkDependencyVersions(graph);
+  if (options.format === 'json') {
+    console.log(formatJson(report, true));
+  } else {
+    console.log(formatVersionReport(report));
+  }
+  const problems = report.incompatible + report.invalid;
+  if (problems > 0) {
+    reportCheckFailure(
+      `${problems} dependency version requirement(s) not met`,
+      'policy',
+      { incompatible: report.incompatible, invalid: report.invalid },
+    );
+  }
+}
+
+export function registerVersionsCommand(program: Command): void {
+  program
+    .command('versions')
+    .description(
+      'Check the versions dependencies require against the versions their providers declare, across stitched graphs',
+    )
+    .argument(
+      '[graphs...]',
+      'Graph exports (.json or .kgs) to stitch and check instead of the database',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (text|json)', 'text')
+    .action((graphs: string[], options: VersionsCommandOptions) => {
+      runVersions(graphs, options);
+    });
+}
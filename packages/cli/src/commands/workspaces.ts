@@ -0,0 +1,160 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: CLI command that lists monorepo workspace members and the dependencies between them
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, command, workspace, monorepo]
+ * context:
+ *   business_goal: Show graph boundaries as the build units defined by pnpm, Go, and Bazel
+ *   domain: cli
+ */
+import { resolve } from 'node:path';
+import type { Command } from 'commander';
+import chalk from 'chalk';
+import {
+  buildWorkspaceReport,
+  createBazelQueryRunner,
+  discoverWorkspaceMembers,
+  queryBazelDependencies,
+  reconcileBazelEdges,
+} from '@know-graph/core';
+import type {
+  BazelEdge,
+  BazelReconciliation,
+  WorkspaceReport,
+} from '@know-graph/core';
+import { formatJson } from '../utils/format.js';
+import { buildGraph, loadEntities } from '../utils/db.js';
+import { reportError } from '../utils/errors.js';
+
+interface WorkspacesCommandOptions {
+  readonly db: string;
+  readonly format: string;
+  readonly bazelQuery?: boolean;
+  readonly bazel: string;
+  readonly bazelScope: string;
+}
+
+export function formatWorkspaceReport(report: WorkspaceReport): string {
+  if (report.members.length === 0) {
+    return 'No workspace members found (looked for pnpm-workspace.yaml, go.work, and Bazel BUILD files).';
+  }
+
+  const lines: string[] = [
+    chalk.bold(`Workspace members: ${report.members.length}`),
+  ];
+  for (const member of report.members) {
+    lines.push(
+      `  ${chalk.bold(member.name)} ` +
+        chalk.dim(`[${member.kind}] ${member.path}`) +
+        ` ${member.entityCount} entities`,
+    );
+    if (member.owners.length > 0) {
+      lines.push(chalk.dim(`    owners: ${member.owners.join(', ')}`));
+    }
+    if (member.dependsOn.length > 0) {
+      lines.push(`    depends on: ${member.dependsOn.join(', ')}`);
+    }
+  }
+
+  if (report.unassignedEntities > 0) {
+    lines.push('');
+    lines.push(
+      chalk.yellow(
+        `${report.unassignedEntities} entities are outside every workspace member`,
+      ),
+    );
+  }
+  return lines.join('\n');
+}
+
+export function formatBazelReconciliation(
+  reconciliation: BazelReconciliation,
+): string {
+  const format = (edge: BazelEdge): string =>
+    `    ${edge.from} -> ${edge.to}`;
+  const lines: string[] = [
+    chalk.bold('Bazel build dependencies'),
+    `  ${chalk.green(`${reconciliation.confirmed.length} confirmed`)} by annotations`,
+  ];
+  if (reconciliation.undeclared.length > 0) {
+    lines.push(
+      chalk.yellow(
+        `  ${reconciliation.undeclared.length} undeclared (build edge, no annotation):`,
+      ),
+    );
+    lines.push(...reconciliation.undeclared.map(format));
+  }
+  if (reconciliation.declaredOnly.length > 0) {
+    lines.push(
+      chalk.yellow(
+        `  ${reconciliation.declaredOnly.length} declared only (annotation, no build edge):`,
+      ),
+    );
+    lines.push(...reconciliation.declaredOnly.map(format));
+  }
+  return lines.join('\n');
+}
+
+async function runWorkspaces(
+  rootPath: string,
+  options: WorkspacesCommandOptions,
+): Promise<void> {
+  try {
+    const dbPath = resolve(options.db);
+    const entities = loadEntities(dbPath);
+    if (!entities) return;
+
+    const root = resolve(rootPath);
+    const members = discoverWorkspaceMembers(root);
+    const graph = buildGraph(dbPath, entities, { workspaces: members });
+    const report = buildWorkspaceReport(members, graph);
+
+    let bazel: BazelReconciliation | undefined;
+    if (options.bazelQuery) {
+      const runner = createBazelQueryRunner({
+        cwd: root,
+        bazelPath: options.bazel,
+      });
+      const buildEdges = await queryBazelDependencies(
+        runner,
+        options.bazelScope,
+      );
+      bazel = reconcileBazelEdges(graph, buildEdges);
+    }
+
+    if (options.format === 'json') {
+      console.log(formatJson(bazel ? { ...report, bazel } : report, true));
+    } else {
+      console.log(formatWorkspaceReport(report));
+      if (bazel) {
+        console.log('');
+        console.log(formatBazelReconciliation(bazel));
+      }
+    }
+  } catch (err) {
+    reportError(err);
+  }
+}
+
+export function registerWorkspacesCommand(program: Command): void {
+  program
+    .command('workspaces [path]')
+    .description(
+      'List workspace members (pnpm, go.work, Bazel) and their dependencies',
+    )
+    .option('--db <path>', 'Database path', '.knowgraph/knowgraph.db')
+    .option('--format <format>', 'Output format (table|json)', 'table')
+    .option(
+      '--bazel-query',
+      'Reconcile declared dependencies with bazel query build edges',
+    )
+    .option('--bazel <path>', 'Bazel executable', 'bazel')
+    .option('--bazel-scope <pattern>', 'Target pattern to query', '//...')
+    .action(
+      async (path: string | undefined, options: WorkspacesCommandOptions) => {
+        await runWorkspaces(path ?? '.', options);
+      },
+    );
+}
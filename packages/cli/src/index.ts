@@ -11,6 +11,7 @@
  *   domain: cli
  */
 import { Command } from 'commander';
+import { exitCodeFor } from '@know-graph/core';
 import {
   registerParseCommand,
   registerIndexCommand,
@@ -23,14 +24,127 @@ import {
   registerHookCommand,
   registerExportCommand,
   registerSyncCommand,
+  registerSloCommand,
+  registerCostCommand,
+  registerCheckLinksCommand,
+  registerDecisionsCommand,
+  registerGlossaryCommand,
+  registerDomainsCommand,
+  registerDuplicatesCommand,
+  registerLintCommand,
+  registerWorkspacesCommand,
+  registerGoPackagesCommand,
+  registerAuditCommand,
+  registerPluginsCommand,
+  registerBrowseCommand,
+  registerCompletionCommand,
+  registerExplainCommand,
+  registerOnboardCommand,
+  registerAskCommand,
+  registerAnomaliesCommand,
+  registerBusFactorCommand,
+  registerCheckCommand,
+  registerStitchCommand,
+  registerPatchCommand,
+  registerFsckCommand,
+  registerOperatorCommand,
+  registerCheckConfigCommand,
+  registerChangeCostCommand,
+  registerClustersCommand,
+  registerGoApiCommand,
+  registerSemverCommand,
+  registerLicensesCommand,
+  registerScorecardCommand,
+  registerReportCommand,
+  registerDeploymentsCommand,
+  registerIncidentsCommand,
+  registerGoTestsCommand,
+  registerImpactCommand,
+  registerDoctorCommand,
+  registerTelemetryCommand,
+  registerBenchCommand,
+  registerGenCommand,
+  registerDraftCommand,
+  registerReviewCommand,
+  registerDependentsCommand,
+  registerPathCommand,
+  registerSimulateCommand,
+  registerVersionsCommand,
+  registerContractsCommand,
+  registerCodegenCommand,
+  registerNewCommand,
+  registerDocsgenCommand,
+  registerPublishCommand,
+  registerRunCommand,
+  registerEditCommand,
+  registerTombstonesCommand,
+  registerSinkCommand,
+  registerConfigCommand,
+  registerVacanciesCommand,
+  registerFreshnessCommand,
+  registerCompactHistoryCommand,
+  registerResolveCommand,
+  registerAnnotateCommand,
+  registerBundleCommand,
+  registerGrepCommand,
+  registerHotspotsCommand,
+  registerConformCommand,
 } from './commands/index.js';
+import {
+  reportError,
+  resolveErrorFormat,
+  setErrorFormat,
+} from './utils/errors.js';
+import { configureLogging, resolveLogSettings } from './utils/logging.js';
+import { recordUsage, usageCommandName } from './utils/usage.js';
+
+const errorFormat = resolveErrorFormat();
+setErrorFormat(errorFormat);
+try {
+  configureLogging(resolveLogSettings());
+} catch (err) {
+  reportError(err, 'usage');
+  process.exit(exitCodeFor('usage'));
+}
 
 const program = new Command();
 
 program
   .name('knowgraph')
   .description('AI-navigable code documentation tool')
-  .version('0.4.1');
+  .version('0.4.1')
+  .option(
+    '--error-format <format>',
+    'Write errors to stderr as text or a JSON envelope (text|json)',
+    'text',
+  )
+  .option(
+    '--log-level <level>',
+    'Write log records at this level and above (debug|info|warn|error)',
+    'info',
+  )
+  .option(
+    '--log-format <format>',
+    'Write log records to stderr as text or JSON lines (text|json)',
+    'text',
+  )
+  // Bad arguments exit with the usage code, as an envelope in JSON mode
+  .configureOutput({
+    outputError: (message, write) => {
+      if (errorFormat === 'text') write(message);
+    },
+  })
+  .exitOverride((err) => {
+    if (err.exitCode === 0) process.exit(0);
+    if (errorFormat === 'json') {
+      const message =
+        err.code === 'commander.help'
+          ? 'No command given'
+          : err.message.replace(/^error: /, '');
+      reportError(message, 'usage');
+    }
+    process.exit(exitCodeFor('usage'));
+  });
 
 registerParseCommand(program);
 registerIndexCommand(program);
@@ -43,5 +157,89 @@ registerSuggestCommand(program);
 registerHookCommand(program);
 registerExportCommand(program);
 registerSyncCommand(program);
+registerSloCommand(program);
+registerCostCommand(program);
+registerCheckLinksCommand(program);
+registerDecisionsCommand(program);
+registerGlossaryCommand(program);
+registerDomainsCommand(program);
+registerDuplicatesCommand(program);
+registerLintCommand(program);
+registerWorkspacesCommand(program);
+registerGoPackagesCommand(program);
+registerAuditCommand(program);
+registerPluginsCommand(program);
+registerBrowseCommand(program);
+registerCompletionCommand(program);
+registerExplainCommand(program);
+registerOnboardCommand(program);
+registerAskCommand(program);
+registerAnomaliesCommand(program);
+registerBusFactorCommand(program);
+registerCheckCommand(program);
+registerStitchCommand(program);
+registerPatchCommand(program);
+registerFsckCommand(program);
+registerOperatorCommand(program);
+registerCheckConfigCommand(program);
+registerChangeCostCommand(program);
+registerClustersCommand(program);
+registerGoApiCommand(program);
+registerSemverCommand(program);
+registerLicensesCommand(program);
+registerScorecardCommand(program);
+registerReportCommand(program);
+registerDeploymentsCommand(program);
+registerIncidentsCommand(program);
+registerGoTestsCommand(program);
+registerImpactCommand(program);
+registerDoctorCommand(program);
+registerTelemetryCommand(program);
+registerBenchCommand(program);
+registerGenCommand(program);
+registerDraftCommand(program);
+registerReviewCommand(program);
+registerDependentsCommand(program);
+registerPathCommand(program);
+registerSimulateCommand(program);
+registerVersionsCommand(program);
+registerContractsCommand(program);
+registerCodegenCommand(program);
+registerNewCommand(program);
+registerDocsgenCommand(program);
+registerPublishCommand(program);
+registerRunCommand(program);
+registerEditCommand(program);
+registerTombstonesCommand(program);
+registerSinkCommand(program);
+registerConfigCommand(program);
+registerVacanciesCommand(program);
+registerFreshnessCommand(program);
+registerCompactHistoryCommand(program);
+registerResolveCommand(program);
+registerAnnotateCommand(program);
+registerBundleCommand(program);
+registerGrepCommand(program);
+registerHotspotsCommand(program);
+registerConformCommand(program);
 
-program.parse();
+// Only the name of the command that ran is counted, never its arguments
+let usageCommand: string | undefined;
+program.hook('preAction', (_program, actionCommand) => {
+  usageCommand = usageCommandName(actionCommand);
+});
+
+program
+  .parseAsync()
+  .catch((err: unknown) => {
+    reportError(err);
+  })
+  .then(async () => {
+    if (usageCommand) {
+      await recordUsage(
+        usageCommand,
+        Number(process.exitCode ?? 0),
+        program.version() ?? 'unknown',
+      );
+    }
+  });
@@ -0,0 +1,122 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Records mutating CLI commands, who ran them, and their diffs in the audit log
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, audit, compliance, soc2]
+ * context:
+ *   business_goal: Give compliance reviewers evidence of every change made through the CLI
+ *   domain: cli
+ */
+import { existsSync, statSync } from 'node:fs';
+import { hostname, userInfo } from 'node:os';
+import { dirname, join, relative, resolve } from 'node:path';
+import {
+  appendAuditEntry,
+  buildDependencyGraph,
+  createQueryEngine,
+  fileChange,
+} from '@know-graph/core';
+import type {
+  AuditChange,
+  DependencyGraph,
+  DependencyGraphOptions,
+  DraftedFile,
+} from '@know-graph/core';
+import { openDatabase } from './db.js';
+import { reportError } from './errors.js';
+import { readAuditConfig } from './manifest.js';
+
+/**
+ * Who is running the command: `KNOWGRAPH_ACTOR` (set it in CI to the
+ * pipeline or triggering user), then the git author, then the OS account.
+ */
+export function resolveActor(
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): string {
+  return (
+    env.KNOWGRAPH_ACTOR ||
+    env.GIT_AUTHOR_EMAIL ||
+    `${userInfo().username}@${hostname()}`
+  );
+}
+
+/** The audit log for the manifest at `configPath`, resolved next to it. */
+export function auditLogPath(configPath: string): string {
+  return resolve(dirname(configPath), readAuditConfig(configPath).path);
+}
+
+export function isAuditEnabled(configPath: string): boolean {
+  return readAuditConfig(configPath).enabled;
+}
+
+/**
+ * The graph in the index at `dbPath`, empty when there is no index yet.
+ * The manifest at `configPath` supplies the key of an encrypted index.
+ */
+export function readGraphSnapshot(
+  dbPath: string,
+  options: DependencyGraphOptions = {},
+  configPath?: string,
+): DependencyGraph {
+  if (!existsSync(dbPath)) return { nodes: [], edges: [] };
+  const dbManager = openDatabase(dbPath, configPath, { readOnly: true });
+  try {
+    return buildDependencyGraph(createQueryEngine(dbManager).getAll(), options);
+  } finally {
+    dbManager.close();
+  }
+}
+
+/**
+ * The changes to files that `draft` or `annotate` put blocks into under
+ * `path`, a directory or one file, as paths relative to the working
+ * directory.
+ */
+export function draftedChanges(
+  path: string,
+  files: readonly DraftedFile[],
+): AuditChange[] {
+  const rootDir = statSync(path).isFile() ? dirname(path) : path;
+  return files
+    .map((file) =>
+      fileChange(
+        relative('.', join(rootDir, file.filePath)),
+        file.before,
+        file.content,
+      ),
+    )
+    .filter((change): change is AuditChange => change !== undefined);
+}
+
+/**
+ * Append an entry for `command` unless the manifest turns auditing off.
+ * The outcome follows `process.exitCode`, so call this after the command
+ * has reported its result. A log that cannot be written fails the command:
+ * the change happened, but the evidence for it is missing.
+ */
+export function recordAudit(
+  configPath: string,
+  command: string,
+  changes: ReadonlyArray<AuditChange | undefined>,
+): void {
+  if (!isAuditEnabled(configPath)) return;
+  try {
+    appendAuditEntry(auditLogPath(configPath), {
+      actor: resolveActor(),
+      command,
+      args: process.argv.slice(2),
+      cwd: process.cwd(),
+      outcome: process.exitCode ? 'failure' : 'success',
+      changes: changes.filter(
+        (change): change is AuditChange => change !== undefined,
+      ),
+    });
+  } catch (err) {
+    reportError(
+      `Could not write audit log: ${err instanceof Error ? err.message : String(err)}`,
+      'io',
+    );
+  }
+}
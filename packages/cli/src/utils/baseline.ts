@@ -0,0 +1,163 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Records existing check findings in a baseline file so only new ones fail the build
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, check, baseline, ci]
+ * context:
+ *   business_goal: Let large legacy repositories adopt enforcement without cleaning up every existing problem first
+ *   domain: cli
+ */
+import { mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { followFileLineage } from '@know-graph/core';
+import type { FileLineage } from '@know-graph/core';
+import type { ReportFinding } from './ci-reports.js';
+
+export const BASELINE_VERSION = 1;
+
+/**
+ * A recorded finding. Lines are left out so findings stay baselined when
+ * code above them moves.
+ */
+export interface BaselineEntry {
+  readonly source?: string;
+  readonly filePath: string;
+  readonly rule: string;
+  readonly message: string;
+}
+
+export interface Baseline {
+  readonly version: number;
+  readonly entries: readonly BaselineEntry[];
+  /** Member keys of each dependency cycle, for cycle budgets. */
+  readonly cycles?: readonly (readonly string[])[];
+}
+
+export interface BaselineComparison<T extends ReportFinding> {
+  /** Findings the baseline does not account for. */
+  readonly fresh: readonly T[];
+  readonly baselinedCount: number;
+  /** Baseline entries with no matching finding, fixed since it was recorded. */
+  readonly fixedCount: number;
+}
+
+function entryKey(entry: BaselineEntry): string {
+  return [entry.source ?? '', entry.filePath, entry.rule, entry.message].join(
+    '\0',
+  );
+}
+
+function toEntry(finding: ReportFinding): BaselineEntry {
+  return {
+    ...(finding.source ? { source: finding.source } : {}),
+    filePath: finding.filePath,
+    rule: finding.rule,
+    message: finding.message,
+  };
+}
+
+/**
+ * A baseline of every finding and any dependency `cycles`, sorted so the
+ * file diffs cleanly.
+ */
+export function createBaseline(
+  findings: readonly ReportFinding[],
+  cycles: readonly (readonly string[])[] = [],
+): Baseline {
+  const entries = findings
+    .map(toEntry)
+    .sort(
+      (a, b) =>
+        a.filePath.localeCompare(b.filePath) ||
+        a.rule.localeCompare(b.rule) ||
+        a.message.localeCompare(b.message),
+    );
+  return {
+    version: BASELINE_VERSION,
+    entries,
+    ...(cycles.length > 0 ? { cycles } : {}),
+  };
+}
+
+/**
+ * Split findings into those the baseline records and fresh ones. Each entry
+ * accounts for one finding, so a second copy of a baselined problem in the
+ * same file is still reported. An entry also accounts for a finding in a
+ * file `lineage` says its file's code went to, so findings stay baselined
+ * when their file is renamed, split, or merged.
+ */
+export function compareWithBaseline<T extends ReportFinding>(
+  findings: readonly T[],
+  baseline: Baseline,
+  lineage: FileLineage = {},
+): BaselineComparison<T> {
+  const remaining = new Map<string, number[]>();
+  baseline.entries.forEach((entry, index) => {
+    for (const filePath of followFileLineage(lineage, entry.filePath)) {
+      const key = entryKey({ ...entry, filePath });
+      remaining.set(key, [...(remaining.get(key) ?? []), index]);
+    }
+  });
+  const used = new Set<number>();
+  const fresh: T[] = [];
+  for (const finding of findings) {
+    const candidates = remaining.get(entryKey(toEntry(finding))) ?? [];
+    const index = candidates.find((candidate) => !used.has(candidate));
+    if (index === undefined) {
+      fresh.push(finding);
+    } else {
+      used.add(index);
+    }
+  }
+  return {
+    fresh,
+    baselinedCount: findings.length - fresh.length,
+    fixedCount: baseline.entries.length - used.size,
+  };
+}
+
+function isEntry(value: unknown): value is BaselineEntry {
+  if (typeof value !== 'object' || value === null) return false;
+  const entry = value as Record<string, unknown>;
+  return (
+    typeof entry.filePath === 'string' &&
+    typeof entry.rule === 'string' &&
+    typeof entry.message === 'string' &&
+    (entry.source === undefined || typeof entry.source === 'string')
+  );
+}
+
+function isCycle(value: unknown): value is readonly string[] {
+  return (
+    Array.isArray(value) && value.every((key) => typeof key === 'string')
+  );
+}
+
+/** Read a baseline file, throwing when it is missing or malformed. */
+export function readBaseline(path: string): Baseline {
+  const parsed: unknown = JSON.parse(readFileSync(path, 'utf-8'));
+  const baseline = parsed as Partial<Baseline> | null;
+  if (
+    baseline?.version !== BASELINE_VERSION ||
+    !Array.isArray(baseline.entries) ||
+    !baseline.entries.every(isEntry) ||
+    (baseline.cycles !== undefined &&
+      !(Array.isArray(baseline.cycles) && baseline.cycles.every(isCycle)))
+  ) {
+    throw new Error(
+      `${path} is not a version ${BASELINE_VERSION} knowgraph baseline`,
+    );
+  }
+  return {
+    version: baseline.version,
+    entries: baseline.entries,
+    ...(baseline.cycles ? { cycles: baseline.cycles } : {}),
+  };
+}
+
+export function writeBaseline(path: string, baseline: Baseline): void {
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, `${JSON.stringify(baseline, null, 2)}\n`);
+}
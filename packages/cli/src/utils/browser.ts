@@ -0,0 +1,367 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: State, key handling, and rendering for the interactive knowgraph browse TUI
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, tui, browse, navigation, search]
+ * context:
+ *   business_goal: Keep the terminal browser responsive and predictable on graphs of any size
+ *   domain: cli
+ */
+import chalk from 'chalk';
+import {
+  applyView,
+  entityDiagrams,
+  findDescriptionReferences,
+  viewCell,
+} from '@know-graph/core';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  GraphNode,
+  StoredEntity,
+  ViewProfile,
+} from '@know-graph/core';
+import { fuzzyFilter } from './fuzzy.js';
+import { truncate } from './format.js';
+
+/** A dependency of the entity in view, or a dependent of it. */
+export interface BrowserEdge {
+  readonly direction: 'out' | 'in';
+  readonly kind: DependencyKind;
+  readonly node: GraphNode;
+}
+
+export interface BrowserDetail {
+  readonly entity: StoredEntity;
+  readonly edges: readonly BrowserEdge[];
+  readonly cursor: number;
+}
+
+export interface BrowserState {
+  readonly entities: readonly StoredEntity[];
+  readonly graph: DependencyGraph;
+  readonly query: string;
+  readonly matches: readonly StoredEntity[];
+  readonly cursor: number;
+  /** The entity in view; the search list is shown when absent. */
+  readonly detail?: BrowserDetail;
+  /** Entities opened before the one in view, most recent last. */
+  readonly history: readonly BrowserDetail[];
+  /** Shown in place of the key help until the next key press. */
+  readonly status?: string;
+  /** Lays out the list and detail for one audience when set. */
+  readonly view?: ViewProfile;
+}
+
+export type BrowserKey =
+  | 'up'
+  | 'down'
+  | 'pageUp'
+  | 'pageDown'
+  | 'enter'
+  | 'back'
+  | 'backspace'
+  | 'open'
+  | 'quit'
+  | { readonly text: string };
+
+export type BrowserEffect =
+  | { readonly type: 'quit' }
+  | { readonly type: 'open'; readonly filePath: string; readonly line: number };
+
+export interface BrowserUpdate {
+  readonly state: BrowserState;
+  readonly effect?: BrowserEffect;
+}
+
+export interface Viewport {
+  readonly rows: number;
+  readonly columns: number;
+}
+
+function searchFields(entity: StoredEntity): readonly string[] {
+  return [entity.name, entity.filePath, entity.description, ...entity.tags];
+}
+
+/** Browse `entities`, only those `view` shows and their fields if given. */
+export function createBrowserState(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  view?: ViewProfile,
+): BrowserState {
+  const shown = view ? applyView(view, entities) : entities;
+  return {
+    entities: shown,
+    graph,
+    query: '',
+    matches: shown,
+    cursor: 0,
+    history: [],
+    ...(view ? { view } : {}),
+  };
+}
+
+function withQuery(state: BrowserState, query: string): BrowserState {
+  return {
+    ...state,
+    query,
+    matches: fuzzyFilter(state.entities, query, searchFields),
+    cursor: 0,
+  };
+}
+
+function detailFor(state: BrowserState, entity: StoredEntity): BrowserDetail {
+  const nodes = new Map(state.graph.nodes.map((node) => [node.id, node]));
+  const edges: BrowserEdge[] = [];
+  for (const edge of state.graph.edges) {
+    const node = edge.from === entity.id ? nodes.get(edge.to) : undefined;
+    if (node) edges.push({ direction: 'out', kind: edge.kind, node });
+  }
+  for (const edge of state.graph.edges) {
+    const node = edge.to === entity.id ? nodes.get(edge.from) : undefined;
+    if (node) edges.push({ direction: 'in', kind: edge.kind, node });
+  }
+  return { entity, edges, cursor: 0 };
+}
+
+function clamp(value: number, length: number): number {
+  return Math.max(0, Math.min(value, length - 1));
+}
+
+function move(
+  cursor: number,
+  key: BrowserKey,
+  length: number,
+  page: number,
+): number {
+  if (key === 'up') return clamp(cursor - 1, length);
+  if (key === 'down') return clamp(cursor + 1, length);
+  if (key === 'pageUp') return clamp(cursor - page, length);
+  if (key === 'pageDown') return clamp(cursor + page, length);
+  return cursor;
+}
+
+function updateDetail(
+  state: BrowserState,
+  detail: BrowserDetail,
+  key: BrowserKey,
+  page: number,
+): BrowserUpdate {
+  if (key === 'back') {
+    const previous = state.history.at(-1);
+    const history = state.history.slice(0, -1);
+    return { state: { ...state, detail: previous, history } };
+  }
+  if (key === 'enter') {
+    const target = detail.edges[detail.cursor]?.node;
+    const entity = state.entities.find((e) => e.id === target?.id);
+    if (!entity) return { state };
+    return {
+      state: {
+        ...state,
+        detail: detailFor(state, entity),
+        history: [...state.history, detail],
+      },
+    };
+  }
+  if (key === 'open') {
+    const { filePath, line } = detail.entity;
+    return { state, effect: { type: 'open', filePath, line } };
+  }
+  const cursor = move(detail.cursor, key, detail.edges.length, page);
+  return { state: { ...state, detail: { ...detail, cursor } } };
+}
+
+/**
+ * Apply one key press. Typing filters the list; Enter opens the selected
+ * entity, or follows the selected edge in the detail view; Back returns
+ * along the history, then clears the query, then quits.
+ */
+export function updateBrowser(
+  state: BrowserState,
+  key: BrowserKey,
+  page = 10,
+): BrowserUpdate {
+  if (key === 'quit') return { state, effect: { type: 'quit' } };
+  if (state.detail) return updateDetail(state, state.detail, key, page);
+
+  if (typeof key === 'object') {
+    return { state: withQuery(state, state.query + key.text) };
+  }
+  const selected = state.matches[state.cursor];
+  switch (key) {
+    case 'backspace':
+      return { state: withQuery(state, state.query.slice(0, -1)) };
+    case 'back':
+      if (state.query) return { state: withQuery(state, '') };
+      return { state, effect: { type: 'quit' } };
+    case 'enter':
+      if (!selected) return { state };
+      return { state: { ...state, detail: detailFor(state, selected) } };
+    case 'open':
+      if (!selected) return { state };
+      return {
+        state,
+        effect: {
+          type: 'open',
+          filePath: selected.filePath,
+          line: selected.line,
+        },
+      };
+    default:
+      return {
+        state: {
+          ...state,
+          cursor: move(state.cursor, key, state.matches.length, page),
+        },
+      };
+  }
+}
+
+/** The page of `length` rows holding `cursor` that fits in `height`. */
+function visibleRange(
+  cursor: number,
+  length: number,
+  height: number,
+): { readonly start: number; readonly end: number } {
+  const size = Math.max(height, 1);
+  const start = Math.floor(cursor / size) * size;
+  return { start, end: Math.min(start + size, length) };
+}
+
+function row(text: string, columns: number, selected: boolean): string {
+  const line = truncate(text, columns);
+  return selected ? chalk.inverse(line) : line;
+}
+
+function renderList(state: BrowserState, viewport: Viewport): string[] {
+  const lines = [
+    `${chalk.bold('knowgraph browse')} ${chalk.dim(
+      `${state.matches.length} of ${state.entities.length} entities`,
+    )}`,
+    `${chalk.cyan('>')} ${state.query}${chalk.inverse(' ')}`,
+  ];
+  const height = viewport.rows - lines.length - 1;
+  if (state.matches.length === 0) {
+    lines.push(chalk.dim('  No matches.'));
+  }
+  const { start, end } = visibleRange(
+    state.cursor,
+    state.matches.length,
+    height,
+  );
+  const { view } = state;
+  for (let i = start; i < end; i++) {
+    const entity = state.matches[i];
+    const text = view
+      ? `  ${view.columns.map((c) => viewCell(entity, c.field)).join('  ')}`
+      : `  ${entity.name}  ${entity.entityType}  ` +
+        `${entity.filePath}:${entity.line}  ${entity.owner ?? '-'}`;
+    lines.push(row(text, viewport.columns, i === state.cursor));
+  }
+  return lines;
+}
+
+function edgeText(edge: BrowserEdge): string {
+  const location = edge.node.external
+    ? 'external'
+    : (edge.node.filePath ?? '');
+  const arrow = edge.direction === 'out' ? '->' : '<-';
+  return `  ${arrow} ${edge.node.name}  ${edge.kind}  ${location}`;
+}
+
+/**
+ * `description` cut to `columns`, with each `[[target]]` shown as its
+ * target, underlined: a `reference` edge to follow below.
+ */
+function linkReferences(description: string, columns: number): string {
+  const spans: (readonly [number, number])[] = [];
+  let text = '';
+  let last = 0;
+  for (const reference of findDescriptionReferences(description)) {
+    text += description.slice(last, reference.start);
+    spans.push([text.length, text.length + reference.target.length]);
+    text += reference.target;
+    last = reference.end;
+  }
+  const shown = truncate(text + description.slice(last), columns);
+  let linked = '';
+  let at = 0;
+  for (const [start, end] of spans) {
+    if (start >= shown.length) break;
+    const stop = Math.min(end, shown.length);
+    linked += shown.slice(at, start);
+    linked += chalk.underline(shown.slice(start, stop));
+    at = stop;
+  }
+  return linked + shown.slice(at);
+}
+
+// Fields the detail shows whether or not a view lists them
+const DETAIL_FIELDS = new Set(['owner', 'status', 'tags', 'links']);
+
+function renderDetail(
+  detail: BrowserDetail,
+  viewport: Viewport,
+  view: ViewProfile | undefined,
+): string[] {
+  const { entity } = detail;
+  const lines = [
+    `${chalk.bold(entity.name)} ${chalk.dim(`(${entity.entityType})`)}` +
+      (entity.status ? ` ${entity.status}` : ''),
+    chalk.dim(`${entity.filePath}:${entity.line}  ${entity.owner ?? '-'}`),
+    linkReferences(entity.description, viewport.columns),
+  ];
+  if (entity.tags.length > 0) lines.push(`tags: ${entity.tags.join(', ')}`);
+  for (const link of entity.links) {
+    const text = `${link.title ?? link.type ?? 'link'}: ${link.url}`;
+    lines.push(truncate(text, viewport.columns));
+  }
+  for (const diagram of entityDiagrams(entity)) {
+    lines.push(truncate(`diagram: ${diagram}`, viewport.columns));
+  }
+  for (const field of view?.fields ?? []) {
+    if (DETAIL_FIELDS.has(field)) continue;
+    const value = viewCell(entity, `metadata.${field}`);
+    lines.push(truncate(`${field}: ${value}`, viewport.columns));
+  }
+  lines.push('');
+  const outgoing = detail.edges.filter((e) => e.direction === 'out').length;
+  lines.push(
+    chalk.bold(
+      `Depends on ${outgoing}, used by ${detail.edges.length - outgoing}`,
+    ),
+  );
+  if (detail.edges.length === 0) lines.push(chalk.dim('  No dependencies.'));
+  const height = viewport.rows - lines.length - 1;
+  const { start, end } = visibleRange(
+    detail.cursor,
+    detail.edges.length,
+    height,
+  );
+  for (let i = start; i < end; i++) {
+    lines.push(
+      row(edgeText(detail.edges[i]), viewport.columns, i === detail.cursor),
+    );
+  }
+  return lines;
+}
+
+/** The whole screen for `state`, with the key help on the last row. */
+export function renderBrowser(state: BrowserState, viewport: Viewport): string {
+  const lines = state.detail
+    ? renderDetail(state.detail, viewport, state.view)
+    : renderList(state, viewport);
+  const help = state.detail
+    ? 'up/down select  enter follow edge  ctrl-o open in editor  esc back'
+    : 'type to search  up/down select  enter details  ctrl-o open in editor  esc quit';
+  while (lines.length < viewport.rows - 1) lines.push('');
+  lines.push(
+    state.status
+      ? chalk.yellow(truncate(state.status, viewport.columns))
+      : chalk.dim(truncate(help, viewport.columns)),
+  );
+  return lines.slice(0, viewport.rows).join('\n');
+}
@@ -0,0 +1,139 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Formats validation findings as GitLab Code Quality JSON and JUnit XML reports
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, ci, gitlab, junit, reports]
+ * context:
+ *   business_goal: Show annotation problems natively in merge request UIs on CI systems other than GitHub
+ *   domain: cli
+ */
+import { createHash } from 'node:crypto';
+import type { ValidationSeverity } from '@know-graph/core';
+
+/** A validation or lint problem in a form CI report formats can carry. */
+export interface ReportFinding {
+  readonly severity: ValidationSeverity;
+  /** Relative to the repository root, so the CI system can place it. */
+  readonly filePath: string;
+  readonly line: number;
+  readonly rule: string;
+  readonly message: string;
+  /** The tool that found the problem, such as `validate` or `lint`. */
+  readonly source?: string;
+}
+
+/** One entry of a GitLab Code Quality report. */
+export interface CodeQualityIssue {
+  readonly description: string;
+  readonly check_name: string;
+  readonly fingerprint: string;
+  readonly severity: 'info' | 'minor' | 'major' | 'critical' | 'blocker';
+  readonly location: {
+    readonly path: string;
+    readonly lines: { readonly begin: number };
+  };
+}
+
+const CODE_QUALITY_SEVERITIES: Readonly<
+  Record<ValidationSeverity, CodeQualityIssue['severity']>
+> = { error: 'major', warning: 'minor', info: 'info' };
+
+function checkName(finding: ReportFinding): string {
+  return finding.source ? `${finding.source}/${finding.rule}` : finding.rule;
+}
+
+/**
+ * GitLab Code Quality issues for the findings. The fingerprint hashes the
+ * rule, location, and message, so the same problem keeps its fingerprint
+ * between pipelines and GitLab can tell new problems from fixed ones.
+ */
+export function toCodeQualityIssues(
+  findings: readonly ReportFinding[],
+): readonly CodeQualityIssue[] {
+  return findings.map((finding) => ({
+    description: finding.message,
+    check_name: checkName(finding),
+    fingerprint: createHash('md5')
+      .update(
+        [
+          checkName(finding),
+          finding.filePath,
+          finding.line,
+          finding.message,
+        ].join('\0'),
+      )
+      .digest('hex'),
+    severity: CODE_QUALITY_SEVERITIES[finding.severity],
+    location: { path: finding.filePath, lines: { begin: finding.line } },
+  }));
+}
+
+export function formatCodeQualityReport(
+  findings: readonly ReportFinding[],
+): string {
+  return JSON.stringify(toCodeQualityIssues(findings), null, 2);
+}
+
+function escapeXml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&apos;');
+}
+
+export interface JUnitReportOptions {
+  /** The test suite name, such as `knowgraph check`. */
+  readonly name: string;
+  /** Report warnings as failures rather than passing test cases. */
+  readonly strict?: boolean;
+}
+
+/**
+ * A JUnit XML report with one test case per finding. Errors, and warnings
+ * under `strict`, are failures; other findings pass with the message as
+ * output. A clean run reports a single passing test case so the suite is
+ * never empty.
+ */
+export function formatJUnitReport(
+  findings: readonly ReportFinding[],
+  options: JUnitReportOptions,
+): string {
+  const failed = (finding: ReportFinding): boolean =>
+    finding.severity === 'error' ||
+    (finding.severity === 'warning' && options.strict === true);
+  const failures = findings.filter(failed).length;
+  const tests = Math.max(findings.length, 1);
+  const name = escapeXml(options.name);
+  const cases =
+    findings.length === 0
+      ? ['    <testcase classname="knowgraph" name="annotations"/>']
+      : findings.map((finding) => {
+          const path = escapeXml(finding.filePath);
+          const title = escapeXml(
+            `${checkName(finding)} at line ${finding.line}`,
+          );
+          const text = escapeXml(
+            `${finding.filePath}:${finding.line} ${finding.message}`,
+          );
+          const body = failed(finding)
+            ? `<failure type="${finding.severity}" message="${escapeXml(finding.message)}">${text}</failure>`
+            : `<system-out>${text}</system-out>`;
+          return [
+            `    <testcase classname="${path}" name="${title}" file="${path}" line="${finding.line}">`,
+            `      ${body}`,
+            '    </testcase>',
+          ].join('\n');
+        });
+  return [
+    '<?xml version="1.0" encoding="UTF-8"?>',
+    `<testsuites name="${name}" tests="${tests}" failures="${failures}">`,
+    `  <testsuite name="${name}" tests="${tests}" failures="${failures}" errors="0">`,
+    ...cases,
+    '  </testsuite>',
+    '</testsuites>',
+  ].join('\n');
+}
@@ -0,0 +1,124 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Helpers for opening the knowgraph index and loading entities for report commands
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, database, index, loader]
+ * context:
+ *   business_goal: Give report commands one consistent way to read the indexed graph
+ *   domain: cli
+ */
+import { existsSync } from 'node:fs';
+import { dirname, join, resolve } from 'node:path';
+import {
+  buildDependencyGraphCached,
+  createDatabaseManager,
+  createGraphCache,
+  createQueryEngine,
+} from '@know-graph/core';
+import type {
+  DatabaseManager,
+  DependencyGraph,
+  DependencyGraphOptions,
+  FileLineage,
+  StoredEntity,
+} from '@know-graph/core';
+import { reportError } from './errors.js';
+import {
+  readEdgeRules,
+  readEncryptionOptions,
+  readGraphNames,
+} from './manifest.js';
+
+/**
+ * Open the index at `dbPath`, encrypted at rest when the manifest at
+ * `configPath` configures a key. Commands that only read it open it
+ * `readOnly`, so they do not wait for a run writing an encrypted index.
+ */
+export function openDatabase(
+  dbPath: string,
+  configPath: string = resolve('.knowgraph.yml'),
+  options: { readonly readOnly?: boolean } = {},
+): DatabaseManager {
+  return createDatabaseManager(dbPath, {
+    ...readEncryptionOptions(configPath),
+    ...options,
+  });
+}
+
+/**
+ * Load every indexed entity from the database at `dbPath`, decrypted with
+ * the key the manifest at `configPath` configures.
+ * Prints an error and sets a failing exit code when the index is missing.
+ */
+export function loadEntities(
+  dbPath: string,
+  configPath: string = resolve('.knowgraph.yml'),
+): readonly StoredEntity[] | undefined {
+  if (!existsSync(dbPath)) {
+    reportError(
+      `Database not found at ${dbPath}`,
+      'io',
+      "Run 'knowgraph index' first to create the database.",
+    );
+    return undefined;
+  }
+
+  const dbManager = openDatabase(dbPath, configPath, { readOnly: true });
+  try {
+    return createQueryEngine(dbManager).getAll();
+  } finally {
+    dbManager.close();
+  }
+}
+
+/**
+ * Where the code of files renamed, split, or merged since earlier scans
+ * went, from the index at `dbPath`; empty when there is no index yet.
+ */
+export function readFileLineage(
+  dbPath: string,
+  configPath: string = resolve('.knowgraph.yml'),
+): FileLineage {
+  if (!existsSync(dbPath)) return {};
+  const dbManager = openDatabase(dbPath, configPath, { readOnly: true });
+  try {
+    return dbManager.getFileLineage();
+  } finally {
+    dbManager.close();
+  }
+}
+
+export interface BuildGraphOptions extends DependencyGraphOptions {
+  /** The manifest to read; `.knowgraph.yml` in the working directory. */
+  readonly configPath?: string;
+}
+
+/**
+ * Build the dependency graph for entities loaded from `dbPath`, reusing the
+ * copy cached next to the database when entities and options are unchanged.
+ * The `aliases`, `renames`, and `edge_rules` in the manifest apply unless
+ * `options` sets its own.
+ */
+export function buildGraph(
+  dbPath: string,
+  entities: readonly StoredEntity[],
+  options: BuildGraphOptions = {},
+): DependencyGraph {
+  const { configPath = resolve('.knowgraph.yml'), ...graphOptions } = options;
+  const cache = createGraphCache(join(dirname(dbPath), 'cache'), {
+    format: 'binary',
+    ...readEncryptionOptions(configPath),
+  });
+  const edgeRules = readEdgeRules(configPath);
+  return buildDependencyGraphCached(
+    entities,
+    {
+      ...readGraphNames(configPath),
+      ...(edgeRules.length > 0 ? { edgeRules } : {}),
+      ...graphOptions,
+    },
+    cache,
+  );
+}
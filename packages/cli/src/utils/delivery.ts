@@ -0,0 +1,146 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Builds the delivery client for network sinks from the manifest's retry, offline queue, and credentials settings
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, delivery, retry, queue, credentials, auth]
+ * context:
+ *   business_goal: Keep a flaky network in CI from failing a whole run over one unreachable sink
+ *   domain: cli
+ */
+import { dirname, resolve } from 'node:path';
+import chalk from 'chalk';
+import {
+  createCloudCredentials,
+  createDeliveryClient,
+  createKnowgraphError,
+  createMtlsCredentials,
+  createOidcDeviceCredentials,
+  createTokenCredentials,
+} from '@know-graph/core';
+import type {
+  CredentialsProvider,
+  DeliveryAuthConfig,
+  DeliveryClient,
+  DeliveryConfig,
+  DeviceAuthorization,
+  RetryPolicy,
+  ScopedCredentials,
+} from '@know-graph/core';
+import { readDeliveryConfig } from './manifest.js';
+
+function promptDeviceSignIn(
+  issuer: string,
+): (authorization: DeviceAuthorization) => void {
+  return (authorization) => {
+    const url =
+      authorization.verificationUriComplete ?? authorization.verificationUri;
+    console.error(
+      `To sign in to ${issuer}, open ${chalk.cyan(url)} and enter ${chalk.bold(authorization.userCode)}`,
+    );
+  };
+}
+
+/** A provider that fails when used, for a variable that is unset. */
+function unsetVariable(type: string, name: string): CredentialsProvider {
+  return {
+    type,
+    credentials: async () => {
+      throw createKnowgraphError('usage', `${name} is not set`);
+    },
+  };
+}
+
+/**
+ * The provider `auth` configures, with paths resolved against the
+ * manifest's directory and secrets read from `env`. An unset variable
+ * fails the deliveries that need it rather than every one.
+ */
+export function createManifestCredentials(
+  configPath: string,
+  auth: DeliveryAuthConfig,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): CredentialsProvider {
+  const dir = dirname(configPath);
+  switch (auth.type) {
+    case 'token': {
+      const token = env[auth.token_env];
+      return token
+        ? createTokenCredentials(token)
+        : unsetVariable('token', auth.token_env);
+    }
+    case 'oidc':
+      return createOidcDeviceCredentials({
+        issuer: auth.issuer,
+        clientId: auth.client_id,
+        scope: auth.scope,
+        audience: auth.audience,
+        cachePath: resolve(dir, auth.cache),
+        prompt: promptDeviceSignIn(auth.issuer),
+      });
+    case 'mtls': {
+      const passphrase = auth.passphrase_env && env[auth.passphrase_env];
+      if (auth.passphrase_env && passphrase === undefined) {
+        return unsetVariable('mtls', auth.passphrase_env);
+      }
+      return createMtlsCredentials({
+        certPath: resolve(dir, auth.cert),
+        keyPath: resolve(dir, auth.key),
+        caPath: auth.ca === undefined ? undefined : resolve(dir, auth.ca),
+        passphrase,
+      });
+    }
+    case 'cloud':
+      return createCloudCredentials({
+        source: auth.source,
+        audience: auth.audience,
+        tokenPath:
+          auth.token_file === undefined
+            ? undefined
+            : resolve(dir, auth.token_file),
+        env,
+      });
+  }
+}
+
+/** The retry policy `delivery` configures. */
+export function manifestRetryPolicy(delivery: DeliveryConfig): RetryPolicy {
+  return {
+    attempts: delivery.attempts,
+    initialDelayMs: delivery.backoff_ms,
+    maxDelayMs: delivery.max_backoff_ms,
+  };
+}
+
+/** A provider for each `delivery.auth` entry, scoped to its URL. */
+export function manifestScopedCredentials(
+  configPath: string,
+  delivery: DeliveryConfig,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): readonly ScopedCredentials[] {
+  return delivery.auth.map((auth) => ({
+    url: auth.url,
+    provider: createManifestCredentials(configPath, auth, env),
+  }));
+}
+
+/**
+ * A client with the retries and credentials in `.knowgraph.yml` and,
+ * unless it or `queue` turns the queue off, an outbox resolved against
+ * the manifest's directory. Without one, failed deliveries throw.
+ */
+export function createManifestDeliveryClient(
+  configPath: string,
+  { queue = true }: { readonly queue?: boolean } = {},
+): DeliveryClient {
+  const config = readDeliveryConfig(configPath);
+  return createDeliveryClient({
+    retry: manifestRetryPolicy(config),
+    queuePath:
+      queue && config.queue.enabled
+        ? resolve(dirname(configPath), config.queue.path)
+        : undefined,
+    credentials: manifestScopedCredentials(configPath, config),
+  });
+}
@@ -0,0 +1,118 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses --provenance, --min-confidence, --protocol, and --criticality flags into an edge filter
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, provenance, confidence, protocol, criticality, options]
+ * context:
+ *   business_goal: Give every command the same way to narrow a graph by how sure its edges are
+ *   domain: cli
+ */
+import {
+  DependencyCriticalitySchema,
+  createKnowgraphError,
+  parseEdgeProvenances,
+} from '@know-graph/core';
+import type {
+  DependencyCriticality,
+  EdgeFilter,
+  EdgeProvenance,
+} from '@know-graph/core';
+
+/** The `--protocol` and `--criticality` flags, as given. */
+export interface EdgeAnnotationFlags {
+  readonly protocol?: string;
+  readonly criticality?: string;
+}
+
+/** Parse `--provenance`, throwing a usage error on an unknown name. */
+function parseProvenances(value: string): readonly EdgeProvenance[] {
+  try {
+    return parseEdgeProvenances(value);
+  } catch (err) {
+    throw createKnowgraphError(
+      'usage',
+      err instanceof Error ? err.message : String(err),
+    );
+  }
+}
+
+/** Parse `--min-confidence`, throwing a usage error unless it is 0 to 1. */
+function parseConfidence(value: string): number {
+  const confidence = Number(value);
+  if (value.trim() === '' || !(confidence >= 0 && confidence <= 1)) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid --min-confidence '${value}': expected a number from 0 to 1`,
+    );
+  }
+  return confidence;
+}
+
+function listOf(value: string): string[] {
+  return value
+    .split(',')
+    .map((item) => item.trim())
+    .filter((item) => item !== '');
+}
+
+/** Parse `--protocol`, throwing a usage error on an empty list. */
+function parseProtocols(value: string): readonly string[] {
+  const protocols = listOf(value);
+  if (protocols.length === 0) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid --protocol '${value}': expected protocols such as grpc,http`,
+    );
+  }
+  return protocols;
+}
+
+/** Parse `--criticality`, throwing a usage error on an unknown level. */
+function parseCriticalities(value: string): readonly DependencyCriticality[] {
+  const levels = listOf(value);
+  const valid = levels.every(
+    (level) => DependencyCriticalitySchema.safeParse(level).success,
+  );
+  if (!valid || levels.length === 0) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid --criticality '${value}': use ${DependencyCriticalitySchema.options.join(', ')}`,
+    );
+  }
+  return levels as DependencyCriticality[];
+}
+
+/**
+ * The edge filter for `--provenance`, `--min-confidence`, and the
+ * `--protocol` and `--criticality` of annotated dependencies, or undefined
+ * when none is given.
+ */
+export function parseEdgeFilter(
+  provenance: string | undefined,
+  minConfidence: string | undefined,
+  annotations: EdgeAnnotationFlags = {},
+): EdgeFilter | undefined {
+  const { protocol, criticality } = annotations;
+  if (
+    provenance === undefined &&
+    minConfidence === undefined &&
+    protocol === undefined &&
+    criticality === undefined
+  ) {
+    return undefined;
+  }
+  return {
+    ...(provenance !== undefined
+      ? { provenance: parseProvenances(provenance) }
+      : {}),
+    ...(minConfidence !== undefined
+      ? { minConfidence: parseConfidence(minConfidence) }
+      : {}),
+    ...(protocol !== undefined ? { protocols: parseProtocols(protocol) } : {}),
+    ...(criticality !== undefined
+      ? { criticalities: parseCriticalities(criticality) }
+      : {}),
+  };
+}
@@ -0,0 +1,31 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Checks an --env flag against the environments annotations declare dependencies for
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, environments, dependencies, options]
+ * context:
+ *   business_goal: Catch a mistyped environment instead of silently showing only the shared dependencies
+ *   domain: cli
+ */
+import { createKnowgraphError, dependencyEnvironments } from '@know-graph/core';
+import type { StoredEntity } from '@know-graph/core';
+
+/**
+ * Throw a usage error when `environment` is given and none of `entities`
+ * declares dependencies for it, naming those that are declared.
+ */
+export function checkEnvironment(
+  environment: string | undefined,
+  entities: Iterable<StoredEntity>,
+): void {
+  if (environment === undefined) return;
+  const known = dependencyEnvironments(entities);
+  if (known.includes(environment)) return;
+  const declared = known.length > 0 ? ` (declared: ${known.join(', ')})` : '';
+  throw createKnowgraphError(
+    'usage',
+    `No annotation declares dependencies for environment '${environment}'${declared}`,
+  );
+}
@@ -0,0 +1,93 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reports command failures as text or a JSON envelope on stderr and sets the exit code for their kind
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, errors, exit-codes, automation]
+ * context:
+ *   business_goal: Give bots a machine-readable reason for each failure on stderr
+ *   domain: cli
+ */
+import chalk from 'chalk';
+import { classifyError, exitCodeFor, toErrorEnvelope } from '@know-graph/core';
+import type { ErrorKind } from '@know-graph/core';
+
+export type ErrorFormat = 'text' | 'json';
+
+let errorFormat: ErrorFormat = 'text';
+
+/**
+ * The format from `--error-format <format>` or `--error-format=<format>`,
+ * then `KNOWGRAPH_ERROR_FORMAT`. It is read from argv before parsing so
+ * that option parsing errors use it too.
+ */
+export function resolveErrorFormat(
+  argv: readonly string[] = process.argv,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): ErrorFormat {
+  const index = argv.indexOf('--error-format');
+  const inline = argv.find((arg) => arg.startsWith('--error-format='));
+  const value =
+    index >= 0
+      ? argv[index + 1]
+      : (inline?.slice('--error-format='.length) ??
+        env.KNOWGRAPH_ERROR_FORMAT);
+  return value === 'json' ? 'json' : 'text';
+}
+
+export function setErrorFormat(format: ErrorFormat): void {
+  errorFormat = format;
+}
+
+function writeEnvelope(
+  err: unknown,
+  kind: ErrorKind,
+  extra: Readonly<Record<string, unknown>> = {},
+): void {
+  const envelope = toErrorEnvelope(err, kind);
+  const details = { ...envelope.error.details, ...extra };
+  console.error(
+    JSON.stringify(
+      Object.keys(details).length > 0
+        ? { error: { ...envelope.error, details } }
+        : envelope,
+    ),
+  );
+}
+
+/**
+ * Report a failure on stderr and set the exit code for its kind. Messages
+ * are printed as `Error: <message>` with the `hint` below, or as one JSON
+ * envelope line with `--error-format json`. `kind` overrides the
+ * classification of `err`.
+ */
+export function reportError(
+  err: unknown,
+  kind?: ErrorKind,
+  hint?: string,
+): void {
+  const resolved = kind ?? classifyError(err);
+  if (errorFormat === 'json') {
+    writeEnvelope(err, resolved, hint ? { hint } : {});
+  } else {
+    const message = err instanceof Error ? err.message : String(err);
+    console.error(chalk.red(`Error: ${message}`));
+    if (hint) console.error(chalk.yellow(hint));
+  }
+  process.exitCode = exitCodeFor(resolved);
+}
+
+/**
+ * Fail a check that ran and found problems, such as lint issues or schema
+ * violations. The command has already printed its report, so text mode
+ * adds nothing; JSON mode adds the envelope so bots see one failure shape.
+ */
+export function reportCheckFailure(
+  message: string,
+  kind: ErrorKind = 'policy',
+  details: Readonly<Record<string, unknown>> = {},
+): void {
+  if (errorFormat === 'json') writeEnvelope(message, kind, details);
+  process.exitCode = exitCodeFor(kind);
+}
@@ -0,0 +1,157 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Turns export flags and manifest settings into the options exporters take and the chain that dates, localizes, projects, and redacts each entity
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, export, options, redaction, localization]
+ * context:
+ *   business_goal: Give every export format the same filters, names, and redaction from one set of flags
+ *   domain: cli
+ */
+import {
+  createKnowgraphError,
+  createRedactor,
+  localizeEntity,
+  parseAsOfDate,
+  projectEntity,
+  readGitSubmodules,
+  resolveRedactionProfile,
+  selectAsOf,
+  selectEnvironment,
+} from '@know-graph/core';
+import type {
+  Exporter,
+  ExportOptions,
+  RedactionProfile,
+  Redactor,
+  ViewProfile,
+} from '@know-graph/core';
+import { parseEdgeFilter } from './edge-filter.js';
+import {
+  parseTimeout,
+  readDefaultLocale,
+  readEdgeRules,
+  readGraphNames,
+  readNamespace,
+  readPruneOptions,
+  readRedactionProfiles,
+  readTimeouts,
+  saltRedactionProfile,
+} from './manifest.js';
+import { resolveNamespace } from './namespace.js';
+import { resolvePruneOptions } from './prune.js';
+import type { PruneFlags } from './prune.js';
+
+/** The export flags that set exporter options, whatever the format. */
+export interface ExportOptionFlags extends PruneFlags {
+  readonly timeout?: string;
+  readonly provenance?: string;
+  readonly minConfidence?: string;
+  readonly protocol?: string;
+  readonly criticality?: string;
+  readonly namespace?: string;
+  readonly chunkTokens?: string;
+}
+
+/** The export flags that change each entity before it is written. */
+export interface EntityPipelineFlags {
+  readonly locale?: string;
+  readonly asOf?: string;
+  readonly env?: string;
+  readonly redact?: string;
+}
+
+/** How each entity is prepared for an exporter. */
+export interface EntityPipeline {
+  /** The `--redact` profile, salted, when one was asked for. */
+  readonly profile?: RedactionProfile;
+  /** Dates, environment, locale, and view applied, but nothing redacted. */
+  readonly shape: Redactor;
+  /** `shape`, then the redaction profile. */
+  readonly prepare: Redactor;
+}
+
+export function parseChunkTokens(value: string): number {
+  const tokens = Number(value);
+  if (value.trim() === '' || !Number.isInteger(tokens) || tokens < 1) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid --chunk-tokens '${value}': expected a whole number >= 1`,
+    );
+  }
+  return tokens;
+}
+
+/**
+ * The options an exporter is called with, from `flags` and the manifest at
+ * `configPath`, for the repository at `rootDir`. Throws a usage error for
+ * a bad flag.
+ */
+export function resolveExportOptions(
+  rootDir: string,
+  configPath: string,
+  flags: ExportOptionFlags,
+): ExportOptions {
+  const edgeRules = readEdgeRules(configPath);
+  return {
+    timeoutMs: parseTimeout(flags.timeout, readTimeouts(configPath).export_ms),
+    edgeFilter: parseEdgeFilter(flags.provenance, flags.minConfidence, {
+      protocol: flags.protocol,
+      criticality: flags.criticality,
+    }),
+    namespace: resolveNamespace(flags.namespace, readNamespace(configPath)),
+    submodules: readGitSubmodules(rootDir),
+    prune: resolvePruneOptions(flags, readPruneOptions(configPath)),
+    chunkTokens:
+      flags.chunkTokens !== undefined
+        ? parseChunkTokens(flags.chunkTokens)
+        : undefined,
+    ...readGraphNames(configPath),
+    ...(edgeRules.length > 0 ? { edgeRules } : {}),
+  };
+}
+
+/**
+ * The chain each entity goes through before `exporter` writes it: the
+ * `--as-of` date and `--env` environment are selected, localized formats
+ * read the locale, and `view` drops the fields it leaves out. Redaction
+ * comes last, so nothing localization resolves escapes the profile.
+ */
+export function createEntityPipeline(
+  configPath: string,
+  exporter: Exporter,
+  flags: EntityPipelineFlags,
+  view?: ViewProfile,
+): EntityPipeline {
+  const profile = flags.redact
+    ? saltRedactionProfile(
+        resolveRedactionProfile(
+          flags.redact,
+          readRedactionProfiles(configPath),
+        ),
+      )
+    : undefined;
+  const redact: Redactor = profile
+    ? createRedactor(profile)
+    : (entity) => entity;
+  const defaultLocale = readDefaultLocale(configPath);
+  const locale = flags.locale ?? defaultLocale;
+  const asOf =
+    flags.asOf !== undefined ? parseAsOfDate(flags.asOf) : undefined;
+  const dated: Redactor = asOf
+    ? (entity) => selectAsOf(entity, asOf)
+    : (entity) => entity;
+  const { env } = flags;
+  const inEnvironment: Redactor = env
+    ? (entity) => selectEnvironment(dated(entity), env)
+    : dated;
+  const localize: Redactor = exporter.localized
+    ? (entity) => localizeEntity(inEnvironment(entity), locale, defaultLocale)
+    : inEnvironment;
+  // Fields the view leaves out go after localization has read them
+  const shape: Redactor = view
+    ? (entity) => projectEntity(view, localize(entity))
+    : localize;
+  return { profile, shape, prepare: (entity) => redact(shape(entity)) };
+}
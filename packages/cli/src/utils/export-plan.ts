@@ -0,0 +1,158 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Writes export output into place atomically, and renders the --dry-run plan, pruning report, and --redact --preview diff of an export
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, export, dry-run, plan, redaction, preview]
+ * context:
+ *   business_goal: Show what an export would write or take out before anything leaves the machine
+ *   domain: cli
+ */
+import { existsSync, readFileSync, renameSync, rmSync } from 'node:fs';
+import chalk from 'chalk';
+import { createFileSink, fileChange } from '@know-graph/core';
+import type {
+  AuditChange,
+  ByteSink,
+  PruneDecision,
+  RedactionPreview,
+  RedactionRule,
+} from '@know-graph/core';
+import { colorDiffLine } from './plan.js';
+
+/** What pruning left out, one node per line, so nothing goes unexplained. */
+export function formatPruneReport(decisions: readonly PruneDecision[]): string {
+  const lines = [`Pruned ${decisions.length} node(s):`];
+  for (const decision of decisions) {
+    const into = decision.into ? ' into its module' : '';
+    lines.push(
+      `  ${decision.name} ${chalk.dim(`(${decision.reason}${into}, significance ${decision.significance})`)}`,
+    );
+  }
+  return lines.join('\n');
+}
+
+/**
+ * Stream output to a temporary file next to `path` and move it into place
+ * once `write` succeeds, so a failed or cancelled export leaves the previous
+ * file untouched.
+ */
+export function writeToFile<T>(
+  path: string,
+  write: (sink: ByteSink) => T,
+): T {
+  const tempPath = `${path}.tmp`;
+  const sink = createFileSink(tempPath);
+  try {
+    const result = write(sink);
+    sink.close();
+    renameSync(tempPath, path);
+    return result;
+  } catch (err) {
+    try {
+      sink.close();
+    } catch {
+      // Already closed
+    }
+    rmSync(tempPath, { force: true });
+    throw err;
+  }
+}
+
+/**
+ * Render into memory and describe how `path` would change. Text output
+ * gets a diff; binary output, such as a snapshot, only a summary.
+ */
+export function planFile<T>(
+  path: string,
+  target: string,
+  write: (sink: ByteSink) => T,
+): { readonly result: T; readonly change?: AuditChange } {
+  const chunks: Buffer[] = [];
+  let binary = false;
+  const result = write({
+    write(chunk) {
+      if (typeof chunk !== 'string') binary = true;
+      chunks.push(Buffer.from(chunk));
+    },
+  });
+  const after = Buffer.concat(chunks);
+  const before = existsSync(path) ? readFileSync(path) : undefined;
+  if (!binary) {
+    return {
+      result,
+      change: fileChange(target, before?.toString('utf-8'), after.toString()),
+    };
+  }
+  if (before?.equals(after)) return { result };
+  return {
+    result,
+    change: { target, summary: before ? 'modified' : 'created' },
+  };
+}
+
+/** Render an export into memory; binary output has no text to diff. */
+export function renderExport(
+  write: (sink: ByteSink) => unknown,
+): string | undefined {
+  const chunks: Buffer[] = [];
+  let binary = false;
+  write({
+    write(chunk) {
+      if (typeof chunk !== 'string') binary = true;
+      chunks.push(Buffer.from(chunk));
+    },
+  });
+  return binary ? undefined : Buffer.concat(chunks).toString();
+}
+
+function ruleLabel(rule: RedactionRule): string {
+  const pattern =
+    rule.pattern === undefined ? '' : ` matching /${rule.pattern}/`;
+  return `${rule.action} ${rule.field}${pattern}`;
+}
+
+/**
+ * What `--redact` takes out of an export, rule by rule, followed by the
+ * diff from the unredacted export to the redacted one. `change` is
+ * undefined when the two are equal or the format is binary.
+ */
+export function formatRedactionPreview(
+  preview: RedactionPreview,
+  change: AuditChange | undefined,
+  binary = false,
+): string {
+  const lines = [
+    chalk.bold(`Redaction preview: ${preview.profile}`) +
+      chalk.dim(
+        ` (${preview.redactedEntities} of ${preview.entities} entities redacted)`,
+      ),
+    '',
+  ];
+  for (const summary of preview.rules) {
+    const counts = `${summary.entities} entities, ${summary.values} values`;
+    lines.push(
+      `  ${summary.index + 1}. ${ruleLabel(summary.rule)} ${chalk.cyan(counts)}`,
+    );
+    if (summary.paths.length > 0) {
+      lines.push(chalk.dim(`     ${summary.paths.join(', ')}`));
+    }
+  }
+  lines.push('');
+  if (binary) {
+    lines.push(
+      chalk.dim('Binary format: no diff against the unredacted export.'),
+    );
+  } else if (!change?.diff) {
+    lines.push('The redacted export is identical to the unredacted one.');
+  } else {
+    lines.push(chalk.bold('Diff from the unredacted export:'));
+    for (const line of change.diff.split('\n')) {
+      lines.push(`    ${colorDiffLine(line)}`);
+    }
+  }
+  lines.push('');
+  lines.push(chalk.dim('Preview only: nothing was written.'));
+  return lines.join('\n');
+}
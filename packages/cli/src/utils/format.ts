@@ -9,32 +9,35 @@
  *   business_goal: Present code graph data in human-readable formats
  *   domain: cli
  */
-import type { StoredEntity } from '@know-graph/core';
+import chalk from 'chalk';
+import { groupByView, stableStringify, viewTable } from '@know-graph/core';
+import type {
+  StoredEntity,
+  ValidationSeverity,
+  ViewProfile,
+} from '@know-graph/core';
 
 export function truncate(str: string, maxLen: number): string {
   if (str.length <= maxLen) return str;
   return `${str.slice(0, maxLen - 3)}...`;
 }
 
+/** The colored `[ERROR]`, `[WARN]`, or `[INFO]` label for an issue. */
+export function formatSeverity(severity: ValidationSeverity): string {
+  if (severity === 'error') return chalk.red('[ERROR]');
+  if (severity === 'warning') return chalk.yellow('[WARN]');
+  return chalk.blue('[INFO]');
+}
+
 function padRight(str: string, len: number): string {
   if (str.length >= len) return str;
   return str + ' '.repeat(len - str.length);
 }
 
-export function formatTable(entities: readonly StoredEntity[]): string {
-  if (entities.length === 0) {
-    return 'No results found.';
-  }
-
-  const headers = ['Name', 'Type', 'Owner', 'File', 'Description'];
-  const rows = entities.map((e) => [
-    truncate(e.name, 30),
-    e.entityType,
-    e.owner ?? '-',
-    truncate(e.filePath, 40),
-    truncate(e.description, 50),
-  ]);
-
+function renderTable(
+  headers: readonly string[],
+  rows: readonly (readonly string[])[],
+): string {
   const colWidths = headers.map((h, i) => {
     const maxData = rows.reduce((max, row) => Math.max(max, row[i].length), 0);
     return Math.max(h.length, maxData);
@@ -51,6 +54,71 @@ export function formatTable(entities: readonly StoredEntity[]): string {
   return [headerLine, separator, ...dataLines].join('\n');
 }
 
+/** The columns `formatTable` shows, with nothing cut. */
+export function entityTable(entities: readonly StoredEntity[]): {
+  readonly headers: readonly string[];
+  readonly rows: readonly (readonly string[])[];
+} {
+  return {
+    headers: ['Name', 'Type', 'Owner', 'File', 'Description'],
+    rows: entities.map((e) => [
+      e.name,
+      e.entityType,
+      e.owner ?? '-',
+      e.filePath,
+      e.description,
+    ]),
+  };
+}
+
+export function formatTable(entities: readonly StoredEntity[]): string {
+  if (entities.length === 0) {
+    return 'No results found.';
+  }
+
+  const { headers, rows } = entityTable(entities);
+  return renderTable(
+    headers,
+    rows.map(([name, type, owner, file, description]) => [
+      truncate(name, 30),
+      type,
+      owner,
+      truncate(file, 40),
+      truncate(description, 50),
+    ]),
+  );
+}
+
+/**
+ * `entities` in the columns of `view`, with a table under a heading for
+ * each group when the view groups them.
+ */
+export function formatViewTable(
+  view: ViewProfile,
+  entities: readonly StoredEntity[],
+): string {
+  if (entities.length === 0) {
+    return 'No results found.';
+  }
+
+  return groupByView(view, entities)
+    .map((group) => {
+      const { headers, rows } = viewTable(view, group.entities);
+      const table = renderTable(
+        headers,
+        rows.map((row) => row.map((cell) => truncate(cell, 50))),
+      );
+      if (view.groupBy === undefined) return table;
+      const heading = group.key || `No ${view.groupBy}`;
+      return `${chalk.bold(heading)}\n${table}`;
+    })
+    .join('\n\n');
+}
+
+/**
+ * Serialize with sorted keys and normalized floats so identical inputs
+ * produce byte-identical JSON.
+ */
 export function formatJson(data: unknown, pretty: boolean): string {
-  return pretty ? JSON.stringify(data, null, 2) : JSON.stringify(data);
+  return stableStringify(data, pretty);
 }
@@ -0,0 +1,67 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Fuzzy subsequence matching and ranking for interactive search
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, search, fuzzy, tui]
+ * context:
+ *   business_goal: Find an entity from a few typed characters without knowing its exact name
+ *   domain: cli
+ */
+
+function isBoundary(text: string, index: number): boolean {
+  if (index === 0) return true;
+  const previous = text[index - 1];
+  if (/[^a-zA-Z0-9]/.test(previous)) return true;
+  // camelCase humps count as word starts
+  return /[a-z]/.test(previous) && /[A-Z]/.test(text[index]);
+}
+
+/**
+ * How well `query` matches `text` as a case-insensitive subsequence, or
+ * null when it does not. Consecutive characters and matches at word starts
+ * score higher; characters skipped between matches cost a little.
+ */
+export function fuzzyScore(query: string, text: string): number | null {
+  if (query.length === 0) return 0;
+  const lowerQuery = query.toLowerCase();
+  const lowerText = text.toLowerCase();
+  let score = 0;
+  let last = -1;
+  for (const char of lowerQuery) {
+    const index = lowerText.indexOf(char, last + 1);
+    if (index < 0) return null;
+    score += 1;
+    if (index === last + 1) score += 2;
+    if (isBoundary(text, index)) score += 3;
+    if (last >= 0) score -= Math.min(index - last - 1, 3) * 0.1;
+    last = index;
+  }
+  return score;
+}
+
+/**
+ * The items whose best field matches `query`, best first; ties keep their
+ * order. An empty query keeps every item.
+ */
+export function fuzzyFilter<T>(
+  items: readonly T[],
+  query: string,
+  fields: (item: T) => readonly string[],
+): readonly T[] {
+  if (query.trim().length === 0) return items;
+  const scored: { readonly item: T; readonly score: number }[] = [];
+  for (const item of items) {
+    let best: number | null = null;
+    for (const field of fields(item)) {
+      const score = fuzzyScore(query.trim(), field);
+      if (score !== null && (best === null || score > best)) best = score;
+    }
+    if (best !== null) scored.push({ item, score: best });
+  }
+  return scored
+    .map((entry, index) => ({ ...entry, index }))
+    .sort((a, b) => b.score - a.score || a.index - b.index)
+    .map((entry) => entry.item);
+}
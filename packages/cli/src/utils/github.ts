@@ -0,0 +1,61 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Formats GitHub Actions workflow commands and writes job step summaries
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, github, ci, annotations]
+ * context:
+ *   business_goal: Show annotation problems inline on pull request diffs without a separate action
+ *   domain: cli
+ */
+import { appendFileSync } from 'node:fs';
+
+export type AnnotationLevel = 'error' | 'warning' | 'notice';
+
+/** One `::error` / `::warning` / `::notice` workflow command. */
+export interface WorkflowAnnotation {
+  readonly level: AnnotationLevel;
+  /** Path relative to the repository root, so GitHub can place it. */
+  readonly file: string;
+  readonly line: number;
+  readonly title: string;
+  readonly message: string;
+}
+
+function escapeData(value: string): string {
+  return value
+    .replace(/%/g, '%25')
+    .replace(/\r/g, '%0D')
+    .replace(/\n/g, '%0A');
+}
+
+function escapeProperty(value: string): string {
+  return escapeData(value).replace(/:/g, '%3A').replace(/,/g, '%2C');
+}
+
+/** The workflow command line for an annotation, escaped as GitHub expects. */
+export function formatWorkflowAnnotation(
+  annotation: WorkflowAnnotation,
+): string {
+  const properties = [
+    `file=${escapeProperty(annotation.file)}`,
+    `line=${annotation.line}`,
+    `title=${escapeProperty(annotation.title)}`,
+  ].join(',');
+  return `::${annotation.level} ${properties}::${escapeData(annotation.message)}`;
+}
+
+/**
+ * Append `markdown` to the job summary when `GITHUB_STEP_SUMMARY` is set.
+ * Returns whether a summary was written.
+ */
+export function writeStepSummary(
+  markdown: string,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): boolean {
+  const path = env.GITHUB_STEP_SUMMARY;
+  if (!path) return false;
+  appendFileSync(path, markdown.endsWith('\n') ? markdown : `${markdown}\n`);
+  return true;
+}
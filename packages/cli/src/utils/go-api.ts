@@ -0,0 +1,55 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads and writes recorded Go API surfaces that check and go-api compare the current exports against
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, go, api, stability, baseline]
+ * context:
+ *   business_goal: Keep the last released Go API in the repository so breaking changes to stable packages show up in review
+ *   domain: cli
+ */
+import { mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { GO_API_VERSION, createKnowgraphError } from '@know-graph/core';
+import type { GoApiSurface, GoPackageApi } from '@know-graph/core';
+
+function isPackageApi(value: unknown): value is GoPackageApi {
+  if (typeof value !== 'object' || value === null) return false;
+  const pkg = value as Record<string, unknown>;
+  return (
+    typeof pkg.importPath === 'string' &&
+    typeof pkg.dir === 'string' &&
+    typeof pkg.stable === 'boolean' &&
+    Array.isArray(pkg.symbols) &&
+    pkg.symbols.every(
+      (symbol: unknown) =>
+        typeof symbol === 'object' &&
+        symbol !== null &&
+        typeof (symbol as Record<string, unknown>).name === 'string' &&
+        typeof (symbol as Record<string, unknown>).signature === 'string',
+    )
+  );
+}
+
+/** Read a recorded surface, throwing when it is missing or malformed. */
+export function readGoApiSurface(path: string): GoApiSurface {
+  const parsed: unknown = JSON.parse(readFileSync(path, 'utf-8'));
+  const surface = parsed as Partial<GoApiSurface> | null;
+  if (
+    surface?.version !== GO_API_VERSION ||
+    !Array.isArray(surface.packages) ||
+    !surface.packages.every(isPackageApi)
+  ) {
+    throw createKnowgraphError(
+      'parse',
+      `${path} is not a version ${GO_API_VERSION} knowgraph Go API file`,
+    );
+  }
+  return { version: surface.version, packages: surface.packages };
+}
+
+export function writeGoApiSurface(path: string, surface: GoApiSurface): void {
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, `${JSON.stringify(surface, null, 2)}\n`);
+}
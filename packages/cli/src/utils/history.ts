@@ -0,0 +1,347 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Records scan metrics after each index run and alerts configured sinks about anomalies
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, history, anomalies, alerts]
+ * context:
+ *   business_goal: Tell the people who care about an anomalous scan as soon as the index run finds it
+ *   domain: cli
+ */
+import { readFileSync } from 'node:fs';
+import { dirname, resolve } from 'node:path';
+import chalk from 'chalk';
+import {
+  appendScanMetrics,
+  buildDependencyGraph,
+  collectScanMetrics,
+  createEmailAlertSink,
+  createFileAlertSink,
+  createKnowgraphError,
+  createQueryEngine,
+  createSlackAlertSink,
+  createTeamsAlertSink,
+  createWebhookAlertSink,
+  detectAnomalies,
+  followRenames,
+  readScanHistory,
+} from '@know-graph/core';
+import type {
+  AlertMessage,
+  AlertSink,
+  AlertSinkConfig,
+  AnomalyReport,
+  AnomalyThresholds,
+  DeliveryClient,
+  DeliveryOutcome,
+  HistoryConfig,
+  RetentionPolicy,
+  ScanMetrics,
+} from '@know-graph/core';
+import { openDatabase } from './db.js';
+import { createManifestDeliveryClient } from './delivery.js';
+import { getLogger } from './logging.js';
+import {
+  readDeliveryConfig,
+  readHistoryConfig,
+  readScorecardConfig,
+} from './manifest.js';
+
+const LISTED_ENTITIES = 5;
+
+/** The scan history for the manifest at `configPath`, resolved next to it. */
+export function historyPath(configPath: string): string {
+  return resolve(dirname(configPath), readHistoryConfig(configPath).path);
+}
+
+/** The recorded scorecards for the manifest at `configPath`. */
+export function scorecardHistoryPath(configPath: string): string {
+  return resolve(dirname(configPath), readScorecardConfig(configPath).path);
+}
+
+export function toAnomalyThresholds(
+  config: HistoryConfig,
+): AnomalyThresholds {
+  const { thresholds } = config;
+  return {
+    dependencySpikePercent: thresholds.dependency_spike_percent,
+    ownershipChurnPercent: thresholds.ownership_churn_percent,
+    statusDowngrades: thresholds.status_downgrades,
+    coverageDropPoints: thresholds.coverage_drop_points,
+  };
+}
+
+export function toRetentionPolicy(config: HistoryConfig): RetentionPolicy {
+  return {
+    dailyDays: config.retention.daily_days,
+    weeklyDays: config.retention.weekly_days,
+  };
+}
+
+/**
+ * A webhook sink with its template, read next to the manifest, and its
+ * signing secret. Throws when the template cannot be read or parsed.
+ */
+function createManifestWebhookSink(
+  configPath: string,
+  sink: Extract<AlertSinkConfig, { type: 'webhook' }>,
+  url: string,
+  secret: string | undefined,
+  client: DeliveryClient,
+): AlertSink {
+  const templatePath =
+    sink.template_file === undefined
+      ? undefined
+      : resolve(dirname(configPath), sink.template_file);
+  return createWebhookAlertSink(url, client, {
+    template:
+      templatePath === undefined
+        ? sink.template
+        : readFileSync(templatePath, 'utf-8'),
+    templateName: sink.template_file,
+    secret,
+  });
+}
+
+/**
+ * The sink `sink` configures. Webhook URLs, signing secrets, and SMTP
+ * logins come from variables named in the manifest so secrets stay out of
+ * it. File sinks and templates resolve against the manifest's directory.
+ * Webhook, Slack, and Teams sinks deliver through `client`; email sinks
+ * retry as the manifest's `delivery` section says. Throws a usage error
+ * when a variable is unset, and when a webhook template cannot be read
+ * or parsed.
+ */
+export function createAlertSink(
+  configPath: string,
+  sink: AlertSinkConfig,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+  client: DeliveryClient = createManifestDeliveryClient(configPath),
+): AlertSink {
+  if (sink.type === 'file') {
+    return createFileAlertSink(resolve(dirname(configPath), sink.path));
+  }
+  if (sink.type === 'email') {
+    const unset = [sink.username_env, sink.password_env].find(
+      (name) => name !== undefined && !env[name],
+    );
+    if (unset) throw createKnowgraphError('usage', `${unset} is not set`);
+    const delivery = readDeliveryConfig(configPath);
+    return createEmailAlertSink({
+      host: sink.host,
+      port: sink.port,
+      secure: sink.secure,
+      username: sink.username_env && env[sink.username_env],
+      password: sink.password_env && env[sink.password_env],
+      from: sink.from,
+      to: sink.to,
+      retry: {
+        attempts: delivery.attempts,
+        initialDelayMs: delivery.backoff_ms,
+        maxDelayMs: delivery.max_backoff_ms,
+      },
+    });
+  }
+  const url = sink.url ?? (sink.url_env ? env[sink.url_env] : undefined);
+  if (!url) throw createKnowgraphError('usage', `${sink.url_env} is not set`);
+  if (sink.type === 'slack') return createSlackAlertSink(url, client);
+  if (sink.type === 'teams') return createTeamsAlertSink(url, client);
+  const secret = sink.secret_env ? env[sink.secret_env] : undefined;
+  if (sink.secret_env && !secret) {
+    throw createKnowgraphError('usage', `${sink.secret_env} is not set`);
+  }
+  try {
+    return createManifestWebhookSink(configPath, sink, url, secret, client);
+  } catch (err) {
+    throw createKnowgraphError('usage', describeError(err));
+  }
+}
+
+/**
+ * The configured sinks; one whose variable is unset, or whose template is
+ * broken, is skipped with a warning.
+ */
+export function createAlertSinks(
+  configPath: string,
+  config: HistoryConfig,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+  client: DeliveryClient = createManifestDeliveryClient(configPath),
+): readonly AlertSink[] {
+  return config.sinks.flatMap((sink): AlertSink[] => {
+    try {
+      return [createAlertSink(configPath, sink, env, client)];
+    } catch (err) {
+      getLogger().warn(
+        `Skipping ${sink.type} alert sink: ${describeError(err)}`,
+        { sink: sink.type },
+      );
+      return [];
+    }
+  });
+}
+
+export function formatAnomalyReport(report: AnomalyReport): string {
+  return report.anomalies
+    .map((anomaly) => {
+      const names = anomaly.entities.slice(0, LISTED_ENTITIES).join(', ');
+      const hidden = anomaly.entities.length - LISTED_ENTITIES;
+      const more = hidden > 0 ? chalk.dim(` and ${hidden} more`) : '';
+      const list = names ? `: ${names}${more}` : '';
+      return `  ${chalk.yellow(anomaly.message)}${list}`;
+    })
+    .join('\n');
+}
+
+/**
+ * Measure the index at `dbPath`, opened with the manifest at `configPath`,
+ * and key the `previous` scan's entities by the ids they were renamed to
+ * since.
+ */
+function measureIndex(
+  dbPath: string,
+  configPath: string,
+  files: number,
+  previous: ScanMetrics | undefined,
+): { readonly current: ScanMetrics; readonly previous?: ScanMetrics } {
+  const dbManager = openDatabase(dbPath, configPath, { readOnly: true });
+  try {
+    const entities = createQueryEngine(dbManager).getAll();
+    return {
+      current: collectScanMetrics(
+        entities,
+        buildDependencyGraph(entities),
+        files,
+      ),
+      previous:
+        previous &&
+        followRenames(previous, (id) => dbManager.resolveEntityId(id)),
+    };
+  } finally {
+    dbManager.close();
+  }
+}
+
+function describeError(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+/** Send what earlier runs could not, before anything new joins the queue. */
+async function flushOutbox(client: DeliveryClient): Promise<void> {
+  const logger = getLogger();
+  try {
+    const { sent, dropped } = await client.flush();
+    if (sent > 0) logger.info(`Sent ${sent} queued alert(s)`, { sent });
+    for (const delivery of dropped) {
+      logger.warn(
+        `Dropped a queued ${delivery.request.sink} alert: ${delivery.error}`,
+        { sink: delivery.request.sink },
+      );
+    }
+  } catch (err) {
+    logger.warn(`Could not send queued alerts: ${describeError(err)}`);
+  }
+}
+
+/**
+ * Append the metrics of the index at `dbPath` to the scan history, then
+ * print and send any anomalies since the previous scan. The index itself
+ * succeeded, so problems here are warnings rather than failures. `log`
+ * prints the anomalies, for callers that must keep stdout clear.
+ */
+export async function recordScan(
+  configPath: string,
+  dbPath: string,
+  files: number,
+  log: (line: string) => void = console.log,
+): Promise<void> {
+  const config = readHistoryConfig(configPath);
+  if (!config.enabled) return;
+
+  let previous: ScanMetrics | undefined;
+  let current: ScanMetrics;
+  try {
+    const path = historyPath(configPath);
+    ({ current, previous } = measureIndex(
+      dbPath,
+      configPath,
+      files,
+      readScanHistory(path).at(-1),
+    ));
+    appendScanMetrics(path, current);
+  } catch (err) {
+    getLogger().warn(`Could not record scan history: ${describeError(err)}`);
+    return;
+  }
+  const client = createManifestDeliveryClient(configPath);
+  await flushOutbox(client);
+  if (!previous) return;
+
+  const report = detectAnomalies(
+    previous,
+    current,
+    toAnomalyThresholds(config),
+  );
+  if (report.anomalies.length === 0) return;
+
+  log('');
+  log(chalk.bold('Anomalies since the last scan:'));
+  log(formatAnomalyReport(report));
+  const sinks = createAlertSinks(configPath, config, process.env, client);
+  await deliverToSinks(sinks, 'anomaly alert', (sink) => sink.send(report));
+}
+
+/**
+ * Deliver through every sink at once, warning about those that failed or
+ * queued the alert, since what was alerted about has already happened.
+ */
+async function deliverToSinks(
+  sinks: readonly AlertSink[],
+  what: string,
+  deliver: (sink: AlertSink) => Promise<DeliveryOutcome>,
+): Promise<void> {
+  const results = await Promise.allSettled(sinks.map(deliver));
+  results.forEach((result, index) => {
+    const sink = sinks[index].name;
+    if (result.status === 'rejected') {
+      getLogger().warn(
+        `Could not send ${what}: ${describeError(result.reason)}`,
+        { sink },
+      );
+    } else if (result.value === 'queued') {
+      getLogger().warn(
+        `Could not reach the ${sink} alert sink; queued the alert for the next scan`,
+        { sink },
+      );
+    }
+  });
+}
+
+/**
+ * Send `message` through the manifest's `history.sinks` of the given
+ * types, after what earlier runs queued. Failures are warnings, as is
+ * naming a type no sink has.
+ */
+export async function notifyAlertSinks(
+  configPath: string,
+  types: readonly AlertSinkConfig['type'][],
+  message: AlertMessage,
+): Promise<void> {
+  const config = readHistoryConfig(configPath);
+  for (const type of types) {
+    if (!config.sinks.some((sink) => sink.type === type)) {
+      getLogger().warn(`No ${type} sink in history.sinks to notify`, {
+        sink: type,
+      });
+    }
+  }
+  const client = createManifestDeliveryClient(configPath);
+  await flushOutbox(client);
+  const sinks = createAlertSinks(
+    configPath,
+    { ...config, sinks: config.sinks.filter((s) => types.includes(s.type)) },
+    process.env,
+    client,
+  );
+  await deliverToSinks(sinks, 'notification', (sink) => sink.notify(message));
+}
@@ -1,2 +1,4 @@
 export { formatTable, formatJson, truncate } from './format.js';
 export { detectLanguages, suggestFiles } from './detect.js';
+export { loadEntities } from './db.js';
+export { readDefaultLocale } from './manifest.js';
@@ -0,0 +1,317 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Indexes a repository into a database with the manifest's plugins, enrichers, and settings
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, index, enrichers, plugins]
+ * context:
+ *   business_goal: Build the index the same way whether a person or a schedule starts the scan
+ *   domain: cli
+ */
+import { dirname, join } from 'node:path';
+import { performance } from 'node:perf_hooks';
+import {
+  createDefaultRegistry,
+  createCallEnricher,
+  createExternalApiEnricher,
+  createGitEnricher,
+  createRouteEnricher,
+  createIndexer,
+  createParserRegistryAdapter,
+  createPluginEnricher,
+  createPhaseTimer,
+  createPluginParser,
+  createTreeSitterParser,
+  loadTreeSitterGrammar,
+  planEnrichers,
+  readGitFileLineage,
+  readGitHead,
+  rebuildDependents,
+  recordIndexSource,
+  recordPhaseTimings,
+  runEnrichers,
+  timePhase,
+} from '@know-graph/core';
+import type {
+  EnricherRun,
+  FileLimits,
+  IndexProgress,
+  IndexResult,
+  IndexSource,
+  ParserRegistry,
+  ParserRegistryInterface,
+  PhaseTimer,
+  ScanScope,
+  WalkOptions,
+} from '@know-graph/core';
+import {
+  parseMaxFileBytes,
+  parseTimeout,
+  readAnnotationLayers,
+  readConfigHash,
+  readDefaultLocale,
+  readEnrichers,
+  readEnrichmentRateLimits,
+  readFileLimits,
+  readGraphNames,
+  readPlugins,
+  readTimeouts,
+  readTreeSitterGrammars,
+  readTombstoneRetentionDays,
+  readWalkOptions,
+} from './manifest.js';
+import { getTelemetry } from './telemetry.js';
+import { openDatabase } from './db.js';
+
+const DEFAULT_EXCLUDE = ['node_modules', '.git', 'dist', 'build'];
+
+/** Index meta key of the commit the last scan saw checked out. */
+const GIT_HEAD_KEY = 'git_head';
+
+export interface IndexSettings {
+  /** Comma-separated patterns to exclude instead of the defaults. */
+  readonly exclude?: string;
+  readonly incremental: boolean;
+  /** Overrides `i18n.default_locale`. */
+  readonly locale?: string;
+  /** Overrides `timeouts.scan_ms`. */
+  readonly timeout?: string;
+  /** The repository and commit a git URL target was checked out at. */
+  readonly source?: IndexSource;
+  /** Index only these paths and tags; see `IndexerOptions.scopes`. */
+  readonly scopes?: readonly ScanScope[];
+  /** Overrides `index.follow_symlinks`. */
+  readonly followSymlinks?: boolean;
+  /** Overrides `index.submodules`. */
+  readonly submodules?: boolean;
+  /** Overrides `index.vendor`. */
+  readonly vendor?: boolean;
+  /** Overrides `index.max_file_bytes`. */
+  readonly maxFileBytes?: string;
+  /**
+   * Run only these enrichers, in this order, each with its settings from
+   * the manifest's `enrichers` list if it is there.
+   */
+  readonly enrichers?: readonly string[];
+  readonly onProgress?: (progress: IndexProgress) => void;
+  /** Called once files are indexed, before enrichers run. */
+  readonly onEnrich?: () => void;
+  readonly profiler?: PhaseTimer;
+}
+
+export interface IndexSetup {
+  readonly parserRegistry: ParserRegistry;
+  readonly exclude: readonly string[];
+  readonly defaultLocale: string;
+  /** How the scan treats symlinks, submodules, and vendored code. */
+  readonly walk: WalkOptions;
+  /** The size, line length, and parse time past which files are skipped. */
+  readonly limits: FileLimits;
+  /** What the index records to tell whether its settings changed. */
+  readonly configHash: string;
+}
+
+export interface IndexRun {
+  readonly result: IndexResult;
+  readonly runs: readonly EnricherRun[];
+}
+
+/**
+ * The built-in parsers plus the manifest's tree-sitter grammars and those
+ * of its plugins. Throws a `usage` error when a grammar is not installed.
+ */
+export function readParsers(configPath: string): ParserRegistryInterface {
+  const registry = createDefaultRegistry();
+  for (const grammar of readTreeSitterGrammars(configPath)) {
+    registry.register(
+      createTreeSitterParser(
+        loadTreeSitterGrammar(grammar, dirname(configPath)),
+      ),
+    );
+  }
+  for (const plugin of readPlugins(configPath)) {
+    if (plugin.extensions) registry.register(createPluginParser(plugin));
+  }
+  return registry;
+}
+
+/**
+ * The parsers, exclude patterns, locale, walk, and file limits an index of
+ * `rootDir` uses, from its `.knowgraph.yml` and its plugins unless
+ * `settings` overrides them. Throws on an invalid `maxFileBytes`.
+ */
+export function readIndexSetup(
+  rootDir: string,
+  settings: Pick<
+    IndexSettings,
+    | 'exclude'
+    | 'locale'
+    | 'followSymlinks'
+    | 'submodules'
+    | 'vendor'
+    | 'maxFileBytes'
+  >,
+): IndexSetup {
+  const configPath = join(rootDir, '.knowgraph.yml');
+  const exclude = settings.exclude
+    ? settings.exclude.split(',').map((p) => p.trim())
+    : DEFAULT_EXCLUDE;
+  const defaultLocale = settings.locale ?? readDefaultLocale(configPath);
+  const configured = readWalkOptions(configPath);
+  const walk: WalkOptions = {
+    followSymlinks: settings.followSymlinks ?? configured.followSymlinks,
+    submodules: settings.submodules ?? configured.submodules,
+    vendor: settings.vendor ?? configured.vendor,
+  };
+  const manifestLimits = readFileLimits(configPath);
+  const limits: FileLimits = {
+    ...manifestLimits,
+    maxFileBytes: parseMaxFileBytes(
+      settings.maxFileBytes,
+      manifestLimits.maxFileBytes,
+    ),
+  };
+  return {
+    parserRegistry: createParserRegistryAdapter(readParsers(configPath)),
+    exclude,
+    defaultLocale,
+    walk,
+    limits,
+    configHash: readConfigHash(configPath, {
+      exclude,
+      defaultLocale,
+      walk,
+      limits,
+    }),
+  };
+}
+
+/**
+ * Index `rootDir` into `dbPath` using the plugins, enrichers, locale, and
+ * timeout from the repository's `.knowgraph.yml`, then run the enrichers
+ * and rebuild the dependents read model (see `rebuildDependents`) with the
+ * manifest's aliases and renames. The index records `settings.source`, or that it came from a local
+ * directory. Traces the run, its phases, and its enrichers once telemetry
+ * has been started. Throws when indexing fails or is cancelled.
+ */
+export function indexInto(
+  rootDir: string,
+  dbPath: string,
+  settings: IndexSettings,
+): IndexRun {
+  const telemetry = getTelemetry();
+  if (!telemetry) return indexWith(rootDir, dbPath, settings);
+  // Phase timings go out as metrics even when no profile was asked for
+  const profiler = settings.profiler ?? createPhaseTimer();
+  const started = performance.now();
+  return telemetry.trace(
+    'knowgraph.index',
+    { attributes: { 'knowgraph.incremental': settings.incremental } },
+    (span) => {
+      let outcome = 'error';
+      try {
+        const run = indexWith(rootDir, dbPath, { ...settings, profiler });
+        outcome = 'ok';
+        span.setAttributes({
+          'knowgraph.files': run.result.totalFiles,
+          'knowgraph.entities': run.result.totalEntities,
+          'knowgraph.relationships': run.result.totalRelationships,
+          'knowgraph.errors': run.result.errors.length,
+        });
+        telemetry.count('knowgraph.index.files', run.result.totalFiles);
+        return run;
+      } finally {
+        recordPhaseTimings(telemetry, profiler.timings(), span);
+        telemetry.record(
+          'knowgraph.index.duration',
+          performance.now() - started,
+          'ms',
+          { 'knowgraph.outcome': outcome },
+        );
+      }
+    },
+  );
+}
+
+function indexWith(
+  rootDir: string,
+  dbPath: string,
+  settings: IndexSettings,
+): IndexRun {
+  const configPath = join(rootDir, '.knowgraph.yml');
+  const plugins = readPlugins(configPath);
+  const pluginEnrichers = plugins
+    .filter((plugin) => plugin.enrich)
+    .map(createPluginEnricher);
+  const rateLimits = readEnrichmentRateLimits(configPath);
+  // Without an enrichers list, only plugin enrichers run, in plugin order.
+  const configured =
+    readEnrichers(configPath) ??
+    pluginEnrichers.map((enricher) => ({ name: enricher.name }));
+  const enrichers = planEnrichers(
+    [
+      createGitEnricher(),
+      createCallEnricher(),
+      createRouteEnricher(),
+      createExternalApiEnricher(),
+      ...pluginEnrichers,
+    ],
+    settings.enrichers?.map(
+      (name) => configured.find((step) => step.name === name) ?? { name },
+    ) ?? configured,
+    rateLimits,
+  );
+  const setup = readIndexSetup(rootDir, settings);
+  const dbManager = openDatabase(dbPath, configPath);
+  dbManager.initialize();
+
+  const indexer = createIndexer(setup.parserRegistry, dbManager);
+
+  const { profiler } = settings;
+  try {
+    // Files git renamed, split, or merged since the last scan let their
+    // entities keep history
+    const since = dbManager.getMeta(GIT_HEAD_KEY);
+    const result = indexer.index({
+      rootDir,
+      exclude: setup.exclude,
+      incremental: settings.incremental,
+      defaultLocale: setup.defaultLocale,
+      scopes: settings.scopes,
+      ...setup.walk,
+      ...setup.limits,
+      annotations: readAnnotationLayers(configPath),
+      configHash: setup.configHash,
+      timeoutMs: parseTimeout(
+        settings.timeout,
+        readTimeouts(configPath).scan_ms,
+      ),
+      fileRenames: since ? readGitFileLineage(rootDir, since) : {},
+      tombstoneRetentionDays: readTombstoneRetentionDays(configPath),
+      onProgress: settings.onProgress,
+      profiler,
+    });
+    dbManager.setMeta(GIT_HEAD_KEY, readGitHead(rootDir) ?? '');
+    recordIndexSource(dbManager, settings.source);
+    let runs: readonly EnricherRun[] = [];
+    if (enrichers.length > 0) {
+      settings.onEnrich?.();
+      runs = runEnrichers(enrichers, {
+        rootDir,
+        dbManager,
+        profiler,
+        rateLimits,
+        telemetry: getTelemetry(),
+        cacheDir: join(dirname(dbPath), 'cache'),
+      });
+    }
+    // Last, as enrichers can change the dependencies the graph is built from
+    timePhase(profiler, 'build', () =>
+      rebuildDependents(dbManager, readGraphNames(configPath)),
+    );
+    return { result, runs };
+  } finally {
+    dbManager.close();
+  }
+}
@@ -0,0 +1,72 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: The CLI's shared logger, configured from --log-level and --log-format before commands run
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, logging, observability, automation]
+ * context:
+ *   business_goal: Let users pick how much the CLI says and in what format, for every command at once
+ *   domain: cli
+ */
+import chalk from 'chalk';
+import { createLogger, parseLogFormat, parseLogLevel } from '@know-graph/core';
+import type { LogLevel, Logger, LoggerOptions } from '@know-graph/core';
+
+const LEVEL_STYLES: Record<LogLevel, (text: string) => string> = {
+  debug: chalk.dim,
+  info: chalk.dim,
+  warn: chalk.yellow,
+  error: chalk.red,
+};
+
+let settings: LoggerOptions = {};
+let logger = build(settings);
+
+function build(options: LoggerOptions): Logger {
+  return createLogger({
+    colorize: (level, line) => LEVEL_STYLES[level](line),
+    // Through console.error, which leaves stdout to command output
+    write: (line) => console.error(line),
+    ...options,
+  });
+}
+
+function readFlag(argv: readonly string[], flag: string): string | undefined {
+  const index = argv.indexOf(flag);
+  if (index >= 0) return argv[index + 1];
+  return argv.find((arg) => arg.startsWith(`${flag}=`))?.slice(flag.length + 1);
+}
+
+/**
+ * The level and format from `--log-level` and `--log-format`, then
+ * `KNOWGRAPH_LOG_LEVEL` and `KNOWGRAPH_LOG_FORMAT`. Read from argv before
+ * parsing, like the error format, so every record uses them. Throws a
+ * usage error on an unknown value.
+ */
+export function resolveLogSettings(
+  argv: readonly string[] = process.argv,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): LoggerOptions {
+  const level = readFlag(argv, '--log-level') ?? env.KNOWGRAPH_LOG_LEVEL;
+  const format = readFlag(argv, '--log-format') ?? env.KNOWGRAPH_LOG_FORMAT;
+  return {
+    level: level ? parseLogLevel(level) : 'info',
+    format: format ? parseLogFormat(format) : 'text',
+  };
+}
+
+/** Merge `options` into the settings of the shared logger. */
+export function configureLogging(options: LoggerOptions): void {
+  settings = { ...settings, ...options };
+  logger = build(settings);
+}
+
+export function getLogger(): Logger {
+  return logger;
+}
+
+/** Whether records are JSON lines, which spinners would corrupt. */
+export function isStructuredLogging(): boolean {
+  return settings.format === 'json';
+}
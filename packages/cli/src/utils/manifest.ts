@@ -0,0 +1,707 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads project settings from .knowgraph.yml for commands that honour them
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, config, manifest, i18n]
+ * context:
+ *   business_goal: Apply team-wide configuration such as the default locale without extra flags
+ *   domain: cli
+ */
+import { execFileSync } from 'node:child_process';
+import { existsSync, readFileSync } from 'node:fs';
+import { dirname, resolve } from 'node:path';
+import { parse as parseYaml } from 'yaml';
+import {
+  AuditConfigSchema,
+  ConformanceConfigSchema,
+  DEFAULT_LOCALE,
+  DEFAULT_MATURITY_RUBRIC,
+  DeliveryConfigSchema,
+  DeploymentsConfigSchema,
+  HistoryConfigSchema,
+  IncidentsConfigSchema,
+  OwnershipConfigSchema,
+  ManifestSchema,
+  ScorecardConfigSchema,
+  TelemetryConfigSchema,
+  LlmConfigSchema,
+  WarehouseConfigSchema,
+  createKnowgraphError,
+  hashConfig,
+  parseEncryptionKey,
+  toViewProfile,
+} from '@know-graph/core';
+import type {
+  AnnotationLayerOptions,
+  AuditConfig,
+  ConformanceConfig,
+  ConfluenceConfig,
+  CycleBudgetOptions,
+  FreshnessOptions,
+  DeliveryConfig,
+  DeploymentsConfig,
+  DescriptionThresholds,
+  EdgeRule,
+  EncryptionOptions,
+  EnricherStep,
+  EnrichmentRateLimit,
+  FileLimits,
+  GraphConstraintOptions,
+  GraphNameOptions,
+  HistoryConfig,
+  IncidentsConfig,
+  OwnershipConfig,
+  LlmConfig,
+  Manifest,
+  MaturityRubric,
+  ModuleTemplateConfig,
+  NotionDatabaseConfig,
+  PipelineStepConfig,
+  PluginConfig,
+  PruneOptions,
+  RedactionProfile,
+  RuleSeverities,
+  RuntimeConfig,
+  SavedQueryConfig,
+  ScorecardConfig,
+  ServeConfig,
+  TelemetryConfig,
+  TimeoutsConfig,
+  TreeSitterGrammarConfig,
+  ViewProfile,
+  WalkOptions,
+  WarehouseConfig,
+} from '@know-graph/core';
+
+function readManifest(configPath: string): Manifest | undefined {
+  if (!existsSync(configPath)) return undefined;
+  try {
+    const result = ManifestSchema.safeParse(
+      parseYaml(readFileSync(configPath, 'utf-8')),
+    );
+    return result.success ? result.data : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Like `readManifest`, but throws a `schema` error on an invalid one. An
+ * empty file configures nothing, like a missing one.
+ */
+function readValidManifest(configPath: string): Manifest | undefined {
+  if (!existsSync(configPath)) return undefined;
+  const raw: unknown = parseYaml(readFileSync(configPath, 'utf-8'));
+  if (raw === null || raw === undefined) return undefined;
+  const result = ManifestSchema.safeParse(raw);
+  if (!result.success) {
+    const issue = result.error.issues[0];
+    throw createKnowgraphError(
+      'schema',
+      `Invalid ${configPath}: ${issue?.path.join('.')} ${issue?.message}`,
+    );
+  }
+  return result.data;
+}
+
+/**
+ * The manifest's `i18n.default_locale`, or English when the manifest is
+ * missing, invalid, or does not configure one.
+ */
+export function readDefaultLocale(configPath: string): string {
+  return readManifest(configPath)?.i18n?.default_locale ?? DEFAULT_LOCALE;
+}
+
+/**
+ * The manifest's per-phase `timeouts`, empty when the manifest is missing
+ * or invalid.
+ */
+export function readTimeouts(configPath: string): TimeoutsConfig {
+  return readManifest(configPath)?.timeouts ?? {};
+}
+
+/**
+ * The manifest's `serve` settings, empty when there is no manifest. Unlike
+ * the other readers this throws on an invalid manifest, so a typo cannot
+ * silently turn off the auth it configures.
+ */
+export function readServeConfig(configPath: string): ServeConfig {
+  return readValidManifest(configPath)?.serve ?? {};
+}
+
+/**
+ * The key for encrypting the index and graph snapshots at rest, read from
+ * the variable named by `encryption.key_env` or printed by
+ * `encryption.key_command`, run from the manifest's directory. Without an
+ * `encryption` section files stay plain. Like `readServeConfig` this throws
+ * on an invalid manifest, and it throws when the key cannot be read, so a
+ * misconfiguration never writes the graph unencrypted.
+ */
+export function readEncryptionOptions(
+  configPath: string,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): EncryptionOptions {
+  const encryption = readValidManifest(configPath)?.encryption;
+  if (encryption?.key_env) {
+    const key = env[encryption.key_env];
+    if (!key) {
+      throw createKnowgraphError(
+        'usage',
+        `Environment variable "${encryption.key_env}" for encryption.key_env is not set`,
+      );
+    }
+    return {
+      encryptionKey: parseEncryptionKey(key, `"${encryption.key_env}"`),
+    };
+  }
+  if (encryption?.key_command) {
+    const [command, ...args] = encryption.key_command;
+    let output: string;
+    try {
+      output = execFileSync(command, args, {
+        cwd: dirname(resolve(configPath)),
+        encoding: 'utf-8',
+        stdio: ['ignore', 'pipe', 'inherit'],
+      });
+    } catch (err) {
+      throw createKnowgraphError(
+        'io',
+        `encryption.key_command '${command}' failed`,
+        { cause: err },
+      );
+    }
+    return {
+      encryptionKey: parseEncryptionKey(
+        output,
+        'The output of encryption.key_command',
+      ),
+    };
+  }
+  return {};
+}
+
+/**
+ * The manifest's `redaction.profiles`, with salts read from the variables
+ * named by `salt_env`. Throws when a named variable is unset, since hashes
+ * without the salt would not match earlier exports.
+ */
+export function readRedactionProfiles(
+  configPath: string,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): Readonly<Record<string, RedactionProfile>> {
+  const profiles = readManifest(configPath)?.redaction?.profiles ?? {};
+  return Object.fromEntries(
+    Object.entries(profiles).map(([name, profile]) => {
+      const salt = profile.salt_env ? env[profile.salt_env] : undefined;
+      if (profile.salt_env && !salt) {
+        throw new Error(
+          `Environment variable "${profile.salt_env}" for redaction profile '${name}' is not set`,
+        );
+      }
+      return [name, { name, rules: profile.rules, salt }];
+    }),
+  );
+}
+
+/**
+ * `profile` with its salt read from the variable its `saltEnv` names, for
+ * built-in profiles, which have no manifest entry to give one. Throws when
+ * the variable is unset, since hashes without a salt can be reversed.
+ */
+export function saltRedactionProfile(
+  profile: RedactionProfile,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): RedactionProfile {
+  if (profile.salt || !profile.saltEnv) return profile;
+  const salt = env[profile.saltEnv];
+  if (!salt) {
+    throw createKnowgraphError(
+      'usage',
+      `Environment variable "${profile.saltEnv}" for redaction profile '${profile.name}' is not set`,
+    );
+  }
+  return { ...profile, salt };
+}
+
+/**
+ * The manifest's `audit` settings. Auditing stays on with the default log
+ * path when the manifest is missing, invalid, or leaves it unconfigured.
+ */
+export function readAuditConfig(configPath: string): AuditConfig {
+  return readManifest(configPath)?.audit ?? AuditConfigSchema.parse({});
+}
+
+/**
+ * The manifest's `history` settings. Scans are recorded at the default path
+ * with default thresholds and no sinks when the manifest is missing,
+ * invalid, or leaves it unconfigured.
+ */
+export function readHistoryConfig(configPath: string): HistoryConfig {
+  return readManifest(configPath)?.history ?? HistoryConfigSchema.parse({});
+}
+
+/**
+ * The manifest's `scorecards` settings, or the default history path when
+ * the manifest is missing, invalid, or leaves them unconfigured.
+ */
+export function readScorecardConfig(configPath: string): ScorecardConfig {
+  return (
+    readManifest(configPath)?.scorecards ?? ScorecardConfigSchema.parse({})
+  );
+}
+
+/**
+ * The manifest's `conformance` settings, or the default target file and
+ * history path when the manifest is missing, invalid, or leaves them
+ * unconfigured.
+ */
+export function readConformanceConfig(configPath: string): ConformanceConfig {
+  return (
+    readManifest(configPath)?.conformance ??
+    ConformanceConfigSchema.parse({})
+  );
+}
+
+/**
+ * The manifest's description score thresholds, or none, leaving the
+ * defaults, when the manifest is missing, invalid, or sets no thresholds.
+ */
+export function readDescriptionThresholds(
+  configPath: string,
+): DescriptionThresholds {
+  return readManifest(configPath)?.descriptions?.thresholds ?? {};
+}
+
+/**
+ * The manifest's `delivery` settings, or three tries with the default
+ * backoff and outbox when the manifest is missing, invalid, or leaves it
+ * unconfigured.
+ */
+export function readDeliveryConfig(configPath: string): DeliveryConfig {
+  return readManifest(configPath)?.delivery ?? DeliveryConfigSchema.parse({});
+}
+
+/**
+ * The manifest's `warehouse` settings, or no sinks when the manifest is
+ * missing, invalid, or leaves it unconfigured.
+ */
+export function readWarehouseConfig(configPath: string): WarehouseConfig {
+  return (
+    readManifest(configPath)?.warehouse ?? WarehouseConfigSchema.parse({})
+  );
+}
+
+/**
+ * How the manifest's `index` section has scans treat symlinked
+ * directories, git submodules, and `vendor/`: all skipped when the
+ * manifest is missing, invalid, or leaves them unset.
+ */
+export function readWalkOptions(configPath: string): WalkOptions {
+  const index = readManifest(configPath)?.index;
+  return {
+    followSymlinks: index?.follow_symlinks ?? false,
+    submodules: index?.submodules ?? false,
+    vendor: index?.vendor ?? false,
+  };
+}
+
+/**
+ * The manifest's per-file scan limits from `index`; unset ones take the
+ * indexer's defaults.
+ */
+export function readFileLimits(configPath: string): FileLimits {
+  const index = readManifest(configPath)?.index;
+  return {
+    maxFileBytes: index?.max_file_bytes,
+    maxLineLength: index?.max_line_length,
+    parseTimeoutMs: index?.parse_timeout_ms,
+  };
+}
+
+/**
+ * How many days the manifest's `index` section keeps tombstones of removed
+ * nodes, or undefined for the indexer's default.
+ */
+export function readTombstoneRetentionDays(
+  configPath: string,
+): number | undefined {
+  return readManifest(configPath)?.index?.tombstone_retention_days;
+}
+
+/**
+ * The manifest's `telemetry` settings, or the defaults, which send nothing
+ * without an endpoint, when the manifest is missing, invalid, or leaves
+ * them unconfigured.
+ */
+export function readTelemetryConfig(configPath: string): TelemetryConfig {
+  return (
+    readManifest(configPath)?.telemetry ?? TelemetryConfigSchema.parse({})
+  );
+}
+
+/**
+ * The manifest's `llm` settings, or the defaults, which name no API, when
+ * the manifest is missing, invalid, or leaves them unconfigured.
+ */
+export function readLlmConfig(configPath: string): LlmConfig {
+  return readManifest(configPath)?.llm ?? LlmConfigSchema.parse({});
+}
+
+/**
+ * The manifest's `deployments` settings, or the default log path when the
+ * manifest is missing, invalid, or leaves them unconfigured.
+ */
+export function readDeploymentsConfig(configPath: string): DeploymentsConfig {
+  return (
+    readManifest(configPath)?.deployments ?? DeploymentsConfigSchema.parse({})
+  );
+}
+
+/**
+ * The manifest's `incidents` settings, or the default log path and weights
+ * when the manifest is missing, invalid, or leaves them unconfigured.
+ */
+export function readIncidentsConfig(configPath: string): IncidentsConfig {
+  return readManifest(configPath)?.incidents ?? IncidentsConfigSchema.parse({});
+}
+
+/**
+ * The manifest's `ownership` settings, or the defaults when the manifest
+ * is missing, invalid, or leaves them unconfigured.
+ */
+export function readOwnershipConfig(configPath: string): OwnershipConfig {
+  return readManifest(configPath)?.ownership ?? OwnershipConfigSchema.parse({});
+}
+
+/**
+ * The manifest's `plugins`, run from the manifest's directory so relative
+ * commands such as `./tools/kg-terraform` resolve against the repository.
+ */
+export function readPlugins(configPath: string): readonly PluginConfig[] {
+  return (readManifest(configPath)?.plugins ?? []).map((plugin) => ({
+    name: plugin.name,
+    command: plugin.command,
+    wasm: plugin.wasm ? resolve(dirname(configPath), plugin.wasm) : undefined,
+    memoryMb: plugin.memory_mb,
+    extensions: plugin.extensions,
+    formats: plugin.formats,
+    rules: plugin.rules,
+    enrich: plugin.enrich,
+    timeoutMs: plugin.timeout_ms,
+    cwd: dirname(configPath),
+  }));
+}
+
+/** The grammars under `tree_sitter` in the manifest. */
+export function readTreeSitterGrammars(
+  configPath: string,
+): readonly TreeSitterGrammarConfig[] {
+  return readManifest(configPath)?.tree_sitter?.grammars ?? [];
+}
+
+/**
+ * The manifest's `enrichers` pipeline, or undefined when it has none and
+ * the default order applies.
+ */
+export function readEnrichers(
+  configPath: string,
+): readonly EnricherStep[] | undefined {
+  return readManifest(configPath)?.enrichers?.map((step) => ({
+    name: step.name,
+    enabled: step.enabled,
+    paths: step.paths,
+    batchSize: step.batch_size,
+    rateLimit: step.rate_limit,
+    cacheTtlMs: step.cache_ttl_ms,
+  }));
+}
+
+/** The manifest's named `enrichment.rate_limits`, empty when it has none. */
+export function readEnrichmentRateLimits(
+  configPath: string,
+): Readonly<Record<string, EnrichmentRateLimit>> {
+  const limits = readManifest(configPath)?.enrichment?.rate_limits ?? {};
+  return Object.fromEntries(
+    Object.entries(limits).map(([name, limit]) => [
+      name,
+      { requestsPerMinute: limit.requests_per_minute },
+    ]),
+  );
+}
+
+/**
+ * The manifest's `runtime_config`, with each service's files resolved
+ * against the manifest's directory. Empty when it has none.
+ */
+export function readRuntimeConfig(configPath: string): RuntimeConfig {
+  const config = readManifest(configPath)?.runtime_config;
+  return {
+    services: (config?.services ?? []).map(({ service, files }) => ({
+      service,
+      files: files.map((file) => resolve(dirname(configPath), file)),
+    })),
+    ignore: config?.ignore ?? [],
+  };
+}
+
+/**
+ * The manifest's per-rule `rules` severities, empty when the manifest is
+ * missing, invalid, or configures none, so every rule keeps its default.
+ */
+export function readRuleSeverities(configPath: string): RuleSeverities {
+  return readManifest(configPath)?.rules ?? {};
+}
+
+/**
+ * The manifest's `cycles` budgets, or undefined when the manifest is
+ * missing, invalid, or sets none, so `check` does not look for cycles.
+ */
+export function readCycleBudgets(
+  configPath: string,
+): Pick<CycleBudgetOptions, 'budgets' | 'defaultBudget'> | undefined {
+  const cycles = readManifest(configPath)?.cycles;
+  return cycles
+    ? { budgets: cycles.budgets, defaultBudget: cycles.default_budget }
+    : undefined;
+}
+
+/**
+ * The manifest's `freshness` deadlines and reminder window, or undefined
+ * when the manifest is missing, invalid, or sets none, so `check` does
+ * not enforce review deadlines.
+ */
+export function readFreshnessConfig(
+  configPath: string,
+): Pick<FreshnessOptions, 'deadlines' | 'remindDays'> | undefined {
+  const freshness = readManifest(configPath)?.freshness;
+  return freshness
+    ? { deadlines: freshness.deadlines, remindDays: freshness.remind_days }
+    : undefined;
+}
+
+/**
+ * The manifest's `maturity` rubric, or the default five levels for modules
+ * when the manifest is missing, invalid, or configures none.
+ */
+export function readMaturityRubric(configPath: string): MaturityRubric {
+  const maturity = readManifest(configPath)?.maturity;
+  return maturity
+    ? {
+        types: maturity.types,
+        levels: maturity.levels ?? DEFAULT_MATURITY_RUBRIC.levels,
+        freshWithinMonths: maturity.fresh_within_months,
+      }
+    : DEFAULT_MATURITY_RUBRIC;
+}
+
+/**
+ * The manifest's `constraints` for merged graphs, empty when the manifest
+ * is missing, invalid, or sets none, so only the defaults apply.
+ */
+export function readGraphConstraints(
+  configPath: string,
+): GraphConstraintOptions {
+  const constraints = readManifest(configPath)?.constraints;
+  return constraints
+    ? {
+        uniqueNames: constraints.unique_names,
+        requireOwner: constraints.require_owner,
+      }
+    : {};
+}
+
+/**
+ * The path of the manifest's `service_registry`, resolved against the
+ * manifest's directory; undefined when the manifest sets none.
+ */
+export function readServiceRegistryPath(
+  configPath: string,
+): string | undefined {
+  const path = readManifest(configPath)?.service_registry;
+  return path ? resolve(dirname(configPath), path) : undefined;
+}
+
+/**
+ * The manifest's `aliases` and `renames`, for building graphs in which
+ * dependencies on other and old names resolve to the current ones. Empty
+ * when the manifest is missing, invalid, or configures neither.
+ */
+export function readGraphNames(configPath: string): GraphNameOptions {
+  const manifest = readManifest(configPath);
+  return {
+    ...(manifest?.aliases ? { aliases: manifest.aliases } : {}),
+    ...(manifest?.renames ? { renames: manifest.renames } : {}),
+  };
+}
+
+/**
+ * The manifest's `edge_rules`, each with the kind it adds, for building
+ * graphs with the edges they derive. Empty when the manifest is missing,
+ * invalid, or has none.
+ */
+export function readEdgeRules(configPath: string): readonly EdgeRule[] {
+  const rules = readManifest(configPath)?.edge_rules ?? {};
+  return Object.entries(rules).map(([kind, rule]) => ({ kind, ...rule }));
+}
+
+/**
+ * The manifest's module `templates` by name, empty when there is no
+ * manifest. Throws on an invalid manifest, so a mistyped template field
+ * does not quietly leave every new module without it.
+ */
+export function readModuleTemplates(
+  configPath: string,
+): Readonly<Record<string, ModuleTemplateConfig>> {
+  return readValidManifest(configPath)?.templates ?? {};
+}
+
+/**
+ * The manifest's `confluence` section, undefined when there is none.
+ * Throws on an invalid manifest, so a mistyped page mapping does not
+ * quietly publish a module to the default space.
+ */
+export function readConfluenceConfig(
+  configPath: string,
+): ConfluenceConfig | undefined {
+  return readValidManifest(configPath)?.confluence;
+}
+
+/**
+ * The manifest's `notion_database` section, undefined when there is
+ * none. Throws on an invalid manifest.
+ */
+export function readNotionDatabaseConfig(
+  configPath: string,
+): NotionDatabaseConfig | undefined {
+  return readValidManifest(configPath)?.notion_database;
+}
+
+/**
+ * The manifest's `pipelines` by name, empty when there is no manifest.
+ * Throws on an invalid manifest, so a mistyped step fails before any
+ * step runs.
+ */
+export function readPipelines(
+  configPath: string,
+): Readonly<Record<string, readonly PipelineStepConfig[]>> {
+  return readValidManifest(configPath)?.pipelines ?? {};
+}
+
+/**
+ * The manifest's saved `queries` by name, empty when there is no
+ * manifest. Throws on an invalid manifest, so a mistyped filter does not
+ * quietly widen a saved query.
+ */
+export function readSavedQueries(
+  configPath: string,
+): Readonly<Record<string, SavedQueryConfig>> {
+  return readValidManifest(configPath)?.queries ?? {};
+}
+
+/**
+ * The manifest's `views` by name, empty when there is no manifest. Throws
+ * on an invalid manifest, so a mistyped view does not quietly fall back to
+ * the built-in one of the same name.
+ */
+export function readViews(
+  configPath: string,
+): Readonly<Record<string, ViewProfile>> {
+  const views = readValidManifest(configPath)?.views ?? {};
+  return Object.fromEntries(
+    Object.entries(views).map(([name, view]) => [
+      name,
+      toViewProfile(name, view),
+    ]),
+  );
+}
+
+/**
+ * The manifest's `namespace`, or undefined when the manifest is missing,
+ * invalid, or sets none.
+ */
+export function readNamespace(configPath: string): string | undefined {
+  return readManifest(configPath)?.namespace;
+}
+
+/**
+ * The manifest's `prune` settings for exports, empty when the manifest is
+ * missing, invalid, or configures none, so nothing is pruned.
+ */
+export function readPruneOptions(configPath: string): PruneOptions {
+  const prune = readManifest(configPath)?.prune;
+  return {
+    ...(prune?.collapse_functions !== undefined
+      ? { collapseFunctions: prune.collapse_functions }
+      : {}),
+    ...(prune?.min_significance !== undefined
+      ? { minSignificance: prune.min_significance }
+      : {}),
+    ...(prune?.max_nodes !== undefined ? { maxNodes: prune.max_nodes } : {}),
+  };
+}
+
+/**
+ * How sidecars and path defaults layer onto inline annotations, from the
+ * manifest's `annotations` section. Empty when the manifest is missing,
+ * invalid, or has none.
+ */
+export function readAnnotationLayers(
+  configPath: string,
+): AnnotationLayerOptions {
+  const annotations = readManifest(configPath)?.annotations;
+  return {
+    ...(annotations?.resolution ? { resolution: annotations.resolution } : {}),
+    ...(annotations?.defaults ? { defaults: annotations.defaults } : {}),
+  };
+}
+
+/**
+ * Hash the manifest together with command `settings` that shape the index.
+ * A missing or invalid manifest hashes as empty.
+ */
+export function readConfigHash(
+  configPath: string,
+  settings: Readonly<Record<string, unknown>> = {},
+): string {
+  return hashConfig({ manifest: readManifest(configPath) ?? null, settings });
+}
+
+/**
+ * Parse a `--timeout` value in milliseconds, falling back to `configured`
+ * when the flag is absent.
+ */
+export function parseTimeout(
+  value: string | undefined,
+  configured: number | undefined,
+): number | undefined {
+  if (value === undefined) return configured;
+  const ms = Number(value);
+  if (!Number.isInteger(ms) || ms <= 0) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid timeout '${value}': expected milliseconds > 0`,
+    );
+  }
+  return ms;
+}
+
+/**
+ * Parse a `--max-file-bytes` value, falling back to `configured` when the
+ * flag is absent.
+ */
+export function parseMaxFileBytes(
+  value: string | undefined,
+  configured: number | undefined,
+): number | undefined {
+  if (value === undefined) return configured;
+  const bytes = Number(value);
+  if (!Number.isInteger(bytes) || bytes <= 0) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid max file size '${value}': expected bytes > 0`,
+    );
+  }
+  return bytes;
+}
@@ -0,0 +1,55 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses --namespace flags into a namespace or a list of namespace patterns
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, namespace, multi-tenant, options]
+ * context:
+ *   business_goal: Let users pick one team's graph or many with the same flag
+ *   domain: cli
+ */
+import { createKnowgraphError, parseNamespace } from '@know-graph/core';
+
+function usage(err: unknown): Error {
+  return createKnowgraphError(
+    'usage',
+    err instanceof Error ? err.message : String(err),
+  );
+}
+
+/**
+ * The namespace from `--namespace`, falling back to the manifest's
+ * `namespace`; undefined when neither is set. Throws a usage error on an
+ * invalid flag.
+ */
+export function resolveNamespace(
+  flag: string | undefined,
+  configured: string | undefined,
+): string | undefined {
+  if (flag === undefined) return configured;
+  try {
+    return parseNamespace(flag);
+  } catch (err) {
+    throw usage(err);
+  }
+}
+
+/**
+ * Parse a comma-separated `--namespace` list of namespaces, prefixes of
+ * them such as `acme`, or `*`. Throws a usage error on a bad entry.
+ */
+export function parseNamespacePatterns(value: string): readonly string[] {
+  return value
+    .split(',')
+    .map((entry) => entry.trim())
+    .filter(Boolean)
+    .map((entry) => {
+      if (entry === '*') return entry;
+      try {
+        return parseNamespace(entry);
+      } catch (err) {
+        throw usage(err);
+      }
+    });
+}
@@ -0,0 +1,43 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Resolves a node name given on the command line to exactly one graph node, reporting none or several
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, graph, nodes, options]
+ * context:
+ *   business_goal: Let graph commands take the names people use instead of node ids
+ *   domain: cli
+ */
+import { findGraphNodes } from '@know-graph/core';
+import type { DependencyGraph, GraphNode } from '@know-graph/core';
+import { reportError } from './errors.js';
+
+/**
+ * The one node `name` refers to (see `findGraphNodes`). Reports a usage
+ * error naming it as `label` when it matches none, or lists the ids of
+ * several, and returns undefined.
+ */
+export function resolveGraphNode(
+  graph: DependencyGraph,
+  name: string,
+  label: string = name,
+): GraphNode | undefined {
+  const matches = findGraphNodes(graph, name);
+  if (matches.length === 1) return matches[0];
+  if (matches.length === 0) {
+    reportError(
+      `${label} matches no node in the graph`,
+      'usage',
+      `Search with 'knowgraph query ${name}'.`,
+    );
+    return undefined;
+  }
+  for (const node of matches) console.error(`  ${node.id}`);
+  reportError(
+    `${label} matches ${matches.length} nodes`,
+    'usage',
+    'Pass one of the node ids above.',
+  );
+  return undefined;
+}
@@ -0,0 +1,67 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Renders the terraform-style plan that mutating commands print under --dry-run
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, dry-run, plan, diff, automation]
+ * context:
+ *   business_goal: Let automation preview every write a command would make before allowing it
+ *   domain: cli
+ */
+import chalk from 'chalk';
+import type { AuditChange } from '@know-graph/core';
+
+/** What a command would do; the changes are the same ones it would audit. */
+export interface Plan {
+  /** The command as typed, e.g. `lint --fix`. */
+  readonly command: string;
+  readonly changes: readonly AuditChange[];
+  /** Counts for the closing line, e.g. `3 annotations rewritten`. */
+  readonly totals: readonly string[];
+}
+
+function marker(change: AuditChange): string {
+  if (change.summary === 'created') return chalk.green('+');
+  if (change.summary === 'deleted') return chalk.red('-');
+  return chalk.yellow('~');
+}
+
+/** A unified diff line colored by kind: headers, hunks, additions, removals. */
+export function colorDiffLine(line: string): string {
+  if (line.startsWith('+++') || line.startsWith('---')) return chalk.bold(line);
+  if (line.startsWith('@@')) return chalk.cyan(line);
+  if (line.startsWith('+')) return chalk.green(line);
+  if (line.startsWith('-')) return chalk.red(line);
+  return line;
+}
+
+/**
+ * One line per change with its diff indented below it, then the totals.
+ * Nothing is written while the plan is produced, so the output says so.
+ */
+export function formatPlan(plan: Plan): string {
+  if (plan.changes.length === 0) {
+    return chalk.green(
+      `No changes. knowgraph ${plan.command} would leave everything as it is.`,
+    );
+  }
+  const lines = [
+    chalk.bold(`knowgraph ${plan.command} would make these changes:`),
+    '',
+  ];
+  for (const change of plan.changes) {
+    lines.push(`  ${marker(change)} ${change.target}: ${change.summary}`);
+    if (change.diff) {
+      for (const line of change.diff.split('\n')) {
+        lines.push(`      ${colorDiffLine(line)}`);
+      }
+    }
+  }
+  lines.push('');
+  const totals = plan.totals.length > 0 ? `${plan.totals.join(', ')}. ` : '';
+  lines.push(
+    `${chalk.bold('Plan:')} ${totals}${chalk.dim('Dry run: nothing was written.')}`,
+  );
+  return lines.join('\n');
+}
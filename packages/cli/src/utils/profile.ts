@@ -0,0 +1,84 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Captures V8 CPU and heap profiles plus phase timings behind the --profile flag
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, profiling, performance, inspector]
+ * context:
+ *   business_goal: Let users attach actionable performance data to slow-scan reports
+ *   domain: cli
+ */
+import { mkdirSync, writeFileSync } from 'node:fs';
+import { Session } from 'node:inspector/promises';
+import { join } from 'node:path';
+import chalk from 'chalk';
+import { createPhaseTimer } from '@know-graph/core';
+import type { PhaseTimer, PhaseTiming } from '@know-graph/core';
+
+export interface ProfileCapture {
+  readonly dir: string;
+  readonly timer: PhaseTimer;
+  /** Stop sampling and write the profiles, returning their paths. */
+  stop(): Promise<readonly string[]>;
+}
+
+/**
+ * Start CPU and sampling heap profilers for this process. The profiles are
+ * written as `cpu.cpuprofile` and `heap.heapprofile` in `dir`; both load in
+ * Chrome DevTools and convert to pprof.
+ */
+export async function startProfile(dir: string): Promise<ProfileCapture> {
+  const session = new Session();
+  session.connect();
+  await session.post('Profiler.enable');
+  await session.post('Profiler.start');
+  await session.post('HeapProfiler.enable');
+  await session.post('HeapProfiler.startSampling');
+
+  async function stop(): Promise<readonly string[]> {
+    try {
+      const cpu = await session.post('Profiler.stop');
+      const heap = await session.post('HeapProfiler.stopSampling');
+      mkdirSync(dir, { recursive: true });
+      const cpuPath = join(dir, 'cpu.cpuprofile');
+      const heapPath = join(dir, 'heap.heapprofile');
+      writeFileSync(cpuPath, JSON.stringify(cpu.profile));
+      writeFileSync(heapPath, JSON.stringify(heap.profile));
+      return [cpuPath, heapPath];
+    } finally {
+      session.disconnect();
+    }
+  }
+
+  return { dir, timer: createPhaseTimer(), stop };
+}
+
+function formatRow(label: string, ms: number): string {
+  return `  ${label.padEnd(7)}${`${ms.toFixed(1)}ms`.padStart(12)}`;
+}
+
+export function formatPhaseTimings(timings: readonly PhaseTiming[]): string {
+  if (timings.length === 0) {
+    return 'No phases recorded.';
+  }
+  const total = timings.reduce((sum, t) => sum + t.ms, 0);
+  const lines = [chalk.bold('Phase timings:')];
+  for (const { phase, ms, calls } of timings) {
+    const share = total > 0 ? Math.round((ms / total) * 100) : 0;
+    const detail = `${share}%, ${calls} call${calls === 1 ? '' : 's'}`;
+    lines.push(`${formatRow(phase, ms)}  ${chalk.dim(detail)}`);
+  }
+  lines.push(formatRow('total', total));
+  return lines.join('\n');
+}
+
+/**
+ * Stop `capture` and print the phase timings and profile paths.
+ */
+export async function reportProfile(capture: ProfileCapture): Promise<void> {
+  const files = await capture.stop();
+  console.log('');
+  console.log(formatPhaseTimings(capture.timer.timings()));
+  console.log(chalk.dim(`Profiles written: ${files.join(', ')}`));
+}
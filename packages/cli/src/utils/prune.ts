@@ -0,0 +1,57 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses --collapse-functions, --min-significance, and --max-nodes flags into pruning options
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, prune, budget, options]
+ * context:
+ *   business_goal: Give every output the same graph-trimming flags
+ *   domain: cli
+ */
+import { createKnowgraphError } from '@know-graph/core';
+import type { PruneOptions } from '@know-graph/core';
+
+export interface PruneFlags {
+  readonly collapseFunctions?: boolean;
+  readonly minSignificance?: string;
+  readonly maxNodes?: string;
+}
+
+function parseCount(flag: string, value: string, min: number): number {
+  const count = Number(value);
+  if (value.trim() === '' || !Number.isInteger(count) || count < min) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid ${flag} '${value}': expected a whole number >= ${min}`,
+    );
+  }
+  return count;
+}
+
+/**
+ * Pruning from the flags, each falling back to the manifest's `prune`
+ * settings, or undefined when neither asks for any. Throws a usage error
+ * on a bad number.
+ */
+export function resolvePruneOptions(
+  flags: PruneFlags,
+  configured: PruneOptions,
+): PruneOptions | undefined {
+  const options: PruneOptions = {
+    collapseFunctions: flags.collapseFunctions ?? configured.collapseFunctions,
+    minSignificance:
+      flags.minSignificance !== undefined
+        ? parseCount('--min-significance', flags.minSignificance, 0)
+        : configured.minSignificance,
+    maxNodes:
+      flags.maxNodes !== undefined
+        ? parseCount('--max-nodes', flags.maxNodes, 1)
+        : configured.maxNodes,
+  };
+  const enabled =
+    options.collapseFunctions === true ||
+    options.minSignificance !== undefined ||
+    options.maxNodes !== undefined;
+  return enabled ? options : undefined;
+}
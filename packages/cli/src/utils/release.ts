@@ -0,0 +1,96 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Scans a git ref in a temporary worktree into the entities, graph, and Go API a version suggestion compares
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, release, semver, git, worktree]
+ * context:
+ *   business_goal: Compare two refs without disturbing the working tree a release job runs in
+ *   domain: cli
+ */
+import { mkdtempSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import {
+  buildDependencyGraph,
+  createDatabaseManager,
+  createGitCommandRunner,
+  createKnowgraphError,
+  createIndexer,
+  createQueryEngine,
+  discoverGoPackages,
+  extractGoApi,
+} from '@know-graph/core';
+import type {
+  GitCommandRunner,
+  ReleaseSnapshot,
+  StoredEntity,
+} from '@know-graph/core';
+import { readIndexSetup } from './indexing.js';
+import { readAnnotationLayers, readGraphNames } from './manifest.js';
+
+/** Index `dir` in memory with its own manifest's parsers and settings. */
+function indexEntities(dir: string): readonly StoredEntity[] {
+  const setup = readIndexSetup(dir, {});
+  const dbManager = createDatabaseManager();
+  try {
+    dbManager.initialize();
+    createIndexer(setup.parserRegistry, dbManager).index({
+      rootDir: dir,
+      exclude: setup.exclude,
+      defaultLocale: setup.defaultLocale,
+      ...setup.walk,
+      ...setup.limits,
+      annotations: readAnnotationLayers(join(dir, '.knowgraph.yml')),
+      configHash: setup.configHash,
+    });
+    return createQueryEngine(dbManager).getAll();
+  } finally {
+    dbManager.close();
+  }
+}
+
+/**
+ * The entities and graph of the tree at `dir`, plus the exported API of
+ * any Go packages in it.
+ */
+export function snapshotTree(dir: string): ReleaseSnapshot {
+  const entities = indexEntities(dir);
+  const graph = buildDependencyGraph(
+    entities,
+    readGraphNames(join(dir, '.knowgraph.yml')),
+  );
+  const packages = discoverGoPackages(dir);
+  if (packages.length === 0) return { entities, graph };
+  return { entities, graph, api: extractGoApi(dir, packages, entities) };
+}
+
+/**
+ * Snapshot `ref` of the repository at `rootDir` from a detached worktree
+ * that is removed afterwards, leaving the working tree untouched. A ref
+ * git cannot check out is an `io` error.
+ */
+export function snapshotRef(
+  rootDir: string,
+  ref: string,
+  git: GitCommandRunner = createGitCommandRunner(),
+): ReleaseSnapshot {
+  const parent = mkdtempSync(join(tmpdir(), 'knowgraph-semver-'));
+  const dir = join(parent, 'tree');
+  try {
+    try {
+      git.run(['worktree', 'add', '--quiet', '--detach', dir, ref], rootDir);
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      throw createKnowgraphError('io', `Cannot check out ${ref}: ${message}`);
+    }
+    try {
+      return snapshotTree(dir);
+    } finally {
+      git.run(['worktree', 'remove', '--force', dir], rootDir);
+    }
+  } finally {
+    rmSync(parent, { recursive: true, force: true });
+  }
+}
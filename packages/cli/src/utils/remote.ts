@@ -0,0 +1,46 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Resolves a scan target to a local directory, checking out git URLs into a cache first
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, remote, git, clone, index]
+ * context:
+ *   business_goal: Let any scanning command take a git URL where it takes a path
+ *   domain: cli
+ */
+import { homedir } from 'node:os';
+import { join, resolve } from 'node:path';
+import { checkoutRemote, parseRemoteSource } from '@know-graph/core';
+import type { IndexSource } from '@know-graph/core';
+
+export interface ScanTarget {
+  readonly rootDir: string;
+  /** Set when the target was a git URL. */
+  readonly source?: IndexSource;
+}
+
+/** `$XDG_CACHE_HOME/knowgraph/repos`, or `~/.cache/knowgraph/repos`. */
+export function defaultCacheDir(
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): string {
+  const root = env.XDG_CACHE_HOME || join(homedir(), '.cache');
+  return join(root, 'knowgraph', 'repos');
+}
+
+/**
+ * The directory to scan for `target`. A git URL, optionally pinned with
+ * `#ref`, is fetched into its checkout under `cacheDir` first; anything
+ * else is a local path. Throws when git fails.
+ */
+export function resolveScanTarget(
+  target: string,
+  cacheDir: string = defaultCacheDir(),
+): ScanTarget {
+  const remote = parseRemoteSource(target);
+  if (!remote) return { rootDir: resolve(target) };
+  const { dir, ...source } = checkoutRemote(remote, {
+    cacheDir: resolve(cacheDir),
+  });
+  return { rootDir: dir, source };
+}
@@ -0,0 +1,81 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Rescans a repository into the served index on a cron schedule and alerts on anomalies
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, serve, schedule, cron, index]
+ * context:
+ *   business_goal: Keep a long-running server's graph fresh without external cron glue
+ *   domain: cli
+ */
+import { join } from 'node:path';
+import { stripVTControlCharacters } from 'node:util';
+import { scheduleCron } from '@know-graph/core';
+import type { CronSchedule, ScheduledTask } from '@know-graph/core';
+import { indexInto } from './indexing.js';
+import { recordScan } from './history.js';
+import { getLogger } from './logging.js';
+import { resolveScanTarget } from './remote.js';
+import { publishToWarehouses } from './warehouse.js';
+
+/**
+ * The anomaly report as log records. Logs go to stderr, which stays clear
+ * of the MCP stdio channel.
+ */
+function log(line: string): void {
+  if (line) getLogger().info(stripVTControlCharacters(line));
+}
+
+function describeError(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+/**
+ * Incrementally index `target` into `dbPath`, log a one-line summary,
+ * record the scan in the history so anomalies reach the configured alert
+ * sinks, and publish the graph to any warehouse sinks. A git URL target
+ * is fetched first. Servers reading `dbPath` pick up the changes on their
+ * next poll. Throws when indexing fails.
+ */
+export async function runScheduledScan(
+  target: string,
+  dbPath: string,
+): Promise<void> {
+  const { rootDir, source } = resolveScanTarget(target);
+  const { result } = indexInto(rootDir, dbPath, { incremental: true, source });
+  const at = source ? ` at ${source.commit.slice(0, 12)}` : '';
+  const errors =
+    result.errors.length > 0 ? `, ${result.errors.length} error(s)` : '';
+  getLogger().info(
+    `Rescanned ${result.totalFiles} file(s)${at}: ${result.totalEntities} entities in ${result.duration}ms${errors}`,
+    {
+      files: result.totalFiles,
+      entities: result.totalEntities,
+      durationMs: result.duration,
+      errors: result.errors.length,
+      ...(source ? { commit: source.commit } : {}),
+    },
+  );
+  const configPath = join(rootDir, '.knowgraph.yml');
+  await recordScan(configPath, dbPath, result.totalFiles, log);
+  await publishToWarehouses(configPath, dbPath);
+}
+
+/**
+ * Rescan `target` each time `schedule` matches until `signal` aborts. A
+ * failed scan is reported and the schedule carries on.
+ */
+export function startScanSchedule(
+  schedule: CronSchedule,
+  target: string,
+  dbPath: string,
+  signal: AbortSignal,
+): ScheduledTask {
+  return scheduleCron(schedule, () => runScheduledScan(target, dbPath), {
+    signal,
+    onError: (err) => {
+      getLogger().error(`Scheduled scan failed: ${describeError(err)}`);
+    },
+  });
+}
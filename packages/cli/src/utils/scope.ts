@@ -0,0 +1,37 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Collects repeatable --scope flags and parses them into path and tag scopes
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, scope, options]
+ * context:
+ *   business_goal: Let any command be narrowed to one part of the graph with the same flag
+ *   domain: cli
+ */
+import { createKnowgraphError, parseScope } from '@know-graph/core';
+import type { ScanScope } from '@know-graph/core';
+
+/** Commander option parser that lets `--scope` be given more than once. */
+export function collectScope(
+  value: string,
+  previous: readonly string[] = [],
+): readonly string[] {
+  return [...previous, value];
+}
+
+/** Parse `--scope` values, throwing a usage error on the first bad one. */
+export function parseScopes(
+  values: readonly string[] = [],
+): readonly ScanScope[] {
+  return values.map((value) => {
+    try {
+      return parseScope(value);
+    } catch (err) {
+      throw createKnowgraphError(
+        'usage',
+        err instanceof Error ? err.message : String(err),
+      );
+    }
+  });
+}
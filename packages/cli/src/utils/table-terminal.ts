@@ -0,0 +1,146 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Runs an interactive result table on the terminal's alternate screen and writes one-key exports to the working directory
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, tui, table, terminal, export]
+ * context:
+ *   business_goal: Leave the user's terminal as it was when they quit a result table
+ *   domain: cli
+ */
+import { writeFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import { emitKeypressEvents } from 'node:readline';
+import type { Key } from 'node:readline';
+import {
+  TABLE_EXPORT_EXTENSIONS,
+  createTableState,
+  exportTable,
+  renderTable,
+  updateTable,
+} from './table-view.js';
+import type { Viewport } from './browser.js';
+import type { TableData, TableKey, TableState } from './table-view.js';
+
+const ENTER_SCREEN = '\x1b[?1049h\x1b[?25l';
+const LEAVE_SCREEN = '\x1b[?25h\x1b[?1049l';
+const CLEAR = '\x1b[H\x1b[2J';
+
+export interface TableTerminalOptions {
+  /** Shown above the table, such as the command that produced it. */
+  readonly title: string;
+  /** Exports are written to this path plus the format's extension. */
+  readonly exportPath: string;
+}
+
+/** The table key for a keypress, or undefined for keys it ignores. */
+export function toTableKey(
+  text: string | undefined,
+  key: Key | undefined,
+): TableKey | undefined {
+  if (key?.ctrl) {
+    if (key.name === 'c' || key.name === 'd') return 'quit';
+    if (key.name === 'p') return 'up';
+    if (key.name === 'n') return 'down';
+    return undefined;
+  }
+  switch (key?.name) {
+    case 'up':
+    case 'down':
+    case 'left':
+    case 'right':
+      return key.name;
+    case 'pageup':
+      return 'pageUp';
+    case 'pagedown':
+      return 'pageDown';
+    case 'escape':
+      return 'quit';
+  }
+  switch (text) {
+    case 'q':
+      return 'quit';
+    case 's':
+      return 'sort';
+    case 'h':
+      return 'hide';
+    case 'a':
+      return 'showAll';
+    case 'c':
+      return { export: 'csv' };
+    case 'j':
+      return { export: 'json' };
+    case 'm':
+      return { export: 'markdown' };
+  }
+  return undefined;
+}
+
+/**
+ * Show `data` until the user quits. Export keys write the table as shown,
+ * visible columns in display order, and report where on the status line.
+ */
+export function showTable(
+  data: TableData,
+  options: TableTerminalOptions,
+): Promise<void> {
+  let state: TableState = createTableState(data);
+  const viewport = (): Viewport => ({
+    rows: process.stdout.rows ?? 24,
+    columns: process.stdout.columns ?? 80,
+  });
+  const draw = (): void => {
+    process.stdout.write(
+      `${CLEAR}${renderTable(state, viewport(), options.title)}`,
+    );
+  };
+
+  return new Promise<void>((done) => {
+    const onKey = (text: string | undefined, key: Key | undefined): void => {
+      const tableKey = toTableKey(text, key);
+      if (!tableKey) return;
+      // The title, header, and key help leave the rest for rows
+      const page = Math.max(viewport().rows - 3, 1);
+      const update = updateTable(
+        { ...state, status: undefined },
+        tableKey,
+        page,
+      );
+      state = update.state;
+      if (update.effect?.type === 'quit') {
+        process.stdin.off('keypress', onKey);
+        process.stdout.off('resize', draw);
+        process.stdin.setRawMode(false);
+        process.stdin.pause();
+        process.stdout.write(LEAVE_SCREEN);
+        done();
+        return;
+      }
+      if (update.effect?.type === 'export') {
+        const { format } = update.effect;
+        const path = resolve(
+          `${options.exportPath}.${TABLE_EXPORT_EXTENSIONS[format]}`,
+        );
+        try {
+          writeFileSync(path, exportTable(state, format), 'utf-8');
+          state = { ...state, status: `Wrote ${path}` };
+        } catch (err) {
+          state = {
+            ...state,
+            status: `Could not write ${path}: ${(err as Error).message}`,
+          };
+        }
+      }
+      draw();
+    };
+
+    emitKeypressEvents(process.stdin);
+    process.stdin.setRawMode(true);
+    process.stdin.on('keypress', onKey);
+    process.stdout.on('resize', draw);
+    process.stdin.resume();
+    process.stdout.write(ENTER_SCREEN);
+    draw();
+  });
+}
@@ -0,0 +1,290 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: State, key handling, rendering, and CSV, JSON, and Markdown export for interactive sortable result tables in the terminal
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, tui, table, sort, export, csv, markdown]
+ * context:
+ *   business_goal: Let developers sort, trim, and save query results in the terminal instead of piping JSON through jq
+ *   domain: cli
+ */
+import chalk from 'chalk';
+import { compareStrings, csvField } from '@know-graph/core';
+import { truncate } from './format.js';
+import type { Viewport } from './browser.js';
+
+/** Rows of text cells under their column headers. */
+export interface TableData {
+  readonly headers: readonly string[];
+  readonly rows: readonly (readonly string[])[];
+}
+
+export type TableExportFormat = 'csv' | 'json' | 'markdown';
+
+export const TABLE_EXPORT_EXTENSIONS: Readonly<
+  Record<TableExportFormat, string>
+> = { csv: 'csv', json: 'json', markdown: 'md' };
+
+export interface TableState {
+  readonly data: TableData;
+  /** Indexes into `data.rows`, in display order. */
+  readonly order: readonly number[];
+  readonly sort?: { readonly column: number; readonly descending: boolean };
+  readonly hidden: readonly number[];
+  /** The selected row, in display order. */
+  readonly cursor: number;
+  /** The selected column, which sorting and hiding act on. */
+  readonly column: number;
+  /** Shown in place of the key help until the next key press. */
+  readonly status?: string;
+}
+
+export type TableKey =
+  | 'up'
+  | 'down'
+  | 'left'
+  | 'right'
+  | 'pageUp'
+  | 'pageDown'
+  | 'sort'
+  | 'hide'
+  | 'showAll'
+  | 'quit'
+  | { readonly export: TableExportFormat };
+
+export type TableEffect =
+  | { readonly type: 'quit' }
+  | { readonly type: 'export'; readonly format: TableExportFormat };
+
+export interface TableUpdate {
+  readonly state: TableState;
+  readonly effect?: TableEffect;
+}
+
+/** Columns wider than this are cut, so one long cell cannot hide the rest. */
+const MAX_COLUMN_WIDTH = 40;
+
+export function createTableState(data: TableData): TableState {
+  return {
+    data,
+    order: data.rows.map((_, index) => index),
+    hidden: [],
+    cursor: 0,
+    column: 0,
+  };
+}
+
+function visibleColumns(state: TableState): number[] {
+  return state.data.headers
+    .map((_, index) => index)
+    .filter((index) => !state.hidden.includes(index));
+}
+
+const NUMBER = /^-?\d+(\.\d+)?%?$/;
+
+/** Numbers, percentages included, by value; anything else as text. */
+export function compareCells(a: string, b: string): number {
+  if (NUMBER.test(a) && NUMBER.test(b)) {
+    return parseFloat(a) - parseFloat(b);
+  }
+  return compareStrings(a.toLowerCase(), b.toLowerCase());
+}
+
+/**
+ * Sort by the selected column, ascending first and descending on a
+ * second press of the same column. Ties keep their original order.
+ */
+function sorted(state: TableState): TableState {
+  const { column } = state;
+  const descending = state.sort?.column === column && !state.sort.descending;
+  const { rows } = state.data;
+  const order = rows
+    .map((_, index) => index)
+    .sort((a, b) => {
+      const byCell = compareCells(rows[a][column], rows[b][column]);
+      return (descending ? -byCell : byCell) || a - b;
+    });
+  return { ...state, order, sort: { column, descending }, cursor: 0 };
+}
+
+function hideColumn(state: TableState): TableState {
+  const visible = visibleColumns(state);
+  if (visible.length <= 1) {
+    return { ...state, status: 'The last column cannot be hidden' };
+  }
+  const hidden = [...state.hidden, state.column];
+  const next =
+    visible.find((index) => index > state.column) ??
+    visible.filter((index) => index < state.column).at(-1) ??
+    0;
+  return { ...state, hidden, column: next };
+}
+
+function clamp(value: number, length: number): number {
+  return Math.max(0, Math.min(value, length - 1));
+}
+
+function moveColumn(state: TableState, step: number): TableState {
+  const visible = visibleColumns(state);
+  const at = visible.indexOf(state.column);
+  return { ...state, column: visible[clamp(at + step, visible.length)] };
+}
+
+/**
+ * Apply one key press. Left and right select a column to sort by or
+ * hide; export keys hand the visible columns, in display order, to the
+ * caller to write.
+ */
+export function updateTable(
+  state: TableState,
+  key: TableKey,
+  page = 10,
+): TableUpdate {
+  if (typeof key === 'object') {
+    return { state, effect: { type: 'export', format: key.export } };
+  }
+  const length = state.order.length;
+  switch (key) {
+    case 'quit':
+      return { state, effect: { type: 'quit' } };
+    case 'up':
+      return { state: { ...state, cursor: clamp(state.cursor - 1, length) } };
+    case 'down':
+      return { state: { ...state, cursor: clamp(state.cursor + 1, length) } };
+    case 'pageUp':
+      return {
+        state: { ...state, cursor: clamp(state.cursor - page, length) },
+      };
+    case 'pageDown':
+      return {
+        state: { ...state, cursor: clamp(state.cursor + page, length) },
+      };
+    case 'left':
+      return { state: moveColumn(state, -1) };
+    case 'right':
+      return { state: moveColumn(state, 1) };
+    case 'sort':
+      return { state: sorted(state) };
+    case 'hide':
+      return { state: hideColumn(state) };
+    case 'showAll':
+      return { state: { ...state, hidden: [] } };
+  }
+}
+
+/** The visible columns of the rows, in display order. */
+function visibleTable(state: TableState): TableData {
+  const columns = visibleColumns(state);
+  return {
+    headers: columns.map((index) => state.data.headers[index]),
+    rows: state.order.map((row) =>
+      columns.map((index) => state.data.rows[row][index]),
+    ),
+  };
+}
+
+function markdownCell(value: string): string {
+  return value.replace(/\|/g, '\\|').replace(/\n/g, ' ');
+}
+
+/** The table as shown: visible columns only, rows in display order. */
+export function exportTable(
+  state: TableState,
+  format: TableExportFormat,
+): string {
+  const { headers, rows } = visibleTable(state);
+  switch (format) {
+    case 'csv':
+      return `${[headers, ...rows]
+        .map((row) => row.map(csvField).join(','))
+        .join('\n')}\n`;
+    case 'json':
+      return `${JSON.stringify(
+        rows.map((row) =>
+          Object.fromEntries(headers.map((header, i) => [header, row[i]])),
+        ),
+        null,
+        2,
+      )}\n`;
+    case 'markdown':
+      return `${[
+        `| ${headers.map(markdownCell).join(' | ')} |`,
+        `|${headers.map(() => '---').join('|')}|`,
+        ...rows.map((row) => `| ${row.map(markdownCell).join(' | ')} |`),
+      ].join('\n')}\n`;
+  }
+}
+
+function pad(text: string, width: number): string {
+  const cut = truncate(text, width);
+  return cut + ' '.repeat(width - cut.length);
+}
+
+/** The whole screen for `state`, with the key help on the last row. */
+export function renderTable(
+  state: TableState,
+  viewport: Viewport,
+  title = 'knowgraph',
+): string {
+  const columns = visibleColumns(state);
+  const { headers, rows } = state.data;
+  // Room for the sort arrow beside each header
+  const widths = columns.map((index) =>
+    Math.min(
+      MAX_COLUMN_WIDTH,
+      rows.reduce(
+        (max, row) => Math.max(max, row[index].length),
+        headers[index].length + 2,
+      ),
+    ),
+  );
+  const line = (cells: readonly string[]): string =>
+    truncate(
+      cells.map((cell, i) => pad(cell, widths[i])).join('  '),
+      viewport.columns,
+    );
+
+  const { sort } = state;
+  const sortedBy = sort
+    ? `, by ${headers[sort.column]} ${sort.descending ? 'descending' : 'ascending'}`
+    : '';
+  const hidden =
+    state.hidden.length > 0 ? `, ${state.hidden.length} hidden columns` : '';
+  const header = line(
+    columns.map((index) => {
+      if (sort?.column !== index) return headers[index];
+      return `${headers[index]} ${sort.descending ? 'v' : '^'}`;
+    }),
+  );
+  // Underline the selected column, measured on the unstyled header
+  const focus = columns.indexOf(state.column);
+  const from = widths.slice(0, focus).reduce((sum, w) => sum + w + 2, 0);
+  const to = from + widths[focus];
+  const lines = [
+    `${chalk.bold(title)} ${chalk.dim(`${rows.length} rows${sortedBy}${hidden}`)}`,
+    chalk.bold(header.slice(0, from)) +
+      chalk.bold.underline(header.slice(from, to)) +
+      chalk.bold(header.slice(to)),
+  ];
+  if (rows.length === 0) lines.push(chalk.dim('  No rows.'));
+
+  const height = Math.max(viewport.rows - lines.length - 1, 1);
+  const start = Math.floor(state.cursor / height) * height;
+  const end = Math.min(start + height, state.order.length);
+  for (let i = start; i < end; i++) {
+    const row = rows[state.order[i]];
+    const text = line(columns.map((index) => row[index]));
+    lines.push(i === state.cursor ? chalk.inverse(text) : text);
+  }
+
+  const help =
+    'up/down rows  left/right column  s sort  h hide  a show all  c csv  j json  m markdown  q quit';
+  while (lines.length < viewport.rows - 1) lines.push('');
+  lines.push(
+    state.status
+      ? chalk.yellow(truncate(state.status, viewport.columns))
+      : chalk.dim(truncate(help, viewport.columns)),
+  );
+  return lines.slice(0, viewport.rows).join('\n');
+}
@@ -0,0 +1,140 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: The CLI's shared telemetry, sending traces and metrics of scans, exports, and serve to an OTLP collector
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, telemetry, opentelemetry, observability]
+ * context:
+ *   business_goal: Trace every command through one setup so no scan goes unmeasured
+ *   domain: cli
+ */
+import { hostname } from 'node:os';
+import { createOtlpExporter, createTelemetry } from '@know-graph/core';
+import type {
+  OtlpExporter,
+  OtlpExporterOptions,
+  Telemetry,
+  TelemetryConfig,
+} from '@know-graph/core';
+import { getLogger } from './logging.js';
+import { readTelemetryConfig } from './manifest.js';
+
+type Env = Readonly<Record<string, string | undefined>>;
+
+export interface TelemetrySettings {
+  readonly exporter: OtlpExporterOptions;
+  /** How often a long-running process sends what it has recorded. */
+  readonly intervalMs: number;
+}
+
+let telemetry: Telemetry | undefined;
+let exporter: OtlpExporter | undefined;
+let intervalMs = 60_000;
+
+/** `OTEL_EXPORTER_OTLP_HEADERS`: comma-separated, URL-encoded `key=value`. */
+function parseHeaderList(value: string | undefined): Record<string, string> {
+  const headers: Record<string, string> = {};
+  for (const pair of (value ?? '').split(',')) {
+    const index = pair.indexOf('=');
+    if (index <= 0) continue;
+    headers[decodeURIComponent(pair.slice(0, index).trim())] =
+      decodeURIComponent(pair.slice(index + 1).trim());
+  }
+  return headers;
+}
+
+/**
+ * Where and how to send telemetry, from the manifest's `telemetry` section
+ * with the standard `OTEL_*` variables taking precedence. Undefined, so
+ * nothing is recorded, without an endpoint, when `enabled` is false, or
+ * when `OTEL_SDK_DISABLED` is `true`.
+ */
+export function resolveTelemetrySettings(
+  config: TelemetryConfig,
+  version: string,
+  env: Env = process.env,
+): TelemetrySettings | undefined {
+  const endpoint = env.OTEL_EXPORTER_OTLP_ENDPOINT || config.endpoint;
+  if (!config.enabled || env.OTEL_SDK_DISABLED === 'true' || !endpoint) {
+    return undefined;
+  }
+  const headers: Record<string, string> = {};
+  for (const [header, variable] of Object.entries(config.headers_env)) {
+    const value = env[variable];
+    if (value) headers[header] = value;
+  }
+  return {
+    exporter: {
+      endpoint,
+      headers: {
+        ...headers,
+        ...parseHeaderList(env.OTEL_EXPORTER_OTLP_HEADERS),
+      },
+      resource: {
+        'service.name': env.OTEL_SERVICE_NAME || config.service_name,
+        'service.version': version,
+        'host.name': hostname(),
+        'process.runtime.name': 'nodejs',
+        'process.runtime.version': process.versions.node,
+      },
+      timeoutMs: config.timeout_ms,
+    },
+    intervalMs: config.export_interval_ms,
+  };
+}
+
+/**
+ * Record from now on when the manifest at `configPath` or the environment
+ * names a collector, replacing any earlier setup. Returns the telemetry to
+ * record into, or undefined when nothing is sent.
+ */
+export function startTelemetry(
+  configPath: string,
+  version: string,
+  env: Env = process.env,
+): Telemetry | undefined {
+  const settings = resolveTelemetrySettings(
+    readTelemetryConfig(configPath),
+    version,
+    env,
+  );
+  telemetry = settings ? createTelemetry() : undefined;
+  exporter = settings ? createOtlpExporter(settings.exporter) : undefined;
+  intervalMs = settings?.intervalMs ?? intervalMs;
+  return telemetry;
+}
+
+export function getTelemetry(): Telemetry | undefined {
+  return telemetry;
+}
+
+/**
+ * Send what has been recorded since the last flush. A collector that
+ * cannot be reached is a warning: telemetry never fails the command.
+ */
+export async function flushTelemetry(): Promise<void> {
+  if (!telemetry || !exporter) return;
+  try {
+    await exporter.export(telemetry.drain());
+  } catch (err) {
+    getLogger().warn(
+      `Could not export telemetry: ${err instanceof Error ? err.message : String(err)}`,
+    );
+  }
+}
+
+/** Flush every `export_interval_ms` until `signal` aborts, then once more. */
+export function flushTelemetryUntil(signal: AbortSignal): void {
+  if (!telemetry) return;
+  const timer = setInterval(() => void flushTelemetry(), intervalMs);
+  timer.unref();
+  signal.addEventListener(
+    'abort',
+    () => {
+      clearInterval(timer);
+      void flushTelemetry();
+    },
+    { once: true },
+  );
+}
@@ -0,0 +1,122 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Records each command run into the opt-in usage counts and sends them to the maintainers at most once a day
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, usage, telemetry, privacy, opt-in]
+ * context:
+ *   business_goal: Send usage counts without slowing commands or retrying on every run
+ *   domain: cli
+ */
+import { homedir } from 'node:os';
+import { join } from 'node:path';
+import type { Command } from 'commander';
+import {
+  buildUsageReport,
+  errorKindForExitCode,
+  isUsageDisabledByEnv,
+  isUsageReportDue,
+  markUsageSent,
+  readUsageState,
+  recordCommandUsage,
+  sendUsageReport,
+  writeUsageState,
+} from '@know-graph/core';
+import type { UsageEnvironment, UsageState } from '@know-graph/core';
+import { getLogger } from './logging.js';
+
+type Env = Readonly<Record<string, string | undefined>>;
+
+/**
+ * `$XDG_CONFIG_HOME/knowgraph/usage.json`, or
+ * `~/.config/knowgraph/usage.json`. The choice is per user, not per
+ * repository, so no manifest can opt anyone in.
+ */
+export function usageStatePath(env: Env = process.env): string {
+  const root = env.XDG_CONFIG_HOME || join(homedir(), '.config');
+  return join(root, 'knowgraph', 'usage.json');
+}
+
+/**
+ * Where reports are posted: `KNOWGRAPH_USAGE_ENDPOINT`, else the endpoint
+ * chosen when opting in. Undefined when there is neither, and then
+ * nothing is sent.
+ */
+export function usageEndpoint(
+  state: UsageState,
+  env: Env = process.env,
+): string | undefined {
+  return env.KNOWGRAPH_USAGE_ENDPOINT || state.endpoint;
+}
+
+export function usageEnvironment(
+  version: string,
+  env: Env = process.env,
+): UsageEnvironment {
+  return {
+    version,
+    os: process.platform,
+    arch: process.arch,
+    node: Number(process.versions.node.split('.')[0]),
+    ci: Boolean(env.CI && env.CI !== 'false'),
+  };
+}
+
+/**
+ * The name `command` is counted under: its path below the program, such
+ * as `deployments import`. Arguments and option values are never part of
+ * it.
+ */
+export function usageCommandName(command: Command): string {
+  const names: string[] = [];
+  for (let current: Command | null = command; current?.parent; ) {
+    names.unshift(current.name());
+    current = current.parent;
+  }
+  return names.join(' ');
+}
+
+export interface RecordUsageOptions {
+  readonly env?: Env;
+  readonly now?: Date;
+  readonly fetch?: typeof fetch;
+}
+
+/**
+ * Count one run of `command`, failed with the kind `exitCode` stands for,
+ * and send the counts when a report is due. Does nothing unless the user
+ * opted in and the environment allows it, and never fails the command.
+ */
+export async function recordUsage(
+  command: string,
+  exitCode: number,
+  version: string,
+  options: RecordUsageOptions = {},
+): Promise<void> {
+  const { env = process.env, now = new Date() } = options;
+  if (isUsageDisabledByEnv(env)) return;
+  const path = usageStatePath(env);
+  try {
+    const previous = readUsageState(path);
+    if (!previous.enabled) return;
+    const state = recordCommandUsage(
+      previous,
+      command,
+      errorKindForExitCode(exitCode),
+    );
+    writeUsageState(path, state);
+    const endpoint = usageEndpoint(state, env);
+    if (endpoint === undefined || !isUsageReportDue(state, now)) return;
+    await sendUsageReport(
+      buildUsageReport(state, usageEnvironment(version, env), now),
+      { endpoint, fetch: options.fetch },
+    );
+    // Runs counted while the report was in flight are dropped with it
+    writeUsageState(path, markUsageSent(state, now));
+  } catch (err) {
+    getLogger().debug(
+      `Could not record usage: ${err instanceof Error ? err.message : String(err)}`,
+    );
+  }
+}
@@ -0,0 +1,199 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Publishes each index run's node and edge tables to the BigQuery and Snowflake sinks in the manifest
+ * owner: knowgraph-cli
+ * status: experimental
+ * tags: [cli, warehouse, bigquery, snowflake, analytics]
+ * context:
+ *   business_goal: Let analysts join graph data with incident and deployment data in the warehouse
+ *   domain: cli
+ */
+import { basename, dirname, resolve } from 'node:path';
+import {
+  BIGQUERY_BASE_URL,
+  buildDependencyGraph,
+  buildWarehouseSnapshot,
+  createBigQuerySink,
+  createKnowgraphError,
+  createQueryEngine,
+  createSnowflakeSink,
+  credentialsFor,
+  namespaceGraph,
+  readGitSubmodules,
+  snowflakeBaseUrl,
+} from '@know-graph/core';
+import type {
+  DependencyGraph,
+  WarehouseConfig,
+  WarehouseSink,
+  WarehouseSinkConfig,
+  WarehouseSnapshot,
+} from '@know-graph/core';
+import {
+  readDeliveryConfig,
+  readGraphNames,
+  readNamespace,
+  readWarehouseConfig,
+} from './manifest.js';
+import {
+  manifestRetryPolicy,
+  manifestScopedCredentials,
+} from './delivery.js';
+import { getLogger } from './logging.js';
+import { openDatabase } from './db.js';
+
+function describeError(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+/** The API root `sink`'s requests go to, for scoping credentials. */
+function warehouseApiUrl(sink: WarehouseSinkConfig): string {
+  const root =
+    sink.type === 'bigquery'
+      ? BIGQUERY_BASE_URL
+      : snowflakeBaseUrl(sink.account);
+  return `${root}/`;
+}
+
+/**
+ * The sink `sink` configures, retrying as `delivery` says. It
+ * authenticates with the `delivery.auth` entry scoped to its API, or
+ * else with the token in `token_env`, so secrets stay out of the
+ * manifest. Throws a usage error when it has neither.
+ */
+export function createWarehouseSink(
+  configPath: string,
+  config: WarehouseConfig,
+  sink: WarehouseSinkConfig,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): WarehouseSink {
+  const delivery = readDeliveryConfig(configPath);
+  const credentials = credentialsFor(
+    manifestScopedCredentials(configPath, delivery, env),
+    warehouseApiUrl(sink),
+  );
+  const token = env[sink.token_env] || undefined;
+  if (!credentials && !token) {
+    throw createKnowgraphError('usage', `${sink.token_env} is not set`);
+  }
+  const shared = {
+    token,
+    credentials,
+    retry: manifestRetryPolicy(delivery),
+    timeoutMs: config.timeout_ms,
+    tables: { nodes: sink.nodes_table, edges: sink.edges_table },
+  };
+  return sink.type === 'bigquery'
+    ? createBigQuerySink({
+        ...shared,
+        project: sink.project,
+        dataset: sink.dataset,
+        location: sink.location,
+      })
+    : createSnowflakeSink({
+        ...shared,
+        account: sink.account,
+        database: sink.database,
+        schema: sink.schema,
+        warehouse: sink.warehouse,
+        role: sink.role,
+        tokenType: sink.token_type,
+      });
+}
+
+/**
+ * The configured sinks; one with no credentials is skipped with a
+ * warning.
+ */
+export function createWarehouseSinks(
+  configPath: string,
+  config: WarehouseConfig,
+  env: Readonly<Record<string, string | undefined>> = process.env,
+): readonly WarehouseSink[] {
+  return config.sinks.flatMap((sink): WarehouseSink[] => {
+    try {
+      return [createWarehouseSink(configPath, config, sink, env)];
+    } catch (err) {
+      getLogger().warn(
+        `Skipping ${sink.type} warehouse sink: ${describeError(err)}`,
+        { sink: sink.type },
+      );
+      return [];
+    }
+  });
+}
+
+/**
+ * The repository rows are stamped with: `warehouse.repository`, then the
+ * manifest's `namespace`, then the name of the manifest's directory.
+ */
+export function warehouseRepository(
+  configPath: string,
+  config: WarehouseConfig,
+): string {
+  return (
+    config.repository ??
+    readNamespace(configPath) ??
+    basename(dirname(resolve(configPath)))
+  );
+}
+
+function loadGraph(configPath: string, dbPath: string): DependencyGraph {
+  const dbManager = openDatabase(dbPath, configPath, { readOnly: true });
+  try {
+    const entities = createQueryEngine(dbManager).getAll();
+    const graph = buildDependencyGraph(entities, {
+      ...readGraphNames(configPath),
+      submodules: readGitSubmodules(dirname(resolve(configPath))),
+    });
+    const namespace = readNamespace(configPath);
+    return namespace ? namespaceGraph(graph, namespace) : graph;
+  } finally {
+    dbManager.close();
+  }
+}
+
+/**
+ * Replace today's rows for this repository in every warehouse sink with
+ * the graph in `dbPath`. The index itself succeeded, so a sink that fails
+ * is logged as a warning and the rest still publish.
+ */
+export async function publishToWarehouses(
+  configPath: string,
+  dbPath: string,
+): Promise<void> {
+  const config = readWarehouseConfig(configPath);
+  if (config.sinks.length === 0) return;
+  const sinks = createWarehouseSinks(configPath, config);
+  if (sinks.length === 0) return;
+
+  let snapshot: WarehouseSnapshot;
+  try {
+    snapshot = buildWarehouseSnapshot(loadGraph(configPath, dbPath), {
+      repository: warehouseRepository(configPath, config),
+    });
+  } catch (err) {
+    getLogger().warn(
+      `Could not read the graph to publish: ${describeError(err)}`,
+    );
+    return;
+  }
+  const results = await Promise.allSettled(
+    sinks.map((sink) => sink.publish(snapshot)),
+  );
+  results.forEach((result, index) => {
+    const name = sinks[index].name;
+    if (result.status === 'rejected') {
+      getLogger().warn(
+        `Could not publish to ${name}: ${describeError(result.reason)}`,
+        { sink: name },
+      );
+    } else {
+      getLogger().info(
+        `Published ${result.value.nodes} node(s) and ${result.value.edges} edge(s) for ${snapshot.date} to ${name}`,
+        { sink: name, ...result.value, date: snapshot.date },
+      );
+    }
+  });
+}
@@ -0,0 +1,108 @@
+import { describe, it, expect } from 'vitest';
+import {
+  createDefaultLayers,
+  createSidecarLayers,
+  mergeAnnotationLayers,
+  parseSidecar,
+  resolveAnnotation,
+} from '../annotation-layers.js';
+import type { AnnotationLayer } from '../types.js';
+
+const inline: AnnotationLayer = {
+  source: 'inline',
+  location: 'src/api.ts:3',
+  fields: { type: 'service', description: 'API', owner: 'team-a', tags: ['x'] },
+};
+
+const sidecar: AnnotationLayer = {
+  source: 'sidecar',
+  location: 'src/api.ts.knowgraph.yml',
+  fields: { owner: 'team-b', tags: ['x'], status: 'stable' },
+};
+
+describe('mergeAnnotationLayers', () => {
+  it('keeps the highest-priority value and reports differing ones', () => {
+    const merged = mergeAnnotationLayers([sidecar, inline]);
+    expect(merged.fields).toEqual({
+      type: 'service',
+      description: 'API',
+      owner: 'team-a',
+      tags: ['x'],
+      status: 'stable',
+    });
+    expect(merged.conflicts).toEqual([
+      {
+        field: 'owner',
+        kept: { source: 'inline', location: 'src/api.ts:3', value: 'team-a' },
+        overridden: [
+          {
+            source: 'sidecar',
+            location: 'src/api.ts.knowgraph.yml',
+            value: 'team-b',
+          },
+        ],
+      },
+    ]);
+  });
+
+  it('ranks sources by the resolution order and skips unlisted ones', () => {
+    const merged = mergeAnnotationLayers([inline, sidecar], ['sidecar']);
+    expect(merged.fields).toEqual({
+      owner: 'team-b',
+      tags: ['x'],
+      status: 'stable',
+    });
+    expect(merged.conflicts).toEqual([]);
+  });
+});
+
+describe('createDefaultLayers', () => {
+  it('returns matching entries with the last one listed first', () => {
+    const layersFor = createDefaultLayers([
+      { paths: ['src/'], metadata: { owner: 'platform' } },
+      { paths: ['src/pay/'], metadata: { owner: 'payments' } },
+    ]);
+    expect(layersFor('src/pay/api.ts').map((l) => l.location)).toEqual([
+      'annotations.defaults[1]',
+      'annotations.defaults[0]',
+    ]);
+    expect(layersFor('lib/util.ts')).toEqual([]);
+  });
+});
+
+describe('sidecars', () => {
+  it('maps entity names to their fields', () => {
+    const layersFor = createSidecarLayers(
+      'src/api.ts',
+      'Api:\n  owner: team-b\n',
+    );
+    expect(layersFor('Api')).toEqual([
+      {
+        source: 'sidecar',
+        location: 'src/api.ts.knowgraph.yml',
+        fields: { owner: 'team-b' },
+      },
+    ]);
+    expect(layersFor('Other')).toEqual([]);
+    expect(createSidecarLayers('src/api.ts', undefined)('Api')).toEqual([]);
+  });
+
+  it('rejects sidecars that are not mappings of mappings', () => {
+    expect(() => parseSidecar('- Api')).toThrow('must map entity names');
+    expect(() => parseSidecar('Api: team-b')).toThrow('Sidecar entry "Api"');
+    expect(() => createSidecarLayers('src/api.ts', 'Api: 1')).toThrow(
+      'src/api.ts.knowgraph.yml: ',
+    );
+  });
+});
+
+describe('resolveAnnotation', () => {
+  it('keeps the inline metadata when the merge does not validate', () => {
+    const metadata = { type: 'service' as const, description: 'API' };
+    const resolved = resolveAnnotation(metadata, 'src/api.ts:3', [
+      { ...sidecar, fields: { status: 'retired' } },
+    ]);
+    expect(resolved.metadata).toBe(metadata);
+    expect(resolved.error).toContain('status');
+  });
+});
@@ -0,0 +1,190 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads sidecar and default annotations and merges them with inline ones in resolution order, recording every conflict
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [annotations, conflicts, sidecar, defaults, merge]
+ * context:
+ *   business_goal: Surface contradictory annotations instead of letting one silently win
+ *   domain: annotations
+ */
+import ignore from 'ignore';
+import { parse as parseYaml } from 'yaml';
+import { stableStringify } from '../canonical/canonical.js';
+import { validateMetadata } from '../parsers/metadata-extractor.js';
+import type { CoreMetadata, ExtendedMetadata } from '../types/entity.js';
+import type {
+  AnnotationDefaults,
+  AnnotationLayer,
+  AnnotationMerge,
+  AnnotationSource,
+  AnnotationValue,
+  FieldConflict,
+  ResolvedAnnotation,
+} from './types.js';
+
+export const ANNOTATION_SOURCES: readonly AnnotationSource[] = [
+  'inline',
+  'sidecar',
+  'defaults',
+];
+
+/** Sidecars sit next to their source: `src/pay.ts.knowgraph.yml`. */
+export const SIDECAR_SUFFIX = '.knowgraph.yml';
+
+export function sidecarPath(filePath: string): string {
+  return `${filePath}${SIDECAR_SUFFIX}`;
+}
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+/**
+ * Parse a sidecar: annotation fields keyed by the name of the entity they
+ * apply to. Throws unless the YAML is a mapping of mappings.
+ */
+export function parseSidecar(
+  content: string,
+): ReadonlyMap<string, Readonly<Record<string, unknown>>> {
+  const parsed: unknown = parseYaml(content) ?? {};
+  if (!isRecord(parsed)) {
+    throw new Error('Sidecar must map entity names to annotation fields');
+  }
+  const entries = new Map<string, Readonly<Record<string, unknown>>>();
+  for (const [name, fields] of Object.entries(parsed)) {
+    if (!isRecord(fields)) {
+      throw new Error(`Sidecar entry "${name}" must be a mapping of fields`);
+    }
+    entries.set(name, fields);
+  }
+  return entries;
+}
+
+/**
+ * A lookup of the sidecar layer for each entity in `filePath`, given the
+ * sidecar's content (undefined when it has none). Throws with the sidecar
+ * path when the content does not parse.
+ */
+export function createSidecarLayers(
+  filePath: string,
+  content: string | undefined,
+): (name: string) => readonly AnnotationLayer[] {
+  if (content === undefined) return () => [];
+  const location = sidecarPath(filePath);
+  let entries: ReadonlyMap<string, Readonly<Record<string, unknown>>>;
+  try {
+    entries = parseSidecar(content);
+  } catch (err) {
+    const message = err instanceof Error ? err.message : String(err);
+    throw new Error(`${location}: ${message}`);
+  }
+  return (name) => {
+    const fields = entries.get(name);
+    return fields ? [{ source: 'sidecar', location, fields }] : [];
+  };
+}
+
+/**
+ * A lookup of the `defaults` layers for a file. When several entries
+ * match, later ones come first, so the most specific entry listed last
+ * wins, as in CODEOWNERS.
+ */
+export function createDefaultLayers(
+  defaults: readonly AnnotationDefaults[] = [],
+): (filePath: string) => readonly AnnotationLayer[] {
+  const entries = defaults
+    .map((entry, i) => ({
+      matcher: ignore().add([...entry.paths]),
+      layer: {
+        source: 'defaults' as const,
+        location: `annotations.defaults[${i}]`,
+        fields: entry.metadata,
+      },
+    }))
+    .reverse();
+  return (filePath) =>
+    entries
+      .filter((entry) => entry.matcher.ignores(filePath))
+      .map((entry) => entry.layer);
+}
+
+/**
+ * Merge `layers` field by field. Layers are ranked by the position of
+ * their source in `resolution` (then by their own order), and each field
+ * takes the value from the highest-ranked layer that sets it; layers whose
+ * source is not listed are ignored. Fields merge whole, so one layer's
+ * `context` replaces another's. Every field that lower-ranked layers set
+ * to a different value is reported as a conflict.
+ */
+export function mergeAnnotationLayers(
+  layers: readonly AnnotationLayer[],
+  resolution: readonly AnnotationSource[] = ANNOTATION_SOURCES,
+): AnnotationMerge {
+  const ranked = layers
+    .filter((layer) => resolution.includes(layer.source))
+    .map((layer, i) => ({ layer, i }))
+    .sort(
+      (a, b) =>
+        resolution.indexOf(a.layer.source) -
+          resolution.indexOf(b.layer.source) || a.i - b.i,
+    )
+    .map(({ layer }) => layer);
+
+  const fields: Record<string, unknown> = {};
+  const kept = new Map<string, AnnotationValue>();
+  const overridden = new Map<string, AnnotationValue[]>();
+  for (const layer of ranked) {
+    for (const [field, value] of Object.entries(layer.fields)) {
+      if (value === undefined) continue;
+      const current = kept.get(field);
+      const entry = { source: layer.source, location: layer.location, value };
+      if (!current) {
+        kept.set(field, entry);
+        fields[field] = value;
+      } else if (
+        stableStringify(current.value, false) !== stableStringify(value, false)
+      ) {
+        overridden.set(field, [...(overridden.get(field) ?? []), entry]);
+      }
+    }
+  }
+
+  const conflicts: FieldConflict[] = [];
+  for (const [field, values] of overridden) {
+    const value = kept.get(field);
+    if (value) conflicts.push({ field, kept: value, overridden: values });
+  }
+  return { fields, conflicts };
+}
+
+/**
+ * The metadata an inline annotation at `location` ends up with once
+ * `layers` (its sidecar entry and defaults) are merged in, with the
+ * conflicts found. When the merged fields fail validation the inline
+ * metadata is kept and `error` says why.
+ */
+export function resolveAnnotation(
+  inline: CoreMetadata | ExtendedMetadata,
+  location: string,
+  layers: readonly AnnotationLayer[],
+  resolution?: readonly AnnotationSource[],
+): ResolvedAnnotation {
+  if (layers.length === 0 && resolution === undefined) {
+    return { metadata: inline, conflicts: [] };
+  }
+  const merged = mergeAnnotationLayers(
+    [{ source: 'inline', location, fields: inline }, ...layers],
+    resolution,
+  );
+  const { metadata, errors } = validateMetadata(merged.fields);
+  if (!metadata) {
+    return {
+      metadata: inline,
+      conflicts: [],
+      error: `layered annotation is invalid (${errors[0]?.message ?? 'no fields'})`,
+    };
+  }
+  return { metadata, conflicts: merged.conflicts };
+}
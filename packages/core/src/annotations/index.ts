@@ -0,0 +1,21 @@
+export type {
+  AnnotationConflict,
+  AnnotationDefaults,
+  AnnotationLayer,
+  AnnotationLayerOptions,
+  AnnotationMerge,
+  AnnotationSource,
+  AnnotationValue,
+  FieldConflict,
+  ResolvedAnnotation,
+} from './types.js';
+export {
+  ANNOTATION_SOURCES,
+  SIDECAR_SUFFIX,
+  createDefaultLayers,
+  createSidecarLayers,
+  mergeAnnotationLayers,
+  parseSidecar,
+  resolveAnnotation,
+  sidecarPath,
+} from './annotation-layers.js';
@@ -0,0 +1,78 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for layering inline, sidecar, and default annotations and the conflicts between them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [annotations, conflicts, sidecar, defaults, types, interface]
+ * context:
+ *   business_goal: Let teams add annotations outside the source without losing track of where each came from
+ *   domain: annotations
+ */
+import type { CoreMetadata, ExtendedMetadata } from '../types/entity.js';
+
+/**
+ * Where annotation fields come from: the `@knowgraph` block itself, a
+ * sidecar file next to the source, or manifest defaults for its path.
+ */
+export type AnnotationSource = 'inline' | 'sidecar' | 'defaults';
+
+/** The annotation fields one place sets for an entity. */
+export interface AnnotationLayer {
+  readonly source: AnnotationSource;
+  /**
+   * Where the fields are written: `file:line` for inline annotations, the
+   * sidecar path, or the manifest entry (`annotations.defaults[0]`).
+   */
+  readonly location: string;
+  readonly fields: Readonly<Record<string, unknown>>;
+}
+
+export interface AnnotationValue {
+  readonly source: AnnotationSource;
+  readonly location: string;
+  readonly value: unknown;
+}
+
+/** A field that two or more layers set to different values. */
+export interface FieldConflict {
+  readonly field: string;
+  /** The value kept: the one from the layer first in resolution order. */
+  readonly kept: AnnotationValue;
+  /** The differing values it overrode, in resolution order. */
+  readonly overridden: readonly AnnotationValue[];
+}
+
+export interface AnnotationConflict extends FieldConflict {
+  readonly filePath: string;
+  /** Name of the annotated entity. */
+  readonly entity: string;
+}
+
+export interface AnnotationMerge {
+  readonly fields: Readonly<Record<string, unknown>>;
+  readonly conflicts: readonly FieldConflict[];
+}
+
+export interface ResolvedAnnotation {
+  readonly metadata: CoreMetadata | ExtendedMetadata;
+  readonly conflicts: readonly FieldConflict[];
+  /** Why the merged fields were rejected in favour of the inline ones. */
+  readonly error?: string;
+}
+
+/** Default annotation fields for files, as in `annotations.defaults`. */
+export interface AnnotationDefaults {
+  /** Gitignore-style patterns, relative to the scan root. */
+  readonly paths: readonly string[];
+  readonly metadata: Readonly<Record<string, unknown>>;
+}
+
+export interface AnnotationLayerOptions {
+  /**
+   * Sources from highest to lowest priority. Sources left out are not
+   * read. Default `['inline', 'sidecar', 'defaults']`.
+   */
+  readonly resolution?: readonly AnnotationSource[];
+  readonly defaults?: readonly AnnotationDefaults[];
+}
@@ -0,0 +1,152 @@
+import { describe, it, expect } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { askGraph, questionTerms, questionTypes } from '../ask.js';
+import type { AnswerModel, Embedder } from '../types.js';
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  description: string,
+  dependencies: { services?: string[]; databases?: string[] } = {},
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType: 'service',
+    description,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'identity-team',
+    status: 'stable',
+    metadata: { type: 'service', description, dependencies },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const entities = [
+  makeEntity(
+    'SessionStore',
+    'src/session-store.ts',
+    'Stores user sessions in Redis',
+    { databases: ['redis-sessions'] },
+  ),
+  makeEntity(
+    'AuthService',
+    'src/auth.ts',
+    'Authenticates users and issues sessions',
+    { services: ['SessionStore'], databases: ['redis-sessions'] },
+  ),
+  makeEntity(
+    'expireSessions',
+    'src/jobs.ts',
+    'Expires stale sessions',
+    { databases: ['redis-sessions'] },
+    { entityType: 'function' },
+  ),
+  makeEntity('Checkout', 'src/checkout.ts', 'Runs checkout for a cart'),
+];
+const graph = buildDependencyGraph(entities);
+
+describe('question parsing', () => {
+  it('keeps search terms and pulls out the entity types named', () => {
+    const question = 'Which services can write to redis-sessions?';
+    expect(questionTerms(question)).toEqual(['write', 'redi', 'session']);
+    expect(questionTypes(question)).toEqual(['service']);
+    expect(questionTypes('list the classes and endpoints')).toEqual([
+      'class',
+      'api_endpoint',
+    ]);
+  });
+});
+
+describe('askGraph', () => {
+  it('answers offline from the best match and its dependents', async () => {
+    const result = await askGraph(
+      entities,
+      graph,
+      'Which services can write to redis-sessions?',
+    );
+    expect(result.mode).toBe('offline');
+    expect(result.matches[0].node.name).toBe('redis-sessions');
+    expect(result.matches[0].kind).toBe('database');
+    expect(result.matches.map((m) => m.node.name)).not.toContain('Checkout');
+    expect(result.answer).toContain('redis-sessions (database, external)');
+    expect(result.answer).toContain(
+      'Used by (service): SessionStore (src/session-store.ts), AuthService (src/auth.ts)',
+    );
+    expect(result.answer).toContain('Also relevant:');
+  });
+
+  it('describes an entity the question names', async () => {
+    const result = await askGraph(
+      entities,
+      graph,
+      'What does AuthService depend on?',
+    );
+    expect(result.answer).toContain(
+      'AuthService (service, src/auth.ts:1): Authenticates users and issues sessions',
+    );
+    expect(result.answer).toContain('Owner: identity-team');
+    expect(result.answer).toContain(
+      'Depends on: SessionStore (src/session-store.ts), redis-sessions',
+    );
+  });
+
+  it('says when nothing matches', async () => {
+    const result = await askGraph(entities, graph, 'What about kubernetes?');
+    expect(result.matches).toEqual([]);
+    expect(result.answer).toBe('No indexed entities match the question.');
+  });
+
+  it('keeps only the best matches', async () => {
+    const result = await askGraph(entities, graph, 'sessions', { limit: 2 });
+    expect(result.matches).toHaveLength(2);
+  });
+
+  it('ranks by embeddings when given an embedder', async () => {
+    const embedder: Embedder = {
+      embed: async (texts) =>
+        texts.map((text, index) =>
+          index === 0 || text.includes('cart') ? [1, 0] : [0, 1],
+        ),
+    };
+    const result = await askGraph(
+      entities,
+      graph,
+      'Where do we take payment?',
+      { embedder },
+    );
+    expect(result.matches.map((m) => m.node.name)).toEqual(['Checkout']);
+  });
+
+  it('composes the answer with a model from the retrieved context', async () => {
+    const prompts: string[] = [];
+    const model: AnswerModel = {
+      complete: async (prompt) => {
+        prompts.push(prompt);
+        return 'SessionStore and AuthService';
+      },
+    };
+    const question = 'Which services can write to redis-sessions?';
+    const result = await askGraph(entities, graph, question, { model });
+    expect(result.mode).toBe('llm');
+    expect(result.answer).toBe('SessionStore and AuthService');
+    expect(prompts[0]).toContain(`Question: ${question}`);
+    expect(prompts[0]).toContain('- redis-sessions (database) at external');
+    expect(prompts[0]).toContain(
+      '  Used by: SessionStore (src/session-store.ts), AuthService (src/auth.ts), expireSessions (src/jobs.ts)',
+    );
+  });
+});
@@ -0,0 +1,71 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import {
+  createOpenAiCompatibleEmbedder,
+  createOpenAiCompatibleModel,
+} from '../llm-client.js';
+
+function mockFetch(body: unknown, ok = true): ReturnType<typeof vi.fn> {
+  const fn = vi.fn(async () => ({
+    ok,
+    status: ok ? 200 : 401,
+    statusText: ok ? 'OK' : 'Unauthorized',
+    json: async () => body,
+  }));
+  vi.stubGlobal('fetch', fn);
+  return fn;
+}
+
+describe('OpenAI-compatible clients', () => {
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('completes a prompt with the first choice', async () => {
+    const fn = mockFetch({ choices: [{ message: { content: ' Two. ' } }] });
+    const model = createOpenAiCompatibleModel({
+      baseUrl: 'http://llm:8000/v1/',
+      model: 'small',
+      apiKey: 'secret',
+    });
+    expect(await model.complete('How many?')).toBe('Two.');
+
+    const [url, init] = fn.mock.calls[0] as unknown as [string, RequestInit];
+    expect(url).toBe('http://llm:8000/v1/chat/completions');
+    expect((init.headers as Record<string, string>).Authorization).toBe(
+      'Bearer secret',
+    );
+    expect(JSON.parse(init.body as string)).toMatchObject({
+      model: 'small',
+      messages: [{ role: 'user', content: 'How many?' }],
+    });
+  });
+
+  it('returns embeddings in input order', async () => {
+    mockFetch({
+      data: [
+        { index: 1, embedding: [0, 1] },
+        { index: 0, embedding: [1, 0] },
+      ],
+    });
+    const embedder = createOpenAiCompatibleEmbedder({
+      baseUrl: 'http://llm:8000/v1',
+      model: 'embed',
+    });
+    expect(await embedder.embed(['a', 'b'])).toEqual([
+      [1, 0],
+      [0, 1],
+    ]);
+  });
+
+  it('throws an I/O error on HTTP errors', async () => {
+    mockFetch({}, false);
+    const model = createOpenAiCompatibleModel({
+      baseUrl: 'http://llm:8000/v1',
+      model: 'small',
+    });
+    await expect(model.complete('hi')).rejects.toMatchObject({
+      kind: 'io',
+      message: 'LLM API error: 401 Unauthorized',
+    });
+  });
+});
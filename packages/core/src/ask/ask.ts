@@ -0,0 +1,258 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Retrieves graph nodes relevant to a question and composes an answer offline or with a language model
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [ask, rag, retrieval, tfidf, embeddings]
+ * context:
+ *   business_goal: Ground answers in the graph so they cite real modules rather than guesses
+ *   domain: ask
+ */
+import {
+  buildTfIdfVectors,
+  cosineSimilarity,
+  tokenize,
+} from '../duplicates/tfidf.js';
+import type { DependencyGraph, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { EntityType } from '../types/entity.js';
+import type { AskMatch, AskOptions, AskResult } from './types.js';
+
+const DEFAULT_LIMIT = 5;
+
+// Interrogatives and auxiliaries, after tokenize() has stemmed them
+const QUESTION_WORDS = new Set([
+  'which',
+  'what',
+  'who',
+  'whom',
+  'where',
+  'when',
+  'how',
+  'why',
+  'can',
+  'could',
+  'doe',
+  'did',
+  'should',
+  'would',
+  'will',
+  'there',
+]);
+
+const TYPE_WORDS: ReadonlyMap<string, EntityType> = new Map([
+  ['module', 'module'],
+  ['class', 'class'],
+  ['classe', 'class'],
+  ['function', 'function'],
+  ['method', 'method'],
+  ['service', 'service'],
+  ['endpoint', 'api_endpoint'],
+  ['interface', 'interface'],
+  ['enum', 'enum'],
+]);
+
+/**
+ * The question's search terms, stemmed, without question words or the
+ * entity types it names (those filter the answer rather than rank nodes).
+ */
+export function questionTerms(question: string): readonly string[] {
+  return [...new Set(tokenize(question))].filter(
+    (term) => !QUESTION_WORDS.has(term) && !TYPE_WORDS.has(term),
+  );
+}
+
+/** The entity types the question names, e.g. `service` for "which services". */
+export function questionTypes(question: string): readonly EntityType[] {
+  const types = tokenize(question).flatMap((term) => {
+    const type = TYPE_WORDS.get(term);
+    return type ? [type] : [];
+  });
+  return [...new Set(types)];
+}
+
+function mentionedNames(question: string): ReadonlySet<string> {
+  return new Set(
+    question
+      .toLowerCase()
+      .split(/[^a-z0-9_.:/-]+/)
+      .map((word) => word.replace(/[.:-]+$/, '')),
+  );
+}
+
+function nodeText(node: GraphNode, entity: StoredEntity | undefined): string {
+  if (!entity) return `${node.name} ${node.name}`;
+  return [
+    entity.name,
+    entity.name,
+    entity.parent ?? '',
+    ...entity.tags,
+    entity.description,
+    node.domain ?? '',
+  ].join(' ');
+}
+
+function cosine(a: readonly number[], b: readonly number[]): number {
+  let dot = 0;
+  let normA = 0;
+  let normB = 0;
+  for (let i = 0; i < Math.min(a.length, b.length); i++) {
+    dot += a[i] * b[i];
+    normA += a[i] * a[i];
+    normB += b[i] * b[i];
+  }
+  return normA && normB ? dot / Math.sqrt(normA * normB) : 0;
+}
+
+function describeNode(node: GraphNode): string {
+  return node.filePath ? `${node.name} (${node.filePath})` : node.name;
+}
+
+function listNodes(nodes: readonly GraphNode[]): string {
+  return nodes.length > 0 ? nodes.map(describeNode).join(', ') : 'none';
+}
+
+function location(match: AskMatch): string {
+  if (!match.entity) return 'external';
+  return `${match.entity.filePath}:${match.entity.line}`;
+}
+
+/**
+ * A deterministic answer built from the best match: what it is, what uses
+ * it (only the entity types the question names, if any), and what it uses.
+ */
+export function composeOfflineAnswer(
+  matches: readonly AskMatch[],
+  types: readonly EntityType[] = [],
+): string {
+  const [best, ...rest] = matches;
+  if (!best) return 'No indexed entities match the question.';
+
+  const heading = `${best.node.name} (${best.kind}, ${location(best)})`;
+  const lines = [
+    best.entity ? `${heading}: ${best.entity.description}` : heading,
+  ];
+  if (best.entity?.owner) lines.push(`Owner: ${best.entity.owner}`);
+  const dependents =
+    types.length > 0
+      ? best.dependents.filter(
+          (node) => node.entityType && types.includes(node.entityType),
+        )
+      : best.dependents;
+  const usedBy = types.length > 0 ? `Used by (${types.join(', ')})` : 'Used by';
+  lines.push(
+    `${usedBy}: ${listNodes(dependents)}`,
+    `Depends on: ${listNodes(best.dependencies)}`,
+  );
+  if (rest.length > 0) {
+    lines.push(`Also relevant: ${rest.map((m) => m.node.name).join(', ')}`);
+  }
+  return lines.join('\n');
+}
+
+/** The prompt sent to a language model: the question and matches as context. */
+export function buildAskPrompt(
+  question: string,
+  matches: readonly AskMatch[],
+): string {
+  const context = matches.flatMap((match) => {
+    const owner = match.entity?.owner ? `, owned by ${match.entity.owner}` : '';
+    const description = match.entity ? `: ${match.entity.description}` : '';
+    return [
+      `- ${match.node.name} (${match.kind}) at ${location(match)}${owner}${description}`,
+      `  Used by: ${listNodes(match.dependents)}`,
+      `  Depends on: ${listNodes(match.dependencies)}`,
+    ];
+  });
+  return [
+    'Answer the question about a codebase using only the context below, which comes from its knowledge graph.',
+    'Name the entities your answer relies on. If the context does not answer the question, say so.',
+    '',
+    `Question: ${question}`,
+    '',
+    'Context:',
+    ...(context.length > 0 ? context : ['(no matching entities)']),
+  ].join('\n');
+}
+
+/**
+ * Answer `question` from the graph. Nodes are ranked by TF-IDF similarity
+ * between the question and each node's name, tags, and description, with a
+ * bonus for nodes the question names outright; with an embedder, semantic
+ * similarity is added to the score. The answer is composed by the model
+ * when one is given and deterministically otherwise.
+ */
+export async function askGraph(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  question: string,
+  options: AskOptions = {},
+): Promise<AskResult> {
+  const limit = options.limit ?? DEFAULT_LIMIT;
+  const byId = new Map(entities.map((entity) => [entity.id, entity]));
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const terms = questionTerms(question);
+  const types = questionTypes(question);
+
+  const texts = graph.nodes.map((node) => nodeText(node, byId.get(node.id)));
+  const documents = texts.map(tokenize);
+  const vectors = buildTfIdfVectors([...documents, terms]);
+  const questionVector = vectors[vectors.length - 1];
+  const mentioned = mentionedNames(question);
+  const semantic = options.embedder
+    ? await options.embedder.embed([question, ...texts])
+    : undefined;
+
+  const scored = graph.nodes.map((node, index) => {
+    const keyword = terms.length
+      ? cosineSimilarity(questionVector, vectors[index])
+      : 0;
+    const named = mentioned.has(node.name.toLowerCase()) ? 1 : 0;
+    const similarity = semantic ? cosine(semantic[0], semantic[index + 1]) : 0;
+    const found = new Set(documents[index]);
+    return {
+      node,
+      score: keyword + named + similarity,
+      terms: terms.filter((term) => found.has(term)),
+    };
+  });
+  const ranked = scored
+    .filter((entry) => entry.score > 0)
+    .sort((a, b) => b.score - a.score || a.node.name.localeCompare(b.node.name))
+    .slice(0, limit);
+
+  const matches: AskMatch[] = ranked.map(({ node, score, terms: found }) => {
+    const incoming = graph.edges.filter((edge) => edge.to === node.id);
+    const outgoing = graph.edges.filter((edge) => edge.from === node.id);
+    const entity = byId.get(node.id) ?? null;
+    const resolve = (id: string): GraphNode[] => {
+      const target = nodes.get(id);
+      return target ? [target] : [];
+    };
+    return {
+      node,
+      entity,
+      kind: node.entityType ?? incoming[0]?.kind ?? 'module',
+      score,
+      terms: found,
+      dependents: incoming.flatMap((edge) => resolve(edge.from)),
+      dependencies: outgoing.flatMap((edge) => resolve(edge.to)),
+    };
+  });
+
+  if (options.model) {
+    const answer = await options.model.complete(
+      buildAskPrompt(question, matches),
+    );
+    return { question, mode: 'llm', terms, types, matches, answer };
+  }
+  return {
+    question,
+    mode: 'offline',
+    terms,
+    types,
+    matches,
+    answer: composeOfflineAnswer(matches, types),
+  };
+}
@@ -0,0 +1,20 @@
+export type {
+  AnswerModel,
+  AskMatch,
+  AskMode,
+  AskOptions,
+  AskResult,
+  Embedder,
+  OpenAiCompatibleOptions,
+} from './types.js';
+export {
+  askGraph,
+  buildAskPrompt,
+  composeOfflineAnswer,
+  questionTerms,
+  questionTypes,
+} from './ask.js';
+export {
+  createOpenAiCompatibleEmbedder,
+  createOpenAiCompatibleModel,
+} from './llm-client.js';
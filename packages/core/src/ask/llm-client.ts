@@ -0,0 +1,99 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Fetch-based clients for OpenAI-compatible chat completion and embedding APIs
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [ask, llm, embeddings, api]
+ * context:
+ *   business_goal: Let teams bring their own language model without extra dependencies
+ *   domain: ask
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type {
+  AnswerModel,
+  Embedder,
+  OpenAiCompatibleOptions,
+} from './types.js';
+
+interface ChatCompletionResponse {
+  readonly choices?: readonly {
+    readonly message?: { readonly content?: string | null };
+  }[];
+}
+
+interface EmbeddingResponse {
+  readonly data?: readonly {
+    readonly index: number;
+    readonly embedding: readonly number[];
+  }[];
+}
+
+function createPoster(options: OpenAiCompatibleOptions) {
+  const baseUrl = options.baseUrl.replace(/\/+$/, '');
+  const headers: Record<string, string> = {
+    Accept: 'application/json',
+    'Content-Type': 'application/json',
+  };
+  if (options.apiKey) headers.Authorization = `Bearer ${options.apiKey}`;
+
+  return async <T>(path: string, body: unknown): Promise<T> => {
+    const response = await fetch(`${baseUrl}${path}`, {
+      method: 'POST',
+      headers,
+      body: JSON.stringify(body),
+    });
+    if (!response.ok) {
+      throw createKnowgraphError(
+        'io',
+        `LLM API error: ${response.status} ${response.statusText}`,
+      );
+    }
+    return (await response.json()) as T;
+  };
+}
+
+/** Answer with the first choice of a `/chat/completions` call. */
+export function createOpenAiCompatibleModel(
+  options: OpenAiCompatibleOptions,
+): AnswerModel {
+  const post = createPoster(options);
+  return {
+    async complete(prompt: string): Promise<string> {
+      const body = await post<ChatCompletionResponse>('/chat/completions', {
+        model: options.model,
+        messages: [{ role: 'user', content: prompt }],
+        temperature: 0,
+      });
+      const content = body.choices?.[0]?.message?.content;
+      if (typeof content !== 'string') {
+        throw createKnowgraphError('io', 'LLM API returned no answer');
+      }
+      return content.trim();
+    },
+  };
+}
+
+/** Embed texts with one `/embeddings` call. */
+export function createOpenAiCompatibleEmbedder(
+  options: OpenAiCompatibleOptions,
+): Embedder {
+  const post = createPoster(options);
+  return {
+    async embed(texts: readonly string[]) {
+      if (texts.length === 0) return [];
+      const body = await post<EmbeddingResponse>('/embeddings', {
+        model: options.model,
+        input: texts,
+      });
+      const data = [...(body.data ?? [])].sort((a, b) => a.index - b.index);
+      if (data.length !== texts.length) {
+        throw createKnowgraphError(
+          'io',
+          `Embedding API returned ${data.length} vectors for ${texts.length} texts`,
+        );
+      }
+      return data.map((item) => item.embedding);
+    },
+  };
+}
@@ -0,0 +1,66 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Types for answering natural-language questions from the dependency graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [ask, rag, retrieval, types]
+ * context:
+ *   business_goal: Let the CLI and server answer questions through one shared shape
+ *   domain: ask
+ */
+import type { DependencyKind, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { EntityType } from '../types/entity.js';
+
+/** Turns texts into dense vectors, one per text, in order. */
+export interface Embedder {
+  embed(texts: readonly string[]): Promise<readonly (readonly number[])[]>;
+}
+
+/** Completes a prompt with a language model. */
+export interface AnswerModel {
+  complete(prompt: string): Promise<string>;
+}
+
+/** An OpenAI-compatible API, such as OpenAI, Ollama, or vLLM. */
+export interface OpenAiCompatibleOptions {
+  /** Base URL including the version, e.g. `https://api.openai.com/v1`. */
+  readonly baseUrl: string;
+  readonly model: string;
+  readonly apiKey?: string;
+}
+
+export interface AskMatch {
+  readonly node: GraphNode;
+  /** The indexed entity behind the node; null for external dependencies. */
+  readonly entity: StoredEntity | null;
+  /** The entity type, or the dependency kind for external nodes. */
+  readonly kind: EntityType | DependencyKind;
+  readonly score: number;
+  /** Question terms found in the node's name, tags, or description. */
+  readonly terms: readonly string[];
+  readonly dependents: readonly GraphNode[];
+  readonly dependencies: readonly GraphNode[];
+}
+
+export type AskMode = 'offline' | 'llm';
+
+export interface AskResult {
+  readonly question: string;
+  readonly mode: AskMode;
+  readonly terms: readonly string[];
+  /** Entity types the question names ("which services ..."). */
+  readonly types: readonly EntityType[];
+  readonly matches: readonly AskMatch[];
+  readonly answer: string;
+}
+
+export interface AskOptions {
+  /** Number of matches to retrieve (default 5). */
+  readonly limit?: number;
+  /** Ranks by semantic similarity as well as keywords. */
+  readonly embedder?: Embedder;
+  /** Composes the answer; without one the answer is built offline. */
+  readonly model?: AnswerModel;
+}
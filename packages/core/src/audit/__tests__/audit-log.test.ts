@@ -0,0 +1,244 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { spawnSync } from 'node:child_process';
+import {
+  existsSync,
+  mkdirSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { GraphNode } from '../../graph/types.js';
+import {
+  GENESIS_HASH,
+  appendAuditEntry,
+  filterAuditEntries,
+  formatAuditCsv,
+  readAuditLog,
+  verifyAuditLog,
+} from '../audit-log.js';
+import { diffText, fileChange, graphChange } from '../diff.js';
+import type { AuditRecord } from '../types.js';
+
+function record(command: string, actor = 'alice'): AuditRecord {
+  return {
+    actor,
+    command,
+    args: [command, '--fix'],
+    cwd: '/repo',
+    outcome: 'success',
+    changes: [{ target: 'src/pay.ts', summary: 'modified' }],
+  };
+}
+
+describe('audit log', () => {
+  let dir: string;
+  let logPath: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-audit-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(dir, { recursive: true });
+    logPath = join(dir, '.knowgraph', 'audit.jsonl');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('chains appended entries by hash', () => {
+    const first = appendAuditEntry(logPath, record('lint'));
+    const second = appendAuditEntry(logPath, record('index'));
+    expect(first.seq).toBe(1);
+    expect(first.prevHash).toBe(GENESIS_HASH);
+    expect(second.seq).toBe(2);
+    expect(second.prevHash).toBe(first.hash);
+    expect(readAuditLog(logPath)).toEqual([first, second]);
+    expect(verifyAuditLog(readAuditLog(logPath))).toEqual({
+      valid: true,
+      entries: 2,
+    });
+    expect(existsSync(`${logPath}.lock`)).toBe(false);
+  });
+
+  it('appends after a run that died holding the log', () => {
+    const { pid } = spawnSync(process.execPath, ['-e', '']);
+    mkdirSync(join(dir, '.knowgraph'), { recursive: true });
+    writeFileSync(`${logPath}.lock`, String(pid));
+    expect(appendAuditEntry(logPath, record('lint')).seq).toBe(1);
+    expect(existsSync(`${logPath}.lock`)).toBe(false);
+  });
+
+  it('treats a missing log as empty', () => {
+    expect(readAuditLog(logPath)).toEqual([]);
+  });
+
+  it('detects edited entries', () => {
+    appendAuditEntry(logPath, record('lint'));
+    appendAuditEntry(logPath, record('index'));
+    const lines = readFileSync(logPath, 'utf-8').replace('alice', 'mallory');
+    writeFileSync(logPath, lines);
+    const result = verifyAuditLog(readAuditLog(logPath));
+    expect(result.valid).toBe(false);
+    expect(result.brokenAt).toBe(1);
+    expect(result.reason).toContain('hash');
+  });
+
+  it('detects removed entries', () => {
+    appendAuditEntry(logPath, record('lint'));
+    appendAuditEntry(logPath, record('index'));
+    appendAuditEntry(logPath, record('sync'));
+    const lines = readFileSync(logPath, 'utf-8').split('\n');
+    writeFileSync(logPath, [lines[0], lines[2]].join('\n'));
+    const result = verifyAuditLog(readAuditLog(logPath));
+    expect(result.valid).toBe(false);
+    expect(result.brokenAt).toBe(3);
+  });
+
+  it('rejects lines that are not JSON', () => {
+    writeFileSync(join(dir, 'bad.jsonl'), '{');
+    expect(() => readAuditLog(join(dir, 'bad.jsonl'))).toThrow(
+      'bad.jsonl:1',
+    );
+  });
+
+  it('filters by time, actor, and command', () => {
+    appendAuditEntry(
+      logPath,
+      record('lint'),
+      new Date('2026-01-01T00:00:00Z'),
+    );
+    appendAuditEntry(
+      logPath,
+      record('index', 'bob'),
+      new Date('2026-02-01T00:00:00Z'),
+    );
+    const entries = readAuditLog(logPath);
+    expect(
+      filterAuditEntries(entries, { since: '2026-01-15' }).map((e) => e.seq),
+    ).toEqual([2]);
+    expect(
+      filterAuditEntries(entries, { until: '2026-02-01T00:00:00Z' }).map(
+        (e) => e.seq,
+      ),
+    ).toEqual([1]);
+    expect(filterAuditEntries(entries, { actor: 'bob' })).toHaveLength(1);
+    expect(filterAuditEntries(entries, { command: 'lint' })).toHaveLength(1);
+  });
+
+  it('exports CSV with quoted fields', () => {
+    const entry = appendAuditEntry(logPath, {
+      ...record('lint'),
+      actor: 'Doe, Jane',
+    });
+    const [header, row] = formatAuditCsv([entry]).trimEnd().split('\n');
+    expect(header).toBe(
+      'seq,timestamp,actor,command,args,outcome,changes,hash',
+    );
+    expect(row).toContain('"Doe, Jane"');
+    expect(row).toContain('src/pay.ts: modified');
+    expect(row.endsWith(entry.hash)).toBe(true);
+  });
+});
+
+describe('diffText', () => {
+  it('renders a unified diff with context', () => {
+    const before = ['a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'].join('\n') + '\n';
+    const after = before.replace('e\n', 'E\n');
+    expect(diffText('src/x.ts', before, after)).toBe(
+      [
+        '--- a/src/x.ts',
+        '+++ b/src/x.ts',
+        '@@ -2,7 +2,7 @@',
+        ' b',
+        ' c',
+        ' d',
+        '-e',
+        '+E',
+        ' f',
+        ' g',
+        ' h',
+      ].join('\n'),
+    );
+  });
+
+  it('splits distant changes into hunks', () => {
+    const lines = Array.from({ length: 20 }, (_, i) => `line ${i}`);
+    const before = lines.join('\n');
+    const after = lines
+      .map((line, i) => (i === 1 || i === 18 ? `${line}!` : line))
+      .join('\n');
+    const hunks = diffText('f', before, after)
+      .split('\n')
+      .filter((line) => line.startsWith('@@'));
+    expect(hunks).toEqual(['@@ -1,5 +1,5 @@', '@@ -16,5 +16,5 @@']);
+  });
+
+  it('diffs created files against /dev/null', () => {
+    expect(diffText('.knowgraph.yml', undefined, 'version: "1.0"\n')).toBe(
+      [
+        '--- /dev/null',
+        '+++ b/.knowgraph.yml',
+        '@@ -0,0 +1,1 @@',
+        '+version: "1.0"',
+      ].join('\n'),
+    );
+  });
+
+  it('returns nothing for equal content', () => {
+    expect(diffText('f', 'same\n', 'same\n')).toBe('');
+    expect(fileChange('f', 'same\n', 'same\n')).toBeUndefined();
+  });
+});
+
+describe('graphChange', () => {
+  const node: GraphNode = {
+    id: 'n1',
+    name: 'Payments',
+    entityType: 'service',
+    external: false,
+    filePath: 'src/pay.ts',
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+
+  it('summarizes node events', () => {
+    expect(
+      graphChange([
+        { type: 'node_added', node },
+        { type: 'node_removed', node: { ...node, name: 'Legacy' } },
+      ]),
+    ).toEqual({
+      target: 'graph',
+      summary: '1 added, 0 updated, 1 removed',
+      diff: '+ Payments (service)\n- Legacy (service)',
+    });
+  });
+
+  it('shows renames with the old name', () => {
+    expect(
+      graphChange([
+        {
+          type: 'node_renamed',
+          node,
+          previous: { ...node, id: 'n0', name: 'Billing' },
+        },
+      ]),
+    ).toEqual({
+      target: 'graph',
+      summary: '0 added, 0 updated, 0 removed, 1 renamed',
+      diff: '> Billing -> Payments (service)',
+    });
+  });
+
+  it('omits the diff when nothing changed', () => {
+    expect(graphChange([])).toEqual({
+      target: 'graph',
+      summary: '0 added, 0 updated, 0 removed',
+    });
+  });
+});
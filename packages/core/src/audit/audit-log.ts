@@ -0,0 +1,163 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Appends, reads, verifies, and exports the hash-chained JSON Lines audit log
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [audit, compliance, soc2, jsonl, tamper-evidence]
+ * context:
+ *   business_goal: Produce audit evidence of graph and annotation changes that cannot be silently edited
+ *   domain: audit
+ */
+import { createHash } from 'node:crypto';
+import { appendFileSync, existsSync, mkdirSync, readFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { stableStringify } from '../canonical/canonical.js';
+import { csvField } from '../finops/csv.js';
+import { withFileLock } from '../locking/file-lock.js';
+import type {
+  AuditEntry,
+  AuditFilter,
+  AuditRecord,
+  AuditVerification,
+} from './types.js';
+
+export const GENESIS_HASH = '0'.repeat(64);
+
+function hashEntry(entry: Omit<AuditEntry, 'hash'>): string {
+  return createHash('sha256')
+    .update(stableStringify(entry, false))
+    .digest('hex');
+}
+
+/**
+ * Parse the log at `logPath`, one entry per line. A missing log is empty.
+ * Throws on a line that is not JSON, with its line number.
+ */
+export function readAuditLog(logPath: string): readonly AuditEntry[] {
+  if (!existsSync(logPath)) return [];
+  const entries: AuditEntry[] = [];
+  const lines = readFileSync(logPath, 'utf-8').split('\n');
+  lines.forEach((line, index) => {
+    if (line.trim() === '') return;
+    try {
+      entries.push(JSON.parse(line) as AuditEntry);
+    } catch {
+      throw new Error(`Invalid audit log entry at ${logPath}:${index + 1}`);
+    }
+  });
+  return entries;
+}
+
+/**
+ * Append `record` to the log, chained to the last entry by its hash. The
+ * file is only ever opened for appending. Reading the last entry and
+ * appending happen under the log's lockfile, so concurrent runs take
+ * consecutive sequence numbers instead of forking the chain.
+ */
+export function appendAuditEntry(
+  logPath: string,
+  record: AuditRecord,
+  now: Date = new Date(),
+): AuditEntry {
+  mkdirSync(dirname(logPath), { recursive: true });
+  return withFileLock(logPath, () => {
+    const previous = readAuditLog(logPath).at(-1);
+    const unsigned: Omit<AuditEntry, 'hash'> = {
+      seq: (previous?.seq ?? 0) + 1,
+      timestamp: now.toISOString(),
+      ...record,
+      prevHash: previous?.hash ?? GENESIS_HASH,
+    };
+    const entry: AuditEntry = { ...unsigned, hash: hashEntry(unsigned) };
+    appendFileSync(logPath, `${JSON.stringify(entry)}\n`, {
+      encoding: 'utf-8',
+      flag: 'a',
+    });
+    return entry;
+  });
+}
+
+/**
+ * Check that every entry hashes to its `hash`, links to the previous one,
+ * and has the next sequence number. Editing, removing, or reordering an
+ * entry breaks the chain from that entry on.
+ */
+export function verifyAuditLog(
+  entries: readonly AuditEntry[],
+): AuditVerification {
+  let prevHash = GENESIS_HASH;
+  let seq = 1;
+  for (const entry of entries) {
+    const { hash, ...unsigned } = entry;
+    const broken = (reason: string): AuditVerification => ({
+      valid: false,
+      entries: entries.length,
+      brokenAt: entry.seq,
+      reason,
+    });
+    if (entry.seq !== seq) {
+      return broken(`expected sequence ${seq}, found ${entry.seq}`);
+    }
+    if (entry.prevHash !== prevHash) {
+      return broken('previous hash does not match the preceding entry');
+    }
+    if (hashEntry(unsigned) !== hash) {
+      return broken('entry contents do not match its hash');
+    }
+    prevHash = hash;
+    seq++;
+  }
+  return { valid: true, entries: entries.length };
+}
+
+export function filterAuditEntries(
+  entries: readonly AuditEntry[],
+  filter: AuditFilter,
+): readonly AuditEntry[] {
+  const since = filter.since ? Date.parse(filter.since) : undefined;
+  const until = filter.until ? Date.parse(filter.until) : undefined;
+  return entries.filter((entry) => {
+    const time = Date.parse(entry.timestamp);
+    if (since !== undefined && time < since) return false;
+    if (until !== undefined && time >= until) return false;
+    if (filter.actor && entry.actor !== filter.actor) return false;
+    if (filter.command && entry.command !== filter.command) return false;
+    return true;
+  });
+}
+
+const CSV_COLUMNS = [
+  'seq',
+  'timestamp',
+  'actor',
+  'command',
+  'args',
+  'outcome',
+  'changes',
+  'hash',
+] as const;
+
+/**
+ * One row per entry with change summaries joined by `; `. Diffs are left
+ * out; export JSON when reviewers need them.
+ */
+export function formatAuditCsv(entries: readonly AuditEntry[]): string {
+  const rows = entries.map((entry) =>
+    [
+      String(entry.seq),
+      entry.timestamp,
+      entry.actor,
+      entry.command,
+      entry.args.join(' '),
+      entry.outcome,
+      entry.changes
+        .map((change) => `${change.target}: ${change.summary}`)
+        .join('; '),
+      entry.hash,
+    ]
+      .map(csvField)
+      .join(','),
+  );
+  return [CSV_COLUMNS.join(','), ...rows].join('\n') + '\n';
+}
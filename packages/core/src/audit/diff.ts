@@ -0,0 +1,201 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Renders file and graph changes as compact diffs for audit log entries
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [audit, diff, compliance]
+ * context:
+ *   business_goal: Record exactly what a mutating command changed, not just that it ran
+ *   domain: audit
+ */
+import type { GraphChangeEvent } from '../graph/types.js';
+import type { AuditChange } from './types.js';
+
+const CONTEXT_LINES = 3;
+
+interface DiffOp {
+  readonly kind: ' ' | '-' | '+';
+  readonly line: string;
+}
+
+function splitLines(content: string | undefined): readonly string[] {
+  if (content === undefined || content === '') return [];
+  const lines = content.split('\n');
+  if (content.endsWith('\n')) lines.pop();
+  return lines;
+}
+
+function lineOps(
+  before: readonly string[],
+  after: readonly string[],
+): readonly DiffOp[] {
+  // Fixes and config edits are local, so trim the shared prefix and suffix
+  // before the quadratic LCS over what is left.
+  let start = 0;
+  while (
+    start < before.length &&
+    start < after.length &&
+    before[start] === after[start]
+  ) {
+    start++;
+  }
+  let endBefore = before.length;
+  let endAfter = after.length;
+  while (
+    endBefore > start &&
+    endAfter > start &&
+    before[endBefore - 1] === after[endAfter - 1]
+  ) {
+    endBefore--;
+    endAfter--;
+  }
+
+  const a = before.slice(start, endBefore);
+  const b = after.slice(start, endAfter);
+  const lcs = Array.from({ length: a.length + 1 }, () =>
+    new Array<number>(b.length + 1).fill(0),
+  );
+  for (let i = a.length - 1; i >= 0; i--) {
+    for (let j = b.length - 1; j >= 0; j--) {
+      lcs[i][j] =
+        a[i] === b[j]
+          ? lcs[i + 1][j + 1] + 1
+          : Math.max(lcs[i + 1][j], lcs[i][j + 1]);
+    }
+  }
+
+  const ops: DiffOp[] = before
+    .slice(0, start)
+    .map((line): DiffOp => ({ kind: ' ', line }));
+  let i = 0;
+  let j = 0;
+  while (i < a.length || j < b.length) {
+    if (i < a.length && j < b.length && a[i] === b[j]) {
+      ops.push({ kind: ' ', line: a[i++] });
+      j++;
+    } else if (
+      j >= b.length ||
+      (i < a.length && lcs[i + 1][j] >= lcs[i][j + 1])
+    ) {
+      ops.push({ kind: '-', line: a[i++] });
+    } else {
+      ops.push({ kind: '+', line: b[j++] });
+    }
+  }
+  for (const line of before.slice(endBefore)) ops.push({ kind: ' ', line });
+  return ops;
+}
+
+function range(start: number, count: number): string {
+  return `${count === 0 ? start : start + 1},${count}`;
+}
+
+/**
+ * Unified diff of two versions of a file, with three lines of context.
+ * `undefined` stands for a file that did not exist (or no longer does).
+ * Returns an empty string when the contents are equal.
+ */
+export function diffText(
+  path: string,
+  before: string | undefined,
+  after: string | undefined,
+): string {
+  const ops = lineOps(splitLines(before), splitLines(after));
+  const changed = ops.flatMap((op, index) =>
+    op.kind === ' ' ? [] : [index],
+  );
+  if (changed.length === 0) return '';
+
+  // Group changes whose context windows touch into one hunk
+  const hunks: [number, number][] = [];
+  for (const index of changed) {
+    const last = hunks[hunks.length - 1];
+    if (last && index - last[1] <= 2 * CONTEXT_LINES) {
+      last[1] = index;
+    } else {
+      hunks.push([index, index]);
+    }
+  }
+
+  const oldLine: number[] = [];
+  const newLine: number[] = [];
+  let oldCount = 0;
+  let newCount = 0;
+  for (const op of ops) {
+    oldLine.push(oldCount);
+    newLine.push(newCount);
+    if (op.kind !== '+') oldCount++;
+    if (op.kind !== '-') newCount++;
+  }
+
+  const lines = [
+    `--- ${before === undefined ? '/dev/null' : `a/${path}`}`,
+    `+++ ${after === undefined ? '/dev/null' : `b/${path}`}`,
+  ];
+  for (const [first, last] of hunks) {
+    const from = Math.max(0, first - CONTEXT_LINES);
+    const to = Math.min(ops.length - 1, last + CONTEXT_LINES);
+    const hunk = ops.slice(from, to + 1);
+    const removed = hunk.filter((op) => op.kind !== '+').length;
+    const added = hunk.filter((op) => op.kind !== '-').length;
+    lines.push(
+      `@@ -${range(oldLine[from], removed)} +${range(newLine[from], added)} @@`,
+    );
+    for (const op of hunk) lines.push(`${op.kind}${op.line}`);
+  }
+  return lines.join('\n');
+}
+
+/** An audit change for a file, or undefined when the file is unchanged. */
+export function fileChange(
+  path: string,
+  before: string | undefined,
+  after: string | undefined,
+): AuditChange | undefined {
+  const diff = diffText(path, before, after);
+  if (diff === '') return undefined;
+  const summary =
+    before === undefined
+      ? 'created'
+      : after === undefined
+        ? 'deleted'
+        : 'modified';
+  return { target: path, summary, diff };
+}
+
+const EVENT_MARKS = {
+  node_added: '+',
+  node_updated: '~',
+  node_renamed: '>',
+  node_removed: '-',
+} as const;
+
+/** The graph-level audit change for events from `diffGraphs`. */
+export function graphChange(
+  events: readonly GraphChangeEvent[],
+): AuditChange {
+  const count = (type: GraphChangeEvent['type']): number =>
+    events.filter((event) => event.type === type).length;
+  const renamed = count('node_renamed');
+  return {
+    target: 'graph',
+    summary:
+      `${count('node_added')} added, ${count('node_updated')} updated, ` +
+      `${count('node_removed')} removed` +
+      (renamed > 0 ? `, ${renamed} renamed` : ''),
+    ...(events.length > 0
+      ? {
+          diff: events
+            .map(({ type, node, previous }) => {
+              const kind = node.entityType ?? 'external';
+              const name = previous
+                ? `${previous.name} -> ${node.name}`
+                : node.name;
+              return `${EVENT_MARKS[type]} ${name} (${kind})`;
+            })
+            .join('\n'),
+        }
+      : {}),
+  };
+}
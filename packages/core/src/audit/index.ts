@@ -0,0 +1,17 @@
+export type {
+  AuditOutcome,
+  AuditChange,
+  AuditRecord,
+  AuditEntry,
+  AuditVerification,
+  AuditFilter,
+} from './types.js';
+export {
+  GENESIS_HASH,
+  readAuditLog,
+  appendAuditEntry,
+  verifyAuditLog,
+  filterAuditEntries,
+  formatAuditCsv,
+} from './audit-log.js';
+export { diffText, fileChange, graphChange } from './diff.js';
@@ -0,0 +1,60 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the append-only, hash-chained audit log of mutating commands
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [audit, compliance, soc2, types, interface]
+ * context:
+ *   business_goal: Show auditors who changed the graph or annotations, when, and how
+ *   domain: audit
+ */
+
+export type AuditOutcome = 'success' | 'failure';
+
+export interface AuditChange {
+  /** File path, or `graph` for changes to the index itself. */
+  readonly target: string;
+  /** One line, e.g. `3 added, 1 updated, 0 removed`. */
+  readonly summary: string;
+  /** Unified diff for files, or one `+`/`~`/`-` line per graph node. */
+  readonly diff?: string;
+}
+
+/** What a command reports; the log adds the sequence, time, and hashes. */
+export interface AuditRecord {
+  readonly actor: string;
+  readonly command: string;
+  readonly args: readonly string[];
+  readonly cwd: string;
+  readonly outcome: AuditOutcome;
+  readonly changes: readonly AuditChange[];
+}
+
+export interface AuditEntry extends AuditRecord {
+  /** Starts at 1 and increases by one per entry. */
+  readonly seq: number;
+  /** ISO 8601 time the entry was appended. */
+  readonly timestamp: string;
+  /** `hash` of the previous entry; 64 zeros for the first. */
+  readonly prevHash: string;
+  /** SHA-256 of the canonical entry without this field. */
+  readonly hash: string;
+}
+
+export interface AuditVerification {
+  readonly valid: boolean;
+  readonly entries: number;
+  /** `seq` of the first entry that does not chain, when invalid. */
+  readonly brokenAt?: number;
+  readonly reason?: string;
+}
+
+export interface AuditFilter {
+  /** Inclusive ISO 8601 lower bound. */
+  readonly since?: string;
+  /** Exclusive ISO 8601 upper bound. */
+  readonly until?: string;
+  readonly actor?: string;
+  readonly command?: string;
+}
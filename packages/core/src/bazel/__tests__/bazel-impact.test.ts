@@ -0,0 +1,70 @@
+import { describe, it, expect } from 'vitest';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import type { WorkspaceMember } from '../../workspace/types.js';
+import { bazelTargetPatterns, selectBazelTargets } from '../bazel-impact.js';
+import { mergeBazelEdges } from '../bazel-query.js';
+
+const MEMBERS: readonly WorkspaceMember[] = [
+  { name: '//', kind: 'bazel', path: '.' },
+  { name: '//libs/db', kind: 'bazel', path: 'libs/db' },
+  { name: '//services/api', kind: 'bazel', path: 'services/api' },
+  { name: '//services/worker', kind: 'bazel', path: 'services/worker' },
+  { name: 'web', kind: 'pnpm', path: 'web' },
+];
+
+function makeNode(id: string, filePath: string): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath,
+    owner: 'team-a',
+    domain: null,
+    workspace: null,
+  };
+}
+
+describe('selectBazelTargets', () => {
+  const declared: DependencyGraph = {
+    nodes: [
+      makeNode('db', 'libs/db/db.go'),
+      makeNode('worker', 'services/worker/main.go'),
+    ],
+    edges: [{ from: 'worker', to: 'db', kind: 'service' }],
+  };
+  const graph = mergeBazelEdges(declared, [
+    { from: '//services/api', to: '//libs/db' },
+  ]);
+
+  it('selects changed packages and their build and declared dependents', () => {
+    const selection = selectBazelTargets(MEMBERS, graph, [
+      'libs/db/schema.sql',
+    ]);
+    expect(selection.packages.map((p) => [p.label, p.changed])).toEqual([
+      ['//libs/db', true],
+      ['//services/api', false],
+      ['//services/worker', false],
+    ]);
+    expect(bazelTargetPatterns(selection)).toEqual([
+      '//libs/db:all',
+      '//services/api:all',
+      '//services/worker:all',
+    ]);
+  });
+
+  it('maps files outside nested packages to the root package', () => {
+    const selection = selectBazelTargets(MEMBERS, graph, ['tools/gen.sh']);
+    expect(bazelTargetPatterns(selection)).toEqual(['//:all']);
+  });
+
+  it('selects everything when a macro changes', () => {
+    const selection = selectBazelTargets(MEMBERS, graph, ['tools/defs.bzl']);
+    expect(selection).toMatchObject({
+      full: true,
+      reason: 'tools/defs.bzl changed',
+    });
+    expect(selection.packages).toHaveLength(4);
+    expect(bazelTargetPatterns(selection)).toEqual(['//...']);
+  });
+});
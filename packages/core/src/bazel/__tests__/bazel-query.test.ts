@@ -0,0 +1,138 @@
+import { describe, it, expect } from 'vitest';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import type { BazelQueryRunner } from '../types.js';
+import {
+  mergeBazelEdges,
+  packageOf,
+  parseBazelGraphOutput,
+  queryBazelDependencies,
+  reconcileBazelEdges,
+  toPackageEdges,
+} from '../bazel-query.js';
+
+function makeNode(
+  id: string,
+  workspace: string | null,
+  owner: string | null = 'team-a',
+): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `${workspace?.slice(2) ?? 'src'}/${id}.ts`,
+    owner,
+    domain: null,
+    workspace,
+  };
+}
+
+const GRAPH_OUTPUT = [
+  'digraph mygraph {',
+  '  node [shape=box];',
+  '  "//services/api:server"',
+  '  "//services/api:server" -> "//services/api:handlers"',
+  '  "//services/api:handlers" -> "//libs/db:db\\n//libs/db:migrations"',
+  '  "//services/api:handlers" -> "@maven//:guava"',
+  '  "//:tools" -> "//libs/db:db"',
+  '}',
+].join('\n');
+
+describe('parseBazelGraphOutput', () => {
+  it('extracts target edges, expanding factored nodes', () => {
+    expect(parseBazelGraphOutput(GRAPH_OUTPUT)).toEqual([
+      { from: '//services/api:server', to: '//services/api:handlers' },
+      { from: '//services/api:handlers', to: '//libs/db:db' },
+      { from: '//services/api:handlers', to: '//libs/db:migrations' },
+      { from: '//:tools', to: '//libs/db:db' },
+    ]);
+  });
+
+  it('rolls targets up to distinct package edges', () => {
+    expect(packageOf('//:tools')).toBe('//');
+    expect(packageOf('//libs/db')).toBe('//libs/db');
+    expect(toPackageEdges(parseBazelGraphOutput(GRAPH_OUTPUT))).toEqual([
+      { from: '//services/api', to: '//libs/db' },
+      { from: '//', to: '//libs/db' },
+    ]);
+  });
+});
+
+describe('queryBazelDependencies', () => {
+  it('queries deps of the scope and returns package edges', async () => {
+    const expressions: string[] = [];
+    const runner: BazelQueryRunner = {
+      query(expression) {
+        expressions.push(expression);
+        return Promise.resolve(GRAPH_OUTPUT);
+      },
+    };
+    const edges = await queryBazelDependencies(runner, '//services/...');
+    expect(expressions).toEqual(['deps(//services/...)']);
+    expect(edges).toHaveLength(2);
+  });
+});
+
+describe('reconcileBazelEdges', () => {
+  const graph: DependencyGraph = {
+    nodes: [
+      makeNode('api', '//services/api'),
+      makeNode('db', '//libs/db'),
+      makeNode('billing', '//services/billing'),
+      makeNode('legacy', null),
+    ],
+    edges: [
+      { from: 'api', to: 'db', kind: 'service' },
+      { from: 'api', to: 'billing', kind: 'service' },
+      { from: 'legacy', to: 'db', kind: 'service' },
+    ],
+  };
+
+  it('splits edges into confirmed, undeclared, and declared-only', () => {
+    const result = reconcileBazelEdges(graph, [
+      { from: '//services/api', to: '//libs/db' },
+      { from: '//services/billing', to: '//libs/db' },
+      { from: '//services/api', to: '//third_party/json' },
+    ]);
+    expect(result).toEqual({
+      confirmed: [{ from: '//services/api', to: '//libs/db' }],
+      undeclared: [{ from: '//services/billing', to: '//libs/db' }],
+      declaredOnly: [{ from: '//services/api', to: '//services/billing' }],
+    });
+  });
+
+  it('does not flag declared edges for packages outside the build graph', () => {
+    const result = reconcileBazelEdges(graph, [
+      { from: '//services/api', to: '//libs/db' },
+    ]);
+    expect(result.declaredOnly).toEqual([]);
+  });
+});
+
+describe('mergeBazelEdges', () => {
+  it('adds package nodes and build edges', () => {
+    const graph: DependencyGraph = {
+      nodes: [makeNode('api', '//services/api')],
+      edges: [],
+    };
+    const merged = mergeBazelEdges(graph, [
+      { from: '//services/api', to: '//' },
+    ]);
+    expect(merged.edges).toEqual([
+      {
+        from: 'bazel://services/api',
+        to: 'bazel://',
+        kind: 'build',
+        provenance: 'build',
+        confidence: 1,
+      },
+    ]);
+    expect(
+      merged.nodes.map((node) => [node.id, node.filePath, node.owner]),
+    ).toEqual([
+      ['api', 'services/api/api.ts', 'team-a'],
+      ['bazel://services/api', 'services/api', 'team-a'],
+      ['bazel://', '.', null],
+    ]);
+  });
+});
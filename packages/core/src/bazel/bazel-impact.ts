@@ -0,0 +1,95 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Selects the Bazel packages a change set can break and the target patterns to test them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bazel, build, impact, ci, selection]
+ * context:
+ *   business_goal: Let Bazel users test only what a change can break, falling back to everything when unsure
+ *   domain: bazel
+ */
+import { selectImpactedUnits } from '../graph/graph-impact.js';
+import type { DependencyGraph } from '../graph/types.js';
+import { findWorkspaceMember } from '../workspace/discovery.js';
+import type { WorkspaceMember } from '../workspace/types.js';
+import type {
+  BazelTargetSelection,
+  BazelTargetSelectionOptions,
+  SelectedBazelPackage,
+} from './types.js';
+
+/**
+ * Files whose change can affect any target: workspace setup, flags, and
+ * Starlark macros, which any BUILD file may load.
+ */
+const WORKSPACE_FILES = [
+  'MODULE.bazel',
+  'MODULE.bazel.lock',
+  'WORKSPACE',
+  'WORKSPACE.bazel',
+  '.bazelrc',
+  '.bazelversion',
+  '*.bzl',
+];
+
+/**
+ * Pick the Bazel packages whose targets `changedFiles` can break, walking
+ * dependents with `selectImpactedUnits`. A file or entity belongs to the
+ * innermost package containing it. Package nodes and `build` edges from
+ * `mergeBazelEdges` make the walk follow real build dependencies; without
+ * them only declared dependencies between entities are followed. Every
+ * package is selected when workspace files or a `.bzl` file changed.
+ * Members other than Bazel packages are ignored.
+ */
+export function selectBazelTargets(
+  members: readonly WorkspaceMember[],
+  graph: DependencyGraph,
+  changedFiles: readonly string[],
+  options: BazelTargetSelectionOptions = {},
+): BazelTargetSelection {
+  const packages = members.filter((member) => member.kind === 'bazel');
+  const units = packages.map((member) => ({
+    id: `bazel:${member.name}`,
+    member,
+  }));
+  const byName = new Map(units.map((unit) => [unit.member.name, unit]));
+  const unitOf = (file: string) => {
+    const member = findWorkspaceMember(file, packages);
+    return member && byName.get(member.name);
+  };
+  const selection = selectImpactedUnits(
+    graph,
+    units,
+    { unitOfFile: unitOf, unitOfNode: (node) => unitOf(node.filePath!) },
+    changedFiles,
+    { minCoverage: options.minCoverage, globalFiles: WORKSPACE_FILES },
+  );
+  const changed = new Set(selection.changed);
+  return {
+    full: selection.full,
+    reason: selection.reason,
+    packages: selection.units
+      .map(
+        (unit): SelectedBazelPackage => ({
+          label: unit.member.name,
+          path: unit.member.path,
+          changed: changed.has(unit),
+        }),
+      )
+      .sort((a, b) => a.label.localeCompare(b.label)),
+    unmapped: selection.unmapped,
+    coverage: selection.coverage,
+  };
+}
+
+/**
+ * Target patterns for `bazel test`: every target in each selected package
+ * (`//pkg:all`), or `//...` when the selection is full.
+ */
+export function bazelTargetPatterns(
+  selection: BazelTargetSelection,
+): readonly string[] {
+  if (selection.full) return ['//...'];
+  return selection.packages.map((pkg) => `${pkg.label}:all`);
+}
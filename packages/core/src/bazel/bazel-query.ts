@@ -0,0 +1,253 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Runs bazel query, rolls target edges up to packages, and merges them into the dependency graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bazel, build, dependencies, enricher, graph]
+ * context:
+ *   business_goal: Ground the graph in real build dependencies and flag drift from what annotations declare
+ *   domain: bazel
+ */
+import { execFile } from 'node:child_process';
+import type {
+  DependencyGraph,
+  GraphEdge,
+  GraphNode,
+} from '../graph/types.js';
+import type {
+  BazelEdge,
+  BazelQueryRunner,
+  BazelQueryRunnerOptions,
+  BazelReconciliation,
+} from './types.js';
+
+const DEFAULT_TIMEOUT_MS = 120_000;
+const MAX_BUFFER_BYTES = 256 * 1024 * 1024;
+
+const EDGE_PATTERN = /^\s*"([^"]+)"\s*->\s*"([^"]+)"/;
+
+/**
+ * Create a runner that shells out to `bazel query --output=graph`. Output
+ * is unfactored so every node holds exactly one label.
+ */
+export function createBazelQueryRunner(
+  options: BazelQueryRunnerOptions,
+): BazelQueryRunner {
+  const bazel = options.bazelPath ?? 'bazel';
+  const timeout = options.timeoutMs ?? DEFAULT_TIMEOUT_MS;
+
+  return {
+    query(expression: string): Promise<string> {
+      const args = [
+        'query',
+        expression,
+        '--output=graph',
+        '--nograph:factored',
+      ];
+      return new Promise((resolve, reject) => {
+        execFile(
+          bazel,
+          args,
+          { cwd: options.cwd, timeout, maxBuffer: MAX_BUFFER_BYTES },
+          (error, stdout, stderr) => {
+            if (error) {
+              const detail = stderr.trim().split('\n').pop() ?? '';
+              reject(
+                new Error(
+                  `bazel query failed: ${detail || error.message}`.trim(),
+                ),
+              );
+              return;
+            }
+            resolve(stdout);
+          },
+        );
+      });
+    },
+  };
+}
+
+/**
+ * Parse `--output=graph` (Graphviz) output into target edges. Factored nodes
+ * (labels joined by `\n`) are expanded so each label gets its own edges.
+ * Labels from external repositories (`@repo//...`) are dropped.
+ */
+export function parseBazelGraphOutput(output: string): readonly BazelEdge[] {
+  const seen = new Set<string>();
+  const edges: BazelEdge[] = [];
+  for (const line of output.split('\n')) {
+    const match = EDGE_PATTERN.exec(line);
+    if (!match) continue;
+    const froms = match[1].split('\\n');
+    const tos = match[2].split('\\n');
+    for (const from of froms) {
+      for (const to of tos) {
+        if (!from.startsWith('//') || !to.startsWith('//')) continue;
+        const key = `${from}\u0000${to}`;
+        if (seen.has(key)) continue;
+        seen.add(key);
+        edges.push({ from, to });
+      }
+    }
+  }
+  return edges;
+}
+
+/**
+ * The package label of a target: `//pkg/sub:name` -> `//pkg/sub`,
+ * `//:name` -> `//`.
+ */
+export function packageOf(label: string): string {
+  const colon = label.indexOf(':');
+  return colon === -1 ? label : label.slice(0, colon);
+}
+
+/**
+ * Collapse target edges to distinct package-to-package edges, dropping
+ * dependencies within a package.
+ */
+export function toPackageEdges(
+  edges: readonly BazelEdge[],
+): readonly BazelEdge[] {
+  const seen = new Set<string>();
+  const result: BazelEdge[] = [];
+  for (const edge of edges) {
+    const from = packageOf(edge.from);
+    const to = packageOf(edge.to);
+    const key = `${from}\u0000${to}`;
+    if (from === to || seen.has(key)) continue;
+    seen.add(key);
+    result.push({ from, to });
+  }
+  return result;
+}
+
+/**
+ * Query the build graph for `scope` and return package-level edges.
+ */
+export async function queryBazelDependencies(
+  runner: BazelQueryRunner,
+  scope: string = '//...',
+): Promise<readonly BazelEdge[]> {
+  const output = await runner.query(`deps(${scope})`);
+  return toPackageEdges(parseBazelGraphOutput(output));
+}
+
+function edgeKey(edge: BazelEdge): string {
+  return `${edge.from}\u0000${edge.to}`;
+}
+
+function sortEdges(edges: readonly BazelEdge[]): readonly BazelEdge[] {
+  return [...edges].sort(
+    (a, b) => a.from.localeCompare(b.from) || a.to.localeCompare(b.to),
+  );
+}
+
+/**
+ * Package-level edges implied by the graph's annotation-declared service
+ * dependencies, for nodes assigned to workspace members.
+ */
+function declaredPackageEdges(graph: DependencyGraph): readonly BazelEdge[] {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const seen = new Set<string>();
+  const edges: BazelEdge[] = [];
+  for (const edge of graph.edges) {
+    if (edge.kind !== 'service') continue;
+    const from = nodes.get(edge.from)?.workspace;
+    const to = nodes.get(edge.to)?.workspace;
+    if (!from || !to || from === to) continue;
+    const declared = { from, to };
+    if (seen.has(edgeKey(declared))) continue;
+    seen.add(edgeKey(declared));
+    edges.push(declared);
+  }
+  return edges;
+}
+
+/**
+ * Compare build edges with declared dependencies. Only packages holding
+ * annotated entities take part: a build edge into an unannotated package
+ * could never have been declared.
+ */
+export function reconcileBazelEdges(
+  graph: DependencyGraph,
+  buildEdges: readonly BazelEdge[],
+): BazelReconciliation {
+  const annotated = new Set(
+    graph.nodes.flatMap((node) =>
+      !node.external && node.workspace ? [node.workspace] : [],
+    ),
+  );
+  const build = buildEdges.filter(
+    (edge) => annotated.has(edge.from) && annotated.has(edge.to),
+  );
+  const buildKeys = new Set(build.map(edgeKey));
+  const buildPackages = new Set(buildEdges.flatMap((e) => [e.from, e.to]));
+
+  const declared = declaredPackageEdges(graph);
+  const declaredKeys = new Set(declared.map(edgeKey));
+
+  return {
+    confirmed: sortEdges(declared.filter((e) => buildKeys.has(edgeKey(e)))),
+    undeclared: sortEdges(build.filter((e) => !declaredKeys.has(edgeKey(e)))),
+    declaredOnly: sortEdges(
+      declared.filter(
+        (e) =>
+          !buildKeys.has(edgeKey(e)) &&
+          buildPackages.has(e.from) &&
+          buildPackages.has(e.to),
+      ),
+    ),
+  };
+}
+
+/**
+ * Add one node per Bazel package (`bazel:<label>`) and a `build` edge per
+ * package dependency. Package nodes take the sole owner of their annotated
+ * entities, or none when owners differ.
+ */
+export function mergeBazelEdges(
+  graph: DependencyGraph,
+  buildEdges: readonly BazelEdge[],
+): DependencyGraph {
+  const owners = new Map<string, Set<string>>();
+  for (const node of graph.nodes) {
+    if (node.external || !node.workspace || !node.owner) continue;
+    const set = owners.get(node.workspace) ?? new Set<string>();
+    set.add(node.owner);
+    owners.set(node.workspace, set);
+  }
+
+  const packageNode = (label: string): GraphNode => {
+    const packageOwners = [...(owners.get(label) ?? [])];
+    return {
+      id: `bazel:${label}`,
+      name: label,
+      entityType: null,
+      external: false,
+      filePath: label === '//' ? '.' : label.slice(2),
+      owner: packageOwners.length === 1 ? packageOwners[0] : null,
+      domain: null,
+      workspace: label,
+    };
+  };
+
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const added: GraphEdge[] = [];
+  for (const edge of buildEdges) {
+    for (const label of [edge.from, edge.to]) {
+      const node = packageNode(label);
+      if (!nodes.has(node.id)) nodes.set(node.id, node);
+    }
+    added.push({
+      from: `bazel:${edge.from}`,
+      to: `bazel:${edge.to}`,
+      kind: 'build',
+      provenance: 'build',
+      confidence: 1,
+    });
+  }
+
+  return { nodes: [...nodes.values()], edges: [...graph.edges, ...added] };
+}
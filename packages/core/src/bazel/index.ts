@@ -0,0 +1,19 @@
+export type {
+  BazelEdge,
+  BazelQueryRunner,
+  BazelQueryRunnerOptions,
+  BazelReconciliation,
+  BazelTargetSelectionOptions,
+  SelectedBazelPackage,
+  BazelTargetSelection,
+} from './types.js';
+export {
+  createBazelQueryRunner,
+  parseBazelGraphOutput,
+  packageOf,
+  toPackageEdges,
+  queryBazelDependencies,
+  reconcileBazelEdges,
+  mergeBazelEdges,
+} from './bazel-query.js';
+export { bazelTargetPatterns, selectBazelTargets } from './bazel-impact.js';
@@ -0,0 +1,68 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for build-time dependency edges derived from bazel query
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bazel, build, dependencies, types, interface]
+ * context:
+ *   business_goal: Compare what teams declare against what the build actually depends on
+ *   domain: bazel
+ */
+
+export interface BazelEdge {
+  /** Target label (`//pkg:name`) or, after roll-up, package label (`//pkg`). */
+  readonly from: string;
+  readonly to: string;
+}
+
+export interface BazelQueryRunner {
+  /** Run `bazel query` for an expression and return its graph output. */
+  query(expression: string): Promise<string>;
+}
+
+export interface BazelQueryRunnerOptions {
+  readonly cwd: string;
+  readonly bazelPath?: string;
+  readonly timeoutMs?: number;
+}
+
+export interface BazelReconciliation {
+  /** Package dependencies declared in annotations and present in the build. */
+  readonly confirmed: readonly BazelEdge[];
+  /** Build dependencies between annotated packages that nothing declares. */
+  readonly undeclared: readonly BazelEdge[];
+  /** Declared dependencies between Bazel packages with no build edge. */
+  readonly declaredOnly: readonly BazelEdge[];
+}
+
+export interface BazelTargetSelectionOptions {
+  /**
+   * Share of changed files (other than Markdown) that must map to a Bazel
+   * package or an annotated entity; below it every target is selected.
+   * Defaults to 0.8.
+   */
+  readonly minCoverage?: number;
+}
+
+export interface SelectedBazelPackage {
+  /** Package label, `//pkg` or `//` for the root. */
+  readonly label: string;
+  /** POSIX directory relative to the workspace root; `.` for the root. */
+  readonly path: string;
+  /** A changed file is in the package, rather than it depending on one. */
+  readonly changed: boolean;
+}
+
+export interface BazelTargetSelection {
+  /** Every target is selected: run `bazel test //...`. */
+  readonly full: boolean;
+  /** Why every target is selected; null when the selection is narrowed. */
+  readonly reason: string | null;
+  /** Sorted by label; every package when `full`. */
+  readonly packages: readonly SelectedBazelPackage[];
+  /** Changed files that map to no package or entity. */
+  readonly unmapped: readonly string[];
+  /** Share of considered changed files that mapped, from 0 to 1. */
+  readonly coverage: number;
+}
@@ -0,0 +1,245 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readdirSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { readGraphSnapshot } from '../../snapshot/graph-snapshot.js';
+import { compareBenchReports, runBenchmarks } from '../bench.js';
+import {
+  formatBenchSize,
+  parseBenchSize,
+  writeSyntheticRepo,
+} from '../synthetic-repo.js';
+import { writeTestData } from '../testdata.js';
+import type { BenchReport, BenchResult } from '../types.js';
+
+function report(results: Partial<BenchResult>[]): BenchReport {
+  return {
+    schema: 1,
+    version: '0.0.0',
+    createdAt: '2026-01-01T00:00:00.000Z',
+    node: '20.0.0',
+    os: 'linux',
+    arch: 'x64',
+    cpus: 4,
+    results: results.map(
+      (result): BenchResult => ({
+        name: 'scan/1k',
+        case: 'scan',
+        files: 1000,
+        entities: 3000,
+        edges: 0,
+        runs: 3,
+        medianMs: 100,
+        minMs: 90,
+        maxMs: 110,
+        ...result,
+      }),
+    ),
+  };
+}
+
+describe('bench sizes', () => {
+  it('parses file counts with k and m suffixes', () => {
+    expect(parseBenchSize('250')).toBe(250);
+    expect(parseBenchSize('10k')).toBe(10_000);
+    expect(parseBenchSize('1M')).toBe(1_000_000);
+  });
+
+  it('rejects anything else as a usage error', () => {
+    for (const text of ['', '0', '-5', '1.5k', 'ten']) {
+      expect(() => parseBenchSize(text)).toThrow(
+        expect.objectContaining({ kind: 'usage' }),
+      );
+    }
+  });
+
+  it('formats sizes the way results are named', () => {
+    expect(formatBenchSize(100_000)).toBe('100k');
+    expect(formatBenchSize(2_000_000)).toBe('2m');
+    expect(formatBenchSize(1500)).toBe('1500');
+  });
+});
+
+describe('writeSyntheticRepo', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-bench-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function contents(root: string): string[] {
+    const src = join(root, 'src', 'd0');
+    return readdirSync(src)
+      .sort()
+      .map((name) => `${name}\n${readFileSync(join(src, name), 'utf-8')}`);
+  }
+
+  it('writes a hundred files per directory', () => {
+    const repo = writeSyntheticRepo(join(dir, 'repo'), { files: 250 });
+    expect(repo.entities).toBe(750);
+    expect(readdirSync(join(dir, 'repo', 'src')).sort()).toEqual([
+      'd0',
+      'd1',
+      'd2',
+    ]);
+    expect(readdirSync(join(dir, 'repo', 'src', 'd2'))).toHaveLength(50);
+  });
+
+  it('writes the same repository for the same seed', () => {
+    const first = writeSyntheticRepo(join(dir, 'a'), { files: 40, seed: 7 });
+    const second = writeSyntheticRepo(join(dir, 'b'), { files: 40, seed: 7 });
+    expect(second.dependencies).toBe(first.dependencies);
+    expect(contents(join(dir, 'b'))).toEqual(contents(join(dir, 'a')));
+  });
+
+  it('only depends on earlier services', () => {
+    writeSyntheticRepo(dir, { files: 30, pythonShare: 0 });
+    const first = join(dir, 'src', 'd0', 'service-0.ts');
+    expect(readFileSync(first, 'utf-8')).not.toContain('dependencies:');
+  });
+
+  it('writes exactly the entities and dependencies asked for', () => {
+    const repo = writeSyntheticRepo(dir, {
+      files: 4,
+      entities: 10,
+      dependencies: 6,
+      pythonShare: 0,
+    });
+    expect(repo).toMatchObject({ entities: 10, dependencies: 6 });
+    const last = readFileSync(join(dir, 'src', 'd0', 'service-3.ts'), 'utf-8');
+    expect(last).toContain('export class Service3 {\n}');
+    expect(last).not.toContain('configure3');
+  });
+
+  it('rejects more dependencies than the services can hold', () => {
+    expect(() =>
+      writeSyntheticRepo(dir, { files: 4, dependencies: 7 }),
+    ).toThrow('at most 6 fit');
+    expect(() => writeSyntheticRepo(dir, { files: 4, entities: 9 })).toThrow(
+      'each file holds one to three',
+    );
+  });
+});
+
+describe('writeTestData', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-testdata-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('writes a repository and a snapshot of the size asked for', () => {
+    const data = writeTestData(dir, { nodes: 50, edges: 80, seed: 3 });
+    expect(data).toMatchObject({ files: 17, nodes: 50, edges: 80 });
+    const graph = readGraphSnapshot(data.snapshotPath);
+    expect(graph.nodes).toHaveLength(50);
+    expect(graph.edges).toHaveLength(80);
+  });
+
+  it('refuses to write over an earlier repository', () => {
+    writeTestData(dir, { nodes: 3, edges: 0 });
+    expect(() => writeTestData(dir, { nodes: 3, edges: 0 })).toThrow(
+      'already exists',
+    );
+  });
+});
+
+describe('runBenchmarks', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-bench-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('times a scan and a graph build per size', () => {
+    const sizes: number[] = [];
+    const result = runBenchmarks({
+      sizes: [20],
+      runs: 2,
+      workDir: dir,
+      version: '1.2.3',
+      onSize: (files) => sizes.push(files),
+    });
+
+    expect(sizes).toEqual([20]);
+    expect(result).toMatchObject({ schema: 1, version: '1.2.3' });
+    expect(result.results.map((r) => r.name)).toEqual(['scan/20', 'graph/20']);
+    const [scanResult] = result.results;
+    expect(scanResult.entities).toBe(60);
+    expect(scanResult.runs).toBe(2);
+    expect(scanResult.minMs).toBeLessThanOrEqual(scanResult.medianMs);
+    expect(scanResult.medianMs).toBeLessThanOrEqual(scanResult.maxMs);
+  });
+
+  it('reuses repositories written by an earlier run', () => {
+    runBenchmarks({ sizes: [10], runs: 1, workDir: dir });
+    const written = readdirSync(dir);
+    runBenchmarks({ sizes: [10], runs: 1, workDir: dir });
+    expect(readdirSync(dir)).toEqual(written);
+  });
+});
+
+describe('compareBenchReports', () => {
+  it('flags slowdowns beyond the allowed percentage', () => {
+    const comparisons = compareBenchReports(
+      report([{ name: 'scan/1k' }, { name: 'graph/1k', medianMs: 40 }]),
+      report([
+        { name: 'scan/1k', medianMs: 130 },
+        { name: 'graph/1k', medianMs: 42 },
+      ]),
+      { maxRegressionPercent: 20 },
+    );
+    expect(comparisons).toEqual([
+      {
+        name: 'scan/1k',
+        baselineMs: 100,
+        currentMs: 130,
+        changePercent: 30,
+        regressed: true,
+      },
+      {
+        name: 'graph/1k',
+        baselineMs: 40,
+        currentMs: 42,
+        changePercent: 5,
+        regressed: false,
+      },
+    ]);
+  });
+
+  it('ignores slowdowns smaller than the noise floor', () => {
+    const [comparison] = compareBenchReports(
+      report([{ medianMs: 2 }]),
+      report([{ medianMs: 4 }]),
+      { maxRegressionPercent: 10 },
+    );
+    expect(comparison.changePercent).toBe(100);
+    expect(comparison.regressed).toBe(false);
+  });
+
+  it('reports without flagging when no limit is set', () => {
+    const [comparison] = compareBenchReports(
+      report([{}]),
+      report([{ medianMs: 500 }]),
+    );
+    expect(comparison.regressed).toBe(false);
+  });
+
+  it('skips cases missing from the baseline', () => {
+    expect(
+      compareBenchReports(report([]), report([{ name: 'scan/10k' }])),
+    ).toEqual([]);
+  });
+});
@@ -0,0 +1,152 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Times scans and graph builds over synthetic repositories and compares the results against a baseline report
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bench, performance, regression, scan, graph]
+ * context:
+ *   business_goal: Make performance comparisons between releases repeatable on any machine
+ *   domain: profiling
+ */
+import { existsSync, rmSync, writeFileSync } from 'node:fs';
+import { arch, cpus, platform } from 'node:os';
+import { join } from 'node:path';
+import { performance } from 'node:perf_hooks';
+import { scan } from '../library/knowgraph.js';
+import { formatBenchSize, writeSyntheticRepo } from './synthetic-repo.js';
+import type {
+  BenchCase,
+  BenchCompareOptions,
+  BenchComparison,
+  BenchOptions,
+  BenchReport,
+  BenchResult,
+} from './types.js';
+
+const DEFAULT_MIN_DELTA_MS = 5;
+
+function median(values: readonly number[]): number {
+  const sorted = [...values].sort((a, b) => a - b);
+  const middle = Math.floor(sorted.length / 2);
+  return sorted.length % 2 === 1
+    ? sorted[middle]
+    : (sorted[middle - 1] + sorted[middle]) / 2;
+}
+
+function round(ms: number): number {
+  return Math.round(ms * 100) / 100;
+}
+
+function toResult(
+  benchCase: BenchCase,
+  files: number,
+  counts: { readonly entities: number; readonly edges: number },
+  timings: readonly number[],
+): BenchResult {
+  return {
+    name: `${benchCase}/${formatBenchSize(files)}`,
+    case: benchCase,
+    files,
+    ...counts,
+    runs: timings.length,
+    medianMs: round(median(timings)),
+    minMs: round(Math.min(...timings)),
+    maxMs: round(Math.max(...timings)),
+  };
+}
+
+/**
+ * Write a synthetic repository per size under `options.workDir`, then time
+ * an in-memory scan of it and a graph build over the scan, `runs` times
+ * each. Repositories already written by an earlier run with the same seed
+ * are reused, so only the first run pays for the writes.
+ */
+export function runBenchmarks(options: BenchOptions): BenchReport {
+  const { sizes, workDir, seed = 1, version = 'unknown', onSize } = options;
+  const runs = Math.max(1, options.runs ?? 3);
+  const results: BenchResult[] = [];
+
+  for (const files of sizes) {
+    const rootDir = join(workDir, `${formatBenchSize(files)}-seed${seed}`);
+    if (!existsSync(join(rootDir, '.complete'))) {
+      rmSync(rootDir, { recursive: true, force: true });
+      writeSyntheticRepo(rootDir, { files, seed });
+      // Marks the repository whole, so an interrupted write is redone
+      writeFileSync(join(rootDir, '.complete'), '', 'utf-8');
+    }
+
+    const scanTimings: number[] = [];
+    const graphTimings: number[] = [];
+    let counts = { entities: 0, edges: 0 };
+    onSize?.(files);
+    for (let run = 0; run < runs; run++) {
+      const scanStart = performance.now();
+      const result = scan(rootDir);
+      scanTimings.push(performance.now() - scanStart);
+      try {
+        const graphStart = performance.now();
+        const graph = result.graph();
+        graphTimings.push(performance.now() - graphStart);
+        counts = {
+          entities: result.index.totalEntities,
+          edges: graph.edges.length,
+        };
+      } finally {
+        result.close();
+      }
+    }
+    results.push(
+      toResult('scan', files, counts, scanTimings),
+      toResult('graph', files, counts, graphTimings),
+    );
+  }
+
+  return {
+    schema: 1,
+    version,
+    createdAt: new Date().toISOString(),
+    node: process.versions.node,
+    os: platform(),
+    arch: arch(),
+    cpus: cpus().length,
+    results,
+  };
+}
+
+/**
+ * Compare each result of `current` with the result of the same name in
+ * `baseline`. A case regressed when its median slowed by more than
+ * `maxRegressionPercent` and by at least `minDeltaMs`, so sub-millisecond
+ * cases cannot fail the gate on noise. Cases missing from either report
+ * are left out.
+ */
+export function compareBenchReports(
+  baseline: BenchReport,
+  current: BenchReport,
+  options: BenchCompareOptions = {},
+): readonly BenchComparison[] {
+  const { maxRegressionPercent, minDeltaMs = DEFAULT_MIN_DELTA_MS } = options;
+  const baselineByName = new Map(
+    baseline.results.map((result) => [result.name, result]),
+  );
+  return current.results.flatMap((result) => {
+    const before = baselineByName.get(result.name);
+    if (!before) return [];
+    const delta = result.medianMs - before.medianMs;
+    const changePercent =
+      before.medianMs > 0 ? round((delta / before.medianMs) * 100) : 0;
+    return [
+      {
+        name: result.name,
+        baselineMs: before.medianMs,
+        currentMs: result.medianMs,
+        changePercent,
+        regressed:
+          maxRegressionPercent !== undefined &&
+          changePercent > maxRegressionPercent &&
+          delta >= minDeltaMs,
+      },
+    ];
+  });
+}
@@ -0,0 +1,20 @@
+export type {
+  BenchCase,
+  BenchCompareOptions,
+  BenchComparison,
+  BenchOptions,
+  BenchReport,
+  BenchResult,
+  SyntheticRepo,
+  SyntheticRepoOptions,
+  TestData,
+  TestDataOptions,
+} from './types.js';
+export { compareBenchReports, runBenchmarks } from './bench.js';
+export {
+  createRandom,
+  formatBenchSize,
+  parseBenchSize,
+  writeSyntheticRepo,
+} from './synthetic-repo.js';
+export { writeTestData } from './testdata.js';
@@ -0,0 +1,263 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Writes deterministic synthetic repositories of annotated TypeScript and Python services for benchmarks
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bench, performance, synthetic, fixtures]
+ * context:
+ *   business_goal: Benchmark against the same repository every run so timings can be compared
+ *   domain: profiling
+ */
+import { mkdirSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { SyntheticRepo, SyntheticRepoOptions } from './types.js';
+
+const FILES_PER_DIR = 100;
+const OWNERS = ['payments', 'identity', 'search', 'platform', 'growth'];
+const DOMAINS = ['billing', 'auth', 'catalog', 'infra', 'marketing'];
+const STATUSES = ['stable', 'experimental', 'deprecated'];
+
+/** A small, seeded PRNG (mulberry32) returning numbers in [0, 1). */
+export function createRandom(seed: number): () => number {
+  let state = seed >>> 0;
+  return () => {
+    state = (state + 0x6d2b79f5) >>> 0;
+    let t = state;
+    t = Math.imul(t ^ (t >>> 15), t | 1);
+    t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+    return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+  };
+}
+
+/**
+ * A size such as `1000`, `10k`, or `1m` in files. Throws a `usage` error
+ * for anything else.
+ */
+export function parseBenchSize(text: string): number {
+  const match = /^(\d+)([km]?)$/i.exec(text.trim());
+  const scale = { '': 1, k: 1000, m: 1_000_000 };
+  const size = match
+    ? Number(match[1]) * scale[match[2].toLowerCase() as keyof typeof scale]
+    : NaN;
+  if (!Number.isSafeInteger(size) || size <= 0) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid size "${text}": use a file count such as 1000 or 10k`,
+    );
+  }
+  return size;
+}
+
+/** `10000` as `10k`; the name benchmark results use. */
+export function formatBenchSize(files: number): string {
+  if (files % 1_000_000 === 0) return `${files / 1_000_000}m`;
+  if (files % 1000 === 0) return `${files / 1000}k`;
+  return String(files);
+}
+
+function pick<T>(items: readonly T[], random: () => number): T {
+  return items[Math.floor(random() * items.length)];
+}
+
+/** Services earlier than `index`, so the graph has no cycles to skew it. */
+function pickDependencies(
+  index: number,
+  max: number,
+  random: () => number,
+): readonly string[] {
+  if (index === 0) return [];
+  const count = Math.floor(random() * (max + 1));
+  const picked = new Set<string>();
+  for (let i = 0; i < count; i++) {
+    picked.add(`Service${Math.floor(random() * index)}`);
+  }
+  return [...picked];
+}
+
+/**
+ * Exactly `total` distinct dependencies between `files` services, each on
+ * an earlier one. Squaring the draw skews targets toward early services,
+ * so a few are depended on widely and most by one or two.
+ */
+function planDependencies(
+  files: number,
+  total: number,
+  random: () => number,
+): readonly (readonly string[])[] {
+  const max = (files * (files - 1)) / 2;
+  if (!Number.isSafeInteger(total) || total < 0 || total > max) {
+    throw createKnowgraphError(
+      'usage',
+      `Cannot write ${total} dependencies between ${files} services: at most ${max} fit`,
+    );
+  }
+  const planned = Array.from({ length: files }, () => new Set<number>());
+  let placed = 0;
+  while (placed < total) {
+    const from = 1 + Math.floor(random() * (files - 1));
+    const to = Math.floor(random() ** 2 * from);
+    if (!planned[from].has(to)) {
+      planned[from].add(to);
+      placed++;
+    }
+  }
+  return planned.map((targets) =>
+    [...targets].sort((a, b) => a - b).map((index) => `Service${index}`),
+  );
+}
+
+interface FileSpec {
+  readonly index: number;
+  /** Entities in the file: the service, then its method, then a function. */
+  readonly members: number;
+  readonly owner: string;
+  readonly domain: string;
+  readonly status: string;
+  readonly dependencies: readonly string[];
+}
+
+function yamlBlock(spec: FileSpec): readonly string[] {
+  const lines = [
+    'type: service',
+    `description: Synthetic service ${spec.index} in the ${spec.domain} domain`,
+    `owner: ${spec.owner}`,
+    `status: ${spec.status}`,
+    `tags: [synthetic, ${spec.domain}]`,
+    'context:',
+    `  domain: ${spec.domain}`,
+  ];
+  if (spec.dependencies.length > 0) {
+    lines.push(
+      'dependencies:',
+      `  services: [${spec.dependencies.join(', ')}]`,
+    );
+  }
+  return lines;
+}
+
+function typescriptFile(spec: FileSpec): string {
+  const block = ['@knowgraph', ...yamlBlock(spec)];
+  const method = [
+    '  /**',
+    '   * @knowgraph',
+    '   * type: method',
+    `   * description: Handles one request for service ${spec.index}`,
+    `   * owner: ${spec.owner}`,
+    '   */',
+    '  handle(input: string): string {',
+    '    return input.trim();',
+    '  }',
+  ];
+  const fn = [
+    '',
+    '/**',
+    ' * @knowgraph',
+    ' * type: function',
+    ` * description: Builds the default configuration of service ${spec.index}`,
+    ` * owner: ${spec.owner}`,
+    ' */',
+    `export function configure${spec.index}(): Record<string, string> {`,
+    `  return { name: 'service-${spec.index}' };`,
+    '}',
+  ];
+  return [
+    '/**',
+    ...block.map((line) => ` * ${line}`),
+    ' */',
+    `export class Service${spec.index} {`,
+    ...(spec.members >= 2 ? method : []),
+    '}',
+    ...(spec.members >= 3 ? fn : []),
+    '',
+  ].join('\n');
+}
+
+function pythonFile(spec: FileSpec): string {
+  const method = [
+    '',
+    '    def handle(self, value):',
+    '        """',
+    '        @knowgraph',
+    '        type: method',
+    `        description: Handles one request for service ${spec.index}`,
+    `        owner: ${spec.owner}`,
+    '        """',
+    '        return value.strip()',
+  ];
+  const fn = [
+    '',
+    '',
+    `def configure_${spec.index}():`,
+    '    """',
+    '    @knowgraph',
+    '    type: function',
+    `    description: Builds the default configuration of service ${spec.index}`,
+    `    owner: ${spec.owner}`,
+    '    """',
+    `    return {"name": "service-${spec.index}"}`,
+  ];
+  return [
+    `class Service${spec.index}:`,
+    '    """',
+    '    @knowgraph',
+    ...yamlBlock(spec).map((line) => `    ${line}`),
+    '    """',
+    ...(spec.members >= 2 ? method : []),
+    ...(spec.members >= 3 ? fn : []),
+    '',
+  ].join('\n');
+}
+
+/**
+ * Write `options.files` annotated files under `rootDir`, a hundred per
+ * directory. Each file holds one service, depending on up to
+ * `maxDependencies` earlier services, plus a method and a function, so
+ * scans see realistic block sizes and graphs realistic fan-out. With
+ * `entities` or `dependencies`, the repository holds exactly that many.
+ */
+export function writeSyntheticRepo(
+  rootDir: string,
+  options: SyntheticRepoOptions,
+): SyntheticRepo {
+  const { files, seed = 1, pythonShare = 0.2, maxDependencies = 3 } = options;
+  const entities = options.entities ?? files * 3;
+  if (
+    !Number.isSafeInteger(entities) ||
+    entities <= (files - 1) * 3 ||
+    entities > files * 3
+  ) {
+    throw createKnowgraphError(
+      'usage',
+      `Cannot write ${entities} entities in ${files} files: each file holds one to three`,
+    );
+  }
+  const random = createRandom(seed);
+  const planned =
+    options.dependencies === undefined
+      ? undefined
+      : planDependencies(files, options.dependencies, random);
+  let dependencies = 0;
+  for (let index = 0; index < files; index++) {
+    const dir = join(rootDir, 'src', `d${Math.floor(index / FILES_PER_DIR)}`);
+    if (index % FILES_PER_DIR === 0) mkdirSync(dir, { recursive: true });
+    const spec: FileSpec = {
+      index,
+      members: Math.min(3, entities - index * 3),
+      owner: pick(OWNERS, random),
+      domain: pick(DOMAINS, random),
+      status: pick(STATUSES, random),
+      dependencies:
+        planned?.[index] ?? pickDependencies(index, maxDependencies, random),
+    };
+    dependencies += spec.dependencies.length;
+    const python = random() < pythonShare;
+    writeFileSync(
+      join(dir, python ? `service_${index}.py` : `service-${index}.ts`),
+      python ? pythonFile(spec) : typescriptFile(spec),
+      'utf-8',
+    );
+  }
+  return { rootDir, files, entities, dependencies };
+}
@@ -0,0 +1,67 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Writes a synthetic annotated repository of a requested graph size, with a snapshot of its graph, for load tests and evaluations
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bench, synthetic, fixtures, testdata, snapshot]
+ * context:
+ *   business_goal: Produce realistic graphs of any size without exposing a real codebase
+ *   domain: profiling
+ */
+import { existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import { scan } from '../library/knowgraph.js';
+import { writeGraphSnapshot } from '../snapshot/graph-snapshot.js';
+import { writeSyntheticRepo } from './synthetic-repo.js';
+import type { TestData, TestDataOptions } from './types.js';
+
+/**
+ * Write a synthetic repository with `nodes` entities and `edges`
+ * dependencies to `<outDir>/repo`, scan it, and write its graph to
+ * `<outDir>/graph.kgs`. The counts returned are the scanned graph's, so a
+ * parser change that loses entities shows up in them.
+ */
+export function writeTestData(
+  outDir: string,
+  options: TestDataOptions,
+): TestData {
+  const { nodes, edges, seed = 1, pythonShare } = options;
+  if (!Number.isSafeInteger(nodes) || nodes < 1) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid node count ${nodes}: use a positive integer`,
+    );
+  }
+  const rootDir = join(outDir, 'repo');
+  if (existsSync(rootDir)) {
+    throw createKnowgraphError(
+      'usage',
+      `${rootDir} already exists; remove it or write the test data elsewhere`,
+    );
+  }
+
+  const repo = writeSyntheticRepo(rootDir, {
+    files: Math.ceil(nodes / 3),
+    entities: nodes,
+    dependencies: edges,
+    seed,
+    pythonShare,
+  });
+  const result = scan(rootDir);
+  try {
+    const graph = result.graph();
+    const snapshotPath = join(outDir, 'graph.kgs');
+    writeGraphSnapshot(snapshotPath, graph);
+    return {
+      rootDir,
+      snapshotPath,
+      files: repo.files,
+      nodes: graph.nodes.length,
+      edges: graph.edges.length,
+    };
+  } finally {
+    result.close();
+  }
+}
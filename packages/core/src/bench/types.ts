@@ -0,0 +1,120 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the scanner and graph builder benchmarks, their synthetic repositories, and regression checks
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bench, performance, regression, types, interface]
+ * context:
+ *   business_goal: Keep benchmark reports comparable across versions that write them
+ *   domain: profiling
+ */
+
+export interface SyntheticRepoOptions {
+  /** Annotated source files to write. */
+  readonly files: number;
+  /** Seeds the generator; the same seed writes the same repository. */
+  readonly seed?: number;
+  /** Share of files written as Python rather than TypeScript (default: 0.2). */
+  readonly pythonShare?: number;
+  /** Services each service depends on, at most (default: 3). */
+  readonly maxDependencies?: number;
+  /**
+   * Annotated entities to write (default: three per file). Fewer than three
+   * per file leaves the method, then the function, out of the last file.
+   */
+  readonly entities?: number;
+  /**
+   * `dependencies.services` entries to write in all, in place of up to
+   * `maxDependencies` per service. Most point at a few early services, as
+   * real graphs have shared services many others call.
+   */
+  readonly dependencies?: number;
+}
+
+export interface SyntheticRepo {
+  readonly rootDir: string;
+  readonly files: number;
+  /** Annotated entities written, for checking a scan found them all. */
+  readonly entities: number;
+  /** `dependencies.services` entries written. */
+  readonly dependencies: number;
+}
+
+export interface TestDataOptions {
+  /** Graph nodes, as annotated entities, three to a file. */
+  readonly nodes: number;
+  /** Graph edges, as dependencies between services. */
+  readonly edges: number;
+  readonly seed?: number;
+  /** Share of files written as Python rather than TypeScript (default: 0.2). */
+  readonly pythonShare?: number;
+}
+
+export interface TestData {
+  /** The annotated repository, ready for `knowgraph index`. */
+  readonly rootDir: string;
+  /** A binary snapshot of the repository's graph. */
+  readonly snapshotPath: string;
+  readonly files: number;
+  readonly nodes: number;
+  readonly edges: number;
+}
+
+/** What a benchmark times: a full scan, or building the graph after one. */
+export type BenchCase = 'scan' | 'graph';
+
+export interface BenchOptions {
+  /** Repository sizes in files, such as 1000 and 10000. */
+  readonly sizes: readonly number[];
+  /** Timed runs per case; the median is reported (default: 3). */
+  readonly runs?: number;
+  /** Directory the synthetic repositories are written to. */
+  readonly workDir: string;
+  readonly seed?: number;
+  /** knowgraph version recorded in the report. */
+  readonly version?: string;
+  /** Called before each size is benchmarked, for progress output. */
+  readonly onSize?: (files: number) => void;
+}
+
+export interface BenchResult {
+  /** `<case>/<size>`, such as `scan/10k`; the key reports are compared by. */
+  readonly name: string;
+  readonly case: BenchCase;
+  readonly files: number;
+  readonly entities: number;
+  readonly edges: number;
+  readonly runs: number;
+  readonly medianMs: number;
+  readonly minMs: number;
+  readonly maxMs: number;
+}
+
+/** Machine-readable results of one `knowgraph bench` run. */
+export interface BenchReport {
+  readonly schema: 1;
+  readonly version: string;
+  readonly createdAt: string;
+  readonly node: string;
+  readonly os: string;
+  readonly arch: string;
+  readonly cpus: number;
+  readonly results: readonly BenchResult[];
+}
+
+export interface BenchCompareOptions {
+  /** Slowdown of the median, in percent, that counts as a regression. */
+  readonly maxRegressionPercent?: number;
+  /** Slowdowns smaller than this many milliseconds are noise (default: 5). */
+  readonly minDeltaMs?: number;
+}
+
+export interface BenchComparison {
+  readonly name: string;
+  readonly baselineMs: number;
+  readonly currentMs: number;
+  /** Positive when slower than the baseline. */
+  readonly changePercent: number;
+  readonly regressed: boolean;
+}
@@ -0,0 +1,335 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import {
+  mkdirSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  convertToGoDirectives,
+  editAnnotations,
+  expandAnnotations,
+  importEditCsv,
+  matchesEditQuery,
+  parseEditAssignment,
+  parseEditCsv,
+  parseEditQuery,
+} from '../bulk-edit.js';
+
+const CHARGE = [
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Charges a card',
+  ' * status: experimental # until the PSP migration',
+  ' * tags: [payments, cards]',
+  ' */',
+  'export function charge(): void {}',
+  '',
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Refunds a charge',
+  ' * status: stable',
+  ' * tags: [payments]',
+  ' */',
+  'export function refund(): void {}',
+  '',
+].join('\n');
+
+const PAYOUT = [
+  'def payout(batch):',
+  '    """',
+  '    @knowgraph',
+  '    type: function',
+  '    description: Pays out a batch',
+  '    status: experimental',
+  '    tags: [payments]',
+  '    """',
+  '    return batch',
+  '',
+].join('\n');
+
+describe('parseEditQuery', () => {
+  it('reads terms joined by AND', () => {
+    expect(
+      parseEditQuery('tag=payments AND status != "stable" AND path=src/'),
+    ).toEqual([
+      { field: 'tag', negated: false, value: 'payments' },
+      { field: 'status', negated: true, value: 'stable' },
+      { field: 'path', negated: false, value: 'src/' },
+    ]);
+  });
+
+  it('rejects empty queries and terms without a value', () => {
+    expect(() => parseEditQuery(' ')).toThrow('The edit query is empty');
+    expect(() => parseEditQuery('tag=payments AND status')).toThrow(
+      'Invalid query term "status"',
+    );
+  });
+});
+
+describe('parseEditAssignment', () => {
+  it('reads the value as YAML', () => {
+    expect(parseEditAssignment('status=beta')).toEqual({
+      field: 'status',
+      value: 'beta',
+    });
+    expect(parseEditAssignment('tags=[payments, psp]').value).toEqual([
+      'payments',
+      'psp',
+    ]);
+    expect(() => parseEditAssignment('status')).toThrow(
+      'Invalid assignment "status"',
+    );
+  });
+});
+
+describe('parseEditCsv', () => {
+  it('reads one field per row, skipping blank values', () => {
+    const csv = [
+      'Node,Field,Value',
+      'src/charge.ts:charge,owner,payments-team',
+      'refund,tags,"[payments, refunds]"',
+      'payout,owner,',
+    ].join('\n');
+    expect(parseEditCsv(csv)).toEqual([
+      { row: 1, node: 'src/charge.ts:charge', field: 'owner', value: 'payments-team' },
+      { row: 2, node: 'refund', field: 'tags', value: ['payments', 'refunds'] },
+    ]);
+  });
+
+  it('rejects a missing column or a bad field', () => {
+    expect(() => parseEditCsv('node,value\ncharge,psp')).toThrow(
+      'Edit CSV has no field column',
+    );
+    expect(() => parseEditCsv('id,field,value\ncharge,own er,psp')).toThrow(
+      'Invalid field "own er" in CSV row 1',
+    );
+  });
+});
+
+describe('matchesEditQuery', () => {
+  const fields = {
+    status: 'experimental',
+    tags: ['payments'],
+    context: { domain: 'billing' },
+  };
+
+  it('matches tags, dot paths, and directories', () => {
+    const match = (query: string, filePath = 'src/pay/charge.ts') =>
+      matchesEditQuery(fields, filePath, parseEditQuery(query));
+    expect(match('tag=payments AND context.domain=billing')).toBe(true);
+    expect(match('path=src/pay')).toBe(true);
+    expect(match('path=src/pa')).toBe(false);
+    expect(match('owner!=platform')).toBe(true);
+    expect(match('tag!=payments')).toBe(false);
+  });
+});
+
+describe('editAnnotations', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-edit-'));
+    mkdirSync(join(dir, 'src'));
+    writeFileSync(join(dir, 'src', 'charge.ts'), CHARGE);
+    writeFileSync(join(dir, 'src', 'payout.py'), PAYOUT);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('sets fields on matching annotations, keeping comments', () => {
+    const result = editAnnotations(
+      dir,
+      parseEditQuery('tag=payments AND status=experimental'),
+      [parseEditAssignment('status=stable'), parseEditAssignment('owner=psp')],
+    );
+    expect(result.matches).toEqual([
+      { filePath: 'src/charge.ts', line: 2, changed: true },
+      { filePath: 'src/payout.py', line: 3, changed: true },
+    ]);
+    const charge = readFileSync(join(dir, 'src', 'charge.ts'), 'utf-8');
+    expect(charge).toContain(
+      ' * status: stable # until the PSP migration\n * tags: [payments, cards]\n * owner: psp\n */',
+    );
+    expect(charge).toContain(' * status: stable\n * tags: [payments]\n */');
+    expect(readFileSync(join(dir, 'src', 'payout.py'), 'utf-8')).toContain(
+      '    status: stable\n    tags: [payments]\n    owner: psp\n    """',
+    );
+  });
+
+  it('leaves files alone on a dry run or when nothing changes', () => {
+    const result = editAnnotations(
+      join(dir, 'src', 'charge.ts'),
+      parseEditQuery('tag=payments'),
+      [parseEditAssignment('status=stable')],
+      { write: false },
+    );
+    expect(result.matches.map((match) => match.changed)).toEqual([
+      true,
+      false,
+    ]);
+    expect(result.files).toHaveLength(1);
+    expect(readFileSync(join(dir, 'src', 'charge.ts'), 'utf-8')).toBe(CHARGE);
+  });
+
+  it('refuses an edit that makes an annotation invalid', () => {
+    expect(() =>
+      editAnnotations(dir, parseEditQuery('tag=payments'), [
+        parseEditAssignment('status=beta'),
+      ]),
+    ).toThrow(/^The edit would make src\/charge.ts:\d+ invalid/);
+    expect(readFileSync(join(dir, 'src', 'charge.ts'), 'utf-8')).toBe(CHARGE);
+  });
+});
+
+describe('importEditCsv', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-edit-csv-'));
+    mkdirSync(join(dir, 'src'));
+    writeFileSync(join(dir, 'src', 'charge.ts'), CHARGE);
+    writeFileSync(join(dir, 'src', 'payout.py'), PAYOUT);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('sets each row on the annotation of its node', () => {
+    const result = importEditCsv(
+      dir,
+      parseEditCsv(
+        [
+          'node,field,value',
+          'src/charge.ts:charge,owner,cards-team',
+          'refund,owner,refunds-team',
+          'refund,context.domain,billing',
+          'payout,owner,payouts-team',
+          'missing,owner,nobody',
+        ].join('\n'),
+      ),
+    );
+    expect(result.matches).toEqual([
+      { filePath: 'src/charge.ts', line: 2, changed: true },
+      { filePath: 'src/charge.ts', line: 11, changed: true },
+      { filePath: 'src/payout.py', line: 3, changed: true },
+    ]);
+    expect(result.unresolved).toEqual([
+      { row: 5, node: 'missing', reason: 'matches no annotated symbol' },
+    ]);
+    const charge = readFileSync(join(dir, 'src', 'charge.ts'), 'utf-8');
+    expect(charge).toContain(' * tags: [payments, cards]\n * owner: cards-team');
+    expect(charge).toContain(' * owner: refunds-team\n');
+    expect(charge).toContain('domain: billing');
+    expect(readFileSync(join(dir, 'src', 'payout.py'), 'utf-8')).toContain(
+      '    owner: payouts-team\n    """',
+    );
+  });
+
+  it('writes nothing on a dry run', () => {
+    const result = importEditCsv(
+      dir,
+      parseEditCsv('node,field,value\ncharge,owner,cards-team'),
+      { write: false },
+    );
+    expect(result.files).toHaveLength(1);
+    expect(readFileSync(join(dir, 'src', 'charge.ts'), 'utf-8')).toBe(CHARGE);
+  });
+});
+
+describe('expandAnnotations', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-edit-expand-'));
+    mkdirSync(join(dir, 'src'));
+    writeFileSync(join(dir, 'src', 'charge.ts'), CHARGE);
+    writeFileSync(
+      join(dir, 'src', 'util.ts'),
+      [
+        '// knowgraph: type=function owner=payments-team tags=money',
+        'export function cents(): number { return 100; }',
+        '// knowgraph: type=function owner',
+        'export function dollars(): number { return 1; }',
+        '',
+      ].join('\n'),
+    );
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('rewrites compact annotations and reports the ones that do not parse', () => {
+    const result = expandAnnotations(dir);
+    expect(result.matches).toEqual([
+      { filePath: 'src/util.ts', line: 1, changed: true },
+    ]);
+    expect(result.invalid).toEqual([
+      {
+        filePath: 'src/util.ts',
+        line: 3,
+        message: 'Expected key=value, got "owner"',
+      },
+    ]);
+    const util = readFileSync(join(dir, 'src', 'util.ts'), 'utf-8');
+    expect(util).toContain(
+      '/**\n * @knowgraph\n * type: function\n * owner: payments-team\n * tags: [money]\n */\nexport function cents',
+    );
+    expect(readFileSync(join(dir, 'src', 'charge.ts'), 'utf-8')).toBe(CHARGE);
+  });
+
+  it('writes nothing on a dry run', () => {
+    const result = expandAnnotations(dir, { write: false });
+    expect(result.files).toHaveLength(1);
+    expect(readFileSync(join(dir, 'src', 'util.ts'), 'utf-8')).toMatch(
+      /^\/\/ knowgraph:/,
+    );
+  });
+});
+
+describe('convertToGoDirectives', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-edit-godoc-'));
+    writeFileSync(join(dir, 'charge.ts'), CHARGE);
+    writeFileSync(
+      join(dir, 'charge.go'),
+      [
+        'package billing',
+        '',
+        '// @knowgraph',
+        '// type: function',
+        '// description: Charges a card',
+        'func Charge() {}',
+        '',
+      ].join('\n'),
+    );
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('rewrites the Go files only', () => {
+    const result = convertToGoDirectives(dir);
+    expect(result.matches).toEqual([
+      { filePath: 'charge.go', line: 3, changed: true },
+    ]);
+    expect(result.invalid).toEqual([]);
+    expect(readFileSync(join(dir, 'charge.go'), 'utf-8')).toContain(
+      '//knowgraph:type=function description="Charges a card"\nfunc Charge',
+    );
+    expect(readFileSync(join(dir, 'charge.ts'), 'utf-8')).toBe(CHARGE);
+  });
+});
+
@@ -0,0 +1,481 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Sets annotation fields in source on every annotation a tag, path, or field query matches, or per node from a CSV, keeping comments and layout, and converts between annotation forms
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bulkedit, annotations, query, rewriter, csv]
+ * context:
+ *   business_goal: Change annotation fields across many files without disturbing the code or comments around them
+ *   domain: bulkedit
+ */
+import { existsSync, readFileSync, statSync, writeFileSync } from 'node:fs';
+import { basename, dirname, extname, join } from 'node:path';
+import { isScalar, parse as parseYaml } from 'yaml';
+import type { Document } from 'yaml';
+import { createKnowgraphError } from '../errors/errors.js';
+import { findSymbol } from '../explain/explain.js';
+import { parseCsv } from '../finops/csv.js';
+import { valueAtPath } from '../inbound/mapping.js';
+import { generateEntityId } from '../indexer/database.js';
+import { listIndexableFiles } from '../indexer/indexer.js';
+import { validateMetadata } from '../parsers/metadata-extractor.js';
+import { createDefaultRegistry } from '../parsers/registry.js';
+import {
+  expandCompactAnnotations,
+  findAnnotationBlocks,
+  findSymbolBlock,
+  rewriteAnnotation,
+  toGoDirectives,
+} from '../rewriter/index.js';
+import type { AnnotationBlock } from '../rewriter/index.js';
+import type {
+  EditAssignment,
+  EditCondition,
+  EditCsvResult,
+  EditCsvRow,
+  EditDirectiveResult,
+  EditedFile,
+  EditExpandResult,
+  EditMatch,
+  EditOptions,
+  EditQuery,
+  EditResult,
+  UnconvertedAnnotation,
+  UnexpandedAnnotation,
+  UnresolvedEditRow,
+} from './types.js';
+
+const TERM = /^([\w.-]+)\s*(!?=)\s*(.*)$/;
+const ASSIGNMENT = /^([\w.-]+)=(.*)$/;
+
+/** Accepted header names for each column of an edit CSV. */
+const CSV_COLUMNS = {
+  node: ['node', 'id', 'node_id', 'symbol'],
+  field: ['field'],
+  value: ['value'],
+} as const;
+
+function unquote(value: string): string {
+  const match = /^(["'])(.*)\1$/.exec(value);
+  return match ? match[2] : value;
+}
+
+/**
+ * Parse a query such as `tag=payments AND status!=stable`. Terms are
+ * joined by an upper-case `AND`, and values may be quoted. Throws a usage
+ * error on an empty query or a term without `=`.
+ */
+export function parseEditQuery(text: string): EditQuery {
+  const terms = text.trim() === '' ? [] : text.trim().split(/\s+AND\s+/);
+  if (terms.length === 0) {
+    throw createKnowgraphError('usage', 'The edit query is empty');
+  }
+  return terms.map((term): EditCondition => {
+    const match = TERM.exec(term.trim());
+    const value = match ? unquote(match[3].trim()) : '';
+    if (!match || value === '') {
+      throw createKnowgraphError(
+        'usage',
+        `Invalid query term "${term}": use field=value or field!=value`,
+      );
+    }
+    return { field: match[1], negated: match[2] === '!=', value };
+  });
+}
+
+/**
+ * Parse a `field=value` to set. The value is read as YAML, so `3`, `true`,
+ * and `[a, b]` keep their types; quote it to set a string such as `"3"`.
+ */
+export function parseEditAssignment(text: string): EditAssignment {
+  const match = ASSIGNMENT.exec(text.trim());
+  if (!match || match[2].trim() === '') {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid assignment "${text}": use field=value`,
+    );
+  }
+  try {
+    return { field: match[1], value: parseYaml(match[2]) as unknown };
+  } catch (err) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid value in "${text}": ${err instanceof Error ? err.message : String(err)}`,
+    );
+  }
+}
+
+function matchesCondition(
+  fields: Record<string, unknown>,
+  filePath: string,
+  condition: EditCondition,
+): boolean {
+  const { field, value } = condition;
+  let found: boolean;
+  if (field === 'path') {
+    const dir = value.replace(/^\.\/?/, '').replace(/\/+$/, '');
+    found = dir === '' || filePath === dir || filePath.startsWith(`${dir}/`);
+  } else {
+    const actual = valueAtPath(fields, field === 'tag' ? 'tags' : field);
+    found = Array.isArray(actual)
+      ? actual.some((item) => String(item) === value)
+      : actual !== undefined && actual !== null && String(actual) === value;
+  }
+  return found !== condition.negated;
+}
+
+/** Whether the annotation `fields` in `filePath` match every term. */
+export function matchesEditQuery(
+  fields: Record<string, unknown>,
+  filePath: string,
+  query: EditQuery,
+): boolean {
+  return query.every((condition) =>
+    matchesCondition(fields, filePath, condition),
+  );
+}
+
+function listEditFiles(path: string): {
+  readonly rootDir: string;
+  readonly files: readonly string[];
+} {
+  if (!existsSync(path)) {
+    throw createKnowgraphError('io', `Path not found: ${path}`);
+  }
+  if (statSync(path).isFile()) {
+    return { rootDir: dirname(path), files: [basename(path)] };
+  }
+  const registry = createDefaultRegistry();
+  const adapter = {
+    parse: () => [],
+    canParse: (filePath: string) => registry.getParser(filePath) !== undefined,
+  };
+  return { rootDir: path, files: listIndexableFiles(path, adapter) };
+}
+
+/** The block's fields, or undefined when its YAML is not a mapping. */
+function readFields(
+  block: AnnotationBlock,
+): Record<string, unknown> | undefined {
+  try {
+    const fields: unknown = parseYaml(block.yaml);
+    return typeof fields === 'object' && fields !== null
+      ? (fields as Record<string, unknown>)
+      : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Set one field, updating a scalar in place so the comment after it stays,
+ * and writing lists and mappings in flow style as annotations are.
+ */
+function assign(doc: Document, { field, value }: EditAssignment): void {
+  const path = field.split('.');
+  const node = doc.getIn(path, true);
+  if (isScalar(node) && (value === null || typeof value !== 'object')) {
+    node.value = value;
+  } else {
+    doc.setIn(path, doc.createNode(value, { flow: true }));
+  }
+}
+
+function isValid(fields: Record<string, unknown> | undefined): boolean {
+  return validateMetadata(fields).errors.length === 0;
+}
+
+/** The assignments to make on one annotation block. */
+interface BlockEdit {
+  readonly block: AnnotationBlock;
+  readonly assignments: readonly EditAssignment[];
+}
+
+/**
+ * Make `edits` on one file's content, bottom-up so an added field does
+ * not move the blocks above it. Throws a usage error when an edit would
+ * make a valid annotation fail validation.
+ */
+function editFile(
+  filePath: string,
+  before: string,
+  edits: readonly BlockEdit[],
+): { readonly after: string; readonly matches: readonly EditMatch[] } {
+  let after = before;
+  const matches: EditMatch[] = [];
+  const ordered = [...edits].sort(
+    (a, b) => b.block.markerLine - a.block.markerLine,
+  );
+  for (const { block, assignments } of ordered) {
+    const edited = rewriteAnnotation(after, block.markerLine, (doc) => {
+      for (const assignment of assignments) assign(doc, assignment);
+    });
+    const changed = edited !== after;
+    if (changed && isValid(readFields(block))) {
+      const editedBlock = findAnnotationBlocks(edited).find(
+        (candidate) => candidate.markerLine === block.markerLine,
+      );
+      const { errors } = validateMetadata(
+        editedBlock && readFields(editedBlock),
+      );
+      if (errors.length > 0) {
+        throw createKnowgraphError(
+          'usage',
+          `The edit would make ${filePath}:${block.markerLine} invalid: ${errors[0].message}`,
+        );
+      }
+    }
+    after = edited;
+    matches.unshift({ filePath, line: block.markerLine, changed });
+  }
+  return { after, matches };
+}
+
+function writeEditedFiles(rootDir: string, files: readonly EditedFile[]): void {
+  for (const file of files) {
+    writeFileSync(join(rootDir, file.filePath), file.after, 'utf-8');
+  }
+}
+
+/**
+ * Set `assignments` on every annotation under `path` (a directory or one
+ * file) that `query` matches, and write the files unless `write` is
+ * false. Comments and the layout of other fields are kept. Throws a usage
+ * error, writing nothing, when an edit would make a valid annotation fail
+ * validation.
+ */
+export function editAnnotations(
+  path: string,
+  query: EditQuery,
+  assignments: readonly EditAssignment[],
+  options: EditOptions = {},
+): EditResult {
+  const { write = true } = options;
+  const { rootDir, files: filePaths } = listEditFiles(path);
+  const matches: EditMatch[] = [];
+  const files: EditedFile[] = [];
+  for (const filePath of filePaths) {
+    const before = readFileSync(join(rootDir, filePath), 'utf-8');
+    if (!before.includes('@knowgraph')) continue;
+    const edits = findAnnotationBlocks(before)
+      .filter((block) => {
+        const fields = readFields(block);
+        return (
+          fields !== undefined && matchesEditQuery(fields, filePath, query)
+        );
+      })
+      .map((block) => ({ block, assignments }));
+    const edited = editFile(filePath, before, edits);
+    matches.push(...edited.matches);
+    if (edited.after !== before) {
+      files.push({ filePath, before, after: edited.after });
+    }
+  }
+  if (write) writeEditedFiles(rootDir, files);
+  return { matches, files };
+}
+
+/**
+ * Rewrite every compact `knowgraph:` annotation under `path` (a directory
+ * or one file) as a full @knowgraph YAML block, and write the files
+ * unless `write` is false. Compact annotations that do not parse are left
+ * as they are and reported.
+ */
+export function expandAnnotations(
+  path: string,
+  options: EditOptions = {},
+): EditExpandResult {
+  const { write = true } = options;
+  const { rootDir, files: filePaths } = listEditFiles(path);
+  const matches: EditMatch[] = [];
+  const files: EditedFile[] = [];
+  const invalid: UnexpandedAnnotation[] = [];
+  for (const filePath of filePaths) {
+    const before = readFileSync(join(rootDir, filePath), 'utf-8');
+    if (!before.includes('knowgraph:')) continue;
+    const { content, expanded, errors } = expandCompactAnnotations(
+      before,
+      filePath,
+    );
+    for (const line of expanded) {
+      matches.push({ filePath, line, changed: true });
+    }
+    for (const error of errors) invalid.push({ filePath, ...error });
+    if (content !== before) files.push({ filePath, before, after: content });
+  }
+  if (write) writeEditedFiles(rootDir, files);
+  return { matches, files, invalid };
+}
+
+/**
+ * Rewrite every `// @knowgraph` YAML block in the Go files under `path`
+ * as `//knowgraph:` directive lines, which godoc does not render, and
+ * write the files unless `write` is false. Blocks with no directive form,
+ * such as ones with YAML comments or lists of objects, are left as they
+ * are and reported.
+ */
+export function convertToGoDirectives(
+  path: string,
+  options: EditOptions = {},
+): EditDirectiveResult {
+  const { write = true } = options;
+  const { rootDir, files: filePaths } = listEditFiles(path);
+  const matches: EditMatch[] = [];
+  const files: EditedFile[] = [];
+  const invalid: UnconvertedAnnotation[] = [];
+  for (const filePath of filePaths) {
+    if (extname(filePath) !== '.go') continue;
+    const before = readFileSync(join(rootDir, filePath), 'utf-8');
+    if (!before.includes('@knowgraph')) continue;
+    const { content, converted, errors } = toGoDirectives(before);
+    for (const line of converted) {
+      matches.push({ filePath, line, changed: true });
+    }
+    for (const error of errors) invalid.push({ filePath, ...error });
+    if (content !== before) files.push({ filePath, before, after: content });
+  }
+  if (write) writeEditedFiles(rootDir, files);
+  return { matches, files, invalid };
+}
+
+/**
+ * Parse a CSV with `node`, `field`, and `value` columns, one field per
+ * row; `id` or `symbol` may name the node column. Rows with an empty
+ * node, field, or value are skipped, so blank spreadsheet cells change
+ * nothing. Values are read as YAML, as `--set` values are. Throws a parse
+ * error on a missing column, a bad field name, or a value that is not YAML.
+ */
+export function parseEditCsv(text: string): readonly EditCsvRow[] {
+  const [header, ...rows] = parseCsv(text);
+  if (!header) return [];
+  const names = header.map((column) => column.trim().toLowerCase());
+  const find = (candidates: readonly string[]) =>
+    names.findIndex((name) => candidates.includes(name));
+  const columns = {
+    node: find(CSV_COLUMNS.node),
+    field: find(CSV_COLUMNS.field),
+    value: find(CSV_COLUMNS.value),
+  };
+  const missing = Object.keys(columns).filter(
+    (key) => columns[key as keyof typeof columns] === -1,
+  );
+  if (missing.length > 0) {
+    throw createKnowgraphError(
+      'parse',
+      `Edit CSV has no ${missing.join(', ')} column`,
+    );
+  }
+
+  const parsed: EditCsvRow[] = [];
+  rows.forEach((cells, index) => {
+    const row = index + 1;
+    const node = (cells[columns.node] ?? '').trim();
+    const field = (cells[columns.field] ?? '').trim();
+    const value = (cells[columns.value] ?? '').trim();
+    if (!node || !field || !value) return;
+    if (!/^[\w.-]+$/.test(field)) {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid field "${field}" in CSV row ${row}`,
+      );
+    }
+    try {
+      parsed.push({ row, node, field, value: parseYaml(value) as unknown });
+    } catch (err) {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid value in CSV row ${row}: ${err instanceof Error ? err.message : String(err)}`,
+      );
+    }
+  });
+  return parsed;
+}
+
+/** An annotated symbol a CSV row can name, with the block annotating it. */
+interface EditTarget {
+  readonly id: string;
+  readonly filePath: string;
+  readonly name: string;
+  readonly line: number;
+  readonly parent: string | null;
+  readonly block: AnnotationBlock;
+}
+
+/**
+ * Set the field each CSV row names on the annotation of its node, given
+ * as an entity id, `path:name`, `path:line`, `Parent.name`, or a name, as
+ * `knowgraph explain` accepts. Rows whose node matches no annotated
+ * symbol under `path`, or several, are returned as `unresolved` and the
+ * rest are applied; files are written unless `write` is false. Throws a
+ * usage error, writing nothing, when an edit would make a valid annotation
+ * fail validation.
+ */
+export function importEditCsv(
+  path: string,
+  rows: readonly EditCsvRow[],
+  options: EditOptions = {},
+): EditCsvResult {
+  const { write = true } = options;
+  const { rootDir, files: filePaths } = listEditFiles(path);
+  const registry = createDefaultRegistry();
+  const contents = new Map<string, string>();
+  const targets: EditTarget[] = [];
+  for (const filePath of filePaths) {
+    const content = readFileSync(join(rootDir, filePath), 'utf-8');
+    if (!content.includes('@knowgraph')) continue;
+    contents.set(filePath, content);
+    const blocks = findAnnotationBlocks(content);
+    for (const result of registry.parseFile(content, filePath).results) {
+      const block = findSymbolBlock(blocks, result);
+      if (!block) continue;
+      targets.push({
+        id: generateEntityId(filePath, result.name, result.line),
+        filePath,
+        name: result.name,
+        line: result.line,
+        parent: result.parent ?? null,
+        block,
+      });
+    }
+  }
+
+  const unresolved: UnresolvedEditRow[] = [];
+  const edits = new Map<string, Map<AnnotationBlock, EditAssignment[]>>();
+  for (const { row, node, field, value } of rows) {
+    const found = findSymbol(targets, node);
+    if (found.length !== 1) {
+      const reason =
+        found.length === 0
+          ? 'matches no annotated symbol'
+          : `matches ${found.length} symbols (${found.map((target) => `${target.filePath}:${target.line}`).join(', ')})`;
+      unresolved.push({ row, node, reason });
+      continue;
+    }
+    const [{ filePath, block }] = found;
+    const fileEdits = edits.get(filePath) ?? new Map();
+    fileEdits.set(block, [
+      ...(fileEdits.get(block) ?? []),
+      { field, value },
+    ]);
+    edits.set(filePath, fileEdits);
+  }
+
+  const matches: EditMatch[] = [];
+  const files: EditedFile[] = [];
+  for (const [filePath, before] of contents) {
+    const fileEdits = edits.get(filePath);
+    if (!fileEdits) continue;
+    const edited = editFile(
+      filePath,
+      before,
+      [...fileEdits].map(([block, assignments]) => ({ block, assignments })),
+    );
+    matches.push(...edited.matches);
+    if (edited.after !== before) {
+      files.push({ filePath, before, after: edited.after });
+    }
+  }
+  if (write) writeEditedFiles(rootDir, files);
+  return { matches, files, unresolved };
+}
@@ -0,0 +1,26 @@
+export type {
+  EditCondition,
+  EditQuery,
+  EditAssignment,
+  EditOptions,
+  EditMatch,
+  EditedFile,
+  EditResult,
+  EditCsvRow,
+  UnresolvedEditRow,
+  EditCsvResult,
+  UnexpandedAnnotation,
+  EditExpandResult,
+  UnconvertedAnnotation,
+  EditDirectiveResult,
+} from './types.js';
+export {
+  editAnnotations,
+  expandAnnotations,
+  importEditCsv,
+  convertToGoDirectives,
+  matchesEditQuery,
+  parseEditAssignment,
+  parseEditCsv,
+  parseEditQuery,
+} from './bulk-edit.js';
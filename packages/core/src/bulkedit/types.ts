@@ -0,0 +1,103 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for bulk edits that set annotation fields on every annotation a query matches
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bulkedit, annotations, query, rewriter, types, interface]
+ * context:
+ *   business_goal: Let a planned bulk edit be previewed as a diff before anything is written
+ *   domain: bulkedit
+ */
+
+/**
+ * One `field=value` or `field!=value` term of an edit query. `tag`
+ * matches one of `tags`, `path` the annotated file or a directory above
+ * it, and any other field the annotation's value at that dot path.
+ */
+export interface EditCondition {
+  readonly field: string;
+  readonly negated: boolean;
+  readonly value: string;
+}
+
+/** Terms joined by `AND`; an annotation must match them all. */
+export type EditQuery = readonly EditCondition[];
+
+/** A `field=value` to set, the value read as YAML. */
+export interface EditAssignment {
+  /** Dot path, such as `status` or `context.domain`. */
+  readonly field: string;
+  readonly value: unknown;
+}
+
+export interface EditOptions {
+  /** Write the edits into the files (default: true). */
+  readonly write?: boolean;
+}
+
+/** An annotation the query matched. */
+export interface EditMatch {
+  /** Relative to the edited directory, with `/` separators. */
+  readonly filePath: string;
+  /** 1-based line of the @knowgraph marker, before any edit. */
+  readonly line: number;
+  /** False when the annotation already had every value. */
+  readonly changed: boolean;
+}
+
+export interface EditedFile {
+  readonly filePath: string;
+  readonly before: string;
+  readonly after: string;
+}
+
+export interface EditResult {
+  readonly matches: readonly EditMatch[];
+  readonly files: readonly EditedFile[];
+}
+
+/** One CSV row setting a field on the annotation of a node. */
+export interface EditCsvRow {
+  /** 1-based data row, not counting the header. */
+  readonly row: number;
+  /** Entity id, `path:name`, `path:line`, `Parent.name`, or a name. */
+  readonly node: string;
+  readonly field: string;
+  readonly value: unknown;
+}
+
+/** A CSV row whose node names no annotated symbol, or several. */
+export interface UnresolvedEditRow {
+  readonly row: number;
+  readonly node: string;
+  readonly reason: string;
+}
+
+export interface EditCsvResult extends EditResult {
+  readonly unresolved: readonly UnresolvedEditRow[];
+}
+
+/** A compact annotation left as it was because it does not parse. */
+export interface UnexpandedAnnotation {
+  readonly filePath: string;
+  /** 1-based line of the compact annotation. */
+  readonly line: number;
+  readonly message: string;
+}
+
+export interface EditExpandResult extends EditResult {
+  readonly invalid: readonly UnexpandedAnnotation[];
+}
+
+/** A Go @knowgraph block left as it was because it has no directive form. */
+export interface UnconvertedAnnotation {
+  readonly filePath: string;
+  /** 1-based line of the `@knowgraph` marker. */
+  readonly line: number;
+  readonly message: string;
+}
+
+export interface EditDirectiveResult extends EditResult {
+  readonly invalid: readonly UnconvertedAnnotation[];
+}
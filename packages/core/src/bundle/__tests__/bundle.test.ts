@@ -0,0 +1,105 @@
+import { describe, it, expect } from 'vitest';
+import { deflateRawSync, inflateRawSync } from 'node:zlib';
+import { INDEX_SCHEMA_VERSION } from '../../indexer/schema.js';
+import { SNAPSHOT_VERSION } from '../../snapshot/graph-snapshot.js';
+import {
+  BUNDLE_PATHS,
+  bundleFile,
+  checkBundle,
+  decodeBundle,
+  encodeBundle,
+  isOfflineBundle,
+} from '../bundle.js';
+import type { BundleHeader } from '../types.js';
+
+const files = [
+  { path: BUNDLE_PATHS.index, bytes: Buffer.from('SQLite format 3\0') },
+  { path: BUNDLE_PATHS.site, bytes: Buffer.from('# Codebase\n') },
+  { path: BUNDLE_PATHS.config, bytes: Buffer.alloc(0) },
+];
+
+function header(schemas: Partial<BundleHeader['schemas']>): BundleHeader {
+  return {
+    bundleVersion: 1,
+    createdAt: '2026-01-01T00:00:00.000Z',
+    namespace: null,
+    schemas: {
+      manifest: '1.0',
+      index: INDEX_SCHEMA_VERSION,
+      snapshot: SNAPSHOT_VERSION,
+      ...schemas,
+    },
+    files: [],
+  };
+}
+
+describe('encodeBundle', () => {
+  it('round-trips files with their checksums', () => {
+    const bytes = encodeBundle(files, {
+      namespace: 'acme/shop',
+      now: new Date('2026-01-01T00:00:00Z'),
+    });
+    expect(isOfflineBundle(bytes)).toBe(true);
+    const bundle = decodeBundle(bytes);
+    expect(bundle.header).toMatchObject({
+      bundleVersion: 1,
+      createdAt: '2026-01-01T00:00:00.000Z',
+      namespace: 'acme/shop',
+      schemas: { index: INDEX_SCHEMA_VERSION, snapshot: SNAPSHOT_VERSION },
+    });
+    expect(bundle.header.files.map((file) => file.size)).toEqual([16, 11, 0]);
+    expect(Buffer.from(bundleFile(bundle, BUNDLE_PATHS.site)!).toString()).toBe(
+      '# Codebase\n',
+    );
+    expect(bundleFile(bundle, BUNDLE_PATHS.graph)).toBeUndefined();
+  });
+});
+
+describe('decodeBundle', () => {
+  it('rejects files that are not bundles or are from a newer release', () => {
+    expect(() => decodeBundle(Buffer.from('KGS\x05'))).toThrow(
+      'Not a knowgraph bundle',
+    );
+    const newer = encodeBundle(files);
+    newer[3] = 9;
+    expect(() => decodeBundle(newer)).toThrow('Upgrade knowgraph');
+  });
+
+  it('rejects bundles damaged in transit', () => {
+    const bytes = encodeBundle(files);
+    const body = inflateRawSync(bytes.subarray(4));
+    body[body.length - 3] ^= 0xff;
+    const damaged = Buffer.concat([bytes.subarray(0, 4), deflateRawSync(body)]);
+    expect(() => decodeBundle(damaged)).toThrow(
+      'site/CODEBASE.md does not match its checksum',
+    );
+    expect(() => decodeBundle(bytes.subarray(0, 20))).toThrow('damaged');
+  });
+
+  it('stops unpacking bundles that expand past the limit', () => {
+    const bytes = encodeBundle([
+      { path: BUNDLE_PATHS.index, bytes: Buffer.alloc(1024 * 1024) },
+    ]);
+    expect(bytes.length).toBeLessThan(4096);
+    expect(() => decodeBundle(bytes, { maxBytes: 64 * 1024 })).toThrow(
+      'The bundle is damaged: it unpacks to more than 65536 bytes',
+    );
+  });
+});
+
+describe('checkBundle', () => {
+  it('accepts a bundle from this release', () => {
+    expect(checkBundle(header({}))).toEqual([]);
+  });
+
+  it('stops on newer formats and warns on older ones', () => {
+    expect(
+      checkBundle(
+        header({ snapshot: SNAPSHOT_VERSION + 1, index: 1, manifest: '2.0' }),
+      ).map((problem) => problem.severity),
+    ).toEqual(['error', 'warning', 'warning']);
+    expect(
+      checkBundle(header({ index: INDEX_SCHEMA_VERSION + 1 }))[0].message,
+    ).toContain(`this release uses ${INDEX_SCHEMA_VERSION}`);
+  });
+});
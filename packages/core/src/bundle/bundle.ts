@@ -0,0 +1,192 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Packs an index, its graph snapshot, a readable overview, and the config into one checksummed archive, and unpacks and checks it on the other side of an air gap
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bundle, offline, air-gap, archive, checksum]
+ * context:
+ *   business_goal: Make sure a bundle carried across an air gap arrives complete or is refused
+ *   domain: bundle
+ */
+import { createHash } from 'node:crypto';
+import { deflateRawSync, inflateRawSync } from 'node:zlib';
+import { createKnowgraphError } from '../errors/errors.js';
+import { INDEX_SCHEMA_VERSION } from '../indexer/schema.js';
+import { SNAPSHOT_VERSION } from '../snapshot/graph-snapshot.js';
+import type {
+  BundleDecodeOptions,
+  BundleEncodeOptions,
+  BundleFile,
+  BundleHeader,
+  BundleProblem,
+  OfflineBundle,
+} from './types.js';
+
+// "KGB" followed by the format version
+const MAGIC = [0x4b, 0x47, 0x42];
+export const BUNDLE_VERSION = 1;
+
+/**
+ * Most bytes a bundle may unpack to, so a small crafted file cannot
+ * inflate until memory runs out.
+ */
+export const MAX_BUNDLE_BYTES = 2 * 1024 ** 3;
+
+/** The `.knowgraph.yml` schema version this release reads. */
+const MANIFEST_VERSION = '1.0';
+
+/** Where each part of a bundle is kept inside it. */
+export const BUNDLE_PATHS = {
+  index: 'index/knowgraph.db',
+  graph: 'graph/graph.kgs',
+  site: 'site/CODEBASE.md',
+  config: 'config/.knowgraph.yml',
+} as const;
+
+function sha256(bytes: Uint8Array): string {
+  return createHash('sha256').update(bytes).digest('hex');
+}
+
+export function isOfflineBundle(bytes: Uint8Array): boolean {
+  return MAGIC.every((b, i) => bytes[i] === b);
+}
+
+/**
+ * Pack `files` into one bundle: a header listing each file's size and
+ * SHA-256 and the format versions of the index and snapshot, then the
+ * files, deflated together.
+ */
+export function encodeBundle(
+  files: readonly BundleFile[],
+  options: BundleEncodeOptions = {},
+): Buffer {
+  const header: BundleHeader = {
+    bundleVersion: BUNDLE_VERSION,
+    createdAt: (options.now ?? new Date()).toISOString(),
+    namespace: options.namespace ?? null,
+    schemas: {
+      manifest: MANIFEST_VERSION,
+      index: INDEX_SCHEMA_VERSION,
+      snapshot: SNAPSHOT_VERSION,
+    },
+    files: files.map((file) => ({
+      path: file.path,
+      size: file.bytes.length,
+      sha256: sha256(file.bytes),
+    })),
+  };
+  const json = Buffer.from(JSON.stringify(header), 'utf-8');
+  const length = Buffer.alloc(4);
+  length.writeUInt32BE(json.length);
+  const body = Buffer.concat([length, json, ...files.map((f) => f.bytes)]);
+  return Buffer.concat([
+    Buffer.from([...MAGIC, BUNDLE_VERSION]),
+    deflateRawSync(body),
+  ]);
+}
+
+function damaged(detail: string): Error {
+  return createKnowgraphError('parse', `The bundle is damaged: ${detail}`);
+}
+
+/**
+ * Unpack a bundle written by `encodeBundle`, checking every file against
+ * the size and SHA-256 in its header. Throws a `parse` error for anything
+ * that is not a bundle, is from a newer release, was damaged in transit,
+ * or unpacks to more than `maxBytes`.
+ */
+export function decodeBundle(
+  bytes: Uint8Array,
+  options: BundleDecodeOptions = {},
+): OfflineBundle {
+  if (bytes.length < 4 || !isOfflineBundle(bytes)) {
+    throw createKnowgraphError('parse', 'Not a knowgraph bundle');
+  }
+  if (bytes[3] > BUNDLE_VERSION) {
+    throw createKnowgraphError(
+      'parse',
+      `The bundle has format version ${bytes[3]}; this release reads up to ${BUNDLE_VERSION}. Upgrade knowgraph to import it`,
+    );
+  }
+  const maxBytes = options.maxBytes ?? MAX_BUNDLE_BYTES;
+  let body: Buffer;
+  try {
+    body = inflateRawSync(bytes.subarray(4), { maxOutputLength: maxBytes });
+  } catch (err) {
+    if ((err as NodeJS.ErrnoException).code === 'ERR_BUFFER_TOO_LARGE') {
+      throw damaged(`it unpacks to more than ${maxBytes} bytes`);
+    }
+    throw damaged('it does not decompress');
+  }
+  if (body.length < 4) throw damaged('the header is missing');
+  const headerEnd = 4 + body.readUInt32BE(0);
+  let parsed: Partial<BundleHeader> | null;
+  try {
+    parsed = JSON.parse(body.subarray(4, headerEnd).toString('utf-8'));
+  } catch {
+    throw damaged('the header is not JSON');
+  }
+  if (!Array.isArray(parsed?.files) || !parsed.schemas) {
+    throw damaged('the header lists no files');
+  }
+  const header = parsed as BundleHeader;
+
+  const files: BundleFile[] = [];
+  let offset = headerEnd;
+  for (const entry of header.files) {
+    const file = body.subarray(offset, offset + entry.size);
+    offset += entry.size;
+    if (file.length !== entry.size) {
+      throw damaged(`${entry.path} is truncated`);
+    }
+    if (sha256(file) !== entry.sha256) {
+      throw damaged(`${entry.path} does not match its checksum`);
+    }
+    files.push({ path: entry.path, bytes: file });
+  }
+  return { header, files };
+}
+
+/**
+ * What keeps this release from loading `header`'s files as they are. An
+ * index or snapshot in a newer format than it reads is an error; an older
+ * index is a warning, as its rows may lack what this release stores, and
+ * so is a config for another schema version.
+ */
+export function checkBundle(header: BundleHeader): readonly BundleProblem[] {
+  const problems: BundleProblem[] = [];
+  const { schemas } = header;
+  if (schemas.snapshot > SNAPSHOT_VERSION) {
+    problems.push({
+      severity: 'error',
+      message: `The graph snapshot has format version ${schemas.snapshot}; this release reads up to ${SNAPSHOT_VERSION}`,
+    });
+  }
+  if (schemas.index > INDEX_SCHEMA_VERSION) {
+    problems.push({
+      severity: 'error',
+      message: `The index has schema version ${schemas.index}; this release uses ${INDEX_SCHEMA_VERSION}`,
+    });
+  } else if (schemas.index < INDEX_SCHEMA_VERSION) {
+    problems.push({
+      severity: 'warning',
+      message: `The index has schema version ${schemas.index}, older than this release's ${INDEX_SCHEMA_VERSION}; re-export it from an upgraded source for current results`,
+    });
+  }
+  if (schemas.manifest !== MANIFEST_VERSION) {
+    problems.push({
+      severity: 'warning',
+      message: `The config is for schema version ${schemas.manifest}; this release reads ${MANIFEST_VERSION}`,
+    });
+  }
+  return problems;
+}
+
+/** The file at `path` in `bundle`, if it carries one. */
+export function bundleFile(
+  bundle: OfflineBundle,
+  path: string,
+): Uint8Array | undefined {
+  return bundle.files.find((file) => file.path === path)?.bytes;
+}
@@ -0,0 +1,20 @@
+export type {
+  BundleDecodeOptions,
+  BundleEncodeOptions,
+  BundleFile,
+  BundleFileEntry,
+  BundleHeader,
+  BundleProblem,
+  BundleSchemas,
+  OfflineBundle,
+} from './types.js';
+export {
+  BUNDLE_PATHS,
+  BUNDLE_VERSION,
+  MAX_BUNDLE_BYTES,
+  bundleFile,
+  checkBundle,
+  decodeBundle,
+  encodeBundle,
+  isOfflineBundle,
+} from './bundle.js';
@@ -0,0 +1,69 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for offline bundles that carry an index, its graph, a readable overview, and the config across an air gap
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bundle, offline, air-gap, archive, types, interface]
+ * context:
+ *   business_goal: Keep bundles made by one knowgraph version loadable by another
+ *   domain: bundle
+ */
+
+/** A file carried in a bundle. */
+export interface BundleFile {
+  /** Path within the bundle, with `/` separators, such as `graph/graph.kgs`. */
+  readonly path: string;
+  readonly bytes: Uint8Array;
+}
+
+/** A file as the bundle header lists it, for checking what arrived. */
+export interface BundleFileEntry {
+  readonly path: string;
+  readonly size: number;
+  /** Hex SHA-256 of the file's bytes. */
+  readonly sha256: string;
+}
+
+/** Format versions of the bundled files, checked before they are loaded. */
+export interface BundleSchemas {
+  /** The `.knowgraph.yml` schema version. */
+  readonly manifest: string;
+  /** `INDEX_SCHEMA_VERSION` of the index. */
+  readonly index: number;
+  /** `SNAPSHOT_VERSION` of the graph snapshot. */
+  readonly snapshot: number;
+}
+
+export interface BundleHeader {
+  readonly bundleVersion: number;
+  readonly createdAt: string;
+  /** The namespace the index was built under, if any. */
+  readonly namespace: string | null;
+  readonly schemas: BundleSchemas;
+  readonly files: readonly BundleFileEntry[];
+}
+
+export interface OfflineBundle {
+  readonly header: BundleHeader;
+  readonly files: readonly BundleFile[];
+}
+
+export interface BundleEncodeOptions {
+  readonly namespace?: string;
+  readonly now?: Date;
+}
+
+export interface BundleDecodeOptions {
+  /** Most bytes the bundle may unpack to; `MAX_BUNDLE_BYTES` by default. */
+  readonly maxBytes?: number;
+}
+
+/**
+ * Why a bundle cannot be loaded as it is: an `error` stops the import,
+ * a `warning` is reported and the import goes on.
+ */
+export interface BundleProblem {
+  readonly severity: 'error' | 'warning';
+  readonly message: string;
+}
@@ -0,0 +1,128 @@
+import { describe, it, expect } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { ExtendedMetadata, GitContributor } from '../../types/entity.js';
+import { buildBusFactorReport, computeBusFactor } from '../bus-factor.js';
+
+function makeEntity(
+  name: string,
+  metadata: Partial<ExtendedMetadata> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: { type: 'service', description: `${name} service`, ...metadata },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+function git(
+  recentCommits: number,
+  ...contributors: [string, number][]
+): Partial<ExtendedMetadata> {
+  return {
+    git: {
+      last_commit: 'abc',
+      last_author: contributors[0][0],
+      last_modified: '2026-03-01T09:00:00+00:00',
+      contributors: contributors.map(([author, commits]) => ({
+        author,
+        commits,
+        last_modified: '2026-03-01T09:00:00+00:00',
+      })),
+      recent_commits: recentCommits,
+    },
+  };
+}
+
+const checkout = makeEntity('checkout', {
+  ...git(10, ['ada@example.com', 9]),
+  context: { revenue_impact: 'critical' },
+  dependencies: { services: ['ledger'] },
+});
+const ledger = makeEntity(
+  'ledger',
+  git(6, ['ada@example.com', 3], ['lin@example.com', 3]),
+);
+const cart = makeEntity('cart', {
+  ...git(4, ['lin@example.com', 4]),
+  context: { revenue_impact: 'low' },
+  dependencies: { services: ['ledger'] },
+});
+const docs = makeEntity('docs');
+const entities = [checkout, ledger, cart, docs];
+const graph = buildDependencyGraph(entities);
+
+describe('computeBusFactor', () => {
+  const contributors = (...commits: number[]): GitContributor[] =>
+    commits.map((count, i) => ({
+      author: `dev${i}`,
+      commits: count,
+      last_modified: '2026-03-01',
+    }));
+
+  it('counts the contributors needed to reach the coverage', () => {
+    expect(computeBusFactor(contributors(5, 3, 2), 10)).toBe(2);
+    expect(computeBusFactor(contributors(8, 2), 10)).toBe(1);
+    expect(computeBusFactor(contributors(5, 3, 2), 10, 0.5)).toBe(1);
+  });
+
+  it('goes one past the list when the listed commits fall short', () => {
+    expect(computeBusFactor(contributors(2, 2), 10)).toBe(3);
+  });
+});
+
+describe('buildBusFactorReport', () => {
+  it('flags critical entities maintained by one person', () => {
+    const report = buildBusFactorReport(entities, graph);
+    const rows = report.entries.map((e) => [e.name, e.busFactor, e.atRisk]);
+    expect(rows).toEqual([
+      ['checkout', 1, true],
+      ['cart', 1, false],
+      ['ledger', 2, false],
+    ]);
+    expect(report.entries[0]).toMatchObject({
+      topContributor: 'ada@example.com',
+      topShare: 0.9,
+      recentCommits: 10,
+      revenueImpact: 'critical',
+      critical: true,
+    });
+    expect(report.atRisk).toBe(1);
+    expect(report.unknown).toBe(1);
+  });
+
+  it('treats heavily depended-on entities as critical', () => {
+    const report = buildBusFactorReport(entities, graph, { minDependents: 2 });
+    const entry = report.entries.find((e) => e.name === 'ledger');
+    expect(entry).toMatchObject({ dependents: 2, critical: true });
+    expect(report.entries.map((e) => e.name)).toEqual([
+      'checkout',
+      'ledger',
+      'cart',
+    ]);
+  });
+
+  it('honours the configured critical impacts', () => {
+    const report = buildBusFactorReport(entities, graph, {
+      criticalImpacts: ['low'],
+    });
+    const atRisk = report.entries.filter((e) => e.atRisk);
+    expect(atRisk.map((e) => e.name)).toEqual(['cart']);
+  });
+});
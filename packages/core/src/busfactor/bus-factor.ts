@@ -0,0 +1,119 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Builds bus-factor reports from git contributor concentration and node criticality
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bus-factor, ownership, git, risk]
+ * context:
+ *   business_goal: Rank knowledge concentration by how much it would hurt to lose the one maintainer
+ *   domain: ownership
+ */
+import type { DependencyGraph } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  ExtendedMetadata,
+  GitContributor,
+  RevenueImpact,
+} from '../types/entity.js';
+import type {
+  BusFactorEntry,
+  BusFactorOptions,
+  BusFactorReport,
+} from './types.js';
+
+const DEFAULT_CRITICAL_IMPACTS: readonly RevenueImpact[] = ['critical', 'high'];
+
+/**
+ * The fewest contributors whose commits reach `coverage` of `total`. When the
+ * listed contributors fall short, more people share the work than were kept,
+ * so the count is one past the list.
+ */
+export function computeBusFactor(
+  contributors: readonly GitContributor[],
+  total: number,
+  coverage = 0.75,
+): number {
+  let covered = 0;
+  for (const [i, contributor] of contributors.entries()) {
+    covered += contributor.commits;
+    if (covered >= coverage * total) return i + 1;
+  }
+  return contributors.length + 1;
+}
+
+function countDependents(graph: DependencyGraph): ReadonlyMap<string, number> {
+  const counts = new Map<string, number>();
+  for (const edge of graph.edges) {
+    counts.set(edge.to, (counts.get(edge.to) ?? 0) + 1);
+  }
+  return counts;
+}
+
+/**
+ * Report how concentrated recent commits to each entity's file are, from the
+ * git enricher's `git.contributors`. An entity is critical when its revenue
+ * impact is in `criticalImpacts` or enough of the graph depends on it, and
+ * at risk when it is critical with a bus factor of one.
+ */
+export function buildBusFactorReport(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  options: BusFactorOptions = {},
+): BusFactorReport {
+  const {
+    coverage = 0.75,
+    criticalImpacts = DEFAULT_CRITICAL_IMPACTS,
+    minDependents = 5,
+  } = options;
+  const dependents = countDependents(graph);
+  const entries: BusFactorEntry[] = [];
+  let unknown = 0;
+
+  for (const entity of entities) {
+    const metadata = entity.metadata as ExtendedMetadata;
+    const contributors = metadata.git?.contributors ?? [];
+    if (contributors.length === 0) {
+      unknown += 1;
+      continue;
+    }
+    const listed = contributors.reduce((sum, c) => sum + c.commits, 0);
+    const recentCommits = Math.max(metadata.git?.recent_commits ?? 0, listed);
+    const busFactor = computeBusFactor(contributors, recentCommits, coverage);
+    const [top] = contributors;
+    const revenueImpact = metadata.context?.revenue_impact ?? null;
+    const dependentCount = dependents.get(entity.id) ?? 0;
+    const critical =
+      (revenueImpact !== null && criticalImpacts.includes(revenueImpact)) ||
+      dependentCount >= minDependents;
+    entries.push({
+      entityId: entity.id,
+      name: entity.name,
+      entityType: entity.entityType,
+      filePath: entity.filePath,
+      owner: entity.owner,
+      revenueImpact,
+      dependents: dependentCount,
+      busFactor,
+      topContributor: top.author,
+      topShare: Math.round((top.commits / recentCommits) * 100) / 100,
+      recentCommits,
+      contributors,
+      critical,
+      atRisk: critical && busFactor === 1,
+    });
+  }
+
+  entries.sort(
+    (a, b) =>
+      Number(b.atRisk) - Number(a.atRisk) ||
+      Number(b.critical) - Number(a.critical) ||
+      b.topShare - a.topShare ||
+      a.name.localeCompare(b.name),
+  );
+  return {
+    entries,
+    atRisk: entries.filter((entry) => entry.atRisk).length,
+    unknown,
+  };
+}
@@ -0,0 +1,6 @@
+export type {
+  BusFactorEntry,
+  BusFactorOptions,
+  BusFactorReport,
+} from './types.js';
+export { buildBusFactorReport, computeBusFactor } from './bus-factor.js';
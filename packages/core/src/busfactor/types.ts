@@ -0,0 +1,55 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Types for bus-factor reports combining git contributor concentration with node criticality
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [bus-factor, ownership, git, risk, types]
+ * context:
+ *   business_goal: Let bus-factor findings feed dashboards as well as the terminal
+ *   domain: ownership
+ */
+import type {
+  EntityType,
+  GitContributor,
+  RevenueImpact,
+} from '../types/entity.js';
+
+export interface BusFactorEntry {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly owner: string | null;
+  readonly revenueImpact: RevenueImpact | null;
+  /** Entities in the graph that depend on this one. */
+  readonly dependents: number;
+  /** Contributors needed to cover the `coverage` share of recent commits. */
+  readonly busFactor: number;
+  readonly topContributor: string;
+  /** Share of recent commits by the top contributor, from 0 to 1. */
+  readonly topShare: number;
+  readonly recentCommits: number;
+  readonly contributors: readonly GitContributor[];
+  /** Revenue impact or dependents put the entity above the bar. */
+  readonly critical: boolean;
+  /** Critical and effectively maintained by one person. */
+  readonly atRisk: boolean;
+}
+
+export interface BusFactorReport {
+  /** At-risk entries first, then critical ones, each by top share. */
+  readonly entries: readonly BusFactorEntry[];
+  readonly atRisk: number;
+  /** Entities without contributor data from the git enricher. */
+  readonly unknown: number;
+}
+
+export interface BusFactorOptions {
+  /** Share of recent commits the bus factor must cover. Default 0.75. */
+  readonly coverage?: number;
+  /** Revenue impacts that make an entity critical. Default critical, high. */
+  readonly criticalImpacts?: readonly RevenueImpact[];
+  /** Dependents that make an entity critical on their own. Default 5. */
+  readonly minDependents?: number;
+}
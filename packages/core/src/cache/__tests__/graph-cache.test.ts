@@ -0,0 +1,128 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdirSync, readdirSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  buildDependencyGraphCached,
+  createGraphCache,
+  graphCacheKey,
+  hashConfig,
+} from '../graph-cache.js';
+
+function makeEntity(
+  name: string,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'team-a',
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `${name} description`,
+      dependencies:
+        name === 'checkout' ? { services: ['payments'] } : undefined,
+    },
+    tags: [],
+    links: [],
+    fileHash: `hash-${name}`,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+describe('hashConfig', () => {
+  it('ignores key order', () => {
+    expect(hashConfig({ a: 1, b: [2] })).toBe(hashConfig({ b: [2], a: 1 }));
+    expect(hashConfig({ a: 1 })).not.toBe(hashConfig({ a: 2 }));
+  });
+});
+
+describe('graphCacheKey', () => {
+  const entities = [makeEntity('checkout'), makeEntity('payments')];
+
+  it('does not depend on entity order', () => {
+    expect(graphCacheKey(entities)).toBe(
+      graphCacheKey([...entities].reverse()),
+    );
+  });
+
+  it('changes with file hashes, config hash, and options', () => {
+    const key = graphCacheKey(entities);
+    expect(
+      graphCacheKey([entities[0], makeEntity('payments', { fileHash: 'x' })]),
+    ).not.toBe(key);
+    expect(graphCacheKey(entities, {}, { configHash: 'c' })).not.toBe(key);
+    expect(
+      graphCacheKey(entities, {
+        workspaces: [{ name: 'shop', path: 'src', kind: 'pnpm' }],
+      }),
+    ).not.toBe(key);
+  });
+});
+
+describe('buildDependencyGraphCached', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-cache-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('reuses a cached graph and keeps only the latest entry', () => {
+    const cache = createGraphCache(join(dir, 'cache'));
+    const entities = [makeEntity('checkout'), makeEntity('payments')];
+
+    const built = buildDependencyGraphCached(entities, {}, cache);
+    expect(built.edges).toHaveLength(1);
+    const key = graphCacheKey(entities);
+    expect(cache.get(key)).toEqual(built);
+
+    const changed = [makeEntity('checkout', { fileHash: 'new' })];
+    buildDependencyGraphCached(changed, {}, cache);
+    expect(cache.get(key)).toBeUndefined();
+    expect(readdirSync(cache.dir)).toEqual([
+      `graph-${graphCacheKey(changed)}.json`,
+    ]);
+  });
+
+  it('stores binary snapshots when configured', () => {
+    const cache = createGraphCache(join(dir, 'cache'), { format: 'binary' });
+    const entities = [makeEntity('checkout'), makeEntity('payments')];
+
+    const built = buildDependencyGraphCached(entities, {}, cache);
+    const key = graphCacheKey(entities);
+    expect(readdirSync(cache.dir)).toEqual([`graph-${key}.kgs`]);
+    expect(cache.get(key)).toEqual(built);
+  });
+
+  it('treats an unwritable cache as a miss', () => {
+    const cache = createGraphCache(join(dir, 'missing', '\0bad'));
+    const graph = buildDependencyGraphCached(
+      [makeEntity('payments')],
+      {},
+      cache,
+    );
+    expect(graph.nodes).toHaveLength(1);
+    expect(existsSync(cache.dir)).toBe(false);
+  });
+});
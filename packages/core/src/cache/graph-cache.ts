@@ -0,0 +1,148 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Content-addressed dependency graph cache keyed by entity hashes, schema version, and config hash
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [cache, graph, performance, hashing]
+ * context:
+ *   business_goal: Skip rebuilding dependency graphs when nothing that shapes them has changed
+ *   domain: cache
+ */
+import { createHash } from 'node:crypto';
+import {
+  existsSync,
+  mkdirSync,
+  readdirSync,
+  rmSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { compareStrings, stableStringify } from '../canonical/canonical.js';
+import { readStoreFile, writeStoreFile } from '../encryption/encryption.js';
+import { buildDependencyGraph } from '../graph/graph-builder.js';
+import type {
+  DependencyGraph,
+  DependencyGraphOptions,
+} from '../graph/types.js';
+import { INDEX_SCHEMA_VERSION } from '../indexer/schema.js';
+import type { StoredEntity } from '../indexer/types.js';
+import {
+  decodeGraphSnapshot,
+  encodeGraphSnapshot,
+} from '../snapshot/graph-snapshot.js';
+import type {
+  GraphCache,
+  GraphCacheKeyOptions,
+  GraphCacheOptions,
+} from './types.js';
+
+const ENTRY_PATTERN = /^graph-[0-9a-f]{64}\.(json|kgs)$/;
+
+function sha256(value: string): string {
+  return createHash('sha256').update(value).digest('hex');
+}
+
+/**
+ * Hash a configuration object. Key order does not affect the result.
+ */
+export function hashConfig(config: unknown): string {
+  return sha256(stableStringify(config, false));
+}
+
+/**
+ * Compute the cache key for a graph built from `entities` with `options`.
+ * Each entity contributes its id, file hash, and update time, so any
+ * re-indexed file produces a new key without hashing full entity bodies.
+ */
+export function graphCacheKey(
+  entities: readonly StoredEntity[],
+  options: DependencyGraphOptions = {},
+  keyOptions: GraphCacheKeyOptions = {},
+): string {
+  const fingerprints = entities
+    .map((e) => `${e.id}\0${e.fileHash ?? ''}\0${e.updatedAt}`)
+    .sort(compareStrings);
+  return sha256(
+    stableStringify(
+      {
+        schemaVersion: INDEX_SCHEMA_VERSION,
+        configHash: keyOptions.configHash ?? '',
+        entities: fingerprints,
+        options,
+      },
+      false,
+    ),
+  );
+}
+
+/**
+ * Create a graph cache stored in `dir`, as JSON files or binary snapshots
+ * (`.kgs`), encrypted when `options` holds a key. Only the most recent
+ * entry is kept. Unreadable entries count as misses and write failures are
+ * ignored, so a broken cache never fails a command.
+ */
+export function createGraphCache(
+  dir: string,
+  options: GraphCacheOptions = {},
+): GraphCache {
+  const { format = 'json' } = options;
+  const extension = format === 'binary' ? 'kgs' : 'json';
+
+  function entryPath(key: string): string {
+    return join(dir, `graph-${key}.${extension}`);
+  }
+
+  function get(key: string): DependencyGraph | undefined {
+    const path = entryPath(key);
+    if (!existsSync(path)) return undefined;
+    try {
+      const bytes = readStoreFile(path, options);
+      return format === 'binary'
+        ? decodeGraphSnapshot(bytes)
+        : (JSON.parse(bytes.toString('utf-8')) as DependencyGraph);
+    } catch {
+      return undefined;
+    }
+  }
+
+  function set(key: string, graph: DependencyGraph): void {
+    const name = `graph-${key}.${extension}`;
+    try {
+      mkdirSync(dir, { recursive: true });
+      for (const entry of readdirSync(dir)) {
+        if (entry !== name && ENTRY_PATTERN.test(entry)) {
+          rmSync(join(dir, entry), { force: true });
+        }
+      }
+      writeStoreFile(
+        entryPath(key),
+        format === 'binary'
+          ? encodeGraphSnapshot(graph)
+          : Buffer.from(stableStringify(graph, false)),
+        options,
+      );
+    } catch {
+      // Caching is best-effort
+    }
+  }
+
+  return { dir, get, set };
+}
+
+/**
+ * Build a dependency graph, reusing the cached copy when the key matches.
+ */
+export function buildDependencyGraphCached(
+  entities: readonly StoredEntity[],
+  options: DependencyGraphOptions,
+  cache: GraphCache,
+  keyOptions: GraphCacheKeyOptions = {},
+): DependencyGraph {
+  const key = graphCacheKey(entities, options, keyOptions);
+  const cached = cache.get(key);
+  if (cached) return cached;
+
+  const graph = buildDependencyGraph(entities, options);
+  cache.set(key, graph);
+  return graph;
+}
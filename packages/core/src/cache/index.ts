@@ -0,0 +1,11 @@
+export type {
+  GraphCache,
+  GraphCacheKeyOptions,
+  GraphCacheOptions,
+} from './types.js';
+export {
+  hashConfig,
+  graphCacheKey,
+  createGraphCache,
+  buildDependencyGraphCached,
+} from './graph-cache.js';
@@ -0,0 +1,31 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the content-addressed dependency graph cache
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [cache, graph, types, interface]
+ * context:
+ *   business_goal: Invalidate cached graphs whenever their format or inputs change
+ *   domain: cache
+ */
+import type { EncryptionOptions } from '../encryption/types.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type { SnapshotFormat } from '../snapshot/types.js';
+
+export interface GraphCache {
+  /** Cache directory the entries are written to. */
+  readonly dir: string;
+  get(key: string): DependencyGraph | undefined;
+  set(key: string, graph: DependencyGraph): void;
+}
+
+export interface GraphCacheKeyOptions {
+  /** Hash of the configuration that produced the entities. */
+  readonly configHash?: string;
+}
+
+export interface GraphCacheOptions extends EncryptionOptions {
+  /** Entry encoding (default: `json`). */
+  readonly format?: SnapshotFormat;
+}
@@ -0,0 +1,46 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import {
+  createCancellationCheck,
+  isCancellationError,
+} from '../cancellation.js';
+
+describe('createCancellationCheck', () => {
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  it('passes until the signal is aborted', () => {
+    const controller = new AbortController();
+    const check = createCancellationCheck('scan', {
+      signal: controller.signal,
+    });
+    expect(check).not.toThrow();
+    controller.abort();
+    expect(check).toThrow(expect.objectContaining({ name: 'AbortError' }));
+  });
+
+  it('times out once the deadline passes', () => {
+    vi.useFakeTimers();
+    const check = createCancellationCheck('export', { timeoutMs: 50 });
+    expect(check).not.toThrow();
+    vi.advanceTimersByTime(51);
+    expect(check).toThrow('export timed out after 50ms');
+  });
+
+  it('never fires without a signal or timeout', () => {
+    expect(createCancellationCheck('scan')).not.toThrow();
+  });
+});
+
+describe('isCancellationError', () => {
+  it('recognises abort and timeout errors only', () => {
+    expect(isCancellationError(new DOMException('x', 'AbortError'))).toBe(
+      true,
+    );
+    expect(isCancellationError(new DOMException('x', 'TimeoutError'))).toBe(
+      true,
+    );
+    expect(isCancellationError(new Error('disk full'))).toBe(false);
+    expect(isCancellationError('AbortError')).toBe(false);
+  });
+});
@@ -0,0 +1,53 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Cancellation checks combining an AbortSignal with a per-phase deadline
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [cancellation, timeout, abort, signal]
+ * context:
+ *   business_goal: Let callers abort runaway scans and exports cleanly instead of killing the process
+ *   domain: cancellation
+ */
+
+export interface CancellationOptions {
+  /** Aborts the operation at the next check when signalled. */
+  readonly signal?: AbortSignal;
+  /** Fails the operation once it has run longer than this. */
+  readonly timeoutMs?: number;
+}
+
+/**
+ * Create a check to call between units of work. It throws the signal's
+ * reason once aborted, or a `TimeoutError` once `timeoutMs` has elapsed
+ * since creation. The deadline is read from the clock rather than a timer,
+ * so it also fires inside synchronous loops where timers cannot run.
+ */
+export function createCancellationCheck(
+  phase: string,
+  options: CancellationOptions = {},
+): () => void {
+  const { signal, timeoutMs } = options;
+  const deadline =
+    timeoutMs === undefined ? Number.POSITIVE_INFINITY : Date.now() + timeoutMs;
+  return () => {
+    signal?.throwIfAborted();
+    if (Date.now() > deadline) {
+      throw new DOMException(
+        `${phase} timed out after ${timeoutMs}ms`,
+        'TimeoutError',
+      );
+    }
+  };
+}
+
+/**
+ * Whether an error comes from cancellation (an aborted signal or an
+ * elapsed deadline) rather than a failure of the work itself.
+ */
+export function isCancellationError(err: unknown): err is Error {
+  return (
+    err instanceof Error &&
+    (err.name === 'AbortError' || err.name === 'TimeoutError')
+  );
+}
@@ -0,0 +1,5 @@
+export type { CancellationOptions } from './cancellation.js';
+export {
+  createCancellationCheck,
+  isCancellationError,
+} from './cancellation.js';
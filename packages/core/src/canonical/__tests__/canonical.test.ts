@@ -0,0 +1,105 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  canonicalNumber,
+  compareEntities,
+  compareStrings,
+  sortGraph,
+  stableStringify,
+} from '../canonical.js';
+
+function makeEntity(filePath: string, line: number, name = 'fn'): StoredEntity {
+  return {
+    id: `${filePath}:${line}:${name}`,
+    filePath,
+    name,
+    entityType: 'function',
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line,
+    column: 0,
+    owner: null,
+    status: null,
+    metadata: { type: 'function', description: `${name} description` },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+describe('ordering', () => {
+  it('compares strings by code unit, independent of locale', () => {
+    expect(['b', 'B', 'a', 'é'].sort(compareStrings)).toEqual([
+      'B',
+      'a',
+      'b',
+      'é',
+    ]);
+  });
+
+  it('orders entities by file, then line, then name', () => {
+    const entities = [
+      makeEntity('src/b.ts', 1),
+      makeEntity('src/a.ts', 20),
+      makeEntity('src/a.ts', 3, 'zeta'),
+      makeEntity('src/a.ts', 3, 'alpha'),
+    ];
+    expect([...entities].sort(compareEntities).map((e) => e.id)).toEqual([
+      'src/a.ts:3:alpha',
+      'src/a.ts:3:zeta',
+      'src/a.ts:20:fn',
+      'src/b.ts:1:fn',
+    ]);
+  });
+
+  it('sorts graph nodes and edges', () => {
+    const node = (id: string) => ({
+      id,
+      name: id,
+      entityType: null,
+      external: false,
+      filePath: null,
+      owner: null,
+      domain: null,
+      workspace: null,
+    });
+    const graph = sortGraph({
+      nodes: [node('b'), node('a')],
+      edges: [
+        { from: 'b', to: 'a', kind: 'service' },
+        { from: 'a', to: 'b', kind: 'service' },
+      ],
+    });
+    expect(graph.nodes.map((n) => n.id)).toEqual(['a', 'b']);
+    expect(graph.edges.map((e) => e.from)).toEqual(['a', 'b']);
+  });
+});
+
+describe('stableStringify', () => {
+  it('sorts keys at every depth and drops undefined values', () => {
+    const a = stableStringify({ b: 1, a: { d: [2, 1], c: undefined } });
+    const b = stableStringify({ a: { d: [2, 1] }, b: 1 });
+    expect(a).toBe(b);
+    expect(a).toBe(JSON.stringify({ a: { d: [2, 1] }, b: 1 }, null, 2));
+  });
+
+  it('normalizes floating-point noise', () => {
+    expect(canonicalNumber(0.1 + 0.2)).toBe(0.3);
+    expect(canonicalNumber(42)).toBe(42);
+    expect(stableStringify({ x: 0.1 + 0.2 }, false)).toBe('{"x":0.3}');
+  });
+
+  it('serializes maps with sorted keys and sets as arrays', () => {
+    expect(
+      stableStringify(
+        { m: new Map([['z', 1], ['a', 2]]), s: new Set(['x']) },
+        false,
+      ),
+    ).toBe('{"m":{"a":2,"z":1},"s":["x"]}');
+  });
+});
@@ -0,0 +1,104 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Canonical ordering and serialization so identical inputs export byte-identical output
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [export, determinism, serialization, sorting]
+ * context:
+ *   business_goal: Make exports safe to commit and meaningful to diff in review
+ *   domain: canonical
+ */
+import type { DependencyGraph, GraphEdge, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+
+// Enough to absorb summation-order noise without hiding real differences
+const FLOAT_PRECISION = 12;
+
+/**
+ * Compare strings by UTF-16 code unit. Unlike `localeCompare`, the result
+ * does not depend on the ICU data or locale of the machine.
+ */
+export function compareStrings(a: string, b: string): number {
+  if (a === b) return 0;
+  return a < b ? -1 : 1;
+}
+
+/**
+ * Order entities by location (file, line, column), then name and id.
+ */
+export function compareEntities(a: StoredEntity, b: StoredEntity): number {
+  return (
+    compareStrings(a.filePath, b.filePath) ||
+    a.line - b.line ||
+    a.column - b.column ||
+    compareStrings(a.name, b.name) ||
+    compareStrings(a.id, b.id)
+  );
+}
+
+function compareNodes(a: GraphNode, b: GraphNode): number {
+  return compareStrings(a.id, b.id);
+}
+
+function compareEdges(a: GraphEdge, b: GraphEdge): number {
+  return (
+    compareStrings(a.from, b.from) ||
+    compareStrings(a.to, b.to) ||
+    compareStrings(a.kind, b.kind) ||
+    compareStrings(a.provenance, b.provenance)
+  );
+}
+
+/**
+ * A copy of the graph with nodes sorted by id and edges by endpoints,
+ * kind, and provenance.
+ */
+export function sortGraph(graph: DependencyGraph): DependencyGraph {
+  return {
+    nodes: [...graph.nodes].sort(compareNodes),
+    edges: [...graph.edges].sort(compareEdges),
+  };
+}
+
+/**
+ * Round away floating-point noise so values computed in a different order
+ * (0.1 + 0.2 vs 0.3) print the same. Integers pass through unchanged.
+ */
+export function canonicalNumber(value: number): number {
+  if (Number.isInteger(value) || !Number.isFinite(value)) return value;
+  return Number(value.toPrecision(FLOAT_PRECISION));
+}
+
+/**
+ * Deep copy with object keys sorted and floats normalized. Array order is
+ * kept: callers sort collections whose order carries no meaning.
+ */
+export function canonicalize(value: unknown): unknown {
+  if (typeof value === 'number') return canonicalNumber(value);
+  if (Array.isArray(value)) return value.map(canonicalize);
+  if (value instanceof Map) {
+    return canonicalize(Object.fromEntries(value));
+  }
+  if (value instanceof Set) return [...value].map(canonicalize);
+  if (typeof value !== 'object' || value === null) return value;
+  if ('toJSON' in value && typeof value.toJSON === 'function') {
+    return canonicalize(value.toJSON());
+  }
+
+  const result: Record<string, unknown> = {};
+  for (const key of Object.keys(value).sort(compareStrings)) {
+    const child = (value as Record<string, unknown>)[key];
+    if (child !== undefined) result[key] = canonicalize(child);
+  }
+  return result;
+}
+
+/**
+ * `JSON.stringify` over the canonical form.
+ */
+export function stableStringify(value: unknown, pretty = true): string {
+  return pretty
+    ? JSON.stringify(canonicalize(value), null, 2)
+    : JSON.stringify(canonicalize(value));
+}
@@ -0,0 +1,8 @@
+export {
+  compareStrings,
+  compareEntities,
+  sortGraph,
+  canonicalNumber,
+  canonicalize,
+  stableStringify,
+} from './canonical.js';
@@ -0,0 +1,154 @@
+import { describe, it, expect } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import { estimateChangeCost, resolveChangeSet } from '../change-cost.js';
+
+function makeEntity(
+  name: string,
+  owner: string | null,
+  metadata: Partial<ExtendedMetadata> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}/index.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner,
+    status: 'stable',
+    metadata: { type: 'service', description: `${name} service`, ...metadata },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const checkout = makeEntity('checkout', 'team-payments', {
+  context: { domain: 'payments' },
+  dependencies: { services: ['ledger'], external_apis: ['stripe'] },
+  compliance: {
+    regulations: ['PCI-DSS'],
+    audit_requirements: ['SOX'],
+    data_sensitivity: 'restricted',
+  },
+});
+const refunds = makeEntity('refunds', 'team-payments', {
+  context: { domain: 'payments' },
+  dependencies: { services: ['checkout'] },
+  compliance: { regulations: ['PCI-DSS'], data_sensitivity: 'internal' },
+});
+const ledger = makeEntity('ledger', 'team-finance', {
+  context: { domain: 'finance' },
+});
+const cart = makeEntity('cart', 'team-web', {
+  context: { domain: 'storefront' },
+  dependencies: { services: ['checkout'] },
+});
+const legacy = makeEntity('legacy', null, {
+  dependencies: { services: ['checkout'] },
+});
+const entities = [checkout, refunds, ledger, cart, legacy];
+const graph = buildDependencyGraph(entities);
+
+describe('resolveChangeSet', () => {
+  it('matches paths, directories, and symbols, and reports the rest', () => {
+    const set = resolveChangeSet(entities, [
+      './src/checkout/',
+      'src/refunds/index.ts',
+      'ledger',
+      'src/missing',
+    ]);
+    expect(set.entities.map((entity) => entity.name)).toEqual([
+      'checkout',
+      'refunds',
+      'ledger',
+    ]);
+    expect(set.unmatched).toEqual(['src/missing']);
+  });
+});
+
+describe('estimateChangeCost', () => {
+  it('counts teams, cross-domain edges, and reviews with default weights', () => {
+    const report = estimateChangeCost([checkout], graph);
+
+    expect(report.teams).toEqual([
+      { team: 'team-payments', changed: ['checkout'], dependents: ['refunds'] },
+      { team: 'team-web', changed: [], dependents: ['cart'] },
+    ]);
+    expect(report.unowned).toEqual(['legacy']);
+    expect(report.crossDomainEdges).toEqual([
+      {
+        from: 'legacy',
+        to: 'checkout',
+        fromDomain: '(no domain)',
+        toDomain: 'payments',
+      },
+      {
+        from: 'checkout',
+        to: 'ledger',
+        fromDomain: 'payments',
+        toDomain: 'finance',
+      },
+      {
+        from: 'cart',
+        to: 'checkout',
+        fromDomain: 'storefront',
+        toDomain: 'payments',
+      },
+    ]);
+    expect(report.complianceReviews).toEqual([
+      { kind: 'regulation', name: 'PCI-DSS', entities: ['checkout'] },
+      { kind: 'audit', name: 'SOX', entities: ['checkout'] },
+      { kind: 'data_sensitivity', name: 'restricted', entities: ['checkout'] },
+    ]);
+    expect(report.breakdown).toEqual({
+      teams: 6,
+      crossDomainEdges: 3,
+      complianceReviews: 15,
+    });
+    expect(report.score).toBe(24);
+  });
+
+  it('shares reviews across entities and applies custom weights', () => {
+    const report = estimateChangeCost([checkout, refunds], graph, {
+      weights: { teams: 10, complianceReviews: 1 },
+      reviewedSensitivity: ['restricted', 'internal'],
+    });
+
+    expect(report.teams.map((team) => team.team)).toEqual([
+      'team-payments',
+      'team-web',
+    ]);
+    expect(report.complianceReviews).toEqual([
+      {
+        kind: 'regulation',
+        name: 'PCI-DSS',
+        entities: ['checkout', 'refunds'],
+      },
+      { kind: 'audit', name: 'SOX', entities: ['checkout'] },
+      { kind: 'data_sensitivity', name: 'internal', entities: ['refunds'] },
+      { kind: 'data_sensitivity', name: 'restricted', entities: ['checkout'] },
+    ]);
+    expect(report.breakdown).toEqual({
+      teams: 20,
+      crossDomainEdges: 3,
+      complianceReviews: 4,
+    });
+    expect(report.score).toBe(27);
+  });
+
+  it('costs nothing for an empty change set', () => {
+    const report = estimateChangeCost([], graph);
+    expect(report.score).toBe(0);
+    expect(report.teams).toEqual([]);
+  });
+});
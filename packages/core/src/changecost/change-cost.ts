@@ -0,0 +1,221 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Scores a proposed change set by the owning teams it impacts, the domain boundaries it crosses, and the compliance reviews it triggers
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [change-cost, ownership, domain, compliance, planning]
+ * context:
+ *   business_goal: Put a number on coordination overhead so it can be planned rather than discovered
+ *   domain: ownership
+ */
+import { findSymbol } from '../explain/explain.js';
+import { UNASSIGNED_DOMAIN } from '../graph/domain-rollup.js';
+import { getEntityDomain } from '../graph/graph-builder.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { DataSensitivity, ExtendedMetadata } from '../types/entity.js';
+import type {
+  ChangeCostOptions,
+  ChangeCostReport,
+  ChangeCostWeights,
+  ChangeSet,
+  ComplianceReview,
+  ComplianceReviewKind,
+  CrossDomainEdge,
+  ImpactedTeam,
+} from './types.js';
+
+export const DEFAULT_CHANGE_COST_WEIGHTS: ChangeCostWeights = {
+  teams: 3,
+  crossDomainEdges: 1,
+  complianceReviews: 5,
+};
+
+const DEFAULT_REVIEWED_SENSITIVITY: readonly DataSensitivity[] = [
+  'confidential',
+  'restricted',
+];
+
+const REVIEW_ORDER: readonly ComplianceReviewKind[] = [
+  'regulation',
+  'audit',
+  'data_sensitivity',
+];
+
+function normalizePath(path: string): string {
+  return path
+    .replace(/\\/g, '/')
+    .replace(/^(\.\/)+/, '')
+    .replace(/\/+$/, '');
+}
+
+function inPath(entity: StoredEntity, path: string): boolean {
+  return (
+    path === '' ||
+    path === '.' ||
+    entity.filePath === path ||
+    entity.filePath.startsWith(`${path}/`)
+  );
+}
+
+/**
+ * The entities `targets` name. A target is a file or directory relative
+ * to the index root, or else any symbol `findSymbol` accepts. Targets
+ * that match nothing are returned as `unmatched` rather than dropped.
+ */
+export function resolveChangeSet(
+  entities: readonly StoredEntity[],
+  targets: readonly string[],
+): ChangeSet {
+  const selected = new Map<string, StoredEntity>();
+  const unmatched: string[] = [];
+  for (const target of targets) {
+    const path = normalizePath(target);
+    let found = entities.filter((entity) => inPath(entity, path));
+    if (found.length === 0) found = findSymbol(entities, target);
+    if (found.length === 0) unmatched.push(target);
+    for (const entity of found) selected.set(entity.id, entity);
+  }
+  return { entities: [...selected.values()], unmatched };
+}
+
+/**
+ * Estimate how much coordination `changed` needs. Impacted teams own a
+ * changed entity or a direct dependent of one. Cross-domain edges are
+ * dependencies in either direction between a changed entity and an
+ * entity in another domain; edges to external stubs don't count. Each
+ * regulation, audit requirement, and reviewed sensitivity level on a
+ * changed entity is one review, however many entities share it. The
+ * score is each count times its weight, summed.
+ */
+export function estimateChangeCost(
+  changed: readonly StoredEntity[],
+  graph: DependencyGraph,
+  options: ChangeCostOptions = {},
+): ChangeCostReport {
+  const weights = { ...DEFAULT_CHANGE_COST_WEIGHTS, ...options.weights };
+  const reviewedSensitivity =
+    options.reviewedSensitivity ?? DEFAULT_REVIEWED_SENSITIVITY;
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const changedIds = new Set(changed.map((entity) => entity.id));
+
+  const teams = new Map<
+    string,
+    { changed: Set<string>; dependents: Set<string> }
+  >();
+  const unowned = new Set<string>();
+  const impact = (
+    owner: string | null,
+    name: string,
+    role: 'changed' | 'dependents',
+  ): void => {
+    if (!owner) {
+      unowned.add(name);
+      return;
+    }
+    const team = teams.get(owner) ?? {
+      changed: new Set<string>(),
+      dependents: new Set<string>(),
+    };
+    team[role].add(name);
+    teams.set(owner, team);
+  };
+  for (const entity of changed) impact(entity.owner, entity.name, 'changed');
+
+  const crossDomainEdges: CrossDomainEdge[] = [];
+  const seen = new Set<string>();
+  for (const edge of graph.edges) {
+    const fromChanged = changedIds.has(edge.from);
+    const toChanged = changedIds.has(edge.to);
+    if (!fromChanged && !toChanged) continue;
+    const from = nodes.get(edge.from);
+    const to = nodes.get(edge.to);
+    if (!from || !to || from.external || to.external) continue;
+    if (!fromChanged) impact(from.owner, from.name, 'dependents');
+
+    const fromDomain = from.domain ?? UNASSIGNED_DOMAIN;
+    const toDomain = to.domain ?? UNASSIGNED_DOMAIN;
+    const key = `${edge.from}\u0000${edge.to}`;
+    if (fromDomain === toDomain || seen.has(key)) continue;
+    seen.add(key);
+    crossDomainEdges.push({
+      from: from.name,
+      to: to.name,
+      fromDomain,
+      toDomain,
+    });
+  }
+
+  const reviews = new Map<string, ComplianceReview>();
+  const review = (
+    kind: ComplianceReviewKind,
+    name: string,
+    entity: string,
+  ): void => {
+    const key = `${kind}\u0000${name}`;
+    const entry = reviews.get(key) ?? { kind, name, entities: [] };
+    if (!entry.entities.includes(entity)) {
+      reviews.set(key, { ...entry, entities: [...entry.entities, entity] });
+    }
+  };
+  for (const entity of changed) {
+    const compliance = (entity.metadata as ExtendedMetadata).compliance;
+    if (!compliance) continue;
+    for (const name of compliance.regulations ?? []) {
+      review('regulation', name, entity.name);
+    }
+    for (const name of compliance.audit_requirements ?? []) {
+      review('audit', name, entity.name);
+    }
+    const sensitivity = compliance.data_sensitivity;
+    if (sensitivity && reviewedSensitivity.includes(sensitivity)) {
+      review('data_sensitivity', sensitivity, entity.name);
+    }
+  }
+
+  const impacted: ImpactedTeam[] = [...teams.entries()]
+    .map(([team, names]) => ({
+      team,
+      changed: [...names.changed].sort(),
+      dependents: [...names.dependents].sort(),
+    }))
+    .sort((a, b) => a.team.localeCompare(b.team));
+  crossDomainEdges.sort(
+    (a, b) =>
+      a.fromDomain.localeCompare(b.fromDomain) ||
+      a.toDomain.localeCompare(b.toDomain) ||
+      a.from.localeCompare(b.from) ||
+      a.to.localeCompare(b.to),
+  );
+  const complianceReviews = [...reviews.values()].sort(
+    (a, b) =>
+      REVIEW_ORDER.indexOf(a.kind) - REVIEW_ORDER.indexOf(b.kind) ||
+      a.name.localeCompare(b.name),
+  );
+
+  const breakdown = {
+    teams: impacted.length * weights.teams,
+    crossDomainEdges: crossDomainEdges.length * weights.crossDomainEdges,
+    complianceReviews: complianceReviews.length * weights.complianceReviews,
+  };
+  return {
+    changed: changed.map((entity) => ({
+      id: entity.id,
+      name: entity.name,
+      filePath: entity.filePath,
+      owner: entity.owner,
+      domain: getEntityDomain(entity),
+    })),
+    teams: impacted,
+    unowned: [...unowned].sort(),
+    crossDomainEdges,
+    complianceReviews,
+    weights,
+    breakdown,
+    score:
+      breakdown.teams +
+      breakdown.crossDomainEdges +
+      breakdown.complianceReviews,
+  };
+}
@@ -0,0 +1,17 @@
+export type {
+  ChangeCostBreakdown,
+  ChangeCostOptions,
+  ChangeCostReport,
+  ChangeCostWeights,
+  ChangedEntity,
+  ChangeSet,
+  ComplianceReview,
+  ComplianceReviewKind,
+  CrossDomainEdge,
+  ImpactedTeam,
+} from './types.js';
+export {
+  DEFAULT_CHANGE_COST_WEIGHTS,
+  estimateChangeCost,
+  resolveChangeSet,
+} from './change-cost.js';
@@ -0,0 +1,88 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for estimating the coordination cost of a proposed change set from ownership, domains, and compliance metadata
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [change-cost, ownership, domain, compliance, planning, types]
+ * context:
+ *   business_goal: Let planning tools read change cost estimates without parsing terminal output
+ *   domain: ownership
+ */
+import type { StoredEntity } from '../indexer/types.js';
+import type { DataSensitivity } from '../types/entity.js';
+
+/** Points each unit of a factor adds to the score. */
+export interface ChangeCostWeights {
+  readonly teams: number;
+  readonly crossDomainEdges: number;
+  readonly complianceReviews: number;
+}
+
+export interface ChangedEntity {
+  readonly id: string;
+  readonly name: string;
+  readonly filePath: string;
+  readonly owner: string | null;
+  readonly domain: string | null;
+}
+
+/** A team that owns changed entities or direct dependents of them. */
+export interface ImpactedTeam {
+  readonly team: string;
+  /** Names of the changed entities the team owns. */
+  readonly changed: readonly string[];
+  /** Names of the team's entities that depend on a changed one. */
+  readonly dependents: readonly string[];
+}
+
+/** A dependency between a changed entity and one in another domain. */
+export interface CrossDomainEdge {
+  readonly from: string;
+  readonly to: string;
+  readonly fromDomain: string;
+  readonly toDomain: string;
+}
+
+export type ComplianceReviewKind = 'regulation' | 'audit' | 'data_sensitivity';
+
+/** A review the change set needs, and the changed entities that need it. */
+export interface ComplianceReview {
+  readonly kind: ComplianceReviewKind;
+  /** The regulation, audit requirement, or data sensitivity level. */
+  readonly name: string;
+  readonly entities: readonly string[];
+}
+
+/** Points contributed by each factor; they add up to the score. */
+export interface ChangeCostBreakdown {
+  readonly teams: number;
+  readonly crossDomainEdges: number;
+  readonly complianceReviews: number;
+}
+
+export interface ChangeCostReport {
+  readonly changed: readonly ChangedEntity[];
+  readonly teams: readonly ImpactedTeam[];
+  /** Impacted entities without an owner, which nobody can sign off on. */
+  readonly unowned: readonly string[];
+  readonly crossDomainEdges: readonly CrossDomainEdge[];
+  readonly complianceReviews: readonly ComplianceReview[];
+  /** The weights the breakdown was scored with. */
+  readonly weights: ChangeCostWeights;
+  readonly breakdown: ChangeCostBreakdown;
+  readonly score: number;
+}
+
+export interface ChangeCostOptions {
+  /** Default 3 per team, 1 per cross-domain edge, 5 per review. */
+  readonly weights?: Partial<ChangeCostWeights>;
+  /** Sensitivity levels needing a review. Default confidential, restricted. */
+  readonly reviewedSensitivity?: readonly DataSensitivity[];
+}
+
+/** The entities a change set touches, and the targets that matched none. */
+export interface ChangeSet {
+  readonly entities: readonly StoredEntity[];
+  readonly unmatched: readonly string[];
+}
@@ -0,0 +1,96 @@
+import { describe, it, expect } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { EntityType } from '../../types/entity.js';
+import { chunkGraph, estimateTokens } from '../chunks.js';
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  entityType: EntityType,
+  services: string[] = [],
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType,
+    description: `${name} does one thing well`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'shop-team',
+    status: null,
+    metadata: {
+      type: entityType,
+      description: `${name} does one thing well`,
+      owner: 'shop-team',
+      dependencies: { services, databases: ['orders-db'] },
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const entities = [
+  makeEntity('orders', 'src/orders.ts', 'service', ['payments']),
+  makeEntity('ordering', 'src/orders.ts', 'module'),
+  makeEntity('payments', 'src/payments.ts', 'service'),
+];
+const graph = buildDependencyGraph(entities);
+
+describe('chunkGraph', () => {
+  it('writes a chunk per module with its neighbors as context', () => {
+    const chunks = chunkGraph(graph, entities);
+    expect(chunks.map((chunk) => [chunk.module, chunk.nodes])).toEqual([
+      ['ordering', ['id-ordering', 'id-orders']],
+      ['src/payments.ts', ['id-payments']],
+    ]);
+    const [orders, payments] = chunks;
+    expect(orders.neighbors).toEqual([
+      'external:database:orders-db',
+      'id-payments',
+    ]);
+    expect(payments.neighbors).toContain('id-orders');
+    expect(orders.text).toContain('Depends on: orders-db (database)');
+    expect(orders.text).toContain(
+      '- payments (service) in src/payments.ts: payments does one thing well',
+    );
+    expect(payments.text).toContain('Used by: orders (service)');
+    expect(orders.tokens).toBe(estimateTokens(orders.text));
+    expect(orders.digest).toMatch(/^[0-9a-f]{64}$/);
+  });
+
+  it('splits modules over the token budget into parts', () => {
+    const chunks = chunkGraph(graph, entities, { maxTokens: 60 });
+    const parts = chunks.filter((chunk) => chunk.filePath === 'src/orders.ts');
+    const layout = parts.map((chunk) => [chunk.part, chunk.parts, chunk.nodes]);
+    expect(layout).toEqual([
+      [1, 2, ['id-ordering']],
+      [2, 2, ['id-orders']],
+    ]);
+    expect(parts[1].text).toMatch(/^# ordering \(src\/orders\.ts\), part 2/);
+    expect(parts[1].neighbors).toContain('id-payments');
+  });
+
+  it('keeps chunk ids stable and apart between namespaces', () => {
+    const ids = chunkGraph(graph, entities).map((chunk) => chunk.id);
+    const reversed = chunkGraph(graph, [...entities].reverse());
+    expect(reversed.map((chunk) => chunk.id)).toEqual(ids);
+    const namespaced = chunkGraph(graph, entities, {
+      namespace: 'acme/shop',
+    });
+    expect(namespaced[0].id).not.toBe(ids[0]);
+    expect(namespaced[0].nodes).toEqual([
+      'acme/shop:id-ordering',
+      'acme/shop:id-orders',
+    ]);
+    expect(namespaced[0].neighbors[0]).toBe('external:database:orders-db');
+  });
+});
@@ -0,0 +1,236 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Splits the graph into overlapping per-module chunks of text with their neighbors as context, token counts, and stable ids
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [chunking, llm, rag, export]
+ * context:
+ *   business_goal: Let RAG pipelines index the graph in pieces that each make sense on their own
+ *   domain: export
+ */
+import { createHash } from 'node:crypto';
+import { compareStrings } from '../canonical/canonical.js';
+import type { DependencyGraph, GraphEdge, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { namespacedId } from '../namespace/namespace.js';
+import type { GraphChunk, GraphChunkOptions } from './types.js';
+
+const DEFAULT_MAX_TOKENS = 1000;
+
+/**
+ * About how many tokens `text` is: one per four characters, as the common
+ * BPE tokenizers average over English prose and code. Budgets should leave
+ * room for the difference.
+ */
+export function estimateTokens(text: string): number {
+  return Math.ceil(text.length / 4);
+}
+
+function sha256(text: string): string {
+  return createHash('sha256').update(text).digest('hex');
+}
+
+/** Stable while the file keeps its path and the part its number. */
+function chunkId(
+  namespace: string | undefined,
+  filePath: string,
+  part: number,
+): string {
+  const key = `${namespace ?? ''}\0${filePath}\0${part}`;
+  return `chunk-${sha256(key).slice(0, 16)}`;
+}
+
+interface Member {
+  readonly node: GraphNode;
+  readonly entity?: StoredEntity;
+}
+
+interface Neighbor {
+  readonly node: GraphNode;
+  readonly kind: string;
+}
+
+function firstLine(text: string): string {
+  return text.split('\n')[0].trim();
+}
+
+/** `name (type)`, with the edge kind standing in for external nodes. */
+function label(node: GraphNode, kind?: string): string {
+  const type = node.entityType ?? kind ?? 'external';
+  return `${node.name} (${type})`;
+}
+
+function compareMembers(a: Member, b: Member): number {
+  const module = (member: Member): number =>
+    member.node.entityType === 'module' ? 0 : 1;
+  return (
+    module(a) - module(b) ||
+    (a.entity?.line ?? 0) - (b.entity?.line ?? 0) ||
+    compareStrings(a.node.name, b.node.name) ||
+    compareStrings(a.node.id, b.node.id)
+  );
+}
+
+/**
+ * Split `graph` into chunks of text for retrieval, one per file holding
+ * graph nodes, in file order. Each describes its nodes in full from
+ * `entities`, with what they depend on and what uses them, and
+ * summarizes those neighbors, so chunks overlap at their edges and each
+ * reads on its own. A file over `maxTokens` is split between its nodes
+ * into parts. Stub and external nodes are only ever neighbors.
+ *
+ * Node ids must be entity ids, so build `graph` without a namespace and
+ * pass the namespace in `options` instead; chunks list ids in it.
+ */
+export function chunkGraph(
+  graph: DependencyGraph,
+  entities: readonly StoredEntity[],
+  options: GraphChunkOptions = {},
+): readonly GraphChunk[] {
+  const { namespace } = options;
+  const maxTokens = options.maxTokens ?? DEFAULT_MAX_TOKENS;
+  const idOf = (node: GraphNode): string =>
+    namespace && !node.external ? namespacedId(namespace, node.id) : node.id;
+  const byId = new Map(entities.map((entity) => [entity.id, entity]));
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const outgoing = new Map<string, GraphEdge[]>();
+  const incoming = new Map<string, GraphEdge[]>();
+  for (const edge of graph.edges) {
+    if (edge.from === edge.to) continue;
+    outgoing.set(edge.from, [...(outgoing.get(edge.from) ?? []), edge]);
+    incoming.set(edge.to, [...(incoming.get(edge.to) ?? []), edge]);
+  }
+
+  const files = new Map<string, Member[]>();
+  for (const node of graph.nodes) {
+    if (node.external || node.stub) continue;
+    const key = node.filePath ?? node.id;
+    const entity = byId.get(node.id);
+    const members = files.get(key) ?? [];
+    members.push(entity ? { node, entity } : { node });
+    files.set(key, members);
+  }
+
+  const related = (
+    edges: readonly GraphEdge[],
+    end: 'from' | 'to',
+  ): readonly string[] => {
+    const seen = new Map<string, string>();
+    for (const edge of edges) {
+      const node = nodes.get(edge[end]);
+      if (node && !seen.has(node.id)) {
+        seen.set(node.id, label(node, edge.kind));
+      }
+    }
+    return [...seen.values()].sort(compareStrings);
+  };
+
+  const section = ({ node, entity }: Member): string => {
+    const lines = [`## ${label(node)}`];
+    if (entity?.description) lines.push(entity.description);
+    if (entity?.signature) lines.push(`Signature: ${entity.signature}`);
+    if (node.owner) lines.push(`Owner: ${node.owner}`);
+    if (entity?.status) lines.push(`Status: ${entity.status}`);
+    if (node.domain) lines.push(`Domain: ${node.domain}`);
+    if (entity?.tags.length) lines.push(`Tags: ${entity.tags.join(', ')}`);
+    const uses = related(outgoing.get(node.id) ?? [], 'to');
+    if (uses.length > 0) lines.push(`Depends on: ${uses.join(', ')}`);
+    const usedBy = related(incoming.get(node.id) ?? [], 'from');
+    if (usedBy.length > 0) lines.push(`Used by: ${usedBy.join(', ')}`);
+    return lines.join('\n');
+  };
+
+  /** Nodes joined to the group, each with the kind of an edge joining it. */
+  const neighborsOf = (group: readonly Member[]): readonly Neighbor[] => {
+    const inGroup = new Set(group.map((member) => member.node.id));
+    const found = new Map<string, Neighbor>();
+    for (const { node } of group) {
+      const ends = [
+        ...(outgoing.get(node.id) ?? []).map((edge) => [edge.to, edge.kind]),
+        ...(incoming.get(node.id) ?? []).map((edge) => [edge.from, edge.kind]),
+      ];
+      for (const [id, kind] of ends) {
+        const neighbor = nodes.get(id);
+        if (neighbor && !inGroup.has(id) && !found.has(id)) {
+          found.set(id, { node: neighbor, kind });
+        }
+      }
+    }
+    return [...found.values()].sort(
+      (a, b) =>
+        compareStrings(a.node.name, b.node.name) ||
+        compareStrings(a.node.id, b.node.id),
+    );
+  };
+
+  const summary = ({ node, kind }: Neighbor): string => {
+    const description = byId.get(node.id)?.description;
+    const where = node.external
+      ? ', external'
+      : node.filePath
+        ? ` in ${node.filePath}`
+        : '';
+    const about = description ? `: ${firstLine(description)}` : '';
+    return `- ${label(node, kind)}${where}${about}`;
+  };
+
+  const render = (
+    module: string,
+    filePath: string,
+    group: readonly Member[],
+    part: string,
+  ): string => {
+    const where = module === filePath ? '' : ` (${filePath})`;
+    const header = `# ${module}${where}${part}`;
+    const context = neighborsOf(group).map(summary);
+    return [
+      header,
+      ...group.map(section),
+      ...(context.length > 0 ? [['## Related', ...context].join('\n')] : []),
+    ].join('\n\n');
+  };
+
+  const chunks: GraphChunk[] = [];
+  const keys = [...files.keys()].sort(compareStrings);
+  for (const filePath of keys) {
+    const members = [...(files.get(filePath) ?? [])].sort(compareMembers);
+    const module =
+      members[0].node.entityType === 'module'
+        ? members[0].node.name
+        : filePath;
+    const groups: Member[][] = [];
+    let current: Member[] = [];
+    for (const member of members) {
+      const candidate = [...current, member];
+      const text = render(module, filePath, candidate, '');
+      if (current.length > 0 && estimateTokens(text) > maxTokens) {
+        groups.push(current);
+        current = [member];
+      } else {
+        current = candidate;
+      }
+    }
+    groups.push(current);
+
+    groups.forEach((group, index) => {
+      const part = index + 1;
+      const parts = groups.length;
+      const suffix = parts > 1 ? `, part ${part} of ${parts}` : '';
+      const text = render(module, filePath, group, suffix);
+      chunks.push({
+        id: chunkId(namespace, filePath, part),
+        module,
+        filePath,
+        part,
+        parts,
+        nodes: group.map((member) => idOf(member.node)),
+        neighbors: neighborsOf(group).map(({ node }) => idOf(node)),
+        tokens: estimateTokens(text),
+        digest: sha256(text),
+        text,
+      });
+    });
+  }
+  return chunks;
+}
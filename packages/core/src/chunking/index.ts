@@ -0,0 +1,2 @@
+export type { GraphChunk, GraphChunkOptions } from './types.js';
+export { chunkGraph, estimateTokens } from './chunks.js';
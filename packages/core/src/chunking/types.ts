@@ -0,0 +1,48 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for graph chunks, the overlapping per-module slices of the graph written for LLM retrieval pipelines
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [chunking, llm, rag, export, types, interface]
+ * context:
+ *   business_goal: Keep chunk ids stable so retrieval indexes update instead of growing
+ *   domain: export
+ */
+
+export interface GraphChunkOptions {
+  /**
+   * Tokens a chunk may hold before its module is split into parts
+   * (default: 1000). A single entity over the budget still gets a part.
+   */
+  readonly maxTokens?: number;
+  /**
+   * The `org/repo` namespace node ids are listed in, as graph exports
+   * write them; chunk ids then differ between repositories too.
+   */
+  readonly namespace?: string;
+}
+
+/** One module, or one part of a large module, with its neighbors. */
+export interface GraphChunk {
+  /** Stable across exports: derived from the namespace, file, and part. */
+  readonly id: string;
+  /** The name of the file's module entity, or the file path. */
+  readonly module: string;
+  readonly filePath: string;
+  /** Which part of the module this is, from 1, and how many there are. */
+  readonly part: number;
+  readonly parts: number;
+  /** Ids of the nodes the chunk describes. */
+  readonly nodes: readonly string[];
+  /**
+   * Ids of the nodes those depend on or are used by, summarized as
+   * context; they are described in full in their own chunks.
+   */
+  readonly neighbors: readonly string[];
+  /** Estimated tokens in `text` (see `estimateTokens`). */
+  readonly tokens: number;
+  /** SHA-256 of `text`, to re-embed only the chunks that changed. */
+  readonly digest: string;
+  readonly text: string;
+}
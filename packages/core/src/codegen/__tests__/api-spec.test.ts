@@ -0,0 +1,158 @@
+import { describe, it, expect } from 'vitest';
+import {
+  entityApiSpecs,
+  parseOpenApiSpec,
+  parseProtoSpec,
+} from '../api-spec.js';
+import { goIdentifier } from '../go-names.js';
+
+const OPENAPI = `
+openapi: 3.0.3
+info: { title: Tokens, version: 2.4.0 }
+paths:
+  /tokens:
+    post:
+      operationId: issueToken
+      summary: Issue a token
+      requestBody:
+        content:
+          application/json:
+            schema: { $ref: '#/components/schemas/TokenRequest' }
+      responses:
+        '201':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  token: { type: string }
+                  expires_in: { type: integer }
+  /tokens/{id}:
+    parameters:
+      - { name: id, in: path, schema: { type: string } }
+      - { name: X-Trace, in: header, schema: { type: string } }
+    delete:
+      responses:
+        '204': { description: Revoked }
+components:
+  schemas:
+    TokenRequest:
+      type: object
+      properties:
+        subject: { type: string }
+        scopes: { type: array, items: { type: string } }
+        claims: { $ref: '#/components/schemas/Claims' }
+    Claims:
+      type: object
+`;
+
+const PROTO = `
+syntax = "proto3";
+package tokens.v1;
+
+// Issues and revokes tokens
+service TokenService {
+  rpc IssueToken(IssueTokenRequest) returns (IssueTokenResponse);
+  rpc WatchRevocations(google.protobuf.Empty) returns (stream Revocation);
+}
+
+message IssueTokenRequest {
+  string subject = 1;
+  repeated string scopes = 2;
+  map<string, string> claims = 3;
+  message Audience { string name = 1; }
+  oneof lifetime {
+    int64 ttl_seconds = 4;
+    bool session = 5;
+  }
+}
+
+message IssueTokenResponse { bytes token = 1; /* signed */ double score = 2; }
+message Revocation { tokens.v1.IssueTokenResponse token = 1; }
+`;
+
+describe('parseOpenApiSpec', () => {
+  it('reads operations with their request and response fields', () => {
+    const [issue, revoke] = parseOpenApiSpec(OPENAPI, 'api/tokens.yaml');
+    expect(issue).toEqual({
+      name: 'IssueToken',
+      route: 'POST /tokens',
+      summary: 'Issue a token',
+      request: [
+        { name: 'subject', type: 'string', repeated: false },
+        { name: 'scopes', type: 'string', repeated: true },
+        { name: 'claims', type: 'object', repeated: false },
+      ],
+      response: [
+        { name: 'token', type: 'string', repeated: false },
+        { name: 'expires_in', type: 'integer', repeated: false },
+      ],
+    });
+    expect(revoke).toEqual({
+      name: 'DeleteTokensId',
+      route: 'DELETE /tokens/{id}',
+      summary: null,
+      request: [{ name: 'id', type: 'string', repeated: false }],
+      response: [],
+    });
+  });
+
+  it('rejects a document without paths', () => {
+    expect(() => parseOpenApiSpec('openapi: 3.0.0\n', 'api.yaml')).toThrow(
+      'api.yaml is not an OpenAPI document',
+    );
+  });
+});
+
+describe('parseProtoSpec', () => {
+  it('reads rpcs with the fields of their messages', () => {
+    const [issue, watch] = parseProtoSpec(PROTO);
+    expect(issue.route).toBe('rpc IssueToken');
+    expect(issue.request).toEqual([
+      { name: 'subject', type: 'string', repeated: false },
+      { name: 'scopes', type: 'string', repeated: true },
+      { name: 'claims', type: 'object', repeated: false },
+      { name: 'ttl_seconds', type: 'integer', repeated: false },
+      { name: 'session', type: 'boolean', repeated: false },
+    ]);
+    expect(issue.response).toEqual([
+      { name: 'token', type: 'bytes', repeated: false },
+      { name: 'score', type: 'number', repeated: false },
+    ]);
+    expect(watch).toMatchObject({
+      name: 'WatchRevocations',
+      request: [],
+      response: [{ name: 'token', type: 'object', repeated: false }],
+    });
+    expect(watch.summary).toContain('Streams');
+  });
+});
+
+describe('entityApiSpecs', () => {
+  it('resolves specs relative to the annotated file', () => {
+    expect(
+      entityApiSpecs({
+        filePath: 'services/tokens/main.go',
+        metadata: {
+          type: 'service',
+          description: 'Tokens',
+          api_specs: ['../../api/tokens.yaml', 'tokens.proto'],
+        },
+      }),
+    ).toEqual(['api/tokens.yaml', 'services/tokens/tokens.proto']);
+  });
+});
+
+describe('goIdentifier', () => {
+  it('turns names into exported Go identifiers', () => {
+    for (const [name, identifier] of [
+      ['token-service', 'TokenService'],
+      ['issueToken', 'IssueToken'],
+      ['HTTPGateway', 'HttpGateway'],
+      ['expires_in', 'ExpiresIn'],
+      ['2fa', 'X2fa'],
+    ] as const) {
+      expect(goIdentifier(name)).toBe(identifier);
+    }
+  });
+});
@@ -0,0 +1,191 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { generateDependencyStubs, renderGoStubs } from '../go-stubs.js';
+import { externalNode } from '../../graph/graph-builder.js';
+import type {
+  DependencyGraph,
+  GraphEdge,
+  GraphNode,
+} from '../../graph/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+
+function node(id: string): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `${id}/main.go`,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+function entity(id: string, metadata: Partial<ExtendedMetadata> = {}) {
+  return {
+    id,
+    filePath: `${id}/main.go`,
+    metadata: { type: 'service', description: id, ...metadata },
+  } as StoredEntity;
+}
+
+function edge(from: string, to: string): GraphEdge {
+  return { from, to, kind: 'service', provenance: 'declared', confidence: 1 };
+}
+
+const TOKENS = `
+openapi: 3.0.3
+paths:
+  /tokens:
+    post:
+      operationId: issueToken
+      summary: Issue a token
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                subject: { type: string }
+                scopes: { type: array, items: { type: string } }
+      responses:
+        '201':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  token: { type: string }
+`;
+
+describe('renderGoStubs', () => {
+  it('writes an interface with message types and a stub', () => {
+    const [client, stub] = renderGoStubs(
+      node('token-service'),
+      ['api/tokens.proto'],
+      [
+        {
+          name: 'IssueToken',
+          route: 'rpc IssueToken',
+          summary: null,
+          request: [
+            { name: 'subject', type: 'string', repeated: false },
+            { name: 'scopes', type: 'string', repeated: true },
+          ],
+          response: [],
+        },
+      ],
+      'deps',
+    );
+    expect(client.fileName).toBe('token_service.go');
+    expect(client.content).toBe(
+      [
+        '// Code generated by knowgraph codegen from api/tokens.proto. DO NOT EDIT.',
+        '',
+        'package deps',
+        '',
+        'import "context"',
+        '',
+        '// TokenServiceClient is the API of token-service, from api/tokens.proto.',
+        'type TokenServiceClient interface {',
+        '\t// IssueToken calls rpc IssueToken',
+        '\tIssueToken(ctx context.Context, req *TokenServiceIssueTokenRequest) (*TokenServiceIssueTokenResponse, error)',
+        '}',
+        '',
+        '// TokenServiceIssueTokenRequest is the request of IssueToken.',
+        'type TokenServiceIssueTokenRequest struct {',
+        '\tSubject string   `json:"subject,omitempty"`',
+        '\tScopes  []string `json:"scopes,omitempty"`',
+        '}',
+        '',
+        '// TokenServiceIssueTokenResponse is the response of IssueToken.',
+        'type TokenServiceIssueTokenResponse struct{}',
+        '',
+      ].join('\n'),
+    );
+    expect(stub.fileName).toBe('token_service_stub.go');
+    expect(stub.content).toContain(
+      '\tIssueTokenFunc func(ctx context.Context, req *TokenServiceIssueTokenRequest) (*TokenServiceIssueTokenResponse, error)',
+    );
+    expect(stub.content).toContain(
+      'var _ TokenServiceClient = (*StubTokenServiceClient)(nil)',
+    );
+    expect(stub.content).toContain(
+      '\treturn &TokenServiceIssueTokenResponse{}, nil',
+    );
+  });
+});
+
+describe('generateDependencyStubs', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-codegen-'));
+    mkdirSync(join(dir, 'api'));
+    writeFileSync(join(dir, 'api', 'tokens.yaml'), TOKENS);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('generates stubs for providers with specs and skips the rest', () => {
+    const stripe = externalNode('service', 'stripe');
+    const graph: DependencyGraph = {
+      nodes: [
+        node('checkout'),
+        node('token-service'),
+        node('ledger'),
+        node('audit'),
+        stripe,
+      ],
+      edges: [
+        edge('checkout', 'token-service'),
+        edge('checkout', 'ledger'),
+        edge('checkout', 'audit'),
+        edge('checkout', stripe.id),
+        edge('ledger', 'token-service'),
+      ],
+    };
+    const entities = [
+      entity('checkout'),
+      entity('token-service', { api_specs: ['../api/tokens.yaml'] }),
+      entity('ledger'),
+      entity('audit', { api_specs: ['missing.proto'] }),
+    ];
+
+    const result = generateDependencyStubs(entities, graph, 'checkout', {
+      rootDir: dir,
+      packageName: 'deps',
+    });
+    expect(result.stubs).toHaveLength(1);
+    expect(result.stubs[0]).toMatchObject({
+      interfaceName: 'TokenServiceClient',
+      specs: ['api/tokens.yaml'],
+      operations: 1,
+    });
+    expect(result.stubs[0].files[0].content).toContain(
+      '\t// IssueToken calls POST /tokens: Issue a token',
+    );
+    expect(
+      result.skipped.map((skip) => [skip.provider.name, skip.reason]),
+    ).toEqual([
+      ['audit', 'API spec audit/missing.proto not found'],
+      ['ledger', 'links no api_specs'],
+      ['stripe', 'not indexed here, so no api_specs to read'],
+    ]);
+  });
+
+  it('rejects a consumer that is not in the graph', () => {
+    expect(() =>
+      generateDependencyStubs([], { nodes: [], edges: [] }, 'web', {
+        rootDir: dir,
+        packageName: 'deps',
+      }),
+    ).toThrow('No node web in the graph');
+  });
+});
@@ -0,0 +1,324 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads the operations and message fields of OpenAPI and protobuf specs an entity links in api_specs
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [codegen, openapi, protobuf, specs, parsing]
+ * context:
+ *   business_goal: Read the API contracts teams already publish instead of asking them to restate them
+ *   domain: graph
+ */
+import { readFileSync } from 'node:fs';
+import { join, posix } from 'node:path';
+import { parse as parseYaml } from 'yaml';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { toPosixPath } from '../paths/paths.js';
+import type { CoreMetadata, ExtendedMetadata } from '../types/entity.js';
+import { goIdentifier } from './go-names.js';
+import type {
+  ApiField,
+  ApiFieldType,
+  ApiOperation,
+  ApiSpec,
+  ApiSpecFormat,
+} from './types.js';
+
+type Json = Record<string, unknown>;
+
+const HTTP_METHODS = ['get', 'put', 'post', 'delete', 'patch'] as const;
+
+function isObject(value: unknown): value is Json {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+function text(value: unknown): string | null {
+  return typeof value === 'string' && value.trim() !== ''
+    ? value.trim()
+    : null;
+}
+
+/** The `api_specs` an annotation lists, as written. */
+export function declaredApiSpecs(
+  metadata: CoreMetadata | ExtendedMetadata,
+): readonly string[] {
+  return 'api_specs' in metadata ? (metadata.api_specs ?? []) : [];
+}
+
+/**
+ * The specs `entity` links, relative to the indexed root rather than to
+ * the entity's file as they are written.
+ */
+export function entityApiSpecs(
+  entity: Pick<StoredEntity, 'filePath' | 'metadata'>,
+): readonly string[] {
+  const dir = posix.dirname(toPosixPath(entity.filePath));
+  return declaredApiSpecs(entity.metadata).map((path) =>
+    posix.normalize(posix.join(dir, toPosixPath(path))),
+  );
+}
+
+/** `.proto` files are protobuf; anything else is read as OpenAPI. */
+export function apiSpecFormat(path: string): ApiSpecFormat {
+  return posix.extname(path).toLowerCase() === '.proto' ? 'proto' : 'openapi';
+}
+
+// --- OpenAPI ---
+
+/** `#/components/schemas/Token`, or Swagger 2's `#/definitions/Token`. */
+function resolveRef(doc: Json, schema: unknown): Json | undefined {
+  if (!isObject(schema)) return undefined;
+  const ref = text(schema.$ref);
+  if (!ref) return schema;
+  let target: unknown = doc;
+  for (const part of ref.replace(/^#\//, '').split('/')) {
+    target = isObject(target) ? target[part] : undefined;
+  }
+  return isObject(target) ? target : undefined;
+}
+
+function schemaField(doc: Json, name: string, schema: unknown): ApiField {
+  const resolved = resolveRef(doc, schema);
+  if (resolved?.type === 'array') {
+    const item = schemaField(doc, name, resolved.items);
+    return { name, type: item.type, repeated: true };
+  }
+  const type: ApiFieldType =
+    resolved?.format === 'byte' || resolved?.format === 'binary'
+      ? 'bytes'
+      : resolved?.type === 'string' ||
+          resolved?.type === 'integer' ||
+          resolved?.type === 'number' ||
+          resolved?.type === 'boolean'
+        ? resolved.type
+        : 'object';
+  return { name, type, repeated: false };
+}
+
+/** The top-level properties of a body schema; nested objects stay opaque. */
+function schemaFields(doc: Json, schema: unknown): ApiField[] {
+  const resolved = resolveRef(doc, schema);
+  if (!resolved) return [];
+  if (resolved.type === 'array') return [schemaField(doc, 'items', resolved)];
+  const properties = isObject(resolved.properties) ? resolved.properties : {};
+  return Object.entries(properties).map(([name, property]) =>
+    schemaField(doc, name, property),
+  );
+}
+
+/** The schema of a JSON body, preferring `application/json`. */
+function bodySchema(doc: Json, body: unknown): unknown {
+  const resolved = resolveRef(doc, body);
+  if (!resolved) return undefined;
+  // Swagger 2 puts the schema on the body parameter or response itself
+  if ('schema' in resolved) return resolved.schema;
+  const content = isObject(resolved.content) ? resolved.content : {};
+  const media = content['application/json'] ?? Object.values(content)[0];
+  return isObject(media) ? media.schema : undefined;
+}
+
+function operationName(method: string, path: string): string {
+  const words = path
+    .split('/')
+    .filter(Boolean)
+    .map((part) => part.replace(/[{}]/g, ''));
+  return goIdentifier([method, ...words].join(' '));
+}
+
+/**
+ * The operations of an OpenAPI 3 or Swagger 2 document, in path order:
+ * parameters and body properties form the request, and the first 2xx
+ * response's properties the response. Throws a `schema` error when
+ * `content` is not a document with `paths`.
+ */
+export function parseOpenApiSpec(
+  content: string,
+  path: string,
+): readonly ApiOperation[] {
+  let doc: unknown;
+  try {
+    doc = parseYaml(content);
+  } catch (err) {
+    throw createKnowgraphError(
+      'schema',
+      `${path} is not YAML or JSON: ${(err as Error).message}`,
+    );
+  }
+  if (!isObject(doc) || !isObject(doc.paths)) {
+    throw createKnowgraphError(
+      'schema',
+      `${path} is not an OpenAPI document: it has no "paths"`,
+    );
+  }
+  const operations: ApiOperation[] = [];
+  for (const [route, item] of Object.entries(doc.paths)) {
+    if (!isObject(item)) continue;
+    const shared = Array.isArray(item.parameters) ? item.parameters : [];
+    for (const method of HTTP_METHODS) {
+      const op = item[method];
+      if (!isObject(op)) continue;
+      const own = Array.isArray(op.parameters) ? op.parameters : [];
+      const request: ApiField[] = [];
+      for (const parameter of [...shared, ...own]) {
+        const resolved = resolveRef(doc, parameter);
+        const name = text(resolved?.name);
+        if (!resolved || !name) continue;
+        if (resolved.in === 'body') {
+          request.push(...schemaFields(doc, resolved.schema));
+        } else if (resolved.in !== 'header' && resolved.in !== 'cookie') {
+          request.push(schemaField(doc, name, resolved.schema ?? resolved));
+        }
+      }
+      request.push(...schemaFields(doc, bodySchema(doc, op.requestBody)));
+      const responses = isObject(op.responses) ? op.responses : {};
+      const success = Object.keys(responses)
+        .filter((status) => /^2\d\d$/.test(status))
+        .sort()[0];
+      operations.push({
+        name: goIdentifier(
+          text(op.operationId) ?? operationName(method, route),
+        ),
+        route: `${method.toUpperCase()} ${route}`,
+        summary: text(op.summary) ?? text(op.description),
+        request,
+        response: success
+          ? schemaFields(doc, bodySchema(doc, responses[success]))
+          : [],
+      });
+    }
+  }
+  return operations;
+}
+
+// --- Protobuf ---
+
+const PROTO_TYPES: Readonly<Record<string, ApiFieldType>> = {
+  string: 'string',
+  bytes: 'bytes',
+  bool: 'boolean',
+  double: 'number',
+  float: 'number',
+  int32: 'integer',
+  int64: 'integer',
+  uint32: 'integer',
+  uint64: 'integer',
+  sint32: 'integer',
+  sint64: 'integer',
+  fixed32: 'integer',
+  fixed64: 'integer',
+  sfixed32: 'integer',
+  sfixed64: 'integer',
+};
+
+/** The index just past the `}` closing the block opened before `start`. */
+function blockEnd(source: string, start: number): number {
+  let depth = 1;
+  let end = start;
+  while (end < source.length && depth > 0) {
+    if (source[end] === '{') depth++;
+    if (source[end] === '}') depth--;
+    end++;
+  }
+  return end;
+}
+
+/** The body of each `keyword Name { ... }` block, nested braces included. */
+function protoBlocks(source: string, keyword: string): Map<string, string> {
+  const blocks = new Map<string, string>();
+  const pattern = new RegExp(`\\b${keyword}\\s+(\\w+)\\s*\\{`, 'g');
+  for (const match of source.matchAll(pattern)) {
+    const start = (match.index ?? 0) + match[0].length;
+    const body = source.slice(start, blockEnd(source, start) - 1);
+    if (!blocks.has(match[1])) blocks.set(match[1], body);
+  }
+  return blocks;
+}
+
+/**
+ * A message body without its nested messages and enums, which declare
+ * their own fields, and with the fields of its `oneof`s inlined.
+ */
+function ownFields(body: string): string {
+  const nested = /\b(message|enum|oneof)\s+\w+\s*\{/.exec(body);
+  if (!nested) return body;
+  const start = nested.index + nested[0].length;
+  const end = blockEnd(body, start);
+  const kept = nested[1] === 'oneof' ? body.slice(start, end - 1) : '';
+  return ownFields(body.slice(0, nested.index) + kept + body.slice(end));
+}
+
+const PROTO_FIELD_PATTERN =
+  /(?:^|[;{\s])(repeated\s+|optional\s+)?(map\s*<[^>]+>|[\w.]+)\s+(\w+)\s*=\s*\d+/g;
+
+const RPC_PATTERN =
+  /\brpc\s+(\w+)\s*\(\s*(stream\s+)?([\w.]+)\s*\)\s*returns\s*\(\s*(stream\s+)?([\w.]+)\s*\)/g;
+
+function protoFields(body: string | undefined): ApiField[] {
+  if (!body) return [];
+  const fields: ApiField[] = [];
+  for (const [, label, type, name] of ownFields(body).matchAll(
+    PROTO_FIELD_PATTERN,
+  )) {
+    fields.push({
+      name,
+      type: PROTO_TYPES[type] ?? 'object',
+      repeated: label?.trim() === 'repeated',
+    });
+  }
+  return fields;
+}
+
+/**
+ * The rpcs of every `service` in a protobuf file, with the fields of
+ * their request and response messages. Messages from other files, such as
+ * `google.protobuf.Empty`, have no fields here.
+ */
+export function parseProtoSpec(content: string): readonly ApiOperation[] {
+  const source = content
+    .replace(/\/\*[\s\S]*?\*\//g, '')
+    .replace(/\/\/.*$/gm, '');
+  const messages = protoBlocks(source, 'message');
+  const message = (type: string) =>
+    protoFields(messages.get(type.split('.').pop() ?? type));
+  const operations: ApiOperation[] = [];
+  for (const body of protoBlocks(source, 'service').values()) {
+    for (const [, name, inStream, input, outStream, output] of body.matchAll(
+      RPC_PATTERN,
+    )) {
+      operations.push({
+        name: goIdentifier(name),
+        route: `rpc ${name}`,
+        summary:
+          inStream || outStream
+            ? 'Streams; the stub sends and receives one message'
+            : null,
+        request: message(input),
+        response: message(output),
+      });
+    }
+  }
+  return operations;
+}
+
+/**
+ * Read the spec at `path`, relative to `rootDir`. Throws an `io` error
+ * when it is missing and a `schema` error when it cannot be read.
+ */
+export function readApiSpec(rootDir: string, path: string): ApiSpec {
+  let content: string;
+  try {
+    content = readFileSync(join(rootDir, path), 'utf-8');
+  } catch {
+    throw createKnowgraphError('io', `API spec ${path} not found`);
+  }
+  const format = apiSpecFormat(path);
+  return {
+    path,
+    format,
+    operations:
+      format === 'proto'
+        ? parseProtoSpec(content)
+        : parseOpenApiSpec(content, path),
+  };
+}
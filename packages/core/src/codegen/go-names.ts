@@ -0,0 +1,40 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Turns entity, operation, and field names into Go identifiers and file names
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [codegen, go, naming]
+ * context:
+ *   business_goal: Give generated Go code names a reviewer would have picked
+ *   domain: graph
+ */
+
+/**
+ * The words of `name`: `LoginService`, `login-service`, and `HTTPService`
+ * give `login`, `service` and `http`, `service`.
+ */
+export function nameWords(name: string): readonly string[] {
+  const found = name
+    .replace(/([a-z\d])([A-Z])/g, '$1 $2')
+    .replace(/([A-Z]+)([A-Z][a-z])/g, '$1 $2')
+    .toLowerCase()
+    .match(/[a-z\d]+/g);
+  return found ?? ['unnamed'];
+}
+
+/**
+ * An exported Go identifier for `name`: `token-service` gives
+ * `TokenService`. A leading digit gets an `X` in front.
+ */
+export function goIdentifier(name: string): string {
+  const identifier = nameWords(name)
+    .map((word) => word[0].toUpperCase() + word.slice(1))
+    .join('');
+  return /^\d/.test(identifier) ? `X${identifier}` : identifier;
+}
+
+/** A Go file name for `name`: `token-service` gives `token_service`. */
+export function goFileName(name: string): string {
+  return nameWords(name).join('_');
+}
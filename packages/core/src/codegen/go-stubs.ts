@@ -0,0 +1,292 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Generates a Go client interface, message types, and a test stub for each declared service dependency of a module from its provider's API specs
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [codegen, go, stubs, testing, dependencies]
+ * context:
+ *   business_goal: Give Go teams client interfaces that match the provider's spec so tests mock the real surface
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DependencyGraph, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { entityApiSpecs, readApiSpec } from './api-spec.js';
+import { goFileName, goIdentifier } from './go-names.js';
+import type {
+  ApiField,
+  ApiFieldType,
+  ApiOperation,
+  DependencyStub,
+  GeneratedFile,
+  SkippedDependency,
+  StubGeneration,
+  StubOptions,
+} from './types.js';
+
+const GO_TYPES: Readonly<Record<ApiFieldType, string>> = {
+  string: 'string',
+  integer: 'int64',
+  number: 'float64',
+  boolean: 'bool',
+  bytes: '[]byte',
+  object: 'any',
+};
+
+interface Method {
+  readonly operation: ApiOperation;
+  readonly request: string;
+  readonly response: string;
+  readonly signature: string;
+}
+
+function header(specs: readonly string[], packageName: string): string[] {
+  return [
+    `// Code generated by knowgraph codegen from ${specs.join(', ')}. DO NOT EDIT.`,
+    '',
+    `package ${packageName}`,
+    '',
+    'import "context"',
+    '',
+  ];
+}
+
+/** Lines aligned in columns, as gofmt aligns struct fields. */
+function alignColumns(rows: readonly (readonly string[])[]): string[] {
+  const widths: number[] = [];
+  for (const row of rows) {
+    row.forEach((cell, i) => {
+      if (i < row.length - 1) {
+        widths[i] = Math.max(widths[i] ?? 0, cell.length);
+      }
+    });
+  }
+  return rows.map((row) =>
+    row
+      .map((cell, i) => (i < row.length - 1 ? cell.padEnd(widths[i]) : cell))
+      .join(' '),
+  );
+}
+
+function structType(
+  name: string,
+  comment: string,
+  fields: readonly ApiField[],
+): string[] {
+  const lines = [`// ${name} ${comment}`];
+  if (fields.length === 0) return [...lines, `type ${name} struct{}`, ''];
+  const used = new Set<string>();
+  const rows = fields.map((field) => {
+    let fieldName = goIdentifier(field.name);
+    for (let n = 2; used.has(fieldName); n++) {
+      fieldName = `${goIdentifier(field.name)}${n}`;
+    }
+    used.add(fieldName);
+    const type = (field.repeated ? '[]' : '') + GO_TYPES[field.type];
+    return [`\t${fieldName}`, type, `\`json:"${field.name},omitempty"\``];
+  });
+  return [...lines, `type ${name} struct {`, ...alignColumns(rows), '}', ''];
+}
+
+function interfaceFile(
+  provider: GraphNode,
+  interfaceName: string,
+  methods: readonly Method[],
+  specs: readonly string[],
+  packageName: string,
+): string {
+  const lines = [
+    ...header(specs, packageName),
+    `// ${interfaceName} is the API of ${provider.name}, from ${specs.join(', ')}.`,
+    `type ${interfaceName} interface {`,
+  ];
+  methods.forEach(({ operation, signature }, i) => {
+    if (i > 0) lines.push('');
+    const summary = operation.summary ? `: ${operation.summary}` : '';
+    lines.push(`\t// ${operation.name} calls ${operation.route}${summary}`);
+    lines.push(`\t${signature}`);
+  });
+  lines.push('}', '');
+  for (const { operation, request, response } of methods) {
+    lines.push(
+      ...structType(
+        request,
+        `is the request of ${operation.name}.`,
+        operation.request,
+      ),
+      ...structType(
+        response,
+        `is the response of ${operation.name}.`,
+        operation.response,
+      ),
+    );
+  }
+  return lines.join('\n').replace(/\n+$/, '\n');
+}
+
+function stubFile(
+  interfaceName: string,
+  methods: readonly Method[],
+  specs: readonly string[],
+  packageName: string,
+): string {
+  const stub = `Stub${interfaceName}`;
+  const rows = methods.map(({ operation, signature }) => [
+    `\t${operation.name}Func`,
+    `func${signature.slice(operation.name.length)}`,
+  ]);
+  const lines = [
+    ...header(specs, packageName),
+    `// ${stub} is a ${interfaceName} for tests. Each method calls its`,
+    '// Func field when it is set, and otherwise returns an empty response.',
+    `type ${stub} struct {`,
+    ...alignColumns(rows),
+    '}',
+    '',
+    `var _ ${interfaceName} = (*${stub})(nil)`,
+  ];
+  for (const { operation, response, signature } of methods) {
+    const func = `${operation.name}Func`;
+    lines.push(
+      '',
+      `// ${operation.name} calls ${func}, or returns an empty response.`,
+      `func (s *${stub}) ${signature} {`,
+      `\tif s.${func} != nil {`,
+      `\t\treturn s.${func}(ctx, req)`,
+      '\t}',
+      `\treturn &${response}{}, nil`,
+      '}',
+    );
+  }
+  return [...lines, ''].join('\n');
+}
+
+/**
+ * The Go interface of `provider`'s API, with a request and a response
+ * type per operation, and a stub that implements it for tests, in two
+ * files named after the provider. Operations with the same name in
+ * several specs are generated once, from the first.
+ */
+export function renderGoStubs(
+  provider: GraphNode,
+  specs: readonly string[],
+  operations: readonly ApiOperation[],
+  packageName: string,
+): readonly GeneratedFile[] {
+  const prefix = goIdentifier(provider.name);
+  const interfaceName = `${prefix}Client`;
+  const seen = new Set<string>();
+  const methods: Method[] = [];
+  for (const operation of operations) {
+    if (seen.has(operation.name)) continue;
+    seen.add(operation.name);
+    const request = `${prefix}${operation.name}Request`;
+    const response = `${prefix}${operation.name}Response`;
+    methods.push({
+      operation,
+      request,
+      response,
+      signature: `${operation.name}(ctx context.Context, req *${request}) (*${response}, error)`,
+    });
+  }
+  const file = goFileName(provider.name);
+  return [
+    {
+      fileName: `${file}.go`,
+      content: interfaceFile(
+        provider,
+        interfaceName,
+        methods,
+        specs,
+        packageName,
+      ),
+    },
+    {
+      fileName: `${file}_stub.go`,
+      content: stubFile(interfaceName, methods, specs, packageName),
+    },
+  ];
+}
+
+/**
+ * Go interfaces and test stubs for each service `consumer` declares a
+ * dependency on, from the OpenAPI and protobuf files the provider links
+ * in `api_specs`. External services, providers without specs, and specs
+ * that cannot be read are skipped with the reason. Throws a `usage` error
+ * when `consumer` is not in the graph.
+ */
+export function generateDependencyStubs(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  consumer: string,
+  options: StubOptions,
+): StubGeneration {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const consumerNode = nodes.get(consumer);
+  if (!consumerNode) {
+    throw createKnowgraphError('usage', `No node ${consumer} in the graph`);
+  }
+  const byId = new Map(entities.map((entity) => [entity.id, entity]));
+  const providers = [
+    ...new Set(
+      graph.edges
+        .filter(
+          (edge) =>
+            edge.from === consumer &&
+            edge.kind === 'service' &&
+            edge.to !== consumer,
+        )
+        .map((edge) => edge.to),
+    ),
+  ]
+    .flatMap((id) => nodes.get(id) ?? [])
+    .sort((a, b) => compareStrings(a.name, b.name));
+
+  const stubs: DependencyStub[] = [];
+  const skipped: SkippedDependency[] = [];
+  for (const provider of providers) {
+    const entity = byId.get(provider.id);
+    const specs = entity ? entityApiSpecs(entity) : [];
+    if (specs.length === 0) {
+      skipped.push({
+        provider,
+        reason: entity
+          ? 'links no api_specs'
+          : 'not indexed here, so no api_specs to read',
+      });
+      continue;
+    }
+    let operations: ApiOperation[];
+    try {
+      operations = specs.flatMap((path) => [
+        ...readApiSpec(options.rootDir, path).operations,
+      ]);
+    } catch (err) {
+      skipped.push({ provider, reason: (err as Error).message });
+      continue;
+    }
+    if (operations.length === 0) {
+      skipped.push({
+        provider,
+        reason: 'its api_specs declare no operations',
+      });
+      continue;
+    }
+    const files = renderGoStubs(
+      provider,
+      specs,
+      operations,
+      options.packageName,
+    );
+    stubs.push({
+      provider,
+      specs,
+      interfaceName: `${goIdentifier(provider.name)}Client`,
+      operations: new Set(operations.map((op) => op.name)).size,
+      files,
+    });
+  }
+  return { consumer: consumerNode, stubs, skipped };
+}
@@ -0,0 +1,22 @@
+export type {
+  ApiField,
+  ApiFieldType,
+  ApiOperation,
+  ApiSpec,
+  ApiSpecFormat,
+  DependencyStub,
+  GeneratedFile,
+  SkippedDependency,
+  StubGeneration,
+  StubOptions,
+} from './types.js';
+export { goFileName, goIdentifier, nameWords } from './go-names.js';
+export {
+  apiSpecFormat,
+  declaredApiSpecs,
+  entityApiSpecs,
+  parseOpenApiSpec,
+  parseProtoSpec,
+  readApiSpec,
+} from './api-spec.js';
+export { generateDependencyStubs, renderGoStubs } from './go-stubs.js';
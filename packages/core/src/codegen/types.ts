@@ -0,0 +1,85 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for reading OpenAPI and protobuf specs and generating Go interfaces and stubs for declared service dependencies
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [codegen, go, openapi, protobuf, testing, types, interface]
+ * context:
+ *   business_goal: Keep generated code stable across knowgraph versions so regeneration yields small diffs
+ *   domain: graph
+ */
+import type { GraphNode } from '../graph/types.js';
+
+export type ApiSpecFormat = 'openapi' | 'proto';
+
+/** A field's type, as both spec formats can say it. */
+export type ApiFieldType =
+  | 'string'
+  | 'integer'
+  | 'number'
+  | 'boolean'
+  | 'bytes'
+  | 'object';
+
+export interface ApiField {
+  /** As the spec writes it, which is also its JSON name. */
+  readonly name: string;
+  readonly type: ApiFieldType;
+  readonly repeated: boolean;
+}
+
+/** An operation of a spec: an OpenAPI path and method, or a gRPC method. */
+export interface ApiOperation {
+  /** The `operationId` or rpc name, or the method and path without one. */
+  readonly name: string;
+  /** `POST /tokens` for OpenAPI, `rpc IssueToken` for protobuf. */
+  readonly route: string;
+  readonly summary: string | null;
+  /** Parameters and body fields, or the request message's fields. */
+  readonly request: readonly ApiField[];
+  /** The first success response's fields, or the response message's. */
+  readonly response: readonly ApiField[];
+}
+
+export interface ApiSpec {
+  /** Relative to the indexed root, with `/` separators. */
+  readonly path: string;
+  readonly format: ApiSpecFormat;
+  readonly operations: readonly ApiOperation[];
+}
+
+export interface GeneratedFile {
+  /** Relative to the output directory. */
+  readonly fileName: string;
+  readonly content: string;
+}
+
+/** The Go interface and stub generated for one provider. */
+export interface DependencyStub {
+  readonly provider: GraphNode;
+  /** The provider's specs the operations come from. */
+  readonly specs: readonly string[];
+  readonly interfaceName: string;
+  readonly operations: number;
+  readonly files: readonly GeneratedFile[];
+}
+
+/** A service dependency nothing was generated for, and why. */
+export interface SkippedDependency {
+  readonly provider: GraphNode;
+  readonly reason: string;
+}
+
+export interface StubGeneration {
+  readonly consumer: GraphNode;
+  readonly stubs: readonly DependencyStub[];
+  readonly skipped: readonly SkippedDependency[];
+}
+
+export interface StubOptions {
+  /** The indexed root, which entity file paths are relative to. */
+  readonly rootDir: string;
+  /** The Go package the files declare. */
+  readonly packageName: string;
+}
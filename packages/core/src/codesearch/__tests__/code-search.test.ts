@@ -0,0 +1,138 @@
+import { describe, it, expect } from 'vitest';
+import { parseEditQuery } from '../../bulkedit/bulk-edit.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { compileSearchPattern, searchCode } from '../code-search.js';
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  line: number,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType: 'module',
+    description: `${name} module`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line,
+    column: 0,
+    owner: 'auth-team',
+    status: null,
+    metadata: { type: 'module', description: `${name} module` },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const confidential = {
+  type: 'module',
+  description: 'Password hashing',
+  compliance: { data_sensitivity: 'confidential' },
+} as StoredEntity['metadata'];
+
+const sources: Record<string, string> = {
+  'src/auth/passwords.ts': [
+    "import bcrypt from 'bcrypt';",
+    '/** @knowgraph */',
+    'export function hash(p: string) {',
+    '  return bcrypt.hash(p, 12);',
+    '}',
+    '/** @knowgraph */',
+    'export function verify(p: string, h: string) {',
+    '  return bcrypt.compare(p, h);',
+    '}',
+  ].join('\n'),
+  'src/billing/cards.ts': [
+    '/** @knowgraph */',
+    'export const salt = bcrypt.genSaltSync();',
+  ].join('\n'),
+};
+
+const entities = [
+  makeEntity('verify', 'src/auth/passwords.ts', 6, { entityType: 'function' }),
+  makeEntity('hash', 'src/auth/passwords.ts', 2, {
+    entityType: 'function',
+    metadata: confidential,
+  }),
+  makeEntity('cards', 'src/billing/cards.ts', 1, { owner: 'billing-team' }),
+];
+
+const readFile = (path: string): string | undefined => sources[path];
+
+describe('searchCode', () => {
+  it('finds every matching line with the node it falls in', () => {
+    const result = searchCode(entities, {
+      pattern: compileSearchPattern('bcrypt'),
+      readFile,
+    });
+    expect(
+      result.hits.map((hit) => [hit.filePath, hit.line, hit.node?.name]),
+    ).toEqual([
+      ['src/auth/passwords.ts', 1, undefined],
+      ['src/auth/passwords.ts', 4, 'hash'],
+      ['src/auth/passwords.ts', 8, 'verify'],
+      ['src/billing/cards.ts', 2, 'cards'],
+    ]);
+    expect(result.hits[1]).toMatchObject({
+      column: 10,
+      text: '  return bcrypt.hash(p, 12);',
+      node: { entityType: 'function', owner: 'auth-team', line: 2 },
+    });
+    expect(result.files).toBe(2);
+  });
+
+  it('keeps only hits in nodes matching the structural filter', () => {
+    const result = searchCode(entities, {
+      pattern: compileSearchPattern('bcrypt'),
+      where: parseEditQuery(
+        'owner=auth-team AND compliance.data_sensitivity=confidential',
+      ),
+      readFile,
+    });
+    expect(result.hits.map((hit) => hit.node?.name)).toEqual(['hash']);
+    expect(result.files).toBe(1);
+
+    const byType = searchCode(entities, {
+      pattern: compileSearchPattern('bcrypt'),
+      where: parseEditQuery('type=function AND path=src/auth'),
+      readFile,
+    });
+    expect(byType.hits.map((hit) => hit.line)).toEqual([4, 8]);
+  });
+
+  it('stops at the limit and lists files it cannot read', () => {
+    const result = searchCode(
+      [...entities, makeEntity('gone', 'src/gone.ts', 1)],
+      { pattern: compileSearchPattern('bcrypt'), readFile, limit: 2 },
+    );
+    expect(result.hits).toHaveLength(2);
+    expect(result.truncated).toBe(true);
+
+    const all = searchCode([makeEntity('gone', 'src/gone.ts', 1)], {
+      pattern: compileSearchPattern('bcrypt'),
+      readFile,
+    });
+    expect(all.unreadable).toEqual(['src/gone.ts']);
+    expect(all.truncated).toBe(false);
+  });
+});
+
+describe('compileSearchPattern', () => {
+  it('matches literal text with fixed and ignores case on request', () => {
+    const pattern = compileSearchPattern('hash(p', {
+      fixed: true,
+      ignoreCase: true,
+    });
+    expect(pattern.test('return BCRYPT.HASH(p, 12)')).toBe(true);
+    expect(() => compileSearchPattern('hash(p')).toThrow('Invalid pattern');
+  });
+});
@@ -0,0 +1,153 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Searches the text of indexed files and keeps the hits that fall in nodes matching structural filters, giving each hit its node
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [codesearch, grep, search, query]
+ * context:
+ *   business_goal: Tie every text match to the owner and module it belongs to
+ *   domain: codesearch
+ */
+import { matchesEditQuery } from '../bulkedit/bulk-edit.js';
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { toPosixPath } from '../paths/paths.js';
+import type {
+  CodeSearchHit,
+  CodeSearchNode,
+  CodeSearchOptions,
+  CodeSearchResult,
+  SearchPatternOptions,
+} from './types.js';
+
+/**
+ * The regular expression `pattern` is, or the literal text with `fixed`.
+ * Throws a usage error for an invalid expression.
+ */
+export function compileSearchPattern(
+  pattern: string,
+  options: SearchPatternOptions = {},
+): RegExp {
+  const source = options.fixed
+    ? pattern.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')
+    : pattern;
+  try {
+    return new RegExp(source, options.ignoreCase ? 'i' : '');
+  } catch (err) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid pattern "${pattern}": ${(err as Error).message}`,
+    );
+  }
+}
+
+/**
+ * The fields a `where` term reads: the annotation, with the owner, tags,
+ * and type the index settled on, which may come from defaults rather
+ * than the block.
+ */
+function searchFields(entity: StoredEntity): Record<string, unknown> {
+  return {
+    ...(entity.metadata as Record<string, unknown>),
+    type: entity.entityType,
+    owner: entity.owner,
+    tags: entity.tags,
+  };
+}
+
+function toNode(entity: StoredEntity): CodeSearchNode {
+  return {
+    id: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    owner: entity.owner,
+    line: entity.line,
+  };
+}
+
+/** Indexed entities by file, each file's in the order they are declared. */
+function entitiesByFile(
+  entities: readonly StoredEntity[],
+): Map<string, StoredEntity[]> {
+  const byFile = new Map<string, StoredEntity[]>();
+  for (const entity of entities) {
+    const path = toPosixPath(entity.filePath);
+    const list = byFile.get(path) ?? [];
+    list.push(entity);
+    byFile.set(path, list);
+  }
+  for (const list of byFile.values()) {
+    list.sort((a, b) => a.line - b.line || a.column - b.column);
+  }
+  return byFile;
+}
+
+/** The entity whose annotation comes last at or above `line`. */
+function enclosing(
+  declared: readonly StoredEntity[],
+  line: number,
+): StoredEntity | undefined {
+  let found: StoredEntity | undefined;
+  for (const entity of declared) {
+    if (entity.line > line) break;
+    found = entity;
+  }
+  return found;
+}
+
+/**
+ * Every line of the indexed files that `pattern` matches, each with the
+ * node it falls in, in file and line order. With `where`, only files
+ * holding a matching node are read, and only hits in such a node kept.
+ */
+export function searchCode(
+  entities: readonly StoredEntity[],
+  options: CodeSearchOptions,
+): CodeSearchResult {
+  const { pattern, where } = options;
+  const matches = (entity: StoredEntity): boolean =>
+    !where ||
+    matchesEditQuery(searchFields(entity), toPosixPath(entity.filePath), where);
+
+  const byFile = entitiesByFile(entities);
+  const files = [...byFile.keys()]
+    .filter((path) => byFile.get(path)?.some(matches))
+    .sort(compareStrings);
+  const hits: CodeSearchHit[] = [];
+  const unreadable: string[] = [];
+  let truncated = false;
+  let searched = 0;
+
+  for (const filePath of files) {
+    if (truncated) break;
+    const content = options.readFile(filePath);
+    if (content === undefined) {
+      unreadable.push(filePath);
+      continue;
+    }
+    searched++;
+    const declared = byFile.get(filePath) ?? [];
+    const lines = content.split(/\r?\n/);
+    for (let i = 0; i < lines.length; i++) {
+      pattern.lastIndex = 0;
+      const found = pattern.exec(lines[i]);
+      if (!found) continue;
+      const entity = enclosing(declared, i + 1);
+      if (where && (!entity || !matches(entity))) continue;
+      if (hits.length === options.limit) {
+        truncated = true;
+        break;
+      }
+      hits.push({
+        filePath,
+        line: i + 1,
+        column: found.index + 1,
+        text: lines[i].trimEnd(),
+        node: entity ? toNode(entity) : null,
+      });
+    }
+  }
+  return { hits, files: searched, unreadable, truncated };
+}
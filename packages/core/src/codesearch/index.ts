@@ -0,0 +1,8 @@
+export type {
+  SearchPatternOptions,
+  CodeSearchOptions,
+  CodeSearchNode,
+  CodeSearchHit,
+  CodeSearchResult,
+} from './types.js';
+export { compileSearchPattern, searchCode } from './code-search.js';
@@ -0,0 +1,59 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for searching source text within the graph nodes that match structural filters such as owner or data sensitivity
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [codesearch, grep, search, types, interface]
+ * context:
+ *   business_goal: Let search filters use any field the graph knows about a node
+ *   domain: codesearch
+ */
+import type { EditQuery } from '../bulkedit/types.js';
+
+export interface SearchPatternOptions {
+  /** Match the pattern as literal text rather than a regular expression. */
+  readonly fixed?: boolean;
+  readonly ignoreCase?: boolean;
+}
+
+export interface CodeSearchOptions {
+  readonly pattern: RegExp;
+  /** Only hits in nodes matching these terms, as `knowgraph edit --query`. */
+  readonly where?: EditQuery;
+  /** The content of an indexed file, or undefined when it cannot be read. */
+  readonly readFile: (filePath: string) => string | undefined;
+  /** Stop after this many hits. */
+  readonly limit?: number;
+}
+
+/** The node a hit falls in: the nearest annotation at or above it. */
+export interface CodeSearchNode {
+  readonly id: string;
+  readonly name: string;
+  readonly entityType: string;
+  readonly owner: string | null;
+  readonly line: number;
+}
+
+export interface CodeSearchHit {
+  readonly filePath: string;
+  /** 1-based line of the match. */
+  readonly line: number;
+  /** 1-based column where the first match on the line starts. */
+  readonly column: number;
+  /** The line, without its trailing whitespace. */
+  readonly text: string;
+  /** Null for a line above the first annotation in its file. */
+  readonly node: CodeSearchNode | null;
+}
+
+export interface CodeSearchResult {
+  readonly hits: readonly CodeSearchHit[];
+  /** How many indexed files were searched. */
+  readonly files: number;
+  /** Indexed files that could not be read, such as deleted ones. */
+  readonly unreadable: readonly string[];
+  /** Whether `limit` cut the search short. */
+  readonly truncated: boolean;
+}
@@ -0,0 +1,122 @@
+import { describe, it, expect } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  lintManifest,
+  lintManifestText,
+  suggestKey,
+} from '../config-lint.js';
+
+function manifest(...lines: string[]): string {
+  return ['version: "1.0"', ...lines].join('\n');
+}
+
+describe('suggestKey', () => {
+  it('finds the key a typo, transposition, or dash meant', () => {
+    const keys = ['exclude', 'include', 'index', 'follow_symlinks'];
+    expect(suggestKey('exlude', keys)).toBe('exclude');
+    expect(suggestKey('excldue', keys)).toBe('exclude');
+    expect(suggestKey('follow-symlinks', keys)).toBe('follow_symlinks');
+    expect(suggestKey('Index', keys)).toBe('index');
+  });
+
+  it('suggests nothing for a key unlike any candidate', () => {
+    expect(suggestKey('colour', ['exclude', 'index'])).toBeUndefined();
+    expect(suggestKey('idx', ['index'])).toBeUndefined();
+  });
+});
+
+describe('lintManifestText', () => {
+  it('accepts a valid manifest', () => {
+    const text = manifest(
+      'exclude:',
+      '  - dist',
+      'index:',
+      '  follow_symlinks: true',
+    );
+    expect(lintManifestText(text)).toEqual([]);
+  });
+
+  it('reports unknown keys at any depth with suggestions', () => {
+    const issues = lintManifestText(
+      manifest(
+        'exlude:',
+        '  - dist',
+        'history:',
+        '  sinks:',
+        '    - type: file',
+        '      pth: alerts.jsonl',
+        'index:',
+        '  folow_symlinks: true',
+      ),
+    );
+    expect(issues.filter((issue) => issue.rule === 'unknown-key')).toEqual([
+      expect.objectContaining({
+        severity: 'error',
+        path: 'exlude',
+        message: 'Unknown key exlude; did you mean exclude?',
+        suggestion: 'exclude',
+      }),
+      expect.objectContaining({
+        path: 'history.sinks.0.pth',
+        suggestion: 'path',
+      }),
+      expect.objectContaining({
+        path: 'index.folow_symlinks',
+        suggestion: 'follow_symlinks',
+      }),
+    ]);
+  });
+
+  it('reports schema errors and suggests enum values', () => {
+    const issues = lintManifestText(manifest('rules:', '  cycles: eror'));
+    expect(issues).toEqual([
+      expect.objectContaining({
+        rule: 'schema',
+        severity: 'error',
+        path: 'rules.cycles',
+        suggestion: 'error',
+      }),
+    ]);
+  });
+
+  it('warns about deprecated keys', () => {
+    const issues = lintManifestText(manifest('include:', '  - "**/*"'));
+    expect(issues).toEqual([
+      expect.objectContaining({
+        rule: 'deprecated',
+        severity: 'warning',
+        path: 'include',
+      }),
+    ]);
+  });
+
+  it('reports YAML that does not parse', () => {
+    const issues = lintManifestText('version: "1.0"\nexclude: [dist\n');
+    expect(issues.length).toBeGreaterThan(0);
+    expect(issues.every((issue) => issue.rule === 'yaml')).toBe(true);
+  });
+
+  it('gives the line of each finding', () => {
+    const issues = lintManifestText(
+      manifest('index:', '  follow_symlinks: true', '  incrementl: true'),
+    );
+    expect(issues[0]).toMatchObject({ path: 'index.incrementl', line: 4 });
+  });
+});
+
+describe('lintManifest', () => {
+  it('lints the manifest file', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'kg-config-lint-'));
+    try {
+      const path = join(dir, '.knowgraph.yml');
+      writeFileSync(path, manifest('exclud: []'));
+      expect(lintManifest(path).map((issue) => issue.suggestion)).toEqual([
+        'exclude',
+      ]);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+});
@@ -0,0 +1,39 @@
+import { describe, it, expect } from 'vitest';
+import { readFileSync } from 'node:fs';
+import { ManifestSchema } from '../../types/manifest.js';
+import { manifestJsonSchema } from '../json-schema.js';
+
+const published = JSON.parse(
+  readFileSync(
+    new URL('../../../../../schema/v1.0/manifest.schema.json', import.meta.url),
+    'utf-8',
+  ),
+) as Record<string, unknown>;
+
+describe('manifestJsonSchema', () => {
+  it('has every manifest section', () => {
+    const properties = manifestJsonSchema().properties as object;
+    expect(Object.keys(properties).sort()).toEqual(
+      Object.keys(ManifestSchema.shape).sort(),
+    );
+  });
+
+  it('names exported schemas as definitions', () => {
+    const schema = manifestJsonSchema();
+    const properties = schema.properties as Record<string, unknown>;
+    expect(properties.parsers).toEqual({
+      additionalProperties: { $ref: '#/definitions/ParserConfig' },
+      type: 'object',
+    });
+    const definitions = schema.definitions as Record<string, object>;
+    expect(definitions.ParserConfig).toMatchObject({
+      additionalProperties: false,
+      type: 'object',
+    });
+  });
+
+  it('is what schema/v1.0/manifest.schema.json publishes', () => {
+    // Regenerate with `knowgraph config schema`
+    expect(published).toEqual(manifestJsonSchema());
+  });
+});
@@ -0,0 +1,272 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Lints .knowgraph.yml against the manifest schema, locating each problem and suggesting the key or value a typo meant
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [config, manifest, lint, validation, suggestions, deprecation]
+ * context:
+ *   business_goal: Point at the exact line and likely fix for a manifest mistake
+ *   domain: config
+ */
+import { readFileSync } from 'node:fs';
+import { isMap, isScalar, isSeq, LineCounter, parseDocument } from 'yaml';
+import type { Node } from 'yaml';
+import { z } from 'zod';
+import type { ZodIssue, ZodTypeAny } from 'zod';
+import { compareStrings } from '../canonical/canonical.js';
+import { ManifestSchema } from '../types/manifest.js';
+import type { ConfigIssue, ManifestDeprecation } from './types.js';
+
+type Path = readonly (string | number)[];
+
+/** Settings the manifest still accepts but that should be removed. */
+export const MANIFEST_DEPRECATIONS: readonly ManifestDeprecation[] = [
+  {
+    path: 'include',
+    message:
+      'include is ignored: every file a parser handles is scanned. Narrow scans with exclude or index --scope',
+  },
+];
+
+/** Edit distance counting a swap of neighbouring characters as one edit. */
+function editDistance(a: string, b: string): number {
+  const rows = Array.from({ length: a.length + 1 }, (_, i) =>
+    Array.from({ length: b.length + 1 }, (_, j) => (i === 0 ? j : i)),
+  );
+  for (let i = 1; i <= a.length; i++) {
+    for (let j = 1; j <= b.length; j++) {
+      const cost = a[i - 1] === b[j - 1] ? 0 : 1;
+      rows[i][j] = Math.min(
+        rows[i - 1][j] + 1,
+        rows[i][j - 1] + 1,
+        rows[i - 1][j - 1] + cost,
+      );
+      if (i > 1 && j > 1 && a[i - 1] === b[j - 2] && a[i - 2] === b[j - 1]) {
+        rows[i][j] = Math.min(rows[i][j], rows[i - 2][j - 2] + 1);
+      }
+    }
+  }
+  return rows[a.length][b.length];
+}
+
+function normalize(key: string): string {
+  return key.toLowerCase().replace(/-/g, '_');
+}
+
+/**
+ * The candidate `key` most likely misspells: the same but for case or
+ * `-` for `_`, or the closest within two edits (one for short keys).
+ */
+export function suggestKey(
+  key: string,
+  candidates: readonly string[],
+): string | undefined {
+  const wanted = normalize(key);
+  const limit = wanted.length < 5 ? 1 : 2;
+  let best: string | undefined;
+  let bestDistance = limit + 1;
+  for (const candidate of [...candidates].sort(compareStrings)) {
+    const distance = editDistance(wanted, normalize(candidate));
+    if (distance < bestDistance) {
+      best = candidate;
+      bestDistance = distance;
+    }
+  }
+  return best;
+}
+
+function unwrap(schema: ZodTypeAny): ZodTypeAny {
+  for (;;) {
+    if (schema instanceof z.ZodOptional || schema instanceof z.ZodNullable) {
+      schema = schema.unwrap();
+    } else if (schema instanceof z.ZodDefault) {
+      schema = schema.removeDefault();
+    } else if (schema instanceof z.ZodEffects) {
+      schema = schema.innerType();
+    } else if (schema instanceof z.ZodLazy) {
+      schema = schema.schema;
+    } else {
+      return schema;
+    }
+  }
+}
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+/**
+ * Each key in `value` that `schema` has no place for, with the keys the
+ * object at that path does take. Union members are told apart by their
+ * discriminator, or else by which one the value parses as.
+ */
+function findUnknownKeys(
+  schema: ZodTypeAny,
+  value: unknown,
+  path: Path,
+): { readonly path: Path; readonly known: readonly string[] }[] {
+  const type = unwrap(schema);
+  if (type instanceof z.ZodObject && isRecord(value)) {
+    const shape = type.shape as Record<string, ZodTypeAny>;
+    const open =
+      type._def.unknownKeys === 'passthrough' ||
+      !(type._def.catchall instanceof z.ZodNever);
+    return Object.entries(value).flatMap(([key, child]) =>
+      key in shape
+        ? findUnknownKeys(shape[key], child, [...path, key])
+        : open
+          ? []
+          : [{ path: [...path, key], known: Object.keys(shape) }],
+    );
+  }
+  if (type instanceof z.ZodRecord && isRecord(value)) {
+    return Object.entries(value).flatMap(([key, child]) =>
+      findUnknownKeys(type.valueSchema, child, [...path, key]),
+    );
+  }
+  if (type instanceof z.ZodArray && Array.isArray(value)) {
+    return value.flatMap((child, index) =>
+      findUnknownKeys(type.element, child, [...path, index]),
+    );
+  }
+  if (type instanceof z.ZodDiscriminatedUnion && isRecord(value)) {
+    const option = type.optionsMap.get(value[type.discriminator] as never);
+    return option ? findUnknownKeys(option, value, path) : [];
+  }
+  if (type instanceof z.ZodUnion) {
+    const options = type.options as readonly ZodTypeAny[];
+    const option = options.find((o) => o.safeParse(value).success);
+    return option ? findUnknownKeys(option, value, path) : [];
+  }
+  return [];
+}
+
+function hasPath(value: unknown, path: readonly string[]): boolean {
+  let node = value;
+  for (const key of path) {
+    if (!isRecord(node) || !(key in node)) return false;
+    node = node[key];
+  }
+  return true;
+}
+
+/** The 1-based line of the key or item at `path`, or of its nearest parent. */
+function locate(
+  contents: Node | null,
+  lines: LineCounter,
+  path: Path,
+): number | undefined {
+  let node: unknown = contents;
+  let offset: number | undefined;
+  for (const segment of path) {
+    if (isMap(node)) {
+      const pair = node.items.find(
+        (item) => isScalar(item.key) && String(item.key.value) === segment,
+      );
+      if (!pair) break;
+      offset = (pair.key as Node).range?.[0] ?? offset;
+      node = pair.value;
+    } else if (isSeq(node) && typeof segment === 'number') {
+      const item = node.items[segment] as Node | undefined;
+      if (!item) break;
+      offset = item.range?.[0] ?? offset;
+      node = item;
+    } else {
+      break;
+    }
+  }
+  return offset === undefined ? undefined : lines.linePos(offset).line;
+}
+
+/** A schema issue, with the enum value or union member a typo meant. */
+function schemaIssue(issue: ZodIssue): Omit<ConfigIssue, 'line'> {
+  const path = issue.path.join('.');
+  const options =
+    issue.code === 'invalid_enum_value' ||
+    issue.code === 'invalid_union_discriminator'
+      ? issue.options.map(String)
+      : [];
+  return {
+    rule: 'schema',
+    severity: 'error',
+    path,
+    message: `${path || 'The manifest'}: ${issue.message}`,
+    suggestion:
+      issue.code === 'invalid_enum_value'
+        ? suggestKey(String(issue.received), options)
+        : undefined,
+  };
+}
+
+/**
+ * Every problem in manifest `text`, in file order: YAML errors, values
+ * the schema rejects, keys it does not know, which commands would
+ * otherwise drop without a word, and deprecated settings. Unknown keys
+ * are errors, deprecations warnings.
+ */
+export function lintManifestText(
+  text: string,
+  deprecations: readonly ManifestDeprecation[] = MANIFEST_DEPRECATIONS,
+): readonly ConfigIssue[] {
+  const lines = new LineCounter();
+  const doc = parseDocument(text, { lineCounter: lines });
+  if (doc.errors.length > 0) {
+    return doc.errors.map((error): ConfigIssue => ({
+      rule: 'yaml',
+      severity: 'error',
+      path: '',
+      line: error.linePos?.[0]?.line,
+      message: error.message,
+    }));
+  }
+  const value: unknown = doc.toJS();
+  const issues: ConfigIssue[] = [];
+  const result = ManifestSchema.safeParse(value);
+  for (const issue of result.success ? [] : result.error.issues) {
+    // Unknown keys are reported below, with suggestions, for every object
+    if (issue.code === 'unrecognized_keys') continue;
+    issues.push({
+      ...schemaIssue(issue),
+      line: locate(doc.contents, lines, issue.path),
+    });
+  }
+  for (const unknown of findUnknownKeys(ManifestSchema, value, [])) {
+    const key = String(unknown.path.at(-1));
+    const suggestion = suggestKey(key, unknown.known);
+    const path = unknown.path.join('.');
+    issues.push({
+      rule: 'unknown-key',
+      severity: 'error',
+      path,
+      line: locate(doc.contents, lines, unknown.path),
+      message: suggestion
+        ? `Unknown key ${path}; did you mean ${suggestion}?`
+        : `Unknown key ${path}`,
+      suggestion,
+    });
+  }
+  for (const deprecation of deprecations) {
+    const path = deprecation.path.split('.');
+    if (!hasPath(value, path)) continue;
+    issues.push({
+      rule: 'deprecated',
+      severity: 'warning',
+      path: deprecation.path,
+      line: locate(doc.contents, lines, path),
+      message: deprecation.message,
+    });
+  }
+  return issues.sort(
+    (a, b) =>
+      (a.line ?? 0) - (b.line ?? 0) || compareStrings(a.path, b.path),
+  );
+}
+
+/** `lintManifestText` for the manifest at `configPath`. */
+export function lintManifest(
+  configPath: string,
+  deprecations?: readonly ManifestDeprecation[],
+): readonly ConfigIssue[] {
+  return lintManifestText(readFileSync(configPath, 'utf-8'), deprecations);
+}
@@ -0,0 +1,14 @@
+export type {
+  ConfigIssueRule,
+  ConfigIssueSeverity,
+  ConfigIssue,
+  ManifestDeprecation,
+} from './types.js';
+export type { JsonSchema } from './json-schema.js';
+export {
+  MANIFEST_DEPRECATIONS,
+  suggestKey,
+  lintManifestText,
+  lintManifest,
+} from './config-lint.js';
+export { manifestJsonSchema } from './json-schema.js';
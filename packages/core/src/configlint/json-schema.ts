@@ -0,0 +1,224 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Generates the JSON Schema for .knowgraph.yml from the Zod manifest schema, naming each exported schema as a definition
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [config, manifest, json-schema, editor, zod]
+ * context:
+ *   business_goal: Give editors completion and validation for every manifest section without a hand-kept schema falling behind
+ *   domain: config
+ */
+import { z } from 'zod';
+import type { ZodTypeAny } from 'zod';
+import * as entity from '../types/entity.js';
+import * as manifest from '../types/manifest.js';
+import * as delivery from '../types/manifest-delivery.js';
+import * as governance from '../types/manifest-governance.js';
+import * as graph from '../types/manifest-graph.js';
+import * as indexing from '../types/manifest-indexing.js';
+import * as security from '../types/manifest-security.js';
+import * as serve from '../types/manifest-serve.js';
+import * as workflows from '../types/manifest-workflows.js';
+
+export type JsonSchema = { readonly [key: string]: unknown };
+
+const SCHEMA_SUFFIX = 'Schema';
+
+/** Every module exporting schemas the manifest is built from. */
+const SCHEMA_MODULES = [
+  entity,
+  indexing,
+  serve,
+  security,
+  delivery,
+  governance,
+  graph,
+  workflows,
+  manifest,
+];
+
+/**
+ * The exported Zod schemas of the manifest and entity types, by the name
+ * they get under `definitions`: their export name without `Schema`.
+ */
+function namedSchemas(): ReadonlyMap<ZodTypeAny, string> {
+  const named = new Map<ZodTypeAny, string>();
+  for (const module of SCHEMA_MODULES) {
+    for (const [name, value] of Object.entries(module)) {
+      if (!(value instanceof z.ZodType) || !name.endsWith(SCHEMA_SUFFIX)) {
+        continue;
+      }
+      if (value === manifest.ManifestSchema) continue;
+      named.set(value, name.slice(0, -SCHEMA_SUFFIX.length));
+    }
+  }
+  return named;
+}
+
+function stringSchema(schema: z.ZodString): JsonSchema {
+  const out: Record<string, unknown> = { type: 'string' };
+  for (const check of schema._def.checks) {
+    switch (check.kind) {
+      case 'min':
+        out.minLength = check.value;
+        break;
+      case 'max':
+        out.maxLength = check.value;
+        break;
+      case 'length':
+        out.minLength = check.value;
+        out.maxLength = check.value;
+        break;
+      case 'regex':
+        out.pattern = check.regex.source;
+        break;
+      case 'startsWith':
+        out.pattern = `^${check.value.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')}`;
+        break;
+      case 'url':
+        out.format = 'uri';
+        break;
+      case 'email':
+        out.format = 'email';
+        break;
+      case 'date':
+        out.format = 'date';
+        break;
+      case 'datetime':
+        out.format = 'date-time';
+        break;
+    }
+  }
+  return out;
+}
+
+function numberSchema(schema: z.ZodNumber): JsonSchema {
+  const out: Record<string, unknown> = { type: 'number' };
+  for (const check of schema._def.checks) {
+    if (check.kind === 'int') {
+      out.type = 'integer';
+    } else if (check.kind === 'min') {
+      out[check.inclusive ? 'minimum' : 'exclusiveMinimum'] = check.value;
+    } else if (check.kind === 'max') {
+      out[check.inclusive ? 'maximum' : 'exclusiveMaximum'] = check.value;
+    }
+  }
+  return out;
+}
+
+/** Whether an object key of this schema may be left out. */
+function isOptional(schema: ZodTypeAny): boolean {
+  return schema instanceof z.ZodOptional || schema instanceof z.ZodDefault;
+}
+
+function createConverter(named: ReadonlyMap<ZodTypeAny, string>): {
+  readonly convert: (schema: ZodTypeAny) => JsonSchema;
+  readonly definitions: Record<string, JsonSchema>;
+} {
+  const definitions: Record<string, JsonSchema> = {};
+
+  function convert(schema: ZodTypeAny): JsonSchema {
+    const name = named.get(schema);
+    if (name === undefined) return inline(schema);
+    if (!(name in definitions)) {
+      // Claimed first, so a schema that refers to itself terminates
+      definitions[name] = {};
+      definitions[name] = inline(schema);
+    }
+    return { $ref: `#/definitions/${name}` };
+  }
+
+  function inline(schema: ZodTypeAny): JsonSchema {
+    if (schema instanceof z.ZodOptional || schema instanceof z.ZodNullable) {
+      return convert(schema.unwrap());
+    }
+    if (schema instanceof z.ZodDefault) {
+      return {
+        ...convert(schema.removeDefault()),
+        default: schema._def.defaultValue(),
+      };
+    }
+    if (schema instanceof z.ZodEffects) return convert(schema.innerType());
+    if (schema instanceof z.ZodLazy) return convert(schema.schema);
+    if (schema instanceof z.ZodString) return stringSchema(schema);
+    if (schema instanceof z.ZodNumber) return numberSchema(schema);
+    if (schema instanceof z.ZodBoolean) return { type: 'boolean' };
+    if (schema instanceof z.ZodLiteral) return { const: schema.value };
+    if (schema instanceof z.ZodEnum) {
+      return { type: 'string', enum: [...schema.options] };
+    }
+    if (schema instanceof z.ZodArray) {
+      const out: Record<string, unknown> = {
+        type: 'array',
+        items: convert(schema.element),
+      };
+      if (schema._def.minLength) out.minItems = schema._def.minLength.value;
+      if (schema._def.maxLength) out.maxItems = schema._def.maxLength.value;
+      return out;
+    }
+    if (schema instanceof z.ZodRecord) {
+      const propertyNames = Object.fromEntries(
+        Object.entries(convert(schema.keySchema)).filter(
+          ([key]) => key !== 'type',
+        ),
+      );
+      return {
+        type: 'object',
+        ...(Object.keys(propertyNames).length > 0 ? { propertyNames } : {}),
+        additionalProperties: convert(schema.valueSchema),
+      };
+    }
+    if (schema instanceof z.ZodObject) {
+      const shape = schema.shape as Record<string, ZodTypeAny>;
+      const required = Object.keys(shape).filter(
+        (key) => !isOptional(shape[key]),
+      );
+      // Unknown keys are dropped when parsed, which is as good as an error
+      // to whoever wrote them; `config lint` flags them too
+      const open =
+        schema._def.unknownKeys === 'passthrough' ||
+        !(schema._def.catchall instanceof z.ZodNever);
+      return {
+        type: 'object',
+        ...(required.length > 0 ? { required } : {}),
+        properties: Object.fromEntries(
+          Object.entries(shape).map(([key, value]) => [key, convert(value)]),
+        ),
+        additionalProperties: open ? true : false,
+      };
+    }
+    if (
+      schema instanceof z.ZodUnion ||
+      schema instanceof z.ZodDiscriminatedUnion
+    ) {
+      const options = schema.options as readonly ZodTypeAny[];
+      return { anyOf: options.map(convert) };
+    }
+    // z.unknown() and z.any() take anything
+    return {};
+  }
+
+  return { convert, definitions };
+}
+
+/**
+ * The JSON Schema (draft-07) of `.knowgraph.yml`, as published in
+ * `schema/v1.0/manifest.schema.json`. Exported Zod schemas become
+ * `definitions`; refinements such as ordered date windows are left to
+ * `knowgraph config lint`.
+ */
+export function manifestJsonSchema(): JsonSchema {
+  const { convert, definitions } = createConverter(namedSchemas());
+  const root = convert(manifest.ManifestSchema);
+  return {
+    $schema: 'http://json-schema.org/draft-07/schema#',
+    $id: 'https://knowgraph.dev/schema/v1.0/manifest.json',
+    title: 'KnowGraph Manifest',
+    description: 'Schema for .knowgraph.yml repository configuration file',
+    ...root,
+    definitions: Object.fromEntries(
+      Object.entries(definitions).sort(([a], [b]) => a.localeCompare(b)),
+    ),
+  };
+}
@@ -0,0 +1,39 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for manifest lint findings, from YAML syntax and schema errors to misspelled and deprecated keys
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [config, manifest, lint, validation, types, interface]
+ * context:
+ *   business_goal: Let editors and CI show manifest problems from the same findings
+ *   domain: config
+ */
+
+/**
+ * What is wrong: the YAML does not parse, a value breaks the schema, a
+ * key is not part of it, or a key is deprecated.
+ */
+export type ConfigIssueRule = 'yaml' | 'schema' | 'unknown-key' | 'deprecated';
+
+export type ConfigIssueSeverity = 'error' | 'warning';
+
+export interface ConfigIssue {
+  readonly rule: ConfigIssueRule;
+  readonly severity: ConfigIssueSeverity;
+  /** Dot path of the setting, such as `index.follow_symlinks`; empty for the file. */
+  readonly path: string;
+  /** 1-based line of the setting, when the YAML locates it. */
+  readonly line?: number;
+  readonly message: string;
+  /** The key or value probably meant, for misspellings. */
+  readonly suggestion?: string;
+}
+
+/** A manifest setting that still works, or is ignored, but should go. */
+export interface ManifestDeprecation {
+  /** Dot path of the setting. */
+  readonly path: string;
+  /** What to do instead. */
+  readonly message: string;
+}
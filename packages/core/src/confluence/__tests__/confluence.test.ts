@@ -0,0 +1,276 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import {
+  confluenceVersionMessage,
+  createConfluenceSink,
+} from '../confluence-sink.js';
+import { planConfluencePages } from '../module-pages.js';
+import { markdownToStorage } from '../storage-format.js';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { ConfluenceConfigSchema } from '../../types/manifest-workflows.js';
+import type { ConfluencePage } from '../types.js';
+
+function entity(id: string, filePath: string): StoredEntity {
+  return {
+    id,
+    name: id,
+    filePath,
+    entityType: 'module',
+    description: `The ${id} module`,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: { type: 'module', description: `The ${id} module` },
+  } as StoredEntity;
+}
+
+function node(id: string): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'module',
+    external: false,
+    filePath: null,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+const GRAPH: DependencyGraph = {
+  nodes: [node('billing'), node('ledger'), node('web')],
+  edges: [
+    {
+      from: 'billing',
+      to: 'ledger',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    },
+  ],
+};
+
+interface Call {
+  readonly url: string;
+  readonly method: string;
+  readonly body: string;
+  readonly headers: Record<string, string>;
+}
+
+/** Answer fetches with `respond`, recording each one. */
+function mockFetch(
+  respond: (call: Call) => { status?: number; body?: object },
+): Call[] {
+  const calls: Call[] = [];
+  vi.stubGlobal(
+    'fetch',
+    vi.fn(async (url: string, init: RequestInit = {}) => {
+      const call = {
+        url,
+        method: init.method ?? 'GET',
+        body: String(init.body ?? ''),
+        headers: (init.headers ?? {}) as Record<string, string>,
+      };
+      calls.push(call);
+      const { status = 200, body = {} } = respond(call);
+      return {
+        ok: status < 300,
+        status,
+        statusText: `HTTP ${status}`,
+        text: async () => JSON.stringify(body),
+      };
+    }),
+  );
+  return calls;
+}
+
+const noWait = async () => {};
+
+afterEach(() => {
+  vi.unstubAllGlobals();
+});
+
+describe('markdownToStorage', () => {
+  it('converts the Markdown docsgen writes', () => {
+    expect(
+      markdownToStorage(
+        [
+          '### billing',
+          '',
+          'Charges <cards> & `a_b_c` for _orders_',
+          '',
+          '**Owner:** payments-team · **Status:** stable',
+          '',
+          '- Services: ledger',
+          '- External APIs: stripe (external)',
+          '',
+          '![flow.png](./billing/flow.png)',
+          '',
+          '[Docs](https://example.com/docs)',
+        ].join('\n'),
+      ),
+    ).toBe(
+      [
+        '<h3>billing</h3>',
+        '<p>Charges &lt;cards&gt; &amp; <code>a_b_c</code> for <em>orders</em></p>',
+        '<p><strong>Owner:</strong> payments-team · <strong>Status:</strong> stable</p>',
+        '<ul><li>Services: ledger</li><li>External APIs: stripe (external)</li></ul>',
+        '<p><code>billing/flow.png</code></p>',
+        '<p><a href="https://example.com/docs">Docs</a></p>',
+      ].join('\n'),
+    );
+  });
+});
+
+describe('planConfluencePages', () => {
+  it('places mapped modules and the rest in the default space', () => {
+    const config = ConfluenceConfigSchema.parse({
+      base_url: 'https://acme.atlassian.net/wiki',
+      space: 'ENG',
+      pages: {
+        billing: { space: 'PAY', title: 'Billing', parent_id: '42' },
+        'src/web': { page_id: '7' },
+      },
+    });
+    const plan = planConfluencePages(
+      [
+        entity('billing', 'src/billing/doc.go'),
+        entity('ledger', 'src/ledger/doc.go'),
+        entity('web', 'src/web/doc.go'),
+      ],
+      GRAPH,
+      config,
+    );
+    expect(plan.pages.map(({ body: _body, ...page }) => page)).toEqual([
+      {
+        title: 'Billing',
+        space: 'PAY',
+        parentId: '42',
+        directory: 'src/billing',
+        entities: ['billing'],
+      },
+      {
+        title: 'ledger',
+        space: 'ENG',
+        directory: 'src/ledger',
+        entities: ['ledger'],
+      },
+      {
+        title: 'web',
+        space: 'ENG',
+        pageId: '7',
+        directory: 'src/web',
+        entities: ['web'],
+      },
+    ]);
+    expect(plan.pages[0].body).toContain('<ul><li>Services: ledger</li></ul>');
+  });
+
+  it('skips modules with no space and titles already taken', () => {
+    const config = ConfluenceConfigSchema.parse({
+      base_url: 'https://acme.atlassian.net/wiki',
+      pages: {
+        'src/a': { space: 'ENG', title: 'Billing' },
+        'src/b': { space: 'ENG', title: 'Billing' },
+      },
+    });
+    const plan = planConfluencePages(
+      [
+        entity('a', 'src/a/doc.go'),
+        entity('b', 'src/b/doc.go'),
+        entity('c', 'src/c/doc.go'),
+      ],
+      GRAPH,
+      config,
+    );
+    expect(plan.pages.map((page) => page.directory)).toEqual(['src/a']);
+    expect(plan.skipped).toEqual([
+      {
+        directory: 'src/b',
+        reason: 'src/a already publishes the page Billing in ENG',
+      },
+      { directory: 'src/c', reason: 'not mapped to a space' },
+    ]);
+  });
+});
+
+describe('createConfluenceSink', () => {
+  const page: ConfluencePage = {
+    title: 'billing',
+    space: 'ENG',
+    parentId: '42',
+    directory: 'src/billing',
+    entities: ['billing'],
+    body: '<p>The billing module</p>',
+  };
+  const message = confluenceVersionMessage(page.body);
+  const sink = () =>
+    createConfluenceSink({
+      baseUrl: 'https://acme.atlassian.net/wiki/',
+      token: 'secret',
+      email: 'bot@acme.com',
+      sleep: noWait,
+    });
+
+  it('creates a page that does not exist', async () => {
+    const calls = mockFetch((call) =>
+      call.method === 'POST'
+        ? { body: { id: '100', version: { number: 1 } } }
+        : { body: { results: [] } },
+    );
+    expect(await sink().publish(page)).toEqual({
+      title: 'billing',
+      space: 'ENG',
+      id: '100',
+      version: 1,
+      status: 'created',
+    });
+    expect(calls[0].url).toBe(
+      'https://acme.atlassian.net/wiki/rest/api/content?spaceKey=ENG&title=billing&type=page&expand=version',
+    );
+    expect(calls[0].headers.Authorization).toBe(
+      `Basic ${Buffer.from('bot@acme.com:secret').toString('base64')}`,
+    );
+    expect(JSON.parse(calls[1].body)).toEqual({
+      type: 'page',
+      title: 'billing',
+      space: { key: 'ENG' },
+      ancestors: [{ id: '42' }],
+      body: {
+        storage: {
+          value: '<p>The billing module</p>',
+          representation: 'storage',
+        },
+      },
+      version: { number: 1, message },
+    });
+  });
+
+  it('updates a changed page and leaves an unchanged one alone', async () => {
+    let latest = { number: 3, message: 'edited by hand' };
+    const calls = mockFetch((call) => {
+      if (call.method === 'PUT') {
+        latest = JSON.parse(call.body).version;
+        return { body: {} };
+      }
+      return { body: { results: [{ id: '100', version: latest }] } };
+    });
+
+    const first = await sink().publish(page);
+    const second = await sink().publish(page);
+    expect([first.status, first.version]).toEqual(['updated', 4]);
+    expect([second.status, second.version]).toEqual(['unchanged', 4]);
+    const puts = calls.filter((call) => call.method === 'PUT');
+    expect(puts).toHaveLength(1);
+    expect(puts[0].url).toBe(
+      'https://acme.atlassian.net/wiki/rest/api/content/100',
+    );
+    expect(JSON.parse(puts[0].body).version).toEqual({ number: 4, message });
+  });
+
+  it('reports what Confluence rejects', async () => {
+    mockFetch(() => ({ status: 403, body: { message: 'Not permitted' } }));
+    await expect(sink().publish({ ...page, pageId: '7' })).rejects.toThrow(
+      'Confluence request failed: 403 Not permitted',
+    );
+  });
+});
@@ -0,0 +1,129 @@
+/**
+ * @knowgraph
+ * type: service
+ * description: Confluence sink that creates module pages, or updates them only when their content changed since the last publish
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [confluence, publish, rest, idempotent]
+ * context:
+ *   business_goal: Keep page histories readable by saving a new version only when a module really changed
+ *   domain: confluence
+ */
+import { createHash } from 'node:crypto';
+import { createWarehouseClient } from '../warehouse/http.js';
+import type {
+  ConfluencePage,
+  ConfluenceSink,
+  ConfluenceSinkOptions,
+  PublishedConfluencePage,
+} from './types.js';
+
+interface ContentVersion {
+  readonly number: number;
+  readonly message?: string;
+}
+
+interface Content {
+  readonly id: string;
+  readonly version?: ContentVersion;
+}
+
+/**
+ * The version message that marks a page as published with `body`, so a
+ * later publish of the same body can leave the page alone.
+ */
+export function confluenceVersionMessage(body: string): string {
+  const hash = createHash('sha256').update(body).digest('hex');
+  return `knowgraph ${hash.slice(0, 16)}`;
+}
+
+/**
+ * Publish pages through the REST API of the site at `baseUrl`. A page
+ * is found by its `pageId`, or else by title in its space. Each version
+ * it writes carries a hash of the body in its message; a page whose
+ * latest version has the hash of the new body is not touched, so
+ * publishing again adds no versions and sends no notifications.
+ */
+export function createConfluenceSink(
+  options: ConfluenceSinkOptions,
+): ConfluenceSink {
+  const client = createWarehouseClient('Confluence', options);
+  const api = `${options.baseUrl.replace(/\/$/, '')}/rest/api/content`;
+  const authorization = options.email
+    ? `Basic ${Buffer.from(`${options.email}:${options.token}`).toString('base64')}`
+    : `Bearer ${options.token}`;
+  const headers = {
+    Authorization: authorization,
+    Accept: 'application/json',
+    'Content-Type': 'application/json',
+  };
+
+  async function findPage(page: ConfluencePage): Promise<Content | undefined> {
+    if (page.pageId) {
+      const id = encodeURIComponent(page.pageId);
+      const { body } = await client.request(`${api}/${id}?expand=version`, {
+        headers,
+      });
+      return body as unknown as Content;
+    }
+    const query = new URLSearchParams({
+      spaceKey: page.space,
+      title: page.title,
+      type: 'page',
+      expand: 'version',
+    });
+    const { body } = await client.request(`${api}?${query}`, { headers });
+    const results = body.results as readonly Content[] | undefined;
+    return results?.[0];
+  }
+
+  async function publish(
+    page: ConfluencePage,
+  ): Promise<PublishedConfluencePage> {
+    const message = confluenceVersionMessage(page.body);
+    const content = {
+      type: 'page',
+      title: page.title,
+      space: { key: page.space },
+      ...(page.parentId ? { ancestors: [{ id: page.parentId }] } : {}),
+      body: { storage: { value: page.body, representation: 'storage' } },
+    };
+    const existing = await findPage(page);
+    const published = (id: string, version: number) => ({
+      title: page.title,
+      space: page.space,
+      id,
+      version,
+    });
+
+    if (!existing) {
+      const { body } = await client.request(api, {
+        method: 'POST',
+        headers,
+        body: JSON.stringify({ ...content, version: { number: 1, message } }),
+      });
+      const created = body as unknown as Content;
+      return {
+        ...published(created.id, created.version?.number ?? 1),
+        status: 'created',
+      };
+    }
+
+    const current = existing.version?.number ?? 1;
+    if (existing.version?.message === message) {
+      return { ...published(existing.id, current), status: 'unchanged' };
+    }
+    await client.request(`${api}/${encodeURIComponent(existing.id)}`, {
+      method: 'PUT',
+      headers,
+      body: JSON.stringify({
+        id: existing.id,
+        ...content,
+        version: { number: current + 1, message },
+      }),
+    });
+    return { ...published(existing.id, current + 1), status: 'updated' };
+  }
+
+  return { publish };
+}
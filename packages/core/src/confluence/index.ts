@@ -0,0 +1,15 @@
+export type {
+  ConfluencePage,
+  ConfluencePagePlan,
+  ConfluencePageStatus,
+  ConfluenceSink,
+  ConfluenceSinkOptions,
+  PublishedConfluencePage,
+  SkippedConfluencePage,
+} from './types.js';
+export { escapeXml, markdownToStorage } from './storage-format.js';
+export { planConfluencePages, renderModulePage } from './module-pages.js';
+export {
+  confluenceVersionMessage,
+  createConfluenceSink,
+} from './confluence-sink.js';
@@ -0,0 +1,102 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Builds one Confluence page per module directory from the docsgen Markdown and the page mapping in the manifest
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [confluence, docs, pages, mapping]
+ * context:
+ *   business_goal: Put each module page in the space and under the parent its team chose
+ *   domain: confluence
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import {
+  modulesByDirectory,
+  renderModuleMarkdown,
+} from '../docsgen/readme-sections.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  ConfluenceConfig,
+  ConfluencePageConfig,
+} from '../types/manifest-workflows.js';
+import { escapeXml, markdownToStorage } from './storage-format.js';
+import type {
+  ConfluencePage,
+  ConfluencePagePlan,
+  SkippedConfluencePage,
+} from './types.js';
+
+/** The mapping for a directory, or failing that for one of its modules. */
+function pageMapping(
+  config: ConfluenceConfig,
+  directory: string,
+  modules: readonly StoredEntity[],
+): ConfluencePageConfig | undefined {
+  const names = modules.map((entity) => entity.name).sort(compareStrings);
+  const key = [directory, ...names].find((name) => name in config.pages);
+  return key === undefined ? undefined : config.pages[key];
+}
+
+/**
+ * The page body: the modules as docsgen describes them, after a note
+ * that says where the page comes from. Diagram paths are relative to
+ * the root, since the page has no directory of its own.
+ */
+export function renderModulePage(
+  modules: readonly StoredEntity[],
+  graph: DependencyGraph,
+  directory: string,
+): string {
+  const note =
+    '<p><em>Generated by <code>knowgraph publish confluence</code> from ' +
+    `the <code>@knowgraph</code> annotations in <code>${escapeXml(directory)}</code>; ` +
+    'edit those rather than this page.</em></p>';
+  return `${note}\n${markdownToStorage(renderModuleMarkdown(modules, graph, '.'))}`;
+}
+
+/**
+ * A page for every directory in `modulesByDirectory`, placed by the
+ * mapping in `config.pages` for the directory or one of its modules, or
+ * else in `config.space`. Directories with neither are skipped, as are
+ * pages whose title another directory already took in the same space.
+ */
+export function planConfluencePages(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  config: ConfluenceConfig,
+): ConfluencePagePlan {
+  const pages: ConfluencePage[] = [];
+  const skipped: SkippedConfluencePage[] = [];
+  const titles = new Map<string, string>();
+  for (const [directory, modules] of modulesByDirectory(entities)) {
+    const mapping = pageMapping(config, directory, modules);
+    const space = mapping?.space ?? config.space;
+    if (!space) {
+      skipped.push({ directory, reason: 'not mapped to a space' });
+      continue;
+    }
+    const title =
+      mapping?.title ?? (modules.length === 1 ? modules[0].name : directory);
+    const taken = titles.get(`${space}\0${title}`);
+    if (taken !== undefined) {
+      skipped.push({
+        directory,
+        reason: `${taken} already publishes the page ${title} in ${space}`,
+      });
+      continue;
+    }
+    titles.set(`${space}\0${title}`, directory);
+    const parentId = mapping?.parent_id ?? config.parent_id;
+    pages.push({
+      title,
+      space,
+      ...(parentId ? { parentId } : {}),
+      ...(mapping?.page_id ? { pageId: mapping.page_id } : {}),
+      directory,
+      entities: modules.map((entity) => entity.id).sort(compareStrings),
+      body: renderModulePage(modules, graph, directory),
+    });
+  }
+  return { pages, skipped };
+}
@@ -0,0 +1,96 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Converts the Markdown that docsgen writes for modules into Confluence storage format
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [confluence, markdown, storage-format, xhtml]
+ * context:
+ *   business_goal: Show generated docs in Confluence as they look in the repository
+ *   domain: confluence
+ */
+
+export function escapeXml(text: string): string {
+  return text
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;');
+}
+
+function isAbsoluteUrl(target: string): boolean {
+  return /^https?:\/\//i.test(target);
+}
+
+/** Emphasis, links, and images in text that holds no code span. */
+function formatText(text: string): string {
+  return escapeXml(text)
+    .replace(/!\[([^\]]*)\]\(([^)\s]+)\)/g, (_match, alt, target) =>
+      isAbsoluteUrl(target)
+        ? `<ac:image ac:alt="${alt}"><ri:url ri:value="${target}" /></ac:image>`
+        : `<code>${target.replace(/^\.\//, '')}</code>`,
+    )
+    .replace(/\[([^\]]*)\]\(([^)\s]+)\)/g, (_match, label, target) =>
+      isAbsoluteUrl(target)
+        ? `<a href="${target}">${label}</a>`
+        : `<code>${target.replace(/^\.\//, '')}</code>`,
+    )
+    .replace(/\*\*(.+?)\*\*/g, '<strong>$1</strong>')
+    .replace(/(^|[\s(])_(\S(?:.*?\S)?)_(?=$|[\s).,;:])/g, '$1<em>$2</em>');
+}
+
+function formatInline(text: string): string {
+  return text
+    .split(/(`[^`]+`)/)
+    .map((part) =>
+      /^`[^`]+`$/.test(part)
+        ? `<code>${escapeXml(part.slice(1, -1))}</code>`
+        : formatText(part),
+    )
+    .join('');
+}
+
+/**
+ * `markdown` in Confluence storage format. Covers what
+ * `renderModuleMarkdown` writes: ATX headings, paragraphs, `-` lists,
+ * bold, underscore italics, code spans, links, and images. Links and
+ * images with relative targets, which would not resolve in Confluence,
+ * become their path in code.
+ */
+export function markdownToStorage(markdown: string): string {
+  const blocks: string[] = [];
+  let paragraph: string[] = [];
+  let items: string[] = [];
+  const flush = (): void => {
+    if (paragraph.length > 0) {
+      blocks.push(`<p>${formatInline(paragraph.join(' '))}</p>`);
+      paragraph = [];
+    }
+    if (items.length > 0) {
+      const list = items.map((item) => `<li>${formatInline(item)}</li>`);
+      blocks.push(`<ul>${list.join('')}</ul>`);
+      items = [];
+    }
+  };
+
+  for (const raw of markdown.split(/\r?\n/)) {
+    const line = raw.trim();
+    const heading = /^(#{1,6})\s+(.*)$/.exec(line);
+    const item = /^[-*]\s+(.*)$/.exec(line);
+    if (line === '') {
+      flush();
+    } else if (heading) {
+      flush();
+      const level = heading[1].length;
+      blocks.push(`<h${level}>${formatInline(heading[2])}</h${level}>`);
+    } else if (item) {
+      if (paragraph.length > 0) flush();
+      items.push(item[1]);
+    } else {
+      if (items.length > 0) flush();
+      paragraph.push(line);
+    }
+  }
+  flush();
+  return blocks.join('\n');
+}
@@ -0,0 +1,66 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for publishing module pages to Confluence spaces through its REST API
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [confluence, publish, docs, types, interface]
+ * context:
+ *   business_goal: Let the CLI and tests publish pages without depending on a live Confluence site
+ *   domain: confluence
+ */
+import type { WarehouseSinkOptions } from '../warehouse/types.js';
+
+/** One module directory's page, ready to publish. */
+export interface ConfluencePage {
+  readonly title: string;
+  readonly space: string;
+  /** The page to publish under; the space's root when unset. */
+  readonly parentId?: string;
+  /** Update this page rather than looking one up by title. */
+  readonly pageId?: string;
+  /** Module directory the page describes. */
+  readonly directory: string;
+  /** Ids of the modules on the page. */
+  readonly entities: readonly string[];
+  /** The page in Confluence storage format. */
+  readonly body: string;
+}
+
+export interface SkippedConfluencePage {
+  readonly directory: string;
+  readonly reason: string;
+}
+
+export interface ConfluencePagePlan {
+  readonly pages: readonly ConfluencePage[];
+  readonly skipped: readonly SkippedConfluencePage[];
+}
+
+export type ConfluencePageStatus = 'created' | 'updated' | 'unchanged';
+
+export interface PublishedConfluencePage {
+  readonly title: string;
+  readonly space: string;
+  readonly id: string;
+  readonly version: number;
+  readonly status: ConfluencePageStatus;
+}
+
+export interface ConfluenceSinkOptions
+  extends Pick<WarehouseSinkOptions, 'retry' | 'sleep'> {
+  /** The site, such as `https://acme.atlassian.net/wiki`. */
+  readonly baseUrl: string;
+  /** API token with `email`, or a personal access token without. */
+  readonly token: string;
+  /** Account email, for Confluence Cloud's basic auth. */
+  readonly email?: string;
+}
+
+export interface ConfluenceSink {
+  /**
+   * Create the page, or update it when its content changed since the
+   * last publish. Throws an I/O error when Confluence rejects a request.
+   */
+  publish(page: ConfluencePage): Promise<PublishedConfluencePage>;
+}
@@ -0,0 +1,165 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { externalNode } from '../../graph/graph-builder.js';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  GraphEdge,
+  GraphNode,
+} from '../../graph/types.js';
+import {
+  appendConformancePoint,
+  checkConformance,
+  conformancePoint,
+  parseTargetArchitecture,
+  readConformanceHistory,
+} from '../conformance.js';
+
+function node(name: string, overrides: Partial<GraphNode> = {}): GraphNode {
+  return {
+    id: `id-${name}`,
+    name,
+    entityType: 'module',
+    external: false,
+    filePath: `src/${name}.ts`,
+    owner: 'payments-team',
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+function edge(
+  from: string,
+  to: string,
+  kind: DependencyKind = 'service',
+): GraphEdge {
+  return { from, to, kind, provenance: 'declared', confidence: 1 };
+}
+
+const postgres = externalNode('database', 'postgres-main');
+
+const graph: DependencyGraph = {
+  nodes: [
+    node('checkout', { entityType: 'service', aliases: ['checkout-api'] }),
+    node('payments'),
+    node('legacy-billing'),
+    node('format', { entityType: 'function' }),
+    postgres,
+  ],
+  edges: [
+    edge('id-checkout', 'id-payments'),
+    edge('id-checkout', 'id-payments', 'import'),
+    edge('id-payments', postgres.id, 'database'),
+    edge('id-checkout', 'id-legacy-billing'),
+    edge('id-checkout', 'id-format', 'reference'),
+    edge('id-format', 'id-payments'),
+  ],
+};
+
+const target = parseTargetArchitecture(
+  [
+    'modules: [Checkout-API, payments, ledger]',
+    'edges:',
+    '  - { from: checkout-api, to: payments }',
+    '  - { from: payments, to: postgres-main }',
+    '  - { from: payments, to: ledger }',
+  ].join('\n'),
+);
+
+describe('parseTargetArchitecture', () => {
+  it('defaults missing lists and rejects unknown keys', () => {
+    expect(parseTargetArchitecture('')).toEqual({ modules: [], edges: [] });
+    expect(() =>
+      parseTargetArchitecture('edges:\n  - from: a\n', 'architecture.yml'),
+    ).toThrow('architecture.yml: edges.0.to: Required');
+    expect(() => parseTargetArchitecture('layers: []')).toThrow(
+      'Unrecognized key',
+    );
+    expect(() => parseTargetArchitecture('modules: [a')).toThrow(
+      'is not valid YAML',
+    );
+  });
+});
+
+describe('checkConformance', () => {
+  it('reports what the graph adds to and lacks from the target', () => {
+    const report = checkConformance(graph, target);
+    expect(report.modules).toEqual({
+      matched: 3,
+      missing: ['ledger'],
+      unexpected: [
+        {
+          id: 'id-legacy-billing',
+          name: 'legacy-billing',
+          entityType: 'module',
+          filePath: 'src/legacy-billing.ts',
+          owner: 'payments-team',
+        },
+      ],
+    });
+    expect(report.edges).toEqual({
+      matched: 2,
+      missing: [{ from: 'payments', to: 'ledger' }],
+      unexpected: [
+        { from: 'checkout', to: 'legacy-billing', kinds: ['service'] },
+      ],
+    });
+    // 5 matched of 9 built or intended
+    expect(report.convergence).toBe(55.6);
+  });
+
+  it('converges fully when the graph is the target', () => {
+    const built = parseTargetArchitecture(
+      [
+        'modules: [legacy-billing]',
+        'edges:',
+        '  - { from: checkout, to: payments }',
+        '  - { from: checkout, to: legacy-billing }',
+        '  - { from: payments, to: postgres-main }',
+      ].join('\n'),
+    );
+    expect(checkConformance(graph, built).convergence).toBe(100);
+    expect(
+      checkConformance({ nodes: [], edges: [] }, { modules: [], edges: [] })
+        .convergence,
+    ).toBe(100);
+  });
+});
+
+describe('conformance history', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-conformance-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('appends runs and reads them back oldest first', () => {
+    const path = join(dir, '.knowgraph', 'conformance.jsonl');
+    expect(readConformanceHistory(path)).toEqual([]);
+    const report = checkConformance(graph, target);
+    const point = conformancePoint(report, new Date('2026-01-05T00:00:00Z'));
+    expect(point).toEqual({
+      timestamp: '2026-01-05T00:00:00.000Z',
+      convergence: 55.6,
+      missingModules: 1,
+      unexpectedModules: 1,
+      missingEdges: 1,
+      unexpectedEdges: 1,
+    });
+    appendConformancePoint(path, point);
+    appendConformancePoint(path, { ...point, convergence: 60 });
+    expect(
+      readConformanceHistory(path).map((entry) => entry.convergence),
+    ).toEqual([55.6, 60]);
+
+    writeFileSync(path, '{}\nnot json\n');
+    expect(() => readConformanceHistory(path)).toThrow(`${path}:2`);
+  });
+});
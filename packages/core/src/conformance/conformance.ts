@@ -0,0 +1,258 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads a target architecture file and reports the modules and edges the built graph adds to or lacks from it, with a convergence percentage recorded over time
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [conformance, architecture, target, drift, convergence, jsonl]
+ * context:
+ *   business_goal: Turn the gap between intended and actual architecture into a number that can be tracked
+ *   domain: conformance
+ */
+import { appendFileSync, existsSync, mkdirSync, readFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { parse as parseYaml } from 'yaml';
+import { z } from 'zod';
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  GraphNode,
+} from '../graph/types.js';
+import type {
+  ConformanceOptions,
+  ConformancePoint,
+  ConformanceReport,
+  TargetArchitecture,
+  TargetEdge,
+  UnexpectedEdge,
+  UnexpectedModule,
+} from './types.js';
+
+const TargetArchitectureSchema = z
+  .object({
+    modules: z.array(z.string().min(1)).default([]),
+    edges: z
+      .array(
+        z
+          .object({ from: z.string().min(1), to: z.string().min(1) })
+          .strict(),
+      )
+      .default([]),
+  })
+  .strict();
+
+/**
+ * Parse a target architecture, YAML or JSON, naming the intended modules
+ * and the edges allowed between them:
+ *
+ *     modules: [checkout, payments, ledger]
+ *     edges:
+ *       - from: checkout
+ *         to: payments
+ *       - from: payments
+ *         to: postgres-main
+ *
+ * Edge ends are intended modules too, listed or not.
+ */
+export function parseTargetArchitecture(
+  input: string,
+  source = 'target architecture',
+): TargetArchitecture {
+  let body: unknown;
+  try {
+    body = parseYaml(input) ?? {};
+  } catch (err) {
+    throw createKnowgraphError(
+      'parse',
+      `${source} is not valid YAML: ${(err as Error).message}`,
+    );
+  }
+  const parsed = TargetArchitectureSchema.safeParse(body);
+  if (!parsed.success) {
+    const issue = parsed.error.issues[0];
+    throw createKnowgraphError(
+      'schema',
+      `${source}: ${issue.path.join('.') || 'modules'}: ${issue.message}`,
+    );
+  }
+  return parsed.data;
+}
+
+function pairKey(from: string, to: string): string {
+  return `${from}\u0000${to}`;
+}
+
+function round(value: number): number {
+  return Math.round(value * 10) / 10;
+}
+
+/**
+ * Compare `graph` with `target`. Modules are the nodes of `options.types`;
+ * target names match one by id, name, or alias, ignoring case, or else an
+ * external node such as a database. Built edges count from a module to a
+ * module or external, whatever their kind, apart from description
+ * references. Stubs of other repositories are left out.
+ */
+export function checkConformance(
+  graph: DependencyGraph,
+  target: TargetArchitecture,
+  options: ConformanceOptions = {},
+): ConformanceReport {
+  const types = new Set<string>(options.types ?? ['module', 'service']);
+  const isModule = (node: GraphNode): boolean =>
+    !node.external &&
+    !node.stub &&
+    node.entityType !== null &&
+    types.has(node.entityType);
+
+  const byName = new Map<string, GraphNode>();
+  for (const node of graph.nodes) {
+    if (!isModule(node) && !(node.external && !node.stub)) continue;
+    for (const name of [node.id, node.name, ...(node.aliases ?? [])]) {
+      const key = name.toLowerCase();
+      const existing = byName.get(key);
+      if (!existing || (existing.external && !node.external)) {
+        byName.set(key, node);
+      }
+    }
+  }
+  const resolve = (name: string): GraphNode | undefined =>
+    byName.get(name.toLowerCase());
+
+  const named = new Map<string, string>();
+  for (const name of [
+    ...target.modules,
+    ...target.edges.flatMap((edge) => [edge.from, edge.to]),
+  ]) {
+    if (!named.has(name.toLowerCase())) named.set(name.toLowerCase(), name);
+  }
+  const intended = new Set<string>();
+  const missingModules: string[] = [];
+  for (const name of named.values()) {
+    const node = resolve(name);
+    if (node) intended.add(node.id);
+    else missingModules.push(name);
+  }
+  const unexpectedModules: UnexpectedModule[] = graph.nodes
+    .filter((node) => isModule(node) && !intended.has(node.id))
+    .map((node) => ({
+      id: node.id,
+      name: node.name,
+      entityType: node.entityType,
+      filePath: node.filePath,
+      owner: node.owner,
+    }))
+    .sort(
+      (a, b) => compareStrings(a.name, b.name) || compareStrings(a.id, b.id),
+    );
+
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const built = new Map<string, { from: GraphNode; to: GraphNode }>();
+  const kinds = new Map<string, DependencyKind[]>();
+  for (const edge of graph.edges) {
+    const from = nodes.get(edge.from);
+    const to = nodes.get(edge.to);
+    if (!from || !to || from.id === to.id || edge.kind === 'reference') {
+      continue;
+    }
+    if (!isModule(from) || !(isModule(to) || (to.external && !to.stub))) {
+      continue;
+    }
+    const key = pairKey(from.id, to.id);
+    if (!built.has(key)) built.set(key, { from, to });
+    const seen = kinds.get(key) ?? [];
+    if (!seen.includes(edge.kind)) kinds.set(key, [...seen, edge.kind]);
+  }
+
+  const allowed = new Set<string>();
+  const missingEdges: TargetEdge[] = [];
+  for (const edge of target.edges) {
+    const from = resolve(edge.from);
+    const to = resolve(edge.to);
+    const key = from && to ? pairKey(from.id, to.id) : undefined;
+    if (key && built.has(key)) allowed.add(key);
+    else missingEdges.push({ from: edge.from, to: edge.to });
+  }
+  const unexpectedEdges: UnexpectedEdge[] = [...built]
+    .filter(([key]) => !allowed.has(key))
+    .map(([key, { from, to }]) => ({
+      from: from.name,
+      to: to.name,
+      kinds: kinds.get(key) ?? [],
+    }))
+    .sort(
+      (a, b) => compareStrings(a.from, b.from) || compareStrings(a.to, b.to),
+    );
+
+  const matched = intended.size + allowed.size;
+  const total =
+    matched +
+    missingModules.length +
+    unexpectedModules.length +
+    missingEdges.length +
+    unexpectedEdges.length;
+  return {
+    convergence: total === 0 ? 100 : round((matched / total) * 100),
+    modules: {
+      matched: intended.size,
+      missing: missingModules,
+      unexpected: unexpectedModules,
+    },
+    edges: {
+      matched: allowed.size,
+      missing: missingEdges,
+      unexpected: unexpectedEdges,
+    },
+  };
+}
+
+/** The line of the conformance history `report` makes at `now`. */
+export function conformancePoint(
+  report: ConformanceReport,
+  now: Date = new Date(),
+): ConformancePoint {
+  return {
+    timestamp: now.toISOString(),
+    convergence: report.convergence,
+    missingModules: report.modules.missing.length,
+    unexpectedModules: report.modules.unexpected.length,
+    missingEdges: report.edges.missing.length,
+    unexpectedEdges: report.edges.unexpected.length,
+  };
+}
+
+/**
+ * Parse the conformance history at `historyPath`, oldest run first. A
+ * missing file is empty. Throws on a line that is not JSON, with its line
+ * number.
+ */
+export function readConformanceHistory(
+  historyPath: string,
+): readonly ConformancePoint[] {
+  if (!existsSync(historyPath)) return [];
+  const points: ConformancePoint[] = [];
+  const lines = readFileSync(historyPath, 'utf-8').split('\n');
+  lines.forEach((line, index) => {
+    if (line.trim() === '') return;
+    try {
+      points.push(JSON.parse(line) as ConformancePoint);
+    } catch {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid conformance history entry at ${historyPath}:${index + 1}`,
+      );
+    }
+  });
+  return points;
+}
+
+/** Append one run to the history, creating the file when needed. */
+export function appendConformancePoint(
+  historyPath: string,
+  point: ConformancePoint,
+): void {
+  mkdirSync(dirname(historyPath), { recursive: true });
+  appendFileSync(historyPath, `${JSON.stringify(point)}\n`, 'utf-8');
+}
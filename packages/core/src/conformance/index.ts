@@ -0,0 +1,16 @@
+export type {
+  TargetEdge,
+  TargetArchitecture,
+  ConformanceOptions,
+  UnexpectedModule,
+  UnexpectedEdge,
+  ConformanceReport,
+  ConformancePoint,
+} from './types.js';
+export {
+  appendConformancePoint,
+  checkConformance,
+  conformancePoint,
+  parseTargetArchitecture,
+  readConformanceHistory,
+} from './conformance.js';
@@ -0,0 +1,81 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for a target architecture of intended modules and edges and the delta between it and the built graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [conformance, architecture, target, drift, types, interface]
+ * context:
+ *   business_goal: Let architects describe the target architecture in a file reviewed like code
+ *   domain: conformance
+ */
+import type { DependencyKind } from '../graph/types.js';
+import type { EntityType } from '../types/entity.js';
+
+/** An edge the target architecture allows, by module name. */
+export interface TargetEdge {
+  readonly from: string;
+  readonly to: string;
+}
+
+/** The intended modules and the edges allowed between them. */
+export interface TargetArchitecture {
+  /** Names of the modules, services, and externals that should exist. */
+  readonly modules: readonly string[];
+  readonly edges: readonly TargetEdge[];
+}
+
+export interface ConformanceOptions {
+  /** Entity types that are modules. Default module and service. */
+  readonly types?: readonly EntityType[];
+}
+
+/** A built module the target architecture does not name. */
+export interface UnexpectedModule {
+  readonly id: string;
+  readonly name: string;
+  readonly entityType: EntityType | null;
+  readonly filePath: string | null;
+  readonly owner: string | null;
+}
+
+/** Built edges from a module the target architecture does not allow. */
+export interface UnexpectedEdge {
+  /** The module, by name. */
+  readonly from: string;
+  /** The module or external it depends on, by name. */
+  readonly to: string;
+  /** The kinds of the built edges between the two, such as `service`. */
+  readonly kinds: readonly DependencyKind[];
+}
+
+export interface ConformanceReport {
+  /**
+   * Matched modules and edges as a percentage of all those either built
+   * or intended; 100 when the graph is exactly the target.
+   */
+  readonly convergence: number;
+  readonly modules: {
+    readonly matched: number;
+    /** Intended modules with no node, by target name. */
+    readonly missing: readonly string[];
+    readonly unexpected: readonly UnexpectedModule[];
+  };
+  readonly edges: {
+    readonly matched: number;
+    /** Allowed edges nothing builds, by target name. */
+    readonly missing: readonly TargetEdge[];
+    readonly unexpected: readonly UnexpectedEdge[];
+  };
+}
+
+/** One recorded run, a line of the conformance history. */
+export interface ConformancePoint {
+  /** ISO 8601 time of the run. */
+  readonly timestamp: string;
+  readonly convergence: number;
+  readonly missingModules: number;
+  readonly unexpectedModules: number;
+  readonly missingEdges: number;
+  readonly unexpectedEdges: number;
+}
@@ -5,7 +5,7 @@ import {
   extractJiraIssueKey,
 } from '../jira-connector.js';
 import type { JiraApiClient, JiraIssue } from '../jira-connector.js';
-import type { ConnectorConfig } from '../../types/manifest.js';
+import type { ConnectorConfig } from '../../types/manifest-indexing.js';
 import { createDatabaseManager } from '../../indexer/database.js';
 import type { DatabaseManager } from '../../indexer/database.js';
 import type { CoreMetadata, Link } from '../../types/index.js';
@@ -5,7 +5,7 @@ import {
   extractNotionPageId,
 } from '../notion-connector.js';
 import type { NotionApiClient, NotionPage } from '../notion-connector.js';
-import type { ConnectorConfig } from '../../types/manifest.js';
+import type { ConnectorConfig } from '../../types/manifest-indexing.js';
 import { createDatabaseManager } from '../../indexer/database.js';
 import type { DatabaseManager } from '../../indexer/database.js';
 import type { CoreMetadata, Link } from '../../types/index.js';
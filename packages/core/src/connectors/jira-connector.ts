@@ -9,7 +9,7 @@
  *   business_goal: Enrich code entities with linked Jira issue metadata
  *   domain: connectors
  */
-import type { ConnectorConfig } from '../types/manifest.js';
+import type { ConnectorConfig } from '../types/manifest-indexing.js';
 import type { Link } from '../types/entity.js';
 import type { DatabaseManager } from '../indexer/database.js';
 import type { StoredEntity } from '../indexer/types.js';
@@ -12,7 +12,7 @@
 import { z } from 'zod';
 import type { DatabaseManager } from '../indexer/database.js';
 import type { LinkType } from '../types/entity.js';
-import type { ConnectorConfig } from '../types/manifest.js';
+import type { ConnectorConfig } from '../types/manifest-indexing.js';
 
 // --- Zod Schemas ---
 
@@ -54,7 +54,7 @@ export type ConnectorSyncResult = z.infer<typeof ConnectorSyncResultSchema>;
 
 // --- Re-export ConnectorConfig from manifest types ---
 
-export type { ConnectorConfig } from '../types/manifest.js';
+export type { ConnectorConfig } from '../types/manifest-indexing.js';
 
 // --- Interfaces ---
 
@@ -0,0 +1,205 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  contractEdges,
+  renderContractSkeleton,
+} from '../contract-skeleton.js';
+import {
+  checkContractCoverage,
+  findContractTests,
+  scanContractTests,
+} from '../contract-coverage.js';
+import { externalNode } from '../../graph/graph-builder.js';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  GraphEdge,
+  GraphNode,
+} from '../../graph/types.js';
+
+function node(id: string, overrides: Partial<GraphNode> = {}): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+function edge(
+  from: string,
+  to: string,
+  kind: DependencyKind = 'service',
+  confidence = 1,
+): GraphEdge {
+  return {
+    from,
+    to,
+    kind,
+    provenance: confidence < 1 ? 'call' : 'declared',
+    confidence,
+  };
+}
+
+const tokens = externalNode('service', 'token-service');
+
+const graph: DependencyGraph = {
+  nodes: [
+    node('LoginService'),
+    node('Checkout'),
+    node('Billing', { aliases: ['billing-api'] }),
+    tokens,
+    externalNode('database', 'postgres'),
+  ],
+  edges: [
+    edge('Checkout', 'Billing', 'service', 0.5),
+    edge('Checkout', 'Billing'),
+    edge('LoginService', tokens.id),
+    edge('LoginService', 'external:database:postgres', 'database'),
+    edge('Checkout', 'Checkout'),
+  ],
+};
+
+describe('contractEdges', () => {
+  it('pairs each consumer with the services it depends on', () => {
+    const edges = contractEdges(graph);
+    expect(
+      edges.map((e) => [e.consumer.name, e.provider.name, e.provenance]),
+    ).toEqual([
+      ['Checkout', 'Billing', 'declared'],
+      ['LoginService', 'token-service', 'declared'],
+    ]);
+  });
+});
+
+describe('renderContractSkeleton', () => {
+  const [, login] = contractEdges(graph);
+
+  it('writes a Pact consumer test', () => {
+    const skeleton = renderContractSkeleton(login, 'pact');
+    expect(skeleton.fileName).toBe('login-service.token-service.pact.test.ts');
+    expect(skeleton.content).toContain(
+      '// knowgraph:contract LoginService -> token-service',
+    );
+    expect(skeleton.content).toContain("consumer: 'LoginService',");
+    expect(skeleton.content).toContain("provider: 'token-service',");
+  });
+
+  it('writes a plain Go test', () => {
+    const skeleton = renderContractSkeleton(login, 'go');
+    expect(skeleton.fileName).toBe(
+      'login_service_token_service_contract_test.go',
+    );
+    expect(skeleton.content).toContain('package contracts');
+    expect(skeleton.content).toContain(
+      'func TestLoginServiceTokenServiceContract(t *testing.T) {',
+    );
+  });
+
+  it('writes skeletons the coverage scan recognizes', () => {
+    for (const framework of ['pact', 'go'] as const) {
+      const skeleton = renderContractSkeleton(login, framework);
+      expect(findContractTests(skeleton.fileName, skeleton.content)).toEqual([
+        {
+          consumer: 'LoginService',
+          provider: 'token-service',
+          filePath: skeleton.fileName,
+          source: 'marker',
+        },
+      ]);
+    }
+  });
+});
+
+describe('findContractTests', () => {
+  it('reads pact files and Pact tests', () => {
+    const pact = JSON.stringify({
+      consumer: { name: 'checkout' },
+      provider: { name: 'billing-api' },
+      interactions: [],
+    });
+    expect(findContractTests('pacts/checkout-billing.json', pact)).toEqual([
+      {
+        consumer: 'checkout',
+        provider: 'billing-api',
+        filePath: 'pacts/checkout-billing.json',
+        source: 'pact-file',
+      },
+    ]);
+    const test = [
+      "import { PactV3 } from '@pact-foundation/pact';",
+      "new PactV3({ consumer: 'web', provider: \"LoginService\" });",
+    ].join('\n');
+    expect(findContractTests('web.pact.test.ts', test)).toMatchObject([
+      { consumer: 'web', provider: 'LoginService', source: 'pact-test' },
+    ]);
+    expect(findContractTests('package.json', '{"name": "pact"}')).toEqual([]);
+  });
+});
+
+describe('checkContractCoverage', () => {
+  it('reports dependencies without a contract test first', () => {
+    const report = checkContractCoverage(graph, [
+      {
+        consumer: 'checkout',
+        provider: 'billing-api',
+        filePath: 'pacts/checkout-billing.json',
+        source: 'pact-file',
+      },
+      {
+        consumer: 'web',
+        provider: 'LoginService',
+        filePath: 'web.pact.test.ts',
+        source: 'pact-test',
+      },
+    ]);
+    expect(report.covered).toBe(1);
+    expect(report.uncovered).toBe(1);
+    expect(report.edges.map((c) => c.edge.consumer.name)).toEqual([
+      'LoginService',
+      'Checkout',
+    ]);
+    expect(report.orphaned.map((test) => test.consumer)).toEqual(['web']);
+  });
+});
+
+describe('scanContractTests', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-contracts-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('finds contracts in the files an index would walk', () => {
+    mkdirSync(join(dir, 'contracts'));
+    mkdirSync(join(dir, 'node_modules'));
+    writeFileSync(
+      join(dir, 'contracts', 'login_test.go'),
+      '// knowgraph:contract LoginService -> token-service\npackage c\n',
+    );
+    writeFileSync(
+      join(dir, 'node_modules', 'vendored.test.ts'),
+      '// knowgraph:contract Checkout -> Billing\n',
+    );
+    writeFileSync(join(dir, 'README.md'), 'knowgraph:contract a -> b\n');
+    expect(scanContractTests(dir)).toEqual([
+      {
+        consumer: 'LoginService',
+        provider: 'token-service',
+        filePath: 'contracts/login_test.go',
+        source: 'marker',
+      },
+    ]);
+  });
+});
@@ -0,0 +1,167 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Finds the contract tests in a repository, from pact files, Pact tests, and knowgraph:contract markers, and reports service dependencies without one
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [contracts, pact, testing, coverage, graph]
+ * context:
+ *   business_goal: Show which service dependencies nothing verifies, so contract testing goes where it is missing
+ *   domain: graph
+ */
+import { existsSync, readFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { listIndexableFiles } from '../indexer/indexer.js';
+import type { DependencyGraph, GraphNode } from '../graph/types.js';
+import { CONTRACT_MARKER, contractEdges } from './contract-skeleton.js';
+import type {
+  ContractCoverage,
+  ContractReport,
+  ContractTest,
+} from './types.js';
+
+const CONTRACT_FILE_PATTERN = /\.(json|[cm]?[jt]sx?|go|py|java|kt)$/;
+
+const MARKER_PATTERN = new RegExp(
+  `${CONTRACT_MARKER}\\s+(\\S+)\\s*->\\s*(\\S+)`,
+  'g',
+);
+
+const PACT_OPTION_PATTERN = /\b(consumer|provider)\s*:\s*(['"`])([^'"`]+)\2/g;
+
+function pactParticipant(value: unknown): string | undefined {
+  if (typeof value !== 'object' || value === null) return undefined;
+  const { name } = value as Record<string, unknown>;
+  return typeof name === 'string' && name !== '' ? name : undefined;
+}
+
+function readPactFile(
+  filePath: string,
+  content: string,
+): ContractTest | undefined {
+  let parsed: unknown;
+  try {
+    parsed = JSON.parse(content);
+  } catch {
+    return undefined;
+  }
+  if (typeof parsed !== 'object' || parsed === null) return undefined;
+  const pact = parsed as Record<string, unknown>;
+  const consumer = pactParticipant(pact.consumer);
+  const provider = pactParticipant(pact.provider);
+  if (!consumer || !provider || !('interactions' in pact)) return undefined;
+  return { consumer, provider, filePath, source: 'pact-file' };
+}
+
+/**
+ * The contracts `content` of `filePath` holds: the consumer and provider
+ * of a pact file (JSON with `consumer.name`, `provider.name`, and
+ * `interactions`), every `knowgraph:contract <consumer> -> <provider>`
+ * marker, or else the `consumer` and `provider` options of a Pact test.
+ */
+export function findContractTests(
+  filePath: string,
+  content: string,
+): readonly ContractTest[] {
+  if (filePath.endsWith('.json')) {
+    const pact = readPactFile(filePath, content);
+    return pact ? [pact] : [];
+  }
+  const tests = new Map<string, ContractTest>();
+  for (const [, consumer, provider] of content.matchAll(MARKER_PATTERN)) {
+    tests.set(`${consumer}\0${provider}`, {
+      consumer,
+      provider,
+      filePath,
+      source: 'marker',
+    });
+  }
+  if (tests.size > 0 || !/\bPact(V\d)?\b/.test(content)) {
+    return [...tests.values()];
+  }
+  const options: Record<string, string> = {};
+  for (const [, key, , value] of content.matchAll(PACT_OPTION_PATTERN)) {
+    options[key] ??= value;
+  }
+  return options.consumer && options.provider
+    ? [
+        {
+          consumer: options.consumer,
+          provider: options.provider,
+          filePath,
+          source: 'pact-test',
+        },
+      ]
+    : [];
+}
+
+/**
+ * The contract tests under `rootDir` (see `findContractTests`), in the
+ * source, test, and JSON files an index would walk: `.gitignore` and
+ * `exclude` apply. Throws an `io` error when `rootDir` does not exist.
+ */
+export function scanContractTests(
+  rootDir: string,
+  exclude?: readonly string[],
+): readonly ContractTest[] {
+  if (!existsSync(rootDir)) {
+    throw createKnowgraphError('io', `Path not found: ${rootDir}`);
+  }
+  const adapter = {
+    parse: () => [],
+    canParse: (filePath: string) => CONTRACT_FILE_PATTERN.test(filePath),
+  };
+  return listIndexableFiles(rootDir, adapter, exclude).flatMap((filePath) => {
+    const content = readFileSync(join(rootDir, filePath), 'utf-8');
+    // Spare parsing every lockfile and fixture that cannot be a pact
+    if (filePath.endsWith('.json') && !content.includes('"interactions"')) {
+      return [];
+    }
+    return findContractTests(filePath, content);
+  });
+}
+
+/** Whether `name` is the node's id, name, or one of its aliases. */
+function isNamed(node: GraphNode, name: string): boolean {
+  const key = name.toLowerCase();
+  return [node.id, node.name, ...(node.aliases ?? [])].some(
+    (candidate) => candidate.toLowerCase() === key,
+  );
+}
+
+/**
+ * Which service dependencies of `graph` (see `contractEdges`) have a
+ * contract test among `tests`: one whose consumer and provider name the
+ * dependent and the dependency, by id, name, or alias, case-insensitively.
+ */
+export function checkContractCoverage(
+  graph: DependencyGraph,
+  tests: readonly ContractTest[],
+): ContractReport {
+  const matched = new Set<ContractTest>();
+  const edges: ContractCoverage[] = contractEdges(graph).map((edge) => {
+    const found = tests.filter(
+      (test) =>
+        isNamed(edge.consumer, test.consumer) &&
+        isNamed(edge.provider, test.provider),
+    );
+    for (const test of found) matched.add(test);
+    return { edge, tests: found };
+  });
+  const uncovered = edges.filter((coverage) => coverage.tests.length === 0);
+  const covered = edges.filter((coverage) => coverage.tests.length > 0);
+  return {
+    edges: [...uncovered, ...covered],
+    covered: covered.length,
+    uncovered: uncovered.length,
+    orphaned: tests
+      .filter((test) => !matched.has(test))
+      .sort(
+        (a, b) =>
+          compareStrings(a.filePath, b.filePath) ||
+          compareStrings(a.consumer, b.consumer),
+      ),
+  };
+}
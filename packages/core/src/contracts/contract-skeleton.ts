@@ -0,0 +1,169 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Lists the service dependencies of a graph as consumer/provider pairs and writes Pact or Go contract test skeletons for them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [contracts, pact, go, testing, codegen]
+ * context:
+ *   business_goal: Make starting a contract test cheaper than skipping one
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { goIdentifier, nameWords } from '../codegen/go-names.js';
+import type { DependencyGraph, GraphEdge } from '../graph/types.js';
+import type {
+  ContractEdge,
+  ContractFramework,
+  ContractSkeleton,
+} from './types.js';
+
+/**
+ * The comment that ties a test to the dependency it verifies, as
+ * `knowgraph:contract <consumer> -> <provider>`. Skeletons carry it, and
+ * hand-written tests can add it.
+ */
+export const CONTRACT_MARKER = 'knowgraph:contract';
+
+/**
+ * The service dependencies of `graph` a contract test can verify: one per
+ * consumer and provider, from the consumer's most confident edge, sorted
+ * by consumer then provider name. Consumers are nodes in the graph;
+ * providers may be external services.
+ */
+export function contractEdges(graph: DependencyGraph): readonly ContractEdge[] {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const best = new Map<string, GraphEdge>();
+  for (const edge of graph.edges) {
+    if (edge.kind !== 'service' || edge.from === edge.to) continue;
+    const key = `${edge.from}\0${edge.to}`;
+    const current = best.get(key);
+    if (!current || edge.confidence > current.confidence) best.set(key, edge);
+  }
+  const edges: ContractEdge[] = [];
+  for (const edge of best.values()) {
+    const consumer = nodes.get(edge.from);
+    const provider = nodes.get(edge.to);
+    if (!consumer || !provider || consumer.external) continue;
+    edges.push({ consumer, provider, provenance: edge.provenance });
+  }
+  return edges.sort(
+    (a, b) =>
+      compareStrings(a.consumer.name, b.consumer.name) ||
+      compareStrings(a.provider.name, b.provider.name) ||
+      compareStrings(a.consumer.id, b.consumer.id) ||
+      compareStrings(a.provider.id, b.provider.id),
+  );
+}
+
+function tsString(text: string): string {
+  return `'${text.replace(/\\/g, '\\\\').replace(/'/g, "\\'")}'`;
+}
+
+function pactSkeleton(edge: ContractEdge): string {
+  const consumer = edge.consumer.name;
+  const provider = edge.provider.name;
+  return [
+    `// ${CONTRACT_MARKER} ${consumer} -> ${provider}`,
+    `// Generated by knowgraph contracts generate from a ${edge.provenance} dependency.`,
+    '// Replace each TODO with an interaction the consumer relies on.',
+    "import { MatchersV3, PactV3 } from '@pact-foundation/pact';",
+    "import { describe, it } from 'vitest';",
+    '',
+    'const pact = new PactV3({',
+    `  consumer: ${tsString(consumer)},`,
+    `  provider: ${tsString(provider)},`,
+    '});',
+    '',
+    `describe(${tsString(`${consumer} -> ${provider} contract`)}, () => {`,
+    `  it(${tsString(`TODO: an interaction ${consumer} relies on`)}, () => {`,
+    '    pact',
+    "      .given('TODO: the provider state the interaction needs')",
+    `      .uponReceiving(${tsString(`TODO: a request ${consumer} sends`)})`,
+    "      .withRequest({ method: 'GET', path: '/TODO' })",
+    '      .willRespondWith({ status: 200, body: MatchersV3.like({}) });',
+    '',
+    '    return pact.executeTest(async (mockServer) => {',
+    `      // TODO: call ${consumer}'s ${provider} client at mockServer.url`,
+    '      // and assert on what it makes of the response.',
+    '    });',
+    '  });',
+    '});',
+    '',
+  ].join('\n');
+}
+
+function goSkeleton(edge: ContractEdge): string {
+  const consumer = edge.consumer.name;
+  const provider = edge.provider.name;
+  const test = `Test${goIdentifier(consumer)}${goIdentifier(provider)}Contract`;
+  return [
+    `// ${CONTRACT_MARKER} ${consumer} -> ${provider}`,
+    `// Generated by knowgraph contracts generate from a ${edge.provenance} dependency.`,
+    '',
+    'package contracts',
+    '',
+    'import (',
+    '\t"net/http"',
+    '\t"net/http/httptest"',
+    '\t"testing"',
+    ')',
+    '',
+    `// ${test} pins down what ${consumer} relies on from`,
+    `// ${provider}: the consumer half runs ${consumer}'s client against a`,
+    '// recorded response, and the provider half replays the request against',
+    `// ${provider}'s handler.`,
+    `func ${test}(t *testing.T) {`,
+    `\tt.Skip(${JSON.stringify(`TODO: write the contract ${consumer} relies on from ${provider}`)})`,
+    '',
+    '\tt.Run("consumer", func(t *testing.T) {',
+    '\t\tserver := httptest.NewServer(http.HandlerFunc(',
+    '\t\t\tfunc(w http.ResponseWriter, r *http.Request) {',
+    '\t\t\t\t// TODO: assert on the request and write the recorded response.',
+    '\t\t\t\tw.WriteHeader(http.StatusOK)',
+    '\t\t\t},',
+    '\t\t))',
+    '\t\tdefer server.Close()',
+    `\t\t// TODO: call ${consumer}'s ${provider} client at server.URL.`,
+    '\t})',
+    '',
+    '\tt.Run("provider", func(t *testing.T) {',
+    '\t\t// TODO: replay the request against the provider handler.',
+    '\t\trecorder := httptest.NewRecorder()',
+    '\t\tif recorder.Code != http.StatusOK {',
+    '\t\t\tt.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)',
+    '\t\t}',
+    '\t})',
+    '}',
+    '',
+  ].join('\n');
+}
+
+/**
+ * A contract test skeleton for `edge`: a Pact consumer test in
+ * TypeScript, whose run writes the pact the provider verifies, or a plain
+ * Go test with a consumer and a provider half. Both start with the
+ * `knowgraph:contract` marker, so the dependency counts as covered once
+ * the file is in the repository.
+ */
+export function renderContractSkeleton(
+  edge: ContractEdge,
+  framework: ContractFramework,
+): ContractSkeleton {
+  const consumer = nameWords(edge.consumer.name);
+  const provider = nameWords(edge.provider.name);
+  if (framework === 'go') {
+    return {
+      edge,
+      framework,
+      fileName: `${[...consumer, ...provider].join('_')}_contract_test.go`,
+      content: goSkeleton(edge),
+    };
+  }
+  return {
+    edge,
+    framework,
+    fileName: `${consumer.join('-')}.${provider.join('-')}.pact.test.ts`,
+    content: pactSkeleton(edge),
+  };
+}
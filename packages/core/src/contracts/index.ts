@@ -0,0 +1,19 @@
+export type {
+  ContractCoverage,
+  ContractEdge,
+  ContractFramework,
+  ContractReport,
+  ContractSkeleton,
+  ContractSource,
+  ContractTest,
+} from './types.js';
+export {
+  CONTRACT_MARKER,
+  contractEdges,
+  renderContractSkeleton,
+} from './contract-skeleton.js';
+export {
+  checkContractCoverage,
+  findContractTests,
+  scanContractTests,
+} from './contract-coverage.js';
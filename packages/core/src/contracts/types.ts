@@ -0,0 +1,61 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for generating consumer/provider contract test skeletons from service dependencies and finding which have contract tests
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [contracts, pact, testing, graph, types, interface]
+ * context:
+ *   business_goal: Let CI report contract test coverage of service dependencies as data
+ *   domain: graph
+ */
+import type { EdgeProvenance, GraphNode } from '../graph/types.js';
+
+/** Pact tests in TypeScript, or plain Go tests with `net/http/httptest`. */
+export type ContractFramework = 'pact' | 'go';
+
+/** A service dependency a contract test can verify. */
+export interface ContractEdge {
+  readonly consumer: GraphNode;
+  readonly provider: GraphNode;
+  /** How the most confident edge between them was derived. */
+  readonly provenance: EdgeProvenance;
+}
+
+export interface ContractSkeleton {
+  readonly edge: ContractEdge;
+  readonly framework: ContractFramework;
+  /** Relative to the directory skeletons are written to. */
+  readonly fileName: string;
+  readonly content: string;
+}
+
+/**
+ * Where a contract was found: a pact file a Pact run wrote, a Pact test
+ * that names its consumer and provider, or a `knowgraph:contract` marker.
+ */
+export type ContractSource = 'pact-file' | 'pact-test' | 'marker';
+
+/** A contract test found in the repository, by the names it gives. */
+export interface ContractTest {
+  readonly consumer: string;
+  readonly provider: string;
+  /** Relative to the scanned directory, with `/` separators. */
+  readonly filePath: string;
+  readonly source: ContractSource;
+}
+
+export interface ContractCoverage {
+  readonly edge: ContractEdge;
+  /** The contract tests of this consumer and provider; none if uncovered. */
+  readonly tests: readonly ContractTest[];
+}
+
+export interface ContractReport {
+  /** Every service dependency, uncovered first, then by consumer name. */
+  readonly edges: readonly ContractCoverage[];
+  readonly covered: number;
+  readonly uncovered: number;
+  /** Contract tests whose consumer and provider match no dependency. */
+  readonly orphaned: readonly ContractTest[];
+}
@@ -1,5 +1,7 @@
-import { describe, it, expect } from 'vitest';
-import { resolve } from 'node:path';
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import { tmpdir } from 'node:os';
 import { calculateCoverage } from '../coverage-calculator.js';
 
 const FIXTURES_DIR = resolve(__dirname, 'fixtures', 'mixed-project');
@@ -103,3 +105,76 @@ describe('calculateCoverage', () => {
     }
   });
 });
+
+describe('calculateCoverage with generated files', () => {
+  let dir: string;
+
+  const GENERATOR = `/**
+ * @knowgraph
+ * type: function
+ * description: Generates the typed API client from the OpenAPI spec
+ * owner: api-team
+ * generates: [src/generated/]
+ */
+export function generateClient(): void {}
+`;
+
+  const MARKER = '// Code generated by openapi-gen. DO NOT EDIT.\n';
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-coverage-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(join(dir, 'scripts'), { recursive: true });
+    mkdirSync(join(dir, 'src', 'generated'), { recursive: true });
+    writeFileSync(join(dir, 'scripts', 'gen-client.ts'), GENERATOR);
+    writeFileSync(
+      join(dir, 'src', 'generated', 'client.ts'),
+      `${MARKER}export const client = {};\n`,
+    );
+    writeFileSync(
+      join(dir, 'src', 'generated', 'models.ts'),
+      `${MARKER}export interface User {}\n`,
+    );
+    writeFileSync(
+      join(dir, 'src', 'schema.gen.ts'),
+      `${MARKER}export const schema = {};\n`,
+    );
+    writeFileSync(join(dir, 'src', 'plain.ts'), 'export const x = 1;\n');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('credits generated files to the matching generator', () => {
+    const result = calculateCoverage({ rootDir: dir });
+    expect(result.totalFiles).toBe(5);
+    expect(result.annotatedFiles).toBe(3);
+    expect(result.generatedFiles).toBe(3);
+
+    const client = result.files.find((f) =>
+      f.filePath.endsWith('client.ts'),
+    );
+    expect(client?.hasAnnotation).toBe(true);
+    expect(client?.generatedBy).toEqual({
+      name: 'generateClient',
+      filePath: join('scripts', 'gen-client.ts'),
+      owner: 'api-team',
+    });
+  });
+
+  it('reports generated files with no generator as uncovered', () => {
+    const result = calculateCoverage({ rootDir: dir });
+    expect(result.unlinkedGeneratedFiles).toEqual([
+      join('src', 'schema.gen.ts'),
+    ]);
+  });
+
+  it('attributes linked generated files to the generator owner', () => {
+    const result = calculateCoverage({ rootDir: dir });
+    const apiTeam = result.byOwner.find((b) => b.category === 'api-team');
+    expect(apiTeam).toMatchObject({ annotatedCount: 3, totalCount: 3 });
+  });
+});
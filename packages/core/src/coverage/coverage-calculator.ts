@@ -12,7 +12,14 @@
 import { readFileSync, readdirSync, statSync } from 'node:fs';
 import { join, extname, relative, dirname } from 'node:path';
 import { createDefaultRegistry } from '../parsers/registry.js';
+import { toPosixPath } from '../paths/paths.js';
 import type { ParserRegistry } from '../parsers/types.js';
+import type { ParseResult } from '../types/parse-result.js';
+import {
+  detectGeneratedCode,
+  findGenerator,
+  toGeneratorNode,
+} from '../generated/generated-code.js';
 import type {
   CoverageBreakdown,
   CoverageOptions,
@@ -89,34 +96,82 @@ function collectParseableFiles(
   return results;
 }
 
+interface AnalyzedFile {
+  readonly info: FileCoverageInfo;
+  readonly results: readonly ParseResult[];
+  readonly generated: boolean;
+}
+
 function analyzeFile(
   filePath: string,
   rootDir: string,
   registry: ParserRegistry,
-): FileCoverageInfo {
+): AnalyzedFile {
   const ext = extname(filePath);
   const language = EXTENSION_TO_LANGUAGE[ext] ?? 'unknown';
-  const relPath = relative(rootDir, filePath);
+  const relPath = toPosixPath(relative(rootDir, filePath));
 
   try {
     const content = readFileSync(filePath, 'utf-8');
     const { results } = registry.parseFile(content, filePath);
     return {
-      filePath: relPath,
-      language,
-      hasAnnotation: results.length > 0,
-      entityCount: results.length,
+      info: {
+        filePath: relPath,
+        language,
+        hasAnnotation: results.length > 0,
+        entityCount: results.length,
+      },
+      results,
+      generated: detectGeneratedCode(content) !== undefined,
     };
   } catch {
     return {
-      filePath: relPath,
-      language,
-      hasAnnotation: false,
-      entityCount: 0,
+      info: {
+        filePath: relPath,
+        language,
+        hasAnnotation: false,
+        entityCount: 0,
+      },
+      results: [],
+      generated: false,
     };
   }
 }
 
+/**
+ * Credit unannotated generated files to the generator whose `generates`
+ * patterns match them. Generated files that carry their own annotations
+ * keep them; unmatched ones stay uncovered.
+ */
+function linkGeneratedFiles(
+  analyzed: readonly AnalyzedFile[],
+): readonly FileCoverageInfo[] {
+  const generators = analyzed.flatMap(({ info, results }) =>
+    results.flatMap((result) => {
+      const node = toGeneratorNode(result, info.filePath);
+      return node ? [node] : [];
+    }),
+  );
+
+  return analyzed.map(({ info, generated }) => {
+    if (!generated) return info;
+    const generator = info.hasAnnotation
+      ? undefined
+      : findGenerator(info.filePath, generators);
+    if (!generator) return { ...info, generated: true };
+    return {
+      ...info,
+      hasAnnotation: true,
+      generated: true,
+      generatedBy: {
+        name: generator.name,
+        filePath: generator.filePath,
+        owner: generator.metadata.owner,
+      },
+    };
+  });
+}
+
 function computePercentage(annotated: number, total: number): number {
   if (total === 0) return 0;
   return Math.round((annotated / total) * 1000) / 10;
@@ -161,6 +216,19 @@ function buildOwnerBreakdown(
   >();
 
   for (const file of files) {
+    if (file.generatedBy) {
+      const owner = file.generatedBy.owner ?? '(no owner)';
+      const existing = ownerMap.get(owner) ?? {
+        annotatedCount: 0,
+        totalCount: 0,
+      };
+      ownerMap.set(owner, {
+        annotatedCount: existing.annotatedCount + 1,
+        totalCount: existing.totalCount + 1,
+      });
+      continue;
+    }
+
     if (!file.hasAnnotation) {
       const key = '(no owner)';
       const existing = ownerMap.get(key) ?? {
@@ -221,8 +289,8 @@ export function calculateCoverage(options: CoverageOptions): CoverageResult {
   const registry = createDefaultRegistry();
   const filePaths = collectParseableFiles(rootDir, excludeSet);
 
-  const files: readonly FileCoverageInfo[] = filePaths.map((fp) =>
-    analyzeFile(fp, rootDir, registry),
+  const files = linkGeneratedFiles(
+    filePaths.map((fp) => analyzeFile(fp, rootDir, registry)),
   );
 
   const annotatedFiles = files.filter((f) => f.hasAnnotation).length;
@@ -243,5 +311,9 @@ export function calculateCoverage(options: CoverageOptions): CoverageResult {
     byDirectory,
     byOwner,
     files,
+    generatedFiles: files.filter((f) => f.generated).length,
+    unlinkedGeneratedFiles: files
+      .filter((f) => f.generated && !f.hasAnnotation)
+      .map((f) => f.filePath),
   };
 }
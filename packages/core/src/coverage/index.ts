@@ -4,4 +4,5 @@ export type {
   CoverageOptions,
   CoverageResult,
   FileCoverageInfo,
+  GeneratorLink,
 } from './types.js';
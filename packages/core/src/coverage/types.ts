@@ -10,11 +10,21 @@
  *   domain: coverage-engine
  */
 
+export interface GeneratorLink {
+  readonly name: string;
+  readonly filePath: string;
+  readonly owner?: string;
+}
+
 export interface FileCoverageInfo {
   readonly filePath: string;
   readonly language: string;
   readonly hasAnnotation: boolean;
   readonly entityCount: number;
+  /** True for files marked `Code generated ... DO NOT EDIT`. */
+  readonly generated?: boolean;
+  /** Generator whose annotation covers this generated file. */
+  readonly generatedBy?: GeneratorLink;
 }
 
 export interface CoverageBreakdown {
@@ -32,6 +42,9 @@ export interface CoverageResult {
   readonly byDirectory: readonly CoverageBreakdown[];
   readonly byOwner: readonly CoverageBreakdown[];
   readonly files: readonly FileCoverageInfo[];
+  readonly generatedFiles: number;
+  /** Generated files with no annotation and no matching generator. */
+  readonly unlinkedGeneratedFiles: readonly string[];
 }
 
 export interface CoverageOptions {
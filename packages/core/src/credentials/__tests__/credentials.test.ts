@@ -0,0 +1,236 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  cachedToken,
+  createMtlsCredentials,
+  createTokenCredentials,
+  credentialsFor,
+  jwtExpiry,
+} from '../credentials.js';
+import { createCloudCredentials } from '../cloud.js';
+import { createOidcDeviceCredentials } from '../oidc.js';
+
+/** An unsigned JWT expiring at `exp` seconds. */
+function jwt(exp: number): string {
+  const part = (value: object): string =>
+    Buffer.from(JSON.stringify(value)).toString('base64url');
+  return `${part({ alg: 'none' })}.${part({ exp })}.`;
+}
+
+function json(body: unknown, status = 200): Response {
+  return new Response(JSON.stringify(body), { status });
+}
+
+const NOW = Date.UTC(2026, 9, 15);
+
+describe('credentials', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-credentials-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    vi.unstubAllGlobals();
+  });
+
+  it('picks the provider with the longest matching URL prefix', async () => {
+    const registry = createTokenCredentials('registry');
+    const admin = createTokenCredentials('admin');
+    const tokens = 'https://registry.example.com/registry/v1/tokens';
+    const scoped = [
+      { url: 'https://registry.example.com/', provider: registry },
+      { url: tokens, provider: admin },
+    ];
+    expect(credentialsFor(scoped, `${tokens}/ci`)).toBe(admin);
+    expect(credentialsFor(scoped, 'https://registry.example.com/x')).toBe(
+      registry,
+    );
+    expect(credentialsFor(scoped, 'https://hooks.slack.com/x')).toBeUndefined();
+    expect(await registry.credentials()).toEqual({
+      headers: { Authorization: 'Bearer registry' },
+    });
+  });
+
+  it('reuses a token until shortly before it expires', async () => {
+    let now = NOW;
+    const fetchToken = vi.fn(async () => ({
+      token: `t${fetchToken.mock.calls.length}`,
+      expiresAt: now + 120_000,
+    }));
+    const token = cachedToken(fetchToken, () => now);
+    expect(await Promise.all([token(), token()])).toEqual(['t1', 't1']);
+    now += 30_000;
+    expect(await token()).toBe('t1');
+    now += 40_000;
+    expect(await token()).toBe('t2');
+    expect(jwtExpiry(jwt(1_800_000_000))).toBe(1_800_000_000_000);
+    expect(jwtExpiry('opaque')).toBeUndefined();
+  });
+
+  it('reads client certificate files on first use', async () => {
+    writeFileSync(join(dir, 'client.pem'), 'CERT');
+    writeFileSync(join(dir, 'client-key.pem'), 'KEY');
+    const provider = createMtlsCredentials({
+      certPath: join(dir, 'client.pem'),
+      keyPath: join(dir, 'client-key.pem'),
+    });
+    expect(await provider.credentials()).toEqual({
+      headers: {},
+      tls: { cert: 'CERT', key: 'KEY', ca: undefined, passphrase: undefined },
+    });
+    await expect(
+      createMtlsCredentials({
+        certPath: join(dir, 'client.pem'),
+        keyPath: join(dir, 'missing.pem'),
+      }).credentials(),
+    ).rejects.toMatchObject({ kind: 'io' });
+  });
+
+  it('signs in with the device flow once and caches the token', async () => {
+    const fetchMock = vi.fn(async (url: string, init?: RequestInit) => {
+      if (url.endsWith('/.well-known/openid-configuration')) {
+        return json({
+          device_authorization_endpoint: 'https://id.example.com/device',
+          token_endpoint: 'https://id.example.com/token',
+        });
+      }
+      if (url === 'https://id.example.com/device') {
+        return json({
+          device_code: 'dev',
+          user_code: 'ABCD-EFGH',
+          verification_uri: 'https://id.example.com/activate',
+          interval: 1,
+        });
+      }
+      const polls = fetchMock.mock.calls.filter(([u]) => u === url).length;
+      expect(String(init?.body)).toContain('device_code=dev');
+      return polls === 1
+        ? json({ error: 'authorization_pending' }, 400)
+        : json({ access_token: 'oidc-token', expires_in: 3600 });
+    });
+    vi.stubGlobal('fetch', fetchMock);
+    const prompt = vi.fn();
+    const sleep = vi.fn(async () => {});
+    const options = {
+      issuer: 'https://id.example.com/',
+      clientId: 'knowgraph',
+      cachePath: join(dir, 'tokens.json'),
+      prompt,
+      sleep,
+      now: () => NOW,
+    };
+
+    const provider = createOidcDeviceCredentials(options);
+    expect(await provider.credentials()).toEqual({
+      headers: { Authorization: 'Bearer oidc-token' },
+    });
+    expect(prompt).toHaveBeenCalledWith(
+      expect.objectContaining({ userCode: 'ABCD-EFGH' }),
+    );
+    expect(sleep).toHaveBeenCalledTimes(2);
+    expect(
+      JSON.parse(readFileSync(join(dir, 'tokens.json'), 'utf-8')).expiresAt,
+    ).toBe(NOW + 3_600_000);
+
+    // A later run takes the cached token without asking again
+    fetchMock.mockClear();
+    await createOidcDeviceCredentials(options).credentials();
+    expect(fetchMock).not.toHaveBeenCalled();
+  });
+
+  it('fails the device flow when the user declines', async () => {
+    vi.stubGlobal(
+      'fetch',
+      vi.fn(async (url: string) =>
+        url.endsWith('openid-configuration')
+          ? json({
+              device_authorization_endpoint: 'https://id.example.com/device',
+              token_endpoint: 'https://id.example.com/token',
+            })
+          : url.endsWith('/device')
+            ? json({
+                device_code: 'dev',
+                user_code: 'X',
+                verification_uri: 'https://id.example.com/activate',
+              })
+            : json({ error: 'access_denied' }, 400),
+      ),
+    );
+    await expect(
+      createOidcDeviceCredentials({
+        issuer: 'https://id.example.com',
+        clientId: 'knowgraph',
+        prompt: () => {},
+        sleep: async () => {},
+      }).credentials(),
+    ).rejects.toThrow('OIDC sign-in failed: access_denied');
+  });
+
+  it('takes ambient identity from each cloud source', async () => {
+    const fetchMock = vi.fn(async (url: string) => {
+      if (url.includes('metadata.google.internal')) {
+        return new Response(jwt(NOW / 1000 + 3600));
+      }
+      if (url.startsWith('http://169.254.169.254')) {
+        return json({ access_token: 'azure', expires_in: '3599' });
+      }
+      return json({ value: 'github' });
+    });
+    vi.stubGlobal('fetch', fetchMock);
+
+    const gcp = createCloudCredentials({
+      source: 'gcp',
+      audience: 'https://registry.example.com',
+    });
+    expect((await gcp.credentials()).headers.Authorization).toBe(
+      `Bearer ${jwt(NOW / 1000 + 3600)}`,
+    );
+    expect(fetchMock.mock.calls[0][0]).toContain(
+      'identity?audience=https%3A%2F%2Fregistry.example.com&format=full',
+    );
+
+    const azure = createCloudCredentials({
+      source: 'azure',
+      audience: 'api://registry',
+    });
+    expect((await azure.credentials()).headers.Authorization).toBe(
+      'Bearer azure',
+    );
+    await expect(
+      createCloudCredentials({ source: 'azure' }).credentials(),
+    ).rejects.toMatchObject({ kind: 'usage' });
+
+    const github = createCloudCredentials({
+      source: 'github-actions',
+      audience: 'knowgraph',
+      env: {
+        ACTIONS_ID_TOKEN_REQUEST_URL: 'https://actions.example/token?v=1',
+        ACTIONS_ID_TOKEN_REQUEST_TOKEN: 'request',
+      },
+    });
+    expect((await github.credentials()).headers.Authorization).toBe(
+      'Bearer github',
+    );
+    expect(fetchMock.mock.calls.at(-1)).toEqual([
+      'https://actions.example/token?v=1&audience=knowgraph',
+      { headers: { Authorization: 'Bearer request' } },
+    ]);
+    await expect(
+      createCloudCredentials({ source: 'github-actions', env: {} })
+        .credentials(),
+    ).rejects.toThrow('id-token: write');
+
+    writeFileSync(join(dir, 'token'), 'projected\n');
+    const kubernetes = createCloudCredentials({
+      source: 'kubernetes',
+      env: { AWS_WEB_IDENTITY_TOKEN_FILE: join(dir, 'token') },
+    });
+    expect((await kubernetes.credentials()).headers.Authorization).toBe(
+      'Bearer projected',
+    );
+  });
+});
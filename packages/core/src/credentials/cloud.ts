@@ -0,0 +1,189 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Credentials provider for ambient cloud workload identity from the GCP and Azure metadata services, GitHub Actions OIDC, and projected Kubernetes tokens
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [credentials, auth, iam, workload-identity, gcp, azure, github-actions, kubernetes]
+ * context:
+ *   business_goal: Let workloads in the cloud authenticate with the identity they already run as
+ *   domain: credentials
+ */
+import { readFileSync } from 'node:fs';
+import { createKnowgraphError } from '../errors/errors.js';
+import { bearer, cachedToken, jwtExpiry } from './credentials.js';
+import type { ExpiringToken } from './credentials.js';
+import type {
+  CloudCredentialsOptions,
+  CloudIdentitySource,
+  CredentialsProvider,
+} from './types.js';
+
+const GCP_METADATA =
+  'http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default';
+
+const AZURE_IMDS =
+  'http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01';
+
+const KUBERNETES_TOKEN = '/var/run/secrets/kubernetes.io/serviceaccount/token';
+
+/** Tokens with no expiry of their own are fetched again after this. */
+const DEFAULT_LIFETIME_MS = 5 * 60_000;
+
+async function request(
+  source: CloudIdentitySource,
+  url: string,
+  headers: Readonly<Record<string, string>>,
+): Promise<Response> {
+  let response: Response;
+  try {
+    response = await fetch(url, { headers });
+  } catch (err) {
+    throw createKnowgraphError(
+      'io',
+      `Could not reach the ${source} identity endpoint: ${(err as Error).message}`,
+    );
+  }
+  if (!response.ok) {
+    throw createKnowgraphError(
+      'io',
+      `The ${source} identity endpoint answered ${response.status} ${response.statusText}`,
+    );
+  }
+  return response;
+}
+
+function expiring(token: string, now: number): ExpiringToken {
+  return { token, expiresAt: jwtExpiry(token) ?? now + DEFAULT_LIFETIME_MS };
+}
+
+function requireAudience(options: CloudCredentialsOptions): string {
+  if (options.audience === undefined) {
+    throw createKnowgraphError(
+      'usage',
+      `${options.source} workload identity needs an audience`,
+    );
+  }
+  return options.audience;
+}
+
+async function gcpToken(
+  options: CloudCredentialsOptions,
+  now: number,
+): Promise<ExpiringToken> {
+  const headers = { 'Metadata-Flavor': 'Google' };
+  if (options.audience !== undefined) {
+    const audience = encodeURIComponent(options.audience);
+    const response = await request(
+      'gcp',
+      `${GCP_METADATA}/identity?audience=${audience}&format=full`,
+      headers,
+    );
+    return expiring((await response.text()).trim(), now);
+  }
+  const response = await request('gcp', `${GCP_METADATA}/token`, headers);
+  const body = (await response.json()) as {
+    access_token: string;
+    expires_in: number;
+  };
+  return {
+    token: body.access_token,
+    expiresAt: now + body.expires_in * 1000,
+  };
+}
+
+async function azureToken(
+  options: CloudCredentialsOptions,
+  now: number,
+): Promise<ExpiringToken> {
+  const resource = encodeURIComponent(requireAudience(options));
+  const response = await request(
+    'azure',
+    `${AZURE_IMDS}&resource=${resource}`,
+    { Metadata: 'true' },
+  );
+  // IMDS sends expires_in as a string of seconds
+  const body = (await response.json()) as {
+    access_token: string;
+    expires_in: string;
+  };
+  return {
+    token: body.access_token,
+    expiresAt: now + Number(body.expires_in) * 1000,
+  };
+}
+
+async function githubActionsToken(
+  options: CloudCredentialsOptions,
+  now: number,
+): Promise<ExpiringToken> {
+  const env = options.env ?? process.env;
+  const url = env.ACTIONS_ID_TOKEN_REQUEST_URL;
+  const requestToken = env.ACTIONS_ID_TOKEN_REQUEST_TOKEN;
+  if (!url || !requestToken) {
+    throw createKnowgraphError(
+      'usage',
+      'GitHub Actions OIDC tokens are not available to this job; grant it `permissions: id-token: write`',
+    );
+  }
+  const audience =
+    options.audience === undefined
+      ? ''
+      : `&audience=${encodeURIComponent(options.audience)}`;
+  const response = await request('github-actions', `${url}${audience}`, {
+    Authorization: `Bearer ${requestToken}`,
+  });
+  const body = (await response.json()) as { value: string };
+  return expiring(body.value, now);
+}
+
+/**
+ * The projected token file, read again whenever the last one nears its
+ * expiry since the kubelet rotates it in place. EKS sets
+ * `AWS_WEB_IDENTITY_TOKEN_FILE` to the token it projects.
+ */
+function kubernetesToken(
+  options: CloudCredentialsOptions,
+  now: number,
+): ExpiringToken {
+  const env = options.env ?? process.env;
+  const path =
+    options.tokenPath ?? env.AWS_WEB_IDENTITY_TOKEN_FILE ?? KUBERNETES_TOKEN;
+  try {
+    return expiring(readFileSync(path, 'utf-8').trim(), now);
+  } catch (err) {
+    throw createKnowgraphError(
+      'io',
+      `Could not read the service account token at ${path}: ${(err as Error).message}`,
+    );
+  }
+}
+
+/**
+ * Credentials from the identity the workload already has where it runs,
+ * sent as a bearer token, so CI needs no stored secret. The registry must
+ * trust the identity's issuer. Throws a usage error when the source needs
+ * an audience or is not available, and an I/O error when its endpoint or
+ * token file cannot be reached.
+ */
+export function createCloudCredentials(
+  options: CloudCredentialsOptions,
+): CredentialsProvider {
+  const now = options.now ?? Date.now;
+  const token = cachedToken(async () => {
+    switch (options.source) {
+      case 'gcp':
+        return gcpToken(options, now());
+      case 'azure':
+        return azureToken(options, now());
+      case 'github-actions':
+        return githubActionsToken(options, now());
+      case 'kubernetes':
+        return kubernetesToken(options, now());
+    }
+  }, now);
+  return {
+    type: options.source,
+    credentials: async () => bearer(await token()),
+  };
+}
@@ -0,0 +1,129 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Static token and client certificate credentials providers, token caching with early refresh, and the choice of provider by request URL
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [credentials, auth, mtls, token, cache]
+ * context:
+ *   business_goal: Let each team authenticate to a central registry the way its CI environment allows, without long-lived shared secrets
+ *   domain: credentials
+ */
+import { readFileSync } from 'node:fs';
+import { createKnowgraphError } from '../errors/errors.js';
+import type {
+  ClientCertificate,
+  Credentials,
+  CredentialsProvider,
+  MtlsOptions,
+  ScopedCredentials,
+} from './types.js';
+
+/** Tokens are refreshed this long before they expire. */
+export const REFRESH_MARGIN_MS = 60_000;
+
+/** A token and when it stops working, in milliseconds since the epoch. */
+export interface ExpiringToken {
+  readonly token: string;
+  readonly expiresAt: number;
+}
+
+export function bearer(token: string): Credentials {
+  return { headers: { Authorization: `Bearer ${token}` } };
+}
+
+/**
+ * The `exp` of a JWT in milliseconds, or undefined when `token` is not a
+ * JWT or has none. The signature is the server's to check.
+ */
+export function jwtExpiry(token: string): number | undefined {
+  const payload = token.split('.')[1];
+  if (payload === undefined) return undefined;
+  try {
+    const claims = JSON.parse(
+      Buffer.from(payload, 'base64url').toString('utf-8'),
+    ) as { exp?: unknown };
+    return typeof claims.exp === 'number' ? claims.exp * 1000 : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Wrap `fetchToken` so its token is reused until shortly before it
+ * expires. Concurrent callers share one fetch.
+ */
+export function cachedToken(
+  fetchToken: () => Promise<ExpiringToken>,
+  now: () => number = Date.now,
+): () => Promise<string> {
+  let current: ExpiringToken | undefined;
+  let pending: Promise<ExpiringToken> | undefined;
+  return async () => {
+    if (current && current.expiresAt - REFRESH_MARGIN_MS > now()) {
+      return current.token;
+    }
+    pending ??= fetchToken().finally(() => {
+      pending = undefined;
+    });
+    current = await pending;
+    return current.token;
+  };
+}
+
+/** A fixed bearer token, such as one read from a CI secret. */
+export function createTokenCredentials(token: string): CredentialsProvider {
+  return { type: 'token', credentials: async () => bearer(token) };
+}
+
+function readPem(path: string, what: string): string {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch (err) {
+    throw createKnowgraphError(
+      'io',
+      `Could not read the ${what} at ${path}: ${(err as Error).message}`,
+    );
+  }
+}
+
+/**
+ * A client certificate for mutual TLS, read on first use. Throws an I/O
+ * error when a file cannot be read.
+ */
+export function createMtlsCredentials(
+  options: MtlsOptions,
+): CredentialsProvider {
+  let tls: ClientCertificate | undefined;
+  return {
+    type: 'mtls',
+    credentials: async () => {
+      tls ??= {
+        cert: readPem(options.certPath, 'client certificate'),
+        key: readPem(options.keyPath, 'client key'),
+        ca:
+          options.caPath === undefined
+            ? undefined
+            : readPem(options.caPath, 'CA bundle'),
+        passphrase: options.passphrase,
+      };
+      return { headers: {}, tls };
+    },
+  };
+}
+
+/**
+ * The provider for `url`: the one with the longest `url` prefix of it, so
+ * credentials only go to the servers they were configured for.
+ */
+export function credentialsFor(
+  scoped: readonly ScopedCredentials[],
+  url: string,
+): CredentialsProvider | undefined {
+  let best: ScopedCredentials | undefined;
+  for (const entry of scoped) {
+    if (!url.startsWith(entry.url)) continue;
+    if (!best || entry.url.length > best.url.length) best = entry;
+  }
+  return best?.provider;
+}
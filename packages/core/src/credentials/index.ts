@@ -0,0 +1,19 @@
+export {
+  createMtlsCredentials,
+  createTokenCredentials,
+  credentialsFor,
+  jwtExpiry,
+} from './credentials.js';
+export { createCloudCredentials } from './cloud.js';
+export { createOidcDeviceCredentials } from './oidc.js';
+export type {
+  ClientCertificate,
+  CloudCredentialsOptions,
+  CloudIdentitySource,
+  Credentials,
+  CredentialsProvider,
+  DeviceAuthorization,
+  MtlsOptions,
+  OidcDeviceFlowOptions,
+  ScopedCredentials,
+} from './types.js';
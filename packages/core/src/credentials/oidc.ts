@@ -0,0 +1,257 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: OIDC device authorization flow credentials provider that prompts once, polls for the token, and refreshes it from a cache between runs
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [credentials, auth, oidc, oauth, device-flow]
+ * context:
+ *   business_goal: Let people sign in from a terminal once and stay signed in between runs
+ *   domain: credentials
+ */
+import { existsSync, mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import { bearer, cachedToken, REFRESH_MARGIN_MS } from './credentials.js';
+import type { ExpiringToken } from './credentials.js';
+import type { CredentialsProvider, OidcDeviceFlowOptions } from './types.js';
+
+const DEVICE_CODE_GRANT = 'urn:ietf:params:oauth:grant-type:device_code';
+
+/** Issuers that send no `expires_in` get a token this long, in seconds. */
+const DEFAULT_EXPIRES_IN = 3600;
+
+interface Endpoints {
+  readonly device: string;
+  readonly token: string;
+}
+
+interface TokenResponse {
+  readonly access_token?: string;
+  readonly refresh_token?: string;
+  readonly expires_in?: number;
+  readonly error?: string;
+  readonly error_description?: string;
+}
+
+/** What the cache file holds, keyed to the issuer and client. */
+interface CachedTokens extends ExpiringToken {
+  readonly issuer: string;
+  readonly clientId: string;
+  readonly refreshToken?: string;
+}
+
+function wait(ms: number): Promise<void> {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+async function getJson<T>(url: string, what: string): Promise<T> {
+  let response: Response;
+  try {
+    response = await fetch(url, { headers: { Accept: 'application/json' } });
+  } catch (err) {
+    throw createKnowgraphError(
+      'io',
+      `Could not reach ${what}: ${(err as Error).message}`,
+    );
+  }
+  if (!response.ok) {
+    throw createKnowgraphError(
+      'io',
+      `${what} answered ${response.status} ${response.statusText}`,
+    );
+  }
+  return (await response.json()) as T;
+}
+
+async function postForm<T>(
+  url: string,
+  params: Readonly<Record<string, string | undefined>>,
+): Promise<T> {
+  const body = new URLSearchParams();
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== undefined) body.set(key, value);
+  }
+  let response: Response;
+  try {
+    response = await fetch(url, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/x-www-form-urlencoded',
+        Accept: 'application/json',
+      },
+      body: body.toString(),
+    });
+  } catch (err) {
+    throw createKnowgraphError(
+      'io',
+      `Could not reach ${url}: ${(err as Error).message}`,
+    );
+  }
+  // OAuth errors come back as 400s with a JSON body saying which
+  return (await response.json().catch(() => ({}))) as T;
+}
+
+function readCache(options: OidcDeviceFlowOptions): CachedTokens | undefined {
+  const { cachePath } = options;
+  if (cachePath === undefined || !existsSync(cachePath)) return undefined;
+  try {
+    const cached = JSON.parse(readFileSync(cachePath, 'utf-8')) as CachedTokens;
+    return cached.issuer === options.issuer &&
+      cached.clientId === options.clientId
+      ? cached
+      : undefined;
+  } catch {
+    // A corrupt cache only means signing in again
+    return undefined;
+  }
+}
+
+function writeCache(
+  options: OidcDeviceFlowOptions,
+  tokens: CachedTokens,
+): void {
+  if (options.cachePath === undefined) return;
+  mkdirSync(dirname(options.cachePath), { recursive: true });
+  writeFileSync(options.cachePath, `${JSON.stringify(tokens)}\n`, {
+    encoding: 'utf-8',
+    mode: 0o600,
+  });
+}
+
+/**
+ * Sign in with the OAuth 2.0 device authorization grant (RFC 8628): the
+ * user opens a URL on any device and enters a code while this polls for
+ * the token. Suits laptops and CI runners without a browser. Tokens are
+ * refreshed with the refresh token when the issuer grants one, and the
+ * user is asked again only when that fails. Throws an I/O error when the
+ * issuer is unreachable, the user declines, or the code expires.
+ */
+export function createOidcDeviceCredentials(
+  options: OidcDeviceFlowOptions,
+): CredentialsProvider {
+  const { issuer, clientId, sleep = wait, now = Date.now } = options;
+  let endpoints: Endpoints | undefined;
+
+  async function discover(): Promise<Endpoints> {
+    if (endpoints) return endpoints;
+    const document = await getJson<{
+      device_authorization_endpoint?: string;
+      token_endpoint?: string;
+    }>(
+      `${issuer.replace(/\/$/, '')}/.well-known/openid-configuration`,
+      `the OIDC issuer ${issuer}`,
+    );
+    if (!document.device_authorization_endpoint || !document.token_endpoint) {
+      throw createKnowgraphError(
+        'usage',
+        `The OIDC issuer ${issuer} does not support the device flow`,
+      );
+    }
+    endpoints = {
+      device: document.device_authorization_endpoint,
+      token: document.token_endpoint,
+    };
+    return endpoints;
+  }
+
+  function toTokens(
+    response: TokenResponse,
+    previous?: CachedTokens,
+  ): CachedTokens {
+    return {
+      issuer,
+      clientId,
+      token: response.access_token ?? '',
+      expiresAt: now() + (response.expires_in ?? DEFAULT_EXPIRES_IN) * 1000,
+      refreshToken: response.refresh_token ?? previous?.refreshToken,
+    };
+  }
+
+  async function refresh(
+    cached: CachedTokens,
+  ): Promise<CachedTokens | undefined> {
+    if (cached.refreshToken === undefined) return undefined;
+    const { token } = await discover();
+    const response = await postForm<TokenResponse>(token, {
+      grant_type: 'refresh_token',
+      refresh_token: cached.refreshToken,
+      client_id: clientId,
+    });
+    return response.access_token ? toTokens(response, cached) : undefined;
+  }
+
+  async function signIn(): Promise<CachedTokens> {
+    const { device, token } = await discover();
+    const authorization = await postForm<{
+      device_code?: string;
+      user_code?: string;
+      verification_uri?: string;
+      verification_uri_complete?: string;
+      expires_in?: number;
+      interval?: number;
+      error?: string;
+    }>(device, {
+      client_id: clientId,
+      scope: options.scope ?? 'openid',
+      audience: options.audience,
+    });
+    if (
+      !authorization.device_code ||
+      !authorization.user_code ||
+      !authorization.verification_uri
+    ) {
+      throw createKnowgraphError(
+        'io',
+        `OIDC device authorization failed: ${authorization.error ?? 'no device code'}`,
+      );
+    }
+    const expiresIn = authorization.expires_in ?? 600;
+    options.prompt({
+      userCode: authorization.user_code,
+      verificationUri: authorization.verification_uri,
+      verificationUriComplete: authorization.verification_uri_complete,
+      expiresInSeconds: expiresIn,
+    });
+
+    const deadline = now() + expiresIn * 1000;
+    let interval = authorization.interval ?? 5;
+    while (now() < deadline) {
+      await sleep(interval * 1000);
+      const response = await postForm<TokenResponse>(token, {
+        grant_type: DEVICE_CODE_GRANT,
+        device_code: authorization.device_code,
+        client_id: clientId,
+      });
+      if (response.access_token) return toTokens(response);
+      if (response.error === 'slow_down') {
+        interval += 5;
+      } else if (response.error !== 'authorization_pending') {
+        const reason = response.error_description ?? response.error;
+        throw createKnowgraphError(
+          'io',
+          `OIDC sign-in failed: ${reason ?? 'no token'}`,
+        );
+      }
+    }
+    throw createKnowgraphError(
+      'io',
+      'OIDC sign-in failed: the device code expired',
+    );
+  }
+
+  const accessToken = cachedToken(async () => {
+    const cached = readCache(options);
+    if (cached && cached.expiresAt - REFRESH_MARGIN_MS > now()) {
+      return cached;
+    }
+    const tokens = (cached && (await refresh(cached))) ?? (await signIn());
+    writeCache(options, tokens);
+    return tokens;
+  }, now);
+
+  return {
+    type: 'oidc',
+    credentials: async () => bearer(await accessToken()),
+  };
+}
@@ -0,0 +1,111 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the credentials providers that authenticate deliveries to registries and sinks with tokens, OIDC device flow, client certificates, or cloud workload identity
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [credentials, auth, oidc, mtls, iam, types, interface]
+ * context:
+ *   business_goal: Add new ways to authenticate without changing the sinks that use them
+ *   domain: credentials
+ */
+
+/** A client certificate and key for mutual TLS, PEM encoded. */
+export interface ClientCertificate {
+  readonly cert: string;
+  readonly key: string;
+  /** CA bundle to verify the server against, in place of the system's. */
+  readonly ca?: string;
+  readonly passphrase?: string;
+}
+
+/** What a provider adds to a request. */
+export interface Credentials {
+  readonly headers: Readonly<Record<string, string>>;
+  /** Present a client certificate; requests then go through `node:https`. */
+  readonly tls?: ClientCertificate;
+}
+
+/**
+ * Produces credentials for requests. Providers cache what they fetch and
+ * refresh it shortly before it expires, so calling once per request is
+ * cheap.
+ */
+export interface CredentialsProvider {
+  /** The mechanism, such as `oidc`, for messages. */
+  readonly type: string;
+  credentials(): Promise<Credentials>;
+}
+
+/** A provider used for requests whose URL starts with `url`. */
+export interface ScopedCredentials {
+  readonly url: string;
+  readonly provider: CredentialsProvider;
+}
+
+/** What the user must do to finish an OIDC device flow sign-in. */
+export interface DeviceAuthorization {
+  readonly userCode: string;
+  readonly verificationUri: string;
+  /** The verification URI with the code filled in, when the issuer has one. */
+  readonly verificationUriComplete?: string;
+  readonly expiresInSeconds: number;
+}
+
+export interface OidcDeviceFlowOptions {
+  /** Issuer URL, whose discovery document names the endpoints. */
+  readonly issuer: string;
+  readonly clientId: string;
+  /** Space-separated scopes. Default `openid`. */
+  readonly scope?: string;
+  /** The API the token is for, for issuers that take an `audience`. */
+  readonly audience?: string;
+  /**
+   * A file that keeps tokens between runs, so users sign in once rather
+   * than on every command. Kept in memory only when omitted.
+   */
+  readonly cachePath?: string;
+  /** Tells the user where to enter the code. */
+  readonly prompt: (authorization: DeviceAuthorization) => void;
+  /** Waits between polls; tests pass one that does not. */
+  readonly sleep?: (ms: number) => Promise<void>;
+  /** Milliseconds since the epoch; tests pass a fixed clock. */
+  readonly now?: () => number;
+}
+
+/** Client certificate files for mutual TLS. */
+export interface MtlsOptions {
+  readonly certPath: string;
+  readonly keyPath: string;
+  readonly caPath?: string;
+  readonly passphrase?: string;
+}
+
+/**
+ * Where the ambient workload identity comes from: the GCP or Azure
+ * metadata service, the GitHub Actions OIDC token endpoint, or a
+ * projected Kubernetes service account token, as EKS and GKE workload
+ * identity mount.
+ */
+export type CloudIdentitySource =
+  | 'gcp'
+  | 'azure'
+  | 'github-actions'
+  | 'kubernetes';
+
+export interface CloudCredentialsOptions {
+  readonly source: CloudIdentitySource;
+  /**
+   * The API the token is for: the `audience` of GCP and GitHub Actions
+   * identity tokens and the Azure `resource`, which requires one. When
+   * omitted, GCP returns an access token instead and GitHub Actions uses
+   * the repository owner's URL.
+   */
+  readonly audience?: string;
+  /** The token file for `kubernetes`. Default the projected token. */
+  readonly tokenPath?: string;
+  /** Environment for GitHub Actions and token file variables. */
+  readonly env?: Readonly<Record<string, string | undefined>>;
+  /** Milliseconds since the epoch; tests pass a fixed clock. */
+  readonly now?: () => number;
+}
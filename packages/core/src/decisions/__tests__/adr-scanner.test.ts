@@ -0,0 +1,108 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  normalizeDecisionId,
+  parseAdr,
+  scanAdrDirectory,
+} from '../adr-scanner.js';
+
+const NYGARD = `# 7. Use PostgreSQL for billing
+
+Date: 2025-04-02
+
+## Status
+
+Superseded by [ADR-0019](0019-move-billing-to-spanner.md)
+
+## Context
+`;
+
+const INLINE = `# ADR-042: Event sourcing for orders
+
+**Date**: 2026-02-14
+**Status**: Accepted
+`;
+
+const MADR = `---
+status: deprecated
+date: 2024-11-30
+---
+# Use REST for internal services
+`;
+
+describe('normalizeDecisionId', () => {
+  it('normalizes prefixes and padding', () => {
+    expect(normalizeDecisionId('ADR-42')).toBe('ADR-042');
+    expect(normalizeDecisionId('adr-0042')).toBe('ADR-042');
+    expect(normalizeDecisionId('42')).toBe('ADR-042');
+    expect(normalizeDecisionId('ADR-1234')).toBe('ADR-1234');
+    expect(normalizeDecisionId('none')).toBeUndefined();
+  });
+});
+
+describe('parseAdr', () => {
+  it('reads Nygard-style status sections and supersession', () => {
+    const record = parseAdr(NYGARD, 'adr/0007-use-postgres.md');
+    expect(record).toEqual({
+      id: 'ADR-007',
+      title: 'Use PostgreSQL for billing',
+      status: 'superseded',
+      supersededBy: 'ADR-019',
+      date: '2025-04-02',
+      filePath: 'adr/0007-use-postgres.md',
+    });
+  });
+
+  it('reads inline bold status lines', () => {
+    const record = parseAdr(INLINE, 'adr/event-sourcing.md');
+    expect(record?.id).toBe('ADR-042');
+    expect(record?.title).toBe('Event sourcing for orders');
+    expect(record?.status).toBe('accepted');
+    expect(record?.date).toBe('2026-02-14');
+  });
+
+  it('reads MADR front matter and falls back to the file name for ids', () => {
+    const record = parseAdr(MADR, 'adr/0003-use-rest.md');
+    expect(record?.id).toBe('ADR-003');
+    expect(record?.title).toBe('Use REST for internal services');
+    expect(record?.status).toBe('deprecated');
+  });
+
+  it('skips documents without a decision number', () => {
+    expect(parseAdr('# Template\n', 'adr/template.md')).toBeUndefined();
+  });
+
+  it('reports unknown status when none is declared', () => {
+    expect(parseAdr('# 9. Something\n', '0009.md')?.status).toBe('unknown');
+  });
+});
+
+describe('scanAdrDirectory', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-adr-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(join(dir, 'archive'), { recursive: true });
+    writeFileSync(join(dir, '0042-event-sourcing.md'), INLINE);
+    writeFileSync(join(dir, 'archive', '0007-use-postgres.md'), NYGARD);
+    writeFileSync(join(dir, 'README.md'), '# Decisions\n');
+    writeFileSync(join(dir, 'notes.txt'), 'ADR-001');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('finds markdown ADRs recursively, ordered by id', () => {
+    const records = scanAdrDirectory(dir);
+    expect(records.map((r) => [r.id, r.filePath])).toEqual([
+      ['ADR-007', join('archive', '0007-use-postgres.md')],
+      ['ADR-042', '0042-event-sourcing.md'],
+    ]);
+  });
+});
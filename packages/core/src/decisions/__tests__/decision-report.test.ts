@@ -0,0 +1,94 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import { buildDecisionReport } from '../decision-report.js';
+import type { DecisionRecord } from '../types.js';
+
+function makeEntity(name: string, decisions: readonly string[]): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'module',
+    description: `${name} module`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'billing-team',
+    status: 'stable',
+    metadata: {
+      type: 'module',
+      description: `${name} module`,
+      decisions: [...decisions],
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const decisions: readonly DecisionRecord[] = [
+  {
+    id: 'ADR-007',
+    title: 'Use PostgreSQL for billing',
+    status: 'superseded',
+    supersededBy: 'ADR-019',
+    filePath: 'adr/0007.md',
+  },
+  {
+    id: 'ADR-019',
+    title: 'Move billing to Spanner',
+    status: 'accepted',
+    filePath: 'adr/0019.md',
+  },
+  {
+    id: 'ADR-020',
+    title: 'Drop XML exports',
+    status: 'deprecated',
+    filePath: 'adr/0020.md',
+  },
+];
+
+describe('buildDecisionReport', () => {
+  const report = buildDecisionReport(
+    [
+      makeEntity('ledger', ['ADR-7', 'ADR-019']),
+      makeEntity('invoices', ['adr-0019']),
+      makeEntity('legacy', ['ADR-999']),
+    ],
+    decisions,
+  );
+
+  it('links entities to the decisions that govern them', () => {
+    const governs = report.decisions.map((node) => [
+      node.decision.id,
+      node.governs.map((g) => g.name),
+    ]);
+    expect(governs).toEqual([
+      ['ADR-007', ['ledger']],
+      ['ADR-019', ['ledger', 'invoices']],
+      ['ADR-020', []],
+    ]);
+  });
+
+  it('reports code governed by superseded decisions with replacements', () => {
+    expect(report.superseded).toHaveLength(1);
+    expect(report.superseded[0].decision.id).toBe('ADR-007');
+    expect(report.superseded[0].replacement?.title).toBe(
+      'Move billing to Spanner',
+    );
+    expect(report.superseded[0].governs.map((g) => g.name)).toEqual([
+      'ledger',
+    ]);
+  });
+
+  it('collects references to unknown decisions', () => {
+    expect(report.unknownReferences).toEqual([
+      expect.objectContaining({ decisionId: 'ADR-999' }),
+    ]);
+  });
+});
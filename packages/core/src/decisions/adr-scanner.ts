@@ -0,0 +1,156 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Scans a directory of markdown ADRs and extracts decision id, title, status, and supersession
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [decisions, adr, markdown, scanner]
+ * context:
+ *   business_goal: Build decision nodes from the ADR documents teams already write
+ *   domain: decisions
+ */
+import { readdirSync, readFileSync, statSync } from 'node:fs';
+import { join, relative, basename } from 'node:path';
+import { parse as parseYaml } from 'yaml';
+import type { DecisionRecord, DecisionStatus } from './types.js';
+
+const KNOWN_STATUSES: readonly DecisionStatus[] = [
+  'proposed',
+  'accepted',
+  'rejected',
+  'deprecated',
+  'superseded',
+];
+
+const SUPERSEDED_BY = /superseded\s+by\s*:?\s*\[?((?:ADR[-_ ]?)?\d+)/i;
+
+/**
+ * Normalize decision references so `ADR-42`, `adr-0042` and `42` compare
+ * equal. Returns undefined when the reference has no decision number.
+ */
+export function normalizeDecisionId(raw: string): string | undefined {
+  const match = /(\d+)/.exec(raw);
+  if (!match) return undefined;
+  return `ADR-${String(parseInt(match[1], 10)).padStart(3, '0')}`;
+}
+
+function toStatus(raw: string | undefined): DecisionStatus {
+  if (!raw) return 'unknown';
+  const lower = raw.toLowerCase();
+  return KNOWN_STATUSES.find((status) => lower.includes(status)) ?? 'unknown';
+}
+
+function splitFrontMatter(content: string): {
+  readonly data: Record<string, unknown>;
+  readonly body: string;
+} {
+  const match = /^---\r?\n([\s\S]*?)\r?\n---\r?\n?/.exec(content);
+  if (!match) return { data: {}, body: content };
+  try {
+    const data = parseYaml(match[1]) as unknown;
+    const record =
+      data && typeof data === 'object' ? (data as Record<string, unknown>) : {};
+    return { data: record, body: content.slice(match[0].length) };
+  } catch {
+    return { data: {}, body: content.slice(match[0].length) };
+  }
+}
+
+function findStatusLine(body: string): string | undefined {
+  const inline =
+    /^[ \t]*(?:[-*][ \t]*)?\**status\**[ \t]*\**:\**[ \t]*(.+)$/im.exec(body);
+  if (inline) return inline[1].trim();
+
+  const lines = body.split(/\r?\n/);
+  const heading = lines.findIndex((line) =>
+    /^#{2,6}\s+status\s*$/i.test(line),
+  );
+  if (heading === -1) return undefined;
+  return lines
+    .slice(heading + 1)
+    .find((line) => line.trim() !== '' && !line.startsWith('#'))
+    ?.trim();
+}
+
+function findDate(body: string): string | undefined {
+  const match =
+    /^[ \t]*(?:[-*][ \t]*)?\**date\**[ \t]*\**:\**[ \t]*(\S+)/im.exec(body);
+  return match?.[1];
+}
+
+/**
+ * Parse one ADR document. Supports Nygard-style `## Status` sections, inline
+ * `Status: Accepted` lines, and YAML front matter (MADR). Returns undefined
+ * when no decision number can be found in the title or file name.
+ */
+export function parseAdr(
+  content: string,
+  filePath: string,
+): DecisionRecord | undefined {
+  const { data, body } = splitFrontMatter(content);
+  const heading = /^#\s+(.+)$/m.exec(body)?.[1].trim();
+
+  const headingId = heading && /^(?:ADR[-_ ]?)?\d+\b/i.exec(heading)?.[0];
+  const id = normalizeDecisionId(headingId || basename(filePath));
+  if (!id) return undefined;
+
+  const title = (heading ?? basename(filePath))
+    .replace(/^(?:ADR[-_ ]?)?\d+\s*[:.\-–]?\s*/i, '')
+    .trim();
+
+  const statusText =
+    typeof data.status === 'string' ? data.status : findStatusLine(body);
+  const supersededMatch =
+    (typeof data['superseded-by'] === 'string'
+      ? data['superseded-by']
+      : undefined) ?? statusText?.match(SUPERSEDED_BY)?.[1];
+  const status = toStatus(statusText);
+
+  const dateValue = data.date;
+  const date =
+    typeof dateValue === 'string'
+      ? dateValue
+      : dateValue instanceof Date
+        ? dateValue.toISOString().slice(0, 10)
+        : findDate(body);
+
+  return {
+    id,
+    title: title || id,
+    status: supersededMatch && status === 'unknown' ? 'superseded' : status,
+    supersededBy: supersededMatch
+      ? normalizeDecisionId(supersededMatch)
+      : undefined,
+    date,
+    filePath,
+  };
+}
+
+function listMarkdownFiles(dir: string): readonly string[] {
+  const files: string[] = [];
+  for (const entry of readdirSync(dir)) {
+    const path = join(dir, entry);
+    if (statSync(path).isDirectory()) {
+      files.push(...listMarkdownFiles(path));
+    } else if (/\.md$/i.test(entry)) {
+      files.push(path);
+    }
+  }
+  return files.sort();
+}
+
+/**
+ * Read every markdown file under `dir` and return the decisions found,
+ * ordered by id. File paths are reported relative to `rootDir`.
+ */
+export function scanAdrDirectory(
+  dir: string,
+  rootDir: string = dir,
+): readonly DecisionRecord[] {
+  return listMarkdownFiles(dir)
+    .map((path) =>
+      parseAdr(readFileSync(path, 'utf-8'), relative(rootDir, path)),
+    )
+    .filter((record): record is DecisionRecord => record !== undefined)
+    .sort((a, b) => a.id.localeCompare(b.id));
+}
@@ -0,0 +1,87 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Joins entity decision references to ADR records and reports code governed by superseded decisions
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [decisions, adr, governance, report]
+ * context:
+ *   business_goal: Surface code whose governing architecture decision has been replaced
+ *   domain: decisions
+ */
+import type { StoredEntity } from '../indexer/types.js';
+import { normalizeDecisionId } from './adr-scanner.js';
+import type {
+  DecisionNode,
+  DecisionRecord,
+  DecisionReport,
+  GovernedEntity,
+  SupersededGovernance,
+  UnknownDecisionReference,
+} from './types.js';
+
+function toGoverned(entity: StoredEntity): GovernedEntity {
+  return {
+    entityId: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    filePath: entity.filePath,
+    line: entity.line,
+    owner: entity.owner,
+  };
+}
+
+function getDecisionRefs(entity: StoredEntity): readonly string[] {
+  const { metadata } = entity;
+  return 'decisions' in metadata ? (metadata.decisions ?? []) : [];
+}
+
+/**
+ * Build decision nodes with the entities each one governs, list entities
+ * governed by superseded or deprecated decisions (with the replacement when
+ * known), and collect references to ADRs that do not exist.
+ */
+export function buildDecisionReport(
+  entities: readonly StoredEntity[],
+  decisions: readonly DecisionRecord[],
+): DecisionReport {
+  const byId = new Map(decisions.map((decision) => [decision.id, decision]));
+  const governs = new Map<string, GovernedEntity[]>();
+  const unknownReferences: UnknownDecisionReference[] = [];
+
+  for (const entity of entities) {
+    for (const ref of getDecisionRefs(entity)) {
+      const id = normalizeDecisionId(ref);
+      if (!id || !byId.has(id)) {
+        unknownReferences.push({
+          decisionId: ref,
+          entity: toGoverned(entity),
+        });
+        continue;
+      }
+      governs.set(id, [...(governs.get(id) ?? []), toGoverned(entity)]);
+    }
+  }
+
+  const nodes: DecisionNode[] = decisions.map((decision) => ({
+    decision,
+    governs: governs.get(decision.id) ?? [],
+  }));
+
+  const superseded: SupersededGovernance[] = nodes
+    .filter(
+      (node) =>
+        node.governs.length > 0 &&
+        (node.decision.status === 'superseded' ||
+          node.decision.status === 'deprecated'),
+    )
+    .map((node) => ({
+      decision: node.decision,
+      replacement: node.decision.supersededBy
+        ? byId.get(node.decision.supersededBy)
+        : undefined,
+      governs: node.governs,
+    }));
+
+  return { decisions: nodes, superseded, unknownReferences };
+}
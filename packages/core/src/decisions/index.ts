@@ -0,0 +1,15 @@
+export type {
+  DecisionStatus,
+  DecisionRecord,
+  GovernedEntity,
+  DecisionNode,
+  SupersededGovernance,
+  UnknownDecisionReference,
+  DecisionReport,
+} from './types.js';
+export {
+  normalizeDecisionId,
+  parseAdr,
+  scanAdrDirectory,
+} from './adr-scanner.js';
+export { buildDecisionReport } from './decision-report.js';
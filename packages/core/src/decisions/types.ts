@@ -0,0 +1,60 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for architecture decision records and decision governance reports
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [decisions, adr, types, interface]
+ * context:
+ *   business_goal: Connect the documents explaining why code exists to the code itself
+ *   domain: decisions
+ */
+import type { EntityType } from '../types/entity.js';
+
+export type DecisionStatus =
+  | 'proposed'
+  | 'accepted'
+  | 'rejected'
+  | 'deprecated'
+  | 'superseded'
+  | 'unknown';
+
+export interface DecisionRecord {
+  readonly id: string;
+  readonly title: string;
+  readonly status: DecisionStatus;
+  readonly supersededBy?: string;
+  readonly date?: string;
+  readonly filePath: string;
+}
+
+export interface GovernedEntity {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly line: number;
+  readonly owner: string | null;
+}
+
+export interface DecisionNode {
+  readonly decision: DecisionRecord;
+  readonly governs: readonly GovernedEntity[];
+}
+
+export interface SupersededGovernance {
+  readonly decision: DecisionRecord;
+  readonly replacement?: DecisionRecord;
+  readonly governs: readonly GovernedEntity[];
+}
+
+export interface UnknownDecisionReference {
+  readonly decisionId: string;
+  readonly entity: GovernedEntity;
+}
+
+export interface DecisionReport {
+  readonly decisions: readonly DecisionNode[];
+  readonly superseded: readonly SupersededGovernance[];
+  readonly unknownReferences: readonly UnknownDecisionReference[];
+}
@@ -0,0 +1,159 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import { existsSync, mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  backoffDelay,
+  createDeliveryClient,
+  DEFAULT_RETRY_POLICY,
+} from '../delivery.js';
+import { appendOutbox, readOutbox } from '../outbox.js';
+import { createTokenCredentials } from '../../credentials/credentials.js';
+import type { DeliveryRequest } from '../types.js';
+
+const request: DeliveryRequest = {
+  sink: 'slack',
+  url: 'https://hooks.slack.com/x',
+  body: '{"text":"hi"}',
+};
+
+/** Answer each fetch with the next status, or a network error for 0. */
+function mockFetch(...statuses: number[]): ReturnType<typeof vi.fn> {
+  const fn = vi.fn(async () => {
+    const status = statuses.shift() ?? 200;
+    if (status === 0) throw new TypeError('fetch failed');
+    return { ok: status < 300, status, statusText: `HTTP ${status}` };
+  });
+  vi.stubGlobal('fetch', fn);
+  return fn;
+}
+
+function noWait(): (ms: number) => Promise<void> {
+  return vi.fn(async () => {});
+}
+
+describe('backoffDelay', () => {
+  it('doubles from the initial delay up to the cap', () => {
+    const policy = { attempts: 6, initialDelayMs: 500, maxDelayMs: 3000 };
+    expect([0, 1, 2, 3].map((retry) => backoffDelay(policy, retry))).toEqual([
+      500, 1000, 2000, 3000,
+    ]);
+    expect(DEFAULT_RETRY_POLICY.attempts).toBe(3);
+  });
+});
+
+describe('createDeliveryClient', () => {
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('retries network and server errors with backoff', async () => {
+    const fn = mockFetch(0, 503, 200);
+    const sleep = noWait();
+    const client = createDeliveryClient({ sleep });
+    expect(await client.send(request)).toBe('sent');
+    expect(fn).toHaveBeenCalledTimes(3);
+    expect(vi.mocked(sleep).mock.calls).toEqual([[500], [1000]]);
+  });
+
+  it('fails at once when the sink rejects the request', async () => {
+    const fn = mockFetch(400);
+    const client = createDeliveryClient({ sleep: noWait() });
+    await expect(client.send(request)).rejects.toMatchObject({
+      kind: 'io',
+      message: 'Delivery to slack failed: 400 HTTP 400',
+    });
+    expect(fn).toHaveBeenCalledTimes(1);
+  });
+
+  it('throws after the last try without an outbox', async () => {
+    mockFetch(500, 500);
+    const client = createDeliveryClient({
+      retry: { attempts: 2 },
+      sleep: noWait(),
+    });
+    await expect(client.send(request)).rejects.toMatchObject({
+      message: 'Delivery to slack failed after 2 tries: 500 HTTP 500',
+    });
+  });
+
+  it('queues what runs out of retries and sends it on flush', async () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-outbox-'));
+    try {
+      const queuePath = join(dir, 'outbox.jsonl');
+      const client = createDeliveryClient({
+        retry: { attempts: 1 },
+        queuePath,
+        sleep: noWait(),
+      });
+      mockFetch(0);
+      expect(await client.send(request)).toBe('queued');
+      expect(readOutbox(queuePath)).toMatchObject([
+        { request, error: 'fetch failed' },
+      ]);
+
+      mockFetch(200);
+      expect(await client.flush()).toEqual({
+        sent: 1,
+        dropped: [],
+        remaining: 0,
+      });
+      expect(existsSync(queuePath)).toBe(false);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  it('drops refused deliveries and stops at the first still failing', async () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-outbox-'));
+    try {
+      const queuePath = join(dir, 'outbox.jsonl');
+      for (const sink of ['a', 'b', 'c']) {
+        appendOutbox(queuePath, {
+          request: { ...request, sink },
+          queuedAt: '2026-10-01T00:00:00.000Z',
+          error: 'fetch failed',
+        });
+      }
+      const fn = mockFetch(404, 503);
+      const client = createDeliveryClient({
+        retry: { attempts: 1 },
+        queuePath,
+      });
+      const result = await client.flush();
+      expect(fn).toHaveBeenCalledTimes(2);
+      expect(result.sent).toBe(0);
+      expect(result.dropped.map((d) => [d.request.sink, d.error])).toEqual([
+        ['a', '404 HTTP 404'],
+      ]);
+      expect(result.remaining).toBe(2);
+      expect(readOutbox(queuePath).map((d) => d.request.sink)).toEqual([
+        'b',
+        'c',
+      ]);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  it('adds credentials only to the URLs they are scoped to', async () => {
+    const fn = mockFetch(200, 200);
+    const client = createDeliveryClient({
+      credentials: [
+        {
+          url: 'https://registry.example.com/',
+          provider: createTokenCredentials('secret'),
+        },
+      ],
+    });
+    await client.send({ ...request, url: 'https://registry.example.com/v1' });
+    await client.send(request);
+    const headers = fn.mock.calls.map(
+      (call) => (call as [string, RequestInit])[1].headers,
+    );
+    expect(headers).toEqual([
+      { 'Content-Type': 'application/json', Authorization: 'Bearer secret' },
+      { 'Content-Type': 'application/json' },
+    ]);
+  });
+});
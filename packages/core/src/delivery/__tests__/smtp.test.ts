@@ -0,0 +1,140 @@
+import { describe, it, expect, afterEach } from 'vitest';
+import { createServer } from 'node:net';
+import type { AddressInfo, Server } from 'node:net';
+import { formatMail, sendMail } from '../smtp.js';
+import type { MailMessage } from '../types.js';
+
+const message: MailMessage = {
+  from: 'knowgraph@example.com',
+  to: ['platform@example.com', 'ops@example.com'],
+  subject: 'knowgraph: 1 graph anomalies',
+  text: 'Coverage dropped 10 points (80% → 70%)',
+};
+
+/**
+ * An SMTP server on localhost that accepts everything, except that
+ * `replies` overrides its reply to the commands it names, once each.
+ */
+async function fakeServer(replies: Record<string, string[]> = {}) {
+  const commands: string[] = [];
+  const mails: string[] = [];
+  const server: Server = createServer((socket) => {
+    let buffer = '';
+    let data: string[] | undefined;
+    socket.on('error', () => {});
+    socket.write('220 localhost ESMTP\r\n');
+    socket.on('data', (chunk: Buffer) => {
+      buffer += chunk.toString('utf-8');
+      let end: number;
+      while ((end = buffer.indexOf('\r\n')) !== -1) {
+        const line = buffer.slice(0, end);
+        buffer = buffer.slice(end + 2);
+        if (data) {
+          if (line !== '.') {
+            data.push(line);
+            continue;
+          }
+          mails.push(data.join('\r\n'));
+          data = undefined;
+        }
+        const verb = line === '.' ? '.' : line.split(/[ :]/)[0];
+        commands.push(line === '.' ? '.' : verb);
+        const reply =
+          replies[verb]?.shift() ??
+          (verb === 'EHLO'
+            ? '250-localhost\r\n250 8BITMIME'
+            : verb === 'DATA'
+              ? '354 go ahead'
+              : verb === 'QUIT'
+                ? '221 bye'
+                : '250 OK');
+        if (verb === 'DATA' && reply.startsWith('354')) data = [];
+        socket.write(`${reply}\r\n`);
+        if (verb === 'QUIT') socket.end();
+      }
+    });
+  });
+  await new Promise<void>((resolve) => server.listen(0, '127.0.0.1', resolve));
+  const { port } = server.address() as AddressInfo;
+  return { server, port, commands, mails };
+}
+
+const noWait = async () => {};
+
+describe('formatMail', () => {
+  it('encodes the body as base64 and non-ASCII headers as UTF-8', () => {
+    const mail = formatMail(
+      { ...message, subject: 'Übersicht' },
+      new Date('2026-03-01T00:00:00.000Z'),
+      '<1@knowgraph>',
+    );
+    const [headers, body] = mail.split('\r\n\r\n');
+    expect(headers.split('\r\n')).toEqual([
+      'From: knowgraph@example.com',
+      'To: platform@example.com, ops@example.com',
+      'Subject: =?UTF-8?B?w5xiZXJzaWNodA==?=',
+      'Date: Sun, 01 Mar 2026 00:00:00 GMT',
+      'Message-ID: <1@knowgraph>',
+      'MIME-Version: 1.0',
+      'Content-Type: text/plain; charset=utf-8',
+      'Content-Transfer-Encoding: base64',
+    ]);
+    expect(Buffer.from(body, 'base64').toString('utf-8')).toBe(message.text);
+  });
+});
+
+describe('sendMail', () => {
+  let server: Server | undefined;
+
+  afterEach(async () => {
+    await new Promise((resolve) => server?.close(resolve) ?? resolve(null));
+    server = undefined;
+  });
+
+  it('sends the mail to every recipient', async () => {
+    const fake = await fakeServer();
+    server = fake.server;
+    await sendMail({ host: '127.0.0.1', port: fake.port }, message);
+    expect(fake.commands.slice(0, 6)).toEqual([
+      'EHLO',
+      'MAIL',
+      'RCPT',
+      'RCPT',
+      'DATA',
+      '.',
+    ]);
+    expect(fake.mails[0]).toContain('Subject: knowgraph: 1 graph anomalies');
+  });
+
+  it('retries transient replies, and fails at once on permanent ones', async () => {
+    const fake = await fakeServer({
+      MAIL: ['451 try later'],
+      RCPT: ['550 no'],
+    });
+    server = fake.server;
+    const options = { host: '127.0.0.1', port: fake.port, sleep: noWait };
+    await expect(sendMail(options, message)).rejects.toMatchObject({
+      kind: 'io',
+      message: 'Mail to 127.0.0.1 failed: 550 no',
+    });
+    const mails = fake.commands.filter((command) => command === 'MAIL');
+    expect(mails).toHaveLength(2);
+  });
+
+  it('refuses to send a password without TLS', async () => {
+    const fake = await fakeServer();
+    server = fake.server;
+    await expect(
+      sendMail(
+        {
+          host: '127.0.0.1',
+          port: fake.port,
+          username: 'knowgraph',
+          password: 'secret',
+        },
+        message,
+      ),
+    ).rejects.toMatchObject({ kind: 'usage' });
+    expect(fake.commands).not.toContain('AUTH');
+  });
+});
@@ -0,0 +1,209 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Shared client for network sinks that authenticates each request with the credentials scoped to its URL, retries failed deliveries with exponential backoff, and queues those that run out of retries
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [delivery, retry, backoff, queue, webhook]
+ * context:
+ *   business_goal: Retry, authenticate, and queue every network sink the same way
+ *   domain: delivery
+ */
+import { request as httpsRequest } from 'node:https';
+import { credentialsFor } from '../credentials/credentials.js';
+import type { ClientCertificate, Credentials } from '../credentials/types.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { appendOutbox, readOutbox, writeOutbox } from './outbox.js';
+import type {
+  DeliveryClient,
+  DeliveryClientOptions,
+  DeliveryOutcome,
+  DeliveryRequest,
+  FlushResult,
+  QueuedDelivery,
+  RetryPolicy,
+} from './types.js';
+
+export const DEFAULT_RETRY_POLICY: RetryPolicy = {
+  attempts: 3,
+  initialDelayMs: 500,
+  maxDelayMs: 10_000,
+};
+
+/** The wait before retry number `retry` (from 0): doubling, then capped. */
+export function backoffDelay(policy: RetryPolicy, retry: number): number {
+  return Math.min(policy.maxDelayMs, policy.initialDelayMs * 2 ** retry);
+}
+
+/** Timeouts, rate limits, and server errors may go through on a retry. */
+export function isRetryableStatus(status: number): boolean {
+  return status === 408 || status === 429 || status >= 500;
+}
+
+/**
+ * Call `tryOnce` until it succeeds, fails in a way a retry cannot fix, or
+ * has been tried `policy.attempts` times, backing off between tries.
+ * Returns the last result.
+ */
+export async function retryWithBackoff<
+  T extends { readonly ok: boolean; readonly retryable?: boolean },
+>(
+  policy: RetryPolicy,
+  sleep: (ms: number) => Promise<void>,
+  tryOnce: () => Promise<T>,
+): Promise<T> {
+  let result = await tryOnce();
+  for (let retry = 0; retry < policy.attempts - 1; retry++) {
+    if (result.ok || !result.retryable) break;
+    await sleep(backoffDelay(policy, retry));
+    result = await tryOnce();
+  }
+  return result;
+}
+
+type Attempt =
+  | { readonly ok: true }
+  | { readonly ok: false; readonly retryable: boolean; readonly error: string };
+
+interface Reply {
+  readonly ok: boolean;
+  readonly status: number;
+  readonly statusText: string;
+}
+
+/** POST through `node:https`, since fetch cannot present a certificate. */
+function postWithCertificate(
+  url: string,
+  headers: Readonly<Record<string, string>>,
+  body: string,
+  tls: ClientCertificate,
+): Promise<Reply> {
+  return new Promise((resolve, reject) => {
+    const req = httpsRequest(
+      url,
+      { method: 'POST', headers, ...tls },
+      (res) => {
+        const status = res.statusCode ?? 0;
+        res.resume();
+        res.on('end', () =>
+          resolve({
+            ok: status >= 200 && status < 300,
+            status,
+            statusText: res.statusMessage ?? '',
+          }),
+        );
+      },
+    );
+    req.on('error', reject);
+    req.end(body);
+  });
+}
+
+async function attempt(
+  request: DeliveryRequest,
+  credentials: Credentials | undefined,
+): Promise<Attempt> {
+  // The request's own headers win, so a sink's configured header stays
+  const headers = {
+    'Content-Type': 'application/json',
+    ...credentials?.headers,
+    ...request.headers,
+  };
+  let response: Reply;
+  try {
+    response = credentials?.tls
+      ? await postWithCertificate(
+          request.url,
+          headers,
+          request.body,
+          credentials.tls,
+        )
+      : await fetch(request.url, {
+          method: 'POST',
+          headers,
+          body: request.body,
+        });
+  } catch (err) {
+    // Both reject only when the request never got a response
+    const error = err instanceof Error ? err.message : String(err);
+    return { ok: false, retryable: true, error };
+  }
+  if (response.ok) return { ok: true };
+  return {
+    ok: false,
+    retryable: isRetryableStatus(response.status),
+    error: `${response.status} ${response.statusText}`,
+  };
+}
+
+function wait(ms: number): Promise<void> {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+/**
+ * A client sharing one retry policy and outbox across sinks, so one run's
+ * failed deliveries queue up together and go out together on the next.
+ */
+export function createDeliveryClient(
+  options: DeliveryClientOptions = {},
+): DeliveryClient {
+  const policy = { ...DEFAULT_RETRY_POLICY, ...options.retry };
+  const { queuePath, sleep = wait, credentials = [] } = options;
+
+  /**
+   * Credentials are fetched before each try, so a token that expires
+   * during backoff is refreshed. Failing to get them throws.
+   */
+  async function deliver(request: DeliveryRequest): Promise<Attempt> {
+    const provider = credentialsFor(credentials, request.url);
+    return retryWithBackoff(policy, sleep, async () =>
+      attempt(request, await provider?.credentials()),
+    );
+  }
+
+  async function send(request: DeliveryRequest): Promise<DeliveryOutcome> {
+    const result = await deliver(request);
+    if (result.ok) return 'sent';
+    if (!result.retryable || queuePath === undefined) {
+      const tries = result.retryable ? ` after ${policy.attempts} tries` : '';
+      throw createKnowgraphError(
+        'io',
+        `Delivery to ${request.sink} failed${tries}: ${result.error}`,
+      );
+    }
+    appendOutbox(queuePath, {
+      request,
+      queuedAt: new Date().toISOString(),
+      error: result.error,
+    });
+    return 'queued';
+  }
+
+  /**
+   * Stops at the first delivery that still cannot get through, since the
+   * network is likely still down, and leaves it and the rest queued.
+   */
+  async function flush(): Promise<FlushResult> {
+    if (queuePath === undefined) return { sent: 0, dropped: [], remaining: 0 };
+    const queued = readOutbox(queuePath);
+    const dropped: QueuedDelivery[] = [];
+    let remaining: readonly QueuedDelivery[] = [];
+    let sent = 0;
+    for (const [index, delivery] of queued.entries()) {
+      const result = await deliver(delivery.request);
+      if (result.ok) {
+        sent++;
+      } else if (!result.retryable) {
+        dropped.push({ ...delivery, error: result.error });
+      } else {
+        const failed = { ...delivery, error: result.error };
+        remaining = [failed, ...queued.slice(index + 1)];
+        break;
+      }
+    }
+    if (queued.length > 0) writeOutbox(queuePath, remaining);
+    return { sent, dropped, remaining: remaining.length };
+  }
+
+  return { send, flush };
+}
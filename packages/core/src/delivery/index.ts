@@ -0,0 +1,20 @@
+export {
+  backoffDelay,
+  createDeliveryClient,
+  DEFAULT_RETRY_POLICY,
+  isRetryableStatus,
+  retryWithBackoff,
+} from './delivery.js';
+export { appendOutbox, readOutbox, writeOutbox } from './outbox.js';
+export { formatMail, sendMail } from './smtp.js';
+export type {
+  DeliveryClient,
+  DeliveryClientOptions,
+  DeliveryOutcome,
+  DeliveryRequest,
+  FlushResult,
+  MailMessage,
+  QueuedDelivery,
+  RetryPolicy,
+  SmtpOptions,
+} from './types.js';
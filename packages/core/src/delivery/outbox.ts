@@ -0,0 +1,61 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Persists deliveries that ran out of retries to a local JSON Lines outbox so a later run can send them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [delivery, queue, outbox, offline, jsonl]
+ * context:
+ *   business_goal: Make sure an alert that could not be sent during an outage still arrives later
+ *   domain: delivery
+ */
+import {
+  appendFileSync,
+  existsSync,
+  mkdirSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { dirname } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { QueuedDelivery } from './types.js';
+
+/** The queued deliveries at `path`, oldest first; none when it is missing. */
+export function readOutbox(path: string): readonly QueuedDelivery[] {
+  if (!existsSync(path)) return [];
+  const queued: QueuedDelivery[] = [];
+  const lines = readFileSync(path, 'utf-8').split('\n');
+  lines.forEach((line, index) => {
+    if (line.trim() === '') return;
+    try {
+      queued.push(JSON.parse(line) as QueuedDelivery);
+    } catch {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid outbox entry at ${path}:${index + 1}`,
+      );
+    }
+  });
+  return queued;
+}
+
+/** Queue one delivery, creating the outbox when needed. */
+export function appendOutbox(path: string, delivery: QueuedDelivery): void {
+  mkdirSync(dirname(path), { recursive: true });
+  appendFileSync(path, `${JSON.stringify(delivery)}\n`, 'utf-8');
+}
+
+/** Replace the outbox with `queued`, removing it once it is empty. */
+export function writeOutbox(
+  path: string,
+  queued: readonly QueuedDelivery[],
+): void {
+  if (queued.length === 0) {
+    rmSync(path, { force: true });
+    return;
+  }
+  mkdirSync(dirname(path), { recursive: true });
+  const lines = queued.map((delivery) => `${JSON.stringify(delivery)}\n`);
+  writeFileSync(path, lines.join(''), 'utf-8');
+}
@@ -0,0 +1,251 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Minimal SMTP client that sends plain-text mail with STARTTLS or implicit TLS, AUTH PLAIN, and the shared retry policy
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [delivery, smtp, email, tls, retry]
+ * context:
+ *   business_goal: Email alerts through the relay a company already runs, without a mail library
+ *   domain: delivery
+ */
+import { randomUUID } from 'node:crypto';
+import { connect as connectTcp } from 'node:net';
+import type { Socket } from 'node:net';
+import { hostname } from 'node:os';
+import { connect as connectTls } from 'node:tls';
+import { createKnowgraphError, isKnowgraphError } from '../errors/errors.js';
+import { backoffDelay, DEFAULT_RETRY_POLICY } from './delivery.js';
+import type { MailMessage, SmtpOptions } from './types.js';
+
+const DEFAULT_TIMEOUT_MS = 30_000;
+
+interface Reply {
+  readonly code: number;
+  readonly lines: readonly string[];
+}
+
+/** A reply the server sent instead of the one expected. */
+interface ReplyError extends Error {
+  readonly reply: Reply;
+}
+
+function replyError(reply: Reply): ReplyError {
+  const message = `${reply.code} ${reply.lines.join(' ')}`;
+  return Object.assign(new Error(message), { reply });
+}
+
+interface Connection {
+  readonly socket: Socket;
+  read(): Promise<Reply>;
+  send(line: string, ...expected: number[]): Promise<Reply>;
+  /** Stop reading, so TLS can take the socket over. */
+  detach(): void;
+}
+
+/** Reads replies off `socket`, one at a time, in order. */
+function openConnection(socket: Socket, timeoutMs: number): Connection {
+  let buffer = '';
+  let failure: Error | undefined;
+  let waiting:
+    | { resolve: (reply: Reply) => void; reject: (err: Error) => void }
+    | undefined;
+
+  /** A complete reply from the buffer, which may span several lines. */
+  function takeReply(): Reply | undefined {
+    const lines: string[] = [];
+    let offset = 0;
+    for (;;) {
+      const end = buffer.indexOf('\r\n', offset);
+      if (end === -1) return undefined;
+      const line = buffer.slice(offset, end);
+      lines.push(line.slice(4));
+      offset = end + 2;
+      if (line[3] !== '-') {
+        buffer = buffer.slice(offset);
+        return { code: Number(line.slice(0, 3)), lines };
+      }
+    }
+  }
+
+  function settle(): void {
+    if (!waiting) return;
+    const current = waiting;
+    if (failure) {
+      waiting = undefined;
+      current.reject(failure);
+      return;
+    }
+    const reply = takeReply();
+    if (reply) {
+      waiting = undefined;
+      current.resolve(reply);
+    }
+  }
+
+  const fail = (err: Error) => {
+    failure ??= err;
+    settle();
+  };
+  const onData = (chunk: Buffer) => {
+    buffer += chunk.toString('utf-8');
+    settle();
+  };
+  const onClose = () => fail(new Error('connection closed'));
+  const onTimeout = () => {
+    socket.destroy(new Error(`no reply within ${timeoutMs}ms`));
+  };
+  socket.setTimeout(timeoutMs);
+  socket.on('timeout', onTimeout);
+  socket.on('data', onData);
+  socket.on('error', fail);
+  socket.on('close', onClose);
+
+  function detach(): void {
+    socket.setTimeout(0);
+    socket.off('timeout', onTimeout);
+    socket.off('data', onData);
+    socket.off('error', fail);
+    socket.off('close', onClose);
+  }
+
+  function read(): Promise<Reply> {
+    return new Promise((resolve, reject) => {
+      waiting = { resolve, reject };
+      settle();
+    });
+  }
+
+  async function send(line: string, ...expected: number[]): Promise<Reply> {
+    socket.write(`${line}\r\n`);
+    const reply = await read();
+    if (!expected.includes(reply.code)) throw replyError(reply);
+    return reply;
+  }
+
+  return { socket, read, send, detach };
+}
+
+/** A header value, encoded when it is not plain ASCII. */
+function headerValue(value: string): string {
+  const flat = value.replace(/[\r\n]+/g, ' ');
+  return /^[\x20-\x7e]*$/.test(flat)
+    ? flat
+    : `=?UTF-8?B?${Buffer.from(flat, 'utf-8').toString('base64')}?=`;
+}
+
+/**
+ * `message` as an RFC 5322 mail with a base64 UTF-8 body. Base64 lines
+ * never start with a dot, so the body needs no dot-stuffing.
+ */
+export function formatMail(
+  message: MailMessage,
+  date: Date = new Date(),
+  messageId = `<${randomUUID()}@knowgraph>`,
+): string {
+  const body = Buffer.from(message.text, 'utf-8').toString('base64');
+  return [
+    `From: ${headerValue(message.from)}`,
+    `To: ${message.to.map(headerValue).join(', ')}`,
+    `Subject: ${headerValue(message.subject)}`,
+    `Date: ${date.toUTCString()}`,
+    `Message-ID: ${messageId}`,
+    'MIME-Version: 1.0',
+    'Content-Type: text/plain; charset=utf-8',
+    'Content-Transfer-Encoding: base64',
+    '',
+    ...(body.match(/.{1,76}/g) ?? []),
+  ].join('\r\n');
+}
+
+async function deliverOnce(
+  options: SmtpOptions,
+  message: MailMessage,
+): Promise<void> {
+  const secure = options.secure ?? false;
+  const port = options.port ?? (secure ? 465 : 587);
+  const timeoutMs = options.timeoutMs ?? DEFAULT_TIMEOUT_MS;
+  const tlsOptions = { servername: options.host, ...options.tls };
+  let connection = openConnection(
+    secure
+      ? connectTls({ host: options.host, port, ...tlsOptions })
+      : connectTcp({ host: options.host, port }),
+    timeoutMs,
+  );
+  try {
+    const greeting = await connection.read();
+    if (greeting.code !== 220) throw replyError(greeting);
+    const name = hostname();
+    let ehlo = await connection.send(`EHLO ${name}`, 250);
+    let encrypted = secure;
+    if (!secure && ehlo.lines.some((line) => /^STARTTLS\b/i.test(line))) {
+      await connection.send('STARTTLS', 220);
+      connection.detach();
+      const socket = connectTls({ socket: connection.socket, ...tlsOptions });
+      connection = openConnection(socket, timeoutMs);
+      ehlo = await connection.send(`EHLO ${name}`, 250);
+      encrypted = true;
+    }
+    if (options.username !== undefined) {
+      if (!encrypted) {
+        throw createKnowgraphError(
+          'usage',
+          `${options.host} offers no TLS, so the SMTP password would be sent in the clear`,
+        );
+      }
+      const login = `\0${options.username}\0${options.password ?? ''}`;
+      const token = Buffer.from(login, 'utf-8').toString('base64');
+      await connection.send(`AUTH PLAIN ${token}`, 235);
+    }
+    await connection.send(`MAIL FROM:<${message.from}>`, 250);
+    for (const recipient of message.to) {
+      await connection.send(`RCPT TO:<${recipient}>`, 250, 251);
+    }
+    await connection.send('DATA', 354);
+    await connection.send(`${formatMail(message)}\r\n.`, 250);
+    connection.socket.end('QUIT\r\n');
+  } catch (err) {
+    connection.socket.destroy();
+    throw err;
+  }
+}
+
+function wait(ms: number): Promise<void> {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+/**
+ * Send `message` through the SMTP server in `options`, retrying network
+ * errors and transient (4xx) replies with backoff. Throws an I/O error
+ * when the server refuses the mail or retries run out, and a usage error
+ * rather than send a password over a connection without TLS.
+ */
+export async function sendMail(
+  options: SmtpOptions,
+  message: MailMessage,
+): Promise<void> {
+  const policy = { ...DEFAULT_RETRY_POLICY, ...options.retry };
+  const { sleep = wait } = options;
+  let error = '';
+  for (let retry = 0; retry < policy.attempts; retry++) {
+    if (retry > 0) await sleep(backoffDelay(policy, retry - 1));
+    try {
+      await deliverOnce(options, message);
+      return;
+    } catch (err) {
+      if (!(err instanceof Error) || isKnowgraphError(err)) throw err;
+      error = err.message;
+      const code = 'reply' in err ? (err as ReplyError).reply.code : undefined;
+      if (code !== undefined && (code < 400 || code >= 500)) {
+        throw createKnowgraphError(
+          'io',
+          `Mail to ${options.host} failed: ${error}`,
+        );
+      }
+    }
+  }
+  throw createKnowgraphError(
+    'io',
+    `Mail to ${options.host} failed after ${policy.attempts} tries: ${error}`,
+  );
+}
@@ -0,0 +1,112 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for resilient delivery to network sinks, with retry policies and a local offline queue
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [delivery, retry, backoff, queue, types, interface]
+ * context:
+ *   business_goal: Let each sink describe only its request while the shared client handles failure
+ *   domain: delivery
+ */
+import type { ConnectionOptions } from 'node:tls';
+import type { ScopedCredentials } from '../credentials/types.js';
+
+/**
+ * How often to try a delivery and how long to wait between tries. The
+ * wait doubles after each failure, starting at `initialDelayMs` and never
+ * exceeding `maxDelayMs`.
+ */
+export interface RetryPolicy {
+  /** Tries in all, including the first. */
+  readonly attempts: number;
+  readonly initialDelayMs: number;
+  readonly maxDelayMs: number;
+}
+
+/** One POST to a sink, in a form that can be queued and sent again. */
+export interface DeliveryRequest {
+  /** The sink's name, for messages. */
+  readonly sink: string;
+  readonly url: string;
+  readonly body: string;
+  readonly headers?: Readonly<Record<string, string>>;
+}
+
+/** A delivery that ran out of retries, waiting in the outbox. */
+export interface QueuedDelivery {
+  readonly request: DeliveryRequest;
+  readonly queuedAt: string;
+  /** Why the last try failed. */
+  readonly error: string;
+}
+
+/** `sent` once the sink accepted it, `queued` when it went to the outbox. */
+export type DeliveryOutcome = 'sent' | 'queued';
+
+export interface FlushResult {
+  readonly sent: number;
+  /** Deliveries the sink refused outright, which no retry would fix. */
+  readonly dropped: readonly QueuedDelivery[];
+  /** Deliveries that failed again and stay queued. */
+  readonly remaining: number;
+}
+
+export interface DeliveryClientOptions {
+  /** Overrides for `DEFAULT_RETRY_POLICY`. */
+  readonly retry?: Partial<RetryPolicy>;
+  /**
+   * The JSON Lines outbox for deliveries that run out of retries. Without
+   * one, they throw instead.
+   */
+  readonly queuePath?: string;
+  /** Waits between tries; tests pass one that does not. */
+  readonly sleep?: (ms: number) => Promise<void>;
+  /**
+   * Credentials for requests to the URLs each is scoped to. They are added
+   * at send time, so queued deliveries never hold them.
+   */
+  readonly credentials?: readonly ScopedCredentials[];
+}
+
+/** Sends requests to sinks, retrying and queueing them on failure. */
+export interface DeliveryClient {
+  /**
+   * POST `request`, retrying network errors, timeouts, rate limits, and
+   * server errors with backoff. Throws an I/O error when the sink rejects
+   * the request, or when retries run out and there is no outbox.
+   */
+  send(request: DeliveryRequest): Promise<DeliveryOutcome>;
+  /** Try everything in the outbox again, oldest first. */
+  flush(): Promise<FlushResult>;
+}
+
+/** An SMTP server to send mail through. */
+export interface SmtpOptions {
+  readonly host: string;
+  /** Default: 465 when `secure`, else 587. */
+  readonly port?: number;
+  /**
+   * TLS from the start. Otherwise the connection is upgraded with
+   * STARTTLS whenever the server offers it.
+   */
+  readonly secure?: boolean;
+  readonly username?: string;
+  readonly password?: string;
+  /** How long to wait for each reply (default: 30 seconds). */
+  readonly timeoutMs?: number;
+  /** Overrides for `DEFAULT_RETRY_POLICY`, per message. */
+  readonly retry?: Partial<RetryPolicy>;
+  /** Waits between tries; tests pass one that does not. */
+  readonly sleep?: (ms: number) => Promise<void>;
+  /** Options for TLS connections, such as a private CA. */
+  readonly tls?: ConnectionOptions;
+}
+
+/** A plain-text mail. */
+export interface MailMessage {
+  readonly from: string;
+  readonly to: readonly string[];
+  readonly subject: string;
+  readonly text: string;
+}
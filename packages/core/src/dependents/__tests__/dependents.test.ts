@@ -0,0 +1,137 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import type { EntityInsert } from '../../indexer/types.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import {
+  computeDependents,
+  hasDependents,
+  readDependents,
+  rebuildDependents,
+} from '../dependents.js';
+
+function entity(
+  name: string,
+  metadata: Omit<ExtendedMetadata, 'description'>,
+): EntityInsert {
+  return {
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: metadata.type,
+    description: `${name} for tests`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: `${name}-team`,
+    status: 'stable',
+    metadata: { ...metadata, description: `${name} for tests` },
+    tags: [],
+    links: [],
+    fileHash: null,
+  };
+}
+
+describe('dependents read model', () => {
+  let dbManager: DatabaseManager;
+
+  beforeEach(() => {
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+    dbManager.insertEntity(
+      entity('orders', {
+        type: 'service',
+        dependencies: { databases: ['postgres-main'] },
+      }),
+    );
+    dbManager.insertEntity(
+      entity('checkout', {
+        type: 'service',
+        dependencies: { services: ['orders'], external_apis: ['stripe'] },
+      }),
+    );
+    dbManager.insertEntity(
+      entity('storefront', {
+        type: 'module',
+        dependencies: { services: ['checkout'] },
+      }),
+    );
+  });
+
+  afterEach(() => {
+    dbManager.close();
+  });
+
+  it('is absent until the first rebuild', () => {
+    expect(hasDependents(dbManager)).toBe(false);
+    expect(rebuildDependents(dbManager)).toBeGreaterThan(0);
+    expect(hasDependents(dbManager)).toBe(true);
+  });
+
+  it('answers direct and transitive dependents from the table', () => {
+    rebuildDependents(dbManager);
+    expect(
+      readDependents(dbManager, 'Postgres-Main').map((record) => [
+        record.sourceName,
+        record.kind,
+        record.depth,
+      ]),
+    ).toEqual([
+      ['orders', 'database', 1],
+      ['checkout', 'service', 2],
+      ['storefront', 'service', 3],
+    ]);
+    expect(
+      readDependents(dbManager, 'postgres-main', { maxDepth: 1 }),
+    ).toHaveLength(1);
+    expect(readDependents(dbManager, 'storefront')).toEqual([]);
+  });
+
+  it('replaces the previous rows on rebuild', () => {
+    rebuildDependents(dbManager);
+    dbManager.deleteEntitiesByFilePath('src/storefront.ts');
+    rebuildDependents(dbManager);
+    expect(
+      readDependents(dbManager, 'checkout').map((r) => r.sourceName),
+    ).toEqual([]);
+  });
+
+  it('leaves out graphs without services, databases, or external APIs', () => {
+    const records = computeDependents({
+      nodes: [
+        {
+          id: 'a',
+          name: 'a',
+          entityType: 'function',
+          external: false,
+          filePath: 'a.ts',
+          owner: null,
+          domain: null,
+          workspace: null,
+        },
+        {
+          id: 'b',
+          name: 'b',
+          entityType: 'function',
+          external: false,
+          filePath: 'b.ts',
+          owner: null,
+          domain: null,
+          workspace: null,
+        },
+      ],
+      edges: [
+        {
+          from: 'a',
+          to: 'b',
+          kind: 'import',
+          provenance: 'import',
+          confidence: 1,
+        },
+      ],
+    });
+    expect(records).toEqual([]);
+  });
+});
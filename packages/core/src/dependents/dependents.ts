@@ -0,0 +1,181 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Precomputes and persists who depends on each service, database, and external API, transitively, so lookups skip the graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [dependents, graph, index, read-model, performance]
+ * context:
+ *   business_goal: Answer who depends on a service instantly, even on org-scale graphs
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { buildDependencyGraph } from '../graph/graph-builder.js';
+import type {
+  DependencyGraph,
+  DependencyGraphOptions,
+  DependencyKind,
+  GraphEdge,
+} from '../graph/types.js';
+import type { DatabaseManager } from '../indexer/database.js';
+import { INSERT_DEPENDENT_SQL } from '../indexer/schema.js';
+import { createQueryEngine } from '../query/query-engine.js';
+import type { EntityType } from '../types/entity.js';
+import type { DependentRecord, DependentsQueryOptions } from './types.js';
+
+/** `index_meta` key holding the number of rows in the last rebuild. */
+const DEPENDENTS_META_KEY = 'dependents_rows';
+
+const TARGET_KINDS: ReadonlySet<DependencyKind> = new Set([
+  'service',
+  'database',
+  'external_api',
+]);
+
+interface DependentRow {
+  readonly target_id: string;
+  readonly target_name: string;
+  readonly source_id: string;
+  readonly source_name: string;
+  readonly entity_type: string | null;
+  readonly owner: string | null;
+  readonly file_path: string | null;
+  readonly kind: string;
+  readonly depth: number;
+}
+
+/**
+ * Every dependent of every service, database, and external API in
+ * `graph`: the nodes that reach one by following edges of any kind, each
+ * with its distance and the kind of the edge it was reached by. Services
+ * are the nodes annotated `type: service` and those named by a
+ * `dependencies.services` entry.
+ */
+export function computeDependents(
+  graph: DependencyGraph,
+): readonly DependentRecord[] {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const targets = new Set(
+    graph.nodes
+      .filter((node) => node.entityType === 'service')
+      .map((node) => node.id),
+  );
+  const incoming = new Map<string, GraphEdge[]>();
+  for (const edge of graph.edges) {
+    if (TARGET_KINDS.has(edge.kind)) targets.add(edge.to);
+    const edges = incoming.get(edge.to);
+    if (edges) edges.push(edge);
+    else incoming.set(edge.to, [edge]);
+  }
+
+  const records: DependentRecord[] = [];
+  for (const target of [...targets].sort(compareStrings)) {
+    const node = nodes.get(target);
+    if (!node) continue;
+    const visited = new Set([target]);
+    let frontier = [target];
+    for (let depth = 1; frontier.length > 0; depth++) {
+      const nextFrontier: string[] = [];
+      for (const id of frontier) {
+        for (const edge of incoming.get(id) ?? []) {
+          const source = nodes.get(edge.from);
+          if (!source || visited.has(edge.from)) continue;
+          visited.add(edge.from);
+          nextFrontier.push(edge.from);
+          records.push({
+            target,
+            targetName: node.name,
+            source: source.id,
+            sourceName: source.name,
+            entityType: source.entityType,
+            owner: source.owner,
+            filePath: source.filePath,
+            kind: edge.kind,
+            depth,
+          });
+        }
+      }
+      frontier = nextFrontier;
+    }
+  }
+  return records;
+}
+
+/** Replace the persisted dependents with `records`, in one transaction. */
+export function writeDependents(
+  dbManager: DatabaseManager,
+  records: readonly DependentRecord[],
+): void {
+  const { db } = dbManager;
+  const insert = db.prepare(INSERT_DEPENDENT_SQL);
+  db.transaction(() => {
+    db.prepare('DELETE FROM dependents').run();
+    for (const record of records) {
+      insert.run({
+        target_id: record.target,
+        target_name: record.targetName,
+        source_id: record.source,
+        source_name: record.sourceName,
+        entity_type: record.entityType,
+        owner: record.owner,
+        file_path: record.filePath,
+        kind: record.kind,
+        depth: record.depth,
+      });
+    }
+    dbManager.setMeta(DEPENDENTS_META_KEY, String(records.length));
+  })();
+}
+
+/**
+ * Rebuild the persisted dependents from the entities in the index, with
+ * the graph built as `options` says. Returns the number of rows written.
+ */
+export function rebuildDependents(
+  dbManager: DatabaseManager,
+  options: DependencyGraphOptions = {},
+): number {
+  const entities = createQueryEngine(dbManager).getAll();
+  const records = computeDependents(buildDependencyGraph(entities, options));
+  writeDependents(dbManager, records);
+  return records.length;
+}
+
+/** Whether the index holds dependents, which indexes built before it lack. */
+export function hasDependents(dbManager: DatabaseManager): boolean {
+  return dbManager.getMeta(DEPENDENTS_META_KEY) !== undefined;
+}
+
+/**
+ * The persisted dependents of the nodes with id `name` or called `name`,
+ * ignoring case, nearest first. Reads one indexed table; nothing is
+ * traversed.
+ */
+export function readDependents(
+  dbManager: DatabaseManager,
+  name: string,
+  options: DependentsQueryOptions = {},
+): readonly DependentRecord[] {
+  const rows = dbManager.db
+    .prepare(
+      `SELECT * FROM dependents
+       WHERE (target_id = @name OR target_name = @name COLLATE NOCASE)
+         AND depth <= @maxDepth
+       ORDER BY target_id, depth, source_name, source_id`,
+    )
+    .all({
+      name,
+      maxDepth: options.maxDepth ?? Number.MAX_SAFE_INTEGER,
+    }) as readonly DependentRow[];
+  return rows.map((row) => ({
+    target: row.target_id,
+    targetName: row.target_name,
+    source: row.source_id,
+    sourceName: row.source_name,
+    entityType: row.entity_type as EntityType | null,
+    owner: row.owner,
+    filePath: row.file_path,
+    kind: row.kind as DependencyKind,
+    depth: row.depth,
+  }));
+}
@@ -0,0 +1,8 @@
+export type { DependentRecord, DependentsQueryOptions } from './types.js';
+export {
+  computeDependents,
+  hasDependents,
+  readDependents,
+  rebuildDependents,
+  writeDependents,
+} from './dependents.js';
@@ -0,0 +1,36 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the persisted reverse index of what depends on each service, database, and external API
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [dependents, graph, index, types, interface]
+ * context:
+ *   business_goal: Answer who depends on a service without rebuilding the graph
+ *   domain: graph
+ */
+import type { DependencyKind } from '../graph/types.js';
+import type { EntityType } from '../types/entity.js';
+
+/**
+ * A node that depends on a service, database, or external API (the
+ * target), directly or through other nodes.
+ */
+export interface DependentRecord {
+  readonly target: string;
+  readonly targetName: string;
+  readonly source: string;
+  readonly sourceName: string;
+  readonly entityType: EntityType | null;
+  readonly owner: string | null;
+  readonly filePath: string | null;
+  /** Kind of the edge the dependent was reached by. */
+  readonly kind: DependencyKind;
+  /** 1 for a direct dependent, 2 for a dependent of one, and so on. */
+  readonly depth: number;
+}
+
+export interface DependentsQueryOptions {
+  /** Only dependents at most this many hops away (default: all). */
+  readonly maxDepth?: number;
+}
@@ -0,0 +1,295 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { parseDeploymentEvents } from '../deployment-import.js';
+import { joinDeployments } from '../deployment-join.js';
+import {
+  appendDeploymentEvents,
+  currentDeployments,
+  readDeploymentLog,
+} from '../deployment-log.js';
+import type { DeploymentEvent } from '../types.js';
+
+const NOW = new Date('2024-06-01T12:00:00.000Z');
+
+const ARGOCD_APP = {
+  app: {
+    metadata: {
+      name: 'auth-prod',
+      labels: { 'app.kubernetes.io/name': 'auth', environment: 'prod' },
+    },
+    spec: { destination: { namespace: 'auth' } },
+    status: {
+      sync: { revision: '9f1c2ab' },
+      summary: { images: ['ghcr.io/acme/auth:2.3.1'] },
+      operationState: {
+        phase: 'Succeeded',
+        finishedAt: '2024-05-30T08:00:00Z',
+      },
+    },
+  },
+};
+
+const SPINNAKER_EXECUTION = {
+  details: { type: 'orca:pipeline:complete', application: 'billing' },
+  content: {
+    execution: {
+      application: 'billing',
+      status: 'SUCCEEDED',
+      endTime: Date.parse('2024-05-31T09:30:00Z'),
+      trigger: {
+        parameters: { environment: 'staging' },
+        artifacts: [{ version: '1.8.0' }],
+      },
+    },
+  },
+};
+
+function event(
+  service: string,
+  environment: string,
+  version: string,
+  deployedAt: string,
+): DeploymentEvent {
+  return { service, environment, version, deployedAt, source: 'csv' };
+}
+
+function makeEntity(
+  name: string,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `services/${name}.ts`,
+    name,
+    entityType: 'function',
+    description: `The ${name} function`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'identity',
+    status: 'stable',
+    metadata: { type: 'function', description: `The ${name} function` },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00.000Z',
+    updatedAt: '2024-01-01T00:00:00.000Z',
+    ...overrides,
+  };
+}
+
+function makeNode(
+  entity: StoredEntity,
+  overrides: Partial<GraphNode> = {},
+): GraphNode {
+  return {
+    id: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    external: false,
+    filePath: entity.filePath,
+    owner: entity.owner,
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+describe('parseDeploymentEvents', () => {
+  it('reads ArgoCD notifications', () => {
+    const events = parseDeploymentEvents(
+      JSON.stringify(ARGOCD_APP),
+      undefined,
+      NOW,
+    );
+    expect(events).toEqual([
+      {
+        service: 'auth',
+        environment: 'prod',
+        version: '2.3.1',
+        deployedAt: '2024-05-30T08:00:00.000Z',
+        source: 'argocd',
+      },
+    ]);
+  });
+
+  it('falls back to the app name, namespace, and revision', () => {
+    const app = {
+      metadata: { name: 'search' },
+      spec: { destination: { namespace: 'prod' } },
+      status: { sync: { revision: '9f1c2ab' } },
+    };
+    const [parsed] = parseDeploymentEvents(JSON.stringify(app), 'argocd', NOW);
+    expect(parsed).toMatchObject({
+      service: 'search',
+      environment: 'prod',
+      version: '9f1c2ab',
+      deployedAt: NOW.toISOString(),
+    });
+  });
+
+  it('reads Spinnaker webhooks and skips failed executions', () => {
+    const failed = {
+      content: { execution: { ...SPINNAKER_EXECUTION.content.execution } },
+    };
+    failed.content.execution.status = 'TERMINAL';
+    const events = parseDeploymentEvents(
+      JSON.stringify([SPINNAKER_EXECUTION, failed]),
+    );
+    expect(events).toEqual([
+      {
+        service: 'billing',
+        environment: 'staging',
+        version: '1.8.0',
+        deployedAt: '2024-05-31T09:30:00.000Z',
+        source: 'spinnaker',
+      },
+    ]);
+  });
+
+  it('reads CSVs with or without a time column', () => {
+    const csv = [
+      'Service,Env,Version,Deployed_At',
+      'auth,prod,2.3.0,2024-05-01T00:00:00Z',
+      'auth,staging,,2024-05-01T00:00:00Z',
+      'billing,prod,1.7.2,',
+    ].join('\n');
+    const events = parseDeploymentEvents(csv, undefined, NOW);
+    expect(events.map((e) => [e.service, e.version, e.deployedAt])).toEqual([
+      ['auth', '2.3.0', '2024-05-01T00:00:00.000Z'],
+      ['billing', '1.7.2', NOW.toISOString()],
+    ]);
+  });
+
+  it('rejects payloads it cannot read', () => {
+    expect(() => parseDeploymentEvents('service,version\na,1')).toThrow(
+      'Deployment CSV has no environment column',
+    );
+    expect(() => parseDeploymentEvents('{"hello": 1}')).toThrow(
+      'Unrecognized deployment payload',
+    );
+    expect(() =>
+      parseDeploymentEvents(
+        JSON.stringify({ content: { execution: { application: 'a' } } }),
+      ),
+    ).toThrow('spinnaker deployment event is missing environment, version');
+    expect(() =>
+      parseDeploymentEvents('service,env,version,deployed_at\na,b,1,soon'),
+    ).toThrow('Invalid deployment time: soon');
+  });
+});
+
+describe('deployment log', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-deployments-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('appends and reads events', () => {
+    const path = join(dir, 'nested', 'deployments.jsonl');
+    expect(readDeploymentLog(path)).toEqual([]);
+    const events = [event('auth', 'prod', '1', '2024-05-01T00:00:00.000Z')];
+    appendDeploymentEvents(path, events);
+    appendDeploymentEvents(path, events);
+    expect(readDeploymentLog(path)).toEqual([...events, ...events]);
+  });
+
+  it('reports the line of a corrupt entry', () => {
+    const path = join(dir, 'deployments.jsonl');
+    writeFileSync(path, '{"service":"auth"}\nnot json\n');
+    expect(() => readDeploymentLog(path)).toThrow('deployments.jsonl:2');
+  });
+
+  it('keeps the latest event per service and environment', () => {
+    const current = currentDeployments([
+      event('auth', 'prod', '2', '2024-05-02T00:00:00.000Z'),
+      event('Auth', 'Prod', '1', '2024-05-01T00:00:00.000Z'),
+      event('auth', 'staging', '3', '2024-05-03T00:00:00.000Z'),
+      event('auth', 'prod', '2.1', '2024-05-02T00:00:00.000Z'),
+    ]);
+    expect(current.map((e) => `${e.environment}:${e.version}`)).toEqual([
+      'prod:2.1',
+      'staging:3',
+    ]);
+  });
+});
+
+describe('joinDeployments', () => {
+  const login = makeEntity('HandleLogin', { status: 'deprecated' });
+  const session = makeEntity('SessionStore');
+  const auth = makeEntity('auth-service', {
+    entityType: 'service',
+    metadata: { type: 'service', description: 'Auth', aliases: ['auth'] },
+  });
+  const entities = [login, session, auth];
+  const graph: DependencyGraph = {
+    nodes: [
+      makeNode(login, { workspace: 'auth' }),
+      makeNode(session, { workspace: 'sessions' }),
+      makeNode(auth, { aliases: ['auth'] }),
+      {
+        id: 'service:auth',
+        name: 'auth',
+        entityType: null,
+        external: true,
+        filePath: null,
+        owner: null,
+        domain: null,
+        workspace: null,
+      },
+    ],
+    edges: [],
+  };
+  const events = [
+    event('auth', 'prod', '2.3.1', '2024-05-30T08:00:00.000Z'),
+    event('auth', 'staging', '2.4.0', '2024-05-31T08:00:00.000Z'),
+    event('sessions', 'staging', '0.9.0', '2024-05-31T08:00:00.000Z'),
+  ];
+
+  it('matches nodes by name, alias, and workspace', () => {
+    const deployed = joinDeployments(entities, graph, events);
+    const labels = deployed.map(
+      (d) => `${d.environment}:${d.name}@${d.version}`,
+    );
+    expect(labels).toEqual([
+      'prod:auth-service@2.3.1',
+      'prod:HandleLogin@2.3.1',
+      'staging:auth-service@2.4.0',
+      'staging:HandleLogin@2.4.0',
+      'staging:SessionStore@0.9.0',
+    ]);
+  });
+
+  it('finds deprecated nodes still live in an environment', () => {
+    const deployed = joinDeployments(entities, graph, events, {
+      environment: 'PROD',
+      status: 'deprecated',
+      name: 'handlelogin',
+    });
+    expect(deployed).toEqual([
+      {
+        entityId: 'id-HandleLogin',
+        name: 'HandleLogin',
+        entityType: 'function',
+        status: 'deprecated',
+        owner: 'identity',
+        service: 'auth',
+        environment: 'prod',
+        version: '2.3.1',
+        deployedAt: '2024-05-30T08:00:00.000Z',
+      },
+    ]);
+  });
+});
@@ -0,0 +1,239 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses ArgoCD notification and Spinnaker webhook payloads and deployment CSVs into deployment events
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [deployments, argocd, spinnaker, csv, import]
+ * context:
+ *   business_goal: Normalize rollout records from CD tools so they can be joined to the code graph
+ *   domain: deployments
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import { parseCsv } from '../finops/csv.js';
+import type { DeploymentEvent, DeploymentSource } from './types.js';
+
+type Json = Record<string, unknown>;
+
+const CSV_COLUMNS = {
+  service: ['service', 'app', 'application'],
+  environment: ['environment', 'env'],
+  version: ['version', 'revision'],
+  deployedAt: ['deployed_at', 'deployedat', 'timestamp'],
+} as const;
+
+function isObject(value: unknown): value is Json {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+function object(value: unknown): Json {
+  return isObject(value) ? value : {};
+}
+
+function text(value: unknown): string | undefined {
+  if (typeof value === 'number') return String(value);
+  return typeof value === 'string' && value.trim() !== ''
+    ? value.trim()
+    : undefined;
+}
+
+function timestamp(value: unknown, now: Date): string {
+  if (value === undefined || value === null || value === '') {
+    return now.toISOString();
+  }
+  const time =
+    typeof value === 'number' ? value : Date.parse(String(value).trim());
+  if (Number.isNaN(time)) {
+    throw createKnowgraphError(
+      'parse',
+      `Invalid deployment time: ${String(value)}`,
+    );
+  }
+  return new Date(time).toISOString();
+}
+
+function required(
+  source: DeploymentSource,
+  fields: Readonly<Record<string, string | undefined>>,
+): void {
+  const missing = Object.keys(fields).filter((key) => !fields[key]);
+  if (missing.length > 0) {
+    throw createKnowgraphError(
+      'parse',
+      `${source} deployment event is missing ${missing.join(', ')}`,
+    );
+  }
+}
+
+/** The tag of an image reference such as `ghcr.io/acme/api:1.4.2`. */
+function imageTag(image: string): string | undefined {
+  const name = image.split('@')[0].split('/').pop() ?? '';
+  const colon = name.lastIndexOf(':');
+  return colon === -1 ? undefined : name.slice(colon + 1) || undefined;
+}
+
+/**
+ * An ArgoCD Application, as sent by a notifications webhook (bare or under
+ * `app`). The service is the `app.kubernetes.io/name` label or the app
+ * name, the environment its `environment` label or target namespace, and
+ * the version its first image tag or synced revision. Returns undefined for
+ * a sync that has not succeeded.
+ */
+function argoCdEvent(payload: Json, now: Date): DeploymentEvent | undefined {
+  const app = isObject(payload.app) ? payload.app : payload;
+  const metadata = object(app.metadata);
+  const labels = object(metadata.labels);
+  const status = object(app.status);
+  const operation = object(status.operationState);
+  const phase = text(operation.phase);
+  if (phase && phase !== 'Succeeded') return undefined;
+
+  const images = Array.isArray(object(status.summary).images)
+    ? (object(status.summary).images as unknown[])
+    : [];
+  const tagged = images
+    .map((image) => (typeof image === 'string' ? imageTag(image) : undefined))
+    .find(Boolean);
+  const event = {
+    service: text(labels['app.kubernetes.io/name']) ?? text(metadata.name),
+    environment:
+      text(labels.environment) ??
+      text(labels.env) ??
+      text(object(object(app.spec).destination).namespace),
+    version:
+      tagged ??
+      text(object(status.sync).revision) ??
+      text(object(operation.syncResult).revision),
+  };
+  required('argocd', event);
+  return {
+    ...(event as Omit<DeploymentEvent, 'deployedAt' | 'source'>),
+    deployedAt: timestamp(operation.finishedAt ?? status.reconciledAt, now),
+    source: 'argocd',
+  };
+}
+
+/**
+ * A Spinnaker echo webhook for a pipeline execution. The service is the
+ * application, and the environment and version come from the trigger's
+ * `environment` and `version` parameters, or the version from its first
+ * artifact. Returns undefined for an execution that has not succeeded.
+ */
+function spinnakerEvent(payload: Json, now: Date): DeploymentEvent | undefined {
+  const execution = object(object(payload.content).execution);
+  const status = text(execution.status);
+  if (status && status !== 'SUCCEEDED') return undefined;
+
+  const trigger = object(execution.trigger);
+  const parameters = object(trigger.parameters);
+  const artifacts = Array.isArray(trigger.artifacts) ? trigger.artifacts : [];
+  const event = {
+    service:
+      text(execution.application) ?? text(object(payload.details).application),
+    environment: text(parameters.environment) ?? text(parameters.env),
+    version: text(parameters.version) ?? text(object(artifacts[0]).version),
+  };
+  required('spinnaker', event);
+  return {
+    ...(event as Omit<DeploymentEvent, 'deployedAt' | 'source'>),
+    deployedAt: timestamp(execution.endTime, now),
+    source: 'spinnaker',
+  };
+}
+
+/** Which CD tool sent `payload`, or undefined when neither did. */
+function detectPayloadSource(payload: Json): DeploymentSource | undefined {
+  if (isObject(payload.content) && isObject(payload.content.execution)) {
+    return 'spinnaker';
+  }
+  const app = isObject(payload.app) ? payload.app : payload;
+  if (isObject(app.metadata) && (isObject(app.spec) || isObject(app.status))) {
+    return 'argocd';
+  }
+  return undefined;
+}
+
+function parseCsvEvents(input: string, now: Date): readonly DeploymentEvent[] {
+  const [header, ...rows] = parseCsv(input);
+  if (!header) return [];
+  const names = header.map((column) => column.trim().toLowerCase());
+  const find = (candidates: readonly string[]) =>
+    names.findIndex((name) => candidates.includes(name));
+  const columns = {
+    service: find(CSV_COLUMNS.service),
+    environment: find(CSV_COLUMNS.environment),
+    version: find(CSV_COLUMNS.version),
+  };
+  const missing = Object.keys(columns).filter(
+    (key) => columns[key as keyof typeof columns] === -1,
+  );
+  if (missing.length > 0) {
+    throw createKnowgraphError(
+      'parse',
+      `Deployment CSV has no ${missing.join(', ')} column`,
+    );
+  }
+  const deployedAt = find(CSV_COLUMNS.deployedAt);
+
+  const events: DeploymentEvent[] = [];
+  for (const row of rows) {
+    const service = text(row[columns.service]);
+    const environment = text(row[columns.environment]);
+    const version = text(row[columns.version]);
+    if (!service || !environment || !version) continue;
+    events.push({
+      service,
+      environment,
+      version,
+      deployedAt: timestamp(
+        deployedAt === -1 ? undefined : row[deployedAt],
+        now,
+      ),
+      source: 'csv',
+    });
+  }
+  return events;
+}
+
+/**
+ * Parse deployment events from `input`: a CSV with `service`,
+ * `environment`, and `version` columns (and optionally `deployed_at`), or a
+ * JSON ArgoCD or Spinnaker webhook payload or array of them. The source is
+ * detected when not given. Events without a time are stamped `now`; failed
+ * rollouts are skipped.
+ */
+export function parseDeploymentEvents(
+  input: string,
+  source?: DeploymentSource,
+  now: Date = new Date(),
+): readonly DeploymentEvent[] {
+  const trimmed = input.trim();
+  const json = trimmed.startsWith('{') || trimmed.startsWith('[');
+  if (source === 'csv' || (!source && !json)) return parseCsvEvents(input, now);
+
+  let parsed: unknown;
+  try {
+    parsed = JSON.parse(trimmed);
+  } catch {
+    throw createKnowgraphError('parse', 'Deployment payload is not valid JSON');
+  }
+  const payloads = Array.isArray(parsed) ? parsed : [parsed];
+  const events: DeploymentEvent[] = [];
+  for (const payload of payloads) {
+    const kind = isObject(payload)
+      ? (source ?? detectPayloadSource(payload))
+      : undefined;
+    if (!kind || !isObject(payload)) {
+      throw createKnowgraphError(
+        'parse',
+        'Unrecognized deployment payload: expected an ArgoCD or Spinnaker webhook',
+      );
+    }
+    const event =
+      kind === 'argocd'
+        ? argoCdEvent(payload, now)
+        : spinnakerEvent(payload, now);
+    if (event) events.push(event);
+  }
+  return events;
+}
@@ -0,0 +1,67 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Joins the deployments live in each environment to graph nodes by name, alias, and workspace
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [deployments, graph, join]
+ * context:
+ *   business_goal: Answer questions like whether a deprecated function is still running in production
+ *   domain: deployments
+ */
+import { createServiceMatcher } from '../graph/graph-services.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { currentDeployments } from './deployment-log.js';
+import type {
+  DeployedNode,
+  DeploymentEvent,
+  DeploymentFilter,
+} from './types.js';
+
+/**
+ * The nodes each live deployment in `events` runs (see
+ * `createServiceMatcher`). Only the latest event per service and environment
+ * counts (see `currentDeployments`). Sorted by environment, then node name.
+ */
+export function joinDeployments(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  events: readonly DeploymentEvent[],
+  filter: DeploymentFilter = {},
+): readonly DeployedNode[] {
+  const byId = new Map(entities.map((entity) => [entity.id, entity]));
+  const nodes = graph.nodes.filter((node) => byId.has(node.id));
+  const servedBy = createServiceMatcher(nodes);
+  const environment = filter.environment?.toLowerCase();
+  const name = filter.name?.toLowerCase();
+
+  const deployed: DeployedNode[] = [];
+  for (const event of currentDeployments(events)) {
+    if (environment && event.environment.toLowerCase() !== environment) {
+      continue;
+    }
+    for (const node of servedBy(event.service)) {
+      const entity = byId.get(node.id)!;
+      if (filter.status && entity.status !== filter.status) continue;
+      if (name && node.name.toLowerCase() !== name) continue;
+      deployed.push({
+        entityId: node.id,
+        name: node.name,
+        entityType: node.entityType,
+        status: entity.status,
+        owner: node.owner,
+        service: event.service,
+        environment: event.environment,
+        version: event.version,
+        deployedAt: event.deployedAt,
+      });
+    }
+  }
+  return deployed.sort(
+    (a, b) =>
+      a.environment.localeCompare(b.environment) ||
+      a.name.localeCompare(b.name) ||
+      a.service.localeCompare(b.service),
+  );
+}
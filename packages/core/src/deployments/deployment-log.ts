@@ -0,0 +1,76 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads and appends the JSON Lines deployment log and reduces it to the version live per service and environment
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [deployments, jsonl, log]
+ * context:
+ *   business_goal: Keep a durable record of rollouts so the graph can say what runs where
+ *   domain: deployments
+ */
+import { appendFileSync, existsSync, mkdirSync, readFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DeploymentEvent } from './types.js';
+
+/**
+ * Parse the deployment log at `logPath` in the order events were imported.
+ * A missing file is empty. Throws on a line that is not JSON, with its
+ * line number.
+ */
+export function readDeploymentLog(
+  logPath: string,
+): readonly DeploymentEvent[] {
+  if (!existsSync(logPath)) return [];
+  const events: DeploymentEvent[] = [];
+  const lines = readFileSync(logPath, 'utf-8').split('\n');
+  lines.forEach((line, index) => {
+    if (line.trim() === '') return;
+    try {
+      events.push(JSON.parse(line) as DeploymentEvent);
+    } catch {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid deployment log entry at ${logPath}:${index + 1}`,
+      );
+    }
+  });
+  return events;
+}
+
+/** Append `events` to the log, creating the file when needed. */
+export function appendDeploymentEvents(
+  logPath: string,
+  events: readonly DeploymentEvent[],
+): void {
+  if (events.length === 0) return;
+  mkdirSync(dirname(logPath), { recursive: true });
+  const lines = events.map((event) => `${JSON.stringify(event)}\n`);
+  appendFileSync(logPath, lines.join(''), 'utf-8');
+}
+
+/**
+ * The latest event per service and environment (both compared
+ * case-insensitively), sorted by environment then service. Of two events
+ * with the same time, the one imported later wins, so re-importing a
+ * corrected record replaces the original.
+ */
+export function currentDeployments(
+  events: readonly DeploymentEvent[],
+): readonly DeploymentEvent[] {
+  const latest = new Map<string, DeploymentEvent>();
+  for (const event of events) {
+    const environment = event.environment.toLowerCase();
+    const key = `${environment}\0${event.service.toLowerCase()}`;
+    const previous = latest.get(key);
+    if (!previous || event.deployedAt >= previous.deployedAt) {
+      latest.set(key, event);
+    }
+  }
+  return [...latest.values()].sort(
+    (a, b) =>
+      a.environment.localeCompare(b.environment) ||
+      a.service.localeCompare(b.service),
+  );
+}
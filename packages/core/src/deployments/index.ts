@@ -0,0 +1,13 @@
+export type {
+  DeploymentSource,
+  DeploymentEvent,
+  DeployedNode,
+  DeploymentFilter,
+} from './types.js';
+export { parseDeploymentEvents } from './deployment-import.js';
+export {
+  readDeploymentLog,
+  appendDeploymentEvents,
+  currentDeployments,
+} from './deployment-log.js';
+export { joinDeployments } from './deployment-join.js';
@@ -0,0 +1,45 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for deployment events and the versions of annotated nodes live in each environment
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [deployments, argocd, spinnaker, types, interface]
+ * context:
+ *   business_goal: Define contracts for joining what is deployed where to the code graph
+ *   domain: deployments
+ */
+import type { EntityType, Status } from '../types/entity.js';
+
+export type DeploymentSource = 'argocd' | 'spinnaker' | 'csv';
+
+/** One rollout of `version` of `service` to `environment`. */
+export interface DeploymentEvent {
+  readonly service: string;
+  readonly environment: string;
+  readonly version: string;
+  /** ISO 8601 time the rollout finished. */
+  readonly deployedAt: string;
+  readonly source: DeploymentSource;
+}
+
+/** A node with the version of it live in one environment. */
+export interface DeployedNode {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType | null;
+  readonly status: Status | null;
+  readonly owner: string | null;
+  /** The deployed service the node was matched through. */
+  readonly service: string;
+  readonly environment: string;
+  readonly version: string;
+  readonly deployedAt: string;
+}
+
+export interface DeploymentFilter {
+  readonly environment?: string;
+  readonly status?: Status;
+  /** Node name, matched case-insensitively. */
+  readonly name?: string;
+}
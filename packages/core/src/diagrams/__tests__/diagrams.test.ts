@@ -0,0 +1,44 @@
+import { describe, it, expect } from 'vitest';
+import {
+  entityDiagrams,
+  isDiagramImage,
+  isDiagramPath,
+} from '../diagrams.js';
+
+describe('isDiagramPath', () => {
+  it('accepts SVG, PNG, and Excalidraw files', () => {
+    expect(isDiagramPath('docs/flow.svg')).toBe(true);
+    expect(isDiagramPath('docs/flow.PNG')).toBe(true);
+    expect(isDiagramPath('docs/flow.excalidraw')).toBe(true);
+    expect(isDiagramPath('docs/flow.pdf')).toBe(false);
+  });
+
+  it('shows images inline but not Excalidraw scenes', () => {
+    expect(isDiagramImage('flow.svg')).toBe(true);
+    expect(isDiagramImage('flow.excalidraw')).toBe(false);
+  });
+});
+
+describe('entityDiagrams', () => {
+  it('resolves diagrams relative to the annotated file', () => {
+    expect(
+      entityDiagrams({
+        filePath: 'src/payments/charge.ts',
+        metadata: {
+          type: 'module',
+          description: 'Charges cards',
+          diagrams: ['./charge-flow.svg', '../../docs/payments.excalidraw'],
+        },
+      }),
+    ).toEqual(['src/payments/charge-flow.svg', 'docs/payments.excalidraw']);
+  });
+
+  it('is empty without diagrams', () => {
+    expect(
+      entityDiagrams({
+        filePath: 'src/a.ts',
+        metadata: { type: 'function', description: 'Does a thing' },
+      }),
+    ).toEqual([]);
+  });
+});
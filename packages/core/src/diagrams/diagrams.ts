@@ -0,0 +1,53 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads the architecture diagrams an annotation attaches and resolves them to repository paths
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [diagrams, annotations, architecture, images]
+ * context:
+ *   business_goal: Put architecture pictures next to the code they describe, where readers of the graph find them
+ *   domain: annotations
+ */
+import { posix } from 'node:path';
+import type { StoredEntity } from '../indexer/types.js';
+import { toPosixPath } from '../paths/paths.js';
+import type { CoreMetadata, ExtendedMetadata } from '../types/entity.js';
+
+/** The formats `diagrams` may hold: images, and Excalidraw scenes. */
+export const DIAGRAM_EXTENSIONS: readonly string[] = [
+  '.svg',
+  '.png',
+  '.excalidraw',
+];
+
+const IMAGE_EXTENSIONS = new Set(['.svg', '.png']);
+
+export function isDiagramPath(path: string): boolean {
+  return DIAGRAM_EXTENSIONS.includes(posix.extname(path).toLowerCase());
+}
+
+/** Whether a page can show the diagram inline, rather than link to it. */
+export function isDiagramImage(path: string): boolean {
+  return IMAGE_EXTENSIONS.has(posix.extname(path).toLowerCase());
+}
+
+/** The `diagrams` an annotation lists, as written. */
+export function declaredDiagrams(
+  metadata: CoreMetadata | ExtendedMetadata,
+): readonly string[] {
+  return 'diagrams' in metadata ? (metadata.diagrams ?? []) : [];
+}
+
+/**
+ * The diagrams `entity` attaches, relative to the indexed root rather than
+ * to the entity's file as they are written.
+ */
+export function entityDiagrams(
+  entity: Pick<StoredEntity, 'filePath' | 'metadata'>,
+): readonly string[] {
+  const dir = posix.dirname(toPosixPath(entity.filePath));
+  return declaredDiagrams(entity.metadata).map((path) =>
+    posix.normalize(posix.join(dir, toPosixPath(path))),
+  );
+}
@@ -0,0 +1,7 @@
+export {
+  DIAGRAM_EXTENSIONS,
+  declaredDiagrams,
+  entityDiagrams,
+  isDiagramImage,
+  isDiagramPath,
+} from './diagrams.js';
@@ -0,0 +1,181 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  ARCHITECTURE_END,
+  ARCHITECTURE_START,
+  planReadmeUpdates,
+  renderArchitectureSection,
+  updateArchitectureSection,
+} from '../readme-sections.js';
+import { externalNode } from '../../graph/graph-builder.js';
+import type {
+  DependencyGraph,
+  GraphEdge,
+  GraphNode,
+} from '../../graph/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+
+function entity(
+  id: string,
+  filePath: string,
+  metadata: Partial<ExtendedMetadata> = {},
+): StoredEntity {
+  return {
+    id,
+    name: id,
+    filePath,
+    entityType: 'module',
+    description: `The ${id} module`,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: { type: 'module', description: `The ${id} module`, ...metadata },
+  } as StoredEntity;
+}
+
+function node(id: string, domain: string | null = null): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'module',
+    external: false,
+    filePath: null,
+    owner: null,
+    domain,
+    workspace: null,
+  };
+}
+
+function edge(
+  from: string,
+  to: string,
+  kind: GraphEdge['kind'] = 'service',
+): GraphEdge {
+  return { from, to, kind, provenance: 'declared', confidence: 1 };
+}
+
+const stripe = externalNode('external_api', 'stripe');
+const GRAPH: DependencyGraph = {
+  nodes: [node('billing', 'payments'), node('ledger'), node('web'), stripe],
+  edges: [
+    edge('billing', 'ledger'),
+    edge('billing', stripe.id, 'external_api'),
+    edge('billing', 'ledger', 'import'),
+    edge('web', 'billing'),
+  ],
+};
+
+describe('renderArchitectureSection', () => {
+  it('describes a module from its node', () => {
+    const billing = entity('billing', 'services/billing/doc.go', {
+      diagrams: ['flow.svg', '../../docs/billing.excalidraw'],
+    });
+    expect(
+      renderArchitectureSection([billing], GRAPH, 'services/billing'),
+    ).toBe(
+      [
+        ARCHITECTURE_START,
+        '## Architecture',
+        '',
+        '_Generated by `knowgraph docsgen` from the `@knowgraph` annotations; edit those rather than this section._',
+        '',
+        'The billing module',
+        '',
+        '**Owner:** payments-team · **Status:** stable · **Domain:** payments',
+        '',
+        '**Depends on**',
+        '',
+        '- Services: ledger',
+        '- External APIs: stripe (external)',
+        '',
+        '**Used by:** web',
+        '',
+        '![flow.svg](./flow.svg)',
+        '',
+        '[billing.excalidraw](../../docs/billing.excalidraw)',
+        ARCHITECTURE_END,
+      ].join('\n'),
+    );
+  });
+});
+
+describe('updateArchitectureSection', () => {
+  const section = `${ARCHITECTURE_START}\nnew\n${ARCHITECTURE_END}`;
+
+  it('replaces the section between the markers and keeps the rest', () => {
+    const readme = `# Billing\n\n${ARCHITECTURE_START}\nold\n${ARCHITECTURE_END}\n\n## Usage\n`;
+    expect(updateArchitectureSection(readme, section, 'billing')).toBe(
+      `# Billing\n\n${section}\n\n## Usage\n`,
+    );
+  });
+
+  it('appends to a README without one and creates a missing README', () => {
+    expect(updateArchitectureSection('# Billing\n\n', section, 'x')).toBe(
+      `# Billing\n\n${section}\n`,
+    );
+    expect(updateArchitectureSection(undefined, section, 'billing')).toBe(
+      `# billing\n\n${section}\n`,
+    );
+  });
+
+  it('leaves a hand-written Architecture section alone', () => {
+    expect(
+      updateArchitectureSection('## Architecture\n\nMine\n', section, 'x'),
+    ).toBeUndefined();
+  });
+});
+
+describe('planReadmeUpdates', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-docsgen-'));
+    for (const name of ['billing', 'ledger', 'web']) {
+      mkdirSync(join(dir, name));
+    }
+    writeFileSync(join(dir, 'billing', 'Readme.md'), '# Billing\n');
+    writeFileSync(join(dir, 'web', 'README.md'), '## Architecture\n');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('plans an update per module directory', () => {
+    const entities = [
+      entity('billing', 'billing/doc.go'),
+      entity('ledger', 'ledger/doc.go'),
+      entity('web', 'web/main.go'),
+      { ...entity('helper', 'billing/helper.go'), entityType: 'function' },
+    ] as StoredEntity[];
+
+    const plain = planReadmeUpdates(entities, GRAPH, { rootDir: dir });
+    expect(
+      plain.updates.map((update) => [update.path, update.status]),
+    ).toEqual([['billing/Readme.md', 'updated']]);
+    expect(plain.skipped).toEqual([
+      { path: 'ledger/README.md', reason: 'no README to update' },
+      {
+        path: 'web/README.md',
+        reason: expect.stringContaining('has its own Architecture section'),
+      },
+    ]);
+
+    const created = planReadmeUpdates(entities, GRAPH, {
+      rootDir: dir,
+      create: true,
+    });
+    expect(created.updates[1]).toMatchObject({
+      path: 'ledger/README.md',
+      status: 'created',
+      before: undefined,
+    });
+    expect(created.updates[1].after).toMatch(/^# ledger\n\n/);
+
+    writeFileSync(join(dir, 'billing', 'Readme.md'), plain.updates[0].after);
+    const again = planReadmeUpdates(entities, GRAPH, { rootDir: dir });
+    expect(again.updates[0].status).toBe('unchanged');
+  });
+});
@@ -0,0 +1,16 @@
+export type {
+  DocsgenOptions,
+  DocsgenResult,
+  ReadmeStatus,
+  ReadmeUpdate,
+  SkippedReadme,
+} from './types.js';
+export {
+  ARCHITECTURE_END,
+  ARCHITECTURE_START,
+  modulesByDirectory,
+  planReadmeUpdates,
+  renderArchitectureSection,
+  renderModuleMarkdown,
+  updateArchitectureSection,
+} from './readme-sections.js';
@@ -0,0 +1,266 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Generates and updates the Architecture section of each module's README from its node's description, owner, dependencies, and diagrams
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [docsgen, readme, markdown, architecture, diagrams]
+ * context:
+ *   business_goal: Regenerate only the part of a README the graph owns and leave what people wrote alone
+ *   domain: docsgen
+ */
+import { existsSync, readFileSync, readdirSync } from 'node:fs';
+import { basename, join, posix, resolve } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import { entityDiagrams, isDiagramImage } from '../diagrams/diagrams.js';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  GraphNode,
+} from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { toPosixPath } from '../paths/paths.js';
+import type {
+  DocsgenOptions,
+  DocsgenResult,
+  ReadmeUpdate,
+  SkippedReadme,
+} from './types.js';
+
+export const ARCHITECTURE_START = '<!-- knowgraph:architecture:start -->';
+export const ARCHITECTURE_END = '<!-- knowgraph:architecture:end -->';
+
+/** Dependencies worth a reader's attention, with their headings. */
+const DEPENDENCY_LABELS: ReadonlyArray<readonly [DependencyKind, string]> = [
+  ['service', 'Services'],
+  ['external_api', 'External APIs'],
+  ['database', 'Databases'],
+];
+
+function nodeName(node: GraphNode): string {
+  return node.external ? `${node.name} (external)` : node.name;
+}
+
+/** A path from the README's directory, as Markdown links want it. */
+function fromReadme(readmeDir: string, path: string): string {
+  const relative = posix.relative(readmeDir, path);
+  return relative.startsWith('.') ? relative : `./${relative}`;
+}
+
+function moduleLines(
+  entity: StoredEntity,
+  graph: DependencyGraph,
+  nodes: ReadonlyMap<string, GraphNode>,
+  readmeDir: string,
+): string[] {
+  const node = nodes.get(entity.id);
+  const lines = [entity.description];
+
+  const facts = [
+    ...(entity.owner ? [`**Owner:** ${entity.owner}`] : []),
+    ...(entity.status ? [`**Status:** ${entity.status}`] : []),
+    ...(node?.domain ? [`**Domain:** ${node.domain}`] : []),
+  ];
+  if (facts.length > 0) lines.push('', facts.join(' · '));
+
+  const outgoing = graph.edges.filter(
+    (edge) => edge.from === entity.id && edge.to !== entity.id,
+  );
+  const dependencies = DEPENDENCY_LABELS.flatMap(([kind, label]) => {
+    const names = [
+      ...new Set(
+        outgoing
+          .filter((edge) => edge.kind === kind)
+          .flatMap((edge) => {
+            const target = nodes.get(edge.to);
+            return target ? [nodeName(target)] : [];
+          }),
+      ),
+    ].sort(compareStrings);
+    return names.length > 0 ? [`- ${label}: ${names.join(', ')}`] : [];
+  });
+  if (dependencies.length > 0) {
+    lines.push('', '**Depends on**', '', ...dependencies);
+  }
+
+  const dependents = [
+    ...new Set(
+      graph.edges
+        .filter(
+          (edge) =>
+            edge.to === entity.id &&
+            edge.from !== entity.id &&
+            DEPENDENCY_LABELS.some(([kind]) => kind === edge.kind),
+        )
+        .flatMap((edge) => {
+          const source = nodes.get(edge.from);
+          return source ? [nodeName(source)] : [];
+        }),
+    ),
+  ].sort(compareStrings);
+  if (dependents.length > 0) {
+    lines.push('', `**Used by:** ${dependents.join(', ')}`);
+  }
+
+  for (const diagram of entityDiagrams(entity)) {
+    const title = posix.basename(diagram);
+    const target = fromReadme(readmeDir, diagram);
+    lines.push(
+      '',
+      isDiagramImage(diagram)
+        ? `![${title}](${target})`
+        : `[${title}](${target})`,
+    );
+  }
+  return lines;
+}
+
+/**
+ * The Markdown describing the modules of one directory: each module's
+ * description, owner, status, domain, dependencies, dependents, and
+ * diagrams, under a heading of its own when there are several.
+ * `readmeDir` is the page's directory, for the diagram links.
+ */
+export function renderModuleMarkdown(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  readmeDir: string,
+): string {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const lines: string[] = [];
+  const sorted = [...entities].sort((a, b) => compareStrings(a.name, b.name));
+  for (const entity of sorted) {
+    if (lines.length > 0) lines.push('');
+    if (sorted.length > 1) lines.push(`### ${entity.name}`, '');
+    lines.push(...moduleLines(entity, graph, nodes, readmeDir));
+  }
+  return lines.join('\n');
+}
+
+/**
+ * The Architecture section for the modules of one directory, as
+ * `renderModuleMarkdown` describes them, between the marker comments.
+ */
+export function renderArchitectureSection(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  readmeDir: string,
+): string {
+  return [
+    ARCHITECTURE_START,
+    '## Architecture',
+    '',
+    '_Generated by `knowgraph docsgen` from the `@knowgraph` annotations; edit those rather than this section._',
+    '',
+    renderModuleMarkdown(entities, graph, readmeDir),
+    ARCHITECTURE_END,
+  ].join('\n');
+}
+
+/**
+ * `readme` with `section` in place of the one between the markers, or
+ * appended when it has none. Undefined when the README already has an
+ * Architecture heading of its own, which is left for a person to replace.
+ * A missing README becomes a new one titled `title`.
+ */
+export function updateArchitectureSection(
+  readme: string | undefined,
+  section: string,
+  title: string,
+): string | undefined {
+  if (readme === undefined) return `# ${title}\n\n${section}\n`;
+  const eol = readme.includes('\r\n') ? '\r\n' : '\n';
+  const replacement = section.replace(/\n/g, eol);
+  const start = readme.indexOf(ARCHITECTURE_START);
+  const end = readme.indexOf(ARCHITECTURE_END, start);
+  if (start !== -1 && end !== -1) {
+    return (
+      readme.slice(0, start) +
+      replacement +
+      readme.slice(end + ARCHITECTURE_END.length)
+    );
+  }
+  if (/^##\s+Architecture\s*$/im.test(readme)) return undefined;
+  const body = readme.replace(/(\r?\n)*$/, '');
+  return `${body}${eol}${eol}${replacement}${eol}`;
+}
+
+function findReadme(rootDir: string, dir: string): string | undefined {
+  const absolute = join(rootDir, dir);
+  if (!existsSync(absolute)) return undefined;
+  const name = readdirSync(absolute).find((file) =>
+    /^readme\.md$/i.test(file),
+  );
+  return name ? posix.join(dir, name) : undefined;
+}
+
+/**
+ * The entities of type `module`, by the directory of their file, in
+ * directory order. A directory's modules share its README.
+ */
+export function modulesByDirectory(
+  entities: readonly StoredEntity[],
+): ReadonlyMap<string, readonly StoredEntity[]> {
+  const byDir = new Map<string, StoredEntity[]>();
+  for (const entity of entities) {
+    if (entity.entityType !== 'module') continue;
+    const dir = posix.dirname(toPosixPath(entity.filePath));
+    byDir.set(dir, [...(byDir.get(dir) ?? []), entity]);
+  }
+  return new Map([...byDir].sort(([a], [b]) => compareStrings(a, b)));
+}
+
+/**
+ * The README changes that bring the Architecture section of every
+ * directory in `modulesByDirectory` in line with the graph. Directories
+ * without a README are skipped unless `create` is set, as are READMEs
+ * with a hand-written Architecture section and no markers.
+ */
+export function planReadmeUpdates(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  options: DocsgenOptions,
+): DocsgenResult {
+  const updates: ReadmeUpdate[] = [];
+  const skipped: SkippedReadme[] = [];
+  for (const [dir, modules] of modulesByDirectory(entities)) {
+    const existing = findReadme(options.rootDir, dir);
+    const path = existing ?? posix.join(dir, 'README.md');
+    if (!existing && !options.create) {
+      skipped.push({ path, reason: 'no README to update' });
+      continue;
+    }
+    const before = existing
+      ? readFileSync(join(options.rootDir, existing), 'utf-8')
+      : undefined;
+    const title =
+      modules.length === 1
+        ? modules[0].name
+        : basename(resolve(options.rootDir, dir));
+    const after = updateArchitectureSection(
+      before,
+      renderArchitectureSection(modules, graph, dir),
+      title,
+    );
+    if (after === undefined) {
+      skipped.push({
+        path,
+        reason: `has its own Architecture section; put it between the ${ARCHITECTURE_START} and ${ARCHITECTURE_END} markers to have it generated`,
+      });
+      continue;
+    }
+    updates.push({
+      path,
+      entities: modules.map((entity) => entity.id).sort(compareStrings),
+      status:
+        before === undefined
+          ? 'created'
+          : before === after
+            ? 'unchanged'
+            : 'updated',
+      before,
+      after,
+    });
+  }
+  return { updates, skipped };
+}
@@ -0,0 +1,41 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for generating the Architecture section of module READMEs from the graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [docsgen, readme, markdown, types, interface]
+ * context:
+ *   business_goal: Let other publishers reuse generated module docs without regenerating them
+ *   domain: docsgen
+ */
+
+export type ReadmeStatus = 'created' | 'updated' | 'unchanged';
+
+export interface ReadmeUpdate {
+  /** Relative to the root, like entity file paths. */
+  readonly path: string;
+  /** Ids of the modules the section describes. */
+  readonly entities: readonly string[];
+  readonly status: ReadmeStatus;
+  /** Undefined when the README does not exist yet. */
+  readonly before: string | undefined;
+  readonly after: string;
+}
+
+export interface SkippedReadme {
+  readonly path: string;
+  readonly reason: string;
+}
+
+export interface DocsgenOptions {
+  /** Directory the entity file paths are relative to. */
+  readonly rootDir: string;
+  /** Write a README for module directories that have none. */
+  readonly create?: boolean;
+}
+
+export interface DocsgenResult {
+  readonly updates: readonly ReadmeUpdate[];
+  readonly skipped: readonly SkippedReadme[];
+}
@@ -0,0 +1,202 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager } from '../../indexer/database.js';
+import { INDEX_SCHEMA_VERSION } from '../../indexer/schema.js';
+import { createDefaultRegistry } from '../../parsers/registry.js';
+import {
+  checkAnnotations,
+  checkConfig,
+  checkEnrichmentCache,
+  checkFileLimits,
+  checkGit,
+  checkStore,
+  scanParserCoverage,
+} from '../doctor.js';
+
+const VALID = `/**
+ * @knowgraph
+ * type: service
+ * description: Charges cards
+ */
+export function charge() {}
+`;
+
+const INVALID = `/**
+ * @knowgraph
+ * type: [unclosed
+ */
+export function refund() {}
+`;
+
+describe('doctor', () => {
+  let rootDir: string;
+
+  beforeEach(() => {
+    rootDir = join(
+      tmpdir(),
+      `knowgraph-doctor-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(join(rootDir, 'src'), { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(rootDir, { recursive: true, force: true });
+  });
+
+  describe('checkConfig', () => {
+    it('warns when there is no manifest', () => {
+      const check = checkConfig(join(rootDir, '.knowgraph.yml'));
+      expect(check.status).toBe('warn');
+      expect(check.fix).toContain('knowgraph init');
+    });
+
+    it('fails on invalid YAML and on settings outside the schema', () => {
+      const configPath = join(rootDir, '.knowgraph.yml');
+      writeFileSync(configPath, 'exclude: [unclosed\n');
+      expect(checkConfig(configPath).status).toBe('fail');
+      writeFileSync(configPath, 'version: "1.0"\nexclude: 42\n');
+      const check = checkConfig(configPath);
+      expect(check.status).toBe('fail');
+      expect(check.message).toContain('exclude');
+    });
+
+    it('warns about unknown and deprecated keys', () => {
+      const configPath = join(rootDir, '.knowgraph.yml');
+      writeFileSync(configPath, 'version: "1.0"\nexlude: [dist]\n');
+      const check = checkConfig(configPath);
+      expect(check.status).toBe('warn');
+      expect(check.message).toContain('did you mean exclude?');
+      expect(check.fix).toContain('knowgraph config lint');
+    });
+
+    it('passes a valid manifest', () => {
+      const configPath = join(rootDir, '.knowgraph.yml');
+      writeFileSync(configPath, 'version: "1.0"\n');
+      expect(checkConfig(configPath)).toMatchObject({ status: 'ok' });
+    });
+  });
+
+  describe('checkStore', () => {
+    it('warns when there is no index', () => {
+      expect(checkStore(join(rootDir, 'missing.db')).status).toBe('warn');
+    });
+
+    it('fails on a file that is not an index', () => {
+      const dbPath = join(rootDir, 'broken.db');
+      writeFileSync(dbPath, 'not a database');
+      const check = checkStore(dbPath);
+      expect(check.status).toBe('fail');
+      expect(check.fix).toContain('knowgraph index');
+    });
+
+    it('warns on an older schema and passes the current one', () => {
+      const dbPath = join(rootDir, 'knowgraph.db');
+      const dbManager = createDatabaseManager(dbPath);
+      dbManager.initialize();
+      dbManager.setMeta('schema_version', '0');
+      dbManager.close();
+      expect(checkStore(dbPath).status).toBe('warn');
+
+      const current = createDatabaseManager(dbPath);
+      current.setMeta('schema_version', String(INDEX_SCHEMA_VERSION));
+      current.close();
+      expect(checkStore(dbPath)).toMatchObject({ status: 'ok' });
+    });
+  });
+
+  describe('checkGit', () => {
+    it('suggests installing git when it cannot run', () => {
+      const check = checkGit({ version: null, inRepository: false }, rootDir);
+      expect(check.status).toBe('warn');
+      expect(check.fix).toContain('Install git');
+    });
+  });
+
+  describe('scanParserCoverage', () => {
+    it('counts files, entities, and bad annotations per language', () => {
+      writeFileSync(join(rootDir, 'src/charge.ts'), VALID);
+      writeFileSync(join(rootDir, 'src/refund.ts'), INVALID);
+      writeFileSync(join(rootDir, 'src/util.py'), 'def noop():\n    pass\n');
+
+      const coverage = scanParserCoverage(rootDir, createDefaultRegistry());
+
+      expect(coverage.languages).toEqual([
+        {
+          extension: '.ts',
+          parser: 'typescript',
+          files: 2,
+          annotatedFiles: 1,
+          entities: 1,
+          unparseable: 1,
+        },
+        {
+          extension: '.py',
+          parser: 'python',
+          files: 1,
+          annotatedFiles: 0,
+          entities: 0,
+          unparseable: 0,
+        },
+      ]);
+      expect(coverage.diagnostics.map((d) => d.filePath)).toEqual([
+        'src/refund.ts',
+      ]);
+      const check = checkAnnotations(coverage);
+      expect(check.status).toBe('warn');
+      expect(check.fix).toContain('knowgraph validate');
+    });
+
+    it('passes when every annotation parses', () => {
+      writeFileSync(join(rootDir, 'src/charge.ts'), VALID);
+      const coverage = scanParserCoverage(rootDir, createDefaultRegistry());
+      expect(checkAnnotations(coverage).status).toBe('ok');
+    });
+  });
+
+  describe('checkFileLimits', () => {
+    it('warns about files over the scan limits', () => {
+      writeFileSync(join(rootDir, 'src/charge.ts'), VALID);
+      writeFileSync(join(rootDir, 'src/bundle.ts'), 'x'.repeat(200));
+
+      const coverage = scanParserCoverage(
+        rootDir,
+        createDefaultRegistry(),
+        undefined,
+        {},
+        { maxLineLength: 100 },
+      );
+
+      expect(coverage.skipped.map((file) => file.filePath)).toEqual([
+        'src/bundle.ts',
+      ]);
+      expect(coverage.languages[0].files).toBe(1);
+      const check = checkFileLimits(coverage);
+      expect(check.status).toBe('warn');
+      expect(check.fix).toContain('max_line_length');
+    });
+
+    it('passes when no file is skipped', () => {
+      writeFileSync(join(rootDir, 'src/charge.ts'), VALID);
+      const coverage = scanParserCoverage(rootDir, createDefaultRegistry());
+      expect(checkFileLimits(coverage).status).toBe('ok');
+    });
+  });
+
+  describe('checkEnrichmentCache', () => {
+    it('sums cached replies, and warns about unreadable caches', () => {
+      const git = { name: 'git', entries: 12, expired: 0, bytes: 2048 };
+      expect(checkEnrichmentCache([]).status).toBe('ok');
+      expect(checkEnrichmentCache([git]).message).toBe(
+        '12 cached enrichment replies from 1 enrichers',
+      );
+      const check = checkEnrichmentCache([
+        git,
+        { name: 'owners', entries: 0, expired: 0, bytes: 1, damaged: true },
+      ]);
+      expect(check.status).toBe('warn');
+      expect(check.message).toContain('owners');
+    });
+  });
+});
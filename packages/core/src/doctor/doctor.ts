@@ -0,0 +1,426 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Checks the manifest, index store, git, annotation parsing, and enrichment cache, and suggests a fix for each problem
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [doctor, diagnostics, support, health]
+ * context:
+ *   business_goal: Tell users how to fix each setup problem, not just that one exists
+ *   domain: doctor
+ */
+import { spawnSync } from 'node:child_process';
+import { existsSync, readFileSync } from 'node:fs';
+import { dirname, join, posix } from 'node:path';
+import { performance } from 'node:perf_hooks';
+import { parse as parseYaml } from 'yaml';
+import { compareStrings } from '../canonical/canonical.js';
+import { lintManifestText } from '../configlint/config-lint.js';
+import { readEnrichmentCacheStats } from '../enrichers/enrichment-calls.js';
+import type { EnrichmentCacheStats } from '../enrichers/types.js';
+import { createDatabaseManager } from '../indexer/database.js';
+import type { DatabaseManager } from '../indexer/database.js';
+import {
+  readLimitedFile,
+  resolveFileLimits,
+  slowParse,
+} from '../indexer/safeguards.js';
+import type {
+  FileLimits,
+  SkippedFile,
+  WalkOptions,
+} from '../indexer/types.js';
+import {
+  DEFAULT_INDEX_EXCLUDE,
+  listIndexableFiles,
+} from '../indexer/indexer.js';
+import { INDEX_SCHEMA_VERSION } from '../indexer/schema.js';
+import type { ParserRegistry } from '../parsers/types.js';
+import type { ParseDiagnostic } from '../types/parse-result.js';
+import { ManifestSchema } from '../types/manifest.js';
+import type {
+  DoctorCheck,
+  DoctorOptions,
+  DoctorReport,
+  GitStatus,
+  LanguageCoverage,
+  ParserCoverage,
+} from './types.js';
+
+function ok(name: string, message: string): DoctorCheck {
+  return { name, status: 'ok', message, fix: null };
+}
+
+function errorMessage(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+/**
+ * Whether the manifest at `configPath` is valid YAML that matches the
+ * manifest schema. A missing manifest is only a warning: every setting
+ * has a default. So are unknown and deprecated keys.
+ */
+export function checkConfig(configPath: string): DoctorCheck {
+  if (!existsSync(configPath)) {
+    return {
+      name: 'config',
+      status: 'warn',
+      message: `No manifest at ${configPath}; every setting uses its default`,
+      fix: 'Run `knowgraph init` to create one',
+    };
+  }
+  let text: string;
+  let parsed: unknown;
+  try {
+    text = readFileSync(configPath, 'utf-8');
+    parsed = parseYaml(text);
+  } catch (err) {
+    return {
+      name: 'config',
+      status: 'fail',
+      message: `${configPath} is not valid YAML: ${errorMessage(err)}`,
+      fix: `Fix the YAML syntax in ${configPath}`,
+    };
+  }
+  const result = ManifestSchema.safeParse(parsed);
+  if (!result.success) {
+    const { issues } = result.error;
+    const first = issues[0];
+    return {
+      name: 'config',
+      status: 'fail',
+      message:
+        `${configPath} has ${issues.length} invalid settings, first ` +
+        `${first?.path.join('.') || '(root)'}: ${first?.message}`,
+      fix: 'Correct the settings; commands ignore an invalid manifest entirely',
+    };
+  }
+  // Unknown keys pass the schema but are dropped, so a typo goes unnoticed
+  const issues = lintManifestText(text);
+  if (issues.length > 0) {
+    const first = issues[0];
+    return {
+      name: 'config',
+      status: 'warn',
+      message:
+        `${configPath} has ${issues.length} unknown or deprecated ` +
+        `settings, first ${first.path}: ${first.message}`,
+      fix: 'Run `knowgraph config lint` and correct each finding',
+    };
+  }
+  return ok('config', `${configPath} is valid`);
+}
+
+/** The top-level sections set in the manifest, without their values. */
+export function readConfigSections(configPath: string): readonly string[] {
+  try {
+    const parsed: unknown = parseYaml(readFileSync(configPath, 'utf-8'));
+    if (typeof parsed !== 'object' || parsed === null) return [];
+    return Object.keys(parsed).sort(compareStrings);
+  } catch {
+    return [];
+  }
+}
+
+/**
+ * Whether the index at `dbPath` opens, passes SQLite's integrity check,
+ * and was built with the current schema.
+ */
+export function checkStore(dbPath: string): DoctorCheck {
+  if (!existsSync(dbPath)) {
+    return {
+      name: 'store',
+      status: 'warn',
+      message: `No index at ${dbPath}`,
+      fix: 'Run `knowgraph index` to build it',
+    };
+  }
+  const rebuild = `Delete ${dbPath} and run \`knowgraph index\``;
+  let dbManager: DatabaseManager | undefined;
+  try {
+    dbManager = createDatabaseManager(dbPath);
+    const integrity = dbManager.db.pragma('integrity_check') as readonly {
+      readonly integrity_check: string;
+    }[];
+    const problems = integrity
+      .map((row) => row.integrity_check)
+      .filter((message) => message !== 'ok');
+    if (problems.length > 0) {
+      return {
+        name: 'store',
+        status: 'fail',
+        message: `${dbPath} is corrupt: ${problems[0]}`,
+        fix: rebuild,
+      };
+    }
+    const schema = dbManager.getMeta('schema_version');
+    if (schema !== String(INDEX_SCHEMA_VERSION)) {
+      return {
+        name: 'store',
+        status: 'warn',
+        message:
+          `${dbPath} was built with index schema ${schema ?? 'unknown'}; ` +
+          `this version uses ${INDEX_SCHEMA_VERSION}`,
+        fix: 'Run `knowgraph index` to rebuild it',
+      };
+    }
+    const stats = dbManager.getStats();
+    const files = dbManager.getFilePaths().length;
+    return ok(
+      'store',
+      `${dbPath} holds ${stats.totalEntities} entities from ${files} files`,
+    );
+  } catch (err) {
+    return {
+      name: 'store',
+      status: 'fail',
+      message: `${dbPath} is not a readable index: ${errorMessage(err)}`,
+      fix: rebuild,
+    };
+  } finally {
+    dbManager?.close();
+  }
+}
+
+/** The installed git, and whether `rootDir` is inside a work tree. */
+export function detectGit(rootDir: string, gitPath = 'git'): GitStatus {
+  const version = spawnSync(gitPath, ['--version'], { encoding: 'utf-8' });
+  if (version.error || version.status !== 0) {
+    return { version: null, inRepository: false };
+  }
+  const inside = spawnSync(gitPath, ['rev-parse', '--is-inside-work-tree'], {
+    cwd: rootDir,
+    encoding: 'utf-8',
+  });
+  return {
+    version: version.stdout.trim(),
+    inRepository: inside.status === 0 && inside.stdout.trim() === 'true',
+  };
+}
+
+export function checkGit(git: GitStatus, rootDir: string): DoctorCheck {
+  if (!git.version) {
+    return {
+      name: 'git',
+      status: 'warn',
+      message: 'git is not on the PATH',
+      fix: 'Install git; commit history, --staged, and --base need it',
+    };
+  }
+  if (!git.inRepository) {
+    return {
+      name: 'git',
+      status: 'warn',
+      message: `${rootDir} is not inside a git repository`,
+      fix: 'Run from a git checkout to get owners and history from commits',
+    };
+  }
+  return ok('git', git.version);
+}
+
+function extensionOf(filePath: string): string {
+  return posix.extname(posix.basename(filePath));
+}
+
+/**
+ * Parse every file an index of `rootDir` would read and count, per
+ * extension, the files, annotated files, entities, and annotations that
+ * could not be parsed. A parser that throws counts as one unparseable
+ * annotation for the file. Files the index would skip under `limits` are
+ * listed as skipped instead. Paths are relative to `rootDir`.
+ */
+export function scanParserCoverage(
+  rootDir: string,
+  registry: ParserRegistry,
+  exclude: readonly string[] = DEFAULT_INDEX_EXCLUDE,
+  walk: WalkOptions = {},
+  limits: FileLimits = {},
+): ParserCoverage {
+  const resolved = resolveFileLimits(limits);
+  const files = listIndexableFiles(
+    rootDir,
+    {
+      parse: () => [],
+      canParse: (file) => registry.getParser(file) !== undefined,
+    },
+    exclude,
+    [],
+    walk,
+  );
+  const counts = new Map<string, LanguageCoverage>();
+  const diagnostics: ParseDiagnostic[] = [];
+  const skipped: SkippedFile[] = [];
+  for (const relPath of [...files].sort(compareStrings)) {
+    let content: string;
+    try {
+      const read = readLimitedFile(join(rootDir, relPath), relPath, resolved);
+      if ('skipped' in read) {
+        skipped.push(read.skipped);
+        continue;
+      }
+      content = read.content;
+    } catch {
+      continue;
+    }
+    const extension = extensionOf(relPath);
+    const parser = registry.getParser(relPath)?.name ?? 'generic';
+    let entities = 0;
+    let unparseable = 0;
+    try {
+      const started = performance.now();
+      const output = registry.parseFile(content, join(rootDir, relPath));
+      const ms = performance.now() - started;
+      if (ms > resolved.parseTimeoutMs) {
+        skipped.push(slowParse(relPath, ms, resolved));
+        continue;
+      }
+      entities = output.results.length;
+      unparseable = output.diagnostics.length;
+      for (const diagnostic of output.diagnostics) {
+        diagnostics.push({ ...diagnostic, filePath: relPath });
+      }
+    } catch (err) {
+      unparseable = 1;
+      diagnostics.push({
+        filePath: relPath,
+        line: 1,
+        message: errorMessage(err),
+      });
+    }
+    const key = `${extension}\u0000${parser}`;
+    const current = counts.get(key) ?? {
+      extension,
+      parser,
+      files: 0,
+      annotatedFiles: 0,
+      entities: 0,
+      unparseable: 0,
+    };
+    counts.set(key, {
+      ...current,
+      files: current.files + 1,
+      annotatedFiles: current.annotatedFiles + (entities > 0 ? 1 : 0),
+      entities: current.entities + entities,
+      unparseable: current.unparseable + unparseable,
+    });
+  }
+  const languages = [...counts.values()].sort(
+    (a, b) => b.files - a.files || compareStrings(a.extension, b.extension),
+  );
+  return { languages, diagnostics, skipped };
+}
+
+export function checkFileLimits(coverage: ParserCoverage): DoctorCheck {
+  const { skipped } = coverage;
+  if (skipped.length === 0) {
+    return ok('limits', 'No files are over the scan limits');
+  }
+  const reasons = new Map<string, number>();
+  for (const file of skipped) {
+    reasons.set(file.reason, (reasons.get(file.reason) ?? 0) + 1);
+  }
+  const counts = [...reasons].map(([reason, count]) => `${count} ${reason}`);
+  return {
+    name: 'limits',
+    status: 'warn',
+    message: `${skipped.length} files are skipped (${counts.join(', ')})`,
+    fix:
+      'Exclude them, or raise index.max_file_bytes, ' +
+      'index.max_line_length, or index.parse_timeout_ms',
+  };
+}
+
+export function checkAnnotations(coverage: ParserCoverage): DoctorCheck {
+  const total = (field: 'files' | 'annotatedFiles' | 'entities') =>
+    coverage.languages.reduce((sum, language) => sum + language[field], 0);
+  const { diagnostics } = coverage;
+  if (diagnostics.length > 0) {
+    const files = new Set(diagnostics.map((d) => d.filePath)).size;
+    return {
+      name: 'annotations',
+      status: 'warn',
+      message: `${diagnostics.length} annotations in ${files} files do not parse`,
+      fix: 'Run `knowgraph validate` to see each error, then fix its YAML',
+    };
+  }
+  if (total('annotatedFiles') === 0) {
+    return {
+      name: 'annotations',
+      status: 'warn',
+      message: `No annotations found in ${total('files')} files`,
+      fix: 'Run `knowgraph suggest` for the code worth annotating first',
+    };
+  }
+  return ok(
+    'annotations',
+    `${total('entities')} annotations in ${total('annotatedFiles')} of ` +
+      `${total('files')} files parse`,
+  );
+}
+
+export function checkEnrichmentCache(
+  stats: readonly EnrichmentCacheStats[],
+): DoctorCheck {
+  const damaged = stats
+    .filter((cache) => cache.damaged)
+    .map((cache) => cache.name);
+  if (damaged.length > 0) {
+    return {
+      name: 'cache',
+      status: 'warn',
+      message: `Enrichment cache for ${damaged.join(', ')} cannot be read`,
+      fix: 'Run `knowgraph index`; its enrichers ask again and replace the file',
+    };
+  }
+  if (stats.length === 0) {
+    return ok('cache', 'No enrichment cache yet');
+  }
+  const entries = stats.reduce((sum, cache) => sum + cache.entries, 0);
+  return ok(
+    'cache',
+    `${entries} cached enrichment replies from ${stats.length} enrichers`,
+  );
+}
+
+/**
+ * Run every check on the repository at `options.rootDir`. The report holds
+ * no manifest values or file contents, and paths appear as `options` gives
+ * them or relative to the root, so it can be shared with support as it is.
+ */
+export function runDoctor(
+  registry: ParserRegistry,
+  options: DoctorOptions,
+): DoctorReport {
+  const git = detectGit(options.rootDir);
+  const coverage = scanParserCoverage(
+    options.rootDir,
+    registry,
+    options.exclude,
+    options.walk,
+    options.limits,
+  );
+  const enrichmentCache = readEnrichmentCacheStats(
+    options.cacheDir ?? join(dirname(options.dbPath), 'cache'),
+  );
+  return {
+    generatedAt: new Date().toISOString(),
+    environment: {
+      knowgraphVersion: options.knowgraphVersion,
+      nodeVersion: process.version,
+      platform: process.platform,
+      arch: process.arch,
+      gitVersion: git.version,
+    },
+    configSections: readConfigSections(options.configPath),
+    checks: [
+      checkConfig(options.configPath),
+      checkStore(options.dbPath),
+      checkGit(git, options.rootDir),
+      checkAnnotations(coverage),
+      checkFileLimits(coverage),
+      checkEnrichmentCache(enrichmentCache),
+    ],
+    coverage,
+    enrichmentCache,
+  };
+}
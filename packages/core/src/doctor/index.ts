@@ -0,0 +1,22 @@
+export type {
+  DoctorStatus,
+  DoctorCheck,
+  LanguageCoverage,
+  ParserCoverage,
+  GitStatus,
+  DoctorEnvironment,
+  DoctorOptions,
+  DoctorReport,
+} from './types.js';
+export {
+  checkAnnotations,
+  checkConfig,
+  checkEnrichmentCache,
+  checkFileLimits,
+  checkGit,
+  checkStore,
+  detectGit,
+  readConfigSections,
+  runDoctor,
+  scanParserCoverage,
+} from './doctor.js';
@@ -0,0 +1,95 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for environment and repository diagnostics and the support bundle built from them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [doctor, diagnostics, support, types, interface]
+ * context:
+ *   business_goal: Give support a diagnostics bundle with the same fields every time
+ *   domain: doctor
+ */
+import type {
+  FileLimits,
+  SkippedFile,
+  WalkOptions,
+} from '../indexer/types.js';
+import type { EnrichmentCacheStats } from '../enrichers/types.js';
+import type { ParseDiagnostic } from '../types/parse-result.js';
+
+export type DoctorStatus = 'ok' | 'warn' | 'fail';
+
+export interface DoctorCheck {
+  /**
+   * What was checked: `config`, `store`, `git`, `annotations`, `limits`,
+   * or `cache`.
+   */
+  readonly name: string;
+  readonly status: DoctorStatus;
+  readonly message: string;
+  /** What to do about a warning or failure; null when ok. */
+  readonly fix: string | null;
+}
+
+/** How the files with one extension are parsed. */
+export interface LanguageCoverage {
+  /** Such as `.ts`; empty for files without an extension. */
+  readonly extension: string;
+  /** Name of the parser that reads them; `generic` for the fallback. */
+  readonly parser: string;
+  readonly files: number;
+  /** Files with at least one parsed annotation. */
+  readonly annotatedFiles: number;
+  readonly entities: number;
+  /** Annotations that are not valid YAML or fail validation. */
+  readonly unparseable: number;
+}
+
+export interface ParserCoverage {
+  /** Sorted by file count, most first. */
+  readonly languages: readonly LanguageCoverage[];
+  /** Each unparseable annotation, in file order. */
+  readonly diagnostics: readonly ParseDiagnostic[];
+  /** Files the scan limits keep from being parsed, in file order. */
+  readonly skipped: readonly SkippedFile[];
+}
+
+export interface GitStatus {
+  /** Output of `git --version`; null when git cannot run. */
+  readonly version: string | null;
+  readonly inRepository: boolean;
+}
+
+export interface DoctorEnvironment {
+  readonly knowgraphVersion: string;
+  readonly nodeVersion: string;
+  readonly platform: string;
+  readonly arch: string;
+  readonly gitVersion: string | null;
+}
+
+export interface DoctorOptions {
+  readonly rootDir: string;
+  readonly configPath: string;
+  readonly dbPath: string;
+  readonly knowgraphVersion: string;
+  /** Patterns the scan skips; defaults to the indexer's. */
+  readonly exclude?: readonly string[];
+  /** Symlinks, submodules, and vendored code as the index walks them. */
+  readonly walk?: WalkOptions;
+  /** The size, line length, and parse time limits the index applies. */
+  readonly limits?: FileLimits;
+  /** Where enrichers cache replies; the `cache` folder next to the index. */
+  readonly cacheDir?: string;
+}
+
+export interface DoctorReport {
+  readonly generatedAt: string;
+  readonly environment: DoctorEnvironment;
+  /** Top-level manifest sections that are set, without their values. */
+  readonly configSections: readonly string[];
+  readonly checks: readonly DoctorCheck[];
+  readonly coverage: ParserCoverage;
+  /** What each enricher has cached between scans. */
+  readonly enrichmentCache: readonly EnrichmentCacheStats[];
+}
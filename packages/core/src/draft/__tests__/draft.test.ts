@@ -0,0 +1,209 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { AnswerModel } from '../../ask/types.js';
+import { createDefaultRegistry } from '../../parsers/registry.js';
+import {
+  buildDraftPrompt,
+  draftAnnotations,
+  findUnannotatedSymbols,
+  parseDraftReply,
+  renderDraftYaml,
+} from '../draft.js';
+
+const TS_SOURCE = [
+  "import { Stripe } from 'stripe';",
+  '',
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Already annotated by hand',
+  ' */',
+  'export function annotated(): void {}',
+  '',
+  '/** Plain JSDoc, kept as it is. */',
+  'export function charge(amount: number): Promise<void> {',
+  '  return new Stripe().charge(amount);',
+  '}',
+  '',
+  'function helper(): void {}',
+  '',
+  'export class Ledger {',
+  '  record(): void {}',
+  '}',
+  '',
+].join('\n');
+
+const PY_SOURCE = [
+  'import psycopg',
+  '',
+  '',
+  'def settle(batch):',
+  '    """Settle a batch of payouts."""',
+  '    return psycopg.connect().execute(batch)',
+  '',
+  '',
+  'def _private():',
+  '    pass',
+  '',
+  '',
+  'class Payouts(',
+  '    Base,',
+  '):',
+  '    limit = 10',
+  '',
+].join('\n');
+
+function replyFor(prompt: string): string {
+  const name = /`(\w+)`/.exec(prompt)?.[1];
+  return JSON.stringify({
+    type: name === 'Payouts' ? 'service' : 'function',
+    description: `Handles ${name}: the model's summary`,
+    tags: ['Payments', 'payments', 'billing'],
+    dependencies: { external_apis: ['stripe'], services: [] },
+  });
+}
+
+const model: AnswerModel = {
+  complete: async (prompt) => replyFor(prompt),
+};
+
+describe('findUnannotatedSymbols', () => {
+  it('finds exported TypeScript declarations without a block', () => {
+    const symbols = findUnannotatedSymbols(TS_SOURCE, 'src/pay.ts');
+    expect(symbols.map((s) => [s.name, s.line, s.entityType])).toEqual([
+      ['charge', 11, 'function'],
+      ['Ledger', 17, 'class'],
+    ]);
+    expect(symbols[0].source).toContain('new Stripe().charge(amount)');
+  });
+
+  it('finds public top-level Python definitions without a block', () => {
+    const symbols = findUnannotatedSymbols(PY_SOURCE, 'billing/payouts.py');
+    expect(symbols.map((s) => s.name)).toEqual(['settle', 'Payouts']);
+  });
+
+  it('skips symbols with a block that does not validate', () => {
+    const source = '/**\n * @knowgraph\n * type: nonsense\n */\nexport class A {}\n';
+    expect(findUnannotatedSymbols(source, 'a.ts')).toEqual([]);
+  });
+});
+
+describe('parseDraftReply', () => {
+  const [symbol] = findUnannotatedSymbols(TS_SOURCE, 'src/pay.ts');
+
+  it('keeps the schema fields and marks the draft generated', () => {
+    const metadata = parseDraftReply(
+      '```json\n' + replyFor(buildDraftPrompt(symbol)) + '\n```',
+      symbol,
+      'payments-team',
+    );
+    expect(metadata).toEqual({
+      type: 'function',
+      description: "Handles charge: the model's summary",
+      owner: 'payments-team',
+      tags: ['payments', 'billing'],
+      dependencies: { external_apis: ['stripe'] },
+      generated: true,
+    });
+  });
+
+  it('falls back to the declared type and rejects replies without JSON', () => {
+    expect(
+      parseDraftReply('{"type": "widget", "description": "Charges"}', symbol)
+        .type,
+    ).toBe('function');
+    expect(() => parseDraftReply('I cannot help with that', symbol)).toThrow(
+      'did not reply with JSON',
+    );
+    expect(() => parseDraftReply('{"tags": ["x"]}', symbol)).toThrow(
+      'no description',
+    );
+  });
+
+  it('renders YAML the parser reads back', () => {
+    const yaml = renderDraftYaml(
+      parseDraftReply(replyFor(buildDraftPrompt(symbol)), symbol),
+    );
+    expect(yaml).toEqual([
+      'type: function',
+      'description: "Handles charge: the model\'s summary"',
+      'tags: [payments, billing]',
+      'dependencies:',
+      '  external_apis: [stripe]',
+      'generated: true',
+    ]);
+  });
+});
+
+describe('draftAnnotations', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-draft-'));
+    writeFileSync(join(dir, 'pay.ts'), TS_SOURCE);
+    writeFileSync(join(dir, 'payouts.py'), PY_SOURCE);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('writes drafts the parsers bind to their symbols', async () => {
+    const result = await draftAnnotations(dir, { model });
+    expect(result.found).toBe(4);
+    expect(result.skipped).toEqual([]);
+
+    const registry = createDefaultRegistry();
+    for (const [file, names] of [
+      ['pay.ts', ['annotated', 'charge', 'Ledger']],
+      ['payouts.py', ['settle', 'Payouts']],
+    ] as const) {
+      const content = readFileSync(join(dir, file), 'utf-8');
+      const parsed = registry.parseFile(content, file);
+      expect(parsed.diagnostics).toEqual([]);
+      expect(parsed.results.map((r) => r.name)).toEqual(names);
+    }
+    const python = readFileSync(join(dir, 'payouts.py'), 'utf-8');
+    expect(python).toContain('Settle a batch of payouts.\n\n    @knowgraph');
+    expect(python).toContain('    return psycopg.connect()');
+  });
+
+  it('leaves files alone when not writing and stops at the limit', async () => {
+    const result = await draftAnnotations(dir, {
+      model,
+      limit: 1,
+      write: false,
+    });
+    expect(result.found).toBe(4);
+    expect(result.files).toHaveLength(1);
+    expect(result.files[0].drafts.map((d) => d.symbol.name)).toEqual([
+      'charge',
+    ]);
+    expect(readFileSync(join(dir, 'pay.ts'), 'utf-8')).toBe(TS_SOURCE);
+  });
+
+  it('skips symbols the model gives no usable answer for', async () => {
+    const result = await draftAnnotations(join(dir, 'pay.ts'), {
+      model: { complete: async () => 'Sorry' },
+    });
+    expect(result.files).toEqual([]);
+    expect(result.skipped.map((s) => s.symbol.name)).toEqual([
+      'charge',
+      'Ledger',
+    ]);
+  });
+
+  it('stops on model errors', async () => {
+    await expect(
+      draftAnnotations(dir, {
+        model: {
+          complete: async () => {
+            throw new Error('LLM API error: 401 Unauthorized');
+          },
+        },
+      }),
+    ).rejects.toThrow('401');
+  });
+});
@@ -0,0 +1,158 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDefaultRegistry } from '../../parsers/registry.js';
+import { findUnannotatedSymbols } from '../draft.js';
+import {
+  annotateInteractively,
+  detectDependencies,
+  suggestTags,
+} from '../wizard.js';
+import type { WizardPrompter } from '../types.js';
+
+const SOURCE = [
+  "import { Pool } from 'pg';",
+  "import Stripe from 'stripe';",
+  "import { createClient } from 'redis';",
+  '',
+  'export async function charge(amount: number): Promise<void> {',
+  '  await new Stripe(key).charges.create({ amount });',
+  "  await new Pool().query('insert into charges');",
+  '  await notifications.send(amount);',
+  '}',
+  '',
+  'export class Refunds {',
+  '  issue(): void {}',
+  '}',
+  '',
+  'export interface Receipt {',
+  '  id: string;',
+  '}',
+  '',
+].join('\n');
+
+/** Answers each question in turn, then closes the input. */
+function scripted(answers: readonly string[]): {
+  readonly prompter: WizardPrompter;
+  readonly questions: string[];
+} {
+  const queue = [...answers];
+  const questions: string[] = [];
+  return {
+    questions,
+    prompter: {
+      say: () => {},
+      ask: async (question) => {
+        questions.push(question);
+        return queue.shift() ?? null;
+      },
+    },
+  };
+}
+
+describe('detectDependencies', () => {
+  it('finds packages the code uses and services it names', () => {
+    const [charge] = findUnannotatedSymbols(SOURCE, 'src/billing/charge.ts');
+    const imports = SOURCE.split('\n').slice(0, 3);
+    expect(
+      detectDependencies(charge, imports, ['notifications', 'search']),
+    ).toEqual([
+      { kind: 'databases', name: 'postgres', reason: 'uses pg' },
+      { kind: 'external_apis', name: 'stripe', reason: 'uses stripe' },
+      {
+        kind: 'services',
+        name: 'notifications',
+        reason: 'mentions notifications',
+      },
+    ]);
+  });
+
+  it('reads Python imports', () => {
+    const source = 'def load(key):\n    """Load."""\n    return r.get(key)\n';
+    const [load] = findUnannotatedSymbols(source, 'cache.py');
+    expect(detectDependencies(load, ['import redis as r'])).toEqual([
+      { kind: 'databases', name: 'redis', reason: 'uses redis' },
+    ]);
+  });
+});
+
+describe('suggestTags', () => {
+  it('takes the meaningful path segments', () => {
+    expect(suggestTags('src/billing/charge_runner.ts')).toEqual([
+      'billing',
+      'charge',
+      'runner',
+    ]);
+    expect(suggestTags('lib/index.ts')).toEqual([]);
+  });
+});
+
+describe('annotateInteractively', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-wizard-'));
+    writeFileSync(join(dir, 'charge.ts'), SOURCE);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('writes each answered block as it goes', async () => {
+    const { prompter, questions } = scripted([
+      'y',
+      'Charges a card and records the charge',
+      '',
+      'payments-team',
+      'billing, payments',
+      'y',
+      'n',
+      'y',
+      's',
+      'yes',
+      'A receipt sent to the customer',
+      'widget',
+      'interface',
+      '',
+      '-',
+    ]);
+    const result = await annotateInteractively(dir, {
+      prompter,
+      services: ['notifications'],
+    });
+
+    expect(result).toMatchObject({ found: 3, skipped: 1, quit: false });
+    expect(questions).toContain('Depends on databases postgres (uses pg)?');
+    const content = readFileSync(join(dir, 'charge.ts'), 'utf-8');
+    const parsed = createDefaultRegistry().parseFile(content, 'charge.ts');
+    const byName = new Map(parsed.results.map((r) => [r.name, r.metadata]));
+    expect(byName.get('charge')).toMatchObject({
+      type: 'function',
+      owner: 'payments-team',
+      tags: ['billing', 'payments'],
+      dependencies: { databases: ['postgres'], services: ['notifications'] },
+    });
+    expect(byName.get('charge')).not.toHaveProperty('generated');
+    expect(byName.has('Refunds')).toBe(false);
+    // The owner carries over, and an unknown type is asked again
+    expect(byName.get('Receipt')).toMatchObject({
+      type: 'interface',
+      owner: 'payments-team',
+    });
+    expect(byName.get('Receipt')).not.toHaveProperty('tags');
+  });
+
+  it('stops when the input ends', async () => {
+    const { prompter } = scripted(['y', 'Charges a card', '', '', '', 'n']);
+    const result = await annotateInteractively(dir, {
+      prompter,
+      owner: 'payments-team',
+      write: false,
+    });
+    expect(result.quit).toBe(true);
+    expect(result.annotated).toEqual([]);
+    expect(readFileSync(join(dir, 'charge.ts'), 'utf-8')).toBe(SOURCE);
+  });
+});
@@ -0,0 +1,558 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Finds public symbols without annotations and drafts @knowgraph blocks for them with a language model, marked generated for review
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [draft, llm, annotations, rewriter]
+ * context:
+ *   business_goal: Give models enough surrounding code to draft annotations a reviewer can accept as written
+ *   domain: draft
+ */
+import { existsSync, readFileSync, statSync, writeFileSync } from 'node:fs';
+import { basename, dirname, extname, join } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import { dependencyEntryName } from '../graph/graph-environments.js';
+import { listIndexableFiles } from '../indexer/indexer.js';
+import { validateMetadata } from '../parsers/metadata-extractor.js';
+import { createDefaultRegistry } from '../parsers/registry.js';
+import { EntityTypeSchema } from '../types/entity.js';
+import type { EntityType, ExtendedMetadata } from '../types/entity.js';
+import type {
+  DraftLanguage,
+  DraftOptions,
+  DraftResult,
+  DraftSkip,
+  DraftedAnnotation,
+  DraftedFile,
+  UnannotatedSymbol,
+} from './types.js';
+
+const LANGUAGES: Readonly<Record<string, DraftLanguage>> = {
+  '.ts': 'typescript',
+  '.tsx': 'typescript',
+  '.mts': 'typescript',
+  '.cts': 'typescript',
+  '.js': 'typescript',
+  '.jsx': 'typescript',
+  '.py': 'python',
+};
+
+// Enough of a body for the model to see what the symbol calls
+const MAX_SOURCE_LINES = 60;
+const MAX_IMPORT_LINES = 30;
+const MAX_TAGS = 5;
+const DEPENDENCY_KINDS = ['services', 'databases', 'external_apis'] as const;
+
+interface Declaration {
+  readonly pattern: RegExp;
+  readonly entityType: EntityType;
+}
+
+// Shapes the language parsers bind a block to, exported or public only
+const DECLARATIONS: Readonly<Record<DraftLanguage, readonly Declaration[]>> = {
+  typescript: [
+    { pattern: /^export\s+(?:abstract\s+)?class\s+(\w+)/, entityType: 'class' },
+    {
+      pattern: /^export\s+(?:async\s+)?function\s+(\w+)/,
+      entityType: 'function',
+    },
+    {
+      pattern:
+        /^export\s+(?:const|let)\s+(\w+)(?:\s*:\s*[^=]+)?\s*=\s*(?:async\s+)?(?:\([^)]*\)|\w+)\s*=>/,
+      entityType: 'function',
+    },
+    { pattern: /^export\s+interface\s+(\w+)/, entityType: 'interface' },
+    { pattern: /^export\s+(?:const\s+)?enum\s+(\w+)/, entityType: 'enum' },
+  ],
+  python: [
+    { pattern: /^class\s+([A-Za-z]\w*)/, entityType: 'class' },
+    { pattern: /^(?:async\s+)?def\s+([A-Za-z]\w*)/, entityType: 'function' },
+  ],
+};
+
+/** The language drafts are written in for `filePath`, if any. */
+export function draftLanguage(filePath: string): DraftLanguage | undefined {
+  return LANGUAGES[extname(filePath).toLowerCase()];
+}
+
+function matchDeclaration(
+  line: string,
+  language: DraftLanguage,
+): (Declaration & { readonly name: string }) | undefined {
+  for (const declaration of DECLARATIONS[language]) {
+    const match = declaration.pattern.exec(line);
+    if (match) return { ...declaration, name: match[1] };
+  }
+  return undefined;
+}
+
+/** Whether the JSDoc block right above `index`, past decorators, is one. */
+function hasTypescriptBlock(lines: readonly string[], index: number): boolean {
+  let end = index - 1;
+  while (end >= 0 && /^\s*(@|$)/.test(lines[end])) end--;
+  if (end < 0 || !lines[end].trim().endsWith('*/')) return false;
+  let start = end;
+  while (start > 0 && !lines[start].includes('/*')) start--;
+  return lines.slice(start, end + 1).join('\n').includes('@knowgraph');
+}
+
+interface PythonBody {
+  /** Index of the line ending the `def` or `class` header. */
+  readonly headerEnd: number;
+  readonly indent: string;
+  /** First and last line indexes of the existing docstring. */
+  readonly docstring?: { readonly start: number; readonly end: number };
+}
+
+function findPythonBody(
+  lines: readonly string[],
+  index: number,
+): PythonBody | undefined {
+  // The header ends at the first line closing every bracket it opened;
+  // one without a colon there holds its body on the same line
+  let headerEnd = index;
+  let depth = 0;
+  for (; headerEnd < lines.length; headerEnd++) {
+    const code = lines[headerEnd].replace(/#.*$/, '').trimEnd();
+    depth += (code.match(/[([{]/g) ?? []).length;
+    depth -= (code.match(/[)\]}]/g) ?? []).length;
+    if (depth > 0) continue;
+    if (!code.endsWith(':')) return undefined;
+    break;
+  }
+  if (headerEnd >= lines.length) return undefined;
+
+  let first = headerEnd + 1;
+  while (first < lines.length && lines[first].trim() === '') first++;
+  const indent =
+    first < lines.length && /^\s+/.test(lines[first])
+      ? /^(\s+)/.exec(lines[first])![1]
+      : '    ';
+  const opening = /^\s*[rRuU]?("""|''')/.exec(lines[first] ?? '');
+  if (!opening) return { headerEnd, indent };
+
+  const quote = opening[1];
+  const rest = lines[first].slice(lines[first].indexOf(quote) + 3);
+  let end = first;
+  if (!rest.includes(quote)) {
+    end++;
+    while (end < lines.length && !lines[end].includes(quote)) end++;
+    if (end >= lines.length) return undefined;
+  }
+  return { headerEnd, indent, docstring: { start: first, end } };
+}
+
+/** Whether a Python symbol can take a docstring and has no block in it. */
+function needsPythonBlock(lines: readonly string[], index: number): boolean {
+  const body = findPythonBody(lines, index);
+  if (!body) return false;
+  if (!body.docstring) return true;
+  const { start, end } = body.docstring;
+  return !lines.slice(start, end + 1).join('\n').includes('@knowgraph');
+}
+
+function symbolSource(
+  lines: readonly string[],
+  index: number,
+  language: DraftLanguage,
+): string {
+  let end = index + 1;
+  while (
+    end < lines.length &&
+    end - index < MAX_SOURCE_LINES &&
+    !matchDeclaration(lines[end], language)
+  ) {
+    end++;
+  }
+  return lines
+    .slice(index, end)
+    .join('\n')
+    .replace(/\s+$/, '');
+}
+
+function splitLines(content: string): {
+  readonly lines: string[];
+  readonly eol: string;
+} {
+  const eol = content.includes('\r\n') ? '\r\n' : '\n';
+  return { lines: content.split(eol), eol };
+}
+
+/**
+ * The exported TypeScript and JavaScript declarations, and the public
+ * top-level Python classes and functions, of `content` that no @knowgraph
+ * block describes, valid or not. Methods are left to their class, and
+ * one-line Python definitions, which cannot hold a docstring, are skipped.
+ */
+export function findUnannotatedSymbols(
+  content: string,
+  filePath: string,
+): readonly UnannotatedSymbol[] {
+  const language = draftLanguage(filePath);
+  if (!language) return [];
+  const { lines } = splitLines(content);
+  const symbols: UnannotatedSymbol[] = [];
+  // Overloads and redefinitions share one annotation, on the first
+  const seen = new Set<string>();
+  for (let index = 0; index < lines.length; index++) {
+    const declaration = matchDeclaration(lines[index], language);
+    if (!declaration || seen.has(declaration.name)) continue;
+    seen.add(declaration.name);
+    const needed =
+      language === 'python'
+        ? needsPythonBlock(lines, index)
+        : !hasTypescriptBlock(lines, index);
+    if (!needed) continue;
+    symbols.push({
+      name: declaration.name,
+      filePath,
+      line: index + 1,
+      language,
+      entityType: declaration.entityType,
+      source: symbolSource(lines, index, language),
+    });
+  }
+  return symbols;
+}
+
+/**
+ * The prompt asking the model for one symbol's annotation fields as JSON.
+ * `imports` are the file's import lines, which name most of what the symbol
+ * can depend on.
+ */
+export function buildDraftPrompt(
+  symbol: UnannotatedSymbol,
+  imports: readonly string[] = [],
+): string {
+  const lines = [
+    'You are drafting a knowgraph annotation: metadata that describes a piece of code for a dependency graph of the codebase.',
+    `Describe the ${symbol.language} ${symbol.entityType} \`${symbol.name}\` in ${symbol.filePath}.`,
+    '',
+    'Reply with one JSON object and nothing else, with these fields:',
+    `- "type": one of ${EntityTypeSchema.options.join(', ')}. Use "service" only for a class that other parts of the system call as a service.`,
+    '- "description": one sentence saying what it does and why, under 200 characters',
+    `- "tags": 1 to ${MAX_TAGS} lowercase keywords`,
+    '- "dependencies": an object with "services", "databases", and "external_apis" lists naming only what the code visibly calls; leave them empty when unsure',
+    '',
+  ];
+  if (imports.length > 0) {
+    lines.push('Imports of the file:', '```', ...imports, '```', '');
+  }
+  lines.push('Code:', '```', symbol.source, '```');
+  return lines.join('\n');
+}
+
+function stringList(value: unknown): readonly string[] {
+  if (!Array.isArray(value)) return [];
+  const items = value
+    .filter((item): item is string => typeof item === 'string')
+    .map((item) => item.trim())
+    .filter((item) => item.length > 0);
+  return [...new Set(items)];
+}
+
+/**
+ * The annotation fields in a model's `reply`, checked against the schema
+ * and marked `generated: true`. Throws a `parse` error when the reply holds
+ * no JSON object, no description, or fields that do not validate.
+ */
+export function parseDraftReply(
+  reply: string,
+  symbol: UnannotatedSymbol,
+  owner?: string,
+): ExtendedMetadata {
+  const start = reply.indexOf('{');
+  const end = reply.lastIndexOf('}');
+  let parsed: unknown;
+  try {
+    parsed = JSON.parse(reply.slice(start, end + 1));
+  } catch {
+    parsed = undefined;
+  }
+  if (typeof parsed !== 'object' || parsed === null || Array.isArray(parsed)) {
+    throw createKnowgraphError('parse', 'The model did not reply with JSON');
+  }
+  const fields = parsed as Record<string, unknown>;
+
+  const description =
+    typeof fields.description === 'string'
+      ? fields.description.replace(/\s+/g, ' ').trim()
+      : '';
+  if (!description) {
+    throw createKnowgraphError('parse', 'The model gave no description');
+  }
+  const type = EntityTypeSchema.safeParse(fields.type);
+  const tags = [
+    ...new Set(stringList(fields.tags).map((tag) => tag.toLowerCase())),
+  ].slice(0, MAX_TAGS);
+  const declared = (fields.dependencies ?? {}) as Record<string, unknown>;
+  const dependencies = Object.fromEntries(
+    DEPENDENCY_KINDS.map((kind) => [kind, stringList(declared[kind])]).filter(
+      ([, names]) => names.length > 0,
+    ),
+  );
+
+  const { metadata, errors } = validateMetadata({
+    type: type.success ? type.data : symbol.entityType,
+    description,
+    ...(owner ? { owner } : {}),
+    ...(tags.length > 0 ? { tags } : {}),
+    ...(Object.keys(dependencies).length > 0 ? { dependencies } : {}),
+    generated: true,
+  });
+  if (!metadata) {
+    throw createKnowgraphError(
+      'parse',
+      errors[0]?.message ?? 'The model gave invalid fields',
+    );
+  }
+  return metadata as ExtendedMetadata;
+}
+
+/** `text` as a YAML scalar, quoted unless it reads back as the same string. */
+export function yamlScalar(text: string, inFlow = false): string {
+  const plain = inFlow
+    ? /^[A-Za-z0-9_][\w./-]*$/
+    : /^[A-Za-z0-9_][\w .,()/'’-]*$/;
+  const reserved = /^(true|false|null|yes|no|on|off|[-+]?[\d.]+(e[-+]?\d+)?)$/i;
+  return plain.test(text) && !reserved.test(text) && !/\s$/.test(text)
+    ? text
+    : JSON.stringify(text);
+}
+
+function yamlList(items: readonly string[]): string {
+  return `[${items.map((item) => yamlScalar(item, true)).join(', ')}]`;
+}
+
+/**
+ * `metadata` as the YAML lines of a hand-written @knowgraph block, with
+ * `generated: true` only when it was drafted.
+ */
+export function renderDraftYaml(metadata: ExtendedMetadata): readonly string[] {
+  const lines = [`type: ${metadata.type}`];
+  if (typeof metadata.description === 'string') {
+    lines.push(`description: ${yamlScalar(metadata.description)}`);
+  }
+  if (metadata.owner) lines.push(`owner: ${yamlScalar(metadata.owner)}`);
+  if (metadata.tags?.length) lines.push(`tags: ${yamlList(metadata.tags)}`);
+  const dependencies = metadata.dependencies ?? {};
+  const kinds = DEPENDENCY_KINDS.filter((kind) => dependencies[kind]?.length);
+  if (kinds.length > 0) {
+    lines.push('dependencies:');
+    for (const kind of kinds) {
+      const names = dependencies[kind]!.map(dependencyEntryName);
+      lines.push(`  ${kind}: ${yamlList(names)}`);
+    }
+  }
+  if (metadata.generated) lines.push('generated: true');
+  return lines;
+}
+
+interface Insertion {
+  /** Index of the first line replaced, or before which lines are added. */
+  readonly at: number;
+  readonly remove: number;
+  readonly lines: readonly string[];
+}
+
+function planInsertion(
+  lines: readonly string[],
+  symbol: UnannotatedSymbol,
+  yaml: readonly string[],
+): Insertion | undefined {
+  const index = symbol.line - 1;
+  if (symbol.language === 'typescript') {
+    return {
+      at: index,
+      remove: 0,
+      lines: ['/**', ' * @knowgraph', ...yaml.map((l) => ` * ${l}`), ' */'],
+    };
+  }
+
+  const body = findPythonBody(lines, index);
+  if (!body) return undefined;
+  const { indent } = body;
+  const block = [`${indent}@knowgraph`, ...yaml.map((l) => `${indent}${l}`)];
+  if (!body.docstring) {
+    return {
+      at: body.headerEnd + 1,
+      remove: 0,
+      lines: [`${indent}"""`, ...block, `${indent}"""`],
+    };
+  }
+  // Append to the existing docstring, moving its closing quotes down
+  const closing = lines[body.docstring.end];
+  const quote = closing.includes('"""') ? '"""' : "'''";
+  const at = closing.lastIndexOf(quote);
+  const before = closing.slice(0, at).trimEnd();
+  return {
+    at: body.docstring.end,
+    remove: 1,
+    lines: [
+      ...(before.trim() ? [before] : []),
+      '',
+      ...block,
+      `${indent}${closing.slice(at).trim()}`,
+    ],
+  };
+}
+
+function applyDrafts(
+  content: string,
+  drafts: readonly DraftedAnnotation[],
+): string {
+  const { lines, eol } = splitLines(content);
+  const insertions = drafts
+    .map((draft) =>
+      planInsertion(lines, draft.symbol, renderDraftYaml(draft.metadata)),
+    )
+    .filter((insertion): insertion is Insertion => insertion !== undefined)
+    .sort((a, b) => b.at - a.at);
+  for (const insertion of insertions) {
+    lines.splice(insertion.at, insertion.remove, ...insertion.lines);
+  }
+  return lines.join(eol);
+}
+
+/**
+ * Insert `drafts` into `content`, leaving out any the language parser would
+ * not read back for its symbol, such as a Python header it cannot follow.
+ */
+export function insertDrafts(
+  content: string,
+  filePath: string,
+  drafts: readonly DraftedAnnotation[],
+): { readonly content: string; readonly rejected: readonly DraftSkip[] } {
+  const drafted = createDefaultRegistry()
+    .parseFile(applyDrafts(content, drafts), filePath)
+    .results.filter(
+      (result) => 'generated' in result.metadata && result.metadata.generated,
+    )
+    .map((result) => result.name);
+  const kept = drafts.filter((draft) => drafted.includes(draft.symbol.name));
+  const rejected = drafts
+    .filter((draft) => !kept.includes(draft))
+    .map((draft) => ({
+      symbol: draft.symbol,
+      reason: 'The parser would not read a block at this declaration',
+    }));
+  return { content: applyDrafts(content, kept), rejected };
+}
+
+/**
+ * Insert one person-written `annotation` into `content`, or return
+ * undefined when the language parser would not read it back for its
+ * symbol.
+ */
+export function insertAnnotation(
+  content: string,
+  filePath: string,
+  annotation: DraftedAnnotation,
+): string | undefined {
+  const updated = applyDrafts(content, [annotation]);
+  const read = createDefaultRegistry()
+    .parseFile(updated, filePath)
+    .results.some(
+      (result) =>
+        result.name === annotation.symbol.name &&
+        result.metadata.description === annotation.metadata.description,
+    );
+  return read ? updated : undefined;
+}
+
+/** `path`, a directory or one file, as a root and the files to annotate. */
+export function listDraftFiles(path: string): {
+  readonly rootDir: string;
+  readonly files: readonly string[];
+} {
+  if (!existsSync(path)) {
+    throw createKnowgraphError('io', `Path not found: ${path}`);
+  }
+  if (statSync(path).isFile()) {
+    return { rootDir: dirname(path), files: [basename(path)] };
+  }
+  const adapter = {
+    parse: () => [],
+    canParse: (filePath: string) => draftLanguage(filePath) !== undefined,
+  };
+  return { rootDir: path, files: listIndexableFiles(path, adapter) };
+}
+
+/** The import lines of `content`, which name most of what it uses. */
+export function importLines(content: string): readonly string[] {
+  return splitLines(content)
+    .lines.filter((line) => /^(import|from)\s|require\(/.test(line))
+    .slice(0, MAX_IMPORT_LINES);
+}
+
+/**
+ * Draft an annotation for each unannotated symbol under `path`, a
+ * directory or one file, asking `options.model` for one symbol at a time,
+ * and write the drafts into the files unless `write` is false. Every block
+ * carries `generated: true`, which the `draft-reviewed` rule reports until
+ * a person has checked it. Errors from the model itself, such as an API
+ * failure, end the run; replies that hold no usable annotation skip the
+ * symbol.
+ */
+export async function draftAnnotations(
+  path: string,
+  options: DraftOptions,
+): Promise<DraftResult> {
+  const {
+    model,
+    limit = Infinity,
+    owner,
+    write = true,
+    onSymbol,
+    onFile,
+  } = options;
+  const { rootDir, files } = listDraftFiles(path);
+  const drafted: DraftedFile[] = [];
+  const skipped: DraftSkip[] = [];
+  let found = 0;
+  let budget = limit;
+
+  for (const filePath of files) {
+    const absPath = join(rootDir, filePath);
+    const content = readFileSync(absPath, 'utf-8');
+    const symbols = findUnannotatedSymbols(content, filePath);
+    found += symbols.length;
+    const imports = importLines(content);
+    const drafts: DraftedAnnotation[] = [];
+    for (const symbol of symbols.slice(0, Math.max(0, budget))) {
+      budget--;
+      onSymbol?.(symbol);
+      const reply = await model.complete(buildDraftPrompt(symbol, imports));
+      try {
+        const metadata = parseDraftReply(reply, symbol, owner);
+        drafts.push({
+          symbol,
+          metadata,
+          block: renderDraftYaml(metadata).join('\n'),
+        });
+      } catch (err) {
+        skipped.push({ symbol, reason: (err as Error).message });
+      }
+    }
+    if (drafts.length === 0) continue;
+
+    const inserted = insertDrafts(content, filePath, drafts);
+    skipped.push(...inserted.rejected);
+    const kept = drafts.filter(
+      (draft) => !inserted.rejected.some((r) => r.symbol === draft.symbol),
+    );
+    if (kept.length === 0) continue;
+    if (write) writeFileSync(absPath, inserted.content, 'utf-8');
+    const file = {
+      filePath,
+      drafts: kept,
+      before: content,
+      content: inserted.content,
+    };
+    drafted.push(file);
+    onFile?.(file);
+  }
+
+  return { files: drafted, skipped, found };
+}
@@ -0,0 +1,31 @@
+export type {
+  DependencyKind,
+  DetectedDependency,
+  DraftLanguage,
+  DraftOptions,
+  DraftResult,
+  DraftSkip,
+  DraftedAnnotation,
+  DraftedFile,
+  UnannotatedSymbol,
+  WizardOptions,
+  WizardPrompter,
+  WizardResult,
+} from './types.js';
+export {
+  buildDraftPrompt,
+  draftAnnotations,
+  draftLanguage,
+  findUnannotatedSymbols,
+  importLines,
+  insertAnnotation,
+  insertDrafts,
+  listDraftFiles,
+  parseDraftReply,
+  renderDraftYaml,
+} from './draft.js';
+export {
+  annotateInteractively,
+  detectDependencies,
+  suggestTags,
+} from './wizard.js';
@@ -0,0 +1,124 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for drafting annotations of unannotated symbols with a language model
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [draft, llm, annotations, types, interface]
+ * context:
+ *   business_goal: Let any configured model provider draft annotations through one interface
+ *   domain: draft
+ */
+import type { AnswerModel } from '../ask/types.js';
+import type { EntityType, ExtendedMetadata } from '../types/entity.js';
+
+export type DraftLanguage = 'typescript' | 'python';
+
+/** A public top-level declaration with no @knowgraph block. */
+export interface UnannotatedSymbol {
+  readonly name: string;
+  /** Relative to the drafted directory, with `/` separators. */
+  readonly filePath: string;
+  /** 1-based line of the declaration. */
+  readonly line: number;
+  readonly language: DraftLanguage;
+  /** The type the declaration suggests, used when the model gives none. */
+  readonly entityType: EntityType;
+  /** The declaration and the start of its body, as sent to the model. */
+  readonly source: string;
+}
+
+export interface DraftedAnnotation {
+  readonly symbol: UnannotatedSymbol;
+  /** The proposed fields, always with `generated: true`. */
+  readonly metadata: ExtendedMetadata;
+  /** The YAML of the @knowgraph block written to the file. */
+  readonly block: string;
+}
+
+export interface DraftSkip {
+  readonly symbol: UnannotatedSymbol;
+  readonly reason: string;
+}
+
+export interface DraftedFile {
+  readonly filePath: string;
+  readonly drafts: readonly DraftedAnnotation[];
+  /** The file as it was read, before the drafts. */
+  readonly before: string;
+  /** The file with the drafts inserted. */
+  readonly content: string;
+}
+
+export interface DraftOptions {
+  /** Proposes the fields of each annotation. */
+  readonly model: AnswerModel;
+  /** Symbols to draft at most, in file and line order. */
+  readonly limit?: number;
+  /** Owner written into every draft, which the model cannot know. */
+  readonly owner?: string;
+  /** Write the drafts into the files (default: true). */
+  readonly write?: boolean;
+  /** Called before each symbol is sent to the model, for progress output. */
+  readonly onSymbol?: (symbol: UnannotatedSymbol) => void;
+  /**
+   * Called once each file's drafts are inserted, and written unless
+   * `write` is false, so callers keep track of files written before a
+   * later model call fails.
+   */
+  readonly onFile?: (file: DraftedFile) => void;
+}
+
+export interface DraftResult {
+  readonly files: readonly DraftedFile[];
+  /** Symbols the model gave no usable annotation for. */
+  readonly skipped: readonly DraftSkip[];
+  /** Unannotated symbols found, including those past `limit`. */
+  readonly found: number;
+}
+
+/** Asks the person annotating, one question at a time. */
+export interface WizardPrompter {
+  /** Show context, such as the symbol a question is about. */
+  say(text: string): void;
+  /**
+   * The reply to `question`, blank to take `suggested` when one is shown,
+   * or null once the person has closed the input.
+   */
+  ask(question: string, suggested?: string): Promise<string | null>;
+}
+
+export type DependencyKind = 'services' | 'databases' | 'external_apis';
+
+/** A dependency the wizard found in a symbol's code, for confirming. */
+export interface DetectedDependency {
+  readonly kind: DependencyKind;
+  readonly name: string;
+  /** What gave it away, such as `imports pg`. */
+  readonly reason: string;
+}
+
+export interface WizardOptions {
+  readonly prompter: WizardPrompter;
+  /** Owner suggested first; later symbols suggest the last one given. */
+  readonly owner?: string;
+  /** Names of indexed services, found in code that mentions them. */
+  readonly services?: readonly string[];
+  /** Symbols to ask about at most, in file and line order. */
+  readonly limit?: number;
+  /** Write each block as it is answered (default: true). */
+  readonly write?: boolean;
+}
+
+export interface WizardResult {
+  /** The blocks written, in the order they were answered. */
+  readonly annotated: readonly DraftedAnnotation[];
+  /** Each file given a block, with its content before and after. */
+  readonly files: readonly DraftedFile[];
+  /** Symbols passed over, left blank, or whose block would not parse. */
+  readonly skipped: number;
+  /** Unannotated symbols found when the wizard started. */
+  readonly found: number;
+  /** Whether the person quit before the last symbol. */
+  readonly quit: boolean;
+}
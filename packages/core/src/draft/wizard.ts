@@ -0,0 +1,352 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Walks a person through unannotated public symbols one at a time, asking for owner and tags and confirming detected dependencies, and writes each block as it is answered
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [draft, wizard, interactive, annotations, rewriter]
+ * context:
+ *   business_goal: Make each annotation question quick to answer so nobody abandons the walkthrough halfway
+ *   domain: draft
+ */
+import { readFileSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { validateMetadata } from '../parsers/metadata-extractor.js';
+import { EntityTypeSchema } from '../types/entity.js';
+import type { EntityType, ExtendedMetadata } from '../types/entity.js';
+import {
+  findUnannotatedSymbols,
+  importLines,
+  insertAnnotation,
+  listDraftFiles,
+  renderDraftYaml,
+} from './draft.js';
+import type {
+  DependencyKind,
+  DetectedDependency,
+  DraftedAnnotation,
+  DraftedFile,
+  UnannotatedSymbol,
+  WizardOptions,
+  WizardResult,
+} from './types.js';
+
+const MAX_SUGGESTED_TAGS = 3;
+
+// Packages whose use means talking to a database or a third-party API,
+// by the name the annotation should give it
+const KNOWN_PACKAGES: Readonly<
+  Record<string, { readonly kind: DependencyKind; readonly name: string }>
+> = {
+  pg: { kind: 'databases', name: 'postgres' },
+  postgres: { kind: 'databases', name: 'postgres' },
+  psycopg: { kind: 'databases', name: 'postgres' },
+  psycopg2: { kind: 'databases', name: 'postgres' },
+  asyncpg: { kind: 'databases', name: 'postgres' },
+  mysql: { kind: 'databases', name: 'mysql' },
+  mysql2: { kind: 'databases', name: 'mysql' },
+  pymysql: { kind: 'databases', name: 'mysql' },
+  mongodb: { kind: 'databases', name: 'mongodb' },
+  mongoose: { kind: 'databases', name: 'mongodb' },
+  pymongo: { kind: 'databases', name: 'mongodb' },
+  redis: { kind: 'databases', name: 'redis' },
+  ioredis: { kind: 'databases', name: 'redis' },
+  sqlite3: { kind: 'databases', name: 'sqlite' },
+  'better-sqlite3': { kind: 'databases', name: 'sqlite' },
+  '@aws-sdk/client-dynamodb': { kind: 'databases', name: 'dynamodb' },
+  '@elastic/elasticsearch': { kind: 'databases', name: 'elasticsearch' },
+  elasticsearch: { kind: 'databases', name: 'elasticsearch' },
+  stripe: { kind: 'external_apis', name: 'stripe' },
+  twilio: { kind: 'external_apis', name: 'twilio' },
+  '@sendgrid/mail': { kind: 'external_apis', name: 'sendgrid' },
+  sendgrid: { kind: 'external_apis', name: 'sendgrid' },
+  openai: { kind: 'external_apis', name: 'openai' },
+  '@anthropic-ai/sdk': { kind: 'external_apis', name: 'anthropic' },
+  anthropic: { kind: 'external_apis', name: 'anthropic' },
+  '@slack/web-api': { kind: 'external_apis', name: 'slack' },
+  slack_sdk: { kind: 'external_apis', name: 'slack' },
+  '@aws-sdk/client-s3': { kind: 'external_apis', name: 's3' },
+  '@aws-sdk/client-sqs': { kind: 'external_apis', name: 'sqs' },
+  '@octokit/rest': { kind: 'external_apis', name: 'github' },
+};
+
+// Path segments too generic to describe what a file is about
+const GENERIC_SEGMENTS: ReadonlySet<string> = new Set([
+  'src',
+  'lib',
+  'app',
+  'apps',
+  'packages',
+  'internal',
+  'pkg',
+  'index',
+  'main',
+  'utils',
+  'util',
+  'common',
+  'shared',
+]);
+
+interface Import {
+  readonly module: string;
+  /** Names the import binds in the file. */
+  readonly names: readonly string[];
+}
+
+function boundNames(clause: string): readonly string[] {
+  return clause
+    .replace(/[{}*]/g, ',')
+    .split(',')
+    .map((part) => part.trim().split(/\s+as\s+/).pop() ?? '')
+    .map((name) => name.replace(/^type\s+/, '').trim())
+    .filter((name) => /^\w+$/.test(name));
+}
+
+/** The module and bound names of an import line, in either language. */
+function parseImport(line: string): Import | undefined {
+  const es = /^import\s+(?:type\s+)?(.+?)\s+from\s+['"]([^'"]+)['"]/.exec(
+    line,
+  );
+  if (es) return { module: es[2], names: boundNames(es[1]) };
+  const required =
+    /^(?:const|let|var)\s+(.+?)\s*=\s*require\(\s*['"]([^'"]+)['"]\s*\)/.exec(
+      line,
+    );
+  if (required) return { module: required[2], names: boundNames(required[1]) };
+  const from = /^from\s+([\w.]+)\s+import\s+(.+)$/.exec(line);
+  if (from) return { module: from[1], names: boundNames(from[2]) };
+  const plain = /^import\s+([\w.]+)(?:\s+as\s+(\w+))?\s*$/.exec(line);
+  if (plain) {
+    return { module: plain[1], names: [plain[2] ?? plain[1].split('.')[0]] };
+  }
+  return undefined;
+}
+
+/** The package a module path belongs to, such as `pg` for `pg/lib`. */
+function packageOf(module: string): string {
+  return module.startsWith('@')
+    ? module.split('/').slice(0, 2).join('/')
+    : module.split(/[/.]/)[0];
+}
+
+function usesWord(source: string, word: string): boolean {
+  const escaped = word.replace(/[.*+?^${}()|[\]\\-]/g, '\\$&');
+  return new RegExp(`(^|[^\\w-])${escaped}($|[^\\w-])`).test(source);
+}
+
+/**
+ * The databases, third-party APIs, and known services `symbol` looks like
+ * it calls: packages from `imports` whose names its code uses, and any of
+ * `services` it names. These are guesses for a person to confirm.
+ */
+export function detectDependencies(
+  symbol: UnannotatedSymbol,
+  imports: readonly string[],
+  services: readonly string[] = [],
+): readonly DetectedDependency[] {
+  const found = new Map<string, DetectedDependency>();
+  const add = (dependency: DetectedDependency): void => {
+    const key = `${dependency.kind}:${dependency.name}`;
+    if (!found.has(key)) found.set(key, dependency);
+  };
+  for (const line of imports) {
+    const parsed = parseImport(line.trim());
+    if (!parsed) continue;
+    const known =
+      KNOWN_PACKAGES[parsed.module] ?? KNOWN_PACKAGES[packageOf(parsed.module)];
+    if (!known) continue;
+    if (!parsed.names.some((name) => usesWord(symbol.source, name))) continue;
+    add({ ...known, reason: `uses ${parsed.module}` });
+  }
+  for (const service of services) {
+    if (service === symbol.name || !usesWord(symbol.source, service)) continue;
+    add({ kind: 'services', name: service, reason: `mentions ${service}` });
+  }
+  return [...found.values()];
+}
+
+/** Tags suggested by the directories and file name `filePath` sits in. */
+export function suggestTags(filePath: string): readonly string[] {
+  const segments = filePath
+    .replace(/\.[^./]+$/, '')
+    .split('/')
+    .flatMap((segment) => segment.toLowerCase().split(/[._]/))
+    .filter(
+      (segment) =>
+        /^[a-z][a-z0-9-]*$/.test(segment) &&
+        !GENERIC_SEGMENTS.has(segment) &&
+        !segment.startsWith('test'),
+    );
+  return [...new Set(segments)].slice(-MAX_SUGGESTED_TAGS);
+}
+
+function splitList(answer: string): readonly string[] {
+  const items = answer
+    .split(',')
+    .map((item) => item.trim().toLowerCase())
+    .filter((item) => item.length > 0);
+  return [...new Set(items)];
+}
+
+function isYes(answer: string): boolean {
+  return /^y(es)?$/i.test(answer.trim());
+}
+
+/** What came of asking about one symbol. */
+type Outcome = DraftedAnnotation | 'skip' | 'quit';
+
+async function askSymbol(
+  symbol: UnannotatedSymbol,
+  detected: readonly DetectedDependency[],
+  options: WizardOptions,
+  lastOwner: string | undefined,
+): Promise<Outcome> {
+  const { prompter } = options;
+  const ask = async (
+    question: string,
+    suggested?: string,
+  ): Promise<string | null> => {
+    const answer = await prompter.ask(question, suggested);
+    if (answer === null) return null;
+    return answer.trim() === '' ? (suggested ?? '') : answer.trim();
+  };
+
+  prompter.say(
+    `${symbol.filePath}:${symbol.line} ${symbol.entityType} ${symbol.name}`,
+  );
+  prompter.say(symbol.source.split('\n').slice(0, 8).join('\n'));
+  const proceed = await ask('Annotate it? (y)es, (s)kip, (q)uit', 'y');
+  if (proceed === null || /^q/i.test(proceed)) return 'quit';
+  if (!isYes(proceed)) return 'skip';
+
+  const description = await ask('What does it do, and why? (blank skips)');
+  if (description === null) return 'quit';
+  if (!description) return 'skip';
+
+  let type: EntityType = symbol.entityType;
+  for (;;) {
+    const answer = await ask('Type', symbol.entityType);
+    if (answer === null) return 'quit';
+    const parsed = EntityTypeSchema.safeParse(answer);
+    if (parsed.success) {
+      type = parsed.data;
+      break;
+    }
+    prompter.say(`Use one of ${EntityTypeSchema.options.join(', ')}`);
+  }
+
+  const owner = await ask('Owner? (- for none)', lastOwner ?? options.owner);
+  if (owner === null) return 'quit';
+  const tags = await ask(
+    'Tags, comma-separated? (- for none)',
+    suggestTags(symbol.filePath).join(', ') || undefined,
+  );
+  if (tags === null) return 'quit';
+
+  const dependencies: Partial<Record<DependencyKind, string[]>> = {};
+  for (const dependency of detected) {
+    const answer = await ask(
+      `Depends on ${dependency.kind.replace('_', ' ')} ${dependency.name} (${dependency.reason})?`,
+      'y',
+    );
+    if (answer === null) return 'quit';
+    if (!isYes(answer)) continue;
+    (dependencies[dependency.kind] ??= []).push(dependency.name);
+  }
+
+  const tagList = tags === '-' ? [] : splitList(tags);
+  const { metadata, errors } = validateMetadata({
+    type,
+    description: description.replace(/\s+/g, ' '),
+    ...(owner && owner !== '-' ? { owner } : {}),
+    ...(tagList.length > 0 ? { tags: tagList } : {}),
+    ...(Object.keys(dependencies).length > 0 ? { dependencies } : {}),
+  });
+  if (!metadata) {
+    prompter.say(errors[0]?.message ?? 'The answers make no valid block');
+    return 'skip';
+  }
+  const fields = metadata as ExtendedMetadata;
+  return {
+    symbol,
+    metadata: fields,
+    block: renderDraftYaml(fields).join('\n'),
+  };
+}
+
+/**
+ * Ask about each unannotated symbol under `path`, a directory or one file,
+ * in file and line order: whether to annotate it, what it does, its type,
+ * owner, and tags, and whether it depends on each dependency its code
+ * suggests. Each answered block is written into its file before the next
+ * question unless `write` is false, so quitting keeps what was answered.
+ * The blocks are a person's, so none is marked generated.
+ */
+export async function annotateInteractively(
+  path: string,
+  options: WizardOptions,
+): Promise<WizardResult> {
+  const { prompter, services = [], limit = Infinity, write = true } = options;
+  const { rootDir, files } = listDraftFiles(path);
+  const annotated: DraftedAnnotation[] = [];
+  const pending = files.map((filePath) => {
+    const content = readFileSync(join(rootDir, filePath), 'utf-8');
+    const symbols = findUnannotatedSymbols(content, filePath);
+    return { filePath, content, symbols };
+  });
+  const found = pending.reduce((n, file) => n + file.symbols.length, 0);
+  const drafted: DraftedFile[] = [];
+  let skipped = 0;
+  let asked = 0;
+  let lastOwner: string | undefined;
+  const result = (quit: boolean): WizardResult => ({
+    annotated,
+    files: drafted,
+    skipped,
+    found,
+    quit,
+  });
+
+  for (const file of pending) {
+    let { content } = file;
+    const drafts: DraftedAnnotation[] = [];
+    const imports = importLines(content);
+    for (const { name } of file.symbols) {
+      if (asked >= limit) return result(false);
+      // Earlier blocks move the lines below them, so find it again
+      const symbol = findUnannotatedSymbols(content, file.filePath).find(
+        (candidate) => candidate.name === name,
+      );
+      if (!symbol) continue;
+      asked++;
+      const detected = detectDependencies(symbol, imports, services);
+      const outcome = await askSymbol(symbol, detected, options, lastOwner);
+      if (outcome === 'quit') return result(true);
+      if (outcome === 'skip') {
+        skipped++;
+        continue;
+      }
+      const updated = insertAnnotation(content, file.filePath, outcome);
+      if (!updated) {
+        prompter.say('The parser would not read a block at this declaration');
+        skipped++;
+        continue;
+      }
+      content = updated;
+      if (write) writeFileSync(join(rootDir, file.filePath), content, 'utf-8');
+      annotated.push(outcome);
+      drafts.push(outcome);
+      // Updated as each block lands, so quitting keeps the file's entry
+      const entry = {
+        filePath: file.filePath,
+        drafts,
+        before: file.content,
+        content,
+      };
+      if (drafts.length === 1) drafted.push(entry);
+      else drafted[drafted.length - 1] = entry;
+      lastOwner = outcome.metadata.owner ?? lastOwner;
+    }
+  }
+  return result(false);
+}
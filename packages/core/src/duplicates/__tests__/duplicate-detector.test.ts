@@ -0,0 +1,125 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import { detectDuplicates, findSimilarPairs } from '../duplicate-detector.js';
+
+function makeEntity(
+  name: string,
+  description: string,
+  tags: readonly string[],
+  owner: string | null,
+  entityType: StoredEntity['entityType'] = 'module',
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType,
+    description,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner,
+    status: 'stable',
+    metadata: { type: entityType, description },
+    tags: [...tags],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const entities = [
+  makeEntity(
+    'retryWithBackoff',
+    'Retries failed HTTP requests with exponential backoff',
+    ['retry', 'http'],
+    'payments-team',
+  ),
+  makeEntity(
+    'httpRetry',
+    'Retry failed HTTP requests using exponential backoff and jitter',
+    ['retry', 'http'],
+    'search-team',
+  ),
+  makeEntity(
+    'backoffRetrier',
+    'Exponential backoff retry for failed requests',
+    ['retry'],
+    'payments-team',
+  ),
+  makeEntity(
+    'rateLimiter',
+    'Token bucket rate limiter for outbound API calls',
+    ['rate-limit'],
+    'platform-team',
+  ),
+  makeEntity(
+    'renderInvoice',
+    'Renders invoice PDFs for customers',
+    ['billing'],
+    'billing-team',
+  ),
+];
+
+describe('findSimilarPairs', () => {
+  it('scores description similarity and tag overlap', () => {
+    const pairs = findSimilarPairs(entities);
+    expect(pairs[0]).toEqual(
+      expect.objectContaining({
+        a: 'id-retryWithBackoff',
+        b: 'id-httpRetry',
+        tagOverlap: 1,
+      }),
+    );
+    expect(pairs[0].score).toBeGreaterThan(0.8);
+    expect(pairs.every((pair) => pair.score >= 0.5)).toBe(true);
+  });
+
+  it('respects a custom threshold', () => {
+    expect(findSimilarPairs(entities, { threshold: 0.95 })).toEqual([]);
+  });
+});
+
+describe('detectDuplicates', () => {
+  it('clusters the retry implementations', () => {
+    const report = detectDuplicates(entities);
+    expect(report.compared).toBe(5);
+    expect(report.clusters).toHaveLength(1);
+
+    const [cluster] = report.clusters;
+    expect(cluster.members.map((m) => m.name).sort()).toEqual([
+      'backoffRetrier',
+      'httpRetry',
+      'retryWithBackoff',
+    ]);
+    expect(cluster.owners).toEqual(['payments-team', 'search-team']);
+    expect(cluster.sharedTerms).toEqual([
+      'backoff',
+      'exponential',
+      'fail',
+      'request',
+      'retry',
+    ]);
+  });
+
+  it('can restrict to clusters spanning several teams', () => {
+    const sameTeam = entities.map((entity) => ({
+      ...entity,
+      owner: 'payments-team',
+    }));
+    expect(detectDuplicates(sameTeam).clusters).toHaveLength(1);
+    expect(
+      detectDuplicates(sameTeam, { crossTeamOnly: true }).clusters,
+    ).toHaveLength(0);
+  });
+
+  it('only compares the configured entity types', () => {
+    const report = detectDuplicates(entities, { entityTypes: ['service'] });
+    expect(report.compared).toBe(0);
+    expect(report.clusters).toEqual([]);
+  });
+});
@@ -0,0 +1,59 @@
+import { describe, it, expect } from 'vitest';
+import {
+  buildTfIdfVectors,
+  cosineSimilarity,
+  jaccard,
+  tokenize,
+} from '../tfidf.js';
+
+describe('tokenize', () => {
+  it('splits camelCase, drops stop words, and stems', () => {
+    expect(tokenize('retryWithBackoff handles failed Requests')).toEqual([
+      'retry',
+      'backoff',
+      'fail',
+      'request',
+    ]);
+  });
+
+  it('maps -ies plurals to -y', () => {
+    expect(tokenize('Retries policies')).toEqual(['retry', 'policy']);
+  });
+});
+
+describe('buildTfIdfVectors', () => {
+  it('produces unit-length vectors', () => {
+    const [vector] = buildTfIdfVectors([
+      ['retry', 'backoff'],
+      ['invoice'],
+    ]);
+    const norm = Math.sqrt(
+      [...vector.values()].reduce((sum, w) => sum + w * w, 0),
+    );
+    expect(norm).toBeCloseTo(1);
+  });
+
+  it('handles empty documents', () => {
+    const [vector] = buildTfIdfVectors([[]]);
+    expect(vector.size).toBe(0);
+  });
+});
+
+describe('cosineSimilarity', () => {
+  it('is 1 for identical documents and 0 for disjoint ones', () => {
+    const [a, b, c] = buildTfIdfVectors([
+      ['retry', 'backoff'],
+      ['retry', 'backoff'],
+      ['invoice', 'pdf'],
+    ]);
+    expect(cosineSimilarity(a, b)).toBeCloseTo(1);
+    expect(cosineSimilarity(a, c)).toBe(0);
+  });
+});
+
+describe('jaccard', () => {
+  it('computes set overlap', () => {
+    expect(jaccard(['retry', 'http'], ['retry'])).toBe(0.5);
+    expect(jaccard([], [])).toBe(0);
+  });
+});
@@ -0,0 +1,174 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Flags clusters of entities with similar descriptions and overlapping tags as consolidation candidates
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [duplicates, similarity, clustering, report]
+ * context:
+ *   business_goal: Find the third retry library before someone writes a fourth
+ *   domain: duplicates
+ */
+import type { StoredEntity } from '../indexer/types.js';
+import type { EntityType } from '../types/entity.js';
+import {
+  buildTfIdfVectors,
+  cosineSimilarity,
+  jaccard,
+  tokenize,
+} from './tfidf.js';
+import type {
+  DuplicateCluster,
+  DuplicateDetectorOptions,
+  DuplicateMember,
+  DuplicateReport,
+  SimilarPair,
+} from './types.js';
+
+const DEFAULT_THRESHOLD = 0.5;
+const DEFAULT_DESCRIPTION_WEIGHT = 0.7;
+const DEFAULT_ENTITY_TYPES: readonly EntityType[] = [
+  'module',
+  'service',
+  'class',
+  'function',
+];
+const MAX_SHARED_TERMS = 5;
+
+function toMember(entity: StoredEntity): DuplicateMember {
+  return {
+    entityId: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    filePath: entity.filePath,
+    owner: entity.owner,
+    description: entity.description,
+  };
+}
+
+function entityText(entity: StoredEntity): string {
+  return `${entity.name} ${entity.description}`;
+}
+
+/**
+ * Score every pair of candidate entities and return those at or above the
+ * threshold, highest first.
+ */
+export function findSimilarPairs(
+  entities: readonly StoredEntity[],
+  options: DuplicateDetectorOptions = {},
+): readonly SimilarPair[] {
+  const threshold = options.threshold ?? DEFAULT_THRESHOLD;
+  const weight = options.descriptionWeight ?? DEFAULT_DESCRIPTION_WEIGHT;
+  const vectors = buildTfIdfVectors(
+    entities.map((entity) => tokenize(entityText(entity))),
+  );
+  const tags = entities.map((entity) =>
+    entity.tags.map((tag) => tag.toLowerCase()),
+  );
+
+  const pairs: SimilarPair[] = [];
+  for (let i = 0; i < entities.length; i++) {
+    for (let j = i + 1; j < entities.length; j++) {
+      const descriptionSimilarity = cosineSimilarity(vectors[i], vectors[j]);
+      const tagOverlap = jaccard(tags[i], tags[j]);
+      const hasTags = tags[i].length > 0 || tags[j].length > 0;
+      const score = hasTags
+        ? weight * descriptionSimilarity + (1 - weight) * tagOverlap
+        : descriptionSimilarity;
+      if (score < threshold) continue;
+      pairs.push({
+        a: entities[i].id,
+        b: entities[j].id,
+        score: Math.round(score * 100) / 100,
+        descriptionSimilarity: Math.round(descriptionSimilarity * 100) / 100,
+        tagOverlap: Math.round(tagOverlap * 100) / 100,
+      });
+    }
+  }
+  return pairs.sort((x, y) => y.score - x.score);
+}
+
+function clusterPairs(
+  ids: readonly string[],
+  pairs: readonly SimilarPair[],
+): readonly (readonly string[])[] {
+  const parent = new Map(ids.map((id) => [id, id]));
+  const find = (id: string): string => {
+    let root = id;
+    while (parent.get(root) !== root) root = parent.get(root) ?? root;
+    parent.set(id, root);
+    return root;
+  };
+  for (const pair of pairs) {
+    parent.set(find(pair.a), find(pair.b));
+  }
+
+  const groups = new Map<string, string[]>();
+  for (const id of ids) {
+    const root = find(id);
+    groups.set(root, [...(groups.get(root) ?? []), id]);
+  }
+  return [...groups.values()].filter((group) => group.length > 1);
+}
+
+function sharedTerms(members: readonly StoredEntity[]): readonly string[] {
+  const counts = new Map<string, number>();
+  for (const member of members) {
+    for (const token of new Set(tokenize(entityText(member)))) {
+      counts.set(token, (counts.get(token) ?? 0) + 1);
+    }
+  }
+  return [...counts.entries()]
+    .filter(([, count]) => count === members.length)
+    .sort((a, b) => a[0].localeCompare(b[0]))
+    .slice(0, MAX_SHARED_TERMS)
+    .map(([token]) => token);
+}
+
+/**
+ * Group entities linked by similar pairs into clusters of likely duplicate
+ * functionality. A cluster's score is the best pair score inside it.
+ */
+export function detectDuplicates(
+  entities: readonly StoredEntity[],
+  options: DuplicateDetectorOptions = {},
+): DuplicateReport {
+  const types = new Set(options.entityTypes ?? DEFAULT_ENTITY_TYPES);
+  const candidates = entities.filter((entity) => types.has(entity.entityType));
+  const byId = new Map(candidates.map((entity) => [entity.id, entity]));
+  const pairs = findSimilarPairs(candidates, options);
+
+  const clusters: DuplicateCluster[] = clusterPairs(
+    candidates.map((entity) => entity.id),
+    pairs,
+  )
+    .map((ids) => {
+      const members = ids
+        .map((id) => byId.get(id))
+        .filter((entity): entity is StoredEntity => entity !== undefined);
+      const idSet = new Set(ids);
+      const score = Math.max(
+        ...pairs
+          .filter((pair) => idSet.has(pair.a) && idSet.has(pair.b))
+          .map((pair) => pair.score),
+      );
+      const owners = [
+        ...new Set(
+          members
+            .map((member) => member.owner)
+            .filter((owner): owner is string => owner !== null),
+        ),
+      ].sort();
+      return {
+        members: members.map(toMember),
+        owners,
+        sharedTerms: sharedTerms(members),
+        score,
+      };
+    })
+    .filter((cluster) => !options.crossTeamOnly || cluster.owners.length > 1)
+    .sort((a, b) => b.score - a.score || b.members.length - a.members.length);
+
+  return { compared: candidates.length, clusters };
+}
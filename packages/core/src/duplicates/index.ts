@@ -0,0 +1,15 @@
+export type {
+  DuplicateDetectorOptions,
+  DuplicateMember,
+  SimilarPair,
+  DuplicateCluster,
+  DuplicateReport,
+} from './types.js';
+export type { SparseVector } from './tfidf.js';
+export {
+  tokenize,
+  buildTfIdfVectors,
+  cosineSimilarity,
+  jaccard,
+} from './tfidf.js';
+export { findSimilarPairs, detectDuplicates } from './duplicate-detector.js';
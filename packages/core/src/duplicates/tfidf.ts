@@ -0,0 +1,137 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Tokenizer, TF-IDF vectorizer, and cosine/Jaccard similarity helpers for entity text
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [duplicates, tfidf, similarity, text]
+ * context:
+ *   business_goal: Compare entity descriptions without an embedding service
+ *   domain: duplicates
+ */
+
+export type SparseVector = ReadonlyMap<string, number>;
+
+const STOP_WORDS = new Set([
+  'a',
+  'an',
+  'and',
+  'are',
+  'as',
+  'at',
+  'be',
+  'by',
+  'for',
+  'from',
+  'has',
+  'in',
+  'into',
+  'is',
+  'it',
+  'its',
+  'of',
+  'on',
+  'or',
+  'that',
+  'the',
+  'this',
+  'to',
+  'with',
+  'via',
+  'using',
+  'used',
+  'uses',
+  'all',
+  'any',
+  'each',
+  'handles',
+  'handle',
+  'provides',
+  'provide',
+  'support',
+  'supports',
+]);
+
+function stem(word: string): string {
+  if (word.length > 4 && word.endsWith('ies')) return `${word.slice(0, -3)}y`;
+  if (word.length > 5 && word.endsWith('ing')) return word.slice(0, -3);
+  if (word.length > 4 && word.endsWith('ed')) return word.slice(0, -2);
+  if (word.length > 3 && word.endsWith('s') && !word.endsWith('ss')) {
+    return word.slice(0, -1);
+  }
+  return word;
+}
+
+/**
+ * Lower-case, split camelCase and punctuation, drop stop words and very
+ * short tokens, and apply a light suffix stemmer.
+ */
+export function tokenize(text: string): readonly string[] {
+  return text
+    .replace(/([a-z])([A-Z])/g, '$1 $2')
+    .toLowerCase()
+    .split(/[^a-z0-9]+/)
+    .filter((token) => token.length > 2 && !STOP_WORDS.has(token))
+    .map(stem);
+}
+
+/**
+ * Build L2-normalized TF-IDF vectors for a corpus of token lists, using
+ * smoothed IDF so terms present in every document still carry some weight.
+ */
+export function buildTfIdfVectors(
+  documents: readonly (readonly string[])[],
+): readonly SparseVector[] {
+  const documentFrequency = new Map<string, number>();
+  for (const tokens of documents) {
+    for (const token of new Set(tokens)) {
+      documentFrequency.set(token, (documentFrequency.get(token) ?? 0) + 1);
+    }
+  }
+
+  const total = documents.length;
+  return documents.map((tokens) => {
+    const counts = new Map<string, number>();
+    for (const token of tokens) {
+      counts.set(token, (counts.get(token) ?? 0) + 1);
+    }
+
+    const weights = new Map<string, number>();
+    let norm = 0;
+    for (const [token, count] of counts) {
+      const df = documentFrequency.get(token) ?? 0;
+      const idf = Math.log((1 + total) / (1 + df)) + 1;
+      const weight = (count / tokens.length) * idf;
+      weights.set(token, weight);
+      norm += weight * weight;
+    }
+
+    const length = Math.sqrt(norm);
+    return new Map(
+      [...weights].map(([token, weight]) => [
+        token,
+        length ? weight / length : 0,
+      ]),
+    );
+  });
+}
+
+export function cosineSimilarity(a: SparseVector, b: SparseVector): number {
+  const [small, large] = a.size <= b.size ? [a, b] : [b, a];
+  let dot = 0;
+  for (const [token, weight] of small) {
+    dot += weight * (large.get(token) ?? 0);
+  }
+  return dot;
+}
+
+export function jaccard(a: readonly string[], b: readonly string[]): number {
+  const left = new Set(a);
+  const right = new Set(b);
+  if (left.size === 0 && right.size === 0) return 0;
+  let intersection = 0;
+  for (const item of left) {
+    if (right.has(item)) intersection++;
+  }
+  return intersection / (left.size + right.size - intersection);
+}
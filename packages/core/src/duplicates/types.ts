@@ -0,0 +1,52 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for duplicate-functionality detection and consolidation candidate reports
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [duplicates, similarity, types, interface]
+ * context:
+ *   business_goal: Define contracts for finding overlapping capabilities built by different teams
+ *   domain: duplicates
+ */
+import type { EntityType } from '../types/entity.js';
+
+export interface DuplicateDetectorOptions {
+  /** Minimum combined similarity (0-1) for two entities to be linked. */
+  readonly threshold?: number;
+  /** Weight of description similarity; tag overlap gets the remainder. */
+  readonly descriptionWeight?: number;
+  /** Only report clusters whose members have more than one owner. */
+  readonly crossTeamOnly?: boolean;
+  /** Entity types to compare. Defaults to module, service, class, function. */
+  readonly entityTypes?: readonly EntityType[];
+}
+
+export interface DuplicateMember {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly owner: string | null;
+  readonly description: string;
+}
+
+export interface SimilarPair {
+  readonly a: string;
+  readonly b: string;
+  readonly score: number;
+  readonly descriptionSimilarity: number;
+  readonly tagOverlap: number;
+}
+
+export interface DuplicateCluster {
+  readonly members: readonly DuplicateMember[];
+  readonly owners: readonly string[];
+  readonly sharedTerms: readonly string[];
+  readonly score: number;
+}
+
+export interface DuplicateReport {
+  readonly compared: number;
+  readonly clusters: readonly DuplicateCluster[];
+}
@@ -0,0 +1,113 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import {
+  existsSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { randomBytes } from 'node:crypto';
+import { classifyError } from '../../errors/errors.js';
+import {
+  decryptBytes,
+  encryptBytes,
+  isEncrypted,
+  isEncryptedFile,
+  parseEncryptionKey,
+  readStoreFile,
+  writeStoreFile,
+} from '../encryption.js';
+
+const KEY = randomBytes(32);
+const PLAIN = Buffer.from('checkout depends on orders-db');
+
+describe('parseEncryptionKey', () => {
+  it('reads 64 hex digits and 44 base64 characters', () => {
+    expect(parseEncryptionKey(KEY.toString('hex'))).toEqual(KEY);
+    expect(parseEncryptionKey(`${KEY.toString('base64')}\n`)).toEqual(KEY);
+  });
+
+  it('rejects keys of the wrong length as usage errors', () => {
+    expect(() => parseEncryptionKey('abc123', 'KG_KEY')).toThrow(/KG_KEY/);
+    try {
+      parseEncryptionKey(randomBytes(16).toString('hex'));
+    } catch (err) {
+      expect(classifyError(err)).toBe('usage');
+    }
+  });
+});
+
+describe('encryptBytes and decryptBytes', () => {
+  it('round-trips through a fresh IV each time', () => {
+    const first = encryptBytes(PLAIN, KEY);
+    const second = encryptBytes(PLAIN, KEY);
+    expect(isEncrypted(first)).toBe(true);
+    expect(first.equals(second)).toBe(false);
+    expect(first.includes(PLAIN)).toBe(false);
+    expect(decryptBytes(first, KEY)).toEqual(PLAIN);
+  });
+
+  it('fails with an io error under the wrong key or after tampering', () => {
+    const sealed = encryptBytes(PLAIN, KEY);
+    expect(() => decryptBytes(sealed, randomBytes(32), 'graph.db')).toThrow(
+      /Cannot decrypt graph\.db/,
+    );
+
+    const tampered = Buffer.from(sealed);
+    tampered[tampered.length - 1] ^= 1;
+    try {
+      decryptBytes(tampered, KEY);
+      expect.unreachable();
+    } catch (err) {
+      expect(classifyError(err)).toBe('io');
+    }
+  });
+
+  it('does not take plain bytes for encrypted ones', () => {
+    expect(isEncrypted(PLAIN)).toBe(false);
+    expect(() => decryptBytes(PLAIN, KEY)).toThrow(/Cannot decrypt/);
+  });
+});
+
+describe('store files', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-encryption-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('writes plain without a key and encrypted with one', () => {
+    const path = join(dir, 'graph.kgs');
+    writeStoreFile(path, PLAIN);
+    expect(readFileSync(path)).toEqual(PLAIN);
+    expect(isEncryptedFile(path)).toBe(false);
+
+    writeStoreFile(path, PLAIN, { encryptionKey: KEY });
+    expect(isEncryptedFile(path)).toBe(true);
+    expect(existsSync(`${path}.tmp`)).toBe(false);
+    expect(readStoreFile(path, { encryptionKey: KEY })).toEqual(PLAIN);
+  });
+
+  it('reads plain files with or without a key', () => {
+    const path = join(dir, 'plain.kgs');
+    writeFileSync(path, PLAIN);
+    expect(readStoreFile(path)).toEqual(PLAIN);
+    expect(readStoreFile(path, { encryptionKey: KEY })).toEqual(PLAIN);
+  });
+
+  it('asks for a key to read an encrypted file', () => {
+    const path = join(dir, 'graph.kgs');
+    writeStoreFile(path, PLAIN, { encryptionKey: KEY });
+    expect(() => readStoreFile(path)).toThrow(/is encrypted; set encryption/);
+  });
+
+  it('treats a missing file as not encrypted', () => {
+    expect(isEncryptedFile(join(dir, 'missing.db'))).toBe(false);
+  });
+});
@@ -0,0 +1,160 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Encrypts and decrypts index and snapshot files with AES-256-GCM, written atomically
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [encryption, security, aes-gcm, storage]
+ * context:
+ *   business_goal: Keep the architecture and compliance data a graph aggregates unreadable on a lost laptop
+ *   domain: security
+ */
+import { createCipheriv, createDecipheriv, randomBytes } from 'node:crypto';
+import {
+  closeSync,
+  openSync,
+  readFileSync,
+  readSync,
+  renameSync,
+  writeFileSync,
+} from 'node:fs';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { EncryptionOptions } from './types.js';
+
+/** Leads every encrypted file: the format's name and version. */
+export const ENCRYPTION_MAGIC = Buffer.from('KGENC1\0', 'latin1');
+
+const CIPHER = 'aes-256-gcm';
+const KEY_BYTES = 32;
+const IV_BYTES = 12;
+const TAG_BYTES = 16;
+
+/**
+ * The key `text` spells, as 64 hex digits or 44 base64 characters of 32
+ * bytes. `source` names where it came from in the error for anything else.
+ */
+export function parseEncryptionKey(
+  text: string,
+  source = 'The encryption key',
+): Buffer {
+  const trimmed = text.trim();
+  if (/^[0-9a-f]{64}$/i.test(trimmed)) return Buffer.from(trimmed, 'hex');
+  if (/^[A-Za-z0-9+/]{43}=$/.test(trimmed)) {
+    return Buffer.from(trimmed, 'base64');
+  }
+  throw createKnowgraphError(
+    'usage',
+    `${source} must be ${KEY_BYTES} bytes, as 64 hex digits or 44 base64 characters`,
+  );
+}
+
+/** Whether `bytes` start like a file `encryptBytes` wrote. */
+export function isEncrypted(bytes: Uint8Array): boolean {
+  return Buffer.from(
+    bytes.buffer,
+    bytes.byteOffset,
+    Math.min(bytes.byteLength, ENCRYPTION_MAGIC.length),
+  ).equals(ENCRYPTION_MAGIC);
+}
+
+/** Whether the file at `path` is encrypted; false when it is missing. */
+export function isEncryptedFile(path: string): boolean {
+  let fd: number;
+  try {
+    fd = openSync(path, 'r');
+  } catch {
+    return false;
+  }
+  try {
+    const head = Buffer.alloc(ENCRYPTION_MAGIC.length);
+    const read = readSync(fd, head, 0, head.length, 0);
+    return isEncrypted(head.subarray(0, read));
+  } finally {
+    closeSync(fd);
+  }
+}
+
+/**
+ * `plain` encrypted under `key`: the magic, a random IV, the GCM tag, then
+ * the ciphertext. The magic is authenticated along with the content.
+ */
+export function encryptBytes(plain: Uint8Array, key: Uint8Array): Buffer {
+  const iv = randomBytes(IV_BYTES);
+  const cipher = createCipheriv(CIPHER, key, iv);
+  cipher.setAAD(ENCRYPTION_MAGIC);
+  const body = Buffer.concat([cipher.update(plain), cipher.final()]);
+  return Buffer.concat([ENCRYPTION_MAGIC, iv, cipher.getAuthTag(), body]);
+}
+
+/**
+ * The plain bytes `encryptBytes` encrypted. Throws an `io` error when the
+ * key is wrong or the bytes were changed; `label` names them in it.
+ */
+export function decryptBytes(
+  bytes: Uint8Array,
+  key: Uint8Array,
+  label = 'the file',
+): Buffer {
+  const data = Buffer.from(bytes.buffer, bytes.byteOffset, bytes.byteLength);
+  const ivStart = ENCRYPTION_MAGIC.length;
+  const tagStart = ivStart + IV_BYTES;
+  const bodyStart = tagStart + TAG_BYTES;
+  try {
+    if (!isEncrypted(data) || data.length < bodyStart) {
+      throw new Error('not an encrypted file');
+    }
+    const decipher = createDecipheriv(
+      CIPHER,
+      key,
+      data.subarray(ivStart, tagStart),
+    );
+    decipher.setAAD(ENCRYPTION_MAGIC);
+    decipher.setAuthTag(data.subarray(tagStart, bodyStart));
+    return Buffer.concat([
+      decipher.update(data.subarray(bodyStart)),
+      decipher.final(),
+    ]);
+  } catch (err) {
+    throw createKnowgraphError(
+      'io',
+      `Cannot decrypt ${label}: the key is wrong or the file is damaged`,
+      { cause: err },
+    );
+  }
+}
+
+/**
+ * The contents of `path`, decrypted when it is encrypted. Throws a `usage`
+ * error for an encrypted file when `options` holds no key.
+ */
+export function readStoreFile(
+  path: string,
+  options: EncryptionOptions = {},
+): Buffer {
+  const bytes = readFileSync(path);
+  if (!isEncrypted(bytes)) return bytes;
+  if (!options.encryptionKey) {
+    throw createKnowgraphError(
+      'usage',
+      `${path} is encrypted; set encryption.key_env or encryption.key_command in .knowgraph.yml to read it`,
+    );
+  }
+  return decryptBytes(bytes, options.encryptionKey, path);
+}
+
+/**
+ * Write `bytes` to `path`, encrypted when `options` holds a key, replacing
+ * the file only once the new one is complete.
+ */
+export function writeStoreFile(
+  path: string,
+  bytes: Uint8Array,
+  options: EncryptionOptions = {},
+): void {
+  const tempPath = `${path}.tmp`;
+  writeFileSync(
+    tempPath,
+    options.encryptionKey ? encryptBytes(bytes, options.encryptionKey) : bytes,
+  );
+  renameSync(tempPath, path);
+}
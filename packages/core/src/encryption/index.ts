@@ -0,0 +1,11 @@
+export {
+  ENCRYPTION_MAGIC,
+  decryptBytes,
+  encryptBytes,
+  isEncrypted,
+  isEncryptedFile,
+  parseEncryptionKey,
+  readStoreFile,
+  writeStoreFile,
+} from './encryption.js';
+export type { EncryptionOptions } from './types.js';
@@ -0,0 +1,20 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Options for encrypting the local index and graph snapshot files at rest
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [encryption, security, types, interface]
+ * context:
+ *   business_goal: Let teams turn on encryption at rest with one manifest setting
+ *   domain: security
+ */
+
+export interface EncryptionOptions {
+  /**
+   * 32-byte AES-256-GCM key. Files are written encrypted with it and
+   * encrypted files need it to be read; without one, files are written
+   * plain.
+   */
+  readonly encryptionKey?: Uint8Array;
+}
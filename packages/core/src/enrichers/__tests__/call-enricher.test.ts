@@ -0,0 +1,109 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import { createQueryEngine } from '../../query/query-engine.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import { createCallEnricher, findCallSites } from '../call-enricher.js';
+
+describe('findCallSites', () => {
+  it('finds plain, method, and constructor calls once each', () => {
+    const source = 'const c = new Client(); c.charge(x); charge(y); if (z) {}';
+    expect(findCallSites(source)).toEqual(['Client', 'charge', 'if']);
+  });
+});
+
+describe('createCallEnricher', () => {
+  let dir: string;
+  let dbManager: DatabaseManager;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-calls-'));
+    mkdirSync(join(dir, 'src'));
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+  });
+
+  afterEach(() => {
+    dbManager.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function file(path: string, ...lines: string[]): void {
+    writeFileSync(join(dir, path), lines.join('\n'));
+  }
+
+  function insert(filePath: string, name: string, line = 1): string {
+    return dbManager.insertEntity({
+      filePath,
+      name,
+      entityType: 'function',
+      description: name,
+      language: 'typescript',
+      line,
+      column: 0,
+      metadata: { type: 'function', description: name },
+    });
+  }
+
+  function enrich(): number {
+    const entities = createQueryEngine(dbManager).getAll();
+    return createCallEnricher().enrich({ rootDir: dir, dbManager, entities });
+  }
+
+  const callsOf = (id: string) =>
+    (dbManager.getEntityById(id)!.metadata as ExtendedMetadata).calls;
+
+  it('records the indexed entities each entity calls', () => {
+    file(
+      'src/checkout.ts',
+      'function checkout() {',
+      '  // refund() is for later',
+      '  return charge(cart) && log(cart);',
+      '}',
+    );
+    file('src/payments.ts', 'function charge() {}');
+    const checkout = insert('src/checkout.ts', 'checkout');
+    const charge = insert('src/payments.ts', 'charge');
+    insert('src/payments.ts', 'refund', 2);
+
+    expect(enrich()).toBe(1);
+    expect(callsOf(checkout)).toEqual(['src/payments.ts:charge']);
+    expect(callsOf(charge)).toBeUndefined();
+    expect(enrich()).toBe(0);
+  });
+
+  it('prefers the caller file for shared names and skips the rest', () => {
+    file(
+      'src/a.ts',
+      'function run() {',
+      '  format(); validate();',
+      '}',
+      'function format() {}',
+    );
+    file('src/b.ts', 'function format() {}', 'function validate() {}');
+    file('src/c.ts', 'function validate() {}');
+    const run = insert('src/a.ts', 'run');
+    insert('src/a.ts', 'format', 4);
+    insert('src/b.ts', 'format');
+    insert('src/b.ts', 'validate', 2);
+    insert('src/c.ts', 'validate');
+
+    enrich();
+    expect(callsOf(run)).toEqual(['src/a.ts:format']);
+  });
+
+  it('drops calls that are no longer made', () => {
+    file('src/a.ts', 'function run() { stop(); }');
+    file('src/b.ts', 'function stop() {}');
+    const run = insert('src/a.ts', 'run');
+    insert('src/b.ts', 'stop');
+    enrich();
+
+    file('src/a.ts', 'function run() {}');
+    expect(enrich()).toBe(1);
+    expect(callsOf(run)).toBeUndefined();
+  });
+});
@@ -0,0 +1,99 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  nodeReference,
+  parseNodeTrailers,
+  resolveNodeReference,
+  suggestNodeTrailers,
+} from '../commit-trailers.js';
+
+function makeEntity(overrides: Partial<StoredEntity> = {}): StoredEntity {
+  return {
+    id: 'checkout',
+    filePath: 'src/checkout.ts',
+    name: 'CheckoutService',
+    entityType: 'service',
+    description: 'Runs checkout for a cart',
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 12,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: { type: 'service', description: 'Runs checkout for a cart' },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const checkout = makeEntity();
+const charge = makeEntity({
+  id: 'charge',
+  name: 'charge',
+  parent: 'CheckoutService',
+  line: 20,
+});
+const ledger = makeEntity({
+  id: 'ledger',
+  filePath: 'src/ledger.ts',
+  name: 'Ledger',
+  line: 3,
+});
+const entities = [checkout, charge, ledger];
+
+describe('parseNodeTrailers', () => {
+  it('reads every Knowgraph-Node trailer in a message', () => {
+    const message = [
+      'Fix rounding in checkout',
+      '',
+      'Knowgraph-Node: src/checkout.ts:CheckoutService',
+      'knowgraph-node:  Ledger ',
+      'Signed-off-by: Ada <ada@example.com>',
+    ].join('\n');
+    expect(parseNodeTrailers(message)).toEqual([
+      'src/checkout.ts:CheckoutService',
+      'Ledger',
+    ]);
+    expect(parseNodeTrailers('No trailers here')).toEqual([]);
+  });
+});
+
+describe('resolveNodeReference', () => {
+  it('resolves the forms findSymbol accepts and skips ambiguous ones', () => {
+    expect(resolveNodeReference(entities, 'src/ledger.ts:Ledger')).toBe(
+      ledger,
+    );
+    expect(resolveNodeReference(entities, 'CheckoutService.charge')).toBe(
+      charge,
+    );
+    const twin = makeEntity({ id: 'twin', filePath: 'src/other.ts' });
+    expect(
+      resolveNodeReference([...entities, twin], 'CheckoutService'),
+    ).toBeUndefined();
+  });
+});
+
+describe('suggestNodeTrailers', () => {
+  it('suggests top-level entities in the changed files', () => {
+    expect(
+      suggestNodeTrailers(entities, ['src/ledger.ts', 'src/checkout.ts']),
+    ).toEqual([
+      'Knowgraph-Node: src/checkout.ts:CheckoutService',
+      'Knowgraph-Node: src/ledger.ts:Ledger',
+    ]);
+    expect(suggestNodeTrailers(entities, ['README.md'])).toEqual([]);
+  });
+
+  it('falls back to the line when a file repeats a name', () => {
+    const again = makeEntity({ id: 'again', line: 40 });
+    expect(nodeReference(again, [...entities, again])).toBe(
+      'src/checkout.ts:40',
+    );
+  });
+});
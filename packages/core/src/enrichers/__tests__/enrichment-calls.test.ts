@@ -0,0 +1,234 @@
+import { describe, it, expect, vi } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  createEnrichmentCalls,
+  createRateLimiters,
+  readEnrichmentCacheStats,
+} from '../enrichment-calls.js';
+
+function makeEntity(id: string, fileHash: string | null = 'h1'): StoredEntity {
+  return {
+    id,
+    filePath: `src/${id}.ts`,
+    name: id,
+    entityType: 'module',
+    description: id,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: null,
+    status: null,
+    metadata: { type: 'module', description: id },
+    tags: [],
+    links: [],
+    fileHash,
+    createdAt: '2026-10-01T00:00:00Z',
+    updatedAt: '2026-10-01T00:00:00Z',
+  };
+}
+
+const entities = ['a', 'b', 'c', 'd', 'e'].map((id) => makeEntity(id));
+
+/** Replies with each entity's id upper-cased, except for `skip`. */
+function upper(batches: string[][], skip = '') {
+  return (batch: readonly StoredEntity[]) => {
+    batches.push(batch.map((entity) => entity.id));
+    return new Map(
+      batch
+        .filter((entity) => entity.id !== skip)
+        .map((entity) => [entity.id, entity.id.toUpperCase()]),
+    );
+  };
+}
+
+describe('createRateLimiters', () => {
+  it('spaces calls through one limit by its interval', () => {
+    let clock = 0;
+    const sleep = vi.fn((ms: number) => {
+      clock += ms;
+    });
+    const limiter = createRateLimiters(
+      { github: { requestsPerMinute: 60 } },
+      () => clock,
+      sleep,
+    );
+    const first = limiter('github');
+    const second = limiter('github');
+    first();
+    clock += 400;
+    second();
+    first();
+    expect(sleep.mock.calls).toEqual([[600], [1000]]);
+  });
+
+  it('rejects a limit it does not know', () => {
+    const limiter = createRateLimiters({}, () => 0);
+    expect(() => limiter('jira')).toThrow("Unknown rate limit 'jira'");
+  });
+});
+
+describe('createEnrichmentCalls', () => {
+  it('sends everything in one call by default', () => {
+    const batches: string[][] = [];
+    const calls = createEnrichmentCalls({ name: 'owners' });
+    const replies = calls.lookup(entities, upper(batches));
+    expect(batches).toEqual([['a', 'b', 'c', 'd', 'e']]);
+    expect(replies.get('c')).toBe('C');
+    expect(calls.stats()).toEqual({ calls: 1, cached: 0 });
+  });
+
+  it('splits entities into batches, pacing each call', () => {
+    const batches: string[][] = [];
+    const pace = vi.fn();
+    const calls = createEnrichmentCalls(
+      { name: 'owners', batchSize: 2 },
+      { pace },
+    );
+    calls.lookup(entities, upper(batches));
+    expect(batches).toEqual([['a', 'b'], ['c', 'd'], ['e']]);
+    expect(pace).toHaveBeenCalledTimes(3);
+  });
+
+  it('reuses fresh replies and asks again once they expire', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-enrich-'));
+    try {
+      let clock = 1000;
+      const step = { name: 'owners', cacheTtlMs: 500 };
+      const options = { cacheDir: dir, clock: () => clock };
+      const batches: string[][] = [];
+
+      const first = createEnrichmentCalls(step, options);
+      first.lookup(entities, upper(batches, 'b'));
+      clock += 100;
+      const again = createEnrichmentCalls(step, options);
+      const replies = again.lookup(
+        [...entities, makeEntity('f')],
+        upper(batches),
+      );
+      expect(batches).toEqual([['a', 'b', 'c', 'd', 'e'], ['f']]);
+      // an empty reply is cached too, so 'b' is not asked for again
+      expect(replies.has('b')).toBe(false);
+      expect(replies.get('a')).toBe('A');
+      expect(again.stats()).toEqual({ calls: 1, cached: 5 });
+
+      clock += 500;
+      createEnrichmentCalls(step, options).lookup(entities, upper(batches));
+      expect(batches.at(-1)).toEqual(['a', 'b', 'c', 'd', 'e']);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  it('asks again for an entity whose file changed', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-enrich-'));
+    try {
+      const step = { name: 'owners', cacheTtlMs: 60_000 };
+      const batches: string[][] = [];
+      createEnrichmentCalls(step, { cacheDir: dir }).lookup(
+        [makeEntity('a')],
+        upper(batches),
+      );
+      createEnrichmentCalls(step, { cacheDir: dir }).lookup(
+        [makeEntity('a', 'h2')],
+        upper(batches),
+      );
+      expect(batches).toEqual([['a'], ['a']]);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  it('keeps replies from batches before a failing call', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-enrich-'));
+    try {
+      const step = { name: 'owners', batchSize: 2, cacheTtlMs: 60_000 };
+      const batches: string[][] = [];
+      const failing = (batch: readonly StoredEntity[]) => {
+        if (batch[0]?.id === 'c') throw new Error('403 rate limited');
+        return upper(batches)(batch);
+      };
+      expect(() =>
+        createEnrichmentCalls(step, { cacheDir: dir }).lookup(
+          entities,
+          failing,
+        ),
+      ).toThrow('403 rate limited');
+
+      createEnrichmentCalls(step, { cacheDir: dir }).lookup(
+        entities,
+        upper(batches),
+      );
+      expect(batches).toEqual([
+        ['a', 'b'],
+        ['c', 'd'],
+        ['e'],
+      ]);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  it('asks again when the input changed, and defaults the TTL', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-enrich-'));
+    try {
+      const step = { name: 'git' };
+      const options = { cacheDir: dir, defaultCacheTtlMs: 60_000 };
+      const batches: string[][] = [];
+      const lookup = (input: string) =>
+        createEnrichmentCalls(step, options).lookup(
+          [makeEntity('a')],
+          upper(batches),
+          { input },
+        );
+      lookup('head-1');
+      lookup('head-1');
+      lookup('head-2');
+      expect(batches).toEqual([['a'], ['a']]);
+
+      createEnrichmentCalls(
+        { ...step, cacheTtlMs: 0 },
+        { cacheDir: dir },
+      ).lookup([makeEntity('a')], upper(batches), { input: 'head-2' });
+      expect(batches).toHaveLength(3);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+});
+
+describe('readEnrichmentCacheStats', () => {
+  it('counts entries per enricher with the last run', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-enrich-'));
+    try {
+      expect(readEnrichmentCacheStats(join(dir, 'missing'))).toEqual([]);
+      let clock = Date.parse('2026-10-01T00:00:00Z');
+      const options = { cacheDir: dir, clock: () => clock };
+      const calls = createEnrichmentCalls(
+        { name: './plugins/owners.js', cacheTtlMs: 500 },
+        options,
+      );
+      calls.lookup(entities.slice(0, 2), upper([]));
+      clock += 100;
+      calls.lookup(entities, upper([]));
+      writeFileSync(join(dir, 'enrich-broken.json'), '{');
+
+      const [owners, broken] = readEnrichmentCacheStats(dir, clock + 450);
+      expect(owners).toMatchObject({
+        name: './plugins/owners.js',
+        entries: 5,
+        expired: 2,
+        lastRun: { at: '2026-10-01T00:00:00.100Z', calls: 2, cached: 2 },
+      });
+      expect(owners.bytes).toBeGreaterThan(0);
+      expect(broken).toMatchObject({ name: 'broken', damaged: true });
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+});
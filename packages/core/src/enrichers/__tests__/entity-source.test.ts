@@ -0,0 +1,127 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import type { EntityInsert } from '../../indexer/types.js';
+import {
+  entityReferences,
+  readEntitySources,
+  stripComments,
+  updateDerivedField,
+} from '../entity-source.js';
+
+describe('stripComments', () => {
+  it('blanks block and line comments but keeps URLs and line breaks', () => {
+    const source = [
+      '/**',
+      ' * @knowgraph charge()',
+      ' */',
+      "fetch('https://api.stripe.com/v1'); // charge()",
+    ].join('\n');
+    const stripped = stripComments(source, 'typescript');
+    expect(stripped.split('\n')).toHaveLength(4);
+    expect(stripped).toContain("fetch('https://api.stripe.com/v1');");
+    expect(stripped).not.toContain('charge');
+  });
+
+  it('blanks docstrings and hash comments in Python', () => {
+    const source = 'def pay():\n    """charge()"""\n    send()  # charge()\n';
+    const stripped = stripComments(source, 'python');
+    expect(stripped).toContain('send()');
+    expect(stripped).not.toContain('charge');
+  });
+});
+
+describe('indexed entities', () => {
+  let dir: string;
+  let dbManager: DatabaseManager;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-source-'));
+    mkdirSync(join(dir, 'src'));
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+  });
+
+  afterEach(() => {
+    dbManager.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function insert(entity: Partial<EntityInsert> & { name: string }): string {
+    return dbManager.insertEntity({
+      filePath: 'src/billing.ts',
+      entityType: 'function',
+      description: entity.name,
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      metadata: { type: entity.entityType ?? 'function', description: '' },
+      ...entity,
+    });
+  }
+
+  it('spans a module over its file and others to the next entity', () => {
+    writeFileSync(
+      join(dir, 'src/billing.ts'),
+      [
+        'class Billing {',
+        '  charge() {}',
+        '  refund() {}',
+        '}',
+        'function audit() {}',
+      ].join('\n'),
+    );
+    const module = insert({ name: 'billing', entityType: 'module' });
+    const billing = insert({ name: 'Billing', entityType: 'class' });
+    insert({ name: 'charge', parent: 'Billing', line: 2 });
+    const refund = insert({ name: 'refund', parent: 'Billing', line: 3 });
+    const audit = insert({ name: 'audit', line: 5 });
+    const missing = insert({ name: 'gone', filePath: 'src/gone.ts' });
+
+    const sources = readEntitySources(
+      dir,
+      dbManager.getEntitiesByFilePath('src/billing.ts').concat(
+        dbManager.getEntitiesByFilePath('src/gone.ts'),
+      ),
+    );
+    expect(sources.get(module)?.split('\n')).toHaveLength(5);
+    expect(sources.get(billing)).toContain('refund()');
+    expect(sources.get(billing)).not.toContain('audit');
+    expect(sources.get(refund)).toBe('  refund() {}\n}');
+    expect(sources.get(audit)).toBe('function audit() {}');
+    expect(sources.has(missing)).toBe(false);
+  });
+
+  it('references entities by path and name, or line when names clash', () => {
+    insert({ name: 'charge', line: 1 });
+    insert({ name: 'charge', line: 4 });
+    insert({ name: 'refund', line: 8 });
+
+    const all = dbManager.getEntitiesByFilePath('src/billing.ts');
+    expect([...entityReferences(all).values()].sort()).toEqual([
+      'src/billing.ts:1',
+      'src/billing.ts:4',
+      'src/billing.ts:refund',
+    ]);
+  });
+
+  it('sets and removes a derived field', () => {
+    const id = insert({ name: 'charge' });
+    const read = () => dbManager.getEntityById(id)!;
+
+    expect(updateDerivedField(dbManager, read(), 'routes', ['GET /'])).toBe(
+      true,
+    );
+    expect(updateDerivedField(dbManager, read(), 'routes', ['GET /'])).toBe(
+      false,
+    );
+    expect(read().metadata).toHaveProperty('routes', ['GET /']);
+    expect(updateDerivedField(dbManager, read(), 'routes', undefined)).toBe(
+      true,
+    );
+    expect(read().metadata).not.toHaveProperty('routes');
+  });
+});
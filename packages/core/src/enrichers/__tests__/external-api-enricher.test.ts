@@ -0,0 +1,111 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import { createQueryEngine } from '../../query/query-engine.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import {
+  apiName,
+  createExternalApiEnricher,
+  findUrls,
+  isInternalHost,
+} from '../external-api-enricher.js';
+
+describe('findUrls', () => {
+  it('finds each URL once, without trailing punctuation', () => {
+    const source = [
+      "get('https://api.stripe.com/v1/charges?limit=1');",
+      'see https://api.stripe.com/v1/charges.',
+      'fetch(`http://billing/invoices/${id}`)',
+    ].join('\n');
+    expect(findUrls(source).map((url) => url.href)).toEqual([
+      'https://api.stripe.com/v1/charges?limit=1',
+      'http://billing/invoices/',
+    ]);
+  });
+});
+
+describe('isInternalHost', () => {
+  it('tells cluster hosts from public ones', () => {
+    expect(isInternalHost('billing')).toBe(true);
+    expect(isInternalHost('localhost')).toBe(true);
+    expect(isInternalHost('10.0.0.4')).toBe(true);
+    expect(isInternalHost('billing.payments.svc')).toBe(true);
+    expect(isInternalHost('api.stripe.com')).toBe(false);
+  });
+});
+
+describe('apiName', () => {
+  it('names the organization a host belongs to', () => {
+    expect(apiName('api.stripe.com')).toBe('stripe');
+    expect(apiName('hooks.slack.com')).toBe('slack');
+    expect(apiName('www.bbc.co.uk')).toBe('bbc');
+    expect(apiName('twilio.com')).toBe('twilio');
+  });
+});
+
+describe('createExternalApiEnricher', () => {
+  let dir: string;
+  let dbManager: DatabaseManager;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-apis-'));
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+  });
+
+  afterEach(() => {
+    dbManager.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function insert(
+    filePath: string,
+    source: string,
+    metadata: Partial<ExtendedMetadata> = {},
+  ): string {
+    writeFileSync(join(dir, filePath), source);
+    const name = filePath.replace(/\.ts$/, '');
+    return dbManager.insertEntity({
+      filePath,
+      name,
+      entityType: 'service',
+      description: name,
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      metadata: { type: 'service', description: name, ...metadata },
+    });
+  }
+
+  it('joins URLs to external APIs and the services serving them', () => {
+    const checkout = insert(
+      'checkout.ts',
+      [
+        "post('https://api.stripe.com/v1/charges');",
+        "get('http://localhost:8080/invoices/42');",
+        "get('http://ledger/entries');",
+        "get('http://metrics:9090/push');",
+        "const ns = 'http://www.w3.org/2000/svg';",
+      ].join('\n'),
+      { dependencies: { external_apis: ['Stripe'] } },
+    );
+    insert('billing.ts', "app.get('/invoices/:id', show);", {
+      routes: ['GET /invoices/{id}'],
+    });
+    insert('ledger.ts', 'export {};');
+
+    const entities = createQueryEngine(dbManager).getAll();
+    createExternalApiEnricher().enrich({ rootDir: dir, dbManager, entities });
+    const metadata = dbManager.getEntityById(checkout)!
+      .metadata as ExtendedMetadata;
+    expect(metadata.http_calls).toEqual([
+      { url: 'api.stripe.com/v1/charges', api: 'Stripe' },
+      { url: 'localhost/invoices/42', service: 'billing.ts:billing' },
+      { url: 'ledger/entries', service: 'ledger.ts:ledger' },
+      { url: 'metrics/push' },
+    ]);
+  });
+});
@@ -0,0 +1,247 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import { createEnrichmentCalls } from '../enrichment-calls.js';
+import {
+  createGitEnricher,
+  parseGitCommits,
+  parseGitHistory,
+  parseGitLog,
+  topContributors,
+} from '../git-enricher.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import type { GitLogRunner } from '../types.js';
+
+const LOG = [
+  '\x1eccc\x1fal@example.com\x1f2026-03-03T11:00:00+00:00',
+  '',
+  'src/db.ts',
+  '',
+  '\x1ebbb\x1fbo@example.com\x1f2026-03-02T10:00:00+00:00',
+  '',
+  'src/api.ts',
+  '',
+  '\x1eaaa\x1fal@example.com\x1f2026-03-01T09:00:00+00:00',
+  '',
+  'src/api.ts',
+  'src/db.ts',
+  '',
+].join('\n');
+
+describe('parseGitLog', () => {
+  it('keeps the newest commit for each file', () => {
+    const commits = parseGitLog(LOG);
+    expect(commits.get('src/api.ts')).toEqual({
+      commit: 'bbb',
+      author: 'bo@example.com',
+      date: '2026-03-02T10:00:00+00:00',
+    });
+    expect(commits.get('src/db.ts')?.commit).toBe('ccc');
+    expect(commits.size).toBe(2);
+  });
+
+  it('returns nothing for an empty history', () => {
+    expect(parseGitLog('').size).toBe(0);
+  });
+});
+
+const LINKED_LOG = [
+  '\x1eddd\x1fcy@example.com\x1f2026-03-04T12:00:00+00:00\x1fFix rounding\x1fsrc/db.ts:src/db.ts\x1dmissing',
+  '',
+  'src/api.ts',
+  '',
+  LOG,
+].join('\n');
+
+describe('parseGitCommits', () => {
+  it('reads subjects and Knowgraph-Node trailers', () => {
+    const [linked, plain] = parseGitCommits(LINKED_LOG);
+    expect(linked).toEqual({
+      commit: 'ddd',
+      author: 'cy@example.com',
+      date: '2026-03-04T12:00:00+00:00',
+      subject: 'Fix rounding',
+      nodes: ['src/db.ts:src/db.ts', 'missing'],
+      paths: ['src/api.ts'],
+    });
+    expect(plain).toMatchObject({ subject: '', nodes: [] });
+  });
+});
+
+describe('parseGitHistory', () => {
+  it('lists every commit to each file, newest first', () => {
+    const history = parseGitHistory(LOG);
+    expect(history.get('src/api.ts')?.map((c) => c.commit)).toEqual([
+      'bbb',
+      'aaa',
+    ]);
+    expect(history.get('src/db.ts')?.map((c) => c.commit)).toEqual([
+      'ccc',
+      'aaa',
+    ]);
+  });
+});
+
+describe('topContributors', () => {
+  const commit = (author: string, date: string) => ({
+    commit: date,
+    author,
+    date,
+  });
+
+  it('ranks authors by commits, then by their latest commit', () => {
+    const commits = [
+      commit('cy', '2026-03-04'),
+      commit('al', '2026-03-03'),
+      commit('bo', '2026-03-02'),
+      commit('al', '2026-03-01'),
+    ];
+    expect(topContributors(commits)).toEqual([
+      { author: 'al', commits: 2, last_modified: '2026-03-03' },
+      { author: 'cy', commits: 1, last_modified: '2026-03-04' },
+      { author: 'bo', commits: 1, last_modified: '2026-03-02' },
+    ]);
+    expect(topContributors(commits, 1).map((c) => c.author)).toEqual(['al']);
+  });
+});
+
+describe('createGitEnricher', () => {
+  let dbManager: DatabaseManager;
+  const runner: GitLogRunner = { log: () => LOG };
+
+  function insert(filePath: string): string {
+    return dbManager.insertEntity({
+      filePath,
+      name: filePath,
+      entityType: 'module',
+      description: filePath,
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      metadata: { type: 'module', description: filePath },
+    });
+  }
+
+  beforeEach(() => {
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+  });
+
+  afterEach(() => {
+    dbManager.close();
+  });
+
+  it('records the last commit of each entity file', () => {
+    const api = insert('src/api.ts');
+    const untracked = insert('src/new.ts');
+    const enricher = createGitEnricher(runner);
+    const entities = [api, untracked].map((id) => dbManager.getEntityById(id)!);
+
+    expect(enricher.enrich({ rootDir: '/repo', dbManager, entities })).toBe(1);
+    expect(dbManager.getEntityById(api)!.metadata).toMatchObject({
+      git: {
+        last_commit: 'bbb',
+        last_author: 'bo@example.com',
+        last_modified: '2026-03-02T10:00:00+00:00',
+        contributors: [
+          {
+            author: 'bo@example.com',
+            commits: 1,
+            last_modified: '2026-03-02T10:00:00+00:00',
+          },
+          {
+            author: 'al@example.com',
+            commits: 1,
+            last_modified: '2026-03-01T09:00:00+00:00',
+          },
+        ],
+        recent_commits: 2,
+      },
+    });
+    expect(dbManager.getEntityById(untracked)!.metadata).not.toHaveProperty(
+      'git',
+    );
+  });
+
+  it('skips entities that are already up to date', () => {
+    const id = insert('src/db.ts');
+    const enricher = createGitEnricher(runner);
+    const read = () => [dbManager.getEntityById(id)!];
+
+    enricher.enrich({ rootDir: '/repo', dbManager, entities: read() });
+    expect(
+      enricher.enrich({ rootDir: '/repo', dbManager, entities: read() }),
+    ).toBe(0);
+  });
+
+  it('links commits whose trailers name the entity', () => {
+    const id = insert('src/db.ts');
+    const enricher = createGitEnricher({ log: () => LINKED_LOG });
+    const entities = [dbManager.getEntityById(id)!];
+
+    expect(enricher.enrich({ rootDir: '/repo', dbManager, entities })).toBe(1);
+    const metadata = dbManager.getEntityById(id)!.metadata as ExtendedMetadata;
+    expect(metadata.git?.last_commit).toBe('ccc');
+    expect(metadata.git?.linked_commits).toEqual([
+      {
+        commit: 'ddd',
+        author: 'cy@example.com',
+        date: '2026-03-04T12:00:00+00:00',
+        subject: 'Fix rounding',
+      },
+    ]);
+  });
+
+  it('counts only the newest commits of each file', () => {
+    const id = insert('src/api.ts');
+    const enricher = createGitEnricher(runner, {
+      maxContributors: 5,
+      recentCommits: 1,
+    });
+    const entities = [dbManager.getEntityById(id)!];
+
+    enricher.enrich({ rootDir: '/repo', dbManager, entities });
+    const metadata = dbManager.getEntityById(id)!.metadata as ExtendedMetadata;
+    expect(metadata.git?.contributors?.map((c) => c.author)).toEqual([
+      'bo@example.com',
+    ]);
+  });
+
+  it('reads the log again only after HEAD moves', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-git-'));
+    try {
+      const id = insert('src/db.ts');
+      let head = 'ccc';
+      let logs = 0;
+      const enricher = createGitEnricher({
+        log: () => {
+          logs += 1;
+          return LOG;
+        },
+        head: () => head,
+      });
+      const enrich = () =>
+        enricher.enrich({
+          rootDir: '/repo',
+          dbManager,
+          entities: [dbManager.getEntityById(id)!],
+          calls: createEnrichmentCalls(
+            { name: 'git' },
+            { cacheDir: dir, defaultCacheTtlMs: enricher.cacheTtlMs },
+          ),
+        });
+
+      expect(enrich()).toBe(1);
+      expect(enrich()).toBe(0);
+      expect(logs).toBe(1);
+      head = 'ddd';
+      enrich();
+      expect(logs).toBe(2);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+});
@@ -0,0 +1,170 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import { createQueryEngine } from '../../query/query-engine.js';
+import { planEnrichers, runEnrichers } from '../pipeline.js';
+import type { Enricher } from '../types.js';
+
+function recorder(name: string, calls: string[][]): Enricher {
+  return {
+    name,
+    description: name,
+    enrich({ entities }) {
+      calls.push([name, ...entities.map((entity) => entity.filePath)]);
+      return entities.length;
+    },
+  };
+}
+
+describe('planEnrichers', () => {
+  const calls: string[][] = [];
+  const available = [recorder('git', calls), recorder('routes', calls)];
+
+  it('keeps the order of the steps', () => {
+    const plan = planEnrichers(available, [
+      { name: 'routes' },
+      { name: 'git' },
+    ]);
+    expect(plan.map((p) => p.enricher.name)).toEqual(['routes', 'git']);
+  });
+
+  it('rejects unknown names', () => {
+    expect(() => planEnrichers(available, [{ name: 'gti' }])).toThrow(
+      "Unknown enricher 'gti'. Available: git, routes",
+    );
+  });
+
+  it('rejects names listed twice', () => {
+    expect(() =>
+      planEnrichers(available, [{ name: 'git' }, { name: 'git' }]),
+    ).toThrow("Enricher 'git' is listed more than once");
+  });
+
+  it('rejects rate limits that are not configured', () => {
+    expect(() =>
+      planEnrichers(available, [{ name: 'git', rateLimit: 'gihtub' }], {
+        github: { requestsPerMinute: 60 },
+      }),
+    ).toThrow(
+      "Enricher 'git' uses unknown rate limit 'gihtub'. Available: github",
+    );
+  });
+});
+
+describe('runEnrichers', () => {
+  let dbManager: DatabaseManager;
+
+  beforeEach(() => {
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+    for (const filePath of ['src/api/users.ts', 'src/db/pool.ts']) {
+      dbManager.insertEntity({
+        filePath,
+        name: filePath,
+        entityType: 'module',
+        description: filePath,
+        language: 'typescript',
+        line: 1,
+        column: 0,
+        metadata: { type: 'module', description: filePath },
+      });
+    }
+  });
+
+  afterEach(() => {
+    dbManager.close();
+  });
+
+  it('runs enabled steps in order and times each one', () => {
+    const calls: string[][] = [];
+    let clock = 0;
+    const plan = planEnrichers(
+      [recorder('a', calls), recorder('b', calls), recorder('c', calls)],
+      [{ name: 'b' }, { name: 'a', enabled: false }, { name: 'c' }],
+    );
+    const runs = runEnrichers(plan, {
+      rootDir: '/repo',
+      dbManager,
+      now: () => (clock += 5),
+    });
+
+    expect(calls.map(([name]) => name)).toEqual(['b', 'c']);
+    expect(runs).toEqual([
+      { name: 'b', status: 'ok', updated: 2, ms: 5 },
+      { name: 'a', status: 'skipped', updated: 0, ms: 0 },
+      { name: 'c', status: 'ok', updated: 2, ms: 5 },
+    ]);
+  });
+
+  it('passes only entities under the step paths', () => {
+    const calls: string[][] = [];
+    const plan = planEnrichers(
+      [recorder('routes', calls)],
+      [{ name: 'routes', paths: ['src/api/'] }],
+    );
+    runEnrichers(plan, { rootDir: '/repo', dbManager });
+    expect(calls).toEqual([['routes', 'src/api/users.ts']]);
+  });
+
+  it('records a failing step, rolls back its writes, and keeps going', () => {
+    const calls: string[][] = [];
+    const failing: Enricher = {
+      name: 'broken',
+      description: 'Always fails',
+      enrich({ dbManager, entities }) {
+        dbManager.updateEntity(entities[0].id, { owner: 'half-done' });
+        throw new Error('no network');
+      },
+    };
+    const plan = planEnrichers(
+      [failing, recorder('git', calls)],
+      [{ name: 'broken' }, { name: 'git' }],
+    );
+    const runs = runEnrichers(plan, { rootDir: '/repo', dbManager });
+
+    expect(runs[0]).toMatchObject({ status: 'failed', error: 'no network' });
+    expect(runs[1]).toMatchObject({ status: 'ok', updated: 2 });
+    const owners = createQueryEngine(dbManager)
+      .getAll()
+      .map((entity) => entity.owner);
+    expect(owners).not.toContain('half-done');
+  });
+
+  it('paces steps sharing a rate limit and reports their calls', () => {
+    const lookup = (name: string): Enricher => ({
+      name,
+      description: name,
+      enrich({ entities, calls }) {
+        const replies = calls?.lookup(
+          entities,
+          (batch) => new Map(batch.map((entity) => [entity.id, true])),
+        );
+        return replies?.size ?? 0;
+      },
+    });
+    const waits: number[] = [];
+    const limits = { api: { requestsPerMinute: 6 } };
+    const plan = planEnrichers(
+      [lookup('owners'), lookup('incidents')],
+      [
+        { name: 'owners', rateLimit: 'api', batchSize: 1 },
+        { name: 'incidents', rateLimit: 'api' },
+      ],
+      limits,
+    );
+    const runs = runEnrichers(plan, {
+      rootDir: '/repo',
+      dbManager,
+      rateLimits: limits,
+      now: () => 0,
+      sleep: (ms) => waits.push(ms),
+    });
+
+    // three calls in all; each after the first waits out the 10s interval
+    expect(waits).toEqual([10_000, 10_000]);
+    expect(runs.map((run) => run.calls)).toEqual([
+      { calls: 2, cached: 0 },
+      { calls: 1, cached: 0 },
+    ]);
+  });
+});
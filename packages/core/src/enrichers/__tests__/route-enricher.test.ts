@@ -0,0 +1,118 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import {
+  createRouteEnricher,
+  detectRoutes,
+  normalizeRoutePath,
+  routePathMatcher,
+} from '../route-enricher.js';
+
+describe('detectRoutes', () => {
+  it('reads Express, Fastify, and gin routes', () => {
+    const source = [
+      "app.get('/users/:id', show);",
+      'router.post(`/users`, create);',
+      'app.all("/health", ok);',
+      'r.GET("/orders/:id", handler)',
+      "cache.get('session')",
+    ].join('\n');
+    expect(detectRoutes(source)).toEqual([
+      'GET /users/{id}',
+      'POST /users',
+      'ANY /health',
+      'GET /orders/{id}',
+    ]);
+  });
+
+  it('reads Flask and FastAPI routes', () => {
+    const source = [
+      "@app.route('/users/<int:id>', methods=['GET', 'DELETE'])",
+      "@bp.route('/ping')",
+      "@app.put('/users/{id}')",
+    ].join('\n');
+    expect(detectRoutes(source)).toEqual([
+      'PUT /users/{id}',
+      'GET /users/{id}',
+      'DELETE /users/{id}',
+      'GET /ping',
+    ]);
+  });
+
+  it('reads net/http and Spring routes', () => {
+    const source = [
+      'mux.HandleFunc("/users/", list)',
+      'mux.Handle("POST /users/{id}", update)',
+      '@GetMapping("/accounts/{id}")',
+      '@RequestMapping(path = "/admin")',
+    ].join('\n');
+    expect(detectRoutes(source)).toEqual([
+      'ANY /users',
+      'POST /users/{id}',
+      'GET /accounts/{id}',
+      'ANY /admin',
+    ]);
+  });
+});
+
+describe('normalizeRoutePath', () => {
+  it('writes parameters as OpenAPI does', () => {
+    expect(normalizeRoutePath('/a/:id/<name>/<int:n>/')).toBe(
+      '/a/{id}/{name}/{n}',
+    );
+    expect(normalizeRoutePath('/')).toBe('/');
+  });
+});
+
+describe('routePathMatcher', () => {
+  it('matches one segment per parameter', () => {
+    const serves = routePathMatcher('GET /users/{id}');
+    expect(serves('/users/42')).toBe(true);
+    expect(serves('/users/42/')).toBe(true);
+    expect(serves('/users/42/orders')).toBe(false);
+    expect(serves('/users')).toBe(false);
+  });
+});
+
+describe('createRouteEnricher', () => {
+  let dir: string;
+  let dbManager: DatabaseManager;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-routes-'));
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+  });
+
+  afterEach(() => {
+    dbManager.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('records the routes a module registers', () => {
+    writeFileSync(
+      join(dir, 'api.ts'),
+      "// app.get('/old')\napp.get('/users', list);\n",
+    );
+    const id = dbManager.insertEntity({
+      filePath: 'api.ts',
+      name: 'api',
+      entityType: 'module',
+      description: 'api',
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      metadata: { type: 'module', description: 'api' },
+    });
+    const entities = [dbManager.getEntityById(id)!];
+
+    const enricher = createRouteEnricher();
+    expect(enricher.enrich({ rootDir: dir, dbManager, entities })).toBe(1);
+    const metadata = dbManager.getEntityById(id)!.metadata as ExtendedMetadata;
+    expect(metadata.routes).toEqual(['GET /users']);
+  });
+});
@@ -0,0 +1,92 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Enricher that records which indexed entities each entity's code calls, matching call sites to entity names
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, call-graph, calls, source]
+ * context:
+ *   business_goal: Show which code depends on which without every function declaring its callees
+ *   domain: enrichers
+ */
+import { createQueryEngine } from '../query/query-engine.js';
+import type { StoredEntity } from '../indexer/types.js';
+import {
+  entityReferences,
+  readEntitySources,
+  updateDerivedField,
+} from './entity-source.js';
+import type { Enricher } from './types.js';
+
+// `name(`, `new Name(`, and `receiver.name(`; `<T>(` generics are not seen
+const CALL_SITE = /(?<![\w$])([A-Za-z_$][\w$]*)\s*\(/g;
+
+/** The names called in `source`, each once, in order of first call. */
+export function findCallSites(source: string): readonly string[] {
+  return [...new Set([...source.matchAll(CALL_SITE)].map((m) => m[1]))];
+}
+
+/**
+ * The entity a call to `name` from `caller` most likely reaches: the only
+ * entity with that name, or else the only one in the caller's file. The
+ * caller itself, its members, and the entity it is a member of are left
+ * out, since those are its own structure rather than calls.
+ */
+function resolveCallee(
+  caller: StoredEntity,
+  named: readonly StoredEntity[],
+): StoredEntity | undefined {
+  const candidates = named.filter(
+    (entity) =>
+      entity.id !== caller.id &&
+      !(
+        entity.filePath === caller.filePath &&
+        (entity.parent === caller.name || entity.name === caller.parent)
+      ),
+  );
+  if (candidates.length === 1) return candidates[0];
+  const local = candidates.filter((e) => e.filePath === caller.filePath);
+  return local.length === 1 ? local[0] : undefined;
+}
+
+/**
+ * Set `calls` to the `path:name` of each indexed entity an entity's code
+ * calls by name, sorted. Names that several entities in other files share
+ * are left out rather than guessed at, as are calls to anything not
+ * indexed. Entities that call nothing indexed lose any `calls` they had.
+ */
+export function createCallEnricher(): Enricher {
+  return {
+    name: 'calls',
+    description: 'Indexed entities each entity calls',
+    enrich({ rootDir, dbManager, entities }) {
+      if (entities.length === 0) return 0;
+      const all = createQueryEngine(dbManager).getAll();
+      const byName = new Map<string, StoredEntity[]>();
+      for (const entity of all) {
+        const named = byName.get(entity.name) ?? [];
+        named.push(entity);
+        byName.set(entity.name, named);
+      }
+      const references = entityReferences(all);
+      const sources = readEntitySources(rootDir, entities);
+
+      let updated = 0;
+      for (const entity of entities) {
+        const source = sources.get(entity.id);
+        if (source === undefined) continue;
+        const calls = new Set<string>();
+        for (const name of findCallSites(source)) {
+          const callee = resolveCallee(entity, byName.get(name) ?? []);
+          const reference = callee && references.get(callee.id);
+          if (reference) calls.add(reference);
+        }
+        const value = calls.size > 0 ? [...calls].sort() : undefined;
+        if (updateDerivedField(dbManager, entity, 'calls', value)) {
+          updated += 1;
+        }
+      }
+      return updated;
+    },
+  };
+}
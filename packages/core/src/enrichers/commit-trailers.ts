@@ -0,0 +1,66 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads and suggests Knowgraph-Node commit trailers that link commits to graph nodes
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, git, history, trailers]
+ * context:
+ *   business_goal: Tie commits to the code they change so each node shows its recent history
+ *   domain: enrichers
+ */
+import { findSymbol } from '../explain/explain.js';
+import type { StoredEntity } from '../indexer/types.js';
+
+export const NODE_TRAILER = 'Knowgraph-Node';
+
+const TRAILER_LINE = new RegExp(`^${NODE_TRAILER}:\\s*(.+?)\\s*$`, 'gim');
+
+/** The node references in a commit message's `Knowgraph-Node` trailers. */
+export function parseNodeTrailers(message: string): readonly string[] {
+  return [...message.matchAll(TRAILER_LINE)].map((match) => match[1]);
+}
+
+/**
+ * The entity a trailer reference names, in any form `findSymbol` accepts.
+ * References that match no entity or several are ignored.
+ */
+export function resolveNodeReference(
+  entities: readonly StoredEntity[],
+  ref: string,
+): StoredEntity | undefined {
+  const matches = findSymbol(entities, ref);
+  return matches.length === 1 ? matches[0] : undefined;
+}
+
+/**
+ * A reference to `entity` for a trailer: `path:name`, or `path:line` when
+ * another entity in the file has the same name.
+ */
+export function nodeReference(
+  entity: StoredEntity,
+  entities: readonly StoredEntity[],
+): string {
+  const clash = entities.some(
+    (other) =>
+      other.id !== entity.id &&
+      other.filePath === entity.filePath &&
+      other.name === entity.name,
+  );
+  return `${entity.filePath}:${clash ? entity.line : entity.name}`;
+}
+
+/**
+ * `Knowgraph-Node` trailer lines for the top-level entities in the changed
+ * files, in file and line order.
+ */
+export function suggestNodeTrailers(
+  entities: readonly StoredEntity[],
+  changedFiles: readonly string[],
+): readonly string[] {
+  const changed = new Set(changedFiles);
+  return entities
+    .filter((entity) => entity.parent === null && changed.has(entity.filePath))
+    .sort((a, b) => a.filePath.localeCompare(b.filePath) || a.line - b.line)
+    .map((entity) => `${NODE_TRAILER}: ${nodeReference(entity, entities)}`);
+}
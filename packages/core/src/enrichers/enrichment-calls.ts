@@ -0,0 +1,249 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Batches, paces, and caches enricher calls to external services so large scans stay within their rate limits
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, rate-limit, batching, cache, ttl]
+ * context:
+ *   business_goal: Enrich a whole organization's graph without tripping API limits or re-asking for answers already known
+ *   domain: enrichers
+ */
+import {
+  existsSync,
+  mkdirSync,
+  readdirSync,
+  readFileSync,
+  statSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  EnrichmentCacheRun,
+  EnrichmentCacheStats,
+  EnricherStep,
+  EnrichmentCallStats,
+  EnrichmentCalls,
+  EnrichmentCallsOptions,
+  EnrichmentLookupOptions,
+  EnrichmentRateLimit,
+} from './types.js';
+
+/** One cached reply; `null` records that the service had nothing to add. */
+interface CachedReply {
+  readonly expiresAt: number;
+  /** The file contents and other inputs the reply was made from. */
+  readonly input: string;
+  readonly reply: unknown;
+}
+
+/**
+ * One step's cache file: a reply per entity id, so each entity keeps only
+ * its latest, and the counts of the last run that used it.
+ */
+interface ReplyCache {
+  readonly entries: Record<string, CachedReply>;
+  lastRun?: EnrichmentCacheRun;
+}
+
+const CACHE_PREFIX = 'enrich-';
+
+/** Blocks the thread, since enrichers run synchronously. */
+export function sleepSync(ms: number): void {
+  if (ms <= 0) return;
+  Atomics.wait(new Int32Array(new SharedArrayBuffer(4)), 0, 0, ms);
+}
+
+/**
+ * One pacer per named limit, shared by every step that names it, so two
+ * steps calling the same API split its budget instead of each using all
+ * of it. A pacer waits until `60000 / requestsPerMinute` ms have passed
+ * since the previous call through it.
+ */
+export function createRateLimiters(
+  limits: Readonly<Record<string, EnrichmentRateLimit>>,
+  now: () => number,
+  sleep: (ms: number) => void = sleepSync,
+): (name: string) => () => void {
+  const lastCall = new Map<string, number>();
+  return (name) => {
+    const limit = limits[name];
+    if (!limit) throw new Error(`Unknown rate limit '${name}'`);
+    const interval = 60_000 / limit.requestsPerMinute;
+    return () => {
+      const previous = lastCall.get(name);
+      const wait = previous === undefined ? 0 : previous + interval - now();
+      if (wait > 0) sleep(wait);
+      lastCall.set(name, now());
+    };
+  };
+}
+
+/** A file name safe for any enricher name, including plugin paths. */
+function cacheFile(cacheDir: string, name: string): string {
+  return join(cacheDir, `${CACHE_PREFIX}${encodeURIComponent(name)}.json`);
+}
+
+function parseReplyCache(text: string): ReplyCache | undefined {
+  const parsed: unknown = JSON.parse(text);
+  if (!parsed || typeof parsed !== 'object') return undefined;
+  const { entries, lastRun } = parsed as Partial<ReplyCache>;
+  if (!entries || typeof entries !== 'object') return undefined;
+  return { entries, ...(lastRun && { lastRun }) };
+}
+
+/** The cached replies for `name`, empty when missing or unreadable. */
+function readReplyCache(cacheDir: string, name: string): ReplyCache {
+  const file = cacheFile(cacheDir, name);
+  if (!existsSync(file)) return { entries: {} };
+  try {
+    return parseReplyCache(readFileSync(file, 'utf-8')) ?? { entries: {} };
+  } catch {
+    // a damaged cache only costs a refetch
+    return { entries: {} };
+  }
+}
+
+function writeReplyCache(
+  cacheDir: string,
+  name: string,
+  cache: ReplyCache,
+): void {
+  mkdirSync(cacheDir, { recursive: true });
+  writeFileSync(cacheFile(cacheDir, name), JSON.stringify(cache));
+}
+
+/**
+ * What a reply depends on: the entity's file contents, so an edit asks
+ * again even before the TTL runs out, and the caller's other `input`.
+ */
+function replyInput(entity: StoredEntity, input: string): string {
+  return `${entity.fileHash ?? ''}:${input}`;
+}
+
+/**
+ * What the enrichment caches in `cacheDir` hold, one entry per enricher
+ * that kept replies there, sorted by name. Files that cannot be read are
+ * listed as `damaged`; the next scan replaces them.
+ */
+export function readEnrichmentCacheStats(
+  cacheDir: string,
+  now: number = Date.now(),
+): readonly EnrichmentCacheStats[] {
+  if (!existsSync(cacheDir)) return [];
+  const stats: EnrichmentCacheStats[] = [];
+  for (const file of readdirSync(cacheDir).sort(compareStrings)) {
+    if (!file.startsWith(CACHE_PREFIX) || !file.endsWith('.json')) continue;
+    const name = decodeURIComponent(
+      file.slice(CACHE_PREFIX.length, -'.json'.length),
+    );
+    const path = join(cacheDir, file);
+    const bytes = statSync(path).size;
+    let cache: ReplyCache | undefined;
+    try {
+      cache = parseReplyCache(readFileSync(path, 'utf-8'));
+    } catch {
+      cache = undefined;
+    }
+    if (!cache) {
+      stats.push({ name, entries: 0, expired: 0, bytes, damaged: true });
+      continue;
+    }
+    const entries = Object.values(cache.entries);
+    stats.push({
+      name,
+      entries: entries.length,
+      expired: entries.filter((entry) => entry.expiresAt <= now).length,
+      bytes,
+      ...(cache.lastRun && { lastRun: cache.lastRun }),
+    });
+  }
+  return stats;
+}
+
+/**
+ * The `calls` helper for one step: entities go out in batches of
+ * `step.batchSize`, each call paced by `pace`, and replies newer than
+ * `step.cacheTtlMs` (or the enricher's default) are answered from the
+ * cache instead, as long as they were made from the same file contents
+ * and `input`. The cache is saved even when a call throws, so a scan
+ * stopped by an API error resumes where it left off.
+ */
+export function createEnrichmentCalls(
+  step: EnricherStep,
+  options: EnrichmentCallsOptions = {},
+): EnrichmentCalls & { readonly stats: () => EnrichmentCallStats } {
+  const { pace, cacheDir, clock = Date.now } = options;
+  const ttl =
+    cacheDir === undefined
+      ? undefined
+      : (step.cacheTtlMs ?? options.defaultCacheTtlMs);
+  let calls = 0;
+  let cached = 0;
+
+  function lookup<T>(
+    entities: readonly StoredEntity[],
+    fetch: (batch: readonly StoredEntity[]) => ReadonlyMap<string, T>,
+    lookupOptions: EnrichmentLookupOptions = {},
+  ): ReadonlyMap<string, T> {
+    const { input = '' } = lookupOptions;
+    const replies = new Map<string, T>();
+    const cache =
+      !ttl || cacheDir === undefined
+        ? undefined
+        : readReplyCache(cacheDir, step.name);
+    const start = clock();
+
+    const pending = entities.filter((entity) => {
+      const hit = cache?.entries[entity.id];
+      if (
+        !hit ||
+        hit.expiresAt <= start ||
+        hit.input !== replyInput(entity, input)
+      ) {
+        return true;
+      }
+      cached++;
+      if (hit.reply !== null) replies.set(entity.id, hit.reply as T);
+      return false;
+    });
+
+    const size = step.batchSize ?? Math.max(pending.length, 1);
+    try {
+      for (let offset = 0; offset < pending.length; offset += size) {
+        const batch = pending.slice(offset, offset + size);
+        pace?.();
+        calls++;
+        const answered = fetch(batch);
+        const expiresAt = clock() + (ttl ?? 0);
+        for (const entity of batch) {
+          const reply = answered.get(entity.id);
+          if (reply !== undefined) replies.set(entity.id, reply);
+          if (cache) {
+            cache.entries[entity.id] = {
+              expiresAt,
+              input: replyInput(entity, input),
+              reply: reply ?? null,
+            };
+          }
+        }
+      }
+    } finally {
+      if (cache && cacheDir !== undefined) {
+        const now = clock();
+        const live = Object.entries(cache.entries).filter(
+          ([, entry]) => entry.expiresAt > now,
+        );
+        writeReplyCache(cacheDir, step.name, {
+          entries: Object.fromEntries(live),
+          lastRun: { at: new Date(now).toISOString(), calls, cached },
+        });
+      }
+    }
+    return replies;
+  }
+
+  return { lookup, stats: () => ({ calls, cached }) };
+}
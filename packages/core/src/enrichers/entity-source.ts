@@ -0,0 +1,131 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads the lines of source each indexed entity spans, without comments, for enrichers that look inside the code
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, source, comments, spans]
+ * context:
+ *   business_goal: Let the call, route, and external API enrichers see only the code an entity is made of
+ *   domain: enrichers
+ */
+import { readFileSync } from 'node:fs';
+import { join } from 'node:path';
+import type { DatabaseManager } from '../indexer/database.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { ExtendedMetadata } from '../types/entity.js';
+
+// Entities that stand for their whole file rather than a stretch of it
+const FILE_ENTITY_TYPES = new Set(['module', 'service']);
+
+const HASH_COMMENT_LANGUAGES = new Set([
+  'python',
+  'ruby',
+  'shell',
+  'bash',
+  'perl',
+  'r',
+  'yaml',
+  'toml',
+]);
+
+/**
+ * `source` without comments, so annotations and prose that mention a name
+ * or URL are not read as code. Line breaks are kept. String contents are
+ * not tracked, so a `//` inside a string ends the line only when it follows
+ * whitespace, which URLs never do.
+ */
+export function stripComments(source: string, language: string): string {
+  const blank = (comment: string) => comment.replace(/[^\n]/g, ' ');
+  if (HASH_COMMENT_LANGUAGES.has(language)) {
+    return source
+      .replace(/"""[\s\S]*?"""|'''[\s\S]*?'''/g, blank)
+      .replace(/(^|\s)#.*$/gm, (_, space: string) => space);
+  }
+  return source
+    .replace(/\/\*[\s\S]*?\*\//g, blank)
+    .replace(/(^|\s)\/\/.*$/gm, (_, space: string) => space);
+}
+
+/**
+ * The code of each entity, keyed by id and without comments: a module or
+ * service has its whole file; anything else runs from its line to the line
+ * before the next entity in the file that is not one of its members.
+ * Entities whose file cannot be read get none.
+ */
+export function readEntitySources(
+  rootDir: string,
+  entities: readonly StoredEntity[],
+): ReadonlyMap<string, string> {
+  const byFile = new Map<string, StoredEntity[]>();
+  for (const entity of entities) {
+    const inFile = byFile.get(entity.filePath) ?? [];
+    inFile.push(entity);
+    byFile.set(entity.filePath, inFile);
+  }
+  const sources = new Map<string, string>();
+  for (const [filePath, inFile] of byFile) {
+    let text: string;
+    try {
+      text = readFileSync(join(rootDir, filePath), 'utf-8');
+    } catch {
+      continue;
+    }
+    const lines = stripComments(text, inFile[0].language).split('\n');
+    const ordered = [...inFile].sort((a, b) => a.line - b.line);
+    for (const [i, entity] of ordered.entries()) {
+      if (FILE_ENTITY_TYPES.has(entity.entityType)) {
+        sources.set(entity.id, lines.join('\n'));
+        continue;
+      }
+      const next = ordered
+        .slice(i + 1)
+        .find(
+          (other) => other.line > entity.line && other.parent !== entity.name,
+        );
+      const end = next ? next.line - 1 : lines.length;
+      sources.set(entity.id, lines.slice(entity.line - 1, end).join('\n'));
+    }
+  }
+  return sources;
+}
+
+/**
+ * Set the annotation field `key` of `entity` to `value`, or remove it when
+ * `value` is undefined, and say whether that changed anything.
+ */
+export function updateDerivedField<K extends keyof ExtendedMetadata>(
+  dbManager: DatabaseManager,
+  entity: StoredEntity,
+  key: K,
+  value: ExtendedMetadata[K] | undefined,
+): boolean {
+  const metadata = entity.metadata as ExtendedMetadata;
+  if (JSON.stringify(metadata[key]) === JSON.stringify(value)) return false;
+  const updated: ExtendedMetadata = { ...metadata, [key]: value };
+  if (value === undefined) delete updated[key];
+  dbManager.updateEntity(entity.id, { metadata: updated });
+  return true;
+}
+
+/**
+ * `path:name` for each entity, or `path:line` where another entity in the
+ * file has the same name, as `nodeReference` writes them, keyed by id.
+ */
+export function entityReferences(
+  entities: readonly StoredEntity[],
+): ReadonlyMap<string, string> {
+  const counts = new Map<string, number>();
+  const key = (entity: StoredEntity) => `${entity.filePath}\0${entity.name}`;
+  for (const entity of entities) {
+    counts.set(key(entity), (counts.get(key(entity)) ?? 0) + 1);
+  }
+  return new Map(
+    entities.map((entity) => [
+      entity.id,
+      `${entity.filePath}:${
+        (counts.get(key(entity)) ?? 0) > 1 ? entity.line : entity.name
+      }`,
+    ]),
+  );
+}
@@ -0,0 +1,171 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Enricher that joins the URLs in each entity's code to the indexed entity serving them or the external API they belong to
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, external-apis, http, join, source]
+ * context:
+ *   business_goal: Surface third-party and service-to-service calls that no annotation declares
+ *   domain: enrichers
+ */
+import { looseName } from '../graph/graph-builder.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { createQueryEngine } from '../query/query-engine.js';
+import type { ExtendedMetadata, HttpCall } from '../types/entity.js';
+import {
+  entityReferences,
+  readEntitySources,
+  updateDerivedField,
+} from './entity-source.js';
+import { routePathMatcher } from './route-enricher.js';
+import type { Enricher } from './types.js';
+
+const URL_PATTERN = /\bhttps?:\/\/[^\s'"`<>(){}\\]+/g;
+
+// Hosts that name a document format or an example rather than a service
+const IGNORED_HOSTS = new Set([
+  'example.com',
+  'example.org',
+  'example.net',
+  'schema.org',
+  'json-schema.org',
+  'w3.org',
+  'xmlsoap.org',
+]);
+
+// Second-level labels under which the third-level one is the organization
+const SHARED_SECOND_LEVEL = new Set(['co', 'com', 'org', 'net', 'gov', 'ac']);
+
+const INTERNAL_SUFFIXES = ['.local', '.internal', '.svc', '.localhost'];
+
+interface RouteServer {
+  readonly entity: StoredEntity;
+  readonly serves: (path: string) => boolean;
+}
+
+/** The URLs in `source`, one per host and path, in order. */
+export function findUrls(source: string): readonly URL[] {
+  const seen = new Set<string>();
+  const urls: URL[] = [];
+  for (const [text] of source.matchAll(URL_PATTERN)) {
+    let url: URL;
+    try {
+      url = new URL(text.replace(/[.,;:$]+$/, ''));
+    } catch {
+      continue;
+    }
+    const key = `${url.hostname}${url.pathname}`;
+    if (seen.has(key)) continue;
+    seen.add(key);
+    urls.push(url);
+  }
+  return urls;
+}
+
+/**
+ * Whether `host` is reached inside the deployment rather than on the
+ * internet: a bare name such as `localhost`, an IP address, or a cluster
+ * or private domain.
+ */
+export function isInternalHost(host: string): boolean {
+  return (
+    !host.includes('.') ||
+    /^[\d.]+$/.test(host) ||
+    host.startsWith('[') ||
+    INTERNAL_SUFFIXES.some((suffix) => host.endsWith(suffix))
+  );
+}
+
+/**
+ * The organization a public host belongs to, such as `stripe` for
+ * `api.stripe.com` or `bbc` for `www.bbc.co.uk`.
+ */
+export function apiName(host: string): string {
+  const labels = host.split('.');
+  const second = labels.at(-2) ?? host;
+  return SHARED_SECOND_LEVEL.has(second) && labels.length > 2
+    ? labels[labels.length - 3]
+    : second;
+}
+
+function isIgnoredHost(host: string): boolean {
+  const labels = host.split('.');
+  return labels.some((_, i) => IGNORED_HOSTS.has(labels.slice(i).join('.')));
+}
+
+/**
+ * Set `http_calls` to the URLs in each entity's code, joined to what they
+ * reach:
+ *
+ * 1. A public host joins the external API named by its organization, under
+ *    the name an entity's `dependencies.external_apis` gives it when one
+ *    matches, ignoring case, hyphens, and underscores
+ * 2. An internal host joins the indexed entity whose `routes` serve the
+ *    URL's path, or else the entity named like the host's first label
+ * 3. Anything else is recorded with no join
+ *
+ * Run `routes` first so there are routes to join to. Entities with no URLs
+ * lose any `http_calls` they had.
+ */
+export function createExternalApiEnricher(): Enricher {
+  return {
+    name: 'external-apis',
+    description: 'URLs joined to the external APIs and services they call',
+    enrich({ rootDir, dbManager, entities }) {
+      if (entities.length === 0) return 0;
+      const all = createQueryEngine(dbManager).getAll();
+      const references = entityReferences(all);
+      const declaredApis = new Map<string, string>();
+      const servers: RouteServer[] = [];
+      const byLooseName = new Map<string, StoredEntity>();
+      for (const entity of all) {
+        const metadata = entity.metadata as ExtendedMetadata;
+        for (const entry of metadata.dependencies?.external_apis ?? []) {
+          const name = typeof entry === 'string' ? entry : entry.name;
+          declaredApis.set(looseName(name), name);
+        }
+        for (const route of metadata.routes ?? []) {
+          servers.push({ entity, serves: routePathMatcher(route) });
+        }
+        if (!byLooseName.has(looseName(entity.name))) {
+          byLooseName.set(looseName(entity.name), entity);
+        }
+      }
+
+      const join = (caller: StoredEntity, url: URL): HttpCall => {
+        const host = url.hostname;
+        const call = { url: `${host}${url.pathname}` };
+        if (!isInternalHost(host)) {
+          const name = apiName(host);
+          return { ...call, api: declaredApis.get(looseName(name)) ?? name };
+        }
+        const server =
+          servers.find(
+            ({ entity, serves }) =>
+              entity.id !== caller.id && serves(url.pathname),
+          )?.entity ?? byLooseName.get(looseName(host.split('.')[0]));
+        const service =
+          server && server.id !== caller.id
+            ? references.get(server.id)
+            : undefined;
+        return service ? { ...call, service } : call;
+      };
+
+      const sources = readEntitySources(rootDir, entities);
+      let updated = 0;
+      for (const entity of entities) {
+        const source = sources.get(entity.id);
+        if (source === undefined) continue;
+        const calls = findUrls(source)
+          .filter((url) => !isIgnoredHost(url.hostname))
+          .map((url) => join(entity, url));
+        const value = calls.length > 0 ? calls : undefined;
+        if (updateDerivedField(dbManager, entity, 'http_calls', value)) {
+          updated += 1;
+        }
+      }
+      return updated;
+    },
+  };
+}
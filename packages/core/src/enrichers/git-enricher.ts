@@ -0,0 +1,267 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Enricher that records each entity's last commit, top contributors, and trailer-linked commits from git log
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, git, history, ownership]
+ * context:
+ *   business_goal: Show who last changed each part of the codebase and when, without extra annotations
+ *   domain: enrichers
+ */
+import { spawnSync } from 'node:child_process';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  ExtendedMetadata,
+  GitContributor,
+  GitLinkedCommit,
+  GitMetadata,
+} from '../types/entity.js';
+import { NODE_TRAILER, resolveNodeReference } from './commit-trailers.js';
+import type {
+  Enricher,
+  GitCommit,
+  GitEnricherOptions,
+  GitFileCommit,
+  GitLogRunner,
+} from './types.js';
+
+const MAX_BUFFER_BYTES = 256 * 1024 * 1024;
+const RECORD = '\x1e';
+const FIELD = '\x1f';
+const VALUE = '\x1d';
+
+/** A week: the git enricher's replies are also keyed by HEAD. */
+const GIT_CACHE_TTL_MS = 7 * 24 * 60 * 60 * 1000;
+
+/**
+ * Create a runner that shells out to `git log`. Only paths under `rootDir`
+ * are listed, relative to it.
+ */
+export function createGitLogRunner(gitPath = 'git'): GitLogRunner {
+  function git(
+    rootDir: string,
+    command: string,
+    args: readonly string[],
+  ): string {
+    const run = spawnSync(
+      gitPath,
+      ['-c', 'core.quotePath=false', command, ...args],
+      { cwd: rootDir, encoding: 'utf-8', maxBuffer: MAX_BUFFER_BYTES },
+    );
+    if (run.error) {
+      throw new Error(`git ${command} failed: ${run.error.message}`);
+    }
+    if (run.status !== 0) {
+      const detail = run.stderr.trim().split('\n').pop() ?? '';
+      throw new Error(
+        `git ${command} failed: ${detail || `exit ${run.status}`}`,
+      );
+    }
+    return run.stdout;
+  }
+
+  return {
+    log(rootDir: string): string {
+      return git(rootDir, 'log', [
+        '--name-only',
+        '--relative',
+        `--format=${RECORD}%H${FIELD}%aE${FIELD}%aI${FIELD}%s${FIELD}%(trailers:key=${NODE_TRAILER},valueonly,separator=%x1d)`,
+      ]);
+    },
+    head(rootDir: string): string {
+      return git(rootDir, 'rev-parse', ['HEAD']).trim();
+    },
+  };
+}
+
+/**
+ * Parse `git log` output from `createGitLogRunner` into its commits, newest
+ * first. Output without the subject and trailer fields reads as commits
+ * with an empty subject and no linked nodes.
+ */
+export function parseGitCommits(output: string): readonly GitCommit[] {
+  const commits: GitCommit[] = [];
+  for (const record of output.split(RECORD)) {
+    const [header = '', ...paths] = record.split('\n');
+    const [commit, author, date, subject = '', nodes = ''] =
+      header.split(FIELD);
+    if (!commit || author === undefined || date === undefined) continue;
+    commits.push({
+      commit,
+      author,
+      date,
+      subject,
+      nodes: nodes
+        .split(VALUE)
+        .map((node) => node.trim())
+        .filter(Boolean),
+      paths: paths.filter(Boolean),
+    });
+  }
+  return commits;
+}
+
+function groupByPath(
+  commits: readonly GitCommit[],
+): ReadonlyMap<string, readonly GitFileCommit[]> {
+  const history = new Map<string, GitFileCommit[]>();
+  for (const { commit, author, date, paths } of commits) {
+    for (const path of paths) {
+      const commits = history.get(path) ?? [];
+      commits.push({ commit, author, date });
+      history.set(path, commits);
+    }
+  }
+  return history;
+}
+
+/**
+ * Parse `git log` output from `createGitLogRunner` into every commit that
+ * touched each file, newest first.
+ */
+export function parseGitHistory(
+  output: string,
+): ReadonlyMap<string, readonly GitFileCommit[]> {
+  return groupByPath(parseGitCommits(output));
+}
+
+/**
+ * Parse `git log` output from `createGitLogRunner` into the newest commit
+ * for each file.
+ */
+export function parseGitLog(
+  output: string,
+): ReadonlyMap<string, GitFileCommit> {
+  const latest = new Map<string, GitFileCommit>();
+  for (const [path, commits] of parseGitHistory(output)) {
+    latest.set(path, commits[0]);
+  }
+  return latest;
+}
+
+/**
+ * The authors of `commits` (newest first), most commits first. Ties go to
+ * the author who committed most recently.
+ */
+export function topContributors(
+  commits: readonly GitFileCommit[],
+  limit = 3,
+): readonly GitContributor[] {
+  const byAuthor = new Map<string, { commits: number; last: string }>();
+  for (const { author, date } of commits) {
+    const seen = byAuthor.get(author);
+    if (seen) seen.commits += 1;
+    else byAuthor.set(author, { commits: 1, last: date });
+  }
+  // Map order is first appearance, which is most recent commit first.
+  return [...byAuthor]
+    .map(([author, { commits: count, last }]) => ({
+      author,
+      commits: count,
+      last_modified: last,
+    }))
+    .sort((a, b) => b.commits - a.commits)
+    .slice(0, limit);
+}
+
+/**
+ * The commits whose `Knowgraph-Node` trailers name each entity, newest
+ * first and at most `limit` per entity, keyed by entity id.
+ */
+function linkCommits(
+  commits: readonly GitCommit[],
+  entities: readonly StoredEntity[],
+  limit: number,
+): ReadonlyMap<string, readonly GitLinkedCommit[]> {
+  const linked = new Map<string, GitLinkedCommit[]>();
+  for (const { commit, author, date, subject, nodes } of commits) {
+    const targets = new Set(
+      nodes.map((ref) => resolveNodeReference(entities, ref)?.id),
+    );
+    for (const id of targets) {
+      if (!id) continue;
+      const list = linked.get(id) ?? [];
+      if (list.length < limit) list.push({ commit, author, date, subject });
+      linked.set(id, list);
+    }
+  }
+  return linked;
+}
+
+/**
+ * Set `git` metadata from the commits that touched each entity's file: the
+ * last commit, the top authors of its recent commits, and the commits whose
+ * `Knowgraph-Node` trailers name the entity. Entities whose file has no
+ * history (untracked or outside the repository) are left alone. With a
+ * runner that reports HEAD, metadata is cached by commit, so a scan at the
+ * same commit with no changed files does not read the log at all.
+ */
+export function createGitEnricher(
+  runner: GitLogRunner = createGitLogRunner(),
+  options: GitEnricherOptions = {},
+): Enricher {
+  const {
+    maxContributors = 3,
+    recentCommits = 100,
+    maxLinkedCommits = 10,
+  } = options;
+  return {
+    name: 'git',
+    description: 'Last commit, top contributors, and linked commits',
+    cacheTtlMs: GIT_CACHE_TTL_MS,
+    enrich({ rootDir, dbManager, entities, calls }) {
+      if (entities.length === 0) return 0;
+      let parsed:
+        | {
+            history: ReadonlyMap<string, readonly GitFileCommit[]>;
+            linked: ReadonlyMap<string, readonly GitLinkedCommit[]>;
+          }
+        | undefined;
+      // Reads the log once, however many batches ask
+      const describe = (batch: readonly StoredEntity[]) => {
+        if (!parsed) {
+          const log = parseGitCommits(runner.log(rootDir));
+          parsed = {
+            history: groupByPath(log),
+            linked: linkCommits(log, entities, maxLinkedCommits),
+          };
+        }
+        const described = new Map<string, GitMetadata>();
+        for (const entity of batch) {
+          const commits = parsed.history.get(entity.filePath);
+          if (!commits) continue;
+          const [latest] = commits;
+          const recent = commits.slice(0, recentCommits);
+          const linkedCommits = parsed.linked.get(entity.id);
+          described.set(entity.id, {
+            last_commit: latest.commit,
+            last_author: latest.author,
+            last_modified: latest.date,
+            contributors: [...topContributors(recent, maxContributors)],
+            recent_commits: recent.length,
+            ...(linkedCommits && { linked_commits: [...linkedCommits] }),
+          });
+        }
+        return described;
+      };
+      const head = calls && runner.head?.(rootDir);
+      const input = `${head}:${maxContributors}:${recentCommits}:${maxLinkedCommits}`;
+      const described =
+        !calls || head === undefined
+          ? describe(entities)
+          : calls.lookup(entities, describe, { input });
+
+      let updated = 0;
+      for (const entity of entities) {
+        const git = described.get(entity.id);
+        if (!git) continue;
+        const metadata = entity.metadata as ExtendedMetadata;
+        if (JSON.stringify(metadata.git) === JSON.stringify(git)) continue;
+        dbManager.updateEntity(entity.id, { metadata: { ...metadata, git } });
+        updated += 1;
+      }
+      return updated;
+    },
+  };
+}
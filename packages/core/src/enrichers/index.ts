@@ -0,0 +1,61 @@
+export type {
+  Enricher,
+  EnricherContext,
+  EnricherStep,
+  PlannedEnricher,
+  EnricherStatus,
+  EnricherRun,
+  EnricherPipelineOptions,
+  EnrichmentCalls,
+  EnrichmentCallsOptions,
+  EnrichmentCallStats,
+  EnrichmentCacheRun,
+  EnrichmentCacheStats,
+  EnrichmentLookupOptions,
+  EnrichmentRateLimit,
+  GitFileCommit,
+  GitCommit,
+  GitEnricherOptions,
+  GitLogRunner,
+} from './types.js';
+export { planEnrichers, runEnrichers } from './pipeline.js';
+export {
+  sleepSync,
+  createRateLimiters,
+  createEnrichmentCalls,
+  readEnrichmentCacheStats,
+} from './enrichment-calls.js';
+export {
+  createGitLogRunner,
+  parseGitCommits,
+  parseGitLog,
+  parseGitHistory,
+  topContributors,
+  createGitEnricher,
+} from './git-enricher.js';
+export {
+  stripComments,
+  readEntitySources,
+  updateDerivedField,
+  entityReferences,
+} from './entity-source.js';
+export { findCallSites, createCallEnricher } from './call-enricher.js';
+export {
+  normalizeRoutePath,
+  detectRoutes,
+  routePathMatcher,
+  createRouteEnricher,
+} from './route-enricher.js';
+export {
+  findUrls,
+  isInternalHost,
+  apiName,
+  createExternalApiEnricher,
+} from './external-api-enricher.js';
+export {
+  NODE_TRAILER,
+  parseNodeTrailers,
+  resolveNodeReference,
+  nodeReference,
+  suggestNodeTrailers,
+} from './commit-trailers.js';
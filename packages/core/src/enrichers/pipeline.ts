@@ -0,0 +1,146 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Plans and runs enrichers in configured order with per-step conditions and timings
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, pipeline, ordering, timing]
+ * context:
+ *   business_goal: Add derived facts to the graph in a configured order instead of hardcoded steps
+ *   domain: enrichers
+ */
+import { performance } from 'node:perf_hooks';
+import ignore from 'ignore';
+import { createQueryEngine } from '../query/query-engine.js';
+import { timePhase } from '../profiling/phase-timer.js';
+import type { Telemetry } from '../telemetry/types.js';
+import {
+  createEnrichmentCalls,
+  createRateLimiters,
+  sleepSync,
+} from './enrichment-calls.js';
+import type {
+  Enricher,
+  EnricherPipelineOptions,
+  EnricherRun,
+  EnricherStep,
+  EnrichmentRateLimit,
+  PlannedEnricher,
+} from './types.js';
+
+/**
+ * Match `steps` to `available` enrichers, keeping the order of `steps`.
+ * Throws on an unknown or repeated name, or a rate limit missing from
+ * `rateLimits`, so a typo in the manifest fails before indexing starts
+ * rather than after.
+ */
+export function planEnrichers(
+  available: readonly Enricher[],
+  steps: readonly EnricherStep[],
+  rateLimits: Readonly<Record<string, EnrichmentRateLimit>> = {},
+): readonly PlannedEnricher[] {
+  const seen = new Set<string>();
+  return steps.map((step) => {
+    const enricher = available.find((e) => e.name === step.name);
+    if (!enricher) {
+      const names = available.map((e) => e.name).join(', ') || 'none';
+      throw new Error(`Unknown enricher '${step.name}'. Available: ${names}`);
+    }
+    if (seen.has(step.name)) {
+      throw new Error(`Enricher '${step.name}' is listed more than once`);
+    }
+    if (step.rateLimit !== undefined && !(step.rateLimit in rateLimits)) {
+      const names = Object.keys(rateLimits).join(', ') || 'none';
+      throw new Error(
+        `Enricher '${step.name}' uses unknown rate limit '${step.rateLimit}'. Available: ${names}`,
+      );
+    }
+    seen.add(step.name);
+    return { enricher, step };
+  });
+}
+
+/** Run `step` in a span, recording its time and updates as metrics. */
+function traceStep(
+  telemetry: Telemetry,
+  name: string,
+  step: () => EnricherRun,
+): EnricherRun {
+  const attributes = { 'knowgraph.enricher': name };
+  return telemetry.trace('knowgraph.enricher', { attributes }, (span) => {
+    const run = step();
+    const labels = { ...attributes, 'knowgraph.enricher.status': run.status };
+    span.setAttributes({
+      ...labels,
+      'knowgraph.enricher.updated': run.updated,
+    });
+    telemetry.record('knowgraph.enricher.duration', run.ms, 'ms', labels);
+    telemetry.count('knowgraph.enricher.updated', run.updated, labels);
+    if (run.error !== undefined) span.end(new Error(run.error));
+    return run;
+  });
+}
+
+/**
+ * Run each planned step against the current index, in order, so a step
+ * sees what earlier steps wrote. Each step commits as one transaction; a
+ * failing step's writes are rolled back, and it is recorded while the rest
+ * still run. Steps naming the same rate limit share it for the whole run.
+ */
+export function runEnrichers(
+  plan: readonly PlannedEnricher[],
+  options: EnricherPipelineOptions,
+): readonly EnricherRun[] {
+  const { rootDir, dbManager, profiler } = options;
+  const now = options.now ?? (() => performance.now());
+  const query = createQueryEngine(dbManager);
+  const limiter = createRateLimiters(
+    options.rateLimits ?? {},
+    now,
+    options.sleep ?? sleepSync,
+  );
+
+  return plan.map(({ enricher, step }): EnricherRun => {
+    const name = enricher.name;
+    if (step.enabled === false) {
+      return { name, status: 'skipped', updated: 0, ms: 0 };
+    }
+
+    const calls = createEnrichmentCalls(step, {
+      pace: step.rateLimit === undefined ? undefined : limiter(step.rateLimit),
+      cacheDir: options.cacheDir,
+      defaultCacheTtlMs: enricher.cacheTtlMs,
+    });
+    // Only steps that looked anything up report call counts.
+    const stats = (): Pick<EnricherRun, 'calls'> => {
+      const counts = calls.stats();
+      return counts.calls + counts.cached > 0 ? { calls: counts } : {};
+    };
+    const run = (): EnricherRun => {
+      const start = now();
+      try {
+        const updated = timePhase(profiler, 'enrich', () => {
+          const matcher = step.paths?.length ? ignore().add(step.paths) : null;
+          const entities = query
+            .getAll()
+            .filter((entity) => !matcher || matcher.ignores(entity.filePath));
+          // Readers see all of a step's updates or, if it fails, none
+          return dbManager.db.transaction(() =>
+            enricher.enrich({ rootDir, dbManager, entities, calls }),
+          )();
+        });
+        return { name, status: 'ok', updated, ms: now() - start, ...stats() };
+      } catch (err) {
+        return {
+          name,
+          status: 'failed',
+          updated: 0,
+          ms: now() - start,
+          error: err instanceof Error ? err.message : String(err),
+          ...stats(),
+        };
+      }
+    };
+    return options.telemetry ? traceStep(options.telemetry, name, run) : run();
+  });
+}
@@ -0,0 +1,140 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Enricher that records the HTTP routes each entity registers, from Express-style, Flask, net/http, and Spring route declarations
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, routes, http, api, source]
+ * context:
+ *   business_goal: Show which code serves each endpoint so callers of an endpoint can be traced to it
+ *   domain: enrichers
+ */
+import { readEntitySources, updateDerivedField } from './entity-source.js';
+import type { Enricher } from './types.js';
+
+const HTTP_METHODS = [
+  'get',
+  'post',
+  'put',
+  'patch',
+  'delete',
+  'head',
+  'options',
+];
+
+interface RoutePattern {
+  readonly pattern: RegExp;
+  /** The methods and path of a match. */
+  readonly read: (match: RegExpMatchArray) => {
+    readonly methods: readonly string[];
+    readonly path: string;
+  };
+}
+
+const ROUTE_PATTERNS: readonly RoutePattern[] = [
+  // app.get('/users', ...), router.post(...), @app.get('/users') in
+  // FastAPI, r.GET("/users", ...) in gin and echo
+  {
+    pattern: new RegExp(
+      `\\.(${HTTP_METHODS.join('|')}|all)\\(\\s*(['"\`])(/[^'"\`]*)\\2`,
+      'gi',
+    ),
+    read: (m) => ({
+      methods: [m[1].toLowerCase() === 'all' ? 'ANY' : m[1]],
+      path: m[3],
+    }),
+  },
+  // @app.route('/users', methods=['GET', 'POST']) in Flask
+  {
+    pattern:
+      /\.route\(\s*(['"])(\/[^'"]*)\1(?:[^)]*?methods\s*=\s*[[(]([^\])]*)[\])])?/g,
+    read: (m) => ({
+      methods: m[3]
+        ? [...m[3].matchAll(/['"](\w+)['"]/g)].map((method) => method[1])
+        : ['GET'],
+      path: m[2],
+    }),
+  },
+  // http.HandleFunc("/users", ...) and mux.Handle("GET /users/{id}", ...)
+  {
+    pattern: /\.Handle(?:Func)?\(\s*"(?:([A-Z]+)\s+)?(\/[^"]*)"/g,
+    read: (m) => ({ methods: [m[1] ?? 'ANY'], path: m[2] }),
+  },
+  // @GetMapping("/users") and @RequestMapping(path = "/users") in Spring
+  {
+    pattern:
+      /@(Get|Post|Put|Patch|Delete|Request)Mapping\(\s*(?:(?:value|path)\s*=\s*)?\{?\s*"(\/[^"]*)"/g,
+    read: (m) => ({
+      methods: [m[1] === 'Request' ? 'ANY' : m[1]],
+      path: m[2],
+    }),
+  },
+];
+
+/**
+ * `path` with its parameters written as `{name}`, as OpenAPI does, whether
+ * they were `:name`, `<name>`, or `<int:name>`, and without a trailing `/`.
+ */
+export function normalizeRoutePath(path: string): string {
+  const normalized = path
+    .replace(/<(?:\w+:)?(\w+)>/g, '{$1}')
+    .replace(/:([A-Za-z_]\w*)/g, '{$1}');
+  return normalized.length > 1 ? normalized.replace(/\/+$/, '') : normalized;
+}
+
+/**
+ * The routes `source` registers as `METHOD /path`, each once.
+ * A route registered without a method, or for every method, is `ANY`.
+ */
+export function detectRoutes(source: string): readonly string[] {
+  const routes = new Set<string>();
+  for (const { pattern, read } of ROUTE_PATTERNS) {
+    for (const match of source.matchAll(pattern)) {
+      const { methods, path } = read(match);
+      for (const method of methods) {
+        routes.add(`${method.toUpperCase()} ${normalizeRoutePath(path)}`);
+      }
+    }
+  }
+  return [...routes];
+}
+
+/**
+ * A matcher for the URL paths a `METHOD /path` route serves, with each
+ * `{name}` standing for one path segment.
+ */
+export function routePathMatcher(route: string): (path: string) => boolean {
+  const path = route.slice(route.indexOf(' ') + 1);
+  const pattern = new RegExp(
+    `^${path
+      .split(/\{[^}]+\}/)
+      .map((part) => part.replace(/[.*+?^${}()|[\]\\]/g, '\\$&'))
+      .join('[^/]+')}/?$`,
+  );
+  return (candidate) => pattern.test(candidate);
+}
+
+/**
+ * Set `routes` to the HTTP routes each entity's code registers. Entities
+ * that register none lose any `routes` they had.
+ */
+export function createRouteEnricher(): Enricher {
+  return {
+    name: 'routes',
+    description: 'HTTP routes each entity registers',
+    enrich({ rootDir, dbManager, entities }) {
+      const sources = readEntitySources(rootDir, entities);
+      let updated = 0;
+      for (const entity of entities) {
+        const source = sources.get(entity.id);
+        if (source === undefined) continue;
+        const routes = detectRoutes(source);
+        const value = routes.length > 0 ? [...routes] : undefined;
+        if (updateDerivedField(dbManager, entity, 'routes', value)) {
+          updated += 1;
+        }
+      }
+      return updated;
+    },
+  };
+}
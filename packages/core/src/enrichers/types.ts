@@ -0,0 +1,199 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the enricher pipeline that adds derived metadata to indexed entities
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [enrichers, pipeline, indexer, types, interface]
+ * context:
+ *   business_goal: Let plugins add enrichers that run alongside the built-in ones
+ *   domain: enrichers
+ */
+import type { DatabaseManager } from '../indexer/database.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { PhaseTimer } from '../profiling/types.js';
+import type { Telemetry } from '../telemetry/types.js';
+
+export interface EnricherContext {
+  readonly rootDir: string;
+  readonly dbManager: DatabaseManager;
+  /** Indexed entities the step applies to, after its `paths` filter. */
+  readonly entities: readonly StoredEntity[];
+  /**
+   * Batching, rate limiting, and caching for enrichers that call an
+   * external service, set up from the step's options. The pipeline always
+   * passes one; enrichers called directly may get none.
+   */
+  readonly calls?: EnrichmentCalls;
+}
+
+/** Calls to an external service on behalf of one pipeline step. */
+export interface EnrichmentCalls {
+  /**
+   * Answer `entities` through `fetch`, which is called once per batch and
+   * returns replies keyed by entity id; entities it leaves out get none.
+   * Calls are paced by the step's rate limit and fresh cached replies are
+   * used instead of calling, unless the entity's file or `input` changed.
+   */
+  lookup<T>(
+    entities: readonly StoredEntity[],
+    fetch: (batch: readonly StoredEntity[]) => ReadonlyMap<string, T>,
+    options?: EnrichmentLookupOptions,
+  ): ReadonlyMap<string, T>;
+}
+
+export interface EnrichmentLookupOptions {
+  /**
+   * What else the replies depend on besides each entity's file, such as
+   * the repository HEAD or the enricher's options; cached replies made
+   * from a different `input` are asked for again.
+   */
+  readonly input?: string;
+}
+
+/** A budget shared by every step that names it. */
+export interface EnrichmentRateLimit {
+  readonly requestsPerMinute: number;
+}
+
+export interface EnrichmentCallsOptions {
+  /** Waits for the step's rate limit before each call, if it has one. */
+  readonly pace?: () => void;
+  /** Where replies are kept between scans; without it nothing is cached. */
+  readonly cacheDir?: string;
+  /** Wall-clock milliseconds, for cache expiry. */
+  readonly clock?: () => number;
+  /** How long replies are reused when the step sets no `cacheTtlMs`. */
+  readonly defaultCacheTtlMs?: number;
+}
+
+export interface EnrichmentCallStats {
+  /** Calls made to the service. */
+  readonly calls: number;
+  /** Entities answered from the cache. */
+  readonly cached: number;
+}
+
+/** The counts of the last scan that used an enrichment cache. */
+export interface EnrichmentCacheRun {
+  /** ISO timestamp of the scan. */
+  readonly at: string;
+  readonly calls: number;
+  readonly cached: number;
+}
+
+/** What one enricher's cache file holds, as `knowgraph doctor` shows it. */
+export interface EnrichmentCacheStats {
+  readonly name: string;
+  readonly entries: number;
+  /** Entries past their TTL, dropped on the enricher's next run. */
+  readonly expired: number;
+  readonly bytes: number;
+  readonly lastRun?: EnrichmentCacheRun;
+  /** Set when the file could not be read; the next scan replaces it. */
+  readonly damaged?: boolean;
+}
+
+export interface Enricher {
+  readonly name: string;
+  readonly description: string;
+  /**
+   * How long replies looked up through `calls` are reused when the step
+   * sets no `cacheTtlMs`; none by default.
+   */
+  readonly cacheTtlMs?: number;
+  /** Update entities in the index and return how many changed. */
+  enrich(context: EnricherContext): number;
+}
+
+/** One configured pipeline step, as in the manifest's `enrichers` list. */
+export interface EnricherStep {
+  readonly name: string;
+  /** Whether the step runs. Default true. */
+  readonly enabled?: boolean;
+  /** Gitignore-style patterns; the step sees only entities in these files. */
+  readonly paths?: readonly string[];
+  /** Most entities sent per call; all of them in one call by default. */
+  readonly batchSize?: number;
+  /** The `rateLimits` entry that paces the step's calls. */
+  readonly rateLimit?: string;
+  /**
+   * How long replies are reused, in milliseconds; the enricher's own
+   * `cacheTtlMs` by default, and 0 turns caching off.
+   */
+  readonly cacheTtlMs?: number;
+}
+
+export interface PlannedEnricher {
+  readonly enricher: Enricher;
+  readonly step: EnricherStep;
+}
+
+export type EnricherStatus = 'ok' | 'skipped' | 'failed';
+
+export interface EnricherRun {
+  readonly name: string;
+  readonly status: EnricherStatus;
+  readonly updated: number;
+  /** Wall-clock milliseconds, 0 for skipped steps. */
+  readonly ms: number;
+  readonly error?: string;
+  /** Set when the step looked entities up through `calls`. */
+  readonly calls?: EnrichmentCallStats;
+}
+
+export interface EnricherPipelineOptions {
+  readonly rootDir: string;
+  readonly dbManager: DatabaseManager;
+  /** Records every step under the `enrich` phase. */
+  readonly profiler?: PhaseTimer;
+  /**
+   * Traces each step as a `knowgraph.enricher` span and records its time
+   * and updates as metrics.
+   */
+  readonly telemetry?: Telemetry;
+  /** Times steps and paces rate-limited calls. */
+  readonly now?: () => number;
+  /** Named limits that steps refer to by `rateLimit`. */
+  readonly rateLimits?: Readonly<Record<string, EnrichmentRateLimit>>;
+  /** Where steps with a cache TTL keep replies between scans. */
+  readonly cacheDir?: string;
+  /** Waits out rate limits; tests pass one that does not. */
+  readonly sleep?: (ms: number) => void;
+}
+
+export interface GitFileCommit {
+  readonly commit: string;
+  readonly author: string;
+  readonly date: string;
+}
+
+/** One commit from `git log`, with the files it touched. */
+export interface GitCommit extends GitFileCommit {
+  readonly subject: string;
+  /** Values of the commit's `Knowgraph-Node` trailers. */
+  readonly nodes: readonly string[];
+  readonly paths: readonly string[];
+}
+
+export interface GitEnricherOptions {
+  /** Contributors kept per file, most commits first. Default 3. */
+  readonly maxContributors?: number;
+  /** How many of a file's newest commits are counted. Default 100. */
+  readonly recentCommits?: number;
+  /** Trailer-linked commits kept per entity, newest first. Default 10. */
+  readonly maxLinkedCommits?: number;
+}
+
+export interface GitLogRunner {
+  /**
+   * Run `git log --name-only` in `rootDir` and return its output, with
+   * paths relative to `rootDir`.
+   */
+  log(rootDir: string): string;
+  /**
+   * The commit `rootDir` has checked out. Runners without it make the git
+   * enricher read the log on every scan instead of caching by commit.
+   */
+  head?(rootDir: string): string;
+}
@@ -0,0 +1,86 @@
+import { describe, it, expect } from 'vitest';
+import { z } from 'zod';
+import { parse as parseYaml } from 'yaml';
+import {
+  EXIT_CODES,
+  classifyError,
+  createKnowgraphError,
+  errorKindForExitCode,
+  isKnowgraphError,
+  toErrorEnvelope,
+} from '../errors.js';
+
+function thrown(fn: () => unknown): unknown {
+  try {
+    fn();
+  } catch (err) {
+    return err;
+  }
+  throw new Error('expected a throw');
+}
+
+describe('classifyError', () => {
+  it('keeps the kind of tagged errors', () => {
+    const err = createKnowgraphError('usage', 'Bad --limit');
+    expect(isKnowgraphError(err)).toBe(true);
+    expect(classifyError(err)).toBe('usage');
+  });
+
+  it('reads the kind from the error shape', () => {
+    expect(classifyError(thrown(() => z.string().parse(1)))).toBe('schema');
+    expect(classifyError(thrown(() => parseYaml('a: [')))).toBe('parse');
+    expect(classifyError(thrown(() => JSON.parse('{')))).toBe('parse');
+    expect(
+      classifyError(Object.assign(new Error('missing'), { code: 'ENOENT' })),
+    ).toBe('io');
+    expect(
+      classifyError(new DOMException('scan timed out', 'TimeoutError')),
+    ).toBe('timeout');
+  });
+
+  it('falls back to the cause, then to internal', () => {
+    const cause = Object.assign(new Error('refused'), {
+      code: 'ECONNREFUSED',
+    });
+    expect(classifyError(new TypeError('fetch failed', { cause }))).toBe('io');
+    expect(classifyError(new Error('bug'))).toBe('internal');
+    expect(classifyError('thrown string')).toBe('internal');
+  });
+});
+
+describe('toErrorEnvelope', () => {
+  it('carries the kind, exit code, message, and details', () => {
+    const err = createKnowgraphError('parse', 'Bad YAML', {
+      details: { file: 'a.ts', line: 3 },
+    });
+    expect(toErrorEnvelope(err)).toEqual({
+      error: {
+        kind: 'parse',
+        exitCode: EXIT_CODES.parse,
+        message: 'Bad YAML',
+        details: { file: 'a.ts', line: 3 },
+      },
+    });
+  });
+
+  it('uses the given kind over the classified one', () => {
+    expect(toErrorEnvelope(new Error('3 issues'), 'policy').error).toEqual({
+      kind: 'policy',
+      exitCode: 1,
+      message: '3 issues',
+    });
+  });
+
+  it('gives every kind its own exit code', () => {
+    const codes = Object.values(EXIT_CODES);
+    expect(new Set(codes).size).toBe(codes.length);
+    expect(codes).not.toContain(0);
+  });
+
+  it('maps exit codes back to their kind', () => {
+    expect(errorKindForExitCode(0)).toBeUndefined();
+    expect(errorKindForExitCode(EXIT_CODES.io)).toBe('io');
+    expect(errorKindForExitCode(1)).toBe('policy');
+    expect(errorKindForExitCode(42)).toBe('internal');
+  });
+});
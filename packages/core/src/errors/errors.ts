@@ -0,0 +1,108 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Classifies errors into the taxonomy and maps each kind to a distinct exit code
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [errors, exit-codes, taxonomy, classification]
+ * context:
+ *   business_goal: Let CI scripts and bots branch on why a command failed, not just that it did
+ *   domain: errors
+ */
+import { isCancellationError } from '../cancellation/cancellation.js';
+import type {
+  ErrorEnvelope,
+  ErrorKind,
+  KnowgraphError,
+  KnowgraphErrorOptions,
+} from './types.js';
+
+/**
+ * Exit code per kind. `policy` keeps 1, the code failing checks have
+ * always exited with, so existing CI gates behave the same.
+ */
+export const EXIT_CODES: Readonly<Record<ErrorKind, number>> = {
+  policy: 1,
+  usage: 2,
+  parse: 3,
+  schema: 4,
+  io: 5,
+  timeout: 6,
+  internal: 70,
+};
+
+const ERROR_KINDS = new Set<string>(Object.keys(EXIT_CODES));
+
+/** An `Error` tagged with its kind, for `classifyError` to pick up. */
+export function createKnowgraphError(
+  kind: ErrorKind,
+  message: string,
+  options: KnowgraphErrorOptions = {},
+): KnowgraphError {
+  const err = new Error(
+    message,
+    options.cause === undefined ? undefined : { cause: options.cause },
+  );
+  err.name = 'KnowgraphError';
+  return Object.assign(err, {
+    kind,
+    ...(options.details ? { details: options.details } : {}),
+  });
+}
+
+export function isKnowgraphError(err: unknown): err is KnowgraphError {
+  return (
+    err instanceof Error &&
+    ERROR_KINDS.has(String((err as { kind?: unknown }).kind))
+  );
+}
+
+/**
+ * The kind of any thrown value. Tagged errors keep their kind; otherwise
+ * the kind is read from the error's shape: cancellation, Zod issues, YAML
+ * and JSON syntax errors, and Node system errors (`ENOENT`, `ECONNREFUSED`).
+ * A wrapped `cause` is classified when the error itself says nothing.
+ */
+export function classifyError(err: unknown): ErrorKind {
+  if (isKnowgraphError(err)) return err.kind;
+  if (!(err instanceof Error)) return 'internal';
+  if (isCancellationError(err)) return 'timeout';
+  if (err.name === 'ZodError') return 'schema';
+  if (err.name === 'YAMLParseError' || err instanceof SyntaxError) {
+    return 'parse';
+  }
+  const code = (err as { code?: unknown }).code;
+  if (typeof code === 'string' && /^E[A-Z0-9]+$/.test(code)) return 'io';
+  return err.cause === undefined ? 'internal' : classifyError(err.cause);
+}
+
+export function exitCodeFor(kind: ErrorKind): number {
+  return EXIT_CODES[kind];
+}
+
+/**
+ * The kind a process exit code stands for: undefined for 0, and
+ * `internal` for codes outside the taxonomy.
+ */
+export function errorKindForExitCode(code: number): ErrorKind | undefined {
+  if (code === 0) return undefined;
+  const entry = Object.entries(EXIT_CODES).find(([, value]) => value === code);
+  return entry ? (entry[0] as ErrorKind) : 'internal';
+}
+
+/** The envelope for `err`, classified unless `kind` is given. */
+export function toErrorEnvelope(
+  err: unknown,
+  kind: ErrorKind = classifyError(err),
+): ErrorEnvelope {
+  const message = err instanceof Error ? err.message : String(err);
+  const details = isKnowgraphError(err) ? err.details : undefined;
+  return {
+    error: {
+      kind,
+      exitCode: exitCodeFor(kind),
+      message,
+      ...(details ? { details } : {}),
+    },
+  };
+}
@@ -0,0 +1,15 @@
+export type {
+  ErrorKind,
+  KnowgraphError,
+  KnowgraphErrorOptions,
+  ErrorEnvelope,
+} from './types.js';
+export {
+  EXIT_CODES,
+  createKnowgraphError,
+  isKnowgraphError,
+  classifyError,
+  exitCodeFor,
+  errorKindForExitCode,
+  toErrorEnvelope,
+} from './errors.js';
@@ -0,0 +1,51 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the error taxonomy, its exit codes, and the JSON error envelope
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [errors, exit-codes, taxonomy, types, interface]
+ * context:
+ *   business_goal: Keep error kinds and exit codes stable so scripts written today keep working
+ *   domain: errors
+ */
+
+/**
+ * Why a command failed:
+ * - `policy`: the check ran and found problems (lint issues, low coverage)
+ * - `usage`: bad arguments or options
+ * - `parse`: a file or YAML block could not be parsed
+ * - `schema`: an annotation or manifest breaks the schema
+ * - `io`: a file, directory, process, or network call failed
+ * - `timeout`: the operation was cancelled or ran out of time
+ * - `internal`: anything else, usually a bug
+ */
+export type ErrorKind =
+  | 'policy'
+  | 'usage'
+  | 'parse'
+  | 'schema'
+  | 'io'
+  | 'timeout'
+  | 'internal';
+
+export interface KnowgraphError extends Error {
+  readonly kind: ErrorKind;
+  /** Structured context, e.g. the file and line of a parse error. */
+  readonly details?: Readonly<Record<string, unknown>>;
+}
+
+export interface KnowgraphErrorOptions {
+  readonly cause?: unknown;
+  readonly details?: Readonly<Record<string, unknown>>;
+}
+
+/** What `--error-format json` writes to stderr, as one line. */
+export interface ErrorEnvelope {
+  readonly error: {
+    readonly kind: ErrorKind;
+    readonly exitCode: number;
+    readonly message: string;
+    readonly details?: Readonly<Record<string, unknown>>;
+  };
+}
@@ -0,0 +1,210 @@
+import { describe, it, expect } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import {
+  explainEntity,
+  findSymbol,
+  formatExplanationMarkdown,
+  recentChanges,
+} from '../explain.js';
+
+function makeEntity(
+  name: string,
+  overrides: Partial<StoredEntity> = {},
+  metadata: Partial<ExtendedMetadata> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `${name} service`,
+      ...metadata,
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const checkout = makeEntity(
+  'checkout',
+  {
+    tags: ['payments'],
+    links: [{ type: 'runbook', url: 'https://runbooks.example.com/checkout' }],
+  },
+  {
+    context: { business_goal: { en: 'Take payments' }, domain: 'ordering' },
+    dependencies: { services: ['ledger'], external_apis: ['stripe'] },
+    compliance: { regulations: ['PCI-DSS'], data_sensitivity: 'restricted' },
+    operational: {
+      on_call_team: 'payments-oncall',
+      monitoring_dashboards: [
+        { url: 'https://grafana.example.com/d/checkout', title: 'Checkout' },
+      ],
+    },
+    decisions: ['adr-7'],
+    git: {
+      last_commit: '0123456789abcdef',
+      last_author: 'Ada',
+      last_modified: '2024-05-01T10:00:00Z',
+      contributors: [
+        { author: 'Ada', commits: 4, last_modified: '2024-05-01T10:00:00Z' },
+        { author: 'Lin', commits: 1, last_modified: '2024-02-11T08:30:00Z' },
+      ],
+      linked_commits: [
+        {
+          commit: 'fedcba9876543210',
+          author: 'Lin',
+          date: '2024-05-03T09:00:00Z',
+          subject: 'Retry declined charges',
+        },
+        {
+          commit: '0123456789abcdef',
+          author: 'Ada',
+          date: '2024-05-01T10:00:00Z',
+          subject: 'Add checkout',
+        },
+      ],
+    },
+  },
+);
+const ledger = makeEntity('ledger', { entityType: 'class' });
+const cart = makeEntity(
+  'cart',
+  {},
+  {
+    dependencies: { services: ['checkout'] },
+  },
+);
+const entities = [checkout, ledger, cart];
+
+describe('findSymbol', () => {
+  it('finds entities by id, location, qualified name, and name', () => {
+    const method = makeEntity('charge', { parent: 'checkout', line: 40 });
+    const all = [...entities, method];
+    expect(findSymbol(all, 'id-ledger')).toEqual([ledger]);
+    expect(findSymbol(all, 'src/charge.ts:40')).toEqual([method]);
+    expect(findSymbol(all, 'src/cart.ts:cart')).toEqual([cart]);
+    expect(findSymbol(all, 'checkout.charge')).toEqual([method]);
+    expect(findSymbol(all, 'Checkout')).toEqual([checkout]);
+    expect(findSymbol(all, 'missing')).toEqual([]);
+  });
+
+  it('returns every entity an ambiguous name matches', () => {
+    const other = makeEntity('ledger', { id: 'id-ledger-2' });
+    expect(findSymbol([ledger, other], 'ledger')).toHaveLength(2);
+  });
+});
+
+describe('explainEntity', () => {
+  const graph = buildDependencyGraph(entities);
+
+  it('collects dependencies, dependents, and annotation facts', () => {
+    const explanation = explainEntity(checkout, graph);
+    expect(explanation.dependencies.map((d) => [d.name, d.external])).toEqual([
+      ['ledger', false],
+      ['stripe', true],
+    ]);
+    expect(explanation.dependents.map((d) => d.name)).toEqual(['cart']);
+    expect(explanation.businessGoal).toBe('Take payments');
+    expect(explanation.domain).toBe('ordering');
+    expect(explanation.links.map((l) => l.url)).toEqual([
+      'https://runbooks.example.com/checkout',
+      'https://grafana.example.com/d/checkout',
+    ]);
+    expect(explanation.lastChange).toEqual({
+      commit: '0123456789abcdef',
+      author: 'Ada',
+      date: '2024-05-01T10:00:00Z',
+    });
+    expect(explanation.contributors.map((c) => c.author)).toEqual([
+      'Ada',
+      'Lin',
+    ]);
+  });
+
+  it('resolves decision references against ADRs', () => {
+    const explanation = explainEntity(checkout, graph, {
+      decisions: [
+        {
+          id: 'ADR-007',
+          title: 'Use Stripe',
+          status: 'accepted',
+          filePath: 'docs/adr/0007.md',
+        },
+      ],
+    });
+    expect(explanation.decisions).toEqual([
+      expect.objectContaining({ id: 'ADR-007' }),
+    ]);
+    expect(explainEntity(checkout, graph).decisions).toEqual([
+      { id: 'adr-7' },
+    ]);
+  });
+});
+
+describe('recentChanges', () => {
+  it('puts linked commits and the last change in date order once each', () => {
+    const changes = recentChanges(
+      explainEntity(checkout, buildDependencyGraph(entities)),
+    );
+    expect(changes.map((change) => change.commit.slice(0, 7))).toEqual([
+      'fedcba9',
+      '0123456',
+    ]);
+  });
+});
+
+describe('formatExplanationMarkdown', () => {
+  const graph = buildDependencyGraph(entities);
+
+  it('renders every known section', () => {
+    const markdown = formatExplanationMarkdown(explainEntity(checkout, graph));
+    expect(markdown).toContain('# checkout');
+    expect(markdown).toContain('| Location | `src/checkout.ts:1` |');
+    expect(markdown).toContain('**Business goal:** Take payments');
+    expect(markdown).toContain('- **ledger** (service) `src/ledger.ts`');
+    expect(markdown).toContain('- **stripe** (external_api) external');
+    expect(markdown).toContain('## Used by');
+    expect(markdown).toContain('- Regulations: PCI-DSS');
+    expect(markdown).toContain('- On call: payments-oncall');
+    expect(markdown).toContain(
+      '- [Checkout](https://grafana.example.com/d/checkout)',
+    );
+    expect(markdown).toContain(
+      '- `fedcba9` Retry declined charges by Lin on 2024-05-03T09:00:00Z',
+    );
+    expect(markdown).toContain(
+      '- `0123456` Add checkout by Ada on 2024-05-01T10:00:00Z',
+    );
+    expect(markdown).toContain(
+      '- Lin: 1 commit, last on 2024-02-11T08:30:00Z',
+    );
+  });
+
+  it('leaves out sections with nothing to show', () => {
+    const markdown = formatExplanationMarkdown(
+      explainEntity(ledger, buildDependencyGraph([ledger])),
+    );
+    expect(markdown).not.toContain('## Depends on');
+    expect(markdown).not.toContain('## Compliance');
+    expect(markdown).not.toContain('## Recent changes');
+    expect(markdown).not.toContain('## Contributors');
+  });
+});
@@ -0,0 +1,234 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Finds a symbol in the index and gathers everything known about it into a readable summary
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [explain, summary, onboarding, markdown]
+ * context:
+ *   business_goal: Pull together owner, dependencies, and history for a symbol without querying each by hand
+ *   domain: explain
+ */
+import { normalizeDecisionId } from '../decisions/adr-scanner.js';
+import type { DependencyGraph, GraphNode } from '../graph/types.js';
+import { resolveLocalizedText } from '../i18n/localized-text.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { ExtendedMetadata, Link } from '../types/entity.js';
+import type {
+  ExplainedChange,
+  ExplainedDecision,
+  ExplainedNode,
+  ExplainOptions,
+  Explanation,
+} from './types.js';
+
+/**
+ * The entities `symbol` names, trying each form in turn: an entity id,
+ * `path:line`, `path:name`, `Parent.name`, an exact name, then a
+ * case-insensitive name. The first form with a match wins, so the result
+ * holds more than one entity only when the symbol is ambiguous.
+ */
+export function findSymbol<
+  T extends Pick<StoredEntity, 'id' | 'filePath' | 'line' | 'name' | 'parent'>,
+>(entities: readonly T[], symbol: string): readonly T[] {
+  const separator = symbol.lastIndexOf(':');
+  const path = separator > 0 ? symbol.slice(0, separator) : undefined;
+  const rest = symbol.slice(separator + 1);
+  const lower = symbol.toLowerCase();
+  const tiers: ((entity: T) => boolean)[] = [
+    (entity) => entity.id === symbol,
+    (entity) => entity.filePath === path && String(entity.line) === rest,
+    (entity) => entity.filePath === path && entity.name === rest,
+    (entity) =>
+      entity.parent !== null && `${entity.parent}.${entity.name}` === symbol,
+    (entity) => entity.name === symbol,
+    (entity) => entity.name.toLowerCase() === lower,
+  ];
+  for (const matches of tiers) {
+    const found = entities.filter(matches);
+    if (found.length > 0) return found;
+  }
+  return [];
+}
+
+function explainNode(
+  node: GraphNode,
+  kind: ExplainedNode['kind'],
+): ExplainedNode {
+  return {
+    id: node.id,
+    name: node.name,
+    entityType: node.entityType,
+    kind,
+    external: node.external,
+    filePath: node.filePath,
+    owner: node.owner,
+  };
+}
+
+/**
+ * Everything the index knows about `entity`: its annotation, the graph
+ * edges in and out of it, and the git enricher's history. Decision
+ * references are resolved against `options.decisions` when given.
+ */
+export function explainEntity(
+  entity: StoredEntity,
+  graph: DependencyGraph,
+  options: ExplainOptions = {},
+): Explanation {
+  const metadata = entity.metadata as ExtendedMetadata;
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const dependencies: ExplainedNode[] = [];
+  const dependents: ExplainedNode[] = [];
+  for (const edge of graph.edges) {
+    const to = nodes.get(edge.to);
+    const from = nodes.get(edge.from);
+    if (edge.from === entity.id && to) {
+      dependencies.push(explainNode(to, edge.kind));
+    }
+    if (edge.to === entity.id && from) {
+      dependents.push(explainNode(from, edge.kind));
+    }
+  }
+
+  const records = new Map(
+    (options.decisions ?? []).map((record) => [record.id, record]),
+  );
+  const decisions: ExplainedDecision[] = (metadata.decisions ?? []).map(
+    (ref) => {
+      const record = records.get(normalizeDecisionId(ref) ?? ref);
+      return record ? { id: record.id, record } : { id: ref };
+    },
+  );
+
+  const dashboards: Link[] = (
+    metadata.operational?.monitoring_dashboards ?? []
+  ).map((dashboard) => ({
+    type: 'dashboard',
+    url: dashboard.url,
+    title: dashboard.title,
+  }));
+  const git = metadata.git;
+
+  return {
+    entity,
+    businessGoal: resolveLocalizedText(metadata.context?.business_goal),
+    domain: metadata.context?.domain,
+    dependencies,
+    dependents,
+    compliance: metadata.compliance,
+    operational: metadata.operational,
+    links: [...entity.links, ...dashboards],
+    decisions,
+    lastChange: git
+      ? {
+          commit: git.last_commit,
+          author: git.last_author,
+          date: git.last_modified,
+        }
+      : undefined,
+    linkedChanges: git?.linked_commits ?? [],
+    contributors: git?.contributors ?? [],
+  };
+}
+
+/**
+ * The entity's last change followed by its trailer-linked commits, newest
+ * first, without listing a commit twice.
+ */
+export function recentChanges(
+  explanation: Explanation,
+): readonly ExplainedChange[] {
+  const { lastChange, linkedChanges } = explanation;
+  return [
+    ...linkedChanges,
+    ...(lastChange &&
+    !linkedChanges.some((change) => change.commit === lastChange.commit)
+      ? [lastChange]
+      : []),
+  ].sort((a, b) => Date.parse(b.date) - Date.parse(a.date));
+}
+
+function nodeLine(node: ExplainedNode): string {
+  const where = node.external ? 'external' : `\`${node.filePath ?? ''}\``;
+  const owner = node.owner ? `, ${node.owner}` : '';
+  return `- **${node.name}** (${node.kind}) ${where}${owner}`;
+}
+
+/** The explanation as a Markdown document, with empty sections left out. */
+export function formatExplanationMarkdown(explanation: Explanation): string {
+  const { entity } = explanation;
+  const lines = [
+    `# ${entity.name}`,
+    '',
+    entity.description,
+    '',
+    `| | |`,
+    `|---|---|`,
+    `| Type | ${entity.entityType} |`,
+    `| Location | \`${entity.filePath}:${entity.line}\` |`,
+    `| Owner | ${entity.owner ?? '-'} |`,
+    `| Status | ${entity.status ?? '-'} |`,
+  ];
+  if (explanation.domain) lines.push(`| Domain | ${explanation.domain} |`);
+  if (entity.tags.length > 0) {
+    lines.push(`| Tags | ${entity.tags.join(', ')} |`);
+  }
+  if (explanation.businessGoal) {
+    lines.push('', `**Business goal:** ${explanation.businessGoal}`);
+  }
+
+  const section = (title: string, items: readonly string[]): void => {
+    if (items.length > 0) lines.push('', `## ${title}`, '', ...items);
+  };
+  section('Depends on', explanation.dependencies.map(nodeLine));
+  section('Used by', explanation.dependents.map(nodeLine));
+
+  const { compliance, operational } = explanation;
+  section('Compliance', [
+    ...(compliance?.regulations?.length
+      ? [`- Regulations: ${compliance.regulations.join(', ')}`]
+      : []),
+    ...(compliance?.data_sensitivity
+      ? [`- Data sensitivity: ${compliance.data_sensitivity}`]
+      : []),
+    ...(compliance?.audit_requirements?.length
+      ? [`- Audit requirements: ${compliance.audit_requirements.join(', ')}`]
+      : []),
+  ]);
+  section('Operations', [
+    ...(operational?.sla ? [`- SLA: ${operational.sla}`] : []),
+    ...(operational?.on_call_team
+      ? [`- On call: ${operational.on_call_team}`]
+      : []),
+  ]);
+  section(
+    'Links',
+    explanation.links.map(
+      (link) => `- [${link.title ?? link.type ?? link.url}](${link.url})`,
+    ),
+  );
+  section(
+    'Decisions',
+    explanation.decisions.map((decision) =>
+      decision.record
+        ? `- ${decision.id}: ${decision.record.title} (${decision.record.status})`
+        : `- ${decision.id}`,
+    ),
+  );
+  section(
+    'Recent changes',
+    recentChanges(explanation).map(
+      (change) =>
+        `- \`${change.commit.slice(0, 7)}\`${change.subject ? ` ${change.subject}` : ''} by ${change.author} on ${change.date}`,
+    ),
+  );
+  section(
+    'Contributors',
+    explanation.contributors.map(
+      (c) =>
+        `- ${c.author}: ${c.commits} commit${c.commits === 1 ? '' : 's'}, last on ${c.last_modified}`,
+    ),
+  );
+  return `${lines.join('\n')}\n`;
+}
@@ -0,0 +1,13 @@
+export type {
+  ExplainedNode,
+  ExplainedDecision,
+  ExplainedChange,
+  Explanation,
+  ExplainOptions,
+} from './types.js';
+export {
+  findSymbol,
+  explainEntity,
+  formatExplanationMarkdown,
+  recentChanges,
+} from './explain.js';
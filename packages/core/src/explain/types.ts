@@ -0,0 +1,69 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Types for the per-symbol summaries built by explainEntity
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [explain, summary, onboarding, types]
+ * context:
+ *   business_goal: Let editors and chat tools render symbol summaries as well as the terminal
+ *   domain: explain
+ */
+import type { DecisionRecord } from '../decisions/types.js';
+import type { DependencyKind } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  Compliance,
+  EntityType,
+  GitContributor,
+  Link,
+  Operational,
+} from '../types/entity.js';
+
+/** The other end of a dependency edge. */
+export interface ExplainedNode {
+  readonly id: string;
+  readonly name: string;
+  readonly entityType: EntityType | null;
+  readonly kind: DependencyKind;
+  readonly external: boolean;
+  readonly filePath: string | null;
+  readonly owner: string | null;
+}
+
+/** A decision the entity references, with its ADR when one was found. */
+export interface ExplainedDecision {
+  readonly id: string;
+  readonly record?: DecisionRecord;
+}
+
+export interface ExplainedChange {
+  readonly commit: string;
+  readonly author: string;
+  readonly date: string;
+  readonly subject?: string;
+}
+
+export interface Explanation {
+  readonly entity: StoredEntity;
+  readonly businessGoal?: string;
+  readonly domain?: string;
+  readonly dependencies: readonly ExplainedNode[];
+  readonly dependents: readonly ExplainedNode[];
+  readonly compliance?: Compliance;
+  readonly operational?: Operational;
+  /** The entity's links, then its monitoring dashboards. */
+  readonly links: readonly Link[];
+  readonly decisions: readonly ExplainedDecision[];
+  /** The last commit to the entity's file, from the git enricher. */
+  readonly lastChange?: ExplainedChange;
+  /** Commits whose `Knowgraph-Node` trailers name the entity, newest first. */
+  readonly linkedChanges: readonly ExplainedChange[];
+  /** Top authors of recent commits to the file, from the git enricher. */
+  readonly contributors: readonly GitContributor[];
+}
+
+export interface ExplainOptions {
+  /** ADRs to resolve the entity's `decisions` references against. */
+  readonly decisions?: readonly DecisionRecord[];
+}
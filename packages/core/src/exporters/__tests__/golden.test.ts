@@ -0,0 +1,90 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  checkGoldenExports,
+  checkGoldenFile,
+  renderGoldenExport,
+} from '../golden.js';
+import { createDefaultExporterRegistry } from '../registry.js';
+
+// Rewrite the golden files after an intended format change with
+//   KNOWGRAPH_UPDATE_GOLDEN=1 pnpm --filter @know-graph/core test
+// and review the diff like any other change.
+const GOLDEN_DIR = resolve(__dirname, 'fixtures', 'golden');
+const update = process.env.KNOWGRAPH_UPDATE_GOLDEN === '1';
+
+describe('golden exports', () => {
+  const exporters = createDefaultExporterRegistry().list();
+
+  for (const exporter of exporters) {
+    it(`renders ${exporter.name} as its golden file`, () => {
+      const [result] = checkGoldenExports([exporter], GOLDEN_DIR, { update });
+      expect(result.diff ?? '').toBe('');
+      expect(result.status, `${result.path} has not been written`).not.toBe(
+        'missing',
+      );
+    });
+  }
+
+  it('renders the same bytes every time', () => {
+    for (const exporter of exporters) {
+      expect(renderGoldenExport(exporter)).toEqual(
+        renderGoldenExport(exporter),
+      );
+    }
+  });
+});
+
+describe('checkGoldenFile', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-golden-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  const bytes = (text: string): Uint8Array => Buffer.from(text, 'utf-8');
+
+  it('reports a missing golden file unless updating', () => {
+    const path = join(dir, 'nested', 'text.golden');
+    expect(checkGoldenFile(path, bytes('a\n')).status).toBe('missing');
+    expect(checkGoldenFile(path, bytes('a\n'), { update: true }).status).toBe(
+      'created',
+    );
+    expect(readFileSync(path, 'utf-8')).toBe('a\n');
+    expect(checkGoldenFile(path, bytes('a\n')).status).toBe('match');
+  });
+
+  it('diffs text output against the golden file', () => {
+    const path = join(dir, 'text.golden');
+    writeFileSync(path, 'one\ntwo\nthree\n');
+    const result = checkGoldenFile(path, bytes('one\n2\nthree\n'));
+    expect(result.status).toBe('mismatch');
+    expect(result.diff).toContain('-two');
+    expect(result.diff).toContain('+2');
+    expect(readFileSync(path, 'utf-8')).toBe('one\ntwo\nthree\n');
+  });
+
+  it('summarizes binary differences', () => {
+    const path = join(dir, 'binary.golden');
+    writeFileSync(path, Buffer.from([1, 0, 2, 3]));
+    const result = checkGoldenFile(path, Buffer.from([1, 0, 9]));
+    expect(result.diff).toBe(
+      `Binary output differs from ${path}: expected 4 bytes, got 3, ` +
+        'first difference at byte 2',
+    );
+  });
+
+  it('rewrites a different golden file when updating', () => {
+    const path = join(dir, 'text.golden');
+    writeFileSync(path, 'old\n');
+    const result = checkGoldenFile(path, bytes('new\n'), { update: true });
+    expect(result).toEqual({ path, status: 'updated' });
+    expect(readFileSync(path, 'utf-8')).toBe('new\n');
+  });
+});
@@ -0,0 +1,170 @@
+import { describe, it, expect } from 'vitest';
+import { stableStringify } from '../../canonical/canonical.js';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { applyGraphPatch, parseGraphPatch } from '../../patch/graph-patch.js';
+import { isParquet } from '../../parquet/parquet.js';
+import { decodeGraphSnapshot } from '../../snapshot/graph-snapshot.js';
+import {
+  createDefaultExporterRegistry,
+  createExporterRegistry,
+} from '../registry.js';
+import type { Exporter } from '../types.js';
+
+function makeEntity(name: string, dependsOn: string[] = []): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: name,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: null,
+    status: null,
+    metadata: {
+      type: 'service',
+      description: `${name} service`,
+      dependencies: { services: dependsOn },
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const entities = [makeEntity('checkout', ['payments']), makeEntity('payments')];
+
+function collect(exporter: Exporter): {
+  readonly chunks: Array<string | Uint8Array>;
+  readonly stats: ReturnType<Exporter['export']>;
+} {
+  const chunks: Array<string | Uint8Array> = [];
+  const stats = exporter.export(
+    () => entities,
+    { write: (chunk) => chunks.push(chunk) },
+    {},
+  );
+  return { chunks, stats };
+}
+
+describe('exporter registry', () => {
+  it('keeps the first exporter registered under a name', () => {
+    const registry = createExporterRegistry();
+    const first: Exporter = {
+      name: 'text',
+      description: 'first',
+      defaultOutput: 'out.txt',
+      export: () => ({ nodes: 0 }),
+    };
+    registry.register(first);
+    registry.register({ ...first, description: 'second' });
+    expect(registry.get('text')).toBe(first);
+    expect(registry.get('missing')).toBeUndefined();
+    expect(registry.list()).toEqual([first]);
+  });
+
+  it('provides the graph formats by default', () => {
+    const registry = createDefaultExporterRegistry();
+    expect(registry.list().map((e) => [e.name, e.defaultOutput])).toEqual([
+      ['json', 'knowgraph-graph.json'],
+      ['snapshot', 'knowgraph-graph.kgs'],
+      ['patch', 'knowgraph-graph.patch.json'],
+      ['parquet-nodes', 'knowgraph-nodes.parquet'],
+      ['parquet-edges', 'knowgraph-edges.parquet'],
+      ['chunks', 'knowgraph-chunks.jsonl'],
+    ]);
+  });
+
+  it('streams graph JSON', () => {
+    const json = createDefaultExporterRegistry().get('json')!;
+    const { chunks, stats } = collect(json);
+    expect(chunks.join('')).toBe(
+      stableStringify(buildDependencyGraph(entities)),
+    );
+    expect(stats).toEqual({ nodes: 2, edges: 1 });
+  });
+
+  it('writes snapshots as one binary chunk', () => {
+    const snapshot = createDefaultExporterRegistry().get('snapshot')!;
+    const { chunks, stats } = collect(snapshot);
+    expect(chunks).toHaveLength(1);
+    expect(decodeGraphSnapshot(chunks[0] as Uint8Array)).toEqual(
+      buildDependencyGraph(entities),
+    );
+    expect(stats).toEqual({ nodes: 2, edges: 1 });
+  });
+
+  it('writes each Parquet table as one binary chunk', () => {
+    const registry = createDefaultExporterRegistry();
+    for (const name of ['parquet-nodes', 'parquet-edges']) {
+      const { chunks, stats } = collect(registry.get(name)!);
+      expect(chunks).toHaveLength(1);
+      expect(isParquet(chunks[0] as Uint8Array)).toBe(true);
+      expect(stats).toEqual({ nodes: 2, edges: 1 });
+    }
+  });
+
+  it('keeps out-of-scope neighbors as stubs in scoped snapshots', () => {
+    const snapshot = createDefaultExporterRegistry().get('snapshot')!;
+    const chunks: Array<string | Uint8Array> = [];
+    snapshot.export(
+      () => entities,
+      { write: (chunk) => chunks.push(chunk) },
+      { inScope: new Set(['id-checkout']) },
+    );
+    const graph = decodeGraphSnapshot(chunks[0] as Uint8Array);
+    expect(graph.nodes.map((node) => [node.name, node.stub])).toEqual([
+      ['checkout', undefined],
+      ['payments', true],
+    ]);
+    expect(graph.edges).toHaveLength(1);
+  });
+
+  it('writes pruned graph JSON and reports what was left out', () => {
+    const json = createDefaultExporterRegistry().get('json')!;
+    const chunks: string[] = [];
+    const stats = json.export(
+      () => [...entities, makeEntity('audit')],
+      { write: (chunk) => chunks.push(String(chunk)) },
+      { prune: { minSignificance: 1 } },
+    );
+    expect(chunks.join('')).toBe(
+      stableStringify(buildDependencyGraph(entities)),
+    );
+    expect(stats).toEqual({
+      nodes: 2,
+      edges: 1,
+      pruned: [
+        {
+          node: 'id-audit',
+          name: 'audit',
+          reason: 'below-significance',
+          significance: 0,
+        },
+      ],
+    });
+  });
+
+  it('writes a patch from the base graph to the export', () => {
+    const patcher = createDefaultExporterRegistry().get('patch')!;
+    const base = buildDependencyGraph(entities.slice(0, 1));
+    const chunks: string[] = [];
+    const stats = patcher.export(
+      () => entities,
+      { write: (chunk) => chunks.push(String(chunk)) },
+      { base },
+    );
+    expect(applyGraphPatch(base, parseGraphPatch(chunks.join('')))).toEqual(
+      buildDependencyGraph(entities),
+    );
+    expect(stats).toEqual({ nodes: 2, edges: 2 });
+    expect(() => collect(patcher)).toThrow('The patch format needs --base');
+  });
+});
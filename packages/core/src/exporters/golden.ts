@@ -0,0 +1,243 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Golden-file harness that renders each exporter over a fixed set of entities and compares the bytes with a checked-in file
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [export, testing, golden, plugins]
+ * context:
+ *   business_goal: Make every change to an output format explicit and reviewed, for built-in and plugin exporters alike
+ *   domain: export
+ */
+import { existsSync, mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { dirname, join } from 'node:path';
+import { diffText } from '../audit/diff.js';
+import { buildDependencyGraph } from '../graph/graph-builder.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  Exporter,
+  ExportOptions,
+  GoldenCheckOptions,
+  GoldenExportResult,
+  GoldenResult,
+} from './types.js';
+
+const TIMESTAMP = '2026-01-01T00:00:00.000Z';
+
+function goldenEntity(
+  entity: Pick<StoredEntity, 'id' | 'name' | 'filePath' | 'metadata'> &
+    Partial<StoredEntity>,
+): StoredEntity {
+  const { metadata } = entity;
+  return {
+    entityType: metadata.type,
+    description:
+      typeof metadata.description === 'string' ? metadata.description : '',
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 1,
+    owner: metadata.owner ?? null,
+    status: metadata.status ?? null,
+    tags: metadata.tags ?? [],
+    links: metadata.links ?? [],
+    fileHash: null,
+    createdAt: TIMESTAMP,
+    updatedAt: TIMESTAMP,
+    ...entity,
+  };
+}
+
+/**
+ * The entities golden exports are rendered from: services that depend on
+ * each other, a method inside one, a database and an external API, links,
+ * a Python service, and a description outside ASCII. Changing them changes
+ * every golden file, built-in and plugin alike.
+ */
+export const GOLDEN_ENTITIES: readonly StoredEntity[] = [
+  goldenEntity({
+    id: 'golden-checkout',
+    name: 'CheckoutService',
+    filePath: 'src/checkout/checkout-service.ts',
+    metadata: {
+      type: 'service',
+      description: 'Takes payment for a cart and books the shipment',
+      owner: 'payments-team',
+      status: 'stable',
+      tags: ['checkout', 'payments'],
+      links: [
+        {
+          type: 'runbook',
+          url: 'https://runbooks.example.com/checkout',
+          title: 'Checkout runbook',
+        },
+      ],
+      context: { domain: 'commerce', business_goal: 'Convert carts' },
+      dependencies: {
+        services: ['PaymentGateway', 'ShippingService'],
+        databases: ['orders-db'],
+      },
+    },
+  }),
+  goldenEntity({
+    id: 'golden-payment-gateway',
+    name: 'PaymentGateway',
+    filePath: 'src/payments/gateway.ts',
+    metadata: {
+      type: 'service',
+      description: 'Charges cards through the card processor',
+      owner: 'payments-team',
+      status: 'stable',
+      tags: ['payments'],
+      context: { domain: 'commerce' },
+      dependencies: { external_apis: ['stripe'] },
+    },
+  }),
+  goldenEntity({
+    id: 'golden-charge',
+    name: 'charge',
+    filePath: 'src/payments/gateway.ts',
+    line: 24,
+    parent: 'PaymentGateway',
+    signature: 'charge(amount: Money): Promise<Receipt>',
+    metadata: {
+      type: 'method',
+      description: 'Charges an amount once, retrying on timeouts',
+      owner: 'payments-team',
+      tags: ['payments', 'idempotent'],
+    },
+  }),
+  goldenEntity({
+    id: 'golden-shipping',
+    name: 'ShippingService',
+    filePath: 'services/shipping/service.py',
+    language: 'python',
+    metadata: {
+      type: 'service',
+      description: 'Books parcels with carriers — DHL, La Poste, ヤマト運輸',
+      owner: 'logistics-team',
+      status: 'experimental',
+      tags: ['shipping'],
+      context: { domain: 'logistics' },
+    },
+  }),
+];
+
+/**
+ * Options golden exports are rendered with: binary formats uncompressed,
+ * and a `base` for the `patch` format holding only the first two entities.
+ */
+export function goldenExportOptions(): ExportOptions {
+  return {
+    base: buildDependencyGraph(GOLDEN_ENTITIES.slice(0, 2)),
+    compress: false,
+  };
+}
+
+/** Render `exporter` over `GOLDEN_ENTITIES` as one run of bytes. */
+export function renderGoldenExport(
+  exporter: Exporter,
+  options: ExportOptions = goldenExportOptions(),
+): Uint8Array {
+  const chunks: Buffer[] = [];
+  exporter.export(
+    () => GOLDEN_ENTITIES,
+    {
+      write: (chunk) =>
+        chunks.push(
+          typeof chunk === 'string'
+            ? Buffer.from(chunk, 'utf-8')
+            : Buffer.from(chunk),
+        ),
+    },
+    options,
+  );
+  return Buffer.concat(chunks);
+}
+
+/** The golden file for `exporter` in `dir`. */
+export function goldenFilePath(dir: string, exporter: Exporter): string {
+  return join(dir, `${exporter.name}.golden`);
+}
+
+/** `bytes` as text, or undefined when they are not UTF-8 without NULs. */
+function asText(bytes: Uint8Array): string | undefined {
+  if (bytes.includes(0)) return undefined;
+  try {
+    return new TextDecoder('utf-8', { fatal: true }).decode(bytes);
+  } catch {
+    return undefined;
+  }
+}
+
+function describeMismatch(
+  path: string,
+  expected: Uint8Array,
+  actual: Uint8Array,
+): string {
+  const expectedText = asText(expected);
+  const actualText = asText(actual);
+  if (expectedText !== undefined && actualText !== undefined) {
+    return diffText(path, expectedText, actualText);
+  }
+  let offset = 0;
+  while (
+    offset < expected.length &&
+    offset < actual.length &&
+    expected[offset] === actual[offset]
+  ) {
+    offset++;
+  }
+  return (
+    `Binary output differs from ${path}: expected ${expected.length} ` +
+    `bytes, got ${actual.length}, first difference at byte ${offset}`
+  );
+}
+
+/**
+ * Compare `actual` with the golden file at `path`. With `update`, a
+ * missing or different file is rewritten instead of reported.
+ */
+export function checkGoldenFile(
+  path: string,
+  actual: Uint8Array,
+  options: GoldenCheckOptions = {},
+): GoldenResult {
+  const exists = existsSync(path);
+  const expected = exists ? readFileSync(path) : undefined;
+  if (expected && Buffer.compare(expected, actual) === 0) {
+    return { path, status: 'match' };
+  }
+  if (options.update) {
+    mkdirSync(dirname(path), { recursive: true });
+    writeFileSync(path, actual);
+    return { path, status: exists ? 'updated' : 'created' };
+  }
+  if (!expected) return { path, status: 'missing' };
+  return {
+    path,
+    status: 'mismatch',
+    diff: describeMismatch(path, expected, actual),
+  };
+}
+
+/**
+ * Render every exporter over `GOLDEN_ENTITIES` and check it against its
+ * golden file in `dir`, in the order given.
+ */
+export function checkGoldenExports(
+  exporters: readonly Exporter[],
+  dir: string,
+  options: GoldenCheckOptions = {},
+): readonly GoldenExportResult[] {
+  return exporters.map((exporter) => ({
+    format: exporter.name,
+    ...checkGoldenFile(
+      goldenFilePath(dir, exporter),
+      renderGoldenExport(exporter),
+      options,
+    ),
+  }));
+}
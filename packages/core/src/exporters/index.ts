@@ -0,0 +1,28 @@
+export type {
+  ExportOptions,
+  ExportStats,
+  Exporter,
+  ExporterRegistry,
+  GoldenCheckOptions,
+  GoldenExportResult,
+  GoldenResult,
+  GoldenStatus,
+} from './types.js';
+export {
+  buildExportGraph,
+  createChunksExporter,
+  createExporterRegistry,
+  createDefaultExporterRegistry,
+  createGraphJsonExporter,
+  createParquetExporter,
+  createPatchExporter,
+  createSnapshotExporter,
+} from './registry.js';
+export {
+  GOLDEN_ENTITIES,
+  checkGoldenExports,
+  checkGoldenFile,
+  goldenExportOptions,
+  goldenFilePath,
+  renderGoldenExport,
+} from './golden.js';
@@ -0,0 +1,251 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Exporter registry that resolves output formats by name, with the graph JSON, snapshot, patch, Parquet, and chunk formats built in
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [export, registry, factory, streaming, snapshot, patch, parquet, chunking]
+ * context:
+ *   business_goal: Add output formats uniformly instead of special-casing each one in the export command
+ *   domain: export
+ */
+import { stableStringify } from '../canonical/canonical.js';
+import { chunkGraph } from '../chunking/chunks.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import {
+  buildDependencyGraph,
+  namespaceSubmodules,
+} from '../graph/graph-builder.js';
+import { filterGraphEdges } from '../graph/graph-provenance.js';
+import { pruneGraph } from '../graph/graph-prune.js';
+import type { DependencyGraph } from '../graph/types.js';
+import { namespaceGraph } from '../namespace/namespace.js';
+import { encodeGraphParquet } from '../parquet/graph-parquet.js';
+import type { GraphParquetTable } from '../parquet/types.js';
+import { createGraphPatch } from '../patch/graph-patch.js';
+import { encodeGraphSnapshot } from '../snapshot/graph-snapshot.js';
+import { writeGraphJson } from '../streaming/graph-json.js';
+import { timePhase } from '../profiling/phase-timer.js';
+import { scopeGraph } from '../scope/scope.js';
+import type { EntitySource } from '../streaming/types.js';
+import type {
+  Exporter,
+  ExporterRegistry,
+  ExportOptions,
+  ExportStats,
+} from './types.js';
+
+export function createExporterRegistry(): ExporterRegistry {
+  const exporters: Exporter[] = [];
+
+  return {
+    register(exporter: Exporter): void {
+      if (!exporters.some((e) => e.name === exporter.name)) {
+        exporters.push(exporter);
+      }
+    },
+
+    get(name: string): Exporter | undefined {
+      return exporters.find((e) => e.name === name);
+    },
+
+    list(): readonly Exporter[] {
+      return [...exporters];
+    },
+  };
+}
+
+/**
+ * The graph a graph format writes: built with the names and edge rules in
+ * `options`, then filtered by edge, scoped, pruned, and namespaced in that
+ * order. Scopes hold entity ids, so namespacing, submodules' first, comes
+ * last.
+ */
+export function buildExportGraph(
+  entities: EntitySource,
+  options: ExportOptions,
+): Pick<ExportStats, 'pruned'> & { readonly graph: DependencyGraph } {
+  const built = buildDependencyGraph([...entities()], {
+    aliases: options.aliases,
+    renames: options.renames,
+    edgeRules: options.edgeRules,
+  });
+  const full = options.edgeFilter
+    ? filterGraphEdges(built, options.edgeFilter)
+    : built;
+  const scoped = options.inScope ? scopeGraph(full, options.inScope) : full;
+  const pruned = options.prune ? pruneGraph(scoped, options.prune) : undefined;
+  const graph = pruned?.graph ?? scoped;
+  const local = options.submodules?.length
+    ? namespaceSubmodules(graph, options.submodules)
+    : graph;
+  return {
+    graph: options.namespace ? namespaceGraph(local, options.namespace) : local,
+    ...(pruned ? { pruned: pruned.decisions } : {}),
+  };
+}
+
+/**
+ * Streams the dependency graph as pretty-printed JSON. Pruning and edge
+ * rules need the whole graph, so those exports are built in memory instead.
+ */
+export function createGraphJsonExporter(): Exporter {
+  return {
+    name: 'json',
+    description: 'Dependency graph as JSON, streamed in bounded memory',
+    defaultOutput: 'knowgraph-graph.json',
+    scoped: true,
+    export(entities, sink, options) {
+      if (options.prune || options.edgeRules?.length) {
+        const { graph, pruned } = timePhase(options.profiler, 'build', () =>
+          buildExportGraph(entities, options),
+        );
+        timePhase(options.profiler, 'export', () =>
+          sink.write(stableStringify(graph, true)),
+        );
+        return { nodes: graph.nodes.length, edges: graph.edges.length, pruned };
+      }
+      return writeGraphJson(entities, sink, {
+        signal: options.signal,
+        timeoutMs: options.timeoutMs,
+        profiler: options.profiler,
+        inScope: options.inScope,
+        edgeFilter: options.edgeFilter,
+        aliases: options.aliases,
+        renames: options.renames,
+        namespace: options.namespace,
+        submodules: options.submodules,
+        pretty: true,
+      });
+    },
+  };
+}
+
+/** Writes the dependency graph as a compact binary snapshot. */
+export function createSnapshotExporter(): Exporter {
+  return {
+    name: 'snapshot',
+    description: 'Dependency graph as a compressed binary snapshot',
+    defaultOutput: 'knowgraph-graph.kgs',
+    scoped: true,
+    export(entities, sink, options) {
+      const { graph, pruned } = timePhase(options.profiler, 'build', () =>
+        buildExportGraph(entities, options),
+      );
+      timePhase(options.profiler, 'export', () =>
+        sink.write(
+          encodeGraphSnapshot(graph, { compress: options.compress }),
+        ),
+      );
+      return { nodes: graph.nodes.length, edges: graph.edges.length, pruned };
+    },
+  };
+}
+
+/**
+ * Writes the changes from `options.base` to the dependency graph as a
+ * compact JSON graph patch, for pushing to a registry that holds the base.
+ */
+export function createPatchExporter(): Exporter {
+  return {
+    name: 'patch',
+    description: 'Changes since --base as a graph patch, for registry pushes',
+    defaultOutput: 'knowgraph-graph.patch.json',
+    scoped: true,
+    export(entities, sink, options) {
+      const { base } = options;
+      if (!base) {
+        throw createKnowgraphError(
+          'usage',
+          'The patch format needs --base <graph>: the graph the receiver has',
+        );
+      }
+      const { graph, pruned } = timePhase(options.profiler, 'build', () =>
+        buildExportGraph(entities, options),
+      );
+      const patch = createGraphPatch(base, graph);
+      timePhase(options.profiler, 'export', () =>
+        sink.write(stableStringify(patch, false)),
+      );
+      return {
+        nodes: patch.nodes.upsert.length + patch.nodes.remove.length,
+        edges: patch.edges.upsert.length + patch.edges.remove.length,
+        pruned,
+      };
+    },
+  };
+}
+
+/**
+ * Writes the graph's nodes or edges as a Parquet table with typed
+ * columns, for DuckDB, Spark, BigQuery, and other analytics engines.
+ */
+export function createParquetExporter(table: GraphParquetTable): Exporter {
+  return {
+    name: `parquet-${table}`,
+    description: `Dependency graph ${table} as a Parquet table`,
+    defaultOutput: `knowgraph-${table}.parquet`,
+    scoped: true,
+    export(entities, sink, options) {
+      const { graph, pruned } = timePhase(options.profiler, 'build', () =>
+        buildExportGraph(entities, options),
+      );
+      timePhase(options.profiler, 'export', () =>
+        sink.write(
+          encodeGraphParquet(graph, table, { compress: options.compress }),
+        ),
+      );
+      return { nodes: graph.nodes.length, edges: graph.edges.length, pruned };
+    },
+  };
+}
+
+/**
+ * Writes the graph as JSON Lines of overlapping per-module chunks with
+ * token counts and stable ids (see `chunkGraph`), for RAG indexing.
+ */
+export function createChunksExporter(): Exporter {
+  return {
+    name: 'chunks',
+    description: 'Per-module graph chunks with neighbors, for RAG indexing',
+    defaultOutput: 'knowgraph-chunks.jsonl',
+    localized: true,
+    scoped: true,
+    export(entities, sink, options) {
+      const all = [...entities()];
+      // Chunks list namespaced ids themselves; the graph keeps entity ids
+      const { graph, pruned } = timePhase(options.profiler, 'build', () =>
+        buildExportGraph(() => all, {
+          ...options,
+          namespace: undefined,
+          submodules: undefined,
+        }),
+      );
+      const chunks = timePhase(options.profiler, 'export', () => {
+        const built = chunkGraph(graph, all, {
+          maxTokens: options.chunkTokens,
+          namespace: options.namespace,
+        });
+        for (const chunk of built) {
+          sink.write(`${stableStringify(chunk, false)}\n`);
+        }
+        return built;
+      });
+      return {
+        nodes: chunks.reduce((sum, chunk) => sum + chunk.nodes.length, 0),
+        pruned,
+      };
+    },
+  };
+}
+
+export function createDefaultExporterRegistry(): ExporterRegistry {
+  const registry = createExporterRegistry();
+  registry.register(createGraphJsonExporter());
+  registry.register(createSnapshotExporter());
+  registry.register(createPatchExporter());
+  registry.register(createParquetExporter('nodes'));
+  registry.register(createParquetExporter('edges'));
+  registry.register(createChunksExporter());
+  return registry;
+}
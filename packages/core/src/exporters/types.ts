@@ -0,0 +1,133 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Exporter interface and registry types shared by built-in, CLI, and plugin output formats
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [export, registry, types, interface]
+ * context:
+ *   business_goal: Let plugins and the CLI register output formats through one interface
+ *   domain: export
+ */
+import type { CancellationOptions } from '../cancellation/cancellation.js';
+import type {
+  DependencyGraph,
+  EdgeFilter,
+  GraphNameOptions,
+  GraphRuleOptions,
+  PruneDecision,
+  PruneOptions,
+} from '../graph/types.js';
+import type { GitSubmodule } from '../indexer/types.js';
+import type { PhaseTimer } from '../profiling/types.js';
+import type { ByteSink, EntitySource } from '../streaming/types.js';
+
+/**
+ * `aliases`, `renames`, and `edgeRules` apply to the formats that build a
+ * graph.
+ */
+export interface ExportOptions
+  extends CancellationOptions,
+    GraphNameOptions,
+    GraphRuleOptions {
+  /** Records preparing the graph as `build` and writing it as `export`. */
+  readonly profiler?: PhaseTimer;
+  /**
+   * Ids of the entities in scope, for exporters that are `scoped`. Ids
+   * survive redaction, unlike the paths and tags scopes match on.
+   */
+  readonly inScope?: ReadonlySet<string>;
+  /** For graph formats, the edges to write; the rest are left out. */
+  readonly edgeFilter?: EdgeFilter;
+  /** For graph formats, the `org/repo` namespace to prefix node ids with. */
+  readonly namespace?: string;
+  /** For graph formats, the submodules whose nodes carry their own. */
+  readonly submodules?: readonly GitSubmodule[];
+  /**
+   * Cut the graph down before it is written (see `pruneGraph`). Context
+   * formats leave out the entities whose nodes are pruned.
+   */
+  readonly prune?: PruneOptions;
+  /** For the `chunks` format, the tokens a chunk may hold (default 1000). */
+  readonly chunkTokens?: number;
+  /** For the `patch` format, the graph the receiver already has. */
+  readonly base?: DependencyGraph;
+  /**
+   * For binary formats, whether to compress the payload (default: true).
+   * Golden files are written uncompressed, since deflate output can differ
+   * between zlib builds.
+   */
+  readonly compress?: boolean;
+}
+
+export interface ExportStats {
+  /** Entities or graph nodes written. */
+  readonly nodes: number;
+  /** Dependency edges written, for graph formats. */
+  readonly edges?: number;
+  /** What `prune` left out and why, when pruning was asked for. */
+  readonly pruned?: readonly PruneDecision[];
+}
+
+export interface Exporter {
+  /** The `--format` value, such as `json`. */
+  readonly name: string;
+  readonly description: string;
+  /** File written when no output path is given. */
+  readonly defaultOutput: string;
+  /**
+   * Whether the format is read by people and wants descriptions resolved
+   * to one locale (see `localizeEntity`) before entities are passed in.
+   */
+  readonly localized?: boolean;
+  /**
+   * Whether the format applies `inScope` itself, keeping out-of-scope
+   * neighbors as stubs. Other exporters are passed only in-scope entities.
+   */
+  readonly scoped?: boolean;
+  /**
+   * Write `entities` to `sink`. `entities` restarts on each call, so
+   * exporters may read it more than once.
+   */
+  export(
+    entities: EntitySource,
+    sink: ByteSink,
+    options: ExportOptions,
+  ): ExportStats;
+}
+
+export interface ExporterRegistry {
+  /** Add an exporter. A name that is already taken keeps its exporter. */
+  register(exporter: Exporter): void;
+  get(name: string): Exporter | undefined;
+  /** Every exporter, in registration order. */
+  list(): readonly Exporter[];
+}
+
+/** How an export compared with its golden file. */
+export type GoldenStatus =
+  | 'match'
+  | 'mismatch'
+  | 'missing'
+  | 'created'
+  | 'updated';
+
+export interface GoldenCheckOptions {
+  /** Write the output as the new golden file instead of failing on it. */
+  readonly update?: boolean;
+}
+
+export interface GoldenResult {
+  readonly path: string;
+  readonly status: GoldenStatus;
+  /**
+   * For a mismatch, a unified diff from the golden file to the output, or
+   * a summary when either is binary.
+   */
+  readonly diff?: string;
+}
+
+export interface GoldenExportResult extends GoldenResult {
+  /** The exporter's format name. */
+  readonly format: string;
+}
@@ -0,0 +1,72 @@
+import { describe, it, expect } from 'vitest';
+import { detectCostExportProvider, parseCostExport } from '../cost-import.js';
+
+const AWS_CUR = [
+  'lineItem/UsageAccountId,lineItem/UnblendedCost,lineItem/CurrencyCode,resourceTags/user:app',
+  '1234,12.50,USD,checkout',
+  '1234,3.25,USD,',
+  '1234,,USD,checkout',
+].join('\n');
+
+const AWS_CUR2 = [
+  'line_item_unblended_cost,line_item_currency_code,resource_tags',
+  '4.00,USD,"{""user_app"":""search""}"',
+].join('\n');
+
+const GCP_EXPORT = [
+  'service.description,cost,currency,labels',
+  'Compute Engine,7.5,EUR,"[{""key"":""app"",""value"":""checkout""}]"',
+].join('\n');
+
+describe('detectCostExportProvider', () => {
+  it('detects aws and gcp headers', () => {
+    expect(detectCostExportProvider(['lineItem/UnblendedCost'])).toBe('aws');
+    expect(detectCostExportProvider(['line_item_unblended_cost'])).toBe('aws');
+    expect(detectCostExportProvider(['cost', 'currency'])).toBe('gcp');
+    expect(detectCostExportProvider(['amount'])).toBeUndefined();
+  });
+});
+
+describe('parseCostExport', () => {
+  it('parses legacy AWS CUR tag columns', () => {
+    const items = parseCostExport(AWS_CUR);
+    expect(items).toHaveLength(2);
+    expect(items[0]).toEqual({
+      provider: 'aws',
+      cost: 12.5,
+      currency: 'USD',
+      tags: { app: 'checkout' },
+    });
+    expect(items[1].tags).toEqual({});
+  });
+
+  it('parses CUR 2.0 JSON resource tags', () => {
+    const items = parseCostExport(AWS_CUR2);
+    expect(items[0].tags).toEqual({ app: 'search' });
+  });
+
+  it('parses GCP label arrays', () => {
+    const items = parseCostExport(GCP_EXPORT);
+    expect(items[0]).toEqual({
+      provider: 'gcp',
+      cost: 7.5,
+      currency: 'EUR',
+      tags: { app: 'checkout' },
+    });
+  });
+
+  it('parses GCP flattened label columns', () => {
+    const items = parseCostExport('cost,labels.team\n1,payments', 'gcp');
+    expect(items[0].tags).toEqual({ team: 'payments' });
+  });
+
+  it('throws on unrecognized formats', () => {
+    expect(() => parseCostExport('amount\n1')).toThrow(
+      'Unrecognized cost export format',
+    );
+  });
+
+  it('returns no items for empty input', () => {
+    expect(parseCostExport('')).toEqual([]);
+  });
+});
@@ -0,0 +1,116 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { CloudResource } from '../../types/entity.js';
+import { buildCostReport } from '../cost-report.js';
+import type { CostLineItem } from '../types.js';
+
+function makeEntity(
+  name: string,
+  costCenter: string | undefined,
+  resources: readonly CloudResource[],
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'module',
+    description: `${name} module`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'platform-team',
+    status: 'stable',
+    metadata: {
+      type: 'module',
+      description: `${name} module`,
+      cost_center: costCenter,
+      cloud_resources: [...resources],
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+function item(cost: number, tags: Record<string, string>): CostLineItem {
+  return { provider: 'aws', cost, currency: 'USD', tags };
+}
+
+describe('buildCostReport', () => {
+  const checkout = makeEntity('checkout', 'CC-1', [
+    { provider: 'aws', tag: 'app', value: 'checkout' },
+  ]);
+  const search = makeEntity('search', 'CC-2', [
+    { provider: 'aws', tag: 'app', value: 'search' },
+  ]);
+
+  it('attributes spend by matching resource tags', () => {
+    const report = buildCostReport(
+      [checkout, search],
+      [item(10, { app: 'checkout' }), item(4, { app: 'search' }), item(1, {})],
+    );
+    expect(report.currency).toBe('USD');
+    expect(report.totalCost).toBe(15);
+    expect(report.unattributedCost).toBe(1);
+    expect(report.modules.map((m) => [m.name, m.cost])).toEqual([
+      ['checkout', 10],
+      ['search', 4],
+    ]);
+    expect(report.costCenters).toEqual([
+      { costCenter: 'CC-1', cost: 10 },
+      { costCenter: 'CC-2', cost: 4 },
+    ]);
+  });
+
+  it('splits shared line items evenly', () => {
+    const shared = makeEntity('shared', 'CC-1', [
+      { provider: 'aws', tag: 'team', value: 'payments' },
+    ]);
+    const other = makeEntity('other', 'CC-1', [
+      { provider: 'aws', tag: 'team', value: 'payments' },
+    ]);
+    const report = buildCostReport(
+      [shared, other],
+      [item(9, { team: 'payments' })],
+    );
+    expect(report.modules.every((m) => m.cost === 4.5)).toBe(true);
+    expect(report.costCenters).toEqual([{ costCenter: 'CC-1', cost: 9 }]);
+  });
+
+  it('ignores resources from a different provider', () => {
+    const gcpItem: CostLineItem = {
+      provider: 'gcp',
+      cost: 5,
+      tags: { app: 'checkout' },
+    };
+    const report = buildCostReport([checkout], [gcpItem]);
+    expect(report.modules[0].cost).toBe(0);
+    expect(report.unattributedCost).toBe(5);
+  });
+
+  it('refuses to total line items in different currencies', () => {
+    const euros: CostLineItem = { ...item(3, {}), currency: 'EUR' };
+    expect(() =>
+      buildCostReport([checkout], [item(10, { app: 'checkout' }), euros]),
+    ).toThrow(expect.objectContaining({ kind: 'usage' }));
+    expect(() => buildCostReport([checkout], [item(1, {}), euros])).toThrow(
+      'Cost line items mix currencies (USD, EUR)',
+    );
+  });
+
+  it('skips entities without cost attribution fields', () => {
+    const plain: StoredEntity = {
+      ...checkout,
+      id: 'plain',
+      metadata: { type: 'module', description: 'x' },
+    };
+    const report = buildCostReport([plain], []);
+    expect(report.modules).toEqual([]);
+    expect(report.currency).toBeNull();
+  });
+});
@@ -0,0 +1,37 @@
+import { describe, it, expect } from 'vitest';
+import { csvField, parseCsv } from '../csv.js';
+
+describe('parseCsv', () => {
+  it('splits simple rows and fields', () => {
+    expect(parseCsv('a,b\n1,2\n')).toEqual([
+      ['a', 'b'],
+      ['1', '2'],
+    ]);
+  });
+
+  it('handles quoted fields with commas, quotes and newlines', () => {
+    const rows = parseCsv('name,note\r\n"x, y","say ""hi""\nthere"\r\n');
+    expect(rows[1]).toEqual(['x, y', 'say "hi"\nthere']);
+  });
+
+  it('keeps empty fields and skips blank lines', () => {
+    expect(parseCsv('a,,c\n\n1,2,')).toEqual([
+      ['a', '', 'c'],
+      ['1', '2', ''],
+    ]);
+  });
+});
+
+describe('csvField', () => {
+  it('quotes fields with commas, quotes, and line breaks', () => {
+    expect(csvField('plain')).toBe('plain');
+    expect(csvField('say "hi"')).toBe('"say ""hi"""');
+    expect(csvField('a\rb')).toBe('"a\rb"');
+    expect(csvField('a\r\nb')).toBe('"a\r\nb"');
+  });
+
+  it('reads back unchanged with parseCsv', () => {
+    const fields = ['x, y', 'say "hi"', 'carriage\rreturn', 'two\nlines'];
+    expect(parseCsv(fields.map(csvField).join(','))).toEqual([fields]);
+  });
+});
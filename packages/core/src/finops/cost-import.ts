@@ -0,0 +1,144 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses AWS Cost and Usage Report and GCP billing export CSVs into tagged cost line items
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [finops, cost, aws, gcp, import]
+ * context:
+ *   business_goal: Normalize cloud billing exports so spend can be joined to code by resource tag
+ *   domain: finops
+ */
+import type { CloudProvider } from '../types/entity.js';
+import { parseCsv } from './csv.js';
+import type { CostLineItem } from './types.js';
+
+const AWS_COST_COLUMNS = ['lineItem/UnblendedCost', 'line_item_unblended_cost'];
+const AWS_CURRENCY_COLUMNS = [
+  'lineItem/CurrencyCode',
+  'line_item_currency_code',
+];
+const AWS_LEGACY_TAG_PREFIX = 'resourceTags/';
+const AWS_TAGS_JSON_COLUMN = 'resource_tags';
+
+const GCP_COST_COLUMNS = ['cost'];
+const GCP_CURRENCY_COLUMNS = ['currency'];
+const GCP_LABEL_PREFIX = 'labels.';
+const GCP_LABELS_JSON_COLUMN = 'labels';
+
+function findColumn(
+  header: readonly string[],
+  names: readonly string[],
+): number {
+  return header.findIndex((column) => names.includes(column));
+}
+
+/**
+ * Guess which provider produced a cost export from its header row.
+ * Returns undefined when the header matches neither format.
+ */
+export function detectCostExportProvider(
+  header: readonly string[],
+): CloudProvider | undefined {
+  if (findColumn(header, AWS_COST_COLUMNS) !== -1) return 'aws';
+  if (findColumn(header, GCP_COST_COLUMNS) !== -1) return 'gcp';
+  return undefined;
+}
+
+function stripAwsTagPrefix(key: string): string {
+  return key.replace(/^user[:_]/, '');
+}
+
+function parseJsonTags(raw: string | undefined): Record<string, string> {
+  if (!raw) return {};
+  try {
+    const parsed: unknown = JSON.parse(raw);
+    const tags: Record<string, string> = {};
+    if (Array.isArray(parsed)) {
+      for (const item of parsed) {
+        if (item && typeof item.key === 'string' && item.value !== undefined) {
+          tags[item.key] = String(item.value);
+        }
+      }
+    } else if (parsed && typeof parsed === 'object') {
+      for (const [key, value] of Object.entries(parsed)) {
+        if (value !== null && value !== undefined) tags[key] = String(value);
+      }
+    }
+    return tags;
+  } catch {
+    return {};
+  }
+}
+
+function extractTags(
+  provider: CloudProvider,
+  header: readonly string[],
+  row: readonly string[],
+): Record<string, string> {
+  const tags: Record<string, string> = {};
+
+  const jsonColumn =
+    provider === 'aws' ? AWS_TAGS_JSON_COLUMN : GCP_LABELS_JSON_COLUMN;
+  const jsonIndex = header.indexOf(jsonColumn);
+  if (jsonIndex !== -1) {
+    for (const [key, value] of Object.entries(parseJsonTags(row[jsonIndex]))) {
+      tags[provider === 'aws' ? stripAwsTagPrefix(key) : key] = value;
+    }
+  }
+
+  const prefix = provider === 'aws' ? AWS_LEGACY_TAG_PREFIX : GCP_LABEL_PREFIX;
+  header.forEach((column, index) => {
+    if (!column.startsWith(prefix)) return;
+    const value = row[index];
+    if (!value) return;
+    const key = column.slice(prefix.length);
+    tags[provider === 'aws' ? stripAwsTagPrefix(key) : key] = value;
+  });
+
+  return tags;
+}
+
+/**
+ * Parse a cost export CSV into line items. The provider is detected from the
+ * header when not given. Rows with a missing or non-numeric cost are skipped.
+ */
+export function parseCostExport(
+  text: string,
+  provider?: CloudProvider,
+): readonly CostLineItem[] {
+  const [header, ...rows] = parseCsv(text);
+  if (!header) return [];
+
+  const resolved = provider ?? detectCostExportProvider(header);
+  if (!resolved) {
+    throw new Error('Unrecognized cost export format: no cost column found');
+  }
+
+  const costIndex = findColumn(
+    header,
+    resolved === 'aws' ? AWS_COST_COLUMNS : GCP_COST_COLUMNS,
+  );
+  if (costIndex === -1) {
+    throw new Error(`Cost export is missing a cost column for ${resolved}`);
+  }
+  const currencyIndex = findColumn(
+    header,
+    resolved === 'aws' ? AWS_CURRENCY_COLUMNS : GCP_CURRENCY_COLUMNS,
+  );
+
+  const items: CostLineItem[] = [];
+  for (const row of rows) {
+    const raw = row[costIndex];
+    const cost = Number(raw);
+    if (!raw || !Number.isFinite(cost)) continue;
+    const currency = currencyIndex !== -1 ? row[currencyIndex] : undefined;
+    items.push({
+      provider: resolved,
+      cost,
+      currency: currency || undefined,
+      tags: extractTags(resolved, header, row),
+    });
+  }
+  return items;
+}
@@ -0,0 +1,149 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Joins cost line items to entities by cloud resource tag and rolls spend up per module and cost center
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [finops, cost, report, attribution]
+ * context:
+ *   business_goal: Produce per-module cost reports for FinOps reviews
+ *   domain: finops
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { CloudResource } from '../types/entity.js';
+import type {
+  CostCenterTotal,
+  CostLineItem,
+  CostReport,
+  ModuleCost,
+} from './types.js';
+
+const UNASSIGNED = '(no cost center)';
+
+interface EntityTotals {
+  readonly cost: number;
+  readonly lineItems: number;
+}
+
+interface Claim {
+  readonly entity: StoredEntity;
+  readonly costCenter: string | null;
+  readonly resources: readonly CloudResource[];
+}
+
+function round(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+function toClaim(entity: StoredEntity): Claim | undefined {
+  const { metadata } = entity;
+  const resources =
+    'cloud_resources' in metadata ? metadata.cloud_resources : undefined;
+  const costCenter =
+    'cost_center' in metadata ? metadata.cost_center ?? null : null;
+  if (!resources?.length && !costCenter) return undefined;
+  return { entity, costCenter, resources: resources ?? [] };
+}
+
+function matches(claim: Claim, item: CostLineItem): boolean {
+  return claim.resources.some(
+    (resource) =>
+      resource.provider === item.provider &&
+      item.tags[resource.tag] === resource.value,
+  );
+}
+
+/**
+ * The one currency `items` are in, or null when none says. Summing mixed
+ * currencies would give a meaningless total, so they throw a usage error.
+ */
+function reportCurrency(items: readonly CostLineItem[]): string | null {
+  const currencies = [
+    ...new Set(items.flatMap((item) => (item.currency ? [item.currency] : []))),
+  ];
+  if (currencies.length > 1) {
+    throw createKnowgraphError(
+      'usage',
+      `Cost line items mix currencies (${currencies.join(', ')}); convert them to one currency before reporting`,
+    );
+  }
+  return currencies[0] ?? null;
+}
+
+/**
+ * Attribute each line item to the entities whose `cloud_resources` match its
+ * tags. A line item claimed by several entities is split evenly so totals are
+ * never double counted; unmatched spend is reported as unattributed. Throws
+ * a usage error when the items are in more than one currency.
+ */
+export function buildCostReport(
+  entities: readonly StoredEntity[],
+  items: readonly CostLineItem[],
+): CostReport {
+  const claims = entities
+    .map(toClaim)
+    .filter((claim): claim is Claim => claim !== undefined);
+
+  const currency = reportCurrency(items);
+  const costs = new Map<string, EntityTotals>();
+  for (const claim of claims) {
+    costs.set(claim.entity.id, { cost: 0, lineItems: 0 });
+  }
+
+  let totalCost = 0;
+  let unattributedCost = 0;
+
+  for (const item of items) {
+    totalCost += item.cost;
+
+    const owners = claims.filter((claim) => matches(claim, item));
+    if (owners.length === 0) {
+      unattributedCost += item.cost;
+      continue;
+    }
+    const share = item.cost / owners.length;
+    for (const owner of owners) {
+      const entry = costs.get(owner.entity.id) ?? { cost: 0, lineItems: 0 };
+      costs.set(owner.entity.id, {
+        cost: entry.cost + share,
+        lineItems: entry.lineItems + 1,
+      });
+    }
+  }
+
+  const modules: ModuleCost[] = claims
+    .map((claim) => {
+      const entry = costs.get(claim.entity.id) ?? { cost: 0, lineItems: 0 };
+      return {
+        entityId: claim.entity.id,
+        name: claim.entity.name,
+        entityType: claim.entity.entityType,
+        filePath: claim.entity.filePath,
+        owner: claim.entity.owner,
+        costCenter: claim.costCenter,
+        cost: round(entry.cost),
+        lineItems: entry.lineItems,
+      };
+    })
+    .sort((a, b) => b.cost - a.cost || a.name.localeCompare(b.name));
+
+  const centerTotals = new Map<string, number>();
+  for (const mod of modules) {
+    const key = mod.costCenter ?? UNASSIGNED;
+    centerTotals.set(key, (centerTotals.get(key) ?? 0) + mod.cost);
+  }
+  const costCenters: CostCenterTotal[] = [...centerTotals.entries()]
+    .map(([costCenter, cost]) => ({ costCenter, cost: round(cost) }))
+    .sort(
+      (a, b) => b.cost - a.cost || a.costCenter.localeCompare(b.costCenter),
+    );
+
+  return {
+    currency,
+    modules,
+    costCenters,
+    totalCost: round(totalCost),
+    unattributedCost: round(unattributedCost),
+  };
+}
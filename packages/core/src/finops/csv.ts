@@ -0,0 +1,65 @@
+/**
+ * @knowgraph
+ * type: function
+ * description: Minimal RFC 4180 CSV parser supporting quoted fields and embedded newlines, and the field quoting CSV reports are written with
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [finops, csv, parser]
+ * context:
+ *   business_goal: Read cloud billing exports without adding a CSV dependency
+ *   domain: finops
+ */
+
+/**
+ * `value` as one CSV field, quoted when it holds a comma, quote, or line
+ * break, so spreadsheets and `parseCsv` read it back unchanged.
+ */
+export function csvField(value: string): string {
+  return /[",\r\n]/.test(value) ? `"${value.replace(/"/g, '""')}"` : value;
+}
+
+/**
+ * Parse CSV text into rows of fields. Handles quoted fields, doubled quotes,
+ * CRLF line endings and newlines inside quotes. Blank lines are skipped.
+ */
+export function parseCsv(text: string): readonly (readonly string[])[] {
+  const rows: string[][] = [];
+  let row: string[] = [];
+  let field = '';
+  let inQuotes = false;
+
+  const endRow = (): void => {
+    row.push(field);
+    if (row.length > 1 || row[0] !== '') rows.push(row);
+    row = [];
+    field = '';
+  };
+
+  for (let i = 0; i < text.length; i++) {
+    const ch = text[i];
+    if (inQuotes) {
+      if (ch === '"') {
+        if (text[i + 1] === '"') {
+          field += '"';
+          i++;
+        } else {
+          inQuotes = false;
+        }
+      } else {
+        field += ch;
+      }
+    } else if (ch === '"') {
+      inQuotes = true;
+    } else if (ch === ',') {
+      row.push(field);
+      field = '';
+    } else if (ch === '\n') {
+      endRow();
+    } else if (ch !== '\r') {
+      field += ch;
+    }
+  }
+
+  if (field !== '' || row.length > 0) endRow();
+  return rows;
+}
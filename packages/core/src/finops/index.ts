@@ -0,0 +1,9 @@
+export type {
+  CostLineItem,
+  ModuleCost,
+  CostCenterTotal,
+  CostReport,
+} from './types.js';
+export { csvField, parseCsv } from './csv.js';
+export { detectCostExportProvider, parseCostExport } from './cost-import.js';
+export { buildCostReport } from './cost-report.js';
@@ -0,0 +1,43 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for cloud cost export line items and per-module cost reports
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [finops, cost, types, interface]
+ * context:
+ *   business_goal: Define contracts for attributing cloud spend to annotated code
+ *   domain: finops
+ */
+import type { CloudProvider, EntityType } from '../types/entity.js';
+
+export interface CostLineItem {
+  readonly provider: CloudProvider;
+  readonly cost: number;
+  readonly currency?: string;
+  readonly tags: Readonly<Record<string, string>>;
+}
+
+export interface ModuleCost {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly owner: string | null;
+  readonly costCenter: string | null;
+  readonly cost: number;
+  readonly lineItems: number;
+}
+
+export interface CostCenterTotal {
+  readonly costCenter: string;
+  readonly cost: number;
+}
+
+export interface CostReport {
+  readonly currency: string | null;
+  readonly modules: readonly ModuleCost[];
+  readonly costCenters: readonly CostCenterTotal[];
+  readonly totalCost: number;
+  readonly unattributedCost: number;
+}
@@ -0,0 +1,130 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import {
+  addMonths,
+  buildFreshnessReport,
+  describeDue,
+  freshnessDigest,
+} from '../freshness.js';
+
+const NOW = new Date('2024-06-15T12:00:00Z');
+
+function makeEntity(
+  name: string,
+  metadata: Partial<ExtendedMetadata>,
+  owner: string | null = 'payments-team',
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner,
+    status: null,
+    metadata: {
+      type: 'service',
+      description: `${name} service`,
+      ...metadata,
+    } as ExtendedMetadata,
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+describe('addMonths', () => {
+  it('adds months, clamping to the end of a shorter month', () => {
+    expect(addMonths('2024-01-15', 3)).toBe('2024-04-15');
+    expect(addMonths('2024-01-31', 3)).toBe('2024-04-30');
+    expect(addMonths('2023-11-30', 3)).toBe('2024-02-29');
+    expect(addMonths('2024-08-01', 6)).toBe('2025-02-01');
+  });
+});
+
+describe('buildFreshnessReport', () => {
+  const entities = [
+    makeEntity('checkout', {
+      context: { revenue_impact: 'critical' },
+      last_reviewed: '2024-01-10',
+    }),
+    makeEntity('ledger', {
+      context: { revenue_impact: 'critical' },
+      last_reviewed: '2024-01-01',
+      git: { last_modified: '2024-04-01T09:00:00Z' },
+    }),
+    makeEntity('billing', { context: { revenue_impact: 'high' } }, null),
+    makeEntity('search', {
+      context: { revenue_impact: 'high' },
+      last_reviewed: '2024-05-01',
+    }),
+    makeEntity('docs', { context: { revenue_impact: 'low' } }),
+  ];
+
+  it('measures each deadline from the latest review or commit', () => {
+    const report = buildFreshnessReport(entities, { now: NOW });
+    expect(report.overdue).toBe(2);
+    expect(report.dueSoon).toBe(1);
+    expect(
+      report.entries.map((entry) => [entry.name, entry.state, entry.dueAt]),
+    ).toEqual([
+      ['billing', 'overdue', null],
+      ['checkout', 'overdue', '2024-04-10'],
+      ['ledger', 'due-soon', '2024-07-01'],
+      ['search', 'current', '2024-11-01'],
+    ]);
+    expect(report.entries[2].reviewedFrom).toBe('git');
+    expect(report.entries[3].reviewedFrom).toBe('last_reviewed');
+  });
+
+  it('uses configured deadlines and reminder window', () => {
+    const report = buildFreshnessReport(entities, {
+      deadlines: { low: 12 },
+      remindDays: 0,
+      now: NOW,
+    });
+    expect(report.entries.map((entry) => entry.name)).toEqual(['docs']);
+    expect(report.entries[0].state).toBe('overdue');
+  });
+
+  it('describes when an entry is due', () => {
+    const [billing, checkout, ledger] = buildFreshnessReport(entities, {
+      now: NOW,
+    }).entries;
+    expect(describeDue(billing)).toBe('never reviewed');
+    expect(describeDue(checkout)).toBe('overdue since 2024-04-10 (66 days)');
+    expect(describeDue(ledger)).toBe('due 2024-07-01 (in 16 days)');
+  });
+});
+
+describe('freshnessDigest', () => {
+  it('groups entries due by owner', () => {
+    const report = buildFreshnessReport(
+      [
+        makeEntity('checkout', { context: { revenue_impact: 'critical' } }),
+        makeEntity('billing', { context: { revenue_impact: 'high' } }, null),
+      ],
+      { now: NOW },
+    );
+    const digest = freshnessDigest(report);
+    expect(digest.event).toBe('knowgraph.freshness');
+    expect(digest.subject).toBe(
+      '2 annotations overdue for review, 0 due soon',
+    );
+    expect(digest.lines).toEqual([
+      'No owner',
+      '  billing (src/billing.ts): never reviewed',
+      'payments-team',
+      '  checkout (src/checkout.ts): never reviewed',
+    ]);
+  });
+});
@@ -0,0 +1,186 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Measures how long ago each annotation was reviewed against a deadline per revenue impact, and builds the reminder digest of nodes due
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [freshness, review, sla, digest]
+ * context:
+ *   business_goal: Review the most revenue-critical annotations most often
+ *   domain: freshness
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type { AlertMessage } from '../history/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { ExtendedMetadata } from '../types/entity.js';
+import type {
+  FreshnessEntry,
+  FreshnessOptions,
+  FreshnessReport,
+  FreshnessState,
+  ReviewDeadlines,
+  ReviewSource,
+} from './types.js';
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+export const DEFAULT_REVIEW_DEADLINES: ReviewDeadlines = {
+  critical: 3,
+  high: 6,
+};
+
+export const DEFAULT_REMIND_DAYS = 30;
+
+/**
+ * `date` (YYYY-MM-DD) plus `months`, on the last day of the month when
+ * it is shorter, so a review on January 31 is due on the last of April.
+ */
+export function addMonths(date: string, months: number): string {
+  const [year, month, day] = date.split('-').map(Number);
+  const target = new Date(Date.UTC(year, month - 1 + months, 1));
+  const lastDay = new Date(
+    Date.UTC(target.getUTCFullYear(), target.getUTCMonth() + 1, 0),
+  ).getUTCDate();
+  target.setUTCDate(Math.min(day, lastDay));
+  return target.toISOString().slice(0, 10);
+}
+
+/** The UTC date of an ISO 8601 time; undefined when it is not one. */
+function dateOf(time: string | undefined): string | undefined {
+  const parsed = Date.parse(time ?? '');
+  return Number.isNaN(parsed)
+    ? undefined
+    : new Date(parsed).toISOString().slice(0, 10);
+}
+
+/**
+ * When the entity was last reviewed: the later of its `last_reviewed`
+ * date and its file's last commit, since changing the code means reading
+ * the annotation above it.
+ */
+function lastReview(
+  metadata: ExtendedMetadata,
+): { readonly at: string; readonly from: ReviewSource } | undefined {
+  const reviewed = dateOf(metadata.last_reviewed);
+  const committed = dateOf(metadata.git?.last_modified);
+  if (committed && (!reviewed || committed > reviewed)) {
+    return { at: committed, from: 'git' };
+  }
+  return reviewed ? { at: reviewed, from: 'last_reviewed' } : undefined;
+}
+
+const STATE_ORDER: Readonly<Record<FreshnessState, number>> = {
+  overdue: 0,
+  'due-soon': 1,
+  current: 2,
+};
+
+function compareEntries(a: FreshnessEntry, b: FreshnessEntry): number {
+  return (
+    STATE_ORDER[a.state] - STATE_ORDER[b.state] ||
+    compareStrings(a.dueAt ?? '', b.dueAt ?? '') ||
+    compareStrings(a.filePath, b.filePath) ||
+    a.line - b.line
+  );
+}
+
+/**
+ * Measure each entity with a review deadline for its
+ * `context.revenue_impact` against that deadline. An entity was reviewed
+ * when its `last_reviewed` date says so or its file last changed,
+ * whichever is later, and without either it is overdue.
+ */
+export function buildFreshnessReport(
+  entities: readonly StoredEntity[],
+  options: FreshnessOptions = {},
+): FreshnessReport {
+  const {
+    deadlines = DEFAULT_REVIEW_DEADLINES,
+    remindDays = DEFAULT_REMIND_DAYS,
+    now = new Date(),
+  } = options;
+  const today = Date.parse(now.toISOString().slice(0, 10));
+  const entries: FreshnessEntry[] = [];
+
+  for (const entity of entities) {
+    const metadata = entity.metadata as ExtendedMetadata;
+    const revenueImpact = metadata.context?.revenue_impact ?? null;
+    const months = deadlines[revenueImpact ?? 'unset'];
+    if (months === undefined) continue;
+    const review = lastReview(metadata);
+    const dueAt = review ? addMonths(review.at, months) : null;
+    const daysLeft = dueAt
+      ? Math.round((Date.parse(dueAt) - today) / DAY_MS)
+      : null;
+    const state: FreshnessState =
+      daysLeft === null || daysLeft < 0
+        ? 'overdue'
+        : daysLeft <= remindDays
+          ? 'due-soon'
+          : 'current';
+    entries.push({
+      entityId: entity.id,
+      name: entity.name,
+      entityType: entity.entityType,
+      filePath: entity.filePath,
+      line: entity.line,
+      owner: entity.owner,
+      revenueImpact,
+      months,
+      reviewedAt: review?.at ?? null,
+      reviewedFrom: review?.from ?? null,
+      dueAt,
+      daysLeft,
+      state,
+    });
+  }
+
+  entries.sort(compareEntries);
+  const count = (state: FreshnessState): number =>
+    entries.filter((entry) => entry.state === state).length;
+  return {
+    generatedAt: now.toISOString(),
+    entries,
+    overdue: count('overdue'),
+    dueSoon: count('due-soon'),
+  };
+}
+
+/** When `entry` is due, in words. */
+export function describeDue(entry: FreshnessEntry): string {
+  if (entry.daysLeft === null) return 'never reviewed';
+  if (entry.daysLeft < 0) {
+    return `overdue since ${entry.dueAt} (${-entry.daysLeft} days)`;
+  }
+  return `due ${entry.dueAt} (in ${entry.daysLeft} days)`;
+}
+
+/**
+ * The reminder digest: entities overdue or due soon, grouped by owner, as
+ * an alert message for the `history.sinks` alert sinks.
+ */
+export function freshnessDigest(report: FreshnessReport): AlertMessage {
+  const due = report.entries.filter((entry) => entry.state !== 'current');
+  const owners = [...new Set(due.map((entry) => entry.owner ?? ''))].sort(
+    compareStrings,
+  );
+  const lines = owners.flatMap((owner) => [
+    owner || 'No owner',
+    ...due
+      .filter((entry) => (entry.owner ?? '') === owner)
+      .map(
+        (entry) =>
+          `  ${entry.name} (${entry.filePath}): ${describeDue(entry)}`,
+      ),
+  ]);
+  return {
+    event: 'knowgraph.freshness',
+    subject: `${report.overdue} annotations overdue for review, ${report.dueSoon} due soon`,
+    lines,
+    data: {
+      overdue: report.overdue,
+      dueSoon: report.dueSoon,
+      entries: due,
+    },
+  };
+}
@@ -0,0 +1,16 @@
+export type {
+  ReviewDeadlines,
+  FreshnessState,
+  ReviewSource,
+  FreshnessEntry,
+  FreshnessReport,
+  FreshnessOptions,
+} from './types.js';
+export {
+  DEFAULT_REMIND_DAYS,
+  DEFAULT_REVIEW_DEADLINES,
+  addMonths,
+  buildFreshnessReport,
+  describeDue,
+  freshnessDigest,
+} from './freshness.js';
@@ -0,0 +1,66 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for annotation review deadlines per revenue impact and the report of nodes overdue or due soon
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [freshness, review, sla, types, interface]
+ * context:
+ *   business_goal: Define contracts for keeping annotations on critical code from going stale
+ *   domain: freshness
+ */
+import type { EntityType, RevenueImpact } from '../types/entity.js';
+
+/**
+ * Months allowed between reviews per revenue impact; `unset` applies to
+ * entities without one. Impacts left out have no deadline.
+ */
+export type ReviewDeadlines = Readonly<
+  Partial<Record<RevenueImpact | 'unset', number>>
+>;
+
+/**
+ * `overdue` once the deadline has passed, or when the annotation was
+ * never reviewed; `due-soon` within the reminder window before it.
+ */
+export type FreshnessState = 'overdue' | 'due-soon' | 'current';
+
+/** `last_reviewed` in the annotation, or the git enricher's last commit. */
+export type ReviewSource = 'last_reviewed' | 'git';
+
+export interface FreshnessEntry {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly line: number;
+  readonly owner: string | null;
+  readonly revenueImpact: RevenueImpact | null;
+  /** Months allowed between reviews. */
+  readonly months: number;
+  /** Date (YYYY-MM-DD) of the latest review; null when never reviewed. */
+  readonly reviewedAt: string | null;
+  readonly reviewedFrom: ReviewSource | null;
+  /** Date the next review is due; null when never reviewed. */
+  readonly dueAt: string | null;
+  /** Days until `dueAt`, negative once past; null when never reviewed. */
+  readonly daysLeft: number | null;
+  readonly state: FreshnessState;
+}
+
+export interface FreshnessReport {
+  /** ISO 8601 time the deadlines were measured against. */
+  readonly generatedAt: string;
+  /** Entities with a deadline: overdue first, then by due date. */
+  readonly entries: readonly FreshnessEntry[];
+  readonly overdue: number;
+  readonly dueSoon: number;
+}
+
+export interface FreshnessOptions {
+  /** Defaults to `DEFAULT_REVIEW_DEADLINES`. */
+  readonly deadlines?: ReviewDeadlines;
+  /** Days before a deadline an entity counts as due soon. Default 30. */
+  readonly remindDays?: number;
+  readonly now?: Date;
+}
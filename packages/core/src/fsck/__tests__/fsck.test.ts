@@ -0,0 +1,159 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, unlinkSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import { createIndexer } from '../../indexer/indexer.js';
+import type { ParserRegistry } from '../../indexer/indexer.js';
+import type { ParseResult } from '../../types/index.js';
+import { checkIndex, repairIndex } from '../fsck.js';
+
+function createTempDir(): string {
+  const dir = join(
+    tmpdir(),
+    `knowgraph-fsck-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+  );
+  mkdirSync(join(dir, 'src'), { recursive: true });
+  return dir;
+}
+
+function parsed(name: string): ParseResult {
+  return {
+    name,
+    filePath: `src/${name}.ts`,
+    line: 1,
+    column: 0,
+    language: 'typescript',
+    entityType: 'service',
+    metadata: { type: 'service', description: `The ${name} service` },
+    rawDocstring: '// @knowgraph',
+  };
+}
+
+// Files containing "@knowgraph" parse to one service named after the file
+const parserRegistry: ParserRegistry = {
+  canParse: (filePath) => filePath.endsWith('.ts'),
+  parse: (filePath, content) => {
+    const name = filePath.split('/').pop()?.replace('.ts', '') ?? '';
+    return content.includes('@knowgraph') ? [parsed(name)] : [];
+  },
+};
+
+describe('checkIndex', () => {
+  let dbManager: DatabaseManager;
+  let rootDir: string;
+
+  beforeEach(() => {
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+    rootDir = createTempDir();
+    writeFileSync(join(rootDir, 'src/checkout.ts'), '// @knowgraph v1');
+    writeFileSync(join(rootDir, 'src/refund.ts'), '// @knowgraph v1');
+    createIndexer(parserRegistry, dbManager).index({ rootDir });
+  });
+
+  afterEach(() => {
+    dbManager.close();
+    rmSync(rootDir, { recursive: true, force: true });
+  });
+
+  it('finds nothing in a freshly built index', () => {
+    expect(checkIndex(dbManager, parserRegistry, { rootDir })).toEqual({
+      filesChecked: 2,
+      issues: [],
+    });
+  });
+
+  it('reports changed, deleted, and unindexed files', () => {
+    writeFileSync(join(rootDir, 'src/checkout.ts'), '// @knowgraph v2');
+    unlinkSync(join(rootDir, 'src/refund.ts'));
+    writeFileSync(join(rootDir, 'src/ledger.ts'), '// @knowgraph v1');
+    writeFileSync(join(rootDir, 'src/plain.ts'), '// nothing here');
+
+    const report = checkIndex(dbManager, parserRegistry, { rootDir });
+    expect(report.filesChecked).toBe(3);
+    expect(report.issues.map((found) => [found.kind, found.subject])).toEqual([
+      ['stale', 'src/checkout.ts'],
+      ['unindexed', 'src/ledger.ts'],
+      ['missing', 'src/refund.ts'],
+    ]);
+  });
+
+  it('reports dangling rows and entities missing from search', () => {
+    dbManager.db.pragma('foreign_keys = OFF');
+    dbManager.db
+      .prepare("INSERT INTO tags (entity_id, tag) VALUES ('gone', 'x')")
+      .run();
+    dbManager.db
+      .prepare(
+        "DELETE FROM entities_fts WHERE entity_id IN (SELECT id FROM entities WHERE file_path = 'src/refund.ts')",
+      )
+      .run();
+
+    const report = checkIndex(dbManager, parserRegistry, { rootDir });
+    expect(report.issues.map((found) => [found.kind, found.subject])).toEqual([
+      ['dangling', 'tags'],
+      ['search', 'src/refund.ts'],
+    ]);
+  });
+
+  it('reports a configuration the index was not built with', () => {
+    const report = checkIndex(dbManager, parserRegistry, {
+      rootDir,
+      configHash: 'changed',
+    });
+    expect(report.issues).toEqual([
+      {
+        kind: 'setup',
+        subject: 'config_hash',
+        message: 'Configuration changed since the index was built',
+        repairable: true,
+      },
+    ]);
+  });
+});
+
+describe('repairIndex', () => {
+  let dbManager: DatabaseManager;
+  let rootDir: string;
+
+  beforeEach(() => {
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+    rootDir = createTempDir();
+    writeFileSync(join(rootDir, 'src/checkout.ts'), '// @knowgraph v1');
+    writeFileSync(join(rootDir, 'src/refund.ts'), '// @knowgraph v1');
+    createIndexer(parserRegistry, dbManager).index({ rootDir });
+  });
+
+  afterEach(() => {
+    dbManager.close();
+    rmSync(rootDir, { recursive: true, force: true });
+  });
+
+  it('clears divergent files so a re-index leaves nothing to report', () => {
+    writeFileSync(join(rootDir, 'src/checkout.ts'), '// @knowgraph v2');
+    unlinkSync(join(rootDir, 'src/refund.ts'));
+    dbManager.db.pragma('foreign_keys = OFF');
+    dbManager.db
+      .prepare("INSERT INTO tags (entity_id, tag) VALUES ('gone', 'x')")
+      .run();
+
+    const report = checkIndex(dbManager, parserRegistry, { rootDir });
+    expect(repairIndex(dbManager, report)).toEqual({
+      filesCleared: ['src/checkout.ts', 'src/refund.ts'],
+      rowsDeleted: 1,
+    });
+    expect(dbManager.getFilePaths()).toEqual([]);
+
+    createIndexer(parserRegistry, dbManager).index({
+      rootDir,
+      incremental: true,
+    });
+    expect(checkIndex(dbManager, parserRegistry, { rootDir }).issues).toEqual(
+      [],
+    );
+    expect(dbManager.getFilePaths()).toEqual(['src/checkout.ts']);
+  });
+});
@@ -0,0 +1,328 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Checks the local index against a fresh scan of source and its own referential integrity, and clears what diverged
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [fsck, indexer, consistency, integrity, repair]
+ * context:
+ *   business_goal: Repair a damaged index in place rather than forcing a full rebuild
+ *   domain: indexer-engine
+ */
+import { existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import type { DatabaseManager } from '../indexer/database.js';
+import {
+  DEFAULT_INDEX_EXCLUDE,
+  indexedFileHash,
+  listIndexableFiles,
+  readIndexedScopes,
+  readSidecar,
+  readSlowFiles,
+} from '../indexer/indexer.js';
+import type { ParserRegistry } from '../indexer/indexer.js';
+import { readLimitedFile, resolveFileLimits } from '../indexer/safeguards.js';
+import { INDEX_SCHEMA_VERSION } from '../indexer/schema.js';
+import { formatScope, tagsInScope } from '../scope/scope.js';
+import type { ScanScope } from '../scope/types.js';
+import type {
+  FsckIssue,
+  FsckIssueKind,
+  FsckOptions,
+  FsckRepair,
+  FsckReport,
+} from './types.js';
+
+/** Issues about a file, fixed by dropping its entities and re-indexing it. */
+const FILE_KINDS: ReadonlySet<FsckIssueKind> = new Set([
+  'stale',
+  'missing',
+  'search',
+]);
+
+/** Rows that point at an entity, and the columns that do the pointing. */
+const REFERENCES: readonly (readonly [string, readonly string[]])[] = [
+  ['relationships', ['source_id', 'target_id']],
+  ['tags', ['entity_id']],
+  ['links', ['entity_id']],
+  ['entities_fts', ['entity_id']],
+];
+
+function danglingWhere(columns: readonly string[]): string {
+  return columns
+    .map((column) => `${column} NOT IN (SELECT id FROM entities)`)
+    .join(' OR ');
+}
+
+function issue(
+  kind: FsckIssueKind,
+  subject: string,
+  message: string,
+  repairable = true,
+): FsckIssue {
+  return { kind, subject, message, repairable };
+}
+
+function checkSetup(
+  dbManager: DatabaseManager,
+  options: FsckOptions,
+  scopes: readonly ScanScope[],
+): readonly FsckIssue[] {
+  const schema = dbManager.getMeta('schema_version');
+  if (schema === undefined) return [];
+  const issues: FsckIssue[] = [];
+  if (schema !== String(INDEX_SCHEMA_VERSION)) {
+    issues.push(
+      issue(
+        'setup',
+        'schema_version',
+        `Index schema is version ${schema}; this knowgraph writes ${INDEX_SCHEMA_VERSION}`,
+      ),
+    );
+  }
+  const configHash = dbManager.getMeta('config_hash');
+  if (options.configHash !== undefined && configHash !== options.configHash) {
+    issues.push(
+      issue(
+        'setup',
+        'config_hash',
+        'Configuration changed since the index was built',
+      ),
+    );
+  }
+  const scopeKey = scopes.map(formatScope).sort().join(' ');
+  if ((dbManager.getMeta('scopes') ?? '') !== scopeKey) {
+    issues.push(
+      issue(
+        'setup',
+        'scopes',
+        `Index was built for scope '${dbManager.getMeta('scopes') || 'all'}', not '${scopeKey || 'all'}'`,
+      ),
+    );
+  }
+  return issues;
+}
+
+interface HashRow {
+  readonly file_path: string;
+  readonly file_hash: string | null;
+}
+
+function storedHashes(
+  dbManager: DatabaseManager,
+): ReadonlyMap<string, ReadonlySet<string | null>> {
+  const rows = dbManager.db
+    .prepare(
+      'SELECT DISTINCT file_path, file_hash FROM entities ORDER BY file_path',
+    )
+    .all() as readonly HashRow[];
+  const hashes = new Map<string, Set<string | null>>();
+  for (const row of rows) {
+    const current = hashes.get(row.file_path) ?? new Set();
+    current.add(row.file_hash);
+    hashes.set(row.file_path, current);
+  }
+  return hashes;
+}
+
+/** Whether any entity the parser finds in an unstored file would be kept. */
+function hasIndexableEntities(
+  parserRegistry: ParserRegistry,
+  absPath: string,
+  content: string,
+  scopes: readonly ScanScope[],
+): boolean {
+  try {
+    return parserRegistry
+      .parse(absPath, content)
+      .some((result) => tagsInScope(result.metadata.tags, scopes));
+  } catch {
+    // A file that fails to parse is an indexing error, not a divergence
+    return false;
+  }
+}
+
+function checkFiles(
+  dbManager: DatabaseManager,
+  parserRegistry: ParserRegistry,
+  options: FsckOptions,
+  scopes: readonly ScanScope[],
+): { readonly filesChecked: number; readonly issues: readonly FsckIssue[] } {
+  const { rootDir, exclude = DEFAULT_INDEX_EXCLUDE } = options;
+  const files = [
+    ...listIndexableFiles(rootDir, parserRegistry, exclude, scopes, options),
+  ].sort(compareStrings);
+  const stored = storedHashes(dbManager);
+  const limits = resolveFileLimits(options);
+  const slow = readSlowFiles(dbManager);
+  const issues: FsckIssue[] = [];
+  // Files the index skips on purpose are neither unindexed nor missing
+  const skipped = new Set<string>();
+
+  for (const relPath of files) {
+    const absPath = join(rootDir, relPath);
+    let content: string;
+    try {
+      const read = readLimitedFile(absPath, relPath, limits);
+      if ('skipped' in read) {
+        skipped.add(relPath);
+        continue;
+      }
+      content = read.content;
+    } catch {
+      continue;
+    }
+    const hash = indexedFileHash(content, readSidecar(rootDir, relPath));
+    if (slow.get(relPath)?.hash === hash) {
+      skipped.add(relPath);
+      continue;
+    }
+    const hashes = stored.get(relPath);
+    if (hashes === undefined) {
+      if (hasIndexableEntities(parserRegistry, absPath, content, scopes)) {
+        issues.push(
+          issue('unindexed', relPath, 'Has annotations but is not indexed'),
+        );
+      }
+      continue;
+    }
+    if (hashes.size > 1) {
+      issues.push(
+        issue(
+          'stale',
+          relPath,
+          'Entities were stored from different versions of the file',
+        ),
+      );
+    } else if (!hashes.has(hash)) {
+      issues.push(issue('stale', relPath, 'Changed since it was indexed'));
+    }
+  }
+
+  const scanned = new Set(files);
+  for (const relPath of stored.keys()) {
+    if (scanned.has(relPath) && !skipped.has(relPath)) continue;
+    issues.push(
+      issue(
+        'missing',
+        relPath,
+        existsSync(join(rootDir, relPath))
+          ? 'Indexed, but now excluded, out of scope, skipped, or unparsable'
+          : 'Indexed, but the file no longer exists',
+      ),
+    );
+  }
+
+  return { filesChecked: files.length, issues };
+}
+
+function checkReferences(dbManager: DatabaseManager): readonly FsckIssue[] {
+  const issues: FsckIssue[] = [];
+  for (const [table, columns] of REFERENCES) {
+    const { count } = dbManager.db
+      .prepare(
+        `SELECT COUNT(*) AS count FROM ${table} WHERE ${danglingWhere(columns)}`,
+      )
+      .get() as { readonly count: number };
+    if (count > 0) {
+      issues.push(
+        issue(
+          'dangling',
+          table,
+          `${count} row(s) refer to entities that do not exist`,
+        ),
+      );
+    }
+  }
+  const unsearchable = dbManager.db
+    .prepare(
+      `SELECT DISTINCT file_path FROM entities
+       WHERE id NOT IN (SELECT entity_id FROM entities_fts)
+       ORDER BY file_path`,
+    )
+    .all() as readonly { readonly file_path: string }[];
+  for (const row of unsearchable) {
+    issues.push(
+      issue(
+        'search',
+        row.file_path,
+        'Has entities missing from the full-text index',
+      ),
+    );
+  }
+  return issues;
+}
+
+/**
+ * Compare the index in `dbManager` with a fresh scan of `options.rootDir`,
+ * file by file by content hash, then check that every stored row refers to
+ * an entity that exists. A store that fails SQLite's integrity check is
+ * reported as corrupt and not checked further.
+ */
+export function checkIndex(
+  dbManager: DatabaseManager,
+  parserRegistry: ParserRegistry,
+  options: FsckOptions,
+): FsckReport {
+  const integrity = dbManager.db.pragma('integrity_check') as readonly {
+    readonly integrity_check: string;
+  }[];
+  const problems = integrity
+    .map((row) => row.integrity_check)
+    .filter((message) => message !== 'ok');
+  if (problems.length > 0) {
+    return {
+      filesChecked: 0,
+      issues: problems.map((message) =>
+        issue('corrupt', 'database', message, false),
+      ),
+    };
+  }
+
+  const scopes = options.scopes ?? readIndexedScopes(dbManager);
+  const files = checkFiles(dbManager, parserRegistry, options, scopes);
+  return {
+    filesChecked: files.filesChecked,
+    issues: [
+      ...checkSetup(dbManager, options, scopes),
+      ...files.issues,
+      ...checkReferences(dbManager),
+    ],
+  };
+}
+
+/**
+ * Undo the divergence `report` found: drop the entities of stale, missing,
+ * and unsearchable files and delete dangling rows. Re-indexing afterwards
+ * restores cleared files, indexes unindexed ones, and rebuilds the index
+ * when its setup changed.
+ */
+export function repairIndex(
+  dbManager: DatabaseManager,
+  report: FsckReport,
+): FsckRepair {
+  const files = [
+    ...new Set(
+      report.issues
+        .filter((found) => found.repairable && FILE_KINDS.has(found.kind))
+        .map((found) => found.subject),
+    ),
+  ];
+  const dangling = report.issues.some(
+    (found) => found.repairable && found.kind === 'dangling',
+  );
+  let rowsDeleted = 0;
+  dbManager.db.transaction(() => {
+    for (const filePath of files) {
+      dbManager.deleteEntitiesByFilePath(filePath);
+    }
+    if (!dangling) return;
+    for (const [table, columns] of REFERENCES) {
+      rowsDeleted += dbManager.db
+        .prepare(`DELETE FROM ${table} WHERE ${danglingWhere(columns)}`)
+        .run().changes;
+    }
+  })();
+  return { filesCleared: files, rowsDeleted };
+}
@@ -0,0 +1,8 @@
+export { checkIndex, repairIndex } from './fsck.js';
+export type {
+  FsckIssue,
+  FsckIssueKind,
+  FsckOptions,
+  FsckRepair,
+  FsckReport,
+} from './types.js';
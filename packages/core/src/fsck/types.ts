@@ -0,0 +1,65 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for index consistency checks, the divergences between the local store and source
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [fsck, indexer, consistency, types, interface]
+ * context:
+ *   business_goal: Let scripts tell which kind of index divergence a check found
+ *   domain: indexer-engine
+ */
+import type { FileLimits, WalkOptions } from '../indexer/types.js';
+import type { ScanScope } from '../scope/types.js';
+
+/**
+ * How the store disagrees with source or with itself:
+ * - `stale`: a file changed since its entities were stored
+ * - `missing`: entities are stored for a file the scan no longer reads
+ * - `unindexed`: a file has annotations but nothing is stored for it
+ * - `setup`: the schema, configuration, or scopes changed since the index
+ * - `dangling`: rows refer to entities that do not exist
+ * - `search`: the full-text index and the entities table disagree
+ * - `corrupt`: SQLite's own integrity check failed
+ */
+export type FsckIssueKind =
+  | 'stale'
+  | 'missing'
+  | 'unindexed'
+  | 'setup'
+  | 'dangling'
+  | 'search'
+  | 'corrupt';
+
+export interface FsckIssue {
+  readonly kind: FsckIssueKind;
+  /** The file, table, or index setting the issue is about. */
+  readonly subject: string;
+  readonly message: string;
+  /** Whether `repairIndex` and a re-index can fix it. */
+  readonly repairable: boolean;
+}
+
+/** `WalkOptions` and `FileLimits` as the index was built with them. */
+export interface FsckOptions extends WalkOptions, FileLimits {
+  readonly rootDir: string;
+  /** Patterns the index excluded; defaults to the indexer's. */
+  readonly exclude?: readonly string[];
+  /** The configuration hash a fresh index would record. */
+  readonly configHash?: string;
+  /** The scopes to scan; defaults to those the index was built with. */
+  readonly scopes?: readonly ScanScope[];
+}
+
+export interface FsckReport {
+  /** Files the scan would index, each compared with the store. */
+  readonly filesChecked: number;
+  readonly issues: readonly FsckIssue[];
+}
+
+export interface FsckRepair {
+  /** Files whose stored entities were dropped for re-indexing. */
+  readonly filesCleared: readonly string[];
+  /** Dangling and orphaned full-text rows deleted. */
+  readonly rowsDeleted: number;
+}
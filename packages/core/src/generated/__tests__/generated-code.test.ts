@@ -0,0 +1,92 @@
+import { describe, it, expect } from 'vitest';
+import type { ParseResult } from '../../types/parse-result.js';
+import {
+  detectGeneratedCode,
+  findGenerator,
+  inheritGeneratorMetadata,
+  toGeneratorNode,
+} from '../generated-code.js';
+
+function makeParseResult(overrides: Partial<ParseResult> = {}): ParseResult {
+  return {
+    name: 'protoGen',
+    filePath: '/repo/tools/proto-gen.ts',
+    line: 1,
+    column: 1,
+    language: 'typescript',
+    entityType: 'module',
+    metadata: {
+      type: 'module',
+      description: 'Compiles protobuf definitions into typed clients',
+      owner: 'platform-team',
+      generates: ['*.pb.go', 'gen/'],
+    },
+    rawDocstring: '',
+    ...overrides,
+  };
+}
+
+describe('detectGeneratedCode', () => {
+  it('recognises the Go convention and extracts the tool', () => {
+    const content =
+      '// Code generated by protoc-gen-go. DO NOT EDIT.\n// versions:\npackage api\n';
+    expect(detectGeneratedCode(content)).toEqual({
+      line: 1,
+      tool: 'protoc-gen-go',
+    });
+  });
+
+  it('accepts other comment styles and markers without a tool', () => {
+    expect(
+      detectGeneratedCode('"""Docs."""\n# Code generated - DO NOT EDIT.\n'),
+    ).toEqual({ line: 2 });
+    expect(
+      detectGeneratedCode('/* Code generated by graphql-codegen, DO NOT EDIT */')
+        ?.tool,
+    ).toBe('graphql-codegen');
+  });
+
+  it('ignores files that merely mention the phrase', () => {
+    expect(
+      detectGeneratedCode(
+        'const banner = "Code generated by x. DO NOT EDIT.";\n',
+      ),
+    ).toBeUndefined();
+    expect(detectGeneratedCode('export const x = 1;\n')).toBeUndefined();
+  });
+});
+
+describe('generator linking', () => {
+  const generator = toGeneratorNode(makeParseResult(), 'tools/proto-gen.ts');
+
+  it('builds generator nodes only from annotations with generates', () => {
+    expect(generator?.patterns).toEqual(['*.pb.go', 'gen/']);
+    expect(
+      toGeneratorNode(
+        makeParseResult({
+          metadata: { type: 'module', description: 'Plain module' },
+        }),
+        'src/plain.ts',
+      ),
+    ).toBeUndefined();
+  });
+
+  it('matches generated paths with gitignore semantics', () => {
+    const generators = generator ? [generator] : [];
+    expect(findGenerator('api/v1/user.pb.go', generators)?.name).toBe(
+      'protoGen',
+    );
+    expect(findGenerator('gen/client/index.ts', generators)?.name).toBe(
+      'protoGen',
+    );
+    expect(findGenerator('src/user.go', generators)).toBeUndefined();
+  });
+
+  it('propagates metadata without the generates patterns', () => {
+    expect(generator && inheritGeneratorMetadata(generator)).toEqual({
+      type: 'module',
+      description: 'Compiles protobuf definitions into typed clients',
+      owner: 'platform-team',
+    });
+  });
+});
@@ -0,0 +1,85 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Detects "Code generated ... DO NOT EDIT" files and links them to annotated generators
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [generated, codegen, coverage, propagation]
+ * context:
+ *   business_goal: Stop generated files from dragging down coverage when their generator is documented
+ *   domain: generated-code
+ */
+import ignore from 'ignore';
+import type { ParseResult } from '../types/parse-result.js';
+import type { CoreMetadata, ExtendedMetadata } from '../types/entity.js';
+import type { GeneratedCodeMarker, GeneratorNode } from './types.js';
+
+// The Go convention (`// Code generated by X. DO NOT EDIT.`), accepted in
+// any line or block comment style so protobuf, GraphQL, and OpenAPI output
+// in other languages is recognised too.
+const MARKER_PATTERN =
+  /^\s*(?:\/\/|#|--|\/\*+|\*)\s*Code generated\b(.*?)\bDO NOT EDIT\b/;
+const TOOL_PATTERN = /^\s*by\s+(\S+?)[.,:]?(?:\s|$)/;
+
+/**
+ * Find the generated-code marker in a file, if it has one.
+ */
+export function detectGeneratedCode(
+  content: string,
+): GeneratedCodeMarker | undefined {
+  const lines = content.split('\n');
+  for (let i = 0; i < lines.length; i++) {
+    const match = MARKER_PATTERN.exec(lines[i]);
+    if (!match) continue;
+    const tool = TOOL_PATTERN.exec(match[1])?.[1];
+    return tool ? { line: i + 1, tool } : { line: i + 1 };
+  }
+  return undefined;
+}
+
+/**
+ * Build a generator node from a parse result whose annotation declares
+ * `generates` patterns. Returns undefined for ordinary annotations.
+ */
+export function toGeneratorNode(
+  result: ParseResult,
+  relativePath: string,
+): GeneratorNode | undefined {
+  const { metadata } = result;
+  if (!('generates' in metadata) || !metadata.generates?.length) {
+    return undefined;
+  }
+  return {
+    name: result.name,
+    filePath: relativePath,
+    patterns: metadata.generates,
+    metadata,
+  };
+}
+
+/**
+ * The first generator whose patterns match `relativePath`. Patterns use
+ * .gitignore semantics, so `*.pb.go` matches at any depth and `gen/`
+ * matches a whole directory.
+ */
+export function findGenerator(
+  relativePath: string,
+  generators: readonly GeneratorNode[],
+): GeneratorNode | undefined {
+  return generators.find((generator) =>
+    ignore().add([...generator.patterns]).ignores(relativePath),
+  );
+}
+
+/**
+ * Metadata a generated file inherits from its generator: everything except
+ * the `generates` patterns, so the file is not itself treated as a generator.
+ */
+export function inheritGeneratorMetadata(
+  generator: GeneratorNode,
+): CoreMetadata | ExtendedMetadata {
+  if (!('generates' in generator.metadata)) return generator.metadata;
+  const inherited = { ...generator.metadata };
+  delete inherited.generates;
+  return inherited;
+}
@@ -0,0 +1,7 @@
+export type { GeneratedCodeMarker, GeneratorNode } from './types.js';
+export {
+  detectGeneratedCode,
+  toGeneratorNode,
+  findGenerator,
+  inheritGeneratorMetadata,
+} from './generated-code.js';
@@ -0,0 +1,27 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for generated-file markers and the generator annotations they inherit from
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [generated, codegen, types, interface]
+ * context:
+ *   business_goal: Describe how generated files are tied back to the code that produces them
+ *   domain: generated-code
+ */
+import type { CoreMetadata, ExtendedMetadata } from '../types/entity.js';
+
+export interface GeneratedCodeMarker {
+  /** 1-based line of the `Code generated ... DO NOT EDIT` comment. */
+  readonly line: number;
+  /** Tool named by `Code generated by <tool>`, when present. */
+  readonly tool?: string;
+}
+
+export interface GeneratorNode {
+  readonly name: string;
+  /** Path of the annotated generator, relative to the project root. */
+  readonly filePath: string;
+  readonly patterns: readonly string[];
+  readonly metadata: CoreMetadata | ExtendedMetadata;
+}
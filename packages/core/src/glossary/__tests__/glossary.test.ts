@@ -0,0 +1,141 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Glossary } from '../../types/glossary.js';
+import {
+  findEntitiesForTerm,
+  findTermMention,
+  linkGlossaryTerms,
+  loadGlossary,
+} from '../glossary.js';
+
+function makeEntity(name: string, description: string): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'function',
+    description,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: { type: 'function', description },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const glossary: Glossary = {
+  terms: [
+    {
+      term: 'chargeback',
+      definition: 'A payment reversal initiated by the card issuer',
+      aliases: ['dispute'],
+    },
+    {
+      term: 'settlement batch',
+      definition: 'A group of captured payments paid out together',
+      aliases: [],
+    },
+  ],
+};
+
+const entities = [
+  makeEntity('handleChargeback', 'Processes incoming Chargebacks from Stripe'),
+  makeEntity('openDispute', 'Opens a dispute case for a customer'),
+  makeEntity('closeBatch', 'Closes the current settlement-batch'),
+  makeEntity('refund', 'Refunds a captured payment'),
+];
+
+describe('findTermMention', () => {
+  it('matches case-insensitively with plurals', () => {
+    expect(findTermMention('Handles CHARGEBACKS', glossary.terms[0])).toBe(
+      'chargeback',
+    );
+  });
+
+  it('matches aliases and hyphenated phrases', () => {
+    expect(findTermMention('a dispute', glossary.terms[0])).toBe('dispute');
+    expect(findTermMention('settlement-batch', glossary.terms[1])).toBe(
+      'settlement batch',
+    );
+  });
+
+  it('requires whole words', () => {
+    expect(
+      findTermMention('chargebackable', glossary.terms[0]),
+    ).toBeUndefined();
+  });
+});
+
+describe('linkGlossaryTerms', () => {
+  it('links each term to mentioning entities', () => {
+    const links = linkGlossaryTerms(entities, glossary);
+    const names = links.map((l) => [
+      l.term.term,
+      l.mentions.map((m) => m.name),
+    ]);
+    expect(names).toEqual([
+      ['chargeback', ['handleChargeback', 'openDispute']],
+      ['settlement batch', ['closeBatch']],
+    ]);
+    expect(links[0].mentions[1].matchedAs).toBe('dispute');
+  });
+});
+
+describe('findEntitiesForTerm', () => {
+  it('finds entities by term or alias', () => {
+    expect(
+      findEntitiesForTerm(entities, glossary, 'Dispute')?.mentions,
+    ).toHaveLength(2);
+  });
+
+  it('returns undefined for unknown terms', () => {
+    expect(findEntitiesForTerm(entities, glossary, 'ledger')).toBeUndefined();
+  });
+});
+
+describe('loadGlossary', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-glossary-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('parses a YAML glossary file', () => {
+    const path = join(dir, 'glossary.yml');
+    writeFileSync(
+      path,
+      'terms:\n  - term: chargeback\n    definition: Card reversal\n',
+    );
+    expect(loadGlossary(path).terms[0]).toEqual({
+      term: 'chargeback',
+      definition: 'Card reversal',
+      aliases: [],
+    });
+  });
+
+  it('throws on invalid glossaries', () => {
+    const path = join(dir, 'glossary.yml');
+    writeFileSync(path, 'terms:\n  - term: chargeback\n');
+    expect(() => loadGlossary(path)).toThrow('Invalid glossary');
+  });
+});
@@ -0,0 +1,104 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Loads glossary files and links entities whose descriptions mention glossary terms or aliases
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [glossary, domain, linking, search]
+ * context:
+ *   business_goal: Answer "show everything related to this business term" across the codebase
+ *   domain: glossary
+ */
+import { readFileSync } from 'node:fs';
+import { parse as parseYaml } from 'yaml';
+import type { StoredEntity } from '../indexer/types.js';
+import { GlossarySchema } from '../types/glossary.js';
+import type { Glossary, GlossaryTerm } from '../types/glossary.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { TermLinks, TermMention } from './types.js';
+
+/**
+ * Read and validate a YAML glossary file.
+ */
+export function loadGlossary(path: string): Glossary {
+  const parsed = parseYaml(readFileSync(path, 'utf-8')) as unknown;
+  const result = GlossarySchema.safeParse(parsed);
+  if (!result.success) {
+    const issue = result.error.issues[0];
+    throw createKnowgraphError(
+      'schema',
+      `Invalid glossary ${path}: ${issue.path.join('.')} ${issue.message}`,
+    );
+  }
+  return result.data;
+}
+
+function escapeRegExp(value: string): string {
+  return value.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+function phrasePattern(phrase: string): RegExp {
+  const words = phrase.trim().split(/[\s-]+/).map(escapeRegExp);
+  return new RegExp(`\\b${words.join('[\\s-]+')}(?:s|es)?\\b`, 'i');
+}
+
+/**
+ * Return the first spelling (term or alias) of `term` mentioned in `text`.
+ * Matching is case-insensitive, whole-word, and tolerates simple plurals and
+ * hyphen/space variations.
+ */
+export function findTermMention(
+  text: string,
+  term: GlossaryTerm,
+): string | undefined {
+  return [term.term, ...term.aliases].find((phrase) =>
+    phrasePattern(phrase).test(text),
+  );
+}
+
+function toMention(entity: StoredEntity, matchedAs: string): TermMention {
+  return {
+    entityId: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    filePath: entity.filePath,
+    line: entity.line,
+    owner: entity.owner,
+    matchedAs,
+  };
+}
+
+/**
+ * Link every glossary term to the entities whose description mentions it.
+ */
+export function linkGlossaryTerms(
+  entities: readonly StoredEntity[],
+  glossary: Glossary,
+): readonly TermLinks[] {
+  return glossary.terms.map((term) => ({
+    term,
+    mentions: entities.flatMap((entity) => {
+      const matchedAs = findTermMention(entity.description, term);
+      return matchedAs ? [toMention(entity, matchedAs)] : [];
+    }),
+  }));
+}
+
+/**
+ * Look up a term by name or alias and return the entities related to it.
+ * Returns undefined when the glossary does not define the term.
+ */
+export function findEntitiesForTerm(
+  entities: readonly StoredEntity[],
+  glossary: Glossary,
+  query: string,
+): TermLinks | undefined {
+  const needle = query.trim().toLowerCase();
+  const term = glossary.terms.find((candidate) =>
+    [candidate.term, ...candidate.aliases].some(
+      (phrase) => phrase.toLowerCase() === needle,
+    ),
+  );
+  if (!term) return undefined;
+  return linkGlossaryTerms(entities, { terms: [term] })[0];
+}
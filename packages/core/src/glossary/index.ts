@@ -0,0 +1,7 @@
+export type { TermMention, TermLinks } from './types.js';
+export {
+  loadGlossary,
+  findTermMention,
+  linkGlossaryTerms,
+  findEntitiesForTerm,
+} from './glossary.js';
@@ -0,0 +1,27 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for glossary term matches and term-to-entity link reports
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [glossary, domain, types, interface]
+ * context:
+ *   business_goal: Define contracts for linking code to domain vocabulary
+ *   domain: glossary
+ */
+import type { EntityType, GlossaryTerm } from '../types/index.js';
+
+export interface TermMention {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly line: number;
+  readonly owner: string | null;
+  readonly matchedAs: string;
+}
+
+export interface TermLinks {
+  readonly term: GlossaryTerm;
+  readonly mentions: readonly TermMention[];
+}
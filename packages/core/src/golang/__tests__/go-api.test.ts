@@ -0,0 +1,178 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Status } from '../../types/entity.js';
+import { diffGoApi, extractGoApi, GO_API_VERSION } from '../go-api.js';
+import type { GoApiRunner, GoApiSurface, GoPackage } from '../types.js';
+
+function makeEntity(filePath: string, status: Status): StoredEntity {
+  return {
+    id: `id-${filePath}`,
+    filePath,
+    name: 'client',
+    entityType: 'module',
+    description: 'HTTP client for the payments API',
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'go',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status,
+    metadata: {
+      type: 'module',
+      description: 'HTTP client for the payments API',
+      status,
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const packages: readonly GoPackage[] = [
+  {
+    importPath: 'example.com/app/pkg/client',
+    name: 'client',
+    dir: 'pkg/client',
+    module: 'example.com/app',
+    imports: [],
+  },
+  {
+    importPath: 'example.com/app/internal/util',
+    name: 'util',
+    dir: 'internal/util',
+    module: 'example.com/app',
+    imports: [],
+  },
+];
+
+const output = JSON.stringify([
+  {
+    dir: 'pkg/client',
+    name: 'main',
+    symbols: [{ name: 'Stray', kind: 'func', signature: 'func Stray()' }],
+  },
+  {
+    dir: 'pkg/client',
+    name: 'client',
+    symbols: [
+      { name: 'New', kind: 'func', signature: 'func New(Options) *Client' },
+    ],
+  },
+  { dir: 'internal/util', name: 'util', symbols: [] },
+]);
+
+describe('extractGoApi', () => {
+  it('reads each package and marks stable ones from annotations', () => {
+    const calls: (readonly string[])[] = [];
+    const runner: GoApiRunner = {
+      exports(_rootDir, dirs) {
+        calls.push(dirs);
+        return output;
+      },
+    };
+    const surface = extractGoApi(
+      '/repo',
+      packages,
+      [
+        makeEntity('pkg/client/doc.go', 'stable'),
+        makeEntity('internal/util/util.go', 'experimental'),
+      ],
+      runner,
+    );
+
+    expect(calls).toEqual([['pkg/client', 'internal/util']]);
+    expect(surface.packages.map((pkg) => [pkg.dir, pkg.stable])).toEqual([
+      ['internal/util', false],
+      ['pkg/client', true],
+    ]);
+    expect(surface.packages[1].symbols.map((s) => s.name)).toEqual(['New']);
+  });
+
+  it('does not run go without packages', () => {
+    const runner: GoApiRunner = {
+      exports() {
+        throw new Error('should not run');
+      },
+    };
+    expect(extractGoApi('/repo', [], [], runner).packages).toEqual([]);
+  });
+});
+
+describe('diffGoApi', () => {
+  const before: GoApiSurface = {
+    version: GO_API_VERSION,
+    packages: [
+      {
+        importPath: 'example.com/app/pkg/client',
+        dir: 'pkg/client',
+        stable: true,
+        symbols: [
+          {
+            name: 'Client.Do',
+            kind: 'method',
+            signature: 'func (*Client) Do(string) error',
+          },
+          { name: 'New', kind: 'func', signature: 'func New(Options) *Client' },
+          {
+            name: 'Version',
+            kind: 'const',
+            signature: 'const Version untyped string',
+          },
+        ],
+      },
+      {
+        importPath: 'example.com/app/internal/util',
+        dir: 'internal/util',
+        stable: false,
+        symbols: [
+          { name: 'Trim', kind: 'func', signature: 'func Trim(string) string' },
+        ],
+      },
+    ],
+  };
+
+  it('flags removed and changed symbols of stable packages as breaking', () => {
+    const after: GoApiSurface = {
+      version: GO_API_VERSION,
+      packages: [
+        {
+          ...before.packages[0],
+          symbols: [
+            {
+              name: 'Client.Do',
+              kind: 'method',
+              signature: 'func (*Client) Do(context.Context, string) error',
+            },
+            {
+              name: 'Dial',
+              kind: 'func',
+              signature: 'func Dial(string) (*Client, error)',
+            },
+            {
+              name: 'New',
+              kind: 'func',
+              signature: 'func New(Options) *Client',
+            },
+          ],
+        },
+      ],
+    };
+
+    const changes = diffGoApi(before, after);
+    expect(changes.map((c) => [c.symbol, c.change, c.breaking])).toEqual([
+      ['Trim', 'removed', false],
+      ['Client.Do', 'changed', true],
+      ['Dial', 'added', false],
+      ['Version', 'removed', true],
+    ]);
+    expect(changes[1].before).toBe('func (*Client) Do(string) error');
+  });
+
+  it('reports nothing for an unchanged surface', () => {
+    expect(diffGoApi(before, before)).toEqual([]);
+  });
+});
@@ -0,0 +1,119 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { discoverGoPackages, parseGoImports } from '../go-packages.js';
+
+describe('parseGoImports', () => {
+  it('reads single, grouped, named, blank, and dot imports', () => {
+    const content = [
+      '// Package api serves HTTP.',
+      'package api',
+      '',
+      'import "fmt"',
+      'import log "github.com/sirupsen/logrus"',
+      'import (',
+      '\t"net/http" // server',
+      '\t_ "embed"',
+      '\t. "example.com/repo/util"',
+      '\t/* "ignored/in/comment" */',
+      ')',
+      '',
+      'func main() {}',
+      'import "not/an/import"',
+    ].join('\n');
+    expect(parseGoImports(content)).toEqual([
+      'fmt',
+      'github.com/sirupsen/logrus',
+      'net/http',
+      'embed',
+      'example.com/repo/util',
+    ]);
+  });
+
+  it('handles groups on a single line and raw string paths', () => {
+    expect(
+      parseGoImports('package x\nimport ("os"; `strings`)\nvar y = 1\n'),
+    ).toEqual(['os', 'strings']);
+  });
+});
+
+describe('discoverGoPackages', () => {
+  let dir: string;
+
+  function write(path: string, content = ''): void {
+    const full = join(dir, path);
+    mkdirSync(join(full, '..'), { recursive: true });
+    writeFileSync(full, content);
+  }
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-golang-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('maps directories to import paths and keeps intra-repo imports', () => {
+    write('go.mod', 'module example.com/repo\n');
+    write(
+      'main.go',
+      'package main\n\nimport (\n\t"fmt"\n\t"example.com/repo/internal/db"\n)\n',
+    );
+    write('internal/db/db.go', 'package db\n');
+    write(
+      'internal/db/db_test.go',
+      'package db\n\nimport "example.com/repo/internal/testutil"\n',
+    );
+    write('internal/testutil/util.go', 'package testutil\n');
+    write('vendor/example.com/dep/dep.go', 'package dep\n');
+    write('testdata/fixture.go', 'package fixture\n');
+
+    expect(discoverGoPackages(dir)).toEqual([
+      {
+        importPath: 'example.com/repo',
+        name: 'main',
+        dir: '.',
+        module: 'example.com/repo',
+        imports: ['example.com/repo/internal/db'],
+      },
+      {
+        importPath: 'example.com/repo/internal/db',
+        name: 'db',
+        dir: 'internal/db',
+        module: 'example.com/repo',
+        imports: [],
+      },
+      {
+        importPath: 'example.com/repo/internal/testutil',
+        name: 'testutil',
+        dir: 'internal/testutil',
+        module: 'example.com/repo',
+        imports: [],
+      },
+    ]);
+  });
+
+  it('resolves nested modules and ignores files outside any module', () => {
+    write('scripts/gen.go', 'package main\n');
+    write('services/api/go.mod', 'module example.com/api\n');
+    write(
+      'services/api/handlers/h.go',
+      'package handlers\n\nimport "example.com/lib"\n',
+    );
+    write('libs/lib/go.mod', 'module example.com/lib\n');
+    write('libs/lib/lib.go', 'package lib\n');
+
+    const packages = discoverGoPackages(dir);
+    expect(packages.map((pkg) => [pkg.importPath, pkg.dir])).toEqual([
+      ['example.com/api/handlers', 'services/api/handlers'],
+      ['example.com/lib', 'libs/lib'],
+    ]);
+    expect(packages[0].imports).toEqual(['example.com/lib']);
+  });
+});
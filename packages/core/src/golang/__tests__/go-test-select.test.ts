@@ -0,0 +1,150 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { discoverGoTestTags, selectGoTests } from '../go-test-select.js';
+import type { GoPackage } from '../types.js';
+
+function pkg(dir: string, imports: readonly string[] = []): GoPackage {
+  const importPath =
+    dir === '.' ? 'example.com/shop' : `example.com/shop/${dir}`;
+  return {
+    importPath,
+    name: dir.split('/').pop()!,
+    dir,
+    module: 'example.com/shop',
+    imports: imports.map((path) => `example.com/shop/${path}`),
+  };
+}
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  dependencies: readonly string[] = [],
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType: 'service',
+    description: `The ${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'go',
+    line: 1,
+    column: 0,
+    owner: 'platform',
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `The ${name} service`,
+      dependencies: { services: [...dependencies] },
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00.000Z',
+    updatedAt: '2024-01-01T00:00:00.000Z',
+  };
+}
+
+describe('selectGoTests', () => {
+  const packages = [
+    pkg('.', ['cmd/api']),
+    pkg('cmd/api', ['internal/billing']),
+    pkg('internal/billing', ['internal/money']),
+    pkg('internal/money'),
+    pkg('internal/search'),
+    pkg('worker'),
+  ];
+  const entities = [
+    makeEntity('billing', 'internal/billing/service.go'),
+    makeEntity('worker', 'worker/main.go', ['billing']),
+  ];
+  const graph = buildDependencyGraph(entities, { goPackages: packages });
+  const dirs = (files: readonly string[], minCoverage?: number) =>
+    selectGoTests(packages, graph, files, { minCoverage }).packages.map(
+      (p) => p.dir,
+    );
+
+  it('selects changed packages, their importers, and entity dependents', () => {
+    const selection = selectGoTests(packages, graph, [
+      'internal/money/money.go',
+      'README.md',
+    ]);
+    expect(selection).toMatchObject({ full: false, coverage: 1 });
+    expect(selection.packages.map((p) => [p.dir, p.changed])).toEqual([
+      ['.', false],
+      ['cmd/api', false],
+      ['internal/billing', false],
+      ['internal/money', true],
+      ['worker', false],
+    ]);
+  });
+
+  it('follows declared dependencies between entities into packages', () => {
+    expect(dirs(['internal/billing/service.go'])).toEqual([
+      '.',
+      'cmd/api',
+      'internal/billing',
+      'worker',
+    ]);
+    expect(dirs(['internal/search/testdata/golden.json'])).toEqual([
+      'internal/search',
+    ]);
+  });
+
+  it('falls back to every package when too few files map', () => {
+    const selection = selectGoTests(packages, graph, [
+      'internal/search/index.go',
+      'Makefile',
+    ]);
+    expect(selection.full).toBe(true);
+    expect(selection.reason).toBe(
+      'only 50% of changed files map to the graph (minimum 80%)',
+    );
+    expect(selection.unmapped).toEqual(['Makefile']);
+    expect(selection.packages).toHaveLength(packages.length);
+    expect(dirs(['internal/search/index.go', 'Makefile'], 0.5)).toEqual([
+      'internal/search',
+    ]);
+  });
+
+  it('falls back to every package when module files change', () => {
+    const selection = selectGoTests(packages, graph, ['./go.sum']);
+    expect(selection).toMatchObject({ full: true, reason: 'go.sum changed' });
+  });
+});
+
+describe('discoverGoTestTags', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-go-tags-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('collects custom tags from test file build constraints', () => {
+    mkdirSync(join(dir, 'db'));
+    writeFileSync(
+      join(dir, 'db', 'db_test.go'),
+      '//go:build integration && linux && !race\n\npackage db\n',
+    );
+    writeFileSync(
+      join(dir, 'db', 'e2e_test.go'),
+      '//go:build (e2e || smoke) && go1.21\n\npackage db\n',
+    );
+    writeFileSync(join(dir, 'db', 'db.go'), '//go:build ignored\npackage db\n');
+    expect(discoverGoTestTags(dir, ['db', 'missing'])).toEqual([
+      'e2e',
+      'integration',
+      'smoke',
+    ]);
+  });
+});
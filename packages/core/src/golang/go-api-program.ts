@@ -0,0 +1,172 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Go program that type-checks packages with go/types and prints their exported API as JSON
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [go, api, types, stability]
+ * context:
+ *   business_goal: Read the real exported surface of Go packages so stable APIs can be held to their contract
+ *   domain: golang
+ */
+
+/**
+ * Source of the helper `createGoApiRunner` runs with `go run`. It needs
+ * only the standard library, so it runs in any module without a
+ * download. Signatures leave out parameter names, constant values, and
+ * unexported struct fields, none of which a caller depends on.
+ */
+export const GO_API_PROGRAM = `// Prints the exported API of the Go packages in the directories given as
+// arguments, type-checked with go/types, as JSON.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+type symbol struct {
+	Name      string \`json:"name"\`
+	Kind      string \`json:"kind"\`
+	Signature string \`json:"signature"\`
+}
+
+type surface struct {
+	Dir     string   \`json:"dir"\`
+	Name    string   \`json:"name"\`
+	Symbols []symbol \`json:"symbols"\`
+}
+
+func typeParams(list *types.TypeParamList, q types.Qualifier) string {
+	if list.Len() == 0 {
+		return ""
+	}
+	params := make([]string, list.Len())
+	for i := range params {
+		p := list.At(i)
+		params[i] = p.Obj().Name() + " " + types.TypeString(p.Constraint(), q)
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// Parameter names are left out, since renaming one breaks no caller.
+func unnamed(tuple *types.Tuple) *types.Tuple {
+	vars := make([]*types.Var, tuple.Len())
+	for i := range vars {
+		v := tuple.At(i)
+		vars[i] = types.NewParam(v.Pos(), v.Pkg(), "", v.Type())
+	}
+	return types.NewTuple(vars...)
+}
+
+func funcSignature(head string, sig *types.Signature, q types.Qualifier) string {
+	bare := types.NewSignatureType(nil, nil, nil, unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic())
+	return head + typeParams(sig.TypeParams(), q) +
+		strings.TrimPrefix(types.TypeString(bare, q), "func")
+}
+
+// Unexported struct fields are left out, since callers cannot use them.
+func typeSignature(obj *types.TypeName, q types.Qualifier) string {
+	named, ok := obj.Type().(*types.Named)
+	if !ok || obj.IsAlias() {
+		return types.ObjectString(obj, q)
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return types.ObjectString(obj, q)
+	}
+	var fields []string
+	for i := 0; i < st.NumFields(); i++ {
+		if f := st.Field(i); f.Exported() {
+			if f.Embedded() {
+				fields = append(fields, types.TypeString(f.Type(), q))
+			} else {
+				fields = append(fields, f.Name()+" "+types.TypeString(f.Type(), q))
+			}
+		}
+	}
+	return "type " + obj.Name() + typeParams(named.TypeParams(), q) +
+		" struct{" + strings.Join(fields, "; ") + "}"
+}
+
+func exported(pkg *types.Package) []symbol {
+	q := types.RelativeTo(pkg)
+	symbols := []symbol{}
+	for _, name := range pkg.Scope().Names() {
+		obj := pkg.Scope().Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Func:
+			sig := obj.Type().(*types.Signature)
+			symbols = append(symbols, symbol{name, "func", funcSignature("func "+name, sig, q)})
+		case *types.Var:
+			symbols = append(symbols, symbol{name, "var", types.ObjectString(obj, q)})
+		case *types.Const:
+			// The value is not part of the contract, only the type
+			symbols = append(symbols, symbol{name, "const", "const " + name + " " + types.TypeString(obj.Type(), q)})
+		case *types.TypeName:
+			symbols = append(symbols, symbol{name, "type", typeSignature(obj, q)})
+			named, ok := obj.Type().(*types.Named)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+			for i := 0; i < named.NumMethods(); i++ {
+				m := named.Method(i)
+				if !m.Exported() {
+					continue
+				}
+				sig := m.Type().(*types.Signature)
+				head := "func (" + types.TypeString(sig.Recv().Type(), q) + ") " + m.Name()
+				symbols = append(symbols, symbol{name + "." + m.Name(), "method", funcSignature(head, sig, q)})
+			}
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Name < symbols[j].Name })
+	return symbols
+}
+
+func main() {
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil)
+	surfaces := []surface{}
+	for _, dir := range os.Args[1:] {
+		pkgs, err := parser.ParseDir(fset, dir, func(info fs.FileInfo) bool {
+			return !strings.HasSuffix(info.Name(), "_test.go")
+		}, 0)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		names := make([]string, 0, len(pkgs))
+		for name := range pkgs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			files := make([]*ast.File, 0, len(pkgs[name].Files))
+			for _, file := range pkgs[name].Files {
+				files = append(files, file)
+			}
+			// Report what type-checks even when an import cannot be resolved
+			conf := types.Config{Importer: imp, Error: func(error) {}}
+			pkg, _ := conf.Check(dir, fset, files, nil)
+			surfaces = append(surfaces, surface{dir, name, exported(pkg)})
+		}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(surfaces); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`;
@@ -0,0 +1,166 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Extracts the exported API of Go packages with go/types and diffs it, flagging breaking changes in stable packages
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [go, api, stability, diff, breaking-changes]
+ * context:
+ *   business_goal: Read a Go package's API the way the compiler sees it so diffs have no false alarms
+ *   domain: golang
+ */
+import { spawnSync } from 'node:child_process';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join, posix, sep } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { GO_API_PROGRAM } from './go-api-program.js';
+import type {
+  GoApiChange,
+  GoApiRunner,
+  GoApiSurface,
+  GoApiSymbol,
+  GoPackage,
+  GoPackageApi,
+} from './types.js';
+
+export const GO_API_VERSION = 1;
+
+const MAX_BUFFER_BYTES = 256 * 1024 * 1024;
+
+/** One package the helper program type-checked. */
+interface CheckedPackage {
+  readonly dir: string;
+  readonly name: string;
+  readonly symbols: readonly GoApiSymbol[];
+}
+
+/**
+ * Create a runner that writes the helper program to a temporary directory
+ * and runs it with `go run` in `rootDir`, so imports resolve against the
+ * repository's own go.mod files.
+ */
+export function createGoApiRunner(goPath = 'go'): GoApiRunner {
+  return {
+    exports(rootDir: string, dirs: readonly string[]): string {
+      const programDir = mkdtempSync(join(tmpdir(), 'knowgraph-go-api-'));
+      try {
+        const program = join(programDir, 'main.go');
+        writeFileSync(program, GO_API_PROGRAM);
+        const run = spawnSync(goPath, ['run', program, ...dirs], {
+          cwd: rootDir,
+          encoding: 'utf-8',
+          maxBuffer: MAX_BUFFER_BYTES,
+        });
+        if (run.error) {
+          throw new Error(`go run failed: ${run.error.message}`);
+        }
+        if (run.status !== 0) {
+          const detail = run.stderr.trim().split('\n').pop() ?? '';
+          throw new Error(`go run failed: ${detail || `exit ${run.status}`}`);
+        }
+        return run.stdout;
+      } finally {
+        rmSync(programDir, { recursive: true, force: true });
+      }
+    },
+  };
+}
+
+/**
+ * Directories of packages whose `module` annotation, usually on the
+ * package clause, declares `status: stable`.
+ */
+function stableDirs(entities: readonly StoredEntity[]): ReadonlySet<string> {
+  return new Set(
+    entities
+      .filter(
+        (entity) =>
+          entity.entityType === 'module' && entity.status === 'stable',
+      )
+      .map((entity) => posix.dirname(entity.filePath.split(sep).join('/'))),
+  );
+}
+
+/**
+ * The exported API of `packages`, found by `discoverGoPackages` under
+ * `rootDir`, with each marked stable from `entities`. A package that does
+ * not type-check still lists what go/types could resolve.
+ */
+export function extractGoApi(
+  rootDir: string,
+  packages: readonly GoPackage[],
+  entities: readonly StoredEntity[],
+  runner: GoApiRunner = createGoApiRunner(),
+): GoApiSurface {
+  if (packages.length === 0) {
+    return { version: GO_API_VERSION, packages: [] };
+  }
+  const checked = JSON.parse(
+    runner.exports(rootDir, packages.map((pkg) => pkg.dir)),
+  ) as readonly CheckedPackage[];
+  const stable = stableDirs(entities);
+  const apis: GoPackageApi[] = packages.map((pkg) => {
+    // A directory can hold stray files of another package; prefer the
+    // package clause discovery found
+    const inDir = checked.filter((entry) => entry.dir === pkg.dir);
+    const match = inDir.find((entry) => entry.name === pkg.name) ?? inDir[0];
+    return {
+      importPath: pkg.importPath,
+      dir: pkg.dir,
+      stable: stable.has(pkg.dir),
+      symbols: match?.symbols ?? [],
+    };
+  });
+  return {
+    version: GO_API_VERSION,
+    packages: apis.sort((a, b) => compareStrings(a.importPath, b.importPath)),
+  };
+}
+
+/**
+ * Every exported symbol added, removed, or given a new signature between
+ * two surfaces. Removals and changes are breaking when the package was
+ * stable in `before`, so a package can be marked stable or unmarked
+ * in the change that alters its API without failing it.
+ */
+export function diffGoApi(
+  before: GoApiSurface,
+  after: GoApiSurface,
+): readonly GoApiChange[] {
+  const old = new Map(before.packages.map((pkg) => [pkg.importPath, pkg]));
+  const current = new Map(after.packages.map((pkg) => [pkg.importPath, pkg]));
+  const paths = [...new Set([...old.keys(), ...current.keys()])].sort(
+    compareStrings,
+  );
+
+  const changes: GoApiChange[] = [];
+  for (const importPath of paths) {
+    const from = old.get(importPath);
+    const to = current.get(importPath);
+    const dir = (to ?? from)?.dir ?? '.';
+    const fromSymbols = new Map(from?.symbols.map((s) => [s.name, s]));
+    const toSymbols = new Map(to?.symbols.map((s) => [s.name, s]));
+    const names = [
+      ...new Set([...fromSymbols.keys(), ...toSymbols.keys()]),
+    ].sort(compareStrings);
+    for (const symbol of names) {
+      const was = fromSymbols.get(symbol);
+      const is = toSymbols.get(symbol);
+      if (was && is && was.signature === is.signature) continue;
+      const change = !was ? 'added' : !is ? 'removed' : 'changed';
+      changes.push({
+        importPath,
+        dir,
+        symbol,
+        kind: ((is ?? was) as GoApiSymbol).kind,
+        change,
+        before: was?.signature ?? null,
+        after: is?.signature ?? null,
+        breaking: (from?.stable ?? false) && change !== 'added',
+      });
+    }
+  }
+  return changes;
+}
@@ -0,0 +1,160 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Discovers Go packages under go.mod modules and the intra-repo imports between them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [go, packages, imports, discovery]
+ * context:
+ *   business_goal: Show the real package structure of Go code even where nothing is annotated
+ *   domain: golang
+ */
+import { readFileSync, readdirSync, statSync } from 'node:fs';
+import { join, posix, relative, sep } from 'node:path';
+import { parseGoModulePath } from '../workspace/discovery.js';
+import type { GoPackage } from './types.js';
+
+const SKIP_DIRS = new Set(['vendor', 'testdata', 'node_modules']);
+
+const BLOCK_COMMENT_REGEX = /\/\*[\s\S]*?\*\//g;
+const PACKAGE_REGEX = /^package\s+(\w+)/m;
+const IMPORT_SPEC_REGEX = /^(?:[\w.]+\s+)?["`]([^"`]+)["`]/;
+const DECLARATION_REGEX = /^(?:func|type|var|const)\b/;
+
+function readText(path: string): string | undefined {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Import paths from a Go source file, covering single imports, grouped
+ * imports, and named, blank (`_`), and dot imports. Scanning stops at the
+ * first top-level declaration since imports must precede them.
+ */
+export function parseGoImports(content: string): readonly string[] {
+  const imports: string[] = [];
+  let inBlock = false;
+  const source = content.replace(BLOCK_COMMENT_REGEX, '');
+  for (const raw of source.split('\n')) {
+    let line = raw.replace(/\/\/.*$/, '').trim();
+    if (!inBlock) {
+      if (DECLARATION_REGEX.test(line)) break;
+      const group = /^import\s*\((.*)$/.exec(line);
+      if (group) {
+        inBlock = true;
+        line = group[1].trim();
+      } else {
+        const single = /^import\s+(.*)$/.exec(line);
+        const spec = single && IMPORT_SPEC_REGEX.exec(single[1]);
+        if (spec) imports.push(spec[1]);
+        continue;
+      }
+    }
+    // Grouped specs may share a line with the closing parenthesis
+    for (const part of line.split(';')) {
+      const spec = part.trim();
+      if (spec.startsWith(')')) {
+        inBlock = false;
+        break;
+      }
+      const match = IMPORT_SPEC_REGEX.exec(spec);
+      if (match) imports.push(match[1]);
+      if (spec.endsWith(')')) {
+        inBlock = false;
+        break;
+      }
+    }
+  }
+  return imports;
+}
+
+interface GoModule {
+  readonly path: string;
+  readonly dir: string;
+}
+
+interface RawPackage {
+  readonly importPath: string;
+  readonly name: string;
+  readonly dir: string;
+  readonly module: string;
+  readonly imports: ReadonlySet<string>;
+}
+
+/**
+ * Find every Go package under `rootDir`. Each directory holding non-test
+ * `.go` files belongs to the innermost enclosing go.mod; directories the go
+ * tool ignores (`vendor`, `testdata`, and names starting with `.` or `_`)
+ * are skipped. Only imports of other discovered packages are kept.
+ */
+export function discoverGoPackages(rootDir: string): readonly GoPackage[] {
+  const found: RawPackage[] = [];
+
+  function walk(dir: string, module: GoModule | undefined): void {
+    let entries: readonly string[];
+    try {
+      entries = readdirSync(dir);
+    } catch {
+      return;
+    }
+
+    let current = module;
+    if (entries.includes('go.mod')) {
+      const modulePath = parseGoModulePath(
+        readText(join(dir, 'go.mod')) ?? '',
+      );
+      if (modulePath) current = { path: modulePath, dir };
+    }
+
+    const sources = entries.filter(
+      (entry) => entry.endsWith('.go') && !entry.endsWith('_test.go'),
+    );
+    if (current && sources.length > 0) {
+      let name: string | undefined;
+      const imports = new Set<string>();
+      for (const file of sources) {
+        const content = readText(join(dir, file));
+        if (content === undefined) continue;
+        const source = content.replace(BLOCK_COMMENT_REGEX, '');
+        name ??= PACKAGE_REGEX.exec(source)?.[1];
+        for (const path of parseGoImports(content)) imports.add(path);
+      }
+      const rel = relative(current.dir, dir).split(sep).join('/');
+      const importPath = rel ? `${current.path}/${rel}` : current.path;
+      const pkgDir = relative(rootDir, dir).split(sep).join('/') || '.';
+      found.push({
+        importPath,
+        name: name ?? posix.basename(importPath),
+        dir: pkgDir,
+        module: current.path,
+        imports,
+      });
+    }
+
+    for (const entry of entries) {
+      if (entry.startsWith('.') || entry.startsWith('_')) continue;
+      if (entry.startsWith('bazel-') || SKIP_DIRS.has(entry)) continue;
+      const fullPath = join(dir, entry);
+      try {
+        if (statSync(fullPath).isDirectory()) walk(fullPath, current);
+      } catch {
+        // Skip inaccessible entries
+      }
+    }
+  }
+
+  walk(rootDir, undefined);
+
+  const known = new Set(found.map((pkg) => pkg.importPath));
+  return found
+    .map((pkg) => ({
+      ...pkg,
+      imports: [...pkg.imports]
+        .filter((path) => known.has(path) && path !== pkg.importPath)
+        .sort(),
+    }))
+    .sort((a, b) => a.importPath.localeCompare(b.importPath));
+}
@@ -0,0 +1,177 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Selects the Go test packages and build tags a change set needs by walking dependents in the graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [go, testing, ci, impact, selection]
+ * context:
+ *   business_goal: Cut CI time by running only the Go tests a change can break, falling back to everything when unsure
+ *   domain: golang
+ */
+import { readFileSync, readdirSync } from 'node:fs';
+import { join, posix, sep } from 'node:path';
+import { goPackageNodeId } from '../graph/graph-builder.js';
+import { selectImpactedUnits } from '../graph/graph-impact.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type {
+  GoPackage,
+  GoTestSelection,
+  GoTestSelectionOptions,
+  SelectedGoPackage,
+} from './types.js';
+
+/** Files whose change can affect any package's build. */
+const MODULE_FILES = ['go.mod', 'go.sum', 'go.work', 'go.work.sum'];
+
+const BUILD_LINE_REGEX = /^\/\/go:build\s+(.+)$/;
+const TAG_REGEX = /(!?)\s*([A-Za-z0-9_.]+)/g;
+
+/** Build constraints the go tool sets itself, which `-tags` never needs. */
+const IMPLICIT_TAGS = new Set([
+  'aix',
+  'android',
+  'darwin',
+  'dragonfly',
+  'freebsd',
+  'hurd',
+  'illumos',
+  'ios',
+  'js',
+  'linux',
+  'netbsd',
+  'openbsd',
+  'plan9',
+  'solaris',
+  'wasip1',
+  'windows',
+  'unix',
+  '386',
+  'amd64',
+  'arm',
+  'arm64',
+  'loong64',
+  'mips',
+  'mipsle',
+  'mips64',
+  'mips64le',
+  'ppc64',
+  'ppc64le',
+  'riscv64',
+  's390x',
+  'wasm',
+  'cgo',
+  'gc',
+  'gccgo',
+  'ignore',
+]);
+
+/**
+ * The package a file belongs to: the one in its directory for a `.go`
+ * file, or the nearest enclosing one for any file under `testdata`.
+ */
+function packageOf(
+  file: string,
+  byDir: ReadonlyMap<string, GoPackage>,
+): GoPackage | undefined {
+  const dir = posix.dirname(file);
+  if (file.endsWith('.go')) return byDir.get(dir);
+  const segments = dir === '.' ? [] : dir.split('/');
+  const testdata = segments.indexOf('testdata');
+  if (testdata === -1) return undefined;
+  return byDir.get(segments.slice(0, testdata).join('/') || '.');
+}
+
+/**
+ * Pick the Go packages whose tests `changedFiles` can break, walking
+ * dependents with `selectImpactedUnits`. A file maps to its package (see
+ * `packageOf`) and an entity to the package in its directory, so a declared
+ * service dependency on an entity pulls in the package of its dependent.
+ * Every package is selected when a go.mod, go.sum, or go.work file changed.
+ */
+export function selectGoTests(
+  packages: readonly GoPackage[],
+  graph: DependencyGraph,
+  changedFiles: readonly string[],
+  options: GoTestSelectionOptions = {},
+): GoTestSelection {
+  const byDir = new Map(packages.map((pkg) => [pkg.dir, pkg]));
+  const units = packages.map((pkg) => ({
+    id: goPackageNodeId(pkg.importPath),
+    pkg,
+  }));
+  const unitByDir = new Map(units.map((unit) => [unit.pkg.dir, unit]));
+  const selection = selectImpactedUnits(
+    graph,
+    units,
+    {
+      unitOfFile: (file) => {
+        const pkg = packageOf(file, byDir);
+        return pkg && unitByDir.get(pkg.dir);
+      },
+      unitOfNode: (node) =>
+        unitByDir.get(posix.dirname(node.filePath!.split(sep).join('/'))),
+    },
+    changedFiles,
+    { minCoverage: options.minCoverage, globalFiles: MODULE_FILES },
+  );
+  const changed = new Set(selection.changed);
+  return {
+    full: selection.full,
+    reason: selection.reason,
+    packages: selection.units
+      .map(
+        (unit): SelectedGoPackage => ({
+          importPath: unit.pkg.importPath,
+          dir: unit.pkg.dir,
+          changed: changed.has(unit),
+        }),
+      )
+      .sort((a, b) => a.importPath.localeCompare(b.importPath)),
+    unmapped: selection.unmapped,
+    coverage: selection.coverage,
+  };
+}
+
+/**
+ * The custom build tags that `_test.go` files in `dirs` (relative to
+ * `rootDir`) are constrained by, so `go test -tags` compiles them all. Tags
+ * the go tool sets itself (GOOS, GOARCH, `cgo`, `go1.N`) and tags that only
+ * appear negated are left out. Sorted.
+ */
+export function discoverGoTestTags(
+  rootDir: string,
+  dirs: readonly string[],
+): readonly string[] {
+  const tags = new Set<string>();
+  for (const dir of dirs) {
+    let entries: readonly string[];
+    try {
+      entries = readdirSync(join(rootDir, dir));
+    } catch {
+      continue;
+    }
+    for (const entry of entries) {
+      if (!entry.endsWith('_test.go')) continue;
+      let content: string;
+      try {
+        content = readFileSync(join(rootDir, dir, entry), 'utf-8');
+      } catch {
+        continue;
+      }
+      for (const line of content.split('\n')) {
+        const trimmed = line.trim();
+        if (trimmed.startsWith('package ')) break;
+        const expression = BUILD_LINE_REGEX.exec(trimmed)?.[1];
+        if (!expression) continue;
+        for (const [, negated, tag] of expression.matchAll(TAG_REGEX)) {
+          if (negated || IMPLICIT_TAGS.has(tag) || /^go1\.\d+$/.test(tag)) {
+            continue;
+          }
+          tags.add(tag);
+        }
+      }
+    }
+  }
+  return [...tags].sort();
+}
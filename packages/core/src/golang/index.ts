@@ -0,0 +1,22 @@
+export type {
+  GoPackage,
+  GoApiSymbolKind,
+  GoApiSymbol,
+  GoPackageApi,
+  GoApiSurface,
+  GoApiChangeKind,
+  GoApiChange,
+  GoApiRunner,
+  GoTestSelectionOptions,
+  SelectedGoPackage,
+  GoTestSelection,
+} from './types.js';
+export { parseGoImports, discoverGoPackages } from './go-packages.js';
+export {
+  GO_API_VERSION,
+  createGoApiRunner,
+  diffGoApi,
+  extractGoApi,
+} from './go-api.js';
+export { GO_API_PROGRAM } from './go-api-program.js';
+export { discoverGoTestTags, selectGoTests } from './go-test-select.js';
@@ -0,0 +1,102 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for Go packages and the intra-repo imports discovered from source
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [go, packages, imports, types, interface]
+ * context:
+ *   business_goal: Describe Go packages in terms other tools in the repo already use
+ *   domain: golang
+ */
+
+export interface GoPackage {
+  /** Full import path: module path plus the directory within the module. */
+  readonly importPath: string;
+  /** Name from the `package` clause. */
+  readonly name: string;
+  /** POSIX directory relative to the scan root; `.` for the root. */
+  readonly dir: string;
+  /** Path of the module (go.mod) the package belongs to. */
+  readonly module: string;
+  /** Import paths of other packages in the repository, sorted. */
+  readonly imports: readonly string[];
+}
+
+export type GoApiSymbolKind = 'func' | 'method' | 'type' | 'var' | 'const';
+
+/** An exported identifier, as go/types describes it. */
+export interface GoApiSymbol {
+  /** `Type.Method` for methods. */
+  readonly name: string;
+  readonly kind: GoApiSymbolKind;
+  /** Declaration without parameter names or constant values. */
+  readonly signature: string;
+}
+
+export interface GoPackageApi {
+  readonly importPath: string;
+  readonly dir: string;
+  /** The package's `module` annotation declares `status: stable`. */
+  readonly stable: boolean;
+  /** Sorted by name. */
+  readonly symbols: readonly GoApiSymbol[];
+}
+
+/** The exported API of a repository's Go packages, as recorded to diff. */
+export interface GoApiSurface {
+  readonly version: number;
+  /** Sorted by import path. */
+  readonly packages: readonly GoPackageApi[];
+}
+
+export type GoApiChangeKind = 'added' | 'removed' | 'changed';
+
+export interface GoApiChange {
+  readonly importPath: string;
+  readonly dir: string;
+  readonly symbol: string;
+  readonly kind: GoApiSymbolKind;
+  readonly change: GoApiChangeKind;
+  readonly before: string | null;
+  readonly after: string | null;
+  /** Removed or changed in a package that was stable before. */
+  readonly breaking: boolean;
+}
+
+export interface GoApiRunner {
+  /**
+   * Type-check the packages in `dirs`, relative to `rootDir`, and return
+   * the helper program's JSON output.
+   */
+  exports(rootDir: string, dirs: readonly string[]): string;
+}
+
+export interface GoTestSelectionOptions {
+  /**
+   * Share of changed files (other than Markdown) that must map to a Go
+   * package or an annotated entity for the selection to be trusted; below
+   * it every package is selected. Defaults to 0.8.
+   */
+  readonly minCoverage?: number;
+}
+
+export interface SelectedGoPackage {
+  readonly importPath: string;
+  readonly dir: string;
+  /** A changed file is in the package, rather than it depending on one. */
+  readonly changed: boolean;
+}
+
+export interface GoTestSelection {
+  /** Every package is selected: run `go test ./...`. */
+  readonly full: boolean;
+  /** Why every package is selected; null when the selection is narrowed. */
+  readonly reason: string | null;
+  /** Sorted by import path. */
+  readonly packages: readonly SelectedGoPackage[];
+  /** Changed files that map to no package or entity. */
+  readonly unmapped: readonly string[];
+  /** Share of considered changed files that mapped, from 0 to 1. */
+  readonly coverage: number;
+}
@@ -0,0 +1,107 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Dependencies, EntityType } from '../../types/entity.js';
+import { buildDependencyGraph } from '../graph-builder.js';
+import { buildDomainReport, UNASSIGNED_DOMAIN } from '../domain-rollup.js';
+
+function makeEntity(
+  name: string,
+  options: {
+    readonly entityType?: EntityType;
+    readonly domain?: string;
+    readonly owner?: string | null;
+    readonly dependencies?: Dependencies;
+  } = {},
+): StoredEntity {
+  const entityType = options.entityType ?? 'service';
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType,
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: options.owner === undefined ? 'team-a' : options.owner,
+    status: 'stable',
+    metadata: {
+      type: entityType,
+      description: `${name} description`,
+      context: options.domain ? { domain: options.domain } : undefined,
+      dependencies: options.dependencies,
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+describe('buildDomainReport', () => {
+  const entities = [
+    makeEntity('checkout', {
+      domain: 'ordering',
+      owner: 'orders-team',
+      dependencies: { services: ['ledger', 'cart'], external_apis: ['stripe'] },
+    }),
+    makeEntity('cart', { domain: 'ordering', owner: 'orders-team' }),
+    makeEntity('ledger', {
+      domain: 'billing',
+      owner: 'billing-team',
+      dependencies: { services: ['notifier'] },
+    }),
+    makeEntity('invoices', {
+      domain: 'billing',
+      owner: 'billing-team',
+      dependencies: { services: ['checkout'] },
+    }),
+    makeEntity('notifier', { owner: null }),
+  ];
+  const report = buildDomainReport(buildDependencyGraph(entities));
+
+  it('summarizes each domain with unassigned last', () => {
+    expect(report.domains.map((d) => d.domain)).toEqual([
+      'billing',
+      'ordering',
+      UNASSIGNED_DOMAIN,
+    ]);
+    const ordering = report.domains[1];
+    expect(ordering.entityCount).toBe(2);
+    expect(ordering.owners).toEqual(['orders-team']);
+    expect(ordering.internalEdges).toBe(1);
+  });
+
+  it('counts cross-domain dependencies and ignores external stubs', () => {
+    expect(
+      report.dependencies.map((d) => [d.from, d.to, d.count]),
+    ).toEqual([
+      ['billing', UNASSIGNED_DOMAIN, 1],
+      ['billing', 'ordering', 1],
+      ['ordering', 'billing', 1],
+    ]);
+    expect(report.dependencies[2].examples).toEqual(['checkout -> ledger']);
+  });
+
+  it('computes coupling and instability', () => {
+    const billing = report.domains[0];
+    expect(billing.afferent).toBe(1);
+    expect(billing.efferent).toBe(2);
+    expect(billing.instability).toBe(0.67);
+    const unassigned = report.domains[2];
+    expect(unassigned.efferent).toBe(0);
+    expect(unassigned.instability).toBe(0);
+  });
+
+  it('handles graphs without cross-domain edges', () => {
+    const isolated = buildDomainReport(
+      buildDependencyGraph([makeEntity('solo', { domain: 'x' })]),
+    );
+    expect(isolated.dependencies).toEqual([]);
+    expect(isolated.domains[0].instability).toBeNull();
+  });
+});
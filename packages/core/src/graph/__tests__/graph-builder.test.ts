@@ -0,0 +1,485 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Dependencies, EntityType } from '../../types/entity.js';
+import {
+  buildDependencyGraph,
+  createReferenceResolver,
+  getEntityDomain,
+  goPackageNodeId,
+} from '../graph-builder.js';
+import { findDescriptionReferences } from '../graph-references.js';
+
+function makeEntity(
+  name: string,
+  options: {
+    readonly entityType?: EntityType;
+    readonly domain?: string;
+    readonly owner?: string | null;
+    readonly dependencies?: Dependencies;
+    readonly aliases?: readonly string[];
+  } = {},
+): StoredEntity {
+  const entityType = options.entityType ?? 'service';
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType,
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: options.owner === undefined ? 'team-a' : options.owner,
+    status: 'stable',
+    metadata: {
+      type: entityType,
+      description: `${name} description`,
+      context: options.domain ? { domain: options.domain } : undefined,
+      dependencies: options.dependencies,
+      aliases: options.aliases ? [...options.aliases] : undefined,
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+describe('buildDependencyGraph', () => {
+  it('adds the calls enrichers found, below declared ones', () => {
+    const checkout = makeEntity('checkout', {
+      dependencies: { services: ['payments'] },
+    });
+    const entities = [
+      {
+        ...checkout,
+        metadata: {
+          ...checkout.metadata,
+          calls: ['src/payments.ts:payments', 'src/gone.ts:gone'],
+          http_calls: [
+            { url: 'ledger/entries', service: 'src/ledger.ts:ledger' },
+            { url: 'api.stripe.com/v1/charges', api: 'stripe' },
+            { url: 'metrics/push' },
+          ],
+        },
+      },
+      makeEntity('payments'),
+      makeEntity('ledger'),
+    ];
+
+    const graph = buildDependencyGraph(entities);
+    expect(graph.edges).toEqual([
+      {
+        from: 'id-checkout',
+        to: 'id-payments',
+        kind: 'service',
+        provenance: 'declared',
+        confidence: 1,
+      },
+      {
+        from: 'id-checkout',
+        to: 'id-ledger',
+        kind: 'service',
+        provenance: 'router',
+        confidence: 0.8,
+      },
+      {
+        from: 'id-checkout',
+        to: 'external:external_api:stripe',
+        kind: 'external_api',
+        provenance: 'call',
+        confidence: 0.8,
+      },
+    ]);
+  });
+
+  it('keeps only the dependencies of one environment', () => {
+    const entities = [
+      makeEntity('sessions', {
+        dependencies: {
+          services: ['auth'],
+          environments: {
+            prod: { databases: ['postgres-main'] },
+            dev: { databases: ['sqlite'] },
+          },
+        },
+      }),
+    ];
+    const targets = (environment?: string) =>
+      buildDependencyGraph(entities, { environment }).edges.map((e) => e.to);
+    expect(targets()).toEqual([
+      'external:service:auth',
+      'external:database:sqlite',
+      'external:database:postgres-main',
+    ]);
+    expect(targets('dev')).toEqual([
+      'external:service:auth',
+      'external:database:sqlite',
+    ]);
+  });
+
+  it('resolves service dependencies to indexed entities', () => {
+    const graph = buildDependencyGraph([
+      makeEntity('checkout', { dependencies: { services: ['Payments'] } }),
+      makeEntity('payments'),
+    ]);
+    expect(graph.edges).toEqual([
+      {
+        from: 'id-checkout',
+        to: 'id-payments',
+        kind: 'service',
+        provenance: 'declared',
+        confidence: 1,
+      },
+    ]);
+    expect(graph.nodes.every((node) => !node.external)).toBe(true);
+  });
+
+  it('prefers service entities over same-named functions', () => {
+    const graph = buildDependencyGraph([
+      makeEntity('checkout', { dependencies: { services: ['payments'] } }),
+      makeEntity('payments', { entityType: 'function' }),
+      { ...makeEntity('payments'), id: 'id-payments-service' },
+    ]);
+    expect(graph.edges[0].to).toBe('id-payments-service');
+  });
+
+  it('creates shared external stubs for unresolved dependencies', () => {
+    const graph = buildDependencyGraph([
+      makeEntity('checkout', {
+        dependencies: {
+          services: ['fraud-service'],
+          external_apis: ['stripe'],
+          databases: ['postgres'],
+        },
+      }),
+      makeEntity('refunds', { dependencies: { external_apis: ['stripe'] } }),
+    ]);
+    const external = graph.nodes.filter((node) => node.external);
+    expect(external.map((node) => node.id)).toEqual([
+      'external:service:fraud-service',
+      'external:external_api:stripe',
+      'external:database:postgres',
+    ]);
+    expect(graph.edges).toHaveLength(4);
+  });
+
+  it('carries annotated dependency entries onto their edges', () => {
+    const graph = buildDependencyGraph([
+      makeEntity('checkout', {
+        dependencies: {
+          services: [
+            {
+              name: 'tokens',
+              purpose: 'JWT signing',
+              protocol: 'grpc',
+              criticality: 'high',
+            },
+          ],
+          databases: [{ name: 'postgres', criticality: 'critical' }],
+          versions: { tokens: '>=2' },
+        },
+      }),
+      makeEntity('tokens'),
+    ]);
+    expect(graph.edges).toEqual([
+      {
+        from: 'id-checkout',
+        to: 'id-tokens',
+        kind: 'service',
+        provenance: 'declared',
+        confidence: 1,
+        requires: '>=2',
+        purpose: 'JWT signing',
+        protocol: 'grpc',
+        criticality: 'high',
+      },
+      {
+        from: 'id-checkout',
+        to: 'external:database:postgres',
+        kind: 'database',
+        provenance: 'declared',
+        confidence: 1,
+        criticality: 'critical',
+      },
+    ]);
+  });
+
+  it('ignores self and duplicate dependencies', () => {
+    const graph = buildDependencyGraph([
+      makeEntity('checkout', {
+        dependencies: { services: ['checkout', 'payments', 'payments'] },
+      }),
+      makeEntity('payments'),
+    ]);
+    expect(graph.edges).toHaveLength(1);
+  });
+
+  it('reads the context domain onto nodes', () => {
+    const entity = makeEntity('ledger', { domain: 'billing' });
+    expect(getEntityDomain(entity)).toBe('billing');
+    expect(buildDependencyGraph([entity]).nodes[0].domain).toBe('billing');
+    expect(getEntityDomain(makeEntity('other'))).toBeNull();
+  });
+
+  it('carries declared aliases onto entity nodes', () => {
+    const graph = buildDependencyGraph([
+      makeEntity('auth', { aliases: ['token-service'] }),
+      makeEntity('web'),
+    ]);
+    expect(graph.nodes.map((node) => node.aliases)).toEqual([
+      ['token-service'],
+      undefined,
+    ]);
+  });
+
+  it('resolves dependencies through aliases and renames', () => {
+    const graph = buildDependencyGraph(
+      [
+        makeEntity('checkout', {
+          dependencies: {
+            services: ['users', 'Accounts', 'token-service', 'mailer'],
+            external_apis: ['stripe-api'],
+          },
+        }),
+        makeEntity('user-service'),
+        makeEntity('auth', { aliases: ['token-service'] }),
+      ],
+      {
+        aliases: { 'user-service': ['users'], stripe: ['stripe-api'] },
+        renames: [
+          { from: 'accounts', to: 'identity' },
+          { from: 'identity', to: 'user-service' },
+        ],
+      },
+    );
+    expect(graph.edges.map((edge) => edge.to)).toEqual([
+      'id-user-service',
+      'id-auth',
+      'external:service:mailer',
+      'external:external_api:stripe',
+    ]);
+    expect(graph.nodes[1].aliases).toEqual(['accounts', 'identity', 'users']);
+  });
+
+  it('prefers an entity name over another entity alias', () => {
+    const graph = buildDependencyGraph([
+      makeEntity('checkout', { dependencies: { services: ['billing'] } }),
+      makeEntity('ledger', { aliases: ['billing'] }),
+      makeEntity('billing'),
+    ]);
+    expect(graph.edges).toEqual([
+      {
+        from: 'id-checkout',
+        to: 'id-billing',
+        kind: 'service',
+        provenance: 'declared',
+        confidence: 1,
+      },
+    ]);
+  });
+
+  it('assigns nodes to the innermost workspace member', () => {
+    const graph = buildDependencyGraph(
+      [
+        makeEntity('ledger', { dependencies: { databases: ['postgres'] } }),
+        { ...makeEntity('tool'), filePath: 'scripts/tool.ts' },
+      ],
+      {
+        workspaces: [
+          { name: '//', kind: 'bazel', path: '.' },
+          { name: '@acme/ledger', kind: 'pnpm', path: 'src' },
+        ],
+      },
+    );
+    const byName = new Map(graph.nodes.map((node) => [node.name, node]));
+    expect(byName.get('ledger')?.workspace).toBe('@acme/ledger');
+    expect(byName.get('tool')?.workspace).toBe('//');
+    expect(byName.get('postgres')?.workspace).toBeNull();
+    expect(buildDependencyGraph([makeEntity('x')]).nodes[0].workspace).toBe(
+      null,
+    );
+  });
+
+  it('prefixes node ids with a namespace but not external stubs', () => {
+    const graph = buildDependencyGraph(
+      [
+        makeEntity('checkout', {
+          dependencies: { services: ['payments'], databases: ['orders-db'] },
+        }),
+        makeEntity('payments'),
+      ],
+      { namespace: 'acme/shop' },
+    );
+    expect(graph.nodes.map((node) => [node.id, node.namespace])).toEqual([
+      ['acme/shop:id-checkout', 'acme/shop'],
+      ['acme/shop:id-payments', 'acme/shop'],
+      ['external:database:orders-db', undefined],
+    ]);
+    expect(graph.edges.map((edge) => [edge.from, edge.to])).toEqual([
+      ['acme/shop:id-checkout', 'acme/shop:id-payments'],
+      ['acme/shop:id-checkout', 'external:database:orders-db'],
+    ]);
+  });
+
+  it('adds Go package nodes and import edges, marking unannotated ones', () => {
+    const graph = buildDependencyGraph(
+      [{ ...makeEntity('server', { domain: 'api' }), filePath: 'api/srv.go' }],
+      {
+        workspaces: [{ name: 'example.com/api', kind: 'go', path: 'api' }],
+        goPackages: [
+          {
+            importPath: 'example.com/api',
+            name: 'api',
+            dir: 'api',
+            module: 'example.com/api',
+            imports: ['example.com/api/store', 'example.com/missing'],
+          },
+          {
+            importPath: 'example.com/api/store',
+            name: 'store',
+            dir: 'api/store',
+            module: 'example.com/api',
+            imports: [],
+          },
+        ],
+      },
+    );
+    const api = graph.nodes.find(
+      (node) => node.id === goPackageNodeId('example.com/api'),
+    );
+    const store = graph.nodes.find(
+      (node) => node.id === goPackageNodeId('example.com/api/store'),
+    );
+    expect(api).toMatchObject({
+      annotated: true,
+      owner: 'team-a',
+      domain: 'api',
+      workspace: 'example.com/api',
+    });
+    expect(store).toMatchObject({ annotated: false, owner: null });
+    expect(graph.edges).toEqual([
+      {
+        from: 'go:example.com/api',
+        to: 'go:example.com/api/store',
+        kind: 'import',
+        provenance: 'import',
+        confidence: 1,
+      },
+    ]);
+    expect(
+      graph.nodes.find((node) => node.name === 'server')?.annotated,
+    ).toBeUndefined();
+  });
+});
+
+describe('vendored and submodule entities', () => {
+  it('makes vendored entities external and namespaces submodules', () => {
+    const graph = buildDependencyGraph(
+      [
+        {
+          ...makeEntity('api', { dependencies: { services: ['auth'] } }),
+          filePath: 'services/api.ts',
+        },
+        { ...makeEntity('auth'), filePath: 'libs/auth/src/auth.ts' },
+        { ...makeEntity('lodash'), filePath: 'vendor/lodash/index.ts' },
+      ],
+      {
+        namespace: 'acme/app',
+        submodules: [
+          {
+            name: 'libs/auth',
+            path: 'libs/auth',
+            url: 'git@github.com:acme/auth.git',
+            namespace: 'acme/auth',
+          },
+        ],
+      },
+    );
+    expect(
+      graph.nodes.map(({ id, namespace, external }) => ({
+        id,
+        namespace,
+        external,
+      })),
+    ).toEqual([
+      { id: 'acme/app:id-api', namespace: 'acme/app', external: false },
+      { id: 'acme/auth:id-auth', namespace: 'acme/auth', external: false },
+      { id: 'id-lodash', namespace: undefined, external: true },
+    ]);
+    expect(graph.edges.map(({ from, to }) => [from, to])).toEqual([
+      ['acme/app:id-api', 'acme/auth:id-auth'],
+    ]);
+  });
+});
+
+describe('description references', () => {
+  const login = {
+    ...makeEntity('HandleLogin', { entityType: 'function', domain: 'auth' }),
+    filePath: 'src/auth/handlers.ts',
+  };
+  const adminLogin = {
+    ...makeEntity('HandleLogin', { entityType: 'function' }),
+    id: 'id-admin-login',
+    filePath: 'src/admin/handlers.ts',
+  };
+  const users = {
+    ...makeEntity('UserService'),
+    description: 'Stores accounts; sessions come from [[auth.HandleLogin]]',
+  };
+  const entities = [login, adminLogin, users];
+
+  it('finds [[target]] references with their offsets', () => {
+    expect(
+      findDescriptionReferences('Calls [[user-service]] and [[ auth.Login ]]'),
+    ).toEqual([
+      { target: 'user-service', start: 6, end: 22 },
+      { target: 'auth.Login', start: 27, end: 43 },
+    ]);
+    expect(findDescriptionReferences('Plain [text] and [[]]')).toEqual([]);
+  });
+
+  it('resolves names, qualified names, and loosely written names', () => {
+    const resolve = createReferenceResolver(entities);
+    expect(resolve('userservice')?.id).toBe('id-UserService');
+    expect(resolve('admin.HandleLogin')?.id).toBe('id-admin-login');
+    expect(resolve('auth.HandleLogin')?.id).toBe('id-HandleLogin');
+    expect(resolve('user-service')?.id).toBe('id-UserService');
+    expect(resolve('billing.HandleLogin')).toBeUndefined();
+  });
+
+  it('adds reference edges only when asked', () => {
+    expect(buildDependencyGraph(entities).edges).toEqual([]);
+    const graph = buildDependencyGraph(
+      [
+        ...entities,
+        {
+          ...makeEntity('Checkout'),
+          description: 'Charges [[UserService]] accounts via [[Stripe]]',
+        },
+      ],
+      { references: true },
+    );
+    expect(graph.edges).toEqual([
+      {
+        from: 'id-UserService',
+        to: 'id-HandleLogin',
+        kind: 'reference',
+        provenance: 'declared',
+        confidence: 1,
+      },
+      {
+        from: 'id-Checkout',
+        to: 'id-UserService',
+        kind: 'reference',
+        provenance: 'declared',
+        confidence: 1,
+      },
+    ]);
+    expect(graph.nodes).toHaveLength(4);
+  });
+});
@@ -0,0 +1,95 @@
+import { describe, it, expect } from 'vitest';
+import { checkGraphConstraints } from '../graph-constraints.js';
+import { externalNode } from '../graph-builder.js';
+import type { DependencyGraph, GraphNode } from '../types.js';
+
+function node(id: string, overrides: Partial<GraphNode> = {}): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: 'team',
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+const shop: DependencyGraph = {
+  nodes: [
+    node('acme/shop:billing', {
+      name: 'billing',
+      namespace: 'acme/shop',
+      filePath: 'src/billing.ts',
+    }),
+    node('acme/shop:charge', { name: 'charge', entityType: 'function' }),
+    externalNode('service', 'ledger'),
+  ],
+  edges: [],
+};
+
+const finance: DependencyGraph = {
+  nodes: [
+    node('acme/finance:billing', {
+      name: 'Billing',
+      namespace: 'acme/finance',
+      filePath: 'services/billing/main.go',
+    }),
+    node('acme/finance:charge', { name: 'charge', entityType: 'function' }),
+    node('acme/finance:ledger', { name: 'ledger', owner: null }),
+  ],
+  edges: [],
+};
+
+describe('checkGraphConstraints', () => {
+  it('locates every copy of a service name used in several graphs', () => {
+    expect(checkGraphConstraints([shop, finance])).toEqual([
+      {
+        rule: 'unique-name',
+        message: '2 service nodes are named "billing"',
+        locations: [
+          {
+            graph: 0,
+            node: 'acme/shop:billing',
+            namespace: 'acme/shop',
+            filePath: 'src/billing.ts',
+            owner: 'team',
+          },
+          {
+            graph: 1,
+            node: 'acme/finance:billing',
+            namespace: 'acme/finance',
+            filePath: 'services/billing/main.go',
+            owner: 'team',
+          },
+        ],
+      },
+    ]);
+  });
+
+  it('checks the configured types and owners', () => {
+    const conflicts = checkGraphConstraints([shop, finance], {
+      uniqueNames: ['function'],
+      requireOwner: true,
+    });
+    expect(conflicts.map((conflict) => conflict.message)).toEqual([
+      '2 function nodes are named "charge"',
+      'ledger has no owner',
+    ]);
+  });
+
+  it('reports copies of one node that disagree on its owner', () => {
+    const later: DependencyGraph = {
+      nodes: [node('acme/shop:billing', { name: 'billing', owner: 'ops' })],
+      edges: [],
+    };
+    const [conflict] = checkGraphConstraints([shop, later]);
+    expect(conflict.rule).toBe('single-owner');
+    expect(conflict.message).toBe('billing has 2 owners: ops, team');
+    expect(conflict.locations.map((location) => location.graph)).toEqual([
+      0, 1,
+    ]);
+  });
+});
@@ -0,0 +1,137 @@
+import { describe, it, expect } from 'vitest';
+import type { DependencyGraph, GraphEdge, GraphNode } from '../types.js';
+import {
+  checkCycleBudgets,
+  cycleMemberKey,
+  findDependencyCycles,
+} from '../graph-cycles.js';
+
+function node(name: string, domain: string | null = null): GraphNode {
+  return {
+    id: `id-${name}`,
+    name,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${name}.ts`,
+    owner: null,
+    domain,
+    workspace: null,
+  };
+}
+
+function edge(from: string, to: string): GraphEdge {
+  return {
+    from: `id-${from}`,
+    to: `id-${to}`,
+    kind: 'service',
+    provenance: 'declared',
+    confidence: 1,
+  };
+}
+
+const graph: DependencyGraph = {
+  nodes: [
+    node('cart', 'ordering'),
+    node('checkout', 'ordering'),
+    node('pricing', 'ordering'),
+    node('ledger', 'billing'),
+    node('invoices', 'billing'),
+    node('notifier'),
+    {
+      ...node('stripe'),
+      id: 'external:external_api:stripe',
+      external: true,
+      filePath: null,
+    },
+  ],
+  edges: [
+    // ordering: checkout -> cart -> pricing -> checkout, plus cart <-> checkout
+    edge('checkout', 'cart'),
+    edge('cart', 'pricing'),
+    edge('pricing', 'checkout'),
+    edge('cart', 'checkout'),
+    edge('checkout', 'cart'),
+    // billing <-> notifier
+    edge('ledger', 'invoices'),
+    edge('invoices', 'notifier'),
+    edge('notifier', 'ledger'),
+    edge('checkout', 'ledger'),
+    { ...edge('ledger', 'stripe'), to: 'external:external_api:stripe' },
+  ],
+};
+
+const names = (edges: readonly GraphEdge[]) =>
+  edges.map((e) => `${e.from.slice(3)}->${e.to.slice(3)}`);
+
+describe('findDependencyCycles', () => {
+  it('groups mutually reachable nodes and finds edges that break them', () => {
+    const cycles = findDependencyCycles(graph);
+
+    expect(cycles.map((c) => c.members.map((m) => m.name))).toEqual([
+      ['cart', 'checkout', 'pricing'],
+      ['invoices', 'ledger', 'notifier'],
+    ]);
+    const [ordering, billing] = cycles;
+    expect(billing.edges).toBe(3);
+    expect(billing.breakingEdges).toHaveLength(1);
+    expect(ordering.edges).toBe(4);
+    expect(names(ordering.breakingEdges)).toEqual(['checkout->cart']);
+  });
+
+  it('breaks a group of thousands of nodes without rescanning it', () => {
+    const size = 5000;
+    const ring = Array.from({ length: size }, (_, i) => `n${i}`);
+    const [cycle, ...rest] = findDependencyCycles({
+      ...graph,
+      nodes: ring.map((name) => node(name)),
+      edges: ring.map((name, i) => edge(name, ring[(i + 1) % size])),
+    });
+    expect(rest).toEqual([]);
+    expect(cycle.members).toHaveLength(size);
+    expect(cycle.breakingEdges).toHaveLength(1);
+  });
+
+  it('reports nothing for an acyclic graph', () => {
+    const acyclic = { ...graph, edges: [edge('checkout', 'cart')] };
+    expect(findDependencyCycles(acyclic)).toEqual([]);
+  });
+});
+
+describe('checkCycleBudgets', () => {
+  const cycles = findDependencyCycles(graph);
+  const ordering = cycles[0].members.map(cycleMemberKey);
+
+  it('fails every new cycle in a domain with no budget left', () => {
+    const report = checkCycleBudgets(cycles);
+    expect(report.overBudget).toBe(2);
+    expect(report.domains).toEqual([
+      { domain: '(no domain)', budget: 0, newCycles: 1 },
+      { domain: 'billing', budget: 0, newCycles: 1 },
+      { domain: 'ordering', budget: 0, newCycles: 1 },
+    ]);
+  });
+
+  it('allows new cycles within budget and skips baselined ones', () => {
+    const report = checkCycleBudgets(cycles, {
+      budgets: { billing: 1 },
+      defaultBudget: 1,
+      baseline: [[...ordering, 'src/gone.ts:gone']],
+    });
+    expect(report.overBudget).toBe(0);
+    expect(report.cycles.map((c) => c.baselined)).toEqual([true, false]);
+    expect(report.domains.find((d) => d.domain === 'ordering')).toEqual({
+      domain: 'ordering',
+      budget: 1,
+      newCycles: 0,
+    });
+  });
+
+  it('counts a cycle against each domain it touches', () => {
+    const report = checkCycleBudgets(cycles, {
+      budgets: { billing: 1 },
+      baseline: [ordering],
+    });
+    expect(report.cycles[1].domains).toEqual(['(no domain)', 'billing']);
+    expect(report.cycles[1].overBudget).toBe(true);
+  });
+});
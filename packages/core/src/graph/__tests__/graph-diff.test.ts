@@ -0,0 +1,141 @@
+import { describe, it, expect } from 'vitest';
+import { diffGraphs } from '../graph-diff.js';
+import { traverseGraph } from '../graph-traversal.js';
+import type { DependencyGraph, GraphEdge, GraphNode } from '../types.js';
+
+function node(id: string, owner: string | null = 'team-a'): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner,
+    domain: null,
+    workspace: null,
+  };
+}
+
+function edge(
+  from: string,
+  to: string,
+  overrides: Partial<GraphEdge> = {},
+): GraphEdge {
+  return {
+    from,
+    to,
+    kind: 'service',
+    provenance: 'declared',
+    confidence: 1,
+    ...overrides,
+  };
+}
+
+function ids(steps: Iterable<{ readonly node: GraphNode }>): string[] {
+  return [...steps].map((step) => step.node.id);
+}
+
+describe('diffGraphs', () => {
+  it('reports added, updated, and removed nodes', () => {
+    const previous: DependencyGraph = {
+      nodes: [node('a'), node('b'), node('c')],
+      edges: [],
+    };
+    const next: DependencyGraph = {
+      nodes: [node('a'), node('b', 'team-b'), node('d')],
+      edges: [],
+    };
+    expect(
+      diffGraphs(previous, next).map((e) => `${e.type}:${e.node.id}`),
+    ).toEqual(['node_updated:b', 'node_added:d', 'node_removed:c']);
+  });
+
+  it('treats a change in outgoing edges as an update', () => {
+    const previous: DependencyGraph = {
+      nodes: [node('a'), node('b')],
+      edges: [],
+    };
+    const next: DependencyGraph = {
+      nodes: [node('a'), node('b')],
+      edges: [edge('a', 'b')],
+    };
+    expect(diffGraphs(previous, next)).toEqual([
+      { type: 'node_updated', node: node('a') },
+    ]);
+  });
+
+  it('reports a node whose alias was removed as renamed', () => {
+    const renamed = { ...node('user-service'), aliases: ['users'] };
+    const previous: DependencyGraph = {
+      nodes: [node('users'), node('legacy')],
+      edges: [],
+    };
+    const next: DependencyGraph = { nodes: [renamed], edges: [] };
+    expect(diffGraphs(previous, next)).toEqual([
+      { type: 'node_renamed', node: renamed, previous: node('users') },
+      { type: 'node_removed', node: node('legacy') },
+    ]);
+  });
+
+  it('reports a node an index run renamed as renamed', () => {
+    const previous: DependencyGraph = { nodes: [node('a')], edges: [] };
+    const next: DependencyGraph = { nodes: [node('b')], edges: [] };
+    expect(diffGraphs(previous, next, [{ from: 'a', to: 'b' }])).toEqual([
+      { type: 'node_renamed', node: node('b'), previous: node('a') },
+    ]);
+  });
+
+  it('returns nothing for identical graphs', () => {
+    const graph: DependencyGraph = {
+      nodes: [node('a'), node('b')],
+      edges: [edge('a', 'b')],
+    };
+    expect(diffGraphs(graph, { ...graph, edges: [...graph.edges] })).toEqual(
+      [],
+    );
+  });
+});
+
+describe('traverseGraph', () => {
+  const graph: DependencyGraph = {
+    nodes: [node('a'), node('b'), node('c'), node('d')],
+    edges: [edge('a', 'b'), edge('b', 'c'), edge('d', 'a')],
+  };
+
+  it('follows dependencies breadth-first with depths', () => {
+    expect(
+      [...traverseGraph(graph, 'a')].map((s) => [s.node.id, s.depth]),
+    ).toEqual([
+      ['b', 1],
+      ['c', 2],
+    ]);
+  });
+
+  it('finds dependents and honours maxDepth', () => {
+    expect(
+      ids(traverseGraph(graph, 'c', { direction: 'incoming', maxDepth: 2 })),
+    ).toEqual(['b', 'a']);
+  });
+
+  it('filters by edge kind and ignores unknown start nodes', () => {
+    expect(ids(traverseGraph(graph, 'a', { kinds: ['import'] }))).toEqual([]);
+    expect(ids(traverseGraph(graph, 'missing'))).toEqual([]);
+  });
+
+  it('follows only edges with the given provenance and confidence', () => {
+    const inferred: DependencyGraph = {
+      nodes: graph.nodes,
+      edges: [
+        edge('a', 'b', { provenance: 'call', confidence: 0.6 }),
+        edge('a', 'c'),
+        edge('c', 'd', { provenance: 'call', confidence: 0.9 }),
+      ],
+    };
+    expect(ids(traverseGraph(inferred, 'a', { minConfidence: 0.8 }))).toEqual(
+      ['c', 'd'],
+    );
+    expect(
+      ids(traverseGraph(inferred, 'a', { provenance: ['declared'] })),
+    ).toEqual(['c']);
+  });
+});
@@ -0,0 +1,95 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Dependencies } from '../../types/entity.js';
+import {
+  dependencyEnvironments,
+  environmentDependencies,
+  selectEnvironment,
+} from '../graph-environments.js';
+
+function makeEntity(name: string, dependencies?: Dependencies): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'team-a',
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `${name} description`,
+      dependencies,
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const deps: Dependencies = {
+  services: ['auth'],
+  databases: ['redis'],
+  environments: {
+    prod: { databases: ['postgres-main', 'redis'] },
+    dev: { databases: ['sqlite'], external_apis: ['stripe-sandbox'] },
+  },
+};
+
+describe('environmentDependencies', () => {
+  it('adds the lists of one environment to the shared ones', () => {
+    expect(environmentDependencies(deps, 'prod')).toEqual({
+      services: ['auth'],
+      databases: ['redis', 'postgres-main'],
+    });
+    expect(environmentDependencies(deps, 'staging')).toEqual({
+      services: ['auth'],
+      databases: ['redis'],
+    });
+  });
+
+  it('adds every environment, in name order, without one', () => {
+    expect(environmentDependencies(deps)).toEqual({
+      services: ['auth'],
+      external_apis: ['stripe-sandbox'],
+      databases: ['redis', 'sqlite', 'postgres-main'],
+    });
+  });
+
+  it('keeps an entry listed twice as first listed, annotated or not', () => {
+    const annotated = { name: 'auth', protocol: 'grpc' };
+    expect(
+      environmentDependencies({
+        services: [annotated],
+        environments: { prod: { services: ['auth', 'billing'] } },
+      }),
+    ).toEqual({ services: [annotated, 'billing'] });
+  });
+});
+
+describe('selectEnvironment', () => {
+  it('replaces the dependencies with those of the environment', () => {
+    const selected = selectEnvironment(makeEntity('sessions', deps), 'dev');
+    expect(selected.metadata).toMatchObject({
+      dependencies: {
+        services: ['auth'],
+        external_apis: ['stripe-sandbox'],
+        databases: ['redis', 'sqlite'],
+      },
+    });
+  });
+
+  it('lists the environments entities declare', () => {
+    expect(
+      dependencyEnvironments([makeEntity('a', deps), makeEntity('b')]),
+    ).toEqual(['dev', 'prod']);
+  });
+});
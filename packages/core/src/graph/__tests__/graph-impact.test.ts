@@ -0,0 +1,45 @@
+import { describe, it, expect } from 'vitest';
+import { selectImpactedUnits } from '../graph-impact.js';
+import type { DependencyGraph, GraphNode } from '../types.js';
+
+function node(id: string, filePath: string | null = null): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: filePath ? 'service' : null,
+    external: false,
+    filePath,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+describe('selectImpactedUnits', () => {
+  const units = [{ id: 'unit:a' }, { id: 'unit:b' }, { id: 'unit:c' }];
+  const graph: DependencyGraph = {
+    nodes: [node('unit:a'), node('unit:b'), node('svc', 'b/svc.ts')],
+    edges: [{ from: 'svc', to: 'unit:a', kind: 'import' }],
+  };
+  // Units own the top-level directory named like them
+  const unitOf = (file: string) =>
+    units.find((unit) => unit.id === `unit:${file[0]}`);
+  const mapping = {
+    unitOfFile: unitOf,
+    unitOfNode: (n: GraphNode) => unitOf(n.filePath!),
+  };
+
+  it('walks dependents and the units of entities they reach', () => {
+    const selection = selectImpactedUnits(graph, units, mapping, ['a/x.ts']);
+    expect(selection.units.map((u) => u.id)).toEqual(['unit:a', 'unit:b']);
+    expect(selection.changed.map((u) => u.id)).toEqual(['unit:a']);
+  });
+
+  it('selects every unit when a global file changes', () => {
+    const selection = selectImpactedUnits(graph, units, mapping, ['c/y.pb'], {
+      globalFiles: ['*.pb'],
+    });
+    expect(selection).toMatchObject({ full: true, reason: 'c/y.pb changed' });
+    expect(selection.units).toHaveLength(3);
+  });
+});
@@ -0,0 +1,115 @@
+import { describe, it, expect } from 'vitest';
+import { findGraphNodes, findShortestPaths } from '../graph-paths.js';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  EdgeProvenance,
+  GraphEdge,
+  GraphNode,
+} from '../types.js';
+
+function node(id: string, aliases?: readonly string[]): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+    ...(aliases ? { aliases } : {}),
+  };
+}
+
+function edge(
+  from: string,
+  to: string,
+  kind: DependencyKind = 'service',
+  provenance: EdgeProvenance = 'declared',
+  confidence = 1,
+): GraphEdge {
+  return { from, to, kind, provenance, confidence };
+}
+
+// checkout -> orders -> postgres, checkout -> cart -> orders,
+// checkout -> cart -> inventory -> postgres
+const graph: DependencyGraph = {
+  nodes: ['checkout', 'orders', 'cart', 'inventory', 'postgres'].map((id) =>
+    node(id, id === 'postgres' ? ['postgres-main'] : undefined),
+  ),
+  edges: [
+    edge('checkout', 'orders'),
+    edge('orders', 'postgres', 'database'),
+    edge('checkout', 'cart', 'import', 'import'),
+    edge('cart', 'orders', 'service', 'call', 0.6),
+    edge('cart', 'inventory'),
+    edge('inventory', 'postgres', 'database'),
+  ],
+};
+
+function ids(paths: ReturnType<typeof findShortestPaths>): string[][] {
+  return paths.map((path) => path.nodes.map((n) => n.id));
+}
+
+describe('findShortestPaths', () => {
+  it('returns the k shortest paths, fewest hops first', () => {
+    const paths = findShortestPaths(graph, 'checkout', 'postgres', { k: 5 });
+    expect(ids(paths)).toEqual([
+      ['checkout', 'orders', 'postgres'],
+      ['checkout', 'cart', 'inventory', 'postgres'],
+      ['checkout', 'cart', 'orders', 'postgres'],
+    ]);
+    expect(paths[2].edges.map((e) => e.provenance)).toEqual([
+      'import',
+      'call',
+      'declared',
+    ]);
+  });
+
+  it('stops at k', () => {
+    expect(
+      ids(findShortestPaths(graph, 'checkout', 'postgres', { k: 1 })),
+    ).toEqual([['checkout', 'orders', 'postgres']]);
+  });
+
+  it('follows only the edges the filter keeps', () => {
+    const paths = findShortestPaths(graph, 'checkout', 'postgres', {
+      provenance: ['declared'],
+    });
+    expect(ids(paths)).toEqual([['checkout', 'orders', 'postgres']]);
+    expect(
+      findShortestPaths(graph, 'cart', 'orders', { minConfidence: 0.7 })
+        .length,
+    ).toBe(0);
+  });
+
+  it('goes by the most confident of parallel edges', () => {
+    const parallel: DependencyGraph = {
+      nodes: [node('a'), node('b')],
+      edges: [
+        edge('a', 'b', 'import', 'import', 0.5),
+        edge('a', 'b', 'service', 'declared', 1),
+      ],
+    };
+    const [path] = findShortestPaths(parallel, 'a', 'b');
+    expect(path.edges).toEqual([edge('a', 'b')]);
+  });
+
+  it('is empty without a path or a node', () => {
+    expect(findShortestPaths(graph, 'postgres', 'checkout')).toEqual([]);
+    expect(findShortestPaths(graph, 'missing', 'postgres')).toEqual([]);
+  });
+});
+
+describe('findGraphNodes', () => {
+  it('matches ids, names, aliases, and loose names', () => {
+    expect(findGraphNodes(graph, 'postgres-main').map((n) => n.id)).toEqual([
+      'postgres',
+    ]);
+    expect(findGraphNodes(graph, 'Check_Out').map((n) => n.id)).toEqual([
+      'checkout',
+    ]);
+    expect(findGraphNodes(graph, 'billing')).toEqual([]);
+  });
+});
@@ -0,0 +1,112 @@
+import { describe, it, expect } from 'vitest';
+import { externalNode } from '../graph-builder.js';
+import {
+  filterGraphEdges,
+  parseEdgeProvenances,
+  withProvenance,
+} from '../graph-provenance.js';
+import type { DependencyGraph, EdgeFilter, GraphNode } from '../types.js';
+
+function node(id: string): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+describe('withProvenance', () => {
+  it('defaults edges from older exports by kind', () => {
+    expect(withProvenance({ from: 'a', to: 'b', kind: 'import' })).toEqual({
+      from: 'a',
+      to: 'b',
+      kind: 'import',
+      provenance: 'import',
+      confidence: 1,
+    });
+    expect(
+      withProvenance({
+        from: 'a',
+        to: 'b',
+        kind: 'service',
+        provenance: 'call',
+        confidence: 0.5,
+      }),
+    ).toMatchObject({ provenance: 'call', confidence: 0.5 });
+  });
+
+  it('keeps the version an edge requires', () => {
+    expect(
+      withProvenance({ from: 'a', to: 'b', kind: 'service', requires: '^2' }),
+    ).toMatchObject({ provenance: 'declared', requires: '^2' });
+  });
+});
+
+describe('filterGraphEdges', () => {
+  const stripe = externalNode('external_api', 'stripe');
+  const graph: DependencyGraph = {
+    nodes: [node('checkout'), node('ledger'), stripe],
+    edges: [
+      {
+        from: 'checkout',
+        to: 'ledger',
+        kind: 'service',
+        provenance: 'declared',
+        confidence: 1,
+      },
+      {
+        from: 'checkout',
+        to: stripe.id,
+        kind: 'external_api',
+        provenance: 'call',
+        confidence: 0.7,
+      },
+    ],
+  };
+
+  it('keeps matching edges and drops external stubs left unused', () => {
+    const declared = filterGraphEdges(graph, { provenance: ['declared'] });
+    expect(declared.edges.map((edge) => edge.to)).toEqual(['ledger']);
+    expect(declared.nodes.map((n) => n.id)).toEqual(['checkout', 'ledger']);
+    expect(filterGraphEdges(graph, { minConfidence: 0.7 })).toEqual(graph);
+    expect(filterGraphEdges(graph, { minConfidence: 0.8 }).edges).toHaveLength(
+      1,
+    );
+  });
+
+  it('keeps edges annotated with a protocol or criticality', () => {
+    const annotated: DependencyGraph = {
+      ...graph,
+      edges: [
+        { ...graph.edges[0], protocol: 'grpc', criticality: 'high' },
+        { ...graph.edges[1], protocol: 'https' },
+      ],
+    };
+    const to = (filter: EdgeFilter) =>
+      filterGraphEdges(annotated, filter).edges.map((edge) => edge.to);
+    expect(to({ protocols: ['grpc', 'http'] })).toEqual(['ledger']);
+    expect(to({ criticalities: ['critical', 'high'] })).toEqual(['ledger']);
+    expect(to({ criticalities: ['low'] })).toEqual([]);
+  });
+});
+
+describe('parseEdgeProvenances', () => {
+  it('parses comma-separated provenances', () => {
+    expect(parseEdgeProvenances('declared, import')).toEqual([
+      'declared',
+      'import',
+    ]);
+  });
+
+  it('rejects unknown and empty lists', () => {
+    expect(() => parseEdgeProvenances('declared,guess')).toThrow(
+      'Invalid provenance "guess"',
+    );
+    expect(() => parseEdgeProvenances(',')).toThrow('Invalid provenance ","');
+  });
+});
@@ -0,0 +1,115 @@
+import { describe, it, expect } from 'vitest';
+import { externalNode } from '../graph-builder.js';
+import { graphSignificance, pruneGraph } from '../graph-prune.js';
+import type { DependencyGraph, GraphEdge, GraphNode } from '../types.js';
+
+function node(
+  id: string,
+  entityType: GraphNode['entityType'],
+  filePath = `src/${id}.ts`,
+): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType,
+    external: false,
+    filePath,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+function edge(from: string, to: string, confidence = 1): GraphEdge {
+  return { from, to, kind: 'import', provenance: 'import', confidence };
+}
+
+const stripe = externalNode('external_api', 'stripe');
+
+describe('graphSignificance', () => {
+  it('counts edges in either direction', () => {
+    const graph: DependencyGraph = {
+      nodes: [node('a', 'module'), node('b', 'module'), node('c', 'module')],
+      edges: [edge('a', 'b'), edge('c', 'b')],
+    };
+    expect([...graphSignificance(graph)]).toEqual([
+      ['a', 1],
+      ['b', 2],
+      ['c', 1],
+    ]);
+  });
+});
+
+describe('pruneGraph', () => {
+  const graph: DependencyGraph = {
+    nodes: [
+      node('utils', 'module', 'src/utils.ts'),
+      node('formatDate', 'function', 'src/utils.ts'),
+      node('parseDate', 'function', 'src/utils.ts'),
+      node('checkout', 'service'),
+      node('orphan', 'function'),
+      stripe,
+    ],
+    edges: [
+      edge('checkout', 'utils'),
+      edge('checkout', 'parseDate'),
+      edge('formatDate', 'checkout', 0.5),
+      edge('utils', 'checkout'),
+      edge('formatDate', stripe.id),
+    ],
+  };
+
+  it('folds functions nothing depends on into their module', () => {
+    const { graph: pruned, decisions } = pruneGraph(graph, {
+      collapseFunctions: true,
+    });
+    expect(pruned.nodes.map((n) => n.id)).not.toContain('formatDate');
+    expect(pruned.edges).toEqual([
+      edge('checkout', 'utils'),
+      edge('checkout', 'parseDate'),
+      edge('utils', 'checkout'),
+      edge('utils', stripe.id),
+    ]);
+    expect(decisions).toEqual([
+      {
+        node: 'formatDate',
+        name: 'formatDate',
+        reason: 'leaf-function',
+        significance: 2,
+        into: 'utils',
+      },
+    ]);
+  });
+
+  it('drops nodes below the threshold and the stubs they leave behind', () => {
+    const { graph: pruned, decisions } = pruneGraph(
+      {
+        nodes: [node('checkout', 'service'), node('refund', 'service'), stripe],
+        edges: [edge('checkout', stripe.id), edge('refund', stripe.id)],
+      },
+      { minSignificance: 2 },
+    );
+    expect(pruned).toEqual({ nodes: [], edges: [] });
+    expect(decisions.map((d) => [d.node, d.reason, d.significance])).toEqual([
+      ['checkout', 'below-significance', 1],
+      ['refund', 'below-significance', 1],
+      [stripe.id, 'orphaned', 0],
+    ]);
+  });
+
+  it('keeps the most significant nodes within the budget', () => {
+    const { graph: pruned, decisions } = pruneGraph(graph, { maxNodes: 2 });
+    expect(pruned.nodes.map((n) => n.id)).toEqual(['utils', 'checkout']);
+    expect(pruned.edges).toEqual([
+      edge('checkout', 'utils'),
+      edge('utils', 'checkout'),
+    ]);
+    expect(decisions.map((d) => d.reason)).toEqual([
+      'over-budget',
+      'over-budget',
+      'over-budget',
+      'over-budget',
+    ]);
+    expect(pruneGraph(graph, {})).toEqual({ graph, decisions: [] });
+  });
+});
@@ -0,0 +1,127 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Dependencies, EntityType } from '../../types/entity.js';
+import { buildDependencyGraph } from '../graph-builder.js';
+import { deriveEdges } from '../graph-rules.js';
+
+function makeEntity(
+  name: string,
+  options: {
+    readonly entityType?: EntityType;
+    readonly owner?: string;
+    readonly dependencies?: Dependencies;
+  } = {},
+): StoredEntity {
+  const entityType = options.entityType ?? 'service';
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType,
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: options.owner ?? 'team-a',
+    status: 'stable',
+    metadata: {
+      type: entityType,
+      description: `${name} description`,
+      dependencies: options.dependencies,
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const entities = [
+  makeEntity('orders', {
+    dependencies: { services: ['payments'], databases: ['postgres-main'] },
+  }),
+  makeEntity('billing', { dependencies: { databases: ['postgres-main'] } }),
+  makeEntity('ledger', {
+    entityType: 'module',
+    dependencies: { databases: ['postgres-main'] },
+  }),
+  makeEntity('payments', {
+    owner: 'team-b',
+    dependencies: { databases: ['payments-db'] },
+  }),
+];
+
+describe('deriveEdges', () => {
+  it('joins entities that share a value once, with derived provenance', () => {
+    const graph = buildDependencyGraph(entities, {
+      edgeRules: [
+        {
+          kind: 'shares_datastore',
+          shared: 'dependencies.databases',
+          from: { type: 'service' },
+          to: { type: 'service' },
+        },
+      ],
+    });
+    expect(
+      graph.edges.filter((edge) => edge.provenance === 'derived'),
+    ).toEqual([
+      {
+        from: 'id-billing',
+        to: 'id-orders',
+        kind: 'shares_datastore',
+        provenance: 'derived',
+        confidence: 1,
+      },
+    ]);
+    expect(graph.edges).toContainEqual(
+      expect.objectContaining({
+        from: 'id-orders',
+        to: 'external:database:postgres-main',
+        provenance: 'declared',
+      }),
+    );
+  });
+
+  it('joins the ends of each path of edge kinds', () => {
+    const graph = buildDependencyGraph(entities);
+    const derived = deriveEdges(graph, entities, [
+      {
+        kind: 'touches_datastore',
+        path: ['service', 'database'],
+        confidence: 0.8,
+      },
+    ]);
+    expect(derived).toEqual([
+      {
+        from: 'id-orders',
+        to: 'external:database:payments-db',
+        kind: 'touches_datastore',
+        provenance: 'derived',
+        confidence: 0.8,
+      },
+    ]);
+  });
+
+  it('joins only the ends each rule selects, in the direction given', () => {
+    const graph = buildDependencyGraph(entities);
+    const derived = deriveEdges(graph, entities, [
+      {
+        kind: 'reads_ledger_of',
+        shared: 'dependencies.databases',
+        from: { type: 'module' },
+        to: { owner: 'team-a' },
+      },
+      // Rules see only built edges, not those another rule derives
+      { kind: 'chained', path: ['reads_ledger_of', 'service'] },
+    ]);
+    expect(derived.map((edge) => `${edge.from} -> ${edge.to}`)).toEqual([
+      'id-ledger -> id-orders',
+      'id-ledger -> id-billing',
+    ]);
+  });
+});
@@ -0,0 +1,34 @@
+import { describe, it, expect } from 'vitest';
+import { createServiceMatcher } from '../graph-services.js';
+import type { GraphNode } from '../types.js';
+
+function node(name: string, overrides: Partial<GraphNode> = {}): GraphNode {
+  return {
+    id: `id-${name}`,
+    name,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${name}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+describe('createServiceMatcher', () => {
+  const auth = node('auth-service', { aliases: ['auth'] });
+  const login = node('HandleLogin', { workspace: 'auth' });
+  const billing = node('Billing', { workspace: 'billing' });
+  const servedBy = createServiceMatcher([auth, login, billing]);
+
+  it('matches names, aliases, and workspaces case-insensitively', () => {
+    expect(servedBy('AUTH')).toEqual([auth, login]);
+    expect(servedBy('handlelogin')).toEqual([login]);
+  });
+
+  it('lists a node matched by name and workspace once', () => {
+    expect(servedBy('billing')).toEqual([billing]);
+    expect(servedBy('search')).toEqual([]);
+  });
+});
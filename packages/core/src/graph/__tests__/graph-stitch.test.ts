@@ -0,0 +1,203 @@
+import { describe, it, expect } from 'vitest';
+import { externalNode } from '../graph-builder.js';
+import { stitchGraphs } from '../graph-stitch.js';
+import { parseServiceRegistry } from '../service-registry.js';
+import type { DependencyGraph, GraphNode } from '../types.js';
+
+function node(id: string, overrides: Partial<GraphNode> = {}): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+const checkout: DependencyGraph = {
+  nodes: [
+    node('checkout'),
+    externalNode('service', 'Token-Service'),
+    externalNode('service', 'ledger'),
+    externalNode('database', 'orders-db'),
+  ],
+  edges: [
+    { from: 'checkout', to: 'external:service:Token-Service', kind: 'service' },
+    { from: 'checkout', to: 'external:service:ledger', kind: 'service' },
+    { from: 'checkout', to: 'external:database:orders-db', kind: 'database' },
+  ],
+};
+
+const identity: DependencyGraph = {
+  nodes: [
+    node('auth', { aliases: ['token-service'] }),
+    node('ledger-fn', { name: 'ledger', entityType: 'function' }),
+    node('ledger'),
+  ],
+  edges: [],
+};
+
+describe('stitchGraphs', () => {
+  it('resolves stubs by name, then alias, and redirects their edges', () => {
+    const { graph, resolved } = stitchGraphs([checkout, identity]);
+    expect(resolved).toEqual([
+      {
+        stub: 'external:service:Token-Service',
+        target: 'auth',
+        match: 'alias',
+      },
+      { stub: 'external:service:ledger', target: 'ledger', match: 'name' },
+    ]);
+    expect(graph.nodes.map((n) => n.id)).toEqual([
+      'checkout',
+      'external:database:orders-db',
+      'auth',
+      'ledger-fn',
+      'ledger',
+    ]);
+    expect(graph.edges).toEqual([
+      { from: 'checkout', to: 'auth', kind: 'service' },
+      { from: 'checkout', to: 'ledger', kind: 'service' },
+      { from: 'checkout', to: 'external:database:orders-db', kind: 'database' },
+    ]);
+  });
+
+  it('reports the stubs nothing resolves, with their dependents', () => {
+    const { unresolved } = stitchGraphs([checkout]);
+    expect(
+      unresolved.map((stub) => [stub.node.name, stub.kinds, stub.dependents]),
+    ).toEqual([
+      ['Token-Service', ['service'], ['checkout']],
+      ['ledger', ['service'], ['checkout']],
+      ['orders-db', ['database'], ['checkout']],
+    ]);
+  });
+
+  it('prefers real nodes over scoped stubs with the same id', () => {
+    const scoped: DependencyGraph = {
+      nodes: [node('checkout'), node('auth', { stub: true })],
+      edges: [{ from: 'checkout', to: 'auth', kind: 'service' }],
+    };
+    const full: DependencyGraph = {
+      nodes: [node('auth', { owner: 'identity-team' })],
+      edges: [{ from: 'checkout', to: 'auth', kind: 'service' }],
+    };
+    const { graph } = stitchGraphs([scoped, full]);
+    expect(graph.nodes).toEqual([
+      node('checkout'),
+      node('auth', { owner: 'identity-team' }),
+    ]);
+    expect(graph.edges).toHaveLength(1);
+  });
+
+  it('drops edges that resolution turns into self-loops', () => {
+    const graph: DependencyGraph = {
+      nodes: [
+        node('auth', { aliases: ['tokens'] }),
+        externalNode('service', 'tokens'),
+      ],
+      edges: [{ from: 'auth', to: 'external:service:tokens', kind: 'service' }],
+    };
+    expect(stitchGraphs([graph]).graph).toEqual({
+      nodes: [node('auth', { aliases: ['tokens'] })],
+      edges: [],
+    });
+  });
+
+  it('resolves stubs through the service registry first', () => {
+    const registry = parseServiceRegistry(
+      [
+        'services:',
+        '  user-service:',
+        '    repo: acme/users',
+        '    node: UserService',
+        '    endpoints: [https://users.acme.internal/]',
+      ].join('\n'),
+    );
+    const orders: DependencyGraph = {
+      nodes: [
+        node('orders'),
+        externalNode('service', 'user-service'),
+        externalNode('external_api', 'users.acme.internal'),
+      ],
+      edges: [
+        {
+          from: 'orders',
+          to: 'external:service:user-service',
+          kind: 'service',
+        },
+        {
+          from: 'orders',
+          to: 'external:external_api:users.acme.internal',
+          kind: 'external_api',
+        },
+      ],
+    };
+    const users: DependencyGraph = {
+      nodes: [
+        node('acme/legacy:UserService', {
+          name: 'UserService',
+          namespace: 'acme/legacy',
+        }),
+        node('acme/users:UserService', {
+          name: 'UserService',
+          namespace: 'acme/users',
+        }),
+        node('user-service', { entityType: 'module' }),
+      ],
+      edges: [],
+    };
+    const { graph, resolved } = stitchGraphs([orders, users], { registry });
+    expect(resolved).toEqual([
+      {
+        stub: 'external:service:user-service',
+        target: 'acme/users:UserService',
+        match: 'registry',
+      },
+      {
+        stub: 'external:external_api:users.acme.internal',
+        target: 'acme/users:UserService',
+        match: 'registry',
+      },
+    ]);
+    expect(graph.edges).toEqual([
+      { from: 'orders', to: 'acme/users:UserService', kind: 'service' },
+      { from: 'orders', to: 'acme/users:UserService', kind: 'external_api' },
+    ]);
+  });
+
+  it('suggests the name an unresolved service stub nearly matches', () => {
+    const { unresolved } = stitchGraphs([
+      checkout,
+      { nodes: [node('ledgers'), node('orders-dbs')], edges: [] },
+    ]);
+    expect(
+      unresolved.map((stub) => [stub.node.name, stub.suggestion]),
+    ).toEqual([
+      ['Token-Service', undefined],
+      ['ledger', 'ledgers'],
+      ['orders-db', undefined],
+    ]);
+  });
+});
+
+describe('parseServiceRegistry', () => {
+  it('lists services under their canonical names', () => {
+    expect(
+      parseServiceRegistry('{"services": {"billing": {"aliases": ["bills"]}}}'),
+    ).toEqual({
+      services: [{ name: 'billing', aliases: ['bills'], endpoints: [] }],
+    });
+  });
+
+  it('rejects registries that do not fit the schema', () => {
+    expect(() =>
+      parseServiceRegistry('services:\n  billing:\n    repo: acme/ bills\n'),
+    ).toThrow('services.billing.repo: repo must be a namespace');
+    expect(() => parseServiceRegistry('services: [')).toThrow('not valid YAML');
+  });
+});
@@ -0,0 +1,132 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import { buildDependencyGraph } from '../graph-builder.js';
+import {
+  isValidAt,
+  ownerAt,
+  parseAsOfDate,
+  selectAsOf,
+} from '../graph-validity.js';
+
+function makeEntity(
+  name: string,
+  metadata: Partial<ExtendedMetadata> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: metadata.owner ?? null,
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `${name} description`,
+      ...metadata,
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+// Payments handed billing to finance on 2026-04-01 and moved it from
+// oracle to postgres on 2026-06-30
+const billing = makeEntity('billing', {
+  owner: 'finance',
+  owners: [
+    { owner: 'payments', valid_until: '2026-04-01' },
+    { owner: 'finance', valid_from: '2026-04-01' },
+  ],
+  dependencies: {
+    services: ['ledger'],
+    databases: ['oracle', 'postgres'],
+    environments: { dev: { databases: ['oracle'] } },
+    validity: {
+      oracle: { valid_until: '2026-06-30' },
+      postgres: { valid_from: '2026-06-30' },
+    },
+  },
+});
+
+describe('isValidAt and ownerAt', () => {
+  it('holds from valid_from up to the day before valid_until', () => {
+    const window = { valid_from: '2026-01-01', valid_until: '2026-04-01' };
+    expect(isValidAt(window, '2025-12-31')).toBe(false);
+    expect(isValidAt(window, '2026-01-01')).toBe(true);
+    expect(isValidAt(window, '2026-03-31')).toBe(true);
+    expect(isValidAt(window, '2026-04-01')).toBe(false);
+    expect(isValidAt(undefined, '2026-04-01')).toBe(true);
+  });
+
+  it('reads the dated owner, falling back to the undated one', () => {
+    expect(ownerAt(billing, '2026-03-31')).toBe('payments');
+    expect(ownerAt(billing, '2026-04-01')).toBe('finance');
+    const gap = makeEntity('gap', {
+      owner: 'platform',
+      owners: [{ owner: 'payments', valid_from: '2027-01-01' }],
+    });
+    expect(ownerAt(gap, '2026-04-01')).toBe('platform');
+  });
+});
+
+describe('selectAsOf', () => {
+  it('keeps the owner and dependencies of the date everywhere', () => {
+    const dated = selectAsOf(billing, '2026-05-01');
+    expect(dated.owner).toBe('finance');
+    expect(dated.metadata).toEqual({
+      type: 'service',
+      description: 'billing description',
+      owner: 'finance',
+      dependencies: {
+        services: ['ledger'],
+        databases: ['oracle'],
+        environments: { dev: { databases: ['oracle'] } },
+      },
+    });
+    expect(selectAsOf(billing, '2026-07-01').metadata).toMatchObject({
+      dependencies: {
+        databases: ['postgres'],
+        environments: { dev: { databases: [] } },
+      },
+    });
+  });
+
+  it('leaves entities without dated facts alone', () => {
+    const ledger = makeEntity('ledger', { owner: 'finance' });
+    expect(selectAsOf(ledger, '2026-05-01')).toBe(ledger);
+  });
+});
+
+describe('buildDependencyGraph asOf', () => {
+  it('builds the graph as it stood on the date', () => {
+    const graph = buildDependencyGraph([billing], { asOf: '2026-03-01' });
+    expect(graph.nodes.find((node) => node.id === 'id-billing')?.owner).toBe(
+      'payments',
+    );
+    expect(graph.edges.map((edge) => edge.to)).toEqual([
+      'external:service:ledger',
+      'external:database:oracle',
+    ]);
+  });
+});
+
+describe('parseAsOfDate', () => {
+  it('accepts calendar dates only', () => {
+    expect(parseAsOfDate('2026-03-31')).toBe('2026-03-31');
+    expect(() => parseAsOfDate('2026-02-30')).toThrow(
+      "Invalid date '2026-02-30': expected YYYY-MM-DD",
+    );
+    expect(() => parseAsOfDate('March 31')).toThrow('expected YYYY-MM-DD');
+  });
+});
@@ -0,0 +1,113 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Rolls the dependency graph up to bounded contexts and reports cross-domain coupling
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, domain, ddd, coupling, report]
+ * context:
+ *   business_goal: Let DDD teams see context boundaries and the coupling between them
+ *   domain: graph
+ */
+import type {
+  DependencyGraph,
+  DomainDependency,
+  DomainReport,
+  DomainSummary,
+  GraphNode,
+} from './types.js';
+
+export const UNASSIGNED_DOMAIN = '(no domain)';
+
+const MAX_EXAMPLES = 3;
+
+function domainOf(node: GraphNode): string {
+  return node.domain ?? UNASSIGNED_DOMAIN;
+}
+
+/**
+ * Summarize each domain and count dependency edges that cross domain
+ * boundaries. Edges to external stub nodes are not coupling between
+ * contexts and are ignored. Afferent/efferent counts are the number of
+ * distinct other domains depending on / depended on by a domain, and
+ * instability is efferent / (afferent + efferent).
+ */
+export function buildDomainReport(graph: DependencyGraph): DomainReport {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const internal = graph.nodes.filter((node) => !node.external);
+
+  const entityCounts = new Map<string, number>();
+  const owners = new Map<string, Set<string>>();
+  for (const node of internal) {
+    const domain = domainOf(node);
+    entityCounts.set(domain, (entityCounts.get(domain) ?? 0) + 1);
+    const set = owners.get(domain) ?? new Set<string>();
+    if (node.owner) set.add(node.owner);
+    owners.set(domain, set);
+  }
+
+  const internalEdges = new Map<string, number>();
+  const crossing = new Map<string, { count: number; examples: string[] }>();
+  for (const edge of graph.edges) {
+    const from = nodes.get(edge.from);
+    const to = nodes.get(edge.to);
+    if (!from || !to || from.external || to.external) continue;
+
+    const fromDomain = domainOf(from);
+    const toDomain = domainOf(to);
+    if (fromDomain === toDomain) {
+      internalEdges.set(fromDomain, (internalEdges.get(fromDomain) ?? 0) + 1);
+      continue;
+    }
+
+    const key = `${fromDomain}\u0000${toDomain}`;
+    const entry = crossing.get(key) ?? { count: 0, examples: [] };
+    crossing.set(key, {
+      count: entry.count + 1,
+      examples:
+        entry.examples.length < MAX_EXAMPLES
+          ? [...entry.examples, `${from.name} -> ${to.name}`]
+          : entry.examples,
+    });
+  }
+
+  const dependencies: DomainDependency[] = [...crossing.entries()]
+    .map(([key, value]) => {
+      const [from, to] = key.split('\u0000');
+      return { from, to, count: value.count, examples: value.examples };
+    })
+    .sort(
+      (a, b) =>
+        b.count - a.count ||
+        a.from.localeCompare(b.from) ||
+        a.to.localeCompare(b.to),
+    );
+
+  const domains: DomainSummary[] = [...entityCounts.keys()]
+    .map((domain) => {
+      const afferent = new Set(
+        dependencies.filter((d) => d.to === domain).map((d) => d.from),
+      ).size;
+      const efferent = new Set(
+        dependencies.filter((d) => d.from === domain).map((d) => d.to),
+      ).size;
+      const total = afferent + efferent;
+      return {
+        domain,
+        entityCount: entityCounts.get(domain) ?? 0,
+        owners: [...(owners.get(domain) ?? [])].sort(),
+        internalEdges: internalEdges.get(domain) ?? 0,
+        afferent,
+        efferent,
+        instability:
+          total === 0 ? null : Math.round((efferent / total) * 100) / 100,
+      };
+    })
+    .sort((a, b) => {
+      if (a.domain === UNASSIGNED_DOMAIN) return 1;
+      if (b.domain === UNASSIGNED_DOMAIN) return -1;
+      return a.domain.localeCompare(b.domain);
+    });
+
+  return { domains, dependencies };
+}
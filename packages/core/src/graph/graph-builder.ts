@@ -0,0 +1,475 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Builds a dependency graph from entity metadata, resolving service names to indexed entities
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, dependencies, builder]
+ * context:
+ *   business_goal: Turn declared dependencies into a graph that rollups and impact reports can walk
+ *   domain: graph
+ */
+import { posix, sep } from 'node:path';
+import type { GoPackage } from '../golang/types.js';
+import type { GitSubmodule, StoredEntity } from '../indexer/types.js';
+import { findSubmodule, isVendoredPath } from '../indexer/walker.js';
+import {
+  namespaceGraph,
+  namespaceNode,
+  namespacedId,
+} from '../namespace/namespace.js';
+import { findWorkspaceMember } from '../workspace/discovery.js';
+import type { WorkspaceMember } from '../workspace/types.js';
+import type { DependencyEntry } from '../types/entity.js';
+import {
+  dependencyEntryName,
+  environmentDependencies,
+} from './graph-environments.js';
+import {
+  ENRICHED_EDGE_CONFIDENCE,
+  createEntityReferenceIndex,
+  enrichedDependencies,
+} from './graph-enriched.js';
+import { createNameTable } from './graph-names.js';
+import { findDescriptionReferences } from './graph-references.js';
+import { deriveEdges } from './graph-rules.js';
+import { selectAsOf } from './graph-validity.js';
+import type {
+  DeclaredDependency,
+  DependencyGraph,
+  DependencyGraphOptions,
+  DependencyKind,
+  GraphEdge,
+  GraphNode,
+  NameTable,
+} from './types.js';
+
+const NO_NAMES = createNameTable();
+
+// What a dependency carries onto its edge besides the endpoints; edges are
+// declared, with confidence 1, unless it says otherwise
+type EdgeAttributes = Omit<DeclaredDependency, 'kind' | 'name'> &
+  Partial<Pick<GraphEdge, 'provenance' | 'confidence'>>;
+
+export function getEntityDomain(entity: StoredEntity): string | null {
+  const { metadata } = entity;
+  return 'context' in metadata ? (metadata.context?.domain ?? null) : null;
+}
+
+/**
+ * The other names `entity` answers to: its `aliases` annotation, then the
+ * aliases and old names `names` records for it, without repeats.
+ */
+export function entityAliases(
+  entity: StoredEntity,
+  names: NameTable = NO_NAMES,
+): readonly string[] {
+  const { metadata } = entity;
+  const declared = 'aliases' in metadata ? (metadata.aliases ?? []) : [];
+  const seen = new Set([entity.name.toLowerCase()]);
+  return [...declared, ...names.aliasesOf(entity.name)].filter((alias) => {
+    const key = alias.toLowerCase();
+    if (seen.has(key)) return false;
+    seen.add(key);
+    return true;
+  });
+}
+
+export function toEntityNode(
+  entity: StoredEntity,
+  workspaces: readonly WorkspaceMember[] = [],
+  names: NameTable = NO_NAMES,
+): GraphNode {
+  const aliases = entityAliases(entity, names);
+  const { metadata } = entity;
+  const version = 'version' in metadata ? metadata.version : undefined;
+  return {
+    id: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    // Vendored code is a dependency, not part of the repository's graph
+    external: isVendoredPath(entity.filePath),
+    filePath: entity.filePath,
+    owner: entity.owner,
+    domain: getEntityDomain(entity),
+    workspace: findWorkspaceMember(entity.filePath, workspaces)?.name ?? null,
+    ...(aliases.length > 0 ? { aliases } : {}),
+    ...(version ? { version } : {}),
+  };
+}
+
+/**
+ * `graph` with the nodes of files inside `submodules` moved into each
+ * submodule's namespace, as if its repository had been scanned on its own.
+ */
+export function namespaceSubmodules(
+  graph: DependencyGraph,
+  submodules: readonly GitSubmodule[],
+): DependencyGraph {
+  const moved = new Map<string, string>();
+  const nodes = graph.nodes.map((node) => {
+    const submodule =
+      node.external || !node.filePath
+        ? undefined
+        : findSubmodule(node.filePath, submodules);
+    if (!submodule) return node;
+    moved.set(node.id, namespacedId(submodule.namespace, node.id));
+    return namespaceNode(node, submodule.namespace);
+  });
+  const id = (value: string): string => moved.get(value) ?? value;
+  return {
+    nodes,
+    edges: graph.edges.map(
+      (edge): GraphEdge => ({ ...edge, from: id(edge.from), to: id(edge.to) }),
+    ),
+  };
+}
+
+export function externalNode(kind: DependencyKind, name: string): GraphNode {
+  return {
+    id: `external:${kind}:${name}`,
+    name,
+    entityType: null,
+    external: true,
+    filePath: null,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+export function goPackageNodeId(importPath: string): string {
+  return `go:${importPath}`;
+}
+
+function soleValue(values: readonly (string | null)[]): string | null {
+  const distinct = new Set(values.filter((v): v is string => v !== null));
+  return distinct.size === 1 ? [...distinct][0] : null;
+}
+
+/**
+ * A node for a Go package. Owner and domain come from the annotated
+ * entities in the package directory when they agree.
+ */
+function goPackageNode(
+  pkg: GoPackage,
+  entities: readonly StoredEntity[],
+  workspaces: readonly WorkspaceMember[],
+): GraphNode {
+  const members = entities.filter(
+    (entity) =>
+      posix.dirname(entity.filePath.split(sep).join('/')) === pkg.dir,
+  );
+  return {
+    id: goPackageNodeId(pkg.importPath),
+    name: pkg.importPath,
+    entityType: null,
+    external: false,
+    filePath: pkg.dir,
+    owner: soleValue(members.map((entity) => entity.owner)),
+    domain: soleValue(members.map(getEntityDomain)),
+    // The trailing slash makes a member rooted at the package dir match
+    workspace: findWorkspaceMember(`${pkg.dir}/`, workspaces)?.name ?? null,
+    annotated: members.length > 0,
+  };
+}
+
+function targetRank(entityType: string): number {
+  if (entityType === 'service') return 0;
+  if (entityType === 'module') return 1;
+  return 2;
+}
+
+/**
+ * Whether `candidate` should replace `existing` as the entity a dependency
+ * name resolves to. Service and module entities win over others so
+ * `dependencies.services` resolve to the component rather than a same-named
+ * function; otherwise the first entity seen is kept.
+ */
+export function isPreferredTarget(
+  candidate: Pick<StoredEntity, 'entityType'>,
+  existing: Pick<StoredEntity, 'entityType'> | undefined,
+): boolean {
+  return (
+    !existing ||
+    targetRank(candidate.entityType) < targetRank(existing.entityType)
+  );
+}
+
+export interface TargetIndex<T extends Pick<StoredEntity, 'entityType'>> {
+  add(entity: StoredEntity, target: T): void;
+  /** The target a service dependency on `name` resolves to, if any. */
+  resolve(name: string): T | undefined;
+}
+
+/**
+ * An index of the entities service dependencies can resolve to, under
+ * their names and their aliases (see `entityAliases`). A name beats an
+ * alias, so an alias never takes a dependency away from the entity that
+ * carries the name; after both, the current name from `names` is tried.
+ */
+export function createTargetIndex<T extends Pick<StoredEntity, 'entityType'>>(
+  names: NameTable = NO_NAMES,
+): TargetIndex<T> {
+  const byName = new Map<string, T>();
+  const byAlias = new Map<string, T>();
+  const put = (index: Map<string, T>, name: string, target: T): void => {
+    const key = name.toLowerCase();
+    if (isPreferredTarget(target, index.get(key))) index.set(key, target);
+  };
+  const lookup = (key: string): T | undefined =>
+    byName.get(key) ?? byAlias.get(key);
+  return {
+    add(entity, target) {
+      put(byName, entity.name, target);
+      for (const alias of entityAliases(entity, names)) {
+        put(byAlias, alias, target);
+      }
+    },
+    resolve(name) {
+      return (
+        lookup(name.toLowerCase()) ??
+        lookup(names.canonical(name).toLowerCase())
+      );
+    },
+  };
+}
+
+// `user-service`, `user_service`, and `UserService` all read the same
+export function looseName(name: string): string {
+  return name.toLowerCase().replace(/[-_\s]/g, '');
+}
+
+/**
+ * Whether `qualifier`, as in `auth` of `[[auth.HandleLogin]]`, names where
+ * `entity` lives: its parent, its domain, its file, or its directory.
+ */
+function inQualifier(entity: StoredEntity, qualifier: string): boolean {
+  const path = entity.filePath.split(sep).join('/');
+  const places = [
+    entity.parent,
+    getEntityDomain(entity),
+    posix.basename(path).replace(/\.[^.]+$/, ''),
+    posix.basename(posix.dirname(path)),
+  ];
+  const key = looseName(qualifier);
+  return places.some((place) => place !== null && looseName(place) === key);
+}
+
+/**
+ * Resolve a reference target to the entity it names. A target is looked up
+ * as a dependency name would be, by name, alias, or old name; then, as
+ * `qualifier.name`, among the entities with that name whose parent,
+ * domain, file, or directory is the qualifier; and last ignoring case,
+ * hyphens, and underscores, so `[[user-service]]` finds `UserService`.
+ */
+export function createReferenceResolver(
+  entities: readonly StoredEntity[],
+  names?: NameTable,
+): (target: string) => StoredEntity | undefined {
+  const targets = createTargetIndex<StoredEntity>(names);
+  const byName = new Map<string, StoredEntity[]>();
+  const byLooseName = new Map<string, StoredEntity>();
+  for (const entity of entities) {
+    targets.add(entity, entity);
+    const key = entity.name.toLowerCase();
+    byName.set(key, [...(byName.get(key) ?? []), entity]);
+    const loose = looseName(entity.name);
+    if (isPreferredTarget(entity, byLooseName.get(loose))) {
+      byLooseName.set(loose, entity);
+    }
+  }
+
+  const qualified = (target: string): StoredEntity | undefined => {
+    const dot = target.lastIndexOf('.');
+    if (dot <= 0 || dot === target.length - 1) return undefined;
+    const qualifier = target.slice(0, dot);
+    const named = byName.get(target.slice(dot + 1).toLowerCase()) ?? [];
+    let found: StoredEntity | undefined;
+    for (const entity of named) {
+      if (inQualifier(entity, qualifier) && isPreferredTarget(entity, found)) {
+        found = entity;
+      }
+    }
+    return found;
+  };
+
+  return (target) =>
+    targets.resolve(target) ??
+    qualified(target) ??
+    byLooseName.get(looseName(target));
+}
+
+/**
+ * The dependencies an entity declares, in graph edge order: services,
+ * then external APIs, then databases, each with the version range
+ * `dependencies.versions` requires of it and what its entry annotates the
+ * edge with, when it is an object. With `environment`, only those
+ * that apply in it; without, those of every environment (see
+ * `environmentDependencies`).
+ */
+export function declaredDependencies(
+  entity: Pick<StoredEntity, 'metadata'>,
+  environment?: string,
+): readonly DeclaredDependency[] {
+  const { metadata } = entity;
+  const declared =
+    'dependencies' in metadata ? metadata.dependencies : undefined;
+  if (!declared) return [];
+  const deps = environmentDependencies(declared, environment);
+  const { versions = {} } = declared;
+  const dependency =
+    (kind: DependencyKind) =>
+    (entry: DependencyEntry): DeclaredDependency => {
+      const name = dependencyEntryName(entry);
+      const annotated = typeof entry === 'string' ? undefined : entry;
+      return {
+        kind,
+        name,
+        ...(Object.hasOwn(versions, name) ? { requires: versions[name] } : {}),
+        ...(annotated?.purpose ? { purpose: annotated.purpose } : {}),
+        ...(annotated?.protocol ? { protocol: annotated.protocol } : {}),
+        ...(annotated?.criticality
+          ? { criticality: annotated.criticality }
+          : {}),
+      };
+    };
+  return [
+    ...(deps.services ?? []).map(dependency('service')),
+    ...(deps.external_apis ?? []).map(dependency('external_api')),
+    ...(deps.databases ?? []).map(dependency('database')),
+  ];
+}
+
+/**
+ * Build a graph with one node per entity and an edge per declared dependency.
+ * Service dependencies that name an indexed entity point at it; everything
+ * else (unknown services, external APIs, databases) becomes an external stub
+ * node shared by all dependents. Dependencies may use an entity's aliases,
+ * and `aliases` and `renames` map other and old names to current ones, so
+ * external stubs are shared under the current name too. With `workspaces`,
+ * each entity node records the innermost build unit containing its file.
+ * With `goPackages`, every package becomes a node (annotated or not) joined
+ * by `import` edges, so the graph shows the full structure with annotations
+ * as enrichment. With `namespace`, node ids other than external stubs carry
+ * it as a prefix; with `submodules`, nodes in a submodule carry its own.
+ * Entities in `vendor/` directories are external nodes. With `references`,
+ * each `[[name]]` in a description that resolves to an entity adds a
+ * `reference` edge to it; references to nothing indexed are left out.
+ * The `calls` and `http_calls` that enrichers record add `service` edges with
+ * `call` or `router` provenance, and `external_api` edges with `call`
+ * provenance, at a lower confidence; a declared edge between the same
+ * nodes is kept instead.
+ * With `environment`, only the dependencies declared for it are edges.
+ * With `edgeRules`, the edges they derive from all of these are added.
+ * With `asOf`, each entity is taken as it stood on that date.
+ */
+export function buildDependencyGraph(
+  entities: readonly StoredEntity[],
+  options: DependencyGraphOptions = {},
+): DependencyGraph {
+  if (options.asOf !== undefined) {
+    const { asOf, ...undated } = options;
+    const dated = entities.map((entity) => selectAsOf(entity, asOf));
+    return buildDependencyGraph(dated, undated);
+  }
+  const { workspaces = [], goPackages = [] } = options;
+  const names = createNameTable(options);
+  const targets = createTargetIndex<StoredEntity>(names);
+  for (const entity of entities) targets.add(entity, entity);
+  const nodes = new Map<string, GraphNode>(
+    entities.map((entity) => [
+      entity.id,
+      toEntityNode(entity, workspaces, names),
+    ]),
+  );
+  const edges: GraphEdge[] = [];
+  const seen = new Set<string>();
+
+  const addEdge = (
+    from: string,
+    to: string,
+    kind: DependencyKind,
+    attributes: EdgeAttributes = {},
+  ): void => {
+    const key = `${from}\u0000${to}\u0000${kind}`;
+    if (from === to || seen.has(key)) return;
+    seen.add(key);
+    edges.push({
+      from,
+      to,
+      kind,
+      provenance: kind === 'import' ? 'import' : 'declared',
+      confidence: 1,
+      ...attributes,
+    });
+  };
+
+  const addExternal = (
+    from: string,
+    kind: DependencyKind,
+    name: string,
+    attributes?: EdgeAttributes,
+  ) => {
+    const node = externalNode(kind, names.canonical(name));
+    if (!nodes.has(node.id)) nodes.set(node.id, node);
+    addEdge(from, node.id, kind, attributes);
+  };
+
+  const references = createEntityReferenceIndex<string>();
+  for (const entity of entities) references.add(entity, entity.id);
+  for (const entity of entities) {
+    const declared = declaredDependencies(entity, options.environment);
+    for (const { kind, name, ...attributes } of declared) {
+      const target = kind === 'service' ? targets.resolve(name) : undefined;
+      if (target) {
+        addEdge(entity.id, target.id, kind, attributes);
+      } else {
+        addExternal(entity.id, kind, name, attributes);
+      }
+    }
+    for (const found of enrichedDependencies(entity, references.resolve)) {
+      const attributes = {
+        provenance: found.provenance,
+        confidence: ENRICHED_EDGE_CONFIDENCE,
+      };
+      if (found.kind === 'service') {
+        addEdge(entity.id, found.target, found.kind, attributes);
+      } else {
+        addExternal(entity.id, found.kind, found.name, attributes);
+      }
+    }
+  }
+
+  if (options.references) {
+    const resolveReference = createReferenceResolver(entities, names);
+    for (const entity of entities) {
+      for (const { target } of findDescriptionReferences(entity.description)) {
+        const found = resolveReference(target);
+        if (found) addEdge(entity.id, found.id, 'reference');
+      }
+    }
+  }
+
+  for (const pkg of goPackages) {
+    const node = goPackageNode(pkg, entities, workspaces);
+    nodes.set(node.id, node);
+  }
+  for (const pkg of goPackages) {
+    for (const path of pkg.imports) {
+      const target = goPackageNodeId(path);
+      if (nodes.has(target)) {
+        addEdge(goPackageNodeId(pkg.importPath), target, 'import');
+      }
+    }
+  }
+
+  const built = { nodes: [...nodes.values()], edges };
+  if (options.edgeRules?.length) {
+    edges.push(...deriveEdges(built, entities, options.edgeRules));
+  }
+  const graph = options.submodules?.length
+    ? namespaceSubmodules(built, options.submodules)
+    : built;
+  return options.namespace ? namespaceGraph(graph, options.namespace) : graph;
+}
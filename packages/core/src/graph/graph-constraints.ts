@@ -0,0 +1,113 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Checks graphs being merged for duplicate names and nodes without exactly one owner, locating each copy in its graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, stitch, merge, constraints, ownership, federation]
+ * context:
+ *   business_goal: Catch two repositories claiming the same service or disagreeing on who owns a node before the merged graph is trusted
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type {
+  ConflictLocation,
+  ConstraintConflict,
+  DependencyGraph,
+  GraphConstraintOptions,
+  GraphNode,
+} from './types.js';
+
+const DEFAULT_UNIQUE_NAMES = ['service'] as const;
+
+function isReal(node: GraphNode): boolean {
+  return !node.external && !node.stub && node.entityType !== null;
+}
+
+function locate(graph: number, node: GraphNode): ConflictLocation {
+  return {
+    graph,
+    node: node.id,
+    namespace: node.namespace ?? null,
+    filePath: node.filePath,
+    owner: node.owner,
+  };
+}
+
+function group<T>(
+  items: readonly T[],
+  key: (item: T) => string | undefined,
+): Map<string, T[]> {
+  const groups = new Map<string, T[]>();
+  for (const item of items) {
+    const found = key(item);
+    if (found === undefined) continue;
+    groups.set(found, [...(groups.get(found) ?? []), item]);
+  }
+  return groups;
+}
+
+/**
+ * The constraints `graphs` break once merged, checked across every copy
+ * of every real node so each conflict lists where its copies came from.
+ * Names of the `uniqueNames` types must be unique, case-insensitively,
+ * among nodes with different ids; copies of a node with the same id must
+ * agree on its owner; with `requireOwner`, every node needs one. Stubs and
+ * external nodes are exempt.
+ */
+export function checkGraphConstraints(
+  graphs: readonly DependencyGraph[],
+  options: GraphConstraintOptions = {},
+): readonly ConstraintConflict[] {
+  const uniqueNames = new Set<string>(
+    options.uniqueNames ?? DEFAULT_UNIQUE_NAMES,
+  );
+  const copies = graphs.flatMap((graph, index) =>
+    graph.nodes.filter(isReal).map((node) => locate(index, node)),
+  );
+  const nodes = new Map<string, GraphNode>();
+  for (const graph of graphs) {
+    for (const node of graph.nodes) {
+      if (isReal(node) && !nodes.has(node.id)) nodes.set(node.id, node);
+    }
+  }
+  const conflicts: ConstraintConflict[] = [];
+
+  const byName = group(copies, (copy) => {
+    const node = nodes.get(copy.node);
+    return node && uniqueNames.has(node.entityType ?? '')
+      ? `${node.entityType}\0${node.name.toLowerCase()}`
+      : undefined;
+  });
+  for (const locations of byName.values()) {
+    const ids = new Set(locations.map((location) => location.node));
+    if (ids.size < 2) continue;
+    const node = nodes.get(locations[0].node);
+    conflicts.push({
+      rule: 'unique-name',
+      message: `${ids.size} ${node?.entityType} nodes are named "${node?.name}"`,
+      locations,
+    });
+  }
+
+  for (const [id, locations] of group(copies, (copy) => copy.node)) {
+    const owners = [
+      ...new Set(locations.flatMap((location) => location.owner ?? [])),
+    ].sort(compareStrings);
+    const name = nodes.get(id)?.name ?? id;
+    if (owners.length > 1) {
+      conflicts.push({
+        rule: 'single-owner',
+        message: `${name} has ${owners.length} owners: ${owners.join(', ')}`,
+        locations,
+      });
+    } else if (owners.length === 0 && options.requireOwner) {
+      conflicts.push({
+        rule: 'single-owner',
+        message: `${name} has no owner`,
+        locations,
+      });
+    }
+  }
+  return conflicts;
+}
@@ -0,0 +1,352 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Finds dependency cycles, the edges that would break each one, and which new cycles exceed their domain's budget
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, cycles, budget, check, architecture]
+ * context:
+ *   business_goal: Stop new circular dependencies from landing while legacy domains pay down the ones they have
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { UNASSIGNED_DOMAIN } from './domain-rollup.js';
+import type {
+  CycleBudgetOptions,
+  CycleBudgetReport,
+  CycleMember,
+  CycleStatus,
+  DependencyCycle,
+  DependencyGraph,
+  DomainCycleBudget,
+  GraphEdge,
+  GraphNode,
+} from './types.js';
+
+/**
+ * How baselines name a cycle member: `path:name`, which unlike node ids
+ * survives the member moving within its file.
+ */
+export function cycleMemberKey(member: CycleMember | GraphNode): string {
+  return member.filePath ? `${member.filePath}:${member.name}` : member.name;
+}
+
+/** Strongly connected components, by Tarjan's algorithm without recursion. */
+function stronglyConnected(
+  ids: readonly string[],
+  successors: ReadonlyMap<string, readonly string[]>,
+): string[][] {
+  const index = new Map<string, number>();
+  const low = new Map<string, number>();
+  const onStack = new Set<string>();
+  const stack: string[] = [];
+  const components: string[][] = [];
+
+  for (const root of ids) {
+    if (index.has(root)) continue;
+    const work: { id: string; next: number }[] = [{ id: root, next: 0 }];
+    index.set(root, index.size);
+    low.set(root, index.get(root) ?? 0);
+    stack.push(root);
+    onStack.add(root);
+    while (work.length > 0) {
+      const frame = work[work.length - 1];
+      const targets = successors.get(frame.id) ?? [];
+      if (frame.next < targets.length) {
+        const to = targets[frame.next];
+        frame.next += 1;
+        if (!index.has(to)) {
+          index.set(to, index.size);
+          low.set(to, index.get(to) ?? 0);
+          stack.push(to);
+          onStack.add(to);
+          work.push({ id: to, next: 0 });
+        } else if (onStack.has(to)) {
+          low.set(
+            frame.id,
+            Math.min(low.get(frame.id) ?? 0, index.get(to) ?? 0),
+          );
+        }
+        continue;
+      }
+      work.pop();
+      const parent = work[work.length - 1];
+      if (parent) {
+        low.set(
+          parent.id,
+          Math.min(low.get(parent.id) ?? 0, low.get(frame.id) ?? 0),
+        );
+      }
+      if (low.get(frame.id) === index.get(frame.id)) {
+        const component: string[] = [];
+        let id: string | undefined;
+        do {
+          id = stack.pop();
+          if (id === undefined) break;
+          onStack.delete(id);
+          component.push(id);
+        } while (id !== frame.id);
+        components.push(component);
+      }
+    }
+  }
+  return components;
+}
+
+/**
+ * Whether `to` is reachable from `from` along `successors`, which is how
+ * an edge `to -> from` would close a loop.
+ */
+function reaches(
+  successors: ReadonlyMap<string, readonly string[]>,
+  from: string,
+  to: string,
+): boolean {
+  const seen = new Set([from]);
+  const stack = [from];
+  while (stack.length > 0) {
+    const id = stack.pop() as string;
+    if (id === to) return true;
+    for (const next of successors.get(id) ?? []) {
+      if (!seen.has(next)) {
+        seen.add(next);
+        stack.push(next);
+      }
+    }
+  }
+  return false;
+}
+
+/**
+ * Edges that break every loop in a strongly connected group. The group is
+ * ordered with the Eades-Lin-Smyth heuristic and the edges pointing
+ * backwards in that order form the set; then each is put back if no loop
+ * closes through it, so none is redundant.
+ *
+ * Nodes that are neither sinks nor sources wait in buckets by out-degree
+ * minus in-degree, and removing a node only moves its neighbours between
+ * buckets, so the ordering takes O(V + E).
+ */
+function breakingEdges(
+  ids: readonly string[],
+  edges: readonly GraphEdge[],
+): GraphEdge[] {
+  const sorted = [...ids].sort(compareStrings);
+  const outgoing = new Map<string, string[]>(sorted.map((id) => [id, []]));
+  const incoming = new Map<string, string[]>(sorted.map((id) => [id, []]));
+  for (const edge of edges) {
+    outgoing.get(edge.from)?.push(edge.to);
+    incoming.get(edge.to)?.push(edge.from);
+  }
+  const outDegree = new Map(
+    [...outgoing].map(([id, targets]) => [id, targets.length]),
+  );
+  const inDegree = new Map(
+    [...incoming].map(([id, sources]) => [id, sources.length]),
+  );
+
+  // Each remaining node sits in exactly one of these
+  const sinks = new Set<string>();
+  const sources = new Set<string>();
+  const buckets = new Map<number, Set<string>>();
+  const bucketOf = new Map<string, number>();
+  let maxDelta = -Infinity;
+  const place = (id: string): void => {
+    const out = outDegree.get(id) ?? 0;
+    const into = inDegree.get(id) ?? 0;
+    if (out === 0) {
+      sinks.add(id);
+    } else if (into === 0) {
+      sources.add(id);
+    } else {
+      const delta = out - into;
+      const bucket = buckets.get(delta) ?? new Set<string>();
+      bucket.add(id);
+      buckets.set(delta, bucket);
+      bucketOf.set(id, delta);
+      maxDelta = Math.max(maxDelta, delta);
+    }
+  };
+  const unplace = (id: string): void => {
+    sinks.delete(id);
+    sources.delete(id);
+    const delta = bucketOf.get(id);
+    if (delta !== undefined) {
+      buckets.get(delta)?.delete(id);
+      bucketOf.delete(id);
+    }
+  };
+  for (const id of sorted) place(id);
+
+  const remaining = new Set(sorted);
+  const remove = (id: string): void => {
+    unplace(id);
+    remaining.delete(id);
+    for (const to of outgoing.get(id) ?? []) {
+      if (!remaining.has(to)) continue;
+      unplace(to);
+      inDegree.set(to, (inDegree.get(to) ?? 0) - 1);
+      place(to);
+    }
+    for (const from of incoming.get(id) ?? []) {
+      if (!remaining.has(from)) continue;
+      unplace(from);
+      outDegree.set(from, (outDegree.get(from) ?? 0) - 1);
+      place(from);
+    }
+  };
+  const first = (set: ReadonlySet<string>): string | undefined =>
+    set.values().next().value;
+
+  const head: string[] = [];
+  const tail: string[] = [];
+  while (remaining.size > 0) {
+    const sink = first(sinks);
+    if (sink !== undefined) {
+      tail.push(sink);
+      remove(sink);
+      continue;
+    }
+    let next = first(sources);
+    while (next === undefined) {
+      next = first(buckets.get(maxDelta) ?? new Set());
+      if (next === undefined) maxDelta -= 1;
+    }
+    head.push(next);
+    remove(next);
+  }
+  const position = new Map(
+    [...head, ...tail.reverse()].map((id, i) => [id, i]),
+  );
+  const backward = (edge: GraphEdge): boolean =>
+    (position.get(edge.from) ?? 0) >= (position.get(edge.to) ?? 0);
+
+  const kept = new Map<string, string[]>(sorted.map((id) => [id, []]));
+  for (const edge of edges) {
+    if (!backward(edge)) kept.get(edge.from)?.push(edge.to);
+  }
+  const breaking: GraphEdge[] = [];
+  for (const edge of edges.filter(backward)) {
+    if (reaches(kept, edge.to, edge.from)) breaking.push(edge);
+    else kept.get(edge.from)?.push(edge.to);
+  }
+  return breaking;
+}
+
+/**
+ * The dependency cycles in `graph`: each group of two or more nodes that
+ * all reach one another, with edges that would break it. External stubs
+ * have no dependencies of their own, so they are never on a cycle, and
+ * parallel edges between two nodes count once.
+ */
+export function findDependencyCycles(
+  graph: DependencyGraph,
+): readonly DependencyCycle[] {
+  const nodes = new Map(
+    graph.nodes.filter((node) => !node.external).map((node) => [node.id, node]),
+  );
+  const unique = new Map<string, GraphEdge>();
+  for (const edge of graph.edges) {
+    if (edge.from === edge.to) continue;
+    if (!nodes.has(edge.from) || !nodes.has(edge.to)) continue;
+    const key = `${edge.from}\u0000${edge.to}`;
+    if (!unique.has(key)) unique.set(key, edge);
+  }
+  const edges = [...unique.values()];
+  const successors = new Map<string, string[]>();
+  for (const edge of edges) {
+    const targets = successors.get(edge.from);
+    if (targets) targets.push(edge.to);
+    else successors.set(edge.from, [edge.to]);
+  }
+
+  const byKey = (a: CycleMember, b: CycleMember): number =>
+    compareStrings(cycleMemberKey(a), cycleMemberKey(b));
+  const cycles: DependencyCycle[] = [];
+  for (const component of stronglyConnected([...nodes.keys()], successors)) {
+    if (component.length < 2) continue;
+    const inside = new Set(component);
+    const internal = edges.filter(
+      (edge) => inside.has(edge.from) && inside.has(edge.to),
+    );
+    const members = component
+      .map((id) => nodes.get(id) as GraphNode)
+      .map(({ id, name, filePath, domain }) => ({
+        id,
+        name,
+        filePath,
+        domain,
+      }))
+      .sort(byKey);
+    cycles.push({
+      members,
+      edges: internal.length,
+      breakingEdges: breakingEdges(
+        members.map((member) => member.id),
+        internal,
+      ),
+    });
+  }
+  return cycles.sort((a, b) => byKey(a.members[0], b.members[0]));
+}
+
+/**
+ * Compare `cycles` with the baseline and each domain's budget. A cycle is
+ * new unless a baselined cycle contains all its members, and counts
+ * against every domain its members belong to. When a domain has more
+ * new cycles than its budget, every new cycle in it is over budget.
+ */
+export function checkCycleBudgets(
+  cycles: readonly DependencyCycle[],
+  options: CycleBudgetOptions = {},
+): CycleBudgetReport {
+  const { budgets = {}, defaultBudget = 0, baseline = [] } = options;
+  const known = baseline.map((keys) => new Set(keys));
+  const budgetOf = (domain: string): number =>
+    Object.hasOwn(budgets, domain) ? budgets[domain] : defaultBudget;
+
+  const statuses = cycles.map((cycle) => {
+    const keys = cycle.members.map(cycleMemberKey);
+    return {
+      cycle,
+      domains: [
+        ...new Set(
+          cycle.members.map((member) => member.domain ?? UNASSIGNED_DOMAIN),
+        ),
+      ].sort(compareStrings),
+      baselined: known.some((set) => keys.every((key) => set.has(key))),
+    };
+  });
+
+  const newCycles = new Map<string, number>();
+  for (const status of statuses) {
+    for (const domain of status.domains) {
+      const count = status.baselined ? 0 : 1;
+      newCycles.set(domain, (newCycles.get(domain) ?? 0) + count);
+    }
+  }
+  const domains: DomainCycleBudget[] = [...newCycles.entries()]
+    .map(([domain, count]) => ({
+      domain,
+      budget: budgetOf(domain),
+      newCycles: count,
+    }))
+    .sort((a, b) => compareStrings(a.domain, b.domain));
+  const exceeded = new Set(
+    domains
+      .filter((entry) => entry.newCycles > entry.budget)
+      .map((entry) => entry.domain),
+  );
+
+  const result: CycleStatus[] = statuses.map((status) => ({
+    ...status,
+    overBudget:
+      !status.baselined &&
+      status.domains.some((domain) => exceeded.has(domain)),
+  }));
+  return {
+    cycles: result,
+    domains,
+    overBudget: result.filter((status) => status.overBudget).length,
+  };
+}
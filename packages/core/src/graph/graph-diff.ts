@@ -0,0 +1,99 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Diffs two dependency graphs into node added, updated, renamed, and removed events
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, diff, events, subscriptions]
+ * context:
+ *   business_goal: Let subscribers react to graph changes without re-reading the whole graph
+ *   domain: graph
+ */
+import { stableStringify } from '../canonical/canonical.js';
+import type { EntityRename } from '../indexer/types.js';
+import type { DependencyGraph, GraphChangeEvent, GraphNode } from './types.js';
+
+function outgoing(graph: DependencyGraph): ReadonlyMap<string, string[]> {
+  const byNode = new Map<string, string[]>();
+  for (const edge of graph.edges) {
+    const keys = byNode.get(edge.from) ?? [];
+    keys.push(
+      `${edge.to}\u0000${edge.kind}\u0000${edge.provenance}\u0000${edge.confidence}`,
+    );
+    byNode.set(edge.from, keys);
+  }
+  for (const keys of byNode.values()) keys.sort();
+  return byNode;
+}
+
+function fingerprint(
+  node: GraphNode,
+  edges: ReadonlyMap<string, readonly string[]>,
+): string {
+  return stableStringify({ node, edges: edges.get(node.id) ?? [] }, false);
+}
+
+/**
+ * Compare two graphs node by node. A node counts as updated when any of its
+ * fields or its outgoing edges (including their provenance) changed. A new node is renamed rather than
+ * added when `entityRenames` (such as an index run's `renames`) maps a
+ * removed node's id to its id, or a removed entity went by one of its
+ * aliases (from annotations or the `aliases` and `renames` the graph was
+ * built with); the removed node is then reported as its `previous`
+ * instead of as a removal. Events come in `next` node order, followed by
+ * removals in `previous` order.
+ */
+export function diffGraphs(
+  previous: DependencyGraph,
+  next: DependencyGraph,
+  entityRenames: readonly Pick<EntityRename, 'from' | 'to'>[] = [],
+): readonly GraphChangeEvent[] {
+  const previousEdges = outgoing(previous);
+  const nextEdges = outgoing(next);
+  const before = new Map(previous.nodes.map((node) => [node.id, node]));
+  const nextIds = new Set(next.nodes.map((node) => node.id));
+  const removedByName = new Map<string, GraphNode>();
+  for (const node of previous.nodes) {
+    const key = node.name.toLowerCase();
+    if (!nextIds.has(node.id) && !node.external && !removedByName.has(key)) {
+      removedByName.set(key, node);
+    }
+  }
+  const fromIds = new Map(entityRenames.map(({ from, to }) => [to, from]));
+  const renamed = new Set<string>();
+  const renamedFrom = (node: GraphNode): GraphNode | undefined => {
+    const moved = before.get(fromIds.get(node.id) ?? '');
+    if (moved && !nextIds.has(moved.id) && !renamed.has(moved.id)) {
+      return moved;
+    }
+    for (const alias of node.aliases ?? []) {
+      const old = removedByName.get(alias.toLowerCase());
+      if (old && !renamed.has(old.id)) return old;
+    }
+    return undefined;
+  };
+  const events: GraphChangeEvent[] = [];
+
+  for (const node of next.nodes) {
+    const old = before.get(node.id);
+    if (!old) {
+      const from = renamedFrom(node);
+      if (from) {
+        renamed.add(from.id);
+        events.push({ type: 'node_renamed', node, previous: from });
+      } else {
+        events.push({ type: 'node_added', node });
+      }
+    } else if (
+      fingerprint(old, previousEdges) !== fingerprint(node, nextEdges)
+    ) {
+      events.push({ type: 'node_updated', node });
+    }
+  }
+  for (const node of previous.nodes) {
+    if (!nextIds.has(node.id) && !renamed.has(node.id)) {
+      events.push({ type: 'node_removed', node });
+    }
+  }
+  return events;
+}
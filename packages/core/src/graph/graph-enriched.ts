@@ -0,0 +1,85 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads the calls and HTTP calls enrichers recorded on entities as inferred graph dependencies
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, enrichers, call-graph, provenance]
+ * context:
+ *   business_goal: Put what enrichers found in the code on the graph, marked apart from declared dependencies
+ *   domain: graph
+ */
+import type { StoredEntity } from '../indexer/types.js';
+import type { ExtendedMetadata } from '../types/entity.js';
+import type { EdgeProvenance } from './types.js';
+
+/**
+ * Confidence of edges read from enricher output: names and URLs matched in
+ * source can reach the wrong entity, unlike a declared dependency.
+ */
+export const ENRICHED_EDGE_CONFIDENCE = 0.8;
+
+/**
+ * A dependency an enricher found in an entity's code: on the indexed
+ * entity `target` stands for, or on the external API `name`.
+ */
+export type EnrichedDependency<T> =
+  | {
+      readonly kind: 'service';
+      readonly target: T;
+      readonly provenance: EdgeProvenance;
+    }
+  | {
+      readonly kind: 'external_api';
+      readonly name: string;
+      readonly provenance: EdgeProvenance;
+    };
+
+/**
+ * An index of `path:name` and `path:line` references, as the calls
+ * enricher writes them, to what each entity added stands for. References
+ * to a name several entities in the file share match none.
+ */
+export function createEntityReferenceIndex<T>(): {
+  add(entity: Pick<StoredEntity, 'filePath' | 'name' | 'line'>, value: T): void;
+  resolve(ref: string): T | undefined;
+} {
+  const byRef = new Map<string, { value: T } | null>();
+  return {
+    add(entity, value) {
+      const named = `${entity.filePath}:${entity.name}`;
+      byRef.set(named, byRef.has(named) ? null : { value });
+      byRef.set(`${entity.filePath}:${entity.line}`, { value });
+    },
+    resolve: (ref) => byRef.get(ref)?.value,
+  };
+}
+
+/**
+ * The dependencies the enrichers recorded for `entity`: a `call` edge to
+ * each entity in `calls`, a `router` edge to each entity serving one of
+ * its `http_calls`, and a `call` edge to each external API they joined.
+ * References that no longer resolve are left out.
+ */
+export function enrichedDependencies<T>(
+  entity: Pick<StoredEntity, 'metadata'>,
+  resolve: (ref: string) => T | undefined,
+): readonly EnrichedDependency<T>[] {
+  const metadata = entity.metadata as ExtendedMetadata;
+  const found: EnrichedDependency<T>[] = [];
+  for (const ref of metadata.calls ?? []) {
+    const target = resolve(ref);
+    if (target !== undefined) {
+      found.push({ kind: 'service', target, provenance: 'call' });
+    }
+  }
+  for (const call of metadata.http_calls ?? []) {
+    const target = call.service ? resolve(call.service) : undefined;
+    if (target !== undefined) {
+      found.push({ kind: 'service', target, provenance: 'router' });
+    } else if (call.api) {
+      found.push({ kind: 'external_api', name: call.api, provenance: 'call' });
+    }
+  }
+  return found;
+}
@@ -0,0 +1,91 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Resolves dependencies declared per environment, such as postgres in prod and sqlite in dev, to the lists one environment uses
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, dependencies, environments, topology]
+ * context:
+ *   business_goal: Show the topology each environment really runs instead of one list that is only true in prod
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  Dependencies,
+  DependencyEntry,
+  DependencyList,
+} from '../types/entity.js';
+
+const LIST_KEYS = ['services', 'external_apis', 'databases'] as const;
+
+/** The name of a dependency entry, a bare name or an annotated one. */
+export function dependencyEntryName(entry: DependencyEntry): string {
+  return typeof entry === 'string' ? entry : entry.name;
+}
+
+/** The environments any of `entities` declares dependencies for, sorted. */
+export function dependencyEnvironments(
+  entities: Iterable<StoredEntity>,
+): readonly string[] {
+  const environments = new Set<string>();
+  for (const entity of entities) {
+    const { metadata } = entity;
+    const deps = 'dependencies' in metadata ? metadata.dependencies : undefined;
+    for (const name of Object.keys(deps?.environments ?? {})) {
+      environments.add(name);
+    }
+  }
+  return [...environments].sort(compareStrings);
+}
+
+/**
+ * The dependencies that apply in `environment`: the lists for every
+ * environment, then those under `environments.<environment>`. Without an
+ * environment, those of every environment are added, so nothing declared
+ * is left out. Names listed twice are kept once, as first listed.
+ */
+export function environmentDependencies(
+  deps: Dependencies,
+  environment?: string,
+): DependencyList {
+  const variants = Object.entries(deps.environments ?? {})
+    .filter(([name]) => environment === undefined || name === environment)
+    .sort(([a], [b]) => compareStrings(a, b))
+    .map(([, list]) => list);
+  const result: Record<string, DependencyEntry[]> = {};
+  for (const key of LIST_KEYS) {
+    const entries = new Map<string, DependencyEntry>();
+    const listed = [deps, ...variants].flatMap((list) => list[key] ?? []);
+    for (const entry of listed) {
+      const name = dependencyEntryName(entry);
+      if (!entries.has(name)) entries.set(name, entry);
+    }
+    if (entries.size > 0) result[key] = [...entries.values()];
+  }
+  return result;
+}
+
+/**
+ * A copy of `entity` whose `dependencies` are those of `environment`
+ * (see `environmentDependencies`), without `environments`, so exports and
+ * query results show one environment's topology. `versions` are kept.
+ */
+export function selectEnvironment(
+  entity: StoredEntity,
+  environment: string,
+): StoredEntity {
+  const { metadata } = entity;
+  if (!('dependencies' in metadata) || !metadata.dependencies) return entity;
+  const { versions } = metadata.dependencies;
+  return {
+    ...entity,
+    metadata: {
+      ...metadata,
+      dependencies: {
+        ...environmentDependencies(metadata.dependencies, environment),
+        ...(versions ? { versions } : {}),
+      },
+    },
+  };
+}
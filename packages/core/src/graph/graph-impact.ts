@@ -0,0 +1,132 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Walks dependents of changed files to the build or test units they can break, falling back to all units when unsure
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, impact, ci, selection, traversal]
+ * context:
+ *   business_goal: Let CI build and test only what a change can break
+ *   domain: graph
+ */
+import { posix } from 'node:path';
+import type {
+  DependencyGraph,
+  ImpactMapping,
+  ImpactOptions,
+  ImpactSelection,
+  ImpactUnit,
+} from './types.js';
+
+const DEFAULT_MIN_COVERAGE = 0.8;
+
+function normalizeFile(file: string): string {
+  return file.replace(/\\/g, '/').replace(/^\.\//, '');
+}
+
+/**
+ * Pick the units `changedFiles` can break. Each changed file maps to its
+ * unit and to the annotated entities it defines; the selection is those
+ * units plus everything that depends on them, found by walking incoming
+ * edges of every kind. An entity and its unit stand for each other, so a
+ * declared dependency on an entity pulls in the unit of its dependent.
+ * Markdown files are not considered. When fewer than `minCoverage` of the
+ * other changed files map, or one of `globalFiles` changed, every unit is
+ * selected instead.
+ */
+export function selectImpactedUnits<U extends ImpactUnit>(
+  graph: DependencyGraph,
+  units: readonly U[],
+  mapping: ImpactMapping<U>,
+  changedFiles: readonly string[],
+  options: ImpactOptions = {},
+): ImpactSelection<U> {
+  const minCoverage = options.minCoverage ?? DEFAULT_MIN_COVERAGE;
+  const globalFiles = new Set(options.globalFiles ?? []);
+  const isGlobal = (file: string): boolean => {
+    const base = posix.basename(file);
+    return globalFiles.has(base) || globalFiles.has(`*${posix.extname(base)}`);
+  };
+
+  const entitiesByFile = new Map<string, string[]>();
+  const membersByUnit = new Map<string, string[]>();
+  const unitByEntity = new Map<string, string>();
+  for (const node of graph.nodes) {
+    if (node.external || node.entityType === null || !node.filePath) continue;
+    const file = normalizeFile(node.filePath);
+    entitiesByFile.set(file, [...(entitiesByFile.get(file) ?? []), node.id]);
+    const unit = mapping.unitOfNode(node);
+    if (!unit) continue;
+    unitByEntity.set(node.id, unit.id);
+    membersByUnit.set(unit.id, [
+      ...(membersByUnit.get(unit.id) ?? []),
+      node.id,
+    ]);
+  }
+
+  const considered = [...new Set(changedFiles.map(normalizeFile))].filter(
+    (file) => !file.endsWith('.md'),
+  );
+  const seeds = new Set<string>();
+  const changedUnits = new Set<string>();
+  const unmapped: string[] = [];
+  let globalFile: string | undefined;
+  for (const file of considered) {
+    if (isGlobal(file)) globalFile ??= file;
+    const unit = mapping.unitOfFile(file);
+    const entities = entitiesByFile.get(file) ?? [];
+    if (unit) {
+      seeds.add(unit.id);
+      changedUnits.add(unit.id);
+    }
+    for (const id of entities) seeds.add(id);
+    if (!unit && entities.length === 0) unmapped.push(file);
+  }
+  const coverage =
+    considered.length === 0
+      ? 1
+      : (considered.length - unmapped.length) / considered.length;
+  const changed = units.filter((unit) => changedUnits.has(unit.id));
+
+  let reason: string | null = null;
+  if (globalFile) {
+    reason = `${globalFile} changed`;
+  } else if (coverage < minCoverage) {
+    reason =
+      `only ${Math.round(coverage * 100)}% of changed files map to the ` +
+      `graph (minimum ${Math.round(minCoverage * 100)}%)`;
+  }
+  if (reason) {
+    return { full: true, reason, units, changed, unmapped, coverage };
+  }
+
+  const dependents = new Map<string, string[]>();
+  for (const edge of graph.edges) {
+    dependents.set(edge.to, [...(dependents.get(edge.to) ?? []), edge.from]);
+  }
+  const visited = new Set(seeds);
+  const queue = [...seeds];
+  while (queue.length > 0) {
+    const id = queue.shift()!;
+    const next = [
+      ...(dependents.get(id) ?? []),
+      ...(membersByUnit.get(id) ?? []),
+    ];
+    const unitId = unitByEntity.get(id);
+    if (unitId) next.push(unitId);
+    for (const other of next) {
+      if (visited.has(other)) continue;
+      visited.add(other);
+      queue.push(other);
+    }
+  }
+
+  return {
+    full: false,
+    reason,
+    units: units.filter((unit) => visited.has(unit.id)),
+    changed,
+    unmapped,
+    coverage,
+  };
+}
@@ -0,0 +1,64 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Resolves configured aliases and rename records to the names services and entities go by now
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, aliases, renames, names]
+ * context:
+ *   business_goal: Keep dependencies on historical service names pointing at the right component
+ *   domain: graph
+ */
+import type { GraphNameOptions, NameTable } from './types.js';
+
+interface NameLink {
+  /** The old name or alias as written. */
+  readonly name: string;
+  /** The name it points to, which may itself have been renamed. */
+  readonly next: string;
+}
+
+/**
+ * A table of the `aliases` and `renames` in `options`. Names compare
+ * case-insensitively, like dependency names do. Rename chains are followed
+ * to the last name; a cycle stops at the name that would repeat.
+ */
+export function createNameTable(options: GraphNameOptions = {}): NameTable {
+  const links = new Map<string, NameLink>();
+  const link = (name: string, next: string): void => {
+    const key = name.toLowerCase();
+    if (key !== next.toLowerCase() && !links.has(key)) {
+      links.set(key, { name, next });
+    }
+  };
+  for (const rename of options.renames ?? []) link(rename.from, rename.to);
+  for (const [current, aliases] of Object.entries(options.aliases ?? {})) {
+    for (const alias of aliases) link(alias, current);
+  }
+
+  function canonical(name: string): string {
+    let current = name;
+    const visited = new Set<string>();
+    for (;;) {
+      const key = current.toLowerCase();
+      const next = links.get(key);
+      if (!next || visited.has(key)) return current;
+      visited.add(key);
+      current = next.next;
+    }
+  }
+
+  const byCurrent = new Map<string, string[]>();
+  for (const { name } of links.values()) {
+    const key = canonical(name).toLowerCase();
+    if (key === name.toLowerCase()) continue;
+    const names = byCurrent.get(key) ?? [];
+    names.push(name);
+    byCurrent.set(key, names);
+  }
+
+  return {
+    canonical,
+    aliasesOf: (name) => byCurrent.get(name.toLowerCase()) ?? [],
+  };
+}
@@ -0,0 +1,152 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Finds the k shortest dependency paths between two graph nodes, and the nodes a name refers to
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, paths, traversal, coupling]
+ * context:
+ *   business_goal: Explain how two components that should not know about each other came to be coupled
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { looseName } from './graph-builder.js';
+import { edgeMatches } from './graph-provenance.js';
+import type {
+  DependencyGraph,
+  GraphEdge,
+  GraphNode,
+  GraphPath,
+  PathOptions,
+} from './types.js';
+
+const DEFAULT_PATH_COUNT = 3;
+
+/**
+ * The nodes `name` refers to: the node with that id, else those with that
+ * name or alias, else those whose name matches ignoring case, hyphens, and
+ * underscores, so `checkout-service` finds `CheckoutService`.
+ */
+export function findGraphNodes(
+  graph: DependencyGraph,
+  name: string,
+): readonly GraphNode[] {
+  const byId = graph.nodes.find((node) => node.id === name);
+  if (byId) return [byId];
+  const named = graph.nodes.filter(
+    (node) => node.name === name || node.aliases?.includes(name),
+  );
+  if (named.length > 0) return named;
+  const loose = looseName(name);
+  return graph.nodes.filter((node) => looseName(node.name) === loose);
+}
+
+/** One id sequence per path; the edges are looked up once it is chosen. */
+type IdPath = readonly string[];
+
+function pathKey(path: IdPath): string {
+  return path.join('\0');
+}
+
+function comparePaths(a: IdPath, b: IdPath): number {
+  return a.length - b.length || compareStrings(pathKey(a), pathKey(b));
+}
+
+/**
+ * The `k` shortest paths from `fromId` to `toId` along dependency edges,
+ * fewest hops first, ties in node id order (Yen's algorithm over
+ * breadth-first searches). Paths never visit a node twice. Where two nodes
+ * are joined by several edges, a path goes by the most confident, the
+ * first in graph order on a tie. Empty when either node is missing or no
+ * path exists.
+ */
+export function findShortestPaths(
+  graph: DependencyGraph,
+  fromId: string,
+  toId: string,
+  options: PathOptions = {},
+): readonly GraphPath[] {
+  const { k = DEFAULT_PATH_COUNT, kinds } = options;
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  if (!nodes.has(fromId) || !nodes.has(toId) || fromId === toId) return [];
+
+  const chosen = new Map<string, GraphEdge>();
+  for (const edge of graph.edges) {
+    if (kinds && !kinds.includes(edge.kind)) continue;
+    if (!edgeMatches(edge, options)) continue;
+    if (!nodes.has(edge.from) || !nodes.has(edge.to)) continue;
+    const key = `${edge.from}\0${edge.to}`;
+    const current = chosen.get(key);
+    if (!current || edge.confidence > current.confidence) {
+      chosen.set(key, edge);
+    }
+  }
+  const adjacency = new Map<string, string[]>();
+  for (const edge of chosen.values()) {
+    const next = adjacency.get(edge.from);
+    if (next) next.push(edge.to);
+    else adjacency.set(edge.from, [edge.to]);
+  }
+  for (const next of adjacency.values()) next.sort(compareStrings);
+
+  // Breadth-first, so the first path found is one of the shortest
+  function shortest(
+    start: string,
+    blockedNodes: ReadonlySet<string>,
+    blockedEdges: ReadonlySet<string>,
+  ): IdPath | undefined {
+    const previous = new Map<string, string>([[start, start]]);
+    let frontier = [start];
+    while (frontier.length > 0 && !previous.has(toId)) {
+      const nextFrontier: string[] = [];
+      for (const id of frontier) {
+        for (const to of adjacency.get(id) ?? []) {
+          if (previous.has(to) || blockedNodes.has(to)) continue;
+          if (blockedEdges.has(`${id}\0${to}`)) continue;
+          previous.set(to, id);
+          nextFrontier.push(to);
+        }
+      }
+      frontier = nextFrontier;
+    }
+    if (!previous.has(toId)) return undefined;
+    const path = [toId];
+    while (path[0] !== start) path.unshift(previous.get(path[0]) as string);
+    return path;
+  }
+
+  const first = shortest(fromId, new Set(), new Set());
+  if (!first) return [];
+  const found: IdPath[] = [first];
+  const seen = new Set([pathKey(first)]);
+  const candidates: IdPath[] = [];
+  while (found.length < k) {
+    const last = found[found.length - 1];
+    for (let i = 0; i < last.length - 1; i++) {
+      const root = last.slice(0, i + 1);
+      const rootKey = pathKey(root);
+      const blockedEdges = new Set(
+        found
+          .filter((path) => pathKey(path.slice(0, i + 1)) === rootKey)
+          .map((path) => `${path[i]}\0${path[i + 1]}`),
+      );
+      const spur = shortest(last[i], new Set(root.slice(0, i)), blockedEdges);
+      if (!spur) continue;
+      const candidate = [...root.slice(0, i), ...spur];
+      const key = pathKey(candidate);
+      if (seen.has(key)) continue;
+      seen.add(key);
+      candidates.push(candidate);
+    }
+    if (candidates.length === 0) break;
+    candidates.sort(comparePaths);
+    found.push(candidates.shift() as IdPath);
+  }
+
+  return found.map((path) => ({
+    nodes: path.map((id) => nodes.get(id) as GraphNode),
+    edges: path
+      .slice(1)
+      .map((to, i) => chosen.get(`${path[i]}\0${to}`) as GraphEdge),
+  }));
+}
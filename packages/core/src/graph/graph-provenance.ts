@@ -0,0 +1,109 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Names edge provenances, fills them in for older graphs, and filters edges by provenance, confidence, protocol, and criticality
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, provenance, confidence, filter, audit]
+ * context:
+ *   business_goal: Let audits tell declared dependencies from inferred ones
+ *   domain: graph
+ */
+import type {
+  DependencyGraph,
+  DependencyKind,
+  EdgeFilter,
+  EdgeProvenance,
+  GraphEdge,
+} from './types.js';
+
+export const EDGE_PROVENANCES: readonly EdgeProvenance[] = [
+  'declared',
+  'import',
+  'call',
+  'router',
+  'build',
+  'manual',
+  'derived',
+];
+
+/**
+ * The provenance of a `kind` edge in a graph written before edges recorded
+ * one: the only way such edges were derived.
+ */
+export function defaultEdgeProvenance(kind: DependencyKind): EdgeProvenance {
+  if (kind === 'import') return 'import';
+  if (kind === 'build') return 'build';
+  return 'declared';
+}
+
+/**
+ * `edge` with provenance and confidence, defaulted when it predates them
+ * (as edges in older JSON exports do), the version it requires, and what
+ * its dependency entry annotates it with.
+ */
+export function withProvenance(
+  edge: Pick<GraphEdge, 'from' | 'to' | 'kind'> & Partial<GraphEdge>,
+): GraphEdge {
+  return {
+    from: edge.from,
+    to: edge.to,
+    kind: edge.kind,
+    provenance: edge.provenance ?? defaultEdgeProvenance(edge.kind),
+    confidence: edge.confidence ?? 1,
+    ...(edge.requires ? { requires: edge.requires } : {}),
+    ...(edge.purpose ? { purpose: edge.purpose } : {}),
+    ...(edge.protocol ? { protocol: edge.protocol } : {}),
+    ...(edge.criticality ? { criticality: edge.criticality } : {}),
+  };
+}
+
+export function edgeMatches(edge: GraphEdge, filter: EdgeFilter): boolean {
+  return (
+    (!filter.provenance || filter.provenance.includes(edge.provenance)) &&
+    edge.confidence >= (filter.minConfidence ?? 0) &&
+    (!filter.protocols ||
+      (edge.protocol !== undefined &&
+        filter.protocols.includes(edge.protocol))) &&
+    (!filter.criticalities ||
+      (edge.criticality !== undefined &&
+        filter.criticalities.includes(edge.criticality)))
+  );
+}
+
+/**
+ * `graph` with only the edges `filter` selects. External stubs exist only
+ * for the edges that use them, so stubs left without edges are dropped;
+ * every other node is kept.
+ */
+export function filterGraphEdges(
+  graph: DependencyGraph,
+  filter: EdgeFilter,
+): DependencyGraph {
+  const edges = graph.edges.filter((edge) => edgeMatches(edge, filter));
+  const used = new Set(edges.flatMap((edge) => [edge.from, edge.to]));
+  return {
+    nodes: graph.nodes.filter((node) => !node.external || used.has(node.id)),
+    edges,
+  };
+}
+
+/**
+ * Parse a comma-separated list of provenances, such as `--provenance
+ * declared,import`. Throws on an unknown name.
+ */
+export function parseEdgeProvenances(value: string): EdgeProvenance[] {
+  const names = value
+    .split(',')
+    .map((name) => name.trim())
+    .filter((name) => name !== '');
+  const unknown = names.find(
+    (name) => !EDGE_PROVENANCES.includes(name as EdgeProvenance),
+  );
+  if (unknown !== undefined || names.length === 0) {
+    throw new Error(
+      `Invalid provenance "${unknown ?? value}": use ${EDGE_PROVENANCES.join(', ')}`,
+    );
+  }
+  return names as EdgeProvenance[];
+}
@@ -0,0 +1,200 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Prunes dependency graphs to a size budget by collapsing leaf functions and dropping insignificant nodes, recording every decision
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, prune, budget, visualization, llm-context]
+ * context:
+ *   business_goal: Fit large graphs into visualizations and LLM context windows without nodes vanishing unexplained
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type {
+  DependencyGraph,
+  GraphEdge,
+  GraphNode,
+  PruneDecision,
+  PruneOptions,
+  PruneReason,
+  PruneResult,
+} from './types.js';
+
+/** Each node's significance: the number of edges it has, either way. */
+export function graphSignificance(
+  graph: DependencyGraph,
+): ReadonlyMap<string, number> {
+  const counts = new Map(graph.nodes.map((node) => [node.id, 0]));
+  const count = (id: string): void => {
+    const current = counts.get(id);
+    if (current !== undefined) counts.set(id, current + 1);
+  };
+  for (const edge of graph.edges) {
+    count(edge.from);
+    if (edge.to !== edge.from) count(edge.to);
+  }
+  return counts;
+}
+
+function decide(
+  node: GraphNode,
+  reason: PruneReason,
+  significance: ReadonlyMap<string, number>,
+  into?: string,
+): PruneDecision {
+  return {
+    node: node.id,
+    name: node.name,
+    reason,
+    significance: significance.get(node.id) ?? 0,
+    ...(into !== undefined ? { into } : {}),
+  };
+}
+
+/** `graph` without `dropped` and the edges that touch them. */
+function dropNodes(
+  graph: DependencyGraph,
+  dropped: ReadonlySet<string>,
+): DependencyGraph {
+  return {
+    nodes: graph.nodes.filter((node) => !dropped.has(node.id)),
+    edges: graph.edges.filter(
+      (edge) => !dropped.has(edge.from) && !dropped.has(edge.to),
+    ),
+  };
+}
+
+/**
+ * Edges moved onto the module of a collapsed function can repeat one the
+ * module already has; keep one of each, the most confident.
+ */
+function dedupeEdges(edges: readonly GraphEdge[]): readonly GraphEdge[] {
+  const byKey = new Map<string, GraphEdge>();
+  for (const edge of edges) {
+    const key = [edge.from, edge.to, edge.kind, edge.provenance].join('\0');
+    const current = byKey.get(key);
+    if (!current || edge.confidence > current.confidence) {
+      byKey.set(key, edge);
+    }
+  }
+  return [...byKey.values()];
+}
+
+function collapseLeafFunctions(
+  graph: DependencyGraph,
+  decisions: PruneDecision[],
+): DependencyGraph {
+  const modules = new Map<string, GraphNode>();
+  for (const node of graph.nodes) {
+    if (node.external || node.entityType !== 'module' || !node.filePath) {
+      continue;
+    }
+    const current = modules.get(node.filePath);
+    if (!current || compareStrings(node.id, current.id) < 0) {
+      modules.set(node.filePath, node);
+    }
+  }
+  const dependedOn = new Set(graph.edges.map((edge) => edge.to));
+  const significance = graphSignificance(graph);
+  const into = new Map<string, string>();
+  for (const node of graph.nodes) {
+    if (node.external || node.entityType !== 'function') continue;
+    const module = node.filePath ? modules.get(node.filePath) : undefined;
+    if (!module || dependedOn.has(node.id)) continue;
+    into.set(node.id, module.id);
+    decisions.push(decide(node, 'leaf-function', significance, module.id));
+  }
+  if (into.size === 0) return graph;
+  return {
+    nodes: graph.nodes.filter((node) => !into.has(node.id)),
+    edges: dedupeEdges(
+      graph.edges
+        .map((edge) => ({ ...edge, from: into.get(edge.from) ?? edge.from }))
+        .filter((edge) => edge.from !== edge.to),
+    ),
+  };
+}
+
+function dropBelow(
+  graph: DependencyGraph,
+  minSignificance: number,
+  decisions: PruneDecision[],
+): DependencyGraph {
+  const significance = graphSignificance(graph);
+  const dropped = graph.nodes.filter(
+    (node) => (significance.get(node.id) ?? 0) < minSignificance,
+  );
+  for (const node of dropped) {
+    decisions.push(decide(node, 'below-significance', significance));
+  }
+  return dropNodes(graph, new Set(dropped.map((node) => node.id)));
+}
+
+function dropOverBudget(
+  graph: DependencyGraph,
+  maxNodes: number,
+  decisions: PruneDecision[],
+): DependencyGraph {
+  if (graph.nodes.length <= maxNodes) return graph;
+  const significance = graphSignificance(graph);
+  const weight = (node: GraphNode): number => significance.get(node.id) ?? 0;
+  const dropped = new Set(
+    [...graph.nodes]
+      .sort((a, b) => weight(a) - weight(b) || compareStrings(a.id, b.id))
+      .slice(0, graph.nodes.length - maxNodes)
+      .map((node) => node.id),
+  );
+  for (const node of graph.nodes) {
+    if (dropped.has(node.id)) {
+      decisions.push(decide(node, 'over-budget', significance));
+    }
+  }
+  return dropNodes(graph, dropped);
+}
+
+/** External stubs exist only for their edges, so drop those left without. */
+function dropOrphans(
+  graph: DependencyGraph,
+  before: ReadonlyMap<string, number>,
+  decisions: PruneDecision[],
+): DependencyGraph {
+  const significance = graphSignificance(graph);
+  const orphans = graph.nodes.filter(
+    (node) =>
+      node.external &&
+      significance.get(node.id) === 0 &&
+      (before.get(node.id) ?? 0) > 0,
+  );
+  for (const node of orphans) {
+    decisions.push(decide(node, 'orphaned', significance));
+  }
+  return dropNodes(graph, new Set(orphans.map((node) => node.id)));
+}
+
+/**
+ * Cut `graph` down with the strategies in `options`, applied in order:
+ * collapse leaf functions, drop nodes below the significance threshold,
+ * then drop the least significant nodes (ties by id) over `maxNodes`.
+ * External stubs orphaned along the way go too. Every node left out is
+ * listed in `decisions`, with its significance at the time.
+ */
+export function pruneGraph(
+  graph: DependencyGraph,
+  options: PruneOptions,
+): PruneResult {
+  const decisions: PruneDecision[] = [];
+  const before = graphSignificance(graph);
+  let pruned = options.collapseFunctions
+    ? collapseLeafFunctions(graph, decisions)
+    : graph;
+  if (options.minSignificance !== undefined) {
+    pruned = dropBelow(pruned, options.minSignificance, decisions);
+  }
+  if (options.maxNodes !== undefined) {
+    pruned = dropOverBudget(pruned, options.maxNodes, decisions);
+  }
+  return {
+    graph: dropOrphans(pruned, before, decisions),
+    decisions,
+  };
+}
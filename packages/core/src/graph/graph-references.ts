@@ -0,0 +1,26 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Finds wiki-style double-bracket references to other entities in descriptions
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, references, descriptions, navigation]
+ * context:
+ *   business_goal: Turn the prose in descriptions into structure readers can navigate
+ *   domain: graph
+ */
+import type { DescriptionReference } from './types.js';
+
+const REFERENCE_PATTERN = /\[\[([^[\]\n]+?)\]\]/g;
+
+/** The `[[target]]` references in `text`, in order. */
+export function findDescriptionReferences(
+  text: string,
+): readonly DescriptionReference[] {
+  return [...text.matchAll(REFERENCE_PATTERN)].flatMap((match) => {
+    const target = match[1].trim();
+    if (!target) return [];
+    const start = match.index ?? 0;
+    return [{ target, start, end: start + match[0].length }];
+  });
+}
@@ -0,0 +1,163 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Derives edges from declarative rules, joining entities that share an annotation value or the two ends of a chain of edges
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, rules, derived, edges, provenance]
+ * context:
+ *   business_goal: Let teams encode their own graph semantics, such as services sharing a datastore, without code changes
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  EdgeRule,
+  EdgeRuleEnd,
+  GraphEdge,
+  GraphNode,
+} from './types.js';
+
+/** The edge kinds the graph builder adds, which no rule may name. */
+export const BUILTIN_EDGE_KINDS: readonly DependencyKind[] = [
+  'service',
+  'external_api',
+  'database',
+  'build',
+  'import',
+  'reference',
+];
+
+function matchesEnd(node: GraphNode, end: EdgeRuleEnd | undefined): boolean {
+  return (
+    (end?.type === undefined || node.entityType === end.type) &&
+    (end?.owner === undefined || node.owner === end.owner) &&
+    (end?.domain === undefined || node.domain === end.domain)
+  );
+}
+
+/** The strings and numbers at the dotted annotation `field` of `entity`. */
+function fieldValues(entity: StoredEntity, field: string): readonly string[] {
+  let value: unknown = entity.metadata;
+  for (const key of field.split('.')) {
+    if (typeof value !== 'object' || value === null || Array.isArray(value)) {
+      return [];
+    }
+    value = (value as Record<string, unknown>)[key];
+  }
+  const values = Array.isArray(value) ? value : [value];
+  return values.flatMap((item) =>
+    typeof item === 'string' || typeof item === 'number' ? [String(item)] : [],
+  );
+}
+
+/**
+ * Pairs of entity nodes with a value of `rule.shared` in common. A pair
+ * each of whose nodes could start the edge is joined once, from the node
+ * whose id sorts first.
+ */
+function sharedPairs(
+  rule: EdgeRule,
+  field: string,
+  entities: readonly StoredEntity[],
+  nodes: ReadonlyMap<string, GraphNode>,
+): Array<readonly [GraphNode, GraphNode]> {
+  const byValue = new Map<string, GraphNode[]>();
+  for (const entity of entities) {
+    const node = nodes.get(entity.id);
+    if (!node || (!matchesEnd(node, rule.from) && !matchesEnd(node, rule.to))) {
+      continue;
+    }
+    for (const value of new Set(fieldValues(entity, field))) {
+      const holders = byValue.get(value);
+      if (holders) holders.push(node);
+      else byValue.set(value, [node]);
+    }
+  }
+  const pairs: Array<readonly [GraphNode, GraphNode]> = [];
+  for (const holders of byValue.values()) {
+    for (const from of holders) {
+      if (!matchesEnd(from, rule.from)) continue;
+      for (const to of holders) {
+        if (from === to || !matchesEnd(to, rule.to)) continue;
+        const reverse = matchesEnd(to, rule.from) && matchesEnd(from, rule.to);
+        if (reverse && compareStrings(from.id, to.id) > 0) continue;
+        pairs.push([from, to]);
+      }
+    }
+  }
+  return pairs;
+}
+
+/** The first and last node of each chain of edges of the `path` kinds. */
+function pathPairs(
+  rule: EdgeRule,
+  path: readonly DependencyKind[],
+  graph: DependencyGraph,
+  nodes: ReadonlyMap<string, GraphNode>,
+): Array<readonly [GraphNode, GraphNode]> {
+  const outgoing = new Map<string, GraphEdge[]>();
+  for (const edge of graph.edges) {
+    const edges = outgoing.get(edge.from);
+    if (edges) edges.push(edge);
+    else outgoing.set(edge.from, [edge]);
+  }
+  const pairs: Array<readonly [GraphNode, GraphNode]> = [];
+  for (const from of graph.nodes) {
+    if (!matchesEnd(from, rule.from)) continue;
+    let reached = new Set([from.id]);
+    for (const kind of path) {
+      const next = new Set<string>();
+      for (const id of reached) {
+        for (const edge of outgoing.get(id) ?? []) {
+          if (edge.kind === kind) next.add(edge.to);
+        }
+      }
+      reached = next;
+    }
+    for (const id of [...reached].sort(compareStrings)) {
+      const to = nodes.get(id);
+      if (to && to !== from && matchesEnd(to, rule.to)) pairs.push([from, to]);
+    }
+  }
+  return pairs;
+}
+
+/**
+ * The edges `rules` add to `graph`, built from `entities` and not yet
+ * namespaced, with `derived` provenance. Rules see only the edges of
+ * `graph`, never those another rule derives, so their order does not
+ * matter. Edges `graph` already has are left out.
+ */
+export function deriveEdges(
+  graph: DependencyGraph,
+  entities: readonly StoredEntity[],
+  rules: readonly EdgeRule[],
+): readonly GraphEdge[] {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const key = (edge: Pick<GraphEdge, 'from' | 'to' | 'kind'>): string =>
+    `${edge.from}\u0000${edge.to}\u0000${edge.kind}`;
+  const seen = new Set(graph.edges.map(key));
+  const derived: GraphEdge[] = [];
+  for (const rule of rules) {
+    const pairs =
+      rule.shared !== undefined
+        ? sharedPairs(rule, rule.shared, entities, nodes)
+        : pathPairs(rule, rule.path ?? [], graph, nodes);
+    for (const [from, to] of pairs) {
+      const edge: GraphEdge = {
+        from: from.id,
+        to: to.id,
+        kind: rule.kind,
+        provenance: 'derived',
+        confidence: rule.confidence ?? 1,
+      };
+      if (seen.has(key(edge))) continue;
+      seen.add(key(edge));
+      derived.push(edge);
+    }
+  }
+  return derived;
+}
@@ -0,0 +1,47 @@
+/**
+ * @knowgraph
+ * type: function
+ * description: Matches the service names operational tools report to the graph nodes that make up each service
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, services, aliases, workspaces]
+ * context:
+ *   business_goal: Join deployments and incidents recorded per service to the code they concern
+ *   domain: graph
+ */
+import type { GraphNode } from './types.js';
+
+function indexBy(
+  nodes: readonly GraphNode[],
+  keys: (node: GraphNode) => readonly (string | null)[],
+): Map<string, GraphNode[]> {
+  const byKey = new Map<string, GraphNode[]>();
+  for (const node of nodes) {
+    for (const key of keys(node)) {
+      if (!key) continue;
+      const matches = byKey.get(key.toLowerCase()) ?? [];
+      if (!matches.includes(node)) matches.push(node);
+      byKey.set(key.toLowerCase(), matches);
+    }
+  }
+  return byKey;
+}
+
+/**
+ * A lookup from a service name, such as an ArgoCD app or an incident's
+ * affected service, to the nodes it covers: those named like it or with it
+ * among their aliases, and every node in the workspace member of that name.
+ * Names compare case-insensitively.
+ */
+export function createServiceMatcher(
+  nodes: readonly GraphNode[],
+): (service: string) => readonly GraphNode[] {
+  const byName = indexBy(nodes, (node) => [node.name, ...(node.aliases ?? [])]);
+  const byWorkspace = indexBy(nodes, (node) => [node.workspace]);
+  return (service) => {
+    const key = service.toLowerCase();
+    return [
+      ...new Set([...(byName.get(key) ?? []), ...(byWorkspace.get(key) ?? [])]),
+    ];
+  };
+}
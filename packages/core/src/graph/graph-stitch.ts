@@ -0,0 +1,184 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Merges dependency graphs and resolves external stubs against real nodes by service registry entry, name, or alias
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, stitch, merge, stubs, federation]
+ * context:
+ *   business_goal: Join services across repositories by the names teams actually use for them
+ *   domain: graph
+ */
+import { suggestKey } from '../configlint/config-lint.js';
+import type { EntityType } from '../types/entity.js';
+import { isPreferredTarget } from './graph-builder.js';
+import { checkGraphConstraints } from './graph-constraints.js';
+import { indexServiceRegistry, registryKey } from './service-registry.js';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  GraphEdge,
+  GraphNode,
+  RegisteredService,
+  StitchOptions,
+  StitchResult,
+  StubResolution,
+  UnresolvedStub,
+} from './types.js';
+
+type Target = GraphNode & { readonly entityType: EntityType };
+
+function isTarget(node: GraphNode): node is Target {
+  return !node.external && !node.stub && node.entityType !== null;
+}
+
+function addTarget(
+  index: Map<string, Target>,
+  name: string,
+  node: Target,
+): void {
+  const key = name.toLowerCase();
+  if (isPreferredTarget(node, index.get(key))) index.set(key, node);
+}
+
+/**
+ * The node a registered service points at: one named its `node` or
+ * `name`, or with that alias, in its `repo`, or failing that in a graph
+ * built without a namespace.
+ */
+function registeredTarget(
+  service: RegisteredService,
+  targets: readonly Target[],
+): Target | undefined {
+  const wanted = (service.node ?? service.name).toLowerCase();
+  const named = targets.filter(
+    (node) =>
+      node.name.toLowerCase() === wanted ||
+      (node.aliases ?? []).some((alias) => alias.toLowerCase() === wanted),
+  );
+  const inRepo = named.filter((node) => node.namespace === service.repo);
+  const candidates =
+    inRepo.length > 0
+      ? inRepo
+      : named.filter((node) => node.namespace === undefined);
+  let best: Target | undefined;
+  for (const node of candidates) {
+    if (isPreferredTarget(node, best)) best = node;
+  }
+  return best;
+}
+
+/**
+ * Merge `graphs` into one and resolve their external stubs. A stub whose
+ * name, alias, or endpoint is in `options.registry` is replaced by the
+ * node the registry places the service at, so a dependency resolves
+ * across repositories that name the service differently. Failing that, a
+ * stub whose name matches a real node's name, or one of its `aliases`
+ * (case-insensitively), is replaced by that node, with service and module
+ * nodes preferred as the graph builder prefers them. Edges are redirected
+ * and deduplicated; stubs nothing matches are kept and reported, service
+ * stubs with the name they are a near miss of, if any, as their
+ * `suggestion`.
+ *
+ * Nodes with the same id are merged, keeping the first copy unless it is
+ * a scoped-graph stub and a later graph has the real node. Node and edge
+ * order follows `graphs`. The merge is checked against
+ * `options.constraints` as `checkGraphConstraints` checks it.
+ */
+export function stitchGraphs(
+  graphs: readonly DependencyGraph[],
+  options: StitchOptions = {},
+): StitchResult {
+  const nodes = new Map<string, GraphNode>();
+  for (const graph of graphs) {
+    for (const node of graph.nodes) {
+      const existing = nodes.get(node.id);
+      if (!existing || (existing.stub && !node.stub)) nodes.set(node.id, node);
+    }
+  }
+
+  const targets = [...nodes.values()].filter(isTarget);
+  const byName = new Map<string, Target>();
+  const byAlias = new Map<string, Target>();
+  for (const node of targets) {
+    addTarget(byName, node.name, node);
+    for (const alias of node.aliases ?? []) addTarget(byAlias, alias, node);
+  }
+  const registered = indexServiceRegistry(options.registry);
+
+  const redirects = new Map<string, string>();
+  const resolved: StubResolution[] = [];
+  for (const node of nodes.values()) {
+    if (!node.external) continue;
+    const service = registered.get(registryKey(node.name));
+    const listed = service ? registeredTarget(service, targets) : undefined;
+    const key = node.name.toLowerCase();
+    const named = listed ? undefined : byName.get(key);
+    const target = listed ?? named ?? byAlias.get(key);
+    if (!target) continue;
+    redirects.set(node.id, target.id);
+    resolved.push({
+      stub: node.id,
+      target: target.id,
+      match: listed ? 'registry' : named ? 'name' : 'alias',
+    });
+  }
+
+  const edges: GraphEdge[] = [];
+  const seen = new Set<string>();
+  for (const graph of graphs) {
+    for (const edge of graph.edges) {
+      const to = redirects.get(edge.to) ?? edge.to;
+      const key = `${edge.from}\u0000${to}\u0000${edge.kind}`;
+      if (edge.from === to || seen.has(key)) continue;
+      seen.add(key);
+      edges.push(to === edge.to ? edge : { ...edge, to });
+    }
+  }
+
+  const incoming = new Map<string, GraphEdge[]>();
+  for (const edge of edges) {
+    const into = incoming.get(edge.to) ?? [];
+    into.push(edge);
+    incoming.set(edge.to, into);
+  }
+  const known = [
+    ...new Set([
+      ...targets.flatMap((node) => [node.name, ...(node.aliases ?? [])]),
+      ...(options.registry?.services ?? []).flatMap((service) => [
+        service.name,
+        ...service.aliases,
+      ]),
+    ]),
+  ];
+  const unresolved: UnresolvedStub[] = [];
+  for (const node of nodes.values()) {
+    if (!node.external || redirects.has(node.id)) continue;
+    const into = incoming.get(node.id) ?? [];
+    const kinds = [...new Set<DependencyKind>(into.map((edge) => edge.kind))];
+    const key = node.name.toLowerCase();
+    // Only services are named by hand in another repository's terms
+    const suggestion = kinds.includes('service')
+      ? suggestKey(
+          node.name,
+          known.filter((name) => name.toLowerCase() !== key),
+        )
+      : undefined;
+    unresolved.push({
+      node,
+      kinds,
+      dependents: [...new Set(into.map((edge) => edge.from))],
+      ...(suggestion ? { suggestion } : {}),
+    });
+  }
+
+  return {
+    graph: {
+      nodes: [...nodes.values()].filter((node) => !redirects.has(node.id)),
+      edges,
+    },
+    resolved,
+    unresolved,
+    conflicts: checkGraphConstraints(graphs, options.constraints),
+  };
+}
@@ -0,0 +1,60 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Breadth-first traversal of a dependency graph by direction, depth, edge kind, and provenance
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, traversal, bfs, impact]
+ * context:
+ *   business_goal: Answer what a component depends on and what depends on it
+ *   domain: graph
+ */
+import { edgeMatches } from './graph-provenance.js';
+import type {
+  DependencyGraph,
+  GraphEdge,
+  TraversalOptions,
+  TraversalStep,
+} from './types.js';
+
+/**
+ * Walk the graph breadth-first from `startId`, yielding each reachable node
+ * once with its distance and the edge it was reached by. The start node is
+ * not yielded. Stops silently when `startId` is not in the graph.
+ */
+export function* traverseGraph(
+  graph: DependencyGraph,
+  startId: string,
+  options: TraversalOptions = {},
+): Generator<TraversalStep> {
+  const { direction = 'outgoing', maxDepth = Infinity, kinds } = options;
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  if (!nodes.has(startId)) return;
+
+  const adjacency = new Map<string, { edge: GraphEdge; to: string }[]>();
+  const link = (from: string, to: string, edge: GraphEdge): void => {
+    adjacency.set(from, [...(adjacency.get(from) ?? []), { edge, to }]);
+  };
+  for (const edge of graph.edges) {
+    if (kinds && !kinds.includes(edge.kind)) continue;
+    if (!edgeMatches(edge, options)) continue;
+    if (direction !== 'incoming') link(edge.from, edge.to, edge);
+    if (direction !== 'outgoing') link(edge.to, edge.from, edge);
+  }
+
+  const visited = new Set([startId]);
+  let frontier = [startId];
+  for (let depth = 1; depth <= maxDepth && frontier.length > 0; depth++) {
+    const nextFrontier: string[] = [];
+    for (const id of frontier) {
+      for (const { edge, to } of adjacency.get(id) ?? []) {
+        const node = nodes.get(to);
+        if (!node || visited.has(to)) continue;
+        visited.add(to);
+        nextFrontier.push(to);
+        yield { node, depth, edge };
+      }
+    }
+    frontier = nextFrontier;
+  }
+}
@@ -0,0 +1,106 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Evaluates time-bounded owners and dependencies at a date, so an entity or graph reads as it stood then
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, temporal, validity, as-of, audit]
+ * context:
+ *   business_goal: Keep audits that span reorgs and migrations accurate about who owned what and what depended on what
+ *   domain: graph
+ */
+import { z } from 'zod';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  DependencyEntry,
+  DependencyList,
+  Validity,
+} from '../types/entity.js';
+import { dependencyEntryName } from './graph-environments.js';
+
+const LIST_KEYS = ['services', 'external_apis', 'databases'] as const;
+
+const DateSchema = z.string().date();
+
+/**
+ * Parse a `YYYY-MM-DD` date such as `--as-of 2026-03-31`. Throws a usage
+ * error on anything else.
+ */
+export function parseAsOfDate(value: string): string {
+  if (!DateSchema.safeParse(value).success) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid date '${value}': expected YYYY-MM-DD`,
+    );
+  }
+  return value;
+}
+
+/**
+ * Whether `date` falls in `window`: on or after `valid_from` and before
+ * `valid_until`. Dates are `YYYY-MM-DD`, so they compare as strings.
+ */
+export function isValidAt(window: Validity | undefined, date: string): boolean {
+  return (
+    (window?.valid_from === undefined || window.valid_from <= date) &&
+    (window?.valid_until === undefined || date < window.valid_until)
+  );
+}
+
+/**
+ * The owner of `entity` on `date`: the first of its `owners` whose window
+ * holds the date, or its `owner` when none does.
+ */
+export function ownerAt(entity: StoredEntity, date: string): string | null {
+  const { metadata } = entity;
+  const owners = 'owners' in metadata ? (metadata.owners ?? []) : [];
+  return owners.find((dated) => isValidAt(dated, date))?.owner ?? entity.owner;
+}
+
+/**
+ * A copy of `entity` as it stood on `date`: its owner then (see `ownerAt`)
+ * and only the dependencies whose `dependencies.validity` holds the date,
+ * in every environment. `owners` and `validity` are resolved, so they are
+ * left out.
+ */
+export function selectAsOf(entity: StoredEntity, date: string): StoredEntity {
+  const { metadata } = entity;
+  const deps = 'dependencies' in metadata ? metadata.dependencies : undefined;
+  const owners = 'owners' in metadata ? metadata.owners : undefined;
+  if (!deps && !owners) return entity;
+
+  const owner = ownerAt(entity, date);
+  const dated: Record<string, unknown> = { ...metadata };
+  delete dated.owners;
+  if (owner !== null) dated.owner = owner;
+  if (deps) {
+    const { validity = {} } = deps;
+    const valid = (list: DependencyList): DependencyList => {
+      const result: Record<string, DependencyEntry[]> = {};
+      for (const key of LIST_KEYS) {
+        const entries = list[key]?.filter((entry) => {
+          const name = dependencyEntryName(entry);
+          return isValidAt(
+            Object.hasOwn(validity, name) ? validity[name] : undefined,
+            date,
+          );
+        });
+        if (entries) result[key] = entries;
+      }
+      return result;
+    };
+    const dependencies: Record<string, unknown> = { ...deps, ...valid(deps) };
+    delete dependencies.validity;
+    if (deps.environments) {
+      dependencies.environments = Object.fromEntries(
+        Object.entries(deps.environments).map(([name, list]) => [
+          name,
+          valid(list),
+        ]),
+      );
+    }
+    dated.dependencies = dependencies;
+  }
+  return { ...entity, owner, metadata: dated as StoredEntity['metadata'] };
+}
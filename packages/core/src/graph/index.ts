@@ -0,0 +1,109 @@
+export type {
+  DeclaredDependency,
+  DependencyKind,
+  DescriptionReference,
+  DerivedEdgeKind,
+  EdgeAnnotations,
+  EdgeFilter,
+  EdgeProvenance,
+  EdgeRule,
+  EdgeRuleEnd,
+  GraphNode,
+  GraphEdge,
+  DependencyGraph,
+  DependencyGraphOptions,
+  GraphNameOptions,
+  GraphRuleOptions,
+  NameTable,
+  PruneDecision,
+  PruneOptions,
+  PruneReason,
+  PruneResult,
+  RenameRecord,
+  DomainDependency,
+  DomainSummary,
+  DomainReport,
+  RegisteredService,
+  ServiceRegistry,
+  StitchOptions,
+  StitchResult,
+  StubResolution,
+  UnresolvedStub,
+  GraphConstraintRule,
+  GraphConstraintOptions,
+  ConflictLocation,
+  ConstraintConflict,
+  GraphChangeType,
+  GraphChangeEvent,
+  CycleMember,
+  DependencyCycle,
+  CycleBudgetOptions,
+  CycleStatus,
+  DomainCycleBudget,
+  CycleBudgetReport,
+  GraphPath,
+  PathOptions,
+  TraversalDirection,
+  TraversalOptions,
+  TraversalStep,
+  ImpactUnit,
+  ImpactMapping,
+  ImpactOptions,
+  ImpactSelection,
+} from './types.js';
+export {
+  buildDependencyGraph,
+  createReferenceResolver,
+  declaredDependencies,
+  entityAliases,
+  externalNode,
+  getEntityDomain,
+  goPackageNodeId,
+  isPreferredTarget,
+  namespaceSubmodules,
+  toEntityNode,
+} from './graph-builder.js';
+export {
+  checkCycleBudgets,
+  cycleMemberKey,
+  findDependencyCycles,
+} from './graph-cycles.js';
+export { buildDomainReport, UNASSIGNED_DOMAIN } from './domain-rollup.js';
+export { diffGraphs } from './graph-diff.js';
+export type { EnrichedDependency } from './graph-enriched.js';
+export {
+  ENRICHED_EDGE_CONFIDENCE,
+  createEntityReferenceIndex,
+  enrichedDependencies,
+} from './graph-enriched.js';
+export { createNameTable } from './graph-names.js';
+export { findDescriptionReferences } from './graph-references.js';
+export {
+  EDGE_PROVENANCES,
+  defaultEdgeProvenance,
+  edgeMatches,
+  filterGraphEdges,
+  parseEdgeProvenances,
+  withProvenance,
+} from './graph-provenance.js';
+export { graphSignificance, pruneGraph } from './graph-prune.js';
+export { BUILTIN_EDGE_KINDS, deriveEdges } from './graph-rules.js';
+export { stitchGraphs } from './graph-stitch.js';
+export { parseServiceRegistry } from './service-registry.js';
+export { checkGraphConstraints } from './graph-constraints.js';
+export { traverseGraph } from './graph-traversal.js';
+export { findGraphNodes, findShortestPaths } from './graph-paths.js';
+export {
+  dependencyEntryName,
+  dependencyEnvironments,
+  environmentDependencies,
+  selectEnvironment,
+} from './graph-environments.js';
+export { createServiceMatcher } from './graph-services.js';
+export {
+  isValidAt,
+  ownerAt,
+  parseAsOfDate,
+  selectAsOf,
+} from './graph-validity.js';
+export { selectImpactedUnits } from './graph-impact.js';
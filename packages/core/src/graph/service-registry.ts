@@ -0,0 +1,96 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads the service registry file mapping service names, aliases, and endpoints to the repositories and nodes that implement them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, stitch, registry, services, federation]
+ * context:
+ *   business_goal: Link dependencies on a service to its node even when the two repositories are written in different languages and name it differently
+ *   domain: graph
+ */
+import { parse as parseYaml } from 'yaml';
+import { z } from 'zod';
+import { createKnowgraphError } from '../errors/errors.js';
+import { NAMESPACE_PATTERN } from '../namespace/namespace.js';
+import type { RegisteredService, ServiceRegistry } from './types.js';
+
+const RegisteredServiceSchema = z.object({
+  repo: z
+    .string()
+    .regex(NAMESPACE_PATTERN, 'repo must be a namespace such as acme/users')
+    .optional(),
+  node: z.string().min(1).optional(),
+  aliases: z.array(z.string().min(1)).default([]),
+  endpoints: z.array(z.string().min(1)).default([]),
+});
+
+const ServiceRegistrySchema = z.object({
+  services: z.record(z.string().min(1), RegisteredServiceSchema),
+});
+
+/**
+ * Parse a service registry, YAML or JSON, listing each service under its
+ * canonical name:
+ *
+ *     services:
+ *       user-service:
+ *         repo: acme/users
+ *         node: UserService
+ *         aliases: [users]
+ *         endpoints: [https://users.acme.internal]
+ */
+export function parseServiceRegistry(
+  input: string,
+  source = 'service registry',
+): ServiceRegistry {
+  let body: unknown;
+  try {
+    body = parseYaml(input);
+  } catch (err) {
+    throw createKnowgraphError(
+      'parse',
+      `${source} is not valid YAML: ${(err as Error).message}`,
+    );
+  }
+  const parsed = ServiceRegistrySchema.safeParse(body);
+  if (!parsed.success) {
+    const issue = parsed.error.issues[0];
+    throw createKnowgraphError(
+      'schema',
+      `${source}: ${issue.path.join('.') || 'services'}: ${issue.message}`,
+    );
+  }
+  const services: RegisteredService[] = Object.entries(
+    parsed.data.services,
+  ).map(([name, entry]) => ({ name, ...entry }));
+  return { services };
+}
+
+/**
+ * How a stub or registry name is compared: without case, and for an
+ * endpoint without its scheme or trailing slash, so a dependency on
+ * `users.acme.internal` finds `https://users.acme.internal/`.
+ */
+export function registryKey(name: string): string {
+  return name
+    .trim()
+    .toLowerCase()
+    .replace(/^[a-z][a-z0-9+.-]*:\/\//, '')
+    .replace(/\/+$/, '');
+}
+
+/** The registered service each name, alias, and endpoint refers to. */
+export function indexServiceRegistry(
+  registry: ServiceRegistry | undefined,
+): ReadonlyMap<string, RegisteredService> {
+  const index = new Map<string, RegisteredService>();
+  for (const service of registry?.services ?? []) {
+    const names = [service.name, ...service.aliases, ...service.endpoints];
+    for (const name of names) {
+      const key = registryKey(name);
+      if (!index.has(key)) index.set(key, service);
+    }
+  }
+  return index;
+}
@@ -0,0 +1,513 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the in-memory dependency graph built from indexed entities
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [graph, dependencies, types, interface]
+ * context:
+ *   business_goal: Give reports a shared node/edge model of how code depends on other code
+ *   domain: graph
+ */
+import type { DependencyCriticality, EntityType } from '../types/entity.js';
+import type { GoPackage } from '../golang/types.js';
+import type { GitSubmodule } from '../indexer/types.js';
+import type { WorkspaceMember } from '../workspace/types.js';
+
+// `build` edges come from build tooling (bazel query) and `import` edges from
+// source imports, rather than annotations; `reference` edges from `[[name]]`
+// references in descriptions. Edge rules add edges of the kinds they name
+export type DependencyKind =
+  | 'service'
+  | 'external_api'
+  | 'database'
+  | 'build'
+  | 'import'
+  | 'reference'
+  | DerivedEdgeKind;
+
+/** A kind of edge an edge rule adds, such as `shares_datastore`. */
+export type DerivedEdgeKind = string & {};
+
+/**
+ * How an edge was derived: `declared` in annotations, from source
+ * `import` analysis, a `call` graph, `router` detection, `build` tooling,
+ * added by hand (`manual`), or `derived` by an edge rule.
+ */
+export type EdgeProvenance =
+  | 'declared'
+  | 'import'
+  | 'call'
+  | 'router'
+  | 'build'
+  | 'manual'
+  | 'derived';
+
+export interface GraphNode {
+  readonly id: string;
+  readonly name: string;
+  readonly entityType: EntityType | null;
+  readonly external: boolean;
+  readonly filePath: string | null;
+  readonly owner: string | null;
+  readonly domain: string | null;
+  /** Workspace member (build unit) containing the node's file, if known. */
+  readonly workspace: string | null;
+  /** Other names the entity answers to, from its `aliases` annotation. */
+  readonly aliases?: readonly string[];
+  /**
+   * Set only on structural nodes derived from source (Go packages): false
+   * when no annotated entity lives in the package.
+   */
+  readonly annotated?: boolean;
+  /**
+   * Set in scoped graphs (see `scopeGraph`): true on an out-of-scope node
+   * kept because an edge joins it to the scope.
+   */
+  readonly stub?: boolean;
+  /**
+   * The `org/repo` namespace the node belongs to, set when its graph was
+   * built with one; its id then carries the namespace as a prefix.
+   */
+  readonly namespace?: string;
+  /** The API version the entity declares it provides. */
+  readonly version?: string;
+}
+
+/** A service or entity that used to go by another name. */
+export interface RenameRecord {
+  readonly from: string;
+  readonly to: string;
+}
+
+/** Names dependencies may use besides the current entity names. */
+export interface GraphNameOptions {
+  /** Other names for each service or entity, keyed by its current name. */
+  readonly aliases?: Readonly<Record<string, readonly string[]>>;
+  /** Past renames; chains such as a to b to c resolve to the last name. */
+  readonly renames?: readonly RenameRecord[];
+}
+
+/** The nodes at one end of the edges a rule adds, matching every field set. */
+export interface EdgeRuleEnd {
+  readonly type?: EntityType;
+  readonly owner?: string;
+  readonly domain?: string;
+}
+
+/**
+ * Adds `kind` edges with `derived` provenance, from nodes matching `from`
+ * to nodes matching `to`. A `shared` rule joins entities with a value in
+ * common at that annotation field, such as `dependencies.databases`; a
+ * `path` rule joins the two ends of each chain of edges of those kinds.
+ */
+export interface EdgeRule {
+  readonly kind: DerivedEdgeKind;
+  readonly shared?: string;
+  readonly path?: readonly DependencyKind[];
+  readonly from?: EdgeRuleEnd;
+  readonly to?: EdgeRuleEnd;
+  /** Confidence of the edges the rule adds (default: 1). */
+  readonly confidence?: number;
+}
+
+/** Adds the edges of org-specific rules once the graph is built. */
+export interface GraphRuleOptions {
+  /**
+   * Rules evaluated against the built edges, so no rule sees the edges
+   * another derives (see `deriveEdges`).
+   */
+  readonly edgeRules?: readonly EdgeRule[];
+}
+
+export interface DependencyGraphOptions
+  extends GraphNameOptions,
+    GraphRuleOptions {
+  /** Members used to assign each entity to its build unit. */
+  readonly workspaces?: readonly WorkspaceMember[];
+  /** Go packages to add as nodes, with their intra-repo imports as edges. */
+  readonly goPackages?: readonly GoPackage[];
+  /** Prefix node ids with this `org/repo` namespace (see `namespaceGraph`). */
+  readonly namespace?: string;
+  /**
+   * Put the nodes of files inside these submodules in the submodule's own
+   * namespace instead (see `namespaceSubmodules`).
+   */
+  readonly submodules?: readonly GitSubmodule[];
+  /**
+   * Add a `reference` edge for each `[[name]]` in a description that
+   * names an indexed entity (see `createReferenceResolver`).
+   */
+  readonly references?: boolean;
+  /**
+   * Only the dependencies that apply in this environment (see
+   * `environmentDependencies`); by default those of every environment.
+   */
+  readonly environment?: string;
+  /**
+   * Build the graph as it stood on this `YYYY-MM-DD` date, with the owners
+   * and dependencies then (see `selectAsOf`); by default every dependency
+   * and the undated owner.
+   */
+  readonly asOf?: string;
+}
+
+/** Resolves aliases and old names to the names in use now. */
+export interface NameTable {
+  /** The current name `name` refers to, or `name` itself. */
+  canonical(name: string): string;
+  /** The aliases and old names that refer to `name`. */
+  aliasesOf(name: string): readonly string[];
+}
+
+export type GraphChangeType =
+  | 'node_added'
+  | 'node_updated'
+  | 'node_renamed'
+  | 'node_removed';
+
+export interface GraphChangeEvent {
+  readonly type: GraphChangeType;
+  /** The node after the change, or as it was before removal. */
+  readonly node: GraphNode;
+  /** For `node_renamed`, the node as it was under its old name. */
+  readonly previous?: GraphNode;
+}
+
+export type TraversalDirection = 'outgoing' | 'incoming' | 'both';
+
+/**
+ * Selects edges by how they were derived and how sure that is, and by
+ * what their dependency entries annotate them with.
+ */
+export interface EdgeFilter {
+  /** Only edges with one of these provenances. */
+  readonly provenance?: readonly EdgeProvenance[];
+  /** Only edges at least this confident, from 0 to 1. */
+  readonly minConfidence?: number;
+  /** Only edges annotated with one of these protocols. */
+  readonly protocols?: readonly string[];
+  /** Only edges annotated with one of these criticalities. */
+  readonly criticalities?: readonly DependencyCriticality[];
+}
+
+export interface TraversalOptions extends EdgeFilter {
+  /** `outgoing` follows dependencies, `incoming` finds dependents. */
+  readonly direction?: TraversalDirection;
+  /** Stop after this many hops (default: unlimited). */
+  readonly maxDepth?: number;
+  /** Only follow edges of these kinds. */
+  readonly kinds?: readonly DependencyKind[];
+}
+
+/** Options for `findShortestPaths`. */
+export interface PathOptions extends EdgeFilter {
+  /** How many paths to find, shortest first (default: 3). */
+  readonly k?: number;
+  /** Only follow edges of these kinds. */
+  readonly kinds?: readonly DependencyKind[];
+}
+
+/** A chain of dependencies from one node to another. */
+export interface GraphPath {
+  /** The nodes in order, from the start to the end. */
+  readonly nodes: readonly GraphNode[];
+  /** The edge between each pair of consecutive nodes. */
+  readonly edges: readonly GraphEdge[];
+}
+
+export interface TraversalStep {
+  readonly node: GraphNode;
+  readonly depth: number;
+  readonly edge: GraphEdge;
+}
+
+/** A `[[target]]` in a description, with its offsets in the text. */
+export interface DescriptionReference {
+  readonly target: string;
+  readonly start: number;
+  readonly end: number;
+}
+
+/** What a dependency entry says about its edge, when it is an object. */
+export interface EdgeAnnotations {
+  /** What the dependent uses the target for, such as `JWT signing`. */
+  readonly purpose?: string;
+  /** How the dependent talks to the target, such as `grpc`. */
+  readonly protocol?: string;
+  /** How much the dependent suffers when the target is down. */
+  readonly criticality?: DependencyCriticality;
+}
+
+/** A dependency named in an entity's `dependencies` metadata. */
+export interface DeclaredDependency extends EdgeAnnotations {
+  readonly kind: DependencyKind;
+  readonly name: string;
+  /** The version range required of it, from `dependencies.versions`. */
+  readonly requires?: string;
+}
+
+export interface GraphEdge extends EdgeAnnotations {
+  readonly from: string;
+  readonly to: string;
+  readonly kind: DependencyKind;
+  readonly provenance: EdgeProvenance;
+  /** From 0 to 1; edges read straight from annotations or source are 1. */
+  readonly confidence: number;
+  /** The version range the dependent requires of the target, if any. */
+  readonly requires?: string;
+}
+
+export interface DependencyGraph {
+  readonly nodes: readonly GraphNode[];
+  readonly edges: readonly GraphEdge[];
+}
+
+/** An external stub that stitching replaced with a real node. */
+export interface StubResolution {
+  readonly stub: string;
+  readonly target: string;
+  /**
+   * Whether the stub named the target or one of its `aliases`, or the
+   * service registry pointed it there.
+   */
+  readonly match: 'name' | 'alias' | 'registry';
+}
+
+/** An external stub no stitched graph provides a node for. */
+export interface UnresolvedStub {
+  readonly node: GraphNode;
+  /** Kinds of the edges that point at it, such as `service`. */
+  readonly kinds: readonly DependencyKind[];
+  /** Ids of the nodes that depend on it. */
+  readonly dependents: readonly string[];
+  /** A node or registered name the stub's name is a near miss of. */
+  readonly suggestion?: string;
+}
+
+/**
+ * How `pruneGraph` cuts a graph down to size, applied in this order. A
+ * node's significance is the number of edges it has, in either direction.
+ */
+export interface PruneOptions {
+  /** Fold function nodes nothing depends on into the module in their file. */
+  readonly collapseFunctions?: boolean;
+  /** Drop nodes with a significance below this. */
+  readonly minSignificance?: number;
+  /** Then drop the least significant nodes until at most this many remain. */
+  readonly maxNodes?: number;
+}
+
+/**
+ * Why a node was pruned: a `leaf-function` folded into its module, a node
+ * `below-significance`, one dropped to stay within `maxNodes`
+ * (`over-budget`), or an external stub `orphaned` by the other removals.
+ */
+export type PruneReason =
+  | 'leaf-function'
+  | 'below-significance'
+  | 'over-budget'
+  | 'orphaned';
+
+/** A node left out of a pruned graph, so nothing disappears unexplained. */
+export interface PruneDecision {
+  readonly node: string;
+  readonly name: string;
+  readonly reason: PruneReason;
+  /** The node's significance when it was pruned. */
+  readonly significance: number;
+  /** For `leaf-function`, the module node that took over its edges. */
+  readonly into?: string;
+}
+
+export interface PruneResult {
+  readonly graph: DependencyGraph;
+  readonly decisions: readonly PruneDecision[];
+}
+
+/**
+ * A rule the merged graph must keep: `unique-name` when real nodes of
+ * one type share a name, `single-owner` when a node has no owner or its
+ * copies disagree on one.
+ */
+export type GraphConstraintRule = 'unique-name' | 'single-owner';
+
+/** What the merged graph is checked for; see `checkGraphConstraints`. */
+export interface GraphConstraintOptions {
+  /** Entity types whose names must be unique. Defaults to `['service']`. */
+  readonly uniqueNames?: readonly EntityType[];
+  /** Report nodes with no owner, not only those with several. */
+  readonly requireOwner?: boolean;
+}
+
+/** One copy of a node involved in a conflict, and where it came from. */
+export interface ConflictLocation {
+  /** Index of the graph the copy is in, in the order given. */
+  readonly graph: number;
+  readonly node: string;
+  readonly namespace: string | null;
+  readonly filePath: string | null;
+  readonly owner: string | null;
+}
+
+export interface ConstraintConflict {
+  readonly rule: GraphConstraintRule;
+  readonly message: string;
+  readonly locations: readonly ConflictLocation[];
+}
+
+/**
+ * A service as the service registry lists it: the canonical name other
+ * repositories depend on it by, and where its node is.
+ */
+export interface RegisteredService {
+  readonly name: string;
+  /** The `org/repo` namespace of the repository holding its node. */
+  readonly repo?: string;
+  /** The name of its node there, when it differs from `name`. */
+  readonly node?: string;
+  /** Other names dependents use for it. */
+  readonly aliases: readonly string[];
+  /** URLs or host names it is reached at, which dependents may name. */
+  readonly endpoints: readonly string[];
+}
+
+/** Where each service lives, whatever language its repository is in. */
+export interface ServiceRegistry {
+  readonly services: readonly RegisteredService[];
+}
+
+export interface StitchOptions {
+  readonly constraints?: GraphConstraintOptions;
+  /** Consulted before names and aliases; see `stitchGraphs`. */
+  readonly registry?: ServiceRegistry;
+}
+
+export interface StitchResult {
+  readonly graph: DependencyGraph;
+  readonly resolved: readonly StubResolution[];
+  readonly unresolved: readonly UnresolvedStub[];
+  /** Constraints the merged graph breaks; see `checkGraphConstraints`. */
+  readonly conflicts: readonly ConstraintConflict[];
+}
+
+export interface DomainDependency {
+  readonly from: string;
+  readonly to: string;
+  readonly count: number;
+  readonly examples: readonly string[];
+}
+
+export interface DomainSummary {
+  readonly domain: string;
+  readonly entityCount: number;
+  readonly owners: readonly string[];
+  readonly internalEdges: number;
+  readonly afferent: number;
+  readonly efferent: number;
+  readonly instability: number | null;
+}
+
+export interface DomainReport {
+  readonly domains: readonly DomainSummary[];
+  readonly dependencies: readonly DomainDependency[];
+}
+
+/** A node on a dependency cycle. */
+export interface CycleMember {
+  readonly id: string;
+  readonly name: string;
+  readonly filePath: string | null;
+  readonly domain: string | null;
+}
+
+/**
+ * A strongly connected group of nodes: every member reaches every other,
+ * so each edge inside the group lies on at least one loop.
+ */
+export interface DependencyCycle {
+  /** Ordered by `cycleMemberKey`. */
+  readonly members: readonly CycleMember[];
+  /** Distinct dependencies between members. */
+  readonly edges: number;
+  /**
+   * Edges whose removal leaves the group without loops. No edge can be
+   * left out of the set, though a smaller set may exist.
+   */
+  readonly breakingEdges: readonly GraphEdge[];
+}
+
+export interface CycleBudgetOptions {
+  /** New cycles each domain may have. */
+  readonly budgets?: Readonly<Record<string, number>>;
+  /** For domains `budgets` leaves out. Default 0. */
+  readonly defaultBudget?: number;
+  /**
+   * Member keys of cycles that already existed. A cycle whose members are
+   * all in one of them is not new, so shrinking a cycle never fails.
+   */
+  readonly baseline?: readonly (readonly string[])[];
+}
+
+export interface CycleStatus {
+  readonly cycle: DependencyCycle;
+  /** The members' domains, `(no domain)` for members without one. */
+  readonly domains: readonly string[];
+  readonly baselined: boolean;
+  /** New, in a domain with more new cycles than its budget. */
+  readonly overBudget: boolean;
+}
+
+export interface DomainCycleBudget {
+  readonly domain: string;
+  readonly budget: number;
+  readonly newCycles: number;
+}
+
+export interface CycleBudgetReport {
+  readonly cycles: readonly CycleStatus[];
+  /** Domains on any cycle, by name. */
+  readonly domains: readonly DomainCycleBudget[];
+  readonly overBudget: number;
+}
+
+/** A build or test unit that owns files, such as a Go or Bazel package. */
+export interface ImpactUnit {
+  /** Node id of the unit; need not be in the graph. */
+  readonly id: string;
+}
+
+export interface ImpactMapping<U extends ImpactUnit> {
+  /** The unit a changed file belongs to. */
+  unitOfFile(file: string): U | undefined;
+  /** The unit an entity node's file belongs to. */
+  unitOfNode(node: GraphNode): U | undefined;
+}
+
+export interface ImpactOptions {
+  /**
+   * Share of changed files (other than Markdown) that must map to a unit or
+   * an entity for the selection to be trusted. Defaults to 0.8.
+   */
+  readonly minCoverage?: number;
+  /**
+   * Files whose change affects every unit: base names like `go.mod`, or
+   * `*.ext` for every file with that extension.
+   */
+  readonly globalFiles?: readonly string[];
+}
+
+export interface ImpactSelection<U extends ImpactUnit> {
+  /** Every unit is selected. */
+  readonly full: boolean;
+  /** Why every unit is selected; null when the selection is narrowed. */
+  readonly reason: string | null;
+  /** In the order given; every unit when `full`. */
+  readonly units: readonly U[];
+  /** Units a changed file belongs to. */
+  readonly changed: readonly U[];
+  /** Changed files that map to no unit or entity. */
+  readonly unmapped: readonly string[];
+  /** Share of considered changed files that mapped, from 0 to 1. */
+  readonly coverage: number;
+}
@@ -0,0 +1,206 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createHmac } from 'node:crypto';
+import { createDeliveryClient } from '../../delivery/delivery.js';
+import {
+  createFileAlertSink,
+  createSlackAlertSink,
+  createTeamsAlertSink,
+  createWebhookAlertSink,
+  formatAlertText,
+} from '../alert-sinks.js';
+import type { AlertMessage, AnomalyReport, ScanMetrics } from '../types.js';
+
+const scan: ScanMetrics = {
+  timestamp: '2024-05-01T00:00:00.000Z',
+  files: 10,
+  entities: 2,
+  edges: 4,
+  coverage: 80,
+  byEntity: {
+    a: { name: 'a', owner: null, status: 'stable', dependencies: 4 },
+  },
+};
+
+const report: AnomalyReport = {
+  previous: scan,
+  current: scan,
+  anomalies: [
+    {
+      kind: 'status_downgrade',
+      message: '7 entities moved to a lower status',
+      value: 7,
+      threshold: 5,
+      entities: ['a', 'b', 'c', 'd', 'e', 'f', 'g'],
+    },
+  ],
+};
+
+function mockFetch(ok = true): ReturnType<typeof vi.fn> {
+  const fn = vi.fn(async () => ({
+    ok,
+    status: ok ? 200 : 500,
+    statusText: ok ? 'OK' : 'Internal Server Error',
+  }));
+  vi.stubGlobal('fetch', fn);
+  return fn;
+}
+
+function sentBody(fn: ReturnType<typeof vi.fn>): Record<string, unknown> {
+  const [, init] = fn.mock.calls[0] as unknown as [string, RequestInit];
+  return JSON.parse(init.body as string) as Record<string, unknown>;
+}
+
+describe('alert sinks', () => {
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('summarizes anomalies as text', () => {
+    expect(formatAlertText(report)).toBe(
+      [
+        'knowgraph: 1 graph anomalies in the scan at 2024-05-01T00:00:00.000Z',
+        '- 7 entities moved to a lower status: a, b, c, d, e and 2 more',
+      ].join('\n'),
+    );
+  });
+
+  it('posts the report to a webhook without per-entity metrics', async () => {
+    const fn = mockFetch();
+    await createWebhookAlertSink('https://hooks.example.com/kg').send(report);
+    const body = sentBody(fn);
+    expect(body.event).toBe('knowgraph.anomalies');
+    expect(body.current).toEqual({
+      timestamp: '2024-05-01T00:00:00.000Z',
+      files: 10,
+      entities: 2,
+      edges: 4,
+      coverage: 80,
+    });
+  });
+
+  it('renders a template and signs the body', async () => {
+    const fn = mockFetch();
+    const template = [
+      '{"summary": {{ json .text }},',
+      ' "kinds": [{{ range $i, $a := .anomalies }}{{ if $i }},{{ end }}',
+      '{{ json $a.kind }}{{ end }}]}',
+    ].join('');
+    await createWebhookAlertSink(
+      'https://hooks.example.com/kg',
+      createDeliveryClient(),
+      { template, secret: 'shh' },
+    ).send(report);
+    const [, init] = fn.mock.calls[0] as unknown as [string, RequestInit];
+    const body = init.body as string;
+    expect(JSON.parse(body)).toEqual({
+      summary: formatAlertText(report),
+      kinds: ['status_downgrade'],
+    });
+    const digest = createHmac('sha256', 'shh').update(body).digest('hex');
+    expect(init.headers).toMatchObject({
+      'X-Knowgraph-Event': 'knowgraph.anomalies',
+      'X-Knowgraph-Signature-256': `sha256=${digest}`,
+    });
+  });
+
+  it('refuses a template that does not render JSON', async () => {
+    const fn = mockFetch();
+    const sink = createWebhookAlertSink(
+      'https://hooks.example.com/kg',
+      createDeliveryClient(),
+      { template: '{"summary": {{ .text }}}', templateName: 'hook.tmpl' },
+    );
+    await expect(sink.send(report)).rejects.toMatchObject({
+      kind: 'usage',
+      message: expect.stringContaining('hook.tmpl did not render JSON'),
+    });
+    expect(fn).not.toHaveBeenCalled();
+  });
+
+  it('posts the text summary to Slack', async () => {
+    const fn = mockFetch();
+    await createSlackAlertSink('https://hooks.slack.com/x').send(report);
+    expect(sentBody(fn)).toEqual({ text: formatAlertText(report) });
+  });
+
+  it('posts the text summary to Teams as an Adaptive Card', async () => {
+    const fn = mockFetch();
+    await createTeamsAlertSink('https://example.webhook.office.com/x').send(
+      report,
+    );
+    const body = sentBody(fn) as {
+      attachments: Array<{ content: { body: unknown[] } }>;
+    };
+    expect(body.attachments[0].content.body).toEqual([
+      {
+        type: 'TextBlock',
+        text: 'knowgraph: 1 graph anomalies in the scan at 2024-05-01T00:00:00.000Z',
+        weight: 'Bolder',
+        wrap: true,
+      },
+      {
+        type: 'TextBlock',
+        text: '- 7 entities moved to a lower status: a, b, c, d, e and 2 more',
+        wrap: true,
+      },
+    ]);
+  });
+
+  it('throws an I/O error when the webhook fails', async () => {
+    const fn = mockFetch(false);
+    const client = createDeliveryClient({ sleep: async () => {} });
+    await expect(
+      createWebhookAlertSink('https://hooks.example.com/kg', client).send(
+        report,
+      ),
+    ).rejects.toMatchObject({ kind: 'io' });
+    expect(fn).toHaveBeenCalledTimes(3);
+  });
+
+  it('appends the report to a file', async () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-alerts-'));
+    try {
+      const path = join(dir, 'alerts', 'anomalies.jsonl');
+      await createFileAlertSink(path).send(report);
+      const [line] = readFileSync(path, 'utf-8').trim().split('\n');
+      expect(JSON.parse(line).anomalies).toHaveLength(1);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  it('delivers messages other than reports', async () => {
+    const message: AlertMessage = {
+      event: 'knowgraph.pipeline',
+      subject: 'knowgraph: pipeline nightly failed at check',
+      lines: ['- scan: ok', '- check: failed (exit 1)'],
+      data: { pipeline: 'nightly', ok: false },
+    };
+    const fn = mockFetch();
+    await createSlackAlertSink('https://hooks.slack.com/x').notify(message);
+    expect(sentBody(fn)).toEqual({
+      text: [
+        'knowgraph: pipeline nightly failed at check',
+        '- scan: ok',
+        '- check: failed (exit 1)',
+      ].join('\n'),
+    });
+
+    fn.mockClear();
+    await createWebhookAlertSink('https://hooks.example.com/kg').notify(
+      message,
+    );
+    const [, init] = fn.mock.calls[0] as unknown as [string, RequestInit];
+    expect(init.headers).toMatchObject({
+      'X-Knowgraph-Event': 'knowgraph.pipeline',
+    });
+    expect(sentBody(fn)).toEqual({
+      event: 'knowgraph.pipeline',
+      pipeline: 'nightly',
+      ok: false,
+    });
+  });
+});
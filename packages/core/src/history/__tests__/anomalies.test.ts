@@ -0,0 +1,131 @@
+import { describe, it, expect } from 'vitest';
+import { detectAnomalies, detectHistoryAnomalies } from '../anomalies.js';
+import type { EntityMetrics, ScanMetrics } from '../types.js';
+
+function scan(
+  byEntity: Record<string, Partial<EntityMetrics>>,
+  overrides: Partial<ScanMetrics> = {},
+): ScanMetrics {
+  const entities = Object.fromEntries(
+    Object.entries(byEntity).map(([id, entity]) => [
+      id,
+      {
+        name: id,
+        owner: 'payments-team',
+        status: 'stable',
+        dependencies: 1,
+        ...entity,
+      },
+    ]),
+  );
+  const edges = Object.values(entities).reduce(
+    (sum, entity) => sum + entity.dependencies,
+    0,
+  );
+  return {
+    timestamp: '2024-05-01T00:00:00.000Z',
+    files: 10,
+    entities: Object.keys(entities).length,
+    edges,
+    coverage: 80,
+    byEntity: entities,
+    ...overrides,
+  };
+}
+
+const ids = ['a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j'];
+const baseline = scan(Object.fromEntries(ids.map((id) => [id, {}])));
+
+function changed(
+  changes: Record<string, Partial<EntityMetrics>>,
+  overrides: Partial<ScanMetrics> = {},
+): ScanMetrics {
+  return scan(
+    Object.fromEntries(ids.map((id) => [id, changes[id] ?? {}])),
+    overrides,
+  );
+}
+
+describe('detectAnomalies', () => {
+  it('reports nothing for a quiet scan', () => {
+    const report = detectAnomalies(
+      baseline,
+      changed({ a: { dependencies: 2 } }),
+    );
+    expect(report.anomalies).toEqual([]);
+  });
+
+  it('flags a dependency spike with the entities that grew', () => {
+    const report = detectAnomalies(
+      baseline,
+      changed({ a: { dependencies: 5 }, b: { dependencies: 3 } }),
+    );
+    expect(report.anomalies).toEqual([
+      {
+        kind: 'dependency_spike',
+        message: 'Dependency edges grew 60% (10 → 16)',
+        value: 60,
+        threshold: 50,
+        entities: ['a', 'b'],
+      },
+    ]);
+  });
+
+  it('flags ownership churn across entities in both scans', () => {
+    const report = detectAnomalies(
+      baseline,
+      changed({ c: { owner: 'ledger-team' }, d: { owner: null } }),
+    );
+    expect(report.anomalies).toEqual([
+      expect.objectContaining({
+        kind: 'ownership_churn',
+        message: 'Owner changed for 20% of entities (2 of 10)',
+        entities: ['c', 'd'],
+      }),
+    ]);
+  });
+
+  it('flags mass status downgrades but not upgrades', () => {
+    const downgraded = changed({
+      a: { status: 'deprecated' },
+      b: { status: 'experimental' },
+      c: { status: 'deprecated' },
+    });
+    expect(
+      detectAnomalies(baseline, downgraded, { statusDowngrades: 3 }).anomalies,
+    ).toEqual([
+      expect.objectContaining({ kind: 'status_downgrade', value: 3 }),
+    ]);
+    expect(
+      detectAnomalies(downgraded, baseline, { statusDowngrades: 1 }).anomalies,
+    ).toEqual([]);
+  });
+
+  it('flags a large drop in coverage', () => {
+    const report = detectAnomalies(baseline, changed({}, { coverage: 72.5 }));
+    expect(report.anomalies).toEqual([
+      expect.objectContaining({
+        kind: 'coverage_drop',
+        message: 'Coverage dropped 7.5 points (80% → 72.5%)',
+      }),
+    ]);
+  });
+
+  it('honours configured thresholds', () => {
+    const report = detectAnomalies(baseline, changed({}, { coverage: 72.5 }), {
+      coverageDropPoints: 10,
+    });
+    expect(report.anomalies).toEqual([]);
+  });
+});
+
+describe('detectHistoryAnomalies', () => {
+  it('compares each scan with the one before it', () => {
+    const reports = detectHistoryAnomalies([
+      baseline,
+      changed({}, { coverage: 60 }),
+      changed({}, { coverage: 60 }),
+    ]);
+    expect(reports.map((r) => r.anomalies.length)).toEqual([1, 0]);
+  });
+});
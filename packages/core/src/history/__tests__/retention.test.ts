@@ -0,0 +1,110 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { ScanMetrics } from '../types.js';
+import { compactScanHistory, writeScanHistory } from '../retention.js';
+import { readScanHistory } from '../scan-history.js';
+
+const NOW = new Date('2026-06-30T12:00:00Z');
+
+function scan(timestamp: string, entities = 4): ScanMetrics {
+  return {
+    timestamp,
+    files: 10,
+    entities,
+    edges: 6,
+    coverage: 80,
+    byEntity: {
+      'id-checkout': {
+        name: 'checkout',
+        owner: 'payments-team',
+        status: 'stable',
+        dependencies: 2,
+      },
+    },
+  };
+}
+
+describe('compactScanHistory', () => {
+  const history = [
+    scan('2024-03-02T08:00:00.000Z', 1),
+    scan('2025-01-05T08:00:00.000Z', 2),
+    scan('2025-01-20T08:00:00.000Z', 3),
+    // Monday and Wednesday of the same week, then the next Monday
+    scan('2026-03-02T08:00:00.000Z', 4),
+    scan('2026-03-04T08:00:00.000Z', 5),
+    scan('2026-03-09T08:00:00.000Z', 6),
+    scan('2026-06-20T08:00:00.000Z', 7),
+    scan('2026-06-20T18:00:00.000Z', 8),
+    scan('2026-06-30T09:00:00.000Z', 9),
+  ];
+
+  it('keeps daily, then weekly scans, then monthly aggregates', () => {
+    const result = compactScanHistory(history, undefined, NOW);
+    expect(
+      result.history.map((entry) => [entry.entities, entry.scans]),
+    ).toEqual([
+      [1, 1],
+      [3, 2],
+      [5, undefined],
+      [6, undefined],
+      [8, undefined],
+      [9, undefined],
+    ]);
+    expect(result).toMatchObject({ kept: 4, aggregates: 2, pruned: 3 });
+    expect(result.history[1].byEntity).toEqual({});
+    expect(Object.keys(result.history[2].byEntity)).toEqual(['id-checkout']);
+  });
+
+  it('folds aggregates again and always keeps the latest scan', () => {
+    const once = compactScanHistory(history, undefined, NOW).history;
+    const result = compactScanHistory(
+      [...once, scan('2025-01-31T08:00:00.000Z', 10)].sort((a, b) =>
+        a.timestamp.localeCompare(b.timestamp),
+      ),
+      { dailyDays: 0, weeklyDays: 0 },
+      NOW,
+    );
+    expect(
+      result.history.map((entry) => [entry.entities, entry.scans]),
+    ).toEqual([
+      [1, 1],
+      [10, 3],
+      [6, 2],
+      [8, 1],
+      [9, undefined],
+    ]);
+    expect(result.history.at(-1)?.byEntity).not.toEqual({});
+  });
+
+  it('prunes nothing from a recent history', () => {
+    const recent = [scan('2026-06-29T08:00:00.000Z'), scan(NOW.toISOString())];
+    expect(compactScanHistory(recent, undefined, NOW)).toEqual({
+      history: recent,
+      kept: 2,
+      aggregates: 0,
+      pruned: 0,
+    });
+  });
+});
+
+describe('writeScanHistory', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-retention-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('replaces the history without leaving a temporary file', () => {
+    const path = join(dir, 'nested', 'history.jsonl');
+    const scans = [scan('2026-06-29T08:00:00.000Z')];
+    writeScanHistory(path, scans);
+    expect(readScanHistory(path)).toEqual(scans);
+    expect(existsSync(`${path}.tmp`)).toBe(false);
+  });
+});
@@ -0,0 +1,132 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  appendScanMetrics,
+  collectScanMetrics,
+  followRenames,
+  readScanHistory,
+} from '../scan-history.js';
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  services: string[] = [],
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType: 'service',
+    description: `${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `${name} service`,
+      dependencies: { services },
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+describe('collectScanMetrics', () => {
+  it('counts entities, edges, coverage, and per-entity facts', () => {
+    const entities = [
+      makeEntity('checkout', 'src/checkout.ts', ['ledger', 'stripe']),
+      makeEntity('ledger', 'src/ledger.ts'),
+      makeEntity('refunds', 'src/ledger.ts', ['ledger']),
+    ];
+    const metrics = collectScanMetrics(
+      entities,
+      buildDependencyGraph(entities),
+      3,
+      new Date('2024-05-01T00:00:00Z'),
+    );
+    expect(metrics).toMatchObject({
+      timestamp: '2024-05-01T00:00:00.000Z',
+      files: 3,
+      entities: 3,
+      edges: 3,
+      coverage: 66.7,
+    });
+    expect(metrics.byEntity['id-checkout']).toEqual({
+      name: 'checkout',
+      owner: 'payments-team',
+      status: 'stable',
+      dependencies: 2,
+    });
+  });
+
+  it('reports zero coverage when no files were scanned', () => {
+    const metrics = collectScanMetrics([], { nodes: [], edges: [] }, 0);
+    expect(metrics.coverage).toBe(0);
+  });
+});
+
+describe('followRenames', () => {
+  it('keys entities by the ids they were renamed to', () => {
+    const entities = [
+      makeEntity('checkout', 'src/checkout.ts'),
+      makeEntity('ledger', 'src/ledger.ts'),
+    ];
+    const metrics = collectScanMetrics(
+      entities,
+      buildDependencyGraph(entities),
+      2,
+    );
+    const renamed = followRenames(metrics, (id) =>
+      id === 'id-checkout' ? 'id-cart' : id,
+    );
+    expect(Object.keys(renamed.byEntity)).toEqual(['id-cart', 'id-ledger']);
+    expect(renamed.byEntity['id-cart']).toBe(metrics.byEntity['id-checkout']);
+  });
+});
+
+describe('scan history', () => {
+  let dir: string;
+  let historyPath: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-history-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(dir, { recursive: true });
+    historyPath = join(dir, '.knowgraph', 'history.jsonl');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('appends scans and reads them back in order', () => {
+    expect(readScanHistory(historyPath)).toEqual([]);
+    const first = collectScanMetrics([], { nodes: [], edges: [] }, 1);
+    const second = { ...first, files: 2 };
+    appendScanMetrics(historyPath, first);
+    appendScanMetrics(historyPath, second);
+    expect(readScanHistory(historyPath)).toEqual([first, second]);
+  });
+
+  it('reports the line of an invalid entry', () => {
+    mkdirSync(join(dir, '.knowgraph'));
+    writeFileSync(historyPath, '{}\nnot json\n');
+    expect(() => readScanHistory(historyPath)).toThrow(
+      `Invalid scan history entry at ${historyPath}:2`,
+    );
+  });
+});
@@ -0,0 +1,255 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Delivers anomaly reports and other alerts, such as pipeline outcomes, to templated and signed webhooks, Slack and Teams incoming webhooks, email, and JSON Lines files
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [history, anomalies, alerts, webhook, slack, teams, email]
+ * context:
+ *   business_goal: Tell the owning team about graph anomalies where they already look
+ *   domain: history
+ */
+import { createHmac } from 'node:crypto';
+import { appendFileSync, mkdirSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { createDeliveryClient } from '../delivery/delivery.js';
+import { sendMail } from '../delivery/smtp.js';
+import type {
+  DeliveryClient,
+  DeliveryOutcome,
+  SmtpOptions,
+} from '../delivery/types.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { REPORT_FUNCTIONS } from '../report/functions.js';
+import { parseTemplate } from '../report/template.js';
+import type {
+  AlertMessage,
+  AlertSink,
+  AnomalyReport,
+  ScanMetrics,
+  WebhookAlertOptions,
+} from './types.js';
+
+const LISTED_ENTITIES = 5;
+const ANOMALY_EVENT = 'knowgraph.anomalies';
+
+export const WEBHOOK_EVENT_HEADER = 'X-Knowgraph-Event';
+export const WEBHOOK_SIGNATURE_HEADER = 'X-Knowgraph-Signature-256';
+
+type ScanSummary = Omit<ScanMetrics, 'byEntity'>;
+
+function summarize(scan: ScanMetrics): ScanSummary {
+  const { timestamp, files, entities, edges, coverage } = scan;
+  return { timestamp, files, entities, edges, coverage };
+}
+
+/** The report without per-entity metrics, which can be large. */
+function alertPayload(report: AnomalyReport): {
+  readonly previous: ScanSummary;
+  readonly current: ScanSummary;
+  readonly anomalies: AnomalyReport['anomalies'];
+} {
+  return {
+    previous: summarize(report.previous),
+    current: summarize(report.current),
+    anomalies: report.anomalies,
+  };
+}
+
+/** What every sink delivers, whether a report or a message. */
+interface Alert {
+  readonly event: string;
+  readonly subject: string;
+  /** The headline, then one line per item. */
+  readonly text: string;
+  readonly payload: Readonly<Record<string, unknown>>;
+}
+
+function reportAlert(report: AnomalyReport): Alert {
+  return {
+    event: ANOMALY_EVENT,
+    subject: `knowgraph: ${report.anomalies.length} graph anomalies`,
+    text: formatAlertText(report),
+    payload: alertPayload(report),
+  };
+}
+
+function messageAlert(message: AlertMessage): Alert {
+  return {
+    event: message.event,
+    subject: message.subject,
+    text: [message.subject, ...message.lines].join('\n'),
+    payload: message.data,
+  };
+}
+
+/** A sink that delivers reports and messages alike through `deliver`. */
+function alertSink(
+  name: string,
+  deliver: (alert: Alert) => Promise<DeliveryOutcome>,
+): AlertSink {
+  return {
+    name,
+    send: (report) => deliver(reportAlert(report)),
+    notify: (message) => deliver(messageAlert(message)),
+  };
+}
+
+/** The report as plain text, one line per anomaly. */
+export function formatAlertText(report: AnomalyReport): string {
+  const lines = [
+    `knowgraph: ${report.anomalies.length} graph anomalies in the scan at ${report.current.timestamp}`,
+  ];
+  for (const anomaly of report.anomalies) {
+    const names = anomaly.entities.slice(0, LISTED_ENTITIES).join(', ');
+    const hidden = anomaly.entities.length - LISTED_ENTITIES;
+    const more = hidden > 0 ? ` and ${hidden} more` : '';
+    lines.push(`- ${anomaly.message}${names ? `: ${names}${more}` : ''}`);
+  }
+  return lines.join('\n');
+}
+
+/**
+ * The `sha256=`-prefixed hex HMAC of `body`, as sent in
+ * `X-Knowgraph-Signature-256`. Receivers recompute it over the raw body.
+ */
+export function webhookSignature(
+  secret: string,
+  body: string | Buffer,
+): string {
+  const digest = createHmac('sha256', secret).update(body).digest('hex');
+  return `sha256=${digest}`;
+}
+
+/**
+ * POST the report as JSON (without per-entity metrics) through `client`,
+ * which retries failures and may queue the report for a later run. A
+ * template replaces the body, and must render valid JSON; it is parsed
+ * here, so a broken one fails before any report is sent. The body is
+ * signed when there is a secret, and queued deliveries keep the signature.
+ * Messages are posted the same way, with their data in place of the
+ * report's fields.
+ */
+export function createWebhookAlertSink(
+  url: string,
+  client: DeliveryClient = createDeliveryClient(),
+  options: WebhookAlertOptions = {},
+): AlertSink {
+  const template =
+    options.template === undefined
+      ? undefined
+      : parseTemplate(options.template, {
+          name: options.templateName ?? 'webhook',
+          functions: {
+            ...REPORT_FUNCTIONS,
+            json: (...args) => JSON.stringify(args.at(-1) ?? null),
+          },
+        });
+
+  function render(alert: Alert): string {
+    const payload = { event: alert.event, ...alert.payload };
+    if (!template) return JSON.stringify(payload);
+    const body = template.execute({ ...payload, text: alert.text });
+    try {
+      JSON.parse(body);
+    } catch (err) {
+      throw createKnowgraphError(
+        'usage',
+        `Webhook template ${template.name} did not render JSON: ${(err as Error).message}`,
+      );
+    }
+    return body;
+  }
+
+  return alertSink('webhook', async (alert) => {
+    const body = render(alert);
+    const headers: Record<string, string> = {
+      [WEBHOOK_EVENT_HEADER]: alert.event,
+    };
+    if (options.secret !== undefined) {
+      headers[WEBHOOK_SIGNATURE_HEADER] = webhookSignature(
+        options.secret,
+        body,
+      );
+    }
+    return client.send({ sink: 'webhook', url, body, headers });
+  });
+}
+
+/** POST the text summary to a Slack incoming webhook through `client`. */
+export function createSlackAlertSink(
+  url: string,
+  client: DeliveryClient = createDeliveryClient(),
+): AlertSink {
+  return alertSink('slack', async (alert) => {
+    const body = JSON.stringify({ text: alert.text });
+    return client.send({ sink: 'slack', url, body });
+  });
+}
+
+/**
+ * POST the text summary to a Microsoft Teams incoming webhook through
+ * `client`, as an Adaptive Card with the headline in bold.
+ */
+export function createTeamsAlertSink(
+  url: string,
+  client: DeliveryClient = createDeliveryClient(),
+): AlertSink {
+  return alertSink('teams', async (alert) => {
+    const [headline, ...lines] = alert.text.split('\n');
+    const card = {
+      $schema: 'http://adaptivecards.io/schemas/adaptive-card.json',
+      type: 'AdaptiveCard',
+      version: '1.4',
+      body: [
+        { type: 'TextBlock', text: headline, weight: 'Bolder', wrap: true },
+        ...lines.map((text) => ({ type: 'TextBlock', text, wrap: true })),
+      ],
+    };
+    const body = JSON.stringify({
+      type: 'message',
+      attachments: [
+        {
+          contentType: 'application/vnd.microsoft.card.adaptive',
+          content: card,
+        },
+      ],
+    });
+    return client.send({ sink: 'teams', url, body });
+  });
+}
+
+/**
+ * Mail the text summary from `from` to `to` through an SMTP server.
+ * Failures are retried, but never queued in the outbox.
+ */
+export function createEmailAlertSink(
+  options: SmtpOptions & {
+    readonly from: string;
+    readonly to: readonly string[];
+  },
+): AlertSink {
+  const { from, to, ...smtp } = options;
+  return alertSink('email', async (alert) => {
+    await sendMail(smtp, {
+      from,
+      to,
+      subject: alert.subject,
+      text: alert.text,
+    });
+    return 'sent';
+  });
+}
+
+/**
+ * Append the report as one JSON line, for log shippers to pick up. Lines
+ * carry the event, so reports and messages can be told apart.
+ */
+export function createFileAlertSink(path: string): AlertSink {
+  return alertSink('file', async (alert) => {
+    mkdirSync(dirname(path), { recursive: true });
+    const record = { event: alert.event, ...alert.payload };
+    appendFileSync(path, `${JSON.stringify(record)}\n`, 'utf-8');
+    return 'sent';
+  });
+}
@@ -0,0 +1,155 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Compares consecutive scans for dependency spikes, ownership churn, status downgrades, and coverage drops
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [history, anomalies, detection]
+ * context:
+ *   business_goal: Separate the changes worth a human look from the normal churn of a growing codebase
+ *   domain: history
+ */
+import type {
+  Anomaly,
+  AnomalyReport,
+  AnomalyThresholds,
+  ScanMetrics,
+} from './types.js';
+
+export const DEFAULT_ANOMALY_THRESHOLDS: AnomalyThresholds = {
+  dependencySpikePercent: 50,
+  ownershipChurnPercent: 10,
+  statusDowngrades: 5,
+  coverageDropPoints: 5,
+};
+
+// Higher is healthier; a move to a lower rank is a downgrade
+const STATUS_RANK: Readonly<Record<string, number>> = {
+  deprecated: 0,
+  experimental: 1,
+  stable: 2,
+};
+
+function round(value: number): number {
+  return Math.round(value * 10) / 10;
+}
+
+function dependencySpike(
+  previous: ScanMetrics,
+  current: ScanMetrics,
+  threshold: number,
+): Anomaly | undefined {
+  if (previous.edges === 0) return undefined;
+  const added = current.edges - previous.edges;
+  const growth = round((added / previous.edges) * 100);
+  if (growth < threshold) return undefined;
+  const grown = Object.entries(current.byEntity)
+    .map(([id, entity]) => ({
+      name: entity.name,
+      added: entity.dependencies - (previous.byEntity[id]?.dependencies ?? 0),
+    }))
+    .filter((entry) => entry.added > 0)
+    .sort((a, b) => b.added - a.added || a.name.localeCompare(b.name));
+  return {
+    kind: 'dependency_spike',
+    message: `Dependency edges grew ${growth}% (${previous.edges} → ${current.edges})`,
+    value: growth,
+    threshold,
+    entities: grown.map((entry) => entry.name),
+  };
+}
+
+function ownershipChurn(
+  previous: ScanMetrics,
+  current: ScanMetrics,
+  threshold: number,
+): Anomaly | undefined {
+  const common = Object.keys(current.byEntity).filter(
+    (id) => id in previous.byEntity,
+  );
+  if (common.length === 0) return undefined;
+  const changed = common.filter(
+    (id) => current.byEntity[id].owner !== previous.byEntity[id].owner,
+  );
+  const churn = round((changed.length / common.length) * 100);
+  if (churn < threshold) return undefined;
+  return {
+    kind: 'ownership_churn',
+    message: `Owner changed for ${churn}% of entities (${changed.length} of ${common.length})`,
+    value: churn,
+    threshold,
+    entities: changed.map((id) => current.byEntity[id].name).sort(),
+  };
+}
+
+function statusDowngrades(
+  previous: ScanMetrics,
+  current: ScanMetrics,
+  threshold: number,
+): Anomaly | undefined {
+  const downgraded = Object.entries(current.byEntity)
+    .filter(([id, entity]) => {
+      const before = STATUS_RANK[previous.byEntity[id]?.status ?? ''];
+      const after = STATUS_RANK[entity.status ?? ''];
+      return before !== undefined && after !== undefined && after < before;
+    })
+    .map(([, entity]) => entity.name)
+    .sort();
+  if (downgraded.length === 0 || downgraded.length < threshold) {
+    return undefined;
+  }
+  return {
+    kind: 'status_downgrade',
+    message: `${downgraded.length} entities moved to a lower status`,
+    value: downgraded.length,
+    threshold,
+    entities: downgraded,
+  };
+}
+
+function coverageDrop(
+  previous: ScanMetrics,
+  current: ScanMetrics,
+  threshold: number,
+): Anomaly | undefined {
+  const drop = round(previous.coverage - current.coverage);
+  if (drop <= 0 || drop < threshold) return undefined;
+  return {
+    kind: 'coverage_drop',
+    message: `Coverage dropped ${drop} points (${previous.coverage}% → ${current.coverage}%)`,
+    value: drop,
+    threshold,
+    entities: [],
+  };
+}
+
+/**
+ * Compare a scan with the one before it. Each check fires when its change
+ * reaches the threshold; thresholds not given use the defaults.
+ */
+export function detectAnomalies(
+  previous: ScanMetrics,
+  current: ScanMetrics,
+  thresholds: Partial<AnomalyThresholds> = {},
+): AnomalyReport {
+  const limits = { ...DEFAULT_ANOMALY_THRESHOLDS, ...thresholds };
+  const anomalies = [
+    dependencySpike(previous, current, limits.dependencySpikePercent),
+    ownershipChurn(previous, current, limits.ownershipChurnPercent),
+    statusDowngrades(previous, current, limits.statusDowngrades),
+    coverageDrop(previous, current, limits.coverageDropPoints),
+  ].filter((anomaly): anomaly is Anomaly => anomaly !== undefined);
+  return { previous, current, anomalies };
+}
+
+/** Reports for each scan in `history` against the one before it. */
+export function detectHistoryAnomalies(
+  history: readonly ScanMetrics[],
+  thresholds: Partial<AnomalyThresholds> = {},
+): readonly AnomalyReport[] {
+  return history
+    .slice(1)
+    .map((current, index) =>
+      detectAnomalies(history[index], current, thresholds),
+    );
+}
@@ -0,0 +1,40 @@
+export type {
+  AlertMessage,
+  AlertSink,
+  Anomaly,
+  AnomalyKind,
+  AnomalyReport,
+  AnomalyThresholds,
+  CompactionResult,
+  EntityMetrics,
+  RetentionPolicy,
+  ScanMetrics,
+  WebhookAlertOptions,
+} from './types.js';
+export {
+  appendScanMetrics,
+  collectScanMetrics,
+  followRenames,
+  readScanHistory,
+} from './scan-history.js';
+export {
+  DEFAULT_RETENTION,
+  compactScanHistory,
+  writeScanHistory,
+} from './retention.js';
+export {
+  DEFAULT_ANOMALY_THRESHOLDS,
+  detectAnomalies,
+  detectHistoryAnomalies,
+} from './anomalies.js';
+export {
+  createEmailAlertSink,
+  createFileAlertSink,
+  createSlackAlertSink,
+  createTeamsAlertSink,
+  createWebhookAlertSink,
+  formatAlertText,
+  webhookSignature,
+  WEBHOOK_EVENT_HEADER,
+  WEBHOOK_SIGNATURE_HEADER,
+} from './alert-sinks.js';
@@ -0,0 +1,109 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Compacts the scan history to daily, then weekly scans, then monthly trend aggregates, and rewrites it in place
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [history, retention, compaction, jsonl]
+ * context:
+ *   business_goal: Thin old scans without losing the long-run trends teams report on
+ *   domain: history
+ */
+import { mkdirSync, renameSync, writeFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import type {
+  CompactionResult,
+  RetentionPolicy,
+  ScanMetrics,
+} from './types.js';
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+export const DEFAULT_RETENTION: RetentionPolicy = {
+  dailyDays: 30,
+  weeklyDays: 365,
+};
+
+/** The UTC date of the Monday starting the week of `time`. */
+function weekOf(time: number): string {
+  const day = (new Date(time).getUTCDay() + 6) % 7;
+  return new Date(time - day * DAY_MS).toISOString().slice(0, 10);
+}
+
+/**
+ * The bucket a scan falls in: its day while it is recent, then its week,
+ * then its month. Only the last scan of each bucket survives.
+ */
+function bucketOf(
+  scan: ScanMetrics,
+  policy: RetentionPolicy,
+  now: number,
+): string {
+  const time = Date.parse(scan.timestamp);
+  const age = (now - time) / DAY_MS;
+  if (age < policy.dailyDays) return `day ${scan.timestamp.slice(0, 10)}`;
+  if (age < policy.weeklyDays) return `week ${weekOf(time)}`;
+  return `month ${scan.timestamp.slice(0, 7)}`;
+}
+
+/**
+ * Compact `history` (oldest first) under `policy`. The last scan of each
+ * day within `dailyDays` and of each week within `weeklyDays` is kept
+ * whole; older scans fold into one trend aggregate per month, holding its
+ * last scan's totals without the per-entity metrics. The latest scan is
+ * always kept, so anomaly checks still compare the next scan with it.
+ */
+export function compactScanHistory(
+  history: readonly ScanMetrics[],
+  policy: RetentionPolicy = DEFAULT_RETENTION,
+  now: Date = new Date(),
+): CompactionResult {
+  const buckets = new Map<string, ScanMetrics[]>();
+  history.forEach((scan, index) => {
+    const key =
+      index === history.length - 1
+        ? 'latest'
+        : bucketOf(scan, policy, now.getTime());
+    buckets.set(key, [...(buckets.get(key) ?? []), scan]);
+  });
+  const compacted: ScanMetrics[] = [];
+  let aggregates = 0;
+  for (const [key, scans] of buckets) {
+    const last = scans[scans.length - 1];
+    if (!key.startsWith('month ')) {
+      compacted.push(last);
+      continue;
+    }
+    aggregates++;
+    compacted.push({
+      ...last,
+      byEntity: {},
+      scans: scans.reduce((sum, scan) => sum + (scan.scans ?? 1), 0),
+    });
+  }
+  compacted.sort((a, b) => Date.parse(a.timestamp) - Date.parse(b.timestamp));
+  return {
+    history: compacted,
+    kept: compacted.length - aggregates,
+    aggregates,
+    pruned: history.length - compacted.length,
+  };
+}
+
+/**
+ * Replace the history at `historyPath` with `history`, writing a
+ * temporary file first so a failed write leaves the old one intact.
+ */
+export function writeScanHistory(
+  historyPath: string,
+  history: readonly ScanMetrics[],
+): void {
+  mkdirSync(dirname(historyPath), { recursive: true });
+  const tempPath = `${historyPath}.tmp`;
+  writeFileSync(
+    tempPath,
+    history.map((scan) => `${JSON.stringify(scan)}\n`).join(''),
+    'utf-8',
+  );
+  renameSync(tempPath, historyPath);
+}
@@ -0,0 +1,101 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Collects per-scan graph metrics and reads and appends the JSON Lines scan history
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [history, metrics, jsonl]
+ * context:
+ *   business_goal: Keep a cheap record of every scan that trends and anomaly checks can read back
+ *   domain: history
+ */
+import { appendFileSync, existsSync, mkdirSync, readFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { EntityMetrics, ScanMetrics } from './types.js';
+
+/**
+ * Measure the index after a scan of `files` parseable files. Coverage is
+ * the share of those files holding at least one entity, to one decimal.
+ */
+export function collectScanMetrics(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  files: number,
+  now: Date = new Date(),
+): ScanMetrics {
+  const dependencies = new Map<string, number>();
+  for (const edge of graph.edges) {
+    dependencies.set(edge.from, (dependencies.get(edge.from) ?? 0) + 1);
+  }
+  const byEntity: Record<string, EntityMetrics> = {};
+  for (const entity of entities) {
+    byEntity[entity.id] = {
+      name: entity.name,
+      owner: entity.owner,
+      status: entity.status,
+      dependencies: dependencies.get(entity.id) ?? 0,
+    };
+  }
+  const annotated = new Set(entities.map((entity) => entity.filePath)).size;
+  return {
+    timestamp: now.toISOString(),
+    files,
+    entities: entities.length,
+    edges: graph.edges.length,
+    coverage: files > 0 ? Math.round((annotated / files) * 1000) / 10 : 0,
+    byEntity,
+  };
+}
+
+/**
+ * `metrics` with each entity under the id `resolve` gives it now, so an
+ * earlier scan compares with the current one across renames. An entity
+ * already under its current id wins over one renamed to it.
+ */
+export function followRenames(
+  metrics: ScanMetrics,
+  resolve: (id: string) => string,
+): ScanMetrics {
+  const byEntity: Record<string, EntityMetrics> = {};
+  for (const [id, entity] of Object.entries(metrics.byEntity)) {
+    const current = resolve(id);
+    if (current === id || !(current in metrics.byEntity)) {
+      byEntity[current] = entity;
+    }
+  }
+  return { ...metrics, byEntity };
+}
+
+/**
+ * Parse the history at `historyPath`, oldest scan first. A missing file is
+ * empty. Throws on a line that is not JSON, with its line number.
+ */
+export function readScanHistory(historyPath: string): readonly ScanMetrics[] {
+  if (!existsSync(historyPath)) return [];
+  const scans: ScanMetrics[] = [];
+  const lines = readFileSync(historyPath, 'utf-8').split('\n');
+  lines.forEach((line, index) => {
+    if (line.trim() === '') return;
+    try {
+      scans.push(JSON.parse(line) as ScanMetrics);
+    } catch {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid scan history entry at ${historyPath}:${index + 1}`,
+      );
+    }
+  });
+  return scans;
+}
+
+/** Append one scan to the history, creating the file when needed. */
+export function appendScanMetrics(
+  historyPath: string,
+  metrics: ScanMetrics,
+): void {
+  mkdirSync(dirname(historyPath), { recursive: true });
+  appendFileSync(historyPath, `${JSON.stringify(metrics)}\n`, 'utf-8');
+}
@@ -0,0 +1,128 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for per-scan graph metrics, the anomalies between scans, and where alerts go
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [history, anomalies, alerts, types, interface]
+ * context:
+ *   business_goal: Keep scan history readable by older and newer versions of knowgraph alike
+ *   domain: history
+ */
+import type { DeliveryOutcome } from '../delivery/types.js';
+
+/** The facts about one entity that anomaly checks compare between scans. */
+export interface EntityMetrics {
+  readonly name: string;
+  readonly owner: string | null;
+  readonly status: string | null;
+  /** Outgoing dependency edges. */
+  readonly dependencies: number;
+}
+
+/** One line of the scan history, recorded after each `knowgraph index`. */
+export interface ScanMetrics {
+  /** ISO 8601 time of the scan. */
+  readonly timestamp: string;
+  readonly files: number;
+  readonly entities: number;
+  readonly edges: number;
+  /** Share of scanned files with at least one entity, as a percentage. */
+  readonly coverage: number;
+  /** Keyed by entity id. */
+  readonly byEntity: Readonly<Record<string, EntityMetrics>>;
+  /**
+   * Set on a trend aggregate left by compaction: the number of scans in
+   * its month it stands for. Aggregates keep the totals of the month's
+   * last scan but not `byEntity`.
+   */
+  readonly scans?: number;
+}
+
+/** How long compaction keeps scans, in days before now. */
+export interface RetentionPolicy {
+  /** Keep the last scan of each day this recent. */
+  readonly dailyDays: number;
+  /** Then keep the last scan of each week this recent. */
+  readonly weeklyDays: number;
+}
+
+export interface CompactionResult {
+  /** The compacted history, oldest first. */
+  readonly history: readonly ScanMetrics[];
+  /** Scans kept whole as the last of their day or week. */
+  readonly kept: number;
+  /** Monthly trend aggregates older scans were folded into. */
+  readonly aggregates: number;
+  /** Entries of the history before that are gone. */
+  readonly pruned: number;
+}
+
+export type AnomalyKind =
+  | 'dependency_spike'
+  | 'ownership_churn'
+  | 'status_downgrade'
+  | 'coverage_drop';
+
+export interface Anomaly {
+  readonly kind: AnomalyKind;
+  readonly message: string;
+  /** The measured change, in the threshold's unit. */
+  readonly value: number;
+  readonly threshold: number;
+  /** Names of the entities involved, most affected first. */
+  readonly entities: readonly string[];
+}
+
+/** Each check fires when its change reaches the threshold. */
+export interface AnomalyThresholds {
+  /** Growth in dependency edges, in percent. */
+  readonly dependencySpikePercent: number;
+  /** Entities whose owner changed, in percent of those in both scans. */
+  readonly ownershipChurnPercent: number;
+  /** Entities moved to a lower status (stable > experimental > deprecated). */
+  readonly statusDowngrades: number;
+  /** Fall in coverage, in percentage points. */
+  readonly coverageDropPoints: number;
+}
+
+export interface AnomalyReport {
+  readonly previous: ScanMetrics;
+  readonly current: ScanMetrics;
+  readonly anomalies: readonly Anomaly[];
+}
+
+/** How a webhook sink shapes and signs what it posts. */
+export interface WebhookAlertOptions {
+  /**
+   * A template in Go text/template syntax that renders the JSON body. It
+   * sees `.event`, `.text`, `.previous`, `.current`, and `.anomalies`, and
+   * has the report helpers plus `json`, which quotes a value as JSON.
+   */
+  readonly template?: string;
+  /** Shown in template errors; usually the template's file name. */
+  readonly templateName?: string;
+  /** Signs each body with HMAC-SHA256. */
+  readonly secret?: string;
+}
+
+/** Something other than an anomaly report, such as how a pipeline ran. */
+export interface AlertMessage {
+  /** Sent as `X-Knowgraph-Event` and `.event`, e.g. `knowgraph.pipeline`. */
+  readonly event: string;
+  /** The headline, and the subject of mails. */
+  readonly subject: string;
+  /** Lines below the headline. */
+  readonly lines: readonly string[];
+  /** Fields webhook and file sinks send alongside `event` and `text`. */
+  readonly data: Readonly<Record<string, unknown>>;
+}
+
+/** Where anomaly reports are delivered. */
+export interface AlertSink {
+  readonly name: string;
+  /** Resolves to `queued` when the report waits in the outbox instead. */
+  send(report: AnomalyReport): Promise<DeliveryOutcome>;
+  /** Deliver `message` the way the sink delivers reports. */
+  notify(message: AlertMessage): Promise<DeliveryOutcome>;
+}
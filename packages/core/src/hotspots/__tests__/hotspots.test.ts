@@ -0,0 +1,150 @@
+import { describe, it, expect } from 'vitest';
+import type { GitFileCommit } from '../../enrichers/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { RevenueImpact } from '../../types/entity.js';
+import {
+  buildHotspotReport,
+  formatHotspotCsv,
+  measureChurn,
+} from '../hotspots.js';
+
+function makeModule(
+  name: string,
+  revenueImpact?: RevenueImpact,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'module',
+    description: `${name} module`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: null,
+    metadata: {
+      type: 'module',
+      description: `${name} module`,
+      ...(revenueImpact && { context: { revenue_impact: revenueImpact } }),
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+function commits(
+  count: number,
+  authors: readonly string[] = ['ana@example.com'],
+): readonly GitFileCommit[] {
+  return Array.from({ length: count }, (_, i) => ({
+    commit: `c${i}`,
+    author: authors[i % authors.length],
+    date: `2024-06-${String(30 - i).padStart(2, '0')}T12:00:00Z`,
+  }));
+}
+
+const churn = measureChurn(
+  new Map([
+    ['src/charges.ts', commits(9, ['ana@example.com', 'bo@example.com'])],
+    ['src/refunds.ts', commits(2)],
+    ['src/ledger.ts', commits(1)],
+    ['src/emails.ts', commits(6)],
+  ]),
+);
+
+const entities = [
+  makeModule('refunds', 'critical'),
+  makeModule('emails', 'low'),
+  makeModule('charges', 'critical'),
+  makeModule('ledger', 'high'),
+  makeModule('avatars'),
+  makeModule('format', undefined, { entityType: 'function' }),
+];
+
+describe('measureChurn', () => {
+  it('counts commits and authors per file from a date on', () => {
+    expect(churn.get('src/charges.ts')).toEqual({
+      commits: 9,
+      authors: 2,
+      lastModified: '2024-06-30T12:00:00Z',
+    });
+    const recent = measureChurn(
+      new Map([['src/charges.ts', commits(9)]]),
+      new Date('2024-06-25T00:00:00Z'),
+    );
+    expect(recent.get('src/charges.ts')?.commits).toBe(6);
+    expect(
+      measureChurn(
+        new Map([['src/old.ts', commits(1)]]),
+        new Date('2024-07-01T00:00:00Z'),
+      ).size,
+    ).toBe(0);
+  });
+});
+
+describe('buildHotspotReport', () => {
+  it('puts high-churn critical modules first', () => {
+    const report = buildHotspotReport(entities, churn);
+    expect(report.churnThreshold).toBe(4);
+    expect(
+      report.entries.map((entry) => [entry.name, entry.quadrant]),
+    ).toEqual([
+      ['charges', 'hotspot'],
+      ['emails', 'churning'],
+      ['refunds', 'critical'],
+      ['ledger', 'critical'],
+      ['avatars', 'quiet'],
+    ]);
+    expect(report.counts).toEqual({
+      hotspot: 1,
+      churning: 1,
+      critical: 2,
+      quiet: 1,
+    });
+    expect(report.entries[4]).toMatchObject({
+      commits: 0,
+      revenueImpact: null,
+      lastModified: null,
+    });
+  });
+
+  it('takes a churn threshold and the impacts that count as critical', () => {
+    const report = buildHotspotReport(entities, churn, {
+      churnThreshold: 2,
+      criticalImpacts: ['critical'],
+    });
+    expect(
+      report.entries
+        .filter((entry) => entry.quadrant === 'hotspot')
+        .map((entry) => entry.name),
+    ).toEqual(['charges', 'refunds']);
+    expect(
+      report.entries.find((entry) => entry.name === 'ledger')?.quadrant,
+    ).toBe('quiet');
+  });
+});
+
+describe('formatHotspotCsv', () => {
+  it('writes one row per entry in report order', () => {
+    const csv = formatHotspotCsv(buildHotspotReport(entities, churn));
+    const lines = csv.trimEnd().split('\n');
+    expect(lines[0]).toBe(
+      'quadrant,name,type,file,owner,revenue_impact,commits,authors,last_modified',
+    );
+    expect(lines[1]).toBe(
+      'hotspot,charges,module,src/charges.ts,payments-team,critical,9,2,2024-06-30T12:00:00Z',
+    );
+    expect(lines[5]).toBe(
+      'quiet,avatars,module,src/avatars.ts,payments-team,,0,0,',
+    );
+  });
+});
@@ -0,0 +1,157 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Measures how often each file changed in git and places every module on a quadrant of churn against revenue impact, exportable as CSV
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [hotspots, churn, git, criticality, report, csv]
+ * context:
+ *   business_goal: Point stabilization work at the code that changes most and costs most when it breaks
+ *   domain: hotspots
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type { GitFileCommit } from '../enrichers/types.js';
+import { csvField } from '../finops/csv.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { ExtendedMetadata, RevenueImpact } from '../types/entity.js';
+import type {
+  FileChurn,
+  HotspotEntry,
+  HotspotOptions,
+  HotspotQuadrant,
+  HotspotReport,
+} from './types.js';
+
+/** In report order. */
+export const HOTSPOT_QUADRANTS: readonly HotspotQuadrant[] = [
+  'hotspot',
+  'churning',
+  'critical',
+  'quiet',
+];
+
+const IMPACT_RANK: Readonly<Record<RevenueImpact, number>> = {
+  critical: 0,
+  high: 1,
+  medium: 2,
+  low: 3,
+  none: 4,
+};
+
+/**
+ * The churn of each file in `history`, as `parseGitHistory` returns it,
+ * counting only commits from `since` on when given.
+ */
+export function measureChurn(
+  history: ReadonlyMap<string, readonly GitFileCommit[]>,
+  since?: Date,
+): ReadonlyMap<string, FileChurn> {
+  const churn = new Map<string, FileChurn>();
+  for (const [path, commits] of history) {
+    const recent = since
+      ? commits.filter((commit) => Date.parse(commit.date) >= since.getTime())
+      : commits;
+    if (recent.length === 0) continue;
+    churn.set(path, {
+      commits: recent.length,
+      authors: new Set(recent.map((commit) => commit.author)).size,
+      lastModified: recent[0].date,
+    });
+  }
+  return churn;
+}
+
+function median(values: readonly number[]): number {
+  const sorted = [...values].sort((a, b) => a - b);
+  const middle = Math.floor(sorted.length / 2);
+  return sorted.length % 2 === 1
+    ? sorted[middle]
+    : (sorted[middle - 1] + sorted[middle]) / 2;
+}
+
+function quadrantOf(highChurn: boolean, critical: boolean): HotspotQuadrant {
+  if (highChurn) return critical ? 'hotspot' : 'churning';
+  return critical ? 'critical' : 'quiet';
+}
+
+/**
+ * Place each entity of `options.types` on a quadrant of churn, the commits
+ * to its file in `churn`, against criticality, its `context.revenue_impact`.
+ * Entities in one file share its churn.
+ */
+export function buildHotspotReport(
+  entities: readonly StoredEntity[],
+  churn: ReadonlyMap<string, FileChurn>,
+  options: HotspotOptions = {},
+): HotspotReport {
+  const types = new Set<string>(options.types ?? ['module', 'service']);
+  const criticalImpacts = options.criticalImpacts ?? ['critical', 'high'];
+  const graded = entities.filter((entity) => types.has(entity.entityType));
+  const changed = graded
+    .map((entity) => churn.get(entity.filePath)?.commits ?? 0)
+    .filter((commits) => commits > 0);
+  const churnThreshold =
+    options.churnThreshold ??
+    (changed.length === 0 ? 1 : Math.max(1, median(changed)));
+
+  const entries: HotspotEntry[] = graded.map((entity) => {
+    const file = churn.get(entity.filePath);
+    const commits = file?.commits ?? 0;
+    const revenueImpact =
+      (entity.metadata as ExtendedMetadata).context?.revenue_impact ?? null;
+    const critical =
+      revenueImpact !== null && criticalImpacts.includes(revenueImpact);
+    return {
+      entityId: entity.id,
+      name: entity.name,
+      entityType: entity.entityType,
+      filePath: entity.filePath,
+      owner: entity.owner,
+      revenueImpact,
+      commits,
+      authors: file?.authors ?? 0,
+      lastModified: file?.lastModified ?? null,
+      quadrant: quadrantOf(commits > 0 && commits >= churnThreshold, critical),
+    };
+  });
+  const rank = (impact: RevenueImpact | null): number =>
+    impact === null ? Object.keys(IMPACT_RANK).length : IMPACT_RANK[impact];
+  entries.sort(
+    (a, b) =>
+      HOTSPOT_QUADRANTS.indexOf(a.quadrant) -
+        HOTSPOT_QUADRANTS.indexOf(b.quadrant) ||
+      b.commits - a.commits ||
+      rank(a.revenueImpact) - rank(b.revenueImpact) ||
+      compareStrings(a.filePath, b.filePath) ||
+      compareStrings(a.name, b.name),
+  );
+
+  const counts = { hotspot: 0, churning: 0, critical: 0, quiet: 0 };
+  for (const entry of entries) counts[entry.quadrant] += 1;
+  return { churnThreshold, criticalImpacts, entries, counts };
+}
+
+/** One row per entry, in report order, for spreadsheets and BI tools. */
+export function formatHotspotCsv(report: HotspotReport): string {
+  const rows = report.entries.map((entry) =>
+    [
+      entry.quadrant,
+      entry.name,
+      entry.entityType,
+      entry.filePath,
+      entry.owner ?? '',
+      entry.revenueImpact ?? '',
+      String(entry.commits),
+      String(entry.authors),
+      entry.lastModified ?? '',
+    ]
+      .map(csvField)
+      .join(','),
+  );
+  return (
+    [
+      'quadrant,name,type,file,owner,revenue_impact,commits,authors,last_modified',
+      ...rows,
+    ].join('\n') + '\n'
+  );
+}
@@ -0,0 +1,13 @@
+export type {
+  FileChurn,
+  HotspotQuadrant,
+  HotspotOptions,
+  HotspotEntry,
+  HotspotReport,
+} from './types.js';
+export {
+  HOTSPOT_QUADRANTS,
+  buildHotspotReport,
+  formatHotspotCsv,
+  measureChurn,
+} from './hotspots.js';
@@ -0,0 +1,62 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the churn of each file and the quadrant report that crosses it with revenue impact
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [hotspots, churn, git, criticality, types, interface]
+ * context:
+ *   business_goal: Let hotspot reports be charted in spreadsheets as well as read in the terminal
+ *   domain: hotspots
+ */
+import type { EntityType, RevenueImpact } from '../types/entity.js';
+
+/** How much a file changed in the measured window. */
+export interface FileChurn {
+  readonly commits: number;
+  /** Distinct commit authors. */
+  readonly authors: number;
+  /** ISO 8601 time of the newest commit. */
+  readonly lastModified: string;
+}
+
+/**
+ * Where a node falls: `hotspot` for high churn and high criticality,
+ * `churning` for high churn only, `critical` for high criticality only,
+ * and `quiet` for neither.
+ */
+export type HotspotQuadrant = 'hotspot' | 'churning' | 'critical' | 'quiet';
+
+export interface HotspotOptions {
+  /** Entity types placed on the quadrant. Default module and service. */
+  readonly types?: readonly EntityType[];
+  /** Revenue impacts that count as critical. Default critical and high. */
+  readonly criticalImpacts?: readonly RevenueImpact[];
+  /**
+   * Commits in the window at which churn counts as high. Defaults to the
+   * median of the nodes that changed, and at least 1.
+   */
+  readonly churnThreshold?: number;
+}
+
+export interface HotspotEntry {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly owner: string | null;
+  readonly revenueImpact: RevenueImpact | null;
+  /** Commits to its file in the window, 0 when it did not change. */
+  readonly commits: number;
+  readonly authors: number;
+  readonly lastModified: string | null;
+  readonly quadrant: HotspotQuadrant;
+}
+
+export interface HotspotReport {
+  readonly churnThreshold: number;
+  readonly criticalImpacts: readonly RevenueImpact[];
+  /** Hotspots first, then churning, critical, and quiet; most commits first. */
+  readonly entries: readonly HotspotEntry[];
+  readonly counts: Readonly<Record<HotspotQuadrant, number>>;
+}
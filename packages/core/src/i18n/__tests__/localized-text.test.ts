@@ -0,0 +1,101 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  listLocales,
+  localizedValues,
+  localizeEntity,
+  resolveLocalizedText,
+} from '../localized-text.js';
+
+function makeEntity(overrides: Partial<StoredEntity> = {}): StoredEntity {
+  return {
+    id: 'e1',
+    filePath: 'src/checkout.ts',
+    name: 'checkout',
+    entityType: 'module',
+    description: 'Checkout flow',
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: null,
+    metadata: {
+      type: 'module',
+      description: { en: 'Checkout flow', ja: 'チェックアウトの流れ' },
+      context: {
+        business_goal: { en: 'Increase conversion', ja: '購入率の向上' },
+        domain: 'payments',
+      },
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2026-01-01T00:00:00Z',
+    updatedAt: '2026-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const description = { en: 'Checkout flow', ja: 'チェックアウトの流れ' };
+
+describe('resolveLocalizedText', () => {
+  it('returns plain strings unchanged', () => {
+    expect(resolveLocalizedText('Checkout flow', 'ja')).toBe('Checkout flow');
+    expect(resolveLocalizedText(undefined, 'ja')).toBeUndefined();
+  });
+
+  it('picks the requested locale', () => {
+    expect(resolveLocalizedText(description, 'ja')).toBe('チェックアウトの流れ');
+    expect(resolveLocalizedText(description, 'en')).toBe('Checkout flow');
+  });
+
+  it('falls back to the base language, then the default locale', () => {
+    expect(resolveLocalizedText(description, 'ja-JP')).toBe(
+      'チェックアウトの流れ',
+    );
+    expect(resolveLocalizedText(description, 'de', 'ja')).toBe(
+      'チェックアウトの流れ',
+    );
+  });
+
+  it('uses the first translation when neither locale exists', () => {
+    expect(resolveLocalizedText({ fr: 'Paiement', de: 'Kasse' }, 'ja')).toBe(
+      'Paiement',
+    );
+  });
+});
+
+describe('localizedValues and listLocales', () => {
+  it('lists translations and locales', () => {
+    expect(localizedValues(description)).toEqual([
+      'Checkout flow',
+      'チェックアウトの流れ',
+    ]);
+    expect(listLocales(description)).toEqual(['en', 'ja']);
+    expect(localizedValues('Checkout')).toEqual(['Checkout']);
+    expect(listLocales('Checkout')).toEqual([]);
+  });
+});
+
+describe('localizeEntity', () => {
+  it('resolves description and business goal to the locale', () => {
+    const entity = localizeEntity(makeEntity(), 'ja');
+    expect(entity.description).toBe('チェックアウトの流れ');
+    expect(entity.metadata.description).toBe('チェックアウトの流れ');
+    expect(
+      'context' in entity.metadata && entity.metadata.context,
+    ).toMatchObject({ business_goal: '購入率の向上', domain: 'payments' });
+  });
+
+  it('keeps plain-string metadata as is', () => {
+    const entity = localizeEntity(
+      makeEntity({ metadata: { type: 'module', description: 'Checkout' } }),
+      'ja',
+    );
+    expect(entity.description).toBe('Checkout');
+    expect(entity.metadata).toEqual({ type: 'module', description: 'Checkout' });
+  });
+});
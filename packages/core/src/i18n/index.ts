@@ -0,0 +1,7 @@
+export {
+  DEFAULT_LOCALE,
+  resolveLocalizedText,
+  localizedValues,
+  listLocales,
+  localizeEntity,
+} from './localized-text.js';
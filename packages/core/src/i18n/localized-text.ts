@@ -0,0 +1,110 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Resolves locale-keyed descriptions and business goals to a single language
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [i18n, locale, metadata]
+ * context:
+ *   business_goal: Let multinational teams annotate in their working languages while exports stay monolingual
+ *   domain: i18n
+ */
+import type { StoredEntity } from '../indexer/types.js';
+import type { LocalizedText } from '../types/entity.js';
+
+export const DEFAULT_LOCALE = 'en';
+
+function languageOf(locale: string): string {
+  return locale.split('-')[0];
+}
+
+/**
+ * Pick the text for `locale`. Region-specific locales fall back to their
+ * base language (`pt-BR` -> `pt`), then to `defaultLocale`, then to the first
+ * translation present. Plain strings are returned as-is.
+ */
+export function resolveLocalizedText(
+  value: LocalizedText,
+  locale?: string,
+  defaultLocale?: string,
+): string;
+export function resolveLocalizedText(
+  value: LocalizedText | undefined,
+  locale?: string,
+  defaultLocale?: string,
+): string | undefined;
+export function resolveLocalizedText(
+  value: LocalizedText | undefined,
+  locale: string = DEFAULT_LOCALE,
+  defaultLocale: string = DEFAULT_LOCALE,
+): string | undefined {
+  if (value === undefined || typeof value === 'string') return value;
+  const candidates = [
+    locale,
+    languageOf(locale),
+    defaultLocale,
+    languageOf(defaultLocale),
+  ];
+  for (const candidate of candidates) {
+    const text = value[candidate];
+    if (text !== undefined) return text;
+  }
+  return Object.values(value)[0];
+}
+
+/**
+ * Every translation of a localized value, in declaration order.
+ */
+export function localizedValues(
+  value: LocalizedText | undefined,
+): readonly string[] {
+  if (value === undefined) return [];
+  return typeof value === 'string' ? [value] : Object.values(value);
+}
+
+/**
+ * Locales a value is translated into; empty for plain strings.
+ */
+export function listLocales(
+  value: LocalizedText | undefined,
+): readonly string[] {
+  return value === undefined || typeof value === 'string'
+    ? []
+    : Object.keys(value);
+}
+
+/**
+ * Return a copy of the entity with its description and business goal
+ * resolved to `locale`, in both the entity fields and its metadata.
+ */
+export function localizeEntity(
+  entity: StoredEntity,
+  locale: string,
+  defaultLocale: string = DEFAULT_LOCALE,
+): StoredEntity {
+  const metadata = entity.metadata;
+  const description = resolveLocalizedText(
+    metadata.description,
+    locale,
+    defaultLocale,
+  );
+  const context =
+    'context' in metadata && metadata.context
+      ? {
+          ...metadata.context,
+          business_goal: resolveLocalizedText(
+            metadata.context.business_goal,
+            locale,
+            defaultLocale,
+          ),
+        }
+      : undefined;
+
+  return {
+    ...entity,
+    description,
+    metadata: context
+      ? { ...metadata, description, context }
+      : { ...metadata, description },
+  };
+}
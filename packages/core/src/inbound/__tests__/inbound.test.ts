@@ -0,0 +1,118 @@
+import { describe, it, expect } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { webhookSignature } from '../../history/alert-sinks.js';
+import {
+  appendFact,
+  currentFacts,
+  factsForNode,
+  readFactLog,
+} from '../fact-log.js';
+import {
+  mapInboundPayload,
+  valueAtPath,
+  verifyWebhookSignature,
+} from '../mapping.js';
+import type { InboundMapping } from '../types.js';
+
+const mapping: InboundMapping = {
+  node: 'app.name',
+  facts: {
+    version: 'deployment.version',
+    healthy: 'deployment.healthy',
+    region: 'deployment.regions.0',
+    missing: 'deployment.owner',
+    labels: 'deployment.labels',
+  },
+};
+
+const payload = {
+  app: { name: 'billing' },
+  deployment: {
+    version: '1.4.2',
+    healthy: true,
+    regions: ['eu-west-1', 'us-east-1'],
+    labels: { tier: 'gold' },
+  },
+};
+
+describe('mapInboundPayload', () => {
+  it('reads scalar facts by dot path', () => {
+    expect(valueAtPath(payload, 'deployment.regions.1')).toBe('us-east-1');
+    expect(valueAtPath(payload, 'app.name.first')).toBeUndefined();
+    expect(
+      mapInboundPayload('argo', mapping, payload, '2026-05-01T00:00:00.000Z'),
+    ).toEqual({
+      source: 'argo',
+      node: 'billing',
+      facts: { version: '1.4.2', healthy: true, region: 'eu-west-1' },
+      receivedAt: '2026-05-01T00:00:00.000Z',
+    });
+  });
+
+  it('ignores payloads without a node or any fact', () => {
+    expect(mapInboundPayload('argo', mapping, { app: {} })).toBeUndefined();
+    expect(
+      mapInboundPayload('argo', mapping, { app: { name: 'billing' } }),
+    ).toBeUndefined();
+  });
+});
+
+describe('verifyWebhookSignature', () => {
+  it('accepts only the HMAC of the exact body', () => {
+    const body = JSON.stringify(payload);
+    const signature = webhookSignature('shh', body);
+    expect(verifyWebhookSignature('shh', body, signature)).toBe(true);
+    expect(verifyWebhookSignature('shh', `${body} `, signature)).toBe(false);
+    expect(verifyWebhookSignature('other', body, signature)).toBe(false);
+    expect(verifyWebhookSignature('shh', body, 'sha256=00')).toBe(false);
+    expect(verifyWebhookSignature('shh', body, undefined)).toBe(false);
+  });
+});
+
+describe('fact log', () => {
+  it('keeps the latest value of each fact, by id or name', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-facts-'));
+    try {
+      const path = join(dir, 'logs', 'facts.jsonl');
+      expect(readFactLog(path)).toEqual([]);
+      appendFact(path, {
+        source: 'argo',
+        node: 'Billing',
+        facts: { version: '1.4.1', environment: 'prod' },
+        receivedAt: '2026-05-01T00:00:00.000Z',
+      });
+      appendFact(path, {
+        source: 'cmdb',
+        node: 'src/billing',
+        facts: { tier: 'gold' },
+        receivedAt: '2026-05-01T00:01:00.000Z',
+      });
+      appendFact(path, {
+        source: 'argo',
+        node: 'billing',
+        facts: { version: '1.4.2' },
+        receivedAt: '2026-05-01T00:02:00.000Z',
+      });
+      const current = currentFacts(readFactLog(path));
+      const facts = factsForNode(current, {
+        id: 'src/billing',
+        name: 'billing',
+      });
+      expect(facts.map((fact) => [fact.name, fact.value])).toEqual([
+        ['environment', 'prod'],
+        ['tier', 'gold'],
+        ['version', '1.4.2'],
+      ]);
+      expect(facts[2].receivedAt).toBe('2026-05-01T00:02:00.000Z');
+
+      writeFileSync(path, '{"source":\n');
+      expect(() => readFactLog(path)).toThrow(
+        `Invalid fact log entry at ${path}:1`,
+      );
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+});
@@ -0,0 +1,85 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Appends facts from inbound webhooks to a JSON Lines log and folds it into the latest facts per node
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [inbound, webhooks, facts, jsonl, log]
+ * context:
+ *   business_goal: Keep every reported fact so the latest state can be rebuilt at any time
+ *   domain: inbound
+ */
+import { appendFileSync, existsSync, mkdirSync, readFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { InboundFact, NodeFact } from './types.js';
+
+/**
+ * Parse the fact log at `logPath` in the order facts arrived. A missing
+ * file is empty. Throws on a line that is not JSON, with its line number.
+ */
+export function readFactLog(logPath: string): readonly InboundFact[] {
+  if (!existsSync(logPath)) return [];
+  const facts: InboundFact[] = [];
+  const lines = readFileSync(logPath, 'utf-8').split('\n');
+  lines.forEach((line, index) => {
+    if (line.trim() === '') return;
+    try {
+      facts.push(JSON.parse(line) as InboundFact);
+    } catch {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid fact log entry at ${logPath}:${index + 1}`,
+      );
+    }
+  });
+  return facts;
+}
+
+/** Append `fact` to the log, creating the file when needed. */
+export function appendFact(logPath: string, fact: InboundFact): void {
+  mkdirSync(dirname(logPath), { recursive: true });
+  appendFileSync(logPath, `${JSON.stringify(fact)}\n`, 'utf-8');
+}
+
+/**
+ * The latest value of each fact about a node, keyed by the node as the
+ * senders named it, lowercased, and sorted by fact name. A fact reported
+ * again replaces the earlier value, whichever sender reported it.
+ */
+export function currentFacts(
+  facts: readonly InboundFact[],
+): ReadonlyMap<string, readonly NodeFact[]> {
+  const latest = new Map<string, Map<string, NodeFact>>();
+  for (const fact of facts) {
+    const node = fact.node.toLowerCase();
+    const values = latest.get(node) ?? new Map<string, NodeFact>();
+    for (const [name, value] of Object.entries(fact.facts)) {
+      const { source, receivedAt } = fact;
+      values.set(name, { name, value, source, receivedAt });
+    }
+    latest.set(node, values);
+  }
+  return new Map(
+    [...latest].map(([node, values]) => [
+      node,
+      [...values.values()].sort((a, b) => compareStrings(a.name, b.name)),
+    ]),
+  );
+}
+
+/**
+ * The facts about a node, reported under its id or its name. Facts
+ * under the id win over those under the name.
+ */
+export function factsForNode(
+  current: ReadonlyMap<string, readonly NodeFact[]>,
+  node: { readonly id: string; readonly name: string },
+): readonly NodeFact[] {
+  const byName = current.get(node.name.toLowerCase()) ?? [];
+  const byId = current.get(node.id.toLowerCase()) ?? [];
+  const merged = new Map(byName.map((fact) => [fact.name, fact]));
+  for (const fact of byId) merged.set(fact.name, fact);
+  return [...merged.values()].sort((a, b) => compareStrings(a.name, b.name));
+}
@@ -0,0 +1,17 @@
+export type {
+  FactValue,
+  InboundFact,
+  InboundMapping,
+  NodeFact,
+} from './types.js';
+export {
+  mapInboundPayload,
+  valueAtPath,
+  verifyWebhookSignature,
+} from './mapping.js';
+export {
+  appendFact,
+  currentFacts,
+  factsForNode,
+  readFactLog,
+} from './fact-log.js';
@@ -0,0 +1,75 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Maps inbound webhook payloads onto node facts by dot path, and verifies their HMAC-SHA256 signatures
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [inbound, webhooks, mapping, hmac, signatures]
+ * context:
+ *   business_goal: Accept webhooks from any tool without writing code for each one
+ *   domain: inbound
+ */
+import { timingSafeEqual } from 'node:crypto';
+import { webhookSignature } from '../history/alert-sinks.js';
+import type { FactValue, InboundFact, InboundMapping } from './types.js';
+
+/**
+ * The value at `path` in `payload`, such as `deployment.environment` or
+ * `services.0.name`; undefined when any step is missing.
+ */
+export function valueAtPath(payload: unknown, path: string): unknown {
+  let value = payload;
+  for (const key of path.split('.')) {
+    if (value === null || typeof value !== 'object') return undefined;
+    value = (value as Record<string, unknown>)[key];
+  }
+  return value;
+}
+
+function isFactValue(value: unknown): value is FactValue {
+  return (
+    value === null ||
+    typeof value === 'string' ||
+    typeof value === 'number' ||
+    typeof value === 'boolean'
+  );
+}
+
+/**
+ * The facts `payload` reports through `mapping`. Facts whose path is
+ * missing or holds a list or object are left out. Undefined when the
+ * payload names no node or reports none of the facts.
+ */
+export function mapInboundPayload(
+  source: string,
+  mapping: InboundMapping,
+  payload: unknown,
+  receivedAt: string = new Date().toISOString(),
+): InboundFact | undefined {
+  const node = valueAtPath(payload, mapping.node);
+  if (typeof node !== 'string' && typeof node !== 'number') return undefined;
+  const facts: Record<string, FactValue> = {};
+  for (const [name, path] of Object.entries(mapping.facts)) {
+    const value = valueAtPath(payload, path);
+    if (isFactValue(value)) facts[name] = value;
+  }
+  if (Object.keys(facts).length === 0) return undefined;
+  return { source, node: String(node), facts, receivedAt };
+}
+
+/**
+ * Whether `signature` is the `sha256=<hex>` HMAC of the raw `body` under
+ * `secret`, compared in constant time.
+ */
+export function verifyWebhookSignature(
+  secret: string,
+  body: string | Buffer,
+  signature: string | undefined,
+): boolean {
+  if (signature === undefined) return false;
+  const expected = Buffer.from(webhookSignature(secret, body), 'utf-8');
+  const actual = Buffer.from(signature.trim(), 'utf-8');
+  return (
+    expected.length === actual.length && timingSafeEqual(expected, actual)
+  );
+}
@@ -0,0 +1,42 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for facts about nodes that signed inbound webhooks report, and how payloads map onto them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [inbound, webhooks, facts, types, interface]
+ * context:
+ *   business_goal: Let teams describe how a tool's payload maps onto the graph in configuration
+ *   domain: inbound
+ */
+
+export type FactValue = string | number | boolean | null;
+
+/** How one sender's payloads become facts. */
+export interface InboundMapping {
+  /** Dot path to the node's id or name, such as `service.name`. */
+  readonly node: string;
+  /** Fact names and the dot paths of their values. */
+  readonly facts: Readonly<Record<string, string>>;
+}
+
+/** What one webhook delivery reported about one node. */
+export interface InboundFact {
+  /** The sender, as named in `serve.webhooks.sources`. */
+  readonly source: string;
+  /** The node's id or name, as the payload has it. */
+  readonly node: string;
+  readonly facts: Readonly<Record<string, FactValue>>;
+  /** ISO 8601 time the webhook arrived. */
+  readonly receivedAt: string;
+  /** SHA-256 of the signed body, which a replayed delivery repeats. */
+  readonly delivery?: string;
+}
+
+/** The latest value of one fact about a node. */
+export interface NodeFact {
+  readonly name: string;
+  readonly value: FactValue;
+  readonly source: string;
+  readonly receivedAt: string;
+}
@@ -0,0 +1,317 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { fetchIncidents, parseIncidents } from '../incident-import.js';
+import { appendIncidents, readIncidentLog } from '../incident-log.js';
+import { buildIncidentReport } from '../incident-report.js';
+import type { Incident } from '../types.js';
+
+const FIREHYDRANT_LIST = {
+  data: [
+    {
+      id: 'fh-1',
+      number: 41,
+      name: 'Checkout errors after deploy',
+      severity: 'SEV1',
+      started_at: '2024-05-02T10:00:00Z',
+      incident_url: 'https://app.firehydrant.io/incidents/fh-1',
+      services: [{ id: 's1', name: 'checkout' }],
+      functionalities: [{ id: 'f1', name: 'payments' }],
+    },
+  ],
+  pagination: { page: 1, next: null },
+};
+
+const INCIDENT_IO_LIST = {
+  incidents: [
+    {
+      id: '01HX',
+      reference: 'INC-142',
+      name: 'Double charges on retry',
+      severity: { name: 'Critical' },
+      created_at: '2024-05-10T08:00:00Z',
+      permalink: 'https://app.incident.io/acme/incidents/142',
+      custom_field_entries: [
+        {
+          custom_field: { name: 'Affected services' },
+          values: [
+            { value_catalog_entry: { name: 'payments' } },
+            { value_option: { value: 'ledger' } },
+          ],
+        },
+        {
+          custom_field: { name: 'Customer impact' },
+          values: [{ value_text: 'checkout' }],
+        },
+      ],
+    },
+  ],
+  pagination_meta: { page_size: 100 },
+};
+
+function makeEntity(
+  name: string,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `The ${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'platform',
+    status: 'stable',
+    metadata: { type: 'service', description: `The ${name} service` },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00.000Z',
+    updatedAt: '2024-01-01T00:00:00.000Z',
+    ...overrides,
+  };
+}
+
+function makeNode(entity: StoredEntity): GraphNode {
+  return {
+    id: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    external: false,
+    filePath: entity.filePath,
+    owner: entity.owner,
+    domain: null,
+    workspace: null,
+  };
+}
+
+function incident(
+  id: string,
+  services: readonly string[],
+  startedAt: string,
+  url: string | null = null,
+): Incident {
+  return {
+    id,
+    source: 'firehydrant',
+    title: `Incident ${id}`,
+    severity: null,
+    startedAt,
+    url,
+    services,
+  };
+}
+
+describe('parseIncidents', () => {
+  it('reads FireHydrant incident lists', () => {
+    expect(parseIncidents(JSON.stringify(FIREHYDRANT_LIST))).toEqual([
+      {
+        id: 'fh-1',
+        source: 'firehydrant',
+        title: 'Checkout errors after deploy',
+        severity: 'SEV1',
+        startedAt: '2024-05-02T10:00:00.000Z',
+        url: 'https://app.firehydrant.io/incidents/fh-1',
+        services: ['checkout', 'payments'],
+      },
+    ]);
+  });
+
+  it('reads incident.io incident lists from service fields', () => {
+    const [parsed] = parseIncidents(JSON.stringify(INCIDENT_IO_LIST));
+    expect(parsed).toEqual({
+      id: '01HX',
+      source: 'incidentio',
+      title: 'INC-142 Double charges on retry',
+      severity: 'Critical',
+      startedAt: '2024-05-10T08:00:00.000Z',
+      url: 'https://app.incident.io/acme/incidents/142',
+      services: ['payments', 'ledger'],
+    });
+    const [custom] = parseIncidents(
+      JSON.stringify(INCIDENT_IO_LIST),
+      'incidentio',
+      ['Customer impact'],
+    );
+    expect(custom.services).toEqual(['checkout']);
+  });
+
+  it('rejects exports it cannot read', () => {
+    expect(() => parseIncidents('{"items": []}')).toThrow(
+      'Unrecognized incident export',
+    );
+    expect(() => parseIncidents('not json')).toThrow('not valid JSON');
+    expect(() =>
+      parseIncidents(JSON.stringify({ data: [{ id: 'fh-2' }] })),
+    ).toThrow('FireHydrant incident fh-2 has no valid start time');
+  });
+});
+
+describe('fetchIncidents', () => {
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('follows FireHydrant pages', async () => {
+    const second = {
+      data: [{ ...FIREHYDRANT_LIST.data[0], id: 'fh-0' }],
+      pagination: { page: 2, next: null },
+    };
+    const first = { ...FIREHYDRANT_LIST, pagination: { page: 1, next: 2 } };
+    const fetchMock = vi
+      .fn()
+      .mockResolvedValueOnce(new Response(JSON.stringify(first)))
+      .mockResolvedValueOnce(new Response(JSON.stringify(second)));
+    vi.stubGlobal('fetch', fetchMock);
+
+    const incidents = await fetchIncidents('firehydrant', { token: 'fh' });
+    expect(incidents.map((i) => i.id)).toEqual(['fh-1', 'fh-0']);
+    expect(fetchMock.mock.calls[1][0]).toBe(
+      'https://api.firehydrant.io/v1/incidents?page=2&per_page=100',
+    );
+    expect(fetchMock.mock.calls[0][1].headers.Authorization).toBe('Bearer fh');
+  });
+
+  it('follows incident.io cursors and keeps recent incidents', async () => {
+    const first = {
+      ...INCIDENT_IO_LIST,
+      pagination_meta: { after: '01HX', page_size: 100 },
+    };
+    const old = {
+      incidents: [
+        { ...INCIDENT_IO_LIST.incidents[0], created_at: '2023-01-01T00:00Z' },
+      ],
+      pagination_meta: { page_size: 100 },
+    };
+    const fetchMock = vi
+      .fn()
+      .mockResolvedValueOnce(new Response(JSON.stringify(first)))
+      .mockResolvedValueOnce(new Response(JSON.stringify(old)));
+    vi.stubGlobal('fetch', fetchMock);
+
+    const incidents = await fetchIncidents('incidentio', {
+      token: 'io',
+      since: '2024-01-01T00:00:00.000Z',
+    });
+    expect(incidents).toHaveLength(1);
+    expect(fetchMock.mock.calls[1][0]).toBe(
+      'https://api.incident.io/v2/incidents?page_size=100&after=01HX',
+    );
+  });
+
+  it('throws an I/O error on a failed request', async () => {
+    vi.stubGlobal(
+      'fetch',
+      vi.fn().mockResolvedValue(
+        new Response('{}', { status: 401, statusText: 'Unauthorized' }),
+      ),
+    );
+    await expect(
+      fetchIncidents('incidentio', { token: 'bad' }),
+    ).rejects.toMatchObject({
+      kind: 'io',
+      message: 'incident.io API error: 401 Unauthorized',
+    });
+  });
+});
+
+describe('incident log', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-incidents-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('keeps the last import of each incident', () => {
+    const path = join(dir, 'nested', 'incidents.jsonl');
+    expect(readIncidentLog(path)).toEqual([]);
+    const first = incident('a', ['checkout'], '2024-05-01T00:00:00.000Z');
+    const second = incident('b', ['ledger'], '2024-05-02T00:00:00.000Z');
+    appendIncidents(path, [first, second]);
+    appendIncidents(path, [{ ...first, severity: 'SEV2' }]);
+    expect(readIncidentLog(path)).toEqual([
+      second,
+      { ...first, severity: 'SEV2' },
+    ]);
+  });
+});
+
+describe('buildIncidentReport', () => {
+  const checkout = makeEntity('checkout', {
+    metadata: {
+      type: 'service',
+      description: 'Checkout',
+      context: { revenue_impact: 'critical' },
+    },
+  });
+  const search = makeEntity('search', {
+    links: [
+      {
+        type: 'incident',
+        url: 'https://app.incident.io/acme/incidents/7',
+        title: 'INC-7 Search outage',
+      },
+      { type: 'incident', url: 'https://fh/incidents/c' },
+    ],
+  });
+  const ledger = makeEntity('ledger', {
+    metadata: {
+      type: 'service',
+      description: 'Ledger',
+      context: { revenue_impact: 'none' },
+    },
+  });
+  const entities = [checkout, search, ledger];
+  const graph: DependencyGraph = {
+    nodes: entities.map(makeNode),
+    edges: [],
+  };
+  const incidents = [
+    incident('a', ['checkout'], '2024-05-01T00:00:00.000Z'),
+    incident('b', ['Checkout', 'ledger'], '2024-05-03T00:00:00.000Z'),
+    incident('c', [], '2024-04-01T00:00:00.000Z', 'https://fh/incidents/c'),
+    incident('d', ['billing'], '2024-05-04T00:00:00.000Z'),
+  ];
+
+  it('ranks nodes by incident count times revenue impact', () => {
+    const report = buildIncidentReport(entities, graph, incidents);
+    expect(
+      report.rankings.map((r) => [r.name, r.incidents.length, r.score]),
+    ).toEqual([
+      ['checkout', 2, 10],
+      ['search', 2, 2],
+      ['ledger', 1, 0.5],
+    ]);
+    expect(report.rankings[0].incidents.map((i) => i.id)).toEqual(['b', 'a']);
+    expect(report.rankings[1].incidents.map((i) => i.id)).toEqual([
+      'c',
+      'https://app.incident.io/acme/incidents/7',
+    ]);
+    expect(report.unmatched.map((i) => i.id)).toEqual(['d']);
+  });
+
+  it('limits the report to a window and applies custom weights', () => {
+    const report = buildIncidentReport(entities, graph, incidents, {
+      since: '2024-05-02T00:00:00.000Z',
+      weights: { none: 4 },
+    });
+    expect(
+      report.rankings.map((r) => [r.name, r.incidents.length, r.score]),
+    ).toEqual([
+      ['checkout', 1, 5],
+      ['ledger', 1, 4],
+    ]);
+  });
+});
@@ -0,0 +1,233 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads incidents and their affected services from the FireHydrant and incident.io APIs or saved responses
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [incidents, firehydrant, incident-io, import, api]
+ * context:
+ *   business_goal: Bring incident history into the graph without re-typing it into annotations
+ *   domain: incidents
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type {
+  Incident,
+  IncidentFetchOptions,
+  IncidentSource,
+} from './types.js';
+
+type Json = Record<string, unknown>;
+
+const FIREHYDRANT_API = 'https://api.firehydrant.io';
+const INCIDENT_IO_API = 'https://api.incident.io';
+const PAGE_SIZE = 100;
+
+export const DEFAULT_SERVICE_FIELDS: readonly string[] = [
+  'affected services',
+  'services',
+  'service',
+];
+
+const SOURCE_LABELS: Readonly<Record<IncidentSource, string>> = {
+  firehydrant: 'FireHydrant',
+  incidentio: 'incident.io',
+};
+
+function object(value: unknown): Json {
+  return typeof value === 'object' && value !== null && !Array.isArray(value)
+    ? (value as Json)
+    : {};
+}
+
+function array(value: unknown): readonly unknown[] {
+  return Array.isArray(value) ? value : [];
+}
+
+function text(value: unknown): string | undefined {
+  if (typeof value === 'number') return String(value);
+  return typeof value === 'string' && value.trim() !== ''
+    ? value.trim()
+    : undefined;
+}
+
+function names(entries: readonly unknown[]): string[] {
+  return entries
+    .map((entry) => text(object(entry).name))
+    .filter((name): name is string => name !== undefined);
+}
+
+function startTime(source: IncidentSource, id: string, value: unknown): string {
+  const time = Date.parse(text(value) ?? '');
+  if (Number.isNaN(time)) {
+    throw createKnowgraphError(
+      'parse',
+      `${SOURCE_LABELS[source]} incident ${id} has no valid start time`,
+    );
+  }
+  return new Date(time).toISOString();
+}
+
+/** A FireHydrant incident, affecting its services and functionalities. */
+function fireHydrantIncident(raw: Json): Incident {
+  const id = text(raw.id) ?? text(raw.number);
+  if (!id) {
+    throw createKnowgraphError('parse', 'FireHydrant incident has no id');
+  }
+  return {
+    id,
+    source: 'firehydrant',
+    title: text(raw.name) ?? id,
+    severity: text(raw.severity) ?? null,
+    startedAt: startTime('firehydrant', id, raw.started_at ?? raw.created_at),
+    url: text(raw.incident_url) ?? null,
+    services: [
+      ...new Set([
+        ...names(array(raw.services)),
+        ...names(array(raw.functionalities)),
+      ]),
+    ],
+  };
+}
+
+/**
+ * An incident.io incident, affecting the catalog entries, options, or text
+ * in its custom fields named like one of `serviceFields`.
+ */
+function incidentIoIncident(
+  raw: Json,
+  serviceFields: readonly string[],
+): Incident {
+  const id = text(raw.id);
+  if (!id) {
+    throw createKnowgraphError('parse', 'incident.io incident has no id');
+  }
+  const fields = new Set(serviceFields.map((field) => field.toLowerCase()));
+  const services: string[] = [];
+  for (const entry of array(raw.custom_field_entries)) {
+    const field = text(object(object(entry).custom_field).name);
+    if (!field || !fields.has(field.toLowerCase())) continue;
+    for (const value of array(object(entry).values)) {
+      const name =
+        text(object(object(value).value_catalog_entry).name) ??
+        text(object(object(value).value_option).value) ??
+        text(object(value).value_text);
+      if (name && !services.includes(name)) services.push(name);
+    }
+  }
+  const reference = text(raw.reference);
+  const name = text(raw.name) ?? id;
+  return {
+    id,
+    source: 'incidentio',
+    title: reference ? `${reference} ${name}` : name,
+    severity: text(object(raw.severity).name) ?? null,
+    startedAt: startTime('incidentio', id, raw.created_at),
+    url: text(raw.permalink) ?? null,
+    services,
+  };
+}
+
+function incidentsIn(
+  body: Json,
+  source: IncidentSource,
+  serviceFields: readonly string[],
+): readonly Incident[] {
+  return source === 'firehydrant'
+    ? array(body.data).map((raw) => fireHydrantIncident(object(raw)))
+    : array(body.incidents).map((raw) =>
+        incidentIoIncident(object(raw), serviceFields),
+      );
+}
+
+/**
+ * Parse a saved incident list response: FireHydrant's `GET /v1/incidents`
+ * (incidents under `data`) or incident.io's `GET /v2/incidents` (under
+ * `incidents`). The source is detected when not given.
+ */
+export function parseIncidents(
+  input: string,
+  source?: IncidentSource,
+  serviceFields: readonly string[] = DEFAULT_SERVICE_FIELDS,
+): readonly Incident[] {
+  let body: Json;
+  try {
+    body = object(JSON.parse(input));
+  } catch {
+    throw createKnowgraphError('parse', 'Incident export is not valid JSON');
+  }
+  const detected =
+    source ??
+    (Array.isArray(body.incidents)
+      ? 'incidentio'
+      : Array.isArray(body.data)
+        ? 'firehydrant'
+        : undefined);
+  if (!detected) {
+    throw createKnowgraphError(
+      'parse',
+      'Unrecognized incident export: expected a FireHydrant or incident.io incident list',
+    );
+  }
+  return incidentsIn(body, detected, serviceFields);
+}
+
+async function getPage(
+  source: IncidentSource,
+  url: string,
+  token: string,
+): Promise<Json> {
+  const response = await fetch(url, {
+    headers: { Authorization: `Bearer ${token}`, Accept: 'application/json' },
+  });
+  if (!response.ok) {
+    throw createKnowgraphError(
+      'io',
+      `${SOURCE_LABELS[source]} API error: ${response.status} ${response.statusText}`,
+    );
+  }
+  return object(await response.json());
+}
+
+/**
+ * Fetch every incident from the FireHydrant or incident.io API, following
+ * pagination, and keep those started at or after `since`.
+ */
+export async function fetchIncidents(
+  source: IncidentSource,
+  options: IncidentFetchOptions,
+): Promise<readonly Incident[]> {
+  const serviceFields = options.serviceFields ?? DEFAULT_SERVICE_FIELDS;
+  const incidents: Incident[] = [];
+  if (source === 'firehydrant') {
+    const base = (options.baseUrl ?? FIREHYDRANT_API).replace(/\/$/, '');
+    for (let page = 1; ; page++) {
+      const body = await getPage(
+        source,
+        `${base}/v1/incidents?page=${page}&per_page=${PAGE_SIZE}`,
+        options.token,
+      );
+      const batch = incidentsIn(body, source, serviceFields);
+      incidents.push(...batch);
+      if (batch.length === 0 || !object(body.pagination).next) break;
+    }
+  } else {
+    const base = (options.baseUrl ?? INCIDENT_IO_API).replace(/\/$/, '');
+    let after: string | undefined;
+    do {
+      const cursor = after ? `&after=${encodeURIComponent(after)}` : '';
+      const body = await getPage(
+        source,
+        `${base}/v2/incidents?page_size=${PAGE_SIZE}${cursor}`,
+        options.token,
+      );
+      const batch = incidentsIn(body, source, serviceFields);
+      incidents.push(...batch);
+      after =
+        batch.length > 0 ? text(object(body.pagination_meta).after) : undefined;
+    } while (after);
+  }
+  const since = options.since;
+  return since
+    ? incidents.filter((incident) => incident.startedAt >= since)
+    : incidents;
+}
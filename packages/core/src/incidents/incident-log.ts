@@ -0,0 +1,54 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads and appends the JSON Lines incident log, keeping the latest copy of each incident
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [incidents, jsonl, log]
+ * context:
+ *   business_goal: Let nodes accumulate incident history across imports
+ *   domain: incidents
+ */
+import { appendFileSync, existsSync, mkdirSync, readFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { Incident } from './types.js';
+
+/**
+ * Parse the incident log at `logPath`. A missing file is empty. An incident
+ * imported more than once appears once, as last imported, so re-importing
+ * picks up renamed titles, new severities, and added services. Throws on a
+ * line that is not JSON, with its line number.
+ */
+export function readIncidentLog(logPath: string): readonly Incident[] {
+  if (!existsSync(logPath)) return [];
+  const latest = new Map<string, Incident>();
+  const lines = readFileSync(logPath, 'utf-8').split('\n');
+  lines.forEach((line, index) => {
+    if (line.trim() === '') return;
+    let incident: Incident;
+    try {
+      incident = JSON.parse(line) as Incident;
+    } catch {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid incident log entry at ${logPath}:${index + 1}`,
+      );
+    }
+    const key = `${incident.source}\0${incident.id}`;
+    latest.delete(key);
+    latest.set(key, incident);
+  });
+  return [...latest.values()];
+}
+
+/** Append `incidents` to the log, creating the file when needed. */
+export function appendIncidents(
+  logPath: string,
+  incidents: readonly Incident[],
+): void {
+  if (incidents.length === 0) return;
+  mkdirSync(dirname(logPath), { recursive: true });
+  const lines = incidents.map((incident) => `${JSON.stringify(incident)}\n`);
+  appendFileSync(logPath, lines.join(''), 'utf-8');
+}
@@ -0,0 +1,142 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Ranks nodes by how often incidents hit them, weighted by their revenue impact
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [incidents, report, revenue, reliability]
+ * context:
+ *   business_goal: Point reliability work at the code whose incidents cost the business most
+ *   domain: incidents
+ */
+import { createServiceMatcher } from '../graph/graph-services.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { ExtendedMetadata, RevenueImpact } from '../types/entity.js';
+import type {
+  Incident,
+  IncidentRanking,
+  IncidentReport,
+  IncidentReportOptions,
+  NodeIncident,
+} from './types.js';
+
+export const DEFAULT_INCIDENT_WEIGHTS: Readonly<
+  Record<RevenueImpact | 'unset', number>
+> = {
+  critical: 5,
+  high: 3,
+  medium: 2,
+  low: 1,
+  none: 0.5,
+  unset: 1,
+};
+
+function toNodeIncident(incident: Incident): NodeIncident {
+  return {
+    id: incident.id,
+    title: incident.title,
+    severity: incident.severity,
+    startedAt: incident.startedAt,
+    url: incident.url,
+  };
+}
+
+/**
+ * Count the incidents that hit each entity and rank entities by that count
+ * times the weight of their `context.revenue_impact`. An imported incident
+ * hits the nodes of each affected service (see `createServiceMatcher`); an
+ * entity's `incident` links count too, as the imported incident with the
+ * same URL when there is one. With `since`, links to incidents that were
+ * never imported have no start time and are left out.
+ */
+export function buildIncidentReport(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  incidents: readonly Incident[],
+  options: IncidentReportOptions = {},
+): IncidentReport {
+  const weights = { ...DEFAULT_INCIDENT_WEIGHTS, ...options.weights };
+  const { since } = options;
+  const byId = new Map(entities.map((entity) => [entity.id, entity]));
+  const servedBy = createServiceMatcher(
+    graph.nodes.filter((node) => byId.has(node.id)),
+  );
+  const inWindow = incidents.filter(
+    (incident) => !since || incident.startedAt >= since,
+  );
+  const byUrl = new Map(
+    incidents
+      .filter((incident) => incident.url)
+      .map((incident) => [incident.url as string, incident]),
+  );
+
+  const hits = new Map<string, Map<string, NodeIncident>>();
+  const matched = new Set<Incident>();
+  const hit = (entityId: string, key: string, incident: NodeIncident) => {
+    const entry = hits.get(entityId) ?? new Map<string, NodeIncident>();
+    if (!entry.has(key)) entry.set(key, incident);
+    hits.set(entityId, entry);
+  };
+
+  for (const incident of inWindow) {
+    for (const service of incident.services) {
+      for (const node of servedBy(service)) {
+        const key = incident.url ?? `${incident.source}:${incident.id}`;
+        hit(node.id, key, toNodeIncident(incident));
+        matched.add(incident);
+      }
+    }
+  }
+  for (const entity of entities) {
+    for (const link of entity.links) {
+      if (link.type !== 'incident') continue;
+      const imported = byUrl.get(link.url);
+      if (imported) {
+        if (since && imported.startedAt < since) continue;
+        hit(entity.id, link.url, toNodeIncident(imported));
+        matched.add(imported);
+      } else if (!since) {
+        hit(entity.id, link.url, {
+          id: link.url,
+          title: link.title ?? link.url,
+          severity: null,
+          startedAt: null,
+          url: link.url,
+        });
+      }
+    }
+  }
+
+  const rankings: IncidentRanking[] = [];
+  for (const [entityId, entry] of hits) {
+    const entity = byId.get(entityId)!;
+    const metadata = entity.metadata as ExtendedMetadata;
+    const revenueImpact = metadata.context?.revenue_impact ?? null;
+    const weight = weights[revenueImpact ?? 'unset'];
+    const nodeIncidents = [...entry.values()].sort((a, b) =>
+      (b.startedAt ?? '').localeCompare(a.startedAt ?? ''),
+    );
+    rankings.push({
+      entityId,
+      name: entity.name,
+      entityType: entity.entityType,
+      filePath: entity.filePath,
+      owner: entity.owner,
+      revenueImpact,
+      incidents: nodeIncidents,
+      weight,
+      score: Math.round(nodeIncidents.length * weight * 100) / 100,
+    });
+  }
+  rankings.sort(
+    (a, b) =>
+      b.score - a.score ||
+      b.incidents.length - a.incidents.length ||
+      a.name.localeCompare(b.name),
+  );
+  return {
+    rankings,
+    unmatched: inWindow.filter((incident) => !matched.has(incident)),
+  };
+}
@@ -0,0 +1,19 @@
+export type {
+  IncidentSource,
+  Incident,
+  NodeIncident,
+  IncidentRanking,
+  IncidentReport,
+  IncidentReportOptions,
+  IncidentFetchOptions,
+} from './types.js';
+export {
+  DEFAULT_SERVICE_FIELDS,
+  fetchIncidents,
+  parseIncidents,
+} from './incident-import.js';
+export { appendIncidents, readIncidentLog } from './incident-log.js';
+export {
+  DEFAULT_INCIDENT_WEIGHTS,
+  buildIncidentReport,
+} from './incident-report.js';
@@ -0,0 +1,71 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for incidents imported from incident tools and the report ranking nodes by incident cost
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [incidents, firehydrant, incident-io, types, interface]
+ * context:
+ *   business_goal: Define contracts for tying incident history to the code it hit
+ *   domain: incidents
+ */
+import type { EntityType, RevenueImpact } from '../types/entity.js';
+
+export type IncidentSource = 'firehydrant' | 'incidentio';
+
+/** One incident, with the services it affected by name. */
+export interface Incident {
+  readonly id: string;
+  readonly source: IncidentSource;
+  readonly title: string;
+  readonly severity: string | null;
+  /** ISO 8601 time the incident started. */
+  readonly startedAt: string;
+  readonly url: string | null;
+  readonly services: readonly string[];
+}
+
+/** An incident as it counts against one node. */
+export interface NodeIncident {
+  readonly id: string;
+  readonly title: string;
+  readonly severity: string | null;
+  /** Null for an `incident` link annotated without a matching import. */
+  readonly startedAt: string | null;
+  readonly url: string | null;
+}
+
+export interface IncidentRanking {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly owner: string | null;
+  readonly revenueImpact: RevenueImpact | null;
+  readonly incidents: readonly NodeIncident[];
+  /** The revenue impact weight the incident count is multiplied by. */
+  readonly weight: number;
+  readonly score: number;
+}
+
+export interface IncidentReport {
+  readonly rankings: readonly IncidentRanking[];
+  /** Imported incidents in the window that matched no node. */
+  readonly unmatched: readonly Incident[];
+}
+
+export interface IncidentReportOptions {
+  /** Only incidents started at or after this ISO 8601 time. */
+  readonly since?: string;
+  /** Weight per revenue impact; `unset` applies to nodes without one. */
+  readonly weights?: Partial<Record<RevenueImpact | 'unset', number>>;
+}
+
+export interface IncidentFetchOptions {
+  readonly token: string;
+  /** Only incidents started at or after this ISO 8601 time. */
+  readonly since?: string;
+  readonly baseUrl?: string;
+  /** incident.io custom fields holding affected services, by name. */
+  readonly serviceFields?: readonly string[];
+}
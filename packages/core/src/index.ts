@@ -7,11 +7,14 @@ export type {
   ParserRegistry as ParserRegistryInterface,
 } from './parsers/types.js';
 export {
+  extractKnowgraphBlock,
   extractKnowgraphYaml,
   parseAndValidateMetadata,
   extractMetadata,
 } from './parsers/metadata-extractor.js';
 export type {
+  AnnotationFormat,
+  KnowgraphBlock,
   ExtractionError,
   ExtractionResult,
 } from './parsers/metadata-extractor.js';
@@ -20,11 +23,109 @@ export { createTypescriptParser } from './parsers/typescript-parser.js';
 export { createGenericParser } from './parsers/generic-parser.js';
 export { createGoParser } from './parsers/go-parser.js';
 export { createJavaParser } from './parsers/java-parser.js';
+export {
+  createTreeSitterParser,
+  loadTreeSitterGrammar,
+} from './parsers/tree-sitter-parser.js';
+export type {
+  TreeSitterGrammar,
+  TreeSitterGrammarConfig,
+  TreeSitterNode,
+  TreeSitterPoint,
+  TreeSitterTree,
+} from './parsers/tree-sitter-parser.js';
 export { createDefaultRegistry } from './parsers/registry.js';
 
 export * from './indexer/index.js';
+export * from './paths/index.js';
+export * from './encryption/index.js';
+export * from './fsck/index.js';
 export * from './query/index.js';
 export * from './validation/index.js';
 export * from './coverage/index.js';
 export * from './suggest/index.js';
 export * from './connectors/index.js';
+export * from './reliability/index.js';
+export * from './finops/index.js';
+export * from './linkcheck/index.js';
+export * from './decisions/index.js';
+export * from './glossary/index.js';
+export * from './graph/index.js';
+export * from './duplicates/index.js';
+export * from './rewriter/index.js';
+export * from './lint/index.js';
+export * from './i18n/index.js';
+export * from './generated/index.js';
+export * from './workspace/index.js';
+export * from './bazel/index.js';
+export * from './golang/index.js';
+export * from './library/index.js';
+export * from './cancellation/index.js';
+export * from './canonical/index.js';
+export * from './cache/index.js';
+export * from './streaming/index.js';
+export * from './profiling/index.js';
+export * from './snapshot/index.js';
+export * from './redaction/index.js';
+export * from './audit/index.js';
+export * from './locking/index.js';
+export * from './plugins/index.js';
+export * from './exporters/index.js';
+export * from './enrichers/index.js';
+export * from './errors/index.js';
+export * from './explain/index.js';
+export * from './onboarding/index.js';
+export * from './ask/index.js';
+export * from './history/index.js';
+export * from './delivery/index.js';
+export * from './busfactor/index.js';
+export * from './schedule/index.js';
+export * from './remote/index.js';
+export * from './scope/index.js';
+export * from './annotations/index.js';
+export * from './namespace/index.js';
+export * from './patch/index.js';
+export * from './registry/index.js';
+export * from './kubernetes/index.js';
+export * from './runtimeconfig/index.js';
+export * from './changecost/index.js';
+export * from './refactor/index.js';
+export * from './release/index.js';
+export * from './licensing/index.js';
+export * from './scorecard/index.js';
+export * from './report/index.js';
+export * from './parquet/index.js';
+export * from './warehouse/index.js';
+export * from './deployments/index.js';
+export * from './incidents/index.js';
+export * from './doctor/index.js';
+export * from './logging/index.js';
+export * from './telemetry/index.js';
+export * from './usage/index.js';
+export * from './bench/index.js';
+export * from './draft/index.js';
+export * from './review/index.js';
+export * from './diagrams/index.js';
+export * from './dependents/index.js';
+export * from './simulation/index.js';
+export * from './versions/index.js';
+export * from './contracts/index.js';
+export * from './codegen/index.js';
+export * from './scaffold/index.js';
+export * from './docsgen/index.js';
+export * from './confluence/index.js';
+export * from './notion/index.js';
+export * from './inbound/index.js';
+export * from './bulkedit/index.js';
+export * from './configlint/index.js';
+export * from './views/index.js';
+export * from './chunking/index.js';
+export * from './vacancy/index.js';
+export * from './freshness/index.js';
+export * from './resolve/index.js';
+export * from './bundle/index.js';
+export * from './codesearch/index.js';
+export * from './maturity/index.js';
+export * from './hotspots/index.js';
+export * from './conformance/index.js';
+export * from './credentials/index.js';
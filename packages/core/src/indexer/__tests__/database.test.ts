@@ -1,7 +1,12 @@
 import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { randomBytes } from 'node:crypto';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
 import { createDatabaseManager, generateEntityId } from '../database.js';
+import { isEncryptedFile } from '../../encryption/encryption.js';
 import type { DatabaseManager } from '../database.js';
-import type { EntityInsert } from '../types.js';
+import type { EntityInsert, Tombstone } from '../types.js';
 import type { CoreMetadata, Link } from '../../types/index.js';
 
 function makeEntity(overrides: Partial<EntityInsert> = {}): EntityInsert {
@@ -380,4 +385,211 @@ describe('DatabaseManager', () => {
       expect(dbManager.getFileHash('nonexistent.ts')).toBeUndefined();
     });
   });
+
+  describe('getFilePaths', () => {
+    it('lists each indexed file once, in path order', () => {
+      dbManager.insertEntity(makeEntity({ filePath: 'src/b.ts' }));
+      dbManager.insertEntity(makeEntity({ filePath: 'src/a.ts' }));
+      dbManager.insertEntity(makeEntity({ filePath: 'src/b.ts', name: 'b2' }));
+      expect(dbManager.getFilePaths()).toEqual(['src/a.ts', 'src/b.ts']);
+    });
+  });
+
+  describe('recordRename / resolveEntityId', () => {
+    it('resolves old ids to the current one in one step', () => {
+      const id = dbManager.insertEntity(makeEntity({ name: 'c' }));
+      dbManager.recordRename({ from: 'a', to: 'b', reason: 'position' });
+      dbManager.recordRename({ from: 'b', to: id, reason: 'git' });
+      expect(dbManager.resolveEntityId('a')).toBe(id);
+      expect(dbManager.resolveEntityId('b')).toBe(id);
+      expect(dbManager.resolveEntityId(id)).toBe(id);
+      expect(dbManager.getEntityById('a')?.name).toBe('c');
+    });
+
+    it('stops redirecting an id that is in use again', () => {
+      dbManager.recordRename({ from: 'a', to: 'b', reason: 'position' });
+      dbManager.recordRename({ from: 'b', to: 'a', reason: 'position' });
+      expect(dbManager.resolveEntityId('a')).toBe('a');
+      expect(dbManager.resolveEntityId('b')).toBe('a');
+    });
+  });
+
+  describe('recordFileLineage / getFileLineage', () => {
+    it('lists each old path with the paths its code went to', () => {
+      dbManager.recordFileLineage('src/pay.ts', 'src/charge.ts');
+      dbManager.recordFileLineage('src/pay.ts', 'src/refund.ts');
+      dbManager.recordFileLineage('src/pay.ts', 'src/charge.ts');
+      dbManager.recordFileLineage('src/util.ts', 'src/util.ts');
+      expect(dbManager.getFileLineage()).toEqual({
+        'src/pay.ts': ['src/charge.ts', 'src/refund.ts'],
+      });
+    });
+  });
+
+  describe('recordTombstone / getTombstones / pruneTombstones', () => {
+    function tombstone(id: string, removedAt: string): Tombstone {
+      return {
+        id,
+        name: id,
+        entityType: 'function',
+        filePath: 'src/pay.ts',
+        owner: 'payments-team',
+        reason: 'removed',
+        removedAt,
+      };
+    }
+
+    it('lists tombstones since a time and prunes old ones', () => {
+      dbManager.recordTombstone(tombstone('b', '2025-03-01T00:00:00.000Z'));
+      dbManager.recordTombstone(tombstone('a', '2025-01-01T00:00:00.000Z'));
+      expect(dbManager.getTombstones().map((t) => t.id)).toEqual(['a', 'b']);
+      expect(dbManager.getTombstones('2025-02-01T00:00:00.000Z')).toEqual([
+        tombstone('b', '2025-03-01T00:00:00.000Z'),
+      ]);
+      expect(dbManager.pruneTombstones('2025-02-01T00:00:00.000Z')).toBe(1);
+      expect(dbManager.getTombstones().map((t) => t.id)).toEqual(['b']);
+    });
+
+    it('drops the tombstone of an entity inserted again', () => {
+      const id = dbManager.insertEntity(makeEntity());
+      dbManager.deleteEntitiesByFilePath('src/auth/login.ts');
+      dbManager.recordTombstone(tombstone(id, '2025-01-01T00:00:00.000Z'));
+      dbManager.insertEntity(makeEntity());
+      expect(dbManager.getTombstones()).toEqual([]);
+    });
+  });
+
+  describe('getMeta / setMeta', () => {
+    it('stores and overwrites values', () => {
+      expect(dbManager.getMeta('config_hash')).toBeUndefined();
+      dbManager.setMeta('config_hash', 'a');
+      dbManager.setMeta('config_hash', 'b');
+      expect(dbManager.getMeta('config_hash')).toBe('b');
+    });
+  });
+
+  describe('snapshot', () => {
+    it('reads one committed state while another connection writes', () => {
+      const dir = mkdtempSync(join(tmpdir(), 'knowgraph-snapshot-'));
+      const reader = createDatabaseManager(join(dir, 'knowgraph.db'));
+      reader.initialize();
+      const writer = createDatabaseManager(join(dir, 'knowgraph.db'));
+      try {
+        const counts = reader.snapshot(() => {
+          const before = reader.getStats().totalEntities;
+          writer.insertEntity(makeEntity());
+          return [before, reader.getStats().totalEntities];
+        });
+        expect(counts).toEqual([0, 0]);
+        expect(reader.getStats().totalEntities).toBe(1);
+      } finally {
+        writer.close();
+        reader.close();
+        rmSync(dir, { recursive: true, force: true });
+      }
+    });
+  });
+
+  describe('encryption at rest', () => {
+    const key = randomBytes(32);
+
+    it('writes only ciphertext and reads it back with the key', () => {
+      const dir = mkdtempSync(join(tmpdir(), 'knowgraph-encrypted-'));
+      const dbPath = join(dir, 'knowgraph.db');
+      try {
+        const writer = createDatabaseManager(dbPath, { encryptionKey: key });
+        writer.initialize();
+        writer.insertEntity(makeEntity());
+        writer.close();
+
+        expect(isEncryptedFile(dbPath)).toBe(true);
+        expect(readFileSync(dbPath).includes('authenticate')).toBe(false);
+
+        const reader = createDatabaseManager(dbPath, { encryptionKey: key });
+        expect(reader.getStats().totalEntities).toBe(1);
+        reader.close();
+
+        expect(() => createDatabaseManager(dbPath)).toThrow(/is encrypted/);
+        expect(() =>
+          createDatabaseManager(dbPath, { encryptionKey: randomBytes(32) }),
+        ).toThrow(/Cannot decrypt/);
+      } finally {
+        rmSync(dir, { recursive: true, force: true });
+      }
+    });
+
+    it('encrypts an existing plain store and drops its journal', () => {
+      const dir = mkdtempSync(join(tmpdir(), 'knowgraph-encrypted-'));
+      const dbPath = join(dir, 'knowgraph.db');
+      try {
+        const plain = createDatabaseManager(dbPath);
+        plain.initialize();
+        plain.insertEntity(makeEntity());
+        plain.close();
+        expect(isEncryptedFile(dbPath)).toBe(false);
+
+        createDatabaseManager(dbPath, { encryptionKey: key }).close();
+
+        expect(isEncryptedFile(dbPath)).toBe(true);
+        expect(existsSync(`${dbPath}-wal`)).toBe(false);
+        const reader = createDatabaseManager(dbPath, { encryptionKey: key });
+        expect(reader.getStats().totalEntities).toBe(1);
+        reader.close();
+      } finally {
+        rmSync(dir, { recursive: true, force: true });
+      }
+    });
+
+    it('locks the store while it is open for writing', () => {
+      const dir = mkdtempSync(join(tmpdir(), 'knowgraph-encrypted-'));
+      const dbPath = join(dir, 'knowgraph.db');
+      try {
+        const writer = createDatabaseManager(dbPath, { encryptionKey: key });
+        writer.initialize();
+        expect(existsSync(`${dbPath}.lock`)).toBe(true);
+        writer.close();
+        expect(existsSync(`${dbPath}.lock`)).toBe(false);
+
+        // Readers neither wait for the lock nor write back
+        const reader = createDatabaseManager(dbPath, {
+          encryptionKey: key,
+          readOnly: true,
+        });
+        expect(existsSync(`${dbPath}.lock`)).toBe(false);
+        reader.insertEntity(makeEntity());
+        reader.close();
+        const after = createDatabaseManager(dbPath, {
+          encryptionKey: key,
+          readOnly: true,
+        });
+        expect(after.getStats().totalEntities).toBe(0);
+        after.close();
+      } finally {
+        rmSync(dir, { recursive: true, force: true });
+      }
+    });
+
+    it('writes back a run that only changed the schema', () => {
+      const dir = mkdtempSync(join(tmpdir(), 'knowgraph-encrypted-'));
+      const dbPath = join(dir, 'knowgraph.db');
+      try {
+        createDatabaseManager(dbPath, { encryptionKey: key }).close();
+        expect(isEncryptedFile(dbPath)).toBe(true);
+
+        const writer = createDatabaseManager(dbPath, { encryptionKey: key });
+        writer.db.exec('CREATE TABLE notes (body TEXT)');
+        writer.close();
+
+        const reader = createDatabaseManager(dbPath, { encryptionKey: key });
+        expect(
+          reader.db
+            .prepare("SELECT name FROM sqlite_master WHERE name = 'notes'")
+            .get(),
+        ).toEqual({ name: 'notes' });
+        reader.close();
+      } finally {
+        rmSync(dir, { recursive: true, force: true });
+      }
+    });
+  });
 });
@@ -6,6 +6,7 @@ import { createDatabaseManager } from '../database.js';
 import type { DatabaseManager } from '../database.js';
 import { createIndexer } from '../indexer.js';
 import type { ParserRegistry } from '../indexer.js';
+import { createPhaseTimer } from '../../profiling/phase-timer.js';
 import type { ParseResult } from '../../types/index.js';
 
 function createTempDir(): string {
@@ -101,6 +102,49 @@ describe('Indexer', () => {
     expect(lastCall.processedFiles).toBe(lastCall.totalFiles);
   });
 
+  it('reports the stored entities of each indexed file', () => {
+    const srcDir = join(tempDir, 'src');
+    mkdirSync(srcDir, { recursive: true });
+    writeFileSync(join(srcDir, 'app.ts'), 'function hello() {}');
+    writeFileSync(join(srcDir, 'empty.ts'), 'const a = 1;');
+
+    const parseResults = new Map<string, readonly ParseResult[]>([
+      ['app.ts', [makeParsedResult({ name: 'hello', filePath: 'src/app.ts' })]],
+    ]);
+    const indexer = createIndexer(
+      createMockParserRegistry(parseResults),
+      dbManager,
+    );
+    const onFileIndexed = vi.fn();
+
+    indexer.index({ rootDir: tempDir, onFileIndexed });
+
+    const calls = new Map(
+      onFileIndexed.mock.calls.map(([filePath, entities]) => [
+        filePath,
+        entities.map((e: { name: string }) => e.name),
+      ]),
+    );
+    expect(calls.get('src/app.ts')).toEqual(['hello']);
+    expect(calls.get('src/empty.ts')).toEqual([]);
+  });
+
+  it('aborts between files when the signal is aborted', () => {
+    const srcDir = join(tempDir, 'src');
+    mkdirSync(srcDir, { recursive: true });
+    writeFileSync(join(srcDir, 'a.ts'), 'const a = 1;');
+    writeFileSync(join(srcDir, 'b.ts'), 'const b = 1;');
+
+    const controller = new AbortController();
+    const indexer = createIndexer(createMockParserRegistry(), dbManager);
+    const onProgress = vi.fn(() => controller.abort());
+
+    expect(() =>
+      indexer.index({ rootDir: tempDir, signal: controller.signal, onProgress }),
+    ).toThrow(expect.objectContaining({ name: 'AbortError' }));
+    expect(onProgress).toHaveBeenCalledTimes(1);
+  });
+
   it('collects errors without throwing', () => {
     const srcDir = join(tempDir, 'src');
     mkdirSync(srcDir, { recursive: true });
@@ -120,6 +164,62 @@ describe('Indexer', () => {
     expect(result.errors[0].message).toContain('Parse failed!');
   });
 
+  it('skips files over the limits and drops what they stored', () => {
+    const srcDir = join(tempDir, 'src');
+    mkdirSync(srcDir, { recursive: true });
+    writeFileSync(join(srcDir, 'app.ts'), 'function hello() {}');
+    writeFileSync(join(srcDir, 'bundle.ts'), 'x'.repeat(50));
+
+    const parseResults = new Map<string, readonly ParseResult[]>([
+      [
+        'bundle.ts',
+        [makeParsedResult({ name: 'bundle', filePath: 'src/bundle.ts' })],
+      ],
+    ]);
+    const indexer = createIndexer(
+      createMockParserRegistry(parseResults),
+      dbManager,
+    );
+    indexer.index({ rootDir: tempDir });
+    expect(dbManager.getEntitiesByFilePath('src/bundle.ts')).toHaveLength(1);
+
+    const result = indexer.index({ rootDir: tempDir, maxLineLength: 20 });
+
+    expect(result.skipped).toEqual([
+      {
+        filePath: 'src/bundle.ts',
+        reason: 'minified',
+        message: 'A 50 character line is over the 20 character limit',
+      },
+    ]);
+    expect(dbManager.getEntitiesByFilePath('src/bundle.ts')).toHaveLength(0);
+  });
+
+  it('remembers slow parses until the file changes', () => {
+    const srcDir = join(tempDir, 'src');
+    mkdirSync(srcDir, { recursive: true });
+    writeFileSync(join(srcDir, 'slow.ts'), 'const a = 1;');
+
+    const registry: ParserRegistry = {
+      canParse: (fp) => fp.endsWith('.ts'),
+      parse: vi.fn(() => {
+        const end = Date.now() + 5;
+        while (Date.now() < end);
+        return [];
+      }),
+    };
+    const indexer = createIndexer(registry, dbManager);
+    const options = { rootDir: tempDir, incremental: true, parseTimeoutMs: 1 };
+
+    expect(indexer.index(options).skipped[0].reason).toBe('slow');
+    expect(indexer.index(options).skipped[0].reason).toBe('slow');
+    expect(registry.parse).toHaveBeenCalledTimes(1);
+
+    writeFileSync(join(srcDir, 'slow.ts'), 'const a = 2;');
+    indexer.index(options);
+    expect(registry.parse).toHaveBeenCalledTimes(2);
+  });
+
   it('skips unchanged files in incremental mode', () => {
     const srcDir = join(tempDir, 'src');
     mkdirSync(srcDir, { recursive: true });
@@ -149,6 +249,105 @@ describe('Indexer', () => {
     expect(entities).toHaveLength(1);
   });
 
+  it('re-parses everything when the config hash changes', () => {
+    const srcDir = join(tempDir, 'src');
+    mkdirSync(srcDir, { recursive: true });
+    writeFileSync(join(srcDir, 'stable.ts'), 'function stable() {}');
+
+    const registry = createMockParserRegistry(
+      new Map([
+        [
+          'stable.ts',
+          [makeParsedResult({ name: 'stable', filePath: 'src/stable.ts' })],
+        ],
+      ]),
+    );
+    const indexer = createIndexer(registry, dbManager);
+    indexer.index({ rootDir: tempDir, incremental: true, configHash: 'a' });
+
+    const parseSpy = vi.spyOn(registry, 'parse');
+    const same = indexer.index({
+      rootDir: tempDir,
+      incremental: true,
+      configHash: 'a',
+    });
+    expect(parseSpy).not.toHaveBeenCalled();
+    expect(same.invalidated).toBe(false);
+
+    const changed = indexer.index({
+      rootDir: tempDir,
+      incremental: true,
+      configHash: 'b',
+    });
+    expect(parseSpy).toHaveBeenCalledTimes(1);
+    expect(changed.invalidated).toBe(true);
+    expect(dbManager.getMeta('config_hash')).toBe('b');
+  });
+
+  it('indexes only the scoped paths and tags, pruning the rest', () => {
+    for (const dir of ['services/auth', 'services/billing']) {
+      mkdirSync(join(tempDir, dir), { recursive: true });
+    }
+    writeFileSync(join(tempDir, 'services/auth/login.ts'), 'login');
+    writeFileSync(join(tempDir, 'services/billing/pay.ts'), 'pay');
+    const tagged = (name: string, tags: string[]): ParseResult =>
+      makeParsedResult({
+        name,
+        metadata: { type: 'function', description: name, tags },
+      });
+    const registry = createMockParserRegistry(
+      new Map([
+        ['login.ts', [tagged('login', ['auth']), tagged('audit', ['audit'])]],
+        ['pay.ts', [tagged('pay', ['payments'])]],
+      ]),
+    );
+    const indexer = createIndexer(registry, dbManager);
+    const names = (): string[] =>
+      dbManager
+        .getFilePaths()
+        .flatMap((file) => dbManager.getEntitiesByFilePath(file))
+        .map((entity) => entity.name)
+        .sort();
+
+    indexer.index({ rootDir: tempDir, incremental: true });
+    expect(names()).toEqual(['audit', 'login', 'pay']);
+
+    const scoped = indexer.index({
+      rootDir: tempDir,
+      incremental: true,
+      scopes: [{ kind: 'path', path: 'services/auth' }],
+    });
+    expect(scoped.totalFiles).toBe(1);
+    expect(scoped.invalidated).toBe(true);
+    expect(names()).toEqual(['audit', 'login']);
+
+    indexer.index({
+      rootDir: tempDir,
+      incremental: true,
+      scopes: [{ kind: 'tag', tag: 'payments' }],
+    });
+    expect(names()).toEqual(['pay']);
+  });
+
+  it('records walk, parse, and bind time with a profiler', () => {
+    const srcDir = join(tempDir, 'src');
+    mkdirSync(srcDir, { recursive: true });
+    writeFileSync(join(srcDir, 'a.ts'), 'function a() {}');
+    writeFileSync(join(srcDir, 'b.ts'), 'function b() {}');
+
+    const profiler = createPhaseTimer();
+    const indexer = createIndexer(createMockParserRegistry(), dbManager);
+    indexer.index({ rootDir: tempDir, profiler });
+
+    expect(
+      profiler.timings().map(({ phase, calls }) => [phase, calls]),
+    ).toEqual([
+      ['walk', 3],
+      ['parse', 2],
+      ['bind', 4],
+    ]);
+  });
+
   it('respects .gitignore patterns', () => {
     mkdirSync(join(tempDir, 'src'), { recursive: true });
     mkdirSync(join(tempDir, 'build'), { recursive: true });
@@ -197,4 +396,293 @@ describe('Indexer', () => {
     const result = indexer.index({ rootDir: tempDir });
     expect(result.totalEntities).toBe(2);
   });
+
+  it('keeps old ids resolving when a file is renamed', () => {
+    mkdirSync(join(tempDir, 'src'), { recursive: true });
+    writeFileSync(join(tempDir, 'src', 'pay.ts'), 'function charge() {}');
+    const parseResults = new Map<string, readonly ParseResult[]>([
+      ['pay.ts', [makeParsedResult({ name: 'charge', line: 3 })]],
+      ['billing.ts', [makeParsedResult({ name: 'charge', line: 5 })]],
+    ]);
+    const indexer = createIndexer(
+      createMockParserRegistry(parseResults),
+      dbManager,
+    );
+    indexer.index({ rootDir: tempDir });
+    const [before] = dbManager.getEntitiesByFilePath('src/pay.ts');
+
+    rmSync(join(tempDir, 'src', 'pay.ts'));
+    writeFileSync(join(tempDir, 'src', 'billing.ts'), 'function charge() {}');
+    const result = indexer.index({
+      rootDir: tempDir,
+      incremental: true,
+      fileRenames: { 'src/pay.ts': 'src/billing.ts' },
+    });
+
+    const [after] = dbManager.getEntitiesByFilePath('src/billing.ts');
+    expect(result.renames).toEqual([
+      { from: before.id, to: after.id, reason: 'git' },
+    ]);
+    expect(dbManager.getEntitiesByFilePath('src/pay.ts')).toEqual([]);
+    expect(dbManager.getEntityById(before.id)?.id).toBe(after.id);
+  });
+
+  it('follows entities into the files a split file went to', () => {
+    mkdirSync(join(tempDir, 'src'), { recursive: true });
+    writeFileSync(join(tempDir, 'src', 'pay.ts'), 'function charge() {}');
+    const parseResults = new Map<string, readonly ParseResult[]>([
+      [
+        'pay.ts',
+        [
+          makeParsedResult({ name: 'charge', line: 3 }),
+          makeParsedResult({ name: 'refund', line: 9 }),
+        ],
+      ],
+      ['charge.ts', [makeParsedResult({ name: 'charge', line: 1 })]],
+      ['refund.ts', [makeParsedResult({ name: 'refund', line: 1 })]],
+    ]);
+    const indexer = createIndexer(
+      createMockParserRegistry(parseResults),
+      dbManager,
+    );
+    indexer.index({ rootDir: tempDir });
+    const [charge, refund] = dbManager.getEntitiesByFilePath('src/pay.ts');
+
+    rmSync(join(tempDir, 'src', 'pay.ts'));
+    writeFileSync(join(tempDir, 'src', 'charge.ts'), 'function charge() {}');
+    writeFileSync(join(tempDir, 'src', 'refund.ts'), 'function refund() {}');
+    const result = indexer.index({
+      rootDir: tempDir,
+      incremental: true,
+      fileRenames: { 'src/pay.ts': ['src/charge.ts'] },
+    });
+
+    expect(result.renames.map((rename) => rename.reason)).toEqual([
+      'git',
+      'symbol',
+    ]);
+    expect(dbManager.getEntityById(charge.id)?.filePath).toBe('src/charge.ts');
+    expect(dbManager.getEntityById(refund.id)?.filePath).toBe('src/refund.ts');
+    expect(dbManager.getFileLineage()).toEqual({
+      'src/pay.ts': ['src/charge.ts', 'src/refund.ts'],
+    });
+  });
+
+  it('keeps tombstones of entities removed and files deleted', () => {
+    mkdirSync(join(tempDir, 'src'), { recursive: true });
+    writeFileSync(join(tempDir, 'src', 'pay.ts'), 'function charge() {}');
+    writeFileSync(join(tempDir, 'src', 'util.ts'), 'function helper() {}');
+    const charge = makeParsedResult({ name: 'charge', line: 3 });
+    const refund = makeParsedResult({
+      name: 'refund',
+      line: 9,
+      rawDocstring: 'Refunds a charge',
+    });
+    const helper = makeParsedResult({
+      name: 'helper',
+      rawDocstring: 'Helps',
+    });
+    createIndexer(
+      createMockParserRegistry(
+        new Map([
+          ['pay.ts', [charge, refund]],
+          ['util.ts', [helper]],
+        ]),
+      ),
+      dbManager,
+    ).index({ rootDir: tempDir });
+
+    writeFileSync(join(tempDir, 'src', 'pay.ts'), 'function charge() { }');
+    rmSync(join(tempDir, 'src', 'util.ts'));
+    const result = createIndexer(
+      createMockParserRegistry(new Map([['pay.ts', [charge]]])),
+      dbManager,
+    ).index({ rootDir: tempDir, incremental: true });
+
+    expect(
+      result.tombstones.map((tombstone) => [tombstone.name, tombstone.reason]),
+    ).toEqual([
+      ['refund', 'removed'],
+      ['helper', 'file_deleted'],
+    ]);
+    expect(dbManager.getEntitiesByFilePath('src/util.ts')).toEqual([]);
+    expect(dbManager.getTombstones().map((t) => t.name)).toEqual([
+      'helper',
+      'refund',
+    ]);
+  });
+
+  it('binds unannotated generated files to their generator', () => {
+    mkdirSync(join(tempDir, 'tools'), { recursive: true });
+    mkdirSync(join(tempDir, 'gen'), { recursive: true });
+    writeFileSync(join(tempDir, 'tools', 'protogen.ts'), 'function gen() {}');
+    writeFileSync(
+      join(tempDir, 'gen', 'api.ts'),
+      'export const x = 1;\n// Code generated by protoc. DO NOT EDIT.\n',
+    );
+    const generator = (owner: string): readonly ParseResult[] => [
+      makeParsedResult({
+        name: 'protogen',
+        filePath: 'tools/protogen.ts',
+        entityType: 'module',
+        metadata: {
+          type: 'module',
+          description: 'Protobuf code generator',
+          owner,
+          tags: ['codegen'],
+          generates: ['gen/'],
+        },
+      }),
+    ];
+    const parseResults = new Map([['protogen.ts', generator('platform')]]);
+    const indexer = createIndexer(
+      createMockParserRegistry(parseResults),
+      dbManager,
+    );
+
+    const result = indexer.index({ rootDir: tempDir, incremental: true });
+    expect(result.totalEntities).toBe(2);
+    const [entity] = dbManager.getEntitiesByFilePath('gen/api.ts');
+    expect(entity).toMatchObject({
+      name: 'api',
+      entityType: 'module',
+      description: 'Protobuf code generator',
+      line: 2,
+      owner: 'platform',
+      tags: ['codegen'],
+    });
+    expect('generates' in entity.metadata).toBe(false);
+
+    // An edit to the generator reaches the unchanged generated file
+    parseResults.set('protogen.ts', generator('payments'));
+    writeFileSync(join(tempDir, 'tools', 'protogen.ts'), 'function gen2() {}');
+    indexer.index({ rootDir: tempDir, incremental: true });
+    expect(dbManager.getEntitiesByFilePath('gen/api.ts')).toMatchObject([
+      { owner: 'payments' },
+    ]);
+    expect(dbManager.getTombstones()).toEqual([]);
+  });
+
+  it('leaves generated files no generator matches unindexed', () => {
+    mkdirSync(join(tempDir, 'gen'), { recursive: true });
+    writeFileSync(
+      join(tempDir, 'gen', 'api.ts'),
+      '// Code generated by protoc. DO NOT EDIT.\n',
+    );
+    const indexer = createIndexer(createMockParserRegistry(), dbManager);
+
+    expect(indexer.index({ rootDir: tempDir }).totalEntities).toBe(0);
+    expect(dbManager.getEntitiesByFilePath('gen/api.ts')).toEqual([]);
+  });
+
+  describe('annotation layers', () => {
+    function setup(): ParserRegistry {
+      mkdirSync(join(tempDir, 'src', 'pay'), { recursive: true });
+      writeFileSync(join(tempDir, 'src', 'pay', 'api.ts'), 'class Api {}');
+      writeFileSync(
+        join(tempDir, 'src', 'pay', 'api.ts.knowgraph.yml'),
+        'Api:\n  owner: billing-team\n  tags: [payments]\n',
+      );
+      return createMockParserRegistry(
+        new Map([
+          [
+            'api.ts',
+            [
+              makeParsedResult({
+                name: 'Api',
+                line: 3,
+                metadata: {
+                  type: 'service',
+                  description: 'Payments API',
+                  owner: 'payments-team',
+                },
+              }),
+            ],
+          ],
+        ]),
+      );
+    }
+
+    const defaults = [
+      { paths: ['src/'], metadata: { status: 'stable', owner: 'platform' } },
+    ];
+
+    it('layers sidecars and defaults under inline annotations', () => {
+      const indexer = createIndexer(setup(), dbManager);
+      const result = indexer.index({
+        rootDir: tempDir,
+        annotations: { defaults },
+      });
+
+      const [api] = dbManager.getEntitiesByFilePath('src/pay/api.ts');
+      expect(api).toMatchObject({
+        owner: 'payments-team',
+        status: 'stable',
+        tags: ['payments'],
+      });
+      expect(result.conflicts).toEqual([
+        {
+          filePath: 'src/pay/api.ts',
+          entity: 'Api',
+          field: 'owner',
+          kept: {
+            source: 'inline',
+            location: 'src/pay/api.ts:3',
+            value: 'payments-team',
+          },
+          overridden: [
+            {
+              source: 'sidecar',
+              location: 'src/pay/api.ts.knowgraph.yml',
+              value: 'billing-team',
+            },
+            {
+              source: 'defaults',
+              location: 'annotations.defaults[0]',
+              value: 'platform',
+            },
+          ],
+        },
+      ]);
+    });
+
+    it('follows the configured resolution order', () => {
+      const indexer = createIndexer(setup(), dbManager);
+      const result = indexer.index({
+        rootDir: tempDir,
+        annotations: { defaults, resolution: ['sidecar', 'inline'] },
+      });
+
+      const [api] = dbManager.getEntitiesByFilePath('src/pay/api.ts');
+      expect(api).toMatchObject({ owner: 'billing-team', status: null });
+      expect(result.conflicts.map((c) => c.kept.source)).toEqual(['sidecar']);
+    });
+
+    it('re-parses a file when only its sidecar changes', () => {
+      const registry = setup();
+      const indexer = createIndexer(registry, dbManager);
+      indexer.index({ rootDir: tempDir, incremental: true });
+
+      writeFileSync(
+        join(tempDir, 'src', 'pay', 'api.ts.knowgraph.yml'),
+        'Api:\n  status: deprecated\n',
+      );
+      const result = indexer.index({ rootDir: tempDir, incremental: true });
+      expect(result.conflicts).toEqual([]);
+      const [api] = dbManager.getEntitiesByFilePath('src/pay/api.ts');
+      expect(api?.status).toBe('deprecated');
+    });
+
+    it('records a file error for a malformed sidecar', () => {
+      const indexer = createIndexer(setup(), dbManager);
+      writeFileSync(
+        join(tempDir, 'src', 'pay', 'api.ts.knowgraph.yml'),
+        '- not: a mapping\n',
+      );
+      const result = indexer.index({ rootDir: tempDir });
+      expect(result.errors[0]?.message).toContain(
+        'src/pay/api.ts.knowgraph.yml: Sidecar must map entity names',
+      );
+    });
+  });
 });
@@ -0,0 +1,167 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  followFileLineage,
+  matchRenames,
+  readGitFileLineage,
+  readGitFileRenames,
+  readGitHead,
+} from '../renames.js';
+import type { RenameCandidate } from '../types.js';
+
+function candidate(overrides: Partial<RenameCandidate>): RenameCandidate {
+  return {
+    id: 'id',
+    filePath: 'src/pay.ts',
+    name: 'charge',
+    entityType: 'function',
+    parent: null,
+    line: 1,
+    rawDocstring: null,
+    ...overrides,
+  };
+}
+
+describe('matchRenames', () => {
+  it('pairs git renames, moves, in-place renames, and moved annotations', () => {
+    const renames = matchRenames(
+      [
+        candidate({ id: 'a', filePath: 'src/old.ts' }),
+        candidate({ id: 'b', name: 'refund', line: 10 }),
+        candidate({ id: 'c', name: 'payout', line: 20 }),
+        candidate({ id: 'd', name: 'void', rawDocstring: 'Voids a charge' }),
+      ],
+      [
+        candidate({ id: 'A', filePath: 'src/new.ts', line: 4 }),
+        candidate({ id: 'B', name: 'refund', line: 12 }),
+        candidate({ id: 'C', name: 'sendPayout', line: 20 }),
+        candidate({
+          id: 'D',
+          filePath: 'src/void.ts',
+          name: 'cancel',
+          rawDocstring: 'Voids a charge',
+        }),
+      ],
+      { 'src/old.ts': 'src/new.ts' },
+    );
+    expect(renames).toEqual([
+      { from: 'a', to: 'A', reason: 'git' },
+      { from: 'b', to: 'B', reason: 'position' },
+      { from: 'c', to: 'C', reason: 'position' },
+      { from: 'd', to: 'D', reason: 'content' },
+    ]);
+  });
+
+  it('follows entities into every file a split file went to', () => {
+    const renames = matchRenames(
+      [
+        candidate({ id: 'a', name: 'charge' }),
+        candidate({ id: 'b', name: 'refund', line: 9 }),
+      ],
+      [
+        candidate({ id: 'A', filePath: 'src/charge.ts', name: 'charge' }),
+        candidate({ id: 'B', filePath: 'src/refund.ts', name: 'refund' }),
+      ],
+      { 'src/pay.ts': ['src/charge.ts', 'src/refund.ts'] },
+    );
+    expect(renames).toEqual([
+      { from: 'a', to: 'A', reason: 'git' },
+      { from: 'b', to: 'B', reason: 'git' },
+    ]);
+  });
+
+  it('pairs a symbol moved to another file without git', () => {
+    expect(
+      matchRenames(
+        [candidate({ id: 'a', name: 'refund', line: 9 })],
+        [candidate({ id: 'b', filePath: 'src/refund.ts', name: 'refund' })],
+      ),
+    ).toEqual([{ from: 'a', to: 'b', reason: 'symbol' }]);
+  });
+
+  it('pairs nothing when a match is ambiguous', () => {
+    expect(
+      matchRenames(
+        [candidate({ id: 'a', line: 1, rawDocstring: 'Same' })],
+        [
+          candidate({ id: 'b', name: 'x', line: 5, rawDocstring: 'Same' }),
+          candidate({ id: 'c', name: 'y', line: 9, rawDocstring: 'Same' }),
+        ],
+      ),
+    ).toEqual([]);
+  });
+});
+
+describe('readGitFileRenames / readGitFileLineage', () => {
+  let dir: string;
+
+  function git(...args: string[]): void {
+    execFileSync(
+      'git',
+      ['-c', 'user.name=test', '-c', 'user.email=test@example.com', ...args],
+      { cwd: dir },
+    );
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-renames-'));
+    mkdirSync(join(dir, 'src'));
+    writeFileSync(
+      join(dir, 'src', 'pay.ts'),
+      'export function charge(): void {}\n'.repeat(5),
+    );
+    git('init', '-q');
+    git('add', '.');
+    git('commit', '-qm', 'init');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('lists files renamed since a commit, old path to new', () => {
+    const head = readGitHead(dir);
+    expect(head).toMatch(/^[0-9a-f]{40}$/);
+    git('mv', 'src/pay.ts', 'src/billing.ts');
+    expect(readGitFileRenames(dir, head!)).toEqual({
+      'src/pay.ts': 'src/billing.ts',
+    });
+  });
+
+  it('lists every file a split file was copied to', () => {
+    const head = readGitHead(dir)!;
+    const body = 'export function charge(): void {}\n'.repeat(5);
+    writeFileSync(join(dir, 'src', 'pay.ts'), `${body}// kept\n`);
+    writeFileSync(join(dir, 'src', 'charge.ts'), body);
+    git('add', '.');
+    expect(readGitFileLineage(dir, head)).toEqual({
+      'src/pay.ts': ['src/charge.ts'],
+    });
+  });
+
+  it('is empty for an unknown commit or outside git', () => {
+    expect(readGitFileRenames(dir, 'f'.repeat(40))).toEqual({});
+    expect(readGitFileLineage(dir, 'f'.repeat(40))).toEqual({});
+    expect(readGitHead(tmpdir())).toBeUndefined();
+  });
+});
+
+describe('followFileLineage', () => {
+  it('follows a path through later renames, splits, and merges', () => {
+    const lineage = {
+      'a.ts': ['b.ts', 'c.ts'],
+      'b.ts': ['d.ts'],
+      'd.ts': ['a.ts'],
+    };
+    expect(followFileLineage(lineage, 'a.ts')).toEqual([
+      'a.ts',
+      'b.ts',
+      'c.ts',
+      'd.ts',
+    ]);
+    expect(followFileLineage(lineage, 'x.ts')).toEqual(['x.ts']);
+  });
+});
@@ -0,0 +1,79 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  DEFAULT_MAX_FILE_BYTES,
+  isBinaryContent,
+  longestLine,
+  readLimitedFile,
+  resolveFileLimits,
+} from '../safeguards.js';
+
+describe('isBinaryContent', () => {
+  it('treats a NUL byte near the start as binary', () => {
+    expect(isBinaryContent(Buffer.from('const a = 1;\n'))).toBe(false);
+    expect(isBinaryContent(Buffer.from([0x89, 0x50, 0x4e, 0x47, 0]))).toBe(
+      true,
+    );
+    const late = Buffer.alloc(9000, 'a');
+    late[8500] = 0;
+    expect(isBinaryContent(late)).toBe(false);
+  });
+});
+
+describe('longestLine', () => {
+  it('measures lines without their endings', () => {
+    expect(longestLine('')).toBe(0);
+    expect(longestLine('ab\r\nabcd\nabc')).toBe(4);
+    expect(longestLine('abc\r\n')).toBe(3);
+  });
+});
+
+describe('readLimitedFile', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-limits-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function read(name: string, content: string | Buffer) {
+    writeFileSync(join(dir, name), content);
+    const limits = resolveFileLimits({ maxFileBytes: 100, maxLineLength: 20 });
+    return readLimitedFile(join(dir, name), name, limits);
+  }
+
+  it('reads files within the limits', () => {
+    expect(read('a.ts', 'const a = 1;\n')).toEqual({
+      content: 'const a = 1;\n',
+    });
+  });
+
+  it('skips oversized, binary, and minified files', () => {
+    expect(read('big.ts', 'a\n'.repeat(60))).toEqual({
+      skipped: {
+        filePath: 'big.ts',
+        reason: 'too-large',
+        message: '120 bytes is over the 100 byte limit',
+      },
+    });
+    expect(read('logo.png', Buffer.from([0x89, 0, 0x4e]))).toMatchObject({
+      skipped: { reason: 'binary' },
+    });
+    expect(read('bundle.js', 'x'.repeat(30))).toMatchObject({
+      skipped: { reason: 'minified' },
+    });
+  });
+
+  it('fills in the defaults', () => {
+    expect(resolveFileLimits().maxFileBytes).toBe(DEFAULT_MAX_FILE_BYTES);
+  });
+});
@@ -0,0 +1,137 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, symlinkSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import ignore from 'ignore';
+import {
+  findSubmodule,
+  isSkippedByWalk,
+  isVendoredPath,
+  parseGitmodules,
+  walkFiles,
+} from '../walker.js';
+
+const GITMODULES = `[submodule "libs/auth"]
+\tpath = libs/auth
+\turl = git@github.com:acme/auth.git
+[submodule "tools"]
+\tpath = tools/
+\turl = ../tools
+[submodule "no-path"]
+\turl = https://example.com/x.git
+`;
+
+function createTempDir(): string {
+  const dir = join(
+    tmpdir(),
+    `knowgraph-walk-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+  );
+  mkdirSync(dir, { recursive: true });
+  return dir;
+}
+
+function write(path: string, content = ''): void {
+  mkdirSync(join(path, '..'), { recursive: true });
+  writeFileSync(path, content);
+}
+
+describe('parseGitmodules', () => {
+  it('reads each submodule with a path, named after its URL', () => {
+    expect(parseGitmodules(GITMODULES)).toEqual([
+      {
+        name: 'libs/auth',
+        path: 'libs/auth',
+        url: 'git@github.com:acme/auth.git',
+        namespace: 'acme/auth',
+      },
+      { name: 'tools', path: 'tools', url: '../tools', namespace: 'tools' },
+    ]);
+  });
+
+  it('finds the innermost submodule of a path', () => {
+    const submodules = parseGitmodules(
+      GITMODULES + '[submodule "inner"]\n\tpath = libs/auth/inner\n',
+    );
+    expect(findSubmodule('libs/auth/inner/a.ts', submodules)?.name).toBe(
+      'inner',
+    );
+    expect(findSubmodule('libs/auth/a.ts', submodules)?.name).toBe(
+      'libs/auth',
+    );
+    expect(findSubmodule('libs/authz/a.ts', submodules)).toBeUndefined();
+  });
+});
+
+describe('isVendoredPath', () => {
+  it('matches files in a vendor directory at any depth', () => {
+    expect(isVendoredPath('vendor/pkg/a.go')).toBe(true);
+    expect(isVendoredPath('svc/vendor/a.go')).toBe(true);
+    expect(isVendoredPath('src/vendor.ts')).toBe(false);
+  });
+});
+
+describe('walkFiles', () => {
+  let rootDir: string;
+  let outside: string;
+
+  beforeEach(() => {
+    rootDir = createTempDir();
+    outside = createTempDir();
+    write(join(rootDir, 'src', 'a.ts'));
+    write(join(rootDir, 'vendor', 'lib', 'b.ts'));
+    write(join(rootDir, 'libs', 'auth', 'c.ts'));
+    write(join(rootDir, '.gitmodules'), GITMODULES);
+    write(join(outside, 'shared', 'd.ts'));
+  });
+
+  afterEach(() => {
+    rmSync(rootDir, { recursive: true, force: true });
+    rmSync(outside, { recursive: true, force: true });
+  });
+
+  it('skips vendor directories and submodules by default', () => {
+    expect(walkFiles(rootDir, ignore())).toEqual(['src/a.ts']);
+  });
+
+  it('includes them when asked', () => {
+    expect(
+      walkFiles(rootDir, ignore(), { submodules: true, vendor: true }),
+    ).toEqual([
+      'libs/auth/c.ts',
+      'src/a.ts',
+      'vendor/lib/b.ts',
+    ]);
+  });
+
+  it('ends symlink cycles and never walks a tree twice', () => {
+    symlinkSync(rootDir, join(rootDir, 'src', 'loop'));
+    symlinkSync(outside, join(rootDir, 'linked'));
+    symlinkSync(outside, join(rootDir, 'src', 'again'));
+    symlinkSync(outside, join(outside, 'shared', 'back'));
+    symlinkSync(join(rootDir, 'missing'), join(rootDir, 'dangling.ts'));
+
+    expect(walkFiles(rootDir, ignore())).toEqual(['src/a.ts']);
+    expect(walkFiles(rootDir, ignore(), { followSymlinks: true })).toEqual([
+      'linked/shared/d.ts',
+      'src/a.ts',
+    ]);
+  });
+
+  it('keeps symlinked files and honours ignore patterns', () => {
+    symlinkSync(join(rootDir, 'src', 'a.ts'), join(rootDir, 'src', 'b.ts'));
+    expect(walkFiles(rootDir, ignore().add('src/a.ts'))).toEqual(['src/b.ts']);
+    expect(walkFiles(rootDir, ignore().add('src'))).toEqual([]);
+  });
+});
+
+describe('isSkippedByWalk', () => {
+  it('skips what a walk with the same options leaves out', () => {
+    const submodules = parseGitmodules(GITMODULES);
+    expect(isSkippedByWalk('vendor/a.ts', submodules, {})).toBe(true);
+    expect(isSkippedByWalk('vendor/a.ts', submodules, { vendor: true })).toBe(
+      false,
+    );
+    expect(isSkippedByWalk('tools/a.ts', submodules, {})).toBe(true);
+    expect(isSkippedByWalk('src/a.ts', submodules, {})).toBe(false);
+  });
+});
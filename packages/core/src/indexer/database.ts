@@ -11,6 +11,14 @@
  */
 import Database from 'better-sqlite3';
 import { createHash } from 'node:crypto';
+import { existsSync, rmSync } from 'node:fs';
+import {
+  isEncryptedFile,
+  readStoreFile,
+  writeStoreFile,
+} from '../encryption/encryption.js';
+import type { EncryptionOptions } from '../encryption/types.js';
+import { acquireFileLock } from '../locking/file-lock.js';
 import type { Link } from '../types/index.js';
 import {
   CREATE_TABLES_SQL,
@@ -20,10 +28,20 @@ import {
   INSERT_FTS_SQL,
   INSERT_LINK_SQL,
   INSERT_RELATIONSHIP_SQL,
+  INSERT_RENAME_SQL,
   INSERT_TAG_SQL,
+  INSERT_TOMBSTONE_SQL,
   UPDATE_ENTITY_SQL,
 } from './schema.js';
-import type { EntityInsert, IndexStats, StoredEntity } from './types.js';
+import type {
+  EntityInsert,
+  EntityRename,
+  FileLineage,
+  IndexStats,
+  StoredEntity,
+  Tombstone,
+  TombstoneReason,
+} from './types.js';
 
 interface EntityRow {
   readonly id: string;
@@ -45,6 +63,16 @@ interface EntityRow {
   readonly updated_at: string;
 }
 
+interface TombstoneRow {
+  readonly id: string;
+  readonly name: string;
+  readonly entity_type: string;
+  readonly file_path: string;
+  readonly owner: string | null;
+  readonly reason: string;
+  readonly removed_at: string;
+}
+
 interface TagRow {
   readonly tag: string;
 }
@@ -110,6 +138,7 @@ export interface DatabaseManager {
   readonly db: Database.Database;
   initialize(): void;
   close(): void;
+  /** The entity with `id`, or the one it was renamed to since. */
   getEntityById(id: string): StoredEntity | undefined;
   getEntitiesByFilePath(filePath: string): readonly StoredEntity[];
   insertEntity(entity: EntityInsert): string;
@@ -120,19 +149,124 @@ export interface DatabaseManager {
   insertLinks(entityId: string, links: readonly Link[]): void;
   getStats(): IndexStats;
   getFileHash(filePath: string): string | undefined;
+  /** Every file path with indexed entities, in path order. */
+  getFilePaths(): readonly string[];
+  getMeta(key: string): string | undefined;
+  setMeta(key: string, value: string): void;
+  /**
+   * Record that the entity with id `from` now has id `to`. Ids renamed to
+   * `from` earlier are pointed at `to` too, so each resolves in one step.
+   */
+  recordRename(rename: EntityRename): void;
+  /** The id `id` was last renamed to, or `id` itself. */
+  resolveEntityId(id: string): string;
+  /** Record that code in the file at `from` went to the file at `to`. */
+  recordFileLineage(from: string, to: string): void;
+  /** Every recorded old file path to the paths its code went to. */
+  getFileLineage(): FileLineage;
+  /**
+   * Keep `tombstone` until `pruneTombstones` drops it or an entity with its
+   * id is inserted again.
+   */
+  recordTombstone(tombstone: Tombstone): void;
+  /** Tombstones removed at or after `since` (ISO 8601), oldest first. */
+  getTombstones(since?: string): readonly Tombstone[];
+  /** Drop tombstones removed before `before`, returning how many. */
+  pruneTombstones(before: string): number;
+  /**
+   * Run `read` in one read transaction, so every statement in it sees the
+   * same committed state even while another connection writes. Inside an
+   * open transaction it joins that one.
+   */
+  snapshot<T>(read: () => T): T;
+}
+
+/**
+ * The in-memory copy of the store at `file`: decrypted when `encrypted`,
+ * copied from the plain database otherwise, and empty when missing.
+ */
+function openInMemory(
+  file: string,
+  encrypted: boolean,
+  options: EncryptionOptions,
+): Database.Database {
+  if (encrypted) return new Database(readStoreFile(file, options));
+  if (!existsSync(file)) return new Database(':memory:');
+  const plain = new Database(file, { readonly: true });
+  try {
+    return new Database(plain.serialize());
+  } finally {
+    plain.close();
+  }
 }
 
-export function createDatabaseManager(dbPath?: string): DatabaseManager {
-  const db = new Database(dbPath ?? ':memory:');
+export interface DatabaseOptions extends EncryptionOptions {
+  /**
+   * Only read the store: an encrypted one is neither locked against other
+   * writers nor written back by `close()`.
+   */
+  readonly readOnly?: boolean;
+}
+
+/**
+ * Open the database at `dbPath`, in memory when omitted. An encrypted
+ * store, and any store once `options` holds a key, is read into memory
+ * and written back encrypted by `close()` in one atomic rename, so only
+ * ciphertext reaches the disk and readers see a run whole or not at all.
+ * Each write-back replaces the whole file, so a read-write open holds the
+ * store's lockfile until `close()`, and a second writer waits for it
+ * rather than overwriting its changes.
+ */
+export function createDatabaseManager(
+  dbPath?: string,
+  options: DatabaseOptions = {},
+): DatabaseManager {
+  const file =
+    dbPath === undefined || dbPath === ':memory:' ? undefined : dbPath;
+  const encrypted = file !== undefined && isEncryptedFile(file);
+  const sealedFile = encrypted || options.encryptionKey ? file : undefined;
+  const writtenFile = options.readOnly ? undefined : sealedFile;
+  const release = writtenFile ? acquireFileLock(writtenFile) : undefined;
+  let db: Database.Database;
+  try {
+    db = sealedFile
+      ? openInMemory(sealedFile, encrypted, options)
+      : new Database(dbPath ?? ':memory:');
+  } catch (err) {
+    release?.();
+    throw err;
+  }
   db.pragma('journal_mode = WAL');
   db.pragma('foreign_keys = ON');
+  // Bumped by DDL, which `total_changes()` does not count
+  const schemaVersion = (): unknown =>
+    db.pragma('schema_version', { simple: true });
+  const openedSchema = schemaVersion();
 
   function initialize(): void {
     db.exec(CREATE_TABLES_SQL);
   }
 
   function close(): void {
-    db.close();
+    try {
+      if (writtenFile && options.encryptionKey) {
+        const { count } = db
+          .prepare('SELECT total_changes() AS count')
+          .get() as CountRow;
+        // A store not yet encrypted is written even when unchanged, and its
+        // plain journal files go with it
+        if (count > 0 || schemaVersion() !== openedSchema || !encrypted) {
+          writeStoreFile(writtenFile, db.serialize(), options);
+        }
+        if (!encrypted) {
+          rmSync(`${writtenFile}-wal`, { force: true });
+          rmSync(`${writtenFile}-shm`, { force: true });
+        }
+      }
+      db.close();
+    } finally {
+      release?.();
+    }
   }
 
   function getTagsForEntity(entityId: string): readonly string[] {
@@ -178,12 +312,13 @@ export function createDatabaseManager(dbPath?: string): DatabaseManager {
   }
 
   function getEntityById(id: string): StoredEntity | undefined {
-    const row = db.prepare('SELECT * FROM entities WHERE id = ?').get(id) as
+    const select = db.prepare('SELECT * FROM entities WHERE id = ?');
+    const row = (select.get(id) ?? select.get(resolveEntityId(id))) as
       | EntityRow
       | undefined;
     if (!row) return undefined;
-    const tags = getTagsForEntity(id);
-    const links = getLinksForEntity(id);
+    const tags = getTagsForEntity(row.id);
+    const links = getLinksForEntity(row.id);
     return rowToStoredEntity(row, tags, links);
   }
 
@@ -219,6 +354,8 @@ export function createDatabaseManager(dbPath?: string): DatabaseManager {
     };
 
     db.prepare(INSERT_ENTITY_SQL).run(params);
+    // An entity back in the index is no longer deleted
+    db.prepare('DELETE FROM tombstones WHERE id = ?').run(id);
 
     const tagsText = entity.tags?.join(' ') ?? '';
     insertFtsEntry(
@@ -368,6 +505,105 @@ export function createDatabaseManager(dbPath?: string): DatabaseManager {
     return row?.file_hash ?? undefined;
   }
 
+  function getFilePaths(): readonly string[] {
+    const rows = db
+      .prepare('SELECT DISTINCT file_path FROM entities ORDER BY file_path')
+      .all() as readonly { readonly file_path: string }[];
+    return rows.map((row) => row.file_path);
+  }
+
+  function getMeta(key: string): string | undefined {
+    const row = db
+      .prepare('SELECT value FROM index_meta WHERE key = ?')
+      .get(key) as { readonly value: string } | undefined;
+    return row?.value;
+  }
+
+  function setMeta(key: string, value: string): void {
+    db.prepare(
+      'INSERT INTO index_meta (key, value) VALUES (?, ?) ' +
+        'ON CONFLICT(key) DO UPDATE SET value = excluded.value',
+    ).run(key, value);
+  }
+
+  function recordRename(rename: EntityRename): void {
+    db.prepare('UPDATE entity_renames SET to_id = ? WHERE to_id = ?').run(
+      rename.to,
+      rename.from,
+    );
+    // An id in use again, as when a move is undone, no longer redirects
+    db.prepare(
+      'DELETE FROM entity_renames WHERE from_id = to_id OR from_id = ?',
+    ).run(rename.to);
+    db.prepare(INSERT_RENAME_SQL).run({
+      from_id: rename.from,
+      to_id: rename.to,
+      reason: rename.reason,
+    });
+  }
+
+  function resolveEntityId(id: string): string {
+    const row = db
+      .prepare('SELECT to_id FROM entity_renames WHERE from_id = ?')
+      .get(id) as { readonly to_id: string } | undefined;
+    return row?.to_id ?? id;
+  }
+
+  function recordFileLineage(from: string, to: string): void {
+    if (from === to) return;
+    db.prepare(
+      'INSERT OR IGNORE INTO file_lineage (from_path, to_path) VALUES (?, ?)',
+    ).run(from, to);
+  }
+
+  function getFileLineage(): FileLineage {
+    const rows = db
+      .prepare('SELECT from_path, to_path FROM file_lineage ORDER BY rowid')
+      .all() as readonly { from_path: string; to_path: string }[];
+    const lineage: Record<string, string[]> = {};
+    for (const row of rows) (lineage[row.from_path] ??= []).push(row.to_path);
+    return lineage;
+  }
+
+  function recordTombstone(tombstone: Tombstone): void {
+    db.prepare(INSERT_TOMBSTONE_SQL).run({
+      id: tombstone.id,
+      name: tombstone.name,
+      entity_type: tombstone.entityType,
+      file_path: tombstone.filePath,
+      owner: tombstone.owner,
+      reason: tombstone.reason,
+      removed_at: tombstone.removedAt,
+    });
+  }
+
+  function getTombstones(since = ''): readonly Tombstone[] {
+    const rows = db
+      .prepare(
+        'SELECT * FROM tombstones WHERE removed_at >= ? ORDER BY removed_at, id',
+      )
+      .all(since) as readonly TombstoneRow[];
+    return rows.map((row) => ({
+      id: row.id,
+      name: row.name,
+      entityType: row.entity_type as Tombstone['entityType'],
+      filePath: row.file_path,
+      owner: row.owner,
+      reason: row.reason as TombstoneReason,
+      removedAt: row.removed_at,
+    }));
+  }
+
+  function pruneTombstones(before: string): number {
+    return db
+      .prepare('DELETE FROM tombstones WHERE removed_at < ?')
+      .run(before).changes;
+  }
+
+  function snapshot<T>(read: () => T): T {
+    return db.inTransaction ? read() : db.transaction(read).deferred();
+  }
+
   return {
     db,
     initialize,
@@ -382,5 +618,16 @@ export function createDatabaseManager(dbPath?: string): DatabaseManager {
     insertLinks,
     getStats,
     getFileHash,
+    getFilePaths,
+    getMeta,
+    setMeta,
+    recordRename,
+    resolveEntityId,
+    recordFileLineage,
+    getFileLineage,
+    recordTombstone,
+    getTombstones,
+    pruneTombstones,
+    snapshot,
   };
 }
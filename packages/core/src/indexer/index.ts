@@ -1,8 +1,36 @@
-export { CREATE_TABLES_SQL } from './schema.js';
+export { CREATE_TABLES_SQL, INDEX_SCHEMA_VERSION } from './schema.js';
 export { createDatabaseManager, generateEntityId } from './database.js';
-export type { DatabaseManager } from './database.js';
-export { createIndexer } from './indexer.js';
+export type { DatabaseManager, DatabaseOptions } from './database.js';
+export {
+  createIndexer,
+  DEFAULT_TOMBSTONE_RETENTION_DAYS,
+  readIndexedScopes,
+} from './indexer.js';
+export {
+  followFileLineage,
+  matchRenames,
+  readGitFileLineage,
+  readGitFileRenames,
+  readGitHead,
+} from './renames.js';
 export type { ParserRegistry, ParserFn } from './indexer.js';
+export {
+  VENDOR_DIRECTORY,
+  findSubmodule,
+  isVendoredPath,
+  parseGitmodules,
+  readGitSubmodules,
+  walkFiles,
+} from './walker.js';
+export {
+  DEFAULT_MAX_FILE_BYTES,
+  DEFAULT_MAX_LINE_LENGTH,
+  DEFAULT_PARSE_TIMEOUT_MS,
+  isBinaryContent,
+  longestLine,
+  readLimitedFile,
+  resolveFileLimits,
+} from './safeguards.js';
 export type {
   StoredEntity,
   EntityInsert,
@@ -11,4 +39,15 @@ export type {
   IndexProgress,
   IndexResult,
   IndexError,
+  WalkOptions,
+  GitSubmodule,
+  FileLimits,
+  SkipReason,
+  SkippedFile,
+  RenameReason,
+  EntityRename,
+  RenameCandidate,
+  FileLineage,
+  Tombstone,
+  TombstoneReason,
 } from './types.js';
@@ -10,13 +10,66 @@
  *   domain: indexer-engine
  */
 import { createHash } from 'node:crypto';
-import { readFileSync } from 'node:fs';
-import { join } from 'node:path';
-import { globSync } from 'glob';
+import { existsSync, readFileSync } from 'node:fs';
+import { basename, join } from 'node:path';
+import { performance } from 'node:perf_hooks';
 import ignore from 'ignore';
 import type { ParseResult } from '../types/index.js';
+import {
+  DEFAULT_LOCALE,
+  resolveLocalizedText,
+} from '../i18n/localized-text.js';
+import {
+  SIDECAR_SUFFIX,
+  createDefaultLayers,
+  createSidecarLayers,
+  resolveAnnotation,
+  sidecarPath,
+} from '../annotations/annotation-layers.js';
+import type { AnnotationConflict } from '../annotations/types.js';
+import {
+  createCancellationCheck,
+  isCancellationError,
+} from '../cancellation/cancellation.js';
+import {
+  detectGeneratedCode,
+  findGenerator,
+  inheritGeneratorMetadata,
+} from '../generated/generated-code.js';
+import type { GeneratorNode } from '../generated/types.js';
+import {
+  dependencyEntryName,
+  environmentDependencies,
+} from '../graph/graph-environments.js';
+import { getLanguageFromPath } from '../parsers/generic-parser.js';
+import { matchPathCase, toPosixPath } from '../paths/paths.js';
+import { timePhase } from '../profiling/phase-timer.js';
+import {
+  formatScope,
+  parseScope,
+  pathInScope,
+  tagsInScope,
+} from '../scope/scope.js';
+import type { ScanScope } from '../scope/types.js';
 import { type DatabaseManager } from './database.js';
-import type { IndexError, IndexerOptions, IndexResult } from './types.js';
+import {
+  readLimitedFile,
+  resolveFileLimits,
+  slowParse,
+} from './safeguards.js';
+import { matchRenames } from './renames.js';
+import { INDEX_SCHEMA_VERSION } from './schema.js';
+import type {
+  IndexError,
+  IndexerOptions,
+  IndexResult,
+  RenameCandidate,
+  SkippedFile,
+  StoredEntity,
+  Tombstone,
+  WalkOptions,
+} from './types.js';
+import { isSkippedByWalk, readGitSubmodules, walkFiles } from './walker.js';
 
 export type ParserFn = (
   filePath: string,
@@ -28,8 +81,30 @@ export interface ParserRegistry {
   readonly canParse: (filePath: string) => boolean;
 }
 
-function computeFileHash(content: string): string {
-  return createHash('md5').update(content).digest('hex');
+/** Days tombstones are kept when `tombstoneRetentionDays` is not given. */
+export const DEFAULT_TOMBSTONE_RETENTION_DAYS = 30;
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+/** Patterns the indexer skips when no `exclude` is given. */
+export const DEFAULT_INDEX_EXCLUDE: readonly string[] = [
+  'node_modules',
+  '.git',
+  'dist',
+  'build',
+];
+
+/**
+ * The hash stored with a file's entities. A sidecar edit has to re-index
+ * its source like a source edit does, so the sidecar is hashed in too.
+ */
+export function indexedFileHash(
+  content: string,
+  sidecar: string | undefined,
+): string {
+  return createHash('md5')
+    .update(sidecar === undefined ? content : `${content}\0${sidecar}`)
+    .digest('hex');
 }
 
 function loadGitignorePatterns(rootDir: string): ReturnType<typeof ignore> {
@@ -43,23 +118,110 @@ function loadGitignorePatterns(rootDir: string): ReturnType<typeof ignore> {
   return ig;
 }
 
+interface SlowFile {
+  readonly hash: string;
+  readonly ms: number;
+}
+
+/** The files too slow to parse when last indexed, by path. */
+export function readSlowFiles(
+  dbManager: DatabaseManager,
+): Map<string, SlowFile> {
+  try {
+    const stored = JSON.parse(dbManager.getMeta('slow_files') ?? '{}');
+    return new Map(Object.entries(stored as Record<string, SlowFile>));
+  } catch {
+    return new Map();
+  }
+}
+
+interface GeneratedFile {
+  readonly hash: string;
+  /** 1-based line of the generated-code marker. */
+  readonly line: number;
+}
+
+/** The unannotated generated files found when last indexed, by path. */
+function readGeneratedFiles(
+  dbManager: DatabaseManager,
+): Map<string, GeneratedFile> {
+  try {
+    const stored = JSON.parse(dbManager.getMeta('generated_files') ?? '{}');
+    return new Map(Object.entries(stored as Record<string, GeneratedFile>));
+  } catch {
+    return new Map();
+  }
+}
+
+/** Every stored entity whose annotation declares `generates` patterns. */
+function storedGenerators(
+  dbManager: DatabaseManager,
+): readonly GeneratorNode[] {
+  return dbManager.getFilePaths().flatMap((filePath) =>
+    dbManager.getEntitiesByFilePath(filePath).flatMap((entity) => {
+      const { metadata } = entity;
+      if (!('generates' in metadata) || !metadata.generates?.length) {
+        return [];
+      }
+      return [
+        {
+          name: entity.name,
+          filePath,
+          patterns: metadata.generates,
+          metadata,
+        },
+      ];
+    }),
+  );
+}
+
+/** The scopes the index in `dbManager` was last built with. */
+export function readIndexedScopes(
+  dbManager: DatabaseManager,
+): readonly ScanScope[] {
+  const key = dbManager.getMeta('scopes') ?? '';
+  return key === '' ? [] : key.split(' ').map(parseScope);
+}
+
+/** The sidecar annotations next to `relPath`, if it has any. */
+export function readSidecar(
+  rootDir: string,
+  relPath: string,
+): string | undefined {
+  const path = join(rootDir, sidecarPath(relPath));
+  return existsSync(path) ? readFileSync(path, 'utf-8') : undefined;
+}
+
 function collectFiles(
   rootDir: string,
   excludePatterns: readonly string[],
+  walk: WalkOptions,
 ): readonly string[] {
   const ig = loadGitignorePatterns(rootDir);
   for (const pattern of excludePatterns) {
     ig.add(pattern);
   }
+  return walkFiles(rootDir, ig, walk);
+}
 
-  const allFiles = globSync('**/*', {
-    cwd: rootDir,
-    nodir: true,
-    dot: false,
-    absolute: false,
-  });
-
-  return allFiles.filter((f) => !ig.ignores(f));
+/**
+ * The files an index of `rootDir` parses: those `.gitignore`, `exclude`,
+ * and the `walk` rules leave, inside the path scopes, other than sidecars,
+ * that a parser reads.
+ */
+export function listIndexableFiles(
+  rootDir: string,
+  parserRegistry: ParserRegistry,
+  exclude: readonly string[] = DEFAULT_INDEX_EXCLUDE,
+  scopes: readonly ScanScope[] = [],
+  walk: WalkOptions = {},
+): readonly string[] {
+  return collectFiles(rootDir, exclude, walk).filter(
+    (f) =>
+      pathInScope(f, scopes) &&
+      !f.endsWith(SIDECAR_SUFFIX) &&
+      parserRegistry.canParse(f),
+  );
 }
 
 export function createIndexer(
@@ -68,23 +230,89 @@ export function createIndexer(
 ): {
   readonly index: (options: IndexerOptions) => IndexResult;
 } {
-  function index(options: IndexerOptions): IndexResult {
+  function indexFiles(options: IndexerOptions): IndexResult {
     const {
       rootDir,
-      exclude = ['node_modules', '.git', 'dist', 'build'],
+      exclude = DEFAULT_INDEX_EXCLUDE,
       incremental = false,
+      defaultLocale = DEFAULT_LOCALE,
       onProgress,
+      onFileIndexed,
+      configHash = '',
+      annotations = {},
+      fileRenames = {},
+      tombstoneRetentionDays = DEFAULT_TOMBSTONE_RETENTION_DAYS,
+      profiler,
     } = options;
+    const checkCancelled = createCancellationCheck('Indexing', options);
+    // Spelled as on disk, so `Services/` and `services/` scope alike where
+    // the file system does not tell them apart
+    const scopes = (options.scopes ?? []).map(
+      (scope): ScanScope =>
+        scope.kind === 'path'
+          ? { kind: 'path', path: matchPathCase(rootDir, scope.path) }
+          : scope,
+    );
+
+    // File hashes only prove a file is unchanged if it was parsed the same way
+    const schemaVersion = String(INDEX_SCHEMA_VERSION);
+    const previousSchema = dbManager.getMeta('schema_version');
+    const scopeKey = scopes.map(formatScope).sort().join(' ');
+    const unchangedSetup =
+      previousSchema === schemaVersion &&
+      dbManager.getMeta('config_hash') === configHash &&
+      (dbManager.getMeta('scopes') ?? '') === scopeKey;
+    const reuseHashes = incremental && unchangedSetup;
+    const limits = resolveFileLimits(options);
+    // Only a file unchanged since it was too slow is skipped without a parse
+    const previousSlow = reuseHashes
+      ? readSlowFiles(dbManager)
+      : new Map<string, SlowFile>();
+    const slowFiles = new Map<string, SlowFile>();
+    // Generated files are bound to their generator after the walk, when
+    // every generator is stored; unchanged ones are bound again so they
+    // follow edits to the generator's annotation
+    const previousGenerated = reuseHashes
+      ? readGeneratedFiles(dbManager)
+      : new Map<string, GeneratedFile>();
+    const generatedFiles = new Map<string, GeneratedFile>();
 
     const startTime = Date.now();
     const errors: IndexError[] = [];
     let totalEntities = 0;
     let totalRelationships = 0;
+    const conflicts: AnnotationConflict[] = [];
+    const skipped: SkippedFile[] = [];
+    const defaultLayers = createDefaultLayers(annotations.defaults);
+
+    // What a skipped file stored before goes, so it cannot go stale
+    function skip(file: SkippedFile): void {
+      skipped.push(file);
+      timePhase(profiler, 'bind', () =>
+        dbManager.deleteEntitiesByFilePath(file.filePath),
+      );
+    }
 
-    const files = collectFiles(rootDir, exclude);
-    const parsableFiles = files.filter((f) => parserRegistry.canParse(f));
+    const parsableFiles = timePhase(profiler, 'walk', () =>
+      listIndexableFiles(rootDir, parserRegistry, exclude, scopes, options),
+    );
+
+    // What re-parsed files held before and hold now, to match as renames
+    const removed: StoredEntity[] = [];
+    const added: RenameCandidate[] = [];
+    const indexable = new Set(parsableFiles);
+    const renamedFrom = new Map<string, string[]>();
+    for (const [from, targets] of Object.entries(fileRenames)) {
+      for (const to of [targets].flat()) {
+        if (indexable.has(to)) dbManager.recordFileLineage(from, to);
+        if (indexable.has(from)) continue;
+        renamedFrom.set(to, [...(renamedFrom.get(to) ?? []), from]);
+      }
+    }
 
     for (let i = 0; i < parsableFiles.length; i++) {
+      // Files already stored stay in the index; an incremental re-run resumes
+      checkCancelled();
       const relPath = parsableFiles[i];
       const absPath = join(rootDir, relPath);
 
@@ -97,66 +325,153 @@ export function createIndexer(
         });
       }
 
+      let stored = false;
       try {
-        const content = readFileSync(absPath, 'utf-8');
-        const fileHash = computeFileHash(content);
+        const read = timePhase(profiler, 'walk', () =>
+          readLimitedFile(absPath, relPath, limits),
+        );
+        if ('skipped' in read) {
+          skip(read.skipped);
+          continue;
+        }
+        const { content } = read;
+        const sidecar = timePhase(profiler, 'walk', () =>
+          readSidecar(rootDir, relPath),
+        );
+        const fileHash = indexedFileHash(content, sidecar);
+        const slow = previousSlow.get(relPath);
+        if (slow?.hash === fileHash) {
+          slowFiles.set(relPath, slow);
+          skip(slowParse(relPath, slow.ms, limits));
+          continue;
+        }
 
-        if (incremental) {
+        const previous = previousGenerated.get(relPath);
+        if (previous?.hash === fileHash) {
+          generatedFiles.set(relPath, previous);
+          continue;
+        }
+
+        if (reuseHashes) {
           const existingHash = dbManager.getFileHash(relPath);
           if (existingHash === fileHash) {
             continue;
           }
         }
 
-        // Remove old entities for this file path
-        dbManager.deleteEntitiesByFilePath(relPath);
+        // Remove old entities for this file path, and for the paths it was
+        // renamed, split, or merged from
+        timePhase(profiler, 'bind', () => {
+          for (const path of [relPath, ...(renamedFrom.get(relPath) ?? [])]) {
+            removed.push(...dbManager.getEntitiesByFilePath(path));
+            dbManager.deleteEntitiesByFilePath(path);
+          }
+        });
+
+        const parseStart = performance.now();
+        const results = timePhase(profiler, 'parse', () =>
+          parserRegistry.parse(absPath, content),
+        );
+        const parseMs = performance.now() - parseStart;
+        if (parseMs > limits.parseTimeoutMs) {
+          slowFiles.set(relPath, { hash: fileHash, ms: parseMs });
+          skip(slowParse(relPath, parseMs, limits));
+          continue;
+        }
 
-        const results = parserRegistry.parse(absPath, content);
-
-        for (const result of results) {
-          const entityId = dbManager.insertEntity({
-            filePath: relPath,
-            name: result.name,
-            entityType: result.entityType,
-            description: result.metadata.description,
-            rawDocstring: result.rawDocstring,
-            signature: result.signature,
-            parent: result.parent,
-            language: result.language,
-            line: result.line,
-            column: result.column,
-            owner: result.metadata.owner,
-            status: result.metadata.status,
-            metadata: result.metadata,
-            tags: result.metadata.tags,
-            links: result.metadata.links,
-            fileHash,
-          });
-
-          // Handle dependency relationships from extended metadata
-          if (
-            'dependencies' in result.metadata &&
-            result.metadata.dependencies
-          ) {
-            const deps = result.metadata.dependencies;
-            const allDeps = [
-              ...(deps.services ?? []),
-              ...(deps.external_apis ?? []),
-              ...(deps.databases ?? []),
-            ];
-            for (const dep of allDeps) {
-              // Store relationship by name - target may not exist yet
-              try {
-                dbManager.insertRelationship(entityId, dep, 'depends_on');
-              } catch {
-                // Target may not exist, skip
+        if (results.length === 0) {
+          const marker = detectGeneratedCode(content);
+          if (marker) {
+            generatedFiles.set(relPath, { hash: fileHash, line: marker.line });
+          }
+        }
+
+        const sidecarLayers = createSidecarLayers(relPath, sidecar);
+
+        timePhase(profiler, 'bind', () => {
+          for (const parsed of results) {
+            const layered = resolveAnnotation(
+              parsed.metadata,
+              `${relPath}:${parsed.line}`,
+              [...sidecarLayers(parsed.name), ...defaultLayers(relPath)],
+              annotations.resolution,
+            );
+            const result = { ...parsed, metadata: layered.metadata };
+            if (!tagsInScope(result.metadata.tags, scopes)) continue;
+            if (layered.error) {
+              errors.push({
+                filePath: relPath,
+                message: `${parsed.name}: ${layered.error}; kept the inline annotation`,
+              });
+            }
+            for (const conflict of layered.conflicts) {
+              conflicts.push({
+                filePath: relPath,
+                entity: parsed.name,
+                ...conflict,
+              });
+            }
+            const entityId = dbManager.insertEntity({
+              filePath: relPath,
+              name: result.name,
+              entityType: result.entityType,
+              description: resolveLocalizedText(
+                result.metadata.description,
+                defaultLocale,
+                defaultLocale,
+              ),
+              rawDocstring: result.rawDocstring,
+              signature: result.signature,
+              parent: result.parent,
+              language: result.language,
+              line: result.line,
+              column: result.column,
+              owner: result.metadata.owner,
+              status: result.metadata.status,
+              metadata: result.metadata,
+              tags: result.metadata.tags,
+              links: result.metadata.links,
+              fileHash,
+            });
+            added.push({
+              id: entityId,
+              filePath: relPath,
+              name: result.name,
+              entityType: result.entityType,
+              parent: result.parent ?? null,
+              line: result.line,
+              rawDocstring: result.rawDocstring ?? null,
+            });
+
+            // Handle dependency relationships from extended metadata
+            if (
+              'dependencies' in result.metadata &&
+              result.metadata.dependencies
+            ) {
+              // Every environment's, as graphs built without one have them
+              const deps = environmentDependencies(
+                result.metadata.dependencies,
+              );
+              const allDeps = [
+                ...(deps.services ?? []),
+                ...(deps.external_apis ?? []),
+                ...(deps.databases ?? []),
+              ].map(dependencyEntryName);
+              for (const dep of allDeps) {
+                // Store relationship by name - target may not exist yet
+                try {
+                  dbManager.insertRelationship(entityId, dep, 'depends_on');
+                } catch {
+                  // Target may not exist, skip
+                }
               }
+              totalRelationships += allDeps.length;
             }
-            totalRelationships += allDeps.length;
-          }
 
-          totalEntities++;
-        }
+            totalEntities++;
+          }
+        });
+        stored = true;
       } catch (err) {
         errors.push({
           filePath: relPath,
@@ -164,6 +479,59 @@ export function createIndexer(
           error: err,
         });
       }
+
+      // Outside the try so a throwing callback is not recorded as a file error
+      if (stored && onFileIndexed) {
+        onFileIndexed(relPath, dbManager.getEntitiesByFilePath(relPath));
+      }
+    }
+
+    // Unannotated generated files inherit their generator's annotation
+    const generators =
+      generatedFiles.size > 0 ? storedGenerators(dbManager) : [];
+    for (const [relPath, file] of generatedFiles) {
+      checkCancelled();
+      const generator = findGenerator(relPath, generators);
+      timePhase(profiler, 'bind', () => {
+        removed.push(...dbManager.getEntitiesByFilePath(relPath));
+        dbManager.deleteEntitiesByFilePath(relPath);
+        if (!generator) return;
+        const metadata = inheritGeneratorMetadata(generator);
+        if (!tagsInScope(metadata.tags, scopes)) return;
+        const name = basename(relPath).replace(/\.[^.]*$/, '');
+        const entityId = dbManager.insertEntity({
+          filePath: relPath,
+          name,
+          entityType: metadata.type,
+          description: resolveLocalizedText(
+            metadata.description,
+            defaultLocale,
+            defaultLocale,
+          ),
+          language: getLanguageFromPath(relPath),
+          line: file.line,
+          column: 1,
+          owner: metadata.owner,
+          status: metadata.status,
+          metadata,
+          tags: metadata.tags,
+          links: metadata.links,
+          fileHash: file.hash,
+        });
+        added.push({
+          id: entityId,
+          filePath: relPath,
+          name,
+          entityType: metadata.type,
+          parent: null,
+          line: file.line,
+          rawDocstring: null,
+        });
+        totalEntities++;
+      });
+      if (generator && onFileIndexed) {
+        onFileIndexed(relPath, dbManager.getEntitiesByFilePath(relPath));
+      }
     }
 
     if (onProgress) {
@@ -175,6 +543,76 @@ export function createIndexer(
       });
     }
 
+    // Drop what an earlier, wider scan stored outside the path scopes, or
+    // in the vendored trees and submodules this walk leaves out, and what
+    // an older index stored under platform separators
+    const submodules = readGitSubmodules(rootDir);
+    for (const filePath of dbManager.getFilePaths()) {
+      if (
+        !pathInScope(filePath, scopes) ||
+        isSkippedByWalk(filePath, submodules, options) ||
+        toPosixPath(filePath) !== filePath
+      ) {
+        dbManager.deleteEntitiesByFilePath(filePath);
+      } else if (!existsSync(join(rootDir, filePath))) {
+        // A deleted file's entities were removed, and may have moved
+        removed.push(...dbManager.getEntitiesByFilePath(filePath));
+        dbManager.deleteEntitiesByFilePath(filePath);
+      }
+    }
+
+    // An id stored again is the same entity; the rest may have moved
+    const before = new Set(removed.map((entity) => entity.id));
+    const after = new Set(added.map((entity) => entity.id));
+    const renames = matchRenames(
+      removed.filter((entity) => !after.has(entity.id)),
+      added.filter((entity) => !before.has(entity.id)),
+      fileRenames,
+    );
+    const pathOf = new Map(
+      [...removed, ...added].map((entity) => [entity.id, entity.filePath]),
+    );
+    for (const rename of renames) {
+      dbManager.recordRename(rename);
+      // Moves between files tell where code went even without git
+      const from = pathOf.get(rename.from);
+      const to = pathOf.get(rename.to);
+      if (from && to) dbManager.recordFileLineage(from, to);
+    }
+
+    // What went without a trace stays visible as a tombstone for a while
+    const renamed = new Set(renames.map((rename) => rename.from));
+    const removedAt = new Date().toISOString();
+    const tombstones = removed
+      .filter((entity) => !after.has(entity.id) && !renamed.has(entity.id))
+      .map(
+        (entity): Tombstone => ({
+          id: entity.id,
+          name: entity.name,
+          entityType: entity.entityType,
+          filePath: entity.filePath,
+          owner: entity.owner,
+          reason: indexable.has(entity.filePath) ? 'removed' : 'file_deleted',
+          removedAt,
+        }),
+      );
+    for (const tombstone of tombstones) dbManager.recordTombstone(tombstone);
+    dbManager.pruneTombstones(
+      new Date(Date.now() - tombstoneRetentionDays * DAY_MS).toISOString(),
+    );
+
+    dbManager.setMeta('schema_version', schemaVersion);
+    dbManager.setMeta('config_hash', configHash);
+    dbManager.setMeta('scopes', scopeKey);
+    dbManager.setMeta(
+      'slow_files',
+      JSON.stringify(Object.fromEntries(slowFiles)),
+    );
+    dbManager.setMeta(
+      'generated_files',
+      JSON.stringify(Object.fromEntries(generatedFiles)),
+    );
+
     const duration = Date.now() - startTime;
 
     return {
@@ -182,9 +620,33 @@ export function createIndexer(
       totalEntities,
       totalRelationships,
       errors,
+      skipped,
+      conflicts,
+      renames,
+      tombstones,
       duration,
+      invalidated:
+        incremental && previousSchema !== undefined && !unchangedSetup,
     };
   }
 
+  /**
+   * Index as one transaction, so other connections see the index before
+   * the scan or after it, never part way. A cancelled scan still commits
+   * the files it stored, which an incremental re-run then resumes from.
+   */
+  function index(options: IndexerOptions): IndexResult {
+    const outcome = dbManager.db.transaction((): IndexResult | Error => {
+      try {
+        return indexFiles(options);
+      } catch (err) {
+        if (isCancellationError(err)) return err;
+        throw err;
+      }
+    })();
+    if (outcome instanceof Error) throw outcome;
+    return outcome;
+  }
+
   return { index };
 }
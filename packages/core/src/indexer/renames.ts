@@ -0,0 +1,178 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Matches entities removed and added by a scan as renames, by git file renames, splits, and merges, position, symbol, and annotation text
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [indexer, renames, refactoring, git, history, lineage]
+ * context:
+ *   business_goal: Keep node ids, history, and external references working across refactors instead of seeing a deletion and an addition
+ *   domain: indexer-engine
+ */
+import { spawnSync } from 'node:child_process';
+import type {
+  EntityRename,
+  FileLineage,
+  RenameCandidate,
+  RenameReason,
+} from './types.js';
+
+/**
+ * Pair each entity a scan removed with the one it added that is the same
+ * code under a new id. Pairs are tried in order: same name, parent, and
+ * type in a file `fileRenames` says the old file was renamed, split, or
+ * merged into, then same file, type, parent, and name (moved within the
+ * file), same file, type, and line (renamed in place), same type, parent,
+ * and name in another file (moved there), and same type and annotation
+ * text (moved and renamed). A key that several removed or several added
+ * entities share pairs none of them.
+ */
+export function matchRenames(
+  removed: readonly RenameCandidate[],
+  added: readonly RenameCandidate[],
+  fileRenames: Readonly<Record<string, string | readonly string[]>> = {},
+): readonly EntityRename[] {
+  const left = new Set(removed);
+  const right = new Set(added);
+  const renames: EntityRename[] = [];
+
+  function pair(
+    reason: RenameReason,
+    key: (entity: RenameCandidate, path: string) => string | undefined,
+    pathsOf: (entity: RenameCandidate) => readonly string[] = (entity) => [
+      entity.filePath,
+    ],
+  ): void {
+    const groups = new Map<string, [RenameCandidate[], RenameCandidate[]]>();
+    const add = (
+      entity: RenameCandidate,
+      side: 0 | 1,
+      paths: readonly string[],
+    ) => {
+      for (const path of paths) {
+        const found = key(entity, path);
+        if (found === undefined) continue;
+        const group = groups.get(found) ?? [[], []];
+        group[side].push(entity);
+        groups.set(found, group);
+      }
+    };
+    for (const entity of left) add(entity, 0, pathsOf(entity));
+    for (const entity of right) add(entity, 1, [entity.filePath]);
+    for (const [before, after] of groups.values()) {
+      if (before.length !== 1 || after.length !== 1) continue;
+      // Lineage can put an entity in several groups; it pairs once
+      if (!left.has(before[0]) || !right.has(after[0])) continue;
+      renames.push({ from: before[0].id, to: after[0].id, reason });
+      left.delete(before[0]);
+      right.delete(after[0]);
+    }
+  }
+
+  const byName = (entity: RenameCandidate, path: string) =>
+    `${path}\0${entity.entityType}\0${entity.parent}\0${entity.name}`;
+  pair('git', byName, (entity) => [fileRenames[entity.filePath] ?? []].flat());
+  pair('position', byName);
+  pair(
+    'position',
+    (entity, path) => `${path}\0${entity.entityType}\0${entity.line}`,
+  );
+  pair('symbol', (entity) => byName(entity, ''));
+  pair('content', (entity) =>
+    entity.rawDocstring
+      ? `${entity.entityType}\0${entity.rawDocstring}`
+      : undefined,
+  );
+  return renames;
+}
+
+/**
+ * `path` and every path its code went to since, following `lineage`
+ * through later renames, splits, and merges.
+ */
+export function followFileLineage(
+  lineage: FileLineage,
+  path: string,
+): readonly string[] {
+  const seen = new Set([path]);
+  for (const from of seen) {
+    for (const to of lineage[from] ?? []) seen.add(to);
+  }
+  return [...seen];
+}
+
+/** The commit `rootDir` has checked out, or undefined outside git. */
+export function readGitHead(
+  rootDir: string,
+  gitPath = 'git',
+): string | undefined {
+  const run = spawnSync(gitPath, ['rev-parse', 'HEAD'], {
+    cwd: rootDir,
+    encoding: 'utf-8',
+  });
+  return run.error || run.status !== 0 ? undefined : run.stdout.trim();
+}
+
+/**
+ * The files git reports renamed between commit `since` and the work
+ * tree, old path to new, relative to `rootDir`. Empty outside git or when
+ * `since` is unknown, such as after a shallow fetch.
+ */
+export function readGitFileRenames(
+  rootDir: string,
+  since: string,
+  gitPath = 'git',
+): Readonly<Record<string, string>> {
+  const run = spawnSync(
+    gitPath,
+    ['diff', '--name-status', '-M', '--relative', '-z', since, '--'],
+    { cwd: rootDir, encoding: 'utf-8', maxBuffer: 64 * 1024 * 1024 },
+  );
+  if (run.error || run.status !== 0) return {};
+  const fields = run.stdout.split('\0');
+  const renames: Record<string, string> = {};
+  for (let i = 0; i < fields.length; i++) {
+    const status = fields[i];
+    if (status.startsWith('R')) {
+      renames[fields[i + 1]] = fields[i + 2];
+      i += 2;
+    } else if (status.startsWith('C')) {
+      i += 2;
+    } else if (status !== '') {
+      i += 1;
+    }
+  }
+  return renames;
+}
+
+/**
+ * The files git reports renamed or copied between commit `since` and the
+ * work tree, each old path to the files that took its code, relative to
+ * `rootDir`. A split shows as a file copied to several others, a merge as
+ * several files renamed or copied to one. New files count once git
+ * tracks them. Empty outside git or when `since` is unknown.
+ */
+export function readGitFileLineage(
+  rootDir: string,
+  since: string,
+  gitPath = 'git',
+): FileLineage {
+  const run = spawnSync(
+    gitPath,
+    ['diff', '--name-status', '-M', '-C', '--relative', '-z', since, '--'],
+    { cwd: rootDir, encoding: 'utf-8', maxBuffer: 64 * 1024 * 1024 },
+  );
+  if (run.error || run.status !== 0) return {};
+  const fields = run.stdout.split('\0');
+  const lineage: Record<string, string[]> = {};
+  for (let i = 0; i < fields.length; i++) {
+    const status = fields[i];
+    if (status.startsWith('R') || status.startsWith('C')) {
+      (lineage[fields[i + 1]] ??= []).push(fields[i + 2]);
+      i += 2;
+    } else if (status !== '') {
+      i += 1;
+    }
+  }
+  return lineage;
+}
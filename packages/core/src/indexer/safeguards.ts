@@ -0,0 +1,109 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Skips oversized, binary, minified, and slow-to-parse files before they can stall or exhaust a scan
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [indexer, safeguards, limits, binary, performance]
+ * context:
+ *   business_goal: Keep a stray 2GB fixture or minified bundle from hanging or crashing a scan
+ *   domain: indexer-engine
+ */
+import { readFileSync, statSync } from 'node:fs';
+import type { FileLimits, SkippedFile } from './types.js';
+
+/** Larger files are almost always generated, vendored, or data. */
+export const DEFAULT_MAX_FILE_BYTES = 1024 * 1024;
+/** Longer lines are minified code, where annotations do not survive. */
+export const DEFAULT_MAX_LINE_LENGTH = 10_000;
+export const DEFAULT_PARSE_TIMEOUT_MS = 5_000;
+
+// As much as git reads to tell binary files from text
+const SNIFF_BYTES = 8000;
+
+/** Whether `bytes` look binary: a NUL byte near the start, as git checks. */
+export function isBinaryContent(bytes: Uint8Array): boolean {
+  return bytes.subarray(0, SNIFF_BYTES).includes(0);
+}
+
+/** The length of the longest line of `content`, without its ending. */
+export function longestLine(content: string): number {
+  let longest = 0;
+  let start = 0;
+  while (start <= content.length) {
+    let end = content.indexOf('\n', start);
+    if (end === -1) end = content.length;
+    const length = end - start - (content[end - 1] === '\r' ? 1 : 0);
+    if (length > longest) longest = length;
+    start = end + 1;
+  }
+  return longest;
+}
+
+/** `limits` with the defaults filled in. */
+export function resolveFileLimits(
+  limits: FileLimits = {},
+): Required<FileLimits> {
+  return {
+    maxFileBytes: limits.maxFileBytes ?? DEFAULT_MAX_FILE_BYTES,
+    maxLineLength: limits.maxLineLength ?? DEFAULT_MAX_LINE_LENGTH,
+    parseTimeoutMs: limits.parseTimeoutMs ?? DEFAULT_PARSE_TIMEOUT_MS,
+  };
+}
+
+/**
+ * Read `absPath` as UTF-8 unless `limits` skip it. The size is checked
+ * before anything is read, so an oversized file costs one `stat`. Throws
+ * when the file cannot be read. `filePath` is what skips report.
+ */
+export function readLimitedFile(
+  absPath: string,
+  filePath: string,
+  limits: Required<FileLimits>,
+): { readonly content: string } | { readonly skipped: SkippedFile } {
+  const { size } = statSync(absPath);
+  if (size > limits.maxFileBytes) {
+    return {
+      skipped: {
+        filePath,
+        reason: 'too-large',
+        message: `${size} bytes is over the ${limits.maxFileBytes} byte limit`,
+      },
+    };
+  }
+  const bytes = readFileSync(absPath);
+  if (isBinaryContent(bytes)) {
+    return {
+      skipped: { filePath, reason: 'binary', message: 'Binary content' },
+    };
+  }
+  const content = bytes.toString('utf-8');
+  const longest = longestLine(content);
+  if (longest > limits.maxLineLength) {
+    return {
+      skipped: {
+        filePath,
+        reason: 'minified',
+        message:
+          `A ${longest} character line is over the ` +
+          `${limits.maxLineLength} character limit`,
+      },
+    };
+  }
+  return { content };
+}
+
+/** The skip for a file whose parse took `ms`, over `limits`. */
+export function slowParse(
+  filePath: string,
+  ms: number,
+  limits: Required<FileLimits>,
+): SkippedFile {
+  return {
+    filePath,
+    reason: 'slow',
+    message:
+      `Parsing took ${Math.round(ms)}ms, over the ` +
+      `${limits.parseTimeoutMs}ms limit; skipped until it changes`,
+  };
+}
@@ -9,6 +9,13 @@
  *   business_goal: Provide persistent storage schema for the code knowledge graph
  *   domain: indexer-engine
  */
+/**
+ * Version of the stored entity shape. Bump it when parsing or storage
+ * changes so incremental indexes and graph caches rebuild instead of
+ * reusing stale rows.
+ */
+export const INDEX_SCHEMA_VERSION = 3;
+
 export const CREATE_TABLES_SQL = `
   CREATE TABLE IF NOT EXISTS entities (
     id TEXT PRIMARY KEY,
@@ -52,6 +59,55 @@ export const CREATE_TABLES_SQL = `
     title TEXT
   );
 
+  CREATE TABLE IF NOT EXISTS index_meta (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+  );
+
+  -- Read model: what depends on each service, database, and external API,
+  -- rebuilt from the dependency graph after every index
+  CREATE TABLE IF NOT EXISTS dependents (
+    target_id TEXT NOT NULL,
+    target_name TEXT NOT NULL,
+    source_id TEXT NOT NULL,
+    source_name TEXT NOT NULL,
+    entity_type TEXT,
+    owner TEXT,
+    file_path TEXT,
+    kind TEXT NOT NULL,
+    depth INTEGER NOT NULL,
+    PRIMARY KEY (target_id, source_id)
+  );
+
+  -- Ids entities had before a rename or move, each pointing at the id in
+  -- use now, so references to old ids keep resolving
+  CREATE TABLE IF NOT EXISTS entity_renames (
+    from_id TEXT PRIMARY KEY,
+    to_id TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    renamed_at TEXT NOT NULL DEFAULT (datetime('now'))
+  );
+
+  -- Entities scans removed, kept for the retention window so consumers
+  -- of the index can process deletions
+  CREATE TABLE IF NOT EXISTS tombstones (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    owner TEXT,
+    reason TEXT NOT NULL,
+    removed_at TEXT NOT NULL
+  );
+
+  -- Files whose code went to other files in a rename, split, or merge, so
+  -- what is keyed by file path can follow it
+  CREATE TABLE IF NOT EXISTS file_lineage (
+    from_path TEXT NOT NULL,
+    to_path TEXT NOT NULL,
+    PRIMARY KEY (from_path, to_path)
+  );
+
   CREATE VIRTUAL TABLE IF NOT EXISTS entities_fts USING fts5(
     entity_id UNINDEXED,
     name, description, tags_text, owner
@@ -65,6 +121,8 @@ export const CREATE_TABLES_SQL = `
   CREATE INDEX IF NOT EXISTS idx_links_entity ON links(entity_id);
   CREATE INDEX IF NOT EXISTS idx_relationships_source ON relationships(source_id);
   CREATE INDEX IF NOT EXISTS idx_relationships_target ON relationships(target_id);
+  CREATE INDEX IF NOT EXISTS idx_dependents_target_name
+    ON dependents(target_name COLLATE NOCASE);
 `;
 
 export const INSERT_ENTITY_SQL = `
@@ -127,3 +185,30 @@ export const INSERT_LINK_SQL = `
   INSERT INTO links (entity_id, link_type, url, title)
   VALUES (@entity_id, @link_type, @url, @title)
 `;
+
+export const INSERT_TOMBSTONE_SQL = `
+  INSERT OR REPLACE INTO tombstones (
+    id, name, entity_type, file_path, owner, reason, removed_at
+  ) VALUES (
+    @id, @name, @entity_type, @file_path, @owner, @reason, @removed_at
+  )
+`;
+
+export const INSERT_RENAME_SQL = `
+  INSERT INTO entity_renames (from_id, to_id, reason)
+  VALUES (@from_id, @to_id, @reason)
+  ON CONFLICT(from_id) DO UPDATE SET
+    to_id = excluded.to_id,
+    reason = excluded.reason,
+    renamed_at = datetime('now')
+`;
+
+export const INSERT_DEPENDENT_SQL = `
+  INSERT OR IGNORE INTO dependents (
+    target_id, target_name, source_id, source_name, entity_type, owner,
+    file_path, kind, depth
+  ) VALUES (
+    @target_id, @target_name, @source_id, @source_name, @entity_type, @owner,
+    @file_path, @kind, @depth
+  )
+`;
@@ -16,6 +16,13 @@ import type {
   Link,
   Status,
 } from '../types/index.js';
+import type {
+  AnnotationConflict,
+  AnnotationLayerOptions,
+} from '../annotations/types.js';
+import type { CancellationOptions } from '../cancellation/cancellation.js';
+import type { PhaseTimer } from '../profiling/types.js';
+import type { ScanScope } from '../scope/types.js';
 
 export interface StoredEntity {
   readonly id: string;
@@ -67,12 +74,109 @@ export interface IndexStats {
   readonly entitiesByLanguage: Readonly<Record<string, number>>;
 }
 
-export interface IndexerOptions {
+/** What the file walk does with links, submodules, and vendored code. */
+export interface WalkOptions {
+  /**
+   * Follow symlinked directories that lead outside the repository. Links
+   * back into the repository or into a tree already walked are skipped,
+   * so cycles end and nothing is indexed twice.
+   */
+  readonly followSymlinks?: boolean;
+  /** Descend into the git submodules `.gitmodules` lists. */
+  readonly submodules?: boolean;
+  /** Descend into `vendor/` directories, whose entities are external. */
+  readonly vendor?: boolean;
+}
+
+/** A git submodule of the repository, from its `.gitmodules`. */
+export interface GitSubmodule {
+  readonly name: string;
+  /** Directory relative to the repository root, with `/` separators. */
+  readonly path: string;
+  readonly url: string | null;
+  /** The `org/repo` of its URL, else its name, as a valid namespace. */
+  readonly namespace: string;
+}
+
+/**
+ * Limits that keep one file from stalling or exhausting a scan. Files over
+ * them are skipped and reported rather than parsed.
+ */
+export interface FileLimits {
+  /** Skip files larger than this many bytes, before reading them. */
+  readonly maxFileBytes?: number;
+  /** Skip files with a line longer than this, such as minified bundles. */
+  readonly maxLineLength?: number;
+  /**
+   * Drop what a file parsed when parsing it took longer than this, and
+   * skip it until it changes. A parse cannot be interrupted, so the limit
+   * bounds how often a slow file is paid for rather than how long.
+   */
+  readonly parseTimeoutMs?: number;
+}
+
+/**
+ * Why a file was skipped:
+ * - `too-large`: over `maxFileBytes`
+ * - `binary`: holds NUL bytes, so it is not text
+ * - `minified`: has a line over `maxLineLength`
+ * - `slow`: parsing it took longer than `parseTimeoutMs`
+ */
+export type SkipReason = 'too-large' | 'binary' | 'minified' | 'slow';
+
+export interface SkippedFile {
+  readonly filePath: string;
+  readonly reason: SkipReason;
+  readonly message: string;
+}
+
+export interface IndexerOptions
+  extends CancellationOptions, WalkOptions, FileLimits {
   readonly rootDir: string;
   readonly outputDir?: string;
   readonly exclude?: readonly string[];
   readonly incremental?: boolean;
+  /**
+   * Hash of the configuration that shaped this index. When it (or the
+   * schema version) differs from the last run, incremental indexing
+   * re-parses every file.
+   */
+  readonly configHash?: string;
+  /** Records time spent in the walk, parse, and bind phases. */
+  readonly profiler?: PhaseTimer;
+  /** Locale stored as the searchable description for localized annotations. */
+  readonly defaultLocale?: string;
+  /**
+   * Index only files under the path scopes and entities matching the tag
+   * scopes (see `entityInScope`). Entities of files outside the path
+   * scopes are removed, so the index holds just the subgraph.
+   */
+  readonly scopes?: readonly ScanScope[];
+  /**
+   * How sidecar files (`<file>.knowgraph.yml`) and path defaults layer
+   * onto inline annotations. Sidecars are read whenever they exist.
+   */
+  readonly annotations?: AnnotationLayerOptions;
+  /**
+   * Files renamed since the last run, old path to new, such as from
+   * `readGitFileRenames`, or old path to every file that took its code
+   * when it was split or merged, as from `readGitFileLineage`. The
+   * entities under an old path are dropped when a new one is indexed, can
+   * be matched as renames, and the lineage is kept (see
+   * `DatabaseManager.getFileLineage`).
+   */
+  readonly fileRenames?: Readonly<Record<string, string | readonly string[]>>;
+  /**
+   * How many days tombstones of removed entities are kept. Defaults to
+   * `DEFAULT_TOMBSTONE_RETENTION_DAYS`.
+   */
+  readonly tombstoneRetentionDays?: number;
   readonly onProgress?: (progress: IndexProgress) => void;
+  /** Called after each file is stored, with the entities parsed from it. */
+  readonly onFileIndexed?: (
+    filePath: string,
+    entities: readonly StoredEntity[],
+  ) => void;
 }
 
 export interface IndexProgress {
@@ -87,9 +191,66 @@ export interface IndexResult {
   readonly totalEntities: number;
   readonly totalRelationships: number;
   readonly errors: readonly IndexError[];
+  /** Files the `FileLimits` kept from being parsed, in walk order. */
+  readonly skipped: readonly SkippedFile[];
+  /** Fields annotated differently in several places, in files parsed now. */
+  readonly conflicts: readonly AnnotationConflict[];
+  /** Entities found again under a new id, in `matchRenames` order. */
+  readonly renames: readonly EntityRename[];
+  /** Entities this run removed that were not found again. */
+  readonly tombstones: readonly Tombstone[];
   readonly duration: number;
+  /** Set when an incremental run re-parsed all files after a config change. */
+  readonly invalidated?: boolean;
+}
+
+/**
+ * How a rename was detected:
+ * - `git`: same name and type in a file git reports renamed, split, or
+ *   merged
+ * - `position`: same file and type, and same name or same line
+ * - `symbol`: same name, parent, and type, in another file
+ * - `content`: same type and annotation text, anywhere
+ */
+export type RenameReason = 'git' | 'position' | 'symbol' | 'content';
+
+/**
+ * Why an entity was removed: its file was deleted, or it is gone from a
+ * file that is still indexed.
+ */
+export type TombstoneReason = 'file_deleted' | 'removed';
+
+/**
+ * An entity a scan removed, kept for a while so consumers of the index
+ * can process the deletion instead of seeing the entity vanish.
+ */
+export interface Tombstone {
+  readonly id: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly owner: string | null;
+  readonly reason: TombstoneReason;
+  /** ISO 8601 time of the scan that removed it. */
+  readonly removedAt: string;
 }
 
+/** Each old file path to the files its code went to. */
+export type FileLineage = Readonly<Record<string, readonly string[]>>;
+
+/** An entity that went from one id to another between two scans. */
+export interface EntityRename {
+  readonly from: string;
+  readonly to: string;
+  readonly reason: RenameReason;
+}
+
+/** What `matchRenames` compares of a removed or added entity. */
+export type RenameCandidate = Pick<
+  StoredEntity,
+  'id' | 'filePath' | 'name' | 'entityType' | 'parent' | 'line' | 'rawDocstring'
+>;
+
 export interface IndexError {
   readonly filePath: string;
   readonly message: string;
@@ -0,0 +1,193 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Walks a repository's files without looping on symlinks, skipping git submodules and vendored trees unless asked
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [indexer, walker, filesystem, symlinks, submodules, vendor]
+ * context:
+ *   business_goal: Scan real repositories with linked, nested, and vendored code without hanging or double counting
+ *   domain: indexer-engine
+ */
+import { readFileSync, readdirSync, realpathSync, statSync } from 'node:fs';
+import type { Dirent } from 'node:fs';
+import { join, sep } from 'node:path';
+import type { Ignore } from 'ignore';
+import { compareStrings } from '../canonical/canonical.js';
+import { NAMESPACE_PATTERN } from '../namespace/namespace.js';
+import { toPosixPath } from '../paths/paths.js';
+import type { GitSubmodule, WalkOptions } from './types.js';
+
+/** The directory vendored dependencies are kept in, at any depth. */
+export const VENDOR_DIRECTORY = 'vendor';
+
+function isInside(base: string, path: string): boolean {
+  return path === base || path.startsWith(base + sep);
+}
+
+/** Whether `filePath` lies in a `vendor/` directory. */
+export function isVendoredPath(filePath: string): boolean {
+  return filePath.split(/[\\/]/).slice(0, -1).includes(VENDOR_DIRECTORY);
+}
+
+/** `org/repo` from the last two segments of a git URL, when they form one. */
+function namespaceFromUrl(url: string): string | undefined {
+  const segments = url
+    .replace(/\.git\/*$/, '')
+    .split(/[/:]/)
+    .filter(Boolean);
+  const namespace = segments.slice(-2).join('/');
+  return NAMESPACE_PATTERN.test(namespace) ? namespace : undefined;
+}
+
+function submoduleNamespace(name: string, url: string | null): string {
+  const fromUrl = url ? namespaceFromUrl(url) : undefined;
+  if (fromUrl) return fromUrl;
+  const cleaned = name
+    .split('/')
+    .map((segment) => segment.replace(/[^A-Za-z0-9._-]/g, '-'))
+    .map((segment) => segment.replace(/^[^A-Za-z0-9]+/, ''))
+    .filter(Boolean)
+    .join('/');
+  return cleaned || 'submodule';
+}
+
+/**
+ * The submodules a `.gitmodules` file declares, in file order. Sections
+ * without a `path` are left out.
+ */
+export function parseGitmodules(content: string): readonly GitSubmodule[] {
+  const sections: { name: string; path?: string; url?: string }[] = [];
+  for (const line of content.split(/\r?\n/)) {
+    const header = /^\s*\[submodule\s+"([^"]+)"\]\s*$/.exec(line);
+    if (header) {
+      sections.push({ name: header[1] });
+      continue;
+    }
+    const entry = /^\s*(path|url)\s*=\s*(.*?)\s*$/.exec(line);
+    const section = sections[sections.length - 1];
+    if (entry && section) section[entry[1] as 'path' | 'url'] = entry[2];
+  }
+  return sections.flatMap(({ name, path, url }) =>
+    path
+      ? [
+          {
+            name,
+            path: path.replace(/\/+$/, ''),
+            url: url ?? null,
+            namespace: submoduleNamespace(name, url ?? null),
+          },
+        ]
+      : [],
+  );
+}
+
+/** The submodules in `rootDir`'s `.gitmodules`; none without one. */
+export function readGitSubmodules(rootDir: string): readonly GitSubmodule[] {
+  try {
+    const content = readFileSync(join(rootDir, '.gitmodules'), 'utf-8');
+    return parseGitmodules(content);
+  } catch {
+    return [];
+  }
+}
+
+/** The innermost of `submodules` containing `filePath`, if any. */
+export function findSubmodule(
+  filePath: string,
+  submodules: readonly GitSubmodule[],
+): GitSubmodule | undefined {
+  const path = toPosixPath(filePath);
+  let found: GitSubmodule | undefined;
+  for (const submodule of submodules) {
+    if (!path.startsWith(`${submodule.path}/`)) continue;
+    if (!found || submodule.path.length > found.path.length) found = submodule;
+  }
+  return found;
+}
+
+/**
+ * Whether a walk with `options` leaves `filePath` out for being vendored
+ * or inside one of `submodules`, so an index can drop what an earlier walk
+ * stored there.
+ */
+export function isSkippedByWalk(
+  filePath: string,
+  submodules: readonly GitSubmodule[],
+  options: WalkOptions,
+): boolean {
+  return (
+    (!options.vendor && isVendoredPath(filePath)) ||
+    (!options.submodules && findSubmodule(filePath, submodules) !== undefined)
+  );
+}
+
+/**
+ * The files under `rootDir` that `ignored` leaves, relative to it with
+ * `/` separators on every platform, in name order. Dot files and
+ * directories are skipped, as are submodules and `vendor/` unless
+ * `options` asks for them. Symlinked files are kept; symlinked directories
+ * are followed only with `followSymlinks`, and only out of the repository
+ * into a tree not already walked.
+ */
+export function walkFiles(
+  rootDir: string,
+  ignored: Ignore,
+  options: WalkOptions = {},
+): readonly string[] {
+  const submodules = new Set(readGitSubmodules(rootDir).map((s) => s.path));
+  // The repository and each tree a link led into, in real paths
+  const walked = [realpathSync(rootDir)];
+  const files: string[] = [];
+
+  function skipsDirectory(relPath: string, name: string): boolean {
+    return (
+      ignored.ignores(`${relPath}/`) ||
+      (!options.vendor && name === VENDOR_DIRECTORY) ||
+      (!options.submodules && submodules.has(relPath))
+    );
+  }
+
+  function visit(dir: string, prefix: string): void {
+    let entries: Dirent[];
+    try {
+      entries = readdirSync(dir, { withFileTypes: true });
+    } catch {
+      return;
+    }
+    entries.sort((a, b) => compareStrings(a.name, b.name));
+    for (const entry of entries) {
+      if (entry.name.startsWith('.')) continue;
+      const absPath = join(dir, entry.name);
+      const relPath = prefix ? `${prefix}/${entry.name}` : entry.name;
+      let isDirectory = entry.isDirectory();
+      if (entry.isSymbolicLink()) {
+        try {
+          isDirectory = statSync(absPath).isDirectory();
+        } catch {
+          continue; // A dangling link
+        }
+        if (isDirectory) {
+          if (!options.followSymlinks) continue;
+          const real = realpathSync(absPath);
+          if (walked.some((base) => isInside(base, real))) continue;
+          if (skipsDirectory(relPath, entry.name)) continue;
+          walked.push(real);
+          visit(absPath, relPath);
+          continue;
+        }
+      }
+      if (isDirectory) {
+        if (!skipsDirectory(relPath, entry.name)) visit(absPath, relPath);
+      } else if (
+        (entry.isFile() || entry.isSymbolicLink()) &&
+        !ignored.ignores(relPath)
+      ) {
+        files.push(relPath);
+      }
+    }
+  }
+
+  visit(rootDir, '');
+  return files;
+}
@@ -0,0 +1,140 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import {
+  createKubernetesClient,
+  inClusterClientOptions,
+} from '../client.js';
+
+function mockFetch(body: unknown, status = 200): ReturnType<typeof vi.fn> {
+  const fn = vi.fn(async () => ({
+    ok: status < 400,
+    status,
+    statusText: status < 400 ? 'OK' : 'Forbidden',
+    json: async () => body,
+  }));
+  vi.stubGlobal('fetch', fn);
+  return fn;
+}
+
+function request(fn: ReturnType<typeof vi.fn>, index = 0) {
+  const [url, init] = fn.mock.calls[index] as [string, RequestInit];
+  return {
+    url,
+    method: init.method,
+    headers: init.headers as Record<string, string>,
+    body: init.body ? JSON.parse(init.body as string) : undefined,
+  };
+}
+
+const scan = {
+  name: 'payments',
+  namespace: 'platform',
+  uid: 'uid-1',
+  spec: {
+    repo: 'https://github.com/acme/payments.git',
+    selector: { matchLabels: {} },
+    topOwners: 5,
+  },
+};
+
+describe('inClusterClientOptions', () => {
+  it('reads the service host and account token', () => {
+    const options = inClusterClientOptions(
+      { KUBERNETES_SERVICE_HOST: '10.0.0.1', KUBERNETES_SERVICE_PORT: '6443' },
+      () => 'secret\n',
+    );
+    expect(options).toEqual({
+      apiUrl: 'https://10.0.0.1:6443',
+      token: 'secret',
+    });
+  });
+
+  it('is undefined outside a cluster', () => {
+    expect(inClusterClientOptions({}, () => '')).toBeUndefined();
+  });
+});
+
+describe('createKubernetesClient', () => {
+  const client = createKubernetesClient({
+    apiUrl: 'https://k8s.test/',
+    token: 'secret',
+  });
+
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('lists scans across namespaces', async () => {
+    const fn = mockFetch({
+      items: [{ metadata: scan, spec: scan.spec }],
+    });
+    const scans = await client.listScans();
+    expect(scans.map((s) => s.name)).toEqual(['payments']);
+    const { url, headers } = request(fn);
+    expect(url).toBe(
+      'https://k8s.test/apis/knowgraph.dev/v1alpha1/knowgraphscans',
+    );
+    expect(headers.Authorization).toBe('Bearer secret');
+  });
+
+  it('lists deployments by label selector', async () => {
+    const fn = mockFetch({
+      items: [{ metadata: { name: 'checkout', namespace: 'payments' } }],
+    });
+    const deployments = await client.listDeployments('payments', {
+      matchLabels: { team: 'payments', tier: 'web' },
+    });
+    expect(deployments).toEqual([
+      { name: 'checkout', namespace: 'payments', labels: {} },
+    ]);
+    expect(request(fn).url).toBe(
+      'https://k8s.test/apis/apps/v1/namespaces/payments/deployments?labelSelector=team%3Dpayments%2Ctier%3Dweb',
+    );
+  });
+
+  it('applies summaries owned by their scan', async () => {
+    const fn = mockFetch({});
+    await client.applyConfigMap(scan, 'knowgraph-payments', { a: 'b' });
+    const { url, method, headers, body } = request(fn);
+    expect(method).toBe('PATCH');
+    expect(url).toContain(
+      '/api/v1/namespaces/platform/configmaps/knowgraph-payments?fieldManager=knowgraph-operator&force=true',
+    );
+    expect(headers['Content-Type']).toBe('application/apply-patch+yaml');
+    expect(body.metadata.ownerReferences[0]).toMatchObject({
+      kind: 'KnowGraphScan',
+      uid: 'uid-1',
+    });
+    expect(body.data).toEqual({ a: 'b' });
+  });
+
+  it('clears status fields the new status leaves out', async () => {
+    const fn = mockFetch({});
+    await client.updateScanStatus(scan, {
+      phase: 'Ready',
+      lastScanTime: 'now',
+      entities: 4,
+    });
+    const { url, body } = request(fn);
+    expect(url).toContain(
+      '/namespaces/platform/knowgraphscans/payments/status',
+    );
+    expect(body.status).toEqual({
+      phase: 'Ready',
+      lastScanTime: 'now',
+      commit: null,
+      entities: 4,
+      workloads: null,
+      message: null,
+    });
+  });
+
+  it('reports the API server message on failure', async () => {
+    mockFetch({ message: 'deployments is forbidden' }, 403);
+    await expect(
+      client.annotateDeployment(
+        { name: 'checkout', namespace: 'payments', labels: {} },
+        { 'knowgraph.dev/owner': 'team' },
+      ),
+    ).rejects.toThrow('403 deployments is forbidden');
+  });
+});
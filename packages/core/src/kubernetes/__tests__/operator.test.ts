@@ -0,0 +1,214 @@
+import { describe, it, expect } from 'vitest';
+import {
+  parseKnowGraphScan,
+  reconcileScans,
+  summarizeGraph,
+  workloadAnnotations,
+} from '../operator.js';
+import type {
+  KnowGraphScan,
+  KnowGraphScanStatus,
+  KubernetesClient,
+  KubernetesDeployment,
+  RepoScan,
+} from '../types.js';
+
+const scanResource = {
+  apiVersion: 'knowgraph.dev/v1alpha1',
+  kind: 'KnowGraphScan',
+  metadata: {
+    name: 'payments',
+    namespace: 'platform',
+    uid: 'uid-1',
+    generation: 3,
+  },
+  spec: {
+    repo: 'https://github.com/acme/payments.git',
+    selector: { matchLabels: { team: 'payments' } },
+    targetNamespace: 'payments',
+  },
+};
+
+const repoScan: RepoScan = {
+  commit: 'abc123',
+  entities: [
+    { name: 'checkout', entityType: 'service', owner: 'team-checkout' },
+    { name: 'charge', entityType: 'function', owner: 'team-billing' },
+    { name: 'refund', entityType: 'function', owner: 'team-billing' },
+    { name: 'helpers', entityType: 'module', owner: null },
+  ],
+};
+
+function fakeClient(
+  scans: readonly KnowGraphScan[],
+  deployments: readonly KubernetesDeployment[],
+) {
+  const calls = {
+    configMaps: [] as { name: string; data: Record<string, string> }[],
+    annotations: [] as {
+      name: string;
+      annotations: Record<string, string | null>;
+    }[],
+    statuses: [] as { name: string; status: KnowGraphScanStatus }[],
+    listedIn: [] as string[],
+  };
+  const client: KubernetesClient = {
+    listScans: async () => scans,
+    listDeployments: async (namespace) => {
+      calls.listedIn.push(namespace);
+      return deployments;
+    },
+    applyConfigMap: async (_owner, name, data) => {
+      calls.configMaps.push({ name, data: { ...data } });
+    },
+    annotateDeployment: async (deployment, annotations) => {
+      calls.annotations.push({
+        name: deployment.name,
+        annotations: { ...annotations },
+      });
+    },
+    updateScanStatus: async (scan, status) => {
+      calls.statuses.push({ name: scan.name, status });
+    },
+  };
+  return { client, calls };
+}
+
+describe('parseKnowGraphScan', () => {
+  it('reads metadata and fills spec defaults', () => {
+    expect(parseKnowGraphScan(scanResource)).toEqual({
+      name: 'payments',
+      namespace: 'platform',
+      uid: 'uid-1',
+      generation: 3,
+      spec: {
+        repo: 'https://github.com/acme/payments.git',
+        selector: { matchLabels: { team: 'payments' } },
+        targetNamespace: 'payments',
+        topOwners: 5,
+      },
+    });
+  });
+
+  it('names the first invalid field', () => {
+    const spec = { selector: scanResource.spec.selector };
+    expect(() => parseKnowGraphScan({ ...scanResource, spec })).toThrow(
+      'Invalid KnowGraphScan: spec.repo Required',
+    );
+  });
+
+  it('only accepts git URLs', () => {
+    const spec = { ...scanResource.spec, repo: '/etc' };
+    expect(() => parseKnowGraphScan({ ...scanResource, spec })).toThrow(
+      'Invalid KnowGraphScan: spec.repo must be a git URL without a #ref',
+    );
+    const local = { ...scanResource.spec, repo: 'file:///etc/repo' };
+    expect(() => parseKnowGraphScan({ ...scanResource, spec: local })).toThrow(
+      'spec.repo must not be a file:// URL',
+    );
+    const option = { ...scanResource.spec, ref: '--upload-pack=touch x' };
+    expect(() => parseKnowGraphScan({ ...scanResource, spec: option })).toThrow(
+      'spec.ref must be a valid git branch, tag, or commit',
+    );
+  });
+});
+
+describe('summarizeGraph', () => {
+  it('counts owners busiest first and records service owners', () => {
+    const summary = summarizeGraph('acme/payments', repoScan, 'now', 1);
+    expect(summary).toEqual({
+      repo: 'acme/payments',
+      commit: 'abc123',
+      scannedAt: 'now',
+      entities: 4,
+      ownedPercent: 75,
+      topOwners: [{ owner: 'team-billing', entities: 2 }],
+      services: { checkout: 'team-checkout' },
+    });
+  });
+});
+
+describe('workloadAnnotations', () => {
+  const summary = summarizeGraph('acme/payments', repoScan, 'now');
+
+  it('uses the owner of the service named by the app label', () => {
+    const annotations = workloadAnnotations(
+      summary,
+      {
+        name: 'checkout-v2',
+        namespace: 'payments',
+        labels: { 'app.kubernetes.io/name': 'checkout' },
+      },
+      'platform/knowgraph-payments',
+    );
+    expect(annotations).toEqual({
+      'knowgraph.dev/owner': 'team-checkout',
+      'knowgraph.dev/service': 'checkout',
+      'knowgraph.dev/repo': 'acme/payments',
+      'knowgraph.dev/commit': 'abc123',
+      'knowgraph.dev/summary': 'platform/knowgraph-payments',
+    });
+  });
+
+  it('falls back to the busiest owner when no service matches', () => {
+    const annotations = workloadAnnotations(
+      summary,
+      { name: 'worker', namespace: 'payments', labels: {} },
+      'platform/knowgraph-payments',
+    );
+    expect(annotations['knowgraph.dev/owner']).toBe('team-billing');
+    expect(annotations['knowgraph.dev/service']).toBeNull();
+  });
+});
+
+describe('reconcileScans', () => {
+  const scan = parseKnowGraphScan(scanResource);
+  const now = () => '2026-10-14T00:00:00.000Z';
+
+  it('publishes the summary, annotates workloads, and reports Ready', async () => {
+    const { client, calls } = fakeClient(
+      [scan],
+      [{ name: 'checkout', namespace: 'payments', labels: {} }],
+    );
+    const results = await reconcileScans(client, async () => repoScan, {
+      now,
+    });
+
+    expect(calls.configMaps.map((c) => c.name)).toEqual([
+      'knowgraph-payments',
+    ]);
+    expect(
+      JSON.parse(calls.configMaps[0]?.data['summary.json'] ?? '{}'),
+    ).toMatchObject({ entities: 4, commit: 'abc123' });
+    expect(calls.listedIn).toEqual(['payments']);
+    expect(calls.annotations[0]?.annotations['knowgraph.dev/owner']).toBe(
+      'team-checkout',
+    );
+    expect(results[0]?.status).toEqual({
+      phase: 'Ready',
+      observedGeneration: 3,
+      lastScanTime: '2026-10-14T00:00:00.000Z',
+      commit: 'abc123',
+      entities: 4,
+      workloads: 1,
+    });
+    expect(calls.statuses).toHaveLength(1);
+  });
+
+  it('records a failed scan and carries on with the next', async () => {
+    const other = { ...scan, name: 'ledger' };
+    const { client, calls } = fakeClient([scan, other], []);
+    const results = await reconcileScans(
+      client,
+      async (target) => {
+        if (target.name === 'payments') throw new Error('clone failed');
+        return repoScan;
+      },
+      { now },
+    );
+
+    expect(results.map((r) => r.status.phase)).toEqual(['Failed', 'Ready']);
+    expect(calls.statuses[0]?.status.message).toBe('clone failed');
+    expect(calls.configMaps.map((c) => c.name)).toEqual(['knowgraph-ledger']);
+  });
+});
@@ -0,0 +1,198 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Fetch-based Kubernetes API client for the calls the KnowGraphScan operator makes, with in-cluster service account configuration
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [kubernetes, operator, api, client]
+ * context:
+ *   business_goal: Talk to the Kubernetes API with nothing but the pod's own service account
+ *   domain: kubernetes
+ */
+import { readFileSync } from 'node:fs';
+import { createKnowgraphError } from '../errors/errors.js';
+import {
+  KNOWGRAPH_API_GROUP,
+  KNOWGRAPH_API_VERSION,
+  parseKnowGraphScan,
+} from './operator.js';
+import type {
+  KnowGraphScan,
+  KubernetesClient,
+  KubernetesClientOptions,
+  KubernetesDeployment,
+} from './types.js';
+
+/** Where Kubernetes mounts a pod's service account. */
+export const SERVICE_ACCOUNT_DIR =
+  '/var/run/secrets/kubernetes.io/serviceaccount';
+
+const FIELD_MANAGER = 'knowgraph-operator';
+
+/** Status fields a later status may leave out, cleared when it does. */
+const STATUS_FIELDS = {
+  commit: null,
+  entities: null,
+  workloads: null,
+  message: null,
+};
+
+interface ObjectList {
+  readonly items?: readonly unknown[];
+}
+
+interface DeploymentObject {
+  readonly metadata: {
+    readonly name: string;
+    readonly namespace: string;
+    readonly labels?: Record<string, string>;
+  };
+}
+
+/**
+ * Options for a client running in a pod, from the service account token
+ * and the `KUBERNETES_SERVICE_*` variables. Undefined outside a cluster.
+ * The API server's certificate is signed by the cluster CA, which Node
+ * trusts once `NODE_EXTRA_CA_CERTS` points at the mounted `ca.crt`.
+ */
+export function inClusterClientOptions(
+  env: Readonly<Record<string, string | undefined>> = process.env,
+  readFile: (path: string) => string = (path) => readFileSync(path, 'utf-8'),
+): KubernetesClientOptions | undefined {
+  const host = env.KUBERNETES_SERVICE_HOST;
+  if (!host) return undefined;
+  const port = env.KUBERNETES_SERVICE_PORT ?? '443';
+  // IPv6 service addresses need brackets in a URL.
+  const authority = host.includes(':') ? `[${host}]` : host;
+  return {
+    apiUrl: `https://${authority}:${port}`,
+    token: readFile(`${SERVICE_ACCOUNT_DIR}/token`).trim(),
+  };
+}
+
+function scansPath(namespace?: string): string {
+  const base = `/apis/${KNOWGRAPH_API_GROUP}/${KNOWGRAPH_API_VERSION}`;
+  return namespace
+    ? `${base}/namespaces/${encodeURIComponent(namespace)}/knowgraphscans`
+    : `${base}/knowgraphscans`;
+}
+
+export function createKubernetesClient(
+  options: KubernetesClientOptions,
+): KubernetesClient {
+  const baseUrl = options.apiUrl.replace(/\/+$/, '');
+  const headers: Record<string, string> = { Accept: 'application/json' };
+  if (options.token) headers.Authorization = `Bearer ${options.token}`;
+
+  async function call(
+    method: string,
+    path: string,
+    body?: { readonly contentType: string; readonly value: unknown },
+  ): Promise<unknown> {
+    const response = await fetch(`${baseUrl}${path}`, {
+      method,
+      headers: body
+        ? { ...headers, 'Content-Type': body.contentType }
+        : headers,
+      body: body ? JSON.stringify(body.value) : undefined,
+    });
+    if (!response.ok) {
+      // Failures come back as a Status object with a readable message.
+      const status = (await response.json().catch(() => ({}))) as {
+        message?: string;
+      };
+      throw createKnowgraphError(
+        'io',
+        `Kubernetes API error: ${method} ${path}: ${response.status} ${status.message ?? response.statusText}`,
+      );
+    }
+    return response.json();
+  }
+
+  return {
+    async listScans(): Promise<readonly KnowGraphScan[]> {
+      const list = (await call(
+        'GET',
+        scansPath(options.namespace),
+      )) as ObjectList;
+      return (list.items ?? []).map(parseKnowGraphScan);
+    },
+
+    async listDeployments(namespace, selector) {
+      const labels = Object.entries(selector.matchLabels)
+        .map(([key, value]) => `${key}=${value}`)
+        .join(',');
+      const query = labels
+        ? `?labelSelector=${encodeURIComponent(labels)}`
+        : '';
+      const list = (await call(
+        'GET',
+        `/apis/apps/v1/namespaces/${encodeURIComponent(namespace)}/deployments${query}`,
+      )) as ObjectList;
+      return (list.items ?? []).map((item): KubernetesDeployment => {
+        const { metadata } = item as DeploymentObject;
+        return {
+          name: metadata.name,
+          namespace: metadata.namespace,
+          labels: metadata.labels ?? {},
+        };
+      });
+    },
+
+    async applyConfigMap(owner, name, data) {
+      const namespace = encodeURIComponent(owner.namespace);
+      // Server-side apply creates or replaces in one call; JSON is YAML.
+      await call(
+        'PATCH',
+        `/api/v1/namespaces/${namespace}/configmaps/${encodeURIComponent(name)}?fieldManager=${FIELD_MANAGER}&force=true`,
+        {
+          contentType: 'application/apply-patch+yaml',
+          value: {
+            apiVersion: 'v1',
+            kind: 'ConfigMap',
+            metadata: {
+              name,
+              namespace: owner.namespace,
+              labels: { 'app.kubernetes.io/managed-by': FIELD_MANAGER },
+              // Deleting the scan garbage-collects its summary.
+              ownerReferences: [
+                {
+                  apiVersion: `${KNOWGRAPH_API_GROUP}/${KNOWGRAPH_API_VERSION}`,
+                  kind: 'KnowGraphScan',
+                  name: owner.name,
+                  uid: owner.uid,
+                },
+              ],
+            },
+            data,
+          },
+        },
+      );
+    },
+
+    async annotateDeployment(deployment, annotations) {
+      await call(
+        'PATCH',
+        `/apis/apps/v1/namespaces/${encodeURIComponent(deployment.namespace)}/deployments/${encodeURIComponent(deployment.name)}`,
+        {
+          contentType: 'application/merge-patch+json',
+          value: { metadata: { annotations } },
+        },
+      );
+    },
+
+    async updateScanStatus(scan, status) {
+      // A merge patch keeps fields it leaves out, so clear them explicitly.
+      const set = Object.entries(status).filter(([, v]) => v !== undefined);
+      const value = { ...STATUS_FIELDS, ...Object.fromEntries(set) };
+      await call(
+        'PATCH',
+        `${scansPath(scan.namespace)}/${encodeURIComponent(scan.name)}/status`,
+        {
+          contentType: 'application/merge-patch+json',
+          value: { status: value },
+        },
+      );
+    },
+  };
+}
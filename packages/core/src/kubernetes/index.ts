@@ -0,0 +1,30 @@
+export type {
+  GraphSummary,
+  KnowGraphScan,
+  KnowGraphScanSpec,
+  KnowGraphScanStatus,
+  KubernetesClient,
+  KubernetesClientOptions,
+  KubernetesDeployment,
+  LabelSelector,
+  OwnerCount,
+  ReconcileOptions,
+  ReconcileResult,
+  RepoScan,
+} from './types.js';
+export {
+  ANNOTATION_PREFIX,
+  KNOWGRAPH_API_GROUP,
+  KNOWGRAPH_API_VERSION,
+  parseKnowGraphScan,
+  reconcileScan,
+  reconcileScans,
+  summarizeGraph,
+  summaryConfigMapName,
+  workloadAnnotations,
+} from './operator.js';
+export {
+  SERVICE_ACCOUNT_DIR,
+  createKubernetesClient,
+  inClusterClientOptions,
+} from './client.js';
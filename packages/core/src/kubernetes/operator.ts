@@ -0,0 +1,230 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reconciles KnowGraphScan resources by scanning their repositories and publishing ownership summaries to ConfigMaps and matching Deployments
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [kubernetes, operator, crd, ownership, reconcile]
+ * context:
+ *   business_goal: Keep workload ownership labels current as repositories change, without manual runs
+ *   domain: kubernetes
+ */
+import { z } from 'zod';
+import { createKnowgraphError } from '../errors/errors.js';
+import {
+  isValidGitRef,
+  parseRemoteSource,
+} from '../remote/remote-checkout.js';
+import type {
+  GraphSummary,
+  KnowGraphScan,
+  KnowGraphScanStatus,
+  KubernetesClient,
+  KubernetesDeployment,
+  ReconcileOptions,
+  ReconcileResult,
+  RepoScan,
+} from './types.js';
+
+export const KNOWGRAPH_API_GROUP = 'knowgraph.dev';
+export const KNOWGRAPH_API_VERSION = 'v1alpha1';
+export const ANNOTATION_PREFIX = `${KNOWGRAPH_API_GROUP}/`;
+
+/** Labels naming a workload's app, most specific first. */
+const APP_NAME_LABELS = ['app.kubernetes.io/name', 'app'];
+
+const ScanResourceSchema = z.object({
+  metadata: z.object({
+    name: z.string().min(1),
+    namespace: z.string().min(1),
+    uid: z.string().min(1),
+    generation: z.number().int().optional(),
+  }),
+  spec: z.object({
+    // Only network git URLs, so a scan cannot point the operator at its
+    // own disk, and refs git could never read as options.
+    repo: z
+      .string()
+      .refine((repo) => !/^file:/i.test(repo), {
+        message: 'must not be a file:// URL',
+      })
+      .refine(
+        (repo) => !repo.includes('#') && parseRemoteSource(repo) !== undefined,
+        { message: 'must be a git URL without a #ref' },
+      ),
+    ref: z
+      .string()
+      .refine(isValidGitRef, {
+        message: 'must be a valid git branch, tag, or commit',
+      })
+      .optional(),
+    selector: z.object({
+      matchLabels: z.record(z.string(), z.string()).default({}),
+    }),
+    targetNamespace: z.string().min(1).optional(),
+    topOwners: z.number().int().positive().default(5),
+  }),
+});
+
+/**
+ * Read a `KnowGraphScan` from the API server's JSON. Throws a schema error
+ * naming the first invalid field.
+ */
+export function parseKnowGraphScan(resource: unknown): KnowGraphScan {
+  const parsed = ScanResourceSchema.safeParse(resource);
+  if (!parsed.success) {
+    const issue = parsed.error.issues[0];
+    throw createKnowgraphError(
+      'schema',
+      `Invalid KnowGraphScan: ${issue?.path.join('.')} ${issue?.message}`,
+    );
+  }
+  const { metadata, spec } = parsed.data;
+  return { ...metadata, spec };
+}
+
+/** The ConfigMap a scan's summary is published to. */
+export function summaryConfigMapName(scan: KnowGraphScan): string {
+  return `knowgraph-${scan.name}`;
+}
+
+/**
+ * Count entities, the owners with the most entities, and who owns each
+ * `service` entity, in a scan of `repo`.
+ */
+export function summarizeGraph(
+  repo: string,
+  scan: RepoScan,
+  scannedAt: string,
+  topOwners = 5,
+): GraphSummary {
+  const counts = new Map<string, number>();
+  const services: Record<string, string | null> = {};
+  for (const entity of scan.entities) {
+    if (entity.owner) {
+      counts.set(entity.owner, (counts.get(entity.owner) ?? 0) + 1);
+    }
+    if (entity.entityType === 'service') {
+      services[entity.name] = entity.owner;
+    }
+  }
+  const owned = [...counts.values()].reduce((sum, n) => sum + n, 0);
+  const total = scan.entities.length;
+  return {
+    repo,
+    ...(scan.commit ? { commit: scan.commit } : {}),
+    scannedAt,
+    entities: total,
+    ownedPercent: total > 0 ? Math.round((owned / total) * 1000) / 10 : 0,
+    topOwners: [...counts.entries()]
+      .sort(([a, x], [b, y]) => y - x || a.localeCompare(b))
+      .slice(0, topOwners)
+      .map(([owner, entities]) => ({ owner, entities })),
+    services,
+  };
+}
+
+/**
+ * Annotations for one Deployment: the owner of the service entity named
+ * by its app label or its own name, or the repository's busiest owner
+ * when no service matches. Keys with nothing to say are null, so a stale
+ * value from an earlier scan is removed.
+ */
+export function workloadAnnotations(
+  summary: GraphSummary,
+  deployment: KubernetesDeployment,
+  summaryRef: string,
+): Record<string, string | null> {
+  const candidates = [
+    ...APP_NAME_LABELS.map((label) => deployment.labels[label]),
+    deployment.name,
+  ];
+  const service = candidates.find(
+    (name) => name !== undefined && Object.hasOwn(summary.services, name),
+  );
+  const owner =
+    service !== undefined
+      ? summary.services[service]
+      : summary.topOwners[0]?.owner;
+  return {
+    [`${ANNOTATION_PREFIX}owner`]: owner ?? null,
+    [`${ANNOTATION_PREFIX}service`]: service ?? null,
+    [`${ANNOTATION_PREFIX}repo`]: summary.repo,
+    [`${ANNOTATION_PREFIX}commit`]: summary.commit ?? null,
+    [`${ANNOTATION_PREFIX}summary`]: summaryRef,
+  };
+}
+
+function describeError(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+/**
+ * Scan the repository `scan` names with `scanRepo`, publish the summary
+ * to its ConfigMap, annotate every matching Deployment, and record the
+ * outcome in the scan's status. A failure is recorded as a `Failed`
+ * status rather than thrown; only a failed status update throws.
+ */
+export async function reconcileScan(
+  client: KubernetesClient,
+  scan: KnowGraphScan,
+  scanRepo: (scan: KnowGraphScan) => Promise<RepoScan>,
+  options: ReconcileOptions = {},
+): Promise<ReconcileResult> {
+  const now = options.now ?? (() => new Date().toISOString());
+  const { spec } = scan;
+  let status: KnowGraphScanStatus;
+  try {
+    const scanned = await scanRepo(scan);
+    const summary = summarizeGraph(spec.repo, scanned, now(), spec.topOwners);
+    const configMap = summaryConfigMapName(scan);
+    await client.applyConfigMap(scan, configMap, {
+      'summary.json': `${JSON.stringify(summary, null, 2)}\n`,
+    });
+
+    const deployments = await client.listDeployments(
+      spec.targetNamespace ?? scan.namespace,
+      spec.selector,
+    );
+    const summaryRef = `${scan.namespace}/${configMap}`;
+    for (const deployment of deployments) {
+      await client.annotateDeployment(
+        deployment,
+        workloadAnnotations(summary, deployment, summaryRef),
+      );
+    }
+    status = {
+      phase: 'Ready',
+      observedGeneration: scan.generation,
+      lastScanTime: summary.scannedAt,
+      commit: summary.commit,
+      entities: summary.entities,
+      workloads: deployments.length,
+    };
+  } catch (err) {
+    status = {
+      phase: 'Failed',
+      observedGeneration: scan.generation,
+      lastScanTime: now(),
+      message: describeError(err),
+    };
+  }
+  await client.updateScanStatus(scan, status);
+  return { scan, status };
+}
+
+/**
+ * Reconcile every scan the client can see, one after another, so a slow
+ * repository delays the others instead of competing with them for disk.
+ */
+export async function reconcileScans(
+  client: KubernetesClient,
+  scanRepo: (scan: KnowGraphScan) => Promise<RepoScan>,
+  options: ReconcileOptions = {},
+): Promise<readonly ReconcileResult[]> {
+  const results: ReconcileResult[] = [];
+  for (const scan of await client.listScans()) {
+    results.push(await reconcileScan(client, scan, scanRepo, options));
+  }
+  return results;
+}
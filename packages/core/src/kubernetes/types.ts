@@ -0,0 +1,133 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the KnowGraphScan custom resource, the graph summaries published from it, and the Kubernetes API calls the operator makes
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [kubernetes, operator, crd, ownership, types, interface]
+ * context:
+ *   business_goal: Let cluster admins configure scans with an ordinary custom resource
+ *   domain: kubernetes
+ */
+
+/** Labels a Deployment must carry, all of them, to match. */
+export interface LabelSelector {
+  readonly matchLabels: Readonly<Record<string, string>>;
+}
+
+export interface KnowGraphScanSpec {
+  /** A git URL; `ref` pins what is scanned. */
+  readonly repo: string;
+  /** A branch, tag, or commit; the default branch when omitted. */
+  readonly ref?: string;
+  /** Deployments to annotate. */
+  readonly selector: LabelSelector;
+  /** Where the Deployments are; the scan's own namespace when omitted. */
+  readonly targetNamespace?: string;
+  /** Owners listed in the summary, busiest first. */
+  readonly topOwners: number;
+}
+
+/** A `KnowGraphScan` resource as the operator reads it. */
+export interface KnowGraphScan {
+  readonly name: string;
+  readonly namespace: string;
+  readonly uid: string;
+  readonly generation?: number;
+  readonly spec: KnowGraphScanSpec;
+}
+
+export interface KubernetesDeployment {
+  readonly name: string;
+  readonly namespace: string;
+  readonly labels: Readonly<Record<string, string>>;
+}
+
+export interface OwnerCount {
+  readonly owner: string;
+  readonly entities: number;
+}
+
+/** What a scan found, as published to the cluster. */
+export interface GraphSummary {
+  readonly repo: string;
+  readonly commit?: string;
+  /** ISO 8601 time of the scan. */
+  readonly scannedAt: string;
+  readonly entities: number;
+  /** Share of entities with an owner, as a percentage. */
+  readonly ownedPercent: number;
+  readonly topOwners: readonly OwnerCount[];
+  /** Owner of each `service` entity, keyed by name. */
+  readonly services: Readonly<Record<string, string | null>>;
+}
+
+/** What a scan of one repository hands back to the reconciler. */
+export interface RepoScan {
+  readonly entities: readonly {
+    readonly name: string;
+    readonly entityType: string;
+    readonly owner: string | null;
+  }[];
+  readonly commit?: string;
+}
+
+export interface KnowGraphScanStatus {
+  readonly phase: 'Ready' | 'Failed';
+  readonly observedGeneration?: number;
+  readonly lastScanTime: string;
+  readonly commit?: string;
+  readonly entities?: number;
+  /** Deployments annotated by the last scan. */
+  readonly workloads?: number;
+  readonly message?: string;
+}
+
+/**
+ * The Kubernetes API calls the operator makes. Calls throw an `io` error
+ * when the API server answers with an error.
+ */
+export interface KubernetesClient {
+  /** Every scan, or those in the namespace the client was scoped to. */
+  listScans(): Promise<readonly KnowGraphScan[]>;
+  listDeployments(
+    namespace: string,
+    selector: LabelSelector,
+  ): Promise<readonly KubernetesDeployment[]>;
+  /** Create or replace a ConfigMap owned by `owner`. */
+  applyConfigMap(
+    owner: KnowGraphScan,
+    name: string,
+    data: Readonly<Record<string, string>>,
+  ): Promise<void>;
+  /**
+   * Merge annotations into a Deployment's metadata, not its pod template,
+   * so no rollout starts. A null value removes the annotation.
+   */
+  annotateDeployment(
+    deployment: KubernetesDeployment,
+    annotations: Readonly<Record<string, string | null>>,
+  ): Promise<void>;
+  updateScanStatus(
+    scan: KnowGraphScan,
+    status: KnowGraphScanStatus,
+  ): Promise<void>;
+}
+
+export interface KubernetesClientOptions {
+  /** API server URL, such as `https://kubernetes.default.svc`. */
+  readonly apiUrl: string;
+  readonly token?: string;
+  /** Only watch scans in this namespace. */
+  readonly namespace?: string;
+}
+
+export interface ReconcileOptions {
+  /** ISO timestamps for summaries and status; tests pass a fixed one. */
+  readonly now?: () => string;
+}
+
+export interface ReconcileResult {
+  readonly scan: KnowGraphScan;
+  readonly status: KnowGraphScanStatus;
+}
@@ -0,0 +1,101 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { openIndex, scan, scanStream } from '../knowgraph.js';
+
+const CHECKOUT = `/**
+ * @knowgraph
+ * type: service
+ * description: Checkout flow that charges the customer
+ * owner: shop-team
+ * dependencies:
+ *   services: [payments]
+ */
+export class Checkout {}
+`;
+
+const PAYMENTS = `/**
+ * @knowgraph
+ * type: service
+ * description: Payment gateway wrapper for card charges
+ * owner: payments-team
+ */
+export class Payments {}
+`;
+
+describe('library mode', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-library-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'checkout.ts'), CHECKOUT);
+    writeFileSync(join(dir, 'src', 'payments.ts'), PAYMENTS);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('scans into memory and exposes entities, graph, and query', () => {
+    const kg = scan(dir);
+    try {
+      expect(kg.index.totalEntities).toBe(2);
+      expect(kg.entities().map((e) => e.name).sort()).toEqual([
+        'Checkout',
+        'Payments',
+      ]);
+      expect(kg.graph().edges).toEqual([
+        expect.objectContaining({ kind: 'service' }),
+      ]);
+      expect(kg.query.getByOwner('payments-team')[0]?.name).toBe('Payments');
+    } finally {
+      kg.close();
+    }
+  });
+
+  it('reopens an index written to disk', () => {
+    const dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+
+    const kg = openIndex(dbPath);
+    try {
+      expect(kg.query.search({ query: 'checkout' }).total).toBe(1);
+    } finally {
+      kg.close();
+    }
+  });
+
+  it('streams entity nodes per file before edges', () => {
+    const events: string[] = [];
+    const kg = scanStream(dir, {
+      onProgress: (p) => events.push(`progress:${p.processedFiles}`),
+      onNode: (node) => events.push(`node:${node.name}`),
+      onEdge: (edge) => events.push(`edge:${edge.kind}`),
+    });
+    kg.close();
+
+    expect(events.filter((e) => e.startsWith('node:')).sort()).toEqual([
+      'node:Checkout',
+      'node:Payments',
+    ]);
+    // One node arrives after the first file, before the second starts
+    const firstNode = events.findIndex((e) => e.startsWith('node:'));
+    expect(events.slice(0, firstNode + 2)).toEqual([
+      'progress:0',
+      events[firstNode],
+      'progress:1',
+    ]);
+    expect(events.at(-1)).toBe('edge:service');
+  });
+
+  it('rejects a missing index', () => {
+    expect(() => openIndex(join(dir, 'missing.db'))).toThrow(
+      'Database not found',
+    );
+  });
+});
@@ -0,0 +1,97 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { GraphChangeEvent } from '../../graph/types.js';
+import { scan } from '../knowgraph.js';
+import { watchIndex } from '../watch.js';
+
+function source(name: string, owner: string): string {
+  return `/**
+ * @knowgraph
+ * type: service
+ * description: ${name} service used by the watch tests
+ * owner: ${owner}
+ */
+export class ${name} {}
+`;
+}
+
+describe('watchIndex', () => {
+  let dir: string;
+  let dbPath: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-watch-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'checkout.ts'), source('Checkout', 'shop'));
+    dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('emits node events when another connection re-indexes', () => {
+    const received: GraphChangeEvent[] = [];
+    const watcher = watchIndex(dbPath, (events) => received.push(...events), {
+      intervalMs: 60_000,
+    });
+    try {
+      expect(watcher.poll()).toEqual([]);
+
+      writeFileSync(join(dir, 'src', 'checkout.ts'), source('Checkout', 'ops'));
+      writeFileSync(join(dir, 'src', 'payments.ts'), source('Payments', 'pay'));
+      scan(dir, { dbPath, incremental: true }).close();
+
+      const events = watcher.poll();
+      expect(events.map((e) => `${e.type}:${e.node.name}`).sort()).toEqual([
+        'node_added:Payments',
+        'node_updated:Checkout',
+      ]);
+      expect(received).toEqual(events);
+      expect(watcher.graph().nodes).toHaveLength(2);
+    } finally {
+      watcher.close();
+    }
+  });
+
+  it('never snapshots part of a scan and keeps old snapshots intact', () => {
+    const watcher = watchIndex(dbPath, () => {}, { intervalMs: 60_000 });
+    try {
+      const before = watcher.snapshot();
+      writeFileSync(join(dir, 'src', 'payments.ts'), source('Payments', 'pay'));
+      writeFileSync(join(dir, 'src', 'refunds.ts'), source('Refunds', 'pay'));
+      const polled: number[] = [];
+      scan(dir, {
+        dbPath,
+        onFileIndexed: () => {
+          polled.push(watcher.poll().length);
+        },
+      }).close();
+
+      expect(polled).toEqual([0, 0, 0]);
+      expect(watcher.snapshot()).toBe(before);
+      watcher.poll();
+      const after = watcher.snapshot();
+      expect(after.version).toBe(before.version + 1);
+      expect(after.entities).toHaveLength(3);
+      expect(before.entities.map((e) => e.name)).toEqual(['Checkout']);
+      expect(before.graph.nodes).toHaveLength(1);
+      const payments = after.entities.find((e) => e.name === 'Payments');
+      expect(after.entity(payments?.id ?? '')?.owner).toBe('pay');
+    } finally {
+      watcher.close();
+    }
+  });
+
+  it('rejects a missing database', () => {
+    expect(() => watchIndex(join(dir, 'missing.db'), () => {})).toThrow(
+      /Database not found/,
+    );
+  });
+});
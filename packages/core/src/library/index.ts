@@ -0,0 +1,17 @@
+export type {
+  GraphChangeListener,
+  IndexSnapshot,
+  IndexWatcher,
+  KnowGraph,
+  ScanOptions,
+  ScanResult,
+  StreamScanOptions,
+  WatchIndexOptions,
+} from './types.js';
+export {
+  createParserRegistryAdapter,
+  scan,
+  scanStream,
+  openIndex,
+} from './knowgraph.js';
+export { watchIndex } from './watch.js';
@@ -0,0 +1,128 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Library entry points that scan a repository or open an index and expose graph and query views
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [library, api, scan, graph, query]
+ * context:
+ *   business_goal: Let internal tools embed the engine instead of shelling out to the CLI and parsing JSON
+ *   domain: library
+ */
+import { existsSync } from 'node:fs';
+import {
+  buildDependencyGraph,
+  toEntityNode,
+} from '../graph/graph-builder.js';
+import { createNameTable } from '../graph/graph-names.js';
+import type { EncryptionOptions } from '../encryption/types.js';
+import type { GraphNode } from '../graph/types.js';
+import { createDatabaseManager } from '../indexer/database.js';
+import type { DatabaseManager } from '../indexer/database.js';
+import { createIndexer } from '../indexer/indexer.js';
+import type { ParserRegistry } from '../indexer/indexer.js';
+import { createDefaultRegistry } from '../parsers/registry.js';
+import { createQueryEngine } from '../query/query-engine.js';
+import type {
+  KnowGraph,
+  ScanOptions,
+  ScanResult,
+  StreamScanOptions,
+} from './types.js';
+
+/**
+ * Adapt the default parser registry to the shape the indexer expects.
+ */
+export function createParserRegistryAdapter(
+  registry = createDefaultRegistry(),
+): ParserRegistry {
+  return {
+    parse(filePath: string, content: string) {
+      return registry.parseFile(content, filePath).results;
+    },
+    canParse(filePath: string) {
+      return registry.getParser(filePath) !== undefined;
+    },
+  };
+}
+
+function createHandle(dbManager: DatabaseManager): KnowGraph {
+  const query = createQueryEngine(dbManager);
+  return {
+    query,
+    entities: () => query.getAll(),
+    graph: (options) => buildDependencyGraph(query.getAll(), options),
+    close: () => dbManager.close(),
+  };
+}
+
+/**
+ * Index `rootDir` with the default parsers and return a handle on the
+ * result. The index lives in memory unless `dbPath` is given.
+ */
+export function scan(rootDir: string, options: ScanOptions = {}): ScanResult {
+  const { dbPath, encryptionKey, ...indexerOptions } = options;
+  const dbManager = createDatabaseManager(dbPath, { encryptionKey });
+  try {
+    dbManager.initialize();
+    const indexer = createIndexer(createParserRegistryAdapter(), dbManager);
+    const index = indexer.index({ ...indexerOptions, rootDir });
+    return { ...createHandle(dbManager), index };
+  } catch (err) {
+    dbManager.close();
+    throw err;
+  }
+}
+
+/**
+ * Like `scan`, but emits graph nodes as each file is stored so long scans
+ * can feed progress bars and early consumers. Edges, external stubs, and
+ * nodes of files skipped by incremental scans follow once every file is
+ * parsed, since a dependency may name an entity in a later file.
+ */
+export function scanStream(
+  rootDir: string,
+  options: StreamScanOptions = {},
+): ScanResult {
+  const { graph: graphOptions = {}, onNode, onEdge, ...scanOptions } = options;
+  const names = createNameTable(graphOptions);
+  const emitted = new Set<string>();
+  const emit = (node: GraphNode): void => {
+    if (emitted.has(node.id)) return;
+    emitted.add(node.id);
+    onNode?.(node);
+  };
+
+  const result = scan(rootDir, {
+    ...scanOptions,
+    onFileIndexed: (filePath, entities) => {
+      scanOptions.onFileIndexed?.(filePath, entities);
+      for (const entity of entities) {
+        emit(toEntityNode(entity, graphOptions.workspaces, names));
+      }
+    },
+  });
+  try {
+    const graph = result.graph(graphOptions);
+    for (const node of graph.nodes) emit(node);
+    for (const edge of graph.edges) onEdge?.(edge);
+  } catch (err) {
+    result.close();
+    throw err;
+  }
+  return result;
+}
+
+/**
+ * Open an index previously written by `knowgraph index` or `scan`,
+ * decrypting it with `options` when it is encrypted.
+ */
+export function openIndex(
+  dbPath: string,
+  options: EncryptionOptions = {},
+): KnowGraph {
+  if (!existsSync(dbPath)) {
+    throw new Error(`Database not found at ${dbPath}`);
+  }
+  return createHandle(createDatabaseManager(dbPath, options));
+}
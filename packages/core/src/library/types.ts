@@ -0,0 +1,101 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Stable types for embedding the scan, graph, and query layers as a library
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [library, api, types, interface]
+ * context:
+ *   business_goal: Keep the embedding API stable so internal tools survive knowgraph upgrades
+ *   domain: library
+ */
+import type {
+  DependencyGraph,
+  DependencyGraphOptions,
+  GraphChangeEvent,
+  GraphEdge,
+  GraphNode,
+} from '../graph/types.js';
+import type {
+  IndexerOptions,
+  IndexResult,
+  StoredEntity,
+} from '../indexer/types.js';
+import type { QueryEngine } from '../query/query-engine.js';
+import type { EncryptionOptions } from '../encryption/types.js';
+
+export interface ScanOptions
+  extends Omit<IndexerOptions, 'rootDir'>,
+    EncryptionOptions {
+  /** SQLite file to write the index to; in-memory when omitted. */
+  readonly dbPath?: string;
+}
+
+export interface StreamScanOptions extends ScanOptions {
+  /** Options for emitted nodes and the final graph. */
+  readonly graph?: DependencyGraphOptions;
+  /**
+   * Called once per node: entity nodes as their file is stored, the rest
+   * at the end.
+   */
+  readonly onNode?: (node: GraphNode) => void;
+  /** Called once per edge after every file is parsed. */
+  readonly onEdge?: (edge: GraphEdge) => void;
+}
+
+/**
+ * Handle on an index. Holds an open database until `close()` is called.
+ */
+export interface KnowGraph {
+  /** Full-text search, filters, and dependency lookups. */
+  readonly query: QueryEngine;
+  /** Every indexed entity. */
+  entities(): readonly StoredEntity[];
+  /** Dependency graph over every indexed entity. */
+  graph(options?: DependencyGraphOptions): DependencyGraph;
+  close(): void;
+}
+
+export interface ScanResult extends KnowGraph {
+  /** Files, entities, and errors from the scan that built the index. */
+  readonly index: IndexResult;
+}
+
+export type GraphChangeListener = (
+  events: readonly GraphChangeEvent[],
+  graph: DependencyGraph,
+) => void;
+
+export interface WatchIndexOptions {
+  /** Options for the graphs that are built and diffed. */
+  readonly graph?: DependencyGraphOptions;
+  /** How often to check the index for commits (default: 1000ms). */
+  readonly intervalMs?: number;
+}
+
+/**
+ * One committed state of a watched index. Snapshots are never changed: a
+ * poll that finds a re-index builds a new one, so a reader holding this
+ * one keeps a consistent view for as long as it needs.
+ */
+export interface IndexSnapshot {
+  /** Counts up from 1 with each snapshot the watcher takes. */
+  readonly version: number;
+  /** ISO timestamp of when the watcher read the index for it. */
+  readonly takenAt: string;
+  readonly entities: readonly StoredEntity[];
+  readonly graph: DependencyGraph;
+  entity(id: string): StoredEntity | undefined;
+}
+
+/**
+ * Handle on a watched index. `entities()` and `graph()` without options
+ * read the snapshot of the last poll; `query` reads the latest committed
+ * index.
+ */
+export interface IndexWatcher extends KnowGraph {
+  /** The snapshot of the last poll. */
+  snapshot(): IndexSnapshot;
+  /** Check for changes now instead of waiting for the timer. */
+  poll(): readonly GraphChangeEvent[];
+}
@@ -0,0 +1,97 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Watches an on-disk index for commits by other processes and emits graph change events
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [library, watch, events, subscriptions, sqlite]
+ * context:
+ *   business_goal: Let long-running servers push graph changes to subscribers as re-indexing lands
+ *   domain: library
+ */
+import { existsSync } from 'node:fs';
+import { buildDependencyGraph } from '../graph/graph-builder.js';
+import { diffGraphs } from '../graph/graph-diff.js';
+import type { GraphChangeEvent } from '../graph/types.js';
+import { createDatabaseManager } from '../indexer/database.js';
+import { createQueryEngine } from '../query/query-engine.js';
+import type {
+  GraphChangeListener,
+  IndexSnapshot,
+  IndexWatcher,
+  WatchIndexOptions,
+} from './types.js';
+
+const DEFAULT_INTERVAL_MS = 1000;
+
+/**
+ * Open the index at `dbPath` and poll it for changes. SQLite's
+ * `data_version` moves whenever another connection commits, so a
+ * `knowgraph index` run in another process is picked up on the next poll.
+ * Each scan commits as one transaction, and the poll reads it in one, so
+ * a snapshot never holds part of a scan. A changed index becomes a new
+ * snapshot, swapped in whole once built, and `onChange` receives the node
+ * events, if any. The timer does not keep the process alive.
+ */
+export function watchIndex(
+  dbPath: string,
+  onChange: GraphChangeListener,
+  options: WatchIndexOptions = {},
+): IndexWatcher {
+  const { graph: graphOptions, intervalMs = DEFAULT_INTERVAL_MS } = options;
+  if (!existsSync(dbPath)) {
+    throw new Error(`Database not found at ${dbPath}`);
+  }
+  const dbManager = createDatabaseManager(dbPath);
+  const query = createQueryEngine(dbManager);
+  const dataVersion = (): number =>
+    dbManager.db.pragma('data_version', { simple: true }) as number;
+
+  function takeSnapshot(version: number): IndexSnapshot {
+    const entities = query.getAll();
+    const byId = new Map(entities.map((entity) => [entity.id, entity]));
+    return {
+      version,
+      takenAt: new Date().toISOString(),
+      entities,
+      graph: buildDependencyGraph(entities, graphOptions),
+      entity: (id) => byId.get(id),
+    };
+  }
+
+  let version = dataVersion();
+  let current = takeSnapshot(1);
+
+  function poll(): readonly GraphChangeEvent[] {
+    const latest = dataVersion();
+    if (latest === version) return [];
+    const next = takeSnapshot(current.version + 1);
+    const events = diffGraphs(current.graph, next.graph);
+    version = latest;
+    current = next;
+    if (events.length > 0) onChange(events, next.graph);
+    return events;
+  }
+
+  const timer = setInterval(() => {
+    try {
+      poll();
+    } catch {
+      // A writer may hold the database mid-run; try again on the next tick
+    }
+  }, intervalMs);
+  timer.unref();
+
+  return {
+    query,
+    entities: () => current.entities,
+    graph: (options) =>
+      options ? buildDependencyGraph(current.entities, options) : current.graph,
+    snapshot: () => current,
+    poll,
+    close: () => {
+      clearInterval(timer);
+      dbManager.close();
+    },
+  };
+}
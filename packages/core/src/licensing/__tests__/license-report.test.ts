@@ -0,0 +1,106 @@
+import { describe, it, expect } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { EntityType, ExtendedMetadata } from '../../types/entity.js';
+import { checkLicenses } from '../license-report.js';
+import type { SbomComponent } from '../types.js';
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  entityType: EntityType,
+  extra: Partial<ExtendedMetadata> = {},
+): StoredEntity {
+  const metadata = { type: entityType, description: `The ${name}`, ...extra };
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType,
+    description: `The ${name}`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'checkout-team',
+    status: null,
+    metadata,
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const entities = [
+  makeEntity('checkout', 'src/checkout/index.ts', 'module', {
+    dependencies: { services: ['gpl-lib'] },
+  }),
+  makeEntity('PaymentService', 'src/checkout/payment.ts', 'service', {
+    dependencies: { services: ['readline', 'left-pad', 'mystery'] },
+  }),
+  makeEntity('gpl-lib', 'third_party/gpl/index.ts', 'module', {
+    license: 'GPL-2.0-only',
+    origin: 'forked',
+  }),
+  makeEntity('vendored-lib', 'vendor/lib/index.ts', 'module', {
+    origin: 'vendored',
+  }),
+];
+
+const components: readonly SbomComponent[] = [
+  {
+    name: 'readline',
+    version: '8.2',
+    purl: 'pkg:deb/debian/readline@8.2',
+    license: 'GPL-3.0-or-later',
+  },
+  {
+    name: 'left-pad',
+    version: '1.3.0',
+    purl: 'pkg:npm/left-pad@1.3.0',
+    license: 'MIT',
+  },
+  { name: 'mystery', version: null, purl: null, license: null },
+];
+
+describe('checkLicenses', () => {
+  const graph = buildDependencyGraph(entities);
+
+  it('reports copyleft dependencies of a module and its entities', () => {
+    const report = checkLicenses(entities, graph, components);
+
+    expect(report.checked).toBe(1);
+    expect(report.conflicts).toBe(2);
+    const [checkout] = report.modules;
+    expect(checkout.name).toBe('checkout');
+    expect(checkout.license).toBeNull();
+    expect(checkout.conflicts.map((c) => [c.name, c.source])).toEqual([
+      ['gpl-lib', 'annotation'],
+      ['readline@8.2', 'sbom'],
+    ]);
+    expect(checkout.unknown.map((d) => d.name)).toEqual(['mystery']);
+  });
+
+  it('reports vendored modules without a license', () => {
+    const report = checkLicenses(entities, graph, components);
+    // gpl-lib is forked but declares its license, so needs no review
+    expect(
+      report.modules.map((m) => [m.name, m.origin, m.missingLicense]),
+    ).toEqual([
+      ['checkout', null, false],
+      ['vendored-lib', 'vendored', true],
+    ]);
+  });
+
+  it('checks modules without a license against the default', () => {
+    const report = checkLicenses(entities, graph, components, {
+      defaultLicense: 'GPL-3.0-only',
+    });
+    expect(report.conflicts).toBe(0);
+    expect(report.modules[0].license).toBe('GPL-3.0-only');
+  });
+});
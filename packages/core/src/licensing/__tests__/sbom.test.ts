@@ -0,0 +1,79 @@
+import { describe, it, expect } from 'vitest';
+import { parseSbom } from '../sbom.js';
+
+describe('parseSbom', () => {
+  it('reads CycloneDX components, their licenses, and nested ones', () => {
+    const components = parseSbom(
+      JSON.stringify({
+        bomFormat: 'CycloneDX',
+        specVersion: '1.5',
+        components: [
+          {
+            group: '@acme',
+            name: 'ui',
+            version: '1.2.0',
+            purl: 'pkg:npm/%40acme/ui@1.2.0',
+            licenses: [{ license: { id: 'MIT' } }],
+            components: [
+              {
+                name: 'left-pad',
+                licenses: [
+                  { license: { id: 'MIT' } },
+                  { expression: 'GPL-2.0-only OR BSD-2-Clause' },
+                ],
+              },
+            ],
+          },
+          { name: 'mystery' },
+        ],
+      }),
+    );
+    expect(components).toEqual([
+      {
+        name: '@acme/ui',
+        version: '1.2.0',
+        purl: 'pkg:npm/%40acme/ui@1.2.0',
+        license: 'MIT',
+      },
+      {
+        name: 'left-pad',
+        version: null,
+        purl: null,
+        license: 'MIT AND (GPL-2.0-only OR BSD-2-Clause)',
+      },
+      { name: 'mystery', version: null, purl: null, license: null },
+    ]);
+  });
+
+  it('reads SPDX packages, preferring the concluded license', () => {
+    const components = parseSbom(
+      JSON.stringify({
+        spdxVersion: 'SPDX-2.3',
+        packages: [
+          {
+            name: 'readline',
+            versionInfo: '8.2',
+            licenseConcluded: 'GPL-3.0-or-later',
+            licenseDeclared: 'NOASSERTION',
+            externalRefs: [
+              {
+                referenceType: 'purl',
+                referenceLocator: 'pkg:deb/debian/readline@8.2',
+              },
+            ],
+          },
+          { name: 'zlib', licenseConcluded: 'NOASSERTION' },
+        ],
+      }),
+    );
+    expect(components.map((c) => [c.name, c.license, c.purl])).toEqual([
+      ['readline', 'GPL-3.0-or-later', 'pkg:deb/debian/readline@8.2'],
+      ['zlib', null, null],
+    ]);
+  });
+
+  it('rejects documents that are not an SBOM', () => {
+    expect(() => parseSbom('{"packages": []}')).toThrow(/Unrecognized SBOM/);
+    expect(() => parseSbom('not json')).toThrow(SyntaxError);
+  });
+});
@@ -0,0 +1,42 @@
+import { describe, it, expect } from 'vitest';
+import { classifyLicense, licenseConflict } from '../spdx.js';
+
+describe('classifyLicense', () => {
+  it('classifies single SPDX ids', () => {
+    expect(classifyLicense('MIT')).toBe('permissive');
+    expect(classifyLicense('BSD-3-Clause')).toBe('permissive');
+    expect(classifyLicense('LGPL-2.1-only')).toBe('weak-copyleft');
+    expect(classifyLicense('GPL-3.0-or-later')).toBe('strong-copyleft');
+    expect(classifyLicense('AGPL-3.0-only')).toBe('network-copyleft');
+    expect(classifyLicense('LicenseRef-acme')).toBe('unknown');
+    expect(classifyLicense(null)).toBe('unknown');
+  });
+
+  it('takes the easiest alternative and the hardest conjunct', () => {
+    expect(classifyLicense('(GPL-2.0-only OR MIT)')).toBe('permissive');
+    expect(classifyLicense('MIT AND GPL-2.0-only')).toBe('strong-copyleft');
+    expect(classifyLicense('MIT AND LicenseRef-x')).toBe('unknown');
+    expect(classifyLicense('GPL-2.0-only WITH Classpath-exception-2.0')).toBe(
+      'strong-copyleft',
+    );
+  });
+});
+
+describe('licenseConflict', () => {
+  it('flags copyleft that asks more than the module license', () => {
+    expect(licenseConflict('MIT', 'strong-copyleft')).toBe(
+      'strong copyleft dependency of permissive code',
+    );
+    expect(licenseConflict(null, 'network-copyleft')).toBe(
+      'network copyleft dependency of proprietary or unlicensed code',
+    );
+    expect(licenseConflict('GPL-3.0-only', 'network-copyleft')).not.toBeNull();
+  });
+
+  it('allows permissive, weak copyleft, and matching copyleft', () => {
+    expect(licenseConflict('MIT', 'permissive')).toBeNull();
+    expect(licenseConflict(null, 'weak-copyleft')).toBeNull();
+    expect(licenseConflict('GPL-3.0-only', 'strong-copyleft')).toBeNull();
+    expect(licenseConflict('MIT', 'unknown')).toBeNull();
+  });
+});
@@ -0,0 +1,12 @@
+export type {
+  LicenseCategory,
+  SbomComponent,
+  LicensedDependency,
+  LicenseConflict,
+  ModuleLicenseReport,
+  LicenseReport,
+  LicenseCheckOptions,
+} from './types.js';
+export { classifyLicense, licenseConflict } from './spdx.js';
+export { parseSbom } from './sbom.js';
+export { checkLicenses } from './license-report.js';
@@ -0,0 +1,188 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reports modules whose SBOM components or in-repo dependencies are under licenses incompatible with their own
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [licensing, sbom, compliance, report]
+ * context:
+ *   business_goal: Find license conflicts where they enter a module, not after they ship
+ *   domain: licensing
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { DependencyGraph, GraphNode } from '../graph/types.js';
+import { declaredModules } from '../refactor/extraction.js';
+import type { ModuleOrigin } from '../types/entity.js';
+import { classifyLicense, licenseConflict } from './spdx.js';
+import type {
+  LicenseCheckOptions,
+  LicensedDependency,
+  LicenseReport,
+  ModuleLicenseReport,
+  SbomComponent,
+} from './types.js';
+
+const PURL_PATTERN = /^pkg:[^/]+\/(.+?)(?:@[^@]*)?(?:[?#].*)?$/;
+
+function licenseOf(entity: StoredEntity): string | null {
+  const { metadata } = entity;
+  return 'license' in metadata ? metadata.license ?? null : null;
+}
+
+function originOf(entity: StoredEntity): ModuleOrigin | null {
+  const { metadata } = entity;
+  return 'origin' in metadata ? metadata.origin ?? null : null;
+}
+
+/**
+ * The names a dependency may use for `component`: its own, and the
+ * namespaced and bare names from its package URL.
+ */
+function componentKeys(component: SbomComponent): readonly string[] {
+  const keys = [component.name.toLowerCase()];
+  const match = component.purl ? PURL_PATTERN.exec(component.purl) : null;
+  if (match) {
+    const path = decodeURIComponent(match[1]).toLowerCase();
+    keys.push(path, path.slice(path.lastIndexOf('/') + 1));
+  }
+  return [...new Set(keys)];
+}
+
+function indexComponents(
+  components: readonly SbomComponent[],
+): ReadonlyMap<string, readonly LicensedDependency[]> {
+  const byKey = new Map<string, LicensedDependency[]>();
+  for (const component of components) {
+    const dependency: LicensedDependency = {
+      name: component.version
+        ? `${component.name}@${component.version}`
+        : component.name,
+      license: component.license,
+      category: classifyLicense(component.license),
+      source: 'sbom',
+    };
+    for (const key of componentKeys(component)) {
+      const found = byKey.get(key) ?? [];
+      if (!found.some((other) => other.name === dependency.name)) {
+        found.push(dependency);
+      }
+      byKey.set(key, found);
+    }
+  }
+  return byKey;
+}
+
+/**
+ * Check every module's dependencies against its license. Edges are
+ * attributed to the declared module of their source (see
+ * `declaredModules`), or to the entity itself outside any module.
+ * External dependencies are matched to `components` by name or package
+ * URL; in-repo dependencies count when their module declares a license.
+ * Vendored and forked modules with no license are reported too.
+ */
+export function checkLicenses(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  components: readonly SbomComponent[],
+  options: LicenseCheckOptions = {},
+): LicenseReport {
+  const byId = new Map(entities.map((entity) => [entity.id, entity]));
+  const modulesByName = new Map<string, StoredEntity>();
+  const byPath = [...entities].sort((a, b) =>
+    compareStrings(a.filePath, b.filePath),
+  );
+  for (const entity of byPath) {
+    if (entity.entityType === 'module' && !modulesByName.has(entity.name)) {
+      modulesByName.set(entity.name, entity);
+    }
+  }
+  const moduleOf = declaredModules(graph);
+  const unitOf = (node: GraphNode): StoredEntity | undefined => {
+    const name = moduleOf(node);
+    const module = name === null ? undefined : modulesByName.get(name);
+    return module ?? byId.get(node.id);
+  };
+  const sbom = indexComponents(components);
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+
+  const dependencies = new Map<string, Map<string, LicensedDependency>>();
+  for (const edge of graph.edges) {
+    const from = nodes.get(edge.from);
+    const to = nodes.get(edge.to);
+    const unit = from && !from.external ? unitOf(from) : undefined;
+    if (!unit || !to) continue;
+    let found: readonly LicensedDependency[] = [];
+    if (to.external) {
+      found = sbom.get(to.name.toLowerCase()) ?? [];
+    } else {
+      const target = unitOf(to);
+      const license = target ? licenseOf(target) : null;
+      if (target && license && target.id !== unit.id) {
+        found = [
+          {
+            name: target.name,
+            license,
+            category: classifyLicense(license),
+            source: 'annotation',
+          },
+        ];
+      }
+    }
+    if (found.length === 0) continue;
+    const deps =
+      dependencies.get(unit.id) ?? new Map<string, LicensedDependency>();
+    for (const dep of found) deps.set(`${dep.name}\u0000${dep.license}`, dep);
+    dependencies.set(unit.id, deps);
+  }
+
+  const units = new Set(dependencies.keys());
+  for (const entity of entities) {
+    const origin = originOf(entity);
+    if (origin === 'vendored' || origin === 'forked') units.add(entity.id);
+  }
+
+  const modules: ModuleLicenseReport[] = [];
+  for (const id of units) {
+    const entity = byId.get(id) as StoredEntity;
+    const origin = originOf(entity);
+    const own = licenseOf(entity);
+    const license = own ?? options.defaultLicense ?? null;
+    const deps = [...(dependencies.get(id)?.values() ?? [])].sort((a, b) =>
+      compareStrings(a.name, b.name),
+    );
+    const conflicts = deps.flatMap((dep) => {
+      const reason = licenseConflict(license, dep.category);
+      return reason ? [{ ...dep, reason }] : [];
+    });
+    const report: ModuleLicenseReport = {
+      entityId: entity.id,
+      name: entity.name,
+      entityType: entity.entityType,
+      filePath: entity.filePath,
+      owner: entity.owner,
+      license,
+      origin,
+      conflicts,
+      unknown: deps.filter((dep) => dep.category === 'unknown'),
+      missingLicense: (origin === 'vendored' || origin === 'forked') && !own,
+    };
+    if (
+      report.conflicts.length > 0 ||
+      report.unknown.length > 0 ||
+      report.missingLicense
+    ) {
+      modules.push(report);
+    }
+  }
+
+  modules.sort(
+    (a, b) =>
+      compareStrings(a.filePath, b.filePath) || compareStrings(a.name, b.name),
+  );
+  return {
+    modules,
+    checked: dependencies.size,
+    conflicts: modules.reduce((sum, m) => sum + m.conflicts.length, 0),
+  };
+}
@@ -0,0 +1,121 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses CycloneDX and SPDX JSON software bills of materials into components with their licenses
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [licensing, sbom, cyclonedx, spdx, import]
+ * context:
+ *   business_goal: Normalize SBOMs from any generator so third-party licenses can be joined to the modules that use them
+ *   domain: licensing
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type { SbomComponent } from './types.js';
+
+const NO_ASSERTION = new Set(['NOASSERTION', 'NONE']);
+
+type Json = Record<string, unknown>;
+
+function isObject(value: unknown): value is Json {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+function text(value: unknown): string | null {
+  return typeof value === 'string' && value.trim() ? value.trim() : null;
+}
+
+function objects(value: unknown): readonly Json[] {
+  return Array.isArray(value) ? value.filter(isObject) : [];
+}
+
+/**
+ * A CycloneDX component's licenses as one expression. Entries carry an
+ * `expression` or a `license` with an SPDX `id` or a free-form `name`;
+ * several entries all apply.
+ */
+function cycloneDxLicense(component: Json): string | null {
+  const terms = objects(component.licenses).flatMap((entry) => {
+    const expression = text(entry.expression);
+    if (expression) return [expression];
+    const license = isObject(entry.license) ? entry.license : {};
+    const id = text(license.id) ?? text(license.name);
+    return id ? [id] : [];
+  });
+  if (terms.length === 0) return null;
+  if (terms.length === 1) return terms[0];
+  return terms
+    .map((term) => (/\s/.test(term) ? `(${term})` : term))
+    .join(' AND ');
+}
+
+/** Components of a CycloneDX BOM, including nested ones. */
+function cycloneDxComponents(bom: Json): readonly SbomComponent[] {
+  const found: SbomComponent[] = [];
+  const visit = (components: unknown): void => {
+    for (const component of objects(components)) {
+      const name = text(component.name);
+      if (name) {
+        const group = text(component.group);
+        found.push({
+          name: group ? `${group}/${name}` : name,
+          version: text(component.version),
+          purl: text(component.purl),
+          license: cycloneDxLicense(component),
+        });
+      }
+      visit(component.components);
+    }
+  };
+  visit(bom.components);
+  return found;
+}
+
+function spdxLicense(pkg: Json): string | null {
+  for (const field of [pkg.licenseConcluded, pkg.licenseDeclared]) {
+    const license = text(field);
+    if (license && !NO_ASSERTION.has(license)) return license;
+  }
+  return null;
+}
+
+function spdxPurl(pkg: Json): string | null {
+  const ref = objects(pkg.externalRefs).find(
+    (entry) => entry.referenceType === 'purl',
+  );
+  return ref ? text(ref.referenceLocator) : null;
+}
+
+/** Packages of an SPDX 2.x JSON document. */
+function spdxComponents(document: Json): readonly SbomComponent[] {
+  return objects(document.packages).flatMap((pkg) => {
+    const name = text(pkg.name);
+    if (!name) return [];
+    return [
+      {
+        name,
+        version: text(pkg.versionInfo),
+        purl: spdxPurl(pkg),
+        license: spdxLicense(pkg),
+      },
+    ];
+  });
+}
+
+/**
+ * The components of a CycloneDX (`bomFormat: CycloneDX`) or SPDX
+ * (`spdxVersion`) JSON SBOM. Throws a parse error for invalid JSON or
+ * any other document.
+ */
+export function parseSbom(content: string): readonly SbomComponent[] {
+  const document: unknown = JSON.parse(content);
+  if (isObject(document) && document.bomFormat === 'CycloneDX') {
+    return cycloneDxComponents(document);
+  }
+  if (isObject(document) && typeof document.spdxVersion === 'string') {
+    return spdxComponents(document);
+  }
+  throw createKnowgraphError(
+    'parse',
+    'Unrecognized SBOM: expected CycloneDX or SPDX JSON',
+  );
+}
@@ -0,0 +1,97 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Classifies SPDX license expressions from permissive to network copyleft and decides which dependencies conflict
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [licensing, spdx, copyleft, compliance]
+ * context:
+ *   business_goal: Judge license compatibility the same way for every SBOM format
+ *   domain: licensing
+ */
+import type { LicenseCategory } from './types.js';
+
+type KnownCategory = Exclude<LicenseCategory, 'unknown'>;
+
+const RANK: Readonly<Record<KnownCategory, number>> = {
+  permissive: 0,
+  'weak-copyleft': 1,
+  'strong-copyleft': 2,
+  'network-copyleft': 3,
+};
+
+// Checked in order, so LGPL is matched before GPL
+const FAMILIES: readonly (readonly [RegExp, KnownCategory])[] = [
+  [/^(AGPL|SSPL)-/, 'network-copyleft'],
+  [/^(LGPL|MPL|EPL|CDDL|CPL)-/, 'weak-copyleft'],
+  [/^(GPL|EUPL|OSL)-/, 'strong-copyleft'],
+  [/^(BSD|Apache|MIT|Python|PSF)-/, 'permissive'],
+];
+
+const PERMISSIVE = new Set([
+  '0BSD',
+  'Artistic-2.0',
+  'BlueOak-1.0.0',
+  'BSL-1.0',
+  'CC0-1.0',
+  'ISC',
+  'MIT',
+  'Unlicense',
+  'WTFPL',
+  'X11',
+  'Zlib',
+]);
+
+function classifyId(id: string): LicenseCategory {
+  if (PERMISSIVE.has(id)) return 'permissive';
+  return FAMILIES.find(([pattern]) => pattern.test(id))?.[1] ?? 'unknown';
+}
+
+/**
+ * The category of an SPDX expression. Of `OR` alternatives the consumer
+ * may pick the least demanding; `AND` terms all apply, so the most
+ * demanding wins and any unknown term makes the whole unknown. License
+ * exceptions (`WITH`) are ignored, and parentheses are not nested.
+ */
+export function classifyLicense(expression: string | null): LicenseCategory {
+  if (!expression) return 'unknown';
+  const alternatives = expression
+    .replace(/[()]/g, ' ')
+    .split(/\s+OR\s+/i)
+    .map((alternative): LicenseCategory => {
+      const terms = alternative
+        .split(/\s+AND\s+/i)
+        .map((term) => classifyId(term.trim().split(/\s+WITH\s+/i)[0]));
+      if (terms.includes('unknown')) return 'unknown';
+      return (terms as KnownCategory[]).reduce((a, b) =>
+        RANK[b] > RANK[a] ? b : a,
+      );
+    });
+  const known = alternatives.filter(
+    (category): category is KnownCategory => category !== 'unknown',
+  );
+  if (known.length === 0) return 'unknown';
+  return known.reduce((a, b) => (RANK[b] < RANK[a] ? b : a));
+}
+
+/**
+ * Why a module under `moduleLicense` cannot depend on code in `category`,
+ * or null when it can. Weak copyleft only binds the library itself, so
+ * only strong and network copyleft that asks more than the module's own
+ * license conflicts. A module with no classifiable license is treated as
+ * proprietary.
+ */
+export function licenseConflict(
+  moduleLicense: string | null,
+  category: LicenseCategory,
+): string | null {
+  if (category !== 'strong-copyleft' && category !== 'network-copyleft') {
+    return null;
+  }
+  const own = classifyLicense(moduleLicense);
+  if (own !== 'unknown' && RANK[own] >= RANK[category]) return null;
+  const label = category.replace('-', ' ');
+  return own === 'unknown'
+    ? `${label} dependency of proprietary or unlicensed code`
+    : `${label} dependency of ${own.replace('-', ' ')} code`;
+}
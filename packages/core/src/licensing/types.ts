@@ -0,0 +1,79 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for SBOM components and the per-module report of dependencies under incompatible licenses
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [licensing, sbom, compliance, types]
+ * context:
+ *   business_goal: Let compliance tools read license findings without parsing the CLI report
+ *   domain: licensing
+ */
+import type { EntityType, ModuleOrigin } from '../types/entity.js';
+
+/**
+ * How much a license asks of code that depends on it, from least to most.
+ * `unknown` covers expressions with no recognized SPDX license, such as
+ * `NOASSERTION` or a `LicenseRef-`.
+ */
+export type LicenseCategory =
+  | 'permissive'
+  | 'weak-copyleft'
+  | 'strong-copyleft'
+  | 'network-copyleft'
+  | 'unknown';
+
+/** A package listed in a CycloneDX or SPDX software bill of materials. */
+export interface SbomComponent {
+  readonly name: string;
+  readonly version: string | null;
+  /** Package URL, such as `pkg:npm/%40acme/ui@1.2.0`. */
+  readonly purl: string | null;
+  /** SPDX license expression, or null when the SBOM asserts none. */
+  readonly license: string | null;
+}
+
+export interface LicensedDependency {
+  /** The component as `name@version`, or the in-repo module's name. */
+  readonly name: string;
+  readonly license: string | null;
+  readonly category: LicenseCategory;
+  /** `sbom` for third-party components, `annotation` for in-repo modules. */
+  readonly source: 'sbom' | 'annotation';
+}
+
+export interface LicenseConflict extends LicensedDependency {
+  readonly reason: string;
+}
+
+export interface ModuleLicenseReport {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly owner: string | null;
+  /** The module's `license`, else the default license checked against. */
+  readonly license: string | null;
+  readonly origin: ModuleOrigin | null;
+  readonly conflicts: readonly LicenseConflict[];
+  /** Dependencies whose license could not be classified. */
+  readonly unknown: readonly LicensedDependency[];
+  /** Vendored or forked code with no `license` of its own. */
+  readonly missingLicense: boolean;
+}
+
+export interface LicenseReport {
+  /** Modules with a conflict, an unknown license, or a missing license. */
+  readonly modules: readonly ModuleLicenseReport[];
+  /** Modules with a dependency found in the SBOM or licensed in the repo. */
+  readonly checked: number;
+  readonly conflicts: number;
+}
+
+export interface LicenseCheckOptions {
+  /**
+   * License of modules that declare none, usually the repository's own.
+   * Without it they are held to what proprietary code may depend on.
+   */
+  readonly defaultLicense?: string;
+}
@@ -0,0 +1,77 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import { createLinkChecker, parseHeaders } from '../link-checker.js';
+
+function mockFetch(status: number, statusText = ''): ReturnType<typeof vi.fn> {
+  const fn = vi.fn(async () => ({
+    ok: status >= 200 && status < 300,
+    status,
+    statusText,
+    body: null,
+  }));
+  vi.stubGlobal('fetch', fn);
+  return fn;
+}
+
+describe('createLinkChecker', () => {
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('treats 2xx responses as alive', async () => {
+    mockFetch(200, 'OK');
+    const result = await createLinkChecker().check('https://wiki/runbook');
+    expect(result).toEqual({ ok: true, status: 200 });
+  });
+
+  it('reports non-2xx responses as dead', async () => {
+    mockFetch(404, 'Not Found');
+    const result = await createLinkChecker().check('https://wiki/missing');
+    expect(result.ok).toBe(false);
+    expect(result.status).toBe(404);
+    expect(result.error).toBe('HTTP 404 Not Found');
+  });
+
+  it('sends configured auth headers', async () => {
+    const fn = mockFetch(200);
+    await createLinkChecker({
+      headers: { Authorization: 'Bearer secret' },
+    }).check('https://grafana/d/1');
+    const init = fn.mock.calls[0][1] as RequestInit;
+    expect(init.headers).toEqual({ Authorization: 'Bearer secret' });
+  });
+
+  it('reports network errors without throwing', async () => {
+    vi.stubGlobal(
+      'fetch',
+      vi.fn(async () => {
+        throw new Error('getaddrinfo ENOTFOUND wiki');
+      }),
+    );
+    const result = await createLinkChecker().check('https://wiki/x');
+    expect(result).toEqual({ ok: false, error: 'getaddrinfo ENOTFOUND wiki' });
+  });
+
+  it('reports timeouts', async () => {
+    vi.stubGlobal(
+      'fetch',
+      vi.fn(async () => {
+        throw Object.assign(new Error('aborted'), { name: 'TimeoutError' });
+      }),
+    );
+    const checker = createLinkChecker({ timeoutMs: 50 });
+    const result = await checker.check('https://x');
+    expect(result.error).toBe('Timed out after 50ms');
+  });
+});
+
+describe('parseHeaders', () => {
+  it('parses name/value pairs', () => {
+    expect(
+      parseHeaders(['Authorization: Bearer abc', 'X-Org:  ops ']),
+    ).toEqual({ Authorization: 'Bearer abc', 'X-Org': 'ops' });
+  });
+
+  it('rejects malformed headers', () => {
+    expect(() => parseHeaders(['nope'])).toThrow('Invalid header');
+  });
+});
@@ -0,0 +1,111 @@
+import { describe, it, expect, vi } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  buildDeadLinkReport,
+  checkOperationalLinks,
+  collectOperationalLinks,
+} from '../operational-links.js';
+import type { LinkChecker } from '../types.js';
+
+function makeEntity(overrides: Partial<StoredEntity> = {}): StoredEntity {
+  return {
+    id: 'id-1',
+    filePath: 'src/checkout.ts',
+    name: 'checkout',
+    entityType: 'service',
+    description: 'Checkout service',
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: { type: 'service', description: 'Checkout service' },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const checkout = makeEntity({
+  links: [
+    { type: 'runbook', url: 'https://wiki/runbooks/checkout' },
+    { type: 'notion', url: 'https://notion.so/design' },
+    { type: 'dashboard', url: 'https://grafana/d/checkout' },
+  ],
+  metadata: {
+    type: 'service',
+    description: 'Checkout service',
+    operational: {
+      monitoring_dashboards: [
+        { url: 'https://grafana/d/checkout' },
+        { url: 'https://datadog/dash/checkout', title: 'APM' },
+      ],
+    },
+  },
+});
+
+const search = makeEntity({
+  id: 'id-2',
+  name: 'search',
+  owner: null,
+  links: [{ type: 'runbook', url: 'https://wiki/runbooks/search' }],
+});
+
+describe('collectOperationalLinks', () => {
+  it('collects runbook, dashboard, and monitoring dashboard links', () => {
+    const links = collectOperationalLinks([checkout]);
+    expect(links.map((l) => [l.kind, l.url])).toEqual([
+      ['runbook', 'https://wiki/runbooks/checkout'],
+      ['dashboard', 'https://grafana/d/checkout'],
+      ['dashboard', 'https://datadog/dash/checkout'],
+    ]);
+  });
+});
+
+describe('checkOperationalLinks', () => {
+  it('checks each distinct url once', async () => {
+    const check = vi.fn(async (url: string) => ({
+      ok: !url.includes('search'),
+    }));
+    const checker: LinkChecker = { check };
+    const shared = makeEntity({
+      id: 'id-3',
+      links: [{ type: 'runbook', url: 'https://wiki/runbooks/checkout' }],
+    });
+    const links = collectOperationalLinks([checkout, search, shared]);
+    const results = await checkOperationalLinks(links, checker, 2);
+    expect(check).toHaveBeenCalledTimes(4);
+    expect(results).toHaveLength(5);
+    expect(results.filter((r) => !r.ok).map((r) => r.link.name)).toEqual([
+      'search',
+    ]);
+  });
+});
+
+describe('buildDeadLinkReport', () => {
+  it('groups dead links by owner with unowned last', async () => {
+    const checker: LinkChecker = {
+      check: async () => ({ ok: false, status: 404 }),
+    };
+    const links = collectOperationalLinks([search, checkout]);
+    const results = await checkOperationalLinks(links, checker);
+    const report = buildDeadLinkReport(results);
+    expect(report.checked).toBe(4);
+    expect(report.dead).toBe(4);
+    expect(report.groups.map((g) => g.owner)).toEqual([
+      'payments-team',
+      '(no owner)',
+    ]);
+  });
+
+  it('is empty when every link resolves', () => {
+    const report = buildDeadLinkReport([]);
+    expect(report).toEqual({ checked: 0, dead: 0, groups: [] });
+  });
+});
@@ -0,0 +1,16 @@
+export type {
+  OperationalLinkKind,
+  OperationalLink,
+  LinkStatus,
+  LinkCheckResult,
+  LinkChecker,
+  LinkCheckerOptions,
+  DeadLinkOwnerGroup,
+  DeadLinkReport,
+} from './types.js';
+export { createLinkChecker, parseHeaders } from './link-checker.js';
+export {
+  collectOperationalLinks,
+  checkOperationalLinks,
+  buildDeadLinkReport,
+} from './operational-links.js';
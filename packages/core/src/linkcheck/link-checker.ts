@@ -0,0 +1,72 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Fetch-based URL liveness checker with auth header and timeout support
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [linkcheck, http, liveness]
+ * context:
+ *   business_goal: Detect runbook and dashboard links that no longer resolve
+ *   domain: reliability
+ */
+import type { LinkChecker, LinkCheckerOptions, LinkStatus } from './types.js';
+
+const DEFAULT_TIMEOUT_MS = 10_000;
+
+/**
+ * Create a checker that issues a GET for each URL (following redirects) and
+ * treats any 2xx final response as alive. Network errors and timeouts are
+ * reported as dead links rather than thrown.
+ */
+export function createLinkChecker(
+  options: LinkCheckerOptions = {},
+): LinkChecker {
+  const timeoutMs = options.timeoutMs ?? DEFAULT_TIMEOUT_MS;
+  const headers = { ...options.headers };
+
+  return {
+    async check(url: string): Promise<LinkStatus> {
+      try {
+        const response = await fetch(url, {
+          method: 'GET',
+          headers,
+          redirect: 'follow',
+          signal: AbortSignal.timeout(timeoutMs),
+        });
+        await response.body?.cancel();
+        return response.ok
+          ? { ok: true, status: response.status }
+          : {
+              ok: false,
+              status: response.status,
+              error: `HTTP ${response.status} ${response.statusText}`.trim(),
+            };
+      } catch (err) {
+        if (err instanceof Error && err.name === 'TimeoutError') {
+          return { ok: false, error: `Timed out after ${timeoutMs}ms` };
+        }
+        return {
+          ok: false,
+          error: err instanceof Error ? err.message : String(err),
+        };
+      }
+    },
+  };
+}
+
+/**
+ * Parse `Name: value` header strings as accepted on the command line.
+ */
+export function parseHeaders(
+  values: readonly string[],
+): Readonly<Record<string, string>> {
+  const headers: Record<string, string> = {};
+  for (const value of values) {
+    const index = value.indexOf(':');
+    if (index <= 0) {
+      throw new Error(`Invalid header "${value}". Expected "Name: value"`);
+    }
+    headers[value.slice(0, index).trim()] = value.slice(index + 1).trim();
+  }
+  return headers;
+}
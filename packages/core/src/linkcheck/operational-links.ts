@@ -0,0 +1,121 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Collects runbook and dashboard links from entities and groups dead links by owning team
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [linkcheck, runbook, dashboard, report]
+ * context:
+ *   business_goal: Report dead operational links per team before they are needed in an incident
+ *   domain: reliability
+ */
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  DeadLinkOwnerGroup,
+  DeadLinkReport,
+  LinkCheckResult,
+  LinkChecker,
+  LinkStatus,
+  OperationalLink,
+  OperationalLinkKind,
+} from './types.js';
+
+const UNOWNED = '(no owner)';
+const DEFAULT_CONCURRENCY = 4;
+
+/**
+ * Gather `runbook` and `dashboard` links plus operational monitoring
+ * dashboards from every entity. Duplicate URLs on the same entity are reported once.
+ */
+export function collectOperationalLinks(
+  entities: readonly StoredEntity[],
+): readonly OperationalLink[] {
+  const links: OperationalLink[] = [];
+
+  for (const entity of entities) {
+    const seen = new Set<string>();
+    const add = (kind: OperationalLinkKind, url: string, title?: string) => {
+      if (seen.has(url)) return;
+      seen.add(url);
+      links.push({
+        entityId: entity.id,
+        name: entity.name,
+        filePath: entity.filePath,
+        owner: entity.owner,
+        kind,
+        url,
+        title,
+      });
+    };
+
+    for (const link of entity.links) {
+      if (link.type === 'runbook' || link.type === 'dashboard') {
+        add(link.type, link.url, link.title);
+      }
+    }
+
+    const { metadata } = entity;
+    const dashboards =
+      'operational' in metadata
+        ? metadata.operational?.monitoring_dashboards
+        : undefined;
+    for (const dashboard of dashboards ?? []) {
+      add('dashboard', dashboard.url, dashboard.title);
+    }
+  }
+
+  return links;
+}
+
+/**
+ * Check every link with bounded concurrency. Each distinct URL is fetched
+ * only once even when several entities reference it.
+ */
+export async function checkOperationalLinks(
+  links: readonly OperationalLink[],
+  checker: LinkChecker,
+  concurrency: number = DEFAULT_CONCURRENCY,
+): Promise<readonly LinkCheckResult[]> {
+  const urls = [...new Set(links.map((link) => link.url))];
+  const statuses = new Map<string, LinkStatus>();
+
+  let next = 0;
+  const worker = async (): Promise<void> => {
+    while (next < urls.length) {
+      const url = urls[next++];
+      statuses.set(url, await checker.check(url));
+    }
+  };
+  const workers = Math.max(1, Math.min(concurrency, urls.length));
+  await Promise.all(Array.from({ length: workers }, worker));
+
+  return links.map((link) => ({
+    link,
+    ...(statuses.get(link.url) ?? { ok: false, error: 'Not checked' }),
+  }));
+}
+
+export function buildDeadLinkReport(
+  results: readonly LinkCheckResult[],
+): DeadLinkReport {
+  const groups = new Map<string, LinkCheckResult[]>();
+  for (const result of results) {
+    if (result.ok) continue;
+    const owner = result.link.owner ?? UNOWNED;
+    groups.set(owner, [...(groups.get(owner) ?? []), result]);
+  }
+
+  const ownerGroups: DeadLinkOwnerGroup[] = [...groups.entries()]
+    .map(([owner, deadLinks]) => ({ owner, deadLinks }))
+    .sort((a, b) => {
+      if (a.owner === UNOWNED) return 1;
+      if (b.owner === UNOWNED) return -1;
+      return a.owner.localeCompare(b.owner);
+    });
+
+  return {
+    checked: results.length,
+    dead: results.filter((result) => !result.ok).length,
+    groups: ownerGroups,
+  };
+}
@@ -0,0 +1,53 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for operational link liveness checks and dead-link reports
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [linkcheck, runbook, dashboard, types, interface]
+ * context:
+ *   business_goal: Define contracts for verifying runbook and dashboard links still resolve
+ *   domain: reliability
+ */
+
+export type OperationalLinkKind = 'runbook' | 'dashboard';
+
+export interface OperationalLink {
+  readonly entityId: string;
+  readonly name: string;
+  readonly filePath: string;
+  readonly owner: string | null;
+  readonly kind: OperationalLinkKind;
+  readonly url: string;
+  readonly title?: string;
+}
+
+export interface LinkStatus {
+  readonly ok: boolean;
+  readonly status?: number;
+  readonly error?: string;
+}
+
+export interface LinkCheckResult extends LinkStatus {
+  readonly link: OperationalLink;
+}
+
+export interface LinkChecker {
+  check(url: string): Promise<LinkStatus>;
+}
+
+export interface LinkCheckerOptions {
+  readonly headers?: Readonly<Record<string, string>>;
+  readonly timeoutMs?: number;
+}
+
+export interface DeadLinkOwnerGroup {
+  readonly owner: string;
+  readonly deadLinks: readonly LinkCheckResult[];
+}
+
+export interface DeadLinkReport {
+  readonly checked: number;
+  readonly dead: number;
+  readonly groups: readonly DeadLinkOwnerGroup[];
+}
@@ -0,0 +1,94 @@
+import { describe, it, expect } from 'vitest';
+import {
+  describeProblems,
+  descriptionThreshold,
+  scoreDescription,
+} from '../description-quality.js';
+import { lintContent } from '../linter.js';
+import { createDescriptionQualityRule } from '../rules.js';
+
+function annotated(description: string, impact?: string): string {
+  return [
+    '/**',
+    ' * @knowgraph',
+    ' * type: function',
+    ` * description: ${description}`,
+    ...(impact ? [' * context:', ` *   revenue_impact: ${impact}`] : []),
+    ' */',
+    'export async function getUserById(id: string) {}',
+    '',
+  ].join('\n');
+}
+
+describe('scoreDescription', () => {
+  it('gives full marks to a description that says something', () => {
+    expect(
+      scoreDescription('Loads a user and their active sessions', 'getUserById'),
+    ).toEqual({ score: 100, problems: [] });
+  });
+
+  it('penalises TODOs, boilerplate, and few words', () => {
+    expect(scoreDescription('TODO: describe the refund flow').problems).toEqual([
+      'todo',
+    ]);
+    expect(scoreDescription('Helper function.')).toEqual({
+      score: 10,
+      problems: ['boilerplate', 'short'],
+    });
+    expect(scoreDescription('Refunds').problems).toEqual(['short']);
+  });
+
+  it('penalises restating the symbol name', () => {
+    expect(
+      scoreDescription('Gets the user by id', 'getUserById').problems,
+    ).toEqual(['restates-name']);
+    expect(
+      scoreDescription('Payment retry service', 'PaymentRetryService').problems,
+    ).toEqual(['restates-name']);
+    expect(
+      scoreDescription('Gets the user by id', 'settleBatch').problems,
+    ).toEqual([]);
+  });
+
+  it('describes problems for messages', () => {
+    expect(describeProblems(['restates-name', 'short'])).toBe(
+      'restates the name, has fewer than 3 words',
+    );
+  });
+});
+
+describe('descriptionThreshold', () => {
+  it('asks more of critical code and honours overrides', () => {
+    expect(descriptionThreshold('critical')).toBe(80);
+    expect(descriptionThreshold(undefined)).toBe(60);
+    expect(descriptionThreshold('low', { low: 30 })).toBe(30);
+  });
+});
+
+describe('description-quality rule', () => {
+  it('flags a description that restates the declared name', () => {
+    const [issue] = lintContent(annotated('Gets the user by id'), 'users.ts');
+    expect(issue?.rule).toBe('description-quality');
+    expect(issue?.message).toBe(
+      'Description scores 50 of 100, below 60: it restates the name',
+    );
+  });
+
+  it('applies the threshold for the revenue impact', () => {
+    const description = 'Looks up users by id (TODO)';
+    const rules = [createDescriptionQualityRule({ critical: 90, low: 40 })];
+    expect(lintContent(annotated(description, 'low'), 'a.ts', rules)).toEqual(
+      [],
+    );
+    expect(
+      lintContent(annotated(description, 'critical'), 'a.ts', rules)[0]
+        ?.message,
+    ).toContain('below 90');
+  });
+
+  it('passes informative descriptions', () => {
+    expect(
+      lintContent(annotated('Loads a user and their active sessions'), 'a.ts'),
+    ).toEqual([]);
+  });
+});
@@ -0,0 +1,222 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { lintContent, fixContent, createLinter } from '../linter.js';
+import {
+  createDefaultLintRules,
+  createShortDescriptionRule,
+  isPersonalOwner,
+} from '../rules.js';
+import type { BatchLintRule, LintTarget } from '../types.js';
+
+const SOURCE = `/**
+ * @knowgraph
+ * type: module
+ * description: Payments
+ * owner: jdoe@example.com
+ * tags: [payments, billing, Payments]
+ */
+export const x = 1;
+`;
+
+const CLEAN = `# @knowgraph
+# type: module
+# description: Handles refunds for card payments
+# owner: payments-team
+# tags: [payments, refunds]
+`;
+
+const ownerMap = { 'jdoe@example.com': 'payments-team' };
+
+const SUPPRESSED = SOURCE.replace(
+  ' * tags: [payments, billing, Payments]\n',
+  ' * tags: [payments, billing, Payments]\n' +
+    ' * # knowgraph:ignore duplicate-tags reason="Case is significant"\n',
+);
+
+describe('lint rules', () => {
+  it('flags short descriptions, personal owners, and duplicate tags', () => {
+    const issues = lintContent(SOURCE, 'src/pay.ts');
+    expect(issues.map((i) => i.rule)).toEqual([
+      'short-description',
+      'personal-owner',
+      'duplicate-tags',
+    ]);
+    expect(issues.every((i) => i.line === 2)).toBe(true);
+    expect(issues[0].message).toContain('8 characters');
+    expect(issues[2].message).toBe('Duplicate tags: payments');
+  });
+
+  it('offers an owner fix only when the owner is mapped', () => {
+    const unmapped = lintContent(SOURCE, 'a.ts');
+    expect(unmapped.find((i) => i.rule === 'personal-owner')?.fix).toBe(
+      undefined,
+    );
+
+    const mapped = lintContent(
+      SOURCE,
+      'a.ts',
+      createDefaultLintRules({ ownerMap }),
+    );
+    expect(mapped.find((i) => i.rule === 'personal-owner')?.fix).toBe(
+      'Replace owner with "payments-team"',
+    );
+  });
+
+  it('recognises personal owners', () => {
+    expect(isPersonalOwner('jdoe@example.com')).toBe(true);
+    expect(isPersonalOwner('@jdoe')).toBe(true);
+    expect(isPersonalOwner('@acme/payments')).toBe(false);
+    expect(isPersonalOwner('payments-team')).toBe(false);
+    expect(isPersonalOwner('jdoe', { jdoe: 'payments-team' })).toBe(true);
+  });
+
+  it('honours a custom minimum description length', () => {
+    const rule = createShortDescriptionRule(40);
+    expect(rule.check({ description: 'Handles refunds' })).toHaveLength(1);
+    expect(rule.check({})).toHaveLength(0);
+  });
+
+  it('reports nothing for a clean block', () => {
+    expect(lintContent(CLEAN, 'refunds.py')).toEqual([]);
+  });
+});
+
+describe('fixContent', () => {
+  it('rewrites owner and tags in place and leaves code untouched', () => {
+    const outcome = fixContent(SOURCE, createDefaultLintRules({ ownerMap }));
+    expect(outcome.fixedCount).toBe(2);
+    expect(outcome.content).toBe(`/**
+ * @knowgraph
+ * type: module
+ * description: Payments
+ * owner: payments-team
+ * tags: [payments, billing]
+ */
+export const x = 1;
+`);
+  });
+
+  it('fixes every block in a file', () => {
+    const twice = `${SOURCE}\n${SOURCE}`;
+    const outcome = fixContent(twice);
+    expect(outcome.fixedCount).toBe(2);
+    expect(outcome.content.match(/tags: \[payments, billing\]/g)).toHaveLength(
+      2,
+    );
+  });
+
+  it('returns content unchanged when nothing is fixable', () => {
+    expect(fixContent(CLEAN)).toEqual({ content: CLEAN, fixedCount: 0 });
+  });
+
+  it('does not fix rules the block suppresses', () => {
+    expect(fixContent(SUPPRESSED)).toEqual({
+      content: SUPPRESSED,
+      fixedCount: 0,
+    });
+  });
+});
+
+describe('createLinter', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-lint-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'pay.ts'), SOURCE);
+    writeFileSync(join(dir, 'src', 'refunds.py'), CLEAN);
+    writeFileSync(join(dir, 'README.md'), SOURCE);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('lints annotated source files only', () => {
+    const result = createLinter().lint(dir);
+    expect(result.fileCount).toBe(2);
+    expect(result.issues).toHaveLength(3);
+    expect(result.fixableCount).toBe(1);
+    expect(result.fixedCount).toBe(0);
+  });
+
+  it('writes fixes and reports what remains', () => {
+    const linter = createLinter(createDefaultLintRules({ ownerMap }));
+    const result = linter.lint(dir, { fix: true });
+    expect(result.fixedCount).toBe(2);
+    expect(result.fixedFiles).toEqual([join(dir, 'src', 'pay.ts')]);
+    expect(result.issues.map((i) => i.rule)).toEqual(['short-description']);
+    expect(readFileSync(join(dir, 'src', 'pay.ts'), 'utf-8')).toContain(
+      'owner: payments-team',
+    );
+  });
+
+  it('reports fixes without writing them in a dry run', () => {
+    const linter = createLinter(createDefaultLintRules({ ownerMap }));
+    const result = linter.lint(dir, { fix: true, dryRun: true });
+    expect(result.fixedCount).toBe(2);
+    expect(result.fixedFiles).toEqual([join(dir, 'src', 'pay.ts')]);
+    expect(readFileSync(join(dir, 'src', 'pay.ts'), 'utf-8')).toBe(SOURCE);
+  });
+
+  it('reports suppressed issues apart from the rest', () => {
+    writeFileSync(join(dir, 'src', 'pay.ts'), SUPPRESSED);
+    const result = createLinter().lint(dir);
+    expect(result.issues.map((i) => i.rule)).not.toContain('duplicate-tags');
+    expect(result.fixableCount).toBe(0);
+    expect(result.suppressed).toEqual([
+      {
+        issue: expect.objectContaining({ rule: 'duplicate-tags', line: 2 }),
+        reason: 'Case is significant',
+      },
+    ]);
+  });
+
+  it('runs batch rules once over every annotation', () => {
+    const seen: LintTarget[][] = [];
+    const batch: BatchLintRule = {
+      name: 'policy',
+      description: 'Sees all annotations',
+      checkAll: (targets) => {
+        seen.push([...targets]);
+        return [
+          {
+            filePath: targets[0].filePath,
+            line: 1,
+            rule: 'policy',
+            message: 'x',
+          },
+        ];
+      },
+    };
+    const result = createLinter([], [batch]).lint(dir);
+    expect(seen).toHaveLength(1);
+    expect(seen[0].map((t) => t.filePath).sort()).toEqual([
+      join(dir, 'src', 'pay.ts'),
+      join(dir, 'src', 'refunds.py'),
+    ]);
+    expect(seen[0][0].metadata).toHaveProperty('description');
+    expect(result.issues.map((i) => i.rule)).toEqual(['policy']);
+  });
+
+  it('reports each fixed file before writing it', () => {
+    const linter = createLinter(createDefaultLintRules({ ownerMap }));
+    const fixes: [string, string, string][] = [];
+    linter.lint(dir, {
+      fix: true,
+      onFix: (filePath, before, after) => fixes.push([filePath, before, after]),
+    });
+    expect(fixes.map(([filePath]) => filePath)).toEqual([
+      join(dir, 'src', 'pay.ts'),
+    ]);
+    expect(fixes[0][1]).not.toContain('owner: payments-team');
+    expect(fixes[0][2]).toBe(
+      readFileSync(join(dir, 'src', 'pay.ts'), 'utf-8'),
+    );
+  });
+});
@@ -0,0 +1,157 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Scores how much a description tells a reader, penalising TODOs, boilerplate, few words, and restating the symbol name
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [lint, quality, descriptions, scorecard]
+ * context:
+ *   business_goal: Catch descriptions that pass validation but tell a reader nothing
+ *   domain: lint
+ */
+import type { RevenueImpact } from '../types/entity.js';
+import type {
+  DescriptionProblem,
+  DescriptionScore,
+  DescriptionThresholds,
+} from './types.js';
+
+/**
+ * Lowest passing score per `context.revenue_impact`, with `unset` for
+ * entities without one: the more critical the code, the more its
+ * description has to say.
+ */
+export const DEFAULT_DESCRIPTION_THRESHOLDS: Readonly<
+  Record<RevenueImpact | 'unset', number>
+> = {
+  critical: 80,
+  high: 70,
+  medium: 60,
+  low: 60,
+  none: 60,
+  unset: 60,
+};
+
+const PENALTIES: Readonly<Record<DescriptionProblem, number>> = {
+  todo: 50,
+  boilerplate: 60,
+  'restates-name': 50,
+  short: 30,
+};
+
+const TODO_PATTERNS: readonly RegExp[] = [/\b(?:todo|fixme|tbd)\b/i, /\bXXX\b/];
+
+// Matched against the whole description, lowercased without end punctuation
+const BOILERPLATE_PATTERNS: readonly RegExp[] = [
+  /^(?:n\/?a|none|null|wip|placeholder|description|no description|lorem ipsum.*)$/,
+  /^(?:auto-?)?generated(?: code| file| description)?$/,
+  /^(?:(?:this|the|a|an) )?(?:class|function|method|module|service|component|file|interface|helper|handler|type|enum|constant|variable|endpoint|code)(?: (?:that )?(?:does|handles|is used for|does stuff|does things|stuff|things))?$/,
+  /^(?:helper|utility|util|misc|miscellaneous|common|generic|various|some) (?:function|functions|method|methods|helpers|utilities|stuff|code|things)$/,
+  /^(?:does|handles|manages|contains|implements) (?:stuff|things|logic|everything|something|it)$/,
+];
+
+// Words that say nothing on their own about what a symbol does
+const FILLER_WORDS = new Set([
+  'a',
+  'an',
+  'the',
+  'this',
+  'that',
+  'of',
+  'for',
+  'to',
+  'and',
+  'or',
+  'is',
+  'in',
+  'on',
+  'with',
+  'class',
+  'function',
+  'method',
+  'module',
+  'service',
+  'interface',
+]);
+
+const MIN_WORDS = 3;
+
+function words(text: string): readonly string[] {
+  return text.toLowerCase().match(/[a-z0-9]+/g) ?? [];
+}
+
+function stem(word: string): string {
+  return word.length > 3 && word.endsWith('s') ? word.slice(0, -1) : word;
+}
+
+/** `getUserById` and `get_user_by_id` both as `get user by id`. */
+function nameWords(name: string): ReadonlySet<string> {
+  const spaced = name
+    .replace(/([a-z0-9])([A-Z])/g, '$1 $2')
+    .replace(/([A-Z]+)([A-Z][a-z])/g, '$1 $2');
+  return new Set(words(spaced).map(stem));
+}
+
+function restatesName(text: string, name: string): boolean {
+  const named = nameWords(name);
+  const content = words(text)
+    .filter((word) => !FILLER_WORDS.has(word))
+    .map(stem);
+  return (
+    named.size > 0 &&
+    content.length > 0 &&
+    content.every((word) => named.has(word))
+  );
+}
+
+/**
+ * Score `description` from 0 to 100 for how much it tells a reader, taking
+ * points off for a TODO, boilerplate such as "Helper function", fewer than
+ * three words, and, given the symbol's `name`, saying nothing the name does
+ * not, as "Gets the user" does for `getUser`.
+ */
+export function scoreDescription(
+  description: string,
+  name?: string,
+): DescriptionScore {
+  const text = description.trim();
+  const normalized = text
+    .toLowerCase()
+    .replace(/[.!…]+$/, '')
+    .replace(/\s+/g, ' ');
+  const problems: DescriptionProblem[] = [];
+  if (TODO_PATTERNS.some((pattern) => pattern.test(text))) {
+    problems.push('todo');
+  }
+  if (BOILERPLATE_PATTERNS.some((pattern) => pattern.test(normalized))) {
+    problems.push('boilerplate');
+  } else if (name && restatesName(text, name)) {
+    problems.push('restates-name');
+  }
+  if (words(text).length < MIN_WORDS) problems.push('short');
+  const penalty = problems.reduce((sum, problem) => sum + PENALTIES[problem], 0);
+  return { score: Math.max(0, 100 - penalty), problems };
+}
+
+/** The lowest passing score for an entity of `revenueImpact`. */
+export function descriptionThreshold(
+  revenueImpact: RevenueImpact | null | undefined,
+  thresholds: DescriptionThresholds = {},
+): number {
+  const merged = { ...DEFAULT_DESCRIPTION_THRESHOLDS, ...thresholds };
+  return merged[revenueImpact ?? 'unset'];
+}
+
+const PROBLEM_LABELS: Readonly<Record<DescriptionProblem, string>> = {
+  todo: 'contains a TODO',
+  boilerplate: 'is boilerplate',
+  'restates-name': 'restates the name',
+  short: `has fewer than ${MIN_WORDS} words`,
+};
+
+/** The problems of a score as a phrase, e.g. `restates the name`. */
+export function describeProblems(
+  problems: readonly DescriptionProblem[],
+): string {
+  return problems.map((problem) => PROBLEM_LABELS[problem]).join(', ');
+}
@@ -0,0 +1,31 @@
+export type {
+  LintFix,
+  LintFinding,
+  LintRule,
+  LintTarget,
+  BatchLintRule,
+  LintRuleConfig,
+  LintIssue,
+  LintResult,
+  LintOptions,
+  LintContext,
+  DescriptionProblem,
+  DescriptionScore,
+  DescriptionThresholds,
+} from './types.js';
+export {
+  createShortDescriptionRule,
+  createDescriptionQualityRule,
+  createPersonalOwnerRule,
+  createDuplicateTagsRule,
+  createDefaultLintRules,
+  isPersonalOwner,
+} from './rules.js';
+export {
+  DEFAULT_DESCRIPTION_THRESHOLDS,
+  scoreDescription,
+  descriptionThreshold,
+  describeProblems,
+} from './description-quality.js';
+export type { FixOutcome, Linter } from './linter.js';
+export { lintContent, fixContent, createLinter } from './linter.js';
@@ -0,0 +1,280 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Runs lint rules over annotation blocks and applies their fixes through the comment rewriter
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [lint, autofix, scanner]
+ * context:
+ *   business_goal: Raise annotation quality with one command that can also clean up what it finds
+ *   domain: lint
+ */
+import { readFileSync, writeFileSync } from 'node:fs';
+import { extname } from 'node:path';
+import {
+  findAnnotationBlocks,
+  parseAnnotationDocument,
+  rewriteAnnotation,
+} from '../rewriter/comment-rewriter.js';
+import type { AnnotationBlock } from '../rewriter/comment-rewriter.js';
+import {
+  applySuppressions,
+  parseSuppressions,
+} from '../validation/suppressions.js';
+import type { Suppression } from '../validation/types.js';
+import { collectFiles } from '../validation/validator.js';
+import type {
+  BatchLintRule,
+  LintFinding,
+  LintContext,
+  LintIssue,
+  LintOptions,
+  LintResult,
+  LintRule,
+  LintTarget,
+} from './types.js';
+import { createDefaultLintRules } from './rules.js';
+
+const LINTABLE_EXTENSIONS = new Set([
+  '.ts',
+  '.tsx',
+  '.js',
+  '.jsx',
+  '.mjs',
+  '.cjs',
+  '.py',
+  '.go',
+  '.java',
+]);
+
+function readMetadata(
+  block: AnnotationBlock,
+): Readonly<Record<string, unknown>> | undefined {
+  const doc = parseAnnotationDocument(block);
+  if (doc.errors.length > 0) return undefined;
+  const value: unknown = doc.toJS();
+  if (typeof value !== 'object' || value === null || Array.isArray(value)) {
+    return undefined;
+  }
+  return value as Record<string, unknown>;
+}
+
+const DECLARATION_PATTERNS: readonly RegExp[] = [
+  /\b(?:class|interface|enum|type|function\*?|def|func|struct|record|trait)\s+(?:\([^)]*\)\s*)?(\w+)/,
+  /\b(?:const|let|var|val)\s+(\w+)/,
+  /(\w+)\s*[(=:]/,
+];
+// How far from a block its declaration may be
+const DECLARATION_LOOKAHEAD = 5;
+const HEADER_LOOKBEHIND = 20;
+
+function nameIn(line: string): string | undefined {
+  for (const pattern of DECLARATION_PATTERNS) {
+    const name = pattern.exec(line)?.[1];
+    if (name) return name;
+  }
+  return undefined;
+}
+
+/**
+ * The name the code next to `block` declares: the header above a docstring
+ * block, or else the first line of code below the comment, past decorators.
+ */
+function declaredName(
+  lines: readonly string[],
+  block: AnnotationBlock,
+): string | undefined {
+  if (block.prefix.trim() === '') {
+    const first = Math.max(0, block.markerLine - 1 - HEADER_LOOKBEHIND);
+    for (let i = block.markerLine - 2; i >= first; i--) {
+      const header = /^\s*(?:async\s+)?(?:def|class)\s+(\w+)/.exec(lines[i]);
+      if (header) return header[1];
+    }
+    return undefined;
+  }
+  const last = Math.min(lines.length, block.endLine + DECLARATION_LOOKAHEAD);
+  for (let i = block.endLine; i < last; i++) {
+    const text = lines[i].trim();
+    // Skip the comment's end, blank lines, and decorators
+    if (/^(?:$|\*|\/\/|#|@)/.test(text)) continue;
+    return nameIn(text);
+  }
+  return undefined;
+}
+
+function checkBlock(
+  block: AnnotationBlock,
+  rules: readonly LintRule[],
+  context: LintContext = {},
+): readonly LintFinding[] {
+  const metadata = readMetadata(block);
+  if (!metadata) return [];
+  return rules.flatMap((rule) => rule.check(metadata, context));
+}
+
+/**
+ * Lint every @knowgraph block in a file's content. Blocks whose YAML does
+ * not parse are left to `knowgraph validate`.
+ */
+export function lintContent(
+  content: string,
+  filePath: string,
+  rules: readonly LintRule[] = createDefaultLintRules(),
+): readonly LintIssue[] {
+  const lines = content.split('\n');
+  return findAnnotationBlocks(content).flatMap((block) => {
+    const context = { name: declaredName(lines, block) };
+    return checkBlock(block, rules, context).map((finding) => ({
+      filePath,
+      line: block.markerLine,
+      rule: finding.rule,
+      message: finding.message,
+      ...(finding.fix ? { fix: finding.fix.description } : {}),
+    }));
+  });
+}
+
+export interface FixOutcome {
+  readonly content: string;
+  readonly fixedCount: number;
+}
+
+/**
+ * Apply every available fix to the content, except for rules a block's
+ * `knowgraph:ignore` comments suppress. Blocks are rewritten bottom-up so
+ * earlier marker lines stay valid when a rewrite changes the line count.
+ */
+export function fixContent(
+  content: string,
+  rules: readonly LintRule[] = createDefaultLintRules(),
+): FixOutcome {
+  const blocks = [...findAnnotationBlocks(content)].reverse();
+  let updated = content;
+  let fixedCount = 0;
+
+  for (const block of blocks) {
+    const ignored = parseSuppressions(block.yaml).flatMap((s) => s.rules);
+    const fixes = checkBlock(block, rules).flatMap((finding) =>
+      finding.fix && !ignored.includes(finding.rule) ? [finding.fix] : [],
+    );
+    if (fixes.length === 0) continue;
+    const rewritten = rewriteAnnotation(updated, block.markerLine, (doc) => {
+      for (const fix of fixes) fix.apply(doc);
+    });
+    if (rewritten !== updated) {
+      updated = rewritten;
+      fixedCount += fixes.length;
+    }
+  }
+
+  return { content: updated, fixedCount };
+}
+
+export interface Linter {
+  lint(targetPath: string, options?: LintOptions): LintResult;
+}
+
+function suppressionKey(filePath: string, line: number): string {
+  return `${filePath}:${line}`;
+}
+
+function collectSuppressions(
+  content: string,
+  filePath: string,
+  into: Map<string, readonly Suppression[]>,
+): void {
+  for (const block of findAnnotationBlocks(content)) {
+    const suppressions = parseSuppressions(block.yaml);
+    if (suppressions.length > 0) {
+      into.set(suppressionKey(filePath, block.markerLine), suppressions);
+    }
+  }
+}
+
+function lintTargets(
+  content: string,
+  filePath: string,
+): readonly LintTarget[] {
+  return findAnnotationBlocks(content).flatMap((block) => {
+    const metadata = readMetadata(block);
+    return metadata ? [{ filePath, line: block.markerLine, metadata }] : [];
+  });
+}
+
+/**
+ * `batchRules` run once per lint over every annotation, after fixes, so a
+ * rule backed by an external process is started once rather than per block.
+ * Issues on an annotation whose `knowgraph:ignore` comments name their rule
+ * are moved to `suppressed`.
+ */
+export function createLinter(
+  rules: readonly LintRule[] = createDefaultLintRules(),
+  batchRules: readonly BatchLintRule[] = [],
+): Linter {
+  return {
+    lint(targetPath: string, options?: LintOptions): LintResult {
+      const issues: LintIssue[] = [];
+      const targets: LintTarget[] = [];
+      const fixedFiles: string[] = [];
+      const suppressions = new Map<string, readonly Suppression[]>();
+      let fileCount = 0;
+      let fixedCount = 0;
+
+      for (const filePath of collectFiles(targetPath)) {
+        if (!LINTABLE_EXTENSIONS.has(extname(filePath))) continue;
+
+        let content: string;
+        try {
+          content = readFileSync(filePath, 'utf-8');
+        } catch {
+          continue;
+        }
+
+        const fileIssues = lintContent(content, filePath, rules);
+        if (findAnnotationBlocks(content).length > 0) fileCount++;
+
+        if (options?.fix && fileIssues.some((issue) => issue.fix)) {
+          const outcome = fixContent(content, rules);
+          if (outcome.fixedCount > 0) {
+            options.onFix?.(filePath, content, outcome.content);
+            if (!options.dryRun) {
+              writeFileSync(filePath, outcome.content, 'utf-8');
+            }
+            fixedFiles.push(filePath);
+            fixedCount += outcome.fixedCount;
+            // Report only what is left after fixing
+            issues.push(...lintContent(outcome.content, filePath, rules));
+            collectSuppressions(outcome.content, filePath, suppressions);
+            if (batchRules.length > 0) {
+              targets.push(...lintTargets(outcome.content, filePath));
+            }
+            continue;
+          }
+        }
+        issues.push(...fileIssues);
+        collectSuppressions(content, filePath, suppressions);
+        if (batchRules.length > 0) {
+          targets.push(...lintTargets(content, filePath));
+        }
+      }
+
+      for (const rule of batchRules) {
+        issues.push(...rule.checkAll(targets));
+      }
+
+      const outcome = applySuppressions(
+        issues,
+        (issue) =>
+          suppressions.get(suppressionKey(issue.filePath, issue.line)) ?? [],
+      );
+      return {
+        issues: outcome.issues,
+        fileCount,
+        fixableCount: outcome.issues.filter((issue) => issue.fix).length,
+        fixedCount,
+        fixedFiles,
+        suppressed: outcome.suppressed,
+      };
+    },
+  };
+}
@@ -0,0 +1,214 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Factory functions for annotation lint rules with optional YAML auto-fixes
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [lint, rules, factory, autofix]
+ * context:
+ *   business_goal: Catch low-quality annotations and offer safe mechanical rewrites
+ *   domain: lint
+ */
+import { isScalar, isSeq } from 'yaml';
+import { RevenueImpactSchema } from '../types/entity.js';
+import type { RevenueImpact } from '../types/entity.js';
+import {
+  describeProblems,
+  descriptionThreshold,
+  scoreDescription,
+} from './description-quality.js';
+import type {
+  DescriptionThresholds,
+  LintFinding,
+  LintFix,
+  LintRule,
+  LintRuleConfig,
+} from './types.js';
+
+const DEFAULT_MIN_DESCRIPTION_LENGTH = 10;
+
+const EMAIL_PATTERN = /^[^@\s]+@[^@\s]+\.[^@\s]+$/;
+// `@alice` is a person; `@org/team` is a GitHub team
+const HANDLE_PATTERN = /^@[\w.-]+$/;
+
+export function createShortDescriptionRule(
+  minLength: number = DEFAULT_MIN_DESCRIPTION_LENGTH,
+): LintRule {
+  return {
+    name: 'short-description',
+    description: `description must be at least ${minLength} characters`,
+    check(metadata): readonly LintFinding[] {
+      const { description } = metadata;
+      const entries: readonly [string, unknown][] =
+        typeof description === 'object' && description !== null
+          ? Object.entries(description)
+          : [['', description]];
+      return entries.flatMap(([locale, text]) => {
+        if (typeof text !== 'string') return [];
+        const length = text.trim().length;
+        if (length >= minLength) return [];
+        const label = locale ? `Description (${locale})` : 'Description';
+        return [
+          {
+            rule: 'short-description',
+            message: `${label} is ${length} characters; write at least ${minLength}`,
+          },
+        ];
+      });
+    },
+  };
+}
+
+function revenueImpactOf(
+  metadata: Readonly<Record<string, unknown>>,
+): RevenueImpact | undefined {
+  const { context } = metadata;
+  if (typeof context !== 'object' || context === null) return undefined;
+  const parsed = RevenueImpactSchema.safeParse(
+    (context as Record<string, unknown>).revenue_impact,
+  );
+  return parsed.success ? parsed.data : undefined;
+}
+
+/**
+ * Flags descriptions that score below the threshold for the entity's
+ * `context.revenue_impact`. Descriptions under `minLength` are left to
+ * `short-description`.
+ */
+export function createDescriptionQualityRule(
+  thresholds: DescriptionThresholds = {},
+  minLength: number = DEFAULT_MIN_DESCRIPTION_LENGTH,
+): LintRule {
+  return {
+    name: 'description-quality',
+    description:
+      'description must say more than a TODO, boilerplate, or the symbol name',
+    check(metadata, context): readonly LintFinding[] {
+      const threshold = descriptionThreshold(
+        revenueImpactOf(metadata),
+        thresholds,
+      );
+      const { description } = metadata;
+      const entries: readonly [string, unknown][] =
+        typeof description === 'object' && description !== null
+          ? Object.entries(description)
+          : [['', description]];
+      return entries.flatMap(([locale, text]) => {
+        if (typeof text !== 'string' || text.trim().length < minLength) {
+          return [];
+        }
+        const { score, problems } = scoreDescription(text, context?.name);
+        if (score >= threshold) return [];
+        const label = locale ? `Description (${locale})` : 'Description';
+        return [
+          {
+            rule: 'description-quality',
+            message: `${label} scores ${score} of 100, below ${threshold}: it ${describeProblems(problems)}`,
+          },
+        ];
+      });
+    },
+  };
+}
+
+export function isPersonalOwner(
+  owner: string,
+  ownerMap: Readonly<Record<string, string>> = {},
+): boolean {
+  return (
+    Object.hasOwn(ownerMap, owner) ||
+    EMAIL_PATTERN.test(owner) ||
+    HANDLE_PATTERN.test(owner)
+  );
+}
+
+export function createPersonalOwnerRule(
+  ownerMap: Readonly<Record<string, string>> = {},
+): LintRule {
+  return {
+    name: 'personal-owner',
+    description: 'owner should name a team rather than a person',
+    check(metadata): readonly LintFinding[] {
+      const { owner } = metadata;
+      if (typeof owner !== 'string' || !isPersonalOwner(owner, ownerMap)) {
+        return [];
+      }
+      const team = Object.hasOwn(ownerMap, owner)
+        ? ownerMap[owner]
+        : undefined;
+      const fix: LintFix | undefined = team
+        ? {
+            description: `Replace owner with "${team}"`,
+            apply: (doc) => {
+              doc.set('owner', team);
+            },
+          }
+        : undefined;
+      return [
+        {
+          rule: 'personal-owner',
+          message: `Owner "${owner}" looks like a person; use a team name`,
+          ...(fix ? { fix } : {}),
+        },
+      ];
+    },
+  };
+}
+
+function tagKey(value: unknown): string {
+  return String(value).trim().toLowerCase();
+}
+
+export function createDuplicateTagsRule(): LintRule {
+  return {
+    name: 'duplicate-tags',
+    description: 'tags must not repeat (case-insensitive)',
+    check(metadata): readonly LintFinding[] {
+      const { tags } = metadata;
+      if (!Array.isArray(tags)) return [];
+      const seen = new Set<string>();
+      const duplicates = new Set<string>();
+      for (const tag of tags) {
+        const key = tagKey(tag);
+        if (seen.has(key)) duplicates.add(key);
+        seen.add(key);
+      }
+      if (duplicates.size === 0) return [];
+      return [
+        {
+          rule: 'duplicate-tags',
+          message: `Duplicate tags: ${[...duplicates].join(', ')}`,
+          fix: {
+            description: 'Remove repeated tags, keeping the first occurrence',
+            apply: (doc) => {
+              // Filter the sequence node so flow style and comments survive
+              const node = doc.get('tags', true);
+              if (!isSeq(node)) return;
+              const kept = new Set<string>();
+              node.items = node.items.filter((item) => {
+                const key = tagKey(isScalar(item) ? item.value : item);
+                if (kept.has(key)) return false;
+                kept.add(key);
+                return true;
+              });
+            },
+          },
+        },
+      ];
+    },
+  };
+}
+
+export function createDefaultLintRules(
+  config: LintRuleConfig = {},
+): readonly LintRule[] {
+  return [
+    createShortDescriptionRule(config.minDescriptionLength),
+    createDescriptionQualityRule(
+      config.descriptionThresholds,
+      config.minDescriptionLength,
+    ),
+    createPersonalOwnerRule(config.ownerMap),
+    createDuplicateTagsRule(),
+  ];
+}
@@ -0,0 +1,107 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Immutable types for annotation lint rules, findings, and auto-fixes
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [lint, types, interface, autofix]
+ * context:
+ *   business_goal: Define contracts for style checks that can rewrite annotations in place
+ *   domain: lint
+ */
+import type { Document } from 'yaml';
+import type { RevenueImpact } from '../types/entity.js';
+import type { SuppressedIssue } from '../validation/types.js';
+
+export interface LintFix {
+  readonly description: string;
+  apply(doc: Document): void;
+}
+
+export interface LintFinding {
+  readonly rule: string;
+  readonly message: string;
+  readonly fix?: LintFix;
+}
+
+/** What the linter knows about the symbol an annotation describes. */
+export interface LintContext {
+  /** The declared name, when the code next to the block shows one. */
+  readonly name?: string;
+}
+
+export interface LintRule {
+  readonly name: string;
+  readonly description: string;
+  check(
+    metadata: Readonly<Record<string, unknown>>,
+    context?: LintContext,
+  ): readonly LintFinding[];
+}
+
+/** One parsed annotation block, as seen by a batch rule. */
+export interface LintTarget {
+  readonly filePath: string;
+  readonly line: number;
+  readonly metadata: Readonly<Record<string, unknown>>;
+}
+
+/** A rule that checks every annotation at once; its issues are not fixable. */
+export interface BatchLintRule {
+  readonly name: string;
+  readonly description: string;
+  checkAll(targets: readonly LintTarget[]): readonly LintIssue[];
+}
+
+export interface LintRuleConfig {
+  /** Descriptions shorter than this are flagged (default 10). */
+  readonly minDescriptionLength?: number;
+  /** Personal owner (username or email) to team name, used by --fix. */
+  readonly ownerMap?: Readonly<Record<string, string>>;
+  /** Lowest passing description score per revenue impact. */
+  readonly descriptionThresholds?: DescriptionThresholds;
+}
+
+export type DescriptionProblem =
+  | 'todo'
+  | 'boilerplate'
+  | 'restates-name'
+  | 'short';
+
+export interface DescriptionScore {
+  /** 0 to 100; higher tells a reader more. */
+  readonly score: number;
+  readonly problems: readonly DescriptionProblem[];
+}
+
+/** Lowest passing score per revenue impact; `unset` for entities without one. */
+export type DescriptionThresholds = Readonly<
+  Partial<Record<RevenueImpact | 'unset', number>>
+>;
+
+export interface LintIssue {
+  readonly filePath: string;
+  readonly line: number;
+  readonly rule: string;
+  readonly message: string;
+  readonly fix?: string;
+}
+
+export interface LintResult {
+  readonly issues: readonly LintIssue[];
+  readonly fileCount: number;
+  readonly fixableCount: number;
+  /** Number of fixes written back; zero unless linting with fix enabled. */
+  readonly fixedCount: number;
+  readonly fixedFiles: readonly string[];
+  /** Issues hidden by `knowgraph:ignore` comments in their annotation. */
+  readonly suppressed: readonly SuppressedIssue<LintIssue>[];
+}
+
+export interface LintOptions {
+  readonly fix?: boolean;
+  /** Called with both versions of each file before a fix is written. */
+  readonly onFix?: (filePath: string, before: string, after: string) => void;
+  /** With `fix`, report fixes and call `onFix` but leave files untouched. */
+  readonly dryRun?: boolean;
+}
@@ -0,0 +1,47 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { spawnSync } from 'node:child_process';
+import {
+  existsSync,
+  mkdtempSync,
+  readFileSync,
+  rmSync,
+  writeFileSync,
+} from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { acquireFileLock, withFileLock } from '../file-lock.js';
+
+describe('file lock', () => {
+  let dir: string;
+  let path: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-lock-'));
+    path = join(dir, 'audit.jsonl');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('holds a pid lockfile until released', () => {
+    const release = acquireFileLock(path);
+    expect(readFileSync(`${path}.lock`, 'utf-8')).toBe(String(process.pid));
+    expect(() => acquireFileLock(path, { timeoutMs: 50 })).toThrow(
+      expect.objectContaining({ kind: 'timeout' }),
+    );
+    release();
+    release();
+    expect(existsSync(`${path}.lock`)).toBe(false);
+    expect(withFileLock(path, () => 'done')).toBe('done');
+    expect(existsSync(`${path}.lock`)).toBe(false);
+  });
+
+  it('takes over a lock left by a process that exited', () => {
+    const { pid } = spawnSync(process.execPath, ['-e', '']);
+    writeFileSync(`${path}.lock`, String(pid));
+    const release = acquireFileLock(path, { timeoutMs: 50 });
+    expect(readFileSync(`${path}.lock`, 'utf-8')).toBe(String(process.pid));
+    release();
+  });
+});
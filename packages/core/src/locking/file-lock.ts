@@ -0,0 +1,125 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Exclusive lockfiles created with O_EXCL beside a file, with stale locks from dead processes taken over
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [locking, concurrency, filesystem, lockfile]
+ * context:
+ *   business_goal: Keep concurrent knowgraph runs from overwriting each other's audit entries and indexes
+ *   domain: locking
+ */
+import {
+  closeSync,
+  mkdirSync,
+  openSync,
+  readFileSync,
+  unlinkSync,
+  writeSync,
+} from 'node:fs';
+import { dirname } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+
+export interface FileLockOptions {
+  /** Give up waiting for another holder after this long. */
+  readonly timeoutMs?: number;
+  /** Wait this long between attempts. */
+  readonly retryMs?: number;
+}
+
+/** Releases a lock; calling it again does nothing. */
+export type ReleaseLock = () => void;
+
+const DEFAULT_TIMEOUT_MS = 10_000;
+const DEFAULT_RETRY_MS = 25;
+
+function sleepSync(ms: number): void {
+  Atomics.wait(new Int32Array(new SharedArrayBuffer(4)), 0, 0, ms);
+}
+
+/** Whether the lockfile names a process on this host that has exited. */
+function isStale(lockPath: string): boolean {
+  let pid: number;
+  try {
+    pid = Number.parseInt(readFileSync(lockPath, 'utf-8'), 10);
+  } catch {
+    // Removed by its holder in the meantime; try again
+    return false;
+  }
+  // Empty while its holder is still writing the pid
+  if (!Number.isInteger(pid) || pid <= 0) return false;
+  try {
+    process.kill(pid, 0);
+    return false;
+  } catch (err) {
+    return (err as NodeJS.ErrnoException).code === 'ESRCH';
+  }
+}
+
+/**
+ * Take the exclusive lock on `path`: `<path>.lock`, created with `O_EXCL`
+ * so only one process can hold it, and holding the holder's pid. A lock
+ * left by a process that exited without releasing it is taken over.
+ * Waits for a live holder, and throws a timeout error when it keeps the
+ * lock past `timeoutMs`.
+ */
+export function acquireFileLock(
+  path: string,
+  options: FileLockOptions = {},
+): ReleaseLock {
+  const lockPath = `${path}.lock`;
+  const timeoutMs = options.timeoutMs ?? DEFAULT_TIMEOUT_MS;
+  const retryMs = options.retryMs ?? DEFAULT_RETRY_MS;
+  const deadline = Date.now() + timeoutMs;
+  mkdirSync(dirname(lockPath), { recursive: true });
+  for (;;) {
+    let fd: number;
+    try {
+      fd = openSync(lockPath, 'wx');
+    } catch (err) {
+      if ((err as NodeJS.ErrnoException).code !== 'EEXIST') throw err;
+      if (isStale(lockPath)) {
+        try {
+          unlinkSync(lockPath);
+        } catch {
+          // Another waiter took it over first
+        }
+        continue;
+      }
+      if (Date.now() >= deadline) {
+        throw createKnowgraphError(
+          'timeout',
+          `Timed out waiting for ${lockPath}; another knowgraph run holds it. Remove it if no run is active.`,
+        );
+      }
+      sleepSync(retryMs);
+      continue;
+    }
+    writeSync(fd, String(process.pid));
+    closeSync(fd);
+    let released = false;
+    return () => {
+      if (released) return;
+      released = true;
+      try {
+        unlinkSync(lockPath);
+      } catch {
+        // Already gone
+      }
+    };
+  }
+}
+
+/** Run `fn` holding the lock on `path`, releasing it however `fn` ends. */
+export function withFileLock<T>(
+  path: string,
+  fn: () => T,
+  options: FileLockOptions = {},
+): T {
+  const release = acquireFileLock(path, options);
+  try {
+    return fn();
+  } finally {
+    release();
+  }
+}
@@ -0,0 +1,2 @@
+export type { FileLockOptions, ReleaseLock } from './file-lock.js';
+export { acquireFileLock, withFileLock } from './file-lock.js';
@@ -0,0 +1,67 @@
+import { describe, it, expect } from 'vitest';
+import { createLogger, parseLogFormat, parseLogLevel } from '../logger.js';
+import type { LoggerOptions } from '../types.js';
+
+const NOW = new Date('2024-05-02T10:00:00.000Z');
+
+function capture(options: LoggerOptions) {
+  const lines: string[] = [];
+  const logger = createLogger({
+    ...options,
+    write: (line) => lines.push(line),
+    now: () => NOW,
+  });
+  return { logger, lines };
+}
+
+describe('createLogger', () => {
+  it('drops records below the level', () => {
+    const { logger, lines } = capture({ level: 'warn' });
+
+    logger.debug('parsing');
+    logger.info('indexed');
+    logger.warn('sink skipped');
+    logger.error('scan failed');
+
+    expect(lines).toEqual(['sink skipped', 'scan failed']);
+    expect(logger.enabled('info')).toBe(false);
+    expect(logger.enabled('error')).toBe(true);
+  });
+
+  it('writes JSON records with bound and record fields', () => {
+    const { logger, lines } = capture({ format: 'json', level: 'debug' });
+
+    logger
+      .with({ component: 'scan', files: 1 })
+      .debug('Rescanned', { files: 12, error: new Error('boom') });
+
+    expect(JSON.parse(lines[0])).toEqual({
+      time: '2024-05-02T10:00:00.000Z',
+      level: 'debug',
+      msg: 'Rescanned',
+      component: 'scan',
+      files: 12,
+      error: 'boom',
+    });
+  });
+
+  it('prefixes text lines with the time and styles them', () => {
+    const { logger, lines } = capture({
+      timestamps: true,
+      colorize: (level, line) => `${level}:${line}`,
+    });
+
+    logger.info('Rescanned', { files: 12 });
+
+    expect(lines).toEqual(['info:[2024-05-02T10:00:00.000Z] Rescanned']);
+  });
+});
+
+describe('parseLogLevel and parseLogFormat', () => {
+  it('accepts known values and rejects others as usage errors', () => {
+    expect(parseLogLevel('debug')).toBe('debug');
+    expect(parseLogFormat('json')).toBe('json');
+    expect(() => parseLogLevel('verbose')).toThrow(/Unknown log level/);
+    expect(() => parseLogFormat('xml')).toThrow(/Unknown log format/);
+  });
+});
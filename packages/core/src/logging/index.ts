@@ -0,0 +1,13 @@
+export type {
+  LogLevel,
+  LogFormat,
+  LogFields,
+  Logger,
+  LoggerOptions,
+} from './types.js';
+export {
+  LOG_LEVELS,
+  createLogger,
+  parseLogFormat,
+  parseLogLevel,
+} from './logger.js';
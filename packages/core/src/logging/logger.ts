@@ -0,0 +1,95 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Leveled logger that writes records as plain text or as one JSON object per line
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [logging, observability, json]
+ * context:
+ *   business_goal: Give CI and the serve daemon logs that machines can parse and people can filter
+ *   domain: logging
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type {
+  LogFields,
+  LogFormat,
+  LogLevel,
+  Logger,
+  LoggerOptions,
+} from './types.js';
+
+/** Every level, from the most to the least verbose. */
+export const LOG_LEVELS: readonly LogLevel[] = [
+  'debug',
+  'info',
+  'warn',
+  'error',
+];
+
+const LOG_FORMATS: readonly LogFormat[] = ['text', 'json'];
+
+export function parseLogLevel(value: string): LogLevel {
+  const level = LOG_LEVELS.find((candidate) => candidate === value);
+  if (!level) {
+    throw createKnowgraphError(
+      'usage',
+      `Unknown log level "${value}"; use one of ${LOG_LEVELS.join(', ')}`,
+    );
+  }
+  return level;
+}
+
+export function parseLogFormat(value: string): LogFormat {
+  const format = LOG_FORMATS.find((candidate) => candidate === value);
+  if (!format) {
+    throw createKnowgraphError(
+      'usage',
+      `Unknown log format "${value}"; use text or json`,
+    );
+  }
+  return format;
+}
+
+/** Errors become their message, since `JSON.stringify` drops it. */
+function toJsonValue(_key: string, value: unknown): unknown {
+  return value instanceof Error ? value.message : value;
+}
+
+/**
+ * A logger writing records at `options.level` and above. JSON records
+ * hold `time`, `level`, and `msg`, then the logger's fields and the
+ * record's, later ones winning; text lines hold the message only.
+ */
+export function createLogger(options: LoggerOptions = {}): Logger {
+  const threshold = LOG_LEVELS.indexOf(options.level ?? 'info');
+  const format = options.format ?? 'text';
+  const write =
+    options.write ?? ((line: string) => process.stderr.write(`${line}\n`));
+  const now = options.now ?? (() => new Date());
+
+  function build(bound: LogFields): Logger {
+    const enabled = (level: LogLevel) =>
+      LOG_LEVELS.indexOf(level) >= threshold;
+    const log = (level: LogLevel, message: string, fields: LogFields = {}) => {
+      if (!enabled(level)) return;
+      const time = now().toISOString();
+      if (format === 'json') {
+        const record = { time, level, msg: message, ...bound, ...fields };
+        write(JSON.stringify(record, toJsonValue));
+        return;
+      }
+      const line = options.timestamps ? `[${time}] ${message}` : message;
+      write(options.colorize ? options.colorize(level, line) : line);
+    };
+    return {
+      debug: (message, fields) => log('debug', message, fields),
+      info: (message, fields) => log('info', message, fields),
+      warn: (message, fields) => log('warn', message, fields),
+      error: (message, fields) => log('error', message, fields),
+      with: (fields) => build({ ...bound, ...fields }),
+      enabled,
+    };
+  }
+
+  return build({});
+}
@@ -0,0 +1,43 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for leveled, structured log records written as text or JSON lines
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [logging, observability, types, interface]
+ * context:
+ *   business_goal: Keep log fields consistent so log pipelines can index them
+ *   domain: logging
+ */
+
+export type LogLevel = 'debug' | 'info' | 'warn' | 'error';
+
+/** `text` prints the message only; `json` prints one object per line. */
+export type LogFormat = 'text' | 'json';
+
+/** Structured attributes of a record, such as counts and paths. */
+export type LogFields = Readonly<Record<string, unknown>>;
+
+export interface Logger {
+  debug(message: string, fields?: LogFields): void;
+  info(message: string, fields?: LogFields): void;
+  warn(message: string, fields?: LogFields): void;
+  error(message: string, fields?: LogFields): void;
+  /** A logger that adds `fields` to every record it writes. */
+  with(fields: LogFields): Logger;
+  /** Whether a record at `level` would be written. */
+  enabled(level: LogLevel): boolean;
+}
+
+export interface LoggerOptions {
+  /** Records below this level are dropped. Defaults to `info`. */
+  readonly level?: LogLevel;
+  readonly format?: LogFormat;
+  /** Prefix text lines with the time; JSON records always carry it. */
+  readonly timestamps?: boolean;
+  /** Styles a text line, such as coloring warnings. */
+  readonly colorize?: (level: LogLevel, line: string) => string;
+  /** Where lines go. Defaults to stderr. */
+  readonly write?: (line: string) => void;
+  readonly now?: () => Date;
+}
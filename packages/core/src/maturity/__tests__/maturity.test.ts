@@ -0,0 +1,188 @@
+import { describe, it, expect } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  DEFAULT_MATURITY_RUBRIC,
+  buildMaturityReport,
+  describeCriterion,
+  listTestFiles,
+} from '../maturity.js';
+
+const now = new Date('2024-07-01T00:00:00Z');
+
+function makeModule(
+  name: string,
+  filePath: string,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType: 'module',
+    description: `${name} module`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: null,
+    metadata: {
+      type: 'module',
+      description: `${name} module`,
+      last_reviewed: '2024-05-01',
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const runbook = { type: 'runbook' as const, url: 'https://runbooks/x' };
+
+describe('buildMaturityReport', () => {
+  it('levels each module and lists what each higher level needs', () => {
+    const report = buildMaturityReport(
+      [
+        makeModule('charges', 'src/charges.ts', { links: [runbook] }),
+        makeModule('refunds', 'src/refunds.ts', { owner: null }),
+        makeModule('ledger', 'src/ledger.ts', {
+          links: [runbook],
+          metadata: {
+            type: 'module',
+            description: 'ledger module',
+            last_reviewed: '2024-05-01',
+            slo: { availability: 99.9 },
+          },
+        }),
+        makeModule('helper', 'src/helper.ts', { entityType: 'function' }),
+      ],
+      {
+        testFiles: ['src/__tests__/charges.test.ts', 'src/ledger.spec.ts'],
+        now,
+      },
+    );
+    expect(
+      report.modules.map((module) => [
+        module.name,
+        module.level,
+        module.levelName,
+      ]),
+    ).toEqual([
+      ['refunds', 0, null],
+      ['charges', 4, 'operable'],
+      ['ledger', 5, 'measured'],
+    ]);
+    expect(report.counts).toEqual([1, 0, 0, 0, 1, 1]);
+    expect(report.modules[0].roadmap.map((step) => step.missing)).toEqual([
+      ['owner'],
+      [],
+      ['tests'],
+      ['runbook'],
+      ['slo'],
+    ]);
+    expect(report.modules[1].roadmap).toEqual([
+      { level: 5, name: 'measured', missing: ['slo'] },
+    ]);
+    expect(report.modules[2].roadmap).toEqual([]);
+  });
+
+  it('holds a module back at the first level it misses', () => {
+    const report = buildMaturityReport(
+      [
+        makeModule('stale', 'src/stale.ts', {
+          links: [runbook],
+          metadata: {
+            type: 'module',
+            description: 'stale module',
+            last_reviewed: '2023-01-01',
+          },
+        }),
+      ],
+      { testFiles: ['tests/test_other.py'], now },
+    );
+    expect(report.modules[0]).toMatchObject({
+      level: 1,
+      met: ['owner', 'runbook'],
+    });
+  });
+
+  it('grades against a configured rubric and review deadlines', () => {
+    const report = buildMaturityReport(
+      [
+        makeModule('api', 'svc/api.go', {
+          entityType: 'service',
+          metadata: {
+            type: 'service',
+            description: 'api service',
+            last_reviewed: '2024-05-01',
+            context: { revenue_impact: 'critical' },
+            operational: { on_call_team: 'payments-oncall' },
+          },
+        }),
+      ],
+      {
+        rubric: {
+          types: ['service'],
+          levels: [
+            {
+              name: 'staffed',
+              require: ['owner', 'field:operational.on_call_team'],
+            },
+            { name: 'verified', require: ['tests', 'fresh'] },
+          ],
+          freshWithinMonths: 12,
+        },
+        testFiles: ['svc/handlers_test.go'],
+        deadlines: { critical: 1 },
+        now,
+      },
+    );
+    expect(report.modules[0]).toMatchObject({
+      level: 1,
+      levelName: 'staffed',
+      roadmap: [{ level: 2, name: 'verified', missing: ['fresh'] }],
+    });
+  });
+});
+
+describe('describeCriterion', () => {
+  it('names built-in criteria and fields', () => {
+    expect(describeCriterion('runbook')).toBe('a runbook link');
+    expect(describeCriterion('field:operational.on_call_team')).toBe(
+      'operational.on_call_team set',
+    );
+    expect(DEFAULT_MATURITY_RUBRIC.levels).toHaveLength(5);
+  });
+});
+
+describe('listTestFiles', () => {
+  it('lists test files by name, skipping what an index skips', () => {
+    const dir = mkdtempSync(join(tmpdir(), 'knowgraph-maturity-'));
+    try {
+      mkdirSync(join(dir, 'src', '__tests__'), { recursive: true });
+      mkdirSync(join(dir, 'node_modules'));
+      for (const file of [
+        'src/charges.ts',
+        'src/__tests__/charges.test.ts',
+        'src/api_test.go',
+        'node_modules/lib.spec.js',
+      ]) {
+        writeFileSync(join(dir, file), '');
+      }
+      expect(listTestFiles(dir)).toEqual([
+        'src/__tests__/charges.test.ts',
+        'src/api_test.go',
+      ]);
+    } finally {
+      rmSync(dir, { recursive: true, force: true });
+    }
+  });
+});
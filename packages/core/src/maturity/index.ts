@@ -0,0 +1,15 @@
+export type {
+  MaturityCriterion,
+  MaturityLevel,
+  MaturityRubric,
+  MaturityOptions,
+  MaturityStep,
+  ModuleMaturity,
+  MaturityReport,
+} from './types.js';
+export {
+  DEFAULT_MATURITY_RUBRIC,
+  buildMaturityReport,
+  describeCriterion,
+  listTestFiles,
+} from './maturity.js';
@@ -0,0 +1,211 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Grades each module against a maturity rubric of ownership, freshness, tests, runbooks, and SLOs, and lists what it lacks for each level above its own
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [maturity, rubric, scorecard, roadmap]
+ * context:
+ *   business_goal: Show each team where its modules stand operationally and what to do next to raise them
+ *   domain: maturity
+ */
+import { posix } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import { buildFreshnessReport } from '../freshness/freshness.js';
+import type { ReviewDeadlines } from '../freshness/types.js';
+import { valueAtPath } from '../inbound/mapping.js';
+import { listIndexableFiles } from '../indexer/indexer.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { toPosixPath } from '../paths/paths.js';
+import { RevenueImpactSchema } from '../types/entity.js';
+import type {
+  MaturityCriterion,
+  MaturityOptions,
+  MaturityReport,
+  MaturityRubric,
+  ModuleMaturity,
+} from './types.js';
+
+export const DEFAULT_MATURITY_RUBRIC: MaturityRubric = {
+  types: ['module'],
+  levels: [
+    { name: 'owned', require: ['owner'] },
+    { name: 'documented', require: ['fresh'] },
+    { name: 'tested', require: ['tests'] },
+    { name: 'operable', require: ['runbook'] },
+    { name: 'measured', require: ['slo'] },
+  ],
+  freshWithinMonths: 6,
+};
+
+const CRITERIA: Readonly<Record<string, string>> = {
+  owner: 'an owner',
+  runbook: 'a runbook link',
+  slo: 'an SLO',
+  tests: 'tests for its file',
+  fresh: 'an annotation reviewed on schedule',
+};
+
+/** What meeting `criterion` takes, in words. */
+export function describeCriterion(criterion: MaturityCriterion): string {
+  return criterion.startsWith('field:')
+    ? `${criterion.slice('field:'.length)} set`
+    : (CRITERIA[criterion] ?? criterion);
+}
+
+// `name.test.ts`, `name_test.go`, `name.spec.js`, `test_name.py`
+const TEST_FILE = /^(?:test_(.+)|(.+?)[._](?:test|spec))\.[^.]+$/;
+const TEST_DIRS: ReadonlySet<string> = new Set(['__tests__', 'tests', 'test']);
+
+/**
+ * The files `testFiles` test, as `dir/stem` without extension, and the
+ * directories of Go packages with tests.
+ */
+function testedFiles(testFiles: readonly string[]): {
+  readonly stems: ReadonlySet<string>;
+  readonly goPackages: ReadonlySet<string>;
+} {
+  const stems = new Set<string>();
+  const goPackages = new Set<string>();
+  for (const file of testFiles) {
+    const path = toPosixPath(file);
+    const dir = posix.dirname(path);
+    if (path.endsWith('_test.go')) goPackages.add(dir);
+    const found = TEST_FILE.exec(posix.basename(path));
+    if (!found) continue;
+    const sourceDir = TEST_DIRS.has(posix.basename(dir))
+      ? posix.dirname(dir)
+      : dir;
+    stems.add(posix.join(sourceDir, found[1] ?? found[2]));
+  }
+  return { stems, goPackages };
+}
+
+/**
+ * The test files under `rootDir`, relative to it, for
+ * `MaturityOptions.testFiles`. Skips what an index would.
+ */
+export function listTestFiles(rootDir: string): readonly string[] {
+  return listIndexableFiles(rootDir, {
+    parse: () => [],
+    canParse: (filePath) => TEST_FILE.test(posix.basename(filePath)),
+  });
+}
+
+function isSet(value: unknown): boolean {
+  if (Array.isArray(value)) return value.length > 0;
+  return value !== undefined && value !== null && value !== '';
+}
+
+/**
+ * Review deadlines for freshness: those configured, and
+ * `freshWithinMonths` for every impact they leave out.
+ */
+function freshnessDeadlines(
+  rubric: MaturityRubric,
+  deadlines: ReviewDeadlines | undefined,
+): ReviewDeadlines {
+  const fallback = Object.fromEntries(
+    [...RevenueImpactSchema.options, 'unset'].map((impact) => [
+      impact,
+      rubric.freshWithinMonths,
+    ]),
+  );
+  return { ...fallback, ...deadlines };
+}
+
+/**
+ * Grade every entity of the rubric's types. A module reaches a level by
+ * meeting its criteria and those of every level below; its roadmap lists
+ * what each higher level still needs. An annotation is fresh until its
+ * review deadline passes, as `buildFreshnessReport` measures it.
+ */
+export function buildMaturityReport(
+  entities: readonly StoredEntity[],
+  options: MaturityOptions = {},
+): MaturityReport {
+  const rubric = options.rubric ?? DEFAULT_MATURITY_RUBRIC;
+  const now = options.now ?? new Date();
+  const types = new Set<string>(rubric.types);
+  const modules = entities.filter((entity) => types.has(entity.entityType));
+
+  const overdue = new Set(
+    buildFreshnessReport(modules, {
+      deadlines: freshnessDeadlines(rubric, options.deadlines),
+      now,
+    })
+      .entries.filter((entry) => entry.state === 'overdue')
+      .map((entry) => entry.entityId),
+  );
+  const tested = testedFiles(options.testFiles ?? []);
+  const hasTests = (entity: StoredEntity): boolean => {
+    const path = toPosixPath(entity.filePath);
+    const dir = posix.dirname(path);
+    const stem = posix.basename(path).replace(/\.[^.]+$/, '');
+    return (
+      tested.stems.has(posix.join(dir, stem)) ||
+      (path.endsWith('.go') && tested.goPackages.has(dir))
+    );
+  };
+  const meets = (
+    entity: StoredEntity,
+    criterion: MaturityCriterion,
+  ): boolean => {
+    switch (criterion) {
+      case 'owner':
+        return entity.owner !== null;
+      case 'runbook':
+        return entity.links.some((link) => link.type === 'runbook');
+      case 'slo':
+        return isSet(valueAtPath(entity.metadata, 'slo'));
+      case 'tests':
+        return hasTests(entity);
+      case 'fresh':
+        return !overdue.has(entity.id);
+      default:
+        return isSet(
+          valueAtPath(entity.metadata, criterion.slice('field:'.length)),
+        );
+    }
+  };
+
+  const criteria = [
+    ...new Set(rubric.levels.flatMap((level) => level.require)),
+  ];
+  const graded: ModuleMaturity[] = modules.map((entity) => {
+    const met = criteria.filter((criterion) => meets(entity, criterion));
+    const steps = rubric.levels.map((level, i) => ({
+      level: i + 1,
+      name: level.name,
+      missing: level.require.filter((criterion) => !met.includes(criterion)),
+    }));
+    const reached = steps.findIndex((step) => step.missing.length > 0);
+    const level = reached === -1 ? steps.length : reached;
+    return {
+      entityId: entity.id,
+      name: entity.name,
+      entityType: entity.entityType,
+      filePath: entity.filePath,
+      owner: entity.owner,
+      level,
+      levelName: level === 0 ? null : rubric.levels[level - 1].name,
+      met,
+      roadmap: steps.slice(level),
+    };
+  });
+  graded.sort(
+    (a, b) =>
+      a.level - b.level ||
+      compareStrings(a.filePath, b.filePath) ||
+      compareStrings(a.name, b.name),
+  );
+
+  const counts = rubric.levels.map(() => 0).concat(0);
+  for (const module of graded) counts[module.level] += 1;
+  return {
+    generatedAt: now.toISOString(),
+    levels: rubric.levels,
+    modules: graded,
+    counts,
+  };
+}
@@ -0,0 +1,86 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the maturity rubric modules are graded against and the level and roadmap each module gets
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [maturity, rubric, scorecard, roadmap, types, interface]
+ * context:
+ *   business_goal: Let each organization set its own bar for every maturity level
+ *   domain: maturity
+ */
+import type { ReviewDeadlines } from '../freshness/types.js';
+import type { EntityType } from '../types/entity.js';
+
+/**
+ * A built-in check, or `field:<path>` for an annotation field that must
+ * be set, such as `field:operational.on_call_team`.
+ */
+export type MaturityCriterion =
+  | 'owner'
+  | 'runbook'
+  | 'slo'
+  | 'tests'
+  | 'fresh'
+  | `field:${string}`;
+
+export interface MaturityLevel {
+  readonly name: string;
+  readonly require: readonly MaturityCriterion[];
+}
+
+export interface MaturityRubric {
+  /** Entity types graded as modules. */
+  readonly types: readonly EntityType[];
+  /** Lowest first; a module reaches a level by meeting it and all below. */
+  readonly levels: readonly MaturityLevel[];
+  /** Months an annotation stays fresh without a review deadline. */
+  readonly freshWithinMonths: number;
+}
+
+export interface MaturityOptions {
+  /** Defaults to `DEFAULT_MATURITY_RUBRIC`. */
+  readonly rubric?: MaturityRubric;
+  /**
+   * Test files in the repository, relative to its root, for `tests`. A
+   * module has tests when one is named for its file next to it or in a
+   * `__tests__`, `tests`, or `test` directory beside it, or, in Go, when
+   * its package has any.
+   */
+  readonly testFiles?: readonly string[];
+  /** Review deadlines by revenue impact, as `freshness` configures them. */
+  readonly deadlines?: ReviewDeadlines;
+  readonly now?: Date;
+}
+
+/** What a module still lacks for one level above its own. */
+export interface MaturityStep {
+  /** 1-based. */
+  readonly level: number;
+  readonly name: string;
+  readonly missing: readonly MaturityCriterion[];
+}
+
+export interface ModuleMaturity {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly owner: string | null;
+  /** The highest level reached, 0 for none. */
+  readonly level: number;
+  /** That level's name, null for none. */
+  readonly levelName: string | null;
+  readonly met: readonly MaturityCriterion[];
+  /** Each level above `level`, lowest first; empty at the top. */
+  readonly roadmap: readonly MaturityStep[];
+}
+
+export interface MaturityReport {
+  readonly generatedAt: string;
+  readonly levels: readonly MaturityLevel[];
+  /** Lowest level first, then by file. */
+  readonly modules: readonly ModuleMaturity[];
+  /** Modules at each level, from level 0. */
+  readonly counts: readonly number[];
+}
@@ -0,0 +1,119 @@
+import { describe, it, expect } from 'vitest';
+import { externalNode } from '../../graph/graph-builder.js';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import {
+  filterNamespaces,
+  matchesNamespace,
+  namespaceGraph,
+  parseNamespace,
+} from '../namespace.js';
+
+function node(id: string, overrides: Partial<GraphNode> = {}): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+    ...overrides,
+  };
+}
+
+function edge(from: string, to: string) {
+  return {
+    from,
+    to,
+    kind: 'service' as const,
+    provenance: 'declared' as const,
+    confidence: 1,
+  };
+}
+
+describe('parseNamespace', () => {
+  it('accepts org and org/repo segments', () => {
+    expect(parseNamespace('acme')).toBe('acme');
+    expect(parseNamespace(' acme/payments-api ')).toBe('acme/payments-api');
+    expect(parseNamespace('acme/team.v2/repo_1')).toBe('acme/team.v2/repo_1');
+  });
+
+  it('rejects empty segments, colons, and wildcards', () => {
+    for (const value of ['', 'acme/', '/acme', 'acme//x', 'a:b', '*', '-a']) {
+      expect(() => parseNamespace(value)).toThrow('Invalid namespace');
+    }
+  });
+});
+
+describe('matchesNamespace', () => {
+  it('matches the namespace, namespaces under it, and *', () => {
+    expect(matchesNamespace('acme/payments', 'acme/payments')).toBe(true);
+    expect(matchesNamespace('acme/payments', 'acme')).toBe(true);
+    expect(matchesNamespace('acme/payments', '*')).toBe(true);
+    expect(matchesNamespace('acme/payments', 'acme/pay')).toBe(false);
+    expect(matchesNamespace('acme', 'acme/payments')).toBe(false);
+    expect(matchesNamespace(undefined, '*')).toBe(false);
+  });
+});
+
+describe('namespaceGraph', () => {
+  it('prefixes internal ids and edge ends but not external stubs', () => {
+    const graph: DependencyGraph = {
+      nodes: [node('checkout'), externalNode('service', 'payments')],
+      edges: [edge('checkout', 'external:service:payments')],
+    };
+    expect(namespaceGraph(graph, 'acme/shop')).toEqual({
+      nodes: [
+        node('acme/shop:checkout', {
+          name: 'checkout',
+          filePath: 'src/checkout.ts',
+          namespace: 'acme/shop',
+        }),
+        externalNode('service', 'payments'),
+      ],
+      edges: [edge('acme/shop:checkout', 'external:service:payments')],
+    });
+  });
+});
+
+describe('filterNamespaces', () => {
+  const graph: DependencyGraph = {
+    nodes: [
+      node('acme/shop:checkout', { namespace: 'acme/shop' }),
+      node('acme/payments:payments', { namespace: 'acme/payments' }),
+      node('globex/ledger:ledger', { namespace: 'globex/ledger' }),
+      externalNode('database', 'orders-db'),
+      externalNode('database', 'ledger-db'),
+      node('unscoped'),
+    ],
+    edges: [
+      edge('acme/shop:checkout', 'acme/payments:payments'),
+      edge('acme/shop:checkout', 'external:database:orders-db'),
+      edge('acme/payments:payments', 'globex/ledger:ledger'),
+      edge('globex/ledger:ledger', 'external:database:ledger-db'),
+    ],
+  };
+
+  it('keeps matching namespaces and the external stubs they use', () => {
+    const shop = filterNamespaces(graph, ['acme/shop']);
+    expect(shop.nodes.map((n) => n.id)).toEqual([
+      'acme/shop:checkout',
+      'external:database:orders-db',
+    ]);
+    expect(shop.edges).toEqual([
+      edge('acme/shop:checkout', 'external:database:orders-db'),
+    ]);
+  });
+
+  it('keeps edges between namespaces that both match', () => {
+    const acme = filterNamespaces(graph, ['acme']);
+    expect(acme.nodes.map((n) => n.id)).toEqual([
+      'acme/shop:checkout',
+      'acme/payments:payments',
+      'external:database:orders-db',
+    ]);
+    expect(acme.edges).toHaveLength(2);
+    expect(filterNamespaces(graph, []).nodes).toEqual([]);
+  });
+});
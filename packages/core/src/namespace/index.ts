@@ -0,0 +1,9 @@
+export {
+  NAMESPACE_PATTERN,
+  filterNamespaces,
+  matchesNamespace,
+  namespaceGraph,
+  namespaceNode,
+  namespacedId,
+  parseNamespace,
+} from './namespace.js';
@@ -0,0 +1,117 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses org/repo namespaces, prefixes node ids with them, and cuts graphs down to chosen namespaces
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [namespace, multi-tenant, graph, filter]
+ * context:
+ *   business_goal: Host graphs for many teams in one instance without node id collisions
+ *   domain: namespace
+ */
+import type { DependencyGraph, GraphEdge, GraphNode } from '../graph/types.js';
+
+/**
+ * One or more `/`-separated segments such as `acme` or `acme/payments`,
+ * each starting with a letter or digit and holding only letters, digits,
+ * `.`, `_`, and `-`.
+ */
+export const NAMESPACE_PATTERN =
+  /^[A-Za-z0-9][A-Za-z0-9._-]*(?:\/[A-Za-z0-9][A-Za-z0-9._-]*)*$/;
+
+/** Parse a namespace matching `NAMESPACE_PATTERN`. Throws on anything else. */
+export function parseNamespace(value: string): string {
+  const namespace = value.trim();
+  if (!NAMESPACE_PATTERN.test(namespace)) {
+    throw new Error(
+      `Invalid namespace "${value}": use org/repo segments of letters, digits, '.', '_', or '-'`,
+    );
+  }
+  return namespace;
+}
+
+/** `id` prefixed with its namespace: `acme/payments:<id>`. */
+export function namespacedId(namespace: string, id: string): string {
+  return `${namespace}:${id}`;
+}
+
+/**
+ * Whether `namespace` falls under `pattern`: the namespace itself, one it
+ * contains (`acme` covers `acme/payments`), or `*` for every namespace.
+ */
+export function matchesNamespace(
+  namespace: string | undefined,
+  pattern: string,
+): boolean {
+  if (namespace === undefined) return false;
+  return (
+    pattern === '*' ||
+    namespace === pattern ||
+    namespace.startsWith(`${pattern}/`)
+  );
+}
+
+/**
+ * `node` moved into `namespace`, with its id prefixed. External nodes are
+ * shared between namespaces and are returned as they are, so stubs for the
+ * same service still meet when graphs are stitched.
+ */
+export function namespaceNode(node: GraphNode, namespace: string): GraphNode {
+  if (node.external) return node;
+  return { ...node, id: namespacedId(namespace, node.id), namespace };
+}
+
+/**
+ * `graph` with every non-external node and its edge ends in `namespace`.
+ * Nodes already in a namespace, such as a submodule's, keep theirs.
+ */
+export function namespaceGraph(
+  graph: DependencyGraph,
+  namespace: string,
+): DependencyGraph {
+  const moved = (node: GraphNode): boolean =>
+    !node.external && node.namespace === undefined;
+  const internal = new Set(graph.nodes.filter(moved).map((node) => node.id));
+  const id = (value: string): string =>
+    internal.has(value) ? namespacedId(namespace, value) : value;
+  return {
+    nodes: graph.nodes.map((node) =>
+      moved(node) ? namespaceNode(node, namespace) : node,
+    ),
+    edges: graph.edges.map(
+      (edge): GraphEdge => ({ ...edge, from: id(edge.from), to: id(edge.to) }),
+    ),
+  };
+}
+
+/**
+ * The part of `graph` in namespaces matching any of `patterns`: their
+ * nodes, the edges between them, and the external nodes they depend on.
+ * Nodes without a namespace belong to none and are left out.
+ */
+export function filterNamespaces(
+  graph: DependencyGraph,
+  patterns: readonly string[],
+): DependencyGraph {
+  const kept = new Set(
+    graph.nodes
+      .filter((node) =>
+        patterns.some((pattern) => matchesNamespace(node.namespace, pattern)),
+      )
+      .map((node) => node.id),
+  );
+  const external = new Set(
+    graph.nodes.filter((node) => node.external).map((node) => node.id),
+  );
+  const edges = graph.edges.filter(
+    (edge) =>
+      kept.has(edge.from) && (kept.has(edge.to) || external.has(edge.to)),
+  );
+  const reached = new Set(edges.map((edge) => edge.to));
+  return {
+    nodes: graph.nodes.filter(
+      (node) => kept.has(node.id) || (node.external && reached.has(node.id)),
+    ),
+    edges,
+  };
+}
@@ -0,0 +1,222 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { createNotionDatabaseSync } from '../notion-database.js';
+import { diffNotionRow, notionRows } from '../rows.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { NotionRow } from '../types.js';
+
+function entity(
+  id: string,
+  entityType: string,
+  fields: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id,
+    name: id,
+    entityType,
+    description: `The ${id} ${entityType}`,
+    owner: 'payments-team',
+    status: 'stable',
+    tags: [],
+    links: [],
+    ...fields,
+  } as StoredEntity;
+}
+
+type Properties = Record<string, Record<string, unknown>>;
+
+/** A database behind a fake Notion API, which stores what is written. */
+function fakeNotion(schema: Record<string, string>) {
+  const database: Properties = Object.fromEntries(
+    Object.entries(schema).map(([name, type]) => [name, { type }]),
+  );
+  const pages = new Map<string, Properties>();
+  const writes: string[] = [];
+  const text = (parts: Array<{ text: { content: string } }>) =>
+    parts.map((part) => ({ plain_text: part.text.content }));
+  /** Written properties, in the shape Notion returns them. */
+  const stored = (properties: Properties): Properties =>
+    Object.fromEntries(
+      Object.entries(properties).map(([name, value]) => {
+        const [type, content] = Object.entries(value)[0];
+        return [
+          name,
+          type === 'title' || type === 'rich_text'
+            ? { type, [type]: text(content as []) }
+            : { type, [type]: content },
+        ];
+      }),
+    );
+
+  vi.stubGlobal(
+    'fetch',
+    vi.fn(async (url: string, init: RequestInit = {}) => {
+      const method = init.method ?? 'GET';
+      const body = init.body ? JSON.parse(String(init.body)) : {};
+      let response: object = {};
+      if (method !== 'GET' && !url.endsWith('/query')) {
+        writes.push(`${method} ${url.replace(/^.*\/v1/, '')}`);
+      }
+      if (url.endsWith('/query')) {
+        response = {
+          results: [...pages].map(([id, properties]) => ({ id, properties })),
+          has_more: false,
+        };
+      } else if (url.includes('/databases/')) {
+        if (method === 'PATCH') {
+          for (const [name, value] of Object.entries(body.properties)) {
+            database[name] = { type: Object.keys(value as object)[0] };
+          }
+        }
+        response = { properties: database };
+      } else if (method === 'POST') {
+        const id = `page-${pages.size + 1}`;
+        pages.set(id, stored(body.properties));
+        response = { id };
+      } else {
+        const id = url.slice(url.lastIndexOf('/') + 1);
+        pages.set(id, { ...pages.get(id), ...stored(body.properties) });
+      }
+      return {
+        ok: true,
+        status: 200,
+        statusText: 'OK',
+        text: async () => JSON.stringify(response),
+      };
+    }),
+  );
+  return { database, pages, writes };
+}
+
+const noWait = async () => {};
+
+describe('notionRows', () => {
+  it('keeps the configured types, with sorted tags', () => {
+    const rows = notionRows(
+      [
+        entity('ledger', 'service', {
+          owner: null,
+          tags: ['money', 'core', 'money'],
+          links: [{ url: 'https://wiki/ledger' }],
+        }),
+        entity('billing', 'module'),
+        entity('charge', 'function'),
+      ],
+      ['module', 'service'],
+    );
+    expect(rows.map((row) => row.id)).toEqual(['billing', 'ledger']);
+    expect(rows[1]).toMatchObject({
+      owner: '',
+      tags: ['core', 'money'],
+      links: ['https://wiki/ledger'],
+    });
+    expect(diffNotionRow({ ...rows[1], owner: 'ops' }, rows[1])).toEqual([
+      { field: 'owner', notion: 'ops', code: '' },
+    ]);
+  });
+});
+
+describe('createNotionDatabaseSync', () => {
+  const rows: readonly NotionRow[] = notionRows(
+    [
+      entity('billing', 'module', { tags: ['money'] }),
+      entity('ledger', 'service'),
+    ],
+    ['module', 'service'],
+  );
+  const database = () =>
+    createNotionDatabaseSync({
+      databaseId: 'db-1',
+      token: 'secret',
+      sleep: noWait,
+    });
+
+  let notion: ReturnType<typeof fakeNotion>;
+
+  beforeEach(() => {
+    notion = fakeNotion({ Service: 'title' });
+  });
+
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('adds the properties and rows, then leaves them alone', async () => {
+    const first = await database().sync(rows);
+    expect(first.rows.map((row) => [row.id, row.status])).toEqual([
+      ['billing', 'created'],
+      ['ledger', 'created'],
+    ]);
+    expect(notion.database).toMatchObject({
+      Service: { type: 'title' },
+      Owner: { type: 'rich_text' },
+      Status: { type: 'select' },
+      Tags: { type: 'multi_select' },
+      'Knowgraph ID': { type: 'rich_text' },
+    });
+    expect(notion.pages.get('page-1')?.Service).toEqual({
+      type: 'title',
+      title: [{ plain_text: 'billing' }],
+    });
+
+    notion.writes.length = 0;
+    const second = await database().sync(rows);
+    expect(second.rows.map((row) => row.status)).toEqual([
+      'unchanged',
+      'unchanged',
+    ]);
+    expect(notion.writes).toEqual([]);
+  });
+
+  it('flags rows edited in Notion instead of overwriting them', async () => {
+    await database().sync(rows);
+    notion.pages.get('page-1')!.Owner = {
+      type: 'rich_text',
+      rich_text: [{ plain_text: 'growth-team' }],
+    };
+    notion.writes.length = 0;
+
+    const result = await database().sync(rows);
+    expect(result.rows[0]).toEqual({
+      id: 'billing',
+      name: 'billing',
+      status: 'conflict',
+      pageId: 'page-1',
+      conflicts: [
+        { field: 'owner', notion: 'growth-team', code: 'payments-team' },
+      ],
+    });
+    expect(notion.writes).toEqual([]);
+
+    const forced = await database().sync(rows, { force: true });
+    expect(forced.rows[0].status).toBe('updated');
+    expect(notion.writes).toEqual(['PATCH /pages/page-1']);
+    expect(notion.pages.get('page-1')?.Owner).toEqual({
+      type: 'rich_text',
+      rich_text: [{ plain_text: 'payments-team' }],
+    });
+  });
+
+  it('updates rows the code changed, and reports orphaned ones', async () => {
+    await database().sync(rows);
+    const changed = [{ ...rows[0], status: 'deprecated' }];
+    const dry = await database().sync(changed, { dryRun: true });
+    expect(dry.rows[0].status).toBe('updated');
+    expect(dry.orphaned).toEqual(['ledger']);
+    expect(notion.pages.get('page-1')?.Status).toMatchObject({
+      select: { name: 'stable' },
+    });
+
+    const result = await database().sync(changed);
+    expect(result.rows[0]).toMatchObject({ status: 'updated', conflicts: [] });
+    expect(notion.pages.get('page-1')?.Status).toMatchObject({
+      select: { name: 'deprecated' },
+    });
+  });
+
+  it('refuses a property of another type', async () => {
+    notion = fakeNotion({ Name: 'title', Status: 'status' });
+    await expect(database().sync(rows)).rejects.toThrow(
+      'The Notion property Status is a status, not a select',
+    );
+  });
+});
@@ -0,0 +1,19 @@
+export type {
+  NotionDatabaseOptions,
+  NotionDatabaseSync,
+  NotionFieldConflict,
+  NotionRow,
+  NotionRowField,
+  NotionRowResult,
+  NotionRowStatus,
+  NotionRowValues,
+  NotionSyncOptions,
+  NotionSyncResult,
+} from './types.js';
+export { diffNotionRow, notionRowHash, notionRows } from './rows.js';
+export {
+  createNotionDatabaseSync,
+  NOTION_HASH_PROPERTY,
+  NOTION_ID_PROPERTY,
+  NOTION_PROPERTIES,
+} from './notion-database.js';
@@ -0,0 +1,269 @@
+/**
+ * @knowgraph
+ * type: service
+ * description: Keeps a Notion database's rows in line with the graph through the Notion API, flagging rows edited in Notion rather than overwriting them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [notion, sync, database, rest, conflicts]
+ * context:
+ *   business_goal: Give non-engineers a browsable catalog that cannot quietly drift from the code
+ *   domain: notion
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { createWarehouseClient } from '../warehouse/http.js';
+import { diffNotionRow, notionRowHash } from './rows.js';
+import type {
+  NotionDatabaseOptions,
+  NotionDatabaseSync,
+  NotionRow,
+  NotionRowResult,
+  NotionRowValues,
+  NotionSyncOptions,
+  NotionSyncResult,
+} from './types.js';
+
+const DEFAULT_BASE_URL = 'https://api.notion.com/v1';
+const NOTION_VERSION = '2022-06-28';
+/** Notion's limit on the content of one rich text object. */
+const TEXT_LIMIT = 2000;
+
+export const NOTION_ID_PROPERTY = 'Knowgraph ID';
+export const NOTION_HASH_PROPERTY = 'Knowgraph Hash';
+
+type PropertyType = 'rich_text' | 'select' | 'multi_select';
+type NotionProperty = Exclude<keyof NotionRowValues, 'name'>;
+
+/** The database properties knowgraph writes, besides the title. */
+export const NOTION_PROPERTIES: Readonly<
+  Record<NotionProperty, readonly [string, PropertyType]>
+> = {
+  type: ['Type', 'select'],
+  description: ['Description', 'rich_text'],
+  owner: ['Owner', 'rich_text'],
+  status: ['Status', 'select'],
+  tags: ['Tags', 'multi_select'],
+  links: ['Links', 'rich_text'],
+};
+
+interface PropertyValue {
+  readonly type?: string;
+  readonly title?: ReadonlyArray<{ readonly plain_text?: string }>;
+  readonly rich_text?: ReadonlyArray<{ readonly plain_text?: string }>;
+  readonly select?: { readonly name?: string } | null;
+  readonly multi_select?: ReadonlyArray<{ readonly name?: string }>;
+}
+
+interface NotionPageObject {
+  readonly id: string;
+  readonly properties: Readonly<Record<string, PropertyValue | undefined>>;
+}
+
+function richText(value: string): object[] {
+  const chunks: object[] = [];
+  for (let start = 0; start < value.length; start += TEXT_LIMIT) {
+    const content = value.slice(start, start + TEXT_LIMIT);
+    chunks.push({ type: 'text', text: { content } });
+  }
+  return chunks;
+}
+
+/** The text of a title, rich text, or select property. */
+function propertyText(value: PropertyValue | undefined): string {
+  if (!value) return '';
+  if (value.select !== undefined) return value.select?.name ?? '';
+  const parts = value.title ?? value.rich_text ?? [];
+  return parts.map((part) => part.plain_text ?? '').join('');
+}
+
+function readValues(page: NotionPageObject, title: string): NotionRowValues {
+  const property = (field: NotionProperty) =>
+    page.properties[NOTION_PROPERTIES[field][0]];
+  const tags = property('tags')?.multi_select ?? [];
+  return {
+    name: propertyText(page.properties[title]),
+    type: propertyText(property('type')),
+    description: propertyText(property('description')),
+    owner: propertyText(property('owner')),
+    status: propertyText(property('status')),
+    tags: tags.map((tag) => tag.name ?? '').sort(compareStrings),
+    links: propertyText(property('links'))
+      .split('\n')
+      .filter((link) => link !== ''),
+  };
+}
+
+function propertyValues(
+  row: NotionRow,
+  title: string,
+): Record<string, object> {
+  const values: Record<string, object> = {
+    [title]: { title: richText(row.name) },
+    [NOTION_ID_PROPERTY]: { rich_text: richText(row.id) },
+    [NOTION_HASH_PROPERTY]: { rich_text: richText(notionRowHash(row)) },
+  };
+  for (const [field, [name, type]] of Object.entries(NOTION_PROPERTIES)) {
+    const value = row[field as NotionProperty];
+    const text = typeof value === 'string' ? value : value.join('\n');
+    values[name] =
+      type === 'multi_select'
+        ? { multi_select: [...value].map((tag) => ({ name: tag })) }
+        : type === 'select'
+          ? { select: text === '' ? null : { name: text } }
+          : { rich_text: richText(text) };
+  }
+  return values;
+}
+
+/**
+ * Sync rows into the database `databaseId`, matched on the
+ * `Knowgraph ID` property. Each row keeps a hash of the values knowgraph
+ * last wrote in `Knowgraph Hash`: when the row's values no longer have
+ * that hash, someone edited it in Notion, and if the edits disagree with
+ * the code the row is reported as a conflict and left alone. Edits that
+ * agree with the code are kept. Rows for entities that left the graph
+ * are reported, not deleted.
+ */
+export function createNotionDatabaseSync(
+  options: NotionDatabaseOptions,
+): NotionDatabaseSync {
+  const client = createWarehouseClient('Notion', options);
+  const api = (options.baseUrl ?? DEFAULT_BASE_URL).replace(/\/$/, '');
+  const database = `${api}/databases/${encodeURIComponent(options.databaseId)}`;
+  const headers = {
+    Authorization: `Bearer ${options.token}`,
+    'Notion-Version': NOTION_VERSION,
+    'Content-Type': 'application/json',
+  };
+
+  /** Add the missing properties, and return the title property's name. */
+  async function prepareDatabase(dryRun: boolean): Promise<string> {
+    const { body } = await client.request(database, { headers });
+    const existing = (body.properties ?? {}) as Readonly<
+      Record<string, PropertyValue>
+    >;
+    const title = Object.keys(existing).find(
+      (name) => existing[name].type === 'title',
+    );
+    if (title === undefined) {
+      throw createKnowgraphError(
+        'io',
+        `Notion database ${options.databaseId} has no title property`,
+      );
+    }
+    const wanted: ReadonlyArray<readonly [string, PropertyType]> = [
+      ...Object.values(NOTION_PROPERTIES),
+      [NOTION_ID_PROPERTY, 'rich_text'],
+      [NOTION_HASH_PROPERTY, 'rich_text'],
+    ];
+    const missing: Record<string, object> = {};
+    for (const [name, type] of wanted) {
+      const property = existing[name];
+      if (!property) {
+        missing[name] = { [type]: {} };
+      } else if (property.type !== type) {
+        throw createKnowgraphError(
+          'usage',
+          `The Notion property ${name} is a ${property.type}, not a ${type}; rename it so knowgraph can add its own`,
+        );
+      }
+    }
+    if (!dryRun && Object.keys(missing).length > 0) {
+      await client.request(database, {
+        method: 'PATCH',
+        headers,
+        body: JSON.stringify({ properties: missing }),
+      });
+    }
+    return title;
+  }
+
+  async function queryPages(): Promise<NotionPageObject[]> {
+    const pages: NotionPageObject[] = [];
+    let cursor: string | undefined;
+    do {
+      const { body } = await client.request(`${database}/query`, {
+        method: 'POST',
+        headers,
+        body: JSON.stringify({
+          page_size: 100,
+          ...(cursor ? { start_cursor: cursor } : {}),
+        }),
+      });
+      pages.push(...((body.results ?? []) as NotionPageObject[]));
+      cursor =
+        body.has_more && typeof body.next_cursor === 'string'
+          ? body.next_cursor
+          : undefined;
+    } while (cursor);
+    return pages;
+  }
+
+  async function sync(
+    rows: readonly NotionRow[],
+    syncOptions: NotionSyncOptions = {},
+  ): Promise<NotionSyncResult> {
+    const { force = false, dryRun = false } = syncOptions;
+    const title = await prepareDatabase(dryRun);
+    const pages = new Map<string, NotionPageObject>();
+    for (const page of await queryPages()) {
+      const id = propertyText(page.properties[NOTION_ID_PROPERTY]);
+      if (id !== '' && !pages.has(id)) pages.set(id, page);
+    }
+
+    const results: NotionRowResult[] = [];
+    for (const row of rows) {
+      const base = { id: row.id, name: row.name };
+      const page = pages.get(row.id);
+      const properties = propertyValues(row, title);
+      if (!page) {
+        let pageId: string | undefined;
+        if (!dryRun) {
+          const { body } = await client.request(`${api}/pages`, {
+            method: 'POST',
+            headers,
+            body: JSON.stringify({
+              parent: { database_id: options.databaseId },
+              properties,
+            }),
+          });
+          pageId = body.id as string;
+        }
+        results.push({
+          ...base,
+          status: 'created',
+          ...(pageId ? { pageId } : {}),
+          conflicts: [],
+        });
+        continue;
+      }
+
+      const notion = readValues(page, title);
+      const differences = diffNotionRow(notion, row);
+      const stored = propertyText(page.properties[NOTION_HASH_PROPERTY]);
+      const conflicts = stored === notionRowHash(notion) ? [] : differences;
+      const status =
+        differences.length === 0 && stored === notionRowHash(row)
+          ? 'unchanged'
+          : conflicts.length > 0 && !force
+            ? 'conflict'
+            : 'updated';
+      if (status === 'updated' && !dryRun) {
+        await client.request(`${api}/pages/${encodeURIComponent(page.id)}`, {
+          method: 'PATCH',
+          headers,
+          body: JSON.stringify({ properties }),
+        });
+      }
+      results.push({ ...base, status, pageId: page.id, conflicts });
+    }
+
+    const ids = new Set(rows.map((row) => row.id));
+    const orphaned = [...pages.keys()]
+      .filter((id) => !ids.has(id))
+      .sort(compareStrings);
+    return { rows: results, orphaned };
+  }
+
+  return { sync };
+}
@@ -0,0 +1,79 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Builds the Notion database rows for the graph's entities, and compares them with what the database holds
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [notion, sync, rows, hashing, conflicts]
+ * context:
+ *   business_goal: Show the same facts about a module in Notion as in the graph
+ *   domain: notion
+ */
+import { createHash } from 'node:crypto';
+import { compareStrings, stableStringify } from '../canonical/canonical.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { EntityType } from '../types/entity.js';
+import type {
+  NotionFieldConflict,
+  NotionRow,
+  NotionRowField,
+  NotionRowValues,
+} from './types.js';
+
+const FIELDS: readonly NotionRowField[] = [
+  'name',
+  'type',
+  'description',
+  'owner',
+  'status',
+  'tags',
+  'links',
+];
+
+/** A row per entity of one of `types`, in name order. */
+export function notionRows(
+  entities: readonly StoredEntity[],
+  types: readonly EntityType[],
+): readonly NotionRow[] {
+  return entities
+    .filter((entity) => types.includes(entity.entityType))
+    .map((entity) => ({
+      id: entity.id,
+      name: entity.name,
+      type: entity.entityType,
+      description: entity.description,
+      owner: entity.owner ?? '',
+      status: entity.status ?? '',
+      tags: [...new Set(entity.tags)].sort(compareStrings),
+      links: [...new Set(entity.links.map((link) => link.url))],
+    }))
+    .sort(
+      (a, b) => compareStrings(a.name, b.name) || compareStrings(a.id, b.id),
+    );
+}
+
+/** A hash of the row's values, which the row keeps to spot Notion edits. */
+export function notionRowHash(values: NotionRowValues): string {
+  const fields = Object.fromEntries(
+    FIELDS.map((field) => [field, values[field]]),
+  );
+  return createHash('sha256')
+    .update(stableStringify(fields, false))
+    .digest('hex')
+    .slice(0, 16);
+}
+
+function fieldText(value: string | readonly string[]): string {
+  return typeof value === 'string' ? value : value.join(', ');
+}
+
+/** The fields where the Notion row differs from the code's. */
+export function diffNotionRow(
+  notion: NotionRowValues,
+  code: NotionRowValues,
+): readonly NotionFieldConflict[] {
+  return FIELDS.flatMap((field) => {
+    const [theirs, ours] = [fieldText(notion[field]), fieldText(code[field])];
+    return theirs === ours ? [] : [{ field, notion: theirs, code: ours }];
+  });
+}
@@ -0,0 +1,92 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for keeping a Notion database in sync with the graph's modules and services, and flagging rows edited in Notion
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [notion, sync, database, types, interface]
+ * context:
+ *   business_goal: Let the catalog sync run without depending on a live Notion workspace in tests
+ *   domain: notion
+ */
+import type { WarehouseSinkOptions } from '../warehouse/types.js';
+
+/** The values of one row, as the code or the Notion database has them. */
+export interface NotionRowValues {
+  readonly name: string;
+  readonly type: string;
+  readonly description: string;
+  /** Empty when unset, as Notion has no null text. */
+  readonly owner: string;
+  readonly status: string;
+  /** Sorted. */
+  readonly tags: readonly string[];
+  /** Link URLs, in the annotation's order. */
+  readonly links: readonly string[];
+}
+
+export type NotionRowField = keyof NotionRowValues;
+
+/** One entity's row, keyed on its id. */
+export interface NotionRow extends NotionRowValues {
+  readonly id: string;
+}
+
+/** A field someone changed in Notion to differ from the code. */
+export interface NotionFieldConflict {
+  readonly field: NotionRowField;
+  readonly notion: string;
+  readonly code: string;
+}
+
+/**
+ * What syncing did to a row. A row is a `conflict` when someone edited
+ * it in Notion since knowgraph last wrote it, and the edits disagree
+ * with the code; it is left as it is unless the sync is forced.
+ */
+export type NotionRowStatus = 'created' | 'updated' | 'unchanged' | 'conflict';
+
+export interface NotionRowResult {
+  readonly id: string;
+  readonly name: string;
+  readonly status: NotionRowStatus;
+  /** The Notion page, once there is one. */
+  readonly pageId?: string;
+  /** The diverging fields, for conflicts and for forced overwrites. */
+  readonly conflicts: readonly NotionFieldConflict[];
+}
+
+export interface NotionSyncResult {
+  readonly rows: readonly NotionRowResult[];
+  /** Ids of rows whose entity is no longer in the graph. */
+  readonly orphaned: readonly string[];
+}
+
+export interface NotionSyncOptions {
+  /** Overwrite rows edited in Notion with the code's values. */
+  readonly force?: boolean;
+  /** Read the database, but write nothing. */
+  readonly dryRun?: boolean;
+}
+
+export interface NotionDatabaseOptions
+  extends Pick<WarehouseSinkOptions, 'retry' | 'sleep'> {
+  readonly databaseId: string;
+  /** Token of an integration the database is shared with. */
+  readonly token: string;
+  /** API root (default: `https://api.notion.com/v1`). */
+  readonly baseUrl?: string;
+}
+
+export interface NotionDatabaseSync {
+  /**
+   * Add the knowgraph properties the database lacks, then create, update,
+   * or flag a row per entry of `rows`. Throws a usage error when a
+   * property exists with another type, and an I/O error when Notion
+   * rejects a request.
+   */
+  sync(
+    rows: readonly NotionRow[],
+    options?: NotionSyncOptions,
+  ): Promise<NotionSyncResult>;
+}
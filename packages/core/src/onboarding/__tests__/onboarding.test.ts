@@ -0,0 +1,167 @@
+import { describe, it, expect } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { ExtendedMetadata } from '../../types/entity.js';
+import {
+  buildOnboardingPath,
+  formatOnboardingMarkdown,
+  resolveOnboardingArea,
+  selectAreaEntities,
+} from '../onboarding.js';
+
+function makeEntity(
+  name: string,
+  services: string[] = [],
+  overrides: Partial<StoredEntity> = {},
+  metadata: Partial<ExtendedMetadata> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/billing/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'billing-team',
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `${name} service`,
+      context: { domain: 'billing' },
+      dependencies: { services },
+      ...metadata,
+    },
+    tags: ['billing'],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+// api -> invoices -> ledger, api -> ledger, cron -> ledger, ledger -> users
+const api = makeEntity('api', ['invoices', 'ledger']);
+const cron = makeEntity('cron', ['ledger']);
+const invoices = makeEntity('invoices', ['ledger']);
+const ledger = makeEntity('ledger', [], {}, {
+  dependencies: { services: ['users'], databases: ['postgres'] },
+});
+const users = makeEntity(
+  'users',
+  [],
+  { filePath: 'src/accounts/users.ts', tags: [], owner: 'identity-team' },
+  { context: { domain: 'accounts' } },
+);
+const entities = [api, cron, invoices, ledger, users];
+const graph = buildDependencyGraph(entities);
+const billing = { kind: 'domain', value: 'billing' } as const;
+
+describe('resolveOnboardingArea', () => {
+  it('honours an explicit kind prefix', () => {
+    expect(resolveOnboardingArea(entities, 'tag:billing')).toEqual({
+      kind: 'tag',
+      value: 'billing',
+    });
+    expect(resolveOnboardingArea(entities, 'module:src/accounts')).toEqual({
+      kind: 'module',
+      value: 'src/accounts',
+    });
+  });
+
+  it('tries a bare value as a domain, a tag, then a module path', () => {
+    expect(resolveOnboardingArea(entities, 'accounts').kind).toBe('domain');
+    const tagged = [makeEntity('x', [], { tags: ['payments'] })];
+    expect(resolveOnboardingArea(tagged, 'payments').kind).toBe('tag');
+    expect(resolveOnboardingArea(entities, 'src/billing').kind).toBe('module');
+  });
+});
+
+describe('selectAreaEntities', () => {
+  it('matches module paths on whole directory names', () => {
+    const area = { kind: 'module', value: 'src/bill' } as const;
+    expect(selectAreaEntities(entities, area)).toEqual([]);
+    expect(
+      selectAreaEntities(entities, { kind: 'module', value: 'src/accounts/' }),
+    ).toEqual([users]);
+  });
+});
+
+describe('buildOnboardingPath', () => {
+  it('puts entrypoints first, then dependencies by depth', () => {
+    const path = buildOnboardingPath(entities, graph, billing);
+    expect(path.steps.map((s) => [s.entity.name, s.role, s.depth])).toEqual([
+      ['api', 'entrypoint', 0],
+      ['cron', 'entrypoint', 0],
+      ['ledger', 'dependency', 1],
+      ['invoices', 'dependency', 1],
+    ]);
+    expect(path.outside.map((node) => node.name)).toEqual([
+      'postgres',
+      'users',
+    ]);
+  });
+
+  it('ranks steps at the same depth by centrality', () => {
+    const path = buildOnboardingPath(entities, graph, billing);
+    const [apiStep, cronStep, ledgerStep, invoicesStep] = path.steps;
+    expect(apiStep.centrality).toBeGreaterThan(cronStep.centrality);
+    expect(ledgerStep.centrality).toBeCloseTo(1);
+    expect(invoicesStep.centrality).toBeCloseTo(2 / 5);
+  });
+
+  it('enters a cycle nothing depends on at its most central entity', () => {
+    const a = makeEntity('a', ['b']);
+    const b = makeEntity('b', ['a', 'c']);
+    const c = makeEntity('c');
+    const cycle = [a, b, c];
+    const path = buildOnboardingPath(
+      cycle,
+      buildDependencyGraph(cycle),
+      billing,
+    );
+    expect(path.steps.map((s) => [s.entity.name, s.role])).toEqual([
+      ['b', 'entrypoint'],
+      ['a', 'dependency'],
+      ['c', 'dependency'],
+    ]);
+  });
+
+  it('keeps only the first steps when limited', () => {
+    const path = buildOnboardingPath(entities, graph, billing, { limit: 2 });
+    expect(path.steps).toHaveLength(2);
+    expect(path.outside.map((node) => node.name)).toEqual([
+      'ledger',
+      'invoices',
+    ]);
+  });
+});
+
+describe('formatOnboardingMarkdown', () => {
+  it('renders the reading order as a guide', () => {
+    const markdown = formatOnboardingMarkdown(
+      buildOnboardingPath(entities, graph, billing),
+    );
+    expect(markdown).toContain('# Onboarding: billing');
+    expect(markdown).toContain('## 1. api');
+    expect(markdown).toContain(
+      '`src/billing/api.ts:1` · service · owner billing-team · entrypoint',
+    );
+    expect(markdown).toContain('Depends on: **invoices**, **ledger**');
+    expect(markdown).toContain('Depends on: users, postgres (external)');
+    expect(markdown).toContain('- users `src/accounts/users.ts`');
+    expect(markdown).toContain('## Who to ask\n\n- billing-team');
+  });
+
+  it('says when the area is empty', () => {
+    const markdown = formatOnboardingMarkdown(
+      buildOnboardingPath(entities, graph, { kind: 'tag', value: 'none' }),
+    );
+    expect(markdown).toContain('No entities found for tag `none`.');
+  });
+});
@@ -0,0 +1,14 @@
+export type {
+  OnboardingArea,
+  OnboardingAreaKind,
+  OnboardingOptions,
+  OnboardingPath,
+  OnboardingRole,
+  OnboardingStep,
+} from './types.js';
+export {
+  buildOnboardingPath,
+  formatOnboardingMarkdown,
+  resolveOnboardingArea,
+  selectAreaEntities,
+} from './onboarding.js';
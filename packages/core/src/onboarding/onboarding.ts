@@ -0,0 +1,245 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Orders an area's entities for reading, entrypoints first, then dependencies by centrality
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [onboarding, reading-order, graph, markdown]
+ * context:
+ *   business_goal: Start newcomers on the code everything else depends on
+ *   domain: onboarding
+ */
+import { getEntityDomain } from '../graph/graph-builder.js';
+import type { DependencyGraph, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  OnboardingArea,
+  OnboardingAreaKind,
+  OnboardingOptions,
+  OnboardingPath,
+  OnboardingStep,
+} from './types.js';
+
+const AREA_KINDS: readonly OnboardingAreaKind[] = ['domain', 'tag', 'module'];
+
+function inArea(entity: StoredEntity, area: OnboardingArea): boolean {
+  switch (area.kind) {
+    case 'tag':
+      return entity.tags.includes(area.value);
+    case 'domain':
+      return getEntityDomain(entity) === area.value;
+    case 'module': {
+      const prefix = area.value.replace(/\/+$/, '');
+      return (
+        entity.filePath === prefix || entity.filePath.startsWith(`${prefix}/`)
+      );
+    }
+  }
+}
+
+/** The entities that belong to `area`. */
+export function selectAreaEntities(
+  entities: readonly StoredEntity[],
+  area: OnboardingArea,
+): readonly StoredEntity[] {
+  return entities.filter((entity) => inArea(entity, area));
+}
+
+/**
+ * Parse `tag:<name>`, `domain:<name>`, or `module:<path>`. A bare value is
+ * tried as a domain, then a tag, then a module path, and the first kind
+ * with any entities wins; it falls back to a domain when none match.
+ */
+export function resolveOnboardingArea(
+  entities: readonly StoredEntity[],
+  text: string,
+): OnboardingArea {
+  const separator = text.indexOf(':');
+  const prefix = text.slice(0, separator);
+  if (separator > 0 && (AREA_KINDS as readonly string[]).includes(prefix)) {
+    return {
+      kind: prefix as OnboardingAreaKind,
+      value: text.slice(separator + 1),
+    };
+  }
+  for (const kind of AREA_KINDS) {
+    const area = { kind, value: text };
+    if (entities.some((entity) => inArea(entity, area))) return area;
+  }
+  return { kind: 'domain', value: text };
+}
+
+function byCentrality(
+  centrality: ReadonlyMap<string, number>,
+  names: ReadonlyMap<string, string>,
+): (a: string, b: string) => number {
+  return (a: string, b: string): number =>
+    (centrality.get(b) ?? 0) - (centrality.get(a) ?? 0) ||
+    (names.get(a) ?? a).localeCompare(names.get(b) ?? b);
+}
+
+/**
+ * A suggested reading order for the entities in `area`. Entrypoints (area
+ * entities nothing else in the area depends on) come first, then the area
+ * entities they reach, nearest first. Ties are broken by degree centrality
+ * across the whole graph, so the most connected entities are read earlier.
+ * A cycle nothing leads into is entered at its most central entity.
+ */
+export function buildOnboardingPath(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  area: OnboardingArea,
+  options: OnboardingOptions = {},
+): OnboardingPath {
+  const members = new Map(
+    selectAreaEntities(entities, area).map((entity) => [entity.id, entity]),
+  );
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const names = new Map([...members.values()].map((e) => [e.id, e.name]));
+
+  const neighbours = new Map<string, Set<string>>();
+  const outgoing = new Map<string, string[]>();
+  const touch = (a: string, b: string): void => {
+    neighbours.set(a, (neighbours.get(a) ?? new Set()).add(b));
+  };
+  for (const edge of graph.edges) {
+    if (edge.from === edge.to) continue;
+    touch(edge.from, edge.to);
+    touch(edge.to, edge.from);
+    const targets = outgoing.get(edge.from) ?? [];
+    if (!targets.includes(edge.to)) targets.push(edge.to);
+    outgoing.set(edge.from, targets);
+  }
+  const others = Math.max(graph.nodes.length - 1, 1);
+  const centrality = new Map(
+    [...members.keys()].map((id) => [
+      id,
+      (neighbours.get(id)?.size ?? 0) / others,
+    ]),
+  );
+  const compare = byCentrality(centrality, names);
+
+  const dependedOn = new Set<string>();
+  for (const [id, targets] of outgoing) {
+    if (!members.has(id)) continue;
+    for (const target of targets) {
+      if (target !== id && members.has(target)) dependedOn.add(target);
+    }
+  }
+
+  const depths = new Map<string, number>();
+  const entrypoints = new Set<string>();
+  const visit = (seeds: readonly string[]): void => {
+    let frontier = seeds;
+    for (const id of seeds) depths.set(id, 0);
+    for (let depth = 1; frontier.length > 0; depth++) {
+      const next: string[] = [];
+      for (const id of frontier) {
+        for (const target of outgoing.get(id) ?? []) {
+          if (!members.has(target) || depths.has(target)) continue;
+          depths.set(target, depth);
+          next.push(target);
+        }
+      }
+      frontier = next;
+    }
+  };
+  const roots = [...members.keys()].filter((id) => !dependedOn.has(id));
+  roots.forEach((id) => entrypoints.add(id));
+  visit(roots);
+  for (;;) {
+    const [unreached] = [...members.keys()]
+      .filter((id) => !depths.has(id))
+      .sort(compare);
+    if (!unreached) break;
+    entrypoints.add(unreached);
+    visit([unreached]);
+  }
+
+  const order = [...members.keys()].sort(
+    (a, b) =>
+      Number(entrypoints.has(b)) - Number(entrypoints.has(a)) ||
+      (depths.get(a) ?? 0) - (depths.get(b) ?? 0) ||
+      compare(a, b),
+  );
+  const steps: OnboardingStep[] = order.map((id) => ({
+    entity: members.get(id)!,
+    role: entrypoints.has(id) ? 'entrypoint' : 'dependency',
+    depth: depths.get(id) ?? 0,
+    centrality: centrality.get(id) ?? 0,
+    dependencies: (outgoing.get(id) ?? [])
+      .map((target) => nodes.get(target))
+      .filter((node): node is GraphNode => node !== undefined),
+  }));
+  const kept =
+    options.limit !== undefined ? steps.slice(0, options.limit) : steps;
+
+  const counts = new Map<string, { node: GraphNode; count: number }>();
+  for (const step of kept) {
+    for (const node of step.dependencies) {
+      if (members.has(node.id)) continue;
+      const entry = counts.get(node.id) ?? { node, count: 0 };
+      counts.set(node.id, { node, count: entry.count + 1 });
+    }
+  }
+  const outside = [...counts.values()]
+    .sort(
+      (a, b) => b.count - a.count || a.node.name.localeCompare(b.node.name),
+    )
+    .map((entry) => entry.node);
+
+  return { area, steps: kept, outside };
+}
+
+function dependencyName(node: GraphNode, area: ReadonlySet<string>): string {
+  if (node.external) return `${node.name} (external)`;
+  return area.has(node.id) ? `**${node.name}**` : node.name;
+}
+
+/** The reading order as a Markdown onboarding guide. */
+export function formatOnboardingMarkdown(path: OnboardingPath): string {
+  const { area, steps } = path;
+  const ids = new Set(steps.map((step) => step.entity.id));
+  const lines = [`# Onboarding: ${area.value}`, ''];
+  if (steps.length === 0) {
+    lines.push(`No entities found for ${area.kind} \`${area.value}\`.`);
+    return `${lines.join('\n')}\n`;
+  }
+  lines.push(
+    `A suggested reading order for the ${steps.length} entities in ${area.kind} \`${area.value}\`. ` +
+      'Start with the entrypoints, then read what they depend on; ' +
+      'the most connected entities come first.',
+  );
+
+  steps.forEach((step, index) => {
+    const { entity } = step;
+    const facts = [
+      `\`${entity.filePath}:${entity.line}\``,
+      entity.entityType,
+      ...(entity.owner ? [`owner ${entity.owner}`] : []),
+      step.role,
+    ];
+    lines.push('', `## ${index + 1}. ${entity.name}`, '', facts.join(' · '));
+    lines.push('', entity.description);
+    if (step.dependencies.length > 0) {
+      const names = step.dependencies.map((node) => dependencyName(node, ids));
+      lines.push('', `Depends on: ${names.join(', ')}`);
+    }
+  });
+
+  if (path.outside.length > 0) {
+    lines.push('', '## Outside this area', '');
+    for (const node of path.outside) {
+      const where = node.external ? 'external' : `\`${node.filePath ?? ''}\``;
+      lines.push(`- ${node.name} ${where}`);
+    }
+  }
+
+  const owners = [
+    ...new Set(steps.flatMap((step) => step.entity.owner ?? [])),
+  ].sort();
+  if (owners.length > 0) {
+    lines.push('', '## Who to ask', '', ...owners.map((owner) => `- ${owner}`));
+  }
+  return `${lines.join('\n')}\n`;
+}
@@ -0,0 +1,50 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Types for onboarding reading orders built from an area of the dependency graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [onboarding, reading-order, graph, types]
+ * context:
+ *   business_goal: Let onboarding guides be rendered for wikis as well as the terminal
+ *   domain: onboarding
+ */
+import type { GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+
+/** How an area's entities are chosen: by tag, path prefix, or domain. */
+export type OnboardingAreaKind = 'tag' | 'module' | 'domain';
+
+export interface OnboardingArea {
+  readonly kind: OnboardingAreaKind;
+  readonly value: string;
+}
+
+/**
+ * `entrypoint` steps are not depended on by anything else in the area;
+ * `dependency` steps are reached by following dependencies from them.
+ */
+export type OnboardingRole = 'entrypoint' | 'dependency';
+
+export interface OnboardingStep {
+  readonly entity: StoredEntity;
+  readonly role: OnboardingRole;
+  /** Hops from the nearest entrypoint; 0 for entrypoints. */
+  readonly depth: number;
+  /** Share of the other graph nodes this entity has an edge to or from. */
+  readonly centrality: number;
+  /** What the entity depends on, inside the area or not. */
+  readonly dependencies: readonly GraphNode[];
+}
+
+export interface OnboardingPath {
+  readonly area: OnboardingArea;
+  readonly steps: readonly OnboardingStep[];
+  /** Nodes outside the area that steps depend on, most depended on first. */
+  readonly outside: readonly GraphNode[];
+}
+
+export interface OnboardingOptions {
+  /** Keep only the first this many steps (default: all). */
+  readonly limit?: number;
+}
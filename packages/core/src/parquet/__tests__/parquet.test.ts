@@ -0,0 +1,202 @@
+import { describe, it, expect } from 'vitest';
+import type { DependencyGraph } from '../../graph/types.js';
+import { graphEdgesTable, graphNodesTable } from '../graph-parquet.js';
+import { encodeParquet, isParquet } from '../parquet.js';
+import {
+  binary,
+  createByteWriter,
+  i32,
+  struct,
+  writeStruct,
+} from '../thrift.js';
+
+const GRAPH: DependencyGraph = {
+  nodes: [
+    {
+      id: 'checkout',
+      name: 'checkout',
+      entityType: 'service',
+      external: false,
+      filePath: 'src/checkout.ts',
+      owner: 'shop-team',
+      domain: null,
+      workspace: null,
+      aliases: ['cart'],
+    },
+    {
+      id: 'external:database:orders-db',
+      name: 'orders-db',
+      entityType: null,
+      external: true,
+      filePath: null,
+      owner: null,
+      domain: null,
+      workspace: null,
+    },
+  ],
+  edges: [
+    {
+      from: 'checkout',
+      to: 'external:database:orders-db',
+      kind: 'database',
+      provenance: 'declared',
+      confidence: 1,
+      protocol: 'postgres',
+      criticality: 'critical',
+    },
+  ],
+};
+
+function footerLength(file: Buffer): number {
+  return file.readUInt32LE(file.length - 8);
+}
+
+describe('thrift compact encoding', () => {
+  it('writes short field deltas in the header and long ones after it', () => {
+    const writer = createByteWriter();
+    writeStruct(writer, [
+      i32(1, 5),
+      undefined,
+      binary(4, 'ab'),
+      struct(20, []),
+    ]);
+    expect([...writer.finish()]).toEqual([
+      0x15, 0x0a, 0x38, 0x02, 0x61, 0x62, 0x0c, 0x28, 0x00, 0x00,
+    ]);
+  });
+});
+
+describe('encodeParquet', () => {
+  it('frames the file with magic and a footer length', () => {
+    const file = encodeParquet({
+      columns: [{ name: 'id', type: 'string', values: ['a', 'b'] }],
+    });
+    expect(isParquet(file)).toBe(true);
+    expect(file.subarray(0, 4).toString()).toBe('PAR1');
+    expect(footerLength(file)).toBeLessThan(file.length - 12);
+    expect(file.includes('knowgraph')).toBe(true);
+  });
+
+  it('writes definition levels as RLE runs before plain values', () => {
+    const file = encodeParquet(
+      {
+        columns: [
+          {
+            name: 'owner',
+            type: 'string',
+            nullable: true,
+            values: [null, 'x', null],
+          },
+        ],
+      },
+      { compress: false },
+    );
+    const page = Buffer.from([
+      // 6 bytes of levels: one 0, one 1, one 0
+      0x06, 0x00, 0x00, 0x00, 0x02, 0x00, 0x02, 0x01, 0x02, 0x00,
+      // One string of length 1
+      0x01, 0x00, 0x00, 0x00, 0x78,
+    ]);
+    expect(file.includes(page)).toBe(true);
+  });
+
+  it('writes list columns with repetition levels', () => {
+    const file = encodeParquet(
+      {
+        columns: [
+          { name: 'tags', type: 'string', list: true, values: [['a', 'b']] },
+        ],
+      },
+      { compress: false },
+    );
+    const levels = Buffer.from([
+      // Repetition levels: 0 for the first item, 1 for the next
+      0x04, 0x00, 0x00, 0x00, 0x02, 0x00, 0x02, 0x01,
+      // Definition levels: 1 for both, since the list itself is required
+      0x02, 0x00, 0x00, 0x00, 0x04, 0x01,
+    ]);
+    expect(file.includes(levels)).toBe(true);
+    expect(file.includes('element')).toBe(true);
+  });
+
+  it('gzips pages unless told not to', () => {
+    const values = Array.from({ length: 500 }, () => 'payments-team');
+    const table = {
+      columns: [{ name: 'owner', type: 'string' as const, values }],
+    };
+    expect(encodeParquet(table).length).toBeLessThan(
+      encodeParquet(table, { compress: false }).length / 10,
+    );
+  });
+
+  it('writes a table without rows', () => {
+    const file = encodeParquet({
+      columns: [{ name: 'id', type: 'string', values: [] }],
+    });
+    expect(isParquet(file)).toBe(true);
+  });
+
+  it('rejects nulls in required columns and ragged tables', () => {
+    expect(() =>
+      encodeParquet({
+        columns: [{ name: 'id', type: 'string', values: [null] }],
+      }),
+    ).toThrow('Null in required column id at row 0');
+    expect(() =>
+      encodeParquet({
+        columns: [
+          { name: 'id', type: 'string', values: ['a'] },
+          { name: 'name', type: 'string', values: [] },
+        ],
+      }),
+    ).toThrow('Column name has 0 values, not 1');
+  });
+});
+
+describe('graph Parquet tables', () => {
+  it('lays nodes out one per row with typed columns', () => {
+    const table = graphNodesTable(GRAPH);
+    const column = (name: string) =>
+      table.columns.find((c) => c.name === name);
+    expect(table.columns.map((c) => c.name)).toEqual([
+      'id',
+      'name',
+      'entity_type',
+      'external',
+      'file_path',
+      'owner',
+      'domain',
+      'workspace',
+      'namespace',
+      'aliases',
+      'annotated',
+      'stub',
+    ]);
+    expect(column('external')?.values).toEqual([false, true]);
+    expect(column('aliases')?.values).toEqual([['cart'], []]);
+    expect(column('annotated')?.values).toEqual([null, null]);
+    expect(isParquet(encodeParquet(table))).toBe(true);
+  });
+
+  it('lays edges out with snake_case endpoints', () => {
+    const table = graphEdgesTable(GRAPH);
+    expect(table.columns.map((c) => [c.name, c.type])).toEqual([
+      ['from_id', 'string'],
+      ['to_id', 'string'],
+      ['kind', 'string'],
+      ['provenance', 'string'],
+      ['confidence', 'double'],
+      ['requires', 'string'],
+      ['purpose', 'string'],
+      ['protocol', 'string'],
+      ['criticality', 'string'],
+    ]);
+    expect(table.columns[1].values).toEqual(['external:database:orders-db']);
+    expect(table.columns.slice(5).map((c) => c.values[0])).toEqual([
+      null,
+      null,
+      'postgres',
+      'critical',
+    ]);
+  });
+});
@@ -0,0 +1,148 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Lays dependency graph nodes and edges out as typed Parquet tables for analytics engines
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [parquet, graph, export, analytics]
+ * context:
+ *   business_goal: Let analytics engines query the graph alongside other engineering datasets
+ *   domain: export
+ */
+import type { DependencyGraph } from '../graph/types.js';
+import { encodeParquet } from './parquet.js';
+import type {
+  GraphParquetTable,
+  ParquetTable,
+  ParquetWriteOptions,
+} from './types.js';
+
+/**
+ * One row per node. Columns are snake_case so they need no quoting in
+ * SQL; `annotated` is null except on structural nodes such as Go packages.
+ */
+export function graphNodesTable(graph: DependencyGraph): ParquetTable {
+  const { nodes } = graph;
+  return {
+    columns: [
+      { name: 'id', type: 'string', values: nodes.map((n) => n.id) },
+      { name: 'name', type: 'string', values: nodes.map((n) => n.name) },
+      {
+        name: 'entity_type',
+        type: 'string',
+        nullable: true,
+        values: nodes.map((n) => n.entityType),
+      },
+      {
+        name: 'external',
+        type: 'boolean',
+        values: nodes.map((n) => n.external),
+      },
+      {
+        name: 'file_path',
+        type: 'string',
+        nullable: true,
+        values: nodes.map((n) => n.filePath),
+      },
+      {
+        name: 'owner',
+        type: 'string',
+        nullable: true,
+        values: nodes.map((n) => n.owner),
+      },
+      {
+        name: 'domain',
+        type: 'string',
+        nullable: true,
+        values: nodes.map((n) => n.domain),
+      },
+      {
+        name: 'workspace',
+        type: 'string',
+        nullable: true,
+        values: nodes.map((n) => n.workspace),
+      },
+      {
+        name: 'namespace',
+        type: 'string',
+        nullable: true,
+        values: nodes.map((n) => n.namespace ?? null),
+      },
+      {
+        name: 'aliases',
+        type: 'string',
+        list: true,
+        values: nodes.map((n) => n.aliases ?? []),
+      },
+      {
+        name: 'annotated',
+        type: 'boolean',
+        nullable: true,
+        values: nodes.map((n) => n.annotated ?? null),
+      },
+      {
+        name: 'stub',
+        type: 'boolean',
+        values: nodes.map((n) => n.stub ?? false),
+      },
+    ],
+  };
+}
+
+/** One row per edge, joined to nodes on `from_id` and `to_id`. */
+export function graphEdgesTable(graph: DependencyGraph): ParquetTable {
+  const { edges } = graph;
+  return {
+    columns: [
+      { name: 'from_id', type: 'string', values: edges.map((e) => e.from) },
+      { name: 'to_id', type: 'string', values: edges.map((e) => e.to) },
+      { name: 'kind', type: 'string', values: edges.map((e) => e.kind) },
+      {
+        name: 'provenance',
+        type: 'string',
+        values: edges.map((e) => e.provenance),
+      },
+      {
+        name: 'confidence',
+        type: 'double',
+        values: edges.map((e) => e.confidence),
+      },
+      {
+        name: 'requires',
+        type: 'string',
+        nullable: true,
+        values: edges.map((e) => e.requires ?? null),
+      },
+      {
+        name: 'purpose',
+        type: 'string',
+        nullable: true,
+        values: edges.map((e) => e.purpose ?? null),
+      },
+      {
+        name: 'protocol',
+        type: 'string',
+        nullable: true,
+        values: edges.map((e) => e.protocol ?? null),
+      },
+      {
+        name: 'criticality',
+        type: 'string',
+        nullable: true,
+        values: edges.map((e) => e.criticality ?? null),
+      },
+    ],
+  };
+}
+
+/** Encode one table of `graph` as a Parquet file. */
+export function encodeGraphParquet(
+  graph: DependencyGraph,
+  table: GraphParquetTable,
+  options: ParquetWriteOptions = {},
+): Buffer {
+  return encodeParquet(
+    table === 'nodes' ? graphNodesTable(graph) : graphEdgesTable(graph),
+    options,
+  );
+}
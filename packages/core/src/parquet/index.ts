@@ -0,0 +1,14 @@
+export type {
+  GraphParquetTable,
+  ParquetColumn,
+  ParquetTable,
+  ParquetValue,
+  ParquetValueType,
+  ParquetWriteOptions,
+} from './types.js';
+export { encodeParquet, isParquet } from './parquet.js';
+export {
+  encodeGraphParquet,
+  graphEdgesTable,
+  graphNodesTable,
+} from './graph-parquet.js';
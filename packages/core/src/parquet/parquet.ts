@@ -0,0 +1,284 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Writes typed columnar tables as Parquet files with plain-encoded, optionally gzipped data pages
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [parquet, binary, serialization, analytics, columnar]
+ * context:
+ *   business_goal: Write Parquet files any analytics engine can read without a native dependency
+ *   domain: export
+ */
+import { gzipSync } from 'node:zlib';
+import {
+  binary,
+  binaryList,
+  createByteWriter,
+  i32,
+  i32List,
+  i64,
+  struct,
+  structList,
+  writeStruct,
+} from './thrift.js';
+import type { ThriftStruct } from './thrift.js';
+import type {
+  ParquetColumn,
+  ParquetTable,
+  ParquetValue,
+  ParquetValueType,
+  ParquetWriteOptions,
+} from './types.js';
+
+const MAGIC = Buffer.from('PAR1', 'ascii');
+
+// parquet.thrift enum values
+const PHYSICAL_TYPES: Readonly<Record<ParquetValueType, number>> = {
+  boolean: 0,
+  int64: 2,
+  double: 5,
+  string: 6,
+};
+const REQUIRED = 0;
+const OPTIONAL = 1;
+const REPEATED = 2;
+const CONVERTED_UTF8 = 0;
+const CONVERTED_LIST = 3;
+const ENCODING_PLAIN = 0;
+const ENCODING_RLE = 3;
+const CODEC_UNCOMPRESSED = 0;
+const CODEC_GZIP = 2;
+const PAGE_DATA = 0;
+
+/*
+ * Layout: "PAR1", one row group holding one data page per column, the
+ * thrift-encoded FileMetaData footer, its 4-byte length, and "PAR1". A
+ * table without rows has no row group at all.
+ *
+ * Each page is version 1: repetition levels (list columns only) and
+ * definition levels (nullable and list columns), each as a 4-byte
+ * length and an RLE run per stretch of equal levels, then the non-null
+ * values in PLAIN encoding. List columns use the three-level layout
+ * `<name> (LIST) > list (repeated) > element` that DuckDB, Spark,
+ * BigQuery, and pandas all read as a list.
+ */
+
+interface Levels {
+  readonly repetition: number[];
+  readonly definition: number[];
+  readonly values: ParquetValue[];
+}
+
+function maxDefinition(column: ParquetColumn): number {
+  return (column.nullable ? 1 : 0) + (column.list ? 1 : 0);
+}
+
+/** Split a column into its levels and the values actually present. */
+function shred(column: ParquetColumn): Levels {
+  const levels: Levels = { repetition: [], definition: [], values: [] };
+  const max = maxDefinition(column);
+  for (const [row, value] of column.values.entries()) {
+    if (value === null || value === undefined) {
+      if (!column.nullable) {
+        throw new Error(`Null in required column ${column.name} at row ${row}`);
+      }
+      levels.repetition.push(0);
+      levels.definition.push(0);
+    } else if (column.list) {
+      const items = value as readonly ParquetValue[];
+      if (items.length === 0) {
+        levels.repetition.push(0);
+        levels.definition.push(max - 1);
+      }
+      for (const [index, item] of items.entries()) {
+        levels.repetition.push(index === 0 ? 0 : 1);
+        levels.definition.push(max);
+        levels.values.push(item);
+      }
+    } else {
+      levels.repetition.push(0);
+      levels.definition.push(max);
+      levels.values.push(value as ParquetValue);
+    }
+  }
+  return levels;
+}
+
+/** Levels as a 4-byte length and RLE runs, each level in one byte. */
+function encodeLevels(levels: readonly number[]): Buffer {
+  const runs = createByteWriter();
+  let start = 0;
+  while (start < levels.length) {
+    let end = start + 1;
+    while (end < levels.length && levels[end] === levels[start]) end++;
+    runs.varint((end - start) * 2);
+    runs.byte(levels[start]);
+    start = end;
+  }
+  const body = runs.finish();
+  const length = Buffer.alloc(4);
+  length.writeUInt32LE(body.length);
+  return Buffer.concat([length, body]);
+}
+
+function encodeValues(
+  type: ParquetValueType,
+  values: readonly ParquetValue[],
+): Buffer {
+  switch (type) {
+    case 'string': {
+      const parts = values.map((value) => {
+        const bytes = Buffer.from(value as string, 'utf-8');
+        const length = Buffer.alloc(4);
+        length.writeUInt32LE(bytes.length);
+        return Buffer.concat([length, bytes]);
+      });
+      return Buffer.concat(parts);
+    }
+    case 'boolean': {
+      // Bit-packed, least significant bit first
+      const bits = Buffer.alloc(Math.ceil(values.length / 8));
+      values.forEach((value, i) => {
+        if (value) bits[i >> 3] |= 1 << (i & 7);
+      });
+      return bits;
+    }
+    case 'int64': {
+      const out = Buffer.alloc(values.length * 8);
+      values.forEach((value, i) => out.writeBigInt64LE(BigInt(value), i * 8));
+      return out;
+    }
+    case 'double': {
+      const out = Buffer.alloc(values.length * 8);
+      values.forEach((value, i) => out.writeDoubleLE(value as number, i * 8));
+      return out;
+    }
+  }
+}
+
+function schemaElements(column: ParquetColumn): ThriftStruct[] {
+  const repetition = column.nullable ? OPTIONAL : REQUIRED;
+  const leaf = (name: string, repetitionType: number): ThriftStruct => [
+    i32(1, PHYSICAL_TYPES[column.type]),
+    i32(3, repetitionType),
+    binary(4, name),
+    column.type === 'string' ? i32(6, CONVERTED_UTF8) : undefined,
+    // LogicalType union: STRING is field 1
+    column.type === 'string' ? struct(10, [struct(1, [])]) : undefined,
+  ];
+  if (!column.list) return [leaf(column.name, repetition)];
+  return [
+    [
+      i32(3, repetition),
+      binary(4, column.name),
+      i32(5, 1),
+      i32(6, CONVERTED_LIST),
+      // LogicalType union: LIST is field 3
+      struct(10, [struct(3, [])]),
+    ],
+    [i32(3, REPEATED), binary(4, 'list'), i32(5, 1)],
+    leaf('element', REQUIRED),
+  ];
+}
+
+function columnPath(column: ParquetColumn): string[] {
+  return column.list ? [column.name, 'list', 'element'] : [column.name];
+}
+
+/**
+ * Encode `table` as a Parquet file. Every column must have the same
+ * number of values.
+ */
+export function encodeParquet(
+  table: ParquetTable,
+  options: ParquetWriteOptions = {},
+): Buffer {
+  const { compress = true, createdBy = 'knowgraph' } = options;
+  const rows = table.columns[0]?.values.length ?? 0;
+  for (const column of table.columns) {
+    if (column.values.length !== rows) {
+      throw new Error(
+        `Column ${column.name} has ${column.values.length} values, not ${rows}`,
+      );
+    }
+  }
+
+  const file = createByteWriter();
+  file.bytes(MAGIC);
+  const chunks: ThriftStruct[] = [];
+  let totalBytes = 0;
+  for (const column of rows > 0 ? table.columns : []) {
+    const levels = shred(column);
+    const body = Buffer.concat([
+      column.list ? encodeLevels(levels.repetition) : Buffer.alloc(0),
+      maxDefinition(column) > 0
+        ? encodeLevels(levels.definition)
+        : Buffer.alloc(0),
+      encodeValues(column.type, levels.values),
+    ]);
+    const data = compress ? gzipSync(body) : body;
+    const header = createByteWriter();
+    writeStruct(header, [
+      i32(1, PAGE_DATA),
+      i32(2, body.length),
+      i32(3, data.length),
+      struct(5, [
+        i32(1, levels.definition.length),
+        i32(2, ENCODING_PLAIN),
+        i32(3, ENCODING_RLE),
+        i32(4, ENCODING_RLE),
+      ]),
+    ]);
+    const offset = file.length;
+    const headerBytes = header.finish();
+    file.bytes(headerBytes);
+    file.bytes(data);
+    totalBytes += headerBytes.length + body.length;
+    chunks.push([
+      i64(2, offset),
+      struct(3, [
+        i32(1, PHYSICAL_TYPES[column.type]),
+        i32List(2, [ENCODING_PLAIN, ENCODING_RLE]),
+        binaryList(3, columnPath(column)),
+        i32(4, compress ? CODEC_GZIP : CODEC_UNCOMPRESSED),
+        i64(5, levels.definition.length),
+        i64(6, headerBytes.length + body.length),
+        i64(7, headerBytes.length + data.length),
+        i64(9, offset),
+      ]),
+    ]);
+  }
+
+  const footer = createByteWriter();
+  writeStruct(footer, [
+    i32(1, 1),
+    structList(2, [
+      [binary(4, 'schema'), i32(5, table.columns.length)],
+      ...table.columns.flatMap(schemaElements),
+    ]),
+    i64(3, rows),
+    structList(
+      4,
+      rows > 0
+        ? [[structList(1, chunks), i64(2, totalBytes), i64(3, rows)]]
+        : [],
+    ),
+    binary(6, createdBy),
+  ]);
+  const footerBytes = footer.finish();
+  const length = Buffer.alloc(4);
+  length.writeUInt32LE(footerBytes.length);
+  file.bytes(footerBytes);
+  file.bytes(length);
+  file.bytes(MAGIC);
+  return file.finish();
+}
+
+/** Whether `bytes` starts and ends with the Parquet magic. */
+export function isParquet(bytes: Uint8Array): boolean {
+  return (
+    bytes.length >= 12 &&
+    MAGIC.every((b, i) => bytes[i] === b) &&
+    MAGIC.every((b, i) => bytes[bytes.length - 4 + i] === b)
+  );
+}
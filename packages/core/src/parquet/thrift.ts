@@ -0,0 +1,173 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Thrift compact protocol encoder for the Parquet page headers and file footer
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [parquet, thrift, binary, serialization]
+ * context:
+ *   business_goal: Encode Parquet metadata exactly as readers expect it
+ *   domain: export
+ */
+
+// Compact protocol type ids
+const TYPE_I32 = 5;
+const TYPE_I64 = 6;
+const TYPE_BINARY = 8;
+const TYPE_LIST = 9;
+const TYPE_STRUCT = 12;
+
+/** A growable byte buffer. */
+export interface ByteWriter {
+  readonly length: number;
+  byte(value: number): void;
+  varint(value: number): void;
+  bytes(value: Uint8Array): void;
+  finish(): Buffer;
+}
+
+export function createByteWriter(): ByteWriter {
+  let buffer = Buffer.alloc(4096);
+  let length = 0;
+
+  function ensure(extra: number): void {
+    if (length + extra <= buffer.length) return;
+    const next = Buffer.alloc(Math.max(buffer.length * 2, length + extra));
+    buffer.copy(next, 0, 0, length);
+    buffer = next;
+  }
+
+  function byte(value: number): void {
+    ensure(1);
+    buffer[length++] = value;
+  }
+
+  function varint(value: number): void {
+    let rest = value;
+    while (rest >= 0x80) {
+      byte((rest % 0x80) | 0x80);
+      rest = Math.floor(rest / 0x80);
+    }
+    byte(rest);
+  }
+
+  function bytes(value: Uint8Array): void {
+    ensure(value.length);
+    buffer.set(value, length);
+    length += value.length;
+  }
+
+  return {
+    get length() {
+      return length;
+    },
+    byte,
+    varint,
+    bytes,
+    finish: () => buffer.subarray(0, length),
+  };
+}
+
+/** A struct field: its id, compact type, and how to write its value. */
+export interface ThriftField {
+  readonly id: number;
+  readonly type: number;
+  write(writer: ByteWriter): void;
+}
+
+/** A struct, with `undefined` standing for an unset optional field. */
+export type ThriftStruct = readonly (ThriftField | undefined)[];
+
+// Integers are zigzag varints; graph sizes stay well inside 2^53
+function zigzag(value: number): number {
+  return value >= 0 ? value * 2 : -value * 2 - 1;
+}
+
+export function i32(id: number, value: number): ThriftField {
+  return { id, type: TYPE_I32, write: (w) => w.varint(zigzag(value)) };
+}
+
+export function i64(id: number, value: number): ThriftField {
+  return { id, type: TYPE_I64, write: (w) => w.varint(zigzag(value)) };
+}
+
+export function binary(id: number, value: string): ThriftField {
+  const bytes = Buffer.from(value, 'utf-8');
+  return {
+    id,
+    type: TYPE_BINARY,
+    write: (w) => {
+      w.varint(bytes.length);
+      w.bytes(bytes);
+    },
+  };
+}
+
+export function struct(id: number, fields: ThriftStruct): ThriftField {
+  return { id, type: TYPE_STRUCT, write: (w) => writeStruct(w, fields) };
+}
+
+function list(
+  id: number,
+  type: number,
+  items: readonly ((w: ByteWriter) => void)[],
+): ThriftField {
+  return {
+    id,
+    type: TYPE_LIST,
+    write: (w) => {
+      if (items.length < 15) {
+        w.byte((items.length << 4) | type);
+      } else {
+        w.byte(0xf0 | type);
+        w.varint(items.length);
+      }
+      for (const item of items) item(w);
+    },
+  };
+}
+
+export function i32List(id: number, values: readonly number[]): ThriftField {
+  return list(
+    id,
+    TYPE_I32,
+    values.map((value) => (w) => w.varint(zigzag(value))),
+  );
+}
+
+export function binaryList(id: number, values: readonly string[]): ThriftField {
+  return list(
+    id,
+    TYPE_BINARY,
+    values.map((value) => (w) => binary(0, value).write(w)),
+  );
+}
+
+export function structList(
+  id: number,
+  values: readonly ThriftStruct[],
+): ThriftField {
+  return list(
+    id,
+    TYPE_STRUCT,
+    values.map((fields) => (w) => writeStruct(w, fields)),
+  );
+}
+
+/** Write `fields` in id order, each with a delta header, then a stop byte. */
+export function writeStruct(writer: ByteWriter, fields: ThriftStruct): void {
+  let last = 0;
+  for (const field of fields) {
+    if (!field) continue;
+    const delta = field.id - last;
+    if (delta > 0 && delta <= 15) {
+      writer.byte((delta << 4) | field.type);
+    } else {
+      writer.byte(field.type);
+      writer.varint(zigzag(field.id));
+    }
+    field.write(writer);
+    last = field.id;
+  }
+  writer.byte(0);
+}
@@ -0,0 +1,44 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the Parquet table writer and the node and edge tables exported from the graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [parquet, export, analytics, types, interface]
+ * context:
+ *   business_goal: Keep exported table columns stable so saved analytics queries keep working
+ *   domain: export
+ */
+
+/** Physical and logical type of a Parquet column's values. */
+export type ParquetValueType = 'string' | 'boolean' | 'int64' | 'double';
+
+export type ParquetValue = string | boolean | number;
+
+export interface ParquetColumn {
+  readonly name: string;
+  readonly type: ParquetValueType;
+  /** Whether values may be null; required columns reject nulls. */
+  readonly nullable?: boolean;
+  /**
+   * Whether each value is a list of `type`, written with the standard
+   * three-level LIST layout. A null list needs `nullable`.
+   */
+  readonly list?: boolean;
+  /** One value per row. */
+  readonly values: readonly (ParquetValue | readonly ParquetValue[] | null)[];
+}
+
+/** The graph table a Parquet export writes. */
+export type GraphParquetTable = 'nodes' | 'edges';
+
+export interface ParquetTable {
+  readonly columns: readonly ParquetColumn[];
+}
+
+export interface ParquetWriteOptions {
+  /** Gzip each data page (default: true). */
+  readonly compress?: boolean;
+  /** The `created_by` footer field (default: `knowgraph`). */
+  readonly createdBy?: string;
+}
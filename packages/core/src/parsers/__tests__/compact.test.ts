@@ -0,0 +1,75 @@
+import { describe, it, expect } from 'vitest';
+import {
+  findCompactLineComments,
+  parseCompactAnnotation,
+} from '../compact.js';
+
+describe('parseCompactAnnotation', () => {
+  it('reads key=value pairs as the fields take them', () => {
+    expect(
+      parseCompactAnnotation(
+        'type=function owner=auth-team tags=auth,http slo.availability=99.9 generated=true',
+      ),
+    ).toEqual({
+      type: 'function',
+      owner: 'auth-team',
+      tags: ['auth', 'http'],
+      slo: { availability: 99.9 },
+      generated: true,
+    });
+  });
+
+  it('keeps quoted values whole', () => {
+    expect(
+      parseCompactAnnotation(
+        'description="Hashes a password, slowly" tags="a,b" status="true"',
+      ),
+    ).toEqual({
+      description: 'Hashes a password, slowly',
+      tags: ['a,b'],
+      status: 'true',
+    });
+  });
+
+  it('nests dotted keys', () => {
+    expect(
+      parseCompactAnnotation('context.domain=auth context.team=identity'),
+    ).toEqual({ context: { domain: 'auth', team: 'identity' } });
+  });
+
+  it('rejects text that is not a pair and keys set twice', () => {
+    expect(() => parseCompactAnnotation('type=function stray')).toThrow(
+      'Expected key=value, got "stray"',
+    );
+    expect(() => parseCompactAnnotation('owner=a owner=b')).toThrow(
+      'owner is set twice',
+    );
+    expect(() =>
+      parseCompactAnnotation('context=x context.domain=auth'),
+    ).toThrow('context.domain is set twice');
+  });
+
+  it('returns no fields for empty text', () => {
+    expect(parseCompactAnnotation('   ')).toEqual({});
+  });
+});
+
+describe('findCompactLineComments', () => {
+  it('finds each compact line comment with its line', () => {
+    const content = [
+      'import x from "x";',
+      '',
+      '  // knowgraph: type=function owner=auth-team',
+      'export function login(): void {}',
+      '// a plain comment',
+    ].join('\n');
+    const [comment, ...rest] = findCompactLineComments(content);
+    expect(rest).toHaveLength(0);
+    expect(comment?.content).toBe('// knowgraph: type=function owner=auth-team');
+    expect(comment?.startLine).toBe(3);
+    expect(comment?.endLine).toBe(3);
+    expect(content.slice(comment?.endIndex)).toBe(
+      '\nexport function login(): void {}\n// a plain comment',
+    );
+  });
+});
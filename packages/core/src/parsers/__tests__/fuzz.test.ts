@@ -0,0 +1,211 @@
+import { describe, it, expect } from 'vitest';
+import { readdirSync, readFileSync } from 'node:fs';
+import { resolve, join } from 'node:path';
+import { createRandom } from '../../bench/synthetic-repo.js';
+import { normalizeLineEndings } from '../../paths/paths.js';
+import {
+  extractKnowgraphYaml,
+  parseAndValidateMetadata,
+} from '../metadata-extractor.js';
+import { createDefaultRegistry } from '../registry.js';
+
+// Annotations come from every contributor's comments, so no input may make
+// extraction or binding throw, hang, or return something the indexer
+// cannot store. Run longer with a fresh seed to explore:
+//   KNOWGRAPH_FUZZ_RUNS=20000 KNOWGRAPH_FUZZ_SEED=$RANDOM \
+//     pnpm --filter @know-graph/core vitest run src/parsers/__tests__/fuzz
+const CORPUS_DIR = resolve(__dirname, 'fixtures', 'fuzz-corpus');
+const RUNS = Number(process.env.KNOWGRAPH_FUZZ_RUNS ?? 300);
+const SEED = Number(process.env.KNOWGRAPH_FUZZ_SEED ?? 1);
+const EXTENSIONS = ['.ts', '.py', '.go', '.java', '.rb'];
+
+const TOKENS = [
+  '@knowgraph',
+  '/**',
+  '*/',
+  '/*',
+  '"""',
+  "'''",
+  '//',
+  '#',
+  '\n',
+  '\r',
+  '\r\n',
+  '\t',
+  ' * ',
+  ':',
+  ': ',
+  '- ',
+  '    ',
+  '&a ',
+  '*a',
+  '<<: *a',
+  '!!binary ',
+  '!tag ',
+  '{',
+  '}',
+  '[',
+  ']',
+  '|',
+  '>-',
+  '---',
+  '...',
+  '"',
+  "'",
+  '\\',
+  '\u0000',
+  '\uFEFF',
+  '\u202E',
+  '\uD800',
+  '\u{1F600}',
+  'type: module',
+  'type: function',
+  'description: fuzzed',
+  'owner: team',
+  'dependencies:\n  services: [a, b]',
+  'class A:',
+  'def f(self):',
+  ') -> int:',
+  '@decorator',
+  'export class A {',
+  'export function f(a: T): R {',
+  'export const f = () => {',
+  'func F() {',
+  'type T struct {',
+  'public class A {',
+  '@Override',
+];
+
+const corpus = readdirSync(CORPUS_DIR)
+  .sort()
+  .map((name) => ({
+    name,
+    content: readFileSync(join(CORPUS_DIR, name), 'utf-8'),
+  }));
+
+function mutate(input: string, random: () => number): string {
+  let text = input;
+  const steps = 1 + Math.floor(random() * 8);
+  for (let step = 0; step < steps; step++) {
+    const at = Math.floor(random() * (text.length + 1));
+    const span = 1 + Math.floor(random() * 64);
+    switch (Math.floor(random() * 5)) {
+      case 0: {
+        const token = TOKENS[Math.floor(random() * TOKENS.length)];
+        text = text.slice(0, at) + token + text.slice(at);
+        break;
+      }
+      case 1:
+        text = text.slice(0, at) + text.slice(at + span);
+        break;
+      case 2:
+        text =
+          text.slice(0, at) + text.slice(at, at + span) + text.slice(at);
+        break;
+      case 3: {
+        const start = text.lastIndexOf('\n', at - 1) + 1;
+        const end = text.indexOf('\n', at);
+        const line = text.slice(start, end === -1 ? text.length : end);
+        const copies = Math.floor(random() * 50);
+        text =
+          text.slice(0, start) +
+          `${line}\n`.repeat(copies) +
+          text.slice(start);
+        break;
+      }
+      default:
+        text =
+          text.slice(0, at) +
+          String.fromCharCode(Math.floor(random() * 0x10000)) +
+          text.slice(at + 1);
+    }
+  }
+  return text;
+}
+
+const registry = createDefaultRegistry();
+
+/** Check every invariant for `input`, naming `label` in failures. */
+function check(input: string, label: string): void {
+  const yaml = extractKnowgraphYaml(input);
+  expect(yaml === null, `${label}: marker detection`).toBe(
+    !input.includes('@knowgraph'),
+  );
+  if (yaml !== null) {
+    const extraction = parseAndValidateMetadata(yaml);
+    expect(
+      extraction.metadata === null,
+      `${label}: metadata or errors, not both`,
+    ).toBe(extraction.errors.length > 0);
+    expect(() => JSON.stringify(extraction.metadata), label).not.toThrow();
+  }
+
+  const lineCount = normalizeLineEndings(input).split('\n').length;
+  for (const ext of EXTENSIONS) {
+    const path = `fuzz/input${ext}`;
+    const output = registry.parseFile(input, path);
+    for (const result of output.results) {
+      expect(typeof result.name, `${label}${ext}: name`).toBe('string');
+      expect(
+        Number.isInteger(result.line) &&
+          result.line >= 1 &&
+          result.line <= lineCount,
+        `${label}${ext}: line ${result.line} of ${lineCount}`,
+      ).toBe(true);
+      // The indexer stores metadata as JSON
+      expect(() => JSON.stringify(result.metadata), label).not.toThrow();
+    }
+    for (const diagnostic of output.diagnostics) {
+      expect(
+        Number.isInteger(diagnostic.line) && diagnostic.line >= 0,
+        `${label}${ext}: diagnostic line ${diagnostic.line}`,
+      ).toBe(true);
+    }
+    expect(registry.parseFile(input, path), `${label}${ext}: stable`).toEqual(
+      output,
+    );
+  }
+}
+
+describe('annotation parser fuzzing', () => {
+  it('survives every corpus entry', () => {
+    expect(corpus.length).toBeGreaterThan(0);
+    for (const entry of corpus) check(entry.content, entry.name);
+  });
+
+  it('survives generated pathological inputs', () => {
+    const block = (body: string): string =>
+      `/**\n * @knowgraph\n * type: module\n${body} */\nexport class A {}\n`;
+    check(block(' * x\n'.repeat(200_000)), 'many lines in one block');
+    check(
+      block(` * description: ${'a'.repeat(1_000_000)}\n`),
+      'a megabyte description',
+    );
+    check(
+      block(` * description: ${'['.repeat(5000)}${']'.repeat(5000)}\n`),
+      'deeply nested flow sequences',
+    );
+    check(
+      block(
+        Array.from(
+          { length: 1000 },
+          (_, i) => ` * ${'  '.repeat(i)}k${i}:\n`,
+        ).join(''),
+      ),
+      'deeply nested mappings',
+    );
+    check(block('').repeat(1000), 'a thousand blocks');
+    check('@knowgraph'.repeat(100_000), 'repeated markers');
+    check('/**'.repeat(50_000), 'unclosed openers');
+    check('"""'.repeat(50_000), 'docstring quotes');
+  }, 120_000);
+
+  it(`survives ${RUNS} mutations of the corpus (seed ${SEED})`, () => {
+    const random = createRandom(SEED);
+    for (let run = 0; run < RUNS; run++) {
+      const entry = corpus[Math.floor(random() * corpus.length)];
+      const input = mutate(entry.content, random);
+      check(input, `seed ${SEED} run ${run} (${entry.name})`);
+    }
+  }, 300_000);
+});
@@ -158,6 +158,88 @@ type User struct {
       expect(results[0]?.metadata.description).toBe('Represents a user in the system');
       expect(results[0]?.metadata.owner).toBe('core-team');
     });
+
+    it('merges knowgraph struct tags into field metadata', () => {
+      const content = `package auth
+
+// @knowgraph
+// type: class
+// description: Registration payload
+// fields:
+//   Password:
+//     description: Hashed on arrival
+type RegisterRequest struct {
+	Email    string \`json:"email" knowgraph:"sensitivity=pii tags=contact,login"\`
+	Password string \`json:"password" knowgraph:"sensitivity=secret description=\\"Never logged\\""\`
+	First, Last string \`knowgraph:"sensitivity=pii"\`
+	Device struct {
+		IP string \`knowgraph:"sensitivity=pii"\`
+	}
+	*Audit \`knowgraph:"description=shared"\`
+	Plan string \`json:"plan"\`
+}
+`;
+      const { results, diagnostics } = parser.parse(content, 'register.go');
+      expect(diagnostics).toHaveLength(0);
+      expect(results[0]?.metadata).toMatchObject({
+        fields: {
+          Email: { sensitivity: 'pii', tags: ['contact', 'login'] },
+          Password: { sensitivity: 'secret', description: 'Hashed on arrival' },
+          First: { sensitivity: 'pii' },
+          Last: { sensitivity: 'pii' },
+          Audit: { description: 'shared' },
+        },
+      });
+      expect(results[0]?.metadata).not.toHaveProperty('fields.IP');
+      expect(results[0]?.metadata).not.toHaveProperty('fields.Plan');
+    });
+
+    it('reads //knowgraph: directives after the doc comment prose', () => {
+      const content = `package billing
+
+// Charge charges a card.
+//
+//knowgraph:type=function description="Charges a card"
+//knowgraph:owner=payments-team tags=billing,cards
+func Charge(amount int) error {
+}
+`;
+      const { results, diagnostics } = parser.parse(content, 'charge.go');
+      expect(diagnostics).toHaveLength(0);
+      expect(results[0]?.name).toBe('Charge');
+      expect(results[0]?.metadata).toMatchObject({
+        description: 'Charges a card',
+        owner: 'payments-team',
+        tags: ['billing', 'cards'],
+      });
+    });
+
+    it('reports struct tags that do not parse or name unknown keys', () => {
+      const content = `package auth
+
+// @knowgraph
+// type: class
+// description: Registration payload
+type RegisterRequest struct {
+	Email string \`knowgraph:"sensitivty=pii"\`
+	Name  string \`knowgraph:"sensitivity"\`
+}
+`;
+      const { results, diagnostics } = parser.parse(content, 'register.go');
+      expect(results).toHaveLength(1);
+      expect(diagnostics).toEqual([
+        {
+          filePath: 'register.go',
+          line: 7,
+          message: expect.stringContaining("knowgraph struct tag of Email: Unrecognized key(s) in object: 'sensitivty'"),
+        },
+        {
+          filePath: 'register.go',
+          line: 8,
+          message: 'knowgraph struct tag of Name: Expected key=value, got "sensitivity"',
+        },
+      ]);
+    });
   });
 
   describe('interface declarations', () => {
@@ -0,0 +1,25 @@
+import { describe, it, expect } from 'vitest';
+import { createLineIndex } from '../line-index.js';
+
+describe('createLineIndex', () => {
+  it('maps offsets to 1-based line numbers', () => {
+    const lineAt = createLineIndex('ab\ncd\n\nef');
+    expect(lineAt(0)).toBe(1);
+    expect(lineAt(2)).toBe(1);
+    expect(lineAt(3)).toBe(2);
+    expect(lineAt(6)).toBe(3);
+    expect(lineAt(7)).toBe(4);
+    expect(lineAt(9)).toBe(4);
+  });
+
+  it('clamps offsets outside the source', () => {
+    const lineAt = createLineIndex('a\nb');
+    expect(lineAt(-1)).toBe(1);
+    expect(lineAt(100)).toBe(2);
+  });
+
+  it('treats a source without newlines as one line', () => {
+    expect(createLineIndex('')(0)).toBe(1);
+    expect(createLineIndex('/**'.repeat(10))(29)).toBe(1);
+  });
+});
@@ -1,5 +1,6 @@
 import { describe, it, expect } from 'vitest';
 import {
+  extractKnowgraphBlock,
   extractKnowgraphYaml,
   parseAndValidateMetadata,
   extractMetadata,
@@ -172,3 +173,121 @@ description: Something
     expect(result.errors.length).toBeGreaterThan(0);
   });
 });
+
+describe('JSON and TOML blocks', () => {
+  it('reads the format from the marker', () => {
+    expect(extractKnowgraphBlock('@knowgraph\ntype: module')).toEqual({
+      format: 'yaml',
+      content: 'type: module',
+    });
+    expect(extractKnowgraphBlock(' * @knowgraph-json:\n * {}')).toEqual({
+      format: 'json',
+      content: '{}',
+    });
+    expect(extractKnowgraphBlock('# @knowgraph-toml\n# a = 1')).toEqual({
+      format: 'toml',
+      content: 'a = 1',
+    });
+  });
+
+  it('normalizes JSON into the same metadata as YAML', () => {
+    const block = `
+ * @knowgraph-json:
+ * {
+ *   "type": "service",
+ *   "description": "Charges cards",
+ *   "owner": "payments-team",
+ *   "tags": ["billing"],
+ *   "context": { "domain": "payments" }
+ * }
+    `;
+    const yaml = `
+ * @knowgraph
+ * type: service
+ * description: Charges cards
+ * owner: payments-team
+ * tags: [billing]
+ * context:
+ *   domain: payments
+    `;
+    const result = extractMetadata(block);
+    expect(result.errors).toEqual([]);
+    expect(result.metadata).toEqual(extractMetadata(yaml).metadata);
+  });
+
+  it('normalizes TOML into the same metadata as YAML', () => {
+    const block = `
+# @knowgraph-toml:
+type = "service"
+description = "Charges cards"
+owner = "payments-team"
+tags = ["billing"]
+
+[context]
+domain = "payments"
+    `;
+    const result = extractMetadata(block);
+    expect(result.errors).toEqual([]);
+    expect(result.metadata).toMatchObject({
+      type: 'service',
+      owner: 'payments-team',
+      tags: ['billing'],
+      context: { domain: 'payments' },
+    });
+  });
+
+  it('names the format in parse errors', () => {
+    const json = extractMetadata('@knowgraph-json\n{ "type": ', 7);
+    expect(json.errors[0]?.message).toMatch(/^JSON parse error/);
+    expect(json.errors[0]?.line).toBe(7);
+    const toml = extractMetadata('@knowgraph-toml\ntype = ');
+    expect(toml.errors[0]?.message).toMatch(/^TOML parse error/);
+    const list = extractMetadata('@knowgraph-json\n[1]');
+    expect(list.errors).toEqual([
+      expect.objectContaining({ message: expect.stringContaining('Validation') }),
+    ]);
+  });
+});
+
+describe('compact annotations', () => {
+  it('reads a knowgraph: line after any comment token', () => {
+    expect(
+      extractKnowgraphBlock('// knowgraph: type=function owner=auth-team'),
+    ).toEqual({ format: 'compact', content: 'type=function owner=auth-team' });
+    expect(extractKnowgraphBlock('# knowgraph: type=module')).toEqual({
+      format: 'compact',
+      content: 'type=module',
+    });
+  });
+
+  it('joins the pairs of several knowgraph: lines', () => {
+    const block = 'Charge charges a card.\n\nknowgraph:type=function\nknowgraph:owner=payments';
+    expect(extractKnowgraphBlock(block)).toEqual({
+      format: 'compact',
+      content: 'type=function owner=payments',
+    });
+  });
+
+  it('normalizes into the same metadata as YAML', () => {
+    const compact = extractMetadata(
+      '// knowgraph: type=function description="Checks a token" owner=auth-team tags=auth,http',
+    );
+    const yaml = extractMetadata(
+      '@knowgraph\ntype: function\ndescription: Checks a token\nowner: auth-team\ntags: [auth, http]',
+    );
+    expect(compact.errors).toEqual([]);
+    expect(compact.metadata).toEqual(yaml.metadata);
+  });
+
+  it('reports pairs that do not parse and fields that do not validate', () => {
+    const bad = extractMetadata('// knowgraph: type=function oops', 4);
+    expect(bad.errors).toEqual([
+      {
+        message: 'Compact annotation parse error: Expected key=value, got "oops"',
+        line: 4,
+      },
+    ]);
+    const invalid = extractMetadata('// knowgraph: type=gadget description=x');
+    expect(invalid.errors[0]?.message).toMatch(/^Validation error at type/);
+  });
+});
@@ -364,6 +364,29 @@ def stable_func():
       expect(results).toHaveLength(1);
       expect(results[0]?.metadata.status).toBe('stable');
     });
+
+    it('reads TOML annotation blocks', () => {
+      const content = `
+def charge():
+    """
+    @knowgraph-toml:
+    type = "function"
+    description = "Charges a card"
+    tags = ["billing"]
+
+    [context]
+    domain = "payments"
+    """
+    pass
+`;
+      const { results, diagnostics } = parser.parse(content, 'charge.py');
+      expect(diagnostics).toHaveLength(0);
+      expect(results[0]?.metadata).toMatchObject({
+        type: 'function',
+        tags: ['billing'],
+        context: { domain: 'payments' },
+      });
+    });
   });
 
   describe('file path handling', () => {
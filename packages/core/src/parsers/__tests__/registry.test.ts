@@ -109,6 +109,22 @@ public class App {}
       expect(results[0]?.entityType).toBe('class');
     });
 
+    it('parses CRLF files as it parses LF ones', () => {
+      const files = {
+        'app.ts': '/**\n * @knowgraph\n * type: function\n * description: Run\n */\nexport function run(): void {}\n',
+        'app.py': 'def run():\n    """\n    @knowgraph\n    type: function\n    description: Run\n    """\n',
+        'main.go': '/*\n@knowgraph\ntype: function\ndescription: Run\n*/\nfunc Run() {}\n',
+        'App.java': '/**\n * @knowgraph\n * type: class\n * description: Run\n */\npublic class App {}\n',
+        'run.rb': '# @knowgraph\n# type: module\n# description: Run\n',
+      };
+      for (const [file, content] of Object.entries(files)) {
+        const lf = registry.parseFile(content, file);
+        expect(lf.results).toHaveLength(1);
+        const crlf = '\uFEFF' + content.replace(/\n/g, '\r\n');
+        expect(registry.parseFile(crlf, file)).toEqual(lf);
+      }
+    });
+
     it('returns empty array for files without knowgraph annotations', () => {
       const content = 'const x = 1;';
       const { results } = registry.parseFile(content, 'plain.ts');
@@ -0,0 +1,109 @@
+import { describe, it, expect } from 'vitest';
+import { parseToml } from '../toml.js';
+
+describe('parseToml', () => {
+  it('parses keys, strings, numbers, and booleans', () => {
+    const text = [
+      '# a comment',
+      'name = "orders"  # trailing comment',
+      "path = 'C:\\Users\\svc'",
+      'quoted = "tab\\there \\u00e9"',
+      '"dotted.key" = 1',
+      'site.owner = "web"',
+      'count = 1_000',
+      'hex = 0xff',
+      'ratio = 0.5',
+      'big = 1e3',
+      'neg = -inf',
+      'enabled = true',
+      'since = 2024-01-31',
+      'at = 1979-05-27 07:32:00Z',
+    ].join('\n');
+    expect(parseToml(text)).toEqual({
+      name: 'orders',
+      path: 'C:\\Users\\svc',
+      quoted: 'tab\there é',
+      'dotted.key': 1,
+      site: { owner: 'web' },
+      count: 1000,
+      hex: 255,
+      ratio: 0.5,
+      big: 1000,
+      neg: -Infinity,
+      enabled: true,
+      since: '2024-01-31',
+      at: '1979-05-27 07:32:00Z',
+    });
+  });
+
+  it('parses multi-line strings and arrays', () => {
+    const text = [
+      'description = """',
+      'Charges cards \\',
+      '    and refunds them."""',
+      "raw = '''",
+      'line one',
+      "line two'''",
+      'tags = [',
+      '  "billing",  # why',
+      '  "payments",',
+      ']',
+      'nested = [[1, 2], ["a"]]',
+    ].join('\n');
+    expect(parseToml(text)).toEqual({
+      description: 'Charges cards and refunds them.',
+      raw: 'line one\nline two',
+      tags: ['billing', 'payments'],
+      nested: [[1, 2], ['a']],
+    });
+  });
+
+  it('parses tables, arrays of tables, and inline tables', () => {
+    const text = [
+      'type = "service"',
+      '[context]',
+      'domain = "payments"',
+      'owner = { team = "pay", slack = "#pay" }',
+      '[[dependencies.services]]',
+      'name = "ledger"',
+      '[[dependencies.services]]',
+      'name = "fraud"',
+      '[dependencies.services.sla]',
+      'p99 = 200',
+    ].join('\n');
+    expect(parseToml(text)).toEqual({
+      type: 'service',
+      context: {
+        domain: 'payments',
+        owner: { team: 'pay', slack: '#pay' },
+      },
+      dependencies: {
+        services: [{ name: 'ledger' }, { name: 'fraud', sla: { p99: 200 } }],
+      },
+    });
+  });
+
+  it('rejects redefined keys and tables', () => {
+    expect(() => parseToml('a = 1\na = 2')).toThrow(
+      'Key a is already defined at line 2',
+    );
+    expect(() => parseToml('[t]\n[t]')).toThrow('Table t is already defined');
+    expect(() => parseToml('t = { a = 1 }\n[t]')).toThrow();
+    expect(() => parseToml('t = { a = 1 }\nt.b = 2')).toThrow();
+  });
+
+  it('reports the line of a syntax error', () => {
+    expect(() => parseToml('a = 1\nb = "open\n')).toThrow(
+      'Unterminated string at line 2',
+    );
+    expect(() => parseToml('a = 1\n\nb')).toThrow('Expected = after key');
+    expect(() => parseToml('a = nope')).toThrow('Invalid value nope');
+    expect(() => parseToml('a = 1 2')).toThrow('Expected a new line');
+  });
+
+  it('treats __proto__ as a plain key', () => {
+    const parsed = parseToml('__proto__ = { polluted = true }');
+    expect(Object.keys(parsed)).toEqual(['__proto__']);
+    expect(({} as Record<string, unknown>).polluted).toBeUndefined();
+  });
+});
@@ -0,0 +1,184 @@
+import { describe, it, expect } from 'vitest';
+import { tmpdir } from 'node:os';
+import {
+  createTreeSitterParser,
+  loadTreeSitterGrammar,
+} from '../tree-sitter-parser.js';
+import type { TreeSitterNode } from '../tree-sitter-parser.js';
+
+const SOURCE = [
+  '# @knowgraph',
+  '# type: module',
+  '# description: Billing helpers',
+  '',
+  '# @knowgraph',
+  '# type: class',
+  '# description: Charges cards in €',
+  'class Payments',
+  '  # @knowgraph',
+  '  # type: method',
+  '  # description: Charges one card',
+  '  def charge(amount)',
+  '    amount',
+  '  end',
+  'end',
+  '',
+].join('\n');
+
+interface Spec {
+  readonly type: string;
+  readonly text: string;
+  readonly children?: readonly TreeSitterNode[];
+  readonly fields?: Readonly<Record<string, TreeSitterNode>>;
+}
+
+function pointAt(index: number): { row: number; column: number } {
+  const before = SOURCE.slice(0, index).split('\n');
+  return { row: before.length - 1, column: before[before.length - 1].length };
+}
+
+// A syntax node over the first occurrence of `text` after `from`, as
+// tree-sitter-ruby would produce it
+function node(spec: Spec, from = 0): TreeSitterNode {
+  const startIndex = SOURCE.indexOf(spec.text, from);
+  const endIndex = startIndex + spec.text.length;
+  const children = spec.children ?? [];
+  const built: TreeSitterNode = {
+    type: spec.type,
+    startIndex,
+    endIndex,
+    startPosition: pointAt(startIndex),
+    endPosition: pointAt(endIndex),
+    parent: null,
+    namedChildren: children,
+    childForFieldName: (name) => spec.fields?.[name] ?? null,
+  };
+  for (const child of children) {
+    Object.assign(child, { parent: built });
+  }
+  return built;
+}
+
+function comments(lines: readonly string[], from = 0): TreeSitterNode[] {
+  return lines.map((text) => node({ type: 'comment', text }, from));
+}
+
+function rubyTree(): TreeSitterNode {
+  const methodName = node({ type: 'identifier', text: 'charge' });
+  const methodBody = node({ type: 'body_statement', text: 'amount\n' }, 150);
+  const method = node({
+    type: 'method',
+    text: 'def charge(amount)\n    amount\n  end',
+    children: [methodName, methodBody],
+    fields: { name: methodName, body: methodBody },
+  });
+  const className = node({ type: 'constant', text: 'Payments' });
+  const classBody = node({
+    type: 'body_statement',
+    text: SOURCE.slice(SOURCE.indexOf('# @knowgraph', 100), -6),
+    children: [
+      ...comments(
+        [
+          '# @knowgraph',
+          '# type: method',
+          '# description: Charges one card',
+        ],
+        100,
+      ),
+      method,
+    ],
+  });
+  const klass = node({
+    type: 'class',
+    text: SOURCE.slice(SOURCE.indexOf('class Payments'), -1),
+    children: [className, classBody],
+    fields: { name: className, body: classBody },
+  });
+  return node({
+    type: 'program',
+    text: SOURCE,
+    children: [
+      ...comments(SOURCE.split('\n').slice(0, 3)),
+      ...comments(SOURCE.split('\n').slice(4, 7), 40),
+      klass,
+    ],
+  });
+}
+
+describe('createTreeSitterParser', () => {
+  const parser = createTreeSitterParser({
+    language: 'ruby',
+    extensions: ['.rb'],
+    parse: () => ({ rootNode: rubyTree() }),
+  });
+
+  it('binds annotations to the definition after or around them', () => {
+    const { results, diagnostics } = parser.parse(SOURCE, 'lib/billing.rb');
+    expect(diagnostics).toEqual([]);
+    expect(
+      results.map(({ name, line, column, parent, signature }) => ({
+        name,
+        line,
+        column,
+        parent,
+        signature,
+      })),
+    ).toEqual([
+      { name: 'billing', line: 1, column: 1 },
+      { name: 'Payments', line: 8, column: 1 },
+      {
+        name: 'charge',
+        line: 12,
+        column: 3,
+        parent: 'Payments',
+        signature: 'def charge(amount)',
+      },
+    ]);
+    expect(results[1].entityType).toBe('class');
+    expect(results[1].language).toBe('ruby');
+    expect(parser.name).toBe('tree-sitter:ruby');
+  });
+
+  it('gives ranges in UTF-8 bytes', () => {
+    const { results } = parser.parse(SOURCE, 'lib/billing.rb');
+    const start = SOURCE.indexOf('def charge');
+    // The euro sign is one UTF-16 code unit and three bytes
+    expect(results[2].range).toEqual({
+      startByte: start + 2,
+      endByte: start + 2 + 'def charge(amount)\n    amount\n  end'.length,
+      startLine: 12,
+      endLine: 14,
+    });
+    expect(results[0].range?.endByte).toBe(Buffer.byteLength(SOURCE));
+  });
+
+  it('reports annotations that fail validation', () => {
+    const broken = createTreeSitterParser({
+      language: 'ruby',
+      extensions: ['.rb'],
+      parse: () => ({
+        rootNode: node({
+          type: 'program',
+          text: SOURCE,
+          children: comments(['# @knowgraph', '# type: module']),
+        }),
+      }),
+    });
+    const { results, diagnostics } = broken.parse(SOURCE, 'lib/billing.rb');
+    expect(results).toEqual([]);
+    expect(diagnostics[0]).toMatchObject({ filePath: 'lib/billing.rb' });
+  });
+});
+
+describe('loadTreeSitterGrammar', () => {
+  it('names the packages to install when they are missing', () => {
+    expect(() =>
+      loadTreeSitterGrammar(
+        { language: 'ruby', module: 'tree-sitter-ruby', extensions: ['.rb'] },
+        tmpdir(),
+      ),
+    ).toThrow(
+      'The ruby tree-sitter grammar needs tree-sitter: run npm install tree-sitter tree-sitter-ruby',
+    );
+  });
+});
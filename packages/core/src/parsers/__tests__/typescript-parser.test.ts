@@ -87,6 +87,37 @@ function logEvent(event: Event) {
       expect(results[0]?.name).toBe('logEvent');
       expect(results[0]?.signature).toBe('function logEvent(event: Event)');
     });
+
+    it('parses JSON annotation blocks', () => {
+      const content = `
+/**
+ * @knowgraph-json:
+ * {
+ *   "type": "function",
+ *   "description": "Logs an event",
+ *   "owner": "observability"
+ * }
+ */
+function logEvent(event: Event) {
+}
+`;
+      const { results, diagnostics } = parser.parse(content, 'logger.ts');
+      expect(diagnostics).toHaveLength(0);
+      expect(results[0]?.name).toBe('logEvent');
+      expect(results[0]?.metadata.owner).toBe('observability');
+    });
+
+    it('parses compact line annotations', () => {
+      const content = `
+// knowgraph: type=function description="Logs an event" tags=logging,audit
+function logEvent(event: Event) {
+}
+`;
+      const { results, diagnostics } = parser.parse(content, 'logger.ts');
+      expect(diagnostics).toHaveLength(0);
+      expect(results[0]?.name).toBe('logEvent');
+      expect(results[0]?.metadata.tags).toEqual(['logging', 'audit']);
+    });
   });
 
   describe('interface JSDoc', () => {
@@ -0,0 +1,154 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses one-line `knowgraph: type=function owner=team tags=a,b` annotations into the same fields as a YAML block
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [parser, annotations, compact]
+ * context:
+ *   business_goal: Let small utilities carry an annotation without a ten-line YAML block
+ *   domain: parser-engine
+ */
+import { z } from 'zod';
+import type { ZodTypeAny } from 'zod';
+import { ExtendedMetadataSchema } from '../types/entity.js';
+import { createLineIndex } from './line-index.js';
+
+const COMPACT_LINE_COMMENT = /^[ \t]*\/\/[ \t]*knowgraph:.*$/gm;
+
+// A key=value pair; the value is a double-quoted string or runs to the
+// next whitespace
+const PAIR = /([\w.-]+)=("(?:[^"\\]|\\.)*"|\S*)/y;
+
+function unwrap(schema: ZodTypeAny): ZodTypeAny {
+  for (;;) {
+    if (schema instanceof z.ZodOptional || schema instanceof z.ZodNullable) {
+      schema = schema.unwrap();
+    } else if (schema instanceof z.ZodDefault) {
+      schema = schema.removeDefault();
+    } else if (schema instanceof z.ZodEffects) {
+      schema = schema.innerType();
+    } else {
+      return schema;
+    }
+  }
+}
+
+/** The schema of the field at `path` under `root`, if there is one. */
+function fieldSchema(
+  root: ZodTypeAny,
+  path: readonly string[],
+): ZodTypeAny | undefined {
+  let schema = root;
+  for (const key of path) {
+    const type = unwrap(schema);
+    if (!(type instanceof z.ZodObject)) return undefined;
+    const shape = type.shape as Record<string, ZodTypeAny>;
+    if (!Object.hasOwn(shape, key)) return undefined;
+    schema = shape[key];
+  }
+  return unwrap(schema);
+}
+
+/**
+ * A value as its field takes it: lists split on commas, and booleans and
+ * numbers converted. Quoted values and fields the schema does not know
+ * stay strings, apart from list fields, where a quoted value is one item.
+ */
+function coerce(
+  root: ZodTypeAny,
+  path: readonly string[],
+  raw: string,
+): unknown {
+  const quoted = raw.startsWith('"');
+  const text = quoted ? (JSON.parse(raw) as string) : raw;
+  const schema = fieldSchema(root, path);
+  if (schema instanceof z.ZodArray) {
+    if (quoted) return [text];
+    return text.split(',').filter((item) => item !== '');
+  }
+  if (quoted) return text;
+  if (schema instanceof z.ZodBoolean && (text === 'true' || text === 'false')) {
+    return text === 'true';
+  }
+  if (schema instanceof z.ZodNumber && text !== '' && !isNaN(Number(text))) {
+    return Number(text);
+  }
+  return text;
+}
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+/**
+ * Parse the `key=value` pairs of a compact annotation, the text after its
+ * `knowgraph:` marker. Dotted keys such as `context.domain=payments` set
+ * nested fields. Values are typed by `schema`, the annotation schema unless
+ * the pairs describe something else. Throws on text that is not a pair or
+ * a key given twice.
+ */
+export function parseCompactAnnotation(
+  text: string,
+  schema: ZodTypeAny = ExtendedMetadataSchema,
+): Record<string, unknown> {
+  const fields: Record<string, unknown> = {};
+  let pos = 0;
+  for (;;) {
+    while (pos < text.length && /\s/.test(text[pos])) pos++;
+    if (pos >= text.length) return fields;
+    PAIR.lastIndex = pos;
+    const match = PAIR.exec(text);
+    const end = match ? pos + match[0].length : pos;
+    if (!match || (end < text.length && !/\s/.test(text[end]))) {
+      const token = text.slice(pos).split(/\s/)[0];
+      throw new Error(`Expected key=value, got "${token}"`);
+    }
+    pos = end;
+
+    const [, key, raw] = match;
+    const path = key.split('.');
+    let target = fields;
+    for (const part of path.slice(0, -1)) {
+      if (!Object.hasOwn(target, part)) target[part] = {};
+      const next = target[part];
+      if (!isRecord(next)) throw new Error(`${key} is set twice`);
+      target = next;
+    }
+    const last = path[path.length - 1];
+    if (Object.hasOwn(target, last)) throw new Error(`${key} is set twice`);
+    target[last] = coerce(schema, path, raw);
+  }
+}
+
+export interface CompactComment {
+  /** The comment line, trimmed. */
+  readonly content: string;
+  readonly startLine: number;
+  readonly endLine: number;
+  /** Offset just past the end of the line. */
+  readonly endIndex: number;
+}
+
+/**
+ * Every `// knowgraph:` line comment in `content`, for parsers that
+ * otherwise only read doc comments.
+ */
+export function findCompactLineComments(
+  content: string,
+): readonly CompactComment[] {
+  const lineAt = createLineIndex(content);
+  const regex = new RegExp(COMPACT_LINE_COMMENT.source, 'gm');
+  const results: CompactComment[] = [];
+  let match: RegExpExecArray | null;
+  while ((match = regex.exec(content)) !== null) {
+    const line = lineAt(match.index);
+    results.push({
+      content: match[0].trim(),
+      startLine: line,
+      endLine: line,
+      endIndex: match.index + match[0].length,
+    });
+  }
+  return results;
+}
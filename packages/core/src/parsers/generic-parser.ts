@@ -37,7 +37,8 @@ function getLineNumber(source: string, charIndex: number): number {
   return line;
 }
 
-function stripBlockComment(raw: string): string {
+/** The text of a block comment or docstring without its delimiters. */
+export function stripBlockComment(raw: string): string {
   // Handle /* ... */
   if (raw.startsWith('/*') && raw.endsWith('*/')) {
     const inner = raw.slice(2, -2);
@@ -82,7 +83,8 @@ function getModuleName(filePath: string): string {
   return dotIndex > 0 ? fileName.slice(0, dotIndex) : fileName;
 }
 
-function getLanguageFromPath(filePath: string): string {
+/** The language a file is written in, by its extension. */
+export function getLanguageFromPath(filePath: string): string {
   const ext = filePath.slice(filePath.lastIndexOf('.'));
   const languageMap: Record<string, string> = {
     '.py': 'python',
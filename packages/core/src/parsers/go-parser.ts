@@ -1,7 +1,7 @@
 /**
  * @knowgraph
  * type: module
- * description: Go language parser that extracts @knowgraph annotations from line and block comments
+ * description: Go language parser that extracts @knowgraph annotations from line and block comments, and field metadata from knowgraph struct tags
  * owner: knowgraph-core
  * status: experimental
  * tags: [parser, go, comments]
@@ -14,7 +14,15 @@ import type {
   ParseDiagnostic,
   ParseOutput,
 } from '../types/parse-result.js';
+import { FieldMetadataSchema } from '../types/entity.js';
+import type {
+  CoreMetadata,
+  ExtendedMetadata,
+  FieldMetadata,
+} from '../types/entity.js';
 import type { Parser } from './types.js';
+import { parseCompactAnnotation } from './compact.js';
+import { createLineIndex } from './line-index.js';
 import { extractMetadata, extractKnowgraphYaml } from './metadata-extractor.js';
 
 const GO_EXTENSIONS = ['.go'] as const;
@@ -37,6 +45,23 @@ const GO_FUNC_REGEX =
  */
 const GO_STRUCT_REGEX = /^type\s+(\w+)\s+struct\s*\{/;
 
+/**
+ * Regex to match a named Go struct field with a tag.
+ * Groups: (1) field names, comma-separated, (2) tag
+ */
+const GO_FIELD_REGEX = /^(\w+(?:\s*,\s*\w+)*)\s+[^`\s][^`]*`([^`]*)`/;
+
+/**
+ * Regex to match an embedded Go struct field with a tag.
+ * Groups: (1) embedded type name, (2) tag
+ */
+const GO_EMBEDDED_FIELD_REGEX = /^\*?(?:\w+\.)?(\w+)\s*`([^`]*)`/;
+
+/**
+ * Regex to match the `knowgraph:"..."` key of a struct tag.
+ */
+const GO_KNOWGRAPH_TAG_REGEX = /(?:^|\s)knowgraph:"((?:[^"\\]|\\.)*)"/;
+
 /**
  * Regex to match Go interface type declarations.
  */
@@ -107,13 +132,14 @@ function stripLineCommentPrefix(line: string): string {
  */
 function findBlockComments(content: string): readonly CommentMatch[] {
   const results: CommentMatch[] = [];
+  const lineAt = createLineIndex(content);
   let match: RegExpExecArray | null;
   const regex = new RegExp(BLOCK_COMMENT_REGEX.source, 'g');
 
   while ((match = regex.exec(content)) !== null) {
-    const startLine = getLineNumber(content, match.index);
+    const startLine = lineAt(match.index);
     const endIndex = match.index + match[0].length;
-    const endLine = getLineNumber(content, endIndex - 1);
+    const endLine = lineAt(endIndex - 1);
     results.push({
       content: stripBlockComment(match[0]),
       startLine,
@@ -306,6 +332,102 @@ function getEndIndexOfNextNonEmptyLine(
   return offset;
 }
 
+interface StructFieldTags {
+  readonly fields: Readonly<Record<string, FieldMetadata>>;
+  readonly diagnostics: readonly ParseDiagnostic[];
+}
+
+/**
+ * Braces on a line of Go code, outside struct tags and line comments.
+ */
+function braceDepthChange(line: string): number {
+  const code = line.replace(/`[^`]*`/g, '').replace(/\/\/.*$/, '');
+  let change = 0;
+  for (const ch of code) {
+    if (ch === '{') change++;
+    else if (ch === '}') change--;
+  }
+  return change;
+}
+
+/**
+ * Read `knowgraph:"key=value ..."` struct tags on the fields of the struct
+ * declared on `structLine` (1-based) into field metadata keyed by field
+ * name. The tag holds compact annotation pairs, such as
+ * `knowgraph:"sensitivity=pii tags=auth,login"`.
+ */
+function readStructFieldTags(
+  content: string,
+  structLine: number,
+  filePath: string,
+): StructFieldTags {
+  const lines = content.split('\n');
+  const fields: Record<string, FieldMetadata> = {};
+  const diagnostics: ParseDiagnostic[] = [];
+  let depth = braceDepthChange(lines[structLine - 1] ?? '');
+
+  for (let i = structLine; i < lines.length && depth > 0; i++) {
+    const line = lines[i] ?? '';
+    const trimmed = line.trim();
+    // Fields of nested anonymous structs belong to those structs
+    const fieldMatch =
+      depth === 1
+        ? (trimmed.match(GO_FIELD_REGEX) ??
+          trimmed.match(GO_EMBEDDED_FIELD_REGEX))
+        : null;
+    depth += braceDepthChange(line);
+    if (!fieldMatch) continue;
+
+    const tagMatch = (fieldMatch[2] ?? '').match(GO_KNOWGRAPH_TAG_REGEX);
+    if (!tagMatch) continue;
+    const names = (fieldMatch[1] ?? '').split(',').map((name) => name.trim());
+    const text = (tagMatch[1] ?? '').replace(/\\(.)/g, '$1');
+    const report = (message: string): void => {
+      diagnostics.push({
+        filePath,
+        line: i + 1,
+        message: `knowgraph struct tag of ${names.join(', ')}: ${message}`,
+      });
+    };
+
+    let pairs: Record<string, unknown>;
+    try {
+      pairs = parseCompactAnnotation(text, FieldMetadataSchema);
+    } catch (error) {
+      report(error instanceof Error ? error.message : String(error));
+      continue;
+    }
+    const parsed = FieldMetadataSchema.strict().safeParse(pairs);
+    if (!parsed.success) {
+      for (const issue of parsed.error.issues) {
+        const path = issue.path.join('.');
+        report(path ? `${path}: ${issue.message}` : issue.message);
+      }
+      continue;
+    }
+    for (const name of names) fields[name] = parsed.data;
+  }
+
+  return { fields, diagnostics };
+}
+
+/**
+ * Merge struct tag field metadata into a struct's annotation. Where both
+ * describe a field, the annotation's `fields` entry wins key by key.
+ */
+function withFieldTags(
+  metadata: CoreMetadata | ExtendedMetadata,
+  tagged: Readonly<Record<string, FieldMetadata>>,
+): CoreMetadata | ExtendedMetadata {
+  if (Object.keys(tagged).length === 0) return metadata;
+  const annotated = 'fields' in metadata ? (metadata.fields ?? {}) : {};
+  const fields: Record<string, FieldMetadata> = { ...annotated };
+  for (const [name, field] of Object.entries(tagged)) {
+    fields[name] = { ...field, ...annotated[name] };
+  }
+  return { ...metadata, fields };
+}
+
 /**
  * Build a function/method signature string from regex match groups.
  */
@@ -407,6 +529,12 @@ export function createGoParser(): Parser {
         // Try to match struct declaration
         const structMatch = nextLine.match(GO_STRUCT_REGEX);
         if (structMatch) {
+          const tagged = readStructFieldTags(
+            content,
+            nextLineNumber,
+            filePath,
+          );
+          diagnostics.push(...tagged.diagnostics);
           results.push({
             name: structMatch[1]!,
             filePath,
@@ -414,7 +542,7 @@ export function createGoParser(): Parser {
             column: 1,
             language: 'go',
             entityType: metadata.type,
-            metadata,
+            metadata: withFieldTags(metadata, tagged.fields),
             rawDocstring: comment.content,
           });
           continue;
@@ -1,11 +1,15 @@
 export type { Parser, ParserRegistry } from './types.js';
 export type { ParseOutput, ParseDiagnostic } from '../types/parse-result.js';
 export {
+  extractKnowgraphBlock,
   extractKnowgraphYaml,
   parseAndValidateMetadata,
+  validateMetadata,
   extractMetadata,
 } from './metadata-extractor.js';
 export type {
+  AnnotationFormat,
+  KnowgraphBlock,
   ExtractionError,
   ExtractionResult,
 } from './metadata-extractor.js';
@@ -14,4 +18,15 @@ export { createTypescriptParser } from './typescript-parser.js';
 export { createGenericParser } from './generic-parser.js';
 export { createGoParser } from './go-parser.js';
 export { createJavaParser } from './java-parser.js';
+export {
+  createTreeSitterParser,
+  loadTreeSitterGrammar,
+} from './tree-sitter-parser.js';
+export type {
+  TreeSitterGrammar,
+  TreeSitterGrammarConfig,
+  TreeSitterNode,
+  TreeSitterPoint,
+  TreeSitterTree,
+} from './tree-sitter-parser.js';
 export { createDefaultRegistry } from './registry.js';
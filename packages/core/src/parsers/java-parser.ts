@@ -15,6 +15,8 @@ import type {
   ParseOutput,
 } from '../types/parse-result.js';
 import type { Parser } from './types.js';
+import { findCompactLineComments } from './compact.js';
+import { createLineIndex } from './line-index.js';
 import { extractMetadata, extractKnowgraphYaml } from './metadata-extractor.js';
 
 const JAVA_EXTENSIONS = ['.java'] as const;
@@ -85,13 +87,14 @@ function stripJavadoc(raw: string): string {
 
 function findAllJavadocBlocks(content: string): readonly JavadocMatch[] {
   const results: JavadocMatch[] = [];
+  const lineAt = createLineIndex(content);
   let match: RegExpExecArray | null;
   const regex = new RegExp(JAVADOC_REGEX.source, 'g');
 
   while ((match = regex.exec(content)) !== null) {
-    const startLine = getLineNumber(content, match.index);
+    const startLine = lineAt(match.index);
     const endIndex = match.index + match[0].length;
-    const endLine = getLineNumber(content, endIndex - 1);
+    const endLine = lineAt(endIndex - 1);
     results.push({
       content: stripJavadoc(match[0]),
       startLine,
@@ -254,7 +257,11 @@ export function createJavaParser(): Parser {
     supportedExtensions: JAVA_EXTENSIONS,
 
     parse(content: string, filePath: string): ParseOutput {
-      const javadocBlocks = findAllJavadocBlocks(content);
+      // One-line `// knowgraph:` annotations bind like doc comments
+      const javadocBlocks = [
+        ...findAllJavadocBlocks(content),
+        ...findCompactLineComments(content),
+      ].sort((a, b) => a.startLine - b.startLine);
       const results: ParseResult[] = [];
       const diagnostics: ParseDiagnostic[] = [];
 
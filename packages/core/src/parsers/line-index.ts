@@ -0,0 +1,41 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Maps character offsets to line numbers in one pass over the source, for parsers that visit every comment block
+ * owner: knowgraph-core
+ * status: stable
+ * tags: [parser, lines, performance]
+ * context:
+ *   business_goal: Keep parsing linear in file size however many comment openers a file holds
+ *   domain: parser-engine
+ */
+
+/**
+ * Return a function mapping a character offset in `source` to its 1-based
+ * line number. Line starts are found once, so a parser can look up every
+ * comment block without rescanning the file from the start for each.
+ */
+export function createLineIndex(
+  source: string,
+): (charIndex: number) => number {
+  const lineStarts = [0];
+  let newline = source.indexOf('\n');
+  while (newline !== -1) {
+    lineStarts.push(newline + 1);
+    newline = source.indexOf('\n', newline + 1);
+  }
+
+  return (charIndex) => {
+    let low = 0;
+    let high = lineStarts.length - 1;
+    while (low < high) {
+      const mid = (low + high + 1) >> 1;
+      if (lineStarts[mid] <= charIndex) {
+        low = mid;
+      } else {
+        high = mid - 1;
+      }
+    }
+    return low + 1;
+  };
+}
@@ -1,10 +1,10 @@
 /**
  * @knowgraph
  * type: module
- * description: Extracts and validates @knowgraph YAML metadata from comment blocks
+ * description: Extracts and validates @knowgraph YAML, JSON, TOML, and one-line compact metadata from comment blocks
  * owner: knowgraph-core
  * status: stable
- * tags: [parser, yaml, extraction, metadata]
+ * tags: [parser, yaml, json, toml, compact, extraction, metadata]
  * context:
  *   business_goal: Transform raw code comments into structured, validated metadata
  *   domain: parser-engine
@@ -12,6 +12,21 @@
 import { parse as parseYaml } from 'yaml';
 import { CoreMetadataSchema, ExtendedMetadataSchema } from '../types/entity.js';
 import type { CoreMetadata, ExtendedMetadata } from '../types/entity.js';
+import { parseCompactAnnotation } from './compact.js';
+import { parseToml } from './toml.js';
+
+/**
+ * How a block's fields are written: `@knowgraph` blocks hold YAML, and
+ * `@knowgraph-json` and `@knowgraph-toml` blocks hold JSON and TOML. A
+ * `knowgraph:` line holds compact `key=value` pairs.
+ */
+export type AnnotationFormat = 'yaml' | 'json' | 'toml' | 'compact';
+
+export interface KnowgraphBlock {
+  readonly format: AnnotationFormat;
+  /** The block's text after the marker, without comment prefixes. */
+  readonly content: string;
+}
 
 export interface ExtractionError {
   readonly message: string;
@@ -21,25 +36,37 @@ export interface ExtractionError {
 export interface ExtractionResult {
   readonly metadata: CoreMetadata | ExtendedMetadata | null;
   readonly errors: readonly ExtractionError[];
+  /** The block's text, in whichever format it is written. */
   readonly rawYaml: string;
 }
 
+const FORMAT_LABELS: Readonly<Record<AnnotationFormat, string>> = {
+  yaml: 'YAML',
+  json: 'JSON',
+  toml: 'TOML',
+  compact: 'Compact annotation',
+};
+
+// `@knowgraph-json` and `@knowgraph-toml`, optionally followed by a colon
+const FORMAT_SUFFIX = /^-(json|toml)\b:?/;
+
+// A `knowgraph:` line, after any comment token
+const COMPACT_LINE = /^[ \t]*(?:\/\/+|\*|#|--)?[ \t]*knowgraph:(.*)$/gm;
+
 /**
  * Remove common leading whitespace from all non-empty lines.
  */
 function dedent(text: string): string {
   const lines = text.split('\n');
-  const nonEmptyLines = lines.filter((line) => line.trim().length > 0);
-  if (nonEmptyLines.length === 0) return text;
-
-  const minIndent = Math.min(
-    ...nonEmptyLines.map((line) => {
-      const match = line.match(/^(\s*)/);
-      return match ? match[1].length : 0;
-    }),
-  );
+  // A loop, not `Math.min(...)`: spreading a block of a few hundred
+  // thousand lines overflows the call stack
+  let minIndent = Infinity;
+  for (const line of lines) {
+    if (line.trim().length === 0) continue;
+    minIndent = Math.min(minIndent, line.length - line.trimStart().length);
+  }
 
-  if (minIndent === 0) return text;
+  if (minIndent === Infinity || minIndent === 0) return text;
 
   return lines
     .map((line) => (line.trim().length > 0 ? line.slice(minIndent) : line))
@@ -47,17 +74,28 @@ function dedent(text: string): string {
 }
 
 /**
- * Extract the YAML content following a @knowgraph marker from a comment block.
- * Returns null if no @knowgraph marker is found.
+ * Extract the format and content following a @knowgraph marker from a
+ * comment block, or the pairs on its compact `knowgraph:` lines when it
+ * has no marker. Returns null if there is neither.
  */
-export function extractKnowgraphYaml(commentBlock: string): string | null {
+export function extractKnowgraphBlock(
+  commentBlock: string,
+): KnowgraphBlock | null {
   const marker = '@knowgraph';
   const markerIndex = commentBlock.indexOf(marker);
   if (markerIndex === -1) {
-    return null;
+    // Several lines, such as Go `//knowgraph:` directives, add up to one
+    const pairs = [...commentBlock.matchAll(COMPACT_LINE)].map((match) =>
+      match[1].trim(),
+    );
+    if (pairs.length === 0) return null;
+    return { format: 'compact', content: pairs.join(' ').trim() };
   }
 
-  const afterMarker = commentBlock.slice(markerIndex + marker.length);
+  let afterMarker = commentBlock.slice(markerIndex + marker.length);
+  const suffix = FORMAT_SUFFIX.exec(afterMarker);
+  const format = suffix ? (suffix[1] as AnnotationFormat) : 'yaml';
+  if (suffix) afterMarker = afterMarker.slice(suffix[0].length);
 
   // Strip leading asterisks/hashes from each line (JSDoc or Python comment style)
   const lines = afterMarker.split('\n').map((line) => {
@@ -72,34 +110,52 @@ export function extractKnowgraphYaml(commentBlock: string): string | null {
 
   // Dedent to handle indented docstrings (e.g., Python method docstrings)
   // then trim leading/trailing whitespace
-  return dedent(lines.join('\n')).trim();
+  return { format, content: dedent(lines.join('\n')).trim() };
 }
 
 /**
- * Parse YAML string and validate against knowgraph schemas.
+ * Extract the content following a @knowgraph marker from a comment block,
+ * whatever its format. Returns null if no @knowgraph marker is found.
+ */
+export function extractKnowgraphYaml(commentBlock: string): string | null {
+  return extractKnowgraphBlock(commentBlock)?.content ?? null;
+}
+
+function parseBlock(content: string, format: AnnotationFormat): unknown {
+  if (format === 'json') return JSON.parse(content);
+  if (format === 'toml') return parseToml(content);
+  if (format === 'compact') return parseCompactAnnotation(content);
+  return parseYaml(content);
+}
+
+/**
+ * Parse a YAML, JSON, TOML, or compact string and validate against
+ * knowgraph schemas.
  * Tries ExtendedMetadataSchema first, then falls back to CoreMetadataSchema.
  */
 export function parseAndValidateMetadata(
   yamlString: string,
   baseLineOffset: number = 0,
+  format: AnnotationFormat = 'yaml',
 ): ExtractionResult {
+  const label = FORMAT_LABELS[format];
   if (!yamlString.trim()) {
     return {
       metadata: null,
-      errors: [{ message: 'Empty YAML content', line: baseLineOffset }],
+      errors: [{ message: `Empty ${label} content`, line: baseLineOffset }],
       rawYaml: yamlString,
     };
   }
 
   let parsed: unknown;
   try {
-    parsed = parseYaml(yamlString);
+    parsed = parseBlock(yamlString, format);
   } catch (error) {
-    const message = error instanceof Error ? error.message : 'Invalid YAML';
+    const message = error instanceof Error ? error.message : `Invalid ${label}`;
     return {
       metadata: null,
       errors: [
-        { message: `YAML parse error: ${message}`, line: baseLineOffset },
+        { message: `${label} parse error: ${message}`, line: baseLineOffset },
       ],
       rawYaml: yamlString,
     };
@@ -109,30 +165,34 @@ export function parseAndValidateMetadata(
     return {
       metadata: null,
       errors: [
-        { message: 'YAML did not produce an object', line: baseLineOffset },
+        { message: `${label} did not produce an object`, line: baseLineOffset },
       ],
       rawYaml: yamlString,
     };
   }
 
+  return { ...validateMetadata(parsed, baseLineOffset), rawYaml: yamlString };
+}
+
+/**
+ * Validate parsed annotation fields against knowgraph schemas, such as
+ * fields merged from several annotation sources.
+ * Tries ExtendedMetadataSchema first, then falls back to CoreMetadataSchema.
+ */
+export function validateMetadata(
+  value: unknown,
+  baseLineOffset: number = 0,
+): Omit<ExtractionResult, 'rawYaml'> {
   // Try extended schema first (superset of core)
-  const extendedResult = ExtendedMetadataSchema.safeParse(parsed);
+  const extendedResult = ExtendedMetadataSchema.safeParse(value);
   if (extendedResult.success) {
-    return {
-      metadata: extendedResult.data,
-      errors: [],
-      rawYaml: yamlString,
-    };
+    return { metadata: extendedResult.data, errors: [] };
   }
 
   // Try core schema
-  const coreResult = CoreMetadataSchema.safeParse(parsed);
+  const coreResult = CoreMetadataSchema.safeParse(value);
   if (coreResult.success) {
-    return {
-      metadata: coreResult.data,
-      errors: [],
-      rawYaml: yamlString,
-    };
+    return { metadata: coreResult.data, errors: [] };
   }
 
   // Both failed; report errors from extended schema (more informative)
@@ -143,11 +203,7 @@ export function parseAndValidateMetadata(
     }),
   );
 
-  return {
-    metadata: null,
-    errors,
-    rawYaml: yamlString,
-  };
+  return { metadata: null, errors };
 }
 
 /**
@@ -157,8 +213,8 @@ export function extractMetadata(
   commentBlock: string,
   baseLineOffset: number = 0,
 ): ExtractionResult {
-  const yamlContent = extractKnowgraphYaml(commentBlock);
-  if (yamlContent === null) {
+  const block = extractKnowgraphBlock(commentBlock);
+  if (block === null) {
     return {
       metadata: null,
       errors: [],
@@ -166,5 +222,5 @@ export function extractMetadata(
     };
   }
 
-  return parseAndValidateMetadata(yamlContent, baseLineOffset);
+  return parseAndValidateMetadata(block.content, baseLineOffset, block.format);
 }
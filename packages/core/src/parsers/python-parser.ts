@@ -15,6 +15,7 @@ import type {
   ParseOutput,
 } from '../types/parse-result.js';
 import type { Parser } from './types.js';
+import { createLineIndex } from './line-index.js';
 import { extractMetadata, extractKnowgraphYaml } from './metadata-extractor.js';
 
 const PYTHON_EXTENSIONS = ['.py', '.pyi'] as const;
@@ -78,12 +79,13 @@ function stripDocstringQuotes(raw: string): string {
 
 function findAllDocstrings(content: string): readonly DocstringMatch[] {
   const results: DocstringMatch[] = [];
+  const lineAt = createLineIndex(content);
   let match: RegExpExecArray | null;
   const regex = new RegExp(DOCSTRING_REGEX.source, 'g');
 
   while ((match = regex.exec(content)) !== null) {
-    const startLine = getLineNumber(content, match.index);
-    const endLine = getLineNumber(content, match.index + match[0].length - 1);
+    const startLine = lineAt(match.index);
+    const endLine = lineAt(match.index + match[0].length - 1);
     results.push({
       content: stripDocstringQuotes(match[0]),
       startLine,
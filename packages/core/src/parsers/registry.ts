@@ -9,6 +9,7 @@
  *   business_goal: Enable automatic parser selection based on file type
  *   domain: parser-engine
  */
+import { normalizeLineEndings } from '../paths/paths.js';
 import type { ParseResult, ParseOutput } from '../types/parse-result.js';
 import type { Parser, ParserRegistry } from './types.js';
 import { createPythonParser } from './python-parser.js';
@@ -45,7 +46,8 @@ function createRegistry(): ParserRegistry {
       if (!parser) {
         return EMPTY_OUTPUT;
       }
-      return parser.parse(content, filePath);
+      // Parsers split on `\n`; CRLF files would leave `\r` in every line
+      return parser.parse(normalizeLineEndings(content), filePath);
     },
 
     parseFileResults(
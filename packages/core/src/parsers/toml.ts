@@ -0,0 +1,335 @@
+/**
+ * @knowgraph
+ * type: function
+ * description: Minimal TOML parser for annotation blocks, covering tables, arrays of tables, inline tables, and every string and number form
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [parser, toml, annotations]
+ * context:
+ *   business_goal: Accept TOML annotations without adding a TOML dependency
+ *   domain: parser-engine
+ */
+
+const BARE_KEY = /[A-Za-z0-9_-]+/y;
+const SCALAR = /[A-Za-z0-9_+\-.:]+/y;
+const TIME_AFTER_DATE = / \d{2}:[0-9:.Z+-]*/y;
+
+const ESCAPES: Readonly<Record<string, string>> = {
+  b: '\b',
+  t: '\t',
+  n: '\n',
+  f: '\f',
+  r: '\r',
+  '"': '"',
+  '\\': '\\',
+};
+
+const INTEGER = /^[+-]?(0|[1-9](_?\d)*)$/;
+const PREFIXED_INTEGER =
+  /^0(x[0-9A-Fa-f](_?[0-9A-Fa-f])*|o[0-7](_?[0-7])*|b[01](_?[01])*)$/;
+const FLOAT =
+  /^[+-]?(0|[1-9](_?\d)*)(\.\d(_?\d)*([eE][+-]?\d(_?\d)*)?|[eE][+-]?\d(_?\d)*)$/;
+const DATE_OR_TIME = /^(\d{4}-\d{2}-\d{2}|\d{2}:\d{2}:\d{2})/;
+
+type Table = Record<string, unknown>;
+
+function isTable(value: unknown): value is Table {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+/** Set `key` as an own property, so that `__proto__` is only a key. */
+function define(table: Table, key: string, value: unknown): void {
+  Object.defineProperty(table, key, {
+    value,
+    enumerable: true,
+    writable: true,
+    configurable: true,
+  });
+}
+
+/** A bare-word value: a boolean, a number, or a date or time. */
+function scalar(token: string): unknown {
+  if (token === 'true') return true;
+  if (token === 'false') return false;
+  if (/^[+-]?inf$/.test(token)) return token[0] === '-' ? -Infinity : Infinity;
+  if (/^[+-]?nan$/.test(token)) return NaN;
+  if (INTEGER.test(token) || FLOAT.test(token)) {
+    return Number(token.replace(/_/g, ''));
+  }
+  if (PREFIXED_INTEGER.test(token)) return Number(token.replace(/_/g, ''));
+  // Dates and times stay strings, as YAML annotations keep them
+  if (DATE_OR_TIME.test(token)) return token;
+  return undefined;
+}
+
+/**
+ * Parse TOML text into plain objects. Dates and times are kept as the
+ * strings they were written as. Throws with the line of the first
+ * syntax error or redefined key.
+ */
+export function parseToml(text: string): Record<string, unknown> {
+  const root: Table = {};
+  // Inline tables and arrays are complete as written; headers cannot
+  // name them or add to them
+  const closed = new Set<object>();
+  const headed = new Set<object>();
+  let current = root;
+  let pos = 0;
+
+  function fail(message: string): never {
+    const line = text.slice(0, pos).split('\n').length;
+    throw new Error(`${message} at line ${line}`);
+  }
+  const skipSpace = (): void => {
+    while (text[pos] === ' ' || text[pos] === '\t') pos++;
+  };
+  const skipComment = (): void => {
+    if (text[pos] !== '#') return;
+    while (pos < text.length && text[pos] !== '\n') pos++;
+  };
+  const skipBlank = (): void => {
+    for (;;) {
+      skipSpace();
+      skipComment();
+      if (text[pos] !== '\n' && text[pos] !== '\r') return;
+      pos++;
+    }
+  };
+  const endLine = (): void => {
+    skipSpace();
+    skipComment();
+    if (pos >= text.length) return;
+    if (text[pos] === '\r') pos++;
+    if (text[pos] !== '\n') fail('Expected a new line');
+    pos++;
+  };
+
+  const parseEscape = (): string => {
+    const ch = text[pos++];
+    if (ch !== undefined && ch in ESCAPES) return ESCAPES[ch];
+    if (ch === 'u' || ch === 'U') {
+      const hex = text.slice(pos, pos + (ch === 'u' ? 4 : 8));
+      if (!/^[0-9A-Fa-f]{4}([0-9A-Fa-f]{4})?$/.test(hex)) {
+        fail('Invalid unicode escape');
+      }
+      pos += hex.length;
+      return String.fromCodePoint(parseInt(hex, 16));
+    }
+    return fail(`Invalid escape \\${ch ?? ''}`);
+  };
+  const parseMultiline = (delimiter: string, escapes: boolean): string => {
+    pos += 3;
+    // A newline right after the opening delimiter is not part of the value
+    if (text.startsWith('\r\n', pos)) pos += 2;
+    else if (text[pos] === '\n') pos++;
+    let value = '';
+    for (;;) {
+      if (pos >= text.length) fail('Unterminated string');
+      if (text.startsWith(delimiter, pos)) {
+        // Up to two quotes may sit right before the closing delimiter
+        let quotes = 0;
+        while (quotes < 2 && text[pos + 3 + quotes] === delimiter[0]) quotes++;
+        pos += 3 + quotes;
+        return value + delimiter[0].repeat(quotes);
+      }
+      const ch = text[pos++];
+      if (escapes && ch === '\\') {
+        // A backslash ending a line joins it to the next one
+        if (/^[ \t]*\r?\n/.test(text.slice(pos, pos + 80))) {
+          while (/\s/.test(text[pos] ?? '')) pos++;
+        } else {
+          value += parseEscape();
+        }
+      } else {
+        value += ch;
+      }
+    }
+  };
+  const parseBasicString = (): string => {
+    if (text.startsWith('"""', pos)) return parseMultiline('"""', true);
+    pos++;
+    let value = '';
+    for (;;) {
+      const ch = text[pos];
+      if (ch === undefined || ch === '\n') fail('Unterminated string');
+      pos++;
+      if (ch === '"') return value;
+      value += ch === '\\' ? parseEscape() : ch;
+    }
+  };
+  const parseLiteralString = (): string => {
+    if (text.startsWith("'''", pos)) return parseMultiline("'''", false);
+    const end = text.indexOf("'", pos + 1);
+    const newline = text.indexOf('\n', pos + 1);
+    if (end === -1 || (newline !== -1 && newline < end)) {
+      fail('Unterminated string');
+    }
+    const value = text.slice(pos + 1, end);
+    pos = end + 1;
+    return value;
+  };
+
+  const parseKey = (): readonly string[] => {
+    const parts: string[] = [];
+    for (;;) {
+      skipSpace();
+      if (text[pos] === '"') {
+        parts.push(parseBasicString());
+      } else if (text[pos] === "'") {
+        parts.push(parseLiteralString());
+      } else {
+        BARE_KEY.lastIndex = pos;
+        const match = BARE_KEY.exec(text);
+        if (!match) fail('Expected a key');
+        parts.push(match[0]);
+        pos += match[0].length;
+      }
+      skipSpace();
+      if (text[pos] !== '.') return parts;
+      pos++;
+    }
+  };
+
+  /** Set a dotted key in `table`, creating the tables along the way. */
+  const assign = (
+    table: Table,
+    key: readonly string[],
+    value: unknown,
+  ): void => {
+    let target = table;
+    for (const part of key.slice(0, -1)) {
+      const existing = target[part];
+      if (!Object.hasOwn(target, part)) {
+        const child: Table = {};
+        define(target, part, child);
+        target = child;
+      } else if (isTable(existing) && !closed.has(existing)) {
+        target = existing;
+      } else {
+        fail(`Key ${key.join('.')} is already defined`);
+      }
+    }
+    const last = key[key.length - 1];
+    if (Object.hasOwn(target, last)) {
+      fail(`Key ${key.join('.')} is already defined`);
+    }
+    define(target, last, value);
+  };
+
+  const parseArray = (): unknown[] => {
+    pos++;
+    const items: unknown[] = [];
+    for (;;) {
+      skipBlank();
+      if (text[pos] === ']') break;
+      items.push(parseValue());
+      skipBlank();
+      if (text[pos] !== ',') break;
+      pos++;
+    }
+    if (text[pos] !== ']') fail('Expected , or ] in array');
+    pos++;
+    closed.add(items);
+    return items;
+  };
+  const parseInlineTable = (): Table => {
+    pos++;
+    const table: Table = {};
+    skipSpace();
+    if (text[pos] !== '}') {
+      for (;;) {
+        const key = parseKey();
+        if (text[pos] !== '=') fail('Expected = after key');
+        pos++;
+        skipSpace();
+        assign(table, key, parseValue());
+        skipSpace();
+        if (text[pos] !== ',') break;
+        pos++;
+      }
+    }
+    if (text[pos] !== '}') fail('Expected , or } in inline table');
+    pos++;
+    closed.add(table);
+    return table;
+  };
+  const parseValue = (): unknown => {
+    const ch = text[pos];
+    if (ch === '"') return parseBasicString();
+    if (ch === "'") return parseLiteralString();
+    if (ch === '[') return parseArray();
+    if (ch === '{') return parseInlineTable();
+    SCALAR.lastIndex = pos;
+    const match = SCALAR.exec(text);
+    if (!match) return fail('Expected a value');
+    let token = match[0];
+    pos += token.length;
+    if (/^\d{4}-\d{2}-\d{2}$/.test(token)) {
+      TIME_AFTER_DATE.lastIndex = pos;
+      const time = TIME_AFTER_DATE.exec(text);
+      if (time) {
+        token += time[0];
+        pos += time[0].length;
+      }
+    }
+    const value = scalar(token);
+    return value === undefined ? fail(`Invalid value ${token}`) : value;
+  };
+
+  const parseHeader = (): void => {
+    const array = text.startsWith('[[', pos);
+    pos += array ? 2 : 1;
+    const key = parseKey();
+    const name = key.join('.');
+    if (!text.startsWith(array ? ']]' : ']', pos)) {
+      fail(`Expected ${array ? ']]' : ']'} after table name`);
+    }
+    pos += array ? 2 : 1;
+
+    let target = root;
+    for (const part of key.slice(0, -1)) {
+      if (!Object.hasOwn(target, part)) define(target, part, {});
+      let existing = target[part];
+      // A header inside an array of tables extends its latest table
+      if (Array.isArray(existing) && !closed.has(existing)) {
+        existing = existing[existing.length - 1];
+      }
+      if (!isTable(existing) || closed.has(existing)) {
+        fail(`Key ${name} is already defined`);
+      }
+      target = existing;
+    }
+    const last = key[key.length - 1];
+    if (!Object.hasOwn(target, last)) define(target, last, array ? [] : {});
+    const existing = target[last];
+    if (array) {
+      if (!Array.isArray(existing) || closed.has(existing)) {
+        fail(`Key ${name} is already defined`);
+      }
+      const table: Table = {};
+      existing.push(table);
+      current = table;
+    } else {
+      if (!isTable(existing) || closed.has(existing) || headed.has(existing)) {
+        fail(`Table ${name} is already defined`);
+      }
+      headed.add(existing);
+      current = existing;
+    }
+  };
+
+  for (;;) {
+    skipBlank();
+    if (pos >= text.length) return root;
+    if (text[pos] === '[') {
+      parseHeader();
+    } else {
+      const key = parseKey();
+      if (text[pos] !== '=') fail('Expected = after key');
+      pos++;
+      skipSpace();
+      assign(current, key, parseValue());
+    }
+    endLine();
+  }
+}
@@ -0,0 +1,326 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Binds annotations to the nearest function or class of a tree-sitter syntax tree, with byte-accurate ranges, for any language with a grammar
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [parser, tree-sitter, binding, languages]
+ * context:
+ *   business_goal: Cover every language tree-sitter has a grammar for while native extractors mature
+ *   domain: parser-engine
+ */
+import { createRequire } from 'node:module';
+import { join } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import type {
+  ParseDiagnostic,
+  ParseOutput,
+  ParseResult,
+  SourceRange,
+} from '../types/parse-result.js';
+import { stripBlockComment } from './generic-parser.js';
+import { extractMetadata } from './metadata-extractor.js';
+import type { Parser } from './types.js';
+
+export interface TreeSitterPoint {
+  readonly row: number;
+  readonly column: number;
+}
+
+/**
+ * The part of a syntax node the binder reads, which node-tree-sitter and
+ * web-tree-sitter nodes both have. Indices count UTF-16 code units, as
+ * both report them.
+ */
+export interface TreeSitterNode {
+  readonly type: string;
+  readonly startIndex: number;
+  readonly endIndex: number;
+  readonly startPosition: TreeSitterPoint;
+  readonly endPosition: TreeSitterPoint;
+  readonly parent: TreeSitterNode | null;
+  readonly namedChildren: readonly TreeSitterNode[];
+  childForFieldName(name: string): TreeSitterNode | null;
+}
+
+export interface TreeSitterTree {
+  readonly rootNode: TreeSitterNode;
+}
+
+export interface TreeSitterGrammar {
+  /** The language entities are recorded in, such as `ruby`. */
+  readonly language: string;
+  readonly extensions: readonly string[];
+  parse(content: string): TreeSitterTree;
+}
+
+/** A grammar as `tree_sitter.grammars` in the manifest names it. */
+export interface TreeSitterGrammarConfig {
+  readonly language: string;
+  /** The npm package of the grammar, such as `tree-sitter-ruby`. */
+  readonly module: string;
+  /** The export holding the language, for packages with several. */
+  readonly export?: string;
+  readonly extensions: readonly string[];
+}
+
+/** Node types of functions and methods across the common grammars. */
+const FUNCTION_TYPES: ReadonlySet<string> = new Set([
+  'function_definition',
+  'function_declaration',
+  'function_item',
+  'method_definition',
+  'method_declaration',
+  'method',
+  'singleton_method',
+  'constructor_declaration',
+  'function_signature_item',
+]);
+
+/** Node types of classes, structs, and the like. */
+const TYPE_TYPES: ReadonlySet<string> = new Set([
+  'class_definition',
+  'class_declaration',
+  'class',
+  'class_specifier',
+  'struct_specifier',
+  'struct_item',
+  'struct_declaration',
+  'enum_item',
+  'enum_declaration',
+  'enum_specifier',
+  'trait_item',
+  'trait_declaration',
+  'interface_declaration',
+  'object_declaration',
+  'protocol_declaration',
+  'record_declaration',
+  'namespace_definition',
+  'mod_item',
+  'module',
+  'type_spec',
+]);
+
+const MARKERS = ['@knowgraph', 'knowgraph:'];
+
+const LINE_COMMENT_PREFIX = /^\s*(?:\/\/+|#+|--+|;+|%+)\s?/;
+
+function isDefinition(node: TreeSitterNode): boolean {
+  // The root of some grammars, such as Python's, is a `module`
+  return (
+    node.parent !== null &&
+    (FUNCTION_TYPES.has(node.type) || TYPE_TYPES.has(node.type))
+  );
+}
+
+/**
+ * The definition `node` is or wraps, such as the function an `export` or
+ * a decorator list holds.
+ */
+function definitionIn(node: TreeSitterNode): TreeSitterNode | undefined {
+  if (isDefinition(node)) return node;
+  return node.namedChildren.find(isDefinition);
+}
+
+function enclosingDefinition(node: TreeSitterNode): TreeSitterNode | undefined {
+  for (let at = node.parent; at; at = at.parent) {
+    if (isDefinition(at)) return at;
+  }
+  return undefined;
+}
+
+function textOf(content: string, node: TreeSitterNode): string {
+  return content.slice(node.startIndex, node.endIndex);
+}
+
+/** The name of a definition, following C-style declarators to it. */
+function nameOf(content: string, node: TreeSitterNode): string | undefined {
+  const name = node.childForFieldName('name');
+  if (name) return textOf(content, name);
+  const declarator = node.childForFieldName('declarator');
+  if (declarator) {
+    return declarator.namedChildren.length === 0
+      ? textOf(content, declarator)
+      : nameOf(content, declarator);
+  }
+  return undefined;
+}
+
+/** A function's declaration up to its body, on one line. */
+function signatureOf(content: string, node: TreeSitterNode): string {
+  const body = node.childForFieldName('body');
+  const end = body ? body.startIndex : node.endIndex;
+  return content
+    .slice(node.startIndex, end)
+    .replace(/\s+/g, ' ')
+    .replace(/\s*[{:]?\s*$/, '');
+}
+
+function commentText(raw: string): string {
+  if (/^(?:\/\*|"""|''')/.test(raw)) return stripBlockComment(raw);
+  return raw.replace(LINE_COMMENT_PREFIX, '').trimEnd();
+}
+
+/** Runs of comments in `nodes`, each on the line after the one before. */
+function commentGroups(
+  nodes: readonly TreeSitterNode[],
+): readonly (readonly TreeSitterNode[])[] {
+  const groups: TreeSitterNode[][] = [];
+  let previous: TreeSitterNode | undefined;
+  for (const node of nodes) {
+    if (!node.type.includes('comment')) {
+      previous = undefined;
+      continue;
+    }
+    const last = groups[groups.length - 1];
+    if (previous && node.startPosition.row === previous.endPosition.row + 1) {
+      last.push(node);
+    } else {
+      groups.push([node]);
+    }
+    previous = node;
+  }
+  return groups;
+}
+
+function getModuleName(filePath: string): string {
+  const fileName = filePath.split('/').pop() ?? '';
+  const dotIndex = fileName.lastIndexOf('.');
+  return dotIndex > 0 ? fileName.slice(0, dotIndex) : fileName;
+}
+
+/**
+ * A parser binding each annotation comment to the definition it
+ * documents: the function or class starting on the line after it, or
+ * failing that the one it is inside, or else the file. Results carry the
+ * bound node's `range`, in UTF-8 bytes, and functions their signature.
+ */
+export function createTreeSitterParser(grammar: TreeSitterGrammar): Parser {
+  return {
+    name: `tree-sitter:${grammar.language}`,
+    supportedExtensions: grammar.extensions,
+
+    parse(content: string, filePath: string): ParseOutput {
+      const results: ParseResult[] = [];
+      const diagnostics: ParseDiagnostic[] = [];
+      const { rootNode } = grammar.parse(content);
+      const byteAt = (index: number): number =>
+        Buffer.byteLength(content.slice(0, index), 'utf-8');
+      const rangeOf = (node: TreeSitterNode): SourceRange => ({
+        startByte: byteAt(node.startIndex),
+        endByte: byteAt(node.endIndex),
+        startLine: node.startPosition.row + 1,
+        endLine: node.endPosition.row + 1,
+      });
+
+      const visit = (node: TreeSitterNode): void => {
+        for (const group of commentGroups(node.namedChildren)) {
+          const text = group
+            .map((comment) => commentText(textOf(content, comment)))
+            .join('\n')
+            .trim();
+          if (!MARKERS.some((marker) => text.includes(marker))) continue;
+          const first = group[0];
+          const last = group[group.length - 1];
+          const startLine = first.startPosition.row + 1;
+          const extraction = extractMetadata(text, startLine);
+          if (!extraction.metadata) {
+            for (const error of extraction.errors) {
+              diagnostics.push({
+                filePath,
+                line: error.line ?? startLine,
+                message: error.message,
+              });
+            }
+            continue;
+          }
+
+          const siblings = node.namedChildren;
+          const next = siblings
+            .slice(siblings.indexOf(last) + 1)
+            .find((sibling) => !sibling.type.includes('comment'));
+          const adjacent =
+            next && next.startPosition.row <= last.endPosition.row + 1
+              ? definitionIn(next)
+              : undefined;
+          const bound = adjacent ?? enclosingDefinition(first);
+          const owner = bound && enclosingDefinition(bound);
+          const parent = owner && nameOf(content, owner);
+          const { metadata } = extraction;
+          results.push({
+            name: (bound && nameOf(content, bound)) ?? getModuleName(filePath),
+            filePath,
+            line: bound ? bound.startPosition.row + 1 : startLine,
+            column: bound ? bound.startPosition.column + 1 : 1,
+            language: grammar.language,
+            entityType: metadata.type,
+            metadata,
+            rawDocstring: text,
+            range: rangeOf(bound ?? rootNode),
+            ...(bound && FUNCTION_TYPES.has(bound.type)
+              ? { signature: signatureOf(content, bound) }
+              : {}),
+            ...(parent ? { parent } : {}),
+          });
+        }
+        node.namedChildren.forEach(visit);
+      };
+      visit(rootNode);
+
+      return { results, diagnostics };
+    },
+  };
+}
+
+interface NodeTreeSitterParser {
+  setLanguage(language: unknown): void;
+  parse(input: (index: number) => string | null): TreeSitterTree;
+}
+
+type NodeTreeSitter = new () => NodeTreeSitterParser;
+
+/** Chunk size the parser reads sources in, under node-tree-sitter's 32K. */
+const CHUNK = 16_384;
+
+/**
+ * Load a grammar with node-tree-sitter, both resolved from `fromDir` as
+ * the project there installed them. Throws a `usage` error naming the
+ * packages to install when either is missing.
+ */
+export function loadTreeSitterGrammar(
+  config: TreeSitterGrammarConfig,
+  fromDir: string,
+): TreeSitterGrammar {
+  const requireFrom = createRequire(join(fromDir, 'package.json'));
+  const load = (id: string): unknown => {
+    try {
+      return requireFrom(id);
+    } catch (err) {
+      throw createKnowgraphError(
+        'usage',
+        `The ${config.language} tree-sitter grammar needs ${id}: run npm install tree-sitter ${config.module}`,
+        { cause: err },
+      );
+    }
+  };
+  const TreeSitter = load('tree-sitter') as NodeTreeSitter;
+  const loaded = load(config.module) as Record<string, unknown>;
+  const language = config.export ? loaded[config.export] : loaded;
+  if (!language) {
+    throw createKnowgraphError(
+      'usage',
+      `${config.module} has no export '${config.export}'`,
+    );
+  }
+  const parser = new TreeSitter();
+  parser.setLanguage(language);
+  return {
+    language: config.language,
+    extensions: config.extensions,
+    parse(content) {
+      return parser.parse((index) =>
+        index < content.length ? content.slice(index, index + CHUNK) : null,
+      );
+    },
+  };
+}
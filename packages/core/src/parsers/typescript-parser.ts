@@ -15,6 +15,8 @@ import type {
   ParseOutput,
 } from '../types/parse-result.js';
 import type { Parser } from './types.js';
+import { findCompactLineComments } from './compact.js';
+import { createLineIndex } from './line-index.js';
 import { extractMetadata, extractKnowgraphYaml } from './metadata-extractor.js';
 
 const TS_EXTENSIONS = ['.ts', '.tsx', '.js', '.jsx', '.mts', '.cts'] as const;
@@ -91,13 +93,14 @@ function stripJsdoc(raw: string): string {
 
 function findAllJsdocBlocks(content: string): readonly JsdocMatch[] {
   const results: JsdocMatch[] = [];
+  const lineAt = createLineIndex(content);
   let match: RegExpExecArray | null;
   const regex = new RegExp(JSDOC_REGEX.source, 'g');
 
   while ((match = regex.exec(content)) !== null) {
-    const startLine = getLineNumber(content, match.index);
+    const startLine = lineAt(match.index);
     const endIndex = match.index + match[0].length;
-    const endLine = getLineNumber(content, endIndex - 1);
+    const endLine = lineAt(endIndex - 1);
     results.push({
       content: stripJsdoc(match[0]),
       startLine,
@@ -221,7 +224,11 @@ export function createTypescriptParser(): Parser {
     supportedExtensions: TS_EXTENSIONS,
 
     parse(content: string, filePath: string): ParseOutput {
-      const jsdocBlocks = findAllJsdocBlocks(content);
+      // One-line `// knowgraph:` annotations bind like doc comments
+      const jsdocBlocks = [
+        ...findAllJsdocBlocks(content),
+        ...findCompactLineComments(content),
+      ].sort((a, b) => a.startLine - b.startLine);
       const results: ParseResult[] = [];
       const diagnostics: ParseDiagnostic[] = [];
 
@@ -0,0 +1,120 @@
+import { describe, it, expect } from 'vitest';
+import { externalNode } from '../../graph/graph-builder.js';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import {
+  applyGraphPatch,
+  createGraphPatch,
+  graphDigest,
+  parseGraphPatch,
+} from '../graph-patch.js';
+
+function node(id: string, owner: string | null = null): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner,
+    domain: null,
+    workspace: null,
+  };
+}
+
+function edge(from: string, to: string, confidence = 1) {
+  return {
+    from,
+    to,
+    kind: 'service' as const,
+    provenance: 'declared' as const,
+    confidence,
+  };
+}
+
+const stripe = externalNode('external_api', 'stripe');
+
+const base: DependencyGraph = {
+  nodes: [node('checkout'), node('payments'), node('legacy'), stripe],
+  edges: [edge('checkout', 'payments'), edge('legacy', stripe.id)],
+};
+
+const target: DependencyGraph = {
+  nodes: [node('payments', 'payments-team'), node('checkout'), node('refunds')],
+  edges: [edge('checkout', 'payments', 0.5), edge('refunds', 'payments')],
+};
+
+describe('graphDigest', () => {
+  it('ignores node and edge order', () => {
+    expect(
+      graphDigest({
+        nodes: [...base.nodes].reverse(),
+        edges: [...base.edges].reverse(),
+      }),
+    ).toBe(graphDigest(base));
+    expect(graphDigest(target)).not.toBe(graphDigest(base));
+    expect(graphDigest(base)).toMatch(/^sha256:[0-9a-f]{64}$/);
+  });
+});
+
+describe('createGraphPatch', () => {
+  it('carries changed nodes and edges whole and removals by key', () => {
+    const patch = createGraphPatch(base, target);
+    expect(patch.nodes).toEqual({
+      upsert: [node('payments', 'payments-team'), node('refunds')],
+      remove: [stripe.id, 'legacy'],
+    });
+    expect(patch.edges).toEqual({
+      upsert: [edge('checkout', 'payments', 0.5), edge('refunds', 'payments')],
+      remove: [
+        {
+          from: 'legacy',
+          to: stripe.id,
+          kind: 'service',
+          provenance: 'declared',
+        },
+      ],
+    });
+  });
+
+  it('is empty between equal graphs', () => {
+    const patch = createGraphPatch(base, base);
+    expect(patch.base).toBe(patch.target);
+    expect(patch.nodes).toEqual({ upsert: [], remove: [] });
+    expect(patch.edges).toEqual({ upsert: [], remove: [] });
+  });
+});
+
+describe('applyGraphPatch', () => {
+  it('turns the base into the target', () => {
+    const patch = parseGraphPatch(
+      JSON.stringify(createGraphPatch(base, target)),
+    );
+    const patched = applyGraphPatch(base, patch);
+    expect(graphDigest(patched)).toBe(graphDigest(target));
+    expect(patched.nodes.map((n) => n.id)).toEqual([
+      'checkout',
+      'payments',
+      'refunds',
+    ]);
+  });
+
+  it('refuses a base the patch was not made from', () => {
+    const patch = createGraphPatch(base, target);
+    expect(() => applyGraphPatch(target, patch)).toThrow(
+      'push the full graph instead',
+    );
+  });
+});
+
+describe('parseGraphPatch', () => {
+  it('rejects other JSON and newer versions', () => {
+    expect(() => parseGraphPatch('{')).toThrow('Invalid graph patch');
+    expect(() => parseGraphPatch('{"nodes":[],"edges":[]}')).toThrow(
+      'Not a graph patch',
+    );
+    const newer = { ...createGraphPatch(base, base), version: 99 };
+    expect(() => parseGraphPatch(JSON.stringify(newer))).toThrow(
+      'Unsupported graph patch version 99',
+    );
+  });
+});
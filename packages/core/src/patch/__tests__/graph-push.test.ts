@@ -0,0 +1,89 @@
+import { describe, it, expect, vi } from 'vitest';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import { graphDigest } from '../graph-patch.js';
+import { pushGraph } from '../graph-push.js';
+
+function node(id: string): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+const base: DependencyGraph = { nodes: [node('checkout')], edges: [] };
+const target: DependencyGraph = {
+  nodes: [node('checkout'), node('refunds')],
+  edges: [],
+};
+
+function reply(status: number, body: object): Response {
+  return new Response(JSON.stringify(body), {
+    status,
+    headers: { 'Content-Type': 'application/json' },
+  });
+}
+
+const options = {
+  server: 'https://kg.example.com/',
+  name: 'payments',
+  token: 'kg_test',
+};
+
+describe('pushGraph', () => {
+  it('sends only the patch from the base', async () => {
+    const fetch = vi.fn(async () =>
+      reply(200, { digest: graphDigest(target) }),
+    );
+    const report = await pushGraph(target, { ...options, base, fetch });
+
+    expect(fetch).toHaveBeenCalledTimes(1);
+    const [url, init] = fetch.mock.calls[0] as unknown as [
+      string,
+      RequestInit,
+    ];
+    expect(url).toBe('https://kg.example.com/registry/v1/graphs/payments');
+    expect(init.method).toBe('PATCH');
+    expect(init.headers).toMatchObject({ Authorization: 'Bearer kg_test' });
+    expect(JSON.parse(init.body as string).nodes.upsert).toEqual([
+      node('refunds'),
+    ]);
+    expect(report).toMatchObject({
+      digest: graphDigest(target),
+      created: false,
+    });
+    expect(report.patch?.target).toBe(graphDigest(target));
+    expect(report.bytes).toBe((init.body as string).length);
+  });
+
+  it('sends the full graph when the server cannot apply it', async () => {
+    const fetch = vi
+      .fn()
+      .mockResolvedValueOnce(reply(409, { error: 'Patch applies to x' }))
+      .mockResolvedValueOnce(reply(201, { digest: graphDigest(target) }));
+    const report = await pushGraph(target, { ...options, base, fetch });
+
+    expect(fetch.mock.calls.map(([, init]) => init.method)).toEqual([
+      'PATCH',
+      'PUT',
+    ]);
+    expect(JSON.parse(fetch.mock.calls[1][1].body)).toEqual(target);
+    expect(report.patch).toBeUndefined();
+    expect(report.created).toBe(true);
+  });
+
+  it('fails with the server error otherwise', async () => {
+    const fetch = vi.fn(async () =>
+      reply(403, { error: 'Needs one of the roles: admin' }),
+    );
+    await expect(
+      pushGraph(target, { ...options, base, fetch }),
+    ).rejects.toThrow('Graph push failed: 403 Needs one of the roles: admin');
+    expect(fetch).toHaveBeenCalledTimes(1);
+  });
+});
@@ -0,0 +1,87 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import { createGraphPatch, graphDigest } from '../graph-patch.js';
+import { createGraphStore } from '../graph-store.js';
+
+function node(id: string): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+const base: DependencyGraph = {
+  nodes: [node('checkout'), node('payments')],
+  edges: [
+    {
+      from: 'checkout',
+      to: 'payments',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    },
+  ],
+};
+
+const target: DependencyGraph = {
+  nodes: [...base.nodes, node('refunds')],
+  edges: base.edges,
+};
+
+describe('createGraphStore', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-graphs-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('keeps pushed graphs as snapshots and patches them', () => {
+    const store = createGraphStore(join(dir, 'graphs'));
+    expect(store.digest('payments')).toBeUndefined();
+    expect(store.put('payments', base)).toEqual({
+      digest: graphDigest(base),
+      created: true,
+    });
+    expect(existsSync(join(dir, 'graphs', 'payments.kgs'))).toBe(true);
+
+    const patched = store.patch('payments', createGraphPatch(base, target));
+    expect(patched).toEqual({ digest: graphDigest(target), created: false });
+
+    const reopened = createGraphStore(join(dir, 'graphs'));
+    expect(reopened.digest('payments')).toBe(graphDigest(target));
+    expect(reopened.get('payments')?.nodes).toHaveLength(3);
+  });
+
+  it('refuses patches it has no base for', () => {
+    const store = createGraphStore(dir);
+    const patch = createGraphPatch(base, target);
+    expect(() => store.patch('payments', patch)).toThrow(
+      "No graph 'payments' to patch",
+    );
+    store.put('payments', target);
+    expect(() => store.patch('payments', patch)).toThrow(
+      'push the full graph instead',
+    );
+    expect(store.digest('payments')).toBe(graphDigest(target));
+  });
+
+  it('rejects names that are not one path segment', () => {
+    const store = createGraphStore(dir);
+    expect(() => store.put('../payments', base)).toThrow(
+      "Invalid graph name '../payments'",
+    );
+  });
+});
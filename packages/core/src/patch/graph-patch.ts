@@ -0,0 +1,172 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Computes patches between dependency graphs and applies them, checking both ends against content digests
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [patch, diff, graph, registry, push]
+ * context:
+ *   business_goal: Make sure a patch applies only to the graph it was made from
+ *   domain: patch
+ */
+import { createHash } from 'node:crypto';
+import {
+  compareStrings,
+  sortGraph,
+  stableStringify,
+} from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DependencyGraph, GraphEdge, GraphNode } from '../graph/types.js';
+import type { EdgeKey, GraphPatch } from './types.js';
+
+export const GRAPH_PATCH_VERSION = 1;
+
+function edgeKey(edge: EdgeKey): string {
+  return [edge.from, edge.to, edge.kind, edge.provenance].join('\0');
+}
+
+/**
+ * `sha256:` and the hex digest of the canonical form of `graph`, so the
+ * same nodes and edges digest alike in any order.
+ */
+export function graphDigest(graph: DependencyGraph): string {
+  const canonical = stableStringify(sortGraph(graph), false);
+  return `sha256:${createHash('sha256').update(canonical).digest('hex')}`;
+}
+
+function changed<K, T>(
+  before: ReadonlyMap<K, T>,
+  after: ReadonlyMap<K, T>,
+): T[] {
+  return [...after]
+    .filter(
+      ([key, value]) =>
+        stableStringify(before.get(key)) !== stableStringify(value),
+    )
+    .map(([, value]) => value);
+}
+
+/** The patch that turns `base` into `target`. */
+export function createGraphPatch(
+  base: DependencyGraph,
+  target: DependencyGraph,
+): GraphPatch {
+  const nodesBefore = new Map(base.nodes.map((node) => [node.id, node]));
+  const nodesAfter = new Map(target.nodes.map((node) => [node.id, node]));
+  const edgesBefore = new Map(base.edges.map((edge) => [edgeKey(edge), edge]));
+  const edgesAfter = new Map(target.edges.map((edge) => [edgeKey(edge), edge]));
+  const sorted = sortGraph({
+    nodes: changed(nodesBefore, nodesAfter),
+    edges: changed(edgesBefore, edgesAfter),
+  });
+  return {
+    format: 'knowgraph-patch',
+    version: GRAPH_PATCH_VERSION,
+    base: graphDigest(base),
+    target: graphDigest(target),
+    nodes: {
+      upsert: sorted.nodes,
+      remove: [...nodesBefore.keys()]
+        .filter((id) => !nodesAfter.has(id))
+        .sort(compareStrings),
+    },
+    edges: {
+      upsert: sorted.edges,
+      remove: [...edgesBefore]
+        .filter(([key]) => !edgesAfter.has(key))
+        .sort(([a], [b]) => compareStrings(a, b))
+        .map(([, { from, to, kind, provenance }]) => ({
+          from,
+          to,
+          kind,
+          provenance,
+        })),
+    },
+  };
+}
+
+/**
+ * Apply `patch` to `base`, returning the graph in canonical order. Throws
+ * a usage error when `base` is not the graph the patch was made from, and
+ * a schema error when the result does not match the patch's target.
+ */
+export function applyGraphPatch(
+  base: DependencyGraph,
+  patch: GraphPatch,
+): DependencyGraph {
+  const digest = graphDigest(base);
+  if (digest !== patch.base) {
+    throw createKnowgraphError(
+      'usage',
+      `Patch applies to graph ${patch.base}, not ${digest}; push the full graph instead`,
+    );
+  }
+  const nodes = new Map<string, GraphNode>(
+    base.nodes.map((node) => [node.id, node]),
+  );
+  for (const id of patch.nodes.remove) nodes.delete(id);
+  for (const node of patch.nodes.upsert) nodes.set(node.id, node);
+  const edges = new Map<string, GraphEdge>(
+    base.edges.map((edge) => [edgeKey(edge), edge]),
+  );
+  for (const key of patch.edges.remove) edges.delete(edgeKey(key));
+  for (const edge of patch.edges.upsert) edges.set(edgeKey(edge), edge);
+  const graph = sortGraph({
+    nodes: [...nodes.values()],
+    edges: [...edges.values()],
+  });
+  if (graphDigest(graph) !== patch.target) {
+    throw createKnowgraphError(
+      'schema',
+      `Patched graph does not match the patch target ${patch.target}`,
+    );
+  }
+  return graph;
+}
+
+function isPatch(value: unknown): value is GraphPatch {
+  if (typeof value !== 'object' || value === null) return false;
+  const patch = value as Record<string, unknown>;
+  const part = (name: string): boolean => {
+    const section = patch[name] as Record<string, unknown> | undefined;
+    return (
+      typeof section === 'object' &&
+      section !== null &&
+      Array.isArray(section.upsert) &&
+      Array.isArray(section.remove)
+    );
+  };
+  return (
+    patch.format === 'knowgraph-patch' &&
+    typeof patch.base === 'string' &&
+    typeof patch.target === 'string' &&
+    part('nodes') &&
+    part('edges')
+  );
+}
+
+/** Parse a patch written by `createGraphPatch`, as JSON. */
+export function parseGraphPatch(text: string): GraphPatch {
+  let parsed: unknown;
+  try {
+    parsed = JSON.parse(text);
+  } catch (err) {
+    throw createKnowgraphError(
+      'parse',
+      `Invalid graph patch: ${err instanceof Error ? err.message : String(err)}`,
+    );
+  }
+  if (!isPatch(parsed)) {
+    throw createKnowgraphError(
+      'schema',
+      'Not a graph patch: expected format "knowgraph-patch" with nodes and edges',
+    );
+  }
+  if (parsed.version > GRAPH_PATCH_VERSION) {
+    throw createKnowgraphError(
+      'schema',
+      `Unsupported graph patch version ${parsed.version}`,
+    );
+  }
+  return parsed;
+}
@@ -0,0 +1,91 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Client that pushes a graph to a registry server as a patch from the last push, sending the full graph only when the server cannot apply it
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [patch, registry, push, http, client]
+ * context:
+ *   business_goal: Recover on its own when the registry and the client disagree about the last push
+ *   domain: patch
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DependencyGraph } from '../graph/types.js';
+import { createGraphPatch } from './graph-patch.js';
+import type {
+  GraphPushOptions,
+  GraphPushReport,
+  GraphPushResult,
+} from './types.js';
+
+/** Where a registry server keeps graphs, below its base URL. */
+export const GRAPHS_PATH = '/registry/v1/graphs/';
+
+async function pushFailure(response: Response): Promise<Error> {
+  let detail = response.statusText;
+  try {
+    const body = (await response.json()) as { error?: unknown };
+    if (typeof body.error === 'string') detail = body.error;
+  } catch {
+    // not JSON; the status text will do
+  }
+  return createKnowgraphError(
+    'io',
+    `Graph push failed: ${response.status} ${detail}`,
+  );
+}
+
+async function pushResult(response: Response): Promise<GraphPushResult> {
+  const body = (await response.json()) as { digest?: unknown };
+  if (typeof body.digest !== 'string') {
+    throw createKnowgraphError(
+      'schema',
+      'Graph push answered without the stored digest',
+    );
+  }
+  return { digest: body.digest, created: response.status === 201 };
+}
+
+/**
+ * Push `graph` to the registry at `options.server` under `options.name`.
+ * Given the graph last pushed as `base`, only the patch from it is sent;
+ * when the server has no graph by that name, or not that one, the full
+ * graph follows. Throws an io error when the server refuses either.
+ */
+export async function pushGraph(
+  graph: DependencyGraph,
+  options: GraphPushOptions,
+): Promise<GraphPushReport> {
+  const send = options.fetch ?? fetch;
+  const server = options.server.replace(/\/+$/, '');
+  const url = `${server}${GRAPHS_PATH}${encodeURIComponent(options.name)}`;
+  const request = (method: string, body: string) =>
+    send(url, {
+      method,
+      headers: {
+        Authorization: `Bearer ${options.token}`,
+        'Content-Type': 'application/json',
+      },
+      body,
+    });
+
+  if (options.base) {
+    const patch = createGraphPatch(options.base, graph);
+    const body = JSON.stringify(patch);
+    const response = await request('PATCH', body);
+    if (response.ok) {
+      return {
+        ...(await pushResult(response)),
+        patch,
+        bytes: Buffer.byteLength(body),
+      };
+    }
+    if (response.status !== 404 && response.status !== 409) {
+      throw await pushFailure(response);
+    }
+  }
+  const body = JSON.stringify(graph);
+  const response = await request('PUT', body);
+  if (!response.ok) throw await pushFailure(response);
+  return { ...(await pushResult(response)), bytes: Buffer.byteLength(body) };
+}
@@ -0,0 +1,75 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Directory-backed store of the graphs repositories push to a registry, replaced whole or by a patch checked against the stored digest
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [patch, registry, push, graph, store]
+ * context:
+ *   business_goal: Let a central server keep each repository's graph current from pushes of only what changed
+ *   domain: patch
+ */
+import { existsSync, mkdirSync } from 'node:fs';
+import { join } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DependencyGraph } from '../graph/types.js';
+import {
+  readGraphSnapshot,
+  writeGraphSnapshot,
+} from '../snapshot/graph-snapshot.js';
+import { applyGraphPatch, graphDigest } from './graph-patch.js';
+import type { GraphPatch, GraphPushResult, GraphStore } from './types.js';
+
+/** Graph names: one segment, as registry token and bundle names are. */
+const NAME_PATTERN = /^[A-Za-z0-9][A-Za-z0-9._-]*$/;
+
+/**
+ * A store keeping each graph as a `<name>.kgs` snapshot in `dir`,
+ * replacing it only once the new file is complete. Digests are computed
+ * once per graph and kept in memory.
+ */
+export function createGraphStore(dir: string): GraphStore {
+  const digests = new Map<string, string>();
+
+  function pathOf(name: string): string {
+    if (!NAME_PATTERN.test(name)) {
+      throw createKnowgraphError('schema', `Invalid graph name '${name}'`);
+    }
+    return join(dir, `${name}.kgs`);
+  }
+
+  function get(name: string): DependencyGraph | undefined {
+    const path = pathOf(name);
+    if (!existsSync(path)) return undefined;
+    const graph = readGraphSnapshot(path);
+    if (!digests.has(name)) digests.set(name, graphDigest(graph));
+    return graph;
+  }
+
+  function digest(name: string): string | undefined {
+    if (!digests.has(name)) get(name);
+    return digests.get(name);
+  }
+
+  function store(name: string, graph: DependencyGraph): GraphPushResult {
+    const created = digest(name) === undefined;
+    mkdirSync(dir, { recursive: true });
+    writeGraphSnapshot(pathOf(name), graph);
+    const stored = graphDigest(graph);
+    digests.set(name, stored);
+    return { digest: stored, created };
+  }
+
+  function patch(name: string, change: GraphPatch): GraphPushResult {
+    const base = get(name);
+    if (!base) {
+      throw createKnowgraphError(
+        'usage',
+        `No graph '${name}' to patch; push the full graph instead`,
+      );
+    }
+    return store(name, applyGraphPatch(base, change));
+  }
+
+  return { digest, get, put: store, patch };
+}
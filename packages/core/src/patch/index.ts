@@ -0,0 +1,17 @@
+export type {
+  EdgeKey,
+  GraphPatch,
+  GraphPushOptions,
+  GraphPushReport,
+  GraphPushResult,
+  GraphStore,
+} from './types.js';
+export {
+  GRAPH_PATCH_VERSION,
+  applyGraphPatch,
+  createGraphPatch,
+  graphDigest,
+  parseGraphPatch,
+} from './graph-patch.js';
+export { createGraphStore } from './graph-store.js';
+export { GRAPHS_PATH, pushGraph } from './graph-push.js';
@@ -0,0 +1,96 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for graph patches, the changes between two exports of a dependency graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [patch, diff, graph, types, interface]
+ * context:
+ *   business_goal: Keep graph patches readable by registry servers on other knowgraph versions
+ *   domain: patch
+ */
+import type {
+  DependencyGraph,
+  DependencyKind,
+  EdgeProvenance,
+  GraphEdge,
+  GraphNode,
+} from '../graph/types.js';
+
+/**
+ * Identifies an edge: two nodes are joined by at most one edge of each
+ * kind and provenance.
+ */
+export interface EdgeKey {
+  readonly from: string;
+  readonly to: string;
+  readonly kind: DependencyKind;
+  readonly provenance: EdgeProvenance;
+}
+
+/**
+ * The changes that turn one graph into another. Added and changed nodes
+ * and edges are carried whole; removals carry only their keys.
+ */
+export interface GraphPatch {
+  readonly format: 'knowgraph-patch';
+  readonly version: number;
+  /** `graphDigest` of the graph the patch applies to. */
+  readonly base: string;
+  /** `graphDigest` of the graph applying it produces. */
+  readonly target: string;
+  readonly nodes: {
+    readonly upsert: readonly GraphNode[];
+    readonly remove: readonly string[];
+  };
+  readonly edges: {
+    readonly upsert: readonly GraphEdge[];
+    readonly remove: readonly EdgeKey[];
+  };
+}
+
+/** What a push left stored under its name. */
+export interface GraphPushResult {
+  /** `graphDigest` of the stored graph. */
+  readonly digest: string;
+  /** False when a graph was already stored under the name. */
+  readonly created: boolean;
+}
+
+/**
+ * Graphs pushed to a registry, by name, kept whole and replaced either
+ * whole or by a patch. Every change is written to disk before it returns.
+ */
+export interface GraphStore {
+  /** `graphDigest` of the graph stored as `name`, if any. */
+  digest(name: string): string | undefined;
+  get(name: string): DependencyGraph | undefined;
+  /** Store `graph` as `name`. Throws a schema error for an invalid name. */
+  put(name: string, graph: DependencyGraph): GraphPushResult;
+  /**
+   * Apply `patch` to the graph stored as `name`. Throws a usage error when
+   * there is none or it is not the patch's base, and a schema error when
+   * the result does not match the patch's target.
+   */
+  patch(name: string, patch: GraphPatch): GraphPushResult;
+}
+
+export interface GraphPushOptions {
+  /** Base URL of a `knowgraph serve --http` server with a registry. */
+  readonly server: string;
+  /** Name the graph is stored under. */
+  readonly name: string;
+  /** Bearer token with one of the registry's admin roles. */
+  readonly token: string;
+  /** The graph last pushed; a patch from it is sent when given. */
+  readonly base?: DependencyGraph;
+  readonly fetch?: typeof fetch;
+}
+
+/** How a graph was pushed. */
+export interface GraphPushReport extends GraphPushResult {
+  /** The patch sent, or undefined when the full graph was. */
+  readonly patch?: GraphPatch;
+  /** Size of the request body that was accepted. */
+  readonly bytes: number;
+}
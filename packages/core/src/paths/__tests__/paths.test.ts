@@ -0,0 +1,57 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdirSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  matchPathCase,
+  normalizeLineEndings,
+  toPosixPath,
+} from '../paths.js';
+
+describe('toPosixPath', () => {
+  it('replaces Windows separators', () => {
+    expect(toPosixPath('src\\auth\\login.ts', '\\')).toBe('src/auth/login.ts');
+  });
+
+  it('leaves POSIX paths, backslashes included, as they are', () => {
+    expect(toPosixPath('src/a\\b.ts', '/')).toBe('src/a\\b.ts');
+  });
+});
+
+describe('normalizeLineEndings', () => {
+  it('turns CRLF and lone CR into LF and drops a byte order mark', () => {
+    expect(normalizeLineEndings('\uFEFFa\r\nb\rc\n')).toBe('a\nb\nc\n');
+  });
+});
+
+describe('matchPathCase', () => {
+  let rootDir: string;
+
+  beforeEach(() => {
+    rootDir = join(
+      tmpdir(),
+      `knowgraph-paths-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(join(rootDir, 'Services', 'Auth'), { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(rootDir, { recursive: true, force: true });
+  });
+
+  it('keeps a path already spelled as on disk', () => {
+    expect(matchPathCase(rootDir, 'Services/Auth')).toBe('Services/Auth');
+  });
+
+  it('takes the on-disk case only where the file system ignores case', () => {
+    const insensitive = existsSync(join(rootDir, 'services'));
+    expect(matchPathCase(rootDir, 'services/auth')).toBe(
+      insensitive ? 'Services/Auth' : 'services/auth',
+    );
+  });
+
+  it('returns paths that do not exist as given', () => {
+    expect(matchPathCase(rootDir, 'Services/Billing')).toBe('Services/Billing');
+    expect(matchPathCase(rootDir, '')).toBe('');
+  });
+});
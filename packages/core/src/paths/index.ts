@@ -0,0 +1,5 @@
+export {
+  matchPathCase,
+  normalizeLineEndings,
+  toPosixPath,
+} from './paths.js';
@@ -0,0 +1,63 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Normalizes path separators, path case, and line endings so Windows scans store what POSIX scans do
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [paths, windows, crlf, portability]
+ * context:
+ *   business_goal: Give annotators on Windows laptops the same entities, ids, and paths as everyone else
+ *   domain: indexer-engine
+ */
+import { existsSync, readdirSync } from 'node:fs';
+import { join, sep } from 'node:path';
+
+/**
+ * `path` with `/` separators. Only `separator`, the platform's by default,
+ * is replaced, so a POSIX file name holding a backslash is left as it is.
+ */
+export function toPosixPath(path: string, separator: string = sep): string {
+  return separator === '/' ? path : path.split(separator).join('/');
+}
+
+/**
+ * `content` with `\n` line endings and no byte order mark, however the
+ * editor or a `core.autocrlf` checkout saved it.
+ */
+export function normalizeLineEndings(content: string): string {
+  return content.replace(/^\uFEFF/, '').replace(/\r\n?/g, '\n');
+}
+
+/**
+ * `relPath` under `rootDir` spelled as it is on disk. On case-insensitive
+ * file systems a path typed in another case names the same directory, so
+ * each segment that only matches an entry in another case takes the
+ * entry's. On case-sensitive ones, and for segments that do not exist,
+ * `relPath` is returned as given.
+ */
+export function matchPathCase(rootDir: string, relPath: string): string {
+  const segments = relPath.split('/').filter(Boolean);
+  const matched: string[] = [];
+  let dir = rootDir;
+  for (const [index, segment] of segments.entries()) {
+    let names: string[];
+    try {
+      names = readdirSync(dir);
+    } catch {
+      return relPath;
+    }
+    let name = segment;
+    if (!names.includes(segment)) {
+      const lower = segment.toLowerCase();
+      const found = names.filter((entry) => entry.toLowerCase() === lower);
+      // Only a case-insensitive file system resolves the typed spelling
+      if (found.length !== 1 || !existsSync(join(dir, segment))) {
+        return relPath;
+      }
+      name = found[0];
+    }
+    matched.push(name);
+    if (index < segments.length - 1) dir = join(dir, name);
+  }
+  return matched.join('/');
+}
@@ -0,0 +1,256 @@
+import { describe, it, expect, beforeAll, afterAll } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { StoredEntity } from '../../indexer/types.js';
+import { createDatabaseManager } from '../../indexer/database.js';
+import { createEnrichmentCalls } from '../../enrichers/enrichment-calls.js';
+import {
+  callPlugin,
+  createPluginExporter,
+  createPluginLintRule,
+  createPluginParser,
+  describePlugin,
+  enrichWithPlugin,
+  exportWithPlugin,
+  findExportPlugin,
+} from '../exec-plugin.js';
+import type { PluginConfig } from '../types.js';
+
+const PLUGIN_SOURCE = `
+let input = '';
+process.stdin.on('data', (chunk) => (input += chunk));
+process.stdin.on('end', () => {
+  const { protocol, method, params } = JSON.parse(input);
+  const reply = (result) => process.stdout.write(JSON.stringify({ result }));
+  if (protocol !== 1) return reply(null);
+  switch (method) {
+    case 'describe':
+      return reply({ name: 'terraform', version: '0.1.0' });
+    case 'extract':
+      return reply({
+        entities: params.content.split('\\n').flatMap((line, i) => {
+          const match = /^resource "(\\w+)"/.exec(line);
+          return match
+            ? [{
+                name: match[1],
+                line: i + 1,
+                entityType: 'service',
+                metadata: { type: 'service', description: 'Terraform ' + match[1] },
+              }]
+            : [];
+        }),
+      });
+    case 'export':
+      return reply({
+        content: params.format + ':' + params.entities.map((e) => e.name).join(','),
+      });
+    case 'lint':
+      return reply({
+        issues: params.annotations
+          .filter((a) => !a.metadata.owner)
+          .map((a) => ({
+            filePath: a.filePath,
+            line: a.line,
+            rule: 'owner-required',
+            message: 'Every annotation needs an owner',
+          })),
+      });
+    case 'enrich':
+      return reply({
+        entities: params.entities.map((e) => ({
+          id: e.id,
+          tags: ['terraform', ...e.tags],
+          links: [{ type: 'runbook', url: 'https://runbooks.example.com/' + e.name }],
+          metadata: { owner: 'platform' },
+        })).concat([{ id: 'missing', tags: ['x'] }]),
+      });
+    case 'fail':
+      process.stderr.write('boom\\n');
+      process.exit(3);
+    default:
+      process.stdout.write(JSON.stringify({ error: { message: 'no ' + method } }));
+  }
+});
+`;
+
+function makeEntity(name: string): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: 'main.tf',
+    name,
+    entityType: 'service',
+    description: name,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'terraform',
+    line: 1,
+    column: 0,
+    owner: null,
+    status: null,
+    metadata: { type: 'service', description: name },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+describe('exec plugins', () => {
+  let dir: string;
+  let plugin: PluginConfig;
+
+  beforeAll(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-plugin-'));
+    writeFileSync(join(dir, 'plugin.cjs'), PLUGIN_SOURCE);
+    plugin = {
+      name: 'terraform',
+      command: [process.execPath, join(dir, 'plugin.cjs')],
+      extensions: ['.tf'],
+      formats: ['tfdoc'],
+      rules: true,
+    };
+  });
+
+  afterAll(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('describes itself', () => {
+    expect(describePlugin(plugin)).toEqual({
+      name: 'terraform',
+      version: '0.1.0',
+    });
+  });
+
+  it('extracts entities through a parser', () => {
+    const parser = createPluginParser(plugin);
+    expect(parser.supportedExtensions).toEqual(['.tf']);
+    const output = parser.parse(
+      'provider "aws" {}\nresource "queue" {}\n',
+      'infra/main.tf',
+    );
+    expect(output.results).toEqual([
+      {
+        name: 'queue',
+        line: 2,
+        column: 0,
+        entityType: 'service',
+        metadata: { type: 'service', description: 'Terraform queue' },
+        filePath: 'infra/main.tf',
+        language: 'terraform',
+        rawDocstring: '',
+      },
+    ]);
+  });
+
+  it('renders export formats', () => {
+    expect(findExportPlugin([plugin], 'tfdoc')).toBe(plugin);
+    expect(findExportPlugin([plugin], 'markdown')).toBeUndefined();
+    expect(
+      exportWithPlugin(plugin, 'tfdoc', [makeEntity('a'), makeEntity('b')]),
+    ).toBe('tfdoc:a,b');
+
+    const chunks: Array<string | Uint8Array> = [];
+    const exporter = createPluginExporter(plugin, 'tfdoc');
+    expect(exporter.defaultOutput).toBe('knowgraph-export.tfdoc');
+    expect(
+      exporter.export(
+        () => [makeEntity('a')],
+        { write: (c) => chunks.push(c) },
+        {},
+      ),
+    ).toEqual({ nodes: 1 });
+    expect(chunks).toEqual(['tfdoc:a']);
+  });
+
+  it('checks annotations in one batch and prefixes rule names', () => {
+    const rule = createPluginLintRule(plugin);
+    const issues = rule.checkAll([
+      { filePath: 'a.ts', line: 1, metadata: { owner: 'team' } },
+      { filePath: 'b.ts', line: 4, metadata: {} },
+    ]);
+    expect(issues).toEqual([
+      {
+        filePath: 'b.ts',
+        line: 4,
+        rule: 'terraform/owner-required',
+        message: 'Every annotation needs an owner',
+      },
+    ]);
+  });
+
+  it('merges enrichments into indexed entities', () => {
+    const dbManager = createDatabaseManager();
+    dbManager.initialize();
+    const id = dbManager.insertEntity({
+      filePath: 'main.tf',
+      name: 'queue',
+      entityType: 'service',
+      description: 'Queue',
+      language: 'terraform',
+      line: 1,
+      column: 0,
+      metadata: { type: 'service', description: 'Queue', status: 'stable' },
+      tags: ['infra'],
+    });
+
+    const entities = [dbManager.getEntityById(id)!];
+    expect(enrichWithPlugin(plugin, dbManager, entities)).toBe(1);
+    const enriched = dbManager.getEntityById(id)!;
+    expect(enriched.owner).toBe('platform');
+    expect(enriched.metadata).toEqual({
+      type: 'service',
+      description: 'Queue',
+      status: 'stable',
+      owner: 'platform',
+    });
+    expect([...enriched.tags].sort()).toEqual(['infra', 'terraform']);
+    expect(enriched.links).toHaveLength(1);
+
+    enrichWithPlugin(plugin, dbManager, [enriched]);
+    expect(dbManager.getEntityById(id)!.links).toHaveLength(1);
+    dbManager.close();
+  });
+
+  it('sends entities in the batches of the step', () => {
+    const dbManager = createDatabaseManager();
+    dbManager.initialize();
+    const entities = ['queue', 'topic', 'bucket'].map((name) => {
+      const id = dbManager.insertEntity({
+        filePath: 'main.tf',
+        name,
+        entityType: 'service',
+        description: name,
+        language: 'terraform',
+        line: 1,
+        column: 0,
+        metadata: { type: 'service', description: name },
+      });
+      return dbManager.getEntityById(id)!;
+    });
+
+    const calls = createEnrichmentCalls({ name: 'terraform', batchSize: 2 });
+    expect(enrichWithPlugin(plugin, dbManager, entities, calls)).toBe(3);
+    expect(calls.stats()).toEqual({ calls: 2, cached: 0 });
+    dbManager.close();
+  });
+
+  it('surfaces plugin errors, exits, and missing executables', () => {
+    expect(() => callPlugin(plugin, 'unknown' as never, {})).toThrow(
+      "Plugin 'terraform' unknown failed: no unknown",
+    );
+    expect(() => callPlugin(plugin, 'fail' as never, {})).toThrow(
+      'exited with 3: boom',
+    );
+    expect(() =>
+      callPlugin(
+        { ...plugin, command: [join(dir, 'missing')] },
+        'describe',
+        {},
+      ),
+    ).toThrow("Plugin 'terraform' describe failed");
+  });
+});
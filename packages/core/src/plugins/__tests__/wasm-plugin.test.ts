@@ -0,0 +1,177 @@
+import { describe, it, expect, beforeAll, afterAll } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { callPlugin, createPluginLintRule } from '../exec-plugin.js';
+import { runSandbox } from '../wasm-plugin.js';
+import type { PluginConfig } from '../types.js';
+
+interface ModuleOptions {
+  readonly response?: string;
+  readonly loop?: boolean;
+  readonly extraImport?: boolean;
+  readonly minPages?: number;
+}
+
+function leb(value: number): number[] {
+  const bytes: number[] = [];
+  let rest = value;
+  do {
+    let byte = rest & 0x7f;
+    rest >>>= 7;
+    if (rest !== 0) byte |= 0x80;
+    bytes.push(byte);
+  } while (rest !== 0);
+  return bytes;
+}
+
+function name(text: string): number[] {
+  const bytes = [...Buffer.from(text)];
+  return [...leb(bytes.length), ...bytes];
+}
+
+function section(id: number, bytes: readonly number[]): number[] {
+  return [id, ...leb(bytes.length), ...bytes];
+}
+
+/**
+ * A hand-assembled module that follows the plugin ABI: `knowgraph_handle`
+ * returns a pointer to a data segment holding `response`, or spins forever.
+ */
+function wasmModule(options: ModuleOptions = {}): Uint8Array {
+  const json = [...Buffer.from(options.response ?? '{"result":null}')];
+  const length = json.length;
+  const reply = [
+    length & 0xff,
+    (length >> 8) & 0xff,
+    (length >> 16) & 0xff,
+    (length >>> 24) & 0xff,
+    ...json,
+  ];
+  const minPages = leb(options.minPages ?? 1);
+  const imports = [
+    [...name('env'), ...name('memory'), 0x02, 0x00, ...minPages],
+  ];
+  if (options.extraImport) {
+    imports.push([...name('env'), ...name('now'), 0x00, 0x00]);
+  }
+  const first = options.extraImport ? 1 : 0;
+  const alloc = [0x00, 0x41, ...leb(1024), 0x0b];
+  const handle = options.loop
+    ? [0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x41, 0x00, 0x0b]
+    : [0x00, 0x41, 0x00, 0x0b];
+
+  return new Uint8Array([
+    0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+    ...section(1, [2, 0x60, 1, 0x7f, 1, 0x7f, 0x60, 2, 0x7f, 0x7f, 1, 0x7f]),
+    ...section(2, [...leb(imports.length), ...imports.flat()]),
+    ...section(3, [2, 0x00, 0x01]),
+    ...section(7, [
+      2,
+      ...name('knowgraph_alloc'),
+      0x00,
+      first,
+      ...name('knowgraph_handle'),
+      0x00,
+      first + 1,
+    ]),
+    ...section(10, [
+      2,
+      ...leb(alloc.length),
+      ...alloc,
+      ...leb(handle.length),
+      ...handle,
+    ]),
+    ...section(11, [1, 0x00, 0x41, 0x00, 0x0b, ...leb(reply.length), ...reply]),
+  ]);
+}
+
+describe('wasm plugins', () => {
+  let dir: string;
+
+  function plugin(file: string, options: ModuleOptions): PluginConfig {
+    const path = join(dir, file);
+    writeFileSync(path, wasmModule(options));
+    return { name: 'policy', wasm: path, rules: true, timeoutMs: 2000 };
+  }
+
+  beforeAll(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-wasm-'));
+  });
+
+  afterAll(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('returns the response the module writes to memory', () => {
+    const rule = createPluginLintRule(
+      plugin('lint.wasm', {
+        response: JSON.stringify({
+          result: {
+            issues: [
+              { filePath: 'a.ts', line: 1, rule: 'no-x', message: 'No x' },
+            ],
+          },
+        }),
+      }),
+    );
+    expect(
+      rule.checkAll([{ filePath: 'a.ts', line: 1, metadata: {} }]),
+    ).toEqual([
+      { filePath: 'a.ts', line: 1, rule: 'policy/no-x', message: 'No x' },
+    ]);
+  });
+
+  it('reports errors the module answers with', () => {
+    const errors = plugin('error.wasm', {
+      response: '{"error":{"message":"unsupported"}}',
+    });
+    expect(() => callPlugin(errors, 'describe', {})).toThrow(
+      "Plugin 'policy' describe failed: unsupported",
+    );
+  });
+
+  it('stops modules that run past the time limit', () => {
+    const looping = {
+      ...plugin('loop.wasm', { loop: true }),
+      timeoutMs: 200,
+    };
+    expect(() => callPlugin(looping, 'lint', {})).toThrow(
+      'did not finish within 200ms',
+    );
+  });
+
+  it('rejects modules that import anything but memory', () => {
+    expect(() =>
+      callPlugin(plugin('import.wasm', { extraImport: true }), 'lint', {}),
+    ).toThrow('module must import env.memory and nothing else');
+  });
+
+  it('rejects modules that need more memory than allowed', () => {
+    const hungry = {
+      ...plugin('memory.wasm', { minPages: 32 }),
+      memoryMb: 1,
+    };
+    expect(() => callPlugin(hungry, 'lint', {})).toThrow(
+      "Plugin 'policy' lint failed",
+    );
+  });
+
+  it('fails at once when the sandbox exits without replying', () => {
+    const started = Date.now();
+    expect(() =>
+      runSandbox('process.exit(3)', {}, 10_000, "Plugin 'policy' lint"),
+    ).toThrow(
+      "Plugin 'policy' lint failed: worker exited with code 3 without replying",
+    );
+    expect(Date.now() - started).toBeLessThan(5000);
+  });
+
+  it('fails at once when the sandbox crashes', () => {
+    const started = Date.now();
+    expect(() =>
+      runSandbox("throw new Error('boom')", {}, 10_000, "Plugin 'policy' lint"),
+    ).toThrow("Plugin 'policy' lint failed: boom");
+    expect(Date.now() - started).toBeLessThan(5000);
+  });
+});
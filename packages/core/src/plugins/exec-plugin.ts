@@ -0,0 +1,342 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Runs exec and WebAssembly plugins over a one-request JSON protocol
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [plugins, exec, protocol, json, extensibility]
+ * context:
+ *   business_goal: Let organizations extend knowgraph without forking it or growing the core
+ *   domain: plugins
+ */
+import { spawnSync } from 'node:child_process';
+import { z } from 'zod';
+import type { ParseResult } from '../types/parse-result.js';
+import {
+  EntityTypeSchema,
+  ExtendedMetadataSchema,
+  LinkSchema,
+} from '../types/entity.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { DatabaseManager } from '../indexer/database.js';
+import type { Exporter } from '../exporters/types.js';
+import type { Enricher, EnrichmentCalls } from '../enrichers/types.js';
+import { timePhase } from '../profiling/phase-timer.js';
+import type { Parser } from '../parsers/types.js';
+import type { BatchLintRule, LintIssue } from '../lint/types.js';
+import type {
+  PluginConfig,
+  PluginDescription,
+  PluginMethod,
+} from './types.js';
+import { runWasmPlugin } from './wasm-plugin.js';
+
+export const PLUGIN_PROTOCOL_VERSION = 1;
+
+const DEFAULT_TIMEOUT_MS = 30_000;
+const MAX_OUTPUT_BYTES = 64 * 1024 * 1024;
+
+const ResponseSchema = z.union([
+  z.object({ result: z.unknown() }),
+  z.object({ error: z.object({ message: z.string() }) }),
+]);
+
+const DescribeSchema = z.object({
+  name: z.string(),
+  version: z.string().optional(),
+  description: z.string().optional(),
+});
+
+const ExtractSchema = z.object({
+  entities: z.array(
+    z.object({
+      name: z.string(),
+      line: z.number().int().positive(),
+      column: z.number().int().nonnegative().default(0),
+      entityType: EntityTypeSchema,
+      metadata: ExtendedMetadataSchema,
+      signature: z.string().optional(),
+      parent: z.string().optional(),
+    }),
+  ),
+});
+
+const ExportSchema = z.object({ content: z.string() });
+
+const LintSchema = z.object({
+  issues: z.array(
+    z.object({
+      filePath: z.string(),
+      line: z.number().int().nonnegative(),
+      rule: z.string(),
+      message: z.string(),
+    }),
+  ),
+});
+
+const EnrichSchema = z.object({
+  entities: z.array(
+    z.object({
+      id: z.string(),
+      tags: z.array(z.string()).optional(),
+      links: z.array(LinkSchema).optional(),
+      metadata: z.record(z.unknown()).optional(),
+    }),
+  ),
+});
+
+function lastLine(text: string): string {
+  return text.trim().split('\n').at(-1) ?? '';
+}
+
+function runExecPlugin(
+  plugin: PluginConfig,
+  request: string,
+  prefix: string,
+): string {
+  const [executable, ...args] = plugin.command ?? [];
+  if (!executable) {
+    throw new Error(`${prefix} failed: no command configured`);
+  }
+  const run = spawnSync(executable, args, {
+    input: request,
+    encoding: 'utf-8',
+    cwd: plugin.cwd,
+    timeout: plugin.timeoutMs ?? DEFAULT_TIMEOUT_MS,
+    maxBuffer: MAX_OUTPUT_BYTES,
+  });
+
+  if (run.error) {
+    throw new Error(`${prefix} failed: ${run.error.message}`);
+  }
+  if (run.status !== 0) {
+    const detail = lastLine(run.stderr);
+    throw new Error(
+      `${prefix} exited with ${run.status ?? run.signal}${detail ? `: ${detail}` : ''}`,
+    );
+  }
+  return run.stdout;
+}
+
+/**
+ * Send `{ protocol, method, params }` to the plugin and return the `result`
+ * of its JSON response. Exec plugins are started once per call and read the
+ * request on stdin; `wasm` plugins get it through `knowgraph_handle` in a
+ * fresh sandbox. Either way plugins keep no state between calls. Throws
+ * when the plugin cannot start, times out, fails, answers with anything but
+ * a response, or answers with `{ error: { message } }`.
+ */
+export function callPlugin(
+  plugin: PluginConfig,
+  method: PluginMethod,
+  params: unknown,
+): unknown {
+  const request = JSON.stringify({
+    protocol: PLUGIN_PROTOCOL_VERSION,
+    method,
+    params,
+  });
+  const prefix = `Plugin '${plugin.name}' ${method}`;
+  const output = plugin.wasm
+    ? runWasmPlugin(plugin, request, prefix)
+    : runExecPlugin(plugin, request, prefix);
+
+  let response: z.infer<typeof ResponseSchema>;
+  try {
+    response = ResponseSchema.parse(JSON.parse(output));
+  } catch {
+    throw new Error(`${prefix} did not print a JSON response`);
+  }
+  if ('error' in response) {
+    throw new Error(`${prefix} failed: ${response.error.message}`);
+  }
+  return response.result;
+}
+
+function parseResult<T>(
+  plugin: PluginConfig,
+  method: PluginMethod,
+  schema: z.ZodType<T, z.ZodTypeDef, unknown>,
+  value: unknown,
+): T {
+  const parsed = schema.safeParse(value);
+  if (!parsed.success) {
+    const issue = parsed.error.issues[0];
+    throw new Error(
+      `Plugin '${plugin.name}' ${method} returned an invalid result: ` +
+        `${issue?.path.join('.')} ${issue?.message}`,
+    );
+  }
+  return parsed.data;
+}
+
+export function describePlugin(plugin: PluginConfig): PluginDescription {
+  return parseResult(
+    plugin,
+    'describe',
+    DescribeSchema,
+    callPlugin(plugin, 'describe', {}),
+  );
+}
+
+/**
+ * A parser for the plugin's `extensions`. Entity metadata is validated
+ * against the extended schema, so plugins cannot write what annotations
+ * could not. Register it after the built-in parsers, which keep priority.
+ */
+export function createPluginParser(plugin: PluginConfig): Parser {
+  return {
+    name: `plugin:${plugin.name}`,
+    supportedExtensions: plugin.extensions ?? [],
+    parse(content: string, filePath: string) {
+      const { entities } = parseResult(
+        plugin,
+        'extract',
+        ExtractSchema,
+        callPlugin(plugin, 'extract', { filePath, content }),
+      );
+      const results: ParseResult[] = entities.map((entity) => ({
+        ...entity,
+        filePath,
+        language: plugin.name,
+        rawDocstring: '',
+      }));
+      return { results, diagnostics: [] };
+    },
+  };
+}
+
+/** Render `entities` in one of the plugin's `formats`. */
+export function exportWithPlugin(
+  plugin: PluginConfig,
+  format: string,
+  entities: readonly StoredEntity[],
+): string {
+  return parseResult(
+    plugin,
+    'export',
+    ExportSchema,
+    callPlugin(plugin, 'export', { format, entities }),
+  ).content;
+}
+
+/** An exporter for one of the plugin's `formats`, sent localized entities. */
+export function createPluginExporter(
+  plugin: PluginConfig,
+  format: string,
+): Exporter {
+  return {
+    name: format,
+    description: `Rendered by plugin '${plugin.name}'`,
+    defaultOutput: `knowgraph-export.${format}`,
+    localized: true,
+    export(entities, sink, options) {
+      const all = timePhase(options.profiler, 'build', () => [...entities()]);
+      const content = timePhase(options.profiler, 'export', () =>
+        exportWithPlugin(plugin, format, all),
+      );
+      sink.write(content);
+      return { nodes: all.length };
+    },
+  };
+}
+
+/**
+ * A batch lint rule that sends every annotation to the plugin in one call.
+ * Rule names are prefixed with the plugin name.
+ */
+export function createPluginLintRule(plugin: PluginConfig): BatchLintRule {
+  return {
+    name: plugin.name,
+    description: `Policy rules from plugin '${plugin.name}'`,
+    checkAll(targets): readonly LintIssue[] {
+      const { issues } = parseResult(
+        plugin,
+        'lint',
+        LintSchema,
+        callPlugin(plugin, 'lint', { annotations: targets }),
+      );
+      return issues.map((issue) => ({
+        ...issue,
+        rule: `${plugin.name}/${issue.rule}`,
+      }));
+    },
+  };
+}
+
+/**
+ * Send `entities` to the plugin and merge what it returns into the index.
+ * Metadata keys are shallow-merged and must still pass the extended schema;
+ * tags and links are added when missing. Ids that were not sent are
+ * skipped. Every update is validated before any is written, so a bad reply
+ * changes nothing. With `calls`, entities go out in the step's batches and
+ * pace, and cached replies are reused. Returns how many entities were
+ * updated.
+ */
+export function enrichWithPlugin(
+  plugin: PluginConfig,
+  dbManager: DatabaseManager,
+  entities: readonly StoredEntity[],
+  calls?: EnrichmentCalls,
+): number {
+  const request = (batch: readonly StoredEntity[]) => {
+    const { entities: updates } = parseResult(
+      plugin,
+      'enrich',
+      EnrichSchema,
+      callPlugin(plugin, 'enrich', { entities: batch }),
+    );
+    const sent = new Set(batch.map((entity) => entity.id));
+    return new Map(
+      updates
+        .filter((update) => sent.has(update.id))
+        .map((update) => [update.id, update]),
+    );
+  };
+  const updates = calls ? calls.lookup(entities, request) : request(entities);
+
+  const planned = [...updates.values()].flatMap((update) => {
+    const entity = dbManager.getEntityById(update.id);
+    if (!entity) return [];
+    const metadata = parseResult(plugin, 'enrich', ExtendedMetadataSchema, {
+      ...entity.metadata,
+      ...update.metadata,
+    });
+    const tags = [...new Set(update.tags ?? [])].filter(
+      (tag) => !entity.tags.includes(tag),
+    );
+    const urls = new Set(entity.links.map((link) => link.url));
+    const links = (update.links ?? []).filter((link) => !urls.has(link.url));
+    return [{ entity, metadata, tags, links }];
+  });
+
+  for (const { entity, metadata, tags, links } of planned) {
+    dbManager.updateEntity(entity.id, {
+      metadata,
+      owner: metadata.owner,
+      status: metadata.status,
+      tags: [...entity.tags, ...tags],
+    });
+    dbManager.insertTags(entity.id, tags);
+    dbManager.insertLinks(entity.id, links);
+  }
+  return planned.length;
+}
+
+/** A pipeline step for a plugin with `enrich`, named after the plugin. */
+export function createPluginEnricher(plugin: PluginConfig): Enricher {
+  return {
+    name: plugin.name,
+    description: `Enrichments from plugin '${plugin.name}'`,
+    enrich: ({ dbManager, entities, calls }) =>
+      enrichWithPlugin(plugin, dbManager, entities, calls),
+  };
+}
+
+/** The plugin that renders `format`, if any. */
+export function findExportPlugin(
+  plugins: readonly PluginConfig[],
+  format: string,
+): PluginConfig | undefined {
+  return plugins.find((plugin) => plugin.formats?.includes(format));
+}
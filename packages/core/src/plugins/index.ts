@@ -0,0 +1,17 @@
+export type {
+  PluginMethod,
+  PluginConfig,
+  PluginDescription,
+} from './types.js';
+export {
+  PLUGIN_PROTOCOL_VERSION,
+  callPlugin,
+  describePlugin,
+  createPluginParser,
+  exportWithPlugin,
+  createPluginExporter,
+  createPluginLintRule,
+  findExportPlugin,
+  enrichWithPlugin,
+  createPluginEnricher,
+} from './exec-plugin.js';
@@ -0,0 +1,48 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for exec and WebAssembly plugins that add extractors, exporters, lint rules, and enrichers
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [plugins, extensibility, types, interface]
+ * context:
+ *   business_goal: Let plugins in any language describe what they add in one small config
+ *   domain: plugins
+ */
+
+export type PluginMethod =
+  | 'describe'
+  | 'extract'
+  | 'export'
+  | 'lint'
+  | 'enrich';
+
+export interface PluginConfig {
+  readonly name: string;
+  /** Executable and its arguments, started without a shell. */
+  readonly command?: readonly string[];
+  /**
+   * Path to a WebAssembly module, used instead of `command`. The module
+   * runs sandboxed: it can import only its memory.
+   */
+  readonly wasm?: string;
+  /** Memory for a `wasm` plugin, fixed at instantiation. Default 64. */
+  readonly memoryMb?: number;
+  /** File extensions the plugin extracts entities from, such as `.tf`. */
+  readonly extensions?: readonly string[];
+  /** Formats the plugin renders for `knowgraph export --format`. */
+  readonly formats?: readonly string[];
+  /** Whether the plugin checks annotations during `knowgraph lint`. */
+  readonly rules?: boolean;
+  /** Whether the plugin adds metadata, tags, and links after indexing. */
+  readonly enrich?: boolean;
+  /** Per-call limit; the process or worker is killed when it runs longer. */
+  readonly timeoutMs?: number;
+  readonly cwd?: string;
+}
+
+export interface PluginDescription {
+  readonly name: string;
+  readonly version?: string;
+  readonly description?: string;
+}
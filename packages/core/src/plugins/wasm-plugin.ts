@@ -0,0 +1,177 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Runs WebAssembly plugins in a worker with no host imports, capped memory, and a time limit
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [plugins, wasm, sandbox, worker, extensibility]
+ * context:
+ *   business_goal: Let teams share community policy rules and enrichers without trusting their code
+ *   domain: plugins
+ */
+import { readFileSync } from 'node:fs';
+import {
+  MessageChannel,
+  Worker,
+  receiveMessageOnPort,
+} from 'node:worker_threads';
+import type { PluginConfig } from './types.js';
+
+const DEFAULT_TIMEOUT_MS = 30_000;
+const DEFAULT_MEMORY_MB = 64;
+const PAGES_PER_MB = 16;
+// Heap for the worker's own JavaScript; module memory is allocated apart.
+const WORKER_HEAP_MB = 32;
+
+interface WorkerReply {
+  readonly output?: string;
+  readonly error?: string;
+}
+
+/**
+ * Evaluated in the sandbox worker. The module may import nothing but
+ * `env.memory`, so it has no clock, filesystem, network, or environment.
+ * The memory is created at its maximum size; `memory.grow` beyond it fails.
+ */
+const SANDBOX_SOURCE = `
+const { parentPort, workerData } = require('node:worker_threads');
+const { bytes, request, pages } = workerData;
+let reply;
+try {
+  const module = new WebAssembly.Module(bytes);
+  const imports = WebAssembly.Module.imports(module);
+  const memoryOnly = imports.length === 1 && imports[0].module === 'env' &&
+    imports[0].name === 'memory' && imports[0].kind === 'memory';
+  if (!memoryOnly) {
+    throw new Error('module must import env.memory and nothing else');
+  }
+  const memory = new WebAssembly.Memory({ initial: pages, maximum: pages });
+  const { exports } = new WebAssembly.Instance(module, { env: { memory } });
+  if (typeof exports.knowgraph_alloc !== 'function' ||
+      typeof exports.knowgraph_handle !== 'function') {
+    throw new Error('module must export knowgraph_alloc and knowgraph_handle');
+  }
+  const input = Buffer.from(request, 'utf-8');
+  const ptr = exports.knowgraph_alloc(input.length) >>> 0;
+  new Uint8Array(memory.buffer, ptr, input.length).set(input);
+  const out = exports.knowgraph_handle(ptr, input.length) >>> 0;
+  const length = new DataView(memory.buffer).getUint32(out, true);
+  const output = Buffer.from(memory.buffer, out + 4, length).toString('utf-8');
+  reply = { output };
+} catch (err) {
+  reply = { error: err instanceof Error ? err.message : String(err) };
+}
+parentPort.postMessage(reply);
+`;
+
+/**
+ * Evaluated in the worker the caller waits on. It runs the sandbox and
+ * relays its one reply, and since its own event loop keeps running while
+ * the caller blocks, a sandbox that crashes or exits without replying is
+ * reported at once instead of when the time limit runs out.
+ */
+const SUPERVISOR_SOURCE = `
+const { Worker, workerData } = require('node:worker_threads');
+const { source, heapMb, port, signal, ...data } = workerData;
+let replied = false;
+function reply(message) {
+  if (replied) return;
+  replied = true;
+  port.postMessage(message);
+  Atomics.store(signal, 0, 1);
+  Atomics.notify(signal, 0);
+}
+const sandbox = new Worker(source, {
+  eval: true,
+  workerData: data,
+  resourceLimits: { maxOldGenerationSizeMb: heapMb },
+});
+sandbox.on('message', reply);
+sandbox.on('error', (err) => reply({ error: err.message }));
+sandbox.on('exit', (code) => {
+  reply({ error: 'worker exited with code ' + code + ' without replying' });
+});
+`;
+
+/**
+ * Run `source` in a sandbox worker with `data` as its `workerData` and
+ * return the output of the one reply it posts. The caller blocks until
+ * then: a sandbox still running once `timeoutMs` passes is terminated,
+ * and one that crashes or exits without replying fails as soon as it
+ * does.
+ */
+export function runSandbox(
+  source: string,
+  data: Readonly<Record<string, unknown>>,
+  timeoutMs: number,
+  prefix: string,
+): string {
+  const signal = new Int32Array(new SharedArrayBuffer(4));
+  const { port1, port2 } = new MessageChannel();
+  const worker = new Worker(SUPERVISOR_SOURCE, {
+    eval: true,
+    workerData: {
+      ...data,
+      source,
+      heapMb: WORKER_HEAP_MB,
+      port: port2,
+      signal,
+    },
+    transferList: [port2],
+    resourceLimits: { maxOldGenerationSizeMb: WORKER_HEAP_MB },
+  });
+  // The supervisor reports the sandbox's failures; its own can only time out.
+  worker.on('error', () => undefined);
+
+  try {
+    const status = Atomics.wait(signal, 0, 0, timeoutMs);
+    const reply = receiveMessageOnPort(port1)?.message as
+      | WorkerReply
+      | undefined;
+    if (status === 'timed-out' || !reply) {
+      throw new Error(`${prefix} did not finish within ${timeoutMs}ms`);
+    }
+    if (reply.error !== undefined) {
+      throw new Error(`${prefix} failed: ${reply.error}`);
+    }
+    return reply.output ?? '';
+  } finally {
+    port1.close();
+    void worker.terminate();
+  }
+}
+
+/**
+ * Run one request through the plugin's `wasm` module and return the text
+ * it wrote back. The module copies the request into memory from
+ * `knowgraph_alloc(len)` and `knowgraph_handle(ptr, len)` returns a pointer
+ * to a little-endian u32 length followed by that many bytes of UTF-8.
+ *
+ * A module that loops forever is terminated once `timeoutMs` passes; one
+ * that needs more than `memoryMb` fails to instantiate.
+ */
+export function runWasmPlugin(
+  plugin: PluginConfig,
+  request: string,
+  prefix: string,
+): string {
+  if (!plugin.wasm) {
+    throw new Error(`${prefix} failed: no wasm module configured`);
+  }
+  let bytes: Buffer;
+  try {
+    bytes = readFileSync(plugin.wasm);
+  } catch (err) {
+    throw new Error(
+      `${prefix} failed: ${err instanceof Error ? err.message : String(err)}`,
+    );
+  }
+
+  const memoryMb = plugin.memoryMb ?? DEFAULT_MEMORY_MB;
+  return runSandbox(
+    SANDBOX_SOURCE,
+    { bytes, request, pages: memoryMb * PAGES_PER_MB },
+    plugin.timeoutMs ?? DEFAULT_TIMEOUT_MS,
+    prefix,
+  );
+}
@@ -0,0 +1,51 @@
+import { describe, it, expect } from 'vitest';
+import { createPhaseTimer, timePhase } from '../phase-timer.js';
+
+function fakeClock(): { readonly now: () => number; tick(ms: number): void } {
+  let current = 0;
+  return {
+    now: () => current,
+    tick(ms: number) {
+      current += ms;
+    },
+  };
+}
+
+describe('createPhaseTimer', () => {
+  it('sums durations per phase in pipeline order', () => {
+    const clock = fakeClock();
+    const timer = createPhaseTimer(clock.now);
+
+    timer.time('parse', () => clock.tick(5));
+    timer.time('walk', () => clock.tick(2));
+    const value = timer.time('parse', () => {
+      clock.tick(3);
+      return 'done';
+    });
+
+    expect(value).toBe('done');
+    expect(timer.timings()).toEqual([
+      { phase: 'walk', ms: 2, calls: 1 },
+      { phase: 'parse', ms: 8, calls: 2 },
+    ]);
+  });
+
+  it('records time when the phase throws and ignores nested calls', () => {
+    const clock = fakeClock();
+    const timer = createPhaseTimer(clock.now);
+
+    expect(() =>
+      timer.time('bind', () => {
+        timer.time('bind', () => clock.tick(4));
+        throw new Error('insert failed');
+      }),
+    ).toThrow('insert failed');
+    expect(timer.timings()).toEqual([{ phase: 'bind', ms: 4, calls: 1 }]);
+  });
+});
+
+describe('timePhase', () => {
+  it('runs the function without a timer', () => {
+    expect(timePhase(undefined, 'export', () => 42)).toBe(42);
+  });
+});
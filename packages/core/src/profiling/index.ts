@@ -0,0 +1,6 @@
+export type { ProfilePhase, PhaseTiming, PhaseTimer } from './types.js';
+export {
+  PROFILE_PHASES,
+  createPhaseTimer,
+  timePhase,
+} from './phase-timer.js';
@@ -0,0 +1,69 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Accumulates wall-clock time per pipeline phase (walk, parse, bind, build, export)
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [profiling, performance, timing]
+ * context:
+ *   business_goal: Let users report slow scans with data that points at the slow phase
+ *   domain: profiling
+ */
+import { performance } from 'node:perf_hooks';
+import type { PhaseTimer, PhaseTiming, ProfilePhase } from './types.js';
+
+export const PROFILE_PHASES: readonly ProfilePhase[] = [
+  'walk',
+  'parse',
+  'bind',
+  'enrich',
+  'build',
+  'export',
+];
+
+/**
+ * Create a timer that sums durations per phase. Nested calls for the same
+ * phase are counted once, by the outermost call.
+ */
+export function createPhaseTimer(
+  now: () => number = () => performance.now(),
+): PhaseTimer {
+  const totals = new Map<ProfilePhase, { ms: number; calls: number }>();
+  const active = new Set<ProfilePhase>();
+
+  function time<T>(phase: ProfilePhase, fn: () => T): T {
+    if (active.has(phase)) return fn();
+    active.add(phase);
+    const start = now();
+    try {
+      return fn();
+    } finally {
+      active.delete(phase);
+      const total = totals.get(phase) ?? { ms: 0, calls: 0 };
+      totals.set(phase, {
+        ms: total.ms + (now() - start),
+        calls: total.calls + 1,
+      });
+    }
+  }
+
+  function timings(): readonly PhaseTiming[] {
+    return PROFILE_PHASES.flatMap((phase) => {
+      const total = totals.get(phase);
+      return total ? [{ phase, ...total }] : [];
+    });
+  }
+
+  return { time, timings };
+}
+
+/**
+ * Time `fn` when a timer is given; otherwise just run it.
+ */
+export function timePhase<T>(
+  timer: PhaseTimer | undefined,
+  phase: ProfilePhase,
+  fn: () => T,
+): T {
+  return timer ? timer.time(phase, fn) : fn();
+}
@@ -0,0 +1,37 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for per-phase timing of scans and exports
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [profiling, performance, timing, types, interface]
+ * context:
+ *   business_goal: Keep phase timings comparable between scans and between releases
+ *   domain: profiling
+ */
+
+/**
+ * Pipeline phases: finding and reading files, parsing annotations, storing
+ * entities and relationships, building the graph, and writing output.
+ */
+export type ProfilePhase =
+  | 'walk'
+  | 'parse'
+  | 'bind'
+  | 'enrich'
+  | 'build'
+  | 'export';
+
+export interface PhaseTiming {
+  readonly phase: ProfilePhase;
+  /** Total wall-clock milliseconds spent in the phase. */
+  readonly ms: number;
+  readonly calls: number;
+}
+
+export interface PhaseTimer {
+  /** Run `fn`, adding its duration to `phase` even when it throws. */
+  time<T>(phase: ProfilePhase, fn: () => T): T;
+  /** Recorded phases in pipeline order. */
+  timings(): readonly PhaseTiming[];
+}
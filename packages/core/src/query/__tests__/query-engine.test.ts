@@ -87,6 +87,29 @@ describe('QueryEngine', () => {
       expect(result.entities[0].name).toBe('MyClass');
     });
 
+    it('filters by any of several entity types', () => {
+      dbManager.insertEntity(makeEntity({
+        name: 'MyClass',
+        line: 1,
+        entityType: 'class',
+        metadata: { type: 'class', description: 'A class' },
+      }));
+      dbManager.insertEntity(makeEntity({
+        name: 'MyEnum',
+        line: 2,
+        entityType: 'enum',
+        metadata: { type: 'enum', description: 'An enum' },
+      }));
+      dbManager.insertEntity(makeEntity({ name: 'myFunc', line: 3 }));
+
+      const result = queryEngine.search({ types: ['class', 'enum'] });
+      expect(result.entities.map((e) => e.name).sort()).toEqual([
+        'MyClass',
+        'MyEnum',
+      ]);
+      expect(result.total).toBe(2);
+    });
+
     it('filters by owner', () => {
       dbManager.insertEntity(makeEntity({ name: 'func1', line: 1, owner: 'team-a' }));
       dbManager.insertEntity(makeEntity({ name: 'func2', line: 2, owner: 'team-b' }));
@@ -123,6 +146,76 @@ describe('QueryEngine', () => {
       expect(result.entities[0].name).toBe('func1');
     });
 
+    it('filters by git contributor', () => {
+      const git = (author: string) => ({
+        type: 'function' as const,
+        description: 'A test function',
+        git: {
+          last_commit: 'abc',
+          last_author: author,
+          last_modified: '2026-03-01T09:00:00+00:00',
+          contributors: [
+            { author, commits: 2, last_modified: '2026-03-01T09:00:00+00:00' },
+          ],
+        },
+      });
+      dbManager.insertEntity(makeEntity({ name: 'func1', line: 1, metadata: git('al@example.com') }));
+      dbManager.insertEntity(makeEntity({ name: 'func2', line: 2, metadata: git('bo@example.com') }));
+      dbManager.insertEntity(makeEntity({ name: 'func3', line: 3 }));
+
+      const result = queryEngine.search({ contributor: 'bo@example.com' });
+      expect(result.entities.map((e) => e.name)).toEqual(['func2']);
+    });
+
+    it('filters by the owner on a date and returns entities as of then', () => {
+      const reorg = {
+        type: 'service' as const,
+        description: 'Ledger service',
+        owner: 'finance',
+        owners: [{ owner: 'payments', valid_until: '2026-04-01' }],
+        dependencies: {
+          databases: ['oracle', 'postgres'],
+          validity: {
+            oracle: { valid_until: '2026-06-30' },
+            postgres: { valid_from: '2026-06-30' },
+          },
+        },
+      };
+      dbManager.insertEntity(makeEntity({ name: 'ledger', line: 1, owner: 'finance', metadata: reorg }));
+      dbManager.insertEntity(makeEntity({ name: 'func2', line: 2, owner: 'payments' }));
+
+      const before = queryEngine.search({ owner: 'payments', asOf: '2026-03-31' });
+      expect(before.entities.map((e) => e.name)).toEqual(['func2', 'ledger']);
+      expect(before.entities[1].owner).toBe('payments');
+      expect(before.entities[1].metadata).toMatchObject({
+        owner: 'payments',
+        dependencies: { databases: ['oracle'] },
+      });
+
+      const after = queryEngine.search({ owner: 'payments', asOf: '2026-04-01' });
+      expect(after.entities.map((e) => e.name)).toEqual(['func2']);
+      expect(queryEngine.search({ owner: 'payments' }).total).toBe(1);
+    });
+
+    it('filters by path and tag scopes', () => {
+      dbManager.insertEntity(makeEntity({ name: 'charge', line: 1, filePath: 'services/auth/pay.ts', tags: ['payments'] }));
+      dbManager.insertEntity(makeEntity({ name: 'login', line: 2, filePath: 'services/auth/login.ts', tags: ['auth'] }));
+      dbManager.insertEntity(makeEntity({ name: 'refund', line: 1, filePath: 'services/billing/refund.ts', tags: ['payments'] }));
+      dbManager.insertEntity(makeEntity({ name: 'lookalike', line: 1, filePath: 'services/authz/x.ts', tags: ['payments'] }));
+
+      const byPath = queryEngine.search({ scopes: [{ kind: 'path', path: 'services/auth' }] });
+      expect(byPath.entities.map((e) => e.name)).toEqual(['charge', 'login']);
+
+      const both = queryEngine.search({
+        scopes: [
+          { kind: 'path', path: 'services/auth' },
+          { kind: 'path', path: 'services/billing' },
+          { kind: 'tag', tag: 'payments' },
+        ],
+      });
+      expect(both.entities.map((e) => e.name)).toEqual(['charge', 'refund']);
+    });
+
     it('combines multiple filters', () => {
       dbManager.insertEntity(makeEntity({
         name: 'authLogin',
@@ -250,6 +343,31 @@ describe('QueryEngine', () => {
     });
   });
 
+  describe('getAll', () => {
+    it('returns every entity ordered by file path and line', () => {
+      dbManager.insertEntity(makeEntity({ name: 'b', filePath: 'src/b.ts', line: 1 }));
+      dbManager.insertEntity(makeEntity({ name: 'a2', filePath: 'src/a.ts', line: 9, tags: ['x'] }));
+      dbManager.insertEntity(makeEntity({ name: 'a1', filePath: 'src/a.ts', line: 2 }));
+
+      const entities = queryEngine.getAll();
+      expect(entities.map((e) => e.name)).toEqual(['a1', 'a2', 'b']);
+      expect(entities[1].tags).toEqual(['x']);
+    });
+
+    it('returns empty array for an empty index', () => {
+      expect(queryEngine.getAll()).toHaveLength(0);
+    });
+  });
+
+  describe('iterateAll', () => {
+    it('yields entities in the same order as getAll', () => {
+      dbManager.insertEntity(makeEntity({ name: 'b', filePath: 'src/b.ts', line: 1 }));
+      dbManager.insertEntity(makeEntity({ name: 'a', filePath: 'src/a.ts', line: 3, tags: ['x'] }));
+
+      expect([...queryEngine.iterateAll()]).toEqual(queryEngine.getAll());
+    });
+  });
+
   describe('getStats', () => {
     it('returns accurate statistics', () => {
       const id1 = dbManager.insertEntity(makeEntity({ name: 'f1', line: 1, tags: ['a'] }));
@@ -0,0 +1,62 @@
+import { describe, it, expect } from 'vitest';
+import type { QueryEngine, QueryOptions } from '../query-engine.js';
+import { runSavedQuery, savedQueryOptions } from '../saved-queries.js';
+
+function recordingEngine(searches: QueryOptions[]): QueryEngine {
+  return {
+    search: (options) => {
+      searches.push(options);
+      return { entities: [], total: 0, query: options };
+    },
+  } as unknown as QueryEngine;
+}
+
+describe('savedQueryOptions', () => {
+  it('keeps the search fields and leaves out the description', () => {
+    expect(
+      savedQueryOptions({
+        description: 'Services that handle personal data',
+        query: 'payment',
+        type: 'service',
+        tags: ['gdpr', 'critical'],
+      }),
+    ).toEqual({ query: 'payment', type: 'service', tags: ['gdpr', 'critical'] });
+  });
+});
+
+describe('runSavedQuery', () => {
+  const queries = {
+    'critical-gdpr-paths': { tags: ['gdpr', 'critical'] },
+    'billing-services': { type: 'service' as const, owner: 'billing', limit: 5 },
+  };
+
+  it('searches for every match unless the query saves a limit', () => {
+    const searches: QueryOptions[] = [];
+    const engine = recordingEngine(searches);
+    runSavedQuery(engine, queries, 'critical-gdpr-paths');
+    runSavedQuery(engine, queries, 'billing-services');
+    expect(searches).toEqual([
+      { limit: Number.MAX_SAFE_INTEGER, tags: ['gdpr', 'critical'] },
+      { limit: 5, type: 'service', owner: 'billing' },
+    ]);
+  });
+
+  it('lets overrides replace the saved options', () => {
+    const searches: QueryOptions[] = [];
+    runSavedQuery(recordingEngine(searches), queries, 'billing-services', {
+      owner: 'payments',
+      limit: 20,
+    });
+    expect(searches).toEqual([
+      { limit: 20, type: 'service', owner: 'payments' },
+    ]);
+  });
+
+  it('names the saved queries when the name is unknown', () => {
+    expect(() =>
+      runSavedQuery(recordingEngine([]), queries, 'constructor'),
+    ).toThrow(
+      "Unknown saved query 'constructor'. Available: critical-gdpr-paths, billing-services",
+    );
+  });
+});
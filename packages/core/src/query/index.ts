@@ -1,2 +1,3 @@
 export { createQueryEngine } from './query-engine.js';
 export type { QueryEngine, QueryOptions, QueryResult } from './query-engine.js';
+export { runSavedQuery, savedQueryOptions } from './saved-queries.js';
@@ -9,17 +9,30 @@
  *   business_goal: Enable fast, flexible search across the code knowledge graph
  *   domain: query-engine
  */
+import { selectAsOf } from '../graph/graph-validity.js';
 import type { DatabaseManager } from '../indexer/database.js';
 import type { EntityType, Link, Status } from '../types/index.js';
 import type { IndexStats, StoredEntity } from '../indexer/types.js';
+import type { ScanScope } from '../scope/types.js';
 
 export interface QueryOptions {
   readonly query?: string;
   readonly type?: EntityType;
+  /** Any of these types, on top of `type`; every type when empty. */
+  readonly types?: readonly EntityType[];
   readonly owner?: string;
   readonly status?: Status;
   readonly tags?: readonly string[];
   readonly filePath?: string;
+  /** Author among the git enricher's top contributors. */
+  readonly contributor?: string;
+  /** Restrict results to these scopes, matched as `entityInScope` does. */
+  readonly scopes?: readonly ScanScope[];
+  /**
+   * Search the graph as it stood on this `YYYY-MM-DD` date: `owner`
+   * matches the owner then, and results come back as `selectAsOf` gives.
+   */
+  readonly asOf?: string;
   readonly limit?: number;
   readonly offset?: number;
 }
@@ -37,6 +50,9 @@ export interface QueryEngine {
   getDependents(entityId: string): readonly StoredEntity[];
   getByOwner(owner: string): readonly StoredEntity[];
   getByTag(tag: string): readonly StoredEntity[];
+  getAll(): readonly StoredEntity[];
+  /** Same order as `getAll`, reading rows one at a time. */
+  iterateAll(): IterableIterator<StoredEntity>;
   getStats(): IndexStats;
 }
 
@@ -130,10 +146,14 @@ export function createQueryEngine(dbManager: DatabaseManager): QueryEngine {
     const {
       query,
       type,
+      types = [],
       owner,
       status,
       tags,
       filePath,
+      contributor,
+      scopes = [],
+      asOf,
       limit = 50,
       offset = 0,
     } = options;
@@ -153,7 +173,22 @@ export function createQueryEngine(dbManager: DatabaseManager): QueryEngine {
       params.type = type;
     }
 
-    if (owner) {
+    if (types.length > 0) {
+      const typePlaceholders = types.map((_, i) => `@type${i}`);
+      conditions.push(`e.entity_type IN (${typePlaceholders.join(', ')})`);
+      types.forEach((value, i) => {
+        params[`type${i}`] = value;
+      });
+    }
+
+    if (owner && asOf) {
+      // The first dated owner whose window holds the date, else the owner
+      conditions.push(
+        `COALESCE((SELECT json_extract(o.value, '$.owner') FROM json_each(e.metadata_json, '$.owners') o WHERE COALESCE(json_extract(o.value, '$.valid_from'), '') <= @asOf AND (json_extract(o.value, '$.valid_until') IS NULL OR @asOf < json_extract(o.value, '$.valid_until')) ORDER BY o.key LIMIT 1), e.owner) = @owner`,
+      );
+      params.owner = owner;
+      params.asOf = asOf;
+    } else if (owner) {
       conditions.push('e.owner = @owner');
       params.owner = owner;
     }
@@ -168,6 +203,13 @@ export function createQueryEngine(dbManager: DatabaseManager): QueryEngine {
       params.filePath = filePath;
     }
 
+    if (contributor) {
+      conditions.push(
+        `EXISTS (SELECT 1 FROM json_each(e.metadata_json, '$.git.contributors') c WHERE json_extract(c.value, '$.author') = @contributor)`,
+      );
+      params.contributor = contributor;
+    }
+
     if (tags && tags.length > 0) {
       const tagPlaceholders = tags.map((_, i) => `@tag${i}`);
       conditions.push(
@@ -178,18 +220,46 @@ export function createQueryEngine(dbManager: DatabaseManager): QueryEngine {
       }
     }
 
+    const pathScopes: string[] = [];
+    const tagScopes: string[] = [];
+    scopes.forEach((scope, i) => {
+      if (scope.kind === 'tag') {
+        tagScopes.push(`@scope${i}`);
+        params[`scope${i}`] = scope.tag;
+      } else if (scope.path) {
+        // Not LIKE, which ignores case and treats _ as a wildcard
+        pathScopes.push(
+          `e.file_path = @scope${i} OR substr(e.file_path, 1, length(@scope${i}) + 1) = @scope${i} || '/'`,
+        );
+        params[`scope${i}`] = scope.path;
+      } else {
+        pathScopes.push('1 = 1');
+      }
+    });
+    if (pathScopes.length > 0) {
+      conditions.push(`(${pathScopes.join(' OR ')})`);
+    }
+    if (tagScopes.length > 0) {
+      conditions.push(
+        `e.id IN (SELECT entity_id FROM tags WHERE tag IN (${tagScopes.join(', ')}))`,
+      );
+    }
+
     const whereClause =
       conditions.length > 0 ? `WHERE ${conditions.join(' AND ')}` : '';
 
     const countSql = `SELECT COUNT(*) as count FROM entities e ${whereClause}`;
     const total = (db.prepare(countSql).get(params) as CountRow).count;
 
-    const dataSql = `SELECT e.* FROM entities e ${whereClause} ORDER BY e.name ASC LIMIT @limit OFFSET @offset`;
+    const dataSql = `SELECT e.* FROM entities e ${whereClause} ORDER BY e.name ASC, e.file_path ASC, e.line ASC, e.id ASC LIMIT @limit OFFSET @offset`;
     params.limit = limit;
     params.offset = offset;
     const rows = db.prepare(dataSql).all(params) as readonly EntityRow[];
 
-    const entities = rows.map((row) => hydrateEntity(db, row));
+    const entities = rows.map((row) => {
+      const entity = hydrateEntity(db, row);
+      return asOf ? selectAsOf(entity, asOf) : entity;
+    });
 
     return { entities, total, query: options };
   }
@@ -238,17 +308,41 @@ export function createQueryEngine(dbManager: DatabaseManager): QueryEngine {
     return rows.map((row) => hydrateEntity(db, row));
   }
 
+  const ALL_ENTITIES_SQL =
+    'SELECT * FROM entities ' +
+    'ORDER BY file_path ASC, line ASC, column_num ASC, id ASC';
+
+  function getAll(): readonly StoredEntity[] {
+    const rows = db.prepare(ALL_ENTITIES_SQL).all() as readonly EntityRow[];
+    return rows.map((row) => hydrateEntity(db, row));
+  }
+
+  // The open statement holds its read transaction until the last row, so
+  // rows hydrated along the way come from the same committed state
+  function* iterateAll(): IterableIterator<StoredEntity> {
+    const rows = db
+      .prepare(ALL_ENTITIES_SQL)
+      .iterate() as IterableIterator<EntityRow>;
+    for (const row of rows) {
+      yield hydrateEntity(db, row);
+    }
+  }
+
   function getStats(): IndexStats {
     return dbManager.getStats();
   }
 
+  // Each lookup reads one committed state, never part of a re-index
+  const { snapshot } = dbManager;
   return {
-    search,
-    getEntity,
-    getDependencies,
-    getDependents,
-    getByOwner,
-    getByTag,
-    getStats,
+    search: (options) => snapshot(() => search(options)),
+    getEntity: (id) => snapshot(() => getEntity(id)),
+    getDependencies: (entityId) => snapshot(() => getDependencies(entityId)),
+    getDependents: (entityId) => snapshot(() => getDependents(entityId)),
+    getByOwner: (owner) => snapshot(() => getByOwner(owner)),
+    getByTag: (tag) => snapshot(() => getByTag(tag)),
+    getAll: () => snapshot(getAll),
+    iterateAll,
+    getStats: () => snapshot(getStats),
   };
 }
@@ -0,0 +1,52 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Runs the named searches saved in the manifest or the registry against the query engine
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [query, search, saved-queries]
+ * context:
+ *   business_goal: Let teams name the searches they run often and share them across the CLI, reports, and dashboards
+ *   domain: query-engine
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type { SavedQueryConfig } from '../types/manifest-workflows.js';
+import type { QueryEngine, QueryOptions, QueryResult } from './query-engine.js';
+
+/** The search options a saved query stands for. */
+export function savedQueryOptions(saved: SavedQueryConfig): QueryOptions {
+  return {
+    ...(saved.query !== undefined ? { query: saved.query } : {}),
+    ...(saved.type !== undefined ? { type: saved.type } : {}),
+    ...(saved.owner !== undefined ? { owner: saved.owner } : {}),
+    ...(saved.tags !== undefined ? { tags: saved.tags } : {}),
+    ...(saved.limit !== undefined ? { limit: saved.limit } : {}),
+  };
+}
+
+/**
+ * Run the query saved as `name` in `queries`, with any `overrides` in
+ * place of its own options. Without a limit every match is returned.
+ * Throws a usage error naming the saved queries when there is none by
+ * that name.
+ */
+export function runSavedQuery(
+  engine: QueryEngine,
+  queries: Readonly<Record<string, SavedQueryConfig>>,
+  name: string,
+  overrides: QueryOptions = {},
+): QueryResult {
+  const saved = Object.hasOwn(queries, name) ? queries[name] : undefined;
+  if (!saved) {
+    const names = Object.keys(queries).join(', ') || 'none';
+    throw createKnowgraphError(
+      'usage',
+      `Unknown saved query '${name}'. Available: ${names}`,
+    );
+  }
+  return engine.search({
+    limit: Number.MAX_SAFE_INTEGER,
+    ...savedQueryOptions(saved),
+    ...overrides,
+  });
+}
@@ -0,0 +1,168 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  REDACTED,
+  createRedactor,
+  hashValue,
+  previewRedaction,
+  resolveRedactionProfile,
+} from '../redaction.js';
+
+function makeEntity(): StoredEntity {
+  return {
+    id: 'id-payments',
+    filePath: 'src/payments.ts',
+    name: 'Payments',
+    entityType: 'service',
+    description: 'Calls ledger.corp for settlement',
+    rawDocstring: '@knowgraph owner: jane@example.com',
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'jane@example.com',
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: 'Calls ledger.corp for settlement',
+      owner: 'jane@example.com',
+      compliance: { regulations: ['PCI-DSS'] },
+      links: [{ type: 'dashboard', url: 'https://grafana.internal/d/pay' }],
+    },
+    tags: ['payments'],
+    links: [{ type: 'dashboard', url: 'https://grafana.internal/d/pay' }],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+describe('createRedactor', () => {
+  it('applies the built-in vendor profile', () => {
+    const redact = createRedactor({
+      ...resolveRedactionProfile('vendor'),
+      salt: 'pepper',
+    });
+    const entity = redact(makeEntity());
+    const metadata = entity.metadata as Record<string, unknown>;
+
+    expect(entity.owner).toBe(hashValue('jane@example.com', 'pepper'));
+    expect(metadata.owner).toBe(entity.owner);
+    expect(metadata.compliance).toBeUndefined();
+    expect(entity.rawDocstring).toBeNull();
+    expect(entity.description).toBe(`Calls ${REDACTED} for settlement`);
+    expect(entity.links[0]?.url).toBe(`https://${REDACTED}/d/pay`);
+    expect(entity).toMatchObject({
+      id: 'id-payments',
+      name: 'Payments',
+      entityType: 'service',
+    });
+  });
+
+  it('strips and hashes nested fields by path', () => {
+    const redact = createRedactor({
+      name: 'partner',
+      salt: 'pepper',
+      rules: [
+        { field: 'links.url', action: 'hash' },
+        { field: 'compliance.regulations', action: 'strip' },
+        { field: 'file_path', action: 'strip' },
+      ],
+    });
+    const entity = redact(makeEntity());
+    const metadata = entity.metadata as Record<string, unknown>;
+
+    const url = hashValue('https://grafana.internal/d/pay', 'pepper');
+    expect(entity.links).toEqual([{ type: 'dashboard', url }]);
+    expect(metadata.links).toEqual([{ type: 'dashboard', url }]);
+    expect(metadata.compliance).toEqual({});
+    expect(entity.filePath).toBe(REDACTED);
+  });
+
+  it('salts hashes and rejects bad rules', () => {
+    expect(hashValue('a', 'x')).not.toBe(hashValue('a', 'y'));
+    expect(() => createRedactor(resolveRedactionProfile('vendor'))).toThrow(
+      "Redaction profile 'vendor' hashes values and needs a salt: set KNOWGRAPH_REDACTION_SALT",
+    );
+    expect(() =>
+      createRedactor({ name: 'x', rules: [{ field: '*', action: 'strip' }] }),
+    ).toThrow(/needs a pattern/);
+    expect(() =>
+      createRedactor({
+        name: 'x',
+        rules: [{ field: 'owner', action: 'strip', pattern: '(' }],
+      }),
+    ).toThrow(/Invalid pattern/);
+  });
+});
+
+describe('resolveRedactionProfile', () => {
+  it('prefers custom profiles and lists the available ones', () => {
+    const custom = { vendor: { name: 'vendor', rules: [] } };
+    expect(resolveRedactionProfile('vendor', custom).rules).toEqual([]);
+    expect(() =>
+      resolveRedactionProfile('nope', { mine: custom.vendor }),
+    ).toThrow("Unknown redaction profile 'nope'. Available: mine, vendor");
+  });
+});
+
+describe('previewRedaction', () => {
+  it('counts the values and paths each rule redacted', () => {
+    const clean: StoredEntity = {
+      ...makeEntity(),
+      id: 'id-search',
+      name: 'Search',
+      description: 'Full-text search',
+      rawDocstring: null,
+      owner: null,
+      metadata: { type: 'service', description: 'Full-text search' },
+      links: [],
+    };
+    const preview = previewRedaction([makeEntity(), clean], {
+      ...resolveRedactionProfile('vendor'),
+      salt: 'pepper',
+    });
+
+    expect(preview.profile).toBe('vendor');
+    expect(preview.entities).toBe(2);
+    expect(preview.redactedEntities).toBe(1);
+    expect(
+      preview.rules.map((r) => [r.rule.field, r.entities, r.values]),
+    ).toEqual([
+      ['raw_docstring', 1, 1],
+      ['compliance', 1, 1],
+      ['*', 1, 2],
+      ['*', 1, 4],
+    ]);
+    expect(preview.rules[3].paths).toEqual([
+      'description',
+      'links[].url',
+      'metadata.description',
+      'metadata.links[].url',
+    ]);
+    expect(preview.values[0]).toEqual({
+      entityId: 'id-payments',
+      entity: 'Payments',
+      rule: 0,
+      path: 'rawDocstring',
+      before: '@knowgraph owner: jane@example.com',
+      after: null,
+    });
+  });
+
+  it('reports each value with what the earlier rules left', () => {
+    const preview = previewRedaction([makeEntity()], {
+      name: 'owners',
+      rules: [
+        { field: 'owner', action: 'hash' },
+        { field: '*', action: 'strip', pattern: 'sha256:\\w+' },
+      ],
+    });
+    const owner = preview.values.filter((v) => v.path === 'owner');
+    expect(owner.map((v) => [v.rule, v.after])).toEqual([
+      [0, hashValue('jane@example.com')],
+      [1, REDACTED],
+    ]);
+  });
+});
@@ -0,0 +1,17 @@
+export type {
+  RedactionAction,
+  RedactionRule,
+  RedactionProfile,
+  Redactor,
+  RedactedValue,
+  RedactionRuleSummary,
+  RedactionPreview,
+} from './types.js';
+export {
+  REDACTED,
+  BUILTIN_REDACTION_PROFILES,
+  hashValue,
+  createRedactor,
+  previewRedaction,
+  resolveRedactionProfile,
+} from './redaction.js';
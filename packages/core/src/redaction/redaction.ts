@@ -0,0 +1,314 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Applies redaction profiles to stored entities by stripping or hashing fields and matched substrings
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [redaction, export, privacy, hashing]
+ * context:
+ *   business_goal: Share a sanitized graph with vendors or across trust boundaries
+ *   domain: redaction
+ */
+import { createHash } from 'node:crypto';
+import type { StoredEntity } from '../indexer/types.js';
+import type {
+  RedactedValue,
+  RedactionPreview,
+  RedactionProfile,
+  RedactionRule,
+  Redactor,
+} from './types.js';
+
+/** Replacement for stripped substrings and required string fields. */
+export const REDACTED = '[redacted]';
+
+const EMAIL_PATTERN = '[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\\.[A-Za-z]{2,}';
+const INTERNAL_HOST_PATTERN =
+  '\\b[A-Za-z0-9-]+(?:\\.[A-Za-z0-9-]+)*\\.(?:internal|local|corp|lan|intranet|private)\\b';
+
+export const BUILTIN_REDACTION_PROFILES: Readonly<
+  Record<string, RedactionProfile>
+> = {
+  vendor: {
+    name: 'vendor',
+    // Unsalted email hashes are reversed by hashing a list of addresses
+    saltEnv: 'KNOWGRAPH_REDACTION_SALT',
+    rules: [
+      { field: 'raw_docstring', action: 'strip' },
+      { field: 'compliance', action: 'strip' },
+      { field: '*', action: 'hash', pattern: EMAIL_PATTERN },
+      { field: '*', action: 'strip', pattern: INTERNAL_HOST_PATTERN },
+    ],
+  },
+};
+
+/**
+ * Entity columns that mirror an annotation field, with the value left
+ * behind when the field is stripped.
+ */
+const COLUMNS: Readonly<
+  Record<string, { readonly key: keyof StoredEntity; readonly empty: unknown }>
+> = {
+  owner: { key: 'owner', empty: null },
+  status: { key: 'status', empty: null },
+  description: { key: 'description', empty: '' },
+  tags: { key: 'tags', empty: [] },
+  links: { key: 'links', empty: [] },
+  file_path: { key: 'filePath', empty: REDACTED },
+  raw_docstring: { key: 'rawDocstring', empty: null },
+  signature: { key: 'signature', empty: null },
+};
+
+/** Salted, truncated SHA-256; equal inputs hash equally within a profile. */
+export function hashValue(value: string, salt = ''): string {
+  const digest = createHash('sha256')
+    .update(salt)
+    .update('\u0000')
+    .update(value)
+    .digest('hex');
+  return `sha256:${digest.slice(0, 16)}`;
+}
+
+type Transform = (value: unknown) => unknown;
+
+function mapStrings(value: unknown, fn: (text: string) => string): unknown {
+  if (typeof value === 'string') return fn(value);
+  if (Array.isArray(value)) return value.map((item) => mapStrings(item, fn));
+  if (typeof value === 'object' && value !== null) {
+    return Object.fromEntries(
+      Object.entries(value).map(([key, item]) => [key, mapStrings(item, fn)]),
+    );
+  }
+  return value;
+}
+
+function compileRule(rule: RedactionRule, salt: string | undefined): Transform {
+  const hash = (text: string): string => hashValue(text, salt);
+  if (rule.pattern === undefined) {
+    if (rule.field === '*') {
+      throw new Error("Redaction rule for '*' needs a pattern");
+    }
+    return rule.action === 'strip'
+      ? () => undefined
+      : (value) => mapStrings(value, hash);
+  }
+
+  let regex: RegExp;
+  try {
+    regex = new RegExp(rule.pattern, 'g');
+  } catch (err) {
+    throw new Error(
+      `Invalid pattern for redaction rule '${rule.field}': ${err instanceof Error ? err.message : String(err)}`,
+    );
+  }
+  const replace =
+    rule.action === 'strip' ? () => REDACTED : (match: string) => hash(match);
+  return (value) => mapStrings(value, (text) => text.replace(regex, replace));
+}
+
+function applyAtPath(
+  value: unknown,
+  path: readonly string[],
+  transform: Transform,
+): unknown {
+  if (path.length === 0) return transform(value);
+  if (Array.isArray(value)) {
+    return value.map((item) => applyAtPath(item, path, transform));
+  }
+  if (typeof value !== 'object' || value === null) return value;
+
+  const [key, ...rest] = path as [string, ...string[]];
+  if (!(key in value)) return value;
+  const record = value as Record<string, unknown>;
+  const next = applyAtPath(record[key], rest, transform);
+  const copy = { ...record };
+  if (next === undefined) delete copy[key];
+  else copy[key] = next;
+  return copy;
+}
+
+function applyRule(
+  entity: StoredEntity,
+  rule: RedactionRule,
+  transform: Transform,
+): StoredEntity {
+  if (rule.field === '*') {
+    const { id, name, entityType, language, line, column, ...fields } =
+      entity;
+    const redacted = transform(fields) as Omit<
+      StoredEntity,
+      'id' | 'name' | 'entityType' | 'language' | 'line' | 'column'
+    >;
+    return { ...redacted, id, name, entityType, language, line, column };
+  }
+
+  const path = rule.field.split('.');
+  const metadata = applyAtPath(entity.metadata, path, transform);
+  let result: StoredEntity = {
+    ...entity,
+    metadata: (metadata ?? {}) as StoredEntity['metadata'],
+  };
+  const column = COLUMNS[path[0] ?? ''];
+  if (column) {
+    const current = result[column.key];
+    const next =
+      path.length === 1
+        ? transform(current)
+        : applyAtPath(current, path.slice(1), transform);
+    result = { ...result, [column.key]: next ?? column.empty };
+  }
+  return result;
+}
+
+interface CompiledRule {
+  readonly rule: RedactionRule;
+  readonly transform: Transform;
+}
+
+function compileProfile(profile: RedactionProfile): readonly CompiledRule[] {
+  const hashes = profile.rules.some((rule) => rule.action === 'hash');
+  if (hashes && profile.saltEnv && !profile.salt) {
+    throw new Error(
+      `Redaction profile '${profile.name}' hashes values and needs a salt: set ${profile.saltEnv}`,
+    );
+  }
+  return profile.rules.map((rule) => ({
+    rule,
+    transform: compileRule(rule, profile.salt),
+  }));
+}
+
+/**
+ * Compile a profile into a function that redacts one entity. Patterns are
+ * compiled once; an invalid pattern, or a missing salt the profile needs,
+ * throws here rather than mid-export. Ids, names, and entity types are
+ * never touched so the graph keeps its shape.
+ */
+export function createRedactor(profile: RedactionProfile): Redactor {
+  const rules = compileProfile(profile);
+  return (entity) =>
+    rules.reduce(
+      (current, { rule, transform }) => applyRule(current, rule, transform),
+      entity,
+    );
+}
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+type Leaf = Pick<RedactedValue, 'path' | 'before' | 'after'>;
+
+/** Every leaf that differs between two versions of a value. */
+function changedLeaves(
+  before: unknown,
+  after: unknown,
+  path: string,
+  out: Leaf[],
+): void {
+  if (before === after) return;
+  if (isRecord(before) && isRecord(after)) {
+    const keys = new Set([...Object.keys(before), ...Object.keys(after)]);
+    for (const key of keys) {
+      const child = path ? `${path}.${key}` : key;
+      changedLeaves(before[key], after[key], child, out);
+    }
+    return;
+  }
+  if (
+    Array.isArray(before) &&
+    Array.isArray(after) &&
+    before.length === after.length
+  ) {
+    before.forEach((item, i) =>
+      changedLeaves(item, after[i], `${path}[${i}]`, out),
+    );
+    return;
+  }
+  out.push({ path, before, after: after ?? null });
+}
+
+/**
+ * Apply `profile` to `entities` rule by rule and record every value each
+ * rule changed, so a reviewer can sign off on exactly what an export with
+ * the profile leaves out. Values are reported as each rule saw them, after
+ * the rules before it.
+ */
+export function previewRedaction(
+  entities: Iterable<StoredEntity>,
+  profile: RedactionProfile,
+): RedactionPreview {
+  const rules = compileProfile(profile);
+  const counts = rules.map(() => ({
+    entities: 0,
+    values: 0,
+    paths: new Set<string>(),
+  }));
+  const values: RedactedValue[] = [];
+  let total = 0;
+  let redacted = 0;
+
+  for (const entity of entities) {
+    total += 1;
+    let current = entity;
+    let changed = false;
+    rules.forEach(({ rule, transform }, index) => {
+      const next = applyRule(current, rule, transform);
+      const leaves: Leaf[] = [];
+      changedLeaves(current, next, '', leaves);
+      current = next;
+      if (leaves.length === 0) return;
+      changed = true;
+      const count = counts[index];
+      count.entities += 1;
+      count.values += leaves.length;
+      for (const leaf of leaves) {
+        count.paths.add(leaf.path.replace(/\[\d+\]/g, '[]'));
+        values.push({
+          entityId: entity.id,
+          entity: entity.name,
+          rule: index,
+          ...leaf,
+        });
+      }
+    });
+    if (changed) redacted += 1;
+  }
+
+  return {
+    profile: profile.name,
+    entities: total,
+    redactedEntities: redacted,
+    rules: rules.map(({ rule }, index) => ({
+      index,
+      rule,
+      entities: counts[index].entities,
+      values: counts[index].values,
+      paths: [...counts[index].paths].sort(),
+    })),
+    values,
+  };
+}
+
+/**
+ * Look a profile up by name, preferring `custom` (from the manifest) over
+ * the built-in ones.
+ */
+export function resolveRedactionProfile(
+  name: string,
+  custom: Readonly<Record<string, RedactionProfile>> = {},
+): RedactionProfile {
+  const profile = custom[name] ?? BUILTIN_REDACTION_PROFILES[name];
+  if (!profile) {
+    const available = [
+      ...new Set([
+        ...Object.keys(custom),
+        ...Object.keys(BUILTIN_REDACTION_PROFILES),
+      ]),
+    ].sort();
+    throw new Error(
+      `Unknown redaction profile '${name}'. Available: ${available.join(', ')}`,
+    );
+  }
+  return profile;
+}
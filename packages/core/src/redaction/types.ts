@@ -0,0 +1,77 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for export redaction profiles that strip or hash sensitive entity fields
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [redaction, export, privacy, types, interface]
+ * context:
+ *   business_goal: Let security teams define what leaves the company in one reviewed profile
+ *   domain: redaction
+ */
+import type { StoredEntity } from '../indexer/types.js';
+
+/** `strip` removes the value; `hash` replaces it with a salted digest. */
+export type RedactionAction = 'strip' | 'hash';
+
+export interface RedactionRule {
+  /**
+   * Annotation field, dotted for nested fields (`operational.on_call`,
+   * `links.url`). `*` matches every string in the entity and requires a
+   * `pattern`.
+   */
+  readonly field: string;
+  readonly action: RedactionAction;
+  /**
+   * Regular expression; only matching substrings of string values are
+   * redacted. The whole field is redacted when omitted.
+   */
+  readonly pattern?: string;
+}
+
+export interface RedactionProfile {
+  readonly name: string;
+  /** Applied in order. */
+  readonly rules: readonly RedactionRule[];
+  /** Mixed into hashes so they cannot be reversed by guessing inputs. */
+  readonly salt?: string;
+  /**
+   * Environment variable callers read `salt` from. A profile naming one
+   * refuses to hash without its salt.
+   */
+  readonly saltEnv?: string;
+}
+
+export type Redactor = (entity: StoredEntity) => StoredEntity;
+
+/** One value a rule changed or removed. */
+export interface RedactedValue {
+  readonly entityId: string;
+  readonly entity: string;
+  /** Index of the rule in its profile. */
+  readonly rule: number;
+  /** Dotted path into the entity, with array indexes (`links[0].url`). */
+  readonly path: string;
+  readonly before: unknown;
+  /** Null when the value was removed. */
+  readonly after: unknown;
+}
+
+export interface RedactionRuleSummary {
+  readonly index: number;
+  readonly rule: RedactionRule;
+  /** Entities the rule changed. */
+  readonly entities: number;
+  readonly values: number;
+  /** The paths it changed, array indexes folded (`links[].url`), sorted. */
+  readonly paths: readonly string[];
+}
+
+/** What a profile would redact from a set of entities, rule by rule. */
+export interface RedactionPreview {
+  readonly profile: string;
+  readonly entities: number;
+  readonly redactedEntities: number;
+  readonly rules: readonly RedactionRuleSummary[];
+  readonly values: readonly RedactedValue[];
+}
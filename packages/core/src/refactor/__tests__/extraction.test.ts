@@ -0,0 +1,141 @@
+import { describe, it, expect } from 'vitest';
+import type {
+  DependencyGraph,
+  EdgeProvenance,
+  GraphEdge,
+  GraphNode,
+} from '../../graph/types.js';
+import type { EntityType } from '../../types/entity.js';
+import { detectCommunities } from '../communities.js';
+import { declaredModules, suggestExtractions } from '../extraction.js';
+
+function node(
+  name: string,
+  filePath: string | null,
+  entityType: EntityType | null = 'class',
+): GraphNode {
+  return {
+    id: `id-${name}`,
+    name,
+    entityType,
+    external: false,
+    filePath,
+    owner: null,
+    domain: null,
+    workspace: null,
+  };
+}
+
+function edge(
+  from: string,
+  to: string,
+  provenance: EdgeProvenance = 'declared',
+  confidence = 1,
+): GraphEdge {
+  return {
+    from: `id-${from}`,
+    to: `id-${to}`,
+    kind: 'service',
+    provenance,
+    confidence,
+  };
+}
+
+const nodes = [
+  node('billing', 'src/billing/index.ts', 'module'),
+  node('Invoice', 'src/billing/invoice.ts'),
+  node('InvoiceRepository', 'src/billing/store/repository.ts'),
+  node('auth', 'src/auth/__init__.py', 'module'),
+  node('InvoiceToken', 'src/auth/tokens.py'),
+  node('Session', 'src/auth/session.py'),
+  node('SessionStore', 'src/auth/session.py'),
+  node('login', 'src/auth/login.py', 'function'),
+];
+const graph: DependencyGraph = {
+  nodes: [
+    ...nodes,
+    {
+      ...node('stripe', null, null),
+      id: 'external:external_api:stripe',
+      external: true,
+    },
+  ],
+  edges: [
+    edge('Invoice', 'InvoiceRepository'),
+    edge('Invoice', 'InvoiceToken', 'call', 0.8),
+    edge('InvoiceToken', 'InvoiceRepository', 'import'),
+    edge('Session', 'SessionStore'),
+    edge('login', 'Session'),
+    edge('login', 'SessionStore', 'call'),
+    edge('login', 'InvoiceToken', 'call', 0.4),
+    { ...edge('Invoice', 'stripe'), to: 'external:external_api:stripe' },
+  ],
+};
+
+describe('detectCommunities', () => {
+  it('separates densely connected groups joined by a weak edge', () => {
+    const communities = detectCommunities(graph);
+    const of = (name: string) => communities.get(`id-${name}`);
+    expect(of('InvoiceRepository')).toBe(of('Invoice'));
+    expect(of('InvoiceToken')).toBe(of('Invoice'));
+    expect(of('SessionStore')).toBe(of('Session'));
+    expect(of('login')).toBe(of('Session'));
+    expect(of('login')).not.toBe(of('Invoice'));
+    expect(of('billing')).not.toBe(of('auth'));
+    expect(communities.has('external:external_api:stripe')).toBe(false);
+  });
+
+  it('gives every node its own community without edges', () => {
+    const communities = detectCommunities({ nodes, edges: [] });
+    expect(new Set(communities.values()).size).toBe(nodes.length);
+  });
+});
+
+describe('declaredModules', () => {
+  it('uses the file, then the nearest directory index, then nothing', () => {
+    const moduleOf = declaredModules(graph);
+    expect(moduleOf(node('x', 'src/billing/index.ts'))).toBe('billing');
+    expect(moduleOf(node('x', 'src/billing/store/repository.ts'))).toBe(
+      'billing',
+    );
+    expect(moduleOf(node('x', 'lib/util.ts'))).toBeNull();
+    expect(moduleOf(node('example.com/app/api', 'api', null))).toBe(
+      'example.com/app/api',
+    );
+  });
+});
+
+describe('suggestExtractions', () => {
+  it('suggests clusters spanning modules and where to move them', () => {
+    const report = suggestExtractions(graph);
+
+    expect(report.clusters).toBe(2);
+    expect(report.suggestions).toHaveLength(1);
+    const [suggestion] = report.suggestions;
+    expect(suggestion.members.map((m) => m.name)).toEqual([
+      'Invoice',
+      'InvoiceRepository',
+      'InvoiceToken',
+    ]);
+    expect(suggestion.modules).toEqual([
+      {
+        module: 'billing',
+        members: ['Invoice', 'InvoiceRepository'],
+        share: 0.67,
+      },
+      { module: 'auth', members: ['InvoiceToken'], share: 0.2 },
+    ]);
+    expect(suggestion.home).toBe('billing');
+    expect(suggestion.moves).toEqual(['InvoiceToken']);
+    expect(suggestion.internalEdges).toBe(3);
+    expect(suggestion.boundaryEdges).toBe(1);
+    expect(suggestion.cohesion).toBe(0.88);
+  });
+
+  it('drops clusters below the size and cohesion thresholds', () => {
+    expect(suggestExtractions(graph, { minSize: 4 }).suggestions).toEqual([]);
+    expect(
+      suggestExtractions(graph, { minCohesion: 0.95 }).suggestions,
+    ).toEqual([]);
+  });
+});
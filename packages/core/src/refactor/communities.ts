@@ -0,0 +1,151 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Deterministic Louvain community detection over the dependency graph, treating edges as undirected and weighting them by confidence
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [refactor, clustering, louvain, graph]
+ * context:
+ *   business_goal: Give the same cluster boundaries on every run so suggestions can be tracked over time
+ *   domain: refactor
+ */
+import type { DependencyGraph } from '../graph/types.js';
+
+/** A weighted, undirected graph over nodes 0..n-1. */
+interface Level {
+  /** Neighbour to edge weight, stored in both directions. */
+  readonly adjacency: readonly Map<number, number>[];
+  /** Weight of the edges folded into each node by earlier levels. */
+  readonly internal: readonly number[];
+}
+
+const MAX_PASSES = 100;
+const EPSILON = 1e-12;
+
+function addWeight(
+  map: Map<number, number>,
+  key: number,
+  weight: number,
+): void {
+  map.set(key, (map.get(key) ?? 0) + weight);
+}
+
+/**
+ * Move each node to the neighbouring community that gains the most
+ * modularity until no move gains any. Nodes are visited in order and only
+ * strict gains move them, so the result is the same on every run.
+ */
+function moveNodes(level: Level, totalWeight: number): number[] {
+  const { adjacency, internal } = level;
+  const degree = adjacency.map(
+    (neighbours, i) =>
+      [...neighbours.values()].reduce((sum, w) => sum + w, 0) +
+      2 * internal[i],
+  );
+  const community = adjacency.map((_, i) => i);
+  const totals = [...degree];
+
+  for (let pass = 0; pass < MAX_PASSES; pass += 1) {
+    let moved = false;
+    for (let i = 0; i < adjacency.length; i += 1) {
+      const own = community[i];
+      totals[own] -= degree[i];
+      const links = new Map<number, number>();
+      for (const [j, weight] of adjacency[i]) {
+        if (j !== i) addWeight(links, community[j], weight);
+      }
+      const gain = (c: number): number =>
+        (links.get(c) ?? 0) - (totals[c] * degree[i]) / totalWeight;
+      let best = own;
+      let bestGain = gain(own);
+      for (const c of links.keys()) {
+        const g = gain(c);
+        if (g > bestGain + EPSILON) {
+          best = c;
+          bestGain = g;
+        }
+      }
+      totals[best] += degree[i];
+      if (best !== own) {
+        community[i] = best;
+        moved = true;
+      }
+    }
+    if (!moved) break;
+  }
+  return community;
+}
+
+function countOf(community: readonly number[]): number {
+  return community.reduce((max, c) => Math.max(max, c + 1), 0);
+}
+
+/** Communities numbered 0..k-1 in order of first appearance. */
+function renumber(community: readonly number[]): number[] {
+  const numbers = new Map<number, number>();
+  return community.map((c) => {
+    const existing = numbers.get(c);
+    if (existing !== undefined) return existing;
+    numbers.set(c, numbers.size);
+    return numbers.size - 1;
+  });
+}
+
+/** `level` with each community collapsed into one node. */
+function aggregate(level: Level, community: readonly number[]): Level {
+  const count = countOf(community);
+  const adjacency = Array.from(
+    { length: count },
+    () => new Map<number, number>(),
+  );
+  const internal = new Array<number>(count).fill(0);
+  level.adjacency.forEach((neighbours, i) => {
+    const c = community[i];
+    internal[c] += level.internal[i];
+    for (const [j, weight] of neighbours) {
+      const d = community[j];
+      // Each edge is stored at both ends, so half of it lands here twice
+      if (c === d) internal[c] += weight / 2;
+      else addWeight(adjacency[c], d, weight);
+    }
+  });
+  return { adjacency, internal };
+}
+
+/**
+ * Group the non-external nodes of `graph` into densely connected
+ * communities with the Louvain method: move nodes between communities to
+ * raise modularity, collapse each community into one node, and repeat
+ * until nothing moves. Edge direction is ignored, parallel edges add up,
+ * and each edge weighs its confidence. Returns each node's community,
+ * numbered from 0; unconnected nodes get one each.
+ */
+export function detectCommunities(
+  graph: DependencyGraph,
+): ReadonlyMap<string, number> {
+  const ids = graph.nodes.filter((node) => !node.external).map((n) => n.id);
+  const index = new Map(ids.map((id, i) => [id, i]));
+  const adjacency = ids.map(() => new Map<number, number>());
+  let totalWeight = 0;
+  for (const edge of graph.edges) {
+    const from = index.get(edge.from);
+    const to = index.get(edge.to);
+    if (from === undefined || to === undefined || from === to) continue;
+    if (!(edge.confidence > 0)) continue;
+    addWeight(adjacency[from], to, edge.confidence);
+    addWeight(adjacency[to], from, edge.confidence);
+    totalWeight += 2 * edge.confidence;
+  }
+
+  let membership = ids.map((_, i) => i);
+  if (totalWeight > 0) {
+    let level: Level = { adjacency, internal: ids.map(() => 0) };
+    for (;;) {
+      const community = renumber(moveNodes(level, totalWeight));
+      if (countOf(community) === level.adjacency.length) break;
+      membership = membership.map((c) => community[c]);
+      level = aggregate(level, community);
+    }
+  }
+  return new Map(ids.map((id, i) => [id, membership[i]]));
+}
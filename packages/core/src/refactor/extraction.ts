@@ -0,0 +1,195 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Suggests module extraction boundaries from graph communities that span several declared modules
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [refactor, modules, clustering, graph, suggestions]
+ * context:
+ *   business_goal: Propose module splits a team can act on rather than raw cluster lists
+ *   domain: refactor
+ */
+import { posix } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import type { DependencyGraph, GraphNode } from '../graph/types.js';
+import { detectCommunities } from './communities.js';
+import type {
+  ClusterMember,
+  ClusterModule,
+  ExtractionOptions,
+  ExtractionReport,
+  ExtractionSuggestion,
+} from './types.js';
+
+export const UNASSIGNED_MODULE = '(no module)';
+
+/** Files whose `module` annotation speaks for their whole directory. */
+const INDEX_FILE =
+  /(?:^|\/)(?:index\.[cm]?[jt]sx?|__init__\.py|mod\.rs|doc\.go|package-info\.java)$/;
+
+interface EdgeStats {
+  internalEdges: number;
+  boundaryEdges: number;
+  internalWeight: number;
+  boundaryWeight: number;
+}
+
+const EMPTY_STATS: EdgeStats = {
+  internalEdges: 0,
+  boundaryEdges: 0,
+  internalWeight: 0,
+  boundaryWeight: 0,
+};
+
+function round(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+/**
+ * A function giving each node its declared module: the `module` entity in
+ * its file, else the one in the index file (such as `index.ts` or
+ * `__init__.py`) of the nearest enclosing directory. Go package nodes are
+ * modules of their own.
+ */
+export function declaredModules(
+  graph: DependencyGraph,
+): (node: GraphNode) => string | null {
+  const byFile = new Map<string, string>();
+  const byDir = new Map<string, string>();
+  for (const node of graph.nodes) {
+    if (node.entityType !== 'module' || !node.filePath) continue;
+    const path = node.filePath.replace(/\\/g, '/');
+    if (!byFile.has(path)) byFile.set(path, node.name);
+    const dir = posix.dirname(path);
+    if (INDEX_FILE.test(path) && !byDir.has(dir)) byDir.set(dir, node.name);
+  }
+
+  return (node) => {
+    if (node.external || !node.filePath) return null;
+    if (node.entityType === null) return node.name;
+    const path = node.filePath.replace(/\\/g, '/');
+    const own = byFile.get(path);
+    if (own !== undefined) return own;
+    for (let dir = posix.dirname(path); ; dir = posix.dirname(dir)) {
+      const found = byDir.get(dir);
+      if (found !== undefined) return found;
+      if (dir === posix.dirname(dir)) return null;
+    }
+  };
+}
+
+/**
+ * Find densely connected clusters with `detectCommunities` and suggest
+ * the ones that span more than one declared module as extraction
+ * boundaries: the members to move together, and the module most of them
+ * already live in. A cluster needs `minSize` members and `minCohesion`,
+ * the share of the edge weight touching it that stays inside it.
+ */
+export function suggestExtractions(
+  graph: DependencyGraph,
+  options: ExtractionOptions = {},
+): ExtractionReport {
+  const { minSize = 3, minCohesion = 0.5 } = options;
+  const communities = detectCommunities(graph);
+  const moduleOf = declaredModules(graph);
+
+  const clusters = new Map<number, GraphNode[]>();
+  const moduleSizes = new Map<string, number>();
+  for (const node of graph.nodes) {
+    const community = communities.get(node.id);
+    if (community === undefined) continue;
+    const cluster = clusters.get(community) ?? [];
+    cluster.push(node);
+    clusters.set(community, cluster);
+    const moduleName = moduleOf(node) ?? UNASSIGNED_MODULE;
+    moduleSizes.set(moduleName, (moduleSizes.get(moduleName) ?? 0) + 1);
+  }
+
+  const edgeStats = new Map<number, EdgeStats>();
+  const count = (
+    community: number,
+    internal: boolean,
+    weight: number,
+  ): void => {
+    const stats = { ...(edgeStats.get(community) ?? EMPTY_STATS) };
+    if (internal) {
+      stats.internalEdges += 1;
+      stats.internalWeight += weight;
+    } else {
+      stats.boundaryEdges += 1;
+      stats.boundaryWeight += weight;
+    }
+    edgeStats.set(community, stats);
+  };
+  for (const edge of graph.edges) {
+    const from = communities.get(edge.from);
+    const to = communities.get(edge.to);
+    // Edges to external stubs don't tie a cluster to other code
+    if (from === undefined || to === undefined) continue;
+    count(from, from === to, edge.confidence);
+    if (from !== to) count(to, false, edge.confidence);
+  }
+
+  const suggestions: ExtractionSuggestion[] = [];
+  for (const [community, nodes] of clusters) {
+    if (nodes.length < minSize) continue;
+    const members: ClusterMember[] = nodes
+      .map((node) => ({
+        id: node.id,
+        name: node.name,
+        entityType: node.entityType,
+        filePath: node.filePath,
+        module: moduleOf(node),
+      }))
+      .sort((a, b) => compareStrings(a.name, b.name));
+    const byModule = new Map<string, string[]>();
+    for (const member of members) {
+      const moduleName = member.module ?? UNASSIGNED_MODULE;
+      const names = byModule.get(moduleName) ?? [];
+      names.push(member.name);
+      byModule.set(moduleName, names);
+    }
+    if (byModule.size < 2) continue;
+
+    const { internalEdges, boundaryEdges, internalWeight, boundaryWeight } =
+      edgeStats.get(community) ?? EMPTY_STATS;
+    const total = internalWeight + boundaryWeight;
+    const cohesion = total === 0 ? 0 : round(internalWeight / total);
+    if (cohesion < minCohesion) continue;
+
+    const modules: ClusterModule[] = [...byModule.entries()]
+      .map(([moduleName, names]) => ({
+        module: moduleName,
+        members: names,
+        share: round(names.length / (moduleSizes.get(moduleName) ?? 1)),
+      }))
+      .sort(
+        (a, b) =>
+          b.members.length - a.members.length ||
+          compareStrings(a.module, b.module),
+      );
+    const home =
+      modules.find((entry) => entry.module !== UNASSIGNED_MODULE)?.module ??
+      null;
+    suggestions.push({
+      members,
+      modules,
+      home,
+      moves: members
+        .filter((member) => member.module !== home)
+        .map((member) => member.name),
+      internalEdges,
+      boundaryEdges,
+      cohesion,
+    });
+  }
+
+  suggestions.sort(
+    (a, b) =>
+      b.cohesion - a.cohesion ||
+      b.members.length - a.members.length ||
+      compareStrings(a.members[0].name, b.members[0].name),
+  );
+  const connected = [...clusters.values()].filter((nodes) => nodes.length > 1);
+  return { suggestions, clusters: connected.length };
+}
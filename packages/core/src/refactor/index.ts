@@ -0,0 +1,13 @@
+export type {
+  ClusterMember,
+  ClusterModule,
+  ExtractionOptions,
+  ExtractionReport,
+  ExtractionSuggestion,
+} from './types.js';
+export { detectCommunities } from './communities.js';
+export {
+  declaredModules,
+  suggestExtractions,
+  UNASSIGNED_MODULE,
+} from './extraction.js';
@@ -0,0 +1,60 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for suggesting module extraction boundaries from densely connected clusters in the dependency graph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [refactor, modules, clustering, graph, types]
+ * context:
+ *   business_goal: Let refactoring suggestions be reviewed in tools other than the terminal
+ *   domain: refactor
+ */
+import type { EntityType } from '../types/entity.js';
+
+export interface ClusterMember {
+  readonly id: string;
+  readonly name: string;
+  readonly entityType: EntityType | null;
+  readonly filePath: string | null;
+  /** The declared module it is in now, if any. */
+  readonly module: string | null;
+}
+
+/** The part of a cluster that sits in one module. */
+export interface ClusterModule {
+  /** The module's name, or `(no module)`. */
+  readonly module: string;
+  readonly members: readonly string[];
+  /** Share of the module's nodes that are in the cluster, from 0 to 1. */
+  readonly share: number;
+}
+
+/** A cluster spanning modules, and how its members could move together. */
+export interface ExtractionSuggestion {
+  readonly members: readonly ClusterMember[];
+  /** Most members first. */
+  readonly modules: readonly ClusterModule[];
+  /** The declared module with the most members, or null if none has any. */
+  readonly home: string | null;
+  /** Names of members outside `home`, which would move into it. */
+  readonly moves: readonly string[];
+  readonly internalEdges: number;
+  /** Edges between a member and a node outside the cluster. */
+  readonly boundaryEdges: number;
+  /** Internal edge weight over all edge weight touching the cluster. */
+  readonly cohesion: number;
+}
+
+export interface ExtractionReport {
+  /** Most cohesive first. */
+  readonly suggestions: readonly ExtractionSuggestion[];
+  /** Clusters of two or more nodes, including ones inside one module. */
+  readonly clusters: number;
+}
+
+export interface ExtractionOptions {
+  /** Smallest cluster worth suggesting. Default 3. */
+  readonly minSize?: number;
+  /** Lowest cohesion worth suggesting, from 0 to 1. Default 0.5. */
+  readonly minCohesion?: number;
+}
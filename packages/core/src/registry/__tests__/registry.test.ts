@@ -0,0 +1,133 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createRegistryStore, hashToken } from '../registry.js';
+import type { RegistryStoreOptions } from '../types.js';
+
+describe('createRegistryStore', () => {
+  let dir: string;
+  let path: string;
+  let clock: number;
+  const options: RegistryStoreOptions = {
+    now: () => `2026-10-0${++clock}T00:00:00.000Z`,
+    generateToken: () => 'kg_test',
+  };
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-registry-'));
+    path = join(dir, 'registry.json');
+    clock = 0;
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('creates, replaces, and deletes resources by name', () => {
+    const store = createRegistryStore(path, options);
+    const created = store.put('namespaces', 'acme/payments', {
+      roles: ['payments'],
+    });
+    expect(created.created).toBe(true);
+    expect(created.record).toEqual({
+      resource: { name: 'acme/payments', roles: ['payments'] },
+      revision: 1,
+      createdAt: '2026-10-01T00:00:00.000Z',
+      updatedAt: '2026-10-01T00:00:00.000Z',
+    });
+
+    const replaced = store.put('namespaces', 'acme/payments', {
+      description: 'Payments',
+    });
+    expect(replaced.created).toBe(false);
+    expect(replaced.record).toMatchObject({
+      resource: { name: 'acme/payments', description: 'Payments', roles: [] },
+      revision: 2,
+      createdAt: '2026-10-01T00:00:00.000Z',
+      updatedAt: '2026-10-02T00:00:00.000Z',
+    });
+
+    expect(store.remove('namespaces', 'acme/payments')).toBe(true);
+    expect(store.remove('namespaces', 'acme/payments')).toBe(false);
+    expect(store.get('namespaces', 'acme/payments')).toBeUndefined();
+  });
+
+  it('returns a token value once and keeps it on replace', () => {
+    const store = createRegistryStore(path, options);
+    const created = store.put('tokens', 'ci', { roles: ['reader'] });
+    expect(created.token).toBe('kg_test');
+    expect(created.record.resource.tokenHash).toBe(hashToken('kg_test'));
+
+    const replaced = store.put('tokens', 'ci', { roles: ['admin'] });
+    expect(replaced.token).toBeUndefined();
+    expect(replaced.record.resource).toEqual({
+      name: 'ci',
+      roles: ['admin'],
+      tokenHash: hashToken('kg_test'),
+    });
+    expect(readFileSync(path, 'utf-8')).not.toContain('kg_test');
+  });
+
+  it('keeps resources across restarts, sorted by name', () => {
+    const store = createRegistryStore(path, options);
+    store.put('policy-bundles', 'strict', {
+      rules: { 'owner-required': 'error' },
+    });
+    store.put('policy-bundles', 'baseline', { rules: {} });
+
+    const reopened = createRegistryStore(path, options);
+    expect(
+      reopened.list('policy-bundles').map((record) => record.resource.name),
+    ).toEqual(['baseline', 'strict']);
+    expect(reopened.get('policy-bundles', 'strict')?.resource.rules).toEqual({
+      'owner-required': 'error',
+    });
+  });
+
+  it('saves queries, and opens registries written before they existed', () => {
+    writeFileSync(path, '{"version": 1, "namespaces": {}}');
+    const store = createRegistryStore(path, options);
+    expect(store.list('queries')).toEqual([]);
+    store.put('queries', 'critical-gdpr-paths', {
+      description: 'Critical services handling personal data',
+      type: 'service',
+      tags: ['gdpr', 'critical'],
+    });
+
+    const reopened = createRegistryStore(path, options);
+    expect(reopened.get('queries', 'critical-gdpr-paths')?.resource).toEqual({
+      name: 'critical-gdpr-paths',
+      description: 'Critical services handling personal data',
+      type: 'service',
+      tags: ['gdpr', 'critical'],
+    });
+    expect(() => store.put('queries', 'x', { type: 'spaceship' })).toThrow(
+      "Invalid queries 'x': type",
+    );
+  });
+
+  it('rejects invalid names and bodies', () => {
+    const store = createRegistryStore(path, options);
+    expect(() => store.put('tokens', 'ci/bot', {})).toThrow(
+      "Invalid tokens name 'ci/bot'",
+    );
+    expect(() => store.put('tokens', 'ci', { role: ['admin'] })).toThrow(
+      "Invalid tokens 'ci': body Unrecognized key(s) in object: 'role'",
+    );
+    expect(() =>
+      store.put('policy-bundles', 'strict', { rules: { x: 'fatal' } }),
+    ).toThrow("Invalid policy-bundles 'strict': rules.x");
+    expect(() => store.put('namespaces', 'acme', { name: 'other' })).toThrow(
+      "name 'other' does not match the path",
+    );
+    expect(store.get('tokens', 'toString')).toBeUndefined();
+  });
+
+  it('refuses to open a file that is not a registry', () => {
+    writeFileSync(path, '{"version": 2}');
+    expect(() => createRegistryStore(path)).toThrow(
+      `Invalid registry ${path}: version`,
+    );
+  });
+});
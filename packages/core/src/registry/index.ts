@@ -0,0 +1,20 @@
+export type {
+  RegistryNamespace,
+  RegistryToken,
+  PolicyBundle,
+  RegistrySavedQuery,
+  RegistryResources,
+  RegistryKind,
+  RegistryRecord,
+  RegistryState,
+  RegistryPutResult,
+  RegistryStore,
+  RegistryStoreOptions,
+} from './types.js';
+export {
+  REGISTRY_KINDS,
+  isRegistryKind,
+  hashToken,
+  generateToken,
+  createRegistryStore,
+} from './registry.js';
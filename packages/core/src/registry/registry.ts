@@ -0,0 +1,248 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: File-backed store for the central registry's namespaces, access tokens, policy bundles, and saved queries, with create-or-replace semantics for infrastructure-as-code clients
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [registry, namespaces, tokens, policy, crud, terraform]
+ * context:
+ *   business_goal: Let platform teams manage a central knowgraph server's access and policy from infrastructure code
+ *   domain: registry
+ */
+import { createHash, randomBytes } from 'node:crypto';
+import {
+  existsSync,
+  mkdirSync,
+  readFileSync,
+  renameSync,
+  writeFileSync,
+} from 'node:fs';
+import { dirname } from 'node:path';
+import { z } from 'zod';
+import { createKnowgraphError } from '../errors/errors.js';
+import { NAMESPACE_PATTERN } from '../namespace/namespace.js';
+import { RuleSeveritySchema } from '../types/manifest.js';
+import { SavedQuerySchema } from '../types/manifest-workflows.js';
+import type {
+  RegistryKind,
+  RegistryPutResult,
+  RegistryRecord,
+  RegistryResources,
+  RegistryState,
+  RegistryStore,
+  RegistryStoreOptions,
+  RegistryToken,
+} from './types.js';
+
+export const REGISTRY_KINDS: readonly RegistryKind[] = [
+  'namespaces',
+  'tokens',
+  'policy-bundles',
+  'queries',
+];
+
+/** Token, bundle, and query names: one segment, as in a namespace. */
+const NAME_PATTERN = /^[A-Za-z0-9][A-Za-z0-9._-]*$/;
+
+const RolesSchema = z.array(z.string().min(1)).default([]);
+
+// Request bodies are strict so a misspelt field fails instead of vanishing.
+const BODY_SCHEMAS = {
+  namespaces: z
+    .object({
+      name: z.string().optional(),
+      description: z.string().optional(),
+      roles: RolesSchema,
+    })
+    .strict(),
+  tokens: z
+    .object({
+      name: z.string().optional(),
+      description: z.string().optional(),
+      roles: RolesSchema,
+    })
+    .strict(),
+  'policy-bundles': z
+    .object({
+      name: z.string().optional(),
+      description: z.string().optional(),
+      rules: z.record(z.string(), RuleSeveritySchema),
+    })
+    .strict(),
+  queries: SavedQuerySchema.extend({ name: z.string().optional() }).strict(),
+};
+
+const RecordSchema = z.object({
+  resource: z.record(z.string(), z.unknown()),
+  revision: z.number().int().positive(),
+  createdAt: z.string(),
+  updatedAt: z.string(),
+});
+
+const StateSchema = z.object({
+  version: z.literal(1),
+  namespaces: z.record(z.string(), RecordSchema).default({}),
+  tokens: z.record(z.string(), RecordSchema).default({}),
+  'policy-bundles': z.record(z.string(), RecordSchema).default({}),
+  queries: z.record(z.string(), RecordSchema).default({}),
+});
+
+export function isRegistryKind(value: string): value is RegistryKind {
+  return (REGISTRY_KINDS as readonly string[]).includes(value);
+}
+
+/** The hex SHA-256 kept in place of a token's value. */
+export function hashToken(token: string): string {
+  return createHash('sha256').update(token).digest('hex');
+}
+
+/** A new token value: `kg_` and 32 random bytes, base64url-encoded. */
+export function generateToken(): string {
+  return `kg_${randomBytes(32).toString('base64url')}`;
+}
+
+function emptyState(): RegistryState {
+  return { namespaces: {}, tokens: {}, 'policy-bundles': {}, queries: {} };
+}
+
+function readState(path: string): RegistryState {
+  if (!existsSync(path)) return emptyState();
+  let raw: unknown;
+  try {
+    raw = JSON.parse(readFileSync(path, 'utf-8'));
+  } catch (err) {
+    throw createKnowgraphError('parse', `Invalid registry ${path}`, {
+      cause: err,
+    });
+  }
+  const parsed = StateSchema.safeParse(raw);
+  if (!parsed.success) {
+    const issue = parsed.error.issues[0];
+    throw createKnowgraphError(
+      'schema',
+      `Invalid registry ${path}: ${issue?.path.join('.')} ${issue?.message}`,
+    );
+  }
+  // Resources were validated when they were put.
+  const { namespaces, tokens, queries } = parsed.data;
+  const bundles = parsed.data['policy-bundles'];
+  return {
+    namespaces,
+    tokens,
+    'policy-bundles': bundles,
+    queries,
+  } as unknown as RegistryState;
+}
+
+function writeState(path: string, state: RegistryState): void {
+  mkdirSync(dirname(path), { recursive: true });
+  const tempPath = `${path}.tmp`;
+  writeFileSync(
+    tempPath,
+    `${JSON.stringify({ version: 1, ...state }, null, 2)}\n`,
+    // token hashes are not secrets, but nobody else needs them
+    { mode: 0o600 },
+  );
+  renameSync(tempPath, path);
+}
+
+function checkName(kind: RegistryKind, name: string): void {
+  const pattern = kind === 'namespaces' ? NAMESPACE_PATTERN : NAME_PATTERN;
+  if (!pattern.test(name)) {
+    throw createKnowgraphError('schema', `Invalid ${kind} name '${name}'`);
+  }
+}
+
+/**
+ * A store kept in memory and written to the JSON file at `path` after
+ * every change, replacing it only once the new file is complete. Throws
+ * when an existing file is not a registry.
+ */
+export function createRegistryStore(
+  path: string,
+  options: RegistryStoreOptions = {},
+): RegistryStore {
+  const now = options.now ?? (() => new Date().toISOString());
+  const newToken = options.generateToken ?? generateToken;
+  let state = readState(path);
+
+  function find<K extends RegistryKind>(
+    kind: K,
+    name: string,
+  ): RegistryRecord<RegistryResources[K]> | undefined {
+    return Object.hasOwn(state[kind], name) ? records(kind)[name] : undefined;
+  }
+
+  function records<K extends RegistryKind>(
+    kind: K,
+  ): Readonly<Record<string, RegistryRecord<RegistryResources[K]>>> {
+    return state[kind] as Readonly<
+      Record<string, RegistryRecord<RegistryResources[K]>>
+    >;
+  }
+
+  function put<K extends RegistryKind>(
+    kind: K,
+    name: string,
+    body: unknown,
+  ): RegistryPutResult<RegistryResources[K]> {
+    checkName(kind, name);
+    const parsed = BODY_SCHEMAS[kind].safeParse(body ?? {});
+    if (!parsed.success) {
+      const issue = parsed.error.issues[0];
+      const field = issue?.path.join('.') || 'body';
+      throw createKnowgraphError(
+        'schema',
+        `Invalid ${kind} '${name}': ${field} ${issue?.message}`,
+      );
+    }
+    const { name: bodyName, ...fields } = parsed.data;
+    if (bodyName !== undefined && bodyName !== name) {
+      throw createKnowgraphError(
+        'schema',
+        `Invalid ${kind} '${name}': name '${bodyName}' does not match the path`,
+      );
+    }
+
+    const existing = find(kind, name);
+    let token: string | undefined;
+    let resource: Record<string, unknown> = { name, ...fields };
+    if (kind === 'tokens') {
+      const previous = existing?.resource as RegistryToken | undefined;
+      if (previous) {
+        resource = { ...resource, tokenHash: previous.tokenHash };
+      } else {
+        token = newToken();
+        resource = { ...resource, tokenHash: hashToken(token) };
+      }
+    }
+    const time = now();
+    const record = {
+      resource: resource as unknown as RegistryResources[K],
+      revision: (existing?.revision ?? 0) + 1,
+      createdAt: existing?.createdAt ?? time,
+      updatedAt: time,
+    };
+    state = { ...state, [kind]: { ...state[kind], [name]: record } };
+    writeState(path, state);
+    return { record, created: existing === undefined, token };
+  }
+
+  function remove(kind: RegistryKind, name: string): boolean {
+    if (!find(kind, name)) return false;
+    const rest = Object.entries(state[kind]).filter(([key]) => key !== name);
+    state = { ...state, [kind]: Object.fromEntries(rest) };
+    writeState(path, state);
+    return true;
+  }
+
+  return {
+    list: (kind) =>
+      Object.entries(records(kind))
+        .sort(([a], [b]) => a.localeCompare(b))
+        .map(([, record]) => record),
+    get: find,
+    put,
+    remove,
+  };
+}
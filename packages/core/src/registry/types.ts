@@ -0,0 +1,108 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the central registry's namespaces, access tokens, policy bundles, and saved queries managed as code
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [registry, namespaces, tokens, policy, types, interface]
+ * context:
+ *   business_goal: Describe registry resources in a form Terraform providers can map one to one
+ *   domain: registry
+ */
+import type { RuleSeverity } from '../types/manifest.js';
+import type { SavedQueryConfig } from '../types/manifest-workflows.js';
+
+/** A namespace and the roles that may see its nodes. */
+export interface RegistryNamespace {
+  readonly name: string;
+  readonly description?: string;
+  /** Roles that may see the namespace; everyone may when empty. */
+  readonly roles: readonly string[];
+}
+
+/** A bearer token for the server; only a hash of its value is kept. */
+export interface RegistryToken {
+  readonly name: string;
+  readonly description?: string;
+  readonly roles: readonly string[];
+  /** Hex SHA-256 of the token value. */
+  readonly tokenHash: string;
+}
+
+/** Lint rule severities shared across repositories. */
+export interface PolicyBundle {
+  readonly name: string;
+  readonly description?: string;
+  readonly rules: Readonly<Record<string, RuleSeverity>>;
+}
+
+/** A search saved for dashboards and reports to run by name. */
+export interface RegistrySavedQuery extends SavedQueryConfig {
+  readonly name: string;
+}
+
+/** The resource types, keyed by the kind used in API paths. */
+export interface RegistryResources {
+  readonly namespaces: RegistryNamespace;
+  readonly tokens: RegistryToken;
+  readonly 'policy-bundles': PolicyBundle;
+  readonly queries: RegistrySavedQuery;
+}
+
+export type RegistryKind = keyof RegistryResources;
+
+/** A stored resource with the bookkeeping a client needs to spot drift. */
+export interface RegistryRecord<T> {
+  readonly resource: T;
+  /** Starts at 1 and goes up with every change. */
+  readonly revision: number;
+  readonly createdAt: string;
+  readonly updatedAt: string;
+}
+
+export type RegistryState = {
+  readonly [K in RegistryKind]: Readonly<
+    Record<string, RegistryRecord<RegistryResources[K]>>
+  >;
+};
+
+export interface RegistryPutResult<T> {
+  readonly record: RegistryRecord<T>;
+  /** False when an existing resource was replaced. */
+  readonly created: boolean;
+  /** The new token's value, only when a token was created. */
+  readonly token?: string;
+}
+
+/**
+ * Create, read, replace, and delete registry resources by name. Every
+ * change is written to disk before it returns.
+ */
+export interface RegistryStore {
+  list<K extends RegistryKind>(
+    kind: K,
+  ): readonly RegistryRecord<RegistryResources[K]>[];
+  get<K extends RegistryKind>(
+    kind: K,
+    name: string,
+  ): RegistryRecord<RegistryResources[K]> | undefined;
+  /**
+   * Create `name`, or replace it when it exists, from a request body.
+   * Replacing a token keeps its value. Throws a schema error when the
+   * name or body is invalid.
+   */
+  put<K extends RegistryKind>(
+    kind: K,
+    name: string,
+    body: unknown,
+  ): RegistryPutResult<RegistryResources[K]>;
+  /** Whether there was a resource to delete. */
+  remove(kind: RegistryKind, name: string): boolean;
+}
+
+export interface RegistryStoreOptions {
+  /** ISO timestamps for records; tests pass a fixed one. */
+  readonly now?: () => string;
+  /** New token values; tests pass a predictable one. */
+  readonly generateToken?: () => string;
+}
@@ -0,0 +1,143 @@
+import { describe, it, expect } from 'vitest';
+import { GO_API_VERSION } from '../../golang/go-api.js';
+import type { GoApiSurface } from '../../golang/types.js';
+import type { GraphNode } from '../../graph/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Status } from '../../types/entity.js';
+import { nextVersion, suggestVersionBump } from '../version-bump.js';
+import type { ReleaseSnapshot } from '../types.js';
+
+function makeEntity(name: string, line: number, status: Status): StoredEntity {
+  return {
+    id: `id-${name}-${line}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `The ${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line,
+    column: 0,
+    owner: 'platform-team',
+    status,
+    metadata: { type: 'service', description: `The ${name} service`, status },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+function snapshot(
+  entities: readonly StoredEntity[],
+  api?: GoApiSurface,
+): ReleaseSnapshot {
+  const nodes: GraphNode[] = entities.map((entity) => ({
+    id: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    external: false,
+    filePath: entity.filePath,
+    owner: entity.owner,
+    domain: null,
+    workspace: null,
+  }));
+  return { entities, graph: { nodes, edges: [] }, api };
+}
+
+function surface(symbols: readonly string[]): GoApiSurface {
+  return {
+    version: GO_API_VERSION,
+    packages: [
+      {
+        importPath: 'example.com/app/pkg/client',
+        dir: 'pkg/client',
+        stable: true,
+        symbols: symbols.map((name) => ({
+          name,
+          kind: 'func',
+          signature: `func ${name}()`,
+        })),
+      },
+    ],
+  };
+}
+
+describe('suggestVersionBump', () => {
+  const base = [
+    makeEntity('billing', 1, 'stable'),
+    makeEntity('search', 1, 'experimental'),
+  ];
+
+  it('suggests patch for entities that only moved', () => {
+    const moved = [
+      makeEntity('billing', 12, 'stable'),
+      makeEntity('search', 30, 'experimental'),
+    ];
+    const suggestion = suggestVersionBump(
+      snapshot(base),
+      snapshot(moved),
+      '1.4.2',
+    );
+    expect(suggestion).toEqual({
+      bump: 'patch',
+      reasons: [],
+      current: '1.4.2',
+      next: '1.4.3',
+    });
+  });
+
+  it('suggests minor for new entities and exported symbols', () => {
+    const suggestion = suggestVersionBump(
+      snapshot(base, surface(['New'])),
+      snapshot(
+        [...base, makeEntity('ledger', 1, 'experimental')],
+        surface(['Dial', 'New']),
+      ),
+    );
+    expect(suggestion.bump).toBe('minor');
+    expect(suggestion.next).toBeNull();
+    expect(suggestion.reasons.map((r) => [r.kind, r.subject])).toEqual([
+      ['api-added', 'example.com/app/pkg/client.Dial'],
+      ['node-added', 'src/ledger.ts:ledger'],
+    ]);
+  });
+
+  it('suggests major when a stable entity or API is removed', () => {
+    const suggestion = suggestVersionBump(
+      snapshot(base, surface(['New'])),
+      snapshot([makeEntity('ledger', 1, 'experimental')], surface([])),
+      'v2.0.1',
+    );
+    expect(suggestion.bump).toBe('major');
+    expect(suggestion.next).toBe('v3.0.0');
+    expect(suggestion.reasons.map((r) => [r.bump, r.kind])).toEqual([
+      ['major', 'api-breaking'],
+      ['major', 'stable-removed'],
+      ['minor', 'node-added'],
+      ['patch', 'node-removed'],
+    ]);
+    expect(suggestion.reasons[1].detail).toBe('stable service removed');
+  });
+});
+
+describe('nextVersion', () => {
+  it('applies each bump, keeping a leading v', () => {
+    expect(nextVersion('1.2.3', 'major')).toBe('2.0.0');
+    expect(nextVersion('1.2.3', 'minor')).toBe('1.3.0');
+    expect(nextVersion('v1.2.3+build.5', 'patch')).toBe('v1.2.4');
+  });
+
+  it('releases a pre-release that already carries the bump', () => {
+    expect(nextVersion('2.0.0-rc.1', 'minor')).toBe('2.0.0');
+    expect(nextVersion('1.3.0-beta', 'major')).toBe('2.0.0');
+    expect(nextVersion('1.2.4-alpha.2', 'patch')).toBe('1.2.4');
+  });
+
+  it('rejects versions that are not MAJOR.MINOR.PATCH', () => {
+    expect(() => nextVersion('1.2', 'patch')).toThrow(/Invalid version/);
+  });
+});
@@ -0,0 +1,8 @@
+export { nextVersion, suggestVersionBump } from './version-bump.js';
+export type {
+  BumpReason,
+  BumpReasonKind,
+  ReleaseSnapshot,
+  VersionBump,
+  VersionSuggestion,
+} from './types.js';
@@ -0,0 +1,53 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for suggesting a semantic version bump from the graph and Go API changes between two refs
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [release, semver, diff, types]
+ * context:
+ *   business_goal: Let release tooling read the suggested bump and its reasons as data
+ *   domain: release
+ */
+import type { GoApiSurface } from '../golang/types.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+
+export type VersionBump = 'major' | 'minor' | 'patch';
+
+export type BumpReasonKind =
+  | 'api-breaking'
+  | 'stable-removed'
+  | 'stable-renamed'
+  | 'api-added'
+  | 'node-added'
+  | 'api-changed'
+  | 'node-updated'
+  | 'node-removed'
+  | 'node-renamed';
+
+/** One change and the bump it calls for. */
+export interface BumpReason {
+  readonly bump: VersionBump;
+  readonly kind: BumpReasonKind;
+  /** The entity's `path:name`, or the Go package and symbol. */
+  readonly subject: string;
+  readonly detail: string;
+}
+
+/** The index and, for Go code, exported API at one ref. */
+export interface ReleaseSnapshot {
+  readonly entities: readonly StoredEntity[];
+  readonly graph: DependencyGraph;
+  readonly api?: GoApiSurface;
+}
+
+export interface VersionSuggestion {
+  /** The largest bump any reason calls for; `patch` when nothing did. */
+  readonly bump: VersionBump;
+  /** Major reasons first, then minor, then patch. */
+  readonly reasons: readonly BumpReason[];
+  readonly current: string | null;
+  /** `current` with `bump` applied, when a current version was given. */
+  readonly next: string | null;
+}
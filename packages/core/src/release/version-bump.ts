@@ -0,0 +1,177 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Suggests a semantic version bump, with reasons, from the graph and Go API changes between two refs
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [release, semver, diff, api]
+ * context:
+ *   business_goal: Explain every suggested version bump by the change that forced it
+ *   domain: release
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { diffGoApi } from '../golang/go-api.js';
+import { diffGraphs } from '../graph/graph-diff.js';
+import type { DependencyGraph, GraphNode } from '../graph/types.js';
+import type {
+  BumpReason,
+  ReleaseSnapshot,
+  VersionBump,
+  VersionSuggestion,
+} from './types.js';
+
+const BUMP_RANK: Readonly<Record<VersionBump, number>> = {
+  major: 0,
+  minor: 1,
+  patch: 2,
+};
+
+const VERSION_PATTERN =
+  /^(v?)(\d+)\.(\d+)\.(\d+)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$/;
+
+function nodeKey(node: Pick<GraphNode, 'id' | 'filePath' | 'name'>): string {
+  return node.filePath ? `${node.filePath}:${node.name}` : node.id;
+}
+
+/**
+ * Entity ids hash the line an entity starts on, so re-key nodes by file
+ * and name; otherwise every entity below an edit would diff as removed
+ * and added again. External stubs carry no API of their own and are left
+ * out.
+ */
+function rekey(graph: DependencyGraph): DependencyGraph {
+  const keys = new Map<string, string>();
+  const nodes: GraphNode[] = [];
+  for (const node of graph.nodes) {
+    if (node.external) continue;
+    const id = nodeKey(node);
+    keys.set(node.id, id);
+    nodes.push({ ...node, id });
+  }
+  const edges = graph.edges.flatMap((edge) => {
+    const from = keys.get(edge.from);
+    const to = keys.get(edge.to);
+    return from && to ? [{ ...edge, from, to }] : [];
+  });
+  return { nodes, edges };
+}
+
+/**
+ * Apply `bump` to `current`, keeping a leading `v`. As in npm's `semver`,
+ * a pre-release is released rather than skipped when it already carries
+ * the bump: `2.0.0-rc.1` bumps to `2.0.0` for any bump.
+ */
+export function nextVersion(current: string, bump: VersionBump): string {
+  const match = VERSION_PATTERN.exec(current.trim());
+  if (!match) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid version '${current}': expected MAJOR.MINOR.PATCH`,
+    );
+  }
+  const [, prefix, major, minor, patch, pre] = match;
+  const [x, y, z] = [Number(major), Number(minor), Number(patch)];
+  if (bump === 'major') {
+    return pre && y === 0 && z === 0
+      ? `${prefix}${x}.0.0`
+      : `${prefix}${x + 1}.0.0`;
+  }
+  if (bump === 'minor') {
+    return pre && z === 0
+      ? `${prefix}${x}.${y}.0`
+      : `${prefix}${x}.${y + 1}.0`;
+  }
+  return pre ? `${prefix}${x}.${y}.${z}` : `${prefix}${x}.${y}.${z + 1}`;
+}
+
+/**
+ * Suggest how far to bump the version between two snapshots: major when a
+ * stable entity is removed or renamed or a stable Go package breaks its
+ * API, minor when entities or exported Go symbols are added, and patch
+ * otherwise. Stability is judged on `before`, matching `diffGoApi`.
+ */
+export function suggestVersionBump(
+  before: ReleaseSnapshot,
+  after: ReleaseSnapshot,
+  current?: string,
+): VersionSuggestion {
+  const stable = new Set(
+    before.entities
+      .filter((entity) => entity.status === 'stable')
+      .map((entity) => nodeKey(entity)),
+  );
+  const reasons: BumpReason[] = [];
+
+  for (const event of diffGraphs(rekey(before.graph), rekey(after.graph))) {
+    const { node } = event;
+    const type = node.entityType ?? 'node';
+    if (event.type === 'node_added') {
+      reasons.push({
+        bump: 'minor',
+        kind: 'node-added',
+        subject: node.id,
+        detail: `new ${type}`,
+      });
+    } else if (event.type === 'node_updated') {
+      reasons.push({
+        bump: 'patch',
+        kind: 'node-updated',
+        subject: node.id,
+        detail: `${type} changed`,
+      });
+    } else if (event.type === 'node_removed') {
+      const isStable = stable.has(node.id);
+      reasons.push({
+        bump: isStable ? 'major' : 'patch',
+        kind: isStable ? 'stable-removed' : 'node-removed',
+        subject: node.id,
+        detail: isStable ? `stable ${type} removed` : `${type} removed`,
+      });
+    } else {
+      const from = event.previous?.id ?? node.id;
+      const isStable = stable.has(from);
+      reasons.push({
+        bump: isStable ? 'major' : 'patch',
+        kind: isStable ? 'stable-renamed' : 'node-renamed',
+        subject: from,
+        detail: `${isStable ? 'stable ' : ''}${type} renamed to ${node.name}`,
+      });
+    }
+  }
+
+  if (before.api && after.api) {
+    for (const change of diffGoApi(before.api, after.api)) {
+      const subject = `${change.importPath}.${change.symbol}`;
+      if (change.breaking) {
+        reasons.push({
+          bump: 'major',
+          kind: 'api-breaking',
+          subject,
+          detail: `${change.kind} ${change.change} in a stable package`,
+        });
+      } else {
+        reasons.push({
+          bump: change.change === 'added' ? 'minor' : 'patch',
+          kind: change.change === 'added' ? 'api-added' : 'api-changed',
+          subject,
+          detail: `${change.kind} ${change.change}`,
+        });
+      }
+    }
+  }
+
+  reasons.sort(
+    (a, b) =>
+      BUMP_RANK[a.bump] - BUMP_RANK[b.bump] ||
+      compareStrings(a.kind, b.kind) ||
+      compareStrings(a.subject, b.subject),
+  );
+  const bump = reasons[0]?.bump ?? 'patch';
+  return {
+    bump,
+    reasons,
+    current: current ?? null,
+    next: current === undefined ? null : nextVersion(current, bump),
+  };
+}
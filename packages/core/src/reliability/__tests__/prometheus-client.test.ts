@@ -0,0 +1,69 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import { createPrometheusClient } from '../prometheus-client.js';
+
+function mockFetch(body: unknown, ok = true): ReturnType<typeof vi.fn> {
+  const fn = vi.fn(async () => ({
+    ok,
+    status: ok ? 200 : 503,
+    statusText: ok ? 'OK' : 'Service Unavailable',
+    json: async () => body,
+  }));
+  vi.stubGlobal('fetch', fn);
+  return fn;
+}
+
+describe('createPrometheusClient', () => {
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('reads the first sample of a vector result', async () => {
+    mockFetch({
+      status: 'success',
+      data: { resultType: 'vector', result: [{ metric: {}, value: [1, '0.999'] }] },
+    });
+    const client = createPrometheusClient({ baseUrl: 'http://prom:9090/' });
+    expect(await client.queryScalar('up')).toBe(0.999);
+  });
+
+  it('reads scalar results', async () => {
+    mockFetch({ status: 'success', data: { resultType: 'scalar', result: [1, '0.5'] } });
+    const client = createPrometheusClient({ baseUrl: 'http://prom:9090' });
+    expect(await client.queryScalar('vector(0.5)')).toBe(0.5);
+  });
+
+  it('returns undefined for empty vectors', async () => {
+    mockFetch({ status: 'success', data: { resultType: 'vector', result: [] } });
+    const client = createPrometheusClient({ baseUrl: 'http://prom:9090' });
+    expect(await client.queryScalar('absent')).toBeUndefined();
+  });
+
+  it('encodes the query and sends the bearer token', async () => {
+    const fn = mockFetch({ status: 'success', data: { resultType: 'vector', result: [] } });
+    const client = createPrometheusClient({
+      baseUrl: 'http://prom:9090',
+      bearerToken: 'secret',
+    });
+    await client.queryScalar('sum(rate(x[5m]))');
+
+    const [url, init] = fn.mock.calls[0] as unknown as [string, RequestInit];
+    expect(url).toBe(
+      'http://prom:9090/api/v1/query?query=sum(rate(x%5B5m%5D))',
+    );
+    expect((init.headers as Record<string, string>).Authorization).toBe(
+      'Bearer secret',
+    );
+  });
+
+  it('throws on HTTP errors', async () => {
+    mockFetch({}, false);
+    const client = createPrometheusClient({ baseUrl: 'http://prom:9090' });
+    await expect(client.queryScalar('up')).rejects.toThrow('503');
+  });
+
+  it('throws on query errors', async () => {
+    mockFetch({ status: 'error', error: 'parse error' });
+    const client = createPrometheusClient({ baseUrl: 'http://prom:9090' });
+    await expect(client.queryScalar('(')).rejects.toThrow('parse error');
+  });
+});
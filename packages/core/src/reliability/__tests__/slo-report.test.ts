@@ -0,0 +1,164 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Slo } from '../../types/entity.js';
+import {
+  buildSloReport,
+  collectSloEntries,
+  computeErrorBudgetRemaining,
+  joinSliData,
+} from '../slo-report.js';
+import type { PrometheusClient } from '../types.js';
+
+function makeEntity(overrides: Partial<StoredEntity> = {}): StoredEntity {
+  return {
+    id: 'id-1',
+    filePath: 'src/checkout.ts',
+    name: 'checkout-service',
+    entityType: 'service',
+    description: 'Checkout service',
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: { type: 'service', description: 'Checkout service' },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+function withSlo(
+  name: string,
+  owner: string | null,
+  slo: Slo,
+): StoredEntity {
+  return makeEntity({
+    id: `id-${name}`,
+    name,
+    owner,
+    metadata: { type: 'service', description: `${name} service`, slo },
+  });
+}
+
+describe('collectSloEntries', () => {
+  it('only includes entities that declare an slo', () => {
+    const entries = collectSloEntries([
+      makeEntity(),
+      withSlo('checkout', 'payments-team', { availability: 99.9 }),
+    ]);
+    expect(entries).toHaveLength(1);
+    expect(entries[0].name).toBe('checkout');
+    expect(entries[0].slo.availability).toBe(99.9);
+  });
+});
+
+describe('buildSloReport', () => {
+  it('groups entries by owner alphabetically with unowned last', () => {
+    const report = buildSloReport([
+      withSlo('search', null, { availability: 99 }),
+      withSlo('checkout', 'payments-team', { availability: 99.9 }),
+      withSlo('login', 'auth-team', { latency_ms: 200 }),
+      withSlo('billing', 'payments-team', { availability: 99.5 }),
+    ]);
+
+    expect(report.totalEntries).toBe(4);
+    expect(report.groups.map((g) => g.owner)).toEqual([
+      'auth-team',
+      'payments-team',
+      '(no owner)',
+    ]);
+    expect(report.groups[1].entries.map((e) => e.name)).toEqual([
+      'billing',
+      'checkout',
+    ]);
+  });
+
+  it('returns an empty report when no slos are declared', () => {
+    const report = buildSloReport([makeEntity()]);
+    expect(report.groups).toHaveLength(0);
+    expect(report.totalEntries).toBe(0);
+  });
+});
+
+describe('computeErrorBudgetRemaining', () => {
+  it('returns the unspent share of the budget', () => {
+    expect(computeErrorBudgetRemaining(99.9, 99.95)).toBe(50);
+  });
+
+  it('goes negative when the budget is blown', () => {
+    expect(computeErrorBudgetRemaining(99, 97)).toBe(-100);
+  });
+
+  it('returns undefined for a 100% target', () => {
+    expect(computeErrorBudgetRemaining(100, 100)).toBeUndefined();
+  });
+});
+
+describe('joinSliData', () => {
+  it('attaches measured availability and error budget', async () => {
+    const client: PrometheusClient = {
+      queryScalar: async () => 0.9995,
+    };
+    const report = buildSloReport([
+      withSlo('checkout', 'payments-team', {
+        availability: 99.9,
+        sli_query: 'availability:checkout',
+      }),
+    ]);
+
+    const joined = await joinSliData(report, client);
+    const sli = joined.groups[0].entries[0].sli;
+    expect(sli?.availability).toBe(99.95);
+    expect(sli?.errorBudgetRemaining).toBe(50);
+  });
+
+  it('skips entries without an sli_query', async () => {
+    let calls = 0;
+    const client: PrometheusClient = {
+      queryScalar: async () => {
+        calls++;
+        return 1;
+      },
+    };
+    const report = buildSloReport([
+      withSlo('checkout', 'payments-team', { availability: 99.9 }),
+    ]);
+
+    const joined = await joinSliData(report, client);
+    expect(calls).toBe(0);
+    expect(joined.groups[0].entries[0].sli).toBeUndefined();
+  });
+
+  it('records query failures on the entry', async () => {
+    const client: PrometheusClient = {
+      queryScalar: async () => {
+        throw new Error('connection refused');
+      },
+    };
+    const report = buildSloReport([
+      withSlo('checkout', 'payments-team', { sli_query: 'up' }),
+    ]);
+
+    const joined = await joinSliData(report, client);
+    expect(joined.groups[0].entries[0].sli?.error).toBe('connection refused');
+  });
+
+  it('records empty query results', async () => {
+    const client: PrometheusClient = { queryScalar: async () => undefined };
+    const report = buildSloReport([
+      withSlo('checkout', 'payments-team', { sli_query: 'up' }),
+    ]);
+
+    const joined = await joinSliData(report, client);
+    expect(joined.groups[0].entries[0].sli?.error).toBe(
+      'Query returned no data',
+    );
+  });
+});
@@ -0,0 +1,15 @@
+export type {
+  SliMeasurement,
+  SloEntry,
+  SloOwnerGroup,
+  SloReport,
+  PrometheusClient,
+  PrometheusClientOptions,
+} from './types.js';
+export {
+  collectSloEntries,
+  buildSloReport,
+  computeErrorBudgetRemaining,
+  joinSliData,
+} from './slo-report.js';
+export { createPrometheusClient } from './prometheus-client.js';
@@ -0,0 +1,69 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Fetch-based Prometheus HTTP API client for instant scalar queries
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [reliability, prometheus, api, sli]
+ * context:
+ *   business_goal: Join live SLI data into SLO reports without extra dependencies
+ *   domain: reliability
+ */
+import type { PrometheusClient, PrometheusClientOptions } from './types.js';
+
+interface PrometheusVectorSample {
+  readonly value?: readonly [number, string];
+}
+
+interface PrometheusQueryResponse {
+  readonly status: string;
+  readonly error?: string;
+  readonly data?: {
+    readonly resultType: string;
+    readonly result: readonly PrometheusVectorSample[] | readonly [number, string];
+  };
+}
+
+function extractValue(body: PrometheusQueryResponse): number | undefined {
+  const data = body.data;
+  if (!data) return undefined;
+
+  let raw: string | undefined;
+  if (data.resultType === 'scalar') {
+    raw = (data.result as readonly [number, string])[1];
+  } else if (data.resultType === 'vector') {
+    const samples = data.result as readonly PrometheusVectorSample[];
+    raw = samples[0]?.value?.[1];
+  }
+
+  if (raw === undefined) return undefined;
+  const value = Number(raw);
+  return Number.isFinite(value) ? value : undefined;
+}
+
+export function createPrometheusClient(
+  options: PrometheusClientOptions,
+): PrometheusClient {
+  const baseUrl = options.baseUrl.replace(/\/+$/, '');
+  const headers: Record<string, string> = { Accept: 'application/json' };
+  if (options.bearerToken) {
+    headers.Authorization = `Bearer ${options.bearerToken}`;
+  }
+
+  return {
+    async queryScalar(promql: string): Promise<number | undefined> {
+      const url = `${baseUrl}/api/v1/query?query=${encodeURIComponent(promql)}`;
+      const response = await fetch(url, { headers });
+      if (!response.ok) {
+        throw new Error(
+          `Prometheus API error: ${response.status} ${response.statusText}`,
+        );
+      }
+      const body = (await response.json()) as PrometheusQueryResponse;
+      if (body.status !== 'success') {
+        throw new Error(`Prometheus query failed: ${body.error ?? 'unknown'}`);
+      }
+      return extractValue(body);
+    },
+  };
+}
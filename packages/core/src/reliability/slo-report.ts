@@ -0,0 +1,135 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Builds SLO reports grouped by owner and joins live SLI data to compute error budgets
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [reliability, slo, report, error-budget]
+ * context:
+ *   business_goal: Show which teams own SLO-bearing services and how much error budget remains
+ *   domain: reliability
+ */
+import type { StoredEntity } from '../indexer/types.js';
+import type { Slo } from '../types/entity.js';
+import type {
+  PrometheusClient,
+  SliMeasurement,
+  SloEntry,
+  SloOwnerGroup,
+  SloReport,
+} from './types.js';
+
+const UNOWNED = '(no owner)';
+
+function getSlo(entity: StoredEntity): Slo | undefined {
+  const { metadata } = entity;
+  if (!('slo' in metadata) || !metadata.slo) return undefined;
+  return metadata.slo;
+}
+
+export function collectSloEntries(
+  entities: readonly StoredEntity[],
+): readonly SloEntry[] {
+  const entries: SloEntry[] = [];
+  for (const entity of entities) {
+    const slo = getSlo(entity);
+    if (!slo) continue;
+    entries.push({
+      entityId: entity.id,
+      name: entity.name,
+      entityType: entity.entityType,
+      filePath: entity.filePath,
+      line: entity.line,
+      owner: entity.owner,
+      slo,
+    });
+  }
+  return entries;
+}
+
+function groupByOwner(entries: readonly SloEntry[]): readonly SloOwnerGroup[] {
+  const groups = new Map<string, SloEntry[]>();
+  for (const entry of entries) {
+    const owner = entry.owner ?? UNOWNED;
+    groups.set(owner, [...(groups.get(owner) ?? []), entry]);
+  }
+
+  return [...groups.entries()]
+    .map(([owner, groupEntries]) => ({
+      owner,
+      entries: [...groupEntries].sort((a, b) => a.name.localeCompare(b.name)),
+    }))
+    .sort((a, b) => {
+      if (a.owner === UNOWNED) return 1;
+      if (b.owner === UNOWNED) return -1;
+      return a.owner.localeCompare(b.owner);
+    });
+}
+
+export function buildSloReport(entities: readonly StoredEntity[]): SloReport {
+  const entries = collectSloEntries(entities);
+  return {
+    groups: groupByOwner(entries),
+    totalEntries: entries.length,
+  };
+}
+
+/**
+ * Percentage of the error budget still unspent, given an availability target
+ * and the measured availability (both as percentages). Negative when blown.
+ */
+export function computeErrorBudgetRemaining(
+  target: number,
+  actual: number,
+): number | undefined {
+  const budget = 100 - target;
+  if (budget <= 0) return undefined;
+  const consumed = 100 - actual;
+  return Math.round(((budget - consumed) / budget) * 1000) / 10;
+}
+
+async function measure(
+  entry: SloEntry,
+  client: PrometheusClient,
+): Promise<SliMeasurement | undefined> {
+  const { sli_query: query, availability: target } = entry.slo;
+  if (!query) return undefined;
+
+  try {
+    const ratio = await client.queryScalar(query);
+    if (ratio === undefined) {
+      return { error: 'Query returned no data' };
+    }
+    const availability = Math.round(ratio * 100_000) / 1000;
+    return {
+      availability,
+      errorBudgetRemaining:
+        target !== undefined
+          ? computeErrorBudgetRemaining(target, availability)
+          : undefined,
+    };
+  } catch (err) {
+    return { error: err instanceof Error ? err.message : String(err) };
+  }
+}
+
+/**
+ * Run each entry's `sli_query` against Prometheus and attach the measured
+ * availability and remaining error budget. Query failures are recorded on the
+ * entry rather than failing the whole report.
+ */
+export async function joinSliData(
+  report: SloReport,
+  client: PrometheusClient,
+): Promise<SloReport> {
+  const groups: SloOwnerGroup[] = [];
+  for (const group of report.groups) {
+    const entries: SloEntry[] = [];
+    for (const entry of group.entries) {
+      const sli = await measure(entry, client);
+      entries.push(sli ? { ...entry, sli } : entry);
+    }
+    groups.push({ owner: group.owner, entries });
+  }
+  return { groups, totalEntries: report.totalEntries };
+}
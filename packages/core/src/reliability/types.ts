@@ -0,0 +1,48 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for SLO reports and live SLI measurements joined from Prometheus
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [reliability, slo, types, interface]
+ * context:
+ *   business_goal: Define contracts for reporting service level objectives per owner
+ *   domain: reliability
+ */
+import type { EntityType, Slo } from '../types/entity.js';
+
+export interface SliMeasurement {
+  readonly availability?: number;
+  readonly errorBudgetRemaining?: number;
+  readonly error?: string;
+}
+
+export interface SloEntry {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  readonly line: number;
+  readonly owner: string | null;
+  readonly slo: Slo;
+  readonly sli?: SliMeasurement;
+}
+
+export interface SloOwnerGroup {
+  readonly owner: string;
+  readonly entries: readonly SloEntry[];
+}
+
+export interface SloReport {
+  readonly groups: readonly SloOwnerGroup[];
+  readonly totalEntries: number;
+}
+
+export interface PrometheusClient {
+  queryScalar(promql: string): Promise<number | undefined>;
+}
+
+export interface PrometheusClientOptions {
+  readonly baseUrl: string;
+  readonly bearerToken?: string;
+}
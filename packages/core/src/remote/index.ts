@@ -0,0 +1,17 @@
+export type {
+  CheckoutOptions,
+  GitCommandRunner,
+  IndexSource,
+  RemoteCheckout,
+  RemoteSource,
+} from './types.js';
+export {
+  checkoutRemote,
+  createGitCommandRunner,
+  isValidGitRef,
+  parseRemoteSource,
+  readIndexSource,
+  recordIndexSource,
+  redactUrl,
+  remoteCacheDir,
+} from './remote-checkout.js';
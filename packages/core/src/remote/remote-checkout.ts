@@ -0,0 +1,201 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Recognizes git URLs as scan targets and keeps shallow checkouts of them in a cache
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [remote, git, clone, cache]
+ * context:
+ *   business_goal: Let platform teams index repositories they do not have checked out
+ *   domain: remote
+ */
+import { spawnSync } from 'node:child_process';
+import { createHash } from 'node:crypto';
+import { existsSync, mkdirSync } from 'node:fs';
+import { join } from 'node:path';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DatabaseManager } from '../indexer/database.js';
+import type {
+  CheckoutOptions,
+  GitCommandRunner,
+  IndexSource,
+  RemoteCheckout,
+  RemoteSource,
+} from './types.js';
+
+const REMOTE_PATTERN = /^(?:(?:https?|ssh|git|file):\/\/|[\w.-]+@[\w.-]+:)/i;
+
+const SOURCE_KEYS = {
+  url: 'source_url',
+  ref: 'source_ref',
+  commit: 'source_commit',
+} as const;
+
+/**
+ * Whether `ref` is safe to hand to git as a branch, tag, or commit: the
+ * rules of `git check-ref-format`, plus no leading `-`, so a ref can
+ * never be read as an option such as `--upload-pack`.
+ */
+export function isValidGitRef(ref: string): boolean {
+  return (
+    ref.length > 0 &&
+    ref !== '@' &&
+    !ref.startsWith('-') &&
+    !ref.startsWith('/') &&
+    !ref.endsWith('/') &&
+    !ref.endsWith('.') &&
+    !ref.includes('..') &&
+    !ref.includes('//') &&
+    !ref.includes('@{') &&
+    ![...ref].some((char) => char <= ' ' || char === '\x7f') &&
+    !/[~^:?*[\\]/.test(ref) &&
+    ref
+      .split('/')
+      .every((part) => !part.startsWith('.') && !part.endsWith('.lock'))
+  );
+}
+
+/**
+ * The git repository `target` names, or undefined for a local path. URLs
+ * (`https://`, `ssh://`, `git://`, `file://`) and scp-style addresses
+ * (`git@host:org/repo.git`) are remote; a `#ref` suffix pins a branch,
+ * tag, or commit. Throws a usage error when the ref is not a valid git
+ * ref name.
+ */
+export function parseRemoteSource(target: string): RemoteSource | undefined {
+  if (!REMOTE_PATTERN.test(target)) return undefined;
+  const hash = target.lastIndexOf('#');
+  if (hash < 0) return { url: target };
+  const url = target.slice(0, hash);
+  const ref = target.slice(hash + 1);
+  if (!ref) return { url };
+  assertValidRef(ref);
+  return { url, ref };
+}
+
+function assertValidRef(ref: string): void {
+  if (!isValidGitRef(ref)) {
+    throw createKnowgraphError(
+      'usage',
+      `"${ref}" is not a valid git branch, tag, or commit`,
+    );
+  }
+}
+
+/** `text` with credentials removed from any URLs, so it is safe to show. */
+export function redactUrl(text: string): string {
+  return text.replace(/\b(https?:\/\/)[^/@\s]*@/gi, '$1');
+}
+
+/**
+ * The checkout directory for `url` under `cacheDir`: a readable name from
+ * the URL plus a short hash, so similar URLs never share a checkout.
+ */
+export function remoteCacheDir(cacheDir: string, url: string): string {
+  const name = redactUrl(url)
+    .replace(/^[a-z]+:\/\//i, '')
+    .replace(/\.git$/, '')
+    .replace(/[^\w.-]+/g, '-')
+    .replace(/^-+|-+$/g, '');
+  const hash = createHash('sha256').update(url).digest('hex').slice(0, 8);
+  return join(cacheDir, `${name}-${hash}`);
+}
+
+/**
+ * Create a runner that shells out to git. Prompts are disabled, so a
+ * repository that needs credentials fails instead of waiting for input.
+ */
+export function createGitCommandRunner(gitPath = 'git'): GitCommandRunner {
+  return {
+    run(args: readonly string[], cwd: string): string {
+      const run = spawnSync(gitPath, args, {
+        cwd,
+        encoding: 'utf-8',
+        env: { ...process.env, GIT_TERMINAL_PROMPT: '0' },
+      });
+      if (run.error) {
+        throw new Error(`git ${args[0]} failed: ${run.error.message}`);
+      }
+      if (run.status !== 0) {
+        // git prints hints after the fatal line that explains the failure
+        const lines = run.stderr.trim().split('\n');
+        const detail =
+          lines.find((line) => line.startsWith('fatal: '))?.slice(7) ??
+          lines.pop() ??
+          '';
+        throw new Error(
+          `git ${args[0]} failed: ${redactUrl(detail) || `exit ${run.status}`}`,
+        );
+      }
+      return run.stdout;
+    },
+  };
+}
+
+/**
+ * Bring the cached checkout of `source` up to date and return it. The
+ * first call initializes the checkout; every call fetches only the pinned
+ * ref (or the default branch) at depth 1 and checks it out detached, so
+ * repeated scans download just what changed. Throws when git fails or
+ * the ref is not a valid git ref name.
+ */
+export function checkoutRemote(
+  source: RemoteSource,
+  options: CheckoutOptions,
+): RemoteCheckout {
+  if (source.ref !== undefined) assertValidRef(source.ref);
+  const git = options.git ?? createGitCommandRunner();
+  const dir = remoteCacheDir(options.cacheDir, source.url);
+  if (existsSync(join(dir, '.git'))) {
+    git.run(['remote', 'set-url', 'origin', source.url], dir);
+  } else {
+    mkdirSync(dir, { recursive: true });
+    git.run(['init', '--quiet'], dir);
+    git.run(['remote', 'add', 'origin', source.url], dir);
+  }
+  // Nothing after --end-of-options is read as an option, whatever it holds
+  git.run(
+    [
+      'fetch',
+      '--quiet',
+      '--depth',
+      '1',
+      '--end-of-options',
+      'origin',
+      source.ref ?? 'HEAD',
+    ],
+    dir,
+  );
+  git.run(['checkout', '--quiet', '--force', '--detach', 'FETCH_HEAD'], dir);
+  const commit = git.run(['rev-parse', 'HEAD'], dir).trim();
+  return {
+    url: redactUrl(source.url),
+    ...(source.ref ? { ref: source.ref } : {}),
+    commit,
+    dir,
+  };
+}
+
+/**
+ * Record in the index which repository and commit it was scanned from, or
+ * clear the record with undefined after scanning a local directory.
+ */
+export function recordIndexSource(
+  dbManager: DatabaseManager,
+  source: IndexSource | undefined,
+): void {
+  dbManager.setMeta(SOURCE_KEYS.url, source ? redactUrl(source.url) : '');
+  dbManager.setMeta(SOURCE_KEYS.ref, source?.ref ?? '');
+  dbManager.setMeta(SOURCE_KEYS.commit, source?.commit ?? '');
+}
+
+/** The source recorded by `recordIndexSource`, if the index has one. */
+export function readIndexSource(
+  dbManager: DatabaseManager,
+): IndexSource | undefined {
+  const url = dbManager.getMeta(SOURCE_KEYS.url);
+  const commit = dbManager.getMeta(SOURCE_KEYS.commit);
+  if (!url || !commit) return undefined;
+  const ref = dbManager.getMeta(SOURCE_KEYS.ref);
+  return ref ? { url, ref, commit } : { url, commit };
+}
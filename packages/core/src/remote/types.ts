@@ -0,0 +1,39 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for git URLs given as scan targets and the cached checkouts made from them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [remote, git, clone, types, interface]
+ * context:
+ *   business_goal: Keep cached checkouts of remote repositories reusable across runs
+ *   domain: remote
+ */
+
+/** A git repository named as a scan target, optionally pinned to a ref. */
+export interface RemoteSource {
+  readonly url: string;
+  /** A branch, tag, or commit; the remote's default branch when omitted. */
+  readonly ref?: string;
+}
+
+/** Where an index was scanned from, recorded alongside it. */
+export interface IndexSource extends RemoteSource {
+  readonly commit: string;
+}
+
+export interface RemoteCheckout extends IndexSource {
+  /** The working tree to scan. */
+  readonly dir: string;
+}
+
+/** Runs git, returning stdout and throwing on failure; swappable in tests. */
+export interface GitCommandRunner {
+  run(args: readonly string[], cwd: string): string;
+}
+
+export interface CheckoutOptions {
+  /** Directory holding one checkout per repository URL. */
+  readonly cacheDir: string;
+  readonly git?: GitCommandRunner;
+}
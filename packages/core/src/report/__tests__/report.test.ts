@@ -0,0 +1,142 @@
+import { describe, it, expect } from 'vitest';
+import type { DependencyGraph } from '../../graph/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { REPORT_FUNCTIONS } from '../functions.js';
+import { buildReportData, renderReport } from '../report.js';
+
+function makeEntity(
+  name: string,
+  owner: string | null,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `The ${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner,
+    status: 'stable',
+    metadata: { type: 'service', description: `The ${name} service` },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00.000Z',
+    updatedAt: '2024-01-01T00:00:00.000Z',
+    ...overrides,
+  };
+}
+
+const entities = [
+  makeEntity('ledger', 'billing', { tags: ['pci', 'core'] }),
+  makeEntity('search', 'discovery', { status: 'deprecated', tags: ['core'] }),
+  makeEntity('invoices', 'billing', { description: 'Bills | and\nreceipts' }),
+  makeEntity('legacy', null),
+];
+
+const graph: DependencyGraph = {
+  nodes: [],
+  edges: [
+    {
+      from: 'id-invoices',
+      to: 'id-ledger',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    },
+  ],
+};
+
+const { filter, groupBy, sortBy, table } = REPORT_FUNCTIONS;
+
+describe('report functions', () => {
+  it('filters by a field value, a list member, or truth', () => {
+    const names = (list: unknown) =>
+      (list as StoredEntity[]).map((entity) => entity.name);
+    expect(names(filter('owner', 'billing', entities))).toEqual([
+      'ledger',
+      'invoices',
+    ]);
+    expect(names(filter('tags', 'core', entities))).toEqual([
+      'ledger',
+      'search',
+    ]);
+    expect(names(filter('owner', entities))).toEqual([
+      'ledger',
+      'search',
+      'invoices',
+    ]);
+    expect(() => filter('owner', 'billing')).toThrow('needs a list');
+  });
+
+  it('groups by a field, with list fields in each group', () => {
+    const byTag = groupBy('tags', entities) as Map<string, StoredEntity[]>;
+    expect(byTag.get('core')?.map((entity) => entity.name)).toEqual([
+      'ledger',
+      'search',
+    ]);
+    const byOwner = groupBy('owner', entities) as Map<string, StoredEntity[]>;
+    expect(byOwner.get('')?.map((entity) => entity.name)).toEqual(['legacy']);
+  });
+
+  it('sorts by a field with missing values last', () => {
+    const sorted = sortBy('owner', entities) as StoredEntity[];
+    expect(sorted.map((entity) => entity.owner)).toEqual([
+      'billing',
+      'billing',
+      'discovery',
+      null,
+    ]);
+  });
+
+  it('renders an escaped Markdown table', () => {
+    expect(
+      table('Name=name', 'description', [entities[2], entities[3]]),
+    ).toBe(
+      [
+        '| Name | description |',
+        '|---|---|',
+        '| invoices | Bills \\| and receipts |',
+        '| legacy | The legacy service |',
+      ].join('\n'),
+    );
+  });
+});
+
+describe('renderReport', () => {
+  it('renders the graph through the helpers', () => {
+    const source = [
+      '# Report {{ .generatedAt }}',
+      '{{ range $owner, $items := groupBy "owner" .entities }}',
+      '## {{ $owner | default "unowned" }} ({{ len $items }})',
+      '{{ $items | sortBy "name" | table "Service=name" "tags" }}',
+      '{{ end -}}',
+      'Deprecated: {{ range filter "status" "deprecated" .entities }}{{ .name }}{{ end }}',
+      'Edges: {{ len .edges }}',
+    ].join('\n');
+    const output = renderReport(source, {
+      entities,
+      graph,
+      now: new Date('2024-07-01T00:00:00.000Z'),
+    });
+    expect(output).toContain('# Report 2024-07-01T00:00:00.000Z');
+    expect(output).toContain('## unowned (1)');
+    expect(output).toContain(
+      '## billing (2)\n| Service | tags |\n|---|---|\n| invoices |  |\n| ledger | pci, core |',
+    );
+    expect(output).toContain('Deprecated: search\nEdges: 1');
+  });
+
+  it('exposes entities, nodes, and edges', () => {
+    const report = buildReportData({ entities, graph, now: new Date(0) });
+    expect(report.generatedAt).toBe('1970-01-01T00:00:00.000Z');
+    expect(report.entities).toHaveLength(4);
+    expect(report.edges).toHaveLength(1);
+  });
+});
@@ -0,0 +1,132 @@
+import { describe, it, expect } from 'vitest';
+import { parseTemplate, renderTemplate } from '../template.js';
+
+const data = {
+  name: 'payments',
+  owner: null,
+  team: { lead: 'ana', size: 4 },
+  items: ['a', 'b', 'c'],
+  scores: { search: 80, billing: 92 },
+};
+
+describe('renderTemplate', () => {
+  it('prints fields, chains, and literals', () => {
+    expect(
+      renderTemplate('{{ .name }} led by {{ .team.lead }} ({{ 3 }})', data),
+    ).toBe('payments led by ana (3)');
+    expect(renderTemplate('{{ .owner }}|{{ .missing }}', data)).toBe(
+      '<nil>|<no value>',
+    );
+    expect(renderTemplate('{{ .items }} {{ .team }}', data)).toBe(
+      '[a b c] map[lead:ana size:4]',
+    );
+  });
+
+  it('pipes values into the last argument', () => {
+    expect(
+      renderTemplate('{{ .team.size | printf "%s has %d" .name }}', data),
+    ).toBe('payments has 4');
+    expect(
+      renderTemplate('{{ printf "%-4s|%03d|%.1f" "ab" 7 3.14159 }}', data),
+    ).toBe('ab  |007|3.1');
+    expect(renderTemplate('{{ len .items | eq 3 }}', data)).toBe('true');
+  });
+
+  it('branches with if, else if, and with', () => {
+    const source =
+      '{{ if gt .team.size 5 }}big{{ else if .team.size }}small{{ else }}none{{ end }}';
+    expect(renderTemplate(source, data)).toBe('small');
+    expect(
+      renderTemplate('{{ with .team }}{{ .lead }}{{ else }}-{{ end }}', data),
+    ).toBe('ana');
+    expect(
+      renderTemplate(
+        '{{ with .owner }}{{ . }}{{ else }}unowned{{ end }}',
+        data,
+      ),
+    ).toBe('unowned');
+  });
+
+  it('ranges over lists, maps by key, and counts', () => {
+    expect(
+      renderTemplate(
+        '{{ range $i, $x := .items }}{{ $i }}{{ $x }} {{ end }}',
+        data,
+      ),
+    ).toBe('0a 1b 2c ');
+    expect(
+      renderTemplate(
+        '{{ range $k, $v := .scores }}{{ $k }}={{ $v }};{{ end }}',
+        data,
+      ),
+    ).toBe('billing=92;search=80;');
+    expect(renderTemplate('{{ range 3 }}{{ . }}{{ end }}', data)).toBe('012');
+    expect(
+      renderTemplate('{{ range .none }}x{{ else }}empty{{ end }}', data),
+    ).toBe('empty');
+  });
+
+  it('stops and skips with break and continue', () => {
+    const source =
+      '{{ range .items }}{{ if eq . "b" }}{{ continue }}{{ end }}{{ if eq . "c" }}{{ break }}{{ end }}{{ . }}{{ end }}';
+    expect(renderTemplate(source, data)).toBe('a');
+  });
+
+  it('scopes variables to their block and assigns to outer ones', () => {
+    const source =
+      '{{ $total := 0 }}{{ range .items }}{{ $total = . }}{{ end }}{{ $total }}{{ $ | len }}';
+    expect(renderTemplate(source, data)).toBe('c5');
+    expect(() =>
+      renderTemplate('{{ if true }}{{ $x := 1 }}{{ end }}{{ $x }}', data),
+    ).toThrow('undefined variable "$x"');
+  });
+
+  it('evaluates and and or lazily', () => {
+    expect(
+      renderTemplate('{{ if and .items (index .items 0) }}yes{{ end }}', data),
+    ).toBe('yes');
+    expect(renderTemplate('{{ and .none (index .none 0) }}', data)).toBe(
+      '<no value>',
+    );
+    expect(renderTemplate('{{ or .owner "nobody" }}', data)).toBe('nobody');
+  });
+
+  it('trims whitespace and drops comments', () => {
+    const source =
+      'a  {{- /* note */ -}}  b\n{{- range .items }}\n- {{ . }}{{ end }}';
+    expect(renderTemplate(source, data)).toBe('ab\n- a\n- b\n- c');
+  });
+
+  it('defines and calls named templates', () => {
+    const template = parseTemplate(
+      '{{ define "row" }}<{{ .lead }}>{{ end }}{{ template "row" .team }}{{ block "tail" . }}!{{ .name }}{{ end }}',
+    );
+    expect(template.templates).toEqual(['row', 'tail']);
+    expect(template.execute(data)).toBe('<ana>!payments');
+  });
+
+  it('reports syntax and execution errors with the line', () => {
+    expect(() => parseTemplate('a\n{{ if .x }}', { name: 'r.md' })).toThrow(
+      'template: r.md:2: unexpected EOF',
+    );
+    expect(() => parseTemplate('{{ nope . }}')).toThrow(
+      'function "nope" not defined',
+    );
+    expect(() => parseTemplate('{{ end }}')).toThrow('unexpected {{end}}');
+    expect(() => parseTemplate('{{ break }}')).toThrow('outside {{range}}');
+    expect(() => renderTemplate('\n\n{{ index .items 9 }}', data)).toThrow(
+      'template: template:3: error calling index: index out of range: 9',
+    );
+    expect(() => renderTemplate('{{ .name.first }}', data)).toThrow(
+      "can't evaluate field first in type string",
+    );
+  });
+
+  it('calls added functions', () => {
+    expect(
+      renderTemplate('{{ .name | shout }}', data, {
+        functions: { shout: (value) => `${String(value)}!` },
+      }),
+    ).toBe('payments!');
+  });
+});
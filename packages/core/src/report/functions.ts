@@ -0,0 +1,154 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Template helpers for reports that filter, group, sort, and tabulate lists of entities, nodes, and edges
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [report, template, helpers, markdown]
+ * context:
+ *   business_goal: Cover the filtering and tabulating most reports need so templates stay short
+ *   domain: report
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { formatValue, isTrue } from './template.js';
+import type { TemplateFunctions } from './types.js';
+
+/** The value at a dotted `path` such as `metadata.domain`. */
+function valueAt(item: unknown, path: string): unknown {
+  let value = item;
+  for (const key of path.split('.')) {
+    if (value === null || typeof value !== 'object') return undefined;
+    value = (value as Record<string, unknown>)[key];
+  }
+  return value;
+}
+
+/** The list a helper works on, which comes last so it can be piped in. */
+function listArg(name: string, args: readonly unknown[]): readonly unknown[] {
+  const list = args.at(-1);
+  if (list === undefined || list === null) return [];
+  if (!Array.isArray(list)) {
+    throw new Error(`${name} needs a list, got ${formatValue(list)}`);
+  }
+  return list;
+}
+
+function pathArg(name: string, value: unknown): string {
+  if (typeof value !== 'string' || value === '') {
+    throw new Error(`${name} needs a field name`);
+  }
+  return value;
+}
+
+function matches(value: unknown, wanted: unknown): boolean {
+  return Array.isArray(value) ? value.includes(wanted) : value === wanted;
+}
+
+function compareEntries(a: unknown, b: unknown): number {
+  const missingA = a === undefined || a === null;
+  const missingB = b === undefined || b === null;
+  if (missingA || missingB) return Number(missingA) - Number(missingB);
+  if (typeof a === 'number' && typeof b === 'number') return a - b;
+  return compareStrings(formatValue(a), formatValue(b));
+}
+
+function cellText(value: unknown): string {
+  if (value === undefined || value === null) return '';
+  if (Array.isArray(value)) return value.map(cellText).join(', ');
+  if (typeof value === 'object') return JSON.stringify(value);
+  return String(value);
+}
+
+function markdownCell(value: unknown): string {
+  return cellText(value).replace(/\|/g, '\\|').replace(/\s*\n\s*/g, ' ');
+}
+
+/**
+ * Helpers for report templates. Each takes the list it works on last, so
+ * `{{ .entities | filter "status" "deprecated" | sortBy "name" }}` works.
+ */
+export const REPORT_FUNCTIONS: TemplateFunctions = {
+  /**
+   * `filter field [value] list`: the items whose field equals `value`, or
+   * contains it when the field is a list, or is true when no value is given.
+   */
+  filter: (...args) => {
+    if (args.length < 2 || args.length > 3) {
+      throw new Error('filter takes a field, an optional value, and a list');
+    }
+    const path = pathArg('filter', args[0]);
+    return listArg('filter', args).filter((item) =>
+      args.length === 3
+        ? matches(valueAt(item, path), args[1])
+        : isTrue(valueAt(item, path)),
+    );
+  },
+  /**
+   * `groupBy field list`: a map from each value of the field to its items.
+   * Ranging over it goes key by key; an item with a list field joins each
+   * of its groups, and one without a value joins the `""` group.
+   */
+  groupBy: (...args) => {
+    if (args.length !== 2) throw new Error('groupBy takes a field and a list');
+    const path = pathArg('groupBy', args[0]);
+    const groups = new Map<string, unknown[]>();
+    for (const item of listArg('groupBy', args)) {
+      const value = valueAt(item, path);
+      const keys = Array.isArray(value) ? value : [value];
+      for (const key of keys) {
+        const name = key === undefined || key === null ? '' : String(key);
+        groups.set(name, [...(groups.get(name) ?? []), item]);
+      }
+    }
+    return groups;
+  },
+  /** `sortBy field list`: a copy sorted by the field, missing values last. */
+  sortBy: (...args) => {
+    if (args.length !== 2) throw new Error('sortBy takes a field and a list');
+    const path = pathArg('sortBy', args[0]);
+    return [...listArg('sortBy', args)].sort((a, b) =>
+      compareEntries(valueAt(a, path), valueAt(b, path)),
+    );
+  },
+  /** `reverse list`: the list backwards. */
+  reverse: (...args) => [...listArg('reverse', args)].reverse(),
+  /**
+   * `table column... list`: a Markdown table with a row per item. A column
+   * is a field, or `Header=field` to name it; list fields join with commas.
+   */
+  table: (...args) => {
+    const columns = args.slice(0, -1).map((spec) => {
+      const text = pathArg('table', spec);
+      const split = text.indexOf('=');
+      return split === -1
+        ? { header: text, path: text }
+        : { header: text.slice(0, split), path: text.slice(split + 1) };
+    });
+    if (columns.length === 0) throw new Error('table needs a column');
+    const rows = listArg('table', args).map((item) => {
+      const cells = columns.map(({ path }) =>
+        markdownCell(valueAt(item, path)),
+      );
+      return `| ${cells.join(' | ')} |`;
+    });
+    return [
+      `| ${columns.map(({ header }) => markdownCell(header)).join(' | ')} |`,
+      `|${columns.map(() => '---').join('|')}|`,
+      ...rows,
+    ].join('\n');
+  },
+  /** `join separator list`: the items as text, separated. */
+  join: (...args) => {
+    if (args.length !== 2) throw new Error('join takes a separator and a list');
+    return listArg('join', args).map(cellText).join(String(args[0]));
+  },
+  upper: (...args) => args.map(cellText).join(' ').toUpperCase(),
+  lower: (...args) => args.map(cellText).join(' ').toLowerCase(),
+  /** `default fallback value`: the value, or the fallback when it is empty. */
+  default: (...args) => {
+    if (args.length !== 2) {
+      throw new Error('default takes a fallback and a value');
+    }
+    return isTrue(args[1]) ? args[1] : args[0];
+  },
+};
@@ -0,0 +1,19 @@
+export type {
+  TemplateFunction,
+  TemplateFunctions,
+  TemplateOptions,
+  CompiledTemplate,
+  ReportData,
+  ReportInput,
+} from './types.js';
+export {
+  BUILTIN_FUNCTIONS,
+  parseTemplate,
+  renderTemplate,
+} from './template.js';
+export { REPORT_FUNCTIONS } from './functions.js';
+export {
+  buildReportData,
+  parseReportTemplate,
+  renderReport,
+} from './report.js';
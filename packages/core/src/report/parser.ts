@@ -0,0 +1,539 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Lexes and parses templates in Go text/template syntax into actions, pipelines, and control structures
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [report, template, parser, lexer]
+ * context:
+ *   business_goal: Accept the template syntax Helm and Go users already write, with errors that point at the line
+ *   domain: report
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type { TemplateFunctions } from './types.js';
+
+type TokenKind =
+  | 'field'
+  | 'variable'
+  | 'ident'
+  | 'literal'
+  | 'dot'
+  | 'pipe'
+  | 'lparen'
+  | 'rparen'
+  | 'declare'
+  | 'assign'
+  | 'comma';
+
+interface Token {
+  readonly kind: TokenKind;
+  readonly text: string;
+  readonly value?: string | number;
+  /** Whether whitespace precedes it; `.a.b` chains only unspaced fields. */
+  readonly spaced: boolean;
+}
+
+type Item =
+  | { readonly type: 'text'; readonly text: string }
+  | {
+      readonly type: 'action';
+      readonly tokens: readonly Token[];
+      readonly line: number;
+    };
+
+export type Arg =
+  | { readonly type: 'dot' }
+  | { readonly type: 'field'; readonly chain: readonly string[] }
+  | {
+      readonly type: 'variable';
+      readonly name: string;
+      readonly chain: readonly string[];
+    }
+  | { readonly type: 'literal'; readonly value: unknown }
+  | { readonly type: 'function'; readonly name: string }
+  | {
+      readonly type: 'pipe';
+      readonly pipe: Pipeline;
+      readonly chain: readonly string[];
+    };
+
+export interface Pipeline {
+  readonly decl: readonly string[];
+  readonly assign: boolean;
+  readonly cmds: readonly (readonly Arg[])[];
+}
+
+export type Node =
+  | { readonly type: 'text'; readonly text: string }
+  | { readonly type: 'action'; readonly pipe: Pipeline; readonly line: number }
+  | {
+      readonly type: 'if' | 'with' | 'range';
+      readonly pipe: Pipeline;
+      readonly list: readonly Node[];
+      readonly elseList: readonly Node[] | null;
+      readonly line: number;
+    }
+  | {
+      readonly type: 'template';
+      readonly name: string;
+      readonly pipe: Pipeline | null;
+      readonly line: number;
+    }
+  | { readonly type: 'break' | 'continue'; readonly line: number };
+
+const KEYWORDS = new Set([
+  'if',
+  'else',
+  'end',
+  'range',
+  'with',
+  'define',
+  'block',
+  'template',
+  'break',
+  'continue',
+]);
+
+const LITERALS: Readonly<Record<string, unknown>> = {
+  true: true,
+  false: false,
+  nil: null,
+};
+
+export function templateError(
+  name: string,
+  line: number,
+  message: string,
+): Error {
+  return createKnowgraphError('parse', `template: ${name}:${line}: ${message}`);
+}
+
+function isSpace(ch: string | undefined): boolean {
+  return ch !== undefined && /\s/.test(ch);
+}
+
+function countLines(text: string): number {
+  return text.split('\n').length - 1;
+}
+
+/** The index just past the quoted literal opening at `start`. */
+function skipQuoted(source: string, start: number): number {
+  const quote = source[start];
+  let i = start + 1;
+  while (i < source.length && source[i] !== quote) {
+    i += quote === '"' && source[i] === '\\' ? 2 : 1;
+  }
+  return i + 1;
+}
+
+function lexAction(
+  body: string,
+  fail: (message: string) => never,
+): readonly Token[] {
+  const tokens: Token[] = [];
+  let spaced = true;
+  let i = 0;
+  const push = (kind: TokenKind, text: string, value?: string | number) => {
+    tokens.push({ kind, text, value, spaced });
+    spaced = false;
+    i += text.length;
+  };
+  while (i < body.length) {
+    const rest = body.slice(i);
+    const ch = body[i];
+    if (isSpace(ch)) {
+      spaced = true;
+      i += 1;
+      continue;
+    }
+    const field = /^\.[A-Za-z_]\w*/.exec(rest);
+    const number =
+      /^[-+]?(?:0[xX][0-9a-fA-F]+|\d+(?:\.\d+)?(?:[eE][-+]?\d+)?)/.exec(rest);
+    const word = /^[A-Za-z_]\w*/.exec(rest);
+    if (field) {
+      push('field', field[0], field[0].slice(1));
+    } else if (ch === '.') {
+      push('dot', '.');
+    } else if (ch === '$') {
+      push('variable', /^\$\w*/.exec(rest)?.[0] ?? '$');
+    } else if (ch === '"' || ch === '`') {
+      const end = skipQuoted(body, i);
+      if (end > body.length) fail('unterminated quoted string');
+      const text = body.slice(i, end);
+      let value: string;
+      try {
+        value = ch === '"' ? (JSON.parse(text) as string) : text.slice(1, -1);
+      } catch {
+        fail(`invalid string ${text}`);
+      }
+      push('literal', text, value);
+    } else if (number) {
+      push('literal', number[0], Number(number[0]));
+    } else if (word) {
+      push('ident', word[0]);
+    } else if (rest.startsWith(':=')) {
+      push('declare', ':=');
+    } else {
+      const kinds: Readonly<Record<string, TokenKind>> = {
+        '=': 'assign',
+        '|': 'pipe',
+        '(': 'lparen',
+        ')': 'rparen',
+        ',': 'comma',
+      };
+      const kind = kinds[ch];
+      if (!kind) fail(`unexpected "${ch}" in action`);
+      push(kind, ch);
+    }
+  }
+  return tokens;
+}
+
+/**
+ * Split `source` into text and actions, applying `{{-` and `-}}` trim
+ * markers and dropping comments.
+ */
+function scan(source: string, name: string): readonly Item[] {
+  const items: Item[] = [];
+  let pos = 0;
+  let line = 1;
+  let trimNext = false;
+  const pushText = (text: string) => {
+    if (text !== '') items.push({ type: 'text', text });
+  };
+  while (pos < source.length) {
+    const open = source.indexOf('{{', pos);
+    const raw = source.slice(pos, open === -1 ? undefined : open);
+    let text = trimNext ? raw.trimStart() : raw;
+    if (open === -1) {
+      pushText(text);
+      break;
+    }
+    line += countLines(raw);
+    let start = open + 2;
+    if (source[start] === '-' && isSpace(source[start + 1])) {
+      text = text.trimEnd();
+      start += 1;
+    }
+    pushText(text);
+
+    const fail = (message: string): never => {
+      throw templateError(name, line, message);
+    };
+    let close = start;
+    const comment = source.slice(start).trimStart().startsWith('/*');
+    if (comment) {
+      const end = source.indexOf('*/', start);
+      if (end === -1) fail('unclosed comment');
+      close = end + 2;
+      while (isSpace(source[close])) close += 1;
+      if (source[close] === '-') close += 1;
+    }
+    while (close < source.length && !source.startsWith('}}', close)) {
+      close =
+        source[close] === '"' || source[close] === '`'
+          ? skipQuoted(source, close)
+          : close + 1;
+    }
+    if (close >= source.length) fail('unclosed action');
+    trimNext = source[close - 1] === '-' && isSpace(source[close - 2]);
+    const body = source.slice(start, trimNext ? close - 1 : close);
+    if (!comment) {
+      const tokens = lexAction(body, fail);
+      if (tokens.length === 0) fail('missing value for command');
+      items.push({ type: 'action', tokens, line });
+    }
+    line += countLines(body);
+    pos = close + 2;
+  }
+  return items;
+}
+
+interface TokenStream {
+  peek(offset?: number): Token | undefined;
+  next(): Token | undefined;
+  done(): boolean;
+  fail(message: string): never;
+}
+
+function createTokenStream(
+  tokens: readonly Token[],
+  fail: (message: string) => never,
+): TokenStream {
+  let index = 0;
+  return {
+    peek: (offset = 0) => tokens[index + offset],
+    next: () => {
+      index += 1;
+      return tokens[index - 1];
+    },
+    done: () => index >= tokens.length,
+    fail,
+  };
+}
+
+interface ListEnd {
+  readonly keyword: string;
+  readonly rest: readonly Token[];
+  readonly line: number;
+}
+
+export interface ParsedTemplate {
+  readonly nodes: readonly Node[];
+  readonly templates: ReadonlyMap<string, readonly Node[]>;
+}
+
+/**
+ * Parse `source` into its main body and the templates it defines. Calls to
+ * functions missing from `functions`, undefined variables, and syntax
+ * errors throw a `parse` error with the line.
+ */
+export function parseSource(
+  source: string,
+  name: string,
+  functions: TemplateFunctions,
+): ParsedTemplate {
+  const items = scan(source, name);
+  const templates = new Map<string, readonly Node[]>();
+  let index = 0;
+  let rangeDepth = 0;
+  let vars: string[] = ['$'];
+
+  const fail = (line: number, message: string): never => {
+    throw templateError(name, line, message);
+  };
+  const stream = (tokens: readonly Token[], line: number) =>
+    createTokenStream(tokens, (message) => fail(line, message));
+
+  /** Nodes up to an action starting with one of `terminators`. */
+  function parseList(terminators: readonly string[]): {
+    readonly nodes: readonly Node[];
+    readonly end: ListEnd | null;
+  } {
+    const nodes: Node[] = [];
+    while (index < items.length) {
+      const item = items[index];
+      index += 1;
+      if (item.type === 'text') {
+        nodes.push(item);
+        continue;
+      }
+      const [first, ...rest] = item.tokens;
+      const keyword = first.kind === 'ident' ? first.text : '';
+      if (keyword === 'end' || keyword === 'else') {
+        if (!terminators.includes(keyword)) {
+          fail(item.line, `unexpected {{${keyword}}}`);
+        }
+        return { nodes, end: { keyword, rest, line: item.line } };
+      }
+      const node = parseAction(keyword, rest, item);
+      if (node) nodes.push(node);
+    }
+    if (terminators.length > 0) {
+      const actions = items.filter((item) => item.type === 'action');
+      const last = actions.at(-1);
+      fail(last?.type === 'action' ? last.line : 1, 'unexpected EOF');
+    }
+    return { nodes, end: null };
+  }
+
+  function parseAction(
+    keyword: string,
+    rest: readonly Token[],
+    item: Extract<Item, { type: 'action' }>,
+  ): Node | null {
+    const { line } = item;
+    switch (keyword) {
+      case 'if':
+      case 'with':
+      case 'range':
+        return parseControl(keyword, rest, line);
+      case 'break':
+      case 'continue':
+        if (rangeDepth === 0) fail(line, `{{${keyword}}} outside {{range}}`);
+        if (rest.length > 0) fail(line, `unexpected "${rest[0].text}"`);
+        return { type: keyword, line };
+      case 'define':
+      case 'block': {
+        const tokens = stream(rest, line);
+        const template = templateName(tokens, keyword);
+        const pipe =
+          keyword === 'block' ? parsePipeline(tokens, keyword) : null;
+        defineTemplate(template, line);
+        return pipe ? { type: 'template', name: template, pipe, line } : null;
+      }
+      case 'template': {
+        const tokens = stream(rest, line);
+        const template = templateName(tokens, keyword);
+        const pipe = tokens.done() ? null : parsePipeline(tokens, keyword);
+        if (!tokens.done()) fail(line, `unexpected "${tokens.peek()?.text}"`);
+        return { type: 'template', name: template, pipe, line };
+      }
+      default: {
+        const tokens = stream(item.tokens, line);
+        const pipe = parsePipeline(tokens, 'command');
+        if (!tokens.done()) fail(line, `unexpected "${tokens.peek()?.text}"`);
+        return { type: 'action', pipe, line };
+      }
+    }
+  }
+
+  function templateName(tokens: TokenStream, keyword: string): string {
+    const token = tokens.next();
+    if (token?.kind !== 'literal' || typeof token.value !== 'string') {
+      return tokens.fail(`missing template name in {{${keyword}}}`);
+    }
+    return token.value;
+  }
+
+  /** Read the body of a `define` or `block` up to its `end`. */
+  function defineTemplate(template: string, line: number): void {
+    const outer = { vars, rangeDepth };
+    vars = ['$'];
+    rangeDepth = 0;
+    const { nodes, end } = parseList(['end']);
+    if (end && end.rest.length > 0) {
+      fail(end.line, `unexpected "${end.rest[0].text}" in end`);
+    }
+    if (templates.has(template)) {
+      fail(line, `template "${template}" is already defined`);
+    }
+    templates.set(template, nodes);
+    ({ vars, rangeDepth } = outer);
+  }
+
+  function parseControl(
+    type: 'if' | 'with' | 'range',
+    rest: readonly Token[],
+    line: number,
+  ): Node {
+    const scope = vars.length;
+    const tokens = stream(rest, line);
+    const pipe = parsePipeline(tokens, type);
+    if (!tokens.done()) fail(line, `unexpected "${tokens.peek()?.text}"`);
+    if (type === 'range') rangeDepth += 1;
+    const body = parseList(['else', 'end']);
+    if (type === 'range') rangeDepth -= 1;
+    vars.length = scope;
+
+    let elseList: readonly Node[] | null = null;
+    const end = body.end;
+    const chained = end?.rest[0];
+    if (end?.keyword === 'else' && chained?.text === type && type !== 'range') {
+      // `else if` and `else with` share the enclosing end
+      elseList = [parseControl(type, end.rest.slice(1), end.line)];
+    } else if (end && end.rest.length > 0) {
+      fail(end.line, `unexpected "${end.rest[0].text}" in ${end.keyword}`);
+    } else if (end?.keyword === 'else') {
+      elseList = parseList(['end']).nodes;
+      vars.length = scope;
+    }
+    return { type, pipe, list: body.nodes, elseList, line };
+  }
+
+  function parsePipeline(
+    tokens: TokenStream,
+    context: string,
+    closing = false,
+  ): Pipeline {
+    const decl: string[] = [];
+    let assign = false;
+    const declares = (offset: number) => {
+      const kind = tokens.peek(offset)?.kind;
+      return kind === 'declare' || kind === 'assign';
+    };
+    const declare = () => {
+      decl.push(tokens.next()?.text ?? '$');
+    };
+    if (tokens.peek()?.kind === 'variable') {
+      if (declares(1)) {
+        declare();
+        assign = tokens.next()?.kind === 'assign';
+      } else if (
+        context === 'range' &&
+        tokens.peek(1)?.kind === 'comma' &&
+        tokens.peek(2)?.kind === 'variable' &&
+        declares(3)
+      ) {
+        declare();
+        tokens.next();
+        declare();
+        assign = tokens.next()?.kind === 'assign';
+      }
+    }
+    const undefinedVariable = decl.find((variable) => !vars.includes(variable));
+    if (assign && undefinedVariable) {
+      tokens.fail(`undefined variable "${undefinedVariable}"`);
+    }
+
+    const cmds: Arg[][] = [];
+    for (;;) {
+      const args: Arg[] = [];
+      while (!tokens.done()) {
+        const kind = tokens.peek()?.kind;
+        if (kind === 'pipe' || (closing && kind === 'rparen')) break;
+        args.push(parseArg(tokens));
+      }
+      if (args.length === 0) tokens.fail(`missing value for ${context}`);
+      cmds.push(args);
+      if (tokens.peek()?.kind !== 'pipe') break;
+      tokens.next();
+    }
+    if (!assign) vars.push(...decl);
+    return { decl, assign, cmds };
+  }
+
+  /** The fields directly after an argument, as in `$x.a.b` or `(...).a`. */
+  function chain(tokens: TokenStream): readonly string[] {
+    const fields: string[] = [];
+    for (;;) {
+      const token = tokens.peek();
+      if (token?.kind !== 'field' || token.spaced) return fields;
+      tokens.next();
+      fields.push(String(token.value));
+    }
+  }
+
+  function parseArg(tokens: TokenStream): Arg {
+    const token = tokens.next();
+    if (!token) return tokens.fail('unexpected end of action');
+    switch (token.kind) {
+      case 'dot':
+        return { type: 'dot' };
+      case 'field':
+        return {
+          type: 'field',
+          chain: [String(token.value), ...chain(tokens)],
+        };
+      case 'variable':
+        if (!vars.includes(token.text)) {
+          tokens.fail(`undefined variable "${token.text}"`);
+        }
+        return { type: 'variable', name: token.text, chain: chain(tokens) };
+      case 'literal':
+        return { type: 'literal', value: token.value };
+      case 'ident':
+        if (token.text in LITERALS) {
+          return { type: 'literal', value: LITERALS[token.text] };
+        }
+        if (KEYWORDS.has(token.text)) {
+          tokens.fail(`unexpected {{${token.text}}}`);
+        }
+        if (!(token.text in functions)) {
+          tokens.fail(`function "${token.text}" not defined`);
+        }
+        return { type: 'function', name: token.text };
+      case 'lparen': {
+        const pipe = parsePipeline(tokens, 'parenthesized pipeline', true);
+        if (tokens.next()?.kind !== 'rparen') {
+          tokens.fail('unclosed left paren');
+        }
+        return { type: 'pipe', pipe, chain: chain(tokens) };
+      }
+      default:
+        return tokens.fail(`unexpected "${token.text}"`);
+    }
+  }
+
+  return { nodes: parseList([]).nodes, templates };
+}
@@ -0,0 +1,49 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Renders report templates against the indexed entities and dependency graph with the report helpers
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [report, template, graph]
+ * context:
+ *   business_goal: Give every report template the same view of entities and dependencies
+ *   domain: report
+ */
+import { REPORT_FUNCTIONS } from './functions.js';
+import { parseTemplate } from './template.js';
+import type {
+  CompiledTemplate,
+  ReportData,
+  ReportInput,
+  TemplateOptions,
+} from './types.js';
+
+/** The data a report template renders: `.entities`, `.nodes`, and `.edges`. */
+export function buildReportData(input: ReportInput): ReportData {
+  return {
+    generatedAt: (input.now ?? new Date()).toISOString(),
+    entities: input.entities,
+    nodes: input.graph.nodes,
+    edges: input.graph.edges,
+  };
+}
+
+/** Parse a report template, with the report helpers on top of Go's. */
+export function parseReportTemplate(
+  source: string,
+  options: TemplateOptions = {},
+): CompiledTemplate {
+  return parseTemplate(source, {
+    ...options,
+    functions: { ...REPORT_FUNCTIONS, ...options.functions },
+  });
+}
+
+/** Render a report template against the graph. */
+export function renderReport(
+  source: string,
+  input: ReportInput,
+  options: TemplateOptions = {},
+): string {
+  return parseReportTemplate(source, options).execute(buildReportData(input));
+}
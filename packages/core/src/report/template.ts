@@ -0,0 +1,463 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Renders templates in Go text/template syntax, with Go's truth rules, value printing, and built-in functions
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [report, template, interpreter]
+ * context:
+ *   business_goal: Render templates the way Go would, so ones written for Go tools work unchanged
+ *   domain: report
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { parseSource, templateError } from './parser.js';
+import type { Arg, Node, ParsedTemplate, Pipeline } from './parser.js';
+import type {
+  CompiledTemplate,
+  TemplateFunctions,
+  TemplateOptions,
+} from './types.js';
+
+/**
+ * Go's truth: false, 0, nil, and empty strings, lists, and maps are
+ * false; everything else is true.
+ */
+export function isTrue(value: unknown): boolean {
+  if (value === undefined || value === null) return false;
+  if (Array.isArray(value) || typeof value === 'string') {
+    return value.length > 0;
+  }
+  if (value instanceof Map) return value.size > 0;
+  if (typeof value === 'object') return Object.keys(value).length > 0;
+  return Boolean(value);
+}
+
+/** A value printed the way Go's `%v` prints it. */
+export function formatValue(value: unknown): string {
+  if (value === undefined) return '<no value>';
+  if (value === null) return '<nil>';
+  if (Array.isArray(value)) {
+    return `[${value.map((entry) => formatValue(entry)).join(' ')}]`;
+  }
+  if (value instanceof Map || typeof value === 'object') {
+    const entries = entriesOf(value);
+    const pairs = entries.map(([key, entry]) => `${key}:${formatValue(entry)}`);
+    return `map[${pairs.join(' ')}]`;
+  }
+  return String(value);
+}
+
+/** The entries of a map or object, by key, as Go ranges over maps. */
+function entriesOf(value: object): readonly (readonly [string, unknown])[] {
+  const entries =
+    value instanceof Map
+      ? [...value.entries()].map(
+          ([key, entry]) => [String(key), entry] as const,
+        )
+      : Object.entries(value);
+  return entries.sort(([a], [b]) => compareStrings(a, b));
+}
+
+function expectArgs(
+  args: readonly unknown[],
+  min: number,
+  max: number = min,
+): void {
+  if (args.length < min || args.length > max) {
+    const want = min === max ? `${min}` : `${min} to ${max}`;
+    throw new Error(`wrong number of args: want ${want}, got ${args.length}`);
+  }
+}
+
+function compareValues(a: unknown, b: unknown): number {
+  if (typeof a === 'number' && typeof b === 'number') return a - b;
+  if (typeof a === 'string' && typeof b === 'string') {
+    return compareStrings(a, b);
+  }
+  throw new Error('incompatible types for comparison');
+}
+
+function sprint(args: readonly unknown[]): string {
+  return args
+    .map((arg, i) => {
+      const spaced =
+        i > 0 && typeof arg !== 'string' && typeof args[i - 1] !== 'string';
+      return `${spaced ? ' ' : ''}${formatValue(arg)}`;
+    })
+    .join('');
+}
+
+function sprintf(format: string, args: readonly unknown[]): string {
+  let next = 0;
+  return format.replace(
+    /%([-+ 0#]*)(\d+)?(?:\.(\d+))?([vsdqfgtx%])/g,
+    (_match, flags: string, width?: string, precision?: string, verb = '') => {
+      if (verb === '%') return '%';
+      if (next >= args.length) return `%!${verb}(MISSING)`;
+      const arg = args[next];
+      next += 1;
+      const number = Number(arg);
+      let text: string;
+      switch (verb) {
+        case 'd':
+          text = String(Math.trunc(number));
+          break;
+        case 'f':
+          text = number.toFixed(
+            precision === undefined ? 6 : Number(precision),
+          );
+          break;
+        case 'q':
+          text = JSON.stringify(
+            typeof arg === 'string' ? arg : formatValue(arg),
+          );
+          break;
+        case 'x':
+          text =
+            typeof arg === 'number'
+              ? Math.trunc(arg).toString(16)
+              : Buffer.from(formatValue(arg)).toString('hex');
+          break;
+        default:
+          text = formatValue(arg);
+          if (verb === 's' && precision !== undefined) {
+            text = text.slice(0, Number(precision));
+          }
+      }
+      const size = width === undefined ? 0 : Number(width);
+      if (flags.includes('-')) return text.padEnd(size);
+      const numeric = 'dfx'.includes(verb) && flags.includes('0');
+      return text.padStart(size, numeric ? '0' : ' ');
+    },
+  );
+}
+
+function escapeHtml(text: string): string {
+  return text
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&#34;')
+    .replace(/'/g, '&#39;');
+}
+
+/** The functions every template has, as in Go's text/template. */
+export const BUILTIN_FUNCTIONS: TemplateFunctions = {
+  and: (...args) => {
+    expectArgs(args, 1, Infinity);
+    return args.find((arg) => !isTrue(arg)) ?? args.at(-1);
+  },
+  or: (...args) => {
+    expectArgs(args, 1, Infinity);
+    return args.find((arg) => isTrue(arg)) ?? args.at(-1);
+  },
+  not: (...args) => {
+    expectArgs(args, 1);
+    return !isTrue(args[0]);
+  },
+  len: (...args) => {
+    expectArgs(args, 1);
+    const [value] = args;
+    if (typeof value === 'string' || Array.isArray(value)) return value.length;
+    if (value instanceof Map) return value.size;
+    if (value !== null && typeof value === 'object') {
+      return Object.keys(value).length;
+    }
+    throw new Error(`len of ${formatValue(value)}`);
+  },
+  index: (...args) => {
+    expectArgs(args, 1, Infinity);
+    let [value] = args;
+    for (const key of args.slice(1)) {
+      if (Array.isArray(value) || typeof value === 'string') {
+        const position = Number(key);
+        if (!Number.isInteger(position) || position < 0) {
+          throw new Error(`cannot index slice/array with ${formatValue(key)}`);
+        }
+        if (position >= value.length) {
+          throw new Error(`index out of range: ${position}`);
+        }
+        value = value[position];
+      } else if (value instanceof Map) {
+        value = value.get(key);
+      } else if (value !== null && typeof value === 'object') {
+        value = (value as Record<string, unknown>)[String(key)];
+      } else {
+        throw new Error(`can't index item of type ${typeof value}`);
+      }
+    }
+    return value;
+  },
+  eq: (...args) => {
+    expectArgs(args, 2, Infinity);
+    return args.slice(1).some((arg) => arg === args[0]);
+  },
+  ne: (...args) => {
+    expectArgs(args, 2);
+    return args[0] !== args[1];
+  },
+  lt: (...args) => {
+    expectArgs(args, 2);
+    return compareValues(args[0], args[1]) < 0;
+  },
+  le: (...args) => {
+    expectArgs(args, 2);
+    return compareValues(args[0], args[1]) <= 0;
+  },
+  gt: (...args) => {
+    expectArgs(args, 2);
+    return compareValues(args[0], args[1]) > 0;
+  },
+  ge: (...args) => {
+    expectArgs(args, 2);
+    return compareValues(args[0], args[1]) >= 0;
+  },
+  print: (...args) => sprint(args),
+  println: (...args) => `${args.map((arg) => formatValue(arg)).join(' ')}\n`,
+  printf: (...args) => {
+    expectArgs(args, 1, Infinity);
+    return sprintf(String(args[0]), args.slice(1));
+  },
+  html: (...args) => escapeHtml(sprint(args)),
+  urlquery: (...args) => encodeURIComponent(sprint(args)),
+};
+
+const BREAK = Symbol('break');
+const CONTINUE = Symbol('continue');
+
+interface Variable {
+  readonly name: string;
+  value: unknown;
+}
+
+/** Render the parsed `template` against `data`. */
+function execute(
+  template: ParsedTemplate,
+  data: unknown,
+  name: string,
+  functions: TemplateFunctions,
+): string {
+  const out: string[] = [];
+  let vars: Variable[] = [{ name: '$', value: data }];
+  let line = 1;
+
+  const fail = (message: string): never => {
+    throw templateError(name, line, message);
+  };
+
+  function lookup(variable: string): Variable | undefined {
+    for (let i = vars.length - 1; i >= 0; i -= 1) {
+      if (vars[i].name === variable) return vars[i];
+    }
+    return undefined;
+  }
+
+  function setVariable(variable: string, value: unknown, assign: boolean) {
+    const existing = assign ? lookup(variable) : undefined;
+    if (existing) {
+      existing.value = value;
+    } else {
+      vars.push({ name: variable, value });
+    }
+  }
+
+  function walkList(nodes: readonly Node[], dot: unknown): void {
+    for (const node of nodes) walk(node, dot);
+  }
+
+  function walk(node: Node, dot: unknown): void {
+    if (node.type === 'text') {
+      out.push(node.text);
+      return;
+    }
+    line = node.line;
+    const scope = vars.length;
+    switch (node.type) {
+      case 'action': {
+        const value = evalPipeline(node.pipe, dot);
+        if (node.pipe.decl.length === 0) out.push(formatValue(value));
+        return;
+      }
+      case 'if':
+      case 'with': {
+        const value = evalPipeline(node.pipe, dot);
+        if (isTrue(value)) {
+          walkList(node.list, node.type === 'with' ? value : dot);
+        } else if (node.elseList) {
+          walkList(node.elseList, dot);
+        }
+        break;
+      }
+      case 'range':
+        walkRange(node, dot);
+        break;
+      case 'template': {
+        const body = template.templates.get(node.name);
+        if (!body) return fail(`no such template "${node.name}"`);
+        const value = node.pipe ? evalPipeline(node.pipe, dot) : undefined;
+        const outer = vars;
+        vars = [{ name: '$', value }];
+        walkList(body, value);
+        vars = outer;
+        break;
+      }
+      case 'break':
+        throw BREAK;
+      case 'continue':
+        throw CONTINUE;
+    }
+    vars.length = scope;
+  }
+
+  function rangeEntries(
+    value: unknown,
+  ): readonly (readonly [unknown, unknown])[] {
+    if (value === undefined || value === null) return [];
+    if (Array.isArray(value)) {
+      return value.map((entry, index) => [index, entry] as const);
+    }
+    if (typeof value === 'number' && Number.isInteger(value)) {
+      return Array.from(
+        { length: Math.max(value, 0) },
+        (_, i) => [i, i] as const,
+      );
+    }
+    if (typeof value === 'object') return entriesOf(value);
+    return fail(`range can't iterate over ${formatValue(value)}`);
+  }
+
+  function walkRange(node: Extract<Node, { type: 'range' }>, dot: unknown) {
+    const entries = rangeEntries(evalPipeline({ ...node.pipe, decl: [] }, dot));
+    if (entries.length === 0) {
+      if (node.elseList) walkList(node.elseList, dot);
+      return;
+    }
+    const { decl, assign } = node.pipe;
+    const scope = vars.length;
+    for (const [key, entry] of entries) {
+      const values = decl.length === 2 ? [key, entry] : [entry];
+      decl.forEach((variable, i) => setVariable(variable, values[i], assign));
+      try {
+        walkList(node.list, entry);
+      } catch (signal) {
+        if (signal === BREAK) break;
+        if (signal !== CONTINUE) throw signal;
+      } finally {
+        vars.length = scope;
+      }
+    }
+  }
+
+  function evalPipeline(pipe: Pipeline, dot: unknown): unknown {
+    let value: unknown;
+    pipe.cmds.forEach((cmd, i) => {
+      value = evalCommand(cmd, dot, i > 0 ? [value] : []);
+    });
+    for (const variable of pipe.decl) setVariable(variable, value, pipe.assign);
+    return value;
+  }
+
+  function evalCommand(
+    args: readonly Arg[],
+    dot: unknown,
+    piped: readonly unknown[],
+  ): unknown {
+    const [first, ...rest] = args;
+    if (first.type !== 'function') {
+      if (rest.length > 0 || piped.length > 0) {
+        fail("can't give argument to non-function");
+      }
+      return evalArg(first, dot);
+    }
+    if (first.name === 'and' || first.name === 'or') {
+      // Stop at the first argument that decides the result, as Go does
+      const values: unknown[] = [];
+      for (const arg of rest) {
+        const value = evalArg(arg, dot);
+        values.push(value);
+        if (isTrue(value) === (first.name === 'or')) break;
+      }
+      if (values.length === rest.length) values.push(...piped);
+      return call(first.name, values);
+    }
+    return call(first.name, [
+      ...rest.map((arg) => evalArg(arg, dot)),
+      ...piped,
+    ]);
+  }
+
+  function call(fn: string, args: readonly unknown[]): unknown {
+    try {
+      return functions[fn](...args);
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      return fail(`error calling ${fn}: ${message}`);
+    }
+  }
+
+  function evalArg(arg: Arg, dot: unknown): unknown {
+    switch (arg.type) {
+      case 'dot':
+        return dot;
+      case 'field':
+        return evalChain(dot, arg.chain);
+      case 'variable':
+        return evalChain(lookup(arg.name)?.value, arg.chain);
+      case 'literal':
+        return arg.value;
+      case 'function':
+        return call(arg.name, []);
+      case 'pipe':
+        return evalChain(evalPipeline(arg.pipe, dot), arg.chain);
+    }
+  }
+
+  /** Missing and nil values stay missing down a chain, like absent keys. */
+  function evalChain(value: unknown, chain: readonly string[]): unknown {
+    let current = value;
+    for (const field of chain) {
+      if (current === undefined || current === null) return undefined;
+      if (current instanceof Map) {
+        current = current.get(field);
+      } else if (typeof current === 'object' && !Array.isArray(current)) {
+        current = (current as Record<string, unknown>)[field];
+      } else {
+        const type = Array.isArray(current) ? 'list' : typeof current;
+        fail(`can't evaluate field ${field} in type ${type}`);
+      }
+    }
+    return current;
+  }
+
+  walkList(template.nodes, data);
+  return out.join('');
+}
+
+/**
+ * Parse a template in Go text/template syntax: `{{ .field }}`, pipelines,
+ * `$variables`, `if`/`else`/`with`/`range` with `break` and `continue`,
+ * `define`/`block`/`template`, comments, and `{{-`/`-}}` trimming. Calls to
+ * undefined functions and syntax errors throw a `parse` error with the line.
+ */
+export function parseTemplate(
+  source: string,
+  options: TemplateOptions = {},
+): CompiledTemplate {
+  const name = options.name ?? 'template';
+  const functions = { ...BUILTIN_FUNCTIONS, ...options.functions };
+  const parsed = parseSource(source, name, functions);
+  return {
+    name,
+    templates: [...parsed.templates.keys()],
+    execute: (data) => execute(parsed, data, name, functions),
+  };
+}
+
+/** Parse `source` and render it against `data`. */
+export function renderTemplate(
+  source: string,
+  data: unknown,
+  options: TemplateOptions = {},
+): string {
+  return parseTemplate(source, options).execute(data);
+}
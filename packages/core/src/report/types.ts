@@ -0,0 +1,50 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Type definitions for report templates, their helper functions, and the graph data they render
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [report, template, types]
+ * context:
+ *   business_goal: Keep the data templates see stable as the graph model grows
+ *   domain: report
+ */
+import type { DependencyGraph, GraphEdge, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+
+/**
+ * A function templates can call. Arguments arrive in call order, with a
+ * piped value last, as in Go's text/template. Throwing fails the render.
+ */
+export type TemplateFunction = (...args: readonly unknown[]) => unknown;
+
+export type TemplateFunctions = Readonly<Record<string, TemplateFunction>>;
+
+export interface TemplateOptions {
+  /** Shown in errors; usually the template's file name. */
+  readonly name?: string;
+  /** Functions added to the built-ins, replacing any of the same name. */
+  readonly functions?: TemplateFunctions;
+}
+
+/** A parsed template, ready to render against any data. */
+export interface CompiledTemplate {
+  readonly name: string;
+  /** The names of the templates it defines with `define` or `block`. */
+  readonly templates: readonly string[];
+  execute(data: unknown): string;
+}
+
+/** What a report template sees as `.`. */
+export interface ReportData {
+  readonly generatedAt: string;
+  readonly entities: readonly StoredEntity[];
+  readonly nodes: readonly GraphNode[];
+  readonly edges: readonly GraphEdge[];
+}
+
+export interface ReportInput {
+  readonly entities: readonly StoredEntity[];
+  readonly graph: DependencyGraph;
+  readonly now?: Date;
+}
@@ -0,0 +1,168 @@
+import { describe, it, expect } from 'vitest';
+import type { ApiSpec } from '../../codegen/types.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { resolveIdentifier } from '../resolve.js';
+import type { IdentifierKind, ResolveContext } from '../types.js';
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  line: number,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType: 'function',
+    description: `${name} function`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line,
+    column: 0,
+    owner: 'payments-team',
+    status: null,
+    metadata: { type: 'function', description: `${name} function` },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+const tokensSpec: ApiSpec = {
+  path: 'services/tokens/api/tokens.yaml',
+  format: 'openapi',
+  operations: [
+    {
+      name: 'issueToken',
+      route: 'POST /tokens',
+      summary: null,
+      request: [],
+      response: [],
+    },
+    {
+      name: 'revokeToken',
+      route: 'DELETE /tokens/{id}',
+      summary: null,
+      request: [],
+      response: [],
+    },
+  ],
+};
+
+const context: ResolveContext = {
+  entities: [
+    makeEntity('tokens', 'services/tokens/doc.go', 1, {
+      entityType: 'module',
+    }),
+    makeEntity('TokenService', 'services/tokens/service.go', 5, {
+      entityType: 'service',
+      metadata: {
+        type: 'service',
+        description: 'Issues tokens',
+        api_specs: ['api/tokens.yaml'],
+      },
+    }),
+    makeEntity('HandleIssueToken', 'services/tokens/http/issue.go', 12),
+    makeEntity('store', 'services/tokens/store/doc.go', 1, {
+      entityType: 'module',
+    }),
+    makeEntity('billing', 'packages/billing/src/index.ts', 1, {
+      entityType: 'module',
+    }),
+    makeEntity('Invoice', 'packages/billing/src/index.ts', 20),
+    makeEntity('Ledger', 'vendor/ledger/ledger.go', 3, {
+      entityType: 'service',
+    }),
+  ],
+  goPackages: [
+    {
+      importPath: 'example.com/app/services/tokens',
+      name: 'tokens',
+      dir: 'services/tokens',
+      module: 'example.com/app',
+      imports: [],
+    },
+  ],
+  workspaces: [
+    { name: '@acme/billing', kind: 'pnpm', path: 'packages/billing' },
+  ],
+  specs: new Map([[tokensSpec.path, tokensSpec]]),
+  submodules: [
+    {
+      name: 'ledger',
+      path: 'vendor/ledger',
+      url: 'https://github.com/acme/ledger.git',
+      namespace: 'acme/ledger',
+    },
+  ],
+  repository: 'acme/app',
+  names: { aliases: { TokenService: ['auth-tokens'] } },
+};
+
+function names(query: string, kind?: IdentifierKind) {
+  const resolution = resolveIdentifier(query, context, kind);
+  return [resolution.kind, resolution.matches.map((match) => match.name)];
+}
+
+describe('resolveIdentifier', () => {
+  it('resolves a file location to the nearest entity above it', () => {
+    expect(names('./packages/billing/src/index.ts:42')).toEqual([
+      'location',
+      ['Invoice'],
+    ]);
+    expect(names('packages/billing/src/index.ts:19')).toEqual([
+      'location',
+      ['billing'],
+    ]);
+  });
+
+  it('resolves import paths to the top module of their directory', () => {
+    const resolution = resolveIdentifier(
+      'example.com/app/services/tokens',
+      context,
+    );
+    expect(resolution.kind).toBe('import');
+    expect(resolution.matches.map((match) => match.name)).toEqual(['tokens']);
+    expect(resolution.matches[0].via).toBe(
+      'go package example.com/app/services/tokens',
+    );
+    expect(names('@acme/billing/src')).toEqual(['import', ['billing']]);
+    expect(names('services/tokens/store')).toEqual(['import', ['store']]);
+  });
+
+  it('resolves operation ids to their handler, or else the service', () => {
+    const issue = resolveIdentifier('issueToken', context);
+    expect(issue.kind).toBe('operation');
+    expect(issue.matches.map((match) => match.name)).toEqual([
+      'HandleIssueToken',
+    ]);
+    expect(issue.matches[0].via).toBe(
+      'POST /tokens in services/tokens/api/tokens.yaml',
+    );
+    expect(names('revokeToken')).toEqual(['operation', ['TokenService']]);
+  });
+
+  it('resolves services to the repository their code lives in', () => {
+    const ledger = resolveIdentifier('ledger', context, 'service');
+    expect(ledger.matches[0].repository).toBe('acme/ledger');
+    const tokens = resolveIdentifier('auth-tokens', context);
+    expect(tokens.kind).toBe('service');
+    expect(tokens.matches[0]).toMatchObject({
+      name: 'TokenService',
+      repository: 'acme/app',
+      via: 'alias auth-tokens',
+    });
+  });
+
+  it('falls back to symbols and reports what did not resolve', () => {
+    expect(names('Invoice')).toEqual(['node', ['Invoice']]);
+    expect(names('Invoice', 'service')).toEqual([null, []]);
+    expect(names('nothing-here')).toEqual([null, []]);
+  });
+});
@@ -0,0 +1,7 @@
+export type {
+  IdentifierKind,
+  ResolveContext,
+  Resolution,
+  ResolvedNode,
+} from './types.js';
+export { IDENTIFIER_KINDS, resolveIdentifier } from './resolve.js';
@@ -0,0 +1,230 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Translates file locations, import paths, service names, and OpenAPI operation ids into the graph nodes they name
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [resolve, identifiers, openapi, go, workspace]
+ * context:
+ *   business_goal: Answer "which node is this" the same way for every tool that asks
+ *   domain: resolve
+ */
+import { posix } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import { entityApiSpecs } from '../codegen/api-spec.js';
+import { findSymbol } from '../explain/explain.js';
+import { entityAliases } from '../graph/graph-builder.js';
+import { createNameTable } from '../graph/graph-names.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { findSubmodule } from '../indexer/walker.js';
+import { toPosixPath } from '../paths/paths.js';
+import type {
+  IdentifierKind,
+  ResolveContext,
+  Resolution,
+  ResolvedNode,
+} from './types.js';
+
+/** The kinds tried, in order, when the caller does not say which. */
+export const IDENTIFIER_KINDS: readonly IdentifierKind[] = [
+  'location',
+  'import',
+  'operation',
+  'service',
+  'node',
+];
+
+interface Match {
+  readonly entity: StoredEntity;
+  readonly via: string | null;
+}
+
+function normalizePath(path: string): string {
+  return toPosixPath(path).replace(/^\.\//, '').replace(/\/$/, '') || '.';
+}
+
+function byLocation(a: StoredEntity, b: StoredEntity): number {
+  return compareStrings(a.filePath, b.filePath) || a.line - b.line;
+}
+
+/**
+ * `path:line` as the entity whose annotation comes last at or above the
+ * line in that file, the nearest one enclosing it.
+ */
+function resolveLocation(query: string, context: ResolveContext): Match[] {
+  const found = /^(.+):(\d+)$/.exec(query);
+  if (!found) return [];
+  const path = normalizePath(found[1]);
+  const line = Number(found[2]);
+  const above = context.entities.filter(
+    (entity) => toPosixPath(entity.filePath) === path && entity.line <= line,
+  );
+  const nearest = Math.max(...above.map((entity) => entity.line));
+  return above
+    .filter((entity) => entity.line === nearest)
+    .map((entity) => ({ entity, via: null }));
+}
+
+/** The directory an import path names, and what named it. */
+function importDirectory(
+  query: string,
+  context: ResolveContext,
+): { readonly dir: string; readonly via: string } | undefined {
+  const pkg = context.goPackages?.find((found) => found.importPath === query);
+  if (pkg) return { dir: pkg.dir, via: `go package ${pkg.importPath}` };
+  const member = [...(context.workspaces ?? [])]
+    .filter(
+      (found) => query === found.name || query.startsWith(`${found.name}/`),
+    )
+    .sort((a, b) => b.name.length - a.name.length)[0];
+  if (member) {
+    const subpath = query.slice(member.name.length + 1);
+    return {
+      dir: normalizePath(posix.join(member.path, subpath)),
+      via: `${member.kind} package ${member.name}`,
+    };
+  }
+  return undefined;
+}
+
+function depth(dir: string): number {
+  return dir === '.' ? 0 : dir.split('/').length;
+}
+
+/**
+ * An import path (a Go package, a workspace package or a path within it,
+ * or a directory) as the `module` entities closest to the top of its
+ * directory.
+ */
+function resolveImport(query: string, context: ResolveContext): Match[] {
+  const named = importDirectory(query, context);
+  const dir = named?.dir ?? normalizePath(query);
+  const modules = context.entities.filter((entity) => {
+    const file = toPosixPath(entity.filePath);
+    return (
+      entity.entityType === 'module' &&
+      (dir === '.' || file.startsWith(`${dir}/`))
+    );
+  });
+  const dirOf = (entity: StoredEntity) =>
+    posix.dirname(toPosixPath(entity.filePath));
+  const top = Math.min(...modules.map((entity) => depth(dirOf(entity))));
+  return modules
+    .filter((entity) => depth(dirOf(entity)) === top)
+    .sort(byLocation)
+    .map((entity) => ({ entity, via: named?.via ?? null }));
+}
+
+/**
+ * A service by name, ignoring case, or by one of its aliases or old names
+ * (see `entityAliases`).
+ */
+function resolveService(query: string, context: ResolveContext): Match[] {
+  const lower = query.toLowerCase();
+  const names = createNameTable(context.names);
+  const matches: Match[] = [];
+  for (const entity of context.entities) {
+    if (entity.entityType !== 'service') continue;
+    const alias = entityAliases(entity, names).find(
+      (name) => name.toLowerCase() === lower,
+    );
+    if (entity.name.toLowerCase() === lower) {
+      matches.push({ entity, via: null });
+    } else if (alias) {
+      matches.push({ entity, via: `alias ${alias}` });
+    }
+  }
+  return matches.sort((a, b) => byLocation(a.entity, b.entity));
+}
+
+function handlerKey(name: string): string {
+  return name.toLowerCase().replace(/[^a-z0-9]/g, '');
+}
+
+/**
+ * An OpenAPI `operationId` or gRPC method as the entity handling it. The
+ * handler is an entity under the directory of the service whose
+ * `api_specs` declare the operation, named after it, optionally with a
+ * `handle` prefix or `Handler` suffix; without one, the service itself.
+ */
+function resolveOperation(query: string, context: ResolveContext): Match[] {
+  const key = handlerKey(query);
+  const names = new Set([key, `handle${key}`, `${key}handler`]);
+  const matches: Match[] = [];
+  for (const provider of context.entities) {
+    for (const path of entityApiSpecs(provider)) {
+      const operation = context.specs
+        ?.get(path)
+        ?.operations.find((found) => handlerKey(found.name) === key);
+      if (!operation) continue;
+      const via = `${operation.route} in ${path}`;
+      const dir = posix.dirname(toPosixPath(provider.filePath));
+      const handlers = context.entities.filter(
+        (entity) =>
+          entity.id !== provider.id &&
+          names.has(handlerKey(entity.name)) &&
+          (dir === '.' || toPosixPath(entity.filePath).startsWith(`${dir}/`)),
+      );
+      const found = handlers.length > 0 ? handlers : [provider];
+      matches.push(...found.map((entity) => ({ entity, via })));
+    }
+  }
+  return matches;
+}
+
+const RESOLVERS: Readonly<
+  Record<IdentifierKind, (query: string, context: ResolveContext) => Match[]>
+> = {
+  location: resolveLocation,
+  import: resolveImport,
+  operation: resolveOperation,
+  service: resolveService,
+  node: (query, context) =>
+    findSymbol(context.entities, query).map((entity) => ({
+      entity,
+      via: null,
+    })),
+};
+
+function toResolvedNode(match: Match, context: ResolveContext): ResolvedNode {
+  const { entity } = match;
+  const submodule = findSubmodule(entity.filePath, context.submodules ?? []);
+  return {
+    id: entity.id,
+    name: entity.name,
+    entityType: entity.entityType,
+    filePath: entity.filePath,
+    line: entity.line,
+    owner: entity.owner,
+    repository: submodule?.namespace ?? context.repository ?? null,
+    via: match.via,
+  };
+}
+
+/**
+ * Resolve `query` as `kind`, or as each kind of `IDENTIFIER_KINDS` in
+ * turn until one matches. A service name resolves to the repository its
+ * code lives in through each match's `repository`.
+ */
+export function resolveIdentifier(
+  query: string,
+  context: ResolveContext,
+  kind?: IdentifierKind,
+): Resolution {
+  for (const tried of kind ? [kind] : IDENTIFIER_KINDS) {
+    const matches = new Map<string, Match>();
+    for (const match of RESOLVERS[tried](query.trim(), context)) {
+      if (!matches.has(match.entity.id)) matches.set(match.entity.id, match);
+    }
+    if (matches.size > 0) {
+      return {
+        query,
+        kind: tried,
+        matches: [...matches.values()].map((match) =>
+          toResolvedNode(match, context),
+        ),
+      };
+    }
+  }
+  return { query, kind: null, matches: [] };
+}
@@ -0,0 +1,65 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for translating file locations, import paths, service names, and API operation ids into graph nodes
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [resolve, identifiers, types, interface]
+ * context:
+ *   business_goal: Let callers tell an exact match from a best guess when resolving names
+ *   domain: resolve
+ */
+import type { ApiSpec } from '../codegen/types.js';
+import type { GoPackage } from '../golang/types.js';
+import type { GraphNameOptions } from '../graph/types.js';
+import type { GitSubmodule, StoredEntity } from '../indexer/types.js';
+import type { WorkspaceMember } from '../workspace/types.js';
+
+/**
+ * What an identifier names: a `file:line` location, an import path, a
+ * service name, an API operation id, or any node as `knowgraph explain`
+ * finds it.
+ */
+export type IdentifierKind =
+  | 'location'
+  | 'import'
+  | 'service'
+  | 'operation'
+  | 'node';
+
+export interface ResolvedNode {
+  readonly id: string;
+  readonly name: string;
+  readonly entityType: string;
+  readonly filePath: string;
+  readonly line: number;
+  readonly owner: string | null;
+  /** The `org/repo` the node's code lives in, when known. */
+  readonly repository: string | null;
+  /** How the identifier led to the node, such as `go package …`. */
+  readonly via: string | null;
+}
+
+export interface Resolution {
+  readonly query: string;
+  /** The kind that matched, or null when nothing did. */
+  readonly kind: IdentifierKind | null;
+  readonly matches: readonly ResolvedNode[];
+}
+
+/** What resolution looks identifiers up in, besides the entities. */
+export interface ResolveContext {
+  readonly entities: readonly StoredEntity[];
+  /** Go packages of the repository, for Go import paths. */
+  readonly goPackages?: readonly GoPackage[];
+  /** Workspace members, for package names such as `@acme/billing`. */
+  readonly workspaces?: readonly WorkspaceMember[];
+  /** The `api_specs` entities link, by path relative to the root. */
+  readonly specs?: ReadonlyMap<string, ApiSpec>;
+  /** Submodules, whose files belong to their own repository. */
+  readonly submodules?: readonly GitSubmodule[];
+  /** The repository of everything outside submodules. */
+  readonly repository?: string;
+  /** The manifest's `aliases` and `renames`, for older service names. */
+  readonly names?: GraphNameOptions;
+}
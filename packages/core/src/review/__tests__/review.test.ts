@@ -0,0 +1,216 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createDefaultRegistry } from '../../parsers/registry.js';
+import {
+  approveAnnotation,
+  findReviewItems,
+  isPendingReview,
+  listReviewQueue,
+  matchReviewItems,
+  rejectAnnotation,
+  reviewAnnotations,
+} from '../review.js';
+
+const TS_SOURCE = [
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Reviewed by hand already',
+  ' */',
+  'export function settled(): void {}',
+  '',
+  '/**',
+  ' * @knowgraph',
+  ' * type: function',
+  ' * description: Reverses the ledger entries of an order',
+  ' * tags: [billing]',
+  ' * generated: true',
+  ' */',
+  'export function refund(orderId: string): void {}',
+  '',
+  '/** Keeps running totals. */',
+  '/**',
+  ' * @knowgraph',
+  ' * type: class',
+  ' * description: Running totals per account',
+  ' * generated: true',
+  ' */',
+  'export class Ledger {}',
+  '',
+].join('\n');
+
+const PY_SOURCE = [
+  'def settle(batch):',
+  '    """',
+  '    @knowgraph',
+  '    type: function',
+  '    description: Settles a batch of payouts',
+  '    generated: true',
+  '    """',
+  '    return batch',
+  '',
+  '',
+  'def pay(batch):',
+  '    """Pay a batch.',
+  '',
+  '    @knowgraph',
+  '    type: function',
+  '    description: Pays a batch of payouts',
+  '    generated: true',
+  '    """',
+  '    return batch',
+  '',
+].join('\n');
+
+describe('isPendingReview', () => {
+  it('holds for generated annotations only', () => {
+    expect(
+      isPendingReview({ type: 'function', description: 'x', generated: true }),
+    ).toBe(true);
+    expect(
+      isPendingReview({ type: 'function', description: 'x', reviewed_by: 'a' }),
+    ).toBe(false);
+  });
+});
+
+describe('findReviewItems', () => {
+  it('lists generated annotations at their marker lines', () => {
+    const items = findReviewItems(TS_SOURCE, 'billing.ts');
+    expect(items.map((item) => [item.name, item.line])).toEqual([
+      ['refund', 9],
+      ['Ledger', 19],
+    ]);
+    expect(items[0].metadata.tags).toEqual(['billing']);
+  });
+
+  it('pairs Python docstrings with their functions', () => {
+    const items = findReviewItems(PY_SOURCE, 'payouts.py');
+    expect(items.map((item) => [item.name, item.line])).toEqual([
+      ['settle', 3],
+      ['pay', 14],
+    ]);
+  });
+});
+
+describe('matchReviewItems', () => {
+  const items = [
+    ...findReviewItems(TS_SOURCE, 'billing.ts'),
+    ...findReviewItems(PY_SOURCE, 'payouts.py'),
+  ];
+
+  it('matches path:line, path:name, and names', () => {
+    expect(matchReviewItems(items, 'payouts.py:14')[0]?.name).toBe('pay');
+    expect(matchReviewItems(items, 'billing.ts:Ledger')[0]?.line).toBe(19);
+    expect(matchReviewItems(items, 'ledger')[0]?.name).toBe('Ledger');
+    expect(matchReviewItems(items, 'settled')).toEqual([]);
+  });
+});
+
+describe('approveAnnotation', () => {
+  it('records the reviewer in place of the generated flag', () => {
+    const [item] = findReviewItems(TS_SOURCE, 'billing.ts');
+    const approved = approveAnnotation(TS_SOURCE, item, 'ana@example.com');
+    expect(approved).toContain('reviewed_by: ana@example.com');
+    const results = createDefaultRegistry().parseFile(
+      approved,
+      'billing.ts',
+    ).results;
+    const refund = results.find((result) => result.name === 'refund');
+    expect(refund && isPendingReview(refund.metadata)).toBe(false);
+    const ledger = results.find((result) => result.name === 'Ledger');
+    expect(ledger && isPendingReview(ledger.metadata)).toBe(true);
+  });
+});
+
+describe('rejectAnnotation', () => {
+  it('removes the comment a TypeScript draft was written in', () => {
+    const items = findReviewItems(TS_SOURCE, 'billing.ts');
+    const rejected = rejectAnnotation(TS_SOURCE, items[1]);
+    expect(rejected).toContain(
+      '/** Keeps running totals. */\nexport class Ledger {}',
+    );
+    expect(rejected).toContain('generated: true\n */\nexport function refund');
+  });
+
+  it('removes a Python draft and the docstring it leaves empty', () => {
+    const items = findReviewItems(PY_SOURCE, 'payouts.py');
+    const rejected = rejectAnnotation(
+      rejectAnnotation(PY_SOURCE, items[1]),
+      items[0],
+    );
+    expect(rejected).toBe(
+      [
+        'def settle(batch):',
+        '    return batch',
+        '',
+        '',
+        'def pay(batch):',
+        '    """Pay a batch.',
+        '    """',
+        '    return batch',
+        '',
+      ].join('\n'),
+    );
+  });
+});
+
+describe('reviewAnnotations', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-review-'));
+    writeFileSync(join(dir, 'billing.ts'), TS_SOURCE);
+    writeFileSync(join(dir, 'payouts.py'), PY_SOURCE);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('lists the queue across files', () => {
+    expect(listReviewQueue(dir).map((item) => item.name)).toEqual([
+      'refund',
+      'Ledger',
+      'settle',
+      'pay',
+    ]);
+  });
+
+  it('applies each decision and writes the files', () => {
+    const result = reviewAnnotations(dir, ['Ledger', 'refund'], 'reject', {
+      reviewer: 'ana@example.com',
+    });
+    expect(result.outcomes.map((outcome) => outcome.item.name)).toEqual([
+      'refund',
+      'Ledger',
+    ]);
+    expect(result.files).toHaveLength(1);
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).not.toContain(
+      'generated',
+    );
+    expect(listReviewQueue(dir).map((item) => item.name)).toEqual([
+      'settle',
+      'pay',
+    ]);
+  });
+
+  it('writes nothing when a target does not match exactly one annotation', () => {
+    expect(() =>
+      reviewAnnotations(dir, ['refund', 'settled'], 'approve', {
+        reviewer: 'ana@example.com',
+      }),
+    ).toThrow(/No annotation awaiting review matches 'settled'/);
+    expect(readFileSync(join(dir, 'billing.ts'), 'utf-8')).toBe(TS_SOURCE);
+  });
+
+  it('leaves the files alone without write', () => {
+    const result = reviewAnnotations(dir, ['payouts.py:3'], 'approve', {
+      reviewer: 'ana@example.com',
+      write: false,
+    });
+    expect(result.files[0]?.after).toContain('reviewed_by: ana@example.com');
+    expect(readFileSync(join(dir, 'payouts.py'), 'utf-8')).toBe(PY_SOURCE);
+  });
+});
@@ -0,0 +1,17 @@
+export type {
+  ReviewDecision,
+  ReviewItem,
+  ReviewOptions,
+  ReviewOutcome,
+  ReviewResult,
+  ReviewedFile,
+} from './types.js';
+export {
+  approveAnnotation,
+  findReviewItems,
+  isPendingReview,
+  listReviewQueue,
+  matchReviewItems,
+  rejectAnnotation,
+  reviewAnnotations,
+} from './review.js';
@@ -0,0 +1,260 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Lists generated annotations awaiting review and approves or rejects them in the source, recording the reviewer
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [review, annotations, draft, rewriter]
+ * context:
+ *   business_goal: Record who approved each generated annotation so trust in it can be traced
+ *   domain: review
+ */
+import { existsSync, readFileSync, statSync, writeFileSync } from 'node:fs';
+import { basename, dirname, join } from 'node:path';
+import { parse as parseYaml } from 'yaml';
+import { createKnowgraphError } from '../errors/errors.js';
+import { listIndexableFiles } from '../indexer/indexer.js';
+import { createDefaultRegistry } from '../parsers/registry.js';
+import {
+  findAnnotationBlocks,
+  findSymbolBlock,
+  rewriteAnnotation,
+} from '../rewriter/index.js';
+import type { AnnotationBlock } from '../rewriter/index.js';
+import type { CoreMetadata, ExtendedMetadata } from '../types/entity.js';
+import type {
+  ReviewDecision,
+  ReviewItem,
+  ReviewOptions,
+  ReviewOutcome,
+  ReviewResult,
+  ReviewedFile,
+} from './types.js';
+
+const DOCSTRING_QUOTES = ['"""', "'''"];
+
+/**
+ * Whether an annotation is still a draft nobody has reviewed. Exports
+ * leave such annotations out, as they are not yet authoritative.
+ */
+export function isPendingReview(
+  metadata: CoreMetadata | ExtendedMetadata,
+): boolean {
+  return 'generated' in metadata && metadata.generated === true;
+}
+
+function isGeneratedBlock(block: AnnotationBlock): boolean {
+  try {
+    const fields: unknown = parseYaml(block.yaml);
+    return (
+      typeof fields === 'object' &&
+      fields !== null &&
+      (fields as Record<string, unknown>).generated === true
+    );
+  } catch {
+    return false;
+  }
+}
+
+/** The annotations in `content` marked `generated: true`, in line order. */
+export function findReviewItems(
+  content: string,
+  filePath: string,
+): readonly ReviewItem[] {
+  const blocks = findAnnotationBlocks(content).filter(isGeneratedBlock);
+  const results = createDefaultRegistry()
+    .parseFile(content, filePath)
+    .results.filter((result) => isPendingReview(result.metadata));
+  const items: ReviewItem[] = [];
+  for (const result of results) {
+    const block = findSymbolBlock(blocks, result);
+    if (!block || items.some((item) => item.line === block.markerLine)) {
+      continue;
+    }
+    items.push({
+      name: result.name,
+      filePath,
+      line: block.markerLine,
+      entityType: result.entityType,
+      metadata: result.metadata as ExtendedMetadata,
+    });
+  }
+  return items.sort((a, b) => a.line - b.line);
+}
+
+function listReviewFiles(path: string): {
+  readonly rootDir: string;
+  readonly files: readonly string[];
+} {
+  if (!existsSync(path)) {
+    throw createKnowgraphError('io', `Path not found: ${path}`);
+  }
+  if (statSync(path).isFile()) {
+    return { rootDir: dirname(path), files: [basename(path)] };
+  }
+  const registry = createDefaultRegistry();
+  const adapter = {
+    parse: () => [],
+    canParse: (filePath: string) => registry.getParser(filePath) !== undefined,
+  };
+  return { rootDir: path, files: listIndexableFiles(path, adapter) };
+}
+
+function readQueue(path: string): {
+  readonly rootDir: string;
+  readonly contents: ReadonlyMap<string, string>;
+  readonly items: readonly ReviewItem[];
+} {
+  const { rootDir, files } = listReviewFiles(path);
+  const contents = new Map<string, string>();
+  const items: ReviewItem[] = [];
+  for (const filePath of files) {
+    const content = readFileSync(join(rootDir, filePath), 'utf-8');
+    if (!content.includes('generated')) continue;
+    const found = findReviewItems(content, filePath);
+    if (found.length === 0) continue;
+    contents.set(filePath, content);
+    items.push(...found);
+  }
+  return { rootDir, contents, items };
+}
+
+/** The annotations awaiting review under `path`, a directory or one file. */
+export function listReviewQueue(path: string): readonly ReviewItem[] {
+  return readQueue(path).items;
+}
+
+/**
+ * The queued annotations `target` names: `path:line` of the marker,
+ * `path:name`, or a name, trying each form in turn.
+ */
+export function matchReviewItems(
+  items: readonly ReviewItem[],
+  target: string,
+): readonly ReviewItem[] {
+  const separator = target.lastIndexOf(':');
+  const path = separator > 0 ? target.slice(0, separator) : undefined;
+  const rest = target.slice(separator + 1);
+  const lower = target.toLowerCase();
+  const tiers: ((item: ReviewItem) => boolean)[] = [
+    (item) => item.filePath === path && String(item.line) === rest,
+    (item) => item.filePath === path && item.name === rest,
+    (item) => item.name === target,
+    (item) => item.name.toLowerCase() === lower,
+  ];
+  for (const matches of tiers) {
+    const found = items.filter(matches);
+    if (found.length > 0) return found;
+  }
+  return [];
+}
+
+/**
+ * Approve the annotation at `item`: drop `generated` and record who
+ * reviewed it as `reviewed_by`.
+ */
+export function approveAnnotation(
+  content: string,
+  item: ReviewItem,
+  reviewer: string,
+): string {
+  return rewriteAnnotation(content, item.line, (doc) => {
+    doc.delete('generated');
+    doc.set('reviewed_by', reviewer);
+  });
+}
+
+/**
+ * Reject the annotation at `item`, removing its block and the comment or
+ * docstring it leaves empty, so the symbol is unannotated again.
+ */
+export function rejectAnnotation(content: string, item: ReviewItem): string {
+  const block = findAnnotationBlocks(content).find(
+    (candidate) => candidate.markerLine === item.line,
+  );
+  if (!block) return content;
+  const eol = content.includes('\r\n') ? '\r\n' : '\n';
+  const lines = content.split(eol);
+  let start = block.markerLine - 1;
+  let end = block.endLine;
+  const before = lines[start - 1]?.trim();
+  const after = lines[end]?.trim();
+  if (
+    (before === '/**' && after === '*/') ||
+    (before === after && DOCSTRING_QUOTES.includes(before ?? ''))
+  ) {
+    start--;
+    end++;
+  } else if (before === '' && DOCSTRING_QUOTES.includes(after ?? '')) {
+    // The block was appended to a docstring after a blank line
+    start--;
+  }
+  lines.splice(start, end - start);
+  return lines.join(eol);
+}
+
+function decide(
+  content: string,
+  item: ReviewItem,
+  decision: ReviewDecision,
+  reviewer: string,
+): string {
+  return decision === 'approve'
+    ? approveAnnotation(content, item, reviewer)
+    : rejectAnnotation(content, item);
+}
+
+/**
+ * Approve or reject the queued annotations `targets` name under `path`,
+ * and write the files unless `write` is false. Every target must name
+ * exactly one annotation; otherwise nothing is written.
+ */
+export function reviewAnnotations(
+  path: string,
+  targets: readonly string[],
+  decision: ReviewDecision,
+  options: ReviewOptions,
+): ReviewResult {
+  const { reviewer, write = true } = options;
+  const { rootDir, contents, items } = readQueue(path);
+  const chosen = new Set<ReviewItem>();
+  for (const target of targets) {
+    const matches = matchReviewItems(items, target);
+    if (matches.length === 0) {
+      throw createKnowgraphError(
+        'usage',
+        `No annotation awaiting review matches '${target}'`,
+      );
+    }
+    if (matches.length > 1) {
+      const names = matches.map((item) => `${item.filePath}:${item.line}`);
+      throw createKnowgraphError(
+        'usage',
+        `'${target}' matches ${matches.length} annotations (${names.join(', ')}); name one by path:line`,
+      );
+    }
+    chosen.add(matches[0]);
+  }
+
+  const outcomes: ReviewOutcome[] = [];
+  const files: ReviewedFile[] = [];
+  for (const [filePath, before] of contents) {
+    // Bottom-up, so a rejection does not move the blocks above it
+    const fileItems = [...chosen]
+      .filter((item) => item.filePath === filePath)
+      .sort((a, b) => b.line - a.line);
+    if (fileItems.length === 0) continue;
+    let after = before;
+    for (const item of fileItems) {
+      after = decide(after, item, decision, reviewer);
+    }
+    if (write) writeFileSync(join(rootDir, filePath), after, 'utf-8');
+    files.push({ filePath, before, after });
+    outcomes.push(
+      ...fileItems
+        .reverse()
+        .map((item) => ({ item, decision, reviewer })),
+    );
+  }
+  return { outcomes, files };
+}
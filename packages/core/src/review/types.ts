@@ -0,0 +1,49 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the review queue of generated annotations and the decisions taken on them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [review, annotations, draft, types, interface]
+ * context:
+ *   business_goal: Let review queues be worked from the terminal or a bot alike
+ *   domain: review
+ */
+import type { EntityType, ExtendedMetadata } from '../types/entity.js';
+
+/** An annotation marked `generated: true`, waiting for a reviewer. */
+export interface ReviewItem {
+  readonly name: string;
+  /** Relative to the reviewed directory, with `/` separators. */
+  readonly filePath: string;
+  /** 1-based line of the @knowgraph marker. */
+  readonly line: number;
+  readonly entityType: EntityType;
+  readonly metadata: ExtendedMetadata;
+}
+
+export type ReviewDecision = 'approve' | 'reject';
+
+export interface ReviewOptions {
+  /** Recorded as `reviewed_by` on approved annotations. */
+  readonly reviewer: string;
+  /** Write the decisions into the files (default: true). */
+  readonly write?: boolean;
+}
+
+export interface ReviewOutcome {
+  readonly item: ReviewItem;
+  readonly decision: ReviewDecision;
+  readonly reviewer: string;
+}
+
+export interface ReviewedFile {
+  readonly filePath: string;
+  readonly before: string;
+  readonly after: string;
+}
+
+export interface ReviewResult {
+  readonly outcomes: readonly ReviewOutcome[];
+  readonly files: readonly ReviewedFile[];
+}
@@ -0,0 +1,95 @@
+import { describe, it, expect } from 'vitest';
+import {
+  findAnnotationBlocks,
+  parseAnnotationDocument,
+  rewriteAnnotation,
+} from '../comment-rewriter.js';
+
+const TS_SOURCE = `/**
+ * @knowgraph
+ * type: module
+ * description: Payment processing
+ * owner: jdoe
+ * tags: [payments, billing, payments]
+ *
+ */
+import { x } from './x.js';
+`;
+
+const PY_SOURCE = `def charge():
+    """
+    @knowgraph
+    type: function
+    description: Charge a card
+    context:
+      business_goal: Revenue
+    """
+    pass
+`;
+
+const GO_SOURCE = `// @knowgraph
+// type: function
+// description: Refund a payment
+func Refund() {}
+`;
+
+describe('findAnnotationBlocks', () => {
+  it('finds JSDoc blocks and strips the prefix', () => {
+    const [block] = findAnnotationBlocks(TS_SOURCE);
+    expect(block.markerLine).toBe(2);
+    expect(block.startLine).toBe(3);
+    expect(block.endLine).toBe(6);
+    expect(block.prefix).toBe(' * ');
+    expect(block.yaml).toBe(
+      'type: module\ndescription: Payment processing\nowner: jdoe\ntags: [payments, billing, payments]',
+    );
+  });
+
+  it('finds docstring blocks using indentation as the prefix', () => {
+    const [block] = findAnnotationBlocks(PY_SOURCE);
+    expect(block.prefix).toBe('    ');
+    expect(block.endLine).toBe(7);
+    expect(parseAnnotationDocument(block).toJS()).toEqual({
+      type: 'function',
+      description: 'Charge a card',
+      context: { business_goal: 'Revenue' },
+    });
+  });
+
+  it('finds line-comment blocks', () => {
+    const [block] = findAnnotationBlocks(GO_SOURCE);
+    expect(block.prefix).toBe('// ');
+    expect(block.yaml).toBe('type: function\ndescription: Refund a payment');
+  });
+
+  it('ignores inline mentions of the marker', () => {
+    expect(findAnnotationBlocks('// uses @knowgraph blocks\n')).toEqual([]);
+  });
+});
+
+describe('rewriteAnnotation', () => {
+  it('rewrites a field and keeps surrounding code intact', () => {
+    const result = rewriteAnnotation(TS_SOURCE, 2, (doc) => {
+      doc.set('owner', 'payments-team');
+    });
+    expect(result).toContain(' * owner: payments-team\n');
+    expect(result).toContain(' * tags: [payments, billing, payments]\n');
+    expect(result).toContain(" */\nimport { x } from './x.js';");
+    expect(result.split('\n')).toHaveLength(TS_SOURCE.split('\n').length);
+  });
+
+  it('re-indents docstring blocks', () => {
+    const result = rewriteAnnotation(PY_SOURCE, 5, (doc) => {
+      doc.set('owner', 'payments-team');
+    });
+    expect(result).toContain('      business_goal: Revenue\n');
+    expect(result).toContain('    owner: payments-team\n    """');
+  });
+
+  it('returns content unchanged when nothing changes', () => {
+    expect(rewriteAnnotation(GO_SOURCE, 1, () => undefined)).toBe(GO_SOURCE);
+    expect(
+      rewriteAnnotation(GO_SOURCE, 40, (doc) => doc.set('owner', 'x')),
+    ).toBe(GO_SOURCE);
+  });
+});
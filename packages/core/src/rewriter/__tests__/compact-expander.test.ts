@@ -0,0 +1,72 @@
+import { describe, it, expect } from 'vitest';
+import { expandCompactAnnotations } from '../compact-expander.js';
+
+describe('expandCompactAnnotations', () => {
+  it('expands a line comment into a JSDoc block in TypeScript', () => {
+    const content = [
+      'export class Auth {',
+      '  // knowgraph: type=method owner=auth-team tags=auth,http context.domain=identity',
+      '  login(): void {}',
+      '}',
+    ].join('\n');
+    const result = expandCompactAnnotations(content, 'src/auth.ts');
+    expect(result.expanded).toEqual([2]);
+    expect(result.errors).toEqual([]);
+    expect(result.content).toBe(
+      [
+        'export class Auth {',
+        '  /**',
+        '   * @knowgraph',
+        '   * type: method',
+        '   * owner: auth-team',
+        '   * tags: [auth, http]',
+        '   * context:',
+        '   *   domain: identity',
+        '   */',
+        '  login(): void {}',
+        '}',
+      ].join('\n'),
+    );
+  });
+
+  it('keeps the comment style of other languages', () => {
+    const go = expandCompactAnnotations(
+      '// knowgraph: type=function description="Sends mail"\nfunc Send() {}',
+      'mail.go',
+    );
+    expect(go.content).toBe(
+      [
+        '// @knowgraph',
+        '// type: function',
+        '// description: Sends mail',
+        'func Send() {}',
+      ].join('\n'),
+    );
+    const python = expandCompactAnnotations(
+      'def send():\n    """knowgraph: type=function"""\n    # knowgraph: type=x',
+      'mail.py',
+    );
+    expect(python.expanded).toEqual([2]);
+    expect(python.content).toBe(
+      [
+        'def send():',
+        '    """',
+        '    @knowgraph',
+        '    type: function',
+        '    """',
+        '    # knowgraph: type=x',
+      ].join('\n'),
+    );
+  });
+
+  it('leaves annotations that do not parse as they are', () => {
+    const content = '// knowgraph: type=function stray\nlet a;\n// knowgraph:\n';
+    const result = expandCompactAnnotations(content, 'a.ts');
+    expect(result.content).toBe(content);
+    expect(result.expanded).toEqual([]);
+    expect(result.errors).toEqual([
+      { line: 1, message: 'Expected key=value, got "stray"' },
+      { line: 3, message: 'The annotation has no fields' },
+    ]);
+  });
+});
@@ -0,0 +1,83 @@
+import { describe, it, expect } from 'vitest';
+import { parseCompactAnnotation } from '../../parsers/compact.js';
+import { toGoDirectives } from '../go-directives.js';
+
+describe('toGoDirectives', () => {
+  it('rewrites a YAML block as directive lines after the doc prose', () => {
+    const content = [
+      'package auth',
+      '',
+      '// HandleRegister registers a user.',
+      '//',
+      '// @knowgraph',
+      '// type: function',
+      '// description: HTTP handler for user registration with input validation',
+      '// owner: auth-team',
+      '// tags: [auth, registration, http]',
+      '// context:',
+      '//   funnel_stage: acquisition',
+      'func HandleRegister(w http.ResponseWriter, r *http.Request) {}',
+    ].join('\n');
+    const result = toGoDirectives(content);
+    expect(result.converted).toEqual([5]);
+    expect(result.errors).toEqual([]);
+    expect(result.content).toBe(
+      [
+        'package auth',
+        '',
+        '// HandleRegister registers a user.',
+        '//',
+        '//knowgraph:type=function',
+        '//knowgraph:description="HTTP handler for user registration with input validation"',
+        '//knowgraph:owner=auth-team tags=auth,registration,http',
+        '//knowgraph:context.funnel_stage=acquisition',
+        'func HandleRegister(w http.ResponseWriter, r *http.Request) {}',
+      ].join('\n'),
+    );
+  });
+
+  it('writes pairs that read back as the YAML fields', () => {
+    const content = [
+      '\t// @knowgraph',
+      '\t// type: method',
+      '\t// description: Retries "twice"',
+      '\t// tags: [two words]',
+      '\t// slo:',
+      '\t//   availability: 99.9',
+    ].join('\n');
+    const { content: converted } = toGoDirectives(content);
+    expect(converted.startsWith('\t//knowgraph:type=method')).toBe(true);
+    const pairs = converted
+      .split('\n')
+      .map((line) => line.replace('\t//knowgraph:', ''))
+      .join(' ');
+    expect(parseCompactAnnotation(pairs)).toEqual({
+      type: 'method',
+      description: 'Retries "twice"',
+      tags: ['two words'],
+      slo: { availability: 99.9 },
+    });
+  });
+
+  it('leaves blocks with no directive form as they are', () => {
+    const content = [
+      '// @knowgraph',
+      '// type: function',
+      '// description: Charges a card # until the PSP migration',
+      'func Charge() {}',
+      '',
+      '// @knowgraph',
+      '// type: service',
+      '// description: Payments',
+      '// links:',
+      '//   - url: https://example.com',
+      'package payments',
+    ].join('\n');
+    const result = toGoDirectives(content);
+    expect(result.content).toBe(content);
+    expect(result.errors).toEqual([
+      { line: 1, message: 'The block has YAML comments, which would be lost' },
+      { line: 6, message: 'links cannot be written on one line' },
+    ]);
+  });
+});
@@ -0,0 +1,178 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Locates @knowgraph YAML blocks in source comments and rewrites them in place
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [rewriter, yaml, annotations, autofix]
+ * context:
+ *   business_goal: Let tools fix or extend annotations without disturbing surrounding code
+ *   domain: rewriter
+ */
+import { parseDocument } from 'yaml';
+import type { Document } from 'yaml';
+
+const MARKER = '@knowgraph';
+
+// Keep long values on one line and render flow sequences as `[a, b]`,
+// matching how annotations are written by hand.
+const TO_STRING_OPTIONS = { lineWidth: 0, flowCollectionPadding: false };
+
+export interface AnnotationBlock {
+  /** 1-based line of the @knowgraph marker. */
+  readonly markerLine: number;
+  /** 1-based first and last lines holding YAML (inclusive). */
+  readonly startLine: number;
+  readonly endLine: number;
+  /** Comment prefix repeated on every YAML line, e.g. ` * ` or `# `. */
+  readonly prefix: string;
+  readonly yaml: string;
+}
+
+interface PrefixStyle {
+  readonly prefix: string;
+  readonly pattern: RegExp;
+}
+
+function detectPrefix(markerLineText: string): PrefixStyle {
+  const before = markerLineText.slice(0, markerLineText.indexOf(MARKER));
+  const styles: readonly [RegExp, RegExp][] = [
+    [/^(\s*\*\s?)$/, /^\s*\*(?!\/)\s?/],
+    [/^(\s*\/\/\s?)$/, /^\s*\/\/\s?/],
+    [/^(\s*#\s?)$/, /^\s*#\s?/],
+    [/^(\s*--\s?)$/, /^\s*--\s?/],
+  ];
+  for (const [marker, line] of styles) {
+    const match = marker.exec(before);
+    if (match) return { prefix: match[1], pattern: line };
+  }
+  // Docstring-style blocks carry no comment token, only indentation
+  const indent = /^(\s*)/.exec(before)?.[1] ?? '';
+  return { prefix: indent, pattern: new RegExp(`^${indent}`) };
+}
+
+function isTerminator(text: string): boolean {
+  const trimmed = text.trim();
+  return (
+    trimmed.startsWith('*/') ||
+    trimmed.startsWith('"""') ||
+    trimmed.startsWith("'''")
+  );
+}
+
+function belongsToBlock(text: string, style: PrefixStyle): boolean {
+  if (isTerminator(text)) return false;
+  if (style.prefix.trim() === '') {
+    return text.trim() === '' || style.pattern.test(text);
+  }
+  return (
+    style.pattern.test(text) || text.trimEnd() === style.prefix.trimEnd()
+  );
+}
+
+function stripPrefix(text: string, style: PrefixStyle): string {
+  if (text.trimEnd() === style.prefix.trimEnd()) return '';
+  return text.replace(style.pattern, '');
+}
+
+/**
+ * Find every @knowgraph block whose marker sits alone on its comment line.
+ * The YAML body runs from the line after the marker to the last non-blank
+ * line before the comment ends.
+ */
+export function findAnnotationBlocks(
+  content: string,
+): readonly AnnotationBlock[] {
+  const lines = content.split('\n');
+  const blocks: AnnotationBlock[] = [];
+
+  for (let i = 0; i < lines.length; i++) {
+    const text = lines[i];
+    const markerIndex = text.indexOf(MARKER);
+    if (markerIndex === -1) continue;
+    if (text.slice(markerIndex + MARKER.length).trim() !== '') continue;
+
+    const style = detectPrefix(text);
+    let end = i;
+    while (end + 1 < lines.length && belongsToBlock(lines[end + 1], style)) {
+      end++;
+    }
+    while (end > i && stripPrefix(lines[end], style).trim() === '') end--;
+    if (end === i) continue;
+
+    const body = lines
+      .slice(i + 1, end + 1)
+      .map((line) => stripPrefix(line, style));
+    blocks.push({
+      markerLine: i + 1,
+      startLine: i + 2,
+      endLine: end + 1,
+      prefix: style.prefix,
+      yaml: body.join('\n'),
+    });
+    i = end;
+  }
+
+  return blocks;
+}
+
+/**
+ * The block annotating a symbol declared at `line`: the first one below a
+ * Python header, where docstrings go, and otherwise the last one above it.
+ */
+export function findSymbolBlock(
+  blocks: readonly AnnotationBlock[],
+  symbol: { readonly language: string; readonly line: number },
+): AnnotationBlock | undefined {
+  if (symbol.language === 'python') {
+    return blocks.find((block) => block.markerLine > symbol.line);
+  }
+  return blocks.filter((block) => block.endLine < symbol.line).at(-1);
+}
+
+/**
+ * Parse a block's YAML into a comment- and style-preserving document.
+ */
+export function parseAnnotationDocument(block: AnnotationBlock): Document {
+  return parseDocument(block.yaml);
+}
+
+function renderBlock(block: AnnotationBlock, yaml: string): readonly string[] {
+  return yaml
+    .replace(/\n+$/, '')
+    .split('\n')
+    .map((line) =>
+      line === '' ? block.prefix.trimEnd() : `${block.prefix}${line}`,
+    );
+}
+
+/**
+ * Apply `edit` to the YAML of the block containing `line` (any line from the
+ * marker to the end of the block) and return the updated file content.
+ * Returns the content unchanged when no block contains the line or the edit
+ * leaves the document as it was.
+ */
+export function rewriteAnnotation(
+  content: string,
+  line: number,
+  edit: (doc: Document) => void,
+): string {
+  const block = findAnnotationBlocks(content).find(
+    (candidate) => line >= candidate.markerLine && line <= candidate.endLine,
+  );
+  if (!block) return content;
+
+  const doc = parseAnnotationDocument(block);
+  const before = doc.toString(TO_STRING_OPTIONS);
+  edit(doc);
+  const after = doc.toString(TO_STRING_OPTIONS);
+  if (after === before) return content;
+
+  const lines = content.split('\n');
+  lines.splice(
+    block.startLine - 1,
+    block.endLine - block.startLine + 1,
+    ...renderBlock(block, after),
+  );
+  return lines.join('\n');
+}
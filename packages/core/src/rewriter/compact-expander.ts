@@ -0,0 +1,187 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Rewrites one-line compact `knowgraph:` annotations into full @knowgraph YAML blocks in the file's comment style
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [rewriter, annotations, compact, yaml]
+ * context:
+ *   business_goal: Let a utility that outgrows its one-line annotation switch to the full form without retyping it
+ *   domain: rewriter
+ */
+import { extname } from 'node:path';
+import { stringify } from 'yaml';
+import { parseCompactAnnotation } from '../parsers/compact.js';
+
+/** Languages whose parsers read annotations from `/** ... *\/` doc comments. */
+const DOC_COMMENT_EXTENSIONS = new Set([
+  '.ts',
+  '.tsx',
+  '.js',
+  '.jsx',
+  '.mts',
+  '.cts',
+  '.java',
+]);
+
+const PYTHON_EXTENSIONS = new Set(['.py', '.pyi']);
+
+const LINE_COMMENT = /^([ \t]*)(\/\/|#|--)[ \t]*knowgraph:(.*)$/;
+const DOC_COMMENT = /^([ \t]*)\/\*\*?[ \t]*knowgraph:(.*?)\*\/[ \t]*$/;
+const DOCSTRING = /^([ \t]*)("""|''')[ \t]*knowgraph:(.*?)\2[ \t]*$/;
+
+export interface CompactExpansionError {
+  /** 1-based line of the compact annotation. */
+  readonly line: number;
+  readonly message: string;
+}
+
+export interface CompactExpansion {
+  readonly content: string;
+  /** 1-based lines, in the original content, of each annotation expanded. */
+  readonly expanded: readonly number[];
+  /** Compact annotations left as they were because they do not parse. */
+  readonly errors: readonly CompactExpansionError[];
+}
+
+interface CompactLine {
+  /** The text after the `knowgraph:` marker. */
+  readonly pairs: string;
+  readonly render: (yaml: readonly string[]) => readonly string[];
+  /**
+   * The pairs of a following line that continues this annotation, as Go
+   * `//knowgraph:` directive lines do.
+   */
+  readonly continuation?: (text: string) => string | undefined;
+}
+
+function scalarText(value: unknown): string {
+  return stringify(value, { lineWidth: 0 }).trimEnd();
+}
+
+/** YAML lines for `fields`, with lists in flow style as written by hand. */
+function yamlLines(fields: Readonly<Record<string, unknown>>): string[] {
+  return Object.entries(fields).flatMap(([key, value]) => {
+    if (Array.isArray(value)) {
+      const items = value.map((item) => {
+        const text = scalarText(item);
+        return /[,[\]{}]/.test(text) ? JSON.stringify(item) : text;
+      });
+      return [`${key}: [${items.join(', ')}]`];
+    }
+    if (typeof value === 'object' && value !== null) {
+      const nested = yamlLines(value as Record<string, unknown>);
+      return [`${key}:`, ...nested.map((line) => `  ${line}`)];
+    }
+    return [`${key}: ${scalarText(value)}`];
+  });
+}
+
+/**
+ * The full block replacing one compact annotation, or undefined when
+ * `text` is not one. Line comments stay line comments, except in
+ * languages whose parsers only read doc comments.
+ */
+function matchCompactLine(
+  text: string,
+  filePath: string,
+): CompactLine | undefined {
+  const ext = extname(filePath);
+  const docComment = (indent: string) => (yaml: readonly string[]) => [
+    `${indent}/**`,
+    `${indent} * @knowgraph`,
+    ...yaml.map((line) => `${indent} * ${line}`),
+    `${indent} */`,
+  ];
+
+  const doc = DOC_COMMENT.exec(text);
+  if (doc) return { pairs: doc[2], render: docComment(doc[1]) };
+
+  const docstring = DOCSTRING.exec(text);
+  if (docstring) {
+    const [, indent, quotes, pairs] = docstring;
+    return {
+      pairs,
+      render: (yaml) => [
+        `${indent}${quotes}`,
+        `${indent}@knowgraph`,
+        ...yaml.map((line) => `${indent}${line}`),
+        `${indent}${quotes}`,
+      ],
+    };
+  }
+
+  const comment = LINE_COMMENT.exec(text);
+  // Python reads annotations from docstrings, never from comments
+  if (!comment || PYTHON_EXTENSIONS.has(ext)) return undefined;
+  const [, indent, token, pairs] = comment;
+  const continuation = (next: string): string | undefined => {
+    const match = LINE_COMMENT.exec(next);
+    return match && match[1] === indent && match[2] === token
+      ? match[3]
+      : undefined;
+  };
+  if (token === '//' && DOC_COMMENT_EXTENSIONS.has(ext)) {
+    return { pairs, render: docComment(indent), continuation };
+  }
+  return {
+    pairs,
+    render: (yaml) =>
+      ['@knowgraph', ...yaml].map((line) => `${indent}${token} ${line}`),
+    continuation,
+  };
+}
+
+/**
+ * Rewrite every compact annotation in `content` as a full @knowgraph
+ * YAML block in the same place and comment style. Consecutive compact
+ * line comments are one annotation. `filePath` picks the style parsers of
+ * its language read.
+ */
+export function expandCompactAnnotations(
+  content: string,
+  filePath: string,
+): CompactExpansion {
+  const output: string[] = [];
+  const expanded: number[] = [];
+  const errors: CompactExpansionError[] = [];
+
+  const lines = content.split('\n');
+  for (let index = 0; index < lines.length; index++) {
+    const text = lines[index] ?? '';
+    const match = matchCompactLine(text, filePath);
+    if (!match) {
+      output.push(text);
+      continue;
+    }
+    const pairs = [match.pairs];
+    let end = index + 1;
+    for (; end < lines.length; end++) {
+      const more = match.continuation?.(lines[end] ?? '');
+      if (more === undefined) break;
+      pairs.push(more);
+    }
+    const original = lines.slice(index, end);
+    const line = index + 1;
+    index = end - 1;
+
+    let fields: Record<string, unknown>;
+    try {
+      fields = parseCompactAnnotation(pairs.join(' '));
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      errors.push({ line, message });
+      output.push(...original);
+      continue;
+    }
+    if (Object.keys(fields).length === 0) {
+      errors.push({ line, message: 'The annotation has no fields' });
+      output.push(...original);
+      continue;
+    }
+    output.push(...match.render(yamlLines(fields)));
+    expanded.push(line);
+  }
+
+  return { content: output.join('\n'), expanded, errors };
+}
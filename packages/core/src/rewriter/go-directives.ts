@@ -0,0 +1,155 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Converts // @knowgraph YAML blocks in Go files into //knowgraph: directive lines that godoc leaves out of rendered documentation
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [rewriter, annotations, go, godoc, compact]
+ * context:
+ *   business_goal: Keep annotations in Go code without cluttering the package documentation pkg.go.dev renders
+ *   domain: rewriter
+ */
+import { isDeepStrictEqual } from 'node:util';
+import { parse as parseYaml } from 'yaml';
+import { parseCompactAnnotation } from '../parsers/compact.js';
+
+const BLOCK_START = /^([ \t]*)\/\/[ \t]?@knowgraph[ \t]*$/;
+const LINE_WIDTH = 80;
+
+// Values that need no quotes in a compact pair
+const BARE_VALUE = /^[^\s"]+$/;
+const KEY = /^[\w-]+$/;
+
+export interface GoDirectiveError {
+  /** 1-based line of the `@knowgraph` marker. */
+  readonly line: number;
+  readonly message: string;
+}
+
+export interface GoDirectiveConversion {
+  readonly content: string;
+  /** 1-based lines, in the original content, of each block converted. */
+  readonly converted: readonly number[];
+  /** Blocks left as they were because they have no directive form. */
+  readonly errors: readonly GoDirectiveError[];
+}
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+function scalarText(key: string, value: unknown): string {
+  if (typeof value === 'number' || typeof value === 'boolean') {
+    return String(value);
+  }
+  if (typeof value !== 'string') {
+    throw new Error(`${key} cannot be written on one line`);
+  }
+  return value;
+}
+
+/** The `key=value` pairs that set `value` at `key`, nesting by dots. */
+function toPairs(key: string, value: unknown): string[] {
+  if (isRecord(value)) {
+    return Object.entries(value).flatMap(([child, nested]) => {
+      if (!KEY.test(child)) {
+        throw new Error(`${key}.${child} cannot be written as a key`);
+      }
+      return toPairs(`${key}.${child}`, nested);
+    });
+  }
+  if (Array.isArray(value)) {
+    const items = value.map((item) => scalarText(key, item));
+    if (items.length === 1 && !BARE_VALUE.test(items[0] ?? '')) {
+      return [`${key}=${JSON.stringify(items[0])}`];
+    }
+    if (items.some((item) => item.includes(',') || !BARE_VALUE.test(item))) {
+      throw new Error(
+        `${key} has list items that cannot be written on one line`,
+      );
+    }
+    return [`${key}=${items.join(',')}`];
+  }
+  const text = scalarText(key, value);
+  return [`${key}=${BARE_VALUE.test(text) ? text : JSON.stringify(text)}`];
+}
+
+/** Pack pairs into directive lines of about `LINE_WIDTH` columns. */
+function directiveLines(indent: string, pairs: readonly string[]): string[] {
+  const prefix = `${indent}//knowgraph:`;
+  const lines: string[] = [];
+  let current = '';
+  for (const pair of pairs) {
+    if (current && prefix.length + current.length + pair.length >= LINE_WIDTH) {
+      lines.push(prefix + current);
+      current = '';
+    }
+    current = current ? `${current} ${pair}` : pair;
+  }
+  if (current) lines.push(prefix + current);
+  return lines;
+}
+
+/**
+ * The directive lines replacing one YAML block, whose lines are `body`
+ * with their `//` prefixes. Throws when the block has no directive form
+ * that reads back the same.
+ */
+function convertBlock(indent: string, body: readonly string[]): string[] {
+  const yaml = body.map((line) => line.replace(/^\s*\/\/ ?/, ''));
+  if (yaml.some((line) => /(^|\s)#/.test(line))) {
+    throw new Error('The block has YAML comments, which would be lost');
+  }
+  const fields: unknown = parseYaml(yaml.join('\n'));
+  if (!isRecord(fields) || Object.keys(fields).length === 0) {
+    throw new Error('The block has no fields');
+  }
+  const pairs = Object.entries(fields).flatMap(([key, value]) =>
+    toPairs(key, value),
+  );
+  if (!isDeepStrictEqual(parseCompactAnnotation(pairs.join(' ')), fields)) {
+    throw new Error('The block would read back differently as directives');
+  }
+  return directiveLines(indent, pairs);
+}
+
+/**
+ * Rewrite every `// @knowgraph` YAML line-comment block in Go `content`
+ * as `//knowgraph:` directive lines of compact pairs. Go 1.19 and later
+ * leave directive lines out of rendered documentation, and gofmt keeps
+ * them at the end of the doc comment, so prose before the block still
+ * documents the declaration.
+ */
+export function toGoDirectives(content: string): GoDirectiveConversion {
+  const lines = content.split('\n');
+  const output: string[] = [];
+  const converted: number[] = [];
+  const errors: GoDirectiveError[] = [];
+
+  for (let index = 0; index < lines.length; index++) {
+    const text = lines[index] ?? '';
+    const start = BLOCK_START.exec(text);
+    if (!start) {
+      output.push(text);
+      continue;
+    }
+    const indent = start[1] ?? '';
+    let end = index + 1;
+    while (end < lines.length && lines[end]?.trimStart().startsWith('//')) {
+      end++;
+    }
+    const block = lines.slice(index, end);
+    const line = index + 1;
+    index = end - 1;
+    try {
+      output.push(...convertBlock(indent, block.slice(1)));
+      converted.push(line);
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      errors.push({ line, message });
+      output.push(...block);
+    }
+  }
+
+  return { content: output.join('\n'), converted, errors };
+}
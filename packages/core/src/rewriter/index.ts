@@ -0,0 +1,17 @@
+export type { AnnotationBlock } from './comment-rewriter.js';
+export {
+  findAnnotationBlocks,
+  findSymbolBlock,
+  parseAnnotationDocument,
+  rewriteAnnotation,
+} from './comment-rewriter.js';
+export type {
+  CompactExpansion,
+  CompactExpansionError,
+} from './compact-expander.js';
+export { expandCompactAnnotations } from './compact-expander.js';
+export type {
+  GoDirectiveConversion,
+  GoDirectiveError,
+} from './go-directives.js';
+export { toGoDirectives } from './go-directives.js';
@@ -0,0 +1,130 @@
+import { describe, it, expect } from 'vitest';
+import {
+  checkRuntimeConfig,
+  parseRuntimeSettings,
+  settingTarget,
+} from '../runtime-config.js';
+
+const VALUES = `
+payments:
+  env:
+    FRAUD_CHECK_URL: http://fraud-check.risk.svc:8080
+    LEDGER_URL: https://ledger.internal/api
+    OTEL_EXPORTER_OTLP_ENDPOINT: http://otel-collector:4317
+    LOG_LEVEL: info
+postgresql:
+  auth:
+    database: payments
+ingress:
+  hosts:
+    - host: payments.example.com
+`;
+
+const DEPLOYMENT = `
+apiVersion: v1
+kind: ConfigMap
+data:
+  CACHE_HOST: redis-master:6379
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+          env:
+            - name: DATABASE_URL
+              valueFrom:
+                secretKeyRef: { name: db, key: url }
+            - name: STRIPE_API_BASE
+              value: https://api.stripe.com
+`;
+
+describe('parseRuntimeSettings', () => {
+  it('keys env lists by variable name across documents', () => {
+    const settings = parseRuntimeSettings(DEPLOYMENT, 'deploy.yaml');
+    expect(settings).toContainEqual({
+      key: 'data.CACHE_HOST',
+      value: 'redis-master:6379',
+      file: 'deploy.yaml',
+    });
+    expect(settings).toContainEqual({
+      key: 'spec.template.spec.containers.0.env.DATABASE_URL',
+      value: '',
+      file: 'deploy.yaml',
+    });
+  });
+
+  it('names the file in parse errors', () => {
+    expect(() => parseRuntimeSettings('a: [b', 'values.yaml')).toThrow(
+      'Invalid YAML in values.yaml',
+    );
+  });
+});
+
+describe('settingTarget', () => {
+  const target = (key: string, value: string) =>
+    settingTarget({ key, value, file: 'f' });
+
+  it('finds hosts in URLs, connection strings, and host keys', () => {
+    expect(target('x', 'postgres://app:pw@orders-db:5432/orders')).toEqual({
+      host: 'orders-db',
+      scheme: 'postgres',
+    });
+    expect(target('CONN', 'Server=tcp:sql01,1433;Database=crm')).toEqual({
+      host: 'sql01',
+    });
+    expect(target('CACHE_HOST', 'redis-master:6379')).toEqual({
+      host: 'redis-master',
+    });
+    expect(target('env.DATABASE_URL', '')).toEqual({ host: 'DATABASE_URL' });
+  });
+
+  it('ignores plain values and local addresses', () => {
+    expect(target('LOG_LEVEL', 'info')).toBeUndefined();
+    expect(target('USE_PROXY_SERVER', 'true')).toBeUndefined();
+    expect(target('API_URL', 'http://localhost:3000')).toBeUndefined();
+  });
+});
+
+describe('checkRuntimeConfig', () => {
+  const settings = [
+    ...parseRuntimeSettings(VALUES, 'values.yaml'),
+    ...parseRuntimeSettings(DEPLOYMENT, 'deploy.yaml'),
+  ];
+
+  it('flags declared dependencies without configuration and the reverse', () => {
+    const report = checkRuntimeConfig(
+      {
+        name: 'payments',
+        dependencies: [
+          { kind: 'service', name: 'ledger-service' },
+          { kind: 'external_api', name: 'Stripe API' },
+          { kind: 'database', name: 'postgresql' },
+          { kind: 'service', name: 'notifications' },
+        ],
+      },
+      settings,
+      { ignore: ['OTEL_*'] },
+    );
+
+    expect(report.configured.map((c) => c.dependency.name)).toEqual([
+      'ledger-service',
+      'Stripe API',
+      'postgresql',
+    ]);
+    expect(report.configured[1]?.keys).toEqual([
+      'spec.template.spec.containers.0.env.STRIPE_API_BASE',
+    ]);
+    expect(report.unconfigured).toEqual([
+      { kind: 'service', name: 'notifications' },
+    ]);
+    expect(report.undeclared.map((u) => [u.setting.key, u.target])).toEqual([
+      ['payments.env.FRAUD_CHECK_URL', 'fraud-check.risk.svc'],
+      ['data.CACHE_HOST', 'redis-master'],
+      ['spec.template.spec.containers.0.env.DATABASE_URL', 'DATABASE_URL'],
+    ]);
+    expect(report.files).toEqual(['values.yaml', 'deploy.yaml']);
+  });
+});
@@ -0,0 +1,13 @@
+export type {
+  ConfiguredDependency,
+  RuntimeConfigCheckOptions,
+  RuntimeConfigReport,
+  RuntimeSetting,
+  UndeclaredSetting,
+} from './types.js';
+export {
+  checkRuntimeConfig,
+  parseRuntimeSettings,
+  readRuntimeSettings,
+  settingTarget,
+} from './runtime-config.js';
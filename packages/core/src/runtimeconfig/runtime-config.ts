@@ -0,0 +1,270 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Cross-references a service's declared dependencies with the connection strings and env vars in its Helm values and Kubernetes manifests
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [runtimeconfig, helm, kubernetes, dependencies, validation]
+ * context:
+ *   business_goal: Find dependencies a service really uses but never declared, and ones it declares but never uses
+ *   domain: runtimeconfig
+ */
+import { readFileSync } from 'node:fs';
+import { parseAllDocuments } from 'yaml';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DeclaredDependency } from '../graph/types.js';
+import type {
+  ConfiguredDependency,
+  RuntimeConfigCheckOptions,
+  RuntimeConfigReport,
+  RuntimeSetting,
+  UndeclaredSetting,
+} from './types.js';
+
+/** Words that say what a dependency is rather than which one it is. */
+const GENERIC_WORDS = new Set([
+  'service',
+  'svc',
+  'api',
+  'apis',
+  'db',
+  'database',
+  'server',
+  'client',
+  'http',
+  'grpc',
+]);
+
+/** Last key segments that name where something is, once normalized. */
+const CONNECTION_KEY =
+  /(host|hostname|addr|address|endpoint|url|uri|dsn|server|brokers|connectionstring)$/;
+
+const URL_TARGET =
+  /^([a-z][a-z0-9+.-]*):\/\/(?:[^@/\s]*@)?(\[[^\]]+\]|[^:/?#\s,]+)/i;
+/** `Server=db;Database=orders` style connection strings. */
+const PAIR_TARGET =
+  /(?:^|;)\s*(?:server|host|data source|address)\s*=\s*(?:tcp:)?([^;,:\s]+)/i;
+const BARE_TARGET = /^([A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*)(?::\d+)?$/;
+
+const FLAGS = /^(true|false|null|yes|no|on|off)$/i;
+
+const LOCAL_HOSTS = new Set([
+  'localhost',
+  '127.0.0.1',
+  '0.0.0.0',
+  '::1',
+  '[::1]',
+]);
+
+/** Lowercase letters and digits only, so `ORDERS_DB` meets `orders-db`. */
+function normalize(text: string): string {
+  return text.toLowerCase().replace(/[^a-z0-9]+/g, '');
+}
+
+function matchTerms(name: string): readonly string[] {
+  const words = name.toLowerCase().split(/[^a-z0-9]+/).filter(Boolean);
+  const specific = words.filter((word) => !GENERIC_WORDS.has(word)).join('');
+  return [...new Set([normalize(name), specific])].filter(
+    (term) => term.length > 1,
+  );
+}
+
+function lastSegment(key: string): string {
+  return key.slice(key.lastIndexOf('.') + 1);
+}
+
+function isEnvList(items: readonly unknown[]): boolean {
+  return (
+    items.length > 0 &&
+    items.every(
+      (item) =>
+        typeof item === 'object' &&
+        item !== null &&
+        typeof (item as { name?: unknown }).name === 'string' &&
+        ('value' in item || 'valueFrom' in item),
+    )
+  );
+}
+
+function collect(
+  node: unknown,
+  path: readonly string[],
+  file: string,
+  out: RuntimeSetting[],
+): void {
+  if (Array.isArray(node)) {
+    if (isEnvList(node)) {
+      // `env: [{ name, value }]`, as in containers and many charts
+      for (const item of node as { name: string; value?: unknown }[]) {
+        const value = item.value == null ? '' : String(item.value);
+        out.push({ key: [...path, item.name].join('.'), value, file });
+      }
+      return;
+    }
+    node.forEach((item, index) =>
+      collect(item, [...path, String(index)], file, out),
+    );
+    return;
+  }
+  if (typeof node === 'object' && node !== null) {
+    for (const [key, value] of Object.entries(node)) {
+      collect(value, [...path, key], file, out);
+    }
+    return;
+  }
+  if (node !== null && node !== undefined && path.length > 0) {
+    out.push({ key: path.join('.'), value: String(node), file });
+  }
+}
+
+/**
+ * The settings in YAML `text`: every scalar in Helm values, by dotted
+ * path, with `env` lists keyed by variable name. Multi-document
+ * manifests are read whole. Throws a parse error naming `file`.
+ */
+export function parseRuntimeSettings(
+  text: string,
+  file: string,
+): readonly RuntimeSetting[] {
+  const settings: RuntimeSetting[] = [];
+  for (const doc of parseAllDocuments(text)) {
+    const error = doc.errors[0];
+    if (error) {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid YAML in ${file}: ${error.message}`,
+      );
+    }
+    collect(doc.toJS(), [], file, settings);
+  }
+  return settings;
+}
+
+/** `parseRuntimeSettings` for the file at `path`. */
+export function readRuntimeSettings(path: string): readonly RuntimeSetting[] {
+  return parseRuntimeSettings(readFileSync(path, 'utf-8'), path);
+}
+
+/**
+ * Where a setting points: the host of a URL or connection string, or of
+ * a bare `host:port` under a connection key such as `DATABASE_HOST`. A
+ * connection key whose value comes from elsewhere points at its name.
+ * Undefined for anything else and for local addresses.
+ */
+export function settingTarget(
+  setting: RuntimeSetting,
+): { readonly host: string; readonly scheme?: string } | undefined {
+  const value = setting.value.trim();
+  const url = URL_TARGET.exec(value);
+  const pair = url ? undefined : PAIR_TARGET.exec(value);
+  const connectionKey = CONNECTION_KEY.test(
+    normalize(lastSegment(setting.key)),
+  );
+  let target: { host: string; scheme?: string } | undefined;
+  if (url?.[1] && url[2]) {
+    target = { host: url[2].toLowerCase(), scheme: url[1].toLowerCase() };
+  } else if (pair?.[1]) {
+    target = { host: pair[1].toLowerCase() };
+  } else if (connectionKey) {
+    const bare = BARE_TARGET.exec(value);
+    if (bare?.[1] && /[a-z]/i.test(bare[1]) && !FLAGS.test(bare[1])) {
+      target = { host: bare[1].toLowerCase() };
+    } else if (!value) {
+      target = { host: lastSegment(setting.key) };
+    }
+  }
+  return target && !LOCAL_HOSTS.has(target.host) ? target : undefined;
+}
+
+/**
+ * Whether a setting's key or target mentions one of `terms`. `wholeKey`
+ * looks at every key segment, so a subchart such as `postgresql.auth`
+ * counts; otherwise only the last one does.
+ */
+function mentions(
+  setting: RuntimeSetting,
+  terms: readonly string[],
+  wholeKey = true,
+): boolean {
+  const target = settingTarget(setting);
+  const segments = wholeKey
+    ? setting.key.split('.')
+    : [lastSegment(setting.key)];
+  const candidates = [
+    ...segments.map(normalize),
+    ...(target ? [normalize(target.host), target.scheme ?? ''] : []),
+  ];
+  return terms.some((term) =>
+    candidates.some((candidate) => candidate.includes(term)),
+  );
+}
+
+function ignorePattern(pattern: string): RegExp {
+  const source = pattern
+    .split('*')
+    .map((part) => part.replace(/[.+?^${}()|[\]\\]/g, '\\$&'))
+    .join('.*');
+  return new RegExp(`^${source}$`, 'i');
+}
+
+/**
+ * Check `service`'s declared dependencies against `settings`. A
+ * dependency is configured when a setting's key or target mentions its
+ * name, ignoring generic words such as `service` or `db`. A connection
+ * setting counts as undeclared when it mentions neither a dependency nor
+ * the service itself. Settings under an `ingress` key are the service's
+ * own address and are skipped.
+ */
+export function checkRuntimeConfig(
+  service: {
+    readonly name: string;
+    readonly dependencies: readonly DeclaredDependency[];
+  },
+  settings: readonly RuntimeSetting[],
+  options: RuntimeConfigCheckOptions = {},
+): RuntimeConfigReport {
+  const ignore = (options.ignore ?? []).map(ignorePattern);
+  const relevant = settings.filter(
+    (setting) =>
+      !setting.key.toLowerCase().split('.').includes('ingress') &&
+      !ignore.some(
+        (pattern) =>
+          pattern.test(setting.key) || pattern.test(lastSegment(setting.key)),
+      ),
+  );
+
+  const configured: ConfiguredDependency[] = [];
+  const unconfigured: DeclaredDependency[] = [];
+  const accounted = new Set<RuntimeSetting>();
+  for (const dependency of service.dependencies) {
+    const terms = matchTerms(dependency.name);
+    const matched = relevant.filter((setting) => mentions(setting, terms));
+    matched.forEach((setting) => accounted.add(setting));
+    if (matched.length > 0) {
+      configured.push({
+        dependency,
+        keys: [...new Set(matched.map((setting) => setting.key))],
+      });
+    } else {
+      unconfigured.push(dependency);
+    }
+  }
+
+  const own = matchTerms(service.name);
+  const undeclared: UndeclaredSetting[] = [];
+  for (const setting of relevant) {
+    // Values are often nested under the chart's own name, so only the
+    // last segment can refer to the service itself.
+    if (accounted.has(setting) || mentions(setting, own, false)) continue;
+    const target = settingTarget(setting);
+    if (target) undeclared.push({ setting, target: target.host });
+  }
+
+  return {
+    service: service.name,
+    files: [...new Set(settings.map((setting) => setting.file))],
+    configured,
+    unconfigured,
+    undeclared,
+  };
+}
@@ -0,0 +1,52 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for checking a service's declared dependencies against its Helm values and Kubernetes environment configuration
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [runtimeconfig, helm, kubernetes, dependencies, types, interface]
+ * context:
+ *   business_goal: Let deployment manifests from any chart layout be checked the same way
+ *   domain: runtimeconfig
+ */
+import type { DeclaredDependency } from '../graph/types.js';
+
+/** One value from a values file or manifest, such as an env var. */
+export interface RuntimeSetting {
+  /** Dotted path to the value; env vars end in their name. */
+  readonly key: string;
+  /** Empty when the value comes from a Secret or ConfigMap reference. */
+  readonly value: string;
+  readonly file: string;
+}
+
+/** A declared dependency and the settings that configure it. */
+export interface ConfiguredDependency {
+  readonly dependency: DeclaredDependency;
+  readonly keys: readonly string[];
+}
+
+/** A connection setting that no declared dependency accounts for. */
+export interface UndeclaredSetting {
+  readonly setting: RuntimeSetting;
+  /** The host it points at, or its name when the value is indirect. */
+  readonly target: string;
+}
+
+export interface RuntimeConfigReport {
+  readonly service: string;
+  readonly files: readonly string[];
+  readonly configured: readonly ConfiguredDependency[];
+  /** Declared, with no setting that mentions them. */
+  readonly unconfigured: readonly DeclaredDependency[];
+  /** Configured, but not declared. */
+  readonly undeclared: readonly UndeclaredSetting[];
+}
+
+export interface RuntimeConfigCheckOptions {
+  /**
+   * Keys that are not dependencies, such as telemetry endpoints. Matched
+   * against the full key or its last segment; `*` matches anything.
+   */
+  readonly ignore?: readonly string[];
+}
@@ -0,0 +1,97 @@
+import { describe, it, expect } from 'vitest';
+import { scaffoldModule } from '../module-scaffold.js';
+import { createDefaultRegistry } from '../../parsers/registry.js';
+
+const TEMPLATE = {
+  directory: 'services',
+  metadata: {
+    owner: 'payments-team',
+    tags: ['payments', 'api'],
+    context: { domain: 'payments' },
+    dependencies: { services: ['ledger'] },
+  },
+  handlers: ['CreateInvoice'],
+};
+
+describe('scaffoldModule', () => {
+  it('writes an annotated doc.go and handlers from the template', () => {
+    const scaffold = scaffoldModule({
+      name: 'invoice-api',
+      description: 'Issues and voids invoices',
+      services: ['token-service', 'ledger'],
+      template: TEMPLATE,
+    });
+    expect(scaffold.directory).toBe('services/invoice-api');
+    expect(scaffold.packageName).toBe('invoice_api');
+    expect(scaffold.files.map((file) => file.path)).toEqual([
+      'services/invoice-api/doc.go',
+      'services/invoice-api/create_invoice.go',
+    ]);
+    expect(scaffold.files[0].content).toBe(
+      [
+        '// @knowgraph',
+        '// type: module',
+        '// description: Issues and voids invoices',
+        '// owner: payments-team',
+        '// status: experimental',
+        '// tags: [payments, api]',
+        '// context:',
+        '//   domain: payments',
+        '// dependencies:',
+        '//   services: [ledger, token-service]',
+        '',
+        'package invoice_api',
+        '',
+      ].join('\n'),
+    );
+    expect(scaffold.files[1].content).toContain(
+      'func CreateInvoice(w http.ResponseWriter, r *http.Request) {',
+    );
+  });
+
+  it('writes annotations the Go parser reads back', () => {
+    const scaffold = scaffoldModule({
+      name: 'billing',
+      description: 'Bills customers',
+      owner: 'billing-team',
+      handlers: ['refund'],
+      template: TEMPLATE,
+    });
+    const registry = createDefaultRegistry();
+    const [doc, handler] = scaffold.files.map((file) => {
+      const parser = registry.getParser(file.path)!;
+      return parser.parse(file.content, file.path).results;
+    });
+    expect(doc).toHaveLength(1);
+    expect(doc[0]).toMatchObject({
+      name: 'billing',
+      entityType: 'module',
+      metadata: { owner: 'billing-team', context: { domain: 'payments' } },
+    });
+    expect(handler[0]).toMatchObject({
+      name: 'Refund',
+      entityType: 'function',
+      metadata: { description: 'Handles refund requests' },
+    });
+  });
+
+  it('rejects names outside the project and invalid templates', () => {
+    expect(() =>
+      scaffoldModule({ name: '../billing', description: 'Bills' }),
+    ).toThrow('must be a path inside the project');
+    expect(() =>
+      scaffoldModule({
+        name: 'billing',
+        description: 'Bills',
+        template: { metadata: { status: 'shipped' } },
+      }),
+    ).toThrow('The annotation of billing would be invalid: status');
+    expect(() =>
+      scaffoldModule({
+        name: 'billing',
+        description: 'Bills',
+        handlers: ['createInvoice', 'create-invoice'],
+      }),
+    ).toThrow('would both be written to create_invoice.go');
+  });
+});
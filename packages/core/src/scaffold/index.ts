@@ -0,0 +1,7 @@
+export type {
+  ModuleScaffold,
+  ModuleTemplate,
+  ScaffoldFile,
+  ScaffoldOptions,
+} from './types.js';
+export { scaffoldModule } from './module-scaffold.js';
@@ -0,0 +1,190 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Scaffolds a Go module directory with an annotated doc.go and handler skeletons from an org template
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [scaffold, go, templates, annotations]
+ * context:
+ *   business_goal: Start every module from the layout the organization has agreed on
+ *   domain: scaffold
+ */
+import { posix } from 'node:path';
+import { goFileName, goIdentifier, nameWords } from '../codegen/go-names.js';
+import { yamlScalar } from '../draft/draft.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { ExtendedMetadataSchema } from '../types/entity.js';
+import type { ExtendedMetadata } from '../types/entity.js';
+import type { ModuleScaffold, ScaffoldFile, ScaffoldOptions } from './types.js';
+
+/** The fields an annotation leads with, in the order people write them. */
+const LEADING_FIELDS = ['type', 'description', 'owner', 'status'];
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+function scalar(value: unknown, inFlow = false): string {
+  return typeof value === 'string'
+    ? yamlScalar(value, inFlow)
+    : JSON.stringify(value ?? null);
+}
+
+/**
+ * `fields` as block YAML, with lists of scalars on one line as people
+ * write `tags` and `services`.
+ */
+function yamlLines(fields: Readonly<Record<string, unknown>>): string[] {
+  const lines: string[] = [];
+  for (const [key, value] of Object.entries(fields)) {
+    if (value === undefined) continue;
+    if (Array.isArray(value) && !value.some(isRecord)) {
+      const items = value.map((item) => scalar(item, true));
+      lines.push(`${key}: [${items.join(', ')}]`);
+    } else if (Array.isArray(value)) {
+      lines.push(`${key}:`);
+      for (const item of value) {
+        const [first, ...rest] = isRecord(item)
+          ? yamlLines(item)
+          : [scalar(item)];
+        lines.push(`  - ${first}`, ...rest.map((line) => `    ${line}`));
+      }
+    } else if (isRecord(value) && Object.keys(value).length > 0) {
+      lines.push(`${key}:`, ...yamlLines(value).map((line) => `  ${line}`));
+    } else {
+      lines.push(`${key}: ${isRecord(value) ? '{}' : scalar(value)}`);
+    }
+  }
+  return lines;
+}
+
+/** `fields` as Go line comments under the `@knowgraph` marker. */
+function annotationComment(
+  fields: Readonly<Record<string, unknown>>,
+): string[] {
+  const ordered = Object.fromEntries(
+    [
+      ...LEADING_FIELDS.filter((key) => key in fields),
+      ...Object.keys(fields).filter((key) => !LEADING_FIELDS.includes(key)),
+    ].map((key) => [key, fields[key]]),
+  );
+  return [
+    '// @knowgraph',
+    ...yamlLines(ordered).map((line) => `// ${line}`),
+  ];
+}
+
+function moduleFields(options: ScaffoldOptions): Record<string, unknown> {
+  const base = options.template?.metadata ?? {};
+  const dependencies = (base.dependencies ?? {}) as Record<string, unknown>;
+  const declared = Array.isArray(dependencies.services)
+    ? (dependencies.services as unknown[])
+    : [];
+  // Annotated entries name their service under `name`
+  const named = new Set(
+    declared.map((entry) => (isRecord(entry) ? entry.name : entry)),
+  );
+  const services = [
+    ...declared,
+    ...new Set(options.services?.filter((name) => !named.has(name))),
+  ];
+  return {
+    status: 'experimental',
+    ...base,
+    type: 'module',
+    description: options.description,
+    ...(options.owner ? { owner: options.owner } : {}),
+    ...(services.length > 0
+      ? { dependencies: { ...dependencies, services } }
+      : {}),
+  };
+}
+
+function moduleDirectory(options: ScaffoldOptions): string {
+  const name = options.name.replace(/\\/g, '/');
+  if (posix.isAbsolute(name) || name.split('/').includes('..')) {
+    throw createKnowgraphError(
+      'usage',
+      `Module name ${options.name} must be a path inside the project`,
+    );
+  }
+  return posix.normalize(
+    posix.join(options.template?.directory ?? '.', name.replace(/\/+$/, '')),
+  );
+}
+
+function handlerFile(
+  handler: string,
+  packageName: string,
+  metadata: ExtendedMetadata,
+): string {
+  const fields = {
+    type: 'function',
+    description: `Handles ${nameWords(handler).join(' ')} requests`,
+    ...(metadata.owner ? { owner: metadata.owner } : {}),
+    ...(metadata.status ? { status: metadata.status } : {}),
+  };
+  return [
+    `package ${packageName}`,
+    '',
+    'import "net/http"',
+    '',
+    ...annotationComment(fields),
+    `func ${goIdentifier(handler)}(w http.ResponseWriter, r *http.Request) {`,
+    '\thttp.Error(w, "not implemented", http.StatusNotImplemented)',
+    '}',
+    '',
+  ].join('\n');
+}
+
+/**
+ * The files of a new Go module: a `doc.go` whose annotation merges the
+ * template's metadata with the options, and a `net/http` handler skeleton
+ * per handler, each annotated as well. Throws a `schema` error when the
+ * merged annotation is invalid, and a `usage` error when the name leaves
+ * the project or two handlers would share a file.
+ */
+export function scaffoldModule(options: ScaffoldOptions): ModuleScaffold {
+  const directory = moduleDirectory(options);
+  const packageName = goFileName(posix.basename(directory));
+  const fields = moduleFields(options);
+  const result = ExtendedMetadataSchema.safeParse(fields);
+  if (!result.success) {
+    const issue = result.error.issues[0];
+    throw createKnowgraphError(
+      'schema',
+      `The annotation of ${directory} would be invalid: ${issue?.path.join('.')} ${issue?.message}`,
+    );
+  }
+  const metadata = result.data;
+
+  const files: ScaffoldFile[] = [
+    {
+      path: posix.join(directory, 'doc.go'),
+      content: [
+        ...annotationComment(fields),
+        '',
+        `package ${packageName}`,
+        '',
+      ].join('\n'),
+    },
+  ];
+  const handlers = options.handlers ?? options.template?.handlers ?? [];
+  const written = new Map([['doc.go', 'the package doc']]);
+  for (const handler of handlers) {
+    const fileName = `${goFileName(handler)}.go`;
+    const other = written.get(fileName);
+    if (other) {
+      throw createKnowgraphError(
+        'usage',
+        `Handler ${handler} and ${other} would both be written to ${fileName}`,
+      );
+    }
+    written.set(fileName, handler);
+    files.push({
+      path: posix.join(directory, fileName),
+      content: handlerFile(handler, packageName, metadata),
+    });
+  }
+  return { directory, packageName, metadata, files };
+}
@@ -0,0 +1,51 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for scaffolding new Go modules that are annotated from their first commit
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [scaffold, go, templates, types, interface]
+ * context:
+ *   business_goal: Let each organization define its own module templates without changing knowgraph
+ *   domain: scaffold
+ */
+import type { ExtendedMetadata } from '../types/entity.js';
+
+/** An org's defaults for new modules, as `templates` in the manifest. */
+export interface ModuleTemplate {
+  /** Where new modules go, relative to the project root. */
+  readonly directory?: string;
+  /** Fields every module's annotation starts with. */
+  readonly metadata?: Readonly<Record<string, unknown>>;
+  /** Handlers to write skeletons for when none are given. */
+  readonly handlers?: readonly string[];
+}
+
+export interface ScaffoldOptions {
+  /** The module's path under the template's directory, e.g. `billing`. */
+  readonly name: string;
+  readonly description: string;
+  /** Overrides the template's owner. */
+  readonly owner?: string;
+  /** Overrides the template's handlers. */
+  readonly handlers?: readonly string[];
+  /** Services the module depends on, added to the template's. */
+  readonly services?: readonly string[];
+  readonly template?: ModuleTemplate;
+}
+
+export interface ScaffoldFile {
+  /** Relative to the project root. */
+  readonly path: string;
+  readonly content: string;
+}
+
+export interface ModuleScaffold {
+  /** Relative to the project root. */
+  readonly directory: string;
+  readonly packageName: string;
+  /** The module's annotation, as `doc.go` declares it. */
+  readonly metadata: ExtendedMetadata;
+  /** `doc.go` first, then a file per handler. */
+  readonly files: readonly ScaffoldFile[];
+}
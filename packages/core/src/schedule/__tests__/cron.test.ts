@@ -0,0 +1,121 @@
+import { afterEach, beforeEach, describe, expect, it, vi } from 'vitest';
+import { nextCronTime, parseCronExpression, scheduleCron } from '../cron.js';
+
+function next(expression: string, after: string): string | undefined {
+  return nextCronTime(parseCronExpression(expression), new Date(after), {
+    utc: true,
+  })?.toISOString();
+}
+
+describe('parseCronExpression', () => {
+  it('expands values, ranges, lists, steps, and names', () => {
+    const schedule = parseCronExpression('0,30 */6 1-5 jan,JUL mon-fri');
+    expect([...schedule.minutes]).toEqual([0, 30]);
+    expect([...schedule.hours]).toEqual([0, 6, 12, 18]);
+    expect([...schedule.daysOfMonth]).toEqual([1, 2, 3, 4, 5]);
+    expect([...schedule.months]).toEqual([1, 7]);
+    expect([...schedule.daysOfWeek]).toEqual([1, 2, 3, 4, 5]);
+    expect(schedule.restrictsDayOfMonth).toBe(true);
+  });
+
+  it('treats 7 as Sunday and accepts shorthands', () => {
+    expect([...parseCronExpression('0 0 * * 7').daysOfWeek]).toEqual([0]);
+    expect(parseCronExpression('@hourly').minutes).toEqual(new Set([0]));
+  });
+
+  it('rejects malformed expressions', () => {
+    expect(() => parseCronExpression('* * *')).toThrow('expected 5 fields');
+    expect(() => parseCronExpression('60 * * * *')).toThrow('Invalid minute');
+    expect(() => parseCronExpression('* * * * */0')).toThrow(
+      'Invalid day of week',
+    );
+    expect(() => parseCronExpression('* 5-1 * * *')).toThrow('Invalid hour');
+  });
+});
+
+describe('nextCronTime', () => {
+  it('finds the next matching minute after the given time', () => {
+    expect(next('0 */6 * * *', '2024-01-01T05:59:30Z')).toBe(
+      '2024-01-01T06:00:00.000Z',
+    );
+    expect(next('0 */6 * * *', '2024-01-01T06:00:00Z')).toBe(
+      '2024-01-01T12:00:00.000Z',
+    );
+    expect(next('@daily', '2024-12-31T23:59:00Z')).toBe(
+      '2025-01-01T00:00:00.000Z',
+    );
+  });
+
+  it('skips to matching days of the week', () => {
+    expect(next('30 9 * * mon-fri', '2024-01-05T10:00:00Z')).toBe(
+      '2024-01-08T09:30:00.000Z',
+    );
+  });
+
+  it('matches either day field when both are restricted', () => {
+    expect(next('0 0 13 * 5', '2024-01-01T00:00:00Z')).toBe(
+      '2024-01-05T00:00:00.000Z',
+    );
+  });
+
+  it('waits for leap days and gives up on impossible dates', () => {
+    expect(next('0 0 29 2 *', '2024-03-01T00:00:00Z')).toBe(
+      '2028-02-29T00:00:00.000Z',
+    );
+    expect(next('0 0 30 2 *', '2024-03-01T00:00:00Z')).toBeUndefined();
+  });
+});
+
+describe('scheduleCron', () => {
+  beforeEach(() => {
+    vi.useFakeTimers();
+    vi.setSystemTime(new Date('2024-01-01T00:03:00Z'));
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  it('runs the task at each matching time until stopped', async () => {
+    const runs: string[] = [];
+    const task = scheduleCron(
+      parseCronExpression('*/5 * * * *'),
+      (at) => {
+        runs.push(at.toISOString());
+      },
+      { utc: true },
+    );
+    expect(task.next()?.toISOString()).toBe('2024-01-01T00:05:00.000Z');
+    await vi.advanceTimersByTimeAsync(7 * 60_000);
+    expect(runs).toEqual([
+      '2024-01-01T00:05:00.000Z',
+      '2024-01-01T00:10:00.000Z',
+    ]);
+    task.stop();
+    await vi.advanceTimersByTimeAsync(10 * 60_000);
+    expect(runs).toHaveLength(2);
+    expect(task.next()).toBeUndefined();
+  });
+
+  it('reports failures and stops when the signal aborts', async () => {
+    const controller = new AbortController();
+    const errors: unknown[] = [];
+    const task = scheduleCron(
+      parseCronExpression('* * * * *'),
+      () => {
+        throw new Error('scan failed');
+      },
+      {
+        utc: true,
+        signal: controller.signal,
+        onError: (err) => errors.push(err),
+      },
+    );
+    await vi.advanceTimersByTimeAsync(2 * 60_000);
+    expect(errors).toHaveLength(2);
+    controller.abort();
+    await vi.advanceTimersByTimeAsync(2 * 60_000);
+    expect(errors).toHaveLength(2);
+    expect(task.next()).toBeUndefined();
+  });
+});
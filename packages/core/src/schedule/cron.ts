@@ -0,0 +1,300 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses five-field cron expressions and runs tasks at the times they match
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [schedule, cron, timer]
+ * context:
+ *   business_goal: Keep the index fresh on a schedule without external cron glue
+ *   domain: schedule
+ */
+import type {
+  CronOptions,
+  CronSchedule,
+  ScheduledTask,
+  ScheduleOptions,
+} from './types.js';
+
+interface FieldSpec {
+  readonly name: string;
+  readonly min: number;
+  readonly max: number;
+  readonly names?: readonly string[];
+}
+
+const FIELDS: readonly FieldSpec[] = [
+  { name: 'minute', min: 0, max: 59 },
+  { name: 'hour', min: 0, max: 23 },
+  { name: 'day of month', min: 1, max: 31 },
+  {
+    name: 'month',
+    min: 1,
+    max: 12,
+    names: [
+      'jan',
+      'feb',
+      'mar',
+      'apr',
+      'may',
+      'jun',
+      'jul',
+      'aug',
+      'sep',
+      'oct',
+      'nov',
+      'dec',
+    ],
+  },
+  {
+    name: 'day of week',
+    min: 0,
+    max: 7,
+    names: ['sun', 'mon', 'tue', 'wed', 'thu', 'fri', 'sat'],
+  },
+];
+
+const MACROS: Readonly<Record<string, string>> = {
+  '@yearly': '0 0 1 1 *',
+  '@annually': '0 0 1 1 *',
+  '@monthly': '0 0 1 * *',
+  '@weekly': '0 0 * * 0',
+  '@daily': '0 0 * * *',
+  '@midnight': '0 0 * * *',
+  '@hourly': '0 * * * *',
+};
+
+/** How far ahead to look before deciding an expression never matches. */
+const SEARCH_YEARS = 8;
+
+/** setTimeout fires at once past this delay, so longer waits are chained. */
+const MAX_TIMEOUT_MS = 2 ** 31 - 1;
+
+function parseValue(value: string, spec: FieldSpec): number {
+  const named = spec.names?.indexOf(value.toLowerCase()) ?? -1;
+  if (named >= 0) return spec.min + named;
+  if (!/^\d+$/.test(value)) return Number.NaN;
+  return Number(value);
+}
+
+function parseField(field: string, spec: FieldSpec): Set<number> {
+  const values = new Set<number>();
+  for (const part of field.split(',')) {
+    const [range, stepText, extra] = part.split('/');
+    const step = stepText === undefined ? 1 : Number(stepText);
+    let low: number;
+    let high: number;
+    if (range === '*') {
+      low = spec.min;
+      high = spec.max;
+    } else {
+      const [first, last, rest] = range.split('-');
+      low = parseValue(first, spec);
+      high =
+        last === undefined
+          ? stepText === undefined
+            ? low
+            : spec.max
+          : parseValue(last, spec);
+      if (rest !== undefined) high = Number.NaN;
+    }
+    if (
+      extra !== undefined ||
+      !Number.isInteger(step) ||
+      step < 1 ||
+      !Number.isInteger(low) ||
+      !Number.isInteger(high) ||
+      low < spec.min ||
+      high > spec.max ||
+      low > high
+    ) {
+      throw new Error(
+        `Invalid ${spec.name} "${part}": expected values from ${spec.min} to ${spec.max}`,
+      );
+    }
+    for (let value = low; value <= high; value += step) values.add(value);
+  }
+  return values;
+}
+
+/**
+ * Parse a five-field cron expression: minute, hour, day of month, month,
+ * and day of week. Fields take `*`, values, ranges (`1-5`), lists (`1,15`),
+ * and steps (`0-23/6`, or after `*` for the whole range); months and days
+ * of week also take three-letter names. The `@hourly`, `@daily`, `@weekly`,
+ * `@monthly`, and `@yearly` shorthands are accepted too. Throws on anything
+ * else.
+ */
+export function parseCronExpression(expression: string): CronSchedule {
+  const trimmed = expression.trim();
+  const fields = (MACROS[trimmed.toLowerCase()] ?? trimmed).split(/\s+/);
+  if (fields.length !== FIELDS.length) {
+    throw new Error(
+      `Invalid cron expression "${expression}": expected ${FIELDS.length} fields, got ${fields.length}`,
+    );
+  }
+  const [minutes, hours, daysOfMonth, months, daysOfWeek] = fields.map(
+    (field, i) => parseField(field, FIELDS[i]),
+  );
+  if (daysOfWeek.delete(7)) daysOfWeek.add(0);
+  return {
+    expression: trimmed,
+    minutes,
+    hours,
+    daysOfMonth,
+    months,
+    daysOfWeek,
+    restrictsDayOfMonth: !fields[2].startsWith('*'),
+    restrictsDayOfWeek: !fields[4].startsWith('*'),
+  };
+}
+
+interface DateParts {
+  readonly year: number;
+  readonly month: number;
+  readonly day: number;
+  readonly hour: number;
+  readonly minute: number;
+  readonly weekday: number;
+}
+
+function partsOf(date: Date, utc: boolean): DateParts {
+  return utc
+    ? {
+        year: date.getUTCFullYear(),
+        month: date.getUTCMonth() + 1,
+        day: date.getUTCDate(),
+        hour: date.getUTCHours(),
+        minute: date.getUTCMinutes(),
+        weekday: date.getUTCDay(),
+      }
+    : {
+        year: date.getFullYear(),
+        month: date.getMonth() + 1,
+        day: date.getDate(),
+        hour: date.getHours(),
+        minute: date.getMinutes(),
+        weekday: date.getDay(),
+      };
+}
+
+function makeDate(
+  utc: boolean,
+  year: number,
+  month: number,
+  day = 1,
+  hour = 0,
+  minute = 0,
+): Date {
+  return utc
+    ? new Date(Date.UTC(year, month - 1, day, hour, minute))
+    : new Date(year, month - 1, day, hour, minute);
+}
+
+/**
+ * Cron's day rule: when both day fields are restricted a day matches
+ * either one, otherwise it must match both.
+ */
+function dayMatches(schedule: CronSchedule, parts: DateParts): boolean {
+  const byMonth = schedule.daysOfMonth.has(parts.day);
+  const byWeek = schedule.daysOfWeek.has(parts.weekday);
+  return schedule.restrictsDayOfMonth && schedule.restrictsDayOfWeek
+    ? byMonth || byWeek
+    : byMonth && byWeek;
+}
+
+/**
+ * The first minute after `after` that the schedule matches, or undefined
+ * when it never does (such as `0 0 30 2 *`).
+ */
+export function nextCronTime(
+  schedule: CronSchedule,
+  after: Date,
+  options: CronOptions = {},
+): Date | undefined {
+  const utc = options.utc === true;
+  const start = partsOf(after, utc);
+  const limit = makeDate(utc, start.year + SEARCH_YEARS, 1);
+  let candidate = makeDate(
+    utc,
+    start.year,
+    start.month,
+    start.day,
+    start.hour,
+    start.minute + 1,
+  );
+  while (candidate < limit) {
+    const p = partsOf(candidate, utc);
+    if (!schedule.months.has(p.month)) {
+      candidate = makeDate(utc, p.year, p.month + 1);
+    } else if (!dayMatches(schedule, p)) {
+      candidate = makeDate(utc, p.year, p.month, p.day + 1);
+    } else if (!schedule.hours.has(p.hour)) {
+      candidate = makeDate(utc, p.year, p.month, p.day, p.hour + 1);
+    } else if (!schedule.minutes.has(p.minute)) {
+      candidate = makeDate(utc, p.year, p.month, p.day, p.hour, p.minute + 1);
+    } else {
+      return candidate;
+    }
+  }
+  return undefined;
+}
+
+/**
+ * Run `task` each time the schedule matches until stopped or `signal`
+ * aborts. The next run is planned once the current one settles, so runs
+ * never overlap; times missed while a run was still going are skipped.
+ * The timer does not keep the process alive.
+ */
+export function scheduleCron(
+  schedule: CronSchedule,
+  task: (scheduledAt: Date) => Promise<void> | void,
+  options: ScheduleOptions = {},
+): ScheduledTask {
+  let timer: ReturnType<typeof setTimeout> | undefined;
+  let nextRun: Date | undefined;
+  let stopped = false;
+
+  function wait(at: Date): void {
+    const delay = Math.min(at.getTime() - Date.now(), MAX_TIMEOUT_MS);
+    timer = setTimeout(
+      () => {
+        if (Date.now() < at.getTime()) {
+          wait(at);
+        } else {
+          void run(at);
+        }
+      },
+      Math.max(0, delay),
+    );
+    timer.unref();
+  }
+
+  function plan(): void {
+    nextRun = stopped ? undefined : nextCronTime(schedule, new Date(), options);
+    if (nextRun) wait(nextRun);
+  }
+
+  async function run(at: Date): Promise<void> {
+    try {
+      await task(at);
+    } catch (err) {
+      options.onError?.(err);
+    }
+    plan();
+  }
+
+  function stop(): void {
+    stopped = true;
+    nextRun = undefined;
+    if (timer) clearTimeout(timer);
+  }
+
+  if (options.signal?.aborted) {
+    stopped = true;
+  } else {
+    options.signal?.addEventListener('abort', stop, { once: true });
+    plan();
+  }
+  return { next: () => nextRun, stop };
+}
@@ -0,0 +1,7 @@
+export type {
+  CronOptions,
+  CronSchedule,
+  ScheduledTask,
+  ScheduleOptions,
+} from './types.js';
+export { nextCronTime, parseCronExpression, scheduleCron } from './cron.js';
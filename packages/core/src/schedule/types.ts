@@ -0,0 +1,44 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for parsed cron expressions and the tasks scheduled from them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [schedule, cron, types, interface]
+ * context:
+ *   business_goal: Accept the cron syntax operators already know
+ *   domain: schedule
+ */
+
+/** A five-field cron expression expanded into the values each field allows. */
+export interface CronSchedule {
+  readonly expression: string;
+  readonly minutes: ReadonlySet<number>;
+  readonly hours: ReadonlySet<number>;
+  readonly daysOfMonth: ReadonlySet<number>;
+  readonly months: ReadonlySet<number>;
+  /** 0 is Sunday; a 7 in the expression is stored as 0. */
+  readonly daysOfWeek: ReadonlySet<number>;
+  /** Whether the day-of-month field was restricted rather than `*`. */
+  readonly restrictsDayOfMonth: boolean;
+  /** Whether the day-of-week field was restricted rather than `*`. */
+  readonly restrictsDayOfWeek: boolean;
+}
+
+export interface CronOptions {
+  /** Read the schedule in UTC rather than the local time zone. */
+  readonly utc?: boolean;
+}
+
+export interface ScheduleOptions extends CronOptions {
+  /** Stops the schedule when aborted. */
+  readonly signal?: AbortSignal;
+  /** Receives errors thrown by a run; the schedule carries on either way. */
+  readonly onError?: (err: unknown) => void;
+}
+
+export interface ScheduledTask {
+  /** When the task runs next, or undefined once stopped. */
+  readonly next: () => Date | undefined;
+  readonly stop: () => void;
+}
@@ -0,0 +1,115 @@
+import { describe, expect, it } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  entityInScope,
+  formatScope,
+  parseScope,
+  pathInScope,
+  scopeGraph,
+} from '../scope.js';
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  tags: string[] = [],
+  services: string[] = [],
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType: 'service',
+    description: name,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: null,
+    status: null,
+    metadata: {
+      type: 'service',
+      description: name,
+      dependencies: { services, databases: ['postgres'] },
+    },
+    tags,
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+describe('parseScope', () => {
+  it('parses path and tag scopes', () => {
+    expect(parseScope('path=./services/auth/')).toEqual({
+      kind: 'path',
+      path: 'services/auth',
+    });
+    expect(parseScope('tag=payments')).toEqual({
+      kind: 'tag',
+      tag: 'payments',
+    });
+    expect(formatScope(parseScope('path=.'))).toBe('path=.');
+  });
+
+  it('rejects other forms', () => {
+    expect(() => parseScope('owner=team')).toThrow('Invalid scope');
+    expect(() => parseScope('payments')).toThrow('path=<dir> or tag=<tag>');
+    expect(() => parseScope('tag=')).toThrow('Invalid scope');
+  });
+});
+
+describe('entityInScope', () => {
+  const scopes = [
+    parseScope('path=services/auth'),
+    parseScope('path=libs'),
+    parseScope('tag=payments'),
+  ];
+
+  it('matches any scope of a kind and every kind', () => {
+    const auth = makeEntity('login', 'services/auth/login.ts', ['payments']);
+    expect(entityInScope(auth, scopes)).toBe(true);
+    expect(entityInScope({ ...auth, filePath: 'libs/pay.ts' }, scopes)).toBe(
+      true,
+    );
+    expect(entityInScope({ ...auth, tags: ['auth'] }, scopes)).toBe(false);
+  });
+
+  it('matches whole path segments only', () => {
+    expect(pathInScope('services/auth-legacy/x.ts', scopes)).toBe(false);
+    expect(pathInScope('services\\auth\\x.ts', scopes)).toBe(true);
+    expect(pathInScope('anything.ts', [])).toBe(true);
+  });
+});
+
+describe('scopeGraph', () => {
+  it('keeps cross-scope neighbors as stubs in both directions', () => {
+    const auth = makeEntity('auth', 'services/auth/auth.ts', [], ['users']);
+    const users = makeEntity('users', 'services/users/users.ts');
+    const web = makeEntity('web', 'apps/web/web.ts', [], ['auth']);
+    const jobs = makeEntity('jobs', 'apps/jobs/jobs.ts', [], ['users']);
+    const graph = buildDependencyGraph([auth, users, web, jobs]);
+
+    const scoped = scopeGraph(graph, new Set([auth.id]));
+    const nodes = scoped.nodes.map((node) => [node.name, node.stub ?? false]);
+    expect(nodes).toEqual([
+      ['auth', false],
+      ['users', true],
+      ['web', true],
+      ['postgres', false],
+    ]);
+    const edges = scoped.edges.map(({ from, to, kind }) => ({
+      from,
+      to,
+      kind,
+    }));
+    expect(edges).toEqual([
+      { from: auth.id, to: users.id, kind: 'service' },
+      { from: auth.id, to: 'external:database:postgres', kind: 'database' },
+      { from: web.id, to: auth.id, kind: 'service' },
+    ]);
+  });
+});
@@ -0,0 +1,9 @@
+export type { ScanScope } from './types.js';
+export {
+  entityInScope,
+  formatScope,
+  parseScope,
+  pathInScope,
+  scopeGraph,
+  tagsInScope,
+} from './scope.js';
@@ -0,0 +1,120 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses path and tag scopes, matches entities against them, and cuts scoped subgraphs with stub neighbors
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [scope, subgraph, filter, graph]
+ * context:
+ *   business_goal: Let teams build and query the part of a large graph they own without scanning everything
+ *   domain: scope
+ */
+import type { DependencyGraph, GraphEdge, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { ScanScope } from './types.js';
+
+/** `path` relative to the scan root, with `/` separators and no `./`. */
+function normalizePath(path: string): string {
+  return path
+    .replace(/\\/g, '/')
+    .replace(/^(?:\.\/)+/, '')
+    .replace(/\/+$/, '')
+    .replace(/^\.$/, '');
+}
+
+/**
+ * Parse a `--scope` value: `path=<dir>` (relative to the scan root) or
+ * `tag=<tag>`. Throws on anything else.
+ */
+export function parseScope(spec: string): ScanScope {
+  const separator = spec.indexOf('=');
+  const key = spec.slice(0, separator).trim();
+  const value = spec.slice(separator + 1).trim();
+  if (separator > 0 && value) {
+    if (key === 'path') return { kind: 'path', path: normalizePath(value) };
+    if (key === 'tag') return { kind: 'tag', tag: value };
+  }
+  throw new Error(`Invalid scope "${spec}": use path=<dir> or tag=<tag>`);
+}
+
+export function formatScope(scope: ScanScope): string {
+  return scope.kind === 'path'
+    ? `path=${scope.path || '.'}`
+    : `tag=${scope.tag}`;
+}
+
+/**
+ * Whether a file falls under the path scopes. Files match any path scope;
+ * without path scopes every file does.
+ */
+export function pathInScope(
+  filePath: string,
+  scopes: readonly ScanScope[],
+): boolean {
+  const paths = scopes.flatMap((s) => (s.kind === 'path' ? [s.path] : []));
+  if (paths.length === 0) return true;
+  const file = normalizePath(filePath);
+  return paths.some(
+    (path) => path === '' || file === path || file.startsWith(`${path}/`),
+  );
+}
+
+/**
+ * Whether tags satisfy the tag scopes: any one tag scope must match;
+ * without tag scopes any tags do.
+ */
+export function tagsInScope(
+  tags: readonly string[] | undefined,
+  scopes: readonly ScanScope[],
+): boolean {
+  const wanted = scopes.flatMap((s) => (s.kind === 'tag' ? [s.tag] : []));
+  return wanted.length === 0 || wanted.some((tag) => tags?.includes(tag));
+}
+
+/**
+ * Whether `entity` is in scope. Scopes of one kind widen the scope and
+ * the two kinds narrow it, so `path=services/auth` with `tag=payments`
+ * keeps payments entities under `services/auth`.
+ */
+export function entityInScope(
+  entity: Pick<StoredEntity, 'filePath' | 'tags'>,
+  scopes: readonly ScanScope[],
+): boolean {
+  return (
+    pathInScope(entity.filePath, scopes) && tagsInScope(entity.tags, scopes)
+  );
+}
+
+/**
+ * The part of `graph` around the nodes in `inScope`. Every edge with an
+ * end in scope is kept, in both directions; its other end is kept as a
+ * stub (`stub: true`) when it is an out-of-scope node, so traversals from
+ * the subgraph still see what it depends on and what depends on it.
+ * External nodes are kept when an in-scope node uses them. Node and edge
+ * order follows `graph`.
+ */
+export function scopeGraph(
+  graph: DependencyGraph,
+  inScope: ReadonlySet<string>,
+): DependencyGraph {
+  const external = new Set(
+    graph.nodes.filter((node) => node.external).map((node) => node.id),
+  );
+  const edges: GraphEdge[] = [];
+  const kept = new Set<string>();
+  for (const edge of graph.edges) {
+    if (!inScope.has(edge.from) && !inScope.has(edge.to)) continue;
+    edges.push(edge);
+    kept.add(edge.from);
+    kept.add(edge.to);
+  }
+  const nodes: GraphNode[] = [];
+  for (const node of graph.nodes) {
+    if (inScope.has(node.id)) {
+      nodes.push(node);
+    } else if (kept.has(node.id)) {
+      nodes.push(external.has(node.id) ? node : { ...node, stub: true });
+    }
+  }
+  return { nodes, edges };
+}
@@ -0,0 +1,16 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for path and tag scopes that narrow a scan or query to a subgraph
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [scope, subgraph, filter, types, interface]
+ * context:
+ *   business_goal: Let path and tag scopes be combined the same way everywhere they are accepted
+ *   domain: scope
+ */
+
+/** A `path=<dir>` or `tag=<tag>` scope. */
+export type ScanScope =
+  | { readonly kind: 'path'; readonly path: string }
+  | { readonly kind: 'tag'; readonly tag: string };
@@ -0,0 +1,140 @@
+import { describe, it, expect } from 'vitest';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { EntityType, ExtendedMetadata, Link } from '../../types/entity.js';
+import { buildScorecards, scorecardGrade } from '../scorecard.js';
+
+function makeEntity(
+  name: string,
+  owner: string | null,
+  entityType: EntityType,
+  extra: Partial<ExtendedMetadata> = {},
+  fields: Partial<StoredEntity> = {},
+): StoredEntity {
+  const metadata = { type: entityType, description: `The ${name}`, ...extra };
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType,
+    description: `The ${name}`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner,
+    status: null,
+    metadata,
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...fields,
+  };
+}
+
+const runbook: Link = { type: 'runbook', url: 'https://wiki.test/runbook' };
+const git = (date: string) => ({
+  git: { last_commit: 'abc', last_author: 'dev', last_modified: date },
+});
+const now = new Date('2024-07-01T00:00:00Z');
+
+const entities = [
+  makeEntity('CheckoutService', 'checkout', 'service', {
+    ...git('2024-06-01'),
+    dependencies: { services: ['LegacyTax'] },
+  }),
+  makeEntity('cart', 'checkout', 'module', git('2023-01-01')),
+  makeEntity('SearchService', 'search', 'service', git('2024-06-15'), {
+    links: [runbook],
+  }),
+  makeEntity('LegacyTax', 'billing', 'function', {}, { status: 'deprecated' }),
+];
+
+describe('buildScorecards', () => {
+  const report = buildScorecards(entities, buildDependencyGraph(entities), {
+    coverage: [
+      {
+        category: 'checkout',
+        annotatedCount: 3,
+        totalCount: 4,
+        percentage: 75,
+      },
+      { category: 'search', annotatedCount: 2, totalCount: 2, percentage: 100 },
+    ],
+    findings: [{ filePath: 'src/cart.ts' }, { filePath: 'src/cart.ts' }],
+    now,
+  });
+  const byOwner = new Map(report.scorecards.map((card) => [card.owner, card]));
+
+  it('counts each metric per owner', () => {
+    expect(byOwner.get('checkout')?.metrics).toEqual({
+      entities: 2,
+      coverage: 75,
+      dated: 2,
+      stale: 1,
+      violations: 2,
+      deprecatedDependencies: 1,
+      needRunbook: 1,
+      missingRunbooks: 1,
+      weakDescriptions: 1,
+    });
+  });
+
+  it('leaves unmeasured scores out of the overall score', () => {
+    const billing = byOwner.get('billing');
+    expect(billing?.scores).toEqual({
+      coverage: null,
+      freshness: null,
+      policy: 100,
+      dependencies: 100,
+      runbooks: null,
+      descriptions: 100,
+    });
+    expect(billing?.score).toBe(100);
+  });
+
+  it('grades and sorts owners by score', () => {
+    expect(report.scorecards.map((card) => card.owner)).toEqual([
+      'billing',
+      'search',
+      'checkout',
+    ]);
+    const checkout = byOwner.get('checkout');
+    // 0.25 * 75 + 0.2 * 50 + 0.15 * 0 + 0.15 * 50 + 0.15 * 0 + 0.1 * 50
+    expect(checkout?.score).toBe(41);
+    expect(checkout?.grade).toBe('F');
+    expect(byOwner.get('search')?.grade).toBe('A');
+  });
+
+  it('reports trends against a previous report', () => {
+    const next = buildScorecards(entities, buildDependencyGraph(entities), {
+      now,
+      previous: {
+        generatedAt: '2024-06-01T00:00:00.000Z',
+        staleAfterDays: 180,
+        scorecards: [
+          { ...report.scorecards[0], owner: 'billing', score: 100 },
+          { ...report.scorecards[0], owner: 'checkout', score: 10 },
+        ],
+      },
+    });
+    const trends = Object.fromEntries(
+      next.scorecards.map((card) => [card.owner, card.trend]),
+    );
+    expect(trends).toEqual({ billing: 'steady', checkout: 'up', search: null });
+  });
+
+  it('maps scores to letter grades', () => {
+    expect([95, 85, 70, 60, 59].map(scorecardGrade)).toEqual([
+      'A',
+      'B',
+      'C',
+      'D',
+      'F',
+    ]);
+  });
+});
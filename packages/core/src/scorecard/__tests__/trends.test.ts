@@ -0,0 +1,151 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { ScanMetrics } from '../../history/types.js';
+import {
+  appendScorecardReport,
+  buildLeaderboard,
+  coverageTrends,
+  readScorecardHistory,
+  teamTrends,
+} from '../trends.js';
+import type {
+  OwnerScorecard,
+  RepositoryHistory,
+  ScorecardGrade,
+  ScorecardReport,
+} from '../types.js';
+
+function card(owner: string, score: number, grade: ScorecardGrade) {
+  return { owner, score, grade } as OwnerScorecard;
+}
+
+function report(
+  generatedAt: string,
+  scorecards: readonly OwnerScorecard[],
+): ScorecardReport {
+  return { generatedAt, staleAfterDays: 180, scorecards };
+}
+
+function scan(timestamp: string, coverage: number): ScanMetrics {
+  return {
+    timestamp,
+    files: 10,
+    entities: 20,
+    edges: 5,
+    coverage,
+    byEntity: {},
+  };
+}
+
+const repos: readonly RepositoryHistory[] = [
+  {
+    namespace: 'acme/payments',
+    scans: [
+      scan('2024-05-01T00:00:00Z', 60),
+      scan('2024-06-01T00:00:00Z', 70),
+    ],
+    scorecards: [
+      report('2024-05-01T00:00:00Z', [card('checkout', 70, 'C')]),
+      report('2024-06-01T00:00:00Z', [
+        card('checkout', 85, 'B'),
+        card('billing', 92, 'A'),
+      ]),
+    ],
+  },
+  {
+    namespace: 'acme/search',
+    scans: [],
+    scorecards: [report('2024-06-02T00:00:00Z', [card('search', 85, 'B')])],
+  },
+];
+
+describe('buildLeaderboard', () => {
+  it('ranks the latest grade of every team across repositories', () => {
+    const board = buildLeaderboard(repos);
+    expect(board).toMatchObject({
+      repositories: 2,
+      teams: 3,
+      averageScore: 87,
+    });
+    expect(
+      board.entries.map((e) => [e.rank, e.namespace, e.owner, e.trend]),
+    ).toEqual([
+      [1, 'acme/payments', 'billing', null],
+      [2, 'acme/payments', 'checkout', 'up'],
+      [2, 'acme/search', 'search', null],
+    ]);
+    expect(board.entries[1].previousScore).toBe(70);
+  });
+
+  it('is empty without recorded scorecards', () => {
+    const board = buildLeaderboard([
+      { namespace: null, scans: [], scorecards: [] },
+    ]);
+    expect(board).toEqual({
+      repositories: 0,
+      teams: 0,
+      averageScore: null,
+      entries: [],
+    });
+  });
+});
+
+describe('teamTrends', () => {
+  it('lists each team score over time', () => {
+    expect(teamTrends(repos, { owner: 'checkout' })).toEqual([
+      {
+        namespace: 'acme/payments',
+        owner: 'checkout',
+        points: [
+          { timestamp: '2024-05-01T00:00:00Z', score: 70, grade: 'C' },
+          { timestamp: '2024-06-01T00:00:00Z', score: 85, grade: 'B' },
+        ],
+      },
+    ]);
+  });
+
+  it('drops points before since', () => {
+    const trends = teamTrends(repos, { since: '2024-06-01' });
+    expect(trends.map((t) => [t.owner, t.points.length])).toEqual([
+      ['billing', 1],
+      ['checkout', 1],
+      ['search', 1],
+    ]);
+  });
+});
+
+describe('coverageTrends', () => {
+  it('reads coverage from the scan history', () => {
+    const [payments, search] = coverageTrends(repos);
+    expect(payments.points.map((p) => p.coverage)).toEqual([60, 70]);
+    expect(search).toEqual({ namespace: 'acme/search', points: [] });
+  });
+});
+
+describe('scorecard history', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'knowgraph-scorecards-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('appends and reads reports in order', () => {
+    const path = join(dir, 'nested', 'scorecards.jsonl');
+    expect(readScorecardHistory(path)).toEqual([]);
+    appendScorecardReport(path, repos[0].scorecards[0]);
+    appendScorecardReport(path, repos[0].scorecards[1]);
+    expect(readScorecardHistory(path)).toEqual(repos[0].scorecards);
+  });
+
+  it('reports the line of a malformed entry', () => {
+    const path = join(dir, 'scorecards.jsonl');
+    writeFileSync(path, '{}\nnot json\n');
+    expect(() => readScorecardHistory(path)).toThrow('scorecards.jsonl:2');
+  });
+});
@@ -0,0 +1,33 @@
+export type {
+  ScorecardGrade,
+  ScorecardTrend,
+  ScorecardMetricName,
+  ScorecardMetrics,
+  ScorecardScores,
+  OwnerScorecard,
+  ScorecardReport,
+  ScorecardFinding,
+  ScorecardOptions,
+  RepositoryHistory,
+  LeaderboardEntry,
+  Leaderboard,
+  ScorePoint,
+  TeamTrend,
+  CoveragePoint,
+  CoverageTrend,
+  TrendFilter,
+} from './types.js';
+export {
+  DEFAULT_STALE_AFTER_DAYS,
+  SCORECARD_WEIGHTS,
+  buildScorecards,
+  scorecardGrade,
+  scoreTrend,
+} from './scorecard.js';
+export {
+  appendScorecardReport,
+  buildLeaderboard,
+  coverageTrends,
+  readScorecardHistory,
+  teamTrends,
+} from './trends.js';
@@ -0,0 +1,250 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Grades each owner on coverage, freshness, policy findings, deprecated dependencies, runbooks, and description quality
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [scorecard, ownership, adoption, grading]
+ * context:
+ *   business_goal: Grade teams on measures they can improve themselves
+ *   domain: scorecard
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import {
+  descriptionThreshold,
+  scoreDescription,
+} from '../lint/description-quality.js';
+import type { DescriptionThresholds } from '../lint/types.js';
+import type {
+  OwnerScorecard,
+  ScorecardGrade,
+  ScorecardMetricName,
+  ScorecardMetrics,
+  ScorecardOptions,
+  ScorecardReport,
+  ScorecardScores,
+  ScorecardTrend,
+} from './types.js';
+
+export const DEFAULT_STALE_AFTER_DAYS = 180;
+
+const UNOWNED = '(no owner)';
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+/** How much each measured score counts toward the overall one. */
+export const SCORECARD_WEIGHTS: Readonly<Record<ScorecardMetricName, number>> =
+  {
+    coverage: 0.25,
+    freshness: 0.2,
+    policy: 0.15,
+    dependencies: 0.15,
+    runbooks: 0.15,
+    descriptions: 0.1,
+  };
+
+const GRADES: readonly (readonly [number, ScorecardGrade])[] = [
+  [90, 'A'],
+  [80, 'B'],
+  [70, 'C'],
+  [60, 'D'],
+];
+
+export function scorecardGrade(score: number): ScorecardGrade {
+  return GRADES.find(([min]) => score >= min)?.[1] ?? 'F';
+}
+
+interface Tally {
+  entities: number;
+  dated: number;
+  stale: number;
+  violations: number;
+  deprecatedDependencies: number;
+  needRunbook: number;
+  missingRunbooks: number;
+  weakDescriptions: number;
+}
+
+function lastModified(entity: StoredEntity): number | null {
+  const { metadata } = entity;
+  const date = 'git' in metadata ? metadata.git?.last_modified : undefined;
+  const time = date === undefined ? NaN : Date.parse(date);
+  return Number.isNaN(time) ? null : time;
+}
+
+function needsRunbook(entity: StoredEntity): boolean {
+  const { metadata } = entity;
+  return (
+    entity.entityType === 'service' ||
+    ('slo' in metadata && metadata.slo !== undefined) ||
+    ('operational' in metadata && metadata.operational !== undefined)
+  );
+}
+
+function hasWeakDescription(
+  entity: StoredEntity,
+  thresholds: DescriptionThresholds | undefined,
+): boolean {
+  const { metadata } = entity;
+  const impact =
+    'context' in metadata ? metadata.context?.revenue_impact : undefined;
+  const { score } = scoreDescription(entity.description, entity.name);
+  return score < descriptionThreshold(impact, thresholds);
+}
+
+/** The share of `count` out of `total` left over, as a 0-100 score. */
+function remainder(count: number, total: number): number | null {
+  if (total === 0) return null;
+  return Math.max(0, Math.round((1 - count / total) * 100));
+}
+
+function scoresOf(metrics: ScorecardMetrics): ScorecardScores {
+  return {
+    coverage: metrics.coverage === null ? null : Math.round(metrics.coverage),
+    freshness: remainder(metrics.stale, metrics.dated),
+    policy: remainder(metrics.violations, metrics.entities),
+    dependencies: remainder(metrics.deprecatedDependencies, metrics.entities),
+    runbooks: remainder(metrics.missingRunbooks, metrics.needRunbook),
+    descriptions: remainder(metrics.weakDescriptions, metrics.entities),
+  };
+}
+
+/** The weighted mean of the measured scores, reweighted to sum to one. */
+function overall(scores: ScorecardScores): number {
+  let total = 0;
+  let weight = 0;
+  for (const [name, value] of Object.entries(scores)) {
+    if (value === null) continue;
+    const w = SCORECARD_WEIGHTS[name as ScorecardMetricName];
+    total += value * w;
+    weight += w;
+  }
+  return weight === 0 ? 0 : Math.round(total / weight);
+}
+
+/** How `score` moved from `previous`; null without a previous score. */
+export function scoreTrend(
+  score: number,
+  previous: number | null,
+): ScorecardTrend | null {
+  if (previous === null) return null;
+  if (score > previous) return 'up';
+  if (score < previous) return 'down';
+  return 'steady';
+}
+
+/**
+ * Grade every owner of `entities`. Coverage comes from `options.coverage`;
+ * an entity is stale when its git `last_modified` is older than
+ * `staleAfterDays`; findings count against each owner with an entity in
+ * their file; deprecated dependencies are graph edges to a deprecated
+ * entity from one that is not; a description is weak when
+ * `scoreDescription` puts it below the threshold for the entity's revenue
+ * impact. Scores with nothing to measure are left
+ * out of the overall score rather than counted as zero.
+ */
+export function buildScorecards(
+  entities: readonly StoredEntity[],
+  graph: DependencyGraph,
+  options: ScorecardOptions = {},
+): ScorecardReport {
+  const now = options.now ?? new Date();
+  const staleAfterDays = options.staleAfterDays ?? DEFAULT_STALE_AFTER_DAYS;
+  const cutoff = now.getTime() - staleAfterDays * DAY_MS;
+  const ownerOf = (entity: StoredEntity): string => entity.owner ?? UNOWNED;
+
+  const tallies = new Map<string, Tally>();
+  const tally = (owner: string): Tally => {
+    let found = tallies.get(owner);
+    if (!found) {
+      found = {
+        entities: 0,
+        dated: 0,
+        stale: 0,
+        violations: 0,
+        deprecatedDependencies: 0,
+        needRunbook: 0,
+        missingRunbooks: 0,
+        weakDescriptions: 0,
+      };
+      tallies.set(owner, found);
+    }
+    return found;
+  };
+
+  const ownersByFile = new Map<string, Set<string>>();
+  for (const entity of entities) {
+    const owner = ownerOf(entity);
+    const counts = tally(owner);
+    counts.entities += 1;
+    const modified = lastModified(entity);
+    if (modified !== null) {
+      counts.dated += 1;
+      if (modified < cutoff) counts.stale += 1;
+    }
+    if (needsRunbook(entity)) {
+      counts.needRunbook += 1;
+      if (!entity.links.some((link) => link.type === 'runbook')) {
+        counts.missingRunbooks += 1;
+      }
+    }
+    if (hasWeakDescription(entity, options.descriptionThresholds)) {
+      counts.weakDescriptions += 1;
+    }
+    const owners = ownersByFile.get(entity.filePath) ?? new Set<string>();
+    owners.add(owner);
+    ownersByFile.set(entity.filePath, owners);
+  }
+
+  for (const finding of options.findings ?? []) {
+    for (const owner of ownersByFile.get(finding.filePath) ?? []) {
+      tally(owner).violations += 1;
+    }
+  }
+
+  const byId = new Map(entities.map((entity) => [entity.id, entity]));
+  const dependents = new Set<string>();
+  for (const edge of graph.edges) {
+    const from = byId.get(edge.from);
+    const to = byId.get(edge.to);
+    if (!from || !to || from.status === 'deprecated') continue;
+    if (to.status !== 'deprecated' || dependents.has(from.id)) continue;
+    dependents.add(from.id);
+    tally(ownerOf(from)).deprecatedDependencies += 1;
+  }
+
+  const coverage = new Map(
+    (options.coverage ?? []).map((entry) => [entry.category, entry.percentage]),
+  );
+  const previous = new Map(
+    (options.previous?.scorecards ?? []).map((card) => [
+      card.owner,
+      card.score,
+    ]),
+  );
+
+  const scorecards: OwnerScorecard[] = [...tallies].map(([owner, counts]) => {
+    const metrics: ScorecardMetrics = {
+      ...counts,
+      coverage: coverage.get(owner) ?? null,
+    };
+    const scores = scoresOf(metrics);
+    const score = overall(scores);
+    const previousScore = previous.get(owner) ?? null;
+    return {
+      owner,
+      metrics,
+      scores,
+      score,
+      grade: scorecardGrade(score),
+      previousScore,
+      trend: scoreTrend(score, previousScore),
+    };
+  });
+  scorecards.sort(
+    (a, b) => b.score - a.score || compareStrings(a.owner, b.owner),
+  );
+
+  return { generatedAt: now.toISOString(), staleAfterDays, scorecards };
+}
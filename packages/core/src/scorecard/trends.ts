@@ -0,0 +1,173 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Records scorecards to a JSON Lines history and builds leaderboards and score and coverage trends from it
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [scorecard, trends, leaderboard, history, jsonl]
+ * context:
+ *   business_goal: Show organization-wide knowledge health over time without recomputing it from raw snapshots
+ *   domain: scorecard
+ */
+import { appendFileSync, existsSync, mkdirSync, readFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { scoreTrend } from './scorecard.js';
+import type {
+  CoverageTrend,
+  Leaderboard,
+  LeaderboardEntry,
+  RepositoryHistory,
+  ScorecardReport,
+  ScorePoint,
+  TeamTrend,
+  TrendFilter,
+} from './types.js';
+
+/**
+ * Parse the scorecard history at `historyPath`, oldest report first. A
+ * missing file is empty. Throws on a line that is not JSON, with its line
+ * number.
+ */
+export function readScorecardHistory(
+  historyPath: string,
+): readonly ScorecardReport[] {
+  if (!existsSync(historyPath)) return [];
+  const reports: ScorecardReport[] = [];
+  const lines = readFileSync(historyPath, 'utf-8').split('\n');
+  lines.forEach((line, index) => {
+    if (line.trim() === '') return;
+    try {
+      reports.push(JSON.parse(line) as ScorecardReport);
+    } catch {
+      throw createKnowgraphError(
+        'parse',
+        `Invalid scorecard history entry at ${historyPath}:${index + 1}`,
+      );
+    }
+  });
+  return reports;
+}
+
+/** Append one report to the history, creating the file when needed. */
+export function appendScorecardReport(
+  historyPath: string,
+  report: ScorecardReport,
+): void {
+  mkdirSync(dirname(historyPath), { recursive: true });
+  appendFileSync(historyPath, `${JSON.stringify(report)}\n`, 'utf-8');
+}
+
+function byNamespace(
+  a: { readonly namespace: string | null },
+  b: { readonly namespace: string | null },
+): number {
+  return compareStrings(a.namespace ?? '', b.namespace ?? '');
+}
+
+/**
+ * Rank every team by the latest report of its repository. Trends compare
+ * with the report recorded before it, so they follow the history rather
+ * than whatever `--previous` file the report was built against.
+ */
+export function buildLeaderboard(
+  repositories: readonly RepositoryHistory[],
+): Leaderboard {
+  const entries: Omit<LeaderboardEntry, 'rank'>[] = [];
+  for (const repo of repositories) {
+    const latest = repo.scorecards.at(-1);
+    if (!latest) continue;
+    const before = new Map(
+      (repo.scorecards.at(-2)?.scorecards ?? []).map((card) => [
+        card.owner,
+        card.score,
+      ]),
+    );
+    for (const card of latest.scorecards) {
+      const previousScore = before.get(card.owner) ?? null;
+      entries.push({
+        namespace: repo.namespace,
+        owner: card.owner,
+        score: card.score,
+        grade: card.grade,
+        previousScore,
+        trend: scoreTrend(card.score, previousScore),
+        recordedAt: latest.generatedAt,
+      });
+    }
+  }
+  entries.sort(
+    (a, b) =>
+      b.score - a.score ||
+      compareStrings(a.owner, b.owner) ||
+      byNamespace(a, b),
+  );
+
+  // Equal scores share a rank
+  const ranked: LeaderboardEntry[] = [];
+  entries.forEach((entry, index) => {
+    const tied = index > 0 && entries[index - 1].score === entry.score;
+    ranked.push({ rank: tied ? ranked[index - 1].rank : index + 1, ...entry });
+  });
+  const total = entries.reduce((sum, entry) => sum + entry.score, 0);
+  const recorded = repositories.filter((repo) => repo.scorecards.length > 0);
+  return {
+    repositories: recorded.length,
+    teams: new Set(entries.map((entry) => entry.owner)).size,
+    averageScore:
+      entries.length === 0 ? null : Math.round(total / entries.length),
+    entries: ranked,
+  };
+}
+
+/** Each team's score in each recorded report, oldest first. */
+export function teamTrends(
+  repositories: readonly RepositoryHistory[],
+  filter: TrendFilter = {},
+): readonly TeamTrend[] {
+  const trends: TeamTrend[] = [];
+  for (const repo of [...repositories].sort(byNamespace)) {
+    const byOwner = new Map<string, ScorePoint[]>();
+    for (const report of repo.scorecards) {
+      if (filter.since && report.generatedAt < filter.since) continue;
+      for (const card of report.scorecards) {
+        if (filter.owner && card.owner !== filter.owner) continue;
+        const points = byOwner.get(card.owner) ?? [];
+        points.push({
+          timestamp: report.generatedAt,
+          score: card.score,
+          grade: card.grade,
+        });
+        byOwner.set(card.owner, points);
+      }
+    }
+    const owners = [...byOwner.keys()].sort(compareStrings);
+    for (const owner of owners) {
+      trends.push({
+        namespace: repo.namespace,
+        owner,
+        points: byOwner.get(owner) ?? [],
+      });
+    }
+  }
+  return trends;
+}
+
+/** Each repository's coverage at every recorded scan, oldest first. */
+export function coverageTrends(
+  repositories: readonly RepositoryHistory[],
+  filter: Pick<TrendFilter, 'since'> = {},
+): readonly CoverageTrend[] {
+  return [...repositories].sort(byNamespace).map((repo) => ({
+    namespace: repo.namespace,
+    points: repo.scans
+      .filter((scan) => !filter.since || scan.timestamp >= filter.since)
+      .map(({ timestamp, coverage, files, entities }) => ({
+        timestamp,
+        coverage,
+        files,
+        entities,
+      })),
+  }));
+}
@@ -0,0 +1,156 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for per-owner scorecards that grade coverage, freshness, policy, deprecated dependencies, runbooks, and descriptions
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [scorecard, ownership, adoption, types, interface]
+ * context:
+ *   business_goal: Let scorecards be rendered for Slack, HTML, and Markdown from one result
+ *   domain: scorecard
+ */
+import type { CoverageBreakdown } from '../coverage/types.js';
+import type { ScanMetrics } from '../history/types.js';
+import type { DescriptionThresholds } from '../lint/types.js';
+
+export type ScorecardGrade = 'A' | 'B' | 'C' | 'D' | 'F';
+
+/** How an owner's score moved since the previous report. */
+export type ScorecardTrend = 'up' | 'down' | 'steady';
+
+export type ScorecardMetricName =
+  | 'coverage'
+  | 'freshness'
+  | 'policy'
+  | 'dependencies'
+  | 'runbooks'
+  | 'descriptions';
+
+/** The facts counted for one owner. */
+export interface ScorecardMetrics {
+  readonly entities: number;
+  /** Share of the owner's files with an annotation, as a percentage. */
+  readonly coverage: number | null;
+  /** Entities with git metadata, the only ones whose age is known. */
+  readonly dated: number;
+  /** Dated entities whose file has not changed within `staleAfterDays`. */
+  readonly stale: number;
+  /** Validation and lint findings in files the owner's entities live in. */
+  readonly violations: number;
+  /** Entities that depend on a deprecated entity in the repo. */
+  readonly deprecatedDependencies: number;
+  /** Services and entities with SLOs or operational metadata. */
+  readonly needRunbook: number;
+  /** Those of `needRunbook` with no `runbook` link. */
+  readonly missingRunbooks: number;
+  /** Entities whose description scores below its criticality's threshold. */
+  readonly weakDescriptions: number;
+}
+
+/** One metric as a 0-100 score; null when nothing was measured. */
+export type ScorecardScores = Readonly<
+  Record<ScorecardMetricName, number | null>
+>;
+
+export interface OwnerScorecard {
+  readonly owner: string;
+  readonly metrics: ScorecardMetrics;
+  readonly scores: ScorecardScores;
+  /** Weighted mean of the measured scores, rounded. */
+  readonly score: number;
+  readonly grade: ScorecardGrade;
+  readonly previousScore: number | null;
+  readonly trend: ScorecardTrend | null;
+}
+
+export interface ScorecardReport {
+  /** ISO 8601 time the report was built. */
+  readonly generatedAt: string;
+  readonly staleAfterDays: number;
+  /** Best score first. */
+  readonly scorecards: readonly OwnerScorecard[];
+}
+
+/** A validation or lint finding, attributed by the file it is in. */
+export interface ScorecardFinding {
+  readonly filePath: string;
+}
+
+export interface ScorecardOptions {
+  /** File coverage by owner, as `calculateCoverage` reports it. */
+  readonly coverage?: readonly CoverageBreakdown[];
+  readonly findings?: readonly ScorecardFinding[];
+  /** Defaults to `DEFAULT_STALE_AFTER_DAYS`. */
+  readonly staleAfterDays?: number;
+  /** Lowest passing description score per revenue impact. */
+  readonly descriptionThresholds?: DescriptionThresholds;
+  /** An earlier report, for trends. */
+  readonly previous?: ScorecardReport;
+  readonly now?: Date;
+}
+
+/** The recorded history of one repository, as served for trends. */
+export interface RepositoryHistory {
+  /** The repository's `org/repo` namespace, or null for an unnamed one. */
+  readonly namespace: string | null;
+  /** Scan history, oldest first, as `readScanHistory` returns it. */
+  readonly scans: readonly ScanMetrics[];
+  /** Recorded scorecards, oldest first. */
+  readonly scorecards: readonly ScorecardReport[];
+}
+
+export interface LeaderboardEntry {
+  readonly rank: number;
+  readonly namespace: string | null;
+  readonly owner: string;
+  readonly score: number;
+  readonly grade: ScorecardGrade;
+  /** The score in the repository's report before the latest one. */
+  readonly previousScore: number | null;
+  readonly trend: ScorecardTrend | null;
+  /** When the latest report was recorded. */
+  readonly recordedAt: string;
+}
+
+/** The latest recorded grade of every team in every repository. */
+export interface Leaderboard {
+  readonly repositories: number;
+  readonly teams: number;
+  /** Mean of the entries' scores, rounded; null with no entries. */
+  readonly averageScore: number | null;
+  /** Best score first. */
+  readonly entries: readonly LeaderboardEntry[];
+}
+
+export interface ScorePoint {
+  readonly timestamp: string;
+  readonly score: number;
+  readonly grade: ScorecardGrade;
+}
+
+/** One team's score over time in one repository. */
+export interface TeamTrend {
+  readonly namespace: string | null;
+  readonly owner: string;
+  readonly points: readonly ScorePoint[];
+}
+
+export interface CoveragePoint {
+  readonly timestamp: string;
+  readonly coverage: number;
+  readonly files: number;
+  readonly entities: number;
+}
+
+/** One repository's coverage over time, from its scan history. */
+export interface CoverageTrend {
+  readonly namespace: string | null;
+  readonly points: readonly CoveragePoint[];
+}
+
+export interface TrendFilter {
+  /** Only this owning team. */
+  readonly owner?: string;
+  /** Only points recorded at or after this ISO 8601 time. */
+  readonly since?: string;
+}
@@ -0,0 +1,195 @@
+import { describe, it, expect } from 'vitest';
+import { generateEntityId } from '../../indexer/database.js';
+import type { StoredEntity } from '../../indexer/types.js';
+import { simulateMergeRequest } from '../merge-request.js';
+import { applyFilePatch, parseUnifiedDiff } from '../unified-diff.js';
+
+const BASE: Readonly<Record<string, string>> = {
+  'src/orders.ts': [
+    '/**',
+    ' * @knowgraph',
+    ' * type: service',
+    ' * description: Takes orders',
+    ' * owner: shop-team',
+    ' * dependencies:',
+    ' *   services: [payments]',
+    ' */',
+    'export function orders() {}',
+    '',
+  ].join('\n'),
+  'src/payments.ts': [
+    '/**',
+    ' * @knowgraph',
+    ' * type: service',
+    ' * description: Charges cards',
+    ' * owner: payments-team',
+    ' */',
+    'export function payments() {}',
+    '',
+  ].join('\n'),
+  'src/legacy.ts': [
+    '/**',
+    ' * @knowgraph',
+    ' * type: service',
+    ' * description: Old billing',
+    ' * owner: payments-team',
+    ' * status: deprecated',
+    ' */',
+    'export function legacy() {}',
+    '',
+  ].join('\n'),
+};
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  line: number,
+  extra: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: generateEntityId(filePath, name, line),
+    filePath,
+    name,
+    entityType: 'service',
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line,
+    column: 0,
+    owner: 'payments-team',
+    status: null,
+    metadata: { type: 'service', description: `${name} description` },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...extra,
+  };
+}
+
+// As indexed from BASE
+const ENTITIES = [
+  makeEntity('orders', 'src/orders.ts', 9, {
+    owner: 'shop-team',
+    metadata: {
+      type: 'service',
+      description: 'Takes orders',
+      owner: 'shop-team',
+      dependencies: { services: ['payments'] },
+    },
+  }),
+  makeEntity('payments', 'src/payments.ts', 7),
+  makeEntity('legacy', 'src/legacy.ts', 8, { status: 'deprecated' }),
+];
+
+const readFile = (path: string): string | undefined =>
+  Object.hasOwn(BASE, path) ? BASE[path] : undefined;
+
+describe('parseUnifiedDiff and applyFilePatch', () => {
+  it('reads git diffs with new files, renames, and missing newlines', () => {
+    const patches = parseUnifiedDiff(
+      [
+        'diff --git a/a.txt b/a.txt',
+        'index 1111111..2222222 100644',
+        '--- a/a.txt',
+        '+++ b/a.txt',
+        '@@ -1,3 +1,3 @@',
+        ' one',
+        '-two',
+        '+TWO',
+        ' three',
+        '\\ No newline at end of file',
+        'diff --git a/new.txt b/new.txt',
+        'new file mode 100644',
+        '--- /dev/null',
+        '+++ b/new.txt',
+        '@@ -0,0 +1 @@',
+        '+fresh',
+        'diff --git a/old.txt b/moved.txt',
+        'similarity index 100%',
+        'rename from old.txt',
+        'rename to moved.txt',
+      ].join('\n'),
+    );
+    const files = patches.map((patch) => [patch.oldPath, patch.newPath]);
+    expect(files).toEqual([
+      ['a.txt', 'a.txt'],
+      [null, 'new.txt'],
+      ['old.txt', 'moved.txt'],
+    ]);
+    expect(patches[2].hunks).toEqual([]);
+    expect(applyFilePatch('one\ntwo\nthree', patches[0])).toBe(
+      'one\nTWO\nthree',
+    );
+    expect(applyFilePatch('', patches[1])).toBe('fresh\n');
+    expect(applyFilePatch('zero\none\ntwo\nthree', patches[0])).toBe(
+      'zero\none\nTWO\nthree',
+    );
+    expect(() => applyFilePatch('one\n2\nthree', patches[0])).toThrow(
+      'Hunk 1 of a.txt does not apply',
+    );
+    expect(() => parseUnifiedDiff('not a diff')).toThrow(
+      'The diff changes no files',
+    );
+  });
+});
+
+describe('simulateMergeRequest', () => {
+  it('reports the graph changes and newly failing policies of a diff', () => {
+    const diff = [
+      '--- a/src/orders.ts',
+      '+++ b/src/orders.ts',
+      '@@ -4,6 +4,6 @@',
+      '  * description: Takes orders',
+      '  * owner: shop-team',
+      '  * dependencies:',
+      '- *   services: [payments]',
+      '+ *   services: [payments, legacy]',
+      '  */',
+      ' export function orders() {}',
+      '--- /dev/null',
+      '+++ b/src/broken.ts',
+      '@@ -0,0 +1,6 @@',
+      '+/**',
+      '+ * @knowgraph',
+      '+ * type: service',
+      '+ * owner: 42',
+      '+ */',
+      '+export function broken() {}',
+    ].join('\n');
+    const result = simulateMergeRequest(ENTITIES, diff, { readFile });
+
+    expect(result.files).toEqual([
+      { path: 'src/orders.ts', status: 'modified', entities: 1 },
+      { path: 'src/broken.ts', status: 'added', entities: 0 },
+    ]);
+    expect(result.changes.edges.upsert).toEqual([
+      expect.objectContaining({
+        from: ENTITIES[0].id,
+        to: ENTITIES[2].id,
+        kind: 'service',
+      }),
+    ]);
+    expect(result.changes.nodes.remove).toEqual([]);
+    expect(result.violations[0]).toEqual({
+      policy: 'deprecated-dependency',
+      message: 'legacy is deprecated; 1 dependent(s) would newly depend on it',
+      nodes: [ENTITIES[0].id],
+    });
+    expect(result.violations.slice(1)).toContainEqual({
+      policy: 'invalid-annotation',
+      message: 'src/broken.ts:1: Validation error at owner: Expected string, received number',
+      nodes: [],
+    });
+  });
+
+  it('needs the base of every file the diff changes', () => {
+    const diff = '--- a/src/gone.ts\n+++ b/src/gone.ts\n@@ -1 +1 @@\n-a\n+b\n';
+    expect(() => simulateMergeRequest(ENTITIES, diff, { readFile })).toThrow(
+      'No content for src/gone.ts at the base of the merge request',
+    );
+  });
+});
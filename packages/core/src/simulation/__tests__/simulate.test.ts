@@ -0,0 +1,134 @@
+import { describe, it, expect } from 'vitest';
+import { simulateDependencyChange } from '../simulate.js';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  GraphEdge,
+  GraphNode,
+} from '../../graph/types.js';
+
+function node(
+  id: string,
+  owner: string | null,
+  domain: string | null = null,
+): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: owner ? 'service' : null,
+    external: owner === null,
+    filePath: owner ? `${id}.ts` : null,
+    owner,
+    domain,
+    workspace: null,
+  };
+}
+
+function edge(
+  from: string,
+  to: string,
+  kind: DependencyKind = 'service',
+): GraphEdge {
+  return { from, to, kind, provenance: 'declared', confidence: 1 };
+}
+
+// login and sessions use redis; web reaches it through login; cache-admin
+// manages redis and is depended on by login
+const graph: DependencyGraph = {
+  nodes: [
+    node('redis', null),
+    node('login', 'auth-team', 'auth'),
+    node('sessions', 'auth-team', 'auth'),
+    node('web', 'web-team', 'web'),
+    node('memcache', 'platform-team', 'auth'),
+    node('legacy-kv', 'platform-team', 'auth'),
+  ],
+  edges: [
+    edge('login', 'redis', 'database'),
+    edge('sessions', 'redis', 'database'),
+    edge('web', 'login'),
+    edge('memcache', 'sessions'),
+  ],
+};
+
+describe('simulateDependencyChange', () => {
+  it('lists direct dependents to migrate and indirect ones by team', () => {
+    const result = simulateDependencyChange(graph, { remove: 'redis' });
+    expect(result.affected).toBe(4);
+    expect(
+      result.teams.map((team) => ({
+        owner: team.owner,
+        tasks: team.tasks.map((task) => task.node.id),
+        indirect: team.indirect.map((n) => n.id),
+      })),
+    ).toEqual([
+      { owner: 'auth-team', tasks: ['login', 'sessions'], indirect: [] },
+      { owner: 'platform-team', tasks: [], indirect: ['memcache'] },
+      { owner: 'web-team', tasks: [], indirect: ['web'] },
+    ]);
+    expect(result.replacement).toBeNull();
+    expect(result.graph.nodes.some((n) => n.id === 'redis')).toBe(false);
+    expect(result.graph.edges.some((e) => e.to === 'redis')).toBe(false);
+  });
+
+  it('points dependents at a replacement, new or existing', () => {
+    const added = simulateDependencyChange(graph, {
+      remove: 'redis',
+      replaceWith: 'valkey',
+    });
+    expect(added.replacement?.id).toBe('external:database:valkey');
+    expect(
+      added.graph.edges.filter((e) => e.to === 'external:database:valkey'),
+    ).toHaveLength(2);
+
+    const existing = simulateDependencyChange(graph, {
+      remove: 'redis',
+      replaceWith: 'memcache',
+    });
+    expect(existing.replacement?.id).toBe('memcache');
+  });
+
+  it('reports cycles the replacement creates, over budget', () => {
+    const result = simulateDependencyChange(
+      graph,
+      { remove: 'redis', replaceWith: 'memcache' },
+      { cycles: { defaultBudget: 0 } },
+    );
+    expect(result.violations).toHaveLength(1);
+    expect(result.violations[0]).toMatchObject({
+      policy: 'dependency-cycle',
+      nodes: ['memcache', 'sessions'],
+    });
+    expect(
+      simulateDependencyChange(
+        graph,
+        { remove: 'redis', replaceWith: 'memcache' },
+        { cycles: { budgets: { auth: 1 } } },
+      ).violations,
+    ).toEqual([]);
+  });
+
+  it('reports newly depending on a deprecated replacement', () => {
+    const result = simulateDependencyChange(
+      graph,
+      { remove: 'redis', replaceWith: 'legacy-kv' },
+      { deprecated: ['legacy-kv'] },
+    );
+    expect(result.violations).toEqual([
+      {
+        policy: 'deprecated-dependency',
+        message: 'legacy-kv is deprecated; 2 dependent(s) would newly depend on it',
+        nodes: ['login', 'sessions'],
+      },
+    ]);
+  });
+
+  it('rejects unknown nodes and replacing a node with itself', () => {
+    expect(() => simulateDependencyChange(graph, { remove: 'kafka' })).toThrow(
+      'No node kafka in the graph',
+    );
+    expect(() =>
+      simulateDependencyChange(graph, { remove: 'redis', replaceWith: 'redis' }),
+    ).toThrow('cannot replace itself');
+  });
+});
@@ -0,0 +1,18 @@
+export type {
+  DependencyChange,
+  DiffHunk,
+  FilePatch,
+  MergeFileChange,
+  MergeFileStatus,
+  MergeSimulationOptions,
+  MergeSimulationResult,
+  MigrationTask,
+  PolicyViolation,
+  SimulationOptions,
+  SimulationPolicy,
+  SimulationResult,
+  TeamMigration,
+} from './types.js';
+export { simulateDependencyChange } from './simulate.js';
+export { simulateMergeRequest } from './merge-request.js';
+export { applyFilePatch, parseUnifiedDiff } from './unified-diff.js';
@@ -0,0 +1,214 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Simulates merging a diff into an index, extracting annotations from the patched files in memory to report the graph changes and newly failing policies
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [simulation, merge-request, diff, policy, bots]
+ * context:
+ *   business_goal: Let platform bots review what a merge request does to the graph before it lands
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { buildDependencyGraph } from '../graph/graph-builder.js';
+import type { DependencyGraph } from '../graph/types.js';
+import { resolveLocalizedText } from '../i18n/localized-text.js';
+import { generateEntityId } from '../indexer/database.js';
+import { indexedFileHash } from '../indexer/indexer.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { namespacedId } from '../namespace/namespace.js';
+import { createDefaultRegistry } from '../parsers/registry.js';
+import { createGraphPatch } from '../patch/graph-patch.js';
+import type { ParseDiagnostic } from '../types/parse-result.js';
+import { cycleViolations } from './simulate.js';
+import type {
+  FilePatch,
+  MergeFileChange,
+  MergeSimulationOptions,
+  MergeSimulationResult,
+  PolicyViolation,
+} from './types.js';
+import { applyFilePatch, parseUnifiedDiff } from './unified-diff.js';
+
+interface Extracted {
+  readonly entities: readonly StoredEntity[];
+  readonly diagnostics: readonly ParseDiagnostic[];
+}
+
+const NOTHING: Extracted = { entities: [], diagnostics: [] };
+
+/**
+ * The entities the annotations in `content` declare, as indexing `filePath`
+ * would store them, keeping the timestamps of any already indexed.
+ */
+function extractFile(
+  registry: ReturnType<typeof createDefaultRegistry>,
+  filePath: string,
+  content: string,
+  indexed: ReadonlyMap<string, StoredEntity>,
+): Extracted {
+  const output = registry.parseFile(content, filePath);
+  const fileHash = indexedFileHash(content, undefined);
+  const entities = output.results.map((result): StoredEntity => {
+    const id = generateEntityId(filePath, result.name, result.line);
+    const { metadata } = result;
+    return {
+      id,
+      filePath,
+      name: result.name,
+      entityType: result.entityType,
+      description: resolveLocalizedText(metadata.description),
+      rawDocstring: result.rawDocstring,
+      signature: result.signature ?? null,
+      parent: result.parent ?? null,
+      language: result.language,
+      line: result.line,
+      column: result.column,
+      owner: metadata.owner ?? null,
+      status: metadata.status ?? null,
+      metadata,
+      tags: metadata.tags ?? [],
+      links: metadata.links ?? [],
+      fileHash,
+      createdAt: indexed.get(id)?.createdAt ?? '',
+      updatedAt: indexed.get(id)?.updatedAt ?? '',
+    };
+  });
+  return { entities, diagnostics: output.diagnostics };
+}
+
+function fileChange(patch: FilePatch, entities: number): MergeFileChange {
+  const { oldPath, newPath } = patch;
+  if (newPath === null) {
+    return { path: oldPath ?? '', status: 'deleted', entities };
+  }
+  if (oldPath === null) return { path: newPath, status: 'added', entities };
+  return oldPath === newPath
+    ? { path: newPath, status: 'modified', entities }
+    : { path: newPath, status: 'renamed', previousPath: oldPath, entities };
+}
+
+/**
+ * Dependents newly depending on a node in `deprecated`, one violation per
+ * deprecated node.
+ */
+function deprecatedViolations(
+  before: DependencyGraph,
+  after: DependencyGraph,
+  deprecated: ReadonlySet<string>,
+): readonly PolicyViolation[] {
+  const had = new Set(before.edges.map((edge) => `${edge.from}\0${edge.to}`));
+  const newly = new Map<string, Set<string>>();
+  for (const edge of after.edges) {
+    if (!deprecated.has(edge.to) || edge.from === edge.to) continue;
+    if (had.has(`${edge.from}\0${edge.to}`)) continue;
+    const dependents = newly.get(edge.to) ?? new Set<string>();
+    dependents.add(edge.from);
+    newly.set(edge.to, dependents);
+  }
+  const names = new Map(after.nodes.map((node) => [node.id, node.name]));
+  return [...newly]
+    .sort(([a], [b]) => compareStrings(a, b))
+    .map(([id, dependents]) => ({
+      policy: 'deprecated-dependency' as const,
+      message: `${names.get(id) ?? id} is deprecated; ${dependents.size} dependent(s) would newly depend on it`,
+      nodes: [...dependents].sort(compareStrings),
+    }));
+}
+
+/**
+ * Simulate merging the unified `diff` into the index holding `entities`,
+ * without writing anything. Each file the diff touches is read at the
+ * base with `options.readFile`, patched in memory, and extracted before
+ * and after, so the result holds only what the diff changes: the graph
+ * patch from the graph before to the graph after, and the policies the
+ * graph would newly fail. Those are cycles over `options.cycles`, new
+ * dependents of deprecated nodes (`options.deprecated`, by default the
+ * entities marked deprecated after the merge), and annotations in the
+ * patched files that fail validation where they did not before.
+ *
+ * Annotation defaults and sidecar files are not applied to the patched
+ * files. Throws a `parse` error on a malformed diff and a `usage` error
+ * when a file it changes has no base content or a hunk does not apply.
+ */
+export function simulateMergeRequest(
+  entities: readonly StoredEntity[],
+  diff: string,
+  options: MergeSimulationOptions,
+): MergeSimulationResult {
+  const registry = createDefaultRegistry();
+  const indexed = new Map(entities.map((entity) => [entity.id, entity]));
+  const touched = new Set<string>();
+  const before: StoredEntity[] = [];
+  const after: StoredEntity[] = [];
+  const files: MergeFileChange[] = [];
+  const violations: PolicyViolation[] = [];
+
+  for (const patch of parseUnifiedDiff(diff)) {
+    const base =
+      patch.oldPath === null ? '' : options.readFile(patch.oldPath);
+    if (base === undefined) {
+      throw createKnowgraphError(
+        'usage',
+        `No content for ${patch.oldPath} at the base of the merge request`,
+      );
+    }
+    const old =
+      patch.oldPath === null
+        ? NOTHING
+        : extractFile(registry, patch.oldPath, base, indexed);
+    const patched =
+      patch.newPath === null
+        ? NOTHING
+        : extractFile(
+            registry,
+            patch.newPath,
+            applyFilePatch(base, patch),
+            indexed,
+          );
+    for (const path of [patch.oldPath, patch.newPath]) {
+      if (path !== null) touched.add(path);
+    }
+    before.push(...old.entities);
+    after.push(...patched.entities);
+    files.push(fileChange(patch, patched.entities.length));
+
+    const known = new Set(old.diagnostics.map((found) => found.message));
+    for (const found of patched.diagnostics) {
+      if (known.has(found.message)) continue;
+      violations.push({
+        policy: 'invalid-annotation',
+        message: `${found.filePath}:${found.line}: ${found.message}`,
+        nodes: [],
+      });
+    }
+  }
+
+  const unchanged = entities.filter((entity) => !touched.has(entity.filePath));
+  const graphBefore = buildDependencyGraph(
+    [...unchanged, ...before],
+    options.graph,
+  );
+  const merged = [...unchanged, ...after];
+  const graphAfter = buildDependencyGraph(merged, options.graph);
+
+  const namespace = options.graph?.namespace;
+  const deprecated = new Set(
+    options.deprecated ??
+      merged
+        .filter((entity) => entity.status === 'deprecated')
+        .map((entity) =>
+          namespace ? namespacedId(namespace, entity.id) : entity.id,
+        ),
+  );
+  return {
+    files,
+    changes: createGraphPatch(graphBefore, graphAfter),
+    violations: [
+      ...cycleViolations(graphBefore, graphAfter, options),
+      ...deprecatedViolations(graphBefore, graphAfter, deprecated),
+      ...violations,
+    ],
+  };
+}
@@ -0,0 +1,213 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Simulates removing or replacing a dependency, listing who has to migrate by team and which policies would newly fail
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [simulation, what-if, migration, graph, policy]
+ * context:
+ *   business_goal: Size a dependency migration before anyone starts it
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import { externalNode } from '../graph/graph-builder.js';
+import {
+  checkCycleBudgets,
+  cycleMemberKey,
+  findDependencyCycles,
+} from '../graph/graph-cycles.js';
+import { traverseGraph } from '../graph/graph-traversal.js';
+import type { DependencyGraph, GraphEdge, GraphNode } from '../graph/types.js';
+import type {
+  DependencyChange,
+  MigrationTask,
+  PolicyViolation,
+  SimulationOptions,
+  SimulationResult,
+  TeamMigration,
+} from './types.js';
+
+function compareOwners(a: string | null, b: string | null): number {
+  if (a === b) return 0;
+  if (a === null) return 1;
+  if (b === null) return -1;
+  return compareStrings(a, b);
+}
+
+function compareNodes(a: GraphNode, b: GraphNode): number {
+  return compareStrings(a.name, b.name) || compareStrings(a.id, b.id);
+}
+
+/**
+ * The graph after `change`: the removed node and its own dependencies are
+ * gone, and the edges into it point at `replacement` or are dropped.
+ */
+function applyChange(
+  graph: DependencyGraph,
+  removedId: string,
+  replacement: GraphNode | null,
+): DependencyGraph {
+  const edges: GraphEdge[] = [];
+  const seen = new Set<string>();
+  for (const edge of graph.edges) {
+    if (edge.from === removedId) continue;
+    let next = edge;
+    if (edge.to === removedId) {
+      if (!replacement || edge.from === replacement.id) continue;
+      next = { ...edge, to: replacement.id };
+    }
+    const key = `${next.from}\0${next.to}\0${next.kind}`;
+    if (seen.has(key)) continue;
+    seen.add(key);
+    edges.push(next);
+  }
+  const nodes = graph.nodes.filter((node) => node.id !== removedId);
+  if (replacement && !nodes.some((node) => node.id === replacement.id)) {
+    nodes.push(replacement);
+  }
+  return { nodes, edges };
+}
+
+function groupByTeam(
+  tasks: readonly MigrationTask[],
+  indirect: readonly GraphNode[],
+): readonly TeamMigration[] {
+  const teams = new Map<
+    string | null,
+    { tasks: MigrationTask[]; indirect: GraphNode[] }
+  >();
+  const team = (owner: string | null) => {
+    let entry = teams.get(owner);
+    if (!entry) {
+      entry = { tasks: [], indirect: [] };
+      teams.set(owner, entry);
+    }
+    return entry;
+  };
+  for (const task of tasks) team(task.node.owner).tasks.push(task);
+  for (const node of indirect) team(node.owner).indirect.push(node);
+  return [...teams.entries()]
+    .sort(([a], [b]) => compareOwners(a, b))
+    .map(([owner, entry]) => ({
+      owner,
+      tasks: entry.tasks.sort((a, b) => compareNodes(a.node, b.node)),
+      indirect: entry.indirect.sort(compareNodes),
+    }));
+}
+
+/**
+ * Cycles `after` has over the budgets of `options.cycles` that `before`
+ * did not have; none without budgets.
+ */
+export function cycleViolations(
+  before: DependencyGraph,
+  after: DependencyGraph,
+  options: SimulationOptions,
+): readonly PolicyViolation[] {
+  if (!options.cycles) return [];
+  const existing = findDependencyCycles(before).map((cycle) =>
+    cycle.members.map(cycleMemberKey),
+  );
+  const report = checkCycleBudgets(findDependencyCycles(after), {
+    ...options.cycles,
+    baseline: [...(options.cycles.baseline ?? []), ...existing],
+  });
+  return report.cycles
+    .filter((status) => status.overBudget)
+    .map(({ cycle, domains }) => ({
+      policy: 'dependency-cycle' as const,
+      message: `Creates a dependency cycle among ${cycle.members.map((m) => m.name).join(', ')}, over the budget of ${domains.join(', ')}`,
+      nodes: cycle.members.map((member) => member.id),
+      cycle,
+    }));
+}
+
+/**
+ * Simulate `change` on `graph`: who depends on the removed node, grouped
+ * by owner into a migration checklist, and the policies the changed graph
+ * would newly fail. Cycles count only against `options.cycles`, and
+ * depending on a node in `options.deprecated` only when nothing did
+ * before. A `replaceWith` that is not a node id becomes a new external
+ * dependency of the kind the removed node was depended on as. Throws a
+ * `usage` error when `change.remove` is not in the graph or replaces
+ * itself.
+ */
+export function simulateDependencyChange(
+  graph: DependencyGraph,
+  change: DependencyChange,
+  options: SimulationOptions = {},
+): SimulationResult {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const removed = nodes.get(change.remove);
+  if (!removed) {
+    throw createKnowgraphError(
+      'usage',
+      `No node ${change.remove} in the graph`,
+    );
+  }
+  if (change.replaceWith === removed.id) {
+    throw createKnowgraphError(
+      'usage',
+      `${removed.name} cannot replace itself`,
+    );
+  }
+  const incoming = graph.edges.filter(
+    (edge) => edge.to === removed.id && edge.from !== removed.id,
+  );
+  const replacement =
+    change.replaceWith === undefined
+      ? null
+      : (nodes.get(change.replaceWith) ??
+        externalNode(incoming[0]?.kind ?? 'service', change.replaceWith));
+
+  // One task per dependent, by its most confident edge
+  const direct = new Map<string, GraphEdge>();
+  for (const edge of incoming) {
+    const current = direct.get(edge.from);
+    if (!current || edge.confidence > current.confidence) {
+      direct.set(edge.from, edge);
+    }
+  }
+  const tasks = [...direct.values()].flatMap((edge): MigrationTask[] => {
+    const node = nodes.get(edge.from);
+    return node
+      ? [{ node, kind: edge.kind, provenance: edge.provenance }]
+      : [];
+  });
+  const reached = [
+    ...traverseGraph(graph, removed.id, { direction: 'incoming' }),
+  ];
+  const indirect = reached
+    .filter((step) => !direct.has(step.node.id))
+    .map((step) => step.node);
+
+  const after = applyChange(graph, removed.id, replacement);
+  const violations = [...cycleViolations(graph, after, options)];
+  if (replacement && options.deprecated?.includes(replacement.id)) {
+    const dependsAlready = new Set(
+      graph.edges
+        .filter((edge) => edge.to === replacement.id)
+        .map((edge) => edge.from),
+    );
+    const newly = tasks
+      .map((task) => task.node.id)
+      .filter((id) => !dependsAlready.has(id) && id !== replacement.id);
+    if (newly.length > 0) {
+      violations.push({
+        policy: 'deprecated-dependency',
+        message: `${replacement.name} is deprecated; ${newly.length} dependent(s) would newly depend on it`,
+        nodes: newly,
+      });
+    }
+  }
+
+  return {
+    removed,
+    replacement,
+    teams: groupByTeam(tasks, indirect),
+    affected: reached.length,
+    violations,
+    graph: after,
+  };
+}
@@ -0,0 +1,140 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for what-if simulations of removing or replacing a dependency
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [simulation, what-if, migration, graph, types, interface]
+ * context:
+ *   business_goal: Let migration plans be built from simulation results without rerunning them
+ *   domain: graph
+ */
+import type {
+  CycleBudgetOptions,
+  DependencyCycle,
+  DependencyGraph,
+  DependencyGraphOptions,
+  DependencyKind,
+  EdgeProvenance,
+  GraphNode,
+} from '../graph/types.js';
+import type { GraphPatch } from '../patch/types.js';
+
+/** The dependency to remove, and what, if anything, replaces it. */
+export interface DependencyChange {
+  /** Node id of the dependency to remove. */
+  readonly remove: string;
+  /**
+   * Node id of its replacement, or the name of a new external dependency
+   * of the same kind. Without one, the dependency is removed outright.
+   */
+  readonly replaceWith?: string;
+}
+
+export interface SimulationOptions {
+  /**
+   * The cycle budgets `knowgraph check` enforces. Without them, cycles
+   * the change creates are not reported.
+   */
+  readonly cycles?: CycleBudgetOptions;
+  /** Ids of deprecated nodes, which nothing should newly depend on. */
+  readonly deprecated?: readonly string[];
+}
+
+/** A dependency on the removed node that has to change. */
+export interface MigrationTask {
+  readonly node: GraphNode;
+  readonly kind: DependencyKind;
+  readonly provenance: EdgeProvenance;
+}
+
+/** What one owner has to change, and what of theirs is affected anyway. */
+export interface TeamMigration {
+  /** `null` for nodes without an owner. */
+  readonly owner: string | null;
+  /** Direct dependents to migrate. */
+  readonly tasks: readonly MigrationTask[];
+  /** Nodes that reach the removed node only through others. */
+  readonly indirect: readonly GraphNode[];
+}
+
+export type SimulationPolicy =
+  | 'dependency-cycle'
+  | 'deprecated-dependency'
+  | 'invalid-annotation';
+
+/** A policy the graph passes now and would fail after the change. */
+export interface PolicyViolation {
+  readonly policy: SimulationPolicy;
+  readonly message: string;
+  /** Ids of the nodes involved. */
+  readonly nodes: readonly string[];
+  /** For `dependency-cycle`, the cycle the change creates. */
+  readonly cycle?: DependencyCycle;
+}
+
+export interface SimulationResult {
+  readonly removed: GraphNode;
+  /** `null` when the dependency is removed outright. */
+  readonly replacement: GraphNode | null;
+  /** Owners in name order, unowned last. */
+  readonly teams: readonly TeamMigration[];
+  /** Every node that reaches the removed node, directly or not. */
+  readonly affected: number;
+  readonly violations: readonly PolicyViolation[];
+  /** The graph as it would be after the change. */
+  readonly graph: DependencyGraph;
+}
+
+/** One hunk of a unified diff. */
+export interface DiffHunk {
+  readonly oldStart: number;
+  readonly oldLines: number;
+  readonly newStart: number;
+  readonly newLines: number;
+  /**
+   * The hunk's lines, each starting with ` `, `-`, or `+`, or a `\` line
+   * saying the line before it ends the file without a newline.
+   */
+  readonly lines: readonly string[];
+}
+
+/** The changes a unified diff makes to one file. */
+export interface FilePatch {
+  /** Null for a file the diff adds. */
+  readonly oldPath: string | null;
+  /** Null for a file the diff deletes. */
+  readonly newPath: string | null;
+  readonly hunks: readonly DiffHunk[];
+}
+
+export interface MergeSimulationOptions extends SimulationOptions {
+  /**
+   * The content of a file at the merge request's base, by its path from
+   * the repository root; undefined when there is none.
+   */
+  readonly readFile: (path: string) => string | undefined;
+  /** How both graphs are built, such as under the index's `namespace`. */
+  readonly graph?: DependencyGraphOptions;
+}
+
+export type MergeFileStatus = 'added' | 'modified' | 'deleted' | 'renamed';
+
+/** A file the merge request changes. */
+export interface MergeFileChange {
+  /** Its path after the merge, or before it for a deleted file. */
+  readonly path: string;
+  readonly status: MergeFileStatus;
+  /** For a renamed file, its path before the merge. */
+  readonly previousPath?: string;
+  /** How many entities its annotations declare after the merge. */
+  readonly entities: number;
+}
+
+export interface MergeSimulationResult {
+  readonly files: readonly MergeFileChange[];
+  /** What merging would change in the graph, as a graph patch. */
+  readonly changes: GraphPatch;
+  /** Policies the graph passes now and would fail after the merge. */
+  readonly violations: readonly PolicyViolation[];
+}
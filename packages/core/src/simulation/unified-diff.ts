@@ -0,0 +1,186 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses unified diffs, as git and diff -u write them, and applies each file's hunks to its content in memory
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [simulation, diff, patch, merge-request]
+ * context:
+ *   business_goal: Let bots evaluate a merge request from its diff alone, without a checkout
+ *   domain: graph
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type { DiffHunk, FilePatch } from './types.js';
+
+const HUNK_HEADER = /^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@/;
+
+/** `path` without the `a/` or `b/` git puts before it; null for /dev/null. */
+function diffPath(raw: string, prefix: string): string | null {
+  // Paths end at a tab, after which diff -u writes a timestamp
+  const path = raw.split('\t')[0].trim();
+  if (path === '/dev/null') return null;
+  return path.startsWith(prefix) ? path.slice(prefix.length) : path;
+}
+
+function parseError(line: number, message: string): Error {
+  return createKnowgraphError(
+    'parse',
+    `Line ${line} of the diff: ${message}`,
+    { details: { line } },
+  );
+}
+
+/**
+ * The files a unified diff changes, in diff order. Git's extended headers
+ * are read for renames, and files without hunks, such as binary ones, are
+ * kept only when renamed. Throws a `parse` error on a malformed hunk or a
+ * diff that changes no file.
+ */
+export function parseUnifiedDiff(text: string): readonly FilePatch[] {
+  const lines = text.replace(/\r\n/g, '\n').split('\n');
+  const patches: FilePatch[] = [];
+  let renamed: { from?: string; to?: string } = {};
+  let i = 0;
+
+  const flushRename = (): void => {
+    if (renamed.from !== undefined && renamed.to !== undefined) {
+      patches.push({ oldPath: renamed.from, newPath: renamed.to, hunks: [] });
+    }
+    renamed = {};
+  };
+
+  while (i < lines.length) {
+    const line = lines[i];
+    if (line.startsWith('diff --git ')) {
+      flushRename();
+      i++;
+    } else if (line.startsWith('rename from ')) {
+      renamed.from = line.slice('rename from '.length);
+      i++;
+    } else if (line.startsWith('rename to ')) {
+      renamed.to = line.slice('rename to '.length);
+      i++;
+    } else if (line.startsWith('--- ') && lines[i + 1]?.startsWith('+++ ')) {
+      renamed = {};
+      const oldPath = diffPath(line.slice(4), 'a/');
+      const newPath = diffPath(lines[i + 1].slice(4), 'b/');
+      i += 2;
+      const hunks: DiffHunk[] = [];
+      while (lines[i]?.startsWith('@@')) {
+        const header = HUNK_HEADER.exec(lines[i]);
+        if (!header) throw parseError(i + 1, 'malformed hunk header');
+        const hunk = {
+          oldStart: Number(header[1]),
+          oldLines: header[2] === undefined ? 1 : Number(header[2]),
+          newStart: Number(header[3]),
+          newLines: header[4] === undefined ? 1 : Number(header[4]),
+        };
+        i++;
+        const body: string[] = [];
+        let removed = 0;
+        let added = 0;
+        while (removed < hunk.oldLines || added < hunk.newLines) {
+          if (i >= lines.length) {
+            throw parseError(i, 'hunk ends before its last line');
+          }
+          // Some tools strip the space of empty context lines
+          const next = lines[i] === '' ? ' ' : lines[i];
+          const kind = next[0];
+          if (kind === ' ' || kind === '-') removed++;
+          if (kind === ' ' || kind === '+') added++;
+          if (!' -+\\'.includes(kind)) {
+            throw parseError(
+              i + 1,
+              'expected a context, removed or added line',
+            );
+          }
+          body.push(next);
+          i++;
+        }
+        while (lines[i]?.startsWith('\\')) body.push(lines[i++]);
+        hunks.push({ ...hunk, lines: body });
+      }
+      patches.push({ oldPath, newPath, hunks });
+    } else {
+      i++;
+    }
+  }
+  flushRename();
+  if (patches.length === 0) {
+    throw createKnowgraphError('parse', 'The diff changes no files');
+  }
+  return patches;
+}
+
+/** Whether `lines` holds `expected` from `at` on. */
+function matchesAt(
+  lines: readonly string[],
+  expected: readonly string[],
+  at: number,
+): boolean {
+  return (
+    at >= 0 &&
+    at + expected.length <= lines.length &&
+    expected.every((line, offset) => lines[at + offset] === line)
+  );
+}
+
+/**
+ * `content` with the hunks of `patch` applied. A hunk whose lines have
+ * moved since the diff was taken is applied where they now are, nearest
+ * the line it names. Throws a `usage` error when a hunk's context or
+ * removed lines are nowhere in `content`.
+ */
+export function applyFilePatch(content: string, patch: FilePatch): string {
+  const path = patch.newPath ?? patch.oldPath ?? '';
+  const text = content.replace(/\r\n/g, '\n');
+  const lines = text === '' ? [] : text.split('\n');
+  let finalNewline = text === '' || text.endsWith('\n');
+  if (text.endsWith('\n')) lines.pop();
+
+  const result: string[] = [];
+  let position = 0;
+  patch.hunks.forEach((hunk, index) => {
+    const before: string[] = [];
+    const after: string[] = [];
+    // "\ No newline at end of file" follows the last line of either side
+    const missing = { old: false, new: false };
+    let last = '';
+    for (const line of hunk.lines) {
+      if (line.startsWith('\\')) {
+        if (last !== '+') missing.old = true;
+        if (last !== '-') missing.new = true;
+        continue;
+      }
+      last = line[0];
+      if (last !== '+') before.push(line.slice(1));
+      if (last !== '-') after.push(line.slice(1));
+    }
+    if (missing.new) finalNewline = false;
+    else if (missing.old) finalNewline = true;
+    const stated = Math.max(hunk.oldStart - (hunk.oldLines === 0 ? 0 : 1), 0);
+    let at = -1;
+    for (let offset = 0; at === -1; offset++) {
+      const earlier = stated - offset;
+      const later = stated + offset;
+      if (earlier < position && later > lines.length) break;
+      if (earlier >= position && matchesAt(lines, before, earlier)) {
+        at = earlier;
+      } else if (later >= position && matchesAt(lines, before, later)) {
+        at = later;
+      }
+    }
+    if (at === -1) {
+      throw createKnowgraphError(
+        'usage',
+        `Hunk ${index + 1} of ${path} does not apply: its lines are not in the file`,
+        { details: { file: path, hunk: index + 1 } },
+      );
+    }
+    result.push(...lines.slice(position, at), ...after);
+    position = at + before.length;
+  });
+  result.push(...lines.slice(position));
+  if (result.length === 0) return '';
+  return result.join('\n') + (finalNewline ? '\n' : '');
+}
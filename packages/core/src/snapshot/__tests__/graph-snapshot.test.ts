@@ -0,0 +1,151 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdirSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { stableStringify } from '../../canonical/canonical.js';
+import type { DependencyGraph, GraphNode } from '../../graph/types.js';
+import {
+  decodeGraphSnapshot,
+  encodeGraphSnapshot,
+  isGraphSnapshot,
+  readGraphSnapshot,
+  writeGraphSnapshot,
+} from '../graph-snapshot.js';
+
+function node(id: string, overrides: Partial<GraphNode> = {}): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `src/${id}.ts`,
+    owner: 'team-a',
+    domain: 'shop',
+    workspace: null,
+    ...overrides,
+  };
+}
+
+const GRAPH: DependencyGraph = {
+  nodes: [
+    node('checkout'),
+    node('external:database:orders-db', {
+      name: 'orders-db',
+      entityType: null,
+      external: true,
+      filePath: null,
+      owner: null,
+      domain: null,
+    }),
+    node('go:example.com/api', { entityType: null, annotated: false }),
+    node('go:example.com/lib', { entityType: null, annotated: true }),
+    node('ünïcode', { name: 'naïve 🚀', workspace: '//svc' }),
+    node('tokens', { aliases: ['token-service', 'TokenAPI'], version: '2.1.0' }),
+    node('ledger', { stub: true }),
+    node('acme/shop:billing', { name: 'billing', namespace: 'acme/shop' }),
+  ],
+  edges: [
+    {
+      from: 'checkout',
+      to: 'external:database:orders-db',
+      kind: 'database',
+      provenance: 'declared',
+      confidence: 1,
+    },
+    {
+      from: 'go:example.com/api',
+      to: 'go:example.com/lib',
+      kind: 'import',
+      provenance: 'import',
+      confidence: 1,
+    },
+    {
+      from: 'checkout',
+      to: 'tokens',
+      kind: 'service',
+      provenance: 'call',
+      confidence: 0.75,
+      requires: '>=2',
+      purpose: 'JWT signing',
+      protocol: 'grpc',
+      criticality: 'high',
+    },
+  ],
+};
+
+describe('graph snapshots', () => {
+  it('round-trips nodes and edges with and without compression', () => {
+    for (const compress of [true, false]) {
+      const bytes = encodeGraphSnapshot(GRAPH, { compress });
+      expect(isGraphSnapshot(bytes)).toBe(true);
+      expect(decodeGraphSnapshot(bytes)).toEqual(GRAPH);
+    }
+  });
+
+  it('encodes the same graph to the same bytes', () => {
+    expect(encodeGraphSnapshot(GRAPH).equals(encodeGraphSnapshot(GRAPH))).toBe(
+      true,
+    );
+  });
+
+  it('is much smaller than JSON for graphs with repeated values', () => {
+    const large: DependencyGraph = {
+      nodes: Array.from({ length: 2000 }, (_, i) =>
+        node(`svc-${i}`, { owner: `team-${i % 20}` }),
+      ),
+      edges: Array.from({ length: 2000 }, (_, i) => ({
+        from: `svc-${i}`,
+        to: `svc-${(i + 1) % 2000}`,
+        kind: 'service' as const,
+        provenance: 'declared' as const,
+        confidence: 1,
+      })),
+    };
+    const json = Buffer.byteLength(stableStringify(large, false));
+    expect(encodeGraphSnapshot(large).length).toBeLessThan(json / 10);
+    expect(decodeGraphSnapshot(encodeGraphSnapshot(large))).toEqual(large);
+  });
+
+  it('reads version 1 snapshots', () => {
+    const v1: DependencyGraph = { nodes: [node('checkout')], edges: [] };
+    const bytes = Buffer.from(encodeGraphSnapshot(v1, { compress: false }));
+    bytes[3] = 1;
+    expect(decodeGraphSnapshot(bytes)).toEqual(v1);
+  });
+
+  it('rejects foreign, unsupported, and truncated data', () => {
+    expect(() => decodeGraphSnapshot(Buffer.from('{"nodes":[]}'))).toThrow(
+      'Not a graph snapshot',
+    );
+    const bytes = encodeGraphSnapshot(GRAPH, { compress: false });
+    const future = Buffer.from(bytes);
+    future[3] = 99;
+    expect(() => decodeGraphSnapshot(future)).toThrow('version 99');
+    expect(() => decodeGraphSnapshot(bytes.subarray(0, 40))).toThrow(
+      'Truncated',
+    );
+  });
+
+  describe('files', () => {
+    let dir: string;
+
+    beforeEach(() => {
+      dir = join(
+        tmpdir(),
+        `knowgraph-snapshot-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+      );
+      mkdirSync(dir, { recursive: true });
+    });
+
+    afterEach(() => {
+      rmSync(dir, { recursive: true, force: true });
+    });
+
+    it('writes and reads a snapshot file', () => {
+      const path = join(dir, 'graph.kgs');
+      writeGraphSnapshot(path, GRAPH);
+      expect(existsSync(`${path}.tmp`)).toBe(false);
+      expect(readGraphSnapshot(path)).toEqual(GRAPH);
+    });
+  });
+});
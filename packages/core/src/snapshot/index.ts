@@ -0,0 +1,13 @@
+export type {
+  SnapshotFormat,
+  SnapshotEncodeOptions,
+  SnapshotFileOptions,
+} from './types.js';
+export {
+  SNAPSHOT_VERSION,
+  isGraphSnapshot,
+  encodeGraphSnapshot,
+  decodeGraphSnapshot,
+  writeGraphSnapshot,
+  readGraphSnapshot,
+} from './graph-snapshot.js';
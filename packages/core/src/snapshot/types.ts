@@ -0,0 +1,24 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the compact binary dependency graph snapshot format
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [snapshot, binary, serialization, types, interface]
+ * context:
+ *   business_goal: Keep snapshots written by older versions loadable by newer ones
+ *   domain: snapshot
+ */
+import type { EncryptionOptions } from '../encryption/types.js';
+
+/** On-disk encodings for stored graphs. */
+export type SnapshotFormat = 'json' | 'binary';
+
+export interface SnapshotEncodeOptions {
+  /** Deflate the body after encoding (default: true). */
+  readonly compress?: boolean;
+}
+
+export interface SnapshotFileOptions
+  extends SnapshotEncodeOptions,
+    EncryptionOptions {}
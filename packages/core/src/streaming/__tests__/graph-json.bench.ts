@@ -0,0 +1,79 @@
+import { afterAll, bench, describe } from 'vitest';
+import { stableStringify } from '../../canonical/canonical.js';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import { createDatabaseManager } from '../../indexer/database.js';
+import { createQueryEngine } from '../../query/query-engine.js';
+import { writeGraphJson } from '../graph-json.js';
+
+// Run with `pnpm --filter @know-graph/core bench`. Each case also records
+// how far the heap grew while exporting, printed once the suite finishes.
+const ENTITY_COUNT = 20_000;
+
+const dbManager = createDatabaseManager();
+dbManager.initialize();
+const query = createQueryEngine(dbManager);
+
+dbManager.db.transaction(() => {
+  for (let i = 0; i < ENTITY_COUNT; i++) {
+    dbManager.insertEntity({
+      filePath: `services/svc-${i % 500}/handler-${i}.ts`,
+      name: `svc-${i}`,
+      entityType: 'service',
+      description: `Service ${i} handling part of the request pipeline`,
+      language: 'typescript',
+      line: 1,
+      column: 0,
+      owner: `team-${i % 40}`,
+      metadata: {
+        type: 'service',
+        description: `Service ${i} handling part of the request pipeline`,
+        dependencies: {
+          services: [`svc-${(i + 1) % ENTITY_COUNT}`, `svc-${(i * 7) % 997}`],
+          databases: [`db-${i % 25}`],
+        },
+      },
+    });
+  }
+})();
+
+const heapGrowth = new Map<string, number>();
+
+function trackHeap(name: string, baseline: number): () => void {
+  return () => {
+    const growth = process.memoryUsage().heapUsed - baseline;
+    heapGrowth.set(name, Math.max(heapGrowth.get(name) ?? 0, growth));
+  };
+}
+
+describe(`graph JSON export (${ENTITY_COUNT} entities)`, () => {
+  afterAll(() => {
+    for (const [name, bytes] of heapGrowth) {
+      console.log(`${name}: peak heap growth ${(bytes / 1e6).toFixed(1)} MB`);
+    }
+    dbManager.close();
+  });
+
+  bench('materialized: getAll + buildDependencyGraph + stableStringify', () => {
+    const sample = trackHeap('materialized', process.memoryUsage().heapUsed);
+    const json = stableStringify(buildDependencyGraph(query.getAll()), false);
+    sample();
+    if (json.length === 0) throw new Error('empty export');
+  });
+
+  bench('streamed: iterateAll + writeGraphJson', () => {
+    const sample = trackHeap('streamed', process.memoryUsage().heapUsed);
+    let written = 0;
+    writeGraphJson(
+      () => query.iterateAll(),
+      {
+        write: (chunk) => {
+          written += chunk.length;
+          // Sampling on every chunk is too slow; every ~1 MB is enough
+          if (written % 1_000_000 < chunk.length) sample();
+        },
+      },
+    );
+    sample();
+    if (written === 0) throw new Error('empty export');
+  });
+});
@@ -0,0 +1,247 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { stableStringify } from '../../canonical/canonical.js';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import { filterGraphEdges } from '../../graph/graph-provenance.js';
+import { createDatabaseManager } from '../../indexer/database.js';
+import type { DatabaseManager } from '../../indexer/database.js';
+import type { EntityInsert } from '../../indexer/types.js';
+import { namespaceGraph } from '../../namespace/namespace.js';
+import { createQueryEngine } from '../../query/query-engine.js';
+import type { QueryEngine } from '../../query/query-engine.js';
+import { scopeGraph } from '../../scope/scope.js';
+import { writeGraphJson } from '../graph-json.js';
+import { createFileSink } from '../sink.js';
+
+function makeEntity(
+  name: string,
+  overrides: Partial<EntityInsert> = {},
+): EntityInsert {
+  return {
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} description`,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    metadata: { type: 'service', description: `${name} description` },
+    ...overrides,
+  };
+}
+
+describe('writeGraphJson', () => {
+  let dbManager: DatabaseManager;
+  let query: QueryEngine;
+  let dir: string;
+
+  beforeEach(() => {
+    dbManager = createDatabaseManager();
+    dbManager.initialize();
+    query = createQueryEngine(dbManager);
+    dir = join(
+      tmpdir(),
+      `knowgraph-stream-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(dir, { recursive: true });
+
+    dbManager.insertEntity(
+      makeEntity('checkout', {
+        owner: 'shop-team',
+        metadata: {
+          type: 'service',
+          description: 'checkout description',
+          dependencies: {
+            services: ['payments', 'Payments', 'checkout', 'ledger'],
+            external_apis: ['stripe'],
+            databases: ['orders-db'],
+          },
+        },
+      }),
+    );
+    dbManager.insertEntity(makeEntity('payments'));
+    dbManager.insertEntity(
+      makeEntity('payments', { entityType: 'function', line: 5 }),
+    );
+    dbManager.insertEntity(
+      makeEntity('refunds', {
+        metadata: {
+          type: 'service',
+          description: 'refunds description',
+          dependencies: { external_apis: ['stripe'] },
+        },
+      }),
+    );
+  });
+
+  afterEach(() => {
+    dbManager.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('matches the canonical serialization of the built graph', () => {
+    const graph = buildDependencyGraph(query.getAll());
+
+    for (const pretty of [false, true]) {
+      const chunks: string[] = [];
+      const stats = writeGraphJson(
+        () => query.iterateAll(),
+        { write: (chunk) => chunks.push(chunk) },
+        { pretty },
+      );
+      expect(chunks.join('')).toBe(stableStringify(graph, pretty));
+      expect(stats).toEqual({
+        nodes: graph.nodes.length,
+        edges: graph.edges.length,
+      });
+    }
+  });
+
+  it('resolves aliases and renames like the built graph', () => {
+    const options = {
+      aliases: { 'stripe-payments': ['stripe'] },
+      renames: [{ from: 'ledger', to: 'payments' }],
+    };
+    const graph = buildDependencyGraph(query.getAll(), options);
+    const chunks: string[] = [];
+    writeGraphJson(
+      () => query.iterateAll(),
+      { write: (chunk) => chunks.push(chunk) },
+      options,
+    );
+    expect(chunks.join('')).toBe(stableStringify(graph, false));
+    expect(graph.nodes.map((node) => node.id)).toContain(
+      'external:external_api:stripe-payments',
+    );
+    expect(graph.nodes.some((node) => node.name === 'ledger')).toBe(false);
+  });
+
+  it('matches the scoped graph when given scopes', () => {
+    const all = query.getAll();
+    for (const file of ['src/checkout.ts', 'src/refunds.ts']) {
+      const inScope = new Set(
+        all.filter((entity) => entity.filePath === file).map((e) => e.id),
+      );
+      const graph = scopeGraph(buildDependencyGraph(all), inScope);
+      const chunks: string[] = [];
+      writeGraphJson(
+        () => query.iterateAll(),
+        { write: (chunk) => chunks.push(chunk) },
+        { inScope },
+      );
+      expect(chunks.join('')).toBe(stableStringify(graph, false));
+    }
+  });
+
+  it('applies an edge filter like filterGraphEdges', () => {
+    const all = query.getAll();
+    const inScope = new Set(
+      all.filter((entity) => entity.name === 'refunds').map((e) => e.id),
+    );
+    for (const edgeFilter of [
+      { provenance: ['import' as const] },
+      { provenance: ['declared' as const], minConfidence: 1 },
+    ]) {
+      const graph = scopeGraph(
+        filterGraphEdges(buildDependencyGraph(all), edgeFilter),
+        inScope,
+      );
+      const chunks: string[] = [];
+      writeGraphJson(
+        () => query.iterateAll(),
+        { write: (chunk) => chunks.push(chunk) },
+        { inScope, edgeFilter },
+      );
+      expect(chunks.join('')).toBe(stableStringify(graph, false));
+    }
+  });
+
+  it('writes the edges enrichers found like the built graph', () => {
+    dbManager.insertEntity(
+      makeEntity('notify', {
+        metadata: {
+          type: 'service',
+          description: 'notify description',
+          dependencies: { external_apis: ['stripe'] },
+          calls: ['src/checkout.ts:checkout', 'src/payments.ts:5'],
+          http_calls: [
+            { url: 'api.stripe.com/v1', api: 'stripe' },
+            { url: 'api.twilio.com/sms', api: 'twilio' },
+            { url: 'refunds/all', service: 'src/refunds.ts:refunds' },
+          ],
+        },
+      }),
+    );
+    const all = query.getAll();
+    for (const edgeFilter of [undefined, { provenance: ['call' as const] }]) {
+      const built = buildDependencyGraph(all);
+      const graph = edgeFilter ? filterGraphEdges(built, edgeFilter) : built;
+      const chunks: string[] = [];
+      writeGraphJson(
+        () => query.iterateAll(),
+        { write: (chunk) => chunks.push(chunk) },
+        { edgeFilter },
+      );
+      expect(chunks.join('')).toBe(stableStringify(graph, false));
+    }
+    expect(
+      buildDependencyGraph(all)
+        .edges.filter((edge) => edge.provenance !== 'declared')
+        .map((edge) => `${edge.provenance} ${edge.to}`),
+    ).toEqual([
+      expect.stringMatching(/^call /),
+      expect.stringMatching(/^call /),
+      'call external:external_api:twilio',
+      expect.stringMatching(/^router /),
+    ]);
+  });
+
+  it('prefixes ids with a namespace like the built graph', () => {
+    const all = query.getAll();
+    const inScope = new Set(
+      all.filter((entity) => entity.name === 'refunds').map((e) => e.id),
+    );
+    const graph = namespaceGraph(
+      scopeGraph(buildDependencyGraph(all), inScope),
+      'acme/shop',
+    );
+    const chunks: string[] = [];
+    writeGraphJson(
+      () => query.iterateAll(),
+      { write: (chunk) => chunks.push(chunk) },
+      { inScope, namespace: 'acme/shop' },
+    );
+    expect(chunks.join('')).toBe(stableStringify(graph, false));
+  });
+
+  it('writes an empty graph', () => {
+    const chunks: string[] = [];
+    writeGraphJson(() => [], { write: (chunk) => chunks.push(chunk) });
+    expect(chunks.join('')).toBe('{"edges":[],"nodes":[]}');
+  });
+
+  it('streams through a buffered file sink', () => {
+    const path = join(dir, 'graph.json');
+    const sink = createFileSink(path, 16);
+    writeGraphJson(() => query.iterateAll(), sink, { pretty: true });
+    sink.close();
+
+    expect(readFileSync(path, 'utf-8')).toBe(
+      stableStringify(buildDependencyGraph(query.getAll())),
+    );
+  });
+
+  it('stops when cancelled', () => {
+    const controller = new AbortController();
+    controller.abort();
+    expect(() =>
+      writeGraphJson(
+        () => query.iterateAll(),
+        { write: () => undefined },
+        { signal: controller.signal },
+      ),
+    ).toThrow();
+  });
+});
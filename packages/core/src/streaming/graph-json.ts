@@ -0,0 +1,208 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Streams a dependency graph as canonical JSON in passes over the index instead of materializing it
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [export, streaming, graph, json, memory]
+ * context:
+ *   business_goal: Export org-scale graphs without holding the whole document in memory
+ *   domain: streaming
+ */
+import { createCancellationCheck } from '../cancellation/cancellation.js';
+import { canonicalize } from '../canonical/canonical.js';
+import {
+  createTargetIndex,
+  declaredDependencies,
+  externalNode,
+  toEntityNode,
+} from '../graph/graph-builder.js';
+import {
+  ENRICHED_EDGE_CONFIDENCE,
+  createEntityReferenceIndex,
+  enrichedDependencies,
+} from '../graph/graph-enriched.js';
+import { createNameTable } from '../graph/graph-names.js';
+import { edgeMatches } from '../graph/graph-provenance.js';
+import type { GraphEdge, GraphNode } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import { findSubmodule, isVendoredPath } from '../indexer/walker.js';
+import { namespaceNode, namespacedId } from '../namespace/namespace.js';
+import { timePhase } from '../profiling/phase-timer.js';
+import type {
+  EntitySource,
+  GraphJsonOptions,
+  GraphJsonStats,
+  TextSink,
+} from './types.js';
+
+interface NameTarget extends Pick<StoredEntity, 'id' | 'entityType'> {
+  readonly inScope: boolean;
+  /** The namespace the target's node id carries, if any. */
+  readonly namespace?: string;
+}
+
+/** Where an entity's edge goes: to `target`, or else the `kind` stub `name`. */
+interface EdgeEnd {
+  readonly kind: GraphEdge['kind'];
+  readonly target?: NameTarget;
+  readonly name: string;
+  readonly attributes: Partial<GraphEdge>;
+}
+
+function writeArray(
+  sink: TextSink,
+  key: string,
+  items: Iterable<unknown>,
+  pretty: boolean,
+): number {
+  const name = JSON.stringify(key);
+  sink.write(pretty ? `  ${name}: [` : `${name}:[`);
+  let count = 0;
+  for (const item of items) {
+    const json = pretty
+      ? JSON.stringify(canonicalize(item), null, 2).replace(/\n/g, '\n    ')
+      : JSON.stringify(canonicalize(item));
+    sink.write(`${count > 0 ? ',' : ''}${pretty ? '\n    ' : ''}${json}`);
+    count++;
+  }
+  sink.write(pretty && count > 0 ? '\n  ]' : ']');
+  return count;
+}
+
+/**
+ * Write the dependency graph of `source` to `sink` as JSON. The output is
+ * byte-identical to `stableStringify(buildDependencyGraph(entities, options))`
+ * but is produced one node or edge at a time over three passes (name index,
+ * edges, nodes). Only the name index, external stubs, and one entity are
+ * held in memory, so exports scale with the number of distinct names rather
+ * than the size of the graph. Go package nodes are not supported.
+ *
+ * With `inScope`, only edges with an end in scope are written, and the
+ * out-of-scope entities they reach are written as stubs, as `scopeGraph`
+ * does. Only the stub ids are held on top of the unscoped export.
+ * With `edgeFilter`, only the edges it selects are written, as with
+ * `filterGraphEdges`. With `namespace` and `submodules`, ids are
+ * prefixed as `namespaceGraph` and `namespaceSubmodules` do.
+ */
+export function writeGraphJson(
+  source: EntitySource,
+  sink: TextSink,
+  options: GraphJsonOptions = {},
+): GraphJsonStats {
+  const { workspaces = [], pretty = false, profiler } = options;
+  const { submodules = [] } = options;
+  const checkCancelled = createCancellationCheck('Export', options);
+  const inScope = (entity: StoredEntity): boolean =>
+    options.inScope?.has(entity.id) ?? true;
+
+  // Vendored entities are external nodes, which no namespace prefixes
+  const namespaceOf = (entity: StoredEntity): string | undefined =>
+    isVendoredPath(entity.filePath)
+      ? undefined
+      : (findSubmodule(entity.filePath, submodules)?.namespace ??
+        options.namespace);
+
+  const names = createNameTable(options);
+  const targets = createTargetIndex<NameTarget>(names);
+  const references = createEntityReferenceIndex<NameTarget>();
+  timePhase(profiler, 'build', () => {
+    for (const entity of source()) {
+      checkCancelled();
+      const target = {
+        id: entity.id,
+        entityType: entity.entityType,
+        inScope: inScope(entity),
+        namespace: namespaceOf(entity),
+      };
+      targets.add(entity, target);
+      references.add(entity, target);
+    }
+  });
+
+  const id = (value: string, namespace: string | undefined): string =>
+    namespace ? namespacedId(namespace, value) : value;
+  const node = (entity: StoredEntity): GraphNode => {
+    const built = toEntityNode(entity, workspaces, names);
+    const namespace = namespaceOf(entity);
+    return namespace ? namespaceNode(built, namespace) : built;
+  };
+
+  const externals = new Map<string, GraphNode>();
+  const stubs = new Set<string>();
+
+  function* edges(): Generator<GraphEdge> {
+    for (const entity of source()) {
+      checkCancelled();
+      const fromInScope = inScope(entity);
+      const ends: EdgeEnd[] = declaredDependencies(entity).map(
+        ({ kind, name, ...attributes }) => ({
+          kind,
+          target: kind === 'service' ? targets.resolve(name) : undefined,
+          name,
+          attributes,
+        }),
+      );
+      for (const found of enrichedDependencies(entity, references.resolve)) {
+        const attributes = {
+          provenance: found.provenance,
+          confidence: ENRICHED_EDGE_CONFIDENCE,
+        };
+        ends.push(
+          found.kind === 'service'
+            ? { kind: found.kind, target: found.target, name: '', attributes }
+            : { kind: found.kind, name: found.name, attributes },
+        );
+      }
+      // Edges start at this entity, so duplicates can only come from it
+      const seen = new Set<string>();
+      for (const { kind, target, name, attributes } of ends) {
+        if (!fromInScope && !target?.inScope) continue;
+        const external = externalNode(kind, names.canonical(name));
+        const to = target?.id ?? external.id;
+        const edge: GraphEdge = {
+          from: id(entity.id, namespaceOf(entity)),
+          to: target ? id(to, target.namespace) : to,
+          kind,
+          provenance: 'declared',
+          confidence: 1,
+          ...attributes,
+        };
+        const key = `${to}\u0000${kind}`;
+        if (to === entity.id || seen.has(key)) continue;
+        // The first edge between two nodes stands for the rest, as in the
+        // built graph, even when the filter then drops it
+        seen.add(key);
+        if (options.edgeFilter && !edgeMatches(edge, options.edgeFilter)) {
+          continue;
+        }
+        if (!target && !externals.has(to)) externals.set(to, external);
+        if (!fromInScope) stubs.add(entity.id);
+        if (target && !target.inScope) stubs.add(target.id);
+        yield edge;
+      }
+    }
+  }
+
+  function* nodes(): Generator<GraphNode> {
+    for (const entity of source()) {
+      checkCancelled();
+      if (stubs.has(entity.id)) {
+        yield { ...node(entity), stub: true };
+      } else if (inScope(entity)) {
+        yield node(entity);
+      }
+    }
+    yield* externals.values();
+  }
+
+  // Keys in canonical order: edges first, which also discovers the externals
+  return timePhase(profiler, 'export', () => {
+    sink.write(pretty ? '{\n' : '{');
+    const edgeCount = writeArray(sink, 'edges', edges(), pretty);
+    sink.write(pretty ? ',\n' : ',');
+    const nodeCount = writeArray(sink, 'nodes', nodes(), pretty);
+    sink.write(pretty ? '\n}' : '}');
+    return { nodes: nodeCount, edges: edgeCount };
+  });
+}
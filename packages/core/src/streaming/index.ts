@@ -0,0 +1,10 @@
+export type {
+  TextSink,
+  ByteSink,
+  FileSink,
+  EntitySource,
+  GraphJsonOptions,
+  GraphJsonStats,
+} from './types.js';
+export { createFileSink } from './sink.js';
+export { writeGraphJson } from './graph-json.js';
@@ -0,0 +1,58 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Buffered file sink that flushes exported text in fixed-size chunks
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [export, streaming, filesystem, buffer]
+ * context:
+ *   business_goal: Write large exports with memory use that does not grow with the graph
+ *   domain: streaming
+ */
+import { closeSync, openSync, writeSync } from 'node:fs';
+import type { FileSink } from './types.js';
+
+const DEFAULT_BUFFER_SIZE = 64 * 1024;
+
+/**
+ * Open `path` for writing, truncating it. Text is buffered until roughly
+ * `bufferSize` characters are pending, so memory use does not grow with the
+ * size of the file. Binary chunks are written straight through, after any
+ * pending text.
+ */
+export function createFileSink(
+  path: string,
+  bufferSize = DEFAULT_BUFFER_SIZE,
+): FileSink {
+  const fd = openSync(path, 'w');
+  let pending: string[] = [];
+  let pendingLength = 0;
+
+  function flush(): void {
+    if (pendingLength === 0) return;
+    writeSync(fd, pending.join(''));
+    pending = [];
+    pendingLength = 0;
+  }
+
+  function write(chunk: string | Uint8Array): void {
+    if (typeof chunk !== 'string') {
+      flush();
+      writeSync(fd, chunk);
+      return;
+    }
+    pending.push(chunk);
+    pendingLength += chunk.length;
+    if (pendingLength >= bufferSize) flush();
+  }
+
+  function close(): void {
+    try {
+      flush();
+    } finally {
+      closeSync(fd);
+    }
+  }
+
+  return { write, close };
+}
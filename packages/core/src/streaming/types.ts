@@ -0,0 +1,60 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for streaming graph exports through bounded-memory text sinks
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [export, streaming, memory, types, interface]
+ * context:
+ *   business_goal: Let exporters stream to files, sockets, or tests through one sink interface
+ *   domain: streaming
+ */
+import type { CancellationOptions } from '../cancellation/cancellation.js';
+import type { DependencyGraphOptions, EdgeFilter } from '../graph/types.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { PhaseTimer } from '../profiling/types.js';
+
+/** Destination for exported text, written chunk by chunk. */
+export interface TextSink {
+  write(chunk: string): void;
+}
+
+/** A sink that also takes binary chunks, for formats such as snapshots. */
+export interface ByteSink extends TextSink {
+  write(chunk: string | Uint8Array): void;
+}
+
+export interface FileSink extends ByteSink {
+  /** Flush buffered text and close the file. */
+  close(): void;
+}
+
+/**
+ * Yields every entity in a stable order. Called once per pass, so it must
+ * start over on each call (for example `() => query.iterateAll()`).
+ */
+export type EntitySource = () => Iterable<StoredEntity>;
+
+export interface GraphJsonOptions
+  extends Pick<
+      DependencyGraphOptions,
+      'workspaces' | 'aliases' | 'renames' | 'namespace' | 'submodules'
+    >,
+    CancellationOptions {
+  /** Indent with two spaces like `stableStringify(graph)`. */
+  readonly pretty?: boolean;
+  /** Records the name index pass as `build` and writing as `export`. */
+  readonly profiler?: PhaseTimer;
+  /**
+   * Ids of the entities in scope: write only the part of the graph around
+   * them, with out-of-scope neighbors as stubs (see `scopeGraph`).
+   */
+  readonly inScope?: ReadonlySet<string>;
+  /** Write only the edges this selects. */
+  readonly edgeFilter?: EdgeFilter;
+}
+
+export interface GraphJsonStats {
+  readonly nodes: number;
+  readonly edges: number;
+}
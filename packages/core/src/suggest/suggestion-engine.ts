@@ -11,6 +11,7 @@
  */
 import { readFileSync, readdirSync, statSync } from 'node:fs';
 import { join, extname, basename, relative } from 'node:path';
+import { toPosixPath } from '../paths/paths.js';
 import type { ParserRegistry } from '../parsers/types.js';
 import type {
   FileSuggestion,
@@ -188,7 +189,7 @@ function analyzeFile(filePath: string, rootDir: string): FileAnalysis | null {
   try {
     const content = readFileSync(filePath, 'utf-8');
     const fileName = basename(filePath);
-    const relativePath = relative(rootDir, filePath);
+    const relativePath = toPosixPath(relative(rootDir, filePath));
     return {
       filePath,
       relativePath,
@@ -0,0 +1,115 @@
+import { describe, it, expect, vi } from 'vitest';
+import { createTelemetry, recordPhaseTimings } from '../telemetry.js';
+import { createOtlpExporter, toOtlpMetrics, toOtlpTraces } from '../otlp.js';
+import type { TelemetryOptions } from '../types.js';
+
+function fixed(): TelemetryOptions {
+  let clock = 1_714_644_000_000;
+  let id = 0;
+  return {
+    now: () => (clock += 10),
+    randomId: (bytes) => String(++id).padStart(bytes * 2, '0'),
+  };
+}
+
+describe('createTelemetry', () => {
+  it('nests spans started inside trace, across awaits', async () => {
+    const telemetry = createTelemetry(fixed());
+
+    await telemetry.trace('knowgraph.index', {}, async () => {
+      await Promise.resolve();
+      telemetry.trace('knowgraph.enricher', {}, () => undefined);
+    });
+
+    const [child, root] = telemetry.drain().spans;
+    expect(root).toMatchObject({ name: 'knowgraph.index', parentSpanId: null });
+    expect(child.parentSpanId).toBe(root.spanId);
+    expect(child.traceId).toBe(root.traceId);
+  });
+
+  it('marks a span failed when its function throws', () => {
+    const telemetry = createTelemetry(fixed());
+
+    expect(() =>
+      telemetry.trace('knowgraph.export', {}, () => {
+        throw new Error('disk full');
+      }),
+    ).toThrow('disk full');
+
+    expect(telemetry.drain().spans[0].error).toBe('disk full');
+  });
+
+  it('aggregates metrics by name and attributes until drained', () => {
+    const telemetry = createTelemetry(fixed());
+
+    recordPhaseTimings(telemetry, [
+      { phase: 'parse', ms: 30, calls: 2 },
+      { phase: 'walk', ms: 5, calls: 1 },
+    ]);
+    telemetry.record('knowgraph.phase.duration', 10, 'ms', {
+      'knowgraph.phase': 'parse',
+    });
+
+    const { metrics } = telemetry.drain();
+    expect(metrics).toHaveLength(2);
+    expect(metrics[0]).toMatchObject({ count: 2, sum: 40, min: 10, max: 30 });
+    expect(telemetry.drain().metrics).toEqual([]);
+  });
+});
+
+describe('OTLP export', () => {
+  it('encodes spans and metrics as OTLP/JSON', () => {
+    const telemetry = createTelemetry(fixed());
+    const span = telemetry.startSpan('GET /healthz', {
+      kind: 'server',
+      attributes: { 'http.response.status_code': 200 },
+    });
+    span.end();
+    telemetry.count('knowgraph.enricher.updated', 3);
+    const batch = telemetry.drain();
+    const resource = { 'service.name': 'knowgraph' };
+
+    const traces = toOtlpTraces(batch, resource) as any;
+    const encoded = traces.resourceSpans[0].scopeSpans[0].spans[0];
+    expect(encoded).toMatchObject({
+      name: 'GET /healthz',
+      kind: 2,
+      status: { code: 1 },
+      attributes: [
+        { key: 'http.response.status_code', value: { intValue: '200' } },
+      ],
+    });
+    expect(encoded.startTimeUnixNano).toBe('1714644000020000000');
+
+    const metrics = toOtlpMetrics(batch, resource) as any;
+    const metric = metrics.resourceMetrics[0].scopeMetrics[0].metrics[0];
+    expect(metric.sum.dataPoints[0].asDouble).toBe(3);
+  });
+
+  it('posts both signals and reports a refusing receiver', async () => {
+    const telemetry = createTelemetry(fixed());
+    telemetry.startSpan('knowgraph.index').end();
+    telemetry.count('knowgraph.scans', 1);
+    const fetchMock = vi.fn(async () => new Response('', { status: 200 }));
+    const exporter = createOtlpExporter({
+      endpoint: 'http://collector:4318/',
+      headers: { 'x-api-key': 'secret' },
+      resource: { 'service.name': 'knowgraph' },
+      fetch: fetchMock as unknown as typeof fetch,
+    });
+
+    await exporter.export(telemetry.drain());
+
+    const urls = fetchMock.mock.calls.map((call: unknown[]) => call[0]);
+    expect(urls).toEqual([
+      'http://collector:4318/v1/traces',
+      'http://collector:4318/v1/metrics',
+    ]);
+
+    fetchMock.mockImplementation(
+      async () => new Response('', { status: 503, statusText: 'Busy' }),
+    );
+    telemetry.count('knowgraph.scans', 1);
+    await expect(exporter.export(telemetry.drain())).rejects.toThrow(/503/);
+  });
+});
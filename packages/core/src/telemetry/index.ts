@@ -0,0 +1,21 @@
+export type {
+  AttributeValue,
+  Attributes,
+  SpanKind,
+  SpanRecord,
+  Span,
+  SpanOptions,
+  MetricKind,
+  MetricRecord,
+  TelemetryBatch,
+  Telemetry,
+  TelemetryOptions,
+  OtlpExporterOptions,
+  OtlpExporter,
+} from './types.js';
+export { createTelemetry, recordPhaseTimings } from './telemetry.js';
+export {
+  createOtlpExporter,
+  toOtlpMetrics,
+  toOtlpTraces,
+} from './otlp.js';
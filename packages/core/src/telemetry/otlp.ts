@@ -0,0 +1,191 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Encodes recorded spans and metrics as OTLP/JSON and posts them to an OpenTelemetry collector
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [telemetry, opentelemetry, otlp, export]
+ * context:
+ *   business_goal: Send traces to any OpenTelemetry collector without an SDK dependency
+ *   domain: telemetry
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type {
+  AttributeValue,
+  Attributes,
+  MetricRecord,
+  OtlpExporter,
+  OtlpExporterOptions,
+  SpanRecord,
+  TelemetryBatch,
+} from './types.js';
+
+const SCOPE = { name: '@know-graph/core' };
+const DEFAULT_TIMEOUT_MS = 10_000;
+
+// Enum values from the OTLP protobuf definitions
+const SPAN_KIND = { internal: 1, server: 2 } as const;
+const STATUS_OK = 1;
+const STATUS_ERROR = 2;
+const TEMPORALITY_DELTA = 1;
+
+/** Milliseconds since the epoch as the decimal nanoseconds OTLP expects. */
+function nanos(ms: number): string {
+  return (BigInt(Math.round(ms * 1000)) * 1000n).toString();
+}
+
+function anyValue(value: AttributeValue): Record<string, unknown> {
+  if (typeof value === 'boolean') return { boolValue: value };
+  if (typeof value === 'string') return { stringValue: value };
+  return Number.isInteger(value)
+    ? { intValue: String(value) }
+    : { doubleValue: value };
+}
+
+function keyValues(attributes: Attributes): Record<string, unknown>[] {
+  return Object.entries(attributes).map(([key, value]) => ({
+    key,
+    value: anyValue(value),
+  }));
+}
+
+function otlpSpan(span: SpanRecord): Record<string, unknown> {
+  return {
+    traceId: span.traceId,
+    spanId: span.spanId,
+    ...(span.parentSpanId ? { parentSpanId: span.parentSpanId } : {}),
+    name: span.name,
+    kind: SPAN_KIND[span.kind],
+    startTimeUnixNano: nanos(span.startTime),
+    endTimeUnixNano: nanos(span.endTime),
+    attributes: keyValues(span.attributes),
+    status:
+      span.error === null
+        ? { code: STATUS_OK }
+        : { code: STATUS_ERROR, message: span.error },
+  };
+}
+
+function otlpMetric(
+  metric: MetricRecord,
+  batch: TelemetryBatch,
+): Record<string, unknown> {
+  const point = {
+    attributes: keyValues(metric.attributes),
+    startTimeUnixNano: nanos(batch.startTime),
+    timeUnixNano: nanos(batch.endTime),
+  };
+  if (metric.kind === 'counter') {
+    return {
+      name: metric.name,
+      unit: metric.unit,
+      sum: {
+        aggregationTemporality: TEMPORALITY_DELTA,
+        isMonotonic: true,
+        dataPoints: [{ ...point, asDouble: metric.sum }],
+      },
+    };
+  }
+  return {
+    name: metric.name,
+    unit: metric.unit,
+    histogram: {
+      aggregationTemporality: TEMPORALITY_DELTA,
+      // One bucket: the count, sum, and range are what is kept
+      dataPoints: [
+        {
+          ...point,
+          count: String(metric.count),
+          sum: metric.sum,
+          min: metric.min,
+          max: metric.max,
+          bucketCounts: [String(metric.count)],
+          explicitBounds: [],
+        },
+      ],
+    },
+  };
+}
+
+/** The batch's spans as an OTLP/JSON `ExportTraceServiceRequest`. */
+export function toOtlpTraces(
+  batch: TelemetryBatch,
+  resource: Attributes,
+): Record<string, unknown> {
+  return {
+    resourceSpans: [
+      {
+        resource: { attributes: keyValues(resource) },
+        scopeSpans: [{ scope: SCOPE, spans: batch.spans.map(otlpSpan) }],
+      },
+    ],
+  };
+}
+
+/** The batch's metrics as an OTLP/JSON `ExportMetricsServiceRequest`. */
+export function toOtlpMetrics(
+  batch: TelemetryBatch,
+  resource: Attributes,
+): Record<string, unknown> {
+  return {
+    resourceMetrics: [
+      {
+        resource: { attributes: keyValues(resource) },
+        scopeMetrics: [
+          {
+            scope: SCOPE,
+            metrics: batch.metrics.map((metric) => otlpMetric(metric, batch)),
+          },
+        ],
+      },
+    ],
+  };
+}
+
+/**
+ * An exporter posting OTLP/JSON to `${endpoint}/v1/traces` and
+ * `${endpoint}/v1/metrics`, the OTLP/HTTP paths every collector accepts.
+ */
+export function createOtlpExporter(options: OtlpExporterOptions): OtlpExporter {
+  const base = options.endpoint.replace(/\/+$/, '');
+  const fetchFn = options.fetch ?? fetch;
+  const timeoutMs = options.timeoutMs ?? DEFAULT_TIMEOUT_MS;
+
+  async function post(path: string, body: unknown): Promise<void> {
+    const url = `${base}${path}`;
+    let response: Response;
+    try {
+      response = await fetchFn(url, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...options.headers },
+        body: JSON.stringify(body),
+        signal: AbortSignal.timeout(timeoutMs),
+      });
+    } catch (err) {
+      throw createKnowgraphError(
+        'io',
+        `Could not reach the OTLP receiver at ${url}: ` +
+          (err instanceof Error ? err.message : String(err)),
+        { cause: err },
+      );
+    }
+    if (!response.ok) {
+      throw createKnowgraphError(
+        'io',
+        `The OTLP receiver at ${url} answered ${response.status} ` +
+          response.statusText,
+      );
+    }
+  }
+
+  return {
+    async export(batch) {
+      if (batch.spans.length > 0) {
+        await post('/v1/traces', toOtlpTraces(batch, options.resource));
+      }
+      if (batch.metrics.length > 0) {
+        await post('/v1/metrics', toOtlpMetrics(batch, options.resource));
+      }
+    },
+  };
+}
@@ -0,0 +1,189 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Records spans and aggregated metrics about knowgraph's own runs, nesting spans across awaits
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [telemetry, opentelemetry, tracing, metrics]
+ * context:
+ *   business_goal: Let platform teams monitor scheduled scans and find the slow phase from their own observability stack
+ *   domain: telemetry
+ */
+import { AsyncLocalStorage } from 'node:async_hooks';
+import { randomBytes } from 'node:crypto';
+import { performance } from 'node:perf_hooks';
+import type { PhaseTiming } from '../profiling/types.js';
+import type {
+  Attributes,
+  MetricKind,
+  MetricRecord,
+  Span,
+  SpanOptions,
+  SpanRecord,
+  Telemetry,
+  TelemetryBatch,
+  TelemetryOptions,
+} from './types.js';
+
+function errorMessage(err: unknown): string {
+  return err instanceof Error ? err.message : String(err);
+}
+
+function isPromise(value: unknown): value is Promise<unknown> {
+  return (
+    typeof value === 'object' &&
+    value !== null &&
+    typeof (value as { then?: unknown }).then === 'function'
+  );
+}
+
+/** Attributes in key order, so equal sets aggregate into one metric. */
+function attributeKey(attributes: Attributes): string {
+  return JSON.stringify(
+    Object.keys(attributes)
+      .sort()
+      .map((key) => [key, attributes[key]]),
+  );
+}
+
+/**
+ * Create a recorder for spans and metrics. Nothing leaves the process
+ * until a batch from `drain` is handed to an exporter.
+ */
+export function createTelemetry(options: TelemetryOptions = {}): Telemetry {
+  const now =
+    options.now ?? (() => performance.timeOrigin + performance.now());
+  const randomId =
+    options.randomId ??
+    ((bytes: number) => randomBytes(bytes).toString('hex'));
+  const current = new AsyncLocalStorage<Span>();
+  let finished: SpanRecord[] = [];
+  let metrics = new Map<string, MetricRecord>();
+  let batchStart = now();
+
+  function startSpan(name: string, spanOptions: SpanOptions = {}): Span {
+    const parent = spanOptions.parent ?? current.getStore();
+    const traceId = parent?.traceId ?? randomId(16);
+    const spanId = randomId(8);
+    const startTime = now();
+    let attributes: Attributes = { ...spanOptions.attributes };
+    let ended = false;
+    return {
+      traceId,
+      spanId,
+      setAttributes(extra) {
+        attributes = { ...attributes, ...extra };
+      },
+      end(error?: unknown) {
+        if (ended) return;
+        ended = true;
+        finished.push({
+          traceId,
+          spanId,
+          parentSpanId: parent?.spanId ?? null,
+          name,
+          kind: spanOptions.kind ?? 'internal',
+          startTime,
+          endTime: now(),
+          attributes,
+          error: error === undefined ? null : errorMessage(error),
+        });
+      },
+    };
+  }
+
+  function trace<T>(
+    name: string,
+    spanOptions: SpanOptions,
+    fn: (span: Span) => T,
+  ): T {
+    const span = startSpan(name, spanOptions);
+    let result: T;
+    try {
+      result = current.run(span, () => fn(span));
+    } catch (err) {
+      span.end(err);
+      throw err;
+    }
+    if (isPromise(result)) {
+      result.then(
+        () => span.end(),
+        (err: unknown) => span.end(err),
+      );
+    } else {
+      span.end();
+    }
+    return result;
+  }
+
+  function add(
+    kind: MetricKind,
+    name: string,
+    value: number,
+    unit: string,
+    attributes: Attributes,
+  ): void {
+    const key = `${kind}\u0000${name}\u0000${attributeKey(attributes)}`;
+    const previous = metrics.get(key);
+    metrics.set(key, {
+      name,
+      kind,
+      unit,
+      attributes,
+      count: (previous?.count ?? 0) + 1,
+      sum: (previous?.sum ?? 0) + value,
+      min: Math.min(previous?.min ?? value, value),
+      max: Math.max(previous?.max ?? value, value),
+    });
+  }
+
+  function drain(): TelemetryBatch {
+    const endTime = now();
+    const batch = {
+      startTime: batchStart,
+      endTime,
+      spans: finished,
+      metrics: [...metrics.values()],
+    };
+    finished = [];
+    metrics = new Map();
+    batchStart = endTime;
+    return batch;
+  }
+
+  return {
+    startSpan,
+    trace,
+    count: (name, value, attributes = {}) =>
+      add('counter', name, value, '1', attributes),
+    record: (name, value, unit, attributes = {}) =>
+      add('histogram', name, value, unit, attributes),
+    drain,
+  };
+}
+
+/**
+ * Record the time `timings` spent per phase as the
+ * `knowgraph.phase.duration` histogram, and on `span` as
+ * `knowgraph.phase.<phase>.ms` attributes, since each phase is many short
+ * calls rather than one span.
+ */
+export function recordPhaseTimings(
+  telemetry: Telemetry,
+  timings: readonly PhaseTiming[],
+  span?: Span,
+): void {
+  for (const timing of timings) {
+    telemetry.record('knowgraph.phase.duration', timing.ms, 'ms', {
+      'knowgraph.phase': timing.phase,
+    });
+  }
+  span?.setAttributes(
+    Object.fromEntries(
+      timings.map((timing) => [
+        `knowgraph.phase.${timing.phase}.ms`,
+        Math.round(timing.ms),
+      ]),
+    ),
+  );
+}
@@ -0,0 +1,118 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the spans and metrics knowgraph records about its own runs and their OTLP export
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [telemetry, opentelemetry, tracing, metrics, types, interface]
+ * context:
+ *   business_goal: Keep span and metric names stable so dashboards survive upgrades
+ *   domain: telemetry
+ */
+
+export type AttributeValue = string | number | boolean;
+
+export type Attributes = Readonly<Record<string, AttributeValue>>;
+
+/** `server` for spans handling a request; `internal` for everything else. */
+export type SpanKind = 'internal' | 'server';
+
+export interface SpanRecord {
+  /** 32 hex digits, shared by every span of one trace. */
+  readonly traceId: string;
+  /** 16 hex digits. */
+  readonly spanId: string;
+  readonly parentSpanId: string | null;
+  readonly name: string;
+  readonly kind: SpanKind;
+  /** Milliseconds since the Unix epoch. */
+  readonly startTime: number;
+  readonly endTime: number;
+  readonly attributes: Attributes;
+  /** Message of the error that ended the span; null when it succeeded. */
+  readonly error: string | null;
+}
+
+export interface Span {
+  readonly traceId: string;
+  readonly spanId: string;
+  setAttributes(attributes: Attributes): void;
+  /** Finish the span, failed when `error` is given. Later calls do nothing. */
+  end(error?: unknown): void;
+}
+
+export interface SpanOptions {
+  readonly attributes?: Attributes;
+  readonly kind?: SpanKind;
+  /** Defaults to the span `trace` is running in, if any. */
+  readonly parent?: Span;
+}
+
+/** A counter sums values; a histogram also keeps their count and range. */
+export type MetricKind = 'counter' | 'histogram';
+
+export interface MetricRecord {
+  readonly name: string;
+  readonly kind: MetricKind;
+  /** UCUM unit, such as `ms`, `s`, or `1` for counts. */
+  readonly unit: string;
+  readonly attributes: Attributes;
+  readonly count: number;
+  readonly sum: number;
+  readonly min: number;
+  readonly max: number;
+}
+
+/** What was recorded between two drains. */
+export interface TelemetryBatch {
+  readonly startTime: number;
+  readonly endTime: number;
+  readonly spans: readonly SpanRecord[];
+  readonly metrics: readonly MetricRecord[];
+}
+
+export interface Telemetry {
+  startSpan(name: string, options?: SpanOptions): Span;
+  /**
+   * Run `fn` in a span that ends when it returns, throws, or its promise
+   * settles. Spans started inside `fn`, even across awaits, are its
+   * children.
+   */
+  trace<T>(name: string, options: SpanOptions, fn: (span: Span) => T): T;
+  /** Add `value` to a counter. */
+  count(name: string, value: number, attributes?: Attributes): void;
+  /** Add `value` to a histogram. */
+  record(
+    name: string,
+    value: number,
+    unit: string,
+    attributes?: Attributes,
+  ): void;
+  /** Take the finished spans and the metrics since the last drain. */
+  drain(): TelemetryBatch;
+}
+
+export interface TelemetryOptions {
+  /** Milliseconds since the Unix epoch. */
+  readonly now?: () => number;
+  /** Random hex digits of the given byte length, for span and trace ids. */
+  readonly randomId?: (bytes: number) => string;
+}
+
+export interface OtlpExporterOptions {
+  /** Base URL of an OTLP/HTTP receiver; `/v1/traces` is appended. */
+  readonly endpoint: string;
+  readonly headers?: Readonly<Record<string, string>>;
+  /** Describes the process, such as `service.name`. */
+  readonly resource: Attributes;
+  readonly timeoutMs?: number;
+  readonly fetch?: typeof fetch;
+}
+
+export interface OtlpExporter {
+  /**
+   * Send the batch's spans and metrics, skipping whichever is empty.
+   * Throws an `io` error when the receiver cannot be reached or refuses.
+   */
+  export(batch: TelemetryBatch): Promise<void>;
+}
@@ -1,4 +1,6 @@
 import { describe, it, expect } from 'vitest';
+import { readFileSync } from 'node:fs';
+import { fileURLToPath } from 'node:url';
 import {
   CoreMetadataSchema,
   ExtendedMetadataSchema,
@@ -8,8 +10,22 @@ import {
   FunnelStageSchema,
   RevenueImpactSchema,
   DataSensitivitySchema,
+  LocalizedTextSchema,
+  DependenciesSchema,
+  LinkTypeSchema,
 } from '../entity.js';
 
+function readJsonSchema(name: string): {
+  readonly properties: Record<string, unknown>;
+  readonly definitions: Record<
+    string,
+    { readonly properties: Record<string, unknown> }
+  >;
+} {
+  const url = new URL(`../../../../../schema/v1.0/${name}`, import.meta.url);
+  return JSON.parse(readFileSync(fileURLToPath(url), 'utf-8'));
+}
+
 describe('EntityTypeSchema', () => {
   it('accepts all valid entity types', () => {
     const validTypes = [
@@ -64,6 +80,14 @@ describe('LinkSchema', () => {
     expect(() => LinkSchema.parse({ url: 'not-a-url' })).toThrow();
   });
 
+  it('accepts runbook and dashboard link types', () => {
+    for (const type of ['runbook', 'dashboard'] as const) {
+      expect(LinkSchema.parse({ type, url: 'https://example.com' }).type).toBe(
+        type,
+      );
+    }
+  });
+
   it('rejects a link with invalid type', () => {
     expect(() => LinkSchema.parse({ type: 'slack', url: 'https://example.com' })).toThrow();
   });
@@ -246,4 +270,166 @@ describe('ExtendedMetadataSchema', () => {
     });
     expect(result.compliance?.regulations).toEqual([]);
   });
+
+  it('accepts cost attribution fields', () => {
+    const result = ExtendedMetadataSchema.parse({
+      type: 'module' as const,
+      description: 'Checkout module',
+      cost_center: 'CC-1042',
+      cloud_resources: [{ provider: 'aws', tag: 'app', value: 'checkout' }],
+    });
+    expect(result.cost_center).toBe('CC-1042');
+    expect(result.cloud_resources?.[0].provider).toBe('aws');
+  });
+
+  it('rejects unknown cloud providers', () => {
+    expect(() =>
+      ExtendedMetadataSchema.parse({
+        type: 'module' as const,
+        description: 'A module',
+        cloud_resources: [{ provider: 'azure', tag: 'app', value: 'x' }],
+      }),
+    ).toThrow();
+  });
+
+  it('accepts license and origin fields', () => {
+    const result = ExtendedMetadataSchema.parse({
+      type: 'module' as const,
+      description: 'Vendored date parser',
+      license: 'Apache-2.0 OR MIT',
+      origin: 'vendored',
+    });
+    expect(result.license).toBe('Apache-2.0 OR MIT');
+    expect(result.origin).toBe('vendored');
+  });
+
+  it('rejects unknown origins', () => {
+    expect(() =>
+      ExtendedMetadataSchema.parse({
+        type: 'module' as const,
+        description: 'A module',
+        origin: 'borrowed',
+      }),
+    ).toThrow();
+  });
+
+  it('accepts dependencies per environment', () => {
+    const result = ExtendedMetadataSchema.parse({
+      type: 'service' as const,
+      description: 'Session store',
+      dependencies: {
+        services: ['auth-service'],
+        environments: {
+          prod: { databases: ['postgres-main'] },
+          dev: { databases: ['sqlite'] },
+        },
+      },
+    });
+    expect(result.dependencies?.environments?.dev).toEqual({
+      databases: ['sqlite'],
+    });
+  });
+
+  it('accepts annotated dependency entries beside bare names', () => {
+    const result = ExtendedMetadataSchema.parse({
+      type: 'service' as const,
+      description: 'Checkout',
+      dependencies: {
+        services: [
+          {
+            name: 'token-service',
+            purpose: 'JWT signing',
+            protocol: 'grpc',
+            criticality: 'high',
+          },
+          'audit-service',
+        ],
+      },
+    });
+    expect(result.dependencies?.services?.[0]).toMatchObject({
+      name: 'token-service',
+      criticality: 'high',
+    });
+    for (const entry of [
+      { purpose: 'JWT signing' },
+      { name: 'token-service', criticality: 'urgent' },
+      { name: 'token-service', port: 443 },
+    ]) {
+      expect(
+        ExtendedMetadataSchema.safeParse({
+          type: 'service' as const,
+          description: 'Checkout',
+          dependencies: { services: [entry] },
+        }).success,
+      ).toBe(false);
+    }
+  });
+});
+
+describe('LocalizedTextSchema', () => {
+  it('accepts plain strings and locale maps', () => {
+    expect(LocalizedTextSchema.parse('Checkout')).toBe('Checkout');
+    expect(
+      LocalizedTextSchema.parse({ en: 'Checkout', ja: 'チェックアウト' }),
+    ).toEqual({ en: 'Checkout', ja: 'チェックアウト' });
+    expect(LocalizedTextSchema.parse({ 'pt-BR': 'Pagamento' })).toEqual({
+      'pt-BR': 'Pagamento',
+    });
+  });
+
+  it('rejects empty maps, empty translations, and malformed locales', () => {
+    expect(() => LocalizedTextSchema.parse({})).toThrow();
+    expect(() => LocalizedTextSchema.parse({ en: '' })).toThrow();
+    expect(() => LocalizedTextSchema.parse({ English: 'Checkout' })).toThrow();
+  });
+
+  it('allows localized descriptions and business goals in metadata', () => {
+    const result = ExtendedMetadataSchema.parse({
+      type: 'module' as const,
+      description: { en: 'Checkout flow', ja: 'チェックアウトの流れ' },
+      context: { business_goal: { en: 'Increase conversion' } },
+    });
+    expect(result.description).toEqual({
+      en: 'Checkout flow',
+      ja: 'チェックアウトの流れ',
+    });
+    expect(result.context?.business_goal).toEqual({
+      en: 'Increase conversion',
+    });
+  });
+});
+
+describe('extended JSON Schema', () => {
+  const schema = readJsonSchema('extended.schema.json');
+
+  it('has every annotation field', () => {
+    expect(Object.keys(schema.properties).sort()).toEqual(
+      Object.keys(ExtendedMetadataSchema.shape).sort(),
+    );
+  });
+
+  it('has every dependencies field', () => {
+    expect(Object.keys(schema.definitions.Dependencies.properties)).toEqual(
+      Object.keys(DependenciesSchema.shape),
+    );
+  });
+
+  it('describes dated owners like the Zod schema', () => {
+    expect(schema.properties.owners).toBeDefined();
+    expect(Object.keys(schema.definitions.DatedOwner.properties)).toEqual([
+      'owner',
+      'valid_from',
+      'valid_until',
+    ]);
+  });
+});
+
+describe('core JSON Schema', () => {
+  it('accepts every link type', () => {
+    const { definitions } = readJsonSchema('core.schema.json');
+    const type = definitions.Link.properties.type as {
+      readonly enum: readonly string[];
+    };
+    expect(type.enum).toEqual(LinkTypeSchema.options);
+  });
 });
@@ -0,0 +1,30 @@
+import { describe, it, expect } from 'vitest';
+import { GlossarySchema } from '../glossary.js';
+
+describe('GlossarySchema', () => {
+  it('accepts terms with definitions and aliases', () => {
+    const result = GlossarySchema.parse({
+      terms: [
+        {
+          term: 'chargeback',
+          definition: 'A payment reversal initiated by the card issuer',
+          aliases: ['dispute'],
+        },
+      ],
+    });
+    expect(result.terms[0].aliases).toEqual(['dispute']);
+  });
+
+  it('defaults aliases to an empty list', () => {
+    const result = GlossarySchema.parse({
+      terms: [{ term: 'ledger', definition: 'Book of record' }],
+    });
+    expect(result.terms[0].aliases).toEqual([]);
+  });
+
+  it('rejects terms without a definition', () => {
+    expect(() =>
+      GlossarySchema.parse({ terms: [{ term: 'ledger' }] }),
+    ).toThrow();
+  });
+});
@@ -120,4 +120,236 @@ describe('ManifestSchema', () => {
     expect(result.index?.output_dir).toBe('.knowgraph');
     expect(result.index?.incremental).toBe(true);
   });
+
+  it('accepts an i18n default locale', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      i18n: { default_locale: 'ja' },
+    });
+    expect(result.i18n?.default_locale).toBe('ja');
+    expect(ManifestSchema.parse({ version: '1.0', i18n: {} }).i18n).toEqual({
+      default_locale: 'en',
+    });
+  });
+
+  it('rejects an invalid default locale', () => {
+    expect(() =>
+      ManifestSchema.parse({ version: '1.0', i18n: { default_locale: 'EN' } }),
+    ).toThrow();
+  });
+
+  it('accepts per-phase timeouts and rejects non-positive ones', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      timeouts: { scan_ms: 60000 },
+    });
+    expect(result.timeouts).toEqual({ scan_ms: 60000 });
+    expect(() =>
+      ManifestSchema.parse({ version: '1.0', timeouts: { export_ms: 0 } }),
+    ).toThrow();
+  });
+
+  it('accepts serve auth and requires a way to verify JWTs', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      serve: {
+        auth: {
+          tokens: [{ name: 'ci', token_env: 'KG_CI_TOKEN' }],
+          jwt: { issuer: 'https://login.example.com' },
+        },
+        restricted_fields: { compliance: ['compliance'] },
+      },
+    });
+    expect(result.serve?.auth?.tokens?.[0]?.roles).toEqual([]);
+    expect(result.serve?.auth?.jwt?.roles_claim).toBe('roles');
+    expect(() =>
+      ManifestSchema.parse({
+        version: '1.0',
+        serve: { auth: { jwt: { audience: 'knowgraph' } } },
+      }),
+    ).toThrow();
+  });
+
+  it('accepts redaction profiles and needs patterns for wildcards', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      redaction: {
+        profiles: {
+          partner: { rules: [{ field: 'owner', action: 'hash' }] },
+        },
+      },
+    });
+    expect(result.redaction?.profiles?.partner?.rules).toHaveLength(1);
+    expect(() =>
+      ManifestSchema.parse({
+        version: '1.0',
+        redaction: {
+          profiles: { bad: { rules: [{ field: '*', action: 'strip' }] } },
+        },
+      }),
+    ).toThrow();
+  });
+
+  it('defaults the audit log to on, next to the index', () => {
+    const result = ManifestSchema.parse({ version: '1.0', audit: {} });
+    expect(result.audit).toEqual({
+      enabled: true,
+      path: '.knowgraph/audit.jsonl',
+    });
+  });
+
+  it('defaults the scan history with anomaly thresholds and no sinks', () => {
+    const result = ManifestSchema.parse({ version: '1.0', history: {} });
+    expect(result.history).toEqual({
+      enabled: true,
+      path: '.knowgraph/history.jsonl',
+      thresholds: {
+        dependency_spike_percent: 50,
+        ownership_churn_percent: 10,
+        status_downgrades: 5,
+        coverage_drop_points: 5,
+      },
+      retention: { daily_days: 30, weekly_days: 365 },
+      sinks: [],
+    });
+  });
+
+  it('keeps weekly scans at least as long as daily ones', () => {
+    const result = ManifestSchema.safeParse({
+      version: '1.0',
+      history: { retention: { daily_days: 60, weekly_days: 30 } },
+    });
+    expect(result.success).toBe(false);
+  });
+
+  it('defaults where scorecards are recorded', () => {
+    const result = ManifestSchema.parse({ version: '1.0', scorecards: {} });
+    expect(result.scorecards).toEqual({ path: '.knowgraph/scorecards.jsonl' });
+  });
+
+  it('requires a url or url_env for webhook and slack sinks', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      history: {
+        sinks: [
+          { type: 'slack', url_env: 'SLACK_WEBHOOK_URL' },
+          { type: 'file', path: 'alerts.jsonl' },
+        ],
+      },
+    });
+    expect(result.history?.sinks).toHaveLength(2);
+    expect(() =>
+      ManifestSchema.parse({
+        version: '1.0',
+        history: { sinks: [{ type: 'webhook' }] },
+      }),
+    ).toThrow();
+  });
+
+  it('requires recipients for email sinks', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      history: {
+        sinks: [
+          {
+            type: 'email',
+            host: 'smtp.example.com',
+            from: 'knowgraph@example.com',
+            to: ['platform@example.com'],
+          },
+        ],
+      },
+    });
+    expect(result.history?.sinks[0]).toMatchObject({ secure: false });
+    expect(() =>
+      ManifestSchema.parse({
+        version: '1.0',
+        history: {
+          sinks: [
+            {
+              type: 'email',
+              host: 'smtp.example.com',
+              from: 'knowgraph@example.com',
+              to: [],
+            },
+          ],
+        },
+      }),
+    ).toThrow();
+  });
+
+  it('requires plugins to provide something', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      plugins: [{ name: 'tf', command: ['kg-tf'], extensions: ['.tf'] }],
+    });
+    expect(result.plugins?.[0]?.extensions).toEqual(['.tf']);
+    expect(() =>
+      ManifestSchema.parse({
+        version: '1.0',
+        plugins: [{ name: 'idle', command: ['kg-idle'] }],
+      }),
+    ).toThrow();
+  });
+
+  it('orders enrichers and enables them by default', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      enrichers: [
+        { name: 'git', paths: ['src/'] },
+        { name: 'catalog', enabled: false },
+      ],
+    });
+    expect(result.enrichers).toEqual([
+      { name: 'git', enabled: true, paths: ['src/'] },
+      { name: 'catalog', enabled: false },
+    ]);
+  });
+
+  it('accepts wasm plugins for rules and enrichers only', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      plugins: [
+        { name: 'policy', wasm: 'rules.wasm', rules: true, memory_mb: 16 },
+      ],
+    });
+    expect(result.plugins?.[0]?.memory_mb).toBe(16);
+    for (const plugin of [
+      { name: 'both', command: ['kg'], wasm: 'x.wasm', rules: true },
+      { name: 'neither', rules: true },
+      { name: 'tf', wasm: 'tf.wasm', extensions: ['.tf'] },
+    ]) {
+      expect(() =>
+        ManifestSchema.parse({ version: '1.0', plugins: [plugin] }),
+      ).toThrow();
+    }
+  });
+
+  it('accepts edge rules of new kinds with either shared or path', () => {
+    const result = ManifestSchema.parse({
+      version: '1.0',
+      edge_rules: {
+        shares_datastore: {
+          shared: 'dependencies.databases',
+          from: { type: 'service' },
+          to: { type: 'service' },
+        },
+        touches_datastore: { path: ['service', 'database'], confidence: 0.8 },
+      },
+    });
+    expect(Object.keys(result.edge_rules ?? {})).toEqual([
+      'shares_datastore',
+      'touches_datastore',
+    ]);
+    for (const edgeRules of [
+      { database: { shared: 'dependencies.databases' } },
+      { shares_datastore: {} },
+      { shares_datastore: { shared: 'owner', path: ['service'] } },
+      { 'Shares-Datastore': { shared: 'owner' } },
+    ]) {
+      expect(() =>
+        ManifestSchema.parse({ version: '1.0', edge_rules: edgeRules }),
+      ).toThrow();
+    }
+  });
 });
@@ -32,6 +32,9 @@ export const LinkTypeSchema = z.enum([
   'linear',
   'confluence',
   'github',
+  'runbook',
+  'dashboard',
+  'incident',
   'custom',
 ]);
 
@@ -41,9 +44,24 @@ export const LinkSchema = z.object({
   title: z.string().optional(),
 });
 
+// BCP 47-style language tag such as `en`, `ja`, or `pt-BR`
+export const LocaleSchema = z
+  .string()
+  .regex(/^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$/, 'Invalid locale code');
+
+// Plain text, or translations keyed by locale (`{ en: ..., ja: ... }`)
+export const LocalizedTextSchema = z.union([
+  z.string().min(1),
+  z
+    .record(LocaleSchema, z.string().min(1))
+    .refine((value) => Object.keys(value).length > 0, {
+      message: 'At least one locale is required',
+    }),
+]);
+
 export const CoreMetadataSchema = z.object({
   type: EntityTypeSchema,
-  description: z.string().min(1),
+  description: LocalizedTextSchema,
   owner: z.string().optional(),
   status: StatusSchema.optional(),
   tags: z.array(z.string()).optional(),
@@ -68,15 +86,74 @@ export const RevenueImpactSchema = z.enum([
 ]);
 
 export const ContextSchema = z.object({
-  business_goal: z.string().optional(),
+  business_goal: LocalizedTextSchema.optional(),
   funnel_stage: FunnelStageSchema.optional(),
   revenue_impact: RevenueImpactSchema.optional(),
+  domain: z.string().min(1).optional(),
+});
+
+export const DependencyCriticalitySchema = z.enum([
+  'critical',
+  'high',
+  'medium',
+  'low',
+]);
+
+// A dependency by name, or with what the edge to it is for, such as
+// `{name: token-service, purpose: JWT signing, protocol: grpc}`
+export const DependencyEntrySchema = z.union([
+  z.string(),
+  z
+    .object({
+      name: z.string().min(1),
+      purpose: z.string().min(1).optional(),
+      protocol: z.string().min(1).optional(),
+      criticality: DependencyCriticalitySchema.optional(),
+    })
+    .strict(),
+]);
+
+export const DependencyListSchema = z.object({
+  services: z.array(DependencyEntrySchema).optional(),
+  external_apis: z.array(DependencyEntrySchema).optional(),
+  databases: z.array(DependencyEntrySchema).optional(),
 });
 
-export const DependenciesSchema = z.object({
-  services: z.array(z.string()).optional(),
-  external_apis: z.array(z.string()).optional(),
-  databases: z.array(z.string()).optional(),
+// When a fact holds: from `valid_from` until the day before `valid_until`
+const VALIDITY_FIELDS = {
+  valid_from: z.string().date().optional(),
+  valid_until: z.string().date().optional(),
+};
+
+function isOrderedWindow(window: {
+  readonly valid_from?: string;
+  readonly valid_until?: string;
+}): boolean {
+  return (
+    window.valid_from === undefined ||
+    window.valid_until === undefined ||
+    window.valid_from < window.valid_until
+  );
+}
+
+const WINDOW_ORDER = { message: 'valid_from must be before valid_until' };
+
+export const ValiditySchema = z
+  .object(VALIDITY_FIELDS)
+  .refine(isOrderedWindow, WINDOW_ORDER);
+
+// An owner for a stretch of time, such as until a reorg
+export const DatedOwnerSchema = z
+  .object({ owner: z.string().min(1), ...VALIDITY_FIELDS })
+  .refine(isOrderedWindow, WINDOW_ORDER);
+
+// The lists apply in every environment; `environments` adds to them in one
+export const DependenciesSchema = DependencyListSchema.extend({
+  environments: z.record(z.string().min(1), DependencyListSchema).optional(),
+  // Version ranges required of dependencies, such as `token-service: ">=2"`
+  versions: z.record(z.string().min(1), z.string().min(1)).optional(),
+  // When dependencies hold, such as a database until its migration date
+  validity: z.record(z.string().min(1), ValiditySchema).optional(),
 });
 
 export const DataSensitivitySchema = z.enum([
@@ -92,6 +169,15 @@ export const ComplianceSchema = z.object({
   audit_requirements: z.array(z.string()).optional(),
 });
 
+// One field of a type, such as a Go struct field tagged
+// `knowgraph:"sensitivity=pii"`. Sensitivity is a free label (pii, secret)
+// rather than an entity's `data_sensitivity` level
+export const FieldMetadataSchema = z.object({
+  description: z.string().optional(),
+  sensitivity: z.string().min(1).optional(),
+  tags: z.array(z.string()).optional(),
+});
+
 export const MonitoringDashboardSchema = z.object({
   type: z.string().optional(),
   url: z.string().url(),
@@ -104,11 +190,99 @@ export const OperationalSchema = z.object({
   monitoring_dashboards: z.array(MonitoringDashboardSchema).optional(),
 });
 
+export const SloSchema = z.object({
+  availability: z.number().min(0).max(100).optional(),
+  latency_ms: z.number().positive().optional(),
+  latency_percentile: z.number().min(0).max(100).optional(),
+  sli_query: z.string().optional(),
+});
+
+export const CloudProviderSchema = z.enum(['aws', 'gcp']);
+
+export const CloudResourceSchema = z.object({
+  provider: CloudProviderSchema,
+  tag: z.string().min(1),
+  value: z.string().min(1),
+});
+
+// One author of recent commits to a file, as counted by the git enricher
+export const GitContributorSchema = z.object({
+  author: z.string(),
+  commits: z.number().int().positive(),
+  last_modified: z.string(),
+});
+
+// A commit whose Knowgraph-Node trailer names the entity
+export const GitLinkedCommitSchema = z.object({
+  commit: z.string(),
+  author: z.string(),
+  date: z.string(),
+  subject: z.string(),
+});
+
+// Written by the git enricher from the file's last commit
+export const GitMetadataSchema = z.object({
+  last_commit: z.string(),
+  last_author: z.string(),
+  last_modified: z.string(),
+  contributors: z.array(GitContributorSchema).optional(),
+  recent_commits: z.number().int().nonnegative().optional(),
+  linked_commits: z.array(GitLinkedCommitSchema).optional(),
+});
+
+// An absolute URL in the entity's code, as found by the external-apis
+// enricher, with the external API or indexed entity it was joined to
+export const HttpCallSchema = z.object({
+  url: z.string().min(1),
+  api: z.string().min(1).optional(),
+  service: z.string().min(1).optional(),
+});
+
+// Where a module's code comes from, for license and provenance reviews
+export const ModuleOriginSchema = z.enum(['internal', 'vendored', 'forked']);
+
 export const ExtendedMetadataSchema = CoreMetadataSchema.extend({
   context: ContextSchema.optional(),
   dependencies: DependenciesSchema.optional(),
+  // Other names dependencies use for this entity, matched when stitching
+  aliases: z.array(z.string().min(1)).optional(),
+  // Owners for stretches of time; `owner` applies outside all of them
+  owners: z.array(DatedOwnerSchema).optional(),
   compliance: ComplianceSchema.optional(),
+  // Per-field metadata of a type, keyed by field name
+  fields: z.record(z.string().min(1), FieldMetadataSchema).optional(),
   operational: OperationalSchema.optional(),
+  slo: SloSchema.optional(),
+  cost_center: z.string().optional(),
+  cloud_resources: z.array(CloudResourceSchema).optional(),
+  decisions: z.array(z.string().min(1)).optional(),
+  generates: z.array(z.string().min(1)).optional(),
+  // SVG, PNG, or Excalidraw files of the architecture, relative to the file
+  diagrams: z.array(z.string().min(1)).optional(),
+  // OpenAPI or protobuf files of the API the entity provides, relative to
+  // the file; `knowgraph codegen` generates dependents' stubs from them
+  api_specs: z.array(z.string().min(1)).optional(),
+  // Version of the API the entity provides, checked against dependents'
+  // `dependencies.versions`
+  version: z.string().min(1).optional(),
+  // SPDX license expression, such as `MIT` or `Apache-2.0 OR MIT`
+  license: z.string().min(1).optional(),
+  origin: ModuleOriginSchema.optional(),
+  git: GitMetadataSchema.optional(),
+  // Written by the calls enricher: `path:name` of each indexed entity the
+  // code calls
+  calls: z.array(z.string().min(1)).optional(),
+  // Written by the routes enricher: `METHOD /path` of each HTTP route the
+  // code registers, with path parameters as `{name}`
+  routes: z.array(z.string().min(1)).optional(),
+  http_calls: z.array(HttpCallSchema).optional(),
+  // Written by a tool such as `knowgraph draft` and not yet reviewed
+  generated: z.boolean().optional(),
+  // Who approved a generated annotation with `knowgraph review approve`
+  reviewed_by: z.string().optional(),
+  // When a person last confirmed the annotation still holds, for review
+  // deadlines
+  last_reviewed: z.string().date().optional(),
 });
 
 // Inferred TypeScript types
@@ -116,13 +290,29 @@ export type EntityType = z.infer<typeof EntityTypeSchema>;
 export type Status = z.infer<typeof StatusSchema>;
 export type LinkType = z.infer<typeof LinkTypeSchema>;
 export type Link = z.infer<typeof LinkSchema>;
+export type Locale = z.infer<typeof LocaleSchema>;
+export type LocalizedText = z.infer<typeof LocalizedTextSchema>;
 export type CoreMetadata = z.infer<typeof CoreMetadataSchema>;
 export type FunnelStage = z.infer<typeof FunnelStageSchema>;
 export type RevenueImpact = z.infer<typeof RevenueImpactSchema>;
 export type Context = z.infer<typeof ContextSchema>;
+export type DependencyCriticality = z.infer<typeof DependencyCriticalitySchema>;
+export type DependencyEntry = z.infer<typeof DependencyEntrySchema>;
+export type DependencyList = z.infer<typeof DependencyListSchema>;
 export type Dependencies = z.infer<typeof DependenciesSchema>;
+export type Validity = z.infer<typeof ValiditySchema>;
+export type DatedOwner = z.infer<typeof DatedOwnerSchema>;
 export type DataSensitivity = z.infer<typeof DataSensitivitySchema>;
 export type Compliance = z.infer<typeof ComplianceSchema>;
+export type FieldMetadata = z.infer<typeof FieldMetadataSchema>;
 export type MonitoringDashboard = z.infer<typeof MonitoringDashboardSchema>;
 export type Operational = z.infer<typeof OperationalSchema>;
+export type Slo = z.infer<typeof SloSchema>;
+export type GitLinkedCommit = z.infer<typeof GitLinkedCommitSchema>;
+export type GitContributor = z.infer<typeof GitContributorSchema>;
+export type GitMetadata = z.infer<typeof GitMetadataSchema>;
+export type HttpCall = z.infer<typeof HttpCallSchema>;
+export type CloudProvider = z.infer<typeof CloudProviderSchema>;
+export type CloudResource = z.infer<typeof CloudResourceSchema>;
+export type ModuleOrigin = z.infer<typeof ModuleOriginSchema>;
 export type ExtendedMetadata = z.infer<typeof ExtendedMetadataSchema>;
@@ -0,0 +1,26 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Zod schema and types for glossary files defining domain terms and their aliases
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [schema, glossary, domain, zod]
+ * context:
+ *   business_goal: Validate glossary files used to link code to domain vocabulary
+ *   domain: core-types
+ */
+import { z } from 'zod';
+
+export const GlossaryTermSchema = z.object({
+  term: z.string().min(1),
+  definition: z.string().min(1),
+  aliases: z.array(z.string().min(1)).default([]),
+});
+
+export const GlossarySchema = z.object({
+  terms: z.array(GlossaryTermSchema),
+});
+
+// Inferred TypeScript types
+export type GlossaryTerm = z.infer<typeof GlossaryTermSchema>;
+export type Glossary = z.infer<typeof GlossarySchema>;
@@ -3,15 +3,31 @@ export {
   StatusSchema,
   LinkTypeSchema,
   LinkSchema,
+  LocaleSchema,
+  LocalizedTextSchema,
   CoreMetadataSchema,
   FunnelStageSchema,
   RevenueImpactSchema,
   ContextSchema,
   DependenciesSchema,
+  ValiditySchema,
+  DatedOwnerSchema,
+  DependencyCriticalitySchema,
+  DependencyEntrySchema,
+  DependencyListSchema,
   DataSensitivitySchema,
   ComplianceSchema,
+  FieldMetadataSchema,
   MonitoringDashboardSchema,
   OperationalSchema,
+  SloSchema,
+  CloudProviderSchema,
+  CloudResourceSchema,
+  ModuleOriginSchema,
+  GitContributorSchema,
+  GitLinkedCommitSchema,
+  GitMetadataSchema,
+  HttpCallSchema,
   ExtendedMetadataSchema,
 } from './entity.js';
 
@@ -20,15 +36,31 @@ export type {
   Status,
   LinkType,
   Link,
+  Locale,
+  LocalizedText,
   CoreMetadata,
   FunnelStage,
   RevenueImpact,
   Context,
   Dependencies,
+  Validity,
+  DatedOwner,
+  DependencyCriticality,
+  DependencyEntry,
+  DependencyList,
   DataSensitivity,
   Compliance,
+  FieldMetadata,
   MonitoringDashboard,
   Operational,
+  Slo,
+  CloudProvider,
+  CloudResource,
+  ModuleOrigin,
+  GitContributor,
+  GitLinkedCommit,
+  GitMetadata,
+  HttpCall,
   ExtendedMetadata,
 } from './entity.js';
 
@@ -36,6 +68,7 @@ export type {
   ParseResult,
   ParseDiagnostic,
   ParseOutput,
+  SourceRange,
 } from './parse-result.js';
 
 export {
@@ -46,8 +79,89 @@ export {
   WebhookConfigSchema,
   ConnectorsSchema,
   IndexConfigSchema,
-  ManifestSchema,
-} from './manifest.js';
+  I18nConfigSchema,
+  TimeoutsConfigSchema,
+  PruneConfigSchema,
+  PluginSchema,
+  TreeSitterGrammarSchema,
+  TreeSitterConfigSchema,
+  EnricherStepSchema,
+  EnrichmentRateLimitSchema,
+  EnrichmentConfigSchema,
+  RuntimeConfigServiceSchema,
+  RuntimeConfigSchema,
+  AnnotationSourceSchema,
+  AnnotationDefaultsSchema,
+  AnnotationsConfigSchema,
+} from './manifest-indexing.js';
+
+export {
+  ServeTokenSchema,
+  ServeJwtSchema,
+  ServeAuthSchema,
+  NamespaceSchema,
+  ServeNamespaceSchema,
+  ServeRegistrySchema,
+  InboundSourceSchema,
+  ServeWebhooksSchema,
+  ServeConfigSchema,
+} from './manifest-serve.js';
+
+export {
+  RedactionRuleSchema,
+  RedactionProfileSchema,
+  RedactionConfigSchema,
+  AuditConfigSchema,
+  EncryptionConfigSchema,
+} from './manifest-security.js';
+
+export {
+  AnomalyThresholdsSchema,
+  AlertSinkSchema,
+  HistoryConfigSchema,
+  HistoryRetentionSchema,
+  DeliveryAuthSchema,
+  DeliveryConfigSchema,
+  WarehouseSinkSchema,
+  WarehouseConfigSchema,
+  TelemetryConfigSchema,
+  LlmConfigSchema,
+} from './manifest-delivery.js';
+
+export {
+  ScorecardConfigSchema,
+  DescriptionsConfigSchema,
+  DeploymentsConfigSchema,
+  IncidentsConfigSchema,
+  OwnershipConfigSchema,
+  FreshnessConfigSchema,
+  MaturityCriterionSchema,
+  MaturityLevelSchema,
+  MaturityConfigSchema,
+  ConformanceConfigSchema,
+  CycleBudgetsSchema,
+  ConstraintsConfigSchema,
+} from './manifest-governance.js';
+
+export {
+  RenameSchema,
+  EdgeRuleEndSchema,
+  EdgeRuleKindSchema,
+  EdgeRuleSchema,
+} from './manifest-graph.js';
+
+export {
+  ConfluencePageSchema,
+  ConfluenceConfigSchema,
+  NotionDatabaseConfigSchema,
+  ModuleTemplateSchema,
+  PipelineCheckSchema,
+  PipelineStepSchema,
+  SavedQuerySchema,
+  ViewSchema,
+} from './manifest-workflows.js';
+
+export { RuleSeveritySchema, ManifestSchema } from './manifest.js';
 
 export type {
   AnnotationStyle,
@@ -57,5 +171,77 @@ export type {
   WebhookConfig,
   Connectors,
   IndexConfig,
-  Manifest,
-} from './manifest.js';
+  I18nConfig,
+  TimeoutsConfig,
+  PruneConfig,
+  PluginManifestEntry,
+  TreeSitterConfig,
+  EnricherStepConfig,
+  EnrichmentConfig,
+  RuntimeConfigService,
+  RuntimeConfig,
+  AnnotationsConfig,
+} from './manifest-indexing.js';
+
+export type {
+  ServeToken,
+  ServeJwtConfig,
+  ServeAuthConfig,
+  ServeNamespaceConfig,
+  ServeRegistryConfig,
+  InboundSourceConfig,
+  ServeWebhooksConfig,
+  ServeConfig,
+} from './manifest-serve.js';
+
+export type {
+  RedactionRuleConfig,
+  RedactionProfileConfig,
+  RedactionConfig,
+  AuditConfig,
+  EncryptionConfig,
+} from './manifest-security.js';
+
+export type {
+  AnomalyThresholdsConfig,
+  AlertSinkConfig,
+  HistoryConfig,
+  DeliveryAuthConfig,
+  DeliveryConfig,
+  WarehouseSinkConfig,
+  WarehouseConfig,
+  TelemetryConfig,
+  LlmConfig,
+} from './manifest-delivery.js';
+
+export type {
+  ScorecardConfig,
+  DescriptionsConfig,
+  DeploymentsConfig,
+  IncidentsConfig,
+  OwnershipConfig,
+  FreshnessConfig,
+  MaturityConfig,
+  ConformanceConfig,
+  CycleBudgetsConfig,
+  ConstraintsConfig,
+} from './manifest-governance.js';
+
+export type { RenameConfig, EdgeRuleConfig } from './manifest-graph.js';
+
+export type {
+  ConfluencePageConfig,
+  ConfluenceConfig,
+  NotionDatabaseConfig,
+  ModuleTemplateConfig,
+  PipelineCheck,
+  PipelineStepConfig,
+  SavedQueryConfig,
+  ViewConfig,
+} from './manifest-workflows.js';
+
+export type { RuleSeverity, Manifest } from './manifest.js';
+
+export { GlossaryTermSchema, GlossarySchema } from './glossary.js';
+
+export type { GlossaryTerm, Glossary } from './glossary.js';
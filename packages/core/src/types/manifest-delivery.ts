@@ -0,0 +1,229 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Zod schemas for the manifest sections that send data out: scan history and alert sinks, delivery retries and auth, warehouses, telemetry, and the LLM endpoint
+ * owner: knowgraph-core
+ * status: stable
+ * tags: [schema, config, manifest, delivery, alerts, warehouse, zod]
+ * context:
+ *   business_goal: Catch a misconfigured destination when the manifest loads rather than when a delivery fails
+ *   domain: core-types
+ */
+import { z } from 'zod';
+
+export const AnomalyThresholdsSchema = z.object({
+  dependency_spike_percent: z.number().positive().default(50),
+  ownership_churn_percent: z.number().positive().default(10),
+  status_downgrades: z.number().int().positive().default(5),
+  coverage_drop_points: z.number().positive().default(5),
+});
+
+export const AlertSinkSchema = z.discriminatedUnion('type', [
+  z.object({
+    type: z.literal('webhook'),
+    url: z.string().url().optional(),
+    url_env: z.string().optional(),
+    /** Go template rendering the JSON body, inline or from a file. */
+    template: z.string().optional(),
+    template_file: z.string().optional(),
+    /** Variable holding the HMAC-SHA256 signing secret. */
+    secret_env: z.string().optional(),
+  }),
+  z.object({
+    type: z.enum(['slack', 'teams']),
+    url: z.string().url().optional(),
+    url_env: z.string().optional(),
+  }),
+  z.object({ type: z.literal('file'), path: z.string() }),
+  z.object({
+    type: z.literal('email'),
+    /** SMTP server. */
+    host: z.string().min(1),
+    /** Default: 465 with `secure`, else 587; 25 for an internal relay. */
+    port: z.number().int().positive().optional(),
+    /** TLS from the start, rather than upgrading with STARTTLS. */
+    secure: z.boolean().default(false),
+    from: z.string().email(),
+    to: z.array(z.string().email()).min(1),
+    /** Variables holding the SMTP login, for servers that want one. */
+    username_env: z.string().optional(),
+    password_env: z.string().optional(),
+  }),
+]);
+
+/**
+ * How long `knowgraph compact-history` keeps scans: the last of each day
+ * for `daily_days`, then the last of each week up to `weekly_days`, then
+ * one trend aggregate per month.
+ */
+export const HistoryRetentionSchema = z
+  .object({
+    daily_days: z.number().int().nonnegative().default(30),
+    weekly_days: z.number().int().nonnegative().default(365),
+  })
+  .refine((retention) => retention.weekly_days >= retention.daily_days, {
+    message: 'weekly_days must be at least daily_days',
+  });
+
+export const HistoryConfigSchema = z.object({
+  enabled: z.boolean().default(true),
+  path: z.string().default('.knowgraph/history.jsonl'),
+  thresholds: AnomalyThresholdsSchema.default({}),
+  retention: HistoryRetentionSchema.default({}),
+  sinks: z
+    .array(AlertSinkSchema)
+    .default([])
+    .refine(
+      (sinks) =>
+        sinks.every(
+          (sink) =>
+            sink.type === 'file' ||
+            sink.type === 'email' ||
+            sink.url ||
+            sink.url_env,
+        ),
+      { message: 'webhook, slack, and teams sinks need url or url_env' },
+    )
+    .refine(
+      (sinks) =>
+        sinks.every(
+          (sink) =>
+            sink.type !== 'webhook' ||
+            sink.template === undefined ||
+            sink.template_file === undefined,
+        ),
+      { message: 'webhook sinks take template or template_file, not both' },
+    ),
+});
+
+const WarehouseTableNameSchema = z
+  .string()
+  .regex(/^[A-Za-z_][A-Za-z0-9_]*$/, 'Use letters, digits, and underscores');
+
+const WarehouseTablesSchema = {
+  nodes_table: WarehouseTableNameSchema.default('knowgraph_nodes'),
+  edges_table: WarehouseTableNameSchema.default('knowgraph_edges'),
+};
+
+/** Where each scan's node and edge tables are published. */
+export const WarehouseSinkSchema = z.discriminatedUnion('type', [
+  z.object({
+    type: z.literal('bigquery'),
+    project: z.string().min(1),
+    dataset: z.string().min(1),
+    location: z.string().optional(),
+    /** Variable holding an OAuth 2.0 access token. */
+    token_env: z.string().default('GOOGLE_OAUTH_ACCESS_TOKEN'),
+    ...WarehouseTablesSchema,
+  }),
+  z.object({
+    type: z.literal('snowflake'),
+    account: z.string().min(1),
+    database: z.string().min(1),
+    schema: z.string().min(1),
+    warehouse: z.string().optional(),
+    role: z.string().optional(),
+    token_env: z.string().default('SNOWFLAKE_TOKEN'),
+    token_type: z
+      .enum(['OAUTH', 'KEYPAIR_JWT', 'PROGRAMMATIC_ACCESS_TOKEN'])
+      .default('OAUTH'),
+    ...WarehouseTablesSchema,
+  }),
+]);
+
+export const WarehouseConfigSchema = z.object({
+  /** Tells repositories sharing the tables apart (default: `namespace`). */
+  repository: z.string().min(1).optional(),
+  timeout_ms: z.number().int().positive().default(300_000),
+  sinks: z.array(WarehouseSinkSchema).default([]),
+});
+
+/** Where knowgraph sends traces and metrics about its own runs. */
+export const TelemetryConfigSchema = z.object({
+  enabled: z.boolean().default(true),
+  /** OTLP/HTTP base URL; `OTEL_EXPORTER_OTLP_ENDPOINT` takes precedence. */
+  endpoint: z.string().url().optional(),
+  /** Request headers, each read from the named variable. */
+  headers_env: z.record(z.string(), z.string().min(1)).default({}),
+  service_name: z.string().min(1).default('knowgraph'),
+  timeout_ms: z.number().int().positive().default(10_000),
+  /** How often `knowgraph serve` sends what it has recorded. */
+  export_interval_ms: z.number().int().positive().default(60_000),
+});
+
+/** The OpenAI-compatible model `knowgraph draft` writes annotations with. */
+export const LlmConfigSchema = z.object({
+  /** API base URL including the version, such as `https://api.openai.com/v1`. */
+  base_url: z.string().url().optional(),
+  model: z.string().min(1).default('gpt-4o-mini'),
+  /** Variable holding the API key; unset for local servers such as Ollama. */
+  api_key_env: z.string().min(1).default('OPENAI_API_KEY'),
+});
+
+/**
+ * How deliveries to URLs starting with `url` authenticate. Secrets come
+ * from the named variables, and relative paths resolve against the
+ * manifest.
+ */
+export const DeliveryAuthSchema = z.discriminatedUnion('type', [
+  z.object({
+    type: z.literal('token'),
+    url: z.string().url(),
+    token_env: z.string().min(1),
+  }),
+  z.object({
+    /** OAuth 2.0 device flow: sign in once in a browser anywhere. */
+    type: z.literal('oidc'),
+    url: z.string().url(),
+    issuer: z.string().url(),
+    client_id: z.string().min(1),
+    scope: z.string().default('openid'),
+    audience: z.string().optional(),
+    /** Where tokens are kept between runs. */
+    cache: z.string().default('.knowgraph/oidc-tokens.json'),
+  }),
+  z.object({
+    /** Mutual TLS with a client certificate and key, PEM encoded. */
+    type: z.literal('mtls'),
+    url: z.string().url(),
+    cert: z.string().min(1),
+    key: z.string().min(1),
+    /** CA bundle for the server, in place of the system's. */
+    ca: z.string().optional(),
+    passphrase_env: z.string().optional(),
+  }),
+  z.object({
+    /** The workload identity of the machine or CI job. */
+    type: z.literal('cloud'),
+    url: z.string().url(),
+    source: z.enum(['gcp', 'azure', 'github-actions', 'kubernetes']),
+    audience: z.string().optional(),
+    /** The `kubernetes` token file, when not the projected default. */
+    token_file: z.string().optional(),
+  }),
+]);
+
+/** Retries and the offline queue for webhook and Slack deliveries. */
+export const DeliveryConfigSchema = z.object({
+  attempts: z.number().int().positive().default(3),
+  backoff_ms: z.number().int().nonnegative().default(500),
+  max_backoff_ms: z.number().int().positive().default(10_000),
+  queue: z
+    .object({
+      enabled: z.boolean().default(true),
+      path: z.string().default('.knowgraph/outbox.jsonl'),
+    })
+    .default({}),
+  auth: z.array(DeliveryAuthSchema).default([]),
+});
+
+// Inferred TypeScript types
+export type AnomalyThresholdsConfig = z.infer<typeof AnomalyThresholdsSchema>;
+export type AlertSinkConfig = z.infer<typeof AlertSinkSchema>;
+export type HistoryConfig = z.infer<typeof HistoryConfigSchema>;
+export type DeliveryConfig = z.infer<typeof DeliveryConfigSchema>;
+export type DeliveryAuthConfig = z.infer<typeof DeliveryAuthSchema>;
+export type WarehouseSinkConfig = z.infer<typeof WarehouseSinkSchema>;
+export type WarehouseConfig = z.infer<typeof WarehouseConfigSchema>;
+export type TelemetryConfig = z.infer<typeof TelemetryConfigSchema>;
+export type LlmConfig = z.infer<typeof LlmConfigSchema>;
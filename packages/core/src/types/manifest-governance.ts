@@ -0,0 +1,133 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Zod schemas for the manifest sections that grade the graph: scorecards, descriptions, deployments, incidents, ownership, freshness, maturity, conformance, cycles, and constraints
+ * owner: knowgraph-core
+ * status: stable
+ * tags: [schema, config, manifest, governance, scorecard, zod]
+ * context:
+ *   business_goal: Let each team tune the standards its architecture is held to in one checked file
+ *   domain: core-types
+ */
+import { z } from 'zod';
+import { EntityTypeSchema, RevenueImpactSchema } from './entity.js';
+
+/** Where `knowgraph scorecard --record` keeps its reports. */
+export const ScorecardConfigSchema = z.object({
+  path: z.string().default('.knowgraph/scorecards.jsonl'),
+});
+
+/** How much a description has to say, by criticality, in lint and scorecards. */
+export const DescriptionsConfigSchema = z.object({
+  /** Lowest passing score per revenue impact; `unset` for entities without one. */
+  thresholds: z
+    .record(
+      RevenueImpactSchema.or(z.literal('unset')),
+      z.number().int().min(0).max(100),
+    )
+    .optional(),
+});
+
+/** Where `knowgraph deployments import` keeps deployment events. */
+export const DeploymentsConfigSchema = z.object({
+  path: z.string().default('.knowgraph/deployments.jsonl'),
+});
+
+/** Where `knowgraph incidents import` keeps incidents, and how they rank. */
+export const IncidentsConfigSchema = z.object({
+  path: z.string().default('.knowgraph/incidents.jsonl'),
+  /** incident.io custom fields that name an incident's affected services. */
+  service_fields: z.array(z.string().min(1)).optional(),
+  /** Score multiplier per revenue impact; `unset` for entities without one. */
+  weights: z
+    .record(RevenueImpactSchema.or(z.literal('unset')), z.number().min(0))
+    .optional(),
+});
+
+/** Where `knowgraph vacancies` looks owners up and who it escalates to. */
+export const OwnershipConfigSchema = z.object({
+  /** The GitHub organization whose teams and members exist. */
+  github_org: z.string().min(1).optional(),
+  /** The Okta org URL, such as `https://acme.okta.com`. */
+  okta_url: z.string().url().optional(),
+  /** Owner suggested for nodes no active contributor or module owns. */
+  escalate_to: z.string().min(1).optional(),
+  /** Recent contributors listed per vacant owner. */
+  inheritors: z.number().int().positive().default(3),
+});
+
+/** How often annotations must be reviewed, per revenue impact. */
+export const FreshnessConfigSchema = z.object({
+  /** Months between reviews; `unset` for entities without an impact. */
+  deadlines: z
+    .record(
+      RevenueImpactSchema.or(z.literal('unset')),
+      z.number().int().positive(),
+    )
+    .default({ critical: 3, high: 6 }),
+  /** Days before a deadline the reminder digest lists a node. */
+  remind_days: z.number().int().nonnegative().default(30),
+});
+
+/**
+ * What a module needs to reach a maturity level: a built-in check, or
+ * `field:<path>` for an annotation field that must be set.
+ */
+export const MaturityCriterionSchema = z.union([
+  z.enum(['owner', 'runbook', 'slo', 'tests', 'fresh']),
+  z
+    .string()
+    .refine(
+      (value): value is `field:${string}` => /^field:[\w.-]+$/.test(value),
+      'use a built-in criterion or field:<path>',
+    ),
+]);
+
+export const MaturityLevelSchema = z.object({
+  name: z.string().min(1),
+  require: z.array(MaturityCriterionSchema).min(1),
+});
+
+/** The maturity rubric scorecards and reports grade modules against. */
+export const MaturityConfigSchema = z.object({
+  /** Entity types graded as modules. */
+  types: z.array(EntityTypeSchema).min(1).default(['module']),
+  /** Levels from lowest to highest; each needs the ones below it too. */
+  levels: z.array(MaturityLevelSchema).min(1).optional(),
+  /** Months an annotation stays fresh without a `freshness` deadline. */
+  fresh_within_months: z.number().int().positive().default(6),
+});
+
+/** The target architecture `knowgraph conform` compares the graph with. */
+export const ConformanceConfigSchema = z.object({
+  /** Target architecture file, relative to the manifest. */
+  target: z.string().min(1).default('architecture.yml'),
+  /** Recorded conformance, one JSON line per run, for convergence trends. */
+  path: z.string().default('.knowgraph/conformance.jsonl'),
+});
+
+/** New dependency cycles `check` allows, overall and per domain. */
+export const CycleBudgetsSchema = z.object({
+  default_budget: z.number().int().nonnegative().default(0),
+  budgets: z.record(z.string(), z.number().int().nonnegative()).default({}),
+});
+
+/** What `knowgraph stitch` checks graphs merged from several repos for. */
+export const ConstraintsConfigSchema = z.object({
+  /** Entity types whose names must be unique across the merged graph. */
+  unique_names: z.array(EntityTypeSchema).default(['service']),
+  /** Require every node to have an owner, not only at most one. */
+  require_owner: z.boolean().default(false),
+});
+
+// Inferred TypeScript types
+export type ScorecardConfig = z.infer<typeof ScorecardConfigSchema>;
+export type DescriptionsConfig = z.infer<typeof DescriptionsConfigSchema>;
+export type DeploymentsConfig = z.infer<typeof DeploymentsConfigSchema>;
+export type IncidentsConfig = z.infer<typeof IncidentsConfigSchema>;
+export type OwnershipConfig = z.infer<typeof OwnershipConfigSchema>;
+export type FreshnessConfig = z.infer<typeof FreshnessConfigSchema>;
+export type MaturityConfig = z.infer<typeof MaturityConfigSchema>;
+export type ConformanceConfig = z.infer<typeof ConformanceConfigSchema>;
+export type CycleBudgetsConfig = z.infer<typeof CycleBudgetsSchema>;
+export type ConstraintsConfig = z.infer<typeof ConstraintsConfigSchema>;
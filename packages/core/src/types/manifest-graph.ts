@@ -0,0 +1,59 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Zod schemas for the renames and derived edge rules the manifest applies when graphs are built
+ * owner: knowgraph-core
+ * status: stable
+ * tags: [schema, config, manifest, graph, edge-rules, zod]
+ * context:
+ *   business_goal: Keep the graph connected across renames and conventions the annotations do not spell out
+ *   domain: core-types
+ */
+import { z } from 'zod';
+import { BUILTIN_EDGE_KINDS } from '../graph/graph-rules.js';
+import { EntityTypeSchema } from './entity.js';
+
+export const RenameSchema = z.object({
+  from: z.string().min(1),
+  to: z.string().min(1),
+});
+
+/** The nodes at one end of a rule's edges, matching every field set. */
+export const EdgeRuleEndSchema = z
+  .object({
+    type: EntityTypeSchema.optional(),
+    owner: z.string().min(1).optional(),
+    domain: z.string().min(1).optional(),
+  })
+  .strict();
+
+/**
+ * A rule adding edges between entities that share a value of the
+ * annotation field `shared`, or between the ends of each `path` of edge
+ * kinds. Exactly one of the two is set.
+ */
+export const EdgeRuleSchema = z
+  .object({
+    description: z.string().optional(),
+    shared: z.string().min(1).optional(),
+    path: z.array(z.string().min(1)).min(1).optional(),
+    from: EdgeRuleEndSchema.optional(),
+    to: EdgeRuleEndSchema.optional(),
+    confidence: z.number().min(0).max(1).optional(),
+  })
+  .strict()
+  .refine((rule) => (rule.shared === undefined) !== (rule.path === undefined), {
+    message: 'Set one of shared or path',
+  });
+
+/** An edge kind a rule adds, other than those the graph builder adds. */
+export const EdgeRuleKindSchema = z
+  .string()
+  .regex(/^[a-z][a-z0-9_]*$/)
+  .refine((kind) => !BUILTIN_EDGE_KINDS.includes(kind), {
+    message: 'Built-in edge kinds cannot be derived',
+  });
+
+// Inferred TypeScript types
+export type RenameConfig = z.infer<typeof RenameSchema>;
+export type EdgeRuleConfig = z.infer<typeof EdgeRuleSchema>;
@@ -0,0 +1,182 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Zod schemas for the manifest sections that decide what gets indexed and how: parsers, connectors, limits, plugins, enrichers, and annotation sources
+ * owner: knowgraph-core
+ * status: stable
+ * tags: [schema, config, manifest, indexing, zod]
+ * context:
+ *   business_goal: Validate the settings every scan reads before it touches a file
+ *   domain: core-types
+ */
+import { z } from 'zod';
+import { LocaleSchema } from './entity.js';
+
+export const AnnotationStyleSchema = z.enum([
+  'jsdoc',
+  'docstring',
+  'line_comment',
+  'block_comment',
+]);
+
+export const ParserConfigSchema = z.object({
+  enabled: z.boolean().default(true),
+  extensions: z.array(z.string()).optional(),
+  annotation_style: AnnotationStyleSchema.optional(),
+});
+
+export const ConnectorConfigSchema = z.object({
+  enabled: z.boolean().default(false),
+  api_key_env: z.string().optional(),
+  workspace: z.string().optional(),
+  base_url: z.string().url().optional(),
+  project: z.string().optional(),
+  sync_interval: z.number().positive().optional(),
+});
+
+export const WebhookEventSchema = z.enum([
+  'entity.created',
+  'entity.updated',
+  'entity.deleted',
+  'index.complete',
+]);
+
+export const WebhookConfigSchema = z.object({
+  enabled: z.boolean().default(false),
+  url: z.string().url().optional(),
+  events: z.array(WebhookEventSchema).optional(),
+});
+
+export const ConnectorsSchema = z.object({
+  notion: ConnectorConfigSchema.optional(),
+  jira: ConnectorConfigSchema.optional(),
+  linear: ConnectorConfigSchema.optional(),
+  webhook: WebhookConfigSchema.optional(),
+});
+
+export const IndexConfigSchema = z.object({
+  output_dir: z.string().default('.knowgraph'),
+  incremental: z.boolean().default(true),
+  follow_symlinks: z.boolean().default(false),
+  submodules: z.boolean().default(false),
+  vendor: z.boolean().default(false),
+  max_file_bytes: z.number().int().positive().optional(),
+  max_line_length: z.number().int().positive().optional(),
+  parse_timeout_ms: z.number().int().positive().optional(),
+  tombstone_retention_days: z.number().int().nonnegative().optional(),
+});
+
+export const I18nConfigSchema = z.object({
+  default_locale: LocaleSchema.default('en'),
+});
+
+export const TimeoutsConfigSchema = z.object({
+  scan_ms: z.number().int().positive().optional(),
+  export_ms: z.number().int().positive().optional(),
+});
+
+export const PruneConfigSchema = z.object({
+  collapse_functions: z.boolean().optional(),
+  min_significance: z.number().int().nonnegative().optional(),
+  max_nodes: z.number().int().positive().optional(),
+});
+
+export const PluginSchema = z
+  .object({
+    name: z.string(),
+    command: z.array(z.string()).min(1).optional(),
+    wasm: z.string().optional(),
+    memory_mb: z.number().int().positive().max(4096).optional(),
+    extensions: z.array(z.string().startsWith('.')).optional(),
+    formats: z.array(z.string()).optional(),
+    rules: z.boolean().optional(),
+    enrich: z.boolean().optional(),
+    timeout_ms: z.number().int().positive().optional(),
+  })
+  .refine((plugin) => Boolean(plugin.command) !== Boolean(plugin.wasm), {
+    message: 'a plugin needs either command or wasm',
+  })
+  .refine(
+    (plugin) =>
+      plugin.extensions || plugin.formats || plugin.rules || plugin.enrich,
+    { message: 'a plugin needs extensions, formats, rules, or enrich' },
+  )
+  .refine((plugin) => !plugin.wasm || !(plugin.extensions || plugin.formats), {
+    message: 'wasm plugins support only rules and enrich',
+  });
+
+export const TreeSitterGrammarSchema = z.object({
+  language: z.string().min(1),
+  module: z.string().min(1),
+  export: z.string().min(1).optional(),
+  extensions: z.array(z.string().startsWith('.')).min(1),
+});
+
+export const TreeSitterConfigSchema = z.object({
+  grammars: z.array(TreeSitterGrammarSchema).default([]),
+});
+
+export const EnricherStepSchema = z.object({
+  name: z.string(),
+  enabled: z.boolean().default(true),
+  paths: z.array(z.string()).optional(),
+  batch_size: z.number().int().positive().optional(),
+  rate_limit: z.string().optional(),
+  cache_ttl_ms: z.number().int().nonnegative().optional(),
+});
+
+export const EnrichmentRateLimitSchema = z.object({
+  requests_per_minute: z.number().positive(),
+});
+
+export const EnrichmentConfigSchema = z.object({
+  rate_limits: z.record(z.string(), EnrichmentRateLimitSchema).default({}),
+});
+
+/** Helm values and manifests that configure one service at runtime. */
+export const RuntimeConfigServiceSchema = z.object({
+  service: z.string().min(1),
+  files: z.array(z.string().min(1)).min(1),
+});
+
+export const RuntimeConfigSchema = z.object({
+  services: z.array(RuntimeConfigServiceSchema).default([]),
+  /** Setting keys that are not dependencies, such as telemetry endpoints. */
+  ignore: z.array(z.string().min(1)).default([]),
+});
+
+export const AnnotationSourceSchema = z.enum([
+  'inline',
+  'sidecar',
+  'defaults',
+]);
+
+export const AnnotationDefaultsSchema = z.object({
+  paths: z.array(z.string().min(1)).min(1),
+  metadata: z.record(z.string(), z.unknown()),
+});
+
+export const AnnotationsConfigSchema = z.object({
+  /** Highest priority first; sources left out are not read. */
+  resolution: z.array(AnnotationSourceSchema).min(1).optional(),
+  defaults: z.array(AnnotationDefaultsSchema).optional(),
+});
+
+// Inferred TypeScript types
+export type AnnotationStyle = z.infer<typeof AnnotationStyleSchema>;
+export type ParserConfig = z.infer<typeof ParserConfigSchema>;
+export type ConnectorConfig = z.infer<typeof ConnectorConfigSchema>;
+export type WebhookEvent = z.infer<typeof WebhookEventSchema>;
+export type WebhookConfig = z.infer<typeof WebhookConfigSchema>;
+export type Connectors = z.infer<typeof ConnectorsSchema>;
+export type IndexConfig = z.infer<typeof IndexConfigSchema>;
+export type I18nConfig = z.infer<typeof I18nConfigSchema>;
+export type TimeoutsConfig = z.infer<typeof TimeoutsConfigSchema>;
+export type PruneConfig = z.infer<typeof PruneConfigSchema>;
+export type PluginManifestEntry = z.infer<typeof PluginSchema>;
+export type TreeSitterConfig = z.infer<typeof TreeSitterConfigSchema>;
+export type EnricherStepConfig = z.infer<typeof EnricherStepSchema>;
+export type EnrichmentConfig = z.infer<typeof EnrichmentConfigSchema>;
+export type RuntimeConfigService = z.infer<typeof RuntimeConfigServiceSchema>;
+export type RuntimeConfig = z.infer<typeof RuntimeConfigSchema>;
+export type AnnotationsConfig = z.infer<typeof AnnotationsConfigSchema>;
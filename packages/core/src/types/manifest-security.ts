@@ -0,0 +1,56 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Zod schemas for the redaction, audit, and encryption sections of the manifest
+ * owner: knowgraph-core
+ * status: stable
+ * tags: [schema, config, manifest, redaction, audit, encryption, zod]
+ * context:
+ *   business_goal: Keep the rules that protect sensitive graph data checked before anything is exported or stored
+ *   domain: core-types
+ */
+import { z } from 'zod';
+
+export const RedactionRuleSchema = z
+  .object({
+    field: z.string(),
+    action: z.enum(['strip', 'hash']),
+    pattern: z.string().optional(),
+  })
+  .refine((rule) => rule.field !== '*' || rule.pattern !== undefined, {
+    message: "a rule for field '*' needs a pattern",
+  });
+
+export const RedactionProfileSchema = z.object({
+  salt_env: z.string().optional(),
+  rules: z.array(RedactionRuleSchema),
+});
+
+export const RedactionConfigSchema = z.object({
+  profiles: z.record(z.string(), RedactionProfileSchema).optional(),
+});
+
+export const AuditConfigSchema = z.object({
+  enabled: z.boolean().default(true),
+  path: z.string().default('.knowgraph/audit.jsonl'),
+});
+
+/**
+ * Where the key that encrypts the index and graph snapshots comes from: a
+ * variable holding it, or a command printing it, such as a KMS decrypt.
+ */
+export const EncryptionConfigSchema = z
+  .object({
+    key_env: z.string().min(1).optional(),
+    key_command: z.array(z.string()).min(1).optional(),
+  })
+  .refine((encryption) => !(encryption.key_env && encryption.key_command), {
+    message: 'encryption takes key_env or key_command, not both',
+  });
+
+// Inferred TypeScript types
+export type RedactionRuleConfig = z.infer<typeof RedactionRuleSchema>;
+export type RedactionProfileConfig = z.infer<typeof RedactionProfileSchema>;
+export type RedactionConfig = z.infer<typeof RedactionConfigSchema>;
+export type AuditConfig = z.infer<typeof AuditConfigSchema>;
+export type EncryptionConfig = z.infer<typeof EncryptionConfigSchema>;
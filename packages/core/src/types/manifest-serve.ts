@@ -0,0 +1,108 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Zod schemas for the serve section of the manifest: authentication, hosted namespaces, the registry, and inbound webhooks
+ * owner: knowgraph-core
+ * status: stable
+ * tags: [schema, config, manifest, serve, auth, zod]
+ * context:
+ *   business_goal: Reject a server configuration that would expose the graph to the wrong callers
+ *   domain: core-types
+ */
+import { z } from 'zod';
+import { NAMESPACE_PATTERN } from '../namespace/namespace.js';
+
+export const ServeTokenSchema = z.object({
+  name: z.string(),
+  token_env: z.string(),
+  roles: z.array(z.string()).default([]),
+});
+
+export const ServeJwtSchema = z
+  .object({
+    issuer: z.string().url().optional(),
+    audience: z.string().optional(),
+    jwks_uri: z.string().url().optional(),
+    secret_env: z.string().optional(),
+    roles_claim: z.string().default('roles'),
+  })
+  .refine((jwt) => jwt.issuer || jwt.jwks_uri || jwt.secret_env, {
+    message: 'jwt needs an issuer, jwks_uri, or secret_env to verify tokens',
+  });
+
+export const ServeAuthSchema = z.object({
+  tokens: z.array(ServeTokenSchema).optional(),
+  jwt: ServeJwtSchema.optional(),
+});
+
+export const NamespaceSchema = z
+  .string()
+  .regex(NAMESPACE_PATTERN, 'expected org/repo segments such as acme/payments');
+
+export const ServeNamespaceSchema = z.object({
+  /** Another index to serve, with its node ids under this namespace. */
+  db: z.string().optional(),
+  /** Roles that may see the namespace; everyone may when omitted. */
+  roles: z.array(z.string()).optional(),
+  /** The index's scan history, for coverage trends; next to `db` by default. */
+  history: z.string().optional(),
+  /** The index's recorded scorecards; next to `db` by default. */
+  scorecards: z.string().optional(),
+});
+
+export const ServeRegistrySchema = z.object({
+  /** JSON file holding the registry, relative to the manifest. */
+  path: z.string().default('.knowgraph/registry.json'),
+  /** Roles that may change the registry. */
+  admin_roles: z.array(z.string()).min(1).default(['admin']),
+  /** Directory pushed graphs are kept in, relative to the manifest. */
+  graphs: z.string().default('.knowgraph/graphs'),
+});
+
+export const InboundSourceSchema = z.object({
+  /** Variable holding the HMAC-SHA256 secret the sender signs with. */
+  secret_env: z.string(),
+  /** Header carrying the `sha256=<hex>` signature. */
+  signature_header: z.string().default('X-Knowgraph-Signature-256'),
+  /** Dot path to the node id or name in the payload, such as `service.name`. */
+  node: z.string().min(1),
+  /** Fact names and the dot paths of their values in the payload. */
+  facts: z
+    .record(z.string(), z.string())
+    .refine((facts) => Object.keys(facts).length > 0, {
+      message: 'map at least one fact',
+    }),
+});
+
+export const ServeWebhooksSchema = z.object({
+  /** JSON Lines log of received facts, relative to the manifest. */
+  path: z.string().default('.knowgraph/facts.jsonl'),
+  /** Webhook senders, each served at `/webhooks/v1/<name>`. */
+  sources: z.record(z.string().regex(/^[\w-]+$/), InboundSourceSchema),
+});
+
+export const ServeConfigSchema = z.object({
+  auth: ServeAuthSchema.optional(),
+  /** Annotation or node fields that only the listed roles may see. */
+  restricted_fields: z.record(z.string(), z.array(z.string())).optional(),
+  /** Cron expression for rescanning the repository while serving. */
+  scan_schedule: z.string().optional(),
+  /** Hosted indexes and access rules, keyed by namespace or its prefix. */
+  namespaces: z
+    .record(z.union([NamespaceSchema, z.literal('*')]), ServeNamespaceSchema)
+    .optional(),
+  /** Namespaces, tokens, and policy bundles managed over the HTTP API. */
+  registry: ServeRegistrySchema.optional(),
+  /** Signed webhooks that report facts about nodes, in `--http` mode. */
+  webhooks: ServeWebhooksSchema.optional(),
+});
+
+// Inferred TypeScript types
+export type ServeToken = z.infer<typeof ServeTokenSchema>;
+export type ServeJwtConfig = z.infer<typeof ServeJwtSchema>;
+export type ServeAuthConfig = z.infer<typeof ServeAuthSchema>;
+export type ServeNamespaceConfig = z.infer<typeof ServeNamespaceSchema>;
+export type ServeRegistryConfig = z.infer<typeof ServeRegistrySchema>;
+export type InboundSourceConfig = z.infer<typeof InboundSourceSchema>;
+export type ServeWebhooksConfig = z.infer<typeof ServeWebhooksSchema>;
+export type ServeConfig = z.infer<typeof ServeConfigSchema>;
@@ -0,0 +1,137 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Zod schemas for the manifest sections behind publishing and authoring workflows: Confluence and Notion targets, module templates, pipelines, saved queries, and views
+ * owner: knowgraph-core
+ * status: stable
+ * tags: [schema, config, manifest, publish, pipelines, views, zod]
+ * context:
+ *   business_goal: Let teams share their publishing, pipeline, and query setups through the manifest
+ *   domain: core-types
+ */
+import { z } from 'zod';
+import { EntityTypeSchema } from './entity.js';
+
+/** Where one module's page goes, in `confluence.pages`. */
+export const ConfluencePageSchema = z.object({
+  space: z.string().min(1).optional(),
+  /** The page to publish under. */
+  parent_id: z.string().min(1).optional(),
+  title: z.string().min(1).optional(),
+  /** Update this page, rather than the one titled like the module. */
+  page_id: z.string().min(1).optional(),
+});
+
+/** Where `knowgraph publish confluence` puts module pages. */
+export const ConfluenceConfigSchema = z.object({
+  /** The site, such as `https://acme.atlassian.net/wiki`. */
+  base_url: z.string().url(),
+  /** Space for modules `pages` does not map; without it they are skipped. */
+  space: z.string().min(1).optional(),
+  parent_id: z.string().min(1).optional(),
+  /** Variable holding an API token, or a Data Center access token. */
+  token_env: z.string().min(1).default('CONFLUENCE_API_TOKEN'),
+  /** Variable holding the account email, for Confluence Cloud. */
+  email_env: z.string().min(1).optional(),
+  /** Pages by module directory or name. */
+  pages: z.record(z.string(), ConfluencePageSchema).default({}),
+});
+
+/** The Notion database `knowgraph publish notion` keeps in sync. */
+export const NotionDatabaseConfigSchema = z.object({
+  database_id: z.string().min(1),
+  /** Variable holding the integration token. */
+  token_env: z.string().min(1).default('NOTION_API_KEY'),
+  /** Entity types that get a row. */
+  types: z.array(EntityTypeSchema).min(1).default(['module', 'service']),
+});
+
+/** What `knowgraph new module` creates a module from. */
+export const ModuleTemplateSchema = z.object({
+  /** Where new modules go, relative to the project root. */
+  directory: z.string().min(1).default('.'),
+  /** Fields every module's annotation starts with, such as `context`. */
+  metadata: z.record(z.string(), z.unknown()).default({}),
+  /** Handlers to write skeletons for when none are given. */
+  handlers: z.array(z.string().min(1)).default([]),
+});
+
+/** The gating checks a pipeline's `check` step can run. */
+export const PipelineCheckSchema = z.enum([
+  'annotations',
+  'anomalies',
+  'licenses',
+  'versions',
+  'links',
+  'contracts',
+]);
+
+/** One step of a `knowgraph run` pipeline; each names one kind of step. */
+export const PipelineStepSchema = z.union([
+  z
+    .object({
+      scan: z.object({
+        /** What to index, relative to the manifest's directory. */
+        path: z.string().min(1).default('.'),
+        incremental: z.boolean().default(true),
+        exclude: z.array(z.string().min(1)).optional(),
+      }),
+    })
+    .strict(),
+  /** Enrichers the scan before it runs, in this order. */
+  z.object({ enrich: z.array(z.string().min(1)).min(1) }).strict(),
+  z.object({ check: z.array(PipelineCheckSchema).min(1) }).strict(),
+  /** Export formats, each written to the format's default file. */
+  z.object({ export: z.array(z.string().min(1)).min(1) }).strict(),
+  /** Alert sink types from `history.sinks` told how the run went. */
+  z
+    .object({
+      notify: z
+        .array(z.enum(['webhook', 'slack', 'teams', 'email', 'file']))
+        .min(1),
+    })
+    .strict(),
+]);
+
+/** A named search, run by `knowgraph query --saved` and served by name. */
+export const SavedQuerySchema = z.object({
+  description: z.string().optional(),
+  /** Search text, as `knowgraph query` takes it. */
+  query: z.string().min(1).optional(),
+  type: EntityTypeSchema.optional(),
+  owner: z.string().min(1).optional(),
+  /** Entities must have one of these tags. */
+  tags: z.array(z.string().min(1)).min(1).optional(),
+  limit: z.number().int().positive().optional(),
+});
+
+/**
+ * What one audience sees of the graph: the entity types, the annotation
+ * fields, and the table columns and grouping of its output.
+ */
+export const ViewSchema = z
+  .object({
+    description: z.string().optional(),
+    types: z.array(EntityTypeSchema).optional(),
+    /** Annotation fields kept, dotted for nested ones. */
+    fields: z.array(z.string().min(1)).optional(),
+    layout: z
+      .object({
+        /** Entity fields, dotted, each optionally `Header=field`. */
+        columns: z.array(z.string().min(1)).min(1).optional(),
+        group_by: z.string().min(1).optional(),
+      })
+      .strict()
+      .optional(),
+  })
+  .strict();
+
+// Inferred TypeScript types
+export type ConfluencePageConfig = z.infer<typeof ConfluencePageSchema>;
+export type ConfluenceConfig = z.infer<typeof ConfluenceConfigSchema>;
+export type NotionDatabaseConfig = z.infer<typeof NotionDatabaseConfigSchema>;
+export type ModuleTemplateConfig = z.infer<typeof ModuleTemplateSchema>;
+export type PipelineCheck = z.infer<typeof PipelineCheckSchema>;
+export type PipelineStepConfig = z.infer<typeof PipelineStepSchema>;
+export type SavedQueryConfig = z.infer<typeof SavedQuerySchema>;
+export type ViewConfig = z.infer<typeof ViewSchema>;
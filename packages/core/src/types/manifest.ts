@@ -1,7 +1,7 @@
 /**
  * @knowgraph
  * type: module
- * description: Zod schema and types for .knowgraph.yml project manifest files
+ * description: Zod schema and type for .knowgraph.yml project manifest files, assembled from the per-domain section schemas
  * owner: knowgraph-core
  * status: stable
  * tags: [schema, config, manifest, zod]
@@ -10,57 +10,67 @@
  *   domain: core-types
  */
 import { z } from 'zod';
+import {
+  ParserConfigSchema,
+  ConnectorsSchema,
+  IndexConfigSchema,
+  I18nConfigSchema,
+  TimeoutsConfigSchema,
+  PruneConfigSchema,
+  PluginSchema,
+  TreeSitterConfigSchema,
+  EnricherStepSchema,
+  EnrichmentConfigSchema,
+  RuntimeConfigSchema,
+  AnnotationsConfigSchema,
+} from './manifest-indexing.js';
+import { NamespaceSchema, ServeConfigSchema } from './manifest-serve.js';
+import {
+  RedactionConfigSchema,
+  AuditConfigSchema,
+  EncryptionConfigSchema,
+} from './manifest-security.js';
+import {
+  HistoryConfigSchema,
+  WarehouseConfigSchema,
+  TelemetryConfigSchema,
+  LlmConfigSchema,
+  DeliveryConfigSchema,
+} from './manifest-delivery.js';
+import {
+  ScorecardConfigSchema,
+  DescriptionsConfigSchema,
+  DeploymentsConfigSchema,
+  IncidentsConfigSchema,
+  OwnershipConfigSchema,
+  FreshnessConfigSchema,
+  MaturityConfigSchema,
+  ConformanceConfigSchema,
+  CycleBudgetsSchema,
+  ConstraintsConfigSchema,
+} from './manifest-governance.js';
+import {
+  RenameSchema,
+  EdgeRuleSchema,
+  EdgeRuleKindSchema,
+} from './manifest-graph.js';
+import {
+  ConfluenceConfigSchema,
+  NotionDatabaseConfigSchema,
+  ModuleTemplateSchema,
+  PipelineStepSchema,
+  SavedQuerySchema,
+  ViewSchema,
+} from './manifest-workflows.js';
 
-export const AnnotationStyleSchema = z.enum([
-  'jsdoc',
-  'docstring',
-  'line_comment',
-  'block_comment',
-]);
-
-export const ParserConfigSchema = z.object({
-  enabled: z.boolean().default(true),
-  extensions: z.array(z.string()).optional(),
-  annotation_style: AnnotationStyleSchema.optional(),
-});
-
-export const ConnectorConfigSchema = z.object({
-  enabled: z.boolean().default(false),
-  api_key_env: z.string().optional(),
-  workspace: z.string().optional(),
-  base_url: z.string().url().optional(),
-  project: z.string().optional(),
-  sync_interval: z.number().positive().optional(),
-});
-
-export const WebhookEventSchema = z.enum([
-  'entity.created',
-  'entity.updated',
-  'entity.deleted',
-  'index.complete',
-]);
-
-export const WebhookConfigSchema = z.object({
-  enabled: z.boolean().default(false),
-  url: z.string().url().optional(),
-  events: z.array(WebhookEventSchema).optional(),
-});
-
-export const ConnectorsSchema = z.object({
-  notion: ConnectorConfigSchema.optional(),
-  jira: ConnectorConfigSchema.optional(),
-  linear: ConnectorConfigSchema.optional(),
-  webhook: WebhookConfigSchema.optional(),
-});
-
-export const IndexConfigSchema = z.object({
-  output_dir: z.string().default('.knowgraph'),
-  incremental: z.boolean().default(true),
-});
+/** `warn` is spelled as in other linters' configs; `off` skips the rule. */
+export const RuleSeveritySchema = z.enum(['error', 'warn', 'info', 'off']);
 
 export const ManifestSchema = z.object({
   version: z.literal('1.0'),
   name: z.string().optional(),
+  /** The `org/repo` namespace graph node ids are exported under. */
+  namespace: NamespaceSchema.optional(),
   description: z.string().optional(),
   languages: z.array(z.string()).optional(),
   include: z.array(z.string()).default(['**/*']),
@@ -70,14 +80,56 @@ export const ManifestSchema = z.object({
   parsers: z.record(z.string(), ParserConfigSchema).optional(),
   connectors: ConnectorsSchema.optional(),
   index: IndexConfigSchema.optional(),
+  i18n: I18nConfigSchema.optional(),
+  timeouts: TimeoutsConfigSchema.optional(),
+  prune: PruneConfigSchema.optional(),
+  serve: ServeConfigSchema.optional(),
+  redaction: RedactionConfigSchema.optional(),
+  audit: AuditConfigSchema.optional(),
+  history: HistoryConfigSchema.optional(),
+  scorecards: ScorecardConfigSchema.optional(),
+  descriptions: DescriptionsConfigSchema.optional(),
+  delivery: DeliveryConfigSchema.optional(),
+  warehouse: WarehouseConfigSchema.optional(),
+  telemetry: TelemetryConfigSchema.optional(),
+  llm: LlmConfigSchema.optional(),
+  deployments: DeploymentsConfigSchema.optional(),
+  incidents: IncidentsConfigSchema.optional(),
+  ownership: OwnershipConfigSchema.optional(),
+  plugins: z.array(PluginSchema).optional(),
+  tree_sitter: TreeSitterConfigSchema.optional(),
+  enrichers: z.array(EnricherStepSchema).optional(),
+  enrichment: EnrichmentConfigSchema.optional(),
+  runtime_config: RuntimeConfigSchema.optional(),
+  rules: z.record(z.string(), RuleSeveritySchema).optional(),
+  /** Other names for services and entities, keyed by the current name. */
+  aliases: z.record(z.string(), z.array(z.string().min(1))).optional(),
+  renames: z.array(RenameSchema).optional(),
+  /** Rules deriving edges when graphs are built, keyed by the kind they add. */
+  edge_rules: z.record(EdgeRuleKindSchema, EdgeRuleSchema).optional(),
+  annotations: AnnotationsConfigSchema.optional(),
+  cycles: CycleBudgetsSchema.optional(),
+  freshness: FreshnessConfigSchema.optional(),
+  maturity: MaturityConfigSchema.optional(),
+  conformance: ConformanceConfigSchema.optional(),
+  constraints: ConstraintsConfigSchema.optional(),
+  /** Service registry file for `knowgraph stitch`, relative to the manifest. */
+  service_registry: z.string().min(1).optional(),
+  encryption: EncryptionConfigSchema.optional(),
+  confluence: ConfluenceConfigSchema.optional(),
+  notion_database: NotionDatabaseConfigSchema.optional(),
+  /** Module templates for `knowgraph new module`, keyed by name. */
+  templates: z.record(z.string(), ModuleTemplateSchema).optional(),
+  /** Steps `knowgraph run <name>` runs in order, keyed by name. */
+  pipelines: z
+    .record(z.string().regex(/^[\w-]+$/), z.array(PipelineStepSchema).min(1))
+    .optional(),
+  /** Saved queries, keyed by the name they are referenced by. */
+  queries: z.record(z.string().regex(/^[\w-]+$/), SavedQuerySchema).optional(),
+  /** View profiles by name, adding to or replacing the built-in ones. */
+  views: z.record(z.string().regex(/^[\w-]+$/), ViewSchema).optional(),
 });
 
 // Inferred TypeScript types
-export type AnnotationStyle = z.infer<typeof AnnotationStyleSchema>;
-export type ParserConfig = z.infer<typeof ParserConfigSchema>;
-export type ConnectorConfig = z.infer<typeof ConnectorConfigSchema>;
-export type WebhookEvent = z.infer<typeof WebhookEventSchema>;
-export type WebhookConfig = z.infer<typeof WebhookConfigSchema>;
-export type Connectors = z.infer<typeof ConnectorsSchema>;
-export type IndexConfig = z.infer<typeof IndexConfigSchema>;
+export type RuleSeverity = z.infer<typeof RuleSeveritySchema>;
 export type Manifest = z.infer<typeof ManifestSchema>;
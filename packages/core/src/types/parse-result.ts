@@ -11,6 +11,15 @@
  */
 import type { CoreMetadata, EntityType, ExtendedMetadata } from './entity.js';
 
+/** Where a bound entity's definition lies in its file. */
+export interface SourceRange {
+  /** UTF-8 byte offsets, the end exclusive. */
+  readonly startByte: number;
+  readonly endByte: number;
+  readonly startLine: number;
+  readonly endLine: number;
+}
+
 export interface ParseResult {
   readonly name: string;
   readonly filePath: string;
@@ -22,6 +31,8 @@ export interface ParseResult {
   readonly rawDocstring: string;
   readonly signature?: string;
   readonly parent?: string;
+  /** Set by parsers that read a syntax tree, such as tree-sitter ones. */
+  readonly range?: SourceRange;
 }
 
 export interface ParseDiagnostic {
@@ -0,0 +1,148 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  buildUsageReport,
+  createUsageState,
+  isUsageDisabledByEnv,
+  isUsageReportDue,
+  markUsageSent,
+  readUsageState,
+  recordCommandUsage,
+  sendUsageReport,
+  writeUsageState,
+} from '../usage.js';
+import type { UsageEnvironment } from '../types.js';
+
+const ENVIRONMENT: UsageEnvironment = {
+  version: '0.4.1',
+  os: 'linux',
+  arch: 'x64',
+  node: 20,
+  ci: false,
+};
+
+const START = new Date('2026-01-01T00:00:00Z');
+
+describe('usage counts', () => {
+  it('counts nothing until the user opts in', () => {
+    const state = createUsageState(START);
+    expect(state.enabled).toBe(false);
+    expect(recordCommandUsage(state, 'index')).toBe(state);
+  });
+
+  it('counts runs and failures per kind', () => {
+    let state = { ...createUsageState(START), enabled: true };
+    state = recordCommandUsage(state, 'index');
+    state = recordCommandUsage(state, 'index', 'io');
+    state = recordCommandUsage(state, 'export', 'usage');
+    state = recordCommandUsage(state, 'index', 'io');
+    expect(state.commands).toEqual({
+      index: { runs: 3, failures: { io: 2 } },
+      export: { runs: 1, failures: { usage: 1 } },
+    });
+  });
+
+  it('reports counts and the runtime, commands in name order', () => {
+    let state = { ...createUsageState(START), enabled: true };
+    state = recordCommandUsage(state, 'query');
+    state = recordCommandUsage(state, 'export');
+    const report = buildUsageReport(
+      state,
+      ENVIRONMENT,
+      new Date('2026-01-02T00:00:00Z'),
+    );
+    expect(report).toEqual({
+      schema: 1,
+      installId: state.installId,
+      ...ENVIRONMENT,
+      since: '2026-01-01T00:00:00.000Z',
+      until: '2026-01-02T00:00:00.000Z',
+      commands: [
+        { command: 'export', runs: 1, failures: {} },
+        { command: 'query', runs: 1, failures: {} },
+      ],
+    });
+  });
+
+  it('is due once a day when there is something to send', () => {
+    const enabled = { ...createUsageState(START), enabled: true };
+    const counted = recordCommandUsage(enabled, 'index');
+    const later = new Date('2026-01-02T00:00:00Z');
+    expect(isUsageReportDue(enabled, later)).toBe(false);
+    expect(isUsageReportDue(counted, new Date('2026-01-01T12:00:00Z'))).toBe(
+      false,
+    );
+    expect(isUsageReportDue(counted, later)).toBe(true);
+
+    const sent = markUsageSent(counted, later);
+    expect(sent.commands).toEqual({});
+    expect(sent.installId).toBe(counted.installId);
+    expect(sent.lastSentAt).toBe('2026-01-02T00:00:00.000Z');
+  });
+});
+
+describe('isUsageDisabledByEnv', () => {
+  it('honours DO_NOT_TRACK and KNOWGRAPH_TELEMETRY', () => {
+    expect(isUsageDisabledByEnv({})).toBe(false);
+    expect(isUsageDisabledByEnv({ DO_NOT_TRACK: '1' })).toBe(true);
+    expect(isUsageDisabledByEnv({ DO_NOT_TRACK: '0' })).toBe(false);
+    expect(isUsageDisabledByEnv({ KNOWGRAPH_TELEMETRY: 'off' })).toBe(true);
+    expect(isUsageDisabledByEnv({ KNOWGRAPH_TELEMETRY: 'FALSE' })).toBe(true);
+  });
+});
+
+describe('usage state file', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-usage-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('round-trips and starts opted out when missing or damaged', () => {
+    const path = join(dir, 'nested', 'usage.json');
+    expect(readUsageState(path).enabled).toBe(false);
+
+    const state = recordCommandUsage(
+      { ...createUsageState(START), enabled: true },
+      'index',
+    );
+    writeUsageState(path, state);
+    expect(readUsageState(path)).toEqual(state);
+
+    writeFileSync(path, '{"enabled": tru');
+    expect(readUsageState(path).enabled).toBe(false);
+  });
+});
+
+describe('sendUsageReport', () => {
+  const report = buildUsageReport(createUsageState(START), ENVIRONMENT);
+
+  it('posts the report as JSON', async () => {
+    const fetchFn = vi.fn(async () => new Response(null, { status: 204 }));
+    await sendUsageReport(report, {
+      endpoint: 'https://usage.example.com/v1',
+      fetch: fetchFn,
+    });
+    const [url, init] = fetchFn.mock.calls[0] as unknown as [
+      string,
+      RequestInit,
+    ];
+    expect(url).toBe('https://usage.example.com/v1');
+    expect(JSON.parse(String(init.body))).toEqual(report);
+  });
+
+  it('throws an io error when refused', async () => {
+    const fetchFn = vi.fn(
+      async () => new Response(null, { status: 503, statusText: 'Down' }),
+    );
+    await expect(
+      sendUsageReport(report, { endpoint: 'https://x.test', fetch: fetchFn }),
+    ).rejects.toMatchObject({ kind: 'io' });
+  });
+});
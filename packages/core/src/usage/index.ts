@@ -0,0 +1,20 @@
+export type {
+  CommandUsage,
+  UsageState,
+  UsageEnvironment,
+  UsageReportCommand,
+  UsageReport,
+  UsageSendOptions,
+} from './types.js';
+export {
+  USAGE_REPORT_INTERVAL_MS,
+  createUsageState,
+  readUsageState,
+  writeUsageState,
+  isUsageDisabledByEnv,
+  recordCommandUsage,
+  buildUsageReport,
+  isUsageReportDue,
+  markUsageSent,
+  sendUsageReport,
+} from './usage.js';
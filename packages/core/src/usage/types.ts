@@ -0,0 +1,72 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the opt-in anonymous usage counts and the report sent to the maintainers
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [usage, telemetry, privacy, types, interface]
+ * context:
+ *   business_goal: Keep the usage report small and fixed so anyone can audit what it contains
+ *   domain: telemetry
+ */
+import type { ErrorKind } from '../errors/types.js';
+
+/** How often one command ran, and how its failed runs were classified. */
+export interface CommandUsage {
+  readonly runs: number;
+  readonly failures: Readonly<Partial<Record<ErrorKind, number>>>;
+}
+
+/** What is kept on disk between runs. */
+export interface UsageState {
+  /** Counts are only recorded and sent once the user opts in. */
+  readonly enabled: boolean;
+  /** Random id telling installs apart; not derived from the machine or user. */
+  readonly installId: string;
+  /** ISO 8601 time the current counts started. */
+  readonly since: string;
+  /** ISO 8601 time of the last report sent; null before the first. */
+  readonly lastSentAt: string | null;
+  /** Keyed by command name, such as `index` or `deployments import`. */
+  readonly commands: Readonly<Record<string, CommandUsage>>;
+  /**
+   * URL reports are posted to, chosen when opting in. There is no default
+   * collector, so nothing is sent without one.
+   */
+  readonly endpoint?: string;
+}
+
+/** The runtime a report describes, coarse enough not to identify anyone. */
+export interface UsageEnvironment {
+  /** knowgraph version. */
+  readonly version: string;
+  /** `process.platform`, such as `linux`. */
+  readonly os: string;
+  readonly arch: string;
+  /** Node.js major version. */
+  readonly node: number;
+  /** Whether the runs happened in CI. */
+  readonly ci: boolean;
+}
+
+export interface UsageReportCommand {
+  readonly command: string;
+  readonly runs: number;
+  readonly failures: Readonly<Partial<Record<ErrorKind, number>>>;
+}
+
+/** Exactly what is sent: counts and the runtime, never arguments or paths. */
+export interface UsageReport extends UsageEnvironment {
+  readonly schema: 1;
+  readonly installId: string;
+  readonly since: string;
+  readonly until: string;
+  readonly commands: readonly UsageReportCommand[];
+}
+
+export interface UsageSendOptions {
+  /** URL the report is posted to as JSON. */
+  readonly endpoint: string;
+  readonly timeoutMs?: number;
+  readonly fetch?: typeof fetch;
+}
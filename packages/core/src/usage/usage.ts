@@ -0,0 +1,197 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Counts command runs and failure kinds after an explicit opt-in and builds the anonymous report sent to the maintainers
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [usage, telemetry, privacy, opt-in]
+ * context:
+ *   business_goal: Count only what was opted into and nothing that could identify a project
+ *   domain: telemetry
+ */
+import { randomUUID } from 'node:crypto';
+import {
+  existsSync,
+  mkdirSync,
+  readFileSync,
+  renameSync,
+  writeFileSync,
+} from 'node:fs';
+import { dirname } from 'node:path';
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { ErrorKind } from '../errors/types.js';
+import type {
+  UsageEnvironment,
+  UsageReport,
+  UsageSendOptions,
+  UsageState,
+} from './types.js';
+
+/** Reports are sent at most once a day. */
+export const USAGE_REPORT_INTERVAL_MS = 24 * 60 * 60 * 1000;
+
+const DEFAULT_TIMEOUT_MS = 3000;
+
+type Env = Readonly<Record<string, string | undefined>>;
+
+/** A fresh, opted-out state with a new random install id. */
+export function createUsageState(now: Date = new Date()): UsageState {
+  return {
+    enabled: false,
+    installId: randomUUID(),
+    since: now.toISOString(),
+    lastSentAt: null,
+    commands: {},
+  };
+}
+
+function isUsageState(value: unknown): value is UsageState {
+  const state = value as Partial<UsageState> | null;
+  return (
+    typeof state === 'object' &&
+    state !== null &&
+    typeof state.enabled === 'boolean' &&
+    typeof state.installId === 'string' &&
+    typeof state.since === 'string' &&
+    typeof state.commands === 'object' &&
+    state.commands !== null &&
+    (state.endpoint === undefined || typeof state.endpoint === 'string')
+  );
+}
+
+/**
+ * The state stored at `path`. A missing or unreadable file is a fresh,
+ * opted-out state, so a damaged file can only ever turn counting off.
+ */
+export function readUsageState(path: string): UsageState {
+  if (!existsSync(path)) return createUsageState();
+  try {
+    const parsed: unknown = JSON.parse(readFileSync(path, 'utf-8'));
+    return isUsageState(parsed)
+      ? { ...parsed, lastSentAt: parsed.lastSentAt ?? null }
+      : createUsageState();
+  } catch {
+    return createUsageState();
+  }
+}
+
+/** Store `state` at `path`, replacing the file only once it is complete. */
+export function writeUsageState(path: string, state: UsageState): void {
+  mkdirSync(dirname(path), { recursive: true });
+  const tempPath = `${path}.tmp`;
+  writeFileSync(tempPath, `${JSON.stringify(state, null, 2)}\n`, 'utf-8');
+  renameSync(tempPath, path);
+}
+
+/**
+ * Whether the environment turns usage reporting off regardless of the
+ * stored choice: `DO_NOT_TRACK` set to anything but `0`, or
+ * `KNOWGRAPH_TELEMETRY` set to `0`, `false`, or `off`.
+ */
+export function isUsageDisabledByEnv(env: Env = process.env): boolean {
+  const doNotTrack = env.DO_NOT_TRACK;
+  if (doNotTrack && doNotTrack !== '0') return true;
+  return ['0', 'false', 'off'].includes(
+    (env.KNOWGRAPH_TELEMETRY ?? '').toLowerCase(),
+  );
+}
+
+/**
+ * `state` with one more run of `command`, failed with `kind` when given.
+ * Unchanged while the user has not opted in.
+ */
+export function recordCommandUsage(
+  state: UsageState,
+  command: string,
+  kind?: ErrorKind,
+): UsageState {
+  if (!state.enabled) return state;
+  const previous = state.commands[command] ?? { runs: 0, failures: {} };
+  const failures = kind
+    ? { ...previous.failures, [kind]: (previous.failures[kind] ?? 0) + 1 }
+    : previous.failures;
+  return {
+    ...state,
+    commands: {
+      ...state.commands,
+      [command]: { runs: previous.runs + 1, failures },
+    },
+  };
+}
+
+/** The report `state` would send now, commands in name order. */
+export function buildUsageReport(
+  state: UsageState,
+  environment: UsageEnvironment,
+  now: Date = new Date(),
+): UsageReport {
+  return {
+    schema: 1,
+    installId: state.installId,
+    ...environment,
+    since: state.since,
+    until: now.toISOString(),
+    commands: Object.keys(state.commands)
+      .sort(compareStrings)
+      .map((command) => ({ command, ...state.commands[command] })),
+  };
+}
+
+/**
+ * Whether `state` has counts to send and the last report is at least
+ * `intervalMs` old.
+ */
+export function isUsageReportDue(
+  state: UsageState,
+  now: Date = new Date(),
+  intervalMs: number = USAGE_REPORT_INTERVAL_MS,
+): boolean {
+  if (!state.enabled || Object.keys(state.commands).length === 0) {
+    return false;
+  }
+  const last = Date.parse(state.lastSentAt ?? state.since);
+  return Number.isNaN(last) || now.getTime() - last >= intervalMs;
+}
+
+/** `state` after a report sent at `now`: counts cleared, the id kept. */
+export function markUsageSent(
+  state: UsageState,
+  now: Date = new Date(),
+): UsageState {
+  const sentAt = now.toISOString();
+  return { ...state, since: sentAt, lastSentAt: sentAt, commands: {} };
+}
+
+/**
+ * Post `report` as JSON. Throws an `io` error when the endpoint cannot be
+ * reached or refuses it.
+ */
+export async function sendUsageReport(
+  report: UsageReport,
+  options: UsageSendOptions,
+): Promise<void> {
+  const fetchFn = options.fetch ?? fetch;
+  let response: Response;
+  try {
+    response = await fetchFn(options.endpoint, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(report),
+      signal: AbortSignal.timeout(options.timeoutMs ?? DEFAULT_TIMEOUT_MS),
+    });
+  } catch (err) {
+    throw createKnowgraphError(
+      'io',
+      `Could not reach ${options.endpoint}: ` +
+        (err instanceof Error ? err.message : String(err)),
+      { cause: err },
+    );
+  }
+  if (!response.ok) {
+    throw createKnowgraphError(
+      'io',
+      `${options.endpoint} answered ${response.status} ${response.statusText}`,
+    );
+  }
+}
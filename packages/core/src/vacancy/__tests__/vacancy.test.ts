@@ -0,0 +1,242 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { GitContributor } from '../../types/entity.js';
+import {
+  fetchDirectory,
+  indexDirectory,
+  mergeDirectories,
+  parseDirectory,
+} from '../directory.js';
+import { buildVacancyReport, formatVacancyCsv } from '../vacancy-report.js';
+import type { OwnerVacancy } from '../types.js';
+
+const GITHUB_TEAMS = [
+  { id: 1, name: 'Payments', slug: 'payments-team' },
+  { id: 2, name: 'Platform', slug: 'platform' },
+];
+
+const GITHUB_MEMBERS = [{ login: 'lin', id: 7, type: 'User' }];
+
+const OKTA_USERS = [
+  {
+    id: '00u1',
+    status: 'ACTIVE',
+    profile: { login: 'lin@example.com', email: 'lin@example.com' },
+  },
+  {
+    id: '00u2',
+    status: 'DEPROVISIONED',
+    profile: { login: 'ada@example.com', email: 'ada@example.com' },
+  },
+];
+
+function makeEntity(
+  name: string,
+  owner: string | null,
+  contributors: readonly GitContributor[] = [],
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner,
+    status: null,
+    metadata: {
+      type: 'service',
+      description: `${name} service`,
+      ...(contributors.length > 0
+        ? { git: { contributors: [...contributors] } }
+        : {}),
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+function commits(
+  author: string,
+  count: number,
+  lastModified = '2024-05-01T00:00:00Z',
+): GitContributor {
+  return { author, commits: count, last_modified: lastModified };
+}
+
+const directory = mergeDirectories([
+  parseDirectory(JSON.stringify(GITHUB_TEAMS)),
+  parseDirectory(JSON.stringify(GITHUB_MEMBERS)),
+  parseDirectory(JSON.stringify(OKTA_USERS)),
+]);
+
+describe('parseDirectory', () => {
+  it('reads GitHub teams and members and Okta users', () => {
+    expect(directory.teams).toEqual([
+      'payments-team',
+      'Payments',
+      'platform',
+      'Platform',
+    ]);
+    expect(directory.users).toEqual([
+      { login: 'lin', emails: [], active: true },
+      { login: 'lin@example.com', emails: ['lin@example.com'], active: true },
+      { login: 'ada@example.com', emails: ['ada@example.com'], active: false },
+    ]);
+  });
+
+  it('rejects responses it does not recognize', () => {
+    expect(() => parseDirectory('{"teams": []}')).toThrow(
+      'Unrecognized directory export',
+    );
+    expect(() => parseDirectory('not json')).toThrow('not valid JSON');
+  });
+});
+
+describe('indexDirectory', () => {
+  it('matches owners without case, @, or org prefix', () => {
+    const index = indexDirectory(directory);
+    expect(index.status('@acme/payments-team')).toBe('active');
+    expect(index.status('@Lin')).toBe('active');
+    expect(index.status('ada@example.com')).toBe('departed');
+    expect(index.status('search-team')).toBe('unknown');
+    expect(index.user('7+lin@users.noreply.github.com')?.login).toBe('lin');
+  });
+});
+
+describe('buildVacancyReport', () => {
+  const entities = [
+    makeEntity('checkout', 'ada@example.com', [
+      commits('ada@example.com', 30),
+      commits('bo@example.com', 4),
+      commits('lin@example.com', 9, '2024-06-01T00:00:00Z'),
+    ]),
+    makeEntity('ledger', 'ada@example.com', [commits('ada@example.com', 2)]),
+    makeEntity('billing', 'payments-team', [], {
+      entityType: 'module',
+      filePath: 'src/search.ts',
+    }),
+    makeEntity('search', 'search-team', [], { filePath: 'src/search.ts' }),
+    makeEntity('legacy', 'search-team'),
+  ];
+
+  it('finds departed and missing owners with suggested inheritors', () => {
+    const report = buildVacancyReport(entities, directory, {
+      escalateTo: 'platform',
+    });
+    expect(report.vacantNodes).toBe(4);
+    expect(report.unverified).toEqual([]);
+    const [ada, search] = report.vacancies;
+    expect(ada.reason).toBe('departed');
+    expect(ada.inheritors).toEqual([
+      {
+        author: 'lin@example.com',
+        login: 'lin@example.com',
+        commits: 9,
+        lastModified: '2024-06-01T00:00:00Z',
+      },
+      {
+        author: 'bo@example.com',
+        login: null,
+        commits: 4,
+        lastModified: '2024-05-01T00:00:00Z',
+      },
+    ]);
+    const suggestions = (vacancy: OwnerVacancy) =>
+      vacancy.nodes.map((node) => [node.suggested, node.suggestedFrom]);
+    expect(suggestions(ada)).toEqual([
+      ['lin@example.com', 'contributor'],
+      ['platform', 'escalation'],
+    ]);
+    expect(search.reason).toBe('missing');
+    expect(suggestions(search)).toEqual([
+      ['platform', 'escalation'],
+      ['payments-team', 'module'],
+    ]);
+  });
+
+  it('only reports missing owners against a complete directory', () => {
+    const okta = parseDirectory(JSON.stringify(OKTA_USERS));
+    const report = buildVacancyReport(entities, okta);
+    expect(report.vacancies.map((vacancy) => vacancy.owner)).toEqual([
+      'ada@example.com',
+    ]);
+    expect(report.unverified).toEqual(['payments-team', 'search-team']);
+    expect(report.vacancies[0].nodes[1].suggested).toBeNull();
+  });
+
+  it('writes suggestions as an edit CSV', () => {
+    const report = buildVacancyReport(entities, directory);
+    expect(formatVacancyCsv(report)).toBe(
+      [
+        'node,field,value',
+        'id-checkout,owner,lin@example.com',
+        'id-search,owner,payments-team',
+        '',
+      ].join('\n'),
+    );
+  });
+});
+
+describe('fetchDirectory', () => {
+  afterEach(() => {
+    vi.unstubAllGlobals();
+  });
+
+  it('lists GitHub teams and members page by page', async () => {
+    const fetchMock = vi
+      .fn()
+      .mockResolvedValueOnce(new Response(JSON.stringify(GITHUB_TEAMS)))
+      .mockResolvedValueOnce(new Response(JSON.stringify(GITHUB_MEMBERS)));
+    vi.stubGlobal('fetch', fetchMock);
+
+    const found = await fetchDirectory('github', { token: 'gh', org: 'acme' });
+    expect(found.teams).toContain('payments-team');
+    expect(found.users.map((user) => user.login)).toEqual(['lin']);
+    expect(fetchMock.mock.calls[1][0]).toBe(
+      'https://api.github.com/orgs/acme/members?per_page=100&page=1',
+    );
+    expect(fetchMock.mock.calls[0][1].headers.Authorization).toBe('Bearer gh');
+  });
+
+  it('follows Okta links and lists deprovisioned users', async () => {
+    const next = 'https://acme.okta.com/api/v1/users?after=00u1&limit=200';
+    const fetchMock = vi
+      .fn()
+      .mockResolvedValueOnce(
+        new Response(JSON.stringify([OKTA_USERS[0]]), {
+          headers: { link: `<${next}>; rel="next"` },
+        }),
+      )
+      .mockResolvedValueOnce(new Response('[]'))
+      .mockResolvedValueOnce(new Response(JSON.stringify([OKTA_USERS[1]])));
+    vi.stubGlobal('fetch', fetchMock);
+
+    const found = await fetchDirectory('okta', {
+      token: 'okta',
+      baseUrl: 'https://acme.okta.com/',
+    });
+    expect(found.users.map((user) => user.active)).toEqual([true, false]);
+    expect(fetchMock.mock.calls[1][0]).toBe(next);
+    expect(fetchMock.mock.calls[2][0]).toBe(
+      'https://acme.okta.com/api/v1/users?limit=200&filter=status%20eq%20%22DEPROVISIONED%22',
+    );
+    expect(fetchMock.mock.calls[0][1].headers.Authorization).toBe('SSWS okta');
+  });
+
+  it('throws a usage error without the Okta org URL', async () => {
+    await expect(fetchDirectory('okta', { token: 'okta' })).rejects.toThrow(
+      'Okta org URL',
+    );
+  });
+});
@@ -0,0 +1,297 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reads the org directory of teams and people from the GitHub and Okta APIs or saved responses, and looks owners up in it
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [vacancy, ownership, directory, github, okta, api]
+ * context:
+ *   business_goal: Know which owners still exist without keeping a second list of teams and people
+ *   domain: ownership
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import type {
+  DirectoryFetchOptions,
+  DirectorySource,
+  DirectoryUser,
+  OrgDirectory,
+} from './types.js';
+
+type Json = Record<string, unknown>;
+
+const GITHUB_API = 'https://api.github.com';
+const GITHUB_PAGE_SIZE = 100;
+const OKTA_PAGE_SIZE = 200;
+
+/** Okta statuses of people who can no longer sign in. */
+const INACTIVE_OKTA_STATUSES: ReadonlySet<string> = new Set([
+  'DEPROVISIONED',
+  'SUSPENDED',
+]);
+
+const SOURCE_LABELS: Readonly<Record<DirectorySource, string>> = {
+  github: 'GitHub',
+  okta: 'Okta',
+};
+
+function object(value: unknown): Json {
+  return typeof value === 'object' && value !== null && !Array.isArray(value)
+    ? (value as Json)
+    : {};
+}
+
+function text(value: unknown): string | undefined {
+  return typeof value === 'string' && value.trim() !== ''
+    ? value.trim()
+    : undefined;
+}
+
+function defined<T>(values: readonly (T | undefined)[]): T[] {
+  return values.filter((value): value is T => value !== undefined);
+}
+
+function githubTeams(entries: readonly unknown[]): OrgDirectory {
+  return {
+    teams: entries.flatMap((entry) =>
+      defined([text(object(entry).slug), text(object(entry).name)]),
+    ),
+    users: [],
+  };
+}
+
+/** Organization members, who are active while GitHub lists them. */
+function githubMembers(entries: readonly unknown[]): OrgDirectory {
+  const users = defined(entries.map((entry) => text(object(entry).login)));
+  return {
+    teams: [],
+    users: users.map((login) => ({ login, emails: [], active: true })),
+  };
+}
+
+function oktaUsers(entries: readonly unknown[]): OrgDirectory {
+  const users: DirectoryUser[] = [];
+  for (const entry of entries) {
+    const profile = object(object(entry).profile);
+    const login = text(profile.login);
+    if (!login) continue;
+    const emails = defined([
+      text(profile.email),
+      text(profile.secondEmail),
+      login.includes('@') ? login : undefined,
+    ]);
+    const status = text(object(entry).status) ?? 'ACTIVE';
+    users.push({
+      login,
+      emails: [...new Set(emails)],
+      active: !INACTIVE_OKTA_STATUSES.has(status),
+    });
+  }
+  return { teams: [], users };
+}
+
+/** The directory a list response holds, by the shape of its entries. */
+function directoryIn(entries: readonly unknown[]): OrgDirectory | undefined {
+  if (entries.length === 0) return { teams: [], users: [] };
+  const first = object(entries[0]);
+  if ('slug' in first) return githubTeams(entries);
+  if ('profile' in first) return oktaUsers(entries);
+  if ('login' in first) return githubMembers(entries);
+  return undefined;
+}
+
+/**
+ * Parse a saved directory response: GitHub's `GET /orgs/{org}/teams` or
+ * `GET /orgs/{org}/members`, or Okta's `GET /api/v1/users`, told apart
+ * by the fields of their entries.
+ */
+export function parseDirectory(input: string): OrgDirectory {
+  let body: unknown;
+  try {
+    body = JSON.parse(input);
+  } catch {
+    throw createKnowgraphError('parse', 'Directory export is not valid JSON');
+  }
+  const directory = Array.isArray(body) ? directoryIn(body) : undefined;
+  if (!directory) {
+    throw createKnowgraphError(
+      'parse',
+      'Unrecognized directory export: expected a GitHub team or member list or an Okta user list',
+    );
+  }
+  return directory;
+}
+
+/**
+ * One directory holding every team and person of `directories`. A person
+ * listed more than once is active only while every listing says so, as
+ * the identity provider outranks a stale membership.
+ */
+export function mergeDirectories(
+  directories: readonly OrgDirectory[],
+): OrgDirectory {
+  const users = new Map<string, DirectoryUser>();
+  for (const user of directories.flatMap((directory) => directory.users)) {
+    const key = user.login.toLowerCase();
+    const seen = users.get(key);
+    users.set(
+      key,
+      seen
+        ? {
+            login: seen.login,
+            emails: [...new Set([...seen.emails, ...user.emails])],
+            active: seen.active && user.active,
+          }
+        : user,
+    );
+  }
+  return {
+    teams: [...new Set(directories.flatMap((directory) => directory.teams))],
+    users: [...users.values()],
+  };
+}
+
+/** The login in a GitHub noreply commit email, such as `123+ada@users…`. */
+export function githubNoreplyLogin(email: string): string | undefined {
+  return /^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$/i.exec(email)?.[1];
+}
+
+export type OwnerStatus = 'active' | 'departed' | 'unknown';
+
+/**
+ * Look owners and commit emails up in a directory. Names compare without
+ * case or a leading `@`, and `@org/team` owners by the team's slug.
+ */
+export interface DirectoryIndex {
+  status(owner: string): OwnerStatus;
+  user(nameOrEmail: string): DirectoryUser | undefined;
+}
+
+function keysOf(owner: string): readonly string[] {
+  const key = owner.trim().toLowerCase().replace(/^@/, '');
+  const slug = key.slice(key.lastIndexOf('/') + 1);
+  return slug === key ? [key] : [key, slug];
+}
+
+export function indexDirectory(directory: OrgDirectory): DirectoryIndex {
+  const teams = new Set(directory.teams.map((team) => team.toLowerCase()));
+  const users = new Map<string, DirectoryUser>();
+  for (const user of directory.users) {
+    for (const key of [user.login, ...user.emails]) {
+      users.set(key.toLowerCase(), user);
+    }
+  }
+  const user = (nameOrEmail: string): DirectoryUser | undefined => {
+    const keys = keysOf(nameOrEmail);
+    const noreply = githubNoreplyLogin(nameOrEmail);
+    return [...keys, ...(noreply ? [noreply.toLowerCase()] : [])]
+      .map((key) => users.get(key))
+      .find((found) => found !== undefined);
+  };
+  return {
+    status(owner) {
+      if (keysOf(owner).some((key) => teams.has(key))) return 'active';
+      const found = user(owner);
+      if (!found) return 'unknown';
+      return found.active ? 'active' : 'departed';
+    },
+    user,
+  };
+}
+
+async function getPage(
+  source: DirectorySource,
+  url: string,
+  headers: Readonly<Record<string, string>>,
+): Promise<{ entries: readonly unknown[]; next: string | undefined }> {
+  const response = await fetch(url, { headers });
+  if (!response.ok) {
+    throw createKnowgraphError(
+      'io',
+      `${SOURCE_LABELS[source]} API error: ${response.status} ${response.statusText}`,
+    );
+  }
+  const body: unknown = await response.json();
+  const link = response.headers.get('link') ?? '';
+  return {
+    entries: Array.isArray(body) ? body : [],
+    next: /<([^>]+)>;\s*rel="next"/.exec(link)?.[1],
+  };
+}
+
+async function fetchGithub(
+  options: DirectoryFetchOptions,
+): Promise<OrgDirectory> {
+  if (!options.org) {
+    throw createKnowgraphError(
+      'usage',
+      'Listing GitHub teams and members needs the organization',
+    );
+  }
+  const base = (options.baseUrl ?? GITHUB_API).replace(/\/$/, '');
+  const org = encodeURIComponent(options.org);
+  const headers = {
+    Authorization: `Bearer ${options.token}`,
+    Accept: 'application/vnd.github+json',
+  };
+  const list = async (kind: string): Promise<unknown[]> => {
+    const entries: unknown[] = [];
+    for (let page = 1; ; page++) {
+      const { entries: batch } = await getPage(
+        'github',
+        `${base}/orgs/${org}/${kind}?per_page=${GITHUB_PAGE_SIZE}&page=${page}`,
+        headers,
+      );
+      entries.push(...batch);
+      if (batch.length < GITHUB_PAGE_SIZE) return entries;
+    }
+  };
+  return mergeDirectories([
+    githubTeams(await list('teams')),
+    githubMembers(await list('members')),
+  ]);
+}
+
+/**
+ * Okta leaves deprovisioned people out of its user list unless filtered
+ * for, so they are listed separately.
+ */
+async function fetchOkta(
+  options: DirectoryFetchOptions,
+): Promise<OrgDirectory> {
+  if (!options.baseUrl) {
+    throw createKnowgraphError(
+      'usage',
+      'Listing Okta users needs the Okta org URL, such as https://acme.okta.com',
+    );
+  }
+  const base = options.baseUrl.replace(/\/$/, '');
+  const headers = {
+    Authorization: `SSWS ${options.token}`,
+    Accept: 'application/json',
+  };
+  const list = async (query: string): Promise<unknown[]> => {
+    const entries: unknown[] = [];
+    let url: string | undefined =
+      `${base}/api/v1/users?limit=${OKTA_PAGE_SIZE}${query}`;
+    while (url) {
+      const page = await getPage('okta', url, headers);
+      entries.push(...page.entries);
+      url = page.entries.length > 0 ? page.next : undefined;
+    }
+    return entries;
+  };
+  const deprovisioned =
+    `&filter=${encodeURIComponent('status eq "DEPROVISIONED"')}`;
+  return oktaUsers([...(await list('')), ...(await list(deprovisioned))]);
+}
+
+/**
+ * Fetch the directory from GitHub, the organization's teams and members,
+ * or from Okta, its users with their status, following pagination.
+ */
+export async function fetchDirectory(
+  source: DirectorySource,
+  options: DirectoryFetchOptions,
+): Promise<OrgDirectory> {
+  return source === 'github' ? fetchGithub(options) : fetchOkta(options);
+}
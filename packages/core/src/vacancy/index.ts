@@ -0,0 +1,22 @@
+export type {
+  DirectorySource,
+  DirectoryUser,
+  OrgDirectory,
+  VacancyReason,
+  Inheritor,
+  SuggestionSource,
+  VacantNode,
+  OwnerVacancy,
+  VacancyReport,
+  VacancyOptions,
+  DirectoryFetchOptions,
+} from './types.js';
+export type { DirectoryIndex, OwnerStatus } from './directory.js';
+export {
+  fetchDirectory,
+  githubNoreplyLogin,
+  indexDirectory,
+  mergeDirectories,
+  parseDirectory,
+} from './directory.js';
+export { buildVacancyReport, formatVacancyCsv } from './vacancy-report.js';
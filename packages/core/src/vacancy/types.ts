@@ -0,0 +1,99 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for the org directory of teams and people and the report of owners who no longer exist in it
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [vacancy, ownership, directory, github, okta, types, interface]
+ * context:
+ *   business_goal: Define contracts for finding code whose owner left and who should take it over
+ *   domain: ownership
+ */
+import type { EntityType } from '../types/entity.js';
+
+export type DirectorySource = 'github' | 'okta';
+
+/** A person the directory knows, by every name an owner may use. */
+export interface DirectoryUser {
+  readonly login: string;
+  readonly emails: readonly string[];
+  /** False once the identity provider suspends or deprovisions them. */
+  readonly active: boolean;
+}
+
+/** Who exists in the organization: its teams and its people. */
+export interface OrgDirectory {
+  /** Team slugs and names. */
+  readonly teams: readonly string[];
+  readonly users: readonly DirectoryUser[];
+}
+
+/**
+ * `departed` when the directory lists the owner as a person no longer
+ * active, `missing` when it has no team or person by that name.
+ */
+export type VacancyReason = 'departed' | 'missing';
+
+/** A recent contributor to a vacant owner's code. */
+export interface Inheritor {
+  /** The commit email, as the git enricher records it. */
+  readonly author: string;
+  /** Their directory login, when the directory knows the email. */
+  readonly login: string | null;
+  readonly commits: number;
+  /** ISO 8601 time of their latest commit to the code. */
+  readonly lastModified: string;
+}
+
+/**
+ * Where a suggested owner comes from: the node's own recent contributors,
+ * the owner of the module in its file, or the configured escalation owner.
+ */
+export type SuggestionSource = 'contributor' | 'module' | 'escalation';
+
+export interface VacantNode {
+  readonly entityId: string;
+  readonly name: string;
+  readonly entityType: EntityType;
+  readonly filePath: string;
+  /** Who should own it next; null when no one can be suggested. */
+  readonly suggested: string | null;
+  readonly suggestedFrom: SuggestionSource | null;
+}
+
+export interface OwnerVacancy {
+  readonly owner: string;
+  readonly reason: VacancyReason;
+  readonly nodes: readonly VacantNode[];
+  /** Active recent contributors to its nodes, most commits first. */
+  readonly inheritors: readonly Inheritor[];
+}
+
+export interface VacancyReport {
+  /** Owners with the most nodes first. */
+  readonly vacancies: readonly OwnerVacancy[];
+  readonly vacantNodes: number;
+  /**
+   * Owners the directory neither lists nor rules out, because it holds
+   * no teams or no people to compare them with.
+   */
+  readonly unverified: readonly string[];
+}
+
+export interface VacancyOptions {
+  /** Inheritors listed per vacancy. Default 3. */
+  readonly maxInheritors?: number;
+  /** Owner suggested for nodes no active contributor or module owns. */
+  readonly escalateTo?: string;
+}
+
+export interface DirectoryFetchOptions {
+  readonly token: string;
+  /** The GitHub organization whose teams and members are listed. */
+  readonly org?: string;
+  /**
+   * The API base URL: GitHub Enterprise's, or the Okta org URL such as
+   * `https://acme.okta.com`, which Okta requires.
+   */
+  readonly baseUrl?: string;
+}
@@ -0,0 +1,177 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Reports owners the org directory no longer lists, with the nodes they leave unowned and who should inherit them
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [vacancy, ownership, escalation, git, report]
+ * context:
+ *   business_goal: Suggest inheritors from who has been working on the code lately
+ *   domain: ownership
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { csvField } from '../finops/csv.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { ExtendedMetadata, GitContributor } from '../types/entity.js';
+import { indexDirectory } from './directory.js';
+import type { DirectoryIndex } from './directory.js';
+import type {
+  Inheritor,
+  OrgDirectory,
+  OwnerVacancy,
+  SuggestionSource,
+  VacancyOptions,
+  VacancyReason,
+  VacancyReport,
+  VacantNode,
+} from './types.js';
+
+function contributorsOf(entity: StoredEntity): readonly GitContributor[] {
+  return (entity.metadata as ExtendedMetadata).git?.contributors ?? [];
+}
+
+function compareInheritors(a: Inheritor, b: Inheritor): number {
+  return (
+    b.commits - a.commits ||
+    compareStrings(b.lastModified, a.lastModified) ||
+    compareStrings(a.author, b.author)
+  );
+}
+
+/**
+ * The contributors to `entities` who are still around, merged by author.
+ * Authors the directory lists as departed are left out; authors it does
+ * not know are kept, as commit emails often differ from directory ones.
+ */
+function inheritorsOf(
+  entities: readonly StoredEntity[],
+  directory: DirectoryIndex,
+): readonly Inheritor[] {
+  const byAuthor = new Map<string, Inheritor>();
+  for (const contributor of entities.flatMap(contributorsOf)) {
+    const user = directory.user(contributor.author);
+    if (user && !user.active) continue;
+    const seen = byAuthor.get(contributor.author);
+    byAuthor.set(contributor.author, {
+      author: contributor.author,
+      login: user?.login ?? null,
+      commits: (seen?.commits ?? 0) + contributor.commits,
+      lastModified:
+        seen && seen.lastModified > contributor.last_modified
+          ? seen.lastModified
+          : contributor.last_modified,
+    });
+  }
+  return [...byAuthor.values()].sort(compareInheritors);
+}
+
+/** How an owner is written for an inheritor: their login, else email. */
+function ownerName(inheritor: Inheritor): string {
+  return inheritor.login ?? inheritor.author;
+}
+
+/**
+ * Find owners of `entities` that `directory` no longer has: people it
+ * lists as suspended or deprovisioned, and names matching no team or
+ * person. For each node they own, suggest who should own it next: the
+ * node's most active remaining contributor, else the active owner of the
+ * module in its file, else `escalateTo`. Each vacancy also lists the
+ * most active remaining contributors to all of its nodes.
+ *
+ * An owner matching nothing is only reported missing when the directory
+ * holds both teams and people; otherwise it is listed as unverified.
+ */
+export function buildVacancyReport(
+  entities: readonly StoredEntity[],
+  directory: OrgDirectory,
+  options: VacancyOptions = {},
+): VacancyReport {
+  const { maxInheritors = 3, escalateTo } = options;
+  const index = indexDirectory(directory);
+  const complete = directory.teams.length > 0 && directory.users.length > 0;
+
+  const reasons = new Map<string, VacancyReason>();
+  const unverified = new Set<string>();
+  for (const owner of new Set(entities.map((entity) => entity.owner))) {
+    if (!owner) continue;
+    const status = index.status(owner);
+    if (status === 'departed') reasons.set(owner, 'departed');
+    if (status === 'unknown' && complete) reasons.set(owner, 'missing');
+    if (status === 'unknown' && !complete) unverified.add(owner);
+  }
+
+  const moduleOwners = new Map<string, string>();
+  for (const entity of entities) {
+    if (entity.entityType !== 'module' || !entity.owner) continue;
+    if (!reasons.has(entity.owner) && !unverified.has(entity.owner)) {
+      moduleOwners.set(entity.filePath, entity.owner);
+    }
+  }
+
+  const suggest = (
+    entity: StoredEntity,
+  ): Pick<VacantNode, 'suggested' | 'suggestedFrom'> => {
+    const [top] = inheritorsOf([entity], index);
+    const moduleOwner = moduleOwners.get(entity.filePath);
+    const suggestion = (
+      suggested: string | undefined,
+      suggestedFrom: SuggestionSource,
+    ) => (suggested ? { suggested, suggestedFrom } : undefined);
+    return (
+      suggestion(top && ownerName(top), 'contributor') ??
+      suggestion(moduleOwner, 'module') ??
+      suggestion(escalateTo, 'escalation') ?? {
+        suggested: null,
+        suggestedFrom: null,
+      }
+    );
+  };
+
+  const vacancies: OwnerVacancy[] = [];
+  for (const [owner, reason] of reasons) {
+    const owned = entities
+      .filter((entity) => entity.owner === owner)
+      .sort(
+        (a, b) =>
+          compareStrings(a.filePath, b.filePath) ||
+          compareStrings(a.name, b.name),
+      );
+    vacancies.push({
+      owner,
+      reason,
+      nodes: owned.map((entity) => ({
+        entityId: entity.id,
+        name: entity.name,
+        entityType: entity.entityType,
+        filePath: entity.filePath,
+        ...suggest(entity),
+      })),
+      inheritors: inheritorsOf(owned, index).slice(0, maxInheritors),
+    });
+  }
+  vacancies.sort(
+    (a, b) =>
+      b.nodes.length - a.nodes.length || compareStrings(a.owner, b.owner),
+  );
+
+  return {
+    vacancies,
+    vacantNodes: vacancies.reduce((sum, v) => sum + v.nodes.length, 0),
+    unverified: [...unverified].sort(compareStrings),
+  };
+}
+
+/**
+ * The suggested owners as an edit CSV, one `owner` row per vacant node
+ * with a suggestion, for `knowgraph edit --csv` to apply after review.
+ */
+export function formatVacancyCsv(report: VacancyReport): string {
+  const rows = report.vacancies.flatMap((vacancy) =>
+    vacancy.nodes.flatMap((node) =>
+      node.suggested
+        ? [[node.entityId, 'owner', node.suggested].map(csvField).join(',')]
+        : [],
+    ),
+  );
+  return ['node,field,value', ...rows].join('\n') + '\n';
+}
@@ -0,0 +1,9 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: A legacy module whose owner is still being decided
+ * status: experimental
+ * tags: [testing]
+ * # knowgraph:ignore owner-present reason="Ownership moves to payments in Q3"
+ */
+export const legacy = 1;
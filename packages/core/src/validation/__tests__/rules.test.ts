@@ -1,4 +1,7 @@
-import { describe, it, expect } from 'vitest';
+import { describe, it, expect, beforeAll, afterAll } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
 import type { ParseResult } from '../../types/parse-result.js';
 import {
   createRequiredFieldsRule,
@@ -7,6 +10,10 @@ import {
   createNonEmptyTagsRule,
   createOwnerPresentRule,
   createDescriptionLengthRule,
+  createSloEntityTypeRule,
+  createSuppressionReasonRule,
+  createDraftReviewedRule,
+  createDiagramFilesRule,
 } from '../rules.js';
 
 function makeParseResult(overrides: Partial<ParseResult> = {}): ParseResult {
@@ -229,4 +236,140 @@ describe('createDescriptionLengthRule', () => {
     const issues = rule.check(result);
     expect(issues).toHaveLength(0);
   });
+
+  it('checks each translation of a localized description', () => {
+    const result = makeParseResult({
+      metadata: {
+        type: 'function',
+        description: { en: 'Charges a saved card', ja: 'カード決済' },
+      },
+    });
+    const issues = rule.check(result);
+    expect(issues).toHaveLength(1);
+    expect(issues[0].message).toContain('(ja)');
+    expect(issues[0].message).toContain('5 chars');
+  });
+});
+
+describe('createSloEntityTypeRule', () => {
+  const rule = createSloEntityTypeRule();
+
+  it('has correct name and severity', () => {
+    expect(rule.name).toBe('slo-entity-type');
+    expect(rule.severity).toBe('warning');
+  });
+
+  it('returns no issues when slo is absent', () => {
+    expect(rule.check(makeParseResult())).toHaveLength(0);
+  });
+
+  it('returns no issues for slo on a service', () => {
+    const result = makeParseResult({
+      entityType: 'service',
+      metadata: {
+        type: 'service',
+        description: 'Checkout service handling orders',
+        slo: { availability: 99.9 },
+      },
+    });
+    expect(rule.check(result)).toHaveLength(0);
+  });
+
+  it('returns warning for slo on a function', () => {
+    const result = makeParseResult({
+      metadata: {
+        type: 'function',
+        description: 'Computes order totals',
+        slo: { availability: 99.9 },
+      },
+    });
+    const issues = rule.check(result);
+    expect(issues).toHaveLength(1);
+    expect(issues[0].rule).toBe('slo-entity-type');
+    expect(issues[0].message).toContain('function');
+  });
+});
+
+describe('createSuppressionReasonRule', () => {
+  const rule = createSuppressionReasonRule();
+
+  it('warns about suppressions without a reason', () => {
+    const issues = rule.check(
+      makeParseResult({
+        rawDocstring: [
+          '# knowgraph:ignore owner-present',
+          '# knowgraph:ignore valid-status reason="Migrating"',
+        ].join('\n'),
+      }),
+    );
+    expect(issues).toHaveLength(1);
+    expect(issues[0].rule).toBe('suppression-reason');
+    expect(issues[0].severity).toBe('warning');
+    expect(issues[0].message).toContain('owner-present');
+  });
+});
+
+describe('createDraftReviewedRule', () => {
+  const rule = createDraftReviewedRule();
+
+  it('returns no issues for reviewed annotations', () => {
+    expect(rule.check(makeParseResult())).toHaveLength(0);
+  });
+
+  it('warns about generated annotations', () => {
+    const issues = rule.check(
+      makeParseResult({
+        metadata: {
+          type: 'function',
+          description: 'Computes order totals',
+          generated: true,
+        },
+      }),
+    );
+    expect(issues).toHaveLength(1);
+    expect(issues[0].rule).toBe('draft-reviewed');
+    expect(issues[0].severity).toBe('warning');
+  });
+});
+
+describe('createDiagramFilesRule', () => {
+  const rule = createDiagramFilesRule();
+  let dir: string;
+
+  beforeAll(() => {
+    dir = mkdtempSync(join(tmpdir(), 'kg-diagrams-'));
+    writeFileSync(join(dir, 'flow.svg'), '<svg/>');
+  });
+
+  afterAll(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function withDiagrams(diagrams: string[]): ParseResult {
+    return makeParseResult({
+      filePath: join(dir, 'charge.ts'),
+      metadata: {
+        type: 'module',
+        description: 'Charges cards for orders',
+        diagrams,
+      },
+    });
+  }
+
+  it('has correct name and severity', () => {
+    expect(rule.name).toBe('diagram-files');
+    expect(rule.severity).toBe('error');
+  });
+
+  it('returns no issues for diagrams that exist', () => {
+    expect(rule.check(withDiagrams(['./flow.svg']))).toHaveLength(0);
+  });
+
+  it('reports missing diagrams and other formats', () => {
+    const issues = rule.check(withDiagrams(['missing.png', 'flow.pdf']));
+    expect(issues.map((issue) => issue.message)).toEqual([
+      'Diagram missing.png not found',
+      'Diagram flow.pdf is not an SVG, PNG, or Excalidraw file',
+    ]);
+  });
 });
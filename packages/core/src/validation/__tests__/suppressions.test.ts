@@ -0,0 +1,63 @@
+import { describe, it, expect } from 'vitest';
+import { applySeverities, resolveSeverity } from '../severity.js';
+import {
+  applySuppressions,
+  parseSuppressions,
+  SUPPRESSION_REASON_RULE,
+} from '../suppressions.js';
+
+describe('parseSuppressions', () => {
+  it('reads the rules and reason of each comment', () => {
+    const text = [
+      'description: Legacy checkout',
+      '# knowgraph:ignore owner-present, non-empty-tags reason="Retired in Q3"',
+      '# knowgraph:ignore short-description',
+    ].join('\n');
+    expect(parseSuppressions(text)).toEqual([
+      { rules: ['owner-present', 'non-empty-tags'], reason: 'Retired in Q3' },
+      { rules: ['short-description'], reason: null },
+    ]);
+    expect(parseSuppressions('description: nothing ignored')).toEqual([]);
+  });
+});
+
+describe('applySuppressions', () => {
+  const issues = [
+    { rule: 'owner-present' },
+    { rule: 'valid-status' },
+    { rule: SUPPRESSION_REASON_RULE },
+  ];
+
+  it('moves issues of named rules to suppressed with the reason', () => {
+    const outcome = applySuppressions(issues, () => [
+      { rules: ['owner-present', SUPPRESSION_REASON_RULE], reason: 'Legacy' },
+    ]);
+    expect(outcome.issues.map((issue) => issue.rule)).toEqual([
+      'valid-status',
+      SUPPRESSION_REASON_RULE,
+    ]);
+    expect(outcome.suppressed).toEqual([
+      { issue: { rule: 'owner-present' }, reason: 'Legacy' },
+    ]);
+  });
+});
+
+describe('severities', () => {
+  it('maps configured severities and turns rules off', () => {
+    const severities = { 'owner-present': 'info', tags: 'warn' } as const;
+    expect(resolveSeverity('owner-present', 'warning', severities)).toBe(
+      'info',
+    );
+    expect(resolveSeverity('tags', 'error', severities)).toBe('warning');
+    expect(resolveSeverity('valid-status', 'error', severities)).toBe('error');
+    expect(
+      applySeverities(
+        [
+          { rule: 'owner-present', severity: 'warning' as const },
+          { rule: 'short-description', severity: 'warning' as const },
+        ],
+        { 'short-description': 'off' },
+      ),
+    ).toEqual([{ rule: 'owner-present', severity: 'warning' }]);
+  });
+});
@@ -113,6 +113,36 @@ describe('createValidator', () => {
     expect(result.warningCount).toBe(manualWarnings);
   });
 
+  it('tracks issues that knowgraph:ignore comments suppress', () => {
+    const validator = createValidator();
+    const result = validator.validate(resolve(FIXTURES_DIR, 'suppressed.ts'));
+    expect(result.issues.filter((i) => i.rule === 'owner-present')).toEqual(
+      [],
+    );
+    expect(result.suppressed).toEqual([
+      {
+        issue: expect.objectContaining({ rule: 'owner-present' }),
+        reason: 'Ownership moves to payments in Q3',
+      },
+    ]);
+  });
+
+  it('applies configured rule severities', () => {
+    const validator = createValidator();
+    const path = resolve(FIXTURES_DIR, 'no-owner.ts');
+    const info = validator.validate(path, {
+      severities: { 'owner-present': 'info' },
+    });
+    expect(info.issues.find((i) => i.rule === 'owner-present')?.severity).toBe(
+      'info',
+    );
+    expect(info.infoCount).toBeGreaterThan(0);
+    const off = validator.validate(path, {
+      severities: { 'owner-present': 'off' },
+    });
+    expect(off.issues.some((i) => i.rule === 'owner-present')).toBe(false);
+  });
+
   it('includes filePath and line in issues', () => {
     const validator = createValidator();
     const result = validator.validate(FIXTURES_DIR);
@@ -3,6 +3,9 @@ export type {
   ValidationIssue,
   ValidationResult,
   ValidationRule,
+  Suppression,
+  SuppressedIssue,
+  SuppressionOutcome,
 } from './types.js';
 export {
   createRequiredFieldsRule,
@@ -11,7 +14,19 @@ export {
   createNonEmptyTagsRule,
   createOwnerPresentRule,
   createDescriptionLengthRule,
+  createSloEntityTypeRule,
+  createSuppressionReasonRule,
+  createDraftReviewedRule,
+  createDiagramFilesRule,
   createAllDefaultRules,
 } from './rules.js';
+export type { RuleSeverities } from './severity.js';
+export { resolveSeverity, applySeverities } from './severity.js';
+export {
+  SUPPRESSION_MARKER,
+  SUPPRESSION_REASON_RULE,
+  parseSuppressions,
+  applySuppressions,
+} from './suppressions.js';
 export type { ValidateOptions, Validator } from './validator.js';
 export { createValidator } from './validator.js';
@@ -9,15 +9,23 @@
  *   business_goal: Provide pluggable validation checks for knowgraph annotations
  *   domain: validation
  */
+import { existsSync } from 'node:fs';
+import { dirname, resolve } from 'node:path';
+import { declaredDiagrams, isDiagramPath } from '../diagrams/diagrams.js';
 import type { ParseResult } from '../types/parse-result.js';
 import { EntityTypeSchema, StatusSchema } from '../types/entity.js';
-import type { ValidationIssue, ValidationRule } from './types.js';
+import { parseSuppressions, SUPPRESSION_REASON_RULE } from './suppressions.js';
+import type {
+  ValidationIssue,
+  ValidationRule,
+  ValidationSeverity,
+} from './types.js';
 
 function createIssue(
   parseResult: ParseResult,
   rule: string,
   message: string,
-  severity: 'error' | 'warning',
+  severity: ValidationSeverity,
 ): ValidationIssue {
   return {
     filePath: parseResult.filePath,
@@ -149,12 +157,44 @@ export function createDescriptionLengthRule(): ValidationRule {
     severity: 'warning',
     check(parseResult: ParseResult): readonly ValidationIssue[] {
       const { description } = parseResult.metadata;
-      if (description && description.length < 10) {
-        return [
+      if (!description) return [];
+      // Localized descriptions are checked once per locale
+      const entries: readonly [string, string][] =
+        typeof description === 'string'
+          ? [['', description]]
+          : Object.entries(description);
+      return entries
+        .filter(([, text]) => text.length < 10)
+        .map(([locale, text]) =>
           createIssue(
             parseResult,
             'description-length',
-            `Description is too short (${description.length} chars). Minimum recommended: 10`,
+            `Description${locale ? ` (${locale})` : ''} is too short (${text.length} chars). Minimum recommended: 10`,
+            'warning',
+          ),
+        );
+    },
+  };
+}
+
+const SLO_ENTITY_TYPES: ReadonlySet<string> = new Set(['service', 'module']);
+
+export function createSloEntityTypeRule(): ValidationRule {
+  return {
+    name: 'slo-entity-type',
+    description: 'slo should only be declared on service or module entities',
+    severity: 'warning',
+    check(parseResult: ParseResult): readonly ValidationIssue[] {
+      const { metadata } = parseResult;
+      if (!('slo' in metadata) || !metadata.slo) {
+        return [];
+      }
+      if (!SLO_ENTITY_TYPES.has(metadata.type)) {
+        return [
+          createIssue(
+            parseResult,
+            'slo-entity-type',
+            `SLO declared on a ${metadata.type}. SLOs belong on service or module entities`,
             'warning',
           ),
         ];
@@ -164,6 +204,84 @@ export function createDescriptionLengthRule(): ValidationRule {
   };
 }
 
+export function createSuppressionReasonRule(): ValidationRule {
+  return {
+    name: SUPPRESSION_REASON_RULE,
+    description: 'knowgraph:ignore comments must say why with reason="..."',
+    severity: 'warning',
+    check(parseResult: ParseResult): readonly ValidationIssue[] {
+      return parseSuppressions(parseResult.rawDocstring)
+        .filter((suppression) => !suppression.reason)
+        .map((suppression) =>
+          createIssue(
+            parseResult,
+            SUPPRESSION_REASON_RULE,
+            `Suppression of ${suppression.rules.join(', ')} gives no reason="..."`,
+            'warning',
+          ),
+        );
+    },
+  };
+}
+
+export function createDraftReviewedRule(): ValidationRule {
+  return {
+    name: 'draft-reviewed',
+    description: 'generated annotations should be reviewed by a person',
+    severity: 'warning',
+    check(parseResult: ParseResult): readonly ValidationIssue[] {
+      const { metadata } = parseResult;
+      if (!('generated' in metadata) || metadata.generated !== true) {
+        return [];
+      }
+      return [
+        createIssue(
+          parseResult,
+          'draft-reviewed',
+          'Generated draft annotation awaiting review. Approve or reject it with `knowgraph review`',
+          'warning',
+        ),
+      ];
+    },
+  };
+}
+
+/**
+ * Every file in `diagrams` must be an SVG, PNG, or Excalidraw file that
+ * exists, relative to the annotated file.
+ */
+export function createDiagramFilesRule(): ValidationRule {
+  return {
+    name: 'diagram-files',
+    description: 'diagrams must name SVG, PNG, or Excalidraw files that exist',
+    severity: 'error',
+    check(parseResult: ParseResult): readonly ValidationIssue[] {
+      const dir = dirname(parseResult.filePath);
+      return declaredDiagrams(parseResult.metadata).flatMap((path) => {
+        if (!isDiagramPath(path)) {
+          return [
+            createIssue(
+              parseResult,
+              'diagram-files',
+              `Diagram ${path} is not an SVG, PNG, or Excalidraw file`,
+              'error',
+            ),
+          ];
+        }
+        if (existsSync(resolve(dir, path))) return [];
+        return [
+          createIssue(
+            parseResult,
+            'diagram-files',
+            `Diagram ${path} not found`,
+            'error',
+          ),
+        ];
+      });
+    },
+  };
+}
+
 export function createAllDefaultRules(): readonly ValidationRule[] {
   return [
     createRequiredFieldsRule(),
@@ -172,5 +290,9 @@ export function createAllDefaultRules(): readonly ValidationRule[] {
     createNonEmptyTagsRule(),
     createOwnerPresentRule(),
     createDescriptionLengthRule(),
+    createSloEntityTypeRule(),
+    createSuppressionReasonRule(),
+    createDraftReviewedRule(),
+    createDiagramFilesRule(),
   ];
 }
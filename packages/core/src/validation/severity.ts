@@ -0,0 +1,40 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Applies per-rule severities from the manifest to validation and lint issues
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [validation, lint, severity, config]
+ * context:
+ *   business_goal: Let each team decide which annotation rules block a merge and which only inform
+ *   domain: validation
+ */
+import type { RuleSeverity } from '../types/manifest.js';
+import type { ValidationSeverity } from './types.js';
+
+export type RuleSeverities = Readonly<Record<string, RuleSeverity>>;
+
+/**
+ * The severity an issue from `rule` is reported at, or undefined when the
+ * rule is turned off. Rules the config leaves out keep `severity`.
+ */
+export function resolveSeverity(
+  rule: string,
+  severity: ValidationSeverity,
+  severities: RuleSeverities = {},
+): ValidationSeverity | undefined {
+  const configured = severities[rule];
+  if (configured === 'off') return undefined;
+  if (configured === 'warn') return 'warning';
+  return configured ?? severity;
+}
+
+/** The issues at their configured severities, without those turned off. */
+export function applySeverities<
+  T extends { readonly rule: string; readonly severity: ValidationSeverity },
+>(issues: readonly T[], severities: RuleSeverities = {}): readonly T[] {
+  return issues.flatMap((issue) => {
+    const severity = resolveSeverity(issue.rule, issue.severity, severities);
+    return severity ? [{ ...issue, severity }] : [];
+  });
+}
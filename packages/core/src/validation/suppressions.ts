@@ -0,0 +1,60 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses knowgraph:ignore comments and separates the issues they suppress from the rest
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [validation, lint, suppressions]
+ * context:
+ *   business_goal: Let teams accept known exceptions in place while keeping every one of them visible
+ *   domain: validation
+ */
+import type {
+  Suppression,
+  SuppressedIssue,
+  SuppressionOutcome,
+} from './types.js';
+
+export const SUPPRESSION_MARKER = 'knowgraph:ignore';
+
+/** Reported for suppressions without a reason; never suppressible itself. */
+export const SUPPRESSION_REASON_RULE = 'suppression-reason';
+
+const SUPPRESSION_PATTERN =
+  /knowgraph:ignore\s+([\w:./-]+(?:\s*,\s*[\w:./-]+)*)(?:\s+reason\s*=\s*"([^"]*)")?/g;
+
+/**
+ * Every `knowgraph:ignore rule-a,rule-b reason="..."` comment in an
+ * annotation's text. A missing or blank reason is null.
+ */
+export function parseSuppressions(text: string): readonly Suppression[] {
+  return [...text.matchAll(SUPPRESSION_PATTERN)].map((match) => ({
+    rules: match[1].split(',').map((rule) => rule.trim()),
+    reason: match[2]?.trim() || null,
+  }));
+}
+
+/**
+ * Split `issues` into those a suppression for their annotation names, and
+ * the rest. `suppressionsFor` returns the suppressions of the annotation an
+ * issue was reported on.
+ */
+export function applySuppressions<T extends { readonly rule: string }>(
+  issues: readonly T[],
+  suppressionsFor: (issue: T) => readonly Suppression[],
+): SuppressionOutcome<T> {
+  const kept: T[] = [];
+  const suppressed: SuppressedIssue<T>[] = [];
+  for (const issue of issues) {
+    const suppression =
+      issue.rule === SUPPRESSION_REASON_RULE
+        ? undefined
+        : suppressionsFor(issue).find((s) => s.rules.includes(issue.rule));
+    if (suppression) {
+      suppressed.push({ issue, reason: suppression.reason });
+    } else {
+      kept.push(issue);
+    }
+  }
+  return { issues: kept, suppressed };
+}
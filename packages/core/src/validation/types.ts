@@ -11,7 +11,7 @@
  */
 import type { ParseResult } from '../types/parse-result.js';
 
-export type ValidationSeverity = 'error' | 'warning';
+export type ValidationSeverity = 'error' | 'warning' | 'info';
 
 export interface ValidationIssue {
   readonly filePath: string;
@@ -21,12 +21,31 @@ export interface ValidationIssue {
   readonly severity: ValidationSeverity;
 }
 
+/** A `knowgraph:ignore <rule> reason="..."` comment in an annotation. */
+export interface Suppression {
+  readonly rules: readonly string[];
+  readonly reason: string | null;
+}
+
+/** An issue a suppression comment hid, kept so exceptions stay visible. */
+export interface SuppressedIssue<T = ValidationIssue> {
+  readonly issue: T;
+  readonly reason: string | null;
+}
+
+export interface SuppressionOutcome<T> {
+  readonly issues: readonly T[];
+  readonly suppressed: readonly SuppressedIssue<T>[];
+}
+
 export interface ValidationResult {
   readonly issues: readonly ValidationIssue[];
   readonly fileCount: number;
   readonly errorCount: number;
   readonly warningCount: number;
+  readonly infoCount: number;
   readonly isValid: boolean;
+  readonly suppressed: readonly SuppressedIssue[];
 }
 
 export interface ValidationRule {
@@ -14,10 +14,14 @@ import { join, extname } from 'node:path';
 import { createDefaultRegistry } from '../parsers/registry.js';
 import type { ParseResult } from '../types/parse-result.js';
 import type {
+  SuppressedIssue,
   ValidationIssue,
   ValidationResult,
   ValidationRule,
 } from './types.js';
+import type { RuleSeverities } from './severity.js';
+import { applySeverities } from './severity.js';
+import { applySuppressions, parseSuppressions } from './suppressions.js';
 import { createAllDefaultRules } from './rules.js';
 
 const PARSABLE_EXTENSIONS = new Set(['.py', '.ts', '.tsx', '.js', '.jsx']);
@@ -36,9 +40,11 @@ const SKIP_DIRS = new Set([
 export interface ValidateOptions {
   readonly strict?: boolean;
   readonly ruleName?: string;
+  /** Per-rule severities from the manifest's `rules`. */
+  readonly severities?: RuleSeverities;
 }
 
-function collectFiles(targetPath: string): readonly string[] {
+export function collectFiles(targetPath: string): readonly string[] {
   const stat = statSync(targetPath);
   if (stat.isFile()) {
     return [targetPath];
@@ -71,6 +77,7 @@ function collectFiles(targetPath: string): readonly string[] {
 function buildResult(
   issues: readonly ValidationIssue[],
   fileCount: number,
+  suppressed: readonly SuppressedIssue[] = [],
 ): ValidationResult {
   const errorCount = issues.filter((i) => i.severity === 'error').length;
   const warningCount = issues.filter((i) => i.severity === 'warning').length;
@@ -79,7 +86,9 @@ function buildResult(
     fileCount,
     errorCount,
     warningCount,
+    infoCount: issues.length - errorCount - warningCount,
     isValid: errorCount === 0,
+    suppressed,
   };
 }
 
@@ -105,6 +114,7 @@ export function createValidator(
       const registry = createDefaultRegistry();
       const files = collectFiles(rootDir);
       const allIssues: ValidationIssue[] = [];
+      const suppressed: SuppressedIssue[] = [];
       let annotatedFileCount = 0;
 
       for (const filePath of files) {
@@ -131,14 +141,18 @@ export function createValidator(
         annotatedFileCount++;
 
         for (const parseResult of results) {
-          for (const rule of activeRules) {
-            const issues = rule.check(parseResult);
-            allIssues.push(...issues);
-          }
+          const issues = applySeverities(
+            activeRules.flatMap((rule) => rule.check(parseResult)),
+            options?.severities,
+          );
+          const suppressions = parseSuppressions(parseResult.rawDocstring);
+          const outcome = applySuppressions(issues, () => suppressions);
+          allIssues.push(...outcome.issues);
+          suppressed.push(...outcome.suppressed);
         }
       }
 
-      return buildResult(allIssues, annotatedFileCount);
+      return buildResult(allIssues, annotatedFileCount, suppressed);
     },
   };
 }
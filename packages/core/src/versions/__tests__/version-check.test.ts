@@ -0,0 +1,76 @@
+import { describe, it, expect } from 'vitest';
+import { checkDependencyVersions } from '../version-check.js';
+import type {
+  DependencyGraph,
+  GraphEdge,
+  GraphNode,
+} from '../../graph/types.js';
+
+function node(id: string, version?: string): GraphNode {
+  return {
+    id,
+    name: id,
+    entityType: 'service',
+    external: false,
+    filePath: `${id}.ts`,
+    owner: null,
+    domain: null,
+    workspace: null,
+    ...(version ? { version } : {}),
+  };
+}
+
+function edge(from: string, to: string, requires?: string): GraphEdge {
+  return {
+    from,
+    to,
+    kind: 'service',
+    provenance: 'declared',
+    confidence: 1,
+    ...(requires ? { requires } : {}),
+  };
+}
+
+describe('checkDependencyVersions', () => {
+  const graph: DependencyGraph = {
+    nodes: [
+      node('checkout'),
+      node('login'),
+      node('token-service', '1.8.0'),
+      node('billing', '3.2.0'),
+      node('ledger'),
+    ],
+    edges: [
+      edge('checkout', 'token-service', '>=2'),
+      edge('login', 'token-service', '^1.4'),
+      edge('checkout', 'billing', '>=3'),
+      edge('checkout', 'ledger', '>=1'),
+      edge('login', 'billing'),
+    ],
+  };
+
+  it('checks each required range against the provider version', () => {
+    const report = checkDependencyVersions(graph);
+    expect(
+      report.requirements.map((r) => [r.from.id, r.to.id, r.status]),
+    ).toEqual([
+      ['checkout', 'token-service', 'incompatible'],
+      ['checkout', 'ledger', 'unknown'],
+      ['checkout', 'billing', 'compatible'],
+      ['login', 'token-service', 'compatible'],
+    ]);
+    expect(report).toMatchObject({ incompatible: 1, unknown: 1, invalid: 0 });
+  });
+
+  it('reports ranges that cannot be read as invalid', () => {
+    const report = checkDependencyVersions({
+      nodes: graph.nodes,
+      edges: [edge('login', 'billing', 'newest')],
+    });
+    expect(report.requirements[0]).toMatchObject({
+      requires: 'newest',
+      version: '3.2.0',
+      status: 'invalid',
+    });
+  });
+});
@@ -0,0 +1,62 @@
+import { describe, it, expect } from 'vitest';
+import {
+  isVersionRange,
+  parseVersion,
+  satisfiesVersion,
+} from '../version-range.js';
+
+describe('satisfiesVersion', () => {
+  it('reads comparators, partial versions, and alternatives', () => {
+    for (const [version, range, expected] of [
+      ['2.0.0', '>=2', true],
+      ['1.9.9', '>=2', false],
+      ['3.0.0', '>2', true],
+      ['2.9.0', '>2', false],
+      ['2.1.0', '<=2.1', true],
+      ['2.2.0', '<=2.1', false],
+      ['2.4.1', '2', true],
+      ['2.4.1', '2.x', true],
+      ['3.0.0', '2.x', false],
+      ['2.4.1', '=2.4.1', true],
+      ['1.9.0', '^1.4', true],
+      ['2.0.0', '^1.4', false],
+      ['0.2.9', '^0.2.3', true],
+      ['0.3.0', '^0.2.3', false],
+      ['2.1.9', '~2.1', true],
+      ['2.2.0', '~2.1', false],
+      ['1.5.0', '>=1.2 <2', true],
+      ['3.1.0', '1.x || >=3', true],
+      ['2.5.0', '1.x || >=3', false],
+      ['2.0.0-beta.1', '>=2', false],
+      ['v2.1', '>= 2.1', true],
+      ['5.0.0', '*', true],
+    ] as const) {
+      expect(satisfiesVersion(version, range)).toBe(expected);
+    }
+  });
+
+  it('is undefined when the version or range cannot be read', () => {
+    expect(satisfiesVersion('latest', '>=2')).toBeUndefined();
+    expect(satisfiesVersion('2.0.0', '>=two')).toBeUndefined();
+    expect(satisfiesVersion('2.0.0', '')).toBeUndefined();
+  });
+});
+
+describe('parseVersion', () => {
+  it('fills missing parts with 0 and keeps the prerelease', () => {
+    expect(parseVersion('v2.1')).toEqual({
+      parts: [2, 1, 0],
+      prerelease: null,
+    });
+    expect(parseVersion('2.1.3-rc.1')?.prerelease).toBe('rc.1');
+    expect(parseVersion('2.x')).toBeUndefined();
+  });
+});
+
+describe('isVersionRange', () => {
+  it('accepts ranges and rejects other text', () => {
+    expect(isVersionRange('^1.2 || ~2.0')).toBe(true);
+    expect(isVersionRange('>=2 <3')).toBe(true);
+    expect(isVersionRange('newest')).toBe(false);
+  });
+});
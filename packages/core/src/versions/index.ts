@@ -0,0 +1,13 @@
+export type {
+  ParsedVersion,
+  VersionReport,
+  VersionRequirement,
+  VersionStatus,
+} from './types.js';
+export {
+  compareVersions,
+  isVersionRange,
+  parseVersion,
+  satisfiesVersion,
+} from './version-range.js';
+export { checkDependencyVersions } from './version-check.js';
@@ -0,0 +1,51 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for checking the API versions dependencies require against the versions their providers declare
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [versions, compatibility, dependencies, types, interface]
+ * context:
+ *   business_goal: Let CI report version mismatches between services as structured findings
+ *   domain: graph
+ */
+import type { DependencyKind, GraphNode } from '../graph/types.js';
+
+/** A version with missing parts as 0, and its prerelease tag, if any. */
+export interface ParsedVersion {
+  readonly parts: readonly [number, number, number];
+  readonly prerelease: string | null;
+}
+
+/**
+ * `compatible` when the provider's version is in the range, `incompatible`
+ * when it is not, `unknown` when the provider declares no version, and
+ * `invalid` when the range or the version cannot be read.
+ */
+export type VersionStatus =
+  | 'compatible'
+  | 'incompatible'
+  | 'unknown'
+  | 'invalid';
+
+/** One edge whose dependent requires a version range of its target. */
+export interface VersionRequirement {
+  /** The dependent. */
+  readonly from: GraphNode;
+  /** The provider. */
+  readonly to: GraphNode;
+  readonly kind: DependencyKind;
+  /** The range required, such as `>=2`. */
+  readonly requires: string;
+  /** The version the provider declares, or `null` when it declares none. */
+  readonly version: string | null;
+  readonly status: VersionStatus;
+}
+
+export interface VersionReport {
+  /** Every requirement, problems first, then by dependent and provider. */
+  readonly requirements: readonly VersionRequirement[];
+  readonly incompatible: number;
+  readonly unknown: number;
+  readonly invalid: number;
+}
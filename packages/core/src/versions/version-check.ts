@@ -0,0 +1,75 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Checks the version ranges dependents require against the versions providers declare, across one graph or a stitched one
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [versions, compatibility, dependencies, graph, stitch]
+ * context:
+ *   business_goal: Check version compatibility across repositories as well as within one
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type { DependencyGraph } from '../graph/types.js';
+import type {
+  VersionReport,
+  VersionRequirement,
+  VersionStatus,
+} from './types.js';
+import { satisfiesVersion } from './version-range.js';
+
+const STATUS_ORDER: Readonly<Record<VersionStatus, number>> = {
+  incompatible: 0,
+  invalid: 1,
+  unknown: 2,
+  compatible: 3,
+};
+
+function statusOf(version: string | null, requires: string): VersionStatus {
+  if (version === null) return 'unknown';
+  const satisfied = satisfiesVersion(version, requires);
+  if (satisfied === undefined) return 'invalid';
+  return satisfied ? 'compatible' : 'incompatible';
+}
+
+/**
+ * Check every edge whose dependent requires a version range of its target
+ * (from `dependencies.versions`) against the `version` the target
+ * declares. Run on a graph stitched from several repositories, stubs that
+ * resolved to a provider elsewhere are checked against its version;
+ * those still external have none and are `unknown`.
+ */
+export function checkDependencyVersions(graph: DependencyGraph): VersionReport {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const requirements: VersionRequirement[] = [];
+  for (const edge of graph.edges) {
+    if (!edge.requires) continue;
+    const from = nodes.get(edge.from);
+    const to = nodes.get(edge.to);
+    if (!from || !to) continue;
+    const version = to.version ?? null;
+    requirements.push({
+      from,
+      to,
+      kind: edge.kind,
+      requires: edge.requires,
+      version,
+      status: statusOf(version, edge.requires),
+    });
+  }
+  requirements.sort(
+    (a, b) =>
+      STATUS_ORDER[a.status] - STATUS_ORDER[b.status] ||
+      compareStrings(a.from.id, b.from.id) ||
+      compareStrings(a.to.id, b.to.id),
+  );
+  const count = (status: VersionStatus) =>
+    requirements.filter((requirement) => requirement.status === status)
+      .length;
+  return {
+    requirements,
+    incompatible: count('incompatible'),
+    unknown: count('unknown'),
+    invalid: count('invalid'),
+  };
+}
@@ -0,0 +1,198 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Parses semver-style versions and ranges such as >=2, ^1.4, and ~2.1 || 3.x and tests whether a version is in a range
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [versions, semver, ranges, compatibility]
+ * context:
+ *   business_goal: Read the version ranges teams already write in package manifests without a new syntax
+ *   domain: graph
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import type { ParsedVersion } from './types.js';
+
+/**
+ * A version with how many leading parts are numbers, for partial ranges:
+ * `2.x` and `2` have one.
+ */
+interface PartialVersion extends ParsedVersion {
+  readonly given: number;
+  /** Whether a part is `x` or `*`. */
+  readonly wildcard: boolean;
+}
+
+type Operator = '<' | '<=' | '>' | '>=';
+
+interface Comparator {
+  readonly operator: Operator;
+  readonly version: ParsedVersion;
+}
+
+const VERSION_PATTERN =
+  /^v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$/;
+
+const COMPARATOR_PATTERN = /^(<=|>=|<|>|=|\^|~)?\s*(.+)$/;
+
+function parsePartial(text: string): PartialVersion | undefined {
+  const match = VERSION_PATTERN.exec(text.trim());
+  if (!match) return undefined;
+  const written = match.slice(1, 4).filter((part) => part !== undefined);
+  const wildcard = written.findIndex((part) => !/^\d+$/.test(part));
+  const given = wildcard === -1 ? written.length : wildcard;
+  const parts = [0, 1, 2].map((i) =>
+    i < given ? Number(written[i]) : 0,
+  ) as [number, number, number];
+  return {
+    parts,
+    prerelease: match[4] ?? null,
+    given,
+    wildcard: wildcard !== -1,
+  };
+}
+
+/** Parse `2`, `2.1`, `v2.1.3`, or `2.1.3-beta.1` into a version. */
+export function parseVersion(text: string): ParsedVersion | undefined {
+  const version = parsePartial(text);
+  if (!version || version.wildcard) return undefined;
+  return toVersion(version);
+}
+
+/** Order versions as semver does: a prerelease sorts before its release. */
+export function compareVersions(a: ParsedVersion, b: ParsedVersion): number {
+  for (let i = 0; i < 3; i++) {
+    if (a.parts[i] !== b.parts[i]) return a.parts[i] - b.parts[i];
+  }
+  if (a.prerelease === b.prerelease) return 0;
+  if (a.prerelease === null) return 1;
+  if (b.prerelease === null) return -1;
+  return compareStrings(a.prerelease, b.prerelease);
+}
+
+/**
+ * The first version past every one that shares `version`'s parts up to
+ * `index`: bumping `2.1` at 0 gives `3.0.0`, the upper end of `^2.1`.
+ */
+function bump(version: ParsedVersion, index: number): ParsedVersion {
+  const parts = version.parts.map((part, i) =>
+    i < index ? part : i === index ? part + 1 : 0,
+  ) as [number, number, number];
+  return { parts, prerelease: null };
+}
+
+function toVersion(version: PartialVersion): ParsedVersion {
+  return { parts: version.parts, prerelease: version.prerelease };
+}
+
+/** The comparators one range term stands for, or undefined if unreadable. */
+function parseTerm(term: string): readonly Comparator[] | undefined {
+  const match = COMPARATOR_PATTERN.exec(term);
+  if (!match) return undefined;
+  const [, operator = '=', rest] = match;
+  const version = parsePartial(rest);
+  if (!version) return undefined;
+  const { given } = version;
+  if (given === 0) return operator === '<' || operator === '>' ? undefined : [];
+  const low = toVersion(version);
+  const next = bump(version, given - 1);
+  switch (operator) {
+    case '>=':
+      return [{ operator: '>=', version: low }];
+    case '<':
+      return [{ operator: '<', version: low }];
+    case '>':
+      return given === 3
+        ? [{ operator: '>', version: low }]
+        : [{ operator: '>=', version: next }];
+    case '<=':
+      return given === 3
+        ? [{ operator: '<=', version: low }]
+        : [{ operator: '<', version: next }];
+    case '^': {
+      const major = version.parts.findIndex((part) => part !== 0);
+      const index = major === -1 || major >= given ? given - 1 : major;
+      return [
+        { operator: '>=', version: low },
+        { operator: '<', version: bump(version, index) },
+      ];
+    }
+    case '~':
+      return [
+        { operator: '>=', version: low },
+        { operator: '<', version: bump(version, given === 1 ? 0 : 1) },
+      ];
+    default:
+      return given === 3
+        ? [
+            { operator: '>=', version: low },
+            { operator: '<=', version: low },
+          ]
+        : [
+            { operator: '>=', version: low },
+            { operator: '<', version: next },
+          ];
+  }
+}
+
+/**
+ * Parse a range: comparators separated by spaces must all hold, and sets
+ * separated by `||` are alternatives. Comparators are `>=2`, `>2.1`, `<3`,
+ * `<=2.4.1`, `=2.1.0` or a bare version, `^1.4` (same major, or minor
+ * below 1.0), and `~2.1` (same minor). Partial versions and `x` stand for
+ * every version they match, so `2` and `2.x` are `>=2.0.0 <3.0.0`.
+ */
+function parseRange(range: string): Comparator[][] | undefined {
+  const sets: Comparator[][] = [];
+  for (const alternative of range.split('||')) {
+    // `>= 2` is one comparator, as npm reads it
+    const terms = alternative
+      .trim()
+      .replace(/(<=|>=|<|>|=|\^|~)\s+/g, '$1')
+      .split(/\s+/)
+      .filter(Boolean);
+    const set: Comparator[] = [];
+    for (const term of terms) {
+      const comparators = parseTerm(term);
+      if (!comparators) return undefined;
+      set.push(...comparators);
+    }
+    sets.push(set);
+  }
+  return sets;
+}
+
+/** Whether `range` can be read (see `satisfiesVersion`). */
+export function isVersionRange(range: string): boolean {
+  return range.trim() !== '' && parseRange(range) !== undefined;
+}
+
+function holds(version: ParsedVersion, comparator: Comparator): boolean {
+  const order = compareVersions(version, comparator.version);
+  switch (comparator.operator) {
+    case '<':
+      return order < 0;
+    case '<=':
+      return order <= 0;
+    case '>':
+      return order > 0;
+    case '>=':
+      return order >= 0;
+  }
+}
+
+/**
+ * Whether `version` is in `range`, in the npm range syntax most manifests
+ * use: `>=2`, `^1.4`, `~2.1`, `2.x`, `>=1.2 <2`, and `1.x || >=3`.
+ * Undefined when either cannot be read.
+ */
+export function satisfiesVersion(
+  version: string,
+  range: string,
+): boolean | undefined {
+  const parsed = parseVersion(version);
+  const sets = range.trim() === '' ? undefined : parseRange(range);
+  if (!parsed || !sets) return undefined;
+  return sets.some((set) =>
+    set.every((comparator) => holds(parsed, comparator)),
+  );
+}
@@ -0,0 +1,112 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import {
+  BUILTIN_VIEWS,
+  applyView,
+  groupByView,
+  resolveView,
+  toViewProfile,
+  viewTable,
+} from '../views.js';
+
+function makeEntity(
+  name: string,
+  overrides: Partial<StoredEntity> = {},
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath: `src/${name}.ts`,
+    name,
+    entityType: 'service',
+    description: `${name} service`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner: 'payments-team',
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `${name} service`,
+      owner: 'payments-team',
+      compliance: { regulations: ['PCI-DSS'], data_sensitivity: 'restricted' },
+      operational: { sla: '99.9%', on_call_team: 'payments-oncall' },
+    },
+    tags: ['payments'],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+    ...overrides,
+  };
+}
+
+describe('applyView', () => {
+  it('keeps the types and annotation fields the view asks for', () => {
+    const view = toViewProfile('on-call', {
+      types: ['service'],
+      fields: ['operational.on_call_team'],
+    });
+    const shown = applyView(view, [
+      makeEntity('Payments'),
+      makeEntity('charge', { entityType: 'function' }),
+    ]);
+    expect(shown.map((entity) => entity.name)).toEqual(['Payments']);
+    expect(shown[0]?.metadata).toEqual({
+      type: 'service',
+      description: 'Payments service',
+      operational: { on_call_team: 'payments-oncall' },
+    });
+    expect(shown[0]?.owner).toBeNull();
+    expect(shown[0]?.tags).toEqual([]);
+  });
+
+  it('leaves entities whole when the view lists no fields', () => {
+    const entity = makeEntity('Payments');
+    expect(applyView(toViewProfile('all', {}), [entity])).toEqual([entity]);
+  });
+});
+
+describe('viewTable and groupByView', () => {
+  it('lays entities out in the columns and groups of the view', () => {
+    const entities = applyView(BUILTIN_VIEWS.sre!, [
+      makeEntity('Payments'),
+      makeEntity('Ledger', { owner: null }),
+      makeEntity('Checkout', { owner: 'shop-team' }),
+    ]);
+    const groups = groupByView(BUILTIN_VIEWS.sre!, entities);
+    expect(groups.map((group) => group.key)).toEqual([
+      'payments-team',
+      'shop-team',
+      '',
+    ]);
+    expect(viewTable(BUILTIN_VIEWS.sre!, groups[0]!.entities)).toEqual({
+      headers: ['Name', 'Type', 'On call', 'SLA', 'Availability'],
+      rows: [['Payments', 'service', 'payments-oncall', '99.9%', '-']],
+    });
+  });
+
+  it('reads Header=field columns from the manifest', () => {
+    const view = toViewProfile('audit', {
+      layout: { columns: ['name', 'Rules=metadata.compliance.regulations'] },
+    });
+    expect(viewTable(view, [makeEntity('Payments')])).toEqual({
+      headers: ['name', 'Rules'],
+      rows: [['Payments', 'PCI-DSS']],
+    });
+  });
+});
+
+describe('resolveView', () => {
+  it('prefers configured views and names them all when one is unknown', () => {
+    const custom = { sre: toViewProfile('sre', { types: ['service'] }) };
+    expect(resolveView('sre', custom).columns).toHaveLength(5);
+    expect(resolveView('sre', custom).groupBy).toBeUndefined();
+    expect(resolveView('compliance', custom)).toBe(BUILTIN_VIEWS.compliance);
+    expect(() => resolveView('finance', custom)).toThrow(
+      "Unknown view 'finance'. Available: compliance, sre",
+    );
+  });
+});
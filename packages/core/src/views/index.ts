@@ -0,0 +1,12 @@
+export type { ViewColumn, ViewGroup, ViewProfile, ViewTable } from './types.js';
+export {
+  BUILTIN_VIEWS,
+  applyView,
+  groupByView,
+  inView,
+  projectEntity,
+  resolveView,
+  toViewProfile,
+  viewCell,
+  viewTable,
+} from './views.js';
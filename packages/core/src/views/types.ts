@@ -0,0 +1,47 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for view profiles that tailor the entity types, fields, and layout of the graph to one audience
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [views, profiles, output, types, interface]
+ * context:
+ *   business_goal: Let organizations define their own audiences without changing knowgraph
+ *   domain: views
+ */
+import type { StoredEntity } from '../indexer/types.js';
+import type { EntityType } from '../types/entity.js';
+
+/** A table column: an entity field, dotted for nested ones. */
+export interface ViewColumn {
+  readonly header: string;
+  readonly field: string;
+}
+
+export interface ViewProfile {
+  readonly name: string;
+  readonly description?: string;
+  /** Entity types shown; every type when empty. */
+  readonly types: readonly EntityType[];
+  /**
+   * Annotation fields kept, dotted for nested ones (`operational.sla`);
+   * every field when empty. `type` and `description` are always kept.
+   */
+  readonly fields: readonly string[];
+  readonly columns: readonly ViewColumn[];
+  /** Entity field rows are grouped by, such as `owner`. */
+  readonly groupBy?: string;
+}
+
+/** The entities of one group, in their original order. */
+export interface ViewGroup {
+  /** The group's value as text; empty for entities without one. */
+  readonly key: string;
+  readonly entities: readonly StoredEntity[];
+}
+
+/** Entities laid out as a view's table. */
+export interface ViewTable {
+  readonly headers: readonly string[];
+  readonly rows: readonly (readonly string[])[];
+}
@@ -0,0 +1,242 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Resolves view profiles and applies them to entities, keeping the types and fields an audience asked for and laying them out as a table
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [views, profiles, output, filtering]
+ * context:
+ *   business_goal: Show compliance, SRE, and other audiences the part of the graph that answers their questions
+ *   domain: views
+ */
+import { compareStrings } from '../canonical/canonical.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { StoredEntity } from '../indexer/types.js';
+import type { ViewConfig } from '../types/manifest-workflows.js';
+import type { ViewColumn, ViewGroup, ViewProfile, ViewTable } from './types.js';
+
+const DEFAULT_COLUMNS: readonly ViewColumn[] = [
+  { header: 'Name', field: 'name' },
+  { header: 'Type', field: 'entityType' },
+  { header: 'Owner', field: 'owner' },
+  { header: 'File', field: 'filePath' },
+  { header: 'Description', field: 'description' },
+];
+
+export const BUILTIN_VIEWS: Readonly<Record<string, ViewProfile>> = {
+  compliance: {
+    name: 'compliance',
+    description: 'Data sensitivity, regulations, and audit needs by owner',
+    types: [],
+    fields: ['owner', 'status', 'compliance', 'fields'],
+    columns: [
+      { header: 'Name', field: 'name' },
+      { header: 'Type', field: 'entityType' },
+      {
+        header: 'Sensitivity',
+        field: 'metadata.compliance.data_sensitivity',
+      },
+      { header: 'Regulations', field: 'metadata.compliance.regulations' },
+      { header: 'File', field: 'filePath' },
+    ],
+    groupBy: 'owner',
+  },
+  sre: {
+    name: 'sre',
+    description: 'Services and endpoints with on-call teams, SLAs, and SLOs',
+    types: ['service', 'api_endpoint', 'module'],
+    fields: ['owner', 'status', 'operational', 'slo', 'dependencies', 'links'],
+    columns: [
+      { header: 'Name', field: 'name' },
+      { header: 'Type', field: 'entityType' },
+      { header: 'On call', field: 'metadata.operational.on_call_team' },
+      { header: 'SLA', field: 'metadata.operational.sla' },
+      { header: 'Availability', field: 'metadata.slo.availability' },
+    ],
+    groupBy: 'owner',
+  },
+};
+
+/**
+ * Entity columns that mirror an annotation field, with the value left
+ * behind when a view leaves the field out.
+ */
+const COLUMNS: Readonly<
+  Record<string, { readonly key: keyof StoredEntity; readonly empty: unknown }>
+> = {
+  owner: { key: 'owner', empty: null },
+  status: { key: 'status', empty: null },
+  tags: { key: 'tags', empty: [] },
+  links: { key: 'links', empty: [] },
+};
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+/** Copy the value at `path` in `source`, if any, into `target`. */
+function pick(
+  source: Record<string, unknown>,
+  path: readonly string[],
+  target: Record<string, unknown>,
+): void {
+  const [key, ...rest] = path;
+  if (key === undefined || !Object.hasOwn(source, key)) return;
+  const value = source[key];
+  if (rest.length === 0) {
+    target[key] = value;
+    return;
+  }
+  if (!isRecord(value)) return;
+  const existing = target[key];
+  const nested = isRecord(existing) ? { ...existing } : {};
+  pick(value, rest, nested);
+  if (Object.keys(nested).length > 0) target[key] = nested;
+}
+
+function valueAt(item: unknown, field: string): unknown {
+  let value = item;
+  for (const key of field.split('.')) {
+    if (!isRecord(value)) return undefined;
+    value = value[key];
+  }
+  return value;
+}
+
+function text(value: unknown): string {
+  if (value === undefined || value === null) return '';
+  if (Array.isArray(value)) return value.map(text).join(', ');
+  if (typeof value === 'object') return JSON.stringify(value);
+  return String(value);
+}
+
+/** The view `name` from `custom`, or a built-in one. */
+export function resolveView(
+  name: string,
+  custom: Readonly<Record<string, ViewProfile>> = {},
+): ViewProfile {
+  const view = Object.hasOwn(custom, name)
+    ? custom[name]
+    : Object.hasOwn(BUILTIN_VIEWS, name)
+      ? BUILTIN_VIEWS[name]
+      : undefined;
+  if (!view) {
+    const available = [
+      ...new Set([...Object.keys(custom), ...Object.keys(BUILTIN_VIEWS)]),
+    ].sort(compareStrings);
+    throw createKnowgraphError(
+      'usage',
+      `Unknown view '${name}'. Available: ${available.join(', ')}`,
+    );
+  }
+  return view;
+}
+
+/** The view a manifest `views` entry describes. */
+export function toViewProfile(name: string, config: ViewConfig): ViewProfile {
+  const columns = config.layout?.columns?.map((column) => {
+    const split = column.indexOf('=');
+    return split === -1
+      ? { header: column, field: column }
+      : { header: column.slice(0, split), field: column.slice(split + 1) };
+  });
+  return {
+    name,
+    ...(config.description !== undefined
+      ? { description: config.description }
+      : {}),
+    types: config.types ?? [],
+    fields: config.fields ?? [],
+    columns: columns ?? DEFAULT_COLUMNS,
+    ...(config.layout?.group_by !== undefined
+      ? { groupBy: config.layout.group_by }
+      : {}),
+  };
+}
+
+/** Whether `view` shows entities of `entity`'s type. */
+export function inView(view: ViewProfile, entity: StoredEntity): boolean {
+  return view.types.length === 0 || view.types.includes(entity.entityType);
+}
+
+/**
+ * `entity` with only the annotation fields `view` keeps. Entity columns
+ * that mirror a field left out, such as `owner`, are emptied too.
+ */
+export function projectEntity(
+  view: ViewProfile,
+  entity: StoredEntity,
+): StoredEntity {
+  if (view.fields.length === 0) return entity;
+  const source = entity.metadata as Record<string, unknown>;
+  const metadata: Record<string, unknown> = {
+    type: entity.metadata.type,
+    description: entity.metadata.description,
+  };
+  for (const field of view.fields) pick(source, field.split('.'), metadata);
+  const projected: Record<string, unknown> = { ...entity, metadata };
+  for (const [field, { key, empty }] of Object.entries(COLUMNS)) {
+    if (!view.fields.includes(field)) projected[key] = empty;
+  }
+  return projected as unknown as StoredEntity;
+}
+
+/** The entities `view` shows, each with only the fields it keeps. */
+export function applyView(
+  view: ViewProfile,
+  entities: Iterable<StoredEntity>,
+): StoredEntity[] {
+  const shown: StoredEntity[] = [];
+  for (const entity of entities) {
+    if (inView(view, entity)) shown.push(projectEntity(view, entity));
+  }
+  return shown;
+}
+
+/** The text of `entity`'s `field` in a table; `-` when it is unset. */
+export function viewCell(entity: StoredEntity, field: string): string {
+  return text(valueAt(entity, field)) || '-';
+}
+
+/**
+ * `entities` grouped by the view's `groupBy` field, groups sorted by value
+ * with entities lacking one last. Entities with a list field join the group
+ * of each item. One group keyed `''` when the view does not group.
+ */
+export function groupByView(
+  view: ViewProfile,
+  entities: readonly StoredEntity[],
+): readonly ViewGroup[] {
+  const { groupBy } = view;
+  if (groupBy === undefined) return [{ key: '', entities }];
+  const groups = new Map<string, StoredEntity[]>();
+  for (const entity of entities) {
+    const value = valueAt(entity, groupBy);
+    const keys = Array.isArray(value) && value.length > 0 ? value : [value];
+    for (const key of new Set(keys.map(text))) {
+      const group = groups.get(key);
+      if (group) group.push(entity);
+      else groups.set(key, [entity]);
+    }
+  }
+  return [...groups.entries()]
+    .sort(([a], [b]) =>
+      a === '' || b === ''
+        ? Number(a === '') - Number(b === '')
+        : compareStrings(a, b),
+    )
+    .map(([key, grouped]) => ({ key, entities: grouped }));
+}
+
+/** `entities` as rows of the view's columns. */
+export function viewTable(
+  view: ViewProfile,
+  entities: readonly StoredEntity[],
+): ViewTable {
+  return {
+    headers: view.columns.map((column) => column.header),
+    rows: entities.map((entity) =>
+      view.columns.map((column) => viewCell(entity, column.field)),
+    ),
+  };
+}
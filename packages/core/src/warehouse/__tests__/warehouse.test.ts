@@ -0,0 +1,395 @@
+import { describe, it, expect, vi, afterEach } from 'vitest';
+import type { DependencyGraph } from '../../graph/types.js';
+import { bigQuerySchema, createBigQuerySink } from '../bigquery.js';
+import { buildWarehouseSnapshot } from '../snapshot.js';
+import {
+  createSnowflakeSink,
+  snowflakeCreateTable,
+  snowflakeInsert,
+} from '../snowflake.js';
+
+const GRAPH: DependencyGraph = {
+  nodes: [
+    {
+      id: 'checkout',
+      name: 'checkout',
+      entityType: 'service',
+      external: false,
+      filePath: 'src/checkout.ts',
+      owner: 'shop-team',
+      domain: null,
+      workspace: null,
+    },
+    {
+      id: 'payments',
+      name: 'payments',
+      entityType: 'service',
+      external: false,
+      filePath: 'src/payments.ts',
+      owner: 'payments-team',
+      domain: null,
+      workspace: null,
+    },
+  ],
+  edges: [
+    {
+      from: 'checkout',
+      to: 'payments',
+      kind: 'service',
+      provenance: 'declared',
+      confidence: 1,
+    },
+  ],
+};
+
+const snapshot = buildWarehouseSnapshot(GRAPH, {
+  repository: 'acme/shop',
+  date: '2024-07-01',
+});
+
+interface Call {
+  readonly url: string;
+  readonly method: string;
+  readonly body: string;
+  readonly headers: Record<string, string>;
+}
+
+/** Answer fetches with `respond`, recording each one. */
+function mockFetch(
+  respond: (call: Call) => { status?: number; body?: object },
+): Call[] {
+  const calls: Call[] = [];
+  vi.stubGlobal(
+    'fetch',
+    vi.fn(async (url: string, init: RequestInit = {}) => {
+      const call = {
+        url,
+        method: init.method ?? 'GET',
+        body: String(init.body ?? ''),
+        headers: (init.headers ?? {}) as Record<string, string>,
+      };
+      calls.push(call);
+      const { status = 200, body = {} } = respond(call);
+      return {
+        ok: status < 300,
+        status,
+        statusText: `HTTP ${status}`,
+        text: async () => JSON.stringify(body),
+      };
+    }),
+  );
+  return calls;
+}
+
+const noWait = async () => {};
+
+afterEach(() => {
+  vi.unstubAllGlobals();
+});
+
+describe('buildWarehouseSnapshot', () => {
+  it('stamps every row with the date and repository', () => {
+    expect(snapshot.tables.nodes.columns.slice(0, 3)).toEqual([
+      { name: 'snapshot_date', type: 'date' },
+      { name: 'repository', type: 'string' },
+      { name: 'id', type: 'string' },
+    ]);
+    expect(snapshot.tables.edges.rows).toEqual([
+      {
+        snapshot_date: '2024-07-01',
+        repository: 'acme/shop',
+        from_id: 'checkout',
+        to_id: 'payments',
+        kind: 'service',
+        provenance: 'declared',
+        confidence: 1,
+        requires: null,
+        purpose: null,
+        protocol: null,
+        criticality: null,
+      },
+    ]);
+    expect(snapshot.tables.nodes.rows[1]).toMatchObject({
+      name: 'payments',
+      aliases: [],
+      annotated: null,
+    });
+  });
+});
+
+describe('createBigQuerySink', () => {
+  it('maps columns to a BigQuery schema', () => {
+    const fields = bigQuerySchema(snapshot.tables.nodes.columns).fields;
+    expect(fields.slice(0, 3)).toEqual([
+      { name: 'snapshot_date', type: 'DATE', mode: 'REQUIRED' },
+      { name: 'repository', type: 'STRING', mode: 'REQUIRED' },
+      { name: 'id', type: 'STRING', mode: 'REQUIRED' },
+    ]);
+    expect(fields).toContainEqual({
+      name: 'aliases',
+      type: 'STRING',
+      mode: 'REPEATED',
+    });
+  });
+
+  it('creates tables, deletes the day, then loads the rows', async () => {
+    const calls = mockFetch((call) => {
+      if (call.url.endsWith('/tables')) return { status: 409 };
+      if (call.url.endsWith('/queries')) return { body: { jobComplete: true } };
+      if (call.url.includes('/upload/')) {
+        return { body: { jobReference: { jobId: 'load-1', location: 'US' } } };
+      }
+      return { body: { status: { state: 'DONE' } } };
+    });
+    const sink = createBigQuerySink({
+      project: 'acme',
+      dataset: 'eng',
+      token: 'secret',
+      sleep: noWait,
+    });
+    expect(await sink.publish(snapshot)).toEqual({ nodes: 2, edges: 1 });
+    const api = 'https://bigquery.googleapis.com';
+    expect(calls.map((call) => `${call.method} ${call.url}`)).toEqual(
+      [1, 2].flatMap(() => [
+        `POST ${api}/bigquery/v2/projects/acme/datasets/eng/tables`,
+        `POST ${api}/bigquery/v2/projects/acme/queries`,
+        `POST ${api}/upload/bigquery/v2/projects/acme/jobs?uploadType=multipart`,
+        `GET ${api}/bigquery/v2/projects/acme/jobs/load-1?location=US`,
+      ]),
+    );
+    expect(calls[0].headers.Authorization).toBe('Bearer secret');
+    expect(JSON.parse(calls[0].body).timePartitioning).toEqual({
+      type: 'DAY',
+      field: 'snapshot_date',
+    });
+    expect(JSON.parse(calls[1].body).query).toBe(
+      'DELETE FROM `acme.eng.knowgraph_nodes` WHERE snapshot_date = @snapshot_date AND repository = @repository',
+    );
+    expect(calls[2].body).toContain(
+      '{"snapshot_date":"2024-07-01","repository":"acme/shop","id":"checkout"',
+    );
+  });
+
+  it('reports a failed load job', async () => {
+    mockFetch((call) => {
+      if (call.url.endsWith('/queries')) return { body: { jobComplete: true } };
+      if (call.url.includes('/upload/')) {
+        return { body: { jobReference: { jobId: 'load-1' } } };
+      }
+      if (call.url.includes('/jobs/')) {
+        const errorResult = { message: 'No such field: stub' };
+        return { body: { status: { state: 'DONE', errorResult } } };
+      }
+      return {};
+    });
+    const sink = createBigQuerySink({
+      project: 'acme',
+      dataset: 'eng',
+      token: 'secret',
+      sleep: noWait,
+    });
+    await expect(sink.publish(snapshot)).rejects.toThrow(
+      'BigQuery load failed: No such field: stub',
+    );
+  });
+
+  it('gives up on a job that does not finish in time', async () => {
+    mockFetch((call) =>
+      call.url.includes('/jobs/')
+        ? { body: { status: { state: 'RUNNING' } } }
+        : { body: { jobReference: { jobId: 'job-1' } } },
+    );
+    const sink = createBigQuerySink({
+      project: 'acme',
+      dataset: 'eng',
+      token: 'secret',
+      timeoutMs: 3000,
+      sleep: noWait,
+    });
+    await expect(sink.publish(snapshot)).rejects.toMatchObject({
+      kind: 'timeout',
+    });
+  });
+
+  it('retries server errors and fails on rejected requests', async () => {
+    let failures = 2;
+    const calls = mockFetch((call) =>
+      call.url.endsWith('/tables') && failures-- > 0
+        ? { status: 503 }
+        : { status: 403, body: { error: { message: 'Access Denied' } } },
+    );
+    const sink = createBigQuerySink({
+      project: 'acme',
+      dataset: 'eng',
+      token: 'secret',
+      sleep: noWait,
+    });
+    await expect(sink.publish(snapshot)).rejects.toThrow(
+      'BigQuery request failed: 403 Access Denied',
+    );
+    expect(calls).toHaveLength(3);
+  });
+
+  it('fetches credentials from the provider before each try', async () => {
+    let issued = 0;
+    const credentials = {
+      type: 'oidc',
+      credentials: async () => ({
+        headers: { Authorization: `Bearer token-${++issued}` },
+      }),
+    };
+    let failures = 1;
+    const calls = mockFetch(() =>
+      failures-- > 0 ? { status: 503 } : { body: {} },
+    );
+    const sink = createBigQuerySink({
+      project: 'acme',
+      dataset: 'eng',
+      credentials,
+      sleep: noWait,
+    });
+    await sink.test();
+    expect(calls.map((call) => call.headers.Authorization)).toEqual([
+      'Bearer token-1',
+      'Bearer token-2',
+    ]);
+    expect(() => createBigQuerySink({ project: 'a', dataset: 'b' })).toThrow(
+      'BigQuery needs credentials or a token',
+    );
+  });
+
+  it('tests access to the dataset and says what to fix', async () => {
+    const calls = mockFetch(() => ({}));
+    const sink = createBigQuerySink({
+      project: 'acme',
+      dataset: 'eng',
+      token: 'secret',
+    });
+    await sink.test();
+    expect(calls.map((call) => [call.method, call.url])).toEqual([
+      [
+        'GET',
+        'https://bigquery.googleapis.com/bigquery/v2/projects/acme/datasets/eng',
+      ],
+    ]);
+    mockFetch(() => ({
+      status: 404,
+      body: { error: { message: 'Not found: Dataset acme:eng' } },
+    }));
+    await expect(sink.test()).rejects.toThrow(
+      'BigQuery dataset acme.eng does not exist (Not found: Dataset acme:eng); create it, or fix project and dataset',
+    );
+  });
+
+  it('rejects table names that are not identifiers', async () => {
+    const sink = createBigQuerySink({
+      project: 'acme',
+      dataset: 'eng',
+      token: 'secret',
+      tables: { nodes: 'nodes; DROP TABLE x' },
+    });
+    await expect(sink.publish(snapshot)).rejects.toMatchObject({
+      kind: 'usage',
+    });
+  });
+});
+
+describe('createSnowflakeSink', () => {
+  it('builds the DDL and a JSON-bound INSERT', () => {
+    const { columns } = snapshot.tables.edges;
+    expect(snowflakeCreateTable('edges', columns)).toBe(
+      'CREATE TABLE IF NOT EXISTS edges (snapshot_date DATE NOT NULL, repository VARCHAR NOT NULL, from_id VARCHAR NOT NULL, to_id VARCHAR NOT NULL, kind VARCHAR NOT NULL, provenance VARCHAR NOT NULL, confidence FLOAT NOT NULL, requires VARCHAR, purpose VARCHAR, protocol VARCHAR, criticality VARCHAR) CLUSTER BY (snapshot_date, repository)',
+    );
+    expect(snowflakeInsert('edges', columns.slice(0, 2))).toBe(
+      'INSERT INTO edges (snapshot_date, repository) SELECT value:snapshot_date::DATE, value:repository::VARCHAR FROM TABLE(FLATTEN(INPUT => PARSE_JSON(?)))',
+    );
+  });
+
+  it('replaces the day in batches and polls running statements', async () => {
+    let running = 1;
+    const calls = mockFetch((call) =>
+      call.method === 'POST' && running-- > 0
+        ? { status: 202, body: { statementHandle: 'h-1' } }
+        : {},
+    );
+    const sink = createSnowflakeSink({
+      account: 'acme-eng',
+      database: 'ENG',
+      schema: 'KNOWGRAPH',
+      warehouse: 'REPORTING',
+      token: 'jwt',
+      tokenType: 'KEYPAIR_JWT',
+      batchSize: 1,
+      sleep: noWait,
+    });
+    expect(await sink.publish(snapshot)).toEqual({ nodes: 2, edges: 1 });
+    const url = 'https://acme-eng.snowflakecomputing.com/api/v2/statements';
+    expect(calls[1].url).toBe(`${url}/h-1`);
+    const statements = calls
+      .filter((call) => call.method === 'POST')
+      .map((call) => JSON.parse(call.body));
+    expect(statements.map((s) => s.statement.split(' (')[0])).toEqual([
+      'CREATE TABLE IF NOT EXISTS knowgraph_nodes',
+      'DELETE FROM knowgraph_nodes WHERE snapshot_date = ? AND repository = ?',
+      'INSERT INTO knowgraph_nodes',
+      'INSERT INTO knowgraph_nodes',
+      'CREATE TABLE IF NOT EXISTS knowgraph_edges',
+      'DELETE FROM knowgraph_edges WHERE snapshot_date = ? AND repository = ?',
+      'INSERT INTO knowgraph_edges',
+    ]);
+    expect(statements[1]).toMatchObject({
+      database: 'ENG',
+      schema: 'KNOWGRAPH',
+      warehouse: 'REPORTING',
+      bindings: {
+        1: { type: 'TEXT', value: '2024-07-01' },
+        2: { type: 'TEXT', value: 'acme/shop' },
+      },
+    });
+    expect(JSON.parse(statements[2].bindings[1].value)).toHaveLength(1);
+    expect(calls[0].headers['X-Snowflake-Authorization-Token-Type']).toBe(
+      'KEYPAIR_JWT',
+    );
+  });
+
+  it('tests the schema with a query and says what to fix', async () => {
+    const calls = mockFetch(() => ({
+      status: 401,
+      body: { message: 'Invalid OAuth access token' },
+    }));
+    const sink = createSnowflakeSink({
+      account: 'acme-eng',
+      database: 'ENG',
+      schema: 'KNOWGRAPH',
+      token: 'token',
+      sleep: noWait,
+    });
+    await expect(sink.test()).rejects.toThrow(
+      'Snowflake rejected the token (Invalid OAuth access token); check it has not expired and its type is OAUTH',
+    );
+    expect(JSON.parse(calls[0].body)).toMatchObject({
+      statement: 'SELECT 1',
+      database: 'ENG',
+      schema: 'KNOWGRAPH',
+    });
+  });
+
+  it('surfaces the Snowflake error message', async () => {
+    mockFetch(() => ({
+      status: 422,
+      body: {
+        message: "SQL compilation error: Schema 'KNOWGRAPH' does not exist",
+      },
+    }));
+    const sink = createSnowflakeSink({
+      account: 'acme-eng',
+      database: 'ENG',
+      schema: 'KNOWGRAPH',
+      token: 'token',
+      sleep: noWait,
+    });
+    await expect(sink.publish(snapshot)).rejects.toThrow(
+      "Snowflake request failed: 422 SQL compilation error: Schema 'KNOWGRAPH' does not exist",
+    );
+  });
+});
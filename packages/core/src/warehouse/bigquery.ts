@@ -0,0 +1,239 @@
+/**
+ * @knowgraph
+ * type: service
+ * description: BigQuery sink that loads each scan's node and edge rows into date-partitioned tables, replacing earlier rows for the day
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [warehouse, bigquery, analytics, load-job]
+ * context:
+ *   business_goal: Keep BigQuery tables a faithful daily history of the graph that reruns never duplicate
+ *   domain: warehouse
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import {
+  assertIdentifier,
+  createWarehouseClient,
+  DEFAULT_TABLES,
+  errorMessage,
+} from './http.js';
+import type {
+  BigQuerySinkOptions,
+  WarehouseColumn,
+  WarehouseColumnType,
+  WarehouseSink,
+  WarehouseSnapshot,
+  WarehouseTable,
+  WarehouseTableData,
+} from './types.js';
+
+/** The BigQuery API root, unless `baseUrl` says otherwise. */
+export const BIGQUERY_BASE_URL = 'https://bigquery.googleapis.com';
+
+const BIGQUERY_TYPES: Readonly<Record<WarehouseColumnType, string>> = {
+  string: 'STRING',
+  boolean: 'BOOL',
+  int64: 'INT64',
+  double: 'FLOAT64',
+  date: 'DATE',
+};
+
+interface JobReference {
+  readonly jobId: string;
+  readonly location?: string;
+}
+
+interface JobStatus {
+  readonly state?: string;
+  readonly errorResult?: { readonly message?: string };
+}
+
+function fieldMode(column: WarehouseColumn): string {
+  if (column.list) return 'REPEATED';
+  return column.nullable ? 'NULLABLE' : 'REQUIRED';
+}
+
+/** A BigQuery table schema for `columns`. */
+export function bigQuerySchema(columns: readonly WarehouseColumn[]): {
+  readonly fields: readonly object[];
+} {
+  return {
+    fields: columns.map((column) => ({
+      name: column.name,
+      type: BIGQUERY_TYPES[column.type],
+      mode: fieldMode(column),
+    })),
+  };
+}
+
+/**
+ * Publish snapshots to `project.dataset`. Each table is created on first
+ * use, partitioned by day on `snapshot_date` and clustered on
+ * `repository`. A publish deletes the rows for its date and repository,
+ * then appends the new ones with a load job, so scanning twice in a day
+ * keeps only the later scan. Load jobs, unlike streaming inserts, leave
+ * the rows open to that DELETE right away.
+ */
+export function createBigQuerySink(
+  options: BigQuerySinkOptions,
+): WarehouseSink {
+  const client = createWarehouseClient('BigQuery', options);
+  const base = (options.baseUrl ?? BIGQUERY_BASE_URL).replace(/\/$/, '');
+  const project = encodeURIComponent(options.project);
+  const api = `${base}/bigquery/v2/projects/${project}`;
+  const headers = { 'Content-Type': 'application/json' };
+  const tableId = (table: WarehouseTable): string =>
+    assertIdentifier(
+      'BigQuery',
+      options.tables?.[table] ?? DEFAULT_TABLES[table],
+    );
+
+  async function createTable(id: string, data: WarehouseTableData) {
+    const body = {
+      tableReference: {
+        projectId: options.project,
+        datasetId: options.dataset,
+        tableId: id,
+      },
+      schema: bigQuerySchema(data.columns),
+      timePartitioning: { type: 'DAY', field: 'snapshot_date' },
+      clustering: { fields: ['repository'] },
+    };
+    const dataset = encodeURIComponent(options.dataset);
+    // 409: the table already exists
+    await client.request(
+      `${api}/datasets/${dataset}/tables`,
+      { method: 'POST', headers, body: JSON.stringify(body) },
+      [409],
+    );
+  }
+
+  async function awaitJob(job: JobReference, what: string): Promise<void> {
+    const where = job.location ?? options.location;
+    const url =
+      `${api}/jobs/${encodeURIComponent(job.jobId)}` +
+      (where ? `?location=${encodeURIComponent(where)}` : '');
+    const status = await client.poll(what, async () => {
+      const { body } = await client.request(url, { headers });
+      const job = body.status as JobStatus | undefined;
+      return job?.state === 'DONE' ? job : undefined;
+    });
+    if (status.errorResult) {
+      throw createKnowgraphError(
+        'io',
+        `BigQuery ${what} failed: ${status.errorResult.message ?? 'unknown error'}`,
+      );
+    }
+  }
+
+  async function deleteSnapshot(id: string, snapshot: WarehouseSnapshot) {
+    const table = `\`${options.project}.${options.dataset}.${id}\``;
+    const { body } = await client.request(`${api}/queries`, {
+      method: 'POST',
+      headers,
+      body: JSON.stringify({
+        query: `DELETE FROM ${table} WHERE snapshot_date = @snapshot_date AND repository = @repository`,
+        useLegacySql: false,
+        parameterMode: 'NAMED',
+        queryParameters: [
+          {
+            name: 'snapshot_date',
+            parameterType: { type: 'DATE' },
+            parameterValue: { value: snapshot.date },
+          },
+          {
+            name: 'repository',
+            parameterType: { type: 'STRING' },
+            parameterValue: { value: snapshot.repository },
+          },
+        ],
+        ...(options.location ? { location: options.location } : {}),
+      }),
+    });
+    const job = body.jobReference as JobReference | undefined;
+    if (!body.jobComplete && job?.jobId) await awaitJob(job, 'delete');
+  }
+
+  async function loadRows(id: string, data: WarehouseTableData) {
+    const boundary = `knowgraph-${Date.now().toString(36)}`;
+    const config = {
+      configuration: {
+        load: {
+          destinationTable: {
+            projectId: options.project,
+            datasetId: options.dataset,
+            tableId: id,
+          },
+          schema: bigQuerySchema(data.columns),
+          sourceFormat: 'NEWLINE_DELIMITED_JSON',
+          writeDisposition: 'WRITE_APPEND',
+          createDisposition: 'CREATE_NEVER',
+        },
+      },
+      ...(options.location
+        ? { jobReference: { location: options.location } }
+        : {}),
+    };
+    const rows = data.rows.map((row) => JSON.stringify(row)).join('\n');
+    const body = [
+      `--${boundary}`,
+      'Content-Type: application/json; charset=UTF-8',
+      '',
+      JSON.stringify(config),
+      `--${boundary}`,
+      'Content-Type: application/octet-stream',
+      '',
+      rows,
+      `--${boundary}--`,
+      '',
+    ].join('\r\n');
+    const { body: job } = await client.request(
+      `${base}/upload/bigquery/v2/projects/${project}/jobs?uploadType=multipart`,
+      {
+        method: 'POST',
+        headers: {
+          'Content-Type': `multipart/related; boundary=${boundary}`,
+        },
+        body,
+      },
+    );
+    const reference = job.jobReference as JobReference | undefined;
+    if (!reference?.jobId) {
+      throw createKnowgraphError('io', 'BigQuery load returned no job id');
+    }
+    await awaitJob(reference, 'load');
+  }
+
+  return {
+    name: 'bigquery',
+    async publish(snapshot) {
+      for (const table of ['nodes', 'edges'] as const) {
+        const id = tableId(table);
+        const data = snapshot.tables[table];
+        await createTable(id, data);
+        await deleteSnapshot(id, snapshot);
+        if (data.rows.length > 0) await loadRows(id, data);
+      }
+      return {
+        nodes: snapshot.tables.nodes.rows.length,
+        edges: snapshot.tables.edges.rows.length,
+      };
+    },
+    async test() {
+      tableId('nodes');
+      tableId('edges');
+      const dataset = `${options.project}.${options.dataset}`;
+      const { status, body } = await client.request(
+        `${api}/datasets/${encodeURIComponent(options.dataset)}`,
+        { headers },
+        [401, 403, 404],
+      );
+      const detail = errorMessage(body) ?? `HTTP ${status}`;
+      const problems: Readonly<Record<number, string>> = {
+        401: `BigQuery rejected the access token (${detail}); tokens from 'gcloud auth print-access-token' expire after an hour`,
+        403: `BigQuery denied access to dataset ${dataset} (${detail}); grant the token's account the BigQuery Data Editor and Job User roles`,
+        404: `BigQuery dataset ${dataset} does not exist (${detail}); create it, or fix project and dataset`,
+      };
+      if (problems[status]) throw createKnowgraphError('io', problems[status]);
+    },
+  };
+}
@@ -0,0 +1,192 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: JSON requests to warehouse APIs with retries, backoff, and job polling deadlines
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [warehouse, http, retry, backoff]
+ * context:
+ *   business_goal: Keep slow or briefly unavailable warehouse APIs from losing a day of graph data
+ *   domain: warehouse
+ */
+import { createTokenCredentials } from '../credentials/credentials.js';
+import type { CredentialsProvider } from '../credentials/types.js';
+import {
+  DEFAULT_RETRY_POLICY,
+  isRetryableStatus,
+  retryWithBackoff,
+} from '../delivery/delivery.js';
+import type { RetryPolicy } from '../delivery/types.js';
+import { createKnowgraphError } from '../errors/errors.js';
+import type { WarehouseSinkOptions } from './types.js';
+
+export const DEFAULT_JOB_TIMEOUT_MS = 300_000;
+export const POLL_INTERVAL_MS = 1000;
+
+export interface WarehouseResponse {
+  readonly status: number;
+  readonly body: Record<string, unknown>;
+}
+
+/** A request to a warehouse API, before credentials are added. */
+export interface WarehouseRequest {
+  readonly method?: string;
+  readonly headers: Readonly<Record<string, string>>;
+  readonly body?: string;
+}
+
+export interface WarehouseClient {
+  /**
+   * Send a request with the sink's credentials, retrying network errors,
+   * rate limits, and server errors. Statuses in `accept` count as success
+   * along with 2xx; other errors throw with the warehouse's message.
+   */
+  request(
+    url: string,
+    init: WarehouseRequest,
+    accept?: readonly number[],
+  ): Promise<WarehouseResponse>;
+  /** Call `check` until it returns a value, or throw at the deadline. */
+  poll<T>(what: string, check: () => Promise<T | undefined>): Promise<T>;
+}
+
+function wait(ms: number): Promise<void> {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+/** The error message in a BigQuery or Snowflake error body, if any. */
+export function errorMessage(
+  body: Record<string, unknown>,
+): string | undefined {
+  const error = body.error as { message?: unknown } | undefined;
+  const message = error?.message ?? body.message;
+  return typeof message === 'string' ? message : undefined;
+}
+
+async function readBody(response: Response): Promise<Record<string, unknown>> {
+  const text = await response.text();
+  if (!text) return {};
+  try {
+    const parsed: unknown = JSON.parse(text);
+    return typeof parsed === 'object' && parsed !== null
+      ? (parsed as Record<string, unknown>)
+      : {};
+  } catch {
+    return { message: text.slice(0, 200) };
+  }
+}
+
+type Attempt =
+  | { readonly ok: true; readonly response: WarehouseResponse }
+  | { readonly ok: false; readonly retryable: boolean; readonly error: string };
+
+/**
+ * The provider a sink authenticates with: `credentials`, or else one for
+ * the static `token`. Throws a usage error when there is neither.
+ */
+function warehouseCredentials(
+  sink: string,
+  options: WarehouseSinkOptions & { readonly token?: string },
+): CredentialsProvider {
+  if (options.credentials) return options.credentials;
+  if (options.token !== undefined) return createTokenCredentials(options.token);
+  throw createKnowgraphError('usage', `${sink} needs credentials or a token`);
+}
+
+/**
+ * A client that retries with the delivery client's backoff and fetches
+ * credentials before each try, so a token that expires while a job runs
+ * is refreshed.
+ */
+export function createWarehouseClient(
+  sink: string,
+  options: WarehouseSinkOptions & { readonly token?: string },
+): WarehouseClient {
+  const policy: RetryPolicy = { ...DEFAULT_RETRY_POLICY, ...options.retry };
+  const { sleep = wait, timeoutMs = DEFAULT_JOB_TIMEOUT_MS } = options;
+  const provider = warehouseCredentials(sink, options);
+
+  async function attempt(
+    url: string,
+    init: WarehouseRequest,
+    accept: readonly number[],
+  ): Promise<Attempt> {
+    const credentials = await provider.credentials();
+    if (credentials.tls) {
+      throw createKnowgraphError(
+        'usage',
+        `${sink} does not take client certificates; use a token`,
+      );
+    }
+    let response: Response;
+    try {
+      response = await fetch(url, {
+        ...init,
+        headers: { ...credentials.headers, ...init.headers },
+      });
+    } catch (err) {
+      const error = err instanceof Error ? err.message : String(err);
+      return { ok: false, retryable: true, error };
+    }
+    const body = await readBody(response);
+    if (response.ok || accept.includes(response.status)) {
+      return { ok: true, response: { status: response.status, body } };
+    }
+    return {
+      ok: false,
+      retryable: isRetryableStatus(response.status),
+      error: `${response.status} ${errorMessage(body) ?? response.statusText}`,
+    };
+  }
+
+  async function request(
+    url: string,
+    init: WarehouseRequest,
+    accept: readonly number[] = [],
+  ): Promise<WarehouseResponse> {
+    const result = await retryWithBackoff(policy, sleep, () =>
+      attempt(url, init, accept),
+    );
+    if (result.ok) return result.response;
+    const tries = result.retryable ? ` after ${policy.attempts} tries` : '';
+    throw createKnowgraphError(
+      'io',
+      `${sink} request failed${tries}: ${result.error}`,
+    );
+  }
+
+  async function poll<T>(
+    what: string,
+    check: () => Promise<T | undefined>,
+  ): Promise<T> {
+    for (let waited = 0; ; waited += POLL_INTERVAL_MS) {
+      const result = await check();
+      if (result !== undefined) return result;
+      if (waited >= timeoutMs) {
+        throw createKnowgraphError(
+          'timeout',
+          `${sink} ${what} did not finish within ${timeoutMs}ms`,
+        );
+      }
+      await sleep(POLL_INTERVAL_MS);
+    }
+  }
+
+  return { request, poll };
+}
+
+export const DEFAULT_TABLES = {
+  nodes: 'knowgraph_nodes',
+  edges: 'knowgraph_edges',
+} as const;
+
+/** Letters, digits, and underscores, starting with a letter or underscore. */
+export function assertIdentifier(sink: string, name: string): string {
+  if (!/^[A-Za-z_][A-Za-z0-9_]*$/.test(name)) {
+    throw createKnowgraphError(
+      'usage',
+      `Invalid ${sink} table name '${name}': use letters, digits, and underscores`,
+    );
+  }
+  return name;
+}
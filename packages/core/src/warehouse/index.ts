@@ -0,0 +1,26 @@
+export type {
+  BigQuerySinkOptions,
+  SnowflakeSinkOptions,
+  SnowflakeTokenType,
+  WarehouseColumn,
+  WarehouseColumnType,
+  WarehousePublishResult,
+  WarehouseRow,
+  WarehouseSink,
+  WarehouseSinkOptions,
+  WarehouseSnapshot,
+  WarehouseTable,
+  WarehouseTableData,
+} from './types.js';
+export { buildWarehouseSnapshot } from './snapshot.js';
+export {
+  BIGQUERY_BASE_URL,
+  bigQuerySchema,
+  createBigQuerySink,
+} from './bigquery.js';
+export {
+  createSnowflakeSink,
+  snowflakeBaseUrl,
+  snowflakeCreateTable,
+  snowflakeInsert,
+} from './snowflake.js';
@@ -0,0 +1,75 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Turns a dependency graph into dated node and edge rows with the Parquet export's columns
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [warehouse, graph, analytics, rows]
+ * context:
+ *   business_goal: Give warehouse tables the same columns as the Parquet export, so queries work on either
+ *   domain: warehouse
+ */
+import type { DependencyGraph } from '../graph/types.js';
+import { graphEdgesTable, graphNodesTable } from '../parquet/graph-parquet.js';
+import type { ParquetTable } from '../parquet/types.js';
+import type {
+  WarehouseColumn,
+  WarehouseRow,
+  WarehouseSnapshot,
+  WarehouseTableData,
+} from './types.js';
+
+const STAMP_COLUMNS: readonly WarehouseColumn[] = [
+  { name: 'snapshot_date', type: 'date' },
+  { name: 'repository', type: 'string' },
+];
+
+/** Rows of `table`, each led by the date and repository. */
+function stampRows(
+  table: ParquetTable,
+  date: string,
+  repository: string,
+): WarehouseTableData {
+  const rows = table.columns[0]?.values.length ?? 0;
+  return {
+    columns: [
+      ...STAMP_COLUMNS,
+      ...table.columns.map(({ name, type, nullable, list }) => ({
+        name,
+        type,
+        nullable,
+        list,
+      })),
+    ],
+    rows: Array.from({ length: rows }, (_, row): WarehouseRow => {
+      const record: Record<string, WarehouseRow[string]> = {
+        snapshot_date: date,
+        repository,
+      };
+      for (const column of table.columns) {
+        record[column.name] = column.values[row];
+      }
+      return record;
+    }),
+  };
+}
+
+/**
+ * The graph as warehouse rows: the same columns as the `parquet-nodes`
+ * and `parquet-edges` exports, after `snapshot_date` and `repository`.
+ * `date` defaults to today in UTC.
+ */
+export function buildWarehouseSnapshot(
+  graph: DependencyGraph,
+  options: { readonly repository: string; readonly date?: string },
+): WarehouseSnapshot {
+  const date = options.date ?? new Date().toISOString().slice(0, 10);
+  return {
+    date,
+    repository: options.repository,
+    tables: {
+      nodes: stampRows(graphNodesTable(graph), date, options.repository),
+      edges: stampRows(graphEdgesTable(graph), date, options.repository),
+    },
+  };
+}
@@ -0,0 +1,184 @@
+/**
+ * @knowgraph
+ * type: service
+ * description: Snowflake sink that replaces each scan's node and edge rows for the day through the SQL API
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [warehouse, snowflake, analytics, sql-api]
+ * context:
+ *   business_goal: Keep Snowflake tables a faithful daily history of the graph that reruns never duplicate
+ *   domain: warehouse
+ */
+import { createKnowgraphError } from '../errors/errors.js';
+import {
+  assertIdentifier,
+  createWarehouseClient,
+  DEFAULT_TABLES,
+  errorMessage,
+} from './http.js';
+import type { WarehouseRequest } from './http.js';
+import type {
+  SnowflakeSinkOptions,
+  WarehouseColumn,
+  WarehouseColumnType,
+  WarehouseSink,
+  WarehouseTable,
+} from './types.js';
+
+const DEFAULT_BATCH_SIZE = 1000;
+
+const SNOWFLAKE_TYPES: Readonly<Record<WarehouseColumnType, string>> = {
+  string: 'VARCHAR',
+  boolean: 'BOOLEAN',
+  int64: 'NUMBER(38, 0)',
+  double: 'FLOAT',
+  date: 'DATE',
+};
+
+type Binding = { readonly type: 'TEXT'; readonly value: string };
+
+function columnType(column: WarehouseColumn): string {
+  return column.list ? 'ARRAY' : SNOWFLAKE_TYPES[column.type];
+}
+
+/** The SQL API root of account `account`. */
+export function snowflakeBaseUrl(account: string): string {
+  return `https://${account}.snowflakecomputing.com`;
+}
+
+/** `CREATE TABLE IF NOT EXISTS` for `columns`, clustered on the date. */
+export function snowflakeCreateTable(
+  table: string,
+  columns: readonly WarehouseColumn[],
+): string {
+  const definitions = columns.map((column) => {
+    const nullable = column.nullable || column.list ? '' : ' NOT NULL';
+    return `${column.name} ${columnType(column)}${nullable}`;
+  });
+  return `CREATE TABLE IF NOT EXISTS ${table} (${definitions.join(', ')}) CLUSTER BY (snapshot_date, repository)`;
+}
+
+/**
+ * An INSERT reading its rows from one bound JSON array, so a batch of any
+ * width is a single binding.
+ */
+export function snowflakeInsert(
+  table: string,
+  columns: readonly WarehouseColumn[],
+): string {
+  const names = columns.map((column) => column.name).join(', ');
+  const values = columns
+    .map((column) => `value:${column.name}::${columnType(column)}`)
+    .join(', ');
+  return `INSERT INTO ${table} (${names}) SELECT ${values} FROM TABLE(FLATTEN(INPUT => PARSE_JSON(?)))`;
+}
+
+/**
+ * Publish snapshots to `database.schema` through the Snowflake SQL API.
+ * Each table is created on first use and clustered on `snapshot_date`
+ * and `repository`. A publish deletes the rows for its date and
+ * repository, then inserts the new ones `batchSize` at a time, so
+ * scanning twice in a day keeps only the later scan.
+ */
+export function createSnowflakeSink(
+  options: SnowflakeSinkOptions,
+): WarehouseSink {
+  const client = createWarehouseClient('Snowflake', options);
+  const base = (
+    options.baseUrl ?? snowflakeBaseUrl(options.account)
+  ).replace(/\/$/, '');
+  const url = `${base}/api/v2/statements`;
+  const headers = {
+    'X-Snowflake-Authorization-Token-Type': options.tokenType ?? 'OAUTH',
+    'Content-Type': 'application/json',
+    Accept: 'application/json',
+  };
+  const batchSize = options.batchSize ?? DEFAULT_BATCH_SIZE;
+  const tableName = (table: WarehouseTable): string =>
+    assertIdentifier(
+      'Snowflake',
+      options.tables?.[table] ?? DEFAULT_TABLES[table],
+    );
+
+  /** The SQL API request that runs `statement` in the sink's schema. */
+  function request(
+    statement: string,
+    bindings: readonly string[] = [],
+  ): WarehouseRequest {
+    const body = {
+      statement,
+      database: options.database,
+      schema: options.schema,
+      ...(options.warehouse ? { warehouse: options.warehouse } : {}),
+      ...(options.role ? { role: options.role } : {}),
+      ...(bindings.length > 0
+        ? {
+            bindings: Object.fromEntries(
+              bindings.map((value, i): [string, Binding] => [
+                String(i + 1),
+                { type: 'TEXT', value },
+              ]),
+            ),
+          }
+        : {}),
+    };
+    return { method: 'POST', headers, body: JSON.stringify(body) };
+  }
+
+  /** Run one statement, polling while Snowflake answers 202 (running). */
+  async function execute(
+    statement: string,
+    bindings: readonly string[] = [],
+  ): Promise<void> {
+    const first = await client.request(url, request(statement, bindings));
+    if (first.status !== 202) return;
+    const handle = encodeURIComponent(String(first.body.statementHandle));
+    await client.poll('statement', async () => {
+      const { status } = await client.request(`${url}/${handle}`, { headers });
+      return status === 202 ? undefined : true;
+    });
+  }
+
+  return {
+    name: 'snowflake',
+    async publish(snapshot) {
+      for (const table of ['nodes', 'edges'] as const) {
+        const name = tableName(table);
+        const { columns, rows } = snapshot.tables[table];
+        await execute(snowflakeCreateTable(name, columns));
+        await execute(
+          `DELETE FROM ${name} WHERE snapshot_date = ? AND repository = ?`,
+          [snapshot.date, snapshot.repository],
+        );
+        const insert = snowflakeInsert(name, columns);
+        for (let start = 0; start < rows.length; start += batchSize) {
+          const batch = rows.slice(start, start + batchSize);
+          await execute(insert, [JSON.stringify(batch)]);
+        }
+      }
+      return {
+        nodes: snapshot.tables.nodes.rows.length,
+        edges: snapshot.tables.edges.rows.length,
+      };
+    },
+    async test() {
+      tableName('nodes');
+      tableName('edges');
+      // A running statement (202) got past authentication too
+      const { status, body } = await client.request(
+        url,
+        request('SELECT 1'),
+        [401, 403, 404, 422],
+      );
+      const detail = errorMessage(body) ?? `HTTP ${status}`;
+      const schema = `${options.database}.${options.schema}`;
+      const problems: Readonly<Record<number, string>> = {
+        401: `Snowflake rejected the token (${detail}); check it has not expired and its type is ${options.tokenType ?? 'OAUTH'}`,
+        403: `Snowflake denied the request (${detail}); grant the role access to ${schema} and its warehouse`,
+        404: `Snowflake account ${options.account} was not found (${detail}); use the account identifier, such as myorg-myaccount`,
+        422: `Snowflake cannot use ${schema} (${detail}); check it exists and the role and warehouse can use it`,
+      };
+      if (problems[status]) throw createKnowgraphError('io', problems[status]);
+    },
+  };
+}
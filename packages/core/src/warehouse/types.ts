@@ -0,0 +1,121 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for warehouse sinks that replace each scan's dated node and edge rows in BigQuery or Snowflake
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [warehouse, bigquery, snowflake, analytics, types, interface]
+ * context:
+ *   business_goal: Let the index command publish to any warehouse without knowing which one it is
+ *   domain: warehouse
+ */
+import type { CredentialsProvider } from '../credentials/types.js';
+import type { RetryPolicy } from '../delivery/types.js';
+import type { ParquetValue, ParquetValueType } from '../parquet/types.js';
+
+export type WarehouseTable = 'nodes' | 'edges';
+
+/** Column types: the Parquet export's, plus the snapshot's date. */
+export type WarehouseColumnType = ParquetValueType | 'date';
+
+export interface WarehouseColumn {
+  readonly name: string;
+  readonly type: WarehouseColumnType;
+  readonly nullable?: boolean;
+  readonly list?: boolean;
+}
+
+export type WarehouseRow = Readonly<
+  Record<string, ParquetValue | readonly ParquetValue[] | null>
+>;
+
+export interface WarehouseTableData {
+  readonly columns: readonly WarehouseColumn[];
+  /** Keyed by column name; `date` values are `YYYY-MM-DD` strings. */
+  readonly rows: readonly WarehouseRow[];
+}
+
+/**
+ * One scan's graph, as rows stamped with the scan's date and repository.
+ * Publishing it replaces the rows with that date and repository.
+ */
+export interface WarehouseSnapshot {
+  /** UTC date of the scan, `YYYY-MM-DD`; tables are partitioned on it. */
+  readonly date: string;
+  /** Tells repositories sharing the tables apart. */
+  readonly repository: string;
+  readonly tables: Readonly<Record<WarehouseTable, WarehouseTableData>>;
+}
+
+export interface WarehousePublishResult {
+  readonly nodes: number;
+  readonly edges: number;
+}
+
+export interface WarehouseSink {
+  readonly name: string;
+  /**
+   * Create the tables when missing, then replace the snapshot's rows.
+   * Throws an I/O error when the warehouse rejects a request, or when
+   * retries run out, and a timeout error when a job does not finish.
+   */
+  publish(snapshot: WarehouseSnapshot): Promise<WarehousePublishResult>;
+  /**
+   * Check the credentials reach the dataset or schema, writing nothing.
+   * Throws an I/O error saying what to fix when they do not.
+   */
+  test(): Promise<void>;
+}
+
+/** Settings shared by every warehouse sink. */
+export interface WarehouseSinkOptions {
+  /** Table names (defaults: `knowgraph_nodes` and `knowgraph_edges`). */
+  readonly tables?: Partial<Readonly<Record<WarehouseTable, string>>>;
+  /** Overrides for `DEFAULT_RETRY_POLICY`, per request. */
+  readonly retry?: Partial<RetryPolicy>;
+  /** How long to wait for a job to finish (default: 5 minutes). */
+  readonly timeoutMs?: number;
+  /** Waits between retries and polls; tests pass one that does not. */
+  readonly sleep?: (ms: number) => Promise<void>;
+  /**
+   * Authenticates each request, such as a provider from the manifest's
+   * `delivery.auth`. Takes precedence over `token`.
+   */
+  readonly credentials?: CredentialsProvider;
+}
+
+export interface BigQuerySinkOptions extends WarehouseSinkOptions {
+  readonly project: string;
+  readonly dataset: string;
+  /**
+   * OAuth 2.0 access token with BigQuery write access, sent when no
+   * `credentials` are given.
+   */
+  readonly token?: string;
+  /** Dataset location, such as `US` or `europe-west1`. */
+  readonly location?: string;
+  /** API root, for emulators (default: `https://bigquery.googleapis.com`). */
+  readonly baseUrl?: string;
+}
+
+export type SnowflakeTokenType =
+  | 'OAUTH'
+  | 'KEYPAIR_JWT'
+  | 'PROGRAMMATIC_ACCESS_TOKEN';
+
+export interface SnowflakeSinkOptions extends WarehouseSinkOptions {
+  /** Account identifier, such as `myorg-myaccount`. */
+  readonly account: string;
+  readonly database: string;
+  readonly schema: string;
+  readonly warehouse?: string;
+  readonly role?: string;
+  /** Token sent when no `credentials` are given. */
+  readonly token?: string;
+  /** How Snowflake should read `token` (default: `OAUTH`). */
+  readonly tokenType?: SnowflakeTokenType;
+  /** Rows per INSERT statement (default: 1000). */
+  readonly batchSize?: number;
+  /** API root (default: `https://<account>.snowflakecomputing.com`). */
+  readonly baseUrl?: string;
+}
@@ -0,0 +1,128 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  discoverWorkspaceMembers,
+  findWorkspaceMember,
+  parseGoModulePath,
+  parseGoWork,
+  parsePnpmWorkspace,
+} from '../discovery.js';
+
+describe('manifest parsers', () => {
+  it('splits pnpm globs into includes and excludes', () => {
+    const content =
+      'packages:\n  - "packages/*"\n  - "apps/**"\n  - "!**/test/**"\n';
+    expect(parsePnpmWorkspace(content)).toEqual({
+      include: ['packages/*', 'apps/**'],
+      exclude: ['**/test/**'],
+    });
+    expect(parsePnpmWorkspace('')).toEqual({ include: [], exclude: [] });
+  });
+
+  it('reads single-line and block use directives from go.work', () => {
+    const content = [
+      'go 1.22',
+      '',
+      'use ./tools',
+      'use (',
+      '\t./services/api // public API',
+      '\t.',
+      ')',
+    ].join('\n');
+    expect(parseGoWork(content)).toEqual(['tools', 'services/api', '.']);
+  });
+
+  it('reads the module path from go.mod', () => {
+    expect(parseGoModulePath('module example.com/api\n\ngo 1.22\n')).toBe(
+      'example.com/api',
+    );
+    expect(parseGoModulePath('go 1.22\n')).toBeUndefined();
+  });
+});
+
+describe('discoverWorkspaceMembers', () => {
+  let dir: string;
+
+  function write(path: string, content = ''): void {
+    const full = join(dir, path);
+    mkdirSync(join(full, '..'), { recursive: true });
+    writeFileSync(full, content);
+  }
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-workspace-${Date.now()}-${Math.random().toString(36).slice(2)}`,
+    );
+    mkdirSync(dir, { recursive: true });
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('returns no members for a plain repository', () => {
+    write('src/index.ts');
+    expect(discoverWorkspaceMembers(dir)).toEqual([]);
+  });
+
+  it('finds pnpm, Go, and Bazel members', () => {
+    write('pnpm-workspace.yaml', 'packages:\n  - "packages/*"\n');
+    write('packages/web/package.json', '{"name": "@acme/web"}');
+    write('packages/unnamed/package.json', '{}');
+    write('packages/web/node_modules/dep/package.json', '{"name": "dep"}');
+    write('go.work', 'go 1.22\n\nuse ./services/api\n');
+    write('services/api/go.mod', 'module example.com/api\n');
+    write('MODULE.bazel');
+    write('services/api/BUILD.bazel');
+    write('services/api/handlers/BUILD');
+    write('bazel-out/BUILD');
+
+    expect(discoverWorkspaceMembers(dir)).toEqual([
+      { name: 'packages/unnamed', kind: 'pnpm', path: 'packages/unnamed' },
+      { name: '@acme/web', kind: 'pnpm', path: 'packages/web' },
+      { name: '//services/api', kind: 'bazel', path: 'services/api' },
+      { name: 'example.com/api', kind: 'go', path: 'services/api' },
+      {
+        name: '//services/api/handlers',
+        kind: 'bazel',
+        path: 'services/api/handlers',
+      },
+    ]);
+  });
+
+  it('ignores BUILD files outside a Bazel workspace', () => {
+    write('tools/BUILD');
+    expect(discoverWorkspaceMembers(dir)).toEqual([]);
+  });
+});
+
+describe('findWorkspaceMember', () => {
+  const members = [
+    { name: '//', kind: 'bazel' as const, path: '.' },
+    { name: '@acme/web', kind: 'pnpm' as const, path: 'packages/web' },
+    {
+      name: '//packages/web/lib',
+      kind: 'bazel' as const,
+      path: 'packages/web/lib',
+    },
+  ];
+
+  it('picks the innermost containing member', () => {
+    expect(findWorkspaceMember('packages/web/lib/a.ts', members)?.name).toBe(
+      '//packages/web/lib',
+    );
+    expect(findWorkspaceMember('packages/web/index.ts', members)?.name).toBe(
+      '@acme/web',
+    );
+    expect(findWorkspaceMember('README.md', members)?.name).toBe('//');
+  });
+
+  it('does not match sibling directories sharing a prefix', () => {
+    expect(
+      findWorkspaceMember('packages/website/a.ts', members.slice(1)),
+    ).toBeUndefined();
+  });
+});
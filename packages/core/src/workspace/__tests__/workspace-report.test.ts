@@ -0,0 +1,87 @@
+import { describe, it, expect } from 'vitest';
+import type { StoredEntity } from '../../indexer/types.js';
+import type { Dependencies } from '../../types/entity.js';
+import { buildDependencyGraph } from '../../graph/graph-builder.js';
+import { buildWorkspaceReport } from '../workspace-report.js';
+import type { WorkspaceMember } from '../types.js';
+
+function makeEntity(
+  name: string,
+  filePath: string,
+  owner: string | null,
+  dependencies?: Dependencies,
+): StoredEntity {
+  return {
+    id: `id-${name}`,
+    filePath,
+    name,
+    entityType: 'service',
+    description: `${name} description`,
+    rawDocstring: null,
+    signature: null,
+    parent: null,
+    language: 'typescript',
+    line: 1,
+    column: 0,
+    owner,
+    status: 'stable',
+    metadata: {
+      type: 'service',
+      description: `${name} description`,
+      dependencies,
+    },
+    tags: [],
+    links: [],
+    fileHash: null,
+    createdAt: '2024-01-01T00:00:00Z',
+    updatedAt: '2024-01-01T00:00:00Z',
+  };
+}
+
+const members: readonly WorkspaceMember[] = [
+  { name: '@acme/web', kind: 'pnpm', path: 'packages/web' },
+  { name: '@acme/api', kind: 'pnpm', path: 'packages/api' },
+  { name: '@acme/docs', kind: 'pnpm', path: 'packages/docs' },
+];
+
+describe('buildWorkspaceReport', () => {
+  const entities = [
+    makeEntity('web', 'packages/web/src/app.ts', 'web-team', {
+      services: ['api'],
+      databases: ['postgres'],
+    }),
+    makeEntity('api', 'packages/api/src/server.ts', 'api-team', {
+      services: ['auth'],
+    }),
+    makeEntity('auth', 'packages/api/src/auth.ts', 'security-team'),
+    makeEntity('script', 'scripts/seed.ts', null),
+  ];
+  const report = buildWorkspaceReport(
+    members,
+    buildDependencyGraph(entities, { workspaces: members }),
+  );
+
+  it('counts entities and owners per member', () => {
+    const api = report.members.find((m) => m.name === '@acme/api');
+    expect(api).toMatchObject({
+      entityCount: 2,
+      owners: ['api-team', 'security-team'],
+      dependsOn: [],
+    });
+  });
+
+  it('lists dependencies between members only', () => {
+    const web = report.members.find((m) => m.name === '@acme/web');
+    expect(web?.dependsOn).toEqual(['@acme/api']);
+  });
+
+  it('keeps empty members and counts unassigned entities', () => {
+    expect(report.members.map((m) => m.name)).toEqual([
+      '@acme/web',
+      '@acme/api',
+      '@acme/docs',
+    ]);
+    expect(report.members[2].entityCount).toBe(0);
+    expect(report.unassignedEntities).toBe(1);
+  });
+});
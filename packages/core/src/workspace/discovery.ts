@@ -0,0 +1,215 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Discovers workspace members from pnpm-workspace.yaml, go.work, and Bazel BUILD files
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [workspace, monorepo, pnpm, go, bazel, discovery]
+ * context:
+ *   business_goal: Find the real build units of a monorepo instead of guessing from directories
+ *   domain: workspace
+ */
+import { existsSync, readFileSync, readdirSync, statSync } from 'node:fs';
+import { join, posix, relative, sep } from 'node:path';
+import { globSync } from 'glob';
+import { parse as parseYaml } from 'yaml';
+import type { WorkspaceMember } from './types.js';
+
+const BAZEL_ROOT_MARKERS = ['MODULE.bazel', 'WORKSPACE', 'WORKSPACE.bazel'];
+const BAZEL_BUILD_FILES = ['BUILD', 'BUILD.bazel'];
+
+const SKIP_DIRS = new Set([
+  'node_modules',
+  '.git',
+  'dist',
+  'build',
+  'vendor',
+  'coverage',
+]);
+
+function toPosix(path: string): string {
+  return path.split(sep).join('/');
+}
+
+function normalizeDir(path: string): string {
+  const normalized = posix.normalize(toPosix(path)).replace(/\/+$/, '');
+  return normalized === '' ? '.' : normalized;
+}
+
+function readText(path: string): string | undefined {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Package globs from pnpm-workspace.yaml, split into includes and
+ * `!`-prefixed excludes.
+ */
+export function parsePnpmWorkspace(content: string): {
+  readonly include: readonly string[];
+  readonly exclude: readonly string[];
+} {
+  const parsed: unknown = parseYaml(content);
+  const packages =
+    typeof parsed === 'object' && parsed !== null && 'packages' in parsed
+      ? parsed.packages
+      : undefined;
+  const patterns = Array.isArray(packages)
+    ? packages.filter((p): p is string => typeof p === 'string')
+    : [];
+  return {
+    include: patterns.filter((p) => !p.startsWith('!')),
+    exclude: patterns.filter((p) => p.startsWith('!')).map((p) => p.slice(1)),
+  };
+}
+
+/**
+ * Directories named by `use` directives in a go.work file, in both the
+ * single-line and parenthesised block forms.
+ */
+export function parseGoWork(content: string): readonly string[] {
+  const dirs: string[] = [];
+  let inBlock = false;
+  for (const raw of content.split('\n')) {
+    const line = raw.replace(/\/\/.*$/, '').trim();
+    if (inBlock) {
+      if (line === ')') inBlock = false;
+      else if (line !== '') dirs.push(line);
+      continue;
+    }
+    if (/^use\s*\($/.test(line)) {
+      inBlock = true;
+      continue;
+    }
+    const single = /^use\s+(\S+)$/.exec(line);
+    if (single) dirs.push(single[1]);
+  }
+  return dirs.map((dir) => normalizeDir(dir.replace(/^"|"$/g, '')));
+}
+
+/**
+ * The module path declared in a go.mod file.
+ */
+export function parseGoModulePath(content: string): string | undefined {
+  return /^module\s+"?([^"\s]+)"?/m.exec(content)?.[1];
+}
+
+function discoverPnpmMembers(rootDir: string): readonly WorkspaceMember[] {
+  const content = readText(join(rootDir, 'pnpm-workspace.yaml'));
+  if (content === undefined) return [];
+  const { include, exclude } = parsePnpmWorkspace(content);
+  if (include.length === 0) return [];
+
+  const manifests = globSync(
+    include.map((pattern) => posix.join(pattern, 'package.json')),
+    {
+      cwd: rootDir,
+      posix: true,
+      ignore: [
+        '**/node_modules/**',
+        ...exclude.map((pattern) => posix.join(pattern, 'package.json')),
+      ],
+    },
+  );
+
+  return manifests.map((manifest) => {
+    const path = normalizeDir(posix.dirname(manifest));
+    let name = path;
+    try {
+      const pkg: unknown = JSON.parse(readText(join(rootDir, manifest)) ?? '');
+      if (typeof pkg === 'object' && pkg !== null && 'name' in pkg) {
+        name = typeof pkg.name === 'string' ? pkg.name : path;
+      }
+    } catch {
+      // Unnamed or unreadable package.json: fall back to the directory
+    }
+    return { name, kind: 'pnpm' as const, path };
+  });
+}
+
+function discoverGoMembers(rootDir: string): readonly WorkspaceMember[] {
+  const content = readText(join(rootDir, 'go.work'));
+  if (content === undefined) return [];
+  return parseGoWork(content).map((path) => {
+    const goMod = readText(join(rootDir, path, 'go.mod'));
+    const name = (goMod && parseGoModulePath(goMod)) || path;
+    return { name, kind: 'go' as const, path };
+  });
+}
+
+function discoverBazelMembers(rootDir: string): readonly WorkspaceMember[] {
+  const isBazelRoot = BAZEL_ROOT_MARKERS.some((marker) =>
+    existsSync(join(rootDir, marker)),
+  );
+  if (!isBazelRoot) return [];
+
+  const members: WorkspaceMember[] = [];
+  function walk(dir: string): void {
+    let entries: readonly string[];
+    try {
+      entries = readdirSync(dir);
+    } catch {
+      return;
+    }
+    if (entries.some((entry) => BAZEL_BUILD_FILES.includes(entry))) {
+      const path = normalizeDir(relative(rootDir, dir));
+      const label = path === '.' ? '//' : `//${path}`;
+      members.push({ name: label, kind: 'bazel', path });
+    }
+    for (const entry of entries) {
+      // bazel-out, bazel-bin, etc. are output symlinks, not sources
+      if (entry.startsWith('.') || entry.startsWith('bazel-')) continue;
+      if (SKIP_DIRS.has(entry)) continue;
+      const fullPath = join(dir, entry);
+      try {
+        if (statSync(fullPath).isDirectory()) walk(fullPath);
+      } catch {
+        // Skip inaccessible entries
+      }
+    }
+  }
+  walk(rootDir);
+  return members;
+}
+
+/**
+ * Discover every workspace member under `rootDir`, sorted by path. Each
+ * manifest type is optional; a repository with none yields no members.
+ */
+export function discoverWorkspaceMembers(
+  rootDir: string,
+): readonly WorkspaceMember[] {
+  return [
+    ...discoverPnpmMembers(rootDir),
+    ...discoverGoMembers(rootDir),
+    ...discoverBazelMembers(rootDir),
+  ].sort(
+    (a, b) => a.path.localeCompare(b.path) || a.kind.localeCompare(b.kind),
+  );
+}
+
+function depth(path: string): number {
+  return path === '.' ? 0 : path.split('/').length;
+}
+
+/**
+ * The innermost member containing `filePath` (relative to the workspace
+ * root), so a Bazel package nested in a pnpm package wins over it.
+ */
+export function findWorkspaceMember(
+  filePath: string,
+  members: readonly WorkspaceMember[],
+): WorkspaceMember | undefined {
+  const file = posix.normalize(toPosix(filePath));
+  let best: WorkspaceMember | undefined;
+  for (const member of members) {
+    const contains = member.path === '.' || file.startsWith(`${member.path}/`);
+    if (contains && (!best || depth(member.path) > depth(best.path))) {
+      best = member;
+    }
+  }
+  return best;
+}
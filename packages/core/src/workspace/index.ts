@@ -0,0 +1,14 @@
+export type {
+  WorkspaceKind,
+  WorkspaceMember,
+  WorkspaceMemberSummary,
+  WorkspaceReport,
+} from './types.js';
+export {
+  parsePnpmWorkspace,
+  parseGoWork,
+  parseGoModulePath,
+  discoverWorkspaceMembers,
+  findWorkspaceMember,
+} from './discovery.js';
+export { buildWorkspaceReport } from './workspace-report.js';
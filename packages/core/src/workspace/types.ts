@@ -0,0 +1,37 @@
+/**
+ * @knowgraph
+ * type: interface
+ * description: Types for monorepo workspace members discovered from pnpm, Go, and Bazel manifests
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [workspace, monorepo, types, interface]
+ * context:
+ *   business_goal: Model build units so graph boundaries follow how the repository is actually built
+ *   domain: workspace
+ */
+
+export type WorkspaceKind = 'pnpm' | 'go' | 'bazel';
+
+export interface WorkspaceMember {
+  /** Package name, Go module path, or Bazel package label (`//pkg`). */
+  readonly name: string;
+  readonly kind: WorkspaceKind;
+  /** POSIX directory relative to the workspace root; `.` for the root. */
+  readonly path: string;
+}
+
+export interface WorkspaceMemberSummary {
+  readonly name: string;
+  readonly kind: WorkspaceKind;
+  readonly path: string;
+  readonly entityCount: number;
+  readonly owners: readonly string[];
+  /** Other members this member's entities depend on. */
+  readonly dependsOn: readonly string[];
+}
+
+export interface WorkspaceReport {
+  readonly members: readonly WorkspaceMemberSummary[];
+  /** Entities whose files sit outside every member. */
+  readonly unassignedEntities: number;
+}
@@ -0,0 +1,62 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Rolls graph nodes up to workspace members and lists dependencies between build units
+ * owner: knowgraph-core
+ * status: experimental
+ * tags: [workspace, monorepo, report, graph]
+ * context:
+ *   business_goal: Show which packages and build targets own which entities and how they depend on each other
+ *   domain: workspace
+ */
+import type { DependencyGraph } from '../graph/types.js';
+import type {
+  WorkspaceMember,
+  WorkspaceMemberSummary,
+  WorkspaceReport,
+} from './types.js';
+
+/**
+ * Summarize each member of a graph built with `workspaces`. Members with
+ * no annotated entities are still listed so the report mirrors the build.
+ */
+export function buildWorkspaceReport(
+  members: readonly WorkspaceMember[],
+  graph: DependencyGraph,
+): WorkspaceReport {
+  const nodes = new Map(graph.nodes.map((node) => [node.id, node]));
+  const internal = graph.nodes.filter((node) => !node.external);
+
+  const dependsOn = new Map<string, Set<string>>();
+  for (const edge of graph.edges) {
+    const from = nodes.get(edge.from)?.workspace;
+    const to = nodes.get(edge.to)?.workspace;
+    if (!from || !to || from === to) continue;
+    const set = dependsOn.get(from) ?? new Set<string>();
+    set.add(to);
+    dependsOn.set(from, set);
+  }
+
+  const summaries: WorkspaceMemberSummary[] = members.map((member) => {
+    const memberNodes = internal.filter(
+      (node) => node.workspace === member.name,
+    );
+    const owners = new Set(
+      memberNodes.flatMap((node) => (node.owner ? [node.owner] : [])),
+    );
+    return {
+      name: member.name,
+      kind: member.kind,
+      path: member.path,
+      entityCount: memberNodes.length,
+      owners: [...owners].sort(),
+      dependsOn: [...(dependsOn.get(member.name) ?? [])].sort(),
+    };
+  });
+
+  return {
+    members: summaries,
+    unassignedEntities: internal.filter((node) => node.workspace === null)
+      .length,
+  };
+}
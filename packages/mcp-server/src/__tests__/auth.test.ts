@@ -0,0 +1,196 @@
+import { describe, it, expect } from 'vitest';
+import { createHmac, createSign, generateKeyPairSync } from 'node:crypto';
+import type { KeyObject } from 'node:crypto';
+import type { GraphNode } from '@know-graph/core';
+import {
+  ANONYMOUS,
+  bearerToken,
+  createAccessControl,
+  rolesFromClaims,
+  visibleNamespaces
+} from '../auth/access.js';
+import { createJwksResolver, verifyJwt } from '../auth/jwt.js';
+
+const NOW = Date.UTC(2026, 0, 1);
+const SECONDS = NOW / 1000;
+
+function encode(value: object): string {
+  return Buffer.from(JSON.stringify(value)).toString('base64url');
+}
+
+function signHs256(claims: object, secret: string): string {
+  const signed = `${encode({ alg: 'HS256', typ: 'JWT' })}.${encode(claims)}`;
+  const signature = createHmac('sha256', secret)
+    .update(signed)
+    .digest('base64url');
+  return `${signed}.${signature}`;
+}
+
+function signRs256(claims: object, key: KeyObject, kid: string): string {
+  const signed = `${encode({ alg: 'RS256', kid })}.${encode(claims)}`;
+  const signature = createSign('sha256').update(signed).sign(key, 'base64url');
+  return `${signed}.${signature}`;
+}
+
+describe('verifyJwt', () => {
+  const options = { secret: 'shh', now: () => NOW };
+
+  it('verifies HS256 tokens and checks issuer and audience', async () => {
+    const token = signHs256(
+      { sub: 'ada', iss: 'https://idp', aud: ['knowgraph'], exp: SECONDS + 60 },
+      'shh'
+    );
+    await expect(
+      verifyJwt(token, {
+        ...options,
+        issuer: 'https://idp',
+        audience: 'knowgraph'
+      })
+    ).resolves.toMatchObject({ sub: 'ada' });
+    await expect(
+      verifyJwt(token, { ...options, audience: 'other' })
+    ).rejects.toThrow(/audience/);
+  });
+
+  it('rejects bad signatures, expired tokens, and alg none', async () => {
+    await expect(
+      verifyJwt(signHs256({ sub: 'ada' }, 'wrong'), options)
+    ).rejects.toThrow(/signature/);
+    await expect(
+      verifyJwt(signHs256({ exp: SECONDS - 3600 }, 'shh'), options)
+    ).rejects.toThrow(/expired/);
+    const none = `${encode({ alg: 'none' })}.${encode({ sub: 'ada' })}.`;
+    await expect(verifyJwt(none, options)).rejects.toThrow(/signature/);
+    await expect(verifyJwt('not-a-jwt', options)).rejects.toThrow(/Malformed/);
+  });
+
+  it('verifies RS256 tokens against a discovered key set', async () => {
+    const { publicKey, privateKey } = generateKeyPairSync('rsa', {
+      modulusLength: 2048
+    });
+    const jwk = {
+      ...publicKey.export({ format: 'jwk' }),
+      kid: 'k1',
+      use: 'sig'
+    };
+    const requested: string[] = [];
+    const fetchStub = (async (url: string) => {
+      requested.push(url);
+      const body = url.endsWith('openid-configuration')
+        ? { jwks_uri: 'https://idp/jwks' }
+        : { keys: [jwk] };
+      return new Response(JSON.stringify(body));
+    }) as typeof fetch;
+    const keys = createJwksResolver({
+      issuer: 'https://idp/',
+      fetch: fetchStub
+    });
+
+    const token = signRs256({ sub: 'grace' }, privateKey, 'k1');
+    await expect(verifyJwt(token, { keys })).resolves.toMatchObject({
+      sub: 'grace'
+    });
+    await expect(
+      verifyJwt(signRs256({ sub: 'x' }, privateKey, 'k2'), { keys })
+    ).rejects.toThrow(/signature/);
+    expect(requested).toEqual([
+      'https://idp/.well-known/openid-configuration',
+      'https://idp/jwks'
+    ]);
+  });
+});
+
+describe('createAccessControl', () => {
+  const node: GraphNode = {
+    id: 'id-a',
+    name: 'A',
+    entityType: 'service',
+    external: false,
+    filePath: 'src/a.ts',
+    owner: 'team-a',
+    domain: 'billing',
+    workspace: null
+  };
+
+  it('lets everyone in as anonymous when no auth is configured', async () => {
+    const access = createAccessControl();
+    expect(access.required).toBe(false);
+    await expect(access.authorize(undefined)).resolves.toBe(ANONYMOUS);
+  });
+
+  it('accepts static tokens and JWTs with roles', async () => {
+    const access = createAccessControl({
+      tokens: [{ name: 'ci', token: 'ci-token', roles: ['reader'] }],
+      jwt: { secret: 'shh', rolesClaim: 'realm_access.roles' }
+    });
+    await expect(access.authorize('Bearer ci-token')).resolves.toEqual({
+      subject: 'ci',
+      roles: ['reader']
+    });
+    const jwt = signHs256(
+      { sub: 'ada', realm_access: { roles: ['compliance'] } },
+      'shh'
+    );
+    await expect(access.authorize(`Bearer ${jwt}`)).resolves.toEqual({
+      subject: 'ada',
+      roles: ['compliance']
+    });
+    await expect(access.authorize('Bearer nope')).resolves.toBeUndefined();
+    await expect(access.authorize(undefined)).resolves.toBeUndefined();
+  });
+
+  it('hides restricted fields from callers without the role', () => {
+    const access = createAccessControl({
+      restrictedFields: { compliance: ['compliance'], domain: ['compliance'] }
+    });
+    const metadata = { owner: 'team-a', compliance: { regulations: ['GDPR'] } };
+    const reader = { subject: 'ci', roles: ['reader'] };
+    const auditor = { subject: 'ada', roles: ['compliance'] };
+
+    expect(access.filterNode(node, reader).domain).toBeNull();
+    expect(access.filterMetadata(metadata, reader)).toEqual({
+      owner: 'team-a'
+    });
+    expect(access.filterNode(node, auditor)).toEqual(node);
+    expect(access.filterMetadata(metadata, auditor)).toEqual(metadata);
+  });
+
+  it('shows namespaces to the roles of any rule matching them', () => {
+    const access = createAccessControl({
+      namespaceRoles: {
+        acme: ['platform'],
+        'acme/payments': ['payments'],
+        '*': ['admin']
+      }
+    });
+    const payments = { subject: 'pay', roles: ['payments'] };
+    const platform = { subject: 'ops', roles: ['platform'] };
+    const hosted = ['acme/payments', 'acme/search', 'globex/ledger'];
+
+    expect(access.canSeeNamespace('acme/payments', payments)).toBe(true);
+    expect(access.canSeeNamespace('acme/search', payments)).toBe(false);
+    expect(access.canSeeNamespace(undefined, payments)).toBe(true);
+    expect(visibleNamespaces(access, platform, hosted)).toEqual([
+      'acme/payments',
+      'acme/search'
+    ]);
+    expect(
+      visibleNamespaces(access, platform, hosted, ['acme/search'])
+    ).toEqual(['acme/search']);
+    expect(
+      visibleNamespaces(access, { subject: 'root', roles: ['admin'] }, hosted)
+    ).toBeUndefined();
+  });
+});
+
+describe('token helpers', () => {
+  it('reads bearer tokens and role claims', () => {
+    expect(bearerToken('Bearer abc')).toBe('abc');
+    expect(bearerToken('Basic abc')).toBeUndefined();
+    expect(rolesFromClaims({ scope: 'read write' }, 'scope')).toEqual([
+      'read',
+      'write'
+    ]);
+    expect(rolesFromClaims({}, 'roles')).toEqual([]);
+  });
+});
@@ -0,0 +1,331 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { createTelemetry, scan } from '@know-graph/core';
+import type { GraphChangeEvent, GraphNode } from '@know-graph/core';
+import { createAccessControl } from '../auth/access.js';
+import type { AccessControl } from '../auth/access.js';
+import { createGraphService } from '../grpc/service.js';
+import type { GraphService } from '../grpc/service.js';
+import {
+  createGrpcHandlers,
+  PROTO_PATH,
+  traceGrpcHandlers
+} from '../grpc/server.js';
+
+const CHECKOUT = `/**
+ * @knowgraph
+ * type: service
+ * description: Checkout flow that charges the customer
+ * owner: shop-team
+ * dependencies:
+ *   services: [Payments]
+ */
+export class Checkout {}
+`;
+
+const PAYMENTS = `/**
+ * @knowgraph
+ * type: service
+ * description: Payment gateway wrapper for card charges
+ * owner: payments-team
+ */
+export class Payments {}
+`;
+
+const LEDGER = `/**
+ * @knowgraph
+ * type: service
+ * description: Ledger that records settled payments
+ * owner: payments-team
+ */
+export class Ledger {}
+`;
+
+describe('gRPC graph service', () => {
+  let dir: string;
+  let dbPath: string;
+  let service: GraphService;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-grpc-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'checkout.ts'), CHECKOUT);
+    writeFileSync(join(dir, 'src', 'payments.ts'), PAYMENTS);
+    dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+    service = createGraphService({ dbPath, pollIntervalMs: 60_000 });
+  });
+
+  afterEach(() => {
+    service.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function idOf(name: string): string {
+    const node = service.query({ query: name }).nodes[0];
+    if (!node) throw new Error(`No node named ${name}`);
+    return node.id;
+  }
+
+  it('answers queries with graph nodes', () => {
+    const result = service.query({ owner: 'payments-team' });
+    expect(result.total).toBe(1);
+    expect(result.nodes.map((node) => node.name)).toEqual(['Payments']);
+  });
+
+  it('returns a node with its edges', () => {
+    const details = service.getNode(idOf('Payments'));
+    expect(details?.node.name).toBe('Payments');
+    expect(details?.dependencies).toEqual([]);
+    expect(details?.dependents).toEqual([
+      {
+        from: idOf('Checkout'),
+        to: idOf('Payments'),
+        kind: 'service',
+        provenance: 'declared',
+        confidence: 1
+      }
+    ]);
+    expect(service.getNode('missing')).toBeUndefined();
+  });
+
+  it('traverses dependencies and dependents', () => {
+    const names = (direction: 'outgoing' | 'incoming', start: string) =>
+      [...service.traverse({ startId: idOf(start), direction })].map(
+        (step) => step.node.name
+      );
+    expect(names('outgoing', 'Checkout')).toEqual(['Payments']);
+    expect(names('incoming', 'Payments')).toEqual(['Checkout']);
+  });
+
+  it('takes a snapshot of the whole graph', () => {
+    const snapshot = service.graph();
+    expect(snapshot.nodes.map((node) => node.name).sort()).toEqual([
+      'Checkout',
+      'Payments'
+    ]);
+    expect(snapshot.edges).toHaveLength(1);
+  });
+
+  it('notifies subscribers after a re-index', () => {
+    const events: GraphChangeEvent[] = [];
+    const unsubscribe = service.subscribe((event) => events.push(event));
+
+    writeFileSync(join(dir, 'src', 'ledger.ts'), LEDGER);
+    scan(dir, { dbPath, incremental: true }).close();
+    service.refresh();
+    unsubscribe();
+
+    expect(events.map((event) => `${event.type}:${event.node.name}`)).toEqual([
+      'node_added:Ledger'
+    ]);
+    expect(service.query({ query: 'Ledger' }).total).toBe(1);
+  });
+});
+
+describe('namespaced graph service', () => {
+  let dir: string;
+  let service: GraphService;
+
+  beforeEach(() => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-ns-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    for (const [repo, file, source] of [
+      ['shop', 'checkout.ts', CHECKOUT],
+      ['payments', 'payments.ts', PAYMENTS]
+    ]) {
+      mkdirSync(join(dir, repo, 'src'), { recursive: true });
+      writeFileSync(join(dir, repo, 'src', file), source);
+      scan(join(dir, repo), { dbPath: join(dir, `${repo}.db`) }).close();
+    }
+    service = createGraphService({
+      dbPath: join(dir, 'shop.db'),
+      namespace: 'acme/shop',
+      indexes: [
+        { namespace: 'acme/payments', dbPath: join(dir, 'payments.db') }
+      ],
+      pollIntervalMs: 60_000
+    });
+  });
+
+  afterEach(() => {
+    service.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function nodeOf(name: string): GraphNode {
+    const node = service.query({ query: name }).nodes[0];
+    if (!node) throw new Error(`No node named ${name}`);
+    return node;
+  }
+
+  it('prefixes node ids with the namespace of their index', () => {
+    expect(service.namespaces()).toEqual(['acme/shop', 'acme/payments']);
+    expect(nodeOf('Payments').namespace).toBe('acme/payments');
+    expect(nodeOf('Payments').id).toMatch(/^acme\/payments:/);
+    expect(nodeOf('Checkout').id).toMatch(/^acme\/shop:/);
+  });
+
+  it('resolves dependencies across namespaces', () => {
+    const details = service.getNode(nodeOf('Checkout').id);
+    expect(details?.dependencies.map((edge) => edge.to)).toEqual([
+      nodeOf('Payments').id
+    ]);
+    expect(details?.metadata).toMatchObject({ owner: 'shop-team' });
+  });
+
+  it('keeps queries, lookups, and walks to the namespaces asked for', () => {
+    const payments = nodeOf('Payments');
+    const checkout = nodeOf('Checkout');
+    expect(
+      service.query({ owner: 'payments-team', namespaces: ['acme/shop'] })
+        .total
+    ).toBe(0);
+    expect(
+      service.query({ owner: 'payments-team', namespaces: ['acme'] }).nodes
+    ).toEqual([payments]);
+    expect(service.getNode(payments.id, ['acme/shop'])).toBeUndefined();
+    expect(
+      service.getNode(checkout.id, ['acme/shop'])?.dependencies
+    ).toEqual([]);
+    const walk = service.traverse({
+      startId: checkout.id,
+      namespaces: ['acme/shop']
+    });
+    expect([...walk]).toEqual([]);
+    expect(
+      service.graph(['acme/payments']).nodes.map((node) => node.name)
+    ).toEqual(['Payments']);
+  });
+});
+
+describe('gRPC handlers', () => {
+  const NODE: GraphNode = {
+    id: 'id-a',
+    name: 'A',
+    entityType: 'service',
+    external: false,
+    filePath: 'src/a.ts',
+    owner: 'team-a',
+    domain: null,
+    workspace: null,
+    namespace: 'acme/payments'
+  };
+  const service: GraphService = {
+    namespaces: () => ['acme/payments'],
+    query: () => ({ nodes: [], total: 0 }),
+    getNode: (id, namespaces) =>
+      id === NODE.id && (!namespaces || namespaces.includes('acme/payments'))
+        ? {
+            node: NODE,
+            metadata: { owner: 'team-a', compliance: { regulations: ['PCI'] } },
+            dependencies: [],
+            dependents: []
+          }
+        : undefined,
+    traverse: () => [],
+    subscribe: () => () => {},
+    refresh: () => {},
+    close: () => {}
+  };
+
+  type GetNodeHandler = {
+    GetNode(
+      call: {
+        request: { id: string };
+        metadata?: { get(key: string): string[] };
+      },
+      callback: (
+        error: { code: number } | null,
+        response?: { node: { owner: string }; metadataJson: string }
+      ) => void
+    ): Promise<void>;
+  };
+
+  function getNode(
+    access: AccessControl | undefined,
+    id: string,
+    authorization?: string
+  ) {
+    const handlers = createGrpcHandlers(
+      service,
+      { NOT_FOUND: 5, UNAUTHENTICATED: 16 },
+      access
+    ) as GetNodeHandler;
+    return new Promise<{
+      code?: number;
+      response?: { node: { owner: string }; metadataJson: string };
+    }>((resolve) => {
+      void handlers.GetNode(
+        {
+          request: { id },
+          metadata: {
+            get: (key) =>
+              key === 'authorization' && authorization ? [authorization] : []
+          }
+        },
+        (error, response) => resolve({ code: error?.code, response })
+      );
+    });
+  }
+
+  it('maps unknown nodes to NOT_FOUND', async () => {
+    expect((await getNode(undefined, 'x')).code).toBe(5);
+  });
+
+  it('requires a bearer token and filters restricted fields', async () => {
+    const access = createAccessControl({
+      tokens: [{ name: 'ci', token: 's3cret', roles: ['reader'] }],
+      restrictedFields: { compliance: ['compliance'], owner: ['admin'] }
+    });
+    expect((await getNode(access, NODE.id)).code).toBe(16);
+
+    const { response } = await getNode(access, NODE.id, 'Bearer s3cret');
+    expect(response?.node.owner).toBe('');
+    expect(JSON.parse(response?.metadataJson ?? '{}')).toEqual({
+      owner: 'team-a'
+    });
+  });
+
+  it('hides namespaces from callers without their roles', async () => {
+    const access = createAccessControl({
+      tokens: [
+        { name: 'payments', token: 'pay', roles: ['payments'] },
+        { name: 'search', token: 'find', roles: ['search'] }
+      ],
+      namespaceRoles: { 'acme/payments': ['payments'] }
+    });
+    expect((await getNode(access, NODE.id, 'Bearer find')).code).toBe(5);
+    const { response } = await getNode(access, NODE.id, 'Bearer pay');
+    expect(response?.node.owner).toBe('team-a');
+  });
+
+  it('traces each call with the status it answered', async () => {
+    const telemetry = createTelemetry();
+    const handlers = traceGrpcHandlers(
+      createGrpcHandlers(service, { NOT_FOUND: 5, UNAUTHENTICATED: 16 }),
+      telemetry
+    ) as GetNodeHandler;
+
+    await handlers.GetNode({ request: { id: 'x' } }, () => {});
+
+    const { spans, metrics } = telemetry.drain();
+    expect(spans[0]).toMatchObject({
+      name: 'knowgraph.v1.KnowGraph/GetNode',
+      kind: 'server',
+      attributes: { 'rpc.method': 'GetNode', 'rpc.grpc.status_code': 5 }
+    });
+    expect(metrics[0]).toMatchObject({ name: 'rpc.server.duration', count: 1 });
+  });
+
+  it('ships the service definition with the package', () => {
+    expect(existsSync(PROTO_PATH)).toBe(true);
+  });
+});
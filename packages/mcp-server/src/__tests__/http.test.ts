@@ -0,0 +1,976 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { Duplex } from 'node:stream';
+import {
+  createGraphPatch,
+  createGraphStore,
+  createRegistryStore,
+  createTelemetry,
+  graphDigest,
+  scan,
+  webhookSignature
+} from '@know-graph/core';
+import type { GraphNode } from '@know-graph/core';
+import {
+  MAX_CLIENT_FRAME_BYTES,
+  acceptWebSocket,
+  encodeWebSocketFrame,
+  formatSseEvent,
+  websocketAccept
+} from '../http/events.js';
+import { parseEventTypes, startHttpServer } from '../http/server.js';
+import type { HttpServerHandle } from '../http/server.js';
+
+function service(name: string): string {
+  return `/**
+ * @knowgraph
+ * type: service
+ * description: ${name} service used by the event stream tests
+ * owner: platform-team
+ */
+export class ${name} {}
+`;
+}
+
+const NODE: GraphNode = {
+  id: 'id-a',
+  name: 'A',
+  entityType: 'service',
+  external: false,
+  filePath: 'src/a.ts',
+  owner: null,
+  domain: null,
+  workspace: null
+};
+
+describe('event encoding', () => {
+  it('formats SSE messages named after the change type', () => {
+    expect(formatSseEvent(7, { type: 'node_added', node: NODE })).toBe(
+      `id: 7\nevent: node_added\ndata: ${JSON.stringify({
+        type: 'node_added',
+        node: NODE
+      })}\n\n`
+    );
+  });
+
+  it('includes the old node in rename events', () => {
+    const renamed = { ...NODE, id: 'id-b', name: 'B', aliases: ['A'] };
+    expect(
+      formatSseEvent(8, { type: 'node_renamed', node: renamed, previous: NODE })
+    ).toBe(
+      `id: 8\nevent: node_renamed\ndata: ${JSON.stringify({
+        type: 'node_renamed',
+        node: renamed,
+        previous: NODE
+      })}\n\n`
+    );
+  });
+
+  it('computes the RFC 6455 accept key', () => {
+    expect(websocketAccept('dGhlIHNhbXBsZSBub25jZQ==')).toBe(
+      's3pPLMBiTxaQ9kYGzzhZRbK+xOo='
+    );
+  });
+
+  it('uses extended lengths for larger frames', () => {
+    expect([...encodeWebSocketFrame('hi')]).toEqual([0x81, 2, 104, 105]);
+    const medium = encodeWebSocketFrame('x'.repeat(300));
+    expect(medium[1]).toBe(126);
+    expect(medium.readUInt16BE(2)).toBe(300);
+  });
+
+  it('closes with 1009 on client frames over the cap', () => {
+    const written: Buffer[] = [];
+    const socket = new Duplex({
+      read() {},
+      write(chunk: Buffer, _encoding, callback) {
+        written.push(chunk);
+        callback();
+      }
+    });
+    let closed = false;
+    acceptWebSocket('dGhlIHNhbXBsZSBub25jZQ==', socket).onClose(() => {
+      closed = true;
+    });
+
+    const ping = Buffer.from([0x89, 0x80, 0, 0, 0, 0]);
+    socket.emit('data', ping);
+    expect([...written[written.length - 1]]).toEqual([0x8a, 0]);
+
+    // Only the header arrives: the declared length alone is refused
+    const header = Buffer.alloc(14);
+    header[0] = 0x81;
+    header[1] = 0x80 | 127;
+    header.writeBigUInt64BE(BigInt(MAX_CLIENT_FRAME_BYTES + 1), 2);
+    socket.emit('data', header);
+    expect([...written[written.length - 1]]).toEqual([0x88, 2, 0x03, 0xf1]);
+    expect(closed).toBe(true);
+  });
+
+  it('parses the types filter', () => {
+    const url = (query: string) => new URL(`http://localhost/events${query}`);
+    expect(parseEventTypes(url(''))?.size).toBe(4);
+    expect([...(parseEventTypes(url('?types=node_removed')) ?? [])]).toEqual([
+      'node_removed'
+    ]);
+    expect(parseEventTypes(url('?types=node_moved'))).toBeUndefined();
+  });
+});
+
+describe('startHttpServer', () => {
+  let dir: string;
+  let dbPath: string;
+  let server: HttpServerHandle;
+
+  beforeEach(async () => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-http-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'checkout.ts'), service('Checkout'));
+    dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+    server = await startHttpServer({ dbPath, port: 0, pollIntervalMs: 20 });
+  });
+
+  afterEach(async () => {
+    await server.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('reports health', async () => {
+    const res = await fetch(`http://127.0.0.1:${server.port}/healthz`);
+    expect(await res.json()).toEqual({ status: 'ok' });
+  });
+
+  it('traces requests by route when given telemetry', async () => {
+    const telemetry = createTelemetry();
+    const traced = await startHttpServer({ dbPath, port: 0, telemetry });
+    try {
+      await (await fetch(`http://127.0.0.1:${traced.port}/healthz`)).text();
+      await (await fetch(`http://127.0.0.1:${traced.port}/nope`)).text();
+      // Spans end on the response's close event, just after the body
+      await new Promise((resolve) => setTimeout(resolve, 50));
+    } finally {
+      await traced.close();
+    }
+
+    const { spans, metrics } = telemetry.drain();
+    expect(spans.map((span) => span.name)).toEqual(['GET /healthz', 'GET *']);
+    expect(spans[1].attributes).toMatchObject({
+      'url.path': '/nope',
+      'http.response.status_code': 404
+    });
+    expect(metrics[0]).toMatchObject({
+      name: 'http.server.request.duration',
+      unit: 's',
+      count: 1
+    });
+  });
+
+  it('rejects unknown event types', async () => {
+    const res = await fetch(
+      `http://127.0.0.1:${server.port}/events?types=bogus`
+    );
+    expect(res.status).toBe(400);
+  });
+
+  it('streams node events over SSE after a re-index', async () => {
+    const controller = new AbortController();
+    const res = await fetch(`http://127.0.0.1:${server.port}/events`, {
+      signal: controller.signal
+    });
+    expect(res.headers.get('content-type')).toBe('text/event-stream');
+    const reader = res.body!.getReader();
+    const decoder = new TextDecoder();
+
+    writeFileSync(join(dir, 'src', 'payments.ts'), service('Payments'));
+    scan(dir, { dbPath, incremental: true }).close();
+
+    let text = '';
+    while (!text.includes('event: node_added')) {
+      const { value, done } = await reader.read();
+      if (done) break;
+      text += decoder.decode(value);
+    }
+    controller.abort();
+
+    expect(text).toContain('event: node_added');
+    expect(text).toContain('"name":"Payments"');
+  });
+});
+
+describe('startHttpServer with auth', () => {
+  let dir: string;
+  let server: HttpServerHandle;
+
+  beforeEach(async () => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-http-auth-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'checkout.ts'), service('Checkout'));
+    const dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+    server = await startHttpServer({
+      dbPath,
+      port: 0,
+      auth: { tokens: [{ name: 'bot', token: 'bot-token', roles: [] }] }
+    });
+  });
+
+  afterEach(async () => {
+    await server.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('rejects event streams without a valid token', async () => {
+    const res = await fetch(`http://127.0.0.1:${server.port}/events`, {
+      headers: { Authorization: 'Bearer wrong' }
+    });
+    expect(res.status).toBe(401);
+    expect(res.headers.get('www-authenticate')).toBe('Bearer');
+  });
+
+  it('accepts the token as a header or query parameter', async () => {
+    const base = `http://127.0.0.1:${server.port}/events`;
+    for (const [url, headers] of [
+      [base, { Authorization: 'Bearer bot-token' }],
+      [`${base}?access_token=bot-token`, {}]
+    ] as const) {
+      const controller = new AbortController();
+      const res = await fetch(url, { headers, signal: controller.signal });
+      expect(res.status).toBe(200);
+      controller.abort();
+    }
+  });
+
+  it('takes the query parameter on the event stream only', async () => {
+    const res = await fetch(
+      `http://127.0.0.1:${server.port}/graph/v1/nodes?access_token=bot-token`
+    );
+    expect(res.status).toBe(401);
+  });
+
+  it('keeps health checks open', async () => {
+    const res = await fetch(`http://127.0.0.1:${server.port}/healthz`);
+    expect(res.status).toBe(200);
+  });
+});
+
+describe('graph API', () => {
+  let dir: string;
+  let dbPath: string;
+  let server: HttpServerHandle;
+  let base: string;
+
+  const CHECKOUT = `/**
+ * @knowgraph
+ * type: service
+ * description: Checkout service that depends on payments
+ * owner: shop-team
+ * dependencies:
+ *   services: [Payments]
+ */
+export class Checkout {}
+`;
+
+  beforeEach(async () => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-graph-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'checkout.ts'), CHECKOUT);
+    writeFileSync(join(dir, 'src', 'payments.ts'), service('Payments'));
+    dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+    server = await startHttpServer({
+      dbPath,
+      port: 0,
+      pollIntervalMs: 20,
+      auth: {
+        tokens: [
+          { name: 'analyst', token: 'analyst-token', roles: ['analyst'] },
+          { name: 'bot', token: 'bot-token', roles: [] }
+        ],
+        restrictedFields: { owner: ['analyst'] }
+      },
+      savedQueries: {
+        'shop-services': {
+          description: 'Services the shop team owns',
+          type: 'service',
+          owner: 'shop-team'
+        }
+      }
+    });
+    base = `http://127.0.0.1:${server.port}/graph/v1`;
+  });
+
+  afterEach(async () => {
+    await server.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  const analyst = { Authorization: 'Bearer analyst-token' };
+
+  async function get(path: string, headers: Record<string, string> = analyst) {
+    const res = await fetch(`${base}/${path}`, { headers });
+    return { status: res.status, body: await res.json() };
+  }
+
+  it('queries nodes a page at a time', async () => {
+    const { body } = await get('nodes?owner=shop-team&limit=10');
+    expect(body.total).toBe(1);
+    expect(body.nodes.map((node: GraphNode) => node.name)).toEqual([
+      'Checkout'
+    ]);
+  });
+
+  it('returns one node with its fields and edges', async () => {
+    const { body: found } = await get('nodes?query=Payments');
+    const id = found.nodes[0].id;
+    const { body } = await get(`nodes/${encodeURIComponent(id)}`);
+    expect(body.node.name).toBe('Payments');
+    expect(body.metadata).toMatchObject({ type: 'service' });
+    expect(body.dependents).toHaveLength(1);
+    expect((await get('nodes/missing')).status).toBe(404);
+  });
+
+  it('traverses from a node', async () => {
+    const { body: found } = await get('nodes?query=Checkout');
+    const startId = encodeURIComponent(found.nodes[0].id);
+    const { body } = await get(`traverse?startId=${startId}&maxDepth=2`);
+    expect(
+      body.steps.map((step: { node: GraphNode }) => step.node.name)
+    ).toEqual(['Payments']);
+  });
+
+  it('serves a snapshot without restricted fields', async () => {
+    const { body } = await get('snapshot', {
+      Authorization: 'Bearer bot-token'
+    });
+    expect(body.nodes).toHaveLength(2);
+    expect(body.edges).toHaveLength(1);
+    expect(
+      body.nodes.find((node: GraphNode) => node.name === 'Checkout').owner
+    ).toBeNull();
+  });
+
+  it('rejects bad requests', async () => {
+    expect((await get('nodes?limit=-1')).status).toBe(400);
+    expect((await get('traverse')).status).toBe(400);
+    expect((await get('traverse?startId=x&direction=up')).status).toBe(400);
+    expect((await get('edges')).status).toBe(404);
+    expect((await fetch(`${base}/snapshot`)).status).toBe(401);
+  });
+
+  it('pages through sorted nodes with cursors', async () => {
+    const first = await get('nodes?sort=-name&limit=1');
+    expect(first.body.nodes.map((node: GraphNode) => node.name)).toEqual([
+      'Payments'
+    ]);
+    expect(first.body.total).toBe(2);
+    const cursor = encodeURIComponent(first.body.nextCursor);
+    const second = await get(`nodes?sort=-name&limit=1&cursor=${cursor}`);
+    expect(second.body.nodes.map((node: GraphNode) => node.name)).toEqual([
+      'Checkout'
+    ]);
+    expect(second.body).not.toHaveProperty('nextCursor');
+    // A cursor only continues the list it came from
+    expect((await get(`nodes?sort=name&cursor=${cursor}`)).status).toBe(400);
+  });
+
+  it('filters nodes and traversal steps by field', async () => {
+    const { body } = await get('nodes?filter=owner:shop-team');
+    expect(body.nodes.map((node: GraphNode) => node.name)).toEqual([
+      'Checkout'
+    ]);
+    const startId = encodeURIComponent(body.nodes[0].id);
+    const kept = await get(
+      `traverse?startId=${startId}&filter=node.name:Payments`
+    );
+    expect(kept.body.steps).toHaveLength(1);
+    const none = await get(`traverse?startId=${startId}&filter=depth:2`);
+    expect(none.body.steps).toEqual([]);
+    expect((await get('nodes?sort=color')).status).toBe(400);
+    expect((await get('nodes?filter=owner')).status).toBe(400);
+    expect((await get('nodes?cursor=abc&offset=1')).status).toBe(400);
+  });
+
+  it('answers 304 until a re-index changes the graph', async () => {
+    const first = await fetch(`${base}/snapshot`, { headers: analyst });
+    const etag = first.headers.get('etag') ?? '';
+    expect(etag).not.toBe('');
+    expect(first.headers.get('cache-control')).toBe('private, no-cache');
+    await first.text();
+
+    const cached = { ...analyst, 'If-None-Match': etag };
+    const unchanged = await fetch(`${base}/snapshot`, { headers: cached });
+    expect(unchanged.status).toBe(304);
+    expect(unchanged.headers.get('etag')).toBe(etag);
+    // Another caller sees other fields, so the tag is not theirs
+    const bot = await fetch(`${base}/snapshot`, {
+      headers: { Authorization: 'Bearer bot-token', 'If-None-Match': etag }
+    });
+    expect(bot.status).toBe(200);
+    await bot.text();
+
+    writeFileSync(join(dir, 'src', 'inventory.ts'), service('Inventory'));
+    scan(dir, { dbPath, incremental: true }).close();
+    let changed = await fetch(`${base}/snapshot`, { headers: cached });
+    for (let tries = 0; changed.status === 304 && tries < 50; tries++) {
+      await new Promise((resolve) => setTimeout(resolve, 20));
+      changed = await fetch(`${base}/snapshot`, { headers: cached });
+    }
+    expect(changed.status).toBe(200);
+    expect(changed.headers.get('etag')).not.toBe(etag);
+    expect((await changed.json()).nodes).toHaveLength(3);
+  });
+
+  it('honors If-Modified-Since for queries but not for one node', async () => {
+    const page = await fetch(`${base}/nodes?query=Payments`, {
+      headers: analyst
+    });
+    const lastModified = page.headers.get('last-modified') ?? '';
+    const id = encodeURIComponent((await page.json()).nodes[0].id);
+    const since = { ...analyst, 'If-Modified-Since': lastModified };
+    expect(
+      (await fetch(`${base}/nodes?query=Payments`, { headers: since })).status
+    ).toBe(304);
+
+    const node = await fetch(`${base}/nodes/${id}`, { headers: analyst });
+    expect(node.headers.get('last-modified')).not.toBeNull();
+    const etag = node.headers.get('etag') ?? '';
+    await node.text();
+    const cached = await fetch(`${base}/nodes/${id}`, {
+      headers: { ...analyst, 'If-None-Match': `W/${etag}, "other"` }
+    });
+    expect(cached.status).toBe(304);
+    const dated = await fetch(`${base}/nodes/${id}`, { headers: since });
+    expect(dated.status).toBe(200);
+    await dated.text();
+  });
+
+  it('runs saved queries by name and links to them', async () => {
+    const { body } = await get('queries');
+    expect(body.items).toEqual([
+      {
+        name: 'shop-services',
+        description: 'Services the shop team owns',
+        type: 'service',
+        owner: 'shop-team',
+        url: '/graph/v1/nodes?type=service&owner=shop-team'
+      }
+    ]);
+    const linked = await fetch(new URL(body.items[0].url, base), {
+      headers: analyst
+    });
+    const saved = await get('nodes?saved=shop-services');
+    expect(saved.body).toEqual(await linked.json());
+    expect(saved.body.nodes.map((node: GraphNode) => node.name)).toEqual([
+      'Checkout'
+    ]);
+
+    const limited = await get('nodes?saved=shop-services&limit=0');
+    expect(limited.body.nodes).toEqual([]);
+    expect((await get('nodes?saved=missing')).status).toBe(404);
+    expect((await get('queries/missing')).status).toBe(404);
+  });
+});
+
+describe('registry API', () => {
+  let dir: string;
+  let server: HttpServerHandle;
+  let base: string;
+
+  const admin = { Authorization: 'Bearer admin-token' };
+
+  beforeEach(async () => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-registry-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'checkout.ts'), service('Checkout'));
+    const dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+    server = await startHttpServer({
+      dbPath,
+      port: 0,
+      auth: {
+        tokens: [{ name: 'platform', token: 'admin-token', roles: ['admin'] }]
+      },
+      registry: {
+        store: createRegistryStore(join(dir, 'registry.json')),
+        adminRoles: ['admin'],
+        graphs: createGraphStore(join(dir, 'graphs'))
+      }
+    });
+    base = `http://127.0.0.1:${server.port}/registry/v1`;
+  });
+
+  afterEach(async () => {
+    await server.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function put(path: string, body: object, headers: object = admin) {
+    return fetch(`${base}/${path}`, {
+      method: 'PUT',
+      headers: { ...headers, 'Content-Type': 'application/json' },
+      body: JSON.stringify(body)
+    });
+  }
+
+  it('creates, reads, replaces, and deletes a namespace', async () => {
+    const created = await put('namespaces/acme/payments', {
+      roles: ['payments']
+    });
+    expect(created.status).toBe(201);
+    expect(await created.json()).toMatchObject({
+      name: 'acme/payments',
+      roles: ['payments'],
+      revision: 1
+    });
+
+    expect((await put('namespaces/acme/payments', {})).status).toBe(200);
+    const read = await fetch(`${base}/namespaces/acme/payments`, {
+      headers: admin
+    });
+    expect(await read.json()).toMatchObject({ roles: [], revision: 2 });
+
+    const url = `${base}/namespaces/acme/payments`;
+    const removed = await fetch(url, { method: 'DELETE', headers: admin });
+    expect(removed.status).toBe(204);
+    const again = await fetch(url, { method: 'DELETE', headers: admin });
+    expect(again.status).toBe(404);
+  });
+
+  it('issues tokens that authenticate once created', async () => {
+    const created = await put('tokens/ci', { roles: ['reader'] });
+    const { token, ...view } = await created.json();
+    expect(view).not.toHaveProperty('tokenHash');
+
+    const events = new AbortController();
+    const res = await fetch(`http://127.0.0.1:${server.port}/events`, {
+      headers: { Authorization: `Bearer ${token}` },
+      signal: events.signal
+    });
+    expect(res.status).toBe(200);
+    events.abort();
+
+    const list = await fetch(`${base}/tokens`, {
+      headers: { Authorization: `Bearer ${token}` }
+    });
+    expect(list.status).toBe(403);
+  });
+
+  it('lets any caller read policy bundles but only admins change them', async () => {
+    const { token } = await (await put('tokens/ci', { roles: [] })).json();
+    const caller = { Authorization: `Bearer ${token}` };
+    await put('policy-bundles/strict', {
+      rules: { 'owner-required': 'error' }
+    });
+
+    const read = await fetch(`${base}/policy-bundles/strict`, {
+      headers: caller
+    });
+    expect(await read.json()).toMatchObject({
+      rules: { 'owner-required': 'error' }
+    });
+    const change = await put('policy-bundles/strict', { rules: {} }, caller);
+    expect(change.status).toBe(403);
+  });
+
+  it('lets any caller read saved queries and run them', async () => {
+    const { token } = await (await put('tokens/ci', { roles: [] })).json();
+    const caller = { Authorization: `Bearer ${token}` };
+    const saved = await put('queries/services', { type: 'service' });
+    expect(saved.status).toBe(201);
+
+    const read = await fetch(`${base}/queries/services`, { headers: caller });
+    expect(await read.json()).toMatchObject({ type: 'service' });
+    const graph = `http://127.0.0.1:${server.port}/graph/v1`;
+    const run = await fetch(`${graph}/nodes?saved=services`, {
+      headers: caller
+    });
+    expect((await run.json()).total).toBe(1);
+    const change = await put('queries/services', {}, caller);
+    expect(change.status).toBe(403);
+  });
+
+  it('lists a page at a time in the order asked for', async () => {
+    await put('namespaces/acme/a', {});
+    await put('namespaces/acme/b', {});
+    await put('namespaces/acme/b', {});
+    const first = await fetch(`${base}/namespaces?sort=-revision&limit=1`, {
+      headers: admin
+    });
+    const page = await first.json();
+    expect(page.items.map((item: { name: string }) => item.name)).toEqual([
+      'acme/b'
+    ]);
+    const cursor = encodeURIComponent(page.nextCursor);
+    const next = await fetch(
+      `${base}/namespaces?sort=-revision&limit=1&cursor=${cursor}`,
+      { headers: admin }
+    );
+    expect(await next.json()).toMatchObject({ items: [{ name: 'acme/a' }] });
+  });
+
+  it('rejects invalid bodies and unknown kinds', async () => {
+    const invalid = await put('tokens/ci', { role: ['admin'] });
+    expect(invalid.status).toBe(400);
+    expect((await invalid.json()).error).toContain("Invalid tokens 'ci'");
+
+    const unknown = await fetch(`${base}/teams/payments`, { headers: admin });
+    expect(unknown.status).toBe(404);
+    const anonymous = await fetch(`${base}/namespaces`);
+    expect(anonymous.status).toBe(401);
+  });
+
+  it('stores pushed graphs and applies patches to them', async () => {
+    const before = { nodes: [NODE], edges: [] };
+    const after = {
+      nodes: [NODE, { ...NODE, id: 'id-b', name: 'B' }],
+      edges: []
+    };
+    const patch = createGraphPatch(before, after);
+    const send = (method: string, body: object, headers: object = admin) =>
+      fetch(`${base}/graphs/payments`, {
+        method,
+        headers: { ...headers, 'Content-Type': 'application/json' },
+        body: JSON.stringify(body)
+      });
+
+    expect((await send('PATCH', patch)).status).toBe(404);
+    const created = await send('PUT', before);
+    expect(created.status).toBe(201);
+    expect(await created.json()).toEqual({
+      name: 'payments',
+      digest: graphDigest(before)
+    });
+
+    const patched = await send('PATCH', patch);
+    expect(patched.status).toBe(200);
+    expect((await patched.json()).digest).toBe(graphDigest(after));
+    const stale = await send('PATCH', patch);
+    expect(stale.status).toBe(409);
+    expect((await stale.json()).digest).toBe(graphDigest(after));
+
+    const read = await fetch(`${base}/graphs/payments`, { headers: admin });
+    expect(await read.json()).toMatchObject({
+      digest: graphDigest(after),
+      nodes: [{ id: 'id-a' }, { id: 'id-b' }]
+    });
+    expect((await send('PUT', { nodes: [] })).status).toBe(400);
+
+    const { token } = await (await put('tokens/ci', { roles: [] })).json();
+    const caller = { Authorization: `Bearer ${token}` };
+    expect((await send('PUT', before, caller)).status).toBe(403);
+  });
+});
+
+describe('trends API', () => {
+  let dir: string;
+  let server: HttpServerHandle;
+  let base: string;
+
+  const report = (generatedAt: string, score: number) => ({
+    generatedAt,
+    staleAfterDays: 180,
+    scorecards: [{ owner: 'checkout', score, grade: score >= 90 ? 'A' : 'C' }]
+  });
+
+  beforeEach(async () => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-trends-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'checkout.ts'), service('Checkout'));
+    const dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+    const scorecardsPath = join(dir, 'scorecards.jsonl');
+    writeFileSync(
+      scorecardsPath,
+      [
+        report('2024-05-01T00:00:00.000Z', 75),
+        report('2024-06-01T00:00:00.000Z', 91)
+      ]
+        .map((line) => JSON.stringify(line))
+        .join('\n')
+    );
+    server = await startHttpServer({
+      dbPath,
+      namespace: 'acme/payments',
+      port: 0,
+      auth: {
+        tokens: [
+          { name: 'payments', token: 'payments-token', roles: ['payments'] },
+          { name: 'other', token: 'other-token', roles: [] }
+        ],
+        namespaceRoles: { 'acme/payments': ['payments'] }
+      },
+      trends: [
+        {
+          namespace: 'acme/payments',
+          historyPath: join(dir, 'history.jsonl'),
+          scorecardsPath
+        }
+      ]
+    });
+    base = `http://127.0.0.1:${server.port}/trends/v1`;
+  });
+
+  afterEach(async () => {
+    await server.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  const as = (token: string) => ({ Authorization: `Bearer ${token}` });
+
+  it('ranks teams by their latest recorded grade', async () => {
+    const res = await fetch(`${base}/leaderboard`, {
+      headers: as('payments-token')
+    });
+    expect(await res.json()).toMatchObject({
+      repositories: 1,
+      entries: [
+        {
+          rank: 1,
+          namespace: 'acme/payments',
+          owner: 'checkout',
+          score: 91,
+          previousScore: 75,
+          trend: 'up'
+        }
+      ]
+    });
+  });
+
+  it('serves score and coverage trends', async () => {
+    const teams = await fetch(`${base}/teams?since=2024-06-01`, {
+      headers: as('payments-token')
+    });
+    const { items } = await teams.json();
+    expect(items[0].points).toEqual([
+      { timestamp: '2024-06-01T00:00:00.000Z', score: 91, grade: 'A' }
+    ]);
+    const coverage = await fetch(`${base}/coverage`, {
+      headers: as('payments-token')
+    });
+    expect(await coverage.json()).toEqual({
+      items: [{ namespace: 'acme/payments', points: [] }]
+    });
+  });
+
+  it('filters leaderboard entries', async () => {
+    const res = await fetch(`${base}/leaderboard?filter=grade:C`, {
+      headers: as('payments-token')
+    });
+    expect(await res.json()).toMatchObject({ repositories: 1, entries: [] });
+  });
+
+  it('hides namespaces the caller may not see', async () => {
+    const res = await fetch(`${base}/leaderboard`, {
+      headers: as('other-token')
+    });
+    expect(await res.json()).toMatchObject({ repositories: 0, entries: [] });
+  });
+
+  it('rejects bad requests', async () => {
+    const headers = as('payments-token');
+    expect((await fetch(`${base}/teams?since=soon`, { headers })).status).toBe(
+      400
+    );
+    expect((await fetch(`${base}/owners`, { headers })).status).toBe(404);
+    expect((await fetch(`${base}/teams?sort=score`, { headers })).status).toBe(
+      400
+    );
+    expect((await fetch(`${base}/leaderboard`)).status).toBe(401);
+  });
+});
+
+describe('inbound webhooks', () => {
+  let dir: string;
+  let server: HttpServerHandle;
+  let base: string;
+
+  beforeEach(async () => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-webhooks-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'payments.ts'), service('Payments'));
+    const dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+    server = await startHttpServer({
+      dbPath,
+      port: 0,
+      webhooks: {
+        logPath: join(dir, 'facts.jsonl'),
+        sources: {
+          argo: {
+            secret: 'shh',
+            signatureHeader: 'X-Hub-Signature-256',
+            mapping: {
+              node: 'app.name',
+              facts: { version: 'app.version', environment: 'env' }
+            }
+          }
+        }
+      }
+    });
+    base = `http://127.0.0.1:${server.port}`;
+  });
+
+  afterEach(async () => {
+    await server.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function post(path: string, body: string, secret = 'shh') {
+    return fetch(`${base}/webhooks/v1/${path}`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        'X-Hub-Signature-256': webhookSignature(secret, body)
+      },
+      body
+    });
+  }
+
+  it('logs signed facts and shows them with the node', async () => {
+    const body = JSON.stringify({
+      app: { name: 'Payments', version: '2.1.0' },
+      env: 'prod'
+    });
+    const res = await post('argo', body);
+    expect(res.status).toBe(202);
+    expect(await res.json()).toMatchObject({
+      source: 'argo',
+      node: 'Payments',
+      facts: { version: '2.1.0', environment: 'prod' }
+    });
+
+    const found = await fetch(`${base}/graph/v1/nodes?query=Payments`);
+    const id = (await found.json()).nodes[0].id;
+    const node = await fetch(`${base}/graph/v1/nodes/${encodeURIComponent(id)}`);
+    const { facts } = await node.json();
+    expect(
+      facts.map((fact: { name: string; value: string }) => [
+        fact.name,
+        fact.value
+      ])
+    ).toEqual([
+      ['environment', 'prod'],
+      ['version', '2.1.0']
+    ]);
+  });
+
+  it('rejects a delivery replayed with its signature', async () => {
+    const body = JSON.stringify({
+      app: { name: 'Payments', version: '2.2.0' }
+    });
+    expect((await post('argo', body)).status).toBe(202);
+    const replay = await post('argo', body);
+    expect(replay.status).toBe(409);
+    expect(await replay.json()).toEqual({
+      error: 'This delivery was already received'
+    });
+  });
+
+  it('rejects unsigned, unmapped, and unknown deliveries', async () => {
+    const body = JSON.stringify({ app: { name: 'Payments' } });
+    expect((await post('argo', body, 'wrong')).status).toBe(401);
+    expect((await post('argo', body)).status).toBe(422);
+    expect((await post('argo', 'not json')).status).toBe(400);
+    expect((await post('jenkins', body)).status).toBe(404);
+    const get = await fetch(`${base}/webhooks/v1/argo`);
+    expect(get.status).toBe(405);
+  });
+});
+
+describe('merge request simulation API', () => {
+  let dir: string;
+  let server: HttpServerHandle;
+  let base: string;
+
+  beforeEach(async () => {
+    dir = join(
+      tmpdir(),
+      `knowgraph-simulate-${Date.now()}-${Math.random().toString(36).slice(2)}`
+    );
+    mkdirSync(join(dir, 'src'), { recursive: true });
+    writeFileSync(join(dir, 'src', 'payments.ts'), service('Payments'));
+    const dbPath = join(dir, 'knowgraph.db');
+    scan(dir, { dbPath }).close();
+    server = await startHttpServer({
+      dbPath,
+      port: 0,
+      simulation: { rootDir: dir }
+    });
+    base = `http://127.0.0.1:${server.port}`;
+  });
+
+  afterEach(async () => {
+    await server.close();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  function simulate(body: object) {
+    return fetch(`${base}/simulate/v1/merge-requests`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(body)
+    });
+  }
+
+  it('answers with the graph changes of a diff, writing nothing', async () => {
+    const diff = [
+      '--- a/src/payments.ts',
+      '+++ b/src/payments.ts',
+      '@@ -4,3 +4,5 @@',
+      '  * description: Payments service used by the event stream tests',
+      '  * owner: platform-team',
+      '+ * dependencies:',
+      '+ *   databases: [ledger-db]',
+      '  */'
+    ].join('\n');
+    const res = await simulate({ diff });
+    expect(res.status).toBe(200);
+    const result = await res.json();
+    expect(result.files).toEqual([
+      { path: 'src/payments.ts', status: 'modified', entities: 1 }
+    ]);
+    expect(result.changes.edges.upsert).toEqual([
+      expect.objectContaining({
+        to: 'external:database:ledger-db',
+        kind: 'database'
+      })
+    ]);
+    expect(result.violations).toEqual([]);
+
+    const found = await fetch(`${base}/graph/v1/nodes?query=ledger`);
+    expect((await found.json()).total).toBe(0);
+  });
+
+  it('rejects malformed diffs and diffs that do not apply', async () => {
+    expect((await simulate({ diff: 'not a diff' })).status).toBe(400);
+    expect((await simulate({})).status).toBe(400);
+    const stale =
+      '--- a/src/payments.ts\n+++ b/src/payments.ts\n@@ -1 +1 @@\n-x\n+y\n';
+    expect((await simulate({ diff: stale })).status).toBe(422);
+    const other = await simulate({ diff: stale, namespace: 'acme/other' });
+    expect(other.status).toBe(404);
+    const get = await fetch(`${base}/simulate/v1/merge-requests`);
+    expect(get.status).toBe(405);
+  });
+});
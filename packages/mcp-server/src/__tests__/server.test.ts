@@ -1,6 +1,6 @@
 import { describe, it, expect } from 'vitest';
 import { McpServer } from '@modelcontextprotocol/sdk/server/mcp.js';
-import { createServer } from '../server.js';
+import { createServer, startServer } from '../server.js';
 
 describe('createServer', () => {
   it('creates an MCP server instance', () => {
@@ -22,3 +22,16 @@ describe('createServer', () => {
     ).not.toThrow();
   });
 });
+
+describe('startServer', () => {
+  it('does not start when the signal is already aborted', async () => {
+    const controller = new AbortController();
+    controller.abort();
+    await expect(
+      startServer({
+        dbPath: '/nonexistent/path/db.sqlite',
+        signal: controller.signal,
+      })
+    ).rejects.toMatchObject({ name: 'AbortError' });
+  });
+});
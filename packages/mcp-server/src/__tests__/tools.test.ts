@@ -136,6 +136,33 @@ describe('MCP Tools Integration', () => {
       expect(text).toContain('Auth Design Doc');
     });
 
+    it('lists linked commits and the last change', async () => {
+      ctx.rawDb.prepare('UPDATE entities SET git = ? WHERE id = ?').run(
+        JSON.stringify({
+          last_commit: '0123456789abcdef',
+          last_author: 'ada@example.com',
+          last_modified: '2024-05-01T10:00:00Z',
+          linked_commits: [
+            {
+              commit: 'fedcba9876543210',
+              author: 'lin@example.com',
+              date: '2024-05-03T09:00:00Z',
+              subject: 'Rotate session keys',
+            },
+          ],
+        }),
+        'auth-service',
+      );
+      const result = await callTool(server, 'get_entity_details', {
+        entity_id: 'auth-service',
+      });
+      const text = result.content[0].text;
+      expect(text).toContain('### Recent Changes');
+      expect(text).toContain(
+        '- `fedcba9` Rotate session keys by lin@example.com on 2024-05-03T09:00:00Z\n- `0123456` by ada@example.com',
+      );
+    });
+
     it('returns error for missing entity', async () => {
       const result = await callTool(server, 'get_entity_details', {
         entity_id: 'nonexistent',
@@ -0,0 +1,257 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Bearer-token authentication (static tokens, JWT/OIDC) and role-based field filtering for serve mode
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [auth, rbac, security, tokens, jwt]
+ * context:
+ *   business_goal: Keep the internal graph off the network unless callers authenticate, and hide sensitive fields by role
+ *   domain: mcp-server
+ */
+import { createHash, timingSafeEqual } from 'node:crypto';
+import { matchesNamespace } from '@know-graph/core';
+import type { GraphNode, RegistryStore } from '@know-graph/core';
+import { createJwksResolver, verifyJwt } from './jwt.js';
+import type { JwtClaims, KeyResolver } from './jwt.js';
+
+export interface Principal {
+  /** Token name for static tokens, `sub` for JWTs. */
+  readonly subject: string;
+  readonly roles: readonly string[];
+}
+
+export interface StaticToken {
+  readonly name: string;
+  readonly token: string;
+  readonly roles: readonly string[];
+}
+
+export interface JwtAuthOptions {
+  readonly issuer?: string;
+  readonly audience?: string;
+  /** Key set URL; discovered from `issuer` when omitted. */
+  readonly jwksUri?: string;
+  /** Shared secret for HS256 tokens instead of a key set. */
+  readonly secret?: string;
+  /** Claim holding the caller's roles, dotted for nested claims. */
+  readonly rolesClaim?: string;
+  readonly fetch?: typeof fetch;
+}
+
+export interface AuthOptions {
+  readonly tokens?: readonly StaticToken[];
+  readonly jwt?: JwtAuthOptions;
+  /**
+   * Fields only the listed roles may see: node fields (`owner`, `domain`,
+   * `filePath`, `workspace`) or top-level annotation fields such as
+   * `compliance`.
+   */
+  readonly restrictedFields?: Readonly<Record<string, readonly string[]>>;
+  /**
+   * Namespaces, prefixes of them such as `acme`, or `*`, mapped to the
+   * roles that may see their nodes. A namespace several entries match is
+   * visible to the roles of any of them; unmatched namespaces and nodes
+   * without one are visible to every caller.
+   */
+  readonly namespaceRoles?: Readonly<Record<string, readonly string[]>>;
+  /**
+   * Tokens and namespace rules managed through the registry API. They are
+   * read on every request, so changes apply without a restart. With a
+   * registry, callers always need a token.
+   */
+  readonly registry?: RegistryStore;
+}
+
+export interface AccessControl {
+  /** Whether callers must present a bearer token. */
+  readonly required: boolean;
+  /**
+   * Resolve the caller from an `Authorization` header value. Undefined
+   * means reject the request.
+   */
+  authorize(authorization: string | undefined): Promise<Principal | undefined>;
+  /** Whether nodes in `namespace` are visible to `principal`. */
+  canSeeNamespace(namespace: string | undefined, principal: Principal): boolean;
+  filterNode(node: GraphNode, principal: Principal): GraphNode;
+  filterMetadata(
+    metadata: Readonly<Record<string, unknown>>,
+    principal: Principal,
+  ): Readonly<Record<string, unknown>>;
+}
+
+export const ANONYMOUS: Principal = { subject: 'anonymous', roles: [] };
+
+const NODE_FIELDS = ['owner', 'domain', 'filePath', 'workspace'] as const;
+
+type NamespaceRule = readonly [pattern: string, roles: readonly string[]];
+
+/** The token from a `Bearer` authorization header. */
+export function bearerToken(
+  authorization: string | undefined,
+): string | undefined {
+  const match = /^Bearer\s+(\S+)\s*$/i.exec(authorization ?? '');
+  return match?.[1];
+}
+
+/**
+ * Read roles from `claim` (dotted for nested claims such as
+ * `realm_access.roles`). Accepts an array or a space-separated string.
+ */
+export function rolesFromClaims(
+  claims: JwtClaims,
+  claim: string,
+): readonly string[] {
+  let value: unknown = claims;
+  for (const key of claim.split('.')) {
+    value =
+      typeof value === 'object' && value !== null
+        ? (value as Record<string, unknown>)[key]
+        : undefined;
+  }
+  if (typeof value === 'string') return value.split(/\s+/).filter(Boolean);
+  if (Array.isArray(value)) {
+    return value.filter((role): role is string => typeof role === 'string');
+  }
+  return [];
+}
+
+/**
+ * The namespaces a request may read: those in `hosted` that match
+ * `requested` (every one when it is empty) and that `principal` may see.
+ * Undefined when neither narrows what is hosted, so nodes without a
+ * namespace are served too.
+ */
+export function visibleNamespaces(
+  access: AccessControl,
+  principal: Principal,
+  hosted: readonly string[],
+  requested: readonly string[] = [],
+): readonly string[] | undefined {
+  const visible = hosted.filter(
+    (namespace) =>
+      (requested.length === 0 ||
+        requested.some((pattern) => matchesNamespace(namespace, pattern))) &&
+      access.canSeeNamespace(namespace, principal),
+  );
+  return requested.length === 0 && visible.length === hosted.length
+    ? undefined
+    : visible;
+}
+
+function digest(value: string): Buffer {
+  return createHash('sha256').update(value).digest();
+}
+
+/**
+ * Build the access rules for a server. Without `tokens` or `jwt`, every
+ * caller is anonymous: no credentials are checked and restricted fields
+ * and namespaces stay hidden.
+ */
+export function createAccessControl(options: AuthOptions = {}): AccessControl {
+  const tokens = (options.tokens ?? []).map((token) => ({
+    ...token,
+    digest: digest(token.token),
+  }));
+  const jwt = options.jwt;
+  let keys: KeyResolver | undefined;
+  if (jwt && !jwt.secret) {
+    keys = createJwksResolver({
+      jwksUri: jwt.jwksUri,
+      issuer: jwt.issuer,
+      fetch: jwt.fetch,
+    });
+  }
+  const { registry } = options;
+  const required =
+    tokens.length > 0 || jwt !== undefined || registry !== undefined;
+  const restricted = Object.entries(options.restrictedFields ?? {});
+  const configuredRules = Object.entries(options.namespaceRoles ?? {});
+
+  /** The configured rules, then those of registry namespaces with roles. */
+  function namespaceRules(): readonly NamespaceRule[] {
+    const managed = (registry?.list('namespaces') ?? []).flatMap(
+      ({ resource }): NamespaceRule[] =>
+        resource.roles.length > 0 ? [[resource.name, resource.roles]] : [],
+    );
+    return [...configuredRules, ...managed];
+  }
+
+  /** The registry token whose hash matches `presented`, if any. */
+  function issuedToken(presented: Buffer): Principal | undefined {
+    const match = registry?.list('tokens').find(({ resource }) => {
+      const hash = Buffer.from(resource.tokenHash, 'hex');
+      return (
+        hash.length === presented.length && timingSafeEqual(hash, presented)
+      );
+    });
+    if (!match) return undefined;
+    return { subject: match.resource.name, roles: match.resource.roles };
+  }
+
+  const hidden = (principal: Principal): ReadonlySet<string> =>
+    new Set(
+      restricted
+        .filter(([, roles]) => !roles.some((r) => principal.roles.includes(r)))
+        .map(([field]) => field),
+    );
+
+  async function authorize(
+    authorization: string | undefined,
+  ): Promise<Principal | undefined> {
+    if (!required) return ANONYMOUS;
+    const token = bearerToken(authorization);
+    if (!token) return undefined;
+
+    // Fixed-length digests let every comparison run in constant time
+    const presented = digest(token);
+    const match = tokens.find((t) => timingSafeEqual(t.digest, presented));
+    if (match) return { subject: match.name, roles: match.roles };
+    const issued = issuedToken(presented);
+    if (issued) return issued;
+
+    if (!jwt) return undefined;
+    try {
+      const claims = await verifyJwt(token, {
+        issuer: jwt.issuer,
+        audience: jwt.audience,
+        secret: jwt.secret,
+        keys,
+      });
+      return {
+        subject: typeof claims.sub === 'string' ? claims.sub : 'unknown',
+        roles: rolesFromClaims(claims, jwt.rolesClaim ?? 'roles'),
+      };
+    } catch {
+      return undefined;
+    }
+  }
+
+  return {
+    required,
+    authorize,
+    canSeeNamespace: (namespace, principal) => {
+      const rules = namespaceRules().filter(([pattern]) =>
+        matchesNamespace(namespace, pattern),
+      );
+      const allowed = (roles: readonly string[]): boolean =>
+        roles.some((role) => principal.roles.includes(role));
+      return rules.length === 0 || rules.some(([, roles]) => allowed(roles));
+    },
+    filterNode: (node, principal) => {
+      const fields = hidden(principal);
+      if (fields.size === 0) return node;
+      const filtered: Record<string, unknown> = { ...node };
+      for (const field of NODE_FIELDS) {
+        if (fields.has(field)) filtered[field] = null;
+      }
+      return filtered as unknown as GraphNode;
+    },
+    filterMetadata: (metadata, principal) => {
+      const fields = hidden(principal);
+      return Object.fromEntries(
+        Object.entries(metadata).filter(([key]) => !fields.has(key)),
+      );
+    },
+  };
+}
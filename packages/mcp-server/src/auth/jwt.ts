@@ -0,0 +1,199 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: JWT verification (HS256, RS256, ES256) with OIDC discovery and cached JWKS key lookup
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [auth, jwt, oidc, jwks, security]
+ * context:
+ *   business_goal: Accept identity-provider tokens on serve mode without extra dependencies
+ *   domain: mcp-server
+ */
+import {
+  createHmac,
+  createPublicKey,
+  timingSafeEqual,
+  verify,
+} from 'node:crypto';
+import type { JsonWebKey, KeyObject } from 'node:crypto';
+
+export type JwtClaims = Readonly<Record<string, unknown>>;
+
+/** Resolves the verification key for a token's `kid` and `alg`. */
+export type KeyResolver = (
+  kid: string | undefined,
+  alg: string,
+) => Promise<KeyObject | undefined>;
+
+export interface JwtVerifyOptions {
+  readonly issuer?: string;
+  readonly audience?: string;
+  /** Shared secret for HS256 tokens. */
+  readonly secret?: string;
+  /** Public keys for RS256 and ES256 tokens. */
+  readonly keys?: KeyResolver;
+  /** Allowed clock skew for `exp` and `nbf` (default: 60s). */
+  readonly leewaySeconds?: number;
+  readonly now?: () => number;
+}
+
+export interface JwksResolverOptions {
+  /** Key set URL; discovered from `issuer` when omitted. */
+  readonly jwksUri?: string;
+  readonly issuer?: string;
+  readonly fetch?: typeof fetch;
+  /** Minimum time between key set fetches (default: 60s). */
+  readonly refreshMs?: number;
+  readonly now?: () => number;
+}
+
+const KEY_TYPES: Readonly<Record<string, string>> = {
+  RS256: 'rsa',
+  ES256: 'ec',
+};
+
+interface JwtHeader {
+  readonly alg?: string;
+  readonly kid?: string;
+}
+
+function decodeSegment(segment: string): unknown {
+  return JSON.parse(Buffer.from(segment, 'base64url').toString('utf-8'));
+}
+
+function isRecord(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+async function verifySignature(
+  header: JwtHeader,
+  signed: string,
+  signature: Buffer,
+  options: JwtVerifyOptions,
+): Promise<boolean> {
+  const data = Buffer.from(signed);
+  if (header.alg === 'HS256') {
+    if (!options.secret) return false;
+    const expected = createHmac('sha256', options.secret).update(data).digest();
+    return (
+      expected.length === signature.length &&
+      timingSafeEqual(expected, signature)
+    );
+  }
+  const alg = header.alg ?? '';
+  const keyType = KEY_TYPES[alg];
+  if (!keyType) return false;
+  const key = await options.keys?.(header.kid, alg);
+  // Never verify with a key meant for another algorithm family
+  if (!key || key.asymmetricKeyType !== keyType) return false;
+  return alg === 'RS256'
+    ? verify('sha256', data, key, signature)
+    : verify('sha256', data, { key, dsaEncoding: 'ieee-p1363' }, signature);
+}
+
+/**
+ * Verify a compact JWT and return its claims. Throws when the signature,
+ * algorithm, issuer, audience, or validity window does not check out.
+ */
+export async function verifyJwt(
+  token: string,
+  options: JwtVerifyOptions,
+): Promise<JwtClaims> {
+  const parts = token.split('.');
+  if (parts.length !== 3) throw new Error('Malformed JWT');
+  const [headerPart, payloadPart, signaturePart] = parts as [
+    string,
+    string,
+    string,
+  ];
+
+  let header: unknown;
+  let claims: unknown;
+  try {
+    header = decodeSegment(headerPart);
+    claims = decodeSegment(payloadPart);
+  } catch {
+    throw new Error('Malformed JWT');
+  }
+  if (!isRecord(header) || !isRecord(claims)) {
+    throw new Error('Malformed JWT');
+  }
+
+  const valid = await verifySignature(
+    header as JwtHeader,
+    `${headerPart}.${payloadPart}`,
+    Buffer.from(signaturePart, 'base64url'),
+    options,
+  );
+  if (!valid) throw new Error('Invalid JWT signature');
+
+  const now = (options.now ?? Date.now)() / 1000;
+  const leeway = options.leewaySeconds ?? 60;
+  if (typeof claims.exp === 'number' && now > claims.exp + leeway) {
+    throw new Error('JWT has expired');
+  }
+  if (typeof claims.nbf === 'number' && now < claims.nbf - leeway) {
+    throw new Error('JWT is not valid yet');
+  }
+  if (options.issuer && claims.iss !== options.issuer) {
+    throw new Error(`JWT issuer is not ${options.issuer}`);
+  }
+  if (options.audience) {
+    const audiences = Array.isArray(claims.aud) ? claims.aud : [claims.aud];
+    if (!audiences.includes(options.audience)) {
+      throw new Error(`JWT audience does not include ${options.audience}`);
+    }
+  }
+  return claims;
+}
+
+/**
+ * Look up signing keys from a JWKS endpoint, using OIDC discovery on
+ * `issuer` when no `jwksUri` is given. Keys are cached; an unknown `kid`
+ * triggers a refetch at most once per `refreshMs`, so rotated keys are
+ * picked up without letting bad tokens hammer the identity provider.
+ */
+export function createJwksResolver(options: JwksResolverOptions): KeyResolver {
+  const fetchJson = async (url: string): Promise<Record<string, unknown>> => {
+    const res = await (options.fetch ?? fetch)(url);
+    if (!res.ok) throw new Error(`GET ${url} returned ${res.status}`);
+    const body: unknown = await res.json();
+    if (!isRecord(body)) throw new Error(`GET ${url} did not return JSON`);
+    return body;
+  };
+  const now = options.now ?? Date.now;
+  const refreshMs = options.refreshMs ?? 60_000;
+  let keys = new Map<string, KeyObject>();
+  let fetchedAt = -Infinity;
+
+  async function jwksUri(): Promise<string> {
+    if (options.jwksUri) return options.jwksUri;
+    if (!options.issuer) throw new Error('JWKS needs a jwksUri or issuer');
+    const base = options.issuer.replace(/\/$/, '');
+    const config = await fetchJson(`${base}/.well-known/openid-configuration`);
+    if (typeof config.jwks_uri !== 'string') {
+      throw new Error(`${options.issuer} does not advertise a jwks_uri`);
+    }
+    return config.jwks_uri;
+  }
+
+  async function refresh(): Promise<void> {
+    fetchedAt = now();
+    const body = await fetchJson(await jwksUri());
+    const next = new Map<string, KeyObject>();
+    for (const jwk of Array.isArray(body.keys) ? body.keys : []) {
+      if (!isRecord(jwk) || (jwk.use !== undefined && jwk.use !== 'sig')) {
+        continue;
+      }
+      const key = createPublicKey({ key: jwk as JsonWebKey, format: 'jwk' });
+      next.set(typeof jwk.kid === 'string' ? jwk.kid : '', key);
+    }
+    keys = next;
+  }
+
+  return async (kid) => {
+    const id = kid ?? '';
+    if (!keys.has(id) && now() - fetchedAt >= refreshMs) await refresh();
+    return keys.get(id);
+  };
+}
@@ -10,6 +10,8 @@
  *   domain: mcp-server
  */
 import Database from 'better-sqlite3';
+import { isEncryptedFile, readStoreFile } from '@know-graph/core';
+import type { EncryptionOptions } from '@know-graph/core';
 
 export interface EntityRow {
   readonly id: string;
@@ -33,6 +35,8 @@ export interface EntityRow {
   readonly dependencies: string | null;
   readonly compliance: string | null;
   readonly operational: string | null;
+  /** The git enricher's `git` metadata as JSON, when the index has it. */
+  readonly git?: string | null;
 }
 
 export interface LinkRow {
@@ -273,20 +277,35 @@ function createDatabaseApi(
     db.close();
   };
 
+  // Lookups of several statements read one committed state, as a whole
+  // `knowgraph index` run commits at once
+  const snapshot = <T>(read: () => T): T => db.transaction(read).deferred();
+
   return {
     search,
     getById,
     getByOwner,
-    getDependencies,
+    getDependencies: (entityId, depth) =>
+      snapshot(() => getDependencies(entityId, depth)),
     getLinks,
     getByBusinessGoal,
-    getStats,
+    getStats: () => snapshot(getStats),
     close,
   };
 }
 
-export function openDatabase(dbPath: string): McpDatabase {
-  const db = new Database(dbPath, { readonly: true, fileMustExist: true });
+/**
+ * Open the index at `dbPath` read-only. An encrypted index is decrypted
+ * into memory with the key in `options`, so it reflects the file as it was
+ * when opened.
+ */
+export function openDatabase(
+  dbPath: string,
+  options: EncryptionOptions = {},
+): McpDatabase {
+  const db = isEncryptedFile(dbPath)
+    ? new Database(readStoreFile(dbPath, options), { readonly: true })
+    : new Database(dbPath, { readonly: true, fileMustExist: true });
   db.pragma('journal_mode = WAL');
   return createDatabaseApi(db, hasFtsTable(db));
 }
@@ -319,7 +338,8 @@ export function createInMemoryDatabase(): {
       revenue_impact TEXT,
       dependencies TEXT,
       compliance TEXT,
-      operational TEXT
+      operational TEXT,
+      git TEXT
     );
 
     CREATE TABLE links (
@@ -0,0 +1,475 @@
+/**
+ * @knowgraph
+ * type: service
+ * description: gRPC server exposing the KnowGraph service definition over HTTP/2
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [grpc, server, transport, protobuf]
+ * context:
+ *   business_goal: Let gRPC-only service meshes consume the graph with generated clients
+ *   domain: mcp-server
+ */
+import { performance } from 'node:perf_hooks';
+import { fileURLToPath } from 'node:url';
+import type {
+  GraphChangeEvent,
+  GraphEdge,
+  GraphNode,
+  TraversalDirection,
+  DependencyKind,
+  EdgeProvenance,
+  EntityType,
+  Telemetry,
+} from '@know-graph/core';
+import { createAccessControl, visibleNamespaces } from '../auth/access.js';
+import type { AccessControl, AuthOptions, Principal } from '../auth/access.js';
+import { createGraphService } from './service.js';
+import type { GraphService, NamespacedIndex } from './service.js';
+
+/** Path to the service definition, for generating clients. */
+export const PROTO_PATH = fileURLToPath(
+  new URL('../../proto/knowgraph/v1/knowgraph.proto', import.meta.url),
+);
+
+export interface GrpcServerOptions {
+  readonly dbPath: string;
+  /** Namespace to serve the index at `dbPath` under. */
+  readonly namespace?: string;
+  /** Further indexes to serve, each under its own namespace. */
+  readonly indexes?: readonly NamespacedIndex[];
+  readonly host?: string;
+  readonly port?: number;
+  /** How often to check the index for re-index runs (default: 1000ms). */
+  readonly pollIntervalMs?: number;
+  /** Bearer-token checks and field restrictions; anonymous when omitted. */
+  readonly auth?: AuthOptions;
+  /** Records a span and the duration of each call when set. */
+  readonly telemetry?: Telemetry;
+  /** Shuts the server down when aborted. */
+  readonly signal?: AbortSignal;
+}
+
+export interface GrpcServerHandle {
+  /** Port actually bound; differs from the requested one when that was 0. */
+  readonly port: number;
+  close(): Promise<void>;
+}
+
+// The subset of @grpc/grpc-js and @grpc/proto-loader used here. Both are
+// optional so stdio-only installs stay small.
+interface ServerCall<Request> {
+  readonly request: Request;
+  readonly metadata?: { get(key: string): readonly (string | Buffer)[] };
+}
+
+interface ServerStream<Request, Response> extends ServerCall<Request> {
+  write(message: Response): boolean;
+  end(): void;
+  emit(event: 'error', error: { code: number; details: string }): boolean;
+  on(event: 'cancelled', listener: () => void): void;
+}
+
+type UnaryCallback<Response> = (
+  error: { code: number; details: string } | null,
+  response?: Response,
+) => void;
+
+interface GrpcServer {
+  addService(service: unknown, implementation: object): void;
+  bindAsync(
+    address: string,
+    credentials: unknown,
+    callback: (error: Error | null, port: number) => void,
+  ): void;
+  tryShutdown(callback: (error?: Error) => void): void;
+}
+
+interface GrpcModule {
+  readonly Server: new () => GrpcServer;
+  readonly ServerCredentials: { createInsecure(): unknown };
+  readonly status: {
+    readonly NOT_FOUND: number;
+    readonly UNAUTHENTICATED: number;
+  };
+  loadPackageDefinition(definition: unknown): {
+    readonly knowgraph: {
+      readonly v1: { readonly KnowGraph: { readonly service: unknown } };
+    };
+  };
+}
+
+interface ProtoLoaderModule {
+  load(path: string, options: object): Promise<unknown>;
+}
+
+interface NodeMessage {
+  readonly id: string;
+  readonly name: string;
+  readonly entityType: string;
+  readonly external: boolean;
+  readonly filePath: string;
+  readonly owner: string;
+  readonly domain: string;
+  readonly workspace: string;
+  readonly annotated?: boolean;
+  readonly namespace: string;
+}
+
+interface QueryMessage {
+  readonly query: string;
+  readonly type: string;
+  readonly owner: string;
+  readonly tags: readonly string[];
+  readonly limit: number;
+  readonly offset: number;
+  readonly namespaces: readonly string[];
+}
+
+interface TraverseMessage {
+  readonly startId: string;
+  readonly direction: 'OUTGOING' | 'INCOMING' | 'BOTH';
+  readonly maxDepth: number;
+  readonly kinds: readonly string[];
+  readonly provenance: readonly string[];
+  readonly minConfidence: number;
+  readonly namespaces: readonly string[];
+}
+
+interface SubscribeMessage {
+  readonly namespaces: readonly string[];
+}
+
+const EVENT_TYPES: Record<GraphChangeEvent['type'], string> = {
+  node_added: 'NODE_ADDED',
+  node_updated: 'NODE_UPDATED',
+  node_removed: 'NODE_REMOVED',
+  node_renamed: 'NODE_RENAMED',
+};
+
+const SERVICE_NAME = 'knowgraph.v1.KnowGraph';
+
+const MISSING_DEPENDENCIES =
+  'gRPC support needs @grpc/grpc-js and @grpc/proto-loader. ' +
+  'Install them with: npm install @grpc/grpc-js @grpc/proto-loader';
+
+function toNodeMessage(node: GraphNode): NodeMessage {
+  return {
+    id: node.id,
+    name: node.name,
+    entityType: node.entityType ?? '',
+    external: node.external,
+    filePath: node.filePath ?? '',
+    owner: node.owner ?? '',
+    domain: node.domain ?? '',
+    workspace: node.workspace ?? '',
+    ...(node.annotated === undefined ? {} : { annotated: node.annotated }),
+    namespace: node.namespace ?? '',
+  };
+}
+
+function toEdgeMessage(edge: GraphEdge): GraphEdge {
+  return {
+    from: edge.from,
+    to: edge.to,
+    kind: edge.kind,
+    provenance: edge.provenance,
+    confidence: edge.confidence,
+  };
+}
+
+/**
+ * Map the KnowGraph rpcs onto a graph service, authorizing each call from
+ * its `authorization` metadata and keeping it to the namespaces the caller
+ * asked for and may see. Exported so the handlers can be exercised without
+ * binding a port.
+ */
+export function createGrpcHandlers(
+  service: GraphService,
+  status: GrpcModule['status'],
+  access: AccessControl = createAccessControl(),
+): object {
+  async function authorize(
+    call: ServerCall<unknown>,
+  ): Promise<Principal | undefined> {
+    const [value] = call.metadata?.get('authorization') ?? [];
+    return access.authorize(value?.toString());
+  }
+
+  const scope = (
+    principal: Principal,
+    requested?: readonly string[],
+  ): readonly string[] | undefined =>
+    visibleNamespaces(access, principal, service.namespaces(), requested);
+
+  const unauthenticated = {
+    code: status.UNAUTHENTICATED,
+    details: 'Missing or invalid bearer token',
+  };
+
+  return {
+    async Query(
+      call: ServerCall<QueryMessage>,
+      callback: UnaryCallback<{ nodes: NodeMessage[]; total: number }>,
+    ): Promise<void> {
+      const principal = await authorize(call);
+      if (!principal) {
+        callback(unauthenticated);
+        return;
+      }
+      const { query, type, owner, tags, limit, offset, namespaces } =
+        call.request;
+      const result = service.query({
+        query: query || undefined,
+        type: (type || undefined) as EntityType | undefined,
+        owner: owner || undefined,
+        tags: tags.length > 0 ? tags : undefined,
+        limit: limit || undefined,
+        offset: offset || undefined,
+        namespaces: scope(principal, namespaces),
+      });
+      callback(null, {
+        nodes: result.nodes.map((node) =>
+          toNodeMessage(access.filterNode(node, principal)),
+        ),
+        total: result.total,
+      });
+    },
+
+    async GetNode(
+      call: ServerCall<{ readonly id: string }>,
+      callback: UnaryCallback<object>,
+    ): Promise<void> {
+      const principal = await authorize(call);
+      if (!principal) {
+        callback(unauthenticated);
+        return;
+      }
+      const details = service.getNode(call.request.id, scope(principal));
+      if (!details) {
+        callback({
+          code: status.NOT_FOUND,
+          details: `Node not found: ${call.request.id}`,
+        });
+        return;
+      }
+      callback(null, {
+        node: toNodeMessage(access.filterNode(details.node, principal)),
+        dependencies: details.dependencies.map(toEdgeMessage),
+        dependents: details.dependents.map(toEdgeMessage),
+        metadataJson: JSON.stringify(
+          access.filterMetadata(details.metadata, principal),
+        ),
+      });
+    },
+
+    async Traverse(call: ServerStream<TraverseMessage, object>): Promise<void> {
+      const principal = await authorize(call);
+      if (!principal) {
+        call.emit('error', unauthenticated);
+        return;
+      }
+      const {
+        startId,
+        direction,
+        maxDepth,
+        kinds,
+        provenance,
+        minConfidence,
+        namespaces,
+      } = call.request;
+      let cancelled = false;
+      call.on('cancelled', () => {
+        cancelled = true;
+      });
+      const steps = service.traverse({
+        startId,
+        direction: direction.toLowerCase() as TraversalDirection,
+        maxDepth: maxDepth || undefined,
+        kinds:
+          kinds.length > 0 ? (kinds as readonly DependencyKind[]) : undefined,
+        provenance:
+          provenance.length > 0
+            ? (provenance as readonly EdgeProvenance[])
+            : undefined,
+        minConfidence: minConfidence || undefined,
+        namespaces: scope(principal, namespaces),
+      });
+      for (const step of steps) {
+        if (cancelled) return;
+        call.write({
+          node: toNodeMessage(access.filterNode(step.node, principal)),
+          depth: step.depth,
+          edge: toEdgeMessage(step.edge),
+        });
+      }
+      call.end();
+    },
+
+    async Subscribe(
+      call: ServerStream<SubscribeMessage, object>,
+    ): Promise<void> {
+      const principal = await authorize(call);
+      if (!principal) {
+        call.emit('error', unauthenticated);
+        return;
+      }
+      const namespaces = scope(principal, call.request.namespaces);
+      const unsubscribe = service.subscribe((event) => {
+        const { namespace } = event.node;
+        if (namespaces && !(namespace && namespaces.includes(namespace))) {
+          return;
+        }
+        call.write({
+          type: EVENT_TYPES[event.type],
+          node: toNodeMessage(access.filterNode(event.node, principal)),
+          ...(event.previous
+            ? {
+                previous: toNodeMessage(
+                  access.filterNode(event.previous, principal),
+                ),
+              }
+            : {}),
+        });
+      });
+      call.on('cancelled', unsubscribe);
+    },
+  };
+}
+
+/**
+ * Wrap each handler in a server span named after its rpc, recording the
+ * status it answered and `rpc.server.duration`. Streaming rpcs are timed
+ * until their handler returns, not until the stream closes.
+ */
+export function traceGrpcHandlers(
+  handlers: object,
+  telemetry: Telemetry,
+): object {
+  return Object.fromEntries(
+    Object.entries(handlers).map(([method, handler]) => {
+      const run = handler as (
+        call: ServerCall<unknown>,
+        callback?: UnaryCallback<unknown>,
+      ) => Promise<void>;
+      const attributes = {
+        'rpc.system': 'grpc',
+        'rpc.service': SERVICE_NAME,
+        'rpc.method': method,
+      };
+      const traced = (
+        call: ServerCall<unknown>,
+        callback?: UnaryCallback<unknown>,
+      ): Promise<void> => {
+        const started = performance.now();
+        let code = 0;
+        const answer: UnaryCallback<unknown> | undefined = callback
+          ? (error, response) => {
+              code = error?.code ?? 0;
+              callback(error, response);
+            }
+          : undefined;
+        return telemetry.trace(
+          `${SERVICE_NAME}/${method}`,
+          { kind: 'server', attributes },
+          async (span) => {
+            try {
+              await run(call, answer);
+            } finally {
+              const status = { 'rpc.grpc.status_code': code };
+              span.setAttributes(status);
+              telemetry.record(
+                'rpc.server.duration',
+                performance.now() - started,
+                'ms',
+                { ...attributes, ...status },
+              );
+            }
+          },
+        );
+      };
+      return [method, traced];
+    }),
+  );
+}
+
+async function loadGrpc(): Promise<[GrpcModule, ProtoLoaderModule]> {
+  // Variable specifiers keep the optional packages out of type resolution
+  const grpcPackage = '@grpc/grpc-js';
+  const loaderPackage = '@grpc/proto-loader';
+  try {
+    return await Promise.all([
+      import(grpcPackage) as Promise<GrpcModule>,
+      import(loaderPackage) as Promise<ProtoLoaderModule>,
+    ]);
+  } catch {
+    throw new Error(MISSING_DEPENDENCIES);
+  }
+}
+
+/**
+ * Serve the KnowGraph gRPC service on `host:port` (default 127.0.0.1:50051)
+ * without TLS; terminate TLS in the mesh sidecar. Clients send
+ * `authorization: Bearer <token>` metadata when `auth` is configured.
+ */
+export async function startGrpcServer(
+  options: GrpcServerOptions,
+): Promise<GrpcServerHandle> {
+  options.signal?.throwIfAborted();
+  const [grpc, protoLoader] = await loadGrpc();
+  const definition = await protoLoader.load(PROTO_PATH, {
+    enums: String,
+    defaults: true,
+    oneofs: true,
+  });
+  const { KnowGraph } = grpc.loadPackageDefinition(definition).knowgraph.v1;
+
+  const service = createGraphService({
+    dbPath: options.dbPath,
+    namespace: options.namespace,
+    indexes: options.indexes,
+    pollIntervalMs: options.pollIntervalMs,
+  });
+  const handlers = createGrpcHandlers(
+    service,
+    grpc.status,
+    createAccessControl(options.auth),
+  );
+  const server = new grpc.Server();
+  server.addService(
+    KnowGraph.service,
+    options.telemetry
+      ? traceGrpcHandlers(handlers, options.telemetry)
+      : handlers,
+  );
+
+  const address = `${options.host ?? '127.0.0.1'}:${options.port ?? 50051}`;
+  let port: number;
+  try {
+    port = await new Promise<number>((resolve, reject) => {
+      server.bindAsync(
+        address,
+        grpc.ServerCredentials.createInsecure(),
+        (error, boundPort) => (error ? reject(error) : resolve(boundPort)),
+      );
+    });
+  } catch (err) {
+    service.close();
+    throw err;
+  }
+
+  const close = (): Promise<void> =>
+    new Promise((resolve) => {
+      server.tryShutdown(() => {
+        service.close();
+        resolve();
+      });
+    });
+  options.signal?.addEventListener(
+    'abort',
+    () => {
+      void close();
+    },
+    { once: true },
+  );
+  return { port, close };
+}
@@ -0,0 +1,265 @@
+/**
+ * @knowgraph
+ * type: service
+ * description: Transport-neutral graph service backing the gRPC Query, GetNode, Traverse, and Subscribe calls
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [grpc, service, graph, query, subscriptions]
+ * context:
+ *   business_goal: Serve the graph to platforms that consume it over RPC instead of MCP
+ *   domain: mcp-server
+ */
+import { randomUUID } from 'node:crypto';
+import {
+  filterNamespaces,
+  matchesNamespace,
+  namespacedId,
+  stitchGraphs,
+  traverseGraph,
+  watchIndex,
+} from '@know-graph/core';
+import type {
+  DependencyGraph,
+  DependencyKind,
+  EdgeFilter,
+  EntityType,
+  GraphChangeEvent,
+  GraphEdge,
+  GraphNode,
+  StoredEntity,
+  TraversalDirection,
+  TraversalStep,
+} from '@know-graph/core';
+
+const DEFAULT_LIMIT = 50;
+
+export interface GraphQueryRequest {
+  readonly query?: string;
+  readonly type?: EntityType;
+  readonly owner?: string;
+  readonly tags?: readonly string[];
+  readonly limit?: number;
+  readonly offset?: number;
+  /** Search only these namespaces or prefixes (see `matchesNamespace`). */
+  readonly namespaces?: readonly string[];
+}
+
+export interface GraphQueryResponse {
+  readonly nodes: readonly GraphNode[];
+  readonly total: number;
+}
+
+export interface NodeDetails {
+  readonly node: GraphNode;
+  /** Annotation fields of the entity behind the node; empty for stubs. */
+  readonly metadata: Readonly<Record<string, unknown>>;
+  readonly dependencies: readonly GraphEdge[];
+  readonly dependents: readonly GraphEdge[];
+  /** When the entity behind the node last changed; undefined for stubs. */
+  readonly updatedAt?: string;
+}
+
+/** Identifies the state of the served graph, for caching answers. */
+export interface GraphRevision {
+  /**
+   * Changes whenever any hosted index is re-read after a re-index, and
+   * differs between service instances, so it never names two states.
+   */
+  readonly version: string;
+  /** ISO timestamp of when the newest of those reads happened. */
+  readonly modifiedAt: string;
+}
+
+export interface TraverseRequest extends EdgeFilter {
+  readonly startId: string;
+  readonly direction?: TraversalDirection;
+  readonly maxDepth?: number;
+  readonly kinds?: readonly DependencyKind[];
+  /** Walk only nodes in these namespaces and the external stubs they use. */
+  readonly namespaces?: readonly string[];
+}
+
+export type GraphEventListener = (event: GraphChangeEvent) => void;
+
+export interface GraphService {
+  /** Namespaces of the hosted indexes; empty when none has one. */
+  namespaces(): readonly string[];
+  query(request: GraphQueryRequest): GraphQueryResponse;
+  /**
+   * Undefined when no node has this id, or none in `namespaces` when they
+   * are given; edges to nodes outside them are left out.
+   */
+  getNode(id: string, namespaces?: readonly string[]): NodeDetails | undefined;
+  traverse(request: TraverseRequest): Iterable<TraversalStep>;
+  /**
+   * The whole current graph, or with `namespaces` only their nodes, the
+   * external stubs they use, and the edges between them.
+   */
+  graph(namespaces?: readonly string[]): DependencyGraph;
+  /**
+   * The entities of the index served under `namespace`, or of the one
+   * served without a namespace; undefined when there is no such index.
+   */
+  entities(namespace?: string): readonly StoredEntity[] | undefined;
+  revision(): GraphRevision;
+  /** Returns a function that removes the listener. */
+  subscribe(listener: GraphEventListener): () => void;
+  /** Check the index for changes now instead of waiting for the next poll. */
+  refresh(): void;
+  close(): void;
+}
+
+/** An index served under its own namespace. */
+export interface NamespacedIndex {
+  readonly namespace: string;
+  readonly dbPath: string;
+}
+
+export interface GraphServiceOptions {
+  readonly dbPath: string;
+  /** Namespace to serve the index at `dbPath` under, prefixing node ids. */
+  readonly namespace?: string;
+  /** Further indexes to serve alongside it, such as other teams' repos. */
+  readonly indexes?: readonly NamespacedIndex[];
+  /** How often to check the index for re-index runs (default: 1000ms). */
+  readonly pollIntervalMs?: number;
+}
+
+/**
+ * Open the index at `dbPath`, and any further `indexes`, and serve queries
+ * from their latest graphs. With several indexes, their graphs are
+ * stitched so external stubs resolve to nodes in other namespaces. The
+ * indexes are watched so results and subscribers follow `knowgraph index`
+ * runs in other processes.
+ */
+export function createGraphService(options: GraphServiceOptions): GraphService {
+  const listeners = new Set<GraphEventListener>();
+  const notify = (events: readonly GraphChangeEvent[]): void => {
+    for (const event of events) {
+      for (const listener of listeners) listener(event);
+    }
+  };
+  const sources = [
+    { namespace: options.namespace, dbPath: options.dbPath },
+    ...(options.indexes ?? []),
+  ].map(({ namespace, dbPath }) => ({
+    namespace,
+    watcher: watchIndex(dbPath, notify, {
+      intervalMs: options.pollIntervalMs,
+      graph: { namespace },
+    }),
+  }));
+  // Snapshot versions count from 1 in every process
+  const instance = randomUUID();
+  const namespaces = sources.flatMap(({ namespace }) =>
+    namespace === undefined ? [] : [namespace],
+  );
+
+  // Graphs are restitched only when one of the indexes has changed
+  let parts: readonly DependencyGraph[] = [];
+  let stitched: DependencyGraph = { nodes: [], edges: [] };
+  function graph(): DependencyGraph {
+    const latest = sources.map(({ watcher }) => watcher.graph());
+    if (latest.length === 1) return latest[0];
+    if (latest.some((part, i) => part !== parts[i])) {
+      parts = latest;
+      stitched = stitchGraphs(latest).graph;
+    }
+    return stitched;
+  }
+
+  function scoped(selected?: readonly string[]): DependencyGraph {
+    return selected ? filterNamespaces(graph(), selected) : graph();
+  }
+
+  /**
+   * The entity behind `node`, from the same snapshot of its own index as
+   * the node.
+   */
+  function entityOf(node: GraphNode): StoredEntity | undefined {
+    const source = sources.find(
+      ({ namespace }) => namespace === node.namespace,
+    );
+    const prefix = node.namespace === undefined ? '' : `${node.namespace}:`;
+    const entityId = node.id.slice(prefix.length);
+    return source?.watcher.snapshot().entity(entityId);
+  }
+
+  return {
+    namespaces: () => namespaces,
+    query: ({ namespaces: selected, ...request }) => {
+      const searched = sources.filter(
+        ({ namespace }) =>
+          !selected ||
+          selected.some((pattern) => matchesNamespace(namespace, pattern)),
+      );
+      const nodes = new Map(graph().nodes.map((node) => [node.id, node]));
+      const { limit = DEFAULT_LIMIT, offset = 0 } = request;
+      // Each index is asked for enough results to fill the merged page
+      const paged =
+        searched.length === 1
+          ? request
+          : { ...request, limit: offset + limit, offset: 0 };
+      let total = 0;
+      const found = searched.flatMap(({ namespace, watcher }) => {
+        const result = watcher.query.search(paged);
+        total += result.total;
+        return result.entities.flatMap((entity) => {
+          const id = namespace ? namespacedId(namespace, entity.id) : entity.id;
+          const node = nodes.get(id);
+          return node ? [node] : [];
+        });
+      });
+      return {
+        nodes:
+          searched.length === 1 ? found : found.slice(offset, offset + limit),
+        total,
+      };
+    },
+    getNode: (id, selected) => {
+      const current = scoped(selected);
+      const node = current.nodes.find((candidate) => candidate.id === id);
+      if (!node) return undefined;
+      const entity = entityOf(node);
+      return {
+        node,
+        metadata: { ...entity?.metadata },
+        dependencies: current.edges.filter((edge) => edge.from === id),
+        dependents: current.edges.filter((edge) => edge.to === id),
+        ...(entity ? { updatedAt: entity.updatedAt } : {}),
+      };
+    },
+    traverse: ({ startId, namespaces: selected, ...traversal }) =>
+      traverseGraph(scoped(selected), startId, traversal),
+    graph: scoped,
+    entities: (namespace) =>
+      sources
+        .find((source) => source.namespace === namespace)
+        ?.watcher.snapshot().entities,
+    revision: () => {
+      const snapshots = sources.map(({ watcher }) => watcher.snapshot());
+      return {
+        version: [
+          instance,
+          ...snapshots.map((snapshot) => snapshot.version),
+        ].join('.'),
+        modifiedAt: snapshots
+          .map((snapshot) => snapshot.takenAt)
+          .reduce((latest, takenAt) => (takenAt > latest ? takenAt : latest)),
+      };
+    },
+    subscribe: (listener) => {
+      listeners.add(listener);
+      return () => {
+        listeners.delete(listener);
+      };
+    },
+    refresh: () => {
+      for (const { watcher } of sources) watcher.poll();
+    },
+    close: () => {
+      listeners.clear();
+      for (const { watcher } of sources) watcher.close();
+    },
+  };
+}
@@ -0,0 +1,188 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Server-Sent Events and minimal WebSocket framing for pushing graph change events
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [http, sse, websocket, events, streaming]
+ * context:
+ *   business_goal: Push graph changes to browsers and bots with no client library beyond what they ship with
+ *   domain: mcp-server
+ */
+import { createHash } from 'node:crypto';
+import type { Duplex } from 'node:stream';
+import type { GraphChangeEvent } from '@know-graph/core';
+
+const WEBSOCKET_GUID = '258EAFA5-E914-47DA-95CA-C5AB0DC85B11';
+
+const OPCODE_TEXT = 0x1;
+const OPCODE_CLOSE = 0x8;
+const OPCODE_PING = 0x9;
+const OPCODE_PONG = 0xa;
+
+/** Status a server closes with for a message too large to process. */
+const CLOSE_MESSAGE_TOO_BIG = 1009;
+
+/**
+ * Clients only send pings and closes, whose payloads RFC 6455 caps at 125
+ * bytes; a frame declaring more than this is refused before it is read.
+ */
+export const MAX_CLIENT_FRAME_BYTES = 64 * 1024;
+
+/**
+ * Format one graph event as an SSE message. The event name is the change
+ * type, so browsers can `addEventListener('node_added', ...)`.
+ */
+export function formatSseEvent(id: number, event: GraphChangeEvent): string {
+  const data = JSON.stringify({
+    type: event.type,
+    node: event.node,
+    ...(event.previous ? { previous: event.previous } : {}),
+  });
+  return `id: ${id}\nevent: ${event.type}\ndata: ${data}\n\n`;
+}
+
+/** Value of `Sec-WebSocket-Accept` for a client's `Sec-WebSocket-Key`. */
+export function websocketAccept(key: string): string {
+  return createHash('sha1')
+    .update(key + WEBSOCKET_GUID)
+    .digest('base64');
+}
+
+/** Encode an unmasked, unfragmented server frame. */
+export function encodeWebSocketFrame(
+  payload: Buffer | string,
+  opcode = OPCODE_TEXT,
+): Buffer {
+  const body = typeof payload === 'string' ? Buffer.from(payload) : payload;
+  let header: Buffer;
+  if (body.length < 126) {
+    header = Buffer.from([0x80 | opcode, body.length]);
+  } else if (body.length < 0x10000) {
+    header = Buffer.alloc(4);
+    header[0] = 0x80 | opcode;
+    header[1] = 126;
+    header.writeUInt16BE(body.length, 2);
+  } else {
+    header = Buffer.alloc(10);
+    header[0] = 0x80 | opcode;
+    header[1] = 127;
+    header.writeBigUInt64BE(BigInt(body.length), 2);
+  }
+  return Buffer.concat([header, body]);
+}
+
+export interface WebSocketConnection {
+  send(text: string): void;
+  close(): void;
+  onClose(listener: () => void): void;
+}
+
+interface ClientFrame {
+  readonly opcode: number;
+  readonly payload: Buffer;
+  readonly length: number;
+}
+
+/**
+ * The first frame in `buffer`, undefined until all of it has arrived, or
+ * `too-large` as soon as its header declares a payload over the cap.
+ */
+function readClientFrame(
+  buffer: Buffer,
+): ClientFrame | 'too-large' | undefined {
+  if (buffer.length < 2) return undefined;
+  const opcode = buffer[0] & 0x0f;
+  const masked = (buffer[1] & 0x80) !== 0;
+  let length = buffer[1] & 0x7f;
+  let offset = 2;
+  if (length === 126) {
+    if (buffer.length < 4) return undefined;
+    length = buffer.readUInt16BE(2);
+    offset = 4;
+  } else if (length === 127) {
+    if (buffer.length < 10) return undefined;
+    length = Number(buffer.readBigUInt64BE(2));
+    offset = 10;
+  }
+  if (length > MAX_CLIENT_FRAME_BYTES) return 'too-large';
+  const maskOffset = offset;
+  if (masked) offset += 4;
+  if (buffer.length < offset + length) return undefined;
+
+  const payload = Buffer.from(buffer.subarray(offset, offset + length));
+  if (masked) {
+    for (let i = 0; i < payload.length; i++) {
+      payload[i] = payload[i] ^ buffer[maskOffset + (i % 4)];
+    }
+  }
+  return { opcode, payload, length: offset + length };
+}
+
+/**
+ * Complete the WebSocket handshake on an upgraded socket. Client messages
+ * are ignored apart from ping and close; the stream is server-to-client.
+ * A client frame over `MAX_CLIENT_FRAME_BYTES` closes the connection with
+ * status 1009 rather than being buffered.
+ */
+export function acceptWebSocket(
+  key: string,
+  socket: Duplex,
+): WebSocketConnection {
+  socket.write(
+    'HTTP/1.1 101 Switching Protocols\r\n' +
+      'Upgrade: websocket\r\n' +
+      'Connection: Upgrade\r\n' +
+      `Sec-WebSocket-Accept: ${websocketAccept(key)}\r\n\r\n`,
+  );
+
+  const closeListeners: (() => void)[] = [];
+  let closed = false;
+  const finish = (): void => {
+    if (closed) return;
+    closed = true;
+    for (const listener of closeListeners) listener();
+  };
+  const close = (status?: number): void => {
+    if (!closed) {
+      const payload = Buffer.alloc(status === undefined ? 0 : 2);
+      if (status !== undefined) payload.writeUInt16BE(status);
+      socket.end(encodeWebSocketFrame(payload, OPCODE_CLOSE));
+    }
+    finish();
+  };
+
+  let pending = Buffer.alloc(0);
+  socket.on('data', (chunk: Buffer) => {
+    pending = Buffer.concat([pending, chunk]);
+    for (;;) {
+      const frame = readClientFrame(pending);
+      if (!frame) return;
+      if (frame === 'too-large') {
+        pending = Buffer.alloc(0);
+        close(CLOSE_MESSAGE_TOO_BIG);
+        return;
+      }
+      pending = pending.subarray(frame.length);
+      if (frame.opcode === OPCODE_CLOSE) {
+        close();
+        return;
+      }
+      if (frame.opcode === OPCODE_PING) {
+        socket.write(encodeWebSocketFrame(frame.payload, OPCODE_PONG));
+      }
+    }
+  });
+  socket.on('close', finish);
+  socket.on('error', finish);
+
+  return {
+    send: (text) => {
+      if (!closed) socket.write(encodeWebSocketFrame(text));
+    },
+    close: () => close(),
+    onClose: (listener) => {
+      closeListeners.push(listener);
+    },
+  };
+}
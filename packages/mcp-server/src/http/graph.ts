@@ -0,0 +1,460 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: HTTP JSON API for querying nodes, reading one with its edges, traversing, and taking a snapshot of the graph
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [http, graph, query, traversal, json]
+ * context:
+ *   business_goal: Let analysts and scripts read the graph over plain HTTP, such as from a notebook
+ *   domain: mcp-server
+ */
+import { createHash } from 'node:crypto';
+import type { IncomingMessage, ServerResponse } from 'node:http';
+import { currentFacts, factsForNode, readFactLog } from '@know-graph/core';
+import type {
+  DependencyCriticality,
+  DependencyKind,
+  EdgeProvenance,
+  EntityType,
+  GraphNode,
+  SavedQueryConfig,
+  TraversalDirection,
+} from '@know-graph/core';
+import type { AccessControl, Principal } from '../auth/access.js';
+import type { GraphRevision, GraphService } from '../grpc/service.js';
+import {
+  filterAndSort,
+  nextCursor,
+  pageOf,
+  parseListParams,
+} from './listing.js';
+
+export const GRAPH_PREFIX = '/graph/v1/';
+
+// As the gRPC service pages node queries
+const DEFAULT_PAGE_SIZE = 50;
+
+// Fields list endpoints sort and filter by
+const NODE_FIELDS = [
+  'id',
+  'name',
+  'entityType',
+  'owner',
+  'domain',
+  'workspace',
+  'namespace',
+  'filePath',
+  'external',
+];
+const STEP_FIELDS = [
+  'depth',
+  ...NODE_FIELDS.map((field) => `node.${field}`),
+  'edge.kind',
+  'edge.provenance',
+  'edge.confidence',
+  'edge.protocol',
+  'edge.criticality',
+];
+
+const DIRECTIONS: readonly TraversalDirection[] = [
+  'outgoing',
+  'incoming',
+  'both',
+];
+
+// Answers fixed by the graph's revision and the request alone
+const QUERY_PATHS = new Set(['nodes', 'traverse', 'snapshot']);
+
+/** A reply, with when its content last changed if that is known. */
+type Reply = readonly [status: number, body: object, modifiedAt?: string];
+
+interface Validators {
+  readonly etag: string;
+  readonly lastModified?: string;
+}
+
+export interface GraphApiOptions {
+  /** The inbound webhook fact log; nodes come with its latest facts. */
+  readonly factsPath?: string;
+  /** Saved queries callers may run by name, read on every request. */
+  readonly savedQueries?: () => Readonly<Record<string, SavedQueryConfig>>;
+}
+
+/** A caller of the graph API and the namespaces it may read. */
+export interface GraphCaller {
+  readonly access: AccessControl;
+  readonly principal: Principal;
+  /** Undefined when it may read every hosted namespace. */
+  readonly visible: readonly string[] | undefined;
+}
+
+function list(url: URL, name: string): readonly string[] | undefined {
+  const values = (url.searchParams.get(name) ?? '')
+    .split(',')
+    .map((value) => value.trim())
+    .filter(Boolean);
+  return values.length > 0 ? values : undefined;
+}
+
+/** A non-negative number parameter; null when it is malformed. */
+function number(
+  url: URL,
+  name: string,
+  integer = true,
+): number | undefined | null {
+  const raw = url.searchParams.get(name);
+  if (raw === null || raw === '') return undefined;
+  const value = Number(raw);
+  const valid = value >= 0 && (!integer || Number.isInteger(value));
+  return valid ? value : null;
+}
+
+/**
+ * The query string of a `nodes` request running `saved`, relative to the
+ * server, so the link can be shared.
+ */
+export function savedQueryUrl(saved: SavedQueryConfig): string {
+  const params = new URLSearchParams();
+  if (saved.query !== undefined) params.set('query', saved.query);
+  if (saved.type !== undefined) params.set('type', saved.type);
+  if (saved.owner !== undefined) params.set('owner', saved.owner);
+  if (saved.tags !== undefined) params.set('tags', saved.tags.join(','));
+  if (saved.limit !== undefined) params.set('limit', String(saved.limit));
+  const search = params.toString();
+  return `${GRAPH_PREFIX}nodes${search ? `?${search}` : ''}`;
+}
+
+/**
+ * `url` with its `?saved=` query replaced by the saved query's
+ * parameters, under any the request gives itself; undefined when no query
+ * is saved by that name.
+ */
+function expandSavedQuery(
+  url: URL,
+  queries: Readonly<Record<string, SavedQueryConfig>>,
+  name: string,
+): URL | undefined {
+  const saved = Object.hasOwn(queries, name) ? queries[name] : undefined;
+  if (!saved) return undefined;
+  const expanded = new URL(savedQueryUrl(saved), url);
+  for (const [key, value] of url.searchParams) {
+    if (key !== 'saved') expanded.searchParams.set(key, value);
+  }
+  return expanded;
+}
+
+function savedQueryView(name: string, saved: SavedQueryConfig): object {
+  return { name, ...saved, url: savedQueryUrl(saved) };
+}
+
+function route(
+  url: URL,
+  service: GraphService,
+  caller: GraphCaller,
+  options: GraphApiOptions,
+): Reply {
+  const { access, principal, visible } = caller;
+  const { factsPath } = options;
+  const visibleNode = (node: GraphNode) => access.filterNode(node, principal);
+  const path = url.pathname.slice(GRAPH_PREFIX.length);
+
+  if (path === 'nodes') {
+    const params = parseListParams(url, NODE_FIELDS);
+    if (typeof params === 'string') return [400, { error: params }];
+    const request = {
+      query: url.searchParams.get('query') || undefined,
+      type: (url.searchParams.get('type') || undefined) as
+        | EntityType
+        | undefined,
+      owner: url.searchParams.get('owner') || undefined,
+      tags: list(url, 'tags'),
+      namespaces: visible,
+    };
+    // The search pages unsorted, unfiltered lists itself
+    if (params.sort.length === 0 && params.filters.length === 0) {
+      const result = service.query({
+        ...request,
+        limit: params.limit,
+        offset: params.offset,
+      });
+      return [
+        200,
+        {
+          nodes: result.nodes.map(visibleNode),
+          total: result.total,
+          nextCursor: nextCursor(params, result.nodes.length, result.total),
+        },
+      ];
+    }
+    const all = service.query({
+      ...request,
+      limit: Number.MAX_SAFE_INTEGER,
+      offset: 0,
+    });
+    const listed = filterAndSort(all.nodes.map(visibleNode), params);
+    const nodes = listed.slice(
+      params.offset,
+      params.offset + (params.limit ?? DEFAULT_PAGE_SIZE),
+    );
+    return [
+      200,
+      {
+        nodes,
+        total: listed.length,
+        nextCursor: nextCursor(params, nodes.length, listed.length),
+      },
+    ];
+  }
+
+  if (path.startsWith('nodes/')) {
+    const id = decodeURIComponent(path.slice('nodes/'.length));
+    const details = service.getNode(id, visible);
+    if (!details) return [404, { error: `Node not found: ${id}` }];
+    const facts = factsPath
+      ? factsForNode(currentFacts(readFactLog(factsPath)), details.node)
+      : undefined;
+    return [
+      200,
+      {
+        node: visibleNode(details.node),
+        metadata: access.filterMetadata(details.metadata, principal),
+        dependencies: details.dependencies,
+        dependents: details.dependents,
+        ...(facts ? { facts } : {}),
+      },
+      details.updatedAt,
+    ];
+  }
+
+  if (path === 'traverse') {
+    const startId = url.searchParams.get('startId');
+    const direction = url.searchParams.get('direction') ?? 'outgoing';
+    const maxDepth = number(url, 'maxDepth');
+    const params = parseListParams(url, STEP_FIELDS);
+    if (typeof params === 'string') return [400, { error: params }];
+    const minConfidence = number(url, 'minConfidence', false);
+    if (!startId) return [400, { error: 'startId is required' }];
+    if (!DIRECTIONS.includes(direction as TraversalDirection)) {
+      return [400, { error: `direction must be ${DIRECTIONS.join(', ')}` }];
+    }
+    if (maxDepth === null || minConfidence === null) {
+      return [400, { error: 'maxDepth and minConfidence must be numbers' }];
+    }
+    const steps = service.traverse({
+      startId,
+      direction: direction as TraversalDirection,
+      maxDepth,
+      kinds: list(url, 'kinds') as readonly DependencyKind[] | undefined,
+      provenance: list(url, 'provenance') as
+        | readonly EdgeProvenance[]
+        | undefined,
+      minConfidence,
+      protocols: list(url, 'protocol'),
+      criticalities: list(url, 'criticality') as
+        | readonly DependencyCriticality[]
+        | undefined,
+      namespaces: visible,
+    });
+    const page = pageOf(
+      [...steps].map((step) => ({
+        node: visibleNode(step.node),
+        depth: step.depth,
+        edge: step.edge,
+      })),
+      params,
+    );
+    return [200, { steps: page.items, nextCursor: page.nextCursor }];
+  }
+
+  if (path === 'queries') {
+    const params = parseListParams(url, ['name', 'type', 'owner', 'tags']);
+    if (typeof params === 'string') return [400, { error: params }];
+    const queries = Object.entries(options.savedQueries?.() ?? {}).map(
+      ([name, saved]) => savedQueryView(name, saved),
+    );
+    const page = pageOf(queries, params);
+    return [200, { items: page.items, nextCursor: page.nextCursor }];
+  }
+
+  if (path.startsWith('queries/')) {
+    const name = decodeURIComponent(path.slice('queries/'.length));
+    const queries = options.savedQueries?.() ?? {};
+    return Object.hasOwn(queries, name)
+      ? [200, savedQueryView(name, queries[name])]
+      : [404, { error: `Saved query not found: ${name}` }];
+  }
+
+  if (path === 'snapshot') {
+    const graph = service.graph(visible);
+    return [200, { nodes: graph.nodes.map(visibleNode), edges: graph.edges }];
+  }
+
+  return [404, { error: 'Not found' }];
+}
+
+function hash(text: string): string {
+  return createHash('sha256').update(text).digest('base64url');
+}
+
+/**
+ * An HTTP date for an ISO timestamp, or for a SQLite `datetime()` one,
+ * which is UTC without saying so.
+ */
+function httpDate(timestamp: string): string {
+  const iso = timestamp.includes('T')
+    ? timestamp
+    : `${timestamp.replace(' ', 'T')}Z`;
+  return new Date(iso).toUTCString();
+}
+
+/**
+ * The entity tag of a query's answer: the same for the same request by the
+ * same caller until the graph changes. The access token parameter is left
+ * out, as it names the caller and not the answer.
+ */
+function queryTag(
+  revision: GraphRevision,
+  { principal, visible }: GraphCaller,
+  url: URL,
+): string {
+  const params = [...url.searchParams]
+    .filter(([name]) => name !== 'access_token')
+    .sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0));
+  const key = JSON.stringify([
+    revision.version,
+    principal.subject,
+    principal.roles,
+    visible ?? null,
+    url.pathname,
+    params,
+  ]);
+  return `"${hash(key)}"`;
+}
+
+/**
+ * Whether the client's copy is current: its `If-None-Match` names the
+ * entity tag, or, without one and when `since` is allowed, its
+ * `If-Modified-Since` is no earlier than the last change.
+ */
+function isFresh(
+  req: IncomingMessage,
+  { etag, lastModified }: Validators,
+  since: boolean,
+): boolean {
+  const match = req.headers['if-none-match'];
+  if (match !== undefined) {
+    return match
+      .split(',')
+      .map((tag) => tag.trim().replace(/^W\//, ''))
+      .some((tag) => tag === '*' || tag === etag);
+  }
+  const modifiedSince = Date.parse(req.headers['if-modified-since'] ?? '');
+  return (
+    since &&
+    lastModified !== undefined &&
+    !isNaN(modifiedSince) &&
+    Date.parse(lastModified) <= modifiedSince
+  );
+}
+
+function cacheHeaders({ etag, lastModified }: Validators) {
+  return {
+    ETag: etag,
+    ...(lastModified ? { 'Last-Modified': lastModified } : {}),
+    // Answers depend on the caller, and must be checked before each use
+    'Cache-Control': 'private, no-cache',
+  };
+}
+
+function reply(
+  res: ServerResponse,
+  status: number,
+  text: string,
+  validators?: Validators,
+): void {
+  res.writeHead(status, {
+    'Content-Type': 'application/json',
+    ...(validators ? cacheHeaders(validators) : {}),
+  });
+  res.end(text);
+}
+
+function notModified(res: ServerResponse, validators: Validators): void {
+  res.writeHead(304, cacheHeaders(validators));
+  res.end();
+}
+
+/**
+ * Serve `/graph/v1/nodes`, a page of nodes matching `?query=`, `?type=`,
+ * `?owner=`, and `?tags=`; `/graph/v1/nodes/<id>`, one node with its
+ * annotation fields and edges; `/graph/v1/traverse?startId=`, the nodes
+ * reached from one; and `/graph/v1/snapshot`, every node and edge at once.
+ * Each answers with the same data as the gRPC service, kept to the
+ * namespaces and fields the caller may see. With `factsPath`, a node also
+ * comes with the latest facts inbound webhooks reported about it.
+ *
+ * `/graph/v1/queries` lists the saved queries, each with a shareable link
+ * to a `nodes` request holding its parameters, and `nodes?saved=<name>`
+ * runs one by name.
+ *
+ * Answers carry an `ETag` and `Last-Modified`, and a request whose
+ * `If-None-Match` or `If-Modified-Since` shows its copy is current gets
+ * 304 Not Modified. For queries the tag follows the graph's revision, so
+ * a poll between re-index runs is answered without running the query or
+ * serializing the graph. For one node it is a hash of the answer, as its
+ * edges and facts change apart from the entity, and `If-Modified-Since`
+ * alone is not enough to answer 304.
+ */
+export function handleGraphRequest(
+  req: IncomingMessage,
+  res: ServerResponse,
+  url: URL,
+  service: GraphService,
+  caller: GraphCaller,
+  options: GraphApiOptions = {},
+): void {
+  const path = url.pathname.slice(GRAPH_PREFIX.length);
+  const saved = url.searchParams.get('saved');
+  // Expanded first, so cache tags follow changes to the saved query
+  const request =
+    path === 'nodes' && saved !== null
+      ? expandSavedQuery(url, options.savedQueries?.() ?? {}, saved)
+      : url;
+  if (!request) {
+    const error = { error: `Saved query not found: ${saved}` };
+    reply(res, 404, JSON.stringify(error));
+    return;
+  }
+  if (QUERY_PATHS.has(path)) {
+    const revision = service.revision();
+    const validators = {
+      etag: queryTag(revision, caller, request),
+      lastModified: httpDate(revision.modifiedAt),
+    };
+    if (isFresh(req, validators, true)) {
+      notModified(res, validators);
+      return;
+    }
+    const [status, body] = route(request, service, caller, options);
+    const text = JSON.stringify(body);
+    reply(res, status, text, status === 200 ? validators : undefined);
+    return;
+  }
+
+  const [status, body, modifiedAt] = route(request, service, caller, options);
+  const text = JSON.stringify(body);
+  if (status !== 200) {
+    reply(res, status, text);
+    return;
+  }
+  const validators = {
+    etag: `"${hash(text)}"`,
+    ...(modifiedAt ? { lastModified: httpDate(modifiedAt) } : {}),
+  };
+  if (isFresh(req, validators, false)) {
+    notModified(res, validators);
+  } else {
+    reply(res, status, text, validators);
+  }
+}
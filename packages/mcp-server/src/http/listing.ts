@@ -0,0 +1,212 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: Shared cursor pagination, field filters, and sort orders for the list endpoints of the HTTP APIs
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [http, pagination, cursor, filtering, sorting]
+ * context:
+ *   business_goal: Keep list answers small enough for UIs to page through org-scale graphs
+ *   domain: mcp-server
+ */
+import { createHash } from 'node:crypto';
+
+// Parameters that choose a page rather than the list it is a page of
+const PAGE_PARAMS = new Set(['cursor', 'limit', 'offset', 'access_token']);
+
+interface SortKey {
+  readonly field: string;
+  readonly descending: boolean;
+}
+
+interface FieldFilter {
+  readonly field: string;
+  readonly value: string;
+}
+
+/** The page, order, and filters a list request asks for. */
+export interface ListParams {
+  /** Undefined for the rest of the list. */
+  readonly limit: number | undefined;
+  readonly offset: number;
+  readonly sort: readonly SortKey[];
+  readonly filters: readonly FieldFilter[];
+  /** Identifies the list apart from the page, so cursors stay with it. */
+  readonly scope: string;
+}
+
+/** One page of a list. */
+export interface Page<T> {
+  readonly items: readonly T[];
+  /** Where the next page starts; undefined on the last page. */
+  readonly nextCursor: string | undefined;
+}
+
+function count(url: URL, name: string): number | undefined | null {
+  const raw = url.searchParams.get(name);
+  if (raw === null || raw === '') return undefined;
+  const value = Number(raw);
+  return Number.isInteger(value) && value >= 0 ? value : null;
+}
+
+function terms(url: URL, name: string): readonly string[] {
+  return (url.searchParams.get(name) ?? '')
+    .split(',')
+    .map((term) => term.trim())
+    .filter(Boolean);
+}
+
+function scopeOf(url: URL): string {
+  const params = [...url.searchParams]
+    .filter(([name]) => !PAGE_PARAMS.has(name))
+    .sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0));
+  return createHash('sha256')
+    .update(JSON.stringify([url.pathname, params]))
+    .digest('base64url')
+    .slice(0, 16);
+}
+
+function offsetOf(cursor: string, scope: string): number | undefined {
+  try {
+    const decoded: unknown = JSON.parse(
+      Buffer.from(cursor, 'base64url').toString('utf-8'),
+    );
+    if (!Array.isArray(decoded)) return undefined;
+    const [offset, from] = decoded as unknown[];
+    return Number.isInteger(offset) && from === scope
+      ? (offset as number)
+      : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Read `?limit=`, `?cursor=` or `?offset=`, `?sort=`, and `?filter=` for a
+ * list whose items have `fields`, dotted for nested ones. `sort` lists
+ * fields, each descending with a leading `-`; `filter` lists `field:value`
+ * terms an item must all match. Returns an error message for anything
+ * malformed, such as a field not in `fields` or a cursor from another list.
+ */
+export function parseListParams(
+  url: URL,
+  fields: readonly string[],
+): ListParams | string {
+  const scope = scopeOf(url);
+  const limit = count(url, 'limit');
+  const offset = count(url, 'offset');
+  if (limit === null || offset === null) {
+    return 'limit and offset must be non-negative integers';
+  }
+  const cursor = url.searchParams.get('cursor');
+  let start = offset ?? 0;
+  if (cursor) {
+    if (offset !== undefined) return 'Give cursor or offset, not both';
+    const decoded = offsetOf(cursor, scope);
+    if (decoded === undefined) {
+      return 'cursor is malformed or belongs to another list';
+    }
+    start = decoded;
+  }
+
+  const sort: SortKey[] = [];
+  for (const term of terms(url, 'sort')) {
+    const descending = term.startsWith('-');
+    const field = descending ? term.slice(1) : term;
+    if (!fields.includes(field)) {
+      return `Cannot sort by ${field}; use ${fields.join(', ')}`;
+    }
+    sort.push({ field, descending });
+  }
+
+  const filters: FieldFilter[] = [];
+  for (const term of terms(url, 'filter')) {
+    const split = term.indexOf(':');
+    const field = split === -1 ? term : term.slice(0, split);
+    if (split === -1 || !fields.includes(field)) {
+      return `filter takes field:value terms with a field of ${fields.join(', ')}`;
+    }
+    filters.push({ field, value: term.slice(split + 1) });
+  }
+
+  return { limit, offset: start, sort, filters, scope };
+}
+
+function valueAt(item: unknown, field: string): unknown {
+  let value = item;
+  for (const key of field.split('.')) {
+    if (typeof value !== 'object' || value === null) return undefined;
+    value = (value as Record<string, unknown>)[key];
+  }
+  return value;
+}
+
+/** Whether `value` reads as `text`; unset fields read as empty. */
+function matches(value: unknown, text: string): boolean {
+  if (Array.isArray(value)) return value.some((item) => matches(item, text));
+  return String(value ?? '') === text;
+}
+
+function isUnset(value: unknown): boolean {
+  return value === undefined || value === null;
+}
+
+/** Numbers in order, anything else as text. */
+function compare(a: unknown, b: unknown): number {
+  if (typeof a === 'number' && typeof b === 'number') return a - b;
+  const [textA, textB] = [String(a), String(b)];
+  return textA < textB ? -1 : textA > textB ? 1 : 0;
+}
+
+/** `items` kept to the filters and put in the sort order of `params`. */
+export function filterAndSort<T>(
+  items: readonly T[],
+  params: ListParams,
+): readonly T[] {
+  const kept = items.filter((item) =>
+    params.filters.every(({ field, value }) =>
+      matches(valueAt(item, field), value),
+    ),
+  );
+  if (params.sort.length === 0) return kept;
+  return [...kept].sort((a, b) => {
+    for (const { field, descending } of params.sort) {
+      const [valueA, valueB] = [valueAt(a, field), valueAt(b, field)];
+      // Unset values go last in either direction
+      const unset = Number(isUnset(valueA)) - Number(isUnset(valueB));
+      if (unset !== 0) return unset;
+      if (isUnset(valueA)) continue;
+      const order = compare(valueA, valueB);
+      if (order !== 0) return descending ? -order : order;
+    }
+    return 0;
+  });
+}
+
+/**
+ * The cursor of the page after one of `shown` items at `params.offset`, in
+ * a list of `total`; undefined when nothing follows it.
+ */
+export function nextCursor(
+  params: ListParams,
+  shown: number,
+  total: number,
+): string | undefined {
+  const next = params.offset + shown;
+  if (shown === 0 || next >= total) return undefined;
+  return Buffer.from(JSON.stringify([next, params.scope])).toString(
+    'base64url',
+  );
+}
+
+/** The page of `items` that `params` asks for, filtered and sorted. */
+export function pageOf<T>(items: readonly T[], params: ListParams): Page<T> {
+  const listed = filterAndSort(items, params);
+  const end =
+    params.limit === undefined ? listed.length : params.offset + params.limit;
+  const page = listed.slice(params.offset, end);
+  return {
+    items: page,
+    nextCursor: nextCursor(params, page.length, listed.length),
+  };
+}
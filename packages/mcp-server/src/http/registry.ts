@@ -0,0 +1,317 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: REST API for creating, reading, replacing, and deleting registry namespaces, access tokens, and policy bundles, and for receiving pushed graphs and patches
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [http, registry, crud, terraform, tokens, policy, patch]
+ * context:
+ *   business_goal: Let infrastructure-as-code tools converge registry state with plain REST calls
+ *   domain: mcp-server
+ */
+import type { IncomingMessage, ServerResponse } from 'node:http';
+import {
+  GRAPHS_PATH,
+  isKnowgraphError,
+  isRegistryKind,
+  parseGraphPatch,
+} from '@know-graph/core';
+import type {
+  DependencyGraph,
+  GraphPatch,
+  GraphPushResult,
+  GraphStore,
+  RegistryKind,
+  RegistryRecord,
+  RegistryResources,
+  RegistryStore,
+} from '@know-graph/core';
+import type { Principal } from '../auth/access.js';
+import { pageOf, parseListParams } from './listing.js';
+
+export const REGISTRY_PREFIX = '/registry/v1/';
+
+/** Request bodies are a few fields; anything larger is a mistake. */
+const MAX_BODY_BYTES = 1024 * 1024;
+
+/** Full graphs of large monorepos run to tens of megabytes. */
+const MAX_GRAPH_BYTES = 256 * 1024 * 1024;
+
+// Kinds any authenticated caller may read
+const READABLE_KINDS: readonly RegistryKind[] = ['policy-bundles', 'queries'];
+
+// Fields registry lists sort and filter by
+const LIST_FIELDS = ['name', 'revision', 'createdAt', 'updatedAt'];
+
+export interface RegistryApiOptions {
+  readonly store: RegistryStore;
+  /** Roles that may change the registry and read its tokens. */
+  readonly adminRoles: readonly string[];
+  /** Graphs repositories push; `graphs` answers 404 without it. */
+  readonly graphs?: GraphStore;
+}
+
+type Reply = readonly [status: number, body?: object];
+
+/** A record as the API returns it: flat, and without token hashes. */
+export function registryView<K extends RegistryKind>(
+  kind: K,
+  record: RegistryRecord<RegistryResources[K]>,
+): Record<string, unknown> {
+  const resource: Record<string, unknown> = { ...record.resource };
+  if (kind === 'tokens') delete resource.tokenHash;
+  return {
+    ...resource,
+    revision: record.revision,
+    createdAt: record.createdAt,
+    updatedAt: record.updatedAt,
+  };
+}
+
+/**
+ * Split `/registry/v1/<kind>[/<name>]`. Namespace names keep their
+ * slashes. Undefined for an unknown kind or a malformed name.
+ */
+export function parseRegistryPath(
+  pathname: string,
+): { readonly kind: RegistryKind; readonly name?: string } | undefined {
+  const rest = pathname.slice(REGISTRY_PREFIX.length);
+  const split = rest.indexOf('/');
+  const kind = split === -1 ? rest : rest.slice(0, split);
+  if (!isRegistryKind(kind)) return undefined;
+  let name: string;
+  try {
+    name = split === -1 ? '' : decodeURIComponent(rest.slice(split + 1));
+  } catch {
+    return undefined;
+  }
+  return name ? { kind, name } : { kind };
+}
+
+async function readText(
+  req: IncomingMessage,
+  maxBytes: number,
+): Promise<string> {
+  const chunks: Buffer[] = [];
+  let size = 0;
+  for await (const chunk of req) {
+    size += (chunk as Buffer).length;
+    if (size > maxBytes) throw new RangeError('Request body too large');
+    chunks.push(chunk as Buffer);
+  }
+  return Buffer.concat(chunks).toString('utf-8');
+}
+
+async function readBody(req: IncomingMessage): Promise<unknown> {
+  const text = await readText(req, MAX_BODY_BYTES);
+  return text.trim() ? JSON.parse(text) : {};
+}
+
+function isGraph(value: unknown): value is DependencyGraph {
+  if (typeof value !== 'object' || value === null) return false;
+  const graph = value as Record<string, unknown>;
+  return Array.isArray(graph.nodes) && Array.isArray(graph.edges);
+}
+
+/**
+ * Store a pushed graph, or apply a pushed patch to the stored one. A
+ * patch for a graph the store does not have answers 404, and one made
+ * from a graph other than the stored one 409 with the stored digest, so
+ * the client knows to push the full graph.
+ */
+async function pushGraph(
+  req: IncomingMessage,
+  graphs: GraphStore,
+  name: string,
+): Promise<Reply> {
+  let text: string;
+  try {
+    text = await readText(req, MAX_GRAPH_BYTES);
+  } catch (err) {
+    if (err instanceof RangeError) return [413, { error: err.message }];
+    throw err;
+  }
+  let result: GraphPushResult;
+  try {
+    if (req.method === 'PUT') {
+      let graph: unknown;
+      try {
+        graph = JSON.parse(text);
+      } catch {
+        return [400, { error: 'Request body is not valid JSON' }];
+      }
+      if (!isGraph(graph)) {
+        return [400, { error: 'Expected "nodes" and "edges" arrays' }];
+      }
+      result = graphs.put(name, graph);
+    } else {
+      const patch: GraphPatch = parseGraphPatch(text);
+      const digest = graphs.digest(name);
+      if (digest === undefined) return [404, { error: `No graph '${name}'` }];
+      if (digest !== patch.base) {
+        return [
+          409,
+          { error: `Patch applies to ${patch.base}, not ${digest}`, digest },
+        ];
+      }
+      result = graphs.patch(name, patch);
+    }
+  } catch (err) {
+    if (isKnowgraphError(err) && ['parse', 'schema'].includes(err.kind)) {
+      return [400, { error: err.message }];
+    }
+    throw err;
+  }
+  return [result.created ? 201 : 200, { name, digest: result.digest }];
+}
+
+/**
+ * Answer `/registry/v1/graphs/<name>`: `GET` reads the stored graph with
+ * its digest, `PUT` replaces it whole, and `PATCH` applies a patch to it.
+ */
+async function graphRoute(
+  req: IncomingMessage,
+  graphs: GraphStore,
+  name: string,
+): Promise<Reply> {
+  switch (req.method) {
+    case 'GET': {
+      let graph: DependencyGraph | undefined;
+      try {
+        graph = graphs.get(name);
+      } catch (err) {
+        if (isKnowgraphError(err) && err.kind === 'schema') {
+          return [400, { error: err.message }];
+        }
+        throw err;
+      }
+      return graph
+        ? [200, { name, digest: graphs.digest(name), ...graph }]
+        : [404, { error: `No graph '${name}'` }];
+    }
+    case 'PUT':
+    case 'PATCH':
+      return pushGraph(req, graphs, name);
+    default:
+      return [405, { error: 'Method not allowed' }];
+  }
+}
+
+async function put(
+  req: IncomingMessage,
+  store: RegistryStore,
+  kind: RegistryKind,
+  name: string,
+): Promise<Reply> {
+  let body: unknown;
+  try {
+    body = await readBody(req);
+  } catch (err) {
+    return err instanceof RangeError
+      ? [413, { error: err.message }]
+      : [400, { error: 'Request body is not valid JSON' }];
+  }
+  try {
+    const { record, created, token } = store.put(kind, name, body);
+    const view = registryView(kind, record);
+    return [created ? 201 : 200, token ? { ...view, token } : view];
+  } catch (err) {
+    if (isKnowgraphError(err) && err.kind === 'schema') {
+      return [400, { error: err.message }];
+    }
+    throw err;
+  }
+}
+
+/**
+ * Answer a registry request from an authenticated `principal`. Admin
+ * roles may do anything; other callers may only read policy bundles, so
+ * CI jobs can fetch the bundle they lint against, and saved queries, so
+ * dashboards can list them. Pushed graphs are for admin roles alone.
+ */
+async function route(
+  req: IncomingMessage,
+  url: URL,
+  principal: Principal,
+  options: RegistryApiOptions,
+): Promise<Reply> {
+  const { store, adminRoles, graphs } = options;
+  const admin = principal.roles.some((role) => adminRoles.includes(role));
+  const forbidden: Reply = [
+    403,
+    { error: `Needs one of the roles: ${adminRoles.join(', ')}` },
+  ];
+  if (graphs && url.pathname.startsWith(GRAPHS_PATH)) {
+    let name: string;
+    try {
+      name = decodeURIComponent(url.pathname.slice(GRAPHS_PATH.length));
+    } catch {
+      return [404, { error: 'Not found' }];
+    }
+    if (!name) return [404, { error: 'Not found' }];
+    return admin ? graphRoute(req, graphs, name) : forbidden;
+  }
+
+  const target = parseRegistryPath(url.pathname);
+  if (!target) return [404, { error: 'Not found' }];
+  const { kind, name } = target;
+
+  const reading = req.method === 'GET';
+  if (!admin && !(reading && READABLE_KINDS.includes(kind))) {
+    return forbidden;
+  }
+
+  if (name === undefined) {
+    if (!reading) return [405, { error: 'Method not allowed' }];
+    const params = parseListParams(url, LIST_FIELDS);
+    if (typeof params === 'string') return [400, { error: params }];
+    const records = store.list(kind);
+    const page = pageOf(
+      records.map((r) => registryView(kind, r)),
+      params,
+    );
+    return [200, { items: page.items, nextCursor: page.nextCursor }];
+  }
+  switch (req.method) {
+    case 'GET': {
+      const record = store.get(kind, name);
+      return record
+        ? [200, registryView(kind, record)]
+        : [404, { error: `No ${kind} '${name}'` }];
+    }
+    case 'PUT':
+      return put(req, store, kind, name);
+    case 'DELETE':
+      return store.remove(kind, name)
+        ? [204]
+        : [404, { error: `No ${kind} '${name}'` }];
+    default:
+      return [405, { error: 'Method not allowed' }];
+  }
+}
+
+/**
+ * Serve `/registry/v1/<kind>[/<name>]` for `namespaces`, `tokens`,
+ * `policy-bundles`, and `queries`: `GET` lists or reads, `PUT` creates or
+ * replaces, and `DELETE` removes. Lists page, sort, and filter by their
+ * `url`'s query (see `parseListParams`). Resources are identified by name
+ * alone, so a retried `PUT` leaves the same resource and a repeated
+ * `DELETE` answers 404, as infrastructure-as-code tools expect. With a
+ * graph store, also receive pushed graphs under `/registry/v1/graphs/`.
+ */
+export async function handleRegistryRequest(
+  req: IncomingMessage,
+  res: ServerResponse,
+  url: URL,
+  principal: Principal,
+  options: RegistryApiOptions,
+): Promise<void> {
+  const [status, body] = await route(req, url, principal, options);
+  if (body === undefined) {
+    res.writeHead(status);
+    res.end();
+    return;
+  }
+  res.writeHead(status, { 'Content-Type': 'application/json' });
+  res.end(JSON.stringify(body));
+}
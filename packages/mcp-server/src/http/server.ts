@@ -0,0 +1,492 @@
+/**
+ * @knowgraph
+ * type: service
+ * description: HTTP server streaming graph change events over Server-Sent Events and WebSocket and serving the graph as JSON
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [http, server, sse, websocket, subscriptions]
+ * context:
+ *   business_goal: Let dashboards and bots react to graph changes without polling
+ *   domain: mcp-server
+ */
+import { createServer } from 'node:http';
+import type { IncomingMessage, ServerResponse } from 'node:http';
+import { performance } from 'node:perf_hooks';
+import type { Duplex } from 'node:stream';
+import type {
+  GraphChangeEvent,
+  GraphChangeType,
+  SavedQueryConfig,
+  Telemetry,
+} from '@know-graph/core';
+import { createAccessControl, visibleNamespaces } from '../auth/access.js';
+import type { AuthOptions, Principal } from '../auth/access.js';
+import { createGraphService } from '../grpc/service.js';
+import type {
+  GraphEventListener,
+  GraphService,
+  NamespacedIndex,
+} from '../grpc/service.js';
+import { acceptWebSocket, formatSseEvent } from './events.js';
+import type { WebSocketConnection } from './events.js';
+import { GRAPH_PREFIX, handleGraphRequest } from './graph.js';
+import { REGISTRY_PREFIX, handleRegistryRequest } from './registry.js';
+import type { RegistryApiOptions } from './registry.js';
+import { SIMULATE_PREFIX, handleSimulationRequest } from './simulate.js';
+import type { SimulationApiOptions } from './simulate.js';
+import { TRENDS_PREFIX, handleTrendsRequest } from './trends.js';
+import type { TrendSource } from './trends.js';
+import { WEBHOOKS_PREFIX, handleWebhookRequest } from './webhooks.js';
+import type { InboundWebhookOptions } from './webhooks.js';
+
+const HEARTBEAT_MS = 15_000;
+
+const CHANGE_TYPES: readonly GraphChangeType[] = [
+  'node_added',
+  'node_updated',
+  'node_renamed',
+  'node_removed',
+];
+
+export interface HttpServerOptions {
+  readonly dbPath: string;
+  /** Namespace to serve the index at `dbPath` under. */
+  readonly namespace?: string;
+  /** Further indexes to serve, each under its own namespace. */
+  readonly indexes?: readonly NamespacedIndex[];
+  readonly host?: string;
+  readonly port?: number;
+  /** How often to check the index for re-index runs (default: 1000ms). */
+  readonly pollIntervalMs?: number;
+  /** Bearer-token checks and field restrictions; anonymous when omitted. */
+  readonly auth?: AuthOptions;
+  /** Serves the registry API under `/registry/v1/` when set. */
+  readonly registry?: RegistryApiOptions;
+  /** Serves the trends API under `/trends/v1/` from these histories. */
+  readonly trends?: readonly TrendSource[];
+  /**
+   * Receives signed webhooks under `/webhooks/v1/` when set, and shows
+   * the facts they report with nodes in the graph API.
+   */
+  readonly webhooks?: InboundWebhookOptions;
+  /**
+   * Saved queries served under `/graph/v1/queries`, keyed by name. Those
+   * saved in the registry are served too, replacing any of the same name.
+   */
+  readonly savedQueries?: Readonly<Record<string, SavedQueryConfig>>;
+  /**
+   * The checkout and cycle budgets the simulation API under
+   * `/simulate/v1/` uses. The checkout is of the index at `dbPath` unless
+   * its `namespace` names another.
+   */
+  readonly simulation?: SimulationApiOptions;
+  /** Records a span and the duration of each request when set. */
+  readonly telemetry?: Telemetry;
+  /** Shuts the server down when aborted. */
+  readonly signal?: AbortSignal;
+}
+
+export interface HttpServerHandle {
+  /** Port actually bound; differs from the requested one when that was 0. */
+  readonly port: number;
+  close(): Promise<void>;
+}
+
+/**
+ * Read the `types` query parameter (comma-separated change types). Returns
+ * undefined when it names an unknown type; every type when it is absent.
+ */
+export function parseEventTypes(
+  url: URL,
+): ReadonlySet<GraphChangeType> | undefined {
+  const raw = url.searchParams.get('types');
+  if (!raw) return new Set(CHANGE_TYPES);
+  const types = raw.split(',').map((type) => type.trim());
+  if (!types.every((type) => CHANGE_TYPES.includes(type as GraphChangeType))) {
+    return undefined;
+  }
+  return new Set(types as GraphChangeType[]);
+}
+
+/** Read the `namespaces` query parameter (comma-separated). */
+function requestedNamespaces(url: URL): readonly string[] {
+  const raw = url.searchParams.get('namespaces') ?? '';
+  return raw
+    .split(',')
+    .map((namespace) => namespace.trim())
+    .filter(Boolean);
+}
+
+/**
+ * Subscribe `listener` to events of `types`, and with `namespaces` only to
+ * events for nodes in one of them.
+ */
+function subscribeFiltered(
+  service: GraphService,
+  types: ReadonlySet<GraphChangeType>,
+  namespaces: readonly string[] | undefined,
+  listener: GraphEventListener,
+): () => void {
+  return service.subscribe((event) => {
+    const { namespace } = event.node;
+    if (namespaces && !(namespace && namespaces.includes(namespace))) return;
+    if (types.has(event.type)) listener(event);
+  });
+}
+
+function sendJson(
+  res: ServerResponse,
+  status: number,
+  body: object,
+  headers: Readonly<Record<string, string>> = {},
+): void {
+  res.writeHead(status, { 'Content-Type': 'application/json', ...headers });
+  res.end(JSON.stringify(body));
+}
+
+/** The route a path falls under, which keeps ids out of metric attributes. */
+function routeOf(pathname: string): string {
+  for (const prefix of [
+    GRAPH_PREFIX,
+    REGISTRY_PREFIX,
+    SIMULATE_PREFIX,
+    TRENDS_PREFIX,
+    WEBHOOKS_PREFIX,
+  ]) {
+    if (pathname.startsWith(prefix)) return `${prefix}*`;
+  }
+  return pathname === '/healthz' || pathname === '/events' ? pathname : '*';
+}
+
+/**
+ * Record a server span for `req` and its `http.server.request.duration`,
+ * once the response is finished or the client goes away.
+ */
+function traceRequest(
+  telemetry: Telemetry,
+  req: IncomingMessage,
+  res: ServerResponse,
+): void {
+  const url = new URL(req.url ?? '/', 'http://localhost');
+  const method = req.method ?? 'GET';
+  const route = routeOf(url.pathname);
+  const started = performance.now();
+  const span = telemetry.startSpan(`${method} ${route}`, {
+    kind: 'server',
+    attributes: { 'url.path': url.pathname },
+  });
+  res.once('close', () => {
+    const attributes = {
+      'http.request.method': method,
+      'http.route': route,
+      'http.response.status_code': res.statusCode,
+    };
+    telemetry.record(
+      'http.server.request.duration',
+      (performance.now() - started) / 1000,
+      's',
+      attributes,
+    );
+    span.setAttributes(attributes);
+    span.end(
+      res.statusCode >= 500 ? new Error(`HTTP ${res.statusCode}`) : undefined,
+    );
+  });
+}
+
+/** The `Authorization` header, which every route but `/events` needs. */
+function credentials(req: IncomingMessage): string | undefined {
+  return req.headers.authorization;
+}
+
+/**
+ * The `Authorization` header, or an `access_token` query parameter for
+ * clients such as browser `EventSource` that cannot set headers. Only the
+ * event stream takes the parameter, as tokens in URLs end up in proxy and
+ * access logs.
+ */
+function streamCredentials(
+  req: IncomingMessage,
+  url: URL,
+): string | undefined {
+  const token = url.searchParams.get('access_token');
+  return req.headers.authorization ?? (token ? `Bearer ${token}` : undefined);
+}
+
+/**
+ * Serve `GET /events` on `host:port` (default 127.0.0.1:8080). Plain
+ * requests get a Server-Sent Events stream; requests with
+ * `Upgrade: websocket` get one JSON text message per event. Both send
+ * node_added, node_updated, node_renamed, and node_removed events as
+ * re-index runs land, filtered by `?types=` and `?namespaces=`. With
+ * `auth`, both need a bearer token, and callers get only the namespaces
+ * they may see, with nodes minus the fields their roles may not see.
+ * `GET /healthz` reports liveness and never needs a token. The graph API
+ * under `/graph/v1/` answers queries the way the gRPC service does. With
+ * `registry`, the registry API is served too, always behind a token. With
+ * `trends`, the trends API is served for the namespaces a caller may see.
+ * With `webhooks`, signed webhooks are received under `/webhooks/v1/`,
+ * authenticated by their signature rather than a token. Merge requests
+ * posted to `/simulate/v1/merge-requests` are simulated against the index
+ * of their namespace, as `simulation` describes.
+ */
+export async function startHttpServer(
+  options: HttpServerOptions,
+): Promise<HttpServerHandle> {
+  options.signal?.throwIfAborted();
+  const service = createGraphService({
+    dbPath: options.dbPath,
+    namespace: options.namespace,
+    indexes: options.indexes,
+    pollIntervalMs: options.pollIntervalMs,
+  });
+  const access = createAccessControl(
+    options.registry
+      ? { ...options.auth, registry: options.registry.store }
+      : options.auth,
+  );
+  const sockets = new Set<WebSocketConnection>();
+  let sequence = 0;
+
+  /** The namespaces `principal` asks for in `url` and may see. */
+  function scope(
+    url: URL,
+    principal: Principal,
+  ): readonly string[] | undefined {
+    return visibleNamespaces(
+      access,
+      principal,
+      service.namespaces(),
+      requestedNamespaces(url),
+    );
+  }
+
+  /** The saved queries of the options, then those in the registry. */
+  function savedQueries(): Record<string, SavedQueryConfig> {
+    const queries: Record<string, SavedQueryConfig> = {
+      ...options.savedQueries,
+    };
+    for (const { resource } of options.registry?.store.list('queries') ??
+      []) {
+      const { name, ...saved } = resource;
+      queries[name] = saved;
+    }
+    return queries;
+  }
+
+  /** `event` with node fields `principal` may not see removed. */
+  function visibleEvent(
+    event: GraphChangeEvent,
+    principal: Principal,
+  ): GraphChangeEvent {
+    return {
+      type: event.type,
+      node: access.filterNode(event.node, principal),
+      ...(event.previous
+        ? { previous: access.filterNode(event.previous, principal) }
+        : {}),
+    };
+  }
+
+  function streamEvents(
+    req: IncomingMessage,
+    res: ServerResponse,
+    types: ReadonlySet<GraphChangeType>,
+    principal: Principal,
+    namespaces: readonly string[] | undefined,
+  ): void {
+    res.writeHead(200, {
+      'Content-Type': 'text/event-stream',
+      'Cache-Control': 'no-cache',
+      Connection: 'keep-alive',
+    });
+    res.write(': connected\n\n');
+    const unsubscribe = subscribeFiltered(
+      service,
+      types,
+      namespaces,
+      (event) => {
+        res.write(formatSseEvent(++sequence, visibleEvent(event, principal)));
+      },
+    );
+    const heartbeat = setInterval(() => res.write(': ping\n\n'), HEARTBEAT_MS);
+    req.on('close', () => {
+      clearInterval(heartbeat);
+      unsubscribe();
+    });
+  }
+
+  async function handle(
+    req: IncomingMessage,
+    res: ServerResponse,
+  ): Promise<void> {
+    const url = new URL(req.url ?? '/', 'http://localhost');
+    if (options.registry && url.pathname.startsWith(REGISTRY_PREFIX)) {
+      const principal = await access.authorize(credentials(req));
+      if (!principal) {
+        sendJson(
+          res,
+          401,
+          { error: 'Missing or invalid bearer token' },
+          { 'WWW-Authenticate': 'Bearer' },
+        );
+      } else {
+        await handleRegistryRequest(
+          req,
+          res,
+          url,
+          principal,
+          options.registry,
+        );
+      }
+    } else if (options.webhooks && url.pathname.startsWith(WEBHOOKS_PREFIX)) {
+      await handleWebhookRequest(req, res, url.pathname, options.webhooks);
+    } else if (url.pathname.startsWith(SIMULATE_PREFIX)) {
+      const principal = await access.authorize(credentials(req));
+      if (!principal) {
+        sendJson(
+          res,
+          401,
+          { error: 'Missing or invalid bearer token' },
+          { 'WWW-Authenticate': 'Bearer' },
+        );
+      } else {
+        await handleSimulationRequest(
+          req,
+          res,
+          url,
+          service,
+          { access, principal, visible: scope(url, principal) },
+          { namespace: options.namespace, ...options.simulation },
+        );
+      }
+    } else if (req.method !== 'GET') {
+      sendJson(res, 405, { error: 'Method not allowed' });
+    } else if (url.pathname === '/healthz') {
+      sendJson(res, 200, { status: 'ok' });
+    } else if (url.pathname.startsWith(GRAPH_PREFIX)) {
+      const principal = await access.authorize(credentials(req));
+      if (!principal) {
+        sendJson(
+          res,
+          401,
+          { error: 'Missing or invalid bearer token' },
+          { 'WWW-Authenticate': 'Bearer' },
+        );
+      } else {
+        handleGraphRequest(
+          req,
+          res,
+          url,
+          service,
+          { access, principal, visible: scope(url, principal) },
+          { factsPath: options.webhooks?.logPath, savedQueries },
+        );
+      }
+    } else if (options.trends && url.pathname.startsWith(TRENDS_PREFIX)) {
+      const principal = await access.authorize(credentials(req));
+      if (!principal) {
+        sendJson(
+          res,
+          401,
+          { error: 'Missing or invalid bearer token' },
+          { 'WWW-Authenticate': 'Bearer' },
+        );
+      } else {
+        handleTrendsRequest(res, url, options.trends, scope(url, principal));
+      }
+    } else if (url.pathname === '/events') {
+      const principal = await access.authorize(streamCredentials(req, url));
+      const types = parseEventTypes(url);
+      if (!principal) {
+        sendJson(
+          res,
+          401,
+          { error: 'Missing or invalid bearer token' },
+          { 'WWW-Authenticate': 'Bearer' },
+        );
+      } else if (!types) {
+        sendJson(res, 400, {
+          error: `Unknown event type; expected ${CHANGE_TYPES.join(', ')}`,
+        });
+      } else {
+        streamEvents(req, res, types, principal, scope(url, principal));
+      }
+    } else {
+      sendJson(res, 404, { error: 'Not found' });
+    }
+  }
+
+  async function upgrade(req: IncomingMessage, socket: Duplex): Promise<void> {
+    const url = new URL(req.url ?? '/', 'http://localhost');
+    const key = req.headers['sec-websocket-key'];
+    const types = parseEventTypes(url);
+    if (url.pathname !== '/events' || typeof key !== 'string' || !types) {
+      socket.end('HTTP/1.1 400 Bad Request\r\n\r\n');
+      return;
+    }
+    const principal = await access.authorize(streamCredentials(req, url));
+    if (!principal) {
+      socket.end(
+        'HTTP/1.1 401 Unauthorized\r\nWWW-Authenticate: Bearer\r\n\r\n',
+      );
+      return;
+    }
+    const connection = acceptWebSocket(key, socket);
+    const unsubscribe = subscribeFiltered(
+      service,
+      types,
+      scope(url, principal),
+      (event) => {
+        connection.send(JSON.stringify(visibleEvent(event, principal)));
+      },
+    );
+    sockets.add(connection);
+    connection.onClose(() => {
+      sockets.delete(connection);
+      unsubscribe();
+    });
+  }
+
+  const server = createServer((req, res) => {
+    if (options.telemetry) traceRequest(options.telemetry, req, res);
+    handle(req, res).catch(() => {
+      if (!res.headersSent) sendJson(res, 500, { error: 'Internal error' });
+      else res.end();
+    });
+  });
+  server.on('upgrade', (req: IncomingMessage, socket: Duplex) => {
+    upgrade(req, socket).catch(() => socket.destroy());
+  });
+
+  let port: number;
+  try {
+    port = await new Promise<number>((resolve, reject) => {
+      server.once('error', reject);
+      server.listen(options.port ?? 8080, options.host ?? '127.0.0.1', () => {
+        const address = server.address();
+        resolve(typeof address === 'object' && address ? address.port : 0);
+      });
+    });
+  } catch (err) {
+    service.close();
+    throw err;
+  }
+
+  const close = (): Promise<void> =>
+    new Promise((resolve) => {
+      service.close();
+      // Upgraded sockets are no longer tracked by the HTTP server
+      for (const connection of sockets) connection.close();
+      server.close(() => resolve());
+      server.closeAllConnections();
+    });
+  options.signal?.addEventListener(
+    'abort',
+    () => {
+      void close();
+    },
+    { once: true },
+  );
+  return { port, close };
+}
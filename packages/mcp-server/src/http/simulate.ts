@@ -0,0 +1,183 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: HTTP endpoint simulating a merge request from its diff, answering with the graph changes and newly failing policies
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [http, simulation, merge-request, bots, policy]
+ * context:
+ *   business_goal: Let platform bots review what a merge request does to the graph of a repo the server indexes
+ *   domain: mcp-server
+ */
+import { existsSync, readFileSync } from 'node:fs';
+import type { IncomingMessage, ServerResponse } from 'node:http';
+import { isAbsolute, relative, resolve } from 'node:path';
+import { isKnowgraphError, simulateMergeRequest } from '@know-graph/core';
+import type { CycleBudgetOptions } from '@know-graph/core';
+import type { GraphService } from '../grpc/service.js';
+import type { GraphCaller } from './graph.js';
+
+export const SIMULATE_PREFIX = '/simulate/v1/';
+
+/** Diffs of large merge requests run to megabytes; more is a mistake. */
+const MAX_BODY_BYTES = 10 * 1024 * 1024;
+
+export interface SimulationApiOptions {
+  /**
+   * A checkout of the repository of the index served under `namespace`,
+   * which files a diff changes are read from when a request does not
+   * carry them.
+   */
+  readonly rootDir?: string;
+  readonly namespace?: string;
+  /** The cycle budgets `knowgraph check` enforces. */
+  readonly cycles?: CycleBudgetOptions;
+}
+
+interface MergeRequestBody {
+  readonly diff: string;
+  readonly namespace?: string;
+  readonly files: Readonly<Record<string, string>>;
+}
+
+type Reply = readonly [status: number, body: object];
+
+async function readBody(req: IncomingMessage): Promise<unknown> {
+  const chunks: Buffer[] = [];
+  let size = 0;
+  for await (const chunk of req) {
+    size += (chunk as Buffer).length;
+    if (size > MAX_BODY_BYTES) throw new RangeError('Request body too large');
+    chunks.push(chunk as Buffer);
+  }
+  return JSON.parse(Buffer.concat(chunks).toString('utf-8'));
+}
+
+/** The request body, or why it is malformed. */
+function parseBody(body: unknown): MergeRequestBody | string {
+  if (typeof body !== 'object' || body === null || Array.isArray(body)) {
+    return 'Request body must be a JSON object';
+  }
+  const { diff, namespace, files = {} } = body as Record<string, unknown>;
+  if (typeof diff !== 'string' || diff === '') {
+    return 'diff must be a unified diff';
+  }
+  if (namespace !== undefined && typeof namespace !== 'string') {
+    return 'namespace must be a string';
+  }
+  if (
+    typeof files !== 'object' ||
+    files === null ||
+    Object.values(files).some((content) => typeof content !== 'string')
+  ) {
+    return 'files must map paths to their content at the base';
+  }
+  return {
+    diff,
+    ...(namespace !== undefined ? { namespace } : {}),
+    files: files as Record<string, string>,
+  };
+}
+
+/** The content of `path` under `rootDir`; undefined outside it. */
+function readCheckout(rootDir: string, path: string): string | undefined {
+  const absolute = resolve(rootDir, path);
+  const inside = relative(rootDir, absolute);
+  if (inside.startsWith('..') || isAbsolute(inside)) return undefined;
+  return existsSync(absolute) ? readFileSync(absolute, 'utf-8') : undefined;
+}
+
+async function route(
+  req: IncomingMessage,
+  url: URL,
+  service: GraphService,
+  caller: GraphCaller,
+  options: SimulationApiOptions,
+): Promise<Reply> {
+  if (url.pathname !== `${SIMULATE_PREFIX}merge-requests`) {
+    return [404, { error: 'Not found' }];
+  }
+  if (req.method !== 'POST') return [405, { error: 'Method not allowed' }];
+
+  let raw: unknown;
+  try {
+    raw = await readBody(req);
+  } catch (err) {
+    if (err instanceof RangeError) return [413, { error: err.message }];
+    if (err instanceof SyntaxError) {
+      return [400, { error: 'Request body is not valid JSON' }];
+    }
+    throw err;
+  }
+  const body = parseBody(raw);
+  if (typeof body === 'string') return [400, { error: body }];
+
+  const { namespace, files } = body;
+  const { access, principal, visible } = caller;
+  const entities =
+    namespace === undefined || !visible || visible.includes(namespace)
+      ? service.entities(namespace)
+      : undefined;
+  if (!entities) {
+    const name = namespace ?? 'no namespace';
+    return [404, { error: `No index served under ${name}` }];
+  }
+  const { rootDir } = options;
+  const checkout = namespace === options.namespace ? rootDir : undefined;
+  const readFile = (path: string): string | undefined => {
+    if (Object.hasOwn(files, path)) return files[path];
+    return checkout ? readCheckout(checkout, path) : undefined;
+  };
+  try {
+    const result = simulateMergeRequest(entities, body.diff, {
+      readFile,
+      graph: namespace === undefined ? {} : { namespace },
+      cycles: options.cycles,
+    });
+    const { nodes } = result.changes;
+    return [
+      200,
+      {
+        ...result,
+        changes: {
+          ...result.changes,
+          nodes: {
+            ...nodes,
+            upsert: nodes.upsert.map((node) =>
+              access.filterNode(node, principal),
+            ),
+          },
+        },
+      },
+    ];
+  } catch (err) {
+    if (!isKnowgraphError(err)) throw err;
+    if (err.kind === 'parse') return [400, { error: err.message }];
+    if (err.kind === 'usage') return [422, { error: err.message }];
+    throw err;
+  }
+}
+
+/**
+ * Serve `POST /simulate/v1/merge-requests`, whose JSON body holds a
+ * unified `diff`, the `namespace` of the index it applies to, and
+ * optionally `files`, the content of the files it changes at the base by
+ * path. Files it does not carry are read from `rootDir` when it checks
+ * out that index. The diff is applied and extracted in memory, and
+ * the answer is what `simulateMergeRequest` returns: the files changed,
+ * the graph patch, and the policy violations merging would add. Nothing
+ * is written. A malformed diff answers 400, and a diff that does not
+ * apply or a file with no base content 422.
+ */
+export async function handleSimulationRequest(
+  req: IncomingMessage,
+  res: ServerResponse,
+  url: URL,
+  service: GraphService,
+  caller: GraphCaller,
+  options: SimulationApiOptions,
+): Promise<void> {
+  const [status, body] = await route(req, url, service, caller, options);
+  res.writeHead(status, { 'Content-Type': 'application/json' });
+  res.end(JSON.stringify(body));
+}
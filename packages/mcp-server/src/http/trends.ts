@@ -0,0 +1,132 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: HTTP API serving the team leaderboard and score and coverage trends from each hosted index's recorded history
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [http, trends, leaderboard, scorecard, coverage]
+ * context:
+ *   business_goal: Let dashboards chart knowledge health from a hosted instance without direct file access
+ *   domain: mcp-server
+ */
+import type { ServerResponse } from 'node:http';
+import {
+  buildLeaderboard,
+  coverageTrends,
+  readScanHistory,
+  readScorecardHistory,
+  teamTrends,
+} from '@know-graph/core';
+import type { RepositoryHistory } from '@know-graph/core';
+import { pageOf, parseListParams } from './listing.js';
+
+export const TRENDS_PREFIX = '/trends/v1/';
+
+/** Where one hosted index keeps its recorded history. */
+export interface TrendSource {
+  /** The index's namespace; unset for an index served without one. */
+  readonly namespace?: string;
+  /** Its scan history, for coverage trends. */
+  readonly historyPath?: string;
+  /** Its `knowgraph scorecard --record` history. */
+  readonly scorecardsPath?: string;
+}
+
+type Reply = readonly [status: number, body: object];
+
+// Fields each list sorts and filters by
+const LIST_FIELDS: Readonly<Record<string, readonly string[]>> = {
+  leaderboard: [
+    'rank',
+    'namespace',
+    'owner',
+    'score',
+    'grade',
+    'previousScore',
+    'trend',
+    'recordedAt',
+  ],
+  teams: ['namespace', 'owner'],
+  coverage: ['namespace'],
+};
+
+/**
+ * The histories of the `sources` a caller may see. `visible` lists the
+ * namespaces it may read, or is undefined when it may read everything
+ * hosted, indexes without a namespace included.
+ */
+export function readRepositoryHistories(
+  sources: readonly TrendSource[],
+  visible: readonly string[] | undefined,
+): readonly RepositoryHistory[] {
+  return sources
+    .filter(
+      ({ namespace }) =>
+        visible === undefined ||
+        (namespace !== undefined && visible.includes(namespace)),
+    )
+    .map((source) => ({
+      namespace: source.namespace ?? null,
+      scans: source.historyPath ? readScanHistory(source.historyPath) : [],
+      scorecards: source.scorecardsPath
+        ? readScorecardHistory(source.scorecardsPath)
+        : [],
+    }));
+}
+
+function route(
+  url: URL,
+  sources: readonly TrendSource[],
+  visible: readonly string[] | undefined,
+): Reply {
+  const since = url.searchParams.get('since') ?? undefined;
+  if (since !== undefined && Number.isNaN(Date.parse(since))) {
+    return [400, { error: 'since must be an ISO 8601 date or time' }];
+  }
+  const path = url.pathname.slice(TRENDS_PREFIX.length);
+  if (!Object.hasOwn(LIST_FIELDS, path)) return [404, { error: 'Not found' }];
+  const params = parseListParams(url, LIST_FIELDS[path]);
+  if (typeof params === 'string') return [400, { error: params }];
+  const read = () => readRepositoryHistories(sources, visible);
+  switch (path) {
+    case 'leaderboard': {
+      const leaderboard = buildLeaderboard(read());
+      const page = pageOf(leaderboard.entries, params);
+      return [
+        200,
+        { ...leaderboard, entries: page.items, nextCursor: page.nextCursor },
+      ];
+    }
+    case 'teams': {
+      const owner = url.searchParams.get('owner') ?? undefined;
+      const page = pageOf(teamTrends(read(), { owner, since }), params);
+      return [200, { items: page.items, nextCursor: page.nextCursor }];
+    }
+    case 'coverage': {
+      const page = pageOf(coverageTrends(read(), { since }), params);
+      return [200, { items: page.items, nextCursor: page.nextCursor }];
+    }
+    default:
+      return [404, { error: 'Not found' }];
+  }
+}
+
+/**
+ * Serve `/trends/v1/leaderboard`, the latest recorded grade of every team
+ * ranked across repositories; `/trends/v1/teams`, each team's score over
+ * time, narrowed by `?owner=`; and `/trends/v1/coverage`, each
+ * repository's coverage at every scan. The last two take `?since=`, and
+ * all three page, sort, and filter their lists (see `parseListParams`).
+ * All read what `knowgraph index` and `knowgraph scorecard --record`
+ * wrote, so nothing is recomputed per request.
+ */
+export function handleTrendsRequest(
+  res: ServerResponse,
+  url: URL,
+  sources: readonly TrendSource[],
+  visible: readonly string[] | undefined,
+): void {
+  const [status, body] = route(url, sources, visible);
+  res.writeHead(status, { 'Content-Type': 'application/json' });
+  res.end(JSON.stringify(body));
+}
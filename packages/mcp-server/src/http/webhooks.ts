@@ -0,0 +1,116 @@
+/**
+ * @knowgraph
+ * type: module
+ * description: HTTP endpoint receiving signed webhooks from deploy, incident, and CMDB systems and logging the node facts they report
+ * owner: knowgraph-mcp
+ * status: experimental
+ * tags: [http, webhooks, inbound, hmac, facts]
+ * context:
+ *   business_goal: Keep the graph current with deploys, incidents, and CMDB changes between scans
+ *   domain: mcp-server
+ */
+import { createHash } from 'node:crypto';
+import type { IncomingMessage, ServerResponse } from 'node:http';
+import {
+  appendFact,
+  mapInboundPayload,
+  readFactLog,
+  verifyWebhookSignature,
+} from '@know-graph/core';
+import type { InboundMapping } from '@know-graph/core';
+
+export const WEBHOOKS_PREFIX = '/webhooks/v1/';
+
+/** Payloads are single events; anything larger is a mistake. */
+const MAX_BODY_BYTES = 1024 * 1024;
+
+/** One sender of webhooks, served at `/webhooks/v1/<name>`. */
+export interface InboundWebhookSource {
+  /** The HMAC-SHA256 secret the sender signs bodies with. */
+  readonly secret: string;
+  /** Header carrying the `sha256=<hex>` signature. */
+  readonly signatureHeader: string;
+  readonly mapping: InboundMapping;
+}
+
+export interface InboundWebhookOptions {
+  /** The JSON Lines log received facts are appended to. */
+  readonly logPath: string;
+  readonly sources: Readonly<Record<string, InboundWebhookSource>>;
+}
+
+type Reply = readonly [status: number, body: object];
+
+async function readRawBody(req: IncomingMessage): Promise<Buffer> {
+  const chunks: Buffer[] = [];
+  let size = 0;
+  for await (const chunk of req) {
+    size += (chunk as Buffer).length;
+    if (size > MAX_BODY_BYTES) throw new RangeError('Request body too large');
+    chunks.push(chunk as Buffer);
+  }
+  return Buffer.concat(chunks);
+}
+
+async function route(
+  req: IncomingMessage,
+  pathname: string,
+  options: InboundWebhookOptions,
+): Promise<Reply> {
+  const name = pathname.slice(WEBHOOKS_PREFIX.length);
+  const source = Object.hasOwn(options.sources, name)
+    ? options.sources[name]
+    : undefined;
+  if (!source) return [404, { error: 'Not found' }];
+  if (req.method !== 'POST') return [405, { error: 'Method not allowed' }];
+
+  let body: Buffer;
+  try {
+    body = await readRawBody(req);
+  } catch (err) {
+    if (err instanceof RangeError) return [413, { error: err.message }];
+    throw err;
+  }
+  const header = req.headers[source.signatureHeader.toLowerCase()];
+  const signature = Array.isArray(header) ? header[0] : header;
+  if (!verifyWebhookSignature(source.secret, body, signature)) {
+    return [401, { error: `Missing or invalid ${source.signatureHeader}` }];
+  }
+  // The signature covers only the body, so a replay repeats it exactly
+  const delivery = createHash('sha256').update(body).digest('hex');
+  if (readFactLog(options.logPath).some((f) => f.delivery === delivery)) {
+    return [409, { error: 'This delivery was already received' }];
+  }
+  let payload: unknown;
+  try {
+    payload = JSON.parse(body.toString('utf-8'));
+  } catch {
+    return [400, { error: 'Request body is not valid JSON' }];
+  }
+  const fact = mapInboundPayload(name, source.mapping, payload);
+  if (!fact) {
+    return [422, { error: 'The payload names no node or reports no fact' }];
+  }
+  const logged = { ...fact, delivery };
+  appendFact(options.logPath, logged);
+  return [202, logged];
+}
+
+/**
+ * Serve `POST /webhooks/v1/<source>` for each configured sender. The body
+ * must carry a valid HMAC-SHA256 signature of the sender's secret, which
+ * stands in for a bearer token. Its facts, mapped by the sender's dot
+ * paths, are appended to the fact log, and the graph API shows the latest
+ * of them with each node. Answers 202 with the logged fact, or 409 for a
+ * body the log already holds, so a captured delivery cannot be replayed.
+ */
+export async function handleWebhookRequest(
+  req: IncomingMessage,
+  res: ServerResponse,
+  pathname: string,
+  options: InboundWebhookOptions,
+): Promise<void> {
+  const [status, body] = await route(req, pathname, options);
+  res.writeHead(status, { 'Content-Type': 'application/json' });
+  res.end(JSON.stringify(body));
+}
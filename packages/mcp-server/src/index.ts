@@ -24,6 +24,81 @@ export type {
   SearchFilters,
 } from './db.js';
 export { generateClaudeDesktopConfig } from './config.js';
+export { createGraphService } from './grpc/service.js';
+export type {
+  GraphService,
+  GraphServiceOptions,
+  GraphQueryRequest,
+  GraphQueryResponse,
+  GraphEventListener,
+  NamespacedIndex,
+  NodeDetails,
+  TraverseRequest,
+} from './grpc/service.js';
+export {
+  createGrpcHandlers,
+  traceGrpcHandlers,
+  startGrpcServer,
+  PROTO_PATH,
+} from './grpc/server.js';
+export type { GrpcServerOptions, GrpcServerHandle } from './grpc/server.js';
+export { parseEventTypes, startHttpServer } from './http/server.js';
+export type { HttpServerOptions, HttpServerHandle } from './http/server.js';
+export {
+  GRAPH_PREFIX,
+  handleGraphRequest,
+  savedQueryUrl,
+} from './http/graph.js';
+export type { GraphApiOptions, GraphCaller } from './http/graph.js';
+export {
+  MAX_CLIENT_FRAME_BYTES,
+  acceptWebSocket,
+  encodeWebSocketFrame,
+  formatSseEvent,
+  websocketAccept,
+} from './http/events.js';
+export type { WebSocketConnection } from './http/events.js';
+export {
+  REGISTRY_PREFIX,
+  handleRegistryRequest,
+  parseRegistryPath,
+  registryView,
+} from './http/registry.js';
+export type { RegistryApiOptions } from './http/registry.js';
+export { SIMULATE_PREFIX, handleSimulationRequest } from './http/simulate.js';
+export type { SimulationApiOptions } from './http/simulate.js';
+export {
+  TRENDS_PREFIX,
+  handleTrendsRequest,
+  readRepositoryHistories,
+} from './http/trends.js';
+export type { TrendSource } from './http/trends.js';
+export { WEBHOOKS_PREFIX, handleWebhookRequest } from './http/webhooks.js';
+export type {
+  InboundWebhookOptions,
+  InboundWebhookSource,
+} from './http/webhooks.js';
+export {
+  ANONYMOUS,
+  bearerToken,
+  createAccessControl,
+  rolesFromClaims,
+  visibleNamespaces,
+} from './auth/access.js';
+export type {
+  AccessControl,
+  AuthOptions,
+  JwtAuthOptions,
+  Principal,
+  StaticToken,
+} from './auth/access.js';
+export { createJwksResolver, verifyJwt } from './auth/jwt.js';
+export type {
+  JwksResolverOptions,
+  JwtClaims,
+  JwtVerifyOptions,
+  KeyResolver,
+} from './auth/jwt.js';
 
 const isDirectRun =
   process.argv[1]?.endsWith('mcp-server/dist/index.js') ||
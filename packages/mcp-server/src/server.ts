@@ -11,12 +11,15 @@
  */
 import { McpServer } from '@modelcontextprotocol/sdk/server/mcp.js';
 import { StdioServerTransport } from '@modelcontextprotocol/sdk/server/stdio.js';
+import type { EncryptionOptions } from '@know-graph/core';
 import { openDatabase } from './db.js';
 import { registerAllTools } from './tools/index.js';
 
-export interface ServerOptions {
+export interface ServerOptions extends EncryptionOptions {
   readonly dbPath: string;
   readonly verbose?: boolean;
+  /** Closes the server when aborted. */
+  readonly signal?: AbortSignal;
 }
 
 export function createServer(options: ServerOptions): McpServer {
@@ -26,7 +29,7 @@ export function createServer(options: ServerOptions): McpServer {
   });
 
   try {
-    const db = openDatabase(options.dbPath);
+    const db = openDatabase(options.dbPath, options);
     registerAllTools(server, db);
   } catch (error) {
     if (options.verbose) {
@@ -54,7 +57,15 @@ export function createServer(options: ServerOptions): McpServer {
 }
 
 export async function startServer(options: ServerOptions): Promise<void> {
+  options.signal?.throwIfAborted();
   const server = createServer(options);
   const transport = new StdioServerTransport();
   await server.connect(transport);
+  options.signal?.addEventListener(
+    'abort',
+    () => {
+      void server.close();
+    },
+    { once: true },
+  );
 }
@@ -9,6 +9,8 @@
  *   business_goal: Present code graph data in clean, readable formats for AI consumption
  *   domain: mcp-tools
  */
+import { GitMetadataSchema } from '@know-graph/core';
+import type { GitLinkedCommit, GitMetadata } from '@know-graph/core';
 import type { EntityRow, DependencyRow, LinkRow, GraphStats } from '../db.js';
 
 export function formatEntity(entity: EntityRow): string {
@@ -70,6 +72,42 @@ export function formatDependencies(
   return lines.join('\n');
 }
 
+function parseGit(entity: EntityRow): GitMetadata | undefined {
+  if (!entity.git) return undefined;
+  try {
+    const result = GitMetadataSchema.safeParse(JSON.parse(entity.git));
+    return result.success ? result.data : undefined;
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * The commits whose `Knowgraph-Node` trailers name the entity and the last
+ * commit to its file, newest first.
+ */
+export function formatRecentChanges(entity: EntityRow): string {
+  const git = parseGit(entity);
+  if (!git) return 'No recent changes recorded.';
+
+  const changes: GitLinkedCommit[] = [...(git.linked_commits ?? [])];
+  if (!changes.some((change) => change.commit === git.last_commit)) {
+    changes.push({
+      commit: git.last_commit,
+      author: git.last_author,
+      date: git.last_modified,
+      subject: '',
+    });
+  }
+  return changes
+    .sort((a, b) => Date.parse(b.date) - Date.parse(a.date))
+    .map((change) => {
+      const subject = change.subject ? ` ${change.subject}` : '';
+      return `- \`${change.commit.slice(0, 7)}\`${subject} by ${change.author} on ${change.date}`;
+    })
+    .join('\n');
+}
+
 export function formatLinks(links: readonly LinkRow[]): string {
   if (links.length === 0) {
     return 'No external links found.';
@@ -12,7 +12,12 @@
 import { z } from 'zod';
 import type { McpServer } from '@modelcontextprotocol/sdk/server/mcp.js';
 import type { McpDatabase } from '../db.js';
-import { formatEntity, formatLinks, formatDependencies } from './format.js';
+import {
+  formatEntity,
+  formatLinks,
+  formatDependencies,
+  formatRecentChanges,
+} from './format.js';
 
 export function registerGetEntityDetails(
   server: McpServer,
@@ -50,6 +55,9 @@ export function registerGetEntityDetails(
           formatLinks(links),
           '',
           formatDependencies(params.entity_id, deps),
+          '',
+          '### Recent Changes',
+          formatRecentChanges(entity),
         ];
 
         return {